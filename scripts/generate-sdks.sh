@@ -0,0 +1,54 @@
+#!/usr/bin/env bash
+# Generates the Go and TypeScript client SDKs from the gateway's OpenAPI spec
+# and GraphQL schema, after stamping both with the version in sdk/VERSION.
+#
+# Usage: scripts/generate-sdks.sh [--check]
+#   --check  fail if the stamped versions were out of date, for CI
+
+set -euo pipefail
+
+ROOT="$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)"
+SDK_DIR="$ROOT/sdk"
+VERSION="$(tr -d '[:space:]' < "$SDK_DIR/VERSION")"
+CHECK=false
+[[ "${1:-}" == "--check" ]] && CHECK=true
+
+log() {
+    echo "[generate-sdks] $1"
+}
+
+if ! [[ "$VERSION" =~ ^[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.-]+)?$ ]]; then
+    echo "sdk/VERSION must be a semantic version, got '$VERSION'" >&2
+    exit 1
+fi
+
+log "Stamping version $VERSION"
+sed -i.bak -E "s/^const Version = \".*\"/const Version = \"$VERSION\"/" "$SDK_DIR/go/version.go"
+sed -i.bak -E "s/^export const VERSION = '.*'/export const VERSION = '$VERSION'/" "$SDK_DIR/typescript/src/version.ts"
+sed -i.bak -E "0,/\"version\": \".*\"/s//\"version\": \"$VERSION\"/" "$SDK_DIR/typescript/package.json"
+rm -f "$SDK_DIR/go/version.go.bak" "$SDK_DIR/typescript/src/version.ts.bak" "$SDK_DIR/typescript/package.json.bak"
+
+if $CHECK && ! git -C "$ROOT" diff --quiet -- sdk; then
+    echo "SDK versions do not match sdk/VERSION; run scripts/generate-sdks.sh and commit the result" >&2
+    git -C "$ROOT" diff --stat -- sdk >&2
+    exit 1
+fi
+
+log "Generating Go SDK"
+(
+    cd "$SDK_DIR/go"
+    # Generated code brings its own imports, so tidy only once it exists
+    GOFLAGS=-mod=mod go generate ./...
+    go mod tidy
+    go vet ./...
+)
+
+log "Generating TypeScript SDK"
+(
+    cd "$SDK_DIR/typescript"
+    npm install --no-audit --no-fund
+    npm run generate
+    npm run build
+)
+
+log "SDKs generated"
@@ -0,0 +1,172 @@
+// Package aegisshield holds the shared configuration of the AegisShield API
+// clients: credentials, retries and the HTTP client they run on. The typed
+// clients themselves are generated from the gateway's schemas into the rest
+// (OpenAPI) and gql (GraphQL) packages.
+package aegisshield
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Authentication headers accepted by the gateway
+const (
+	AuthorizationHeader = "Authorization"
+	APIKeyHeader        = "X-API-Key"
+)
+
+// TokenSource supplies bearer tokens. It is called for every attempt of
+// every request, so implementations that refresh tokens should cache them.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token implements TokenSource
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticToken is a TokenSource that always returns the same token
+type StaticToken string
+
+// Token implements TokenSource
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// RetryPolicy controls how failed requests are retried. Only requests that
+// are safe to repeat are retried: GET, HEAD, OPTIONS, PUT and DELETE,
+// GraphQL queries, and requests carrying an Idempotency-Key header.
+type RetryPolicy struct {
+	MaxAttempts int           // including the first attempt; 1 disables retries
+	BaseDelay   time.Duration // delay before the first retry, doubled on each further retry
+	MaxDelay    time.Duration // cap on any delay, including one asked for by Retry-After
+	// StatusCodes are the responses worth retrying
+	StatusCodes []int
+}
+
+// DefaultRetryPolicy retries rate limiting and gateway failures up to three
+// times
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		StatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// Config is the configuration shared by the REST and GraphQL clients
+type Config struct {
+	Tokens     TokenSource
+	APIKey     string
+	Retry      RetryPolicy
+	HTTPClient *http.Client
+	UserAgent  string
+}
+
+// Option configures a client
+type Option func(*Config)
+
+// WithBearerToken authenticates with a fixed user access token
+func WithBearerToken(token string) Option {
+	return WithTokenSource(StaticToken(token))
+}
+
+// WithTokenSource authenticates with bearer tokens from source, for callers
+// that refresh their tokens
+func WithTokenSource(source TokenSource) Option {
+	return func(c *Config) {
+		c.Tokens = source
+	}
+}
+
+// WithAPIKey authenticates as a service account. The key's scopes are its
+// only permissions. An API key takes precedence over bearer tokens.
+func WithAPIKey(key string) Option {
+	return func(c *Config) {
+		c.APIKey = key
+	}
+}
+
+// WithRetryPolicy replaces the default retry policy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Config) {
+		c.Retry = policy
+	}
+}
+
+// WithHTTPClient runs requests on client. Its transport is wrapped, not
+// replaced, so proxies and TLS settings are kept.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Config) {
+		c.HTTPClient = client
+	}
+}
+
+// WithUserAgent prefixes the SDK's User-Agent with the integrator's own
+func WithUserAgent(userAgent string) Option {
+	return func(c *Config) {
+		c.UserAgent = userAgent
+	}
+}
+
+// NewConfig applies opts over the defaults
+func NewConfig(opts ...Option) Config {
+	config := Config{Retry: DefaultRetryPolicy()}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// NewHTTPClient returns an HTTP client that authenticates and retries every
+// request as configured by opts
+func NewHTTPClient(opts ...Option) *http.Client {
+	config := NewConfig(opts...)
+
+	client := &http.Client{}
+	if config.HTTPClient != nil {
+		*client = *config.HTTPClient
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	userAgent := "aegisshield-sdk-go/" + Version
+	if config.UserAgent != "" {
+		userAgent = config.UserAgent + " " + userAgent
+	}
+
+	client.Transport = &transport{
+		base:      base,
+		tokens:    config.Tokens,
+		apiKey:    config.APIKey,
+		retry:     config.Retry,
+		userAgent: userAgent,
+	}
+	return client
+}
+
+type retryableKey struct{}
+
+// WithRetryable marks requests made with ctx as safe to retry whatever their
+// method. The GraphQL client uses it for queries.
+func WithRetryable(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryableKey{}, true)
+}
+
+func isRetryable(ctx context.Context) bool {
+	retryable, _ := ctx.Value(retryableKey{}).(bool)
+	return retryable
+}
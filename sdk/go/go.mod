@@ -0,0 +1,9 @@
+module github.com/bubbis-poncho/AegisShield/sdk/go
+
+go 1.21
+
+require (
+	github.com/Khan/genqlient v0.7.0
+	github.com/oapi-codegen/oapi-codegen/v2 v2.3.0
+	github.com/oapi-codegen/runtime v1.1.1
+)
@@ -0,0 +1,41 @@
+// Package gql is the typed client for the gateway's GraphQL API. A function
+// is generated into client.gen.go for each operation in sdk/operations, for
+// example ListAlerts(ctx, client, filter).
+package gql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Khan/genqlient/graphql"
+
+	aegisshield "github.com/bubbis-poncho/AegisShield/sdk/go"
+)
+
+// QueryPath is where the gateway serves GraphQL
+const QueryPath = "/query"
+
+// New creates a GraphQL client for the gateway at baseURL, for example
+// https://api.example.com. Queries are retried as configured by opts;
+// mutations are sent once.
+func New(baseURL string, opts ...aegisshield.Option) graphql.Client {
+	return &client{
+		graphql: graphql.NewClient(
+			strings.TrimRight(baseURL, "/")+QueryPath,
+			aegisshield.NewHTTPClient(opts...),
+		),
+	}
+}
+
+// client marks queries as retryable before sending them, since they are
+// POSTed like mutations
+type client struct {
+	graphql graphql.Client
+}
+
+func (c *client) MakeRequest(ctx context.Context, req *graphql.Request, resp *graphql.Response) error {
+	if strings.HasPrefix(strings.TrimSpace(req.Query), "query") {
+		ctx = aegisshield.WithRetryable(ctx)
+	}
+	return c.graphql.MakeRequest(ctx, req, resp)
+}
@@ -0,0 +1,20 @@
+// Package rest is the typed client for the gateway's REST endpoints: audit
+// trails, entity profiles and usage metering. Its types and methods are
+// generated from services/api-gateway/openapi.yaml into client.gen.go.
+package rest
+
+import (
+	"strings"
+
+	aegisshield "github.com/bubbis-poncho/AegisShield/sdk/go"
+)
+
+// New creates a REST client for the gateway at baseURL, for example
+// https://api.example.com. Every request is authenticated and retried as
+// configured by opts.
+func New(baseURL string, opts ...aegisshield.Option) (*ClientWithResponses, error) {
+	return NewClientWithResponses(
+		strings.TrimRight(baseURL, "/"),
+		WithHTTPClient(aegisshield.NewHTTPClient(opts...)),
+	)
+}
@@ -0,0 +1,3 @@
+package rest
+
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen -config oapi-codegen.yaml ../../../services/api-gateway/openapi.yaml
@@ -0,0 +1,9 @@
+//go:build tools
+
+// Code generators run by go generate, pinned in go.mod
+package aegisshield
+
+import (
+	_ "github.com/Khan/genqlient"
+	_ "github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen"
+)
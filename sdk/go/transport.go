@@ -0,0 +1,159 @@
+package aegisshield
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// IdempotencyKeyHeader marks a non-idempotent request as safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// transport adds credentials to each request and retries failures allowed
+// by the retry policy
+type transport struct {
+	base      http.RoundTripper
+	tokens    TokenSource
+	apiKey    string
+	retry     RetryPolicy
+	userAgent string
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := t.retry.MaxAttempts
+	if attempts < 1 || !t.canRetry(req) {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptReq, err := t.prepare(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if attempt >= attempts || !t.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt, resp)
+		if resp != nil {
+			// Drain so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// prepare clones the request for an attempt, rewinding its body and setting
+// the credentials and User-Agent
+func (t *transport) prepare(req *http.Request, attempt int) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if attempt > 1 && req.Body != nil && req.Body != http.NoBody {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("aegisshield: failed to rewind request body: %w", err)
+		}
+		clone.Body = body
+	}
+
+	switch {
+	case t.apiKey != "":
+		clone.Header.Set(APIKeyHeader, t.apiKey)
+	case t.tokens != nil:
+		token, err := t.tokens.Token(req.Context())
+		if err != nil {
+			return nil, fmt.Errorf("aegisshield: failed to get token: %w", err)
+		}
+		clone.Header.Set(AuthorizationHeader, "Bearer "+token)
+	}
+
+	if clone.Header.Get("User-Agent") == "" {
+		clone.Header.Set("User-Agent", t.userAgent)
+	}
+	return clone, nil
+}
+
+// canRetry reports whether repeating req cannot apply a change twice and
+// its body can be sent again
+func (t *transport) canRetry(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != "" || isRetryable(req.Context())
+}
+
+func (t *transport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		// The request may not have reached the gateway; it is safe to send
+		// again because canRetry already held
+		return true
+	}
+	for _, code := range t.retry.StatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the next attempt: the server's
+// Retry-After when given, otherwise exponential backoff with full jitter
+func (t *transport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return t.capDelay(delay)
+		}
+	}
+
+	delay := t.retry.BaseDelay << (attempt - 1)
+	if delay <= 0 {
+		return 0
+	}
+	delay = t.capDelay(delay)
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+func (t *transport) capDelay(delay time.Duration) time.Duration {
+	if t.retry.MaxDelay > 0 && delay > t.retry.MaxDelay {
+		return t.retry.MaxDelay
+	}
+	return delay
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		delay := time.Until(at)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
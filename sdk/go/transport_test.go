@@ -0,0 +1,261 @@
+package aegisshield
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testRetry retries quickly so tests do not wait on backoff
+var testRetry = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    5 * time.Millisecond,
+	StatusCodes: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+}
+
+// gateway answers with statuses in turn, repeating the last, and records
+// each request it receives
+type gateway struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	g.requests = append(g.requests, r)
+	g.bodies = append(g.bodies, string(body))
+
+	status := g.statuses[len(g.statuses)-1]
+	if len(g.requests) <= len(g.statuses) {
+		status = g.statuses[len(g.requests)-1]
+	}
+	if status == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", "120")
+	}
+	w.WriteHeader(status)
+}
+
+func (g *gateway) attempts() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.requests)
+}
+
+func newGateway(t *testing.T, statuses ...int) (*gateway, string) {
+	t.Helper()
+
+	g := &gateway{statuses: statuses}
+	server := httptest.NewServer(g)
+	t.Cleanup(server.Close)
+	return g, server.URL
+}
+
+func TestTransportRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		header   string // Idempotency-Key
+		query    bool   // marked retryable, as GraphQL queries are
+		statuses []int
+		attempts int
+		status   int
+	}{
+		{"GET Retried Until Success", http.MethodGet, "", "", false, []int{503, 503, 200}, 3, 200},
+		{"GET Gives Up After Max Attempts", http.MethodGet, "", "", false, []int{503}, 3, 503},
+		{"Rate Limit Retried", http.MethodGet, "", "", false, []int{429, 200}, 2, 200},
+		{"Unlisted Status Not Retried", http.MethodGet, "", "", false, []int{500, 200}, 1, 500},
+		{"Client Error Not Retried", http.MethodGet, "", "", false, []int{404, 200}, 1, 404},
+		{"PUT Retried With Its Body", http.MethodPut, `{"status":"closed"}`, "", false, []int{503, 200}, 2, 200},
+		{"DELETE Retried", http.MethodDelete, "", "", false, []int{503, 200}, 2, 200},
+		{"POST Not Retried", http.MethodPost, `{"title":"x"}`, "", false, []int{503, 200}, 1, 503},
+		{"POST With Idempotency Key Retried", http.MethodPost, `{"title":"x"}`, "case-42", false, []int{503, 200}, 2, 200},
+		{"GraphQL Query Retried", http.MethodPost, `{"query":"{ alerts { id } }"}`, "", true, []int{503, 200}, 2, 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, url := newGateway(t, tt.statuses...)
+			client := NewHTTPClient(WithRetryPolicy(testRetry))
+
+			ctx := context.Background()
+			if tt.query {
+				ctx = WithRetryable(ctx)
+			}
+			req, _ := http.NewRequestWithContext(ctx, tt.method, url+"/alerts", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.header)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if g.attempts() != tt.attempts {
+				t.Errorf("attempts = %d, want %d", g.attempts(), tt.attempts)
+			}
+			for i, body := range g.bodies {
+				if body != tt.body {
+					t.Errorf("attempt %d body = %q, want %q", i+1, body, tt.body)
+				}
+			}
+		})
+	}
+}
+
+func TestTransportStopsOnCancel(t *testing.T) {
+	g, url := newGateway(t, http.StatusTooManyRequests)
+	policy := testRetry
+	policy.MaxDelay = time.Minute
+	client := NewHTTPClient(WithRetryPolicy(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url+"/alerts", nil)
+
+	// The gateway asks for a two minute wait, capped at one; the deadline ends it
+	started := time.Now()
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the deadline", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("took %v", elapsed)
+	}
+	if g.attempts() != 1 {
+		t.Errorf("attempts = %d, want 1", g.attempts())
+	}
+}
+
+func TestTransportCredentials(t *testing.T) {
+	t.Run("Bearer Token Per Attempt", func(t *testing.T) {
+		g, url := newGateway(t, 503, 200)
+		var issued int
+		tokens := TokenSourceFunc(func(ctx context.Context) (string, error) {
+			issued++
+			return "token-" + string(rune('0'+issued)), nil
+		})
+		client := NewHTTPClient(WithTokenSource(tokens), WithRetryPolicy(testRetry), WithUserAgent("case-portal/2.1"))
+
+		resp, err := client.Get(url + "/alerts")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		resp.Body.Close()
+
+		if got := g.requests[0].Header.Get(AuthorizationHeader); got != "Bearer token-1" {
+			t.Errorf("first attempt Authorization = %q", got)
+		}
+		if got := g.requests[1].Header.Get(AuthorizationHeader); got != "Bearer token-2" {
+			t.Errorf("retry Authorization = %q, want a fresh token", got)
+		}
+		if got := g.requests[0].Header.Get("User-Agent"); got != "case-portal/2.1 aegisshield-sdk-go/"+Version {
+			t.Errorf("User-Agent = %q", got)
+		}
+	})
+
+	t.Run("API Key Takes Precedence", func(t *testing.T) {
+		g, url := newGateway(t, 200)
+		client := NewHTTPClient(WithBearerToken("user-token"), WithAPIKey("ask_ingest"))
+
+		resp, err := client.Get(url + "/alerts")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		resp.Body.Close()
+
+		if got := g.requests[0].Header.Get(APIKeyHeader); got != "ask_ingest" {
+			t.Errorf("API key = %q", got)
+		}
+		if got := g.requests[0].Header.Get(AuthorizationHeader); got != "" {
+			t.Errorf("Authorization = %q, want none", got)
+		}
+	})
+
+	t.Run("Token Failure Sends Nothing", func(t *testing.T) {
+		g, url := newGateway(t, 200)
+		tokens := TokenSourceFunc(func(ctx context.Context) (string, error) {
+			return "", errors.New("refresh token expired")
+		})
+		client := NewHTTPClient(WithTokenSource(tokens))
+
+		if _, err := client.Get(url + "/alerts"); err == nil || !strings.Contains(err.Error(), "refresh token expired") {
+			t.Errorf("err = %v, want the token error", err)
+		}
+		if g.attempts() != 0 {
+			t.Errorf("attempts = %d, want none", g.attempts())
+		}
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	tr := &transport{retry: RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}}
+
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter string
+		max        time.Duration
+		exact      bool
+	}{
+		{"First Retry", 1, "", 100 * time.Millisecond, false},
+		{"Doubles", 3, "", 400 * time.Millisecond, false},
+		{"Capped", 10, "", time.Second, false},
+		{"Retry-After Seconds", 1, "0", 0, true},
+		{"Retry-After Capped", 1, "120", time.Second, true},
+		{"Invalid Retry-After Ignored", 1, "soon", 100 * time.Millisecond, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+			delay := tr.backoff(tt.attempt, resp)
+			if tt.exact && delay != tt.max {
+				t.Errorf("delay = %v, want %v", delay, tt.max)
+			}
+			if delay < 0 || delay > tt.max {
+				t.Errorf("delay = %v, want at most %v", delay, tt.max)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		delay time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"30", 30 * time.Second, true},
+		{"-1", 0, false},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+		{"later", 0, false},
+	}
+
+	for _, tt := range tests {
+		delay, ok := retryAfter(tt.value)
+		if delay != tt.delay || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, delay, ok, tt.delay, tt.ok)
+		}
+	}
+}
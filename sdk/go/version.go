@@ -0,0 +1,5 @@
+package aegisshield
+
+// Version is the SDK release, stamped from sdk/VERSION by
+// scripts/generate-sdks.sh. It is sent in the User-Agent header.
+const Version = "0.1.0"
@@ -0,0 +1,22 @@
+import type { CodegenConfig } from '@graphql-codegen/cli'
+
+// Generates typed documents for the operations shared with the Go SDK
+const config: CodegenConfig = {
+  schema: '../../services/api-gateway/schema.graphql',
+  documents: '../operations/*.graphql',
+  generates: {
+    'src/generated/graphql.ts': {
+      plugins: ['typescript', 'typescript-operations', 'typed-document-node'],
+      config: {
+        scalars: {
+          UUID: 'string',
+          Time: 'string',
+        },
+        enumsAsTypes: true,
+        avoidOptionals: { field: true },
+      },
+    },
+  },
+}
+
+export default config
@@ -0,0 +1,151 @@
+import { VERSION } from './version'
+
+export const AUTHORIZATION_HEADER = 'Authorization'
+export const API_KEY_HEADER = 'X-API-Key'
+export const IDEMPOTENCY_KEY_HEADER = 'Idempotency-Key'
+
+/** Supplies bearer tokens. Called for every attempt, so cache refreshed tokens. */
+export type TokenSource = () => string | Promise<string>
+
+/**
+ * Controls how failed requests are retried. Only requests that are safe to
+ * repeat are retried: GET, HEAD, OPTIONS, PUT and DELETE, GraphQL queries,
+ * and requests carrying an Idempotency-Key header.
+ */
+export interface RetryPolicy {
+  /** Including the first attempt; 1 disables retries */
+  maxAttempts: number
+  /** Delay before the first retry in milliseconds, doubled on each further retry */
+  baseDelayMs: number
+  /** Cap on any delay, including one asked for by Retry-After */
+  maxDelayMs: number
+  /** Responses worth retrying */
+  statusCodes: number[]
+}
+
+/** Retries rate limiting and gateway failures up to three times */
+export const defaultRetryPolicy: RetryPolicy = {
+  maxAttempts: 4,
+  baseDelayMs: 200,
+  maxDelayMs: 10_000,
+  statusCodes: [429, 502, 503, 504],
+}
+
+export interface ClientOptions {
+  /** Gateway URL, for example https://api.example.com */
+  baseUrl: string
+  /** User access token, or a function returning a current one */
+  token?: string | TokenSource
+  /** Service account API key; takes precedence over token */
+  apiKey?: string
+  retry?: Partial<RetryPolicy>
+  /** Prefixed to the SDK's User-Agent where the runtime allows setting it */
+  userAgent?: string
+  fetch?: typeof fetch
+}
+
+export type Fetch = (input: RequestInfo | URL, init?: RequestInit) => Promise<Response>
+
+/**
+ * Returns a fetch that authenticates and retries every request. Pass
+ * retryable to retry a request whatever its method.
+ */
+export function createFetch(options: ClientOptions, retryable = false): Fetch {
+  const policy = { ...defaultRetryPolicy, ...options.retry }
+  const baseFetch = options.fetch ?? globalThis.fetch
+  const userAgent = [options.userAgent, `aegisshield-sdk-js/${VERSION}`].filter(Boolean).join(' ')
+
+  return async (input, init) => {
+    const request = new Request(input, init)
+    const attempts = retryable || canRetry(request) ? Math.max(policy.maxAttempts, 1) : 1
+
+    for (let attempt = 1; ; attempt++) {
+      const attemptRequest = await prepare(request.clone(), options, userAgent)
+
+      let response: Response | undefined
+      try {
+        response = await baseFetch(attemptRequest)
+      } catch (err) {
+        if (attempt >= attempts || request.signal.aborted) {
+          throw err
+        }
+      }
+      if (response && (attempt >= attempts || !policy.statusCodes.includes(response.status))) {
+        return response
+      }
+
+      const delay = backoff(policy, attempt, response)
+      await response?.body?.cancel()
+      await sleep(delay, request.signal)
+    }
+  }
+}
+
+async function prepare(request: Request, options: ClientOptions, userAgent: string): Promise<Request> {
+  if (options.apiKey) {
+    request.headers.set(API_KEY_HEADER, options.apiKey)
+  } else if (options.token) {
+    const token = typeof options.token === 'function' ? await options.token() : options.token
+    request.headers.set(AUTHORIZATION_HEADER, `Bearer ${token}`)
+  }
+  if (!request.headers.has('User-Agent')) {
+    // Browsers ignore this; Node sends it
+    request.headers.set('User-Agent', userAgent)
+  }
+  return request
+}
+
+function canRetry(request: Request): boolean {
+  switch (request.method) {
+    case 'GET':
+    case 'HEAD':
+    case 'OPTIONS':
+    case 'PUT':
+    case 'DELETE':
+      return true
+  }
+  return request.headers.has(IDEMPOTENCY_KEY_HEADER)
+}
+
+/**
+ * The server's Retry-After when given, otherwise exponential backoff with
+ * full jitter
+ */
+function backoff(policy: RetryPolicy, attempt: number, response?: Response): number {
+  const retryAfter = parseRetryAfter(response?.headers.get('Retry-After'))
+  if (retryAfter !== undefined) {
+    return Math.min(retryAfter, policy.maxDelayMs)
+  }
+  const delay = Math.min(policy.baseDelayMs * 2 ** (attempt - 1), policy.maxDelayMs)
+  return Math.random() * delay
+}
+
+/** Parses a Retry-After header given in seconds or as an HTTP date */
+function parseRetryAfter(value?: string | null): number | undefined {
+  if (!value) {
+    return undefined
+  }
+  if (/^\d+$/.test(value)) {
+    return Number(value) * 1000
+  }
+  const at = Date.parse(value)
+  return Number.isNaN(at) ? undefined : Math.max(at - Date.now(), 0)
+}
+
+function sleep(ms: number, signal: AbortSignal): Promise<void> {
+  return new Promise((resolve, reject) => {
+    if (signal.aborted) {
+      reject(signal.reason)
+      return
+    }
+    const timer = setTimeout(() => {
+      signal.removeEventListener('abort', onAbort)
+      resolve()
+    }, ms)
+    const onAbort = () => {
+      clearTimeout(timer)
+      reject(signal.reason)
+    }
+    signal.addEventListener('abort', onAbort, { once: true })
+  })
+}
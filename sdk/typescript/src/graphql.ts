@@ -0,0 +1,75 @@
+import type { TypedDocumentNode } from '@graphql-typed-document-node/core'
+import { print, type OperationDefinitionNode } from 'graphql'
+
+import { createFetch, type ClientOptions, type Fetch } from './client'
+
+export const QUERY_PATH = '/query'
+
+export interface GraphQLErrorDetail {
+  message: string
+  path?: (string | number)[]
+}
+
+/** Thrown when the gateway answers with GraphQL errors */
+export class GraphQLError extends Error {
+  constructor(readonly errors: GraphQLErrorDetail[], readonly data?: unknown) {
+    super(errors.map((e) => e.message).join('; '))
+    this.name = 'GraphQLError'
+  }
+}
+
+/** Thrown when the gateway answers with a non-2xx status */
+export class HTTPError extends Error {
+  constructor(readonly status: number, readonly body: string) {
+    super(`request failed with status ${status}`)
+    this.name = 'HTTPError'
+  }
+}
+
+/**
+ * Client for the gateway's GraphQL API. Pass it the typed documents
+ * generated from sdk/operations, for example ListAlertsDocument. Queries are
+ * retried; mutations are sent once.
+ */
+export class GraphQLClient {
+  private readonly url: string
+  private readonly queryFetch: Fetch
+  private readonly mutationFetch: Fetch
+
+  constructor(options: ClientOptions) {
+    this.url = options.baseUrl.replace(/\/+$/, '') + QUERY_PATH
+    this.queryFetch = createFetch(options, true)
+    this.mutationFetch = createFetch(options)
+  }
+
+  async request<TResult, TVariables>(
+    document: TypedDocumentNode<TResult, TVariables>,
+    variables?: TVariables,
+    init?: { signal?: AbortSignal },
+  ): Promise<TResult> {
+    const operation = document.definitions.find(
+      (d): d is OperationDefinitionNode => d.kind === 'OperationDefinition',
+    )
+    const send = operation?.operation === 'query' ? this.queryFetch : this.mutationFetch
+
+    const response = await send(this.url, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({
+        query: print(document),
+        operationName: operation?.name?.value,
+        variables,
+      }),
+      signal: init?.signal,
+    })
+    if (!response.ok) {
+      throw new HTTPError(response.status, await response.text())
+    }
+
+    const body = (await response.json()) as { data?: TResult; errors?: GraphQLErrorDetail[] }
+    if (body.errors?.length) {
+      throw new GraphQLError(body.errors, body.data)
+    }
+    return body.data as TResult
+  }
+}
@@ -0,0 +1,5 @@
+export * from './client'
+export * from './graphql'
+export * from './rest'
+export * from './generated/graphql'
+export { VERSION } from './version'
@@ -0,0 +1,21 @@
+import createClient from 'openapi-fetch'
+
+import { createFetch, type ClientOptions } from './client'
+import type { paths } from './generated/rest'
+
+export type { paths, components } from './generated/rest'
+
+/**
+ * Creates a typed client for the gateway's REST endpoints, generated from
+ * services/api-gateway/openapi.yaml:
+ *
+ *   const { data, error } = await rest.GET('/entities/{id}/profile', { params: { path: { id } } })
+ */
+export function createRestClient(options: ClientOptions) {
+  return createClient<paths>({
+    baseUrl: options.baseUrl.replace(/\/+$/, ''),
+    fetch: createFetch(options),
+  })
+}
+
+export type RestClient = ReturnType<typeof createRestClient>
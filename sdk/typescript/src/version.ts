@@ -0,0 +1,2 @@
+// Stamped from sdk/VERSION by scripts/generate-sdks.sh
+export const VERSION = '0.1.0'
@@ -8,26 +8,53 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"sync"
 	"syscall"
 	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/aegis-shield/services/alerting-engine/internal/alertcluster"
+	"github.com/aegis-shield/services/alerting-engine/internal/alerthandoff"
+	"github.com/aegis-shield/services/alerting-engine/internal/alertlifecycle"
+	"github.com/aegis-shield/services/alerting-engine/internal/alertstream"
+	"github.com/aegis-shield/services/alerting-engine/internal/alertthread"
+	"github.com/aegis-shield/services/alerting-engine/internal/autoclose"
+	"github.com/aegis-shield/services/alerting-engine/internal/backtest"
+	"github.com/aegis-shield/services/alerting-engine/internal/batchdigest"
+	"github.com/aegis-shield/services/alerting-engine/internal/casesync"
 	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/correlation"
 	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/deadletter"
+	"github.com/aegis-shield/services/alerting-engine/internal/dedup"
 	"github.com/aegis-shield/services/alerting-engine/internal/engine"
 	"github.com/aegis-shield/services/alerting-engine/internal/handlers"
 	"github.com/aegis-shield/services/alerting-engine/internal/interceptors"
 	"github.com/aegis-shield/services/alerting-engine/internal/kafka"
 	"github.com/aegis-shield/services/alerting-engine/internal/metrics"
 	"github.com/aegis-shield/services/alerting-engine/internal/notification"
+	"github.com/aegis-shield/services/alerting-engine/internal/notifytemplate"
+	"github.com/aegis-shield/services/alerting-engine/internal/riskwebhook"
+	"github.com/aegis-shield/services/alerting-engine/internal/rulefeedback"
+	"github.com/aegis-shield/services/alerting-engine/internal/ruleowner"
+	"github.com/aegis-shield/services/alerting-engine/internal/rulepack"
+	"github.com/aegis-shield/services/alerting-engine/internal/ruleversion"
 	"github.com/aegis-shield/services/alerting-engine/internal/scheduler"
 	"github.com/aegis-shield/services/alerting-engine/internal/server"
+	"github.com/aegis-shield/services/alerting-engine/internal/severitytune"
+	"github.com/aegis-shield/services/alerting-engine/internal/slo"
+	"github.com/aegis-shield/services/alerting-engine/internal/storm"
+	"github.com/aegis-shield/services/alerting-engine/internal/training"
+	"github.com/aegis-shield/services/alerting-engine/internal/watchlist"
 	alertingpb "github.com/aegis-shield/shared/proto"
+	"github.com/aegisshield/shared/rbac"
+	"github.com/aegisshield/shared/reqctx"
+	"github.com/aegisshield/shared/startup"
 )
 
 const (
@@ -50,9 +77,26 @@ func main() {
 		"version", version,
 		"environment", cfg.Environment)
 
+	// Shutdown signals stop the services and abort startup retries
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	// Start in dependency order: migrations, repositories, consumers, servers.
+	// Unavailable dependencies are retried with backoff.
+	seq := startup.NewSequencer(startup.Backoff{
+		Initial:    cfg.Startup.InitialBackoff,
+		Max:        cfg.Startup.MaxBackoff,
+		Multiplier: 2,
+		MaxElapsed: cfg.Startup.MaxElapsed,
+	}, logger)
+
 	// Setup database connection
-	db, err := database.Connect(cfg.Database.ConnectionString)
-	if err != nil {
+	var db *sqlx.DB
+	if err := seq.Step(ctx, startup.Component{Name: "postgres", Phase: startup.PhaseMigrations, Critical: true}, func(ctx context.Context) error {
+		var err error
+		db, err = database.Connect(cfg.Database.ConnectionString)
+		return err
+	}); err != nil {
 		logger.Error("Failed to connect to database", "error", err)
 		os.Exit(1)
 	}
@@ -63,7 +107,9 @@ func main() {
 	}()
 
 	// Run database migrations
-	if err := database.RunMigrations(db, logger); err != nil {
+	if err := seq.Step(ctx, startup.Component{Name: "migrations", Phase: startup.PhaseMigrations, Critical: true}, func(ctx context.Context) error {
+		return database.RunMigrations(db, logger)
+	}); err != nil {
 		logger.Error("Failed to run database migrations", "error", err)
 		os.Exit(1)
 	}
@@ -73,18 +119,357 @@ func main() {
 	ruleRepo := database.NewRuleRepository(db, logger)
 	notificationRepo := database.NewNotificationRepository(db, logger)
 	escalationRepo := database.NewEscalationRepository(db, logger)
+	trainingRepo := database.NewTrainingRepository(db, logger)
+	watchlistRepo := database.NewWatchlistRepository(db, logger)
+	auditRepo := database.NewAuditRepository(db, logger)
+	evidenceRepo := database.NewEvidenceRepository(db, logger)
+	caseSyncRepo := database.NewCaseSyncRepository(db, logger)
+	riskWebhookRepo := database.NewRiskWebhookRepository(db, logger)
+	batchDigestRepo := database.NewBatchDigestRepository(db, logger)
+	alertClusterRepo := database.NewAlertClusterRepository(db, logger)
+	rulePackRepo := database.NewRulePackRepository(db, logger)
+	alertStormRepo := database.NewAlertStormRepository(db, logger)
+	alertCommentRepo := database.NewAlertCommentRepository(db, logger)
+	deadLetterRepo := database.NewDeadLetterRepository(db, logger)
+	backtestRepo := database.NewRuleBacktestRepository(db, logger)
+	suppressionRepo := database.NewRuleSuppressionRepository(db, logger)
+	alertLifecycleRepo := database.NewAlertLifecycleRepository(db, logger)
+	notificationTemplateRepo := database.NewNotificationTemplateRepository(db, logger)
+	notificationBrandingRepo := database.NewNotificationBrandingRepository(db, logger)
+	alertCaseRepo := database.NewAlertCaseRepository(db, logger)
+	alertHandoffRepo := database.NewAlertHandoffRepository(db, logger)
+	ruleWindowRepo := database.NewRuleWindowRepository(db, logger)
+	ruleFeedbackRepo := database.NewRuleFeedbackRepository(db, logger)
+	severityTuningRepo := database.NewSeverityTuningRepository(db, logger)
+	ruleOwnerRepo := database.NewRuleOwnerRepository(db, logger)
+	alertAutoCloseRepo := database.NewAlertAutoCloseRepository(db, logger)
 
-	// Setup notification manager
-	notificationManager := notification.NewManager(cfg, logger)
 
 	// Setup rule engine
 	ruleEngine := engine.NewRuleEngine(cfg, logger, ruleRepo)
 
+	// Setup dual-controlled watchlists; active exclusions suppress rule evaluation
+	watchlistService := watchlist.NewService(cfg, logger, watchlistRepo)
+	if err := seq.Step(ctx, startup.Component{Name: "exclusion-list", Phase: startup.PhaseRepositories, Critical: true}, watchlistService.Refresh); err != nil {
+		logger.Error("Failed to load exclusion list", "error", err)
+		os.Exit(1)
+	}
+	ruleEngine.SetSuppressor(watchlistService)
+
+	// Setup rule pack export/import; its reference index validates pack references
+	rulePackService := rulepack.NewService(cfg, logger, ruleRepo, rulePackRepo, ruleEngine)
+	if err := seq.Step(ctx, startup.Component{Name: "rule-reference-data", Phase: startup.PhaseRepositories, Critical: true}, rulePackService.Refresh); err != nil {
+		logger.Error("Failed to load rule reference data", "error", err)
+		os.Exit(1)
+	}
+
+	// Setup rule versioning; rollbacks recompile restored conditions on the engine
+	ruleVersionService := ruleversion.NewService(logger, ruleRepo, evidenceRepo, ruleEngine)
+
+	// Setup the reference data cache; rule conditions read lookup tables,
+	// thresholds and risk tiers from memory and reload them when they change
+	referenceCache := engine.NewReferenceCache(rulePackRepo, logger, engine.ReferenceCacheOptions{
+		SyncInterval:    cfg.Rules.ReferenceCache.SyncInterval,
+		ChangeOverlap:   cfg.Rules.ReferenceCache.ChangeOverlap,
+		ChangeRetention: cfg.Rules.ReferenceCache.ChangeRetention,
+		LoadTimeout:     cfg.Rules.ReferenceCache.LoadTimeout,
+		MaxLookupTables: cfg.Rules.ReferenceCache.MaxLookupTables,
+		MaxRiskTiers:    cfg.Rules.ReferenceCache.MaxRiskTiers,
+	})
+	ruleEngine.SetReferenceData(referenceCache)
+
+	// Setup rule window state; windows shared by replicas live in Redis, a
+	// single replica keeps them in memory and checkpoints them to the database
+	var memoryWindows *engine.MemoryWindowStore
+	switch cfg.Rules.Windows.Backend {
+	case "redis":
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+			PoolSize: cfg.Redis.PoolSize,
+		})
+		defer redisClient.Close()
+		if err := seq.Step(ctx, startup.Component{Name: "rule-window-redis", Phase: startup.PhaseRepositories, Critical: true}, func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}); err != nil {
+			logger.Error("Failed to connect to rule window Redis", "error", err)
+			os.Exit(1)
+		}
+		ruleEngine.SetWindowStore(engine.NewRedisWindowStore(redisClient, cfg.Rules.Windows.RedisKeyPrefix, cfg.Rules.Windows.MaxEntriesPerWindow))
+	default:
+		memoryWindows = engine.NewMemoryWindowStore(ruleWindowRepo, logger, engine.MemoryWindowStoreOptions{
+			MaxEntries:         cfg.Rules.Windows.MaxEntriesPerWindow,
+			CheckpointInterval: cfg.Rules.Windows.CheckpointInterval,
+			Instance:           cfg.Rules.Windows.CheckpointInstance,
+		})
+		// a replica that cannot restore its windows starts them empty
+		seq.Step(ctx, startup.Component{Name: "rule-windows", Phase: startup.PhaseRepositories}, memoryWindows.Restore)
+		ruleEngine.SetWindowStore(memoryWindows)
+	}
+
+	// Setup rule backtesting; drafts are replayed against the event topic's history
+	backtestService := backtest.NewService(cfg, logger, backtestRepo, ruleRepo, alertRepo, ruleEngine,
+		kafka.NewHistoryReader(cfg, logger))
+
+	// Setup alert storm detection; storming rules raise a triage sample and hold the rest
+	stormService := storm.NewService(cfg, logger, alertStormRepo)
+	ruleEngine.SetStormGate(stormService)
+
+	// Setup alert deduplication; repeats inside a rule's suppression window are counted, not raised
+	dedupService := dedup.NewService(cfg, logger, suppressionRepo, ruleRepo)
+	ruleEngine.SetDeduplicator(dedupService)
+
+	// Setup alert correlation; new alerts join the case of related alerts raised within the window
+	correlationService := correlation.NewService(cfg, logger, alertCaseRepo, alertClusterRepo)
+	ruleEngine.SetCorrelator(correlationService)
+
+	// Setup notification templates; rule overrides win over channel defaults, which win over the built-ins
+	notificationTemplateService := notifytemplate.NewService(cfg, logger, notificationTemplateRepo, notificationBrandingRepo, ruleRepo, alertRepo)
+	ruleEngine.SetNotificationRenderer(notificationTemplateService)
+
+	// Setup notification channel providers; rules notify along their routes, or their listed channels without any
+	notificationDispatcher := notification.NewDispatcher(logger, notification.NewRegistryFromConfig(cfg.Notifications),
+		notificationRepo, ruleRepo, notificationTemplateService)
+	ruleEngine.SetNotificationDispatcher(notificationDispatcher)
+
+	// Setup notification manager
+	notificationManager, err := notification.NewManager(cfg, logger, notificationRepo, notificationDispatcher)
+	if err != nil {
+		logger.Error("Failed to create notification manager", "error", err)
+		os.Exit(1)
+	}
+
+	// Setup training simulator for analyst practice sessions
+	trainingSimulator := training.NewSimulator(cfg, logger, alertRepo, alertCaseRepo, trainingRepo)
+
 	// Setup scheduler for periodic tasks
 	taskScheduler := scheduler.NewScheduler(cfg, logger)
+	if err := taskScheduler.AddTask(&scheduler.ScheduledTask{
+		ID:          "watchlist_expiry",
+		Name:        "Watchlist Expiry",
+		Description: "Expire watchlist and exclusion entries and lapse unreviewed changes",
+		Schedule:    cfg.Watchlist.ExpirySchedule,
+		Handler:     scheduler.NewWatchlistExpiryHandler(watchlistService, cfg, logger),
+		Enabled:     true,
+	}); err != nil {
+		logger.Error("Failed to schedule watchlist expiry", "error", err)
+		os.Exit(1)
+	}
+
+	// Setup SLO error budget reporting from the Prometheus metrics already scraped
+	var sloNotifier slo.Notifier
+	if cfg.Notifications.Slack.Enabled {
+		sloNotifier = slo.NewSlackNotifier(cfg.Notifications.Slack, cfg.SLO.OpsChannel)
+	}
+	sloService := slo.NewService(cfg, logger,
+		slo.NewPrometheusClient(cfg.SLO.PrometheusURL, cfg.SLO.QueryTimeout), sloNotifier)
+	if cfg.SLO.Enabled {
+		if err := taskScheduler.AddTask(&scheduler.ScheduledTask{
+			ID:          "slo_evaluation",
+			Name:        "SLO Evaluation",
+			Description: "Compute SLO burn rates and warn the ops channel on error budget exhaustion",
+			Schedule:    cfg.SLO.EvaluationSchedule,
+			Handler:     scheduler.NewSLOEvaluationHandler(sloService, cfg, logger),
+			Enabled:     true,
+		}); err != nil {
+			logger.Error("Failed to schedule SLO evaluation", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Setup alert discussion threads; escalation deliveries carry them into linked cases
+	alertThreadService := alertthread.NewService(logger, alertCommentRepo, alertRepo)
+
+	// Setup alert lifecycle; SLA deadlines come from the alert's severity
+	alertLifecycleService := alertlifecycle.NewService(cfg, logger, alertLifecycleRepo, alertRepo)
+
+	// Setup alert auto-close; stale alerts closed by a policy are sampled for review, not fed to rule feedback
+	autoClosePolicies, err := autoclose.NewPolicies(cfg.AutoClose)
+	if err != nil {
+		logger.Error("Invalid alert auto-close policies", "error", err)
+		os.Exit(1)
+	}
+	autoCloseService := autoclose.NewService(cfg, logger, alertAutoCloseRepo, autoClosePolicies)
+	if cfg.AutoClose.Enabled {
+		if err := taskScheduler.AddTask(&scheduler.ScheduledTask{
+			ID:          "alert_auto_close",
+			Name:        "Alert Auto-Close",
+			Description: "Close stale alerts under the auto-close policies",
+			Schedule:    cfg.AutoClose.Schedule,
+			Handler:     scheduler.NewAlertAutoCloseHandler(autoCloseService, cfg, logger),
+			Enabled:     true,
+		}); err != nil {
+			logger.Error("Failed to schedule alert auto-close", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Setup rule feedback; closing an alert as confirmed or false positive records its disposition
+	ruleFeedbackService := rulefeedback.NewService(cfg, logger, ruleFeedbackRepo, alertRepo)
+	alertLifecycleService.SetDispositionRecorder(ruleFeedbackService)
+
+	// Setup severity tuning; proposed rule severities take effect only once approved
+	severityTuningService := severitytune.NewService(cfg, logger, severityTuningRepo, ruleFeedbackRepo, ruleRepo)
+
+	// Setup alert handoffs; receivers are notified through the channel providers
+	alertHandoffService := alerthandoff.NewService(cfg, logger, alertHandoffRepo, alertRepo, notificationDispatcher)
+
+	// Setup rule owner digests; enabling and disabling rules is recorded by a database trigger
+	ruleOwnerService := ruleowner.NewService(cfg, logger, ruleOwnerRepo, ruleRepo, notificationDispatcher)
+	if cfg.RuleOwners.Enabled {
+		if err := taskScheduler.AddTask(&scheduler.ScheduledTask{
+			ID:          "rule_health_check",
+			Name:        "Rule Health Check",
+			Description: "Flag rules over their evaluation error budget or trending towards false positives",
+			Schedule:    cfg.RuleOwners.CheckSchedule,
+			Handler:     scheduler.NewRuleHealthCheckHandler(ruleOwnerService, ruleEngine, cfg, logger),
+			Enabled:     true,
+		}); err != nil {
+			logger.Error("Failed to schedule rule health check", "error", err)
+			os.Exit(1)
+		}
+		if err := taskScheduler.AddTask(&scheduler.ScheduledTask{
+			ID:          "rule_owner_digest",
+			Name:        "Rule Owner Digest",
+			Description: "Send rule owners their digest of rule lifecycle events",
+			Schedule:    cfg.RuleOwners.DigestSchedule,
+			Handler:     scheduler.NewRuleOwnerDigestHandler(ruleOwnerService, cfg, logger),
+			Enabled:     true,
+		}); err != nil {
+			logger.Error("Failed to schedule rule owner digest", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Setup external case management sync; alert lifecycle changes are queued by a database trigger
+	caseSyncService := casesync.NewService(cfg, logger, caseSyncRepo, alertRepo, alertCommentRepo)
+	if cfg.CaseSync.Enabled {
+		if err := taskScheduler.AddTask(&scheduler.ScheduledTask{
+			ID:          "case_sync_dispatch",
+			Name:        "Case Sync Dispatch",
+			Description: "Push queued alert lifecycle changes to external case management systems",
+			Schedule:    cfg.CaseSync.DispatchSchedule,
+			Handler:     scheduler.NewCaseSyncDispatchHandler(caseSyncService, cfg, logger),
+			Enabled:     true,
+		}); err != nil {
+			logger.Error("Failed to schedule case sync dispatch", "error", err)
+			os.Exit(1)
+		}
+		if err := taskScheduler.AddTask(&scheduler.ScheduledTask{
+			ID:          "case_sync_reconcile",
+			Name:        "Case Sync Reconciliation",
+			Description: "Detect and repair status drift between alerts and external cases",
+			Schedule:    cfg.CaseSync.ReconcileSchedule,
+			Handler:     scheduler.NewCaseSyncReconcileHandler(caseSyncService, cfg, logger),
+			Enabled:     true,
+		}); err != nil {
+			logger.Error("Failed to schedule case sync reconciliation", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Setup entity risk tier webhooks; transitions are queued by a database trigger
+	riskWebhookService := riskwebhook.NewService(cfg, logger, riskWebhookRepo)
+	if cfg.RiskWebhooks.Enabled {
+		if err := taskScheduler.AddTask(&scheduler.ScheduledTask{
+			ID:          "risk_webhook_dispatch",
+			Name:        "Risk Webhook Dispatch",
+			Description: "Send queued entity risk tier transitions to webhook subscribers",
+			Schedule:    cfg.RiskWebhooks.DispatchSchedule,
+			Handler:     scheduler.NewRiskWebhookDispatchHandler(riskWebhookService, cfg, logger),
+			Enabled:     true,
+		}); err != nil {
+			logger.Error("Failed to schedule risk webhook dispatch", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Setup end-of-day batch rule evaluation and portfolio digests
+	batchDigestService := batchdigest.NewService(cfg, logger, batchDigestRepo, alertRepo, notificationRepo)
+	if cfg.BatchDigest.Enabled {
+		if err := taskScheduler.AddTask(&scheduler.ScheduledTask{
+			ID:          "batch_digest",
+			Name:        "End-of-Day Batch Digest",
+			Description: "Evaluate batch rules over the day's aggregates and send portfolio digests",
+			Schedule:    cfg.BatchDigest.Schedule,
+			Handler:     scheduler.NewBatchDigestHandler(batchDigestService, cfg, logger),
+			Enabled:     true,
+		}); err != nil {
+			logger.Error("Failed to schedule batch digest", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Setup alert clustering into investigation bundles
+	alertClusterService := alertcluster.NewService(cfg, logger, alertClusterRepo)
+	if cfg.AlertClustering.Enabled {
+		if err := taskScheduler.AddTask(&scheduler.ScheduledTask{
+			ID:          "alert_clustering",
+			Name:        "Alert Clustering",
+			Description: "Group open alerts sharing entities or communities into investigation bundles",
+			Schedule:    cfg.AlertClustering.Schedule,
+			Handler:     scheduler.NewAlertClusterHandler(alertClusterService, cfg, logger),
+			Enabled:     true,
+		}); err != nil {
+			logger.Error("Failed to schedule alert clustering", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Setup pruning of correlation keys too old to match new alerts
+	if cfg.AlertCorrelation.Enabled {
+		if err := taskScheduler.AddTask(&scheduler.ScheduledTask{
+			ID:          "alert_correlation_prune",
+			Name:        "Alert Correlation Key Prune",
+			Description: "Forget the correlation keys of alerts past the key retention",
+			Schedule:    cfg.AlertCorrelation.PruneSchedule,
+			Handler:     scheduler.NewCorrelationPruneHandler(correlationService, cfg, logger),
+			Enabled:     true,
+		}); err != nil {
+			logger.Error("Failed to schedule alert correlation key pruning", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Setup post-storm reconciliation
+	if cfg.Storm.Enabled {
+		if err := taskScheduler.AddTask(&scheduler.ScheduledTask{
+			ID:          "alert_storm_sweep",
+			Name:        "Alert Storm Sweep",
+			Description: "End quiet alert storms and reconcile their held alerts",
+			Schedule:    cfg.Storm.ReconcileSchedule,
+			Handler:     scheduler.NewStormSweepHandler(stormService, cfg, logger),
+			Enabled:     true,
+		}); err != nil {
+			logger.Error("Failed to schedule alert storm sweep", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Setup dead-lettering; events that keep failing are parked instead of stalling the consumer
+	deadLetterPublisher := kafka.NewTopicPublisher(cfg, logger)
+	deadLetterService := deadletter.NewService(cfg, logger, deadLetterRepo, deadLetterPublisher)
+	deadLetterRetrier := deadletter.NewRetrier(deadLetterService.Policy(), deadLetterService)
+
+	// Correlation IDs join this service's log lines to the request or event
+	// that caused them; strict mode rejects internal traffic without one
+	requestContext := reqctx.Options{Service: serviceName, Strict: cfg.Security.StrictRequestContext}
 
 	// Setup Kafka event processor
-	eventProcessor := kafka.NewEventProcessor(cfg, logger, ruleEngine, alertRepo, notificationRepo)
+	kafkaConsumer, err := kafka.NewConsumer(cfg, logger, ruleEngine, alertRepo, notificationRepo, deadLetterRetrier)
+	if err != nil {
+		logger.Error("Failed to create Kafka consumer", "error", err)
+		os.Exit(1)
+	}
+	kafkaProducer, err := kafka.NewProducer(cfg, logger)
+	if err != nil {
+		logger.Error("Failed to create Kafka producer", "error", err)
+		os.Exit(1)
+	}
+	kafkaConsumer.SetRequestContext(requestContext)
+	kafkaProducer.SetRequestContext(requestContext)
+	eventProcessor := kafka.NewEventProcessor(cfg, logger, kafkaConsumer, kafkaProducer, ruleEngine, alertRepo, notificationRepo)
 
 	// Setup metrics collector
 	metricsCollector := metrics.NewCollector(
@@ -105,8 +490,8 @@ func main() {
 
 	// Setup gRPC server
 	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(grpcInterceptors.UnaryServerInterceptor()),
-		grpc.StreamInterceptor(grpcInterceptors.StreamServerInterceptor()),
+		grpc.ChainUnaryInterceptor(reqctx.UnaryServerInterceptor(requestContext), grpcInterceptors.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(reqctx.StreamServerInterceptor(requestContext), grpcInterceptors.StreamServerInterceptor()),
 	)
 
 	// Register gRPC service
@@ -122,6 +507,9 @@ func main() {
 		eventProcessor,
 		taskScheduler,
 	)
+	alertingGRPCServer.SetSuppressionService(dedupService)
+	alertingGRPCServer.SetCorrelationService(correlationService)
+	alertingGRPCServer.SetAlertStreamService(alertstream.NewService(cfg, logger, alertRepo))
 	alertingpb.RegisterAlertingEngineServer(grpcServer, alertingGRPCServer)
 
 	// Enable gRPC reflection for development
@@ -145,9 +533,47 @@ func main() {
 
 	// Setup HTTP router
 	httpRouter := mux.NewRouter()
+	httpRouter.Use(reqctx.Middleware(requestContext))
+	if cfg.Security.EnableAuthentication {
+		rbacPolicy, err := rbac.LoadPolicyFile(cfg.Security.RBACPolicyFile)
+		if err != nil {
+			logger.Error("Failed to load RBAC policy", "error", err)
+			os.Exit(1)
+		}
+		evaluator := rbac.NewEvaluator(rbacPolicy, cfg.Security.RBACCacheTTL, cfg.Security.RBACCacheMaxEntries)
+		httpRouter.Use(handlers.AuthorizationMiddleware(logger, evaluator, []byte(cfg.Security.JWTSecret)))
+	}
 	httpHandlers.RegisterRoutes(httpRouter)
-
-	// Add Prometheus metrics endpoint
+	handlers.NewTrainingHandler(logger, trainingSimulator).RegisterRoutes(httpRouter)
+	handlers.NewWatchlistHandler(logger, watchlistService).RegisterRoutes(httpRouter)
+	handlers.NewAuditHandler(logger, auditRepo).RegisterRoutes(httpRouter)
+	handlers.NewEvidenceHandler(logger, evidenceRepo).RegisterRoutes(httpRouter)
+	handlers.NewAlertCommentHandler(logger, alertThreadService).RegisterRoutes(httpRouter)
+	handlers.NewSLOHandler(logger, sloService).RegisterRoutes(httpRouter)
+	handlers.NewCaseSyncHandler(logger, caseSyncService, cfg.CaseSync.CallbackMaxBody).RegisterRoutes(httpRouter)
+	handlers.NewBatchDigestHandler(logger, batchDigestService).RegisterRoutes(httpRouter)
+	handlers.NewAlertClusterHandler(logger, alertClusterService).RegisterRoutes(httpRouter)
+	handlers.NewAlertCaseHandler(logger, correlationService).RegisterRoutes(httpRouter)
+	handlers.NewRulePackHandler(logger, rulePackService, cfg.RulePack.MaxPackSize).RegisterRoutes(httpRouter)
+	handlers.NewRuleVersionHandler(logger, ruleVersionService).RegisterRoutes(httpRouter)
+	handlers.NewStormHandler(logger, stormService).RegisterRoutes(httpRouter)
+	handlers.NewDeadLetterHandler(logger, deadLetterService).RegisterRoutes(httpRouter)
+	handlers.NewReferenceDataHandler(logger, rulePackRepo, referenceCache).RegisterRoutes(httpRouter)
+	handlers.NewRiskWebhookHandler(logger, riskWebhookService).RegisterRoutes(httpRouter)
+	handlers.NewBacktestHandler(logger, backtestService).RegisterRoutes(httpRouter)
+	handlers.NewSuppressionHandler(logger, dedupService).RegisterRoutes(httpRouter)
+	handlers.NewAlertLifecycleHandler(logger, alertLifecycleService).RegisterRoutes(httpRouter)
+	handlers.NewAlertAutoCloseHandler(logger, autoCloseService).RegisterRoutes(httpRouter)
+	handlers.NewAlertHandoffHandler(logger, alertHandoffService).RegisterRoutes(httpRouter)
+	handlers.NewRuleFeedbackHandler(logger, ruleFeedbackService).RegisterRoutes(httpRouter)
+	handlers.NewRuleOwnerHandler(logger, ruleOwnerService).RegisterRoutes(httpRouter)
+	handlers.NewSeverityTuningHandler(logger, severityTuningService).RegisterRoutes(httpRouter)
+	handlers.NewRuleFixtureHandler(logger, ruleRepo, ruleEngine).RegisterRoutes(httpRouter)
+	handlers.NewNotificationTemplateHandler(logger, notificationTemplateService).RegisterRoutes(httpRouter)
+	handlers.NewNotificationRoutingHandler(logger, notificationDispatcher).RegisterRoutes(httpRouter)
+
+	// Add readiness and Prometheus metrics endpoints
+	httpRouter.Handle("/ready", seq.Readiness()).Methods("GET")
 	httpRouter.Handle("/metrics", promhttp.Handler())
 
 	// Setup HTTP server
@@ -159,84 +585,79 @@ func main() {
 		IdleTimeout:  120 * time.Second,
 	}
 
-	// Start services
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Start metrics collector
+	seq.Go(ctx, startup.Component{Name: "metrics-collector", Phase: startup.PhaseConsumers}, metricsCollector.Start)
 
-	var wg sync.WaitGroup
+	// Start reference data cache sync
+	seq.Go(ctx, startup.Component{Name: "reference-cache", Phase: startup.PhaseConsumers}, referenceCache.Run)
 
-	// Start metrics collector
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := metricsCollector.Start(ctx); err != nil && err != context.Canceled {
-			logger.Error("Metrics collector failed", "error", err)
-			cancel()
-		}
-	}()
+	// Start rule window checkpoints
+	if memoryWindows != nil {
+		seq.Go(ctx, startup.Component{Name: "rule-windows", Phase: startup.PhaseConsumers}, memoryWindows.Run)
+	}
+
+	// Start rule backtest runner
+	seq.Go(ctx, startup.Component{Name: "rule-backtests", Phase: startup.PhaseConsumers}, backtestService.Run)
 
 	// Start event processor
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := eventProcessor.Start(ctx); err != nil && err != context.Canceled {
-			logger.Error("Event processor failed", "error", err)
-			cancel()
-		}
-	}()
+	seq.Go(ctx, startup.Component{Name: "event-processor", Phase: startup.PhaseConsumers, Critical: true}, eventProcessor.Start)
 
 	// Start scheduler
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := taskScheduler.Start(ctx); err != nil && err != context.Canceled {
-			logger.Error("Scheduler failed", "error", err)
-			cancel()
-		}
-	}()
+	seq.Go(ctx, startup.Component{Name: "scheduler", Phase: startup.PhaseConsumers, Critical: true}, taskScheduler.Start)
 
 	// Start gRPC server
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.GRPCPort))
-		if err != nil {
-			logger.Error("Failed to listen on gRPC port", "port", cfg.Server.GRPCPort, "error", err)
-			cancel()
-			return
-		}
+	grpcComponent := startup.Component{Name: "grpc-server", Phase: startup.PhaseServers, Critical: true}
+	var grpcListener net.Listener
+	if err := seq.Step(ctx, grpcComponent, func(ctx context.Context) error {
+		var err error
+		grpcListener, err = net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.GRPCPort))
+		return err
+	}); err != nil {
+		logger.Error("Failed to listen on gRPC port", "port", cfg.Server.GRPCPort, "error", err)
+		os.Exit(1)
+	}
 
+	seq.Go(ctx, grpcComponent, func(ctx context.Context) error {
 		logger.Info("Starting gRPC server", "port", cfg.Server.GRPCPort)
-		if err := grpcServer.Serve(listener); err != nil && err != grpc.ErrServerStopped {
-			logger.Error("gRPC server failed", "error", err)
-			cancel()
+		if err := grpcServer.Serve(grpcListener); err != nil && err != grpc.ErrServerStopped {
+			return err
 		}
-	}()
+		return nil
+	})
 
 	// Start HTTP server
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
+	httpComponent := startup.Component{Name: "http-server", Phase: startup.PhaseServers, Critical: true}
+	var httpListener net.Listener
+	if err := seq.Step(ctx, httpComponent, func(ctx context.Context) error {
+		var err error
+		httpListener, err = net.Listen("tcp", httpServer.Addr)
+		return err
+	}); err != nil {
+		logger.Error("Failed to listen on HTTP port", "port", cfg.Server.HTTPPort, "error", err)
+		os.Exit(1)
+	}
+
+	seq.Go(ctx, httpComponent, func(ctx context.Context) error {
 		logger.Info("Starting HTTP server", "port", cfg.Server.HTTPPort)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error("HTTP server failed", "error", err)
-			cancel()
+		if err := httpServer.Serve(httpListener); err != nil && err != http.ErrServerClosed {
+			return err
 		}
-	}()
+		return nil
+	})
 
-	// Setup graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	seq.Complete()
 
+	// Wait for a shutdown signal or a critical component to stop
 	select {
-	case sig := <-sigChan:
-		logger.Info("Received shutdown signal", "signal", sig)
 	case <-ctx.Done():
-		logger.Info("Context cancelled, shutting down")
+		logger.Info("Received shutdown signal")
+	case <-seq.Done():
+		logger.Error("Critical component stopped, shutting down", "error", seq.Err())
 	}
 
 	// Graceful shutdown
 	logger.Info("Shutting down services...")
+	seq.Readiness().Drain()
 
 	// Cancel context to stop all services
 	cancel()
@@ -252,8 +673,12 @@ func main() {
 	// Stop gRPC server
 	grpcServer.GracefulStop()
 
-	// Wait for all goroutines to finish
-	wg.Wait()
+	// Wait for all services to finish
+	seq.Wait()
+
+	if err := deadLetterPublisher.Close(); err != nil {
+		logger.Error("Failed to close dead letter publisher", "error", err)
+	}
 
 	logger.Info("Service shutdown complete")
 }
@@ -277,7 +702,8 @@ func setupLogging(cfg *config.Config) *slog.Logger {
 		handler = slog.NewTextHandler(os.Stdout, handlerOptions)
 	}
 
-	logger := slog.New(handler)
+	// Lines logged with a request's context carry its correlation ID
+	logger := slog.New(reqctx.NewHandler(handler))
 	logger = logger.With(
 		"service", serviceName,
 		"version", version,
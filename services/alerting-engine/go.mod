@@ -50,9 +50,11 @@ require (
 	// JSON and data processing
 	github.com/tidwall/gjson v1.15.0
 	github.com/itchyny/gojq v0.12.12
+	gopkg.in/yaml.v3 v3.0.1
 
 	// Rule engine
 	github.com/hyperjumptech/grule-rule-engine v1.15.0
+	github.com/google/cel-go v0.17.8
 
 	// Scheduler
 	github.com/robfig/cron/v3 v3.0.1
@@ -63,6 +65,8 @@ require (
 	// UUID generation
 	github.com/google/uuid v1.3.1
 
+	github.com/golang-jwt/jwt/v5 v5.2.0
+
 	// Encryption
 	golang.org/x/crypto v0.13.0
 
@@ -101,7 +105,6 @@ require (
 	golang.org/x/sys v0.12.0 // indirect
 	golang.org/x/text v0.13.0 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 // Local module replacements for development
@@ -0,0 +1,218 @@
+package alertcluster
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// Cluster is a group of open alerts linked through shared resolved entities
+// or graph communities
+type Cluster struct {
+	Key             string   `json:"cluster_key"`
+	AlertIDs        []string `json:"alert_ids"`
+	SharedEntityIDs []string `json:"shared_entity_ids"`
+	CommunityIDs    []string `json:"community_ids"`
+	MaxSeverity     string   `json:"max_severity"`
+	Title           string   `json:"title"`
+}
+
+// Options bounds the clusters Build returns
+type Options struct {
+	MinSize int
+	MaxSize int
+}
+
+// Build groups alerts that share a resolved entity or a graph community,
+// directly or through other alerts. Raw entity ids without a stored context
+// stand for themselves. Clusters smaller than MinSize are dropped; clusters
+// larger than MaxSize keep their most severe and most recent alerts, leaving
+// the rest to a later run. Clusters are ordered by severity, then size.
+func Build(candidates []*database.ClusterCandidate, contexts map[string]*database.EntityContext, opts Options) []*Cluster {
+	if opts.MinSize < 2 {
+		opts.MinSize = 2
+	}
+
+	// Union alerts through the entity and community keys they carry
+	parent := make([]int, len(candidates))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	owners := make(map[string]int)
+	alertKeys := make([][]string, len(candidates))
+	for i, candidate := range candidates {
+		alertKeys[i] = linkKeys(candidate, contexts)
+		for _, key := range alertKeys[i] {
+			if owner, exists := owners[key]; exists {
+				parent[find(i)] = find(owner)
+			} else {
+				owners[key] = i
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i := range candidates {
+		root := find(i)
+		groups[root] = append(groups[root], i)
+	}
+
+	var clusters []*Cluster
+	for _, members := range groups {
+		if len(members) < opts.MinSize {
+			continue
+		}
+
+		if opts.MaxSize > 0 && len(members) > opts.MaxSize {
+			sort.SliceStable(members, func(a, b int) bool {
+				ca, cb := candidates[members[a]], candidates[members[b]]
+				if severityRank(ca.Severity) != severityRank(cb.Severity) {
+					return severityRank(ca.Severity) > severityRank(cb.Severity)
+				}
+				return ca.CreatedAt.After(cb.CreatedAt)
+			})
+			members = members[:opts.MaxSize]
+		}
+
+		clusters = append(clusters, newCluster(candidates, alertKeys, members))
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if severityRank(clusters[i].MaxSeverity) != severityRank(clusters[j].MaxSeverity) {
+			return severityRank(clusters[i].MaxSeverity) > severityRank(clusters[j].MaxSeverity)
+		}
+		if len(clusters[i].AlertIDs) != len(clusters[j].AlertIDs) {
+			return len(clusters[i].AlertIDs) > len(clusters[j].AlertIDs)
+		}
+		return clusters[i].Key < clusters[j].Key
+	})
+
+	return clusters
+}
+
+// Key identifies a cluster by its alerts, so an unchanged cluster keeps its
+// proposal across runs and a dismissed one is not proposed again
+func Key(alertIDs []string) string {
+	sorted := append([]string(nil), alertIDs...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+func newCluster(candidates []*database.ClusterCandidate, alertKeys [][]string, members []int) *Cluster {
+	cluster := &Cluster{}
+
+	// Keys carried by two or more alerts are what tie the cluster together
+	counts := make(map[string]int)
+	for _, i := range members {
+		candidate := candidates[i]
+		cluster.AlertIDs = append(cluster.AlertIDs, candidate.ID)
+		if severityRank(candidate.Severity) > severityRank(cluster.MaxSeverity) {
+			cluster.MaxSeverity = candidate.Severity
+		}
+		for _, key := range alertKeys[i] {
+			counts[key]++
+		}
+	}
+
+	for key, count := range counts {
+		if count < 2 {
+			continue
+		}
+		if id, ok := strings.CutPrefix(key, entityKeyPrefix); ok {
+			cluster.SharedEntityIDs = append(cluster.SharedEntityIDs, id)
+		} else if id, ok := strings.CutPrefix(key, communityKeyPrefix); ok {
+			cluster.CommunityIDs = append(cluster.CommunityIDs, id)
+		}
+	}
+
+	sort.Strings(cluster.AlertIDs)
+	sort.Strings(cluster.SharedEntityIDs)
+	sort.Strings(cluster.CommunityIDs)
+	cluster.Key = Key(cluster.AlertIDs)
+	cluster.Title = title(cluster)
+
+	return cluster
+}
+
+const (
+	entityKeyPrefix    = "entity:"
+	communityKeyPrefix = "community:"
+)
+
+// linkKeys returns the resolved entity and community keys an alert carries
+func linkKeys(candidate *database.ClusterCandidate, contexts map[string]*database.EntityContext) []string {
+	var keys []string
+	for _, entityID := range candidate.EntityIDs {
+		if entityID == "" {
+			continue
+		}
+
+		resolved := entityID
+		if context, exists := contexts[entityID]; exists {
+			if context.ResolvedEntityID != nil && *context.ResolvedEntityID != "" {
+				resolved = *context.ResolvedEntityID
+			}
+			if context.CommunityID != nil && *context.CommunityID != "" {
+				keys = appendUnique(keys, communityKeyPrefix+*context.CommunityID)
+			}
+		}
+		keys = appendUnique(keys, entityKeyPrefix+resolved)
+	}
+	return keys
+}
+
+func title(cluster *Cluster) string {
+	switch {
+	case len(cluster.SharedEntityIDs) == 1:
+		return fmt.Sprintf("%d related alerts involving entity %s", len(cluster.AlertIDs), cluster.SharedEntityIDs[0])
+	case len(cluster.SharedEntityIDs) > 1:
+		return fmt.Sprintf("%d related alerts involving %d shared entities", len(cluster.AlertIDs), len(cluster.SharedEntityIDs))
+	case len(cluster.CommunityIDs) == 1:
+		return fmt.Sprintf("%d related alerts in network community %s", len(cluster.AlertIDs), cluster.CommunityIDs[0])
+	default:
+		return fmt.Sprintf("%d related alerts across %d network communities", len(cluster.AlertIDs), len(cluster.CommunityIDs))
+	}
+}
+
+func appendUnique(values []string, value string) []string {
+	if contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	}
+	return 0
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,431 @@
+package alertcluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegisshield/shared/reqctx"
+)
+
+// Service groups open alerts that share resolved entities or graph
+// communities into proposed investigation bundles, and opens one case per
+// accepted bundle with all of its alerts linked
+type Service struct {
+	config *config.Config
+	logger *slog.Logger
+	repo   *database.AlertClusterRepository
+	client *http.Client
+}
+
+// RunResult summarizes a clustering run
+type RunResult struct {
+	*database.AlertClusterRun
+	Bundles []*database.InvestigationBundle `json:"bundles"`
+}
+
+// AcceptInput describes how an accepted bundle becomes a case. Without a
+// CaseID a new investigation is created from the bundle; with one, the
+// bundle's alerts are linked to that existing case.
+type AcceptInput struct {
+	Actor    string `json:"actor"`
+	CaseID   string `json:"case_id,omitempty"`
+	Title    string `json:"title,omitempty"`
+	CaseType string `json:"case_type,omitempty"`
+	Priority string `json:"priority,omitempty"`
+}
+
+// DismissInput describes why a bundle was rejected
+type DismissInput struct {
+	Actor  string `json:"actor"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// AcceptResult is an accepted bundle together with the case it was linked to
+type AcceptResult struct {
+	Bundle      *database.InvestigationBundle `json:"bundle"`
+	CaseID      string                        `json:"case_id"`
+	CaseCreated bool                          `json:"case_created"`
+}
+
+// NewService creates a new alert clustering service
+func NewService(cfg *config.Config, logger *slog.Logger, repo *database.AlertClusterRepository) *Service {
+	return &Service{
+		config: cfg,
+		logger: logger,
+		repo:   repo,
+		client: &http.Client{Timeout: cfg.AlertClustering.CaseAPITimeout, Transport: reqctx.NewTransport("alerting-engine", nil)},
+	}
+}
+
+// Entity contexts
+
+// IngestEntityContexts loads resolved entities and graph communities for raw
+// entity ids from entity resolution and graph analytics
+func (s *Service) IngestEntityContexts(ctx context.Context, contexts []*database.EntityContext) error {
+	if len(contexts) == 0 {
+		return fmt.Errorf("no entity contexts provided")
+	}
+	if max := s.config.AlertClustering.MaxContextBatch; max > 0 && len(contexts) > max {
+		return fmt.Errorf("at most %d entity contexts may be loaded at once", max)
+	}
+
+	for i, entity := range contexts {
+		if strings.TrimSpace(entity.EntityID) == "" {
+			return fmt.Errorf("entity context %d: entity_id is required", i)
+		}
+		if entity.ResolvedEntityID == nil && entity.CommunityID == nil {
+			return fmt.Errorf("entity context %d: resolved_entity_id or community_id is required", i)
+		}
+	}
+
+	return s.repo.UpsertEntityContexts(ctx, contexts)
+}
+
+// Runs
+
+// Run clusters open alerts not yet covered by a case and proposes a bundle
+// for every new cluster. Unchanged clusters keep their open proposal,
+// dismissed clusters are not proposed again and proposals whose alerts have
+// since regrouped are superseded.
+func (s *Service) Run(ctx context.Context, triggeredBy string) (*RunResult, error) {
+	run := &database.AlertClusterRun{
+		ID:          generateID("cluster_run"),
+		Status:      database.ClusterRunStatusRunning,
+		TriggeredBy: defaultString(triggeredBy, "system"),
+		StartedAt:   time.Now(),
+	}
+	if err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	result := &RunResult{AlertClusterRun: run, Bundles: []*database.InvestigationBundle{}}
+	if err := s.run(ctx, result); err != nil {
+		run.ErrorMessage = optionalString(err.Error())
+	}
+
+	completedAt := time.Now()
+	run.CompletedAt = &completedAt
+	run.Status = database.ClusterRunStatusCompleted
+	if run.ErrorMessage != nil {
+		run.Status = database.ClusterRunStatusFailed
+	}
+
+	if err := s.repo.CompleteRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Alert clustering run completed",
+		"run_id", run.ID,
+		"status", run.Status,
+		"alerts_scanned", run.AlertsScanned,
+		"clusters_found", run.ClustersFound,
+		"bundles_proposed", run.BundlesProposed,
+		"bundles_superseded", run.BundlesSuperseded)
+
+	return result, nil
+}
+
+func (s *Service) run(ctx context.Context, result *RunResult) error {
+	cfg := s.config.AlertClustering
+
+	limit := cfg.MaxAlertsPerRun
+	if limit <= 0 {
+		limit = 5000
+	}
+
+	candidates, err := s.repo.ListClusterCandidates(ctx, time.Now().Add(-cfg.Lookback), limit)
+	if err != nil {
+		return err
+	}
+	result.AlertsScanned = len(candidates)
+
+	var entityIDs []string
+	for _, candidate := range candidates {
+		entityIDs = append(entityIDs, candidate.EntityIDs...)
+	}
+
+	contexts := make(map[string]*database.EntityContext)
+	if len(entityIDs) > 0 {
+		stored, err := s.repo.ListEntityContexts(ctx, dedupe(entityIDs))
+		if err != nil {
+			return err
+		}
+		for _, entity := range stored {
+			contexts[entity.EntityID] = entity
+		}
+	}
+
+	clusters := Build(candidates, contexts, Options{MinSize: cfg.MinClusterSize, MaxSize: cfg.MaxBundleSize})
+	result.ClustersFound = len(clusters)
+
+	dismissed, err := s.repo.ListBundleKeys(ctx, []string{database.BundleStatusDismissed})
+	if err != nil {
+		return err
+	}
+
+	currentKeys := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		currentKeys = append(currentKeys, cluster.Key)
+		if _, exists := dismissed[cluster.Key]; exists {
+			continue
+		}
+
+		bundle := &database.InvestigationBundle{
+			ID:              generateID("bundle"),
+			RunID:           &result.ID,
+			ClusterKey:      cluster.Key,
+			Status:          database.BundleStatusProposed,
+			Title:           cluster.Title,
+			AlertIDs:        cluster.AlertIDs,
+			SharedEntityIDs: cluster.SharedEntityIDs,
+			CommunityIDs:    cluster.CommunityIDs,
+			MaxSeverity:     cluster.MaxSeverity,
+		}
+		created, err := s.repo.CreateBundle(ctx, bundle)
+		if err != nil {
+			return err
+		}
+		if created {
+			result.BundlesProposed++
+			result.Bundles = append(result.Bundles, bundle)
+		}
+	}
+
+	superseded, err := s.repo.SupersedeBundles(ctx, currentKeys)
+	if err != nil {
+		return err
+	}
+	result.BundlesSuperseded = superseded
+
+	return nil
+}
+
+// ListRuns returns recent clustering runs
+func (s *Service) ListRuns(ctx context.Context, limit int) ([]*database.AlertClusterRun, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	return s.repo.ListRuns(ctx, limit)
+}
+
+// Bundles
+
+// ListBundles returns bundles, optionally in one status
+func (s *Service) ListBundles(ctx context.Context, status string, limit int) ([]*database.InvestigationBundle, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	return s.repo.ListBundles(ctx, status, limit)
+}
+
+// GetBundle returns a bundle by ID
+func (s *Service) GetBundle(ctx context.Context, id string) (*database.InvestigationBundle, error) {
+	return s.repo.GetBundle(ctx, id)
+}
+
+// ValidateAcceptInput checks an accept request before any case is created
+func ValidateAcceptInput(input AcceptInput) error {
+	if strings.TrimSpace(input.Actor) == "" {
+		return fmt.Errorf("actor is required")
+	}
+
+	switch input.CaseType {
+	case "", "fraud", "money_laundering", "sanctions", "kyc", "other":
+	default:
+		return fmt.Errorf("unsupported case_type %q", input.CaseType)
+	}
+
+	switch input.Priority {
+	case "", "low", "medium", "high", "critical":
+	default:
+		return fmt.Errorf("unsupported priority %q", input.Priority)
+	}
+
+	return nil
+}
+
+// AcceptBundle opens a case for a proposed bundle, or uses the given
+// existing case, and links every alert in the bundle to it in one action
+func (s *Service) AcceptBundle(ctx context.Context, id string, input AcceptInput) (*AcceptResult, error) {
+	if err := ValidateAcceptInput(input); err != nil {
+		return nil, err
+	}
+
+	bundle, err := s.repo.GetBundle(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Status != database.BundleStatusProposed {
+		return nil, database.ErrBundleNotProposed
+	}
+
+	caseID := strings.TrimSpace(input.CaseID)
+	created := false
+	if caseID == "" {
+		caseID, err = s.createCase(ctx, bundle, input)
+		if err != nil {
+			return nil, err
+		}
+		created = true
+	}
+
+	accepted, err := s.repo.AcceptBundle(ctx, id, caseID, input.Actor)
+	if err != nil {
+		if created {
+			// The case exists but no alerts were linked; leave it for the analyst to reuse or close
+			s.logger.Warn("Investigation case created for a bundle that could not be accepted",
+				"bundle_id", id,
+				"case_id", caseID,
+				"error", err)
+		}
+		return nil, err
+	}
+
+	return &AcceptResult{Bundle: accepted, CaseID: caseID, CaseCreated: created}, nil
+}
+
+// DismissBundle rejects a proposed bundle so its cluster is not proposed again
+func (s *Service) DismissBundle(ctx context.Context, id string, input DismissInput) (*database.InvestigationBundle, error) {
+	if strings.TrimSpace(input.Actor) == "" {
+		return nil, fmt.Errorf("actor is required")
+	}
+
+	if _, err := s.repo.GetBundle(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.DismissBundle(ctx, id, input.Actor, truncate(input.Reason, 2000))
+}
+
+// ListAlertCases returns the cases covering an alert
+func (s *Service) ListAlertCases(ctx context.Context, alertID string) ([]*database.AlertCaseLink, error) {
+	return s.repo.ListAlertCaseLinks(ctx, alertID)
+}
+
+// createCase opens an investigation for a bundle in the investigation toolkit
+func (s *Service) createCase(ctx context.Context, bundle *database.InvestigationBundle, input AcceptInput) (string, error) {
+	endpoint := s.config.AlertClustering.CaseAPIURL
+	if endpoint == "" {
+		return "", fmt.Errorf("case_id is required when no case API is configured")
+	}
+
+	payload := map[string]interface{}{
+		"title":       defaultString(strings.TrimSpace(input.Title), bundle.Title),
+		"description": describeBundle(bundle),
+		"case_type":   defaultString(input.CaseType, s.config.AlertClustering.DefaultCaseType),
+		"priority":    defaultString(input.Priority, casePriority(bundle.MaxSeverity)),
+		"tags":        []string{"alert-bundle"},
+		"metadata": map[string]interface{}{
+			"bundle_id":         bundle.ID,
+			"alert_ids":         bundle.AlertIDs,
+			"shared_entity_ids": bundle.SharedEntityIDs,
+			"community_ids":     bundle.CommunityIDs,
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal case request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create case request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", input.Actor)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to create investigation case: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("investigation case API returned %d: %s", resp.StatusCode, truncate(string(respBody), 500))
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(respBody, &created); err != nil || created.ID == "" {
+		return "", errors.New("investigation case API response did not include a case id")
+	}
+
+	s.logger.Info("Investigation case created for bundle",
+		"bundle_id", bundle.ID,
+		"case_id", created.ID,
+		"alerts", len(bundle.AlertIDs))
+	return created.ID, nil
+}
+
+func describeBundle(bundle *database.InvestigationBundle) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Opened from investigation bundle %s covering %d related alerts (highest severity %s).",
+		bundle.ID, len(bundle.AlertIDs), bundle.MaxSeverity)
+	if len(bundle.SharedEntityIDs) > 0 {
+		fmt.Fprintf(&b, "\nShared entities: %s", strings.Join(bundle.SharedEntityIDs, ", "))
+	}
+	if len(bundle.CommunityIDs) > 0 {
+		fmt.Fprintf(&b, "\nNetwork communities: %s", strings.Join(bundle.CommunityIDs, ", "))
+	}
+	return b.String()
+}
+
+// casePriority maps the most severe alert in a bundle to a case priority
+func casePriority(severity string) string {
+	switch severity {
+	case "critical", "high", "medium", "low":
+		return severity
+	}
+	return "medium"
+}
+
+func dedupe(values []string) []string {
+	result := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" && !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func defaultString(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
@@ -0,0 +1,133 @@
+package alerthandoff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/alertlifecycle"
+	"github.com/aegis-shield/services/alerting-engine/internal/alertthread"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// maxTaskLength bounds the description of a single pending task
+const maxTaskLength = 500
+
+var (
+	// ErrInvalidHandoff is returned when a handoff request fails validation
+	ErrInvalidHandoff = errors.New("invalid alert handoff")
+	// ErrNoOwner is returned when handing off an alert nobody owns
+	ErrNoOwner = errors.New("alert has no owner to hand off from; assign it instead")
+	// ErrNotReceiver is returned when someone other than the receiver acts on a handoff
+	ErrNotReceiver = errors.New("only the receiver can act on a handoff")
+)
+
+// Input describes a handoff. The note and pending tasks travel with the alert
+// to the receiver; NotifyRecipient overrides where the receiver is notified,
+// which defaults to their user ID.
+type Input struct {
+	ToUser          string   `json:"to_user"`
+	Note            string   `json:"note"`
+	PendingTasks    []string `json:"pending_tasks"`
+	NotifyRecipient string   `json:"notify_recipient,omitempty"`
+}
+
+// Validate checks a handoff request before the alert is loaded
+func Validate(input Input, maxTasks int) error {
+	if strings.TrimSpace(input.ToUser) == "" {
+		return fmt.Errorf("%w: to_user is required", ErrInvalidHandoff)
+	}
+	if strings.TrimSpace(input.Note) == "" {
+		return fmt.Errorf("%w: a handoff note is required", ErrInvalidHandoff)
+	}
+	if len(input.Note) > alertthread.MaxContentLength {
+		return fmt.Errorf("%w: note exceeds %d characters", ErrInvalidHandoff, alertthread.MaxContentLength)
+	}
+	if maxTasks > 0 && len(input.PendingTasks) > maxTasks {
+		return fmt.Errorf("%w: at most %d pending tasks can be handed off", ErrInvalidHandoff, maxTasks)
+	}
+	for i, task := range input.PendingTasks {
+		if strings.TrimSpace(task) == "" {
+			return fmt.Errorf("%w: pending task %d is empty", ErrInvalidHandoff, i)
+		}
+		if len(task) > maxTaskLength {
+			return fmt.Errorf("%w: pending task %d exceeds %d characters", ErrInvalidHandoff, i, maxTaskLength)
+		}
+	}
+	return nil
+}
+
+// TargetStatus returns the status an alert holds once handed off. Escalated
+// alerts stay escalated under their new owner; other open alerts become
+// assigned. Closed alerts cannot be handed off.
+func TargetStatus(status string) (string, error) {
+	if status == database.AlertStatusEscalated {
+		return status, nil
+	}
+	if err := alertlifecycle.ValidateTransition(status, database.AlertStatusAssigned); err != nil {
+		return "", err
+	}
+	return database.AlertStatusAssigned, nil
+}
+
+// SLAExtension returns the time added to an alert's SLA deadline on handoff
+// so the receiver has at least minReceiverTime left to work it. Alerts
+// without a deadline or already past it are not extended, so a breach
+// stands, and the extensions of an alert never add up to more than
+// maxExtension. It is truncated to whole seconds.
+func SLAExtension(dueAt *time.Time, now time.Time, minReceiverTime, maxExtension time.Duration, extendedSeconds int) time.Duration {
+	if dueAt == nil || minReceiverTime <= 0 || !now.Before(*dueAt) {
+		return 0
+	}
+
+	remaining := dueAt.Sub(now)
+	if remaining >= minReceiverTime {
+		return 0
+	}
+
+	extension := minReceiverTime - remaining
+	if maxExtension > 0 {
+		if room := maxExtension - time.Duration(extendedSeconds)*time.Second; extension > room {
+			extension = room
+		}
+	}
+	if extension <= 0 {
+		return 0
+	}
+	return extension.Truncate(time.Second)
+}
+
+// NewTasks turns the pending task descriptions of a handoff into open tasks
+func NewTasks(handoffID string, descriptions []string) database.HandoffTaskList {
+	tasks := make(database.HandoffTaskList, 0, len(descriptions))
+	for i, description := range descriptions {
+		tasks = append(tasks, database.HandoffTask{
+			ID:          fmt.Sprintf("%s_task_%d", handoffID, i+1),
+			Description: strings.TrimSpace(description),
+		})
+	}
+	return tasks
+}
+
+// FormatNote renders the thread comment and notification text of a handoff.
+// The receiver is mentioned so the thread flags the handoff to them.
+func FormatNote(handoff *database.AlertHandoff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Handed off from @%s to @%s\n\n%s", handoff.FromUser, handoff.ToUser, strings.TrimSpace(handoff.Note))
+
+	if len(handoff.PendingTasks) > 0 {
+		b.WriteString("\n\nPending tasks:")
+		for _, task := range handoff.PendingTasks {
+			fmt.Fprintf(&b, "\n- %s", task.Description)
+		}
+	}
+
+	if handoff.SLADueAtAfter != nil {
+		fmt.Fprintf(&b, "\n\nSLA due %s", handoff.SLADueAtAfter.UTC().Format(time.RFC3339))
+		if handoff.SLAExtensionSeconds > 0 {
+			fmt.Fprintf(&b, " (extended by %s for the handoff)", time.Duration(handoff.SLAExtensionSeconds)*time.Second)
+		}
+	}
+	return b.String()
+}
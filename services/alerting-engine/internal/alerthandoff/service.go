@@ -0,0 +1,274 @@
+package alerthandoff
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/alertlifecycle"
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/notification"
+)
+
+var (
+	alertHandoffs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "alerting_engine_alert_handoffs_total",
+		Help: "Alert handoffs, by whether the SLA deadline was extended",
+	}, []string{"sla_extended"})
+
+	alertHandoffAcceptSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "alerting_engine_alert_handoff_accept_seconds",
+		Help:    "Time from an alert handoff to its acceptance by the receiver",
+		Buckets: []float64{60, 300, 900, 1800, 3600, 7200, 14400, 28800, 86400},
+	})
+)
+
+// ErrAlertNotFound is returned when handing off an alert that does not exist
+var ErrAlertNotFound = errors.New("alert not found")
+
+// Notifier delivers the handoff notification to the receiver
+type Notifier interface {
+	Send(ctx context.Context, channel string, message *notification.Message, routeID *string) error
+}
+
+// Result is a handoff together with the alert as handed off
+type Result struct {
+	Handoff *database.AlertHandoff     `json:"handoff"`
+	Alert   *alertlifecycle.AlertState `json:"alert"`
+}
+
+// Service hands alerts over between analysts with the context the receiver
+// needs, and keeps their SLA clocks fair to the receiver
+type Service struct {
+	config    *config.Config
+	logger    *slog.Logger
+	repo      *database.AlertHandoffRepository
+	alertRepo *database.AlertRepository
+	notifier  Notifier
+	targets   alertlifecycle.SLATargets
+}
+
+// NewService creates a new alert handoff service. A nil notifier records
+// handoffs without notifying the receiver.
+func NewService(cfg *config.Config, logger *slog.Logger, repo *database.AlertHandoffRepository, alertRepo *database.AlertRepository, notifier Notifier) *Service {
+	return &Service{
+		config:    cfg,
+		logger:    logger,
+		repo:      repo,
+		alertRepo: alertRepo,
+		notifier:  notifier,
+		targets:   alertlifecycle.NewSLATargets(cfg.AlertLifecycle.SLATargets),
+	}
+}
+
+// Handoff transfers an open alert from its owner to another analyst. The
+// handoff note and pending tasks are recorded with the handoff and posted to
+// the alert's thread, the receiver is notified, and the SLA deadline is moved
+// out when the receiver would otherwise be left too little time.
+func (s *Service) Handoff(ctx context.Context, alertID string, input Input, actor string) (*Result, error) {
+	cfg := s.config.AlertLifecycle.Handoff
+	if err := Validate(input, cfg.MaxPendingTasks); err != nil {
+		return nil, err
+	}
+
+	alert, err := s.alertRepo.GetByID(ctx, alertID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAlertNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	toStatus, err := TargetStatus(alert.Status)
+	if err != nil {
+		return nil, err
+	}
+	if alert.AssignedTo == nil || *alert.AssignedTo == "" {
+		return nil, ErrNoOwner
+	}
+	toUser := strings.TrimSpace(input.ToUser)
+	if toUser == *alert.AssignedTo {
+		return nil, fmt.Errorf("%w: the alert is already owned by %s", ErrInvalidHandoff, toUser)
+	}
+
+	now := time.Now()
+	handoff := &database.AlertHandoff{
+		ID:       generateID("handoff"),
+		AlertID:  alertID,
+		FromUser: *alert.AssignedTo,
+		ToUser:   toUser,
+		Actor:    actor,
+		Note:     strings.TrimSpace(input.Note),
+	}
+	handoff.PendingTasks = NewTasks(handoff.ID, input.PendingTasks)
+
+	sla := s.targets.Status(alert, now)
+	if sla.DueAt != nil {
+		extension := SLAExtension(sla.DueAt, now, cfg.MinReceiverTime, cfg.MaxSLAExtension, alert.SLAExtensionSeconds)
+		dueAfter := sla.DueAt.Add(extension)
+		handoff.SLADueAtBefore = sla.DueAt
+		handoff.SLADueAtAfter = &dueAfter
+		handoff.SLAExtensionSeconds = int(extension / time.Second)
+	}
+	if cfg.NotifyChannel != "" && s.notifier != nil {
+		handoff.NotifyChannel = &cfg.NotifyChannel
+	}
+
+	reason := fmt.Sprintf("Handed off to %s: %s", toUser, handoff.Note)
+	transition := &database.AlertTransition{
+		ID:         generateID("transition"),
+		AlertID:    alertID,
+		FromStatus: alert.Status,
+		ToStatus:   toStatus,
+		Actor:      actor,
+		AssignedTo: &toUser,
+		Reason:     &reason,
+	}
+
+	content := FormatNote(handoff)
+	note := &database.AlertComment{
+		ID:             generateID("comment"),
+		AlertID:        alertID,
+		UserID:         actor,
+		Content:        content,
+		CommentType:    database.CommentTypeStatusUpdate,
+		MentionedUsers: pq.StringArray{toUser},
+		Metadata: database.JSONB{
+			"handoff_id": handoff.ID,
+			"from_user":  handoff.FromUser,
+			"to_user":    toUser,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	updated, err := s.repo.Handoff(ctx, handoff, transition, note)
+	if err != nil {
+		return nil, err
+	}
+	alertHandoffs.WithLabelValues(fmt.Sprint(handoff.SLAExtensionSeconds > 0)).Inc()
+
+	if handoff.NotifyChannel != nil {
+		s.notify(ctx, handoff, updated, input.NotifyRecipient, content)
+	}
+
+	return &Result{
+		Handoff: handoff,
+		Alert:   &alertlifecycle.AlertState{Alert: updated, SLA: s.targets.Status(updated, time.Now())},
+	}, nil
+}
+
+// ListForAlert returns an alert's handoffs, oldest first
+func (s *Service) ListForAlert(ctx context.Context, alertID string) ([]*database.AlertHandoff, error) {
+	if _, err := s.alertRepo.GetByID(ctx, alertID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAlertNotFound
+		}
+		return nil, err
+	}
+	return s.repo.ListByAlert(ctx, alertID)
+}
+
+// ListReceived returns the handoffs made to a user, newest first, optionally
+// in one status
+func (s *Service) ListReceived(ctx context.Context, toUser, status string, limit int) ([]*database.AlertHandoff, error) {
+	switch status {
+	case "", database.AlertHandoffStatusPending, database.AlertHandoffStatusAccepted, database.AlertHandoffStatusSuperseded:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidHandoff, status)
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	return s.repo.ListForReceiver(ctx, toUser, status, limit)
+}
+
+// Accept records that the receiver has taken over the alert
+func (s *Service) Accept(ctx context.Context, id, actor string) (*database.AlertHandoff, error) {
+	handoff, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if handoff.ToUser != actor {
+		return nil, ErrNotReceiver
+	}
+
+	accepted, err := s.repo.Accept(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if accepted.AcceptedAt != nil {
+		alertHandoffAcceptSeconds.Observe(accepted.AcceptedAt.Sub(accepted.CreatedAt).Seconds())
+	}
+	return accepted, nil
+}
+
+// CompleteTask marks one of the pending tasks handed to the receiver done
+func (s *Service) CompleteTask(ctx context.Context, id, taskID, actor string) (*database.AlertHandoff, error) {
+	handoff, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if handoff.ToUser != actor {
+		return nil, ErrNotReceiver
+	}
+	return s.repo.CompleteTask(ctx, id, taskID, actor)
+}
+
+// notify tells the receiver about the handoff. A failed notification does not
+// undo the handoff; it is recorded on the handoff for follow-up.
+func (s *Service) notify(ctx context.Context, handoff *database.AlertHandoff, alert *database.Alert, recipient, content string) {
+	if recipient = strings.TrimSpace(recipient); recipient == "" {
+		recipient = handoff.ToUser
+	}
+
+	message := &notification.Message{
+		RuleID:    alert.RuleID,
+		RuleName:  alert.RuleName,
+		AlertID:   alert.ID,
+		Recipient: recipient,
+		Subject:   fmt.Sprintf("Alert handed off to you: %s", alert.Title),
+		Body:      content,
+		Severity:  alert.Severity,
+		Priority:  alert.Priority,
+		Details: map[string]interface{}{
+			"handoff_id":    handoff.ID,
+			"from_user":     handoff.FromUser,
+			"pending_tasks": len(handoff.PendingTasks),
+		},
+		CreatedAt: handoff.CreatedAt,
+	}
+
+	sendErr := s.notifier.Send(ctx, *handoff.NotifyChannel, message, nil)
+	if sendErr != nil {
+		s.logger.Warn("Failed to notify handoff receiver",
+			"handoff_id", handoff.ID,
+			"to_user", handoff.ToUser,
+			"channel", *handoff.NotifyChannel,
+			"error", sendErr)
+	}
+	if err := s.repo.RecordNotification(ctx, handoff.ID, sendErr); err != nil {
+		return
+	}
+
+	now := time.Now()
+	if sendErr == nil {
+		handoff.NotifiedAt = &now
+	} else {
+		errMessage := sendErr.Error()
+		handoff.NotifyError = &errMessage
+	}
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
@@ -0,0 +1,153 @@
+package alertlifecycle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// ErrInvalidTransition is returned for a move the alert lifecycle does not allow
+var ErrInvalidTransition = errors.New("invalid alert transition")
+
+// newStates are the statuses an alert may hold before anyone has worked it.
+// Acknowledged predates triage and is treated the same.
+var newStates = []string{
+	database.AlertStatusActive,
+	database.AlertStatusOpen,
+	database.AlertStatusAcknowledged,
+}
+
+// transitions lists the states each state may move to. Assigned may move to
+// itself to reassign the alert; closed, resolved and suppressed alerts are final.
+var transitions = map[string][]string{
+	database.AlertStatusTriaged: {
+		database.AlertStatusAssigned,
+		database.AlertStatusEscalated,
+		database.AlertStatusClosedFalsePositive,
+		database.AlertStatusClosedConfirmed,
+	},
+	database.AlertStatusAssigned: {
+		database.AlertStatusAssigned,
+		database.AlertStatusEscalated,
+		database.AlertStatusClosedFalsePositive,
+		database.AlertStatusClosedConfirmed,
+	},
+	database.AlertStatusEscalated: {
+		database.AlertStatusAssigned,
+		database.AlertStatusClosedFalsePositive,
+		database.AlertStatusClosedConfirmed,
+	},
+}
+
+func init() {
+	for _, state := range newStates {
+		transitions[state] = []string{
+			database.AlertStatusTriaged,
+			database.AlertStatusAssigned,
+			database.AlertStatusEscalated,
+			database.AlertStatusClosedFalsePositive,
+			database.AlertStatusClosedConfirmed,
+		}
+	}
+}
+
+// CanTransition reports whether an alert in the from state may move to the to state
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns ErrInvalidTransition unless from may move to to
+func ValidateTransition(from, to string) error {
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+	}
+	return nil
+}
+
+// IsClosing reports whether a state closes an alert
+func IsClosing(state string) bool {
+	return state == database.AlertStatusClosedFalsePositive || state == database.AlertStatusClosedConfirmed
+}
+
+// OpenStates returns the states in which an alert is still being worked and
+// its SLA clock is running
+func OpenStates() []string {
+	states := make([]string, 0, len(transitions))
+	states = append(states, newStates...)
+	return append(states, database.AlertStatusTriaged, database.AlertStatusAssigned, database.AlertStatusEscalated)
+}
+
+// SLATargets is the time allowed to close an alert, by severity
+type SLATargets map[string]time.Duration
+
+// NewSLATargets builds SLA targets from configuration, skipping unset severities
+func NewSLATargets(cfg config.SLATargetsConfig) SLATargets {
+	targets := SLATargets{}
+	for severity, target := range map[string]time.Duration{
+		"critical": cfg.Critical,
+		"high":     cfg.High,
+		"medium":   cfg.Medium,
+		"low":      cfg.Low,
+	} {
+		if target > 0 {
+			targets[severity] = target
+		}
+	}
+	return targets
+}
+
+// Deadline returns when an alert of the severity created at the given time
+// is due to be closed, and false when the severity has no target
+func (t SLATargets) Deadline(severity string, createdAt time.Time) (time.Time, bool) {
+	target, ok := t[severity]
+	if !ok {
+		return time.Time{}, false
+	}
+	return createdAt.Add(target), true
+}
+
+// SLAStatus is an alert's SLA deadline and whether it was missed. A closed
+// alert breached its SLA if it closed after the deadline; an open one is
+// overdue, and breached, once the deadline has passed.
+type SLAStatus struct {
+	DueAt    *time.Time `json:"due_at,omitempty"`
+	Overdue  bool       `json:"overdue"`
+	Breached bool       `json:"breached"`
+}
+
+// Status computes an alert's SLA status at the given time. The deadline
+// includes any time granted to the alert on handoff.
+func (t SLATargets) Status(alert *database.Alert, now time.Time) SLAStatus {
+	dueAt, ok := t.Deadline(alert.Severity, alert.CreatedAt)
+	if !ok {
+		return SLAStatus{}
+	}
+	dueAt = dueAt.Add(time.Duration(alert.SLAExtensionSeconds) * time.Second)
+
+	status := SLAStatus{DueAt: &dueAt}
+	switch {
+	case alert.ClosedAt != nil:
+		status.Breached = alert.ClosedAt.After(dueAt)
+	case isOpen(alert.Status):
+		status.Overdue = now.After(dueAt)
+		status.Breached = status.Overdue
+	}
+	return status
+}
+
+func isOpen(state string) bool {
+	for _, open := range OpenStates() {
+		if open == state {
+			return true
+		}
+	}
+	return false
+}
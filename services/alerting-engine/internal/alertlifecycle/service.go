@@ -0,0 +1,215 @@
+package alertlifecycle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// ErrAlertNotFound is returned when transitioning an alert that does not exist
+var ErrAlertNotFound = errors.New("alert not found")
+
+var alertTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "alerting_engine_alert_transitions_total",
+	Help: "Alert lifecycle transitions, by state entered",
+}, []string{"to_status"})
+
+// TransitionInput moves an alert to a new state. Assigning requires an
+// assignee and closing requires a reason, which is also posted to the
+// alert's thread as its resolution note.
+type TransitionInput struct {
+	Status     string `json:"status"`
+	AssignedTo string `json:"assigned_to,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// AlertState is an alert with its SLA status
+type AlertState struct {
+	*database.Alert
+	SLA SLAStatus `json:"sla"`
+}
+
+// OverdueQuery selects overdue alerts
+type OverdueQuery struct {
+	Severity   string
+	AssignedTo string
+	Limit      int
+}
+
+// DispositionRecorder records what closing an alert says about the rule
+// that raised it
+type DispositionRecorder interface {
+	RecordClosure(ctx context.Context, alert *database.Alert, actor, reason string) error
+}
+
+// Service moves alerts through their lifecycle and tracks their SLA deadlines
+type Service struct {
+	config       *config.Config
+	logger       *slog.Logger
+	repo         *database.AlertLifecycleRepository
+	alertRepo    *database.AlertRepository
+	targets      SLATargets
+	dispositions DispositionRecorder
+}
+
+// NewService creates a new alert lifecycle service
+func NewService(cfg *config.Config, logger *slog.Logger, repo *database.AlertLifecycleRepository, alertRepo *database.AlertRepository) *Service {
+	return &Service{
+		config:    cfg,
+		logger:    logger,
+		repo:      repo,
+		alertRepo: alertRepo,
+		targets:   NewSLATargets(cfg.AlertLifecycle.SLATargets),
+	}
+}
+
+// SetDispositionRecorder sets where closing an alert as confirmed or false
+// positive is recorded as the alert's disposition
+func (s *Service) SetDispositionRecorder(recorder DispositionRecorder) {
+	s.dispositions = recorder
+}
+
+// Get returns an alert with its SLA status
+func (s *Service) Get(ctx context.Context, alertID string) (*AlertState, error) {
+	alert, err := s.getAlert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	return s.state(alert), nil
+}
+
+// Transition validates and applies a move of an alert to a new state
+func (s *Service) Transition(ctx context.Context, alertID string, input TransitionInput, actor string) (*AlertState, error) {
+	alert, err := s.getAlert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateTransition(alert.Status, input.Status); err != nil {
+		return nil, err
+	}
+
+	transition := &database.AlertTransition{
+		ID:         generateID("transition"),
+		AlertID:    alertID,
+		FromStatus: alert.Status,
+		ToStatus:   input.Status,
+		Actor:      actor,
+	}
+
+	assignee := strings.TrimSpace(input.AssignedTo)
+	if input.Status == database.AlertStatusAssigned {
+		if assignee == "" {
+			return nil, fmt.Errorf("%w: assigned_to is required", ErrInvalidTransition)
+		}
+		transition.AssignedTo = &assignee
+	} else if assignee != "" {
+		return nil, fmt.Errorf("%w: assigned_to is only accepted when assigning", ErrInvalidTransition)
+	}
+
+	var note *database.AlertComment
+	reason := strings.TrimSpace(input.Reason)
+	if reason != "" {
+		transition.Reason = &reason
+	}
+	if IsClosing(input.Status) {
+		if reason == "" {
+			return nil, database.ErrResolutionNoteRequired
+		}
+		now := time.Now()
+		note = &database.AlertComment{
+			ID:             generateID("comment"),
+			AlertID:        alertID,
+			UserID:         actor,
+			Content:        reason,
+			CommentType:    database.CommentTypeResolution,
+			MentionedUsers: pq.StringArray{},
+			Metadata:       database.JSONB{"status": input.Status},
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+	}
+
+	updated, err := s.repo.Transition(ctx, transition, note)
+	if err != nil {
+		return nil, err
+	}
+	alertTransitions.WithLabelValues(input.Status).Inc()
+
+	// the alert is closed either way; a missed disposition only leaves the
+	// rule's feedback one sample short
+	if s.dispositions != nil && IsClosing(input.Status) {
+		if err := s.dispositions.RecordClosure(ctx, updated, actor, reason); err != nil {
+			s.logger.Warn("Failed to record alert disposition",
+				"alert_id", alertID,
+				"error", err)
+		}
+	}
+
+	return s.state(updated), nil
+}
+
+// History returns an alert's transitions, oldest first
+func (s *Service) History(ctx context.Context, alertID string) ([]*database.AlertTransition, error) {
+	if _, err := s.getAlert(ctx, alertID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListTransitions(ctx, alertID)
+}
+
+// ListOverdue returns open alerts past their SLA deadline, most overdue first
+func (s *Service) ListOverdue(ctx context.Context, query OverdueQuery) ([]*AlertState, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = s.config.AlertLifecycle.DefaultOverdueLimit
+	}
+	if max := s.config.AlertLifecycle.MaxOverdueLimit; max > 0 && limit > max {
+		limit = max
+	}
+
+	overdue, err := s.repo.ListOverdue(ctx, database.OverdueFilter{
+		Targets:    s.targets,
+		Statuses:   OpenStates(),
+		Severity:   query.Severity,
+		AssignedTo: query.AssignedTo,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]*AlertState, 0, len(overdue))
+	for _, alert := range overdue {
+		state := s.state(&alert.Alert)
+		// the deadline the query compared against is the one reported
+		state.SLA.DueAt = &alert.SLADueAt
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func (s *Service) getAlert(ctx context.Context, alertID string) (*database.Alert, error) {
+	alert, err := s.alertRepo.GetByID(ctx, alertID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAlertNotFound
+	}
+	return alert, err
+}
+
+func (s *Service) state(alert *database.Alert) *AlertState {
+	return &AlertState{Alert: alert, SLA: s.targets.Status(alert, time.Now())}
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
@@ -0,0 +1,52 @@
+package alertstream
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// ErrInvalidCursor is returned when a subscriber resumes from a cursor this
+// service did not issue
+var ErrInvalidCursor = errors.New("invalid alert stream cursor")
+
+// Cursor is a position in the alert stream: the creation time and ID of the
+// last alert a subscriber received. Alerts are streamed in that order, so a
+// subscriber resuming from a cursor gets every matching alert raised after it.
+type Cursor struct {
+	CreatedAt time.Time
+	AlertID   string
+}
+
+// CursorAfter returns the cursor positioned at an alert
+func CursorAfter(alert *database.Alert) Cursor {
+	return Cursor{CreatedAt: alert.CreatedAt, AlertID: alert.ID}
+}
+
+// Encode returns the opaque form of the cursor handed to subscribers
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + c.AlertID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor decodes a cursor returned by Encode
+func ParseCursor(value string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{CreatedAt: time.Unix(0, unixNano).UTC(), AlertID: id}, nil
+}
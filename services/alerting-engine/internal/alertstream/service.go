@@ -0,0 +1,226 @@
+package alertstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+var (
+	// ErrTooManySubscribers is returned when this replica already serves the
+	// configured maximum of alert streams
+	ErrTooManySubscribers = errors.New("too many alert stream subscribers")
+	// ErrInvalidFilter is returned when a subscription filters on an unknown severity
+	ErrInvalidFilter = errors.New("invalid alert stream filter")
+)
+
+var streamSubscribers = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "alerting_engine_alert_stream_subscribers",
+	Help: "Open alert stream subscriptions on this replica",
+})
+
+// Store reads alerts in stream order
+type Store interface {
+	ListAfter(ctx context.Context, afterTime time.Time, afterID string, until time.Time, filter database.AlertStreamFilter, limit int) ([]*database.Alert, error)
+}
+
+// Subscription describes which alerts a subscriber receives and from where.
+// Without a cursor the stream starts at the time of subscribing; a zero
+// heartbeat uses the configured interval.
+type Subscription struct {
+	Filter    database.AlertStreamFilter
+	Cursor    string
+	Heartbeat time.Duration
+}
+
+// Event is one message on an alert stream: a matching alert, or a heartbeat
+// sent when no alert has matched for a heartbeat interval. Either way Cursor
+// is where a subscriber that disconnects after it should resume.
+type Event struct {
+	Alert     *database.Alert
+	Heartbeat bool
+	Cursor    string
+	SentAt    time.Time
+}
+
+// Service streams alerts to subscribers by tailing the alerts table from
+// each subscriber's cursor. Alerts created within the commit lag are held
+// back until older inserts have had time to commit, so none is skipped.
+type Service struct {
+	config      config.AlertStreamConfig
+	logger      *slog.Logger
+	store       Store
+	subscribers atomic.Int64
+}
+
+// NewService creates a new alert stream service
+func NewService(cfg *config.Config, logger *slog.Logger, store Store) *Service {
+	return &Service{
+		config: cfg.AlertStream,
+		logger: logger,
+		store:  store,
+	}
+}
+
+// Subscribers returns the number of open streams on this replica
+func (s *Service) Subscribers() int {
+	return int(s.subscribers.Load())
+}
+
+// Subscribe streams alerts matching the subscription to send until the
+// context ends or send fails. It returns the context's error once the
+// subscriber goes away.
+func (s *Service) Subscribe(ctx context.Context, sub Subscription, send func(*Event) error) error {
+	filter, err := NormalizeFilter(sub.Filter)
+	if err != nil {
+		return err
+	}
+
+	cursor := Cursor{CreatedAt: time.Now().Add(-s.config.CommitLag)}
+	if sub.Cursor != "" {
+		if cursor, err = ParseCursor(sub.Cursor); err != nil {
+			return err
+		}
+	}
+
+	if open := s.subscribers.Add(1); s.config.MaxSubscribers > 0 && open > int64(s.config.MaxSubscribers) {
+		s.subscribers.Add(-1)
+		return ErrTooManySubscribers
+	}
+	streamSubscribers.Inc()
+	defer func() {
+		s.subscribers.Add(-1)
+		streamSubscribers.Dec()
+	}()
+
+	heartbeat := s.heartbeatInterval(sub.Heartbeat)
+	s.logger.InfoContext(ctx, "Alert stream subscribed",
+		"severities", filter.Severities,
+		"rule_ids", filter.RuleIDs,
+		"entity_ids", filter.EntityIDs,
+		"resumed", sub.Cursor != "",
+		"heartbeat", heartbeat)
+
+	poll := time.NewTicker(s.pollInterval())
+	defer poll.Stop()
+	lastSent := time.Now()
+
+	for {
+		sent, err := s.drain(ctx, filter, &cursor, send)
+		if err != nil {
+			return err
+		}
+		if sent > 0 {
+			lastSent = time.Now()
+		}
+
+		if time.Since(lastSent) >= heartbeat {
+			event := &Event{Heartbeat: true, Cursor: cursor.Encode(), SentAt: time.Now()}
+			if err := send(event); err != nil {
+				return fmt.Errorf("failed to send alert stream heartbeat: %w", err)
+			}
+			lastSent = event.SentAt
+		}
+
+		select {
+		case <-ctx.Done():
+			s.logger.InfoContext(ctx, "Alert stream closed", "cursor", cursor.Encode())
+			return ctx.Err()
+		case <-poll.C:
+		}
+	}
+}
+
+// drain sends every committed alert after the cursor, advancing it past
+// each one sent, and returns how many were sent
+func (s *Service) drain(ctx context.Context, filter database.AlertStreamFilter, cursor *Cursor, send func(*Event) error) (int, error) {
+	batchSize := s.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 200
+	}
+
+	sent := 0
+	for {
+		until := time.Now().Add(-s.config.CommitLag)
+		alerts, err := s.store.ListAfter(ctx, cursor.CreatedAt, cursor.AlertID, until, filter, batchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return sent, ctx.Err()
+			}
+			return sent, fmt.Errorf("failed to read alert stream: %w", err)
+		}
+
+		for _, alert := range alerts {
+			next := CursorAfter(alert)
+			if err := send(&Event{Alert: alert, Cursor: next.Encode(), SentAt: time.Now()}); err != nil {
+				return sent, fmt.Errorf("failed to send alert %s: %w", alert.ID, err)
+			}
+			*cursor = next
+			sent++
+		}
+
+		if len(alerts) < batchSize {
+			return sent, nil
+		}
+	}
+}
+
+func (s *Service) heartbeatInterval(requested time.Duration) time.Duration {
+	if requested <= 0 {
+		requested = s.config.HeartbeatInterval
+	}
+	if requested < s.config.MinHeartbeat {
+		requested = s.config.MinHeartbeat
+	}
+	if requested <= 0 {
+		requested = 15 * time.Second
+	}
+	return requested
+}
+
+func (s *Service) pollInterval() time.Duration {
+	if s.config.PollInterval <= 0 {
+		return time.Second
+	}
+	return s.config.PollInterval
+}
+
+// NormalizeFilter lower-cases severities and drops blank entries, rejecting
+// severities alerts are never raised with
+func NormalizeFilter(filter database.AlertStreamFilter) (database.AlertStreamFilter, error) {
+	var normalized database.AlertStreamFilter
+	for _, severity := range filter.Severities {
+		severity = strings.ToLower(strings.TrimSpace(severity))
+		switch severity {
+		case "":
+			continue
+		case "low", "medium", "high", "critical":
+			normalized.Severities = append(normalized.Severities, severity)
+		default:
+			return database.AlertStreamFilter{}, fmt.Errorf("%w: unsupported severity %q", ErrInvalidFilter, severity)
+		}
+	}
+	normalized.RuleIDs = nonBlank(filter.RuleIDs)
+	normalized.EntityIDs = nonBlank(filter.EntityIDs)
+	return normalized, nil
+}
+
+func nonBlank(values []string) []string {
+	var kept []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,156 @@
+package alertthread
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+var (
+	// ErrAlertNotFound is returned when commenting on an alert that does not exist
+	ErrAlertNotFound = errors.New("alert not found")
+	// ErrInvalidComment is returned when a comment fails validation
+	ErrInvalidComment = errors.New("invalid comment")
+	// ErrNotAuthor is returned when a user edits or deletes someone else's comment
+	ErrNotAuthor = errors.New("only the author can change a comment")
+	// ErrResolutionLocked is returned when a resolution note is edited or deleted
+	ErrResolutionLocked = errors.New("resolution notes cannot be changed")
+)
+
+// Service manages the discussion thread of each alert
+type Service struct {
+	logger    *slog.Logger
+	repo      *database.AlertCommentRepository
+	alertRepo *database.AlertRepository
+}
+
+// CommentInput describes a comment to post
+type CommentInput struct {
+	ParentCommentID string                 `json:"parent_comment_id,omitempty"`
+	Content         string                 `json:"content"`
+	CommentType     string                 `json:"comment_type,omitempty"`
+	IsInternal      bool                   `json:"is_internal"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// NewService creates a new alert thread service
+func NewService(logger *slog.Logger, repo *database.AlertCommentRepository, alertRepo *database.AlertRepository) *Service {
+	return &Service{
+		logger:    logger,
+		repo:      repo,
+		alertRepo: alertRepo,
+	}
+}
+
+// Thread returns an alert's comments arranged into replies
+func (s *Service) Thread(ctx context.Context, alertID string) ([]*Node, int, error) {
+	comments, err := s.repo.ListByAlert(ctx, alertID, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return Build(comments), len(comments), nil
+}
+
+// Post adds a comment or reply to an alert's thread
+func (s *Service) Post(ctx context.Context, alertID, userID string, input CommentInput) (*database.AlertComment, error) {
+	if input.CommentType == "" {
+		input.CommentType = database.CommentTypeGeneral
+	}
+	if !ValidType(input.CommentType) {
+		return nil, fmt.Errorf("%w: unknown comment type %q", ErrInvalidComment, input.CommentType)
+	}
+	if err := validateContent(input.Content); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.alertRepo.GetByID(ctx, alertID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAlertNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	comment := &database.AlertComment{
+		ID:             fmt.Sprintf("comment_%d_%d", now.Unix(), now.Nanosecond()),
+		AlertID:        alertID,
+		UserID:         userID,
+		Content:        input.Content,
+		CommentType:    input.CommentType,
+		MentionedUsers: pq.StringArray(Mentions(input.Content)),
+		IsInternal:     input.IsInternal,
+		Metadata:       database.JSONB(input.Metadata),
+	}
+	if input.ParentCommentID != "" {
+		comment.ParentCommentID = &input.ParentCommentID
+	}
+
+	if err := s.repo.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// Edit replaces the text of the user's own comment
+func (s *Service) Edit(ctx context.Context, alertID, commentID, userID, content string) (*database.AlertComment, error) {
+	if err := validateContent(content); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.authored(ctx, alertID, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment.Content = content
+	comment.MentionedUsers = pq.StringArray(Mentions(content))
+	if err := s.repo.UpdateContent(ctx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// Delete removes the user's own comment from the thread
+func (s *Service) Delete(ctx context.Context, alertID, commentID, userID string) error {
+	if _, err := s.authored(ctx, alertID, commentID, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, commentID)
+}
+
+// authored loads a comment on the alert and checks the user wrote it.
+// Resolution notes are part of the alert's record and stay as written.
+func (s *Service) authored(ctx context.Context, alertID, commentID, userID string) (*database.AlertComment, error) {
+	comment, err := s.repo.GetByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.AlertID != alertID {
+		return nil, database.ErrCommentNotFound
+	}
+	if comment.CommentType == database.CommentTypeResolution {
+		return nil, ErrResolutionLocked
+	}
+	if comment.UserID != userID {
+		return nil, ErrNotAuthor
+	}
+	return comment, nil
+}
+
+func validateContent(content string) error {
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("%w: content is required", ErrInvalidComment)
+	}
+	if len(content) > MaxContentLength {
+		return fmt.Errorf("%w: content exceeds %d characters", ErrInvalidComment, MaxContentLength)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package alertthread
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// MaxContentLength bounds the text of a single comment
+const MaxContentLength = 10000
+
+// userTypes are the comment types analysts can post. Resolution comments are
+// only written when an alert is resolved.
+var userTypes = map[string]bool{
+	database.CommentTypeGeneral:         true,
+	database.CommentTypeQuestion:        true,
+	database.CommentTypeFinding:         true,
+	database.CommentTypeRecommendation:  true,
+	database.CommentTypeStatusUpdate:    true,
+	database.CommentTypeEvidenceComment: true,
+}
+
+// caseTypes maps alert comment types onto investigation collaboration comment
+// types. Types not listed carry over unchanged.
+var caseTypes = map[string]string{
+	database.CommentTypeResolution: database.CommentTypeStatusUpdate,
+}
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// Node is a comment with its replies
+type Node struct {
+	*database.AlertComment
+	Replies []*Node `json:"replies,omitempty"`
+}
+
+// ValidType reports whether analysts may post a comment of this type
+func ValidType(commentType string) bool {
+	return userTypes[commentType]
+}
+
+// Mentions returns the distinct @usernames in a comment, in order of appearance
+func Mentions(content string) []string {
+	seen := make(map[string]bool)
+	var mentions []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		user := match[1]
+		if !seen[user] {
+			seen[user] = true
+			mentions = append(mentions, user)
+		}
+	}
+	return mentions
+}
+
+// Build arranges comments, oldest first, into a tree of top-level comments
+// and replies. Replies whose parent was deleted are kept at the top level.
+func Build(comments []*database.AlertComment) []*Node {
+	nodes := make(map[string]*Node, len(comments))
+	for _, comment := range comments {
+		nodes[comment.ID] = &Node{AlertComment: comment}
+	}
+
+	var roots []*Node
+	for _, comment := range comments {
+		node := nodes[comment.ID]
+		if comment.ParentCommentID != nil {
+			if parent, ok := nodes[*comment.ParentCommentID]; ok {
+				parent.Replies = append(parent.Replies, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// CaseComments renders a thread as investigation collaboration comments, so
+// a case system can import it as the opening discussion of the case. Each
+// comment keeps its alert comment ID in metadata for idempotent imports.
+func CaseComments(comments []*database.AlertComment) []map[string]interface{} {
+	rendered := make([]map[string]interface{}, 0, len(comments))
+	for _, comment := range comments {
+		commentType := comment.CommentType
+		if mapped, ok := caseTypes[commentType]; ok {
+			commentType = mapped
+		}
+
+		metadata := map[string]interface{}{
+			"source":              "alerting-engine",
+			"source_alert_id":     comment.AlertID,
+			"source_comment_id":   comment.ID,
+			"source_comment_type": comment.CommentType,
+		}
+		for key, value := range comment.Metadata {
+			if _, reserved := metadata[key]; !reserved {
+				metadata[key] = value
+			}
+		}
+
+		entry := map[string]interface{}{
+			"id":              comment.ID,
+			"user_id":         comment.UserID,
+			"content":         comment.Content,
+			"comment_type":    commentType,
+			"mentioned_users": []string(comment.MentionedUsers),
+			"is_internal":     comment.IsInternal,
+			"metadata":        metadata,
+			"created_at":      comment.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if comment.ParentCommentID != nil {
+			entry["parent_comment_id"] = *comment.ParentCommentID
+		}
+		if comment.EditedAt != nil {
+			entry["edited_at"] = comment.EditedAt.UTC().Format(time.RFC3339)
+		}
+		rendered = append(rendered, entry)
+	}
+	return rendered
+}
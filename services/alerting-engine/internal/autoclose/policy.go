@@ -0,0 +1,184 @@
+package autoclose
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// ErrInvalidPolicy is returned for auto-close policies that cannot be applied
+var ErrInvalidPolicy = errors.New("invalid auto-close policy")
+
+var (
+	dispositionPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	severities         = map[string]bool{"critical": true, "high": true, "medium": true, "low": true}
+)
+
+// Policy closes open alerts of a severity that have gone InactiveFor without
+// activity. A policy with rule IDs covers only those rules; one without
+// covers the severity's remaining rules.
+type Policy struct {
+	Name        string        `json:"name"`
+	Severity    string        `json:"severity"`
+	RuleIDs     []string      `json:"rule_ids,omitempty"`
+	InactiveFor time.Duration `json:"inactive_for_ns"`
+	Disposition string        `json:"disposition"`
+	SampleRate  float64       `json:"sample_rate"`
+
+	// rules named by other policies of the severity, for a policy without rule IDs
+	excludeRuleIDs []string
+}
+
+// NewPolicies validates the configured policies. Policies naming rules come
+// first, so they act on their rules before the severity-wide policy would.
+func NewPolicies(cfg config.AutoCloseConfig) ([]Policy, error) {
+	policies := make([]Policy, 0, len(cfg.Policies))
+	names := make(map[string]bool)
+	severityWide := make(map[string]string)
+	ruled := make(map[string]string) // severity and rule to policy name
+
+	for _, c := range cfg.Policies {
+		policy := Policy{
+			Name:        strings.TrimSpace(c.Name),
+			Severity:    strings.ToLower(strings.TrimSpace(c.Severity)),
+			InactiveFor: c.InactiveFor,
+			Disposition: strings.TrimSpace(c.Disposition),
+			SampleRate:  c.SampleRate,
+		}
+		if policy.SampleRate == 0 {
+			policy.SampleRate = cfg.DefaultSampleRate
+		}
+
+		switch {
+		case policy.Name == "":
+			return nil, fmt.Errorf("%w: name is required", ErrInvalidPolicy)
+		case names[policy.Name]:
+			return nil, fmt.Errorf("%w: %s is declared twice", ErrInvalidPolicy, policy.Name)
+		case !severities[policy.Severity]:
+			return nil, fmt.Errorf("%w: %s: unknown severity %q", ErrInvalidPolicy, policy.Name, c.Severity)
+		case policy.InactiveFor <= 0:
+			return nil, fmt.Errorf("%w: %s: inactive_for must be positive", ErrInvalidPolicy, policy.Name)
+		case !dispositionPattern.MatchString(policy.Disposition):
+			return nil, fmt.Errorf("%w: %s: disposition must be lower snake case", ErrInvalidPolicy, policy.Name)
+		case policy.SampleRate < 0 || policy.SampleRate > 1:
+			return nil, fmt.Errorf("%w: %s: sample_rate must be between 0 and 1", ErrInvalidPolicy, policy.Name)
+		}
+		names[policy.Name] = true
+
+		for _, ruleID := range c.RuleIDs {
+			ruleID = strings.TrimSpace(ruleID)
+			if ruleID == "" {
+				continue
+			}
+			key := policy.Severity + "/" + ruleID
+			if other, ok := ruled[key]; ok {
+				return nil, fmt.Errorf("%w: %s and %s both cover %s alerts of rule %s",
+					ErrInvalidPolicy, other, policy.Name, policy.Severity, ruleID)
+			}
+			ruled[key] = policy.Name
+			policy.RuleIDs = append(policy.RuleIDs, ruleID)
+		}
+		if len(policy.RuleIDs) == 0 {
+			if other, ok := severityWide[policy.Severity]; ok {
+				return nil, fmt.Errorf("%w: %s and %s both cover every %s alert",
+					ErrInvalidPolicy, other, policy.Name, policy.Severity)
+			}
+			severityWide[policy.Severity] = policy.Name
+		}
+
+		policies = append(policies, policy)
+	}
+
+	for i := range policies {
+		if len(policies[i].RuleIDs) > 0 {
+			continue
+		}
+		for _, other := range policies {
+			if other.Severity == policies[i].Severity {
+				policies[i].excludeRuleIDs = append(policies[i].excludeRuleIDs, other.RuleIDs...)
+			}
+		}
+		sort.Strings(policies[i].excludeRuleIDs)
+	}
+
+	sort.SliceStable(policies, func(i, j int) bool {
+		return len(policies[i].RuleIDs) > 0 && len(policies[j].RuleIDs) == 0
+	})
+	return policies, nil
+}
+
+// Covers reports whether the policy applies to alerts of the rule and severity
+func (p Policy) Covers(ruleID, severity string) bool {
+	if p.Severity != severity {
+		return false
+	}
+	if len(p.RuleIDs) > 0 {
+		return contains(p.RuleIDs, ruleID)
+	}
+	return !contains(p.excludeRuleIDs, ruleID)
+}
+
+// Select returns the policy covering alerts of the rule and severity, or nil
+func Select(policies []Policy, ruleID, severity string) *Policy {
+	for i := range policies {
+		if policies[i].Covers(ruleID, severity) {
+			return &policies[i]
+		}
+	}
+	return nil
+}
+
+// Filter selects the alerts the policy would close at the given time
+func (p Policy) Filter(now time.Time, limit int) database.StaleAlertFilter {
+	return database.StaleAlertFilter{
+		Statuses:       ClosableStates(),
+		Severity:       p.Severity,
+		RuleIDs:        p.RuleIDs,
+		ExcludeRuleIDs: p.excludeRuleIDs,
+		InactiveSince:  now.Add(-p.InactiveFor),
+		Limit:          limit,
+	}
+}
+
+// ClosableStates returns the states an auto-close policy closes alerts from.
+// Escalated alerts are left for the investigator they were escalated to.
+func ClosableStates() []string {
+	return []string{
+		database.AlertStatusActive,
+		database.AlertStatusOpen,
+		database.AlertStatusAcknowledged,
+		database.AlertStatusTriaged,
+		database.AlertStatusAssigned,
+	}
+}
+
+// Sampled reports whether an auto-closed alert is drawn for review at the
+// rate. The draw hashes the alert ID, so it is the same on every run and
+// does not depend on which batch closed the alert.
+func Sampled(alertID string, rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(alertID))
+	return float64(h.Sum32())/float64(1<<32) < rate
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
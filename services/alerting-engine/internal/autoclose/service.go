@@ -0,0 +1,396 @@
+// Package autoclose closes stale low-value alerts nobody worked, under
+// configured per-severity and per-rule policies, and samples the closures
+// for periodic review so the policies can be shown to be safe.
+package autoclose
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// Actor is who auto-closures are recorded as closed by
+const Actor = "system:auto-close"
+
+var alertsAutoClosed = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "alerting_engine_alerts_auto_closed_total",
+	Help: "Stale alerts closed by an auto-close policy, by policy",
+}, []string{"policy"})
+
+var (
+	// ErrClosureNotFound is returned when reviewing an alert that was not auto-closed
+	ErrClosureNotFound = errors.New("alert was not auto-closed")
+	// ErrInvalidReview is returned for review outcomes other than agreed or disagreed
+	ErrInvalidReview = errors.New("review outcome must be agreed or disagreed")
+	// ErrInvalidPeriod is returned for report periods that end before they start
+	ErrInvalidPeriod = errors.New("report period must end after it starts")
+)
+
+// Store finds stale alerts and persists their auto-closures
+type Store interface {
+	ListStale(ctx context.Context, filter database.StaleAlertFilter) ([]*database.StaleAlert, error)
+	Close(ctx context.Context, closure *database.AlertAutoClosure, transition *database.AlertTransition, note *database.AlertComment) (*database.Alert, error)
+	ListClosures(ctx context.Context, filter database.AutoClosureFilter) ([]*database.AlertAutoClosure, error)
+	ReviewClosure(ctx context.Context, alertID, outcome string, notes *string, reviewer string, at time.Time) (*database.AlertAutoClosure, error)
+}
+
+// PolicyRun counts what one policy did in a run
+type PolicyRun struct {
+	Policy  string `json:"policy"`
+	Closed  int    `json:"closed"`
+	Skipped int    `json:"skipped"` // worked or changed after being selected
+	Failed  int    `json:"failed"`
+}
+
+// RunResult summarizes an auto-close run
+type RunResult struct {
+	Closed   int          `json:"closed"`
+	Skipped  int          `json:"skipped"`
+	Failed   int          `json:"failed"`
+	Policies []*PolicyRun `json:"policies"`
+}
+
+// ReviewInput records a reviewer's verdict on a sampled auto-closure
+type ReviewInput struct {
+	Outcome string `json:"outcome"`
+	Notes   string `json:"notes,omitempty"`
+}
+
+// ReportQuery selects the auto-closures a report covers
+type ReportQuery struct {
+	From   time.Time
+	To     time.Time
+	Policy string
+	RuleID string
+}
+
+// Tally counts auto-closures and the reviews of those sampled
+type Tally struct {
+	Closed        int      `json:"closed"`
+	Sampled       int      `json:"sampled"`
+	Reviewed      int      `json:"reviewed"`
+	Disagreed     int      `json:"disagreed"`
+	DisagreedRate *float64 `json:"disagreed_rate,omitempty"` // of reviewed closures
+}
+
+// PolicyTally is a policy's tally in a report
+type PolicyTally struct {
+	Policy string `json:"policy"`
+	Tally
+}
+
+// RuleTally is a rule's tally in a report
+type RuleTally struct {
+	RuleID string `json:"rule_id"`
+	Tally
+}
+
+// Report summarizes the auto-closures of a period and lists the sampled
+// closures still waiting for review, oldest first
+type Report struct {
+	From          time.Time                    `json:"from"`
+	To            time.Time                    `json:"to"`
+	Truncated     bool                         `json:"truncated"` // more closures than were summarized
+	Tally                                      // over every closure in the report
+	Policies      []*PolicyTally               `json:"policies"`
+	Rules         []*RuleTally                 `json:"rules"`
+	PendingReview []*database.AlertAutoClosure `json:"pending_review"`
+}
+
+// Service applies the auto-close policies and reports on their closures
+type Service struct {
+	config   *config.Config
+	logger   *slog.Logger
+	store    Store
+	policies []Policy
+	now      func() time.Time
+}
+
+// NewService creates a new auto-close service with validated policies
+func NewService(cfg *config.Config, logger *slog.Logger, store Store, policies []Policy) *Service {
+	return &Service{
+		config:   cfg,
+		logger:   logger,
+		store:    store,
+		policies: policies,
+		now:      time.Now,
+	}
+}
+
+// Policies returns the policies in the order they are applied
+func (s *Service) Policies() []Policy {
+	return append([]Policy(nil), s.policies...)
+}
+
+// Run closes the alerts each policy finds stale, up to the batch size per
+// policy. Alerts worked since they were selected are skipped; a failed
+// closure does not stop the others.
+func (s *Service) Run(ctx context.Context) (*RunResult, error) {
+	result := &RunResult{Policies: make([]*PolicyRun, 0, len(s.policies))}
+	var errs []error
+
+	for _, policy := range s.policies {
+		run := &PolicyRun{Policy: policy.Name}
+		result.Policies = append(result.Policies, run)
+
+		stale, err := s.store.ListStale(ctx, policy.Filter(s.now(), s.config.AutoClose.BatchSize))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("policy %s: %w", policy.Name, err))
+			continue
+		}
+
+		for _, alert := range stale {
+			err := s.close(ctx, policy, alert)
+			switch {
+			case err == nil:
+				run.Closed++
+			case errors.Is(err, database.ErrAlertActive), errors.Is(err, database.ErrStaleTransition):
+				run.Skipped++
+			default:
+				run.Failed++
+				errs = append(errs, fmt.Errorf("policy %s: alert %s: %w", policy.Name, alert.ID, err))
+			}
+		}
+
+		result.Closed += run.Closed
+		result.Skipped += run.Skipped
+		result.Failed += run.Failed
+	}
+
+	if result.Closed > 0 || result.Failed > 0 {
+		s.logger.Info("Alert auto-close run completed",
+			"closed", result.Closed,
+			"skipped", result.Skipped,
+			"failed", result.Failed)
+	}
+	return result, errors.Join(errs...)
+}
+
+// close closes one stale alert, recording the transition, a resolution note
+// on the alert's thread and the auto-closure
+func (s *Service) close(ctx context.Context, policy Policy, alert *database.StaleAlert) error {
+	now := s.now()
+	reason := Reason(policy, alert.LastActivityAt)
+
+	transition := &database.AlertTransition{
+		ID:         generateID("transition"),
+		AlertID:    alert.ID,
+		FromStatus: alert.Status,
+		ToStatus:   database.AlertStatusClosedAuto,
+		Actor:      Actor,
+		Reason:     &reason,
+	}
+	note := &database.AlertComment{
+		ID:             generateID("comment"),
+		AlertID:        alert.ID,
+		UserID:         Actor,
+		Content:        reason,
+		CommentType:    database.CommentTypeResolution,
+		MentionedUsers: pq.StringArray{},
+		Metadata: database.JSONB{
+			"status":      database.AlertStatusClosedAuto,
+			"policy":      policy.Name,
+			"disposition": policy.Disposition,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	closure := &database.AlertAutoClosure{
+		AlertID:            alert.ID,
+		Policy:             policy.Name,
+		RuleID:             alert.RuleID,
+		Severity:           alert.Severity,
+		FromStatus:         alert.Status,
+		Disposition:        policy.Disposition,
+		InactiveForSeconds: int64(policy.InactiveFor / time.Second),
+		LastActivityAt:     alert.LastActivityAt,
+		ClosedBy:           Actor,
+		Sampled:            Sampled(alert.ID, policy.SampleRate),
+	}
+
+	if _, err := s.store.Close(ctx, closure, transition, note); err != nil {
+		return err
+	}
+	alertsAutoClosed.WithLabelValues(policy.Name).Inc()
+	return nil
+}
+
+// Reason is the resolution note of an alert closed by the policy
+func Reason(policy Policy, lastActivity time.Time) string {
+	return fmt.Sprintf("Auto-closed as %s by policy %s: no activity since %s (limit %s)",
+		policy.Disposition, policy.Name, lastActivity.UTC().Format(time.RFC3339), formatDays(policy.InactiveFor))
+}
+
+// ListClosures retrieves auto-closures, newest first
+func (s *Service) ListClosures(ctx context.Context, filter database.AutoClosureFilter) ([]*database.AlertAutoClosure, error) {
+	if filter.To.IsZero() {
+		filter.To = s.now()
+	}
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		filter.Limit = 100
+	}
+	return s.store.ListClosures(ctx, filter)
+}
+
+// Review records a reviewer's verdict on an auto-closure
+func (s *Service) Review(ctx context.Context, alertID string, input ReviewInput, reviewer string) (*database.AlertAutoClosure, error) {
+	outcome := strings.ToLower(strings.TrimSpace(input.Outcome))
+	if outcome != database.AutoClosureReviewAgreed && outcome != database.AutoClosureReviewDisagreed {
+		return nil, ErrInvalidReview
+	}
+
+	var notes *string
+	if trimmed := strings.TrimSpace(input.Notes); trimmed != "" {
+		notes = &trimmed
+	}
+
+	closure, err := s.store.ReviewClosure(ctx, alertID, outcome, notes, reviewer, s.now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrClosureNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Alert auto-closure reviewed",
+		"alert_id", alertID,
+		"policy", closure.Policy,
+		"outcome", outcome,
+		"reviewer", reviewer)
+	return closure, nil
+}
+
+// Report summarizes the auto-closures of a period. The period defaults to
+// the 30 days up to now.
+func (s *Service) Report(ctx context.Context, query ReportQuery) (*Report, error) {
+	if query.To.IsZero() {
+		query.To = s.now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.AddDate(0, 0, -30)
+	}
+	if !query.To.After(query.From) {
+		return nil, ErrInvalidPeriod
+	}
+
+	limit := s.config.AutoClose.MaxReportClosures
+	closures, err := s.store.ListClosures(ctx, database.AutoClosureFilter{
+		From:   query.From,
+		To:     query.To,
+		Policy: query.Policy,
+		RuleID: query.RuleID,
+		Limit:  limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := Summarize(closures[:minInt(len(closures), limit)])
+	report.From, report.To = query.From, query.To
+	report.Truncated = len(closures) > limit
+	return report, nil
+}
+
+// Summarize tallies auto-closures overall, by policy and by rule, and lists
+// the sampled closures not yet reviewed
+func Summarize(closures []*database.AlertAutoClosure) *Report {
+	report := &Report{Policies: []*PolicyTally{}, Rules: []*RuleTally{}, PendingReview: []*database.AlertAutoClosure{}}
+	byPolicy := make(map[string]*PolicyTally)
+	byRule := make(map[string]*RuleTally)
+
+	for _, closure := range closures {
+		policy, ok := byPolicy[closure.Policy]
+		if !ok {
+			policy = &PolicyTally{Policy: closure.Policy}
+			byPolicy[closure.Policy] = policy
+			report.Policies = append(report.Policies, policy)
+		}
+		rule, ok := byRule[closure.RuleID]
+		if !ok {
+			rule = &RuleTally{RuleID: closure.RuleID}
+			byRule[closure.RuleID] = rule
+			report.Rules = append(report.Rules, rule)
+		}
+
+		for _, tally := range []*Tally{&report.Tally, &policy.Tally, &rule.Tally} {
+			tally.add(closure)
+		}
+		if closure.Sampled && closure.ReviewedAt == nil {
+			report.PendingReview = append(report.PendingReview, closure)
+		}
+	}
+
+	for _, tally := range report.Policies {
+		tally.rate()
+	}
+	for _, tally := range report.Rules {
+		tally.rate()
+	}
+	report.rate()
+
+	sort.Slice(report.Policies, func(i, j int) bool { return report.Policies[i].Policy < report.Policies[j].Policy })
+	sort.Slice(report.Rules, func(i, j int) bool {
+		if report.Rules[i].Closed != report.Rules[j].Closed {
+			return report.Rules[i].Closed > report.Rules[j].Closed
+		}
+		return report.Rules[i].RuleID < report.Rules[j].RuleID
+	})
+	sort.Slice(report.PendingReview, func(i, j int) bool {
+		return report.PendingReview[i].ClosedAt.Before(report.PendingReview[j].ClosedAt)
+	})
+	return report
+}
+
+func (t *Tally) add(closure *database.AlertAutoClosure) {
+	t.Closed++
+	if closure.Sampled {
+		t.Sampled++
+	}
+	if closure.ReviewOutcome != nil {
+		t.Reviewed++
+		if *closure.ReviewOutcome == database.AutoClosureReviewDisagreed {
+			t.Disagreed++
+		}
+	}
+}
+
+func (t *Tally) rate() {
+	if t.Reviewed > 0 {
+		rate := float64(t.Disagreed) / float64(t.Reviewed)
+		t.DisagreedRate = &rate
+	}
+}
+
+func formatDays(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		days := int(d / (24 * time.Hour))
+		if days == 1 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", days)
+	}
+	return d.String()
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
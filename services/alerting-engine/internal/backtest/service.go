@@ -0,0 +1,410 @@
+package backtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/engine"
+	"github.com/aegis-shield/services/alerting-engine/internal/rulepack"
+)
+
+// draftRuleID stands for an unsaved draft rule while it is evaluated
+const draftRuleID = "draft"
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+var (
+	// ErrBacktestDisabled is returned when backtesting is disabled or not yet running
+	ErrBacktestDisabled = errors.New("rule backtesting is unavailable")
+	// ErrInvalidBacktest is returned for backtests with no usable rule or window
+	ErrInvalidBacktest = errors.New("invalid backtest")
+	// ErrTooManyBacktests is returned when the configured number of backtests are already running
+	ErrTooManyBacktests = errors.New("too many backtests running")
+
+	// errEventLimit stops a replay that reached the configured event limit
+	errEventLimit = errors.New("backtest event limit reached")
+)
+
+// EventSource replays the events of a past window
+type EventSource interface {
+	Replay(ctx context.Context, from, to time.Time, fn func(event map[string]interface{}, at time.Time) error) error
+}
+
+// Request starts a backtest of a draft rule. Rule is the draft to test;
+// RuleID names a stored rule, which is tested as stored when Rule is unset
+// and is otherwise left out of the rules the draft is compared against, so
+// an edit can be tested against the rule it replaces.
+type Request struct {
+	RuleID     string                   `json:"rule_id,omitempty"`
+	Rule       *database.RuleDefinition `json:"rule,omitempty"`
+	Since      *time.Time               `json:"since,omitempty"`
+	Until      *time.Time               `json:"until,omitempty"`
+	SampleSize int                      `json:"sample_size,omitempty"`
+}
+
+// Service replays historical events against draft rules in the background
+// and records the projected alert volume
+type Service struct {
+	config    *config.Config
+	logger    *slog.Logger
+	repo      *database.RuleBacktestRepository
+	ruleRepo  *database.RuleRepository
+	alertRepo *database.AlertRepository
+	engine    *engine.RuleEngine
+	source    EventSource
+	slots     chan struct{}
+	mu        sync.Mutex
+	baseCtx   context.Context
+	running   sync.WaitGroup
+}
+
+// NewService creates a new backtest service
+func NewService(
+	cfg *config.Config,
+	logger *slog.Logger,
+	repo *database.RuleBacktestRepository,
+	ruleRepo *database.RuleRepository,
+	alertRepo *database.AlertRepository,
+	ruleEngine *engine.RuleEngine,
+	source EventSource,
+) *Service {
+	maxConcurrent := cfg.Rules.Backtest.MaxConcurrent
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+
+	return &Service{
+		config:    cfg,
+		logger:    logger,
+		repo:      repo,
+		ruleRepo:  ruleRepo,
+		alertRepo: alertRepo,
+		engine:    ruleEngine,
+		source:    source,
+		slots:     make(chan struct{}, maxConcurrent),
+	}
+}
+
+// Run accepts backtests until ctx is done, then waits for those running to
+// stop. Backtests still marked running when it starts were interrupted by a
+// restart and are marked failed.
+func (s *Service) Run(ctx context.Context) error {
+	interrupted, err := s.repo.FailRunning(ctx, "interrupted by a service restart")
+	if err != nil {
+		return err
+	}
+	if interrupted > 0 {
+		s.logger.Warn("Marked interrupted backtests failed", "count", interrupted)
+	}
+
+	s.mu.Lock()
+	s.baseCtx = ctx
+	s.mu.Unlock()
+
+	<-ctx.Done()
+
+	s.mu.Lock()
+	s.baseCtx = nil
+	s.mu.Unlock()
+	s.running.Wait()
+
+	return nil
+}
+
+// Start validates a backtest, records it and replays its window in the
+// background. The returned backtest is running; poll Get for its result.
+func (s *Service) Start(ctx context.Context, req Request, requestedBy string) (*database.RuleBacktest, error) {
+	if !s.config.Rules.Backtest.Enabled {
+		return nil, ErrBacktestDisabled
+	}
+
+	definition, err := s.resolveDefinition(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	expressions := rulepack.ConditionExpressions(definition.Conditions)
+	if len(expressions) == 0 {
+		return nil, fmt.Errorf("%w: rule has no condition expressions", ErrInvalidBacktest)
+	}
+	language := rulepack.ConditionLanguage(definition.Conditions)
+	for i, expression := range expressions {
+		if err := s.engine.CompileExpression(language, expression); err != nil {
+			return nil, fmt.Errorf("%w: condition %d: %v", ErrInvalidBacktest, i, err)
+		}
+	}
+	windows, err := engine.ParseWindows(definition.Conditions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBacktest, err)
+	}
+
+	from, to, err := s.window(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	sampleSize := req.SampleSize
+	if sampleSize < 0 {
+		return nil, fmt.Errorf("%w: sample_size must not be negative", ErrInvalidBacktest)
+	}
+	if sampleSize == 0 {
+		sampleSize = s.config.Rules.Backtest.DefaultSampleSize
+	}
+	if sampleSize > s.config.Rules.Backtest.MaxSampleSize {
+		sampleSize = s.config.Rules.Backtest.MaxSampleSize
+	}
+
+	encoded, _, err := definition.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	backtest := &database.RuleBacktest{
+		ID:          generateID("bt"),
+		RuleName:    definition.Name,
+		Definition:  encoded,
+		WindowStart: from,
+		WindowEnd:   to,
+		SampleSize:  sampleSize,
+		RequestedBy: requestedBy,
+	}
+	if req.RuleID != "" {
+		backtest.RuleID = &req.RuleID
+	}
+	if backtest.RuleName == "" {
+		backtest.RuleName = draftRuleID
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		return nil, ErrTooManyBacktests
+	}
+
+	s.mu.Lock()
+	baseCtx := s.baseCtx
+	if baseCtx != nil {
+		s.running.Add(1)
+	}
+	s.mu.Unlock()
+	if baseCtx == nil {
+		<-s.slots
+		return nil, ErrBacktestDisabled
+	}
+
+	if err := s.repo.Create(ctx, backtest); err != nil {
+		<-s.slots
+		s.running.Done()
+		return nil, err
+	}
+
+	go func() {
+		defer s.running.Done()
+		defer func() { <-s.slots }()
+		s.execute(baseCtx, backtest, req.RuleID, engine.MatcherRule{Language: language, Expressions: expressions, Windows: windows})
+	}()
+
+	s.logger.Info("Backtest started",
+		"backtest_id", backtest.ID,
+		"rule_id", req.RuleID,
+		"rule_name", backtest.RuleName,
+		"window_start", from,
+		"window_end", to,
+		"requested_by", requestedBy)
+
+	return backtest, nil
+}
+
+// Get returns a backtest and, once completed, its result
+func (s *Service) Get(ctx context.Context, id string) (*database.RuleBacktest, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// List returns recent backtests, optionally of one stored rule
+func (s *Service) List(ctx context.Context, ruleID string, limit int) ([]*database.RuleBacktest, error) {
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	backtests, err := s.repo.List(ctx, ruleID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if backtests == nil {
+		backtests = []*database.RuleBacktest{}
+	}
+	return backtests, nil
+}
+
+// resolveDefinition returns the definition a request backtests
+func (s *Service) resolveDefinition(ctx context.Context, req Request) (database.RuleDefinition, error) {
+	if req.RuleID != "" {
+		rule, err := s.ruleRepo.GetByID(ctx, req.RuleID)
+		if err != nil {
+			return database.RuleDefinition{}, err
+		}
+		if req.Rule == nil {
+			return database.NewRuleDefinition(rule), nil
+		}
+	}
+
+	if req.Rule == nil {
+		return database.RuleDefinition{}, fmt.Errorf("%w: rule or rule_id is required", ErrInvalidBacktest)
+	}
+	return *req.Rule, nil
+}
+
+// window returns the replayed window of a request
+func (s *Service) window(req Request, now time.Time) (time.Time, time.Time, error) {
+	to := now
+	if req.Until != nil {
+		to = *req.Until
+	}
+	from := to.Add(-s.config.Rules.Backtest.DefaultWindow)
+	if req.Since != nil {
+		from = *req.Since
+	}
+
+	switch {
+	case !from.Before(to):
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: since must be before until", ErrInvalidBacktest)
+	case to.After(now):
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: until must not be in the future", ErrInvalidBacktest)
+	case to.Sub(from) > s.config.Rules.Backtest.MaxWindow:
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: window is longer than %s", ErrInvalidBacktest, s.config.Rules.Backtest.MaxWindow)
+	}
+
+	return from, to, nil
+}
+
+// execute replays a backtest's window and records its result or failure
+func (s *Service) execute(ctx context.Context, backtest *database.RuleBacktest, ruleID string, draft engine.MatcherRule) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Rules.Backtest.Timeout)
+	defer cancel()
+
+	result, err := s.replay(ctx, backtest, ruleID, draft)
+	if err != nil {
+		s.logger.Error("Backtest failed", "backtest_id", backtest.ID, "error", err)
+		// Record the failure even when the run was cancelled by a shutdown
+		if failErr := s.repo.Fail(context.Background(), backtest.ID, err.Error()); failErr != nil {
+			s.logger.Error("Failed to record backtest failure", "backtest_id", backtest.ID, "error", failErr)
+		}
+		return
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		s.logger.Error("Failed to encode backtest result", "backtest_id", backtest.ID, "error", err)
+		return
+	}
+	if err := s.repo.Complete(context.Background(), backtest.ID, encoded); err != nil {
+		s.logger.Error("Failed to record backtest result", "backtest_id", backtest.ID, "error", err)
+		return
+	}
+
+	s.logger.Info("Backtest completed",
+		"backtest_id", backtest.ID,
+		"events_scanned", result.EventsScanned,
+		"matches", result.Matches,
+		"overlapping_matches", result.Overlap.OverlappingMatches,
+		"truncated", result.Truncated)
+}
+
+// replay evaluates the draft alongside every enabled rule over the window
+func (s *Service) replay(ctx context.Context, backtest *database.RuleBacktest, ruleID string, draft engine.MatcherRule) (*Result, error) {
+	enabled, err := s.ruleRepo.ListEnabled(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	draftID := draftRuleID
+	if ruleID != "" {
+		draftID = ruleID
+	}
+
+	draft.ID = draftID
+	rules := []engine.MatcherRule{draft}
+	ruleNames := make(map[string]string, len(enabled))
+	for _, rule := range enabled {
+		if rule.ID == ruleID {
+			continue
+		}
+		ruleLanguage := rulepack.ConditionLanguage(rule.Conditions)
+		ruleExpressions := rulepack.ConditionExpressions(rule.Conditions)
+		ruleWindows, err := engine.ParseWindows(rule.Conditions)
+		if err != nil || !s.compiles(ruleLanguage, ruleExpressions) {
+			s.logger.Warn("Leaving rule that does not compile out of backtest",
+				"backtest_id", backtest.ID,
+				"rule_id", rule.ID)
+			continue
+		}
+		rules = append(rules, engine.MatcherRule{ID: rule.ID, Language: ruleLanguage, Expressions: ruleExpressions, Windows: ruleWindows})
+		ruleNames[rule.ID] = rule.Name
+	}
+
+	matcher, err := s.engine.NewMatcher(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	tally := NewTally(draftID, backtest.SampleSize)
+	maxEvents := s.config.Rules.Backtest.MaxEvents
+	err = s.source.Replay(ctx, backtest.WindowStart, backtest.WindowEnd, func(event map[string]interface{}, at time.Time) error {
+		if maxEvents > 0 && tally.Scanned() >= maxEvents {
+			return errEventLimit
+		}
+		if matcher.Excluded(event) {
+			tally.AddExcluded()
+			return nil
+		}
+
+		match, err := matcher.Match(ctx, event, at)
+		if err != nil {
+			return err
+		}
+		tally.Add(event, at, match)
+		return nil
+	})
+	truncated := errors.Is(err, errEventLimit)
+	if err != nil && !truncated {
+		return nil, fmt.Errorf("failed to replay events: %w", err)
+	}
+
+	falsePositives := map[string]FalsePositives{}
+	if overlapping := tally.OverlappingRules(); len(overlapping) > 0 {
+		since := time.Now().Add(-s.config.Rules.Backtest.FalsePositiveLookback)
+		resolutions, err := s.alertRepo.CountResolutionsByRule(ctx, overlapping, since)
+		if err != nil {
+			return nil, err
+		}
+		falsePositives = CountFalsePositives(resolutions)
+	}
+
+	return tally.Result(backtest.WindowStart, backtest.WindowEnd, ruleNames, falsePositives, truncated), nil
+}
+
+// compiles reports whether every expression compiles in the language
+func (s *Service) compiles(language string, expressions []string) bool {
+	for _, expression := range expressions {
+		if err := s.engine.CompileExpression(language, expression); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
@@ -0,0 +1,266 @@
+package backtest
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/engine"
+	"github.com/aegis-shield/services/alerting-engine/internal/training"
+)
+
+// Result is the projection of how a draft rule would have behaved over a
+// historical window
+type Result struct {
+	EventsScanned         int            `json:"events_scanned"`
+	EventsExcluded        int            `json:"events_excluded"` // suppressed by the exclusion list, as live evaluation would
+	Matches               int            `json:"matches"`
+	MatchRate             float64        `json:"match_rate"`
+	ProjectedAlertsPerDay float64        `json:"projected_alerts_per_day"`
+	MatchesByDay          map[string]int `json:"matches_by_day"`
+	EvaluationErrors      int            `json:"evaluation_errors"`
+	FirstEvaluationError  string         `json:"first_evaluation_error,omitempty"`
+	Truncated             bool           `json:"truncated"` // the event limit was reached before the end of the window
+	Samples               []*Sample      `json:"samples"`
+	Overlap               *Overlap       `json:"overlap"`
+}
+
+// Sample is one event the draft rule matched
+type Sample struct {
+	EventID       string                 `json:"event_id,omitempty"`
+	OccurredAt    time.Time              `json:"occurred_at"`
+	EntityIDs     []string               `json:"entity_ids,omitempty"`
+	AlsoMatchedBy []string               `json:"also_matched_by,omitempty"`
+	Event         map[string]interface{} `json:"event"`
+}
+
+// Overlap compares the draft rule's matches with those of the enabled rules
+type Overlap struct {
+	OverlappingMatches int     `json:"overlapping_matches"` // draft matches an enabled rule also matched
+	NovelMatches       int     `json:"novel_matches"`
+	OverlapRatio       float64 `json:"overlap_ratio"`
+	// EstimatedFalsePositiveOverlap is the share of the draft's matches
+	// expected to be false positives that an enabled rule already raises, from
+	// how the enabled rules' alerts were resolved
+	EstimatedFalsePositiveOverlap float64        `json:"estimated_false_positive_overlap"`
+	EstimatedFalsePositiveMatches float64        `json:"estimated_false_positive_matches"`
+	Rules                         []*RuleOverlap `json:"rules"`
+}
+
+// RuleOverlap is how often one enabled rule matched alongside the draft
+type RuleOverlap struct {
+	RuleID            string   `json:"rule_id"`
+	RuleName          string   `json:"rule_name"`
+	SharedMatches     int      `json:"shared_matches"`
+	OverlapRatio      float64  `json:"overlap_ratio"`
+	ResolvedAlerts    int      `json:"resolved_alerts"`
+	FalsePositiveRate *float64 `json:"false_positive_rate,omitempty"` // unset when none of its alerts were resolved
+}
+
+// FalsePositives is how an enabled rule's recent alerts were resolved
+type FalsePositives struct {
+	Resolved       int
+	FalsePositives int
+}
+
+// Rate returns the share of resolved alerts that were false positives
+func (f FalsePositives) Rate() (float64, bool) {
+	if f.Resolved == 0 {
+		return 0, false
+	}
+	return float64(f.FalsePositives) / float64(f.Resolved), true
+}
+
+// CountFalsePositives reduces resolution reason counts per rule to false
+// positive counts, normalizing reasons the way training sessions score them
+func CountFalsePositives(resolutions map[string]map[string]int) map[string]FalsePositives {
+	counts := make(map[string]FalsePositives, len(resolutions))
+	for ruleID, reasons := range resolutions {
+		var count FalsePositives
+		for reason, n := range reasons {
+			count.Resolved += n
+			if training.NormalizeDisposition(reason) == training.DispositionFalsePositive {
+				count.FalsePositives += n
+			}
+		}
+		counts[ruleID] = count
+	}
+	return counts
+}
+
+// Tally accumulates a backtest event by event. Samples are drawn uniformly
+// from all of the draft's matches rather than the first ones replayed.
+type Tally struct {
+	draftID          string
+	sampleSize       int
+	random           *rand.Rand
+	scanned          int
+	excluded         int
+	matches          int
+	evaluationErrors int
+	firstError       string
+	byDay            map[string]int
+	shared           map[string]int
+	overlapSets      map[string]int // enabled rules that matched alongside the draft, comma-joined
+	samples          []*Sample
+}
+
+// NewTally creates a tally for the draft rule evaluated under draftID
+func NewTally(draftID string, sampleSize int) *Tally {
+	return &Tally{
+		draftID:     draftID,
+		sampleSize:  sampleSize,
+		random:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		byDay:       make(map[string]int),
+		shared:      make(map[string]int),
+		overlapSets: make(map[string]int),
+	}
+}
+
+// Scanned returns the number of events tallied so far
+func (t *Tally) Scanned() int {
+	return t.scanned
+}
+
+// AddExcluded counts an event the exclusion list suppresses
+func (t *Tally) AddExcluded() {
+	t.scanned++
+	t.excluded++
+}
+
+// Add counts an event and the rules that matched it
+func (t *Tally) Add(event map[string]interface{}, at time.Time, match *engine.MatchResult) {
+	t.scanned++
+
+	if err, failed := match.Errors[t.draftID]; failed {
+		t.evaluationErrors++
+		if t.firstError == "" {
+			t.firstError = err.Error()
+		}
+		return
+	}
+
+	draftMatched := false
+	var alongside []string
+	for _, ruleID := range match.Matched {
+		if ruleID == t.draftID {
+			draftMatched = true
+			continue
+		}
+		alongside = append(alongside, ruleID)
+	}
+	if !draftMatched {
+		return
+	}
+
+	t.matches++
+	t.byDay[at.UTC().Format("2006-01-02")]++
+	for _, ruleID := range alongside {
+		t.shared[ruleID]++
+	}
+	if len(alongside) > 0 {
+		sort.Strings(alongside)
+		t.overlapSets[strings.Join(alongside, ",")]++
+	}
+
+	sample := &Sample{
+		EventID:       eventID(event),
+		OccurredAt:    at.UTC(),
+		EntityIDs:     engine.EventEntityIDs(event),
+		AlsoMatchedBy: alongside,
+		Event:         event,
+	}
+	if len(t.samples) < t.sampleSize {
+		t.samples = append(t.samples, sample)
+	} else if i := t.random.Intn(t.matches); i < t.sampleSize {
+		t.samples[i] = sample
+	}
+}
+
+// OverlappingRules returns the enabled rules that matched alongside the draft
+func (t *Tally) OverlappingRules() []string {
+	ruleIDs := make([]string, 0, len(t.shared))
+	for ruleID := range t.shared {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+	return ruleIDs
+}
+
+// Result projects the tally over the window it was replayed from. An
+// overlapping match is weighed by the mean false positive rate of the
+// enabled rules that matched it and have resolved alerts.
+func (t *Tally) Result(from, to time.Time, ruleNames map[string]string, falsePositives map[string]FalsePositives, truncated bool) *Result {
+	result := &Result{
+		EventsScanned:        t.scanned,
+		EventsExcluded:       t.excluded,
+		Matches:              t.matches,
+		MatchesByDay:         t.byDay,
+		EvaluationErrors:     t.evaluationErrors,
+		FirstEvaluationError: t.firstError,
+		Truncated:            truncated,
+		Samples:              append([]*Sample{}, t.samples...),
+		Overlap:              &Overlap{Rules: []*RuleOverlap{}},
+	}
+
+	if evaluated := t.scanned - t.excluded; evaluated > 0 {
+		result.MatchRate = float64(t.matches) / float64(evaluated)
+	}
+	if days := to.Sub(from).Hours() / 24; days > 0 {
+		result.ProjectedAlertsPerDay = float64(t.matches) / days
+	}
+	sort.Slice(result.Samples, func(i, j int) bool {
+		return result.Samples[i].OccurredAt.Before(result.Samples[j].OccurredAt)
+	})
+
+	overlap := result.Overlap
+	for set, count := range t.overlapSets {
+		overlap.OverlappingMatches += count
+
+		var rateSum float64
+		var rated int
+		for _, ruleID := range strings.Split(set, ",") {
+			if rate, ok := falsePositives[ruleID].Rate(); ok {
+				rateSum += rate
+				rated++
+			}
+		}
+		if rated > 0 {
+			overlap.EstimatedFalsePositiveMatches += float64(count) * rateSum / float64(rated)
+		}
+	}
+	overlap.NovelMatches = t.matches - overlap.OverlappingMatches
+	if t.matches > 0 {
+		overlap.OverlapRatio = float64(overlap.OverlappingMatches) / float64(t.matches)
+		overlap.EstimatedFalsePositiveOverlap = overlap.EstimatedFalsePositiveMatches / float64(t.matches)
+	}
+
+	for _, ruleID := range t.OverlappingRules() {
+		ruleOverlap := &RuleOverlap{
+			RuleID:         ruleID,
+			RuleName:       ruleNames[ruleID],
+			SharedMatches:  t.shared[ruleID],
+			OverlapRatio:   float64(t.shared[ruleID]) / float64(t.matches),
+			ResolvedAlerts: falsePositives[ruleID].Resolved,
+		}
+		if rate, ok := falsePositives[ruleID].Rate(); ok {
+			ruleOverlap.FalsePositiveRate = &rate
+		}
+		overlap.Rules = append(overlap.Rules, ruleOverlap)
+	}
+	sort.SliceStable(overlap.Rules, func(i, j int) bool {
+		return overlap.Rules[i].SharedMatches > overlap.Rules[j].SharedMatches
+	})
+
+	return result
+}
+
+// eventID returns an event's ID, if it has one
+func eventID(event map[string]interface{}) string {
+	if id, ok := event["id"]; ok && id != nil {
+		return fmt.Sprint(id)
+	}
+	return ""
+}
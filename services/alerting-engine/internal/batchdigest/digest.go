@@ -0,0 +1,300 @@
+package batchdigest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// AlertSource marks alerts generated by the end-of-day batch run so they are
+// never mistaken for intraday alerts during reconciliation
+const AlertSource = "batch-eod"
+
+// Finding is a batch rule that fired for one entity of a portfolio
+type Finding struct {
+	Rule        *database.BatchRule `json:"-"`
+	RuleID      string              `json:"rule_id"`
+	RuleName    string              `json:"rule_name"`
+	PortfolioID string              `json:"portfolio_id"`
+	EntityID    string              `json:"entity_id"`
+	Value       float64             `json:"value"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+	AlertID     string              `json:"alert_id,omitempty"`
+	New         bool                `json:"new"`
+}
+
+// ValidateRule checks a batch rule definition before it is stored
+func ValidateRule(rule *database.BatchRule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if strings.TrimSpace(rule.Metric) == "" {
+		return fmt.Errorf("metric is required")
+	}
+	if strings.TrimSpace(rule.AlertType) == "" {
+		return fmt.Errorf("alert_type is required")
+	}
+
+	switch rule.Aggregation {
+	case "sum", "count", "max":
+	default:
+		return fmt.Errorf("unsupported aggregation %q", rule.Aggregation)
+	}
+
+	switch rule.Operator {
+	case "gt", "gte", "lt", "lte":
+	default:
+		return fmt.Errorf("unsupported operator %q", rule.Operator)
+	}
+
+	if severityRank(rule.Severity) == 0 {
+		return fmt.Errorf("unsupported severity %q", rule.Severity)
+	}
+
+	return nil
+}
+
+// ValidatePortfolio checks a portfolio definition before it is stored
+func ValidatePortfolio(portfolio *database.Portfolio) error {
+	if strings.TrimSpace(portfolio.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if strings.TrimSpace(portfolio.ManagerID) == "" {
+		return fmt.Errorf("manager_id is required")
+	}
+	if strings.TrimSpace(portfolio.ManagerContact) == "" {
+		return fmt.Errorf("manager_contact is required")
+	}
+
+	switch portfolio.NotificationChannel {
+	case "email", "slack", "teams", "webhook":
+	default:
+		return fmt.Errorf("unsupported notification_channel %q", portfolio.NotificationChannel)
+	}
+
+	return nil
+}
+
+// AggregateValue returns the aggregate a rule compares against its threshold
+func AggregateValue(aggregate *database.DailyAggregate, aggregation string) float64 {
+	switch aggregation {
+	case "count":
+		return float64(aggregate.Count)
+	case "max":
+		return aggregate.MaxValue
+	default:
+		return aggregate.Total
+	}
+}
+
+// Compare applies a rule operator
+func Compare(value float64, operator string, threshold float64) bool {
+	switch operator {
+	case "gt":
+		return value > threshold
+	case "gte":
+		return value >= threshold
+	case "lt":
+		return value < threshold
+	case "lte":
+		return value <= threshold
+	}
+	return false
+}
+
+// AppliesTo reports whether a rule covers a portfolio; rules without
+// portfolios cover all of them
+func AppliesTo(rule *database.BatchRule, portfolioID string) bool {
+	return len(rule.PortfolioIDs) == 0 || contains(rule.PortfolioIDs, portfolioID)
+}
+
+// Fingerprint identifies the batch alert for a rule, portfolio, entity and
+// business day, so reruns of the same day do not raise it twice
+func Fingerprint(ruleID, portfolioID, entityID, businessDate string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{AlertSource, ruleID, portfolioID, entityID, businessDate}, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Evaluate runs the batch rules over a portfolio's aggregates for a business
+// day. Findings are ordered by severity, then rule name and entity.
+func Evaluate(businessDate string, rules []*database.BatchRule, portfolio *database.Portfolio, aggregates []*database.DailyAggregate) []*Finding {
+	members := make(map[string]bool, len(portfolio.EntityIDs))
+	for _, id := range portfolio.EntityIDs {
+		members[id] = true
+	}
+
+	var findings []*Finding
+	for _, rule := range rules {
+		if !rule.Enabled || !AppliesTo(rule, portfolio.ID) {
+			continue
+		}
+
+		for _, aggregate := range aggregates {
+			if aggregate.Metric != rule.Metric || !members[aggregate.EntityID] {
+				continue
+			}
+
+			value := AggregateValue(aggregate, rule.Aggregation)
+			if !Compare(value, rule.Operator, rule.Threshold) {
+				continue
+			}
+
+			findings = append(findings, &Finding{
+				Rule:        rule,
+				RuleID:      rule.ID,
+				RuleName:    rule.Name,
+				PortfolioID: portfolio.ID,
+				EntityID:    aggregate.EntityID,
+				Value:       value,
+				Severity:    rule.Severity,
+				Fingerprint: Fingerprint(rule.ID, portfolio.ID, aggregate.EntityID, businessDate),
+			})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if severityRank(a.Severity) != severityRank(b.Severity) {
+			return severityRank(a.Severity) > severityRank(b.Severity)
+		}
+		if a.RuleName != b.RuleName {
+			return a.RuleName < b.RuleName
+		}
+		return a.EntityID < b.EntityID
+	})
+
+	return findings
+}
+
+// Reconcile removes findings already raised intraday. A finding duplicates
+// an intraday alert on the same entity from one of the rule's linked intraday
+// rules or, when the rule links none, of the same alert type.
+func Reconcile(findings []*Finding, intraday []*database.Alert) ([]*Finding, []database.Suppression) {
+	kept := make([]*Finding, 0, len(findings))
+	var suppressed []database.Suppression
+
+	for _, finding := range findings {
+		if alert := findIntradayMatch(finding, intraday); alert != nil {
+			suppressed = append(suppressed, database.Suppression{
+				RuleID:          finding.RuleID,
+				RuleName:        finding.RuleName,
+				EntityID:        finding.EntityID,
+				Value:           finding.Value,
+				IntradayAlertID: alert.ID,
+			})
+			continue
+		}
+		kept = append(kept, finding)
+	}
+
+	return kept, suppressed
+}
+
+func findIntradayMatch(finding *Finding, intraday []*database.Alert) *database.Alert {
+	for _, alert := range intraday {
+		if alert.Source == AlertSource || !contains(alert.EntityIDs, finding.EntityID) {
+			continue
+		}
+
+		if len(finding.Rule.IntradayRuleIDs) > 0 {
+			if contains(finding.Rule.IntradayRuleIDs, alert.RuleID) {
+				return alert
+			}
+			continue
+		}
+
+		if alert.Type == finding.Rule.AlertType {
+			return alert
+		}
+	}
+	return nil
+}
+
+// Render builds the subject and body of a portfolio manager's digest,
+// listing at most maxFindings alerts
+func Render(portfolio *database.Portfolio, businessDate string, findings []*Finding, suppressed []database.Suppression, maxFindings int) (string, string) {
+	urgent := 0
+	for _, finding := range findings {
+		if severityRank(finding.Severity) >= severityRank("high") {
+			urgent++
+		}
+	}
+
+	subject := fmt.Sprintf("End-of-day alert digest: %s, %s (%d alerts", portfolio.Name, businessDate, len(findings))
+	if urgent > 0 {
+		subject += fmt.Sprintf(", %d high or critical", urgent)
+	}
+	subject += ")"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Batch rule results for portfolio %s on %s.\n", portfolio.Name, businessDate)
+
+	if len(findings) == 0 {
+		b.WriteString("\nNo batch rules fired for this portfolio.\n")
+	} else {
+		fmt.Fprintf(&b, "\nAlerts (%d):\n", len(findings))
+		for i, finding := range findings {
+			if maxFindings > 0 && i >= maxFindings {
+				fmt.Fprintf(&b, "... and %d more\n", len(findings)-maxFindings)
+				break
+			}
+			fmt.Fprintf(&b, "- [%s] %s: entity %s, %s of %s %s %s (alert %s)\n",
+				strings.ToUpper(finding.Severity), finding.RuleName, finding.EntityID,
+				finding.Rule.Aggregation, finding.Rule.Metric, formatValue(finding.Value),
+				describeCondition(finding.Rule), finding.AlertID)
+		}
+	}
+
+	if len(suppressed) > 0 {
+		fmt.Fprintf(&b, "\nAlready raised intraday (%d):\n", len(suppressed))
+		for i, s := range suppressed {
+			if maxFindings > 0 && i >= maxFindings {
+				fmt.Fprintf(&b, "... and %d more\n", len(suppressed)-maxFindings)
+				break
+			}
+			fmt.Fprintf(&b, "- %s: entity %s (intraday alert %s)\n", s.RuleName, s.EntityID, s.IntradayAlertID)
+		}
+	}
+
+	return subject, b.String()
+}
+
+func describeCondition(rule *database.BatchRule) string {
+	symbols := map[string]string{"gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+	return fmt.Sprintf("%s %s", symbols[rule.Operator], formatValue(rule.Threshold))
+}
+
+func formatValue(v float64) string {
+	if v == float64(int64(v)) {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	}
+	return 0
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,595 @@
+package batchdigest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+const businessDateLayout = "2006-01-02"
+
+// ErrInvalidBusinessDate is returned when a business date is not YYYY-MM-DD
+var ErrInvalidBusinessDate = errors.New("business_date must be YYYY-MM-DD")
+
+// Service evaluates batch rules over each business day's aggregates and sends
+// one digest per portfolio manager, leaving out findings already alerted intraday
+type Service struct {
+	config           *config.Config
+	logger           *slog.Logger
+	repo             *database.BatchDigestRepository
+	alertRepo        *database.AlertRepository
+	notificationRepo *database.NotificationRepository
+	location         *time.Location
+}
+
+// PortfolioInput describes a portfolio to create or update
+type PortfolioInput struct {
+	Name                string   `json:"name"`
+	ManagerID           string   `json:"manager_id"`
+	ManagerContact      string   `json:"manager_contact"`
+	NotificationChannel string   `json:"notification_channel,omitempty"`
+	EntityIDs           []string `json:"entity_ids"`
+	Enabled             *bool    `json:"enabled,omitempty"`
+	Actor               string   `json:"actor"`
+}
+
+// RuleInput describes a batch rule to create or update
+type RuleInput struct {
+	Name            string   `json:"name"`
+	Description     string   `json:"description,omitempty"`
+	Metric          string   `json:"metric"`
+	Aggregation     string   `json:"aggregation,omitempty"`
+	Operator        string   `json:"operator,omitempty"`
+	Threshold       float64  `json:"threshold"`
+	Severity        string   `json:"severity,omitempty"`
+	AlertType       string   `json:"alert_type"`
+	IntradayRuleIDs []string `json:"intraday_rule_ids,omitempty"`
+	PortfolioIDs    []string `json:"portfolio_ids,omitempty"`
+	Enabled         *bool    `json:"enabled,omitempty"`
+	Actor           string   `json:"actor"`
+}
+
+// PortfolioResult summarizes the digest produced for one portfolio
+type PortfolioResult struct {
+	PortfolioID          string `json:"portfolio_id"`
+	DigestID             string `json:"digest_id,omitempty"`
+	NotificationID       string `json:"notification_id,omitempty"`
+	AlertsGenerated      int    `json:"alerts_generated"`
+	DuplicatesSuppressed int    `json:"duplicates_suppressed"`
+	AlreadyGenerated     int    `json:"already_generated"`
+	Error                string `json:"error,omitempty"`
+}
+
+// RunResult summarizes an end-of-day run
+type RunResult struct {
+	*database.BatchDigestRun
+	Results []*PortfolioResult `json:"results"`
+}
+
+// NewService creates a new batch digest service
+func NewService(cfg *config.Config, logger *slog.Logger, repo *database.BatchDigestRepository, alertRepo *database.AlertRepository, notificationRepo *database.NotificationRepository) *Service {
+	location, err := time.LoadLocation(cfg.BatchDigest.Timezone)
+	if err != nil {
+		logger.Warn("Invalid batch digest timezone, using UTC",
+			"timezone", cfg.BatchDigest.Timezone,
+			"error", err)
+		location = time.UTC
+	}
+
+	return &Service{
+		config:           cfg,
+		logger:           logger,
+		repo:             repo,
+		alertRepo:        alertRepo,
+		notificationRepo: notificationRepo,
+		location:         location,
+	}
+}
+
+// Portfolio management
+
+// CreatePortfolio validates and stores a portfolio
+func (s *Service) CreatePortfolio(ctx context.Context, input PortfolioInput) (*database.Portfolio, error) {
+	if input.Actor == "" {
+		return nil, fmt.Errorf("actor is required")
+	}
+
+	portfolio := &database.Portfolio{
+		ID:        generateID("portfolio"),
+		Enabled:   true,
+		CreatedBy: input.Actor,
+	}
+	s.applyPortfolioInput(portfolio, input)
+
+	if err := ValidatePortfolio(portfolio); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreatePortfolio(ctx, portfolio); err != nil {
+		return nil, err
+	}
+
+	return portfolio, nil
+}
+
+// UpdatePortfolio replaces a portfolio's definition
+func (s *Service) UpdatePortfolio(ctx context.Context, id string, input PortfolioInput) (*database.Portfolio, error) {
+	if input.Actor == "" {
+		return nil, fmt.Errorf("actor is required")
+	}
+
+	portfolio, err := s.repo.GetPortfolio(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.applyPortfolioInput(portfolio, input)
+	portfolio.UpdatedBy = optionalString(input.Actor)
+
+	if err := ValidatePortfolio(portfolio); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdatePortfolio(ctx, portfolio); err != nil {
+		return nil, err
+	}
+
+	return portfolio, nil
+}
+
+// GetPortfolio returns a portfolio
+func (s *Service) GetPortfolio(ctx context.Context, id string) (*database.Portfolio, error) {
+	return s.repo.GetPortfolio(ctx, id)
+}
+
+// ListPortfolios returns every portfolio
+func (s *Service) ListPortfolios(ctx context.Context) ([]*database.Portfolio, error) {
+	return s.repo.ListPortfolios(ctx, false)
+}
+
+// DeletePortfolio removes a portfolio
+func (s *Service) DeletePortfolio(ctx context.Context, id string) error {
+	return s.repo.DeletePortfolio(ctx, id)
+}
+
+// Rule management
+
+// CreateRule validates and stores a batch rule
+func (s *Service) CreateRule(ctx context.Context, input RuleInput) (*database.BatchRule, error) {
+	if input.Actor == "" {
+		return nil, fmt.Errorf("actor is required")
+	}
+
+	rule := &database.BatchRule{
+		ID:        generateID("batch_rule"),
+		Enabled:   true,
+		CreatedBy: input.Actor,
+	}
+	applyRuleInput(rule, input)
+
+	if err := ValidateRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateBatchRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// UpdateRule replaces a batch rule's definition
+func (s *Service) UpdateRule(ctx context.Context, id string, input RuleInput) (*database.BatchRule, error) {
+	if input.Actor == "" {
+		return nil, fmt.Errorf("actor is required")
+	}
+
+	rule, err := s.repo.GetBatchRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	applyRuleInput(rule, input)
+	rule.UpdatedBy = optionalString(input.Actor)
+
+	if err := ValidateRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateBatchRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// GetRule returns a batch rule
+func (s *Service) GetRule(ctx context.Context, id string) (*database.BatchRule, error) {
+	return s.repo.GetBatchRule(ctx, id)
+}
+
+// ListRules returns every batch rule
+func (s *Service) ListRules(ctx context.Context) ([]*database.BatchRule, error) {
+	return s.repo.ListBatchRules(ctx, false)
+}
+
+// DeleteRule removes a batch rule
+func (s *Service) DeleteRule(ctx context.Context, id string) error {
+	return s.repo.DeleteBatchRule(ctx, id)
+}
+
+// Aggregates
+
+// IngestAggregates loads aggregated activity from upstream batch jobs. Loads
+// for the same day, entity and metric accumulate.
+func (s *Service) IngestAggregates(ctx context.Context, aggregates []*database.DailyAggregate) error {
+	if len(aggregates) == 0 {
+		return fmt.Errorf("no aggregates provided")
+	}
+	if max := s.config.BatchDigest.MaxAggregateBatch; max > 0 && len(aggregates) > max {
+		return fmt.Errorf("at most %d aggregates may be loaded at once", max)
+	}
+
+	for i, aggregate := range aggregates {
+		if _, err := time.Parse(businessDateLayout, aggregate.BusinessDate); err != nil {
+			return fmt.Errorf("aggregate %d: %w", i, ErrInvalidBusinessDate)
+		}
+		if aggregate.EntityID == "" || aggregate.Metric == "" {
+			return fmt.Errorf("aggregate %d: entity_id and metric are required", i)
+		}
+		if aggregate.Count < 0 {
+			return fmt.Errorf("aggregate %d: count must not be negative", i)
+		}
+	}
+
+	return s.repo.UpsertAggregates(ctx, aggregates)
+}
+
+// Runs and digests
+
+// BusinessDate returns the business day a time falls on in the configured timezone
+func (s *Service) BusinessDate(t time.Time) string {
+	return t.In(s.location).Format(businessDateLayout)
+}
+
+// RunForDate evaluates the batch rules for a business day and sends each
+// portfolio manager one digest. An empty date runs the current business day.
+// Reruns of the same day reuse alerts already generated and only notify
+// managers when new alerts were raised.
+func (s *Service) RunForDate(ctx context.Context, businessDate, triggeredBy string) (*RunResult, error) {
+	if businessDate == "" {
+		businessDate = s.BusinessDate(time.Now())
+	}
+	dayStart, err := time.ParseInLocation(businessDateLayout, businessDate, s.location)
+	if err != nil {
+		return nil, ErrInvalidBusinessDate
+	}
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	run := &database.BatchDigestRun{
+		ID:           generateID("batch_run"),
+		BusinessDate: businessDate,
+		Status:       database.BatchRunStatusRunning,
+		TriggeredBy:  defaultString(triggeredBy, "system"),
+		StartedAt:    time.Now(),
+	}
+	if err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	result := &RunResult{BatchDigestRun: run, Results: []*PortfolioResult{}}
+	if err := s.run(ctx, result, dayStart, dayEnd); err != nil {
+		run.ErrorMessage = optionalString(err.Error())
+	}
+
+	completedAt := time.Now()
+	run.CompletedAt = &completedAt
+	run.Status = database.BatchRunStatusCompleted
+	if run.ErrorMessage != nil {
+		run.Status = database.BatchRunStatusFailed
+	}
+
+	if err := s.repo.CompleteRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Batch digest run completed",
+		"run_id", run.ID,
+		"business_date", businessDate,
+		"status", run.Status,
+		"portfolios", run.Portfolios,
+		"alerts_generated", run.AlertsGenerated,
+		"duplicates_suppressed", run.DuplicatesSuppressed,
+		"already_generated", run.AlreadyGenerated)
+
+	return result, nil
+}
+
+func (s *Service) run(ctx context.Context, result *RunResult, dayStart, dayEnd time.Time) error {
+	portfolios, err := s.repo.ListPortfolios(ctx, true)
+	if err != nil {
+		return err
+	}
+	rules, err := s.repo.ListBatchRules(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	var failures []string
+	for _, portfolio := range portfolios {
+		portfolioResult, err := s.processPortfolio(ctx, result.BatchDigestRun, portfolio, rules, dayStart, dayEnd)
+		if err != nil {
+			s.logger.Error("Failed to build batch digest",
+				"run_id", result.ID,
+				"portfolio_id", portfolio.ID,
+				"error", err)
+			portfolioResult = &PortfolioResult{PortfolioID: portfolio.ID, Error: err.Error()}
+			failures = append(failures, fmt.Sprintf("%s: %v", portfolio.ID, err))
+		}
+
+		result.Results = append(result.Results, portfolioResult)
+		result.Portfolios++
+		result.AlertsGenerated += portfolioResult.AlertsGenerated
+		result.DuplicatesSuppressed += portfolioResult.DuplicatesSuppressed
+		result.AlreadyGenerated += portfolioResult.AlreadyGenerated
+	}
+
+	if len(failures) > 0 {
+		return errors.New(truncate(strings.Join(failures, "; "), 2000))
+	}
+	return nil
+}
+
+func (s *Service) processPortfolio(ctx context.Context, run *database.BatchDigestRun, portfolio *database.Portfolio, rules []*database.BatchRule, dayStart, dayEnd time.Time) (*PortfolioResult, error) {
+	result := &PortfolioResult{PortfolioID: portfolio.ID}
+
+	var findings []*Finding
+	var suppressed []database.Suppression
+	if len(portfolio.EntityIDs) > 0 {
+		aggregates, err := s.repo.ListAggregates(ctx, run.BusinessDate, portfolio.EntityIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		findings = Evaluate(run.BusinessDate, rules, portfolio, aggregates)
+		if len(findings) > 0 {
+			intraday, err := s.repo.ListIntradayAlerts(ctx, dayStart, dayEnd, portfolio.EntityIDs, AlertSource)
+			if err != nil {
+				return nil, err
+			}
+			findings, suppressed = Reconcile(findings, intraday)
+		}
+	}
+	result.DuplicatesSuppressed = len(suppressed)
+
+	if err := s.raiseAlerts(ctx, run, findings, result); err != nil {
+		return nil, err
+	}
+
+	subject, body := Render(portfolio, run.BusinessDate, findings, suppressed, s.config.BatchDigest.MaxFindingsPerDigest)
+	digest := &database.BatchDigest{
+		ID:           generateID("digest"),
+		RunID:        run.ID,
+		BusinessDate: run.BusinessDate,
+		PortfolioID:  portfolio.ID,
+		ManagerID:    portfolio.ManagerID,
+		AlertIDs:     make([]string, 0, len(findings)),
+		Suppressed:   suppressed,
+		Subject:      subject,
+		Body:         body,
+	}
+	for _, finding := range findings {
+		digest.AlertIDs = append(digest.AlertIDs, finding.AlertID)
+	}
+
+	if err := s.repo.SaveDigest(ctx, digest); err != nil {
+		return nil, err
+	}
+	result.DigestID = digest.ID
+
+	// Managers hear about a day again only when a rerun raised something new
+	if result.AlertsGenerated == 0 {
+		return result, nil
+	}
+
+	notificationID, err := s.queueNotification(ctx, portfolio, digest, findings)
+	if err != nil {
+		return nil, err
+	}
+	result.NotificationID = notificationID
+
+	return result, nil
+}
+
+// raiseAlerts creates an alert for each new finding and links findings raised
+// by an earlier run of the same day to their existing alert
+func (s *Service) raiseAlerts(ctx context.Context, run *database.BatchDigestRun, findings []*Finding, result *PortfolioResult) error {
+	if len(findings) == 0 {
+		return nil
+	}
+
+	fingerprints := make([]string, len(findings))
+	for i, finding := range findings {
+		fingerprints[i] = finding.Fingerprint
+	}
+	existing, err := s.repo.ListAlertFingerprints(ctx, fingerprints)
+	if err != nil {
+		return err
+	}
+
+	for _, finding := range findings {
+		if alertID, ok := existing[finding.Fingerprint]; ok {
+			finding.AlertID = alertID
+			result.AlreadyGenerated++
+			continue
+		}
+
+		rule := finding.Rule
+		alert := &database.Alert{
+			ID:       generateID("alert"),
+			RuleID:   rule.ID,
+			RuleName: rule.Name,
+			Type:     rule.AlertType,
+			Severity: rule.Severity,
+			Priority: rule.Severity,
+			Status:   "active",
+			Title:    fmt.Sprintf("%s: %s", rule.Name, finding.EntityID),
+			Description: fmt.Sprintf("End-of-day %s of %s for %s on %s was %s, %s",
+				rule.Aggregation, rule.Metric, finding.EntityID, run.BusinessDate,
+				formatValue(finding.Value), describeCondition(rule)),
+			Source:      AlertSource,
+			EntityIDs:   []string{finding.EntityID},
+			Tags:        []string{"batch", "end-of-day"},
+			Fingerprint: finding.Fingerprint,
+			Metadata: map[string]interface{}{
+				"batch_run_id":  run.ID,
+				"business_date": run.BusinessDate,
+				"portfolio_id":  finding.PortfolioID,
+				"metric":        rule.Metric,
+				"aggregation":   rule.Aggregation,
+				"operator":      rule.Operator,
+				"threshold":     rule.Threshold,
+				"value":         finding.Value,
+			},
+		}
+
+		if err := s.alertRepo.Create(ctx, alert); err != nil {
+			return err
+		}
+
+		finding.AlertID = alert.ID
+		finding.New = true
+		result.AlertsGenerated++
+	}
+
+	return nil
+}
+
+// queueNotification queues the digest for delivery to the portfolio manager.
+// Notifications belong to an alert, so the digest is attached to its most
+// severe new alert.
+func (s *Service) queueNotification(ctx context.Context, portfolio *database.Portfolio, digest *database.BatchDigest, findings []*Finding) (string, error) {
+	var anchor *Finding
+	for _, finding := range findings {
+		if finding.New {
+			anchor = finding
+			break
+		}
+	}
+	if anchor == nil {
+		return "", nil
+	}
+
+	channel := defaultString(portfolio.NotificationChannel, s.config.BatchDigest.NotificationChannel)
+	notification := &database.Notification{
+		ID:          generateID("notification"),
+		AlertID:     anchor.AlertID,
+		Channel:     channel,
+		ChannelType: channel,
+		Recipient:   portfolio.ManagerContact,
+		Subject:     optionalString(digest.Subject),
+		Content:     digest.Body,
+		Status:      "pending",
+		MaxRetries:  3,
+		Metadata: map[string]interface{}{
+			"digest_id":     digest.ID,
+			"portfolio_id":  portfolio.ID,
+			"manager_id":    portfolio.ManagerID,
+			"business_date": digest.BusinessDate,
+			"alert_ids":     []string(digest.AlertIDs),
+		},
+	}
+
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return "", err
+	}
+
+	if err := s.repo.SetDigestNotification(ctx, digest.ID, notification.ID); err != nil {
+		return "", err
+	}
+
+	return notification.ID, nil
+}
+
+// ListRuns returns recent runs, optionally for one business date
+func (s *Service) ListRuns(ctx context.Context, businessDate string, limit int) ([]*database.BatchDigestRun, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	return s.repo.ListRuns(ctx, businessDate, limit)
+}
+
+// ListDigests returns digests filtered by business date, portfolio and manager
+func (s *Service) ListDigests(ctx context.Context, businessDate, portfolioID, managerID string, limit int) ([]*database.BatchDigest, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	return s.repo.ListDigests(ctx, businessDate, portfolioID, managerID, limit)
+}
+
+func (s *Service) applyPortfolioInput(portfolio *database.Portfolio, input PortfolioInput) {
+	portfolio.Name = strings.TrimSpace(input.Name)
+	portfolio.ManagerID = input.ManagerID
+	portfolio.ManagerContact = input.ManagerContact
+	portfolio.NotificationChannel = defaultString(input.NotificationChannel, s.config.BatchDigest.NotificationChannel)
+	portfolio.EntityIDs = dedupe(input.EntityIDs)
+	if input.Enabled != nil {
+		portfolio.Enabled = *input.Enabled
+	}
+}
+
+func applyRuleInput(rule *database.BatchRule, input RuleInput) {
+	rule.Name = strings.TrimSpace(input.Name)
+	rule.Description = optionalString(input.Description)
+	rule.Metric = input.Metric
+	rule.Aggregation = defaultString(input.Aggregation, "sum")
+	rule.Operator = defaultString(input.Operator, "gt")
+	rule.Threshold = input.Threshold
+	rule.Severity = defaultString(input.Severity, "medium")
+	rule.AlertType = input.AlertType
+	rule.IntradayRuleIDs = dedupe(input.IntradayRuleIDs)
+	rule.PortfolioIDs = dedupe(input.PortfolioIDs)
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+}
+
+func dedupe(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" && !contains(result, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func defaultString(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
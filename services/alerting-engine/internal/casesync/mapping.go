@@ -0,0 +1,264 @@
+package casesync
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/alertthread"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the request body, in both directions
+const SignatureHeader = "X-AegisShield-Signature"
+
+// statusOrder ranks alert statuses so external systems can only move alerts forward
+var statusOrder = map[string]int{
+	"open":         0,
+	"active":       0,
+	"suppressed":   0,
+	"escalated":    1,
+	"acknowledged": 2,
+	"resolved":     3,
+}
+
+// BuildPayload renders the outbound body for a lifecycle change. Without
+// field mappings a generic document is sent; otherwise each mapped external
+// field path (dot-separated for nesting) is filled from its alert expression.
+// thread holds the alert comments carried into the case on escalation.
+func BuildPayload(connector *database.CaseSyncConnector, alert *database.Alert, delivery *database.CaseSyncDelivery, thread []*database.AlertComment) (map[string]interface{}, error) {
+	if len(connector.FieldMappings) == 0 {
+		payload := map[string]interface{}{
+			"event":       delivery.EventType,
+			"delivery_id": delivery.ID,
+			"alert_id":    alert.ID,
+			"status":      ExternalStatus(connector, alert.Status),
+			"severity":    alert.Severity,
+			"priority":    alert.Priority,
+			"title":       alert.Title,
+			"description": alert.Description,
+			"rule_id":     alert.RuleID,
+			"entity_ids":  alert.EntityIDs,
+			"created_at":  alert.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if len(thread) > 0 {
+			payload["comments"] = alertthread.CaseComments(thread)
+		}
+		return payload, nil
+	}
+
+	payload := make(map[string]interface{})
+	for field, raw := range connector.FieldMappings {
+		expr, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("mapping for %s must be a string expression", field)
+		}
+
+		value, err := ResolveField(connector, alert, delivery, thread, expr)
+		if err != nil {
+			return nil, fmt.Errorf("mapping for %s: %w", field, err)
+		}
+		if err := setPath(payload, field, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return payload, nil
+}
+
+// ResolveField evaluates a mapping expression against an alert. Expressions
+// are an alert field name, metadata.<key>, event, delivery_id, comments, or =literal.
+func ResolveField(connector *database.CaseSyncConnector, alert *database.Alert, delivery *database.CaseSyncDelivery, thread []*database.AlertComment, expr string) (interface{}, error) {
+	if strings.HasPrefix(expr, "=") {
+		return strings.TrimPrefix(expr, "="), nil
+	}
+	if strings.HasPrefix(expr, "metadata.") {
+		return lookupPath(alert.Metadata, strings.TrimPrefix(expr, "metadata.")), nil
+	}
+
+	switch expr {
+	case "id", "alert_id":
+		return alert.ID, nil
+	case "event":
+		return delivery.EventType, nil
+	case "delivery_id":
+		return delivery.ID, nil
+	case "comments":
+		return alertthread.CaseComments(thread), nil
+	case "status":
+		return ExternalStatus(connector, alert.Status), nil
+	case "internal_status":
+		return alert.Status, nil
+	case "title":
+		return alert.Title, nil
+	case "description":
+		return alert.Description, nil
+	case "severity":
+		return alert.Severity, nil
+	case "priority":
+		return alert.Priority, nil
+	case "type":
+		return alert.Type, nil
+	case "source":
+		return alert.Source, nil
+	case "rule_id":
+		return alert.RuleID, nil
+	case "rule_name":
+		return alert.RuleName, nil
+	case "entity_ids":
+		return alert.EntityIDs, nil
+	case "tags":
+		return alert.Tags, nil
+	case "escalation_level":
+		return alert.EscalationLevel, nil
+	case "assigned_to":
+		return derefString(alert.AssignedTo), nil
+	case "acknowledged_by":
+		return derefString(alert.AcknowledgedBy), nil
+	case "resolved_by":
+		return derefString(alert.ResolvedBy), nil
+	case "resolution_reason":
+		return derefString(alert.ResolutionReason), nil
+	case "created_at":
+		return alert.CreatedAt.UTC().Format(time.RFC3339), nil
+	case "updated_at":
+		return alert.UpdatedAt.UTC().Format(time.RFC3339), nil
+	default:
+		return nil, fmt.Errorf("unknown alert field %q", expr)
+	}
+}
+
+// ExternalStatus maps an alert status to the connector's case status
+func ExternalStatus(connector *database.CaseSyncConnector, status string) string {
+	if mapped, ok := connector.StatusMappings[status].(string); ok && mapped != "" {
+		return mapped
+	}
+	return status
+}
+
+// InternalStatus maps an external case status back to an alert status. When
+// several alert statuses share an external value the most advanced one wins.
+func InternalStatus(connector *database.CaseSyncConnector, external string) (string, bool) {
+	best, found := "", false
+	for internal, raw := range connector.StatusMappings {
+		mapped, ok := raw.(string)
+		if !ok || !strings.EqualFold(mapped, external) {
+			continue
+		}
+		if !found || statusOrder[internal] > statusOrder[best] ||
+			(statusOrder[internal] == statusOrder[best] && internal < best) {
+			best, found = internal, true
+		}
+	}
+
+	if !found {
+		if _, known := statusOrder[strings.ToLower(external)]; known {
+			return strings.ToLower(external), true
+		}
+	}
+	return best, found
+}
+
+// Advances reports whether moving an alert from current to next is forward progress
+func Advances(current, next string) bool {
+	return statusOrder[next] > statusOrder[current]
+}
+
+// Extract reads a dot-separated path from a decoded JSON document as a string
+func Extract(document map[string]interface{}, path string) string {
+	if path == "" {
+		return ""
+	}
+
+	switch v := lookupPath(document, path).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return fmt.Sprintf("%v", v)
+	case bool:
+		return fmt.Sprintf("%t", v)
+	default:
+		return ""
+	}
+}
+
+// Sign returns the signature header value for a body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a callback signature in constant time
+func VerifySignature(secret string, body []byte, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// ExpandTemplate substitutes {external_id} and {alert_id} in a URL template
+func ExpandTemplate(template, externalID, alertID string) string {
+	return strings.NewReplacer("{external_id}", externalID, "{alert_id}", alertID).Replace(template)
+}
+
+// RetryDelay returns the exponential backoff before the next attempt
+func RetryDelay(attempts int, base, max time.Duration) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+func lookupPath(document map[string]interface{}, path string) interface{} {
+	var current interface{} = document
+	for _, key := range strings.Split(path, ".") {
+		node, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = node[key]
+	}
+	return current
+}
+
+func setPath(document map[string]interface{}, path string, value interface{}) error {
+	keys := strings.Split(path, ".")
+	node := document
+	for _, key := range keys[:len(keys)-1] {
+		child, exists := node[key]
+		if !exists {
+			next := make(map[string]interface{})
+			node[key] = next
+			node = next
+			continue
+		}
+		next, ok := child.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("mapping path %s conflicts with another field", path)
+		}
+		node = next
+	}
+	node[keys[len(keys)-1]] = value
+	return nil
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
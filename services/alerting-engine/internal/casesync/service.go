@@ -0,0 +1,917 @@
+package casesync
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegisshield/shared/reqctx"
+)
+
+// Lifecycle events a connector can subscribe to
+var lifecycleEvents = []string{
+	"alert.created",
+	"alert.acknowledged",
+	"alert.escalated",
+	"alert.resolved",
+	"alert.suppressed",
+	"alert.active",
+	"alert.open",
+}
+
+var (
+	// ErrInvalidSignature is returned when a callback is not signed with the connector secret
+	ErrInvalidSignature = errors.New("invalid callback signature")
+	// ErrConnectorDisabled is returned when a callback targets a disabled connector
+	ErrConnectorDisabled = errors.New("case sync connector is disabled")
+)
+
+// Service pushes alert lifecycle changes to external case management systems,
+// applies their acknowledgment callbacks and reconciles status drift
+type Service struct {
+	config      *config.Config
+	logger      *slog.Logger
+	repo        *database.CaseSyncRepository
+	alertRepo   *database.AlertRepository
+	commentRepo *database.AlertCommentRepository
+	client      *http.Client
+}
+
+// ConnectorInput describes a connector to create or update
+type ConnectorInput struct {
+	Name              string                 `json:"name"`
+	SystemType        string                 `json:"system_type"`
+	Enabled           *bool                  `json:"enabled,omitempty"`
+	EndpointURL       string                 `json:"endpoint_url"`
+	HTTPMethod        string                 `json:"http_method,omitempty"`
+	UpdateURLTemplate string                 `json:"update_url_template,omitempty"`
+	UpdateMethod      string                 `json:"update_method,omitempty"`
+	StatusURLTemplate string                 `json:"status_url_template,omitempty"`
+	AuthType          string                 `json:"auth_type,omitempty"`
+	AuthHeader        string                 `json:"auth_header,omitempty"`
+	CredentialEnv     string                 `json:"credential_env,omitempty"`
+	Headers           map[string]interface{} `json:"headers,omitempty"`
+	EventTypes        []string               `json:"event_types"`
+	FieldMappings     map[string]interface{} `json:"field_mappings,omitempty"`
+	StatusMappings    map[string]interface{} `json:"status_mappings,omitempty"`
+	ExternalIDField   string                 `json:"external_id_field,omitempty"`
+	CallbackMapping   map[string]interface{} `json:"callback_mapping,omitempty"`
+	DriftPolicy       string                 `json:"drift_policy,omitempty"`
+	MaxAttempts       int                    `json:"max_attempts,omitempty"`
+	Actor             string                 `json:"actor"`
+}
+
+// CreatedConnector returns the callback secret once, at creation time
+type CreatedConnector struct {
+	*database.CaseSyncConnector
+	CallbackSecret string `json:"callback_secret"`
+}
+
+// DispatchResult summarizes one outbox dispatch pass
+type DispatchResult struct {
+	Claimed   int `json:"claimed"`
+	Delivered int `json:"delivered"`
+	Failed    int `json:"failed"`
+	Dead      int `json:"dead"`
+}
+
+// CallbackResult describes how an inbound callback was applied
+type CallbackResult struct {
+	AlertID        string `json:"alert_id"`
+	ExternalID     string `json:"external_id,omitempty"`
+	ExternalStatus string `json:"external_status,omitempty"`
+	AlertStatus    string `json:"alert_status,omitempty"`
+	AlertUpdated   bool   `json:"alert_updated"`
+}
+
+// NewService creates a new case sync service
+func NewService(cfg *config.Config, logger *slog.Logger, repo *database.CaseSyncRepository, alertRepo *database.AlertRepository, commentRepo *database.AlertCommentRepository) *Service {
+	return &Service{
+		config:      cfg,
+		logger:      logger,
+		repo:        repo,
+		alertRepo:   alertRepo,
+		commentRepo: commentRepo,
+		client:      &http.Client{Timeout: cfg.CaseSync.RequestTimeout, Transport: reqctx.NewTransport("alerting-engine", nil)},
+	}
+}
+
+// Connector management
+
+// CreateConnector validates and registers a connector with a fresh callback secret
+func (s *Service) CreateConnector(ctx context.Context, input ConnectorInput) (*CreatedConnector, error) {
+	secret, err := newCallbackSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	connector := &database.CaseSyncConnector{
+		ID:             generateID("csc"),
+		Enabled:        true,
+		CallbackSecret: secret,
+		CreatedBy:      input.Actor,
+	}
+	applyInput(connector, input)
+
+	if err := ValidateConnector(connector); err != nil {
+		return nil, err
+	}
+	if input.Actor == "" {
+		return nil, fmt.Errorf("actor is required")
+	}
+
+	if err := s.repo.CreateConnector(ctx, connector); err != nil {
+		return nil, err
+	}
+
+	return &CreatedConnector{CaseSyncConnector: connector, CallbackSecret: secret}, nil
+}
+
+// UpdateConnector replaces a connector's configuration, keeping its callback secret
+func (s *Service) UpdateConnector(ctx context.Context, id string, input ConnectorInput) (*database.CaseSyncConnector, error) {
+	connector, err := s.repo.GetConnector(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyInput(connector, input)
+	if input.Actor != "" {
+		connector.UpdatedBy = &input.Actor
+	}
+
+	if err := ValidateConnector(connector); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateConnector(ctx, connector); err != nil {
+		return nil, err
+	}
+
+	return connector, nil
+}
+
+// RotateCallbackSecret issues a new callback secret for a connector
+func (s *Service) RotateCallbackSecret(ctx context.Context, id, actor string) (*CreatedConnector, error) {
+	connector, err := s.repo.GetConnector(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := newCallbackSecret()
+	if err != nil {
+		return nil, err
+	}
+	connector.CallbackSecret = secret
+	connector.UpdatedBy = &actor
+
+	if err := s.repo.UpdateConnector(ctx, connector); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Case sync callback secret rotated", "connector_id", id, "actor", actor)
+	return &CreatedConnector{CaseSyncConnector: connector, CallbackSecret: secret}, nil
+}
+
+// GetConnector retrieves a connector
+func (s *Service) GetConnector(ctx context.Context, id string) (*database.CaseSyncConnector, error) {
+	return s.repo.GetConnector(ctx, id)
+}
+
+// ListConnectors retrieves all connectors
+func (s *Service) ListConnectors(ctx context.Context) ([]*database.CaseSyncConnector, error) {
+	return s.repo.ListConnectors(ctx, false)
+}
+
+// DeleteConnector removes a connector
+func (s *Service) DeleteConnector(ctx context.Context, id string) error {
+	return s.repo.DeleteConnector(ctx, id)
+}
+
+// ListDeliveries retrieves outbox entries
+func (s *Service) ListDeliveries(ctx context.Context, connectorID, alertID, status string, limit int) ([]*database.CaseSyncDelivery, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListDeliveries(ctx, connectorID, alertID, status, limit)
+}
+
+// RetryDelivery requeues a failed or dead delivery
+func (s *Service) RetryDelivery(ctx context.Context, id string) error {
+	return s.repo.RetryDelivery(ctx, id)
+}
+
+// ListLinks retrieves the external cases linked to an alert
+func (s *Service) ListLinks(ctx context.Context, alertID string) ([]*database.CaseSyncLink, error) {
+	return s.repo.ListLinksForAlert(ctx, alertID)
+}
+
+// ListReconciliations retrieves recent drift reports
+func (s *Service) ListReconciliations(ctx context.Context, connectorID string, limit int) ([]*database.CaseSyncReconciliation, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.ListReconciliations(ctx, connectorID, limit)
+}
+
+// ValidateConnector checks a connector definition before it is stored
+func ValidateConnector(connector *database.CaseSyncConnector) error {
+	if strings.TrimSpace(connector.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	switch connector.SystemType {
+	case "actimize", "servicenow", "generic":
+	default:
+		return fmt.Errorf("unsupported system_type %q", connector.SystemType)
+	}
+
+	if err := validateURL(connector.EndpointURL); err != nil {
+		return fmt.Errorf("endpoint_url: %w", err)
+	}
+	if connector.UpdateURLTemplate != nil {
+		if err := validateURL(ExpandTemplate(*connector.UpdateURLTemplate, "x", "x")); err != nil {
+			return fmt.Errorf("update_url_template: %w", err)
+		}
+	}
+	if connector.StatusURLTemplate != nil {
+		if err := validateURL(ExpandTemplate(*connector.StatusURLTemplate, "x", "x")); err != nil {
+			return fmt.Errorf("status_url_template: %w", err)
+		}
+	}
+
+	for _, method := range []string{connector.HTTPMethod, connector.UpdateMethod} {
+		switch method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			return fmt.Errorf("unsupported http method %q", method)
+		}
+	}
+
+	switch connector.AuthType {
+	case "none":
+	case "bearer", "basic", "api_key":
+		if connector.CredentialEnv == nil || *connector.CredentialEnv == "" {
+			return fmt.Errorf("credential_env is required for %s auth", connector.AuthType)
+		}
+		if connector.AuthType == "api_key" && (connector.AuthHeader == nil || *connector.AuthHeader == "") {
+			return fmt.Errorf("auth_header is required for api_key auth")
+		}
+	default:
+		return fmt.Errorf("unsupported auth_type %q", connector.AuthType)
+	}
+
+	if len(connector.EventTypes) == 0 {
+		return fmt.Errorf("at least one event type is required")
+	}
+	for _, event := range connector.EventTypes {
+		if !contains(lifecycleEvents, event) {
+			return fmt.Errorf("unsupported event type %q", event)
+		}
+	}
+
+	switch connector.DriftPolicy {
+	case database.DriftPolicyReport, database.DriftPolicyPush, database.DriftPolicyPull:
+	default:
+		return fmt.Errorf("unsupported drift_policy %q", connector.DriftPolicy)
+	}
+	if connector.DriftPolicy == database.DriftPolicyPull && connector.StatusURLTemplate == nil {
+		return fmt.Errorf("drift_policy pull requires status_url_template")
+	}
+
+	if connector.MaxAttempts <= 0 {
+		return fmt.Errorf("max_attempts must be positive")
+	}
+
+	probe := &database.Alert{Metadata: map[string]interface{}{}}
+	paths := make([]string, 0, len(connector.FieldMappings))
+	for field, raw := range connector.FieldMappings {
+		expr, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("mapping for %s must be a string expression", field)
+		}
+		if _, err := ResolveField(connector, probe, &database.CaseSyncDelivery{}, nil, expr); err != nil {
+			return fmt.Errorf("mapping for %s: %w", field, err)
+		}
+		paths = append(paths, field)
+	}
+	sort.Strings(paths)
+	for i := 1; i < len(paths); i++ {
+		if strings.HasPrefix(paths[i], paths[i-1]+".") {
+			return fmt.Errorf("mapping path %s conflicts with %s", paths[i], paths[i-1])
+		}
+	}
+
+	for internal, raw := range connector.StatusMappings {
+		if _, known := statusOrder[internal]; !known {
+			return fmt.Errorf("status mapping for unknown alert status %q", internal)
+		}
+		if _, ok := raw.(string); !ok {
+			return fmt.Errorf("status mapping for %s must be a string", internal)
+		}
+	}
+
+	return nil
+}
+
+// Outbound delivery
+
+// DispatchPending sends due outbox entries to their connectors
+func (s *Service) DispatchPending(ctx context.Context) (*DispatchResult, error) {
+	deliveries, err := s.repo.ClaimDueDeliveries(ctx, s.config.CaseSync.BatchSize, s.config.CaseSync.DeliveryLease)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DispatchResult{Claimed: len(deliveries)}
+	connectors := make(map[string]*database.CaseSyncConnector)
+
+	for _, delivery := range deliveries {
+		connector, ok := connectors[delivery.ConnectorID]
+		if !ok {
+			connector, err = s.repo.GetConnector(ctx, delivery.ConnectorID)
+			if err != nil {
+				s.logger.Error("Failed to load case sync connector",
+					"connector_id", delivery.ConnectorID,
+					"error", err)
+				continue
+			}
+			connectors[delivery.ConnectorID] = connector
+		}
+
+		if !connector.Enabled {
+			// Leave queued; deliveries resume when the connector is re-enabled
+			continue
+		}
+
+		var responseCode int
+		var externalID string
+		thread, err := s.pendingThread(ctx, delivery)
+		if err == nil {
+			responseCode, externalID, err = s.deliver(ctx, connector, delivery, thread)
+		}
+		if err == nil {
+			var threadMigratedAt *time.Time
+			if len(thread) > 0 {
+				threadMigratedAt = &thread[len(thread)-1].CreatedAt
+			}
+			if err := s.repo.MarkDelivered(ctx, delivery, responseCode, externalID, threadMigratedAt); err != nil {
+				return result, err
+			}
+			result.Delivered++
+			continue
+		}
+
+		attempts := delivery.Attempts + 1
+		dead := attempts >= connector.MaxAttempts
+		nextAttempt := time.Now().Add(RetryDelay(attempts, s.config.CaseSync.RetryBaseDelay, s.config.CaseSync.MaxRetryDelay))
+
+		var code *int
+		if responseCode != 0 {
+			code = &responseCode
+		}
+
+		if markErr := s.repo.MarkFailed(ctx, delivery.ID, code, err.Error(), nextAttempt, dead); markErr != nil {
+			return result, markErr
+		}
+
+		if dead {
+			result.Dead++
+			s.logger.Error("Case sync delivery abandoned",
+				"delivery_id", delivery.ID,
+				"connector", connector.Name,
+				"alert_id", delivery.AlertID,
+				"attempts", attempts,
+				"error", err)
+		} else {
+			result.Failed++
+			s.logger.Warn("Case sync delivery failed",
+				"delivery_id", delivery.ID,
+				"connector", connector.Name,
+				"alert_id", delivery.AlertID,
+				"attempts", attempts,
+				"next_attempt_at", nextAttempt,
+				"error", err)
+		}
+	}
+
+	return result, nil
+}
+
+// pendingThread returns the alert comments an escalation carries into the
+// linked case: the whole thread the first time, then only comments written
+// since the last escalation reached that case
+func (s *Service) pendingThread(ctx context.Context, delivery *database.CaseSyncDelivery) ([]*database.AlertComment, error) {
+	if delivery.EventType != "alert.escalated" {
+		return nil, nil
+	}
+
+	var since *time.Time
+	link, err := s.repo.GetLink(ctx, delivery.ConnectorID, delivery.AlertID)
+	if err != nil && !errors.Is(err, database.ErrCaseLinkNotFound) {
+		return nil, err
+	}
+	if link != nil {
+		since = link.ThreadMigratedAt
+	}
+
+	thread, err := s.commentRepo.ListByAlert(ctx, delivery.AlertID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert thread: %w", err)
+	}
+	return thread, nil
+}
+
+// deliver sends one lifecycle change. Once the external system has returned a
+// case id, later changes go to the connector's update URL for that case.
+func (s *Service) deliver(ctx context.Context, connector *database.CaseSyncConnector, delivery *database.CaseSyncDelivery, thread []*database.AlertComment) (int, string, error) {
+	alert, err := s.alertRepo.GetByID(ctx, delivery.AlertID)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to load alert: %w", err)
+	}
+
+	payload, err := BuildPayload(connector, alert, delivery, thread)
+	if err != nil {
+		return 0, "", err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	method, endpoint := connector.HTTPMethod, connector.EndpointURL
+	link, err := s.repo.GetLink(ctx, connector.ID, delivery.AlertID)
+	if err != nil && !errors.Is(err, database.ErrCaseLinkNotFound) {
+		return 0, "", err
+	}
+	if link != nil && link.ExternalID != nil && connector.UpdateURLTemplate != nil {
+		method = connector.UpdateMethod
+		endpoint = ExpandTemplate(*connector.UpdateURLTemplate, url.PathEscape(*link.ExternalID), url.PathEscape(alert.ID))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-AegisShield-Event", delivery.EventType)
+	req.Header.Set("Idempotency-Key", delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(connector.CallbackSecret, body))
+	if err := s.authorize(req, connector); err != nil {
+		return 0, "", err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, "", fmt.Errorf("external system returned status %d: %s",
+			resp.StatusCode, truncate(string(respBody), 200))
+	}
+
+	var externalID string
+	if connector.ExternalIDField != nil && len(respBody) > 0 {
+		var document map[string]interface{}
+		if err := json.Unmarshal(respBody, &document); err == nil {
+			externalID = Extract(document, *connector.ExternalIDField)
+		}
+	}
+
+	return resp.StatusCode, externalID, nil
+}
+
+// authorize adds credentials read from the connector's environment variable
+func (s *Service) authorize(req *http.Request, connector *database.CaseSyncConnector) error {
+	for key, raw := range connector.Headers {
+		if value, ok := raw.(string); ok {
+			req.Header.Set(key, value)
+		}
+	}
+
+	if connector.AuthType == "none" {
+		return nil
+	}
+
+	credential := ""
+	if connector.CredentialEnv != nil {
+		credential = os.Getenv(*connector.CredentialEnv)
+	}
+	if credential == "" {
+		return fmt.Errorf("credential for connector %s is not set", connector.Name)
+	}
+
+	switch connector.AuthType {
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+credential)
+	case "basic":
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credential)))
+	case "api_key":
+		req.Header.Set(*connector.AuthHeader, credential)
+	}
+
+	return nil
+}
+
+// Inbound callbacks
+
+// HandleCallback verifies and applies an acknowledgment callback. The callback
+// records the external case id and status on the link; mapped acknowledged or
+// resolved statuses also advance the alert.
+func (s *Service) HandleCallback(ctx context.Context, connectorID string, body []byte, signature string) (*CallbackResult, error) {
+	connector, err := s.repo.GetConnector(ctx, connectorID)
+	if err != nil {
+		return nil, err
+	}
+	if !VerifySignature(connector.CallbackSecret, body, signature) {
+		s.logger.Warn("Rejected case sync callback with invalid signature", "connector_id", connectorID)
+		return nil, ErrInvalidSignature
+	}
+	if !connector.Enabled {
+		return nil, ErrConnectorDisabled
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("invalid callback body: %w", err)
+	}
+
+	result := &CallbackResult{
+		AlertID:        Extract(document, callbackPath(connector, "alert_id")),
+		ExternalID:     Extract(document, callbackPath(connector, "external_id")),
+		ExternalStatus: Extract(document, callbackPath(connector, "status")),
+	}
+	actor := Extract(document, callbackPath(connector, "actor"))
+	if actor == "" {
+		actor = connector.Name
+	}
+
+	if result.AlertID == "" {
+		if result.ExternalID == "" {
+			return nil, fmt.Errorf("callback must identify the alert or the external case")
+		}
+		link, err := s.repo.GetLinkByExternalID(ctx, connector.ID, result.ExternalID)
+		if err != nil {
+			return nil, err
+		}
+		result.AlertID = link.AlertID
+	}
+
+	alert, err := s.alertRepo.GetByID(ctx, result.AlertID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert: %w", err)
+	}
+
+	var target string
+	if result.ExternalStatus != "" {
+		if internal, ok := InternalStatus(connector, result.ExternalStatus); ok && Advances(alert.Status, internal) {
+			target = internal
+		}
+	}
+
+	updated, err := s.repo.ApplyCallback(ctx, connector.ID, result.AlertID, result.ExternalID, result.ExternalStatus, target, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	result.AlertUpdated = updated
+	result.AlertStatus = alert.Status
+	if updated {
+		result.AlertStatus = target
+	}
+
+	s.logger.Info("Case sync callback applied",
+		"connector", connector.Name,
+		"alert_id", result.AlertID,
+		"external_id", result.ExternalID,
+		"external_status", result.ExternalStatus,
+		"alert_updated", updated)
+
+	return result, nil
+}
+
+// Reconciliation
+
+// ReconcileAll checks every enabled connector that exposes a status URL
+func (s *Service) ReconcileAll(ctx context.Context) ([]*database.CaseSyncReconciliation, error) {
+	connectors, err := s.repo.ListConnectors(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []*database.CaseSyncReconciliation
+	for _, connector := range connectors {
+		if connector.StatusURLTemplate == nil {
+			continue
+		}
+		run, err := s.reconcile(ctx, connector)
+		if err != nil {
+			s.logger.Error("Case sync reconciliation failed", "connector", connector.Name, "error", err)
+		}
+		if run != nil {
+			runs = append(runs, run)
+		}
+	}
+
+	return runs, nil
+}
+
+// Reconcile checks one connector for status drift
+func (s *Service) Reconcile(ctx context.Context, connectorID string) (*database.CaseSyncReconciliation, error) {
+	connector, err := s.repo.GetConnector(ctx, connectorID)
+	if err != nil {
+		return nil, err
+	}
+	if connector.StatusURLTemplate == nil {
+		return nil, fmt.Errorf("connector %s has no status_url_template", connector.Name)
+	}
+	return s.reconcile(ctx, connector)
+}
+
+// reconcile compares each linked alert's status with the external case and
+// applies the connector's drift policy: report only, push our status, or
+// pull theirs forward
+func (s *Service) reconcile(ctx context.Context, connector *database.CaseSyncConnector) (*database.CaseSyncReconciliation, error) {
+	run := &database.CaseSyncReconciliation{
+		ID:          generateID("csr"),
+		ConnectorID: connector.ID,
+		Status:      "running",
+		StartedAt:   time.Now(),
+	}
+	if err := s.repo.CreateReconciliation(ctx, run); err != nil {
+		return nil, err
+	}
+
+	since := time.Now().Add(-s.config.CaseSync.ReconcileLookback)
+	after := ""
+	var runErr error
+
+	for runErr == nil {
+		items, err := s.repo.ListLinksForReconciliation(ctx, connector.ID, since, after, s.config.CaseSync.ReconcileBatchSize)
+		if err != nil {
+			runErr = err
+			break
+		}
+		if len(items) == 0 {
+			break
+		}
+
+		for _, item := range items {
+			after = item.AlertID
+			if ctx.Err() != nil {
+				runErr = ctx.Err()
+				break
+			}
+			s.reconcileItem(ctx, connector, item, run)
+		}
+	}
+
+	now := time.Now()
+	run.CompletedAt = &now
+	run.Status = "completed"
+	if runErr != nil {
+		message := runErr.Error()
+		run.Status = "failed"
+		run.ErrorMessage = &message
+	}
+
+	if err := s.repo.CompleteReconciliation(context.Background(), run); err != nil {
+		return run, err
+	}
+
+	s.logger.Info("Case sync reconciliation finished",
+		"connector", connector.Name,
+		"status", run.Status,
+		"checked", run.Checked,
+		"drifted", run.Drifted,
+		"repaired", run.Repaired,
+		"errors", run.Errors)
+
+	return run, runErr
+}
+
+func (s *Service) reconcileItem(ctx context.Context, connector *database.CaseSyncConnector, item *database.ReconciliationItem, run *database.CaseSyncReconciliation) {
+	run.Checked++
+
+	observed, err := s.fetchExternalStatus(ctx, connector, item)
+	if err != nil {
+		run.Errors++
+		s.logger.Warn("Failed to fetch external case status",
+			"connector", connector.Name,
+			"alert_id", item.AlertID,
+			"error", err)
+		return
+	}
+
+	expected := ExternalStatus(connector, item.AlertStatus)
+	if strings.EqualFold(expected, observed) {
+		run.InSync++
+		if err := s.repo.MarkReconciled(ctx, connector.ID, item.AlertID, observed, false); err != nil {
+			run.Errors++
+		}
+		return
+	}
+
+	run.Drifted++
+	record := database.DriftRecord{
+		AlertID:          item.AlertID,
+		ExternalID:       derefString(item.ExternalID),
+		AlertStatus:      item.AlertStatus,
+		ExpectedExternal: expected,
+		ObservedExternal: observed,
+		Action:           "reported",
+	}
+
+	switch connector.DriftPolicy {
+	case database.DriftPolicyPush:
+		err = s.repo.EnqueueDelivery(ctx, &database.CaseSyncDelivery{
+			ID:          generateID("csd"),
+			ConnectorID: connector.ID,
+			AlertID:     item.AlertID,
+			EventType:   "alert." + item.AlertStatus,
+			AlertStatus: item.AlertStatus,
+		})
+		if err == nil {
+			record.Action = "pushed"
+			run.Repaired++
+		}
+	case database.DriftPolicyPull:
+		internal, ok := InternalStatus(connector, observed)
+		if ok && Advances(item.AlertStatus, internal) {
+			var updated bool
+			updated, err = s.repo.PullExternalStatus(ctx, connector.ID, item.AlertID, internal, connector.Name)
+			if err == nil && updated {
+				record.Action = "pulled"
+				run.Repaired++
+			}
+		}
+	}
+	if err != nil {
+		run.Errors++
+		record.Action = "failed"
+		s.logger.Warn("Failed to repair case sync drift",
+			"connector", connector.Name,
+			"alert_id", item.AlertID,
+			"error", err)
+	}
+
+	if len(run.Drift) < s.config.CaseSync.MaxDriftDetails {
+		run.Drift = append(run.Drift, record)
+	}
+
+	if err := s.repo.MarkReconciled(ctx, connector.ID, item.AlertID, observed, true); err != nil {
+		run.Errors++
+	}
+}
+
+func (s *Service) fetchExternalStatus(ctx context.Context, connector *database.CaseSyncConnector, item *database.ReconciliationItem) (string, error) {
+	endpoint := ExpandTemplate(*connector.StatusURLTemplate,
+		url.PathEscape(derefString(item.ExternalID)), url.PathEscape(item.AlertID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if err := s.authorize(req, connector); err != nil {
+		return "", err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("external system returned status %d", resp.StatusCode)
+	}
+
+	var document map[string]interface{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&document); err != nil {
+		return "", fmt.Errorf("failed to decode external case: %w", err)
+	}
+
+	status := Extract(document, callbackPath(connector, "status"))
+	if status == "" {
+		return "", fmt.Errorf("external case has no status")
+	}
+	return status, nil
+}
+
+// Helpers
+
+func applyInput(connector *database.CaseSyncConnector, input ConnectorInput) {
+	connector.Name = strings.TrimSpace(input.Name)
+	connector.SystemType = input.SystemType
+	if input.Enabled != nil {
+		connector.Enabled = *input.Enabled
+	}
+	connector.EndpointURL = input.EndpointURL
+	connector.HTTPMethod = defaultString(strings.ToUpper(input.HTTPMethod), http.MethodPost)
+	connector.UpdateURLTemplate = optionalString(input.UpdateURLTemplate)
+	connector.UpdateMethod = defaultString(strings.ToUpper(input.UpdateMethod), http.MethodPatch)
+	connector.StatusURLTemplate = optionalString(input.StatusURLTemplate)
+	connector.AuthType = defaultString(input.AuthType, "none")
+	connector.AuthHeader = optionalString(input.AuthHeader)
+	connector.CredentialEnv = optionalString(input.CredentialEnv)
+	connector.Headers = database.JSONB(nonNilMap(input.Headers))
+	connector.EventTypes = input.EventTypes
+	connector.FieldMappings = database.JSONB(nonNilMap(input.FieldMappings))
+	connector.StatusMappings = database.JSONB(nonNilMap(input.StatusMappings))
+	connector.ExternalIDField = optionalString(input.ExternalIDField)
+	connector.CallbackMapping = database.JSONB(nonNilMap(input.CallbackMapping))
+	connector.DriftPolicy = defaultString(input.DriftPolicy, database.DriftPolicyReport)
+	connector.MaxAttempts = input.MaxAttempts
+	if connector.MaxAttempts == 0 {
+		connector.MaxAttempts = 5
+	}
+}
+
+// callbackPath returns the document path for a callback field, defaulting to its name
+func callbackPath(connector *database.CaseSyncConnector, field string) string {
+	if path, ok := connector.CallbackMapping[field].(string); ok && path != "" {
+		return path
+	}
+	return field
+}
+
+func validateURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return fmt.Errorf("must be an http or https URL")
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	return nil
+}
+
+func newCallbackSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate callback secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func defaultString(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+func nonNilMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
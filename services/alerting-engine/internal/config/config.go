@@ -21,6 +21,25 @@ type Config struct {
 	Scheduler   SchedulerConfig `mapstructure:"scheduler"`
 	Security    SecurityConfig `mapstructure:"security"`
 	Logging     LoggingConfig  `mapstructure:"logging"`
+	Training    TrainingConfig `mapstructure:"training"`
+	Watchlist   WatchlistConfig `mapstructure:"watchlist"`
+	SLO         SLOConfig       `mapstructure:"slo"`
+	CaseSync    CaseSyncConfig  `mapstructure:"case_sync"`
+	BatchDigest BatchDigestConfig `mapstructure:"batch_digest"`
+	AlertClustering AlertClusteringConfig `mapstructure:"alert_clustering"`
+	RulePack    RulePackConfig  `mapstructure:"rule_pack"`
+	Storm       StormConfig     `mapstructure:"storm"`
+	Dedup       DedupConfig     `mapstructure:"dedup"`
+	Startup     StartupConfig   `mapstructure:"startup"`
+	RiskWebhooks RiskWebhooksConfig `mapstructure:"risk_webhooks"`
+	AlertLifecycle AlertLifecycleConfig `mapstructure:"alert_lifecycle"`
+	NotificationTemplates NotificationTemplatesConfig `mapstructure:"notification_templates"`
+	AlertCorrelation AlertCorrelationConfig `mapstructure:"alert_correlation"`
+	RuleFeedback RuleFeedbackConfig `mapstructure:"rule_feedback"`
+	SeverityTuning SeverityTuningConfig `mapstructure:"severity_tuning"`
+	AlertStream AlertStreamConfig `mapstructure:"alert_stream"`
+	RuleOwners  RuleOwnersConfig  `mapstructure:"rule_owners"`
+	AutoClose   AutoCloseConfig   `mapstructure:"auto_close"`
 }
 
 // ServerConfig contains server configuration
@@ -58,6 +77,7 @@ type KafkaConfig struct {
 	GroupID string      `mapstructure:"group_id"`
 	Topics  TopicsConfig `mapstructure:"topics"`
 	SASL    SASLConfig   `mapstructure:"sasl"`
+	DeadLetter DeadLetterConfig `mapstructure:"dead_letter"`
 }
 
 // TopicsConfig contains Kafka topic configuration
@@ -87,6 +107,16 @@ type SASLConfig struct {
 	Password string `mapstructure:"password"`
 }
 
+// DeadLetterConfig contains the consumer retry policy and dead-letter topic
+// that failed events are parked on
+type DeadLetterConfig struct {
+	Topic          string        `mapstructure:"topic"`
+	MaxAttempts    int           `mapstructure:"max_attempts"` // processing attempts per event before it is dead-lettered
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`
+	MaxReplayBatch int           `mapstructure:"max_replay_batch"` // messages one bulk replay request may name
+}
+
 // AlertingConfig contains alerting engine configuration
 type AlertingConfig struct {
 	ProcessingInterval    time.Duration `mapstructure:"processing_interval"`
@@ -137,6 +167,7 @@ type SMSConfig struct {
 	Provider        string        `mapstructure:"provider"` // twilio
 	TwilioSID       string        `mapstructure:"twilio_sid"`
 	TwilioToken     string        `mapstructure:"twilio_token"`
+	TwilioBaseURL   string        `mapstructure:"twilio_base_url"`
 	FromNumber      string        `mapstructure:"from_number"`
 	MaxRetries      int           `mapstructure:"max_retries"`
 	RetryDelay      time.Duration `mapstructure:"retry_delay"`
@@ -183,6 +214,7 @@ type PagerDutyConfig struct {
 	Enabled         bool          `mapstructure:"enabled"`
 	IntegrationKey  string        `mapstructure:"integration_key"`
 	ServiceKey      string        `mapstructure:"service_key"`
+	EventsURL       string        `mapstructure:"events_url"` // Events API v2 enqueue endpoint
 	MaxRetries      int           `mapstructure:"max_retries"`
 	RetryDelay      time.Duration `mapstructure:"retry_delay"`
 	Timeout         time.Duration `mapstructure:"timeout"`
@@ -210,6 +242,54 @@ type RulesConfig struct {
 	CacheTTL            time.Duration `mapstructure:"cache_ttl"`
 	DefaultSeverity     string        `mapstructure:"default_severity"`
 	DefaultPriority     string        `mapstructure:"default_priority"`
+	ReferenceCache      ReferenceCacheConfig `mapstructure:"reference_cache"`
+	Backtest            BacktestConfig       `mapstructure:"backtest"`
+	Windows             RuleWindowsConfig    `mapstructure:"windows"`
+	CEL                 CELConfig            `mapstructure:"cel"`
+}
+
+// ReferenceCacheConfig contains the rule engine's reference data cache configuration
+type ReferenceCacheConfig struct {
+	SyncInterval    time.Duration `mapstructure:"sync_interval"`    // how often the reference data change log is read
+	ChangeOverlap   time.Duration `mapstructure:"change_overlap"`   // changes are re-read this far back to catch late commits
+	ChangeRetention time.Duration `mapstructure:"change_retention"` // logged changes older than this are pruned
+	LoadTimeout     time.Duration `mapstructure:"load_timeout"`
+	MaxLookupTables int           `mapstructure:"max_lookup_tables"`
+	MaxRiskTiers    int           `mapstructure:"max_risk_tiers"`
+}
+
+// RuleWindowsConfig contains the state store behind windowed aggregations in
+// rule conditions. The memory backend suits a single replica and is
+// checkpointed to the database; replicas sharing windows need redis.
+type RuleWindowsConfig struct {
+	Backend             string        `mapstructure:"backend"`                // memory or redis
+	MaxWindowSize       time.Duration `mapstructure:"max_window_size"`        // rules with longer windows do not compile
+	MaxEntriesPerWindow int           `mapstructure:"max_entries_per_window"` // oldest entries are dropped beyond this
+	CheckpointInterval  time.Duration `mapstructure:"checkpoint_interval"`
+	CheckpointInstance  string        `mapstructure:"checkpoint_instance"` // distinct per replica on the memory backend
+	RedisKeyPrefix      string        `mapstructure:"redis_key_prefix"`
+}
+
+// CELConfig bounds the CEL condition expressions rules may be written in.
+// A zero limit is not enforced.
+type CELConfig struct {
+	MaxExpressionLength int           `mapstructure:"max_expression_length"` // longer expressions do not compile
+	CostLimit           uint64        `mapstructure:"cost_limit"`            // evaluations costing more are aborted
+	EvaluationTimeout   time.Duration `mapstructure:"evaluation_timeout"`    // per condition evaluation
+	MaxFixtures         int           `mapstructure:"max_fixtures"`          // test fixtures a rule may carry
+}
+
+// BacktestConfig contains configuration for replaying historical events against draft rules
+type BacktestConfig struct {
+	Enabled               bool          `mapstructure:"enabled"`
+	DefaultWindow         time.Duration `mapstructure:"default_window"`
+	MaxWindow             time.Duration `mapstructure:"max_window"` // bounded by the event topic's retention
+	MaxEvents             int           `mapstructure:"max_events"` // a backtest stops and is marked truncated after this many events
+	DefaultSampleSize     int           `mapstructure:"default_sample_size"`
+	MaxSampleSize         int           `mapstructure:"max_sample_size"`
+	MaxConcurrent         int           `mapstructure:"max_concurrent"`
+	Timeout               time.Duration `mapstructure:"timeout"`
+	FalsePositiveLookback time.Duration `mapstructure:"false_positive_lookback"` // resolved alerts this recent give existing rules' false positive rates
 }
 
 // SchedulerConfig contains scheduler configuration
@@ -234,6 +314,14 @@ type SecurityConfig struct {
 	APIKeyHeader        string `mapstructure:"api_key_header"`
 	EncryptionKey       string `mapstructure:"encryption_key"`
 	HashSalt           string `mapstructure:"hash_salt"`
+	// Role policy for resource/action checks when authentication is enabled;
+	// empty uses the shared built-in policy
+	RBACPolicyFile      string        `mapstructure:"rbac_policy_file"`
+	RBACCacheTTL        time.Duration `mapstructure:"rbac_cache_ttl"`
+	RBACCacheMaxEntries int           `mapstructure:"rbac_cache_max_entries"`
+	// Reject HTTP and gRPC requests and Kafka events that arrive without a
+	// correlation ID instead of starting one; on behind the gateway
+	StrictRequestContext bool `mapstructure:"strict_request_context"`
 }
 
 // LoggingConfig contains logging configuration
@@ -249,6 +337,291 @@ type LoggingConfig struct {
 	IncludeSource   bool   `mapstructure:"include_source"`
 }
 
+// TrainingConfig contains analyst training replay configuration
+type TrainingConfig struct {
+	Enabled              bool          `mapstructure:"enabled"`
+	SandboxTenantPrefix  string        `mapstructure:"sandbox_tenant_prefix"`
+	DefaultScenarioCount int           `mapstructure:"default_scenario_count"`
+	MaxScenarioCount     int           `mapstructure:"max_scenario_count"`
+	DefaultReplayWindow  time.Duration `mapstructure:"default_replay_window"`
+	SensitiveFields      []string      `mapstructure:"sensitive_fields"`
+}
+
+// WatchlistConfig contains dual-control settings for watchlist and exclusion list changes
+type WatchlistConfig struct {
+	PendingChangeTTL         time.Duration `mapstructure:"pending_change_ttl"`
+	DefaultExclusionDuration time.Duration `mapstructure:"default_exclusion_duration"`
+	MaxExclusionDuration     time.Duration `mapstructure:"max_exclusion_duration"`
+	MinJustificationLength   int           `mapstructure:"min_justification_length"`
+	ExpirySchedule           string        `mapstructure:"expiry_schedule"`
+}
+
+// SLOConfig contains service-level objective and error budget reporting configuration
+type SLOConfig struct {
+	Enabled            bool           `mapstructure:"enabled"`
+	PrometheusURL      string         `mapstructure:"prometheus_url"`
+	QueryTimeout       time.Duration  `mapstructure:"query_timeout"`
+	EvaluationSchedule string         `mapstructure:"evaluation_schedule"`
+	OpsChannel         string         `mapstructure:"ops_channel"`
+	FastBurnRate       float64        `mapstructure:"fast_burn_rate"`
+	SlowBurnRate       float64        `mapstructure:"slow_burn_rate"`
+	BudgetWarningRatio float64        `mapstructure:"budget_warning_ratio"`
+	Objectives         []SLOObjective `mapstructure:"objectives"`
+}
+
+// SLOObjective defines an availability or latency target for one service endpoint
+type SLOObjective struct {
+	Name             string        `mapstructure:"name"`
+	Service          string        `mapstructure:"service"`
+	Type             string        `mapstructure:"type"` // availability, latency
+	Target           float64       `mapstructure:"target"`
+	Window           time.Duration `mapstructure:"window"`
+	RequestsMetric   string        `mapstructure:"requests_metric"`
+	DurationMetric   string        `mapstructure:"duration_metric"`
+	Selector         string        `mapstructure:"selector"`
+	LatencyThreshold string        `mapstructure:"latency_threshold"` // histogram bucket boundary, e.g. "0.5"
+}
+
+// CaseSyncConfig contains outbound sync settings for external case management systems
+type CaseSyncConfig struct {
+	Enabled            bool          `mapstructure:"enabled"`
+	DispatchSchedule   string        `mapstructure:"dispatch_schedule"`
+	ReconcileSchedule  string        `mapstructure:"reconcile_schedule"`
+	BatchSize          int           `mapstructure:"batch_size"`
+	DeliveryLease      time.Duration `mapstructure:"delivery_lease"`
+	RequestTimeout     time.Duration `mapstructure:"request_timeout"`
+	RetryBaseDelay     time.Duration `mapstructure:"retry_base_delay"`
+	MaxRetryDelay      time.Duration `mapstructure:"max_retry_delay"`
+	ReconcileLookback  time.Duration `mapstructure:"reconcile_lookback"`
+	ReconcileBatchSize int           `mapstructure:"reconcile_batch_size"`
+	MaxDriftDetails    int           `mapstructure:"max_drift_details"`
+	CallbackMaxBody    int64         `mapstructure:"callback_max_body"`
+}
+
+// BatchDigestConfig contains end-of-day batch rule evaluation and digest settings
+type BatchDigestConfig struct {
+	Enabled              bool   `mapstructure:"enabled"`
+	Schedule             string `mapstructure:"schedule"`
+	Timezone             string `mapstructure:"timezone"` // business day boundaries
+	NotificationChannel  string `mapstructure:"notification_channel"` // fallback when a portfolio has none
+	MaxFindingsPerDigest int    `mapstructure:"max_findings_per_digest"`
+	MaxAggregateBatch    int    `mapstructure:"max_aggregate_batch"`
+}
+
+// AlertClusteringConfig contains settings for grouping related open alerts into investigation bundles
+type AlertClusteringConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Schedule        string        `mapstructure:"schedule"`
+	Lookback        time.Duration `mapstructure:"lookback"` // only alerts created within this window are clustered
+	MinClusterSize  int           `mapstructure:"min_cluster_size"`
+	MaxBundleSize   int           `mapstructure:"max_bundle_size"`
+	MaxAlertsPerRun int           `mapstructure:"max_alerts_per_run"`
+	MaxContextBatch int           `mapstructure:"max_context_batch"`
+	CaseAPIURL      string        `mapstructure:"case_api_url"` // investigation-toolkit investigations endpoint
+	CaseAPITimeout  time.Duration `mapstructure:"case_api_timeout"`
+	DefaultCaseType string        `mapstructure:"default_case_type"`
+}
+
+// RulePackConfig contains signed rule pack export and import configuration
+type RulePackConfig struct {
+	DeploymentID        string            `mapstructure:"deployment_id"` // recorded as the source of exported packs
+	SigningKeyID        string            `mapstructure:"signing_key_id"`
+	SigningKeys         map[string]string `mapstructure:"signing_keys"` // key id to shared secret; signs exports and verifies imports
+	RequireSignature    bool              `mapstructure:"require_signature"`
+	DefaultConflictMode string            `mapstructure:"default_conflict_mode"`
+	MaxRules            int               `mapstructure:"max_rules"`
+	MaxPackSize         int64             `mapstructure:"max_pack_size"`
+}
+
+// StormConfig contains alert storm detection, sampling and post-storm reconciliation settings
+type StormConfig struct {
+	Enabled            bool          `mapstructure:"enabled"`
+	Window             time.Duration `mapstructure:"window"` // alert rate is measured per rule in windows of this length
+	BaselineSmoothing  float64       `mapstructure:"baseline_smoothing"`
+	MinAlerts          int           `mapstructure:"min_alerts"` // per window, below which a rule never storms
+	RateMultiplier     float64       `mapstructure:"rate_multiplier"`
+	QuietWindows       int           `mapstructure:"quiet_windows"`   // windows back under the threshold that end a storm
+	TargetAdmitted     int           `mapstructure:"target_admitted"` // individual alerts still raised per window during a storm
+	MinSampleRate      float64       `mapstructure:"min_sample_rate"`
+	ReconcileSchedule  string        `mapstructure:"reconcile_schedule"`
+	AutoReconcile      bool          `mapstructure:"auto_reconcile"`
+	AutoReconcileDelay time.Duration `mapstructure:"auto_reconcile_delay"` // time analysts have to reconcile an ended storm themselves
+	ExpandMinSeverity  string        `mapstructure:"expand_min_severity"`  // triage reconciliation expands held alerts at or above this severity
+	ReconcileBatchSize int           `mapstructure:"reconcile_batch_size"`
+}
+
+// DedupConfig contains alert deduplication defaults; rules may override the
+// window and fingerprint fields with their own suppression settings
+type DedupConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	DefaultWindow    time.Duration `mapstructure:"default_window"`
+	MaxWindow        time.Duration `mapstructure:"max_window"`
+	DefaultFields    []string      `mapstructure:"default_fields"` // event paths fingerprinted for rules without settings of their own
+	MaxFields        int           `mapstructure:"max_fields"`
+	SettingsCacheTTL time.Duration `mapstructure:"settings_cache_ttl"`
+	StatsWindow      time.Duration `mapstructure:"stats_window"` // how far back suppressed counts are summed for a rule
+}
+
+// StartupConfig contains dependency retry settings for service startup
+type StartupConfig struct {
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`
+	MaxElapsed     time.Duration `mapstructure:"max_elapsed"` // 0 retries until shutdown
+}
+
+// RiskWebhooksConfig contains outbound entity risk tier webhook settings
+type RiskWebhooksConfig struct {
+	Enabled              bool          `mapstructure:"enabled"`
+	DispatchSchedule     string        `mapstructure:"dispatch_schedule"`
+	BatchSize            int           `mapstructure:"batch_size"`
+	DeliveryLease        time.Duration `mapstructure:"delivery_lease"`
+	RequestTimeout       time.Duration `mapstructure:"request_timeout"`
+	RetryBaseDelay       time.Duration `mapstructure:"retry_base_delay"`
+	MaxRetryDelay        time.Duration `mapstructure:"max_retry_delay"`
+	DefaultMaxAttempts   int           `mapstructure:"default_max_attempts"` // attempts before a delivery is dead-lettered
+	MaxReplayTransitions int           `mapstructure:"max_replay_transitions"`
+}
+
+// AlertLifecycleConfig contains alert SLA targets, overdue listing limits and
+// handoff settings. An alert's SLA deadline is its creation time plus the
+// target for its severity, plus any extension granted on handoff.
+type AlertLifecycleConfig struct {
+	SLATargets          SLATargetsConfig   `mapstructure:"sla_targets"`
+	DefaultOverdueLimit int                `mapstructure:"default_overdue_limit"`
+	MaxOverdueLimit     int                `mapstructure:"max_overdue_limit"`
+	Handoff             AlertHandoffConfig `mapstructure:"handoff"`
+}
+
+// AlertHandoffConfig contains settings for handing alerts over to another
+// analyst. A receiver left less than MinReceiverTime before the SLA deadline
+// has the deadline moved out to it, up to MaxSLAExtension per alert.
+type AlertHandoffConfig struct {
+	MinReceiverTime time.Duration `mapstructure:"min_receiver_time"`
+	MaxSLAExtension time.Duration `mapstructure:"max_sla_extension"`
+	MaxPendingTasks int           `mapstructure:"max_pending_tasks"`
+	NotifyChannel   string        `mapstructure:"notify_channel"` // channel the receiver is notified on; empty disables notifications
+}
+
+// SLATargetsConfig contains the time allowed to close an alert, by severity
+type SLATargetsConfig struct {
+	Critical time.Duration `mapstructure:"critical"`
+	High     time.Duration `mapstructure:"high"`
+	Medium   time.Duration `mapstructure:"medium"`
+	Low      time.Duration `mapstructure:"low"`
+}
+
+// NotificationTemplatesConfig contains notification template limits and branding defaults
+type NotificationTemplatesConfig struct {
+	MaxTemplateSize  int           `mapstructure:"max_template_size"`  // bytes of subject plus body
+	MaxOutputSize    int           `mapstructure:"max_output_size"`    // bytes a single render may produce
+	CacheTTL         time.Duration `mapstructure:"cache_ttl"`          // how long resolved templates and branding are reused before being reloaded
+	DefaultBrandName string        `mapstructure:"default_brand_name"` // brand of tenants without branding of their own
+}
+
+// AlertCorrelationConfig contains settings for correlating new alerts into alert cases
+type AlertCorrelationConfig struct {
+	Enabled                 bool          `mapstructure:"enabled"`
+	Window                  time.Duration `mapstructure:"window"`         // alerts correlate with alerts raised at most this long before them
+	AccountFields           []string      `mapstructure:"account_fields"` // dotted event paths holding account identifiers
+	MaxCaseSize             int           `mapstructure:"max_case_size"`  // a full case takes no further alerts; a new case is opened instead
+	MaxMatches              int           `mapstructure:"max_matches"`    // earlier alerts considered per new alert
+	KeyRetention            time.Duration `mapstructure:"key_retention"`
+	PruneSchedule           string        `mapstructure:"prune_schedule"`
+	InvestigationAPIURL     string        `mapstructure:"investigation_api_url"` // investigation-toolkit investigations endpoint
+	InvestigationAPITimeout time.Duration `mapstructure:"investigation_api_timeout"`
+	DefaultCaseType         string        `mapstructure:"default_case_type"`
+}
+
+// RuleFeedbackConfig contains settings for measuring rule precision from
+// investigator dispositions and suggesting threshold adjustments. A
+// suggested threshold keeps at least TargetRecall of a rule's true positives.
+type RuleFeedbackConfig struct {
+	Lookback         time.Duration `mapstructure:"lookback"`         // only dispositions recorded within this window are counted
+	MinDispositions  int           `mapstructure:"min_dispositions"` // rules with fewer dispositions get no suggestion
+	MaxSamples       int           `mapstructure:"max_samples"`      // dispositions read per report
+	TargetRecall     float64       `mapstructure:"target_recall"`
+	MinPrecision     float64       `mapstructure:"min_precision"`   // rules below it that no threshold fixes are flagged for review
+	ThresholdField   string        `mapstructure:"threshold_field"` // dotted event path thresholds are suggested for when a report names none
+	OptimizerURL     string        `mapstructure:"optimizer_url"`   // ml-pipeline threshold optimization endpoint; empty tunes locally only
+	OptimizerTimeout time.Duration `mapstructure:"optimizer_timeout"`
+}
+
+// SeverityTuningConfig contains settings for proposing rule default
+// severities from investigator dispositions. The average loss of a rule's
+// true positives picks a severity tier, which a high or low true-positive
+// rate moves one level; proposals take effect only once approved.
+type SeverityTuningConfig struct {
+	Lookback         time.Duration `mapstructure:"lookback"`         // only dispositions recorded within this window are counted
+	MinDispositions  int           `mapstructure:"min_dispositions"` // rules with fewer dispositions keep their severity
+	MaxSamples       int           `mapstructure:"max_samples"`      // dispositions read per proposal
+	LossField        string        `mapstructure:"loss_field"`       // dotted event path holding the amount at risk
+	MediumLoss       float64       `mapstructure:"medium_loss"`      // average true-positive loss that warrants medium
+	HighLoss         float64       `mapstructure:"high_loss"`
+	CriticalLoss     float64       `mapstructure:"critical_loss"`
+	RaisePrecision   float64       `mapstructure:"raise_precision"` // true-positive rates at or above it raise the tier a level
+	LowerPrecision   float64       `mapstructure:"lower_precision"` // true-positive rates below it lower the tier a level
+	MaxStep          int           `mapstructure:"max_step"`        // levels a severity moves per approved proposal
+	ProposalTTL      time.Duration `mapstructure:"proposal_ttl"`    // pending proposals older than this can no longer be approved
+	SeparateApprover bool          `mapstructure:"separate_approver"`
+}
+
+// AlertStreamConfig contains settings for the SubscribeAlerts gRPC stream.
+// Subscribers tail the alerts table from their cursor, so a stream resumes
+// on any replica; alerts newer than CommitLag are held back so one whose
+// insert commits late is not skipped.
+type AlertStreamConfig struct {
+	PollInterval      time.Duration `mapstructure:"poll_interval"`
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"` // default when a subscriber does not ask for one
+	MinHeartbeat      time.Duration `mapstructure:"min_heartbeat"`
+	CommitLag         time.Duration `mapstructure:"commit_lag"`
+	BatchSize         int           `mapstructure:"batch_size"`      // alerts read per poll
+	MaxSubscribers    int           `mapstructure:"max_subscribers"` // open streams per replica; zero is unlimited
+}
+
+// RuleOwnersConfig contains settings for telling rule owners about their
+// rules in one digest per owner: rules enabled or disabled, evaluation errors
+// over the error budget and false-positive rates that are high and rising.
+// The error budget is checked per replica, against the evaluations since the
+// previous check; dispositions in the trend window are compared with the
+// window of the same length before it.
+type RuleOwnersConfig struct {
+	Enabled                  bool          `mapstructure:"enabled"`
+	CheckSchedule            string        `mapstructure:"check_schedule"`
+	DigestSchedule           string        `mapstructure:"digest_schedule"`
+	ErrorBudget              float64       `mapstructure:"error_budget"`    // share of evaluations allowed to fail
+	MinEvaluations           int64         `mapstructure:"min_evaluations"` // fewer evaluations since the last check are not judged
+	TrendWindow              time.Duration `mapstructure:"trend_window"`
+	MinDispositions          int           `mapstructure:"min_dispositions"`            // per window; fewer give no rate
+	MaxFalsePositiveRate     float64       `mapstructure:"max_false_positive_rate"`     // recent rates at or above it are flagged if rising
+	MinFalsePositiveIncrease float64       `mapstructure:"min_false_positive_increase"` // rise over the prior window's rate that counts as a trend
+	EventCooldown            time.Duration `mapstructure:"event_cooldown"`              // a rule is flagged for the same problem at most once per cooldown
+	MaxEventsPerDigest       int           `mapstructure:"max_events_per_digest"`       // pending events sent per digest run
+}
+
+// AutoCloseConfig contains settings for closing stale alerts nobody worked.
+// Each policy closes open alerts of one severity, optionally narrowed to
+// some rules, once they have gone InactiveFor without a transition, comment
+// or suppressed repeat. A policy naming rules takes precedence over one
+// covering the whole severity.
+type AutoCloseConfig struct {
+	Enabled           bool              `mapstructure:"enabled"`
+	Schedule          string            `mapstructure:"schedule"`
+	BatchSize         int               `mapstructure:"batch_size"`          // alerts closed per policy per run
+	DefaultSampleRate float64           `mapstructure:"default_sample_rate"` // share of closures drawn for review when a policy sets none
+	MaxReportClosures int               `mapstructure:"max_report_closures"` // closures summarized per report
+	Policies          []AutoClosePolicy `mapstructure:"policies"`
+}
+
+// AutoClosePolicy closes stale alerts of a severity with a disposition
+type AutoClosePolicy struct {
+	Name        string        `mapstructure:"name"`
+	Severity    string        `mapstructure:"severity"`
+	RuleIDs     []string      `mapstructure:"rule_ids"` // empty covers every rule not named by another policy
+	InactiveFor time.Duration `mapstructure:"inactive_for"`
+	Disposition string        `mapstructure:"disposition"`
+	SampleRate  float64       `mapstructure:"sample_rate"`
+}
+
 // Load loads configuration from environment variables and config files
 func Load() (Config, error) {
 	viper.SetConfigName("config")
@@ -328,6 +701,13 @@ func setDefaults() {
 	viper.SetDefault("kafka.topics.notification_sent", "notification-sent")
 	viper.SetDefault("kafka.topics.notification_failed", "notification-failed")
 
+	// Kafka dead letters
+	viper.SetDefault("kafka.dead_letter.topic", "alerting-engine-dlq")
+	viper.SetDefault("kafka.dead_letter.max_attempts", 5)
+	viper.SetDefault("kafka.dead_letter.initial_backoff", "500ms")
+	viper.SetDefault("kafka.dead_letter.max_backoff", "30s")
+	viper.SetDefault("kafka.dead_letter.max_replay_batch", 100)
+
 	// Alerting
 	viper.SetDefault("alerting.processing_interval", "10s")
 	viper.SetDefault("alerting.batch_size", 100)
@@ -351,6 +731,7 @@ func setDefaults() {
 
 	viper.SetDefault("notifications.sms.enabled", false)
 	viper.SetDefault("notifications.sms.provider", "twilio")
+	viper.SetDefault("notifications.sms.twilio_base_url", "https://api.twilio.com")
 	viper.SetDefault("notifications.sms.max_retries", 3)
 	viper.SetDefault("notifications.sms.retry_delay", "10s")
 	viper.SetDefault("notifications.sms.timeout", "30s")
@@ -375,6 +756,7 @@ func setDefaults() {
 	viper.SetDefault("notifications.webhook.rate_limit_per_min", 120)
 
 	viper.SetDefault("notifications.pagerduty.enabled", false)
+	viper.SetDefault("notifications.pagerduty.events_url", "https://events.pagerduty.com/v2/enqueue")
 	viper.SetDefault("notifications.pagerduty.max_retries", 3)
 	viper.SetDefault("notifications.pagerduty.retry_delay", "10s")
 	viper.SetDefault("notifications.pagerduty.timeout", "30s")
@@ -397,6 +779,31 @@ func setDefaults() {
 	viper.SetDefault("rules.cache_ttl", "1h")
 	viper.SetDefault("rules.default_severity", "medium")
 	viper.SetDefault("rules.default_priority", "normal")
+	viper.SetDefault("rules.reference_cache.sync_interval", "5s")
+	viper.SetDefault("rules.reference_cache.change_overlap", "1m")
+	viper.SetDefault("rules.reference_cache.change_retention", "24h")
+	viper.SetDefault("rules.reference_cache.load_timeout", "2s")
+	viper.SetDefault("rules.reference_cache.max_lookup_tables", 500)
+	viper.SetDefault("rules.reference_cache.max_risk_tiers", 100000)
+	viper.SetDefault("rules.windows.backend", "memory")
+	viper.SetDefault("rules.windows.max_window_size", "720h")
+	viper.SetDefault("rules.windows.max_entries_per_window", 10000)
+	viper.SetDefault("rules.windows.checkpoint_interval", "1m")
+	viper.SetDefault("rules.windows.checkpoint_instance", "default")
+	viper.SetDefault("rules.windows.redis_key_prefix", "alerting:rule_window:")
+	viper.SetDefault("rules.cel.max_expression_length", 4096)
+	viper.SetDefault("rules.cel.cost_limit", 100000)
+	viper.SetDefault("rules.cel.evaluation_timeout", "50ms")
+	viper.SetDefault("rules.cel.max_fixtures", 50)
+	viper.SetDefault("rules.backtest.enabled", true)
+	viper.SetDefault("rules.backtest.default_window", "24h")
+	viper.SetDefault("rules.backtest.max_window", "168h")
+	viper.SetDefault("rules.backtest.max_events", 2000000)
+	viper.SetDefault("rules.backtest.default_sample_size", 20)
+	viper.SetDefault("rules.backtest.max_sample_size", 100)
+	viper.SetDefault("rules.backtest.max_concurrent", 2)
+	viper.SetDefault("rules.backtest.timeout", "30m")
+	viper.SetDefault("rules.backtest.false_positive_lookback", "2160h")
 
 	// Scheduler
 	viper.SetDefault("scheduler.enabled", true)
@@ -412,6 +819,9 @@ func setDefaults() {
 	viper.SetDefault("security.enable_tls", false)
 	viper.SetDefault("security.enable_authentication", false)
 	viper.SetDefault("security.api_key_header", "X-API-Key")
+	viper.SetDefault("security.rbac_cache_ttl", "1m")
+	viper.SetDefault("security.rbac_cache_max_entries", 10000)
+	viper.SetDefault("security.strict_request_context", false)
 
 	// Logging
 	viper.SetDefault("logging.level", "info")
@@ -422,4 +832,235 @@ func setDefaults() {
 	viper.SetDefault("logging.max_age", 28)
 	viper.SetDefault("logging.compress", true)
 	viper.SetDefault("logging.include_source", false)
-}
\ No newline at end of file
+
+	// Training
+	viper.SetDefault("training.enabled", true)
+	viper.SetDefault("training.sandbox_tenant_prefix", "training")
+	viper.SetDefault("training.default_scenario_count", 20)
+	viper.SetDefault("training.max_scenario_count", 100)
+	viper.SetDefault("training.default_replay_window", "2160h")
+	viper.SetDefault("training.sensitive_fields", []string{
+		"name", "full_name", "first_name", "last_name", "email", "phone",
+		"address", "account_number", "iban", "card_number", "ssn", "tax_id",
+		"date_of_birth", "customer_id", "entity_id", "counterparty",
+	})
+
+	// Watchlist
+	viper.SetDefault("watchlist.pending_change_ttl", "72h")
+	viper.SetDefault("watchlist.default_exclusion_duration", "2160h")
+	viper.SetDefault("watchlist.max_exclusion_duration", "8760h")
+	viper.SetDefault("watchlist.min_justification_length", 20)
+	viper.SetDefault("watchlist.expiry_schedule", "0 */5 * * * *")
+
+	// SLO
+	viper.SetDefault("slo.enabled", true)
+	viper.SetDefault("slo.prometheus_url", "http://prometheus.monitoring:9090")
+	viper.SetDefault("slo.query_timeout", "10s")
+	viper.SetDefault("slo.evaluation_schedule", "0 */5 * * * *")
+	viper.SetDefault("slo.ops_channel", "#aegisshield-ops")
+	viper.SetDefault("slo.fast_burn_rate", 14.4)
+	viper.SetDefault("slo.slow_burn_rate", 6.0)
+	viper.SetDefault("slo.budget_warning_ratio", 0.25)
+	viper.SetDefault("slo.objectives", []map[string]interface{}{
+		{
+			"name":            "api-gateway-availability",
+			"service":         "api-gateway",
+			"type":            "availability",
+			"target":          0.999,
+			"window":          "720h",
+			"requests_metric": "http_requests_total",
+			"selector":        `job="aegisshield-api-gateway",path="/query"`,
+		},
+		{
+			"name":              "api-gateway-latency",
+			"service":           "api-gateway",
+			"type":              "latency",
+			"target":            0.99,
+			"window":            "720h",
+			"duration_metric":   "http_request_duration_seconds",
+			"selector":          `job="aegisshield-api-gateway",path="/query"`,
+			"latency_threshold": "1",
+		},
+		{
+			"name":            "alerting-engine-availability",
+			"service":         "alerting-engine",
+			"type":            "availability",
+			"target":          0.995,
+			"window":          "720h",
+			"requests_metric": "alerting_engine_http_requests_total",
+			"selector":        `job="aegisshield-alerting-engine"`,
+		},
+	})
+
+	// Case sync
+	viper.SetDefault("case_sync.enabled", true)
+	viper.SetDefault("case_sync.dispatch_schedule", "*/30 * * * * *")
+	viper.SetDefault("case_sync.reconcile_schedule", "0 0 2 * * *")
+	viper.SetDefault("case_sync.batch_size", 100)
+	viper.SetDefault("case_sync.delivery_lease", "2m")
+	viper.SetDefault("case_sync.request_timeout", "15s")
+	viper.SetDefault("case_sync.retry_base_delay", "30s")
+	viper.SetDefault("case_sync.max_retry_delay", "1h")
+	viper.SetDefault("case_sync.reconcile_lookback", "168h")
+	viper.SetDefault("case_sync.reconcile_batch_size", 200)
+	viper.SetDefault("case_sync.max_drift_details", 100)
+	viper.SetDefault("case_sync.callback_max_body", 1048576)
+
+	// Batch digest
+	viper.SetDefault("batch_digest.enabled", true)
+	viper.SetDefault("batch_digest.schedule", "0 30 23 * * *")
+	viper.SetDefault("batch_digest.timezone", "UTC")
+	viper.SetDefault("batch_digest.notification_channel", "email")
+	viper.SetDefault("batch_digest.max_findings_per_digest", 200)
+	viper.SetDefault("batch_digest.max_aggregate_batch", 5000)
+
+	// Alert clustering
+	viper.SetDefault("alert_clustering.enabled", true)
+	viper.SetDefault("alert_clustering.schedule", "0 */15 * * * *")
+	viper.SetDefault("alert_clustering.lookback", "720h")
+	viper.SetDefault("alert_clustering.min_cluster_size", 2)
+	viper.SetDefault("alert_clustering.max_bundle_size", 200)
+	viper.SetDefault("alert_clustering.max_alerts_per_run", 5000)
+	viper.SetDefault("alert_clustering.max_context_batch", 5000)
+	viper.SetDefault("alert_clustering.case_api_url", "http://investigation-toolkit:8080/api/v1/investigations")
+	viper.SetDefault("alert_clustering.case_api_timeout", "15s")
+	viper.SetDefault("alert_clustering.default_case_type", "other")
+
+	// Rule packs
+	viper.SetDefault("rule_pack.deployment_id", "aegisshield")
+	viper.SetDefault("rule_pack.signing_key_id", "default")
+	viper.SetDefault("rule_pack.require_signature", true)
+	viper.SetDefault("rule_pack.default_conflict_mode", "skip")
+	viper.SetDefault("rule_pack.max_rules", 500)
+	viper.SetDefault("rule_pack.max_pack_size", 10485760)
+
+	// Alert storms
+	viper.SetDefault("storm.enabled", true)
+	viper.SetDefault("storm.window", "1m")
+	viper.SetDefault("storm.baseline_smoothing", 0.2)
+	viper.SetDefault("storm.min_alerts", 100)
+	viper.SetDefault("storm.rate_multiplier", 10.0)
+	viper.SetDefault("storm.quiet_windows", 5)
+	viper.SetDefault("storm.target_admitted", 10)
+	viper.SetDefault("storm.min_sample_rate", 0.001)
+	viper.SetDefault("storm.reconcile_schedule", "0 * * * * *")
+	viper.SetDefault("storm.auto_reconcile", true)
+	viper.SetDefault("storm.auto_reconcile_delay", "4h")
+	viper.SetDefault("storm.expand_min_severity", "high")
+	viper.SetDefault("storm.reconcile_batch_size", 500)
+
+	// Alert deduplication
+	viper.SetDefault("dedup.enabled", true)
+	viper.SetDefault("dedup.default_window", "1h")
+	viper.SetDefault("dedup.max_window", "168h")
+	viper.SetDefault("dedup.default_fields", []string{})
+	viper.SetDefault("dedup.max_fields", 10)
+	viper.SetDefault("dedup.settings_cache_ttl", "1m")
+	viper.SetDefault("dedup.stats_window", "168h")
+
+	// Startup
+	viper.SetDefault("startup.initial_backoff", "500ms")
+	viper.SetDefault("startup.max_backoff", "30s")
+	viper.SetDefault("startup.max_elapsed", "5m")
+
+	// Risk webhooks
+	viper.SetDefault("risk_webhooks.enabled", true)
+	viper.SetDefault("risk_webhooks.dispatch_schedule", "*/15 * * * * *")
+	viper.SetDefault("risk_webhooks.batch_size", 200)
+	viper.SetDefault("risk_webhooks.delivery_lease", "2m")
+	viper.SetDefault("risk_webhooks.request_timeout", "10s")
+	viper.SetDefault("risk_webhooks.retry_base_delay", "30s")
+	viper.SetDefault("risk_webhooks.max_retry_delay", "1h")
+	viper.SetDefault("risk_webhooks.default_max_attempts", 8)
+	viper.SetDefault("risk_webhooks.max_replay_transitions", 50000)
+
+	// Alert lifecycle
+	viper.SetDefault("alert_lifecycle.sla_targets.critical", "4h")
+	viper.SetDefault("alert_lifecycle.sla_targets.high", "24h")
+	viper.SetDefault("alert_lifecycle.sla_targets.medium", "72h")
+	viper.SetDefault("alert_lifecycle.sla_targets.low", "168h")
+	viper.SetDefault("alert_lifecycle.default_overdue_limit", 100)
+	viper.SetDefault("alert_lifecycle.max_overdue_limit", 1000)
+	viper.SetDefault("alert_lifecycle.handoff.min_receiver_time", "4h")
+	viper.SetDefault("alert_lifecycle.handoff.max_sla_extension", "24h")
+	viper.SetDefault("alert_lifecycle.handoff.max_pending_tasks", 50)
+	viper.SetDefault("alert_lifecycle.handoff.notify_channel", "slack")
+
+	// Notification templates
+	viper.SetDefault("notification_templates.max_template_size", 16384)
+	viper.SetDefault("notification_templates.max_output_size", 65536)
+	viper.SetDefault("notification_templates.cache_ttl", "1m")
+	viper.SetDefault("notification_templates.default_brand_name", "AegisShield")
+
+	// Alert correlation
+	viper.SetDefault("alert_correlation.enabled", true)
+	viper.SetDefault("alert_correlation.window", "24h")
+	viper.SetDefault("alert_correlation.account_fields", []string{"account_id", "from_account", "to_account", "counterparty.account_id"})
+	viper.SetDefault("alert_correlation.max_case_size", 500)
+	viper.SetDefault("alert_correlation.max_matches", 200)
+	viper.SetDefault("alert_correlation.key_retention", "168h")
+	viper.SetDefault("alert_correlation.prune_schedule", "0 30 3 * * *")
+	viper.SetDefault("alert_correlation.investigation_api_url", "http://investigation-toolkit:8080/api/v1/investigations")
+	viper.SetDefault("alert_correlation.investigation_api_timeout", "15s")
+	viper.SetDefault("alert_correlation.default_case_type", "other")
+
+	// Rule feedback
+	viper.SetDefault("rule_feedback.lookback", "2160h")
+	viper.SetDefault("rule_feedback.min_dispositions", 20)
+	viper.SetDefault("rule_feedback.max_samples", 50000)
+	viper.SetDefault("rule_feedback.target_recall", 0.95)
+	viper.SetDefault("rule_feedback.min_precision", 0.1)
+	viper.SetDefault("rule_feedback.threshold_field", "amount")
+	viper.SetDefault("rule_feedback.optimizer_url", "")
+	viper.SetDefault("rule_feedback.optimizer_timeout", "30s")
+
+	// Severity tuning
+	viper.SetDefault("severity_tuning.lookback", "2160h")
+	viper.SetDefault("severity_tuning.min_dispositions", 20)
+	viper.SetDefault("severity_tuning.max_samples", 50000)
+	viper.SetDefault("severity_tuning.loss_field", "amount")
+	viper.SetDefault("severity_tuning.medium_loss", 10000)
+	viper.SetDefault("severity_tuning.high_loss", 100000)
+	viper.SetDefault("severity_tuning.critical_loss", 1000000)
+	viper.SetDefault("severity_tuning.raise_precision", 0.5)
+	viper.SetDefault("severity_tuning.lower_precision", 0.05)
+	viper.SetDefault("severity_tuning.max_step", 1)
+	viper.SetDefault("severity_tuning.proposal_ttl", "168h")
+	viper.SetDefault("severity_tuning.separate_approver", true)
+
+	// Alert stream
+	viper.SetDefault("alert_stream.poll_interval", "1s")
+	viper.SetDefault("alert_stream.heartbeat_interval", "15s")
+	viper.SetDefault("alert_stream.min_heartbeat", "1s")
+	viper.SetDefault("alert_stream.commit_lag", "2s")
+	viper.SetDefault("alert_stream.batch_size", 200)
+	viper.SetDefault("alert_stream.max_subscribers", 500)
+
+	// Rule owners
+	viper.SetDefault("rule_owners.enabled", true)
+	viper.SetDefault("rule_owners.check_schedule", "0 */15 * * * *")
+	viper.SetDefault("rule_owners.digest_schedule", "0 0 8 * * *")
+	viper.SetDefault("rule_owners.error_budget", 0.01)
+	viper.SetDefault("rule_owners.min_evaluations", 100)
+	viper.SetDefault("rule_owners.trend_window", "168h")
+	viper.SetDefault("rule_owners.min_dispositions", 10)
+	viper.SetDefault("rule_owners.max_false_positive_rate", 0.8)
+	viper.SetDefault("rule_owners.min_false_positive_increase", 0.1)
+	viper.SetDefault("rule_owners.event_cooldown", "24h")
+	viper.SetDefault("rule_owners.max_events_per_digest", 1000)
+
+	// Alert auto-close
+	viper.SetDefault("auto_close.enabled", true)
+	viper.SetDefault("auto_close.schedule", "0 30 2 * * *")
+	viper.SetDefault("auto_close.batch_size", 500)
+	viper.SetDefault("auto_close.default_sample_rate", 0.05)
+	viper.SetDefault("auto_close.max_report_closures", 10000)
+	viper.SetDefault("auto_close.policies", []map[string]interface{}{
+		{
+			"name":         "stale-low-severity",
+			"severity":     "low",
+			"inactive_for": "720h",
+			"disposition":  "closed_no_activity",
+		},
+	})
+}
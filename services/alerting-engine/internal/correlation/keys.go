@@ -0,0 +1,272 @@
+package correlation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// Correlation key prefixes; a key is a prefix followed by an identifier
+const (
+	EntityKeyPrefix    = "entity:"
+	AccountKeyPrefix   = "account:"
+	CommunityKeyPrefix = "community:"
+)
+
+// maxKeyLength matches the correlation key column; longer identifiers are not correlated on
+const maxKeyLength = 512
+
+// Keys returns the correlation keys of an alert: the resolved entity of each
+// of its entity ids (the raw id without a stored context), the graph
+// community of each entity, and every account identifier found at the
+// account fields of its triggering event
+func Keys(entityIDs []string, event map[string]interface{}, accountFields []string, contexts map[string]*database.EntityContext) []string {
+	var keys []string
+	for _, entityID := range entityIDs {
+		entityID = strings.TrimSpace(entityID)
+		if entityID == "" {
+			continue
+		}
+
+		resolved := entityID
+		if context, exists := contexts[entityID]; exists {
+			if context.ResolvedEntityID != nil && *context.ResolvedEntityID != "" {
+				resolved = *context.ResolvedEntityID
+			}
+			if context.CommunityID != nil && *context.CommunityID != "" {
+				keys = append(keys, CommunityKeyPrefix+*context.CommunityID)
+			}
+		}
+		keys = append(keys, EntityKeyPrefix+resolved)
+	}
+
+	for _, field := range accountFields {
+		value, ok := lookup(event, field)
+		if !ok {
+			continue
+		}
+		for _, account := range accountValues(value) {
+			keys = append(keys, AccountKeyPrefix+account)
+		}
+	}
+
+	return normalizeKeys(keys)
+}
+
+// Assignment is where a new alert goes: into an existing case, or into a
+// new case together with the earlier alerts it matched
+type Assignment struct {
+	CaseID  string
+	NewCase *database.AlertCase
+	Members []*database.AlertCaseMember
+}
+
+// Assign decides the case a new alert joins given the earlier alerts sharing
+// its keys. It joins the most recently active open or linked case among its
+// matches that has room, taking matched alerts without a case with it; without
+// such a case, a new case is opened for it and those alerts. Alerts of closed
+// cases were dealt with and are not correlated again. Nil means the alert is
+// not correlated.
+func Assign(alert *database.Alert, matches []*database.CorrelationMatch, maxCaseSize int) *Assignment {
+	var target *database.CorrelationMatch
+	var uncased []*database.CorrelationMatch
+	for _, match := range matches {
+		if match.CaseID == nil {
+			uncased = append(uncased, match)
+			continue
+		}
+		if match.CaseStatus == nil || *match.CaseStatus == database.AlertCaseStatusClosed {
+			continue
+		}
+		if maxCaseSize > 0 && match.CaseAlertCount != nil && *match.CaseAlertCount >= maxCaseSize {
+			continue
+		}
+		if target == nil || moreRecent(match, target) {
+			target = match
+		}
+	}
+
+	if target == nil && len(uncased) == 0 {
+		return nil
+	}
+
+	// The new alert matches on every key it shares with the alerts it joins
+	var matchedKeys []string
+	var members []*database.AlertCaseMember
+	for _, match := range uncased {
+		members = append(members, &database.AlertCaseMember{AlertID: match.AlertID, MatchedKeys: match.SharedKeys})
+		matchedKeys = append(matchedKeys, match.SharedKeys...)
+	}
+	if target != nil {
+		for _, match := range matches {
+			if match.CaseID != nil && *match.CaseID == *target.CaseID {
+				matchedKeys = append(matchedKeys, match.SharedKeys...)
+			}
+		}
+	}
+	matchedKeys = normalizeKeys(matchedKeys)
+
+	// Respect the size limit, keeping the new alert and the most recent matches
+	if maxCaseSize > 0 {
+		room := maxCaseSize - 1
+		if target != nil && target.CaseAlertCount != nil {
+			room -= *target.CaseAlertCount
+		}
+		if room < 0 {
+			room = 0
+		}
+		if len(members) > room {
+			members = members[:room]
+		}
+	}
+	if target == nil && len(members) == 0 {
+		return nil
+	}
+	members = append([]*database.AlertCaseMember{{AlertID: alert.ID, MatchedKeys: matchedKeys}}, members...)
+
+	if target != nil {
+		return &Assignment{CaseID: *target.CaseID, Members: members}
+	}
+
+	alertCase := &database.AlertCase{
+		Title:           Title(matchedKeys),
+		Status:          database.AlertCaseStatusOpen,
+		CorrelationKeys: matchedKeys,
+		AlertCount:      len(members),
+		MaxSeverity:     alert.Severity,
+		FirstAlertAt:    alert.CreatedAt,
+		LastAlertAt:     alert.CreatedAt,
+	}
+	for _, match := range uncased[:len(members)-1] {
+		if severityRank(match.Severity) > severityRank(alertCase.MaxSeverity) {
+			alertCase.MaxSeverity = match.Severity
+		}
+		if match.CreatedAt.Before(alertCase.FirstAlertAt) {
+			alertCase.FirstAlertAt = match.CreatedAt
+		}
+		if match.CreatedAt.After(alertCase.LastAlertAt) {
+			alertCase.LastAlertAt = match.CreatedAt
+		}
+	}
+
+	return &Assignment{NewCase: alertCase, Members: members}
+}
+
+// Title describes a case by what its alerts have in common, preferring
+// entities over accounts over network communities
+func Title(keys []string) string {
+	for _, prefix := range []string{EntityKeyPrefix, AccountKeyPrefix, CommunityKeyPrefix} {
+		var ids []string
+		for _, key := range keys {
+			if id, ok := strings.CutPrefix(key, prefix); ok {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		noun := map[string]string{
+			EntityKeyPrefix:    "entity",
+			AccountKeyPrefix:   "account",
+			CommunityKeyPrefix: "network community",
+		}[prefix]
+		if len(ids) == 1 {
+			return fmt.Sprintf("Alerts correlated on %s %s", noun, ids[0])
+		}
+		return fmt.Sprintf("Alerts correlated on %s %s and %d more", noun, ids[0], len(ids)-1)
+	}
+	return "Correlated alerts"
+}
+
+// moreRecent reports whether a's case was active after b's, breaking ties by case ID
+func moreRecent(a, b *database.CorrelationMatch) bool {
+	var at, bt time.Time
+	if a.CaseLastAlertAt != nil {
+		at = *a.CaseLastAlertAt
+	}
+	if b.CaseLastAlertAt != nil {
+		bt = *b.CaseLastAlertAt
+	}
+	if !at.Equal(bt) {
+		return at.After(bt)
+	}
+	return *a.CaseID < *b.CaseID
+}
+
+// accountValues returns the account identifiers held by an event value: a
+// string or number, or a list of them
+func accountValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v = strings.TrimSpace(v); v != "" {
+			return []string{v}
+		}
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case int, int64, json.Number:
+		return []string{fmt.Sprint(v)}
+	case []interface{}:
+		var accounts []string
+		for _, item := range v {
+			accounts = append(accounts, accountValues(item)...)
+		}
+		return accounts
+	case []string:
+		var accounts []string
+		for _, item := range v {
+			accounts = append(accounts, accountValues(item)...)
+		}
+		return accounts
+	}
+	return nil
+}
+
+// lookup resolves a dotted path through nested event maps
+func lookup(event map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = event
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// normalizeKeys drops empty and oversized keys and sorts the rest without duplicates
+func normalizeKeys(keys []string) []string {
+	seen := make(map[string]bool, len(keys))
+	normalized := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key == "" || len(key) > maxKeyLength || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, key)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,380 @@
+package correlation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/engine"
+	"github.com/aegisshield/shared/reqctx"
+)
+
+// Service correlates new alerts into alert cases as they are raised, and
+// links cases to investigation-toolkit investigations
+type Service struct {
+	config   *config.Config
+	logger   *slog.Logger
+	repo     *database.AlertCaseRepository
+	contexts *database.AlertClusterRepository
+	client   *http.Client
+}
+
+// CaseDetail is an alert case together with its member alerts
+type CaseDetail struct {
+	*database.AlertCase
+	Alerts []*database.AlertCaseAlert `json:"alerts"`
+}
+
+// LinkInput describes the investigation a case is linked to. Without an
+// InvestigationID a new investigation is created from the case.
+type LinkInput struct {
+	Actor           string `json:"actor"`
+	InvestigationID string `json:"investigation_id,omitempty"`
+	Title           string `json:"title,omitempty"`
+	CaseType        string `json:"case_type,omitempty"`
+	Priority        string `json:"priority,omitempty"`
+}
+
+// LinkResult is a linked case together with its investigation
+type LinkResult struct {
+	Case                 *database.AlertCase `json:"case"`
+	InvestigationID      string              `json:"investigation_id"`
+	InvestigationCreated bool                `json:"investigation_created"`
+}
+
+// CloseInput describes why a case was closed
+type CloseInput struct {
+	Actor  string `json:"actor"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// NewService creates a new alert correlation service. Entity contexts loaded
+// for alert clustering give the resolved entities and graph communities
+// alerts are correlated on.
+func NewService(cfg *config.Config, logger *slog.Logger, repo *database.AlertCaseRepository, contexts *database.AlertClusterRepository) *Service {
+	return &Service{
+		config:   cfg,
+		logger:   logger,
+		repo:     repo,
+		contexts: contexts,
+		client:   &http.Client{Timeout: cfg.AlertCorrelation.InvestigationAPITimeout, Transport: reqctx.NewTransport("alerting-engine", nil)},
+	}
+}
+
+// Correlate adds a newly raised alert to the case of the alerts it shares an
+// entity, account or graph community with inside the correlation window,
+// opening a case when none of them has one yet
+func (s *Service) Correlate(ctx context.Context, alert *database.Alert, event map[string]interface{}) error {
+	cfg := s.config.AlertCorrelation
+	if !cfg.Enabled {
+		return nil
+	}
+
+	entityIDs := dedupe(append(append([]string(nil), alert.EntityIDs...), engine.EventEntityIDs(event)...))
+	contexts := make(map[string]*database.EntityContext)
+	if len(entityIDs) > 0 {
+		stored, err := s.contexts.ListEntityContexts(ctx, entityIDs)
+		if err != nil {
+			return err
+		}
+		for _, entity := range stored {
+			contexts[entity.EntityID] = entity
+		}
+	}
+
+	keys := Keys(entityIDs, event, cfg.AccountFields, contexts)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	createdAt := alert.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	if err := s.repo.StoreKeys(ctx, alert.ID, createdAt, keys); err != nil {
+		return err
+	}
+
+	limit := cfg.MaxMatches
+	if limit <= 0 {
+		limit = 200
+	}
+	matches, err := s.repo.ListMatches(ctx, alert.ID, keys, createdAt.Add(-cfg.Window), limit)
+	if err != nil {
+		return err
+	}
+
+	assignment := Assign(alert, matches, cfg.MaxCaseSize)
+	if assignment == nil {
+		return nil
+	}
+
+	if assignment.NewCase != nil {
+		assignment.NewCase.ID = generateID("alert_case")
+		return s.repo.CreateCase(ctx, assignment.NewCase, assignment.Members)
+	}
+
+	alertCase, err := s.repo.AddToCase(ctx, assignment.CaseID, assignment.Members)
+	if err != nil {
+		if errors.Is(err, database.ErrAlertCaseClosed) {
+			// Closed since the matches were read; the alert stays uncorrelated
+			return nil
+		}
+		return err
+	}
+
+	s.logger.Debug("Alert correlated into case",
+		"alert_id", alert.ID,
+		"case_id", alertCase.ID,
+		"alerts", alertCase.AlertCount)
+	return nil
+}
+
+// ListCases returns cases, optionally in one status
+func (s *Service) ListCases(ctx context.Context, status string, limit int) ([]*database.AlertCase, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	return s.repo.ListCases(ctx, status, limit)
+}
+
+// GetCase returns a case with its member alerts
+func (s *Service) GetCase(ctx context.Context, id string) (*CaseDetail, error) {
+	alertCase, err := s.repo.GetCase(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := s.repo.ListCaseAlerts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CaseDetail{AlertCase: alertCase, Alerts: alerts}, nil
+}
+
+// GetAlertCase returns the case an alert was correlated into
+func (s *Service) GetAlertCase(ctx context.Context, alertID string) (*database.AlertCase, error) {
+	return s.repo.GetCaseForAlert(ctx, alertID)
+}
+
+// ValidateLinkInput checks a link request before any investigation is created
+func ValidateLinkInput(input LinkInput) error {
+	if strings.TrimSpace(input.Actor) == "" {
+		return fmt.Errorf("actor is required")
+	}
+
+	switch input.CaseType {
+	case "", "fraud", "money_laundering", "sanctions", "kyc", "other":
+	default:
+		return fmt.Errorf("unsupported case_type %q", input.CaseType)
+	}
+
+	switch input.Priority {
+	case "", "low", "medium", "high", "critical":
+	default:
+		return fmt.Errorf("unsupported priority %q", input.Priority)
+	}
+
+	return nil
+}
+
+// LinkInvestigation links an open case to the given investigation, or to a
+// new investigation created from it, and links every alert of the case to
+// the investigation. Alerts joining the case afterwards are linked as well.
+func (s *Service) LinkInvestigation(ctx context.Context, id string, input LinkInput) (*LinkResult, error) {
+	if err := ValidateLinkInput(input); err != nil {
+		return nil, err
+	}
+
+	alertCase, err := s.repo.GetCase(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if alertCase.Status != database.AlertCaseStatusOpen {
+		return nil, database.ErrAlertCaseNotOpen
+	}
+
+	investigationID := strings.TrimSpace(input.InvestigationID)
+	created := false
+	if investigationID == "" {
+		investigationID, err = s.createInvestigation(ctx, alertCase, input)
+		if err != nil {
+			return nil, err
+		}
+		created = true
+	}
+
+	linked, err := s.repo.LinkCase(ctx, id, investigationID, input.Actor)
+	if err != nil {
+		if created {
+			// The investigation exists but no alerts were linked; leave it for the analyst to reuse or close
+			s.logger.Warn("Investigation created for an alert case that could not be linked",
+				"case_id", id,
+				"investigation_id", investigationID,
+				"error", err)
+		}
+		return nil, err
+	}
+
+	return &LinkResult{Case: linked, InvestigationID: investigationID, InvestigationCreated: created}, nil
+}
+
+// CloseCase closes a case so later alerts no longer join it
+func (s *Service) CloseCase(ctx context.Context, id string, input CloseInput) (*database.AlertCase, error) {
+	if strings.TrimSpace(input.Actor) == "" {
+		return nil, fmt.Errorf("actor is required")
+	}
+
+	if _, err := s.repo.GetCase(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CloseCase(ctx, id, input.Actor, truncate(input.Reason, 2000))
+}
+
+// PruneKeys forgets the correlation keys of alerts older than the key
+// retention, which never drops below the correlation window
+func (s *Service) PruneKeys(ctx context.Context) (int64, error) {
+	cfg := s.config.AlertCorrelation
+	retention := cfg.KeyRetention
+	if retention < cfg.Window {
+		retention = cfg.Window
+	}
+
+	pruned, err := s.repo.PruneKeys(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("Alert correlation keys pruned", "pruned", pruned)
+	return pruned, nil
+}
+
+// createInvestigation opens an investigation for a case in the investigation toolkit
+func (s *Service) createInvestigation(ctx context.Context, alertCase *database.AlertCase, input LinkInput) (string, error) {
+	endpoint := s.config.AlertCorrelation.InvestigationAPIURL
+	if endpoint == "" {
+		return "", fmt.Errorf("investigation_id is required when no investigation API is configured")
+	}
+
+	payload := map[string]interface{}{
+		"title":       defaultString(strings.TrimSpace(input.Title), alertCase.Title),
+		"description": describeCase(alertCase),
+		"case_type":   defaultString(input.CaseType, s.config.AlertCorrelation.DefaultCaseType),
+		"priority":    defaultString(input.Priority, casePriority(alertCase.MaxSeverity)),
+		"tags":        []string{"alert-case"},
+		"metadata": map[string]interface{}{
+			"alert_case_id":    alertCase.ID,
+			"correlation_keys": alertCase.CorrelationKeys,
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal investigation request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create investigation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", input.Actor)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to create investigation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("investigation API returned %d: %s", resp.StatusCode, truncate(string(respBody), 500))
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(respBody, &created); err != nil || created.ID == "" {
+		return "", errors.New("investigation API response did not include an investigation id")
+	}
+
+	s.logger.Info("Investigation created for alert case",
+		"case_id", alertCase.ID,
+		"investigation_id", created.ID,
+		"alerts", alertCase.AlertCount)
+	return created.ID, nil
+}
+
+func describeCase(alertCase *database.AlertCase) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Opened from alert case %s covering %d correlated alerts (highest severity %s) raised between %s and %s.",
+		alertCase.ID, alertCase.AlertCount, alertCase.MaxSeverity,
+		alertCase.FirstAlertAt.UTC().Format(time.RFC3339), alertCase.LastAlertAt.UTC().Format(time.RFC3339))
+	for _, group := range []struct{ prefix, label string }{
+		{EntityKeyPrefix, "Shared entities"},
+		{AccountKeyPrefix, "Shared accounts"},
+		{CommunityKeyPrefix, "Network communities"},
+	} {
+		var ids []string
+		for _, key := range alertCase.CorrelationKeys {
+			if id, ok := strings.CutPrefix(key, group.prefix); ok {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > 0 {
+			fmt.Fprintf(&b, "\n%s: %s", group.label, strings.Join(ids, ", "))
+		}
+	}
+	return b.String()
+}
+
+// casePriority maps the most severe alert in a case to an investigation priority
+func casePriority(severity string) string {
+	switch severity {
+	case "critical", "high", "medium", "low":
+		return severity
+	}
+	return "medium"
+}
+
+func dedupe(values []string) []string {
+	result := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" && !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func defaultString(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
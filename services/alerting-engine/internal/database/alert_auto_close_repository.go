@@ -0,0 +1,247 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Auto-closure review outcomes: the reviewer agreed the alert was safe to
+// close unworked, or disagreed and it needed an investigator
+const (
+	AutoClosureReviewAgreed    = "agreed"
+	AutoClosureReviewDisagreed = "disagreed"
+)
+
+var (
+	// ErrAlertActive is returned when an alert saw activity after it was
+	// selected for auto-closing
+	ErrAlertActive = errors.New("alert has recent activity")
+	// ErrAutoClosureReviewed is returned when reviewing an auto-closure twice
+	ErrAutoClosureReviewed = errors.New("auto-closure already reviewed")
+)
+
+// alertLastActivity is when someone last acted on alert a, or a repeat of
+// it was suppressed into it. GREATEST skips the NULLs of states never entered.
+const alertLastActivity = `GREATEST(
+	a.created_at, a.acknowledged_at, a.triaged_at, a.assigned_at, a.escalated_at, a.last_suppressed_at,
+	(SELECT MAX(t.created_at) FROM alert_state_transitions t WHERE t.alert_id = a.id),
+	(SELECT MAX(c.created_at) FROM alert_comments c WHERE c.alert_id = a.id AND c.deleted_at IS NULL)
+)`
+
+// StaleAlert is an open alert with when it last saw activity
+type StaleAlert struct {
+	Alert
+	LastActivityAt time.Time `db:"last_activity_at" json:"last_activity_at"`
+}
+
+// StaleAlertFilter selects open alerts of a severity without activity since
+// InactiveSince. RuleIDs narrows them to the given rules; ExcludeRuleIDs
+// leaves out rules another policy covers.
+type StaleAlertFilter struct {
+	Statuses       []string
+	Severity       string
+	RuleIDs        []string
+	ExcludeRuleIDs []string
+	InactiveSince  time.Time
+	Limit          int
+}
+
+// AlertAutoClosure records a stale alert closed by an auto-close policy and
+// the outcome of its sampling review
+type AlertAutoClosure struct {
+	AlertID            string     `db:"alert_id" json:"alert_id"`
+	Policy             string     `db:"policy" json:"policy"`
+	RuleID             string     `db:"rule_id" json:"rule_id"`
+	Severity           string     `db:"severity" json:"severity"`
+	FromStatus         string     `db:"from_status" json:"from_status"`
+	Disposition        string     `db:"disposition" json:"disposition"`
+	InactiveForSeconds int64      `db:"inactive_for_seconds" json:"inactive_for_seconds"`
+	LastActivityAt     time.Time  `db:"last_activity_at" json:"last_activity_at"`
+	ClosedBy           string     `db:"closed_by" json:"closed_by"`
+	ClosedAt           time.Time  `db:"closed_at" json:"closed_at"`
+	Sampled            bool       `db:"sampled" json:"sampled"`
+	ReviewOutcome      *string    `db:"review_outcome" json:"review_outcome,omitempty"`
+	ReviewNotes        *string    `db:"review_notes" json:"review_notes,omitempty"`
+	ReviewedBy         *string    `db:"reviewed_by" json:"reviewed_by,omitempty"`
+	ReviewedAt         *time.Time `db:"reviewed_at" json:"reviewed_at,omitempty"`
+}
+
+// AutoClosureFilter selects auto-closures closed in [From, To)
+type AutoClosureFilter struct {
+	From          time.Time
+	To            time.Time
+	Policy        string
+	RuleID        string
+	SampledOnly   bool
+	PendingReview bool
+	Limit         int
+}
+
+// AlertAutoCloseRepository handles stale alert lookups and auto-closures
+type AlertAutoCloseRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewAlertAutoCloseRepository creates a new alert auto-close repository
+func NewAlertAutoCloseRepository(db *sqlx.DB, logger *slog.Logger) *AlertAutoCloseRepository {
+	return &AlertAutoCloseRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// ListStale retrieves alerts matching the filter, least recently active first
+func (r *AlertAutoCloseRepository) ListStale(ctx context.Context, filter StaleAlertFilter) ([]*StaleAlert, error) {
+	conditions := []string{
+		"a.status = ANY($1)",
+		"a.severity = $2",
+		"a.deleted_at IS NULL",
+		"a.created_at < $3",
+		"activity.last_activity_at < $3",
+	}
+	args := []interface{}{pq.StringArray(filter.Statuses), filter.Severity, filter.InactiveSince}
+	if len(filter.RuleIDs) > 0 {
+		args = append(args, pq.StringArray(filter.RuleIDs))
+		conditions = append(conditions, fmt.Sprintf("a.rule_id = ANY($%d)", len(args)))
+	}
+	if len(filter.ExcludeRuleIDs) > 0 {
+		args = append(args, pq.StringArray(filter.ExcludeRuleIDs))
+		conditions = append(conditions, fmt.Sprintf("NOT (a.rule_id = ANY($%d))", len(args)))
+	}
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`
+		SELECT a.*, activity.last_activity_at
+		FROM alerts a
+		CROSS JOIN LATERAL (SELECT %s AS last_activity_at) AS activity
+		WHERE %s
+		ORDER BY activity.last_activity_at ASC
+		LIMIT $%d`, alertLastActivity, strings.Join(conditions, " AND "), len(args))
+
+	var alerts []*StaleAlert
+	if err := r.db.SelectContext(ctx, &alerts, query, args...); err != nil {
+		r.logger.Error("Failed to list stale alerts", "severity", filter.Severity, "error", err)
+		return nil, fmt.Errorf("failed to list stale alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// Close auto-closes an alert: it applies the transition and its resolution
+// note and records the closure in one transaction. It returns ErrAlertActive
+// when the alert saw activity after the closure's last activity, and
+// ErrStaleTransition when its status changed.
+func (r *AlertAutoCloseRepository) Close(ctx context.Context, closure *AlertAutoClosure, transition *AlertTransition, note *AlertComment) (*Alert, error) {
+	var alert *Alert
+	err := r.Transaction(func(tx *sqlx.Tx) error {
+		// lock the alert so no transition lands between the check and the close
+		var lastActivity time.Time
+		err := tx.QueryRowxContext(ctx, fmt.Sprintf(`
+			SELECT %s FROM alerts a
+			WHERE a.id = $1 AND a.deleted_at IS NULL
+			FOR UPDATE`, alertLastActivity), closure.AlertID).Scan(&lastActivity)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrStaleTransition
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check alert activity: %w", err)
+		}
+		if lastActivity.After(closure.LastActivityAt) {
+			return ErrAlertActive
+		}
+
+		alert, err = transitionAlert(ctx, tx, transition, note)
+		if err != nil {
+			return err
+		}
+
+		closure.ClosedAt = transition.CreatedAt
+		if _, err := tx.NamedExecContext(ctx, `
+			INSERT INTO alert_auto_closures (
+				alert_id, policy, rule_id, severity, from_status, disposition,
+				inactive_for_seconds, last_activity_at, closed_by, closed_at, sampled
+			) VALUES (
+				:alert_id, :policy, :rule_id, :severity, :from_status, :disposition,
+				:inactive_for_seconds, :last_activity_at, :closed_by, :closed_at, :sampled
+			)`, closure); err != nil {
+			return fmt.Errorf("failed to record auto-closure: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrStaleTransition) && !errors.Is(err, ErrAlertActive) {
+			r.logger.Error("Failed to auto-close alert",
+				"alert_id", closure.AlertID,
+				"policy", closure.Policy,
+				"error", err)
+		}
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+// GetClosure retrieves an alert's auto-closure
+func (r *AlertAutoCloseRepository) GetClosure(ctx context.Context, alertID string) (*AlertAutoClosure, error) {
+	var closure AlertAutoClosure
+	if err := r.db.GetContext(ctx, &closure, `SELECT * FROM alert_auto_closures WHERE alert_id = $1`, alertID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get auto-closure: %w", err)
+	}
+	return &closure, nil
+}
+
+// ListClosures retrieves auto-closures matching the filter, newest first
+func (r *AlertAutoCloseRepository) ListClosures(ctx context.Context, filter AutoClosureFilter) ([]*AlertAutoClosure, error) {
+	query := `
+		SELECT * FROM alert_auto_closures
+		WHERE closed_at >= $1 AND closed_at < $2
+		AND ($3 = '' OR policy = $3)
+		AND ($4 = '' OR rule_id = $4)
+		AND (NOT $5 OR sampled)
+		AND (NOT $6 OR reviewed_at IS NULL)
+		ORDER BY closed_at DESC
+		LIMIT $7`
+
+	var closures []*AlertAutoClosure
+	if err := r.db.SelectContext(ctx, &closures, query,
+		filter.From, filter.To, filter.Policy, filter.RuleID, filter.SampledOnly, filter.PendingReview, filter.Limit); err != nil {
+		r.logger.Error("Failed to list auto-closures", "error", err)
+		return nil, fmt.Errorf("failed to list auto-closures: %w", err)
+	}
+	return closures, nil
+}
+
+// ReviewClosure records the outcome of reviewing an auto-closure. It returns
+// sql.ErrNoRows when the alert was not auto-closed and
+// ErrAutoClosureReviewed when it was already reviewed.
+func (r *AlertAutoCloseRepository) ReviewClosure(ctx context.Context, alertID, outcome string, notes *string, reviewer string, at time.Time) (*AlertAutoClosure, error) {
+	var closure AlertAutoClosure
+	err := r.db.QueryRowxContext(ctx, `
+		UPDATE alert_auto_closures
+		SET review_outcome = $2, review_notes = $3, reviewed_by = $4, reviewed_at = $5
+		WHERE alert_id = $1 AND reviewed_at IS NULL
+		RETURNING *`, alertID, outcome, notes, reviewer, at).StructScan(&closure)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, getErr := r.GetClosure(ctx, alertID); getErr != nil {
+			return nil, getErr
+		}
+		return nil, ErrAutoClosureReviewed
+	}
+	if err != nil {
+		r.logger.Error("Failed to review auto-closure", "alert_id", alertID, "error", err)
+		return nil, fmt.Errorf("failed to review auto-closure: %w", err)
+	}
+	return &closure, nil
+}
@@ -0,0 +1,434 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrAlertCaseNotFound is returned when an alert case does not exist
+	ErrAlertCaseNotFound = errors.New("alert case not found")
+	// ErrAlertCaseNotOpen is returned when an alert case was already linked or closed
+	ErrAlertCaseNotOpen = errors.New("alert case is no longer open")
+	// ErrAlertCaseClosed is returned when alerts are added to a closed case
+	ErrAlertCaseClosed = errors.New("alert case is closed")
+)
+
+// AlertCaseRepository handles alert cases, their member alerts and the
+// correlation keys new alerts are matched on
+type AlertCaseRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewAlertCaseRepository creates a new alert case repository
+func NewAlertCaseRepository(db *sqlx.DB, logger *slog.Logger) *AlertCaseRepository {
+	return &AlertCaseRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Correlation key operations
+
+// StoreKeys records an alert's correlation keys so later alerts can match it
+func (a *AlertCaseRepository) StoreKeys(ctx context.Context, alertID string, createdAt time.Time, keys []string) error {
+	_, err := a.db.ExecContext(ctx, `
+		INSERT INTO alert_correlation_keys (alert_id, correlation_key, alert_created_at)
+		SELECT $1, unnest($2::text[]), $3
+		ON CONFLICT (alert_id, correlation_key) DO NOTHING`,
+		alertID, pq.Array(keys), createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to store correlation keys: %w", err)
+	}
+
+	return nil
+}
+
+// ListMatches retrieves the alerts raised since the given time that share
+// any of the keys, most recent first, with the keys they share and the case
+// each already belongs to
+func (a *AlertCaseRepository) ListMatches(ctx context.Context, alertID string, keys []string, since time.Time, limit int) ([]*CorrelationMatch, error) {
+	query := `
+		SELECT k.alert_id, a.severity, k.alert_created_at,
+			array_agg(k.correlation_key ORDER BY k.correlation_key) AS shared_keys,
+			c.id AS case_id, c.status AS case_status,
+			c.alert_count AS case_alert_count, c.last_alert_at AS case_last_alert_at
+		FROM alert_correlation_keys k
+		JOIN alerts a ON a.id = k.alert_id
+		LEFT JOIN alert_case_members m ON m.alert_id = k.alert_id
+		LEFT JOIN alert_cases c ON c.id = m.case_id
+		WHERE k.correlation_key = ANY($1)
+		AND k.alert_id <> $2
+		AND k.alert_created_at >= $3
+		AND a.deleted_at IS NULL
+		GROUP BY k.alert_id, a.severity, k.alert_created_at, c.id, c.status, c.alert_count, c.last_alert_at
+		ORDER BY k.alert_created_at DESC
+		LIMIT $4`
+
+	var matches []*CorrelationMatch
+	if err := a.db.SelectContext(ctx, &matches, query, pq.Array(keys), alertID, since, limit); err != nil {
+		return nil, fmt.Errorf("failed to list correlation matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+// PruneKeys deletes the correlation keys of alerts raised before the given
+// time, returning how many were removed
+func (a *AlertCaseRepository) PruneKeys(ctx context.Context, before time.Time) (int64, error) {
+	result, err := a.db.ExecContext(ctx, `DELETE FROM alert_correlation_keys WHERE alert_created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune correlation keys: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// Case operations
+
+// CreateCase stores a new case together with its first member alerts
+func (a *AlertCaseRepository) CreateCase(ctx context.Context, alertCase *AlertCase, members []*AlertCaseMember) error {
+	now := time.Now()
+	alertCase.CreatedAt = now
+	alertCase.UpdatedAt = now
+
+	err := a.Transaction(func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, `
+			INSERT INTO alert_cases (
+				id, title, status, correlation_keys, alert_count, max_severity,
+				first_alert_at, last_alert_at, created_at, updated_at
+			) VALUES (
+				:id, :title, :status, :correlation_keys, :alert_count, :max_severity,
+				:first_alert_at, :last_alert_at, :created_at, :updated_at
+			)`, alertCase); err != nil {
+			return fmt.Errorf("failed to create alert case: %w", err)
+		}
+
+		return addMembers(ctx, tx, alertCase, members)
+	})
+	if err != nil {
+		a.logger.Error("Failed to create alert case", "case_id", alertCase.ID, "error", err)
+		return err
+	}
+
+	a.logger.Info("Alert case opened",
+		"case_id", alertCase.ID,
+		"alerts", alertCase.AlertCount)
+	return nil
+}
+
+// AddToCase adds alerts to an open or linked case. Alerts of a linked case
+// are linked to its investigation as they join.
+func (a *AlertCaseRepository) AddToCase(ctx context.Context, caseID string, members []*AlertCaseMember) (*AlertCase, error) {
+	var alertCase AlertCase
+
+	err := a.Transaction(func(tx *sqlx.Tx) error {
+		if err := tx.GetContext(ctx, &alertCase, `SELECT * FROM alert_cases WHERE id = $1 FOR UPDATE`, caseID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrAlertCaseNotFound
+			}
+			return fmt.Errorf("failed to get alert case: %w", err)
+		}
+		if alertCase.Status == AlertCaseStatusClosed {
+			return ErrAlertCaseClosed
+		}
+
+		return addMembers(ctx, tx, &alertCase, members)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &alertCase, nil
+}
+
+// addMembers adds alerts to a case and refreshes its totals. An alert that
+// already belongs to a case stays where it is.
+func addMembers(ctx context.Context, tx *sqlx.Tx, alertCase *AlertCase, members []*AlertCaseMember) error {
+	stmt, err := tx.PreparexContext(ctx, `
+		INSERT INTO alert_case_members (alert_id, case_id, matched_keys, added_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (alert_id) DO NOTHING`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare alert case member insert: %w", err)
+	}
+	defer stmt.Close()
+
+	alertIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		if _, err := stmt.ExecContext(ctx, member.AlertID, alertCase.ID, pq.Array(member.MatchedKeys)); err != nil {
+			return fmt.Errorf("failed to add alert %s to case: %w", member.AlertID, err)
+		}
+		alertIDs = append(alertIDs, member.AlertID)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE alerts SET correlation_id = $2
+		WHERE id = ANY($1)
+		AND EXISTS (SELECT 1 FROM alert_case_members m WHERE m.alert_id = alerts.id AND m.case_id = $2)`,
+		pq.Array(alertIDs), alertCase.ID); err != nil {
+		return fmt.Errorf("failed to set alert correlation ids: %w", err)
+	}
+
+	if alertCase.InvestigationID != nil {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO alert_case_links (alert_id, case_id, linked_by, linked_at)
+			SELECT m.alert_id, $2, 'correlation', NOW()
+			FROM alert_case_members m
+			WHERE m.case_id = $1 AND m.alert_id = ANY($3)
+			ON CONFLICT (alert_id, case_id) DO NOTHING`,
+			alertCase.ID, *alertCase.InvestigationID, pq.Array(alertIDs)); err != nil {
+			return fmt.Errorf("failed to link alerts to investigation: %w", err)
+		}
+	}
+
+	err = tx.GetContext(ctx, alertCase, `
+		UPDATE alert_cases c
+		SET alert_count = s.alert_count,
+			max_severity = s.max_severity,
+			first_alert_at = s.first_alert_at,
+			last_alert_at = s.last_alert_at,
+			correlation_keys = s.correlation_keys
+		FROM (
+			SELECT count(*) AS alert_count,
+				(array_agg(a.severity ORDER BY
+					CASE a.severity WHEN 'critical' THEN 4 WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END DESC))[1] AS max_severity,
+				min(a.created_at) AS first_alert_at,
+				max(a.created_at) AS last_alert_at,
+				ARRAY(
+					SELECT DISTINCT unnest(mm.matched_keys)
+					FROM alert_case_members mm
+					WHERE mm.case_id = $1
+					ORDER BY 1
+				) AS correlation_keys
+			FROM alert_case_members m
+			JOIN alerts a ON a.id = m.alert_id
+			WHERE m.case_id = $1
+		) s
+		WHERE c.id = $1
+		RETURNING c.*`, alertCase.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update alert case totals: %w", err)
+	}
+
+	return nil
+}
+
+// GetCase retrieves an alert case by ID
+func (a *AlertCaseRepository) GetCase(ctx context.Context, id string) (*AlertCase, error) {
+	var alertCase AlertCase
+	if err := a.db.GetContext(ctx, &alertCase, `SELECT * FROM alert_cases WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAlertCaseNotFound
+		}
+		return nil, fmt.Errorf("failed to get alert case: %w", err)
+	}
+
+	return &alertCase, nil
+}
+
+// ListCases retrieves cases, optionally in one status, most recently active first
+func (a *AlertCaseRepository) ListCases(ctx context.Context, status string, limit int) ([]*AlertCase, error) {
+	query := `
+		SELECT * FROM alert_cases
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY last_alert_at DESC, id
+		LIMIT $2`
+
+	var cases []*AlertCase
+	if err := a.db.SelectContext(ctx, &cases, query, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to list alert cases: %w", err)
+	}
+
+	return cases, nil
+}
+
+// ListDecidedCasesBetween retrieves a random sample of the cases first
+// alerted within a time range that were closed with a reason or linked to an
+// investigation, optionally restricted to a set of maximum severities
+func (a *AlertCaseRepository) ListDecidedCasesBetween(ctx context.Context, from, to time.Time, severities []string, limit int) ([]*AlertCase, error) {
+	query := `
+		SELECT * FROM alert_cases
+		WHERE ((status = 'closed' AND close_reason IS NOT NULL) OR status = 'linked')
+		AND first_alert_at BETWEEN $1 AND $2
+		AND (COALESCE(cardinality($3::text[]), 0) = 0 OR max_severity = ANY($3))
+		ORDER BY random()
+		LIMIT $4`
+
+	var cases []*AlertCase
+	if err := a.db.SelectContext(ctx, &cases, query, from, to, pq.Array(severities), limit); err != nil {
+		return nil, fmt.Errorf("failed to list decided alert cases: %w", err)
+	}
+
+	return cases, nil
+}
+
+// ListCaseAlerts retrieves the member alerts of a case in the order they were raised
+func (a *AlertCaseRepository) ListCaseAlerts(ctx context.Context, caseID string) ([]*AlertCaseAlert, error) {
+	query := `
+		SELECT a.id, a.rule_id, a.title, a.severity, a.status,
+			COALESCE(a.entity_ids, '{}') AS entity_ids, m.matched_keys, a.created_at, m.added_at
+		FROM alert_case_members m
+		JOIN alerts a ON a.id = m.alert_id
+		WHERE m.case_id = $1
+		ORDER BY a.created_at, a.id`
+
+	var alerts []*AlertCaseAlert
+	if err := a.db.SelectContext(ctx, &alerts, query, caseID); err != nil {
+		return nil, fmt.Errorf("failed to list alert case alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// GetCaseForAlert retrieves the case an alert belongs to
+func (a *AlertCaseRepository) GetCaseForAlert(ctx context.Context, alertID string) (*AlertCase, error) {
+	var alertCase AlertCase
+	err := a.db.GetContext(ctx, &alertCase, `
+		SELECT c.* FROM alert_cases c
+		JOIN alert_case_members m ON m.case_id = c.id
+		WHERE m.alert_id = $1`, alertID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAlertCaseNotFound
+		}
+		return nil, fmt.Errorf("failed to get alert case for alert: %w", err)
+	}
+
+	return &alertCase, nil
+}
+
+// LinkCase links an open case to an investigation and links each of its
+// alerts to the investigation in one transaction
+func (a *AlertCaseRepository) LinkCase(ctx context.Context, id, investigationID, actor string) (*AlertCase, error) {
+	var alertCase AlertCase
+
+	err := a.Transaction(func(tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, &alertCase, `
+			UPDATE alert_cases
+			SET status = 'linked', investigation_id = $2, linked_by = $3, linked_at = NOW()
+			WHERE id = $1 AND status = 'open'
+			RETURNING *`, id, investigationID, actor)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrAlertCaseNotOpen
+			}
+			return fmt.Errorf("failed to link alert case: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO alert_case_links (alert_id, case_id, linked_by, linked_at)
+			SELECT alert_id, $2, $3, NOW()
+			FROM alert_case_members
+			WHERE case_id = $1
+			ON CONFLICT (alert_id, case_id) DO NOTHING`,
+			id, investigationID, actor); err != nil {
+			return fmt.Errorf("failed to link alerts to investigation: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Alert case linked to investigation",
+		"case_id", id,
+		"investigation_id", investigationID,
+		"alerts", alertCase.AlertCount,
+		"linked_by", actor)
+	return &alertCase, nil
+}
+
+// CloseCase closes an open or linked case so no further alerts join it
+func (a *AlertCaseRepository) CloseCase(ctx context.Context, id, actor, reason string) (*AlertCase, error) {
+	var alertCase AlertCase
+	err := a.db.GetContext(ctx, &alertCase, `
+		UPDATE alert_cases
+		SET status = 'closed', closed_by = $2, closed_at = NOW(), close_reason = NULLIF($3, '')
+		WHERE id = $1 AND status <> 'closed'
+		RETURNING *`, id, actor, reason)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAlertCaseClosed
+		}
+		return nil, fmt.Errorf("failed to close alert case: %w", err)
+	}
+
+	a.logger.Info("Alert case closed", "case_id", id, "closed_by", actor)
+	return &alertCase, nil
+}
+
+// Alert case types
+
+// Alert case statuses
+const (
+	AlertCaseStatusOpen   = "open"
+	AlertCaseStatusLinked = "linked"
+	AlertCaseStatusClosed = "closed"
+)
+
+// AlertCase is a group of alerts correlated through shared entities,
+// accounts or graph communities
+type AlertCase struct {
+	ID              string         `db:"id" json:"id"`
+	Title           string         `db:"title" json:"title"`
+	Status          string         `db:"status" json:"status"`
+	CorrelationKeys pq.StringArray `db:"correlation_keys" json:"correlation_keys"`
+	AlertCount      int            `db:"alert_count" json:"alert_count"`
+	MaxSeverity     string         `db:"max_severity" json:"max_severity"`
+	FirstAlertAt    time.Time      `db:"first_alert_at" json:"first_alert_at"`
+	LastAlertAt     time.Time      `db:"last_alert_at" json:"last_alert_at"`
+	InvestigationID *string        `db:"investigation_id" json:"investigation_id,omitempty"`
+	LinkedBy        *string        `db:"linked_by" json:"linked_by,omitempty"`
+	LinkedAt        *time.Time     `db:"linked_at" json:"linked_at,omitempty"`
+	ClosedBy        *string        `db:"closed_by" json:"closed_by,omitempty"`
+	ClosedAt        *time.Time     `db:"closed_at" json:"closed_at,omitempty"`
+	CloseReason     *string        `db:"close_reason" json:"close_reason,omitempty"`
+	CreatedAt       time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// AlertCaseMember is an alert joining a case and the keys it matched it on
+type AlertCaseMember struct {
+	AlertID     string   `json:"alert_id"`
+	MatchedKeys []string `json:"matched_keys"`
+}
+
+// AlertCaseAlert is a member alert of a case
+type AlertCaseAlert struct {
+	AlertID     string         `db:"id" json:"alert_id"`
+	RuleID      *string        `db:"rule_id" json:"rule_id,omitempty"`
+	Title       string         `db:"title" json:"title"`
+	Severity    string         `db:"severity" json:"severity"`
+	Status      string         `db:"status" json:"status"`
+	EntityIDs   pq.StringArray `db:"entity_ids" json:"entity_ids"`
+	MatchedKeys pq.StringArray `db:"matched_keys" json:"matched_keys"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+	AddedAt     time.Time      `db:"added_at" json:"added_at"`
+}
+
+// CorrelationMatch is an earlier alert sharing correlation keys with a new one
+type CorrelationMatch struct {
+	AlertID         string         `db:"alert_id" json:"alert_id"`
+	Severity        string         `db:"severity" json:"severity"`
+	CreatedAt       time.Time      `db:"alert_created_at" json:"created_at"`
+	SharedKeys      pq.StringArray `db:"shared_keys" json:"shared_keys"`
+	CaseID          *string        `db:"case_id" json:"case_id,omitempty"`
+	CaseStatus      *string        `db:"case_status" json:"case_status,omitempty"`
+	CaseAlertCount  *int           `db:"case_alert_count" json:"case_alert_count,omitempty"`
+	CaseLastAlertAt *time.Time     `db:"case_last_alert_at" json:"case_last_alert_at,omitempty"`
+}
@@ -0,0 +1,428 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrBundleNotFound is returned when an investigation bundle does not exist
+	ErrBundleNotFound = errors.New("investigation bundle not found")
+	// ErrBundleNotProposed is returned when a bundle was already accepted, dismissed or superseded
+	ErrBundleNotProposed = errors.New("investigation bundle is no longer proposed")
+	// ErrAlertClusterRunning is returned when a clustering run is already in progress
+	ErrAlertClusterRunning = errors.New("an alert clustering run is already running")
+)
+
+// AlertClusterRepository handles entity contexts, investigation bundles and
+// the links between alerts and the cases that cover them
+type AlertClusterRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewAlertClusterRepository creates a new alert cluster repository
+func NewAlertClusterRepository(db *sqlx.DB, logger *slog.Logger) *AlertClusterRepository {
+	return &AlertClusterRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Entity context operations
+
+// UpsertEntityContexts stores the resolved entity and graph community of raw
+// entity ids. Empty fields keep the value loaded earlier, so entity resolution
+// and graph analytics may each load their half independently.
+func (a *AlertClusterRepository) UpsertEntityContexts(ctx context.Context, contexts []*EntityContext) error {
+	return a.Transaction(func(tx *sqlx.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO alert_entity_contexts (entity_id, resolved_entity_id, community_id, source, updated_at)
+			VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), NOW())
+			ON CONFLICT (entity_id) DO UPDATE SET
+				resolved_entity_id = COALESCE(EXCLUDED.resolved_entity_id, alert_entity_contexts.resolved_entity_id),
+				community_id = COALESCE(EXCLUDED.community_id, alert_entity_contexts.community_id),
+				source = COALESCE(EXCLUDED.source, alert_entity_contexts.source),
+				updated_at = NOW()`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare entity context upsert: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, entity := range contexts {
+			if _, err := stmt.ExecContext(ctx, entity.EntityID, stringValue(entity.ResolvedEntityID),
+				stringValue(entity.CommunityID), stringValue(entity.Source)); err != nil {
+				return fmt.Errorf("failed to upsert entity context for %s: %w", entity.EntityID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListEntityContexts retrieves the stored contexts of the given entity ids
+func (a *AlertClusterRepository) ListEntityContexts(ctx context.Context, entityIDs []string) ([]*EntityContext, error) {
+	query := `
+		SELECT entity_id, resolved_entity_id, community_id, source, updated_at
+		FROM alert_entity_contexts
+		WHERE entity_id = ANY($1)`
+
+	var contexts []*EntityContext
+	if err := a.db.SelectContext(ctx, &contexts, query, pq.Array(entityIDs)); err != nil {
+		return nil, fmt.Errorf("failed to list entity contexts: %w", err)
+	}
+
+	return contexts, nil
+}
+
+// Alert operations
+
+// ListClusterCandidates retrieves open alerts created since the given time
+// that are not yet covered by an investigation case
+func (a *AlertClusterRepository) ListClusterCandidates(ctx context.Context, since time.Time, limit int) ([]*ClusterCandidate, error) {
+	query := `
+		SELECT a.id, a.title, a.severity, a.entity_ids, a.created_at
+		FROM alerts a
+		WHERE a.status IN ('active', 'acknowledged', 'escalated')
+		AND a.deleted_at IS NULL
+		AND a.created_at >= $1
+		AND cardinality(a.entity_ids) > 0
+		AND NOT EXISTS (SELECT 1 FROM alert_case_links l WHERE l.alert_id = a.id)
+		ORDER BY a.created_at DESC
+		LIMIT $2`
+
+	var candidates []*ClusterCandidate
+	if err := a.db.SelectContext(ctx, &candidates, query, since, limit); err != nil {
+		return nil, fmt.Errorf("failed to list cluster candidates: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// Run operations
+
+// CreateRun starts a clustering run, refusing a second concurrent run
+func (a *AlertClusterRepository) CreateRun(ctx context.Context, run *AlertClusterRun) error {
+	query := `
+		INSERT INTO alert_cluster_runs (id, status, triggered_by, started_at)
+		SELECT $1, $2, $3, $4
+		WHERE NOT EXISTS (
+			SELECT 1 FROM alert_cluster_runs
+			WHERE status = 'running'
+			AND started_at > NOW() - INTERVAL '1 hour'
+		)`
+
+	result, err := a.db.ExecContext(ctx, query, run.ID, run.Status, run.TriggeredBy, run.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create alert cluster run: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAlertClusterRunning
+	}
+
+	return nil
+}
+
+// CompleteRun stores the final counts of a run
+func (a *AlertClusterRepository) CompleteRun(ctx context.Context, run *AlertClusterRun) error {
+	query := `
+		UPDATE alert_cluster_runs SET
+			status = :status,
+			alerts_scanned = :alerts_scanned,
+			clusters_found = :clusters_found,
+			bundles_proposed = :bundles_proposed,
+			bundles_superseded = :bundles_superseded,
+			error_message = :error_message,
+			completed_at = :completed_at
+		WHERE id = :id`
+
+	if _, err := a.db.NamedExecContext(ctx, query, run); err != nil {
+		return fmt.Errorf("failed to complete alert cluster run: %w", err)
+	}
+
+	return nil
+}
+
+// ListRuns retrieves recent clustering runs
+func (a *AlertClusterRepository) ListRuns(ctx context.Context, limit int) ([]*AlertClusterRun, error) {
+	var runs []*AlertClusterRun
+	if err := a.db.SelectContext(ctx, &runs,
+		`SELECT * FROM alert_cluster_runs ORDER BY started_at DESC LIMIT $1`, limit); err != nil {
+		return nil, fmt.Errorf("failed to list alert cluster runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// Bundle operations
+
+// ListBundleKeys returns the cluster keys of bundles in the given statuses
+func (a *AlertClusterRepository) ListBundleKeys(ctx context.Context, statuses []string) (map[string]string, error) {
+	rows, err := a.db.QueryContext(ctx,
+		`SELECT cluster_key, id FROM investigation_bundles WHERE status = ANY($1)`,
+		pq.Array(statuses))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bundle keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make(map[string]string)
+	for rows.Next() {
+		var key, id string
+		if err := rows.Scan(&key, &id); err != nil {
+			return nil, fmt.Errorf("failed to scan bundle key: %w", err)
+		}
+		keys[key] = id
+	}
+
+	return keys, rows.Err()
+}
+
+// CreateBundle stores a proposed bundle. A proposal for the same set of alerts
+// that is already open is left untouched.
+func (a *AlertClusterRepository) CreateBundle(ctx context.Context, bundle *InvestigationBundle) (bool, error) {
+	query := `
+		INSERT INTO investigation_bundles (
+			id, run_id, cluster_key, status, title, alert_ids, shared_entity_ids,
+			community_ids, max_severity, created_at, updated_at
+		) VALUES (
+			:id, :run_id, :cluster_key, :status, :title, :alert_ids, :shared_entity_ids,
+			:community_ids, :max_severity, :created_at, :updated_at
+		)
+		ON CONFLICT (cluster_key) WHERE status = 'proposed' DO NOTHING`
+
+	now := time.Now()
+	bundle.CreatedAt = now
+	bundle.UpdatedAt = now
+
+	result, err := a.db.NamedExecContext(ctx, query, bundle)
+	if err != nil {
+		a.logger.Error("Failed to create investigation bundle", "bundle_id", bundle.ID, "error", err)
+		return false, fmt.Errorf("failed to create investigation bundle: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// SupersedeBundles marks open proposals whose cluster keys are not in the
+// current set as superseded, returning how many were replaced
+func (a *AlertClusterRepository) SupersedeBundles(ctx context.Context, currentKeys []string) (int, error) {
+	result, err := a.db.ExecContext(ctx, `
+		UPDATE investigation_bundles
+		SET status = 'superseded', decided_at = NOW(), decided_by = 'system'
+		WHERE status = 'proposed' AND NOT (cluster_key = ANY($1))`,
+		pq.Array(currentKeys))
+	if err != nil {
+		return 0, fmt.Errorf("failed to supersede investigation bundles: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// GetBundle retrieves an investigation bundle by ID
+func (a *AlertClusterRepository) GetBundle(ctx context.Context, id string) (*InvestigationBundle, error) {
+	var bundle InvestigationBundle
+	if err := a.db.GetContext(ctx, &bundle, `SELECT * FROM investigation_bundles WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBundleNotFound
+		}
+		return nil, fmt.Errorf("failed to get investigation bundle: %w", err)
+	}
+
+	return &bundle, nil
+}
+
+// ListBundles retrieves bundles, optionally in one status, with the most severe and largest first
+func (a *AlertClusterRepository) ListBundles(ctx context.Context, status string, limit int) ([]*InvestigationBundle, error) {
+	query := `
+		SELECT * FROM investigation_bundles
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY
+			CASE max_severity WHEN 'critical' THEN 4 WHEN 'high' THEN 3 WHEN 'medium' THEN 2 ELSE 1 END DESC,
+			cardinality(alert_ids) DESC,
+			created_at DESC
+		LIMIT $2`
+
+	var bundles []*InvestigationBundle
+	if err := a.db.SelectContext(ctx, &bundles, query, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to list investigation bundles: %w", err)
+	}
+
+	return bundles, nil
+}
+
+// AcceptBundle marks a proposed bundle accepted and links each of its alerts
+// to the case in one transaction. Alerts already linked to the case are skipped.
+func (a *AlertClusterRepository) AcceptBundle(ctx context.Context, id, caseID, actor string) (*InvestigationBundle, error) {
+	var bundle InvestigationBundle
+
+	err := a.Transaction(func(tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, &bundle, `
+			UPDATE investigation_bundles
+			SET status = 'accepted', case_id = $2, decided_by = $3, decided_at = NOW()
+			WHERE id = $1 AND status = 'proposed'
+			RETURNING *`, id, caseID, actor)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrBundleNotProposed
+			}
+			return fmt.Errorf("failed to accept investigation bundle: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO alert_case_links (alert_id, case_id, bundle_id, linked_by, linked_at)
+			SELECT unnest($1::text[]), $2, $3, $4, NOW()
+			ON CONFLICT (alert_id, case_id) DO NOTHING`,
+			pq.Array(bundle.AlertIDs), caseID, id, actor); err != nil {
+			return fmt.Errorf("failed to link alerts to case: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Investigation bundle accepted",
+		"bundle_id", id,
+		"case_id", caseID,
+		"alerts", len(bundle.AlertIDs),
+		"accepted_by", actor)
+	return &bundle, nil
+}
+
+// DismissBundle marks a proposed bundle dismissed so the same cluster is not proposed again
+func (a *AlertClusterRepository) DismissBundle(ctx context.Context, id, actor, reason string) (*InvestigationBundle, error) {
+	var bundle InvestigationBundle
+	err := a.db.GetContext(ctx, &bundle, `
+		UPDATE investigation_bundles
+		SET status = 'dismissed', decided_by = $2, decided_at = NOW(), decision_reason = NULLIF($3, '')
+		WHERE id = $1 AND status = 'proposed'
+		RETURNING *`, id, actor, reason)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBundleNotProposed
+		}
+		return nil, fmt.Errorf("failed to dismiss investigation bundle: %w", err)
+	}
+
+	a.logger.Info("Investigation bundle dismissed", "bundle_id", id, "dismissed_by", actor)
+	return &bundle, nil
+}
+
+// ListAlertCaseLinks retrieves the cases covering an alert
+func (a *AlertClusterRepository) ListAlertCaseLinks(ctx context.Context, alertID string) ([]*AlertCaseLink, error) {
+	var links []*AlertCaseLink
+	if err := a.db.SelectContext(ctx, &links,
+		`SELECT * FROM alert_case_links WHERE alert_id = $1 ORDER BY linked_at DESC`, alertID); err != nil {
+		return nil, fmt.Errorf("failed to list alert case links: %w", err)
+	}
+
+	return links, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Alert cluster types
+
+// Investigation bundle statuses
+const (
+	BundleStatusProposed   = "proposed"
+	BundleStatusAccepted   = "accepted"
+	BundleStatusDismissed  = "dismissed"
+	BundleStatusSuperseded = "superseded"
+)
+
+// Alert cluster run statuses
+const (
+	ClusterRunStatusRunning   = "running"
+	ClusterRunStatusCompleted = "completed"
+	ClusterRunStatusFailed    = "failed"
+)
+
+// EntityContext is the resolved entity and graph community a raw entity id belongs to
+type EntityContext struct {
+	EntityID         string    `db:"entity_id" json:"entity_id"`
+	ResolvedEntityID *string   `db:"resolved_entity_id" json:"resolved_entity_id,omitempty"`
+	CommunityID      *string   `db:"community_id" json:"community_id,omitempty"`
+	Source           *string   `db:"source" json:"source,omitempty"`
+	UpdatedAt        time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// ClusterCandidate is an open alert considered for clustering
+type ClusterCandidate struct {
+	ID        string         `db:"id" json:"id"`
+	Title     string         `db:"title" json:"title"`
+	Severity  string         `db:"severity" json:"severity"`
+	EntityIDs pq.StringArray `db:"entity_ids" json:"entity_ids"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+}
+
+// AlertClusterRun summarizes one clustering pass
+type AlertClusterRun struct {
+	ID                string     `db:"id" json:"id"`
+	Status            string     `db:"status" json:"status"`
+	TriggeredBy       string     `db:"triggered_by" json:"triggered_by"`
+	AlertsScanned     int        `db:"alerts_scanned" json:"alerts_scanned"`
+	ClustersFound     int        `db:"clusters_found" json:"clusters_found"`
+	BundlesProposed   int        `db:"bundles_proposed" json:"bundles_proposed"`
+	BundlesSuperseded int        `db:"bundles_superseded" json:"bundles_superseded"`
+	ErrorMessage      *string    `db:"error_message" json:"error_message,omitempty"`
+	StartedAt         time.Time  `db:"started_at" json:"started_at"`
+	CompletedAt       *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+// InvestigationBundle is a group of related open alerts proposed for one investigation case
+type InvestigationBundle struct {
+	ID              string         `db:"id" json:"id"`
+	RunID           *string        `db:"run_id" json:"run_id,omitempty"`
+	ClusterKey      string         `db:"cluster_key" json:"cluster_key"`
+	Status          string         `db:"status" json:"status"`
+	Title           string         `db:"title" json:"title"`
+	AlertIDs        pq.StringArray `db:"alert_ids" json:"alert_ids"`
+	SharedEntityIDs pq.StringArray `db:"shared_entity_ids" json:"shared_entity_ids"`
+	CommunityIDs    pq.StringArray `db:"community_ids" json:"community_ids"`
+	MaxSeverity     string         `db:"max_severity" json:"max_severity"`
+	CaseID          *string        `db:"case_id" json:"case_id,omitempty"`
+	DecidedBy       *string        `db:"decided_by" json:"decided_by,omitempty"`
+	DecidedAt       *time.Time     `db:"decided_at" json:"decided_at,omitempty"`
+	DecisionReason  *string        `db:"decision_reason" json:"decision_reason,omitempty"`
+	CreatedAt       time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// AlertCaseLink records the investigation case covering an alert
+type AlertCaseLink struct {
+	AlertID  string    `db:"alert_id" json:"alert_id"`
+	CaseID   string    `db:"case_id" json:"case_id"`
+	BundleID *string   `db:"bundle_id" json:"bundle_id,omitempty"`
+	LinkedBy string    `db:"linked_by" json:"linked_by"`
+	LinkedAt time.Time `db:"linked_at" json:"linked_at"`
+}
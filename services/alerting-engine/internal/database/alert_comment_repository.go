@@ -0,0 +1,186 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Comment types, shared with investigation collaboration comments. Resolution
+// comments are written by the alert repository when an alert is resolved.
+const (
+	CommentTypeGeneral         = "general"
+	CommentTypeQuestion        = "question"
+	CommentTypeFinding         = "finding"
+	CommentTypeRecommendation  = "recommendation"
+	CommentTypeStatusUpdate    = "status_update"
+	CommentTypeEvidenceComment = "evidence_comment"
+	CommentTypeResolution      = "resolution"
+)
+
+var (
+	// ErrCommentNotFound is returned when an alert comment does not exist or was deleted
+	ErrCommentNotFound = errors.New("alert comment not found")
+	// ErrResolutionNoteRequired is returned when an alert is resolved without a note
+	ErrResolutionNoteRequired = errors.New("resolution note is required")
+)
+
+// AlertComment is one entry in an alert's discussion thread
+type AlertComment struct {
+	ID              string         `db:"id" json:"id"`
+	AlertID         string         `db:"alert_id" json:"alert_id"`
+	ParentCommentID *string        `db:"parent_comment_id" json:"parent_comment_id,omitempty"`
+	UserID          string         `db:"user_id" json:"user_id"`
+	Content         string         `db:"content" json:"content"`
+	CommentType     string         `db:"comment_type" json:"comment_type"`
+	MentionedUsers  pq.StringArray `db:"mentioned_users" json:"mentioned_users"`
+	IsInternal      bool           `db:"is_internal" json:"is_internal"`
+	Metadata        JSONB          `db:"metadata" json:"metadata"`
+	CreatedAt       time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time      `db:"updated_at" json:"updated_at"`
+	EditedAt        *time.Time     `db:"edited_at" json:"edited_at,omitempty"`
+	DeletedAt       *time.Time     `db:"deleted_at" json:"-"`
+}
+
+const insertAlertCommentQuery = `
+	INSERT INTO alert_comments (
+		id, alert_id, parent_comment_id, user_id, content, comment_type,
+		mentioned_users, is_internal, metadata, created_at, updated_at
+	) VALUES (
+		:id, :alert_id, :parent_comment_id, :user_id, :content, :comment_type,
+		:mentioned_users, :is_internal, :metadata, :created_at, :updated_at
+	)`
+
+// AlertCommentRepository handles alert discussion threads
+type AlertCommentRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewAlertCommentRepository creates a new alert comment repository
+func NewAlertCommentRepository(db *sqlx.DB, logger *slog.Logger) *AlertCommentRepository {
+	return &AlertCommentRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Create adds a comment to an alert's thread. A reply must belong to the same alert.
+func (c *AlertCommentRepository) Create(ctx context.Context, comment *AlertComment) error {
+	if comment.ParentCommentID != nil {
+		parent, err := c.GetByID(ctx, *comment.ParentCommentID)
+		if err != nil {
+			return err
+		}
+		if parent.AlertID != comment.AlertID {
+			return ErrCommentNotFound
+		}
+	}
+
+	now := time.Now()
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+	if comment.MentionedUsers == nil {
+		comment.MentionedUsers = pq.StringArray{}
+	}
+	if comment.Metadata == nil {
+		comment.Metadata = JSONB{}
+	}
+
+	if _, err := c.db.NamedExecContext(ctx, insertAlertCommentQuery, comment); err != nil {
+		c.logger.Error("Failed to create alert comment", "alert_id", comment.AlertID, "error", err)
+		return fmt.Errorf("failed to create alert comment: %w", err)
+	}
+
+	c.logger.Info("Alert comment created",
+		"alert_id", comment.AlertID,
+		"comment_id", comment.ID,
+		"comment_type", comment.CommentType,
+		"user_id", comment.UserID)
+	return nil
+}
+
+// GetByID retrieves a comment that has not been deleted
+func (c *AlertCommentRepository) GetByID(ctx context.Context, id string) (*AlertComment, error) {
+	query := `SELECT * FROM alert_comments WHERE id = $1 AND deleted_at IS NULL`
+
+	var comment AlertComment
+	if err := c.db.GetContext(ctx, &comment, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("failed to get alert comment: %w", err)
+	}
+
+	return &comment, nil
+}
+
+// ListByAlert returns an alert's comments in the order they were written.
+// When since is set only comments written after it are returned.
+func (c *AlertCommentRepository) ListByAlert(ctx context.Context, alertID string, since *time.Time) ([]*AlertComment, error) {
+	query := `
+		SELECT * FROM alert_comments
+		WHERE alert_id = $1 AND deleted_at IS NULL
+		  AND ($2::timestamptz IS NULL OR created_at > $2)
+		ORDER BY created_at ASC, id ASC`
+
+	var comments []*AlertComment
+	if err := c.db.SelectContext(ctx, &comments, query, alertID, since); err != nil {
+		return nil, fmt.Errorf("failed to list alert comments: %w", err)
+	}
+
+	return comments, nil
+}
+
+// UpdateContent replaces a comment's text and mentions and marks it edited
+func (c *AlertCommentRepository) UpdateContent(ctx context.Context, comment *AlertComment) error {
+	query := `
+		UPDATE alert_comments SET
+			content = $2,
+			mentioned_users = $3,
+			edited_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING edited_at, updated_at`
+
+	if err := c.db.QueryRowxContext(ctx, query, comment.ID, comment.Content, comment.MentionedUsers).
+		Scan(&comment.EditedAt, &comment.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+		return fmt.Errorf("failed to update alert comment: %w", err)
+	}
+
+	return nil
+}
+
+// Delete soft deletes a comment. Replies stay in the thread.
+func (c *AlertCommentRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE alert_comments SET
+			deleted_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := c.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert comment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrCommentNotFound
+	}
+
+	c.logger.Info("Alert comment deleted", "comment_id", id)
+	return nil
+}
@@ -0,0 +1,335 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Alert handoff statuses. A handoff is pending until the receiver accepts
+// it, and superseded when the alert is handed off again first.
+const (
+	AlertHandoffStatusPending    = "pending"
+	AlertHandoffStatusAccepted   = "accepted"
+	AlertHandoffStatusSuperseded = "superseded"
+)
+
+var (
+	// ErrAlertHandoffNotFound is returned when an alert handoff does not exist
+	ErrAlertHandoffNotFound = errors.New("alert handoff not found")
+	// ErrAlertHandoffNotPending is returned when accepting a handoff that was accepted or superseded
+	ErrAlertHandoffNotPending = errors.New("alert handoff is not pending")
+	// ErrHandoffTaskNotFound is returned when completing a task the handoff does not have
+	ErrHandoffTaskNotFound = errors.New("handoff task not found")
+)
+
+// AlertHandoff records the transfer of an alert from one analyst to another,
+// with the context passed along and the SLA time granted to the receiver
+type AlertHandoff struct {
+	ID                  string          `db:"id" json:"id"`
+	AlertID             string          `db:"alert_id" json:"alert_id"`
+	FromUser            string          `db:"from_user" json:"from_user"`
+	ToUser              string          `db:"to_user" json:"to_user"`
+	Actor               string          `db:"actor" json:"actor"`
+	Note                string          `db:"note" json:"note"`
+	PendingTasks        HandoffTaskList `db:"pending_tasks" json:"pending_tasks"`
+	Status              string          `db:"status" json:"status"`
+	SLADueAtBefore      *time.Time      `db:"sla_due_at_before" json:"sla_due_at_before,omitempty"`
+	SLADueAtAfter       *time.Time      `db:"sla_due_at_after" json:"sla_due_at_after,omitempty"`
+	SLAExtensionSeconds int             `db:"sla_extension_seconds" json:"sla_extension_seconds"`
+	NotifyChannel       *string         `db:"notify_channel" json:"notify_channel,omitempty"`
+	NotifiedAt          *time.Time      `db:"notified_at" json:"notified_at,omitempty"`
+	NotifyError         *string         `db:"notify_error" json:"notify_error,omitempty"`
+	AcceptedAt          *time.Time      `db:"accepted_at" json:"accepted_at,omitempty"`
+	SupersededAt        *time.Time      `db:"superseded_at" json:"superseded_at,omitempty"`
+	CreatedAt           time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// HandoffTask is a piece of work left for the receiver of a handoff
+type HandoffTask struct {
+	ID          string     `json:"id"`
+	Description string     `json:"description"`
+	Done        bool       `json:"done"`
+	CompletedBy *string    `json:"completed_by,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// HandoffTaskList implements database/sql/driver.Valuer and sql.Scanner for handoff tasks
+type HandoffTaskList []HandoffTask
+
+func (t HandoffTaskList) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+func (t *HandoffTaskList) Scan(value interface{}) error {
+	if value == nil {
+		*t = nil
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return fmt.Errorf("cannot scan %T into HandoffTaskList", value)
+	}
+}
+
+// AlertHandoffRepository handles alert handoffs
+type AlertHandoffRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewAlertHandoffRepository creates a new alert handoff repository
+func NewAlertHandoffRepository(db *sqlx.DB, logger *slog.Logger) *AlertHandoffRepository {
+	return &AlertHandoffRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Handoff transfers an alert to the handoff's receiver in one transaction:
+// the alert moves to the transition's to status with the receiver assigned
+// and its SLA extended by the handoff's extension, earlier pending handoffs
+// of the alert are superseded, and the handoff, the transition and the
+// handoff note on the alert's thread are recorded. It returns
+// ErrStaleTransition when the alert changed status or owner meanwhile.
+func (r *AlertHandoffRepository) Handoff(ctx context.Context, handoff *AlertHandoff, transition *AlertTransition, note *AlertComment) (*Alert, error) {
+	now := time.Now()
+	handoff.Status = AlertHandoffStatusPending
+	handoff.CreatedAt = now
+	handoff.UpdatedAt = now
+	transition.CreatedAt = now
+
+	var alert Alert
+	err := r.Transaction(func(tx *sqlx.Tx) error {
+		err := tx.QueryRowxContext(ctx, `
+			UPDATE alerts SET
+				status = $3,
+				assigned_to = $4,
+				assigned_by = $5,
+				assigned_at = NOW(),
+				sla_extension_seconds = sla_extension_seconds + $6,
+				updated_at = NOW()
+			WHERE id = $1 AND status = $2 AND assigned_to = $7 AND deleted_at IS NULL
+			RETURNING *`,
+			handoff.AlertID, transition.FromStatus, transition.ToStatus, handoff.ToUser,
+			handoff.Actor, handoff.SLAExtensionSeconds, handoff.FromUser).StructScan(&alert)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrStaleTransition
+		}
+		if err != nil {
+			return fmt.Errorf("failed to reassign alert: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE alert_handoffs SET status = $2, superseded_at = NOW()
+			WHERE alert_id = $1 AND status = $3`,
+			handoff.AlertID, AlertHandoffStatusSuperseded, AlertHandoffStatusPending); err != nil {
+			return fmt.Errorf("failed to supersede pending handoffs: %w", err)
+		}
+
+		if _, err := tx.NamedExecContext(ctx, `
+			INSERT INTO alert_handoffs (
+				id, alert_id, from_user, to_user, actor, note, pending_tasks, status,
+				sla_due_at_before, sla_due_at_after, sla_extension_seconds, notify_channel,
+				created_at, updated_at
+			) VALUES (
+				:id, :alert_id, :from_user, :to_user, :actor, :note, :pending_tasks, :status,
+				:sla_due_at_before, :sla_due_at_after, :sla_extension_seconds, :notify_channel,
+				:created_at, :updated_at
+			)`, handoff); err != nil {
+			return fmt.Errorf("failed to record alert handoff: %w", err)
+		}
+
+		if _, err := tx.NamedExecContext(ctx, `
+			INSERT INTO alert_state_transitions (
+				id, alert_id, from_status, to_status, actor, assigned_to, reason, created_at
+			) VALUES (
+				:id, :alert_id, :from_status, :to_status, :actor, :assigned_to, :reason, :created_at
+			)`, transition); err != nil {
+			return fmt.Errorf("failed to record alert transition: %w", err)
+		}
+
+		if note != nil {
+			if _, err := tx.NamedExecContext(ctx, insertAlertCommentQuery, note); err != nil {
+				return fmt.Errorf("failed to record handoff note: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrStaleTransition) {
+			r.logger.Error("Failed to hand off alert",
+				"alert_id", handoff.AlertID,
+				"from_user", handoff.FromUser,
+				"to_user", handoff.ToUser,
+				"error", err)
+		}
+		return nil, err
+	}
+
+	r.logger.Info("Alert handed off",
+		"alert_id", handoff.AlertID,
+		"handoff_id", handoff.ID,
+		"from_user", handoff.FromUser,
+		"to_user", handoff.ToUser,
+		"sla_extension_seconds", handoff.SLAExtensionSeconds)
+	return &alert, nil
+}
+
+// RecordNotification stores the outcome of notifying a handoff's receiver
+func (r *AlertHandoffRepository) RecordNotification(ctx context.Context, id string, notifyErr error) error {
+	var errMessage *string
+	if notifyErr != nil {
+		message := notifyErr.Error()
+		if len(message) > 1000 {
+			message = message[:1000]
+		}
+		errMessage = &message
+	}
+
+	query := `
+		UPDATE alert_handoffs SET
+			notified_at = CASE WHEN $2::text IS NULL THEN NOW() ELSE notified_at END,
+			notify_error = $2
+		WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, errMessage); err != nil {
+		r.logger.Error("Failed to record handoff notification", "handoff_id", id, "error", err)
+		return fmt.Errorf("failed to record handoff notification: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an alert handoff by ID
+func (r *AlertHandoffRepository) Get(ctx context.Context, id string) (*AlertHandoff, error) {
+	var handoff AlertHandoff
+	err := r.db.GetContext(ctx, &handoff, `SELECT * FROM alert_handoffs WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAlertHandoffNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to get alert handoff", "handoff_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get alert handoff: %w", err)
+	}
+	return &handoff, nil
+}
+
+// ListByAlert retrieves an alert's handoffs, oldest first
+func (r *AlertHandoffRepository) ListByAlert(ctx context.Context, alertID string) ([]*AlertHandoff, error) {
+	query := `
+		SELECT * FROM alert_handoffs
+		WHERE alert_id = $1
+		ORDER BY created_at ASC`
+
+	var handoffs []*AlertHandoff
+	if err := r.db.SelectContext(ctx, &handoffs, query, alertID); err != nil {
+		r.logger.Error("Failed to list alert handoffs", "alert_id", alertID, "error", err)
+		return nil, fmt.Errorf("failed to list alert handoffs: %w", err)
+	}
+	return handoffs, nil
+}
+
+// ListForReceiver retrieves the handoffs made to a user, newest first,
+// optionally in one status
+func (r *AlertHandoffRepository) ListForReceiver(ctx context.Context, toUser, status string, limit int) ([]*AlertHandoff, error) {
+	query := `
+		SELECT * FROM alert_handoffs
+		WHERE to_user = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3`
+
+	var handoffs []*AlertHandoff
+	if err := r.db.SelectContext(ctx, &handoffs, query, toUser, status, limit); err != nil {
+		r.logger.Error("Failed to list handoffs for receiver", "to_user", toUser, "error", err)
+		return nil, fmt.Errorf("failed to list handoffs for receiver: %w", err)
+	}
+	return handoffs, nil
+}
+
+// Accept marks a pending handoff accepted by its receiver
+func (r *AlertHandoffRepository) Accept(ctx context.Context, id string) (*AlertHandoff, error) {
+	query := `
+		UPDATE alert_handoffs SET status = $2, accepted_at = NOW()
+		WHERE id = $1 AND status = $3
+		RETURNING *`
+
+	var handoff AlertHandoff
+	err := r.db.QueryRowxContext(ctx, query, id, AlertHandoffStatusAccepted, AlertHandoffStatusPending).StructScan(&handoff)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, getErr := r.Get(ctx, id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, ErrAlertHandoffNotPending
+	}
+	if err != nil {
+		r.logger.Error("Failed to accept alert handoff", "handoff_id", id, "error", err)
+		return nil, fmt.Errorf("failed to accept alert handoff: %w", err)
+	}
+
+	r.logger.Info("Alert handoff accepted", "handoff_id", id, "alert_id", handoff.AlertID, "to_user", handoff.ToUser)
+	return &handoff, nil
+}
+
+// CompleteTask marks one of a handoff's pending tasks done. Tasks of a
+// superseded handoff can no longer be completed.
+func (r *AlertHandoffRepository) CompleteTask(ctx context.Context, id, taskID, actor string) (*AlertHandoff, error) {
+	var handoff AlertHandoff
+	err := r.Transaction(func(tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, &handoff, `SELECT * FROM alert_handoffs WHERE id = $1 FOR UPDATE`, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAlertHandoffNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get alert handoff: %w", err)
+		}
+		if handoff.Status == AlertHandoffStatusSuperseded {
+			return ErrAlertHandoffNotPending
+		}
+
+		found := false
+		for i := range handoff.PendingTasks {
+			task := &handoff.PendingTasks[i]
+			if task.ID != taskID {
+				continue
+			}
+			found = true
+			if !task.Done {
+				now := time.Now()
+				task.Done = true
+				task.CompletedBy = &actor
+				task.CompletedAt = &now
+			}
+		}
+		if !found {
+			return ErrHandoffTaskNotFound
+		}
+
+		return tx.QueryRowxContext(ctx, `
+			UPDATE alert_handoffs SET pending_tasks = $2
+			WHERE id = $1
+			RETURNING *`, id, handoff.PendingTasks).StructScan(&handoff)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrAlertHandoffNotFound) && !errors.Is(err, ErrAlertHandoffNotPending) && !errors.Is(err, ErrHandoffTaskNotFound) {
+			r.logger.Error("Failed to complete handoff task", "handoff_id", id, "task_id", taskID, "error", err)
+		}
+		return nil, err
+	}
+	return &handoff, nil
+}
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Alert statuses. Active and open both mark a newly created alert;
+// acknowledged, resolved and suppressed predate the lifecycle states.
+// Closed auto is entered only by an auto-close policy, never by an analyst.
+const (
+	AlertStatusActive              = "active"
+	AlertStatusOpen                = "open"
+	AlertStatusAcknowledged        = "acknowledged"
+	AlertStatusTriaged             = "triaged"
+	AlertStatusAssigned            = "assigned"
+	AlertStatusEscalated           = "escalated"
+	AlertStatusResolved            = "resolved"
+	AlertStatusSuppressed          = "suppressed"
+	AlertStatusClosedFalsePositive = "closed_false_positive"
+	AlertStatusClosedConfirmed     = "closed_confirmed"
+	AlertStatusClosedAuto          = "closed_auto"
+)
+
+// ErrStaleTransition is returned when an alert's status changed between
+// validating a transition and applying it
+var ErrStaleTransition = errors.New("alert status changed concurrently")
+
+// AlertTransition records one move of an alert between lifecycle states
+type AlertTransition struct {
+	ID         string    `db:"id" json:"id"`
+	AlertID    string    `db:"alert_id" json:"alert_id"`
+	FromStatus string    `db:"from_status" json:"from_status"`
+	ToStatus   string    `db:"to_status" json:"to_status"`
+	Actor      string    `db:"actor" json:"actor"`
+	AssignedTo *string   `db:"assigned_to" json:"assigned_to,omitempty"`
+	Reason     *string   `db:"reason" json:"reason,omitempty"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// OverdueAlert is an open alert past its SLA deadline
+type OverdueAlert struct {
+	Alert
+	SLADueAt time.Time `db:"sla_due_at" json:"sla_due_at"`
+}
+
+// OverdueFilter selects overdue alerts. Targets holds the SLA target by
+// severity; alerts of severities without a target are never overdue.
+type OverdueFilter struct {
+	Targets    map[string]time.Duration
+	Statuses   []string
+	Severity   string
+	AssignedTo string
+	Limit      int
+}
+
+// AlertLifecycleRepository handles alert status transitions and their history
+type AlertLifecycleRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewAlertLifecycleRepository creates a new alert lifecycle repository
+func NewAlertLifecycleRepository(db *sqlx.DB, logger *slog.Logger) *AlertLifecycleRepository {
+	return &AlertLifecycleRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Transition moves an alert from the transition's from status to its to
+// status, stamping the columns of the state entered, and records the
+// transition and an optional thread note in the same transaction. It returns
+// ErrStaleTransition when the alert is no longer in the from status.
+func (r *AlertLifecycleRepository) Transition(ctx context.Context, transition *AlertTransition, note *AlertComment) (*Alert, error) {
+	var alert *Alert
+	err := r.Transaction(func(tx *sqlx.Tx) error {
+		var err error
+		alert, err = transitionAlert(ctx, tx, transition, note)
+		return err
+	})
+	if err != nil {
+		if !errors.Is(err, ErrStaleTransition) {
+			r.logger.Error("Failed to transition alert",
+				"alert_id", transition.AlertID,
+				"from_status", transition.FromStatus,
+				"to_status", transition.ToStatus,
+				"error", err)
+		}
+		return nil, err
+	}
+
+	r.logger.Info("Alert transitioned",
+		"alert_id", transition.AlertID,
+		"from_status", transition.FromStatus,
+		"to_status", transition.ToStatus,
+		"actor", transition.Actor)
+	return alert, nil
+}
+
+// transitionAlert applies a transition within the caller's transaction
+func transitionAlert(ctx context.Context, tx *sqlx.Tx, transition *AlertTransition, note *AlertComment) (*Alert, error) {
+	sets := []string{"status = $3", "updated_at = NOW()"}
+	args := []interface{}{transition.AlertID, transition.FromStatus, transition.ToStatus}
+	switch transition.ToStatus {
+	case AlertStatusTriaged:
+		args = append(args, transition.Actor)
+		sets = append(sets, "triaged_at = NOW()", fmt.Sprintf("triaged_by = $%d", len(args)))
+	case AlertStatusAssigned:
+		args = append(args, transition.Actor, transition.AssignedTo)
+		sets = append(sets, "assigned_at = NOW()",
+			fmt.Sprintf("assigned_by = $%d", len(args)-1),
+			fmt.Sprintf("assigned_to = $%d", len(args)))
+	case AlertStatusEscalated:
+		sets = append(sets, "escalated_at = NOW()", "escalation_level = escalation_level + 1")
+	case AlertStatusClosedFalsePositive, AlertStatusClosedConfirmed, AlertStatusClosedAuto:
+		args = append(args, transition.Actor, transition.Reason)
+		sets = append(sets, "closed_at = NOW()",
+			fmt.Sprintf("closed_by = $%d", len(args)-1),
+			fmt.Sprintf("resolution_reason = $%d", len(args)))
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE alerts SET %s
+		WHERE id = $1 AND status = $2 AND deleted_at IS NULL
+		RETURNING *`, strings.Join(sets, ", "))
+
+	transition.CreatedAt = time.Now()
+
+	var alert Alert
+	err := tx.QueryRowxContext(ctx, query, args...).StructScan(&alert)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrStaleTransition
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update alert status: %w", err)
+	}
+
+	if _, err := tx.NamedExecContext(ctx, `
+		INSERT INTO alert_state_transitions (
+			id, alert_id, from_status, to_status, actor, assigned_to, reason, created_at
+		) VALUES (
+			:id, :alert_id, :from_status, :to_status, :actor, :assigned_to, :reason, :created_at
+		)`, transition); err != nil {
+		return nil, fmt.Errorf("failed to record alert transition: %w", err)
+	}
+
+	if note != nil {
+		if _, err := tx.NamedExecContext(ctx, insertAlertCommentQuery, note); err != nil {
+			return nil, fmt.Errorf("failed to record transition note: %w", err)
+		}
+	}
+	return &alert, nil
+}
+
+// ListTransitions retrieves an alert's transition history, oldest first
+func (r *AlertLifecycleRepository) ListTransitions(ctx context.Context, alertID string) ([]*AlertTransition, error) {
+	query := `
+		SELECT * FROM alert_state_transitions
+		WHERE alert_id = $1
+		ORDER BY created_at ASC`
+
+	var transitions []*AlertTransition
+	if err := r.db.SelectContext(ctx, &transitions, query, alertID); err != nil {
+		r.logger.Error("Failed to list alert transitions", "alert_id", alertID, "error", err)
+		return nil, fmt.Errorf("failed to list alert transitions: %w", err)
+	}
+
+	return transitions, nil
+}
+
+// ListOverdue retrieves alerts in the filter's statuses whose SLA deadline,
+// creation time plus their severity's target and any handoff extension, has
+// passed, most overdue first
+func (r *AlertLifecycleRepository) ListOverdue(ctx context.Context, filter OverdueFilter) ([]*OverdueAlert, error) {
+	severities := make([]string, 0, len(filter.Targets))
+	targetSeconds := make([]int64, 0, len(filter.Targets))
+	for severity, target := range filter.Targets {
+		severities = append(severities, severity)
+		targetSeconds = append(targetSeconds, int64(target/time.Second))
+	}
+
+	conditions := []string{
+		"a.status = ANY($3)",
+		"a.deleted_at IS NULL",
+		"a.created_at + make_interval(secs => sla.target_seconds + a.sla_extension_seconds) < NOW()",
+	}
+	args := []interface{}{pq.StringArray(severities), pq.Int64Array(targetSeconds), pq.StringArray(filter.Statuses)}
+	if filter.Severity != "" {
+		args = append(args, filter.Severity)
+		conditions = append(conditions, fmt.Sprintf("a.severity = $%d", len(args)))
+	}
+	if filter.AssignedTo != "" {
+		args = append(args, filter.AssignedTo)
+		conditions = append(conditions, fmt.Sprintf("a.assigned_to = $%d", len(args)))
+	}
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`
+		SELECT a.*, a.created_at + make_interval(secs => sla.target_seconds + a.sla_extension_seconds) AS sla_due_at
+		FROM alerts a
+		JOIN unnest($1::text[], $2::bigint[]) AS sla(severity, target_seconds)
+			ON sla.severity = a.severity
+		WHERE %s
+		ORDER BY sla_due_at ASC
+		LIMIT $%d`, strings.Join(conditions, " AND "), len(args))
+
+	var alerts []*OverdueAlert
+	if err := r.db.SelectContext(ctx, &alerts, query, args...); err != nil {
+		r.logger.Error("Failed to list overdue alerts", "error", err)
+		return nil, fmt.Errorf("failed to list overdue alerts: %w", err)
+	}
+
+	return alerts, nil
+}
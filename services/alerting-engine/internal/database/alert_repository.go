@@ -184,7 +184,7 @@ func (r *AlertRepository) ListExpired(ctx context.Context, limit int) ([]*Alert,
 	query := `
 		SELECT * FROM alerts 
 		WHERE expires_at < NOW() 
-		AND status NOT IN ('resolved', 'expired')
+		AND status NOT IN ('resolved', 'expired', 'closed_false_positive', 'closed_confirmed', 'closed_auto')
 		AND deleted_at IS NULL
 		ORDER BY expires_at ASC
 		LIMIT $1`
@@ -244,6 +244,65 @@ func (r *AlertRepository) ListByFingerprint(ctx context.Context, fingerprint str
 	return alerts, nil
 }
 
+// ListResolvedBetween retrieves a random sample of the resolved alerts
+// created within a time range, optionally restricted to a set of severities
+func (r *AlertRepository) ListResolvedBetween(ctx context.Context, from, to time.Time, severities []string, limit int) ([]*Alert, error) {
+	query := `
+		SELECT * FROM alerts
+		WHERE status = 'resolved'
+		AND resolution_reason IS NOT NULL
+		AND created_at BETWEEN $1 AND $2
+		AND (COALESCE(cardinality($3::text[]), 0) = 0 OR severity = ANY($3))
+		AND deleted_at IS NULL
+		ORDER BY random()
+		LIMIT $4`
+
+	var alerts []*Alert
+	err := r.db.SelectContext(ctx, &alerts, query, from, to, pq.Array(severities), limit)
+	if err != nil {
+		r.logger.Error("Failed to list resolved alerts", "error", err)
+		return nil, fmt.Errorf("failed to list resolved alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// AlertStreamFilter restricts the alerts a stream subscriber receives. Empty
+// lists match every alert.
+type AlertStreamFilter struct {
+	Severities []string
+	RuleIDs    []string
+	EntityIDs  []string
+}
+
+// ListAfter retrieves alerts created after a position, oldest first. The
+// position is a creation time and alert ID so alerts sharing a timestamp are
+// neither repeated nor skipped; only alerts created at or before until are
+// returned. Nil filter lists reach Postgres as NULL arrays, whose
+// cardinality is NULL rather than 0.
+func (r *AlertRepository) ListAfter(ctx context.Context, afterTime time.Time, afterID string, until time.Time, filter AlertStreamFilter, limit int) ([]*Alert, error) {
+	query := `
+		SELECT * FROM alerts
+		WHERE (created_at, id) > ($1, $2)
+		AND created_at <= $3
+		AND (COALESCE(cardinality($4::text[]), 0) = 0 OR severity = ANY($4))
+		AND (COALESCE(cardinality($5::text[]), 0) = 0 OR rule_id = ANY($5))
+		AND (COALESCE(cardinality($6::text[]), 0) = 0 OR entity_ids && $6)
+		AND deleted_at IS NULL
+		ORDER BY created_at ASC, id ASC
+		LIMIT $7`
+
+	var alerts []*Alert
+	err := r.db.SelectContext(ctx, &alerts, query, afterTime, afterID, until,
+		pq.Array(filter.Severities), pq.Array(filter.RuleIDs), pq.Array(filter.EntityIDs), limit)
+	if err != nil {
+		r.logger.Error("Failed to list alerts after cursor", "error", err)
+		return nil, fmt.Errorf("failed to list alerts after cursor: %w", err)
+	}
+
+	return alerts, nil
+}
+
 // GetStats retrieves alert statistics
 func (r *AlertRepository) GetStats(ctx context.Context, timeRange time.Duration) (*AlertStats, error) {
 	query := `
@@ -301,8 +360,13 @@ func (r *AlertRepository) Acknowledge(ctx context.Context, alertID, acknowledged
 	return nil
 }
 
-// Resolve resolves an alert
+// Resolve resolves an alert. The resolution note is required and is also
+// written to the alert's thread, in the same transaction.
 func (r *AlertRepository) Resolve(ctx context.Context, alertID, resolvedBy, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return ErrResolutionNoteRequired
+	}
+
 	query := `
 		UPDATE alerts SET
 			status = 'resolved',
@@ -312,19 +376,41 @@ func (r *AlertRepository) Resolve(ctx context.Context, alertID, resolvedBy, reas
 			updated_at = NOW()
 		WHERE id = $1 AND status IN ('open', 'acknowledged') AND deleted_at IS NULL`
 
-	result, err := r.db.ExecContext(ctx, query, alertID, resolvedBy, reason)
+	now := time.Now()
+	note := &AlertComment{
+		ID:             fmt.Sprintf("comment_%d_%d", now.Unix(), now.Nanosecond()),
+		AlertID:        alertID,
+		UserID:         resolvedBy,
+		Content:        reason,
+		CommentType:    CommentTypeResolution,
+		MentionedUsers: pq.StringArray{},
+		Metadata:       JSONB{},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	err := r.Transaction(func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, query, alertID, resolvedBy, reason)
+		if err != nil {
+			return fmt.Errorf("failed to resolve alert: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("alert not found or already resolved: %s", alertID)
+		}
+
+		if _, err := tx.NamedExecContext(ctx, insertAlertCommentQuery, note); err != nil {
+			return fmt.Errorf("failed to record resolution note: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
 		r.logger.Error("Failed to resolve alert", "alert_id", alertID, "error", err)
-		return fmt.Errorf("failed to resolve alert: %w", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
-	if rowsAffected == 0 {
-		return fmt.Errorf("alert not found or already resolved: %s", alertID)
+		return err
 	}
 
 	r.logger.Info("Alert resolved", "alert_id", alertID, "resolved_by", resolvedBy, "reason", reason)
@@ -0,0 +1,470 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrStormNotFound is returned when an alert storm does not exist
+	ErrStormNotFound = errors.New("alert storm not found")
+	// ErrStormActive is returned when a rule already has an active storm
+	ErrStormActive = errors.New("rule already has an active alert storm")
+	// ErrStormNotEnded is returned when reconciling a storm that is still active or already reconciled
+	ErrStormNotEnded = errors.New("alert storm has not ended or is already reconciled")
+)
+
+// AlertStormRepository handles alert storms, their aggregated storm alerts
+// and the individual alerts held back while a storm was active
+type AlertStormRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewAlertStormRepository creates a new alert storm repository
+func NewAlertStormRepository(db *sqlx.DB, logger *slog.Logger) *AlertStormRepository {
+	return &AlertStormRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Storm operations
+
+// CreateStorm opens a storm for a rule together with the aggregated alert
+// that stands in for the flood. Returns ErrStormActive when another storm
+// for the rule was opened first.
+func (a *AlertStormRepository) CreateStorm(ctx context.Context, storm *AlertStorm, stormAlert *Alert) error {
+	now := time.Now()
+	storm.CreatedAt = now
+	storm.UpdatedAt = now
+	stormAlert.CreatedAt = now
+	stormAlert.UpdatedAt = now
+
+	return a.Transaction(func(tx *sqlx.Tx) error {
+		result, err := tx.NamedExecContext(ctx, `
+			INSERT INTO alert_storms (
+				id, rule_id, rule_name, status, storm_alert_id, baseline_rate, threshold,
+				peak_rate, started_at, last_alert_at, created_at, updated_at
+			) VALUES (
+				:id, :rule_id, :rule_name, :status, :storm_alert_id, :baseline_rate, :threshold,
+				:peak_rate, :started_at, :last_alert_at, :created_at, :updated_at
+			)
+			ON CONFLICT (rule_id) WHERE status = 'active' DO NOTHING`, storm)
+		if err != nil {
+			return fmt.Errorf("failed to create alert storm: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return ErrStormActive
+		}
+
+		if _, err := tx.NamedExecContext(ctx, `
+			INSERT INTO alerts (
+				id, rule_id, rule_name, type, severity, priority, status,
+				title, description, source, source_event, entity_ids, tags,
+				metadata, fingerprint, correlation_id, parent_alert_id,
+				escalation_level, assigned_to, expires_at, notification_sent,
+				created_at, updated_at
+			) VALUES (
+				:id, :rule_id, :rule_name, :type, :severity, :priority, :status,
+				:title, :description, :source, :source_event, :entity_ids, :tags,
+				:metadata, :fingerprint, :correlation_id, :parent_alert_id,
+				:escalation_level, :assigned_to, :expires_at, :notification_sent,
+				:created_at, :updated_at
+			)`, stormAlert); err != nil {
+			return fmt.Errorf("failed to create storm alert: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetStorm retrieves an alert storm by ID
+func (a *AlertStormRepository) GetStorm(ctx context.Context, id string) (*AlertStorm, error) {
+	var storm AlertStorm
+	if err := a.db.GetContext(ctx, &storm, `SELECT * FROM alert_storms WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrStormNotFound
+		}
+		return nil, fmt.Errorf("failed to get alert storm: %w", err)
+	}
+
+	return &storm, nil
+}
+
+// GetActiveStorm retrieves the active storm of a rule, or nil if there is none
+func (a *AlertStormRepository) GetActiveStorm(ctx context.Context, ruleID string) (*AlertStorm, error) {
+	var storm AlertStorm
+	err := a.db.GetContext(ctx, &storm,
+		`SELECT * FROM alert_storms WHERE rule_id = $1 AND status = 'active'`, ruleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active alert storm: %w", err)
+	}
+
+	return &storm, nil
+}
+
+// ListStorms retrieves storms, optionally in one status, newest first
+func (a *AlertStormRepository) ListStorms(ctx context.Context, status string, limit int) ([]*AlertStorm, error) {
+	var storms []*AlertStorm
+	if err := a.db.SelectContext(ctx, &storms, `
+		SELECT * FROM alert_storms
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY started_at DESC
+		LIMIT $2`, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to list alert storms: %w", err)
+	}
+
+	return storms, nil
+}
+
+// ListQuietStorms retrieves active storms that have seen no alert since the given time
+func (a *AlertStormRepository) ListQuietStorms(ctx context.Context, since time.Time) ([]*AlertStorm, error) {
+	var storms []*AlertStorm
+	if err := a.db.SelectContext(ctx, &storms, `
+		SELECT * FROM alert_storms
+		WHERE status = 'active' AND last_alert_at < $1
+		ORDER BY started_at`, since); err != nil {
+		return nil, fmt.Errorf("failed to list quiet alert storms: %w", err)
+	}
+
+	return storms, nil
+}
+
+// ListEndedStorms retrieves storms that ended before the given time and still await reconciliation
+func (a *AlertStormRepository) ListEndedStorms(ctx context.Context, before time.Time, limit int) ([]*AlertStorm, error) {
+	var storms []*AlertStorm
+	if err := a.db.SelectContext(ctx, &storms, `
+		SELECT * FROM alert_storms
+		WHERE status = 'ended' AND ended_at < $1
+		ORDER BY ended_at
+		LIMIT $2`, before, limit); err != nil {
+		return nil, fmt.Errorf("failed to list ended alert storms: %w", err)
+	}
+
+	return storms, nil
+}
+
+// RecordAdmitted counts an alert that was let through individually during a storm
+func (a *AlertStormRepository) RecordAdmitted(ctx context.Context, stormID string, windowCount int) error {
+	if _, err := a.db.ExecContext(ctx, `
+		UPDATE alert_storms SET
+			alert_count = alert_count + 1,
+			admitted_count = admitted_count + 1,
+			peak_rate = GREATEST(peak_rate, $2),
+			last_alert_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1`, stormID, windowCount); err != nil {
+		return fmt.Errorf("failed to record admitted storm alert: %w", err)
+	}
+
+	return nil
+}
+
+// HoldAlert stores an alert held back by a storm and counts it against the storm
+func (a *AlertStormRepository) HoldAlert(ctx context.Context, held *HeldAlert, windowCount int) error {
+	held.HeldAt = time.Now()
+
+	return a.Transaction(func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, `
+			INSERT INTO storm_held_alerts (
+				id, storm_id, rule_id, severity, title, triage_score,
+				alert_data, evidence_data, status, held_at
+			) VALUES (
+				:id, :storm_id, :rule_id, :severity, :title, :triage_score,
+				:alert_data, :evidence_data, :status, :held_at
+			)`, held); err != nil {
+			return fmt.Errorf("failed to hold storm alert: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE alert_storms SET
+				alert_count = alert_count + 1,
+				held_count = held_count + 1,
+				peak_rate = GREATEST(peak_rate, $2),
+				last_alert_at = NOW(),
+				updated_at = NOW()
+			WHERE id = $1`, held.StormID, windowCount); err != nil {
+			return fmt.Errorf("failed to count held storm alert: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// EndStorm marks an active storm ended and rewrites its storm alert's
+// description with the final summary
+func (a *AlertStormRepository) EndStorm(ctx context.Context, id string, summary func(*AlertStorm) string) (*AlertStorm, error) {
+	var storm AlertStorm
+
+	err := a.Transaction(func(tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, &storm, `
+			UPDATE alert_storms
+			SET status = 'ended', ended_at = NOW()
+			WHERE id = $1 AND status = 'active'
+			RETURNING *`, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrStormNotFound
+			}
+			return fmt.Errorf("failed to end alert storm: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE alerts SET description = $2, updated_at = NOW() WHERE id = $1`,
+			storm.StormAlertID, summary(&storm)); err != nil {
+			return fmt.Errorf("failed to update storm alert: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Alert storm ended",
+		"storm_id", id,
+		"rule_id", storm.RuleID,
+		"alerts", storm.AlertCount,
+		"held", storm.HeldCount)
+	return &storm, nil
+}
+
+// Reconciliation operations
+
+// ClaimReconciliation moves an ended storm into reconciliation so only one
+// reconciliation runs at a time
+func (a *AlertStormRepository) ClaimReconciliation(ctx context.Context, id, actor, action string) (*AlertStorm, error) {
+	var storm AlertStorm
+	err := a.db.GetContext(ctx, &storm, `
+		UPDATE alert_storms
+		SET status = 'reconciling', reconciled_by = $2, reconcile_action = $3
+		WHERE id = $1 AND status = 'ended'
+		RETURNING *`, id, actor, action)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrStormNotEnded
+		}
+		return nil, fmt.Errorf("failed to claim alert storm reconciliation: %w", err)
+	}
+
+	return &storm, nil
+}
+
+// ReleaseReconciliation returns a storm whose reconciliation failed to ended
+func (a *AlertStormRepository) ReleaseReconciliation(ctx context.Context, id string) error {
+	if _, err := a.db.ExecContext(ctx, `
+		UPDATE alert_storms
+		SET status = 'ended', reconciled_by = NULL, reconcile_action = NULL
+		WHERE id = $1 AND status = 'reconciling'`, id); err != nil {
+		return fmt.Errorf("failed to release alert storm reconciliation: %w", err)
+	}
+
+	return nil
+}
+
+// ListHeldAlerts retrieves a storm's held alerts, optionally in one status and
+// limited to the given severities, most likely to matter first
+func (a *AlertStormRepository) ListHeldAlerts(ctx context.Context, stormID, status string, severities []string, limit int) ([]*HeldAlert, error) {
+	var held []*HeldAlert
+	if err := a.db.SelectContext(ctx, &held, `
+		SELECT * FROM storm_held_alerts
+		WHERE storm_id = $1
+		AND ($2 = '' OR status = $2)
+		AND (COALESCE(cardinality($3::text[]), 0) = 0 OR severity = ANY($3))
+		ORDER BY triage_score DESC, held_at
+		LIMIT $4`, stormID, status, pq.Array(severities), limit); err != nil {
+		return nil, fmt.Errorf("failed to list held storm alerts: %w", err)
+	}
+
+	return held, nil
+}
+
+// ExpandHeldAlert creates a held alert as an individual alert with its
+// evidence and marks it expanded, in one transaction
+func (a *AlertStormRepository) ExpandHeldAlert(ctx context.Context, held *HeldAlert, alert *Alert, bundle *EvidenceBundle) error {
+	now := time.Now()
+	alert.CreatedAt = now
+	alert.UpdatedAt = now
+	if bundle != nil {
+		bundle.AlertID = alert.ID
+		bundle.CreatedAt = now
+	}
+
+	return a.Transaction(func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE storm_held_alerts SET status = 'expanded', resolved_at = NOW()
+			WHERE id = $1 AND status = 'held'`, held.ID)
+		if err != nil {
+			return fmt.Errorf("failed to mark held alert expanded: %w", err)
+		}
+		if rowsAffected, err := result.RowsAffected(); err != nil || rowsAffected == 0 {
+			// Already expanded or dismissed by an earlier pass
+			return err
+		}
+
+		if _, err := tx.NamedExecContext(ctx, `
+			INSERT INTO alerts (
+				id, rule_id, rule_name, type, severity, priority, status,
+				title, description, source, source_event, entity_ids, tags,
+				metadata, fingerprint, correlation_id, parent_alert_id,
+				escalation_level, assigned_to, expires_at, notification_sent,
+				created_at, updated_at
+			) VALUES (
+				:id, :rule_id, :rule_name, :type, :severity, :priority, :status,
+				:title, :description, :source, :source_event, :entity_ids, :tags,
+				:metadata, :fingerprint, :correlation_id, :parent_alert_id,
+				:escalation_level, :assigned_to, :expires_at, :notification_sent,
+				:created_at, :updated_at
+			)`, alert); err != nil {
+			return fmt.Errorf("failed to create expanded alert: %w", err)
+		}
+
+		if bundle != nil {
+			if _, err := tx.NamedExecContext(ctx, insertEvidenceBundleQuery, bundle); err != nil {
+				return fmt.Errorf("failed to create expanded alert evidence: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// DismissHeldAlerts dismisses every alert of a storm that is still held
+func (a *AlertStormRepository) DismissHeldAlerts(ctx context.Context, stormID string) (int, error) {
+	result, err := a.db.ExecContext(ctx, `
+		UPDATE storm_held_alerts SET status = 'dismissed', resolved_at = NOW()
+		WHERE storm_id = $1 AND status = 'held'`, stormID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to dismiss held storm alerts: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// CompleteReconciliation counts how a storm's held alerts were resolved and
+// resolves the storm alert with the given reason, in one transaction
+func (a *AlertStormRepository) CompleteReconciliation(ctx context.Context, id string, reason func(*AlertStorm) string) (*AlertStorm, error) {
+	var storm AlertStorm
+
+	err := a.Transaction(func(tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, &storm, `
+			UPDATE alert_storms SET
+				status = 'reconciled',
+				expanded_count = (SELECT COUNT(*) FROM storm_held_alerts WHERE storm_id = $1 AND status = 'expanded'),
+				dismissed_count = (SELECT COUNT(*) FROM storm_held_alerts WHERE storm_id = $1 AND status = 'dismissed'),
+				reconciled_at = NOW()
+			WHERE id = $1 AND status = 'reconciling'
+			RETURNING *`, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrStormNotEnded
+			}
+			return fmt.Errorf("failed to complete alert storm reconciliation: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE alerts SET
+				status = 'resolved',
+				resolved_at = NOW(),
+				resolved_by = $2,
+				resolution_reason = $3,
+				updated_at = NOW()
+			WHERE id = $1 AND status <> 'resolved'`,
+			storm.StormAlertID, stringValue(storm.ReconciledBy), reason(&storm)); err != nil {
+			return fmt.Errorf("failed to resolve storm alert: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Alert storm reconciled",
+		"storm_id", id,
+		"expanded", storm.ExpandedCount,
+		"dismissed", storm.DismissedCount,
+		"reconciled_by", stringValue(storm.ReconciledBy))
+	return &storm, nil
+}
+
+// Alert storm types
+
+// Alert storm statuses
+const (
+	StormStatusActive      = "active"
+	StormStatusEnded       = "ended"
+	StormStatusReconciling = "reconciling"
+	StormStatusReconciled  = "reconciled"
+)
+
+// Held alert statuses
+const (
+	HeldAlertStatusHeld      = "held"
+	HeldAlertStatusExpanded  = "expanded"
+	HeldAlertStatusDismissed = "dismissed"
+)
+
+// AlertStorm is a burst of alerts from one rule far above its normal rate,
+// summarized by a single storm alert while it lasts
+type AlertStorm struct {
+	ID              string     `db:"id" json:"id"`
+	RuleID          string     `db:"rule_id" json:"rule_id"`
+	RuleName        string     `db:"rule_name" json:"rule_name"`
+	Status          string     `db:"status" json:"status"`
+	StormAlertID    string     `db:"storm_alert_id" json:"storm_alert_id"`
+	BaselineRate    float64    `db:"baseline_rate" json:"baseline_rate"`
+	Threshold       float64    `db:"threshold" json:"threshold"`
+	PeakRate        int        `db:"peak_rate" json:"peak_rate"`
+	AlertCount      int        `db:"alert_count" json:"alert_count"`
+	AdmittedCount   int        `db:"admitted_count" json:"admitted_count"`
+	HeldCount       int        `db:"held_count" json:"held_count"`
+	ExpandedCount   int        `db:"expanded_count" json:"expanded_count"`
+	DismissedCount  int        `db:"dismissed_count" json:"dismissed_count"`
+	StartedAt       time.Time  `db:"started_at" json:"started_at"`
+	LastAlertAt     time.Time  `db:"last_alert_at" json:"last_alert_at"`
+	EndedAt         *time.Time `db:"ended_at" json:"ended_at,omitempty"`
+	ReconcileAction *string    `db:"reconcile_action" json:"reconcile_action,omitempty"`
+	ReconciledBy    *string    `db:"reconciled_by" json:"reconciled_by,omitempty"`
+	ReconciledAt    *time.Time `db:"reconciled_at" json:"reconciled_at,omitempty"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// HeldAlert is an individual alert held back during a storm, kept in full so
+// reconciliation can still create it
+type HeldAlert struct {
+	ID           string          `db:"id" json:"id"`
+	StormID      string          `db:"storm_id" json:"storm_id"`
+	RuleID       string          `db:"rule_id" json:"rule_id"`
+	Severity     string          `db:"severity" json:"severity"`
+	Title        string          `db:"title" json:"title"`
+	TriageScore  float64         `db:"triage_score" json:"triage_score"`
+	AlertData    json.RawMessage `db:"alert_data" json:"alert_data"`
+	EvidenceData json.RawMessage `db:"evidence_data" json:"evidence_data,omitempty"`
+	Status       string          `db:"status" json:"status"`
+	HeldAt       time.Time       `db:"held_at" json:"held_at"`
+	ResolvedAt   *time.Time      `db:"resolved_at" json:"resolved_at,omitempty"`
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AuditEvent is a single user action recorded against alerting data
+type AuditEvent struct {
+	ID         string    `db:"id" json:"id"`
+	UserID     string    `db:"user_id" json:"user_id"`
+	Action     string    `db:"action" json:"action"`
+	Resource   string    `db:"resource" json:"resource"`
+	ResourceID string    `db:"resource_id" json:"resource_id"`
+	Details    string    `db:"details" json:"details"`
+	Timestamp  time.Time `db:"timestamp" json:"timestamp"`
+}
+
+// AuditFilter narrows an audit event query
+type AuditFilter struct {
+	UserID string
+	Action string
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+}
+
+// AuditRepository derives user action history from alerting tables
+type AuditRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *sqlx.DB, logger *slog.Logger) *AuditRepository {
+	return &AuditRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// auditEventsQuery unions every actor/timestamp pair the alerting tables track
+const auditEventsQuery = `
+	SELECT id || ':create' AS id, created_by AS user_id, 'alert.create' AS action,
+		'alert' AS resource, id AS resource_id, title AS details, created_at AS timestamp
+	FROM alerts WHERE created_by IS NOT NULL
+	UNION ALL
+	SELECT id || ':acknowledge', acknowledged_by, 'alert.acknowledge',
+		'alert', id, title, acknowledged_at
+	FROM alerts WHERE acknowledged_by IS NOT NULL AND acknowledged_at IS NOT NULL
+	UNION ALL
+	SELECT id || ':resolve', resolved_by, 'alert.resolve',
+		'alert', id, COALESCE(resolution, title), resolved_at
+	FROM alerts WHERE resolved_by IS NOT NULL AND resolved_at IS NOT NULL
+	UNION ALL
+	SELECT id || ':escalate', escalated_by, 'alert.escalate',
+		'alert', id, title, escalated_at
+	FROM alerts WHERE escalated_by IS NOT NULL AND escalated_at IS NOT NULL
+	UNION ALL
+	SELECT id || ':create', created_by, 'rule.create',
+		'alert_rule', id, name, created_at
+	FROM alert_rules WHERE created_by IS NOT NULL
+	UNION ALL
+	SELECT id || ':request', requested_by, 'watchlist.request_' || action,
+		list_type, entity_id, justification, requested_at
+	FROM watchlist_change_requests
+	UNION ALL
+	SELECT id || ':review', reviewed_by, 'watchlist.' || status,
+		list_type, entity_id, COALESCE(review_comment, ''), reviewed_at
+	FROM watchlist_change_requests WHERE reviewed_by IS NOT NULL AND reviewed_at IS NOT NULL
+	UNION ALL
+	SELECT alert_id || ':auto_close', closed_by, 'alert.auto_close',
+		'alert', alert_id, policy || ': ' || disposition, closed_at
+	FROM alert_auto_closures
+	UNION ALL
+	SELECT alert_id || ':auto_close_review', reviewed_by, 'alert.auto_close_' || review_outcome,
+		'alert', alert_id, COALESCE(review_notes, ''), reviewed_at
+	FROM alert_auto_closures WHERE reviewed_by IS NOT NULL AND reviewed_at IS NOT NULL`
+
+// ListUserActions returns audit events matching the filter, newest first
+func (a *AuditRepository) ListUserActions(ctx context.Context, filter AuditFilter) ([]*AuditEvent, error) {
+	query := `SELECT id, user_id, action, resource, resource_id, details, timestamp FROM (` +
+		auditEventsQuery + `) AS events WHERE 1=1`
+	args := []interface{}{}
+
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		query += fmt.Sprintf(" AND action = $%d", len(args))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		query += fmt.Sprintf(" AND timestamp >= $%d", len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		query += fmt.Sprintf(" AND timestamp <= $%d", len(args))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY timestamp DESC LIMIT $%d", len(args))
+
+	var events []*AuditEvent
+	if err := a.db.SelectContext(ctx, &events, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,615 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrPortfolioNotFound is returned when a portfolio does not exist
+	ErrPortfolioNotFound = errors.New("portfolio not found")
+	// ErrBatchRuleNotFound is returned when a batch rule does not exist
+	ErrBatchRuleNotFound = errors.New("batch rule not found")
+	// ErrBatchDigestRunning is returned when a run for the business date is already in progress
+	ErrBatchDigestRunning = errors.New("a batch digest run for this business date is already running")
+)
+
+// BatchDigestRepository handles portfolios, batch rules, daily aggregates and
+// the end-of-day digests generated from them
+type BatchDigestRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewBatchDigestRepository creates a new batch digest repository
+func NewBatchDigestRepository(db *sqlx.DB, logger *slog.Logger) *BatchDigestRepository {
+	return &BatchDigestRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Portfolio operations
+
+// CreatePortfolio creates a portfolio
+func (b *BatchDigestRepository) CreatePortfolio(ctx context.Context, portfolio *Portfolio) error {
+	query := `
+		INSERT INTO portfolios (
+			id, name, manager_id, manager_contact, notification_channel,
+			entity_ids, enabled, created_by, created_at, updated_at
+		) VALUES (
+			:id, :name, :manager_id, :manager_contact, :notification_channel,
+			:entity_ids, :enabled, :created_by, :created_at, :updated_at
+		)`
+
+	now := time.Now()
+	portfolio.CreatedAt = now
+	portfolio.UpdatedAt = now
+
+	if _, err := b.db.NamedExecContext(ctx, query, portfolio); err != nil {
+		b.logger.Error("Failed to create portfolio", "portfolio_id", portfolio.ID, "error", err)
+		return fmt.Errorf("failed to create portfolio: %w", err)
+	}
+
+	b.logger.Info("Portfolio created",
+		"portfolio_id", portfolio.ID,
+		"name", portfolio.Name,
+		"manager_id", portfolio.ManagerID)
+	return nil
+}
+
+// GetPortfolio retrieves a portfolio by ID
+func (b *BatchDigestRepository) GetPortfolio(ctx context.Context, id string) (*Portfolio, error) {
+	var portfolio Portfolio
+	if err := b.db.GetContext(ctx, &portfolio, `SELECT * FROM portfolios WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPortfolioNotFound
+		}
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+
+	return &portfolio, nil
+}
+
+// ListPortfolios retrieves portfolios ordered by name
+func (b *BatchDigestRepository) ListPortfolios(ctx context.Context, enabledOnly bool) ([]*Portfolio, error) {
+	query := `
+		SELECT * FROM portfolios
+		WHERE (NOT $1 OR enabled)
+		ORDER BY name`
+
+	var portfolios []*Portfolio
+	if err := b.db.SelectContext(ctx, &portfolios, query, enabledOnly); err != nil {
+		return nil, fmt.Errorf("failed to list portfolios: %w", err)
+	}
+
+	return portfolios, nil
+}
+
+// UpdatePortfolio updates a portfolio's manager, members and delivery settings
+func (b *BatchDigestRepository) UpdatePortfolio(ctx context.Context, portfolio *Portfolio) error {
+	query := `
+		UPDATE portfolios SET
+			name = :name,
+			manager_id = :manager_id,
+			manager_contact = :manager_contact,
+			notification_channel = :notification_channel,
+			entity_ids = :entity_ids,
+			enabled = :enabled,
+			updated_by = :updated_by,
+			updated_at = :updated_at
+		WHERE id = :id`
+
+	portfolio.UpdatedAt = time.Now()
+
+	result, err := b.db.NamedExecContext(ctx, query, portfolio)
+	if err != nil {
+		b.logger.Error("Failed to update portfolio", "portfolio_id", portfolio.ID, "error", err)
+		return fmt.Errorf("failed to update portfolio: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrPortfolioNotFound
+	}
+
+	return nil
+}
+
+// DeletePortfolio removes a portfolio and its digests
+func (b *BatchDigestRepository) DeletePortfolio(ctx context.Context, id string) error {
+	result, err := b.db.ExecContext(ctx, `DELETE FROM portfolios WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete portfolio: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrPortfolioNotFound
+	}
+
+	b.logger.Info("Portfolio deleted", "portfolio_id", id)
+	return nil
+}
+
+// Batch rule operations
+
+// CreateBatchRule creates a batch rule
+func (b *BatchDigestRepository) CreateBatchRule(ctx context.Context, rule *BatchRule) error {
+	query := `
+		INSERT INTO batch_rules (
+			id, name, description, metric, aggregation, operator, threshold,
+			severity, alert_type, intraday_rule_ids, portfolio_ids, enabled,
+			created_by, created_at, updated_at
+		) VALUES (
+			:id, :name, :description, :metric, :aggregation, :operator, :threshold,
+			:severity, :alert_type, :intraday_rule_ids, :portfolio_ids, :enabled,
+			:created_by, :created_at, :updated_at
+		)`
+
+	now := time.Now()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	if _, err := b.db.NamedExecContext(ctx, query, rule); err != nil {
+		b.logger.Error("Failed to create batch rule", "rule_id", rule.ID, "error", err)
+		return fmt.Errorf("failed to create batch rule: %w", err)
+	}
+
+	b.logger.Info("Batch rule created",
+		"rule_id", rule.ID,
+		"name", rule.Name,
+		"metric", rule.Metric,
+		"created_by", rule.CreatedBy)
+	return nil
+}
+
+// GetBatchRule retrieves a batch rule by ID
+func (b *BatchDigestRepository) GetBatchRule(ctx context.Context, id string) (*BatchRule, error) {
+	var rule BatchRule
+	if err := b.db.GetContext(ctx, &rule, `SELECT * FROM batch_rules WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBatchRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to get batch rule: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// ListBatchRules retrieves batch rules ordered by name
+func (b *BatchDigestRepository) ListBatchRules(ctx context.Context, enabledOnly bool) ([]*BatchRule, error) {
+	query := `
+		SELECT * FROM batch_rules
+		WHERE (NOT $1 OR enabled)
+		ORDER BY name`
+
+	var rules []*BatchRule
+	if err := b.db.SelectContext(ctx, &rules, query, enabledOnly); err != nil {
+		return nil, fmt.Errorf("failed to list batch rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// UpdateBatchRule updates a batch rule's condition, scope and reconciliation links
+func (b *BatchDigestRepository) UpdateBatchRule(ctx context.Context, rule *BatchRule) error {
+	query := `
+		UPDATE batch_rules SET
+			name = :name,
+			description = :description,
+			metric = :metric,
+			aggregation = :aggregation,
+			operator = :operator,
+			threshold = :threshold,
+			severity = :severity,
+			alert_type = :alert_type,
+			intraday_rule_ids = :intraday_rule_ids,
+			portfolio_ids = :portfolio_ids,
+			enabled = :enabled,
+			updated_by = :updated_by,
+			updated_at = :updated_at
+		WHERE id = :id`
+
+	rule.UpdatedAt = time.Now()
+
+	result, err := b.db.NamedExecContext(ctx, query, rule)
+	if err != nil {
+		b.logger.Error("Failed to update batch rule", "rule_id", rule.ID, "error", err)
+		return fmt.Errorf("failed to update batch rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrBatchRuleNotFound
+	}
+
+	return nil
+}
+
+// DeleteBatchRule removes a batch rule
+func (b *BatchDigestRepository) DeleteBatchRule(ctx context.Context, id string) error {
+	result, err := b.db.ExecContext(ctx, `DELETE FROM batch_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete batch rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrBatchRuleNotFound
+	}
+
+	b.logger.Info("Batch rule deleted", "rule_id", id)
+	return nil
+}
+
+// Aggregate operations
+
+// UpsertAggregates merges aggregates into their daily rows. Totals and counts
+// are added and the maximum kept, so upstream jobs may load a day in several
+// increments.
+func (b *BatchDigestRepository) UpsertAggregates(ctx context.Context, aggregates []*DailyAggregate) error {
+	return b.Transaction(func(tx *sqlx.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO batch_daily_aggregates (business_date, entity_id, metric, total, count, max_value, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, NOW())
+			ON CONFLICT (business_date, entity_id, metric) DO UPDATE SET
+				total = batch_daily_aggregates.total + EXCLUDED.total,
+				count = batch_daily_aggregates.count + EXCLUDED.count,
+				max_value = GREATEST(batch_daily_aggregates.max_value, EXCLUDED.max_value),
+				updated_at = NOW()`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare aggregate upsert: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, aggregate := range aggregates {
+			if _, err := stmt.ExecContext(ctx, aggregate.BusinessDate, aggregate.EntityID, aggregate.Metric,
+				aggregate.Total, aggregate.Count, aggregate.MaxValue); err != nil {
+				return fmt.Errorf("failed to upsert aggregate for %s/%s: %w", aggregate.EntityID, aggregate.Metric, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListAggregates retrieves a business day's aggregates for the given entities
+func (b *BatchDigestRepository) ListAggregates(ctx context.Context, businessDate string, entityIDs []string) ([]*DailyAggregate, error) {
+	query := `
+		SELECT business_date::text AS business_date, entity_id, metric, total, count, max_value
+		FROM batch_daily_aggregates
+		WHERE business_date = $1 AND entity_id = ANY($2)
+		ORDER BY entity_id, metric`
+
+	var aggregates []*DailyAggregate
+	if err := b.db.SelectContext(ctx, &aggregates, query, businessDate, pq.Array(entityIDs)); err != nil {
+		return nil, fmt.Errorf("failed to list daily aggregates: %w", err)
+	}
+
+	return aggregates, nil
+}
+
+// ListIntradayAlerts retrieves alerts raised in real time within a window for
+// any of the given entities, excluding alerts produced by batch runs
+func (b *BatchDigestRepository) ListIntradayAlerts(ctx context.Context, from, to time.Time, entityIDs []string, batchSource string) ([]*Alert, error) {
+	query := `
+		SELECT * FROM alerts
+		WHERE created_at >= $1 AND created_at < $2
+		AND entity_ids && $3
+		AND source <> $4
+		AND deleted_at IS NULL
+		ORDER BY created_at`
+
+	var alerts []*Alert
+	if err := b.db.SelectContext(ctx, &alerts, query, from, to, pq.Array(entityIDs), batchSource); err != nil {
+		return nil, fmt.Errorf("failed to list intraday alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// ListAlertFingerprints returns which of the given fingerprints already have an alert
+func (b *BatchDigestRepository) ListAlertFingerprints(ctx context.Context, fingerprints []string) (map[string]string, error) {
+	rows, err := b.db.QueryContext(ctx,
+		`SELECT fingerprint, id FROM alerts WHERE fingerprint = ANY($1) AND deleted_at IS NULL`,
+		pq.Array(fingerprints))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert fingerprints: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]string)
+	for rows.Next() {
+		var fingerprint, id string
+		if err := rows.Scan(&fingerprint, &id); err != nil {
+			return nil, fmt.Errorf("failed to scan alert fingerprint: %w", err)
+		}
+		existing[fingerprint] = id
+	}
+
+	return existing, rows.Err()
+}
+
+// Run and digest operations
+
+// CreateRun starts a digest run, refusing a second concurrent run for the same business date
+func (b *BatchDigestRepository) CreateRun(ctx context.Context, run *BatchDigestRun) error {
+	query := `
+		INSERT INTO batch_digest_runs (id, business_date, status, triggered_by, started_at)
+		SELECT $1, $2, $3, $4, $5
+		WHERE NOT EXISTS (
+			SELECT 1 FROM batch_digest_runs
+			WHERE business_date = $2 AND status = 'running'
+			AND started_at > NOW() - INTERVAL '1 hour'
+		)`
+
+	result, err := b.db.ExecContext(ctx, query, run.ID, run.BusinessDate, run.Status, run.TriggeredBy, run.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create batch digest run: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrBatchDigestRunning
+	}
+
+	return nil
+}
+
+// CompleteRun stores the final counts of a run
+func (b *BatchDigestRepository) CompleteRun(ctx context.Context, run *BatchDigestRun) error {
+	query := `
+		UPDATE batch_digest_runs SET
+			status = :status,
+			portfolios = :portfolios,
+			alerts_generated = :alerts_generated,
+			duplicates_suppressed = :duplicates_suppressed,
+			already_generated = :already_generated,
+			error_message = :error_message,
+			completed_at = :completed_at
+		WHERE id = :id`
+
+	if _, err := b.db.NamedExecContext(ctx, query, run); err != nil {
+		return fmt.Errorf("failed to complete batch digest run: %w", err)
+	}
+
+	return nil
+}
+
+// ListRuns retrieves recent runs, optionally for one business date
+func (b *BatchDigestRepository) ListRuns(ctx context.Context, businessDate string, limit int) ([]*BatchDigestRun, error) {
+	query := `
+		SELECT id, business_date::text AS business_date, status, triggered_by, portfolios,
+			   alerts_generated, duplicates_suppressed, already_generated, error_message,
+			   started_at, completed_at
+		FROM batch_digest_runs
+		WHERE ($1 = '' OR business_date = $1::date)
+		ORDER BY started_at DESC
+		LIMIT $2`
+
+	var runs []*BatchDigestRun
+	if err := b.db.SelectContext(ctx, &runs, query, businessDate, limit); err != nil {
+		return nil, fmt.Errorf("failed to list batch digest runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// SaveDigest stores a portfolio's digest for a business day. A rerun replaces
+// the earlier digest, adding newly generated alerts to those already sent.
+func (b *BatchDigestRepository) SaveDigest(ctx context.Context, digest *BatchDigest) error {
+	query := `
+		INSERT INTO batch_digests (
+			id, run_id, business_date, portfolio_id, manager_id, alert_ids,
+			suppressed, subject, body, notification_id, created_at, updated_at
+		) VALUES (
+			:id, :run_id, :business_date, :portfolio_id, :manager_id, :alert_ids,
+			:suppressed, :subject, :body, :notification_id, :created_at, :updated_at
+		)
+		ON CONFLICT (business_date, portfolio_id) DO UPDATE SET
+			run_id = EXCLUDED.run_id,
+			manager_id = EXCLUDED.manager_id,
+			alert_ids = EXCLUDED.alert_ids,
+			suppressed = EXCLUDED.suppressed,
+			subject = EXCLUDED.subject,
+			body = EXCLUDED.body,
+			notification_id = COALESCE(EXCLUDED.notification_id, batch_digests.notification_id)
+		RETURNING id, created_at`
+
+	now := time.Now()
+	digest.CreatedAt = now
+	digest.UpdatedAt = now
+
+	rows, err := b.db.NamedQueryContext(ctx, query, digest)
+	if err != nil {
+		b.logger.Error("Failed to save batch digest", "portfolio_id", digest.PortfolioID, "error", err)
+		return fmt.Errorf("failed to save batch digest: %w", err)
+	}
+	defer rows.Close()
+
+	// A rerun keeps the digest's original id
+	if rows.Next() {
+		if err := rows.Scan(&digest.ID, &digest.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan batch digest: %w", err)
+		}
+	}
+
+	return rows.Err()
+}
+
+// SetDigestNotification links a digest to the notification that delivers it
+func (b *BatchDigestRepository) SetDigestNotification(ctx context.Context, id, notificationID string) error {
+	_, err := b.db.ExecContext(ctx,
+		`UPDATE batch_digests SET notification_id = $2 WHERE id = $1`, id, notificationID)
+	if err != nil {
+		return fmt.Errorf("failed to set batch digest notification: %w", err)
+	}
+
+	return nil
+}
+
+// ListDigests retrieves digests filtered by business date, portfolio and manager, newest first
+func (b *BatchDigestRepository) ListDigests(ctx context.Context, businessDate, portfolioID, managerID string, limit int) ([]*BatchDigest, error) {
+	query := `
+		SELECT id, run_id, business_date::text AS business_date, portfolio_id, manager_id,
+			   alert_ids, suppressed, subject, body, notification_id, created_at, updated_at
+		FROM batch_digests
+		WHERE ($1 = '' OR business_date = $1::date)
+		AND ($2 = '' OR portfolio_id = $2)
+		AND ($3 = '' OR manager_id = $3)
+		ORDER BY business_date DESC, portfolio_id
+		LIMIT $4`
+
+	var digests []*BatchDigest
+	if err := b.db.SelectContext(ctx, &digests, query, businessDate, portfolioID, managerID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list batch digests: %w", err)
+	}
+
+	return digests, nil
+}
+
+// Batch digest types
+
+// Batch digest run statuses
+const (
+	BatchRunStatusRunning   = "running"
+	BatchRunStatusCompleted = "completed"
+	BatchRunStatusFailed    = "failed"
+)
+
+// Portfolio groups monitored entities under the manager who receives their digest
+type Portfolio struct {
+	ID                  string         `db:"id" json:"id"`
+	Name                string         `db:"name" json:"name"`
+	ManagerID           string         `db:"manager_id" json:"manager_id"`
+	ManagerContact      string         `db:"manager_contact" json:"manager_contact"`
+	NotificationChannel string         `db:"notification_channel" json:"notification_channel"`
+	EntityIDs           pq.StringArray `db:"entity_ids" json:"entity_ids"`
+	Enabled             bool           `db:"enabled" json:"enabled"`
+	CreatedBy           string         `db:"created_by" json:"created_by"`
+	UpdatedBy           *string        `db:"updated_by" json:"updated_by,omitempty"`
+	CreatedAt           time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// BatchRule compares one aggregate of a metric with a threshold
+type BatchRule struct {
+	ID              string         `db:"id" json:"id"`
+	Name            string         `db:"name" json:"name"`
+	Description     *string        `db:"description" json:"description,omitempty"`
+	Metric          string         `db:"metric" json:"metric"`
+	Aggregation     string         `db:"aggregation" json:"aggregation"`
+	Operator        string         `db:"operator" json:"operator"`
+	Threshold       float64        `db:"threshold" json:"threshold"`
+	Severity        string         `db:"severity" json:"severity"`
+	AlertType       string         `db:"alert_type" json:"alert_type"`
+	IntradayRuleIDs pq.StringArray `db:"intraday_rule_ids" json:"intraday_rule_ids"`
+	PortfolioIDs    pq.StringArray `db:"portfolio_ids" json:"portfolio_ids"`
+	Enabled         bool           `db:"enabled" json:"enabled"`
+	CreatedBy       string         `db:"created_by" json:"created_by"`
+	UpdatedBy       *string        `db:"updated_by" json:"updated_by,omitempty"`
+	CreatedAt       time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// DailyAggregate is one entity's aggregated activity for a metric on a business day
+type DailyAggregate struct {
+	BusinessDate string  `db:"business_date" json:"business_date"`
+	EntityID     string  `db:"entity_id" json:"entity_id"`
+	Metric       string  `db:"metric" json:"metric"`
+	Total        float64 `db:"total" json:"total"`
+	Count        int64   `db:"count" json:"count"`
+	MaxValue     float64 `db:"max_value" json:"max_value"`
+}
+
+// BatchDigestRun summarizes one end-of-day evaluation
+type BatchDigestRun struct {
+	ID                   string     `db:"id" json:"id"`
+	BusinessDate         string     `db:"business_date" json:"business_date"`
+	Status               string     `db:"status" json:"status"`
+	TriggeredBy          string     `db:"triggered_by" json:"triggered_by"`
+	Portfolios           int        `db:"portfolios" json:"portfolios"`
+	AlertsGenerated      int        `db:"alerts_generated" json:"alerts_generated"`
+	DuplicatesSuppressed int        `db:"duplicates_suppressed" json:"duplicates_suppressed"`
+	AlreadyGenerated     int        `db:"already_generated" json:"already_generated"`
+	ErrorMessage         *string    `db:"error_message" json:"error_message,omitempty"`
+	StartedAt            time.Time  `db:"started_at" json:"started_at"`
+	CompletedAt          *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+// BatchDigest is the end-of-day digest sent to a portfolio manager
+type BatchDigest struct {
+	ID             string          `db:"id" json:"id"`
+	RunID          string          `db:"run_id" json:"run_id"`
+	BusinessDate   string          `db:"business_date" json:"business_date"`
+	PortfolioID    string          `db:"portfolio_id" json:"portfolio_id"`
+	ManagerID      string          `db:"manager_id" json:"manager_id"`
+	AlertIDs       pq.StringArray  `db:"alert_ids" json:"alert_ids"`
+	Suppressed     SuppressionList `db:"suppressed" json:"suppressed"`
+	Subject        string          `db:"subject" json:"subject"`
+	Body           string          `db:"body" json:"body"`
+	NotificationID *string         `db:"notification_id" json:"notification_id,omitempty"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// Suppression records a batch finding left out of a digest because a
+// real-time alert already covered it
+type Suppression struct {
+	RuleID          string  `json:"rule_id"`
+	RuleName        string  `json:"rule_name"`
+	EntityID        string  `json:"entity_id"`
+	Value           float64 `json:"value"`
+	IntradayAlertID string  `json:"intraday_alert_id"`
+}
+
+// SuppressionList implements database/sql/driver.Valuer and sql.Scanner for suppressed findings
+type SuppressionList []Suppression
+
+func (s SuppressionList) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *SuppressionList) Scan(value interface{}) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return fmt.Errorf("cannot scan %T into SuppressionList", value)
+	}
+}
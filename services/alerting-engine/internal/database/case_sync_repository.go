@@ -0,0 +1,716 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrConnectorNotFound is returned when a case sync connector does not exist
+	ErrConnectorNotFound = errors.New("case sync connector not found")
+	// ErrCaseLinkNotFound is returned when no external case is linked to an alert
+	ErrCaseLinkNotFound = errors.New("case sync link not found")
+)
+
+// CaseSyncRepository handles external case management connectors, the
+// lifecycle delivery outbox, alert-to-case links and reconciliation runs
+type CaseSyncRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewCaseSyncRepository creates a new case sync repository
+func NewCaseSyncRepository(db *sqlx.DB, logger *slog.Logger) *CaseSyncRepository {
+	return &CaseSyncRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Connector operations
+
+// CreateConnector registers an external case management system
+func (c *CaseSyncRepository) CreateConnector(ctx context.Context, connector *CaseSyncConnector) error {
+	query := `
+		INSERT INTO case_sync_connectors (
+			id, name, system_type, enabled, endpoint_url, http_method,
+			update_url_template, update_method, status_url_template, auth_type,
+			auth_header, credential_env, headers, event_types, field_mappings,
+			status_mappings, external_id_field, callback_mapping, callback_secret,
+			drift_policy, max_attempts, created_by, created_at, updated_at
+		) VALUES (
+			:id, :name, :system_type, :enabled, :endpoint_url, :http_method,
+			:update_url_template, :update_method, :status_url_template, :auth_type,
+			:auth_header, :credential_env, :headers, :event_types, :field_mappings,
+			:status_mappings, :external_id_field, :callback_mapping, :callback_secret,
+			:drift_policy, :max_attempts, :created_by, :created_at, :updated_at
+		)`
+
+	now := time.Now()
+	connector.CreatedAt = now
+	connector.UpdatedAt = now
+
+	if _, err := c.db.NamedExecContext(ctx, query, connector); err != nil {
+		c.logger.Error("Failed to create case sync connector", "connector_id", connector.ID, "error", err)
+		return fmt.Errorf("failed to create case sync connector: %w", err)
+	}
+
+	c.logger.Info("Case sync connector created",
+		"connector_id", connector.ID,
+		"name", connector.Name,
+		"system_type", connector.SystemType,
+		"created_by", connector.CreatedBy)
+	return nil
+}
+
+// GetConnector retrieves a connector by ID
+func (c *CaseSyncRepository) GetConnector(ctx context.Context, id string) (*CaseSyncConnector, error) {
+	query := `SELECT * FROM case_sync_connectors WHERE id = $1`
+
+	var connector CaseSyncConnector
+	if err := c.db.GetContext(ctx, &connector, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrConnectorNotFound
+		}
+		return nil, fmt.Errorf("failed to get case sync connector: %w", err)
+	}
+
+	return &connector, nil
+}
+
+// ListConnectors retrieves connectors ordered by name
+func (c *CaseSyncRepository) ListConnectors(ctx context.Context, enabledOnly bool) ([]*CaseSyncConnector, error) {
+	query := `
+		SELECT * FROM case_sync_connectors
+		WHERE (NOT $1 OR enabled)
+		ORDER BY name`
+
+	var connectors []*CaseSyncConnector
+	if err := c.db.SelectContext(ctx, &connectors, query, enabledOnly); err != nil {
+		return nil, fmt.Errorf("failed to list case sync connectors: %w", err)
+	}
+
+	return connectors, nil
+}
+
+// UpdateConnector updates a connector's configuration
+func (c *CaseSyncRepository) UpdateConnector(ctx context.Context, connector *CaseSyncConnector) error {
+	query := `
+		UPDATE case_sync_connectors SET
+			name = :name,
+			system_type = :system_type,
+			enabled = :enabled,
+			endpoint_url = :endpoint_url,
+			http_method = :http_method,
+			update_url_template = :update_url_template,
+			update_method = :update_method,
+			status_url_template = :status_url_template,
+			auth_type = :auth_type,
+			auth_header = :auth_header,
+			credential_env = :credential_env,
+			headers = :headers,
+			event_types = :event_types,
+			field_mappings = :field_mappings,
+			status_mappings = :status_mappings,
+			external_id_field = :external_id_field,
+			callback_mapping = :callback_mapping,
+			callback_secret = :callback_secret,
+			drift_policy = :drift_policy,
+			max_attempts = :max_attempts,
+			updated_by = :updated_by,
+			updated_at = :updated_at
+		WHERE id = :id`
+
+	connector.UpdatedAt = time.Now()
+
+	result, err := c.db.NamedExecContext(ctx, query, connector)
+	if err != nil {
+		c.logger.Error("Failed to update case sync connector", "connector_id", connector.ID, "error", err)
+		return fmt.Errorf("failed to update case sync connector: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrConnectorNotFound
+	}
+
+	return nil
+}
+
+// DeleteConnector removes a connector together with its outbox, links and reports
+func (c *CaseSyncRepository) DeleteConnector(ctx context.Context, id string) error {
+	result, err := c.db.ExecContext(ctx, `DELETE FROM case_sync_connectors WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete case sync connector: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrConnectorNotFound
+	}
+
+	c.logger.Info("Case sync connector deleted", "connector_id", id)
+	return nil
+}
+
+// Delivery operations
+
+// ClaimDueDeliveries leases deliveries that are due for an attempt. A delivery
+// is only claimed once every older undelivered change for the same alert and
+// connector has been sent, so external systems see lifecycle changes in order.
+func (c *CaseSyncRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*CaseSyncDelivery, error) {
+	query := `
+		UPDATE case_sync_deliveries SET next_attempt_at = NOW() + $2::interval
+		WHERE id IN (
+			SELECT d.id FROM case_sync_deliveries d
+			WHERE d.status IN ('pending', 'failed')
+			  AND d.next_attempt_at <= NOW()
+			  AND NOT EXISTS (
+				SELECT 1 FROM case_sync_deliveries p
+				WHERE p.connector_id = d.connector_id
+				  AND p.alert_id = d.alert_id
+				  AND p.status IN ('pending', 'failed')
+				  AND p.created_at < d.created_at
+			  )
+			ORDER BY d.created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	var deliveries []*CaseSyncDelivery
+	if err := c.db.SelectContext(ctx, &deliveries, query, limit, fmt.Sprintf("%d seconds", int(lease.Seconds()))); err != nil {
+		return nil, fmt.Errorf("failed to claim case sync deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// EnqueueDelivery queues a change for a single connector outside the alert trigger,
+// used to push the current alert state when reconciliation finds drift
+func (c *CaseSyncRepository) EnqueueDelivery(ctx context.Context, delivery *CaseSyncDelivery) error {
+	query := `
+		INSERT INTO case_sync_deliveries (
+			id, connector_id, alert_id, event_type, alert_status, status,
+			attempts, next_attempt_at, created_at, updated_at
+		) VALUES (
+			:id, :connector_id, :alert_id, :event_type, :alert_status, :status,
+			:attempts, :next_attempt_at, :created_at, :updated_at
+		)`
+
+	now := time.Now()
+	delivery.Status = DeliveryStatusPending
+	delivery.NextAttemptAt = now
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+
+	if _, err := c.db.NamedExecContext(ctx, query, delivery); err != nil {
+		return fmt.Errorf("failed to enqueue case sync delivery: %w", err)
+	}
+
+	return nil
+}
+
+// MarkDelivered records a successful delivery and updates the alert's case link.
+// threadMigratedAt, when set, is the newest alert comment the delivery carried.
+func (c *CaseSyncRepository) MarkDelivered(ctx context.Context, delivery *CaseSyncDelivery, responseCode int, externalID string, threadMigratedAt *time.Time) error {
+	deliveryQuery := `
+		UPDATE case_sync_deliveries SET
+			status = 'delivered',
+			attempts = attempts + 1,
+			response_code = $2,
+			last_error = NULL,
+			delivered_at = NOW()
+		WHERE id = $1`
+
+	linkQuery := `
+		INSERT INTO case_sync_links (
+			connector_id, alert_id, external_id, last_pushed_status, last_pushed_at, thread_migrated_at
+		) VALUES ($1, $2, NULLIF($3, ''), $4, NOW(), $5)
+		ON CONFLICT (connector_id, alert_id) DO UPDATE SET
+			external_id = COALESCE(case_sync_links.external_id, EXCLUDED.external_id),
+			last_pushed_status = EXCLUDED.last_pushed_status,
+			last_pushed_at = EXCLUDED.last_pushed_at,
+			thread_migrated_at = COALESCE(EXCLUDED.thread_migrated_at, case_sync_links.thread_migrated_at)`
+
+	err := c.Transaction(func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, deliveryQuery, delivery.ID, responseCode); err != nil {
+			return fmt.Errorf("failed to mark case sync delivery delivered: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, linkQuery,
+			delivery.ConnectorID, delivery.AlertID, externalID, delivery.AlertStatus, threadMigratedAt); err != nil {
+			return fmt.Errorf("failed to update case sync link: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		c.logger.Error("Failed to record case sync delivery", "delivery_id", delivery.ID, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed attempt; dead deliveries are no longer retried
+func (c *CaseSyncRepository) MarkFailed(ctx context.Context, deliveryID string, responseCode *int, lastError string, nextAttemptAt time.Time, dead bool) error {
+	status := DeliveryStatusFailed
+	if dead {
+		status = DeliveryStatusDead
+	}
+
+	query := `
+		UPDATE case_sync_deliveries SET
+			status = $2,
+			attempts = attempts + 1,
+			response_code = $3,
+			last_error = $4,
+			next_attempt_at = $5
+		WHERE id = $1`
+
+	if _, err := c.db.ExecContext(ctx, query, deliveryID, status, responseCode, lastError, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to mark case sync delivery failed: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeliveries retrieves deliveries filtered by connector, alert and status, newest first
+func (c *CaseSyncRepository) ListDeliveries(ctx context.Context, connectorID, alertID, status string, limit int) ([]*CaseSyncDelivery, error) {
+	query := `
+		SELECT * FROM case_sync_deliveries
+		WHERE ($1 = '' OR connector_id = $1)
+		  AND ($2 = '' OR alert_id = $2)
+		  AND ($3 = '' OR status = $3)
+		ORDER BY created_at DESC
+		LIMIT $4`
+
+	var deliveries []*CaseSyncDelivery
+	if err := c.db.SelectContext(ctx, &deliveries, query, connectorID, alertID, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to list case sync deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RetryDelivery requeues a failed or dead delivery for immediate delivery
+func (c *CaseSyncRepository) RetryDelivery(ctx context.Context, id string) error {
+	query := `
+		UPDATE case_sync_deliveries SET
+			status = 'pending',
+			attempts = 0,
+			next_attempt_at = NOW()
+		WHERE id = $1 AND status IN ('failed', 'dead')`
+
+	result, err := c.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to retry case sync delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("delivery not found or not retryable: %s", id)
+	}
+
+	return nil
+}
+
+// Link operations
+
+// GetLink retrieves the external case linked to an alert by a connector
+func (c *CaseSyncRepository) GetLink(ctx context.Context, connectorID, alertID string) (*CaseSyncLink, error) {
+	query := `SELECT * FROM case_sync_links WHERE connector_id = $1 AND alert_id = $2`
+
+	var link CaseSyncLink
+	if err := c.db.GetContext(ctx, &link, query, connectorID, alertID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCaseLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to get case sync link: %w", err)
+	}
+
+	return &link, nil
+}
+
+// GetLinkByExternalID retrieves a link by the external system's case identifier
+func (c *CaseSyncRepository) GetLinkByExternalID(ctx context.Context, connectorID, externalID string) (*CaseSyncLink, error) {
+	query := `SELECT * FROM case_sync_links WHERE connector_id = $1 AND external_id = $2`
+
+	var link CaseSyncLink
+	if err := c.db.GetContext(ctx, &link, query, connectorID, externalID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCaseLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to get case sync link: %w", err)
+	}
+
+	return &link, nil
+}
+
+// ListLinksForAlert retrieves every external case linked to an alert
+func (c *CaseSyncRepository) ListLinksForAlert(ctx context.Context, alertID string) ([]*CaseSyncLink, error) {
+	query := `SELECT * FROM case_sync_links WHERE alert_id = $1 ORDER BY connector_id`
+
+	var links []*CaseSyncLink
+	if err := c.db.SelectContext(ctx, &links, query, alertID); err != nil {
+		return nil, fmt.Errorf("failed to list case sync links: %w", err)
+	}
+
+	return links, nil
+}
+
+// ApplyCallback records an external acknowledgment on the alert's case link
+// and, when internalStatus is set, moves the alert to that status. The
+// update is tagged with the connector as origin so it is not echoed back.
+// Only alerts already linked by the connector, to the same external case if
+// one is given, can be acknowledged; others return ErrCaseLinkNotFound.
+func (c *CaseSyncRepository) ApplyCallback(ctx context.Context, connectorID, alertID, externalID, externalStatus, internalStatus, actor string) (bool, error) {
+	linkQuery := `
+		UPDATE case_sync_links SET
+			external_id = COALESCE(external_id, NULLIF($3, '')),
+			external_status = COALESCE(NULLIF($4, ''), external_status),
+			acknowledged_at = COALESCE(acknowledged_at, NOW()),
+			acknowledged_by = COALESCE(acknowledged_by, NULLIF($5, ''))
+		WHERE connector_id = $1 AND alert_id = $2
+		  AND (external_id IS NULL OR NULLIF($3, '') IS NULL OR external_id = $3)`
+
+	var changed bool
+	err := c.Transaction(func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, linkQuery, connectorID, alertID, externalID, externalStatus, actor)
+		if err != nil {
+			return fmt.Errorf("failed to record case sync callback: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return ErrCaseLinkNotFound
+		}
+
+		if internalStatus == "" {
+			return nil
+		}
+
+		applied, err := applyExternalStatus(ctx, tx, connectorID, alertID, internalStatus, actor)
+		if err != nil {
+			return err
+		}
+		changed = applied
+		return nil
+	})
+	if errors.Is(err, ErrCaseLinkNotFound) {
+		return false, err
+	}
+	if err != nil {
+		c.logger.Error("Failed to apply case sync callback",
+			"connector_id", connectorID,
+			"alert_id", alertID,
+			"error", err)
+		return false, err
+	}
+
+	return changed, nil
+}
+
+// ListLinksForReconciliation retrieves linked alerts that are still open or
+// changed since the given time, paging by alert ID
+func (c *CaseSyncRepository) ListLinksForReconciliation(ctx context.Context, connectorID string, since time.Time, afterAlertID string, limit int) ([]*ReconciliationItem, error) {
+	query := `
+		SELECT l.*, a.status AS alert_status
+		FROM case_sync_links l
+		JOIN alerts a ON a.id = l.alert_id
+		WHERE l.connector_id = $1
+		  AND l.external_id IS NOT NULL
+		  AND (a.status <> 'resolved' OR a.updated_at >= $2)
+		  AND l.alert_id > $3
+		ORDER BY l.alert_id
+		LIMIT $4`
+
+	var items []*ReconciliationItem
+	if err := c.db.SelectContext(ctx, &items, query, connectorID, since, afterAlertID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list case sync links for reconciliation: %w", err)
+	}
+
+	return items, nil
+}
+
+// MarkReconciled records the external status observed for a link
+func (c *CaseSyncRepository) MarkReconciled(ctx context.Context, connectorID, alertID, externalStatus string, drifted bool) error {
+	query := `
+		UPDATE case_sync_links SET
+			external_status = $3,
+			last_reconciled_at = NOW(),
+			drift_detected_at = CASE WHEN $4 THEN NOW() ELSE NULL END
+		WHERE connector_id = $1 AND alert_id = $2`
+
+	if _, err := c.db.ExecContext(ctx, query, connectorID, alertID, externalStatus, drifted); err != nil {
+		return fmt.Errorf("failed to mark case sync link reconciled: %w", err)
+	}
+
+	return nil
+}
+
+// PullExternalStatus moves an alert to the status held by the external system,
+// tagging the change so it is not pushed back to that connector
+func (c *CaseSyncRepository) PullExternalStatus(ctx context.Context, connectorID, alertID, internalStatus, actor string) (bool, error) {
+	var changed bool
+	err := c.Transaction(func(tx *sqlx.Tx) error {
+		applied, err := applyExternalStatus(ctx, tx, connectorID, alertID, internalStatus, actor)
+		changed = applied
+		return err
+	})
+	return changed, err
+}
+
+// applyExternalStatus advances an alert to acknowledged or resolved. Alerts
+// are never moved backwards by an external system.
+func applyExternalStatus(ctx context.Context, tx *sqlx.Tx, connectorID, alertID, internalStatus, actor string) (bool, error) {
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('aegis.case_sync_origin', $1, true)`, connectorID); err != nil {
+		return false, fmt.Errorf("failed to tag case sync origin: %w", err)
+	}
+
+	var query string
+	switch internalStatus {
+	case "acknowledged":
+		query = `
+			UPDATE alerts SET
+				status = 'acknowledged',
+				acknowledged_at = NOW(),
+				acknowledged_by = $2,
+				updated_at = NOW()
+			WHERE id = $1 AND status IN ('open', 'active', 'escalated')`
+	case "resolved":
+		query = `
+			UPDATE alerts SET
+				status = 'resolved',
+				resolved_at = NOW(),
+				resolved_by = $2,
+				resolution_reason = 'Resolved in external case management system',
+				updated_at = NOW()
+			WHERE id = $1 AND status <> 'resolved'`
+	default:
+		return false, nil
+	}
+
+	result, err := tx.ExecContext(ctx, query, alertID, actor)
+	if err != nil {
+		return false, fmt.Errorf("failed to apply external status to alert: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// Reconciliation operations
+
+// CreateReconciliation starts a reconciliation report for a connector
+func (c *CaseSyncRepository) CreateReconciliation(ctx context.Context, run *CaseSyncReconciliation) error {
+	query := `
+		INSERT INTO case_sync_reconciliations (id, connector_id, status, started_at)
+		VALUES (:id, :connector_id, :status, :started_at)`
+
+	if _, err := c.db.NamedExecContext(ctx, query, run); err != nil {
+		return fmt.Errorf("failed to create case sync reconciliation: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteReconciliation stores the final counts and drift details of a run
+func (c *CaseSyncRepository) CompleteReconciliation(ctx context.Context, run *CaseSyncReconciliation) error {
+	query := `
+		UPDATE case_sync_reconciliations SET
+			status = :status,
+			checked = :checked,
+			in_sync = :in_sync,
+			drifted = :drifted,
+			repaired = :repaired,
+			errors = :errors,
+			drift = :drift,
+			error_message = :error_message,
+			completed_at = :completed_at
+		WHERE id = :id`
+
+	if _, err := c.db.NamedExecContext(ctx, query, run); err != nil {
+		return fmt.Errorf("failed to complete case sync reconciliation: %w", err)
+	}
+
+	return nil
+}
+
+// ListReconciliations retrieves recent reconciliation reports for a connector
+func (c *CaseSyncRepository) ListReconciliations(ctx context.Context, connectorID string, limit int) ([]*CaseSyncReconciliation, error) {
+	query := `
+		SELECT * FROM case_sync_reconciliations
+		WHERE ($1 = '' OR connector_id = $1)
+		ORDER BY started_at DESC
+		LIMIT $2`
+
+	var runs []*CaseSyncReconciliation
+	if err := c.db.SelectContext(ctx, &runs, query, connectorID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list case sync reconciliations: %w", err)
+	}
+
+	return runs, nil
+}
+
+// Case sync types
+
+// Case sync delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusFailed    = "failed"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusDead      = "dead"
+)
+
+// Case sync drift policies
+const (
+	DriftPolicyReport = "report"
+	DriftPolicyPush   = "push"
+	DriftPolicyPull   = "pull"
+)
+
+// CaseSyncConnector describes how alert lifecycle changes are pushed to an
+// external case management system and how its callbacks are read
+type CaseSyncConnector struct {
+	ID                string         `db:"id" json:"id"`
+	Name              string         `db:"name" json:"name"`
+	SystemType        string         `db:"system_type" json:"system_type"`
+	Enabled           bool           `db:"enabled" json:"enabled"`
+	EndpointURL       string         `db:"endpoint_url" json:"endpoint_url"`
+	HTTPMethod        string         `db:"http_method" json:"http_method"`
+	UpdateURLTemplate *string        `db:"update_url_template" json:"update_url_template,omitempty"`
+	UpdateMethod      string         `db:"update_method" json:"update_method"`
+	StatusURLTemplate *string        `db:"status_url_template" json:"status_url_template,omitempty"`
+	AuthType          string         `db:"auth_type" json:"auth_type"`
+	AuthHeader        *string        `db:"auth_header" json:"auth_header,omitempty"`
+	CredentialEnv     *string        `db:"credential_env" json:"credential_env,omitempty"`
+	Headers           JSONB          `db:"headers" json:"headers"`
+	EventTypes        pq.StringArray `db:"event_types" json:"event_types"`
+	FieldMappings     JSONB          `db:"field_mappings" json:"field_mappings"`
+	StatusMappings    JSONB          `db:"status_mappings" json:"status_mappings"`
+	ExternalIDField   *string        `db:"external_id_field" json:"external_id_field,omitempty"`
+	CallbackMapping   JSONB          `db:"callback_mapping" json:"callback_mapping"`
+	CallbackSecret    string         `db:"callback_secret" json:"-"`
+	DriftPolicy       string         `db:"drift_policy" json:"drift_policy"`
+	MaxAttempts       int            `db:"max_attempts" json:"max_attempts"`
+	CreatedBy         string         `db:"created_by" json:"created_by"`
+	UpdatedBy         *string        `db:"updated_by" json:"updated_by,omitempty"`
+	CreatedAt         time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// CaseSyncDelivery is one alert lifecycle change queued for a connector
+type CaseSyncDelivery struct {
+	ID            string     `db:"id" json:"id"`
+	ConnectorID   string     `db:"connector_id" json:"connector_id"`
+	AlertID       string     `db:"alert_id" json:"alert_id"`
+	EventType     string     `db:"event_type" json:"event_type"`
+	AlertStatus   string     `db:"alert_status" json:"alert_status"`
+	Status        string     `db:"status" json:"status"`
+	Attempts      int        `db:"attempts" json:"attempts"`
+	NextAttemptAt time.Time  `db:"next_attempt_at" json:"next_attempt_at"`
+	LastError     *string    `db:"last_error" json:"last_error,omitempty"`
+	ResponseCode  *int       `db:"response_code" json:"response_code,omitempty"`
+	DeliveredAt   *time.Time `db:"delivered_at" json:"delivered_at,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// CaseSyncLink ties an alert to the case record an external system holds for it
+type CaseSyncLink struct {
+	ConnectorID      string     `db:"connector_id" json:"connector_id"`
+	AlertID          string     `db:"alert_id" json:"alert_id"`
+	ExternalID       *string    `db:"external_id" json:"external_id,omitempty"`
+	ExternalStatus   *string    `db:"external_status" json:"external_status,omitempty"`
+	LastPushedStatus *string    `db:"last_pushed_status" json:"last_pushed_status,omitempty"`
+	LastPushedAt     *time.Time `db:"last_pushed_at" json:"last_pushed_at,omitempty"`
+	AcknowledgedAt   *time.Time `db:"acknowledged_at" json:"acknowledged_at,omitempty"`
+	AcknowledgedBy   *string    `db:"acknowledged_by" json:"acknowledged_by,omitempty"`
+	LastReconciledAt *time.Time `db:"last_reconciled_at" json:"last_reconciled_at,omitempty"`
+	DriftDetectedAt  *time.Time `db:"drift_detected_at" json:"drift_detected_at,omitempty"`
+	ThreadMigratedAt *time.Time `db:"thread_migrated_at" json:"thread_migrated_at,omitempty"`
+	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// ReconciliationItem is a linked alert with its current status
+type ReconciliationItem struct {
+	CaseSyncLink
+	AlertStatus string `db:"alert_status" json:"alert_status"`
+}
+
+// CaseSyncReconciliation summarizes one drift check of a connector
+type CaseSyncReconciliation struct {
+	ID           string     `db:"id" json:"id"`
+	ConnectorID  string     `db:"connector_id" json:"connector_id"`
+	Status       string     `db:"status" json:"status"`
+	Checked      int        `db:"checked" json:"checked"`
+	InSync       int        `db:"in_sync" json:"in_sync"`
+	Drifted      int        `db:"drifted" json:"drifted"`
+	Repaired     int        `db:"repaired" json:"repaired"`
+	Errors       int        `db:"errors" json:"errors"`
+	Drift        DriftList  `db:"drift" json:"drift"`
+	ErrorMessage *string    `db:"error_message" json:"error_message,omitempty"`
+	StartedAt    time.Time  `db:"started_at" json:"started_at"`
+	CompletedAt  *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+// DriftRecord describes one alert whose external case status disagreed
+type DriftRecord struct {
+	AlertID          string `json:"alert_id"`
+	ExternalID       string `json:"external_id"`
+	AlertStatus      string `json:"alert_status"`
+	ExpectedExternal string `json:"expected_external_status"`
+	ObservedExternal string `json:"observed_external_status"`
+	Action           string `json:"action"`
+}
+
+// DriftList implements database/sql/driver.Valuer and sql.Scanner for drift details
+type DriftList []DriftRecord
+
+func (d DriftList) Value() (driver.Value, error) {
+	if d == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(d)
+}
+
+func (d *DriftList) Scan(value interface{}) error {
+	if value == nil {
+		*d = nil
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, d)
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	default:
+		return fmt.Errorf("cannot scan %T into DriftList", value)
+	}
+}
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	// ErrDeadLetterNotFound is returned when a dead letter does not exist
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	// ErrDeadLetterNotPending is returned when replaying or discarding a dead letter that was already handled
+	ErrDeadLetterNotPending = errors.New("dead letter is not pending")
+)
+
+// DeadLetterRepository handles consumed events that failed every processing
+// attempt and were parked on the dead-letter topic
+type DeadLetterRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewDeadLetterRepository creates a new dead letter repository
+func NewDeadLetterRepository(db *sqlx.DB, logger *slog.Logger) *DeadLetterRepository {
+	return &DeadLetterRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Create records a dead letter. A message already recorded for the same
+// source offset is left untouched and false is returned, so retrying a
+// partly failed dead-lettering does not duplicate it.
+func (d *DeadLetterRepository) Create(ctx context.Context, message *DeadLetterMessage) (bool, error) {
+	query := `
+		INSERT INTO dead_letter_messages (
+			id, source_topic, source_partition, source_offset, message_key,
+			payload, headers, error, attempts, status, replay_of, message_time,
+			failed_at, created_at, updated_at
+		) VALUES (
+			:id, :source_topic, :source_partition, :source_offset, :message_key,
+			:payload, :headers, :error, :attempts, :status, :replay_of, :message_time,
+			:failed_at, :created_at, :updated_at
+		)
+		ON CONFLICT (source_topic, source_partition, source_offset) DO NOTHING`
+
+	now := time.Now()
+	message.CreatedAt = now
+	message.UpdatedAt = now
+
+	result, err := d.db.NamedExecContext(ctx, query, message)
+	if err != nil {
+		return false, fmt.Errorf("failed to create dead letter: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// Get retrieves a dead letter by ID
+func (d *DeadLetterRepository) Get(ctx context.Context, id string) (*DeadLetterMessage, error) {
+	var message DeadLetterMessage
+	if err := d.db.GetContext(ctx, &message, `SELECT * FROM dead_letter_messages WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeadLetterNotFound
+		}
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+
+	return &message, nil
+}
+
+// List retrieves dead letters, most recently failed first
+func (d *DeadLetterRepository) List(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetterMessage, error) {
+	query := `
+		SELECT * FROM dead_letter_messages
+		WHERE ($1 = '' OR status = $1)
+		  AND ($2 = '' OR source_topic = $2)
+		ORDER BY failed_at DESC
+		LIMIT $3 OFFSET $4`
+
+	var messages []*DeadLetterMessage
+	if err := d.db.SelectContext(ctx, &messages, query, filter.Status, filter.Topic, filter.Limit, filter.Offset); err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	return messages, nil
+}
+
+// ClaimReplay marks a pending dead letter as replayed before it is
+// republished, so concurrent replays of one message publish it once.
+// Returns ErrDeadLetterNotPending when it was already replayed or discarded.
+func (d *DeadLetterRepository) ClaimReplay(ctx context.Context, id, actor string) (*DeadLetterMessage, error) {
+	query := `
+		UPDATE dead_letter_messages SET
+			status = 'replayed',
+			replay_count = replay_count + 1,
+			replayed_by = $2,
+			replayed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING *`
+
+	var message DeadLetterMessage
+	if err := d.db.GetContext(ctx, &message, query, id, actor); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, d.notPending(ctx, id)
+		}
+		return nil, fmt.Errorf("failed to claim dead letter replay: %w", err)
+	}
+
+	return &message, nil
+}
+
+// ReleaseReplay returns a claimed dead letter to pending after its replay
+// could not be published
+func (d *DeadLetterRepository) ReleaseReplay(ctx context.Context, id string) error {
+	query := `
+		UPDATE dead_letter_messages SET
+			status = 'pending',
+			replay_count = replay_count - 1,
+			replayed_by = NULL,
+			replayed_at = NULL
+		WHERE id = $1 AND status = 'replayed'`
+
+	if _, err := d.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to release dead letter replay: %w", err)
+	}
+
+	return nil
+}
+
+// Discard marks a pending dead letter as deliberately dropped
+func (d *DeadLetterRepository) Discard(ctx context.Context, id, actor string) (*DeadLetterMessage, error) {
+	query := `
+		UPDATE dead_letter_messages SET
+			status = 'discarded',
+			discarded_by = $2,
+			discarded_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING *`
+
+	var message DeadLetterMessage
+	if err := d.db.GetContext(ctx, &message, query, id, actor); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, d.notPending(ctx, id)
+		}
+		return nil, fmt.Errorf("failed to discard dead letter: %w", err)
+	}
+
+	return &message, nil
+}
+
+// notPending tells a missing dead letter apart from one already handled
+func (d *DeadLetterRepository) notPending(ctx context.Context, id string) error {
+	if _, err := d.Get(ctx, id); err != nil {
+		return err
+	}
+	return ErrDeadLetterNotPending
+}
+
+// Dead letter types
+
+// Dead letter statuses
+const (
+	DeadLetterStatusPending   = "pending"
+	DeadLetterStatusReplayed  = "replayed"
+	DeadLetterStatusDiscarded = "discarded"
+)
+
+// DeadLetterMessage is a consumed event that failed every processing attempt
+type DeadLetterMessage struct {
+	ID              string     `db:"id" json:"id"`
+	SourceTopic     string     `db:"source_topic" json:"source_topic"`
+	SourcePartition int        `db:"source_partition" json:"source_partition"`
+	SourceOffset    int64      `db:"source_offset" json:"source_offset"`
+	MessageKey      *string    `db:"message_key" json:"message_key,omitempty"`
+	Payload         []byte     `db:"payload" json:"-"`
+	Headers         JSONB      `db:"headers" json:"headers"`
+	Error           string     `db:"error" json:"error"`
+	Attempts        int        `db:"attempts" json:"attempts"`
+	Status          string     `db:"status" json:"status"`
+	ReplayOf        *string    `db:"replay_of" json:"replay_of,omitempty"`
+	ReplayCount     int        `db:"replay_count" json:"replay_count"`
+	MessageTime     *time.Time `db:"message_time" json:"message_time,omitempty"`
+	FailedAt        time.Time  `db:"failed_at" json:"failed_at"`
+	ReplayedBy      *string    `db:"replayed_by" json:"replayed_by,omitempty"`
+	ReplayedAt      *time.Time `db:"replayed_at" json:"replayed_at,omitempty"`
+	DiscardedBy     *string    `db:"discarded_by" json:"discarded_by,omitempty"`
+	DiscardedAt     *time.Time `db:"discarded_at" json:"discarded_at,omitempty"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// DeadLetterFilter narrows a dead letter listing
+type DeadLetterFilter struct {
+	Status string
+	Topic  string
+	Limit  int
+	Offset int
+}
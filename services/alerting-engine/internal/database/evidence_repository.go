@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrEvidenceNotFound is returned when an alert has no evidence bundle
+var ErrEvidenceNotFound = errors.New("evidence bundle not found")
+
+// EvidenceBundle is the immutable record of the inputs that caused a rule to raise an alert
+type EvidenceBundle struct {
+	ID            string          `db:"id" json:"id"`
+	AlertID       string          `db:"alert_id" json:"alert_id"`
+	RuleID        string          `db:"rule_id" json:"rule_id"`
+	RuleName      string          `db:"rule_name" json:"rule_name"`
+	RuleVersion   int             `db:"rule_version" json:"rule_version"`
+	RuleSnapshot  json.RawMessage `db:"rule_snapshot" json:"rule_snapshot"`
+	TriggerEvents json.RawMessage `db:"trigger_events" json:"trigger_events"`
+	WindowValues  json.RawMessage `db:"window_values" json:"window_values"`
+	EvaluatedAt   time.Time       `db:"evaluated_at" json:"evaluated_at"`
+	ContentHash   string          `db:"content_hash" json:"content_hash"`
+	CreatedAt     time.Time       `db:"created_at" json:"created_at"`
+}
+
+const insertEvidenceBundleQuery = `
+	INSERT INTO alert_evidence_bundles (
+		id, alert_id, rule_id, rule_name, rule_version, rule_snapshot,
+		trigger_events, window_values, evaluated_at, content_hash, created_at
+	) VALUES (
+		:id, :alert_id, :rule_id, :rule_name, :rule_version, :rule_snapshot,
+		:trigger_events, :window_values, :evaluated_at, :content_hash, :created_at
+	)`
+
+// CreateWithEvidence creates an alert and its evidence bundle atomically, so
+// a rule-raised alert is never persisted without the record of why it fired
+func (r *AlertRepository) CreateWithEvidence(ctx context.Context, alert *Alert, bundle *EvidenceBundle) error {
+	alertQuery := `
+		INSERT INTO alerts (
+			id, rule_id, rule_name, type, severity, priority, status,
+			title, description, source, source_event, entity_ids, tags,
+			metadata, fingerprint, correlation_id, parent_alert_id,
+			escalation_level, assigned_to, expires_at, notification_sent,
+			created_at, updated_at
+		) VALUES (
+			:id, :rule_id, :rule_name, :type, :severity, :priority, :status,
+			:title, :description, :source, :source_event, :entity_ids, :tags,
+			:metadata, :fingerprint, :correlation_id, :parent_alert_id,
+			:escalation_level, :assigned_to, :expires_at, :notification_sent,
+			:created_at, :updated_at
+		)`
+
+	now := time.Now()
+	alert.CreatedAt = now
+	alert.UpdatedAt = now
+	bundle.AlertID = alert.ID
+	bundle.CreatedAt = now
+
+	err := r.Transaction(func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, alertQuery, alert); err != nil {
+			return fmt.Errorf("failed to create alert: %w", err)
+		}
+		if _, err := tx.NamedExecContext(ctx, insertEvidenceBundleQuery, bundle); err != nil {
+			return fmt.Errorf("failed to create evidence bundle: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to create alert with evidence", "alert_id", alert.ID, "error", err)
+		return err
+	}
+
+	r.logger.Info("Alert created with evidence",
+		"alert_id", alert.ID,
+		"rule_id", alert.RuleID,
+		"evidence_id", bundle.ID)
+	return nil
+}
+
+// EvidenceRepository handles read access to alert evidence bundles
+type EvidenceRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewEvidenceRepository creates a new evidence repository
+func NewEvidenceRepository(db *sqlx.DB, logger *slog.Logger) *EvidenceRepository {
+	return &EvidenceRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// GetByAlertID retrieves the evidence bundle attached to an alert
+func (e *EvidenceRepository) GetByAlertID(ctx context.Context, alertID string) (*EvidenceBundle, error) {
+	query := `SELECT * FROM alert_evidence_bundles WHERE alert_id = $1`
+
+	var bundle EvidenceBundle
+	if err := e.db.GetContext(ctx, &bundle, query, alertID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEvidenceNotFound
+		}
+		return nil, fmt.Errorf("failed to get evidence bundle: %w", err)
+	}
+
+	return &bundle, nil
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrNotificationBrandingNotFound is returned when a tenant has no notification branding
+var ErrNotificationBrandingNotFound = errors.New("notification branding not found")
+
+// NotificationBranding is how a tenant's notifications present themselves.
+// Templates read it as .brand.
+type NotificationBranding struct {
+	TenantID     string    `db:"tenant_id" json:"tenant_id"`
+	DisplayName  string    `db:"display_name" json:"display_name"`
+	LogoURL      *string   `db:"logo_url" json:"logo_url,omitempty"`
+	PrimaryColor *string   `db:"primary_color" json:"primary_color,omitempty"`
+	SupportEmail *string   `db:"support_email" json:"support_email,omitempty"`
+	Footer       *string   `db:"footer" json:"footer,omitempty"`
+	CreatedBy    string    `db:"created_by" json:"created_by"`
+	UpdatedBy    string    `db:"updated_by" json:"updated_by"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// NotificationBrandingRepository handles per-tenant notification branding
+type NotificationBrandingRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewNotificationBrandingRepository creates a new notification branding repository
+func NewNotificationBrandingRepository(db *sqlx.DB, logger *slog.Logger) *NotificationBrandingRepository {
+	return &NotificationBrandingRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Upsert creates or replaces a tenant's branding. Replacing keeps who
+// created it and when.
+func (r *NotificationBrandingRepository) Upsert(ctx context.Context, branding *NotificationBranding) error {
+	now := time.Now()
+	branding.CreatedAt = now
+	branding.UpdatedAt = now
+
+	query := `
+		INSERT INTO notification_brandings (
+			tenant_id, display_name, logo_url, primary_color, support_email, footer,
+			created_by, updated_by, created_at, updated_at
+		) VALUES (
+			:tenant_id, :display_name, :logo_url, :primary_color, :support_email, :footer,
+			:created_by, :updated_by, :created_at, :updated_at
+		)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			display_name = EXCLUDED.display_name,
+			logo_url = EXCLUDED.logo_url,
+			primary_color = EXCLUDED.primary_color,
+			support_email = EXCLUDED.support_email,
+			footer = EXCLUDED.footer,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING created_by, created_at`
+
+	rows, err := r.db.NamedQueryContext(ctx, query, branding)
+	if err != nil {
+		r.logger.Error("Failed to save notification branding", "tenant_id", branding.TenantID, "error", err)
+		return fmt.Errorf("failed to save notification branding: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.Scan(&branding.CreatedBy, &branding.CreatedAt); err != nil {
+			return fmt.Errorf("failed to read notification branding: %w", err)
+		}
+	}
+
+	r.logger.Info("Notification branding saved",
+		"tenant_id", branding.TenantID,
+		"updated_by", branding.UpdatedBy)
+	return rows.Err()
+}
+
+// GetByTenant retrieves a tenant's branding
+func (r *NotificationBrandingRepository) GetByTenant(ctx context.Context, tenantID string) (*NotificationBranding, error) {
+	var branding NotificationBranding
+	err := r.db.GetContext(ctx, &branding, `SELECT * FROM notification_brandings WHERE tenant_id = $1`, tenantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotificationBrandingNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to get notification branding", "tenant_id", tenantID, "error", err)
+		return nil, fmt.Errorf("failed to get notification branding: %w", err)
+	}
+	return &branding, nil
+}
+
+// List retrieves every tenant's branding by tenant
+func (r *NotificationBrandingRepository) List(ctx context.Context) ([]*NotificationBranding, error) {
+	var brandings []*NotificationBranding
+	if err := r.db.SelectContext(ctx, &brandings, `SELECT * FROM notification_brandings ORDER BY tenant_id`); err != nil {
+		r.logger.Error("Failed to list notification brandings", "error", err)
+		return nil, fmt.Errorf("failed to list notification brandings: %w", err)
+	}
+	return brandings, nil
+}
+
+// Delete removes a tenant's branding, so its notifications carry the default brand
+func (r *NotificationBrandingRepository) Delete(ctx context.Context, tenantID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM notification_brandings WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		r.logger.Error("Failed to delete notification branding", "tenant_id", tenantID, "error", err)
+		return fmt.Errorf("failed to delete notification branding: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotificationBrandingNotFound
+	}
+
+	r.logger.Info("Notification branding deleted", "tenant_id", tenantID)
+	return nil
+}
@@ -2,6 +2,8 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -11,6 +13,62 @@ import (
 	"github.com/lib/pq"
 )
 
+var (
+	// ErrNotificationRouteNotFound is returned when a notification route does not exist
+	ErrNotificationRouteNotFound = errors.New("notification route not found")
+	// ErrNotificationRouteExists is returned when a rule already routes to the
+	// same recipient on the same channel
+	ErrNotificationRouteExists = errors.New("notification route already exists for this rule, channel and recipient")
+)
+
+// DeliveryStatusSent marks a notification the channel provider accepted;
+// failed deliveries share DeliveryStatusFailed with case sync
+const DeliveryStatusSent = "sent"
+
+// NotificationRoute sends a rule's notifications to one recipient on one
+// channel. An empty recipient uses the channel provider's default; a minimum
+// severity limits the route to alerts at or above it.
+type NotificationRoute struct {
+	ID          string    `db:"id" json:"id"`
+	RuleID      string    `db:"rule_id" json:"rule_id"`
+	Channel     string    `db:"channel" json:"channel"`
+	Recipient   string    `db:"recipient" json:"recipient"`
+	MinSeverity *string   `db:"min_severity" json:"min_severity,omitempty"`
+	Enabled     bool      `db:"enabled" json:"enabled"`
+	CreatedBy   string    `db:"created_by" json:"created_by"`
+	UpdatedBy   string    `db:"updated_by" json:"updated_by"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// NotificationDelivery records one attempt to send a notification through a
+// channel provider
+type NotificationDelivery struct {
+	ID             string    `db:"id" json:"id"`
+	NotificationID *string   `db:"notification_id" json:"notification_id,omitempty"`
+	RuleID         *string   `db:"rule_id" json:"rule_id,omitempty"`
+	AlertID        *string   `db:"alert_id" json:"alert_id,omitempty"`
+	RouteID        *string   `db:"route_id" json:"route_id,omitempty"`
+	Channel        string    `db:"channel" json:"channel"`
+	Recipient      string    `db:"recipient" json:"recipient"`
+	Status         string    `db:"status" json:"status"`
+	ResponseCode   *int      `db:"response_code" json:"response_code,omitempty"`
+	ExternalID     *string   `db:"external_id" json:"external_id,omitempty"`
+	ProviderStatus *string   `db:"provider_status" json:"provider_status,omitempty"`
+	Error          *string   `db:"error" json:"error,omitempty"`
+	LatencyMs      int64     `db:"latency_ms" json:"latency_ms"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// NotificationDeliveryFilter narrows a delivery listing; empty fields match everything
+type NotificationDeliveryFilter struct {
+	RuleID  string
+	AlertID string
+	Channel string
+	Status  string
+	Limit   int
+}
+
 // NotificationRepository handles notification data operations
 type NotificationRepository struct {
 	BaseRepository
@@ -563,6 +621,153 @@ func (n *NotificationRepository) buildLimitClause(filter Filter, argIndex *int,
 	return limitClause
 }
 
+// Notification routing
+
+// CreateRoute creates a notification route for a rule
+func (n *NotificationRepository) CreateRoute(ctx context.Context, route *NotificationRoute) error {
+	now := time.Now()
+	route.CreatedAt = now
+	route.UpdatedAt = now
+
+	_, err := n.db.NamedExecContext(ctx, `
+		INSERT INTO notification_routes (
+			id, rule_id, channel, recipient, min_severity, enabled,
+			created_by, updated_by, created_at, updated_at
+		) VALUES (
+			:id, :rule_id, :channel, :recipient, :min_severity, :enabled,
+			:created_by, :updated_by, :created_at, :updated_at
+		)`, route)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return ErrNotificationRouteExists
+		}
+		return fmt.Errorf("failed to create notification route: %w", err)
+	}
+
+	return nil
+}
+
+// GetRoute retrieves a notification route by ID
+func (n *NotificationRepository) GetRoute(ctx context.Context, id string) (*NotificationRoute, error) {
+	var route NotificationRoute
+	err := n.db.GetContext(ctx, &route, `SELECT * FROM notification_routes WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotificationRouteNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification route: %w", err)
+	}
+
+	return &route, nil
+}
+
+// ListRoutes retrieves a rule's notification routes
+func (n *NotificationRepository) ListRoutes(ctx context.Context, ruleID string) ([]*NotificationRoute, error) {
+	routes := []*NotificationRoute{}
+	err := n.db.SelectContext(ctx, &routes, `
+		SELECT * FROM notification_routes
+		WHERE rule_id = $1
+		ORDER BY channel, recipient`, ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification routes: %w", err)
+	}
+
+	return routes, nil
+}
+
+// UpdateRoute updates a notification route's recipient, severity and state
+func (n *NotificationRepository) UpdateRoute(ctx context.Context, route *NotificationRoute) error {
+	route.UpdatedAt = time.Now()
+
+	result, err := n.db.NamedExecContext(ctx, `
+		UPDATE notification_routes SET
+			recipient = :recipient,
+			min_severity = :min_severity,
+			enabled = :enabled,
+			updated_by = :updated_by,
+			updated_at = :updated_at
+		WHERE id = :id`, route)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return ErrNotificationRouteExists
+		}
+		return fmt.Errorf("failed to update notification route: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotificationRouteNotFound
+	}
+
+	return nil
+}
+
+// DeleteRoute deletes a notification route
+func (n *NotificationRepository) DeleteRoute(ctx context.Context, id string) error {
+	result, err := n.db.ExecContext(ctx, `DELETE FROM notification_routes WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete notification route: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotificationRouteNotFound
+	}
+
+	return nil
+}
+
+// RecordDelivery records a delivery attempt through a channel provider
+func (n *NotificationRepository) RecordDelivery(ctx context.Context, delivery *NotificationDelivery) error {
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now()
+	}
+
+	_, err := n.db.NamedExecContext(ctx, `
+		INSERT INTO notification_deliveries (
+			id, notification_id, rule_id, alert_id, route_id, channel, recipient,
+			status, response_code, external_id, provider_status, error,
+			latency_ms, created_at
+		) VALUES (
+			:id, :notification_id, :rule_id, :alert_id, :route_id, :channel, :recipient,
+			:status, :response_code, :external_id, :provider_status, :error,
+			:latency_ms, :created_at
+		)`, delivery)
+	if err != nil {
+		return fmt.Errorf("failed to record notification delivery: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeliveries retrieves delivery attempts, newest first
+func (n *NotificationRepository) ListDeliveries(ctx context.Context, filter NotificationDeliveryFilter) ([]*NotificationDelivery, error) {
+	query := `
+		SELECT * FROM notification_deliveries
+		WHERE ($1 = '' OR rule_id = $1)
+		  AND ($2 = '' OR alert_id = $2)
+		  AND ($3 = '' OR channel = $3)
+		  AND ($4 = '' OR status = $4)
+		ORDER BY created_at DESC
+		LIMIT $5`
+
+	deliveries := []*NotificationDelivery{}
+	if err := n.db.SelectContext(ctx, &deliveries, query,
+		filter.RuleID, filter.AlertID, filter.Channel, filter.Status, filter.Limit); err != nil {
+		return nil, fmt.Errorf("failed to list notification deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
 // Additional types for statistics
 
 type NotificationStats struct {
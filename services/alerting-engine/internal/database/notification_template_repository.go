@@ -0,0 +1,285 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNotificationTemplateNotFound is returned when a notification template or version does not exist
+	ErrNotificationTemplateNotFound = errors.New("notification template not found")
+	// ErrNotificationTemplateExists is returned when a channel already has a
+	// default template, or a rule already has an override for the channel
+	ErrNotificationTemplateExists = errors.New("notification template already exists for this channel and rule")
+	// ErrNotificationTemplateStale is returned when a template gained a version
+	// after the one a change was based on
+	ErrNotificationTemplateStale = errors.New("notification template was changed concurrently")
+)
+
+// Notification template version change types
+const (
+	TemplateChangeCreated    = "created"
+	TemplateChangeUpdated    = "updated"
+	TemplateChangeRolledBack = "rolled_back"
+)
+
+// NotificationTemplate renders notifications for one channel. Without a rule
+// it is the channel's default; with one it overrides the default for that rule.
+// Subject and body are those of the current version.
+type NotificationTemplate struct {
+	ID             string    `db:"id" json:"id"`
+	Name           string    `db:"name" json:"name"`
+	Channel        string    `db:"channel" json:"channel"`
+	RuleID         *string   `db:"rule_id" json:"rule_id,omitempty"`
+	Enabled        bool      `db:"enabled" json:"enabled"`
+	CurrentVersion int       `db:"current_version" json:"current_version"`
+	Subject        string    `db:"subject" json:"subject"`
+	Body           string    `db:"body" json:"body"`
+	CreatedBy      string    `db:"created_by" json:"created_by"`
+	UpdatedBy      string    `db:"updated_by" json:"updated_by"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// NotificationTemplateVersion is an immutable copy of a template's subject and body
+type NotificationTemplateVersion struct {
+	TemplateID    string    `db:"template_id" json:"template_id"`
+	Version       int       `db:"version" json:"version"`
+	Subject       string    `db:"subject" json:"subject"`
+	Body          string    `db:"body" json:"body"`
+	ChangeType    string    `db:"change_type" json:"change_type"`
+	SourceVersion *int      `db:"source_version" json:"source_version,omitempty"` // version a rollback restored
+	ChangeNote    *string   `db:"change_note" json:"change_note,omitempty"`
+	CreatedBy     string    `db:"created_by" json:"created_by"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+const selectNotificationTemplateQuery = `
+	SELECT t.id, t.name, t.channel, t.rule_id, t.enabled, t.current_version,
+		v.subject, v.body, t.created_by, t.updated_by, t.created_at, t.updated_at
+	FROM notification_templates t
+	JOIN notification_template_versions v
+		ON v.template_id = t.id AND v.version = t.current_version`
+
+const insertNotificationTemplateVersionQuery = `
+	INSERT INTO notification_template_versions (
+		template_id, version, subject, body, change_type, source_version,
+		change_note, created_by, created_at
+	) VALUES (
+		:template_id, :version, :subject, :body, :change_type, :source_version,
+		:change_note, :created_by, :created_at
+	)`
+
+// NotificationTemplateRepository handles notification templates and their version history
+type NotificationTemplateRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewNotificationTemplateRepository creates a new notification template repository
+func NewNotificationTemplateRepository(db *sqlx.DB, logger *slog.Logger) *NotificationTemplateRepository {
+	return &NotificationTemplateRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Create creates a template with its first version
+func (r *NotificationTemplateRepository) Create(ctx context.Context, template *NotificationTemplate, version *NotificationTemplateVersion) error {
+	now := time.Now()
+	template.CurrentVersion = 1
+	template.CreatedAt = now
+	template.UpdatedAt = now
+	version.TemplateID = template.ID
+	version.Version = 1
+	version.CreatedAt = now
+
+	err := r.Transaction(func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, `
+			INSERT INTO notification_templates (
+				id, name, channel, rule_id, enabled, current_version,
+				created_by, updated_by, created_at, updated_at
+			) VALUES (
+				:id, :name, :channel, :rule_id, :enabled, :current_version,
+				:created_by, :updated_by, :created_at, :updated_at
+			)`, template); err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+				return ErrNotificationTemplateExists
+			}
+			return fmt.Errorf("failed to create notification template: %w", err)
+		}
+
+		if _, err := tx.NamedExecContext(ctx, insertNotificationTemplateVersionQuery, version); err != nil {
+			return fmt.Errorf("failed to record notification template version: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrNotificationTemplateExists) {
+			r.logger.Error("Failed to create notification template", "template_id", template.ID, "error", err)
+		}
+		return err
+	}
+
+	template.Subject = version.Subject
+	template.Body = version.Body
+	r.logger.Info("Notification template created",
+		"template_id", template.ID,
+		"channel", template.Channel,
+		"created_by", template.CreatedBy)
+	return nil
+}
+
+// AddVersion records a new version of a template and makes it current. The
+// version number must follow the template's current version; otherwise
+// ErrNotificationTemplateStale is returned and nothing is written. A non-nil
+// enabled also switches the template on or off.
+func (r *NotificationTemplateRepository) AddVersion(ctx context.Context, version *NotificationTemplateVersion, enabled *bool) error {
+	version.CreatedAt = time.Now()
+
+	err := r.Transaction(func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE notification_templates SET
+				current_version = $2,
+				enabled = COALESCE($3, enabled),
+				updated_by = $4
+			WHERE id = $1 AND current_version = $2 - 1`,
+			version.TemplateID, version.Version, enabled, version.CreatedBy)
+		if err != nil {
+			return fmt.Errorf("failed to update notification template: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return ErrNotificationTemplateStale
+		}
+
+		if _, err := tx.NamedExecContext(ctx, insertNotificationTemplateVersionQuery, version); err != nil {
+			return fmt.Errorf("failed to record notification template version: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrNotificationTemplateStale) {
+			r.logger.Error("Failed to add notification template version",
+				"template_id", version.TemplateID,
+				"version", version.Version,
+				"error", err)
+		}
+		return err
+	}
+
+	r.logger.Info("Notification template version added",
+		"template_id", version.TemplateID,
+		"version", version.Version,
+		"change_type", version.ChangeType,
+		"created_by", version.CreatedBy)
+	return nil
+}
+
+// GetByID retrieves a template with the subject and body of its current version
+func (r *NotificationTemplateRepository) GetByID(ctx context.Context, id string) (*NotificationTemplate, error) {
+	var template NotificationTemplate
+	err := r.db.GetContext(ctx, &template, selectNotificationTemplateQuery+` WHERE t.id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotificationTemplateNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to get notification template", "template_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get notification template: %w", err)
+	}
+	return &template, nil
+}
+
+// List retrieves templates, optionally for one channel and rule, defaults first
+func (r *NotificationTemplateRepository) List(ctx context.Context, channel, ruleID string) ([]*NotificationTemplate, error) {
+	var conditions []string
+	var args []interface{}
+	if channel != "" {
+		args = append(args, channel)
+		conditions = append(conditions, fmt.Sprintf("t.channel = $%d", len(args)))
+	}
+	if ruleID != "" {
+		args = append(args, ruleID)
+		conditions = append(conditions, fmt.Sprintf("t.rule_id = $%d", len(args)))
+	}
+
+	query := selectNotificationTemplateQuery
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY t.channel, t.rule_id NULLS FIRST, t.name"
+
+	var templates []*NotificationTemplate
+	if err := r.db.SelectContext(ctx, &templates, query, args...); err != nil {
+		r.logger.Error("Failed to list notification templates", "error", err)
+		return nil, fmt.Errorf("failed to list notification templates: %w", err)
+	}
+	return templates, nil
+}
+
+// Resolve retrieves the enabled template notifications for a rule on a
+// channel are rendered from: the rule's override if it has one, otherwise the
+// channel default. It returns ErrNotificationTemplateNotFound when neither exists.
+func (r *NotificationTemplateRepository) Resolve(ctx context.Context, channel, ruleID string) (*NotificationTemplate, error) {
+	query := selectNotificationTemplateQuery + `
+		WHERE t.channel = $1 AND t.enabled
+		AND (t.rule_id IS NULL OR t.rule_id = $2)
+		ORDER BY t.rule_id NULLS LAST
+		LIMIT 1`
+
+	var template NotificationTemplate
+	err := r.db.GetContext(ctx, &template, query, channel, ruleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotificationTemplateNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to resolve notification template", "channel", channel, "rule_id", ruleID, "error", err)
+		return nil, fmt.Errorf("failed to resolve notification template: %w", err)
+	}
+	return &template, nil
+}
+
+// GetVersion retrieves one version of a template
+func (r *NotificationTemplateRepository) GetVersion(ctx context.Context, templateID string, version int) (*NotificationTemplateVersion, error) {
+	var templateVersion NotificationTemplateVersion
+	err := r.db.GetContext(ctx, &templateVersion, `
+		SELECT * FROM notification_template_versions
+		WHERE template_id = $1 AND version = $2`, templateID, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotificationTemplateNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to get notification template version",
+			"template_id", templateID,
+			"version", version,
+			"error", err)
+		return nil, fmt.Errorf("failed to get notification template version: %w", err)
+	}
+	return &templateVersion, nil
+}
+
+// ListVersions retrieves every version of a template, newest first
+func (r *NotificationTemplateRepository) ListVersions(ctx context.Context, templateID string) ([]*NotificationTemplateVersion, error) {
+	var versions []*NotificationTemplateVersion
+	err := r.db.SelectContext(ctx, &versions, `
+		SELECT * FROM notification_template_versions
+		WHERE template_id = $1
+		ORDER BY version DESC`, templateID)
+	if err != nil {
+		r.logger.Error("Failed to list notification template versions", "template_id", templateID, "error", err)
+		return nil, fmt.Errorf("failed to list notification template versions: %w", err)
+	}
+	return versions, nil
+}
@@ -0,0 +1,384 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrSubscriberNotFound is returned when a risk webhook subscriber does not exist
+	ErrSubscriberNotFound = errors.New("risk webhook subscriber not found")
+	// ErrRiskDeliveryNotRetryable is returned when a delivery does not exist or has not failed
+	ErrRiskDeliveryNotRetryable = errors.New("risk webhook delivery not found or not retryable")
+)
+
+// RiskWebhookRepository handles risk webhook subscribers, the risk tier
+// transition log and the delivery outbox
+type RiskWebhookRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewRiskWebhookRepository creates a new risk webhook repository
+func NewRiskWebhookRepository(db *sqlx.DB, logger *slog.Logger) *RiskWebhookRepository {
+	return &RiskWebhookRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Subscriber operations
+
+// CreateSubscriber registers a downstream system for risk tier transitions
+func (r *RiskWebhookRepository) CreateSubscriber(ctx context.Context, subscriber *RiskWebhookSubscriber) error {
+	query := `
+		INSERT INTO risk_webhook_subscribers (
+			id, name, endpoint_url, enabled, tiers, signing_secret, max_attempts,
+			created_by, created_at, updated_at
+		) VALUES (
+			:id, :name, :endpoint_url, :enabled, :tiers, :signing_secret, :max_attempts,
+			:created_by, :created_at, :updated_at
+		)`
+
+	now := time.Now()
+	subscriber.CreatedAt = now
+	subscriber.UpdatedAt = now
+
+	if _, err := r.db.NamedExecContext(ctx, query, subscriber); err != nil {
+		r.logger.Error("Failed to create risk webhook subscriber", "name", subscriber.Name, "error", err)
+		return fmt.Errorf("failed to create risk webhook subscriber: %w", err)
+	}
+
+	r.logger.Info("Risk webhook subscriber created", "subscriber_id", subscriber.ID, "name", subscriber.Name)
+	return nil
+}
+
+// GetSubscriber retrieves a subscriber by ID
+func (r *RiskWebhookRepository) GetSubscriber(ctx context.Context, id string) (*RiskWebhookSubscriber, error) {
+	var subscriber RiskWebhookSubscriber
+	err := r.db.GetContext(ctx, &subscriber, `SELECT * FROM risk_webhook_subscribers WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSubscriberNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get risk webhook subscriber: %w", err)
+	}
+
+	return &subscriber, nil
+}
+
+// ListSubscribers retrieves all subscribers
+func (r *RiskWebhookRepository) ListSubscribers(ctx context.Context) ([]*RiskWebhookSubscriber, error) {
+	var subscribers []*RiskWebhookSubscriber
+	if err := r.db.SelectContext(ctx, &subscribers, `SELECT * FROM risk_webhook_subscribers ORDER BY name`); err != nil {
+		return nil, fmt.Errorf("failed to list risk webhook subscribers: %w", err)
+	}
+
+	return subscribers, nil
+}
+
+// UpdateSubscriber stores a subscriber's configuration and signing secret
+func (r *RiskWebhookRepository) UpdateSubscriber(ctx context.Context, subscriber *RiskWebhookSubscriber) error {
+	query := `
+		UPDATE risk_webhook_subscribers SET
+			name = :name,
+			endpoint_url = :endpoint_url,
+			enabled = :enabled,
+			tiers = :tiers,
+			signing_secret = :signing_secret,
+			max_attempts = :max_attempts,
+			updated_by = :updated_by
+		WHERE id = :id`
+
+	result, err := r.db.NamedExecContext(ctx, query, subscriber)
+	if err != nil {
+		return fmt.Errorf("failed to update risk webhook subscriber: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrSubscriberNotFound
+	}
+
+	return nil
+}
+
+// DeleteSubscriber removes a subscriber together with its outbox
+func (r *RiskWebhookRepository) DeleteSubscriber(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM risk_webhook_subscribers WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete risk webhook subscriber: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrSubscriberNotFound
+	}
+
+	r.logger.Info("Risk webhook subscriber deleted", "subscriber_id", id)
+	return nil
+}
+
+// Transition operations
+
+// ListTransitions retrieves logged risk tier transitions, optionally of one
+// entity, newest first
+func (r *RiskWebhookRepository) ListTransitions(ctx context.Context, entityID string, limit int) ([]*RiskTierTransition, error) {
+	query := `
+		SELECT * FROM entity_risk_tier_transitions
+		WHERE ($1 = '' OR entity_id = $1)
+		ORDER BY id DESC
+		LIMIT $2`
+
+	var transitions []*RiskTierTransition
+	if err := r.db.SelectContext(ctx, &transitions, query, entityID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list risk tier transitions: %w", err)
+	}
+
+	return transitions, nil
+}
+
+// GetTransitions retrieves transitions by ID
+func (r *RiskWebhookRepository) GetTransitions(ctx context.Context, ids []int64) (map[int64]*RiskTierTransition, error) {
+	var transitions []*RiskTierTransition
+	query := `SELECT * FROM entity_risk_tier_transitions WHERE id = ANY($1)`
+	if err := r.db.SelectContext(ctx, &transitions, query, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to get risk tier transitions: %w", err)
+	}
+
+	byID := make(map[int64]*RiskTierTransition, len(transitions))
+	for _, transition := range transitions {
+		byID[transition.ID] = transition
+	}
+	return byID, nil
+}
+
+// Delivery operations
+
+// ClaimDueDeliveries leases deliveries that are due for an attempt. A delivery
+// is only claimed once every earlier undelivered transition of the same entity
+// has been sent to that subscriber, so subscribers see each entity's tiers in order.
+func (r *RiskWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*RiskWebhookDelivery, error) {
+	query := `
+		UPDATE risk_webhook_deliveries SET next_attempt_at = NOW() + $2::interval
+		WHERE id IN (
+			SELECT d.id FROM risk_webhook_deliveries d
+			WHERE d.status IN ('pending', 'failed')
+			  AND d.next_attempt_at <= NOW()
+			  AND NOT EXISTS (
+				SELECT 1 FROM risk_webhook_deliveries p
+				WHERE p.subscriber_id = d.subscriber_id
+				  AND p.entity_id = d.entity_id
+				  AND p.status IN ('pending', 'failed')
+				  AND p.transition_id < d.transition_id
+			  )
+			ORDER BY d.transition_id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	var deliveries []*RiskWebhookDelivery
+	if err := r.db.SelectContext(ctx, &deliveries, query, limit, fmt.Sprintf("%d seconds", int(lease.Seconds()))); err != nil {
+		return nil, fmt.Errorf("failed to claim risk webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// MarkDelivered records a successful delivery
+func (r *RiskWebhookRepository) MarkDelivered(ctx context.Context, deliveryID string, responseCode int) error {
+	query := `
+		UPDATE risk_webhook_deliveries SET
+			status = 'delivered',
+			attempts = attempts + 1,
+			response_code = $2,
+			last_error = NULL,
+			delivered_at = NOW()
+		WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, deliveryID, responseCode); err != nil {
+		return fmt.Errorf("failed to mark risk webhook delivery delivered: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed attempt; dead deliveries are no longer retried
+func (r *RiskWebhookRepository) MarkFailed(ctx context.Context, deliveryID string, responseCode *int, lastError string, nextAttemptAt time.Time, dead bool) error {
+	status := DeliveryStatusFailed
+	if dead {
+		status = DeliveryStatusDead
+	}
+
+	query := `
+		UPDATE risk_webhook_deliveries SET
+			status = $2,
+			attempts = attempts + 1,
+			response_code = $3,
+			last_error = $4,
+			next_attempt_at = $5
+		WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, deliveryID, status, responseCode, lastError, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to mark risk webhook delivery failed: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeliveries retrieves deliveries filtered by subscriber, entity and status, newest first
+func (r *RiskWebhookRepository) ListDeliveries(ctx context.Context, subscriberID, entityID, status string, limit int) ([]*RiskWebhookDelivery, error) {
+	query := `
+		SELECT * FROM risk_webhook_deliveries
+		WHERE ($1 = '' OR subscriber_id = $1)
+		  AND ($2 = '' OR entity_id = $2)
+		  AND ($3 = '' OR status = $3)
+		ORDER BY transition_id DESC
+		LIMIT $4`
+
+	var deliveries []*RiskWebhookDelivery
+	if err := r.db.SelectContext(ctx, &deliveries, query, subscriberID, entityID, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to list risk webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RetryDelivery requeues a failed or dead delivery for immediate delivery
+func (r *RiskWebhookRepository) RetryDelivery(ctx context.Context, id string) error {
+	query := `
+		UPDATE risk_webhook_deliveries SET
+			status = 'pending',
+			attempts = 0,
+			next_attempt_at = NOW()
+		WHERE id = $1 AND status IN ('failed', 'dead')`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to retry risk webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrRiskDeliveryNotRetryable
+	}
+
+	return nil
+}
+
+// CountReplayable counts the transitions a replay over [from, to) would
+// requeue for a subscriber
+func (r *RiskWebhookRepository) CountReplayable(ctx context.Context, subscriber *RiskWebhookSubscriber, from, to time.Time, redeliver bool) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM entity_risk_tier_transitions t
+		LEFT JOIN risk_webhook_deliveries d
+		  ON d.subscriber_id = $1 AND d.transition_id = t.id
+		WHERE t.occurred_at >= $2 AND t.occurred_at < $3
+		  AND (COALESCE(cardinality($4::TEXT[]), 0) = 0 OR t.previous_tier = ANY($4) OR t.tier = ANY($4))
+		  AND (d.id IS NULL OR d.status <> 'delivered' OR $5::BOOLEAN)`
+
+	var count int
+	err := r.db.GetContext(ctx, &count, query, subscriber.ID, from, to, subscriber.Tiers, redeliver)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count replayable risk tier transitions: %w", err)
+	}
+
+	return count, nil
+}
+
+// Replay requeues the transitions of [from, to) a subscriber is interested in.
+// Transitions it never received are enqueued and undelivered ones restarted;
+// delivered ones are only sent again with redeliver. Deliveries keep their
+// id, so subscribers can use it to discard duplicates.
+func (r *RiskWebhookRepository) Replay(ctx context.Context, subscriber *RiskWebhookSubscriber, from, to time.Time, redeliver bool) (int, error) {
+	query := `
+		INSERT INTO risk_webhook_deliveries (id, subscriber_id, transition_id, entity_id, replayed_at)
+		SELECT 'rwd_' || md5($1 || ':' || t.id::text), $1, t.id, t.entity_id, NOW()
+		FROM entity_risk_tier_transitions t
+		WHERE t.occurred_at >= $2 AND t.occurred_at < $3
+		  AND (COALESCE(cardinality($4::TEXT[]), 0) = 0 OR t.previous_tier = ANY($4) OR t.tier = ANY($4))
+		ON CONFLICT (subscriber_id, transition_id) DO UPDATE SET
+			status = 'pending',
+			attempts = 0,
+			next_attempt_at = NOW(),
+			last_error = NULL,
+			replayed_at = NOW()
+		WHERE risk_webhook_deliveries.status <> 'delivered' OR $5::BOOLEAN`
+
+	result, err := r.db.ExecContext(ctx, query, subscriber.ID, from, to, subscriber.Tiers, redeliver)
+	if err != nil {
+		r.logger.Error("Failed to replay risk tier transitions", "subscriber_id", subscriber.ID, "error", err)
+		return 0, fmt.Errorf("failed to replay risk tier transitions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// Risk webhook types
+
+// RiskWebhookSubscriber is a downstream system sent signed risk tier transitions
+type RiskWebhookSubscriber struct {
+	ID            string         `db:"id" json:"id"`
+	Name          string         `db:"name" json:"name"`
+	EndpointURL   string         `db:"endpoint_url" json:"endpoint_url"`
+	Enabled       bool           `db:"enabled" json:"enabled"`
+	Tiers         pq.StringArray `db:"tiers" json:"tiers"` // empty subscribes to every transition
+	SigningSecret string         `db:"signing_secret" json:"-"`
+	MaxAttempts   int            `db:"max_attempts" json:"max_attempts"`
+	CreatedBy     string         `db:"created_by" json:"created_by"`
+	UpdatedBy     *string        `db:"updated_by" json:"updated_by,omitempty"`
+	CreatedAt     time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// RiskTierTransition is one logged change of an entity's risk tier. Tier is
+// nil when the entity's risk tier was removed, PreviousTier when it was first set.
+type RiskTierTransition struct {
+	ID           int64     `db:"id" json:"id"`
+	EntityID     string    `db:"entity_id" json:"entity_id"`
+	PreviousTier *string   `db:"previous_tier" json:"previous_tier,omitempty"`
+	Tier         *string   `db:"tier" json:"tier,omitempty"`
+	Score        *float64  `db:"score" json:"score,omitempty"`
+	Reason       *string   `db:"reason" json:"reason,omitempty"`
+	ChangedBy    *string   `db:"changed_by" json:"changed_by,omitempty"`
+	OccurredAt   time.Time `db:"occurred_at" json:"occurred_at"`
+}
+
+// RiskWebhookDelivery is one risk tier transition queued for a subscriber
+type RiskWebhookDelivery struct {
+	ID            string     `db:"id" json:"id"`
+	SubscriberID  string     `db:"subscriber_id" json:"subscriber_id"`
+	TransitionID  int64      `db:"transition_id" json:"transition_id"`
+	EntityID      string     `db:"entity_id" json:"entity_id"`
+	Status        string     `db:"status" json:"status"`
+	Attempts      int        `db:"attempts" json:"attempts"`
+	NextAttemptAt time.Time  `db:"next_attempt_at" json:"next_attempt_at"`
+	LastError     *string    `db:"last_error" json:"last_error,omitempty"`
+	ResponseCode  *int       `db:"response_code" json:"response_code,omitempty"`
+	DeliveredAt   *time.Time `db:"delivered_at" json:"delivered_at,omitempty"`
+	ReplayedAt    *time.Time `db:"replayed_at" json:"replayed_at,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+}
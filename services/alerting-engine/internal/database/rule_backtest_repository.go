@@ -0,0 +1,198 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ErrBacktestNotFound is returned when a backtest does not exist
+var ErrBacktestNotFound = errors.New("backtest not found")
+
+// Backtest statuses
+const (
+	BacktestStatusRunning   = "running"
+	BacktestStatusCompleted = "completed"
+	BacktestStatusFailed    = "failed"
+)
+
+// RuleBacktest is one replay of a historical event window against a draft rule
+type RuleBacktest struct {
+	ID           string          `db:"id" json:"id"`
+	RuleID       *string         `db:"rule_id" json:"rule_id,omitempty"`
+	RuleName     string          `db:"rule_name" json:"rule_name"`
+	Definition   json.RawMessage `db:"definition" json:"definition"`
+	WindowStart  time.Time       `db:"window_start" json:"window_start"`
+	WindowEnd    time.Time       `db:"window_end" json:"window_end"`
+	SampleSize   int             `db:"sample_size" json:"sample_size"`
+	Status       string          `db:"status" json:"status"`
+	Result       json.RawMessage `db:"result" json:"result,omitempty"`
+	ErrorMessage *string         `db:"error_message" json:"error_message,omitempty"`
+	RequestedBy  string          `db:"requested_by" json:"requested_by"`
+	StartedAt    time.Time       `db:"started_at" json:"started_at"`
+	CompletedAt  *time.Time      `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+// RuleBacktestRepository handles backtest records
+type RuleBacktestRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewRuleBacktestRepository creates a new rule backtest repository
+func NewRuleBacktestRepository(db *sqlx.DB, logger *slog.Logger) *RuleBacktestRepository {
+	return &RuleBacktestRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Create records a backtest that has started running
+func (r *RuleBacktestRepository) Create(ctx context.Context, backtest *RuleBacktest) error {
+	query := `
+		INSERT INTO rule_backtests (
+			id, rule_id, rule_name, definition, window_start, window_end,
+			sample_size, status, requested_by, started_at
+		) VALUES (
+			:id, :rule_id, :rule_name, :definition, :window_start, :window_end,
+			:sample_size, :status, :requested_by, :started_at
+		)`
+
+	backtest.Status = BacktestStatusRunning
+	backtest.StartedAt = time.Now()
+
+	if _, err := r.db.NamedExecContext(ctx, query, backtest); err != nil {
+		r.logger.Error("Failed to create backtest", "backtest_id", backtest.ID, "error", err)
+		return fmt.Errorf("failed to create backtest: %w", err)
+	}
+
+	return nil
+}
+
+// Complete stores the result of a finished backtest
+func (r *RuleBacktestRepository) Complete(ctx context.Context, id string, result json.RawMessage) error {
+	query := `
+		UPDATE rule_backtests SET
+			status = $2,
+			result = $3,
+			completed_at = $4
+		WHERE id = $1 AND status = $5`
+
+	if _, err := r.db.ExecContext(ctx, query, id, BacktestStatusCompleted, result, time.Now(), BacktestStatusRunning); err != nil {
+		return fmt.Errorf("failed to complete backtest: %w", err)
+	}
+
+	return nil
+}
+
+// Fail marks a running backtest as failed
+func (r *RuleBacktestRepository) Fail(ctx context.Context, id, message string) error {
+	query := `
+		UPDATE rule_backtests SET
+			status = $2,
+			error_message = $3,
+			completed_at = $4
+		WHERE id = $1 AND status = $5`
+
+	if _, err := r.db.ExecContext(ctx, query, id, BacktestStatusFailed, message, time.Now(), BacktestStatusRunning); err != nil {
+		return fmt.Errorf("failed to fail backtest: %w", err)
+	}
+
+	return nil
+}
+
+// FailRunning marks every running backtest as failed. Backtests run in the
+// process that started them, so at startup any still running were interrupted.
+func (r *RuleBacktestRepository) FailRunning(ctx context.Context, message string) (int, error) {
+	query := `
+		UPDATE rule_backtests SET
+			status = $1,
+			error_message = $2,
+			completed_at = $3
+		WHERE status = $4`
+
+	result, err := r.db.ExecContext(ctx, query, BacktestStatusFailed, message, time.Now(), BacktestStatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail running backtests: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// Get retrieves a backtest by ID
+func (r *RuleBacktestRepository) Get(ctx context.Context, id string) (*RuleBacktest, error) {
+	var backtest RuleBacktest
+	if err := r.db.GetContext(ctx, &backtest, `SELECT * FROM rule_backtests WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBacktestNotFound
+		}
+		return nil, fmt.Errorf("failed to get backtest: %w", err)
+	}
+
+	return &backtest, nil
+}
+
+// List retrieves recent backtests, optionally of one stored rule, without their results
+func (r *RuleBacktestRepository) List(ctx context.Context, ruleID string, limit int) ([]*RuleBacktest, error) {
+	query := `
+		SELECT id, rule_id, rule_name, definition, window_start, window_end,
+			   sample_size, status, error_message, requested_by, started_at, completed_at
+		FROM rule_backtests
+		WHERE ($1 = '' OR rule_id = $1)
+		ORDER BY started_at DESC
+		LIMIT $2`
+
+	var backtests []*RuleBacktest
+	if err := r.db.SelectContext(ctx, &backtests, query, ruleID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list backtests: %w", err)
+	}
+
+	return backtests, nil
+}
+
+// CountResolutionsByRule counts the alerts of each rule resolved since a time,
+// by resolution reason, so backtests can weigh overlap by how often those
+// rules' alerts turned out to be false positives
+func (r *AlertRepository) CountResolutionsByRule(ctx context.Context, ruleIDs []string, since time.Time) (map[string]map[string]int, error) {
+	query := `
+		SELECT rule_id, resolution_reason, COUNT(*) AS count
+		FROM alerts
+		WHERE rule_id = ANY($1)
+		AND status = 'resolved'
+		AND resolution_reason IS NOT NULL
+		AND resolved_at >= $2
+		AND deleted_at IS NULL
+		GROUP BY rule_id, resolution_reason`
+
+	var rows []struct {
+		RuleID           string `db:"rule_id"`
+		ResolutionReason string `db:"resolution_reason"`
+		Count            int    `db:"count"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, pq.Array(ruleIDs), since); err != nil {
+		r.logger.Error("Failed to count alert resolutions by rule", "error", err)
+		return nil, fmt.Errorf("failed to count alert resolutions by rule: %w", err)
+	}
+
+	counts := make(map[string]map[string]int)
+	for _, row := range rows {
+		if counts[row.RuleID] == nil {
+			counts[row.RuleID] = make(map[string]int)
+		}
+		counts[row.RuleID][row.ResolutionReason] += row.Count
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Alert dispositions. Only the two outcomes that say whether a rule should
+// have fired are recorded; escalations and duplicates say nothing about the
+// rule's threshold.
+const (
+	AlertDispositionTruePositive  = "true_positive"
+	AlertDispositionFalsePositive = "false_positive"
+)
+
+// Alert disposition sources. Investigators record dispositions directly, and
+// closing an alert as confirmed or false positive records one for them.
+const (
+	AlertDispositionSourceInvestigator = "investigator"
+	AlertDispositionSourceLifecycle    = "lifecycle"
+)
+
+// ErrAlertDispositionNotFound is returned when an alert has no disposition
+var ErrAlertDispositionNotFound = errors.New("alert disposition not found")
+
+// AlertDisposition is an investigator's verdict on whether an alert should
+// have fired
+type AlertDisposition struct {
+	AlertID      string    `db:"alert_id" json:"alert_id"`
+	RuleID       string    `db:"rule_id" json:"rule_id"`
+	Disposition  string    `db:"disposition" json:"disposition"`
+	Investigator string    `db:"investigator" json:"investigator"`
+	Notes        *string   `db:"notes" json:"notes,omitempty"`
+	Source       string    `db:"source" json:"source"`
+	RecordedAt   time.Time `db:"recorded_at" json:"recorded_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// DispositionSample is a disposition together with the event that raised the alert
+type DispositionSample struct {
+	AlertID     string    `db:"alert_id" json:"alert_id"`
+	RuleID      string    `db:"rule_id" json:"rule_id"`
+	RuleName    string    `db:"rule_name" json:"rule_name"`
+	Disposition string    `db:"disposition" json:"disposition"`
+	SourceEvent JSONB     `db:"source_event" json:"source_event"`
+	RecordedAt  time.Time `db:"recorded_at" json:"recorded_at"`
+}
+
+// RuleFeedbackRepository handles alert dispositions
+type RuleFeedbackRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewRuleFeedbackRepository creates a new rule feedback repository
+func NewRuleFeedbackRepository(db *sqlx.DB, logger *slog.Logger) *RuleFeedbackRepository {
+	return &RuleFeedbackRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// RecordDisposition records an alert's disposition, replacing any earlier one
+func (r *RuleFeedbackRepository) RecordDisposition(ctx context.Context, disposition *AlertDisposition) (*AlertDisposition, error) {
+	query := `
+		INSERT INTO alert_dispositions (
+			alert_id, rule_id, disposition, investigator, notes, source, recorded_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		ON CONFLICT (alert_id) DO UPDATE SET
+			disposition = EXCLUDED.disposition,
+			investigator = EXCLUDED.investigator,
+			notes = EXCLUDED.notes,
+			source = EXCLUDED.source,
+			recorded_at = EXCLUDED.recorded_at
+		RETURNING *`
+
+	var recorded AlertDisposition
+	if err := r.db.GetContext(ctx, &recorded, query,
+		disposition.AlertID, disposition.RuleID, disposition.Disposition,
+		disposition.Investigator, disposition.Notes, disposition.Source); err != nil {
+		r.logger.Error("Failed to record alert disposition", "alert_id", disposition.AlertID, "error", err)
+		return nil, fmt.Errorf("failed to record alert disposition: %w", err)
+	}
+	return &recorded, nil
+}
+
+// GetDisposition retrieves an alert's disposition
+func (r *RuleFeedbackRepository) GetDisposition(ctx context.Context, alertID string) (*AlertDisposition, error) {
+	var disposition AlertDisposition
+	err := r.db.GetContext(ctx, &disposition, `SELECT * FROM alert_dispositions WHERE alert_id = $1`, alertID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAlertDispositionNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to get alert disposition", "alert_id", alertID, "error", err)
+		return nil, fmt.Errorf("failed to get alert disposition: %w", err)
+	}
+	return &disposition, nil
+}
+
+// ListSamples retrieves the dispositions recorded since a time with the
+// events of their alerts, newest first, optionally for one rule
+func (r *RuleFeedbackRepository) ListSamples(ctx context.Context, ruleID string, since time.Time, limit int) ([]*DispositionSample, error) {
+	query := `
+		SELECT d.alert_id, d.rule_id, a.rule_name, d.disposition, a.source_event, d.recorded_at
+		FROM alert_dispositions d
+		JOIN alerts a ON a.id = d.alert_id
+		WHERE ($1 = '' OR d.rule_id = $1)
+		AND d.recorded_at >= $2
+		AND a.deleted_at IS NULL
+		ORDER BY d.recorded_at DESC
+		LIMIT $3`
+
+	var samples []*DispositionSample
+	if err := r.db.SelectContext(ctx, &samples, query, ruleID, since, limit); err != nil {
+		r.logger.Error("Failed to list disposition samples", "rule_id", ruleID, "error", err)
+		return nil, fmt.Errorf("failed to list disposition samples: %w", err)
+	}
+	return samples, nil
+}
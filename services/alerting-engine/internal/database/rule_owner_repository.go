@@ -0,0 +1,251 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Rule lifecycle event types. Enabling and disabling is recorded by a
+// database trigger; the rest are raised by the rule health check.
+const (
+	RuleEventEnabled             = "enabled"
+	RuleEventDisabled            = "disabled"
+	RuleEventErrorBudgetExceeded = "error_budget_exceeded"
+	RuleEventFalsePositiveTrend  = "false_positive_trend"
+)
+
+// Rule owner digest statuses
+const (
+	RuleOwnerDigestSent   = "sent"
+	RuleOwnerDigestFailed = "failed"
+)
+
+// RuleOwner is someone responsible for a rule and where they hear about it
+type RuleOwner struct {
+	RuleID    string    `db:"rule_id" json:"rule_id"`
+	OwnerID   string    `db:"owner_id" json:"owner_id"`
+	Channel   string    `db:"channel" json:"channel"`
+	Recipient string    `db:"recipient" json:"recipient"`
+	CreatedBy string    `db:"created_by" json:"created_by"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// RuleLifecycleEvent is something that happened to a rule its owners should
+// know about. Events stay pending until they are sent in a digest.
+type RuleLifecycleEvent struct {
+	ID         string     `db:"id" json:"id"`
+	RuleID     string     `db:"rule_id" json:"rule_id"`
+	RuleName   string     `db:"rule_name" json:"rule_name"`
+	EventType  string     `db:"event_type" json:"event_type"`
+	Actor      *string    `db:"actor" json:"actor,omitempty"`
+	Details    JSONB      `db:"details" json:"details"`
+	OccurredAt time.Time  `db:"occurred_at" json:"occurred_at"`
+	DigestedAt *time.Time `db:"digested_at" json:"digested_at,omitempty"`
+}
+
+// RuleLifecycleEventFilter selects lifecycle events
+type RuleLifecycleEventFilter struct {
+	RuleID    string
+	EventType string
+	Pending   bool
+	Limit     int
+}
+
+// RuleOwnerDigest is one owner's digest of lifecycle events
+type RuleOwnerDigest struct {
+	ID        string         `db:"id" json:"id"`
+	OwnerID   string         `db:"owner_id" json:"owner_id"`
+	Channel   string         `db:"channel" json:"channel"`
+	Recipient string         `db:"recipient" json:"recipient"`
+	EventIDs  pq.StringArray `db:"event_ids" json:"event_ids"`
+	Subject   string         `db:"subject" json:"subject"`
+	Body      string         `db:"body" json:"body"`
+	Status    string         `db:"status" json:"status"`
+	Error     *string        `db:"error" json:"error,omitempty"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+}
+
+// RuleDispositionTrend counts a rule's dispositions in a recent window and
+// the window of the same length before it
+type RuleDispositionTrend struct {
+	RuleID               string `db:"rule_id" json:"rule_id"`
+	RuleName             string `db:"rule_name" json:"rule_name"`
+	RecentTotal          int    `db:"recent_total" json:"recent_total"`
+	RecentFalsePositives int    `db:"recent_false_positives" json:"recent_false_positives"`
+	PriorTotal           int    `db:"prior_total" json:"prior_total"`
+	PriorFalsePositives  int    `db:"prior_false_positives" json:"prior_false_positives"`
+}
+
+// RuleOwnerRepository handles rule owners, rule lifecycle events and the
+// digests sent to owners
+type RuleOwnerRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewRuleOwnerRepository creates a new rule owner repository
+func NewRuleOwnerRepository(db *sqlx.DB, logger *slog.Logger) *RuleOwnerRepository {
+	return &RuleOwnerRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// ReplaceOwners replaces every owner of a rule
+func (r *RuleOwnerRepository) ReplaceOwners(ctx context.Context, ruleID string, owners []*RuleOwner) error {
+	err := r.Transaction(func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM rule_owners WHERE rule_id = $1`, ruleID); err != nil {
+			return fmt.Errorf("failed to clear rule owners: %w", err)
+		}
+
+		for _, owner := range owners {
+			owner.RuleID = ruleID
+			owner.CreatedAt = time.Now()
+			if _, err := tx.NamedExecContext(ctx, `
+				INSERT INTO rule_owners (rule_id, owner_id, channel, recipient, created_by, created_at)
+				VALUES (:rule_id, :owner_id, :channel, :recipient, :created_by, :created_at)`, owner); err != nil {
+				return fmt.Errorf("failed to add rule owner %s: %w", owner.OwnerID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to replace rule owners", "rule_id", ruleID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// ListOwners retrieves the owners of the given rules
+func (r *RuleOwnerRepository) ListOwners(ctx context.Context, ruleIDs []string) ([]*RuleOwner, error) {
+	var owners []*RuleOwner
+	if err := r.db.SelectContext(ctx, &owners, `
+		SELECT * FROM rule_owners
+		WHERE rule_id = ANY($1)
+		ORDER BY rule_id, owner_id`, pq.Array(ruleIDs)); err != nil {
+		return nil, fmt.Errorf("failed to list rule owners: %w", err)
+	}
+	return owners, nil
+}
+
+// RecordEvent records a rule lifecycle event
+func (r *RuleOwnerRepository) RecordEvent(ctx context.Context, event *RuleLifecycleEvent) error {
+	query := `
+		INSERT INTO rule_lifecycle_events (id, rule_id, rule_name, event_type, actor, details, occurred_at)
+		VALUES (:id, :rule_id, :rule_name, :event_type, :actor, :details, :occurred_at)`
+
+	if _, err := r.db.NamedExecContext(ctx, query, event); err != nil {
+		r.logger.Error("Failed to record rule lifecycle event",
+			"rule_id", event.RuleID,
+			"event_type", event.EventType,
+			"error", err)
+		return fmt.Errorf("failed to record rule lifecycle event: %w", err)
+	}
+	return nil
+}
+
+// LastEventAt returns when a rule last had an event of a type, or nil if never
+func (r *RuleOwnerRepository) LastEventAt(ctx context.Context, ruleID, eventType string) (*time.Time, error) {
+	var last *time.Time
+	if err := r.db.GetContext(ctx, &last, `
+		SELECT MAX(occurred_at) FROM rule_lifecycle_events
+		WHERE rule_id = $1 AND event_type = $2`, ruleID, eventType); err != nil {
+		return nil, fmt.Errorf("failed to get last rule lifecycle event: %w", err)
+	}
+	return last, nil
+}
+
+// ListEvents retrieves lifecycle events, newest first
+func (r *RuleOwnerRepository) ListEvents(ctx context.Context, filter RuleLifecycleEventFilter) ([]*RuleLifecycleEvent, error) {
+	query := `
+		SELECT * FROM rule_lifecycle_events
+		WHERE ($1 = '' OR rule_id = $1)
+		AND ($2 = '' OR event_type = $2)
+		AND (NOT $3 OR digested_at IS NULL)
+		ORDER BY occurred_at DESC
+		LIMIT $4`
+
+	var events []*RuleLifecycleEvent
+	if err := r.db.SelectContext(ctx, &events, query, filter.RuleID, filter.EventType, filter.Pending, filter.Limit); err != nil {
+		return nil, fmt.Errorf("failed to list rule lifecycle events: %w", err)
+	}
+	return events, nil
+}
+
+// ListPendingEvents retrieves events not yet sent in a digest, oldest first
+func (r *RuleOwnerRepository) ListPendingEvents(ctx context.Context, limit int) ([]*RuleLifecycleEvent, error) {
+	var events []*RuleLifecycleEvent
+	if err := r.db.SelectContext(ctx, &events, `
+		SELECT * FROM rule_lifecycle_events
+		WHERE digested_at IS NULL
+		ORDER BY occurred_at
+		LIMIT $1`, limit); err != nil {
+		return nil, fmt.Errorf("failed to list pending rule lifecycle events: %w", err)
+	}
+	return events, nil
+}
+
+// MarkEventsDigested marks events as sent in a digest
+func (r *RuleOwnerRepository) MarkEventsDigested(ctx context.Context, ids []string, at time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE rule_lifecycle_events SET digested_at = $2
+		WHERE id = ANY($1) AND digested_at IS NULL`, pq.Array(ids), at); err != nil {
+		return fmt.Errorf("failed to mark rule lifecycle events digested: %w", err)
+	}
+	return nil
+}
+
+// SaveDigest records a digest sent to a rule owner
+func (r *RuleOwnerRepository) SaveDigest(ctx context.Context, digest *RuleOwnerDigest) error {
+	query := `
+		INSERT INTO rule_owner_digests (
+			id, owner_id, channel, recipient, event_ids, subject, body, status, error, created_at
+		) VALUES (
+			:id, :owner_id, :channel, :recipient, :event_ids, :subject, :body, :status, :error, :created_at
+		)`
+
+	if _, err := r.db.NamedExecContext(ctx, query, digest); err != nil {
+		r.logger.Error("Failed to save rule owner digest", "owner_id", digest.OwnerID, "error", err)
+		return fmt.Errorf("failed to save rule owner digest: %w", err)
+	}
+	return nil
+}
+
+// ListDigests retrieves digests, newest first, optionally for one owner
+func (r *RuleOwnerRepository) ListDigests(ctx context.Context, ownerID string, limit int) ([]*RuleOwnerDigest, error) {
+	var digests []*RuleOwnerDigest
+	if err := r.db.SelectContext(ctx, &digests, `
+		SELECT * FROM rule_owner_digests
+		WHERE ($1 = '' OR owner_id = $1)
+		ORDER BY created_at DESC
+		LIMIT $2`, ownerID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list rule owner digests: %w", err)
+	}
+	return digests, nil
+}
+
+// DispositionTrends counts each rule's dispositions recorded from split
+// onwards and those recorded between since and split
+func (r *RuleOwnerRepository) DispositionTrends(ctx context.Context, split, since time.Time) ([]*RuleDispositionTrend, error) {
+	query := `
+		SELECT d.rule_id, MAX(a.rule_name) AS rule_name,
+			COUNT(*) FILTER (WHERE d.recorded_at >= $1) AS recent_total,
+			COUNT(*) FILTER (WHERE d.recorded_at >= $1 AND d.disposition = $3) AS recent_false_positives,
+			COUNT(*) FILTER (WHERE d.recorded_at < $1) AS prior_total,
+			COUNT(*) FILTER (WHERE d.recorded_at < $1 AND d.disposition = $3) AS prior_false_positives
+		FROM alert_dispositions d
+		JOIN alerts a ON a.id = d.alert_id
+		WHERE d.recorded_at >= $2 AND a.deleted_at IS NULL
+		GROUP BY d.rule_id`
+
+	var trends []*RuleDispositionTrend
+	if err := r.db.SelectContext(ctx, &trends, query, split, since, AlertDispositionFalsePositive); err != nil {
+		return nil, fmt.Errorf("failed to count disposition trends: %w", err)
+	}
+	return trends, nil
+}
@@ -0,0 +1,367 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ErrRiskTierNotFound is returned when an entity has no risk tier
+var ErrRiskTierNotFound = errors.New("entity risk tier not found")
+
+// RulePackRepository handles the lookup tables, named thresholds and entity
+// risk tiers rule conditions reference, the log of changes to them, and the
+// history of rule pack imports
+type RulePackRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewRulePackRepository creates a new rule pack repository
+func NewRulePackRepository(db *sqlx.DB, logger *slog.Logger) *RulePackRepository {
+	return &RulePackRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Lookup table operations
+
+// ListLookupTables retrieves every lookup table
+func (r *RulePackRepository) ListLookupTables(ctx context.Context) ([]*LookupTable, error) {
+	var tables []*LookupTable
+	if err := r.db.SelectContext(ctx, &tables, `SELECT * FROM rule_lookup_tables ORDER BY name`); err != nil {
+		return nil, fmt.Errorf("failed to list lookup tables: %w", err)
+	}
+
+	return tables, nil
+}
+
+// GetLookupTable retrieves a lookup table by name, returning nil when it does not exist
+func (r *RulePackRepository) GetLookupTable(ctx context.Context, name string) (*LookupTable, error) {
+	var table LookupTable
+	err := r.db.GetContext(ctx, &table, `SELECT * FROM rule_lookup_tables WHERE name = $1`, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lookup table: %w", err)
+	}
+
+	return &table, nil
+}
+
+// UpsertLookupTable creates a lookup table or replaces the entries of the one with the same name
+func (r *RulePackRepository) UpsertLookupTable(ctx context.Context, table *LookupTable) error {
+	query := `
+		INSERT INTO rule_lookup_tables (
+			id, name, description, entries, source_pack, created_by, updated_by,
+			created_at, updated_at
+		) VALUES (
+			:id, :name, :description, :entries, :source_pack, :created_by, :updated_by,
+			:created_at, :updated_at
+		)
+		ON CONFLICT (name) DO UPDATE SET
+			description = EXCLUDED.description,
+			entries = EXCLUDED.entries,
+			source_pack = EXCLUDED.source_pack,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at`
+
+	now := time.Now()
+	table.CreatedAt = now
+	table.UpdatedAt = now
+
+	if _, err := r.db.NamedExecContext(ctx, query, table); err != nil {
+		r.logger.Error("Failed to upsert lookup table", "name", table.Name, "error", err)
+		return fmt.Errorf("failed to upsert lookup table: %w", err)
+	}
+
+	r.logger.Info("Lookup table stored", "name", table.Name, "entries", len(table.Entries))
+	return nil
+}
+
+// Threshold operations
+
+// ListThresholds retrieves every named threshold
+func (r *RulePackRepository) ListThresholds(ctx context.Context) ([]*RuleThreshold, error) {
+	var thresholds []*RuleThreshold
+	if err := r.db.SelectContext(ctx, &thresholds, `SELECT * FROM rule_thresholds ORDER BY name`); err != nil {
+		return nil, fmt.Errorf("failed to list rule thresholds: %w", err)
+	}
+
+	return thresholds, nil
+}
+
+// GetThreshold retrieves a threshold by name, returning nil when it does not exist
+func (r *RulePackRepository) GetThreshold(ctx context.Context, name string) (*RuleThreshold, error) {
+	var threshold RuleThreshold
+	err := r.db.GetContext(ctx, &threshold, `SELECT * FROM rule_thresholds WHERE name = $1`, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rule threshold: %w", err)
+	}
+
+	return &threshold, nil
+}
+
+// UpsertThreshold creates a threshold or replaces the value of the one with the same name
+func (r *RulePackRepository) UpsertThreshold(ctx context.Context, threshold *RuleThreshold) error {
+	query := `
+		INSERT INTO rule_thresholds (
+			id, name, value, description, source_pack, created_by, updated_by,
+			created_at, updated_at
+		) VALUES (
+			:id, :name, :value, :description, :source_pack, :created_by, :updated_by,
+			:created_at, :updated_at
+		)
+		ON CONFLICT (name) DO UPDATE SET
+			value = EXCLUDED.value,
+			description = EXCLUDED.description,
+			source_pack = EXCLUDED.source_pack,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at`
+
+	now := time.Now()
+	threshold.CreatedAt = now
+	threshold.UpdatedAt = now
+
+	if _, err := r.db.NamedExecContext(ctx, query, threshold); err != nil {
+		r.logger.Error("Failed to upsert rule threshold", "name", threshold.Name, "error", err)
+		return fmt.Errorf("failed to upsert rule threshold: %w", err)
+	}
+
+	r.logger.Info("Rule threshold stored", "name", threshold.Name, "value", threshold.Value)
+	return nil
+}
+
+// Risk tier operations
+
+// ListRiskTiers retrieves entity risk tiers, optionally only those of one tier
+func (r *RulePackRepository) ListRiskTiers(ctx context.Context, tier string, limit, offset int) ([]*EntityRiskTier, error) {
+	query := `
+		SELECT * FROM entity_risk_tiers
+		WHERE ($1 = '' OR tier = $1)
+		ORDER BY entity_id
+		LIMIT $2 OFFSET $3`
+
+	var tiers []*EntityRiskTier
+	if err := r.db.SelectContext(ctx, &tiers, query, tier, limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to list entity risk tiers: %w", err)
+	}
+
+	return tiers, nil
+}
+
+// GetRiskTier retrieves the risk tier of an entity, returning nil when it has none
+func (r *RulePackRepository) GetRiskTier(ctx context.Context, entityID string) (*EntityRiskTier, error) {
+	var tier EntityRiskTier
+	err := r.db.GetContext(ctx, &tier, `SELECT * FROM entity_risk_tiers WHERE entity_id = $1`, entityID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity risk tier: %w", err)
+	}
+
+	return &tier, nil
+}
+
+// UpsertRiskTier sets the risk tier of an entity
+func (r *RulePackRepository) UpsertRiskTier(ctx context.Context, tier *EntityRiskTier) error {
+	query := `
+		INSERT INTO entity_risk_tiers (
+			entity_id, tier, score, reason, updated_by, created_at, updated_at
+		) VALUES (
+			:entity_id, :tier, :score, :reason, :updated_by, :created_at, :updated_at
+		)
+		ON CONFLICT (entity_id) DO UPDATE SET
+			tier = EXCLUDED.tier,
+			score = EXCLUDED.score,
+			reason = EXCLUDED.reason,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at`
+
+	now := time.Now()
+	tier.CreatedAt = now
+	tier.UpdatedAt = now
+
+	if _, err := r.db.NamedExecContext(ctx, query, tier); err != nil {
+		return fmt.Errorf("failed to upsert entity risk tier: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRiskTier removes the risk tier of an entity
+func (r *RulePackRepository) DeleteRiskTier(ctx context.Context, entityID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM entity_risk_tiers WHERE entity_id = $1`, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to delete entity risk tier: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrRiskTierNotFound
+	}
+
+	return nil
+}
+
+// Change log operations
+
+// ListReferenceChanges retrieves the reference data changes logged since a
+// point in time, along with the database's current time to resume from
+func (r *RulePackRepository) ListReferenceChanges(ctx context.Context, since time.Time) ([]*ReferenceDataChange, time.Time, error) {
+	var now time.Time
+	if err := r.db.GetContext(ctx, &now, `SELECT NOW()`); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read database time: %w", err)
+	}
+
+	var changes []*ReferenceDataChange
+	if err := r.db.SelectContext(ctx, &changes,
+		`SELECT * FROM reference_data_changes WHERE changed_at >= $1 ORDER BY version`, since); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to list reference data changes: %w", err)
+	}
+
+	return changes, now, nil
+}
+
+// PruneReferenceChanges deletes logged changes older than a point in time
+func (r *RulePackRepository) PruneReferenceChanges(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM reference_data_changes WHERE changed_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune reference data changes: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// Import history operations
+
+// CreateImport records a completed rule pack import
+func (r *RulePackRepository) CreateImport(ctx context.Context, record *RulePackImport) error {
+	query := `
+		INSERT INTO rule_pack_imports (
+			id, pack_name, pack_version, dsl_version, source_deployment,
+			signature_key_id, signed, conflict_mode, rules_created, rules_updated,
+			rules_skipped, rules_renamed, result, imported_by, imported_at
+		) VALUES (
+			:id, :pack_name, :pack_version, :dsl_version, :source_deployment,
+			:signature_key_id, :signed, :conflict_mode, :rules_created, :rules_updated,
+			:rules_skipped, :rules_renamed, :result, :imported_by, :imported_at
+		)`
+
+	if _, err := r.db.NamedExecContext(ctx, query, record); err != nil {
+		r.logger.Error("Failed to record rule pack import", "import_id", record.ID, "error", err)
+		return fmt.Errorf("failed to record rule pack import: %w", err)
+	}
+
+	r.logger.Info("Rule pack imported",
+		"import_id", record.ID,
+		"pack_name", record.PackName,
+		"pack_version", record.PackVersion,
+		"imported_by", record.ImportedBy)
+	return nil
+}
+
+// ListImports retrieves recent rule pack imports
+func (r *RulePackRepository) ListImports(ctx context.Context, limit int) ([]*RulePackImport, error) {
+	var imports []*RulePackImport
+	if err := r.db.SelectContext(ctx, &imports,
+		`SELECT * FROM rule_pack_imports ORDER BY imported_at DESC LIMIT $1`, limit); err != nil {
+		return nil, fmt.Errorf("failed to list rule pack imports: %w", err)
+	}
+
+	return imports, nil
+}
+
+// LookupTable is a named set of values rule conditions test membership against
+type LookupTable struct {
+	ID          string         `db:"id" json:"id"`
+	Name        string         `db:"name" json:"name"`
+	Description *string        `db:"description" json:"description,omitempty"`
+	Entries     pq.StringArray `db:"entries" json:"entries"`
+	SourcePack  *string        `db:"source_pack" json:"source_pack,omitempty"`
+	CreatedBy   string         `db:"created_by" json:"created_by"`
+	UpdatedBy   string         `db:"updated_by" json:"updated_by"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// RuleThreshold is a named numeric threshold rule conditions compare against
+type RuleThreshold struct {
+	ID          string    `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	Value       float64   `db:"value" json:"value"`
+	Description *string   `db:"description" json:"description,omitempty"`
+	SourcePack  *string   `db:"source_pack" json:"source_pack,omitempty"`
+	CreatedBy   string    `db:"created_by" json:"created_by"`
+	UpdatedBy   string    `db:"updated_by" json:"updated_by"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Entity risk tiers
+const (
+	RiskTierLow      = "low"
+	RiskTierMedium   = "medium"
+	RiskTierHigh     = "high"
+	RiskTierCritical = "critical"
+)
+
+// EntityRiskTier is the risk tier rule conditions see for an entity
+type EntityRiskTier struct {
+	EntityID  string    `db:"entity_id" json:"entity_id"`
+	Tier      string    `db:"tier" json:"tier"`
+	Score     *float64  `db:"score" json:"score,omitempty"`
+	Reason    *string   `db:"reason" json:"reason,omitempty"`
+	UpdatedBy string    `db:"updated_by" json:"updated_by"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Reference data kinds, as logged in the change log
+const (
+	ReferenceKindLookup    = "lookup"
+	ReferenceKindThreshold = "threshold"
+	ReferenceKindRiskTier  = "risk_tier"
+)
+
+// ReferenceDataChange is one logged change to a lookup table, threshold or risk tier
+type ReferenceDataChange struct {
+	Version   int64     `db:"version" json:"version"`
+	Kind      string    `db:"kind" json:"kind"`
+	Key       string    `db:"key" json:"key"`
+	ChangedAt time.Time `db:"changed_at" json:"changed_at"`
+}
+
+// RulePackImport records one rule pack import and its provenance
+type RulePackImport struct {
+	ID               string    `db:"id" json:"id"`
+	PackName         string    `db:"pack_name" json:"pack_name"`
+	PackVersion      string    `db:"pack_version" json:"pack_version"`
+	DSLVersion       string    `db:"dsl_version" json:"dsl_version"`
+	SourceDeployment *string   `db:"source_deployment" json:"source_deployment,omitempty"`
+	SignatureKeyID   *string   `db:"signature_key_id" json:"signature_key_id,omitempty"`
+	Signed           bool      `db:"signed" json:"signed"`
+	ConflictMode     string    `db:"conflict_mode" json:"conflict_mode"`
+	RulesCreated     int       `db:"rules_created" json:"rules_created"`
+	RulesUpdated     int       `db:"rules_updated" json:"rules_updated"`
+	RulesSkipped     int       `db:"rules_skipped" json:"rules_skipped"`
+	RulesRenamed     int       `db:"rules_renamed" json:"rules_renamed"`
+	Result           JSONB     `db:"result" json:"result,omitempty"`
+	ImportedBy       string    `db:"imported_by" json:"imported_by"`
+	ImportedAt       time.Time `db:"imported_at" json:"imported_at"`
+}
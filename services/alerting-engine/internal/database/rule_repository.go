@@ -25,7 +25,7 @@ func NewRuleRepository(db *sqlx.DB, logger *slog.Logger) *RuleRepository {
 	}
 }
 
-// Create creates a new rule
+// Create creates a new rule and records its first version
 func (r *RuleRepository) Create(ctx context.Context, rule *Rule) error {
 	query := `
 		INSERT INTO rules (
@@ -43,10 +43,20 @@ func (r *RuleRepository) Create(ctx context.Context, rule *Rule) error {
 		)`
 
 	rule.CreatedAt = time.Now()
-	rule.UpdatedAt = time.Now()
+	rule.UpdatedAt = rule.CreatedAt
 	rule.Version = 1
 
-	_, err := r.db.NamedExecContext(ctx, query, rule)
+	version, err := newRuleVersion(rule, RuleChange{Type: RuleChangeCreated, By: rule.CreatedBy}, rule.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	err = r.Transaction(func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, query, rule); err != nil {
+			return err
+		}
+		return activateRuleVersion(ctx, tx, version)
+	})
 	if err != nil {
 		r.logger.Error("Failed to create rule", "rule_id", rule.ID, "error", err)
 		return fmt.Errorf("failed to create rule: %w", err)
@@ -88,8 +98,15 @@ func (r *RuleRepository) GetByName(ctx context.Context, name string) (*Rule, err
 	return &rule, nil
 }
 
-// Update updates an existing rule
+// Update updates an existing rule and records the new version
 func (r *RuleRepository) Update(ctx context.Context, rule *Rule) error {
+	return r.UpdateWithChange(ctx, rule, RuleChange{Type: RuleChangeUpdated, By: rule.UpdatedBy})
+}
+
+// UpdateWithChange updates an existing rule and records the new version as
+// the given change. A rule last written before versioning has its current
+// version recorded first, so the history shows what was replaced.
+func (r *RuleRepository) UpdateWithChange(ctx context.Context, rule *Rule, change RuleChange) error {
 	// First, get the current version
 	currentRule, err := r.GetByID(ctx, rule.ID)
 	if err != nil {
@@ -121,6 +138,11 @@ func (r *RuleRepository) Update(ctx context.Context, rule *Rule) error {
 	rule.Version = currentRule.Version + 1
 	rule.UpdatedAt = time.Now()
 
+	version, err := newRuleVersion(rule, change, rule.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
 	// Use a custom struct to include current version for optimistic locking
 	updateData := struct {
 		*Rule
@@ -130,22 +152,33 @@ func (r *RuleRepository) Update(ctx context.Context, rule *Rule) error {
 		CurrentVersion: currentRule.Version,
 	}
 
-	result, err := r.db.NamedExecContext(ctx, query, updateData)
-	if err != nil {
-		r.logger.Error("Failed to update rule", "rule_id", rule.ID, "error", err)
-		return fmt.Errorf("failed to update rule: %w", err)
-	}
+	err = r.Transaction(func(tx *sqlx.Tx) error {
+		if err := recordBaselineVersion(ctx, tx, currentRule); err != nil {
+			return err
+		}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
+		result, err := tx.NamedExecContext(ctx, query, updateData)
+		if err != nil {
+			return fmt.Errorf("failed to update rule: %w", err)
+		}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("rule not found or version conflict: %s", rule.ID)
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("rule not found or version conflict: %s", rule.ID)
+		}
+
+		return activateRuleVersion(ctx, tx, version)
+	})
+	if err != nil {
+		r.logger.Error("Failed to update rule", "rule_id", rule.ID, "error", err)
+		return err
 	}
 
-	r.logger.Info("Rule updated", "rule_id", rule.ID, "new_version", rule.Version)
+	r.logger.Info("Rule updated", "rule_id", rule.ID, "new_version", rule.Version, "change", change.Type)
 	return nil
 }
 
@@ -287,7 +320,7 @@ func (r *RuleRepository) Disable(ctx context.Context, id, updatedBy string) erro
 	return nil
 }
 
-// Delete soft deletes a rule
+// Delete soft deletes a rule and ends the activation of its live version
 func (r *RuleRepository) Delete(ctx context.Context, id string) error {
 	query := `
 		UPDATE rules SET
@@ -295,53 +328,39 @@ func (r *RuleRepository) Delete(ctx context.Context, id string) error {
 			updated_at = NOW()
 		WHERE id = $1 AND deleted_at IS NULL`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	err := r.Transaction(func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete rule: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("rule not found: %s", id)
+		}
+
+		closeQuery := `
+			UPDATE rule_activations SET deactivated_at = NOW()
+			WHERE rule_id = $1 AND deactivated_at IS NULL`
+		if _, err := tx.ExecContext(ctx, closeQuery, id); err != nil {
+			return fmt.Errorf("failed to close rule activation: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
 		r.logger.Error("Failed to delete rule", "rule_id", id, "error", err)
-		return fmt.Errorf("failed to delete rule: %w", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
-	if rowsAffected == 0 {
-		return fmt.Errorf("rule not found: %s", id)
+		return err
 	}
 
 	r.logger.Info("Rule deleted", "rule_id", id)
 	return nil
 }
 
-// GetVersion retrieves a specific version of a rule
-func (r *RuleRepository) GetVersion(ctx context.Context, id string, version int) (*Rule, error) {
-	// In a full implementation, this would require a rule_versions table
-	// For now, we'll just return the current version if it matches
-	rule, err := r.GetByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	if rule.Version != version {
-		return nil, fmt.Errorf("rule version %d not found", version)
-	}
-
-	return rule, nil
-}
-
-// GetVersionHistory retrieves version history for a rule
-func (r *RuleRepository) GetVersionHistory(ctx context.Context, id string) ([]*Rule, error) {
-	// In a full implementation, this would query a rule_versions table
-	// For now, we'll just return the current version
-	rule, err := r.GetByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	return []*Rule{rule}, nil
-}
-
 // Duplicate creates a copy of an existing rule
 func (r *RuleRepository) Duplicate(ctx context.Context, sourceID, newID, newName, createdBy string) (*Rule, error) {
 	sourceRule, err := r.GetByID(ctx, sourceID)
@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ErrSuppressionNotFound is returned when a rule has no suppression settings of its own
+var ErrSuppressionNotFound = errors.New("rule suppression settings not found")
+
+// RuleSuppression overrides the default alert deduplication settings for one rule
+type RuleSuppression struct {
+	RuleID            string         `db:"rule_id" json:"rule_id"`
+	Enabled           bool           `db:"enabled" json:"enabled"`
+	WindowSeconds     int            `db:"window_seconds" json:"window_seconds"`
+	FingerprintFields pq.StringArray `db:"fingerprint_fields" json:"fingerprint_fields"`
+	UpdatedBy         string         `db:"updated_by" json:"updated_by"`
+	CreatedAt         time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// Window returns the suppression window as a duration
+func (s *RuleSuppression) Window() time.Duration {
+	return time.Duration(s.WindowSeconds) * time.Second
+}
+
+// SuppressionStats summarizes the repeats suppressed for a rule
+type SuppressionStats struct {
+	AlertsWithRepeats int        `db:"alerts_with_repeats" json:"alerts_with_repeats"`
+	SuppressedCount   int        `db:"suppressed_count" json:"suppressed_count"`
+	LastSuppressedAt  *time.Time `db:"last_suppressed_at" json:"last_suppressed_at,omitempty"`
+}
+
+// RuleSuppressionRepository handles per-rule suppression settings and the
+// suppressed counts kept on alerts
+type RuleSuppressionRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewRuleSuppressionRepository creates a new rule suppression repository
+func NewRuleSuppressionRepository(db *sqlx.DB, logger *slog.Logger) *RuleSuppressionRepository {
+	return &RuleSuppressionRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// Get retrieves a rule's suppression settings
+func (r *RuleSuppressionRepository) Get(ctx context.Context, ruleID string) (*RuleSuppression, error) {
+	var settings RuleSuppression
+	err := r.db.GetContext(ctx, &settings, `SELECT * FROM rule_suppression_settings WHERE rule_id = $1`, ruleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSuppressionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rule suppression settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// Upsert creates or replaces a rule's suppression settings
+func (r *RuleSuppressionRepository) Upsert(ctx context.Context, settings *RuleSuppression) error {
+	query := `
+		INSERT INTO rule_suppression_settings (rule_id, enabled, window_seconds, fingerprint_fields, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (rule_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			window_seconds = EXCLUDED.window_seconds,
+			fingerprint_fields = EXCLUDED.fingerprint_fields,
+			updated_by = EXCLUDED.updated_by
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowxContext(ctx, query, settings.RuleID, settings.Enabled, settings.WindowSeconds,
+		settings.FingerprintFields, settings.UpdatedBy).Scan(&settings.CreatedAt, &settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save rule suppression settings: %w", err)
+	}
+
+	r.logger.Info("Rule suppression settings saved",
+		"rule_id", settings.RuleID,
+		"enabled", settings.Enabled,
+		"window_seconds", settings.WindowSeconds)
+	return nil
+}
+
+// Delete removes a rule's suppression settings so it falls back to the defaults
+func (r *RuleSuppressionRepository) Delete(ctx context.Context, ruleID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM rule_suppression_settings WHERE rule_id = $1`, ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to delete rule suppression settings: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrSuppressionNotFound
+	}
+	return nil
+}
+
+// SuppressRepeat counts a repeat against the latest unresolved alert with the
+// fingerprint raised since the given time, returning its ID. An empty ID means
+// there is no such alert and the repeat should be raised.
+func (r *RuleSuppressionRepository) SuppressRepeat(ctx context.Context, fingerprint string, since, at time.Time) (string, error) {
+	query := `
+		UPDATE alerts
+		SET suppressed_count = suppressed_count + 1, last_suppressed_at = $3
+		WHERE id = (
+			SELECT id FROM alerts
+			WHERE fingerprint = $1
+			AND created_at > $2
+			AND status NOT IN ('resolved', 'closed_false_positive', 'closed_confirmed', 'closed_auto')
+			AND deleted_at IS NULL
+			ORDER BY created_at DESC
+			LIMIT 1
+		)
+		RETURNING id`
+
+	var alertID string
+	err := r.db.QueryRowxContext(ctx, query, fingerprint, since, at).Scan(&alertID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to suppress repeated alert: %w", err)
+	}
+	return alertID, nil
+}
+
+// SuppressionStats sums a rule's suppressed repeats over alerts raised since the given time
+func (r *RuleSuppressionRepository) SuppressionStats(ctx context.Context, ruleID string, since time.Time) (*SuppressionStats, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE suppressed_count > 0) AS alerts_with_repeats,
+			COALESCE(SUM(suppressed_count), 0) AS suppressed_count,
+			MAX(last_suppressed_at) AS last_suppressed_at
+		FROM alerts
+		WHERE rule_id = $1 AND created_at > $2 AND deleted_at IS NULL`
+
+	var stats SuppressionStats
+	if err := r.db.GetContext(ctx, &stats, query, ruleID, since); err != nil {
+		return nil, fmt.Errorf("failed to get rule suppression stats: %w", err)
+	}
+	return &stats, nil
+}
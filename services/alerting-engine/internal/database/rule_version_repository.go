@@ -0,0 +1,274 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrRuleVersionNotFound is returned when a rule has no such version
+var ErrRuleVersionNotFound = errors.New("rule version not found")
+
+// Rule version change types
+const (
+	RuleChangeBaseline   = "baseline" // rule existed before versioning
+	RuleChangeCreated    = "created"
+	RuleChangeUpdated    = "updated"
+	RuleChangeRolledBack = "rolled_back"
+)
+
+// RuleDefinition is the part of a rule that decides what it alerts on and
+// how. Enabled state and audit fields are not part of it: switching a rule
+// on or off does not create a version.
+type RuleDefinition struct {
+	Name                 string                 `json:"name"`
+	Description          string                 `json:"description"`
+	Type                 string                 `json:"type"`
+	Severity             string                 `json:"severity"`
+	Priority             string                 `json:"priority"`
+	Conditions           map[string]interface{} `json:"conditions"`
+	Actions              map[string]interface{} `json:"actions"`
+	Tags                 []string               `json:"tags"`
+	Metadata             map[string]interface{} `json:"metadata"`
+	ThrottleWindow       *time.Duration         `json:"throttle_window,omitempty"`
+	EvaluationWindow     *time.Duration         `json:"evaluation_window,omitempty"`
+	GroupBy              []string               `json:"group_by"`
+	NotificationChannels []string               `json:"notification_channels"`
+	EscalationPolicy     *string                `json:"escalation_policy,omitempty"`
+}
+
+// NewRuleDefinition captures the definition of a rule
+func NewRuleDefinition(rule *Rule) RuleDefinition {
+	return RuleDefinition{
+		Name:                 rule.Name,
+		Description:          rule.Description,
+		Type:                 rule.Type,
+		Severity:             rule.Severity,
+		Priority:             rule.Priority,
+		Conditions:           rule.Conditions,
+		Actions:              rule.Actions,
+		Tags:                 rule.Tags,
+		Metadata:             rule.Metadata,
+		ThrottleWindow:       rule.ThrottleWindow,
+		EvaluationWindow:     rule.EvaluationWindow,
+		GroupBy:              rule.GroupBy,
+		NotificationChannels: rule.NotificationChannels,
+		EscalationPolicy:     rule.EscalationPolicy,
+	}
+}
+
+// Apply replaces the definition of a rule, leaving its identity, enabled
+// state and audit fields alone
+func (d RuleDefinition) Apply(rule *Rule) {
+	rule.Name = d.Name
+	rule.Description = d.Description
+	rule.Type = d.Type
+	rule.Severity = d.Severity
+	rule.Priority = d.Priority
+	rule.Conditions = d.Conditions
+	rule.Actions = d.Actions
+	rule.Tags = d.Tags
+	rule.Metadata = d.Metadata
+	rule.ThrottleWindow = d.ThrottleWindow
+	rule.EvaluationWindow = d.EvaluationWindow
+	rule.GroupBy = d.GroupBy
+	rule.NotificationChannels = d.NotificationChannels
+	rule.EscalationPolicy = d.EscalationPolicy
+}
+
+// Encode returns the canonical JSON of a definition and its SHA-256
+// checksum. Map keys are sorted when encoding, so equal definitions always
+// have equal checksums.
+func (d RuleDefinition) Encode() (json.RawMessage, string, error) {
+	encoded, err := json.Marshal(d)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode rule definition: %w", err)
+	}
+
+	sum := sha256.Sum256(encoded)
+	return encoded, hex.EncodeToString(sum[:]), nil
+}
+
+// RuleVersion is an immutable copy of a rule definition
+type RuleVersion struct {
+	RuleID        string          `db:"rule_id" json:"rule_id"`
+	Version       int             `db:"version" json:"version"`
+	Definition    json.RawMessage `db:"definition" json:"definition"`
+	Checksum      string          `db:"checksum" json:"checksum"`
+	ChangeType    string          `db:"change_type" json:"change_type"`
+	SourceVersion *int            `db:"source_version" json:"source_version,omitempty"` // version a rollback restored
+	ChangeReason  *string         `db:"change_reason" json:"change_reason,omitempty"`
+	CreatedBy     string          `db:"created_by" json:"created_by"`
+	CreatedAt     time.Time       `db:"created_at" json:"created_at"`
+}
+
+// Decode returns the definition stored in a version
+func (v *RuleVersion) Decode() (RuleDefinition, error) {
+	var definition RuleDefinition
+	if err := json.Unmarshal(v.Definition, &definition); err != nil {
+		return RuleDefinition{}, fmt.Errorf("failed to decode rule version %d: %w", v.Version, err)
+	}
+	return definition, nil
+}
+
+// RuleActivation records the period a rule version was live
+type RuleActivation struct {
+	ID            int64      `db:"id" json:"id"`
+	RuleID        string     `db:"rule_id" json:"rule_id"`
+	Version       int        `db:"version" json:"version"`
+	ChangeType    string     `db:"change_type" json:"change_type"`
+	ActivatedBy   string     `db:"activated_by" json:"activated_by"`
+	ActivatedAt   time.Time  `db:"activated_at" json:"activated_at"`
+	DeactivatedAt *time.Time `db:"deactivated_at" json:"deactivated_at,omitempty"`
+}
+
+// RuleChange describes why a rule is being changed
+type RuleChange struct {
+	Type          string
+	SourceVersion *int
+	Reason        *string
+	By            string
+}
+
+const insertRuleVersionQuery = `
+	INSERT INTO rule_versions (
+		rule_id, version, definition, checksum, change_type, source_version,
+		change_reason, created_by, created_at
+	) VALUES (
+		:rule_id, :version, :definition, :checksum, :change_type, :source_version,
+		:change_reason, :created_by, :created_at
+	)`
+
+// newRuleVersion builds the version recording a rule as it is now
+func newRuleVersion(rule *Rule, change RuleChange, at time.Time) (*RuleVersion, error) {
+	definition, checksum, err := NewRuleDefinition(rule).Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RuleVersion{
+		RuleID:        rule.ID,
+		Version:       rule.Version,
+		Definition:    definition,
+		Checksum:      checksum,
+		ChangeType:    change.Type,
+		SourceVersion: change.SourceVersion,
+		ChangeReason:  change.Reason,
+		CreatedBy:     change.By,
+		CreatedAt:     at,
+	}, nil
+}
+
+// activateRuleVersion records a version, closes the activation of the
+// version it replaces and opens its own
+func activateRuleVersion(ctx context.Context, tx *sqlx.Tx, version *RuleVersion) error {
+	if _, err := tx.NamedExecContext(ctx, insertRuleVersionQuery, version); err != nil {
+		return fmt.Errorf("failed to record rule version: %w", err)
+	}
+
+	closeQuery := `
+		UPDATE rule_activations SET deactivated_at = $2
+		WHERE rule_id = $1 AND deactivated_at IS NULL`
+	if _, err := tx.ExecContext(ctx, closeQuery, version.RuleID, version.CreatedAt); err != nil {
+		return fmt.Errorf("failed to close rule activation: %w", err)
+	}
+
+	openQuery := `
+		INSERT INTO rule_activations (rule_id, version, change_type, activated_by, activated_at)
+		VALUES ($1, $2, $3, $4, $5)`
+	if _, err := tx.ExecContext(ctx, openQuery,
+		version.RuleID, version.Version, version.ChangeType, version.CreatedBy, version.CreatedAt); err != nil {
+		return fmt.Errorf("failed to open rule activation: %w", err)
+	}
+
+	return nil
+}
+
+// recordBaselineVersion records a rule that was last written before
+// versioning as it is now, live since its last update. It does nothing for
+// rules whose current version is already recorded.
+func recordBaselineVersion(ctx context.Context, tx *sqlx.Tx, rule *Rule) error {
+	version, err := newRuleVersion(rule, RuleChange{Type: RuleChangeBaseline, By: rule.UpdatedBy}, rule.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.NamedExecContext(ctx, insertRuleVersionQuery+` ON CONFLICT (rule_id, version) DO NOTHING`, version)
+	if err != nil {
+		return fmt.Errorf("failed to record baseline rule version: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+		return err
+	}
+
+	openQuery := `
+		INSERT INTO rule_activations (rule_id, version, change_type, activated_by, activated_at)
+		SELECT $1, $2, $3, $4, $5
+		WHERE NOT EXISTS (
+			SELECT 1 FROM rule_activations WHERE rule_id = $1 AND deactivated_at IS NULL
+		)`
+	if _, err := tx.ExecContext(ctx, openQuery,
+		version.RuleID, version.Version, version.ChangeType, version.CreatedBy, version.CreatedAt); err != nil {
+		return fmt.Errorf("failed to open baseline rule activation: %w", err)
+	}
+
+	return nil
+}
+
+// RecordBaseline records the current version of a rule that was last
+// written before versioning, so its history starts from what is live
+func (r *RuleRepository) RecordBaseline(ctx context.Context, rule *Rule) error {
+	return r.Transaction(func(tx *sqlx.Tx) error {
+		return recordBaselineVersion(ctx, tx, rule)
+	})
+}
+
+// GetVersion retrieves a specific version of a rule
+func (r *RuleRepository) GetVersion(ctx context.Context, id string, version int) (*RuleVersion, error) {
+	query := `SELECT * FROM rule_versions WHERE rule_id = $1 AND version = $2`
+
+	var ruleVersion RuleVersion
+	err := r.db.GetContext(ctx, &ruleVersion, query, id, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRuleVersionNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to get rule version", "rule_id", id, "version", version, "error", err)
+		return nil, fmt.Errorf("failed to get rule version: %w", err)
+	}
+
+	return &ruleVersion, nil
+}
+
+// GetVersionHistory retrieves every recorded version of a rule, newest first
+func (r *RuleRepository) GetVersionHistory(ctx context.Context, id string) ([]*RuleVersion, error) {
+	query := `SELECT * FROM rule_versions WHERE rule_id = $1 ORDER BY version DESC`
+
+	var versions []*RuleVersion
+	if err := r.db.SelectContext(ctx, &versions, query, id); err != nil {
+		r.logger.Error("Failed to get rule version history", "rule_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get rule version history: %w", err)
+	}
+
+	return versions, nil
+}
+
+// ListActivations retrieves the activation history of a rule, newest first
+func (r *RuleRepository) ListActivations(ctx context.Context, id string) ([]*RuleActivation, error) {
+	query := `SELECT * FROM rule_activations WHERE rule_id = $1 ORDER BY activated_at DESC, id DESC`
+
+	var activations []*RuleActivation
+	if err := r.db.SelectContext(ctx, &activations, query, id); err != nil {
+		r.logger.Error("Failed to list rule activations", "rule_id", id, "error", err)
+		return nil, fmt.Errorf("failed to list rule activations: %w", err)
+	}
+
+	return activations, nil
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RuleWindowCheckpoint is a replica's saved in-memory rule window state
+type RuleWindowCheckpoint struct {
+	Instance       string          `db:"instance" json:"instance"`
+	State          json.RawMessage `db:"state" json:"state"`
+	Windows        int             `db:"windows" json:"windows"`
+	CheckpointedAt time.Time       `db:"checkpointed_at" json:"checkpointed_at"`
+}
+
+// RuleWindowRepository handles checkpoints of windowed rule aggregations
+type RuleWindowRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewRuleWindowRepository creates a new rule window repository
+func NewRuleWindowRepository(db *sqlx.DB, logger *slog.Logger) *RuleWindowRepository {
+	return &RuleWindowRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// SaveWindowCheckpoint replaces a replica's checkpoint
+func (r *RuleWindowRepository) SaveWindowCheckpoint(ctx context.Context, checkpoint *RuleWindowCheckpoint) error {
+	checkpoint.CheckpointedAt = time.Now()
+
+	query := `
+		INSERT INTO rule_window_checkpoints (instance, state, windows, checkpointed_at)
+		VALUES (:instance, :state, :windows, :checkpointed_at)
+		ON CONFLICT (instance) DO UPDATE SET
+			state = EXCLUDED.state,
+			windows = EXCLUDED.windows,
+			checkpointed_at = EXCLUDED.checkpointed_at`
+
+	if _, err := r.db.NamedExecContext(ctx, query, checkpoint); err != nil {
+		r.logger.Error("Failed to save rule window checkpoint", "instance", checkpoint.Instance, "error", err)
+		return fmt.Errorf("failed to save rule window checkpoint: %w", err)
+	}
+
+	r.logger.Debug("Rule window checkpoint saved",
+		"instance", checkpoint.Instance,
+		"windows", checkpoint.Windows)
+	return nil
+}
+
+// GetWindowCheckpoint retrieves a replica's checkpoint, or nil if it has none
+func (r *RuleWindowRepository) GetWindowCheckpoint(ctx context.Context, instance string) (*RuleWindowCheckpoint, error) {
+	var checkpoint RuleWindowCheckpoint
+	err := r.db.GetContext(ctx, &checkpoint, `SELECT * FROM rule_window_checkpoints WHERE instance = $1`, instance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to get rule window checkpoint", "instance", instance, "error", err)
+		return nil, fmt.Errorf("failed to get rule window checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
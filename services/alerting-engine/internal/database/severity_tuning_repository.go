@@ -0,0 +1,235 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Severity tuning proposal statuses
+const (
+	SeverityProposalPending  = "pending"
+	SeverityProposalApproved = "approved"
+	SeverityProposalRejected = "rejected"
+)
+
+var (
+	// ErrSeverityGuardrailNotFound is returned when a rule has no severity guard rail
+	ErrSeverityGuardrailNotFound = errors.New("severity guard rail not found")
+	// ErrSeverityProposalNotFound is returned when a severity tuning proposal does not exist
+	ErrSeverityProposalNotFound = errors.New("severity tuning proposal not found")
+	// ErrSeverityProposalReviewed is returned when reviewing a proposal that is no longer pending
+	ErrSeverityProposalReviewed = errors.New("severity tuning proposal has already been reviewed")
+)
+
+// SeverityGuardrail bounds the default severity that tuning may propose for a rule
+type SeverityGuardrail struct {
+	RuleID      string    `db:"rule_id" json:"rule_id"`
+	MinSeverity string    `db:"min_severity" json:"min_severity"`
+	MaxSeverity string    `db:"max_severity" json:"max_severity"`
+	UpdatedBy   string    `db:"updated_by" json:"updated_by"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// SeverityProposal is a set of proposed rule default severities awaiting
+// review. Changes and the simulation are stored as the tuning service
+// produced them.
+type SeverityProposal struct {
+	ID            string          `db:"id" json:"id"`
+	Status        string          `db:"status" json:"status"`
+	Since         time.Time       `db:"since" json:"since"`
+	Changes       json.RawMessage `db:"changes" json:"changes"`
+	Simulation    json.RawMessage `db:"simulation" json:"simulation"`
+	ProposedBy    string          `db:"proposed_by" json:"proposed_by"`
+	ProposedAt    time.Time       `db:"proposed_at" json:"proposed_at"`
+	ReviewedBy    *string         `db:"reviewed_by" json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time      `db:"reviewed_at" json:"reviewed_at,omitempty"`
+	ReviewComment *string         `db:"review_comment" json:"review_comment,omitempty"`
+	UpdatedAt     time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// RuleSeverityCount is the number of alerts a rule raised at one severity
+type RuleSeverityCount struct {
+	RuleID   string `db:"rule_id" json:"rule_id"`
+	Severity string `db:"severity" json:"severity"`
+	Count    int    `db:"count" json:"count"`
+}
+
+// SeverityTuningRepository handles severity guard rails and tuning proposals
+type SeverityTuningRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewSeverityTuningRepository creates a new severity tuning repository
+func NewSeverityTuningRepository(db *sqlx.DB, logger *slog.Logger) *SeverityTuningRepository {
+	return &SeverityTuningRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// UpsertGuardrail creates or replaces a rule's severity guard rail
+func (r *SeverityTuningRepository) UpsertGuardrail(ctx context.Context, guardrail *SeverityGuardrail) (*SeverityGuardrail, error) {
+	query := `
+		INSERT INTO rule_severity_guardrails (
+			rule_id, min_severity, max_severity, updated_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, NOW(), NOW())
+		ON CONFLICT (rule_id) DO UPDATE SET
+			min_severity = EXCLUDED.min_severity,
+			max_severity = EXCLUDED.max_severity,
+			updated_by = EXCLUDED.updated_by
+		RETURNING *`
+
+	var saved SeverityGuardrail
+	if err := r.db.GetContext(ctx, &saved, query,
+		guardrail.RuleID, guardrail.MinSeverity, guardrail.MaxSeverity, guardrail.UpdatedBy); err != nil {
+		r.logger.Error("Failed to save severity guard rail", "rule_id", guardrail.RuleID, "error", err)
+		return nil, fmt.Errorf("failed to save severity guard rail: %w", err)
+	}
+	return &saved, nil
+}
+
+// GetGuardrail retrieves a rule's severity guard rail
+func (r *SeverityTuningRepository) GetGuardrail(ctx context.Context, ruleID string) (*SeverityGuardrail, error) {
+	var guardrail SeverityGuardrail
+	err := r.db.GetContext(ctx, &guardrail, `SELECT * FROM rule_severity_guardrails WHERE rule_id = $1`, ruleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSeverityGuardrailNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get severity guard rail: %w", err)
+	}
+	return &guardrail, nil
+}
+
+// ListGuardrails retrieves every severity guard rail
+func (r *SeverityTuningRepository) ListGuardrails(ctx context.Context) ([]*SeverityGuardrail, error) {
+	var guardrails []*SeverityGuardrail
+	if err := r.db.SelectContext(ctx, &guardrails, `SELECT * FROM rule_severity_guardrails ORDER BY rule_id`); err != nil {
+		return nil, fmt.Errorf("failed to list severity guard rails: %w", err)
+	}
+	return guardrails, nil
+}
+
+// DeleteGuardrail removes a rule's severity guard rail
+func (r *SeverityTuningRepository) DeleteGuardrail(ctx context.Context, ruleID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM rule_severity_guardrails WHERE rule_id = $1`, ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to delete severity guard rail: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrSeverityGuardrailNotFound
+	}
+	return nil
+}
+
+// CreateProposal records a pending severity tuning proposal
+func (r *SeverityTuningRepository) CreateProposal(ctx context.Context, proposal *SeverityProposal) error {
+	query := `
+		INSERT INTO severity_tuning_proposals (
+			id, status, since, changes, simulation, proposed_by, proposed_at, updated_at
+		) VALUES (
+			:id, :status, :since, :changes, :simulation, :proposed_by, :proposed_at, :updated_at
+		)`
+
+	proposal.Status = SeverityProposalPending
+	proposal.ProposedAt = time.Now()
+	proposal.UpdatedAt = proposal.ProposedAt
+
+	if _, err := r.db.NamedExecContext(ctx, query, proposal); err != nil {
+		r.logger.Error("Failed to create severity tuning proposal", "proposal_id", proposal.ID, "error", err)
+		return fmt.Errorf("failed to create severity tuning proposal: %w", err)
+	}
+	return nil
+}
+
+// GetProposal retrieves a severity tuning proposal by ID
+func (r *SeverityTuningRepository) GetProposal(ctx context.Context, id string) (*SeverityProposal, error) {
+	var proposal SeverityProposal
+	err := r.db.GetContext(ctx, &proposal, `SELECT * FROM severity_tuning_proposals WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSeverityProposalNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get severity tuning proposal: %w", err)
+	}
+	return &proposal, nil
+}
+
+// ListProposals retrieves severity tuning proposals, newest first, optionally by status
+func (r *SeverityTuningRepository) ListProposals(ctx context.Context, status string, limit int) ([]*SeverityProposal, error) {
+	query := `
+		SELECT * FROM severity_tuning_proposals
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY proposed_at DESC
+		LIMIT $2`
+
+	var proposals []*SeverityProposal
+	if err := r.db.SelectContext(ctx, &proposals, query, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to list severity tuning proposals: %w", err)
+	}
+	return proposals, nil
+}
+
+// Review moves a pending proposal to approved or rejected. Only one
+// reviewer can win; the others get ErrSeverityProposalReviewed.
+func (r *SeverityTuningRepository) Review(ctx context.Context, id, status, reviewer string, comment *string) error {
+	query := `
+		UPDATE severity_tuning_proposals SET
+			status = $2,
+			reviewed_by = $3,
+			reviewed_at = $4,
+			review_comment = $5
+		WHERE id = $1 AND status = $6`
+
+	result, err := r.db.ExecContext(ctx, query, id, status, reviewer, time.Now(), comment, SeverityProposalPending)
+	if err != nil {
+		r.logger.Error("Failed to review severity tuning proposal", "proposal_id", id, "error", err)
+		return fmt.Errorf("failed to review severity tuning proposal: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrSeverityProposalReviewed
+	}
+	return nil
+}
+
+// UpdateChanges stores the outcome of applying an approved proposal's changes
+func (r *SeverityTuningRepository) UpdateChanges(ctx context.Context, id string, changes json.RawMessage) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE severity_tuning_proposals SET changes = $2 WHERE id = $1`, id, changes); err != nil {
+		return fmt.Errorf("failed to update severity tuning proposal changes: %w", err)
+	}
+	return nil
+}
+
+// CountAlertsBySeverity counts the alerts each rule raised since a time at each severity
+func (r *SeverityTuningRepository) CountAlertsBySeverity(ctx context.Context, since time.Time) ([]*RuleSeverityCount, error) {
+	query := `
+		SELECT rule_id, severity, COUNT(*) AS count
+		FROM alerts
+		WHERE created_at >= $1 AND deleted_at IS NULL
+		GROUP BY rule_id, severity`
+
+	var counts []*RuleSeverityCount
+	if err := r.db.SelectContext(ctx, &counts, query, since); err != nil {
+		return nil, fmt.Errorf("failed to count alerts by severity: %w", err)
+	}
+	return counts, nil
+}
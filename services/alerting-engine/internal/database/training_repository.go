@@ -0,0 +1,284 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// TrainingRepository handles training session and scenario data operations
+type TrainingRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewTrainingRepository creates a new training repository
+func NewTrainingRepository(db *sqlx.DB, logger *slog.Logger) *TrainingRepository {
+	return &TrainingRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// CreateSession creates a training session together with its scenarios
+func (t *TrainingRepository) CreateSession(ctx context.Context, session *TrainingSession, scenarios []*TrainingScenario) error {
+	sessionQuery := `
+		INSERT INTO training_sessions (
+			id, trainee_id, sandbox_tenant_id, status, replay_from, replay_to,
+			severity_filter, scenario_types, scenario_count, answered_count, correct_count,
+			score, created_by, created_at, updated_at
+		) VALUES (
+			:id, :trainee_id, :sandbox_tenant_id, :status, :replay_from, :replay_to,
+			:severity_filter, :scenario_types, :scenario_count, :answered_count, :correct_count,
+			:score, :created_by, :created_at, :updated_at
+		)`
+
+	scenarioQuery := `
+		INSERT INTO training_scenarios (
+			id, session_id, sequence, scenario_type, source_alert_id, source_case_id,
+			anonymized_alert, anonymized_case, historical_disposition, historical_severity, created_at
+		) VALUES (
+			:id, :session_id, :sequence, :scenario_type, :source_alert_id, :source_case_id,
+			:anonymized_alert, :anonymized_case, :historical_disposition, :historical_severity, :created_at
+		)`
+
+	now := time.Now()
+	session.CreatedAt = now
+	session.UpdatedAt = now
+	session.ScenarioCount = len(scenarios)
+
+	err := t.Transaction(func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, sessionQuery, session); err != nil {
+			return fmt.Errorf("failed to insert training session: %w", err)
+		}
+
+		for _, scenario := range scenarios {
+			scenario.SessionID = session.ID
+			scenario.CreatedAt = now
+			if _, err := tx.NamedExecContext(ctx, scenarioQuery, scenario); err != nil {
+				return fmt.Errorf("failed to insert training scenario: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.logger.Error("Failed to create training session", "session_id", session.ID, "error", err)
+		return fmt.Errorf("failed to create training session: %w", err)
+	}
+
+	t.logger.Info("Training session created",
+		"session_id", session.ID,
+		"trainee_id", session.TraineeID,
+		"scenarios", len(scenarios))
+	return nil
+}
+
+// GetSession retrieves a training session by ID
+func (t *TrainingRepository) GetSession(ctx context.Context, id string) (*TrainingSession, error) {
+	query := `SELECT * FROM training_sessions WHERE id = $1`
+
+	var session TrainingSession
+	if err := t.db.GetContext(ctx, &session, query, id); err != nil {
+		t.logger.Error("Failed to get training session", "session_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get training session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// ListSessions retrieves training sessions for a trainee, most recent first
+func (t *TrainingRepository) ListSessions(ctx context.Context, traineeID string, limit int) ([]*TrainingSession, error) {
+	query := `
+		SELECT * FROM training_sessions
+		WHERE ($1 = '' OR trainee_id = $1)
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	var sessions []*TrainingSession
+	if err := t.db.SelectContext(ctx, &sessions, query, traineeID, limit); err != nil {
+		t.logger.Error("Failed to list training sessions", "trainee_id", traineeID, "error", err)
+		return nil, fmt.Errorf("failed to list training sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// ListScenarios retrieves all scenarios of a session in presentation order
+func (t *TrainingRepository) ListScenarios(ctx context.Context, sessionID string) ([]*TrainingScenario, error) {
+	query := `
+		SELECT * FROM training_scenarios
+		WHERE session_id = $1
+		ORDER BY sequence ASC`
+
+	var scenarios []*TrainingScenario
+	if err := t.db.SelectContext(ctx, &scenarios, query, sessionID); err != nil {
+		t.logger.Error("Failed to list training scenarios", "session_id", sessionID, "error", err)
+		return nil, fmt.Errorf("failed to list training scenarios: %w", err)
+	}
+
+	return scenarios, nil
+}
+
+// GetScenario retrieves a single scenario of a session
+func (t *TrainingRepository) GetScenario(ctx context.Context, sessionID, scenarioID string) (*TrainingScenario, error) {
+	query := `SELECT * FROM training_scenarios WHERE id = $1 AND session_id = $2`
+
+	var scenario TrainingScenario
+	if err := t.db.GetContext(ctx, &scenario, query, scenarioID, sessionID); err != nil {
+		t.logger.Error("Failed to get training scenario", "scenario_id", scenarioID, "error", err)
+		return nil, fmt.Errorf("failed to get training scenario: %w", err)
+	}
+
+	return &scenario, nil
+}
+
+// MarkScenarioPresented records the first time a scenario was shown to the trainee
+func (t *TrainingRepository) MarkScenarioPresented(ctx context.Context, scenarioID string) error {
+	query := `
+		UPDATE training_scenarios SET presented_at = NOW()
+		WHERE id = $1 AND presented_at IS NULL`
+
+	if _, err := t.db.ExecContext(ctx, query, scenarioID); err != nil {
+		return fmt.Errorf("failed to mark scenario presented: %w", err)
+	}
+
+	return nil
+}
+
+// RecordAnswer stores the trainee response for a scenario and updates the session score
+func (t *TrainingRepository) RecordAnswer(ctx context.Context, scenario *TrainingScenario, correct bool) error {
+	answerQuery := `
+		UPDATE training_scenarios SET
+			trainee_disposition = :trainee_disposition,
+			trainee_severity = :trainee_severity,
+			trainee_notes = :trainee_notes,
+			points = :points,
+			answered_at = :answered_at
+		WHERE id = :id AND answered_at IS NULL`
+
+	sessionQuery := `
+		UPDATE training_sessions SET
+			answered_count = answered_count + 1,
+			correct_count = correct_count + $2,
+			score = (
+				SELECT COALESCE(SUM(points), 0) * 100.0 / NULLIF(COUNT(*), 0)
+				FROM training_scenarios
+				WHERE session_id = $1 AND answered_at IS NOT NULL
+			)
+		WHERE id = $1 AND status = 'active'`
+
+	correctIncrement := 0
+	if correct {
+		correctIncrement = 1
+	}
+
+	err := t.Transaction(func(tx *sqlx.Tx) error {
+		result, err := tx.NamedExecContext(ctx, answerQuery, scenario)
+		if err != nil {
+			return fmt.Errorf("failed to record scenario answer: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("scenario not found or already answered: %s", scenario.ID)
+		}
+
+		if _, err := tx.ExecContext(ctx, sessionQuery, scenario.SessionID, correctIncrement); err != nil {
+			return fmt.Errorf("failed to update session score: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.logger.Error("Failed to record training answer", "scenario_id", scenario.ID, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// CompleteSession closes a session with the given final status
+func (t *TrainingRepository) CompleteSession(ctx context.Context, sessionID, status string) error {
+	query := `
+		UPDATE training_sessions SET
+			status = $2,
+			completed_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1 AND status = 'active'`
+
+	result, err := t.db.ExecContext(ctx, query, sessionID, status)
+	if err != nil {
+		t.logger.Error("Failed to complete training session", "session_id", sessionID, "error", err)
+		return fmt.Errorf("failed to complete training session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("training session not found or already closed: %s", sessionID)
+	}
+
+	t.logger.Info("Training session closed", "session_id", sessionID, "status", status)
+	return nil
+}
+
+// Training types
+
+// TrainingSession represents an analyst training run in a sandbox tenant
+type TrainingSession struct {
+	ID              string         `db:"id" json:"id"`
+	TraineeID       string         `db:"trainee_id" json:"trainee_id"`
+	SandboxTenantID string         `db:"sandbox_tenant_id" json:"sandbox_tenant_id"`
+	Status          string         `db:"status" json:"status"`
+	ReplayFrom      time.Time      `db:"replay_from" json:"replay_from"`
+	ReplayTo        time.Time      `db:"replay_to" json:"replay_to"`
+	SeverityFilter  pq.StringArray `db:"severity_filter" json:"severity_filter,omitempty"`
+	ScenarioTypes   pq.StringArray `db:"scenario_types" json:"scenario_types,omitempty"`
+	ScenarioCount   int            `db:"scenario_count" json:"scenario_count"`
+	AnsweredCount   int            `db:"answered_count" json:"answered_count"`
+	CorrectCount    int            `db:"correct_count" json:"correct_count"`
+	Score           float64        `db:"score" json:"score"`
+	CreatedBy       string         `db:"created_by" json:"created_by"`
+	CreatedAt       time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time      `db:"updated_at" json:"updated_at"`
+	CompletedAt     *time.Time     `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+// Training scenario types
+const (
+	TrainingScenarioAlert = "alert"
+	TrainingScenarioCase  = "case"
+)
+
+// TrainingScenario represents a single replayed, anonymized alert, or an
+// alert case with its member alerts
+type TrainingScenario struct {
+	ID                    string     `db:"id" json:"id"`
+	SessionID             string     `db:"session_id" json:"session_id"`
+	Sequence              int        `db:"sequence" json:"sequence"`
+	ScenarioType          string     `db:"scenario_type" json:"scenario_type"`
+	SourceAlertID         *string    `db:"source_alert_id" json:"-"`
+	SourceCaseID          *string    `db:"source_case_id" json:"-"`
+	AnonymizedAlert       JSONB      `db:"anonymized_alert" json:"alert,omitempty"`
+	AnonymizedCase        JSONB      `db:"anonymized_case" json:"case,omitempty"`
+	HistoricalDisposition string     `db:"historical_disposition" json:"historical_disposition,omitempty"`
+	HistoricalSeverity    *string    `db:"historical_severity" json:"historical_severity,omitempty"`
+	TraineeDisposition    *string    `db:"trainee_disposition" json:"trainee_disposition,omitempty"`
+	TraineeSeverity       *string    `db:"trainee_severity" json:"trainee_severity,omitempty"`
+	TraineeNotes          *string    `db:"trainee_notes" json:"trainee_notes,omitempty"`
+	Points                *float64   `db:"points" json:"points,omitempty"`
+	PresentedAt           *time.Time `db:"presented_at" json:"presented_at,omitempty"`
+	AnsweredAt            *time.Time `db:"answered_at" json:"answered_at,omitempty"`
+	CreatedAt             time.Time  `db:"created_at" json:"created_at"`
+}
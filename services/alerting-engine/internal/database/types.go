@@ -33,6 +33,7 @@ func Connect(cfg config.DatabaseConfig) (*sqlx.DB, error) {
 
 	// Test connection
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
@@ -120,6 +121,8 @@ type Alert struct {
 	Tags             []string               `db:"tags" json:"tags"`
 	Metadata         map[string]interface{} `db:"metadata" json:"metadata"`
 	Fingerprint      string                 `db:"fingerprint" json:"fingerprint"`
+	SuppressedCount  int                    `db:"suppressed_count" json:"suppressed_count"`
+	LastSuppressedAt *time.Time             `db:"last_suppressed_at" json:"last_suppressed_at,omitempty"`
 	CorrelationID    *string                `db:"correlation_id" json:"correlation_id,omitempty"`
 	ParentAlertID    *string                `db:"parent_alert_id" json:"parent_alert_id,omitempty"`
 	EscalationLevel  int                    `db:"escalation_level" json:"escalation_level"`
@@ -129,7 +132,14 @@ type Alert struct {
 	ResolvedAt       *time.Time             `db:"resolved_at" json:"resolved_at,omitempty"`
 	ResolvedBy       *string                `db:"resolved_by" json:"resolved_by,omitempty"`
 	ResolutionReason *string                `db:"resolution_reason" json:"resolution_reason,omitempty"`
+	TriagedAt        *time.Time             `db:"triaged_at" json:"triaged_at,omitempty"`
+	TriagedBy        *string                `db:"triaged_by" json:"triaged_by,omitempty"`
 	AssignedTo       *string                `db:"assigned_to" json:"assigned_to,omitempty"`
+	AssignedAt       *time.Time             `db:"assigned_at" json:"assigned_at,omitempty"`
+	AssignedBy       *string                `db:"assigned_by" json:"assigned_by,omitempty"`
+	ClosedAt         *time.Time             `db:"closed_at" json:"closed_at,omitempty"`
+	ClosedBy         *string                `db:"closed_by" json:"closed_by,omitempty"`
+	SLAExtensionSeconds int                 `db:"sla_extension_seconds" json:"sla_extension_seconds"`
 	ExpiresAt        *time.Time             `db:"expires_at" json:"expires_at,omitempty"`
 	NotificationSent bool                   `db:"notification_sent" json:"notification_sent"`
 	LastNotifiedAt   *time.Time             `db:"last_notified_at" json:"last_notified_at,omitempty"`
@@ -0,0 +1,390 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// WatchlistRepository handles watchlist, exclusion list and change request data operations
+type WatchlistRepository struct {
+	BaseRepository
+	logger *slog.Logger
+}
+
+// NewWatchlistRepository creates a new watchlist repository
+func NewWatchlistRepository(db *sqlx.DB, logger *slog.Logger) *WatchlistRepository {
+	return &WatchlistRepository{
+		BaseRepository: BaseRepository{db: db},
+		logger:         logger,
+	}
+}
+
+// CreateChangeRequest queues a list addition or removal for a second approver
+func (w *WatchlistRepository) CreateChangeRequest(ctx context.Context, change *WatchlistChangeRequest) error {
+	query := `
+		INSERT INTO watchlist_change_requests (
+			id, list_type, action, entity_id, entity_type, entry_id, justification,
+			entry_expires_at, status, requested_by, requested_at, pending_until,
+			created_at, updated_at
+		) VALUES (
+			:id, :list_type, :action, :entity_id, :entity_type, :entry_id, :justification,
+			:entry_expires_at, :status, :requested_by, :requested_at, :pending_until,
+			:created_at, :updated_at
+		)`
+
+	now := time.Now()
+	change.CreatedAt = now
+	change.UpdatedAt = now
+
+	if _, err := w.db.NamedExecContext(ctx, query, change); err != nil {
+		w.logger.Error("Failed to create watchlist change request", "change_id", change.ID, "error", err)
+		return fmt.Errorf("failed to create watchlist change request: %w", err)
+	}
+
+	w.logger.Info("Watchlist change requested",
+		"change_id", change.ID,
+		"list_type", change.ListType,
+		"action", change.Action,
+		"entity_id", change.EntityID,
+		"requested_by", change.RequestedBy)
+	return nil
+}
+
+// GetChangeRequest retrieves a change request by ID
+func (w *WatchlistRepository) GetChangeRequest(ctx context.Context, id string) (*WatchlistChangeRequest, error) {
+	query := `SELECT * FROM watchlist_change_requests WHERE id = $1`
+
+	var change WatchlistChangeRequest
+	if err := w.db.GetContext(ctx, &change, query, id); err != nil {
+		w.logger.Error("Failed to get watchlist change request", "change_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get watchlist change request: %w", err)
+	}
+
+	return &change, nil
+}
+
+// ListChangeRequests retrieves change requests filtered by status and list type, newest first
+func (w *WatchlistRepository) ListChangeRequests(ctx context.Context, status, listType string, limit int) ([]*WatchlistChangeRequest, error) {
+	query := `
+		SELECT * FROM watchlist_change_requests
+		WHERE ($1 = '' OR status = $1)
+		  AND ($2 = '' OR list_type = $2)
+		ORDER BY requested_at DESC
+		LIMIT $3`
+
+	var changes []*WatchlistChangeRequest
+	if err := w.db.SelectContext(ctx, &changes, query, status, listType, limit); err != nil {
+		w.logger.Error("Failed to list watchlist change requests", "status", status, "error", err)
+		return nil, fmt.Errorf("failed to list watchlist change requests: %w", err)
+	}
+
+	return changes, nil
+}
+
+// HasPendingChange reports whether an entity already has a change awaiting approval
+func (w *WatchlistRepository) HasPendingChange(ctx context.Context, listType, entityID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM watchlist_change_requests
+			WHERE list_type = $1 AND entity_id = $2 AND status = 'pending'
+		)`
+
+	var exists bool
+	if err := w.db.GetContext(ctx, &exists, query, listType, entityID); err != nil {
+		return false, fmt.Errorf("failed to check pending watchlist changes: %w", err)
+	}
+
+	return exists, nil
+}
+
+// ApproveChangeRequest records the second approval and applies the change atomically.
+// For additions entry is inserted; for removals the referenced entry is closed.
+func (w *WatchlistRepository) ApproveChangeRequest(ctx context.Context, change *WatchlistChangeRequest, reviewerID, comment string, entry *WatchlistEntry) error {
+	approveQuery := `
+		UPDATE watchlist_change_requests SET
+			status = 'approved',
+			reviewed_by = $2,
+			reviewed_at = NOW(),
+			review_comment = $3,
+			entry_id = COALESCE($4, entry_id),
+			updated_at = NOW()
+		WHERE id = $1 AND status = 'pending' AND requested_by <> $2 AND pending_until > NOW()`
+
+	insertQuery := `
+		INSERT INTO watchlist_entries (
+			id, list_type, entity_id, entity_type, reason, status, expires_at,
+			added_by, approved_by, change_request_id, created_at, updated_at
+		) VALUES (
+			:id, :list_type, :entity_id, :entity_type, :reason, :status, :expires_at,
+			:added_by, :approved_by, :change_request_id, :created_at, :updated_at
+		)`
+
+	removeQuery := `
+		UPDATE watchlist_entries SET
+			status = 'removed',
+			removed_by = $2,
+			removal_approved_by = $3,
+			removed_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1 AND status = 'active'`
+
+	err := w.Transaction(func(tx *sqlx.Tx) error {
+		// Apply the change first so the request can reference the new entry
+		var entryID *string
+		switch change.Action {
+		case WatchlistActionAdd:
+			if entry == nil {
+				return fmt.Errorf("change request %s has no entry to add", change.ID)
+			}
+			now := time.Now()
+			entry.CreatedAt = now
+			entry.UpdatedAt = now
+			if _, err := tx.NamedExecContext(ctx, insertQuery, entry); err != nil {
+				return fmt.Errorf("failed to insert watchlist entry: %w", err)
+			}
+			entryID = &entry.ID
+		case WatchlistActionRemove:
+			if change.EntryID == nil {
+				return fmt.Errorf("change request %s has no entry to remove", change.ID)
+			}
+			result, err := tx.ExecContext(ctx, removeQuery, *change.EntryID, change.RequestedBy, reviewerID)
+			if err != nil {
+				return fmt.Errorf("failed to remove watchlist entry: %w", err)
+			}
+			if rowsAffected, err := result.RowsAffected(); err != nil || rowsAffected == 0 {
+				return fmt.Errorf("watchlist entry %s is no longer active", *change.EntryID)
+			}
+		default:
+			return fmt.Errorf("unknown watchlist action: %s", change.Action)
+		}
+
+		result, err := tx.ExecContext(ctx, approveQuery, change.ID, reviewerID, comment, entryID)
+		if err != nil {
+			return fmt.Errorf("failed to approve change request: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("change request %s is not pending or cannot be approved by %s", change.ID, reviewerID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		w.logger.Error("Failed to approve watchlist change", "change_id", change.ID, "error", err)
+		return err
+	}
+
+	w.logger.Info("Watchlist change approved",
+		"change_id", change.ID,
+		"list_type", change.ListType,
+		"action", change.Action,
+		"entity_id", change.EntityID,
+		"requested_by", change.RequestedBy,
+		"approved_by", reviewerID)
+	return nil
+}
+
+// RejectChangeRequest records a second reviewer's rejection of a pending change
+func (w *WatchlistRepository) RejectChangeRequest(ctx context.Context, id, reviewerID, comment string) error {
+	query := `
+		UPDATE watchlist_change_requests SET
+			status = 'rejected',
+			reviewed_by = $2,
+			reviewed_at = NOW(),
+			review_comment = $3,
+			updated_at = NOW()
+		WHERE id = $1 AND status = 'pending' AND requested_by <> $2`
+
+	return w.closeChangeRequest(ctx, "reject", query, id, reviewerID, comment)
+}
+
+// CancelChangeRequest withdraws a pending change; only the requester may cancel
+func (w *WatchlistRepository) CancelChangeRequest(ctx context.Context, id, requesterID string) error {
+	query := `
+		UPDATE watchlist_change_requests SET
+			status = 'cancelled',
+			updated_at = NOW()
+		WHERE id = $1 AND status = 'pending' AND requested_by = $2`
+
+	return w.closeChangeRequest(ctx, "cancel", query, id, requesterID)
+}
+
+func (w *WatchlistRepository) closeChangeRequest(ctx context.Context, operation, query string, id string, args ...interface{}) error {
+	result, err := w.db.ExecContext(ctx, query, append([]interface{}{id}, args...)...)
+	if err != nil {
+		w.logger.Error("Failed to close watchlist change request", "change_id", id, "operation", operation, "error", err)
+		return fmt.Errorf("failed to %s change request: %w", operation, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("change request %s is not pending or cannot be closed by this user", id)
+	}
+
+	return nil
+}
+
+// LapseStaleChangeRequests closes pending requests that were not reviewed in time
+func (w *WatchlistRepository) LapseStaleChangeRequests(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE watchlist_change_requests SET
+			status = 'lapsed',
+			updated_at = NOW()
+		WHERE status = 'pending' AND pending_until <= NOW()`
+
+	result, err := w.db.ExecContext(ctx, query)
+	if err != nil {
+		w.logger.Error("Failed to lapse stale watchlist change requests", "error", err)
+		return 0, fmt.Errorf("failed to lapse stale change requests: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// GetEntry retrieves a list entry by ID
+func (w *WatchlistRepository) GetEntry(ctx context.Context, id string) (*WatchlistEntry, error) {
+	query := `SELECT * FROM watchlist_entries WHERE id = $1`
+
+	var entry WatchlistEntry
+	if err := w.db.GetContext(ctx, &entry, query, id); err != nil {
+		w.logger.Error("Failed to get watchlist entry", "entry_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get watchlist entry: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// FindActiveEntry retrieves the active entry for an entity on a list, or nil if there is none
+func (w *WatchlistRepository) FindActiveEntry(ctx context.Context, listType, entityID string) (*WatchlistEntry, error) {
+	query := `
+		SELECT * FROM watchlist_entries
+		WHERE list_type = $1 AND entity_id = $2 AND status = 'active'`
+
+	var entries []*WatchlistEntry
+	if err := w.db.SelectContext(ctx, &entries, query, listType, entityID); err != nil {
+		w.logger.Error("Failed to find watchlist entry", "list_type", listType, "entity_id", entityID, "error", err)
+		return nil, fmt.Errorf("failed to find watchlist entry: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return entries[0], nil
+}
+
+// ListEntries retrieves list entries filtered by list type and status
+func (w *WatchlistRepository) ListEntries(ctx context.Context, listType, status string) ([]*WatchlistEntry, error) {
+	query := `
+		SELECT * FROM watchlist_entries
+		WHERE ($1 = '' OR list_type = $1)
+		  AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC`
+
+	var entries []*WatchlistEntry
+	if err := w.db.SelectContext(ctx, &entries, query, listType, status); err != nil {
+		w.logger.Error("Failed to list watchlist entries", "list_type", listType, "error", err)
+		return nil, fmt.Errorf("failed to list watchlist entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ExpireEntries marks active entries past their expiry as expired and returns them
+func (w *WatchlistRepository) ExpireEntries(ctx context.Context) ([]*WatchlistEntry, error) {
+	query := `
+		UPDATE watchlist_entries SET
+			status = 'expired',
+			updated_at = NOW()
+		WHERE status = 'active' AND expires_at IS NOT NULL AND expires_at <= NOW()
+		RETURNING *`
+
+	var entries []*WatchlistEntry
+	if err := w.db.SelectContext(ctx, &entries, query); err != nil {
+		w.logger.Error("Failed to expire watchlist entries", "error", err)
+		return nil, fmt.Errorf("failed to expire watchlist entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Watchlist types
+
+// Watchlist list types
+const (
+	ListTypeWatchlist = "watchlist"
+	ListTypeExclusion = "exclusion"
+)
+
+// Watchlist change actions
+const (
+	WatchlistActionAdd    = "add"
+	WatchlistActionRemove = "remove"
+)
+
+// Watchlist change request statuses
+const (
+	ChangeStatusPending   = "pending"
+	ChangeStatusApproved  = "approved"
+	ChangeStatusRejected  = "rejected"
+	ChangeStatusCancelled = "cancelled"
+	ChangeStatusLapsed    = "lapsed"
+)
+
+// Watchlist entry statuses
+const (
+	EntryStatusActive  = "active"
+	EntryStatusExpired = "expired"
+	EntryStatusRemoved = "removed"
+)
+
+// WatchlistEntry represents an entity on a watchlist or alert exclusion list
+type WatchlistEntry struct {
+	ID                string     `db:"id" json:"id"`
+	ListType          string     `db:"list_type" json:"list_type"`
+	EntityID          string     `db:"entity_id" json:"entity_id"`
+	EntityType        *string    `db:"entity_type" json:"entity_type,omitempty"`
+	Reason            string     `db:"reason" json:"reason"`
+	Status            string     `db:"status" json:"status"`
+	ExpiresAt         *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	AddedBy           string     `db:"added_by" json:"added_by"`
+	ApprovedBy        string     `db:"approved_by" json:"approved_by"`
+	ChangeRequestID   string     `db:"change_request_id" json:"change_request_id"`
+	RemovedBy         *string    `db:"removed_by" json:"removed_by,omitempty"`
+	RemovalApprovedBy *string    `db:"removal_approved_by" json:"removal_approved_by,omitempty"`
+	RemovedAt         *time.Time `db:"removed_at" json:"removed_at,omitempty"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// WatchlistChangeRequest represents a list addition or removal awaiting a second approver
+type WatchlistChangeRequest struct {
+	ID             string     `db:"id" json:"id"`
+	ListType       string     `db:"list_type" json:"list_type"`
+	Action         string     `db:"action" json:"action"`
+	EntityID       string     `db:"entity_id" json:"entity_id"`
+	EntityType     *string    `db:"entity_type" json:"entity_type,omitempty"`
+	EntryID        *string    `db:"entry_id" json:"entry_id,omitempty"`
+	Justification  string     `db:"justification" json:"justification"`
+	EntryExpiresAt *time.Time `db:"entry_expires_at" json:"entry_expires_at,omitempty"`
+	Status         string     `db:"status" json:"status"`
+	RequestedBy    string     `db:"requested_by" json:"requested_by"`
+	RequestedAt    time.Time  `db:"requested_at" json:"requested_at"`
+	PendingUntil   time.Time  `db:"pending_until" json:"pending_until"`
+	ReviewedBy     *string    `db:"reviewed_by" json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time `db:"reviewed_at" json:"reviewed_at,omitempty"`
+	ReviewComment  *string    `db:"review_comment" json:"review_comment,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
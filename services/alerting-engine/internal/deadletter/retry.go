@@ -0,0 +1,167 @@
+// Package deadletter keeps a single bad event from stalling the event
+// consumer. Each event is retried with exponential backoff; events that keep
+// failing, or that can never succeed, are parked on a dead-letter topic and
+// recorded so operators can inspect them and replay them once fixed.
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Message is a consumed event, independent of the Kafka client
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Time      time.Time
+}
+
+// Handler processes one event
+type Handler func(ctx context.Context, message *Message) error
+
+// Sink takes events that failed every processing attempt
+type Sink interface {
+	DeadLetter(ctx context.Context, message *Message, cause error, attempts int) error
+}
+
+// permanentError marks a failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (p *permanentError) Error() string { return p.err.Error() }
+func (p *permanentError) Unwrap() error { return p.err }
+
+// Permanent marks err as a failure that retrying cannot fix, such as a
+// malformed payload. Permanent failures are dead-lettered without retries.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Policy decides how often and how patiently an event is retried
+type Policy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Backoff returns the delay after the given failed attempt, doubling from
+// the initial backoff up to the maximum
+func (p Policy) Backoff(attempt int) time.Duration {
+	if attempt < 1 || p.InitialBackoff <= 0 {
+		return 0
+	}
+
+	delay := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return delay
+}
+
+// Outcome of processing one event
+type Outcome string
+
+const (
+	OutcomeProcessed    Outcome = "processed"
+	OutcomeDeadLettered Outcome = "dead_lettered"
+)
+
+// Result describes how an event was handled. Err is the last processing
+// failure of a dead-lettered event.
+type Result struct {
+	Outcome  Outcome
+	Attempts int
+	Err      error
+}
+
+// Retrier runs events through a handler under a retry policy and hands
+// the ones that keep failing to a sink
+type Retrier struct {
+	policy Policy
+	sink   Sink
+}
+
+// NewRetrier creates a retrier. A policy allowing fewer than one attempt
+// processes each event once.
+func NewRetrier(policy Policy, sink Sink) *Retrier {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	return &Retrier{
+		policy: policy,
+		sink:   sink,
+	}
+}
+
+// Process handles an event until it succeeds, fails permanently or runs out
+// of attempts, then dead-letters it if it failed. Once Process returns nil
+// the event is done with and its offset may be committed. An error means
+// the context ended first and the event must be consumed again.
+func (r *Retrier) Process(ctx context.Context, message *Message, handle Handler) (Result, error) {
+	var err error
+	attempt := 0
+	for attempt < r.policy.MaxAttempts {
+		attempt++
+		if err = handle(ctx, message); err == nil {
+			return Result{Outcome: OutcomeProcessed, Attempts: attempt}, nil
+		}
+		if IsPermanent(err) || attempt == r.policy.MaxAttempts {
+			break
+		}
+		if waitErr := wait(ctx, r.policy.Backoff(attempt)); waitErr != nil {
+			return Result{Attempts: attempt, Err: err}, waitErr
+		}
+	}
+
+	// Parking the event is what unblocks the partition, so keep trying
+	// until the sink takes it or the consumer shuts down
+	for sinkAttempt := 1; ; sinkAttempt++ {
+		sinkErr := r.sink.DeadLetter(ctx, message, err, attempt)
+		if sinkErr == nil {
+			return Result{Outcome: OutcomeDeadLettered, Attempts: attempt, Err: err}, nil
+		}
+		if waitErr := wait(ctx, r.policy.Backoff(sinkAttempt)); waitErr != nil {
+			return Result{Attempts: attempt, Err: err}, sinkErr
+		}
+	}
+}
+
+func wait(ctx context.Context, delay time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,243 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// Headers added to dead-lettered and replayed events
+const (
+	HeaderID              = "x-dead-letter-id"
+	HeaderSourceTopic     = "x-dead-letter-source-topic"
+	HeaderSourcePartition = "x-dead-letter-source-partition"
+	HeaderSourceOffset    = "x-dead-letter-source-offset"
+	HeaderError           = "x-dead-letter-error"
+	HeaderAttempts        = "x-dead-letter-attempts"
+	HeaderReplayOf        = "x-dead-letter-replay-of" // set on replays so a repeat failure links back
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// ErrReplayBatchTooLarge is returned when a bulk replay names more messages than allowed
+var ErrReplayBatchTooLarge = errors.New("too many dead letters in one replay")
+
+// Publisher writes a message to a Kafka topic
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key, value []byte, headers map[string]string) error
+}
+
+// ReplayResult is the outcome of replaying one dead letter in a bulk replay
+type ReplayResult struct {
+	ID         string                      `json:"id"`
+	Replayed   bool                        `json:"replayed"`
+	Error      string                      `json:"error,omitempty"`
+	DeadLetter *database.DeadLetterMessage `json:"dead_letter,omitempty"`
+}
+
+// Service parks failed events on the dead-letter topic, records them, and
+// lets operators replay them to their source topic or discard them
+type Service struct {
+	config    *config.Config
+	logger    *slog.Logger
+	repo      *database.DeadLetterRepository
+	publisher Publisher
+}
+
+// NewService creates a new dead letter service
+func NewService(cfg *config.Config, logger *slog.Logger, repo *database.DeadLetterRepository, publisher Publisher) *Service {
+	return &Service{
+		config:    cfg,
+		logger:    logger,
+		repo:      repo,
+		publisher: publisher,
+	}
+}
+
+// Policy returns the configured consumer retry policy
+func (s *Service) Policy() Policy {
+	return Policy{
+		MaxAttempts:    s.config.Kafka.DeadLetter.MaxAttempts,
+		InitialBackoff: s.config.Kafka.DeadLetter.InitialBackoff,
+		MaxBackoff:     s.config.Kafka.DeadLetter.MaxBackoff,
+	}
+}
+
+// DeadLetter records an event that failed every processing attempt and
+// publishes it to the dead-letter topic. The dead letter's ID is derived
+// from the event's source offset, so retrying after a partial failure
+// neither duplicates the record nor orphans the published copy.
+func (s *Service) DeadLetter(ctx context.Context, message *Message, cause error, attempts int) error {
+	now := time.Now()
+	id := deadLetterID(message)
+
+	headers := make(database.JSONB, len(message.Headers))
+	for key, value := range message.Headers {
+		headers[key] = value
+	}
+
+	record := &database.DeadLetterMessage{
+		ID:              id,
+		SourceTopic:     message.Topic,
+		SourcePartition: message.Partition,
+		SourceOffset:    message.Offset,
+		Payload:         message.Value,
+		Headers:         headers,
+		Error:           errorText(cause),
+		Attempts:        attempts,
+		Status:          database.DeadLetterStatusPending,
+		FailedAt:        now,
+	}
+	if len(message.Key) > 0 {
+		key := string(message.Key)
+		record.MessageKey = &key
+	}
+	if replayOf := message.Headers[HeaderReplayOf]; replayOf != "" {
+		record.ReplayOf = &replayOf
+	}
+	if !message.Time.IsZero() {
+		messageTime := message.Time
+		record.MessageTime = &messageTime
+	}
+
+	created, err := s.repo.Create(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	dlqHeaders := make(map[string]string, len(message.Headers)+6)
+	for key, value := range message.Headers {
+		dlqHeaders[key] = value
+	}
+	dlqHeaders[HeaderID] = id
+	dlqHeaders[HeaderSourceTopic] = message.Topic
+	dlqHeaders[HeaderSourcePartition] = strconv.Itoa(message.Partition)
+	dlqHeaders[HeaderSourceOffset] = strconv.FormatInt(message.Offset, 10)
+	dlqHeaders[HeaderError] = record.Error
+	dlqHeaders[HeaderAttempts] = strconv.Itoa(attempts)
+
+	if err := s.publisher.Publish(ctx, s.config.Kafka.DeadLetter.Topic, message.Key, message.Value, dlqHeaders); err != nil {
+		return fmt.Errorf("failed to publish dead letter: %w", err)
+	}
+
+	if created {
+		s.logger.Warn("Event dead-lettered",
+			"dead_letter_id", id,
+			"topic", message.Topic,
+			"partition", message.Partition,
+			"offset", message.Offset,
+			"attempts", attempts,
+			"error", cause)
+	}
+
+	return nil
+}
+
+// List retrieves dead letters, most recently failed first
+func (s *Service) List(ctx context.Context, filter database.DeadLetterFilter) ([]*database.DeadLetterMessage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Limit > maxListLimit {
+		filter.Limit = maxListLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return s.repo.List(ctx, filter)
+}
+
+// Get retrieves a dead letter by ID
+func (s *Service) Get(ctx context.Context, id string) (*database.DeadLetterMessage, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// Replay republishes a pending dead letter to its source topic so the
+// consumer processes it again. If it fails again it comes back as a new
+// dead letter linked to this one.
+func (s *Service) Replay(ctx context.Context, id, actor string) (*database.DeadLetterMessage, error) {
+	message, err := s.repo.ClaimReplay(ctx, id, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string, len(message.Headers)+1)
+	for key, value := range message.Headers {
+		if text, ok := value.(string); ok {
+			headers[key] = text
+		}
+	}
+	headers[HeaderReplayOf] = message.ID
+
+	var key []byte
+	if message.MessageKey != nil {
+		key = []byte(*message.MessageKey)
+	}
+
+	if err := s.publisher.Publish(ctx, message.SourceTopic, key, message.Payload, headers); err != nil {
+		if releaseErr := s.repo.ReleaseReplay(context.Background(), message.ID); releaseErr != nil {
+			s.logger.Error("Failed to release dead letter after failed replay",
+				"dead_letter_id", message.ID,
+				"error", releaseErr)
+		}
+		return nil, fmt.Errorf("failed to replay dead letter: %w", err)
+	}
+
+	s.logger.Info("Dead letter replayed",
+		"dead_letter_id", message.ID,
+		"topic", message.SourceTopic,
+		"replayed_by", actor)
+
+	return message, nil
+}
+
+// ReplayMany replays several dead letters, reporting the outcome of each
+func (s *Service) ReplayMany(ctx context.Context, ids []string, actor string) ([]ReplayResult, error) {
+	if maxBatch := s.config.Kafka.DeadLetter.MaxReplayBatch; maxBatch > 0 && len(ids) > maxBatch {
+		return nil, fmt.Errorf("%w: %d given, at most %d allowed", ErrReplayBatchTooLarge, len(ids), maxBatch)
+	}
+
+	results := make([]ReplayResult, 0, len(ids))
+	for _, id := range ids {
+		message, err := s.Replay(ctx, id, actor)
+		if err != nil {
+			results = append(results, ReplayResult{ID: id, Error: err.Error()})
+			continue
+		}
+		results = append(results, ReplayResult{ID: id, Replayed: true, DeadLetter: message})
+	}
+
+	return results, nil
+}
+
+// Discard drops a pending dead letter that should not be replayed
+func (s *Service) Discard(ctx context.Context, id, actor string) (*database.DeadLetterMessage, error) {
+	message, err := s.repo.Discard(ctx, id, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Dead letter discarded", "dead_letter_id", id, "discarded_by", actor)
+	return message, nil
+}
+
+func deadLetterID(message *Message) string {
+	return fmt.Sprintf("dlq_%s_%d_%d", message.Topic, message.Partition, message.Offset)
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return "unknown error"
+	}
+	return err.Error()
+}
@@ -0,0 +1,98 @@
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// fieldPattern matches a dotted event path such as "counterparty.account_id"
+var fieldPattern = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+
+// Fingerprint identifies repeats of an alert by its rule, the entities the
+// event concerns in any order, and the values of the fingerprint fields. A
+// field missing from the event fingerprints differently from one set to null.
+func Fingerprint(ruleID string, entityIDs []string, fields []string, event map[string]interface{}) string {
+	h := sha256.New()
+	writePart(h, ruleID)
+
+	entities := uniqueSorted(entityIDs)
+	writePart(h, fmt.Sprint(len(entities)))
+	for _, entityID := range entities {
+		writePart(h, entityID)
+	}
+
+	for _, field := range uniqueSorted(fields) {
+		writePart(h, field)
+		value, ok := lookup(event, field)
+		if !ok {
+			writePart(h, "")
+			continue
+		}
+		// encoding/json sorts map keys, so nested values encode deterministically
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			encoded = []byte(fmt.Sprint(value))
+		}
+		writePart(h, "="+string(encoded))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NormalizeFields trims, validates, deduplicates and sorts fingerprint fields
+func NormalizeFields(fields []string, max int) ([]string, error) {
+	normalized := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if !fieldPattern.MatchString(field) {
+			return nil, fmt.Errorf("%w: fingerprint field %q is not a dotted event path", ErrInvalidSuppression, field)
+		}
+		normalized = append(normalized, field)
+	}
+
+	normalized = uniqueSorted(normalized)
+	if max > 0 && len(normalized) > max {
+		return nil, fmt.Errorf("%w: at most %d fingerprint fields are allowed", ErrInvalidSuppression, max)
+	}
+	return normalized, nil
+}
+
+// lookup resolves a dotted path through nested event maps
+func lookup(event map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = event
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// writePart writes a length-prefixed part so adjacent parts cannot run together
+func writePart(h hash.Hash, part string) {
+	fmt.Fprintf(h, "%d:%s;", len(part), part)
+}
+
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	sort.Strings(unique)
+	return unique
+}
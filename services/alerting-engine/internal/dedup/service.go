@@ -0,0 +1,235 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/engine"
+)
+
+// ErrInvalidSuppression is returned for suppression settings that cannot be applied
+var ErrInvalidSuppression = errors.New("invalid suppression settings")
+
+var (
+	suppressedAlerts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "alerting_engine_alerts_suppressed_total",
+		Help: "Repeated alerts suppressed inside their rule's suppression window, by rule",
+	}, []string{"rule_id"})
+	dedupChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "alerting_engine_dedup_checks_total",
+		Help: "Alert deduplication checks, by result",
+	}, []string{"result"})
+)
+
+// Deduplication check results
+const (
+	resultRaised     = "raised"
+	resultSuppressed = "suppressed"
+	resultDisabled   = "disabled"
+	resultError      = "error"
+)
+
+// UpdateInput replaces a rule's suppression settings
+type UpdateInput struct {
+	Enabled           *bool    `json:"enabled"` // defaults to true
+	WindowSeconds     int      `json:"window_seconds"`
+	FingerprintFields []string `json:"fingerprint_fields"`
+}
+
+// RuleSettings is a rule's effective suppression settings and what they have
+// suppressed recently
+type RuleSettings struct {
+	*database.RuleSuppression
+	Default bool                       `json:"default"` // the rule has no settings of its own and uses the service defaults
+	Stats   *database.SuppressionStats `json:"stats"`
+}
+
+// cachedSettings is a rule's own settings as last loaded; nil settings mean
+// the rule uses the defaults
+type cachedSettings struct {
+	settings *database.RuleSuppression
+	loadedAt time.Time
+}
+
+// Service deduplicates rule alerts. An alert is fingerprinted from its rule,
+// the event's entities and the rule's fingerprint fields; while an unresolved
+// alert with the same fingerprint was raised inside the rule's suppression
+// window, repeats are counted on that alert instead of raised.
+type Service struct {
+	config   *config.Config
+	logger   *slog.Logger
+	repo     *database.RuleSuppressionRepository
+	ruleRepo *database.RuleRepository
+	mu       sync.Mutex
+	cache    map[string]cachedSettings
+}
+
+// NewService creates a new alert deduplication service
+func NewService(cfg *config.Config, logger *slog.Logger, repo *database.RuleSuppressionRepository, ruleRepo *database.RuleRepository) *Service {
+	return &Service{
+		config:   cfg,
+		logger:   logger,
+		repo:     repo,
+		ruleRepo: ruleRepo,
+		cache:    make(map[string]cachedSettings),
+	}
+}
+
+// Suppress fingerprints an alert about to be created and reports whether it
+// repeats an alert inside its rule's suppression window. When settings cannot
+// be loaded the alert is fingerprinted with the defaults and the error returned.
+func (s *Service) Suppress(ctx context.Context, alert *database.Alert, event map[string]interface{}) (bool, error) {
+	settings, err := s.effective(ctx, alert.RuleID)
+	if err != nil {
+		alert.Fingerprint = Fingerprint(alert.RuleID, engine.EventEntityIDs(event), s.defaults(alert.RuleID).FingerprintFields, event)
+		dedupChecks.WithLabelValues(resultError).Inc()
+		return false, err
+	}
+
+	alert.Fingerprint = Fingerprint(alert.RuleID, engine.EventEntityIDs(event), settings.FingerprintFields, event)
+	if !s.config.Dedup.Enabled || !settings.Enabled {
+		dedupChecks.WithLabelValues(resultDisabled).Inc()
+		return false, nil
+	}
+
+	now := time.Now()
+	alertID, err := s.repo.SuppressRepeat(ctx, alert.Fingerprint, now.Add(-settings.Window()), now)
+	if err != nil {
+		dedupChecks.WithLabelValues(resultError).Inc()
+		return false, err
+	}
+	if alertID == "" {
+		dedupChecks.WithLabelValues(resultRaised).Inc()
+		return false, nil
+	}
+
+	dedupChecks.WithLabelValues(resultSuppressed).Inc()
+	suppressedAlerts.WithLabelValues(alert.RuleID).Inc()
+	s.logger.Debug("Alert suppressed as a repeat",
+		"rule_id", alert.RuleID,
+		"alert_id", alertID,
+		"fingerprint", alert.Fingerprint)
+	return true, nil
+}
+
+// Get returns a rule's effective suppression settings with its suppressed counts
+func (s *Service) Get(ctx context.Context, ruleID string) (*RuleSettings, error) {
+	if _, err := s.ruleRepo.GetByID(ctx, ruleID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.repo.Get(ctx, ruleID)
+	isDefault := errors.Is(err, database.ErrSuppressionNotFound)
+	if err != nil && !isDefault {
+		return nil, err
+	}
+	if isDefault {
+		settings = s.defaults(ruleID)
+	}
+
+	stats, err := s.repo.SuppressionStats(ctx, ruleID, time.Now().Add(-s.config.Dedup.StatsWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	return &RuleSettings{RuleSuppression: settings, Default: isDefault, Stats: stats}, nil
+}
+
+// Update validates and saves a rule's own suppression settings
+func (s *Service) Update(ctx context.Context, ruleID string, input UpdateInput, actor string) (*RuleSettings, error) {
+	if _, err := s.ruleRepo.GetByID(ctx, ruleID); err != nil {
+		return nil, err
+	}
+
+	window := time.Duration(input.WindowSeconds) * time.Second
+	if input.WindowSeconds <= 0 {
+		return nil, fmt.Errorf("%w: window_seconds must be positive", ErrInvalidSuppression)
+	}
+	if max := s.config.Dedup.MaxWindow; max > 0 && window > max {
+		return nil, fmt.Errorf("%w: window may not exceed %s", ErrInvalidSuppression, max)
+	}
+	fields, err := NormalizeFields(input.FingerprintFields, s.config.Dedup.MaxFields)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := true
+	if input.Enabled != nil {
+		enabled = *input.Enabled
+	}
+
+	settings := &database.RuleSuppression{
+		RuleID:            ruleID,
+		Enabled:           enabled,
+		WindowSeconds:     input.WindowSeconds,
+		FingerprintFields: fields,
+		UpdatedBy:         actor,
+	}
+	if err := s.repo.Upsert(ctx, settings); err != nil {
+		return nil, err
+	}
+	s.invalidate(ruleID)
+
+	return s.Get(ctx, ruleID)
+}
+
+// Reset removes a rule's own suppression settings so it uses the defaults again
+func (s *Service) Reset(ctx context.Context, ruleID, actor string) error {
+	if err := s.repo.Delete(ctx, ruleID); err != nil {
+		return err
+	}
+	s.invalidate(ruleID)
+
+	s.logger.Info("Rule suppression settings reset to defaults", "rule_id", ruleID, "actor", actor)
+	return nil
+}
+
+// effective returns the settings deduplication applies to a rule, cached for
+// the configured TTL so alert creation rarely reads them from the database
+func (s *Service) effective(ctx context.Context, ruleID string) (*database.RuleSuppression, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[ruleID]
+	s.mu.Unlock()
+
+	if !ok || time.Since(cached.loadedAt) > s.config.Dedup.SettingsCacheTTL {
+		settings, err := s.repo.Get(ctx, ruleID)
+		if err != nil && !errors.Is(err, database.ErrSuppressionNotFound) {
+			return nil, err
+		}
+
+		cached = cachedSettings{settings: settings, loadedAt: time.Now()}
+		s.mu.Lock()
+		s.cache[ruleID] = cached
+		s.mu.Unlock()
+	}
+
+	if cached.settings == nil {
+		return s.defaults(ruleID), nil
+	}
+	return cached.settings, nil
+}
+
+// defaults returns the service-wide suppression settings for a rule
+func (s *Service) defaults(ruleID string) *database.RuleSuppression {
+	return &database.RuleSuppression{
+		RuleID:            ruleID,
+		Enabled:           true,
+		WindowSeconds:     int(s.config.Dedup.DefaultWindow / time.Second),
+		FingerprintFields: s.config.Dedup.DefaultFields,
+	}
+}
+
+func (s *Service) invalidate(ruleID string) {
+	s.mu.Lock()
+	delete(s.cache, ruleID)
+	s.mu.Unlock()
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/evidence"
 )
 
 // EvaluationPool manages concurrent rule evaluations
@@ -73,17 +74,23 @@ func (p *EvaluationPool) worker() {
 
 // CreateAlertHandler handles alert creation actions
 type CreateAlertHandler struct {
-	config    map[string]interface{}
-	alertRepo *database.AlertRepository
-	logger    *slog.Logger
+	config     map[string]interface{}
+	alertRepo  *database.AlertRepository
+	stormGate  StormGate
+	dedup      Deduplicator
+	correlator Correlator
+	logger     *slog.Logger
 }
 
 // NewCreateAlertHandler creates a new alert creation handler
-func NewCreateAlertHandler(config map[string]interface{}, alertRepo *database.AlertRepository, logger *slog.Logger) *CreateAlertHandler {
+func NewCreateAlertHandler(config map[string]interface{}, alertRepo *database.AlertRepository, stormGate StormGate, dedup Deduplicator, correlator Correlator, logger *slog.Logger) *CreateAlertHandler {
 	return &CreateAlertHandler{
-		config:    config,
-		alertRepo: alertRepo,
-		logger:    logger,
+		config:     config,
+		alertRepo:  alertRepo,
+		stormGate:  stormGate,
+		dedup:      dedup,
+		correlator: correlator,
+		logger:     logger,
 	}
 }
 
@@ -119,6 +126,7 @@ func (h *CreateAlertHandler) Execute(ctx context.Context, result *EvaluationResu
 	alert := &database.Alert{
 		ID:          generateID("alert"),
 		RuleID:      result.RuleID,
+		RuleName:    result.RuleName,
 		Title:       title,
 		Description: description,
 		Severity:    severity,
@@ -145,8 +153,48 @@ func (h *CreateAlertHandler) Execute(ctx context.Context, result *EvaluationResu
 		alert.Metadata = metadataBytes
 	}
 
-	// Save alert
-	if err := h.alertRepo.Create(ctx, alert); err != nil {
+	// Repeats inside the rule's suppression window are counted on the earlier alert
+	if h.dedup != nil {
+		suppressed, err := h.dedup.Suppress(ctx, alert, result.Context.Event)
+		if err != nil {
+			h.logger.Error("Alert deduplication failed, raising alert",
+				"rule_id", result.RuleID,
+				"error", err)
+		} else if suppressed {
+			h.logger.Debug("Repeated alert suppressed",
+				"rule_id", result.RuleID,
+				"fingerprint", alert.Fingerprint)
+			return nil
+		}
+	}
+
+	// Capture the evidence bundle so investigators can see exactly why the alert fired
+	bundle, err := evidence.Build(alert.ID, result.Rule, []map[string]interface{}{result.Context.Event},
+		windowValues(result), result.Context.Timestamp)
+	if err != nil {
+		h.logger.Error("Failed to build evidence bundle",
+			"rule_id", result.RuleID,
+			"error", err)
+		return err
+	}
+
+	// During an alert storm only a triage sample is raised individually
+	if h.stormGate != nil {
+		admitted, err := h.stormGate.Admit(ctx, alert, bundle)
+		if err != nil {
+			h.logger.Error("Alert storm check failed, raising alert individually",
+				"rule_id", result.RuleID,
+				"error", err)
+		} else if !admitted {
+			h.logger.Debug("Alert held by alert storm",
+				"alert_id", alert.ID,
+				"rule_id", result.RuleID)
+			return nil
+		}
+	}
+
+	// Save alert together with its evidence
+	if err := h.alertRepo.CreateWithEvidence(ctx, alert, bundle); err != nil {
 		h.logger.Error("Failed to create alert from rule",
 			"rule_id", result.RuleID,
 			"rule_name", result.RuleName,
@@ -160,6 +208,16 @@ func (h *CreateAlertHandler) Execute(ctx context.Context, result *EvaluationResu
 		"rule_name", result.RuleName,
 		"severity", severity)
 
+	// Group the alert with related alerts; the alert stands on its own if this fails
+	if h.correlator != nil {
+		if err := h.correlator.Correlate(ctx, alert, result.Context.Event); err != nil {
+			h.logger.Error("Alert correlation failed",
+				"alert_id", alert.ID,
+				"rule_id", result.RuleID,
+				"error", err)
+		}
+	}
+
 	return nil
 }
 
@@ -168,17 +226,41 @@ func (h *CreateAlertHandler) GetType() string {
 	return "create_alert"
 }
 
+// windowValues collects the computed values the rule was evaluated against
+func windowValues(result *EvaluationResult) map[string]interface{} {
+	values := map[string]interface{}{
+		"historical": result.Context.Historical,
+		"aggregated": result.Context.Aggregated,
+		"metadata":   result.Context.Metadata,
+	}
+	if result.Rule != nil && result.Rule.EvaluationWindow != nil {
+		values["evaluation_window"] = result.Rule.EvaluationWindow.String()
+	}
+	if len(result.Windows) > 0 {
+		values["windows"] = result.Windows
+	}
+	return values
+}
+
 // SendNotificationHandler handles notification sending actions
 type SendNotificationHandler struct {
-	config map[string]interface{}
-	logger *slog.Logger
+	config     map[string]interface{}
+	renderer   NotificationRenderer
+	dispatcher NotificationDispatcher
+	logger     *slog.Logger
 }
 
-// NewSendNotificationHandler creates a new notification handler
-func NewSendNotificationHandler(config map[string]interface{}, logger *slog.Logger) *SendNotificationHandler {
+// NewSendNotificationHandler creates a new notification handler. A nil
+// renderer leaves notifications without a configured subject or message on
+// the built-in text. With a dispatcher, notifications are delivered through
+// the channel providers; an action without a recipient then follows the
+// rule's notification routes.
+func NewSendNotificationHandler(config map[string]interface{}, renderer NotificationRenderer, dispatcher NotificationDispatcher, logger *slog.Logger) *SendNotificationHandler {
 	return &SendNotificationHandler{
-		config: config,
-		logger: logger,
+		config:     config,
+		renderer:   renderer,
+		dispatcher: dispatcher,
+		logger:     logger,
 	}
 }
 
@@ -186,22 +268,28 @@ func NewSendNotificationHandler(config map[string]interface{}, logger *slog.Logg
 func (h *SendNotificationHandler) Execute(ctx context.Context, result *EvaluationResult) error {
 	// Extract notification parameters
 	channel, _ := h.config["channel"].(string)
+	recipient, _ := h.config["recipient"].(string)
+	if h.dispatcher != nil {
+		return h.dispatch(ctx, result, channel, recipient)
+	}
+
 	if channel == "" {
 		channel = "email"
 	}
-
-	recipient, _ := h.config["recipient"].(string)
 	if recipient == "" {
 		h.logger.Error("No recipient specified for notification action")
 		return nil
 	}
 
 	subject, _ := h.config["subject"].(string)
+	message, _ := h.config["message"].(string)
+	if h.renderer != nil && (subject == "" || message == "") {
+		subject, message = h.render(ctx, channel, result, subject, message)
+	}
+
 	if subject == "" {
 		subject = "Alert: " + result.RuleName
 	}
-
-	message, _ := h.config["message"].(string)
 	if message == "" {
 		message = "Rule " + result.RuleName + " has been triggered"
 	}
@@ -250,6 +338,68 @@ func (h *SendNotificationHandler) Execute(ctx context.Context, result *Evaluatio
 	return nil
 }
 
+// dispatch hands the notification to the dispatcher, which renders whatever
+// the action leaves empty per channel it delivers to
+func (h *SendNotificationHandler) dispatch(ctx context.Context, result *EvaluationResult, channel, recipient string) error {
+	if recipient != "" && channel == "" {
+		channel = "email"
+	}
+	subject, _ := h.config["subject"].(string)
+	message, _ := h.config["message"].(string)
+	priority, _ := h.config["priority"].(string)
+	if priority == "" {
+		priority = "medium"
+	}
+
+	rule := result.Rule
+	if rule == nil {
+		rule = &database.Rule{ID: result.RuleID, Name: result.RuleName}
+	}
+	var alert *database.Alert
+	var event map[string]interface{}
+	if result.Context != nil {
+		alert = result.Context.Alert
+		event = result.Context.Event
+	}
+
+	if err := h.dispatcher.DispatchNotification(ctx, rule, alert, event, channel, recipient, subject, message, priority); err != nil {
+		return fmt.Errorf("failed to send notification for rule %s: %w", result.RuleID, err)
+	}
+	return nil
+}
+
+// render fills whichever of subject and message the action left empty from
+// the rule's notification template, keeping them empty if rendering fails
+func (h *SendNotificationHandler) render(ctx context.Context, channel string, result *EvaluationResult, subject, message string) (string, string) {
+	rule := result.Rule
+	if rule == nil {
+		rule = &database.Rule{ID: result.RuleID, Name: result.RuleName}
+	}
+	var alert *database.Alert
+	var event map[string]interface{}
+	if result.Context != nil {
+		alert = result.Context.Alert
+		event = result.Context.Event
+	}
+
+	renderedSubject, renderedMessage, err := h.renderer.RenderNotification(ctx, channel, rule, alert, event)
+	if err != nil {
+		h.logger.Error("Failed to render notification template, using default text",
+			"rule_id", result.RuleID,
+			"channel", channel,
+			"error", err)
+		return subject, message
+	}
+
+	if subject == "" {
+		subject = renderedSubject
+	}
+	if message == "" {
+		message = renderedMessage
+	}
+	return subject, message
+}
+
 // GetType returns the handler type
 func (h *SendNotificationHandler) GetType() string {
 	return "send_notification"
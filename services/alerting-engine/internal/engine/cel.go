@@ -0,0 +1,222 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// Condition languages. Rules name theirs under "language" in their
+// conditions; rules that do not are written in expr.
+const (
+	LanguageExpr = "expr"
+	LanguageCEL  = "cel"
+)
+
+// celInterruptCheckFrequency is how many comprehension iterations a CEL
+// evaluation runs between checks of its deadline
+const celInterruptCheckFrequency = 100
+
+var (
+	// ErrUnsupportedLanguage is returned for conditions in a language the engine does not evaluate
+	ErrUnsupportedLanguage = errors.New("unsupported condition language")
+	// ErrNotBoolean is returned when a condition evaluates to something other than a boolean
+	ErrNotBoolean = errors.New("condition did not return boolean")
+)
+
+// Condition is a compiled condition expression
+type Condition interface {
+	Evaluate(ctx context.Context, env map[string]interface{}) (bool, error)
+}
+
+// exprCondition is a condition compiled by expr
+type exprCondition struct {
+	program *vm.Program
+}
+
+func (c *exprCondition) Evaluate(ctx context.Context, env map[string]interface{}) (bool, error) {
+	result, err := vm.Run(c.program, env)
+	if err != nil {
+		return false, err
+	}
+	matched, ok := result.(bool)
+	if !ok {
+		return false, ErrNotBoolean
+	}
+	return matched, nil
+}
+
+// celCondition is a condition compiled by CEL. Its evaluation is bounded
+// by the program's cost limit and the condition's timeout.
+type celCondition struct {
+	program cel.Program
+	timeout time.Duration
+}
+
+func (c *celCondition) Evaluate(ctx context.Context, env map[string]interface{}) (bool, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	result, _, err := c.program.ContextEval(ctx, celActivation(env))
+	if err != nil {
+		return false, err
+	}
+	matched, ok := result.Value().(bool)
+	if !ok {
+		return false, ErrNotBoolean
+	}
+	return matched, nil
+}
+
+// conditionLanguage resolves the language a rule names for its conditions
+func conditionLanguage(language string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(language)) {
+	case "", LanguageExpr:
+		return LanguageExpr, nil
+	case LanguageCEL:
+		return LanguageCEL, nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnsupportedLanguage, language)
+	}
+}
+
+// compileCondition compiles a condition expression in a language
+func (r *RuleEngine) compileCondition(language, expression string) (Condition, error) {
+	language, err := conditionLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+	if language == LanguageExpr {
+		program, err := expr.Compile(expression)
+		if err != nil {
+			return nil, err
+		}
+		return &exprCondition{program: program}, nil
+	}
+
+	limits := r.config.Rules.CEL
+	if limits.MaxExpressionLength > 0 && len(expression) > limits.MaxExpressionLength {
+		return nil, fmt.Errorf("expression is %d characters, longer than the limit of %d", len(expression), limits.MaxExpressionLength)
+	}
+
+	env, err := r.celEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if output := ast.OutputType(); !output.IsExactType(cel.BoolType) && !output.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression evaluates to %s, not bool", output)
+	}
+
+	options := []cel.ProgramOption{cel.InterruptCheckFrequency(celInterruptCheckFrequency)}
+	if limits.CostLimit > 0 {
+		options = append(options, cel.CostLimit(limits.CostLimit))
+	}
+	program, err := env.Program(ast, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &celCondition{program: program, timeout: limits.EvaluationTimeout}, nil
+}
+
+// celVariables are the variables CEL conditions are checked against, with
+// the value each reads when the evaluation environment lacks it
+var celVariables = []struct {
+	name     string
+	typ      *cel.Type
+	fallback func() interface{}
+}{
+	{"event", cel.MapType(cel.StringType, cel.DynType), emptyObject},
+	{"metadata", cel.MapType(cel.StringType, cel.DynType), emptyObject},
+	{"alert", cel.MapType(cel.StringType, cel.DynType), emptyObject},
+	{"historical", cel.MapType(cel.StringType, cel.DynType), emptyObject},
+	{"aggregated", cel.MapType(cel.StringType, cel.DynType), emptyObject},
+	{"windows", cel.MapType(cel.StringType, cel.DoubleType), func() interface{} { return map[string]float64{} }},
+	{"timestamp", cel.TimestampType, nil},
+	{"now", cel.TimestampType, nil},
+	{"today", cel.TimestampType, nil},
+	{"yesterday", cel.TimestampType, nil},
+}
+
+func emptyObject() interface{} {
+	return map[string]interface{}{}
+}
+
+// celActivation picks the CEL variables out of an evaluation environment
+func celActivation(env map[string]interface{}) map[string]interface{} {
+	activation := make(map[string]interface{}, len(celVariables))
+	for _, variable := range celVariables {
+		if value, ok := env[variable.name]; ok && value != nil {
+			activation[variable.name] = value
+		} else if variable.fallback != nil {
+			activation[variable.name] = variable.fallback()
+		}
+	}
+	return activation
+}
+
+// celEnvironment returns the CEL environment conditions are compiled in,
+// building it on first use. CEL conditions read the same variables as expr
+// conditions, window aggregates through the windows map, and reference data
+// through in_lookup(), threshold() and risk_tier(), which read the engine's
+// reference data when they are called. Fields absent from an event are
+// errors in CEL; conditions guard optional fields with has().
+func (r *RuleEngine) celEnvironment() (*cel.Env, error) {
+	r.celOnce.Do(func() {
+		options := make([]cel.EnvOption, 0, len(celVariables)+4)
+		for _, variable := range celVariables {
+			options = append(options, cel.Variable(variable.name, variable.typ))
+		}
+		options = append(options,
+			cel.CrossTypeNumericComparisons(true),
+			cel.Function("in_lookup",
+				cel.Overload("in_lookup_string_dyn", []*cel.Type{cel.StringType, cel.DynType}, cel.BoolType,
+					cel.BinaryBinding(func(table, value ref.Val) ref.Val {
+						reference := r.reference
+						if reference == nil || value == types.NullValue {
+							return types.False
+						}
+						return types.Bool(reference.InLookup(fmt.Sprint(table.Value()), fmt.Sprint(value.Value())))
+					}))),
+			cel.Function("threshold",
+				cel.Overload("threshold_string", []*cel.Type{cel.StringType}, cel.DoubleType,
+					cel.UnaryBinding(func(name ref.Val) ref.Val {
+						reference := r.reference
+						if reference == nil {
+							return types.Double(math.NaN())
+						}
+						if value, ok := reference.Threshold(fmt.Sprint(name.Value())); ok {
+							return types.Double(value)
+						}
+						return types.Double(math.NaN())
+					}))),
+			cel.Function("risk_tier",
+				cel.Overload("risk_tier_dyn", []*cel.Type{cel.DynType}, cel.StringType,
+					cel.UnaryBinding(func(entityID ref.Val) ref.Val {
+						tiers, ok := r.reference.(RiskTiers)
+						if !ok || entityID == types.NullValue {
+							return types.String("")
+						}
+						tier, _ := tiers.RiskTier(fmt.Sprint(entityID.Value()))
+						return types.String(tier)
+					}))),
+		)
+		r.celEnv, r.celErr = cel.NewEnv(options...)
+	})
+	return r.celEnv, r.celErr
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+)
+
+// DSLVersion is the version of the rule condition language this engine
+// evaluates. Minor versions only add functions, so conditions written for an
+// older minor version of the same major version still compile.
+//
+// 1.1 added in_lookup() and threshold() over lookup tables and named thresholds.
+// 1.2 added risk_tier() over entity risk tiers.
+// 1.3 added window() over the windowed aggregations a rule defines.
+// 1.4 added CEL as a condition language and per-rule test fixtures.
+const DSLVersion = "1.4"
+
+// ReferenceData answers the lookup table and named threshold queries made by
+// rule conditions
+type ReferenceData interface {
+	InLookup(table, value string) bool
+	Threshold(name string) (float64, bool)
+}
+
+// RiskTiers answers the entity risk tier queries made by rule conditions.
+// Reference data that also implements it serves risk_tier().
+type RiskTiers interface {
+	RiskTier(entityID string) (string, bool)
+}
+
+// SetReferenceData sets the lookup tables and thresholds exposed to rule conditions
+func (r *RuleEngine) SetReferenceData(reference ReferenceData) {
+	r.reference = reference
+}
+
+// DSLVersion returns the condition language version this engine evaluates
+func (r *RuleEngine) DSLVersion() string {
+	return DSLVersion
+}
+
+// CompileExpression checks that a condition expression compiles in a
+// language, expr when the language is empty
+func (r *RuleEngine) CompileExpression(language, expression string) error {
+	if _, err := r.compileCondition(language, expression); err != nil {
+		return fmt.Errorf("failed to compile expression: %w", err)
+	}
+	return nil
+}
+
+// addReferenceFunctions exposes reference data to condition expressions. An
+// unknown threshold evaluates to NaN so every comparison against it is false
+// rather than silently comparing against zero, and an entity without a risk
+// tier has the empty tier.
+func (r *RuleEngine) addReferenceFunctions(env map[string]interface{}) {
+	reference := r.reference
+
+	env["in_lookup"] = func(table string, value interface{}) bool {
+		if reference == nil || value == nil {
+			return false
+		}
+		return reference.InLookup(table, fmt.Sprint(value))
+	}
+
+	env["threshold"] = func(name string) float64 {
+		if reference == nil {
+			return math.NaN()
+		}
+		if value, ok := reference.Threshold(name); ok {
+			return value
+		}
+		return math.NaN()
+	}
+
+	env["risk_tier"] = func(entityID interface{}) string {
+		tiers, ok := reference.(RiskTiers)
+		if !ok || entityID == nil {
+			return ""
+		}
+		tier, _ := tiers.RiskTier(fmt.Sprint(entityID))
+		return tier
+	}
+}
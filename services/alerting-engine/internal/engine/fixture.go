@@ -0,0 +1,212 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/rulepack"
+)
+
+var (
+	// ErrInvalidFixture is returned when a rule's test fixtures are malformed
+	ErrInvalidFixture = errors.New("invalid rule test fixture")
+	// ErrFixtureFailed is returned when a rule does not behave as one of its fixtures expects
+	ErrFixtureFailed = errors.New("rule test fixture failed")
+)
+
+// RuleFixture is a unit test carried by a rule: an event, the window
+// aggregates and reference data it is judged against, and whether the rule
+// should match it. Rules list them under "tests" in their conditions:
+//
+//	{"language": "cel",
+//	 "expression": "event.amount > threshold('cash_limit')",
+//	 "tests": [{"name": "over the limit", "event": {"amount": 12000},
+//	            "thresholds": {"cash_limit": 10000}, "expect": true}]}
+//
+// Fixtures are hermetic: they see only the reference data they give, and
+// windows read the aggregates they give, or zero.
+type RuleFixture struct {
+	Name       string                 `json:"name"`
+	Event      map[string]interface{} `json:"event"`
+	Windows    map[string]float64     `json:"windows,omitempty"`
+	Lookups    map[string][]string    `json:"lookups,omitempty"`
+	Thresholds map[string]float64     `json:"thresholds,omitempty"`
+	RiskTiers  map[string]string      `json:"risk_tiers,omitempty"`
+	At         *time.Time             `json:"at,omitempty"` // the evaluation time, now when not given
+	Expect     bool                   `json:"expect"`
+}
+
+// FixtureResult is the outcome of one rule test fixture
+type FixtureResult struct {
+	Name    string `json:"name"`
+	Expect  bool   `json:"expect"`
+	Matched bool   `json:"matched"`
+	Passed  bool   `json:"passed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// FixtureReport is the outcome of every test fixture of a rule
+type FixtureReport struct {
+	Passed   bool             `json:"passed"`
+	Total    int              `json:"total"`
+	Failed   int              `json:"failed"`
+	Fixtures []*FixtureResult `json:"fixtures"`
+}
+
+// ParseFixtures reads and validates the test fixtures of a rule's
+// conditions. Rules without fixtures have none.
+func ParseFixtures(conditions map[string]interface{}) ([]RuleFixture, error) {
+	raw, ok := conditions[rulepack.ConditionTestsKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFixture, err)
+	}
+	var fixtures []RuleFixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("%w: tests must be a list of fixtures: %v", ErrInvalidFixture, err)
+	}
+
+	names := make(map[string]bool, len(fixtures))
+	for i, fixture := range fixtures {
+		if strings.TrimSpace(fixture.Name) == "" {
+			return nil, fmt.Errorf("%w: fixture %d: name is required", ErrInvalidFixture, i)
+		}
+		if fixture.Event == nil {
+			return nil, fmt.Errorf("%w: fixture %q: event is required", ErrInvalidFixture, fixture.Name)
+		}
+		if names[fixture.Name] {
+			return nil, fmt.Errorf("%w: fixture %q is defined more than once", ErrInvalidFixture, fixture.Name)
+		}
+		names[fixture.Name] = true
+	}
+	return fixtures, nil
+}
+
+// TestRule runs the test fixtures of a rule's conditions. The conditions
+// are compiled afresh in an engine of their own, so fixtures neither see
+// nor touch the live engine's reference data, windows or result cache. A
+// fixture whose conditions fail to evaluate fails.
+func (r *RuleEngine) TestRule(ctx context.Context, conditions map[string]interface{}) (*FixtureReport, error) {
+	fixtures, err := ParseFixtures(conditions)
+	if err != nil {
+		return nil, err
+	}
+	if limit := r.config.Rules.CEL.MaxFixtures; limit > 0 && len(fixtures) > limit {
+		return nil, fmt.Errorf("%w: rule has %d fixtures, more than the limit of %d", ErrInvalidFixture, len(fixtures), limit)
+	}
+
+	report := &FixtureReport{Passed: true, Total: len(fixtures), Fixtures: make([]*FixtureResult, 0, len(fixtures))}
+	if len(fixtures) == 0 {
+		return report, nil
+	}
+
+	sandbox := &RuleEngine{config: r.config, logger: r.logger}
+	rule := &CompiledRule{Rule: &database.Rule{ID: "fixture", Conditions: conditions}}
+	if rule.Conditions, rule.Windows, err = sandbox.compileConditions(conditions); err != nil {
+		return nil, err
+	}
+
+	for _, fixture := range fixtures {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result := sandbox.runFixture(ctx, rule, fixture)
+		if !result.Passed {
+			report.Passed = false
+			report.Failed++
+		}
+		report.Fixtures = append(report.Fixtures, result)
+	}
+	return report, nil
+}
+
+// RunFixtures runs the test fixtures of a rule's conditions and returns
+// ErrFixtureFailed naming those that failed
+func (r *RuleEngine) RunFixtures(conditions map[string]interface{}) error {
+	report, err := r.TestRule(context.Background(), conditions)
+	if err != nil {
+		return err
+	}
+	if report.Passed {
+		return nil
+	}
+
+	failed := make([]string, 0, report.Failed)
+	for _, result := range report.Fixtures {
+		if !result.Passed {
+			failed = append(failed, fmt.Sprintf("%q", result.Name))
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrFixtureFailed, strings.Join(failed, ", "))
+}
+
+// runFixture evaluates a compiled rule against one fixture with the
+// fixture's reference data in place
+func (r *RuleEngine) runFixture(ctx context.Context, rule *CompiledRule, fixture RuleFixture) *FixtureResult {
+	result := &FixtureResult{Name: fixture.Name, Expect: fixture.Expect}
+
+	r.reference = fixtureReference{fixture: fixture}
+	defer func() { r.reference = nil }()
+
+	at := time.Now()
+	if fixture.At != nil {
+		at = *fixture.At
+	}
+	evalContext := &EvaluationContext{
+		Event:     fixture.Event,
+		Timestamp: at,
+		Metadata:  make(map[string]interface{}),
+	}
+	if err := r.enrichContext(ctx, evalContext); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	windows := make(map[string]float64, len(rule.Windows))
+	for _, window := range rule.Windows {
+		windows[window.spec.Name] = fixture.Windows[window.spec.Name]
+	}
+
+	matched, err := r.evaluateConditions(ctx, rule, evalContext, windows)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Matched = matched
+	result.Passed = matched == fixture.Expect
+	return result
+}
+
+// fixtureReference serves a fixture's lookup tables, thresholds and risk tiers
+type fixtureReference struct {
+	fixture RuleFixture
+}
+
+func (f fixtureReference) InLookup(table, value string) bool {
+	for _, entry := range f.fixture.Lookups[table] {
+		if entry == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fixtureReference) Threshold(name string) (float64, bool) {
+	value, ok := f.fixture.Thresholds[name]
+	return value, ok
+}
+
+func (f fixtureReference) RiskTier(entityID string) (string, bool) {
+	tier, ok := f.fixture.RiskTiers[entityID]
+	return tier, ok
+}
@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// MatcherRule is a rule given to a Matcher by its condition expressions,
+// the language they are written in and its windowed aggregations
+type MatcherRule struct {
+	ID          string
+	Language    string // expr when empty
+	Expressions []string
+	Windows     []WindowSpec
+}
+
+// Matcher evaluates a fixed set of rules against events with the live
+// engine's condition language, reference data and exclusion list, but
+// without its result cache or actions, so backtests leave no trace in the
+// live engine. Windows are kept in the matcher's own memory, so events must
+// be matched in the order they happened.
+type Matcher struct {
+	engine  *RuleEngine
+	rules   []*CompiledRule
+	windows *MemoryWindowStore
+}
+
+// NewMatcher compiles the condition expressions of each rule
+func (r *RuleEngine) NewMatcher(rules []MatcherRule) (*Matcher, error) {
+	matcher := &Matcher{
+		engine:  r,
+		rules:   make([]*CompiledRule, 0, len(rules)),
+		windows: NewMemoryWindowStore(nil, r.logger, MemoryWindowStoreOptions{MaxEntries: r.config.Rules.Windows.MaxEntriesPerWindow}),
+	}
+
+	for _, rule := range rules {
+		compiledRule := &CompiledRule{
+			Rule:       &database.Rule{ID: rule.ID},
+			Conditions: make([]Condition, 0, len(rule.Expressions)),
+		}
+		for i, expression := range rule.Expressions {
+			condition, err := r.compileCondition(rule.Language, expression)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile condition %d of rule %s: %w", i, rule.ID, err)
+			}
+			compiledRule.Conditions = append(compiledRule.Conditions, condition)
+		}
+		windows, err := r.compileWindows(rule.Language, rule.Windows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile windows of rule %s: %w", rule.ID, err)
+		}
+		compiledRule.Windows = windows
+		matcher.rules = append(matcher.rules, compiledRule)
+	}
+
+	return matcher, nil
+}
+
+// Excluded reports whether live evaluation would suppress an event because
+// it names an entity on the exclusion list
+func (m *Matcher) Excluded(event map[string]interface{}) bool {
+	if m.engine.suppressor == nil {
+		return false
+	}
+	_, excluded := m.engine.suppressor.ExcludedEntity(eventEntityIDs(event))
+	return excluded
+}
+
+// MatchResult lists the rules that matched an event and the errors of those
+// whose conditions could not be evaluated against it
+type MatchResult struct {
+	Matched []string
+	Errors  map[string]error
+}
+
+// Match evaluates every rule against an event that happened at a time.
+// Matched rules are listed in the order the rules were given.
+func (m *Matcher) Match(ctx context.Context, event map[string]interface{}, at time.Time) (*MatchResult, error) {
+	evalContext := &EvaluationContext{
+		Event:     event,
+		Timestamp: at,
+		Metadata:  make(map[string]interface{}),
+	}
+	if err := m.engine.enrichContext(ctx, evalContext); err != nil {
+		return nil, fmt.Errorf("failed to enrich evaluation context: %w", err)
+	}
+
+	result := &MatchResult{}
+	for _, rule := range m.rules {
+		ok := false
+		windows, err := m.engine.aggregateWindows(ctx, m.windows, rule.Rule.ID, rule.Windows, evalContext)
+		if err == nil {
+			ok, err = m.engine.evaluateConditions(ctx, rule, evalContext, windows)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if result.Errors == nil {
+				result.Errors = make(map[string]error)
+			}
+			result.Errors[rule.Rule.ID] = err
+			continue
+		}
+		if ok {
+			result.Matched = append(result.Matched, rule.Rule.ID)
+		}
+	}
+
+	return result, nil
+}
+
+// EventEntityIDs extracts the entity identifiers referenced by an event
+func EventEntityIDs(event map[string]interface{}) []string {
+	return eventEntityIDs(event)
+}
@@ -0,0 +1,437 @@
+package engine
+
+import (
+	"container/list"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+var (
+	referenceCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "alerting_engine_reference_cache_requests_total",
+		Help: "Reference data lookups made by rule conditions, by kind and cache hit or miss",
+	}, []string{"kind", "result"})
+	referenceCacheEvictions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "alerting_engine_reference_cache_evictions_total",
+		Help: "Reference data cache entries dropped, by kind and reason",
+	}, []string{"kind", "reason"})
+	referenceCacheLoadErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "alerting_engine_reference_cache_load_errors_total",
+		Help: "Reference data cache misses that failed to load from the database",
+	}, []string{"kind"})
+	referenceCacheEntries = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "alerting_engine_reference_cache_entries",
+		Help: "Reference data entries currently cached, by kind",
+	}, []string{"kind"})
+)
+
+// Eviction reasons
+const (
+	evictInvalidated = "invalidated"
+	evictCapacity    = "capacity"
+	evictFlush       = "flush"
+)
+
+// pruneInterval is how often the change log is pruned
+const pruneInterval = time.Hour
+
+// ReferenceStore loads reference data on a cache miss and reports the
+// changes the cache invalidates from
+type ReferenceStore interface {
+	GetLookupTable(ctx context.Context, name string) (*database.LookupTable, error)
+	GetThreshold(ctx context.Context, name string) (*database.RuleThreshold, error)
+	GetRiskTier(ctx context.Context, entityID string) (*database.EntityRiskTier, error)
+	ListReferenceChanges(ctx context.Context, since time.Time) ([]*database.ReferenceDataChange, time.Time, error)
+	PruneReferenceChanges(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ReferenceCacheOptions tune the reference data cache
+type ReferenceCacheOptions struct {
+	SyncInterval    time.Duration // how often the change log is read
+	ChangeOverlap   time.Duration // changes are re-read this far back, since commits land out of order
+	ChangeRetention time.Duration // changes are pruned after this; a cache out of sync longer is flushed
+	LoadTimeout     time.Duration
+	MaxLookupTables int // least recently used tables are dropped beyond this; 0 is unbounded
+	MaxRiskTiers    int
+}
+
+// ReferenceCache keeps the lookup tables, thresholds and entity risk tiers
+// rule conditions read in process, loading each on first use. Entries stay
+// cached until the change log shows them updated on any replica; a load
+// that overlaps an invalidation is served but not cached, so a stale value
+// is never kept.
+type ReferenceCache struct {
+	options ReferenceCacheOptions
+	store   ReferenceStore
+	logger  *slog.Logger
+
+	mu          sync.Mutex
+	kinds       map[string]*kindCache
+	generation  uint64 // bumped by every invalidation
+	inflight    map[string]*referenceLoad
+	seen        map[int64]time.Time // applied change versions still inside the overlap window
+	syncedUntil time.Time           // database time the last sync read up to
+	lastSync    time.Time
+	lastPrune   time.Time
+}
+
+type kindCache struct {
+	entries    map[string]*list.Element
+	order      *list.List // most recently used first
+	max        int
+	hits       int64
+	misses     int64
+	evictions  int64
+	loadErrors int64
+}
+
+// referenceEntry is one cached value. Missing data is cached too, so rules
+// referencing an unknown table or entity do not hit the database per event.
+type referenceEntry struct {
+	key     string
+	found   bool
+	entries map[string]struct{} // lookup tables
+	value   float64             // thresholds
+	tier    string              // risk tiers
+}
+
+type referenceLoad struct {
+	done  chan struct{}
+	entry *referenceEntry
+	err   error
+}
+
+// ReferenceCacheStats describes the cache for the engine stats endpoint
+type ReferenceCacheStats struct {
+	Kinds      map[string]ReferenceKindStats `json:"kinds"`
+	Generation uint64                        `json:"generation"`
+	LastSync   *time.Time                    `json:"last_sync,omitempty"`
+}
+
+// ReferenceKindStats counts cache activity for one kind of reference data
+type ReferenceKindStats struct {
+	Entries    int     `json:"entries"`
+	Hits       int64   `json:"hits"`
+	Misses     int64   `json:"misses"`
+	HitRatio   float64 `json:"hit_ratio"`
+	Evictions  int64   `json:"evictions"`
+	LoadErrors int64   `json:"load_errors"`
+}
+
+// NewReferenceCache creates an empty reference data cache
+func NewReferenceCache(store ReferenceStore, logger *slog.Logger, options ReferenceCacheOptions) *ReferenceCache {
+	if options.LoadTimeout <= 0 {
+		options.LoadTimeout = 2 * time.Second
+	}
+	return &ReferenceCache{
+		options: options,
+		store:   store,
+		logger:  logger,
+		kinds: map[string]*kindCache{
+			database.ReferenceKindLookup:    newKindCache(options.MaxLookupTables),
+			database.ReferenceKindThreshold: newKindCache(0),
+			database.ReferenceKindRiskTier:  newKindCache(options.MaxRiskTiers),
+		},
+		inflight: make(map[string]*referenceLoad),
+		seen:     make(map[int64]time.Time),
+	}
+}
+
+func newKindCache(max int) *kindCache {
+	return &kindCache{
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		max:     max,
+	}
+}
+
+// InLookup reports whether value is an entry of the named lookup table
+func (c *ReferenceCache) InLookup(table, value string) bool {
+	entry := c.get(database.ReferenceKindLookup, table)
+	if entry == nil || !entry.found {
+		return false
+	}
+	_, ok := entry.entries[value]
+	return ok
+}
+
+// Threshold returns the value of a named threshold
+func (c *ReferenceCache) Threshold(name string) (float64, bool) {
+	entry := c.get(database.ReferenceKindThreshold, name)
+	if entry == nil || !entry.found {
+		return 0, false
+	}
+	return entry.value, true
+}
+
+// RiskTier returns the risk tier of an entity
+func (c *ReferenceCache) RiskTier(entityID string) (string, bool) {
+	entry := c.get(database.ReferenceKindRiskTier, entityID)
+	if entry == nil || !entry.found {
+		return "", false
+	}
+	return entry.tier, true
+}
+
+// get returns the cached entry for a key, loading it on a miss. Concurrent
+// misses for one key share a single load. Returns nil if the load failed.
+func (c *ReferenceCache) get(kind, key string) *referenceEntry {
+	c.mu.Lock()
+	cache := c.kinds[kind]
+	if element, ok := cache.entries[key]; ok {
+		cache.order.MoveToFront(element)
+		cache.hits++
+		c.mu.Unlock()
+		referenceCacheRequests.WithLabelValues(kind, "hit").Inc()
+		return element.Value.(*referenceEntry)
+	}
+	cache.misses++
+	referenceCacheRequests.WithLabelValues(kind, "miss").Inc()
+
+	loadKey := kind + "\x00" + key
+	if load, ok := c.inflight[loadKey]; ok {
+		c.mu.Unlock()
+		<-load.done
+		if load.err != nil {
+			return nil
+		}
+		return load.entry
+	}
+	load := &referenceLoad{done: make(chan struct{})}
+	c.inflight[loadKey] = load
+	generation := c.generation
+	c.mu.Unlock()
+
+	load.entry, load.err = c.load(kind, key)
+
+	c.mu.Lock()
+	delete(c.inflight, loadKey)
+	if load.err != nil {
+		cache.loadErrors++
+		referenceCacheLoadErrors.WithLabelValues(kind).Inc()
+	} else if c.generation == generation {
+		c.put(kind, cache, load.entry)
+	}
+	c.mu.Unlock()
+	close(load.done)
+
+	if load.err != nil {
+		c.logger.Error("Failed to load rule reference data", "kind", kind, "key", key, "error", load.err)
+		return nil
+	}
+	return load.entry
+}
+
+func (c *ReferenceCache) load(kind, key string) (*referenceEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.options.LoadTimeout)
+	defer cancel()
+
+	entry := &referenceEntry{key: key}
+	switch kind {
+	case database.ReferenceKindLookup:
+		table, err := c.store.GetLookupTable(ctx, key)
+		if err != nil || table == nil {
+			return entry, err
+		}
+		entry.found = true
+		entry.entries = make(map[string]struct{}, len(table.Entries))
+		for _, value := range table.Entries {
+			entry.entries[value] = struct{}{}
+		}
+	case database.ReferenceKindThreshold:
+		threshold, err := c.store.GetThreshold(ctx, key)
+		if err != nil || threshold == nil {
+			return entry, err
+		}
+		entry.found = true
+		entry.value = threshold.Value
+	case database.ReferenceKindRiskTier:
+		tier, err := c.store.GetRiskTier(ctx, key)
+		if err != nil || tier == nil {
+			return entry, err
+		}
+		entry.found = true
+		entry.tier = tier.Tier
+	}
+	return entry, nil
+}
+
+// put caches a loaded entry, dropping the least recently used entries
+// beyond the kind's capacity. Callers hold c.mu.
+func (c *ReferenceCache) put(kind string, cache *kindCache, entry *referenceEntry) {
+	cache.entries[entry.key] = cache.order.PushFront(entry)
+	for cache.max > 0 && cache.order.Len() > cache.max {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*referenceEntry).key)
+		cache.evictions++
+		referenceCacheEvictions.WithLabelValues(kind, evictCapacity).Inc()
+	}
+	referenceCacheEntries.WithLabelValues(kind).Set(float64(cache.order.Len()))
+}
+
+// Invalidate drops one cached entry so the next read reloads it
+func (c *ReferenceCache) Invalidate(kind, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.evict(kind, key, evictInvalidated)
+}
+
+// Flush drops every cached entry
+func (c *ReferenceCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.flush()
+}
+
+func (c *ReferenceCache) flush() {
+	c.generation++
+	for kind, cache := range c.kinds {
+		if count := cache.order.Len(); count > 0 {
+			cache.evictions += int64(count)
+			referenceCacheEvictions.WithLabelValues(kind, evictFlush).Add(float64(count))
+		}
+		cache.entries = make(map[string]*list.Element)
+		cache.order.Init()
+		referenceCacheEntries.WithLabelValues(kind).Set(0)
+	}
+}
+
+// evict drops a cached entry. Callers hold c.mu.
+func (c *ReferenceCache) evict(kind, key, reason string) {
+	cache, ok := c.kinds[kind]
+	if !ok {
+		return
+	}
+	element, ok := cache.entries[key]
+	if !ok {
+		return
+	}
+	cache.order.Remove(element)
+	delete(cache.entries, key)
+	cache.evictions++
+	referenceCacheEvictions.WithLabelValues(kind, reason).Inc()
+	referenceCacheEntries.WithLabelValues(kind).Set(float64(cache.order.Len()))
+}
+
+// Sync reads the change log and invalidates every entry changed since the
+// last sync, on this replica or any other. Changes are re-read for the
+// overlap window, because a change logged earlier can commit later; changes
+// already applied are skipped. A cache that could not sync for longer than
+// the change log is kept may have missed changes, so it is flushed.
+func (c *ReferenceCache) Sync(ctx context.Context) error {
+	c.mu.Lock()
+	since := c.syncedUntil.Add(-c.options.ChangeOverlap)
+	if c.syncedUntil.IsZero() {
+		since = time.Now().Add(-c.options.ChangeOverlap)
+	}
+	missed := !c.lastSync.IsZero() && c.options.ChangeRetention > 0 && time.Since(c.lastSync) > c.options.ChangeRetention
+	c.mu.Unlock()
+
+	changes, now, err := c.store.ListReferenceChanges(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	if missed {
+		c.logger.Warn("Reference data cache was out of sync longer than changes are kept, flushing")
+		c.flush()
+	}
+	applied := 0
+	for _, change := range changes {
+		if _, ok := c.seen[change.Version]; ok {
+			continue
+		}
+		c.seen[change.Version] = change.ChangedAt
+		c.evict(change.Kind, change.Key, evictInvalidated)
+		applied++
+	}
+	if applied > 0 {
+		c.generation++
+	}
+	for version, changedAt := range c.seen {
+		if changedAt.Before(now.Add(-c.options.ChangeOverlap)) {
+			delete(c.seen, version)
+		}
+	}
+	c.syncedUntil = now
+	c.lastSync = time.Now()
+	prune := c.options.ChangeRetention > 0 && time.Since(c.lastPrune) > pruneInterval
+	if prune {
+		c.lastPrune = time.Now()
+	}
+	c.mu.Unlock()
+
+	if prune {
+		if _, err := c.store.PruneReferenceChanges(ctx, now.Add(-c.options.ChangeRetention)); err != nil {
+			c.logger.Warn("Failed to prune reference data changes", "error", err)
+		}
+	}
+	return nil
+}
+
+// Run syncs the cache with the change log until the context ends
+func (c *ReferenceCache) Run(ctx context.Context) error {
+	interval := c.options.SyncInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	if err := c.Sync(ctx); err != nil {
+		c.logger.Warn("Failed to sync reference data cache", "error", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.Sync(ctx); err != nil && ctx.Err() == nil {
+				c.logger.Warn("Failed to sync reference data cache", "error", err)
+			}
+		}
+	}
+}
+
+// Stats returns entry counts and hit/miss counters per kind
+func (c *ReferenceCache) Stats() ReferenceCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := ReferenceCacheStats{
+		Kinds:      make(map[string]ReferenceKindStats, len(c.kinds)),
+		Generation: c.generation,
+	}
+	if !c.lastSync.IsZero() {
+		lastSync := c.lastSync
+		stats.LastSync = &lastSync
+	}
+	for kind, cache := range c.kinds {
+		kindStats := ReferenceKindStats{
+			Entries:    cache.order.Len(),
+			Hits:       cache.hits,
+			Misses:     cache.misses,
+			Evictions:  cache.evictions,
+			LoadErrors: cache.loadErrors,
+		}
+		if total := cache.hits + cache.misses; total > 0 {
+			kindStats.HitRatio = float64(cache.hits) / float64(total)
+		}
+		stats.Kinds[kind] = kindStats
+	}
+	return stats
+}
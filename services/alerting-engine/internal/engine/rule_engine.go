@@ -8,11 +8,11 @@ import (
 	"sync"
 	"time"
 
-	"github.com/antonmedv/expr"
-	"github.com/antonmedv/expr/vm"
+	"github.com/google/cel-go/cel"
 
 	"github.com/aegis-shield/services/alerting-engine/internal/config"
 	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/rulepack"
 )
 
 // RuleEngine evaluates alerting rules against events and data
@@ -28,12 +28,25 @@ type RuleEngine struct {
 	evaluationPool   *EvaluationPool
 	shutdownChan     chan struct{}
 	wg               sync.WaitGroup
+	suppressor       Suppressor
+	reference        ReferenceData
+	stormGate        StormGate
+	deduplicator     Deduplicator
+	renderer         NotificationRenderer
+	dispatcher       NotificationDispatcher
+	correlator       Correlator
+	windowStore      WindowStore
+	metrics          *MetricsCollector
+	celOnce          sync.Once
+	celEnv           *cel.Env
+	celErr           error
 }
 
 // CompiledRule represents a compiled rule for efficient evaluation
 type CompiledRule struct {
 	Rule       *database.Rule
-	Conditions []*vm.Program
+	Conditions []Condition
+	Windows    []*compiledWindow
 	Actions    []ActionHandler
 	LastUsed   time.Time
 }
@@ -59,9 +72,11 @@ type EvaluationContext struct {
 type EvaluationResult struct {
 	RuleID       string
 	RuleName     string
+	Rule         *database.Rule
 	Matched      bool
 	Actions      []string
 	Context      *EvaluationContext
+	Windows      map[string]float64
 	ExecutionTime time.Duration
 	Error        error
 }
@@ -72,6 +87,44 @@ type ActionHandler interface {
 	GetType() string
 }
 
+// Suppressor decides whether events about excluded entities should raise alerts
+type Suppressor interface {
+	ExcludedEntity(entityIDs []string) (string, bool)
+}
+
+// StormGate decides whether an alert is raised individually or held back
+// because its rule is in an alert storm
+type StormGate interface {
+	Admit(ctx context.Context, alert *database.Alert, bundle *database.EvidenceBundle) (bool, error)
+}
+
+// Deduplicator decides whether an alert repeats one its rule recently raised
+// for the same entities and fingerprint fields, in which case the repeat is
+// counted against the earlier alert instead of raised. It sets the alert's
+// fingerprint either way.
+type Deduplicator interface {
+	Suppress(ctx context.Context, alert *database.Alert, event map[string]interface{}) (bool, error)
+}
+
+// Correlator groups a newly raised alert with related earlier alerts. It is
+// consulted after the alert is stored, so failing to correlate never stops
+// an alert being raised.
+type Correlator interface {
+	Correlate(ctx context.Context, alert *database.Alert, event map[string]interface{}) error
+}
+
+// NotificationRenderer renders the subject and body of a rule notification
+// for a channel from the templates configured for the rule
+type NotificationRenderer interface {
+	RenderNotification(ctx context.Context, channel string, rule *database.Rule, alert *database.Alert, event map[string]interface{}) (string, string, error)
+}
+
+// NotificationDispatcher delivers rule notifications through the channel
+// providers, to the given recipient or along the rule's notification routes
+type NotificationDispatcher interface {
+	DispatchNotification(ctx context.Context, rule *database.Rule, alert *database.Alert, event map[string]interface{}, channel, recipient, subject, message, priority string) error
+}
+
 // NewRuleEngine creates a new rule engine
 func NewRuleEngine(
 	cfg *config.Config,
@@ -86,6 +139,7 @@ func NewRuleEngine(
 		alertRepo:       alertRepo,
 		compiledRules:   make(map[string]*CompiledRule),
 		evaluationCache: make(map[string]*CacheEntry),
+		metrics:         NewMetricsCollector(),
 		shutdownChan:    make(chan struct{}),
 	}
 
@@ -125,8 +179,59 @@ func (r *RuleEngine) Stop() {
 	r.logger.Info("Rule engine stopped")
 }
 
+// SetSuppressor sets the exclusion check applied before rule evaluation
+func (r *RuleEngine) SetSuppressor(suppressor Suppressor) {
+	r.suppressor = suppressor
+}
+
+// SetStormGate sets the alert storm check applied before rule alerts are created.
+// It must be set before Start so compiled rules pick it up.
+func (r *RuleEngine) SetStormGate(gate StormGate) {
+	r.stormGate = gate
+}
+
+// SetDeduplicator sets the repeat check applied before rule alerts are created.
+// It must be set before Start so compiled rules pick it up.
+func (r *RuleEngine) SetDeduplicator(deduplicator Deduplicator) {
+	r.deduplicator = deduplicator
+}
+
+// SetCorrelator sets where rule alerts are correlated once created. It must
+// be set before Start so compiled rules pick it up.
+func (r *RuleEngine) SetCorrelator(correlator Correlator) {
+	r.correlator = correlator
+}
+
+// SetNotificationRenderer sets the templates notification actions render
+// their subject and body from when the action does not give them. It must be
+// set before Start so compiled rules pick it up.
+func (r *RuleEngine) SetNotificationRenderer(renderer NotificationRenderer) {
+	r.renderer = renderer
+}
+
+// SetNotificationDispatcher sets where notification actions deliver their
+// notifications. It must be set before Start so compiled rules pick it up.
+func (r *RuleEngine) SetNotificationDispatcher(dispatcher NotificationDispatcher) {
+	r.dispatcher = dispatcher
+}
+
+// EvaluationMetrics returns each rule's evaluations, matches and errors
+// since the engine started
+func (r *RuleEngine) EvaluationMetrics() map[string]*RuleMetrics {
+	return r.metrics.GetMetrics()
+}
+
 // EvaluateEvent evaluates an event against all enabled rules
 func (r *RuleEngine) EvaluateEvent(ctx context.Context, event map[string]interface{}) ([]*EvaluationResult, error) {
+	if r.suppressor != nil {
+		if entityID, excluded := r.suppressor.ExcludedEntity(eventEntityIDs(event)); excluded {
+			r.logger.Info("Event suppressed by exclusion list",
+				"event_id", event["id"],
+				"entity_id", entityID)
+			return nil, nil
+		}
+	}
+
 	r.rulesMutex.RLock()
 	rules := make([]*CompiledRule, 0, len(r.compiledRules))
 	for _, rule := range r.compiledRules {
@@ -165,6 +270,7 @@ func (r *RuleEngine) EvaluateEvent(ctx context.Context, event map[string]interfa
 		default:
 			r.evaluationPool.Submit(func(rule *CompiledRule) {
 				result := r.evaluateRule(ctx, rule, evalContext)
+				r.metrics.RecordEvaluation(result)
 				if result.Error != nil {
 					errorChan <- result.Error
 				} else {
@@ -202,6 +308,27 @@ func (r *RuleEngine) EvaluateEvent(ctx context.Context, event map[string]interfa
 	return matchedResults, nil
 }
 
+// eventEntityIDs extracts the entity identifiers referenced by an event
+func eventEntityIDs(event map[string]interface{}) []string {
+	var ids []string
+	if id, ok := event["entity_id"].(string); ok && id != "" {
+		ids = append(ids, id)
+	}
+
+	switch values := event["entity_ids"].(type) {
+	case []string:
+		ids = append(ids, values...)
+	case []interface{}:
+		for _, value := range values {
+			if id, ok := value.(string); ok && id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
+
 // EvaluateRule evaluates a single rule against an event
 func (r *RuleEngine) evaluateRule(ctx context.Context, compiledRule *CompiledRule, evalContext *EvaluationContext) *EvaluationResult {
 	startTime := time.Now()
@@ -209,12 +336,15 @@ func (r *RuleEngine) evaluateRule(ctx context.Context, compiledRule *CompiledRul
 	result := &EvaluationResult{
 		RuleID:   compiledRule.Rule.ID,
 		RuleName: compiledRule.Rule.Name,
+		Rule:     compiledRule.Rule,
 		Context:  evalContext,
 		Matched:  false,
 	}
 
-	// Check cache first
-	if r.config.Rules.CacheEnabled {
+	// Check cache first; windowed rules are never cached, since every
+	// event they see moves their windows
+	cacheable := r.config.Rules.CacheEnabled && len(compiledRule.Windows) == 0
+	if cacheable {
 		if cached := r.getCachedResult(compiledRule.Rule.ID, evalContext); cached != nil {
 			result.Matched = cached.Result
 			result.ExecutionTime = time.Since(startTime)
@@ -222,8 +352,16 @@ func (r *RuleEngine) evaluateRule(ctx context.Context, compiledRule *CompiledRul
 		}
 	}
 
+	// Record the event in the rule's windows and read their aggregates
+	windows, err := r.aggregateWindows(ctx, r.windowStore, compiledRule.Rule.ID, compiledRule.Windows, evalContext)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to aggregate windows: %w", err)
+		return result
+	}
+	result.Windows = windows
+
 	// Evaluate conditions
-	matched, err := r.evaluateConditions(ctx, compiledRule, evalContext)
+	matched, err := r.evaluateConditions(ctx, compiledRule, evalContext, windows)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to evaluate conditions: %w", err)
 		return result
@@ -233,7 +371,7 @@ func (r *RuleEngine) evaluateRule(ctx context.Context, compiledRule *CompiledRul
 	result.ExecutionTime = time.Since(startTime)
 
 	// Cache result if enabled
-	if r.config.Rules.CacheEnabled && result.Error == nil {
+	if cacheable && result.Error == nil {
 		r.cacheResult(compiledRule.Rule.ID, evalContext, matched, time.Duration(r.config.Rules.CacheTTLSeconds)*time.Second)
 	}
 
@@ -257,14 +395,16 @@ func (r *RuleEngine) evaluateRule(ctx context.Context, compiledRule *CompiledRul
 	return result
 }
 
-// EvaluateConditions evaluates all conditions for a rule
-func (r *RuleEngine) evaluateConditions(ctx context.Context, compiledRule *CompiledRule, evalContext *EvaluationContext) (bool, error) {
+// EvaluateConditions evaluates all conditions for a rule against the event
+// and the rule's window aggregates
+func (r *RuleEngine) evaluateConditions(ctx context.Context, compiledRule *CompiledRule, evalContext *EvaluationContext, windows map[string]float64) (bool, error) {
 	if len(compiledRule.Conditions) == 0 {
 		return true, nil
 	}
 
 	// Create evaluation environment
 	env := r.createEvaluationEnvironment(evalContext)
+	addWindowFunctions(env, windows)
 
 	// Evaluate each condition (AND logic)
 	for i, condition := range compiledRule.Conditions {
@@ -272,16 +412,11 @@ func (r *RuleEngine) evaluateConditions(ctx context.Context, compiledRule *Compi
 		case <-ctx.Done():
 			return false, ctx.Err()
 		default:
-			result, err := vm.Run(condition, env)
+			matched, err := condition.Evaluate(ctx, env)
 			if err != nil {
 				return false, fmt.Errorf("condition %d evaluation failed: %w", i, err)
 			}
 
-			matched, ok := result.(bool)
-			if !ok {
-				return false, fmt.Errorf("condition %d did not return boolean", i)
-			}
-
 			if !matched {
 				return false, nil
 			}
@@ -329,25 +464,15 @@ func (r *RuleEngine) loadRules(ctx context.Context) error {
 func (r *RuleEngine) compileRule(rule *database.Rule) (*CompiledRule, error) {
 	compiledRule := &CompiledRule{
 		Rule:       rule,
-		Conditions: make([]*vm.Program, 0),
+		Conditions: make([]Condition, 0),
 		Actions:    make([]ActionHandler, 0),
 		LastUsed:   time.Now(),
 	}
 
-	// Parse and compile conditions
-	var conditions []map[string]interface{}
-	if err := json.Unmarshal(rule.Conditions, &conditions); err != nil {
-		return nil, fmt.Errorf("failed to parse rule conditions: %w", err)
-	}
-
-	for i, condition := range conditions {
-		if expression, ok := condition["expression"].(string); ok {
-			program, err := expr.Compile(expression)
-			if err != nil {
-				return nil, fmt.Errorf("failed to compile condition %d: %w", i, err)
-			}
-			compiledRule.Conditions = append(compiledRule.Conditions, program)
-		}
+	// Compile conditions and windowed aggregations
+	var err error
+	if compiledRule.Conditions, compiledRule.Windows, err = r.compileConditions(rule.Conditions); err != nil {
+		return nil, err
 	}
 
 	// Parse and compile actions
@@ -371,15 +496,47 @@ func (r *RuleEngine) compileRule(rule *database.Rule) (*CompiledRule, error) {
 	return compiledRule, nil
 }
 
-// CreateEvaluationEnvironment creates the environment for rule evaluation
+// compileConditions compiles a rule's condition expressions and window
+// filters in the language the rule is written in
+func (r *RuleEngine) compileConditions(conditions map[string]interface{}) ([]Condition, []*compiledWindow, error) {
+	language := rulepack.ConditionLanguage(conditions)
+	compiled := make([]Condition, 0)
+	for i, expression := range rulepack.ConditionExpressions(conditions) {
+		condition, err := r.compileCondition(language, expression)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to compile condition %d: %w", i, err)
+		}
+		compiled = append(compiled, condition)
+	}
+
+	windows, err := ParseWindows(conditions)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, window := range windows {
+		if limit := r.config.Rules.Windows.MaxWindowSize; limit > 0 && window.Size > limit {
+			return nil, nil, fmt.Errorf("%w: window %q is longer than %s", ErrInvalidWindow, window.Name, limit)
+		}
+	}
+	compiledWindows, err := r.compileWindows(language, windows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return compiled, compiledWindows, nil
+}
+
+// CreateEvaluationEnvironment creates the environment for rule evaluation.
+// now is the evaluation timestamp rather than the wall clock, so replayed
+// events are judged as of when they happened.
 func (r *RuleEngine) createEvaluationEnvironment(evalContext *EvaluationContext) map[string]interface{} {
 	env := map[string]interface{}{
 		"event":      evalContext.Event,
 		"timestamp":  evalContext.Timestamp,
 		"metadata":   evalContext.Metadata,
-		"now":        time.Now(),
-		"today":      time.Now().Truncate(24 * time.Hour),
-		"yesterday":  time.Now().AddDate(0, 0, -1).Truncate(24 * time.Hour),
+		"now":        evalContext.Timestamp,
+		"today":      evalContext.Timestamp.Truncate(24 * time.Hour),
+		"yesterday":  evalContext.Timestamp.AddDate(0, 0, -1).Truncate(24 * time.Hour),
 	}
 
 	// Add alert data if available
@@ -440,6 +597,8 @@ func (r *RuleEngine) createEvaluationEnvironment(evalContext *EvaluationContext)
 		return strings.Contains(text, pattern)
 	}
 
+	r.addReferenceFunctions(env)
+
 	return env
 }
 
@@ -563,9 +722,9 @@ func (r *RuleEngine) createActionHandler(action map[string]interface{}) (ActionH
 
 	switch actionType {
 	case "create_alert":
-		return NewCreateAlertHandler(action, r.alertRepo, r.logger), nil
+		return NewCreateAlertHandler(action, r.alertRepo, r.stormGate, r.deduplicator, r.correlator, r.logger), nil
 	case "send_notification":
-		return NewSendNotificationHandler(action, r.logger), nil
+		return NewSendNotificationHandler(action, r.renderer, r.dispatcher, r.logger), nil
 	case "webhook":
 		return NewWebhookActionHandler(action, r.logger), nil
 	default:
@@ -590,11 +749,16 @@ func (r *RuleEngine) GetRuleStats() map[string]interface{} {
 			"name":             rule.Rule.Name,
 			"enabled":          rule.Rule.Enabled,
 			"condition_count":  len(rule.Conditions),
+			"window_count":     len(rule.Windows),
 			"action_count":     len(rule.Actions),
 			"last_used":        rule.LastUsed,
 		}
 		stats["rule_details"] = append(stats["rule_details"].([]map[string]interface{}), ruleStats)
 	}
 
+	if cache, ok := r.reference.(*ReferenceCache); ok {
+		stats["reference_cache"] = cache.Stats()
+	}
+
 	return stats
 }
\ No newline at end of file
@@ -0,0 +1,393 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/antonmedv/expr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/rulepack"
+)
+
+var (
+	ruleWindowObservations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "alerting_engine_rule_window_observations_total",
+		Help: "Events evaluated against windowed aggregations, by function and whether the event was recorded",
+	}, []string{"function", "recorded"})
+	ruleWindowErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "alerting_engine_rule_window_errors_total",
+		Help: "Windowed aggregations that could not be read from or written to the window store",
+	})
+)
+
+// Window aggregation functions
+const (
+	WindowCount         = "count"
+	WindowSum           = "sum"
+	WindowDistinctCount = "distinct_count"
+)
+
+// Window types
+const (
+	WindowSliding  = "sliding"
+	WindowTumbling = "tumbling"
+)
+
+// defaultWindowGroupBy is the event field windows are kept per value of
+// when a window does not name one
+const defaultWindowGroupBy = "entity_id"
+
+// ErrInvalidWindow is returned when a rule's window definitions are invalid
+var ErrInvalidWindow = errors.New("invalid rule window")
+
+// WindowSpec defines a windowed aggregation over the events a rule sees,
+// kept separately per value of the group_by field. Rules list them under
+// "windows" in their conditions and read them with window(name):
+//
+//	{"windows": [{"name": "large_transfers", "function": "count",
+//	              "filter": "event.type == 'transfer' && event.amount > 9000",
+//	              "size": "24h"}],
+//	 "expression": "window('large_transfers') > 5"}
+//
+// Filters are written in the rule's condition language; CEL rules read
+// their windows from the windows map, as in windows.large_transfers > 5.
+// Sliding windows cover the size up to each event; tumbling windows cover
+// fixed, epoch-aligned periods of the size.
+type WindowSpec struct {
+	Name     string        `json:"name"`
+	Function string        `json:"function"`
+	Field    string        `json:"field,omitempty"`    // summed for sum, counted distinct for distinct_count
+	GroupBy  string        `json:"group_by,omitempty"` // defaults to entity_id
+	Size     time.Duration `json:"size"`
+	Type     string        `json:"type,omitempty"`   // sliding (default) or tumbling
+	Filter   string        `json:"filter,omitempty"` // only events matching it are aggregated
+}
+
+// UnmarshalJSON reads the window size as a duration string such as "24h"
+func (w *WindowSpec) UnmarshalJSON(data []byte) error {
+	type plain WindowSpec
+	var raw struct {
+		plain
+		Size string `json:"size"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*w = WindowSpec(raw.plain)
+	if raw.Size == "" {
+		return nil
+	}
+	size, err := time.ParseDuration(raw.Size)
+	if err != nil {
+		return fmt.Errorf("invalid window size %q: %w", raw.Size, err)
+	}
+	w.Size = size
+	return nil
+}
+
+// MarshalJSON writes the window size as a duration string
+func (w WindowSpec) MarshalJSON() ([]byte, error) {
+	type plain WindowSpec
+	return json.Marshal(struct {
+		plain
+		Size string `json:"size"`
+	}{plain(w), w.Size.String()})
+}
+
+// ParseWindows reads and validates the window definitions of a rule's
+// conditions. Rules without windows have none. Filters of rules written in
+// a language other than expr are checked when the rule compiles.
+func ParseWindows(conditions map[string]interface{}) ([]WindowSpec, error) {
+	raw, ok := conditions["windows"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWindow, err)
+	}
+	var windows []WindowSpec
+	if err := json.Unmarshal(data, &windows); err != nil {
+		return nil, fmt.Errorf("%w: windows must be a list of window definitions: %v", ErrInvalidWindow, err)
+	}
+
+	language, _ := conditionLanguage(rulepack.ConditionLanguage(conditions))
+	names := make(map[string]bool, len(windows))
+	for i := range windows {
+		window := &windows[i]
+		if window.Type == "" {
+			window.Type = WindowSliding
+		}
+		if window.GroupBy == "" {
+			window.GroupBy = defaultWindowGroupBy
+		}
+		if err := window.validate(language); err != nil {
+			return nil, fmt.Errorf("%w: window %d: %v", ErrInvalidWindow, i, err)
+		}
+		if names[window.Name] {
+			return nil, fmt.Errorf("%w: window %q is defined more than once", ErrInvalidWindow, window.Name)
+		}
+		names[window.Name] = true
+	}
+	return windows, nil
+}
+
+func (w *WindowSpec) validate(language string) error {
+	if strings.TrimSpace(w.Name) == "" {
+		return errors.New("name is required")
+	}
+	switch w.Function {
+	case WindowCount:
+	case WindowSum, WindowDistinctCount:
+		if w.Field == "" {
+			return fmt.Errorf("%s needs a field", w.Function)
+		}
+	default:
+		return fmt.Errorf("unknown function %q", w.Function)
+	}
+	switch w.Type {
+	case WindowSliding, WindowTumbling:
+	default:
+		return fmt.Errorf("unknown window type %q", w.Type)
+	}
+	if w.Size <= 0 {
+		return errors.New("size must be positive")
+	}
+	if w.Filter != "" && language == LanguageExpr {
+		if _, err := expr.Compile(w.Filter); err != nil {
+			return fmt.Errorf("filter does not compile: %v", err)
+		}
+	}
+	return nil
+}
+
+// signature identifies what a window aggregates. Windows are keyed by it,
+// so redefining what a window counts starts it afresh while resizing it
+// keeps what it has seen.
+func (w *WindowSpec) signature() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{w.Function, w.Field, w.GroupBy, w.Type, w.Filter}, "\x00")))
+	return hex.EncodeToString(sum[:6])
+}
+
+// compiledWindow is a window definition with its filter compiled
+type compiledWindow struct {
+	spec      WindowSpec
+	filter    Condition
+	signature string
+}
+
+// compileWindows compiles window filters in the rule's condition language
+func (r *RuleEngine) compileWindows(language string, windows []WindowSpec) ([]*compiledWindow, error) {
+	compiled := make([]*compiledWindow, 0, len(windows))
+	for _, window := range windows {
+		cw := &compiledWindow{spec: window, signature: window.signature()}
+		if window.Filter != "" {
+			filter, err := r.compileCondition(language, window.Filter)
+			if err != nil {
+				return nil, fmt.Errorf("%w: window %q filter: %v", ErrInvalidWindow, window.Name, err)
+			}
+			cw.filter = filter
+		}
+		compiled = append(compiled, cw)
+	}
+	return compiled, nil
+}
+
+// WindowObservation is one event evaluated against one window. The store
+// records it unless Record is false and returns the window's aggregate
+// over the entries in (From, Until].
+type WindowObservation struct {
+	Key      string
+	Function string
+	Entry    string // the event ID, or the distinct value for distinct_count
+	Value    float64
+	At       time.Time
+	From     time.Time
+	Until    time.Time
+	Expires  time.Time // the window can be dropped once no event is this recent
+	Record   bool
+}
+
+// WindowStore keeps the state of windowed aggregations. Entries are keyed,
+// so an event redelivered or retried is not counted twice.
+type WindowStore interface {
+	Aggregate(ctx context.Context, observation WindowObservation) (float64, error)
+}
+
+// SetWindowStore sets where windowed aggregations keep their state. Rules
+// with windows fail to evaluate without one.
+func (r *RuleEngine) SetWindowStore(store WindowStore) {
+	r.windowStore = store
+}
+
+// aggregateWindows records an event in each of a rule's windows it passes
+// the filter of and returns every window's aggregate for the event's group.
+// A window the event has no group for reads NaN, so comparisons against it
+// are false.
+func (r *RuleEngine) aggregateWindows(ctx context.Context, store WindowStore, ruleID string, windows []*compiledWindow, evalContext *EvaluationContext) (map[string]float64, error) {
+	if len(windows) == 0 {
+		return nil, nil
+	}
+	if store == nil {
+		return nil, errors.New("rule has windows but no window store is configured")
+	}
+
+	env := r.createEvaluationEnvironment(evalContext)
+	addWindowFunctions(env, nil)
+
+	values := make(map[string]float64, len(windows))
+	for _, window := range windows {
+		group, ok := eventField(evalContext.Event, window.spec.GroupBy)
+		if !ok {
+			values[window.spec.Name] = math.NaN()
+			continue
+		}
+
+		observation := newWindowObservation(ruleID, window, fmt.Sprint(group), evalContext)
+
+		if window.filter != nil {
+			matched, err := window.filter.Evaluate(ctx, env)
+			if err != nil {
+				return nil, fmt.Errorf("window %q filter evaluation failed: %w", window.spec.Name, err)
+			}
+			if !matched {
+				observation.Record = false
+			}
+		}
+
+		switch window.spec.Function {
+		case WindowSum:
+			value, ok := eventField(evalContext.Event, window.spec.Field)
+			number, isNumber := toFloat(value)
+			if !ok || !isNumber {
+				observation.Record = false
+			}
+			observation.Value = number
+		case WindowDistinctCount:
+			value, ok := eventField(evalContext.Event, window.spec.Field)
+			if !ok || value == nil {
+				observation.Record = false
+			} else {
+				observation.Entry = fmt.Sprint(value)
+			}
+		}
+
+		aggregate, err := store.Aggregate(ctx, observation)
+		if err != nil {
+			ruleWindowErrors.Inc()
+			return nil, fmt.Errorf("window %q: %w", window.spec.Name, err)
+		}
+		ruleWindowObservations.WithLabelValues(window.spec.Function, strconv.FormatBool(observation.Record)).Inc()
+		values[window.spec.Name] = aggregate
+	}
+	return values, nil
+}
+
+// newWindowObservation places an event in a window. Sliding windows end at
+// the event; tumbling windows are the epoch-aligned period containing it.
+func newWindowObservation(ruleID string, window *compiledWindow, group string, evalContext *EvaluationContext) WindowObservation {
+	at := evalContext.Timestamp
+	size := window.spec.Size
+	key := strings.Join([]string{ruleID, window.spec.Name, window.signature, group}, ":")
+
+	observation := WindowObservation{
+		Function: window.spec.Function,
+		Entry:    eventID(evalContext.Event),
+		Value:    1,
+		At:       at,
+		Record:   true,
+	}
+
+	if window.spec.Type == WindowTumbling {
+		start := time.Unix(0, at.UnixNano()-at.UnixNano()%int64(size)).In(at.Location())
+		observation.Key = fmt.Sprintf("%s:%d", key, start.Unix())
+		observation.From = start.Add(-time.Nanosecond)
+		observation.Until = start.Add(size - time.Nanosecond)
+		observation.Expires = start.Add(size)
+		return observation
+	}
+
+	observation.Key = key
+	observation.From = at.Add(-size)
+	observation.Until = at
+	observation.Expires = at.Add(size)
+	return observation
+}
+
+// addWindowFunctions exposes a rule's window aggregates to its conditions.
+// An unknown window reads NaN, like an unknown threshold.
+func addWindowFunctions(env map[string]interface{}, values map[string]float64) {
+	if values == nil {
+		values = map[string]float64{}
+	}
+	env["windows"] = values
+	env["window"] = func(name string) float64 {
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return math.NaN()
+	}
+}
+
+// eventIDSeq numbers events without an ID so each is counted once
+var eventIDSeq uint64
+
+// eventID returns the ID windows key an event's entry by
+func eventID(event map[string]interface{}) string {
+	for _, field := range []string{"id", "event_id"} {
+		if id, ok := event[field]; ok && id != nil && fmt.Sprint(id) != "" {
+			return fmt.Sprint(id)
+		}
+	}
+	return fmt.Sprintf("anon_%d_%d", time.Now().UnixNano(), atomic.AddUint64(&eventIDSeq, 1))
+}
+
+// eventField reads a field of an event, following dots into nested objects
+func eventField(event map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = event
+	for _, part := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
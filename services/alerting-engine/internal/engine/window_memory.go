@@ -0,0 +1,267 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+var (
+	memoryWindows = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "alerting_engine_rule_windows",
+		Help: "Rule aggregation windows held in memory",
+	})
+	windowCheckpointErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "alerting_engine_rule_window_checkpoint_errors_total",
+		Help: "In-memory rule window checkpoints that failed to save",
+	})
+)
+
+// WindowCheckpointStore persists the in-memory window state so a restart
+// does not forget what windows have seen
+type WindowCheckpointStore interface {
+	SaveWindowCheckpoint(ctx context.Context, checkpoint *database.RuleWindowCheckpoint) error
+	GetWindowCheckpoint(ctx context.Context, instance string) (*database.RuleWindowCheckpoint, error)
+}
+
+// MemoryWindowStoreOptions tune the in-memory window store
+type MemoryWindowStoreOptions struct {
+	MaxEntries         int           // oldest entries of a window are dropped beyond this; 0 is unbounded
+	CheckpointInterval time.Duration // how often state is checkpointed and expired windows dropped
+	Instance           string        // the checkpoint this replica saves and restores
+}
+
+// MemoryWindowStore keeps window state in process. It suits a single
+// replica; state is checkpointed to the database periodically and on
+// shutdown and restored at startup, so a restart loses at most one
+// checkpoint interval of events.
+type MemoryWindowStore struct {
+	options     MemoryWindowStoreOptions
+	checkpoints WindowCheckpointStore
+	logger      *slog.Logger
+
+	mu      sync.Mutex
+	windows map[string]*memoryWindow
+}
+
+type memoryWindow struct {
+	Expires time.Time              `json:"expires"`
+	Entries map[string]windowEntry `json:"entries"`
+}
+
+type windowEntry struct {
+	At    time.Time `json:"at"`
+	Value float64   `json:"value"`
+}
+
+// NewMemoryWindowStore creates an empty in-memory window store. A nil
+// checkpoint store keeps state only for the life of the process.
+func NewMemoryWindowStore(checkpoints WindowCheckpointStore, logger *slog.Logger, options MemoryWindowStoreOptions) *MemoryWindowStore {
+	if options.Instance == "" {
+		options.Instance = "default"
+	}
+	return &MemoryWindowStore{
+		options:     options,
+		checkpoints: checkpoints,
+		logger:      logger,
+		windows:     make(map[string]*memoryWindow),
+	}
+}
+
+// Aggregate records an observation and returns its window's aggregate
+func (s *MemoryWindowStore) Aggregate(ctx context.Context, observation WindowObservation) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	window, ok := s.windows[observation.Key]
+	if !ok {
+		if !observation.Record {
+			return 0, nil
+		}
+		window = &memoryWindow{Entries: make(map[string]windowEntry)}
+		s.windows[observation.Key] = window
+		memoryWindows.Set(float64(len(s.windows)))
+	}
+
+	if observation.Record {
+		if entry, ok := window.Entries[observation.Entry]; !ok || entry.At.Before(observation.At) {
+			window.Entries[observation.Entry] = windowEntry{At: observation.At, Value: observation.Value}
+		}
+		if observation.Expires.After(window.Expires) {
+			window.Expires = observation.Expires
+		}
+	}
+
+	for id, entry := range window.Entries {
+		if !entry.At.After(observation.From) {
+			delete(window.Entries, id)
+		}
+	}
+	if s.options.MaxEntries > 0 {
+		for len(window.Entries) > s.options.MaxEntries {
+			window.dropOldest()
+		}
+	}
+
+	var count, sum float64
+	for _, entry := range window.Entries {
+		if entry.At.After(observation.Until) {
+			continue
+		}
+		count++
+		sum += entry.Value
+	}
+	if observation.Function == WindowSum {
+		return sum, nil
+	}
+	return count, nil
+}
+
+func (w *memoryWindow) dropOldest() {
+	var oldestID string
+	var oldest time.Time
+	for id, entry := range w.Entries {
+		if oldestID == "" || entry.At.Before(oldest) {
+			oldestID, oldest = id, entry.At
+		}
+	}
+	delete(w.Entries, oldestID)
+}
+
+// Expire drops windows no event is recent enough to read any more
+func (s *MemoryWindowStore) Expire(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dropped := 0
+	for key, window := range s.windows {
+		if now.After(window.Expires) {
+			delete(s.windows, key)
+			dropped++
+		}
+	}
+	memoryWindows.Set(float64(len(s.windows)))
+	return dropped
+}
+
+// Len returns the number of windows held
+func (s *MemoryWindowStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.windows)
+}
+
+// Snapshot encodes the window state
+func (s *MemoryWindowStore) Snapshot() (json.RawMessage, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := json.Marshal(s.windows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to encode rule windows: %w", err)
+	}
+	return state, len(s.windows), nil
+}
+
+// Load replaces the window state with a snapshot, leaving out windows that
+// have since expired
+func (s *MemoryWindowStore) Load(state json.RawMessage, now time.Time) (int, error) {
+	windows := make(map[string]*memoryWindow)
+	if err := json.Unmarshal(state, &windows); err != nil {
+		return 0, fmt.Errorf("failed to decode rule windows: %w", err)
+	}
+	for key, window := range windows {
+		if now.After(window.Expires) {
+			delete(windows, key)
+		} else if window.Entries == nil {
+			window.Entries = make(map[string]windowEntry)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.windows = windows
+	memoryWindows.Set(float64(len(s.windows)))
+	return len(windows), nil
+}
+
+// Restore loads this replica's last checkpoint. Having none is not an error.
+func (s *MemoryWindowStore) Restore(ctx context.Context) error {
+	if s.checkpoints == nil {
+		return nil
+	}
+
+	checkpoint, err := s.checkpoints.GetWindowCheckpoint(ctx, s.options.Instance)
+	if err != nil {
+		return fmt.Errorf("failed to load rule window checkpoint: %w", err)
+	}
+	if checkpoint == nil {
+		return nil
+	}
+
+	restored, err := s.Load(checkpoint.State, time.Now())
+	if err != nil {
+		return err
+	}
+	s.logger.Info("Rule windows restored from checkpoint",
+		"instance", s.options.Instance,
+		"windows", restored,
+		"checkpointed_at", checkpoint.CheckpointedAt)
+	return nil
+}
+
+// Checkpoint saves the window state
+func (s *MemoryWindowStore) Checkpoint(ctx context.Context) error {
+	if s.checkpoints == nil {
+		return nil
+	}
+
+	state, windows, err := s.Snapshot()
+	if err != nil {
+		return err
+	}
+	return s.checkpoints.SaveWindowCheckpoint(ctx, &database.RuleWindowCheckpoint{
+		Instance: s.options.Instance,
+		State:    state,
+		Windows:  windows,
+	})
+}
+
+// Run drops expired windows and checkpoints the state every interval until
+// the context ends, then checkpoints once more
+func (s *MemoryWindowStore) Run(ctx context.Context) error {
+	interval := s.options.CheckpointInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := s.Checkpoint(shutdownCtx); err != nil {
+				windowCheckpointErrors.Inc()
+				s.logger.Error("Failed to checkpoint rule windows on shutdown", "error", err)
+			}
+			return nil
+		case <-ticker.C:
+			s.Expire(time.Now())
+			if err := s.Checkpoint(ctx); err != nil && ctx.Err() == nil {
+				windowCheckpointErrors.Inc()
+				s.logger.Warn("Failed to checkpoint rule windows", "error", err)
+			}
+		}
+	}
+}
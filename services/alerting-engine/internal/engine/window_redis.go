@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// windowScript records an observation in a window's sorted set, scored by
+// event time in milliseconds, with entry values alongside in a hash, then
+// drops entries at or before the window start and returns the aggregate.
+//
+// KEYS: entries, values
+// ARGV: record, entry, at, value, from, until, expires_at, max_entries, sum
+var windowScript = redis.NewScript(`
+if ARGV[1] == "1" then
+	local current = redis.call("ZSCORE", KEYS[1], ARGV[2])
+	if not current or tonumber(current) < tonumber(ARGV[3]) then
+		redis.call("ZADD", KEYS[1], ARGV[3], ARGV[2])
+		redis.call("HSET", KEYS[2], ARGV[2], ARGV[4])
+	end
+	redis.call("PEXPIREAT", KEYS[1], ARGV[7])
+	redis.call("PEXPIREAT", KEYS[2], ARGV[7])
+end
+
+for _, entry in ipairs(redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[5])) do
+	redis.call("HDEL", KEYS[2], entry)
+end
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[5])
+
+local max = tonumber(ARGV[8])
+if max > 0 then
+	local excess = redis.call("ZCARD", KEYS[1]) - max
+	if excess > 0 then
+		for _, entry in ipairs(redis.call("ZRANGE", KEYS[1], 0, excess - 1)) do
+			redis.call("HDEL", KEYS[2], entry)
+		end
+		redis.call("ZREMRANGEBYRANK", KEYS[1], 0, excess - 1)
+	end
+end
+
+if ARGV[9] ~= "1" then
+	return tostring(redis.call("ZCOUNT", KEYS[1], "(" .. ARGV[5], ARGV[6]))
+end
+local sum = 0
+for _, entry in ipairs(redis.call("ZRANGEBYSCORE", KEYS[1], "(" .. ARGV[5], ARGV[6])) do
+	sum = sum + (tonumber(redis.call("HGET", KEYS[2], entry)) or 0)
+end
+return tostring(sum)
+`)
+
+// RedisWindowStore keeps window state in Redis, shared by every replica.
+// Each window is a sorted set of entries by event time and a hash of their
+// values, both expiring once no event is recent enough to read them.
+type RedisWindowStore struct {
+	client     redis.UniversalClient
+	prefix     string
+	maxEntries int
+}
+
+// NewRedisWindowStore creates a Redis-backed window store. maxEntries bounds
+// each window, dropping the oldest entries beyond it; 0 is unbounded.
+func NewRedisWindowStore(client redis.UniversalClient, prefix string, maxEntries int) *RedisWindowStore {
+	return &RedisWindowStore{
+		client:     client,
+		prefix:     prefix,
+		maxEntries: maxEntries,
+	}
+}
+
+// Aggregate records an observation and returns its window's aggregate
+func (s *RedisWindowStore) Aggregate(ctx context.Context, observation WindowObservation) (float64, error) {
+	// a hash tag keeps a window's keys on one cluster slot
+	key := s.prefix + "{" + observation.Key + "}"
+
+	record := "0"
+	if observation.Record {
+		record = "1"
+	}
+	sum := "0"
+	if observation.Function == WindowSum {
+		sum = "1"
+	}
+
+	result, err := windowScript.Run(ctx, s.client, []string{key + ":entries", key + ":values"},
+		record,
+		observation.Entry,
+		observation.At.UnixMilli(),
+		strconv.FormatFloat(observation.Value, 'f', -1, 64),
+		observation.From.UnixMilli(),
+		observation.Until.UnixMilli(),
+		observation.Expires.UnixMilli(),
+		s.maxEntries,
+		sum,
+	).Text()
+	if err != nil {
+		return 0, fmt.Errorf("failed to aggregate rule window: %w", err)
+	}
+
+	value, err := strconv.ParseFloat(result, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read rule window aggregate %q: %w", result, err)
+	}
+	return value, nil
+}
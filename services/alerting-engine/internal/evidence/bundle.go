@@ -0,0 +1,141 @@
+package evidence
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// Build assembles the evidence bundle for an alert raised by a rule. The
+// rule is snapshotted as evaluated so later edits cannot change the record.
+// Window values that are not finite numbers, like the NaN of a window the
+// event has no group for, are recorded as null.
+func Build(alertID string, rule *database.Rule, events []map[string]interface{}, windowValues map[string]interface{}, evaluatedAt time.Time) (*database.EvidenceBundle, error) {
+	if rule == nil {
+		return nil, errors.New("rule is required for an evidence bundle")
+	}
+	if len(events) == 0 {
+		return nil, errors.New("at least one triggering event is required")
+	}
+	if windowValues == nil {
+		windowValues = map[string]interface{}{}
+	}
+
+	ruleSnapshot, err := json.Marshal(rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot rule: %w", err)
+	}
+	triggerEvents, err := json.Marshal(events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode triggering events: %w", err)
+	}
+	window, err := json.Marshal(finiteValues(windowValues))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode window values: %w", err)
+	}
+
+	bundle := &database.EvidenceBundle{
+		ID:            generateID("evidence"),
+		AlertID:       alertID,
+		RuleID:        rule.ID,
+		RuleName:      rule.Name,
+		RuleVersion:   rule.Version,
+		RuleSnapshot:  ruleSnapshot,
+		TriggerEvents: triggerEvents,
+		WindowValues:  window,
+		// Postgres stores microseconds; truncate so the hash survives a round trip
+		EvaluatedAt: evaluatedAt.UTC().Truncate(time.Microsecond),
+	}
+
+	hash, err := Hash(bundle)
+	if err != nil {
+		return nil, err
+	}
+	bundle.ContentHash = hash
+
+	return bundle, nil
+}
+
+// Hash computes the SHA-256 content hash of a bundle. JSON fields are
+// canonicalized first because JSONB storage does not preserve key order
+// or whitespace.
+func Hash(bundle *database.EvidenceBundle) (string, error) {
+	content := map[string]interface{}{
+		"alert_id":     bundle.AlertID,
+		"rule_id":      bundle.RuleID,
+		"rule_name":    bundle.RuleName,
+		"rule_version": bundle.RuleVersion,
+		"evaluated_at": bundle.EvaluatedAt.UTC().Format(time.RFC3339Nano),
+	}
+
+	for key, raw := range map[string]json.RawMessage{
+		"rule_snapshot":  bundle.RuleSnapshot,
+		"trigger_events": bundle.TriggerEvents,
+		"window_values":  bundle.WindowValues,
+	} {
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return "", fmt.Errorf("failed to decode %s: %w", key, err)
+		}
+		content[key] = value
+	}
+
+	// encoding/json sorts map keys, which makes the encoding canonical
+	canonical, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode evidence bundle: %w", err)
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Verify reports whether a bundle still matches its recorded content hash
+func Verify(bundle *database.EvidenceBundle) (bool, error) {
+	hash, err := Hash(bundle)
+	if err != nil {
+		return false, err
+	}
+	return hash == bundle.ContentHash, nil
+}
+
+// finiteValues replaces NaN and infinite floats, which JSON cannot encode,
+// with nil
+func finiteValues(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+		return v
+	case map[string]float64:
+		values := make(map[string]interface{}, len(v))
+		for key, f := range v {
+			values[key] = finiteValues(f)
+		}
+		return values
+	case map[string]interface{}:
+		values := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			values[key] = finiteValues(item)
+		}
+		return values
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, item := range v {
+			values[i] = finiteValues(item)
+		}
+		return values
+	}
+	return value
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
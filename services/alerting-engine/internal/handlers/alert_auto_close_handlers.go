@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/autoclose"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// AlertAutoCloseHandler handles HTTP requests for the auto-close policies,
+// the alerts they closed and the sampling review of those closures
+type AlertAutoCloseHandler struct {
+	logger  *slog.Logger
+	service *autoclose.Service
+}
+
+// NewAlertAutoCloseHandler creates a new alert auto-close handler
+func NewAlertAutoCloseHandler(logger *slog.Logger, service *autoclose.Service) *AlertAutoCloseHandler {
+	return &AlertAutoCloseHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers alert auto-close routes
+func (h *AlertAutoCloseHandler) RegisterRoutes(router *mux.Router) {
+	autoCloseRouter := router.PathPrefix("/alert-auto-close").Subrouter()
+	autoCloseRouter.HandleFunc("/policies", h.handleListPolicies).Methods("GET")
+	autoCloseRouter.HandleFunc("/run", h.handleRun).Methods("POST")
+	autoCloseRouter.HandleFunc("/report", h.handleReport).Methods("GET")
+	autoCloseRouter.HandleFunc("/closures", h.handleListClosures).Methods("GET")
+	autoCloseRouter.HandleFunc("/closures/{alert_id}/review", h.handleReview).Methods("POST")
+}
+
+func (h *AlertAutoCloseHandler) handleListPolicies(w http.ResponseWriter, r *http.Request) {
+	policies := h.service.Policies()
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"policies": policies,
+		"count":    len(policies),
+	})
+}
+
+// handleRun closes stale alerts now, outside the auto-close schedule
+func (h *AlertAutoCloseHandler) handleRun(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.Run(r.Context())
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to auto-close stale alerts")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, result)
+}
+
+func (h *AlertAutoCloseHandler) handleReport(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := autoclose.ReportQuery{
+		Policy: params.Get("policy"),
+		RuleID: params.Get("rule_id"),
+	}
+
+	var ok bool
+	if query.From, ok = h.timestamp(w, r, "from"); !ok {
+		return
+	}
+	if query.To, ok = h.timestamp(w, r, "to"); !ok {
+		return
+	}
+
+	report, err := h.service.Report(r.Context(), query)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to build auto-close report")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, report)
+}
+
+func (h *AlertAutoCloseHandler) handleListClosures(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	filter := database.AutoClosureFilter{
+		Policy: params.Get("policy"),
+		RuleID: params.Get("rule_id"),
+	}
+
+	var ok bool
+	if filter.From, ok = h.timestamp(w, r, "from"); !ok {
+		return
+	}
+	if filter.To, ok = h.timestamp(w, r, "to"); !ok {
+		return
+	}
+
+	for param, flag := range map[string]*bool{"sampled": &filter.SampledOnly, "pending_review": &filter.PendingReview} {
+		value := params.Get(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			respondError(w, h.logger, http.StatusBadRequest, "Invalid "+param+" flag")
+			return
+		}
+		*flag = parsed
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			respondError(w, h.logger, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	closures, err := h.service.ListClosures(r.Context(), filter)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list auto-closures")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"closures": closures,
+		"count":    len(closures),
+	})
+}
+
+func (h *AlertAutoCloseHandler) handleReview(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var input autoclose.ReviewInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	closure, err := h.service.Review(r.Context(), mux.Vars(r)["alert_id"], input, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to review auto-closure")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, closure)
+}
+
+// timestamp reads an optional RFC3339 timestamp parameter
+func (h *AlertAutoCloseHandler) timestamp(w http.ResponseWriter, r *http.Request, param string) (time.Time, bool) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return time.Time{}, true
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid "+param+" timestamp, expected RFC3339")
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// respondServiceError maps alerts that were not auto-closed to 404, closures
+// already reviewed to 409, invalid reviews and periods to 400 and everything
+// else to 500
+func (h *AlertAutoCloseHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, autoclose.ErrClosureNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, database.ErrAutoClosureReviewed):
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, autoclose.ErrInvalidReview), errors.Is(err, autoclose.ErrInvalidPeriod):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/correlation"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// AlertCaseHandler handles HTTP requests for correlated alert cases
+type AlertCaseHandler struct {
+	logger  *slog.Logger
+	service *correlation.Service
+}
+
+// NewAlertCaseHandler creates a new alert case handler
+func NewAlertCaseHandler(logger *slog.Logger, service *correlation.Service) *AlertCaseHandler {
+	return &AlertCaseHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers alert case routes
+func (h *AlertCaseHandler) RegisterRoutes(router *mux.Router) {
+	caseRouter := router.PathPrefix("/alert-cases").Subrouter()
+	caseRouter.HandleFunc("", h.handleListCases).Methods("GET")
+	caseRouter.HandleFunc("/alerts/{alert_id}", h.handleGetAlertCase).Methods("GET")
+	caseRouter.HandleFunc("/{id}", h.handleGetCase).Methods("GET")
+	caseRouter.HandleFunc("/{id}/link", h.handleLinkCase).Methods("POST")
+	caseRouter.HandleFunc("/{id}/close", h.handleCloseCase).Methods("POST")
+}
+
+func (h *AlertCaseHandler) handleListCases(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = l
+	}
+
+	cases, err := h.service.ListCases(r.Context(), query.Get("status"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list alert cases", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list alert cases")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"cases":       cases,
+		"total_count": len(cases),
+	})
+}
+
+func (h *AlertCaseHandler) handleGetCase(w http.ResponseWriter, r *http.Request) {
+	detail, err := h.service.GetCase(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get alert case")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, detail)
+}
+
+func (h *AlertCaseHandler) handleGetAlertCase(w http.ResponseWriter, r *http.Request) {
+	alertCase, err := h.service.GetAlertCase(r.Context(), mux.Vars(r)["alert_id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get alert case")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, alertCase)
+}
+
+func (h *AlertCaseHandler) handleLinkCase(w http.ResponseWriter, r *http.Request) {
+	var req correlation.LinkInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := correlation.ValidateLinkInput(req); err != nil {
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	result, err := h.service.LinkInvestigation(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to link alert case")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, result)
+}
+
+func (h *AlertCaseHandler) handleCloseCase(w http.ResponseWriter, r *http.Request) {
+	var req correlation.CloseInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Actor == "" {
+		respondError(w, h.logger, http.StatusBadRequest, "actor is required")
+		return
+	}
+
+	alertCase, err := h.service.CloseCase(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to close alert case")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, alertCase)
+}
+
+// respondServiceError maps missing cases to 404, linked or closed cases to 409 and everything else to 500
+func (h *AlertCaseHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrAlertCaseNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, database.ErrAlertCaseNotOpen), errors.Is(err, database.ErrAlertCaseClosed):
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
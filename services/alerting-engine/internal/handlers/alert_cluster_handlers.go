@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/alertcluster"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// AlertClusterHandler handles HTTP requests for alert clustering and investigation bundles
+type AlertClusterHandler struct {
+	logger  *slog.Logger
+	service *alertcluster.Service
+}
+
+// NewAlertClusterHandler creates a new alert cluster handler
+func NewAlertClusterHandler(logger *slog.Logger, service *alertcluster.Service) *AlertClusterHandler {
+	return &AlertClusterHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers alert clustering routes
+func (h *AlertClusterHandler) RegisterRoutes(router *mux.Router) {
+	clusterRouter := router.PathPrefix("/alert-clusters").Subrouter()
+	clusterRouter.HandleFunc("/entity-context", h.handleIngestEntityContexts).Methods("POST")
+	clusterRouter.HandleFunc("/runs", h.handleListRuns).Methods("GET")
+	clusterRouter.HandleFunc("/runs", h.handleRun).Methods("POST")
+	clusterRouter.HandleFunc("/bundles", h.handleListBundles).Methods("GET")
+	clusterRouter.HandleFunc("/bundles/{id}", h.handleGetBundle).Methods("GET")
+	clusterRouter.HandleFunc("/bundles/{id}/accept", h.handleAcceptBundle).Methods("POST")
+	clusterRouter.HandleFunc("/bundles/{id}/dismiss", h.handleDismissBundle).Methods("POST")
+	clusterRouter.HandleFunc("/alerts/{alert_id}/cases", h.handleListAlertCases).Methods("GET")
+}
+
+func (h *AlertClusterHandler) handleIngestEntityContexts(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Entities []*database.EntityContext `json:"entities"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.IngestEntityContexts(r.Context(), req.Entities); err != nil {
+		h.logger.Error("Failed to ingest entity contexts", "count", len(req.Entities), "error", err)
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusAccepted, map[string]interface{}{
+		"success":  true,
+		"ingested": len(req.Entities),
+	})
+}
+
+func (h *AlertClusterHandler) handleRun(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Actor string `json:"actor"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Actor == "" {
+		respondError(w, h.logger, http.StatusBadRequest, "actor is required")
+		return
+	}
+
+	result, err := h.service.Run(r.Context(), req.Actor)
+	if err != nil {
+		if errors.Is(err, database.ErrAlertClusterRunning) {
+			respondError(w, h.logger, http.StatusConflict, err.Error())
+			return
+		}
+		h.logger.Error("Failed to run alert clustering", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to run alert clustering")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, result)
+}
+
+func (h *AlertClusterHandler) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil {
+		limit = l
+	}
+
+	runs, err := h.service.ListRuns(r.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to list alert clustering runs", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list runs")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"runs":        runs,
+		"total_count": len(runs),
+	})
+}
+
+func (h *AlertClusterHandler) handleListBundles(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = l
+	}
+
+	bundles, err := h.service.ListBundles(r.Context(), query.Get("status"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list investigation bundles", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list bundles")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"bundles":     bundles,
+		"total_count": len(bundles),
+	})
+}
+
+func (h *AlertClusterHandler) handleGetBundle(w http.ResponseWriter, r *http.Request) {
+	bundle, err := h.service.GetBundle(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get bundle")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, bundle)
+}
+
+func (h *AlertClusterHandler) handleAcceptBundle(w http.ResponseWriter, r *http.Request) {
+	var req alertcluster.AcceptInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := alertcluster.ValidateAcceptInput(req); err != nil {
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	result, err := h.service.AcceptBundle(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to accept bundle")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, result)
+}
+
+func (h *AlertClusterHandler) handleDismissBundle(w http.ResponseWriter, r *http.Request) {
+	var req alertcluster.DismissInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Actor == "" {
+		respondError(w, h.logger, http.StatusBadRequest, "actor is required")
+		return
+	}
+
+	bundle, err := h.service.DismissBundle(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to dismiss bundle")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, bundle)
+}
+
+func (h *AlertClusterHandler) handleListAlertCases(w http.ResponseWriter, r *http.Request) {
+	links, err := h.service.ListAlertCases(r.Context(), mux.Vars(r)["alert_id"])
+	if err != nil {
+		h.logger.Error("Failed to list alert cases", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list alert cases")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"cases":       links,
+		"total_count": len(links),
+	})
+}
+
+// respondServiceError maps missing bundles to 404, decided bundles to 409 and everything else to 500
+func (h *AlertClusterHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrBundleNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, database.ErrBundleNotProposed):
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
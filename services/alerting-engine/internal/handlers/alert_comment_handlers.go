@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/alertthread"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// AlertCommentHandler serves the discussion thread of each alert. Comments
+// are attributed to the authenticated user.
+type AlertCommentHandler struct {
+	logger  *slog.Logger
+	service *alertthread.Service
+}
+
+// NewAlertCommentHandler creates a new alert comment handler
+func NewAlertCommentHandler(logger *slog.Logger, service *alertthread.Service) *AlertCommentHandler {
+	return &AlertCommentHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers alert comment routes
+func (h *AlertCommentHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/alerts/{id}/comments", h.handleListComments).Methods("GET")
+	router.HandleFunc("/alerts/{id}/comments", h.handlePostComment).Methods("POST")
+	router.HandleFunc("/alerts/{id}/comments/{commentId}", h.handleEditComment).Methods("PUT")
+	router.HandleFunc("/alerts/{id}/comments/{commentId}", h.handleDeleteComment).Methods("DELETE")
+}
+
+func (h *AlertCommentHandler) handleListComments(w http.ResponseWriter, r *http.Request) {
+	alertID := mux.Vars(r)["id"]
+
+	thread, total, err := h.service.Thread(r.Context(), alertID)
+	if err != nil {
+		h.logger.Error("Failed to list alert comments", "alert_id", alertID, "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list alert comments")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"comments":    thread,
+		"total_count": total,
+	})
+}
+
+func (h *AlertCommentHandler) handlePostComment(w http.ResponseWriter, r *http.Request) {
+	alertID := mux.Vars(r)["id"]
+
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var input alertthread.CommentInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	comment, err := h.service.Post(r.Context(), alertID, subject.ID, input)
+	if err != nil {
+		h.writeCommentError(w, alertID, "Failed to post alert comment", err)
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusCreated, comment)
+}
+
+func (h *AlertCommentHandler) handleEditComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	alertID := vars["id"]
+
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	comment, err := h.service.Edit(r.Context(), alertID, vars["commentId"], subject.ID, req.Content)
+	if err != nil {
+		h.writeCommentError(w, alertID, "Failed to edit alert comment", err)
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, comment)
+}
+
+func (h *AlertCommentHandler) handleDeleteComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	alertID := vars["id"]
+
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), alertID, vars["commentId"], subject.ID); err != nil {
+		h.writeCommentError(w, alertID, "Failed to delete alert comment", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeCommentError maps thread failures to HTTP status codes
+func (h *AlertCommentHandler) writeCommentError(w http.ResponseWriter, alertID, message string, err error) {
+	switch {
+	case errors.Is(err, alertthread.ErrInvalidComment):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+	case errors.Is(err, alertthread.ErrAlertNotFound):
+		respondError(w, h.logger, http.StatusNotFound, "Alert not found")
+	case errors.Is(err, database.ErrCommentNotFound):
+		respondError(w, h.logger, http.StatusNotFound, "Comment not found")
+	case errors.Is(err, alertthread.ErrNotAuthor), errors.Is(err, alertthread.ErrResolutionLocked):
+		respondError(w, h.logger, http.StatusForbidden, err.Error())
+	default:
+		h.logger.Error(message, "alert_id", alertID, "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, message)
+	}
+}
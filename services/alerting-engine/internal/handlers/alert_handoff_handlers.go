@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/alerthandoff"
+	"github.com/aegis-shield/services/alerting-engine/internal/alertlifecycle"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// AlertHandoffHandler handles HTTP requests for alert ownership handoffs
+type AlertHandoffHandler struct {
+	logger  *slog.Logger
+	service *alerthandoff.Service
+}
+
+// NewAlertHandoffHandler creates a new alert handoff handler
+func NewAlertHandoffHandler(logger *slog.Logger, service *alerthandoff.Service) *AlertHandoffHandler {
+	return &AlertHandoffHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers alert handoff routes. Handoffs are made on the
+// alert; receivers work through them under their own prefix.
+func (h *AlertHandoffHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/alerts/{id}/handoffs", h.handleListAlertHandoffs).Methods("GET")
+	router.HandleFunc("/alerts/{id}/handoffs", h.handleHandoff).Methods("POST")
+
+	handoffRouter := router.PathPrefix("/alert-handoffs").Subrouter()
+	handoffRouter.HandleFunc("", h.handleListReceived).Methods("GET")
+	handoffRouter.HandleFunc("/{id}/accept", h.handleAccept).Methods("POST")
+	handoffRouter.HandleFunc("/{id}/tasks/{task_id}/complete", h.handleCompleteTask).Methods("POST")
+}
+
+func (h *AlertHandoffHandler) handleHandoff(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var input alerthandoff.Input
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.Handoff(r.Context(), mux.Vars(r)["id"], input, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to hand off alert")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusCreated, result)
+}
+
+func (h *AlertHandoffHandler) handleListAlertHandoffs(w http.ResponseWriter, r *http.Request) {
+	handoffs, err := h.service.ListForAlert(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list alert handoffs")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"handoffs":    handoffs,
+		"total_count": len(handoffs),
+	})
+}
+
+// handleListReceived lists handoffs made to the caller, or to another user
+// given by to_user
+func (h *AlertHandoffHandler) handleListReceived(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	query := r.URL.Query()
+	toUser := query.Get("to_user")
+	if toUser == "" {
+		toUser = subject.ID
+	}
+
+	limit := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = l
+	}
+
+	handoffs, err := h.service.ListReceived(r.Context(), toUser, query.Get("status"), limit)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list alert handoffs")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"handoffs":    handoffs,
+		"total_count": len(handoffs),
+	})
+}
+
+func (h *AlertHandoffHandler) handleAccept(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	handoff, err := h.service.Accept(r.Context(), mux.Vars(r)["id"], subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to accept alert handoff")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, handoff)
+}
+
+func (h *AlertHandoffHandler) handleCompleteTask(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	vars := mux.Vars(r)
+	handoff, err := h.service.CompleteTask(r.Context(), vars["id"], vars["task_id"], subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to complete handoff task")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, handoff)
+}
+
+// respondServiceError maps missing alerts, handoffs and tasks to 404, invalid
+// handoffs to 400, callers other than the receiver to 403, handoffs no
+// longer pending, unowned alerts and concurrent changes to 409 and
+// everything else to 500
+func (h *AlertHandoffHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, alerthandoff.ErrAlertNotFound),
+		errors.Is(err, database.ErrAlertHandoffNotFound),
+		errors.Is(err, database.ErrHandoffTaskNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, alerthandoff.ErrInvalidHandoff),
+		errors.Is(err, alertlifecycle.ErrInvalidTransition):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, alerthandoff.ErrNotReceiver):
+		respondError(w, h.logger, http.StatusForbidden, err.Error())
+		return
+	case errors.Is(err, alerthandoff.ErrNoOwner),
+		errors.Is(err, database.ErrAlertHandoffNotPending),
+		errors.Is(err, database.ErrStaleTransition):
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
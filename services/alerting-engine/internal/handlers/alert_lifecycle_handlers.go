@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/alertlifecycle"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// AlertLifecycleHandler handles HTTP requests for alert state transitions and SLAs
+type AlertLifecycleHandler struct {
+	logger  *slog.Logger
+	service *alertlifecycle.Service
+}
+
+// NewAlertLifecycleHandler creates a new alert lifecycle handler
+func NewAlertLifecycleHandler(logger *slog.Logger, service *alertlifecycle.Service) *AlertLifecycleHandler {
+	return &AlertLifecycleHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers alert lifecycle routes. Overdue alerts live under
+// their own prefix so the listing is not taken for an alert ID.
+func (h *AlertLifecycleHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/alerts/{id}/lifecycle", h.handleGetLifecycle).Methods("GET")
+	router.HandleFunc("/alerts/{id}/transitions", h.handleListTransitions).Methods("GET")
+	router.HandleFunc("/alerts/{id}/transitions", h.handleTransition).Methods("POST")
+	router.HandleFunc("/alert-sla/overdue", h.handleListOverdue).Methods("GET")
+}
+
+func (h *AlertLifecycleHandler) handleGetLifecycle(w http.ResponseWriter, r *http.Request) {
+	state, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get alert lifecycle")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, state)
+}
+
+func (h *AlertLifecycleHandler) handleListTransitions(w http.ResponseWriter, r *http.Request) {
+	transitions, err := h.service.History(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list alert transitions")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"transitions": transitions,
+		"total_count": len(transitions),
+	})
+}
+
+func (h *AlertLifecycleHandler) handleTransition(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var input alertlifecycle.TransitionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	state, err := h.service.Transition(r.Context(), mux.Vars(r)["id"], input, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to transition alert")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, state)
+}
+
+func (h *AlertLifecycleHandler) handleListOverdue(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	overdueQuery := alertlifecycle.OverdueQuery{
+		Severity:   query.Get("severity"),
+		AssignedTo: query.Get("assigned_to"),
+	}
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		overdueQuery.Limit = l
+	}
+
+	alerts, err := h.service.ListOverdue(r.Context(), overdueQuery)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list overdue alerts")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"alerts":      alerts,
+		"total_count": len(alerts),
+	})
+}
+
+// respondServiceError maps missing alerts to 404, disallowed transitions and
+// missing closing reasons to 400, concurrent changes to 409 and everything
+// else to 500
+func (h *AlertLifecycleHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, alertlifecycle.ErrAlertNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, alertlifecycle.ErrInvalidTransition),
+		errors.Is(err, database.ErrResolutionNoteRequired):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, database.ErrStaleTransition):
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// AuditHandler exposes user action history derived from alerting data
+type AuditHandler struct {
+	logger    *slog.Logger
+	auditRepo *database.AuditRepository
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(logger *slog.Logger, auditRepo *database.AuditRepository) *AuditHandler {
+	return &AuditHandler{
+		logger:    logger,
+		auditRepo: auditRepo,
+	}
+}
+
+// RegisterRoutes registers audit routes
+func (h *AuditHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/audit/events", h.handleListEvents).Methods("GET")
+}
+
+func (h *AuditHandler) handleListEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := database.AuditFilter{
+		UserID: query.Get("user_id"),
+		Action: query.Get("action"),
+	}
+
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondError(w, h.logger, http.StatusBadRequest, "Invalid "+param+" timestamp, expected RFC3339")
+			return
+		}
+		*target = &t
+	}
+
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		filter.Limit = l
+	}
+
+	events, err := h.auditRepo.ListUserActions(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to list audit events", "user_id", filter.UserID, "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list audit events")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"events":      events,
+		"total_count": len(events),
+	})
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+)
+
+// routeResources maps the first path segment of each route group to the
+// RBAC resource it exposes. Dead letters hold raw event payloads and risk
+// webhooks send entity data off-platform, so no role but admin is granted them.
+var routeResources = map[string]string{
+	"alerts":                  "alerts",
+	"alert-auto-close":        "alerts",
+	"alert-clusters":          "alerts",
+	"alert-cases":             "alerts",
+	"alert-handoffs":          "alerts",
+	"alert-storms":            "alerts",
+	"alert-sla":               "alerts",
+	"batch-digest":            "alerts",
+	"rules":                   "rules",
+	"rule-packs":              "rules",
+	"rule-backtests":          "rules",
+	"rule-feedback":           "rules",
+	"rule-fixtures":           "rules",
+	"rule-lifecycle":          "rules",
+	"escalation-policies":     "rules",
+	"engine":                  "rules",
+	"scheduler":               "rules",
+	"severity-tuning":         "rules",
+	"notifications":           "notifications",
+	"notification-templates":  "notifications",
+	"notification-brandings":  "notifications",
+	"notification-routes":     "rules",
+	"notification-channels":   "notifications",
+	"notification-deliveries": "notifications",
+	"audit":                   "audit",
+	"case-sync":               "cases",
+	"dead-letters":            "dead_letters",
+	"reference-data":          "rules",
+	"risk-webhooks":           "risk_webhooks",
+	"slo":                     "slo",
+	"training":                "training",
+	"watchlists":              "watchlists",
+}
+
+// AuthorizationMiddleware authenticates bearer tokens and checks each request
+// against the shared RBAC policy. Health, metrics and status stay public, and
+// case sync callbacks are authenticated by their connector signature instead.
+func AuthorizationMiddleware(logger *slog.Logger, checker rbac.Checker, jwtSecret []byte) mux.MiddlewareFunc {
+	authenticate := rbac.Authenticate(jwtSecret)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isPublicPath(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			resource, action := RoutePermission(r)
+			authorized := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				subject, _ := rbac.SubjectFromContext(r.Context())
+				decision, err := checker.CheckPermission(r.Context(), subject, resource, action)
+				if err != nil {
+					logger.Error("Permission check failed", "resource", resource, "action", action, "error", err)
+					respondError(w, logger, http.StatusServiceUnavailable, "Permission check failed")
+					return
+				}
+				if !decision.Allowed {
+					logger.Warn("Request denied by RBAC policy",
+						"user_id", subject.ID,
+						"resource", resource,
+						"action", action,
+						"path", r.URL.Path)
+					respondError(w, logger, http.StatusForbidden, "Insufficient permissions")
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+
+			authenticate(authorized).ServeHTTP(w, r)
+		})
+	}
+}
+
+// RoutePermission returns the resource and action a request needs. Alert
+// evidence bundles and the rule versions behind alerts are guarded as
+// evidence, and approvals as approve.
+func RoutePermission(r *http.Request) (string, string) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	resource, ok := routeResources[segments[0]]
+	if !ok {
+		resource = segments[0]
+	}
+	if segments[0] == "alerts" && len(segments) >= 3 && (segments[2] == "evidence" || segments[2] == "rule-version") {
+		resource = "evidence"
+	}
+
+	action := rbac.ActionForMethod(r.Method)
+	if last := segments[len(segments)-1]; last == "approve" || last == "reject" {
+		action = rbac.ActionApprove
+	}
+	return resource, action
+}
+
+func isPublicPath(path string) bool {
+	switch path {
+	case "/health", "/ready", "/metrics", "/status":
+		return true
+	}
+	return strings.HasPrefix(path, "/case-sync/callbacks/")
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/backtest"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// BacktestHandler handles HTTP requests to backtest draft rules against
+// historical events
+type BacktestHandler struct {
+	logger  *slog.Logger
+	service *backtest.Service
+}
+
+// NewBacktestHandler creates a new backtest handler
+func NewBacktestHandler(logger *slog.Logger, service *backtest.Service) *BacktestHandler {
+	return &BacktestHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers backtest routes
+func (h *BacktestHandler) RegisterRoutes(router *mux.Router) {
+	backtestRouter := router.PathPrefix("/rule-backtests").Subrouter()
+	backtestRouter.HandleFunc("", h.handleListBacktests).Methods("GET")
+	backtestRouter.HandleFunc("", h.handleStartBacktest).Methods("POST")
+	backtestRouter.HandleFunc("/{id}", h.handleGetBacktest).Methods("GET")
+}
+
+func (h *BacktestHandler) handleStartBacktest(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var req backtest.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	started, err := h.service.Start(r.Context(), req, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to start backtest")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusAccepted, started)
+}
+
+func (h *BacktestHandler) handleListBacktests(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = l
+	}
+
+	backtests, err := h.service.List(r.Context(), query.Get("rule_id"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list backtests", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list backtests")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"backtests":   backtests,
+		"total_count": len(backtests),
+	})
+}
+
+func (h *BacktestHandler) handleGetBacktest(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get backtest")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, result)
+}
+
+// respondServiceError maps missing backtests and rules to 404, invalid
+// backtests to 400, a full or disabled backtest runner to 503 and everything
+// else to 500
+func (h *BacktestHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrBacktestNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		respondError(w, h.logger, http.StatusNotFound, "Rule not found")
+		return
+	case errors.Is(err, backtest.ErrInvalidBacktest):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, backtest.ErrTooManyBacktests), errors.Is(err, backtest.ErrBacktestDisabled):
+		respondError(w, h.logger, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/batchdigest"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// BatchDigestHandler handles HTTP requests for end-of-day batch digests
+type BatchDigestHandler struct {
+	logger  *slog.Logger
+	service *batchdigest.Service
+}
+
+// NewBatchDigestHandler creates a new batch digest handler
+func NewBatchDigestHandler(logger *slog.Logger, service *batchdigest.Service) *BatchDigestHandler {
+	return &BatchDigestHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers batch digest routes
+func (h *BatchDigestHandler) RegisterRoutes(router *mux.Router) {
+	digestRouter := router.PathPrefix("/batch-digest").Subrouter()
+	digestRouter.HandleFunc("/portfolios", h.handleListPortfolios).Methods("GET")
+	digestRouter.HandleFunc("/portfolios", h.handleCreatePortfolio).Methods("POST")
+	digestRouter.HandleFunc("/portfolios/{id}", h.handleGetPortfolio).Methods("GET")
+	digestRouter.HandleFunc("/portfolios/{id}", h.handleUpdatePortfolio).Methods("PUT")
+	digestRouter.HandleFunc("/portfolios/{id}", h.handleDeletePortfolio).Methods("DELETE")
+	digestRouter.HandleFunc("/rules", h.handleListRules).Methods("GET")
+	digestRouter.HandleFunc("/rules", h.handleCreateRule).Methods("POST")
+	digestRouter.HandleFunc("/rules/{id}", h.handleGetRule).Methods("GET")
+	digestRouter.HandleFunc("/rules/{id}", h.handleUpdateRule).Methods("PUT")
+	digestRouter.HandleFunc("/rules/{id}", h.handleDeleteRule).Methods("DELETE")
+	digestRouter.HandleFunc("/aggregates", h.handleIngestAggregates).Methods("POST")
+	digestRouter.HandleFunc("/runs", h.handleListRuns).Methods("GET")
+	digestRouter.HandleFunc("/runs", h.handleRun).Methods("POST")
+	digestRouter.HandleFunc("/digests", h.handleListDigests).Methods("GET")
+}
+
+func (h *BatchDigestHandler) handleListPortfolios(w http.ResponseWriter, r *http.Request) {
+	portfolios, err := h.service.ListPortfolios(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list portfolios", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list portfolios")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"portfolios":  portfolios,
+		"total_count": len(portfolios),
+	})
+}
+
+func (h *BatchDigestHandler) handleCreatePortfolio(w http.ResponseWriter, r *http.Request) {
+	var req batchdigest.PortfolioInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	portfolio, err := h.service.CreatePortfolio(r.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to create portfolio", "name", req.Name, "error", err)
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusCreated, portfolio)
+}
+
+func (h *BatchDigestHandler) handleGetPortfolio(w http.ResponseWriter, r *http.Request) {
+	portfolio, err := h.service.GetPortfolio(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get portfolio")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, portfolio)
+}
+
+func (h *BatchDigestHandler) handleUpdatePortfolio(w http.ResponseWriter, r *http.Request) {
+	portfolioID := mux.Vars(r)["id"]
+
+	var req batchdigest.PortfolioInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	portfolio, err := h.service.UpdatePortfolio(r.Context(), portfolioID, req)
+	if err != nil {
+		if errors.Is(err, database.ErrPortfolioNotFound) {
+			respondError(w, h.logger, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("Failed to update portfolio", "portfolio_id", portfolioID, "error", err)
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, portfolio)
+}
+
+func (h *BatchDigestHandler) handleDeletePortfolio(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeletePortfolio(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.respondServiceError(w, err, "Failed to delete portfolio")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BatchDigestHandler) handleListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.ListRules(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list batch rules", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list batch rules")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"rules":       rules,
+		"total_count": len(rules),
+	})
+}
+
+func (h *BatchDigestHandler) handleCreateRule(w http.ResponseWriter, r *http.Request) {
+	var req batchdigest.RuleInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule, err := h.service.CreateRule(r.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to create batch rule", "name", req.Name, "error", err)
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusCreated, rule)
+}
+
+func (h *BatchDigestHandler) handleGetRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := h.service.GetRule(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get batch rule")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, rule)
+}
+
+func (h *BatchDigestHandler) handleUpdateRule(w http.ResponseWriter, r *http.Request) {
+	ruleID := mux.Vars(r)["id"]
+
+	var req batchdigest.RuleInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule, err := h.service.UpdateRule(r.Context(), ruleID, req)
+	if err != nil {
+		if errors.Is(err, database.ErrBatchRuleNotFound) {
+			respondError(w, h.logger, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("Failed to update batch rule", "rule_id", ruleID, "error", err)
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, rule)
+}
+
+func (h *BatchDigestHandler) handleDeleteRule(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteRule(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.respondServiceError(w, err, "Failed to delete batch rule")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BatchDigestHandler) handleIngestAggregates(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Aggregates []*database.DailyAggregate `json:"aggregates"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.IngestAggregates(r.Context(), req.Aggregates); err != nil {
+		h.logger.Error("Failed to ingest daily aggregates", "count", len(req.Aggregates), "error", err)
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusAccepted, map[string]interface{}{
+		"success":  true,
+		"ingested": len(req.Aggregates),
+	})
+}
+
+func (h *BatchDigestHandler) handleRun(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		BusinessDate string `json:"business_date"`
+		Actor        string `json:"actor"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Actor == "" {
+		respondError(w, h.logger, http.StatusBadRequest, "actor is required")
+		return
+	}
+
+	result, err := h.service.RunForDate(r.Context(), req.BusinessDate, req.Actor)
+	if err != nil {
+		switch {
+		case errors.Is(err, batchdigest.ErrInvalidBusinessDate):
+			respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		case errors.Is(err, database.ErrBatchDigestRunning):
+			respondError(w, h.logger, http.StatusConflict, err.Error())
+		default:
+			h.logger.Error("Failed to run batch digest", "business_date", req.BusinessDate, "error", err)
+			respondError(w, h.logger, http.StatusInternalServerError, "Failed to run batch digest")
+		}
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, result)
+}
+
+func (h *BatchDigestHandler) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = l
+	}
+
+	runs, err := h.service.ListRuns(r.Context(), query.Get("business_date"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list batch digest runs", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list runs")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"runs":        runs,
+		"total_count": len(runs),
+	})
+}
+
+func (h *BatchDigestHandler) handleListDigests(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = l
+	}
+
+	digests, err := h.service.ListDigests(r.Context(),
+		query.Get("business_date"), query.Get("portfolio_id"), query.Get("manager_id"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list batch digests", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list digests")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"digests":     digests,
+		"total_count": len(digests),
+	})
+}
+
+// respondServiceError maps portfolio and rule lookups to 404 and everything else to 500
+func (h *BatchDigestHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, database.ErrPortfolioNotFound) || errors.Is(err, database.ErrBatchRuleNotFound) {
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
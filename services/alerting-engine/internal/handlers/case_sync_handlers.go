@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/casesync"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// CaseSyncHandler handles HTTP requests for external case management sync
+type CaseSyncHandler struct {
+	logger          *slog.Logger
+	service         *casesync.Service
+	maxCallbackBody int64
+}
+
+// NewCaseSyncHandler creates a new case sync handler
+func NewCaseSyncHandler(logger *slog.Logger, service *casesync.Service, maxCallbackBody int64) *CaseSyncHandler {
+	return &CaseSyncHandler{
+		logger:          logger,
+		service:         service,
+		maxCallbackBody: maxCallbackBody,
+	}
+}
+
+// RegisterRoutes registers case sync routes
+func (h *CaseSyncHandler) RegisterRoutes(router *mux.Router) {
+	syncRouter := router.PathPrefix("/case-sync").Subrouter()
+	syncRouter.HandleFunc("/connectors", h.handleListConnectors).Methods("GET")
+	syncRouter.HandleFunc("/connectors", h.handleCreateConnector).Methods("POST")
+	syncRouter.HandleFunc("/connectors/{id}", h.handleGetConnector).Methods("GET")
+	syncRouter.HandleFunc("/connectors/{id}", h.handleUpdateConnector).Methods("PUT")
+	syncRouter.HandleFunc("/connectors/{id}", h.handleDeleteConnector).Methods("DELETE")
+	syncRouter.HandleFunc("/connectors/{id}/rotate-secret", h.handleRotateSecret).Methods("POST")
+	syncRouter.HandleFunc("/connectors/{id}/reconcile", h.handleReconcile).Methods("POST")
+	syncRouter.HandleFunc("/deliveries", h.handleListDeliveries).Methods("GET")
+	syncRouter.HandleFunc("/deliveries/{id}/retry", h.handleRetryDelivery).Methods("POST")
+	syncRouter.HandleFunc("/alerts/{alert_id}/links", h.handleListLinks).Methods("GET")
+	syncRouter.HandleFunc("/reconciliations", h.handleListReconciliations).Methods("GET")
+	syncRouter.HandleFunc("/callbacks/{connector_id}", h.handleCallback).Methods("POST")
+}
+
+func (h *CaseSyncHandler) handleListConnectors(w http.ResponseWriter, r *http.Request) {
+	connectors, err := h.service.ListConnectors(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list case sync connectors", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list connectors")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"connectors":  connectors,
+		"total_count": len(connectors),
+	})
+}
+
+func (h *CaseSyncHandler) handleCreateConnector(w http.ResponseWriter, r *http.Request) {
+	var req casesync.ConnectorInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	connector, err := h.service.CreateConnector(r.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to create case sync connector", "name", req.Name, "error", err)
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusCreated, connector)
+}
+
+func (h *CaseSyncHandler) handleGetConnector(w http.ResponseWriter, r *http.Request) {
+	connector, err := h.service.GetConnector(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get connector")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, connector)
+}
+
+func (h *CaseSyncHandler) handleUpdateConnector(w http.ResponseWriter, r *http.Request) {
+	connectorID := mux.Vars(r)["id"]
+
+	var req casesync.ConnectorInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	connector, err := h.service.UpdateConnector(r.Context(), connectorID, req)
+	if err != nil {
+		if errors.Is(err, database.ErrConnectorNotFound) {
+			respondError(w, h.logger, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("Failed to update case sync connector", "connector_id", connectorID, "error", err)
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, connector)
+}
+
+func (h *CaseSyncHandler) handleDeleteConnector(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteConnector(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.respondServiceError(w, err, "Failed to delete connector")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CaseSyncHandler) handleRotateSecret(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Actor string `json:"actor"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Actor == "" {
+		respondError(w, h.logger, http.StatusBadRequest, "actor is required")
+		return
+	}
+
+	connector, err := h.service.RotateCallbackSecret(r.Context(), mux.Vars(r)["id"], req.Actor)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to rotate callback secret")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, connector)
+}
+
+func (h *CaseSyncHandler) handleReconcile(w http.ResponseWriter, r *http.Request) {
+	run, err := h.service.Reconcile(r.Context(), mux.Vars(r)["id"])
+	if err != nil && run == nil {
+		h.respondServiceError(w, err, "Failed to reconcile connector")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, run)
+}
+
+func (h *CaseSyncHandler) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = l
+	}
+
+	deliveries, err := h.service.ListDeliveries(r.Context(),
+		query.Get("connector_id"), query.Get("alert_id"), query.Get("status"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list case sync deliveries", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list deliveries")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"deliveries":  deliveries,
+		"total_count": len(deliveries),
+	})
+}
+
+func (h *CaseSyncHandler) handleRetryDelivery(w http.ResponseWriter, r *http.Request) {
+	deliveryID := mux.Vars(r)["id"]
+
+	if err := h.service.RetryDelivery(r.Context(), deliveryID); err != nil {
+		h.logger.Error("Failed to retry case sync delivery", "delivery_id", deliveryID, "error", err)
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{"success": true})
+}
+
+func (h *CaseSyncHandler) handleListLinks(w http.ResponseWriter, r *http.Request) {
+	links, err := h.service.ListLinks(r.Context(), mux.Vars(r)["alert_id"])
+	if err != nil {
+		h.logger.Error("Failed to list case sync links", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list case links")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"links":       links,
+		"total_count": len(links),
+	})
+}
+
+func (h *CaseSyncHandler) handleListReconciliations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = l
+	}
+
+	runs, err := h.service.ListReconciliations(r.Context(), query.Get("connector_id"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list case sync reconciliations", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list reconciliations")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"reconciliations": runs,
+		"total_count":     len(runs),
+	})
+}
+
+func (h *CaseSyncHandler) handleCallback(w http.ResponseWriter, r *http.Request) {
+	connectorID := mux.Vars(r)["connector_id"]
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxCallbackBody))
+	if err != nil {
+		respondError(w, h.logger, http.StatusRequestEntityTooLarge, "Callback body too large")
+		return
+	}
+
+	result, err := h.service.HandleCallback(r.Context(), connectorID, body, r.Header.Get(casesync.SignatureHeader))
+	if err != nil {
+		switch {
+		case errors.Is(err, casesync.ErrInvalidSignature):
+			respondError(w, h.logger, http.StatusUnauthorized, "Invalid signature")
+		case errors.Is(err, database.ErrConnectorNotFound):
+			// Do not reveal which connector ids exist to unsigned callers
+			respondError(w, h.logger, http.StatusUnauthorized, "Invalid signature")
+		case errors.Is(err, casesync.ErrConnectorDisabled):
+			respondError(w, h.logger, http.StatusConflict, err.Error())
+		case errors.Is(err, database.ErrCaseLinkNotFound):
+			respondError(w, h.logger, http.StatusNotFound, err.Error())
+		default:
+			h.logger.Error("Failed to apply case sync callback", "connector_id", connectorID, "error", err)
+			respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		}
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, result)
+}
+
+// respondServiceError maps connector lookups to 404 and everything else to 500
+func (h *CaseSyncHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, database.ErrConnectorNotFound) {
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/deadletter"
+)
+
+// DeadLetterHandler handles HTTP requests to inspect, replay and discard
+// events the consumer dead-lettered
+type DeadLetterHandler struct {
+	logger  *slog.Logger
+	service *deadletter.Service
+}
+
+// NewDeadLetterHandler creates a new dead letter handler
+func NewDeadLetterHandler(logger *slog.Logger, service *deadletter.Service) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers dead letter routes
+func (h *DeadLetterHandler) RegisterRoutes(router *mux.Router) {
+	deadLetterRouter := router.PathPrefix("/dead-letters").Subrouter()
+	deadLetterRouter.HandleFunc("", h.handleListDeadLetters).Methods("GET")
+	deadLetterRouter.HandleFunc("/replay", h.handleReplayDeadLetters).Methods("POST")
+	deadLetterRouter.HandleFunc("/{id}", h.handleGetDeadLetter).Methods("GET")
+	deadLetterRouter.HandleFunc("/{id}", h.handleDiscardDeadLetter).Methods("DELETE")
+	deadLetterRouter.HandleFunc("/{id}/replay", h.handleReplayDeadLetter).Methods("POST")
+}
+
+// deadLetterView shows the payload as text when it is valid UTF-8 and as
+// base64 otherwise, since malformed payloads are what usually land here
+type deadLetterView struct {
+	*database.DeadLetterMessage
+	Payload       string `json:"payload,omitempty"`
+	PayloadBase64 string `json:"payload_base64,omitempty"`
+}
+
+func newDeadLetterView(message *database.DeadLetterMessage) deadLetterView {
+	view := deadLetterView{DeadLetterMessage: message}
+	if utf8.Valid(message.Payload) {
+		view.Payload = string(message.Payload)
+	} else {
+		view.PayloadBase64 = base64.StdEncoding.EncodeToString(message.Payload)
+	}
+	return view
+}
+
+func (h *DeadLetterHandler) handleListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := database.DeadLetterFilter{
+		Status: query.Get("status"),
+		Topic:  query.Get("topic"),
+	}
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		filter.Limit = l
+	}
+	if o, err := strconv.Atoi(query.Get("offset")); err == nil {
+		filter.Offset = o
+	}
+
+	messages, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to list dead letters", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list dead letters")
+		return
+	}
+
+	views := make([]deadLetterView, 0, len(messages))
+	for _, message := range messages {
+		views = append(views, newDeadLetterView(message))
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"dead_letters": views,
+		"total_count":  len(views),
+	})
+}
+
+func (h *DeadLetterHandler) handleGetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	message, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get dead letter")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, newDeadLetterView(message))
+}
+
+func (h *DeadLetterHandler) handleReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	message, err := h.service.Replay(r.Context(), mux.Vars(r)["id"], subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to replay dead letter")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, newDeadLetterView(message))
+}
+
+func (h *DeadLetterHandler) handleReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(req.IDs) == 0 {
+		respondError(w, h.logger, http.StatusBadRequest, "ids is required")
+		return
+	}
+
+	results, err := h.service.ReplayMany(r.Context(), req.IDs, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to replay dead letters")
+		return
+	}
+
+	replayed := 0
+	for _, result := range results {
+		if result.Replayed {
+			replayed++
+		}
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"results":  results,
+		"replayed": replayed,
+		"failed":   len(results) - replayed,
+	})
+}
+
+func (h *DeadLetterHandler) handleDiscardDeadLetter(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	message, err := h.service.Discard(r.Context(), mux.Vars(r)["id"], subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to discard dead letter")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, newDeadLetterView(message))
+}
+
+// respondServiceError maps missing dead letters to 404, already handled ones to 409, oversized replays to 400 and everything else to 500
+func (h *DeadLetterHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrDeadLetterNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, database.ErrDeadLetterNotPending):
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, deadletter.ErrReplayBatchTooLarge):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
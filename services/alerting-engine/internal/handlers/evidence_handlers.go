@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/evidence"
+)
+
+// EvidenceHandler serves the evidence bundles attached to rule-raised alerts
+type EvidenceHandler struct {
+	logger       *slog.Logger
+	evidenceRepo *database.EvidenceRepository
+}
+
+// NewEvidenceHandler creates a new evidence handler
+func NewEvidenceHandler(logger *slog.Logger, evidenceRepo *database.EvidenceRepository) *EvidenceHandler {
+	return &EvidenceHandler{
+		logger:       logger,
+		evidenceRepo: evidenceRepo,
+	}
+}
+
+// RegisterRoutes registers evidence routes
+func (h *EvidenceHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/alerts/{id}/evidence", h.handleGetEvidence).Methods("GET")
+}
+
+func (h *EvidenceHandler) handleGetEvidence(w http.ResponseWriter, r *http.Request) {
+	alertID := mux.Vars(r)["id"]
+
+	bundle, err := h.evidenceRepo.GetByAlertID(r.Context(), alertID)
+	if err != nil {
+		if errors.Is(err, database.ErrEvidenceNotFound) {
+			respondError(w, h.logger, http.StatusNotFound, "No evidence bundle for alert")
+			return
+		}
+		h.logger.Error("Failed to get evidence bundle", "alert_id", alertID, "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to get evidence bundle")
+		return
+	}
+
+	verified, err := evidence.Verify(bundle)
+	if err != nil {
+		h.logger.Error("Failed to verify evidence bundle", "alert_id", alertID, "error", err)
+	}
+	if !verified {
+		h.logger.Warn("Evidence bundle failed integrity check",
+			"alert_id", alertID,
+			"evidence_id", bundle.ID)
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"evidence": bundle,
+		"verified": verified,
+	})
+}
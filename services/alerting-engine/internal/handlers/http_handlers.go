@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -377,6 +378,10 @@ func (h *HTTPHandler) handleResolveAlert(w http.ResponseWriter, r *http.Request)
 		h.writeError(w, http.StatusBadRequest, "resolved_by is required")
 		return
 	}
+	if strings.TrimSpace(req.Resolution) == "" {
+		h.writeError(w, http.StatusBadRequest, "resolution is required")
+		return
+	}
 
 	if err := h.alertRepo.Resolve(r.Context(), alertID, req.ResolvedBy, req.Resolution); err != nil {
 		h.logger.Error("Failed to resolve alert", "alert_id", alertID, "error", err)
@@ -575,6 +580,9 @@ func (h *HTTPHandler) parseAlertFilter(r *http.Request) database.Filter {
 	if source := r.URL.Query().Get("source"); source != "" {
 		filter.Filters["source"] = source
 	}
+	if assignedTo := r.URL.Query().Get("assigned_to"); assignedTo != "" {
+		filter.Filters["assigned_to"] = assignedTo
+	}
 
 	// Date filters
 	if startTime := r.URL.Query().Get("start_time"); startTime != "" {
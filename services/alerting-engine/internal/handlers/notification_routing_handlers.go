@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/notification"
+)
+
+// NotificationRoutingHandler handles HTTP requests for per-rule notification
+// routes, channel providers and delivery history
+type NotificationRoutingHandler struct {
+	logger     *slog.Logger
+	dispatcher *notification.Dispatcher
+}
+
+// NewNotificationRoutingHandler creates a new notification routing handler
+func NewNotificationRoutingHandler(logger *slog.Logger, dispatcher *notification.Dispatcher) *NotificationRoutingHandler {
+	return &NotificationRoutingHandler{
+		logger:     logger,
+		dispatcher: dispatcher,
+	}
+}
+
+// RegisterRoutes registers notification routing routes
+func (h *NotificationRoutingHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/rules/{id}/notification-routes", h.handleListRoutes).Methods("GET")
+	router.HandleFunc("/rules/{id}/notification-routes", h.handleCreateRoute).Methods("POST")
+	router.HandleFunc("/notification-routes/{id}", h.handleUpdateRoute).Methods("PUT")
+	router.HandleFunc("/notification-routes/{id}", h.handleDeleteRoute).Methods("DELETE")
+	router.HandleFunc("/notification-channels", h.handleListChannels).Methods("GET")
+	router.HandleFunc("/notification-deliveries", h.handleListDeliveries).Methods("GET")
+}
+
+func (h *NotificationRoutingHandler) handleListRoutes(w http.ResponseWriter, r *http.Request) {
+	routes, err := h.dispatcher.ListRoutes(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list notification routes")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"routes":      routes,
+		"total_count": len(routes),
+	})
+}
+
+func (h *NotificationRoutingHandler) handleCreateRoute(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var input notification.RouteInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	route, err := h.dispatcher.CreateRoute(r.Context(), mux.Vars(r)["id"], input, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to create notification route")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusCreated, route)
+}
+
+func (h *NotificationRoutingHandler) handleUpdateRoute(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var input notification.RouteInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	route, err := h.dispatcher.UpdateRoute(r.Context(), mux.Vars(r)["id"], input, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to update notification route")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, route)
+}
+
+func (h *NotificationRoutingHandler) handleDeleteRoute(w http.ResponseWriter, r *http.Request) {
+	if err := h.dispatcher.DeleteRoute(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.respondServiceError(w, err, "Failed to delete notification route")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *NotificationRoutingHandler) handleListChannels(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"channels": h.dispatcher.Channels(),
+	})
+}
+
+func (h *NotificationRoutingHandler) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := database.NotificationDeliveryFilter{
+		RuleID:  query.Get("rule_id"),
+		AlertID: query.Get("alert_id"),
+		Channel: query.Get("channel"),
+		Status:  query.Get("status"),
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			respondError(w, h.logger, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	deliveries, err := h.dispatcher.ListDeliveries(r.Context(), filter)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list notification deliveries")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"deliveries":  deliveries,
+		"total_count": len(deliveries),
+	})
+}
+
+// respondServiceError maps missing routes and rules to 404, invalid routes to
+// 400, duplicate routes to 409 and everything else to 500
+func (h *NotificationRoutingHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrNotificationRouteNotFound),
+		errors.Is(err, notification.ErrRuleNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, notification.ErrInvalidRoute):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, database.ErrNotificationRouteExists):
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
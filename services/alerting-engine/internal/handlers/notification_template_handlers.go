@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/notifytemplate"
+)
+
+// NotificationTemplateHandler handles HTTP requests for notification templates
+// and tenant branding
+type NotificationTemplateHandler struct {
+	logger  *slog.Logger
+	service *notifytemplate.Service
+}
+
+// NewNotificationTemplateHandler creates a new notification template handler
+func NewNotificationTemplateHandler(logger *slog.Logger, service *notifytemplate.Service) *NotificationTemplateHandler {
+	return &NotificationTemplateHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers notification template and branding routes
+func (h *NotificationTemplateHandler) RegisterRoutes(router *mux.Router) {
+	templates := router.PathPrefix("/notification-templates").Subrouter()
+	templates.HandleFunc("", h.handleListTemplates).Methods("GET")
+	templates.HandleFunc("", h.handleCreateTemplate).Methods("POST")
+	templates.HandleFunc("/preview", h.handlePreviewDraft).Methods("POST")
+	templates.HandleFunc("/{id}", h.handleGetTemplate).Methods("GET")
+	templates.HandleFunc("/{id}", h.handleUpdateTemplate).Methods("PUT")
+	templates.HandleFunc("/{id}/versions", h.handleListVersions).Methods("GET")
+	templates.HandleFunc("/{id}/versions/{version}", h.handleGetVersion).Methods("GET")
+	templates.HandleFunc("/{id}/preview", h.handlePreviewTemplate).Methods("POST")
+	templates.HandleFunc("/{id}/rollback", h.handleRollback).Methods("POST")
+
+	brandings := router.PathPrefix("/notification-brandings").Subrouter()
+	brandings.HandleFunc("", h.handleListBrandings).Methods("GET")
+	brandings.HandleFunc("/{tenant_id}", h.handleGetBranding).Methods("GET")
+	brandings.HandleFunc("/{tenant_id}", h.handleSetBranding).Methods("PUT")
+	brandings.HandleFunc("/{tenant_id}", h.handleDeleteBranding).Methods("DELETE")
+}
+
+func (h *NotificationTemplateHandler) handleListTemplates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	templates, err := h.service.List(r.Context(), query.Get("channel"), query.Get("rule_id"))
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list notification templates")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"templates":   templates,
+		"total_count": len(templates),
+	})
+}
+
+func (h *NotificationTemplateHandler) handleCreateTemplate(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var input notifytemplate.CreateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template, err := h.service.Create(r.Context(), input, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to create notification template")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusCreated, template)
+}
+
+func (h *NotificationTemplateHandler) handleGetTemplate(w http.ResponseWriter, r *http.Request) {
+	template, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get notification template")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, template)
+}
+
+func (h *NotificationTemplateHandler) handleUpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var input notifytemplate.UpdateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template, err := h.service.Update(r.Context(), mux.Vars(r)["id"], input, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to update notification template")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, template)
+}
+
+func (h *NotificationTemplateHandler) handleListVersions(w http.ResponseWriter, r *http.Request) {
+	versions, err := h.service.History(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list notification template versions")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"versions":    versions,
+		"total_count": len(versions),
+	})
+}
+
+func (h *NotificationTemplateHandler) handleGetVersion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil || version <= 0 {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid version")
+		return
+	}
+
+	templateVersion, err := h.service.GetVersion(r.Context(), vars["id"], version)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get notification template version")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, templateVersion)
+}
+
+func (h *NotificationTemplateHandler) handlePreviewDraft(w http.ResponseWriter, r *http.Request) {
+	var input notifytemplate.PreviewInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	h.preview(w, r, "", input)
+}
+
+func (h *NotificationTemplateHandler) handlePreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	// the body is optional; without one the current version previews against a sample alert
+	var input notifytemplate.PreviewInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	h.preview(w, r, mux.Vars(r)["id"], input)
+}
+
+func (h *NotificationTemplateHandler) preview(w http.ResponseWriter, r *http.Request, id string, input notifytemplate.PreviewInput) {
+	preview, err := h.service.Preview(r.Context(), id, input)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to preview notification template")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, preview)
+}
+
+func (h *NotificationTemplateHandler) handleRollback(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var input notifytemplate.RollbackInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if input.Version <= 0 {
+		respondError(w, h.logger, http.StatusBadRequest, "version is required")
+		return
+	}
+
+	template, err := h.service.Rollback(r.Context(), mux.Vars(r)["id"], input, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to roll back notification template")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, template)
+}
+
+func (h *NotificationTemplateHandler) handleListBrandings(w http.ResponseWriter, r *http.Request) {
+	brandings, err := h.service.ListBrandings(r.Context())
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list notification brandings")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"brandings":   brandings,
+		"total_count": len(brandings),
+	})
+}
+
+func (h *NotificationTemplateHandler) handleGetBranding(w http.ResponseWriter, r *http.Request) {
+	branding, err := h.service.GetBranding(r.Context(), mux.Vars(r)["tenant_id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get notification branding")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, branding)
+}
+
+func (h *NotificationTemplateHandler) handleSetBranding(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var input notifytemplate.BrandingInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	branding, err := h.service.SetBranding(r.Context(), mux.Vars(r)["tenant_id"], input, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to save notification branding")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, branding)
+}
+
+func (h *NotificationTemplateHandler) handleDeleteBranding(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteBranding(r.Context(), mux.Vars(r)["tenant_id"]); err != nil {
+		h.respondServiceError(w, err, "Failed to delete notification branding")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondServiceError maps missing templates and branding to 404, invalid or
+// unrenderable templates, invalid branding and unknown rules or alerts to
+// 400, duplicate and concurrent changes to 409 and everything else to 500
+func (h *NotificationTemplateHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrNotificationTemplateNotFound),
+		errors.Is(err, database.ErrNotificationBrandingNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, notifytemplate.ErrInvalidTemplate),
+		errors.Is(err, notifytemplate.ErrInvalidBranding),
+		errors.Is(err, notifytemplate.ErrRenderFailed),
+		errors.Is(err, notifytemplate.ErrRuleNotFound),
+		errors.Is(err, notifytemplate.ErrAlertNotFound):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, database.ErrNotificationTemplateExists),
+		errors.Is(err, database.ErrNotificationTemplateStale):
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
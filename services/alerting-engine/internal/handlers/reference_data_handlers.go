@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/engine"
+)
+
+// ReferenceDataHandler handles HTTP requests for entity risk tiers and the
+// rule engine's reference data cache
+type ReferenceDataHandler struct {
+	logger *slog.Logger
+	repo   *database.RulePackRepository
+	cache  *engine.ReferenceCache
+}
+
+// NewReferenceDataHandler creates a new reference data handler
+func NewReferenceDataHandler(logger *slog.Logger, repo *database.RulePackRepository, cache *engine.ReferenceCache) *ReferenceDataHandler {
+	return &ReferenceDataHandler{
+		logger: logger,
+		repo:   repo,
+		cache:  cache,
+	}
+}
+
+// RegisterRoutes registers reference data routes
+func (h *ReferenceDataHandler) RegisterRoutes(router *mux.Router) {
+	referenceRouter := router.PathPrefix("/reference-data").Subrouter()
+	referenceRouter.HandleFunc("/risk-tiers", h.handleListRiskTiers).Methods("GET")
+	referenceRouter.HandleFunc("/risk-tiers/{entityId}", h.handleGetRiskTier).Methods("GET")
+	referenceRouter.HandleFunc("/risk-tiers/{entityId}", h.handleSetRiskTier).Methods("PUT")
+	referenceRouter.HandleFunc("/risk-tiers/{entityId}", h.handleDeleteRiskTier).Methods("DELETE")
+	referenceRouter.HandleFunc("/cache", h.handleCacheStats).Methods("GET")
+	referenceRouter.HandleFunc("/cache/flush", h.handleFlushCache).Methods("POST")
+}
+
+func (h *ReferenceDataHandler) handleListRiskTiers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	tier := query.Get("tier")
+	if tier != "" && !validRiskTier(tier) {
+		respondError(w, h.logger, http.StatusBadRequest, "tier must be low, medium, high or critical")
+		return
+	}
+
+	limit := 100
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 && l <= 1000 {
+		limit = l
+	}
+	offset := 0
+	if o, err := strconv.Atoi(query.Get("offset")); err == nil && o > 0 {
+		offset = o
+	}
+
+	tiers, err := h.repo.ListRiskTiers(r.Context(), tier, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list entity risk tiers", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list entity risk tiers")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"risk_tiers":  tiers,
+		"total_count": len(tiers),
+	})
+}
+
+func (h *ReferenceDataHandler) handleGetRiskTier(w http.ResponseWriter, r *http.Request) {
+	entityID := mux.Vars(r)["entityId"]
+
+	tier, err := h.repo.GetRiskTier(r.Context(), entityID)
+	if err != nil {
+		h.logger.Error("Failed to get entity risk tier", "entity_id", entityID, "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to get entity risk tier")
+		return
+	}
+	if tier == nil {
+		respondError(w, h.logger, http.StatusNotFound, database.ErrRiskTierNotFound.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, tier)
+}
+
+func (h *ReferenceDataHandler) handleSetRiskTier(w http.ResponseWriter, r *http.Request) {
+	entityID := mux.Vars(r)["entityId"]
+
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var req struct {
+		Tier   string   `json:"tier"`
+		Score  *float64 `json:"score"`
+		Reason *string  `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if !validRiskTier(req.Tier) {
+		respondError(w, h.logger, http.StatusBadRequest, "tier must be low, medium, high or critical")
+		return
+	}
+
+	tier := &database.EntityRiskTier{
+		EntityID:  entityID,
+		Tier:      req.Tier,
+		Score:     req.Score,
+		Reason:    req.Reason,
+		UpdatedBy: subject.ID,
+	}
+	if err := h.repo.UpsertRiskTier(r.Context(), tier); err != nil {
+		h.logger.Error("Failed to set entity risk tier", "entity_id", entityID, "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to set entity risk tier")
+		return
+	}
+
+	// Other replicas pick the change up from the change log
+	h.cache.Invalidate(database.ReferenceKindRiskTier, entityID)
+
+	respondJSON(w, h.logger, http.StatusOK, tier)
+}
+
+func (h *ReferenceDataHandler) handleDeleteRiskTier(w http.ResponseWriter, r *http.Request) {
+	entityID := mux.Vars(r)["entityId"]
+
+	if err := h.repo.DeleteRiskTier(r.Context(), entityID); err != nil {
+		if errors.Is(err, database.ErrRiskTierNotFound) {
+			respondError(w, h.logger, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("Failed to delete entity risk tier", "entity_id", entityID, "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to delete entity risk tier")
+		return
+	}
+
+	h.cache.Invalidate(database.ReferenceKindRiskTier, entityID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ReferenceDataHandler) handleCacheStats(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, h.logger, http.StatusOK, h.cache.Stats())
+}
+
+func (h *ReferenceDataHandler) handleFlushCache(w http.ResponseWriter, r *http.Request) {
+	h.cache.Flush()
+	h.logger.Info("Reference data cache flushed")
+
+	respondJSON(w, h.logger, http.StatusOK, h.cache.Stats())
+}
+
+func validRiskTier(tier string) bool {
+	switch tier {
+	case database.RiskTierLow, database.RiskTierMedium, database.RiskTierHigh, database.RiskTierCritical:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// respondJSON writes a JSON response for handlers that are not part of HTTPHandler
+func respondJSON(w http.ResponseWriter, logger *slog.Logger, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+// respondError writes an error response in the same shape as HTTPHandler.writeError
+func respondError(w http.ResponseWriter, logger *slog.Logger, status int, message string) {
+	respondJSON(w, logger, status, map[string]interface{}{
+		"error":     message,
+		"status":    status,
+		"timestamp": time.Now().UTC(),
+	})
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/riskwebhook"
+)
+
+// RiskWebhookHandler handles HTTP requests to manage entity risk tier
+// webhook subscribers, inspect and retry deliveries, and replay transitions
+type RiskWebhookHandler struct {
+	logger  *slog.Logger
+	service *riskwebhook.Service
+}
+
+// NewRiskWebhookHandler creates a new risk webhook handler
+func NewRiskWebhookHandler(logger *slog.Logger, service *riskwebhook.Service) *RiskWebhookHandler {
+	return &RiskWebhookHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers risk webhook routes
+func (h *RiskWebhookHandler) RegisterRoutes(router *mux.Router) {
+	webhookRouter := router.PathPrefix("/risk-webhooks").Subrouter()
+	webhookRouter.HandleFunc("/subscribers", h.handleListSubscribers).Methods("GET")
+	webhookRouter.HandleFunc("/subscribers", h.handleCreateSubscriber).Methods("POST")
+	webhookRouter.HandleFunc("/subscribers/{id}", h.handleGetSubscriber).Methods("GET")
+	webhookRouter.HandleFunc("/subscribers/{id}", h.handleUpdateSubscriber).Methods("PUT")
+	webhookRouter.HandleFunc("/subscribers/{id}", h.handleDeleteSubscriber).Methods("DELETE")
+	webhookRouter.HandleFunc("/subscribers/{id}/rotate-secret", h.handleRotateSecret).Methods("POST")
+	webhookRouter.HandleFunc("/subscribers/{id}/replay", h.handleReplay).Methods("POST")
+	webhookRouter.HandleFunc("/deliveries", h.handleListDeliveries).Methods("GET")
+	webhookRouter.HandleFunc("/deliveries/{id}/retry", h.handleRetryDelivery).Methods("POST")
+	webhookRouter.HandleFunc("/transitions", h.handleListTransitions).Methods("GET")
+}
+
+func (h *RiskWebhookHandler) handleListSubscribers(w http.ResponseWriter, r *http.Request) {
+	subscribers, err := h.service.ListSubscribers(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list risk webhook subscribers", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list subscribers")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"subscribers": subscribers,
+		"total_count": len(subscribers),
+	})
+}
+
+func (h *RiskWebhookHandler) handleCreateSubscriber(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var req riskwebhook.SubscriberInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	subscriber, err := h.service.CreateSubscriber(r.Context(), req, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to create subscriber")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusCreated, subscriber)
+}
+
+func (h *RiskWebhookHandler) handleGetSubscriber(w http.ResponseWriter, r *http.Request) {
+	subscriber, err := h.service.GetSubscriber(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get subscriber")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, subscriber)
+}
+
+func (h *RiskWebhookHandler) handleUpdateSubscriber(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var req riskwebhook.SubscriberInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	subscriber, err := h.service.UpdateSubscriber(r.Context(), mux.Vars(r)["id"], req, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to update subscriber")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, subscriber)
+}
+
+func (h *RiskWebhookHandler) handleDeleteSubscriber(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteSubscriber(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.respondServiceError(w, err, "Failed to delete subscriber")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RiskWebhookHandler) handleRotateSecret(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	subscriber, err := h.service.RotateSecret(r.Context(), mux.Vars(r)["id"], subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to rotate signing secret")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, subscriber)
+}
+
+func (h *RiskWebhookHandler) handleReplay(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var req riskwebhook.ReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.Replay(r.Context(), mux.Vars(r)["id"], req, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to replay transitions")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusAccepted, result)
+}
+
+func (h *RiskWebhookHandler) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = l
+	}
+
+	deliveries, err := h.service.ListDeliveries(r.Context(),
+		query.Get("subscriber_id"), query.Get("entity_id"), query.Get("status"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list risk webhook deliveries", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list deliveries")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"deliveries":  deliveries,
+		"total_count": len(deliveries),
+	})
+}
+
+func (h *RiskWebhookHandler) handleRetryDelivery(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.RetryDelivery(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.respondServiceError(w, err, "Failed to retry delivery")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{"success": true})
+}
+
+func (h *RiskWebhookHandler) handleListTransitions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = l
+	}
+
+	transitions, err := h.service.ListTransitions(r.Context(), query.Get("entity_id"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list risk tier transitions", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list transitions")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"transitions": transitions,
+		"total_count": len(transitions),
+	})
+}
+
+// respondServiceError maps missing subscribers to 404, deliveries that cannot
+// be retried to 409, invalid subscribers to 422, bad replays to 400 and
+// everything else to 500
+func (h *RiskWebhookHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrSubscriberNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, database.ErrRiskDeliveryNotRetryable):
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, riskwebhook.ErrInvalidSubscriber):
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	case errors.Is(err, riskwebhook.ErrInvalidReplay), errors.Is(err, riskwebhook.ErrReplayTooLarge):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
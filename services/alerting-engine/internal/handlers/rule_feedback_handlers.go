@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/rulefeedback"
+)
+
+// RuleFeedbackHandler handles HTTP requests for alert dispositions and the
+// rule precision report built from them
+type RuleFeedbackHandler struct {
+	logger  *slog.Logger
+	service *rulefeedback.Service
+}
+
+// NewRuleFeedbackHandler creates a new rule feedback handler
+func NewRuleFeedbackHandler(logger *slog.Logger, service *rulefeedback.Service) *RuleFeedbackHandler {
+	return &RuleFeedbackHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers rule feedback routes. Dispositions are recorded
+// on the alert; the report is read under its own prefix.
+func (h *RuleFeedbackHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/alerts/{id}/disposition", h.handleGetDisposition).Methods("GET")
+	router.HandleFunc("/alerts/{id}/disposition", h.handleRecordDisposition).Methods("PUT")
+
+	feedbackRouter := router.PathPrefix("/rule-feedback").Subrouter()
+	feedbackRouter.HandleFunc("/report", h.handleReport).Methods("GET")
+	feedbackRouter.HandleFunc("/rules/{id}", h.handleRuleReport).Methods("GET")
+}
+
+func (h *RuleFeedbackHandler) handleRecordDisposition(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var input rulefeedback.DispositionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	disposition, err := h.service.RecordDisposition(r.Context(), mux.Vars(r)["id"], input, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to record alert disposition")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, disposition)
+}
+
+func (h *RuleFeedbackHandler) handleGetDisposition(w http.ResponseWriter, r *http.Request) {
+	disposition, err := h.service.GetDisposition(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get alert disposition")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, disposition)
+}
+
+func (h *RuleFeedbackHandler) handleReport(w http.ResponseWriter, r *http.Request) {
+	query, ok := h.reportQuery(w, r)
+	if !ok {
+		return
+	}
+	query.RuleID = r.URL.Query().Get("rule_id")
+
+	report, err := h.service.Report(r.Context(), query)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to build rule feedback report")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, report)
+}
+
+func (h *RuleFeedbackHandler) handleRuleReport(w http.ResponseWriter, r *http.Request) {
+	query, ok := h.reportQuery(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.RuleReport(r.Context(), mux.Vars(r)["id"], query)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to build rule feedback report")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, report)
+}
+
+// reportQuery reads the field, since and optimize report parameters
+func (h *RuleFeedbackHandler) reportQuery(w http.ResponseWriter, r *http.Request) (rulefeedback.ReportQuery, bool) {
+	params := r.URL.Query()
+	query := rulefeedback.ReportQuery{Field: params.Get("field")}
+
+	if value := params.Get("since"); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondError(w, h.logger, http.StatusBadRequest, "Invalid since timestamp, expected RFC3339")
+			return query, false
+		}
+		query.Since = &since
+	}
+
+	if value := params.Get("optimize"); value != "" {
+		optimize, err := strconv.ParseBool(value)
+		if err != nil {
+			respondError(w, h.logger, http.StatusBadRequest, "Invalid optimize flag")
+			return query, false
+		}
+		query.Optimize = optimize
+	}
+
+	return query, true
+}
+
+// respondServiceError maps missing alerts, dispositions and rules without
+// dispositions to 404, invalid dispositions to 400 and everything else to 500
+func (h *RuleFeedbackHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, rulefeedback.ErrAlertNotFound),
+		errors.Is(err, database.ErrAlertDispositionNotFound),
+		errors.Is(err, rulefeedback.ErrNoDispositions):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, rulefeedback.ErrInvalidDisposition):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
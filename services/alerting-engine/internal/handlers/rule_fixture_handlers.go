@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/engine"
+)
+
+// RuleFixtureHandler handles HTTP requests to run the test fixtures rules
+// carry in their conditions
+type RuleFixtureHandler struct {
+	logger   *slog.Logger
+	ruleRepo *database.RuleRepository
+	engine   *engine.RuleEngine
+}
+
+// NewRuleFixtureHandler creates a new rule fixture handler
+func NewRuleFixtureHandler(logger *slog.Logger, ruleRepo *database.RuleRepository, ruleEngine *engine.RuleEngine) *RuleFixtureHandler {
+	return &RuleFixtureHandler{
+		logger:   logger,
+		ruleRepo: ruleRepo,
+		engine:   ruleEngine,
+	}
+}
+
+// RegisterRoutes registers rule fixture routes
+func (h *RuleFixtureHandler) RegisterRoutes(router *mux.Router) {
+	fixtureRouter := router.PathPrefix("/rule-fixtures").Subrouter()
+	fixtureRouter.HandleFunc("", h.handleTestDraft).Methods("POST")
+	fixtureRouter.HandleFunc("/rules/{id}", h.handleTestRule).Methods("POST")
+}
+
+// handleTestDraft runs the fixtures of draft conditions before they are saved
+func (h *RuleFixtureHandler) handleTestDraft(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Conditions map[string]interface{} `json:"conditions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(req.Conditions) == 0 {
+		respondError(w, h.logger, http.StatusBadRequest, "conditions are required")
+		return
+	}
+
+	h.respondReport(w, r, req.Conditions)
+}
+
+// handleTestRule runs the fixtures of a stored rule
+func (h *RuleFixtureHandler) handleTestRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := h.ruleRepo.GetByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondError(w, h.logger, http.StatusNotFound, "Rule not found")
+			return
+		}
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to get rule")
+		return
+	}
+
+	h.respondReport(w, r, rule.Conditions)
+}
+
+// respondReport runs fixtures and reports each outcome. Conditions that do
+// not compile and malformed fixtures are the caller's to fix, so both are 400.
+func (h *RuleFixtureHandler) respondReport(w http.ResponseWriter, r *http.Request, conditions map[string]interface{}) {
+	report, err := h.engine.TestRule(r.Context(), conditions)
+	if err != nil {
+		if r.Context().Err() != nil {
+			h.logger.Error("Failed to run rule fixtures", "error", err)
+			respondError(w, h.logger, http.StatusInternalServerError, "Failed to run rule fixtures")
+			return
+		}
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, report)
+}
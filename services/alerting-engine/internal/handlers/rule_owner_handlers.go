@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/ruleowner"
+)
+
+// RuleOwnerHandler handles HTTP requests for rule owners and the lifecycle
+// events and digests sent to them
+type RuleOwnerHandler struct {
+	logger  *slog.Logger
+	service *ruleowner.Service
+}
+
+// NewRuleOwnerHandler creates a new rule owner handler
+func NewRuleOwnerHandler(logger *slog.Logger, service *ruleowner.Service) *RuleOwnerHandler {
+	return &RuleOwnerHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers rule owner routes. Owners are set on the rule;
+// events and digests are read under their own prefix.
+func (h *RuleOwnerHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/rules/{id}/owners", h.handleListOwners).Methods("GET")
+	router.HandleFunc("/rules/{id}/owners", h.handleSetOwners).Methods("PUT")
+
+	lifecycleRouter := router.PathPrefix("/rule-lifecycle").Subrouter()
+	lifecycleRouter.HandleFunc("/events", h.handleListEvents).Methods("GET")
+	lifecycleRouter.HandleFunc("/digests", h.handleListDigests).Methods("GET")
+	lifecycleRouter.HandleFunc("/digests/send", h.handleSendDigests).Methods("POST")
+}
+
+func (h *RuleOwnerHandler) handleListOwners(w http.ResponseWriter, r *http.Request) {
+	owners, err := h.service.ListOwners(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list rule owners")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"owners": owners,
+		"count":  len(owners),
+	})
+}
+
+func (h *RuleOwnerHandler) handleSetOwners(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var req struct {
+		Owners []ruleowner.OwnerInput `json:"owners"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	owners, err := h.service.SetOwners(r.Context(), mux.Vars(r)["id"], req.Owners, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to set rule owners")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"owners": owners,
+		"count":  len(owners),
+	})
+}
+
+func (h *RuleOwnerHandler) handleListEvents(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	filter := database.RuleLifecycleEventFilter{
+		RuleID:    params.Get("rule_id"),
+		EventType: params.Get("event_type"),
+	}
+
+	if value := params.Get("pending"); value != "" {
+		pending, err := strconv.ParseBool(value)
+		if err != nil {
+			respondError(w, h.logger, http.StatusBadRequest, "Invalid pending flag")
+			return
+		}
+		filter.Pending = pending
+	}
+
+	limit, ok := h.limit(w, r)
+	if !ok {
+		return
+	}
+	filter.Limit = limit
+
+	events, err := h.service.ListEvents(r.Context(), filter)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list rule lifecycle events")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"events": events,
+		"count":  len(events),
+	})
+}
+
+func (h *RuleOwnerHandler) handleListDigests(w http.ResponseWriter, r *http.Request) {
+	limit, ok := h.limit(w, r)
+	if !ok {
+		return
+	}
+
+	digests, err := h.service.ListDigests(r.Context(), r.URL.Query().Get("owner_id"), limit)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list rule owner digests")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"digests": digests,
+		"count":   len(digests),
+	})
+}
+
+// handleSendDigests sends the pending events now, outside the digest schedule
+func (h *RuleOwnerHandler) handleSendDigests(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.SendDigests(r.Context())
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to send rule owner digests")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, result)
+}
+
+// limit reads the optional limit parameter
+func (h *RuleOwnerHandler) limit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return 0, true
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid limit")
+		return 0, false
+	}
+	return limit, true
+}
+
+// respondServiceError maps missing rules to 404, invalid owners to 400 and
+// everything else to 500
+func (h *RuleOwnerHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, ruleowner.ErrRuleNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, ruleowner.ErrInvalidOwner):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/rulepack"
+)
+
+// yamlContentType is the media type of YAML rule bundles
+const yamlContentType = "application/yaml"
+
+// RulePackHandler handles HTTP requests for rule pack export and import
+type RulePackHandler struct {
+	logger      *slog.Logger
+	service     *rulepack.Service
+	maxPackSize int64
+}
+
+// NewRulePackHandler creates a new rule pack handler
+func NewRulePackHandler(logger *slog.Logger, service *rulepack.Service, maxPackSize int64) *RulePackHandler {
+	return &RulePackHandler{
+		logger:      logger,
+		service:     service,
+		maxPackSize: maxPackSize,
+	}
+}
+
+// RegisterRoutes registers rule pack routes
+func (h *RulePackHandler) RegisterRoutes(router *mux.Router) {
+	packRouter := router.PathPrefix("/rule-packs").Subrouter()
+	packRouter.HandleFunc("/export", h.handleExport).Methods("POST")
+	packRouter.HandleFunc("/validate", h.handleValidate).Methods("POST")
+	packRouter.HandleFunc("/import", h.handleImport).Methods("POST")
+	packRouter.HandleFunc("/import/yaml", h.handleImportYAML).Methods("POST")
+	packRouter.HandleFunc("/imports", h.handleListImports).Methods("GET")
+}
+
+// importRequest carries a pack and how to resolve its conflicts
+type importRequest struct {
+	Pack *rulepack.Pack `json:"pack"`
+	rulepack.ImportOptions
+}
+
+func (h *RulePackHandler) handleExport(w http.ResponseWriter, r *http.Request) {
+	var req rulepack.ExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	pack, err := h.service.Export(r.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to export rule pack", "name", req.Name, "error", err)
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, rulepack.ErrSigningKeyMissing) {
+			status = http.StatusServiceUnavailable
+		}
+		respondError(w, h.logger, status, err.Error())
+		return
+	}
+
+	if r.URL.Query().Get("format") != "yaml" {
+		w.Header().Set("Content-Disposition",
+			fmt.Sprintf("attachment; filename=%q", pack.Name+"-"+pack.Version+".rulepack.json"))
+		respondJSON(w, h.logger, http.StatusOK, pack)
+		return
+	}
+
+	body, err := rulepack.EncodeYAML(pack)
+	if err != nil {
+		h.logger.Error("Failed to encode rule pack as YAML", "name", req.Name, "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to export rule pack")
+		return
+	}
+	w.Header().Set("Content-Type", yamlContentType)
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", pack.Name+"-"+pack.Version+".rulepack.yaml"))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		h.logger.Error("Failed to write YAML rule pack", "name", req.Name, "error", err)
+	}
+}
+
+func (h *RulePackHandler) handleValidate(w http.ResponseWriter, r *http.Request) {
+	var pack rulepack.Pack
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.maxPackSize)).Decode(&pack); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid rule pack")
+		return
+	}
+
+	report, err := h.service.Validate(r.Context(), &pack)
+	if err != nil {
+		h.logger.Error("Failed to validate rule pack", "name", pack.Name, "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to validate rule pack")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, report)
+}
+
+func (h *RulePackHandler) handleImport(w http.ResponseWriter, r *http.Request) {
+	var req importRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.maxPackSize)).Decode(&req); err != nil || req.Pack == nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	h.respondImport(w, r, req.Pack, req.ImportOptions)
+}
+
+// handleImportYAML imports a YAML rule bundle given as the request body.
+// Import options come from the query string and the importer is the caller;
+// dry_run=true reports what the import would do without applying it.
+func (h *RulePackHandler) handleImportYAML(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxPackSize))
+	if err != nil {
+		respondError(w, h.logger, http.StatusRequestEntityTooLarge, "Rule bundle is too large")
+		return
+	}
+	pack, err := rulepack.DecodeYAML(data)
+	if err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	opts := rulepack.ImportOptions{
+		ConflictMode: query.Get("conflict_mode"),
+		ImportedBy:   subject.ID,
+	}
+	if opts.EnableRules, err = parseOptionalBool(query.Get("enable_rules")); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "enable_rules must be true or false")
+		return
+	}
+	if opts.DryRun, err = parseOptionalBool(query.Get("dry_run")); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "dry_run must be true or false")
+		return
+	}
+
+	h.respondImport(w, r, pack, opts)
+}
+
+// respondImport imports a pack and reports the outcome of every item. A pack
+// that fails validation is reported with its validation errors as 422.
+func (h *RulePackHandler) respondImport(w http.ResponseWriter, r *http.Request, pack *rulepack.Pack, opts rulepack.ImportOptions) {
+	result, err := h.service.Import(r.Context(), pack, opts)
+	switch {
+	case errors.Is(err, rulepack.ErrInvalidImportOptions):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, rulepack.ErrIncompatiblePack):
+		respondJSON(w, h.logger, http.StatusUnprocessableEntity, result)
+		return
+	case err != nil:
+		h.logger.Error("Failed to import rule pack",
+			"name", pack.Name,
+			"version", pack.Version,
+			"error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to import rule pack")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, result)
+}
+
+func (h *RulePackHandler) handleListImports(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil {
+		limit = l
+	}
+
+	imports, err := h.service.ListImports(r.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to list rule pack imports", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list rule pack imports")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"imports":     imports,
+		"total_count": len(imports),
+	})
+}
+
+// parseOptionalBool reads a query flag that defaults to false
+func parseOptionalBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
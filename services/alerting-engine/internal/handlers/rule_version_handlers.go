@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/ruleversion"
+)
+
+// RuleVersionHandler handles HTTP requests for rule version history, diffs
+// and rollbacks, and for the rule version behind an alert
+type RuleVersionHandler struct {
+	logger  *slog.Logger
+	service *ruleversion.Service
+}
+
+// NewRuleVersionHandler creates a new rule version handler
+func NewRuleVersionHandler(logger *slog.Logger, service *ruleversion.Service) *RuleVersionHandler {
+	return &RuleVersionHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers rule version routes
+func (h *RuleVersionHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/rules/{id}/versions", h.handleListVersions).Methods("GET")
+	router.HandleFunc("/rules/{id}/versions/{version}", h.handleGetVersion).Methods("GET")
+	router.HandleFunc("/rules/{id}/diff", h.handleDiffVersions).Methods("GET")
+	router.HandleFunc("/rules/{id}/activations", h.handleListActivations).Methods("GET")
+	router.HandleFunc("/rules/{id}/rollback", h.handleRollback).Methods("POST")
+	router.HandleFunc("/alerts/{id}/rule-version", h.handleGetAlertRuleVersion).Methods("GET")
+}
+
+func (h *RuleVersionHandler) handleListVersions(w http.ResponseWriter, r *http.Request) {
+	versions, err := h.service.History(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get rule versions")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"versions":    versions,
+		"total_count": len(versions),
+	})
+}
+
+func (h *RuleVersionHandler) handleGetVersion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil || version <= 0 {
+		respondError(w, h.logger, http.StatusBadRequest, "version must be a positive integer")
+		return
+	}
+
+	ruleVersion, err := h.service.Get(r.Context(), vars["id"], version)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get rule version")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, ruleVersion)
+}
+
+func (h *RuleVersionHandler) handleDiffVersions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, err := strconv.Atoi(query.Get("from"))
+	if err != nil || from <= 0 {
+		respondError(w, h.logger, http.StatusBadRequest, "from must be a positive integer")
+		return
+	}
+	to, err := strconv.Atoi(query.Get("to"))
+	if err != nil || to <= 0 {
+		respondError(w, h.logger, http.StatusBadRequest, "to must be a positive integer")
+		return
+	}
+
+	diff, err := h.service.Diff(r.Context(), mux.Vars(r)["id"], from, to)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to diff rule versions")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, diff)
+}
+
+func (h *RuleVersionHandler) handleListActivations(w http.ResponseWriter, r *http.Request) {
+	activations, err := h.service.Activations(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get rule activations")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"activations": activations,
+		"total_count": len(activations),
+	})
+}
+
+func (h *RuleVersionHandler) handleRollback(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var req ruleversion.RollbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Version <= 0 {
+		respondError(w, h.logger, http.StatusBadRequest, "version must be a positive integer")
+		return
+	}
+
+	rule, err := h.service.Rollback(r.Context(), mux.Vars(r)["id"], req, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to roll back rule")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, rule)
+}
+
+func (h *RuleVersionHandler) handleGetAlertRuleVersion(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.ForAlert(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, database.ErrEvidenceNotFound) {
+			respondError(w, h.logger, http.StatusNotFound, "No evidence bundle for alert")
+			return
+		}
+		h.respondServiceError(w, err, "Failed to get alert rule version")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, result)
+}
+
+// respondServiceError maps missing rules and versions to 404, rollbacks to
+// the live definition to 409, invalid rollbacks to 400 or 422 and
+// everything else to 500
+func (h *RuleVersionHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		respondError(w, h.logger, http.StatusNotFound, "Rule not found")
+		return
+	case errors.Is(err, database.ErrRuleVersionNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, ruleversion.ErrAlreadyLive), errors.Is(err, ruleversion.ErrNameTaken):
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, ruleversion.ErrReasonRequired), errors.Is(err, ruleversion.ErrNotPriorVersion):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, ruleversion.ErrInvalidDefinition):
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
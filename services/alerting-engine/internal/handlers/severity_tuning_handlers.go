@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/severitytune"
+)
+
+// SeverityTuningHandler handles HTTP requests for rule severity tuning:
+// previews, proposals and their review, and per-rule guard rails
+type SeverityTuningHandler struct {
+	logger  *slog.Logger
+	service *severitytune.Service
+}
+
+// NewSeverityTuningHandler creates a new severity tuning handler
+func NewSeverityTuningHandler(logger *slog.Logger, service *severitytune.Service) *SeverityTuningHandler {
+	return &SeverityTuningHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers severity tuning routes
+func (h *SeverityTuningHandler) RegisterRoutes(router *mux.Router) {
+	tuningRouter := router.PathPrefix("/severity-tuning").Subrouter()
+	tuningRouter.HandleFunc("/preview", h.handlePreview).Methods("GET")
+	tuningRouter.HandleFunc("/proposals", h.handleListProposals).Methods("GET")
+	tuningRouter.HandleFunc("/proposals", h.handlePropose).Methods("POST")
+	tuningRouter.HandleFunc("/proposals/{id}", h.handleGetProposal).Methods("GET")
+	tuningRouter.HandleFunc("/proposals/{id}/approve", h.handleApprove).Methods("POST")
+	tuningRouter.HandleFunc("/proposals/{id}/reject", h.handleReject).Methods("POST")
+	tuningRouter.HandleFunc("/guardrails", h.handleListGuardrails).Methods("GET")
+	tuningRouter.HandleFunc("/guardrails/{rule_id}", h.handleGetGuardrail).Methods("GET")
+	tuningRouter.HandleFunc("/guardrails/{rule_id}", h.handleSetGuardrail).Methods("PUT")
+	tuningRouter.HandleFunc("/guardrails/{rule_id}", h.handleDeleteGuardrail).Methods("DELETE")
+}
+
+func (h *SeverityTuningHandler) handlePreview(w http.ResponseWriter, r *http.Request) {
+	query := severitytune.PreviewQuery{RuleID: r.URL.Query().Get("rule_id")}
+	if value := r.URL.Query().Get("since"); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondError(w, h.logger, http.StatusBadRequest, "Invalid since timestamp, expected RFC3339")
+			return
+		}
+		query.Since = &since
+	}
+
+	preview, err := h.service.Preview(r.Context(), query)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to preview severity tuning")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, preview)
+}
+
+func (h *SeverityTuningHandler) handlePropose(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	// The body is optional; without one the configured lookback is used
+	var query severitytune.PreviewQuery
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	proposal, err := h.service.Propose(r.Context(), query, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to propose severity tuning")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusCreated, proposal)
+}
+
+func (h *SeverityTuningHandler) handleListProposals(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+
+	proposals, err := h.service.ListProposals(r.Context(), r.URL.Query().Get("status"), limit)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list severity tuning proposals")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"proposals": proposals,
+		"count":     len(proposals),
+	})
+}
+
+func (h *SeverityTuningHandler) handleGetProposal(w http.ResponseWriter, r *http.Request) {
+	proposal, err := h.service.GetProposal(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get severity tuning proposal")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, proposal)
+}
+
+func (h *SeverityTuningHandler) handleApprove(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.Approve, "Failed to approve severity tuning proposal")
+}
+
+func (h *SeverityTuningHandler) handleReject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.Reject, "Failed to reject severity tuning proposal")
+}
+
+// review runs an approval or rejection as the authenticated reviewer
+func (h *SeverityTuningHandler) review(w http.ResponseWriter, r *http.Request,
+	decide func(ctx context.Context, id, reviewer string, input severitytune.ReviewInput) (*severitytune.ProposalDetail, error), message string) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var input severitytune.ReviewInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	proposal, err := decide(r.Context(), mux.Vars(r)["id"], subject.ID, input)
+	if err != nil {
+		h.respondServiceError(w, err, message)
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, proposal)
+}
+
+func (h *SeverityTuningHandler) handleListGuardrails(w http.ResponseWriter, r *http.Request) {
+	guardrails, err := h.service.ListGuardrails(r.Context())
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list severity guard rails")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"guardrails": guardrails,
+		"count":      len(guardrails),
+	})
+}
+
+func (h *SeverityTuningHandler) handleGetGuardrail(w http.ResponseWriter, r *http.Request) {
+	guardrail, err := h.service.GetGuardrail(r.Context(), mux.Vars(r)["rule_id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get severity guard rail")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, guardrail)
+}
+
+func (h *SeverityTuningHandler) handleSetGuardrail(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var guardrail severitytune.Guardrail
+	if err := json.NewDecoder(r.Body).Decode(&guardrail); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	saved, err := h.service.SetGuardrail(r.Context(), mux.Vars(r)["rule_id"], guardrail, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to save severity guard rail")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, saved)
+}
+
+func (h *SeverityTuningHandler) handleDeleteGuardrail(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteGuardrail(r.Context(), mux.Vars(r)["rule_id"]); err != nil {
+		h.respondServiceError(w, err, "Failed to delete severity guard rail")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondServiceError maps missing proposals, guard rails and rules to 404,
+// invalid guard rails to 400, self-approval to 403 and proposals that can no
+// longer be reviewed or have nothing to change to 409
+func (h *SeverityTuningHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrSeverityProposalNotFound),
+		errors.Is(err, database.ErrSeverityGuardrailNotFound),
+		errors.Is(err, severitytune.ErrRuleNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, severitytune.ErrInvalidGuardrail):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, severitytune.ErrSelfApproval):
+		respondError(w, h.logger, http.StatusForbidden, err.Error())
+		return
+	case errors.Is(err, database.ErrSeverityProposalReviewed),
+		errors.Is(err, severitytune.ErrProposalExpired),
+		errors.Is(err, severitytune.ErrNoChanges):
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/slo"
+)
+
+// SLOHandler exposes service-level objective and error budget status
+type SLOHandler struct {
+	logger  *slog.Logger
+	service *slo.Service
+}
+
+// NewSLOHandler creates a new SLO handler
+func NewSLOHandler(logger *slog.Logger, service *slo.Service) *SLOHandler {
+	return &SLOHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers SLO routes
+func (h *SLOHandler) RegisterRoutes(router *mux.Router) {
+	sloRouter := router.PathPrefix("/slo").Subrouter()
+	sloRouter.HandleFunc("/status", h.handleListStatus).Methods("GET")
+	sloRouter.HandleFunc("/status/{name}", h.handleGetStatus).Methods("GET")
+	sloRouter.HandleFunc("/evaluate", h.handleEvaluate).Methods("POST")
+}
+
+func (h *SLOHandler) handleListStatus(w http.ResponseWriter, r *http.Request) {
+	statuses := h.service.Statuses()
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"objectives":  statuses,
+		"total_count": len(statuses),
+	})
+}
+
+func (h *SLOHandler) handleGetStatus(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	status, ok := h.service.GetStatus(name)
+	if !ok {
+		respondError(w, h.logger, http.StatusNotFound, "SLO objective not found or not yet evaluated")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, status)
+}
+
+func (h *SLOHandler) handleEvaluate(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.service.Evaluate(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to evaluate SLOs", "error", err)
+		respondError(w, h.logger, http.StatusBadGateway, "Failed to evaluate SLOs")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"objectives":  statuses,
+		"total_count": len(statuses),
+	})
+}
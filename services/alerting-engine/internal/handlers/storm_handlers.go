@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/storm"
+)
+
+// StormHandler handles HTTP requests for alert storms and their reconciliation
+type StormHandler struct {
+	logger  *slog.Logger
+	service *storm.Service
+}
+
+// NewStormHandler creates a new alert storm handler
+func NewStormHandler(logger *slog.Logger, service *storm.Service) *StormHandler {
+	return &StormHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers alert storm routes
+func (h *StormHandler) RegisterRoutes(router *mux.Router) {
+	stormRouter := router.PathPrefix("/alert-storms").Subrouter()
+	stormRouter.HandleFunc("", h.handleListStorms).Methods("GET")
+	stormRouter.HandleFunc("/rates", h.handleListRates).Methods("GET")
+	stormRouter.HandleFunc("/sweep", h.handleSweep).Methods("POST")
+	stormRouter.HandleFunc("/{id}", h.handleGetStorm).Methods("GET")
+	stormRouter.HandleFunc("/{id}/held", h.handleListHeldAlerts).Methods("GET")
+	stormRouter.HandleFunc("/{id}/reconcile", h.handleReconcile).Methods("POST")
+}
+
+func (h *StormHandler) handleListStorms(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = l
+	}
+
+	storms, err := h.service.ListStorms(r.Context(), query.Get("status"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list alert storms", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list alert storms")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"storms":      storms,
+		"total_count": len(storms),
+	})
+}
+
+func (h *StormHandler) handleListRates(w http.ResponseWriter, r *http.Request) {
+	rates := h.service.Rates()
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"rates":       rates,
+		"total_count": len(rates),
+	})
+}
+
+func (h *StormHandler) handleSweep(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.Sweep(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to sweep alert storms", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to sweep alert storms")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, result)
+}
+
+func (h *StormHandler) handleGetStorm(w http.ResponseWriter, r *http.Request) {
+	alertStorm, err := h.service.GetStorm(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get alert storm")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, alertStorm)
+}
+
+func (h *StormHandler) handleListHeldAlerts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = l
+	}
+
+	held, err := h.service.ListHeldAlerts(r.Context(), mux.Vars(r)["id"], query.Get("status"), limit)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to list held alerts")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"held_alerts": held,
+		"total_count": len(held),
+	})
+}
+
+func (h *StormHandler) handleReconcile(w http.ResponseWriter, r *http.Request) {
+	var req storm.ReconcileInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := storm.ValidateReconcileInput(req); err != nil {
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	alertStorm, err := h.service.Reconcile(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to reconcile alert storm")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, alertStorm)
+}
+
+// respondServiceError maps missing storms to 404, storms not awaiting reconciliation to 409 and everything else to 500
+func (h *StormHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrStormNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, database.ErrStormNotEnded):
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/shared/rbac"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/dedup"
+)
+
+// SuppressionHandler handles HTTP requests for per-rule alert suppression settings
+type SuppressionHandler struct {
+	logger  *slog.Logger
+	service *dedup.Service
+}
+
+// NewSuppressionHandler creates a new suppression handler
+func NewSuppressionHandler(logger *slog.Logger, service *dedup.Service) *SuppressionHandler {
+	return &SuppressionHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers suppression routes
+func (h *SuppressionHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/rules/{id}/suppression", h.handleGetSuppression).Methods("GET")
+	router.HandleFunc("/rules/{id}/suppression", h.handleUpdateSuppression).Methods("PUT")
+	router.HandleFunc("/rules/{id}/suppression", h.handleResetSuppression).Methods("DELETE")
+}
+
+func (h *SuppressionHandler) handleGetSuppression(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to get rule suppression settings")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, settings)
+}
+
+func (h *SuppressionHandler) handleUpdateSuppression(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var input dedup.UpdateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	settings, err := h.service.Update(r.Context(), mux.Vars(r)["id"], input, subject.ID)
+	if err != nil {
+		h.respondServiceError(w, err, "Failed to update rule suppression settings")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, settings)
+}
+
+func (h *SuppressionHandler) handleResetSuppression(w http.ResponseWriter, r *http.Request) {
+	subject, ok := rbac.SubjectFromContext(r.Context())
+	if !ok {
+		respondError(w, h.logger, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	if err := h.service.Reset(r.Context(), mux.Vars(r)["id"], subject.ID); err != nil {
+		h.respondServiceError(w, err, "Failed to reset rule suppression settings")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondServiceError maps missing rules and settings to 404, invalid
+// settings to 400 and everything else to 500
+func (h *SuppressionHandler) respondServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		respondError(w, h.logger, http.StatusNotFound, "Rule not found")
+		return
+	case errors.Is(err, database.ErrSuppressionNotFound):
+		respondError(w, h.logger, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, dedup.ErrInvalidSuppression):
+		respondError(w, h.logger, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.logger.Error(message, "error", err)
+	respondError(w, h.logger, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/training"
+)
+
+// TrainingHandler handles HTTP requests for analyst training sessions
+type TrainingHandler struct {
+	logger    *slog.Logger
+	simulator *training.Simulator
+}
+
+// NewTrainingHandler creates a new training handler
+func NewTrainingHandler(logger *slog.Logger, simulator *training.Simulator) *TrainingHandler {
+	return &TrainingHandler{
+		logger:    logger,
+		simulator: simulator,
+	}
+}
+
+// RegisterRoutes registers training routes
+func (h *TrainingHandler) RegisterRoutes(router *mux.Router) {
+	trainingRouter := router.PathPrefix("/training/sessions").Subrouter()
+	trainingRouter.HandleFunc("", h.handleStartSession).Methods("POST")
+	trainingRouter.HandleFunc("", h.handleListSessions).Methods("GET")
+	trainingRouter.HandleFunc("/{id}", h.handleGetReport).Methods("GET")
+	trainingRouter.HandleFunc("/{id}/next", h.handleNextScenario).Methods("GET")
+	trainingRouter.HandleFunc("/{id}/scenarios/{scenario_id}/answer", h.handleSubmitAnswer).Methods("POST")
+	trainingRouter.HandleFunc("/{id}/complete", h.handleCompleteSession).Methods("POST")
+	trainingRouter.HandleFunc("/{id}/abandon", h.handleAbandonSession).Methods("POST")
+}
+
+func (h *TrainingHandler) handleStartSession(w http.ResponseWriter, r *http.Request) {
+	var req training.StartSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.TraineeID == "" {
+		respondError(w, h.logger, http.StatusBadRequest, "trainee_id is required")
+		return
+	}
+
+	session, err := h.simulator.StartSession(r.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to start training session", "trainee_id", req.TraineeID, "error", err)
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusCreated, session)
+}
+
+func (h *TrainingHandler) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil {
+		limit = l
+	}
+
+	sessions, err := h.simulator.ListSessions(r.Context(), r.URL.Query().Get("trainee_id"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list training sessions", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list training sessions")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"sessions":    sessions,
+		"total_count": len(sessions),
+	})
+}
+
+func (h *TrainingHandler) handleGetReport(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+
+	report, err := h.simulator.Report(r.Context(), sessionID)
+	if err != nil {
+		respondError(w, h.logger, http.StatusNotFound, "Training session not found")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, report)
+}
+
+func (h *TrainingHandler) handleNextScenario(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+
+	scenario, err := h.simulator.NextScenario(r.Context(), sessionID)
+	if err != nil {
+		h.logger.Error("Failed to get next training scenario", "session_id", sessionID, "error", err)
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	}
+
+	if scenario == nil {
+		respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+			"completed": true,
+		})
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, scenario)
+}
+
+func (h *TrainingHandler) handleSubmitAnswer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+	scenarioID := vars["scenario_id"]
+
+	var answer training.Answer
+	if err := json.NewDecoder(r.Body).Decode(&answer); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if answer.Disposition == "" {
+		respondError(w, h.logger, http.StatusBadRequest, "disposition is required")
+		return
+	}
+
+	result, err := h.simulator.SubmitAnswer(r.Context(), sessionID, scenarioID, answer)
+	if err != nil {
+		h.logger.Error("Failed to submit training answer",
+			"session_id", sessionID,
+			"scenario_id", scenarioID,
+			"error", err)
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, result)
+}
+
+func (h *TrainingHandler) handleCompleteSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+
+	report, err := h.simulator.CompleteSession(r.Context(), sessionID)
+	if err != nil {
+		h.logger.Error("Failed to complete training session", "session_id", sessionID, "error", err)
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, report)
+}
+
+func (h *TrainingHandler) handleAbandonSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+
+	if err := h.simulator.AbandonSession(r.Context(), sessionID); err != nil {
+		h.logger.Error("Failed to abandon training session", "session_id", sessionID, "error", err)
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{"success": true})
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/watchlist"
+)
+
+// WatchlistHandler handles HTTP requests for dual-controlled watchlist and exclusion lists
+type WatchlistHandler struct {
+	logger  *slog.Logger
+	service *watchlist.Service
+}
+
+// NewWatchlistHandler creates a new watchlist handler
+func NewWatchlistHandler(logger *slog.Logger, service *watchlist.Service) *WatchlistHandler {
+	return &WatchlistHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// RegisterRoutes registers watchlist routes
+func (h *WatchlistHandler) RegisterRoutes(router *mux.Router) {
+	watchlistRouter := router.PathPrefix("/watchlists").Subrouter()
+	watchlistRouter.HandleFunc("/entries", h.handleListEntries).Methods("GET")
+	watchlistRouter.HandleFunc("/changes", h.handleRequestChange).Methods("POST")
+	watchlistRouter.HandleFunc("/changes", h.handleListChanges).Methods("GET")
+	watchlistRouter.HandleFunc("/changes/{id}/approve", h.handleApproveChange).Methods("POST")
+	watchlistRouter.HandleFunc("/changes/{id}/reject", h.handleRejectChange).Methods("POST")
+	watchlistRouter.HandleFunc("/changes/{id}/cancel", h.handleCancelChange).Methods("POST")
+	watchlistRouter.HandleFunc("/reports/exclusions", h.handleExclusionReport).Methods("GET")
+}
+
+// reviewRequest carries the second approver's decision on a change
+type reviewRequest struct {
+	ReviewerID string `json:"reviewer_id"`
+	Comment    string `json:"comment"`
+}
+
+func (h *WatchlistHandler) handleRequestChange(w http.ResponseWriter, r *http.Request) {
+	var req watchlist.ChangeRequestInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	change, err := h.service.RequestChange(r.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to request watchlist change",
+			"list_type", req.ListType,
+			"entity_id", req.EntityID,
+			"error", err)
+		respondError(w, h.logger, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusAccepted, change)
+}
+
+func (h *WatchlistHandler) handleListChanges(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	status := query.Get("status")
+	if status == "" {
+		status = database.ChangeStatusPending
+	} else if status == "all" {
+		status = ""
+	}
+
+	limit := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = l
+	}
+
+	changes, err := h.service.ListChanges(r.Context(), status, query.Get("list_type"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list watchlist changes", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list watchlist changes")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"changes":     changes,
+		"total_count": len(changes),
+	})
+}
+
+func (h *WatchlistHandler) handleApproveChange(w http.ResponseWriter, r *http.Request) {
+	changeID := mux.Vars(r)["id"]
+
+	var req reviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	change, err := h.service.Approve(r.Context(), changeID, req.ReviewerID, req.Comment)
+	if err != nil {
+		h.logger.Error("Failed to approve watchlist change", "change_id", changeID, "error", err)
+		respondError(w, h.logger, reviewErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, change)
+}
+
+func (h *WatchlistHandler) handleRejectChange(w http.ResponseWriter, r *http.Request) {
+	changeID := mux.Vars(r)["id"]
+
+	var req reviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, h.logger, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	change, err := h.service.Reject(r.Context(), changeID, req.ReviewerID, req.Comment)
+	if err != nil {
+		h.logger.Error("Failed to reject watchlist change", "change_id", changeID, "error", err)
+		respondError(w, h.logger, reviewErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, change)
+}
+
+func (h *WatchlistHandler) handleCancelChange(w http.ResponseWriter, r *http.Request) {
+	changeID := mux.Vars(r)["id"]
+
+	var req struct {
+		RequestedBy string `json:"requested_by"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RequestedBy == "" {
+		respondError(w, h.logger, http.StatusBadRequest, "requested_by is required")
+		return
+	}
+
+	if err := h.service.Cancel(r.Context(), changeID, req.RequestedBy); err != nil {
+		h.logger.Error("Failed to cancel watchlist change", "change_id", changeID, "error", err)
+		respondError(w, h.logger, http.StatusConflict, err.Error())
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{"success": true})
+}
+
+func (h *WatchlistHandler) handleListEntries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	status := query.Get("status")
+	if status == "" {
+		status = database.EntryStatusActive
+	} else if status == "all" {
+		status = ""
+	}
+
+	entries, err := h.service.ListEntries(r.Context(), query.Get("list_type"), status)
+	if err != nil {
+		h.logger.Error("Failed to list watchlist entries", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to list watchlist entries")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, map[string]interface{}{
+		"entries":     entries,
+		"total_count": len(entries),
+	})
+}
+
+func (h *WatchlistHandler) handleExclusionReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.ExclusionReport(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to build exclusion report", "error", err)
+		respondError(w, h.logger, http.StatusInternalServerError, "Failed to build exclusion report")
+		return
+	}
+
+	respondJSON(w, h.logger, http.StatusOK, report)
+}
+
+// reviewErrorStatus maps dual-control failures to HTTP status codes
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, watchlist.ErrSelfApproval):
+		return http.StatusForbidden
+	case errors.Is(err, watchlist.ErrNotPending):
+		return http.StatusConflict
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
@@ -0,0 +1,153 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+)
+
+// HistoryReader reads a past window of the event topic outside the consumer
+// group, so a backtest neither moves committed offsets nor takes partitions
+// from the live consumer. The window can reach back only as far as the
+// topic's retention.
+type HistoryReader struct {
+	config *config.Config
+	logger *slog.Logger
+}
+
+// NewHistoryReader creates a reader over the event topic
+func NewHistoryReader(cfg *config.Config, logger *slog.Logger) *HistoryReader {
+	return &HistoryReader{
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// Replay calls fn with every event on the topic whose Kafka timestamp falls
+// in [from, to), partition by partition and in offset order within each.
+// Messages that are not event messages are skipped, as the consumer would
+// dead-letter them. An error from fn stops the replay and is returned.
+func (h *HistoryReader) Replay(ctx context.Context, from, to time.Time, fn func(event map[string]interface{}, at time.Time) error) error {
+	topic := h.config.Kafka.Consumer.EventTopic
+
+	partitions, err := h.partitions(ctx, topic)
+	if err != nil {
+		return err
+	}
+
+	for _, partition := range partitions {
+		if err := h.replayPartition(ctx, topic, partition, from, to, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// partitions lists the partition IDs of a topic
+func (h *HistoryReader) partitions(ctx context.Context, topic string) ([]int, error) {
+	var lastErr error
+	for _, broker := range h.config.Kafka.Brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		partitions, err := conn.ReadPartitions(topic)
+		conn.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read partitions of %s: %w", topic, err)
+		}
+
+		ids := make([]int, len(partitions))
+		for i, partition := range partitions {
+			ids[i] = partition.ID
+		}
+		return ids, nil
+	}
+
+	return nil, fmt.Errorf("failed to dial any broker: %w", lastErr)
+}
+
+// offsets returns the first offset at or after a time and the offset the
+// next message will be written at, which bound the messages to read
+func (h *HistoryReader) offsets(ctx context.Context, topic string, partition int, from time.Time) (int64, int64, error) {
+	var lastErr error
+	for _, broker := range h.config.Kafka.Brokers {
+		conn, err := kafka.DialLeader(ctx, "tcp", broker, topic, partition)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		defer conn.Close()
+
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+		end, err := conn.ReadLastOffset()
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to read last offset of partition %d: %w", partition, err)
+		}
+		start, err := conn.ReadOffset(from)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to read offset of partition %d at %s: %w", partition, from, err)
+		}
+		return start, end, nil
+	}
+
+	return 0, 0, fmt.Errorf("failed to dial leader of partition %d: %w", partition, lastErr)
+}
+
+func (h *HistoryReader) replayPartition(ctx context.Context, topic string, partition int, from, to time.Time, fn func(event map[string]interface{}, at time.Time) error) error {
+	start, end, err := h.offsets(ctx, topic, partition, from)
+	if err != nil {
+		return err
+	}
+	// A negative start means no message was written at or after from
+	if start < 0 || start >= end {
+		return nil
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     h.config.Kafka.Brokers,
+		Topic:       topic,
+		Partition:   partition,
+		Logger:      &KafkaLogger{logger: h.logger},
+		ErrorLogger: &KafkaErrorLogger{logger: h.logger},
+	})
+	defer reader.Close()
+
+	if err := reader.SetOffset(start); err != nil {
+		return fmt.Errorf("failed to seek partition %d: %w", partition, err)
+	}
+
+	for {
+		message, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read partition %d: %w", partition, err)
+		}
+		if !message.Time.Before(to) {
+			return nil
+		}
+
+		var eventMsg EventMessage
+		if err := json.Unmarshal(message.Value, &eventMsg); err != nil {
+			h.logger.Debug("Skipping malformed event during replay",
+				"partition", partition,
+				"offset", message.Offset,
+				"error", err)
+		} else if err := fn(eventMsg.EvaluationData(), message.Time); err != nil {
+			return err
+		}
+
+		if message.Offset >= end-1 {
+			return nil
+		}
+	}
+}
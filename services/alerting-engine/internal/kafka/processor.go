@@ -13,7 +13,9 @@ import (
 
 	"github.com/aegis-shield/services/alerting-engine/internal/config"
 	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/deadletter"
 	"github.com/aegis-shield/services/alerting-engine/internal/engine"
+	"github.com/aegisshield/shared/reqctx"
 )
 
 // Consumer handles Kafka message consumption for event processing
@@ -24,22 +26,27 @@ type Consumer struct {
 	ruleEngine       *engine.RuleEngine
 	alertRepo        *database.AlertRepository
 	notificationRepo *database.NotificationRepository
+	retrier          *deadletter.Retrier
+	requestContext   reqctx.Options
 	shutdownChan     chan struct{}
 	wg               sync.WaitGroup
 	messageCount     int64
 	errorCount       int64
+	retryCount       int64
+	deadLetterCount  int64
 	lastProcessed    time.Time
 }
 
 // Producer handles Kafka message production for alert notifications
 type Producer struct {
-	config       *config.Config
-	logger       *slog.Logger
-	writer       *kafka.Writer
-	shutdownChan chan struct{}
-	wg           sync.WaitGroup
-	messageCount int64
-	errorCount   int64
+	config         *config.Config
+	logger         *slog.Logger
+	writer         *kafka.Writer
+	requestContext reqctx.Options
+	shutdownChan   chan struct{}
+	wg             sync.WaitGroup
+	messageCount   int64
+	errorCount     int64
 }
 
 // EventMessage represents an incoming event message
@@ -52,6 +59,23 @@ type EventMessage struct {
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// EvaluationData flattens an event message into the event rule conditions see
+func (m *EventMessage) EvaluationData() map[string]interface{} {
+	eventData := map[string]interface{}{
+		"id":        m.ID,
+		"type":      m.Type,
+		"source":    m.Source,
+		"timestamp": m.Timestamp,
+	}
+
+	// Add event data
+	for k, v := range m.Data {
+		eventData[k] = v
+	}
+
+	return eventData
+}
+
 // AlertMessage represents an outgoing alert notification
 type AlertMessage struct {
 	AlertID     string                 `json:"alert_id"`
@@ -74,6 +98,7 @@ func NewConsumer(
 	ruleEngine *engine.RuleEngine,
 	alertRepo *database.AlertRepository,
 	notificationRepo *database.NotificationRepository,
+	retrier *deadletter.Retrier,
 ) (*Consumer, error) {
 	// Configure Kafka reader
 	reader := kafka.NewReader(kafka.ReaderConfig{
@@ -95,12 +120,19 @@ func NewConsumer(
 		ruleEngine:       ruleEngine,
 		alertRepo:        alertRepo,
 		notificationRepo: notificationRepo,
+		retrier:          retrier,
 		shutdownChan:     make(chan struct{}),
 	}
 
 	return consumer, nil
 }
 
+// SetRequestContext sets how the correlation IDs of consumed events are
+// accepted; strict options dead-letter events that carry none
+func (c *Consumer) SetRequestContext(opts reqctx.Options) {
+	c.requestContext = opts
+}
+
 // Start starts the Kafka consumer
 func (c *Consumer) Start(ctx context.Context) error {
 	c.logger.Info("Starting Kafka consumer",
@@ -147,9 +179,10 @@ func (c *Consumer) worker(ctx context.Context, workerID int) {
 		case <-c.shutdownChan:
 			return
 		default:
-			// Read message with timeout
+			// Fetch message with timeout; offsets are committed only once the
+			// message has been processed or dead-lettered
 			readCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-			message, err := c.reader.ReadMessage(readCtx)
+			message, err := c.reader.FetchMessage(readCtx)
 			cancel()
 
 			if err != nil {
@@ -164,51 +197,78 @@ func (c *Consumer) worker(ctx context.Context, workerID int) {
 				continue
 			}
 
-			// Process message
-			if err := c.processMessage(ctx, &message); err != nil {
-				c.logger.Error("Failed to process Kafka message",
+			// Process message, retrying and dead-lettering it on failure
+			result, err := c.retrier.Process(ctx, toDeadLetterMessage(&message), c.processMessage)
+			if result.Attempts > 1 {
+				c.retryCount += int64(result.Attempts - 1)
+			}
+			if err != nil {
+				// Shutting down mid-retry; the uncommitted message is consumed again
+				c.logger.Warn("Stopped processing Kafka message before it was handled",
 					"worker_id", workerID,
 					"topic", message.Topic,
 					"partition", message.Partition,
 					"offset", message.Offset,
 					"error", err)
+				return
+			}
+
+			switch result.Outcome {
+			case deadletter.OutcomeDeadLettered:
+				c.logger.Error("Failed to process Kafka message, dead-lettered",
+					"worker_id", workerID,
+					"topic", message.Topic,
+					"partition", message.Partition,
+					"offset", message.Offset,
+					"attempts", result.Attempts,
+					"error", result.Err)
 				c.errorCount++
-			} else {
+				c.deadLetterCount++
+			default:
 				c.messageCount++
 				c.lastProcessed = time.Now()
 			}
+
+			if err := c.reader.CommitMessages(ctx, message); err != nil {
+				c.logger.Error("Failed to commit Kafka message",
+					"worker_id", workerID,
+					"topic", message.Topic,
+					"partition", message.Partition,
+					"offset", message.Offset,
+					"error", err)
+			}
 		}
 	}
 }
 
 // processMessage processes a single Kafka message
-func (c *Consumer) processMessage(ctx context.Context, message *kafka.Message) error {
-	// Parse event message
+func (c *Consumer) processMessage(ctx context.Context, message *deadletter.Message) error {
+	// Join the event to the request that produced it; an event without a
+	// correlation ID never gains one on retry, so strict mode skips the retries
+	ctx, err := c.requestContext.ConsumeContext(ctx, message.Topic, message.Headers)
+	if err != nil {
+		return deadletter.Permanent(fmt.Errorf("rejected event message: %w", err))
+	}
+	// Keep a started correlation ID on the message so retries and the dead
+	// letter carry the same one
+	if message.Headers == nil {
+		message.Headers = make(map[string]string)
+	}
+	reqctx.Inject(ctx, "", reqctx.MapCarrier(message.Headers))
+
+	// Parse event message; a malformed payload never parses, so skip the retries
 	var eventMsg EventMessage
 	if err := json.Unmarshal(message.Value, &eventMsg); err != nil {
-		return fmt.Errorf("failed to unmarshal event message: %w", err)
+		return deadletter.Permanent(fmt.Errorf("failed to unmarshal event message: %w", err))
 	}
 
-	c.logger.Debug("Processing event message",
+	c.logger.DebugContext(ctx, "Processing event message",
 		"event_id", eventMsg.ID,
 		"event_type", eventMsg.Type,
 		"source", eventMsg.Source)
 
-	// Create evaluation context
-	eventData := map[string]interface{}{
-		"id":        eventMsg.ID,
-		"type":      eventMsg.Type,
-		"source":    eventMsg.Source,
-		"timestamp": eventMsg.Timestamp,
-	}
-
-	// Add event data
-	for k, v := range eventMsg.Data {
-		eventData[k] = v
-	}
-
 	// Evaluate event against rules
-	results, err := c.ruleEngine.EvaluateEvent(ctx, eventData)
+	results, err := c.ruleEngine.EvaluateEvent(ctx, eventMsg.EvaluationData())
 	if err != nil {
 		return fmt.Errorf("failed to evaluate event against rules: %w", err)
 	}
@@ -216,7 +276,7 @@ func (c *Consumer) processMessage(ctx context.Context, message *kafka.Message) e
 	// Process matched rules
 	for _, result := range results {
 		if result.Matched {
-			c.logger.Info("Rule matched for event",
+			c.logger.InfoContext(ctx, "Rule matched for event",
 				"event_id", eventMsg.ID,
 				"rule_id", result.RuleID,
 				"rule_name", result.RuleName,
@@ -230,6 +290,24 @@ func (c *Consumer) processMessage(ctx context.Context, message *kafka.Message) e
 	return nil
 }
 
+// toDeadLetterMessage copies a consumed message into the client-independent form the retrier works on
+func toDeadLetterMessage(message *kafka.Message) *deadletter.Message {
+	headers := make(map[string]string, len(message.Headers))
+	for _, header := range message.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+
+	return &deadletter.Message{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+		Key:       message.Key,
+		Value:     message.Value,
+		Headers:   headers,
+		Time:      message.Time,
+	}
+}
+
 // metricsReporter reports consumer metrics
 func (c *Consumer) metricsReporter(ctx context.Context) {
 	defer c.wg.Done()
@@ -247,6 +325,8 @@ func (c *Consumer) metricsReporter(ctx context.Context) {
 			c.logger.Debug("Kafka consumer metrics",
 				"messages_processed", c.messageCount,
 				"errors", c.errorCount,
+				"retries", c.retryCount,
+				"dead_lettered", c.deadLetterCount,
 				"last_processed", c.lastProcessed)
 		}
 	}
@@ -257,6 +337,8 @@ func (c *Consumer) GetStats() map[string]interface{} {
 	return map[string]interface{}{
 		"messages_processed": c.messageCount,
 		"errors":            c.errorCount,
+		"retries":           c.retryCount,
+		"dead_lettered":     c.deadLetterCount,
 		"last_processed":    c.lastProcessed,
 		"is_running":        c.reader != nil,
 	}
@@ -290,6 +372,12 @@ func NewProducer(cfg *config.Config, logger *slog.Logger) (*Producer, error) {
 	return producer, nil
 }
 
+// SetRequestContext sets the service named as the producer of the
+// correlation IDs added to published messages
+func (p *Producer) SetRequestContext(opts reqctx.Options) {
+	p.requestContext = opts
+}
+
 // Start starts the Kafka producer
 func (p *Producer) Start(ctx context.Context) error {
 	p.logger.Info("Starting Kafka producer", "topic", p.config.Kafka.Producer.AlertTopic)
@@ -363,6 +451,9 @@ func (p *Producer) PublishAlert(ctx context.Context, alert *database.Alert) erro
 			{Key: "type", Value: []byte(alert.Type)},
 		},
 	}
+	for name, value := range reqctx.MessageHeaders(ctx, p.requestContext.Service) {
+		kafkaMsg.Headers = append(kafkaMsg.Headers, kafka.Header{Key: name, Value: []byte(value)})
+	}
 
 	// Write message
 	if err := p.writer.WriteMessages(ctx, kafkaMsg); err != nil {
@@ -420,6 +511,9 @@ func (p *Producer) PublishNotification(ctx context.Context, notification *databa
 			{Key: "status", Value: []byte(notification.Status)},
 		},
 	}
+	for name, value := range reqctx.MessageHeaders(ctx, p.requestContext.Service) {
+		kafkaMsg.Headers = append(kafkaMsg.Headers, kafka.Header{Key: name, Value: []byte(value)})
+	}
 
 	// Write message to notifications topic
 	writer := &kafka.Writer{
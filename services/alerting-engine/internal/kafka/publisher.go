@@ -0,0 +1,55 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+)
+
+// TopicPublisher writes messages to whichever topic each one names. The
+// dead-letter service uses it to park failed events and to replay them to
+// their source topic.
+type TopicPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewTopicPublisher creates a publisher that waits for every in-sync
+// replica, since a dead letter that is lost cannot be replayed
+func NewTopicPublisher(cfg *config.Config, logger *slog.Logger) *TopicPublisher {
+	return &TopicPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Kafka.Brokers...),
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			Logger:       &KafkaLogger{logger: logger},
+			ErrorLogger:  &KafkaErrorLogger{logger: logger},
+		},
+	}
+}
+
+// Publish writes one message to a topic
+func (p *TopicPublisher) Publish(ctx context.Context, topic string, key, value []byte, headers map[string]string) error {
+	message := kafka.Message{
+		Topic: topic,
+		Key:   key,
+		Value: value,
+	}
+	for name, headerValue := range headers {
+		message.Headers = append(message.Headers, kafka.Header{Key: name, Value: []byte(headerValue)})
+	}
+
+	if err := p.writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to write message to %s: %w", topic, err)
+	}
+
+	return nil
+}
+
+// Close flushes and closes the underlying writer
+func (p *TopicPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,402 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/notifytemplate"
+)
+
+var (
+	// ErrInvalidRoute is returned for notification routes that cannot be stored
+	ErrInvalidRoute = errors.New("invalid notification route")
+	// ErrRuleNotFound is returned when routing notifications of a rule that does not exist
+	ErrRuleNotFound = errors.New("rule not found")
+	// ErrNoProvider is returned when notifying over a channel without an enabled provider
+	ErrNoProvider = errors.New("no provider for notification channel")
+)
+
+var notificationDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "alerting_engine_notification_deliveries_total",
+	Help: "Notification delivery attempts through channel providers, by channel and status",
+}, []string{"channel", "status"})
+
+// RouteStore persists notification routes and delivery attempts
+type RouteStore interface {
+	CreateRoute(ctx context.Context, route *database.NotificationRoute) error
+	GetRoute(ctx context.Context, id string) (*database.NotificationRoute, error)
+	ListRoutes(ctx context.Context, ruleID string) ([]*database.NotificationRoute, error)
+	UpdateRoute(ctx context.Context, route *database.NotificationRoute) error
+	DeleteRoute(ctx context.Context, id string) error
+	RecordDelivery(ctx context.Context, delivery *database.NotificationDelivery) error
+	ListDeliveries(ctx context.Context, filter database.NotificationDeliveryFilter) ([]*database.NotificationDelivery, error)
+}
+
+// RuleLookup finds the rules routes belong to
+type RuleLookup interface {
+	GetByID(ctx context.Context, id string) (*database.Rule, error)
+}
+
+// Renderer renders the subject and body of a rule notification for a
+// channel from the templates configured for the rule
+type Renderer interface {
+	RenderNotification(ctx context.Context, channel string, rule *database.Rule, alert *database.Alert, event map[string]interface{}) (string, string, error)
+}
+
+// RouteInput describes a notification route to create or update. Updates
+// replace the recipient and minimum severity; the channel cannot be changed
+// once a route exists.
+type RouteInput struct {
+	Channel     string `json:"channel"`
+	Recipient   string `json:"recipient,omitempty"`
+	MinSeverity string `json:"min_severity,omitempty"`
+	Enabled     *bool  `json:"enabled,omitempty"`
+}
+
+// Dispatcher routes rule notifications to channel providers and records
+// every delivery attempt. A rule's enabled routes decide where its
+// notifications go; a rule without routes notifies the channels it lists,
+// at each provider's default recipient.
+type Dispatcher struct {
+	logger   *slog.Logger
+	registry *Registry
+	store    RouteStore
+	rules    RuleLookup
+	renderer Renderer
+	now      func() time.Time
+}
+
+// NewDispatcher creates a new notification dispatcher. A nil renderer sends
+// notifications without a subject or message with the built-in text.
+func NewDispatcher(logger *slog.Logger, registry *Registry, store RouteStore, rules RuleLookup, renderer Renderer) *Dispatcher {
+	return &Dispatcher{
+		logger:   logger,
+		registry: registry,
+		store:    store,
+		rules:    rules,
+		renderer: renderer,
+		now:      time.Now,
+	}
+}
+
+// Channels returns the channels notifications can be routed to
+func (d *Dispatcher) Channels() []string {
+	return d.registry.Channels()
+}
+
+// Route management
+
+// CreateRoute validates and stores a notification route for a rule
+func (d *Dispatcher) CreateRoute(ctx context.Context, ruleID string, input RouteInput, actor string) (*database.NotificationRoute, error) {
+	if err := d.checkRule(ctx, ruleID); err != nil {
+		return nil, err
+	}
+
+	route := &database.NotificationRoute{
+		ID:        generateID("route"),
+		RuleID:    ruleID,
+		Channel:   strings.TrimSpace(input.Channel),
+		Enabled:   true,
+		CreatedBy: actor,
+		UpdatedBy: actor,
+	}
+	if err := d.applyInput(route, input); err != nil {
+		return nil, err
+	}
+	if err := d.store.CreateRoute(ctx, route); err != nil {
+		return nil, err
+	}
+
+	d.logger.Info("Notification route created",
+		"route_id", route.ID,
+		"rule_id", ruleID,
+		"channel", route.Channel,
+		"actor", actor)
+	return route, nil
+}
+
+// ListRoutes retrieves a rule's notification routes
+func (d *Dispatcher) ListRoutes(ctx context.Context, ruleID string) ([]*database.NotificationRoute, error) {
+	if err := d.checkRule(ctx, ruleID); err != nil {
+		return nil, err
+	}
+	return d.store.ListRoutes(ctx, ruleID)
+}
+
+// UpdateRoute changes a route's recipient, minimum severity or state
+func (d *Dispatcher) UpdateRoute(ctx context.Context, id string, input RouteInput, actor string) (*database.NotificationRoute, error) {
+	route, err := d.store.GetRoute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if channel := strings.TrimSpace(input.Channel); channel != "" && channel != route.Channel {
+		return nil, fmt.Errorf("%w: the channel of a route cannot be changed", ErrInvalidRoute)
+	}
+
+	if err := d.applyInput(route, input); err != nil {
+		return nil, err
+	}
+	route.UpdatedBy = actor
+	if err := d.store.UpdateRoute(ctx, route); err != nil {
+		return nil, err
+	}
+
+	d.logger.Info("Notification route updated", "route_id", id, "actor", actor)
+	return route, nil
+}
+
+// DeleteRoute deletes a notification route
+func (d *Dispatcher) DeleteRoute(ctx context.Context, id string) error {
+	return d.store.DeleteRoute(ctx, id)
+}
+
+// ListDeliveries retrieves delivery attempts, newest first
+func (d *Dispatcher) ListDeliveries(ctx context.Context, filter database.NotificationDeliveryFilter) ([]*database.NotificationDelivery, error) {
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		filter.Limit = 100
+	}
+	return d.store.ListDeliveries(ctx, filter)
+}
+
+// Dispatch
+
+// target is one channel and recipient a notification is sent to
+type target struct {
+	channel   string
+	recipient string
+	routeID   *string
+}
+
+// DispatchNotification sends a rule notification. With a recipient it goes
+// to that recipient on the given channel only; without one it follows the
+// rule's routes, limited to the given channel if there is one. Subject and
+// message left empty are rendered from the rule's templates per channel.
+// Every target is attempted and recorded; the failures are returned together.
+func (d *Dispatcher) DispatchNotification(ctx context.Context, rule *database.Rule, alert *database.Alert, event map[string]interface{}, channel, recipient, subject, message, priority string) error {
+	targets, err := d.resolve(ctx, rule, alert, channel, recipient)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		d.logger.Debug("No notification targets for rule", "rule_id", rule.ID, "channel", channel)
+		return nil
+	}
+
+	var errs []error
+	for _, t := range targets {
+		msg := d.buildMessage(ctx, t, rule, alert, event, subject, message, priority)
+		if err := d.Send(ctx, t.channel, msg, t.routeID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Send delivers a message through its channel's provider and records the attempt
+func (d *Dispatcher) Send(ctx context.Context, channel string, message *Message, routeID *string) error {
+	provider, ok := d.registry.Provider(channel)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoProvider, channel)
+	}
+
+	started := d.now()
+	receipt, err := provider.Send(ctx, message)
+	delivery := &database.NotificationDelivery{
+		ID:        generateID("delivery"),
+		RouteID:   routeID,
+		Channel:   channel,
+		Recipient: message.Recipient,
+		Status:    database.DeliveryStatusSent,
+		LatencyMs: d.now().Sub(started).Milliseconds(),
+		CreatedAt: started,
+	}
+	delivery.NotificationID = optional(message.NotificationID)
+	delivery.RuleID = optional(message.RuleID)
+	delivery.AlertID = optional(message.AlertID)
+
+	if err != nil {
+		delivery.Status = database.DeliveryStatusFailed
+		errMessage := truncate(err.Error(), 1000)
+		delivery.Error = &errMessage
+		var providerErr *ProviderError
+		if errors.As(err, &providerErr) {
+			delivery.ResponseCode = &providerErr.StatusCode
+		}
+	} else {
+		delivery.ResponseCode = &receipt.StatusCode
+		delivery.ExternalID = optional(receipt.ExternalID)
+		delivery.ProviderStatus = optional(receipt.ProviderStatus)
+	}
+	notificationDeliveries.WithLabelValues(channel, delivery.Status).Inc()
+
+	if recordErr := d.store.RecordDelivery(ctx, delivery); recordErr != nil {
+		d.logger.Error("Failed to record notification delivery",
+			"delivery_id", delivery.ID,
+			"channel", channel,
+			"error", recordErr)
+	}
+
+	if err != nil {
+		d.logger.Warn("Notification delivery failed",
+			"rule_id", message.RuleID,
+			"alert_id", message.AlertID,
+			"channel", channel,
+			"error", err)
+		return err
+	}
+
+	d.logger.Info("Notification delivered",
+		"rule_id", message.RuleID,
+		"alert_id", message.AlertID,
+		"channel", channel,
+		"external_id", receipt.ExternalID)
+	return nil
+}
+
+// resolve decides where a notification goes
+func (d *Dispatcher) resolve(ctx context.Context, rule *database.Rule, alert *database.Alert, channel, recipient string) ([]target, error) {
+	if recipient != "" {
+		return []target{{channel: channel, recipient: recipient}}, nil
+	}
+
+	routes, err := d.store.ListRoutes(ctx, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []target
+	if len(routes) > 0 {
+		severity := rule.Severity
+		if alert != nil && alert.Severity != "" {
+			severity = alert.Severity
+		}
+		for _, route := range routes {
+			if !route.Enabled || (channel != "" && route.Channel != channel) {
+				continue
+			}
+			if route.MinSeverity != nil && severityRank(severity) < severityRank(*route.MinSeverity) {
+				continue
+			}
+			targets = append(targets, target{channel: route.Channel, recipient: route.Recipient, routeID: &route.ID})
+		}
+		return targets, nil
+	}
+
+	// Without routes, notify the rule's channels at their default recipients
+	for _, ruleChannel := range rule.NotificationChannels {
+		if channel != "" && ruleChannel != channel {
+			continue
+		}
+		provider, ok := d.registry.Provider(ruleChannel)
+		if !ok || provider.ValidateRecipient("") != nil {
+			continue
+		}
+		targets = append(targets, target{channel: ruleChannel})
+	}
+	return targets, nil
+}
+
+func (d *Dispatcher) buildMessage(ctx context.Context, t target, rule *database.Rule, alert *database.Alert, event map[string]interface{}, subject, message, priority string) *Message {
+	if d.renderer != nil && notifytemplate.IsChannel(t.channel) && (subject == "" || message == "") {
+		renderedSubject, renderedMessage, err := d.renderer.RenderNotification(ctx, t.channel, rule, alert, event)
+		if err != nil {
+			d.logger.Error("Failed to render notification template, using default text",
+				"rule_id", rule.ID,
+				"channel", t.channel,
+				"error", err)
+		} else {
+			if subject == "" {
+				subject = renderedSubject
+			}
+			if message == "" {
+				message = renderedMessage
+			}
+		}
+	}
+	if subject == "" {
+		subject = "Alert: " + rule.Name
+	}
+	if message == "" {
+		message = "Rule " + rule.Name + " has been triggered"
+	}
+
+	msg := &Message{
+		RuleID:    rule.ID,
+		RuleName:  rule.Name,
+		Recipient: t.recipient,
+		Subject:   subject,
+		Body:      message,
+		Severity:  rule.Severity,
+		Priority:  priority,
+		CreatedAt: d.now(),
+	}
+	if alert != nil {
+		msg.AlertID = alert.ID
+		if alert.Severity != "" {
+			msg.Severity = alert.Severity
+		}
+		msg.Details = map[string]interface{}{
+			"alert_title": alert.Title,
+			"entity_ids":  alert.EntityIDs,
+		}
+	}
+	return msg
+}
+
+// Helpers
+
+func (d *Dispatcher) applyInput(route *database.NotificationRoute, input RouteInput) error {
+	provider, ok := d.registry.Provider(route.Channel)
+	if !ok {
+		return fmt.Errorf("%w: channel %q has no enabled provider; available: %s",
+			ErrInvalidRoute, route.Channel, strings.Join(d.registry.Channels(), ", "))
+	}
+
+	recipient := strings.TrimSpace(input.Recipient)
+	if err := provider.ValidateRecipient(recipient); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRoute, err)
+	}
+	route.Recipient = recipient
+
+	route.MinSeverity = nil
+	if input.MinSeverity != "" {
+		if severityRank(input.MinSeverity) == 0 {
+			return fmt.Errorf("%w: unknown severity %q", ErrInvalidRoute, input.MinSeverity)
+		}
+		minSeverity := input.MinSeverity
+		route.MinSeverity = &minSeverity
+	}
+
+	if input.Enabled != nil {
+		route.Enabled = *input.Enabled
+	}
+	return nil
+}
+
+func (d *Dispatcher) checkRule(ctx context.Context, ruleID string) error {
+	_, err := d.rules.GetByID(ctx, ruleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrRuleNotFound
+	}
+	return err
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
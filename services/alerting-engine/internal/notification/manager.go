@@ -15,8 +15,6 @@ import (
 
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
-	"github.com/twilio/twilio-go"
-	"github.com/twilio/twilio-go/rest/api/v2010"
 	"golang.org/x/time/rate"
 
 	"github.com/aegis-shield/services/alerting-engine/internal/config"
@@ -30,10 +28,8 @@ type Manager struct {
 	notificationRepo      *database.NotificationRepository
 	emailTemplates        *template.Template
 	smsTemplates         *template.Template
-	slackClient          *SlackClient
-	teamsClient          *TeamsClient
 	webhookClient        *WebhookClient
-	pagerDutyClient      *PagerDutyClient
+	dispatcher           *Dispatcher
 	rateLimiters         map[string]*rate.Limiter
 	rateLimiterMutex     sync.RWMutex
 	retryQueue           chan *database.Notification
@@ -42,16 +38,19 @@ type Manager struct {
 	wg                   sync.WaitGroup
 }
 
-// NewManager creates a new notification manager
+// NewManager creates a new notification manager. SMS, Slack, Teams and
+// PagerDuty notifications are delivered through the dispatcher's providers.
 func NewManager(
 	cfg *config.Config,
 	logger *slog.Logger,
 	notificationRepo *database.NotificationRepository,
+	dispatcher *Dispatcher,
 ) (*Manager, error) {
 	manager := &Manager{
 		config:           cfg,
 		logger:           logger,
 		notificationRepo: notificationRepo,
+		dispatcher:       dispatcher,
 		rateLimiters:     make(map[string]*rate.Limiter),
 		retryQueue:       make(chan *database.Notification, cfg.Notifications.QueueSize),
 		workerCount:      cfg.Notifications.WorkerCount,
@@ -122,16 +121,10 @@ func (m *Manager) SendNotification(ctx context.Context, notification *database.N
 	switch notification.Channel {
 	case "email":
 		err = m.sendEmail(ctx, notification)
-	case "sms":
-		err = m.sendSMS(ctx, notification)
-	case "slack":
-		err = m.sendSlack(ctx, notification)
-	case "teams":
-		err = m.sendTeams(ctx, notification)
 	case "webhook":
 		err = m.sendWebhook(ctx, notification)
-	case "pagerduty":
-		err = m.sendPagerDuty(ctx, notification)
+	case ChannelSMS, ChannelSlack, ChannelTeams, ChannelPagerDuty:
+		err = m.sendViaProvider(ctx, notification)
 	default:
 		err = fmt.Errorf("unsupported notification channel: %s", notification.Channel)
 	}
@@ -316,59 +309,28 @@ func (m *Manager) sendEmailViaSMTP(ctx context.Context, notification *database.N
 	return nil
 }
 
-// SMS sending methods
+// Provider sending methods
 
-func (m *Manager) sendSMS(ctx context.Context, notification *database.Notification) error {
-	if !m.config.Notifications.SMS.Enabled {
-		return fmt.Errorf("SMS notifications are disabled")
+func (m *Manager) sendViaProvider(ctx context.Context, notification *database.Notification) error {
+	message := &Message{
+		NotificationID: notification.ID,
+		AlertID:        notification.AlertID,
+		Recipient:      notification.Recipient,
+		Body:           notification.Content,
+		CreatedAt:      notification.CreatedAt,
 	}
-
-	// Render SMS content
-	content, err := m.renderSMSContent(notification)
-	if err != nil {
-		return fmt.Errorf("failed to render SMS content: %w", err)
-	}
-
-	client := twilio.NewRestClientWithParams(twilio.ClientParams{
-		Username: m.config.Notifications.SMS.Twilio.AccountSID,
-		Password: m.config.Notifications.SMS.Twilio.AuthToken,
-	})
-
-	params := &v2010.CreateMessageParams{}
-	params.SetTo(notification.Recipient)
-	params.SetFrom(m.config.Notifications.SMS.FromNumber)
-	params.SetBody(content)
-
-	resp, err := client.Api.CreateMessage(params)
-	if err != nil {
-		return fmt.Errorf("failed to send SMS via Twilio: %w", err)
-	}
-
-	// Update notification with external ID
-	notification.ExternalID = resp.Sid
-	if err := m.notificationRepo.Update(ctx, notification); err != nil {
-		m.logger.Error("Failed to update notification with external ID", "error", err)
+	if notification.Subject != nil {
+		message.Subject = *notification.Subject
 	}
-
-	return nil
-}
-
-// Slack sending methods
-
-func (m *Manager) sendSlack(ctx context.Context, notification *database.Notification) error {
-	if m.slackClient == nil {
-		return fmt.Errorf("Slack client not initialized")
+	if notification.Channel == ChannelSMS {
+		content, err := m.renderSMSContent(notification)
+		if err != nil {
+			return fmt.Errorf("failed to render SMS content: %w", err)
+		}
+		message.Subject, message.Body = "", content
 	}
-	return m.slackClient.SendMessage(ctx, notification)
-}
-
-// Teams sending methods
 
-func (m *Manager) sendTeams(ctx context.Context, notification *database.Notification) error {
-	if m.teamsClient == nil {
-		return fmt.Errorf("Teams client not initialized")
-	}
-	return m.teamsClient.SendMessage(ctx, notification)
+	return m.dispatcher.Send(ctx, notification.Channel, message, nil)
 }
 
 // Webhook sending methods
@@ -380,15 +342,6 @@ func (m *Manager) sendWebhook(ctx context.Context, notification *database.Notifi
 	return m.webhookClient.SendWebhook(ctx, notification)
 }
 
-// PagerDuty sending methods
-
-func (m *Manager) sendPagerDuty(ctx context.Context, notification *database.Notification) error {
-	if m.pagerDutyClient == nil {
-		return fmt.Errorf("PagerDuty client not initialized")
-	}
-	return m.pagerDutyClient.SendAlert(ctx, notification)
-}
-
 // Template rendering
 
 func (m *Manager) renderEmailContent(notification *database.Notification) (*EmailContent, error) {
@@ -587,26 +540,11 @@ func (m *Manager) initializeSMSTemplates() error {
 }
 
 func (m *Manager) initializeClients() error {
-	// Initialize Slack client
-	if m.config.Notifications.Slack.Enabled {
-		m.slackClient = NewSlackClient(m.config.Notifications.Slack, m.logger)
-	}
-	
-	// Initialize Teams client
-	if m.config.Notifications.Teams.Enabled {
-		m.teamsClient = NewTeamsClient(m.config.Notifications.Teams, m.logger)
-	}
-	
 	// Initialize Webhook client
 	if m.config.Notifications.Webhooks.Enabled {
 		m.webhookClient = NewWebhookClient(m.config.Notifications.Webhooks, m.logger)
 	}
 	
-	// Initialize PagerDuty client
-	if m.config.Notifications.PagerDuty.Enabled {
-		m.pagerDutyClient = NewPagerDutyClient(m.config.Notifications.PagerDuty, m.logger)
-	}
-	
 	return nil
 }
 
@@ -0,0 +1,117 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+)
+
+// pagerDutyRoutingKey matches Events API v2 integration (routing) keys
+var pagerDutyRoutingKey = regexp.MustCompile(`^[A-Za-z0-9]{32}$`)
+
+// pagerDutySummaryLimit is the longest event summary PagerDuty accepts
+const pagerDutySummaryLimit = 1024
+
+// PagerDutyProvider triggers PagerDuty incidents through the Events API v2.
+// A recipient is the routing key of the service to page, overriding the
+// configured integration key. Events are deduplicated by alert, so repeated
+// notifications for one alert update a single incident.
+type PagerDutyProvider struct {
+	eventsURL      string
+	integrationKey string
+	client         *http.Client
+}
+
+// NewPagerDutyProvider creates a provider using the configured integration key
+func NewPagerDutyProvider(cfg config.PagerDutyConfig) *PagerDutyProvider {
+	return &PagerDutyProvider{
+		eventsURL:      cfg.EventsURL,
+		integrationKey: cfg.IntegrationKey,
+		client:         &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Channel returns the channel the provider serves
+func (p *PagerDutyProvider) Channel() string {
+	return ChannelPagerDuty
+}
+
+// ValidateRecipient accepts a 32 character routing key, or none when an
+// integration key is configured
+func (p *PagerDutyProvider) ValidateRecipient(recipient string) error {
+	if recipient == "" {
+		if p.integrationKey == "" {
+			return fmt.Errorf("%w: a routing key is required, no PagerDuty integration key is configured", ErrInvalidRecipient)
+		}
+		return nil
+	}
+	if !pagerDutyRoutingKey.MatchString(recipient) {
+		return fmt.Errorf("%w: PagerDuty routing keys are 32 letters and digits", ErrInvalidRecipient)
+	}
+	return nil
+}
+
+// Send triggers an event and returns its dedup key as the external ID
+func (p *PagerDutyProvider) Send(ctx context.Context, message *Message) (*Receipt, error) {
+	routingKey := message.Recipient
+	if routingKey == "" {
+		routingKey = p.integrationKey
+	}
+
+	details := map[string]interface{}{"message": message.Body}
+	for key, value := range message.Details {
+		details[key] = value
+	}
+	if message.RuleID != "" {
+		details["rule_id"] = message.RuleID
+	}
+	if message.Priority != "" {
+		details["priority"] = message.Priority
+	}
+
+	event := PagerDutyEvent{
+		RoutingKey:  routingKey,
+		EventAction: "trigger",
+		DedupKey:    message.AlertID,
+		Payload: PagerDutyPayload{
+			Summary:       truncate(message.Subject, pagerDutySummaryLimit-3),
+			Source:        "AegisShield Alerting Engine",
+			Severity:      pagerDutySeverity(message.Severity),
+			Timestamp:     message.CreatedAt.UTC().Format(time.RFC3339),
+			Component:     "alerting-engine",
+			Group:         "financial-crimes",
+			Class:         message.RuleName,
+			CustomDetails: details,
+		},
+	}
+
+	status, body, err := postJSON(ctx, p.client, ChannelPagerDuty, p.eventsURL, event, http.StatusAccepted)
+	if err != nil {
+		return nil, err
+	}
+
+	var response PagerDutyResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse PagerDuty response: %w", err)
+	}
+	return &Receipt{StatusCode: status, ExternalID: response.DedupKey, ProviderStatus: response.Status}, nil
+}
+
+// pagerDutySeverity maps alert severities onto the Events API's
+func pagerDutySeverity(severity string) string {
+	switch severity {
+	case "critical":
+		return "critical"
+	case "high":
+		return "error"
+	case "medium":
+		return "warning"
+	default:
+		return "info"
+	}
+}
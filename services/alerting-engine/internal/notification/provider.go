@@ -0,0 +1,173 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+)
+
+// Notification channels served by providers
+const (
+	ChannelSlack     = "slack"
+	ChannelPagerDuty = "pagerduty"
+	ChannelTeams     = "teams"
+	ChannelSMS       = "sms"
+)
+
+// ErrInvalidRecipient is returned for recipients a provider cannot deliver to
+var ErrInvalidRecipient = errors.New("invalid notification recipient")
+
+// Provider delivers notifications over one channel
+type Provider interface {
+	// Channel returns the channel the provider serves
+	Channel() string
+	// ValidateRecipient checks a recipient before it is routed to. An empty
+	// recipient selects the provider's configured default, if it has one.
+	ValidateRecipient(recipient string) error
+	// Send delivers a message. A provider that answers with an error status
+	// returns a *ProviderError.
+	Send(ctx context.Context, message *Message) (*Receipt, error)
+}
+
+// Message is a notification as handed to a provider
+type Message struct {
+	NotificationID string
+	RuleID         string
+	RuleName       string
+	AlertID        string
+	Recipient      string
+	Subject        string
+	Body           string
+	Severity       string
+	Priority       string
+	Details        map[string]interface{}
+	CreatedAt      time.Time
+}
+
+// Receipt is a provider's acknowledgement of a delivered message
+type Receipt struct {
+	StatusCode     int
+	ExternalID     string
+	ProviderStatus string
+}
+
+// ProviderError is returned when a provider answers a send with an error status
+type ProviderError struct {
+	Channel    string
+	StatusCode int
+	Body       string
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s returned status %d: %s", e.Channel, e.StatusCode, truncate(e.Body, 200))
+}
+
+// Registry holds the providers notifications can be routed to, by channel
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry creates an empty provider registry
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]Provider)}
+}
+
+// NewRegistryFromConfig creates a registry holding a provider for every
+// enabled channel
+func NewRegistryFromConfig(cfg config.NotificationsConfig) *Registry {
+	registry := NewRegistry()
+	if cfg.Slack.Enabled {
+		registry.Register(NewSlackProvider(cfg.Slack))
+	}
+	if cfg.PagerDuty.Enabled {
+		registry.Register(NewPagerDutyProvider(cfg.PagerDuty))
+	}
+	if cfg.Teams.Enabled {
+		registry.Register(NewTeamsProvider(cfg.Teams))
+	}
+	if cfg.SMS.Enabled {
+		registry.Register(NewSMSProvider(cfg.SMS))
+	}
+	return registry
+}
+
+// Register adds a provider, replacing any earlier one for its channel
+func (r *Registry) Register(provider Provider) {
+	r.providers[provider.Channel()] = provider
+}
+
+// Provider returns the provider for a channel
+func (r *Registry) Provider(channel string) (Provider, bool) {
+	provider, ok := r.providers[channel]
+	return provider, ok
+}
+
+// Channels returns the channels with a provider, sorted
+func (r *Registry) Channels() []string {
+	channels := make([]string, 0, len(r.providers))
+	for channel := range r.providers {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// postJSON posts a payload and returns the response status and the start of
+// its body, or a *ProviderError when the status is not the one expected
+func postJSON(ctx context.Context, client *http.Client, channel, url string, payload interface{}, expected int) (int, []byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to marshal %s payload: %w", channel, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create %s request: %w", channel, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return doRequest(client, channel, req, expected)
+}
+
+func doRequest(client *http.Client, channel string, req *http.Request, expected int) (int, []byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s request failed: %w", channel, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode != expected {
+		return resp.StatusCode, respBody, &ProviderError{Channel: channel, StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	return resp.StatusCode, respBody, nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+)
+
+// SlackProvider posts notifications to a Slack incoming webhook. A
+// recipient names the channel to post to, overriding the configured default.
+type SlackProvider struct {
+	webhookURL     string
+	defaultChannel string
+	client         *http.Client
+}
+
+// NewSlackProvider creates a provider using the configured Slack webhook
+func NewSlackProvider(cfg config.SlackConfig) *SlackProvider {
+	return &SlackProvider{
+		webhookURL:     cfg.WebhookURL,
+		defaultChannel: cfg.DefaultChannel,
+		client:         &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Channel returns the channel the provider serves
+func (p *SlackProvider) Channel() string {
+	return ChannelSlack
+}
+
+// ValidateRecipient accepts a #channel, an @user or a Slack channel or user ID
+func (p *SlackProvider) ValidateRecipient(recipient string) error {
+	if recipient == "" {
+		return nil
+	}
+	if strings.ContainsAny(recipient, " \t\r\n") {
+		return fmt.Errorf("%w: Slack channel %q contains whitespace", ErrInvalidRecipient, recipient)
+	}
+	if strings.HasPrefix(recipient, "#") || strings.HasPrefix(recipient, "@") {
+		if len(recipient) == 1 {
+			return fmt.Errorf("%w: Slack channel name is empty", ErrInvalidRecipient)
+		}
+		return nil
+	}
+	if strings.ToUpper(recipient) != recipient {
+		return fmt.Errorf("%w: Slack recipient %q is neither a #channel, an @user nor an ID", ErrInvalidRecipient, recipient)
+	}
+	return nil
+}
+
+// Send posts a message. A body that is already a Slack payload, as rendered
+// from a Slack notification template, is posted as is.
+func (p *SlackProvider) Send(ctx context.Context, message *Message) (*Receipt, error) {
+	channel := message.Recipient
+	if channel == "" {
+		channel = p.defaultChannel
+	}
+
+	var payload interface{} = p.buildMessage(channel, message)
+	var rendered map[string]interface{}
+	if strings.HasPrefix(strings.TrimSpace(message.Body), "{") && json.Unmarshal([]byte(message.Body), &rendered) == nil {
+		if channel != "" {
+			rendered["channel"] = channel
+		}
+		payload = rendered
+	}
+
+	status, body, err := postJSON(ctx, p.client, ChannelSlack, p.webhookURL, payload, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return &Receipt{StatusCode: status, ProviderStatus: truncate(string(body), 100)}, nil
+}
+
+func (p *SlackProvider) buildMessage(channel string, message *Message) SlackMessage {
+	fields := []SlackField{
+		{Type: "mrkdwn", Text: fmt.Sprintf("*Priority:*\n%s", message.Priority)},
+		{Type: "mrkdwn", Text: fmt.Sprintf("*Created:*\n%s", message.CreatedAt.UTC().Format("2006-01-02 15:04:05 UTC"))},
+	}
+	if message.Severity != "" {
+		fields = append(fields, SlackField{Type: "mrkdwn", Text: fmt.Sprintf("*Severity:*\n%s", message.Severity)})
+	}
+	if message.AlertID != "" {
+		fields = append(fields, SlackField{Type: "mrkdwn", Text: fmt.Sprintf("*Alert:*\n%s", message.AlertID)})
+	}
+
+	return SlackMessage{
+		Channel: channel,
+		Text:    message.Subject,
+		Blocks: []SlackBlock{
+			{
+				Type: "section",
+				Text: &SlackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%s", message.Subject, message.Body)},
+			},
+			{Type: "divider"},
+			{Type: "section", Fields: fields},
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+)
+
+// e164 matches phone numbers in E.164 format
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// smsBodyLimit is the longest message body Twilio accepts
+const smsBodyLimit = 1600
+
+// SMSProvider sends text messages through the Twilio Messages API. Every
+// route needs a recipient phone number; there is no default one.
+type SMSProvider struct {
+	baseURL    string
+	accountSID string
+	authToken  string
+	fromNumber string
+	client     *http.Client
+}
+
+// NewSMSProvider creates a provider using the configured Twilio account
+func NewSMSProvider(cfg config.SMSConfig) *SMSProvider {
+	return &SMSProvider{
+		baseURL:    strings.TrimSuffix(cfg.TwilioBaseURL, "/"),
+		accountSID: cfg.TwilioSID,
+		authToken:  cfg.TwilioToken,
+		fromNumber: cfg.FromNumber,
+		client:     &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Channel returns the channel the provider serves
+func (p *SMSProvider) Channel() string {
+	return ChannelSMS
+}
+
+// ValidateRecipient accepts a phone number in E.164 format
+func (p *SMSProvider) ValidateRecipient(recipient string) error {
+	if !e164.MatchString(recipient) {
+		return fmt.Errorf("%w: SMS recipients are E.164 phone numbers such as +15551234567", ErrInvalidRecipient)
+	}
+	return nil
+}
+
+// Send sends a text message and returns its Twilio message SID as the external ID
+func (p *SMSProvider) Send(ctx context.Context, message *Message) (*Receipt, error) {
+	if err := p.ValidateRecipient(message.Recipient); err != nil {
+		return nil, err
+	}
+
+	text := message.Body
+	if message.Subject != "" {
+		text = message.Subject + "\n" + message.Body
+	}
+	if len(text) > smsBodyLimit {
+		text = text[:smsBodyLimit-3] + "..."
+	}
+
+	form := url.Values{}
+	form.Set("To", message.Recipient)
+	form.Set("From", p.fromNumber)
+	form.Set("Body", text)
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", p.baseURL, url.PathEscape(p.accountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sms request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(p.accountSID, p.authToken)
+
+	status, body, err := doRequest(p.client, ChannelSMS, req, http.StatusCreated)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		SID    string `json:"sid"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse Twilio response: %w", err)
+	}
+	return &Receipt{StatusCode: status, ExternalID: response.SID, ProviderStatus: response.Status}, nil
+}
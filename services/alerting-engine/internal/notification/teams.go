@@ -0,0 +1,101 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+)
+
+// TeamsProvider posts notifications as message cards to a Microsoft Teams
+// incoming webhook. A recipient is the webhook URL of the team channel to
+// post to, overriding the configured one.
+type TeamsProvider struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewTeamsProvider creates a provider using the configured Teams webhook
+func NewTeamsProvider(cfg config.TeamsConfig) *TeamsProvider {
+	return &TeamsProvider{
+		webhookURL: cfg.WebhookURL,
+		client:     &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Channel returns the channel the provider serves
+func (p *TeamsProvider) Channel() string {
+	return ChannelTeams
+}
+
+// ValidateRecipient accepts an https webhook URL, or none when a webhook is configured
+func (p *TeamsProvider) ValidateRecipient(recipient string) error {
+	if recipient == "" {
+		if p.webhookURL == "" {
+			return fmt.Errorf("%w: a webhook URL is required, no Teams webhook is configured", ErrInvalidRecipient)
+		}
+		return nil
+	}
+	parsed, err := url.Parse(recipient)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("%w: Teams recipients are https webhook URLs", ErrInvalidRecipient)
+	}
+	return nil
+}
+
+// Send posts a message card
+func (p *TeamsProvider) Send(ctx context.Context, message *Message) (*Receipt, error) {
+	webhookURL := message.Recipient
+	if webhookURL == "" {
+		webhookURL = p.webhookURL
+	}
+
+	facts := []TeamsFact{
+		{Name: "Rule", Value: message.RuleName},
+		{Name: "Priority", Value: message.Priority},
+		{Name: "Created", Value: message.CreatedAt.UTC().Format("2006-01-02 15:04:05 UTC")},
+	}
+	if message.Severity != "" {
+		facts = append(facts, TeamsFact{Name: "Severity", Value: message.Severity})
+	}
+	if message.AlertID != "" {
+		facts = append(facts, TeamsFact{Name: "Alert ID", Value: message.AlertID})
+	}
+
+	card := TeamsMessage{
+		Type:       "MessageCard",
+		Context:    "http://schema.org/extensions",
+		ThemeColor: teamsThemeColor(message.Severity),
+		Summary:    message.Subject,
+		Sections: []TeamsSection{
+			{
+				ActivityTitle: message.Subject,
+				Text:          message.Body,
+				Facts:         facts,
+			},
+		},
+	}
+
+	status, body, err := postJSON(ctx, p.client, ChannelTeams, webhookURL, card, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return &Receipt{StatusCode: status, ProviderStatus: truncate(string(body), 100)}, nil
+}
+
+func teamsThemeColor(severity string) string {
+	switch severity {
+	case "critical":
+		return "FF0000"
+	case "high":
+		return "FF9900"
+	case "medium":
+		return "FFCC00"
+	case "low":
+		return "00CC00"
+	default:
+		return "0078D4"
+	}
+}
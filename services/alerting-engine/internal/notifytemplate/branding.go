@@ -0,0 +1,235 @@
+package notifytemplate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// ErrInvalidBranding is returned for branding values that are missing,
+// malformed or too long
+var ErrInvalidBranding = errors.New("invalid notification branding")
+
+const (
+	maxBrandNameLength = 100
+	maxLogoURLLength   = 2048
+	maxFooterLength    = 1000
+)
+
+var brandColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// BrandingInput sets a tenant's branding. Empty optional values are cleared.
+type BrandingInput struct {
+	DisplayName  string `json:"display_name"`
+	LogoURL      string `json:"logo_url,omitempty"`
+	PrimaryColor string `json:"primary_color,omitempty"`
+	SupportEmail string `json:"support_email,omitempty"`
+	Footer       string `json:"footer,omitempty"`
+}
+
+// cachedBrand is the brand a tenant's notifications were last rendered with
+type cachedBrand struct {
+	brand    map[string]interface{}
+	loadedAt time.Time
+}
+
+// TenantOf returns the tenant a rule's notification is sent for: the rule's
+// tenant_id metadata, else the tenant_id of the triggering event, else ""
+// for notifications carrying the default brand
+func TenantOf(rule *database.Rule, event map[string]interface{}) string {
+	if rule != nil {
+		if tenantID, ok := rule.Metadata["tenant_id"].(string); ok && tenantID != "" {
+			return tenantID
+		}
+	}
+	if tenantID, ok := event["tenant_id"].(string); ok {
+		return tenantID
+	}
+	return ""
+}
+
+// Brand builds the .brand data templates render against. Every field is
+// present, empty when unset, so templates can test them with "with". A nil
+// branding gives the default brand name.
+func Brand(tenantID string, branding *database.NotificationBranding, defaultName string) map[string]interface{} {
+	brand := map[string]interface{}{
+		"tenant_id":     tenantID,
+		"name":          defaultName,
+		"logo_url":      "",
+		"color":         "",
+		"support_email": "",
+		"footer":        "",
+	}
+	if branding == nil {
+		return brand
+	}
+
+	brand["name"] = branding.DisplayName
+	for key, value := range map[string]*string{
+		"logo_url":      branding.LogoURL,
+		"color":         branding.PrimaryColor,
+		"support_email": branding.SupportEmail,
+		"footer":        branding.Footer,
+	} {
+		if value != nil {
+			brand[key] = *value
+		}
+	}
+	return brand
+}
+
+// SetBranding validates and saves a tenant's branding
+func (s *Service) SetBranding(ctx context.Context, tenantID string, input BrandingInput, actor string) (*database.NotificationBranding, error) {
+	tenantID = strings.TrimSpace(tenantID)
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenant is required", ErrInvalidBranding)
+	}
+
+	branding, err := newBranding(tenantID, input)
+	if err != nil {
+		return nil, err
+	}
+	branding.CreatedBy = actor
+	branding.UpdatedBy = actor
+
+	if err := s.brandingRepo.Upsert(ctx, branding); err != nil {
+		return nil, err
+	}
+	s.invalidateBrand(tenantID)
+
+	return branding, nil
+}
+
+// GetBranding returns a tenant's branding
+func (s *Service) GetBranding(ctx context.Context, tenantID string) (*database.NotificationBranding, error) {
+	return s.brandingRepo.GetByTenant(ctx, tenantID)
+}
+
+// ListBrandings returns every tenant's branding
+func (s *Service) ListBrandings(ctx context.Context) ([]*database.NotificationBranding, error) {
+	return s.brandingRepo.List(ctx)
+}
+
+// DeleteBranding removes a tenant's branding; its notifications go back to
+// the default brand
+func (s *Service) DeleteBranding(ctx context.Context, tenantID string) error {
+	if err := s.brandingRepo.Delete(ctx, tenantID); err != nil {
+		return err
+	}
+	s.invalidateBrand(tenantID)
+	return nil
+}
+
+// brand returns the .brand data of a tenant's notifications. A tenant without
+// branding, or whose branding cannot be loaded, gets the default brand, so
+// branding never stops a notification being sent.
+func (s *Service) brand(ctx context.Context, tenantID string) map[string]interface{} {
+	defaultName := s.config.NotificationTemplates.DefaultBrandName
+	if tenantID == "" || s.brandingRepo == nil {
+		return Brand(tenantID, nil, defaultName)
+	}
+
+	s.mu.Lock()
+	cached, ok := s.brands[tenantID]
+	s.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) <= s.config.NotificationTemplates.CacheTTL {
+		return cached.brand
+	}
+
+	branding, err := s.brandingRepo.GetByTenant(ctx, tenantID)
+	if err != nil && !errors.Is(err, database.ErrNotificationBrandingNotFound) {
+		s.logger.Warn("Failed to load notification branding, using the default brand",
+			"tenant_id", tenantID,
+			"error", err)
+		return Brand(tenantID, nil, defaultName)
+	}
+
+	cached = cachedBrand{brand: Brand(tenantID, branding, defaultName), loadedAt: time.Now()}
+	s.mu.Lock()
+	s.brands[tenantID] = cached
+	s.mu.Unlock()
+
+	return cached.brand
+}
+
+func (s *Service) invalidateBrand(tenantID string) {
+	s.mu.Lock()
+	delete(s.brands, tenantID)
+	s.mu.Unlock()
+}
+
+// newBranding checks branding values, which reach every notification of the
+// tenant: single-line text, an https logo, a #rrggbb colour and a bare email
+// address. Only the footer may span lines.
+func newBranding(tenantID string, input BrandingInput) (*database.NotificationBranding, error) {
+	name := strings.TrimSpace(input.DisplayName)
+	if name == "" {
+		return nil, fmt.Errorf("%w: display_name is required", ErrInvalidBranding)
+	}
+	if utf8.RuneCountInString(name) > maxBrandNameLength {
+		return nil, fmt.Errorf("%w: display_name may not exceed %d characters", ErrInvalidBranding, maxBrandNameLength)
+	}
+	if hasControl(name, false) {
+		return nil, fmt.Errorf("%w: display_name must be a single line of text", ErrInvalidBranding)
+	}
+
+	branding := &database.NotificationBranding{TenantID: tenantID, DisplayName: name}
+
+	if logo := strings.TrimSpace(input.LogoURL); logo != "" {
+		parsed, err := url.Parse(logo)
+		if err != nil || parsed.Scheme != "https" || parsed.Host == "" || len(logo) > maxLogoURLLength {
+			return nil, fmt.Errorf("%w: logo_url must be an https URL of at most %d bytes", ErrInvalidBranding, maxLogoURLLength)
+		}
+		branding.LogoURL = &logo
+	}
+
+	if color := strings.TrimSpace(input.PrimaryColor); color != "" {
+		if !brandColorPattern.MatchString(color) {
+			return nil, fmt.Errorf("%w: primary_color must be a #rrggbb colour", ErrInvalidBranding)
+		}
+		branding.PrimaryColor = &color
+	}
+
+	if email := strings.TrimSpace(input.SupportEmail); email != "" {
+		address, err := mail.ParseAddress(email)
+		if err != nil || address.Name != "" || address.Address != email {
+			return nil, fmt.Errorf("%w: support_email must be an email address", ErrInvalidBranding)
+		}
+		branding.SupportEmail = &email
+	}
+
+	if footer := strings.TrimSpace(strings.ReplaceAll(input.Footer, "\r\n", "\n")); footer != "" {
+		if utf8.RuneCountInString(footer) > maxFooterLength {
+			return nil, fmt.Errorf("%w: footer may not exceed %d characters", ErrInvalidBranding, maxFooterLength)
+		}
+		if hasControl(footer, true) {
+			return nil, fmt.Errorf("%w: footer may not contain control characters", ErrInvalidBranding)
+		}
+		branding.Footer = &footer
+	}
+
+	return branding, nil
+}
+
+// hasControl reports whether s contains control characters, other than line
+// breaks when they are allowed
+func hasControl(s string, allowNewlines bool) bool {
+	for _, r := range s {
+		if allowNewlines && r == '\n' {
+			continue
+		}
+		if unicode.IsControl(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package notifytemplate
+
+import (
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// builtin holds the subject and body rendered for a channel that has no
+// enabled template of its own
+var builtin = map[string]struct{ subject, body string }{
+	ChannelEmail: {
+		subject: `[{{ upper (default "info" .alert.severity) }}] {{ default .rule.name .alert.title }}`,
+		body: `Rule {{ .rule.name }} ({{ .rule.id }}) has been triggered.
+{{ with .alert.id }}
+Alert:    {{ $.alert.title }}
+Severity: {{ $.alert.severity }}
+Priority: {{ $.alert.priority }}
+Status:   {{ $.alert.status }}
+{{- with $.alert.entity_ids }}
+Entities: {{ join ", " . }}
+{{- end }}
+{{ with $.alert.description }}
+{{ . }}
+{{ end }}{{ end }}
+Triggered at {{ date "2006-01-02 15:04:05 MST" .timestamp }}
+{{ with .brand.name }}
+--
+{{ . }}{{ with $.brand.support_email }} <{{ . }}>{{ end }}
+{{ end }}{{ with .brand.footer }}{{ . }}
+{{ end }}`,
+	},
+	ChannelSlack: {
+		body: `{"text": {{ json (printf "*[%s] %s*\nRule %s has been triggered." (upper (default "info" .alert.severity)) (default .rule.name .alert.title) .rule.name) }}
+{{- with .brand.name }}, "username": {{ json . }}{{ end }}
+{{- with .brand.logo_url }}, "icon_url": {{ json . }}{{ end }}}`,
+	},
+	ChannelWebhook: {
+		body: `{
+  "rule": {{ json .rule }},
+  "alert": {{ json .alert }},
+  "event": {{ json .event }},
+  "timestamp": {{ json .timestamp }}
+}`,
+	},
+}
+
+// Default returns the built-in subject and body for a channel
+func Default(channel string) (string, string, bool) {
+	template, ok := builtin[channel]
+	return template.subject, template.body, ok
+}
+
+// NewData builds the data notification templates render against: the rule,
+// the alert it raised and the event that triggered it, and when. A missing
+// alert or event is empty rather than absent so templates can still read
+// their fields. The brand is unset until the tenant's branding is applied.
+func NewData(rule *database.Rule, alert *database.Alert, event map[string]interface{}, timestamp time.Time) map[string]interface{} {
+	data := map[string]interface{}{
+		"rule":      map[string]interface{}{},
+		"alert":     map[string]interface{}{},
+		"event":     map[string]interface{}{},
+		"brand":     Brand("", nil, ""),
+		"timestamp": timestamp.UTC().Format(time.RFC3339),
+	}
+	if rule != nil {
+		data["rule"] = map[string]interface{}{
+			"id":          rule.ID,
+			"name":        rule.Name,
+			"description": rule.Description,
+			"type":        rule.Type,
+			"severity":    rule.Severity,
+			"priority":    rule.Priority,
+			"tags":        rule.Tags,
+		}
+	}
+	if alert != nil {
+		data["alert"] = alert
+	}
+	if event != nil {
+		data["event"] = event
+	}
+	return data
+}
+
+// SampleRule returns the rule previews render against when no real alert
+// is given
+func SampleRule() *database.Rule {
+	return &database.Rule{
+		ID:          "rule_sample",
+		Name:        "Large cash deposit",
+		Description: "Cash deposits above the reporting threshold",
+		Type:        "threshold",
+		Severity:    "high",
+		Priority:    "high",
+		Tags:        []string{"aml", "cash"},
+	}
+}
+
+// SampleAlert returns an alert raised by SampleRule
+func SampleAlert(rule *database.Rule, event map[string]interface{}) *database.Alert {
+	now := time.Now()
+	alert := &database.Alert{
+		ID:          "alert_sample",
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		Type:        rule.Type,
+		Severity:    rule.Severity,
+		Priority:    rule.Priority,
+		Status:      database.AlertStatusActive,
+		Title:       rule.Name + " triggered",
+		Description: "Sample alert for previewing notification templates",
+		Source:      "rule_engine",
+		SourceEvent: event,
+		EntityIDs:   []string{"entity_sample_1", "entity_sample_2"},
+		Tags:        rule.Tags,
+		Metadata:    map[string]interface{}{},
+	}
+	alert.CreatedAt = now
+	alert.UpdatedAt = now
+	return alert
+}
+
+// SampleBrand returns the brand templates are rendered with when they are
+// checked, with every field set
+func SampleBrand() map[string]interface{} {
+	logo := "https://example.com/logo.png"
+	color := "#1F6FEB"
+	email := "support@example.com"
+	footer := "You receive this notification as a member of Example Bank's compliance team."
+	return Brand("tenant_sample", &database.NotificationBranding{
+		DisplayName:  "Example Bank",
+		LogoURL:      &logo,
+		PrimaryColor: &color,
+		SupportEmail: &email,
+		Footer:       &footer,
+	}, "")
+}
+
+// SampleEvent returns the event SampleAlert is raised for
+func SampleEvent() map[string]interface{} {
+	return map[string]interface{}{
+		"type":           "transaction",
+		"transaction_id": "txn_sample",
+		"amount":         15000,
+		"currency":       "USD",
+		"channel":        "branch",
+		"entity_ids":     []string{"entity_sample_1", "entity_sample_2"},
+	}
+}
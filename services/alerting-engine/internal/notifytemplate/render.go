@@ -0,0 +1,333 @@
+package notifytemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+	"unicode/utf8"
+)
+
+// Channels notification templates can be written for
+const (
+	ChannelEmail   = "email"
+	ChannelSlack   = "slack"
+	ChannelWebhook = "webhook"
+)
+
+var (
+	// ErrInvalidTemplate is returned for templates that fail to parse or use
+	// constructs outside the sandbox
+	ErrInvalidTemplate = errors.New("invalid notification template")
+	// ErrRenderFailed is returned when a template cannot be rendered against
+	// the data given, or renders a payload its channel cannot send
+	ErrRenderFailed = errors.New("notification template failed to render")
+
+	errOutputTooLarge = errors.New("rendered output exceeds the size limit")
+)
+
+// IsChannel reports whether templates can be written for a channel
+func IsChannel(channel string) bool {
+	switch channel {
+	case ChannelEmail, ChannelSlack, ChannelWebhook:
+		return true
+	}
+	return false
+}
+
+// postsJSON reports whether a channel posts its rendered body as a JSON payload
+func postsJSON(channel string) bool {
+	return channel == ChannelSlack || channel == ChannelWebhook
+}
+
+// Rendered is a rendered notification
+type Rendered struct {
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body"`
+}
+
+// Renderer renders notification templates in a sandbox. Templates see only
+// plain data decoded from JSON, so no method of a Go value can be called;
+// they may use the functions in funcs and the text/template builtins, but may
+// not define or call other templates, and may only range over data. Output
+// is capped at a configured size.
+type Renderer struct {
+	maxTemplateSize int
+	maxOutputSize   int
+}
+
+// NewRenderer creates a renderer with the given template and output size limits in bytes
+func NewRenderer(maxTemplateSize, maxOutputSize int) *Renderer {
+	return &Renderer{
+		maxTemplateSize: maxTemplateSize,
+		maxOutputSize:   maxOutputSize,
+	}
+}
+
+// Validate checks that a subject and body parse and stay inside the sandbox.
+// Email templates need a subject; other channels ignore it.
+func (r *Renderer) Validate(channel, subject, body string) error {
+	if !IsChannel(channel) {
+		return fmt.Errorf("%w: unsupported channel %q", ErrInvalidTemplate, channel)
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("%w: body is required", ErrInvalidTemplate)
+	}
+	if channel == ChannelEmail && strings.TrimSpace(subject) == "" {
+		return fmt.Errorf("%w: email templates need a subject", ErrInvalidTemplate)
+	}
+	if r.maxTemplateSize > 0 && len(subject)+len(body) > r.maxTemplateSize {
+		return fmt.Errorf("%w: subject and body may not exceed %d bytes", ErrInvalidTemplate, r.maxTemplateSize)
+	}
+
+	if _, err := parseSandboxed("subject", subject); err != nil {
+		return err
+	}
+	_, err := parseSandboxed("body", body)
+	return err
+}
+
+// Render validates and renders a subject and body against notification data
+func (r *Renderer) Render(channel, subject, body string, data map[string]interface{}) (*Rendered, error) {
+	if err := r.Validate(channel, subject, body); err != nil {
+		return nil, err
+	}
+
+	plain, err := plainData(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRenderFailed, err)
+	}
+
+	rendered := &Rendered{}
+	if channel == ChannelEmail {
+		renderedSubject, err := r.execute("subject", subject, plain)
+		if err != nil {
+			return nil, err
+		}
+		// a subject is a single header line
+		rendered.Subject = strings.Join(strings.Fields(renderedSubject), " ")
+	}
+
+	rendered.Body, err = r.execute("body", body, plain)
+	if err != nil {
+		return nil, err
+	}
+	if postsJSON(channel) && !json.Valid([]byte(rendered.Body)) {
+		return nil, fmt.Errorf("%w: %s body is not valid JSON", ErrRenderFailed, channel)
+	}
+
+	return rendered, nil
+}
+
+func (r *Renderer) execute(name, text string, data interface{}) (string, error) {
+	tmpl, err := parseSandboxed(name, text)
+	if err != nil {
+		return "", err
+	}
+
+	out := &limitedBuffer{limit: r.maxOutputSize}
+	if err := tmpl.Execute(out, data); err != nil {
+		if errors.Is(err, errOutputTooLarge) {
+			return "", fmt.Errorf("%w: %s exceeds %d bytes", ErrRenderFailed, name, r.maxOutputSize)
+		}
+		return "", fmt.Errorf("%w: %v", ErrRenderFailed, err)
+	}
+	return out.String(), nil
+}
+
+// parseSandboxed parses a template and rejects constructs outside the sandbox
+func parseSandboxed(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	if len(tmpl.Templates()) > 1 {
+		return nil, fmt.Errorf("%w: %s may not define templates", ErrInvalidTemplate, name)
+	}
+	if tmpl.Tree == nil {
+		return tmpl, nil
+	}
+	if err := checkNode(tmpl.Tree.Root); err != nil {
+		return nil, fmt.Errorf("%w: %s %v", ErrInvalidTemplate, name, err)
+	}
+	return tmpl, nil
+}
+
+// checkNode walks a parse tree rejecting template calls, which could recurse,
+// and ranges over anything but data, which could loop without bound
+func checkNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.TemplateNode:
+		return fmt.Errorf("may not call template %q", n.Name)
+	case *parse.IfNode:
+		return checkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return checkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		if !rangesOverData(n.Pipe) {
+			return fmt.Errorf("may only range over data fields")
+		}
+		return checkBranch(&n.BranchNode)
+	}
+	return nil
+}
+
+func checkBranch(branch *parse.BranchNode) error {
+	if err := checkNode(branch.List); err != nil {
+		return err
+	}
+	if branch.ElseList != nil {
+		return checkNode(branch.ElseList)
+	}
+	return nil
+}
+
+// rangesOverData reports whether a range pipeline is a single field of the
+// data, never a number or function result
+func rangesOverData(pipe *parse.PipeNode) bool {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode, *parse.DotNode:
+		return true
+	case *parse.VariableNode:
+		// $ is the data itself; other variables only through their fields
+		return arg.Ident[0] == "$" || len(arg.Ident) > 1
+	}
+	return false
+}
+
+// plainData round-trips data through JSON so templates see only maps,
+// slices, strings, float64s, bools and nil, keyed by their JSON names
+func plainData(data map[string]interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode template data: %w", err)
+	}
+	var plain map[string]interface{}
+	if err := json.Unmarshal(encoded, &plain); err != nil {
+		return nil, fmt.Errorf("failed to decode template data: %w", err)
+	}
+	return plain, nil
+}
+
+// limitedBuffer fails writes past its limit; a zero limit is unlimited
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && b.Len()+len(p) > b.limit {
+		return 0, errOutputTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+// funcs are the functions templates may call besides the text/template builtins
+var funcs = template.FuncMap{
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"trim":     strings.TrimSpace,
+	"replace":  func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains": func(substr, s string) bool { return strings.Contains(s, substr) },
+	"truncate": truncate,
+	"default":  defaultValue,
+	"join":     join,
+	"json":     toJSON,
+	"date":     formatDate,
+}
+
+// truncate shortens s to at most n runes, ending it with an ellipsis when cut
+func truncate(n int, s string) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	if n == 1 {
+		return "…"
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// defaultValue returns value unless it is missing, empty or zero
+func defaultValue(fallback, value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return fallback
+	case string:
+		if v == "" {
+			return fallback
+		}
+	case float64:
+		if v == 0 {
+			return fallback
+		}
+	case bool:
+		if !v {
+			return fallback
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return fallback
+		}
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return fallback
+		}
+	}
+	return value
+}
+
+// join joins the items of a list with a separator
+func join(sep string, list interface{}) string {
+	items, ok := list.([]interface{})
+	if !ok {
+		if list == nil {
+			return ""
+		}
+		return fmt.Sprint(list)
+	}
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = fmt.Sprint(item)
+	}
+	return strings.Join(parts, sep)
+}
+
+// toJSON encodes a value as JSON, for building Slack and webhook payloads
+func toJSON(value interface{}) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// formatDate formats an RFC 3339 timestamp with a Go layout, returning
+// anything that is not a timestamp unchanged
+func formatDate(layout string, value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return value
+	}
+	return t.UTC().Format(layout)
+}
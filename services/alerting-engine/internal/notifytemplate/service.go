@@ -0,0 +1,448 @@
+package notifytemplate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+var (
+	// ErrRuleNotFound is returned when a template overrides a rule that does not exist
+	ErrRuleNotFound = errors.New("rule not found")
+	// ErrAlertNotFound is returned when previewing against an alert that does not exist
+	ErrAlertNotFound = errors.New("alert not found")
+)
+
+var notificationRenders = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "alerting_engine_notification_template_renders_total",
+	Help: "Rule notifications rendered from templates, by channel and source (template, builtin or error)",
+}, []string{"channel", "source"})
+
+// CreateInput creates a template. Without a rule ID it becomes the channel's
+// default; with one it overrides the default for that rule.
+type CreateInput struct {
+	Name    string `json:"name"`
+	Channel string `json:"channel"`
+	RuleID  string `json:"rule_id,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body"`
+	Enabled *bool  `json:"enabled,omitempty"`
+}
+
+// UpdateInput saves a new version of a template. BaseVersion, when given, is
+// the version the change was made against; saving fails if the template has
+// moved on since.
+type UpdateInput struct {
+	Subject     string `json:"subject,omitempty"`
+	Body        string `json:"body"`
+	Enabled     *bool  `json:"enabled,omitempty"`
+	ChangeNote  string `json:"change_note,omitempty"`
+	BaseVersion int    `json:"base_version,omitempty"`
+}
+
+// RollbackInput restores an earlier version of a template as a new version
+type RollbackInput struct {
+	Version int    `json:"version"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// PreviewInput selects what a preview renders and against what. Previewing a
+// stored template renders its current version unless Version is given;
+// previewing a draft renders Channel, Subject and Body. The data is that of
+// AlertID when given, otherwise a sample alert raised for Event, or for a
+// sample event when Event is empty. TenantID previews with a tenant's
+// branding instead of that of the alert's tenant.
+type PreviewInput struct {
+	Channel  string                 `json:"channel,omitempty"`
+	Subject  string                 `json:"subject,omitempty"`
+	Body     string                 `json:"body,omitempty"`
+	Version  int                    `json:"version,omitempty"`
+	AlertID  string                 `json:"alert_id,omitempty"`
+	Event    map[string]interface{} `json:"event,omitempty"`
+	TenantID string                 `json:"tenant_id,omitempty"`
+}
+
+// Preview is a rendered template and the data it was rendered against
+type Preview struct {
+	Rendered
+	Channel    string                 `json:"channel"`
+	TemplateID string                 `json:"template_id,omitempty"`
+	Version    int                    `json:"version,omitempty"`
+	Sample     bool                   `json:"sample"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// cachedTemplate is the template a rule's notifications on a channel were
+// last resolved to; nil means the channel has none and the built-in is used
+type cachedTemplate struct {
+	template *database.NotificationTemplate
+	loadedAt time.Time
+}
+
+// Service manages notification templates and tenant branding, and renders
+// rule notifications from them
+type Service struct {
+	config       *config.Config
+	logger       *slog.Logger
+	repo         *database.NotificationTemplateRepository
+	brandingRepo *database.NotificationBrandingRepository
+	ruleRepo     *database.RuleRepository
+	alertRepo    *database.AlertRepository
+	renderer     *Renderer
+
+	mu     sync.Mutex
+	cache  map[string]cachedTemplate
+	brands map[string]cachedBrand
+}
+
+// NewService creates a new notification template service
+func NewService(
+	cfg *config.Config,
+	logger *slog.Logger,
+	repo *database.NotificationTemplateRepository,
+	brandingRepo *database.NotificationBrandingRepository,
+	ruleRepo *database.RuleRepository,
+	alertRepo *database.AlertRepository,
+) *Service {
+	return &Service{
+		config:       cfg,
+		logger:       logger,
+		repo:         repo,
+		brandingRepo: brandingRepo,
+		ruleRepo:     ruleRepo,
+		alertRepo:    alertRepo,
+		renderer:     NewRenderer(cfg.NotificationTemplates.MaxTemplateSize, cfg.NotificationTemplates.MaxOutputSize),
+		cache:        make(map[string]cachedTemplate),
+		brands:       make(map[string]cachedBrand),
+	}
+}
+
+// Create validates and saves a new template as its first version
+func (s *Service) Create(ctx context.Context, input CreateInput, actor string) (*database.NotificationTemplate, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidTemplate)
+	}
+	if err := s.validate(input.Channel, input.Subject, input.Body); err != nil {
+		return nil, err
+	}
+
+	template := &database.NotificationTemplate{
+		ID:        generateID("ntpl"),
+		Name:      name,
+		Channel:   input.Channel,
+		Enabled:   input.Enabled == nil || *input.Enabled,
+		CreatedBy: actor,
+		UpdatedBy: actor,
+	}
+	if ruleID := strings.TrimSpace(input.RuleID); ruleID != "" {
+		if err := s.checkRule(ctx, ruleID); err != nil {
+			return nil, err
+		}
+		template.RuleID = &ruleID
+	}
+
+	version := &database.NotificationTemplateVersion{
+		Subject:    input.Subject,
+		Body:       input.Body,
+		ChangeType: database.TemplateChangeCreated,
+		CreatedBy:  actor,
+	}
+	if err := s.repo.Create(ctx, template, version); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	return template, nil
+}
+
+// Update validates and saves a new version of a template
+func (s *Service) Update(ctx context.Context, id string, input UpdateInput, actor string) (*database.NotificationTemplate, error) {
+	template, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.BaseVersion > 0 && input.BaseVersion != template.CurrentVersion {
+		return nil, database.ErrNotificationTemplateStale
+	}
+	if err := s.validate(template.Channel, input.Subject, input.Body); err != nil {
+		return nil, err
+	}
+
+	version := &database.NotificationTemplateVersion{
+		TemplateID: id,
+		Version:    template.CurrentVersion + 1,
+		Subject:    input.Subject,
+		Body:       input.Body,
+		ChangeType: database.TemplateChangeUpdated,
+		CreatedBy:  actor,
+	}
+	if note := strings.TrimSpace(input.ChangeNote); note != "" {
+		version.ChangeNote = &note
+	}
+	if err := s.repo.AddVersion(ctx, version, input.Enabled); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// Rollback restores an earlier version of a template by saving a copy of it
+// as a new version, so the history keeps every version that was ever live
+func (s *Service) Rollback(ctx context.Context, id string, input RollbackInput, actor string) (*database.NotificationTemplate, error) {
+	template, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Version == template.CurrentVersion {
+		return nil, fmt.Errorf("%w: version %d is already current", ErrInvalidTemplate, input.Version)
+	}
+
+	target, err := s.repo.GetVersion(ctx, id, input.Version)
+	if err != nil {
+		return nil, err
+	}
+	// limits may have tightened since the version was saved
+	if err := s.validate(template.Channel, target.Subject, target.Body); err != nil {
+		return nil, err
+	}
+
+	version := &database.NotificationTemplateVersion{
+		TemplateID:    id,
+		Version:       template.CurrentVersion + 1,
+		Subject:       target.Subject,
+		Body:          target.Body,
+		ChangeType:    database.TemplateChangeRolledBack,
+		SourceVersion: &target.Version,
+		CreatedBy:     actor,
+	}
+	if reason := strings.TrimSpace(input.Reason); reason != "" {
+		version.ChangeNote = &reason
+	}
+	if err := s.repo.AddVersion(ctx, version, nil); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// Get returns a template with its current subject and body
+func (s *Service) Get(ctx context.Context, id string) (*database.NotificationTemplate, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns templates, optionally for one channel and rule
+func (s *Service) List(ctx context.Context, channel, ruleID string) ([]*database.NotificationTemplate, error) {
+	return s.repo.List(ctx, channel, ruleID)
+}
+
+// History returns every version of a template, newest first
+func (s *Service) History(ctx context.Context, id string) ([]*database.NotificationTemplateVersion, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListVersions(ctx, id)
+}
+
+// GetVersion returns one version of a template
+func (s *Service) GetVersion(ctx context.Context, id string, version int) (*database.NotificationTemplateVersion, error) {
+	return s.repo.GetVersion(ctx, id, version)
+}
+
+// Preview renders a stored template, or a draft when id is empty, without
+// sending anything
+func (s *Service) Preview(ctx context.Context, id string, input PreviewInput) (*Preview, error) {
+	preview := &Preview{Channel: input.Channel}
+	subject, body := input.Subject, input.Body
+	var rule *database.Rule
+
+	if id != "" {
+		template, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		preview.Channel = template.Channel
+		preview.TemplateID = template.ID
+		preview.Version = template.CurrentVersion
+		subject, body = template.Subject, template.Body
+
+		if input.Version > 0 && input.Version != template.CurrentVersion {
+			version, err := s.repo.GetVersion(ctx, id, input.Version)
+			if err != nil {
+				return nil, err
+			}
+			preview.Version = version.Version
+			subject, body = version.Subject, version.Body
+		}
+		if template.RuleID != nil {
+			// an override previews against its own rule where it still exists
+			rule, _ = s.ruleRepo.GetByID(ctx, *template.RuleID)
+		}
+	}
+
+	data, sample, err := s.previewData(ctx, rule, input)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := s.renderer.Render(preview.Channel, subject, body, data)
+	if err != nil {
+		return nil, err
+	}
+
+	preview.Rendered = *rendered
+	preview.Sample = sample
+	preview.Data = data
+	return preview, nil
+}
+
+// RenderNotification renders a rule notification for a channel from the
+// rule's override, the channel default, or the built-in template, in that
+// order, with the branding of the notification's tenant. Resolved templates
+// and branding are cached for the configured TTL so notifying rarely reads
+// them from the database.
+func (s *Service) RenderNotification(ctx context.Context, channel string, rule *database.Rule, alert *database.Alert, event map[string]interface{}) (string, string, error) {
+	template, err := s.resolve(ctx, channel, rule.ID)
+	if err != nil {
+		notificationRenders.WithLabelValues(channel, "error").Inc()
+		return "", "", err
+	}
+
+	source := "builtin"
+	subject, body, ok := Default(channel)
+	if template != nil {
+		source = "template"
+		subject, body = template.Subject, template.Body
+	} else if !ok {
+		notificationRenders.WithLabelValues(channel, "error").Inc()
+		return "", "", fmt.Errorf("%w: unsupported channel %q", ErrInvalidTemplate, channel)
+	}
+
+	data := NewData(rule, alert, event, time.Now())
+	data["brand"] = s.brand(ctx, TenantOf(rule, event))
+	rendered, err := s.renderer.Render(channel, subject, body, data)
+	if err != nil {
+		notificationRenders.WithLabelValues(channel, "error").Inc()
+		return "", "", err
+	}
+
+	notificationRenders.WithLabelValues(channel, source).Inc()
+	return rendered.Subject, rendered.Body, nil
+}
+
+// validate checks a template's subject and body, then renders them against
+// sample data so templates that cannot render, or render invalid JSON, are
+// rejected when saved rather than when a rule fires
+func (s *Service) validate(channel, subject, body string) error {
+	if err := s.renderer.Validate(channel, subject, body); err != nil {
+		return err
+	}
+
+	rule := SampleRule()
+	event := SampleEvent()
+	data := NewData(rule, SampleAlert(rule, event), event, time.Now())
+	data["brand"] = SampleBrand()
+	if _, err := s.renderer.Render(channel, subject, body, data); err != nil {
+		return fmt.Errorf("%w: sample render failed: %v", ErrInvalidTemplate, err)
+	}
+	return nil
+}
+
+// previewData returns the data a preview renders against and whether it is a sample
+func (s *Service) previewData(ctx context.Context, rule *database.Rule, input PreviewInput) (map[string]interface{}, bool, error) {
+	data, sample, tenantID, err := s.previewAlertData(ctx, rule, input)
+	if err != nil {
+		return nil, false, err
+	}
+	if input.TenantID != "" {
+		tenantID = input.TenantID
+	}
+	data["brand"] = s.brand(ctx, tenantID)
+	return data, sample, nil
+}
+
+// previewAlertData returns the alert data a preview renders against, whether
+// it is a sample and the tenant the alert belongs to
+func (s *Service) previewAlertData(ctx context.Context, rule *database.Rule, input PreviewInput) (map[string]interface{}, bool, string, error) {
+	if input.AlertID != "" {
+		alert, err := s.alertRepo.GetByID(ctx, input.AlertID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, "", ErrAlertNotFound
+		}
+		if err != nil {
+			return nil, false, "", err
+		}
+
+		alertRule, err := s.ruleRepo.GetByID(ctx, alert.RuleID)
+		if err != nil {
+			// the alert still carries the rule's name if the rule is gone
+			alertRule = &database.Rule{ID: alert.RuleID, Name: alert.RuleName, Severity: alert.Severity}
+		}
+		return NewData(alertRule, alert, alert.SourceEvent, time.Now()), false, TenantOf(alertRule, alert.SourceEvent), nil
+	}
+
+	if rule == nil {
+		rule = SampleRule()
+	}
+	event := input.Event
+	if len(event) == 0 {
+		event = SampleEvent()
+	}
+	return NewData(rule, SampleAlert(rule, event), event, time.Now()), true, TenantOf(rule, event), nil
+}
+
+// resolve returns the enabled template for a rule's notifications on a
+// channel, or nil when only the built-in applies
+func (s *Service) resolve(ctx context.Context, channel, ruleID string) (*database.NotificationTemplate, error) {
+	key := channel + "/" + ruleID
+
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+
+	if !ok || time.Since(cached.loadedAt) > s.config.NotificationTemplates.CacheTTL {
+		template, err := s.repo.Resolve(ctx, channel, ruleID)
+		if err != nil && !errors.Is(err, database.ErrNotificationTemplateNotFound) {
+			return nil, err
+		}
+
+		cached = cachedTemplate{template: template, loadedAt: time.Now()}
+		s.mu.Lock()
+		s.cache[key] = cached
+		s.mu.Unlock()
+	}
+
+	return cached.template, nil
+}
+
+func (s *Service) checkRule(ctx context.Context, ruleID string) error {
+	_, err := s.ruleRepo.GetByID(ctx, ruleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrRuleNotFound
+	}
+	return err
+}
+
+// invalidate drops every resolved template, since a change to a channel
+// default affects every rule without its own override
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.cache = make(map[string]cachedTemplate)
+	s.mu.Unlock()
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
@@ -0,0 +1,117 @@
+package riskwebhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// EventType names risk tier transitions in payloads and the event header
+const EventType = "entity.risk_tier_changed"
+
+// Request headers sent with every webhook
+const (
+	EventHeader     = "X-AegisShield-Event"
+	DeliveryHeader  = "X-AegisShield-Delivery"
+	TimestampHeader = "X-AegisShield-Timestamp"
+	// SignatureHeader carries the HMAC-SHA256 of the timestamp header, a dot
+	// and the body, so a captured request cannot be resent later as new
+	SignatureHeader = "X-AegisShield-Signature"
+)
+
+// Directions of a risk tier transition
+const (
+	DirectionAssigned    = "assigned"  // the entity had no tier before
+	DirectionEscalated   = "escalated" // the entity moved to a riskier tier
+	DirectionDeescalated = "deescalated"
+	DirectionRemoved     = "removed" // the entity's tier was cleared
+)
+
+// tierRank orders risk tiers from least to most risky
+var tierRank = map[string]int{
+	database.RiskTierLow:      0,
+	database.RiskTierMedium:   1,
+	database.RiskTierHigh:     2,
+	database.RiskTierCritical: 3,
+}
+
+// Payload is the body of a risk tier webhook. Sequence orders transitions;
+// receivers should ignore a transition older than the last one they applied
+// for the entity.
+type Payload struct {
+	Event        string    `json:"event"`
+	DeliveryID   string    `json:"delivery_id"`
+	Sequence     int64     `json:"sequence"`
+	EntityID     string    `json:"entity_id"`
+	PreviousTier *string   `json:"previous_tier"`
+	Tier         *string   `json:"tier"`
+	Direction    string    `json:"direction"`
+	Score        *float64  `json:"score,omitempty"`
+	Reason       *string   `json:"reason,omitempty"`
+	OccurredAt   time.Time `json:"occurred_at"`
+	Replay       bool      `json:"replay"`
+}
+
+// BuildPayload renders the webhook body for a delivery of a transition
+func BuildPayload(delivery *database.RiskWebhookDelivery, transition *database.RiskTierTransition) Payload {
+	return Payload{
+		Event:        EventType,
+		DeliveryID:   delivery.ID,
+		Sequence:     transition.ID,
+		EntityID:     transition.EntityID,
+		PreviousTier: transition.PreviousTier,
+		Tier:         transition.Tier,
+		Direction:    Direction(transition.PreviousTier, transition.Tier),
+		Score:        transition.Score,
+		Reason:       transition.Reason,
+		OccurredAt:   transition.OccurredAt.UTC(),
+		Replay:       delivery.ReplayedAt != nil,
+	}
+}
+
+// Direction classifies a move between two tiers, either of which may be unset
+func Direction(previous, current *string) string {
+	switch {
+	case previous == nil:
+		return DirectionAssigned
+	case current == nil:
+		return DirectionRemoved
+	case tierRank[*current] > tierRank[*previous]:
+		return DirectionEscalated
+	default:
+		return DirectionDeescalated
+	}
+}
+
+// Sign returns the signature header value for a body sent at a time
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a webhook signature in constant time and rejects requests
+// signed more than tolerance away from now. Subscribers written in Go can
+// use it as the reference implementation.
+func Verify(secret, timestampHeader string, body []byte, signature string, tolerance time.Duration, now time.Time) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+
+	unix, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return false
+	}
+	timestamp := time.Unix(unix, 0)
+	if skew := now.Sub(timestamp); skew > tolerance || skew < -tolerance {
+		return false
+	}
+
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,437 @@
+package riskwebhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/casesync"
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+var (
+	// ErrInvalidSubscriber is returned for subscriber definitions that cannot be stored
+	ErrInvalidSubscriber = errors.New("invalid risk webhook subscriber")
+	// ErrInvalidReplay is returned for replay windows that are empty or in the future
+	ErrInvalidReplay = errors.New("invalid replay window")
+	// ErrReplayTooLarge is returned when a replay would requeue more transitions than allowed
+	ErrReplayTooLarge = errors.New("replay window holds too many transitions")
+)
+
+// Service publishes entity risk tier transitions to subscribed downstream
+// systems as signed webhooks. Transitions are logged and queued by a database
+// trigger on entity_risk_tiers, so every way a tier changes is published.
+type Service struct {
+	config *config.Config
+	logger *slog.Logger
+	repo   *database.RiskWebhookRepository
+	client *http.Client
+}
+
+// SubscriberInput describes a subscriber to create or update
+type SubscriberInput struct {
+	Name        string   `json:"name"`
+	EndpointURL string   `json:"endpoint_url"`
+	Enabled     *bool    `json:"enabled,omitempty"`
+	Tiers       []string `json:"tiers,omitempty"`
+	MaxAttempts int      `json:"max_attempts,omitempty"`
+}
+
+// CreatedSubscriber returns the signing secret once, when it is issued
+type CreatedSubscriber struct {
+	*database.RiskWebhookSubscriber
+	SigningSecret string `json:"signing_secret"`
+}
+
+// ReplayRequest requeues the transitions of a time window for a subscriber
+// that missed them. Until defaults to now.
+type ReplayRequest struct {
+	Since     time.Time  `json:"since"`
+	Until     *time.Time `json:"until,omitempty"`
+	Redeliver bool       `json:"redeliver"` // also resend transitions already delivered
+}
+
+// ReplayResult reports what a replay requeued
+type ReplayResult struct {
+	SubscriberID string    `json:"subscriber_id"`
+	Since        time.Time `json:"since"`
+	Until        time.Time `json:"until"`
+	Requeued     int       `json:"requeued"`
+}
+
+// DispatchResult summarizes one outbox dispatch pass
+type DispatchResult struct {
+	Claimed   int `json:"claimed"`
+	Delivered int `json:"delivered"`
+	Failed    int `json:"failed"`
+	Dead      int `json:"dead"`
+}
+
+// NewService creates a new risk webhook service
+func NewService(cfg *config.Config, logger *slog.Logger, repo *database.RiskWebhookRepository) *Service {
+	return &Service{
+		config: cfg,
+		logger: logger,
+		repo:   repo,
+		client: &http.Client{Timeout: cfg.RiskWebhooks.RequestTimeout},
+	}
+}
+
+// Subscriber management
+
+// CreateSubscriber validates and registers a subscriber with a fresh signing secret
+func (s *Service) CreateSubscriber(ctx context.Context, input SubscriberInput, actor string) (*CreatedSubscriber, error) {
+	secret, err := newSigningSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	subscriber := &database.RiskWebhookSubscriber{
+		ID:            generateID("rws"),
+		Enabled:       true,
+		SigningSecret: secret,
+		CreatedBy:     actor,
+	}
+	s.applyInput(subscriber, input)
+
+	if err := ValidateSubscriber(subscriber); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateSubscriber(ctx, subscriber); err != nil {
+		return nil, err
+	}
+
+	return &CreatedSubscriber{RiskWebhookSubscriber: subscriber, SigningSecret: secret}, nil
+}
+
+// UpdateSubscriber replaces a subscriber's configuration, keeping its signing
+// secret. Tier changes apply to transitions queued from then on.
+func (s *Service) UpdateSubscriber(ctx context.Context, id string, input SubscriberInput, actor string) (*database.RiskWebhookSubscriber, error) {
+	subscriber, err := s.repo.GetSubscriber(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyInput(subscriber, input)
+	subscriber.UpdatedBy = &actor
+
+	if err := ValidateSubscriber(subscriber); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateSubscriber(ctx, subscriber); err != nil {
+		return nil, err
+	}
+
+	return subscriber, nil
+}
+
+// RotateSecret issues a new signing secret for a subscriber. Webhooks are
+// signed with the new secret from the next dispatch on.
+func (s *Service) RotateSecret(ctx context.Context, id, actor string) (*CreatedSubscriber, error) {
+	subscriber, err := s.repo.GetSubscriber(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := newSigningSecret()
+	if err != nil {
+		return nil, err
+	}
+	subscriber.SigningSecret = secret
+	subscriber.UpdatedBy = &actor
+
+	if err := s.repo.UpdateSubscriber(ctx, subscriber); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Risk webhook signing secret rotated", "subscriber_id", id, "actor", actor)
+	return &CreatedSubscriber{RiskWebhookSubscriber: subscriber, SigningSecret: secret}, nil
+}
+
+// GetSubscriber retrieves a subscriber
+func (s *Service) GetSubscriber(ctx context.Context, id string) (*database.RiskWebhookSubscriber, error) {
+	return s.repo.GetSubscriber(ctx, id)
+}
+
+// ListSubscribers retrieves all subscribers
+func (s *Service) ListSubscribers(ctx context.Context) ([]*database.RiskWebhookSubscriber, error) {
+	return s.repo.ListSubscribers(ctx)
+}
+
+// DeleteSubscriber removes a subscriber and its undelivered webhooks
+func (s *Service) DeleteSubscriber(ctx context.Context, id string) error {
+	return s.repo.DeleteSubscriber(ctx, id)
+}
+
+// ListTransitions retrieves logged risk tier transitions
+func (s *Service) ListTransitions(ctx context.Context, entityID string, limit int) ([]*database.RiskTierTransition, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListTransitions(ctx, entityID, limit)
+}
+
+// ListDeliveries retrieves outbox entries; status dead lists the dead letters
+func (s *Service) ListDeliveries(ctx context.Context, subscriberID, entityID, status string, limit int) ([]*database.RiskWebhookDelivery, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListDeliveries(ctx, subscriberID, entityID, status, limit)
+}
+
+// RetryDelivery requeues a failed or dead delivery
+func (s *Service) RetryDelivery(ctx context.Context, id string) error {
+	return s.repo.RetryDelivery(ctx, id)
+}
+
+// Replay requeues the transitions of a window for a subscriber that was down
+// or disabled while they happened. Transitions reach it in their original
+// order, flagged as replays.
+func (s *Service) Replay(ctx context.Context, subscriberID string, req ReplayRequest, actor string) (*ReplayResult, error) {
+	subscriber, err := s.repo.GetSubscriber(ctx, subscriberID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	until := now
+	if req.Until != nil {
+		until = *req.Until
+	}
+	if req.Since.IsZero() || !req.Since.Before(until) || req.Since.After(now) {
+		return nil, fmt.Errorf("%w: since must be before until and not in the future", ErrInvalidReplay)
+	}
+
+	count, err := s.repo.CountReplayable(ctx, subscriber, req.Since, until, req.Redeliver)
+	if err != nil {
+		return nil, err
+	}
+	if max := s.config.RiskWebhooks.MaxReplayTransitions; count > max {
+		return nil, fmt.Errorf("%w: %d transitions, at most %d per replay", ErrReplayTooLarge, count, max)
+	}
+
+	requeued, err := s.repo.Replay(ctx, subscriber, req.Since, until, req.Redeliver)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Risk tier transitions replayed",
+		"subscriber_id", subscriberID,
+		"since", req.Since,
+		"until", until,
+		"redeliver", req.Redeliver,
+		"requeued", requeued,
+		"actor", actor)
+	return &ReplayResult{SubscriberID: subscriberID, Since: req.Since, Until: until, Requeued: requeued}, nil
+}
+
+// ValidateSubscriber checks a subscriber definition before it is stored
+func ValidateSubscriber(subscriber *database.RiskWebhookSubscriber) error {
+	if subscriber.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidSubscriber)
+	}
+	if err := validateURL(subscriber.EndpointURL); err != nil {
+		return fmt.Errorf("%w: endpoint_url: %v", ErrInvalidSubscriber, err)
+	}
+	for _, tier := range subscriber.Tiers {
+		if _, known := tierRank[tier]; !known {
+			return fmt.Errorf("%w: unknown risk tier %q", ErrInvalidSubscriber, tier)
+		}
+	}
+	if subscriber.MaxAttempts < 1 {
+		return fmt.Errorf("%w: max_attempts must be positive", ErrInvalidSubscriber)
+	}
+	return nil
+}
+
+// Outbound delivery
+
+// DispatchPending sends due outbox entries to their subscribers
+func (s *Service) DispatchPending(ctx context.Context) (*DispatchResult, error) {
+	deliveries, err := s.repo.ClaimDueDeliveries(ctx, s.config.RiskWebhooks.BatchSize, s.config.RiskWebhooks.DeliveryLease)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DispatchResult{Claimed: len(deliveries)}
+	if len(deliveries) == 0 {
+		return result, nil
+	}
+
+	ids := make([]int64, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.TransitionID)
+	}
+	transitions, err := s.repo.GetTransitions(ctx, ids)
+	if err != nil {
+		return result, err
+	}
+
+	subscribers := make(map[string]*database.RiskWebhookSubscriber)
+	for _, delivery := range deliveries {
+		subscriber, ok := subscribers[delivery.SubscriberID]
+		if !ok {
+			subscriber, err = s.repo.GetSubscriber(ctx, delivery.SubscriberID)
+			if err != nil {
+				s.logger.Error("Failed to load risk webhook subscriber",
+					"subscriber_id", delivery.SubscriberID,
+					"error", err)
+				continue
+			}
+			subscribers[delivery.SubscriberID] = subscriber
+		}
+
+		if !subscriber.Enabled {
+			// Leave queued; deliveries resume when the subscriber is re-enabled
+			continue
+		}
+
+		transition, ok := transitions[delivery.TransitionID]
+		if !ok {
+			continue
+		}
+
+		responseCode, err := s.deliver(ctx, subscriber, delivery, transition)
+		if err == nil {
+			if err := s.repo.MarkDelivered(ctx, delivery.ID, responseCode); err != nil {
+				return result, err
+			}
+			result.Delivered++
+			continue
+		}
+
+		attempts := delivery.Attempts + 1
+		dead := attempts >= subscriber.MaxAttempts
+		nextAttempt := time.Now().Add(casesync.RetryDelay(attempts, s.config.RiskWebhooks.RetryBaseDelay, s.config.RiskWebhooks.MaxRetryDelay))
+
+		var code *int
+		if responseCode != 0 {
+			code = &responseCode
+		}
+
+		if markErr := s.repo.MarkFailed(ctx, delivery.ID, code, err.Error(), nextAttempt, dead); markErr != nil {
+			return result, markErr
+		}
+
+		if dead {
+			result.Dead++
+			s.logger.Error("Risk webhook delivery dead-lettered",
+				"delivery_id", delivery.ID,
+				"subscriber", subscriber.Name,
+				"entity_id", delivery.EntityID,
+				"attempts", attempts,
+				"error", err)
+		} else {
+			result.Failed++
+			s.logger.Warn("Risk webhook delivery failed",
+				"delivery_id", delivery.ID,
+				"subscriber", subscriber.Name,
+				"entity_id", delivery.EntityID,
+				"attempts", attempts,
+				"next_attempt_at", nextAttempt,
+				"error", err)
+		}
+	}
+
+	return result, nil
+}
+
+// deliver sends one transition to a subscriber
+func (s *Service) deliver(ctx context.Context, subscriber *database.RiskWebhookSubscriber, delivery *database.RiskWebhookDelivery, transition *database.RiskTierTransition) (int, error) {
+	body, err := json.Marshal(BuildPayload(delivery, transition))
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscriber.EndpointURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	sentAt := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", delivery.ID)
+	req.Header.Set(EventHeader, EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(sentAt.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(subscriber.SigningSecret, sentAt, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("subscriber returned status %d: %s",
+			resp.StatusCode, truncate(string(respBody), 200))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Helpers
+
+func (s *Service) applyInput(subscriber *database.RiskWebhookSubscriber, input SubscriberInput) {
+	subscriber.Name = strings.TrimSpace(input.Name)
+	subscriber.EndpointURL = input.EndpointURL
+	if input.Enabled != nil {
+		subscriber.Enabled = *input.Enabled
+	}
+	subscriber.Tiers = input.Tiers
+	if subscriber.Tiers == nil {
+		subscriber.Tiers = []string{}
+	}
+	subscriber.MaxAttempts = input.MaxAttempts
+	if subscriber.MaxAttempts == 0 {
+		subscriber.MaxAttempts = s.config.RiskWebhooks.DefaultMaxAttempts
+	}
+}
+
+func validateURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return fmt.Errorf("must be an http or https URL")
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	return nil
+}
+
+func newSigningSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate signing secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
@@ -0,0 +1,334 @@
+package rulefeedback
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/training"
+	"github.com/aegisshield/shared/reqctx"
+)
+
+var alertDispositions = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "alerting_engine_alert_dispositions_total",
+	Help: "Alert dispositions recorded, by disposition and source",
+}, []string{"disposition", "source"})
+
+var (
+	// ErrAlertNotFound is returned when disposing of an alert that does not exist
+	ErrAlertNotFound = errors.New("alert not found")
+	// ErrInvalidDisposition is returned for dispositions other than true or false positive
+	ErrInvalidDisposition = errors.New("disposition must be true_positive or false_positive")
+	// ErrNoDispositions is returned when reporting on a rule with no dispositions
+	ErrNoDispositions = errors.New("rule has no dispositions")
+)
+
+// DispositionInput records an investigator's disposition of an alert.
+// Free-text dispositions such as "not suspicious" or "SAR filed" are
+// normalized the way historical resolution reasons are.
+type DispositionInput struct {
+	Disposition string `json:"disposition"`
+	Notes       string `json:"notes,omitempty"`
+}
+
+// ReportQuery selects the dispositions a report covers. Field is the dotted
+// event path thresholds are suggested for, defaulting to the configured one,
+// and Optimize asks the ml-pipeline for the suggestion when it is configured.
+type ReportQuery struct {
+	RuleID   string
+	Field    string
+	Since    *time.Time
+	Optimize bool
+}
+
+// RuleReport is a rule's precision over its dispositions with the suggested
+// threshold adjustment
+type RuleReport struct {
+	RuleID         string      `json:"rule_id"`
+	RuleName       string      `json:"rule_name"`
+	Dispositions   int         `json:"dispositions"`
+	TruePositives  int         `json:"true_positives"`
+	FalsePositives int         `json:"false_positives"`
+	Precision      float64     `json:"precision"`
+	Field          string      `json:"field"`
+	ValuedSamples  int         `json:"valued_samples"` // dispositions whose event has a numeric value for the field
+	Suggestion     *Suggestion `json:"suggestion,omitempty"`
+	Recommendation string      `json:"recommendation"`
+}
+
+// Report is the precision of every rule with dispositions, least precise first
+type Report struct {
+	Since          time.Time     `json:"since"`
+	GeneratedAt    time.Time     `json:"generated_at"`
+	TargetRecall   float64       `json:"target_recall"`
+	Dispositions   int           `json:"dispositions"`
+	TruePositives  int           `json:"true_positives"`
+	FalsePositives int           `json:"false_positives"`
+	Precision      float64       `json:"precision"`
+	Rules          []*RuleReport `json:"rules"`
+}
+
+// Service records investigator dispositions of alerts and feeds them back
+// into rule tuning as per-rule precision and threshold suggestions
+type Service struct {
+	config    *config.Config
+	logger    *slog.Logger
+	repo      *database.RuleFeedbackRepository
+	alertRepo *database.AlertRepository
+	client    *http.Client
+}
+
+// NewService creates a new rule feedback service
+func NewService(cfg *config.Config, logger *slog.Logger, repo *database.RuleFeedbackRepository, alertRepo *database.AlertRepository) *Service {
+	return &Service{
+		config:    cfg,
+		logger:    logger,
+		repo:      repo,
+		alertRepo: alertRepo,
+		client:    &http.Client{Timeout: cfg.RuleFeedback.OptimizerTimeout, Transport: reqctx.NewTransport("alerting-engine", nil)},
+	}
+}
+
+// RecordDisposition records an investigator's disposition of an alert
+func (s *Service) RecordDisposition(ctx context.Context, alertID string, input DispositionInput, investigator string) (*database.AlertDisposition, error) {
+	disposition, err := NormalizeDisposition(input.Disposition)
+	if err != nil {
+		return nil, err
+	}
+
+	alert, err := s.alertRepo.GetByID(ctx, alertID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAlertNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.record(ctx, alert, disposition, investigator, input.Notes, database.AlertDispositionSourceInvestigator)
+}
+
+// GetDisposition returns an alert's disposition
+func (s *Service) GetDisposition(ctx context.Context, alertID string) (*database.AlertDisposition, error) {
+	return s.repo.GetDisposition(ctx, alertID)
+}
+
+// RecordClosure records the disposition implied by closing an alert as
+// confirmed or false positive. Other statuses are ignored.
+func (s *Service) RecordClosure(ctx context.Context, alert *database.Alert, actor, reason string) error {
+	var disposition string
+	switch alert.Status {
+	case database.AlertStatusClosedConfirmed:
+		disposition = database.AlertDispositionTruePositive
+	case database.AlertStatusClosedFalsePositive:
+		disposition = database.AlertDispositionFalsePositive
+	default:
+		return nil
+	}
+
+	_, err := s.record(ctx, alert, disposition, actor, reason, database.AlertDispositionSourceLifecycle)
+	return err
+}
+
+func (s *Service) record(ctx context.Context, alert *database.Alert, disposition, investigator, notes, source string) (*database.AlertDisposition, error) {
+	record := &database.AlertDisposition{
+		AlertID:      alert.ID,
+		RuleID:       alert.RuleID,
+		Disposition:  disposition,
+		Investigator: investigator,
+		Source:       source,
+	}
+	if notes = strings.TrimSpace(notes); notes != "" {
+		record.Notes = &notes
+	}
+
+	recorded, err := s.repo.RecordDisposition(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	alertDispositions.WithLabelValues(disposition, source).Inc()
+	return recorded, nil
+}
+
+// Report computes the precision of every rule with dispositions in the query's window
+func (s *Service) Report(ctx context.Context, query ReportQuery) (*Report, error) {
+	cfg := s.config.RuleFeedback
+	since := time.Now().Add(-cfg.Lookback)
+	if query.Since != nil {
+		since = *query.Since
+	}
+
+	samples, err := s.repo.ListSamples(ctx, query.RuleID, since, cfg.MaxSamples)
+	if err != nil {
+		return nil, err
+	}
+
+	byRule := make(map[string][]*database.DispositionSample)
+	for _, sample := range samples {
+		byRule[sample.RuleID] = append(byRule[sample.RuleID], sample)
+	}
+
+	report := &Report{
+		Since:        since,
+		GeneratedAt:  time.Now(),
+		TargetRecall: cfg.TargetRecall,
+		Rules:        make([]*RuleReport, 0, len(byRule)),
+	}
+	for ruleID, ruleSamples := range byRule {
+		rule := s.ruleReport(ctx, ruleID, ruleSamples, query)
+		report.Dispositions += rule.Dispositions
+		report.TruePositives += rule.TruePositives
+		report.FalsePositives += rule.FalsePositives
+		report.Rules = append(report.Rules, rule)
+	}
+	report.Precision = Precision(report.TruePositives, report.FalsePositives)
+
+	sort.Slice(report.Rules, func(i, j int) bool {
+		if report.Rules[i].Precision != report.Rules[j].Precision {
+			return report.Rules[i].Precision < report.Rules[j].Precision
+		}
+		return report.Rules[i].RuleID < report.Rules[j].RuleID
+	})
+	return report, nil
+}
+
+// RuleReport computes one rule's precision and threshold suggestion
+func (s *Service) RuleReport(ctx context.Context, ruleID string, query ReportQuery) (*RuleReport, error) {
+	query.RuleID = ruleID
+	report, err := s.Report(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(report.Rules) == 0 {
+		return nil, ErrNoDispositions
+	}
+	return report.Rules[0], nil
+}
+
+func (s *Service) ruleReport(ctx context.Context, ruleID string, samples []*database.DispositionSample, query ReportQuery) *RuleReport {
+	cfg := s.config.RuleFeedback
+	field := query.Field
+	if field == "" {
+		field = cfg.ThresholdField
+	}
+
+	report := &RuleReport{
+		RuleID:       ruleID,
+		RuleName:     samples[0].RuleName,
+		Dispositions: len(samples),
+		Field:        field,
+	}
+
+	valued := make([]Sample, 0, len(samples))
+	for _, sample := range samples {
+		truePositive := sample.Disposition == database.AlertDispositionTruePositive
+		if truePositive {
+			report.TruePositives++
+		} else {
+			report.FalsePositives++
+		}
+		if value, ok := FieldValue(sample.SourceEvent, field); ok {
+			valued = append(valued, Sample{Value: value, TruePositive: truePositive})
+		}
+	}
+	report.Precision = Precision(report.TruePositives, report.FalsePositives)
+	report.ValuedSamples = len(valued)
+
+	if len(valued) >= cfg.MinDispositions {
+		report.Suggestion = s.suggest(ctx, ruleID, field, valued, query.Optimize)
+	}
+	report.Recommendation = Recommend(report.Dispositions, cfg.MinDispositions, report.Precision, cfg.MinPrecision, report.Suggestion)
+	return report
+}
+
+// suggest asks the ml-pipeline for the threshold when asked to and it is
+// configured, falling back to the local search when it is unavailable
+func (s *Service) suggest(ctx context.Context, ruleID, field string, samples []Sample, optimize bool) *Suggestion {
+	targetRecall := s.config.RuleFeedback.TargetRecall
+	if optimize && s.config.RuleFeedback.OptimizerURL != "" {
+		threshold, err := s.optimize(ctx, ruleID, field, samples, targetRecall)
+		if err == nil {
+			suggestion := Project(samples, threshold)
+			suggestion.Source = SourceMLPipeline
+			return suggestion
+		}
+		s.logger.Warn("Threshold optimizer unavailable, suggesting locally",
+			"rule_id", ruleID,
+			"error", err)
+	}
+	return SuggestThreshold(samples, targetRecall)
+}
+
+// optimize posts the labeled samples to the ml-pipeline threshold optimizer
+func (s *Service) optimize(ctx context.Context, ruleID, field string, samples []Sample, targetRecall float64) (float64, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"rule_id":       ruleID,
+		"field":         field,
+		"target_recall": targetRecall,
+		"samples":       samples,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal optimizer request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.RuleFeedback.OptimizerURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create optimizer request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call threshold optimizer: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("threshold optimizer returned %d: %s", resp.StatusCode, truncate(string(respBody), 500))
+	}
+
+	var optimized struct {
+		Threshold *float64 `json:"threshold"`
+	}
+	if err := json.Unmarshal(respBody, &optimized); err != nil || optimized.Threshold == nil {
+		return 0, errors.New("threshold optimizer response did not include a threshold")
+	}
+	return *optimized.Threshold, nil
+}
+
+// NormalizeDisposition maps an investigator's disposition onto true or
+// false positive
+func NormalizeDisposition(disposition string) (string, error) {
+	if strings.TrimSpace(disposition) == "" {
+		return "", ErrInvalidDisposition
+	}
+	switch training.NormalizeDisposition(disposition) {
+	case training.DispositionTruePositive:
+		return database.AlertDispositionTruePositive, nil
+	case training.DispositionFalsePositive:
+		return database.AlertDispositionFalsePositive, nil
+	default:
+		return "", ErrInvalidDisposition
+	}
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
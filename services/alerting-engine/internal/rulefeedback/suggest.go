@@ -0,0 +1,161 @@
+package rulefeedback
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Recommendations made for a rule from its dispositions
+const (
+	RecommendationInsufficientData = "insufficient_data"
+	RecommendationRaiseThreshold   = "raise_threshold"
+	RecommendationReviewRule       = "review_rule"
+	RecommendationKeep             = "keep"
+)
+
+// Suggestion sources
+const (
+	SourceLocal      = "local"
+	SourceMLPipeline = "ml_pipeline"
+)
+
+// Sample is the tuned field's value in the event behind a disposed alert
+type Sample struct {
+	Value        float64 `json:"value"`
+	TruePositive bool    `json:"true_positive"`
+}
+
+// Suggestion is a minimum for the tuned field below which the rule would no
+// longer alert, with its projected effect on the disposed alerts
+type Suggestion struct {
+	Threshold                float64 `json:"threshold"`
+	CurrentMinimum           float64 `json:"current_minimum"` // smallest value among the disposed alerts
+	ProjectedPrecision       float64 `json:"projected_precision"`
+	ProjectedRecall          float64 `json:"projected_recall"`
+	AlertsSuppressed         int     `json:"alerts_suppressed"`
+	FalsePositivesSuppressed int     `json:"false_positives_suppressed"`
+	TruePositivesLost        int     `json:"true_positives_lost"`
+	Source                   string  `json:"source"`
+}
+
+// Precision is the share of dispositions that were true positives. A rule
+// with no dispositions has zero precision rather than an undefined one.
+func Precision(truePositives, falsePositives int) float64 {
+	if total := truePositives + falsePositives; total > 0 {
+		return float64(truePositives) / float64(total)
+	}
+	return 0
+}
+
+// Project computes the effect of alerting only when the tuned field reaches
+// the threshold
+func Project(samples []Sample, threshold float64) *Suggestion {
+	suggestion := &Suggestion{Threshold: threshold, CurrentMinimum: threshold, Source: SourceLocal}
+	truePositives, keptTruePositives, keptFalsePositives := 0, 0, 0
+	for i, sample := range samples {
+		if i == 0 || sample.Value < suggestion.CurrentMinimum {
+			suggestion.CurrentMinimum = sample.Value
+		}
+		if sample.TruePositive {
+			truePositives++
+		}
+		switch {
+		case sample.Value >= threshold && sample.TruePositive:
+			keptTruePositives++
+		case sample.Value >= threshold:
+			keptFalsePositives++
+		case sample.TruePositive:
+			suggestion.AlertsSuppressed++
+			suggestion.TruePositivesLost++
+		default:
+			suggestion.AlertsSuppressed++
+			suggestion.FalsePositivesSuppressed++
+		}
+	}
+
+	suggestion.ProjectedPrecision = Precision(keptTruePositives, keptFalsePositives)
+	if truePositives > 0 {
+		suggestion.ProjectedRecall = float64(keptTruePositives) / float64(truePositives)
+	}
+	return suggestion
+}
+
+// SuggestThreshold picks the threshold on the tuned field with the highest
+// projected precision that still keeps at least targetRecall of the true
+// positives, preferring the lowest such threshold. Only values seen in the
+// samples are tried. It returns nil when there are no true positives to keep.
+func SuggestThreshold(samples []Sample, targetRecall float64) *Suggestion {
+	values := make([]float64, 0, len(samples))
+	hasTruePositive := false
+	for _, sample := range samples {
+		values = append(values, sample.Value)
+		hasTruePositive = hasTruePositive || sample.TruePositive
+	}
+	if !hasTruePositive {
+		return nil
+	}
+	sort.Float64s(values)
+
+	var best *Suggestion
+	for i, value := range values {
+		if i > 0 && value == values[i-1] {
+			continue
+		}
+		candidate := Project(samples, value)
+		// raising the threshold only ever loses recall
+		if candidate.ProjectedRecall < targetRecall {
+			break
+		}
+		if best == nil || candidate.ProjectedPrecision > best.ProjectedPrecision {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// Recommend sums up what a rule's dispositions say should be done with it
+func Recommend(dispositions, minDispositions int, precision, minPrecision float64, suggestion *Suggestion) string {
+	switch {
+	case dispositions < minDispositions:
+		return RecommendationInsufficientData
+	case suggestion != nil && suggestion.FalsePositivesSuppressed > 0:
+		return RecommendationRaiseThreshold
+	case precision < minPrecision:
+		return RecommendationReviewRule
+	default:
+		return RecommendationKeep
+	}
+}
+
+// FieldValue reads a numeric field of an event, following dots into nested objects
+func FieldValue(event map[string]interface{}, path string) (float64, bool) {
+	var current interface{} = event
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return 0, false
+		}
+		if current, ok = object[key]; !ok {
+			return 0, false
+		}
+	}
+
+	switch v := current.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
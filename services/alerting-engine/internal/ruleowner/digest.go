@@ -0,0 +1,211 @@
+package ruleowner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// Policy decides when a rule's evaluation errors or dispositions are worth
+// telling its owners about
+type Policy struct {
+	ErrorBudget              float64
+	MinEvaluations           int64
+	MinDispositions          int
+	MaxFalsePositiveRate     float64
+	MinFalsePositiveIncrease float64
+}
+
+// EvaluationCount is a rule's evaluations and evaluation errors since the
+// rule engine started
+type EvaluationCount struct {
+	RuleName    string
+	Evaluations int64
+	Errors      int64
+}
+
+// Trend is a rule's false-positive rate in the recent window and the window
+// before it. PriorRate is nil when the prior window had too few dispositions.
+type Trend struct {
+	RecentDispositions int
+	RecentRate         float64
+	PriorDispositions  int
+	PriorRate          *float64
+}
+
+// Recipient is where one owner receives their digest
+type Recipient struct {
+	OwnerID   string
+	Channel   string
+	Recipient string
+}
+
+// ErrorBudgetExceeded returns the share of evaluations that failed and
+// whether it is over the budget. Too few evaluations are not judged.
+func ErrorBudgetExceeded(evaluations, errors int64, policy Policy) (float64, bool) {
+	if evaluations <= 0 || evaluations < policy.MinEvaluations {
+		return 0, false
+	}
+	ratio := float64(errors) / float64(evaluations)
+	return ratio, ratio > policy.ErrorBudget
+}
+
+// FalsePositiveTrend reports whether most of a rule's recent dispositions
+// were false positives and the rate has risen. Without enough prior
+// dispositions to compare against, a recent rate over the limit is enough.
+func FalsePositiveTrend(counts *database.RuleDispositionTrend, policy Policy) (Trend, bool) {
+	trend := Trend{
+		RecentDispositions: counts.RecentTotal,
+		PriorDispositions:  counts.PriorTotal,
+	}
+	if counts.RecentTotal == 0 || counts.RecentTotal < policy.MinDispositions {
+		return trend, false
+	}
+	trend.RecentRate = float64(counts.RecentFalsePositives) / float64(counts.RecentTotal)
+
+	if counts.PriorTotal > 0 && counts.PriorTotal >= policy.MinDispositions {
+		prior := float64(counts.PriorFalsePositives) / float64(counts.PriorTotal)
+		trend.PriorRate = &prior
+	}
+
+	if trend.RecentRate < policy.MaxFalsePositiveRate {
+		return trend, false
+	}
+	if trend.PriorRate != nil && trend.RecentRate-*trend.PriorRate < policy.MinFalsePositiveIncrease {
+		return trend, false
+	}
+	return trend, true
+}
+
+// GroupByOwner assigns each event to every owner of its rule. Recipients are
+// returned in owner order; events of rules without owners go to nobody.
+func GroupByOwner(events []*database.RuleLifecycleEvent, owners []*database.RuleOwner) ([]Recipient, map[Recipient][]*database.RuleLifecycleEvent) {
+	byRule := make(map[string][]Recipient)
+	for _, owner := range owners {
+		byRule[owner.RuleID] = append(byRule[owner.RuleID], Recipient{
+			OwnerID:   owner.OwnerID,
+			Channel:   owner.Channel,
+			Recipient: owner.Recipient,
+		})
+	}
+
+	groups := make(map[Recipient][]*database.RuleLifecycleEvent)
+	for _, event := range events {
+		for _, recipient := range byRule[event.RuleID] {
+			groups[recipient] = append(groups[recipient], event)
+		}
+	}
+
+	recipients := make([]Recipient, 0, len(groups))
+	for recipient := range groups {
+		recipients = append(recipients, recipient)
+	}
+	sort.Slice(recipients, func(i, j int) bool {
+		a, b := recipients[i], recipients[j]
+		if a.OwnerID != b.OwnerID {
+			return a.OwnerID < b.OwnerID
+		}
+		if a.Channel != b.Channel {
+			return a.Channel < b.Channel
+		}
+		return a.Recipient < b.Recipient
+	})
+
+	return recipients, groups
+}
+
+// NeedsAttention reports whether an event asks the owner to look at the rule
+func NeedsAttention(event *database.RuleLifecycleEvent) bool {
+	return event.EventType == database.RuleEventErrorBudgetExceeded ||
+		event.EventType == database.RuleEventFalsePositiveTrend
+}
+
+// Render builds the subject and body of an owner's digest, with the events
+// listed under their rule in the order they happened
+func Render(events []*database.RuleLifecycleEvent) (string, string) {
+	var ruleIDs []string
+	byRule := make(map[string][]*database.RuleLifecycleEvent)
+	attention := 0
+	for _, event := range events {
+		if _, ok := byRule[event.RuleID]; !ok {
+			ruleIDs = append(ruleIDs, event.RuleID)
+		}
+		byRule[event.RuleID] = append(byRule[event.RuleID], event)
+		if NeedsAttention(event) {
+			attention++
+		}
+	}
+
+	subject := fmt.Sprintf("Rule digest: %d %s on %d %s",
+		len(events), plural(len(events), "event", "events"),
+		len(ruleIDs), plural(len(ruleIDs), "rule", "rules"))
+	if attention > 0 {
+		subject += fmt.Sprintf(" (%d needing attention)", attention)
+	}
+
+	var b strings.Builder
+	b.WriteString("Lifecycle events for the rules you own.\n")
+	for _, ruleID := range ruleIDs {
+		ruleEvents := byRule[ruleID]
+		sort.SliceStable(ruleEvents, func(i, j int) bool {
+			return ruleEvents[i].OccurredAt.Before(ruleEvents[j].OccurredAt)
+		})
+
+		fmt.Fprintf(&b, "\n%s (%s):\n", ruleEvents[0].RuleName, ruleID)
+		for _, event := range ruleEvents {
+			fmt.Fprintf(&b, "- %s %s\n", event.OccurredAt.UTC().Format(time.RFC3339), Describe(event))
+		}
+	}
+
+	return subject, b.String()
+}
+
+// Describe renders one event as a line of the digest
+func Describe(event *database.RuleLifecycleEvent) string {
+	switch event.EventType {
+	case database.RuleEventEnabled, database.RuleEventDisabled:
+		if event.Actor != nil {
+			return fmt.Sprintf("%s by %s", event.EventType, *event.Actor)
+		}
+		return event.EventType
+	case database.RuleEventErrorBudgetExceeded:
+		return fmt.Sprintf("error budget exceeded: %s of %d evaluations failed (budget %s)",
+			percent(number(event.Details, "error_ratio")), int64(number(event.Details, "evaluations")),
+			percent(number(event.Details, "error_budget")))
+	case database.RuleEventFalsePositiveTrend:
+		line := fmt.Sprintf("false positives trending up: %s of %d recent dispositions",
+			percent(number(event.Details, "recent_rate")), int64(number(event.Details, "recent_dispositions")))
+		if _, ok := event.Details["prior_rate"]; ok {
+			line += fmt.Sprintf(", up from %s", percent(number(event.Details, "prior_rate")))
+		}
+		return line
+	}
+	return event.EventType
+}
+
+// number reads a numeric detail, whether set in memory or decoded from JSON
+func number(details database.JSONB, key string) float64 {
+	switch v := details[key].(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+func percent(ratio float64) string {
+	return fmt.Sprintf("%.1f%%", ratio*100)
+}
+
+func plural(n int, singular, many string) string {
+	if n == 1 {
+		return singular
+	}
+	return many
+}
@@ -0,0 +1,421 @@
+package ruleowner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/notification"
+)
+
+var (
+	ruleLifecycleEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "alerting_engine_rule_lifecycle_events_total",
+		Help: "Rule lifecycle events raised by the rule health check, by event type",
+	}, []string{"event_type"})
+
+	ruleOwnerDigests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "alerting_engine_rule_owner_digests_total",
+		Help: "Rule lifecycle digests sent to rule owners, by status",
+	}, []string{"status"})
+)
+
+var (
+	// ErrRuleNotFound is returned when managing the owners of a rule that does not exist
+	ErrRuleNotFound = errors.New("rule not found")
+	// ErrInvalidOwner is returned for rule owners that cannot be stored
+	ErrInvalidOwner = errors.New("invalid rule owner")
+)
+
+// Store persists rule owners, lifecycle events and digests
+type Store interface {
+	ReplaceOwners(ctx context.Context, ruleID string, owners []*database.RuleOwner) error
+	ListOwners(ctx context.Context, ruleIDs []string) ([]*database.RuleOwner, error)
+	RecordEvent(ctx context.Context, event *database.RuleLifecycleEvent) error
+	LastEventAt(ctx context.Context, ruleID, eventType string) (*time.Time, error)
+	ListEvents(ctx context.Context, filter database.RuleLifecycleEventFilter) ([]*database.RuleLifecycleEvent, error)
+	ListPendingEvents(ctx context.Context, limit int) ([]*database.RuleLifecycleEvent, error)
+	MarkEventsDigested(ctx context.Context, ids []string, at time.Time) error
+	SaveDigest(ctx context.Context, digest *database.RuleOwnerDigest) error
+	ListDigests(ctx context.Context, ownerID string, limit int) ([]*database.RuleOwnerDigest, error)
+	DispositionTrends(ctx context.Context, split, since time.Time) ([]*database.RuleDispositionTrend, error)
+}
+
+// RuleLookup finds the rules owners are set for
+type RuleLookup interface {
+	GetByID(ctx context.Context, id string) (*database.Rule, error)
+}
+
+// Notifier delivers digests through the notification channel providers
+type Notifier interface {
+	Channels() []string
+	Send(ctx context.Context, channel string, message *notification.Message, routeID *string) error
+}
+
+// OwnerInput names an owner of a rule and where they receive their digest.
+// An empty recipient uses the channel provider's default.
+type OwnerInput struct {
+	OwnerID   string `json:"owner_id"`
+	Channel   string `json:"channel"`
+	Recipient string `json:"recipient,omitempty"`
+}
+
+// CheckResult counts the events raised by a rule health check
+type CheckResult struct {
+	ErrorBudgetExceeded int `json:"error_budget_exceeded"`
+	FalsePositiveTrends int `json:"false_positive_trends"`
+}
+
+// DigestResult summarizes a digest run
+type DigestResult struct {
+	Events  int `json:"events"`
+	Digests int `json:"digests"`
+	Failed  int `json:"failed"`
+}
+
+// Service keeps track of who owns each rule and tells them, in one digest
+// per owner, when their rules are enabled or disabled, fail evaluation more
+// often than the error budget allows or trend towards false positives
+type Service struct {
+	config   *config.Config
+	logger   *slog.Logger
+	store    Store
+	rules    RuleLookup
+	notifier Notifier
+	now      func() time.Time
+
+	mu         sync.Mutex
+	lastCounts map[string]EvaluationCount
+}
+
+// NewService creates a new rule owner service
+func NewService(cfg *config.Config, logger *slog.Logger, store Store, rules RuleLookup, notifier Notifier) *Service {
+	return &Service{
+		config:     cfg,
+		logger:     logger,
+		store:      store,
+		rules:      rules,
+		notifier:   notifier,
+		now:        time.Now,
+		lastCounts: make(map[string]EvaluationCount),
+	}
+}
+
+// Policy returns the configured thresholds for raising events
+func (s *Service) Policy() Policy {
+	cfg := s.config.RuleOwners
+	return Policy{
+		ErrorBudget:              cfg.ErrorBudget,
+		MinEvaluations:           cfg.MinEvaluations,
+		MinDispositions:          cfg.MinDispositions,
+		MaxFalsePositiveRate:     cfg.MaxFalsePositiveRate,
+		MinFalsePositiveIncrease: cfg.MinFalsePositiveIncrease,
+	}
+}
+
+// Owners
+
+// SetOwners replaces the owners of a rule
+func (s *Service) SetOwners(ctx context.Context, ruleID string, inputs []OwnerInput, actor string) ([]*database.RuleOwner, error) {
+	if _, err := s.rules.GetByID(ctx, ruleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, err
+	}
+
+	channels := make(map[string]bool)
+	for _, channel := range s.notifier.Channels() {
+		channels[channel] = true
+	}
+
+	owners := make([]*database.RuleOwner, 0, len(inputs))
+	seen := make(map[string]bool, len(inputs))
+	for _, input := range inputs {
+		ownerID := strings.TrimSpace(input.OwnerID)
+		if ownerID == "" {
+			return nil, fmt.Errorf("%w: owner_id is required", ErrInvalidOwner)
+		}
+		if seen[ownerID] {
+			return nil, fmt.Errorf("%w: %s is listed more than once", ErrInvalidOwner, ownerID)
+		}
+		seen[ownerID] = true
+
+		channel := strings.TrimSpace(input.Channel)
+		if !channels[channel] {
+			return nil, fmt.Errorf("%w: channel %q has no enabled provider; available: %s",
+				ErrInvalidOwner, channel, strings.Join(s.notifier.Channels(), ", "))
+		}
+
+		owners = append(owners, &database.RuleOwner{
+			RuleID:    ruleID,
+			OwnerID:   ownerID,
+			Channel:   channel,
+			Recipient: strings.TrimSpace(input.Recipient),
+			CreatedBy: actor,
+		})
+	}
+
+	if err := s.store.ReplaceOwners(ctx, ruleID, owners); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Rule owners updated",
+		"rule_id", ruleID,
+		"owners", len(owners),
+		"actor", actor)
+	return owners, nil
+}
+
+// ListOwners retrieves the owners of a rule
+func (s *Service) ListOwners(ctx context.Context, ruleID string) ([]*database.RuleOwner, error) {
+	if _, err := s.rules.GetByID(ctx, ruleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, err
+	}
+	return s.store.ListOwners(ctx, []string{ruleID})
+}
+
+// ListEvents retrieves lifecycle events, newest first
+func (s *Service) ListEvents(ctx context.Context, filter database.RuleLifecycleEventFilter) ([]*database.RuleLifecycleEvent, error) {
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		filter.Limit = 100
+	}
+	return s.store.ListEvents(ctx, filter)
+}
+
+// ListDigests retrieves digests sent to owners, newest first
+func (s *Service) ListDigests(ctx context.Context, ownerID string, limit int) ([]*database.RuleOwnerDigest, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	return s.store.ListDigests(ctx, ownerID, limit)
+}
+
+// Health checks
+
+// Check raises error budget and false-positive trend events. Counts are the
+// rule engine's evaluations per rule since it started; the error budget is
+// judged on the evaluations since the previous check.
+func (s *Service) Check(ctx context.Context, counts map[string]EvaluationCount) (*CheckResult, error) {
+	result := &CheckResult{}
+	policy := s.Policy()
+
+	s.mu.Lock()
+	previous := s.lastCounts
+	s.lastCounts = counts
+	s.mu.Unlock()
+
+	var errs []error
+	for ruleID, count := range counts {
+		evaluations, failures := count.Evaluations, count.Errors
+		// Counts only go down when the engine restarted
+		if last, ok := previous[ruleID]; ok && last.Evaluations <= evaluations && last.Errors <= failures {
+			evaluations -= last.Evaluations
+			failures -= last.Errors
+		}
+
+		ratio, exceeded := ErrorBudgetExceeded(evaluations, failures, policy)
+		if !exceeded {
+			continue
+		}
+
+		raised, err := s.raise(ctx, ruleID, count.RuleName, database.RuleEventErrorBudgetExceeded, database.JSONB{
+			"evaluations":  evaluations,
+			"errors":       failures,
+			"error_ratio":  ratio,
+			"error_budget": policy.ErrorBudget,
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if raised {
+			result.ErrorBudgetExceeded++
+		}
+	}
+
+	window := s.config.RuleOwners.TrendWindow
+	split := s.now().Add(-window)
+	trends, err := s.store.DispositionTrends(ctx, split, split.Add(-window))
+	if err != nil {
+		return result, err
+	}
+	for _, counts := range trends {
+		trend, rising := FalsePositiveTrend(counts, policy)
+		if !rising {
+			continue
+		}
+
+		details := database.JSONB{
+			"recent_dispositions": trend.RecentDispositions,
+			"recent_rate":         trend.RecentRate,
+			"prior_dispositions":  trend.PriorDispositions,
+		}
+		if trend.PriorRate != nil {
+			details["prior_rate"] = *trend.PriorRate
+		}
+
+		raised, err := s.raise(ctx, counts.RuleID, counts.RuleName, database.RuleEventFalsePositiveTrend, details)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if raised {
+			result.FalsePositiveTrends++
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// raise records an event unless the rule had one of the same type within the cooldown
+func (s *Service) raise(ctx context.Context, ruleID, ruleName, eventType string, details database.JSONB) (bool, error) {
+	now := s.now()
+	last, err := s.store.LastEventAt(ctx, ruleID, eventType)
+	if err != nil {
+		return false, err
+	}
+	if last != nil && now.Sub(*last) < s.config.RuleOwners.EventCooldown {
+		return false, nil
+	}
+
+	event := &database.RuleLifecycleEvent{
+		ID:         generateID("rule_event"),
+		RuleID:     ruleID,
+		RuleName:   ruleName,
+		EventType:  eventType,
+		Details:    details,
+		OccurredAt: now,
+	}
+	if err := s.store.RecordEvent(ctx, event); err != nil {
+		return false, err
+	}
+	ruleLifecycleEvents.WithLabelValues(eventType).Inc()
+
+	s.logger.Info("Rule lifecycle event raised",
+		"rule_id", ruleID,
+		"event_type", eventType)
+	return true, nil
+}
+
+// Digests
+
+// SendDigests sends every owner one digest of the pending events of their
+// rules. Events are marked sent once every owner's delivery was attempted;
+// a failed delivery is recorded on the owner's digest and not retried.
+// Events of rules nobody owns are marked sent without a digest.
+func (s *Service) SendDigests(ctx context.Context) (*DigestResult, error) {
+	events, err := s.store.ListPendingEvents(ctx, s.config.RuleOwners.MaxEventsPerDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DigestResult{Events: len(events)}
+	if len(events) == 0 {
+		return result, nil
+	}
+
+	ruleIDs := make([]string, 0, len(events))
+	eventIDs := make([]string, 0, len(events))
+	seen := make(map[string]bool)
+	for _, event := range events {
+		eventIDs = append(eventIDs, event.ID)
+		if !seen[event.RuleID] {
+			seen[event.RuleID] = true
+			ruleIDs = append(ruleIDs, event.RuleID)
+		}
+	}
+
+	owners, err := s.store.ListOwners(ctx, ruleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients, groups := GroupByOwner(events, owners)
+	for _, recipient := range recipients {
+		digest, err := s.send(ctx, recipient, groups[recipient])
+		if err != nil {
+			return result, err
+		}
+		result.Digests++
+		if digest.Status == database.RuleOwnerDigestFailed {
+			result.Failed++
+		}
+	}
+
+	if err := s.store.MarkEventsDigested(ctx, eventIDs, s.now()); err != nil {
+		return result, err
+	}
+
+	s.logger.Info("Rule owner digests sent",
+		"events", result.Events,
+		"digests", result.Digests,
+		"failed", result.Failed)
+	return result, nil
+}
+
+// send delivers one owner's digest and records it
+func (s *Service) send(ctx context.Context, recipient Recipient, events []*database.RuleLifecycleEvent) (*database.RuleOwnerDigest, error) {
+	subject, body := Render(events)
+
+	digest := &database.RuleOwnerDigest{
+		ID:        generateID("rule_digest"),
+		OwnerID:   recipient.OwnerID,
+		Channel:   recipient.Channel,
+		Recipient: recipient.Recipient,
+		EventIDs:  make([]string, 0, len(events)),
+		Subject:   subject,
+		Body:      body,
+		Status:    database.RuleOwnerDigestSent,
+		CreatedAt: s.now(),
+	}
+	severity := "low"
+	for _, event := range events {
+		digest.EventIDs = append(digest.EventIDs, event.ID)
+		if NeedsAttention(event) {
+			severity = "medium"
+		}
+	}
+
+	message := &notification.Message{
+		Recipient: recipient.Recipient,
+		Subject:   subject,
+		Body:      body,
+		Severity:  severity,
+		Priority:  severity,
+		Details: map[string]interface{}{
+			"digest_id": digest.ID,
+			"owner_id":  recipient.OwnerID,
+			"event_ids": []string(digest.EventIDs),
+		},
+		CreatedAt: digest.CreatedAt,
+	}
+	if err := s.notifier.Send(ctx, recipient.Channel, message, nil); err != nil {
+		digest.Status = database.RuleOwnerDigestFailed
+		errMessage := err.Error()
+		digest.Error = &errMessage
+	}
+	ruleOwnerDigests.WithLabelValues(digest.Status).Inc()
+
+	if err := s.store.SaveDigest(ctx, digest); err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
@@ -0,0 +1,64 @@
+package rulepack
+
+import (
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// ReferenceIndex is an in-memory copy of the lookup tables and thresholds
+// rule conditions read, so evaluation never hits the database
+type ReferenceIndex struct {
+	lookups    map[string]map[string]struct{}
+	thresholds map[string]float64
+}
+
+// NewReferenceIndex builds an index from stored lookup tables and thresholds
+func NewReferenceIndex(tables []*database.LookupTable, thresholds []*database.RuleThreshold) *ReferenceIndex {
+	index := &ReferenceIndex{
+		lookups:    make(map[string]map[string]struct{}, len(tables)),
+		thresholds: make(map[string]float64, len(thresholds)),
+	}
+	for _, table := range tables {
+		entries := make(map[string]struct{}, len(table.Entries))
+		for _, entry := range table.Entries {
+			entries[entry] = struct{}{}
+		}
+		index.lookups[table.Name] = entries
+	}
+	for _, threshold := range thresholds {
+		index.thresholds[threshold.Name] = threshold.Value
+	}
+	return index
+}
+
+// InLookup reports whether value is an entry of the named lookup table
+func (i *ReferenceIndex) InLookup(table, value string) bool {
+	if i == nil {
+		return false
+	}
+	_, ok := i.lookups[table][value]
+	return ok
+}
+
+// Threshold returns the value of a named threshold
+func (i *ReferenceIndex) Threshold(name string) (float64, bool) {
+	if i == nil {
+		return 0, false
+	}
+	value, ok := i.thresholds[name]
+	return value, ok
+}
+
+// HasLookup reports whether the named lookup table exists
+func (i *ReferenceIndex) HasLookup(name string) bool {
+	if i == nil {
+		return false
+	}
+	_, ok := i.lookups[name]
+	return ok
+}
+
+// HasThreshold reports whether the named threshold exists
+func (i *ReferenceIndex) HasThreshold(name string) bool {
+	_, ok := i.Threshold(name)
+	return ok
+}
@@ -0,0 +1,362 @@
+package rulepack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// FormatVersion is the rule pack document format this service reads and writes
+const FormatVersion = "1"
+
+// SignatureAlgorithm is the only pack signature algorithm accepted
+const SignatureAlgorithm = "hmac-sha256"
+
+// provenanceKey is the rule metadata key holding where an imported rule came from
+const provenanceKey = "provenance"
+
+// Conflict resolution modes applied when a pack item's name already exists
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+var (
+	// ErrUnsigned is returned when a pack carries no signature
+	ErrUnsigned = errors.New("rule pack is not signed")
+	// ErrUnknownSigningKey is returned when a pack was signed with a key this deployment does not hold
+	ErrUnknownSigningKey = errors.New("rule pack signed with an unknown key")
+	// ErrInvalidSignature is returned when a pack was modified after signing
+	ErrInvalidSignature = errors.New("rule pack signature does not match its contents")
+)
+
+// nonIdentifier matches characters not allowed in renamed reference names
+var nonIdentifier = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// referencePattern finds the lookup tables and thresholds a condition names
+var referencePattern = regexp.MustCompile(`\b(in_lookup|threshold)\(\s*["']([^"']+)["']`)
+
+// Pack is a portable, signed set of rules with the lookup tables and
+// thresholds their conditions reference
+type Pack struct {
+	FormatVersion    string                 `json:"format_version"`
+	Name             string                 `json:"name"`
+	Version          string                 `json:"version"`
+	Description      string                 `json:"description,omitempty"`
+	DSLVersion       string                 `json:"dsl_version"`
+	SourceDeployment string                 `json:"source_deployment,omitempty"`
+	ExportedBy       string                 `json:"exported_by,omitempty"`
+	ExportedAt       time.Time              `json:"exported_at"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	Rules            []PackRule             `json:"rules"`
+	LookupTables     []PackLookupTable      `json:"lookup_tables,omitempty"`
+	Thresholds       []PackThreshold        `json:"thresholds,omitempty"`
+	Signature        *Signature             `json:"signature,omitempty"`
+}
+
+// PackRule is a rule as carried in a pack. Escalation policies are
+// deployment-specific and are not exported.
+type PackRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type"`
+	Severity             string                 `json:"severity"`
+	Priority             string                 `json:"priority"`
+	Enabled              bool                   `json:"enabled"`
+	Conditions           map[string]interface{} `json:"conditions"`
+	Actions              map[string]interface{} `json:"actions,omitempty"`
+	Tags                 []string               `json:"tags,omitempty"`
+	Metadata             map[string]interface{} `json:"metadata,omitempty"`
+	ThrottleWindow       *time.Duration         `json:"throttle_window,omitempty"`
+	EvaluationWindow     *time.Duration         `json:"evaluation_window,omitempty"`
+	GroupBy              []string               `json:"group_by,omitempty"`
+	NotificationChannels []string               `json:"notification_channels,omitempty"`
+}
+
+// PackLookupTable is a lookup table as carried in a pack
+type PackLookupTable struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Entries     []string `json:"entries"`
+}
+
+// PackThreshold is a named threshold as carried in a pack
+type PackThreshold struct {
+	Name        string  `json:"name"`
+	Value       float64 `json:"value"`
+	Description string  `json:"description,omitempty"`
+}
+
+// Signature is an HMAC over the pack's canonical form
+type Signature struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+}
+
+// NewPackRule converts a stored rule for export, dropping the provenance of
+// any earlier import since that describes this deployment only
+func NewPackRule(rule *database.Rule) PackRule {
+	metadata := make(map[string]interface{}, len(rule.Metadata))
+	for key, value := range rule.Metadata {
+		if key != provenanceKey {
+			metadata[key] = value
+		}
+	}
+
+	return PackRule{
+		ID:                   rule.ID,
+		Name:                 rule.Name,
+		Description:          rule.Description,
+		Type:                 rule.Type,
+		Severity:             rule.Severity,
+		Priority:             rule.Priority,
+		Enabled:              rule.Enabled,
+		Conditions:           rule.Conditions,
+		Actions:              rule.Actions,
+		Tags:                 rule.Tags,
+		Metadata:             metadata,
+		ThrottleWindow:       rule.ThrottleWindow,
+		EvaluationWindow:     rule.EvaluationWindow,
+		GroupBy:              rule.GroupBy,
+		NotificationChannels: rule.NotificationChannels,
+	}
+}
+
+// CanonicalBytes returns the bytes a signature covers: the pack's JSON with
+// the signature itself removed. Map keys are sorted by encoding/json, so the
+// form survives a decode and re-encode.
+func (p *Pack) CanonicalBytes() ([]byte, error) {
+	unsigned := *p
+	unsigned.Signature = nil
+
+	body, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rule pack: %w", err)
+	}
+	return body, nil
+}
+
+// Sign signs the pack with the given key
+func Sign(pack *Pack, keyID, secret string) error {
+	body, err := pack.CanonicalBytes()
+	if err != nil {
+		return err
+	}
+
+	pack.Signature = &Signature{
+		KeyID:     keyID,
+		Algorithm: SignatureAlgorithm,
+		Value:     signBody(secret, body),
+	}
+	return nil
+}
+
+// Verify checks the pack signature against the key it names
+func Verify(pack *Pack, keys map[string]string) error {
+	if pack.Signature == nil || pack.Signature.Value == "" {
+		return ErrUnsigned
+	}
+	if pack.Signature.Algorithm != SignatureAlgorithm {
+		return fmt.Errorf("unsupported signature algorithm %q", pack.Signature.Algorithm)
+	}
+
+	secret, ok := keys[pack.Signature.KeyID]
+	if !ok || secret == "" {
+		return fmt.Errorf("%w: %s", ErrUnknownSigningKey, pack.Signature.KeyID)
+	}
+
+	body, err := pack.CanonicalBytes()
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(signBody(secret, body)), []byte(pack.Signature.Value)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func signBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckDSLCompatibility reports whether conditions written for packVersion
+// run on an engine at engineVersion: the major versions must match and the
+// pack may not need a newer minor version than the engine provides
+func CheckDSLCompatibility(packVersion, engineVersion string) error {
+	packMajor, packMinor, err := parseDSLVersion(packVersion)
+	if err != nil {
+		return err
+	}
+	engineMajor, engineMinor, err := parseDSLVersion(engineVersion)
+	if err != nil {
+		return err
+	}
+
+	if packMajor != engineMajor {
+		return fmt.Errorf("rule pack DSL version %s is incompatible with engine DSL version %s", packVersion, engineVersion)
+	}
+	if packMinor > engineMinor {
+		return fmt.Errorf("rule pack needs DSL version %s but the engine provides %s", packVersion, engineVersion)
+	}
+	return nil
+}
+
+func parseDSLVersion(version string) (int, int, error) {
+	majorPart, minorPart, _ := strings.Cut(strings.TrimSpace(version), ".")
+	major, err := strconv.Atoi(majorPart)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid DSL version %q", version)
+	}
+
+	minor := 0
+	if minorPart != "" {
+		if minor, err = strconv.Atoi(minorPart); err != nil {
+			return 0, 0, fmt.Errorf("invalid DSL version %q", version)
+		}
+	}
+	return major, minor, nil
+}
+
+// ConditionLanguageKey is the conditions key naming the language a rule's
+// expressions are written in; ConditionTestsKey holds its test fixtures
+const (
+	ConditionLanguageKey = "language"
+	ConditionTestsKey    = "tests"
+)
+
+// ConditionLanguage returns the language a rule's condition expressions are
+// written in, or the empty string for the engine's default language
+func ConditionLanguage(conditions map[string]interface{}) string {
+	language, _ := conditions[ConditionLanguageKey].(string)
+	return language
+}
+
+// ConditionExpressions returns every expression in a rule's conditions,
+// including those nested in condition groups, in a stable order. Test
+// fixtures are data, not conditions, and are skipped.
+func ConditionExpressions(conditions map[string]interface{}) []string {
+	var expressions []string
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if key == ConditionTestsKey {
+					continue
+				}
+				if expression, ok := v[key].(string); ok && key == "expression" {
+					expressions = append(expressions, expression)
+					continue
+				}
+				walk(v[key])
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(conditions)
+	return expressions
+}
+
+// References returns the lookup tables and thresholds named by the expressions
+func References(expressions []string) (lookups []string, thresholds []string) {
+	seenLookups := make(map[string]bool)
+	seenThresholds := make(map[string]bool)
+
+	for _, expression := range expressions {
+		for _, match := range referencePattern.FindAllStringSubmatch(expression, -1) {
+			name := match[2]
+			if match[1] == "in_lookup" && !seenLookups[name] {
+				seenLookups[name] = true
+				lookups = append(lookups, name)
+			} else if match[1] == "threshold" && !seenThresholds[name] {
+				seenThresholds[name] = true
+				thresholds = append(thresholds, name)
+			}
+		}
+	}
+	return lookups, thresholds
+}
+
+// RenameReference rewrites calls of function naming oldName to name newName
+func RenameReference(expression, function, oldName, newName string) string {
+	return referencePattern.ReplaceAllStringFunc(expression, func(call string) string {
+		match := referencePattern.FindStringSubmatch(call)
+		if match[1] != function || match[2] != oldName {
+			return call
+		}
+		return strings.Replace(call, match[2], newName, 1)
+	})
+}
+
+// rewriteExpressions returns a copy of conditions with every expression passed through fn
+func rewriteExpressions(conditions map[string]interface{}, fn func(string) string) map[string]interface{} {
+	var rewrite func(value interface{}) interface{}
+	rewrite = func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			out := make(map[string]interface{}, len(v))
+			for key, item := range v {
+				if expression, ok := item.(string); ok && key == "expression" {
+					out[key] = fn(expression)
+					continue
+				}
+				out[key] = rewrite(item)
+			}
+			return out
+		case []interface{}:
+			out := make([]interface{}, len(v))
+			for i, item := range v {
+				out[i] = rewrite(item)
+			}
+			return out
+		default:
+			return v
+		}
+	}
+
+	out, _ := rewrite(conditions).(map[string]interface{})
+	return out
+}
+
+// RenamedName returns the name given to an item renamed on import. The first
+// attempt tags the name with the pack, later attempts number it.
+func RenamedName(name, packName string, attempt int) string {
+	if attempt <= 1 {
+		return fmt.Sprintf("%s (%s)", name, packName)
+	}
+	return fmt.Sprintf("%s (%s %d)", name, packName, attempt)
+}
+
+// RenamedReference returns the name given to a lookup table or threshold
+// renamed on import. Conditions quote these names, so the pack name is
+// reduced to identifier characters.
+func RenamedReference(name, packName string, attempt int) string {
+	suffix := strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(packName), "_"), "_")
+	if attempt > 1 {
+		suffix = fmt.Sprintf("%s_%d", suffix, attempt)
+	}
+	return name + "__" + suffix
+}
@@ -0,0 +1,761 @@
+package rulepack
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// maxRenameAttempts bounds the search for a free name when renaming on import
+const maxRenameAttempts = 50
+
+var (
+	// ErrSigningKeyMissing is returned when exports are requested without a configured signing key
+	ErrSigningKeyMissing = errors.New("rule pack signing key is not configured")
+	// ErrIncompatiblePack is returned when a pack fails validation and cannot be imported
+	ErrIncompatiblePack = errors.New("rule pack failed validation")
+	// ErrInvalidImportOptions is returned when import options are missing or unknown
+	ErrInvalidImportOptions = errors.New("invalid rule pack import options")
+	// ErrNoRulesSelected is returned when an export selects neither all rules, rules nor tags
+	ErrNoRulesSelected = errors.New("all, rule_ids or tags are required to select rules for export")
+)
+
+// Import item outcomes
+const (
+	OutcomeCreated = "created"
+	OutcomeUpdated = "updated"
+	OutcomeSkipped = "skipped"
+	OutcomeRenamed = "renamed"
+	OutcomeReused  = "reused"
+	OutcomeFailed  = "failed"
+)
+
+// Service exports rules as signed packs, imports packs from other
+// deployments, and serves the lookup tables and thresholds rule conditions read
+type Service struct {
+	config   *config.Config
+	logger   *slog.Logger
+	ruleRepo *database.RuleRepository
+	repo     *database.RulePackRepository
+	compiler Compiler
+
+	mu    sync.RWMutex
+	index *ReferenceIndex
+}
+
+// ExportRequest selects the rules to export and describes the pack
+type ExportRequest struct {
+	Name        string                 `json:"name"`
+	Version     string                 `json:"version"`
+	Description string                 `json:"description"`
+	All         bool                   `json:"all"` // every rule, enabled or not
+	RuleIDs     []string               `json:"rule_ids"`
+	Tags        []string               `json:"tags"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	ExportedBy  string                 `json:"exported_by"`
+}
+
+// ImportOptions controls how pack items are applied
+type ImportOptions struct {
+	ConflictMode string `json:"conflict_mode"`
+	ImportedBy   string `json:"imported_by"`
+	// EnableRules enables newly created rules; by default they arrive disabled
+	// for review. Overwritten rules keep their current enabled state.
+	EnableRules bool `json:"enable_rules"`
+	// DryRun validates the pack and reports what importing it would do
+	// without changing anything
+	DryRun bool `json:"dry_run"`
+}
+
+// ImportResult summarizes an import and the outcome of every pack item. The
+// counts cover rules; lookup table and threshold outcomes are listed in Items.
+type ImportResult struct {
+	ImportID   string            `json:"import_id,omitempty"`
+	DryRun     bool              `json:"dry_run"`
+	Validation *ValidationReport `json:"validation"`
+	Items      []*ImportItem     `json:"items"`
+	Created    int               `json:"created"`
+	Updated    int               `json:"updated"`
+	Skipped    int               `json:"skipped"`
+	Renamed    int               `json:"renamed"`
+	Failed     int               `json:"failed"`
+}
+
+// ImportItem is the outcome of importing one pack item
+type ImportItem struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Outcome    string `json:"outcome"`
+	TargetID   string `json:"target_id,omitempty"`
+	TargetName string `json:"target_name,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// NewService creates a new rule pack service
+func NewService(cfg *config.Config, logger *slog.Logger, ruleRepo *database.RuleRepository,
+	repo *database.RulePackRepository, compiler Compiler) *Service {
+	return &Service{
+		config:   cfg,
+		logger:   logger,
+		ruleRepo: ruleRepo,
+		repo:     repo,
+		compiler: compiler,
+		index:    NewReferenceIndex(nil, nil),
+	}
+}
+
+// Refresh reloads the lookup tables and thresholds served to rule conditions
+func (s *Service) Refresh(ctx context.Context) error {
+	tables, err := s.repo.ListLookupTables(ctx)
+	if err != nil {
+		return err
+	}
+	thresholds, err := s.repo.ListThresholds(ctx)
+	if err != nil {
+		return err
+	}
+
+	index := NewReferenceIndex(tables, thresholds)
+
+	s.mu.Lock()
+	s.index = index
+	s.mu.Unlock()
+
+	s.logger.Info("Rule reference data loaded", "lookup_tables", len(tables), "thresholds", len(thresholds))
+	return nil
+}
+
+// InLookup reports whether value is an entry of the named lookup table
+func (s *Service) InLookup(table, value string) bool {
+	return s.currentIndex().InLookup(table, value)
+}
+
+// Threshold returns the value of a named threshold
+func (s *Service) Threshold(name string) (float64, bool) {
+	return s.currentIndex().Threshold(name)
+}
+
+func (s *Service) currentIndex() *ReferenceIndex {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.index
+}
+
+// Export builds a signed pack from the selected rules and the lookup tables
+// and thresholds their conditions reference
+func (s *Service) Export(ctx context.Context, req ExportRequest) (*Pack, error) {
+	cfg := s.config.RulePack
+	secret := cfg.SigningKeys[cfg.SigningKeyID]
+	if cfg.SigningKeyID == "" || secret == "" {
+		return nil, ErrSigningKeyMissing
+	}
+	if req.Name == "" || req.Version == "" {
+		return nil, fmt.Errorf("pack name and version are required")
+	}
+
+	rules, err := s.selectRules(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxRules > 0 && len(rules) > cfg.MaxRules {
+		return nil, fmt.Errorf("selection contains %d rules, more than the limit of %d", len(rules), cfg.MaxRules)
+	}
+
+	pack := &Pack{
+		FormatVersion:    FormatVersion,
+		Name:             req.Name,
+		Version:          req.Version,
+		Description:      req.Description,
+		DSLVersion:       s.compiler.DSLVersion(),
+		SourceDeployment: cfg.DeploymentID,
+		ExportedBy:       req.ExportedBy,
+		ExportedAt:       time.Now().UTC(),
+		Metadata:         req.Metadata,
+		Rules:            make([]PackRule, 0, len(rules)),
+	}
+
+	var expressions []string
+	for _, rule := range rules {
+		pack.Rules = append(pack.Rules, NewPackRule(rule))
+		expressions = append(expressions, ConditionExpressions(rule.Conditions)...)
+	}
+
+	lookups, thresholds := References(expressions)
+	for _, name := range lookups {
+		table, err := s.repo.GetLookupTable(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if table == nil {
+			return nil, fmt.Errorf("exported rules reference unknown lookup table %q", name)
+		}
+		pack.LookupTables = append(pack.LookupTables, PackLookupTable{
+			Name:        table.Name,
+			Description: stringValue(table.Description),
+			Entries:     table.Entries,
+		})
+	}
+	for _, name := range thresholds {
+		threshold, err := s.repo.GetThreshold(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if threshold == nil {
+			return nil, fmt.Errorf("exported rules reference unknown threshold %q", name)
+		}
+		pack.Thresholds = append(pack.Thresholds, PackThreshold{
+			Name:        threshold.Name,
+			Value:       threshold.Value,
+			Description: stringValue(threshold.Description),
+		})
+	}
+
+	if err := Sign(pack, cfg.SigningKeyID, secret); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Rule pack exported",
+		"pack_name", pack.Name,
+		"pack_version", pack.Version,
+		"rules", len(pack.Rules),
+		"lookup_tables", len(pack.LookupTables),
+		"thresholds", len(pack.Thresholds),
+		"exported_by", req.ExportedBy)
+	return pack, nil
+}
+
+func (s *Service) selectRules(ctx context.Context, req ExportRequest) ([]*database.Rule, error) {
+	var rules []*database.Rule
+	switch {
+	case req.All:
+		all, _, err := s.ruleRepo.List(ctx, database.Filter{})
+		if err != nil {
+			return nil, err
+		}
+		rules = all
+	case len(req.RuleIDs) > 0:
+		for _, id := range req.RuleIDs {
+			rule, err := s.ruleRepo.GetByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, rule)
+		}
+	case len(req.Tags) > 0:
+		tagged, err := s.ruleRepo.ListByTags(ctx, req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		rules = tagged
+	default:
+		return nil, ErrNoRulesSelected
+	}
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no rules matched the export selection")
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+	return rules, nil
+}
+
+// Validate checks whether a pack can be imported: its signature, DSL
+// compatibility, conditions and references, and which items conflict with
+// existing ones
+func (s *Service) Validate(ctx context.Context, pack *Pack) (*ValidationReport, error) {
+	cfg := s.config.RulePack
+	report := Validate(pack, s.compiler, s.currentIndex(), cfg.MaxRules)
+
+	if pack.Signature != nil {
+		report.Signed = true
+		report.SignatureKeyID = pack.Signature.KeyID
+	}
+	switch err := Verify(pack, cfg.SigningKeys); {
+	case err == nil:
+		report.SignatureValid = true
+	case errors.Is(err, ErrUnsigned):
+		if cfg.RequireSignature {
+			report.addError("pack", "%s", err.Error())
+		} else {
+			report.addWarning("pack", "%s", err.Error())
+		}
+	default:
+		report.addError("pack", "%s", err.Error())
+	}
+
+	for _, rule := range pack.Rules {
+		existing, err := s.findRule(ctx, rule.Name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			report.Conflicts = append(report.Conflicts, &Conflict{Kind: KindRule, Name: rule.Name, ExistingID: existing.ID})
+		}
+	}
+	for _, table := range pack.LookupTables {
+		existing, err := s.repo.GetLookupTable(ctx, table.Name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && !sameEntries(existing.Entries, table.Entries) {
+			report.Conflicts = append(report.Conflicts, &Conflict{Kind: KindLookupTable, Name: table.Name, ExistingID: existing.ID})
+		}
+	}
+	for _, threshold := range pack.Thresholds {
+		existing, err := s.repo.GetThreshold(ctx, threshold.Name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.Value != threshold.Value {
+			report.Conflicts = append(report.Conflicts, &Conflict{Kind: KindThreshold, Name: threshold.Name, ExistingID: existing.ID})
+		}
+	}
+
+	report.Compatible = len(report.Errors) == 0
+	return report, nil
+}
+
+// Import validates a pack and applies it. Lookup tables and thresholds are
+// applied first so renamed ones can be rewritten into the pack's conditions.
+// Every imported rule records where it came from under its provenance metadata.
+// A dry run reports the outcome each item would have and records nothing.
+func (s *Service) Import(ctx context.Context, pack *Pack, opts ImportOptions) (*ImportResult, error) {
+	if opts.ConflictMode == "" {
+		opts.ConflictMode = s.config.RulePack.DefaultConflictMode
+	}
+	switch opts.ConflictMode {
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return nil, fmt.Errorf("%w: conflict mode %q, expected skip, overwrite or rename", ErrInvalidImportOptions, opts.ConflictMode)
+	}
+	if opts.ImportedBy == "" {
+		return nil, fmt.Errorf("%w: imported_by is required", ErrInvalidImportOptions)
+	}
+
+	report, err := s.Validate(ctx, pack)
+	if err != nil {
+		return nil, err
+	}
+	result := &ImportResult{DryRun: opts.DryRun, Validation: report, Items: []*ImportItem{}}
+	if !report.Compatible {
+		return result, ErrIncompatiblePack
+	}
+
+	importID := generateID("rule_pack_import")
+	importedAt := time.Now().UTC()
+	sourcePack := pack.Name + "@" + pack.Version
+
+	lookupRenames := make(map[string]string)
+	for _, table := range pack.LookupTables {
+		item, renamed := s.importLookupTable(ctx, table, pack.Name, sourcePack, opts)
+		if renamed != "" {
+			lookupRenames[table.Name] = renamed
+		}
+		result.add(item)
+	}
+
+	thresholdRenames := make(map[string]string)
+	for _, threshold := range pack.Thresholds {
+		item, renamed := s.importThreshold(ctx, threshold, pack.Name, sourcePack, opts)
+		if renamed != "" {
+			thresholdRenames[threshold.Name] = renamed
+		}
+		result.add(item)
+	}
+
+	provenance := map[string]interface{}{
+		"import_id":           importID,
+		"pack_name":           pack.Name,
+		"pack_version":        pack.Version,
+		"pack_dsl_version":    pack.DSLVersion,
+		"source_deployment":   pack.SourceDeployment,
+		"signed":              report.SignatureValid,
+		"signature_key_id":    report.SignatureKeyID,
+		"imported_by":         opts.ImportedBy,
+		"imported_at":         importedAt.Format(time.RFC3339),
+		"conflict_resolution": opts.ConflictMode,
+	}
+
+	for _, packRule := range pack.Rules {
+		rule := s.buildRule(packRule, provenance, lookupRenames, thresholdRenames, opts)
+		result.add(s.importRule(ctx, rule, pack.Name, opts))
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if len(pack.LookupTables) > 0 || len(pack.Thresholds) > 0 {
+		if err := s.Refresh(ctx); err != nil {
+			s.logger.Error("Failed to reload rule reference data after import", "import_id", importID, "error", err)
+		}
+	}
+
+	record := &database.RulePackImport{
+		ID:           importID,
+		PackName:     pack.Name,
+		PackVersion:  pack.Version,
+		DSLVersion:   pack.DSLVersion,
+		Signed:       report.SignatureValid,
+		ConflictMode: opts.ConflictMode,
+		RulesCreated: result.Created,
+		RulesUpdated: result.Updated,
+		RulesSkipped: result.Skipped,
+		RulesRenamed: result.Renamed,
+		Result:       database.JSONB{"items": result.Items, "warnings": report.Warnings},
+		ImportedBy:   opts.ImportedBy,
+		ImportedAt:   importedAt,
+	}
+	if pack.SourceDeployment != "" {
+		record.SourceDeployment = &pack.SourceDeployment
+	}
+	if report.SignatureKeyID != "" {
+		record.SignatureKeyID = &report.SignatureKeyID
+	}
+	if err := s.repo.CreateImport(ctx, record); err != nil {
+		return result, err
+	}
+
+	result.ImportID = importID
+	return result, nil
+}
+
+// ListImports returns recent rule pack imports
+func (s *Service) ListImports(ctx context.Context, limit int) ([]*database.RulePackImport, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	return s.repo.ListImports(ctx, limit)
+}
+
+func (s *Service) importLookupTable(ctx context.Context, table PackLookupTable, packName, sourcePack string, opts ImportOptions) (*ImportItem, string) {
+	item := &ImportItem{Kind: KindLookupTable, Name: table.Name, TargetName: table.Name}
+
+	existing, err := s.repo.GetLookupTable(ctx, table.Name)
+	if err != nil {
+		return item.fail(err), ""
+	}
+
+	renamed := ""
+	switch {
+	case existing == nil:
+		item.Outcome = OutcomeCreated
+	case sameEntries(existing.Entries, table.Entries):
+		item.Outcome = OutcomeReused
+		item.TargetID = existing.ID
+		return item, ""
+	case opts.ConflictMode == ConflictSkip:
+		item.Outcome = OutcomeSkipped
+		item.TargetID = existing.ID
+		item.Message = "existing lookup table with different entries kept"
+		return item, ""
+	case opts.ConflictMode == ConflictOverwrite:
+		item.Outcome = OutcomeUpdated
+	case opts.ConflictMode == ConflictRename:
+		name, err := s.freeReferenceName(table.Name, packName, func(candidate string) (bool, error) {
+			found, err := s.repo.GetLookupTable(ctx, candidate)
+			return found != nil, err
+		})
+		if err != nil {
+			return item.fail(err), ""
+		}
+		item.Outcome = OutcomeRenamed
+		item.TargetName = name
+		renamed = name
+	}
+
+	if opts.DryRun {
+		if existing != nil && renamed == "" {
+			item.TargetID = existing.ID
+		}
+		return item, renamed
+	}
+
+	stored := &database.LookupTable{
+		ID:         generateID("lookup"),
+		Name:       item.TargetName,
+		Entries:    table.Entries,
+		SourcePack: &sourcePack,
+		CreatedBy:  opts.ImportedBy,
+		UpdatedBy:  opts.ImportedBy,
+	}
+	if table.Description != "" {
+		stored.Description = &table.Description
+	}
+	if err := s.repo.UpsertLookupTable(ctx, stored); err != nil {
+		return item.fail(err), ""
+	}
+
+	item.TargetID = stored.ID
+	if existing != nil && renamed == "" {
+		item.TargetID = existing.ID
+	}
+	return item, renamed
+}
+
+func (s *Service) importThreshold(ctx context.Context, threshold PackThreshold, packName, sourcePack string, opts ImportOptions) (*ImportItem, string) {
+	item := &ImportItem{Kind: KindThreshold, Name: threshold.Name, TargetName: threshold.Name}
+
+	existing, err := s.repo.GetThreshold(ctx, threshold.Name)
+	if err != nil {
+		return item.fail(err), ""
+	}
+
+	renamed := ""
+	switch {
+	case existing == nil:
+		item.Outcome = OutcomeCreated
+	case existing.Value == threshold.Value:
+		item.Outcome = OutcomeReused
+		item.TargetID = existing.ID
+		return item, ""
+	case opts.ConflictMode == ConflictSkip:
+		item.Outcome = OutcomeSkipped
+		item.TargetID = existing.ID
+		item.Message = fmt.Sprintf("existing threshold value %v kept", existing.Value)
+		return item, ""
+	case opts.ConflictMode == ConflictOverwrite:
+		item.Outcome = OutcomeUpdated
+	case opts.ConflictMode == ConflictRename:
+		name, err := s.freeReferenceName(threshold.Name, packName, func(candidate string) (bool, error) {
+			found, err := s.repo.GetThreshold(ctx, candidate)
+			return found != nil, err
+		})
+		if err != nil {
+			return item.fail(err), ""
+		}
+		item.Outcome = OutcomeRenamed
+		item.TargetName = name
+		renamed = name
+	}
+
+	if opts.DryRun {
+		if existing != nil && renamed == "" {
+			item.TargetID = existing.ID
+		}
+		return item, renamed
+	}
+
+	stored := &database.RuleThreshold{
+		ID:         generateID("threshold"),
+		Name:       item.TargetName,
+		Value:      threshold.Value,
+		SourcePack: &sourcePack,
+		CreatedBy:  opts.ImportedBy,
+		UpdatedBy:  opts.ImportedBy,
+	}
+	if threshold.Description != "" {
+		stored.Description = &threshold.Description
+	}
+	if err := s.repo.UpsertThreshold(ctx, stored); err != nil {
+		return item.fail(err), ""
+	}
+
+	item.TargetID = stored.ID
+	if existing != nil && renamed == "" {
+		item.TargetID = existing.ID
+	}
+	return item, renamed
+}
+
+// buildRule converts a pack rule for storage, rewriting renamed references
+// and recording its provenance
+func (s *Service) buildRule(packRule PackRule, provenance map[string]interface{},
+	lookupRenames, thresholdRenames map[string]string, opts ImportOptions) *database.Rule {
+	conditions := packRule.Conditions
+	if len(lookupRenames) > 0 || len(thresholdRenames) > 0 {
+		conditions = rewriteExpressions(conditions, func(expression string) string {
+			for oldName, newName := range lookupRenames {
+				expression = RenameReference(expression, "in_lookup", oldName, newName)
+			}
+			for oldName, newName := range thresholdRenames {
+				expression = RenameReference(expression, "threshold", oldName, newName)
+			}
+			return expression
+		})
+	}
+
+	metadata := make(map[string]interface{}, len(packRule.Metadata)+1)
+	for key, value := range packRule.Metadata {
+		metadata[key] = value
+	}
+	ruleProvenance := make(map[string]interface{}, len(provenance)+1)
+	for key, value := range provenance {
+		ruleProvenance[key] = value
+	}
+	ruleProvenance["original_rule_id"] = packRule.ID
+	metadata[provenanceKey] = ruleProvenance
+
+	return &database.Rule{
+		ID:                   generateID("rule"),
+		Name:                 packRule.Name,
+		Description:          packRule.Description,
+		Type:                 packRule.Type,
+		Severity:             packRule.Severity,
+		Priority:             packRule.Priority,
+		Enabled:              opts.EnableRules,
+		Conditions:           conditions,
+		Actions:              packRule.Actions,
+		Tags:                 packRule.Tags,
+		Metadata:             metadata,
+		ThrottleWindow:       packRule.ThrottleWindow,
+		EvaluationWindow:     packRule.EvaluationWindow,
+		GroupBy:              packRule.GroupBy,
+		NotificationChannels: packRule.NotificationChannels,
+		CreatedBy:            opts.ImportedBy,
+		UpdatedBy:            opts.ImportedBy,
+	}
+}
+
+func (s *Service) importRule(ctx context.Context, rule *database.Rule, packName string, opts ImportOptions) *ImportItem {
+	item := &ImportItem{Kind: KindRule, Name: rule.Name, TargetName: rule.Name}
+
+	existing, err := s.findRule(ctx, rule.Name)
+	if err != nil {
+		return item.fail(err)
+	}
+
+	if existing != nil {
+		switch opts.ConflictMode {
+		case ConflictSkip:
+			item.Outcome = OutcomeSkipped
+			item.TargetID = existing.ID
+			item.Message = "rule with the same name already exists"
+			return item
+		case ConflictOverwrite:
+			rule.ID = existing.ID
+			rule.Enabled = existing.Enabled
+			rule.EscalationPolicy = existing.EscalationPolicy
+			rule.CreatedBy = existing.CreatedBy
+			item.Outcome = OutcomeUpdated
+			item.TargetID = rule.ID
+			if opts.DryRun {
+				return item
+			}
+			if err := s.ruleRepo.Update(ctx, rule); err != nil {
+				return item.fail(err)
+			}
+			return item
+		case ConflictRename:
+			name, err := s.freeRuleName(ctx, rule.Name, packName)
+			if err != nil {
+				return item.fail(err)
+			}
+			rule.Name = name
+			item.TargetName = name
+		}
+	}
+
+	item.Outcome = OutcomeCreated
+	if existing != nil {
+		item.Outcome = OutcomeRenamed
+	}
+	if opts.DryRun {
+		return item
+	}
+
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return item.fail(err)
+	}
+	item.TargetID = rule.ID
+	return item
+}
+
+// findRule returns the rule with the given name, or nil when there is none
+func (s *Service) findRule(ctx context.Context, name string) (*database.Rule, error) {
+	rule, err := s.ruleRepo.GetByName(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return rule, err
+}
+
+func (s *Service) freeRuleName(ctx context.Context, name, packName string) (string, error) {
+	for attempt := 1; attempt <= maxRenameAttempts; attempt++ {
+		candidate := RenamedName(name, packName, attempt)
+		existing, err := s.findRule(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no free name found for rule %q", name)
+}
+
+func (s *Service) freeReferenceName(name, packName string, exists func(string) (bool, error)) (string, error) {
+	for attempt := 1; attempt <= maxRenameAttempts; attempt++ {
+		candidate := RenamedReference(name, packName, attempt)
+		taken, err := exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no free name found for %q", name)
+}
+
+func (r *ImportResult) add(item *ImportItem) {
+	r.Items = append(r.Items, item)
+	if item.Kind != KindRule {
+		if item.Outcome == OutcomeFailed {
+			r.Failed++
+		}
+		return
+	}
+
+	switch item.Outcome {
+	case OutcomeCreated:
+		r.Created++
+	case OutcomeUpdated:
+		r.Updated++
+	case OutcomeSkipped:
+		r.Skipped++
+	case OutcomeRenamed:
+		r.Renamed++
+	case OutcomeFailed:
+		r.Failed++
+	}
+}
+
+func (i *ImportItem) fail(err error) *ImportItem {
+	i.Outcome = OutcomeFailed
+	i.Message = err.Error()
+	return i
+}
+
+// sameEntries reports whether two lookup tables hold the same set of entries
+func sameEntries(a, b []string) bool {
+	set := make(map[string]bool, len(a))
+	for _, entry := range a {
+		set[entry] = true
+	}
+	other := make(map[string]bool, len(b))
+	for _, entry := range b {
+		if !set[entry] {
+			return false
+		}
+		other[entry] = true
+	}
+	return len(set) == len(other)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
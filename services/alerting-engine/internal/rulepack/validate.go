@@ -0,0 +1,161 @@
+package rulepack
+
+import (
+	"fmt"
+)
+
+// Compiler checks rule conditions against the running engine's condition
+// languages and runs the test fixtures rules carry
+type Compiler interface {
+	DSLVersion() string
+	CompileExpression(language, expression string) error
+	RunFixtures(conditions map[string]interface{}) error
+}
+
+// ValidationReport describes whether a pack can be imported into this deployment
+type ValidationReport struct {
+	Compatible       bool               `json:"compatible"`
+	PackName         string             `json:"pack_name"`
+	PackVersion      string             `json:"pack_version"`
+	PackDSLVersion   string             `json:"pack_dsl_version"`
+	EngineDSLVersion string             `json:"engine_dsl_version"`
+	Signed           bool               `json:"signed"`
+	SignatureKeyID   string             `json:"signature_key_id,omitempty"`
+	SignatureValid   bool               `json:"signature_valid"`
+	Errors           []*ValidationIssue `json:"errors"`
+	Warnings         []*ValidationIssue `json:"warnings"`
+	Conflicts        []*Conflict        `json:"conflicts"`
+}
+
+// ValidationIssue is a problem found with one pack item
+type ValidationIssue struct {
+	Item    string `json:"item"`
+	Message string `json:"message"`
+}
+
+// Conflict is a pack item whose name already exists in this deployment
+type Conflict struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	ExistingID string `json:"existing_id"`
+}
+
+// Pack item kinds
+const (
+	KindRule        = "rule"
+	KindLookupTable = "lookup_table"
+	KindThreshold   = "threshold"
+)
+
+func (r *ValidationReport) addError(item, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, &ValidationIssue{Item: item, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *ValidationReport) addWarning(item, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, &ValidationIssue{Item: item, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks a pack's structure, its DSL version against the engine,
+// that every condition compiles and passes the rule's test fixtures, and that every lookup table and threshold a
+// condition names is either carried in the pack or already known to this
+// deployment. Signature and conflict checks need deployment state and are
+// added by the service.
+func Validate(pack *Pack, compiler Compiler, known *ReferenceIndex, maxRules int) *ValidationReport {
+	report := &ValidationReport{
+		PackName:         pack.Name,
+		PackVersion:      pack.Version,
+		PackDSLVersion:   pack.DSLVersion,
+		EngineDSLVersion: compiler.DSLVersion(),
+		Errors:           []*ValidationIssue{},
+		Warnings:         []*ValidationIssue{},
+		Conflicts:        []*Conflict{},
+	}
+
+	if pack.FormatVersion != FormatVersion {
+		report.addError("pack", "unsupported pack format version %q", pack.FormatVersion)
+	}
+	if pack.Name == "" || pack.Version == "" {
+		report.addError("pack", "pack name and version are required")
+	}
+	if len(pack.Rules) == 0 {
+		report.addError("pack", "pack contains no rules")
+	}
+	if maxRules > 0 && len(pack.Rules) > maxRules {
+		report.addError("pack", "pack contains %d rules, more than the limit of %d", len(pack.Rules), maxRules)
+	}
+	if err := CheckDSLCompatibility(pack.DSLVersion, report.EngineDSLVersion); err != nil {
+		report.addError("pack", "%s", err.Error())
+	}
+
+	packLookups := make(map[string]bool, len(pack.LookupTables))
+	for _, table := range pack.LookupTables {
+		item := KindLookupTable + ":" + table.Name
+		if table.Name == "" {
+			report.addError(item, "lookup table name is required")
+			continue
+		}
+		if packLookups[table.Name] {
+			report.addError(item, "lookup table appears more than once")
+		}
+		packLookups[table.Name] = true
+	}
+
+	packThresholds := make(map[string]bool, len(pack.Thresholds))
+	for _, threshold := range pack.Thresholds {
+		item := KindThreshold + ":" + threshold.Name
+		if threshold.Name == "" {
+			report.addError(item, "threshold name is required")
+			continue
+		}
+		if packThresholds[threshold.Name] {
+			report.addError(item, "threshold appears more than once")
+		}
+		packThresholds[threshold.Name] = true
+	}
+
+	ruleNames := make(map[string]bool, len(pack.Rules))
+	for _, rule := range pack.Rules {
+		item := KindRule + ":" + rule.Name
+		if rule.Name == "" {
+			report.addError(item, "rule name is required")
+			continue
+		}
+		if ruleNames[rule.Name] {
+			report.addError(item, "rule name appears more than once")
+		}
+		ruleNames[rule.Name] = true
+
+		expressions := ConditionExpressions(rule.Conditions)
+		if len(expressions) == 0 {
+			report.addWarning(item, "rule has no condition expressions")
+		}
+		language := ConditionLanguage(rule.Conditions)
+		compiles := true
+		for i, expression := range expressions {
+			if err := compiler.CompileExpression(language, expression); err != nil {
+				report.addError(item, "condition %d does not compile: %v", i, err)
+				compiles = false
+			}
+		}
+		if compiles {
+			if err := compiler.RunFixtures(rule.Conditions); err != nil {
+				report.addError(item, "%v", err)
+			}
+		}
+
+		lookups, thresholds := References(expressions)
+		for _, name := range lookups {
+			if !packLookups[name] && !known.HasLookup(name) {
+				report.addError(item, "references unknown lookup table %q", name)
+			}
+		}
+		for _, name := range thresholds {
+			if !packThresholds[name] && !known.HasThreshold(name) {
+				report.addError(item, "references unknown threshold %q", name)
+			}
+		}
+	}
+
+	report.Compatible = len(report.Errors) == 0
+	return report
+}
@@ -0,0 +1,54 @@
+package rulepack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidYAML is returned when a YAML rule bundle cannot be read as a pack
+var ErrInvalidYAML = errors.New("invalid YAML rule bundle")
+
+// EncodeYAML writes a pack as YAML for keeping rule sets in version control.
+// The pack goes through its JSON form, so the YAML carries the same field
+// names as JSON packs, with keys in a stable order that keeps diffs small,
+// and a signed pack still verifies once read back.
+func EncodeYAML(pack *Pack) ([]byte, error) {
+	body, err := json.Marshal(pack)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rule pack: %w", err)
+	}
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("failed to encode rule pack: %w", err)
+	}
+
+	out, err := yaml.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rule pack as YAML: %w", err)
+	}
+	return out, nil
+}
+
+// DecodeYAML reads a pack written by EncodeYAML or by hand
+func DecodeYAML(data []byte) (*Pack, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidYAML, err)
+	}
+	if _, ok := document.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("%w: expected a mapping at the top level", ErrInvalidYAML)
+	}
+
+	body, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidYAML, err)
+	}
+	var pack Pack
+	if err := json.Unmarshal(body, &pack); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidYAML, err)
+	}
+	return &pack, nil
+}
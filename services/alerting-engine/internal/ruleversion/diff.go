@@ -0,0 +1,120 @@
+package ruleversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// Kinds of difference between two rule versions
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change is one field that differs between two rule versions. Paths name
+// nested condition, action and metadata fields with dots; lists are compared
+// whole.
+type Change struct {
+	Path   string      `json:"path"`
+	Kind   string      `json:"kind"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// VersionDiff lists what changed from one rule version to another
+type VersionDiff struct {
+	RuleID       string   `json:"rule_id"`
+	FromVersion  int      `json:"from_version"`
+	ToVersion    int      `json:"to_version"`
+	FromChecksum string   `json:"from_checksum"`
+	ToChecksum   string   `json:"to_checksum"`
+	Identical    bool     `json:"identical"`
+	Changes      []Change `json:"changes"`
+}
+
+// Diff compares the definitions stored in two versions of a rule
+func Diff(from, to *database.RuleVersion) (*VersionDiff, error) {
+	before, err := flattenDefinition(from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := flattenDefinition(to)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(before)+len(after))
+	for path := range before {
+		paths = append(paths, path)
+	}
+	for path := range after {
+		if _, ok := before[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	diff := &VersionDiff{
+		RuleID:       to.RuleID,
+		FromVersion:  from.Version,
+		ToVersion:    to.Version,
+		FromChecksum: from.Checksum,
+		ToChecksum:   to.Checksum,
+		Changes:      []Change{},
+	}
+	for _, path := range paths {
+		oldValue, hadOld := before[path]
+		newValue, hasNew := after[path]
+		switch {
+		case !hadOld:
+			diff.Changes = append(diff.Changes, Change{Path: path, Kind: ChangeAdded, After: newValue})
+		case !hasNew:
+			diff.Changes = append(diff.Changes, Change{Path: path, Kind: ChangeRemoved, Before: oldValue})
+		case !reflect.DeepEqual(oldValue, newValue):
+			diff.Changes = append(diff.Changes, Change{Path: path, Kind: ChangeChanged, Before: oldValue, After: newValue})
+		}
+	}
+	diff.Identical = len(diff.Changes) == 0
+
+	return diff, nil
+}
+
+// flattenDefinition maps every leaf of a stored definition to its path.
+// Null leaves are dropped so an unset field and a null one compare equal.
+func flattenDefinition(version *database.RuleVersion) (map[string]interface{}, error) {
+	var definition map[string]interface{}
+	if err := json.Unmarshal(version.Definition, &definition); err != nil {
+		return nil, fmt.Errorf("failed to decode rule version %d: %w", version.Version, err)
+	}
+
+	leaves := make(map[string]interface{})
+	flatten("", definition, leaves)
+	return leaves, nil
+}
+
+func flatten(prefix string, value interface{}, leaves map[string]interface{}) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		if value != nil {
+			leaves[prefix] = value
+		}
+		return
+	}
+
+	if len(object) == 0 && prefix != "" {
+		leaves[prefix] = object
+		return
+	}
+	for key, child := range object {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		flatten(path, child, leaves)
+	}
+}
@@ -0,0 +1,269 @@
+package ruleversion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/rulepack"
+)
+
+var (
+	// ErrAlreadyLive is returned when rolling back to the definition a rule already has
+	ErrAlreadyLive = errors.New("rule version is already live")
+	// ErrNotPriorVersion is returned when rolling back to a version that is not older than the live one
+	ErrNotPriorVersion = errors.New("rollback target must be a prior version")
+	// ErrReasonRequired is returned for rollbacks without a reason
+	ErrReasonRequired = errors.New("a rollback reason is required")
+	// ErrInvalidDefinition is returned when a prior version no longer compiles
+	ErrInvalidDefinition = errors.New("rule version does not compile")
+	// ErrNameTaken is returned when another rule now has the name of the restored version
+	ErrNameTaken = errors.New("rule name of version is taken by another rule")
+)
+
+// RollbackRequest rolls a rule back to a prior version
+type RollbackRequest struct {
+	Version int    `json:"version"`
+	Reason  string `json:"reason"`
+}
+
+// AlertRuleVersion ties an alert to the rule version that raised it
+type AlertRuleVersion struct {
+	AlertID     string                `json:"alert_id"`
+	RuleID      string                `json:"rule_id"`
+	RuleVersion int                   `json:"rule_version"`
+	Version     *database.RuleVersion `json:"version,omitempty"`
+	// SnapshotChecksum is the checksum of the rule definition captured in the
+	// alert's evidence bundle; Matches reports whether it equals the checksum
+	// of the recorded version
+	SnapshotChecksum string `json:"snapshot_checksum"`
+	Matches          bool   `json:"matches"`
+}
+
+// Service serves rule version history, diffs and rollbacks, and proves
+// which rule logic raised an alert
+type Service struct {
+	logger       *slog.Logger
+	ruleRepo     *database.RuleRepository
+	evidenceRepo *database.EvidenceRepository
+	compiler     rulepack.Compiler
+}
+
+// NewService creates a new rule version service
+func NewService(logger *slog.Logger, ruleRepo *database.RuleRepository,
+	evidenceRepo *database.EvidenceRepository, compiler rulepack.Compiler) *Service {
+	return &Service{
+		logger:       logger,
+		ruleRepo:     ruleRepo,
+		evidenceRepo: evidenceRepo,
+		compiler:     compiler,
+	}
+}
+
+// History returns every version of a rule, newest first
+func (s *Service) History(ctx context.Context, ruleID string) ([]*database.RuleVersion, error) {
+	if err := s.recordBaseline(ctx, ruleID); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.ruleRepo.GetVersionHistory(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if versions == nil {
+		versions = []*database.RuleVersion{}
+	}
+	return versions, nil
+}
+
+// Get returns one version of a rule
+func (s *Service) Get(ctx context.Context, ruleID string, version int) (*database.RuleVersion, error) {
+	if err := s.recordBaseline(ctx, ruleID); err != nil {
+		return nil, err
+	}
+	return s.ruleRepo.GetVersion(ctx, ruleID, version)
+}
+
+// Diff compares two versions of a rule
+func (s *Service) Diff(ctx context.Context, ruleID string, fromVersion, toVersion int) (*VersionDiff, error) {
+	if err := s.recordBaseline(ctx, ruleID); err != nil {
+		return nil, err
+	}
+
+	from, err := s.ruleRepo.GetVersion(ctx, ruleID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.ruleRepo.GetVersion(ctx, ruleID, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return Diff(from, to)
+}
+
+// Activations returns the activation history of a rule, newest first
+func (s *Service) Activations(ctx context.Context, ruleID string) ([]*database.RuleActivation, error) {
+	if err := s.recordBaseline(ctx, ruleID); err != nil {
+		return nil, err
+	}
+
+	activations, err := s.ruleRepo.ListActivations(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if activations == nil {
+		activations = []*database.RuleActivation{}
+	}
+	return activations, nil
+}
+
+// Rollback restores the definition of a prior version as a new version of
+// the rule. History is never rewritten: the rollback is itself a version
+// recording the version it restored and why.
+func (s *Service) Rollback(ctx context.Context, ruleID string, req RollbackRequest, rolledBackBy string) (*database.Rule, error) {
+	if req.Reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	current, err := s.ruleRepo.GetByID(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepo.RecordBaseline(ctx, current); err != nil {
+		return nil, err
+	}
+
+	target, err := s.ruleRepo.GetVersion(ctx, ruleID, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := RollbackRule(current, target, s.compiler)
+	if err != nil {
+		return nil, err
+	}
+	if rule.Name != current.Name {
+		if err := s.ruleRepo.ValidateName(ctx, rule.Name, rule.ID); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNameTaken, err)
+		}
+	}
+
+	rule.UpdatedBy = rolledBackBy
+	change := database.RuleChange{
+		Type:          database.RuleChangeRolledBack,
+		SourceVersion: &target.Version,
+		Reason:        &req.Reason,
+		By:            rolledBackBy,
+	}
+	if err := s.ruleRepo.UpdateWithChange(ctx, rule, change); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Rule rolled back",
+		"rule_id", ruleID,
+		"restored_version", target.Version,
+		"new_version", rule.Version,
+		"rolled_back_by", rolledBackBy)
+	return rule, nil
+}
+
+// RollbackRule returns the live rule with the definition of a prior version
+// applied. The rule keeps its identity and enabled state; the restored
+// conditions must still compile and pass their test fixtures.
+func RollbackRule(current *database.Rule, target *database.RuleVersion, compiler rulepack.Compiler) (*database.Rule, error) {
+	if target.Version >= current.Version {
+		return nil, ErrNotPriorVersion
+	}
+
+	_, checksum, err := database.NewRuleDefinition(current).Encode()
+	if err != nil {
+		return nil, err
+	}
+	if checksum == target.Checksum {
+		return nil, ErrAlreadyLive
+	}
+
+	definition, err := target.Decode()
+	if err != nil {
+		return nil, err
+	}
+	language := rulepack.ConditionLanguage(definition.Conditions)
+	for _, expression := range rulepack.ConditionExpressions(definition.Conditions) {
+		if err := compiler.CompileExpression(language, expression); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDefinition, err)
+		}
+	}
+	if err := compiler.RunFixtures(definition.Conditions); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDefinition, err)
+	}
+
+	rule := *current
+	definition.Apply(&rule)
+	return &rule, nil
+}
+
+// ForAlert returns the rule version that raised an alert, checked against
+// the rule snapshot in the alert's evidence bundle. Alerts raised before
+// versioning have no recorded version.
+func (s *Service) ForAlert(ctx context.Context, alertID string) (*AlertRuleVersion, error) {
+	bundle, err := s.evidenceRepo.GetByAlertID(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum, err := SnapshotChecksum(bundle.RuleSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AlertRuleVersion{
+		AlertID:          alertID,
+		RuleID:           bundle.RuleID,
+		RuleVersion:      bundle.RuleVersion,
+		SnapshotChecksum: checksum,
+	}
+
+	version, err := s.ruleRepo.GetVersion(ctx, bundle.RuleID, bundle.RuleVersion)
+	if errors.Is(err, database.ErrRuleVersionNotFound) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Version = version
+	result.Matches = version.Checksum == checksum
+	if !result.Matches {
+		s.logger.Warn("Alert rule snapshot does not match recorded rule version",
+			"alert_id", alertID,
+			"rule_id", bundle.RuleID,
+			"rule_version", bundle.RuleVersion)
+	}
+	return result, nil
+}
+
+// SnapshotChecksum returns the definition checksum of a rule snapshot taken
+// into an evidence bundle
+func SnapshotChecksum(snapshot json.RawMessage) (string, error) {
+	var rule database.Rule
+	if err := json.Unmarshal(snapshot, &rule); err != nil {
+		return "", fmt.Errorf("failed to decode rule snapshot: %w", err)
+	}
+
+	_, checksum, err := database.NewRuleDefinition(&rule).Encode()
+	return checksum, err
+}
+
+// recordBaseline makes sure a rule last written before versioning has its
+// live version recorded
+func (s *Service) recordBaseline(ctx context.Context, ruleID string) error {
+	rule, err := s.ruleRepo.GetByID(ctx, ruleID)
+	if err != nil {
+		return err
+	}
+	return s.ruleRepo.RecordBaseline(ctx, rule)
+}
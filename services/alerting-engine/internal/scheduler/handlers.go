@@ -6,10 +6,20 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/aegis-shield/services/alerting-engine/internal/alertcluster"
+	"github.com/aegis-shield/services/alerting-engine/internal/autoclose"
+	"github.com/aegis-shield/services/alerting-engine/internal/batchdigest"
+	"github.com/aegis-shield/services/alerting-engine/internal/casesync"
 	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/correlation"
 	"github.com/aegis-shield/services/alerting-engine/internal/database"
 	"github.com/aegis-shield/services/alerting-engine/internal/engine"
 	"github.com/aegis-shield/services/alerting-engine/internal/notification"
+	"github.com/aegis-shield/services/alerting-engine/internal/riskwebhook"
+	"github.com/aegis-shield/services/alerting-engine/internal/ruleowner"
+	"github.com/aegis-shield/services/alerting-engine/internal/slo"
+	"github.com/aegis-shield/services/alerting-engine/internal/storm"
+	"github.com/aegis-shield/services/alerting-engine/internal/watchlist"
 )
 
 // AlertCleanupHandler handles cleanup of old alerts
@@ -507,6 +517,529 @@ func (h *PendingNotificationsHandler) GetDescription() string {
 	return "Processes pending notifications that need to be sent"
 }
 
+// WatchlistExpiryHandler enforces scheduled expiry of watchlist and exclusion entries
+type WatchlistExpiryHandler struct {
+	watchlistService *watchlist.Service
+	config           *config.Config
+	logger           *slog.Logger
+}
+
+// NewWatchlistExpiryHandler creates a new watchlist expiry handler
+func NewWatchlistExpiryHandler(watchlistService *watchlist.Service, cfg *config.Config, logger *slog.Logger) *WatchlistExpiryHandler {
+	return &WatchlistExpiryHandler{
+		watchlistService: watchlistService,
+		config:           cfg,
+		logger:           logger,
+	}
+}
+
+// Execute expires overdue entries and lapses unreviewed change requests
+func (h *WatchlistExpiryHandler) Execute(ctx context.Context) error {
+	result, err := h.watchlistService.RunExpiry(ctx)
+	if err != nil {
+		h.logger.Error("Failed to run watchlist expiry", "error", err)
+		return fmt.Errorf("failed to run watchlist expiry: %w", err)
+	}
+
+	if result.ExpiredEntries > 0 || result.LapsedChanges > 0 {
+		h.logger.Info("Watchlist expiry completed",
+			"expired_entries", result.ExpiredEntries,
+			"lapsed_changes", result.LapsedChanges)
+	}
+
+	return nil
+}
+
+// GetName returns the handler name
+func (h *WatchlistExpiryHandler) GetName() string {
+	return "Watchlist Expiry"
+}
+
+// GetDescription returns the handler description
+func (h *WatchlistExpiryHandler) GetDescription() string {
+	return "Expires watchlist and exclusion entries and lapses unreviewed change requests"
+}
+
+// SLOEvaluationHandler recomputes SLO error budgets from Prometheus
+type SLOEvaluationHandler struct {
+	sloService *slo.Service
+	config     *config.Config
+	logger     *slog.Logger
+}
+
+// NewSLOEvaluationHandler creates a new SLO evaluation handler
+func NewSLOEvaluationHandler(sloService *slo.Service, cfg *config.Config, logger *slog.Logger) *SLOEvaluationHandler {
+	return &SLOEvaluationHandler{
+		sloService: sloService,
+		config:     cfg,
+		logger:     logger,
+	}
+}
+
+// Execute evaluates all objectives and publishes budget warnings
+func (h *SLOEvaluationHandler) Execute(ctx context.Context) error {
+	statuses, err := h.sloService.Evaluate(ctx)
+	if err != nil {
+		h.logger.Error("Failed to evaluate SLOs", "error", err)
+		return fmt.Errorf("failed to evaluate SLOs: %w", err)
+	}
+
+	degraded := 0
+	for _, status := range statuses {
+		if status.State != slo.StateOK && status.State != slo.StateNoData {
+			degraded++
+		}
+	}
+
+	h.logger.Debug("SLO evaluation completed",
+		"objectives", len(statuses),
+		"degraded", degraded)
+
+	return nil
+}
+
+// GetName returns the handler name
+func (h *SLOEvaluationHandler) GetName() string {
+	return "SLO Evaluation"
+}
+
+// GetDescription returns the handler description
+func (h *SLOEvaluationHandler) GetDescription() string {
+	return "Computes SLO burn rates and error budgets and warns the ops channel"
+}
+
+// CaseSyncDispatchHandler delivers queued alert lifecycle changes to external case systems
+type CaseSyncDispatchHandler struct {
+	caseSyncService *casesync.Service
+	config          *config.Config
+	logger          *slog.Logger
+}
+
+// NewCaseSyncDispatchHandler creates a new case sync dispatch handler
+func NewCaseSyncDispatchHandler(caseSyncService *casesync.Service, cfg *config.Config, logger *slog.Logger) *CaseSyncDispatchHandler {
+	return &CaseSyncDispatchHandler{
+		caseSyncService: caseSyncService,
+		config:          cfg,
+		logger:          logger,
+	}
+}
+
+// Execute sends due outbox entries
+func (h *CaseSyncDispatchHandler) Execute(ctx context.Context) error {
+	result, err := h.caseSyncService.DispatchPending(ctx)
+	if err != nil {
+		h.logger.Error("Failed to dispatch case sync deliveries", "error", err)
+		return fmt.Errorf("failed to dispatch case sync deliveries: %w", err)
+	}
+
+	if result.Claimed > 0 {
+		h.logger.Debug("Case sync dispatch completed",
+			"claimed", result.Claimed,
+			"delivered", result.Delivered,
+			"failed", result.Failed,
+			"dead", result.Dead)
+	}
+
+	return nil
+}
+
+// GetName returns the handler name
+func (h *CaseSyncDispatchHandler) GetName() string {
+	return "Case Sync Dispatch"
+}
+
+// GetDescription returns the handler description
+func (h *CaseSyncDispatchHandler) GetDescription() string {
+	return "Pushes alert lifecycle changes to external case management systems"
+}
+
+// CaseSyncReconcileHandler compares alert status with external case status
+type CaseSyncReconcileHandler struct {
+	caseSyncService *casesync.Service
+	config          *config.Config
+	logger          *slog.Logger
+}
+
+// NewCaseSyncReconcileHandler creates a new case sync reconciliation handler
+func NewCaseSyncReconcileHandler(caseSyncService *casesync.Service, cfg *config.Config, logger *slog.Logger) *CaseSyncReconcileHandler {
+	return &CaseSyncReconcileHandler{
+		caseSyncService: caseSyncService,
+		config:          cfg,
+		logger:          logger,
+	}
+}
+
+// Execute reconciles every connector with a status URL
+func (h *CaseSyncReconcileHandler) Execute(ctx context.Context) error {
+	runs, err := h.caseSyncService.ReconcileAll(ctx)
+	if err != nil {
+		h.logger.Error("Failed to reconcile case sync connectors", "error", err)
+		return fmt.Errorf("failed to reconcile case sync connectors: %w", err)
+	}
+
+	drifted := 0
+	for _, run := range runs {
+		drifted += run.Drifted
+	}
+
+	h.logger.Info("Case sync reconciliation completed",
+		"connectors", len(runs),
+		"drifted", drifted)
+
+	return nil
+}
+
+// GetName returns the handler name
+func (h *CaseSyncReconcileHandler) GetName() string {
+	return "Case Sync Reconciliation"
+}
+
+// GetDescription returns the handler description
+func (h *CaseSyncReconcileHandler) GetDescription() string {
+	return "Detects and repairs status drift between alerts and external cases"
+}
+
+// BatchDigestHandler runs the end-of-day batch rules and sends portfolio digests
+type BatchDigestHandler struct {
+	batchDigestService *batchdigest.Service
+	config             *config.Config
+	logger             *slog.Logger
+}
+
+// NewBatchDigestHandler creates a new end-of-day batch digest handler
+func NewBatchDigestHandler(batchDigestService *batchdigest.Service, cfg *config.Config, logger *slog.Logger) *BatchDigestHandler {
+	return &BatchDigestHandler{
+		batchDigestService: batchDigestService,
+		config:             cfg,
+		logger:             logger,
+	}
+}
+
+// Execute evaluates the current business day
+func (h *BatchDigestHandler) Execute(ctx context.Context) error {
+	result, err := h.batchDigestService.RunForDate(ctx, "", "scheduler")
+	if err != nil {
+		h.logger.Error("Failed to run end-of-day batch digest", "error", err)
+		return fmt.Errorf("failed to run end-of-day batch digest: %w", err)
+	}
+
+	if result.ErrorMessage != nil {
+		return fmt.Errorf("end-of-day batch digest failed for some portfolios: %s", *result.ErrorMessage)
+	}
+
+	return nil
+}
+
+// GetName returns the handler name
+func (h *BatchDigestHandler) GetName() string {
+	return "End-of-Day Batch Digest"
+}
+
+// GetDescription returns the handler description
+func (h *BatchDigestHandler) GetDescription() string {
+	return "Evaluates batch rules over the day's aggregates and sends one digest per portfolio manager"
+}
+
+// AlertClusterHandler groups related open alerts into investigation bundles
+type AlertClusterHandler struct {
+	alertClusterService *alertcluster.Service
+	config              *config.Config
+	logger              *slog.Logger
+}
+
+// NewAlertClusterHandler creates a new alert clustering handler
+func NewAlertClusterHandler(alertClusterService *alertcluster.Service, cfg *config.Config, logger *slog.Logger) *AlertClusterHandler {
+	return &AlertClusterHandler{
+		alertClusterService: alertClusterService,
+		config:              cfg,
+		logger:              logger,
+	}
+}
+
+// Execute clusters the current open alerts
+func (h *AlertClusterHandler) Execute(ctx context.Context) error {
+	result, err := h.alertClusterService.Run(ctx, "scheduler")
+	if err != nil {
+		h.logger.Error("Failed to run alert clustering", "error", err)
+		return fmt.Errorf("failed to run alert clustering: %w", err)
+	}
+
+	if result.ErrorMessage != nil {
+		return fmt.Errorf("alert clustering failed: %s", *result.ErrorMessage)
+	}
+
+	return nil
+}
+
+// GetName returns the handler name
+func (h *AlertClusterHandler) GetName() string {
+	return "Alert Clustering"
+}
+
+// GetDescription returns the handler description
+func (h *AlertClusterHandler) GetDescription() string {
+	return "Groups open alerts sharing resolved entities or graph communities into proposed investigation bundles"
+}
+
+// CorrelationPruneHandler forgets the correlation keys of old alerts
+type CorrelationPruneHandler struct {
+	correlationService *correlation.Service
+	config             *config.Config
+	logger             *slog.Logger
+}
+
+// NewCorrelationPruneHandler creates a new correlation key prune handler
+func NewCorrelationPruneHandler(correlationService *correlation.Service, cfg *config.Config, logger *slog.Logger) *CorrelationPruneHandler {
+	return &CorrelationPruneHandler{
+		correlationService: correlationService,
+		config:             cfg,
+		logger:             logger,
+	}
+}
+
+// Execute prunes correlation keys past their retention
+func (h *CorrelationPruneHandler) Execute(ctx context.Context) error {
+	if _, err := h.correlationService.PruneKeys(ctx); err != nil {
+		h.logger.Error("Failed to prune alert correlation keys", "error", err)
+		return fmt.Errorf("failed to prune alert correlation keys: %w", err)
+	}
+
+	return nil
+}
+
+// GetName returns the handler name
+func (h *CorrelationPruneHandler) GetName() string {
+	return "Alert Correlation Key Prune"
+}
+
+// GetDescription returns the handler description
+func (h *CorrelationPruneHandler) GetDescription() string {
+	return "Forgets the entities, accounts and communities of alerts too old to correlate with new ones"
+}
+
+// StormSweepHandler ends quiet alert storms and reconciles storms left unreconciled
+type StormSweepHandler struct {
+	stormService *storm.Service
+	config       *config.Config
+	logger       *slog.Logger
+}
+
+// NewStormSweepHandler creates a new alert storm sweep handler
+func NewStormSweepHandler(stormService *storm.Service, cfg *config.Config, logger *slog.Logger) *StormSweepHandler {
+	return &StormSweepHandler{
+		stormService: stormService,
+		config:       cfg,
+		logger:       logger,
+	}
+}
+
+// Execute ends and reconciles alert storms
+func (h *StormSweepHandler) Execute(ctx context.Context) error {
+	result, err := h.stormService.Sweep(ctx)
+	if err != nil {
+		h.logger.Error("Failed to sweep alert storms", "error", err)
+		return fmt.Errorf("failed to sweep alert storms: %w", err)
+	}
+
+	if len(result.Ended) > 0 || len(result.Reconciled) > 0 {
+		h.logger.Info("Alert storm sweep completed",
+			"ended", len(result.Ended),
+			"reconciled", len(result.Reconciled))
+	}
+
+	return nil
+}
+
+// GetName returns the handler name
+func (h *StormSweepHandler) GetName() string {
+	return "Alert Storm Sweep"
+}
+
+// GetDescription returns the handler description
+func (h *StormSweepHandler) GetDescription() string {
+	return "Ends alert storms whose rules have gone quiet and reconciles their held alerts once the analyst window has passed"
+}
+
+// RiskWebhookDispatchHandler sends queued entity risk tier transitions to webhook subscribers
+type RiskWebhookDispatchHandler struct {
+	riskWebhookService *riskwebhook.Service
+	config             *config.Config
+	logger             *slog.Logger
+}
+
+// NewRiskWebhookDispatchHandler creates a new risk webhook dispatch handler
+func NewRiskWebhookDispatchHandler(riskWebhookService *riskwebhook.Service, cfg *config.Config, logger *slog.Logger) *RiskWebhookDispatchHandler {
+	return &RiskWebhookDispatchHandler{
+		riskWebhookService: riskWebhookService,
+		config:             cfg,
+		logger:             logger,
+	}
+}
+
+// Execute sends due outbox entries
+func (h *RiskWebhookDispatchHandler) Execute(ctx context.Context) error {
+	result, err := h.riskWebhookService.DispatchPending(ctx)
+	if err != nil {
+		h.logger.Error("Failed to dispatch risk webhooks", "error", err)
+		return fmt.Errorf("failed to dispatch risk webhooks: %w", err)
+	}
+
+	if result.Claimed > 0 {
+		h.logger.Debug("Risk webhook dispatch completed",
+			"claimed", result.Claimed,
+			"delivered", result.Delivered,
+			"failed", result.Failed,
+			"dead", result.Dead)
+	}
+
+	return nil
+}
+
+// GetName returns the handler name
+func (h *RiskWebhookDispatchHandler) GetName() string {
+	return "Risk Webhook Dispatch"
+}
+
+// GetDescription returns the handler description
+func (h *RiskWebhookDispatchHandler) GetDescription() string {
+	return "Sends entity risk tier transitions to downstream webhook subscribers"
+}
+
+// RuleHealthCheckHandler raises error budget and false-positive trend events for rule owners
+type RuleHealthCheckHandler struct {
+	ruleOwnerService *ruleowner.Service
+	ruleEngine       *engine.RuleEngine
+	config           *config.Config
+	logger           *slog.Logger
+}
+
+// NewRuleHealthCheckHandler creates a new rule health check handler
+func NewRuleHealthCheckHandler(ruleOwnerService *ruleowner.Service, ruleEngine *engine.RuleEngine, cfg *config.Config, logger *slog.Logger) *RuleHealthCheckHandler {
+	return &RuleHealthCheckHandler{
+		ruleOwnerService: ruleOwnerService,
+		ruleEngine:       ruleEngine,
+		config:           cfg,
+		logger:           logger,
+	}
+}
+
+// Execute checks every rule's evaluation errors and dispositions
+func (h *RuleHealthCheckHandler) Execute(ctx context.Context) error {
+	counts := make(map[string]ruleowner.EvaluationCount)
+	for ruleID, metrics := range h.ruleEngine.EvaluationMetrics() {
+		counts[ruleID] = ruleowner.EvaluationCount{
+			RuleName:    metrics.RuleName,
+			Evaluations: metrics.EvaluationCount,
+			Errors:      metrics.ErrorCount,
+		}
+	}
+
+	result, err := h.ruleOwnerService.Check(ctx, counts)
+	if err != nil {
+		h.logger.Error("Failed to check rule health", "error", err)
+		return fmt.Errorf("failed to check rule health: %w", err)
+	}
+
+	if result.ErrorBudgetExceeded > 0 || result.FalsePositiveTrends > 0 {
+		h.logger.Info("Rule health check completed",
+			"error_budget_exceeded", result.ErrorBudgetExceeded,
+			"false_positive_trends", result.FalsePositiveTrends)
+	}
+
+	return nil
+}
+
+// GetName returns the handler name
+func (h *RuleHealthCheckHandler) GetName() string {
+	return "Rule Health Check"
+}
+
+// GetDescription returns the handler description
+func (h *RuleHealthCheckHandler) GetDescription() string {
+	return "Flags rules over their evaluation error budget or trending towards false positives"
+}
+
+// RuleOwnerDigestHandler sends rule owners their digest of rule lifecycle events
+type RuleOwnerDigestHandler struct {
+	ruleOwnerService *ruleowner.Service
+	config           *config.Config
+	logger           *slog.Logger
+}
+
+// NewRuleOwnerDigestHandler creates a new rule owner digest handler
+func NewRuleOwnerDigestHandler(ruleOwnerService *ruleowner.Service, cfg *config.Config, logger *slog.Logger) *RuleOwnerDigestHandler {
+	return &RuleOwnerDigestHandler{
+		ruleOwnerService: ruleOwnerService,
+		config:           cfg,
+		logger:           logger,
+	}
+}
+
+// Execute sends the pending lifecycle events
+func (h *RuleOwnerDigestHandler) Execute(ctx context.Context) error {
+	result, err := h.ruleOwnerService.SendDigests(ctx)
+	if err != nil {
+		h.logger.Error("Failed to send rule owner digests", "error", err)
+		return fmt.Errorf("failed to send rule owner digests: %w", err)
+	}
+
+	if result.Failed > 0 {
+		return fmt.Errorf("%d of %d rule owner digests failed to send", result.Failed, result.Digests)
+	}
+
+	return nil
+}
+
+// GetName returns the handler name
+func (h *RuleOwnerDigestHandler) GetName() string {
+	return "Rule Owner Digest"
+}
+
+// GetDescription returns the handler description
+func (h *RuleOwnerDigestHandler) GetDescription() string {
+	return "Sends each rule owner one digest of their rules' lifecycle events"
+}
+
+// AlertAutoCloseHandler closes stale alerts under the auto-close policies
+type AlertAutoCloseHandler struct {
+	autoCloseService *autoclose.Service
+	config           *config.Config
+	logger           *slog.Logger
+}
+
+// NewAlertAutoCloseHandler creates a new alert auto-close handler
+func NewAlertAutoCloseHandler(autoCloseService *autoclose.Service, cfg *config.Config, logger *slog.Logger) *AlertAutoCloseHandler {
+	return &AlertAutoCloseHandler{
+		autoCloseService: autoCloseService,
+		config:           cfg,
+		logger:           logger,
+	}
+}
+
+// Execute closes the alerts each policy finds stale
+func (h *AlertAutoCloseHandler) Execute(ctx context.Context) error {
+	result, err := h.autoCloseService.Run(ctx)
+	if err != nil {
+		h.logger.Error("Failed to auto-close stale alerts",
+			"closed", result.Closed,
+			"failed", result.Failed,
+			"error", err)
+		return fmt.Errorf("failed to auto-close stale alerts: %w", err)
+	}
+
+	return nil
+}
+
+// GetName returns the handler name
+func (h *AlertAutoCloseHandler) GetName() string {
+	return "Alert Auto-Close"
+}
+
+// GetDescription returns the handler description
+func (h *AlertAutoCloseHandler) GetDescription() string {
+	return "Closes low-value alerts that have gone without activity for longer than their auto-close policy allows"
+}
+
 // Utility functions
 
 func generateHealthAlertID() string {
@@ -2,9 +2,12 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc/codes"
@@ -12,8 +15,11 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/aegis-shield/shared/proto"
+	"github.com/aegis-shield/services/alerting-engine/internal/alertstream"
 	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/correlation"
 	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/dedup"
 	"github.com/aegis-shield/services/alerting-engine/internal/engine"
 	"github.com/aegis-shield/services/alerting-engine/internal/kafka"
 	"github.com/aegis-shield/services/alerting-engine/internal/notification"
@@ -31,6 +37,9 @@ type GRPCServer struct {
 	ruleEngine       *engine.RuleEngine
 	notificationMgr  *notification.Manager
 	eventProcessor   *kafka.EventProcessor
+	suppression      *dedup.Service
+	correlation      *correlation.Service
+	alertStream      *alertstream.Service
 }
 
 // NewGRPCServer creates a new gRPC server
@@ -58,6 +67,21 @@ func NewGRPCServer(
 	}
 }
 
+// SetSuppressionService sets the service behind the rule suppression operations
+func (s *GRPCServer) SetSuppressionService(suppression *dedup.Service) {
+	s.suppression = suppression
+}
+
+// SetCorrelationService sets the service behind the alert case operations
+func (s *GRPCServer) SetCorrelationService(correlationService *correlation.Service) {
+	s.correlation = correlationService
+}
+
+// SetAlertStreamService sets the service behind alert subscriptions
+func (s *GRPCServer) SetAlertStreamService(alertStream *alertstream.Service) {
+	s.alertStream = alertStream
+}
+
 // Alert Management Operations
 
 // CreateAlert creates a new alert
@@ -273,6 +297,9 @@ func (s *GRPCServer) ResolveAlert(ctx context.Context, req *pb.ResolveAlertReque
 	if req.ResolvedBy == "" {
 		return nil, status.Error(codes.InvalidArgument, "resolved_by is required")
 	}
+	if strings.TrimSpace(req.Resolution) == "" {
+		return nil, status.Error(codes.InvalidArgument, "resolution is required")
+	}
 
 	if err := s.alertRepo.Resolve(ctx, req.AlertId, req.ResolvedBy, req.Resolution); err != nil {
 		s.logger.Error("Failed to resolve alert", "alert_id", req.AlertId, "error", err)
@@ -457,6 +484,214 @@ func (s *GRPCServer) DisableRule(ctx context.Context, req *pb.DisableRuleRequest
 	return &pb.DisableRuleResponse{Success: true}, nil
 }
 
+// Rule Suppression Operations
+
+// GetRuleSuppression retrieves a rule's effective suppression settings
+func (s *GRPCServer) GetRuleSuppression(ctx context.Context, req *pb.GetRuleSuppressionRequest) (*pb.GetRuleSuppressionResponse, error) {
+	if req.RuleId == "" {
+		return nil, status.Error(codes.InvalidArgument, "rule_id is required")
+	}
+
+	settings, err := s.suppression.Get(ctx, req.RuleId)
+	if err != nil {
+		return nil, s.suppressionError(err, req.RuleId, "failed to get rule suppression settings")
+	}
+
+	return &pb.GetRuleSuppressionResponse{Suppression: suppressionToProto(settings)}, nil
+}
+
+// UpdateRuleSuppression replaces a rule's suppression settings
+func (s *GRPCServer) UpdateRuleSuppression(ctx context.Context, req *pb.UpdateRuleSuppressionRequest) (*pb.UpdateRuleSuppressionResponse, error) {
+	if req.RuleId == "" {
+		return nil, status.Error(codes.InvalidArgument, "rule_id is required")
+	}
+	if req.UpdatedBy == "" {
+		return nil, status.Error(codes.InvalidArgument, "updated_by is required")
+	}
+
+	enabled := req.Enabled
+	settings, err := s.suppression.Update(ctx, req.RuleId, dedup.UpdateInput{
+		Enabled:           &enabled,
+		WindowSeconds:     int(req.WindowSeconds),
+		FingerprintFields: req.FingerprintFields,
+	}, req.UpdatedBy)
+	if err != nil {
+		return nil, s.suppressionError(err, req.RuleId, "failed to update rule suppression settings")
+	}
+
+	return &pb.UpdateRuleSuppressionResponse{Suppression: suppressionToProto(settings)}, nil
+}
+
+// ResetRuleSuppression returns a rule to the default suppression settings
+func (s *GRPCServer) ResetRuleSuppression(ctx context.Context, req *pb.ResetRuleSuppressionRequest) (*pb.ResetRuleSuppressionResponse, error) {
+	if req.RuleId == "" {
+		return nil, status.Error(codes.InvalidArgument, "rule_id is required")
+	}
+	if req.UpdatedBy == "" {
+		return nil, status.Error(codes.InvalidArgument, "updated_by is required")
+	}
+
+	if err := s.suppression.Reset(ctx, req.RuleId, req.UpdatedBy); err != nil {
+		return nil, s.suppressionError(err, req.RuleId, "failed to reset rule suppression settings")
+	}
+
+	return &pb.ResetRuleSuppressionResponse{Success: true}, nil
+}
+
+// suppressionError maps suppression service errors to gRPC statuses
+func (s *GRPCServer) suppressionError(err error, ruleID, message string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return status.Error(codes.NotFound, "rule not found")
+	case errors.Is(err, database.ErrSuppressionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, dedup.ErrInvalidSuppression):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.logger.Error("Rule suppression operation failed", "rule_id", ruleID, "error", err)
+	return status.Error(codes.Internal, message)
+}
+
+// Alert Correlation Case Operations
+
+// ListAlertCases lists correlated alert cases, most recently active first
+func (s *GRPCServer) ListAlertCases(ctx context.Context, req *pb.ListAlertCasesRequest) (*pb.ListAlertCasesResponse, error) {
+	cases, err := s.correlation.ListCases(ctx, req.Status, int(req.Limit))
+	if err != nil {
+		return nil, s.alertCaseError(err, "", "failed to list alert cases")
+	}
+
+	pbCases := make([]*pb.AlertCase, len(cases))
+	for i, alertCase := range cases {
+		pbCases[i] = alertCaseToProto(alertCase)
+	}
+
+	return &pb.ListAlertCasesResponse{Cases: pbCases}, nil
+}
+
+// GetAlertCase retrieves a case with its member alerts
+func (s *GRPCServer) GetAlertCase(ctx context.Context, req *pb.GetAlertCaseRequest) (*pb.GetAlertCaseResponse, error) {
+	if req.CaseId == "" {
+		return nil, status.Error(codes.InvalidArgument, "case_id is required")
+	}
+
+	detail, err := s.correlation.GetCase(ctx, req.CaseId)
+	if err != nil {
+		return nil, s.alertCaseError(err, req.CaseId, "failed to get alert case")
+	}
+
+	members := make([]*pb.AlertCaseMember, len(detail.Alerts))
+	for i, alert := range detail.Alerts {
+		members[i] = &pb.AlertCaseMember{
+			AlertId:     alert.AlertID,
+			Title:       alert.Title,
+			Severity:    alert.Severity,
+			Status:      alert.Status,
+			EntityIds:   alert.EntityIDs,
+			MatchedKeys: alert.MatchedKeys,
+			CreatedAt:   timestamppb.New(alert.CreatedAt),
+		}
+		if alert.RuleID != nil {
+			members[i].RuleId = *alert.RuleID
+		}
+	}
+
+	return &pb.GetAlertCaseResponse{Case: alertCaseToProto(detail.AlertCase), Alerts: members}, nil
+}
+
+// LinkAlertCase links an open case to an investigation, creating one when no
+// investigation_id is given
+func (s *GRPCServer) LinkAlertCase(ctx context.Context, req *pb.LinkAlertCaseRequest) (*pb.LinkAlertCaseResponse, error) {
+	if req.CaseId == "" {
+		return nil, status.Error(codes.InvalidArgument, "case_id is required")
+	}
+
+	input := correlation.LinkInput{
+		Actor:           req.LinkedBy,
+		InvestigationID: req.InvestigationId,
+		Title:           req.Title,
+		CaseType:        req.CaseType,
+		Priority:        req.Priority,
+	}
+	if err := correlation.ValidateLinkInput(input); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	result, err := s.correlation.LinkInvestigation(ctx, req.CaseId, input)
+	if err != nil {
+		return nil, s.alertCaseError(err, req.CaseId, "failed to link alert case")
+	}
+
+	return &pb.LinkAlertCaseResponse{
+		Case:                 alertCaseToProto(result.Case),
+		InvestigationCreated: result.InvestigationCreated,
+	}, nil
+}
+
+// alertCaseError maps correlation service errors to gRPC statuses
+func (s *GRPCServer) alertCaseError(err error, caseID, message string) error {
+	switch {
+	case errors.Is(err, database.ErrAlertCaseNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, database.ErrAlertCaseNotOpen), errors.Is(err, database.ErrAlertCaseClosed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	s.logger.Error("Alert case operation failed", "case_id", caseID, "error", err)
+	return status.Error(codes.Internal, message)
+}
+
+// Alert Subscription Operations
+
+// SubscribeAlerts streams alerts matching the request's filters as they are
+// raised, with heartbeats while none match. Every event carries a cursor; a
+// subscriber that reconnects with the last one it received misses nothing.
+func (s *GRPCServer) SubscribeAlerts(req *pb.SubscribeAlertsRequest, stream pb.AlertingEngine_SubscribeAlertsServer) error {
+	if s.alertStream == nil {
+		return status.Error(codes.Unavailable, "alert subscriptions are not enabled")
+	}
+	if req.HeartbeatSeconds < 0 {
+		return status.Error(codes.InvalidArgument, "heartbeat_seconds must not be negative")
+	}
+
+	sub := alertstream.Subscription{
+		Filter: database.AlertStreamFilter{
+			Severities: req.Severities,
+			RuleIDs:    req.RuleIds,
+			EntityIDs:  req.EntityIds,
+		},
+		Cursor:    req.Cursor,
+		Heartbeat: time.Duration(req.HeartbeatSeconds) * time.Second,
+	}
+
+	err := s.alertStream.Subscribe(stream.Context(), sub, func(event *alertstream.Event) error {
+		pbEvent := &pb.AlertStreamEvent{Cursor: event.Cursor}
+		if event.Heartbeat {
+			pbEvent.Event = &pb.AlertStreamEvent_Heartbeat{
+				Heartbeat: &pb.AlertStreamHeartbeat{SentAt: timestamppb.New(event.SentAt)},
+			}
+		} else {
+			pbEvent.Event = &pb.AlertStreamEvent_Alert{Alert: s.alertToProto(event.Alert)}
+		}
+		return stream.Send(pbEvent)
+	})
+
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, alertstream.ErrInvalidCursor), errors.Is(err, alertstream.ErrInvalidFilter):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, alertstream.ErrTooManySubscribers):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	s.logger.Error("Alert stream failed", "error", err)
+	return status.Error(codes.Internal, "alert stream failed")
+}
+
 // Notification Operations
 
 // GetNotificationStatus retrieves notification status
@@ -600,6 +835,48 @@ func (s *GRPCServer) ruleToProto(rule *database.Rule) *pb.Rule {
 	return pbRule
 }
 
+func suppressionToProto(settings *dedup.RuleSettings) *pb.RuleSuppression {
+	pbSuppression := &pb.RuleSuppression{
+		RuleId:            settings.RuleID,
+		Enabled:           settings.Enabled,
+		WindowSeconds:     int32(settings.WindowSeconds),
+		FingerprintFields: settings.FingerprintFields,
+		IsDefault:         settings.Default,
+		UpdatedBy:         settings.UpdatedBy,
+	}
+	if !settings.Default {
+		pbSuppression.UpdatedAt = timestamppb.New(settings.UpdatedAt)
+	}
+	if settings.Stats != nil {
+		pbSuppression.SuppressedCount = int64(settings.Stats.SuppressedCount)
+		pbSuppression.AlertsWithRepeats = int32(settings.Stats.AlertsWithRepeats)
+		pbSuppression.LastSuppressedAt = timeToProto(settings.Stats.LastSuppressedAt)
+	}
+	return pbSuppression
+}
+
+func alertCaseToProto(alertCase *database.AlertCase) *pb.AlertCase {
+	pbCase := &pb.AlertCase{
+		Id:              alertCase.ID,
+		Title:           alertCase.Title,
+		Status:          alertCase.Status,
+		CorrelationKeys: alertCase.CorrelationKeys,
+		AlertCount:      int32(alertCase.AlertCount),
+		MaxSeverity:     alertCase.MaxSeverity,
+		FirstAlertAt:    timestamppb.New(alertCase.FirstAlertAt),
+		LastAlertAt:     timestamppb.New(alertCase.LastAlertAt),
+		LinkedAt:        timeToProto(alertCase.LinkedAt),
+		CreatedAt:       timestamppb.New(alertCase.CreatedAt),
+	}
+	if alertCase.InvestigationID != nil {
+		pbCase.InvestigationId = *alertCase.InvestigationID
+	}
+	if alertCase.LinkedBy != nil {
+		pbCase.LinkedBy = *alertCase.LinkedBy
+	}
+	return pbCase
+}
+
 func timeToProto(t *time.Time) *timestamppb.Timestamp {
 	if t == nil {
 		return nil
@@ -0,0 +1,401 @@
+package severitytune
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/rulefeedback"
+)
+
+var (
+	// ErrNoChanges is returned when proposing while every rule keeps its severity
+	ErrNoChanges = errors.New("no rule severities would change")
+	// ErrInvalidGuardrail is returned for guard rails with unknown or inverted severities
+	ErrInvalidGuardrail = errors.New("guard rail severities must be low, medium, high or critical with min no higher than max")
+	// ErrRuleNotFound is returned when setting a guard rail on a rule that does not exist
+	ErrRuleNotFound = errors.New("rule not found")
+	// ErrSelfApproval is returned when the proposer reviews their own proposal
+	ErrSelfApproval = errors.New("a proposal must be approved by someone other than its proposer")
+	// ErrProposalExpired is returned when approving a proposal older than the proposal TTL
+	ErrProposalExpired = errors.New("severity tuning proposal has expired")
+)
+
+// Skip reasons for changes of an approved proposal that were not applied
+const (
+	SkipRuleDeleted     = "rule_deleted"
+	SkipSeverityChanged = "severity_changed_since_proposal"
+	SkipUpdateFailed    = "update_failed"
+)
+
+// PreviewQuery selects the dispositions severities are proposed from
+type PreviewQuery struct {
+	RuleID string     `json:"rule_id,omitempty"`
+	Since  *time.Time `json:"since,omitempty"`
+}
+
+// Preview is the severity each rule with dispositions would be given and
+// how that would have changed the alerts raised over the same lookback
+type Preview struct {
+	Since       time.Time   `json:"since"`
+	GeneratedAt time.Time   `json:"generated_at"`
+	Changed     int         `json:"changed"`
+	Changes     []*Change   `json:"changes"`
+	Simulation  *Simulation `json:"simulation"`
+}
+
+// ReviewInput is a reviewer's decision on a proposal
+type ReviewInput struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// ProposalDetail is a proposal with its changes and simulation decoded
+type ProposalDetail struct {
+	*database.SeverityProposal
+	Changes    []*Change   `json:"changes"`
+	Simulation *Simulation `json:"simulation"`
+}
+
+// Service proposes rule default severities from investigator dispositions
+// and applies them once a reviewer approves
+type Service struct {
+	config       *config.Config
+	logger       *slog.Logger
+	repo         *database.SeverityTuningRepository
+	feedbackRepo *database.RuleFeedbackRepository
+	ruleRepo     *database.RuleRepository
+}
+
+// NewService creates a new severity tuning service
+func NewService(cfg *config.Config, logger *slog.Logger, repo *database.SeverityTuningRepository,
+	feedbackRepo *database.RuleFeedbackRepository, ruleRepo *database.RuleRepository) *Service {
+	return &Service{
+		config:       cfg,
+		logger:       logger,
+		repo:         repo,
+		feedbackRepo: feedbackRepo,
+		ruleRepo:     ruleRepo,
+	}
+}
+
+// Preview proposes a severity for every rule with dispositions in the
+// query's window and simulates the proposals over the same window.
+// Nothing is stored.
+func (s *Service) Preview(ctx context.Context, query PreviewQuery) (*Preview, error) {
+	cfg := s.config.SeverityTuning
+	since := time.Now().Add(-cfg.Lookback)
+	if query.Since != nil {
+		since = *query.Since
+	}
+
+	samples, err := s.feedbackRepo.ListSamples(ctx, query.RuleID, since, cfg.MaxSamples)
+	if err != nil {
+		return nil, err
+	}
+	byRule := make(map[string][]*database.DispositionSample)
+	for _, sample := range samples {
+		byRule[sample.RuleID] = append(byRule[sample.RuleID], sample)
+	}
+
+	guardrails, err := s.guardrailsByRule(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := NewPolicy(cfg)
+	preview := &Preview{Since: since, GeneratedAt: time.Now(), Changes: make([]*Change, 0, len(byRule))}
+	for ruleID, ruleSamples := range byRule {
+		rule, err := s.ruleRepo.GetByID(ctx, ruleID)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Dispositions outlive deleted rules, which have no severity to tune
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		change := Propose(rule, CollectEvidence(ruleSamples, cfg.LossField), guardrails[ruleID], policy)
+		if change.Changed() {
+			preview.Changed++
+		}
+		preview.Changes = append(preview.Changes, change)
+	}
+	SortChanges(preview.Changes)
+
+	counts, err := s.repo.CountAlertsBySeverity(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	preview.Simulation = Simulate(counts, preview.Changes)
+	return preview, nil
+}
+
+// Propose stores the severity changes of a preview as a pending proposal.
+// Rules keeping their severity are left out.
+func (s *Service) Propose(ctx context.Context, query PreviewQuery, proposedBy string) (*ProposalDetail, error) {
+	preview, err := s.Preview(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*Change, 0, preview.Changed)
+	for _, change := range preview.Changes {
+		if change.Changed() {
+			changes = append(changes, change)
+		}
+	}
+	if len(changes) == 0 {
+		return nil, ErrNoChanges
+	}
+
+	changesData, err := json.Marshal(changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode severity changes: %w", err)
+	}
+	simulationData, err := json.Marshal(preview.Simulation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode severity simulation: %w", err)
+	}
+
+	proposal := &database.SeverityProposal{
+		ID:         generateID("sevtune"),
+		Since:      preview.Since,
+		Changes:    changesData,
+		Simulation: simulationData,
+		ProposedBy: proposedBy,
+	}
+	if err := s.repo.CreateProposal(ctx, proposal); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Severity tuning proposed",
+		"proposal_id", proposal.ID,
+		"changes", len(changes),
+		"alerts_changed", preview.Simulation.AlertsChanged,
+		"proposed_by", proposedBy)
+	return &ProposalDetail{SeverityProposal: proposal, Changes: changes, Simulation: preview.Simulation}, nil
+}
+
+// GetProposal returns a proposal with its changes
+func (s *Service) GetProposal(ctx context.Context, id string) (*ProposalDetail, error) {
+	proposal, err := s.repo.GetProposal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return decodeProposal(proposal)
+}
+
+// ListProposals returns proposals, newest first, optionally by status
+func (s *Service) ListProposals(ctx context.Context, status string, limit int) ([]*database.SeverityProposal, error) {
+	return s.repo.ListProposals(ctx, status, limit)
+}
+
+// Approve applies a pending proposal's severities. The proposal is claimed
+// before anything is applied so it is applied at most once. A rule whose
+// severity changed after the proposal was made is skipped rather than
+// overwritten; each change records whether it was applied.
+func (s *Service) Approve(ctx context.Context, id, approver string, input ReviewInput) (*ProposalDetail, error) {
+	proposal, err := s.claim(ctx, id, database.SeverityProposalApproved, approver, input)
+	if err != nil {
+		return nil, err
+	}
+	detail, err := decodeProposal(proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := 0
+	for _, change := range detail.Changes {
+		change.Applied, change.SkipReason = s.apply(ctx, proposal.ID, change, approver)
+		if change.Applied {
+			applied++
+		}
+	}
+
+	changesData, err := json.Marshal(detail.Changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode severity changes: %w", err)
+	}
+	if err := s.repo.UpdateChanges(ctx, proposal.ID, changesData); err != nil {
+		return nil, err
+	}
+	detail.SeverityProposal.Changes = changesData
+
+	s.logger.Info("Severity tuning approved",
+		"proposal_id", proposal.ID,
+		"applied", applied,
+		"skipped", len(detail.Changes)-applied,
+		"approved_by", approver)
+	return detail, nil
+}
+
+// Reject closes a pending proposal without applying it
+func (s *Service) Reject(ctx context.Context, id, reviewer string, input ReviewInput) (*ProposalDetail, error) {
+	proposal, err := s.claim(ctx, id, database.SeverityProposalRejected, reviewer, input)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Severity tuning rejected", "proposal_id", proposal.ID, "rejected_by", reviewer)
+	return decodeProposal(proposal)
+}
+
+// claim records the review of a pending proposal and returns it as reviewed
+func (s *Service) claim(ctx context.Context, id, status, reviewer string, input ReviewInput) (*database.SeverityProposal, error) {
+	proposal, err := s.repo.GetProposal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != database.SeverityProposalPending {
+		return nil, database.ErrSeverityProposalReviewed
+	}
+	if status == database.SeverityProposalApproved {
+		if s.config.SeverityTuning.SeparateApprover && reviewer == proposal.ProposedBy {
+			return nil, ErrSelfApproval
+		}
+		if ttl := s.config.SeverityTuning.ProposalTTL; ttl > 0 && time.Since(proposal.ProposedAt) > ttl {
+			return nil, ErrProposalExpired
+		}
+	}
+
+	var comment *string
+	if trimmed := strings.TrimSpace(input.Comment); trimmed != "" {
+		comment = &trimmed
+	}
+	if err := s.repo.Review(ctx, id, status, reviewer, comment); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	proposal.Status = status
+	proposal.ReviewedBy = &reviewer
+	proposal.ReviewedAt = &now
+	proposal.ReviewComment = comment
+	return proposal, nil
+}
+
+// apply sets one rule's default severity as a new rule version
+func (s *Service) apply(ctx context.Context, proposalID string, change *Change, approver string) (bool, string) {
+	rule, err := s.ruleRepo.GetByID(ctx, change.RuleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, SkipRuleDeleted
+	}
+	if err != nil {
+		s.logger.Error("Failed to load rule for severity tuning", "proposal_id", proposalID, "rule_id", change.RuleID, "error", err)
+		return false, SkipUpdateFailed
+	}
+	if rule.Severity != change.CurrentSeverity {
+		return false, SkipSeverityChanged
+	}
+
+	rule.Severity = change.ProposedSeverity
+	rule.UpdatedBy = approver
+	reason := fmt.Sprintf("severity tuning proposal %s: %s to %s", proposalID, change.CurrentSeverity, change.ProposedSeverity)
+	if err := s.ruleRepo.UpdateWithChange(ctx, rule, database.RuleChange{
+		Type:   database.RuleChangeUpdated,
+		Reason: &reason,
+		By:     approver,
+	}); err != nil {
+		s.logger.Error("Failed to apply tuned severity", "proposal_id", proposalID, "rule_id", change.RuleID, "error", err)
+		return false, SkipUpdateFailed
+	}
+	return true, ""
+}
+
+// SetGuardrail sets the lowest and highest severity tuning may propose for a rule
+func (s *Service) SetGuardrail(ctx context.Context, ruleID string, guardrail Guardrail, updatedBy string) (*database.SeverityGuardrail, error) {
+	low, lowOK := Rank(guardrail.MinSeverity)
+	high, highOK := Rank(guardrail.MaxSeverity)
+	if !lowOK || !highOK || low > high {
+		return nil, ErrInvalidGuardrail
+	}
+
+	if _, err := s.ruleRepo.GetByID(ctx, ruleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, err
+	}
+
+	return s.repo.UpsertGuardrail(ctx, &database.SeverityGuardrail{
+		RuleID:      ruleID,
+		MinSeverity: Severities[low],
+		MaxSeverity: Severities[high],
+		UpdatedBy:   updatedBy,
+	})
+}
+
+// GetGuardrail returns a rule's guard rail
+func (s *Service) GetGuardrail(ctx context.Context, ruleID string) (*database.SeverityGuardrail, error) {
+	return s.repo.GetGuardrail(ctx, ruleID)
+}
+
+// ListGuardrails returns every guard rail
+func (s *Service) ListGuardrails(ctx context.Context) ([]*database.SeverityGuardrail, error) {
+	return s.repo.ListGuardrails(ctx)
+}
+
+// DeleteGuardrail removes a rule's guard rail
+func (s *Service) DeleteGuardrail(ctx context.Context, ruleID string) error {
+	return s.repo.DeleteGuardrail(ctx, ruleID)
+}
+
+func (s *Service) guardrailsByRule(ctx context.Context) (map[string]*Guardrail, error) {
+	stored, err := s.repo.ListGuardrails(ctx)
+	if err != nil {
+		return nil, err
+	}
+	guardrails := make(map[string]*Guardrail, len(stored))
+	for _, guardrail := range stored {
+		guardrails[guardrail.RuleID] = &Guardrail{MinSeverity: guardrail.MinSeverity, MaxSeverity: guardrail.MaxSeverity}
+	}
+	return guardrails, nil
+}
+
+// CollectEvidence sums up a rule's dispositions. Losses are averaged over
+// the true positives whose event has a numeric value at lossField.
+func CollectEvidence(samples []*database.DispositionSample, lossField string) Evidence {
+	evidence := Evidence{Dispositions: len(samples)}
+	totalLoss := 0.0
+	for _, sample := range samples {
+		if sample.Disposition != database.AlertDispositionTruePositive {
+			evidence.FalsePositives++
+			continue
+		}
+		evidence.TruePositives++
+		if loss, ok := rulefeedback.FieldValue(sample.SourceEvent, lossField); ok {
+			evidence.LossSamples++
+			totalLoss += loss
+		}
+	}
+
+	evidence.TruePositiveRate = rulefeedback.Precision(evidence.TruePositives, evidence.FalsePositives)
+	if evidence.LossSamples > 0 {
+		evidence.AverageLoss = totalLoss / float64(evidence.LossSamples)
+	}
+	return evidence
+}
+
+func decodeProposal(proposal *database.SeverityProposal) (*ProposalDetail, error) {
+	detail := &ProposalDetail{SeverityProposal: proposal}
+	if err := json.Unmarshal(proposal.Changes, &detail.Changes); err != nil {
+		return nil, fmt.Errorf("failed to decode severity changes: %w", err)
+	}
+	if len(proposal.Simulation) > 0 {
+		if err := json.Unmarshal(proposal.Simulation, &detail.Simulation); err != nil {
+			return nil, fmt.Errorf("failed to decode severity simulation: %w", err)
+		}
+	}
+	return detail, nil
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
@@ -0,0 +1,225 @@
+package severitytune
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// Severities lists rule severities from least to most severe
+var Severities = []string{"low", "medium", "high", "critical"}
+
+// Reasons given for a rule's proposed severity
+const (
+	ReasonInsufficientData = "insufficient_data"
+	ReasonUnknownSeverity  = "unknown_severity"
+	ReasonRaise            = "raise"
+	ReasonLower            = "lower"
+	ReasonKeep             = "keep"
+)
+
+// Rank returns a severity's position in Severities
+func Rank(severity string) (int, bool) {
+	severity = strings.ToLower(strings.TrimSpace(severity))
+	for i, candidate := range Severities {
+		if candidate == severity {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Evidence is what a rule's dispositions say about how severe its alerts are
+type Evidence struct {
+	Dispositions     int     `json:"dispositions"`
+	TruePositives    int     `json:"true_positives"`
+	FalsePositives   int     `json:"false_positives"`
+	TruePositiveRate float64 `json:"true_positive_rate"`
+	LossSamples      int     `json:"loss_samples"` // true positives whose event has a loss amount
+	AverageLoss      float64 `json:"average_loss"`
+}
+
+// Policy decides the severity evidence warrants
+type Policy struct {
+	MinDispositions int
+	MediumLoss      float64
+	HighLoss        float64
+	CriticalLoss    float64
+	RaisePrecision  float64
+	LowerPrecision  float64
+	MaxStep         int // zero moves straight to the target
+}
+
+// NewPolicy builds the policy of the severity tuning configuration
+func NewPolicy(cfg config.SeverityTuningConfig) Policy {
+	return Policy{
+		MinDispositions: cfg.MinDispositions,
+		MediumLoss:      cfg.MediumLoss,
+		HighLoss:        cfg.HighLoss,
+		CriticalLoss:    cfg.CriticalLoss,
+		RaisePrecision:  cfg.RaisePrecision,
+		LowerPrecision:  cfg.LowerPrecision,
+		MaxStep:         cfg.MaxStep,
+	}
+}
+
+// Guardrail bounds the severities that may be proposed for a rule
+type Guardrail struct {
+	MinSeverity string `json:"min_severity"`
+	MaxSeverity string `json:"max_severity"`
+}
+
+// Change is the severity proposed for one rule. Applied and SkipReason are
+// filled in when an approved proposal is applied.
+type Change struct {
+	RuleID           string   `json:"rule_id"`
+	RuleName         string   `json:"rule_name"`
+	CurrentSeverity  string   `json:"current_severity"`
+	TargetSeverity   string   `json:"target_severity"` // what the evidence alone warrants
+	ProposedSeverity string   `json:"proposed_severity"`
+	Reason           string   `json:"reason"`
+	GuardrailApplied bool     `json:"guardrail_applied"`
+	Evidence         Evidence `json:"evidence"`
+	AlertsAffected   int      `json:"alerts_affected"` // alerts in the lookback that would have had the proposed severity
+	Applied          bool     `json:"applied,omitempty"`
+	SkipReason       string   `json:"skip_reason,omitempty"`
+}
+
+// Changed reports whether the proposed severity differs from the current one
+func (c *Change) Changed() bool {
+	return c.ProposedSeverity != c.CurrentSeverity
+}
+
+// Propose picks the default severity a rule's evidence warrants. The
+// average loss of its true positives sets the tier, a true-positive rate at
+// or above RaisePrecision raises it a level and one below LowerPrecision
+// lowers it a level. The severity moves at most MaxStep levels towards that
+// target and is then held within the rule's guard rail, if it has one.
+func Propose(rule *database.Rule, evidence Evidence, guardrail *Guardrail, policy Policy) *Change {
+	change := &Change{
+		RuleID:           rule.ID,
+		RuleName:         rule.Name,
+		CurrentSeverity:  rule.Severity,
+		TargetSeverity:   rule.Severity,
+		ProposedSeverity: rule.Severity,
+		Reason:           ReasonKeep,
+		Evidence:         evidence,
+	}
+
+	current, ok := Rank(rule.Severity)
+	if !ok {
+		change.Reason = ReasonUnknownSeverity
+		return change
+	}
+	if evidence.Dispositions < policy.MinDispositions {
+		change.Reason = ReasonInsufficientData
+		return change
+	}
+
+	target := current
+	if evidence.LossSamples > 0 {
+		target = policy.lossRank(evidence.AverageLoss)
+	}
+	switch {
+	case evidence.TruePositiveRate >= policy.RaisePrecision:
+		target++
+	case evidence.TruePositiveRate < policy.LowerPrecision:
+		target--
+	}
+	target = clampRank(target, 0, len(Severities)-1)
+	change.TargetSeverity = Severities[target]
+
+	proposed := target
+	if policy.MaxStep > 0 {
+		proposed = clampRank(target, current-policy.MaxStep, current+policy.MaxStep)
+	}
+	if guardrail != nil {
+		low, lowOK := Rank(guardrail.MinSeverity)
+		high, highOK := Rank(guardrail.MaxSeverity)
+		if lowOK && highOK {
+			if bounded := clampRank(proposed, low, high); bounded != proposed {
+				change.GuardrailApplied = true
+				proposed = bounded
+			}
+		}
+	}
+	switch {
+	case proposed > current:
+		change.ProposedSeverity = Severities[proposed]
+		change.Reason = ReasonRaise
+	case proposed < current:
+		change.ProposedSeverity = Severities[proposed]
+		change.Reason = ReasonLower
+	}
+	return change
+}
+
+// lossRank returns the severity tier of an average loss
+func (p Policy) lossRank(loss float64) int {
+	switch {
+	case loss >= p.CriticalLoss:
+		return 3
+	case loss >= p.HighLoss:
+		return 2
+	case loss >= p.MediumLoss:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func clampRank(rank, low, high int) int {
+	if rank < low {
+		return low
+	}
+	if rank > high {
+		return high
+	}
+	return rank
+}
+
+// Simulation is the alerts raised in a lookback counted by severity, as
+// raised and as they would have been raised under the proposed severities
+type Simulation struct {
+	Before        map[string]int `json:"before"`
+	After         map[string]int `json:"after"`
+	AlertsChanged int            `json:"alerts_changed"`
+}
+
+// Simulate replays the alerts counted by rule and severity under changes.
+// Only alerts raised at their rule's current default severity move; alerts
+// whose severity was set some other way keep it. Each change's
+// AlertsAffected is set to the alerts that would have moved.
+func Simulate(counts []*database.RuleSeverityCount, changes []*Change) *Simulation {
+	byRule := make(map[string]*Change, len(changes))
+	for _, change := range changes {
+		change.AlertsAffected = 0
+		byRule[change.RuleID] = change
+	}
+
+	simulation := &Simulation{Before: make(map[string]int), After: make(map[string]int)}
+	for _, count := range counts {
+		simulation.Before[count.Severity] += count.Count
+
+		severity := count.Severity
+		if change, ok := byRule[count.RuleID]; ok && change.Changed() && count.Severity == change.CurrentSeverity {
+			severity = change.ProposedSeverity
+			change.AlertsAffected += count.Count
+			simulation.AlertsChanged += count.Count
+		}
+		simulation.After[severity] += count.Count
+	}
+	return simulation
+}
+
+// SortChanges orders changes with those that move a severity first, then by rule ID
+func SortChanges(changes []*Change) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Changed() != changes[j].Changed() {
+			return changes[i].Changed()
+		}
+		return changes[i].RuleID < changes[j].RuleID
+	})
+}
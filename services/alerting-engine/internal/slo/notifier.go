@@ -0,0 +1,94 @@
+package slo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+)
+
+// SlackNotifier posts error budget warnings to the ops Slack channel
+type SlackNotifier struct {
+	webhookURL string
+	channel    string
+	client     *http.Client
+}
+
+// NewSlackNotifier creates a notifier using the configured Slack webhook
+func NewSlackNotifier(slackCfg config.SlackConfig, channel string) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: slackCfg.WebhookURL,
+		channel:    channel,
+		client:     &http.Client{Timeout: slackCfg.Timeout},
+	}
+}
+
+// Notify sends a single warning message for an objective
+func (n *SlackNotifier) Notify(ctx context.Context, status *Status) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"channel": n.channel,
+		"text":    FormatWarning(status),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal SLO warning: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send SLO warning: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Slack API returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// FormatWarning renders the ops channel message for a degraded objective
+func FormatWarning(status *Status) string {
+	var b strings.Builder
+
+	headline := "error budget burning fast"
+	switch status.State {
+	case StateExhausted:
+		headline = "error budget exhausted"
+	case StateWarning:
+		headline = "error budget at risk"
+	}
+
+	fmt.Fprintf(&b, "*SLO %s: %s* (%s)\n", status.Name, headline, status.Service)
+	fmt.Fprintf(&b, "Target %.3f%% over %s, budget remaining %.1f%%",
+		status.Target*100, status.Window, status.BudgetRemaining*100)
+	if status.SLI != nil {
+		fmt.Fprintf(&b, ", current SLI %.3f%%", *status.SLI*100)
+	}
+
+	if len(status.BurnRates) > 0 {
+		windows := make([]string, 0, len(status.BurnRates))
+		for window := range status.BurnRates {
+			windows = append(windows, window)
+		}
+		sort.Strings(windows)
+
+		rates := make([]string, 0, len(windows))
+		for _, window := range windows {
+			rates = append(rates, fmt.Sprintf("%s=%.1fx", window, status.BurnRates[window]))
+		}
+		fmt.Fprintf(&b, "\nBurn rates: %s", strings.Join(rates, " "))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package slo
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+)
+
+// Objective types
+const (
+	TypeAvailability = "availability"
+	TypeLatency      = "latency"
+)
+
+// Budget states, ordered by severity
+const (
+	StateOK        = "ok"
+	StateNoData    = "no_data"
+	StateWarning   = "warning"
+	StateCritical  = "critical"
+	StateExhausted = "exhausted"
+)
+
+// burnWindows are the short and long windows of the multi-window burn rate
+// alerts: a fast burn pages on 5m/1h, a slow burn warns on 30m/6h
+var burnWindows = []time.Duration{5 * time.Minute, time.Hour, 30 * time.Minute, 6 * time.Hour}
+
+// ValidateObjective checks an objective definition before it is evaluated
+func ValidateObjective(objective config.SLOObjective) error {
+	if objective.Name == "" || objective.Service == "" {
+		return fmt.Errorf("objective name and service are required")
+	}
+	if objective.Target <= 0 || objective.Target >= 1 {
+		return fmt.Errorf("objective %s: target must be between 0 and 1", objective.Name)
+	}
+	if objective.Window <= 0 {
+		return fmt.Errorf("objective %s: window must be positive", objective.Name)
+	}
+
+	switch objective.Type {
+	case TypeAvailability:
+		if objective.RequestsMetric == "" {
+			return fmt.Errorf("objective %s: requests_metric is required", objective.Name)
+		}
+	case TypeLatency:
+		if objective.DurationMetric == "" || objective.LatencyThreshold == "" {
+			return fmt.Errorf("objective %s: duration_metric and latency_threshold are required", objective.Name)
+		}
+	default:
+		return fmt.Errorf("objective %s: unsupported type %q", objective.Name, objective.Type)
+	}
+
+	return nil
+}
+
+// ErrorRatioQuery builds the PromQL expression for the fraction of bad
+// events over the given window
+func ErrorRatioQuery(objective config.SLOObjective, window time.Duration) string {
+	rng := promDuration(window)
+
+	if objective.Type == TypeLatency {
+		bucketSelector := joinSelector(objective.Selector, fmt.Sprintf(`le="%s"`, objective.LatencyThreshold))
+		return fmt.Sprintf(`1 - (sum(rate(%s_bucket{%s}[%s])) / sum(rate(%s_count{%s}[%s])))`,
+			objective.DurationMetric, bucketSelector, rng,
+			objective.DurationMetric, objective.Selector, rng)
+	}
+
+	errorSelector := joinSelector(objective.Selector, `status=~"5.."`)
+	return fmt.Sprintf(`sum(rate(%s{%s}[%s])) / sum(rate(%s{%s}[%s]))`,
+		objective.RequestsMetric, errorSelector, rng,
+		objective.RequestsMetric, objective.Selector, rng)
+}
+
+// BurnRate is how many times faster than sustainable the error budget is being
+// spent; 1.0 exhausts the budget exactly at the end of the SLO window
+func BurnRate(errorRatio, target float64) float64 {
+	budget := 1 - target
+	if budget <= 0 || math.IsNaN(errorRatio) {
+		return 0
+	}
+	return errorRatio / budget
+}
+
+// BudgetRemaining returns the fraction of the error budget left for the window.
+// It goes negative once the objective has been breached.
+func BudgetRemaining(errorRatio, target float64) float64 {
+	budget := 1 - target
+	if budget <= 0 || math.IsNaN(errorRatio) {
+		return 1
+	}
+	return 1 - errorRatio/budget
+}
+
+// Classify derives the budget state from remaining budget and burn rates
+func Classify(remaining float64, burnRates map[string]float64, fastBurn, slowBurn, warningRatio float64) string {
+	if remaining <= 0 {
+		return StateExhausted
+	}
+
+	// Both windows of a pair must burn to avoid paging on short spikes
+	fast := burnRates[promDuration(5*time.Minute)] >= fastBurn && burnRates[promDuration(time.Hour)] >= fastBurn
+	if fast {
+		return StateCritical
+	}
+
+	slow := burnRates[promDuration(30*time.Minute)] >= slowBurn && burnRates[promDuration(6*time.Hour)] >= slowBurn
+	if slow || remaining <= warningRatio {
+		return StateWarning
+	}
+
+	return StateOK
+}
+
+// stateSeverity orders states so only worsening transitions are announced
+func stateSeverity(state string) int {
+	switch state {
+	case StateWarning:
+		return 1
+	case StateCritical:
+		return 2
+	case StateExhausted:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func joinSelector(selector, matcher string) string {
+	if selector == "" {
+		return matcher
+	}
+	return selector + "," + matcher
+}
+
+// promDuration renders a duration in Prometheus range syntax
+func promDuration(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+}
@@ -0,0 +1,82 @@
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusClient runs instant queries against the Prometheus HTTP API
+type PrometheusClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewPrometheusClient creates a new Prometheus query client
+func NewPrometheusClient(baseURL string, timeout time.Duration) *PrometheusClient {
+	return &PrometheusClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+type queryResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Value []interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// Query evaluates a PromQL expression expected to return a single sample.
+// The boolean is false when the query returned no data, e.g. no traffic.
+func (p *PrometheusClient) Query(ctx context.Context, query string) (float64, bool, error) {
+	endpoint := p.baseURL + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create prometheus request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to query prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result queryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, false, fmt.Errorf("failed to decode prometheus response: %w", err)
+	}
+
+	if result.Status != "success" {
+		return 0, false, fmt.Errorf("prometheus query failed: %s: %s", result.ErrorType, result.Error)
+	}
+	if result.Data.ResultType != "vector" {
+		return 0, false, fmt.Errorf("unexpected prometheus result type: %s", result.Data.ResultType)
+	}
+	if len(result.Data.Result) == 0 || len(result.Data.Result[0].Value) != 2 {
+		return 0, false, nil
+	}
+
+	raw, ok := result.Data.Result[0].Value[1].(string)
+	if !ok {
+		return 0, false, fmt.Errorf("unexpected prometheus sample value: %v", result.Data.Result[0].Value[1])
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to parse prometheus sample %q: %w", raw, err)
+	}
+
+	return value, true, nil
+}
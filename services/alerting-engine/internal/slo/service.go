@@ -0,0 +1,200 @@
+package slo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+)
+
+// Status is the current error budget position of one objective
+type Status struct {
+	Name            string             `json:"name"`
+	Service         string             `json:"service"`
+	Type            string             `json:"type"`
+	Target          float64            `json:"target"`
+	Window          string             `json:"window"`
+	SLI             *float64           `json:"sli,omitempty"`
+	BudgetRemaining float64            `json:"budget_remaining"`
+	BurnRates       map[string]float64 `json:"burn_rates"`
+	State           string             `json:"state"`
+	EvaluatedAt     time.Time          `json:"evaluated_at"`
+	Error           string             `json:"error,omitempty"`
+}
+
+// Querier runs instant PromQL queries
+type Querier interface {
+	Query(ctx context.Context, query string) (float64, bool, error)
+}
+
+// Notifier publishes error budget warnings to the ops channel
+type Notifier interface {
+	Notify(ctx context.Context, status *Status) error
+}
+
+// Service evaluates SLOs against Prometheus and tracks error budget state
+type Service struct {
+	config   *config.Config
+	logger   *slog.Logger
+	querier  Querier
+	notifier Notifier
+
+	mu       sync.RWMutex
+	statuses map[string]*Status
+}
+
+// NewService creates a new SLO service; objectives failing validation are skipped
+func NewService(cfg *config.Config, logger *slog.Logger, querier Querier, notifier Notifier) *Service {
+	for _, objective := range cfg.SLO.Objectives {
+		if err := ValidateObjective(objective); err != nil {
+			logger.Warn("Ignoring invalid SLO objective", "error", err)
+		}
+	}
+
+	return &Service{
+		config:   cfg,
+		logger:   logger,
+		querier:  querier,
+		notifier: notifier,
+		statuses: make(map[string]*Status),
+	}
+}
+
+// Evaluate recomputes every objective and announces worsening budget states
+func (s *Service) Evaluate(ctx context.Context) ([]*Status, error) {
+	var failed int
+	results := make([]*Status, 0, len(s.config.SLO.Objectives))
+
+	for _, objective := range s.config.SLO.Objectives {
+		if err := ValidateObjective(objective); err != nil {
+			continue
+		}
+
+		status := s.evaluateObjective(ctx, objective)
+		if status.Error != "" {
+			failed++
+		}
+		results = append(results, status)
+
+		s.mu.Lock()
+		previous := s.statuses[objective.Name]
+		s.statuses[objective.Name] = status
+		s.mu.Unlock()
+
+		s.announce(ctx, previous, status)
+	}
+
+	if failed > 0 && failed == len(results) {
+		return results, fmt.Errorf("failed to evaluate all %d SLO objectives", failed)
+	}
+
+	return results, nil
+}
+
+// Statuses returns the most recent evaluation of every objective
+func (s *Service) Statuses() []*Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]*Status, 0, len(s.statuses))
+	for _, status := range s.statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// GetStatus returns the most recent evaluation of one objective
+func (s *Service) GetStatus(name string) (*Status, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status, ok := s.statuses[name]
+	return status, ok
+}
+
+func (s *Service) evaluateObjective(ctx context.Context, objective config.SLOObjective) *Status {
+	status := &Status{
+		Name:        objective.Name,
+		Service:     objective.Service,
+		Type:        objective.Type,
+		Target:      objective.Target,
+		Window:      promDuration(objective.Window),
+		BurnRates:   make(map[string]float64, len(burnWindows)),
+		EvaluatedAt: time.Now(),
+	}
+
+	ratio, ok, err := s.querier.Query(ctx, ErrorRatioQuery(objective, objective.Window))
+	if err != nil {
+		s.logger.Error("Failed to evaluate SLO", "objective", objective.Name, "error", err)
+		status.State = StateNoData
+		status.Error = err.Error()
+		return status
+	}
+	if !ok {
+		status.State = StateNoData
+		status.BudgetRemaining = 1
+		return status
+	}
+
+	sli := 1 - ratio
+	status.SLI = &sli
+	status.BudgetRemaining = BudgetRemaining(ratio, objective.Target)
+
+	for _, window := range burnWindows {
+		windowRatio, ok, err := s.querier.Query(ctx, ErrorRatioQuery(objective, window))
+		if err != nil {
+			s.logger.Warn("Failed to compute burn rate",
+				"objective", objective.Name,
+				"window", promDuration(window),
+				"error", err)
+			continue
+		}
+		if !ok {
+			windowRatio = 0
+		}
+		status.BurnRates[promDuration(window)] = BurnRate(windowRatio, objective.Target)
+	}
+
+	status.State = Classify(status.BudgetRemaining, status.BurnRates,
+		s.config.SLO.FastBurnRate, s.config.SLO.SlowBurnRate, s.config.SLO.BudgetWarningRatio)
+
+	return status
+}
+
+// announce publishes a warning when an objective moves to a worse budget state
+func (s *Service) announce(ctx context.Context, previous, current *Status) {
+	previousState := StateOK
+	if previous != nil {
+		previousState = previous.State
+	}
+
+	if stateSeverity(current.State) <= stateSeverity(previousState) {
+		if stateSeverity(current.State) < stateSeverity(previousState) {
+			s.logger.Info("SLO error budget recovered",
+				"objective", current.Name,
+				"from", previousState,
+				"to", current.State)
+		}
+		return
+	}
+
+	s.logger.Warn("SLO error budget degraded",
+		"objective", current.Name,
+		"service", current.Service,
+		"state", current.State,
+		"budget_remaining", current.BudgetRemaining)
+
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.Notify(ctx, current); err != nil {
+		s.logger.Error("Failed to publish SLO warning", "objective", current.Name, "error", err)
+	}
+}
@@ -0,0 +1,271 @@
+package storm
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Options tune storm detection and sampling
+type Options struct {
+	Window            time.Duration // alert rate is counted per window
+	BaselineSmoothing float64       // weight of the latest quiet window in the baseline rate
+	MinAlerts         int           // a window never counts as a storm below this many alerts
+	RateMultiplier    float64       // a storm is a window rate this many times the baseline
+	QuietWindows      int           // consecutive windows under the threshold that end a storm
+	TargetAdmitted    int           // individual alerts let through per window during a storm
+	MinSampleRate     float64
+}
+
+// Decision is the detector's view of one rule when an alert is observed
+type Decision struct {
+	Storm      bool    `json:"storm"`
+	Started    bool    `json:"started"` // this alert pushed the rule into a storm
+	Ended      bool    `json:"ended"`   // the rule's previous storm ended before this alert
+	Count      int     `json:"count"`   // alerts in the current window, including this one
+	Baseline   float64 `json:"baseline"`
+	Threshold  float64 `json:"threshold"`
+	SampleRate float64 `json:"sample_rate"`
+}
+
+// RuleRate is a snapshot of one rule's alert rate
+type RuleRate struct {
+	RuleID      string    `json:"rule_id"`
+	WindowStart time.Time `json:"window_start"`
+	Count       int       `json:"count"`
+	Baseline    float64   `json:"baseline"`
+	Threshold   float64   `json:"threshold"`
+	Storm       bool      `json:"storm"`
+	StormSince  time.Time `json:"storm_since,omitempty"`
+}
+
+// Detector tracks the alert rate of each rule in tumbling windows and flags
+// a storm when a window's rate jumps well above the rule's learned baseline.
+// Windows inside a storm are not learned, so a flood never becomes normal.
+type Detector struct {
+	opts  Options
+	mu    sync.Mutex
+	rules map[string]*ruleRate
+}
+
+type ruleRate struct {
+	windowStart time.Time
+	count       int
+	baseline    float64
+	learned     bool
+	storm       bool
+	stormSince  time.Time
+	stormLimit  float64 // threshold in force when the storm started
+	quiet       int
+}
+
+// NewDetector creates a detector, filling unset options with defaults
+func NewDetector(opts Options) *Detector {
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+	if opts.BaselineSmoothing <= 0 || opts.BaselineSmoothing > 1 {
+		opts.BaselineSmoothing = 0.2
+	}
+	if opts.MinAlerts <= 0 {
+		opts.MinAlerts = 100
+	}
+	if opts.RateMultiplier < 1 {
+		opts.RateMultiplier = 10
+	}
+	if opts.QuietWindows <= 0 {
+		opts.QuietWindows = 5
+	}
+	if opts.TargetAdmitted <= 0 {
+		opts.TargetAdmitted = 10
+	}
+	if opts.MinSampleRate <= 0 || opts.MinSampleRate > 1 {
+		opts.MinSampleRate = 0.001
+	}
+
+	return &Detector{
+		opts:  opts,
+		rules: make(map[string]*ruleRate),
+	}
+}
+
+// Observe counts one alert for a rule and reports whether the rule is storming
+func (d *Detector) Observe(ruleID string, at time.Time) Decision {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	rate, ok := d.rules[ruleID]
+	if !ok {
+		rate = &ruleRate{windowStart: at.Truncate(d.opts.Window)}
+		d.rules[ruleID] = rate
+	}
+	wasStorm := rate.storm
+	d.roll(rate, at)
+
+	rate.count++
+	decision := Decision{
+		Ended:     wasStorm && !rate.storm,
+		Count:     rate.count,
+		Baseline:  rate.baseline,
+		Threshold: d.threshold(rate),
+	}
+
+	if !rate.storm && float64(rate.count) >= decision.Threshold {
+		rate.storm = true
+		rate.stormSince = at
+		rate.stormLimit = decision.Threshold
+		rate.quiet = 0
+		decision.Started = true
+	}
+
+	decision.Storm = rate.storm
+	decision.SampleRate = 1
+	if rate.storm {
+		decision.SampleRate = SampleRate(rate.count, d.opts.TargetAdmitted, d.opts.MinSampleRate)
+	}
+
+	return decision
+}
+
+// Sweep closes the windows of every rule up to the given time and returns
+// the rules whose storms have ended
+func (d *Detector) Sweep(at time.Time) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var ended []string
+	for ruleID, rate := range d.rules {
+		wasStorm := rate.storm
+		d.roll(rate, at)
+		if wasStorm && !rate.storm {
+			ended = append(ended, ruleID)
+		}
+		// Rules that have gone quiet are forgotten and relearned on their next alert
+		if !rate.storm && rate.count == 0 && rate.baseline < 0.01 {
+			delete(d.rules, ruleID)
+		}
+	}
+
+	sort.Strings(ended)
+	return ended
+}
+
+// Storming reports whether a rule is currently in a storm
+func (d *Detector) Storming(ruleID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	rate, ok := d.rules[ruleID]
+	return ok && rate.storm
+}
+
+// Rates returns a snapshot of every tracked rule, storming rules first
+func (d *Detector) Rates() []*RuleRate {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	rates := make([]*RuleRate, 0, len(d.rules))
+	for ruleID, rate := range d.rules {
+		rates = append(rates, &RuleRate{
+			RuleID:      ruleID,
+			WindowStart: rate.windowStart,
+			Count:       rate.count,
+			Baseline:    rate.baseline,
+			Threshold:   d.threshold(rate),
+			Storm:       rate.storm,
+			StormSince:  rate.stormSince,
+		})
+	}
+
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].Storm != rates[j].Storm {
+			return rates[i].Storm
+		}
+		return rates[i].RuleID < rates[j].RuleID
+	})
+	return rates
+}
+
+// roll closes every window that ended before the given time. Quiet windows
+// update the baseline; storm windows only count towards the storm ending.
+func (d *Detector) roll(rate *ruleRate, at time.Time) {
+	for !at.Before(rate.windowStart.Add(d.opts.Window)) {
+		if rate.storm {
+			if float64(rate.count) < rate.stormLimit {
+				rate.quiet++
+			} else {
+				rate.quiet = 0
+			}
+			if rate.quiet >= d.opts.QuietWindows {
+				rate.storm = false
+				rate.quiet = 0
+			}
+		} else if !rate.learned {
+			rate.baseline = float64(rate.count)
+			rate.learned = true
+		} else {
+			alpha := d.opts.BaselineSmoothing
+			rate.baseline = alpha*float64(rate.count) + (1-alpha)*rate.baseline
+		}
+
+		rate.count = 0
+		rate.windowStart = rate.windowStart.Add(d.opts.Window)
+
+		// Skip straight past long idle gaps, decaying the baseline once per empty window
+		if idle := int(at.Sub(rate.windowStart) / d.opts.Window); idle > d.opts.QuietWindows+1 {
+			if !rate.storm {
+				rate.baseline *= math.Pow(1-d.opts.BaselineSmoothing, float64(idle))
+			}
+			rate.quiet += idle
+			if rate.storm && rate.quiet >= d.opts.QuietWindows {
+				rate.storm = false
+				rate.quiet = 0
+			}
+			rate.windowStart = rate.windowStart.Add(time.Duration(idle) * d.opts.Window)
+		}
+	}
+}
+
+func (d *Detector) threshold(rate *ruleRate) float64 {
+	return math.Max(float64(d.opts.MinAlerts), d.opts.RateMultiplier*rate.baseline)
+}
+
+// SampleRate is the share of a storming rule's alerts let through individually
+// so that about target alerts per window still reach analysts
+func SampleRate(count, target int, minRate float64) float64 {
+	if count <= target {
+		return 1
+	}
+	return math.Max(minRate, float64(target)/float64(count))
+}
+
+// severityBoost raises the sampling odds of more severe alerts
+var severityBoost = map[string]float64{
+	"critical": 8,
+	"high":     4,
+	"medium":   2,
+	"low":      1,
+}
+
+// TriageScore is the probability that an alert is let through individually
+// during a storm: the storm's sample rate boosted by the alert's severity
+func TriageScore(severity string, sampleRate float64) float64 {
+	boost, ok := severityBoost[severity]
+	if !ok {
+		boost = 1
+	}
+	return math.Min(1, sampleRate*boost)
+}
+
+// Sampled decides whether the alert with the given key falls inside its
+// triage score. The decision is a hash of the key, so it is stable for a key.
+func Sampled(key string, score float64) bool {
+	if score >= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return float64(h.Sum64()%1000000)/1000000 < score
+}
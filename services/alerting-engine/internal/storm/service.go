@@ -0,0 +1,462 @@
+package storm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// Reconciliation actions
+const (
+	ActionExpand  = "expand"  // raise every held alert individually
+	ActionDismiss = "dismiss" // drop every held alert
+	ActionTriage  = "triage"  // raise held alerts at or above a severity, drop the rest
+)
+
+// severityOrder ranks alert severities from least to most severe
+var severityOrder = []string{"low", "medium", "high", "critical"}
+
+// ReconcileInput describes how a storm's held alerts are resolved
+type ReconcileInput struct {
+	Actor       string `json:"actor"`
+	Action      string `json:"action"`
+	MinSeverity string `json:"min_severity,omitempty"` // for triage; defaults to the configured severity
+}
+
+// SweepResult summarizes one pass of the post-storm process
+type SweepResult struct {
+	Ended      []*database.AlertStorm `json:"ended"`
+	Reconciled []*database.AlertStorm `json:"reconciled"`
+}
+
+// Service watches each rule's alert rate during alert creation. When a rule
+// storms it opens one aggregated storm alert, lets a severity-weighted sample
+// of the flood through individually and holds the rest, so that after the
+// storm the held alerts can be expanded or dismissed.
+type Service struct {
+	config   *config.Config
+	logger   *slog.Logger
+	repo     *database.AlertStormRepository
+	detector *Detector
+	mu       sync.Mutex
+	active   map[string]*database.AlertStorm // rule id to its active storm
+}
+
+// NewService creates a new alert storm service
+func NewService(cfg *config.Config, logger *slog.Logger, repo *database.AlertStormRepository) *Service {
+	return &Service{
+		config: cfg,
+		logger: logger,
+		repo:   repo,
+		detector: NewDetector(Options{
+			Window:            cfg.Storm.Window,
+			BaselineSmoothing: cfg.Storm.BaselineSmoothing,
+			MinAlerts:         cfg.Storm.MinAlerts,
+			RateMultiplier:    cfg.Storm.RateMultiplier,
+			QuietWindows:      cfg.Storm.QuietWindows,
+			TargetAdmitted:    cfg.Storm.TargetAdmitted,
+			MinSampleRate:     cfg.Storm.MinSampleRate,
+		}),
+		active: make(map[string]*database.AlertStorm),
+	}
+}
+
+// Admit decides whether an alert about to be created is raised individually.
+// Outside a storm every alert is admitted. During a storm, alerts that miss
+// the triage sample are held against the storm and false is returned.
+func (s *Service) Admit(ctx context.Context, alert *database.Alert, bundle *database.EvidenceBundle) (bool, error) {
+	if !s.config.Storm.Enabled {
+		return true, nil
+	}
+
+	decision := s.detector.Observe(alert.RuleID, time.Now())
+	if decision.Ended {
+		s.endStorm(ctx, alert.RuleID)
+	}
+	if !decision.Storm {
+		return true, nil
+	}
+
+	storm, err := s.activeStorm(ctx, alert, decision)
+	if err != nil {
+		return true, err
+	}
+
+	score := TriageScore(alert.Severity, decision.SampleRate)
+	key := alert.Fingerprint
+	if key == "" {
+		key = alert.ID
+	}
+
+	if Sampled(key, score) {
+		if alert.Metadata == nil {
+			alert.Metadata = make(map[string]interface{})
+		}
+		alert.Metadata["storm_id"] = storm.ID
+		alert.Metadata["storm_sample_rate"] = decision.SampleRate
+		alert.ParentAlertID = &storm.StormAlertID
+		return true, s.repo.RecordAdmitted(ctx, storm.ID, decision.Count)
+	}
+
+	held, err := newHeldAlert(storm, alert, bundle, score)
+	if err != nil {
+		return true, err
+	}
+	if err := s.repo.HoldAlert(ctx, held, decision.Count); err != nil {
+		return true, err
+	}
+
+	return false, nil
+}
+
+// activeStorm returns the rule's active storm, opening one with its storm
+// alert when the rule has just started storming
+func (s *Service) activeStorm(ctx context.Context, alert *database.Alert, decision Decision) (*database.AlertStorm, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if storm, ok := s.active[alert.RuleID]; ok {
+		return storm, nil
+	}
+
+	storm, err := s.repo.GetActiveStorm(ctx, alert.RuleID)
+	if err != nil {
+		return nil, err
+	}
+	if storm != nil {
+		s.active[alert.RuleID] = storm
+		return storm, nil
+	}
+
+	ruleName := alert.RuleName
+	if ruleName == "" {
+		ruleName = alert.RuleID
+	}
+
+	now := time.Now()
+	storm = &database.AlertStorm{
+		ID:           generateID("storm"),
+		RuleID:       alert.RuleID,
+		RuleName:     ruleName,
+		Status:       database.StormStatusActive,
+		StormAlertID: generateID("alert"),
+		BaselineRate: decision.Baseline,
+		Threshold:    decision.Threshold,
+		PeakRate:     decision.Count,
+		StartedAt:    now,
+		LastAlertAt:  now,
+	}
+
+	stormAlert := &database.Alert{
+		ID:       storm.StormAlertID,
+		RuleID:   alert.RuleID,
+		RuleName: ruleName,
+		Type:     "storm",
+		Severity: "high",
+		Priority: "high",
+		Status:   "active",
+		Title:    "Alert storm: " + ruleName,
+		Description: fmt.Sprintf("Rule %s is raising %d alerts per %s against a baseline of %.1f. "+
+			"A sample of individual alerts is still raised; the rest are held for reconciliation when the storm ends.",
+			ruleName, decision.Count, s.detector.opts.Window, decision.Baseline),
+		Source: "storm-detector",
+		Tags:   []string{"alert-storm"},
+		Metadata: map[string]interface{}{
+			"storm_id":      storm.ID,
+			"baseline_rate": decision.Baseline,
+			"threshold":     decision.Threshold,
+		},
+		Fingerprint: "storm:" + storm.ID,
+	}
+
+	if err := s.repo.CreateStorm(ctx, storm, stormAlert); err != nil {
+		if errors.Is(err, database.ErrStormActive) {
+			// Another instance opened the storm first
+			existing, getErr := s.repo.GetActiveStorm(ctx, alert.RuleID)
+			if getErr == nil && existing != nil {
+				s.active[alert.RuleID] = existing
+				return existing, nil
+			}
+		}
+		return nil, err
+	}
+
+	s.logger.Warn("Alert storm started",
+		"storm_id", storm.ID,
+		"rule_id", storm.RuleID,
+		"window_count", decision.Count,
+		"baseline", decision.Baseline,
+		"threshold", decision.Threshold)
+
+	s.active[alert.RuleID] = storm
+	return storm, nil
+}
+
+// endStorm closes the rule's active storm, if any
+func (s *Service) endStorm(ctx context.Context, ruleID string) *database.AlertStorm {
+	s.mu.Lock()
+	storm, ok := s.active[ruleID]
+	delete(s.active, ruleID)
+	s.mu.Unlock()
+
+	if !ok {
+		var err error
+		if storm, err = s.repo.GetActiveStorm(ctx, ruleID); err != nil || storm == nil {
+			return nil
+		}
+	}
+
+	ended, err := s.repo.EndStorm(ctx, storm.ID, s.summarize)
+	if err != nil {
+		if !errors.Is(err, database.ErrStormNotFound) {
+			s.logger.Error("Failed to end alert storm", "storm_id", storm.ID, "error", err)
+		}
+		return nil
+	}
+
+	return ended
+}
+
+// summarize describes a finished storm on its storm alert
+func (s *Service) summarize(storm *database.AlertStorm) string {
+	end := time.Now()
+	if storm.EndedAt != nil {
+		end = *storm.EndedAt
+	}
+
+	return fmt.Sprintf("Rule %s raised %d alerts between %s and %s, peaking at %d per %s against a baseline of %.1f. "+
+		"%d were raised individually and %d are held for reconciliation.",
+		storm.RuleName, storm.AlertCount,
+		storm.StartedAt.Format(time.RFC3339), end.Format(time.RFC3339),
+		storm.PeakRate, s.detector.opts.Window, storm.BaselineRate,
+		storm.AdmittedCount, storm.HeldCount)
+}
+
+// Sweep ends storms whose rules have gone quiet and, when enabled,
+// reconciles ended storms that no analyst reconciled in time
+func (s *Service) Sweep(ctx context.Context) (*SweepResult, error) {
+	cfg := s.config.Storm
+	result := &SweepResult{Ended: []*database.AlertStorm{}, Reconciled: []*database.AlertStorm{}}
+
+	for _, ruleID := range s.detector.Sweep(time.Now()) {
+		if storm := s.endStorm(ctx, ruleID); storm != nil {
+			result.Ended = append(result.Ended, storm)
+		}
+	}
+
+	// Storms this instance is not tracking, such as ones open before a restart
+	quiet, err := s.repo.ListQuietStorms(ctx, time.Now().Add(-time.Duration(cfg.QuietWindows)*s.detector.opts.Window))
+	if err != nil {
+		return nil, err
+	}
+	for _, storm := range quiet {
+		if s.detector.Storming(storm.RuleID) {
+			continue
+		}
+		if ended := s.endStorm(ctx, storm.RuleID); ended != nil {
+			result.Ended = append(result.Ended, ended)
+		}
+	}
+
+	if !cfg.AutoReconcile {
+		return result, nil
+	}
+
+	pending, err := s.repo.ListEndedStorms(ctx, time.Now().Add(-cfg.AutoReconcileDelay), 10)
+	if err != nil {
+		return nil, err
+	}
+	for _, storm := range pending {
+		reconciled, err := s.Reconcile(ctx, storm.ID, ReconcileInput{Actor: "system", Action: ActionTriage})
+		if err != nil {
+			s.logger.Error("Failed to reconcile alert storm", "storm_id", storm.ID, "error", err)
+			continue
+		}
+		result.Reconciled = append(result.Reconciled, reconciled)
+	}
+
+	return result, nil
+}
+
+// ValidateReconcileInput checks a reconciliation request before any alert is touched
+func ValidateReconcileInput(input ReconcileInput) error {
+	if strings.TrimSpace(input.Actor) == "" {
+		return fmt.Errorf("actor is required")
+	}
+
+	switch input.Action {
+	case ActionExpand, ActionDismiss, ActionTriage:
+	default:
+		return fmt.Errorf("action must be one of %s, %s or %s", ActionExpand, ActionDismiss, ActionTriage)
+	}
+
+	if input.MinSeverity != "" && SeveritiesAtLeast(input.MinSeverity) == nil {
+		return fmt.Errorf("unsupported min_severity %q", input.MinSeverity)
+	}
+
+	return nil
+}
+
+// Reconcile resolves an ended storm's held alerts: expanded alerts are
+// created individually under the storm alert, the rest are dismissed, and
+// the storm alert is resolved
+func (s *Service) Reconcile(ctx context.Context, id string, input ReconcileInput) (*database.AlertStorm, error) {
+	if err := ValidateReconcileInput(input); err != nil {
+		return nil, err
+	}
+
+	storm, err := s.repo.ClaimReconciliation(ctx, id, input.Actor, input.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reconcile(ctx, storm, input); err != nil {
+		if releaseErr := s.repo.ReleaseReconciliation(ctx, id); releaseErr != nil {
+			s.logger.Error("Failed to release alert storm reconciliation", "storm_id", id, "error", releaseErr)
+		}
+		return nil, err
+	}
+
+	return s.repo.CompleteReconciliation(ctx, id, func(storm *database.AlertStorm) string {
+		return fmt.Sprintf("Storm reconciled (%s) by %s: %d held alerts expanded, %d dismissed",
+			input.Action, input.Actor, storm.ExpandedCount, storm.DismissedCount)
+	})
+}
+
+func (s *Service) reconcile(ctx context.Context, storm *database.AlertStorm, input ReconcileInput) error {
+	if input.Action != ActionDismiss {
+		var severities []string
+		if input.Action == ActionTriage {
+			minSeverity := input.MinSeverity
+			if minSeverity == "" {
+				minSeverity = s.config.Storm.ExpandMinSeverity
+			}
+			severities = SeveritiesAtLeast(minSeverity)
+		}
+
+		batchSize := s.config.Storm.ReconcileBatchSize
+		if batchSize <= 0 {
+			batchSize = 500
+		}
+
+		for {
+			batch, err := s.repo.ListHeldAlerts(ctx, storm.ID, database.HeldAlertStatusHeld, severities, batchSize)
+			if err != nil {
+				return err
+			}
+			for _, held := range batch {
+				if err := s.expand(ctx, storm, held); err != nil {
+					return err
+				}
+			}
+			if len(batch) < batchSize {
+				break
+			}
+		}
+	}
+
+	_, err := s.repo.DismissHeldAlerts(ctx, storm.ID)
+	return err
+}
+
+// expand creates a held alert individually, linked to the storm alert
+func (s *Service) expand(ctx context.Context, storm *database.AlertStorm, held *database.HeldAlert) error {
+	var alert database.Alert
+	if err := json.Unmarshal(held.AlertData, &alert); err != nil {
+		return fmt.Errorf("failed to decode held alert %s: %w", held.ID, err)
+	}
+
+	var bundle *database.EvidenceBundle
+	if len(held.EvidenceData) > 0 && string(held.EvidenceData) != "null" {
+		bundle = &database.EvidenceBundle{}
+		if err := json.Unmarshal(held.EvidenceData, bundle); err != nil {
+			return fmt.Errorf("failed to decode held alert %s evidence: %w", held.ID, err)
+		}
+	}
+
+	if alert.Metadata == nil {
+		alert.Metadata = make(map[string]interface{})
+	}
+	alert.Metadata["storm_id"] = storm.ID
+	alert.Metadata["storm_expanded"] = true
+	alert.ParentAlertID = &storm.StormAlertID
+
+	return s.repo.ExpandHeldAlert(ctx, held, &alert, bundle)
+}
+
+// Storms
+
+// ListStorms returns storms, optionally in one status
+func (s *Service) ListStorms(ctx context.Context, status string, limit int) ([]*database.AlertStorm, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	return s.repo.ListStorms(ctx, status, limit)
+}
+
+// GetStorm returns a storm by ID
+func (s *Service) GetStorm(ctx context.Context, id string) (*database.AlertStorm, error) {
+	return s.repo.GetStorm(ctx, id)
+}
+
+// ListHeldAlerts returns a storm's held alerts, highest triage score first
+func (s *Service) ListHeldAlerts(ctx context.Context, id, status string, limit int) ([]*database.HeldAlert, error) {
+	if _, err := s.repo.GetStorm(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	return s.repo.ListHeldAlerts(ctx, id, status, nil, limit)
+}
+
+// Rates returns the current alert rate of every rule this instance tracks
+func (s *Service) Rates() []*RuleRate {
+	return s.detector.Rates()
+}
+
+func newHeldAlert(storm *database.AlertStorm, alert *database.Alert, bundle *database.EvidenceBundle, score float64) (*database.HeldAlert, error) {
+	alertData, err := json.Marshal(alert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode held alert: %w", err)
+	}
+	evidenceData, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode held alert evidence: %w", err)
+	}
+
+	return &database.HeldAlert{
+		ID:           alert.ID,
+		StormID:      storm.ID,
+		RuleID:       alert.RuleID,
+		Severity:     alert.Severity,
+		Title:        alert.Title,
+		TriageScore:  score,
+		AlertData:    alertData,
+		EvidenceData: evidenceData,
+		Status:       database.HeldAlertStatusHeld,
+	}, nil
+}
+
+// SeveritiesAtLeast returns the severities at or above the given one, or nil
+// for an unknown severity
+func SeveritiesAtLeast(severity string) []string {
+	for i, s := range severityOrder {
+		if s == severity {
+			return append([]string(nil), severityOrder[i:]...)
+		}
+	}
+	return nil
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
@@ -0,0 +1,121 @@
+package training
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Anonymizer replaces identifying values with stable pseudonyms. The same
+// input value always maps to the same pseudonym within one anonymizer, so
+// relationships between alerts in a session survive anonymization.
+type Anonymizer struct {
+	key             []byte
+	sensitiveFields map[string]bool
+	pseudonyms      map[string]string
+}
+
+// NewAnonymizer creates an anonymizer keyed by a per-session secret
+func NewAnonymizer(key string, sensitiveFields []string) *Anonymizer {
+	fields := make(map[string]bool, len(sensitiveFields))
+	for _, field := range sensitiveFields {
+		fields[strings.ToLower(field)] = true
+	}
+
+	return &Anonymizer{
+		key:             []byte(key),
+		sensitiveFields: fields,
+		pseudonyms:      make(map[string]string),
+	}
+}
+
+// Pseudonym returns the stable pseudonym for a value, prefixed by kind
+func (a *Anonymizer) Pseudonym(kind, value string) string {
+	if value == "" {
+		return ""
+	}
+
+	cacheKey := kind + ":" + value
+	if pseudonym, ok := a.pseudonyms[cacheKey]; ok {
+		return pseudonym
+	}
+
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(cacheKey))
+	pseudonym := fmt.Sprintf("%s_%s", kind, hex.EncodeToString(mac.Sum(nil))[:12])
+
+	a.pseudonyms[cacheKey] = pseudonym
+	return pseudonym
+}
+
+// AnonymizeMap returns a deep copy of data with sensitive fields pseudonymized
+func (a *Anonymizer) AnonymizeMap(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+
+	result := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if a.sensitiveFields[strings.ToLower(key)] {
+			result[key] = a.anonymizeSensitive(key, value)
+			continue
+		}
+		result[key] = a.anonymizeValue(value)
+	}
+
+	return result
+}
+
+// AnonymizeText replaces every known identifier occurring in free text
+func (a *Anonymizer) AnonymizeText(text string, identifiers []string) string {
+	for _, identifier := range identifiers {
+		if identifier == "" {
+			continue
+		}
+		text = strings.ReplaceAll(text, identifier, a.Pseudonym("entity", identifier))
+	}
+	return text
+}
+
+func (a *Anonymizer) anonymizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return a.AnonymizeMap(v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = a.anonymizeValue(item)
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+func (a *Anonymizer) anonymizeSensitive(field string, value interface{}) interface{} {
+	kind := strings.ToLower(field)
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return a.Pseudonym(kind, v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = a.anonymizeSensitive(field, item)
+		}
+		return items
+	case map[string]interface{}:
+		// Every leaf of a sensitive object is treated as sensitive
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = a.anonymizeSensitive(field+"_"+key, item)
+		}
+		return result
+	default:
+		return a.Pseudonym(kind, fmt.Sprintf("%v", v))
+	}
+}
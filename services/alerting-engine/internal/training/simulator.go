@@ -0,0 +1,592 @@
+package training
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	mrand "math/rand"
+	"strings"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// Canonical dispositions used to score trainee answers
+const (
+	DispositionTruePositive  = "true_positive"
+	DispositionFalsePositive = "false_positive"
+	DispositionEscalated     = "escalated"
+	DispositionDuplicate     = "duplicate"
+	DispositionBenign        = "benign"
+)
+
+// Session statuses
+const (
+	SessionStatusActive    = "active"
+	SessionStatusCompleted = "completed"
+	SessionStatusAbandoned = "abandoned"
+)
+
+// candidateMultiplier controls how many historical alerts and cases are
+// sampled from before picking the scenarios of a session
+const candidateMultiplier = 5
+
+// Simulator replays historical alerts and alert cases into sandbox training
+// sessions and scores trainee dispositions against the recorded outcomes
+type Simulator struct {
+	config       *config.Config
+	logger       *slog.Logger
+	alertRepo    *database.AlertRepository
+	caseRepo     *database.AlertCaseRepository
+	trainingRepo *database.TrainingRepository
+}
+
+// StartSessionRequest describes a new training session
+type StartSessionRequest struct {
+	TraineeID     string    `json:"trainee_id"`
+	CreatedBy     string    `json:"created_by"`
+	ScenarioCount int       `json:"scenario_count"`
+	ReplayFrom    time.Time `json:"replay_from"`
+	ReplayTo      time.Time `json:"replay_to"`
+	Severities    []string  `json:"severities,omitempty"`
+	// ScenarioTypes selects whether alerts, cases or both are replayed;
+	// empty replays both
+	ScenarioTypes []string `json:"scenario_types,omitempty"`
+}
+
+// Answer is a trainee triage decision for one scenario
+type Answer struct {
+	Disposition string `json:"disposition"`
+	Severity    string `json:"severity,omitempty"`
+	Notes       string `json:"notes,omitempty"`
+}
+
+// AnswerResult is returned after an answer has been scored
+type AnswerResult struct {
+	ScenarioID            string  `json:"scenario_id"`
+	Correct               bool    `json:"correct"`
+	Points                float64 `json:"points"`
+	TraineeDisposition    string  `json:"trainee_disposition"`
+	HistoricalDisposition string  `json:"historical_disposition"`
+	SeverityMatched       bool    `json:"severity_matched"`
+	HistoricalSeverity    string  `json:"historical_severity,omitempty"`
+}
+
+// SessionReport summarizes a trainee's performance
+type SessionReport struct {
+	Session          *database.TrainingSession   `json:"session"`
+	Accuracy         float64                     `json:"accuracy"`
+	SeverityAccuracy float64                     `json:"severity_accuracy"`
+	AverageResponse  time.Duration               `json:"average_response_ns"`
+	ByDisposition    map[string]*DispositionStat `json:"by_disposition"`
+	Unanswered       int                         `json:"unanswered"`
+}
+
+// DispositionStat holds accuracy for one historical disposition
+type DispositionStat struct {
+	Total   int `json:"total"`
+	Correct int `json:"correct"`
+}
+
+// NewSimulator creates a new training simulator
+func NewSimulator(
+	cfg *config.Config,
+	logger *slog.Logger,
+	alertRepo *database.AlertRepository,
+	caseRepo *database.AlertCaseRepository,
+	trainingRepo *database.TrainingRepository,
+) *Simulator {
+	return &Simulator{
+		config:       cfg,
+		logger:       logger,
+		alertRepo:    alertRepo,
+		caseRepo:     caseRepo,
+		trainingRepo: trainingRepo,
+	}
+}
+
+// StartSession samples historical alerts and cases, anonymizes them and
+// stores them as scenarios of a new session in an isolated sandbox tenant
+func (s *Simulator) StartSession(ctx context.Context, req StartSessionRequest) (*database.TrainingSession, error) {
+	if !s.config.Training.Enabled {
+		return nil, fmt.Errorf("training mode is disabled")
+	}
+	if req.TraineeID == "" {
+		return nil, fmt.Errorf("trainee_id is required")
+	}
+
+	count := req.ScenarioCount
+	if count <= 0 {
+		count = s.config.Training.DefaultScenarioCount
+	}
+	if count > s.config.Training.MaxScenarioCount {
+		count = s.config.Training.MaxScenarioCount
+	}
+
+	if req.ReplayTo.IsZero() {
+		req.ReplayTo = time.Now()
+	}
+	if req.ReplayFrom.IsZero() {
+		req.ReplayFrom = req.ReplayTo.Add(-s.config.Training.DefaultReplayWindow)
+	}
+	if !req.ReplayFrom.Before(req.ReplayTo) {
+		return nil, fmt.Errorf("replay_from must be before replay_to")
+	}
+
+	types, err := scenarioTypes(req.ScenarioTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []candidate
+	if types[database.TrainingScenarioAlert] {
+		alerts, err := s.alertRepo.ListResolvedBetween(ctx, req.ReplayFrom, req.ReplayTo, req.Severities, count*candidateMultiplier)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load historical alerts: %w", err)
+		}
+		for _, alert := range alerts {
+			candidates = append(candidates, candidate{alert: alert})
+		}
+	}
+	if types[database.TrainingScenarioCase] {
+		cases, err := s.caseRepo.ListDecidedCasesBetween(ctx, req.ReplayFrom, req.ReplayTo, req.Severities, count*candidateMultiplier)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load historical cases: %w", err)
+		}
+		for _, alertCase := range cases {
+			candidates = append(candidates, candidate{alertCase: alertCase})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no resolved alerts or decided cases available in the replay window")
+	}
+
+	mrand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if len(candidates) > count {
+		candidates = candidates[:count]
+	}
+
+	sessionID := generateID("training")
+	session := &database.TrainingSession{
+		ID:              sessionID,
+		TraineeID:       req.TraineeID,
+		SandboxTenantID: fmt.Sprintf("%s-%s", s.config.Training.SandboxTenantPrefix, sessionID),
+		Status:          SessionStatusActive,
+		ReplayFrom:      req.ReplayFrom,
+		ReplayTo:        req.ReplayTo,
+		SeverityFilter:  req.Severities,
+		ScenarioTypes:   req.ScenarioTypes,
+		CreatedBy:       req.CreatedBy,
+	}
+
+	anonymizer := NewAnonymizer(randomKey(), s.config.Training.SensitiveFields)
+	scenarios := make([]*database.TrainingScenario, 0, len(candidates))
+	for i, c := range candidates {
+		scenario := &database.TrainingScenario{
+			ID:       generateID("scenario"),
+			Sequence: i + 1,
+		}
+
+		if c.alert != nil {
+			severity := c.alert.Severity
+			scenario.ScenarioType = database.TrainingScenarioAlert
+			scenario.SourceAlertID = &c.alert.ID
+			scenario.AnonymizedAlert = s.anonymizeAlert(anonymizer, c.alert, session.SandboxTenantID)
+			scenario.HistoricalDisposition = NormalizeDisposition(*c.alert.ResolutionReason)
+			scenario.HistoricalSeverity = &severity
+		} else {
+			alerts, err := s.caseRepo.ListCaseAlerts(ctx, c.alertCase.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load historical case alerts: %w", err)
+			}
+			severity := c.alertCase.MaxSeverity
+			scenario.ScenarioType = database.TrainingScenarioCase
+			scenario.SourceCaseID = &c.alertCase.ID
+			scenario.AnonymizedCase = AnonymizeCase(anonymizer, c.alertCase, alerts, session.SandboxTenantID)
+			scenario.HistoricalDisposition = CaseDisposition(c.alertCase)
+			scenario.HistoricalSeverity = &severity
+		}
+
+		scenarios = append(scenarios, scenario)
+	}
+
+	if err := s.trainingRepo.CreateSession(ctx, session, scenarios); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Training session started",
+		"session_id", session.ID,
+		"trainee_id", session.TraineeID,
+		"sandbox_tenant_id", session.SandboxTenantID,
+		"scenarios", len(scenarios))
+
+	return session, nil
+}
+
+// NextScenario returns the next unanswered scenario of an active session
+// with the historical outcome hidden, or nil once all are answered
+func (s *Simulator) NextScenario(ctx context.Context, sessionID string) (*database.TrainingScenario, error) {
+	session, err := s.trainingRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != SessionStatusActive {
+		return nil, fmt.Errorf("training session is %s", session.Status)
+	}
+
+	scenarios, err := s.trainingRepo.ListScenarios(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scenario := range scenarios {
+		if scenario.AnsweredAt != nil {
+			continue
+		}
+
+		if err := s.trainingRepo.MarkScenarioPresented(ctx, scenario.ID); err != nil {
+			s.logger.Warn("Failed to mark scenario presented", "scenario_id", scenario.ID, "error", err)
+		}
+		return hideOutcome(scenario), nil
+	}
+
+	return nil, nil
+}
+
+// SubmitAnswer scores a trainee answer against the historical disposition
+func (s *Simulator) SubmitAnswer(ctx context.Context, sessionID, scenarioID string, answer Answer) (*AnswerResult, error) {
+	disposition := NormalizeDisposition(answer.Disposition)
+	if !isKnownDisposition(disposition) {
+		return nil, fmt.Errorf("unknown disposition: %s", answer.Disposition)
+	}
+
+	session, err := s.trainingRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != SessionStatusActive {
+		return nil, fmt.Errorf("training session is %s", session.Status)
+	}
+
+	scenario, err := s.trainingRepo.GetScenario(ctx, sessionID, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+	if scenario.AnsweredAt != nil {
+		return nil, fmt.Errorf("scenario already answered: %s", scenarioID)
+	}
+
+	points := ScoreDisposition(disposition, scenario.HistoricalDisposition)
+	correct := points == 1
+
+	historicalSeverity := ""
+	if scenario.HistoricalSeverity != nil {
+		historicalSeverity = *scenario.HistoricalSeverity
+	}
+
+	now := time.Now()
+	scenario.TraineeDisposition = &disposition
+	scenario.Points = &points
+	scenario.AnsweredAt = &now
+	if answer.Severity != "" {
+		scenario.TraineeSeverity = &answer.Severity
+	}
+	if answer.Notes != "" {
+		scenario.TraineeNotes = &answer.Notes
+	}
+
+	if err := s.trainingRepo.RecordAnswer(ctx, scenario, correct); err != nil {
+		return nil, err
+	}
+
+	return &AnswerResult{
+		ScenarioID:            scenario.ID,
+		Correct:               correct,
+		Points:                points,
+		TraineeDisposition:    disposition,
+		HistoricalDisposition: scenario.HistoricalDisposition,
+		SeverityMatched:       answer.Severity != "" && strings.EqualFold(answer.Severity, historicalSeverity),
+		HistoricalSeverity:    historicalSeverity,
+	}, nil
+}
+
+// CompleteSession closes a session and returns its final report
+func (s *Simulator) CompleteSession(ctx context.Context, sessionID string) (*SessionReport, error) {
+	if err := s.trainingRepo.CompleteSession(ctx, sessionID, SessionStatusCompleted); err != nil {
+		return nil, err
+	}
+	return s.Report(ctx, sessionID)
+}
+
+// AbandonSession closes a session without completing it
+func (s *Simulator) AbandonSession(ctx context.Context, sessionID string) error {
+	return s.trainingRepo.CompleteSession(ctx, sessionID, SessionStatusAbandoned)
+}
+
+// Report builds a performance report for a session
+func (s *Simulator) Report(ctx context.Context, sessionID string) (*SessionReport, error) {
+	session, err := s.trainingRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	scenarios, err := s.trainingRepo.ListScenarios(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SessionReport{
+		Session:       session,
+		ByDisposition: make(map[string]*DispositionStat),
+	}
+
+	var answered, correct, severityAnswered, severityCorrect int
+	var totalResponse time.Duration
+	var timedResponses int
+
+	for _, scenario := range scenarios {
+		stat, ok := report.ByDisposition[scenario.HistoricalDisposition]
+		if !ok {
+			stat = &DispositionStat{}
+			report.ByDisposition[scenario.HistoricalDisposition] = stat
+		}
+		stat.Total++
+
+		if scenario.AnsweredAt == nil {
+			report.Unanswered++
+			continue
+		}
+
+		answered++
+		if scenario.Points != nil && *scenario.Points == 1 {
+			correct++
+			stat.Correct++
+		}
+
+		if scenario.TraineeSeverity != nil && scenario.HistoricalSeverity != nil {
+			severityAnswered++
+			if strings.EqualFold(*scenario.TraineeSeverity, *scenario.HistoricalSeverity) {
+				severityCorrect++
+			}
+		}
+
+		if scenario.PresentedAt != nil {
+			totalResponse += scenario.AnsweredAt.Sub(*scenario.PresentedAt)
+			timedResponses++
+		}
+	}
+
+	if answered > 0 {
+		report.Accuracy = float64(correct) / float64(answered)
+	}
+	if severityAnswered > 0 {
+		report.SeverityAccuracy = float64(severityCorrect) / float64(severityAnswered)
+	}
+	if timedResponses > 0 {
+		report.AverageResponse = totalResponse / time.Duration(timedResponses)
+	}
+
+	return report, nil
+}
+
+// ListSessions returns recent sessions, optionally for a single trainee
+func (s *Simulator) ListSessions(ctx context.Context, traineeID string, limit int) ([]*database.TrainingSession, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	return s.trainingRepo.ListSessions(ctx, traineeID, limit)
+}
+
+// anonymizeAlert builds the trainee-facing copy of a historical alert
+func (s *Simulator) anonymizeAlert(anonymizer *Anonymizer, alert *database.Alert, tenantID string) database.JSONB {
+	entityIDs := make([]string, len(alert.EntityIDs))
+	for i, entityID := range alert.EntityIDs {
+		entityIDs[i] = anonymizer.Pseudonym("entity", entityID)
+	}
+
+	// Drop any recorded outcome so it cannot leak to the trainee
+	metadata := anonymizer.AnonymizeMap(alert.Metadata)
+	for _, key := range []string{"resolution", "resolution_reason", "disposition", "case_outcome", "resolved_by"} {
+		delete(metadata, key)
+	}
+
+	return database.JSONB{
+		"id":           anonymizer.Pseudonym("alert", alert.ID),
+		"tenant_id":    tenantID,
+		"rule_name":    alert.RuleName,
+		"type":         alert.Type,
+		"priority":     alert.Priority,
+		"title":        anonymizer.AnonymizeText(alert.Title, alert.EntityIDs),
+		"description":  anonymizer.AnonymizeText(alert.Description, alert.EntityIDs),
+		"source":       alert.Source,
+		"source_event": anonymizer.AnonymizeMap(alert.SourceEvent),
+		"entity_ids":   entityIDs,
+		"tags":         alert.Tags,
+		"metadata":     metadata,
+		"created_at":   alert.CreatedAt,
+	}
+}
+
+// AnonymizeCase builds the trainee-facing copy of a historical alert case
+// and its member alerts. How the case was decided is left out.
+func AnonymizeCase(anonymizer *Anonymizer, alertCase *database.AlertCase, alerts []*database.AlertCaseAlert, tenantID string) database.JSONB {
+	var identifiers []string
+	for _, alert := range alerts {
+		identifiers = append(identifiers, alert.EntityIDs...)
+	}
+
+	members := make([]map[string]interface{}, len(alerts))
+	for i, alert := range alerts {
+		entityIDs := make([]string, len(alert.EntityIDs))
+		for j, entityID := range alert.EntityIDs {
+			entityIDs[j] = anonymizer.Pseudonym("entity", entityID)
+		}
+		members[i] = map[string]interface{}{
+			"id":           anonymizer.Pseudonym("alert", alert.AlertID),
+			"title":        anonymizer.AnonymizeText(alert.Title, alert.EntityIDs),
+			"severity":     alert.Severity,
+			"entity_ids":   entityIDs,
+			"matched_keys": pseudonymizeKeys(anonymizer, alert.MatchedKeys),
+			"created_at":   alert.CreatedAt,
+		}
+	}
+
+	return database.JSONB{
+		"id":               anonymizer.Pseudonym("case", alertCase.ID),
+		"tenant_id":        tenantID,
+		"title":            anonymizer.AnonymizeText(alertCase.Title, identifiers),
+		"correlation_keys": pseudonymizeKeys(anonymizer, alertCase.CorrelationKeys),
+		"alert_count":      alertCase.AlertCount,
+		"max_severity":     alertCase.MaxSeverity,
+		"first_alert_at":   alertCase.FirstAlertAt,
+		"last_alert_at":    alertCase.LastAlertAt,
+		"alerts":           members,
+	}
+}
+
+// CaseDisposition is the historical disposition of a decided case: cases
+// linked to an investigation were escalated, closed cases take their close
+// reason
+func CaseDisposition(alertCase *database.AlertCase) string {
+	if alertCase.Status == database.AlertCaseStatusLinked || alertCase.InvestigationID != nil {
+		return DispositionEscalated
+	}
+	if alertCase.CloseReason == nil {
+		return DispositionBenign
+	}
+	return NormalizeDisposition(*alertCase.CloseReason)
+}
+
+// pseudonymizeKeys replaces the identifier of correlation keys, which have
+// the form kind:identifier, keeping the kind
+func pseudonymizeKeys(anonymizer *Anonymizer, keys []string) []string {
+	pseudonyms := make([]string, len(keys))
+	for i, key := range keys {
+		kind, identifier, found := strings.Cut(key, ":")
+		if !found {
+			pseudonyms[i] = anonymizer.Pseudonym("key", key)
+			continue
+		}
+		pseudonyms[i] = kind + ":" + anonymizer.Pseudonym(kind, identifier)
+	}
+	return pseudonyms
+}
+
+// NormalizeDisposition maps free-text resolution reasons onto the canonical
+// dispositions used for scoring
+func NormalizeDisposition(reason string) string {
+	normalized := strings.ToLower(strings.TrimSpace(reason))
+	normalized = strings.NewReplacer("-", "_", " ", "_").Replace(normalized)
+
+	switch {
+	case normalized == "":
+		return DispositionBenign
+	case strings.Contains(normalized, "false_positive"), strings.Contains(normalized, "not_suspicious"):
+		return DispositionFalsePositive
+	case strings.Contains(normalized, "true_positive"), strings.Contains(normalized, "confirmed"),
+		strings.Contains(normalized, "sar_filed"), strings.Contains(normalized, "suspicious"):
+		return DispositionTruePositive
+	case strings.Contains(normalized, "escalat"), strings.Contains(normalized, "case_opened"):
+		return DispositionEscalated
+	case strings.Contains(normalized, "duplicate"):
+		return DispositionDuplicate
+	default:
+		return DispositionBenign
+	}
+}
+
+// ScoreDisposition returns the points awarded for a trainee disposition.
+// Escalating an alert that turned out to be a true positive earns partial
+// credit since it is the conservative choice.
+func ScoreDisposition(trainee, historical string) float64 {
+	switch {
+	case trainee == historical:
+		return 1
+	case trainee == DispositionEscalated && historical == DispositionTruePositive:
+		return 0.5
+	case trainee == DispositionTruePositive && historical == DispositionEscalated:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+// candidate is a historical alert or case a scenario may be made from
+type candidate struct {
+	alert     *database.Alert
+	alertCase *database.AlertCase
+}
+
+// scenarioTypes validates the requested scenario types, defaulting to
+// alerts and cases
+func scenarioTypes(requested []string) (map[string]bool, error) {
+	if len(requested) == 0 {
+		return map[string]bool{database.TrainingScenarioAlert: true, database.TrainingScenarioCase: true}, nil
+	}
+
+	types := make(map[string]bool, len(requested))
+	for _, scenarioType := range requested {
+		switch scenarioType {
+		case database.TrainingScenarioAlert, database.TrainingScenarioCase:
+			types[scenarioType] = true
+		default:
+			return nil, fmt.Errorf("unknown scenario type: %s", scenarioType)
+		}
+	}
+	return types, nil
+}
+
+func isKnownDisposition(disposition string) bool {
+	switch disposition {
+	case DispositionTruePositive, DispositionFalsePositive, DispositionEscalated,
+		DispositionDuplicate, DispositionBenign:
+		return true
+	default:
+		return false
+	}
+}
+
+// hideOutcome strips the historical outcome before a scenario is shown
+func hideOutcome(scenario *database.TrainingScenario) *database.TrainingScenario {
+	visible := *scenario
+	visible.HistoricalDisposition = ""
+	visible.HistoricalSeverity = nil
+	return &visible
+}
+
+func randomKey() string {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
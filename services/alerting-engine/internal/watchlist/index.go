@@ -0,0 +1,54 @@
+package watchlist
+
+import (
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// ExclusionIndex is an in-memory lookup of active exclusion list entries used
+// on the alert path, so suppression checks never hit the database
+type ExclusionIndex struct {
+	entries map[string]*database.WatchlistEntry
+}
+
+// NewExclusionIndex builds an index from active exclusion entries
+func NewExclusionIndex(entries []*database.WatchlistEntry) *ExclusionIndex {
+	index := &ExclusionIndex{entries: make(map[string]*database.WatchlistEntry, len(entries))}
+	for _, entry := range entries {
+		if entry.ListType != database.ListTypeExclusion || entry.Status != database.EntryStatusActive {
+			continue
+		}
+		index.entries[entry.EntityID] = entry
+	}
+	return index
+}
+
+// Match returns the first entry excluding any of the given entities at the given time.
+// Entries past their expiry never match, even before the expiry job has closed them.
+func (i *ExclusionIndex) Match(entityIDs []string, at time.Time) (*database.WatchlistEntry, bool) {
+	if i == nil {
+		return nil, false
+	}
+
+	for _, entityID := range entityIDs {
+		entry, ok := i.entries[entityID]
+		if !ok {
+			continue
+		}
+		if entry.ExpiresAt != nil && !at.Before(*entry.ExpiresAt) {
+			continue
+		}
+		return entry, true
+	}
+
+	return nil, false
+}
+
+// Len returns the number of indexed entries
+func (i *ExclusionIndex) Len() int {
+	if i == nil {
+		return 0
+	}
+	return len(i.entries)
+}
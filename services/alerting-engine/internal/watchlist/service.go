@@ -0,0 +1,418 @@
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// expiringSoonWindow is how far ahead the auditor report flags upcoming expiries
+const expiringSoonWindow = 14 * 24 * time.Hour
+
+var (
+	// ErrSelfApproval is returned when the requester tries to review their own change
+	ErrSelfApproval = errors.New("changes must be reviewed by a different user than the requester")
+	// ErrNotPending is returned when a change request has already been decided or has lapsed
+	ErrNotPending = errors.New("change request is no longer pending")
+)
+
+// Service enforces dual control on watchlist and exclusion list changes and
+// answers exclusion lookups for the alert path
+type Service struct {
+	config *config.Config
+	logger *slog.Logger
+	repo   *database.WatchlistRepository
+
+	mu    sync.RWMutex
+	index *ExclusionIndex
+}
+
+// ChangeRequestInput describes a requested list addition or removal
+type ChangeRequestInput struct {
+	ListType      string     `json:"list_type"`
+	Action        string     `json:"action"`
+	EntityID      string     `json:"entity_id"`
+	EntityType    string     `json:"entity_type,omitempty"`
+	EntryID       string     `json:"entry_id,omitempty"`
+	Justification string     `json:"justification"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	RequestedBy   string     `json:"requested_by"`
+}
+
+// ExclusionReport lists every active exclusion for auditors
+type ExclusionReport struct {
+	GeneratedAt   time.Time              `json:"generated_at"`
+	TotalActive   int                    `json:"total_active"`
+	ExpiringSoon  int                    `json:"expiring_soon"`
+	WithoutExpiry int                    `json:"without_expiry"`
+	Entries       []*ExclusionReportItem `json:"entries"`
+}
+
+// ExclusionReportItem is a single active exclusion with its approval trail
+type ExclusionReportItem struct {
+	*database.WatchlistEntry
+	DaysRemaining *int `json:"days_remaining,omitempty"`
+	ExpiringSoon  bool `json:"expiring_soon"`
+}
+
+// ExpiryResult summarizes a scheduled expiry run
+type ExpiryResult struct {
+	ExpiredEntries int   `json:"expired_entries"`
+	LapsedChanges  int64 `json:"lapsed_changes"`
+}
+
+// NewService creates a new watchlist service
+func NewService(cfg *config.Config, logger *slog.Logger, repo *database.WatchlistRepository) *Service {
+	return &Service{
+		config: cfg,
+		logger: logger,
+		repo:   repo,
+		index:  NewExclusionIndex(nil),
+	}
+}
+
+// RequestChange queues a list addition or removal; nothing takes effect until
+// a second user approves it
+func (s *Service) RequestChange(ctx context.Context, input ChangeRequestInput) (*database.WatchlistChangeRequest, error) {
+	if input.RequestedBy == "" {
+		return nil, fmt.Errorf("requested_by is required")
+	}
+
+	if input.ListType != database.ListTypeWatchlist && input.ListType != database.ListTypeExclusion {
+		return nil, fmt.Errorf("list_type must be %s or %s", database.ListTypeWatchlist, database.ListTypeExclusion)
+	}
+
+	justification := strings.TrimSpace(input.Justification)
+	if len(justification) < s.config.Watchlist.MinJustificationLength {
+		return nil, fmt.Errorf("justification must be at least %d characters", s.config.Watchlist.MinJustificationLength)
+	}
+
+	now := time.Now()
+	change := &database.WatchlistChangeRequest{
+		ID:            generateID("wlchg"),
+		ListType:      input.ListType,
+		Action:        input.Action,
+		Justification: justification,
+		Status:        database.ChangeStatusPending,
+		RequestedBy:   input.RequestedBy,
+		RequestedAt:   now,
+		PendingUntil:  now.Add(s.config.Watchlist.PendingChangeTTL),
+	}
+
+	switch input.Action {
+	case database.WatchlistActionAdd:
+		if input.EntityID == "" {
+			return nil, fmt.Errorf("entity_id is required")
+		}
+
+		existing, err := s.repo.FindActiveEntry(ctx, input.ListType, input.EntityID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, fmt.Errorf("entity %s is already on the %s list", input.EntityID, input.ListType)
+		}
+
+		expiresAt, err := s.resolveExpiry(input.ListType, input.ExpiresAt, now)
+		if err != nil {
+			return nil, err
+		}
+
+		change.EntityID = input.EntityID
+		change.EntryExpiresAt = expiresAt
+		if input.EntityType != "" {
+			change.EntityType = &input.EntityType
+		}
+
+	case database.WatchlistActionRemove:
+		if input.EntryID == "" {
+			return nil, fmt.Errorf("entry_id is required for removals")
+		}
+
+		entry, err := s.repo.GetEntry(ctx, input.EntryID)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Status != database.EntryStatusActive || entry.ListType != input.ListType {
+			return nil, fmt.Errorf("entry %s is not an active %s entry", input.EntryID, input.ListType)
+		}
+
+		change.EntityID = entry.EntityID
+		change.EntityType = entry.EntityType
+		change.EntryID = &entry.ID
+
+	default:
+		return nil, fmt.Errorf("action must be %s or %s", database.WatchlistActionAdd, database.WatchlistActionRemove)
+	}
+
+	pending, err := s.repo.HasPendingChange(ctx, change.ListType, change.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, fmt.Errorf("entity %s already has a pending %s list change", change.EntityID, change.ListType)
+	}
+
+	if err := s.repo.CreateChangeRequest(ctx, change); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// Approve applies a pending change on behalf of a second user
+func (s *Service) Approve(ctx context.Context, changeID, reviewerID, comment string) (*database.WatchlistChangeRequest, error) {
+	change, err := s.pendingChange(ctx, changeID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry *database.WatchlistEntry
+	if change.Action == database.WatchlistActionAdd {
+		entry = &database.WatchlistEntry{
+			ID:              generateID("wlent"),
+			ListType:        change.ListType,
+			EntityID:        change.EntityID,
+			EntityType:      change.EntityType,
+			Reason:          change.Justification,
+			Status:          database.EntryStatusActive,
+			ExpiresAt:       change.EntryExpiresAt,
+			AddedBy:         change.RequestedBy,
+			ApprovedBy:      reviewerID,
+			ChangeRequestID: change.ID,
+		}
+	}
+
+	if err := s.repo.ApproveChangeRequest(ctx, change, reviewerID, comment, entry); err != nil {
+		return nil, err
+	}
+
+	if change.ListType == database.ListTypeExclusion {
+		s.refreshAfterChange(ctx)
+	}
+
+	return s.repo.GetChangeRequest(ctx, change.ID)
+}
+
+// Reject declines a pending change; the reviewer must say why
+func (s *Service) Reject(ctx context.Context, changeID, reviewerID, comment string) (*database.WatchlistChangeRequest, error) {
+	if strings.TrimSpace(comment) == "" {
+		return nil, fmt.Errorf("a comment is required when rejecting a change")
+	}
+
+	if _, err := s.pendingChange(ctx, changeID, reviewerID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RejectChangeRequest(ctx, changeID, reviewerID, comment); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Watchlist change rejected", "change_id", changeID, "reviewed_by", reviewerID)
+	return s.repo.GetChangeRequest(ctx, changeID)
+}
+
+// Cancel withdraws a pending change; only the original requester may cancel
+func (s *Service) Cancel(ctx context.Context, changeID, requesterID string) error {
+	if err := s.repo.CancelChangeRequest(ctx, changeID, requesterID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Watchlist change cancelled", "change_id", changeID, "requested_by", requesterID)
+	return nil
+}
+
+// ListChanges returns change requests, e.g. the pending-change queue
+func (s *Service) ListChanges(ctx context.Context, status, listType string, limit int) ([]*database.WatchlistChangeRequest, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListChangeRequests(ctx, status, listType, limit)
+}
+
+// ListEntries returns list entries filtered by list type and status
+func (s *Service) ListEntries(ctx context.Context, listType, status string) ([]*database.WatchlistEntry, error) {
+	return s.repo.ListEntries(ctx, listType, status)
+}
+
+// ExclusionReport builds the auditor report of all active exclusions
+func (s *Service) ExclusionReport(ctx context.Context) (*ExclusionReport, error) {
+	entries, err := s.repo.ListEntries(ctx, database.ListTypeExclusion, database.EntryStatusActive)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &ExclusionReport{
+		GeneratedAt: now,
+		TotalActive: len(entries),
+		Entries:     make([]*ExclusionReportItem, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		item := &ExclusionReportItem{WatchlistEntry: entry}
+		if entry.ExpiresAt == nil {
+			report.WithoutExpiry++
+		} else {
+			remaining := entry.ExpiresAt.Sub(now)
+			days := int(remaining.Hours() / 24)
+			item.DaysRemaining = &days
+			if remaining <= expiringSoonWindow {
+				item.ExpiringSoon = true
+				report.ExpiringSoon++
+			}
+		}
+		report.Entries = append(report.Entries, item)
+	}
+
+	// Soonest expiries first; open-ended entries last
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		a, b := report.Entries[i].ExpiresAt, report.Entries[j].ExpiresAt
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.Before(*b)
+	})
+
+	return report, nil
+}
+
+// RunExpiry closes expired entries and lapses stale pending changes
+func (s *Service) RunExpiry(ctx context.Context) (*ExpiryResult, error) {
+	expired, err := s.repo.ExpireEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lapsed, err := s.repo.LapseStaleChangeRequests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range expired {
+		s.logger.Info("Watchlist entry expired",
+			"entry_id", entry.ID,
+			"list_type", entry.ListType,
+			"entity_id", entry.EntityID)
+	}
+
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return &ExpiryResult{
+		ExpiredEntries: len(expired),
+		LapsedChanges:  lapsed,
+	}, nil
+}
+
+// Refresh reloads the in-memory exclusion index from the database
+func (s *Service) Refresh(ctx context.Context) error {
+	entries, err := s.repo.ListEntries(ctx, database.ListTypeExclusion, database.EntryStatusActive)
+	if err != nil {
+		return err
+	}
+
+	index := NewExclusionIndex(entries)
+
+	s.mu.Lock()
+	s.index = index
+	s.mu.Unlock()
+
+	s.logger.Debug("Exclusion index refreshed", "active_exclusions", index.Len())
+	return nil
+}
+
+// ExcludedEntity reports whether any of the entities is on the active
+// exclusion list and returns the matching entity ID
+func (s *Service) ExcludedEntity(entityIDs []string) (string, bool) {
+	s.mu.RLock()
+	index := s.index
+	s.mu.RUnlock()
+
+	entry, ok := index.Match(entityIDs, time.Now())
+	if !ok {
+		return "", false
+	}
+	return entry.EntityID, true
+}
+
+// pendingChange loads a change and checks it can be reviewed by reviewerID
+func (s *Service) pendingChange(ctx context.Context, changeID, reviewerID string) (*database.WatchlistChangeRequest, error) {
+	if reviewerID == "" {
+		return nil, fmt.Errorf("reviewer_id is required")
+	}
+
+	change, err := s.repo.GetChangeRequest(ctx, changeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := CheckReview(change, reviewerID, time.Now()); err != nil {
+		if errors.Is(err, ErrSelfApproval) {
+			s.logger.Warn("Rejected self-review of watchlist change",
+				"change_id", change.ID,
+				"user_id", reviewerID)
+		}
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// CheckReview enforces dual control: only a pending change that has not
+// lapsed can be reviewed, and never by the user who requested it
+func CheckReview(change *database.WatchlistChangeRequest, reviewerID string, now time.Time) error {
+	if change.Status != database.ChangeStatusPending || !now.Before(change.PendingUntil) {
+		return ErrNotPending
+	}
+
+	if change.RequestedBy == reviewerID {
+		return ErrSelfApproval
+	}
+
+	return nil
+}
+
+// resolveExpiry applies the exclusion expiry policy. Exclusions always expire;
+// watchlist entries only expire when the requester asks for it.
+func (s *Service) resolveExpiry(listType string, requested *time.Time, now time.Time) (*time.Time, error) {
+	if requested != nil && !requested.After(now) {
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+
+	if listType != database.ListTypeExclusion {
+		return requested, nil
+	}
+
+	if requested == nil {
+		expiresAt := now.Add(s.config.Watchlist.DefaultExclusionDuration)
+		return &expiresAt, nil
+	}
+
+	if requested.Sub(now) > s.config.Watchlist.MaxExclusionDuration {
+		return nil, fmt.Errorf("exclusions cannot last longer than %s", s.config.Watchlist.MaxExclusionDuration)
+	}
+
+	return requested, nil
+}
+
+// refreshAfterChange refreshes the exclusion index, logging rather than
+// failing the already-committed change
+func (s *Service) refreshAfterChange(ctx context.Context) {
+	if err := s.Refresh(ctx); err != nil {
+		s.logger.Error("Failed to refresh exclusion index", "error", err)
+	}
+}
+
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().Unix(), time.Now().Nanosecond())
+}
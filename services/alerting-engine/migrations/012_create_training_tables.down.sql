@@ -0,0 +1,11 @@
+-- Drop training tables
+DROP TRIGGER IF EXISTS update_training_sessions_updated_at ON training_sessions;
+
+DROP INDEX IF EXISTS idx_training_scenarios_source_alert_id;
+DROP INDEX IF EXISTS idx_training_scenarios_session_id;
+DROP INDEX IF EXISTS idx_training_sessions_created_at;
+DROP INDEX IF EXISTS idx_training_sessions_status;
+DROP INDEX IF EXISTS idx_training_sessions_trainee_id;
+
+DROP TABLE IF EXISTS training_scenarios;
+DROP TABLE IF EXISTS training_sessions;
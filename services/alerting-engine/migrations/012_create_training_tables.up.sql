@@ -0,0 +1,73 @@
+-- Create training_sessions table
+CREATE TABLE IF NOT EXISTS training_sessions (
+    id VARCHAR(255) PRIMARY KEY,
+    trainee_id VARCHAR(255) NOT NULL,
+    sandbox_tenant_id VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'active',
+
+    -- Replay window used to select historical alerts
+    replay_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    replay_to TIMESTAMP WITH TIME ZONE NOT NULL,
+    severity_filter TEXT[],
+
+    -- Scoring
+    scenario_count INTEGER NOT NULL DEFAULT 0,
+    answered_count INTEGER NOT NULL DEFAULT 0,
+    correct_count INTEGER NOT NULL DEFAULT 0,
+    score NUMERIC(6, 2) NOT NULL DEFAULT 0,
+
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT training_sessions_status_check CHECK (status IN ('active', 'completed', 'abandoned'))
+);
+
+-- Create training_scenarios table holding anonymized replayed alerts
+CREATE TABLE IF NOT EXISTS training_scenarios (
+    id VARCHAR(255) PRIMARY KEY,
+    session_id VARCHAR(255) NOT NULL,
+    sequence INTEGER NOT NULL,
+
+    -- Source alert is kept for scoring only and never returned to trainees
+    source_alert_id VARCHAR(255) NOT NULL,
+    anonymized_alert JSONB NOT NULL,
+    historical_disposition VARCHAR(50) NOT NULL,
+    historical_severity VARCHAR(50),
+
+    -- Trainee response
+    trainee_disposition VARCHAR(50),
+    trainee_severity VARCHAR(50),
+    trainee_notes TEXT,
+    points NUMERIC(4, 2),
+    presented_at TIMESTAMP WITH TIME ZONE,
+    answered_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT training_scenarios_session_sequence_unique UNIQUE (session_id, sequence),
+    FOREIGN KEY (session_id) REFERENCES training_sessions(id) ON DELETE CASCADE
+);
+
+-- Create indexes for training tables
+CREATE INDEX IF NOT EXISTS idx_training_sessions_trainee_id ON training_sessions(trainee_id);
+CREATE INDEX IF NOT EXISTS idx_training_sessions_status ON training_sessions(status);
+CREATE INDEX IF NOT EXISTS idx_training_sessions_created_at ON training_sessions(created_at);
+CREATE INDEX IF NOT EXISTS idx_training_scenarios_session_id ON training_scenarios(session_id);
+CREATE INDEX IF NOT EXISTS idx_training_scenarios_source_alert_id ON training_scenarios(source_alert_id);
+
+-- Create triggers
+CREATE TRIGGER update_training_sessions_updated_at
+    BEFORE UPDATE ON training_sessions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE training_sessions IS 'Analyst training sessions replaying historical alerts in a sandbox tenant';
+COMMENT ON COLUMN training_sessions.sandbox_tenant_id IS 'Isolated tenant the replayed data is scoped to';
+COMMENT ON COLUMN training_sessions.score IS 'Percentage score across answered scenarios';
+
+COMMENT ON TABLE training_scenarios IS 'Anonymized historical alerts presented to a trainee';
+COMMENT ON COLUMN training_scenarios.anonymized_alert IS 'Pseudonymized copy of the source alert shown to the trainee';
+COMMENT ON COLUMN training_scenarios.historical_disposition IS 'Disposition recorded when the source alert was originally resolved';
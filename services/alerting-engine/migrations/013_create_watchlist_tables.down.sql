@@ -0,0 +1,12 @@
+-- Drop watchlist tables
+DROP TRIGGER IF EXISTS update_watchlist_change_requests_updated_at ON watchlist_change_requests;
+DROP TRIGGER IF EXISTS update_watchlist_entries_updated_at ON watchlist_entries;
+
+DROP INDEX IF EXISTS idx_watchlist_change_requests_entity;
+DROP INDEX IF EXISTS idx_watchlist_change_requests_status;
+DROP INDEX IF EXISTS idx_watchlist_entries_expires_at;
+DROP INDEX IF EXISTS idx_watchlist_entries_status;
+DROP INDEX IF EXISTS idx_watchlist_entries_active_entity;
+
+DROP TABLE IF EXISTS watchlist_change_requests;
+DROP TABLE IF EXISTS watchlist_entries;
@@ -0,0 +1,83 @@
+-- Create watchlist_entries table holding active watchlist and exclusion list members
+CREATE TABLE IF NOT EXISTS watchlist_entries (
+    id VARCHAR(255) PRIMARY KEY,
+    list_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    entity_type VARCHAR(100),
+    reason TEXT NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMP WITH TIME ZONE,
+
+    -- Dual control: the entry exists only because two different people agreed
+    added_by VARCHAR(255) NOT NULL,
+    approved_by VARCHAR(255) NOT NULL,
+    change_request_id VARCHAR(255) NOT NULL,
+
+    removed_by VARCHAR(255),
+    removal_approved_by VARCHAR(255),
+    removed_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT watchlist_entries_list_type_check CHECK (list_type IN ('watchlist', 'exclusion')),
+    CONSTRAINT watchlist_entries_status_check CHECK (status IN ('active', 'expired', 'removed')),
+    CONSTRAINT watchlist_entries_dual_control_check CHECK (added_by <> approved_by)
+);
+
+-- Create watchlist_change_requests table for the pending-change queue
+CREATE TABLE IF NOT EXISTS watchlist_change_requests (
+    id VARCHAR(255) PRIMARY KEY,
+    list_type VARCHAR(50) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    entity_type VARCHAR(100),
+    entry_id VARCHAR(255),
+    justification TEXT NOT NULL,
+    entry_expires_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+
+    requested_by VARCHAR(255) NOT NULL,
+    requested_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    pending_until TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    reviewed_by VARCHAR(255),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    review_comment TEXT,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT watchlist_change_requests_list_type_check CHECK (list_type IN ('watchlist', 'exclusion')),
+    CONSTRAINT watchlist_change_requests_action_check CHECK (action IN ('add', 'remove')),
+    CONSTRAINT watchlist_change_requests_status_check CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled', 'lapsed')),
+    CONSTRAINT watchlist_change_requests_reviewer_check CHECK (reviewed_by IS NULL OR reviewed_by <> requested_by),
+    FOREIGN KEY (entry_id) REFERENCES watchlist_entries(id) ON DELETE SET NULL
+);
+
+-- Create indexes for watchlist tables
+CREATE UNIQUE INDEX IF NOT EXISTS idx_watchlist_entries_active_entity
+    ON watchlist_entries(list_type, entity_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_watchlist_entries_status ON watchlist_entries(status);
+CREATE INDEX IF NOT EXISTS idx_watchlist_entries_expires_at ON watchlist_entries(expires_at);
+CREATE INDEX IF NOT EXISTS idx_watchlist_change_requests_status ON watchlist_change_requests(status);
+CREATE INDEX IF NOT EXISTS idx_watchlist_change_requests_entity ON watchlist_change_requests(list_type, entity_id);
+
+-- Create triggers
+CREATE TRIGGER update_watchlist_entries_updated_at
+    BEFORE UPDATE ON watchlist_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_watchlist_change_requests_updated_at
+    BEFORE UPDATE ON watchlist_change_requests
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE watchlist_entries IS 'Watchlist and alert exclusion list members approved under dual control';
+COMMENT ON COLUMN watchlist_entries.expires_at IS 'Entries are expired automatically after this time';
+COMMENT ON COLUMN watchlist_entries.change_request_id IS 'Approved change request that created the entry';
+
+COMMENT ON TABLE watchlist_change_requests IS 'Pending and decided list additions and removals awaiting a second approver';
+COMMENT ON COLUMN watchlist_change_requests.pending_until IS 'Requests not reviewed by this time lapse and must be resubmitted';
@@ -0,0 +1,7 @@
+-- Drop alert evidence bundles table
+DROP TRIGGER IF EXISTS prevent_alert_evidence_bundles_update ON alert_evidence_bundles;
+DROP FUNCTION IF EXISTS prevent_evidence_bundle_update();
+
+DROP INDEX IF EXISTS idx_alert_evidence_bundles_rule_id;
+
+DROP TABLE IF EXISTS alert_evidence_bundles;
@@ -0,0 +1,39 @@
+-- Create alert_evidence_bundles table holding the immutable record of why an alert fired
+CREATE TABLE IF NOT EXISTS alert_evidence_bundles (
+    id VARCHAR(255) PRIMARY KEY,
+    alert_id VARCHAR(255) NOT NULL,
+
+    -- Rule as it was evaluated, so later edits do not rewrite history
+    rule_id VARCHAR(255) NOT NULL,
+    rule_name VARCHAR(255) NOT NULL,
+    rule_version INTEGER NOT NULL,
+    rule_snapshot JSONB NOT NULL,
+
+    -- Inputs to the evaluation
+    trigger_events JSONB NOT NULL,
+    window_values JSONB NOT NULL,
+    evaluated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    -- SHA-256 over the canonical bundle contents
+    content_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT alert_evidence_bundles_alert_unique UNIQUE (alert_id),
+    FOREIGN KEY (alert_id) REFERENCES alerts(id) ON DELETE CASCADE
+);
+
+-- Create indexes for alert_evidence_bundles table
+CREATE INDEX IF NOT EXISTS idx_alert_evidence_bundles_rule_id ON alert_evidence_bundles(rule_id, rule_version);
+
+-- Evidence bundles are write-once; only cascading alert deletion may remove them
+CREATE OR REPLACE FUNCTION prevent_evidence_bundle_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'alert evidence bundles are immutable';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER prevent_alert_evidence_bundles_update
+    BEFORE UPDATE ON alert_evidence_bundles
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_evidence_bundle_update();
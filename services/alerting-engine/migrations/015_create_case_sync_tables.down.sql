@@ -0,0 +1,18 @@
+-- Drop case sync tables
+DROP TRIGGER IF EXISTS enqueue_alert_case_sync_deliveries ON alerts;
+DROP FUNCTION IF EXISTS enqueue_case_sync_deliveries();
+
+DROP TRIGGER IF EXISTS update_case_sync_links_updated_at ON case_sync_links;
+DROP TRIGGER IF EXISTS update_case_sync_deliveries_updated_at ON case_sync_deliveries;
+DROP TRIGGER IF EXISTS update_case_sync_connectors_updated_at ON case_sync_connectors;
+
+DROP INDEX IF EXISTS idx_case_sync_reconciliations_connector;
+DROP INDEX IF EXISTS idx_case_sync_links_external_id;
+DROP INDEX IF EXISTS idx_case_sync_deliveries_status;
+DROP INDEX IF EXISTS idx_case_sync_deliveries_alert;
+DROP INDEX IF EXISTS idx_case_sync_deliveries_due;
+
+DROP TABLE IF EXISTS case_sync_reconciliations;
+DROP TABLE IF EXISTS case_sync_links;
+DROP TABLE IF EXISTS case_sync_deliveries;
+DROP TABLE IF EXISTS case_sync_connectors;
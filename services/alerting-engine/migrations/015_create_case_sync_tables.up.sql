@@ -0,0 +1,171 @@
+-- Create case_sync_connectors table describing external case management systems
+CREATE TABLE IF NOT EXISTS case_sync_connectors (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    system_type VARCHAR(50) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    -- Outbound requests; updates go to the update URL once the external case id is known
+    endpoint_url TEXT NOT NULL,
+    http_method VARCHAR(10) NOT NULL DEFAULT 'POST',
+    update_url_template TEXT,
+    update_method VARCHAR(10) NOT NULL DEFAULT 'PATCH',
+    status_url_template TEXT,
+
+    -- Credentials are never stored; credential_env names the variable holding them
+    auth_type VARCHAR(20) NOT NULL DEFAULT 'none',
+    auth_header VARCHAR(255),
+    credential_env VARCHAR(255),
+    headers JSONB NOT NULL DEFAULT '{}',
+
+    event_types TEXT[] NOT NULL,
+    field_mappings JSONB NOT NULL DEFAULT '{}',
+    status_mappings JSONB NOT NULL DEFAULT '{}',
+    external_id_field VARCHAR(255),
+    callback_mapping JSONB NOT NULL DEFAULT '{}',
+    callback_secret VARCHAR(255) NOT NULL,
+
+    drift_policy VARCHAR(20) NOT NULL DEFAULT 'report',
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT case_sync_connectors_system_type_check CHECK (system_type IN ('actimize', 'servicenow', 'generic')),
+    CONSTRAINT case_sync_connectors_auth_type_check CHECK (auth_type IN ('none', 'bearer', 'basic', 'api_key')),
+    CONSTRAINT case_sync_connectors_drift_policy_check CHECK (drift_policy IN ('report', 'push', 'pull')),
+    CONSTRAINT case_sync_connectors_max_attempts_check CHECK (max_attempts > 0)
+);
+
+-- Create case_sync_deliveries table, the outbox of alert lifecycle changes per connector
+CREATE TABLE IF NOT EXISTS case_sync_deliveries (
+    id VARCHAR(255) PRIMARY KEY,
+    connector_id VARCHAR(255) NOT NULL,
+    alert_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    alert_status VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    response_code INTEGER,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT case_sync_deliveries_status_check CHECK (status IN ('pending', 'failed', 'delivered', 'dead')),
+    FOREIGN KEY (connector_id) REFERENCES case_sync_connectors(id) ON DELETE CASCADE,
+    FOREIGN KEY (alert_id) REFERENCES alerts(id) ON DELETE CASCADE
+);
+
+-- Create case_sync_links table mapping alerts to external case records
+CREATE TABLE IF NOT EXISTS case_sync_links (
+    connector_id VARCHAR(255) NOT NULL,
+    alert_id VARCHAR(255) NOT NULL,
+    external_id VARCHAR(255),
+    external_status VARCHAR(100),
+    last_pushed_status VARCHAR(50),
+    last_pushed_at TIMESTAMP WITH TIME ZONE,
+    acknowledged_at TIMESTAMP WITH TIME ZONE,
+    acknowledged_by VARCHAR(255),
+    last_reconciled_at TIMESTAMP WITH TIME ZONE,
+    drift_detected_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (connector_id, alert_id),
+    FOREIGN KEY (connector_id) REFERENCES case_sync_connectors(id) ON DELETE CASCADE,
+    FOREIGN KEY (alert_id) REFERENCES alerts(id) ON DELETE CASCADE
+);
+
+-- Create case_sync_reconciliations table recording nightly drift checks
+CREATE TABLE IF NOT EXISTS case_sync_reconciliations (
+    id VARCHAR(255) PRIMARY KEY,
+    connector_id VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    checked INTEGER NOT NULL DEFAULT 0,
+    in_sync INTEGER NOT NULL DEFAULT 0,
+    drifted INTEGER NOT NULL DEFAULT 0,
+    repaired INTEGER NOT NULL DEFAULT 0,
+    errors INTEGER NOT NULL DEFAULT 0,
+    drift JSONB NOT NULL DEFAULT '[]',
+    error_message TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT case_sync_reconciliations_status_check CHECK (status IN ('running', 'completed', 'failed')),
+    FOREIGN KEY (connector_id) REFERENCES case_sync_connectors(id) ON DELETE CASCADE
+);
+
+-- Create indexes for case sync tables
+CREATE INDEX IF NOT EXISTS idx_case_sync_deliveries_due
+    ON case_sync_deliveries(next_attempt_at) WHERE status IN ('pending', 'failed');
+CREATE INDEX IF NOT EXISTS idx_case_sync_deliveries_alert ON case_sync_deliveries(connector_id, alert_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_case_sync_deliveries_status ON case_sync_deliveries(status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_case_sync_links_external_id
+    ON case_sync_links(connector_id, external_id) WHERE external_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_case_sync_reconciliations_connector
+    ON case_sync_reconciliations(connector_id, started_at DESC);
+
+-- Enqueue a delivery for every enabled connector subscribed to the lifecycle change.
+-- Changes applied from a connector's own callback set aegis.case_sync_origin so
+-- they are not echoed back to that connector.
+CREATE OR REPLACE FUNCTION enqueue_case_sync_deliveries()
+RETURNS TRIGGER AS $$
+DECLARE
+    lifecycle_event VARCHAR(50);
+    origin TEXT := COALESCE(current_setting('aegis.case_sync_origin', true), '');
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        lifecycle_event := 'alert.created';
+    ELSIF NEW.status IS DISTINCT FROM OLD.status THEN
+        lifecycle_event := 'alert.' || NEW.status;
+    ELSIF NEW.escalation_level IS DISTINCT FROM OLD.escalation_level THEN
+        lifecycle_event := 'alert.escalated';
+    ELSE
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO case_sync_deliveries (id, connector_id, alert_id, event_type, alert_status)
+    SELECT 'csd_' || md5(c.id || NEW.id || clock_timestamp()::text || random()::text),
+           c.id, NEW.id, lifecycle_event, NEW.status
+    FROM case_sync_connectors c
+    WHERE c.enabled
+      AND lifecycle_event = ANY(c.event_types)
+      AND c.id <> origin;
+
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER enqueue_alert_case_sync_deliveries
+    AFTER INSERT OR UPDATE ON alerts
+    FOR EACH ROW
+    EXECUTE FUNCTION enqueue_case_sync_deliveries();
+
+-- Create triggers
+CREATE TRIGGER update_case_sync_connectors_updated_at
+    BEFORE UPDATE ON case_sync_connectors
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_case_sync_deliveries_updated_at
+    BEFORE UPDATE ON case_sync_deliveries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_case_sync_links_updated_at
+    BEFORE UPDATE ON case_sync_links
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE case_sync_connectors IS 'External case management systems that receive alert lifecycle changes';
+COMMENT ON COLUMN case_sync_connectors.field_mappings IS 'External field path to alert field expression used to build outbound payloads';
+COMMENT ON COLUMN case_sync_connectors.status_mappings IS 'Alert status to external case status';
+COMMENT ON COLUMN case_sync_connectors.credential_env IS 'Environment variable holding the connector credential';
+COMMENT ON TABLE case_sync_deliveries IS 'Outbox of alert lifecycle changes awaiting delivery to a connector';
+COMMENT ON TABLE case_sync_links IS 'External case record created for an alert by a connector';
+COMMENT ON TABLE case_sync_reconciliations IS 'Nightly comparison of alert status with external case status';
@@ -0,0 +1,14 @@
+-- Drop batch digest tables
+DROP TRIGGER IF EXISTS update_batch_digests_updated_at ON batch_digests;
+DROP TRIGGER IF EXISTS update_batch_rules_updated_at ON batch_rules;
+DROP TRIGGER IF EXISTS update_portfolios_updated_at ON portfolios;
+
+DROP INDEX IF EXISTS idx_batch_digests_manager;
+DROP INDEX IF EXISTS idx_batch_digest_runs_date;
+DROP INDEX IF EXISTS idx_batch_daily_aggregates_entity;
+
+DROP TABLE IF EXISTS batch_digests;
+DROP TABLE IF EXISTS batch_digest_runs;
+DROP TABLE IF EXISTS batch_daily_aggregates;
+DROP TABLE IF EXISTS batch_rules;
+DROP TABLE IF EXISTS portfolios;
@@ -0,0 +1,123 @@
+-- Create portfolios table grouping monitored entities under a portfolio manager
+CREATE TABLE IF NOT EXISTS portfolios (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    manager_id VARCHAR(255) NOT NULL,
+    manager_contact VARCHAR(255) NOT NULL,
+    notification_channel VARCHAR(50) NOT NULL DEFAULT 'email',
+    entity_ids TEXT[] NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT portfolios_notification_channel_check CHECK (notification_channel IN ('email', 'slack', 'teams', 'webhook'))
+);
+
+-- Create batch_rules table holding thresholds evaluated over a day's aggregates
+CREATE TABLE IF NOT EXISTS batch_rules (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    metric VARCHAR(100) NOT NULL,
+    aggregation VARCHAR(20) NOT NULL DEFAULT 'sum',
+    operator VARCHAR(10) NOT NULL DEFAULT 'gt',
+    threshold DOUBLE PRECISION NOT NULL,
+    severity VARCHAR(50) NOT NULL DEFAULT 'medium',
+    alert_type VARCHAR(100) NOT NULL,
+
+    -- Real-time rules covering the same behaviour; their alerts suppress this rule
+    intraday_rule_ids TEXT[] NOT NULL DEFAULT '{}',
+    -- Empty applies the rule to every portfolio
+    portfolio_ids TEXT[] NOT NULL DEFAULT '{}',
+
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT batch_rules_aggregation_check CHECK (aggregation IN ('sum', 'count', 'max')),
+    CONSTRAINT batch_rules_operator_check CHECK (operator IN ('gt', 'gte', 'lt', 'lte')),
+    CONSTRAINT batch_rules_severity_check CHECK (severity IN ('low', 'medium', 'high', 'critical'))
+);
+
+-- Create batch_daily_aggregates table holding each entity's aggregated activity per business day
+CREATE TABLE IF NOT EXISTS batch_daily_aggregates (
+    business_date DATE NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    metric VARCHAR(100) NOT NULL,
+    total DOUBLE PRECISION NOT NULL DEFAULT 0,
+    count BIGINT NOT NULL DEFAULT 0,
+    max_value DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (business_date, entity_id, metric)
+);
+
+-- Create batch_digest_runs table recording each end-of-day evaluation
+CREATE TABLE IF NOT EXISTS batch_digest_runs (
+    id VARCHAR(255) PRIMARY KEY,
+    business_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    triggered_by VARCHAR(255) NOT NULL,
+    portfolios INTEGER NOT NULL DEFAULT 0,
+    alerts_generated INTEGER NOT NULL DEFAULT 0,
+    duplicates_suppressed INTEGER NOT NULL DEFAULT 0,
+    already_generated INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT batch_digest_runs_status_check CHECK (status IN ('running', 'completed', 'failed'))
+);
+
+-- Create batch_digests table, one digest per portfolio and business day
+CREATE TABLE IF NOT EXISTS batch_digests (
+    id VARCHAR(255) PRIMARY KEY,
+    run_id VARCHAR(255) NOT NULL,
+    business_date DATE NOT NULL,
+    portfolio_id VARCHAR(255) NOT NULL,
+    manager_id VARCHAR(255) NOT NULL,
+    alert_ids TEXT[] NOT NULL DEFAULT '{}',
+    suppressed JSONB NOT NULL DEFAULT '[]',
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    notification_id VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (business_date, portfolio_id),
+    FOREIGN KEY (run_id) REFERENCES batch_digest_runs(id) ON DELETE CASCADE,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+);
+
+-- Create indexes for batch digest tables
+CREATE INDEX IF NOT EXISTS idx_batch_daily_aggregates_entity ON batch_daily_aggregates(entity_id, business_date);
+CREATE INDEX IF NOT EXISTS idx_batch_digest_runs_date ON batch_digest_runs(business_date, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_batch_digests_manager ON batch_digests(manager_id, business_date DESC);
+
+-- Create triggers for updated_at
+CREATE TRIGGER update_portfolios_updated_at
+    BEFORE UPDATE ON portfolios
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_batch_rules_updated_at
+    BEFORE UPDATE ON batch_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_batch_digests_updated_at
+    BEFORE UPDATE ON batch_digests
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE portfolios IS 'Monitored entities grouped under the portfolio manager who receives their digest';
+COMMENT ON TABLE batch_rules IS 'Rules evaluated once a day over aggregated activity rather than per event';
+COMMENT ON COLUMN batch_rules.intraday_rule_ids IS 'Real-time rules whose alerts on the same entity and day make this rule a duplicate';
+COMMENT ON TABLE batch_daily_aggregates IS 'Per entity and metric totals for a business day, loaded by upstream batch jobs';
+COMMENT ON TABLE batch_digest_runs IS 'End-of-day batch evaluation runs';
+COMMENT ON TABLE batch_digests IS 'End-of-day alert digest sent to a portfolio manager';
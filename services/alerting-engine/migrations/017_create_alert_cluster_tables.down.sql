@@ -0,0 +1,16 @@
+-- Drop alert clustering tables
+DROP TRIGGER IF EXISTS update_investigation_bundles_updated_at ON investigation_bundles;
+
+DROP INDEX IF EXISTS idx_investigation_bundles_proposed_key;
+DROP INDEX IF EXISTS idx_alert_case_links_case;
+DROP INDEX IF EXISTS idx_investigation_bundles_alert_ids;
+DROP INDEX IF EXISTS idx_investigation_bundles_cluster_key;
+DROP INDEX IF EXISTS idx_investigation_bundles_status;
+DROP INDEX IF EXISTS idx_alert_cluster_runs_started;
+DROP INDEX IF EXISTS idx_alert_entity_contexts_community;
+DROP INDEX IF EXISTS idx_alert_entity_contexts_resolved;
+
+DROP TABLE IF EXISTS alert_case_links;
+DROP TABLE IF EXISTS investigation_bundles;
+DROP TABLE IF EXISTS alert_cluster_runs;
+DROP TABLE IF EXISTS alert_entity_contexts;
@@ -0,0 +1,85 @@
+-- Create alert_entity_contexts table holding each entity's resolved identity and graph community
+CREATE TABLE IF NOT EXISTS alert_entity_contexts (
+    entity_id VARCHAR(255) PRIMARY KEY,
+    resolved_entity_id VARCHAR(255),
+    community_id VARCHAR(255),
+    source VARCHAR(100),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create alert_cluster_runs table recording each clustering pass
+CREATE TABLE IF NOT EXISTS alert_cluster_runs (
+    id VARCHAR(255) PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    triggered_by VARCHAR(255) NOT NULL,
+    alerts_scanned INTEGER NOT NULL DEFAULT 0,
+    clusters_found INTEGER NOT NULL DEFAULT 0,
+    bundles_proposed INTEGER NOT NULL DEFAULT 0,
+    bundles_superseded INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT alert_cluster_runs_status_check CHECK (status IN ('running', 'completed', 'failed'))
+);
+
+-- Create investigation_bundles table holding proposed groups of related open alerts
+CREATE TABLE IF NOT EXISTS investigation_bundles (
+    id VARCHAR(255) PRIMARY KEY,
+    run_id VARCHAR(255),
+    cluster_key VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'proposed',
+    title TEXT NOT NULL,
+    alert_ids TEXT[] NOT NULL DEFAULT '{}',
+    shared_entity_ids TEXT[] NOT NULL DEFAULT '{}',
+    community_ids TEXT[] NOT NULL DEFAULT '{}',
+    max_severity VARCHAR(50) NOT NULL,
+    case_id VARCHAR(255),
+    decided_by VARCHAR(255),
+    decided_at TIMESTAMP WITH TIME ZONE,
+    decision_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (run_id) REFERENCES alert_cluster_runs(id) ON DELETE SET NULL,
+    CONSTRAINT investigation_bundles_status_check CHECK (status IN ('proposed', 'accepted', 'dismissed', 'superseded'))
+);
+
+-- Create alert_case_links table linking alerts to the investigation case covering them
+CREATE TABLE IF NOT EXISTS alert_case_links (
+    alert_id VARCHAR(255) NOT NULL,
+    case_id VARCHAR(255) NOT NULL,
+    bundle_id VARCHAR(255),
+    linked_by VARCHAR(255) NOT NULL,
+    linked_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (alert_id, case_id),
+    FOREIGN KEY (alert_id) REFERENCES alerts(id) ON DELETE CASCADE,
+    FOREIGN KEY (bundle_id) REFERENCES investigation_bundles(id) ON DELETE SET NULL
+);
+
+-- Create indexes for alert clustering tables
+CREATE INDEX IF NOT EXISTS idx_alert_entity_contexts_resolved ON alert_entity_contexts(resolved_entity_id);
+CREATE INDEX IF NOT EXISTS idx_alert_entity_contexts_community ON alert_entity_contexts(community_id);
+CREATE INDEX IF NOT EXISTS idx_alert_cluster_runs_started ON alert_cluster_runs(started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_investigation_bundles_status ON investigation_bundles(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_investigation_bundles_cluster_key ON investigation_bundles(cluster_key, status);
+CREATE INDEX IF NOT EXISTS idx_investigation_bundles_alert_ids ON investigation_bundles USING GIN (alert_ids);
+CREATE INDEX IF NOT EXISTS idx_alert_case_links_case ON alert_case_links(case_id);
+
+-- Only one open proposal may exist for the same set of alerts
+CREATE UNIQUE INDEX IF NOT EXISTS idx_investigation_bundles_proposed_key
+    ON investigation_bundles(cluster_key) WHERE status = 'proposed';
+
+-- Create triggers for updated_at
+CREATE TRIGGER update_investigation_bundles_updated_at
+    BEFORE UPDATE ON investigation_bundles
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE alert_entity_contexts IS 'Resolved entity and graph community per raw entity id, loaded by entity resolution and graph analytics';
+COMMENT ON TABLE alert_cluster_runs IS 'Alert clustering passes over open alerts';
+COMMENT ON TABLE investigation_bundles IS 'Groups of open alerts sharing entities or communities, proposed for a single investigation case';
+COMMENT ON COLUMN investigation_bundles.cluster_key IS 'Hash of the sorted alert ids, used to avoid re-proposing unchanged or dismissed clusters';
+COMMENT ON TABLE alert_case_links IS 'Investigation case covering an alert, created when a bundle is accepted';
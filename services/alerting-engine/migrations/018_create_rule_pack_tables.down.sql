@@ -0,0 +1,10 @@
+-- Drop rule pack tables
+DROP TRIGGER IF EXISTS update_rule_thresholds_updated_at ON rule_thresholds;
+DROP TRIGGER IF EXISTS update_rule_lookup_tables_updated_at ON rule_lookup_tables;
+
+DROP INDEX IF EXISTS idx_rule_pack_imports_imported_at;
+DROP INDEX IF EXISTS idx_rule_pack_imports_pack;
+
+DROP TABLE IF EXISTS rule_pack_imports;
+DROP TABLE IF EXISTS rule_thresholds;
+DROP TABLE IF EXISTS rule_lookup_tables;
@@ -0,0 +1,67 @@
+-- Create rule_lookup_tables table holding named value sets referenced by rule conditions
+CREATE TABLE IF NOT EXISTS rule_lookup_tables (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    entries TEXT[] NOT NULL DEFAULT '{}',
+    source_pack VARCHAR(255),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create rule_thresholds table holding named numeric thresholds referenced by rule conditions
+CREATE TABLE IF NOT EXISTS rule_thresholds (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    value DOUBLE PRECISION NOT NULL,
+    description TEXT,
+    source_pack VARCHAR(255),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create rule_pack_imports table recording every imported pack and its outcome
+CREATE TABLE IF NOT EXISTS rule_pack_imports (
+    id VARCHAR(255) PRIMARY KEY,
+    pack_name VARCHAR(255) NOT NULL,
+    pack_version VARCHAR(100) NOT NULL,
+    dsl_version VARCHAR(20) NOT NULL,
+    source_deployment VARCHAR(255),
+    signature_key_id VARCHAR(255),
+    signed BOOLEAN NOT NULL DEFAULT false,
+    conflict_mode VARCHAR(20) NOT NULL,
+    rules_created INTEGER NOT NULL DEFAULT 0,
+    rules_updated INTEGER NOT NULL DEFAULT 0,
+    rules_skipped INTEGER NOT NULL DEFAULT 0,
+    rules_renamed INTEGER NOT NULL DEFAULT 0,
+    result JSONB,
+    imported_by VARCHAR(255) NOT NULL,
+    imported_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT rule_pack_imports_conflict_mode_check CHECK (conflict_mode IN ('skip', 'overwrite', 'rename'))
+);
+
+-- Create indexes for rule pack tables
+CREATE INDEX IF NOT EXISTS idx_rule_pack_imports_pack ON rule_pack_imports(pack_name, pack_version);
+CREATE INDEX IF NOT EXISTS idx_rule_pack_imports_imported_at ON rule_pack_imports(imported_at DESC);
+
+-- Create triggers
+CREATE TRIGGER update_rule_lookup_tables_updated_at
+    BEFORE UPDATE ON rule_lookup_tables
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_rule_thresholds_updated_at
+    BEFORE UPDATE ON rule_thresholds
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE rule_lookup_tables IS 'Named value sets rule conditions test membership against with in_lookup()';
+COMMENT ON TABLE rule_thresholds IS 'Named numeric thresholds rule conditions read with threshold()';
+COMMENT ON COLUMN rule_lookup_tables.source_pack IS 'Rule pack name and version the table was imported from, if any';
+COMMENT ON TABLE rule_pack_imports IS 'History of rule pack imports with provenance and per-item outcomes';
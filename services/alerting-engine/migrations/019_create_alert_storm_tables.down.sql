@@ -0,0 +1,10 @@
+-- Drop alert storm tables
+DROP TRIGGER IF EXISTS update_alert_storms_updated_at ON alert_storms;
+
+DROP INDEX IF EXISTS idx_alert_storms_active_rule;
+DROP INDEX IF EXISTS idx_storm_held_alerts_storm;
+DROP INDEX IF EXISTS idx_alert_storms_ended;
+DROP INDEX IF EXISTS idx_alert_storms_status;
+
+DROP TABLE IF EXISTS storm_held_alerts;
+DROP TABLE IF EXISTS alert_storms;
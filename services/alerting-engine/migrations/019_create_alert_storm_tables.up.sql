@@ -0,0 +1,67 @@
+-- Create alert_storms table recording bursts of alerts from one rule far above its normal rate
+CREATE TABLE IF NOT EXISTS alert_storms (
+    id VARCHAR(255) PRIMARY KEY,
+    rule_id VARCHAR(255) NOT NULL,
+    rule_name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    storm_alert_id VARCHAR(255) NOT NULL,
+    baseline_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    threshold DOUBLE PRECISION NOT NULL DEFAULT 0,
+    peak_rate INTEGER NOT NULL DEFAULT 0,
+    alert_count INTEGER NOT NULL DEFAULT 0,
+    admitted_count INTEGER NOT NULL DEFAULT 0,
+    held_count INTEGER NOT NULL DEFAULT 0,
+    expanded_count INTEGER NOT NULL DEFAULT 0,
+    dismissed_count INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_alert_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    reconcile_action VARCHAR(20),
+    reconciled_by VARCHAR(255),
+    reconciled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (storm_alert_id) REFERENCES alerts(id) ON DELETE CASCADE,
+    CONSTRAINT alert_storms_status_check CHECK (status IN ('active', 'ended', 'reconciling', 'reconciled')),
+    CONSTRAINT alert_storms_reconcile_action_check CHECK (reconcile_action IS NULL OR reconcile_action IN ('expand', 'dismiss', 'triage'))
+);
+
+-- Create storm_held_alerts table keeping the individual alerts held back during a storm
+CREATE TABLE IF NOT EXISTS storm_held_alerts (
+    id VARCHAR(255) PRIMARY KEY,
+    storm_id VARCHAR(255) NOT NULL,
+    rule_id VARCHAR(255) NOT NULL,
+    severity VARCHAR(50) NOT NULL,
+    title TEXT NOT NULL,
+    triage_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    alert_data JSONB NOT NULL,
+    evidence_data JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'held',
+    held_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (storm_id) REFERENCES alert_storms(id) ON DELETE CASCADE,
+    CONSTRAINT storm_held_alerts_status_check CHECK (status IN ('held', 'expanded', 'dismissed'))
+);
+
+-- Create indexes for alert storm tables
+CREATE INDEX IF NOT EXISTS idx_alert_storms_status ON alert_storms(status, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_alert_storms_ended ON alert_storms(ended_at) WHERE status = 'ended';
+CREATE INDEX IF NOT EXISTS idx_storm_held_alerts_storm ON storm_held_alerts(storm_id, status, triage_score DESC);
+
+-- Only one storm per rule may be active at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alert_storms_active_rule
+    ON alert_storms(rule_id) WHERE status = 'active';
+
+-- Create triggers for updated_at
+CREATE TRIGGER update_alert_storms_updated_at
+    BEFORE UPDATE ON alert_storms
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE alert_storms IS 'Bursts of alerts from one rule far above its learned rate, summarized by one storm alert';
+COMMENT ON COLUMN alert_storms.peak_rate IS 'Most alerts the rule raised in a single detection window during the storm';
+COMMENT ON TABLE storm_held_alerts IS 'Individual alerts held back during a storm, expanded or dismissed by post-storm reconciliation';
+COMMENT ON COLUMN storm_held_alerts.triage_score IS 'Probability the alert had of being let through during the storm; higher scores are expanded first';
@@ -0,0 +1,10 @@
+-- Drop alert comments table
+DROP TRIGGER IF EXISTS update_alert_comments_updated_at ON alert_comments;
+
+DROP INDEX IF EXISTS idx_alert_comments_mentions;
+DROP INDEX IF EXISTS idx_alert_comments_parent;
+DROP INDEX IF EXISTS idx_alert_comments_alert;
+
+ALTER TABLE case_sync_links DROP COLUMN IF EXISTS thread_migrated_at;
+
+DROP TABLE IF EXISTS alert_comments;
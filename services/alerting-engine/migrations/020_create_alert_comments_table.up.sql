@@ -0,0 +1,38 @@
+-- Create alert_comments table holding the discussion thread of each alert.
+-- Columns follow the investigation collaboration comments so a thread can be
+-- carried into the case when the alert is escalated.
+CREATE TABLE IF NOT EXISTS alert_comments (
+    id VARCHAR(255) PRIMARY KEY,
+    alert_id VARCHAR(255) NOT NULL,
+    parent_comment_id VARCHAR(255),
+    user_id VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    comment_type VARCHAR(50) NOT NULL DEFAULT 'general',
+    mentioned_users TEXT[] NOT NULL DEFAULT '{}',
+    is_internal BOOLEAN NOT NULL DEFAULT false,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    edited_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (alert_id) REFERENCES alerts(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_comment_id) REFERENCES alert_comments(id) ON DELETE CASCADE,
+    CONSTRAINT alert_comments_type_check CHECK (comment_type IN (
+        'general', 'question', 'finding', 'recommendation', 'status_update', 'evidence_comment', 'resolution'
+    ))
+);
+
+-- Record, per linked case, when the thread was last carried into it
+ALTER TABLE case_sync_links ADD COLUMN IF NOT EXISTS thread_migrated_at TIMESTAMP WITH TIME ZONE;
+
+-- Create indexes for alert_comments
+CREATE INDEX IF NOT EXISTS idx_alert_comments_alert ON alert_comments(alert_id, created_at) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_alert_comments_parent ON alert_comments(parent_comment_id);
+CREATE INDEX IF NOT EXISTS idx_alert_comments_mentions ON alert_comments USING GIN(mentioned_users);
+
+-- Create triggers
+CREATE TRIGGER update_alert_comments_updated_at
+    BEFORE UPDATE ON alert_comments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
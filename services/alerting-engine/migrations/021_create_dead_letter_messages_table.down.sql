@@ -0,0 +1,8 @@
+-- Drop dead_letter_messages table
+DROP TRIGGER IF EXISTS update_dead_letter_messages_updated_at ON dead_letter_messages;
+
+DROP INDEX IF EXISTS idx_dead_letter_messages_source;
+DROP INDEX IF EXISTS idx_dead_letter_messages_topic;
+DROP INDEX IF EXISTS idx_dead_letter_messages_status;
+
+DROP TABLE IF EXISTS dead_letter_messages;
@@ -0,0 +1,44 @@
+-- Create dead_letter_messages table recording consumed events that could not be processed
+CREATE TABLE IF NOT EXISTS dead_letter_messages (
+    id VARCHAR(255) PRIMARY KEY,
+    source_topic VARCHAR(255) NOT NULL,
+    source_partition INTEGER NOT NULL,
+    source_offset BIGINT NOT NULL,
+    message_key TEXT,
+    payload BYTEA NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    replay_of VARCHAR(255),
+    replay_count INTEGER NOT NULL DEFAULT 0,
+    message_time TIMESTAMP WITH TIME ZONE,
+    failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    replayed_by VARCHAR(255),
+    replayed_at TIMESTAMP WITH TIME ZONE,
+    discarded_by VARCHAR(255),
+    discarded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT dead_letter_messages_status_check CHECK (status IN ('pending', 'replayed', 'discarded'))
+);
+
+-- Create indexes for dead_letter_messages table
+CREATE INDEX IF NOT EXISTS idx_dead_letter_messages_status ON dead_letter_messages(status, failed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_dead_letter_messages_topic ON dead_letter_messages(source_topic, failed_at DESC);
+
+-- A message is recorded once however often dead-lettering it is retried
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dead_letter_messages_source
+    ON dead_letter_messages(source_topic, source_partition, source_offset);
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_dead_letter_messages_updated_at
+    BEFORE UPDATE ON dead_letter_messages
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE dead_letter_messages IS 'Consumed events that failed every processing attempt and were parked on the dead-letter topic';
+COMMENT ON COLUMN dead_letter_messages.replay_of IS 'Dead letter whose replay failed again and produced this one';
+COMMENT ON COLUMN dead_letter_messages.replay_count IS 'Times the message has been republished to its source topic';
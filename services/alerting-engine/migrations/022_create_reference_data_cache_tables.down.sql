@@ -0,0 +1,13 @@
+-- Drop reference data cache tables
+DROP TRIGGER IF EXISTS update_entity_risk_tiers_updated_at ON entity_risk_tiers;
+DROP TRIGGER IF EXISTS record_entity_risk_tiers_change ON entity_risk_tiers;
+DROP TRIGGER IF EXISTS record_rule_thresholds_change ON rule_thresholds;
+DROP TRIGGER IF EXISTS record_rule_lookup_tables_change ON rule_lookup_tables;
+
+DROP FUNCTION IF EXISTS record_reference_data_change();
+
+DROP INDEX IF EXISTS idx_reference_data_changes_changed_at;
+DROP INDEX IF EXISTS idx_entity_risk_tiers_tier;
+
+DROP TABLE IF EXISTS reference_data_changes;
+DROP TABLE IF EXISTS entity_risk_tiers;
@@ -0,0 +1,69 @@
+-- Create entity_risk_tiers table holding the risk tier rule conditions read with risk_tier()
+CREATE TABLE IF NOT EXISTS entity_risk_tiers (
+    entity_id VARCHAR(255) PRIMARY KEY,
+    tier VARCHAR(20) NOT NULL,
+    score DOUBLE PRECISION,
+    reason TEXT,
+    updated_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT entity_risk_tiers_tier_check CHECK (tier IN ('low', 'medium', 'high', 'critical'))
+);
+
+-- Create reference_data_changes table logging every change to data rule conditions read
+CREATE TABLE IF NOT EXISTS reference_data_changes (
+    version BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT reference_data_changes_kind_check CHECK (kind IN ('lookup', 'threshold', 'risk_tier'))
+);
+
+-- Create indexes for reference data tables
+CREATE INDEX IF NOT EXISTS idx_entity_risk_tiers_tier ON entity_risk_tiers(tier);
+CREATE INDEX IF NOT EXISTS idx_reference_data_changes_changed_at ON reference_data_changes(changed_at);
+
+-- Log a change to a reference data row. TG_ARGV[0] is the kind and
+-- TG_ARGV[1] the column naming the row; a rename logs both names.
+CREATE OR REPLACE FUNCTION record_reference_data_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        INSERT INTO reference_data_changes (kind, key) VALUES (TG_ARGV[0], to_jsonb(OLD) ->> TG_ARGV[1]);
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND (to_jsonb(OLD) ->> TG_ARGV[1]) IS DISTINCT FROM (to_jsonb(NEW) ->> TG_ARGV[1])) THEN
+        INSERT INTO reference_data_changes (kind, key) VALUES (TG_ARGV[0], to_jsonb(NEW) ->> TG_ARGV[1]);
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+-- Create triggers logging reference data changes, so every replica's rule
+-- engine cache sees updates however they were made
+CREATE TRIGGER record_rule_lookup_tables_change
+    AFTER INSERT OR UPDATE OR DELETE ON rule_lookup_tables
+    FOR EACH ROW
+    EXECUTE FUNCTION record_reference_data_change('lookup', 'name');
+
+CREATE TRIGGER record_rule_thresholds_change
+    AFTER INSERT OR UPDATE OR DELETE ON rule_thresholds
+    FOR EACH ROW
+    EXECUTE FUNCTION record_reference_data_change('threshold', 'name');
+
+CREATE TRIGGER record_entity_risk_tiers_change
+    AFTER INSERT OR UPDATE OR DELETE ON entity_risk_tiers
+    FOR EACH ROW
+    EXECUTE FUNCTION record_reference_data_change('risk_tier', 'entity_id');
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_entity_risk_tiers_updated_at
+    BEFORE UPDATE ON entity_risk_tiers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE entity_risk_tiers IS 'Risk tier of each entity, read by rule conditions through the rule engine cache';
+COMMENT ON TABLE reference_data_changes IS 'Change log of lookup tables, thresholds and risk tiers that rule engine caches invalidate from';
+COMMENT ON COLUMN reference_data_changes.version IS 'Stamp of the change; commits can land out of order, so readers also re-read recent changes';
@@ -0,0 +1,11 @@
+-- Drop rule version tables
+DROP TRIGGER IF EXISTS reject_rule_versions_change ON rule_versions;
+
+DROP FUNCTION IF EXISTS reject_rule_version_change();
+
+DROP INDEX IF EXISTS idx_rule_activations_current;
+DROP INDEX IF EXISTS idx_rule_activations_rule;
+DROP INDEX IF EXISTS idx_rule_versions_created_at;
+
+DROP TABLE IF EXISTS rule_activations;
+DROP TABLE IF EXISTS rule_versions;
@@ -0,0 +1,55 @@
+-- Create rule_versions table holding an immutable copy of every rule definition
+CREATE TABLE IF NOT EXISTS rule_versions (
+    rule_id VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL,
+    definition JSONB NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    change_type VARCHAR(20) NOT NULL,
+    source_version INTEGER,
+    change_reason TEXT,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (rule_id, version),
+    CONSTRAINT rule_versions_change_type_check CHECK (change_type IN ('baseline', 'created', 'updated', 'rolled_back')),
+    CONSTRAINT rule_versions_source_check CHECK ((change_type = 'rolled_back') = (source_version IS NOT NULL))
+);
+
+-- Create rule_activations table recording which version of each rule was live and when
+CREATE TABLE IF NOT EXISTS rule_activations (
+    id BIGSERIAL PRIMARY KEY,
+    rule_id VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL,
+    change_type VARCHAR(20) NOT NULL,
+    activated_by VARCHAR(255) NOT NULL,
+    activated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deactivated_at TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (rule_id, version) REFERENCES rule_versions(rule_id, version)
+);
+
+-- Create indexes for rule version tables
+CREATE INDEX IF NOT EXISTS idx_rule_versions_created_at ON rule_versions(created_at);
+CREATE INDEX IF NOT EXISTS idx_rule_activations_rule ON rule_activations(rule_id, activated_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rule_activations_current ON rule_activations(rule_id) WHERE deactivated_at IS NULL;
+
+-- Reject changes to recorded rule versions, so the logic behind past alerts
+-- can always be shown as it was
+CREATE OR REPLACE FUNCTION reject_rule_version_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'rule versions are immutable';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER reject_rule_versions_change
+    BEFORE UPDATE OR DELETE ON rule_versions
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_rule_version_change();
+
+-- Add table comments
+COMMENT ON TABLE rule_versions IS 'Immutable history of rule definitions; alert evidence bundles reference rows by rule_id and rule_version';
+COMMENT ON COLUMN rule_versions.checksum IS 'SHA-256 of the canonical JSON definition';
+COMMENT ON COLUMN rule_versions.change_type IS 'baseline versions record rules that existed before versioning';
+COMMENT ON COLUMN rule_versions.source_version IS 'Version a rollback restored';
+COMMENT ON TABLE rule_activations IS 'Activation history of rule versions; the open row of a rule is its live version';
@@ -0,0 +1,16 @@
+-- Drop risk webhook tables
+DROP TRIGGER IF EXISTS record_entity_risk_tiers_transition ON entity_risk_tiers;
+DROP FUNCTION IF EXISTS record_entity_risk_tier_transition();
+
+DROP TRIGGER IF EXISTS update_risk_webhook_deliveries_updated_at ON risk_webhook_deliveries;
+DROP TRIGGER IF EXISTS update_risk_webhook_subscribers_updated_at ON risk_webhook_subscribers;
+
+DROP INDEX IF EXISTS idx_risk_webhook_deliveries_status;
+DROP INDEX IF EXISTS idx_risk_webhook_deliveries_entity;
+DROP INDEX IF EXISTS idx_risk_webhook_deliveries_due;
+DROP INDEX IF EXISTS idx_entity_risk_tier_transitions_occurred_at;
+DROP INDEX IF EXISTS idx_entity_risk_tier_transitions_entity;
+
+DROP TABLE IF EXISTS risk_webhook_deliveries;
+DROP TABLE IF EXISTS entity_risk_tier_transitions;
+DROP TABLE IF EXISTS risk_webhook_subscribers;
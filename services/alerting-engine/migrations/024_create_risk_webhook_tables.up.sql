@@ -0,0 +1,129 @@
+-- Create risk_webhook_subscribers table describing downstream systems notified of entity risk tier changes
+CREATE TABLE IF NOT EXISTS risk_webhook_subscribers (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    endpoint_url TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    tiers TEXT[] NOT NULL DEFAULT '{}',
+    signing_secret VARCHAR(255) NOT NULL,
+    max_attempts INTEGER NOT NULL DEFAULT 8,
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT risk_webhook_subscribers_tiers_check CHECK (tiers <@ ARRAY['low', 'medium', 'high', 'critical']::TEXT[]),
+    CONSTRAINT risk_webhook_subscribers_max_attempts_check CHECK (max_attempts > 0)
+);
+
+-- Create entity_risk_tier_transitions table logging every change of an entity's risk tier
+CREATE TABLE IF NOT EXISTS entity_risk_tier_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    entity_id VARCHAR(255) NOT NULL,
+    previous_tier VARCHAR(20),
+    tier VARCHAR(20),
+    score DOUBLE PRECISION,
+    reason TEXT,
+    changed_by VARCHAR(255),
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT entity_risk_tier_transitions_change_check CHECK (previous_tier IS DISTINCT FROM tier)
+);
+
+-- Create risk_webhook_deliveries table, the outbox of risk tier transitions per subscriber
+CREATE TABLE IF NOT EXISTS risk_webhook_deliveries (
+    id VARCHAR(255) PRIMARY KEY,
+    subscriber_id VARCHAR(255) NOT NULL,
+    transition_id BIGINT NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    response_code INTEGER,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    replayed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT risk_webhook_deliveries_status_check CHECK (status IN ('pending', 'failed', 'delivered', 'dead')),
+    CONSTRAINT risk_webhook_deliveries_transition_unique UNIQUE (subscriber_id, transition_id),
+    FOREIGN KEY (subscriber_id) REFERENCES risk_webhook_subscribers(id) ON DELETE CASCADE,
+    FOREIGN KEY (transition_id) REFERENCES entity_risk_tier_transitions(id) ON DELETE CASCADE
+);
+
+-- Create indexes for risk webhook tables
+CREATE INDEX IF NOT EXISTS idx_entity_risk_tier_transitions_entity ON entity_risk_tier_transitions(entity_id, id);
+CREATE INDEX IF NOT EXISTS idx_entity_risk_tier_transitions_occurred_at ON entity_risk_tier_transitions(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_risk_webhook_deliveries_due
+    ON risk_webhook_deliveries(next_attempt_at) WHERE status IN ('pending', 'failed');
+CREATE INDEX IF NOT EXISTS idx_risk_webhook_deliveries_entity ON risk_webhook_deliveries(subscriber_id, entity_id, transition_id);
+CREATE INDEX IF NOT EXISTS idx_risk_webhook_deliveries_status ON risk_webhook_deliveries(status);
+
+-- Log a risk tier transition and enqueue a delivery for every enabled
+-- subscriber interested in the tier left or entered. Writes that keep the
+-- tier, such as a new score or reason, are not transitions.
+CREATE OR REPLACE FUNCTION record_entity_risk_tier_transition()
+RETURNS TRIGGER AS $$
+DECLARE
+    new_transition_id BIGINT;
+    old_tier VARCHAR(20);
+    new_tier VARCHAR(20);
+    changed_entity VARCHAR(255);
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        old_tier := OLD.tier;
+        changed_entity := OLD.entity_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        new_tier := NEW.tier;
+        changed_entity := NEW.entity_id;
+    END IF;
+
+    IF old_tier IS NOT DISTINCT FROM new_tier THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO entity_risk_tier_transitions (entity_id, previous_tier, tier)
+        VALUES (changed_entity, old_tier, NULL)
+        RETURNING id INTO new_transition_id;
+    ELSE
+        INSERT INTO entity_risk_tier_transitions (entity_id, previous_tier, tier, score, reason, changed_by)
+        VALUES (changed_entity, old_tier, new_tier, NEW.score, NEW.reason, NEW.updated_by)
+        RETURNING id INTO new_transition_id;
+    END IF;
+
+    INSERT INTO risk_webhook_deliveries (id, subscriber_id, transition_id, entity_id)
+    SELECT 'rwd_' || md5(s.id || ':' || new_transition_id::text), s.id, new_transition_id, changed_entity
+    FROM risk_webhook_subscribers s
+    WHERE s.enabled
+      AND (cardinality(s.tiers) = 0 OR old_tier = ANY(s.tiers) OR new_tier = ANY(s.tiers));
+
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER record_entity_risk_tiers_transition
+    AFTER INSERT OR UPDATE OR DELETE ON entity_risk_tiers
+    FOR EACH ROW
+    EXECUTE FUNCTION record_entity_risk_tier_transition();
+
+-- Create triggers
+CREATE TRIGGER update_risk_webhook_subscribers_updated_at
+    BEFORE UPDATE ON risk_webhook_subscribers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_risk_webhook_deliveries_updated_at
+    BEFORE UPDATE ON risk_webhook_deliveries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE risk_webhook_subscribers IS 'Downstream systems sent signed webhooks when an entity risk tier changes';
+COMMENT ON COLUMN risk_webhook_subscribers.tiers IS 'Tiers whose entry or exit is sent; empty sends every transition';
+COMMENT ON TABLE entity_risk_tier_transitions IS 'Change log of entity risk tiers; the id orders transitions and is sent as the webhook sequence';
+COMMENT ON COLUMN entity_risk_tier_transitions.tier IS 'Tier entered; NULL when the entity risk tier was removed';
+COMMENT ON TABLE risk_webhook_deliveries IS 'Outbox of risk tier transitions awaiting delivery to a subscriber';
+COMMENT ON COLUMN risk_webhook_deliveries.replayed_at IS 'Last time the transition was requeued by a replay';
@@ -0,0 +1,6 @@
+-- Drop rule_backtests table
+DROP INDEX IF EXISTS idx_rule_backtests_running;
+DROP INDEX IF EXISTS idx_rule_backtests_started_at;
+DROP INDEX IF EXISTS idx_rule_backtests_rule;
+
+DROP TABLE IF EXISTS rule_backtests;
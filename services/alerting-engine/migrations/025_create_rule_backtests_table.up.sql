@@ -0,0 +1,30 @@
+-- Create rule_backtests table holding replays of historical events against draft rules
+CREATE TABLE IF NOT EXISTS rule_backtests (
+    id VARCHAR(255) PRIMARY KEY,
+    rule_id VARCHAR(255),
+    rule_name VARCHAR(255) NOT NULL,
+    definition JSONB NOT NULL,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    window_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    sample_size INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    result JSONB,
+    error_message TEXT,
+    requested_by VARCHAR(255) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT rule_backtests_status_check CHECK (status IN ('running', 'completed', 'failed')),
+    CONSTRAINT rule_backtests_window_check CHECK (window_start < window_end)
+);
+
+-- Create indexes for rule_backtests table
+CREATE INDEX IF NOT EXISTS idx_rule_backtests_rule ON rule_backtests(rule_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_rule_backtests_started_at ON rule_backtests(started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_rule_backtests_running ON rule_backtests(status) WHERE status = 'running';
+
+-- Add table comments
+COMMENT ON TABLE rule_backtests IS 'Replays of a historical event window against a draft rule, projecting its alert volume';
+COMMENT ON COLUMN rule_backtests.rule_id IS 'Stored rule that was backtested; NULL for an unsaved draft';
+COMMENT ON COLUMN rule_backtests.definition IS 'Rule definition as it was backtested';
+COMMENT ON COLUMN rule_backtests.result IS 'Projected alert counts, sample matches and overlap with existing rules';
@@ -0,0 +1,10 @@
+-- Drop rule suppression tables and alert deduplication columns
+DROP TRIGGER IF EXISTS update_rule_suppression_settings_updated_at ON rule_suppression_settings;
+
+DROP INDEX IF EXISTS idx_alerts_fingerprint_created_at;
+
+DROP TABLE IF EXISTS rule_suppression_settings;
+
+ALTER TABLE alerts DROP COLUMN IF EXISTS last_suppressed_at;
+ALTER TABLE alerts DROP COLUMN IF EXISTS suppressed_count;
+ALTER TABLE alerts DROP COLUMN IF EXISTS fingerprint;
@@ -0,0 +1,34 @@
+-- Add deduplication columns to alerts; repeats of an alert inside its rule's
+-- suppression window are counted on the alert instead of raised
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS fingerprint VARCHAR(64);
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS suppressed_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS last_suppressed_at TIMESTAMP WITH TIME ZONE;
+
+-- Create rule_suppression_settings table overriding the default suppression window per rule
+CREATE TABLE IF NOT EXISTS rule_suppression_settings (
+    rule_id VARCHAR(255) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    window_seconds INTEGER NOT NULL,
+    fingerprint_fields TEXT[] NOT NULL DEFAULT '{}',
+    updated_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT rule_suppression_settings_window_check CHECK (window_seconds > 0)
+);
+
+-- Create indexes for deduplication lookups
+CREATE INDEX IF NOT EXISTS idx_alerts_fingerprint_created_at
+    ON alerts(fingerprint, created_at DESC) WHERE fingerprint IS NOT NULL;
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_rule_suppression_settings_updated_at
+    BEFORE UPDATE ON rule_suppression_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON COLUMN alerts.fingerprint IS 'Hash of the rule, entities and fingerprint fields identifying repeats of this alert';
+COMMENT ON COLUMN alerts.suppressed_count IS 'Repeats of this alert suppressed inside its rule''s suppression window';
+COMMENT ON TABLE rule_suppression_settings IS 'Per-rule alert deduplication windows and fingerprint fields overriding the service defaults';
+COMMENT ON COLUMN rule_suppression_settings.fingerprint_fields IS 'Dotted event paths that, with the rule and entities, identify a repeated alert';
@@ -0,0 +1,20 @@
+-- Drop alert lifecycle tables and columns
+DROP TABLE IF EXISTS alert_state_transitions;
+
+DROP INDEX IF EXISTS idx_alerts_assigned_to;
+DROP INDEX IF EXISTS idx_alerts_open_severity_created_at;
+
+ALTER TABLE alerts DROP COLUMN IF EXISTS closed_by;
+ALTER TABLE alerts DROP COLUMN IF EXISTS closed_at;
+ALTER TABLE alerts DROP COLUMN IF EXISTS assigned_by;
+ALTER TABLE alerts DROP COLUMN IF EXISTS assigned_at;
+ALTER TABLE alerts DROP COLUMN IF EXISTS triaged_by;
+ALTER TABLE alerts DROP COLUMN IF EXISTS triaged_at;
+
+-- Restore the original statuses; lifecycle states fall back to their nearest equivalent
+UPDATE alerts SET status = 'acknowledged' WHERE status IN ('triaged', 'assigned');
+UPDATE alerts SET status = 'resolved' WHERE status IN ('closed_false_positive', 'closed_confirmed');
+UPDATE alerts SET status = 'active' WHERE status = 'open';
+ALTER TABLE alerts DROP CONSTRAINT IF EXISTS alerts_status_check;
+ALTER TABLE alerts ADD CONSTRAINT alerts_status_check
+    CHECK (status IN ('active', 'acknowledged', 'resolved', 'escalated', 'suppressed'));
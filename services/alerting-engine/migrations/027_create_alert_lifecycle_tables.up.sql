@@ -0,0 +1,42 @@
+-- Widen alert statuses to the lifecycle states. 'open' is kept alongside
+-- 'active' as both mark a newly created alert.
+ALTER TABLE alerts DROP CONSTRAINT IF EXISTS alerts_status_check;
+ALTER TABLE alerts ADD CONSTRAINT alerts_status_check CHECK (status IN (
+    'active', 'open', 'acknowledged', 'triaged', 'assigned', 'escalated',
+    'resolved', 'suppressed', 'closed_false_positive', 'closed_confirmed'
+));
+
+-- Add per-state timestamps; acknowledged_at and escalated_at already exist
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS triaged_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS triaged_by VARCHAR(255);
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS assigned_to VARCHAR(255);
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS assigned_by VARCHAR(255);
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS closed_by VARCHAR(255);
+
+-- Create alert_state_transitions table recording every lifecycle move
+CREATE TABLE IF NOT EXISTS alert_state_transitions (
+    id VARCHAR(255) PRIMARY KEY,
+    alert_id VARCHAR(255) NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+    from_status VARCHAR(50) NOT NULL,
+    to_status VARCHAR(50) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    assigned_to VARCHAR(255),
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for transition history and overdue lookups
+CREATE INDEX IF NOT EXISTS idx_alert_state_transitions_alert_id
+    ON alert_state_transitions(alert_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_alerts_open_severity_created_at
+    ON alerts(severity, created_at)
+    WHERE status IN ('active', 'open', 'acknowledged', 'triaged', 'assigned', 'escalated');
+CREATE INDEX IF NOT EXISTS idx_alerts_assigned_to ON alerts(assigned_to) WHERE assigned_to IS NOT NULL;
+
+-- Add table comments
+COMMENT ON COLUMN alerts.triaged_at IS 'When the alert was last triaged';
+COMMENT ON COLUMN alerts.assigned_at IS 'When the alert was last assigned';
+COMMENT ON COLUMN alerts.closed_at IS 'When the alert was closed as a false positive or confirmed';
+COMMENT ON TABLE alert_state_transitions IS 'History of alert lifecycle transitions with the analyst who made them';
@@ -0,0 +1,10 @@
+-- Drop notification template tables
+DROP TRIGGER IF EXISTS reject_notification_template_versions_change ON notification_template_versions;
+DROP FUNCTION IF EXISTS reject_notification_template_version_change();
+DROP TRIGGER IF EXISTS update_notification_templates_updated_at ON notification_templates;
+
+DROP INDEX IF EXISTS idx_notification_templates_channel_rule;
+DROP INDEX IF EXISTS idx_notification_templates_channel_default;
+
+DROP TABLE IF EXISTS notification_template_versions;
+DROP TABLE IF EXISTS notification_templates;
@@ -0,0 +1,65 @@
+-- Create notification_templates table; a template without a rule is the
+-- default for its channel, one with a rule overrides it for that rule
+CREATE TABLE IF NOT EXISTS notification_templates (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    channel VARCHAR(50) NOT NULL,
+    rule_id VARCHAR(255),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    current_version INTEGER NOT NULL DEFAULT 1,
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT notification_templates_channel_check CHECK (channel IN ('email', 'slack', 'webhook'))
+);
+
+-- Create notification_template_versions table holding an immutable copy of every template revision
+CREATE TABLE IF NOT EXISTS notification_template_versions (
+    template_id VARCHAR(255) NOT NULL REFERENCES notification_templates(id),
+    version INTEGER NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    change_type VARCHAR(20) NOT NULL,
+    source_version INTEGER,
+    change_note TEXT,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (template_id, version),
+    CONSTRAINT notification_template_versions_change_type_check CHECK (change_type IN ('created', 'updated', 'rolled_back')),
+    CONSTRAINT notification_template_versions_source_check CHECK ((change_type = 'rolled_back') = (source_version IS NOT NULL))
+);
+
+-- Create indexes; each channel has at most one default and one override per rule
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_templates_channel_default
+    ON notification_templates(channel) WHERE rule_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_templates_channel_rule
+    ON notification_templates(channel, rule_id) WHERE rule_id IS NOT NULL;
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_notification_templates_updated_at
+    BEFORE UPDATE ON notification_templates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Reject changes to recorded template versions, so what past notifications
+-- were rendered from can always be shown
+CREATE OR REPLACE FUNCTION reject_notification_template_version_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'notification template versions are immutable';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER reject_notification_template_versions_change
+    BEFORE UPDATE OR DELETE ON notification_template_versions
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_notification_template_version_change();
+
+-- Add table comments
+COMMENT ON TABLE notification_templates IS 'Notification templates by channel, with optional per-rule overrides';
+COMMENT ON COLUMN notification_templates.current_version IS 'Version of the template notifications are rendered from';
+COMMENT ON TABLE notification_template_versions IS 'Immutable history of notification template subjects and bodies';
+COMMENT ON COLUMN notification_template_versions.source_version IS 'Version a rollback restored';
@@ -0,0 +1,10 @@
+-- Drop notification routing tables
+DROP TRIGGER IF EXISTS update_notification_routes_updated_at ON notification_routes;
+
+DROP INDEX IF EXISTS idx_notification_deliveries_channel_status;
+DROP INDEX IF EXISTS idx_notification_deliveries_alert;
+DROP INDEX IF EXISTS idx_notification_deliveries_rule;
+DROP INDEX IF EXISTS idx_notification_routes_rule;
+
+DROP TABLE IF EXISTS notification_deliveries;
+DROP TABLE IF EXISTS notification_routes;
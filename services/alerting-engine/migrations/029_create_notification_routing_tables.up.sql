@@ -0,0 +1,58 @@
+-- Create notification_routes table; each route sends a rule's notifications
+-- to one recipient on one channel, optionally only from a minimum severity
+CREATE TABLE IF NOT EXISTS notification_routes (
+    id VARCHAR(255) PRIMARY KEY,
+    rule_id VARCHAR(255) NOT NULL,
+    channel VARCHAR(50) NOT NULL,
+    recipient TEXT NOT NULL DEFAULT '',
+    min_severity VARCHAR(20),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT notification_routes_channel_check CHECK (channel IN ('slack', 'pagerduty', 'teams', 'sms')),
+    CONSTRAINT notification_routes_min_severity_check CHECK (min_severity IS NULL OR min_severity IN ('low', 'medium', 'high', 'critical')),
+    CONSTRAINT notification_routes_unique UNIQUE (rule_id, channel, recipient)
+);
+
+-- Create notification_deliveries table recording every attempt to send a
+-- notification through a channel provider and how the provider answered
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id VARCHAR(255) PRIMARY KEY,
+    notification_id VARCHAR(255),
+    rule_id VARCHAR(255),
+    alert_id VARCHAR(255),
+    route_id VARCHAR(255),
+    channel VARCHAR(50) NOT NULL,
+    recipient TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    response_code INTEGER,
+    external_id VARCHAR(255),
+    provider_status VARCHAR(100),
+    error TEXT,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT notification_deliveries_status_check CHECK (status IN ('sent', 'failed'))
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_notification_routes_rule ON notification_routes(rule_id);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_rule ON notification_deliveries(rule_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_alert ON notification_deliveries(alert_id) WHERE alert_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_channel_status ON notification_deliveries(channel, status, created_at DESC);
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_notification_routes_updated_at
+    BEFORE UPDATE ON notification_routes
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE notification_routes IS 'Per-rule notification routing to channel providers';
+COMMENT ON COLUMN notification_routes.recipient IS 'Channel-specific recipient; empty uses the provider default';
+COMMENT ON COLUMN notification_routes.min_severity IS 'Lowest alert severity the route is used for; NULL for all';
+COMMENT ON TABLE notification_deliveries IS 'Delivery attempts through notification channel providers';
+COMMENT ON COLUMN notification_deliveries.external_id IS 'Provider reference, such as a Twilio message SID or PagerDuty dedup key';
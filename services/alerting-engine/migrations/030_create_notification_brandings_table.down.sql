@@ -0,0 +1,4 @@
+-- Drop notification branding table
+DROP TRIGGER IF EXISTS update_notification_brandings_updated_at ON notification_brandings;
+
+DROP TABLE IF EXISTS notification_brandings;
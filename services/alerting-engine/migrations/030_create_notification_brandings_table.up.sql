@@ -0,0 +1,22 @@
+-- Create notification_brandings table holding how each tenant's
+-- notifications present themselves
+CREATE TABLE IF NOT EXISTS notification_brandings (
+    tenant_id VARCHAR(255) PRIMARY KEY,
+    display_name VARCHAR(100) NOT NULL,
+    logo_url TEXT,
+    primary_color VARCHAR(7),
+    support_email VARCHAR(255),
+    footer TEXT,
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT notification_brandings_color_check CHECK (primary_color IS NULL OR primary_color ~ '^#[0-9A-Fa-f]{6}$')
+);
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_notification_brandings_updated_at
+    BEFORE UPDATE ON notification_brandings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
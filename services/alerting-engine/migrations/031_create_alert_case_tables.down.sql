@@ -0,0 +1,12 @@
+-- Drop alert case tables
+DROP TRIGGER IF EXISTS update_alert_cases_updated_at ON alert_cases;
+
+DROP INDEX IF EXISTS idx_alert_correlation_keys_created;
+DROP INDEX IF EXISTS idx_alert_correlation_keys_key;
+DROP INDEX IF EXISTS idx_alert_case_members_case;
+DROP INDEX IF EXISTS idx_alert_cases_investigation;
+DROP INDEX IF EXISTS idx_alert_cases_status;
+
+DROP TABLE IF EXISTS alert_correlation_keys;
+DROP TABLE IF EXISTS alert_case_members;
+DROP TABLE IF EXISTS alert_cases;
@@ -0,0 +1,62 @@
+-- Create alert_cases table holding groups of correlated alerts
+CREATE TABLE IF NOT EXISTS alert_cases (
+    id VARCHAR(255) PRIMARY KEY,
+    title TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    correlation_keys TEXT[] NOT NULL DEFAULT '{}',
+    alert_count INTEGER NOT NULL DEFAULT 0,
+    max_severity VARCHAR(50) NOT NULL,
+    first_alert_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_alert_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    investigation_id VARCHAR(255),
+    linked_by VARCHAR(255),
+    linked_at TIMESTAMP WITH TIME ZONE,
+    closed_by VARCHAR(255),
+    closed_at TIMESTAMP WITH TIME ZONE,
+    close_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT alert_cases_status_check CHECK (status IN ('open', 'linked', 'closed'))
+);
+
+-- Create alert_case_members table; an alert belongs to at most one case
+CREATE TABLE IF NOT EXISTS alert_case_members (
+    alert_id VARCHAR(255) PRIMARY KEY,
+    case_id VARCHAR(255) NOT NULL,
+    matched_keys TEXT[] NOT NULL DEFAULT '{}',
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (alert_id) REFERENCES alerts(id) ON DELETE CASCADE,
+    FOREIGN KEY (case_id) REFERENCES alert_cases(id) ON DELETE CASCADE
+);
+
+-- Create alert_correlation_keys table holding the entities, accounts and communities of recent alerts
+CREATE TABLE IF NOT EXISTS alert_correlation_keys (
+    alert_id VARCHAR(255) NOT NULL,
+    correlation_key VARCHAR(512) NOT NULL,
+    alert_created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    PRIMARY KEY (alert_id, correlation_key),
+    FOREIGN KEY (alert_id) REFERENCES alerts(id) ON DELETE CASCADE
+);
+
+-- Create indexes for alert case tables
+CREATE INDEX IF NOT EXISTS idx_alert_cases_status ON alert_cases(status, last_alert_at DESC);
+CREATE INDEX IF NOT EXISTS idx_alert_cases_investigation ON alert_cases(investigation_id);
+CREATE INDEX IF NOT EXISTS idx_alert_case_members_case ON alert_case_members(case_id, added_at);
+CREATE INDEX IF NOT EXISTS idx_alert_correlation_keys_key ON alert_correlation_keys(correlation_key, alert_created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_alert_correlation_keys_created ON alert_correlation_keys(alert_created_at);
+
+-- Create triggers for updated_at
+CREATE TRIGGER update_alert_cases_updated_at
+    BEFORE UPDATE ON alert_cases
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE alert_cases IS 'Alerts correlated through shared entities, accounts or graph communities within the correlation window';
+COMMENT ON COLUMN alert_cases.correlation_keys IS 'Prefixed entity, account and community keys that tied the member alerts together';
+COMMENT ON COLUMN alert_cases.investigation_id IS 'investigation-toolkit investigation the case was linked to';
+COMMENT ON TABLE alert_case_members IS 'Alerts belonging to an alert case and the keys they matched it on';
+COMMENT ON TABLE alert_correlation_keys IS 'Correlation keys of recent alerts, pruned once older than the key retention';
@@ -0,0 +1,9 @@
+-- Drop alert handoff tables and columns
+DROP TRIGGER IF EXISTS update_alert_handoffs_updated_at ON alert_handoffs;
+
+DROP INDEX IF EXISTS idx_alert_handoffs_receiver;
+DROP INDEX IF EXISTS idx_alert_handoffs_alert;
+
+DROP TABLE IF EXISTS alert_handoffs;
+
+ALTER TABLE alerts DROP COLUMN IF EXISTS sla_extension_seconds;
@@ -0,0 +1,42 @@
+-- Track SLA time granted to analysts receiving an alert on handoff
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS sla_extension_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- Create alert_handoffs table recording ownership transfers with their context
+CREATE TABLE IF NOT EXISTS alert_handoffs (
+    id VARCHAR(255) PRIMARY KEY,
+    alert_id VARCHAR(255) NOT NULL,
+    from_user VARCHAR(255) NOT NULL,
+    to_user VARCHAR(255) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    note TEXT NOT NULL,
+    pending_tasks JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    sla_due_at_before TIMESTAMP WITH TIME ZONE,
+    sla_due_at_after TIMESTAMP WITH TIME ZONE,
+    sla_extension_seconds INTEGER NOT NULL DEFAULT 0,
+    notify_channel VARCHAR(50),
+    notified_at TIMESTAMP WITH TIME ZONE,
+    notify_error TEXT,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    superseded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (alert_id) REFERENCES alerts(id) ON DELETE CASCADE,
+    CONSTRAINT alert_handoffs_status_check CHECK (status IN ('pending', 'accepted', 'superseded'))
+);
+
+-- Create indexes for alert handoff lookups
+CREATE INDEX IF NOT EXISTS idx_alert_handoffs_alert ON alert_handoffs(alert_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_alert_handoffs_receiver ON alert_handoffs(to_user, status, created_at DESC);
+
+-- Create triggers for updated_at
+CREATE TRIGGER update_alert_handoffs_updated_at
+    BEFORE UPDATE ON alert_handoffs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON COLUMN alerts.sla_extension_seconds IS 'Time added to the SLA deadline so handoff receivers have enough time left';
+COMMENT ON TABLE alert_handoffs IS 'Alert ownership transfers with the handoff note and pending tasks passed to the receiver';
+COMMENT ON COLUMN alert_handoffs.pending_tasks IS 'Tasks left for the receiver, each with its completion';
@@ -0,0 +1,2 @@
+-- Drop rule window checkpoints table
+DROP TABLE IF EXISTS rule_window_checkpoints;
@@ -0,0 +1,8 @@
+-- Create rule_window_checkpoints table holding the in-memory state of
+-- windowed rule aggregations, one checkpoint per replica
+CREATE TABLE IF NOT EXISTS rule_window_checkpoints (
+    instance VARCHAR(255) PRIMARY KEY,
+    state JSONB NOT NULL DEFAULT '{}',
+    windows INTEGER NOT NULL DEFAULT 0,
+    checkpointed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
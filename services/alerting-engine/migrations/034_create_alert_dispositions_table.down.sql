@@ -0,0 +1,6 @@
+-- Drop alert dispositions table
+DROP TRIGGER IF EXISTS update_alert_dispositions_updated_at ON alert_dispositions;
+
+DROP INDEX IF EXISTS idx_alert_dispositions_rule;
+
+DROP TABLE IF EXISTS alert_dispositions;
@@ -0,0 +1,29 @@
+-- Create alert_dispositions table recording whether investigators found an
+-- alert to be a true or false positive, one disposition per alert
+CREATE TABLE IF NOT EXISTS alert_dispositions (
+    alert_id VARCHAR(255) PRIMARY KEY,
+    rule_id VARCHAR(255) NOT NULL,
+    disposition VARCHAR(20) NOT NULL,
+    investigator VARCHAR(255) NOT NULL,
+    notes TEXT,
+    source VARCHAR(20) NOT NULL DEFAULT 'investigator',
+    recorded_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (alert_id) REFERENCES alerts(id) ON DELETE CASCADE,
+    CONSTRAINT alert_dispositions_disposition_check CHECK (disposition IN ('true_positive', 'false_positive')),
+    CONSTRAINT alert_dispositions_source_check CHECK (source IN ('investigator', 'lifecycle'))
+);
+
+-- Create index for per-rule feedback reports
+CREATE INDEX IF NOT EXISTS idx_alert_dispositions_rule ON alert_dispositions(rule_id, recorded_at DESC);
+
+-- Create triggers for updated_at
+CREATE TRIGGER update_alert_dispositions_updated_at
+    BEFORE UPDATE ON alert_dispositions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE alert_dispositions IS 'Investigator dispositions of alerts, used to measure rule precision and tune rule thresholds';
+COMMENT ON COLUMN alert_dispositions.source IS 'Whether the disposition was recorded directly or derived from closing the alert';
@@ -0,0 +1,8 @@
+-- Drop severity tuning tables
+DROP TRIGGER IF EXISTS update_severity_tuning_proposals_updated_at ON severity_tuning_proposals;
+DROP TRIGGER IF EXISTS update_rule_severity_guardrails_updated_at ON rule_severity_guardrails;
+
+DROP INDEX IF EXISTS idx_severity_tuning_proposals_status;
+
+DROP TABLE IF EXISTS severity_tuning_proposals;
+DROP TABLE IF EXISTS rule_severity_guardrails;
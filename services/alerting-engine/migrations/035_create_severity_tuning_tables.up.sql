@@ -0,0 +1,52 @@
+-- Create rule_severity_guardrails table bounding the default severities
+-- that severity tuning may propose for a rule
+CREATE TABLE IF NOT EXISTS rule_severity_guardrails (
+    rule_id VARCHAR(255) PRIMARY KEY,
+    min_severity VARCHAR(20) NOT NULL,
+    max_severity VARCHAR(20) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (rule_id) REFERENCES rules(id) ON DELETE CASCADE,
+    CONSTRAINT rule_severity_guardrails_min_check CHECK (min_severity IN ('low', 'medium', 'high', 'critical')),
+    CONSTRAINT rule_severity_guardrails_max_check CHECK (max_severity IN ('low', 'medium', 'high', 'critical'))
+);
+
+-- Create severity_tuning_proposals table holding proposed rule severities
+-- until they are approved or rejected
+CREATE TABLE IF NOT EXISTS severity_tuning_proposals (
+    id VARCHAR(255) PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    since TIMESTAMP WITH TIME ZONE NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    simulation JSONB NOT NULL DEFAULT '{}',
+    proposed_by VARCHAR(255) NOT NULL,
+    proposed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    reviewed_by VARCHAR(255),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    review_comment TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT severity_tuning_proposals_status_check CHECK (status IN ('pending', 'approved', 'rejected'))
+);
+
+-- Create index for listing proposals by status
+CREATE INDEX IF NOT EXISTS idx_severity_tuning_proposals_status ON severity_tuning_proposals(status, proposed_at DESC);
+
+-- Create triggers for updated_at
+CREATE TRIGGER update_rule_severity_guardrails_updated_at
+    BEFORE UPDATE ON rule_severity_guardrails
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_severity_tuning_proposals_updated_at
+    BEFORE UPDATE ON severity_tuning_proposals
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add table comments
+COMMENT ON TABLE rule_severity_guardrails IS 'Lowest and highest default severity that severity tuning may propose for a rule';
+COMMENT ON TABLE severity_tuning_proposals IS 'Rule default severities proposed from dispositions, applied only once approved';
+COMMENT ON COLUMN severity_tuning_proposals.changes IS 'Per-rule current and proposed severity with the evidence behind each, and whether it was applied';
+COMMENT ON COLUMN severity_tuning_proposals.simulation IS 'Alerts raised in the lookback by severity, as raised and as they would have been';
@@ -0,0 +1,2 @@
+-- Drop alert stream index
+DROP INDEX IF EXISTS idx_alerts_stream_cursor;
@@ -0,0 +1,2 @@
+-- Alert stream subscribers tail alerts ordered by creation time and ID
+CREATE INDEX IF NOT EXISTS idx_alerts_stream_cursor ON alerts(created_at, id) WHERE deleted_at IS NULL;
@@ -0,0 +1,12 @@
+-- Drop rule owner tables
+DROP TRIGGER IF EXISTS record_rule_lifecycle_events ON rules;
+DROP FUNCTION IF EXISTS record_rule_enabled_change();
+
+DROP INDEX IF EXISTS idx_rule_owner_digests_owner;
+DROP INDEX IF EXISTS idx_rule_lifecycle_events_rule;
+DROP INDEX IF EXISTS idx_rule_lifecycle_events_pending;
+DROP INDEX IF EXISTS idx_rule_owners_owner;
+
+DROP TABLE IF EXISTS rule_owner_digests;
+DROP TABLE IF EXISTS rule_lifecycle_events;
+DROP TABLE IF EXISTS rule_owners;
@@ -0,0 +1,83 @@
+-- Create rule_owners table naming who is responsible for a rule and where
+-- they hear about its lifecycle events
+CREATE TABLE IF NOT EXISTS rule_owners (
+    rule_id VARCHAR(255) NOT NULL,
+    owner_id VARCHAR(255) NOT NULL,
+    channel VARCHAR(50) NOT NULL,
+    recipient TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (rule_id, owner_id),
+    FOREIGN KEY (rule_id) REFERENCES rules(id) ON DELETE CASCADE,
+    CONSTRAINT rule_owners_channel_check CHECK (channel IN ('slack', 'pagerduty', 'teams', 'sms'))
+);
+
+-- Create rule_lifecycle_events table recording what happened to a rule
+-- until the event is sent to the rule's owners in their digest
+CREATE TABLE IF NOT EXISTS rule_lifecycle_events (
+    id VARCHAR(255) PRIMARY KEY,
+    rule_id VARCHAR(255) NOT NULL,
+    rule_name VARCHAR(255) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    actor VARCHAR(255),
+    details JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    digested_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT rule_lifecycle_events_type_check CHECK (event_type IN ('enabled', 'disabled', 'error_budget_exceeded', 'false_positive_trend'))
+);
+
+-- Create rule_owner_digests table, one digest per owner and digest run
+CREATE TABLE IF NOT EXISTS rule_owner_digests (
+    id VARCHAR(255) PRIMARY KEY,
+    owner_id VARCHAR(255) NOT NULL,
+    channel VARCHAR(50) NOT NULL,
+    recipient TEXT NOT NULL DEFAULT '',
+    event_ids TEXT[] NOT NULL DEFAULT '{}',
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT rule_owner_digests_status_check CHECK (status IN ('sent', 'failed'))
+);
+
+-- Create indexes for rule owner tables
+CREATE INDEX IF NOT EXISTS idx_rule_owners_owner ON rule_owners(owner_id);
+CREATE INDEX IF NOT EXISTS idx_rule_lifecycle_events_pending
+    ON rule_lifecycle_events(occurred_at) WHERE digested_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_rule_lifecycle_events_rule ON rule_lifecycle_events(rule_id, event_type, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_rule_owner_digests_owner ON rule_owner_digests(owner_id, created_at DESC);
+
+-- Record rules being enabled or disabled whichever path changed them:
+-- the API, gRPC, rule pack imports or version rollbacks
+CREATE OR REPLACE FUNCTION record_rule_enabled_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.enabled IS NOT DISTINCT FROM OLD.enabled THEN
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO rule_lifecycle_events (id, rule_id, rule_name, event_type, actor)
+    VALUES ('rule_event_' || md5(NEW.id || clock_timestamp()::text || random()::text),
+            NEW.id, NEW.name,
+            CASE WHEN NEW.enabled THEN 'enabled' ELSE 'disabled' END,
+            NULLIF(NEW.updated_by, ''));
+
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER record_rule_lifecycle_events
+    AFTER UPDATE OF enabled ON rules
+    FOR EACH ROW
+    EXECUTE FUNCTION record_rule_enabled_change();
+
+-- Add table comments
+COMMENT ON TABLE rule_owners IS 'Owners of a rule, notified of its lifecycle events in their digest';
+COMMENT ON COLUMN rule_owners.recipient IS 'Channel-specific recipient; empty uses the provider default';
+COMMENT ON TABLE rule_lifecycle_events IS 'Rules enabled or disabled, over their evaluation error budget or trending towards false positives';
+COMMENT ON COLUMN rule_lifecycle_events.digested_at IS 'When the event was included in its owners'' digests; NULL while pending';
+COMMENT ON TABLE rule_owner_digests IS 'Lifecycle event digests sent to rule owners';
@@ -0,0 +1,15 @@
+-- Drop alert auto-closures
+DROP INDEX IF EXISTS idx_alert_auto_closures_pending_review;
+DROP INDEX IF EXISTS idx_alert_auto_closures_policy;
+DROP INDEX IF EXISTS idx_alert_auto_closures_closed_at;
+
+DROP TABLE IF EXISTS alert_auto_closures;
+
+-- Auto-closed alerts fall back to resolved, which carries no disposition
+UPDATE alerts SET status = 'resolved' WHERE status = 'closed_auto';
+
+ALTER TABLE alerts DROP CONSTRAINT IF EXISTS alerts_status_check;
+ALTER TABLE alerts ADD CONSTRAINT alerts_status_check CHECK (status IN (
+    'active', 'open', 'acknowledged', 'triaged', 'assigned', 'escalated',
+    'resolved', 'suppressed', 'closed_false_positive', 'closed_confirmed'
+));
@@ -0,0 +1,41 @@
+-- Widen alert statuses with closed_auto, entered only when an auto-close
+-- policy closes a stale alert nobody worked
+ALTER TABLE alerts DROP CONSTRAINT IF EXISTS alerts_status_check;
+ALTER TABLE alerts ADD CONSTRAINT alerts_status_check CHECK (status IN (
+    'active', 'open', 'acknowledged', 'triaged', 'assigned', 'escalated',
+    'resolved', 'suppressed', 'closed_false_positive', 'closed_confirmed', 'closed_auto'
+));
+
+-- Create alert_auto_closures table recording which policy closed an alert,
+-- with what disposition, and the outcome of any sampling review of it
+CREATE TABLE IF NOT EXISTS alert_auto_closures (
+    alert_id VARCHAR(255) PRIMARY KEY,
+    policy VARCHAR(100) NOT NULL,
+    rule_id VARCHAR(255) NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    from_status VARCHAR(50) NOT NULL,
+    disposition VARCHAR(100) NOT NULL,
+    inactive_for_seconds BIGINT NOT NULL,
+    last_activity_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    closed_by VARCHAR(255) NOT NULL,
+    closed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sampled BOOLEAN NOT NULL DEFAULT FALSE,
+    review_outcome VARCHAR(20),
+    review_notes TEXT,
+    reviewed_by VARCHAR(255),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (alert_id) REFERENCES alerts(id) ON DELETE CASCADE,
+    CONSTRAINT alert_auto_closures_review_outcome_check CHECK (review_outcome IN ('agreed', 'disagreed'))
+);
+
+-- Create indexes for the auto-closure report and the sampling review queue
+CREATE INDEX IF NOT EXISTS idx_alert_auto_closures_closed_at ON alert_auto_closures(closed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_alert_auto_closures_policy ON alert_auto_closures(policy, closed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_alert_auto_closures_pending_review
+    ON alert_auto_closures(closed_at) WHERE sampled AND reviewed_at IS NULL;
+
+-- Add table comments
+COMMENT ON TABLE alert_auto_closures IS 'Stale alerts closed by an auto-close policy, sampled for periodic review';
+COMMENT ON COLUMN alert_auto_closures.last_activity_at IS 'Latest transition, comment, acknowledgement or suppressed repeat before the alert was closed';
+COMMENT ON COLUMN alert_auto_closures.sampled IS 'Whether the closure was drawn for sampling review at the policy''s sample rate';
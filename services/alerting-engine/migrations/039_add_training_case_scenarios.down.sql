@@ -0,0 +1,15 @@
+-- Case scenarios cannot be represented without their columns
+DELETE FROM training_scenarios WHERE scenario_type = 'case';
+
+DROP INDEX IF EXISTS idx_training_scenarios_source_case_id;
+
+ALTER TABLE training_sessions DROP COLUMN IF EXISTS scenario_types;
+
+ALTER TABLE training_scenarios DROP CONSTRAINT IF EXISTS training_scenarios_source_check;
+ALTER TABLE training_scenarios ALTER COLUMN anonymized_alert SET NOT NULL;
+ALTER TABLE training_scenarios ALTER COLUMN source_alert_id SET NOT NULL;
+
+ALTER TABLE training_scenarios
+    DROP COLUMN IF EXISTS anonymized_case,
+    DROP COLUMN IF EXISTS source_case_id,
+    DROP COLUMN IF EXISTS scenario_type;
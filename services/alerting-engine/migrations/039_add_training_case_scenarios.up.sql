@@ -0,0 +1,20 @@
+-- Training scenarios replay either a historical alert or a historical alert
+-- case with its member alerts
+ALTER TABLE training_scenarios
+    ADD COLUMN IF NOT EXISTS scenario_type VARCHAR(20) NOT NULL DEFAULT 'alert',
+    ADD COLUMN IF NOT EXISTS source_case_id VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS anonymized_case JSONB;
+
+ALTER TABLE training_scenarios ALTER COLUMN source_alert_id DROP NOT NULL;
+ALTER TABLE training_scenarios ALTER COLUMN anonymized_alert DROP NOT NULL;
+
+ALTER TABLE training_scenarios ADD CONSTRAINT training_scenarios_source_check CHECK (
+    (scenario_type = 'alert' AND source_alert_id IS NOT NULL AND anonymized_alert IS NOT NULL) OR
+    (scenario_type = 'case' AND source_case_id IS NOT NULL AND anonymized_case IS NOT NULL)
+);
+
+ALTER TABLE training_sessions ADD COLUMN IF NOT EXISTS scenario_types TEXT[];
+
+CREATE INDEX IF NOT EXISTS idx_training_scenarios_source_case_id ON training_scenarios(source_case_id);
+
+COMMENT ON COLUMN training_scenarios.anonymized_case IS 'Pseudonymized copy of the source alert case and its member alerts shown to the trainee';
@@ -0,0 +1,294 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/autoclose"
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+const day = 24 * time.Hour
+
+var autoCloseConfig = config.AutoCloseConfig{
+	BatchSize:         100,
+	DefaultSampleRate: 0.1,
+	MaxReportClosures: 1000,
+	Policies: []config.AutoClosePolicy{
+		{Name: "stale-low", Severity: "low", InactiveFor: 30 * day, Disposition: "closed_no_activity"},
+		{Name: "slow-rule", Severity: "Low", RuleIDs: []string{"rule-slow"}, InactiveFor: 60 * day,
+			Disposition: "closed_no_activity", SampleRate: 1},
+	},
+}
+
+func TestAutoClosePolicies(t *testing.T) {
+	policies, err := autoclose.NewPolicies(autoCloseConfig)
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.Equal(t, "slow-rule", policies[0].Name, "policies naming rules are applied first")
+	assert.Equal(t, "low", policies[0].Severity)
+	assert.Equal(t, 0.1, policies[1].SampleRate, "policies without a sample rate use the default")
+
+	assert.Equal(t, "slow-rule", autoclose.Select(policies, "rule-slow", "low").Name)
+	assert.Equal(t, "stale-low", autoclose.Select(policies, "rule-other", "low").Name)
+	assert.Nil(t, autoclose.Select(policies, "rule-other", "medium"))
+
+	filter := policies[1].Filter(time.Now(), 50)
+	assert.Equal(t, []string{"rule-slow"}, filter.ExcludeRuleIDs, "the severity-wide policy leaves the named rule alone")
+	assert.NotContains(t, filter.Statuses, database.AlertStatusEscalated)
+
+	valid := config.AutoClosePolicy{Name: "p", Severity: "low", InactiveFor: day, Disposition: "closed_no_activity"}
+	for name, policies := range map[string][]config.AutoClosePolicy{
+		"duplicate name":      {valid, valid},
+		"unknown severity":    {{Name: "p", Severity: "minor", InactiveFor: day, Disposition: "d"}},
+		"no inactivity":       {{Name: "p", Severity: "low", Disposition: "d"}},
+		"bad disposition":     {{Name: "p", Severity: "low", InactiveFor: day, Disposition: "Closed Stale"}},
+		"sample rate above 1": {{Name: "p", Severity: "low", InactiveFor: day, Disposition: "d", SampleRate: 2}},
+		"overlapping rules": {
+			{Name: "a", Severity: "low", RuleIDs: []string{"r"}, InactiveFor: day, Disposition: "d"},
+			{Name: "b", Severity: "low", RuleIDs: []string{"r"}, InactiveFor: day, Disposition: "d"},
+		},
+		"two severity-wide": {
+			{Name: "a", Severity: "low", InactiveFor: day, Disposition: "d"},
+			{Name: "b", Severity: "low", InactiveFor: 2 * day, Disposition: "d"},
+		},
+	} {
+		_, err := autoclose.NewPolicies(config.AutoCloseConfig{Policies: policies})
+		assert.ErrorIs(t, err, autoclose.ErrInvalidPolicy, name)
+	}
+}
+
+func TestAutoCloseSampling(t *testing.T) {
+	assert.False(t, autoclose.Sampled("alert-1", 0))
+	assert.True(t, autoclose.Sampled("alert-1", 1))
+	assert.Equal(t, autoclose.Sampled("alert-1", 0.5), autoclose.Sampled("alert-1", 0.5), "the draw is deterministic")
+
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		if autoclose.Sampled(fmt.Sprintf("alert_%d", i), 0.05) {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 500, sampled, 100)
+}
+
+// autoCloseStore keeps alerts and their auto-closures in memory
+type autoCloseStore struct {
+	alerts   []*database.StaleAlert
+	active   map[string]bool // alerts worked after being selected
+	closures []*database.AlertAutoClosure
+	notes    []*database.AlertComment
+}
+
+func (s *autoCloseStore) ListStale(ctx context.Context, filter database.StaleAlertFilter) ([]*database.StaleAlert, error) {
+	var stale []*database.StaleAlert
+	for _, alert := range s.alerts {
+		if alert.Severity != filter.Severity || !containsString(filter.Statuses, alert.Status) ||
+			!alert.LastActivityAt.Before(filter.InactiveSince) ||
+			(len(filter.RuleIDs) > 0 && !containsString(filter.RuleIDs, alert.RuleID)) ||
+			containsString(filter.ExcludeRuleIDs, alert.RuleID) {
+			continue
+		}
+		stale = append(stale, alert)
+	}
+	return stale, nil
+}
+
+func (s *autoCloseStore) Close(ctx context.Context, closure *database.AlertAutoClosure, transition *database.AlertTransition, note *database.AlertComment) (*database.Alert, error) {
+	if s.active[closure.AlertID] {
+		return nil, database.ErrAlertActive
+	}
+	for _, alert := range s.alerts {
+		if alert.ID != transition.AlertID {
+			continue
+		}
+		if alert.Status != transition.FromStatus {
+			return nil, database.ErrStaleTransition
+		}
+		alert.Status = transition.ToStatus
+		alert.ClosedBy = &transition.Actor
+		alert.ResolutionReason = transition.Reason
+		closure.ClosedAt = time.Now()
+		s.closures = append(s.closures, closure)
+		s.notes = append(s.notes, note)
+		return &alert.Alert, nil
+	}
+	return nil, database.ErrStaleTransition
+}
+
+func (s *autoCloseStore) ListClosures(ctx context.Context, filter database.AutoClosureFilter) ([]*database.AlertAutoClosure, error) {
+	var closures []*database.AlertAutoClosure
+	for _, closure := range s.closures {
+		if !closure.ClosedAt.Before(filter.From) && closure.ClosedAt.Before(filter.To) &&
+			(filter.Policy == "" || closure.Policy == filter.Policy) {
+			closures = append(closures, closure)
+		}
+	}
+	if len(closures) > filter.Limit {
+		closures = closures[:filter.Limit]
+	}
+	return closures, nil
+}
+
+func (s *autoCloseStore) ReviewClosure(ctx context.Context, alertID, outcome string, notes *string, reviewer string, at time.Time) (*database.AlertAutoClosure, error) {
+	for _, closure := range s.closures {
+		if closure.AlertID != alertID {
+			continue
+		}
+		if closure.ReviewedAt != nil {
+			return nil, database.ErrAutoClosureReviewed
+		}
+		closure.ReviewOutcome, closure.ReviewNotes, closure.ReviewedBy, closure.ReviewedAt = &outcome, notes, &reviewer, &at
+		return closure, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func staleAlert(id, ruleID, severity, status string, inactive time.Duration) *database.StaleAlert {
+	return &database.StaleAlert{
+		Alert:          database.Alert{ID: id, RuleID: ruleID, Severity: severity, Status: status},
+		LastActivityAt: time.Now().Add(-inactive),
+	}
+}
+
+func TestAutoCloseService(t *testing.T) {
+	ctx := context.Background()
+	policies, err := autoclose.NewPolicies(autoCloseConfig)
+	require.NoError(t, err)
+
+	store := &autoCloseStore{
+		alerts: []*database.StaleAlert{
+			staleAlert("stale", "rule-a", "low", database.AlertStatusOpen, 45*day),
+			staleAlert("stale-triaged", "rule-a", "low", database.AlertStatusTriaged, 31*day),
+			staleAlert("recent", "rule-a", "low", database.AlertStatusOpen, 10*day),
+			staleAlert("escalated", "rule-a", "low", database.AlertStatusEscalated, 90*day),
+			staleAlert("medium", "rule-a", "medium", database.AlertStatusOpen, 90*day),
+			staleAlert("slow-young", "rule-slow", "low", database.AlertStatusOpen, 45*day),
+			staleAlert("slow-old", "rule-slow", "low", database.AlertStatusOpen, 61*day),
+			staleAlert("worked", "rule-a", "low", database.AlertStatusOpen, 40*day),
+		},
+		active: map[string]bool{"worked": true},
+	}
+	cfg := &config.Config{AutoClose: autoCloseConfig}
+	service := autoclose.NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), store, policies)
+
+	result, err := service.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Closed)
+	assert.Equal(t, 1, result.Skipped, "the alert worked after being selected is left open")
+	require.Len(t, result.Policies, 2)
+	assert.Equal(t, "slow-rule", result.Policies[0].Policy)
+	assert.Equal(t, 1, result.Policies[0].Closed)
+
+	closed := make(map[string]*database.AlertAutoClosure)
+	for _, closure := range store.closures {
+		closed[closure.AlertID] = closure
+	}
+	assert.Contains(t, closed, "stale")
+	assert.Contains(t, closed, "stale-triaged")
+	assert.Contains(t, closed, "slow-old")
+	assert.NotContains(t, closed, "slow-young", "the rule's own policy allows it 60 days")
+	assert.Equal(t, "slow-rule", closed["slow-old"].Policy)
+	assert.True(t, closed["slow-old"].Sampled, "the rule's policy samples every closure")
+	assert.Equal(t, database.AlertStatusTriaged, closed["stale-triaged"].FromStatus)
+	assert.Equal(t, int64(30*day/time.Second), closed["stale"].InactiveForSeconds)
+
+	for _, alert := range store.alerts {
+		if _, ok := closed[alert.ID]; ok {
+			assert.Equal(t, database.AlertStatusClosedAuto, alert.Status)
+			assert.Equal(t, autoclose.Actor, *alert.ClosedBy)
+		} else {
+			assert.NotEqual(t, database.AlertStatusClosedAuto, alert.Status, alert.ID)
+		}
+	}
+	require.Len(t, store.notes, 3)
+	assert.Equal(t, database.CommentTypeResolution, store.notes[0].CommentType)
+	assert.Contains(t, store.notes[0].Content, "Auto-closed as closed_no_activity by policy slow-rule")
+	assert.Contains(t, store.notes[0].Content, "(limit 60 days)")
+
+	// a second run finds nothing left to close
+	result, err = service.Run(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, result.Closed)
+
+	t.Run("Reviews", func(t *testing.T) {
+		_, err := service.Review(ctx, "slow-old", autoclose.ReviewInput{Outcome: "maybe"}, "carol")
+		assert.ErrorIs(t, err, autoclose.ErrInvalidReview)
+
+		_, err = service.Review(ctx, "recent", autoclose.ReviewInput{Outcome: "agreed"}, "carol")
+		assert.ErrorIs(t, err, autoclose.ErrClosureNotFound)
+
+		closure, err := service.Review(ctx, "slow-old", autoclose.ReviewInput{Outcome: " Disagreed ", Notes: "repeat offender"}, "carol")
+		require.NoError(t, err)
+		assert.Equal(t, database.AutoClosureReviewDisagreed, *closure.ReviewOutcome)
+		assert.Equal(t, "carol", *closure.ReviewedBy)
+
+		_, err = service.Review(ctx, "slow-old", autoclose.ReviewInput{Outcome: "agreed"}, "dave")
+		assert.ErrorIs(t, err, database.ErrAutoClosureReviewed)
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		report, err := service.Report(ctx, autoclose.ReportQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Closed)
+		assert.Equal(t, 1, report.Reviewed)
+		require.NotNil(t, report.DisagreedRate)
+		assert.Equal(t, 1.0, *report.DisagreedRate)
+		assert.False(t, report.Truncated)
+
+		require.Len(t, report.Policies, 2)
+		assert.Equal(t, "slow-rule", report.Policies[0].Policy)
+		assert.Equal(t, 1, report.Policies[0].Disagreed)
+		assert.Equal(t, 2, report.Policies[1].Closed)
+		assert.Nil(t, report.Policies[1].DisagreedRate, "nothing of the policy was reviewed")
+
+		require.Len(t, report.Rules, 2)
+		assert.Equal(t, "rule-a", report.Rules[0].RuleID, "rules with the most closures come first")
+
+		for _, pending := range report.PendingReview {
+			assert.True(t, pending.Sampled)
+			assert.Nil(t, pending.ReviewedAt)
+		}
+
+		_, err = service.Report(ctx, autoclose.ReportQuery{From: time.Now(), To: time.Now().Add(-time.Hour)})
+		assert.ErrorIs(t, err, autoclose.ErrInvalidPeriod)
+	})
+}
+
+func TestAutoCloseSummarize(t *testing.T) {
+	at := time.Date(2024, 5, 1, 2, 30, 0, 0, time.UTC)
+	agreed := database.AutoClosureReviewAgreed
+	disagreed := database.AutoClosureReviewDisagreed
+
+	report := autoclose.Summarize([]*database.AlertAutoClosure{
+		{AlertID: "a3", Policy: "p", RuleID: "r1", ClosedAt: at.Add(2 * time.Hour), Sampled: true},
+		{AlertID: "a1", Policy: "p", RuleID: "r1", ClosedAt: at, Sampled: true},
+		{AlertID: "a2", Policy: "p", RuleID: "r2", ClosedAt: at, Sampled: true, ReviewOutcome: &agreed, ReviewedAt: &at},
+		{AlertID: "a4", Policy: "q", RuleID: "r2", ClosedAt: at, Sampled: true, ReviewOutcome: &disagreed, ReviewedAt: &at},
+		{AlertID: "a5", Policy: "q", RuleID: "r2", ClosedAt: at},
+	})
+
+	assert.Equal(t, 5, report.Closed)
+	assert.Equal(t, 4, report.Sampled)
+	assert.Equal(t, 2, report.Reviewed)
+	require.NotNil(t, report.DisagreedRate)
+	assert.InDelta(t, 0.5, *report.DisagreedRate, 1e-9)
+
+	require.Len(t, report.PendingReview, 2)
+	assert.Equal(t, "a1", report.PendingReview[0].AlertID, "the oldest closure waits longest")
+	assert.Equal(t, "r2", report.Rules[0].RuleID)
+	assert.Equal(t, 3, report.Rules[0].Closed)
+
+	empty := autoclose.Summarize(nil)
+	assert.Zero(t, empty.Closed)
+	assert.NotNil(t, empty.PendingReview)
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/correlation"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+func correlationMatch(alertID, severity string, age time.Duration, caseID, caseStatus string, caseSize int, keys ...string) *database.CorrelationMatch {
+	match := &database.CorrelationMatch{
+		AlertID:    alertID,
+		Severity:   severity,
+		CreatedAt:  time.Now().Add(-age),
+		SharedKeys: keys,
+	}
+	if caseID != "" {
+		lastAlertAt := time.Now().Add(-age)
+		match.CaseID = &caseID
+		match.CaseStatus = &caseStatus
+		match.CaseAlertCount = &caseSize
+		match.CaseLastAlertAt = &lastAlertAt
+	}
+	return match
+}
+
+func TestCorrelationKeys(t *testing.T) {
+	event := map[string]interface{}{
+		"from_account": "ACC-1",
+		"to_account":   float64(123456789012),
+		"counterparty": map[string]interface{}{"account_id": []interface{}{"ACC-2", " "}},
+	}
+	contexts := map[string]*database.EntityContext{
+		"cust-1": entityContext("cust-1", "person-7", "community-3"),
+	}
+
+	keys := correlation.Keys([]string{"cust-1", "cust-2", ""}, event,
+		[]string{"account_id", "from_account", "to_account", "counterparty.account_id"}, contexts)
+	assert.Equal(t, []string{
+		"account:123456789012",
+		"account:ACC-1",
+		"account:ACC-2",
+		"community:community-3",
+		"entity:cust-2",
+		"entity:person-7",
+	}, keys)
+
+	assert.Empty(t, correlation.Keys(nil, map[string]interface{}{"amount": 10}, []string{"account_id"}, nil))
+}
+
+func TestAssignOpensCaseForUncasedMatches(t *testing.T) {
+	alert := &database.Alert{ID: "alert_new", Severity: "medium"}
+	alert.CreatedAt = time.Now()
+	matches := []*database.CorrelationMatch{
+		correlationMatch("alert_2", "critical", time.Hour, "", "", 0, "account:ACC-1"),
+		correlationMatch("alert_1", "low", 2*time.Hour, "", "", 0, "account:ACC-1", "entity:person-7"),
+	}
+
+	assignment := correlation.Assign(alert, matches, 0)
+	require.NotNil(t, assignment)
+	require.NotNil(t, assignment.NewCase)
+	assert.Empty(t, assignment.CaseID)
+
+	require.Len(t, assignment.Members, 3)
+	assert.Equal(t, "alert_new", assignment.Members[0].AlertID)
+	assert.Equal(t, []string{"account:ACC-1", "entity:person-7"}, assignment.Members[0].MatchedKeys)
+
+	newCase := assignment.NewCase
+	assert.Equal(t, database.AlertCaseStatusOpen, newCase.Status)
+	assert.Equal(t, "critical", newCase.MaxSeverity)
+	assert.Equal(t, 3, newCase.AlertCount)
+	assert.Equal(t, "Alerts correlated on entity person-7", newCase.Title)
+	assert.True(t, newCase.FirstAlertAt.Before(newCase.LastAlertAt))
+}
+
+func TestAssignJoinsMostRecentOpenCase(t *testing.T) {
+	alert := &database.Alert{ID: "alert_new", Severity: "high"}
+	matches := []*database.CorrelationMatch{
+		correlationMatch("alert_5", "low", time.Minute, "case_closed", database.AlertCaseStatusClosed, 3, "entity:e1"),
+		correlationMatch("alert_4", "low", 10*time.Minute, "case_recent", database.AlertCaseStatusLinked, 4, "account:ACC-9"),
+		correlationMatch("alert_3", "low", 20*time.Minute, "", "", 0, "entity:e1"),
+		correlationMatch("alert_2", "low", time.Hour, "case_old", database.AlertCaseStatusOpen, 2, "entity:e1"),
+	}
+
+	assignment := correlation.Assign(alert, matches, 0)
+	require.NotNil(t, assignment)
+	assert.Nil(t, assignment.NewCase)
+	assert.Equal(t, "case_recent", assignment.CaseID, "the most recently active open or linked case wins; closed cases are skipped")
+
+	require.Len(t, assignment.Members, 2)
+	assert.Equal(t, "alert_new", assignment.Members[0].AlertID)
+	assert.Equal(t, []string{"account:ACC-9", "entity:e1"}, assignment.Members[0].MatchedKeys)
+	assert.Equal(t, "alert_3", assignment.Members[1].AlertID, "alerts without a case come along")
+}
+
+func TestAssignRespectsCaseSize(t *testing.T) {
+	alert := &database.Alert{ID: "alert_new", Severity: "low"}
+
+	full := []*database.CorrelationMatch{
+		correlationMatch("alert_1", "low", time.Minute, "case_full", database.AlertCaseStatusOpen, 3, "entity:e1"),
+	}
+	assert.Nil(t, correlation.Assign(alert, full, 3), "a full case takes no more alerts")
+
+	matches := []*database.CorrelationMatch{
+		correlationMatch("alert_3", "low", time.Minute, "", "", 0, "entity:e1"),
+		correlationMatch("alert_2", "low", 2*time.Minute, "", "", 0, "entity:e1"),
+		correlationMatch("alert_1", "low", 3*time.Minute, "", "", 0, "entity:e1"),
+	}
+	assignment := correlation.Assign(alert, matches, 3)
+	require.NotNil(t, assignment)
+	require.Len(t, assignment.Members, 3)
+	assert.Equal(t, "alert_2", assignment.Members[2].AlertID, "the most recent matches are kept")
+
+	assert.Nil(t, correlation.Assign(alert, nil, 0))
+}
+
+func TestCorrelationCaseTitle(t *testing.T) {
+	assert.Equal(t, "Alerts correlated on account ACC-1 and 1 more",
+		correlation.Title([]string{"account:ACC-1", "account:ACC-2", "community:c1"}))
+	assert.Equal(t, "Alerts correlated on network community c1", correlation.Title([]string{"community:c1"}))
+	assert.Equal(t, "Correlated alerts", correlation.Title(nil))
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/alertcluster"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+func clusterCandidate(id, severity string, age time.Duration, entityIDs ...string) *database.ClusterCandidate {
+	return &database.ClusterCandidate{
+		ID:        id,
+		Title:     "alert " + id,
+		Severity:  severity,
+		EntityIDs: entityIDs,
+		CreatedAt: time.Now().Add(-age),
+	}
+}
+
+func entityContext(entityID, resolvedID, communityID string) *database.EntityContext {
+	entity := &database.EntityContext{EntityID: entityID}
+	if resolvedID != "" {
+		entity.ResolvedEntityID = &resolvedID
+	}
+	if communityID != "" {
+		entity.CommunityID = &communityID
+	}
+	return entity
+}
+
+func TestBuildClustersBySharedResolvedEntity(t *testing.T) {
+	candidates := []*database.ClusterCandidate{
+		clusterCandidate("alert_1", "medium", time.Hour, "acct-1"),
+		clusterCandidate("alert_2", "high", time.Hour, "acct-2"),
+		clusterCandidate("alert_3", "low", time.Hour, "acct-9"),
+	}
+	contexts := map[string]*database.EntityContext{
+		"acct-1": entityContext("acct-1", "person-7", ""),
+		"acct-2": entityContext("acct-2", "person-7", ""),
+	}
+
+	clusters := alertcluster.Build(candidates, contexts, alertcluster.Options{MinSize: 2})
+	require.Len(t, clusters, 1, "raw ids resolving to the same entity are linked; the unrelated alert is left out")
+
+	cluster := clusters[0]
+	assert.Equal(t, []string{"alert_1", "alert_2"}, cluster.AlertIDs)
+	assert.Equal(t, []string{"person-7"}, cluster.SharedEntityIDs)
+	assert.Empty(t, cluster.CommunityIDs)
+	assert.Equal(t, "high", cluster.MaxSeverity)
+	assert.Contains(t, cluster.Title, "person-7")
+	assert.Equal(t, alertcluster.Key([]string{"alert_2", "alert_1"}), cluster.Key)
+}
+
+func TestBuildClustersTransitivelyAndByCommunity(t *testing.T) {
+	candidates := []*database.ClusterCandidate{
+		clusterCandidate("alert_1", "low", time.Hour, "ent-a", "ent-b"),
+		clusterCandidate("alert_2", "low", time.Hour, "ent-b", "ent-c"),
+		clusterCandidate("alert_3", "critical", time.Hour, "ent-c"),
+		clusterCandidate("alert_4", "medium", time.Hour, "ent-x"),
+		clusterCandidate("alert_5", "medium", time.Hour, "ent-y"),
+	}
+	contexts := map[string]*database.EntityContext{
+		"ent-x": entityContext("ent-x", "", "community-3"),
+		"ent-y": entityContext("ent-y", "", "community-3"),
+	}
+
+	clusters := alertcluster.Build(candidates, contexts, alertcluster.Options{MinSize: 2})
+	require.Len(t, clusters, 2)
+
+	// The critical chain sorts first
+	assert.Equal(t, []string{"alert_1", "alert_2", "alert_3"}, clusters[0].AlertIDs)
+	assert.Equal(t, []string{"ent-b", "ent-c"}, clusters[0].SharedEntityIDs)
+	assert.Equal(t, "critical", clusters[0].MaxSeverity)
+
+	assert.Equal(t, []string{"alert_4", "alert_5"}, clusters[1].AlertIDs)
+	assert.Empty(t, clusters[1].SharedEntityIDs)
+	assert.Equal(t, []string{"community-3"}, clusters[1].CommunityIDs)
+	assert.Contains(t, clusters[1].Title, "community-3")
+}
+
+func TestBuildClusterSizeLimits(t *testing.T) {
+	candidates := []*database.ClusterCandidate{
+		clusterCandidate("alert_1", "low", 3*time.Hour, "ent-a"),
+		clusterCandidate("alert_2", "low", time.Hour, "ent-a"),
+		clusterCandidate("alert_3", "high", 5*time.Hour, "ent-a"),
+	}
+
+	assert.Empty(t, alertcluster.Build(candidates, nil, alertcluster.Options{MinSize: 4}))
+
+	clusters := alertcluster.Build(candidates, nil, alertcluster.Options{MinSize: 2, MaxSize: 2})
+	require.Len(t, clusters, 1)
+	assert.Equal(t, []string{"alert_2", "alert_3"}, clusters[0].AlertIDs,
+		"oversized clusters keep the most severe, then most recent alerts")
+
+	// A minimum below two still requires alerts to be related
+	single := alertcluster.Build(candidates[:1], nil, alertcluster.Options{MinSize: 1})
+	assert.Empty(t, single)
+}
+
+func TestClusterKeyIsOrderIndependent(t *testing.T) {
+	assert.Equal(t, alertcluster.Key([]string{"a", "b", "c"}), alertcluster.Key([]string{"c", "a", "b"}))
+	assert.NotEqual(t, alertcluster.Key([]string{"a", "b"}), alertcluster.Key([]string{"a", "b", "c"}))
+}
+
+func TestValidateAcceptInput(t *testing.T) {
+	assert.Error(t, alertcluster.ValidateAcceptInput(alertcluster.AcceptInput{}))
+	assert.NoError(t, alertcluster.ValidateAcceptInput(alertcluster.AcceptInput{Actor: "analyst-1"}))
+	assert.NoError(t, alertcluster.ValidateAcceptInput(alertcluster.AcceptInput{
+		Actor: "analyst-1", CaseType: "money_laundering", Priority: "critical",
+	}))
+	assert.Error(t, alertcluster.ValidateAcceptInput(alertcluster.AcceptInput{Actor: "analyst-1", CaseType: "tax"}))
+	assert.Error(t, alertcluster.ValidateAcceptInput(alertcluster.AcceptInput{Actor: "analyst-1", Priority: "urgent"}))
+}
@@ -0,0 +1,108 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/alerthandoff"
+	"github.com/aegis-shield/services/alerting-engine/internal/alertlifecycle"
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+func TestAlertHandoffValidate(t *testing.T) {
+	valid := alerthandoff.Input{ToUser: "bob", Note: "Waiting on KYC docs", PendingTasks: []string{"Call the branch"}}
+	assert.NoError(t, alerthandoff.Validate(valid, 5))
+
+	tests := map[string]alerthandoff.Input{
+		"no receiver":  {Note: "note"},
+		"no note":      {ToUser: "bob", Note: "  "},
+		"empty task":   {ToUser: "bob", Note: "note", PendingTasks: []string{"ok", " "}},
+		"long task":    {ToUser: "bob", Note: "note", PendingTasks: []string{strings.Repeat("x", 501)}},
+		"many tasks":   {ToUser: "bob", Note: "note", PendingTasks: []string{"1", "2", "3"}},
+		"long comment": {ToUser: "bob", Note: strings.Repeat("x", 10001)},
+	}
+	for name, input := range tests {
+		assert.ErrorIs(t, alerthandoff.Validate(input, 2), alerthandoff.ErrInvalidHandoff, name)
+	}
+}
+
+func TestAlertHandoffTargetStatus(t *testing.T) {
+	status, err := alerthandoff.TargetStatus(database.AlertStatusTriaged)
+	require.NoError(t, err)
+	assert.Equal(t, database.AlertStatusAssigned, status)
+
+	status, err = alerthandoff.TargetStatus(database.AlertStatusEscalated)
+	require.NoError(t, err)
+	assert.Equal(t, database.AlertStatusEscalated, status, "escalated alerts stay escalated under their new owner")
+
+	_, err = alerthandoff.TargetStatus(database.AlertStatusClosedConfirmed)
+	assert.ErrorIs(t, err, alertlifecycle.ErrInvalidTransition)
+}
+
+func TestAlertHandoffSLAExtension(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		due := now.Add(d)
+		return &due
+	}
+
+	// enough time left: no extension
+	assert.Zero(t, alerthandoff.SLAExtension(at(6*time.Hour), now, 4*time.Hour, 24*time.Hour, 0))
+
+	// one hour left: the receiver is given four
+	assert.Equal(t, 3*time.Hour, alerthandoff.SLAExtension(at(time.Hour), now, 4*time.Hour, 24*time.Hour, 0))
+
+	// extensions are capped per alert
+	assert.Equal(t, 30*time.Minute, alerthandoff.SLAExtension(at(time.Hour), now, 4*time.Hour, 24*time.Hour, int((23*time.Hour+30*time.Minute)/time.Second)))
+	assert.Zero(t, alerthandoff.SLAExtension(at(time.Hour), now, 4*time.Hour, 24*time.Hour, int((24*time.Hour)/time.Second)))
+
+	// a breached deadline stands, and alerts without one are not extended
+	assert.Zero(t, alerthandoff.SLAExtension(at(-time.Minute), now, 4*time.Hour, 24*time.Hour, 0))
+	assert.Zero(t, alerthandoff.SLAExtension(nil, now, 4*time.Hour, 24*time.Hour, 0))
+
+	// sub-second remainders are dropped
+	assert.Equal(t, 3*time.Hour, alerthandoff.SLAExtension(at(time.Hour-time.Millisecond), now, 4*time.Hour, 0, 0))
+}
+
+func TestSLAStatusIncludesHandoffExtension(t *testing.T) {
+	targets := alertlifecycle.NewSLATargets(config.SLATargetsConfig{High: 24 * time.Hour})
+	created := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	alert := &database.Alert{Severity: "high", Status: database.AlertStatusAssigned, SLAExtensionSeconds: 3 * 3600}
+	alert.CreatedAt = created
+
+	status := targets.Status(alert, created.Add(25*time.Hour))
+	require.NotNil(t, status.DueAt)
+	assert.Equal(t, created.Add(27*time.Hour), *status.DueAt)
+	assert.False(t, status.Overdue)
+	assert.True(t, targets.Status(alert, created.Add(28*time.Hour)).Overdue)
+}
+
+func TestAlertHandoffNote(t *testing.T) {
+	due := time.Date(2024, 3, 1, 16, 0, 0, 0, time.UTC)
+	handoff := &database.AlertHandoff{
+		ID:                  "handoff_1",
+		FromUser:            "alice",
+		ToUser:              "bob",
+		Note:                " Customer called back; docs expected Monday. ",
+		SLADueAtAfter:       &due,
+		SLAExtensionSeconds: 7200,
+	}
+	handoff.PendingTasks = alerthandoff.NewTasks(handoff.ID, []string{" Review KYC docs ", "Close or escalate"})
+
+	require.Len(t, handoff.PendingTasks, 2)
+	assert.Equal(t, "handoff_1_task_1", handoff.PendingTasks[0].ID)
+	assert.Equal(t, "Review KYC docs", handoff.PendingTasks[0].Description)
+	assert.False(t, handoff.PendingTasks[0].Done)
+
+	assert.Equal(t, "Handed off from @alice to @bob\n\n"+
+		"Customer called back; docs expected Monday.\n\n"+
+		"Pending tasks:\n- Review KYC docs\n- Close or escalate\n\n"+
+		"SLA due 2024-03-01T16:00:00Z (extended by 2h0m0s for the handoff)",
+		alerthandoff.FormatNote(handoff))
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/alertlifecycle"
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+func TestAlertLifecycleTransitions(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{database.AlertStatusActive, database.AlertStatusTriaged, true},
+		{database.AlertStatusOpen, database.AlertStatusAssigned, true},
+		{database.AlertStatusAcknowledged, database.AlertStatusClosedFalsePositive, true},
+		{database.AlertStatusTriaged, database.AlertStatusEscalated, true},
+		{database.AlertStatusAssigned, database.AlertStatusAssigned, true},
+		{database.AlertStatusEscalated, database.AlertStatusClosedConfirmed, true},
+		{database.AlertStatusTriaged, database.AlertStatusTriaged, false},
+		{database.AlertStatusEscalated, database.AlertStatusTriaged, false},
+		{database.AlertStatusClosedConfirmed, database.AlertStatusAssigned, false},
+		{database.AlertStatusResolved, database.AlertStatusTriaged, false},
+		{database.AlertStatusActive, "unknown", false},
+	}
+
+	for _, tt := range tests {
+		err := alertlifecycle.ValidateTransition(tt.from, tt.to)
+		if tt.allowed {
+			assert.NoError(t, err, "%s to %s", tt.from, tt.to)
+		} else {
+			assert.ErrorIs(t, err, alertlifecycle.ErrInvalidTransition, "%s to %s", tt.from, tt.to)
+		}
+	}
+}
+
+func TestAlertLifecycleOpenStates(t *testing.T) {
+	open := alertlifecycle.OpenStates()
+	assert.Contains(t, open, database.AlertStatusActive)
+	assert.Contains(t, open, database.AlertStatusEscalated)
+	assert.NotContains(t, open, database.AlertStatusClosedFalsePositive)
+	assert.NotContains(t, open, database.AlertStatusResolved)
+	assert.NotContains(t, open, database.AlertStatusSuppressed)
+}
+
+func TestSLATargetsDeadline(t *testing.T) {
+	targets := alertlifecycle.NewSLATargets(config.SLATargetsConfig{Critical: 4 * time.Hour, High: 24 * time.Hour})
+	created := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	due, ok := targets.Deadline("critical", created)
+	require.True(t, ok)
+	assert.Equal(t, created.Add(4*time.Hour), due)
+
+	// severities without a target have no deadline
+	_, ok = targets.Deadline("low", created)
+	assert.False(t, ok)
+}
+
+func TestSLAStatus(t *testing.T) {
+	targets := alertlifecycle.NewSLATargets(config.SLATargetsConfig{High: 24 * time.Hour})
+	created := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	now := created.Add(30 * time.Hour)
+
+	open := &database.Alert{Severity: "high", Status: database.AlertStatusAssigned}
+	open.CreatedAt = created
+	status := targets.Status(open, now)
+	require.NotNil(t, status.DueAt)
+	assert.True(t, status.Overdue)
+	assert.True(t, status.Breached)
+
+	closedAt := created.Add(12 * time.Hour)
+	closed := &database.Alert{Severity: "high", Status: database.AlertStatusClosedConfirmed, ClosedAt: &closedAt}
+	closed.CreatedAt = created
+	status = targets.Status(closed, now)
+	assert.False(t, status.Overdue)
+	assert.False(t, status.Breached)
+
+	lateAt := created.Add(26 * time.Hour)
+	closed.ClosedAt = &lateAt
+	assert.True(t, targets.Status(closed, now).Breached)
+
+	untargeted := &database.Alert{Severity: "low", Status: database.AlertStatusActive}
+	untargeted.CreatedAt = created
+	assert.Nil(t, targets.Status(untargeted, now).DueAt)
+}
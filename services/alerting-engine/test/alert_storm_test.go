@@ -0,0 +1,144 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/storm"
+)
+
+func stormDetector() *storm.Detector {
+	return storm.NewDetector(storm.Options{
+		Window:            time.Minute,
+		BaselineSmoothing: 0.5,
+		MinAlerts:         20,
+		RateMultiplier:    5,
+		QuietWindows:      2,
+		TargetAdmitted:    5,
+		MinSampleRate:     0.01,
+	})
+}
+
+// observe records n alerts for a rule spread across the given window
+func observe(d *storm.Detector, ruleID string, windowStart time.Time, n int) storm.Decision {
+	var decision storm.Decision
+	for i := 0; i < n; i++ {
+		decision = d.Observe(ruleID, windowStart.Add(time.Duration(i)*time.Second))
+	}
+	return decision
+}
+
+func TestStormDetectorStartsAboveBaseline(t *testing.T) {
+	detector := stormDetector()
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	observe(detector, "rule_1", start, 4)
+	decision := observe(detector, "rule_1", start.Add(time.Minute), 4)
+	assert.False(t, decision.Storm)
+	assert.Equal(t, 4.0, decision.Baseline)
+
+	storming := start.Add(2 * time.Minute)
+	decision = observe(detector, "rule_1", storming, 19)
+	assert.False(t, decision.Storm, "one alert short of the threshold")
+	assert.Equal(t, 20.0, decision.Threshold)
+
+	decision = detector.Observe("rule_1", storming.Add(30*time.Second))
+	assert.True(t, decision.Storm)
+	assert.True(t, decision.Started)
+	assert.Equal(t, 20, decision.Count)
+
+	decision = observe(detector, "rule_1", storming.Add(40*time.Second), 5)
+	assert.True(t, decision.Storm)
+	assert.False(t, decision.Started, "only the alert that crosses the threshold starts the storm")
+	assert.InDelta(t, 0.2, decision.SampleRate, 1e-9, "five of twenty-five alerts are sampled")
+
+	assert.True(t, detector.Storming("rule_1"))
+	assert.False(t, detector.Storming("rule_2"))
+}
+
+func TestStormDetectorEndsAfterQuietWindows(t *testing.T) {
+	detector := stormDetector()
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	observe(detector, "rule_1", start, 4)
+	observe(detector, "rule_1", start.Add(time.Minute), 4)
+	observe(detector, "rule_1", start.Add(2*time.Minute), 25)
+
+	decision := observe(detector, "rule_1", start.Add(3*time.Minute), 3)
+	assert.True(t, decision.Storm, "a single quiet window does not end the storm")
+
+	assert.Empty(t, detector.Sweep(start.Add(4*time.Minute)))
+	assert.Equal(t, []string{"rule_1"}, detector.Sweep(start.Add(5*time.Minute)))
+	assert.False(t, detector.Storming("rule_1"))
+
+	rates := detector.Rates()
+	require.Len(t, rates, 1)
+	assert.Equal(t, 4.0, rates[0].Baseline, "storm windows are not learned into the baseline")
+}
+
+func TestStormDetectorEndsOnLateAlert(t *testing.T) {
+	detector := stormDetector()
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	observe(detector, "rule_1", start, 25)
+	require.True(t, detector.Storming("rule_1"), "the first window storms once it reaches the minimum")
+
+	decision := detector.Observe("rule_1", start.Add(time.Hour))
+	assert.True(t, decision.Ended)
+	assert.False(t, decision.Storm)
+	assert.Equal(t, 1, decision.Count)
+	assert.Equal(t, 1.0, decision.SampleRate)
+}
+
+func TestStormDetectorTracksRulesIndependently(t *testing.T) {
+	detector := stormDetector()
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	observe(detector, "rule_noisy", start, 30)
+	decision := observe(detector, "rule_quiet", start, 3)
+
+	assert.False(t, decision.Storm)
+	rates := detector.Rates()
+	require.Len(t, rates, 2)
+	assert.Equal(t, "rule_noisy", rates[0].RuleID, "storming rules are listed first")
+	assert.True(t, rates[0].Storm)
+}
+
+func TestStormSampling(t *testing.T) {
+	assert.Equal(t, 1.0, storm.SampleRate(5, 10, 0.01))
+	assert.Equal(t, 0.1, storm.SampleRate(100, 10, 0.01))
+	assert.Equal(t, 0.01, storm.SampleRate(100000, 10, 0.01), "never samples below the floor")
+
+	assert.Equal(t, 0.1, storm.TriageScore("low", 0.1))
+	assert.Equal(t, 0.4, storm.TriageScore("high", 0.1))
+	assert.Equal(t, 1.0, storm.TriageScore("critical", 0.2))
+	assert.Equal(t, 0.1, storm.TriageScore("unknown", 0.1))
+
+	assert.True(t, storm.Sampled("alert_1", 1))
+	assert.False(t, storm.Sampled("alert_1", 0))
+	assert.Equal(t, storm.Sampled("alert_42", 0.5), storm.Sampled("alert_42", 0.5), "sampling is stable for a key")
+
+	admitted := 0
+	for i := 0; i < 10000; i++ {
+		if storm.Sampled(fmt.Sprintf("alert_%d", i), 0.1) {
+			admitted++
+		}
+	}
+	assert.InDelta(t, 1000, admitted, 150)
+}
+
+func TestStormReconcileInput(t *testing.T) {
+	assert.Equal(t, []string{"high", "critical"}, storm.SeveritiesAtLeast("high"))
+	assert.Equal(t, []string{"low", "medium", "high", "critical"}, storm.SeveritiesAtLeast("low"))
+	assert.Nil(t, storm.SeveritiesAtLeast("severe"))
+
+	assert.NoError(t, storm.ValidateReconcileInput(storm.ReconcileInput{Actor: "analyst-1", Action: storm.ActionTriage, MinSeverity: "medium"}))
+	assert.NoError(t, storm.ValidateReconcileInput(storm.ReconcileInput{Actor: "analyst-1", Action: storm.ActionDismiss}))
+	assert.Error(t, storm.ValidateReconcileInput(storm.ReconcileInput{Action: storm.ActionExpand}))
+	assert.Error(t, storm.ValidateReconcileInput(storm.ReconcileInput{Actor: "analyst-1", Action: "ignore"}))
+	assert.Error(t, storm.ValidateReconcileInput(storm.ReconcileInput{Actor: "analyst-1", Action: storm.ActionTriage, MinSeverity: "severe"}))
+}
@@ -0,0 +1,221 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/alertstream"
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+// memoryAlertStore is an in-memory alertstream.Store
+type memoryAlertStore struct {
+	mu     sync.Mutex
+	alerts []*database.Alert
+}
+
+func (m *memoryAlertStore) add(alert *database.Alert) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.alerts = append(m.alerts, alert)
+}
+
+func (m *memoryAlertStore) ListAfter(ctx context.Context, afterTime time.Time, afterID string, until time.Time, filter database.AlertStreamFilter, limit int) ([]*database.Alert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var matched []*database.Alert
+	for _, alert := range m.alerts {
+		after := alert.CreatedAt.After(afterTime) || (alert.CreatedAt.Equal(afterTime) && alert.ID > afterID)
+		if !after || alert.CreatedAt.After(until) {
+			continue
+		}
+		if len(filter.Severities) > 0 && !containsString(filter.Severities, alert.Severity) {
+			continue
+		}
+		if len(filter.RuleIDs) > 0 && !containsString(filter.RuleIDs, alert.RuleID) {
+			continue
+		}
+		if len(filter.EntityIDs) > 0 && !sharesString(filter.EntityIDs, alert.EntityIDs) {
+			continue
+		}
+		matched = append(matched, alert)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.Before(matched[j].CreatedAt)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sharesString(a, b []string) bool {
+	for _, value := range a {
+		if containsString(b, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func newAlertStreamService(store alertstream.Store, maxSubscribers int) *alertstream.Service {
+	cfg := &config.Config{AlertStream: config.AlertStreamConfig{
+		PollInterval:      10 * time.Millisecond,
+		HeartbeatInterval: 50 * time.Millisecond,
+		MinHeartbeat:      10 * time.Millisecond,
+		BatchSize:         2,
+		MaxSubscribers:    maxSubscribers,
+	}}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	return alertstream.NewService(cfg, logger, store)
+}
+
+// collectAlertStream subscribes until want alerts have been received
+func collectAlertStream(t *testing.T, service *alertstream.Service, sub alertstream.Subscription, want int) []*alertstream.Event {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var events []*alertstream.Event
+	alerts := 0
+	err := service.Subscribe(ctx, sub, func(event *alertstream.Event) error {
+		events = append(events, event)
+		if event.Alert != nil {
+			alerts++
+		}
+		if alerts >= want {
+			cancel()
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	return events
+}
+
+func streamedAlertIDs(events []*alertstream.Event) []string {
+	var ids []string
+	for _, event := range events {
+		if event.Alert != nil {
+			ids = append(ids, event.Alert.ID)
+		}
+	}
+	return ids
+}
+
+func TestAlertStreamCursorRoundTrip(t *testing.T) {
+	cursor := alertstream.Cursor{CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC), AlertID: "alert_1:2"}
+
+	parsed, err := alertstream.ParseCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(parsed.CreatedAt))
+	assert.Equal(t, "alert_1:2", parsed.AlertID)
+
+	_, err = alertstream.ParseCursor("not a cursor")
+	assert.ErrorIs(t, err, alertstream.ErrInvalidCursor)
+}
+
+func TestAlertStreamNormalizeFilter(t *testing.T) {
+	filter, err := alertstream.NormalizeFilter(database.AlertStreamFilter{
+		Severities: []string{" High", "", "critical"},
+		RuleIDs:    []string{"rule-1", " "},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"high", "critical"}, filter.Severities)
+	assert.Equal(t, []string{"rule-1"}, filter.RuleIDs)
+	assert.Empty(t, filter.EntityIDs)
+
+	_, err = alertstream.NormalizeFilter(database.AlertStreamFilter{Severities: []string{"urgent"}})
+	assert.ErrorIs(t, err, alertstream.ErrInvalidFilter)
+}
+
+func TestAlertStreamFiltersAndResumes(t *testing.T) {
+	base := time.Now().Add(-time.Minute)
+	store := &memoryAlertStore{}
+	store.add(&database.Alert{ID: "a1", RuleID: "rule-1", Severity: "high", EntityIDs: []string{"e1"}, AuditFields: database.AuditFields{CreatedAt: base}})
+	store.add(&database.Alert{ID: "a2", RuleID: "rule-2", Severity: "low", EntityIDs: []string{"e2"}, AuditFields: database.AuditFields{CreatedAt: base}})
+	store.add(&database.Alert{ID: "a3", RuleID: "rule-1", Severity: "critical", EntityIDs: []string{"e3"}, AuditFields: database.AuditFields{CreatedAt: base}})
+	store.add(&database.Alert{ID: "a4", RuleID: "rule-1", Severity: "high", EntityIDs: []string{"e1"}, AuditFields: database.AuditFields{CreatedAt: base.Add(time.Second)}})
+	service := newAlertStreamService(store, 0)
+
+	start := alertstream.Cursor{CreatedAt: base.Add(-time.Second)}.Encode()
+	sub := alertstream.Subscription{
+		Filter: database.AlertStreamFilter{RuleIDs: []string{"rule-1"}},
+		Cursor: start,
+	}
+	events := collectAlertStream(t, service, sub, 3)
+	assert.Equal(t, []string{"a1", "a3", "a4"}, streamedAlertIDs(events))
+
+	// Resuming from the first alert's cursor replays only what came after it
+	sub.Cursor = events[0].Cursor
+	events = collectAlertStream(t, service, sub, 2)
+	assert.Equal(t, []string{"a3", "a4"}, streamedAlertIDs(events))
+
+	sub = alertstream.Subscription{
+		Filter: database.AlertStreamFilter{Severities: []string{"high"}, EntityIDs: []string{"e1"}},
+		Cursor: start,
+	}
+	events = collectAlertStream(t, service, sub, 2)
+	assert.Equal(t, []string{"a1", "a4"}, streamedAlertIDs(events))
+}
+
+func TestAlertStreamHeartbeat(t *testing.T) {
+	service := newAlertStreamService(&memoryAlertStore{}, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var heartbeat *alertstream.Event
+	err := service.Subscribe(ctx, alertstream.Subscription{Heartbeat: 20 * time.Millisecond}, func(event *alertstream.Event) error {
+		heartbeat = event
+		cancel()
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotNil(t, heartbeat)
+	assert.True(t, heartbeat.Heartbeat)
+	assert.Nil(t, heartbeat.Alert)
+
+	_, err = alertstream.ParseCursor(heartbeat.Cursor)
+	assert.NoError(t, err)
+}
+
+func TestAlertStreamLimitsSubscribers(t *testing.T) {
+	service := newAlertStreamService(&memoryAlertStore{}, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- service.Subscribe(ctx, alertstream.Subscription{}, func(*alertstream.Event) error { return nil })
+	}()
+	require.Eventually(t, func() bool { return service.Subscribers() == 1 }, time.Second, 5*time.Millisecond)
+
+	err := service.Subscribe(context.Background(), alertstream.Subscription{}, func(*alertstream.Event) error { return nil })
+	assert.ErrorIs(t, err, alertstream.ErrTooManySubscribers)
+
+	cancel()
+	assert.True(t, errors.Is(<-done, context.Canceled))
+	assert.Equal(t, 0, service.Subscribers())
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/alertthread"
+	"github.com/aegis-shield/services/alerting-engine/internal/casesync"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+func alertThreadComments() []*database.AlertComment {
+	created := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	question := "comment_1"
+	deleted := "comment_gone"
+	return []*database.AlertComment{
+		{ID: "comment_1", AlertID: "alert_1", UserID: "alice", Content: "Is this customer on payroll?",
+			CommentType: database.CommentTypeQuestion, CreatedAt: created},
+		{ID: "comment_2", AlertID: "alert_1", UserID: "bob", Content: "Yes, @alice - see KYC file",
+			CommentType: database.CommentTypeFinding, ParentCommentID: &question,
+			MentionedUsers: []string{"alice"}, CreatedAt: created.Add(time.Minute)},
+		{ID: "comment_3", AlertID: "alert_1", UserID: "carol", Content: "Reply to a removed comment",
+			CommentType: database.CommentTypeGeneral, ParentCommentID: &deleted, CreatedAt: created.Add(2 * time.Minute)},
+		{ID: "comment_4", AlertID: "alert_1", UserID: "alice", Content: "Payroll run, expected",
+			CommentType: database.CommentTypeResolution, CreatedAt: created.Add(3 * time.Minute),
+			Metadata: database.JSONB{"source": "spoofed", "ticket": "OPS-7"}},
+	}
+}
+
+func TestAlertThreadBuildNestsReplies(t *testing.T) {
+	thread := alertthread.Build(alertThreadComments())
+
+	require.Len(t, thread, 3)
+	assert.Equal(t, "comment_1", thread[0].ID)
+	require.Len(t, thread[0].Replies, 1)
+	assert.Equal(t, "comment_2", thread[0].Replies[0].ID)
+	assert.Equal(t, "comment_3", thread[1].ID, "replies to deleted comments stay at the top level")
+	assert.Equal(t, "comment_4", thread[2].ID)
+}
+
+func TestAlertThreadMentions(t *testing.T) {
+	assert.Equal(t, []string{"alice", "bob.smith"},
+		alertthread.Mentions("@alice please check with @bob.smith, cc @alice"))
+	assert.Empty(t, alertthread.Mentions("mail ops@example.com about it"))
+}
+
+func TestAlertThreadValidType(t *testing.T) {
+	assert.True(t, alertthread.ValidType(database.CommentTypeFinding))
+	assert.False(t, alertthread.ValidType(database.CommentTypeResolution), "resolution notes are written on resolve only")
+	assert.False(t, alertthread.ValidType("chat"))
+}
+
+func TestAlertThreadCaseComments(t *testing.T) {
+	comments := alertthread.CaseComments(alertThreadComments())
+	require.Len(t, comments, 4)
+
+	assert.Equal(t, "comment_1", comments[1]["parent_comment_id"])
+	assert.Equal(t, []string{"alice"}, comments[1]["mentioned_users"])
+	assert.Equal(t, "2024-03-01T10:01:00Z", comments[1]["created_at"])
+
+	resolution := comments[3]
+	assert.Equal(t, database.CommentTypeStatusUpdate, resolution["comment_type"])
+	metadata := resolution["metadata"].(map[string]interface{})
+	assert.Equal(t, "alerting-engine", metadata["source"])
+	assert.Equal(t, "comment_4", metadata["source_comment_id"])
+	assert.Equal(t, database.CommentTypeResolution, metadata["source_comment_type"])
+	assert.Equal(t, "OPS-7", metadata["ticket"])
+}
+
+func TestCaseSyncEscalationCarriesThread(t *testing.T) {
+	connector := caseSyncConnector()
+	connector.FieldMappings = database.JSONB{}
+	delivery := &database.CaseSyncDelivery{ID: "csd_2", EventType: "alert.escalated"}
+
+	payload, err := casesync.BuildPayload(connector, caseSyncAlert(), delivery, alertThreadComments())
+	require.NoError(t, err)
+	assert.Len(t, payload["comments"], 4)
+
+	payload, err = casesync.BuildPayload(connector, caseSyncAlert(), delivery, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, payload, "comments")
+
+	mapped := caseSyncConnector()
+	mapped.FieldMappings["case.discussion"] = "comments"
+	require.NoError(t, casesync.ValidateConnector(mapped))
+
+	payload, err = casesync.BuildPayload(mapped, caseSyncAlert(), delivery, alertThreadComments())
+	require.NoError(t, err)
+	caseDoc := payload["case"].(map[string]interface{})
+	assert.Len(t, caseDoc["discussion"], 4)
+}
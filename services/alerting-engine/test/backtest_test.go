@@ -0,0 +1,112 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/backtest"
+	"github.com/aegis-shield/services/alerting-engine/internal/engine"
+)
+
+func backtestEvent(id string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "entity_id": "cust-" + id, "amount": 9500.0}
+}
+
+func TestBacktestTallyProjectsMatches(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(48 * time.Hour)
+
+	tally := backtest.NewTally("draft", 10)
+	tally.Add(backtestEvent("1"), from.Add(time.Hour), &engine.MatchResult{Matched: []string{"draft"}})
+	tally.Add(backtestEvent("2"), from.Add(2*time.Hour), &engine.MatchResult{Matched: []string{"rule_a"}})
+	tally.Add(backtestEvent("3"), from.Add(30*time.Hour), &engine.MatchResult{Matched: []string{"draft", "rule_a"}})
+	tally.Add(backtestEvent("4"), from.Add(31*time.Hour), &engine.MatchResult{})
+	tally.AddExcluded()
+
+	result := tally.Result(from, to, map[string]string{"rule_a": "Structuring"}, nil, false)
+	assert.Equal(t, 5, result.EventsScanned)
+	assert.Equal(t, 1, result.EventsExcluded)
+	assert.Equal(t, 2, result.Matches)
+	assert.InDelta(t, 0.5, result.MatchRate, 0.0001)
+	assert.InDelta(t, 1.0, result.ProjectedAlertsPerDay, 0.0001)
+	assert.Equal(t, map[string]int{"2024-03-01": 1, "2024-03-02": 1}, result.MatchesByDay)
+	assert.False(t, result.Truncated)
+
+	require.Len(t, result.Samples, 2)
+	assert.Equal(t, "1", result.Samples[0].EventID)
+	assert.Equal(t, []string{"cust-1"}, result.Samples[0].EntityIDs)
+	assert.Equal(t, []string{"rule_a"}, result.Samples[1].AlsoMatchedBy)
+}
+
+func TestBacktestTallyCapsSamples(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tally := backtest.NewTally("draft", 3)
+	for i := 0; i < 50; i++ {
+		tally.Add(backtestEvent("e"), from.Add(time.Duration(i)*time.Minute), &engine.MatchResult{Matched: []string{"draft"}})
+	}
+
+	result := tally.Result(from, from.Add(24*time.Hour), nil, nil, true)
+	assert.Equal(t, 50, result.Matches)
+	assert.Len(t, result.Samples, 3)
+	assert.True(t, result.Truncated)
+	for i := 1; i < len(result.Samples); i++ {
+		assert.False(t, result.Samples[i].OccurredAt.Before(result.Samples[i-1].OccurredAt))
+	}
+}
+
+func TestBacktestTallyCountsEvaluationErrors(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tally := backtest.NewTally("draft", 5)
+	tally.Add(backtestEvent("1"), from, &engine.MatchResult{
+		Errors: map[string]error{"draft": errors.New("cannot compare string and float")},
+	})
+	tally.Add(backtestEvent("2"), from, &engine.MatchResult{
+		Matched: []string{"draft"},
+		Errors:  map[string]error{"rule_a": errors.New("unrelated")},
+	})
+
+	result := tally.Result(from, from.Add(24*time.Hour), nil, nil, false)
+	assert.Equal(t, 1, result.EvaluationErrors)
+	assert.Equal(t, "cannot compare string and float", result.FirstEvaluationError)
+	assert.Equal(t, 1, result.Matches)
+}
+
+func TestBacktestOverlapWeighsFalsePositives(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tally := backtest.NewTally("draft", 5)
+	tally.Add(backtestEvent("1"), from, &engine.MatchResult{Matched: []string{"draft", "rule_a"}})
+	tally.Add(backtestEvent("2"), from, &engine.MatchResult{Matched: []string{"draft", "rule_a", "rule_b"}})
+	tally.Add(backtestEvent("3"), from, &engine.MatchResult{Matched: []string{"draft", "rule_c"}})
+	tally.Add(backtestEvent("4"), from, &engine.MatchResult{Matched: []string{"draft"}})
+	assert.Equal(t, []string{"rule_a", "rule_b", "rule_c"}, tally.OverlappingRules())
+
+	falsePositives := backtest.CountFalsePositives(map[string]map[string]int{
+		"rule_a": {"False positive": 3, "SAR filed": 1},
+		"rule_b": {"not suspicious": 1, "confirmed": 1},
+	})
+	assert.Equal(t, backtest.FalsePositives{Resolved: 4, FalsePositives: 3}, falsePositives["rule_a"])
+
+	result := tally.Result(from, from.Add(24*time.Hour), map[string]string{"rule_a": "Structuring"}, falsePositives, false)
+	overlap := result.Overlap
+	assert.Equal(t, 3, overlap.OverlappingMatches)
+	assert.Equal(t, 1, overlap.NovelMatches)
+	assert.InDelta(t, 0.75, overlap.OverlapRatio, 0.0001)
+	// event 1 weighs 0.75, event 2 the mean of 0.75 and 0.5, event 3 nothing as rule_c has no resolved alerts
+	assert.InDelta(t, 1.375, overlap.EstimatedFalsePositiveMatches, 0.0001)
+	assert.InDelta(t, 1.375/4, overlap.EstimatedFalsePositiveOverlap, 0.0001)
+
+	require.Len(t, overlap.Rules, 3)
+	assert.Equal(t, "rule_a", overlap.Rules[0].RuleID)
+	assert.Equal(t, "Structuring", overlap.Rules[0].RuleName)
+	assert.Equal(t, 2, overlap.Rules[0].SharedMatches)
+	require.NotNil(t, overlap.Rules[0].FalsePositiveRate)
+	assert.InDelta(t, 0.75, *overlap.Rules[0].FalsePositiveRate, 0.0001)
+	assert.Nil(t, overlap.Rules[2].FalsePositiveRate)
+}
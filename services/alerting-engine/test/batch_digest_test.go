@@ -0,0 +1,187 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/batchdigest"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+func batchPortfolio() *database.Portfolio {
+	return &database.Portfolio{
+		ID:                  "portfolio_1",
+		Name:                "Private Banking EMEA",
+		ManagerID:           "pm-42",
+		ManagerContact:      "pm42@example.com",
+		NotificationChannel: "email",
+		EntityIDs:           []string{"ent-a", "ent-b", "ent-c"},
+		Enabled:             true,
+	}
+}
+
+func batchRules() []*database.BatchRule {
+	return []*database.BatchRule{
+		{
+			ID:              "br_volume",
+			Name:            "Daily cash volume",
+			Metric:          "cash_amount",
+			Aggregation:     "sum",
+			Operator:        "gt",
+			Threshold:       10000,
+			Severity:        "high",
+			AlertType:       "structuring",
+			IntradayRuleIDs: []string{"rule_cash_rt"},
+			Enabled:         true,
+		},
+		{
+			ID:          "br_count",
+			Name:        "Daily transfer count",
+			Metric:      "wire_count",
+			Aggregation: "count",
+			Operator:    "gte",
+			Threshold:   5,
+			Severity:    "medium",
+			AlertType:   "velocity",
+			Enabled:     true,
+		},
+		{
+			ID:           "br_other_portfolio",
+			Name:         "Other portfolio only",
+			Metric:       "cash_amount",
+			Aggregation:  "max",
+			Operator:     "gt",
+			Threshold:    0,
+			Severity:     "critical",
+			AlertType:    "structuring",
+			PortfolioIDs: []string{"portfolio_2"},
+			Enabled:      true,
+		},
+	}
+}
+
+func batchAggregates() []*database.DailyAggregate {
+	return []*database.DailyAggregate{
+		{BusinessDate: "2024-03-01", EntityID: "ent-a", Metric: "cash_amount", Total: 12500, Count: 3, MaxValue: 9000},
+		{BusinessDate: "2024-03-01", EntityID: "ent-b", Metric: "cash_amount", Total: 8000, Count: 2, MaxValue: 5000},
+		{BusinessDate: "2024-03-01", EntityID: "ent-c", Metric: "cash_amount", Total: 15000, Count: 4, MaxValue: 7000},
+		{BusinessDate: "2024-03-01", EntityID: "ent-b", Metric: "wire_count", Total: 0, Count: 5},
+		{BusinessDate: "2024-03-01", EntityID: "outsider", Metric: "cash_amount", Total: 99999, Count: 1},
+	}
+}
+
+func TestBatchDigestEvaluate(t *testing.T) {
+	findings := batchdigest.Evaluate("2024-03-01", batchRules(), batchPortfolio(), batchAggregates())
+
+	require.Len(t, findings, 3)
+
+	// High severity first, then by rule name and entity
+	assert.Equal(t, "br_volume", findings[0].RuleID)
+	assert.Equal(t, "ent-a", findings[0].EntityID)
+	assert.Equal(t, 12500.0, findings[0].Value)
+	assert.Equal(t, "ent-c", findings[1].EntityID)
+	assert.Equal(t, "br_count", findings[2].RuleID)
+	assert.Equal(t, 5.0, findings[2].Value)
+
+	for _, finding := range findings {
+		assert.NotEqual(t, "outsider", finding.EntityID, "entities outside the portfolio are ignored")
+		assert.NotEqual(t, "br_other_portfolio", finding.RuleID, "rules scoped to another portfolio are ignored")
+	}
+}
+
+func TestBatchDigestCompare(t *testing.T) {
+	assert.True(t, batchdigest.Compare(10, "gt", 5))
+	assert.False(t, batchdigest.Compare(5, "gt", 5))
+	assert.True(t, batchdigest.Compare(5, "gte", 5))
+	assert.True(t, batchdigest.Compare(1, "lt", 5))
+	assert.True(t, batchdigest.Compare(5, "lte", 5))
+	assert.False(t, batchdigest.Compare(5, "between", 5))
+
+	aggregate := &database.DailyAggregate{Total: 100, Count: 4, MaxValue: 60}
+	assert.Equal(t, 100.0, batchdigest.AggregateValue(aggregate, "sum"))
+	assert.Equal(t, 4.0, batchdigest.AggregateValue(aggregate, "count"))
+	assert.Equal(t, 60.0, batchdigest.AggregateValue(aggregate, "max"))
+}
+
+func TestBatchDigestReconcileSuppressesIntradayDuplicates(t *testing.T) {
+	findings := batchdigest.Evaluate("2024-03-01", batchRules(), batchPortfolio(), batchAggregates())
+
+	intraday := []*database.Alert{
+		// Linked intraday rule on ent-a suppresses the volume finding
+		{ID: "alert_rt_1", RuleID: "rule_cash_rt", Type: "structuring", Source: "kafka", EntityIDs: []string{"ent-a"}},
+		// Same type on ent-c but not from a linked rule, so it does not count
+		{ID: "alert_rt_2", RuleID: "rule_other", Type: "structuring", Source: "kafka", EntityIDs: []string{"ent-c"}},
+		// Rules without linked intraday rules fall back to the alert type
+		{ID: "alert_rt_3", RuleID: "rule_wires", Type: "velocity", Source: "kafka", EntityIDs: []string{"ent-b", "ent-x"}},
+		// Earlier batch alerts are never treated as intraday coverage
+		{ID: "alert_batch", RuleID: "rule_cash_rt", Type: "structuring", Source: batchdigest.AlertSource, EntityIDs: []string{"ent-c"}},
+	}
+
+	kept, suppressed := batchdigest.Reconcile(findings, intraday)
+
+	require.Len(t, kept, 1)
+	assert.Equal(t, "ent-c", kept[0].EntityID)
+	assert.Equal(t, "br_volume", kept[0].RuleID)
+
+	require.Len(t, suppressed, 2)
+	assert.Equal(t, "alert_rt_1", suppressed[0].IntradayAlertID)
+	assert.Equal(t, "ent-a", suppressed[0].EntityID)
+	assert.Equal(t, "alert_rt_3", suppressed[1].IntradayAlertID)
+	assert.Equal(t, "br_count", suppressed[1].RuleID)
+}
+
+func TestBatchDigestFingerprint(t *testing.T) {
+	a := batchdigest.Fingerprint("br_volume", "portfolio_1", "ent-a", "2024-03-01")
+
+	assert.Equal(t, a, batchdigest.Fingerprint("br_volume", "portfolio_1", "ent-a", "2024-03-01"), "reruns of a day produce the same fingerprint")
+	assert.NotEqual(t, a, batchdigest.Fingerprint("br_volume", "portfolio_1", "ent-a", "2024-03-02"))
+	assert.NotEqual(t, a, batchdigest.Fingerprint("br_volume", "portfolio_2", "ent-a", "2024-03-01"))
+	assert.Len(t, a, 64)
+}
+
+func TestBatchDigestRender(t *testing.T) {
+	findings := batchdigest.Evaluate("2024-03-01", batchRules(), batchPortfolio(), batchAggregates())
+	for i, finding := range findings {
+		finding.AlertID = []string{"alert_1", "alert_2", "alert_3"}[i]
+	}
+	suppressed := []database.Suppression{
+		{RuleID: "br_count", RuleName: "Daily transfer count", EntityID: "ent-d", IntradayAlertID: "alert_rt_9"},
+	}
+
+	subject, body := batchdigest.Render(batchPortfolio(), "2024-03-01", findings, suppressed, 2)
+
+	assert.Equal(t, "End-of-day alert digest: Private Banking EMEA, 2024-03-01 (3 alerts, 2 high or critical)", subject)
+	assert.Contains(t, body, "- [HIGH] Daily cash volume: entity ent-a, sum of cash_amount 12500 > 10000 (alert alert_1)")
+	assert.Contains(t, body, "... and 1 more")
+	assert.NotContains(t, body, "alert_3")
+	assert.Contains(t, body, "Already raised intraday (1):")
+	assert.Contains(t, body, "intraday alert alert_rt_9")
+
+	_, empty := batchdigest.Render(batchPortfolio(), "2024-03-01", nil, nil, 10)
+	assert.True(t, strings.Contains(empty, "No batch rules fired"))
+}
+
+func TestBatchDigestValidation(t *testing.T) {
+	rule := batchRules()[0]
+	assert.NoError(t, batchdigest.ValidateRule(rule))
+
+	rule.Aggregation = "avg"
+	assert.Error(t, batchdigest.ValidateRule(rule))
+
+	rule = batchRules()[0]
+	rule.Severity = "urgent"
+	assert.Error(t, batchdigest.ValidateRule(rule))
+
+	portfolio := batchPortfolio()
+	assert.NoError(t, batchdigest.ValidatePortfolio(portfolio))
+
+	portfolio.NotificationChannel = "sms"
+	assert.Error(t, batchdigest.ValidatePortfolio(portfolio))
+
+	portfolio = batchPortfolio()
+	portfolio.ManagerContact = ""
+	assert.Error(t, batchdigest.ValidatePortfolio(portfolio))
+}
@@ -0,0 +1,171 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/casesync"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+)
+
+func caseSyncConnector() *database.CaseSyncConnector {
+	updateURL := "https://cases.example.com/api/cases/{external_id}"
+	return &database.CaseSyncConnector{
+		ID:           "csc_1",
+		Name:         "servicenow",
+		SystemType:   "servicenow",
+		Enabled:      true,
+		EndpointURL:  "https://cases.example.com/api/cases",
+		HTTPMethod:   "POST",
+		UpdateMethod: "PATCH",
+		AuthType:     "none",
+		EventTypes:   []string{"alert.created", "alert.resolved"},
+		FieldMappings: database.JSONB{
+			"case.reference":   "id",
+			"case.state":       "status",
+			"case.short_title": "title",
+			"case.customer":    "metadata.customer.id",
+			"source_system":    "=aegis",
+		},
+		StatusMappings: database.JSONB{
+			"open":         "New",
+			"active":       "New",
+			"acknowledged": "In Progress",
+			"escalated":    "In Progress",
+			"resolved":     "Closed",
+		},
+		UpdateURLTemplate: &updateURL,
+		DriftPolicy:       database.DriftPolicyReport,
+		MaxAttempts:       5,
+	}
+}
+
+func caseSyncAlert() *database.Alert {
+	alert := &database.Alert{
+		ID:       "alert_1",
+		Title:    "Structuring pattern",
+		Status:   "open",
+		Severity: "high",
+		Metadata: map[string]interface{}{
+			"customer": map[string]interface{}{"id": "cust-42"},
+		},
+	}
+	alert.CreatedAt = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	return alert
+}
+
+func TestCaseSyncBuildPayloadAppliesMappings(t *testing.T) {
+	payload, err := casesync.BuildPayload(caseSyncConnector(), caseSyncAlert(),
+		&database.CaseSyncDelivery{ID: "csd_1", EventType: "alert.created"}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "aegis", payload["source_system"])
+	caseDoc, ok := payload["case"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "alert_1", caseDoc["reference"])
+	assert.Equal(t, "New", caseDoc["state"])
+	assert.Equal(t, "Structuring pattern", caseDoc["short_title"])
+	assert.Equal(t, "cust-42", caseDoc["customer"])
+}
+
+func TestCaseSyncBuildPayloadWithoutMappings(t *testing.T) {
+	connector := caseSyncConnector()
+	connector.FieldMappings = database.JSONB{}
+
+	payload, err := casesync.BuildPayload(connector, caseSyncAlert(),
+		&database.CaseSyncDelivery{ID: "csd_1", EventType: "alert.created"}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "alert.created", payload["event"])
+	assert.Equal(t, "csd_1", payload["delivery_id"])
+	assert.Equal(t, "New", payload["status"])
+	assert.Equal(t, "2024-03-01T10:00:00Z", payload["created_at"])
+}
+
+func TestCaseSyncValidateConnector(t *testing.T) {
+	require.NoError(t, casesync.ValidateConnector(caseSyncConnector()))
+
+	unknownField := caseSyncConnector()
+	unknownField.FieldMappings["case.owner"] = "owner_email"
+	assert.Error(t, casesync.ValidateConnector(unknownField))
+
+	conflicting := caseSyncConnector()
+	conflicting.FieldMappings["case"] = "title"
+	assert.Error(t, casesync.ValidateConnector(conflicting))
+
+	missingCredential := caseSyncConnector()
+	missingCredential.AuthType = "bearer"
+	assert.Error(t, casesync.ValidateConnector(missingCredential))
+
+	pullWithoutStatusURL := caseSyncConnector()
+	pullWithoutStatusURL.DriftPolicy = database.DriftPolicyPull
+	assert.Error(t, casesync.ValidateConnector(pullWithoutStatusURL))
+
+	unknownEvent := caseSyncConnector()
+	unknownEvent.EventTypes = []string{"alert.deleted"}
+	assert.Error(t, casesync.ValidateConnector(unknownEvent))
+}
+
+func TestCaseSyncStatusMapping(t *testing.T) {
+	connector := caseSyncConnector()
+
+	assert.Equal(t, "Closed", casesync.ExternalStatus(connector, "resolved"))
+	assert.Equal(t, "suppressed", casesync.ExternalStatus(connector, "suppressed"))
+
+	// Shared external values resolve to the most advanced alert status
+	status, ok := casesync.InternalStatus(connector, "in progress")
+	require.True(t, ok)
+	assert.Equal(t, "acknowledged", status)
+
+	status, ok = casesync.InternalStatus(connector, "New")
+	require.True(t, ok)
+	assert.Equal(t, "active", status)
+
+	_, ok = casesync.InternalStatus(connector, "On Hold")
+	assert.False(t, ok)
+
+	assert.True(t, casesync.Advances("open", "acknowledged"))
+	assert.True(t, casesync.Advances("escalated", "resolved"))
+	assert.False(t, casesync.Advances("resolved", "acknowledged"))
+	assert.False(t, casesync.Advances("acknowledged", "acknowledged"))
+}
+
+func TestCaseSyncSignature(t *testing.T) {
+	body := []byte(`{"external_id":"INC001","status":"In Progress"}`)
+	signature := casesync.Sign("secret", body)
+
+	assert.True(t, casesync.VerifySignature("secret", body, signature))
+	assert.False(t, casesync.VerifySignature("other", body, signature))
+	assert.False(t, casesync.VerifySignature("secret", []byte(`{}`), signature))
+	assert.False(t, casesync.VerifySignature("", body, casesync.Sign("", body)))
+}
+
+func TestCaseSyncExtractAndTemplate(t *testing.T) {
+	document := map[string]interface{}{
+		"result": map[string]interface{}{
+			"sys_id": "abc123",
+			"number": float64(1001),
+		},
+	}
+
+	assert.Equal(t, "abc123", casesync.Extract(document, "result.sys_id"))
+	assert.Equal(t, "1001", casesync.Extract(document, "result.number"))
+	assert.Equal(t, "", casesync.Extract(document, "result.missing"))
+	assert.Equal(t, "", casesync.Extract(document, "result"))
+
+	assert.Equal(t, "https://cases.example.com/api/cases/abc123?alert=alert_1",
+		casesync.ExpandTemplate("https://cases.example.com/api/cases/{external_id}?alert={alert_id}", "abc123", "alert_1"))
+}
+
+func TestCaseSyncRetryDelay(t *testing.T) {
+	base, max := 30*time.Second, 10*time.Minute
+
+	assert.Equal(t, 30*time.Second, casesync.RetryDelay(1, base, max))
+	assert.Equal(t, 60*time.Second, casesync.RetryDelay(2, base, max))
+	assert.Equal(t, 4*time.Minute, casesync.RetryDelay(4, base, max))
+	assert.Equal(t, max, casesync.RetryDelay(10, base, max))
+	assert.Equal(t, max, casesync.RetryDelay(1000, base, max))
+}
@@ -0,0 +1,168 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/deadletter"
+)
+
+// fakeSink records dead letters after rejecting its first failures calls
+type fakeSink struct {
+	failures int
+	calls    int
+	parked   []*deadletter.Message
+	causes   []error
+	attempts []int
+}
+
+func (f *fakeSink) DeadLetter(ctx context.Context, message *deadletter.Message, cause error, attempts int) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return errors.New("dead-letter topic unavailable")
+	}
+	f.parked = append(f.parked, message)
+	f.causes = append(f.causes, cause)
+	f.attempts = append(f.attempts, attempts)
+	return nil
+}
+
+// failingHandler fails its first n calls with err
+func failingHandler(n int, err error) (deadletter.Handler, *int) {
+	calls := 0
+	return func(ctx context.Context, message *deadletter.Message) error {
+		calls++
+		if calls <= n {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func fastPolicy(maxAttempts int) deadletter.Policy {
+	return deadletter.Policy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+	}
+}
+
+func eventMessage() *deadletter.Message {
+	return &deadletter.Message{
+		Topic:     "pattern-detected",
+		Partition: 2,
+		Offset:    41,
+		Value:     []byte(`{"id":"evt_1"}`),
+	}
+}
+
+func TestDeadLetterBackoffDoublesUpToMax(t *testing.T) {
+	policy := deadletter.Policy{
+		MaxAttempts:    10,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     3 * time.Second,
+	}
+
+	assert.Equal(t, time.Duration(0), policy.Backoff(0))
+	assert.Equal(t, 500*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, time.Second, policy.Backoff(2))
+	assert.Equal(t, 2*time.Second, policy.Backoff(3))
+	assert.Equal(t, 3*time.Second, policy.Backoff(4))
+	assert.Equal(t, 3*time.Second, policy.Backoff(40))
+}
+
+func TestDeadLetterPermanentErrors(t *testing.T) {
+	cause := errors.New("invalid character")
+	permanent := fmt.Errorf("processing event: %w", deadletter.Permanent(cause))
+
+	assert.True(t, deadletter.IsPermanent(permanent))
+	assert.ErrorIs(t, permanent, cause)
+	assert.False(t, deadletter.IsPermanent(cause))
+	assert.Nil(t, deadletter.Permanent(nil))
+}
+
+func TestRetrierRecoversFromTransientFailures(t *testing.T) {
+	sink := &fakeSink{}
+	handle, calls := failingHandler(2, errors.New("database unavailable"))
+
+	result, err := deadletter.NewRetrier(fastPolicy(5), sink).Process(context.Background(), eventMessage(), handle)
+	require.NoError(t, err)
+
+	assert.Equal(t, deadletter.OutcomeProcessed, result.Outcome)
+	assert.Equal(t, 3, result.Attempts)
+	assert.Equal(t, 3, *calls)
+	assert.Empty(t, sink.parked)
+}
+
+func TestRetrierDeadLettersAfterMaxAttempts(t *testing.T) {
+	sink := &fakeSink{}
+	cause := errors.New("rule evaluation timed out")
+	handle, calls := failingHandler(100, cause)
+	message := eventMessage()
+
+	result, err := deadletter.NewRetrier(fastPolicy(3), sink).Process(context.Background(), message, handle)
+	require.NoError(t, err)
+
+	assert.Equal(t, deadletter.OutcomeDeadLettered, result.Outcome)
+	assert.Equal(t, 3, result.Attempts)
+	assert.Equal(t, 3, *calls)
+	assert.ErrorIs(t, result.Err, cause)
+	require.Len(t, sink.parked, 1)
+	assert.Same(t, message, sink.parked[0])
+	assert.Equal(t, []int{3}, sink.attempts)
+}
+
+func TestRetrierDeadLettersPermanentFailuresWithoutRetrying(t *testing.T) {
+	sink := &fakeSink{}
+	handle, calls := failingHandler(100, deadletter.Permanent(errors.New("failed to unmarshal event message")))
+
+	result, err := deadletter.NewRetrier(fastPolicy(5), sink).Process(context.Background(), eventMessage(), handle)
+	require.NoError(t, err)
+
+	assert.Equal(t, deadletter.OutcomeDeadLettered, result.Outcome)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, 1, *calls)
+	require.Len(t, sink.causes, 1)
+	assert.True(t, deadletter.IsPermanent(sink.causes[0]))
+}
+
+func TestRetrierKeepsTryingTheSink(t *testing.T) {
+	sink := &fakeSink{failures: 2}
+	handle, _ := failingHandler(100, deadletter.Permanent(errors.New("malformed")))
+
+	result, err := deadletter.NewRetrier(fastPolicy(1), sink).Process(context.Background(), eventMessage(), handle)
+	require.NoError(t, err)
+
+	assert.Equal(t, deadletter.OutcomeDeadLettered, result.Outcome)
+	assert.Equal(t, 3, sink.calls)
+	assert.Len(t, sink.parked, 1)
+}
+
+func TestRetrierStopsWhenContextEnds(t *testing.T) {
+	sink := &fakeSink{failures: 1000}
+	handle, _ := failingHandler(100, errors.New("database unavailable"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := deadletter.NewRetrier(fastPolicy(3), sink).Process(ctx, eventMessage(), handle)
+	require.Error(t, err, "an unparked event must not be committed")
+	assert.Empty(t, sink.parked)
+}
+
+func TestRetrierProcessesOnceWithoutPolicy(t *testing.T) {
+	sink := &fakeSink{}
+	handle, calls := failingHandler(100, errors.New("boom"))
+
+	result, err := deadletter.NewRetrier(deadletter.Policy{}, sink).Process(context.Background(), eventMessage(), handle)
+	require.NoError(t, err)
+
+	assert.Equal(t, deadletter.OutcomeDeadLettered, result.Outcome)
+	assert.Equal(t, 1, *calls)
+}
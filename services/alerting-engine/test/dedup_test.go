@@ -0,0 +1,63 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/dedup"
+)
+
+func dedupEvent(amount float64, channel string) map[string]interface{} {
+	return map[string]interface{}{
+		"amount":       amount,
+		"counterparty": map[string]interface{}{"country": "PA", "channel": channel},
+	}
+}
+
+func TestFingerprintIgnoresEntityAndFieldOrder(t *testing.T) {
+	event := dedupEvent(9500, "wire")
+
+	a := dedup.Fingerprint("rule_1", []string{"cust-1", "acct-9"}, []string{"counterparty.country", "amount"}, event)
+	b := dedup.Fingerprint("rule_1", []string{"acct-9", "cust-1", "cust-1"}, []string{"amount", "counterparty.country"}, event)
+	assert.Equal(t, a, b)
+	assert.Len(t, a, 64)
+}
+
+func TestFingerprintDistinguishesRuleEntitiesAndFields(t *testing.T) {
+	event := dedupEvent(9500, "wire")
+	base := dedup.Fingerprint("rule_1", []string{"cust-1"}, []string{"counterparty.channel"}, event)
+
+	assert.NotEqual(t, base, dedup.Fingerprint("rule_2", []string{"cust-1"}, []string{"counterparty.channel"}, event))
+	assert.NotEqual(t, base, dedup.Fingerprint("rule_1", []string{"cust-2"}, []string{"counterparty.channel"}, event))
+	assert.NotEqual(t, base, dedup.Fingerprint("rule_1", []string{"cust-1"}, []string{"counterparty.channel"}, dedupEvent(9500, "card")))
+
+	// fields not fingerprinted do not tell repeats apart
+	assert.Equal(t, base, dedup.Fingerprint("rule_1", []string{"cust-1"}, []string{"counterparty.channel"}, dedupEvent(120, "wire")))
+}
+
+func TestFingerprintSeparatesMissingAndNullFields(t *testing.T) {
+	withNull := map[string]interface{}{"reference": nil}
+	missing := map[string]interface{}{}
+
+	assert.NotEqual(t,
+		dedup.Fingerprint("rule_1", nil, []string{"reference"}, withNull),
+		dedup.Fingerprint("rule_1", nil, []string{"reference"}, missing))
+	// entity IDs are length-prefixed so they cannot run into each other
+	assert.NotEqual(t,
+		dedup.Fingerprint("rule_1", []string{"ab", "c"}, nil, missing),
+		dedup.Fingerprint("rule_1", []string{"a", "bc"}, nil, missing))
+}
+
+func TestNormalizeFingerprintFields(t *testing.T) {
+	fields, err := dedup.NormalizeFields([]string{" counterparty.country ", "amount", "amount"}, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"amount", "counterparty.country"}, fields)
+
+	_, err = dedup.NormalizeFields([]string{"counterparty..country"}, 5)
+	assert.ErrorIs(t, err, dedup.ErrInvalidSuppression)
+
+	_, err = dedup.NormalizeFields([]string{"a", "b", "c"}, 2)
+	assert.ErrorIs(t, err, dedup.ErrInvalidSuppression)
+}
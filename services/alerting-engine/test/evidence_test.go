@@ -0,0 +1,87 @@
+package test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/evidence"
+)
+
+func TestEvidenceBundle_Unit(t *testing.T) {
+	window := 15 * time.Minute
+	rule := &database.Rule{
+		ID:               "rule-1",
+		Name:             "Large cash deposits",
+		Severity:         "high",
+		Version:          3,
+		EvaluationWindow: &window,
+	}
+	events := []map[string]interface{}{
+		{"id": "evt-1", "type": "transaction", "amount": 15000.5, "entity_id": "entity-1"},
+	}
+	values := map[string]interface{}{
+		"aggregated":        map[string]interface{}{"total_amount": 42000.0},
+		"evaluation_window": window.String(),
+	}
+	evaluatedAt := time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC)
+
+	bundle, err := evidence.Build("alert-1", rule, events, values, evaluatedAt)
+	require.NoError(t, err)
+
+	t.Run("Captures Rule Version", func(t *testing.T) {
+		assert.Equal(t, "alert-1", bundle.AlertID)
+		assert.Equal(t, "rule-1", bundle.RuleID)
+		assert.Equal(t, 3, bundle.RuleVersion)
+		assert.Len(t, bundle.ContentHash, 64)
+		assert.Equal(t, evaluatedAt.Truncate(time.Microsecond), bundle.EvaluatedAt)
+	})
+
+	t.Run("Verifies Unmodified Bundle", func(t *testing.T) {
+		verified, err := evidence.Verify(bundle)
+		require.NoError(t, err)
+		assert.True(t, verified)
+	})
+
+	t.Run("Hash Survives JSONB Normalization", func(t *testing.T) {
+		stored := *bundle
+		stored.TriggerEvents = json.RawMessage(`[ {"type": "transaction", "entity_id": "entity-1", "amount": 15000.5, "id": "evt-1"} ]`)
+
+		verified, err := evidence.Verify(&stored)
+		require.NoError(t, err)
+		assert.True(t, verified)
+	})
+
+	t.Run("Detects Tampering", func(t *testing.T) {
+		tampered := *bundle
+		tampered.WindowValues = json.RawMessage(`{"aggregated": {"total_amount": 1.0}}`)
+
+		verified, err := evidence.Verify(&tampered)
+		require.NoError(t, err)
+		assert.False(t, verified)
+	})
+
+	t.Run("Requires Triggering Event", func(t *testing.T) {
+		_, err := evidence.Build("alert-2", rule, nil, values, evaluatedAt)
+		assert.Error(t, err)
+	})
+	t.Run("Records Windows Without Data As Null", func(t *testing.T) {
+		// A window the event has no group for reads NaN
+		withWindows := map[string]interface{}{
+			"windows": map[string]float64{"large_transfers": 6, "distinct_counterparties": math.NaN()},
+		}
+
+		bundle, err := evidence.Build("alert-3", rule, events, withWindows, evaluatedAt)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"windows": {"large_transfers": 6, "distinct_counterparties": null}}`, string(bundle.WindowValues))
+
+		verified, err := evidence.Verify(bundle)
+		require.NoError(t, err)
+		assert.True(t, verified)
+	})
+}
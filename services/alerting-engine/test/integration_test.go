@@ -170,6 +170,133 @@ func TestAlertRepository_Integration(t *testing.T) {
 		assert.NotNil(t, retrieved.ResolvedAt)
 	})
 
+	t.Run("List After With Empty Stream Filters", func(t *testing.T) {
+		start := time.Now().Add(-time.Hour)
+		ruleID := "stream-rule"
+		alert := &database.Alert{
+			ID:        "test-alert-stream-1",
+			RuleID:    ruleID,
+			Title:     "Streamed Alert",
+			Severity:  "high",
+			Status:    "open",
+			EntityIDs: []string{"entity-1"},
+			CreatedBy: "test-user",
+			UpdatedBy: "test-user",
+		}
+		require.NoError(t, alertRepo.Create(ctx, alert))
+
+		// Subscriptions without a filter send nil lists, which Postgres
+		// receives as NULL arrays
+		for name, filter := range map[string]database.AlertStreamFilter{
+			"no filters":    {},
+			"severity only": {Severities: []string{"high"}},
+			"rule only":     {RuleIDs: []string{ruleID}},
+			"entity only":   {EntityIDs: []string{"entity-1"}},
+			"empty non-nil": {Severities: []string{}, RuleIDs: []string{}, EntityIDs: []string{}},
+		} {
+			alerts, err := alertRepo.ListAfter(ctx, start, "", time.Now().Add(time.Minute), filter, 100)
+			require.NoError(t, err, name)
+
+			ids := make([]string, len(alerts))
+			for i, listed := range alerts {
+				ids[i] = listed.ID
+			}
+			assert.Contains(t, ids, alert.ID, name)
+		}
+
+		alerts, err := alertRepo.ListAfter(ctx, start, "", time.Now().Add(time.Minute),
+			database.AlertStreamFilter{Severities: []string{"low"}}, 100)
+		require.NoError(t, err)
+		for _, listed := range alerts {
+			assert.NotEqual(t, alert.ID, listed.ID, "filters still exclude other severities")
+		}
+	})
+
+	t.Run("List Resolved Between Without Severities", func(t *testing.T) {
+		alert := &database.Alert{
+			ID:        "test-alert-training-1",
+			Title:     "Alert to Replay",
+			Severity:  "medium",
+			Status:    "open",
+			CreatedBy: "test-user",
+			UpdatedBy: "test-user",
+		}
+		require.NoError(t, alertRepo.Create(ctx, alert))
+		require.NoError(t, alertRepo.Resolve(ctx, alert.ID, "test-analyst", "False positive"))
+
+		// Training sessions without a severity filter send a NULL array
+		alerts, err := alertRepo.ListResolvedBetween(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Minute), nil, 100)
+		require.NoError(t, err)
+
+		ids := make([]string, len(alerts))
+		for i, listed := range alerts {
+			ids[i] = listed.ID
+		}
+		assert.Contains(t, ids, alert.ID)
+	})
+
+	t.Run("Case Sync Callbacks Need A Link", func(t *testing.T) {
+		caseSyncRepo := database.NewCaseSyncRepository(db, logger)
+		connector := &database.CaseSyncConnector{
+			ID:              "connector-callbacks",
+			Name:            "Callback Connector",
+			SystemType:      "generic",
+			Enabled:         true,
+			EndpointURL:     "https://cases.example.com/api/cases",
+			HTTPMethod:      "POST",
+			UpdateMethod:    "PATCH",
+			AuthType:        "none",
+			Headers:         database.JSONB{},
+			EventTypes:      []string{"created"},
+			FieldMappings:   database.JSONB{},
+			StatusMappings:  database.JSONB{},
+			CallbackMapping: database.JSONB{},
+			CallbackSecret:  "secret",
+			DriftPolicy:     "report",
+			MaxAttempts:     3,
+			CreatedBy:       "test-user",
+		}
+		require.NoError(t, caseSyncRepo.CreateConnector(ctx, connector))
+
+		alert := &database.Alert{
+			ID:        "test-alert-case-sync-1",
+			Title:     "Alert Pushed to Cases",
+			Severity:  "high",
+			Status:    "open",
+			CreatedBy: "test-user",
+			UpdatedBy: "test-user",
+		}
+		require.NoError(t, alertRepo.Create(ctx, alert))
+
+		// A signed callback cannot link an alert the connector was never sent
+		_, err := caseSyncRepo.ApplyCallback(ctx, connector.ID, alert.ID, "CASE-1", "open", "", "case-system")
+		assert.ErrorIs(t, err, database.ErrCaseLinkNotFound)
+		_, err = caseSyncRepo.GetLink(ctx, connector.ID, alert.ID)
+		assert.ErrorIs(t, err, database.ErrCaseLinkNotFound)
+
+		delivery := &database.CaseSyncDelivery{
+			ID:          "delivery-callbacks-1",
+			ConnectorID: connector.ID,
+			AlertID:     alert.ID,
+			EventType:   "created",
+			AlertStatus: "open",
+		}
+		require.NoError(t, caseSyncRepo.EnqueueDelivery(ctx, delivery))
+		require.NoError(t, caseSyncRepo.MarkDelivered(ctx, delivery, 201, "CASE-1", nil))
+
+		_, err = caseSyncRepo.ApplyCallback(ctx, connector.ID, alert.ID, "CASE-2", "open", "", "case-system")
+		assert.ErrorIs(t, err, database.ErrCaseLinkNotFound, "callbacks for another external case are rejected")
+
+		updated, err := caseSyncRepo.ApplyCallback(ctx, connector.ID, alert.ID, "CASE-1", "in_progress", "acknowledged", "case-system")
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		link, err := caseSyncRepo.GetLink(ctx, connector.ID, alert.ID)
+		require.NoError(t, err)
+		require.NotNil(t, link.AcknowledgedAt)
+		assert.Equal(t, "in_progress", *link.ExternalStatus)
+	})
+
 	t.Run("Get Alert Statistics", func(t *testing.T) {
 		since := time.Now().Add(-1 * time.Hour)
 		stats, err := alertRepo.GetStatsByTimeRange(ctx, since, time.Now())
@@ -0,0 +1,491 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/notification"
+)
+
+// capturedRequest is what a fake provider endpoint received
+type capturedRequest struct {
+	path     string
+	header   http.Header
+	body     []byte
+	username string
+	password string
+}
+
+func providerServer(t *testing.T, status int, response string) (*httptest.Server, *capturedRequest) {
+	t.Helper()
+	captured := &capturedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured.path = r.URL.Path
+		captured.header = r.Header.Clone()
+		captured.body, _ = io.ReadAll(r.Body)
+		captured.username, captured.password, _ = r.BasicAuth()
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, captured
+}
+
+func sampleNotificationMessage() *notification.Message {
+	return &notification.Message{
+		RuleID:    "rule_1",
+		RuleName:  "Large cash deposits",
+		AlertID:   "alert_1",
+		Subject:   "High risk deposit",
+		Body:      "Rule Large cash deposits has been triggered",
+		Severity:  "high",
+		Priority:  "high",
+		CreatedAt: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+	}
+}
+
+func TestNotificationSlackProvider(t *testing.T) {
+	server, captured := providerServer(t, http.StatusOK, "ok")
+	provider := notification.NewSlackProvider(config.SlackConfig{WebhookURL: server.URL, DefaultChannel: "#alerts", Timeout: time.Second})
+
+	receipt, err := provider.Send(context.Background(), sampleNotificationMessage())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, receipt.StatusCode)
+	assert.Equal(t, "ok", receipt.ProviderStatus)
+
+	var payload notification.SlackMessage
+	require.NoError(t, json.Unmarshal(captured.body, &payload))
+	assert.Equal(t, "#alerts", payload.Channel)
+	assert.Equal(t, "High risk deposit", payload.Text)
+	require.NotEmpty(t, payload.Blocks)
+	assert.Contains(t, payload.Blocks[0].Text.Text, "Rule Large cash deposits has been triggered")
+
+	// A body rendered from a Slack template is posted as is, to the route's channel
+	message := sampleNotificationMessage()
+	message.Recipient = "#fraud-ops"
+	message.Body = `{"text": "templated"}`
+	_, err = provider.Send(context.Background(), message)
+	require.NoError(t, err)
+
+	var rendered map[string]interface{}
+	require.NoError(t, json.Unmarshal(captured.body, &rendered))
+	assert.Equal(t, map[string]interface{}{"text": "templated", "channel": "#fraud-ops"}, rendered)
+}
+
+func TestNotificationPagerDutyProvider(t *testing.T) {
+	server, captured := providerServer(t, http.StatusAccepted, `{"status": "success", "message": "Event processed", "dedup_key": "alert_1"}`)
+	provider := notification.NewPagerDutyProvider(config.PagerDutyConfig{
+		EventsURL:      server.URL,
+		IntegrationKey: "0123456789abcdef0123456789abcdef",
+		Timeout:        time.Second,
+	})
+
+	receipt, err := provider.Send(context.Background(), sampleNotificationMessage())
+	require.NoError(t, err)
+	assert.Equal(t, "alert_1", receipt.ExternalID)
+	assert.Equal(t, "success", receipt.ProviderStatus)
+
+	var event notification.PagerDutyEvent
+	require.NoError(t, json.Unmarshal(captured.body, &event))
+	assert.Equal(t, "0123456789abcdef0123456789abcdef", event.RoutingKey)
+	assert.Equal(t, "trigger", event.EventAction)
+	assert.Equal(t, "alert_1", event.DedupKey)
+	assert.Equal(t, "error", event.Payload.Severity)
+	assert.Equal(t, "High risk deposit", event.Payload.Summary)
+	assert.Equal(t, "rule_1", event.Payload.CustomDetails["rule_id"])
+}
+
+func TestNotificationPagerDutyProviderRejected(t *testing.T) {
+	server, _ := providerServer(t, http.StatusBadRequest, `{"status": "invalid event", "message": "Event object is invalid"}`)
+	provider := notification.NewPagerDutyProvider(config.PagerDutyConfig{EventsURL: server.URL, Timeout: time.Second})
+
+	_, err := provider.Send(context.Background(), sampleNotificationMessage())
+	var providerErr *notification.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, http.StatusBadRequest, providerErr.StatusCode)
+	assert.Equal(t, notification.ChannelPagerDuty, providerErr.Channel)
+}
+
+func TestNotificationTeamsProvider(t *testing.T) {
+	server, captured := providerServer(t, http.StatusOK, "1")
+	provider := notification.NewTeamsProvider(config.TeamsConfig{WebhookURL: server.URL, Timeout: time.Second})
+
+	_, err := provider.Send(context.Background(), sampleNotificationMessage())
+	require.NoError(t, err)
+
+	var card notification.TeamsMessage
+	require.NoError(t, json.Unmarshal(captured.body, &card))
+	assert.Equal(t, "MessageCard", card.Type)
+	assert.Equal(t, "FF9900", card.ThemeColor)
+	require.Len(t, card.Sections, 1)
+	assert.Equal(t, "High risk deposit", card.Sections[0].ActivityTitle)
+	assert.Contains(t, card.Sections[0].Facts, notification.TeamsFact{Name: "Alert ID", Value: "alert_1"})
+}
+
+func TestNotificationSMSProvider(t *testing.T) {
+	server, captured := providerServer(t, http.StatusCreated, `{"sid": "SM123", "status": "queued"}`)
+	provider := notification.NewSMSProvider(config.SMSConfig{
+		TwilioBaseURL: server.URL,
+		TwilioSID:     "AC123",
+		TwilioToken:   "secret",
+		FromNumber:    "+15550000000",
+		Timeout:       time.Second,
+	})
+
+	message := sampleNotificationMessage()
+	message.Recipient = "+15551234567"
+	receipt, err := provider.Send(context.Background(), message)
+	require.NoError(t, err)
+	assert.Equal(t, "SM123", receipt.ExternalID)
+	assert.Equal(t, "queued", receipt.ProviderStatus)
+
+	assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", captured.path)
+	assert.Equal(t, "AC123", captured.username)
+	assert.Equal(t, "secret", captured.password)
+	form, err := url.ParseQuery(string(captured.body))
+	require.NoError(t, err)
+	assert.Equal(t, "+15551234567", form.Get("To"))
+	assert.Equal(t, "+15550000000", form.Get("From"))
+	assert.Equal(t, "High risk deposit\nRule Large cash deposits has been triggered", form.Get("Body"))
+
+	message.Recipient = ""
+	_, err = provider.Send(context.Background(), message)
+	assert.ErrorIs(t, err, notification.ErrInvalidRecipient)
+}
+
+func TestNotificationProviderRecipients(t *testing.T) {
+	slack := notification.NewSlackProvider(config.SlackConfig{WebhookURL: "https://hooks.slack.test/x"})
+	pagerDuty := notification.NewPagerDutyProvider(config.PagerDutyConfig{})
+	teams := notification.NewTeamsProvider(config.TeamsConfig{WebhookURL: "https://teams.test/hook"})
+	sms := notification.NewSMSProvider(config.SMSConfig{})
+
+	tests := []struct {
+		provider  notification.Provider
+		recipient string
+		valid     bool
+	}{
+		{slack, "", true},
+		{slack, "#fraud-ops", true},
+		{slack, "@oncall", true},
+		{slack, "C024BE91L", true},
+		{slack, "fraud ops", false},
+		{slack, "#", false},
+		{pagerDuty, "", false}, // no integration key configured
+		{pagerDuty, "0123456789abcdef0123456789abcdef", true},
+		{pagerDuty, "short", false},
+		{teams, "", true},
+		{teams, "https://example.webhook.office.com/webhookb2/abc", true},
+		{teams, "http://example.webhook.office.com/webhookb2/abc", false},
+		{sms, "", false},
+		{sms, "+15551234567", true},
+		{sms, "5551234567", false},
+	}
+	for _, tt := range tests {
+		err := tt.provider.ValidateRecipient(tt.recipient)
+		if tt.valid {
+			assert.NoError(t, err, "%s %q", tt.provider.Channel(), tt.recipient)
+		} else {
+			assert.ErrorIs(t, err, notification.ErrInvalidRecipient, "%s %q", tt.provider.Channel(), tt.recipient)
+		}
+	}
+}
+
+func TestNotificationRegistryFromConfig(t *testing.T) {
+	registry := notification.NewRegistryFromConfig(config.NotificationsConfig{
+		Slack: config.SlackConfig{Enabled: true},
+		SMS:   config.SMSConfig{Enabled: true},
+		Teams: config.TeamsConfig{Enabled: false},
+	})
+	assert.Equal(t, []string{"slack", "sms"}, registry.Channels())
+
+	_, ok := registry.Provider(notification.ChannelTeams)
+	assert.False(t, ok)
+}
+
+// fakeChannelProvider records what it was asked to send
+type fakeChannelProvider struct {
+	channel string
+	err     error
+	sent    []*notification.Message
+}
+
+func (p *fakeChannelProvider) Channel() string { return p.channel }
+
+func (p *fakeChannelProvider) ValidateRecipient(recipient string) error {
+	if p.channel == notification.ChannelSMS && recipient == "" {
+		return notification.ErrInvalidRecipient
+	}
+	return nil
+}
+
+func (p *fakeChannelProvider) Send(ctx context.Context, message *notification.Message) (*notification.Receipt, error) {
+	p.sent = append(p.sent, message)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &notification.Receipt{StatusCode: http.StatusOK, ExternalID: "ext_" + message.Recipient}, nil
+}
+
+// fakeRouteStore keeps notification routes and deliveries in memory
+type fakeRouteStore struct {
+	mu         sync.Mutex
+	routes     []*database.NotificationRoute
+	deliveries []*database.NotificationDelivery
+}
+
+func (s *fakeRouteStore) CreateRoute(ctx context.Context, route *database.NotificationRoute) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.routes {
+		if existing.RuleID == route.RuleID && existing.Channel == route.Channel && existing.Recipient == route.Recipient {
+			return database.ErrNotificationRouteExists
+		}
+	}
+	s.routes = append(s.routes, route)
+	return nil
+}
+
+func (s *fakeRouteStore) GetRoute(ctx context.Context, id string) (*database.NotificationRoute, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, route := range s.routes {
+		if route.ID == id {
+			copied := *route
+			return &copied, nil
+		}
+	}
+	return nil, database.ErrNotificationRouteNotFound
+}
+
+func (s *fakeRouteStore) ListRoutes(ctx context.Context, ruleID string) ([]*database.NotificationRoute, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	routes := []*database.NotificationRoute{}
+	for _, route := range s.routes {
+		if route.RuleID == ruleID {
+			routes = append(routes, route)
+		}
+	}
+	return routes, nil
+}
+
+func (s *fakeRouteStore) UpdateRoute(ctx context.Context, route *database.NotificationRoute) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.routes {
+		if existing.ID == route.ID {
+			s.routes[i] = route
+			return nil
+		}
+	}
+	return database.ErrNotificationRouteNotFound
+}
+
+func (s *fakeRouteStore) DeleteRoute(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, route := range s.routes {
+		if route.ID == id {
+			s.routes = append(s.routes[:i], s.routes[i+1:]...)
+			return nil
+		}
+	}
+	return database.ErrNotificationRouteNotFound
+}
+
+func (s *fakeRouteStore) RecordDelivery(ctx context.Context, delivery *database.NotificationDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func (s *fakeRouteStore) ListDeliveries(ctx context.Context, filter database.NotificationDeliveryFilter) ([]*database.NotificationDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deliveries, nil
+}
+
+// fakeRuleLookup knows a fixed set of rules
+type fakeRuleLookup map[string]*database.Rule
+
+func (l fakeRuleLookup) GetByID(ctx context.Context, id string) (*database.Rule, error) {
+	if rule, ok := l[id]; ok {
+		return rule, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func newTestDispatcher(providers ...notification.Provider) (*notification.Dispatcher, *fakeRouteStore, *database.Rule) {
+	registry := notification.NewRegistry()
+	for _, provider := range providers {
+		registry.Register(provider)
+	}
+	rule := &database.Rule{ID: "rule_1", Name: "Large cash deposits", Severity: "medium"}
+	store := &fakeRouteStore{}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return notification.NewDispatcher(logger, registry, store, fakeRuleLookup{rule.ID: rule}, nil), store, rule
+}
+
+func TestNotificationDispatcherFollowsRoutes(t *testing.T) {
+	slack := &fakeChannelProvider{channel: notification.ChannelSlack}
+	pagerDuty := &fakeChannelProvider{channel: notification.ChannelPagerDuty}
+	dispatcher, store, rule := newTestDispatcher(slack, pagerDuty)
+	ctx := context.Background()
+
+	_, err := dispatcher.CreateRoute(ctx, rule.ID, notification.RouteInput{Channel: "slack", Recipient: "#fraud-ops"}, "user_1")
+	require.NoError(t, err)
+	_, err = dispatcher.CreateRoute(ctx, rule.ID, notification.RouteInput{Channel: "pagerduty", MinSeverity: "critical"}, "user_1")
+	require.NoError(t, err)
+	disabled := false
+	_, err = dispatcher.CreateRoute(ctx, rule.ID, notification.RouteInput{Channel: "slack", Recipient: "#archive", Enabled: &disabled}, "user_1")
+	require.NoError(t, err)
+
+	// A high alert reaches Slack but is below the PagerDuty route's minimum
+	alert := &database.Alert{ID: "alert_1", Severity: "high", Title: "Deposit"}
+	require.NoError(t, dispatcher.DispatchNotification(ctx, rule, alert, nil, "", "", "", "", "high"))
+	require.Len(t, slack.sent, 1)
+	assert.Equal(t, "#fraud-ops", slack.sent[0].Recipient)
+	assert.Equal(t, "Alert: Large cash deposits", slack.sent[0].Subject)
+	assert.Equal(t, "high", slack.sent[0].Severity)
+	assert.Empty(t, pagerDuty.sent)
+
+	// A critical alert pages as well
+	alert = &database.Alert{ID: "alert_2", Severity: "critical"}
+	require.NoError(t, dispatcher.DispatchNotification(ctx, rule, alert, nil, "", "", "", "", "critical"))
+	assert.Len(t, slack.sent, 2)
+	require.Len(t, pagerDuty.sent, 1)
+	assert.Equal(t, "alert_2", pagerDuty.sent[0].AlertID)
+
+	require.Len(t, store.deliveries, 3)
+	for _, delivery := range store.deliveries {
+		assert.Equal(t, database.DeliveryStatusSent, delivery.Status)
+		require.NotNil(t, delivery.RouteID)
+		require.NotNil(t, delivery.RuleID)
+		assert.Equal(t, rule.ID, *delivery.RuleID)
+	}
+
+	// An action naming a channel only uses that channel's routes
+	require.NoError(t, dispatcher.DispatchNotification(ctx, rule, alert, nil, "pagerduty", "", "", "", "critical"))
+	assert.Len(t, slack.sent, 2)
+	assert.Len(t, pagerDuty.sent, 2)
+}
+
+func TestNotificationDispatcherFallsBackToRuleChannels(t *testing.T) {
+	slack := &fakeChannelProvider{channel: notification.ChannelSlack}
+	sms := &fakeChannelProvider{channel: notification.ChannelSMS}
+	dispatcher, store, rule := newTestDispatcher(slack, sms)
+	rule.NotificationChannels = []string{"email", "slack", "sms"}
+
+	require.NoError(t, dispatcher.DispatchNotification(context.Background(), rule, nil, nil, "", "", "Subject", "Body", "medium"))
+
+	// email has no provider and SMS has no default recipient
+	require.Len(t, slack.sent, 1)
+	assert.Equal(t, "", slack.sent[0].Recipient)
+	assert.Equal(t, "Subject", slack.sent[0].Subject)
+	assert.Equal(t, "Body", slack.sent[0].Body)
+	assert.Empty(t, sms.sent)
+	require.Len(t, store.deliveries, 1)
+	assert.Nil(t, store.deliveries[0].RouteID)
+}
+
+func TestNotificationDispatcherRecordsFailures(t *testing.T) {
+	failing := &fakeChannelProvider{channel: notification.ChannelTeams, err: &notification.ProviderError{
+		Channel: notification.ChannelTeams, StatusCode: http.StatusBadGateway, Body: "upstream down",
+	}}
+	slack := &fakeChannelProvider{channel: notification.ChannelSlack}
+	dispatcher, store, rule := newTestDispatcher(failing, slack)
+	ctx := context.Background()
+
+	_, err := dispatcher.CreateRoute(ctx, rule.ID, notification.RouteInput{Channel: "teams"}, "user_1")
+	require.NoError(t, err)
+	_, err = dispatcher.CreateRoute(ctx, rule.ID, notification.RouteInput{Channel: "slack"}, "user_1")
+	require.NoError(t, err)
+
+	err = dispatcher.DispatchNotification(ctx, rule, &database.Alert{ID: "alert_1"}, nil, "", "", "", "", "medium")
+	var providerErr *notification.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, http.StatusBadGateway, providerErr.StatusCode)
+
+	// The Slack route was still delivered to
+	assert.Len(t, slack.sent, 1)
+	require.Len(t, store.deliveries, 2)
+	failed := store.deliveries[0]
+	assert.Equal(t, database.DeliveryStatusFailed, failed.Status)
+	require.NotNil(t, failed.ResponseCode)
+	assert.Equal(t, http.StatusBadGateway, *failed.ResponseCode)
+	require.NotNil(t, failed.Error)
+	assert.Contains(t, *failed.Error, "upstream down")
+	assert.Equal(t, database.DeliveryStatusSent, store.deliveries[1].Status)
+}
+
+func TestNotificationDispatcherExplicitRecipient(t *testing.T) {
+	sms := &fakeChannelProvider{channel: notification.ChannelSMS}
+	dispatcher, store, rule := newTestDispatcher(sms)
+
+	require.NoError(t, dispatcher.DispatchNotification(context.Background(), rule, nil, nil, "sms", "+15551234567", "", "", "high"))
+	require.Len(t, sms.sent, 1)
+	assert.Equal(t, "+15551234567", sms.sent[0].Recipient)
+	require.Len(t, store.deliveries, 1)
+	require.NotNil(t, store.deliveries[0].ExternalID)
+	assert.Equal(t, "ext_+15551234567", *store.deliveries[0].ExternalID)
+
+	err := dispatcher.DispatchNotification(context.Background(), rule, nil, nil, "email", "ops@example.com", "", "", "high")
+	assert.ErrorIs(t, err, notification.ErrNoProvider)
+}
+
+func TestNotificationRouteValidation(t *testing.T) {
+	dispatcher, _, rule := newTestDispatcher(
+		notification.NewSMSProvider(config.SMSConfig{}),
+		&fakeChannelProvider{channel: notification.ChannelSlack},
+	)
+	ctx := context.Background()
+
+	_, err := dispatcher.CreateRoute(ctx, "missing", notification.RouteInput{Channel: "slack"}, "user_1")
+	assert.ErrorIs(t, err, notification.ErrRuleNotFound)
+
+	_, err = dispatcher.CreateRoute(ctx, rule.ID, notification.RouteInput{Channel: "teams"}, "user_1")
+	assert.ErrorIs(t, err, notification.ErrInvalidRoute)
+
+	_, err = dispatcher.CreateRoute(ctx, rule.ID, notification.RouteInput{Channel: "sms", Recipient: "555-1234"}, "user_1")
+	assert.ErrorIs(t, err, notification.ErrInvalidRoute)
+
+	_, err = dispatcher.CreateRoute(ctx, rule.ID, notification.RouteInput{Channel: "slack", MinSeverity: "urgent"}, "user_1")
+	assert.ErrorIs(t, err, notification.ErrInvalidRoute)
+
+	route, err := dispatcher.CreateRoute(ctx, rule.ID, notification.RouteInput{Channel: "sms", Recipient: "+15551234567", MinSeverity: "high"}, "user_1")
+	require.NoError(t, err)
+	assert.True(t, route.Enabled)
+
+	_, err = dispatcher.CreateRoute(ctx, rule.ID, notification.RouteInput{Channel: "sms", Recipient: "+15551234567"}, "user_2")
+	assert.ErrorIs(t, err, database.ErrNotificationRouteExists)
+
+	_, err = dispatcher.UpdateRoute(ctx, route.ID, notification.RouteInput{Channel: "slack"}, "user_2")
+	assert.ErrorIs(t, err, notification.ErrInvalidRoute)
+
+	disabled := false
+	updated, err := dispatcher.UpdateRoute(ctx, route.ID, notification.RouteInput{Recipient: "+15557654321", Enabled: &disabled}, "user_2")
+	require.NoError(t, err)
+	assert.Equal(t, "+15557654321", updated.Recipient)
+	assert.Nil(t, updated.MinSeverity)
+	assert.False(t, updated.Enabled)
+	assert.Equal(t, "user_2", updated.UpdatedBy)
+
+	require.NoError(t, dispatcher.DeleteRoute(ctx, route.ID))
+	assert.True(t, errors.Is(dispatcher.DeleteRoute(ctx, route.ID), database.ErrNotificationRouteNotFound))
+}
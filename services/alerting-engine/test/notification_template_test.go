@@ -0,0 +1,199 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/notifytemplate"
+)
+
+func sampleTemplateData() map[string]interface{} {
+	rule := notifytemplate.SampleRule()
+	event := notifytemplate.SampleEvent()
+	timestamp := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	return notifytemplate.NewData(rule, notifytemplate.SampleAlert(rule, event), event, timestamp)
+}
+
+func TestNotificationTemplateRender(t *testing.T) {
+	renderer := notifytemplate.NewRenderer(0, 0)
+
+	rendered, err := renderer.Render(notifytemplate.ChannelEmail,
+		`{{ upper .alert.severity }}: {{ .alert.title | truncate 10 }}`,
+		`{{ .event.amount }} {{ .event.currency }} for {{ join ", " .alert.entity_ids }} at {{ date "15:04" .timestamp }}; owner {{ default "unassigned" .alert.assigned_to }}`,
+		sampleTemplateData())
+	require.NoError(t, err)
+	assert.Equal(t, "HIGH: Large cas…", rendered.Subject)
+	assert.Equal(t, "15000 USD for entity_sample_1, entity_sample_2 at 09:30; owner unassigned", rendered.Body)
+}
+
+func TestNotificationTemplateSubjectIsOneLine(t *testing.T) {
+	renderer := notifytemplate.NewRenderer(0, 0)
+	data := map[string]interface{}{"alert": map[string]interface{}{"title": "Bad\r\nBcc: someone@example.com"}}
+
+	rendered, err := renderer.Render(notifytemplate.ChannelEmail, `{{ .alert.title }}`, `body`, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Bad Bcc: someone@example.com", rendered.Subject)
+}
+
+func TestNotificationTemplateSandbox(t *testing.T) {
+	renderer := notifytemplate.NewRenderer(0, 0)
+
+	tests := map[string]string{
+		"define":          `{{ define "x" }}hi{{ end }}{{ template "x" }}`,
+		"template call":   `{{ template "body" . }}`,
+		"block":           `{{ block "x" . }}hi{{ end }}`,
+		"range over int":  `{{ range 1000000000 }}x{{ end }}`,
+		"range over call": `{{ range slice .alert.entity_ids 0 1 }}x{{ end }}`,
+		"unknown func":    `{{ exec "rm" }}`,
+		"syntax":          `{{ .alert.title `,
+	}
+	for name, body := range tests {
+		err := renderer.Validate(notifytemplate.ChannelWebhook, "", body)
+		assert.ErrorIs(t, err, notifytemplate.ErrInvalidTemplate, name)
+	}
+
+	assert.NoError(t, renderer.Validate(notifytemplate.ChannelWebhook, "",
+		`[{{ range $i, $id := .alert.entity_ids }}{{ if $i }},{{ end }}{{ json $id }}{{ end }}]`))
+}
+
+func TestNotificationTemplateValidation(t *testing.T) {
+	renderer := notifytemplate.NewRenderer(32, 0)
+
+	assert.ErrorIs(t, renderer.Validate("sms", "", "hi"), notifytemplate.ErrInvalidTemplate)
+	assert.ErrorIs(t, renderer.Validate(notifytemplate.ChannelEmail, "", "hi"), notifytemplate.ErrInvalidTemplate)
+	assert.ErrorIs(t, renderer.Validate(notifytemplate.ChannelSlack, "", " "), notifytemplate.ErrInvalidTemplate)
+	assert.ErrorIs(t, renderer.Validate(notifytemplate.ChannelSlack, "", strings.Repeat("x", 33)), notifytemplate.ErrInvalidTemplate)
+	assert.NoError(t, renderer.Validate(notifytemplate.ChannelSlack, "", `{"text": "hi"}`))
+}
+
+func TestNotificationTemplateJSONChannels(t *testing.T) {
+	renderer := notifytemplate.NewRenderer(0, 0)
+	data := sampleTemplateData()
+
+	_, err := renderer.Render(notifytemplate.ChannelSlack, "", `{"text": "{{ .alert.title }}`, data)
+	assert.ErrorIs(t, err, notifytemplate.ErrRenderFailed)
+
+	rendered, err := renderer.Render(notifytemplate.ChannelSlack, "", `{"text": {{ json .alert.description }}}`, data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"text": "Sample alert for previewing notification templates"}`, rendered.Body)
+}
+
+func TestNotificationTemplateOutputLimit(t *testing.T) {
+	renderer := notifytemplate.NewRenderer(0, 64)
+	data := map[string]interface{}{"items": make([]int, 100)}
+
+	_, err := renderer.Render(notifytemplate.ChannelEmail, "subject", `{{ range .items }}0123456789{{ end }}`, data)
+	assert.ErrorIs(t, err, notifytemplate.ErrRenderFailed)
+}
+
+func TestNotificationTemplateDefaults(t *testing.T) {
+	renderer := notifytemplate.NewRenderer(16384, 65536)
+	rule := notifytemplate.SampleRule()
+	event := notifytemplate.SampleEvent()
+
+	datasets := map[string]map[string]interface{}{
+		"alert":    sampleTemplateData(),
+		"no alert": notifytemplate.NewData(&database.Rule{ID: "r1", Name: "Velocity"}, nil, nil, time.Now()),
+	}
+	for _, channel := range []string{notifytemplate.ChannelEmail, notifytemplate.ChannelSlack, notifytemplate.ChannelWebhook} {
+		subject, body, ok := notifytemplate.Default(channel)
+		require.True(t, ok, channel)
+
+		for name, data := range datasets {
+			rendered, err := renderer.Render(channel, subject, body, data)
+			require.NoError(t, err, "%s with %s", channel, name)
+			assert.NotContains(t, rendered.Body, "<no value>", "%s with %s", channel, name)
+		}
+	}
+
+	subject, body, _ := notifytemplate.Default(notifytemplate.ChannelWebhook)
+	rendered, err := renderer.Render(notifytemplate.ChannelWebhook, subject, body,
+		notifytemplate.NewData(rule, notifytemplate.SampleAlert(rule, event), event, time.Now()))
+	require.NoError(t, err)
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(rendered.Body), &payload))
+	assert.Equal(t, "alert_sample", payload["alert"].(map[string]interface{})["id"])
+	assert.Equal(t, rule.Name, payload["rule"].(map[string]interface{})["name"])
+
+	_, _, ok := notifytemplate.Default("sms")
+	assert.False(t, ok)
+}
+
+func TestNotificationTemplateBrandedDefaults(t *testing.T) {
+	renderer := notifytemplate.NewRenderer(0, 0)
+	data := sampleTemplateData()
+	data["brand"] = notifytemplate.SampleBrand()
+
+	subject, body, _ := notifytemplate.Default(notifytemplate.ChannelEmail)
+	rendered, err := renderer.Render(notifytemplate.ChannelEmail, subject, body, data)
+	require.NoError(t, err)
+	assert.Contains(t, rendered.Body, "--\nExample Bank <support@example.com>\n")
+	assert.True(t, strings.HasSuffix(rendered.Body, "member of Example Bank's compliance team.\n"))
+
+	subject, body, _ = notifytemplate.Default(notifytemplate.ChannelSlack)
+	rendered, err = renderer.Render(notifytemplate.ChannelSlack, subject, body, data)
+	require.NoError(t, err)
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(rendered.Body), &payload))
+	assert.Equal(t, "Example Bank", payload["username"])
+	assert.Equal(t, "https://example.com/logo.png", payload["icon_url"])
+
+	data["brand"] = notifytemplate.Brand("", nil, "")
+	rendered, err = renderer.Render(notifytemplate.ChannelSlack, subject, body, data)
+	require.NoError(t, err)
+	payload = nil
+	require.NoError(t, json.Unmarshal([]byte(rendered.Body), &payload))
+	assert.NotContains(t, payload, "username")
+	assert.NotContains(t, payload, "icon_url")
+}
+
+func TestNotificationBrandTenant(t *testing.T) {
+	event := map[string]interface{}{"tenant_id": "from-event"}
+
+	assert.Equal(t, "from-rule", notifytemplate.TenantOf(
+		&database.Rule{Metadata: map[string]interface{}{"tenant_id": "from-rule"}}, event))
+	assert.Equal(t, "from-event", notifytemplate.TenantOf(&database.Rule{}, event))
+	assert.Equal(t, "", notifytemplate.TenantOf(&database.Rule{}, nil))
+
+	brand := notifytemplate.Brand("acme", nil, "AegisShield")
+	assert.Equal(t, "acme", brand["tenant_id"])
+	assert.Equal(t, "AegisShield", brand["name"])
+	assert.Equal(t, "", brand["footer"])
+}
+
+func TestNotificationBrandingValidation(t *testing.T) {
+	service := notifytemplate.NewService(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil)
+
+	tests := map[string]notifytemplate.BrandingInput{
+		"missing name":      {},
+		"long name":         {DisplayName: strings.Repeat("x", 101)},
+		"multi-line name":   {DisplayName: "Acme\nBcc: someone@example.com"},
+		"http logo":         {DisplayName: "Acme", LogoURL: "http://example.com/logo.png"},
+		"relative logo":     {DisplayName: "Acme", LogoURL: "/logo.png"},
+		"named color":       {DisplayName: "Acme", PrimaryColor: "red"},
+		"short color":       {DisplayName: "Acme", PrimaryColor: "#fff"},
+		"named email":       {DisplayName: "Acme", SupportEmail: "Support <support@example.com>"},
+		"invalid email":     {DisplayName: "Acme", SupportEmail: "support"},
+		"long footer":       {DisplayName: "Acme", Footer: strings.Repeat("x", 1001)},
+		"control in footer": {DisplayName: "Acme", Footer: "bell\a"},
+	}
+	for name, input := range tests {
+		_, err := service.SetBranding(context.Background(), "acme", input, "admin")
+		assert.ErrorIs(t, err, notifytemplate.ErrInvalidBranding, name)
+	}
+
+	_, err := service.SetBranding(context.Background(), " ", notifytemplate.BrandingInput{DisplayName: "Acme"}, "admin")
+	assert.ErrorIs(t, err, notifytemplate.ErrInvalidBranding)
+}
@@ -0,0 +1,196 @@
+package test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/handlers"
+	"github.com/aegisshield/shared/rbac"
+)
+
+var rbacTestSecret = []byte("rbac-test-secret")
+
+func signRBACToken(t *testing.T, claims jwt.MapClaims) string {
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(rbacTestSecret)
+	require.NoError(t, err)
+	return token
+}
+
+func TestRBAC_Unit(t *testing.T) {
+	evaluator := rbac.NewEvaluator(rbac.DefaultPolicy(), time.Minute, 100)
+
+	t.Run("Role Grants Permission", func(t *testing.T) {
+		subject := &rbac.Subject{ID: "u1", Roles: []string{rbac.RoleAnalyst}}
+		decision := evaluator.Check(subject, "alerts", rbac.ActionWrite)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, "role:analyst", decision.MatchedBy)
+
+		assert.False(t, evaluator.Allowed(subject, "rules", rbac.ActionWrite))
+	})
+
+	t.Run("Admin Wildcard", func(t *testing.T) {
+		subject := &rbac.Subject{Roles: []string{rbac.RoleAdmin}}
+		assert.True(t, evaluator.Allowed(subject, "anything", "delete"))
+	})
+
+	t.Run("Direct Token Permission", func(t *testing.T) {
+		subject := &rbac.Subject{Roles: []string{rbac.RoleViewOnly}, Permissions: []string{"rules:write"}}
+		decision := evaluator.Check(subject, "rules", rbac.ActionWrite)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, "permission:rules:write", decision.MatchedBy)
+	})
+
+	t.Run("Policy Change Clears Cache", func(t *testing.T) {
+		subject := &rbac.Subject{Roles: []string{"auditor"}}
+		assert.False(t, evaluator.Allowed(subject, "audit", rbac.ActionRead))
+
+		policy := rbac.NewPolicy()
+		require.NoError(t, policy.Grant("auditor", "audit:*"))
+		evaluator.SetPolicy(policy)
+		assert.True(t, evaluator.Allowed(subject, "audit", rbac.ActionRead))
+	})
+
+	t.Run("Invalid Permission Rejected", func(t *testing.T) {
+		_, err := rbac.ParsePermission("alerts")
+		assert.Error(t, err)
+		assert.Error(t, rbac.NewPolicy().Grant("analyst", "alerts:read:all"))
+	})
+
+	t.Run("Subject From Claims", func(t *testing.T) {
+		subject := rbac.SubjectFromClaims(map[string]interface{}{
+			"user_id":     float64(42),
+			"role":        "investigator",
+			"permissions": []interface{}{"reports:read"},
+		})
+		assert.Equal(t, "42", subject.ID)
+		assert.Equal(t, []string{"investigator"}, subject.Roles)
+		assert.Equal(t, []string{"reports:read"}, subject.Permissions)
+	})
+}
+
+type countingChecker struct {
+	calls int
+}
+
+func (c *countingChecker) CheckPermission(ctx context.Context, subject *rbac.Subject, resource, action string) (rbac.Decision, error) {
+	c.calls++
+	return rbac.Decision{Allowed: subject.HasRole(rbac.RoleAdmin)}, nil
+}
+
+func TestRBAC_CachedChecker(t *testing.T) {
+	remote := &countingChecker{}
+	checker := rbac.NewCachedChecker(remote, time.Minute, 100)
+	ctx := context.Background()
+
+	for _, id := range []string{"u1", "u2", "u3"} {
+		decision, err := checker.CheckPermission(ctx, &rbac.Subject{ID: id, Roles: []string{rbac.RoleAdmin}}, "alerts", rbac.ActionRead)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+	}
+	// Subjects with the same grants share one cached decision
+	assert.Equal(t, 1, remote.calls)
+
+	_, err := checker.CheckPermission(ctx, &rbac.Subject{Roles: []string{rbac.RoleAnalyst}}, "alerts", rbac.ActionRead)
+	require.NoError(t, err)
+	assert.Equal(t, 2, remote.calls)
+}
+
+func TestRBAC_AuthorizationMiddleware(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	evaluator := rbac.NewEvaluator(rbac.DefaultPolicy(), time.Minute, 100)
+
+	router := mux.NewRouter()
+	router.Use(handlers.AuthorizationMiddleware(logger, evaluator, rbacTestSecret))
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router.HandleFunc("/health", ok).Methods("GET")
+	router.HandleFunc("/alerts", ok).Methods("GET", "POST")
+	router.HandleFunc("/rules/{id}", ok).Methods("PUT")
+	router.HandleFunc("/watchlists/changes/{id}/approve", ok).Methods("POST")
+
+	analyst := signRBACToken(t, jwt.MapClaims{"user_id": float64(7), "role": "analyst"})
+	compliance := signRBACToken(t, jwt.MapClaims{"user_id": "c1", "roles": []string{"compliance"}})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{"Health Is Public", "GET", "/health", "", http.StatusOK},
+		{"Missing Token", "GET", "/alerts", "", http.StatusUnauthorized},
+		{"Invalid Token", "GET", "/alerts", "not-a-token", http.StatusUnauthorized},
+		{"Analyst Reads Alerts", "GET", "/alerts", analyst, http.StatusOK},
+		{"Analyst Creates Alerts", "POST", "/alerts", analyst, http.StatusOK},
+		{"Analyst Cannot Edit Rules", "PUT", "/rules/r1", analyst, http.StatusForbidden},
+		{"Analyst Cannot Approve Watchlist Change", "POST", "/watchlists/changes/c1/approve", analyst, http.StatusForbidden},
+		{"Compliance Approves Watchlist Change", "POST", "/watchlists/changes/c1/approve", compliance, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func TestRBAC_RoutePermission(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		resource string
+		action   string
+	}{
+		{"GET", "/alerts/a1", "alerts", rbac.ActionRead},
+		{"GET", "/alerts/a1/evidence", "evidence", rbac.ActionRead},
+		{"GET", "/alerts/a1/rule-version", "evidence", rbac.ActionRead},
+		{"POST", "/alerts/a1/transitions", "alerts", rbac.ActionWrite},
+		{"GET", "/alert-sla/overdue", "alerts", rbac.ActionRead},
+		{"GET", "/alert-cases/c1", "alerts", rbac.ActionRead},
+		{"POST", "/alert-cases/c1/link", "alerts", rbac.ActionWrite},
+		{"POST", "/alerts/a1/handoffs", "alerts", rbac.ActionWrite},
+		{"POST", "/alert-handoffs/h1/accept", "alerts", rbac.ActionWrite},
+		{"POST", "/notification-templates/preview", "notifications", rbac.ActionWrite},
+		{"POST", "/notification-templates/t1/rollback", "notifications", rbac.ActionWrite},
+		{"GET", "/notification-templates/t1/versions", "notifications", rbac.ActionRead},
+		{"PUT", "/notification-brandings/acme", "notifications", rbac.ActionWrite},
+		{"POST", "/rules/r1/notification-routes", "rules", rbac.ActionWrite},
+		{"PUT", "/notification-routes/nr1", "rules", rbac.ActionWrite},
+		{"DELETE", "/notification-routes/nr1", "rules", rbac.ActionDelete},
+		{"GET", "/notification-deliveries", "notifications", rbac.ActionRead},
+		{"GET", "/notification-channels", "notifications", rbac.ActionRead},
+		{"POST", "/rules/r1/rollback", "rules", rbac.ActionWrite},
+		{"PUT", "/rules/r1/suppression", "rules", rbac.ActionWrite},
+		{"DELETE", "/rules/r1/suppression", "rules", rbac.ActionDelete},
+		{"DELETE", "/rules/r1", "rules", rbac.ActionDelete},
+		{"POST", "/escalation-policies", "rules", rbac.ActionWrite},
+		{"POST", "/rule-backtests", "rules", rbac.ActionWrite},
+		{"GET", "/rule-backtests/bt1", "rules", rbac.ActionRead},
+		{"POST", "/case-sync/connectors", "cases", rbac.ActionWrite},
+		{"POST", "/risk-webhooks/subscribers/s1/replay", "risk_webhooks", rbac.ActionWrite},
+		{"POST", "/watchlists/changes/c1/reject", "watchlists", rbac.ActionApprove},
+	}
+
+	for _, tt := range tests {
+		resource, action := handlers.RoutePermission(httptest.NewRequest(tt.method, tt.path, nil))
+		assert.Equal(t, tt.resource, resource, tt.path)
+		assert.Equal(t, tt.action, action, tt.path)
+	}
+}
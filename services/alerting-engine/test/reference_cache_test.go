@@ -0,0 +1,262 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/engine"
+)
+
+// fakeReferenceStore serves reference data from maps and counts loads
+type fakeReferenceStore struct {
+	mu         sync.Mutex
+	tables     map[string][]string
+	thresholds map[string]float64
+	tiers      map[string]string
+	changes    []*database.ReferenceDataChange
+	loads      map[string]int
+	failTiers  bool
+	tierGate   chan struct{} // when set, risk tier loads wait for it
+}
+
+func newFakeReferenceStore() *fakeReferenceStore {
+	return &fakeReferenceStore{
+		tables:     map[string][]string{},
+		thresholds: map[string]float64{},
+		tiers:      map[string]string{},
+		loads:      map[string]int{},
+	}
+}
+
+func (f *fakeReferenceStore) GetLookupTable(ctx context.Context, name string) (*database.LookupTable, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.loads["lookup:"+name]++
+	entries, ok := f.tables[name]
+	if !ok {
+		return nil, nil
+	}
+	return &database.LookupTable{Name: name, Entries: entries}, nil
+}
+
+func (f *fakeReferenceStore) GetThreshold(ctx context.Context, name string) (*database.RuleThreshold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.loads["threshold:"+name]++
+	value, ok := f.thresholds[name]
+	if !ok {
+		return nil, nil
+	}
+	return &database.RuleThreshold{Name: name, Value: value}, nil
+}
+
+func (f *fakeReferenceStore) GetRiskTier(ctx context.Context, entityID string) (*database.EntityRiskTier, error) {
+	f.mu.Lock()
+	gate := f.tierGate
+	f.loads["risk_tier:"+entityID]++
+	tier, ok := f.tiers[entityID]
+	fail := f.failTiers
+	f.mu.Unlock()
+
+	if gate != nil {
+		<-gate
+	}
+	if fail {
+		return nil, errors.New("connection refused")
+	}
+	if !ok {
+		return nil, nil
+	}
+	return &database.EntityRiskTier{EntityID: entityID, Tier: tier}, nil
+}
+
+func (f *fakeReferenceStore) ListReferenceChanges(ctx context.Context, since time.Time) ([]*database.ReferenceDataChange, time.Time, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	changes := []*database.ReferenceDataChange{}
+	for _, change := range f.changes {
+		if !change.ChangedAt.Before(since) {
+			changes = append(changes, change)
+		}
+	}
+	return changes, time.Now(), nil
+}
+
+func (f *fakeReferenceStore) PruneReferenceChanges(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeReferenceStore) change(kind, key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.changes = append(f.changes, &database.ReferenceDataChange{
+		Version:   int64(len(f.changes) + 1),
+		Kind:      kind,
+		Key:       key,
+		ChangedAt: time.Now(),
+	})
+}
+
+func (f *fakeReferenceStore) loadCount(key string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.loads[key]
+}
+
+func referenceCache(store *fakeReferenceStore, options engine.ReferenceCacheOptions) *engine.ReferenceCache {
+	if options.ChangeOverlap == 0 {
+		options.ChangeOverlap = time.Minute
+	}
+	return engine.NewReferenceCache(store, setupTestLogger(), options)
+}
+
+func TestReferenceCacheLoadsOnceThenHits(t *testing.T) {
+	store := newFakeReferenceStore()
+	store.tables["sanctioned_countries"] = []string{"KP", "IR"}
+	store.thresholds["large_transfer"] = 10000
+	cache := referenceCache(store, engine.ReferenceCacheOptions{})
+
+	for i := 0; i < 5; i++ {
+		assert.True(t, cache.InLookup("sanctioned_countries", "KP"))
+		assert.False(t, cache.InLookup("sanctioned_countries", "FR"))
+		value, ok := cache.Threshold("large_transfer")
+		assert.True(t, ok)
+		assert.Equal(t, 10000.0, value)
+	}
+
+	assert.Equal(t, 1, store.loadCount("lookup:sanctioned_countries"))
+	assert.Equal(t, 1, store.loadCount("threshold:large_transfer"))
+
+	stats := cache.Stats().Kinds[database.ReferenceKindLookup]
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, int64(9), stats.Hits)
+	assert.Equal(t, 1, stats.Entries)
+	assert.InDelta(t, 0.9, stats.HitRatio, 0.001)
+}
+
+func TestReferenceCacheCachesMissingData(t *testing.T) {
+	store := newFakeReferenceStore()
+	cache := referenceCache(store, engine.ReferenceCacheOptions{})
+
+	for i := 0; i < 3; i++ {
+		assert.False(t, cache.InLookup("unknown_table", "x"))
+		_, ok := cache.RiskTier("entity_1")
+		assert.False(t, ok)
+	}
+
+	assert.Equal(t, 1, store.loadCount("lookup:unknown_table"))
+	assert.Equal(t, 1, store.loadCount("risk_tier:entity_1"))
+}
+
+func TestReferenceCacheSyncInvalidatesChangedEntries(t *testing.T) {
+	store := newFakeReferenceStore()
+	store.tiers["entity_1"] = database.RiskTierLow
+	store.tiers["entity_2"] = database.RiskTierMedium
+	cache := referenceCache(store, engine.ReferenceCacheOptions{})
+	ctx := context.Background()
+	require.NoError(t, cache.Sync(ctx))
+
+	tier, _ := cache.RiskTier("entity_1")
+	assert.Equal(t, database.RiskTierLow, tier)
+	cache.RiskTier("entity_2")
+
+	// Another replica raises entity_1's tier
+	store.mu.Lock()
+	store.tiers["entity_1"] = database.RiskTierHigh
+	store.mu.Unlock()
+	store.change(database.ReferenceKindRiskTier, "entity_1")
+
+	tier, _ = cache.RiskTier("entity_1")
+	assert.Equal(t, database.RiskTierLow, tier, "served from cache until the change is synced")
+
+	require.NoError(t, cache.Sync(ctx))
+	tier, _ = cache.RiskTier("entity_1")
+	assert.Equal(t, database.RiskTierHigh, tier)
+	assert.Equal(t, 2, store.loadCount("risk_tier:entity_1"))
+
+	// The change is re-read inside the overlap window but applied only once
+	require.NoError(t, cache.Sync(ctx))
+	cache.RiskTier("entity_1")
+	cache.RiskTier("entity_2")
+	assert.Equal(t, 2, store.loadCount("risk_tier:entity_1"))
+	assert.Equal(t, 1, store.loadCount("risk_tier:entity_2"))
+}
+
+func TestReferenceCacheDoesNotKeepLoadsRacingAnInvalidation(t *testing.T) {
+	store := newFakeReferenceStore()
+	store.tiers["entity_1"] = database.RiskTierLow
+	store.tierGate = make(chan struct{})
+	cache := referenceCache(store, engine.ReferenceCacheOptions{})
+
+	done := make(chan string)
+	go func() {
+		tier, _ := cache.RiskTier("entity_1")
+		done <- tier
+	}()
+
+	require.Eventually(t, func() bool { return store.loadCount("risk_tier:entity_1") == 1 }, time.Second, time.Millisecond)
+	cache.Invalidate(database.ReferenceKindRiskTier, "entity_1")
+	close(store.tierGate)
+	assert.Equal(t, database.RiskTierLow, <-done)
+
+	cache.RiskTier("entity_1")
+	assert.Equal(t, 2, store.loadCount("risk_tier:entity_1"), "the racing load was served but not cached")
+}
+
+func TestReferenceCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	store := newFakeReferenceStore()
+	cache := referenceCache(store, engine.ReferenceCacheOptions{MaxRiskTiers: 2})
+
+	cache.RiskTier("a")
+	cache.RiskTier("b")
+	cache.RiskTier("a")
+	cache.RiskTier("c") // evicts b
+
+	cache.RiskTier("a")
+	cache.RiskTier("b")
+	assert.Equal(t, 1, store.loadCount("risk_tier:a"))
+	assert.Equal(t, 2, store.loadCount("risk_tier:b"))
+
+	stats := cache.Stats().Kinds[database.ReferenceKindRiskTier]
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, int64(2), stats.Evictions)
+}
+
+func TestReferenceCacheDoesNotCacheLoadErrors(t *testing.T) {
+	store := newFakeReferenceStore()
+	store.tiers["entity_1"] = database.RiskTierCritical
+	store.failTiers = true
+	cache := referenceCache(store, engine.ReferenceCacheOptions{})
+
+	_, ok := cache.RiskTier("entity_1")
+	assert.False(t, ok)
+
+	store.mu.Lock()
+	store.failTiers = false
+	store.mu.Unlock()
+
+	tier, ok := cache.RiskTier("entity_1")
+	assert.True(t, ok)
+	assert.Equal(t, database.RiskTierCritical, tier)
+	assert.Equal(t, int64(1), cache.Stats().Kinds[database.ReferenceKindRiskTier].LoadErrors)
+}
+
+func TestReferenceCacheFlush(t *testing.T) {
+	store := newFakeReferenceStore()
+	store.tables["watch"] = []string{"x"}
+	cache := referenceCache(store, engine.ReferenceCacheOptions{})
+
+	cache.InLookup("watch", "x")
+	cache.Flush()
+	cache.InLookup("watch", "x")
+
+	assert.Equal(t, 2, store.loadCount("lookup:watch"))
+	assert.Equal(t, 1, cache.Stats().Kinds[database.ReferenceKindLookup].Entries)
+}
@@ -0,0 +1,99 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/riskwebhook"
+)
+
+func riskTier(tier string) *string {
+	return &tier
+}
+
+func TestRiskWebhookSignVerifies(t *testing.T) {
+	body := []byte(`{"event":"entity.risk_tier_changed","entity_id":"cust-42"}`)
+	sentAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	timestamp := "1709287200"
+
+	signature := riskwebhook.Sign("secret", sentAt, body)
+	assert.Contains(t, signature, "sha256=")
+
+	assert.True(t, riskwebhook.Verify("secret", timestamp, body, signature, 5*time.Minute, sentAt.Add(time.Minute)))
+	assert.False(t, riskwebhook.Verify("other", timestamp, body, signature, 5*time.Minute, sentAt))
+	assert.False(t, riskwebhook.Verify("secret", timestamp, []byte(`{"entity_id":"cust-43"}`), signature, 5*time.Minute, sentAt))
+	assert.False(t, riskwebhook.Verify("secret", "1709287201", body, signature, 5*time.Minute, sentAt))
+}
+
+func TestRiskWebhookVerifyRejectsStaleTimestamps(t *testing.T) {
+	body := []byte(`{}`)
+	sentAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	signature := riskwebhook.Sign("secret", sentAt, body)
+
+	assert.False(t, riskwebhook.Verify("secret", "1709287200", body, signature, 5*time.Minute, sentAt.Add(6*time.Minute)))
+	assert.False(t, riskwebhook.Verify("secret", "1709287200", body, signature, 5*time.Minute, sentAt.Add(-6*time.Minute)))
+	assert.False(t, riskwebhook.Verify("secret", "not-a-time", body, signature, 5*time.Minute, sentAt))
+	assert.False(t, riskwebhook.Verify("", "1709287200", body, signature, 5*time.Minute, sentAt))
+}
+
+func TestRiskWebhookDirection(t *testing.T) {
+	assert.Equal(t, riskwebhook.DirectionAssigned, riskwebhook.Direction(nil, riskTier("high")))
+	assert.Equal(t, riskwebhook.DirectionRemoved, riskwebhook.Direction(riskTier("high"), nil))
+	assert.Equal(t, riskwebhook.DirectionEscalated, riskwebhook.Direction(riskTier("medium"), riskTier("critical")))
+	assert.Equal(t, riskwebhook.DirectionDeescalated, riskwebhook.Direction(riskTier("high"), riskTier("low")))
+}
+
+func TestRiskWebhookBuildPayload(t *testing.T) {
+	score := 87.5
+	transition := &database.RiskTierTransition{
+		ID:           42,
+		EntityID:     "cust-42",
+		PreviousTier: riskTier("medium"),
+		Tier:         riskTier("high"),
+		Score:        &score,
+		OccurredAt:   time.Date(2024, 3, 1, 11, 0, 0, 0, time.FixedZone("CET", 3600)),
+	}
+	delivery := &database.RiskWebhookDelivery{ID: "rwd_1", TransitionID: 42, EntityID: "cust-42"}
+
+	payload := riskwebhook.BuildPayload(delivery, transition)
+	assert.Equal(t, riskwebhook.EventType, payload.Event)
+	assert.Equal(t, "rwd_1", payload.DeliveryID)
+	assert.Equal(t, int64(42), payload.Sequence)
+	assert.Equal(t, riskwebhook.DirectionEscalated, payload.Direction)
+	assert.Equal(t, time.UTC, payload.OccurredAt.Location())
+	assert.False(t, payload.Replay)
+
+	replayedAt := time.Now()
+	delivery.ReplayedAt = &replayedAt
+	assert.True(t, riskwebhook.BuildPayload(delivery, transition).Replay)
+}
+
+func TestRiskWebhookValidateSubscriber(t *testing.T) {
+	subscriber := &database.RiskWebhookSubscriber{
+		Name:        "core-banking",
+		EndpointURL: "https://bank.example.com/hooks/risk",
+		Tiers:       []string{"high", "critical"},
+		MaxAttempts: 8,
+	}
+	require.NoError(t, riskwebhook.ValidateSubscriber(subscriber))
+
+	invalid := *subscriber
+	invalid.EndpointURL = "ftp://bank.example.com"
+	assert.ErrorIs(t, riskwebhook.ValidateSubscriber(&invalid), riskwebhook.ErrInvalidSubscriber)
+
+	invalid = *subscriber
+	invalid.Tiers = []string{"severe"}
+	assert.ErrorIs(t, riskwebhook.ValidateSubscriber(&invalid), riskwebhook.ErrInvalidSubscriber)
+
+	invalid = *subscriber
+	invalid.MaxAttempts = 0
+	assert.ErrorIs(t, riskwebhook.ValidateSubscriber(&invalid), riskwebhook.ErrInvalidSubscriber)
+
+	invalid = *subscriber
+	invalid.Name = ""
+	assert.ErrorIs(t, riskwebhook.ValidateSubscriber(&invalid), riskwebhook.ErrInvalidSubscriber)
+}
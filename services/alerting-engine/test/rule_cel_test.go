@@ -0,0 +1,222 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/engine"
+)
+
+func celEngine(t *testing.T, limits config.CELConfig) *engine.RuleEngine {
+	cfg := &config.Config{}
+	cfg.Rules.CEL = limits
+	ruleEngine, err := engine.NewRuleEngine(cfg, setupTestLogger(), nil, nil)
+	require.NoError(t, err)
+	return ruleEngine
+}
+
+func TestCEL_CompileExpression(t *testing.T) {
+	ruleEngine := celEngine(t, config.CELConfig{MaxExpressionLength: 64})
+
+	t.Run("Boolean Expression Compiles", func(t *testing.T) {
+		assert.NoError(t, ruleEngine.CompileExpression(engine.LanguageCEL, "event.amount > 9000 && in_lookup('high_risk', event.country)"))
+		assert.NoError(t, ruleEngine.CompileExpression("CEL", "has(event.channel) && event.channel == 'wire'"))
+	})
+
+	t.Run("Syntax And Type Errors Rejected", func(t *testing.T) {
+		assert.Error(t, ruleEngine.CompileExpression(engine.LanguageCEL, "event.amount >"))
+		assert.Error(t, ruleEngine.CompileExpression(engine.LanguageCEL, "unknown_fn(event.amount)"))
+		assert.Error(t, ruleEngine.CompileExpression(engine.LanguageCEL, "threshold(5) > 1"))
+	})
+
+	t.Run("Non Boolean Output Rejected", func(t *testing.T) {
+		err := ruleEngine.CompileExpression(engine.LanguageCEL, "threshold('cash_limit') + 1.0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not bool")
+	})
+
+	t.Run("Expression Length Limited", func(t *testing.T) {
+		err := ruleEngine.CompileExpression(engine.LanguageCEL, "event.amount > 1"+strings.Repeat(" || event.amount > 1", 5))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "longer than the limit")
+	})
+
+	t.Run("Unknown Language Rejected", func(t *testing.T) {
+		err := ruleEngine.CompileExpression("lua", "event.amount > 1")
+		assert.True(t, errors.Is(err, engine.ErrUnsupportedLanguage))
+	})
+}
+
+func TestCEL_Matcher(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Conditions Evaluate Over Event Fields", func(t *testing.T) {
+		ruleEngine := celEngine(t, config.CELConfig{CostLimit: 10000})
+		matcher, err := ruleEngine.NewMatcher([]engine.MatcherRule{{
+			ID:          "large_wire",
+			Language:    engine.LanguageCEL,
+			Expressions: []string{"event.type == 'transfer' && event.amount > 9000", "event.tags.exists(t, t == 'wire')"},
+		}})
+		require.NoError(t, err)
+
+		match, err := matcher.Match(context.Background(), map[string]interface{}{
+			"id": "evt_1", "type": "transfer", "amount": 12000, "tags": []interface{}{"wire", "intl"},
+		}, at)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"large_wire"}, match.Matched)
+
+		match, err = matcher.Match(context.Background(), map[string]interface{}{
+			"id": "evt_2", "type": "transfer", "amount": 12000, "tags": []interface{}{"ach"},
+		}, at)
+		require.NoError(t, err)
+		assert.Empty(t, match.Matched)
+	})
+
+	t.Run("Window Filters And Aggregates", func(t *testing.T) {
+		ruleEngine := celEngine(t, config.CELConfig{})
+		windows, err := engine.ParseWindows(map[string]interface{}{
+			"language": "cel",
+			"windows": []interface{}{map[string]interface{}{
+				"name": "large", "function": "count", "size": "24h", "filter": "event.amount > 9000",
+			}},
+		})
+		require.NoError(t, err)
+
+		matcher, err := ruleEngine.NewMatcher([]engine.MatcherRule{{
+			ID:          "structuring",
+			Language:    engine.LanguageCEL,
+			Expressions: []string{"windows.large >= 2"},
+			Windows:     windows,
+		}})
+		require.NoError(t, err)
+
+		var matched []string
+		for i, amount := range []float64{9500, 100, 9800} {
+			event := transfer(string(rune('a'+i)), "entity_1", amount)
+			match, err := matcher.Match(context.Background(), event, at.Add(time.Duration(i)*time.Minute))
+			require.NoError(t, err)
+			matched = append(matched, match.Matched...)
+		}
+		assert.Equal(t, []string{"structuring"}, matched)
+	})
+
+	t.Run("Cost Limit Aborts Evaluation", func(t *testing.T) {
+		ruleEngine := celEngine(t, config.CELConfig{CostLimit: 50})
+		matcher, err := ruleEngine.NewMatcher([]engine.MatcherRule{{
+			ID:          "expensive",
+			Language:    engine.LanguageCEL,
+			Expressions: []string{"event.items.all(i, i > 0)"},
+		}})
+		require.NoError(t, err)
+
+		items := make([]interface{}, 1000)
+		for i := range items {
+			items[i] = i + 1
+		}
+		match, err := matcher.Match(context.Background(), map[string]interface{}{"id": "evt_1", "items": items}, at)
+		require.NoError(t, err)
+		assert.Empty(t, match.Matched)
+		require.Contains(t, match.Errors, "expensive")
+		assert.Contains(t, match.Errors["expensive"].Error(), "cost limit")
+	})
+}
+
+func TestCEL_RuleFixtures(t *testing.T) {
+	ruleEngine := celEngine(t, config.CELConfig{MaxFixtures: 3})
+
+	conditions := func(tests ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"language":   "cel",
+			"expression": "event.amount > threshold('cash_limit') && in_lookup('high_risk', event.country)",
+			"tests":      tests,
+		}
+	}
+	over := map[string]interface{}{
+		"name":       "over the limit from a high risk country",
+		"event":      map[string]interface{}{"amount": 12000, "country": "XX"},
+		"thresholds": map[string]interface{}{"cash_limit": 10000},
+		"lookups":    map[string]interface{}{"high_risk": []interface{}{"XX"}},
+		"expect":     true,
+	}
+	under := map[string]interface{}{
+		"name":       "under the limit",
+		"event":      map[string]interface{}{"amount": 500, "country": "XX"},
+		"thresholds": map[string]interface{}{"cash_limit": 10000},
+		"lookups":    map[string]interface{}{"high_risk": []interface{}{"XX"}},
+		"expect":     false,
+	}
+
+	t.Run("Passing Fixtures", func(t *testing.T) {
+		report, err := ruleEngine.TestRule(context.Background(), conditions(over, under))
+		require.NoError(t, err)
+		assert.True(t, report.Passed)
+		assert.Equal(t, 2, report.Total)
+		assert.Zero(t, report.Failed)
+		assert.NoError(t, ruleEngine.RunFixtures(conditions(over, under)))
+	})
+
+	t.Run("Fixtures Are Hermetic", func(t *testing.T) {
+		// Without the fixture's threshold the limit is unknown, so nothing exceeds it
+		noThreshold := map[string]interface{}{
+			"name":    "no threshold given",
+			"event":   map[string]interface{}{"amount": 12000, "country": "XX"},
+			"lookups": map[string]interface{}{"high_risk": []interface{}{"XX"}},
+			"expect":  true,
+		}
+		report, err := ruleEngine.TestRule(context.Background(), conditions(noThreshold))
+		require.NoError(t, err)
+		assert.False(t, report.Passed)
+		assert.False(t, report.Fixtures[0].Matched)
+	})
+
+	t.Run("Failing Fixture Named", func(t *testing.T) {
+		wrong := map[string]interface{}{
+			"name":       "expects a match it does not get",
+			"event":      map[string]interface{}{"amount": 500, "country": "XX"},
+			"thresholds": map[string]interface{}{"cash_limit": 10000},
+			"expect":     true,
+		}
+		err := ruleEngine.RunFixtures(conditions(over, wrong))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, engine.ErrFixtureFailed))
+		assert.Contains(t, err.Error(), "expects a match it does not get")
+	})
+
+	t.Run("Evaluation Error Fails Fixture", func(t *testing.T) {
+		missing := map[string]interface{}{
+			"name":    "no amount",
+			"event":   map[string]interface{}{"country": "XX"},
+			"lookups": map[string]interface{}{"high_risk": []interface{}{"XX"}},
+			"expect":  false,
+		}
+		report, err := ruleEngine.TestRule(context.Background(), conditions(missing))
+		require.NoError(t, err)
+		assert.False(t, report.Passed)
+		assert.NotEmpty(t, report.Fixtures[0].Error)
+	})
+
+	t.Run("Invalid Fixtures Rejected", func(t *testing.T) {
+		_, err := ruleEngine.TestRule(context.Background(), conditions(map[string]interface{}{"event": map[string]interface{}{}}))
+		assert.True(t, errors.Is(err, engine.ErrInvalidFixture))
+
+		_, err = ruleEngine.TestRule(context.Background(), conditions(over, over))
+		assert.True(t, errors.Is(err, engine.ErrInvalidFixture))
+
+		_, err = ruleEngine.TestRule(context.Background(), conditions(over, under, map[string]interface{}{"name": "c", "event": map[string]interface{}{}}, map[string]interface{}{"name": "d", "event": map[string]interface{}{}}))
+		assert.True(t, errors.Is(err, engine.ErrInvalidFixture))
+	})
+
+	t.Run("Rules Without Fixtures Pass", func(t *testing.T) {
+		report, err := ruleEngine.TestRule(context.Background(), map[string]interface{}{"language": "cel", "expression": "true"})
+		require.NoError(t, err)
+		assert.True(t, report.Passed)
+		assert.Zero(t, report.Total)
+	})
+}
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/rulefeedback"
+)
+
+// feedbackSamples is a rule whose false positives cluster at small amounts
+func feedbackSamples() []rulefeedback.Sample {
+	return []rulefeedback.Sample{
+		{Value: 1000, TruePositive: false},
+		{Value: 1500, TruePositive: false},
+		{Value: 2000, TruePositive: false},
+		{Value: 2500, TruePositive: true},
+		{Value: 3000, TruePositive: false},
+		{Value: 5000, TruePositive: true},
+		{Value: 8000, TruePositive: true},
+		{Value: 9000, TruePositive: true},
+	}
+}
+
+func TestRuleFeedbackPrecision(t *testing.T) {
+	assert.Equal(t, 0.75, rulefeedback.Precision(3, 1))
+	assert.Equal(t, 0.0, rulefeedback.Precision(0, 4))
+	assert.Equal(t, 0.0, rulefeedback.Precision(0, 0))
+}
+
+func TestRuleFeedbackProject(t *testing.T) {
+	projected := rulefeedback.Project(feedbackSamples(), 2500)
+
+	assert.Equal(t, 1000.0, projected.CurrentMinimum)
+	assert.Equal(t, 3, projected.AlertsSuppressed)
+	assert.Equal(t, 3, projected.FalsePositivesSuppressed)
+	assert.Equal(t, 0, projected.TruePositivesLost)
+	assert.InDelta(t, 0.8, projected.ProjectedPrecision, 1e-9)
+	assert.InDelta(t, 1.0, projected.ProjectedRecall, 1e-9)
+	assert.Equal(t, rulefeedback.SourceLocal, projected.Source)
+
+	projected = rulefeedback.Project(feedbackSamples(), 6000)
+	assert.Equal(t, 2, projected.TruePositivesLost)
+	assert.InDelta(t, 0.5, projected.ProjectedRecall, 1e-9)
+	assert.InDelta(t, 1.0, projected.ProjectedPrecision, 1e-9)
+}
+
+func TestRuleFeedbackSuggestThreshold(t *testing.T) {
+	// every true positive must be kept, so the threshold stops at the smallest one
+	suggestion := rulefeedback.SuggestThreshold(feedbackSamples(), 1.0)
+	require.NotNil(t, suggestion)
+	assert.Equal(t, 2500.0, suggestion.Threshold)
+	assert.Equal(t, 3, suggestion.FalsePositivesSuppressed)
+	assert.Equal(t, 0, suggestion.TruePositivesLost)
+
+	// giving up one true positive in four clears the last false positive
+	suggestion = rulefeedback.SuggestThreshold(feedbackSamples(), 0.75)
+	require.NotNil(t, suggestion)
+	assert.Equal(t, 5000.0, suggestion.Threshold)
+	assert.Equal(t, 1, suggestion.TruePositivesLost)
+	assert.InDelta(t, 1.0, suggestion.ProjectedPrecision, 1e-9)
+
+	// nothing to raise when the smallest alert was a true positive
+	suggestion = rulefeedback.SuggestThreshold([]rulefeedback.Sample{
+		{Value: 100, TruePositive: true},
+		{Value: 200, TruePositive: false},
+		{Value: 300, TruePositive: true},
+	}, 1.0)
+	require.NotNil(t, suggestion)
+	assert.Equal(t, 100.0, suggestion.Threshold)
+	assert.Equal(t, 0, suggestion.AlertsSuppressed)
+
+	assert.Nil(t, rulefeedback.SuggestThreshold([]rulefeedback.Sample{{Value: 100}, {Value: 200}}, 0.9))
+	assert.Nil(t, rulefeedback.SuggestThreshold(nil, 0.9))
+}
+
+func TestRuleFeedbackRecommend(t *testing.T) {
+	raise := &rulefeedback.Suggestion{FalsePositivesSuppressed: 3}
+	keep := &rulefeedback.Suggestion{}
+
+	assert.Equal(t, rulefeedback.RecommendationInsufficientData, rulefeedback.Recommend(5, 20, 0.1, 0.2, raise))
+	assert.Equal(t, rulefeedback.RecommendationRaiseThreshold, rulefeedback.Recommend(50, 20, 0.1, 0.2, raise))
+	assert.Equal(t, rulefeedback.RecommendationReviewRule, rulefeedback.Recommend(50, 20, 0.1, 0.2, keep))
+	assert.Equal(t, rulefeedback.RecommendationReviewRule, rulefeedback.Recommend(50, 20, 0, 0.2, nil))
+	assert.Equal(t, rulefeedback.RecommendationKeep, rulefeedback.Recommend(50, 20, 0.6, 0.2, keep))
+}
+
+func TestRuleFeedbackNormalizeDisposition(t *testing.T) {
+	tests := map[string]string{
+		"true_positive":  database.AlertDispositionTruePositive,
+		"False Positive": database.AlertDispositionFalsePositive,
+		"not suspicious": database.AlertDispositionFalsePositive,
+		"SAR filed":      database.AlertDispositionTruePositive,
+	}
+	for input, expected := range tests {
+		disposition, err := rulefeedback.NormalizeDisposition(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, disposition, input)
+	}
+
+	for _, input := range []string{"", "  ", "duplicate", "escalated", "benign"} {
+		_, err := rulefeedback.NormalizeDisposition(input)
+		assert.ErrorIs(t, err, rulefeedback.ErrInvalidDisposition, input)
+	}
+}
+
+func TestRuleFeedbackRecordClosureIgnoresOtherStatuses(t *testing.T) {
+	service := rulefeedback.NewService(&config.Config{}, setupTestLogger(), nil, nil)
+
+	// only closing as confirmed or false positive says anything about the rule
+	for _, status := range []string{database.AlertStatusResolved, database.AlertStatusSuppressed, database.AlertStatusEscalated} {
+		err := service.RecordClosure(context.Background(), &database.Alert{ID: "alert-1", RuleID: "rule-1", Status: status}, "alice", "done")
+		assert.NoError(t, err, status)
+	}
+}
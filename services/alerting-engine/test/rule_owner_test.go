@@ -0,0 +1,301 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/notification"
+	"github.com/aegis-shield/services/alerting-engine/internal/ruleowner"
+)
+
+var ruleOwnerPolicy = ruleowner.Policy{
+	ErrorBudget:              0.01,
+	MinEvaluations:           100,
+	MinDispositions:          10,
+	MaxFalsePositiveRate:     0.8,
+	MinFalsePositiveIncrease: 0.1,
+}
+
+func TestRuleOwnerErrorBudget(t *testing.T) {
+	ratio, exceeded := ruleowner.ErrorBudgetExceeded(1000, 20, ruleOwnerPolicy)
+	assert.True(t, exceeded)
+	assert.InDelta(t, 0.02, ratio, 1e-9)
+
+	_, exceeded = ruleowner.ErrorBudgetExceeded(1000, 10, ruleOwnerPolicy)
+	assert.False(t, exceeded, "exactly on budget is within it")
+
+	_, exceeded = ruleowner.ErrorBudgetExceeded(50, 50, ruleOwnerPolicy)
+	assert.False(t, exceeded, "too few evaluations are not judged")
+
+	_, exceeded = ruleowner.ErrorBudgetExceeded(0, 0, ruleowner.Policy{})
+	assert.False(t, exceeded, "rules that were not evaluated are not judged")
+}
+
+func TestRuleOwnerFalsePositiveTrend(t *testing.T) {
+	// rising from 50% to 90%
+	trend, rising := ruleowner.FalsePositiveTrend(&database.RuleDispositionTrend{
+		RecentTotal: 20, RecentFalsePositives: 18, PriorTotal: 20, PriorFalsePositives: 10,
+	}, ruleOwnerPolicy)
+	assert.True(t, rising)
+	assert.InDelta(t, 0.9, trend.RecentRate, 1e-9)
+	require.NotNil(t, trend.PriorRate)
+	assert.InDelta(t, 0.5, *trend.PriorRate, 1e-9)
+
+	// high but steady
+	_, rising = ruleowner.FalsePositiveTrend(&database.RuleDispositionTrend{
+		RecentTotal: 20, RecentFalsePositives: 18, PriorTotal: 20, PriorFalsePositives: 17,
+	}, ruleOwnerPolicy)
+	assert.False(t, rising)
+
+	// no baseline to compare against: the recent rate alone decides
+	trend, rising = ruleowner.FalsePositiveTrend(&database.RuleDispositionTrend{
+		RecentTotal: 10, RecentFalsePositives: 9, PriorTotal: 3, PriorFalsePositives: 0,
+	}, ruleOwnerPolicy)
+	assert.True(t, rising)
+	assert.Nil(t, trend.PriorRate)
+
+	// below the rate limit, or too few dispositions
+	_, rising = ruleowner.FalsePositiveTrend(&database.RuleDispositionTrend{
+		RecentTotal: 20, RecentFalsePositives: 10,
+	}, ruleOwnerPolicy)
+	assert.False(t, rising)
+	_, rising = ruleowner.FalsePositiveTrend(&database.RuleDispositionTrend{
+		RecentTotal: 5, RecentFalsePositives: 5,
+	}, ruleOwnerPolicy)
+	assert.False(t, rising)
+}
+
+func TestRuleOwnerGroupAndRender(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	actor := "alice"
+	events := []*database.RuleLifecycleEvent{
+		{ID: "e2", RuleID: "rule-1", RuleName: "Structuring", EventType: database.RuleEventErrorBudgetExceeded, OccurredAt: at.Add(time.Hour),
+			Details: database.JSONB{"error_ratio": 0.025, "evaluations": float64(400), "error_budget": 0.01}},
+		{ID: "e1", RuleID: "rule-1", RuleName: "Structuring", EventType: database.RuleEventDisabled, Actor: &actor, OccurredAt: at},
+		{ID: "e3", RuleID: "rule-2", RuleName: "Velocity", EventType: database.RuleEventFalsePositiveTrend, OccurredAt: at,
+			Details: database.JSONB{"recent_rate": 0.9, "recent_dispositions": 20, "prior_rate": 0.5}},
+		{ID: "e4", RuleID: "rule-3", RuleName: "Unowned", EventType: database.RuleEventEnabled, OccurredAt: at},
+	}
+	owners := []*database.RuleOwner{
+		{RuleID: "rule-1", OwnerID: "bob", Channel: "slack"},
+		{RuleID: "rule-1", OwnerID: "alice", Channel: "teams"},
+		{RuleID: "rule-2", OwnerID: "bob", Channel: "slack"},
+	}
+
+	recipients, groups := ruleowner.GroupByOwner(events, owners)
+	require.Len(t, recipients, 2)
+	assert.Equal(t, "alice", recipients[0].OwnerID)
+	assert.Len(t, groups[recipients[0]], 2)
+	assert.Len(t, groups[recipients[1]], 3, "bob owns both rules")
+
+	subject, body := ruleowner.Render(groups[recipients[1]])
+	assert.Equal(t, "Rule digest: 3 events on 2 rules (2 needing attention)", subject)
+	assert.Contains(t, body, "Structuring (rule-1):")
+	assert.Contains(t, body, "disabled by alice")
+	assert.Contains(t, body, "error budget exceeded: 2.5% of 400 evaluations failed (budget 1.0%)")
+	assert.Contains(t, body, "false positives trending up: 90.0% of 20 recent dispositions, up from 50.0%")
+	assert.Less(t, strings.Index(body, "disabled by alice"), strings.Index(body, "error budget exceeded"),
+		"events are listed in the order they happened")
+	assert.NotContains(t, body, "Unowned")
+}
+
+// ruleOwnerStore keeps rule owners, events and digests in memory
+type ruleOwnerStore struct {
+	owners   []*database.RuleOwner
+	events   []*database.RuleLifecycleEvent
+	digests  []*database.RuleOwnerDigest
+	trends   []*database.RuleDispositionTrend
+	digested []string
+}
+
+func (s *ruleOwnerStore) ReplaceOwners(ctx context.Context, ruleID string, owners []*database.RuleOwner) error {
+	s.owners = owners
+	return nil
+}
+
+func (s *ruleOwnerStore) ListOwners(ctx context.Context, ruleIDs []string) ([]*database.RuleOwner, error) {
+	return s.owners, nil
+}
+
+func (s *ruleOwnerStore) RecordEvent(ctx context.Context, event *database.RuleLifecycleEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *ruleOwnerStore) LastEventAt(ctx context.Context, ruleID, eventType string) (*time.Time, error) {
+	var last *time.Time
+	for _, event := range s.events {
+		if event.RuleID == ruleID && event.EventType == eventType {
+			at := event.OccurredAt
+			last = &at
+		}
+	}
+	return last, nil
+}
+
+func (s *ruleOwnerStore) ListEvents(ctx context.Context, filter database.RuleLifecycleEventFilter) ([]*database.RuleLifecycleEvent, error) {
+	return s.events, nil
+}
+
+func (s *ruleOwnerStore) ListPendingEvents(ctx context.Context, limit int) ([]*database.RuleLifecycleEvent, error) {
+	var pending []*database.RuleLifecycleEvent
+	for _, event := range s.events {
+		if event.DigestedAt == nil {
+			pending = append(pending, event)
+		}
+	}
+	return pending, nil
+}
+
+func (s *ruleOwnerStore) MarkEventsDigested(ctx context.Context, ids []string, at time.Time) error {
+	s.digested = append(s.digested, ids...)
+	for _, event := range s.events {
+		event.DigestedAt = &at
+	}
+	return nil
+}
+
+func (s *ruleOwnerStore) SaveDigest(ctx context.Context, digest *database.RuleOwnerDigest) error {
+	s.digests = append(s.digests, digest)
+	return nil
+}
+
+func (s *ruleOwnerStore) ListDigests(ctx context.Context, ownerID string, limit int) ([]*database.RuleOwnerDigest, error) {
+	return s.digests, nil
+}
+
+func (s *ruleOwnerStore) DispositionTrends(ctx context.Context, split, since time.Time) ([]*database.RuleDispositionTrend, error) {
+	return s.trends, nil
+}
+
+type ruleOwnerRules struct{}
+
+func (ruleOwnerRules) GetByID(ctx context.Context, id string) (*database.Rule, error) {
+	return &database.Rule{ID: id}, nil
+}
+
+// ruleOwnerNotifier records sent messages and fails for the given channel
+type ruleOwnerNotifier struct {
+	sent    []*notification.Message
+	failing string
+}
+
+func (n *ruleOwnerNotifier) Channels() []string {
+	return []string{"slack", "teams"}
+}
+
+func (n *ruleOwnerNotifier) Send(ctx context.Context, channel string, message *notification.Message, routeID *string) error {
+	if channel == n.failing {
+		return errors.New("provider unavailable")
+	}
+	n.sent = append(n.sent, message)
+	return nil
+}
+
+func newRuleOwnerService(store *ruleOwnerStore, notifier *ruleOwnerNotifier) *ruleowner.Service {
+	cfg := &config.Config{RuleOwners: config.RuleOwnersConfig{
+		ErrorBudget:              ruleOwnerPolicy.ErrorBudget,
+		MinEvaluations:           ruleOwnerPolicy.MinEvaluations,
+		TrendWindow:              7 * 24 * time.Hour,
+		MinDispositions:          ruleOwnerPolicy.MinDispositions,
+		MaxFalsePositiveRate:     ruleOwnerPolicy.MaxFalsePositiveRate,
+		MinFalsePositiveIncrease: ruleOwnerPolicy.MinFalsePositiveIncrease,
+		EventCooldown:            24 * time.Hour,
+		MaxEventsPerDigest:       1000,
+	}}
+	return ruleowner.NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), store, ruleOwnerRules{}, notifier)
+}
+
+func TestRuleOwnerService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Owners Are Validated", func(t *testing.T) {
+		service := newRuleOwnerService(&ruleOwnerStore{}, &ruleOwnerNotifier{})
+
+		owners, err := service.SetOwners(ctx, "rule-1", []ruleowner.OwnerInput{{OwnerID: " bob ", Channel: "slack"}}, "alice")
+		require.NoError(t, err)
+		require.Len(t, owners, 1)
+		assert.Equal(t, "bob", owners[0].OwnerID)
+		assert.Equal(t, "alice", owners[0].CreatedBy)
+
+		for name, inputs := range map[string][]ruleowner.OwnerInput{
+			"no owner":        {{Channel: "slack"}},
+			"unknown channel": {{OwnerID: "bob", Channel: "email"}},
+			"duplicate":       {{OwnerID: "bob", Channel: "slack"}, {OwnerID: "bob", Channel: "teams"}},
+		} {
+			_, err := service.SetOwners(ctx, "rule-1", inputs, "alice")
+			assert.ErrorIs(t, err, ruleowner.ErrInvalidOwner, name)
+		}
+	})
+
+	t.Run("Check Judges Evaluations Since The Last Check", func(t *testing.T) {
+		store := &ruleOwnerStore{trends: []*database.RuleDispositionTrend{
+			{RuleID: "rule-2", RuleName: "Velocity", RecentTotal: 20, RecentFalsePositives: 19},
+		}}
+		service := newRuleOwnerService(store, &ruleOwnerNotifier{})
+
+		result, err := service.Check(ctx, map[string]ruleowner.EvaluationCount{
+			"rule-1": {RuleName: "Structuring", Evaluations: 1000, Errors: 5},
+		})
+		require.NoError(t, err)
+		assert.Zero(t, result.ErrorBudgetExceeded)
+		assert.Equal(t, 1, result.FalsePositiveTrends)
+
+		// 100 new evaluations, 5 of them failed: over budget only since the last check
+		result, err = service.Check(ctx, map[string]ruleowner.EvaluationCount{
+			"rule-1": {RuleName: "Structuring", Evaluations: 1100, Errors: 10},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.ErrorBudgetExceeded)
+		assert.Zero(t, result.FalsePositiveTrends, "the trend was raised within the cooldown")
+
+		require.Len(t, store.events, 2)
+		assert.Equal(t, database.RuleEventErrorBudgetExceeded, store.events[1].EventType)
+		assert.Equal(t, int64(100), store.events[1].Details["evaluations"])
+	})
+
+	t.Run("Digests Go To Each Owner", func(t *testing.T) {
+		at := time.Now()
+		store := &ruleOwnerStore{
+			owners: []*database.RuleOwner{
+				{RuleID: "rule-1", OwnerID: "bob", Channel: "slack", Recipient: "#rules"},
+				{RuleID: "rule-1", OwnerID: "carol", Channel: "teams"},
+			},
+			events: []*database.RuleLifecycleEvent{
+				{ID: "e1", RuleID: "rule-1", RuleName: "Structuring", EventType: database.RuleEventEnabled, OccurredAt: at},
+				{ID: "e2", RuleID: "rule-9", RuleName: "Unowned", EventType: database.RuleEventDisabled, OccurredAt: at},
+			},
+		}
+		notifier := &ruleOwnerNotifier{failing: "teams"}
+		service := newRuleOwnerService(store, notifier)
+
+		result, err := service.SendDigests(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Events)
+		assert.Equal(t, 2, result.Digests)
+		assert.Equal(t, 1, result.Failed)
+
+		require.Len(t, notifier.sent, 1)
+		assert.Equal(t, "#rules", notifier.sent[0].Recipient)
+		assert.Equal(t, "Rule digest: 1 event on 1 rule", notifier.sent[0].Subject)
+
+		require.Len(t, store.digests, 2)
+		assert.Equal(t, database.RuleOwnerDigestSent, store.digests[0].Status)
+		assert.Equal(t, database.RuleOwnerDigestFailed, store.digests[1].Status)
+		assert.ElementsMatch(t, []string{"e1", "e2"}, store.digested, "unowned events are settled too")
+
+		result, err = service.SendDigests(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, result.Digests)
+	})
+}
@@ -0,0 +1,263 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/rulepack"
+)
+
+// fakeCompiler rejects expressions containing "!!" in place of the engine's compiler
+type fakeCompiler struct {
+	version string
+}
+
+func (c fakeCompiler) DSLVersion() string { return c.version }
+
+func (c fakeCompiler) CompileExpression(language, expression string) error {
+	if strings.Contains(expression, "!!") {
+		return errors.New("unexpected token")
+	}
+	return nil
+}
+
+func (c fakeCompiler) RunFixtures(conditions map[string]interface{}) error {
+	return nil
+}
+
+func rulePack() *rulepack.Pack {
+	return &rulepack.Pack{
+		FormatVersion: rulepack.FormatVersion,
+		Name:          "structuring-pack",
+		Version:       "2.1.0",
+		DSLVersion:    "1.1",
+		Rules: []rulepack.PackRule{
+			{
+				ID:       "rule_1",
+				Name:     "Cash structuring",
+				Type:     "threshold",
+				Severity: "high",
+				Conditions: map[string]interface{}{
+					"expression": `event.amount > threshold("ctr_limit") && in_lookup("high_risk_countries", event.country)`,
+				},
+			},
+			{
+				ID:       "rule_2",
+				Name:     "Rapid movement",
+				Type:     "pattern",
+				Severity: "medium",
+				Conditions: map[string]interface{}{
+					"all": []interface{}{
+						map[string]interface{}{"expression": `event.hops >= 3`},
+						map[string]interface{}{"expression": `event.window_minutes < threshold("rapid_window")`},
+					},
+				},
+			},
+		},
+		LookupTables: []rulepack.PackLookupTable{
+			{Name: "high_risk_countries", Entries: []string{"IR", "KP"}},
+		},
+		Thresholds: []rulepack.PackThreshold{
+			{Name: "ctr_limit", Value: 10000},
+		},
+	}
+}
+
+func TestRulePackSignatureSurvivesRoundTrip(t *testing.T) {
+	keys := map[string]string{"prod-2024": "pack-signing-secret"}
+	pack := rulePack()
+	require.NoError(t, rulepack.Sign(pack, "prod-2024", keys["prod-2024"]))
+	assert.True(t, strings.HasPrefix(pack.Signature.Value, "sha256="))
+
+	body, err := json.Marshal(pack)
+	require.NoError(t, err)
+
+	var received rulepack.Pack
+	require.NoError(t, json.Unmarshal(body, &received))
+	assert.NoError(t, rulepack.Verify(&received, keys))
+
+	t.Run("Tampered Pack Rejected", func(t *testing.T) {
+		tampered := received
+		tampered.Thresholds = []rulepack.PackThreshold{{Name: "ctr_limit", Value: 1}}
+		assert.ErrorIs(t, rulepack.Verify(&tampered, keys), rulepack.ErrInvalidSignature)
+	})
+
+	t.Run("Unknown Key Rejected", func(t *testing.T) {
+		assert.ErrorIs(t, rulepack.Verify(&received, map[string]string{"other": "secret"}), rulepack.ErrUnknownSigningKey)
+	})
+
+	t.Run("Unsigned Pack Rejected", func(t *testing.T) {
+		assert.ErrorIs(t, rulepack.Verify(rulePack(), keys), rulepack.ErrUnsigned)
+	})
+}
+
+func TestRulePackYAML(t *testing.T) {
+	keys := map[string]string{"prod-2024": "pack-signing-secret"}
+	pack := rulePack()
+	pack.ExportedAt = time.Date(2024, 5, 1, 9, 30, 0, 123456789, time.UTC)
+	pack.Metadata = map[string]interface{}{"owner": "fiu", "review_ratio": 0.25}
+	require.NoError(t, rulepack.Sign(pack, "prod-2024", keys["prod-2024"]))
+
+	body, err := rulepack.EncodeYAML(pack)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "name: structuring-pack")
+
+	again, err := rulepack.EncodeYAML(pack)
+	require.NoError(t, err)
+	assert.Equal(t, string(body), string(again), "encoding is stable for version control")
+
+	t.Run("Signature Survives YAML Round Trip", func(t *testing.T) {
+		received, err := rulepack.DecodeYAML(body)
+		require.NoError(t, err)
+		assert.NoError(t, rulepack.Verify(received, keys))
+		assert.Equal(t, pack.Rules[1].Conditions, received.Rules[1].Conditions)
+		assert.True(t, pack.ExportedAt.Equal(received.ExportedAt))
+	})
+
+	t.Run("Hand Written Bundle", func(t *testing.T) {
+		received, err := rulepack.DecodeYAML([]byte(`
+format_version: "1"
+name: local-rules
+version: 0.1.0
+dsl_version: "1.4"
+rules:
+  - id: r1
+    name: Large cash
+    type: threshold
+    severity: high
+    conditions:
+      language: cel
+      expression: event.amount > 10000
+`))
+		require.NoError(t, err)
+		require.Len(t, received.Rules, 1)
+		assert.Equal(t, "cel", rulepack.ConditionLanguage(received.Rules[0].Conditions))
+		assert.Equal(t, []string{"event.amount > 10000"}, rulepack.ConditionExpressions(received.Rules[0].Conditions))
+	})
+
+	t.Run("Invalid Bundle Rejected", func(t *testing.T) {
+		_, err := rulepack.DecodeYAML([]byte("rules: [unterminated"))
+		assert.ErrorIs(t, err, rulepack.ErrInvalidYAML)
+
+		_, err = rulepack.DecodeYAML([]byte("- just\n- a list"))
+		assert.ErrorIs(t, err, rulepack.ErrInvalidYAML)
+
+		_, err = rulepack.DecodeYAML([]byte("rules: not-a-list"))
+		assert.ErrorIs(t, err, rulepack.ErrInvalidYAML)
+	})
+}
+
+func TestRulePackDSLCompatibility(t *testing.T) {
+	tests := []struct {
+		pack       string
+		engine     string
+		compatible bool
+	}{
+		{"1.1", "1.1", true},
+		{"1.0", "1.1", true},
+		{"1", "1.1", true},
+		{"1.2", "1.1", false},
+		{"2.0", "1.1", false},
+		{"v1", "1.1", false},
+	}
+
+	for _, tt := range tests {
+		err := rulepack.CheckDSLCompatibility(tt.pack, tt.engine)
+		assert.Equal(t, tt.compatible, err == nil, "pack %s on engine %s", tt.pack, tt.engine)
+	}
+}
+
+func TestRulePackValidate(t *testing.T) {
+	compiler := fakeCompiler{version: "1.1"}
+	known := rulepack.NewReferenceIndex(nil, []*database.RuleThreshold{{Name: "rapid_window", Value: 30}})
+
+	t.Run("References Resolved From Pack And Deployment", func(t *testing.T) {
+		report := rulepack.Validate(rulePack(), compiler, known, 100)
+		assert.True(t, report.Compatible, "%+v", report.Errors)
+		assert.Empty(t, report.Errors)
+	})
+
+	t.Run("Unknown Reference Rejected", func(t *testing.T) {
+		report := rulepack.Validate(rulePack(), compiler, nil, 100)
+		assert.False(t, report.Compatible)
+		require.Len(t, report.Errors, 1)
+		assert.Equal(t, "rule:Rapid movement", report.Errors[0].Item)
+		assert.Contains(t, report.Errors[0].Message, `"rapid_window"`)
+	})
+
+	t.Run("Uncompilable Condition Rejected", func(t *testing.T) {
+		pack := rulePack()
+		pack.Rules[0].Conditions = map[string]interface{}{"expression": "event.amount !! 5"}
+		report := rulepack.Validate(pack, compiler, known, 100)
+		assert.False(t, report.Compatible)
+		assert.Contains(t, report.Errors[0].Message, "does not compile")
+	})
+
+	t.Run("Newer DSL Rejected", func(t *testing.T) {
+		pack := rulePack()
+		pack.DSLVersion = "1.4"
+		report := rulepack.Validate(pack, compiler, known, 100)
+		assert.False(t, report.Compatible)
+		assert.Equal(t, "pack", report.Errors[0].Item)
+	})
+
+	t.Run("Duplicate Rule And Rule Limit", func(t *testing.T) {
+		pack := rulePack()
+		pack.Rules = append(pack.Rules, pack.Rules[0])
+		report := rulepack.Validate(pack, compiler, known, 2)
+		assert.Len(t, report.Errors, 2)
+	})
+}
+
+func TestRulePackExpressions(t *testing.T) {
+	pack := rulePack()
+
+	expressions := rulepack.ConditionExpressions(pack.Rules[1].Conditions)
+	assert.Equal(t, []string{`event.hops >= 3`, `event.window_minutes < threshold("rapid_window")`}, expressions)
+
+	lookups, thresholds := rulepack.References(rulepack.ConditionExpressions(pack.Rules[0].Conditions))
+	assert.Equal(t, []string{"high_risk_countries"}, lookups)
+	assert.Equal(t, []string{"ctr_limit"}, thresholds)
+
+	renamed := rulepack.RenameReference(
+		`in_lookup("high_risk_countries", event.country) || threshold("high_risk_countries") > 1`,
+		"in_lookup", "high_risk_countries", "high_risk_countries__structuring_pack")
+	assert.Equal(t,
+		`in_lookup("high_risk_countries__structuring_pack", event.country) || threshold("high_risk_countries") > 1`,
+		renamed)
+}
+
+func TestRulePackRenaming(t *testing.T) {
+	assert.Equal(t, "Cash structuring (structuring-pack)", rulepack.RenamedName("Cash structuring", "structuring-pack", 1))
+	assert.Equal(t, "Cash structuring (structuring-pack 3)", rulepack.RenamedName("Cash structuring", "structuring-pack", 3))
+	assert.Equal(t, "ctr_limit__acme_aml_pack", rulepack.RenamedReference("ctr_limit", "Acme AML \"pack\"", 1))
+	assert.Equal(t, "ctr_limit__acme_2", rulepack.RenamedReference("ctr_limit", "acme", 2))
+}
+
+func TestRulePackReferenceIndex(t *testing.T) {
+	index := rulepack.NewReferenceIndex(
+		[]*database.LookupTable{{Name: "high_risk_countries", Entries: []string{"IR", "KP"}}},
+		[]*database.RuleThreshold{{Name: "ctr_limit", Value: 10000}},
+	)
+
+	assert.True(t, index.InLookup("high_risk_countries", "KP"))
+	assert.False(t, index.InLookup("high_risk_countries", "FR"))
+	assert.False(t, index.InLookup("unknown", "KP"))
+	assert.True(t, index.HasLookup("high_risk_countries"))
+
+	value, ok := index.Threshold("ctr_limit")
+	assert.True(t, ok)
+	assert.Equal(t, 10000.0, value)
+	assert.False(t, index.HasThreshold("unknown"))
+
+	var empty *rulepack.ReferenceIndex
+	assert.False(t, empty.InLookup("high_risk_countries", "KP"))
+	assert.False(t, empty.HasThreshold("ctr_limit"))
+}
@@ -0,0 +1,164 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/ruleversion"
+)
+
+func versionedRule(version int, expression string) *database.Rule {
+	window := 5 * time.Minute
+	return &database.Rule{
+		ID:             "rule-1",
+		Name:           "Rapid Structuring",
+		Type:           "threshold",
+		Severity:       "high",
+		Priority:       "p2",
+		Enabled:        true,
+		Conditions:     map[string]interface{}{"expression": expression, "window": map[string]interface{}{"minutes": 30.0}},
+		Actions:        map[string]interface{}{"notify": true},
+		Tags:           []string{"aml"},
+		Metadata:       map[string]interface{}{"owner": "compliance"},
+		ThrottleWindow: &window,
+		GroupBy:        []string{"account_id"},
+		Version:        version,
+	}
+}
+
+func recordedVersion(t *testing.T, rule *database.Rule, changeType string) *database.RuleVersion {
+	definition, checksum, err := database.NewRuleDefinition(rule).Encode()
+	require.NoError(t, err)
+	return &database.RuleVersion{
+		RuleID:     rule.ID,
+		Version:    rule.Version,
+		Definition: definition,
+		Checksum:   checksum,
+		ChangeType: changeType,
+	}
+}
+
+func TestRuleVersion_Definition(t *testing.T) {
+	t.Run("Checksum Stable Across Round Trip", func(t *testing.T) {
+		rule := versionedRule(1, "amount > 9000")
+		version := recordedVersion(t, rule, database.RuleChangeCreated)
+
+		definition, err := version.Decode()
+		require.NoError(t, err)
+
+		_, checksum, err := definition.Encode()
+		require.NoError(t, err)
+		assert.Equal(t, version.Checksum, checksum)
+	})
+
+	t.Run("Enabled State Not Versioned", func(t *testing.T) {
+		enabled := versionedRule(1, "amount > 9000")
+		disabled := versionedRule(1, "amount > 9000")
+		disabled.Enabled = false
+		disabled.UpdatedBy = "someone-else"
+
+		assert.Equal(t, recordedVersion(t, enabled, database.RuleChangeCreated).Checksum,
+			recordedVersion(t, disabled, database.RuleChangeCreated).Checksum)
+	})
+
+	t.Run("Apply Keeps Identity", func(t *testing.T) {
+		old := recordedVersion(t, versionedRule(1, "amount > 9000"), database.RuleChangeCreated)
+		definition, err := old.Decode()
+		require.NoError(t, err)
+
+		rule := versionedRule(3, "amount > 5000")
+		rule.Enabled = false
+		definition.Apply(rule)
+
+		assert.Equal(t, "amount > 9000", rule.Conditions["expression"])
+		assert.Equal(t, 3, rule.Version)
+		assert.False(t, rule.Enabled)
+	})
+}
+
+func TestRuleVersion_Diff(t *testing.T) {
+	from := versionedRule(1, "amount > 9000")
+	to := versionedRule(2, "amount > 5000")
+	to.Severity = "critical"
+	to.Metadata = map[string]interface{}{"owner": "compliance", "ticket": "AML-12"}
+	to.Tags = []string{"aml", "structuring"}
+
+	diff, err := ruleversion.Diff(recordedVersion(t, from, database.RuleChangeCreated),
+		recordedVersion(t, to, database.RuleChangeUpdated))
+	require.NoError(t, err)
+
+	assert.False(t, diff.Identical)
+	assert.Equal(t, 1, diff.FromVersion)
+	assert.Equal(t, 2, diff.ToVersion)
+
+	paths := make([]string, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		paths = append(paths, change.Path)
+	}
+	assert.Equal(t, []string{"conditions.expression", "metadata.ticket", "severity", "tags"}, paths)
+
+	assert.Equal(t, ruleversion.ChangeChanged, diff.Changes[0].Kind)
+	assert.Equal(t, "amount > 9000", diff.Changes[0].Before)
+	assert.Equal(t, "amount > 5000", diff.Changes[0].After)
+	assert.Equal(t, ruleversion.ChangeAdded, diff.Changes[1].Kind)
+
+	t.Run("Identical Definitions", func(t *testing.T) {
+		diff, err := ruleversion.Diff(recordedVersion(t, from, database.RuleChangeCreated),
+			recordedVersion(t, versionedRule(3, "amount > 9000"), database.RuleChangeRolledBack))
+		require.NoError(t, err)
+		assert.True(t, diff.Identical)
+		assert.Empty(t, diff.Changes)
+	})
+}
+
+func TestRuleVersion_RollbackRule(t *testing.T) {
+	compiler := fakeCompiler{version: "1.1"}
+	current := versionedRule(3, "amount > 5000")
+
+	t.Run("Restores Prior Definition", func(t *testing.T) {
+		target := recordedVersion(t, versionedRule(1, "amount > 9000"), database.RuleChangeCreated)
+
+		rule, err := ruleversion.RollbackRule(current, target, compiler)
+		require.NoError(t, err)
+		assert.Equal(t, "amount > 9000", rule.Conditions["expression"])
+		assert.Equal(t, current.ID, rule.ID)
+		assert.Equal(t, current.Version, rule.Version)
+		assert.Equal(t, "amount > 5000", current.Conditions["expression"], "live rule left untouched")
+	})
+
+	t.Run("Target Must Be Prior", func(t *testing.T) {
+		target := recordedVersion(t, versionedRule(3, "amount > 9000"), database.RuleChangeUpdated)
+
+		_, err := ruleversion.RollbackRule(current, target, compiler)
+		assert.ErrorIs(t, err, ruleversion.ErrNotPriorVersion)
+	})
+
+	t.Run("Already Live", func(t *testing.T) {
+		target := recordedVersion(t, versionedRule(2, "amount > 5000"), database.RuleChangeUpdated)
+
+		_, err := ruleversion.RollbackRule(current, target, compiler)
+		assert.ErrorIs(t, err, ruleversion.ErrAlreadyLive)
+	})
+
+	t.Run("Restored Conditions Must Compile", func(t *testing.T) {
+		target := recordedVersion(t, versionedRule(1, "amount >> !!"), database.RuleChangeCreated)
+
+		_, err := ruleversion.RollbackRule(current, target, compiler)
+		assert.ErrorIs(t, err, ruleversion.ErrInvalidDefinition)
+	})
+}
+
+func TestRuleVersion_SnapshotChecksum(t *testing.T) {
+	rule := versionedRule(2, "amount > 9000")
+	snapshot, err := json.Marshal(rule)
+	require.NoError(t, err)
+
+	checksum, err := ruleversion.SnapshotChecksum(snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, recordedVersion(t, rule, database.RuleChangeUpdated).Checksum, checksum)
+}
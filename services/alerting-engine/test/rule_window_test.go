@@ -0,0 +1,206 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/engine"
+)
+
+// fakeWindowCheckpoints keeps checkpoints in memory
+type fakeWindowCheckpoints struct {
+	saved map[string]*database.RuleWindowCheckpoint
+}
+
+func (f *fakeWindowCheckpoints) SaveWindowCheckpoint(ctx context.Context, checkpoint *database.RuleWindowCheckpoint) error {
+	f.saved[checkpoint.Instance] = checkpoint
+	return nil
+}
+
+func (f *fakeWindowCheckpoints) GetWindowCheckpoint(ctx context.Context, instance string) (*database.RuleWindowCheckpoint, error) {
+	return f.saved[instance], nil
+}
+
+func windowMatcher(t *testing.T, windows []engine.WindowSpec, expressions ...string) *engine.Matcher {
+	cfg := &config.Config{}
+	ruleEngine, err := engine.NewRuleEngine(cfg, setupTestLogger(), nil, nil)
+	require.NoError(t, err)
+
+	matcher, err := ruleEngine.NewMatcher([]engine.MatcherRule{{ID: "structuring", Expressions: expressions, Windows: windows}})
+	require.NoError(t, err)
+	return matcher
+}
+
+func transfer(id, entityID string, amount float64) map[string]interface{} {
+	return map[string]interface{}{"id": id, "type": "transfer", "entity_id": entityID, "amount": amount}
+}
+
+func TestParseWindows(t *testing.T) {
+	var conditions map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"windows": [{"name": "large_transfers", "function": "count", "size": "24h",
+		             "filter": "event.amount > 9000"}],
+		"expression": "window('large_transfers') > 5"
+	}`), &conditions))
+
+	windows, err := engine.ParseWindows(conditions)
+	require.NoError(t, err)
+	require.Len(t, windows, 1)
+	assert.Equal(t, 24*time.Hour, windows[0].Size)
+	assert.Equal(t, engine.WindowSliding, windows[0].Type)
+	assert.Equal(t, "entity_id", windows[0].GroupBy)
+
+	none, err := engine.ParseWindows(map[string]interface{}{"expression": "true"})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	invalid := map[string][]interface{}{
+		"no name":         {map[string]interface{}{"function": "count", "size": "1h"}},
+		"bad function":    {map[string]interface{}{"name": "w", "function": "avg", "size": "1h"}},
+		"sum needs field": {map[string]interface{}{"name": "w", "function": "sum", "size": "1h"}},
+		"bad size":        {map[string]interface{}{"name": "w", "function": "count", "size": "a day"}},
+		"no size":         {map[string]interface{}{"name": "w", "function": "count"}},
+		"bad type":        {map[string]interface{}{"name": "w", "function": "count", "size": "1h", "type": "hopping"}},
+		"bad filter":      {map[string]interface{}{"name": "w", "function": "count", "size": "1h", "filter": "event.amount >"}},
+		"duplicate": {
+			map[string]interface{}{"name": "w", "function": "count", "size": "1h"},
+			map[string]interface{}{"name": "w", "function": "count", "size": "2h"},
+		},
+	}
+	for name, windows := range invalid {
+		_, err := engine.ParseWindows(map[string]interface{}{"windows": windows})
+		assert.ErrorIs(t, err, engine.ErrInvalidWindow, name)
+	}
+}
+
+func TestSlidingWindowCountsFilteredEventsPerEntity(t *testing.T) {
+	matcher := windowMatcher(t, []engine.WindowSpec{{
+		Name: "large_transfers", Function: engine.WindowCount, GroupBy: "entity_id",
+		Size: 24 * time.Hour, Type: engine.WindowSliding,
+		Filter: "event.type == 'transfer' && event.amount > 9000",
+	}}, "window('large_transfers') > 5")
+
+	ctx := context.Background()
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// five large transfers and a small one do not match; the sixth large one does
+	for i, amount := range []float64{9500, 9900, 100, 9100, 9800, 9200} {
+		match, err := matcher.Match(ctx, transfer(string(rune('a'+i)), "acct_1", amount), start.Add(time.Duration(i)*time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, match.Matched, "event %d", i)
+	}
+	match, err := matcher.Match(ctx, transfer("g", "acct_1", 9600), start.Add(6*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"structuring"}, match.Matched)
+
+	// a redelivered event is not counted twice, and other entities have their own window
+	match, err = matcher.Match(ctx, transfer("g", "acct_1", 9600), start.Add(6*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"structuring"}, match.Matched)
+	match, err = matcher.Match(ctx, transfer("h", "acct_2", 9600), start.Add(6*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, match.Matched)
+
+	// the first transfers slide out of the window a day later
+	match, err = matcher.Match(ctx, transfer("i", "acct_1", 9700), start.Add(25*time.Hour+time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, match.Matched)
+}
+
+func TestTumblingWindowSumResetsEachPeriod(t *testing.T) {
+	matcher := windowMatcher(t, []engine.WindowSpec{{
+		Name: "daily_total", Function: engine.WindowSum, Field: "amount", GroupBy: "entity_id",
+		Size: 24 * time.Hour, Type: engine.WindowTumbling,
+	}}, "window('daily_total') >= 20000")
+
+	ctx := context.Background()
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	match, err := matcher.Match(ctx, transfer("a", "acct_1", 12000), day.Add(22*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, match.Matched)
+
+	// the next day starts a new window, even within 24 hours
+	match, err = matcher.Match(ctx, transfer("b", "acct_1", 12000), day.Add(26*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, match.Matched)
+
+	match, err = matcher.Match(ctx, transfer("c", "acct_1", 8000), day.Add(30*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"structuring"}, match.Matched)
+}
+
+func TestDistinctCountWindow(t *testing.T) {
+	matcher := windowMatcher(t, []engine.WindowSpec{{
+		Name: "counterparties", Function: engine.WindowDistinctCount, Field: "counterparty.id",
+		GroupBy: "entity_id", Size: time.Hour, Type: engine.WindowSliding,
+	}}, "window('counterparties') >= 3")
+
+	ctx := context.Background()
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	event := func(id, counterparty string) map[string]interface{} {
+		e := transfer(id, "acct_1", 100)
+		e["counterparty"] = map[string]interface{}{"id": counterparty}
+		return e
+	}
+
+	for i, counterparty := range []string{"cp_1", "cp_2", "cp_1", "cp_2"} {
+		match, err := matcher.Match(ctx, event(string(rune('a'+i)), counterparty), at.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, err)
+		assert.Empty(t, match.Matched)
+	}
+	match, err := matcher.Match(ctx, event("e", "cp_3"), at.Add(5*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"structuring"}, match.Matched)
+}
+
+func TestWindowWithoutGroupReadsNaN(t *testing.T) {
+	matcher := windowMatcher(t, []engine.WindowSpec{{
+		Name: "transfers", Function: engine.WindowCount, GroupBy: "entity_id",
+		Size: time.Hour, Type: engine.WindowSliding,
+	}}, "window('transfers') < 1 || window('transfers') >= 1")
+
+	match, err := matcher.Match(context.Background(), map[string]interface{}{"id": "a", "type": "transfer"}, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, match.Matched)
+}
+
+func TestMemoryWindowStoreCheckpointRestore(t *testing.T) {
+	ctx := context.Background()
+	checkpoints := &fakeWindowCheckpoints{saved: map[string]*database.RuleWindowCheckpoint{}}
+	now := time.Now()
+
+	observe := func(store *engine.MemoryWindowStore, key, entry string, at time.Time, record bool) float64 {
+		value, err := store.Aggregate(ctx, engine.WindowObservation{
+			Key: key, Function: engine.WindowCount, Entry: entry, Value: 1,
+			At: at, From: at.Add(-time.Hour), Until: at, Expires: at.Add(time.Hour), Record: record,
+		})
+		require.NoError(t, err)
+		return value
+	}
+
+	store := engine.NewMemoryWindowStore(checkpoints, setupTestLogger(), engine.MemoryWindowStoreOptions{Instance: "replica_a", MaxEntries: 3})
+	for i, entry := range []string{"a", "b", "c", "d"} {
+		observe(store, "rule:w:acct_1", entry, now.Add(time.Duration(i-10)*time.Minute), true)
+	}
+	observe(store, "rule:w:acct_2", "e", now.Add(-2*time.Hour), true)
+	assert.Equal(t, float64(3), observe(store, "rule:w:acct_1", "", now, false), "oldest entries beyond the cap are dropped")
+	require.NoError(t, store.Checkpoint(ctx))
+	require.Contains(t, checkpoints.saved, "replica_a")
+	assert.Equal(t, 2, checkpoints.saved["replica_a"].Windows)
+
+	restored := engine.NewMemoryWindowStore(checkpoints, setupTestLogger(), engine.MemoryWindowStoreOptions{Instance: "replica_a"})
+	require.NoError(t, restored.Restore(ctx))
+	assert.Equal(t, 1, restored.Len(), "expired windows are not restored")
+	assert.Equal(t, float64(3), observe(restored, "rule:w:acct_1", "", now, false))
+
+	assert.Equal(t, 1, restored.Expire(now.Add(2*time.Hour)))
+	assert.Zero(t, restored.Len())
+}
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/severitytune"
+)
+
+func severityPolicy() severitytune.Policy {
+	return severitytune.Policy{
+		MinDispositions: 10,
+		MediumLoss:      10000,
+		HighLoss:        100000,
+		CriticalLoss:    1000000,
+		RaisePrecision:  0.5,
+		LowerPrecision:  0.05,
+		MaxStep:         1,
+	}
+}
+
+func TestSeverityTuneCollectEvidence(t *testing.T) {
+	samples := []*database.DispositionSample{
+		{Disposition: database.AlertDispositionTruePositive, SourceEvent: database.JSONB{"amount": 20000.0}},
+		{Disposition: database.AlertDispositionTruePositive, SourceEvent: database.JSONB{"amount": "40000"}},
+		{Disposition: database.AlertDispositionTruePositive, SourceEvent: database.JSONB{}},
+		{Disposition: database.AlertDispositionFalsePositive, SourceEvent: database.JSONB{"amount": 5.0}},
+	}
+
+	evidence := severitytune.CollectEvidence(samples, "amount")
+	assert.Equal(t, 4, evidence.Dispositions)
+	assert.Equal(t, 3, evidence.TruePositives)
+	assert.Equal(t, 1, evidence.FalsePositives)
+	assert.Equal(t, 0.75, evidence.TruePositiveRate)
+	assert.Equal(t, 2, evidence.LossSamples)
+	assert.Equal(t, 30000.0, evidence.AverageLoss)
+}
+
+func TestSeverityTuneProposeRaisesOneStep(t *testing.T) {
+	rule := &database.Rule{ID: "rule-1", Name: "Large transfers", Severity: "low"}
+	evidence := severitytune.Evidence{
+		Dispositions:     40,
+		TruePositives:    30,
+		FalsePositives:   10,
+		TruePositiveRate: 0.75,
+		LossSamples:      30,
+		AverageLoss:      500000,
+	}
+
+	change := severitytune.Propose(rule, evidence, nil, severityPolicy())
+	assert.Equal(t, "critical", change.TargetSeverity)
+	assert.Equal(t, "medium", change.ProposedSeverity)
+	assert.Equal(t, severitytune.ReasonRaise, change.Reason)
+	assert.True(t, change.Changed())
+}
+
+func TestSeverityTuneProposeLowersNoisyRule(t *testing.T) {
+	rule := &database.Rule{ID: "rule-2", Severity: "high"}
+	evidence := severitytune.Evidence{Dispositions: 50, TruePositives: 1, FalsePositives: 49, TruePositiveRate: 0.02}
+
+	change := severitytune.Propose(rule, evidence, nil, severityPolicy())
+	assert.Equal(t, "medium", change.ProposedSeverity)
+	assert.Equal(t, severitytune.ReasonLower, change.Reason)
+}
+
+func TestSeverityTuneProposeRespectsGuardrail(t *testing.T) {
+	rule := &database.Rule{ID: "rule-3", Severity: "high"}
+	evidence := severitytune.Evidence{Dispositions: 50, TruePositives: 1, FalsePositives: 49, TruePositiveRate: 0.02}
+	guardrail := &severitytune.Guardrail{MinSeverity: "high", MaxSeverity: "critical"}
+
+	change := severitytune.Propose(rule, evidence, guardrail, severityPolicy())
+	assert.Equal(t, "high", change.ProposedSeverity)
+	assert.True(t, change.GuardrailApplied)
+	assert.False(t, change.Changed())
+	assert.Equal(t, severitytune.ReasonKeep, change.Reason)
+}
+
+func TestSeverityTuneProposeNeedsEnoughDispositions(t *testing.T) {
+	rule := &database.Rule{ID: "rule-4", Severity: "medium"}
+	evidence := severitytune.Evidence{Dispositions: 3, TruePositives: 3, TruePositiveRate: 1}
+
+	change := severitytune.Propose(rule, evidence, nil, severityPolicy())
+	assert.Equal(t, severitytune.ReasonInsufficientData, change.Reason)
+	assert.False(t, change.Changed())
+}
+
+func TestSeverityTuneSimulate(t *testing.T) {
+	counts := []*database.RuleSeverityCount{
+		{RuleID: "rule-1", Severity: "low", Count: 12},
+		{RuleID: "rule-1", Severity: "critical", Count: 2},
+		{RuleID: "rule-2", Severity: "high", Count: 5},
+	}
+	changes := []*severitytune.Change{
+		{RuleID: "rule-1", CurrentSeverity: "low", ProposedSeverity: "medium"},
+		{RuleID: "rule-2", CurrentSeverity: "high", ProposedSeverity: "high"},
+	}
+
+	simulation := severitytune.Simulate(counts, changes)
+	require.NotNil(t, simulation)
+	assert.Equal(t, map[string]int{"low": 12, "critical": 2, "high": 5}, simulation.Before)
+	assert.Equal(t, map[string]int{"medium": 12, "critical": 2, "high": 5}, simulation.After)
+	assert.Equal(t, 12, simulation.AlertsChanged)
+	assert.Equal(t, 12, changes[0].AlertsAffected)
+	assert.Equal(t, 0, changes[1].AlertsAffected)
+
+	severitytune.SortChanges(changes)
+	assert.Equal(t, "rule-1", changes[0].RuleID)
+}
@@ -0,0 +1,121 @@
+package test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/config"
+	"github.com/aegis-shield/services/alerting-engine/internal/slo"
+)
+
+// staticQuerier answers PromQL queries by matching the range selector
+type staticQuerier struct {
+	ratios map[string]float64
+}
+
+func (q *staticQuerier) Query(ctx context.Context, query string) (float64, bool, error) {
+	for rng, ratio := range q.ratios {
+		if strings.Contains(query, "["+rng+"]") {
+			return ratio, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+type recordingNotifier struct {
+	statuses []*slo.Status
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, status *slo.Status) error {
+	n.statuses = append(n.statuses, status)
+	return nil
+}
+
+func TestSLO_Unit(t *testing.T) {
+	availability := config.SLOObjective{
+		Name:           "gateway-availability",
+		Service:        "api-gateway",
+		Type:           slo.TypeAvailability,
+		Target:         0.999,
+		Window:         30 * 24 * time.Hour,
+		RequestsMetric: "http_requests_total",
+		Selector:       `job="aegisshield-api-gateway"`,
+	}
+
+	t.Run("Builds Availability Query", func(t *testing.T) {
+		query := slo.ErrorRatioQuery(availability, time.Hour)
+		assert.Equal(t,
+			`sum(rate(http_requests_total{job="aegisshield-api-gateway",status=~"5.."}[1h])) / sum(rate(http_requests_total{job="aegisshield-api-gateway"}[1h]))`,
+			query)
+	})
+
+	t.Run("Builds Latency Query", func(t *testing.T) {
+		latency := availability
+		latency.Type = slo.TypeLatency
+		latency.DurationMetric = "http_request_duration_seconds"
+		latency.LatencyThreshold = "0.5"
+
+		query := slo.ErrorRatioQuery(latency, 30*24*time.Hour)
+		assert.Contains(t, query, `http_request_duration_seconds_bucket{job="aegisshield-api-gateway",le="0.5"}[30d]`)
+		assert.Contains(t, query, `http_request_duration_seconds_count{job="aegisshield-api-gateway"}[30d]`)
+	})
+
+	t.Run("Burn Rate And Budget", func(t *testing.T) {
+		assert.InDelta(t, 1.0, slo.BurnRate(0.001, 0.999), 1e-9)
+		assert.InDelta(t, 14.4, slo.BurnRate(0.0144, 0.999), 1e-9)
+		assert.InDelta(t, 0.5, slo.BudgetRemaining(0.0005, 0.999), 1e-9)
+		assert.Less(t, slo.BudgetRemaining(0.002, 0.999), 0.0)
+	})
+
+	t.Run("Classifies Budget State", func(t *testing.T) {
+		fast := map[string]float64{"5m": 20, "1h": 15, "30m": 1, "6h": 1}
+		spike := map[string]float64{"5m": 20, "1h": 2, "30m": 1, "6h": 1}
+		slow := map[string]float64{"5m": 1, "1h": 1, "30m": 7, "6h": 6.5}
+
+		assert.Equal(t, slo.StateCritical, slo.Classify(0.8, fast, 14.4, 6, 0.25))
+		assert.Equal(t, slo.StateOK, slo.Classify(0.8, spike, 14.4, 6, 0.25))
+		assert.Equal(t, slo.StateWarning, slo.Classify(0.8, slow, 14.4, 6, 0.25))
+		assert.Equal(t, slo.StateWarning, slo.Classify(0.2, spike, 14.4, 6, 0.25))
+		assert.Equal(t, slo.StateExhausted, slo.Classify(-0.1, spike, 14.4, 6, 0.25))
+	})
+
+	t.Run("Announces Only Worsening States", func(t *testing.T) {
+		cfg := &config.Config{SLO: config.SLOConfig{
+			FastBurnRate:       14.4,
+			SlowBurnRate:       6,
+			BudgetWarningRatio: 0.25,
+			Objectives:         []config.SLOObjective{availability},
+		}}
+		querier := &staticQuerier{ratios: map[string]float64{"30d": 0.0009}}
+		notifier := &recordingNotifier{}
+		service := slo.NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), querier, notifier)
+
+		statuses, err := service.Evaluate(context.Background())
+		require.NoError(t, err)
+		require.Len(t, statuses, 1)
+		assert.Equal(t, slo.StateWarning, statuses[0].State)
+		require.Len(t, notifier.statuses, 1)
+
+		_, err = service.Evaluate(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, notifier.statuses, 1)
+
+		querier.ratios["30d"] = 0.0011
+		_, err = service.Evaluate(context.Background())
+		require.NoError(t, err)
+		require.Len(t, notifier.statuses, 2)
+		assert.Equal(t, slo.StateExhausted, notifier.statuses[1].State)
+		assert.Contains(t, slo.FormatWarning(notifier.statuses[1]), "error budget exhausted")
+
+		status, ok := service.GetStatus("gateway-availability")
+		require.True(t, ok)
+		assert.Equal(t, slo.StateExhausted, status.State)
+	})
+}
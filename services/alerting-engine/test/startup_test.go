@@ -0,0 +1,170 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/shared/startup"
+)
+
+func newTestSequencer() *startup.Sequencer {
+	return startup.NewSequencer(startup.Backoff{
+		Initial:    time.Millisecond,
+		Max:        5 * time.Millisecond,
+		Multiplier: 2,
+		MaxElapsed: 200 * time.Millisecond,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func readinessStatus(t *testing.T, readiness *startup.Readiness) (int, string) {
+	rec := httptest.NewRecorder()
+	readiness.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	var body struct {
+		Status string `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec.Code, body.Status
+}
+
+func TestStartup_Unit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Retries Dependency Until Available", func(t *testing.T) {
+		seq := newTestSequencer()
+		attempts := 0
+
+		err := seq.Step(ctx, startup.Component{Name: "postgres", Phase: startup.PhaseMigrations, Critical: true}, func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("connection refused")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+
+		components := seq.Readiness().Components()
+		require.Len(t, components, 1)
+		assert.Equal(t, startup.StateReady, components[0].State)
+		assert.Equal(t, 3, components[0].Attempts)
+		assert.Empty(t, components[0].LastError)
+	})
+
+	t.Run("Critical Step Gives Up", func(t *testing.T) {
+		seq := newTestSequencer()
+
+		err := seq.Step(ctx, startup.Component{Name: "postgres", Phase: startup.PhaseMigrations, Critical: true}, func(ctx context.Context) error {
+			return errors.New("connection refused")
+		})
+		assert.ErrorContains(t, err, "postgres: connection refused")
+		assert.Equal(t, startup.StateFailed, seq.Readiness().Components()[0].State)
+	})
+
+	t.Run("Permanent Error Is Not Retried", func(t *testing.T) {
+		seq := newTestSequencer()
+		attempts := 0
+
+		err := seq.Step(ctx, startup.Component{Name: "migrations", Phase: startup.PhaseMigrations, Critical: true}, func(ctx context.Context) error {
+			attempts++
+			return startup.Permanent(errors.New("dirty database version 12"))
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("Optional Step Failure Does Not Block Readiness", func(t *testing.T) {
+		seq := newTestSequencer()
+
+		require.NoError(t, seq.Step(ctx, startup.Component{Name: "postgres", Phase: startup.PhaseMigrations, Critical: true}, func(ctx context.Context) error {
+			return nil
+		}))
+		require.NoError(t, seq.Step(ctx, startup.Component{Name: "cache", Phase: startup.PhaseRepositories}, func(ctx context.Context) error {
+			return errors.New("no route to host")
+		}))
+
+		seq.Complete()
+		assert.True(t, seq.Readiness().Ready())
+	})
+
+	t.Run("Phases Run In Order", func(t *testing.T) {
+		seq := newTestSequencer()
+
+		require.NoError(t, seq.Step(ctx, startup.Component{Name: "neo4j", Phase: startup.PhaseRepositories, Critical: true}, func(ctx context.Context) error {
+			return nil
+		}))
+		assert.Panics(t, func() {
+			seq.Step(ctx, startup.Component{Name: "migrations", Phase: startup.PhaseMigrations, Critical: true}, func(ctx context.Context) error {
+				return nil
+			})
+		})
+	})
+
+	t.Run("Readiness Gated Until Startup Completes", func(t *testing.T) {
+		seq := newTestSequencer()
+		readiness := seq.Readiness()
+
+		require.NoError(t, seq.Step(ctx, startup.Component{Name: "postgres", Phase: startup.PhaseMigrations, Critical: true}, func(ctx context.Context) error {
+			return nil
+		}))
+		code, status := readinessStatus(t, readiness)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "starting", status)
+
+		seq.Complete()
+		code, status = readinessStatus(t, readiness)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ready", status)
+
+		readiness.Drain()
+		code, status = readinessStatus(t, readiness)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "draining", status)
+	})
+
+	t.Run("Critical Component Failure Stops Service", func(t *testing.T) {
+		seq := newTestSequencer()
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stopConsumer := make(chan struct{})
+		seq.Go(runCtx, startup.Component{Name: "event-processor", Phase: startup.PhaseConsumers, Critical: true}, func(ctx context.Context) error {
+			<-stopConsumer
+			return errors.New("broker connection lost")
+		})
+		seq.Go(runCtx, startup.Component{Name: "http-server", Phase: startup.PhaseServers, Critical: true}, func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+		seq.Complete()
+		assert.True(t, seq.Readiness().Ready())
+
+		close(stopConsumer)
+		select {
+		case <-seq.Done():
+		case <-time.After(time.Second):
+			t.Fatal("sequencer did not report the failed consumer")
+		}
+		assert.ErrorContains(t, seq.Err(), "event-processor: broker connection lost")
+		assert.False(t, seq.Readiness().Ready())
+
+		cancel()
+		seq.Wait()
+		states := map[string]startup.State{}
+		for _, component := range seq.Readiness().Components() {
+			states[component.Name] = component.State
+		}
+		assert.Equal(t, startup.StateFailed, states["event-processor"])
+		assert.Equal(t, startup.StateStopped, states["http-server"])
+	})
+}
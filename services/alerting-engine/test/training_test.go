@@ -0,0 +1,113 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/training"
+)
+
+func TestTrainingSimulator_Unit(t *testing.T) {
+	t.Run("Disposition Normalization", func(t *testing.T) {
+		cases := map[string]string{
+			"False Positive":       training.DispositionFalsePositive,
+			"not suspicious":       training.DispositionFalsePositive,
+			"SAR filed":            training.DispositionTruePositive,
+			"confirmed-fraud":      training.DispositionTruePositive,
+			"escalated to case":    training.DispositionEscalated,
+			"duplicate of alert_1": training.DispositionDuplicate,
+			"":                     training.DispositionBenign,
+		}
+
+		for reason, expected := range cases {
+			assert.Equal(t, expected, training.NormalizeDisposition(reason), "reason %q", reason)
+		}
+	})
+
+	t.Run("Disposition Scoring", func(t *testing.T) {
+		assert.Equal(t, 1.0, training.ScoreDisposition(training.DispositionFalsePositive, training.DispositionFalsePositive))
+		assert.Equal(t, 0.5, training.ScoreDisposition(training.DispositionEscalated, training.DispositionTruePositive))
+		assert.Equal(t, 0.0, training.ScoreDisposition(training.DispositionFalsePositive, training.DispositionTruePositive))
+	})
+
+	t.Run("Anonymization Is Stable And Hides Identifiers", func(t *testing.T) {
+		anonymizer := training.NewAnonymizer("session-key", []string{"account_number", "name"})
+
+		event := map[string]interface{}{
+			"account_number": "GB29NWBK60161331926819",
+			"amount":         12500.0,
+			"counterparty": map[string]interface{}{
+				"name": "Jane Doe",
+			},
+		}
+
+		first := anonymizer.AnonymizeMap(event)
+		second := anonymizer.AnonymizeMap(event)
+
+		assert.Equal(t, first["account_number"], second["account_number"], "Pseudonyms should be stable within a session")
+		assert.NotEqual(t, event["account_number"], first["account_number"])
+		assert.Equal(t, 12500.0, first["amount"], "Non-sensitive values should be preserved")
+		assert.NotEqual(t, "Jane Doe", first["counterparty"].(map[string]interface{})["name"])
+
+		other := training.NewAnonymizer("other-key", []string{"account_number"})
+		assert.NotEqual(t, first["account_number"], other.AnonymizeMap(event)["account_number"],
+			"Pseudonyms should differ between sessions")
+	})
+	t.Run("Case Dispositions", func(t *testing.T) {
+		investigationID := "inv-1"
+		falsePositive := "False positive - shared household"
+
+		assert.Equal(t, training.DispositionEscalated, training.CaseDisposition(&database.AlertCase{
+			Status: database.AlertCaseStatusLinked, InvestigationID: &investigationID,
+		}))
+		assert.Equal(t, training.DispositionFalsePositive, training.CaseDisposition(&database.AlertCase{
+			Status: database.AlertCaseStatusClosed, CloseReason: &falsePositive,
+		}))
+	})
+
+	t.Run("Case Anonymization Hides Identifiers And Outcome", func(t *testing.T) {
+		anonymizer := training.NewAnonymizer("session-key", nil)
+		investigationID := "inv-1"
+		closeReason := "confirmed fraud"
+		alertCase := &database.AlertCase{
+			ID:              "case-1",
+			Title:           "3 alerts on ACME-42",
+			Status:          database.AlertCaseStatusLinked,
+			CorrelationKeys: []string{"entity:ACME-42", "account:GB29NWBK60161331926819"},
+			AlertCount:      1,
+			MaxSeverity:     "high",
+			InvestigationID: &investigationID,
+			CloseReason:     &closeReason,
+			FirstAlertAt:    time.Now().Add(-time.Hour),
+			LastAlertAt:     time.Now(),
+		}
+		alerts := []*database.AlertCaseAlert{{
+			AlertID:     "alert-1",
+			Title:       "Structuring by ACME-42",
+			Severity:    "high",
+			EntityIDs:   []string{"ACME-42"},
+			MatchedKeys: []string{"entity:ACME-42"},
+		}}
+
+		anonymized := training.AnonymizeCase(anonymizer, alertCase, alerts, "training-1")
+		encoded, err := json.Marshal(anonymized)
+		require.NoError(t, err)
+
+		for _, hidden := range []string{"case-1", "alert-1", "ACME-42", "GB29NWBK60161331926819", "inv-1", "confirmed fraud", "linked"} {
+			assert.False(t, strings.Contains(string(encoded), hidden), "%q should not reach the trainee", hidden)
+		}
+		assert.Equal(t, "training-1", anonymized["tenant_id"])
+		assert.Equal(t, "high", anonymized["max_severity"])
+
+		keys := anonymized["correlation_keys"].([]string)
+		entityPseudonym := anonymizer.Pseudonym("entity", "ACME-42")
+		assert.Equal(t, "entity:"+entityPseudonym, keys[0], "keys keep their kind and match the entity pseudonyms")
+		assert.Contains(t, anonymized["title"], entityPseudonym)
+	})
+}
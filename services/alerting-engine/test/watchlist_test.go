@@ -0,0 +1,110 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegis-shield/services/alerting-engine/internal/database"
+	"github.com/aegis-shield/services/alerting-engine/internal/watchlist"
+)
+
+func TestWatchlist_Unit(t *testing.T) {
+	now := time.Now()
+	future := now.Add(24 * time.Hour)
+	past := now.Add(-time.Hour)
+
+	entries := []*database.WatchlistEntry{
+		{ID: "e1", ListType: database.ListTypeExclusion, EntityID: "entity-1", Status: database.EntryStatusActive, ExpiresAt: &future},
+		{ID: "e2", ListType: database.ListTypeExclusion, EntityID: "entity-2", Status: database.EntryStatusActive, ExpiresAt: &past},
+		{ID: "e3", ListType: database.ListTypeWatchlist, EntityID: "entity-3", Status: database.EntryStatusActive},
+		{ID: "e4", ListType: database.ListTypeExclusion, EntityID: "entity-4", Status: database.EntryStatusRemoved},
+		{ID: "e5", ListType: database.ListTypeExclusion, EntityID: "entity-5", Status: database.EntryStatusActive},
+	}
+
+	index := watchlist.NewExclusionIndex(entries)
+
+	t.Run("Only Active Exclusions Indexed", func(t *testing.T) {
+		assert.Equal(t, 3, index.Len())
+	})
+
+	t.Run("Matches Active Exclusion", func(t *testing.T) {
+		entry, ok := index.Match([]string{"unknown", "entity-1"}, now)
+		assert.True(t, ok)
+		assert.Equal(t, "e1", entry.ID)
+
+		entry, ok = index.Match([]string{"entity-5"}, now)
+		assert.True(t, ok)
+		assert.Equal(t, "e5", entry.ID)
+	})
+
+	t.Run("Expired Entry Does Not Suppress", func(t *testing.T) {
+		_, ok := index.Match([]string{"entity-2"}, now)
+		assert.False(t, ok)
+
+		_, ok = index.Match([]string{"entity-1"}, future)
+		assert.False(t, ok)
+	})
+
+	t.Run("Watchlist And Removed Entries Do Not Suppress", func(t *testing.T) {
+		_, ok := index.Match([]string{"entity-3", "entity-4"}, now)
+		assert.False(t, ok)
+	})
+
+	t.Run("Nil Index", func(t *testing.T) {
+		var empty *watchlist.ExclusionIndex
+		_, ok := empty.Match([]string{"entity-1"}, now)
+		assert.False(t, ok)
+		assert.Equal(t, 0, empty.Len())
+	})
+}
+
+func TestWatchlist_DualControl(t *testing.T) {
+	now := time.Now()
+	pending := func() *database.WatchlistChangeRequest {
+		return &database.WatchlistChangeRequest{
+			ID:           "wlchg-1",
+			ListType:     database.ListTypeExclusion,
+			Action:       database.WatchlistActionAdd,
+			EntityID:     "entity-1",
+			Status:       database.ChangeStatusPending,
+			RequestedBy:  "analyst-1",
+			RequestedAt:  now.Add(-time.Hour),
+			PendingUntil: now.Add(time.Hour),
+		}
+	}
+
+	t.Run("Second User Can Review", func(t *testing.T) {
+		require.NoError(t, watchlist.CheckReview(pending(), "analyst-2", now))
+	})
+
+	t.Run("Requester Cannot Review Own Change", func(t *testing.T) {
+		err := watchlist.CheckReview(pending(), "analyst-1", now)
+		assert.ErrorIs(t, err, watchlist.ErrSelfApproval)
+	})
+
+	t.Run("Lapsed Change Cannot Be Reviewed", func(t *testing.T) {
+		change := pending()
+		change.PendingUntil = now.Add(-time.Minute)
+		assert.ErrorIs(t, watchlist.CheckReview(change, "analyst-2", now), watchlist.ErrNotPending)
+
+		// The deadline itself is already too late
+		change.PendingUntil = now
+		assert.ErrorIs(t, watchlist.CheckReview(change, "analyst-2", now), watchlist.ErrNotPending)
+	})
+
+	t.Run("Decided Change Cannot Be Reviewed Again", func(t *testing.T) {
+		for _, status := range []string{
+			database.ChangeStatusApproved,
+			database.ChangeStatusRejected,
+			database.ChangeStatusCancelled,
+			database.ChangeStatusLapsed,
+		} {
+			change := pending()
+			change.Status = status
+			assert.ErrorIs(t, watchlist.CheckReview(change, "analyst-2", now), watchlist.ErrNotPending, status)
+		}
+	})
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,16 +15,26 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/gorilla/mux"
+	_ "github.com/lib/pq"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 
+	"aegisshield/services/api-gateway/internal/audit"
 	"aegisshield/services/api-gateway/internal/auth"
 	"aegisshield/services/api-gateway/internal/config"
 	"aegisshield/services/api-gateway/internal/graph"
 	"aegisshield/services/api-gateway/internal/graph/generated"
+	"aegisshield/services/api-gateway/internal/metering"
+	"aegisshield/services/api-gateway/internal/profile"
 	"aegisshield/services/api-gateway/internal/middleware"
+	"aegisshield/services/api-gateway/internal/requestlog"
 	"aegisshield/services/api-gateway/internal/services"
+	"aegisshield/shared/rbac"
+	"aegisshield/shared/rbac/policyservice"
+	"aegisshield/shared/reqctx"
+	"aegisshield/shared/reqctx/logrusctx"
 )
 
 var (
@@ -35,6 +47,8 @@ var (
 func init() {
 	logger.SetFormatter(&logrus.JSONFormatter{})
 	logger.SetLevel(logrus.InfoLevel)
+	// Lines logged WithContext carry the request's correlation ID
+	logger.AddHook(logrusctx.Hook{})
 	
 	if os.Getenv("LOG_LEVEL") == "debug" {
 		logger.SetLevel(logrus.DebugLevel)
@@ -54,6 +68,12 @@ func main() {
 		logger.WithError(err).Fatal("Failed to load configuration")
 	}
 
+	// Initialize request logging; the correlation IDs it assigns are passed on to backend calls
+	requestLogger, err := requestlog.NewLogger(cfg.RequestLog, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to configure request logging")
+	}
+
 	// Initialize services
 	serviceClients, err := services.NewServiceClients(cfg)
 	if err != nil {
@@ -61,8 +81,41 @@ func main() {
 	}
 	defer serviceClients.Close()
 
+	// Initialize role-based access control
+	policy, err := rbac.LoadPolicyFile(cfg.RBAC.PolicyFile)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load RBAC policy")
+	}
+	evaluator := rbac.NewEvaluator(policy, time.Duration(cfg.RBAC.CacheTTLSeconds)*time.Second, cfg.RBAC.CacheMaxEntries)
+
 	// Initialize authentication
-	authService := auth.NewService(cfg.Auth)
+	authService := auth.NewService(cfg.Auth, evaluator)
+
+	// Initialize usage metering
+	db, err := sql.Open("postgres", cfg.Database.PostgreSQLURL)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to open usage database")
+	}
+	defer db.Close()
+
+	usageStore := metering.NewStore(db)
+	if err := usageStore.EnsureSchema(context.Background()); err != nil {
+		logger.WithError(err).Fatal("Failed to prepare usage database")
+	}
+
+	meter := metering.NewMeter()
+	attribution := metering.Attribution{
+		DefaultTenant: cfg.Metering.DefaultTenant,
+		DefaultTeam:   cfg.Metering.DefaultTeam,
+	}
+	meteringService := metering.NewService(meter, usageStore, attribution, cfg.Metering.MaxReportDays, logger)
+
+	meteringCtx, stopMetering := context.WithCancel(context.Background())
+	meteringDone := make(chan struct{})
+	go func() {
+		defer close(meteringDone)
+		meteringService.Run(meteringCtx, time.Duration(cfg.Metering.FlushIntervalSeconds)*time.Second)
+	}()
 
 	// Create GraphQL server
 	resolver := &graph.Resolver{
@@ -79,14 +132,56 @@ func main() {
 	router := mux.NewRouter()
 
 	// Add middleware
-	router.Use(middleware.LoggingMiddleware(logger))
+	router.Use(requestLogger.Middleware())
 	router.Use(middleware.MetricsMiddleware())
-	router.Use(middleware.AuthMiddleware(authService))
+	router.Use(middleware.DeadlineMiddleware(
+		time.Duration(cfg.Pool.RequestTimeoutMs)*time.Millisecond,
+		time.Duration(cfg.Pool.MaxRequestTimeoutMs)*time.Millisecond,
+	))
+	router.Use(middleware.AuthMiddleware(authService, serviceClients.Identity.APIKeys()))
+	router.Use(metering.Middleware(meter, authService, attribution, logger))
 
 	// GraphQL endpoints
 	router.Handle("/query", srv).Methods("POST")
 	router.Handle("/", playground.Handler("GraphQL playground", "/query")).Methods("GET")
 
+	// Cross-service audit trail endpoints
+	auditClient := &http.Client{
+		Timeout:   time.Duration(cfg.Audit.TimeoutSeconds) * time.Second,
+		Transport: reqctx.NewTransport("api-gateway", nil),
+	}
+	auditAggregator := audit.NewAggregator([]audit.Source{
+		audit.NewUserManagementSource(cfg.Audit.UserManagementURL, auditClient),
+		audit.NewInvestigationToolkitSource(cfg.Audit.InvestigationToolkitURL, auditClient),
+		audit.NewAlertingEngineSource(cfg.Audit.AlertingEngineURL, auditClient),
+	}, time.Duration(cfg.Audit.TimeoutSeconds)*time.Second, cfg.Audit.MaxResults, logger)
+	audit.NewHandler(auditAggregator, authService, logger).RegisterRoutes(router)
+
+	// Aggregated entity profile endpoint
+	profileTimeout := time.Duration(cfg.Profile.TimeoutMs) * time.Millisecond
+	profileClient := &http.Client{Timeout: profileTimeout, Transport: reqctx.NewTransport("api-gateway", nil)}
+	profileAggregator := profile.NewAggregator([]profile.Source{
+		profile.NewGraphNeighborhoodSource(cfg.Profile.GraphEngineURL, profileClient),
+		profile.NewGraphMetricsSource(cfg.Profile.GraphEngineURL, profileClient),
+		profile.NewEntityResolutionSource(cfg.Profile.EntityResolutionURL, profileClient),
+		profile.NewAlertingEngineSource(cfg.Profile.AlertingEngineURL, profileClient),
+		profile.NewInvestigationToolkitSource(cfg.Profile.InvestigationToolkitURL, profileClient),
+		profile.NewComplianceEngineSource(cfg.Profile.ComplianceEngineURL, profileClient),
+	}, profileTimeout, profile.Limits{
+		RecentAlerts:   cfg.Profile.RecentAlerts,
+		OpenCases:      cfg.Profile.OpenCases,
+		ScreeningHits:  cfg.Profile.ScreeningHits,
+		Counterparties: cfg.Profile.Counterparties,
+		Scan:           cfg.Profile.ScanLimit,
+	}, logger)
+	profile.NewHandler(profileAggregator, authService, logger).RegisterRoutes(router)
+
+	// Usage metering and chargeback reporting endpoints
+	metering.NewHandler(meteringService, authService, logger).RegisterRoutes(router)
+
+	// Request log sample rates, adjustable at runtime
+	requestlog.NewHandler(requestLogger.Sampler(), authService, logger).RegisterRoutes(router)
+
 	// Health and metrics endpoints
 	router.HandleFunc("/health", healthHandler).Methods("GET")
 	router.HandleFunc("/ready", readinessHandler(serviceClients)).Methods("GET")
@@ -119,6 +214,24 @@ func main() {
 		}
 	}()
 
+	// Serve policy checks for services that do not evaluate the policy locally
+	var policyServer *grpc.Server
+	if cfg.RBAC.GRPCPort > 0 {
+		policyServer = grpc.NewServer()
+		policyservice.NewServer(evaluator, []byte(cfg.Auth.JWTSecret)).Register(policyServer)
+
+		go func() {
+			listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.RBAC.GRPCPort))
+			if err != nil {
+				logger.WithError(err).Fatal("Failed to listen on policy service port")
+			}
+			logger.WithField("port", cfg.RBAC.GRPCPort).Info("Starting RBAC policy gRPC server")
+			if err := policyServer.Serve(listener); err != nil {
+				logger.WithError(err).Error("RBAC policy gRPC server stopped")
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -134,6 +247,13 @@ func main() {
 	if err := httpServer.Shutdown(ctx); err != nil {
 		logger.WithError(err).Error("Failed to shutdown HTTP server gracefully")
 	}
+	if policyServer != nil {
+		policyServer.GracefulStop()
+	}
+
+	// Flush remaining usage
+	stopMetering()
+	<-meteringDone
 
 	logger.Info("Server shutdown complete")
 }
@@ -146,7 +266,7 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 
 func readinessHandler(services *services.ServiceClients) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := context.Background()
+		ctx := r.Context()
 		
 		// Check service connections
 		if err := services.HealthCheck(ctx); err != nil {
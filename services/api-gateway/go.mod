@@ -7,6 +7,7 @@ require (
 	github.com/99designs/gqlgen v0.17.43
 	github.com/golang-jwt/jwt/v5 v5.2.0
 	github.com/gorilla/mux v1.8.1
+	github.com/lib/pq v1.10.9
 	github.com/prometheus/client_golang v1.17.0
 	github.com/rs/cors v1.10.1
 	github.com/sirupsen/logrus v1.9.3
@@ -0,0 +1,196 @@
+package audit
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Event is a user action normalized across service audit trails
+type Event struct {
+	ID         string    `json:"id"`
+	Service    string    `json:"service"`
+	UserID     string    `json:"user_id"`
+	Action     string    `json:"action"`
+	Resource   string    `json:"resource"`
+	ResourceID string    `json:"resource_id,omitempty"`
+	Details    string    `json:"details,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// Query describes which audit events to collect. A user has a different ID
+// in each service, a number in user-management and a UUID in the
+// investigation toolkit, so UserIDs holds every ID of the user to collect
+// events for; each source is queried for the IDs in its form.
+type Query struct {
+	UserIDs  []string   `json:"user_ids,omitempty"`
+	Action   string     `json:"action,omitempty"`
+	Resource string     `json:"resource,omitempty"`
+	Services []string   `json:"services,omitempty"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	Limit    int        `json:"limit"`
+}
+
+// Source fetches audit events from a single service
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context, query Query) ([]Event, error)
+}
+
+// SourceStatus reports how a single source answered a query
+type SourceStatus struct {
+	Service string `json:"service"`
+	Count   int    `json:"count"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Result is the merged, chronologically ordered audit trail
+type Result struct {
+	Events    []Event        `json:"events"`
+	Sources   []SourceStatus `json:"sources"`
+	Partial   bool           `json:"partial"`
+	Truncated bool           `json:"truncated"`
+}
+
+// Aggregator fans audit queries out to every source and merges the results
+type Aggregator struct {
+	sources    []Source
+	timeout    time.Duration
+	maxResults int
+	logger     *logrus.Logger
+}
+
+// NewAggregator creates a new audit aggregator
+func NewAggregator(sources []Source, timeout time.Duration, maxResults int, logger *logrus.Logger) *Aggregator {
+	return &Aggregator{
+		sources:    sources,
+		timeout:    timeout,
+		maxResults: maxResults,
+		logger:     logger,
+	}
+}
+
+// Query collects matching events from all selected sources concurrently.
+// A failing source does not fail the query; it is reported in Sources and
+// the result is marked partial so investigators know the trail is incomplete.
+func (a *Aggregator) Query(ctx context.Context, query Query) *Result {
+	if query.Limit <= 0 || query.Limit > a.maxResults {
+		query.Limit = a.maxResults
+	}
+
+	selected := a.selectSources(query.Services)
+	statuses := make([]SourceStatus, len(selected))
+	batches := make([][]Event, len(selected))
+
+	var wg sync.WaitGroup
+	for i, source := range selected {
+		wg.Add(1)
+		go func(i int, source Source) {
+			defer wg.Done()
+
+			sourceCtx, cancel := context.WithTimeout(ctx, a.timeout)
+			defer cancel()
+
+			events, err := source.Fetch(sourceCtx, query)
+			statuses[i] = SourceStatus{Service: source.Name(), Count: len(events)}
+			if err != nil {
+				a.logger.WithError(err).WithField("service", source.Name()).Warn("Audit source query failed")
+				statuses[i].Error = err.Error()
+				return
+			}
+			for j := range events {
+				events[j].Service = source.Name()
+			}
+			batches[i] = events
+		}(i, source)
+	}
+	wg.Wait()
+
+	result := &Result{Sources: statuses}
+	for _, status := range statuses {
+		if status.Error != "" {
+			result.Partial = true
+		}
+	}
+
+	result.Events, result.Truncated = Merge(batches, query)
+	return result
+}
+
+func (a *Aggregator) selectSources(services []string) []Source {
+	if len(services) == 0 {
+		return a.sources
+	}
+
+	wanted := make(map[string]bool, len(services))
+	for _, service := range services {
+		wanted[strings.TrimSpace(service)] = true
+	}
+
+	var selected []Source
+	for _, source := range a.sources {
+		if wanted[source.Name()] {
+			selected = append(selected, source)
+		}
+	}
+	return selected
+}
+
+// Merge combines per-source batches into a single trail, newest first.
+// Filters are re-applied here because not every source supports all of them.
+func Merge(batches [][]Event, query Query) ([]Event, bool) {
+	var merged []Event
+	for _, batch := range batches {
+		for _, event := range batch {
+			if matches(event, query) {
+				merged = append(merged, event)
+			}
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Timestamp.Equal(merged[j].Timestamp) {
+			return merged[i].Service < merged[j].Service
+		}
+		return merged[i].Timestamp.After(merged[j].Timestamp)
+	})
+
+	if query.Limit > 0 && len(merged) > query.Limit {
+		return merged[:query.Limit], true
+	}
+	return merged, false
+}
+
+func matches(event Event, query Query) bool {
+	if len(query.UserIDs) > 0 && !contains(query.UserIDs, event.UserID) {
+		return false
+	}
+	if query.Action != "" && event.Action != query.Action {
+		return false
+	}
+	if query.Resource != "" && event.Resource != query.Resource {
+		return false
+	}
+	if query.From != nil && event.Timestamp.Before(*query.From) {
+		return false
+	}
+	if query.To != nil && event.Timestamp.After(*query.To) {
+		return false
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var auditEpoch = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+// staticSource returns fixed events and records the queries it is given
+type staticSource struct {
+	name    string
+	events  []Event
+	err     error
+	mu      sync.Mutex
+	queries []Query
+}
+
+func (s *staticSource) Name() string {
+	return s.name
+}
+
+func (s *staticSource) Fetch(ctx context.Context, query Query) ([]Event, error) {
+	s.mu.Lock()
+	s.queries = append(s.queries, query)
+	s.mu.Unlock()
+	return s.events, s.err
+}
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func eventIDs(events []Event) []string {
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	return ids
+}
+
+func TestMerge(t *testing.T) {
+	batches := [][]Event{
+		{
+			{ID: "um-1", Service: ServiceUserManagement, UserID: "42", Action: "login", Timestamp: auditEpoch},
+			{ID: "um-2", Service: ServiceUserManagement, UserID: "7", Action: "login", Timestamp: auditEpoch.Add(time.Minute)},
+		},
+		{
+			{ID: "it-1", Service: ServiceInvestigationToolkit, UserID: "0b7c5a4e-8d1f-4a52-9c3e-2f6d1e0a9b71", Action: "view", Timestamp: auditEpoch.Add(2 * time.Minute)},
+			{ID: "ae-1", Service: ServiceAlertingEngine, UserID: "42", Action: "login", Timestamp: auditEpoch},
+		},
+	}
+
+	t.Run("orders newest first and services alphabetically on ties", func(t *testing.T) {
+		events, truncated := Merge(batches, Query{})
+		if truncated {
+			t.Error("expected untruncated trail")
+		}
+		if got, want := eventIDs(events), []string{"it-1", "um-2", "ae-1", "um-1"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("order = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("re-applies filters", func(t *testing.T) {
+		from := auditEpoch.Add(30 * time.Second)
+		cases := map[string]struct {
+			query Query
+			want  []string
+		}{
+			"user": {Query{UserIDs: []string{"42"}}, []string{"ae-1", "um-1"}},
+			"user in each service": {
+				Query{UserIDs: []string{"42", "0b7c5a4e-8d1f-4a52-9c3e-2f6d1e0a9b71"}},
+				[]string{"it-1", "ae-1", "um-1"},
+			},
+			"action":     {Query{Action: "view"}, []string{"it-1"}},
+			"time range": {Query{From: &from}, []string{"it-1", "um-2"}},
+		}
+		for name, c := range cases {
+			events, _ := Merge(batches, c.query)
+			if got := eventIDs(events); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("%s: got %v, want %v", name, got, c.want)
+			}
+		}
+	})
+
+	t.Run("truncates to the limit", func(t *testing.T) {
+		events, truncated := Merge(batches, Query{Limit: 2})
+		if !truncated {
+			t.Error("expected truncated trail")
+		}
+		if got, want := eventIDs(events), []string{"it-1", "um-2"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
+
+func TestAggregatorQuery(t *testing.T) {
+	users := &staticSource{name: ServiceUserManagement, events: []Event{{ID: "um-1", Timestamp: auditEpoch}}}
+	alerts := &staticSource{name: ServiceAlertingEngine, events: []Event{{ID: "ae-1", Timestamp: auditEpoch.Add(time.Minute)}}}
+	failing := &staticSource{name: ServiceInvestigationToolkit, err: errors.New("connection refused")}
+	aggregator := NewAggregator([]Source{users, failing, alerts}, time.Second, 100, testLogger())
+
+	t.Run("failing sources make the result partial", func(t *testing.T) {
+		result := aggregator.Query(context.Background(), Query{})
+
+		if !result.Partial {
+			t.Error("expected partial result")
+		}
+		if got, want := eventIDs(result.Events), []string{"ae-1", "um-1"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("events = %v, want %v", got, want)
+		}
+		if result.Events[0].Service != ServiceAlertingEngine {
+			t.Errorf("expected events to name their service, got %q", result.Events[0].Service)
+		}
+		want := []SourceStatus{
+			{Service: ServiceUserManagement, Count: 1},
+			{Service: ServiceInvestigationToolkit, Error: "connection refused"},
+			{Service: ServiceAlertingEngine, Count: 1},
+		}
+		if !reflect.DeepEqual(result.Sources, want) {
+			t.Errorf("sources = %+v, want %+v", result.Sources, want)
+		}
+	})
+
+	t.Run("queries only the selected services", func(t *testing.T) {
+		result := aggregator.Query(context.Background(), Query{Services: []string{ServiceAlertingEngine, " " + ServiceUserManagement}})
+
+		if result.Partial {
+			t.Error("expected complete result")
+		}
+		if len(result.Sources) != 2 {
+			t.Errorf("expected 2 sources, got %+v", result.Sources)
+		}
+	})
+
+	t.Run("caps the limit", func(t *testing.T) {
+		for _, limit := range []int{0, 500} {
+			aggregator.Query(context.Background(), Query{Limit: limit, Services: []string{ServiceUserManagement}})
+			if got := users.queries[len(users.queries)-1].Limit; got != 100 {
+				t.Errorf("limit %d: sources asked for %d, want 100", limit, got)
+			}
+		}
+	})
+}
+
+func TestSourcesMapUserIDs(t *testing.T) {
+	var mu sync.Mutex
+	requested := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested[r.URL.Path] = append(requested[r.URL.Path], r.URL.Query().Get("user_id"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"audit_logs": [], "logs": [], "events": []}`))
+	}))
+	defer server.Close()
+
+	sources := []Source{
+		NewUserManagementSource(server.URL, server.Client()),
+		NewInvestigationToolkitSource(server.URL, server.Client()),
+		NewAlertingEngineSource(server.URL, server.Client()),
+	}
+	query := Query{UserIDs: []string{"42", "0b7c5a4e-8d1f-4a52-9c3e-2f6d1e0a9b71"}, Limit: 10}
+	for _, source := range sources {
+		if _, err := source.Fetch(context.Background(), query); err != nil {
+			t.Fatalf("%s: %v", source.Name(), err)
+		}
+	}
+
+	want := map[string][]string{
+		"/audit-logs":        {"42"},
+		"/api/v1/audit/logs": {"0b7c5a4e-8d1f-4a52-9c3e-2f6d1e0a9b71"},
+		"/audit/events":      {"42", "0b7c5a4e-8d1f-4a52-9c3e-2f6d1e0a9b71"},
+	}
+	if !reflect.DeepEqual(requested, want) {
+		t.Errorf("requested user IDs = %v, want %v", requested, want)
+	}
+
+	t.Run("services without the user are not queried", func(t *testing.T) {
+		requested = map[string][]string{}
+		events, err := sources[1].Fetch(context.Background(), Query{UserIDs: []string{"42"}})
+		if err != nil || len(events) != 0 {
+			t.Fatalf("expected no events, got %v, %v", events, err)
+		}
+		if len(requested) != 0 {
+			t.Errorf("expected no request, got %v", requested)
+		}
+	})
+}
+
+func TestSourcesForwardCredentials(t *testing.T) {
+	var mu sync.Mutex
+	var received []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received = append(received, r.Header.Clone())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"audit_logs": [], "logs": [], "events": []}`))
+	}))
+	defer server.Close()
+
+	aggregator := NewAggregator([]Source{
+		NewUserManagementSource(server.URL, server.Client()),
+		NewInvestigationToolkitSource(server.URL, server.Client()),
+		NewAlertingEngineSource(server.URL, server.Client()),
+	}, time.Second, 100, testLogger())
+
+	caller := http.Header{}
+	caller.Set("Authorization", "Bearer caller-token")
+	caller.Set("Cookie", "session=abc")
+	result := aggregator.Query(WithCredentials(context.Background(), caller), Query{})
+
+	if result.Partial {
+		t.Fatalf("expected complete result, got %+v", result.Sources)
+	}
+	if len(received) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(received))
+	}
+	for _, header := range received {
+		if got := header.Get("Authorization"); got != "Bearer caller-token" {
+			t.Errorf("Authorization = %q, want the caller's token", got)
+		}
+		if header.Get("Cookie") != "" {
+			t.Error("only credentials are forwarded")
+		}
+	}
+}
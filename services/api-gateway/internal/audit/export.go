@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Export formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+var csvHeader = []string{"timestamp", "service", "user_id", "action", "resource", "resource_id", "details", "ip_address", "id"}
+
+// WriteCSV writes events as CSV with a header row
+func WriteCSV(w io.Writer, events []Event) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		record := []string{
+			event.Timestamp.UTC().Format(time.RFC3339Nano),
+			event.Service,
+			event.UserID,
+			event.Action,
+			event.Resource,
+			event.ResourceID,
+			event.Details,
+			event.IPAddress,
+			event.ID,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the full result, including source status, as JSON
+func WriteJSON(w io.Writer, result *Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
@@ -0,0 +1,179 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/api-gateway/internal/auth"
+	"aegisshield/shared/rbac"
+)
+
+// Handler serves aggregated audit queries and exports
+type Handler struct {
+	aggregator  *Aggregator
+	authService *auth.Service
+	logger      *logrus.Logger
+}
+
+// NewHandler creates a new audit handler
+func NewHandler(aggregator *Aggregator, authService *auth.Service, logger *logrus.Logger) *Handler {
+	return &Handler{
+		aggregator:  aggregator,
+		authService: authService,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registers audit routes
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/audit/events", h.handleEvents).Methods("GET")
+	router.HandleFunc("/audit/export", h.handleExport).Methods("GET")
+}
+
+func (h *Handler) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	query, err := parseQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result := h.aggregator.Query(WithCredentials(r.Context(), r.Header), query)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *Handler) handleExport(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	query, err := parseQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = FormatCSV
+	}
+	if format != FormatCSV && format != FormatJSON {
+		writeError(w, http.StatusBadRequest, "format must be csv or json")
+		return
+	}
+
+	result := h.aggregator.Query(WithCredentials(r.Context(), r.Header), query)
+	filename := fmt.Sprintf("audit-export-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if result.Partial {
+		w.Header().Set("X-Audit-Partial", "true")
+	}
+
+	switch format {
+	case FormatCSV:
+		w.Header().Set("Content-Type", "text/csv")
+		w.WriteHeader(http.StatusOK)
+		err = WriteCSV(w, result.Events)
+	case FormatJSON:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		err = WriteJSON(w, result)
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to write audit export")
+	}
+}
+
+// authorize rejects callers without the audit:read permission
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	user, err := h.authService.GetUserFromContext(r.Context())
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return false
+	}
+	allowed, err := h.authService.Authorize(r.Context(), user, "audit", rbac.ActionRead)
+	if err != nil {
+		h.logger.WithError(err).Error("Audit permission check failed")
+		writeError(w, http.StatusServiceUnavailable, "permission check failed")
+		return false
+	}
+	if !allowed {
+		writeError(w, http.StatusForbidden, "insufficient permissions to query audit logs")
+		return false
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_id": user.ID,
+		"path":    r.URL.Path,
+		"query":   r.URL.RawQuery,
+	}).Info("Audit trail accessed")
+	return true
+}
+
+func parseQuery(r *http.Request) (Query, error) {
+	values := r.URL.Query()
+
+	query := Query{
+		Action:   values.Get("action"),
+		Resource: values.Get("resource"),
+	}
+
+	// user_id may be repeated to give a user's ID in each service
+	for _, userID := range values["user_id"] {
+		if userID = strings.TrimSpace(userID); userID != "" {
+			query.UserIDs = append(query.UserIDs, userID)
+		}
+	}
+
+	if services := values.Get("services"); services != "" {
+		query.Services = strings.Split(services, ",")
+	}
+
+	if from := values.Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return query, fmt.Errorf("invalid from timestamp, expected RFC3339")
+		}
+		query.From = &t
+	}
+	if to := values.Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return query, fmt.Errorf("invalid to timestamp, expected RFC3339")
+		}
+		query.To = &t
+	}
+	if query.From != nil && query.To != nil && query.To.Before(*query.From) {
+		return query, fmt.Errorf("to must not be before from")
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l <= 0 {
+			return query, fmt.Errorf("limit must be a positive integer")
+		}
+		query.Limit = l
+	}
+
+	return query, nil
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
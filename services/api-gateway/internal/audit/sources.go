@@ -0,0 +1,269 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"aegisshield/shared/rbac"
+)
+
+// Service names reported on aggregated events
+const (
+	ServiceUserManagement       = "user-management"
+	ServiceInvestigationToolkit = "investigation-toolkit"
+	ServiceAlertingEngine       = "alerting-engine"
+)
+
+// credentialsKey carries the caller's credentials in a query's context
+type credentialsKey struct{}
+
+// WithCredentials returns a context carrying the bearer token or API key the
+// caller authenticated with. The services authorize audit reads themselves,
+// so sources query them on the caller's behalf.
+func WithCredentials(ctx context.Context, header http.Header) context.Context {
+	credentials := http.Header{}
+	for _, name := range []string{"Authorization", rbac.APIKeyHeader} {
+		if value := header.Get(name); value != "" {
+			credentials.Set(name, value)
+		}
+	}
+	return context.WithValue(ctx, credentialsKey{}, credentials)
+}
+
+// uuidPattern matches the UUIDs the investigation toolkit identifies users by
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// httpSource queries a service's audit endpoint over REST
+type httpSource struct {
+	name    string
+	baseURL string
+	path    string
+	client  *http.Client
+	params  func(query Query) url.Values
+	decode  func(body *json.Decoder) ([]Event, error)
+	// userID reports whether an ID has the form the service keys users
+	// by; nil accepts every ID
+	userID func(id string) bool
+}
+
+func (s *httpSource) Name() string {
+	return s.name
+}
+
+// Fetch queries the service once for each of the query's user IDs in the
+// service's form. A service none of the IDs belong to has no events for the
+// user and is not queried.
+func (s *httpSource) Fetch(ctx context.Context, query Query) ([]Event, error) {
+	if len(query.UserIDs) == 0 {
+		return s.fetch(ctx, query, "")
+	}
+
+	var events []Event
+	for _, userID := range query.UserIDs {
+		if s.userID != nil && !s.userID(userID) {
+			continue
+		}
+		batch, err := s.fetch(ctx, query, userID)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, batch...)
+	}
+	return events, nil
+}
+
+func (s *httpSource) fetch(ctx context.Context, query Query, userID string) ([]Event, error) {
+	values := s.params(query)
+	if userID != "" {
+		values.Set("user_id", userID)
+	}
+	endpoint := strings.TrimRight(s.baseURL, "/") + s.path + "?" + values.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build audit request: %w", err)
+	}
+	if credentials, ok := ctx.Value(credentialsKey{}).(http.Header); ok {
+		for name, values := range credentials {
+			req.Header[name] = values
+		}
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("audit request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("audit request returned status %d", resp.StatusCode)
+	}
+
+	events, err := s.decode(json.NewDecoder(resp.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode audit response: %w", err)
+	}
+	return events, nil
+}
+
+// NewUserManagementSource queries the user-management audit log, which
+// identifies users by their numeric ID
+func NewUserManagementSource(baseURL string, client *http.Client) Source {
+	return &httpSource{
+		name:    ServiceUserManagement,
+		baseURL: baseURL,
+		path:    "/audit-logs",
+		client:  client,
+		userID: func(id string) bool {
+			_, err := strconv.ParseUint(id, 10, 64)
+			return err == nil
+		},
+		params: func(query Query) url.Values {
+			values := timeRangeParams(query, "from", "to")
+			if query.Action != "" {
+				values.Set("action", query.Action)
+			}
+			if query.Resource != "" {
+				values.Set("resource", query.Resource)
+			}
+			return values
+		},
+		decode: func(body *json.Decoder) ([]Event, error) {
+			var payload struct {
+				AuditLogs []struct {
+					ID        uint      `json:"id"`
+					UserID    uint      `json:"user_id"`
+					Action    string    `json:"action"`
+					Resource  string    `json:"resource"`
+					Details   string    `json:"details"`
+					IPAddress string    `json:"ip_address"`
+					Timestamp time.Time `json:"timestamp"`
+				} `json:"audit_logs"`
+			}
+			if err := body.Decode(&payload); err != nil {
+				return nil, err
+			}
+
+			events := make([]Event, 0, len(payload.AuditLogs))
+			for _, log := range payload.AuditLogs {
+				events = append(events, Event{
+					ID:        strconv.FormatUint(uint64(log.ID), 10),
+					UserID:    strconv.FormatUint(uint64(log.UserID), 10),
+					Action:    log.Action,
+					Resource:  log.Resource,
+					Details:   log.Details,
+					IPAddress: log.IPAddress,
+					Timestamp: log.Timestamp,
+				})
+			}
+			return events, nil
+		},
+	}
+}
+
+// NewInvestigationToolkitSource queries the investigation toolkit audit log,
+// which identifies users by UUID
+func NewInvestigationToolkitSource(baseURL string, client *http.Client) Source {
+	return &httpSource{
+		name:    ServiceInvestigationToolkit,
+		baseURL: baseURL,
+		path:    "/api/v1/audit/logs",
+		client:  client,
+		userID:  uuidPattern.MatchString,
+		params: func(query Query) url.Values {
+			values := timeRangeParams(query, "date_from", "date_to")
+			if query.Action != "" {
+				values.Set("action", query.Action)
+			}
+			if query.Resource != "" {
+				values.Set("entity_type", query.Resource)
+			}
+			return values
+		},
+		decode: func(body *json.Decoder) ([]Event, error) {
+			var payload struct {
+				Logs []struct {
+					ID           string          `json:"id"`
+					UserID       string          `json:"user_id"`
+					Action       string          `json:"action"`
+					ResourceType string          `json:"resource_type"`
+					ResourceID   *string         `json:"resource_id"`
+					IPAddress    *string         `json:"ip_address"`
+					Metadata     json.RawMessage `json:"metadata"`
+					CreatedAt    time.Time       `json:"created_at"`
+				} `json:"logs"`
+			}
+			if err := body.Decode(&payload); err != nil {
+				return nil, err
+			}
+
+			events := make([]Event, 0, len(payload.Logs))
+			for _, log := range payload.Logs {
+				event := Event{
+					ID:        log.ID,
+					UserID:    log.UserID,
+					Action:    log.Action,
+					Resource:  log.ResourceType,
+					Timestamp: log.CreatedAt,
+				}
+				if log.ResourceID != nil {
+					event.ResourceID = *log.ResourceID
+				}
+				if log.IPAddress != nil {
+					event.IPAddress = *log.IPAddress
+				}
+				if len(log.Metadata) > 0 && string(log.Metadata) != "null" {
+					event.Details = string(log.Metadata)
+				}
+				events = append(events, event)
+			}
+			return events, nil
+		},
+	}
+}
+
+// NewAlertingEngineSource queries user actions recorded by the alerting engine
+func NewAlertingEngineSource(baseURL string, client *http.Client) Source {
+	return &httpSource{
+		name:    ServiceAlertingEngine,
+		baseURL: baseURL,
+		path:    "/audit/events",
+		client:  client,
+		params: func(query Query) url.Values {
+			values := timeRangeParams(query, "from", "to")
+			if query.Action != "" {
+				values.Set("action", query.Action)
+			}
+			return values
+		},
+		decode: func(body *json.Decoder) ([]Event, error) {
+			var payload struct {
+				Events []Event `json:"events"`
+			}
+			if err := body.Decode(&payload); err != nil {
+				return nil, err
+			}
+			return payload.Events, nil
+		},
+	}
+}
+
+// timeRangeParams builds the limit and time range parameters shared by every source
+func timeRangeParams(query Query, fromKey, toKey string) url.Values {
+	values := url.Values{}
+	values.Set("limit", strconv.Itoa(query.Limit))
+	if query.From != nil {
+		values.Set(fromKey, query.From.Format(time.RFC3339))
+	}
+	if query.To != nil {
+		values.Set(toKey, query.To.Format(time.RFC3339))
+	}
+	return values
+}
@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"aegisshield/services/api-gateway/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksRefreshInterval limits how often an unknown key id refetches the JWKS,
+// whether or not the last fetch succeeded
+const jwksRefreshInterval = time.Minute
+
+// OIDCValidator verifies tokens issued by the OpenID Connect identity
+// provider against its published signing keys and maps the IdP groups in
+// them to gateway roles
+type OIDCValidator struct {
+	config config.OIDCConfig
+	client *http.Client
+
+	mu              sync.Mutex
+	jwksURL         string
+	keys            map[string]*rsa.PublicKey
+	keysRequestedAt time.Time
+}
+
+// NewOIDCValidator creates an OIDC token validator. The JWKS URL is
+// discovered from the issuer on first use unless configured.
+func NewOIDCValidator(cfg config.OIDCConfig) *OIDCValidator {
+	cfg.IssuerURL = strings.TrimRight(cfg.IssuerURL, "/")
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+
+	return &OIDCValidator{
+		config:  cfg,
+		client:  &http.Client{Timeout: 5 * time.Second},
+		jwksURL: cfg.JWKSURL,
+		keys:    make(map[string]*rsa.PublicKey),
+	}
+}
+
+// Issued reports whether the token claims to come from the identity
+// provider. The signature is not checked here.
+func (v *OIDCValidator) Issued(tokenString string) bool {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return false
+	}
+	issuer, _ := claims["iss"].(string)
+	return issuer != "" && strings.TrimRight(issuer, "/") == v.config.IssuerURL
+}
+
+// Validate verifies an IdP token and converts it to gateway claims. Only
+// tokens issued for the configured audience are accepted.
+func (v *OIDCValidator) Validate(tokenString string) (*Claims, error) {
+	if v.config.Audience == "" {
+		return nil, errors.New("no OIDC audience is configured")
+	}
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+		jwt.WithAudience(v.config.Audience),
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.key(kid)
+	}, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	issuer, _ := claims["iss"].(string)
+	if strings.TrimRight(issuer, "/") != v.config.IssuerURL {
+		return nil, fmt.Errorf("unexpected token issuer: %s", issuer)
+	}
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("token has no subject")
+	}
+
+	roles := v.roles(claims[v.config.GroupsClaim])
+	if len(roles) == 0 {
+		return nil, fmt.Errorf("no role is mapped to the token groups")
+	}
+
+	email, _ := claims["email"].(string)
+	result := &Claims{
+		UserID: subject,
+		Email:  email,
+		Roles:  roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:  issuer,
+			Subject: subject,
+		},
+	}
+	result.ExpiresAt, _ = claims.GetExpirationTime()
+	result.IssuedAt, _ = claims.GetIssuedAt()
+	return result, nil
+}
+
+// roles maps the token groups, given as a JSON array or a single string, to
+// roles, falling back to the default role
+func (v *OIDCValidator) roles(value interface{}) []string {
+	var groups []string
+	switch typed := value.(type) {
+	case []interface{}:
+		for _, group := range typed {
+			if name, ok := group.(string); ok {
+				groups = append(groups, name)
+			}
+		}
+	case string:
+		groups = []string{typed}
+	}
+
+	var roles []string
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		role, ok := v.config.GroupRoles[group]
+		if !ok || seen[role] {
+			continue
+		}
+		seen[role] = true
+		roles = append(roles, role)
+	}
+
+	if len(roles) == 0 && v.config.DefaultRole != "" {
+		roles = []string{v.config.DefaultRole}
+	}
+	return roles
+}
+
+// key returns the signing key with the given id, refetching the key set
+// when the IdP has rotated to a key not seen yet. Failed fetches back off
+// like successful ones, so an unreachable IdP is not asked again for every
+// token.
+func (v *OIDCValidator) key(kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(v.keysRequestedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	v.keysRequestedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), v.client.Timeout)
+	defer cancel()
+
+	if v.jwksURL == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.getJSON(ctx, v.config.IssuerURL+"/.well-known/openid-configuration", &discovery); err != nil {
+			return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+		}
+		if discovery.JWKSURI == "" {
+			return nil, errors.New("OIDC discovery document has no jwks_uri")
+		}
+		v.jwksURL = discovery.JWKSURI
+	}
+
+	var set jsonWebKeySet
+	if err := v.getJSON(ctx, v.jwksURL, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	v.keys = set.rsaKeys()
+
+	key, ok := v.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (v *OIDCValidator) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+// jsonWebKeySet is a JWKS document (RFC 7517)
+type jsonWebKeySet struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// rsaKeys returns the RSA signing keys of the set by key id
+func (s jsonWebKeySet) rsaKeys() map[string]*rsa.PublicKey {
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range s.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"aegisshield/services/api-gateway/internal/config"
+)
+
+// identityProvider serves a JWKS with one signing key and counts fetches
+type identityProvider struct {
+	server  *httptest.Server
+	key     *rsa.PrivateKey
+	fetches int32
+	down    int32
+}
+
+func newIdentityProvider(t *testing.T) *identityProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	idp := &identityProvider{key: key}
+	idp.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&idp.fetches, 1)
+		if atomic.LoadInt32(&idp.down) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *identityProvider) validator() *OIDCValidator {
+	return NewOIDCValidator(config.OIDCConfig{
+		IssuerURL:  "https://idp.example.com",
+		Audience:   "aegisshield-gateway",
+		JWKSURL:    idp.server.URL,
+		GroupRoles: map[string]string{"aml-analysts": "analyst"},
+	})
+}
+
+func (idp *identityProvider) token(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "key-1"
+	signed, err := token.SignedString(idp.key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func idpClaims(audience interface{}) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"iss":    "https://idp.example.com",
+		"sub":    "user-1",
+		"groups": []string{"aml-analysts"},
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}
+	if audience != nil {
+		claims["aud"] = audience
+	}
+	return claims
+}
+
+func TestOIDCValidatorAudience(t *testing.T) {
+	idp := newIdentityProvider(t)
+	validator := idp.validator()
+
+	claims, err := validator.Validate(idp.token(t, idpClaims([]string{"other-client", "aegisshield-gateway"})))
+	if err != nil {
+		t.Fatalf("expected token for the gateway to be accepted, got %v", err)
+	}
+	if claims.UserID != "user-1" || len(claims.Roles) != 1 || claims.Roles[0] != "analyst" {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	for name, audience := range map[string]interface{}{
+		"another client": "other-client",
+		"no audience":    nil,
+	} {
+		if _, err := validator.Validate(idp.token(t, idpClaims(audience))); err == nil {
+			t.Errorf("%s: expected token to be rejected", name)
+		}
+	}
+
+	unconfigured := NewOIDCValidator(config.OIDCConfig{IssuerURL: "https://idp.example.com", JWKSURL: idp.server.URL})
+	if _, err := unconfigured.Validate(idp.token(t, idpClaims("aegisshield-gateway"))); err == nil {
+		t.Error("expected validator without an audience to reject tokens")
+	}
+}
+
+func TestOIDCValidatorBacksOffAfterFailedFetch(t *testing.T) {
+	idp := newIdentityProvider(t)
+	validator := idp.validator()
+	token := idp.token(t, idpClaims("aegisshield-gateway"))
+
+	atomic.StoreInt32(&idp.down, 1)
+	for i := 0; i < 5; i++ {
+		if _, err := validator.Validate(token); err == nil {
+			t.Fatal("expected validation to fail while the IdP is down")
+		}
+	}
+	if got := atomic.LoadInt32(&idp.fetches); got != 1 {
+		t.Errorf("expected one JWKS fetch while backing off, got %d", got)
+	}
+
+	// Once the back-off has passed the keys are fetched again
+	atomic.StoreInt32(&idp.down, 0)
+	validator.keysRequestedAt = time.Now().Add(-jwksRefreshInterval)
+	if _, err := validator.Validate(token); err != nil {
+		t.Fatalf("expected token to be accepted after the IdP recovered, got %v", err)
+	}
+	if got := atomic.LoadInt32(&idp.fetches); got != 2 {
+		t.Errorf("expected a second JWKS fetch, got %d", got)
+	}
+}
@@ -5,30 +5,58 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"aegisshield/services/api-gateway/internal/config"
+	"aegisshield/shared/rbac"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type Service struct {
-	config config.AuthConfig
+	config  config.AuthConfig
+	checker rbac.Checker
+	oidc    *OIDCValidator // nil unless an OIDC issuer is configured
 }
 
 type Claims struct {
-	UserID   string   `json:"user_id"`
-	Email    string   `json:"email"`
-	Roles    []string `json:"roles"`
+	UserID      string   `json:"user_id"`
+	Email       string   `json:"email"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions,omitempty"`
+	TenantID    string   `json:"tenant_id,omitempty"`
+	TeamID      string   `json:"team_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type User struct {
-	ID    string   `json:"id"`
-	Email string   `json:"email"`
-	Roles []string `json:"roles"`
+	ID          string   `json:"id"`
+	Email       string   `json:"email"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions,omitempty"`
+	TenantID    string   `json:"tenant_id,omitempty"`
+	TeamID      string   `json:"team_id,omitempty"`
 }
 
-func NewService(cfg config.AuthConfig) *Service {
+// Subject returns the user as an rbac subject
+func (u *User) Subject() *rbac.Subject {
+	return &rbac.Subject{
+		ID:          u.ID,
+		Roles:       u.Roles,
+		Permissions: u.Permissions,
+	}
+}
+
+// NewService creates the auth service. checker decides resource/action
+// permissions for Authorize. Tokens from the configured OIDC identity
+// provider are accepted alongside the gateway's own.
+func NewService(cfg config.AuthConfig, checker rbac.Checker) *Service {
+	var oidc *OIDCValidator
+	if cfg.OIDC.IssuerURL != "" {
+		oidc = NewOIDCValidator(cfg.OIDC)
+	}
+
 	return &Service{
-		config: cfg,
+		config:  cfg,
+		checker: checker,
+		oidc:    oidc,
 	}
 }
 
@@ -37,9 +65,12 @@ func (s *Service) GenerateToken(user *User) (string, error) {
 	expirationTime := now.Add(time.Duration(s.config.TokenDuration) * time.Minute)
 
 	claims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Roles:  user.Roles,
+		UserID:      user.ID,
+		Email:       user.Email,
+		Roles:       user.Roles,
+		Permissions: user.Permissions,
+		TenantID:    user.TenantID,
+		TeamID:      user.TeamID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -59,6 +90,10 @@ func (s *Service) GenerateToken(user *User) (string, error) {
 }
 
 func (s *Service) ValidateToken(tokenString string) (*Claims, error) {
+	if s.oidc != nil && s.oidc.Issued(tokenString) {
+		return s.oidc.Validate(tokenString)
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -108,6 +143,16 @@ func (s *Service) HasAnyRole(user *User, roles []string) bool {
 	return false
 }
 
+// Authorize reports whether the user may perform action on resource under
+// the shared RBAC policy
+func (s *Service) Authorize(ctx context.Context, user *User, resource, action string) (bool, error) {
+	decision, err := s.checker.CheckPermission(ctx, user.Subject(), resource, action)
+	if err != nil {
+		return false, err
+	}
+	return decision.Allowed, nil
+}
+
 // Predefined roles
 const (
 	RoleAnalyst      = "analyst"
@@ -115,4 +160,5 @@ const (
 	RoleAdmin        = "admin"
 	RoleCompliance   = "compliance"
 	RoleViewOnly     = "view_only"
-)
\ No newline at end of file
+	RoleService      = "service"
+)
@@ -0,0 +1,31 @@
+package config
+
+import "testing"
+
+func TestLoadRequiresOIDCAudience(t *testing.T) {
+	t.Setenv("OIDC_ISSUER_URL", "https://idp.example.com")
+	t.Setenv("OIDC_AUDIENCE", "")
+	t.Setenv("OIDC_CLIENT_ID", "")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected an OIDC issuer without an audience to be rejected")
+	}
+
+	t.Setenv("OIDC_CLIENT_ID", "aegisshield-web")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Auth.OIDC.Audience != "aegisshield-web" {
+		t.Errorf("expected the audience to default to the client ID, got %q", cfg.Auth.OIDC.Audience)
+	}
+
+	t.Setenv("OIDC_AUDIENCE", "aegisshield-gateway")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Auth.OIDC.Audience != "aegisshield-gateway" {
+		t.Errorf("audience = %q", cfg.Auth.OIDC.Audience)
+	}
+}
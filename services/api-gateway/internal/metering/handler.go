@@ -0,0 +1,320 @@
+package metering
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/api-gateway/internal/auth"
+	"aegisshield/shared/rbac"
+)
+
+// usageResource is the RBAC resource guarding usage data. Reading reports
+// and billing exports needs usage:read; reporting usage on behalf of a
+// service needs usage:write.
+const usageResource = "usage"
+
+// Service ties the in-memory meter to the daily usage store
+type Service struct {
+	meter       *Meter
+	store       *Store
+	attribution Attribution
+	maxDays     int
+	logger      *logrus.Logger
+}
+
+// NewService creates a new metering service
+func NewService(meter *Meter, store *Store, attribution Attribution, maxDays int, logger *logrus.Logger) *Service {
+	return &Service{
+		meter:       meter,
+		store:       store,
+		attribution: attribution,
+		maxDays:     maxDays,
+		logger:      logger,
+	}
+}
+
+// Flush persists accumulated usage. On failure the usage is kept in memory
+// and retried on the next flush.
+func (s *Service) Flush(ctx context.Context) error {
+	records := s.meter.Drain()
+	if err := s.store.Save(ctx, records); err != nil {
+		s.meter.Restore(records)
+		return err
+	}
+	return nil
+}
+
+// Run flushes usage on an interval until the context is cancelled, then
+// flushes once more so no usage is lost on shutdown
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := s.Flush(flushCtx); err != nil {
+				s.logger.WithError(err).Error("Failed to flush usage on shutdown")
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				s.logger.WithError(err).Error("Failed to flush usage")
+			}
+		}
+	}
+}
+
+// Handler serves usage ingestion, reports and billing exports
+type Handler struct {
+	service     *Service
+	authService *auth.Service
+	logger      *logrus.Logger
+}
+
+// NewHandler creates a new metering handler
+func NewHandler(service *Service, authService *auth.Service, logger *logrus.Logger) *Handler {
+	return &Handler{
+		service:     service,
+		authService: authService,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registers usage routes
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/usage/events", h.handleEvents).Methods("POST")
+	router.HandleFunc("/usage/report", h.handleReport).Methods("GET")
+	router.HandleFunc("/usage/export", h.handleExport).Methods("GET")
+}
+
+// UsageEvent is usage reported by another service, such as alerts raised,
+// predictions served or storage held
+type UsageEvent struct {
+	TenantID   string     `json:"tenant_id"`
+	TeamID     string     `json:"team_id"`
+	Metric     string     `json:"metric"`
+	Quantity   float64    `json:"quantity"`
+	OccurredAt *time.Time `json:"occurred_at"`
+}
+
+func (h *Handler) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r, rbac.ActionWrite) {
+		return
+	}
+
+	var req struct {
+		Events []UsageEvent `json:"events"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.Events) == 0 {
+		writeError(w, http.StatusBadRequest, "at least one event is required")
+		return
+	}
+
+	// Validate everything first so a batch is accepted or rejected as a whole
+	for i, event := range req.Events {
+		if _, err := MetricKind(event.Metric); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("event %d: %s", i, err.Error()))
+			return
+		}
+		if event.Quantity < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("event %d: quantity must not be negative", i))
+			return
+		}
+	}
+
+	now := time.Now()
+	for _, event := range req.Events {
+		tenantID, teamID := h.service.attribution.Resolve(&auth.User{TenantID: event.TenantID, TeamID: event.TeamID})
+		at := now
+		if event.OccurredAt != nil {
+			at = *event.OccurredAt
+		}
+		h.service.meter.Record(tenantID, teamID, event.Metric, event.Quantity, at)
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{"accepted": len(req.Events)})
+}
+
+func (h *Handler) handleReport(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r, rbac.ActionRead) {
+		return
+	}
+
+	filter, err := h.parseFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = GroupByTenant
+	}
+	if groupBy != GroupByTenant && groupBy != GroupByTeam {
+		writeError(w, http.StatusBadRequest, "group_by must be tenant or team")
+		return
+	}
+
+	usage, ok := h.loadUsage(w, r, filter)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"from":     filter.From.Format("2006-01-02"),
+		"to":       filter.To.Format("2006-01-02"),
+		"group_by": groupBy,
+		"usage":    Summarize(usage, groupBy),
+	})
+}
+
+func (h *Handler) handleExport(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r, rbac.ActionRead) {
+		return
+	}
+
+	filter, err := h.parseFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		writeError(w, http.StatusBadRequest, "format must be csv or json")
+		return
+	}
+
+	usage, ok := h.loadUsage(w, r, filter)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("usage-%s-%s.%s", filter.From.Format("20060102"), filter.To.Format("20060102"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.WriteHeader(http.StatusOK)
+		err = WriteCSV(w, usage)
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(map[string]interface{}{"usage": usage})
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to write usage export")
+	}
+}
+
+// loadUsage flushes pending usage so today's figures are current, then reads the store
+func (h *Handler) loadUsage(w http.ResponseWriter, r *http.Request, filter Filter) ([]DailyUsage, bool) {
+	if err := h.service.Flush(r.Context()); err != nil {
+		h.logger.WithError(err).Warn("Failed to flush usage before reporting")
+	}
+
+	usage, err := h.service.store.Daily(r.Context(), filter)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to load usage")
+		writeError(w, http.StatusInternalServerError, "failed to load usage")
+		return nil, false
+	}
+	if usage == nil {
+		usage = []DailyUsage{}
+	}
+	return usage, true
+}
+
+// parseFilter reads a date range (YYYY-MM-DD, defaulting to the current month)
+// and optional tenant, team and metric filters
+func (h *Handler) parseFilter(r *http.Request) (Filter, error) {
+	values := r.URL.Query()
+	now := time.Now().UTC()
+
+	filter := Filter{
+		From:     time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		TenantID: values.Get("tenant_id"),
+		TeamID:   values.Get("team_id"),
+	}
+
+	if from := values.Get("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from date, expected YYYY-MM-DD")
+		}
+		filter.From = t
+	}
+	if to := values.Get("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to date, expected YYYY-MM-DD")
+		}
+		filter.To = t
+	}
+	if filter.To.Before(filter.From) {
+		return filter, fmt.Errorf("to must not be before from")
+	}
+	if days := int(filter.To.Sub(filter.From).Hours()/24) + 1; days > h.service.maxDays {
+		return filter, fmt.Errorf("date range must not exceed %d days", h.service.maxDays)
+	}
+
+	if metrics := values.Get("metrics"); metrics != "" {
+		for _, metric := range strings.Split(metrics, ",") {
+			if _, err := MetricKind(metric); err != nil {
+				return filter, err
+			}
+			filter.Metrics = append(filter.Metrics, metric)
+		}
+	}
+
+	return filter, nil
+}
+
+// authorize rejects callers not allowed the given action on usage data
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request, action string) bool {
+	user, err := h.authService.GetUserFromContext(r.Context())
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return false
+	}
+	allowed, err := h.authService.Authorize(r.Context(), user, usageResource, action)
+	if err != nil {
+		h.logger.WithError(err).Error("Usage permission check failed")
+		writeError(w, http.StatusServiceUnavailable, "permission check failed")
+		return false
+	}
+	if !allowed {
+		writeError(w, http.StatusForbidden, "insufficient permissions for usage data")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
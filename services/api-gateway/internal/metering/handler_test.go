@@ -0,0 +1,143 @@
+package metering
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"aegisshield/services/api-gateway/internal/auth"
+	"aegisshield/services/api-gateway/internal/config"
+	"aegisshield/shared/rbac"
+)
+
+// grants allows the listed actions on usage data to every subject
+type grants []string
+
+func (g grants) CheckPermission(ctx context.Context, subject *rbac.Subject, resource, action string) (rbac.Decision, error) {
+	for _, granted := range g {
+		if resource == usageResource && action == granted {
+			return rbac.Decision{Allowed: true}, nil
+		}
+	}
+	return rbac.Decision{Reason: "not granted"}, nil
+}
+
+// failingChecker stands in for an unreachable policy store
+type failingChecker struct{}
+
+func (failingChecker) CheckPermission(ctx context.Context, subject *rbac.Subject, resource, action string) (rbac.Decision, error) {
+	return rbac.Decision{}, errors.New("policy store unavailable")
+}
+
+// testHandler returns a handler without a store, so only requests rejected
+// before usage is loaded can be served
+func testHandler(checker rbac.Checker) (*Handler, *Meter) {
+	meter := NewMeter()
+	service := NewService(meter, nil, Attribution{DefaultTenant: "default", DefaultTeam: "unassigned"}, 31, testLogger())
+	return NewHandler(service, auth.NewService(config.AuthConfig{}, checker), testLogger()), meter
+}
+
+func usageRequest(method, target, body string, user *auth.User) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if user != nil {
+		r = r.WithContext(context.WithValue(r.Context(), "user", user))
+	}
+	return r
+}
+
+func TestHandleEvents(t *testing.T) {
+	service := &auth.User{ID: "alerting-engine", Roles: []string{auth.RoleService}}
+	batch := `{"events": [
+		{"tenant_id": "acme", "team_id": "aml", "metric": "alerts_generated", "quantity": 3, "occurred_at": "2026-03-02T10:00:00Z"},
+		{"tenant_id": "acme", "metric": "ml_predictions", "quantity": 40, "occurred_at": "2026-03-02T10:00:00Z"}
+	]}`
+
+	for _, tt := range []struct {
+		name     string
+		checker  rbac.Checker
+		user     *auth.User
+		body     string
+		status   int
+		recorded int
+	}{
+		{"unauthenticated callers are rejected", grants{rbac.ActionWrite}, nil, batch, http.StatusUnauthorized, 0},
+		{"callers without usage:write are rejected", grants{rbac.ActionRead}, service, batch, http.StatusForbidden, 0},
+		{"a failing permission check is not an allow", failingChecker{}, service, batch, http.StatusServiceUnavailable, 0},
+		{"an empty batch", grants{rbac.ActionWrite}, service, `{"events": []}`, http.StatusBadRequest, 0},
+		{"an unknown metric rejects the whole batch", grants{rbac.ActionWrite}, service,
+			`{"events": [{"metric": "api_calls", "quantity": 1}, {"metric": "page_views", "quantity": 1}]}`, http.StatusBadRequest, 0},
+		{"a negative quantity rejects the whole batch", grants{rbac.ActionWrite}, service,
+			`{"events": [{"metric": "api_calls", "quantity": 1}, {"metric": "storage_bytes", "quantity": -10}]}`, http.StatusBadRequest, 0},
+		{"a valid batch is recorded", grants{rbac.ActionWrite}, service, batch, http.StatusAccepted, 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, meter := testHandler(tt.checker)
+
+			rec := httptest.NewRecorder()
+			handler.handleEvents(rec, usageRequest(http.MethodPost, "/usage/events", tt.body, tt.user))
+			if rec.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if records := meter.Drain(); len(records) != tt.recorded {
+				t.Fatalf("got %d usage buckets, want %d", len(records), tt.recorded)
+			}
+		})
+	}
+
+	t.Run("events without a team are charged to the default team", func(t *testing.T) {
+		handler, meter := testHandler(grants{rbac.ActionWrite})
+
+		rec := httptest.NewRecorder()
+		handler.handleEvents(rec, usageRequest(http.MethodPost, "/usage/events",
+			`{"events": [{"tenant_id": "acme", "metric": "ml_predictions", "quantity": 40}]}`, service))
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("got status %d, want %d", rec.Code, http.StatusAccepted)
+		}
+		records := meter.Drain()
+		if len(records) != 1 || records[0].TenantID != "acme" || records[0].TeamID != "unassigned" {
+			t.Fatalf("got %+v, want usage charged to acme/unassigned", records)
+		}
+	})
+}
+
+func TestUsageReportLimits(t *testing.T) {
+	analyst := &auth.User{ID: "alice", Roles: []string{auth.RoleAnalyst}}
+
+	for _, tt := range []struct {
+		name    string
+		checker rbac.Checker
+		target  string
+		status  int
+		message string
+	}{
+		{"reports need usage:read", grants{rbac.ActionWrite}, "/usage/report?from=2026-03-01&to=2026-03-31", http.StatusForbidden, ""},
+		{"ranges longer than the limit", grants{rbac.ActionRead}, "/usage/report?from=2026-01-01&to=2026-03-31", http.StatusBadRequest, "date range must not exceed 31 days"},
+		{"ranges ending before they start", grants{rbac.ActionRead}, "/usage/report?from=2026-03-31&to=2026-03-01", http.StatusBadRequest, "to must not be before from"},
+		{"malformed dates", grants{rbac.ActionRead}, "/usage/report?from=03/01/2026", http.StatusBadRequest, "invalid from date"},
+		{"unknown metrics", grants{rbac.ActionRead}, "/usage/report?from=2026-03-01&to=2026-03-31&metrics=api_calls,page_views", http.StatusBadRequest, "unknown usage metric"},
+		{"unknown groupings", grants{rbac.ActionRead}, "/usage/report?from=2026-03-01&to=2026-03-31&group_by=region", http.StatusBadRequest, "group_by must be tenant or team"},
+		{"exports apply the same limit", grants{rbac.ActionRead}, "/usage/export?from=2026-01-01&to=2026-03-31", http.StatusBadRequest, "date range must not exceed 31 days"},
+		{"unknown export formats", grants{rbac.ActionRead}, "/usage/export?from=2026-03-01&to=2026-03-31&format=xlsx", http.StatusBadRequest, "format must be csv or json"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := testHandler(tt.checker)
+
+			rec := httptest.NewRecorder()
+			r := usageRequest(http.MethodGet, tt.target, "", analyst)
+			if strings.HasPrefix(tt.target, "/usage/export") {
+				handler.handleExport(rec, r)
+			} else {
+				handler.handleReport(rec, r)
+			}
+			if rec.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.message) {
+				t.Fatalf("got %s, want an error containing %q", rec.Body.String(), tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package metering
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Metered usage types
+const (
+	MetricAPICalls        = "api_calls"
+	MetricAlertsGenerated = "alerts_generated"
+	MetricStorageBytes    = "storage_bytes"
+	MetricMLPredictions   = "ml_predictions"
+)
+
+// Metric kinds. Counters are summed over a day; gauges keep the day's peak.
+const (
+	KindCounter = "counter"
+	KindGauge   = "gauge"
+)
+
+var metricKinds = map[string]string{
+	MetricAPICalls:        KindCounter,
+	MetricAlertsGenerated: KindCounter,
+	MetricStorageBytes:    KindGauge,
+	MetricMLPredictions:   KindCounter,
+}
+
+// MetricKind returns the kind of a known metric
+func MetricKind(metric string) (string, error) {
+	kind, ok := metricKinds[metric]
+	if !ok {
+		return "", fmt.Errorf("unknown usage metric %q", metric)
+	}
+	return kind, nil
+}
+
+// Key identifies one daily usage bucket
+type Key struct {
+	Day      string // YYYY-MM-DD in UTC
+	TenantID string
+	TeamID   string
+	Metric   string
+}
+
+// Record is the accumulated usage of one bucket
+type Record struct {
+	Key
+	Kind     string
+	Quantity float64
+}
+
+// Meter accumulates usage in memory until it is flushed to the store
+type Meter struct {
+	mu      sync.Mutex
+	buckets map[Key]*Record
+}
+
+// NewMeter creates an empty meter
+func NewMeter() *Meter {
+	return &Meter{buckets: make(map[Key]*Record)}
+}
+
+// Record adds usage for a tenant and team at the given time
+func (m *Meter) Record(tenantID, teamID, metric string, quantity float64, at time.Time) error {
+	kind, err := MetricKind(metric)
+	if err != nil {
+		return err
+	}
+	if quantity < 0 {
+		return fmt.Errorf("usage quantity must not be negative")
+	}
+
+	key := Key{
+		Day:      at.UTC().Format("2006-01-02"),
+		TenantID: tenantID,
+		TeamID:   teamID,
+		Metric:   metric,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.merge(Record{Key: key, Kind: kind, Quantity: quantity})
+	return nil
+}
+
+// Drain returns all accumulated usage and resets the meter
+func (m *Meter) Drain() []Record {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	records := make([]Record, 0, len(m.buckets))
+	for _, record := range m.buckets {
+		records = append(records, *record)
+	}
+	m.buckets = make(map[Key]*Record)
+	return records
+}
+
+// Restore puts drained usage back, used when a flush fails so nothing is lost
+func (m *Meter) Restore(records []Record) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, record := range records {
+		m.merge(record)
+	}
+}
+
+func (m *Meter) merge(record Record) {
+	existing, ok := m.buckets[record.Key]
+	if !ok {
+		copied := record
+		m.buckets[record.Key] = &copied
+		return
+	}
+
+	if record.Kind == KindGauge {
+		if record.Quantity > existing.Quantity {
+			existing.Quantity = record.Quantity
+		}
+		return
+	}
+	existing.Quantity += record.Quantity
+}
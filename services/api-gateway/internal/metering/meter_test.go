@@ -0,0 +1,164 @@
+package metering
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/api-gateway/internal/auth"
+	"aegisshield/services/api-gateway/internal/config"
+)
+
+var meterEpoch = time.Date(2026, 3, 2, 23, 30, 0, 0, time.UTC)
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// drained returns the meter's records ordered by key
+func drained(m *Meter) []Record {
+	records := m.Drain()
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i].Key, records[j].Key
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.TenantID != b.TenantID {
+			return a.TenantID < b.TenantID
+		}
+		if a.TeamID != b.TeamID {
+			return a.TeamID < b.TeamID
+		}
+		return a.Metric < b.Metric
+	})
+	return records
+}
+
+func TestMeterRecord(t *testing.T) {
+	t.Run("rejects unknown metrics and negative quantities", func(t *testing.T) {
+		m := NewMeter()
+		if err := m.Record("acme", "aml", "page_views", 1, meterEpoch); err == nil {
+			t.Fatal("expected an error for an unknown metric")
+		}
+		if err := m.Record("acme", "aml", MetricAPICalls, -1, meterEpoch); err == nil {
+			t.Fatal("expected an error for a negative quantity")
+		}
+		if records := m.Drain(); len(records) != 0 {
+			t.Fatalf("rejected usage was recorded: %v", records)
+		}
+	})
+
+	t.Run("counters are summed and gauges keep the peak per UTC day", func(t *testing.T) {
+		m := NewMeter()
+		berlin := time.FixedZone("CET", 3600)
+		for _, usage := range []struct {
+			metric   string
+			quantity float64
+			at       time.Time
+		}{
+			{MetricAPICalls, 1, meterEpoch},
+			{MetricAPICalls, 2, meterEpoch.Add(20 * time.Minute)},
+			{MetricAPICalls, 4, meterEpoch.Add(40 * time.Minute)}, // the next UTC day
+			{MetricAPICalls, 8, meterEpoch.In(berlin)},            // still 23:30 UTC
+			{MetricStorageBytes, 500, meterEpoch},
+			{MetricStorageBytes, 900, meterEpoch.Add(10 * time.Minute)},
+			{MetricStorageBytes, 700, meterEpoch.Add(20 * time.Minute)},
+		} {
+			if err := m.Record("acme", "aml", usage.metric, usage.quantity, usage.at); err != nil {
+				t.Fatalf("Record: %v", err)
+			}
+		}
+
+		got := drained(m)
+		want := []Record{
+			{Key: Key{Day: "2026-03-02", TenantID: "acme", TeamID: "aml", Metric: MetricAPICalls}, Kind: KindCounter, Quantity: 11},
+			{Key: Key{Day: "2026-03-02", TenantID: "acme", TeamID: "aml", Metric: MetricStorageBytes}, Kind: KindGauge, Quantity: 900},
+			{Key: Key{Day: "2026-03-03", TenantID: "acme", TeamID: "aml", Metric: MetricAPICalls}, Kind: KindCounter, Quantity: 4},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("record %d: got %+v, want %+v", i, got[i], want[i])
+			}
+		}
+		if records := m.Drain(); len(records) != 0 {
+			t.Fatalf("drain did not reset the meter: %v", records)
+		}
+	})
+
+	t.Run("restored usage merges with usage recorded since the drain", func(t *testing.T) {
+		m := NewMeter()
+		m.Record("acme", "aml", MetricAPICalls, 3, meterEpoch)
+		m.Record("acme", "aml", MetricStorageBytes, 900, meterEpoch)
+		failed := m.Drain()
+
+		m.Record("acme", "aml", MetricAPICalls, 2, meterEpoch)
+		m.Record("acme", "aml", MetricStorageBytes, 400, meterEpoch)
+		m.Restore(failed)
+
+		got := drained(m)
+		if len(got) != 2 || got[0].Quantity != 5 || got[1].Quantity != 900 {
+			t.Fatalf("got %+v, want 5 calls and a 900 byte peak", got)
+		}
+	})
+}
+
+func TestAttributionResolve(t *testing.T) {
+	attribution := Attribution{DefaultTenant: "default", DefaultTeam: "unassigned"}
+
+	for _, tt := range []struct {
+		user         auth.User
+		tenant, team string
+	}{
+		{auth.User{TenantID: "acme", TeamID: "aml"}, "acme", "aml"},
+		{auth.User{TenantID: "acme"}, "acme", "unassigned"},
+		{auth.User{TeamID: "aml"}, "default", "aml"},
+		{auth.User{}, "default", "unassigned"},
+	} {
+		tenant, team := attribution.Resolve(&tt.user)
+		if tenant != tt.tenant || team != tt.team {
+			t.Errorf("Resolve(%+v) = %s/%s, want %s/%s", tt.user, tenant, team, tt.tenant, tt.team)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	m := NewMeter()
+	authService := auth.NewService(config.AuthConfig{}, nil)
+	handler := Middleware(m, authService, Attribution{DefaultTenant: "default"}, testLogger())(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	call := func(path string, user *auth.User) {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if user != nil {
+			r = r.WithContext(context.WithValue(r.Context(), "user", user))
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	alice := &auth.User{ID: "alice", TeamID: "aml"}
+	call("/api/v1/cases", alice)
+	call("/api/v1/cases/42", alice)
+	call("/health", alice)
+	call("/metrics", alice)
+	call("/api/v1/cases", nil)
+
+	records := m.Drain()
+	if len(records) != 1 {
+		t.Fatalf("got %v, want one bucket", records)
+	}
+	record := records[0]
+	if record.TenantID != "default" || record.TeamID != "aml" || record.Metric != MetricAPICalls || record.Quantity != 2 {
+		t.Fatalf("got %+v, want two API calls charged to default/aml", record)
+	}
+}
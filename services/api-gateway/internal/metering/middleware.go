@@ -0,0 +1,61 @@
+package metering
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/api-gateway/internal/auth"
+)
+
+// unmeteredPaths are infrastructure endpoints that are not billed
+var unmeteredPaths = map[string]bool{
+	"/health":  true,
+	"/ready":   true,
+	"/metrics": true,
+	"/":        true,
+}
+
+// Middleware counts authenticated API calls per tenant and team. It must be
+// registered after the auth middleware so the caller is in the context.
+func Middleware(meter *Meter, authService *auth.Service, attribution Attribution, logger *logrus.Logger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+
+			if unmeteredPaths[r.URL.Path] {
+				return
+			}
+
+			user, err := authService.GetUserFromContext(r.Context())
+			if err != nil {
+				return
+			}
+
+			tenantID, teamID := attribution.Resolve(user)
+			if err := meter.Record(tenantID, teamID, MetricAPICalls, 1, time.Now()); err != nil {
+				logger.WithError(err).Warn("Failed to meter API call")
+			}
+		})
+	}
+}
+
+// Attribution maps callers without tenant or team claims to default buckets
+type Attribution struct {
+	DefaultTenant string
+	DefaultTeam   string
+}
+
+// Resolve returns the tenant and team usage is charged to
+func (a Attribution) Resolve(user *auth.User) (tenantID, teamID string) {
+	tenantID, teamID = user.TenantID, user.TeamID
+	if tenantID == "" {
+		tenantID = a.DefaultTenant
+	}
+	if teamID == "" {
+		teamID = a.DefaultTeam
+	}
+	return tenantID, teamID
+}
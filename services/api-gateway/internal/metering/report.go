@@ -0,0 +1,124 @@
+package metering
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Report groupings
+const (
+	GroupByTenant = "tenant"
+	GroupByTeam   = "team"
+)
+
+// Summary is the usage of one tenant (or team) and metric over a period.
+// Counters report their total. Gauges report the average of the daily peaks,
+// which is what storage is billed on, together with the highest peak.
+type Summary struct {
+	TenantID string  `json:"tenant_id"`
+	TeamID   string  `json:"team_id,omitempty"`
+	Metric   string  `json:"metric"`
+	Kind     string  `json:"kind"`
+	Quantity float64 `json:"quantity"`
+	Peak     float64 `json:"peak,omitempty"`
+	Days     int     `json:"days"`
+}
+
+// Summarize rolls daily usage up per tenant or per tenant and team
+func Summarize(usage []DailyUsage, groupBy string) []Summary {
+	type groupKey struct {
+		tenantID string
+		teamID   string
+		metric   string
+	}
+	type accumulator struct {
+		kind  string
+		total float64
+		peak  float64
+		days  map[string]float64
+	}
+
+	groups := make(map[groupKey]*accumulator)
+	for _, row := range usage {
+		key := groupKey{tenantID: row.TenantID, metric: row.Metric}
+		if groupBy == GroupByTeam {
+			key.teamID = row.TeamID
+		}
+
+		acc, ok := groups[key]
+		if !ok {
+			acc = &accumulator{kind: row.Kind, days: make(map[string]float64)}
+			groups[key] = acc
+		}
+
+		// When grouping by tenant, same-day gauge peaks of its teams are summed
+		acc.days[row.Date] += row.Quantity
+		if row.Kind != KindGauge {
+			acc.total += row.Quantity
+		}
+	}
+
+	summaries := make([]Summary, 0, len(groups))
+	for key, acc := range groups {
+		summary := Summary{
+			TenantID: key.tenantID,
+			TeamID:   key.teamID,
+			Metric:   key.metric,
+			Kind:     acc.kind,
+			Quantity: acc.total,
+			Days:     len(acc.days),
+		}
+		if acc.kind == KindGauge {
+			sum := 0.0
+			for _, quantity := range acc.days {
+				sum += quantity
+				if quantity > summary.Peak {
+					summary.Peak = quantity
+				}
+			}
+			summary.Quantity = sum / float64(len(acc.days))
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if a.TenantID != b.TenantID {
+			return a.TenantID < b.TenantID
+		}
+		if a.TeamID != b.TeamID {
+			return a.TeamID < b.TeamID
+		}
+		return a.Metric < b.Metric
+	})
+	return summaries
+}
+
+var csvHeader = []string{"date", "tenant_id", "team_id", "metric", "kind", "quantity"}
+
+// WriteCSV writes daily usage rows as CSV with a header row
+func WriteCSV(w io.Writer, usage []DailyUsage) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, row := range usage {
+		record := []string{
+			row.Date,
+			row.TenantID,
+			row.TeamID,
+			row.Metric,
+			row.Kind,
+			strconv.FormatFloat(row.Quantity, 'f', -1, 64),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,66 @@
+package metering
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	usage := []DailyUsage{
+		{Date: "2026-03-01", TenantID: "acme", TeamID: "aml", Metric: MetricAPICalls, Kind: KindCounter, Quantity: 100},
+		{Date: "2026-03-02", TenantID: "acme", TeamID: "aml", Metric: MetricAPICalls, Kind: KindCounter, Quantity: 50},
+		{Date: "2026-03-01", TenantID: "acme", TeamID: "fraud", Metric: MetricAPICalls, Kind: KindCounter, Quantity: 25},
+		{Date: "2026-03-01", TenantID: "acme", TeamID: "aml", Metric: MetricStorageBytes, Kind: KindGauge, Quantity: 1000},
+		{Date: "2026-03-02", TenantID: "acme", TeamID: "aml", Metric: MetricStorageBytes, Kind: KindGauge, Quantity: 3000},
+		{Date: "2026-03-02", TenantID: "acme", TeamID: "fraud", Metric: MetricStorageBytes, Kind: KindGauge, Quantity: 1000},
+		{Date: "2026-03-01", TenantID: "globex", TeamID: "aml", Metric: MetricAPICalls, Kind: KindCounter, Quantity: 7},
+	}
+
+	t.Run("by tenant", func(t *testing.T) {
+		want := []Summary{
+			{TenantID: "acme", Metric: MetricAPICalls, Kind: KindCounter, Quantity: 175, Days: 2},
+			// Team peaks on the same day add up: 1000 on the 1st, 4000 on the 2nd
+			{TenantID: "acme", Metric: MetricStorageBytes, Kind: KindGauge, Quantity: 2500, Peak: 4000, Days: 2},
+			{TenantID: "globex", Metric: MetricAPICalls, Kind: KindCounter, Quantity: 7, Days: 1},
+		}
+		if got := Summarize(usage, GroupByTenant); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("by team", func(t *testing.T) {
+		want := []Summary{
+			{TenantID: "acme", TeamID: "aml", Metric: MetricAPICalls, Kind: KindCounter, Quantity: 150, Days: 2},
+			{TenantID: "acme", TeamID: "aml", Metric: MetricStorageBytes, Kind: KindGauge, Quantity: 2000, Peak: 3000, Days: 2},
+			{TenantID: "acme", TeamID: "fraud", Metric: MetricAPICalls, Kind: KindCounter, Quantity: 25, Days: 1},
+			{TenantID: "acme", TeamID: "fraud", Metric: MetricStorageBytes, Kind: KindGauge, Quantity: 1000, Peak: 1000, Days: 1},
+			{TenantID: "globex", TeamID: "aml", Metric: MetricAPICalls, Kind: KindCounter, Quantity: 7, Days: 1},
+		}
+		if got := Summarize(usage, GroupByTeam); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("no usage", func(t *testing.T) {
+		if got := Summarize(nil, GroupByTenant); len(got) != 0 {
+			t.Fatalf("got %+v, want none", got)
+		}
+	})
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []DailyUsage{
+		{Date: "2026-03-01", TenantID: "acme", TeamID: "aml, fraud", Metric: MetricStorageBytes, Kind: KindGauge, Quantity: 1.5e9},
+	})
+	if err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	want := "date,tenant_id,team_id,metric,kind,quantity\n" +
+		"2026-03-01,acme,\"aml, fraud\",storage_bytes,gauge,1500000000\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
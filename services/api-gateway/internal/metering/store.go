@@ -0,0 +1,146 @@
+package metering
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS usage_daily (
+	usage_date DATE NOT NULL,
+	tenant_id VARCHAR(255) NOT NULL,
+	team_id VARCHAR(255) NOT NULL,
+	metric VARCHAR(100) NOT NULL,
+	kind VARCHAR(20) NOT NULL,
+	quantity DOUBLE PRECISION NOT NULL DEFAULT 0,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (usage_date, tenant_id, team_id, metric)
+);
+CREATE INDEX IF NOT EXISTS idx_usage_daily_tenant ON usage_daily (tenant_id, usage_date);
+`
+
+// DailyUsage is one persisted day of usage for a tenant, team and metric
+type DailyUsage struct {
+	Date     string  `json:"date"`
+	TenantID string  `json:"tenant_id"`
+	TeamID   string  `json:"team_id"`
+	Metric   string  `json:"metric"`
+	Kind     string  `json:"kind"`
+	Quantity float64 `json:"quantity"`
+}
+
+// Filter selects persisted usage
+type Filter struct {
+	From     time.Time
+	To       time.Time
+	TenantID string
+	TeamID   string
+	Metrics  []string
+}
+
+// Store persists daily usage in PostgreSQL
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates a new usage store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// EnsureSchema creates the usage table if it does not exist
+func (s *Store) EnsureSchema(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, schema); err != nil {
+		return fmt.Errorf("failed to create usage schema: %w", err)
+	}
+	return nil
+}
+
+// Save merges records into their daily rows. Counters are added to the
+// stored total and gauges keep the highest value seen that day, so flushing
+// the same day several times is safe.
+func (s *Store) Save(ctx context.Context, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin usage transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO usage_daily (usage_date, tenant_id, team_id, metric, kind, quantity, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (usage_date, tenant_id, team_id, metric) DO UPDATE SET
+			quantity = CASE WHEN EXCLUDED.kind = 'gauge'
+				THEN GREATEST(usage_daily.quantity, EXCLUDED.quantity)
+				ELSE usage_daily.quantity + EXCLUDED.quantity END,
+			updated_at = NOW()
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare usage upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, record := range records {
+		if _, err := stmt.ExecContext(ctx, record.Day, record.TenantID, record.TeamID,
+			record.Metric, record.Kind, record.Quantity); err != nil {
+			return fmt.Errorf("failed to save usage for %s/%s: %w", record.TenantID, record.Metric, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit usage: %w", err)
+	}
+	return nil
+}
+
+// Daily returns persisted usage rows ordered by date, tenant, team and metric
+func (s *Store) Daily(ctx context.Context, filter Filter) ([]DailyUsage, error) {
+	conditions := []string{"usage_date >= $1", "usage_date <= $2"}
+	args := []interface{}{filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02")}
+
+	if filter.TenantID != "" {
+		args = append(args, filter.TenantID)
+		conditions = append(conditions, fmt.Sprintf("tenant_id = $%d", len(args)))
+	}
+	if filter.TeamID != "" {
+		args = append(args, filter.TeamID)
+		conditions = append(conditions, fmt.Sprintf("team_id = $%d", len(args)))
+	}
+	if len(filter.Metrics) > 0 {
+		placeholders := make([]string, 0, len(filter.Metrics))
+		for _, metric := range filter.Metrics {
+			args = append(args, metric)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		conditions = append(conditions, fmt.Sprintf("metric IN (%s)", strings.Join(placeholders, ", ")))
+	}
+
+	query := `
+		SELECT to_char(usage_date, 'YYYY-MM-DD'), tenant_id, team_id, metric, kind, quantity
+		FROM usage_daily
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY usage_date, tenant_id, team_id, metric`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []DailyUsage
+	for rows.Next() {
+		var row DailyUsage
+		if err := rows.Scan(&row.Date, &row.TenantID, &row.TeamID, &row.Metric, &row.Kind, &row.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		usage = append(usage, row)
+	}
+	return usage, rows.Err()
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -9,9 +10,9 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	"github.com/sirupsen/logrus"
 
 	"aegisshield/services/api-gateway/internal/auth"
+	"aegisshield/shared/rbac"
 )
 
 var (
@@ -32,34 +33,6 @@ var (
 	)
 )
 
-// LoggingMiddleware logs HTTP requests
-func LoggingMiddleware(logger *logrus.Logger) mux.MiddlewareFunc {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-
-			// Create a custom response writer to capture status code
-			rw := &responseWriter{
-				ResponseWriter: w,
-				statusCode:     200,
-			}
-
-			// Call next handler
-			next.ServeHTTP(rw, r)
-
-			// Log request details
-			logger.WithFields(logrus.Fields{
-				"method":      r.Method,
-				"path":        r.URL.Path,
-				"remote_addr": r.RemoteAddr,
-				"user_agent":  r.UserAgent(),
-				"status":      rw.statusCode,
-				"duration":    time.Since(start),
-			}).Info("HTTP request")
-		})
-	}
-}
-
 // MetricsMiddleware collects HTTP metrics
 func MetricsMiddleware() mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
@@ -87,8 +60,40 @@ func MetricsMiddleware() mux.MiddlewareFunc {
 	}
 }
 
+// DeadlineMiddleware gives each request a deadline that backend calls derive
+// their own deadlines from. Clients may ask for a different one with the
+// X-Request-Timeout header (a duration such as 1500ms), capped at maxTimeout.
+func DeadlineMiddleware(defaultTimeout, maxTimeout time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := defaultTimeout
+			if header := r.Header.Get("X-Request-Timeout"); header != "" {
+				requested, err := time.ParseDuration(header)
+				if err != nil || requested <= 0 {
+					http.Error(w, "Invalid X-Request-Timeout header", http.StatusBadRequest)
+					return
+				}
+				timeout = requested
+			}
+			if maxTimeout > 0 && timeout > maxTimeout {
+				timeout = maxTimeout
+			}
+
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 // AuthMiddleware handles JWT authentication
-func AuthMiddleware(authService *auth.Service) mux.MiddlewareFunc {
+func AuthMiddleware(authService *auth.Service, apiKeys rbac.Authenticator) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Skip auth for health checks and playground
@@ -97,6 +102,30 @@ func AuthMiddleware(authService *auth.Service) mux.MiddlewareFunc {
 				return
 			}
 
+			// Service accounts authenticate with an API key instead of a token
+			if key := r.Header.Get(rbac.APIKeyHeader); key != "" && apiKeys != nil {
+				subject, err := apiKeys.Authenticate(r.Context(), key)
+				if err != nil {
+					if errors.Is(err, rbac.ErrInvalidToken) {
+						http.Error(w, "Invalid API key", http.StatusUnauthorized)
+						return
+					}
+					http.Error(w, "API key validation failed", http.StatusServiceUnavailable)
+					return
+				}
+
+				// The key's scopes are its only permissions
+				user := &auth.User{
+					ID:          subject.ID,
+					Roles:       subject.Roles,
+					Permissions: subject.Permissions,
+				}
+				ctx := context.WithValue(r.Context(), "user", user)
+				ctx = rbac.WithSubject(ctx, subject)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			// Extract token from Authorization header
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
@@ -122,13 +151,17 @@ func AuthMiddleware(authService *auth.Service) mux.MiddlewareFunc {
 
 			// Create user from claims
 			user := &auth.User{
-				ID:    claims.UserID,
-				Email: claims.Email,
-				Roles: claims.Roles,
+				ID:          claims.UserID,
+				Email:       claims.Email,
+				Roles:       claims.Roles,
+				Permissions: claims.Permissions,
+				TenantID:    claims.TenantID,
+				TeamID:      claims.TeamID,
 			}
 
-			// Add user to context
+			// Add user to context, and its RBAC subject for permission checks
 			ctx := context.WithValue(r.Context(), "user", user)
+			ctx = rbac.WithSubject(ctx, user.Subject())
 			r = r.WithContext(ctx)
 
 			next.ServeHTTP(w, r)
@@ -149,4 +182,4 @@ func (rw *responseWriter) WriteHeader(code int) {
 
 func (rw *responseWriter) Write(b []byte) (int, error) {
 	return rw.ResponseWriter.Write(b)
-}
\ No newline at end of file
+}
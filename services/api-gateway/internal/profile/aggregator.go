@@ -0,0 +1,216 @@
+package profile
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Profile sections assembled from the backend services
+const (
+	SectionIdentity       = "identity"
+	SectionRisk           = "risk"
+	SectionAlerts         = "alerts"
+	SectionCases          = "cases"
+	SectionScreening      = "screening"
+	SectionCounterparties = "counterparties"
+)
+
+// Profile is everything the case UI needs to render one entity page
+type Profile struct {
+	EntityID       string         `json:"entity_id"`
+	Identity       Identity       `json:"identity"`
+	Risk           Risk           `json:"risk"`
+	RecentAlerts   []Alert        `json:"recent_alerts"`
+	OpenCases      []Case         `json:"open_cases"`
+	ScreeningHits  []ScreeningHit `json:"screening_hits"`
+	Counterparties []Counterparty `json:"top_counterparties"`
+	Sources        []SourceStatus `json:"sources"`
+	Partial        bool           `json:"partial"`
+	GeneratedAt    time.Time      `json:"generated_at"`
+}
+
+// Identity holds the entity's identifying attributes
+type Identity struct {
+	Type       string                 `json:"type,omitempty"`
+	Name       string                 `json:"name,omitempty"`
+	Aliases    []Alias                `json:"aliases,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// Alias is an alternative name recorded by entity resolution
+type Alias struct {
+	Name      string `json:"name"`
+	AliasType string `json:"alias_type,omitempty"`
+}
+
+// Risk combines the entity's compliance risk score with its network position
+type Risk struct {
+	Score   *float64        `json:"score,omitempty"`
+	Network *NetworkMetrics `json:"network,omitempty"`
+}
+
+// NetworkMetrics are the graph centrality measures of the entity
+type NetworkMetrics struct {
+	DegreeCentrality      float64   `json:"degree_centrality"`
+	BetweennessCentrality float64   `json:"betweenness_centrality"`
+	PageRank              float64   `json:"page_rank"`
+	CommunityID           string    `json:"community_id,omitempty"`
+	CalculatedAt          time.Time `json:"calculated_at"`
+}
+
+// Alert is a recent alert raised against the entity
+type Alert struct {
+	ID        string    `json:"id"`
+	RuleName  string    `json:"rule_name,omitempty"`
+	Title     string    `json:"title"`
+	Severity  string    `json:"severity"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Case is an open investigation involving the entity
+type Case struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	CaseType   string     `json:"case_type"`
+	Priority   string     `json:"priority"`
+	Status     string     `json:"status"`
+	AssignedTo *string    `json:"assigned_to,omitempty"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ScreeningHit is an open compliance screening violation against the entity
+type ScreeningHit struct {
+	ID          string    `json:"id"`
+	RuleID      string    `json:"rule_id"`
+	RuleName    string    `json:"rule_name"`
+	Severity    string    `json:"severity"`
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	RiskScore   float64   `json:"risk_score"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Counterparty is an entity directly connected to the profiled entity
+type Counterparty struct {
+	EntityID          string   `json:"entity_id"`
+	Type              string   `json:"type,omitempty"`
+	Name              string   `json:"name,omitempty"`
+	RelationshipTypes []string `json:"relationship_types"`
+	Relationships     int      `json:"relationships"`
+	TotalAmount       float64  `json:"total_amount"`
+}
+
+// Limits caps the size of the list sections
+type Limits struct {
+	RecentAlerts   int
+	OpenCases      int
+	ScreeningHits  int
+	Counterparties int
+	Scan           int // items requested from backends that cannot filter by entity
+}
+
+// Contribution fills the profile sections a source is responsible for
+type Contribution func(profile *Profile)
+
+// Source fetches part of an entity profile from a single service
+type Source interface {
+	Name() string
+	Sections() []string
+	Fetch(ctx context.Context, entityID string, limits Limits) (Contribution, error)
+}
+
+// SourceStatus reports how a single source answered
+type SourceStatus struct {
+	Service  string   `json:"service"`
+	Sections []string `json:"sections"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Aggregator assembles entity profiles from every source in parallel
+type Aggregator struct {
+	sources []Source
+	timeout time.Duration
+	limits  Limits
+	logger  *logrus.Logger
+}
+
+// NewAggregator creates a new entity profile aggregator
+func NewAggregator(sources []Source, timeout time.Duration, limits Limits, logger *logrus.Logger) *Aggregator {
+	return &Aggregator{
+		sources: sources,
+		timeout: timeout,
+		limits:  limits,
+		logger:  logger,
+	}
+}
+
+// Build fetches every section concurrently. A failing source leaves its
+// sections empty and marks the profile partial instead of failing the request.
+func (a *Aggregator) Build(ctx context.Context, entityID string) *Profile {
+	statuses := make([]SourceStatus, len(a.sources))
+	contributions := make([]Contribution, len(a.sources))
+
+	var wg sync.WaitGroup
+	for i, source := range a.sources {
+		wg.Add(1)
+		go func(i int, source Source) {
+			defer wg.Done()
+
+			sourceCtx, cancel := context.WithTimeout(ctx, a.timeout)
+			defer cancel()
+
+			contribution, err := source.Fetch(sourceCtx, entityID, a.limits)
+			statuses[i] = SourceStatus{Service: source.Name(), Sections: source.Sections()}
+			if err != nil {
+				a.logger.WithError(err).WithFields(logrus.Fields{
+					"service":   source.Name(),
+					"entity_id": entityID,
+				}).Warn("Entity profile source failed")
+				statuses[i].Error = err.Error()
+				return
+			}
+			contributions[i] = contribution
+		}(i, source)
+	}
+	wg.Wait()
+
+	profile := &Profile{
+		EntityID:       entityID,
+		RecentAlerts:   []Alert{},
+		OpenCases:      []Case{},
+		ScreeningHits:  []ScreeningHit{},
+		Counterparties: []Counterparty{},
+		Sources:        statuses,
+		GeneratedAt:    time.Now().UTC(),
+	}
+
+	// Contributions are applied in source order once every fetch has returned,
+	// so sources sharing a section never race each other
+	for _, contribution := range contributions {
+		if contribution != nil {
+			contribution(profile)
+		}
+	}
+	for _, status := range statuses {
+		if status.Error != "" {
+			profile.Partial = true
+		}
+	}
+
+	return profile
+}
+
+// Failed reports whether no source answered at all
+func (p *Profile) Failed() bool {
+	for _, status := range p.Sources {
+		if status.Error == "" {
+			return false
+		}
+	}
+	return len(p.Sources) > 0
+}
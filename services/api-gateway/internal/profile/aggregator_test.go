@@ -0,0 +1,126 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// stubSource contributes a fixed change to the profile, or fails
+type stubSource struct {
+	name       string
+	sections   []string
+	contribute Contribution
+	err        error
+	delay      time.Duration
+}
+
+func (s *stubSource) Name() string {
+	return s.name
+}
+
+func (s *stubSource) Sections() []string {
+	return s.sections
+}
+
+func (s *stubSource) Fetch(ctx context.Context, entityID string, limits Limits) (Contribution, error) {
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.contribute, nil
+}
+
+func testAggregator(sources ...Source) *Aggregator {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewAggregator(sources, 50*time.Millisecond, Limits{}, logger)
+}
+
+func identitySource(name string) *stubSource {
+	return &stubSource{
+		name:     ServiceGraphEngine,
+		sections: []string{SectionIdentity},
+		contribute: func(profile *Profile) {
+			profile.Identity.Name = name
+		},
+	}
+}
+
+func alertsSource(err error) *stubSource {
+	return &stubSource{
+		name:     ServiceAlertingEngine,
+		sections: []string{SectionAlerts},
+		err:      err,
+		contribute: func(profile *Profile) {
+			profile.RecentAlerts = []Alert{{ID: "alert-1"}}
+		},
+	}
+}
+
+func TestAggregatorBuild(t *testing.T) {
+	t.Run("every source answered", func(t *testing.T) {
+		profile := testAggregator(identitySource("Acme Ltd"), alertsSource(nil)).Build(context.Background(), "acme")
+
+		if profile.Partial || profile.Failed() {
+			t.Fatalf("got partial %v, failed %v for a complete profile", profile.Partial, profile.Failed())
+		}
+		if profile.EntityID != "acme" || profile.Identity.Name != "Acme Ltd" || len(profile.RecentAlerts) != 1 {
+			t.Fatalf("got %+v, want identity and alerts filled in", profile)
+		}
+	})
+
+	t.Run("a failing source leaves its sections empty", func(t *testing.T) {
+		profile := testAggregator(identitySource("Acme Ltd"), alertsSource(errors.New("connection refused"))).Build(context.Background(), "acme")
+
+		if !profile.Partial || profile.Failed() {
+			t.Fatalf("got partial %v, failed %v, want a partial profile", profile.Partial, profile.Failed())
+		}
+		if profile.RecentAlerts == nil || len(profile.RecentAlerts) != 0 {
+			t.Fatalf("got alerts %v, want an empty list", profile.RecentAlerts)
+		}
+		if status := profile.Sources[1]; status.Service != ServiceAlertingEngine || status.Error != "connection refused" {
+			t.Fatalf("got status %+v, want the alerting engine error", status)
+		}
+		if profile.Sources[0].Error != "" {
+			t.Fatalf("got status %+v, want the graph engine to have answered", profile.Sources[0])
+		}
+	})
+
+	t.Run("a slow source times out", func(t *testing.T) {
+		slow := identitySource("Acme Ltd")
+		slow.delay = time.Second
+
+		profile := testAggregator(slow, alertsSource(nil)).Build(context.Background(), "acme")
+		if !profile.Partial || profile.Identity.Name != "" || len(profile.RecentAlerts) != 1 {
+			t.Fatalf("got %+v, want only the alerts section", profile)
+		}
+	})
+
+	t.Run("no source answered", func(t *testing.T) {
+		down := errors.New("unavailable")
+		identity := identitySource("Acme Ltd")
+		identity.err = down
+
+		if profile := testAggregator(identity, alertsSource(down)).Build(context.Background(), "acme"); !profile.Failed() {
+			t.Fatal("expected the profile to have failed")
+		}
+	})
+
+	t.Run("sources sharing a section apply in order", func(t *testing.T) {
+		profile := testAggregator(identitySource("first"), identitySource("second")).Build(context.Background(), "acme")
+		if profile.Identity.Name != "second" {
+			t.Fatalf("got %s, want the later source to win", profile.Identity.Name)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package profile
+
+import (
+	"sort"
+)
+
+// SubGraph is the graph-engine neighborhood of an entity
+type SubGraph struct {
+	Entities      []*GraphEntity       `json:"entities"`
+	Relationships []*GraphRelationship `json:"relationships"`
+}
+
+// GraphEntity is a node of the neighborhood
+type GraphEntity struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// GraphRelationship is an edge of the neighborhood
+type GraphRelationship struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	SourceID   string                 `json:"source_id"`
+	TargetID   string                 `json:"target_id"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+func (g *SubGraph) entity(id string) *GraphEntity {
+	for _, entity := range g.Entities {
+		if entity != nil && entity.ID == id {
+			return entity
+		}
+	}
+	return nil
+}
+
+// TopCounterparties ranks the entities directly related to entityID by the
+// total amount flowing between them, then by the number of relationships
+func TopCounterparties(entityID string, graph *SubGraph, limit int) []Counterparty {
+	byID := make(map[string]*Counterparty)
+	types := make(map[string]map[string]bool)
+
+	for _, rel := range graph.Relationships {
+		if rel == nil {
+			continue
+		}
+
+		var otherID string
+		switch entityID {
+		case rel.SourceID:
+			otherID = rel.TargetID
+		case rel.TargetID:
+			otherID = rel.SourceID
+		default:
+			continue
+		}
+		if otherID == "" || otherID == entityID {
+			continue
+		}
+
+		counterparty, ok := byID[otherID]
+		if !ok {
+			counterparty = &Counterparty{EntityID: otherID}
+			if other := graph.entity(otherID); other != nil {
+				counterparty.Type = other.Type
+				counterparty.Name = stringProperty(other.Properties, "name")
+			}
+			byID[otherID] = counterparty
+			types[otherID] = make(map[string]bool)
+		}
+
+		counterparty.Relationships++
+		counterparty.TotalAmount += floatProperty(rel.Properties, "amount")
+		if rel.Type != "" && !types[otherID][rel.Type] {
+			types[otherID][rel.Type] = true
+			counterparty.RelationshipTypes = append(counterparty.RelationshipTypes, rel.Type)
+		}
+	}
+
+	counterparties := make([]Counterparty, 0, len(byID))
+	for _, counterparty := range byID {
+		sort.Strings(counterparty.RelationshipTypes)
+		if counterparty.RelationshipTypes == nil {
+			counterparty.RelationshipTypes = []string{}
+		}
+		counterparties = append(counterparties, *counterparty)
+	}
+
+	sort.Slice(counterparties, func(i, j int) bool {
+		a, b := counterparties[i], counterparties[j]
+		if a.TotalAmount != b.TotalAmount {
+			return a.TotalAmount > b.TotalAmount
+		}
+		if a.Relationships != b.Relationships {
+			return a.Relationships > b.Relationships
+		}
+		return a.EntityID < b.EntityID
+	})
+
+	if limit > 0 && len(counterparties) > limit {
+		counterparties = counterparties[:limit]
+	}
+	return counterparties
+}
+
+func stringProperty(properties map[string]interface{}, key string) string {
+	if value, ok := properties[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+func floatProperty(properties map[string]interface{}, key string) float64 {
+	switch value := properties[key].(type) {
+	case float64:
+		return value
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	}
+	return 0
+}
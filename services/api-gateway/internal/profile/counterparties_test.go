@@ -0,0 +1,56 @@
+package profile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTopCounterparties(t *testing.T) {
+	graph := &SubGraph{
+		Entities: []*GraphEntity{
+			{ID: "acme", Type: "organization", Properties: map[string]interface{}{"name": "Acme Ltd"}},
+			{ID: "bob", Type: "person", Properties: map[string]interface{}{"name": "Bob"}},
+			{ID: "shell", Type: "organization"},
+		},
+		Relationships: []*GraphRelationship{
+			{Type: "TRANSACTS_WITH", SourceID: "acme", TargetID: "shell", Properties: map[string]interface{}{"amount": 5000.0}},
+			{Type: "TRANSACTS_WITH", SourceID: "shell", TargetID: "acme", Properties: map[string]interface{}{"amount": 2500}},
+			{Type: "OWNS", SourceID: "bob", TargetID: "acme"},
+			{Type: "DIRECTOR_OF", SourceID: "bob", TargetID: "acme"},
+			{Type: "OWNS", SourceID: "bob", TargetID: "acme"},
+			{Type: "TRANSACTS_WITH", SourceID: "acme", TargetID: "carol", Properties: map[string]interface{}{"amount": int64(7500)}},
+			{Type: "TRANSACTS_WITH", SourceID: "acme", TargetID: "dave"},
+			// Relationships not touching the entity, self loops and gaps are ignored
+			{Type: "TRANSACTS_WITH", SourceID: "bob", TargetID: "shell", Properties: map[string]interface{}{"amount": 1e6}},
+			{Type: "TRANSACTS_WITH", SourceID: "acme", TargetID: "acme", Properties: map[string]interface{}{"amount": 1e6}},
+			nil,
+		},
+	}
+
+	// shell and carol moved the same amount; shell has more relationships
+	want := []Counterparty{
+		{EntityID: "shell", Type: "organization", RelationshipTypes: []string{"TRANSACTS_WITH"}, Relationships: 2, TotalAmount: 7500},
+		{EntityID: "carol", RelationshipTypes: []string{"TRANSACTS_WITH"}, Relationships: 1, TotalAmount: 7500},
+		{EntityID: "bob", Type: "person", Name: "Bob", RelationshipTypes: []string{"DIRECTOR_OF", "OWNS"}, Relationships: 3},
+		{EntityID: "dave", RelationshipTypes: []string{"TRANSACTS_WITH"}, Relationships: 1},
+	}
+
+	t.Run("ranked by amount, then relationships", func(t *testing.T) {
+		if got := TopCounterparties("acme", graph, 0); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		got := TopCounterparties("acme", graph, 2)
+		if len(got) != 2 || got[0].EntityID != "shell" || got[1].EntityID != "carol" {
+			t.Fatalf("got %+v, want shell and carol", got)
+		}
+	})
+
+	t.Run("unconnected entity", func(t *testing.T) {
+		if got := TopCounterparties("nobody", graph, 5); len(got) != 0 {
+			t.Fatalf("got %+v, want none", got)
+		}
+	})
+}
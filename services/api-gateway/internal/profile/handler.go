@@ -0,0 +1,86 @@
+package profile
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/api-gateway/internal/auth"
+	"aegisshield/shared/rbac"
+)
+
+// Handler serves aggregated entity profiles
+type Handler struct {
+	aggregator  *Aggregator
+	authService *auth.Service
+	logger      *logrus.Logger
+}
+
+// NewHandler creates a new entity profile handler
+func NewHandler(aggregator *Aggregator, authService *auth.Service, logger *logrus.Logger) *Handler {
+	return &Handler{
+		aggregator:  aggregator,
+		authService: authService,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registers entity profile routes
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/entities/{id}/profile", h.handleProfile).Methods("GET")
+}
+
+func (h *Handler) handleProfile(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	entityID := strings.TrimSpace(mux.Vars(r)["id"])
+	if entityID == "" {
+		writeError(w, http.StatusBadRequest, "entity id is required")
+		return
+	}
+
+	profile := h.aggregator.Build(r.Context(), entityID)
+
+	status := http.StatusOK
+	if profile.Failed() {
+		status = http.StatusBadGateway
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if profile.Partial {
+		w.Header().Set("X-Profile-Partial", "true")
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(profile)
+}
+
+// authorize rejects callers without the entities:read permission
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	user, err := h.authService.GetUserFromContext(r.Context())
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return false
+	}
+	allowed, err := h.authService.Authorize(r.Context(), user, "entities", rbac.ActionRead)
+	if err != nil {
+		h.logger.WithError(err).Error("Entity profile permission check failed")
+		writeError(w, http.StatusServiceUnavailable, "permission check failed")
+		return false
+	}
+	if !allowed {
+		writeError(w, http.StatusForbidden, "insufficient permissions to view entity profiles")
+		return false
+	}
+	return true
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,76 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/api-gateway/internal/auth"
+	"aegisshield/services/api-gateway/internal/config"
+	"aegisshield/shared/rbac"
+)
+
+// checkerFunc adapts a function to rbac.Checker
+type checkerFunc func(resource, action string) (bool, error)
+
+func (f checkerFunc) CheckPermission(ctx context.Context, subject *rbac.Subject, resource, action string) (rbac.Decision, error) {
+	allowed, err := f(resource, action)
+	return rbac.Decision{Allowed: allowed}, err
+}
+
+func canReadEntities(resource, action string) (bool, error) {
+	return resource == "entities" && action == rbac.ActionRead, nil
+}
+
+func serveProfile(checker rbac.Checker, aggregator *Aggregator, user *auth.User) *httptest.ResponseRecorder {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	router := mux.NewRouter()
+	NewHandler(aggregator, auth.NewService(config.AuthConfig{}, checker), logger).RegisterRoutes(router)
+
+	r := httptest.NewRequest(http.MethodGet, "/entities/acme/profile", nil)
+	if user != nil {
+		r = r.WithContext(context.WithValue(r.Context(), "user", user))
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestHandleProfile(t *testing.T) {
+	analyst := &auth.User{ID: "alice", Roles: []string{auth.RoleAnalyst}}
+	down := errors.New("unavailable")
+
+	for _, tt := range []struct {
+		name    string
+		checker rbac.Checker
+		user    *auth.User
+		sources []Source
+		status  int
+		partial bool
+	}{
+		{"unauthenticated callers are rejected", checkerFunc(canReadEntities), nil, nil, http.StatusUnauthorized, false},
+		{"callers without entities:read are rejected", checkerFunc(func(string, string) (bool, error) { return false, nil }), analyst, nil, http.StatusForbidden, false},
+		{"a failing permission check is not an allow", checkerFunc(func(string, string) (bool, error) { return false, down }), analyst, nil, http.StatusServiceUnavailable, false},
+		{"a complete profile", checkerFunc(canReadEntities), analyst, []Source{identitySource("Acme Ltd"), alertsSource(nil)}, http.StatusOK, false},
+		{"a partial profile", checkerFunc(canReadEntities), analyst, []Source{identitySource("Acme Ltd"), alertsSource(down)}, http.StatusOK, true},
+		{"every source failed", checkerFunc(canReadEntities), analyst, []Source{alertsSource(down)}, http.StatusBadGateway, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveProfile(tt.checker, testAggregator(tt.sources...), tt.user)
+			if rec.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if partial := rec.Header().Get("X-Profile-Partial") == "true"; partial != tt.partial {
+				t.Fatalf("got partial header %v, want %v", partial, tt.partial)
+			}
+		})
+	}
+}
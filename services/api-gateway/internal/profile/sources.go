@@ -0,0 +1,350 @@
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Service names reported in profile source statuses
+const (
+	ServiceGraphEngine          = "graph-engine"
+	ServiceEntityResolution     = "entity-resolution"
+	ServiceAlertingEngine       = "alerting-engine"
+	ServiceInvestigationToolkit = "investigation-toolkit"
+	ServiceComplianceEngine     = "compliance-engine"
+)
+
+// httpSource fetches one part of the profile from a service's REST API
+type httpSource struct {
+	name     string
+	sections []string
+	baseURL  string
+	path     func(entityID string) string
+	client   *http.Client
+	params   func(entityID string, limits Limits) url.Values
+	decode   func(body *json.Decoder, entityID string, limits Limits) (Contribution, error)
+}
+
+func (s *httpSource) Name() string {
+	return s.name
+}
+
+func (s *httpSource) Sections() []string {
+	return s.sections
+}
+
+func (s *httpSource) Fetch(ctx context.Context, entityID string, limits Limits) (Contribution, error) {
+	endpoint := strings.TrimRight(s.baseURL, "/") + s.path(entityID)
+	if s.params != nil {
+		endpoint += "?" + s.params(entityID, limits).Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build profile request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("profile request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("profile request returned status %d", resp.StatusCode)
+	}
+
+	contribution, err := s.decode(json.NewDecoder(resp.Body), entityID, limits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode profile response: %w", err)
+	}
+	return contribution, nil
+}
+
+// NewGraphNeighborhoodSource reads the entity's identity attributes and its
+// top counterparties from the graph-engine neighborhood
+func NewGraphNeighborhoodSource(baseURL string, client *http.Client) Source {
+	return &httpSource{
+		name:     ServiceGraphEngine,
+		sections: []string{SectionIdentity, SectionCounterparties},
+		baseURL:  baseURL,
+		path: func(entityID string) string {
+			return "/api/v1/entities/" + url.PathEscape(entityID) + "/neighborhood"
+		},
+		client: client,
+		decode: func(body *json.Decoder, entityID string, limits Limits) (Contribution, error) {
+			var payload struct {
+				SubGraph *SubGraph `json:"subgraph"`
+			}
+			if err := body.Decode(&payload); err != nil {
+				return nil, err
+			}
+			if payload.SubGraph == nil {
+				payload.SubGraph = &SubGraph{}
+			}
+
+			counterparties := TopCounterparties(entityID, payload.SubGraph, limits.Counterparties)
+			center := payload.SubGraph.entity(entityID)
+			return func(profile *Profile) {
+				if center != nil {
+					profile.Identity.Type = center.Type
+					profile.Identity.Name = stringProperty(center.Properties, "name")
+					profile.Identity.Attributes = center.Properties
+				}
+				profile.Counterparties = counterparties
+			}, nil
+		},
+	}
+}
+
+// NewGraphMetricsSource reads the entity's network centrality from the graph engine
+func NewGraphMetricsSource(baseURL string, client *http.Client) Source {
+	return &httpSource{
+		name:     ServiceGraphEngine,
+		sections: []string{SectionRisk},
+		baseURL:  baseURL,
+		path: func(entityID string) string {
+			return "/api/v1/entities/" + url.PathEscape(entityID) + "/metrics"
+		},
+		client: client,
+		decode: func(body *json.Decoder, entityID string, limits Limits) (Contribution, error) {
+			var metrics NetworkMetrics
+			if err := body.Decode(&metrics); err != nil {
+				return nil, err
+			}
+			return func(profile *Profile) {
+				profile.Risk.Network = &metrics
+			}, nil
+		},
+	}
+}
+
+// NewEntityResolutionSource reads the entity's aliases from entity resolution
+func NewEntityResolutionSource(baseURL string, client *http.Client) Source {
+	return &httpSource{
+		name:     ServiceEntityResolution,
+		sections: []string{SectionIdentity},
+		baseURL:  baseURL,
+		path: func(entityID string) string {
+			return "/api/v1/entities/" + url.PathEscape(entityID) + "/aliases"
+		},
+		client: client,
+		decode: func(body *json.Decoder, entityID string, limits Limits) (Contribution, error) {
+			var payload struct {
+				Aliases []struct {
+					Alias     string `json:"alias"`
+					AliasType string `json:"alias_type"`
+				} `json:"aliases"`
+			}
+			if err := body.Decode(&payload); err != nil {
+				return nil, err
+			}
+
+			aliases := make([]Alias, 0, len(payload.Aliases))
+			for _, alias := range payload.Aliases {
+				aliases = append(aliases, Alias{Name: alias.Alias, AliasType: alias.AliasType})
+			}
+			return func(profile *Profile) {
+				profile.Identity.Aliases = aliases
+			}, nil
+		},
+	}
+}
+
+// NewAlertingEngineSource reads the entity's most recent alerts. The alert
+// list cannot filter on entities, so a window of recent alerts is scanned
+// and matched on entity_ids here.
+func NewAlertingEngineSource(baseURL string, client *http.Client) Source {
+	return &httpSource{
+		name:     ServiceAlertingEngine,
+		sections: []string{SectionAlerts},
+		baseURL:  baseURL,
+		path: func(entityID string) string {
+			return "/alerts"
+		},
+		client: client,
+		params: func(entityID string, limits Limits) url.Values {
+			values := url.Values{}
+			values.Set("entity_id", entityID)
+			values.Set("limit", strconv.Itoa(limits.Scan))
+			return values
+		},
+		decode: func(body *json.Decoder, entityID string, limits Limits) (Contribution, error) {
+			var payload struct {
+				Alerts []struct {
+					ID        string    `json:"id"`
+					RuleName  string    `json:"rule_name"`
+					Title     string    `json:"title"`
+					Severity  string    `json:"severity"`
+					Status    string    `json:"status"`
+					EntityIDs []string  `json:"entity_ids"`
+					CreatedAt time.Time `json:"created_at"`
+				} `json:"alerts"`
+			}
+			if err := body.Decode(&payload); err != nil {
+				return nil, err
+			}
+
+			alerts := []Alert{}
+			for _, alert := range payload.Alerts {
+				if !containsString(alert.EntityIDs, entityID) {
+					continue
+				}
+				alerts = append(alerts, Alert{
+					ID:        alert.ID,
+					RuleName:  alert.RuleName,
+					Title:     alert.Title,
+					Severity:  alert.Severity,
+					Status:    alert.Status,
+					CreatedAt: alert.CreatedAt,
+				})
+			}
+			sort.SliceStable(alerts, func(i, j int) bool {
+				return alerts[i].CreatedAt.After(alerts[j].CreatedAt)
+			})
+			if limits.RecentAlerts > 0 && len(alerts) > limits.RecentAlerts {
+				alerts = alerts[:limits.RecentAlerts]
+			}
+			return func(profile *Profile) {
+				profile.RecentAlerts = alerts
+			}, nil
+		},
+	}
+}
+
+// openCaseStatuses are the investigation statuses that count as open
+var openCaseStatuses = map[string]bool{
+	"open":         true,
+	"in_progress":  true,
+	"under_review": true,
+}
+
+// NewInvestigationToolkitSource reads the open investigations that reference
+// the entity in their title or description
+func NewInvestigationToolkitSource(baseURL string, client *http.Client) Source {
+	return &httpSource{
+		name:     ServiceInvestigationToolkit,
+		sections: []string{SectionCases},
+		baseURL:  baseURL,
+		path: func(entityID string) string {
+			return "/api/v1/investigations"
+		},
+		client: client,
+		params: func(entityID string, limits Limits) url.Values {
+			values := url.Values{}
+			values.Set("search", entityID)
+			values.Set("limit", strconv.Itoa(limits.Scan))
+			return values
+		},
+		decode: func(body *json.Decoder, entityID string, limits Limits) (Contribution, error) {
+			var payload struct {
+				Data []struct {
+					ID         string     `json:"id"`
+					Title      string     `json:"title"`
+					CaseType   string     `json:"case_type"`
+					Priority   string     `json:"priority"`
+					Status     string     `json:"status"`
+					AssignedTo *string    `json:"assigned_to"`
+					DueDate    *time.Time `json:"due_date"`
+					CreatedAt  time.Time  `json:"created_at"`
+				} `json:"data"`
+			}
+			if err := body.Decode(&payload); err != nil {
+				return nil, err
+			}
+
+			cases := []Case{}
+			for _, investigation := range payload.Data {
+				if !openCaseStatuses[investigation.Status] {
+					continue
+				}
+				cases = append(cases, Case{
+					ID:         investigation.ID,
+					Title:      investigation.Title,
+					CaseType:   investigation.CaseType,
+					Priority:   investigation.Priority,
+					Status:     investigation.Status,
+					AssignedTo: investigation.AssignedTo,
+					DueDate:    investigation.DueDate,
+					CreatedAt:  investigation.CreatedAt,
+				})
+			}
+			if limits.OpenCases > 0 && len(cases) > limits.OpenCases {
+				cases = cases[:limits.OpenCases]
+			}
+			return func(profile *Profile) {
+				profile.OpenCases = cases
+			}, nil
+		},
+	}
+}
+
+// NewComplianceEngineSource reads the entity's open screening violations.
+// The highest violation risk score becomes the profile's risk score.
+func NewComplianceEngineSource(baseURL string, client *http.Client) Source {
+	return &httpSource{
+		name:     ServiceComplianceEngine,
+		sections: []string{SectionScreening, SectionRisk},
+		baseURL:  baseURL,
+		path: func(entityID string) string {
+			return "/api/v1/violations"
+		},
+		client: client,
+		params: func(entityID string, limits Limits) url.Values {
+			values := url.Values{}
+			values.Set("status", "open")
+			values.Set("limit", strconv.Itoa(limits.Scan))
+			return values
+		},
+		decode: func(body *json.Decoder, entityID string, limits Limits) (Contribution, error) {
+			var payload struct {
+				Violations []struct {
+					ScreeningHit
+					EntityID string `json:"entity_id"`
+				} `json:"violations"`
+			}
+			if err := body.Decode(&payload); err != nil {
+				return nil, err
+			}
+
+			hits := []ScreeningHit{}
+			var score *float64
+			for _, violation := range payload.Violations {
+				if violation.EntityID != entityID {
+					continue
+				}
+				hits = append(hits, violation.ScreeningHit)
+				if score == nil || violation.RiskScore > *score {
+					riskScore := violation.RiskScore
+					score = &riskScore
+				}
+			}
+			sort.SliceStable(hits, func(i, j int) bool {
+				return hits[i].RiskScore > hits[j].RiskScore
+			})
+			if limits.ScreeningHits > 0 && len(hits) > limits.ScreeningHits {
+				hits = hits[:limits.ScreeningHits]
+			}
+			return func(profile *Profile) {
+				profile.ScreeningHits = hits
+				profile.Risk.Score = score
+			}, nil
+		},
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
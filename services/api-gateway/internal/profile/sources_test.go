@@ -0,0 +1,146 @@
+package profile
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fetchProfile serves body from a test server and applies what source makes of it
+func fetchProfile(t *testing.T, newSource func(string, *http.Client) Source, status int, body string, limits Limits) (*Profile, *http.Request, error) {
+	t.Helper()
+
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	contribution, err := newSource(server.URL, server.Client()).Fetch(context.Background(), "acme/uk", limits)
+	if err != nil {
+		return nil, received, err
+	}
+	profile := &Profile{}
+	contribution(profile)
+	return profile, received, nil
+}
+
+func TestAlertingEngineSource(t *testing.T) {
+	body := `{"alerts": [
+		{"id": "a1", "entity_ids": ["acme/uk"], "created_at": "2026-03-01T10:00:00Z"},
+		{"id": "a2", "entity_ids": ["globex"], "created_at": "2026-03-04T10:00:00Z"},
+		{"id": "a3", "entity_ids": ["globex", "acme/uk"], "created_at": "2026-03-03T10:00:00Z"},
+		{"id": "a4", "entity_ids": ["acme/uk"], "created_at": "2026-03-02T10:00:00Z"}
+	]}`
+
+	profile, r, err := fetchProfile(t, NewAlertingEngineSource, http.StatusOK, body, Limits{RecentAlerts: 2, Scan: 200})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if got := r.URL.Query().Get("limit"); got != "200" {
+		t.Fatalf("got scan limit %s, want 200", got)
+	}
+
+	// Only the entity's alerts, newest first, capped at the limit
+	if len(profile.RecentAlerts) != 2 || profile.RecentAlerts[0].ID != "a3" || profile.RecentAlerts[1].ID != "a4" {
+		t.Fatalf("got %+v, want a3 and a4", profile.RecentAlerts)
+	}
+}
+
+func TestInvestigationToolkitSource(t *testing.T) {
+	body := `{"data": [
+		{"id": "c1", "status": "open"},
+		{"id": "c2", "status": "closed"},
+		{"id": "c3", "status": "under_review"},
+		{"id": "c4", "status": "archived"},
+		{"id": "c5", "status": "in_progress"}
+	]}`
+
+	profile, r, err := fetchProfile(t, NewInvestigationToolkitSource, http.StatusOK, body, Limits{OpenCases: 2, Scan: 50})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if got := r.URL.Query().Get("search"); got != "acme/uk" {
+		t.Fatalf("got search %q, want the entity id", got)
+	}
+	if len(profile.OpenCases) != 2 || profile.OpenCases[0].ID != "c1" || profile.OpenCases[1].ID != "c3" {
+		t.Fatalf("got %+v, want the first two open cases", profile.OpenCases)
+	}
+}
+
+func TestComplianceEngineSource(t *testing.T) {
+	t.Run("the highest open violation is the risk score", func(t *testing.T) {
+		body := `{"violations": [
+			{"id": "v1", "entity_id": "acme/uk", "risk_score": 40},
+			{"id": "v2", "entity_id": "globex", "risk_score": 95},
+			{"id": "v3", "entity_id": "acme/uk", "risk_score": 80},
+			{"id": "v4", "entity_id": "acme/uk", "risk_score": 60}
+		]}`
+
+		profile, r, err := fetchProfile(t, NewComplianceEngineSource, http.StatusOK, body, Limits{ScreeningHits: 2})
+		if err != nil {
+			t.Fatalf("Fetch: %v", err)
+		}
+		if got := r.URL.Query().Get("status"); got != "open" {
+			t.Fatalf("got status filter %q, want open", got)
+		}
+		if profile.Risk.Score == nil || *profile.Risk.Score != 80 {
+			t.Fatalf("got score %v, want 80", profile.Risk.Score)
+		}
+		if len(profile.ScreeningHits) != 2 || profile.ScreeningHits[0].ID != "v3" || profile.ScreeningHits[1].ID != "v4" {
+			t.Fatalf("got %+v, want v3 and v4", profile.ScreeningHits)
+		}
+	})
+
+	t.Run("no violations leave the score unset", func(t *testing.T) {
+		profile, _, err := fetchProfile(t, NewComplianceEngineSource, http.StatusOK, `{"violations": []}`, Limits{})
+		if err != nil {
+			t.Fatalf("Fetch: %v", err)
+		}
+		if profile.Risk.Score != nil {
+			t.Fatalf("got score %v, want none", *profile.Risk.Score)
+		}
+	})
+}
+
+func TestGraphNeighborhoodSource(t *testing.T) {
+	body := `{"subgraph": {
+		"entities": [{"id": "acme/uk", "type": "organization", "properties": {"name": "Acme Ltd"}}],
+		"relationships": [{"type": "OWNS", "source_id": "bob", "target_id": "acme/uk"}]
+	}}`
+
+	profile, r, err := fetchProfile(t, NewGraphNeighborhoodSource, http.StatusOK, body, Limits{Counterparties: 5})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if r.URL.EscapedPath() != "/api/v1/entities/acme%2Fuk/neighborhood" {
+		t.Fatalf("got path %s, want the entity id escaped", r.URL.EscapedPath())
+	}
+	if profile.Identity.Name != "Acme Ltd" || profile.Identity.Type != "organization" {
+		t.Fatalf("got identity %+v, want Acme Ltd", profile.Identity)
+	}
+	if len(profile.Counterparties) != 1 || profile.Counterparties[0].EntityID != "bob" {
+		t.Fatalf("got %+v, want bob", profile.Counterparties)
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"error status", http.StatusInternalServerError, `{"alerts": []}`},
+		{"not found", http.StatusNotFound, ``},
+		{"malformed body", http.StatusOK, `{"alerts": [`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := fetchProfile(t, NewAlertingEngineSource, tt.status, tt.body, Limits{}); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package requestlog
+
+import (
+	"context"
+	"net/http"
+
+	"aegisshield/shared/reqctx"
+)
+
+// CorrelationHeader carries the correlation ID on requests to and from the
+// gateway and on its calls to backend services
+const CorrelationHeader = reqctx.CorrelationHeader
+
+// WithCorrelationID returns a context carrying the correlation ID. The
+// gateway is the edge, so the request context it starts has no origin.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return reqctx.NewContext(ctx, reqctx.RequestContext{CorrelationID: id})
+}
+
+// CorrelationID returns the correlation ID carried by the context, if any
+func CorrelationID(ctx context.Context) string {
+	return reqctx.CorrelationID(ctx)
+}
+
+// requestCorrelationID keeps the correlation ID a client sent, falling back
+// to its X-Request-ID, and generates one when neither is usable
+func requestCorrelationID(r *http.Request) string {
+	for _, header := range []string{CorrelationHeader, "X-Request-ID"} {
+		if id := r.Header.Get(header); reqctx.ValidCorrelationID(id) {
+			return id
+		}
+	}
+	return reqctx.NewCorrelationID()
+}
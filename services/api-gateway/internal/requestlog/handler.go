@@ -0,0 +1,134 @@
+package requestlog
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/api-gateway/internal/auth"
+	"aegisshield/shared/rbac"
+)
+
+// gatewayResource is the RBAC resource guarding gateway operations settings
+const gatewayResource = "gateway"
+
+// Handler lets operators read and change request log sample rates while the
+// gateway runs, for example to log every request on a route being debugged
+type Handler struct {
+	sampler     *Sampler
+	authService *auth.Service
+	logger      *logrus.Logger
+}
+
+// NewHandler creates a new request log handler
+func NewHandler(sampler *Sampler, authService *auth.Service, logger *logrus.Logger) *Handler {
+	return &Handler{
+		sampler:     sampler,
+		authService: authService,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registers request log routes
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/gateway/request-log/sampling", h.handleGetSampling).Methods("GET")
+	router.HandleFunc("/gateway/request-log/sampling", h.handleUpdateSampling).Methods("POST")
+}
+
+// SamplingUpdate changes sample rates. Routes maps path prefixes to their new
+// rate; Remove returns routes to the default rate.
+type SamplingUpdate struct {
+	Default *float64           `json:"default"`
+	Routes  map[string]float64 `json:"routes"`
+	Remove  []string           `json:"remove"`
+}
+
+func (h *Handler) handleGetSampling(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r, rbac.ActionRead) {
+		return
+	}
+	writeJSON(w, http.StatusOK, h.sampler.Rates())
+}
+
+func (h *Handler) handleUpdateSampling(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r, rbac.ActionWrite) {
+		return
+	}
+
+	var update SamplingUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	// Validate everything first so an update is applied or rejected as a whole
+	if update.Default != nil {
+		if err := validateRate(*update.Default); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	for route, rate := range update.Routes {
+		if _, err := normalizeRoute(route); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := validateRate(rate); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if update.Default != nil {
+		h.sampler.SetDefault(*update.Default)
+	}
+	for _, route := range update.Remove {
+		h.sampler.RemoveRate(route)
+	}
+	for route, rate := range update.Routes {
+		h.sampler.SetRate(route, rate)
+	}
+
+	rates := h.sampler.Rates()
+	user, _ := h.authService.GetUserFromContext(r.Context())
+	h.logger.WithFields(logrus.Fields{
+		"user_id":        user.ID,
+		"correlation_id": CorrelationID(r.Context()),
+		"default":        rates.Default,
+		"routes":         rates.Routes,
+	}).Info("Request log sample rates updated")
+
+	writeJSON(w, http.StatusOK, rates)
+}
+
+// authorize rejects callers not allowed the given action on gateway settings
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request, action string) bool {
+	user, err := h.authService.GetUserFromContext(r.Context())
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return false
+	}
+	allowed, err := h.authService.Authorize(r.Context(), user, gatewayResource, action)
+	if err != nil {
+		h.logger.WithError(err).Error("Gateway permission check failed")
+		writeError(w, http.StatusServiceUnavailable, "permission check failed")
+		return false
+	}
+	if !allowed {
+		writeError(w, http.StatusForbidden, "insufficient permissions for gateway settings")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,250 @@
+package requestlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/api-gateway/internal/config"
+)
+
+var requestLogsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "http_request_logs_total",
+		Help: "HTTP requests by whether they were logged and why",
+	},
+	[]string{"reason"},
+)
+
+// Reasons a request was logged, or skipped
+const (
+	reasonSampled = "sampled"
+	reasonError   = "error"
+	reasonSlow    = "slow"
+	reasonSkipped = "skipped"
+)
+
+// Logger writes structured request/response logs with sensitive data
+// scrubbed, sampled per route
+type Logger struct {
+	logger       *logrus.Logger
+	scrubber     *Scrubber
+	sampler      *Sampler
+	maxBodyBytes int
+	slowRequest  time.Duration
+}
+
+// NewLogger creates a request logger from configuration
+func NewLogger(cfg config.RequestLogConfig, logger *logrus.Logger) (*Logger, error) {
+	scrubber, err := NewScrubber(cfg.ScrubFields, cfg.ScrubHeaders, cfg.ScrubPatterns)
+	if err != nil {
+		return nil, err
+	}
+	sampler, err := NewSampler(cfg.SampleRate, cfg.RouteSampleRates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Logger{
+		logger:       logger,
+		scrubber:     scrubber,
+		sampler:      sampler,
+		maxBodyBytes: cfg.MaxBodyBytes,
+		slowRequest:  time.Duration(cfg.SlowRequestMs) * time.Millisecond,
+	}, nil
+}
+
+// Sampler returns the sampler so rates can be changed at runtime
+func (l *Logger) Sampler() *Sampler {
+	return l.sampler
+}
+
+// Middleware assigns every request a correlation ID, returned in the
+// X-Correlation-ID response header and carried by the request context to
+// backend calls, and logs sampled requests with their responses. Server
+// errors and slow requests are always logged.
+func (l *Logger) Middleware() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			correlationID := requestCorrelationID(r)
+			w.Header().Set(CorrelationHeader, correlationID)
+			r = r.WithContext(WithCorrelationID(r.Context(), correlationID))
+
+			rate := l.sampler.Rate(r.URL.Path)
+			sampled := l.sampler.Sample(rate)
+
+			// Bodies are captured for every request so errors can be logged with them
+			var requestBody *captureBuffer
+			if l.maxBodyBytes > 0 && r.Body != nil && r.Body != http.NoBody {
+				requestBody = &captureBuffer{limit: l.maxBodyBytes}
+				r.Body = &captureReader{ReadCloser: r.Body, buffer: requestBody}
+			}
+			rw := &responseWriter{
+				ResponseWriter: w,
+				statusCode:     http.StatusOK,
+				body:           captureBuffer{limit: l.maxBodyBytes},
+			}
+
+			next.ServeHTTP(rw, r)
+
+			duration := time.Since(start)
+			reason := reasonSkipped
+			switch {
+			case rw.statusCode >= http.StatusInternalServerError:
+				reason = reasonError
+			case l.slowRequest > 0 && duration >= l.slowRequest:
+				reason = reasonSlow
+			case sampled:
+				reason = reasonSampled
+			}
+			requestLogsTotal.WithLabelValues(reason).Inc()
+			if reason == reasonSkipped {
+				return
+			}
+
+			fields := logrus.Fields{
+				"correlation_id":   correlationID,
+				"method":           r.Method,
+				"path":             r.URL.Path,
+				"remote_addr":      r.RemoteAddr,
+				"user_agent":       l.scrubber.String(r.UserAgent()),
+				"request_headers":  l.scrubber.Headers(r.Header),
+				"status":           rw.statusCode,
+				"duration_ms":      duration.Milliseconds(),
+				"response_bytes":   rw.bytes,
+				"response_headers": l.scrubber.Headers(rw.Header()),
+				"sample_rate":      rate,
+				"log_reason":       reason,
+			}
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					fields["route"] = template
+				}
+			}
+			if query := l.scrubber.Query(r.URL.Query()); query != "" {
+				fields["query"] = query
+			}
+			if requestBody != nil {
+				l.addBody(fields, "request_body", r.Header.Get("Content-Type"), requestBody)
+			}
+			if l.maxBodyBytes > 0 {
+				l.addBody(fields, "response_body", rw.Header().Get("Content-Type"), &rw.body)
+			}
+
+			entry := l.logger.WithFields(fields)
+			switch {
+			case rw.statusCode >= http.StatusInternalServerError:
+				entry.Error("HTTP request")
+			case rw.statusCode >= http.StatusBadRequest:
+				entry.Warn("HTTP request")
+			default:
+				entry.Info("HTTP request")
+			}
+		})
+	}
+}
+
+// addBody logs a captured body as scrubbed JSON. Bodies that are not JSON or
+// were truncated cannot be scrubbed field by field, so only their size is
+// logged.
+func (l *Logger) addBody(fields logrus.Fields, name, contentType string, body *captureBuffer) {
+	if body.size == 0 {
+		return
+	}
+	fields[name+"_bytes"] = body.size
+
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "application/json" {
+		return
+	}
+	if body.truncated() {
+		fields[name+"_truncated"] = true
+		return
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body.buf.Bytes()))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return
+	}
+	fields[name] = l.scrubber.Value(value)
+}
+
+// captureBuffer keeps the first limit bytes written to it and counts the rest
+type captureBuffer struct {
+	buf   bytes.Buffer
+	limit int
+	size  int64
+}
+
+func (b *captureBuffer) capture(p []byte) {
+	b.size += int64(len(p))
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			p = p[:room]
+		}
+		b.buf.Write(p)
+	}
+}
+
+func (b *captureBuffer) truncated() bool {
+	return b.size > int64(b.buf.Len())
+}
+
+// captureReader copies a request body into a capture buffer as the handler reads it
+type captureReader struct {
+	io.ReadCloser
+	buffer *captureBuffer
+}
+
+func (r *captureReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.buffer.capture(p[:n])
+	}
+	return n, err
+}
+
+// responseWriter captures the status, size and leading bytes of a response
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	bytes       int64
+	body        captureBuffer
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.statusCode = code
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	if rw.body.limit > 0 {
+		rw.body.capture(b[:n])
+	}
+	return n, err
+}
+
+// Flush lets streaming handlers flush through the wrapper
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
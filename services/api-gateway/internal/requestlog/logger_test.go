@@ -0,0 +1,165 @@
+package requestlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/api-gateway/internal/config"
+)
+
+// secrets appear in test requests and responses and must never be logged
+var secrets = []string{"hunter2", "alice@example.com", "123-45-6789", "Bearer token-1"}
+
+// testRequestLogger returns a logger that writes JSON entries to the buffer
+func testRequestLogger(t *testing.T, cfg config.RequestLogConfig) (*Logger, *bytes.Buffer) {
+	t.Helper()
+
+	var out bytes.Buffer
+	base := logrus.New()
+	base.SetOutput(&out)
+	base.SetFormatter(&logrus.JSONFormatter{})
+
+	if cfg.ScrubFields == nil {
+		cfg.ScrubFields = []string{"password"}
+	}
+	if cfg.ScrubHeaders == nil {
+		cfg.ScrubHeaders = []string{"Authorization"}
+	}
+	if cfg.ScrubPatterns == nil {
+		cfg.ScrubPatterns = []string{"email", "ssn"}
+	}
+	l, err := NewLogger(cfg, base)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	return l, &out
+}
+
+// serve runs a request carrying every secret through the middleware
+func serve(l *Logger, status int, contentType, requestBody, responseBody string) {
+	handler := l.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		io.WriteString(w, responseBody)
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/customers?password=hunter2&email=alice@example.com", strings.NewReader(requestBody))
+	r.Header.Set("Content-Type", contentType)
+	r.Header.Set("Authorization", "Bearer token-1")
+	r.Header.Set("X-Customer", "ssn 123-45-6789")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+// logEntries decodes the logged entries, failing if any contains a secret
+func logEntries(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	for _, secret := range secrets {
+		if strings.Contains(out.String(), secret) {
+			t.Fatalf("log contains %q: %s", secret, out.String())
+		}
+	}
+	var entries []map[string]interface{}
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		var entry map[string]interface{}
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatalf("decode log entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLoggerMiddlewareScrubs(t *testing.T) {
+	const jsonBody = `{"user":{"email":"alice@example.com","password":"hunter2"},"note":"ssn 123-45-6789"}`
+
+	t.Run("sampled JSON bodies are logged scrubbed", func(t *testing.T) {
+		l, out := testRequestLogger(t, config.RequestLogConfig{SampleRate: 1, MaxBodyBytes: 1024})
+		serve(l, http.StatusOK, "application/json", jsonBody, jsonBody)
+
+		entries := logEntries(t, out)
+		if len(entries) != 1 {
+			t.Fatalf("got %d entries, want 1", len(entries))
+		}
+		entry := entries[0]
+		for _, name := range []string{"request_body", "response_body"} {
+			body, ok := entry[name].(map[string]interface{})
+			if !ok {
+				t.Fatalf("%s was not logged: %v", name, entry)
+			}
+			user := body["user"].(map[string]interface{})
+			if user["password"] != redacted || user["email"] != redacted || body["note"] != "ssn "+redacted {
+				t.Fatalf("%s not scrubbed: %v", name, body)
+			}
+		}
+		if entry["query"] == nil || entry["request_headers"] == nil {
+			t.Fatalf("query and headers were not logged: %v", entry)
+		}
+	})
+
+	t.Run("server errors are logged scrubbed whatever the sample rate", func(t *testing.T) {
+		l, out := testRequestLogger(t, config.RequestLogConfig{SampleRate: 0, MaxBodyBytes: 1024})
+		serve(l, http.StatusInternalServerError, "application/json", jsonBody, jsonBody)
+
+		entries := logEntries(t, out)
+		if len(entries) != 1 || entries[0]["log_reason"] != reasonError {
+			t.Fatalf("expected one error entry, got %v", entries)
+		}
+		if entries[0]["request_body"] == nil {
+			t.Fatalf("request body was not logged: %v", entries[0])
+		}
+	})
+
+	t.Run("truncated JSON bodies log only their size", func(t *testing.T) {
+		l, out := testRequestLogger(t, config.RequestLogConfig{SampleRate: 1, MaxBodyBytes: 16})
+		serve(l, http.StatusOK, "application/json", jsonBody, jsonBody)
+
+		entry := logEntries(t, out)[0]
+		if entry["request_body"] != nil || entry["request_body_truncated"] != true {
+			t.Fatalf("truncated body was logged: %v", entry)
+		}
+		if entry["request_body_bytes"] != float64(len(jsonBody)) {
+			t.Fatalf("got request_body_bytes %v, want %d", entry["request_body_bytes"], len(jsonBody))
+		}
+	})
+
+	t.Run("bodies that are not JSON log only their size", func(t *testing.T) {
+		const textBody = "password=hunter2 email=alice@example.com"
+		l, out := testRequestLogger(t, config.RequestLogConfig{SampleRate: 1, MaxBodyBytes: 1024})
+		serve(l, http.StatusOK, "text/plain", textBody, textBody)
+
+		entry := logEntries(t, out)[0]
+		if entry["request_body"] != nil || entry["response_body"] != nil {
+			t.Fatalf("plain text body was logged: %v", entry)
+		}
+	})
+
+	t.Run("malformed JSON bodies log only their size", func(t *testing.T) {
+		const brokenBody = `{"password":"hunter2"`
+		l, out := testRequestLogger(t, config.RequestLogConfig{SampleRate: 1, MaxBodyBytes: 1024})
+		serve(l, http.StatusOK, "application/json", brokenBody, brokenBody)
+
+		entry := logEntries(t, out)[0]
+		if entry["request_body"] != nil {
+			t.Fatalf("malformed body was logged: %v", entry)
+		}
+	})
+
+	t.Run("skipped requests are not logged", func(t *testing.T) {
+		l, out := testRequestLogger(t, config.RequestLogConfig{SampleRate: 0, MaxBodyBytes: 1024})
+		serve(l, http.StatusBadRequest, "application/json", jsonBody, jsonBody)
+
+		if entries := logEntries(t, out); len(entries) != 0 {
+			t.Fatalf("got %d entries, want none", len(entries))
+		}
+	})
+}
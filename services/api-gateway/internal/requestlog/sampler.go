@@ -0,0 +1,163 @@
+package requestlog
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Sampler decides which requests are logged. Each route, a path prefix, has
+// its own rate with the longest matching prefix winning; other requests use
+// the default rate. Rates can be changed while the gateway runs.
+type Sampler struct {
+	mu          sync.RWMutex
+	defaultRate float64
+	routes      map[string]float64
+}
+
+// RouteRate is the sample rate of one route
+type RouteRate struct {
+	Route string  `json:"route"`
+	Rate  float64 `json:"rate"`
+}
+
+// SamplingRates are the default rate and every route rate
+type SamplingRates struct {
+	Default float64     `json:"default"`
+	Routes  []RouteRate `json:"routes"`
+}
+
+// NewSampler creates a sampler from the default rate and route rates given
+// as strings, as read from configuration
+func NewSampler(defaultRate float64, routes map[string]string) (*Sampler, error) {
+	if err := validateRate(defaultRate); err != nil {
+		return nil, err
+	}
+
+	s := &Sampler{defaultRate: defaultRate, routes: make(map[string]float64, len(routes))}
+	for route, value := range routes {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sample rate %q for route %s", value, route)
+		}
+		if err := s.SetRate(route, rate); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Rate returns the sample rate of a request path
+func (s *Sampler) Rate(path string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rate, matched := s.defaultRate, ""
+	for route, routeRate := range s.routes {
+		if len(route) > len(matched) && routeMatches(route, path) {
+			rate, matched = routeRate, route
+		}
+	}
+	return rate
+}
+
+// Sample reports whether a request with the given rate is logged
+func (s *Sampler) Sample(rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	return rand.Float64() < rate
+}
+
+// SetDefault changes the rate of requests on routes without their own
+func (s *Sampler) SetDefault(rate float64) error {
+	if err := validateRate(rate); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaultRate = rate
+	return nil
+}
+
+// SetRate sets the rate of a route
+func (s *Sampler) SetRate(route string, rate float64) error {
+	route, err := normalizeRoute(route)
+	if err != nil {
+		return err
+	}
+	if err := validateRate(rate); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[route] = rate
+	return nil
+}
+
+// RemoveRate returns a route to the default rate
+func (s *Sampler) RemoveRate(route string) bool {
+	route, err := normalizeRoute(route)
+	if err != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.routes[route]
+	delete(s.routes, route)
+	return exists
+}
+
+// Rates returns the current rates, routes sorted by path
+func (s *Sampler) Rates() SamplingRates {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rates := SamplingRates{Default: s.defaultRate, Routes: make([]RouteRate, 0, len(s.routes))}
+	for route, rate := range s.routes {
+		rates.Routes = append(rates.Routes, RouteRate{Route: route, Rate: rate})
+	}
+	sort.Slice(rates.Routes, func(i, j int) bool {
+		return rates.Routes[i].Route < rates.Routes[j].Route
+	})
+	return rates
+}
+
+// routeMatches reports whether path is the route or below it, so /query
+// matches /query and /query/batch but not /queryable
+func routeMatches(route, path string) bool {
+	if route == "/" {
+		return true
+	}
+	if !strings.HasPrefix(path, route) {
+		return false
+	}
+	return len(path) == len(route) || path[len(route)] == '/'
+}
+
+func normalizeRoute(route string) (string, error) {
+	route = strings.TrimSpace(route)
+	if !strings.HasPrefix(route, "/") {
+		return "", fmt.Errorf("route %q must start with /", route)
+	}
+	if route = strings.TrimRight(route, "/"); route == "" {
+		route = "/"
+	}
+	return route, nil
+}
+
+func validateRate(rate float64) error {
+	if rate < 0 || rate > 1 {
+		return fmt.Errorf("sample rate %v must be between 0 and 1", rate)
+	}
+	return nil
+}
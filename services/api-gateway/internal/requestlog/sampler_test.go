@@ -0,0 +1,130 @@
+package requestlog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewSampler(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		defaultRate float64
+		routes      map[string]string
+	}{
+		{"negative default rate", -0.1, nil},
+		{"default rate above one", 1.5, nil},
+		{"route rate that is not a number", 0.5, map[string]string{"/query": "half"}},
+		{"route rate above one", 0.5, map[string]string{"/query": "2"}},
+		{"negative route rate", 0.5, map[string]string{"/query": "-1"}},
+		{"route without a leading slash", 0.5, map[string]string{"query": "1"}},
+	} {
+		t.Run("rejects a "+tt.name, func(t *testing.T) {
+			if _, err := NewSampler(tt.defaultRate, tt.routes); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+
+	t.Run("accepts the bounds", func(t *testing.T) {
+		s, err := NewSampler(0, map[string]string{"/health": "0", "/query": "1"})
+		if err != nil {
+			t.Fatalf("NewSampler: %v", err)
+		}
+		want := SamplingRates{Default: 0, Routes: []RouteRate{{"/health", 0}, {"/query", 1}}}
+		if got := s.Rates(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestSamplerSample(t *testing.T) {
+	s, err := NewSampler(0.5, nil)
+	if err != nil {
+		t.Fatalf("NewSampler: %v", err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if s.Sample(0) {
+			t.Fatal("a rate of 0 logged a request")
+		}
+		if !s.Sample(1) {
+			t.Fatal("a rate of 1 skipped a request")
+		}
+	}
+}
+
+func TestSamplerRate(t *testing.T) {
+	s, err := NewSampler(0.1, map[string]string{
+		"/query":       "1",
+		"/query/batch": "0.5",
+		"/health/":     "0",
+	})
+	if err != nil {
+		t.Fatalf("NewSampler: %v", err)
+	}
+
+	for _, tt := range []struct {
+		path string
+		want float64
+	}{
+		{"/query", 1},
+		{"/query/", 1},
+		{"/query/batch", 0.5},
+		{"/query/batch/42", 0.5},
+		{"/queryable", 0.1},
+		{"/health", 0},
+		{"/health/live", 0},
+		{"/api/v1/cases", 0.1},
+		{"/", 0.1},
+	} {
+		if got := s.Rate(tt.path); got != tt.want {
+			t.Errorf("Rate(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	t.Run("a root route replaces the default", func(t *testing.T) {
+		if err := s.SetRate("/", 0.3); err != nil {
+			t.Fatalf("SetRate: %v", err)
+		}
+		defer s.RemoveRate("/")
+
+		if got := s.Rate("/api/v1/cases"); got != 0.3 {
+			t.Fatalf("got %v, want 0.3", got)
+		}
+		if got := s.Rate("/query/batch"); got != 0.5 {
+			t.Fatalf("longer routes still win, got %v", got)
+		}
+	})
+}
+
+func TestSamplerUpdates(t *testing.T) {
+	s, err := NewSampler(0.1, map[string]string{"/query": "1"})
+	if err != nil {
+		t.Fatalf("NewSampler: %v", err)
+	}
+
+	t.Run("invalid rates leave the sampler unchanged", func(t *testing.T) {
+		if err := s.SetDefault(1.01); err == nil {
+			t.Fatal("expected an error for the default rate")
+		}
+		if err := s.SetRate("/query", -0.5); err == nil {
+			t.Fatal("expected an error for the route rate")
+		}
+		want := SamplingRates{Default: 0.1, Routes: []RouteRate{{"/query", 1}}}
+		if got := s.Rates(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("removing a route returns it to the default", func(t *testing.T) {
+		if !s.RemoveRate("/query/") {
+			t.Fatal("expected the route to be removed")
+		}
+		if s.RemoveRate("/query") {
+			t.Fatal("a removed route was removed again")
+		}
+		if got := s.Rate("/query"); got != 0.1 {
+			t.Fatalf("got %v, want 0.1", got)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package requestlog
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces scrubbed values in logs
+const redacted = "[REDACTED]"
+
+// valuePatterns are the built-in value scrubbers that can be enabled by name.
+// They mask personal data wherever it appears in a logged string, such as
+// literals inside a GraphQL query.
+var valuePatterns = map[string]*regexp.Regexp{
+	"email":       regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	"card_number": regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+	"ssn":         regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+	"iban":        regexp.MustCompile(`\b[A-Z]{2}\d{2}(?:\s?[A-Z0-9]{4}){2,7}(?:\s?[A-Z0-9]{1,4})?\b`),
+	"phone":       regexp.MustCompile(`\+\d[\d\s\-()]{7,}\d`),
+}
+
+// Scrubber masks sensitive fields, headers and values before they are logged
+type Scrubber struct {
+	fields   map[string]bool
+	headers  map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// NewScrubber creates a scrubber masking the named JSON and query fields
+// (case-insensitive), the named headers, and values matching the named
+// built-in patterns
+func NewScrubber(fields, headers, patterns []string) (*Scrubber, error) {
+	s := &Scrubber{
+		fields:  make(map[string]bool, len(fields)),
+		headers: make(map[string]bool, len(headers)),
+	}
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			s.fields[normalizeField(field)] = true
+		}
+	}
+	for _, header := range headers {
+		if header = strings.TrimSpace(header); header != "" {
+			s.headers[http.CanonicalHeaderKey(header)] = true
+		}
+	}
+	for _, name := range patterns {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		pattern, exists := valuePatterns[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown scrub pattern %q", name)
+		}
+		s.patterns = append(s.patterns, pattern)
+	}
+	return s, nil
+}
+
+// Headers returns the headers with scrubbed headers masked. Multiple values
+// are joined so each header logs as a single field.
+func (s *Scrubber) Headers(headers http.Header) map[string]string {
+	result := make(map[string]string, len(headers))
+	for name, values := range headers {
+		if s.headers[http.CanonicalHeaderKey(name)] {
+			result[name] = redacted
+			continue
+		}
+		result[name] = s.String(strings.Join(values, ", "))
+	}
+	return result
+}
+
+// Query returns a query string with scrubbed fields masked
+func (s *Scrubber) Query(values url.Values) string {
+	if len(values) == 0 {
+		return ""
+	}
+	scrubbed := make(url.Values, len(values))
+	for key, vals := range values {
+		if s.fields[normalizeField(key)] {
+			scrubbed[key] = []string{redacted}
+			continue
+		}
+		for _, v := range vals {
+			scrubbed[key] = append(scrubbed[key], s.String(v))
+		}
+	}
+	return scrubbed.Encode()
+}
+
+// Value returns a copy of a decoded JSON value with scrubbed fields masked
+// at any depth and every string passed through the value scrubbers
+func (s *Scrubber) Value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if s.fields[normalizeField(key)] {
+				result[key] = redacted
+				continue
+			}
+			result[key] = s.Value(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = s.Value(item)
+		}
+		return result
+	case string:
+		return s.String(v)
+	}
+	return value
+}
+
+// String masks every match of the value scrubbers in s
+func (s *Scrubber) String(value string) string {
+	for _, pattern := range s.patterns {
+		value = pattern.ReplaceAllString(value, redacted)
+	}
+	return value
+}
+
+// normalizeField lets accountNumber, account-number and account_number match
+func normalizeField(field string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(field))
+}
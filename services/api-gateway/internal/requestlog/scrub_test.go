@@ -0,0 +1,150 @@
+package requestlog
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestNewScrubber(t *testing.T) {
+	t.Run("unknown patterns are rejected", func(t *testing.T) {
+		if _, err := NewScrubber(nil, nil, []string{"email", "passport"}); err == nil {
+			t.Fatal("expected an error for an unknown pattern")
+		}
+	})
+
+	t.Run("blank names are ignored", func(t *testing.T) {
+		s, err := NewScrubber([]string{" ", ""}, []string{""}, []string{" "})
+		if err != nil {
+			t.Fatalf("NewScrubber: %v", err)
+		}
+		if len(s.fields) != 0 || len(s.headers) != 0 || len(s.patterns) != 0 {
+			t.Fatalf("expected an empty scrubber, got %+v", s)
+		}
+	})
+}
+
+func TestScrubberFields(t *testing.T) {
+	s, err := NewScrubber([]string{"password", "account_number"}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewScrubber: %v", err)
+	}
+
+	t.Run("fields are masked at any depth whatever their spelling", func(t *testing.T) {
+		got := s.Value(map[string]interface{}{
+			"username": "alice",
+			"Password": "hunter2",
+			"accounts": []interface{}{
+				map[string]interface{}{"accountNumber": "12345678", "bank": "ACME"},
+				map[string]interface{}{"account-number": "87654321"},
+			},
+			"transfer": map[string]interface{}{"ACCOUNT_NUMBER": map[string]interface{}{"nested": "value"}},
+		})
+		want := map[string]interface{}{
+			"username": "alice",
+			"Password": redacted,
+			"accounts": []interface{}{
+				map[string]interface{}{"accountNumber": redacted, "bank": "ACME"},
+				map[string]interface{}{"account-number": redacted},
+			},
+			"transfer": map[string]interface{}{"ACCOUNT_NUMBER": redacted},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("the original value is left untouched", func(t *testing.T) {
+		original := map[string]interface{}{"password": "hunter2"}
+		s.Value(original)
+		if original["password"] != "hunter2" {
+			t.Fatalf("original was modified: %v", original)
+		}
+	})
+
+	t.Run("query parameters are masked", func(t *testing.T) {
+		got, err := url.ParseQuery(s.Query(url.Values{
+			"account-number": {"12345678", "87654321"},
+			"page":           {"2"},
+		}))
+		if err != nil {
+			t.Fatalf("parse scrubbed query: %v", err)
+		}
+		want := url.Values{"account-number": {redacted}, "page": {"2"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	})
+}
+
+func TestScrubberHeaders(t *testing.T) {
+	s, err := NewScrubber(nil, []string{"authorization", "X-Api-Key"}, []string{"email"})
+	if err != nil {
+		t.Fatalf("NewScrubber: %v", err)
+	}
+
+	got := s.Headers(http.Header{
+		"Authorization": {"Bearer secret"},
+		"X-Api-Key":     {"key-1", "key-2"},
+		"Accept":        {"application/json", "text/plain"},
+		"From":          {"alice@example.com"},
+	})
+	want := map[string]string{
+		"Authorization": redacted,
+		"X-Api-Key":     redacted,
+		"Accept":        "application/json, text/plain",
+		"From":          redacted,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestScrubberPatterns(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		pattern string
+		value   string
+		want    string
+	}{
+		{"email", "email", "contact alice.smith+aml@example.co.uk today", "contact [REDACTED] today"},
+		{"card number with separators", "card_number", "card 4111 1111 1111 1111 used", "card [REDACTED] used"},
+		{"card number without separators", "card_number", "4111111111111111", redacted},
+		{"short numbers are not card numbers", "card_number", "order 12345 of 2026", "order 12345 of 2026"},
+		{"ssn", "ssn", "ssn 123-45-6789", "ssn [REDACTED]"},
+		{"iban", "iban", "to GB82 WEST 1234 5698 7654 32 now", "to [REDACTED] now"},
+		{"phone", "phone", "call +44 20 7946 0958", "call [REDACTED]"},
+		{"graphql literal", "email", `{ customer(email: "bob@example.com") { id } }`, `{ customer(email: "[REDACTED]") { id } }`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewScrubber(nil, nil, []string{tt.pattern})
+			if err != nil {
+				t.Fatalf("NewScrubber: %v", err)
+			}
+			if got := s.String(tt.value); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	s, err := NewScrubber(nil, nil, []string{"email", "ssn"})
+	if err != nil {
+		t.Fatalf("NewScrubber: %v", err)
+	}
+
+	t.Run("every enabled pattern applies and only those", func(t *testing.T) {
+		value := "alice@example.com 123-45-6789 +44 20 7946 0958"
+		if got, want := s.String(value), "[REDACTED] [REDACTED] +44 20 7946 0958"; got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("strings inside JSON values are scrubbed", func(t *testing.T) {
+		got := s.Value(map[string]interface{}{"note": "ssn 123-45-6789", "amount": 42.5})
+		want := map[string]interface{}{"note": "ssn [REDACTED]", "amount": 42.5}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	})
+}
@@ -3,17 +3,18 @@ package services
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"aegisshield/services/api-gateway/internal/config"
 	dataIngestionPb "aegisshield/shared/proto"
 	entityResolutionPb "aegisshield/shared/proto"
 	alertingPb "aegisshield/shared/proto"
 	graphPb "aegisshield/shared/proto"
+	userPb "aegisshield/shared/proto/user-management"
+	"aegisshield/shared/rbac/userservice"
+	"aegisshield/shared/reqctx"
 )
 
 type ServiceClients struct {
@@ -21,112 +22,108 @@ type ServiceClients struct {
 	EntityResolution entityResolutionPb.EntityResolutionServiceClient
 	AlertingEngine   alertingPb.AlertingEngineServiceClient
 	GraphEngine      graphPb.GraphEngineServiceClient
+	UserManagement   userPb.UserServiceClient
+	// Identity authenticates tokens and API keys through user-management
+	Identity *userservice.Client
 	
-	// gRPC connections
-	dataIngestionConn   *grpc.ClientConn
-	entityResolutionConn *grpc.ClientConn
-	alertingEngineConn   *grpc.ClientConn
-	graphEngineConn      *grpc.ClientConn
+	// Pooled gRPC connections, one pool per backend service
+	dataIngestionPool    *Pool
+	entityResolutionPool *Pool
+	alertingEnginePool   *Pool
+	graphEnginePool      *Pool
+	userManagementPool   *Pool
 }
 
 func NewServiceClients(cfg *config.Config) (*ServiceClients, error) {
 	clients := &ServiceClients{}
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(reqctx.UnaryClientInterceptor("api-gateway")),
+		grpc.WithStreamInterceptor(reqctx.StreamClientInterceptor("api-gateway")),
+	}
 
 	// Data Ingestion Service
-	dataIngestionConn, err := grpc.Dial(
-		cfg.Services.DataIngestionURL,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithTimeout(10*time.Second),
-	)
+	dataIngestionPool, err := NewPool("data-ingestion", splitTargets(cfg.Services.DataIngestionURL), cfg.Pool, dialOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to data ingestion service: %w", err)
 	}
-	clients.dataIngestionConn = dataIngestionConn
-	clients.DataIngestion = dataIngestionPb.NewDataIngestionServiceClient(dataIngestionConn)
+	clients.dataIngestionPool = dataIngestionPool
+	clients.DataIngestion = dataIngestionPb.NewDataIngestionServiceClient(dataIngestionPool)
 
 	// Entity Resolution Service
-	entityResolutionConn, err := grpc.Dial(
-		cfg.Services.EntityResolutionURL,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithTimeout(10*time.Second),
-	)
+	entityResolutionPool, err := NewPool("entity-resolution", splitTargets(cfg.Services.EntityResolutionURL), cfg.Pool, dialOptions...)
 	if err != nil {
+		clients.Close()
 		return nil, fmt.Errorf("failed to connect to entity resolution service: %w", err)
 	}
-	clients.entityResolutionConn = entityResolutionConn
-	clients.EntityResolution = entityResolutionPb.NewEntityResolutionServiceClient(entityResolutionConn)
+	clients.entityResolutionPool = entityResolutionPool
+	clients.EntityResolution = entityResolutionPb.NewEntityResolutionServiceClient(entityResolutionPool)
 
 	// Alerting Engine Service
-	alertingEngineConn, err := grpc.Dial(
-		cfg.Services.AlertingEngineURL,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithTimeout(10*time.Second),
-	)
+	alertingEnginePool, err := NewPool("alerting-engine", splitTargets(cfg.Services.AlertingEngineURL), cfg.Pool, dialOptions...)
 	if err != nil {
+		clients.Close()
 		return nil, fmt.Errorf("failed to connect to alerting engine service: %w", err)
 	}
-	clients.alertingEngineConn = alertingEngineConn
-	clients.AlertingEngine = alertingPb.NewAlertingEngineServiceClient(alertingEngineConn)
+	clients.alertingEnginePool = alertingEnginePool
+	clients.AlertingEngine = alertingPb.NewAlertingEngineServiceClient(alertingEnginePool)
 
 	// Graph Engine Service
-	graphEngineConn, err := grpc.Dial(
-		cfg.Services.GraphEngineURL,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithTimeout(10*time.Second),
-	)
+	graphEnginePool, err := NewPool("graph-engine", splitTargets(cfg.Services.GraphEngineURL), cfg.Pool, dialOptions...)
 	if err != nil {
+		clients.Close()
 		return nil, fmt.Errorf("failed to connect to graph engine service: %w", err)
 	}
-	clients.graphEngineConn = graphEngineConn
-	clients.GraphEngine = graphPb.NewGraphEngineServiceClient(graphEngineConn)
+	clients.graphEnginePool = graphEnginePool
+	clients.GraphEngine = graphPb.NewGraphEngineServiceClient(graphEnginePool)
+
+	// User Management Service
+	userManagementPool, err := NewPool("user-management", splitTargets(cfg.Services.UserManagementURL), cfg.Pool, dialOptions...)
+	if err != nil {
+		clients.Close()
+		return nil, fmt.Errorf("failed to connect to user management service: %w", err)
+	}
+	clients.userManagementPool = userManagementPool
+	clients.UserManagement = userPb.NewUserServiceClient(userManagementPool)
+	clients.Identity = userservice.NewClient(userManagementPool)
 
 	return clients, nil
 }
 
 func (s *ServiceClients) Close() {
-	if s.dataIngestionConn != nil {
-		s.dataIngestionConn.Close()
-	}
-	if s.entityResolutionConn != nil {
-		s.entityResolutionConn.Close()
-	}
-	if s.alertingEngineConn != nil {
-		s.alertingEngineConn.Close()
-	}
-	if s.graphEngineConn != nil {
-		s.graphEngineConn.Close()
+	for _, pool := range s.pools() {
+		if pool.pool != nil {
+			pool.pool.Close()
+		}
 	}
 }
 
+// HealthCheck fails when any backend service has no healthy replica
 func (s *ServiceClients) HealthCheck(ctx context.Context) error {
-	// Check each service health
-	connections := []struct {
-		name string
-		conn *grpc.ClientConn
-	}{
-		{"data-ingestion", s.dataIngestionConn},
-		{"entity-resolution", s.entityResolutionConn},
-		{"alerting-engine", s.alertingEngineConn},
-		{"graph-engine", s.graphEngineConn},
-	}
-
-	for _, service := range connections {
-		if service.conn == nil {
-			return fmt.Errorf("connection to %s service is nil", service.name)
+	for _, service := range s.pools() {
+		if service.pool == nil {
+			return fmt.Errorf("connection pool for %s service is nil", service.name)
 		}
 
-		healthClient := grpc_health_v1.NewHealthClient(service.conn)
-		
-		checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-		_, err := healthClient.Check(checkCtx, &grpc_health_v1.HealthCheckRequest{
-			Service: service.name,
-		})
-		cancel()
-
-		if err != nil {
+		if err := service.pool.HealthCheck(ctx); err != nil {
 			return fmt.Errorf("health check failed for %s service: %w", service.name, err)
 		}
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+type namedPool struct {
+	name string
+	pool *Pool
+}
+
+func (s *ServiceClients) pools() []namedPool {
+	return []namedPool{
+		{"data-ingestion", s.dataIngestionPool},
+		{"entity-resolution", s.entityResolutionPool},
+		{"alerting-engine", s.alertingEnginePool},
+		{"graph-engine", s.graphEnginePool},
+		{"user-management", s.userManagementPool},
+	}
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"aegisshield/services/api-gateway/internal/config"
+)
+
+var (
+	poolConnectionsInUse = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "grpc_pool_connections_in_use",
+			Help: "Number of in-flight calls on pooled backend connections",
+		},
+		[]string{"service", "target"},
+	)
+
+	poolWaitDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_pool_wait_seconds",
+			Help:    "Time calls waited for a healthy pooled backend connection",
+			Buckets: []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2.5},
+		},
+		[]string{"service"},
+	)
+
+	poolReplicaHealthy = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "grpc_pool_replica_healthy",
+			Help: "Whether a backend replica passed its last health check (1) or not (0)",
+		},
+		[]string{"service", "target"},
+	)
+
+	poolCallDeadlinesExceeded = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_pool_deadline_rejections_total",
+			Help: "Backend calls rejected before sending because too little of the request deadline was left",
+		},
+		[]string{"service"},
+	)
+)
+
+// waitPollInterval is how often a waiting call looks for a usable connection
+const waitPollInterval = 10 * time.Millisecond
+
+// Pool spreads calls to one backend service over several connections to each
+// of its replicas. Calls go to the usable connection with the fewest calls in
+// flight; replicas failing their health check are skipped until they recover.
+// Pool implements grpc.ClientConnInterface so generated clients can use it
+// in place of a single connection.
+type Pool struct {
+	service string
+	conns   []*pooledConn
+	cfg     config.PoolConfig
+	next    uint32
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+type pooledConn struct {
+	target   string
+	conn     *grpc.ClientConn
+	inFlight int64
+	healthy  int32
+}
+
+// NewPool dials ConnsPerReplica connections to every target and starts
+// background health checks
+func NewPool(service string, targets []string, cfg config.PoolConfig, opts ...grpc.DialOption) (*Pool, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no targets configured for %s service", service)
+	}
+
+	perReplica := cfg.ConnsPerReplica
+	if perReplica <= 0 {
+		perReplica = 1
+	}
+
+	pool := &Pool{
+		service: service,
+		cfg:     cfg,
+		stop:    make(chan struct{}),
+	}
+
+	for _, target := range targets {
+		for i := 0; i < perReplica; i++ {
+			// Each connection is dialled separately so calls spread over
+			// distinct HTTP/2 transports instead of sharing one
+			conn, err := grpc.Dial(target, opts...)
+			if err != nil {
+				pool.Close()
+				return nil, fmt.Errorf("failed to connect to %s service at %s: %w", service, target, err)
+			}
+			pool.conns = append(pool.conns, &pooledConn{target: target, conn: conn, healthy: 1})
+		}
+		poolReplicaHealthy.WithLabelValues(service, target).Set(1)
+	}
+
+	if interval := time.Duration(cfg.HealthCheckIntervalSeconds) * time.Second; interval > 0 {
+		pool.wg.Add(1)
+		go pool.healthLoop(interval)
+	}
+
+	return pool, nil
+}
+
+// Invoke performs a unary call on the least loaded healthy connection
+func (p *Pool) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	callCtx, cancel, err := p.callContext(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	pc, err := p.acquire(callCtx)
+	if err != nil {
+		return err
+	}
+	defer p.release(pc)
+
+	return pc.conn.Invoke(callCtx, method, args, reply, opts...)
+}
+
+// NewStream opens a stream on the least loaded healthy connection. Streams
+// keep the request deadline but are not bounded by the per-call timeout.
+func (p *Pool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	callCtx, cancel, err := p.callContext(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	pc, err := p.acquire(callCtx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	stream, err := pc.conn.NewStream(callCtx, desc, method, opts...)
+	if err != nil {
+		p.release(pc)
+		cancel()
+		return nil, err
+	}
+
+	pooled := &pooledStream{ClientStream: stream, desc: desc, done: func() {
+		p.release(pc)
+		cancel()
+	}}
+	// Streams the caller abandons, or closes without reading to the end,
+	// are released when their context ends
+	go func() {
+		<-callCtx.Done()
+		pooled.finish()
+	}()
+	return pooled, nil
+}
+
+// HealthCheck checks every replica now and fails when none is healthy
+func (p *Pool) HealthCheck(ctx context.Context) error {
+	p.checkReplicas(ctx)
+
+	for _, pc := range p.conns {
+		if atomic.LoadInt32(&pc.healthy) == 1 {
+			return nil
+		}
+	}
+	return fmt.Errorf("no healthy replicas for %s service", p.service)
+}
+
+// Close stops health checks and closes every pooled connection
+func (p *Pool) Close() {
+	select {
+	case <-p.stop:
+		return
+	default:
+		close(p.stop)
+	}
+	p.wg.Wait()
+
+	for _, pc := range p.conns {
+		pc.conn.Close()
+	}
+}
+
+// callContext derives the deadline of a backend call from the incoming
+// request deadline, keeping a margin so the gateway can still respond.
+// Calls are failed before sending when too little time is left.
+func (p *Pool) callContext(ctx context.Context, bounded bool) (context.Context, context.CancelFunc, error) {
+	timeout := time.Duration(p.cfg.CallTimeoutMs) * time.Millisecond
+	if !bounded {
+		timeout = 0
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline) - time.Duration(p.cfg.DeadlineMarginMs)*time.Millisecond
+		if remaining < time.Duration(p.cfg.MinCallTimeoutMs)*time.Millisecond || remaining <= 0 {
+			poolCallDeadlinesExceeded.WithLabelValues(p.service).Inc()
+			return nil, nil, status.Errorf(codes.DeadlineExceeded,
+				"not enough time left in the request deadline to call %s service", p.service)
+		}
+		if timeout <= 0 || remaining < timeout {
+			timeout = remaining
+		}
+	}
+
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
+
+// acquire picks the usable connection with the fewest calls in flight,
+// waiting up to MaxWaitMs for one to become usable
+func (p *Pool) acquire(ctx context.Context) (*pooledConn, error) {
+	start := time.Now()
+	maxWait := time.Duration(p.cfg.MaxWaitMs) * time.Millisecond
+
+	for {
+		if pc := p.pick(); pc != nil {
+			atomic.AddInt64(&pc.inFlight, 1)
+			poolConnectionsInUse.WithLabelValues(p.service, pc.target).Inc()
+			poolWaitDuration.WithLabelValues(p.service).Observe(time.Since(start).Seconds())
+			return pc, nil
+		}
+
+		if time.Since(start) >= maxWait {
+			poolWaitDuration.WithLabelValues(p.service).Observe(time.Since(start).Seconds())
+			return nil, status.Errorf(codes.Unavailable, "no healthy connection available for %s service", p.service)
+		}
+
+		timer := time.NewTimer(waitPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			poolWaitDuration.WithLabelValues(p.service).Observe(time.Since(start).Seconds())
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// pick returns the usable connection with the fewest calls in flight,
+// starting from a rotating offset so ties spread across replicas
+func (p *Pool) pick() *pooledConn {
+	n := len(p.conns)
+	offset := int(atomic.AddUint32(&p.next, 1))
+
+	var best *pooledConn
+	var bestLoad int64
+	for i := 0; i < n; i++ {
+		pc := p.conns[(offset+i)%n]
+		if !pc.usable() {
+			continue
+		}
+		load := atomic.LoadInt64(&pc.inFlight)
+		if best == nil || load < bestLoad {
+			best, bestLoad = pc, load
+		}
+	}
+
+	return best
+}
+
+func (p *Pool) release(pc *pooledConn) {
+	atomic.AddInt64(&pc.inFlight, -1)
+	poolConnectionsInUse.WithLabelValues(p.service, pc.target).Dec()
+}
+
+func (p *Pool) healthLoop(interval time.Duration) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			p.checkReplicas(ctx)
+			cancel()
+		}
+	}
+}
+
+// checkReplicas runs the gRPC health check once per replica and marks all of
+// the replica's connections with the result. Backends report their overall
+// health under the empty service name; backends without the health service
+// are treated as healthy.
+func (p *Pool) checkReplicas(ctx context.Context) {
+	checked := make(map[string]bool)
+	for _, pc := range p.conns {
+		if _, done := checked[pc.target]; done {
+			continue
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		resp, err := grpc_health_v1.NewHealthClient(pc.conn).Check(checkCtx, &grpc_health_v1.HealthCheckRequest{})
+		cancel()
+
+		switch {
+		case err == nil:
+			checked[pc.target] = resp.Status == grpc_health_v1.HealthCheckResponse_SERVING
+		default:
+			checked[pc.target] = status.Code(err) == codes.Unimplemented
+		}
+	}
+
+	for _, pc := range p.conns {
+		healthy := int32(0)
+		if checked[pc.target] {
+			healthy = 1
+		}
+		atomic.StoreInt32(&pc.healthy, healthy)
+	}
+
+	for target, healthy := range checked {
+		value := 0.0
+		if healthy {
+			value = 1
+		}
+		poolReplicaHealthy.WithLabelValues(p.service, target).Set(value)
+	}
+}
+
+func (pc *pooledConn) usable() bool {
+	if atomic.LoadInt32(&pc.healthy) == 0 {
+		return false
+	}
+
+	switch pc.conn.GetState() {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return false
+	}
+	return true
+}
+
+// pooledStream returns its connection to the pool once the stream ends:
+// when a receive fails, when the single response of a stream without
+// server streaming arrives, or when the stream's context ends
+type pooledStream struct {
+	grpc.ClientStream
+	desc *grpc.StreamDesc
+	once sync.Once
+	done func()
+}
+
+func (s *pooledStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil || !s.desc.ServerStreams {
+		s.finish()
+	}
+	return err
+}
+
+func (s *pooledStream) finish() {
+	s.once.Do(s.done)
+}
+
+// splitTargets returns the replica addresses in a comma-separated service URL
+func splitTargets(url string) []string {
+	var targets []string
+	for _, target := range strings.Split(url, ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+var _ grpc.ClientConnInterface = (*Pool)(nil)
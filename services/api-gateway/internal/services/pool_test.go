@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"aegisshield/services/api-gateway/internal/config"
+)
+
+// startBackend serves a gRPC backend on a local port, with the health
+// service when healthServer is not nil
+func startBackend(t *testing.T, healthServer *health.Server) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer()
+	if healthServer != nil {
+		grpc_health_v1.RegisterHealthServer(server, healthServer)
+	}
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func testPool(t *testing.T, targets ...string) *Pool {
+	t.Helper()
+
+	pool, err := NewPool("entity-resolution", targets, config.PoolConfig{
+		ConnsPerReplica:  2,
+		CallTimeoutMs:    1000,
+		MinCallTimeoutMs: 10,
+		MaxWaitMs:        50,
+	}, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("new pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func inFlight(pool *Pool) int64 {
+	var total int64
+	for _, pc := range pool.conns {
+		total += atomic.LoadInt64(&pc.inFlight)
+	}
+	return total
+}
+
+func TestPoolHealthCheck(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("backends registering only overall health are healthy", func(t *testing.T) {
+		// Backends register health under "" only; a check for the service
+		// name would get NotFound
+		pool := testPool(t, startBackend(t, health.NewServer()))
+
+		if err := pool.HealthCheck(ctx); err != nil {
+			t.Fatalf("expected healthy pool, got %v", err)
+		}
+		if err := pool.Invoke(ctx, "/grpc.health.v1.Health/Check", &grpc_health_v1.HealthCheckRequest{}, &grpc_health_v1.HealthCheckResponse{}); err != nil {
+			t.Fatalf("expected call to succeed, got %v", err)
+		}
+	})
+
+	t.Run("backends without the health service are healthy", func(t *testing.T) {
+		pool := testPool(t, startBackend(t, nil))
+
+		if err := pool.HealthCheck(ctx); err != nil {
+			t.Fatalf("expected healthy pool, got %v", err)
+		}
+	})
+
+	t.Run("replicas not serving are skipped", func(t *testing.T) {
+		down := health.NewServer()
+		down.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		pool := testPool(t, startBackend(t, down), startBackend(t, health.NewServer()))
+
+		if err := pool.HealthCheck(ctx); err != nil {
+			t.Fatalf("expected one healthy replica, got %v", err)
+		}
+		for _, pc := range pool.conns {
+			healthy := atomic.LoadInt32(&pc.healthy) == 1
+			if healthy != (pc.target == pool.conns[len(pool.conns)-1].target) {
+				t.Errorf("replica %s healthy = %v", pc.target, healthy)
+			}
+		}
+	})
+
+	t.Run("calls fail when no replica is serving", func(t *testing.T) {
+		down := health.NewServer()
+		down.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		pool := testPool(t, startBackend(t, down))
+
+		if err := pool.HealthCheck(ctx); err == nil {
+			t.Fatal("expected unhealthy pool")
+		}
+		err := pool.Invoke(ctx, "/grpc.health.v1.Health/Check", &grpc_health_v1.HealthCheckRequest{}, &grpc_health_v1.HealthCheckResponse{})
+		if status.Code(err) != codes.Unavailable {
+			t.Fatalf("expected Unavailable, got %v", err)
+		}
+	})
+}
+
+func TestPoolStreamsRelease(t *testing.T) {
+	pool := testPool(t, startBackend(t, health.NewServer()))
+
+	t.Run("abandoned streams are released when their context ends", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := grpc_health_v1.NewHealthClient(pool).Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("watch: %v", err)
+		}
+		if _, err := stream.Recv(); err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		if got := inFlight(pool); got != 1 {
+			t.Fatalf("expected 1 call in flight, got %d", got)
+		}
+
+		// The stream is never read again
+		cancel()
+		waitForInFlight(t, pool, 0)
+	})
+
+	t.Run("streams closed for sending are released when their context ends", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		stream, err := grpc_health_v1.NewHealthClient(pool).Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("watch: %v", err)
+		}
+		if err := stream.CloseSend(); err != nil {
+			t.Fatalf("close send: %v", err)
+		}
+
+		waitForInFlight(t, pool, 0)
+	})
+
+	t.Run("streams are released when a receive fails", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, err := grpc_health_v1.NewHealthClient(pool).Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "unknown"})
+		if err != nil {
+			t.Fatalf("watch: %v", err)
+		}
+		if _, err := stream.Recv(); err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+
+		pool.Close()
+		if _, err := stream.Recv(); err == nil {
+			t.Fatal("expected receive to fail once the pool is closed")
+		}
+		waitForInFlight(t, pool, 0)
+	})
+}
+
+func waitForInFlight(t *testing.T, pool *Pool, want int64) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for inFlight(pool) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d calls in flight, got %d", want, inFlight(pool))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
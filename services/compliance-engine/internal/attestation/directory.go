@@ -0,0 +1,127 @@
+package attestation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aegisshield/compliance-engine/internal/config"
+)
+
+// Attester is a user a campaign target resolved to
+type Attester struct {
+	ID    string
+	Name  string
+	Email string
+	Role  string
+	Team  string
+}
+
+// Directory resolves campaign targets to the users who must attest
+type Directory interface {
+	FindAttesters(ctx context.Context, target Target) ([]Attester, error)
+}
+
+// UserDirectory resolves targets against the user-management service,
+// matching the target team to the user's department
+type UserDirectory struct {
+	baseURL  string
+	token    string
+	pageSize int
+	client   *http.Client
+}
+
+// NewUserDirectory creates a directory backed by the user-management service
+func NewUserDirectory(cfg config.AttestationDirectoryConfig) *UserDirectory {
+	return &UserDirectory{
+		baseURL:  strings.TrimRight(cfg.URL, "/"),
+		token:    cfg.Token,
+		pageSize: cfg.PageSize,
+		client:   &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+type directoryUser struct {
+	ID         uint   `json:"id"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	Role       string `json:"role"`
+	Department string `json:"department"`
+}
+
+type directoryPage struct {
+	Users      []directoryUser `json:"users"`
+	TotalPages int             `json:"total_pages"`
+}
+
+// FindAttesters pages through the active users matching the target
+func (d *UserDirectory) FindAttesters(ctx context.Context, target Target) ([]Attester, error) {
+	var attesters []Attester
+	for page := 1; ; page++ {
+		result, err := d.fetchPage(ctx, target, page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, user := range result.Users {
+			name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+			if name == "" {
+				name = user.Username
+			}
+			attesters = append(attesters, Attester{
+				ID:    strconv.FormatUint(uint64(user.ID), 10),
+				Name:  name,
+				Email: user.Email,
+				Role:  user.Role,
+				Team:  user.Department,
+			})
+		}
+
+		if page >= result.TotalPages {
+			return attesters, nil
+		}
+	}
+}
+
+func (d *UserDirectory) fetchPage(ctx context.Context, target Target, page int) (*directoryPage, error) {
+	query := url.Values{}
+	query.Set("active", "true")
+	query.Set("page", strconv.Itoa(page))
+	query.Set("limit", strconv.Itoa(d.pageSize))
+	if target.Role != "" {
+		query.Set("role", target.Role)
+	}
+	if target.Team != "" {
+		query.Set("department", target.Team)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.baseURL+"/users/?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build directory request: %w", err)
+	}
+	if d.token != "" {
+		req.Header.Set("Authorization", "Bearer "+d.token)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user directory: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user directory returned %s", resp.Status)
+	}
+
+	var result directoryPage
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode user directory response: %w", err)
+	}
+	return &result, nil
+}
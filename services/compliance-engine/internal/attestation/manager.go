@@ -0,0 +1,782 @@
+package attestation
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aegisshield/compliance-engine/internal/config"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrCampaignNotFound is returned for unknown campaign IDs
+	ErrCampaignNotFound = errors.New("attestation campaign not found")
+	// ErrAttestationNotFound is returned for unknown attestation IDs
+	ErrAttestationNotFound = errors.New("attestation not found")
+	// ErrInvalidCampaign is returned for campaign definitions that cannot be launched
+	ErrInvalidCampaign = errors.New("invalid attestation campaign")
+	// ErrInvalidSignature is returned for signatures missing what they attest to
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrCampaignState is returned when a campaign is not in the state an operation needs
+	ErrCampaignState = errors.New("attestation campaign is not in the required state")
+	// ErrAlreadySigned is returned when an attestation was already signed
+	ErrAlreadySigned = errors.New("attestation already signed")
+	// ErrNotPermitted is returned when the caller may not sign
+	ErrNotPermitted = errors.New("caller may not sign")
+)
+
+// CampaignInput defines a new campaign. Escalation defaults to the
+// configured levels when empty.
+type CampaignInput struct {
+	Name         string
+	Description  string
+	Period       string
+	Statement    string
+	Targets      []Target
+	DueAt        time.Time
+	Escalation   []EscalationLevel
+	SignOffRoles []string // the sign-off chain, in order
+}
+
+// SignInput is what a signer submits: the name they typed to sign and,
+// for attestations, any exceptions to the statement
+type SignInput struct {
+	SignerName string
+	Exceptions string
+	Decision   string // sign-off only: approved or rejected
+	Comment    string // sign-off only; required to reject
+	IPAddress  string
+	UserAgent  string
+}
+
+// Manager runs attestation campaigns: it resolves attesters, records their
+// signed attestations, reminds and escalates non-responders and walks each
+// campaign through its sign-off chain
+type Manager struct {
+	config       config.AttestationConfig
+	directory    Directory
+	logger       *zap.Logger
+	campaigns    map[string]*Campaign
+	attestations map[string]*Attestation
+	mu           sync.RWMutex
+	running      bool
+	stopChan     chan struct{}
+}
+
+// NewManager creates a new attestation manager
+func NewManager(cfg config.AttestationConfig, directory Directory, logger *zap.Logger) *Manager {
+	return &Manager{
+		config:       cfg,
+		directory:    directory,
+		logger:       logger,
+		campaigns:    make(map[string]*Campaign),
+		attestations: make(map[string]*Attestation),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Start starts the reminder and escalation loop
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return fmt.Errorf("attestation manager is already running")
+	}
+
+	m.logger.Info("Starting attestation manager")
+
+	go m.reminderLoop(ctx)
+
+	m.running = true
+	m.logger.Info("Attestation manager started successfully")
+
+	return nil
+}
+
+// Stop stops the attestation manager
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.running {
+		return nil
+	}
+
+	m.logger.Info("Stopping attestation manager")
+
+	close(m.stopChan)
+	m.running = false
+
+	m.logger.Info("Attestation manager stopped")
+	return nil
+}
+
+// CanManageCampaigns reports whether a caller with roles may create,
+// launch and cancel campaigns
+func (m *Manager) CanManageCampaigns(roles []string) bool {
+	for _, role := range m.config.CampaignRoles {
+		if hasRole(roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateCampaign validates and stores a draft campaign
+func (m *Manager) CreateCampaign(ctx context.Context, input CampaignInput, createdBy string) (*Campaign, error) {
+	campaign := &Campaign{
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+		Period:      strings.TrimSpace(input.Period),
+		Statement:   strings.TrimSpace(input.Statement),
+		DueAt:       input.DueAt,
+		Escalation:  input.Escalation,
+		Status:      CampaignStatusDraft,
+		CreatedBy:   createdBy,
+	}
+
+	switch {
+	case campaign.Name == "":
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidCampaign)
+	case campaign.Period == "":
+		return nil, fmt.Errorf("%w: period is required", ErrInvalidCampaign)
+	case campaign.Statement == "":
+		return nil, fmt.Errorf("%w: statement is required", ErrInvalidCampaign)
+	case !campaign.DueAt.After(time.Now()):
+		return nil, fmt.Errorf("%w: due date must be in the future", ErrInvalidCampaign)
+	case len(input.Targets) == 0:
+		return nil, fmt.Errorf("%w: at least one target is required", ErrInvalidCampaign)
+	case len(input.SignOffRoles) == 0:
+		return nil, fmt.Errorf("%w: the sign-off chain needs at least one role", ErrInvalidCampaign)
+	}
+
+	for _, target := range input.Targets {
+		target.Role = strings.TrimSpace(target.Role)
+		target.Team = strings.TrimSpace(target.Team)
+		if target.Role == "" && target.Team == "" {
+			return nil, fmt.Errorf("%w: a target needs a role, a team or both", ErrInvalidCampaign)
+		}
+		campaign.Targets = append(campaign.Targets, target)
+	}
+
+	for i, role := range input.SignOffRoles {
+		role = strings.TrimSpace(role)
+		if role == "" {
+			return nil, fmt.Errorf("%w: sign-off step %d has no role", ErrInvalidCampaign, i+1)
+		}
+		campaign.SignOffChain = append(campaign.SignOffChain, SignOffStep{
+			Order:    i + 1,
+			Role:     role,
+			Decision: SignOffPending,
+		})
+	}
+
+	if len(campaign.Escalation) == 0 {
+		for _, level := range m.config.DefaultEscalation {
+			campaign.Escalation = append(campaign.Escalation, EscalationLevel{
+				After:      level.After,
+				Recipients: level.Recipients,
+			})
+		}
+	}
+	for i, level := range campaign.Escalation {
+		if len(level.Recipients) == 0 {
+			return nil, fmt.Errorf("%w: escalation level %d has no recipients", ErrInvalidCampaign, i+1)
+		}
+		if level.After < 0 || (i > 0 && level.After <= campaign.Escalation[i-1].After) {
+			return nil, fmt.Errorf("%w: escalation levels must have increasing, non-negative delays", ErrInvalidCampaign)
+		}
+	}
+
+	hash := sha256.Sum256([]byte(campaign.Statement))
+	campaign.StatementHash = hex.EncodeToString(hash[:])
+
+	now := time.Now()
+	campaign.ID = m.generateCampaignID()
+	campaign.CreatedAt = now
+	campaign.UpdatedAt = now
+
+	m.mu.Lock()
+	m.campaigns[campaign.ID] = campaign
+	m.mu.Unlock()
+
+	m.logger.Info("Attestation campaign created",
+		zap.String("campaign_id", campaign.ID),
+		zap.String("period", campaign.Period),
+		zap.String("created_by", createdBy),
+	)
+
+	return copyCampaign(campaign), nil
+}
+
+// LaunchCampaign resolves a draft campaign's targets and opens an
+// attestation for every attester found. Users matched by several targets
+// attest once.
+func (m *Manager) LaunchCampaign(ctx context.Context, campaignID, launchedBy string) (*Campaign, error) {
+	campaign, err := m.GetCampaign(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != CampaignStatusDraft {
+		return nil, fmt.Errorf("%w: only draft campaigns can be launched", ErrCampaignState)
+	}
+
+	// resolve outside the lock, the directory is a remote call
+	attesters := make(map[string]Attester)
+	for _, target := range campaign.Targets {
+		found, err := m.directory.FindAttesters(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve attesters for role %q team %q: %w", target.Role, target.Team, err)
+		}
+		for _, attester := range found {
+			attesters[attester.ID] = attester
+		}
+	}
+	if len(attesters) == 0 {
+		return nil, fmt.Errorf("%w: no active users match the campaign targets", ErrInvalidCampaign)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, exists := m.campaigns[campaignID]
+	if !exists {
+		return nil, ErrCampaignNotFound
+	}
+	if stored.Status != CampaignStatusDraft {
+		return nil, fmt.Errorf("%w: only draft campaigns can be launched", ErrCampaignState)
+	}
+
+	now := time.Now()
+	for _, attester := range attesters {
+		attestation := &Attestation{
+			ID:            m.generateAttestationID(stored.ID, attester.ID),
+			CampaignID:    stored.ID,
+			AttesterID:    attester.ID,
+			AttesterName:  attester.Name,
+			AttesterEmail: attester.Email,
+			Role:          attester.Role,
+			Team:          attester.Team,
+			Status:        AttestationStatusPending,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		m.attestations[attestation.ID] = attestation
+	}
+
+	stored.Status = CampaignStatusActive
+	stored.LaunchedAt = &now
+	stored.UpdatedAt = now
+
+	m.logger.Info("Attestation campaign launched",
+		zap.String("campaign_id", stored.ID),
+		zap.Int("attesters", len(attesters)),
+		zap.String("launched_by", launchedBy),
+	)
+
+	return copyCampaign(stored), nil
+}
+
+// CancelCampaign cancels a campaign that has not been signed off
+func (m *Manager) CancelCampaign(ctx context.Context, campaignID, cancelledBy string) (*Campaign, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	campaign, exists := m.campaigns[campaignID]
+	if !exists {
+		return nil, ErrCampaignNotFound
+	}
+	switch campaign.Status {
+	case CampaignStatusCompleted, CampaignStatusRejected, CampaignStatusCancelled:
+		return nil, fmt.Errorf("%w: campaign is already %s", ErrCampaignState, campaign.Status)
+	}
+
+	now := time.Now()
+	campaign.Status = CampaignStatusCancelled
+	campaign.ClosedAt = &now
+	campaign.UpdatedAt = now
+
+	m.logger.Info("Attestation campaign cancelled",
+		zap.String("campaign_id", campaignID),
+		zap.String("cancelled_by", cancelledBy),
+	)
+
+	return copyCampaign(campaign), nil
+}
+
+// GetCampaign retrieves a campaign by ID
+func (m *Manager) GetCampaign(ctx context.Context, campaignID string) (*Campaign, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	campaign, exists := m.campaigns[campaignID]
+	if !exists {
+		return nil, ErrCampaignNotFound
+	}
+	return copyCampaign(campaign), nil
+}
+
+// ListCampaigns retrieves campaigns, newest first, optionally by status
+func (m *Manager) ListCampaigns(ctx context.Context, status string) []*Campaign {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	campaigns := make([]*Campaign, 0, len(m.campaigns))
+	for _, campaign := range m.campaigns {
+		if status == "" || campaign.Status == status {
+			campaigns = append(campaigns, copyCampaign(campaign))
+		}
+	}
+	sort.Slice(campaigns, func(i, j int) bool {
+		return campaigns[i].CreatedAt.After(campaigns[j].CreatedAt)
+	})
+	return campaigns
+}
+
+// ListAttestations retrieves a campaign's attestations, optionally by status
+func (m *Manager) ListAttestations(ctx context.Context, campaignID, status string) ([]*Attestation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.campaigns[campaignID]; !exists {
+		return nil, ErrCampaignNotFound
+	}
+	return m.attestationsWhere(func(a *Attestation) bool {
+		return a.CampaignID == campaignID && (status == "" || a.Status == status)
+	}), nil
+}
+
+// ListAttesterAttestations retrieves the attestations assigned to a user in
+// campaigns that are still collecting responses
+func (m *Manager) ListAttesterAttestations(ctx context.Context, attesterID string) []*Attestation {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.attestationsWhere(func(a *Attestation) bool {
+		campaign := m.campaigns[a.CampaignID]
+		return a.AttesterID == attesterID && campaign != nil && campaign.Status == CampaignStatusActive
+	})
+}
+
+// Attest records an attester's e-signed response. The campaign moves to
+// sign-off once every attester has responded.
+func (m *Manager) Attest(ctx context.Context, attestationID, signerID string, input SignInput) (*Attestation, error) {
+	signerName := strings.TrimSpace(input.SignerName)
+	if signerName == "" {
+		return nil, fmt.Errorf("%w: type your name to sign", ErrInvalidSignature)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attestation, exists := m.attestations[attestationID]
+	if !exists {
+		return nil, ErrAttestationNotFound
+	}
+	if attestation.AttesterID != signerID {
+		return nil, fmt.Errorf("%w: the attestation is assigned to another user", ErrNotPermitted)
+	}
+	if attestation.Status != AttestationStatusPending {
+		return nil, ErrAlreadySigned
+	}
+	campaign := m.campaigns[attestation.CampaignID]
+	if campaign.Status != CampaignStatusActive {
+		return nil, fmt.Errorf("%w: the campaign is %s", ErrCampaignState, campaign.Status)
+	}
+
+	status, intent := AttestationStatusAttested, "attest"
+	exceptions := strings.TrimSpace(input.Exceptions)
+	if exceptions != "" {
+		status, intent = AttestationStatusException, "attest_with_exceptions"
+	}
+
+	now := time.Now()
+	signature := &Signature{
+		SignerID:      signerID,
+		SignerName:    signerName,
+		Intent:        intent,
+		StatementHash: campaign.StatementHash,
+		SignedAt:      now,
+		IPAddress:     input.IPAddress,
+		UserAgent:     input.UserAgent,
+	}
+	signature.Seal = m.seal(attestation.ID, exceptions, signature)
+
+	attestation.Status = status
+	attestation.Exceptions = exceptions
+	attestation.Signature = signature
+	attestation.UpdatedAt = now
+
+	m.logger.Info("Attestation signed",
+		zap.String("attestation_id", attestation.ID),
+		zap.String("campaign_id", campaign.ID),
+		zap.String("status", status),
+	)
+
+	if m.allResponded(campaign.ID) {
+		campaign.Status = CampaignStatusSignOff
+		campaign.UpdatedAt = now
+		m.logger.Info("Attestation campaign ready for sign-off", zap.String("campaign_id", campaign.ID))
+	}
+
+	return copyAttestation(attestation), nil
+}
+
+// SignOff records the next decision in a campaign's sign-off chain. The
+// signer must hold the step's role and may not have signed an earlier step.
+// Approving the last step completes the campaign; rejecting any step
+// rejects it.
+func (m *Manager) SignOff(ctx context.Context, campaignID, signerID string, roles []string, input SignInput) (*Campaign, error) {
+	signerName := strings.TrimSpace(input.SignerName)
+	comment := strings.TrimSpace(input.Comment)
+	switch {
+	case signerName == "":
+		return nil, fmt.Errorf("%w: type your name to sign", ErrInvalidSignature)
+	case input.Decision != SignOffApproved && input.Decision != SignOffRejected:
+		return nil, fmt.Errorf("%w: decision must be approved or rejected", ErrInvalidSignature)
+	case input.Decision == SignOffRejected && comment == "":
+		return nil, fmt.Errorf("%w: a rejection needs a comment", ErrInvalidSignature)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	campaign, exists := m.campaigns[campaignID]
+	if !exists {
+		return nil, ErrCampaignNotFound
+	}
+	if campaign.Status != CampaignStatusSignOff {
+		return nil, fmt.Errorf("%w: the campaign is %s", ErrCampaignState, campaign.Status)
+	}
+
+	var step *SignOffStep
+	for i := range campaign.SignOffChain {
+		current := &campaign.SignOffChain[i]
+		if current.Decision == SignOffPending {
+			step = current
+			break
+		}
+		if current.Signature != nil && current.Signature.SignerID == signerID {
+			return nil, fmt.Errorf("%w: you signed step %d of this campaign", ErrNotPermitted, current.Order)
+		}
+	}
+	if step == nil {
+		return nil, fmt.Errorf("%w: the sign-off chain is complete", ErrCampaignState)
+	}
+	if !hasRole(roles, step.Role) {
+		return nil, fmt.Errorf("%w: step %d requires the %s role", ErrNotPermitted, step.Order, step.Role)
+	}
+
+	intent := "approve"
+	if input.Decision == SignOffRejected {
+		intent = "reject"
+	}
+
+	now := time.Now()
+	signature := &Signature{
+		SignerID:      signerID,
+		SignerName:    signerName,
+		Intent:        intent,
+		StatementHash: campaign.StatementHash,
+		SignedAt:      now,
+		IPAddress:     input.IPAddress,
+		UserAgent:     input.UserAgent,
+	}
+	signature.Seal = m.seal(stepSubject(campaign.ID, step.Order), comment, signature)
+
+	step.Decision = input.Decision
+	step.Comment = comment
+	step.Signature = signature
+	campaign.UpdatedAt = now
+
+	switch {
+	case input.Decision == SignOffRejected:
+		campaign.Status = CampaignStatusRejected
+		campaign.ClosedAt = &now
+	case step.Order == len(campaign.SignOffChain):
+		campaign.Status = CampaignStatusCompleted
+		campaign.ClosedAt = &now
+	}
+
+	m.logger.Info("Attestation campaign sign-off recorded",
+		zap.String("campaign_id", campaign.ID),
+		zap.Int("step", step.Order),
+		zap.String("decision", input.Decision),
+		zap.String("status", campaign.Status),
+	)
+
+	return copyCampaign(campaign), nil
+}
+
+// GetProgress summarizes a campaign's responses
+func (m *Manager) GetProgress(ctx context.Context, campaignID string) (*Progress, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	campaign, exists := m.campaigns[campaignID]
+	if !exists {
+		return nil, ErrCampaignNotFound
+	}
+
+	progress := &Progress{CampaignID: campaign.ID, Status: campaign.Status}
+	now := time.Now()
+	for _, attestation := range m.attestations {
+		if attestation.CampaignID != campaignID {
+			continue
+		}
+		progress.Total++
+		switch attestation.Status {
+		case AttestationStatusAttested:
+			progress.Attested++
+		case AttestationStatusException:
+			progress.Exceptions++
+		default:
+			progress.Pending++
+			if now.After(campaign.DueAt) {
+				progress.Overdue++
+			}
+		}
+		if attestation.EscalationLevel > 0 {
+			progress.Escalated++
+		}
+	}
+	if progress.Total > 0 {
+		progress.CompletionRate = float64(progress.Attested+progress.Exceptions) / float64(progress.Total)
+	}
+
+	return progress, nil
+}
+
+// Evidence returns the evidence rows for a campaign: every attestation
+// followed by the sign-off chain, each with whether its seal verifies
+func (m *Manager) Evidence(ctx context.Context, campaignID string) ([]EvidenceRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	campaign, exists := m.campaigns[campaignID]
+	if !exists {
+		return nil, ErrCampaignNotFound
+	}
+
+	base := EvidenceRecord{
+		CampaignID:     campaign.ID,
+		CampaignName:   campaign.Name,
+		Period:         campaign.Period,
+		CampaignStatus: campaign.Status,
+		StatementHash:  campaign.StatementHash,
+		DueAt:          campaign.DueAt,
+	}
+
+	attestations := m.attestationsWhere(func(a *Attestation) bool { return a.CampaignID == campaignID })
+	records := make([]EvidenceRecord, 0, len(attestations)+len(campaign.SignOffChain))
+	for _, attestation := range attestations {
+		record := base
+		record.RecordType = EvidenceAttestation
+		record.RecordID = attestation.ID
+		record.SignerID = attestation.AttesterID
+		record.SignerName = attestation.AttesterName
+		record.Email = attestation.AttesterEmail
+		record.Role = attestation.Role
+		record.Team = attestation.Team
+		record.Outcome = attestation.Status
+		record.Remarks = attestation.Exceptions
+		record.RemindersSent = attestation.RemindersSent
+		record.EscalationLevel = attestation.EscalationLevel
+		if sig := attestation.Signature; sig != nil {
+			m.signedEvidence(&record, sig, m.seal(attestation.ID, attestation.Exceptions, sig))
+			record.Late = sig.SignedAt.After(campaign.DueAt)
+		}
+		records = append(records, record)
+	}
+
+	for _, step := range campaign.SignOffChain {
+		record := base
+		record.RecordType = EvidenceSignOff
+		record.RecordID = strconv.Itoa(step.Order)
+		record.Role = step.Role
+		record.Outcome = step.Decision
+		record.Remarks = step.Comment
+		if sig := step.Signature; sig != nil {
+			m.signedEvidence(&record, sig, m.seal(stepSubject(campaign.ID, step.Order), step.Comment, sig))
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (m *Manager) signedEvidence(record *EvidenceRecord, sig *Signature, expectedSeal string) {
+	signedAt := sig.SignedAt
+	record.SignerID = sig.SignerID
+	record.SignerName = sig.SignerName
+	record.SignedAt = &signedAt
+	record.IPAddress = sig.IPAddress
+	record.UserAgent = sig.UserAgent
+	record.Seal = sig.Seal
+	record.SealValid = hmac.Equal([]byte(sig.Seal), []byte(expectedSeal))
+}
+
+func (m *Manager) reminderLoop(ctx context.Context) {
+	ticker := time.NewTicker(m.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			m.processReminders(ctx, time.Now())
+		}
+	}
+}
+
+// processReminders reminds pending attesters every reminder interval and,
+// once a campaign is past due, escalates them through its escalation levels
+func (m *Manager) processReminders(ctx context.Context, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, attestation := range m.attestations {
+		campaign := m.campaigns[attestation.CampaignID]
+		if attestation.Status != AttestationStatusPending || campaign.Status != CampaignStatusActive {
+			continue
+		}
+
+		lastReminded := attestation.CreatedAt
+		if attestation.LastRemindedAt != nil {
+			lastReminded = *attestation.LastRemindedAt
+		}
+		if now.Sub(lastReminded) >= m.config.ReminderInterval {
+			m.notify("Sending attestation reminder", attestation, campaign, []string{attestation.AttesterID})
+			attestation.RemindersSent++
+			attestation.LastRemindedAt = &now
+			attestation.UpdatedAt = now
+		}
+
+		level := 0
+		for i, escalation := range campaign.Escalation {
+			if !now.Before(campaign.DueAt.Add(escalation.After)) {
+				level = i + 1
+			}
+		}
+		if level <= attestation.EscalationLevel {
+			continue
+		}
+
+		var recipients []string
+		for _, escalation := range campaign.Escalation[attestation.EscalationLevel:level] {
+			recipients = append(recipients, escalation.Recipients...)
+		}
+		m.notify("Escalating overdue attestation", attestation, campaign, recipients)
+		attestation.EscalationLevel = level
+		attestation.EscalatedAt = &now
+		attestation.EscalatedTo = append(attestation.EscalatedTo, recipients...)
+		attestation.UpdatedAt = now
+	}
+}
+
+func (m *Manager) notify(message string, attestation *Attestation, campaign *Campaign, recipients []string) {
+	m.logger.Info(message,
+		zap.String("attestation_id", attestation.ID),
+		zap.String("campaign_id", campaign.ID),
+		zap.String("attester_id", attestation.AttesterID),
+		zap.Time("due_at", campaign.DueAt),
+		zap.Strings("recipients", recipients),
+	)
+	// Implementation would deliver the notification to the recipients
+}
+
+// seal computes the HMAC binding a signature to its subject, the remarks
+// signed with it and the campaign statement
+func (m *Manager) seal(subject, remarks string, sig *Signature) string {
+	mac := hmac.New(sha256.New, []byte(m.config.SigningKey))
+	for _, field := range []string{
+		subject,
+		sig.SignerID,
+		sig.SignerName,
+		sig.Intent,
+		sig.StatementHash,
+		remarks,
+		sig.SignedAt.UTC().Format(time.RFC3339Nano),
+		sig.IPAddress,
+		sig.UserAgent,
+	} {
+		mac.Write([]byte(field))
+		mac.Write([]byte{0})
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (m *Manager) allResponded(campaignID string) bool {
+	for _, attestation := range m.attestations {
+		if attestation.CampaignID == campaignID && attestation.Status == AttestationStatusPending {
+			return false
+		}
+	}
+	return true
+}
+
+// attestationsWhere returns copies of the matching attestations ordered by
+// attester; callers hold the lock
+func (m *Manager) attestationsWhere(match func(*Attestation) bool) []*Attestation {
+	var attestations []*Attestation
+	for _, attestation := range m.attestations {
+		if match(attestation) {
+			attestations = append(attestations, copyAttestation(attestation))
+		}
+	}
+	sort.Slice(attestations, func(i, j int) bool {
+		if attestations[i].CampaignID != attestations[j].CampaignID {
+			return attestations[i].CampaignID < attestations[j].CampaignID
+		}
+		return attestations[i].AttesterName < attestations[j].AttesterName
+	})
+	return attestations
+}
+
+func (m *Manager) generateCampaignID() string {
+	return fmt.Sprintf("ATC_%d", time.Now().UnixNano())
+}
+
+func (m *Manager) generateAttestationID(campaignID, attesterID string) string {
+	return fmt.Sprintf("%s_%s", strings.Replace(campaignID, "ATC_", "ATT_", 1), attesterID)
+}
+
+func stepSubject(campaignID string, order int) string {
+	return fmt.Sprintf("%s/sign_off/%d", campaignID, order)
+}
+
+func copyCampaign(campaign *Campaign) *Campaign {
+	copied := *campaign
+	copied.Targets = append([]Target(nil), campaign.Targets...)
+	copied.Escalation = append([]EscalationLevel(nil), campaign.Escalation...)
+	copied.SignOffChain = append([]SignOffStep(nil), campaign.SignOffChain...)
+	return &copied
+}
+
+func copyAttestation(attestation *Attestation) *Attestation {
+	copied := *attestation
+	copied.EscalatedTo = append([]string(nil), attestation.EscalatedTo...)
+	return &copied
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,313 @@
+package attestation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/aegisshield/compliance-engine/internal/config"
+)
+
+// stubDirectory resolves targets to fixed attesters keyed by role
+type stubDirectory map[string][]Attester
+
+func (d stubDirectory) FindAttesters(ctx context.Context, target Target) ([]Attester, error) {
+	return d[target.Role], nil
+}
+
+func newTestManager() *Manager {
+	cfg := config.AttestationConfig{
+		CheckInterval:    time.Minute,
+		ReminderInterval: 24 * time.Hour,
+		SigningKey:       "test-signing-key",
+		CampaignRoles:    []string{"compliance"},
+	}
+	directory := stubDirectory{
+		"analyst": {
+			{ID: "1", Name: "Ann Analyst", Email: "ann@example.com", Role: "analyst", Team: "AML"},
+			{ID: "2", Name: "Bob Analyst", Email: "bob@example.com", Role: "analyst", Team: "Fraud"},
+		},
+	}
+	return NewManager(cfg, directory, zap.NewNop())
+}
+
+// launchCampaign creates and launches a campaign due in a week with a
+// two-step sign-off chain and two escalation levels
+func launchCampaign(t *testing.T, m *Manager) *Campaign {
+	t.Helper()
+	ctx := context.Background()
+
+	campaign, err := m.CreateCampaign(ctx, CampaignInput{
+		Name:      "Q3 access review",
+		Period:    "2026-Q3",
+		Statement: "I reviewed the access of everyone reporting to me.",
+		Targets:   []Target{{Role: "analyst"}},
+		DueAt:     time.Now().Add(7 * 24 * time.Hour),
+		Escalation: []EscalationLevel{
+			{After: 0, Recipients: []string{"team-lead"}},
+			{After: 48 * time.Hour, Recipients: []string{"head-of-compliance"}},
+		},
+		SignOffRoles: []string{"compliance", "admin"},
+	}, "officer-1")
+	require.NoError(t, err)
+
+	campaign, err = m.LaunchCampaign(ctx, campaign.ID, "officer-1")
+	require.NoError(t, err)
+	return campaign
+}
+
+// attestAll signs every pending attestation of a campaign, the first with
+// exceptions
+func attestAll(t *testing.T, m *Manager, campaignID string) {
+	t.Helper()
+	ctx := context.Background()
+
+	attestations, err := m.ListAttestations(ctx, campaignID, AttestationStatusPending)
+	require.NoError(t, err)
+	for i, attestation := range attestations {
+		input := SignInput{SignerName: attestation.AttesterName, IPAddress: "10.0.0.1", UserAgent: "test"}
+		if i == 0 {
+			input.Exceptions = "Two contractors were not reviewed"
+		}
+		_, err := m.Attest(ctx, attestation.ID, attestation.AttesterID, input)
+		require.NoError(t, err)
+	}
+}
+
+func approve(signerName string) SignInput {
+	return SignInput{SignerName: signerName, Decision: SignOffApproved}
+}
+
+func TestSignOffChain(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sign-off waits for every attester", func(t *testing.T) {
+		m := newTestManager()
+		campaign := launchCampaign(t, m)
+
+		_, err := m.SignOff(ctx, campaign.ID, "officer-2", []string{"compliance"}, approve("Carol Officer"))
+		assert.ErrorIs(t, err, ErrCampaignState)
+
+		attestAll(t, m, campaign.ID)
+		campaign, err = m.GetCampaign(ctx, campaign.ID)
+		require.NoError(t, err)
+		assert.Equal(t, CampaignStatusSignOff, campaign.Status)
+	})
+
+	t.Run("steps are signed in order", func(t *testing.T) {
+		m := newTestManager()
+		campaign := launchCampaign(t, m)
+		attestAll(t, m, campaign.ID)
+
+		// The second step's role cannot jump ahead of the first
+		_, err := m.SignOff(ctx, campaign.ID, "admin-1", []string{"admin"}, approve("Dan Admin"))
+		assert.ErrorIs(t, err, ErrNotPermitted)
+
+		campaign, err = m.SignOff(ctx, campaign.ID, "officer-2", []string{"compliance"}, approve("Carol Officer"))
+		require.NoError(t, err)
+		assert.Equal(t, CampaignStatusSignOff, campaign.Status)
+		assert.Equal(t, SignOffApproved, campaign.SignOffChain[0].Decision)
+		assert.Equal(t, SignOffPending, campaign.SignOffChain[1].Decision)
+
+		campaign, err = m.SignOff(ctx, campaign.ID, "admin-1", []string{"admin"}, approve("Dan Admin"))
+		require.NoError(t, err)
+		assert.Equal(t, CampaignStatusCompleted, campaign.Status)
+		assert.NotNil(t, campaign.ClosedAt)
+
+		_, err = m.SignOff(ctx, campaign.ID, "admin-2", []string{"admin"}, approve("Eve Admin"))
+		assert.ErrorIs(t, err, ErrCampaignState)
+	})
+
+	t.Run("one person cannot sign two steps", func(t *testing.T) {
+		m := newTestManager()
+		campaign := launchCampaign(t, m)
+		attestAll(t, m, campaign.ID)
+
+		roles := []string{"compliance", "admin"}
+		_, err := m.SignOff(ctx, campaign.ID, "officer-2", roles, approve("Carol Officer"))
+		require.NoError(t, err)
+
+		_, err = m.SignOff(ctx, campaign.ID, "officer-2", roles, approve("Carol Officer"))
+		assert.ErrorIs(t, err, ErrNotPermitted)
+	})
+
+	t.Run("rejecting a step rejects the campaign", func(t *testing.T) {
+		m := newTestManager()
+		campaign := launchCampaign(t, m)
+		attestAll(t, m, campaign.ID)
+
+		_, err := m.SignOff(ctx, campaign.ID, "officer-2", []string{"compliance"},
+			SignInput{SignerName: "Carol Officer", Decision: SignOffRejected})
+		assert.ErrorIs(t, err, ErrInvalidSignature, "a rejection needs a comment")
+
+		campaign, err = m.SignOff(ctx, campaign.ID, "officer-2", []string{"compliance"},
+			SignInput{SignerName: "Carol Officer", Decision: SignOffRejected, Comment: "Contractors must be reviewed"})
+		require.NoError(t, err)
+		assert.Equal(t, CampaignStatusRejected, campaign.Status)
+		assert.Equal(t, SignOffPending, campaign.SignOffChain[1].Decision)
+	})
+}
+
+func TestEscalation(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager()
+	campaign := launchCampaign(t, m)
+
+	// Ann attests, Bob stays pending
+	attestations, err := m.ListAttestations(ctx, campaign.ID, "")
+	require.NoError(t, err)
+	require.Len(t, attestations, 2)
+	_, err = m.Attest(ctx, attestations[0].ID, attestations[0].AttesterID, SignInput{SignerName: "Ann Analyst"})
+	require.NoError(t, err)
+	pendingID := attestations[1].ID
+
+	pending := func() *Attestation {
+		t.Helper()
+		list, err := m.ListAttestations(ctx, campaign.ID, AttestationStatusPending)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		require.Equal(t, pendingID, list[0].ID)
+		return list[0]
+	}
+	due := campaign.DueAt
+
+	t.Run("reminders before the due date", func(t *testing.T) {
+		created := pending().CreatedAt
+
+		m.processReminders(ctx, created.Add(time.Hour))
+		assert.Equal(t, 0, pending().RemindersSent, "too soon for a reminder")
+
+		m.processReminders(ctx, created.Add(24*time.Hour))
+		assert.Equal(t, 1, pending().RemindersSent)
+		assert.Equal(t, 0, pending().EscalationLevel)
+	})
+
+	t.Run("first level at the due date", func(t *testing.T) {
+		m.processReminders(ctx, due.Add(-time.Second))
+		assert.Equal(t, 0, pending().EscalationLevel)
+
+		m.processReminders(ctx, due)
+		attestation := pending()
+		assert.Equal(t, 1, attestation.EscalationLevel)
+		assert.Equal(t, []string{"team-lead"}, attestation.EscalatedTo)
+	})
+
+	t.Run("next level only after its delay", func(t *testing.T) {
+		m.processReminders(ctx, due.Add(47*time.Hour))
+		assert.Equal(t, 1, pending().EscalationLevel)
+
+		m.processReminders(ctx, due.Add(48*time.Hour))
+		attestation := pending()
+		assert.Equal(t, 2, attestation.EscalationLevel)
+		assert.Equal(t, []string{"team-lead", "head-of-compliance"}, attestation.EscalatedTo)
+
+		m.processReminders(ctx, due.Add(72*time.Hour))
+		assert.Equal(t, []string{"team-lead", "head-of-compliance"}, pending().EscalatedTo, "levels are not repeated")
+	})
+
+	t.Run("signed attestations are not chased", func(t *testing.T) {
+		signed, err := m.ListAttestations(ctx, campaign.ID, AttestationStatusAttested)
+		require.NoError(t, err)
+		require.Len(t, signed, 1)
+		assert.Equal(t, 0, signed[0].RemindersSent)
+		assert.Equal(t, 0, signed[0].EscalationLevel)
+	})
+
+	t.Run("a late attester skipping levels gets every recipient at once", func(t *testing.T) {
+		late := newTestManager()
+		lateCampaign := launchCampaign(t, late)
+
+		late.processReminders(ctx, lateCampaign.DueAt.Add(72*time.Hour))
+		list, err := late.ListAttestations(ctx, lateCampaign.ID, AttestationStatusPending)
+		require.NoError(t, err)
+		for _, attestation := range list {
+			assert.Equal(t, 2, attestation.EscalationLevel)
+			assert.Equal(t, []string{"team-lead", "head-of-compliance"}, attestation.EscalatedTo)
+		}
+	})
+}
+
+func TestEvidence(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager()
+	campaign := launchCampaign(t, m)
+	attestAll(t, m, campaign.ID)
+	_, err := m.SignOff(ctx, campaign.ID, "officer-2", []string{"compliance"},
+		SignInput{SignerName: "Carol Officer", Decision: SignOffApproved, Comment: "Exceptions accepted", IPAddress: "10.0.0.5"})
+	require.NoError(t, err)
+
+	records, err := m.Evidence(ctx, campaign.ID)
+	require.NoError(t, err)
+
+	t.Run("one row per attestation then per sign-off step", func(t *testing.T) {
+		require.Len(t, records, 4)
+		var types []string
+		for _, record := range records {
+			types = append(types, record.RecordType)
+			assert.Equal(t, campaign.ID, record.CampaignID)
+			assert.Equal(t, "2026-Q3", record.Period)
+			assert.Equal(t, CampaignStatusSignOff, record.CampaignStatus)
+			assert.Equal(t, campaign.StatementHash, record.StatementHash)
+		}
+		assert.Equal(t, []string{EvidenceAttestation, EvidenceAttestation, EvidenceSignOff, EvidenceSignOff}, types)
+	})
+
+	t.Run("attestation rows carry the signature", func(t *testing.T) {
+		ann := records[0]
+		assert.Equal(t, "1", ann.SignerID)
+		assert.Equal(t, "Ann Analyst", ann.SignerName)
+		assert.Equal(t, "ann@example.com", ann.Email)
+		assert.Equal(t, "AML", ann.Team)
+		assert.Equal(t, AttestationStatusException, ann.Outcome)
+		assert.Equal(t, "Two contractors were not reviewed", ann.Remarks)
+		assert.Equal(t, "10.0.0.1", ann.IPAddress)
+		require.NotNil(t, ann.SignedAt)
+		assert.False(t, ann.Late)
+		assert.True(t, ann.SealValid)
+
+		assert.Equal(t, AttestationStatusAttested, records[1].Outcome)
+		assert.Empty(t, records[1].Remarks)
+	})
+
+	t.Run("sign-off rows follow the chain", func(t *testing.T) {
+		signed, open := records[2], records[3]
+		assert.Equal(t, "1", signed.RecordID)
+		assert.Equal(t, "compliance", signed.Role)
+		assert.Equal(t, SignOffApproved, signed.Outcome)
+		assert.Equal(t, "Exceptions accepted", signed.Remarks)
+		assert.Equal(t, "officer-2", signed.SignerID)
+		assert.True(t, signed.SealValid)
+
+		assert.Equal(t, "2", open.RecordID)
+		assert.Equal(t, "admin", open.Role)
+		assert.Equal(t, SignOffPending, open.Outcome)
+		assert.Nil(t, open.SignedAt)
+		assert.Empty(t, open.Seal)
+		assert.False(t, open.SealValid)
+	})
+
+	t.Run("tampered signatures no longer verify", func(t *testing.T) {
+		attestations, err := m.ListAttestations(ctx, campaign.ID, "")
+		require.NoError(t, err)
+
+		m.mu.Lock()
+		m.attestations[attestations[0].ID].Exceptions = ""
+		m.campaigns[campaign.ID].SignOffChain[0].Signature.SignerName = "Someone Else"
+		m.mu.Unlock()
+
+		tampered, err := m.Evidence(ctx, campaign.ID)
+		require.NoError(t, err)
+		assert.False(t, tampered[0].SealValid)
+		assert.True(t, tampered[1].SealValid)
+		assert.False(t, tampered[2].SealValid)
+	})
+
+	t.Run("unknown campaigns have no evidence", func(t *testing.T) {
+		_, err := m.Evidence(ctx, "ATC_missing")
+		assert.ErrorIs(t, err, ErrCampaignNotFound)
+	})
+}
@@ -0,0 +1,158 @@
+package attestation
+
+import "time"
+
+// Campaign statuses. A campaign is drafted, launched to its attesters, sent
+// for sign-off once every attester has responded and closed by its sign-off
+// chain.
+const (
+	CampaignStatusDraft     = "draft"
+	CampaignStatusActive    = "active"
+	CampaignStatusSignOff   = "sign_off"
+	CampaignStatusCompleted = "completed"
+	CampaignStatusRejected  = "rejected"
+	CampaignStatusCancelled = "cancelled"
+)
+
+// Attestation statuses. An attester either attests to the campaign
+// statement or attests with exceptions, explaining which reviews were not
+// completed.
+const (
+	AttestationStatusPending   = "pending"
+	AttestationStatusAttested  = "attested"
+	AttestationStatusException = "exception"
+)
+
+// Sign-off step decisions
+const (
+	SignOffPending  = "pending"
+	SignOffApproved = "approved"
+	SignOffRejected = "rejected"
+)
+
+// Campaign asks a set of attesters to attest to a statement for a period,
+// e.g. that quarterly access reviews were completed
+type Campaign struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Description   string            `json:"description"`
+	Period        string            `json:"period"` // e.g. 2026-Q3
+	Statement     string            `json:"statement"`
+	StatementHash string            `json:"statement_hash"` // SHA-256 of the statement every signature refers to
+	Targets       []Target          `json:"targets"`
+	DueAt         time.Time         `json:"due_at"`
+	Escalation    []EscalationLevel `json:"escalation"`
+	SignOffChain  []SignOffStep     `json:"sign_off_chain"`
+	Status        string            `json:"status"`
+	CreatedBy     string            `json:"created_by"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	LaunchedAt    *time.Time        `json:"launched_at,omitempty"`
+	ClosedAt      *time.Time        `json:"closed_at,omitempty"`
+}
+
+// Target selects the active users holding Role in Team. Either may be left
+// empty, but not both.
+type Target struct {
+	Role string `json:"role,omitempty"`
+	Team string `json:"team,omitempty"`
+}
+
+// EscalationLevel notifies Recipients about attesters still pending After
+// the campaign due date
+type EscalationLevel struct {
+	After      time.Duration `json:"after"`
+	Recipients []string      `json:"recipients"`
+}
+
+// SignOffStep is one approval in a campaign's sign-off chain. Steps are
+// signed in order, each by a holder of Role.
+type SignOffStep struct {
+	Order     int        `json:"order"`
+	Role      string     `json:"role"`
+	Decision  string     `json:"decision"`
+	Comment   string     `json:"comment,omitempty"`
+	Signature *Signature `json:"signature,omitempty"`
+}
+
+// Attestation is one attester's response to a campaign
+type Attestation struct {
+	ID              string     `json:"id"`
+	CampaignID      string     `json:"campaign_id"`
+	AttesterID      string     `json:"attester_id"`
+	AttesterName    string     `json:"attester_name"`
+	AttesterEmail   string     `json:"attester_email"`
+	Role            string     `json:"role"`
+	Team            string     `json:"team"`
+	Status          string     `json:"status"`
+	Exceptions      string     `json:"exceptions,omitempty"`
+	Signature       *Signature `json:"signature,omitempty"`
+	RemindersSent   int        `json:"reminders_sent"`
+	LastRemindedAt  *time.Time `json:"last_reminded_at,omitempty"`
+	EscalationLevel int        `json:"escalation_level"`
+	EscalatedAt     *time.Time `json:"escalated_at,omitempty"`
+	EscalatedTo     []string   `json:"escalated_to,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Signature is the e-sign metadata captured when an attester or sign-off
+// approver signs. Seal is an HMAC over the signed fields, so a signature
+// altered after the fact no longer verifies.
+type Signature struct {
+	SignerID      string    `json:"signer_id"`
+	SignerName    string    `json:"signer_name"` // the name the signer typed to sign
+	Intent        string    `json:"intent"`      // what was signed: attest, attest_with_exceptions, approve or reject
+	StatementHash string    `json:"statement_hash"`
+	SignedAt      time.Time `json:"signed_at"`
+	IPAddress     string    `json:"ip_address"`
+	UserAgent     string    `json:"user_agent"`
+	Seal          string    `json:"seal"`
+}
+
+// Progress summarizes the responses to a campaign
+type Progress struct {
+	CampaignID     string  `json:"campaign_id"`
+	Status         string  `json:"status"`
+	Total          int     `json:"total"`
+	Attested       int     `json:"attested"`
+	Exceptions     int     `json:"exceptions"`
+	Pending        int     `json:"pending"`
+	Overdue        int     `json:"overdue"`
+	Escalated      int     `json:"escalated"`
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+// Evidence record types: one row per attestation and one per sign-off step
+const (
+	EvidenceAttestation = "attestation"
+	EvidenceSignOff     = "sign_off"
+)
+
+// EvidenceRecord is one row of the evidence auditors receive for a campaign.
+// SealValid reports whether the signature still verifies against its seal.
+type EvidenceRecord struct {
+	RecordType      string     `json:"record_type"`
+	CampaignID      string     `json:"campaign_id"`
+	CampaignName    string     `json:"campaign_name"`
+	Period          string     `json:"period"`
+	CampaignStatus  string     `json:"campaign_status"`
+	StatementHash   string     `json:"statement_hash"`
+	RecordID        string     `json:"record_id"` // attestation ID, or the sign-off step order
+	SignerID        string     `json:"signer_id"`
+	SignerName      string     `json:"signer_name"`
+	Email           string     `json:"email,omitempty"`
+	Role            string     `json:"role"`
+	Team            string     `json:"team,omitempty"`
+	Outcome         string     `json:"outcome"`
+	Remarks         string     `json:"remarks,omitempty"` // exceptions or sign-off comment
+	DueAt           time.Time  `json:"due_at"`
+	SignedAt        *time.Time `json:"signed_at,omitempty"`
+	Late            bool       `json:"late"`
+	RemindersSent   int        `json:"reminders_sent"`
+	EscalationLevel int        `json:"escalation_level"`
+	IPAddress       string     `json:"ip_address,omitempty"`
+	UserAgent       string     `json:"user_agent,omitempty"`
+	Seal            string     `json:"seal,omitempty"`
+	SealValid       bool       `json:"seal_valid"`
+}
@@ -26,6 +26,8 @@ type AuditLogger struct {
 	logChannel  chan *compliance.AuditLog
 	batchBuffer []*compliance.AuditLog
 	lastFlush   time.Time
+	wormWriter  WORMWriter
+	lockedUntil map[string]time.Time
 }
 
 // AuditCategory represents an audit category configuration
@@ -40,7 +42,7 @@ type AuditCategory struct {
 
 // NewAuditLogger creates a new audit logger instance
 func NewAuditLogger(cfg config.AuditConfig, logger *zap.Logger) *AuditLogger {
-	return &AuditLogger{
+	al := &AuditLogger{
 		config:      cfg,
 		logger:      logger,
 		auditLogs:   make(map[string]*compliance.AuditLog),
@@ -49,7 +51,14 @@ func NewAuditLogger(cfg config.AuditConfig, logger *zap.Logger) *AuditLogger {
 		logChannel:  make(chan *compliance.AuditLog, cfg.BufferSize),
 		batchBuffer: make([]*compliance.AuditLog, 0, cfg.BatchSize),
 		lastFlush:   time.Now(),
+		lockedUntil: make(map[string]time.Time),
 	}
+
+	if cfg.WORM.Enabled {
+		al.wormWriter = NewS3ObjectLockWriter(cfg.WORM, logger)
+	}
+
+	return al
 }
 
 // Start starts the audit logger
@@ -68,6 +77,13 @@ func (al *AuditLogger) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to load audit categories: %w", err)
 	}
 
+	// Refuse to start if audit logs cannot be written to write-once storage
+	if al.wormWriter != nil {
+		if err := al.wormWriter.VerifyObjectLock(ctx); err != nil {
+			return fmt.Errorf("failed to verify WORM audit storage: %w", err)
+		}
+	}
+
 	// Start background processes
 	go al.logProcessingLoop(ctx)
 	go al.retentionLoop(ctx)
@@ -307,8 +323,15 @@ func (al *AuditLogger) ArchiveOldLogs(ctx context.Context, archiveBefore time.Ti
 	}
 
 	archivedCount := 0
+	lockedCount := 0
+	now := time.Now()
 	for id, log := range al.auditLogs {
 		if log.Timestamp.Before(archiveBefore) {
+			if al.isLocked(id, now) {
+				lockedCount++
+				continue
+			}
+
 			// Archive log (simplified implementation - would typically export to external storage)
 			if err := al.archiveLog(log); err != nil {
 				al.logger.Error("Failed to archive audit log",
@@ -319,12 +342,14 @@ func (al *AuditLogger) ArchiveOldLogs(ctx context.Context, archiveBefore time.Ti
 			}
 
 			delete(al.auditLogs, id)
+			delete(al.lockedUntil, id)
 			archivedCount++
 		}
 	}
 
 	al.logger.Info("Archived old audit logs",
 		zap.Int("count", archivedCount),
+		zap.Int("retention_locked", lockedCount),
 		zap.Time("before", archiveBefore),
 	)
 
@@ -464,16 +489,29 @@ func (al *AuditLogger) flushBatch() {
 	}
 
 	// Store logs in memory (in production, would write to persistent storage)
+	var pending []*compliance.AuditLog
 	for _, log := range al.batchBuffer {
+		// Logs that could not be locked stay in the batch and are retried on the next flush
+		if al.wormWriter != nil {
+			if err := al.writeToWORM(log); err != nil {
+				al.logger.Error("Failed to write audit log to WORM storage",
+					zap.String("log_id", log.ID),
+					zap.Error(err),
+				)
+				pending = append(pending, log)
+				continue
+			}
+		}
 		al.auditLogs[log.ID] = log
 	}
 
 	al.logger.Debug("Flushed audit log batch",
-		zap.Int("count", len(al.batchBuffer)),
+		zap.Int("count", len(al.batchBuffer)-len(pending)),
+		zap.Int("pending", len(pending)),
 	)
 
 	// Clear batch
-	al.batchBuffer = al.batchBuffer[:0]
+	al.batchBuffer = append(al.batchBuffer[:0], pending...)
 	al.lastFlush = time.Now()
 }
 
@@ -494,6 +532,11 @@ func (al *AuditLogger) enforceRetentionPolicies() {
 		}
 
 		if now.Sub(log.Timestamp) > category.RetentionPeriod {
+			// Retention locks outlive shorter category retention periods
+			if al.isLocked(id, now) {
+				continue
+			}
+
 			// Archive before deletion if required
 			if al.config.ArchiveBeforeDelete {
 				if err := al.archiveLog(log); err != nil {
@@ -506,6 +549,7 @@ func (al *AuditLogger) enforceRetentionPolicies() {
 			}
 
 			delete(al.auditLogs, id)
+			delete(al.lockedUntil, id)
 			deletedCount++
 		}
 	}
@@ -181,6 +181,7 @@ type Report struct {
 	GeneratedAt  time.Time              `json:"generated_at" bson:"generated_at"`
 	ScheduledFor time.Time              `json:"scheduled_for" bson:"scheduled_for"`
 	Recipients   []string               `json:"recipients" bson:"recipients"`
+	RedactionProfile string             `json:"redaction_profile" bson:"redaction_profile"`
 	Metadata     map[string]interface{} `json:"metadata" bson:"metadata"`
 }
 
@@ -216,6 +217,7 @@ type ReportSchedule struct {
 	Frequency   string                 `json:"frequency" bson:"frequency"` // daily, weekly, monthly, quarterly
 	Parameters  map[string]interface{} `json:"parameters" bson:"parameters"`
 	Recipients  []string               `json:"recipients" bson:"recipients"`
+	RedactionProfile string            `json:"redaction_profile" bson:"redaction_profile"`
 	NextRun     time.Time              `json:"next_run" bson:"next_run"`
 	LastRun     time.Time              `json:"last_run" bson:"last_run"`
 	Enabled     bool                   `json:"enabled" bson:"enabled"`
@@ -10,15 +10,16 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Server     ServerConfig     `mapstructure:"server"`
-	Database   DatabaseConfig   `mapstructure:"database"`
-	Redis      RedisConfig      `mapstructure:"redis"`
-	Kafka      KafkaConfig      `mapstructure:"kafka"`
-	Compliance ComplianceConfig `mapstructure:"compliance"`
-	Reporting  ReportingConfig  `mapstructure:"reporting"`
-	Audit      AuditConfig      `mapstructure:"audit"`
-	Monitoring MonitoringConfig `mapstructure:"monitoring"`
-	Security   SecurityConfig   `mapstructure:"security"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Redis       RedisConfig       `mapstructure:"redis"`
+	Kafka       KafkaConfig       `mapstructure:"kafka"`
+	Compliance  ComplianceConfig  `mapstructure:"compliance"`
+	Attestation AttestationConfig `mapstructure:"attestation"`
+	Reporting   ReportingConfig   `mapstructure:"reporting"`
+	Audit       AuditConfig       `mapstructure:"audit"`
+	Monitoring  MonitoringConfig  `mapstructure:"monitoring"`
+	Security    SecurityConfig    `mapstructure:"security"`
 }
 
 // ServerConfig contains HTTP/gRPC server configuration
@@ -186,6 +187,32 @@ type ArchiveConfig struct {
 	ArchiveSchedule  string `mapstructure:"archive_schedule"`
 }
 
+// AttestationConfig contains attestation campaign settings
+type AttestationConfig struct {
+	CheckInterval     time.Duration                `mapstructure:"check_interval"`    // how often reminders and escalations are evaluated
+	ReminderInterval  time.Duration                `mapstructure:"reminder_interval"` // between reminders to a pending attester
+	SigningKey        string                       `mapstructure:"signing_key"`       // seals e-signatures
+	CampaignRoles     []string                     `mapstructure:"campaign_roles"`    // may create, launch and cancel campaigns
+	DefaultEscalation []AttestationEscalationLevel `mapstructure:"default_escalation"`
+	Directory         AttestationDirectoryConfig   `mapstructure:"directory"`
+}
+
+// AttestationEscalationLevel notifies recipients about attesters still
+// pending a delay after the campaign due date
+type AttestationEscalationLevel struct {
+	After      time.Duration `mapstructure:"after"`
+	Recipients []string      `mapstructure:"recipients"`
+}
+
+// AttestationDirectoryConfig locates the user-management service that
+// campaign targets are resolved against
+type AttestationDirectoryConfig struct {
+	URL      string        `mapstructure:"url"`
+	Token    string        `mapstructure:"token"` // service token with the admin role
+	Timeout  time.Duration `mapstructure:"timeout"`
+	PageSize int           `mapstructure:"page_size"`
+}
+
 // ReportingConfig contains reporting engine settings
 type ReportingConfig struct {
 	Templates        TemplatesConfig        `mapstructure:"templates"`
@@ -193,6 +220,34 @@ type ReportingConfig struct {
 	Distribution     DistributionConfig     `mapstructure:"distribution"`
 	Formats          FormatsConfig          `mapstructure:"formats"`
 	Scheduling       SchedulingConfig       `mapstructure:"scheduling"`
+	Tiering          ReportTieringConfig    `mapstructure:"tiering"`
+	Redaction        RedactionConfig        `mapstructure:"redaction"`
+}
+
+// RedactionConfig contains the redaction profiles selectable for reports and
+// bulk exports
+type RedactionConfig struct {
+	DefaultProfile string                            `mapstructure:"default_profile"` // applied when the caller does not choose one
+	HashKey        string                            `mapstructure:"hash_key"`        // keys the hash transform; required if any profile uses it
+	Profiles       map[string]RedactionProfileConfig `mapstructure:"profiles"`
+}
+
+// RedactionProfileConfig maps exported fields to transforms: remove, mask,
+// last4, hash or year
+type RedactionProfileConfig struct {
+	Description string            `mapstructure:"description"`
+	Roles       []string          `mapstructure:"roles"` // empty allows every caller
+	Fields      map[string]string `mapstructure:"fields"`
+}
+
+// ReportTieringConfig contains lifecycle tiering settings for generated report artifacts
+type ReportTieringConfig struct {
+	Enabled           bool          `mapstructure:"enabled"`
+	InfrequentAfter   time.Duration `mapstructure:"infrequent_after"`
+	ArchiveAfter      time.Duration `mapstructure:"archive_after"`
+	RestoreDelay      time.Duration `mapstructure:"restore_delay"`
+	RestoredRetention time.Duration `mapstructure:"restored_retention"`
+	CheckInterval     time.Duration `mapstructure:"check_interval"`
 }
 
 // TemplatesConfig contains report template settings
@@ -342,6 +397,16 @@ type AuditConfig struct {
 	CompressLogs      bool              `mapstructure:"compress_logs"`
 	AuditCategories   []AuditCategory   `mapstructure:"audit_categories"`
 	ExternalForwarding ExternalForwarding `mapstructure:"external_forwarding"`
+	WORM              AuditWORMConfig   `mapstructure:"worm"`
+}
+
+// AuditWORMConfig contains write-once audit storage settings (S3 Object Lock, compliance mode)
+type AuditWORMConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Bucket          string        `mapstructure:"bucket"`
+	Region          string        `mapstructure:"region"`
+	Prefix          string        `mapstructure:"prefix"`
+	RetentionPeriod time.Duration `mapstructure:"retention_period"`
 }
 
 // AuditCategory defines audit log categories
@@ -493,6 +558,60 @@ func setDefaults() {
 	viper.SetDefault("monitoring.health_check.port", 8082)
 	viper.SetDefault("monitoring.health_check.path", "/health")
 
+	// Audit defaults
+	viper.SetDefault("audit.worm.enabled", false)
+	viper.SetDefault("audit.worm.prefix", "audit/")
+	viper.SetDefault("audit.worm.retention_period", "61320h") // 7 years
+
+	// Attestation defaults
+	viper.SetDefault("attestation.check_interval", "1h")
+	viper.SetDefault("attestation.reminder_interval", "72h")
+	viper.SetDefault("attestation.campaign_roles", []string{"admin", "compliance_officer"})
+	viper.SetDefault("attestation.default_escalation", []map[string]interface{}{
+		{"after": "0s", "recipients": []string{"compliance_officer"}},
+		{"after": "168h", "recipients": []string{"chief_compliance_officer"}},
+	})
+	viper.SetDefault("attestation.directory.url", "http://user-management:8080")
+	viper.SetDefault("attestation.directory.timeout", "10s")
+	viper.SetDefault("attestation.directory.page_size", 200)
+
+	// Reporting defaults
+	viper.SetDefault("reporting.tiering.enabled", false)
+	viper.SetDefault("reporting.tiering.infrequent_after", "720h") // 30 days
+	viper.SetDefault("reporting.tiering.archive_after", "4320h")   // 180 days
+	viper.SetDefault("reporting.tiering.restore_delay", "4h")
+	viper.SetDefault("reporting.tiering.restored_retention", "168h")
+	viper.SetDefault("reporting.tiering.check_interval", "1h")
+	viper.SetDefault("reporting.redaction.default_profile", "partner_bank")
+	viper.SetDefault("reporting.redaction.profiles", map[string]interface{}{
+		"internal_audit": map[string]interface{}{
+			"description": "Unredacted output for internal audit and compliance staff",
+			"roles":       []string{"admin", "compliance_officer", "auditor"},
+			"fields":      map[string]string{},
+		},
+		"partner_bank": map[string]interface{}{
+			"description": "Customer PII masked for sharing with partner banks",
+			"fields": map[string]string{
+				"customer_name":  "mask",
+				"full_name":      "mask",
+				"email":          "mask",
+				"phone":          "mask",
+				"address":        "remove",
+				"ssn":            "remove",
+				"tax_id":         "remove",
+				"national_id":    "remove",
+				"passport":       "remove",
+				"date_of_birth":  "year",
+				"account_number": "last4",
+				"iban":           "last4",
+				"card_number":    "last4",
+				"user_id":        "mask",
+				"ip_address":     "remove",
+				"user_agent":     "remove",
+			},
+		},
+	})
+
 	// Security defaults
 	viper.SetDefault("security.jwt_expiry", "24h")
 	viper.SetDefault("security.enable_tls", false)
@@ -523,6 +642,64 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("Kafka brokers are required")
 	}
 
+	if c.Audit.WORM.Enabled {
+		if c.Audit.WORM.Bucket == "" {
+			return fmt.Errorf("audit WORM bucket is required")
+		}
+		if c.Audit.WORM.RetentionPeriod <= 0 {
+			return fmt.Errorf("audit WORM retention period must be positive")
+		}
+	}
+
+	if c.Reporting.Tiering.Enabled {
+		if c.Reporting.Tiering.InfrequentAfter <= 0 {
+			return fmt.Errorf("report tiering infrequent access age must be positive")
+		}
+		if c.Reporting.Tiering.ArchiveAfter <= c.Reporting.Tiering.InfrequentAfter {
+			return fmt.Errorf("report tiering archive age must be greater than the infrequent access age")
+		}
+		if c.Reporting.Tiering.CheckInterval <= 0 {
+			return fmt.Errorf("report tiering check interval must be positive")
+		}
+	}
+
+	attestation := c.Attestation
+	if attestation.SigningKey == "" {
+		return fmt.Errorf("attestation signing key is required")
+	}
+	if attestation.CheckInterval <= 0 || attestation.ReminderInterval <= 0 {
+		return fmt.Errorf("attestation check and reminder intervals must be positive")
+	}
+	if attestation.Directory.PageSize <= 0 || attestation.Directory.PageSize > 500 {
+		return fmt.Errorf("attestation directory page size must be between 1 and 500")
+	}
+	for i, level := range attestation.DefaultEscalation {
+		if len(level.Recipients) == 0 {
+			return fmt.Errorf("attestation escalation level %d has no recipients", i+1)
+		}
+		if level.After < 0 || (i > 0 && level.After <= attestation.DefaultEscalation[i-1].After) {
+			return fmt.Errorf("attestation escalation levels must have increasing, non-negative delays")
+		}
+	}
+
+	redaction := c.Reporting.Redaction
+	if _, ok := redaction.Profiles[redaction.DefaultProfile]; !ok {
+		return fmt.Errorf("default redaction profile %q is not configured", redaction.DefaultProfile)
+	}
+	for name, profile := range redaction.Profiles {
+		for field, transform := range profile.Fields {
+			switch transform {
+			case "remove", "mask", "last4", "year":
+			case "hash":
+				if redaction.HashKey == "" {
+					return fmt.Errorf("redaction profile %s hashes %s but no hash key is configured", name, field)
+				}
+			default:
+				return fmt.Errorf("redaction profile %s has unsupported transform %q for %s", name, transform, field)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aegisshield/compliance-engine/internal/attestation"
+	"github.com/aegisshield/compliance-engine/internal/compliance"
+	"github.com/aegisshield/compliance-engine/internal/reporting"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Attestation endpoints
+
+func (h *ComplianceHandler) GetAttestationCampaigns(c *gin.Context) {
+	campaigns := h.attestations.ListCampaigns(c.Request.Context(), c.Query("status"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"campaigns": campaigns,
+		"count":     len(campaigns),
+	})
+}
+
+// CreateAttestationCampaign drafts a campaign. Escalation delays are Go
+// durations after the due date, e.g. "0s" or "168h".
+func (h *ComplianceHandler) CreateAttestationCampaign(c *gin.Context) {
+	if !h.requireCampaignRole(c) {
+		return
+	}
+
+	var request struct {
+		Name        string               `json:"name" binding:"required"`
+		Description string               `json:"description"`
+		Period      string               `json:"period" binding:"required"`
+		Statement   string               `json:"statement" binding:"required"`
+		Targets     []attestation.Target `json:"targets" binding:"required"`
+		DueAt       time.Time            `json:"due_at" binding:"required"`
+		Escalation  []struct {
+			After      string   `json:"after"`
+			Recipients []string `json:"recipients"`
+		} `json:"escalation"`
+		SignOffRoles []string `json:"sign_off_roles" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := attestation.CampaignInput{
+		Name:         request.Name,
+		Description:  request.Description,
+		Period:       request.Period,
+		Statement:    request.Statement,
+		Targets:      request.Targets,
+		DueAt:        request.DueAt,
+		SignOffRoles: request.SignOffRoles,
+	}
+	for _, level := range request.Escalation {
+		after, err := time.ParseDuration(level.After)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid escalation delay %q", level.After)})
+			return
+		}
+		input.Escalation = append(input.Escalation, attestation.EscalationLevel{After: after, Recipients: level.Recipients})
+	}
+
+	userID := c.GetHeader(userIDHeader)
+	campaign, err := h.attestations.CreateCampaign(c.Request.Context(), input, userID)
+	if err != nil {
+		h.respondAttestationError(c, err, "Failed to create attestation campaign")
+		return
+	}
+
+	h.auditLogger.LogEvent(c.Request.Context(), "attestation_campaign_created", "attestation",
+		userID, campaign.ID, "attestation_campaign", "create",
+		map[string]interface{}{
+			"period":         campaign.Period,
+			"statement_hash": campaign.StatementHash,
+			"due_at":         campaign.DueAt,
+		})
+
+	c.JSON(http.StatusCreated, campaign)
+}
+
+func (h *ComplianceHandler) GetAttestationCampaign(c *gin.Context) {
+	ctx := c.Request.Context()
+	campaignID := c.Param("campaign_id")
+
+	campaign, err := h.attestations.GetCampaign(ctx, campaignID)
+	if err != nil {
+		h.respondAttestationError(c, err, "Failed to get attestation campaign")
+		return
+	}
+	progress, err := h.attestations.GetProgress(ctx, campaignID)
+	if err != nil {
+		h.respondAttestationError(c, err, "Failed to get attestation campaign")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"campaign": campaign,
+		"progress": progress,
+	})
+}
+
+// LaunchAttestationCampaign resolves the campaign's targets and opens an
+// attestation for every attester
+func (h *ComplianceHandler) LaunchAttestationCampaign(c *gin.Context) {
+	if !h.requireCampaignRole(c) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetHeader(userIDHeader)
+
+	campaign, err := h.attestations.LaunchCampaign(ctx, c.Param("campaign_id"), userID)
+	if err != nil {
+		h.respondAttestationError(c, err, "Failed to launch attestation campaign")
+		return
+	}
+	progress, err := h.attestations.GetProgress(ctx, campaign.ID)
+	if err != nil {
+		h.respondAttestationError(c, err, "Failed to launch attestation campaign")
+		return
+	}
+
+	h.auditLogger.LogEvent(ctx, "attestation_campaign_launched", "attestation",
+		userID, campaign.ID, "attestation_campaign", "launch",
+		map[string]interface{}{
+			"attesters": progress.Total,
+		})
+
+	c.JSON(http.StatusOK, gin.H{
+		"campaign": campaign,
+		"progress": progress,
+	})
+}
+
+func (h *ComplianceHandler) CancelAttestationCampaign(c *gin.Context) {
+	if !h.requireCampaignRole(c) {
+		return
+	}
+
+	userID := c.GetHeader(userIDHeader)
+	campaign, err := h.attestations.CancelCampaign(c.Request.Context(), c.Param("campaign_id"), userID)
+	if err != nil {
+		h.respondAttestationError(c, err, "Failed to cancel attestation campaign")
+		return
+	}
+
+	h.auditLogger.LogEvent(c.Request.Context(), "attestation_campaign_cancelled", "attestation",
+		userID, campaign.ID, "attestation_campaign", "cancel", nil)
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+func (h *ComplianceHandler) GetCampaignAttestations(c *gin.Context) {
+	attestations, err := h.attestations.ListAttestations(c.Request.Context(), c.Param("campaign_id"), c.Query("status"))
+	if err != nil {
+		h.respondAttestationError(c, err, "Failed to list attestations")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attestations": attestations,
+		"count":        len(attestations),
+	})
+}
+
+// GetMyAttestations lists the caller's attestations in open campaigns
+func (h *ComplianceHandler) GetMyAttestations(c *gin.Context) {
+	attestations := h.attestations.ListAttesterAttestations(c.Request.Context(), c.GetHeader(userIDHeader))
+
+	c.JSON(http.StatusOK, gin.H{
+		"attestations": attestations,
+		"count":        len(attestations),
+	})
+}
+
+// SignAttestation e-signs the caller's attestation, with exceptions when
+// not every review was completed
+func (h *ComplianceHandler) SignAttestation(c *gin.Context) {
+	var request struct {
+		SignerName string `json:"signer_name" binding:"required"`
+		Exceptions string `json:"exceptions"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetHeader(userIDHeader)
+	signed, err := h.attestations.Attest(c.Request.Context(), c.Param("attestation_id"), userID, attestation.SignInput{
+		SignerName: request.SignerName,
+		Exceptions: request.Exceptions,
+		IPAddress:  c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	})
+	if err != nil {
+		h.respondAttestationError(c, err, "Failed to sign attestation")
+		return
+	}
+
+	h.auditLogger.LogEvent(c.Request.Context(), "attestation_signed", "attestation",
+		userID, signed.ID, "attestation", "sign",
+		map[string]interface{}{
+			"campaign_id": signed.CampaignID,
+			"status":      signed.Status,
+			"seal":        signed.Signature.Seal,
+		})
+
+	c.JSON(http.StatusOK, signed)
+}
+
+// SignOffAttestationCampaign records the caller's decision on the next step
+// of the campaign's sign-off chain
+func (h *ComplianceHandler) SignOffAttestationCampaign(c *gin.Context) {
+	var request struct {
+		SignerName string `json:"signer_name" binding:"required"`
+		Decision   string `json:"decision" binding:"required"`
+		Comment    string `json:"comment"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetHeader(userIDHeader)
+	campaign, err := h.attestations.SignOff(c.Request.Context(), c.Param("campaign_id"), userID, callerRoles(c), attestation.SignInput{
+		SignerName: request.SignerName,
+		Decision:   request.Decision,
+		Comment:    request.Comment,
+		IPAddress:  c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	})
+	if err != nil {
+		h.respondAttestationError(c, err, "Failed to sign off attestation campaign")
+		return
+	}
+
+	h.auditLogger.LogEvent(c.Request.Context(), "attestation_campaign_signed_off", "attestation",
+		userID, campaign.ID, "attestation_campaign", request.Decision,
+		map[string]interface{}{
+			"status": campaign.Status,
+		})
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+// ExportAttestationEvidence exports a campaign's attestations and sign-off
+// chain with their e-signatures for auditors, redacted by the requested
+// profile
+func (h *ComplianceHandler) ExportAttestationEvidence(c *gin.Context) {
+	format := c.DefaultQuery("format", compliance.ReportFormatJSON)
+	if format != compliance.ReportFormatJSON && format != compliance.ReportFormatCSV {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	profile, ok := h.resolveRedactionProfile(c, c.Query("redaction_profile"))
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	campaignID := c.Param("campaign_id")
+	userID := c.GetHeader(userIDHeader)
+
+	records, err := h.attestations.Evidence(ctx, campaignID)
+	if err != nil {
+		h.respondAttestationError(c, err, "Failed to load attestation evidence")
+		return
+	}
+
+	record, content, err := h.reportEngine.Export(ctx, reporting.ExportDatasetAttestationEvidence, format, userID, records, profile)
+	if err != nil {
+		h.logger.Error("Failed to export attestation evidence", zap.String("campaign_id", campaignID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export attestation evidence"})
+		return
+	}
+
+	h.auditLogger.LogEvent(ctx, "attestation_evidence_exported", "attestation",
+		userID, campaignID, "attestation_campaign", "export",
+		map[string]interface{}{
+			"export_id":         record.ID,
+			"records":           record.RecordCount,
+			"redaction_profile": record.RedactionProfile,
+		})
+
+	contentType := "application/json"
+	if format == compliance.ReportFormatCSV {
+		contentType = "text/csv"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("attestation-evidence-%s.%s", campaignID, format)))
+	c.Header("X-Export-ID", record.ID)
+	c.Header("X-Redaction-Profile", record.RedactionProfile)
+	c.Data(http.StatusOK, contentType, content)
+}
+
+// requireCampaignRole writes a 403 unless the caller may manage campaigns
+func (h *ComplianceHandler) requireCampaignRole(c *gin.Context) bool {
+	if h.attestations.CanManageCampaigns(callerRoles(c)) {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "managing attestation campaigns requires a campaign role"})
+	return false
+}
+
+// respondAttestationError maps unknown campaigns and attestations to 404,
+// signers without permission to 403, state conflicts to 409 and invalid
+// input to 400
+func (h *ComplianceHandler) respondAttestationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, attestation.ErrCampaignNotFound), errors.Is(err, attestation.ErrAttestationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, attestation.ErrNotPermitted):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, attestation.ErrCampaignState), errors.Is(err, attestation.ErrAlreadySigned):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, attestation.ErrInvalidCampaign), errors.Is(err, attestation.ErrInvalidSignature):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
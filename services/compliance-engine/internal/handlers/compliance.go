@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/aegisshield/compliance-engine/internal/attestation"
 	"github.com/aegisshield/compliance-engine/internal/audit"
 	"github.com/aegisshield/compliance-engine/internal/compliance"
 	"github.com/aegisshield/compliance-engine/internal/regulatory"
@@ -22,6 +23,7 @@ type ComplianceHandler struct {
 	reportEngine      *reporting.ReportEngine
 	auditLogger       *audit.AuditLogger
 	regulationManager *regulatory.RegulationManager
+	attestations      *attestation.Manager
 	logger            *zap.Logger
 }
 
@@ -33,6 +35,7 @@ func NewComplianceHandler(
 	reportEngine *reporting.ReportEngine,
 	auditLogger *audit.AuditLogger,
 	regulationManager *regulatory.RegulationManager,
+	attestations *attestation.Manager,
 	logger *zap.Logger,
 ) *ComplianceHandler {
 	return &ComplianceHandler{
@@ -42,6 +45,7 @@ func NewComplianceHandler(
 		reportEngine:      reportEngine,
 		auditLogger:       auditLogger,
 		regulationManager: regulationManager,
+		attestations:      attestations,
 		logger:            logger,
 	}
 }
@@ -77,11 +81,32 @@ func (h *ComplianceHandler) RegisterRoutes(router *gin.Engine) {
 	api.DELETE("/reports/templates/:template_id", h.DeleteReportTemplate)
 	api.POST("/reports/generate", h.GenerateReport)
 	api.GET("/reports/:report_id/status", h.GetReportStatus)
+	api.GET("/reports/:report_id/storage", h.GetReportStorageStatus)
+	api.GET("/reports/:report_id/content", h.GetReportContent)
+	api.POST("/reports/:report_id/restore", h.RestoreReport)
 	api.POST("/reports/schedule", h.ScheduleReport)
 
+	// Bulk export endpoints
+	api.POST("/exports", h.CreateExport)
+	api.GET("/exports/:export_id", h.GetExport)
+	api.GET("/redaction/profiles", h.GetRedactionProfiles)
+
 	// Audit endpoints
 	api.GET("/audit/logs", h.GetAuditLogs)
 	api.GET("/audit/statistics", h.GetAuditStatistics)
+	api.GET("/audit/logs/:log_id/retention", h.GetAuditRetentionLock)
+
+	// Attestation endpoints
+	api.GET("/attestations/campaigns", h.GetAttestationCampaigns)
+	api.POST("/attestations/campaigns", h.CreateAttestationCampaign)
+	api.GET("/attestations/campaigns/:campaign_id", h.GetAttestationCampaign)
+	api.POST("/attestations/campaigns/:campaign_id/launch", h.LaunchAttestationCampaign)
+	api.POST("/attestations/campaigns/:campaign_id/cancel", h.CancelAttestationCampaign)
+	api.GET("/attestations/campaigns/:campaign_id/attestations", h.GetCampaignAttestations)
+	api.POST("/attestations/campaigns/:campaign_id/sign-off", h.SignOffAttestationCampaign)
+	api.GET("/attestations/campaigns/:campaign_id/evidence", h.ExportAttestationEvidence)
+	api.GET("/attestations/mine", h.GetMyAttestations)
+	api.POST("/attestations/:attestation_id/sign", h.SignAttestation)
 
 	// Regulatory endpoints
 	api.GET("/regulations", h.GetRegulations)
@@ -480,8 +505,9 @@ func (h *ComplianceHandler) DeleteReportTemplate(c *gin.Context) {
 
 func (h *ComplianceHandler) GenerateReport(c *gin.Context) {
 	var request struct {
-		TemplateID string                 `json:"template_id" binding:"required"`
-		Parameters map[string]interface{} `json:"parameters"`
+		TemplateID       string                 `json:"template_id" binding:"required"`
+		Parameters       map[string]interface{} `json:"parameters"`
+		RedactionProfile string                 `json:"redaction_profile"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -489,7 +515,12 @@ func (h *ComplianceHandler) GenerateReport(c *gin.Context) {
 		return
 	}
 
-	report, err := h.reportEngine.GenerateReport(c.Request.Context(), request.TemplateID, request.Parameters)
+	profile, ok := h.resolveRedactionProfile(c, request.RedactionProfile)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportEngine.GenerateReport(c.Request.Context(), request.TemplateID, request.Parameters, profile)
 	if err != nil {
 		h.logger.Error("Failed to generate report", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate report"})
@@ -499,6 +530,7 @@ func (h *ComplianceHandler) GenerateReport(c *gin.Context) {
 	c.JSON(http.StatusAccepted, gin.H{
 		"report_id": report.ID,
 		"status": report.Status,
+		"redaction_profile": report.RedactionProfile,
 		"message": "Report generation started",
 	})
 }
@@ -516,6 +548,54 @@ func (h *ComplianceHandler) GetReportStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, status)
 }
 
+func (h *ComplianceHandler) GetReportStorageStatus(c *gin.Context) {
+	reportID := c.Param("report_id")
+
+	status, err := h.reportEngine.GetReportStorageStatus(c.Request.Context(), reportID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// GetReportContent downloads a generated report. Archived reports respond
+// with 202 and a restoring_from_archive status until the restore completes.
+func (h *ComplianceHandler) GetReportContent(c *gin.Context) {
+	reportID := c.Param("report_id")
+
+	content, status, err := h.reportEngine.GetReportContent(c.Request.Context(), reportID)
+	if err == reporting.ErrReportRestoring {
+		c.JSON(http.StatusAccepted, status)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return
+	}
+
+	c.Header("X-Storage-Tier", status.Tier)
+	c.Data(http.StatusOK, "application/octet-stream", content)
+}
+
+func (h *ComplianceHandler) RestoreReport(c *gin.Context) {
+	reportID := c.Param("report_id")
+
+	status, err := h.reportEngine.RequestReportRestore(c.Request.Context(), reportID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return
+	}
+
+	if status.Availability == reporting.AvailabilityRestoring {
+		c.JSON(http.StatusAccepted, status)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
 func (h *ComplianceHandler) ScheduleReport(c *gin.Context) {
 	var schedule compliance.ReportSchedule
 	if err := c.ShouldBindJSON(&schedule); err != nil {
@@ -523,6 +603,12 @@ func (h *ComplianceHandler) ScheduleReport(c *gin.Context) {
 		return
 	}
 
+	profile, ok := h.resolveRedactionProfile(c, schedule.RedactionProfile)
+	if !ok {
+		return
+	}
+	schedule.RedactionProfile = profile.Name
+
 	err := h.reportEngine.ScheduleReport(c.Request.Context(), &schedule)
 	if err != nil {
 		h.logger.Error("Failed to schedule report", zap.Error(err))
@@ -592,6 +678,19 @@ func (h *ComplianceHandler) GetAuditStatistics(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+func (h *ComplianceHandler) GetAuditRetentionLock(c *gin.Context) {
+	logID := c.Param("log_id")
+
+	status, err := h.auditLogger.VerifyRetentionLock(c.Request.Context(), logID)
+	if err != nil {
+		h.logger.Error("Failed to verify audit retention lock", zap.String("log_id", logID), zap.Error(err))
+		c.JSON(http.StatusNotFound, gin.H{"error": "Audit log not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
 // Regulatory endpoints
 
 func (h *ComplianceHandler) GetRegulations(c *gin.Context) {
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aegisshield/compliance-engine/internal/audit"
+	"github.com/aegisshield/compliance-engine/internal/compliance"
+	"github.com/aegisshield/compliance-engine/internal/redaction"
+	"github.com/aegisshield/compliance-engine/internal/reporting"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Caller identity forwarded by the API gateway
+const (
+	userIDHeader    = "X-User-ID"
+	userRolesHeader = "X-User-Roles" // comma-separated
+)
+
+// Bulk export endpoints
+
+// CreateExport exports audit logs or violations in bulk, redacted by the
+// requested profile
+func (h *ComplianceHandler) CreateExport(c *gin.Context) {
+	var request struct {
+		Dataset          string     `json:"dataset" binding:"required"`
+		Format           string     `json:"format"`
+		RedactionProfile string     `json:"redaction_profile"`
+		Category         string     `json:"category"`
+		EventType        string     `json:"event_type"`
+		UserID           string     `json:"user_id"`
+		EntityID         string     `json:"entity_id"`
+		Status           string     `json:"status"`
+		Severity         string     `json:"severity"`
+		StartTime        *time.Time `json:"start_time"`
+		EndTime          *time.Time `json:"end_time"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Format == "" {
+		request.Format = compliance.ReportFormatJSON
+	}
+	if request.Format != compliance.ReportFormatJSON && request.Format != compliance.ReportFormatCSV {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	profile, ok := h.resolveRedactionProfile(c, request.RedactionProfile)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var records interface{}
+	var err error
+
+	switch request.Dataset {
+	case reporting.ExportDatasetAuditLogs:
+		records, err = h.auditLogger.GetAuditLogs(ctx, audit.AuditFilters{
+			Category:  request.Category,
+			EventType: request.EventType,
+			UserID:    request.UserID,
+			EntityID:  request.EntityID,
+			StartTime: request.StartTime,
+			EndTime:   request.EndTime,
+		})
+	case reporting.ExportDatasetViolations:
+		switch {
+		case request.Status != "":
+			records, err = h.violationManager.GetViolationsByStatus(ctx, request.Status)
+		case request.Severity != "":
+			records, err = h.violationManager.GetViolationsBySeverity(ctx, request.Severity)
+		case request.StartTime != nil && request.EndTime != nil:
+			records, err = h.violationManager.GetViolationsByTimeRange(ctx, *request.StartTime, *request.EndTime)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "violation exports require status, severity or start_time and end_time"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown dataset %q", request.Dataset)})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to load export records", zap.String("dataset", request.Dataset), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load export records"})
+		return
+	}
+
+	record, content, err := h.reportEngine.Export(ctx, request.Dataset, request.Format, c.GetHeader(userIDHeader), records, profile)
+	if err != nil {
+		h.logger.Error("Failed to export records", zap.String("dataset", request.Dataset), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export records"})
+		return
+	}
+
+	contentType := "application/json"
+	if request.Format == compliance.ReportFormatCSV {
+		contentType = "text/csv"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-%s.%s", request.Dataset, record.ID, request.Format)))
+	c.Header("X-Export-ID", record.ID)
+	c.Header("X-Redaction-Profile", record.RedactionProfile)
+	c.Data(http.StatusOK, contentType, content)
+}
+
+func (h *ComplianceHandler) GetExport(c *gin.Context) {
+	exportID := c.Param("export_id")
+
+	record, err := h.reportEngine.GetExport(c.Request.Context(), exportID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// GetRedactionProfiles lists the profiles and whether the caller may select each
+func (h *ComplianceHandler) GetRedactionProfiles(c *gin.Context) {
+	roles := callerRoles(c)
+
+	profiles := h.reportEngine.ListRedactionProfiles(c.Request.Context())
+	result := make([]gin.H, 0, len(profiles))
+	for _, profile := range profiles {
+		result = append(result, gin.H{
+			"name":        profile.Name,
+			"description": profile.Description,
+			"roles":       profile.Roles,
+			"fields":      profile.Fields,
+			"allowed":     profile.Allows(roles),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"profiles": result})
+}
+
+// resolveRedactionProfile resolves the requested profile against the
+// caller's roles, writing the error response when it cannot be used
+func (h *ComplianceHandler) resolveRedactionProfile(c *gin.Context, name string) (*redaction.Profile, bool) {
+	profile, err := h.reportEngine.ResolveRedactionProfile(name, callerRoles(c))
+	switch {
+	case errors.Is(err, redaction.ErrUnknownProfile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	case errors.Is(err, redaction.ErrProfileNotAllowed):
+		h.logger.Warn("Redaction profile denied",
+			zap.String("profile", name),
+			zap.String("user_id", c.GetHeader(userIDHeader)),
+		)
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return nil, false
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve redaction profile"})
+		return nil, false
+	}
+
+	return profile, true
+}
+
+func callerRoles(c *gin.Context) []string {
+	var roles []string
+	for _, role := range strings.Split(c.GetHeader(userRolesHeader), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
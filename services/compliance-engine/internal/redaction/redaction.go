@@ -0,0 +1,265 @@
+package redaction
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Transforms a profile can apply to a field
+const (
+	TransformRemove = "remove" // drop the field; CSV cells are left empty
+	TransformMask   = "mask"   // replace every letter and digit with *
+	TransformLast4  = "last4"  // mask all but the last four characters
+	TransformHash   = "hash"   // replace with a keyed pseudonym that is stable across exports
+	TransformYear   = "year"   // reduce a date to its year
+)
+
+var (
+	// ErrUnknownProfile is returned when a requested profile is not configured
+	ErrUnknownProfile = errors.New("unknown redaction profile")
+	// ErrProfileNotAllowed is returned when the caller holds none of the profile's roles
+	ErrProfileNotAllowed = errors.New("redaction profile not allowed for caller roles")
+)
+
+// ValidTransform reports whether transform is a supported transform
+func ValidTransform(transform string) bool {
+	switch transform {
+	case TransformRemove, TransformMask, TransformLast4, TransformHash, TransformYear:
+		return true
+	}
+	return false
+}
+
+// Profile maps field names to the transform applied to them on export.
+// Fields match case-insensitively at any depth of the exported data.
+type Profile struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Roles       []string          `json:"roles"` // roles allowed to select the profile; empty allows everyone
+	Fields      map[string]string `json:"fields"`
+
+	hashKey []byte
+}
+
+// NewProfile creates a profile, rejecting unknown transforms
+func NewProfile(name, description string, roles []string, fields map[string]string) (*Profile, error) {
+	normalized := make(map[string]string, len(fields))
+	for field, transform := range fields {
+		if !ValidTransform(transform) {
+			return nil, fmt.Errorf("profile %s: unsupported transform %q for field %s", name, transform, field)
+		}
+		normalized[normalizeField(field)] = transform
+	}
+
+	return &Profile{
+		Name:        name,
+		Description: description,
+		Roles:       roles,
+		Fields:      normalized,
+	}, nil
+}
+
+// Allows reports whether a caller holding roles may select the profile
+func (p *Profile) Allows(roles []string) bool {
+	if len(p.Roles) == 0 {
+		return true
+	}
+	for _, allowed := range p.Roles {
+		for _, role := range roles {
+			if strings.EqualFold(allowed, role) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Apply returns a redacted copy of data. Structs are converted through their
+// JSON form, so fields are matched by their JSON names.
+func (p *Profile) Apply(data interface{}) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode data for redaction: %w", err)
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, fmt.Errorf("failed to decode data for redaction: %w", err)
+	}
+
+	return p.redactValue(generic), nil
+}
+
+// ApplyRecord redacts one tabular row, matching columns by header name
+func (p *Profile) ApplyRecord(headers, values []string) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = value
+		if i >= len(headers) {
+			continue
+		}
+		transform, ok := p.Fields[normalizeField(headers[i])]
+		if !ok {
+			continue
+		}
+		if transform == TransformRemove {
+			result[i] = ""
+			continue
+		}
+		result[i] = fmt.Sprint(p.transform(transform, value))
+	}
+	return result
+}
+
+func (p *Profile) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			transform, ok := p.Fields[normalizeField(key)]
+			if !ok {
+				result[key] = p.redactValue(item)
+				continue
+			}
+			if transform == TransformRemove {
+				continue
+			}
+			result[key] = p.transform(transform, item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = p.redactValue(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func (p *Profile) transform(transform string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		raw, _ := json.Marshal(value)
+		text = string(raw)
+	}
+	if text == "" {
+		return text
+	}
+
+	switch transform {
+	case TransformMask:
+		return mask(text, 0)
+	case TransformLast4:
+		return mask(text, 4)
+	case TransformHash:
+		mac := hmac.New(sha256.New, p.hashKey)
+		mac.Write([]byte(text))
+		return "h_" + hex.EncodeToString(mac.Sum(nil))[:16]
+	case TransformYear:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+			if t, err := time.Parse(layout, text); err == nil {
+				return t.Format("2006")
+			}
+		}
+		return mask(text, 0)
+	}
+
+	return text
+}
+
+// mask replaces letters and digits with *, keeping separators and the last keep characters
+func mask(text string, keep int) string {
+	runes := []rune(text)
+	for i := 0; i < len(runes)-keep; i++ {
+		r := runes[i]
+		if r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			runes[i] = '*'
+		}
+	}
+	return string(runes)
+}
+
+func normalizeField(field string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(field)), " ", "_")
+}
+
+// Registry holds the configured profiles and the default applied when a
+// caller does not choose one
+type Registry struct {
+	profiles       map[string]*Profile
+	defaultProfile string
+}
+
+// NewRegistry creates a registry; hashKey keys the hash transform of every profile
+func NewRegistry(profiles []*Profile, defaultProfile, hashKey string) (*Registry, error) {
+	registry := &Registry{
+		profiles:       make(map[string]*Profile, len(profiles)),
+		defaultProfile: defaultProfile,
+	}
+
+	for _, profile := range profiles {
+		profile.hashKey = []byte(hashKey)
+		registry.profiles[profile.Name] = profile
+	}
+
+	if _, ok := registry.profiles[defaultProfile]; !ok {
+		return nil, fmt.Errorf("default redaction profile %q is not configured", defaultProfile)
+	}
+
+	return registry, nil
+}
+
+// Resolve returns the named profile, or the default when name is empty,
+// provided the caller's roles allow it
+func (r *Registry) Resolve(name string, roles []string) (*Profile, error) {
+	if name == "" {
+		name = r.defaultProfile
+	}
+
+	profile, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if !profile.Allows(roles) {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotAllowed, name)
+	}
+
+	return profile, nil
+}
+
+// Get returns a profile by name without checking roles
+func (r *Registry) Get(name string) (*Profile, error) {
+	profile, ok := r.profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProfile, name)
+	}
+	return profile, nil
+}
+
+// List returns all profiles sorted by name
+func (r *Registry) List() []*Profile {
+	profiles := make([]*Profile, 0, len(r.profiles))
+	for _, profile := range r.profiles {
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
+
+// Default returns the name of the default profile
+func (r *Registry) Default() string {
+	return r.defaultProfile
+}
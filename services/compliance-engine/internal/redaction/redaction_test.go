@@ -0,0 +1,165 @@
+package redaction
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRegistry configures an unrestricted default profile and a
+// restricted one for regulators
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+
+	standard, err := NewProfile("standard", "Analyst exports", nil, map[string]string{
+		"Account Number": TransformLast4,
+		"tax_id":         TransformRemove,
+		"name":           TransformHash,
+		"date_of_birth":  TransformYear,
+		"email":          TransformMask,
+	})
+	require.NoError(t, err)
+
+	regulator, err := NewProfile("regulator", "Regulator filings", []string{"compliance", "admin"}, map[string]string{
+		"tax_id": TransformLast4,
+	})
+	require.NoError(t, err)
+
+	registry, err := NewRegistry([]*Profile{standard, regulator}, "standard", "test-hash-key")
+	require.NoError(t, err)
+	return registry
+}
+
+func TestNewProfile(t *testing.T) {
+	_, err := NewProfile("broken", "", nil, map[string]string{"tax_id": "encrypt"})
+	assert.Error(t, err)
+
+	profile, err := NewProfile("normalized", "", nil, map[string]string{" Account Number ": TransformMask})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"account_number": TransformMask}, profile.Fields)
+}
+
+func TestRegistryResolve(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	for _, tt := range []struct {
+		name    string
+		profile string
+		roles   []string
+		want    string
+		err     error
+	}{
+		{"the default applies when none is chosen", "", []string{"analyst"}, "standard", nil},
+		{"unrestricted profiles allow everyone", "standard", nil, "standard", nil},
+		{"restricted profiles allow their roles", "regulator", []string{"analyst", "Compliance"}, "regulator", nil},
+		{"restricted profiles reject other roles", "regulator", []string{"analyst"}, "", ErrProfileNotAllowed},
+		{"restricted profiles reject callers without roles", "regulator", nil, "", ErrProfileNotAllowed},
+		{"unknown profiles", "raw", []string{"admin"}, "", ErrUnknownProfile},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := registry.Resolve(tt.profile, tt.roles)
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err), "got %v, want %v", err, tt.err)
+				assert.Nil(t, profile)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, profile.Name)
+		})
+	}
+
+	_, err := NewRegistry(nil, "standard", "key")
+	assert.Error(t, err, "a missing default profile must be rejected")
+}
+
+func TestProfileApply(t *testing.T) {
+	registry := newTestRegistry(t)
+	standard, err := registry.Get("standard")
+	require.NoError(t, err)
+
+	type party struct {
+		Name          string `json:"name"`
+		AccountNumber string `json:"account_number"`
+		TaxID         string `json:"tax_id"`
+		DateOfBirth   string `json:"date_of_birth"`
+		Country       string `json:"country"`
+	}
+
+	redacted, err := standard.Apply(map[string]interface{}{
+		"case_id": "case-42",
+		"Email":   "ann.analyst@example.com",
+		"parties": []party{
+			{Name: "Jane Doe", AccountNumber: "GB29-NWBK-6016", TaxID: "123-45-6789", DateOfBirth: "1984-07-21", Country: "GB"},
+		},
+		"amount": 15000.5,
+	})
+	require.NoError(t, err)
+
+	result := redacted.(map[string]interface{})
+	assert.Equal(t, "case-42", result["case_id"])
+	assert.Equal(t, 15000.5, result["amount"])
+	assert.Equal(t, "***.*******@*******.***", result["Email"], "field names match case-insensitively")
+
+	parties := result["parties"].([]interface{})
+	require.Len(t, parties, 1)
+	jane := parties[0].(map[string]interface{})
+	assert.NotContains(t, jane, "tax_id")
+	assert.Equal(t, "****-****-6016", jane["account_number"])
+	assert.Equal(t, "1984", jane["date_of_birth"])
+	assert.Equal(t, "GB", jane["country"])
+	assert.Regexp(t, `^h_[0-9a-f]{16}$`, jane["name"])
+	assert.NotEqual(t, "Jane Doe", jane["name"])
+}
+
+func TestProfileHashIsStable(t *testing.T) {
+	first := newTestRegistry(t)
+	second := newTestRegistry(t)
+	a, _ := first.Get("standard")
+	b, _ := second.Get("standard")
+
+	// The same key gives the same pseudonym across exports, so redacted
+	// exports can still be joined on the entity
+	assert.Equal(t, a.transform(TransformHash, "Jane Doe"), b.transform(TransformHash, "Jane Doe"))
+	assert.NotEqual(t, a.transform(TransformHash, "Jane Doe"), a.transform(TransformHash, "John Doe"))
+
+	otherKey, err := NewRegistry([]*Profile{{Name: "standard", Fields: map[string]string{}}}, "standard", "another-key")
+	require.NoError(t, err)
+	c, _ := otherKey.Get("standard")
+	assert.NotEqual(t, a.transform(TransformHash, "Jane Doe"), c.transform(TransformHash, "Jane Doe"))
+}
+
+func TestProfileTransforms(t *testing.T) {
+	profile := &Profile{hashKey: []byte("key")}
+
+	for _, tt := range []struct {
+		name      string
+		transform string
+		value     interface{}
+		want      interface{}
+	}{
+		{"mask keeps separators", TransformMask, "+44 (20) 7946-0958", "+** (**) ****-****"},
+		{"last4 keeps the last four characters", TransformLast4, "4111111111111111", "************1111"},
+		{"last4 of a short value", TransformLast4, "123", "123"},
+		{"numbers are redacted as text", TransformLast4, 123456789.0, "*****6789"},
+		{"year of a date", TransformYear, "1984-07-21", "1984"},
+		{"year of a timestamp", TransformYear, "2026-03-02T10:15:00Z", "2026"},
+		{"an unparseable date is masked", TransformYear, "21/07/1984", "**/**/****"},
+		{"null stays null", TransformMask, nil, nil},
+		{"empty stays empty", TransformHash, "", ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, profile.transform(tt.transform, tt.value))
+		})
+	}
+}
+
+func TestProfileApplyRecord(t *testing.T) {
+	registry := newTestRegistry(t)
+	standard, _ := registry.Get("standard")
+
+	headers := []string{"Case ID", "Account Number", "Tax ID", "Date of Birth"}
+	got := standard.ApplyRecord(headers, []string{"case-42", "GB29NWBK6016", "123-45-6789", "1984-07-21", "extra"})
+	assert.Equal(t, []string{"case-42", "********6016", "", "1984", "extra"}, got)
+}
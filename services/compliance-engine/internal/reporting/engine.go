@@ -13,6 +13,7 @@ import (
 
 	"github.com/aegisshield/compliance-engine/internal/compliance"
 	"github.com/aegisshield/compliance-engine/internal/config"
+	"github.com/aegisshield/compliance-engine/internal/redaction"
 	"github.com/jung-kurt/gofpdf"
 	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
@@ -25,6 +26,9 @@ type ReportEngine struct {
 	templates      map[string]*compliance.ReportTemplate
 	schedules      map[string]*compliance.ReportSchedule
 	activeReports  map[string]*ReportStatus
+	artifacts      map[string]*ReportArtifact
+	exports        map[string]*ExportRecord
+	redaction      *redaction.Registry
 	mu             sync.RWMutex
 	running        bool
 	stopChan       chan struct{}
@@ -48,6 +52,8 @@ func NewReportEngine(cfg config.ReportingConfig, logger *zap.Logger) *ReportEngi
 		templates:     make(map[string]*compliance.ReportTemplate),
 		schedules:     make(map[string]*compliance.ReportSchedule),
 		activeReports: make(map[string]*ReportStatus),
+		artifacts:     make(map[string]*ReportArtifact),
+		exports:       make(map[string]*ExportRecord),
 		stopChan:      make(chan struct{}),
 	}
 }
@@ -68,9 +74,19 @@ func (re *ReportEngine) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to load default templates: %w", err)
 	}
 
+	// Load redaction profiles enforced on reports and exports
+	if err := re.loadRedactionProfiles(); err != nil {
+		return fmt.Errorf("failed to load redaction profiles: %w", err)
+	}
+
 	// Start background scheduler
 	go re.schedulerLoop(ctx)
 
+	// Start storage tiering for generated reports
+	if re.config.Tiering.Enabled {
+		go re.tieringLoop(ctx)
+	}
+
 	re.running = true
 	re.logger.Info("Report engine started successfully")
 
@@ -95,8 +111,9 @@ func (re *ReportEngine) Stop(ctx context.Context) error {
 	return nil
 }
 
-// GenerateReport generates a report based on template and parameters
-func (re *ReportEngine) GenerateReport(ctx context.Context, templateID string, parameters map[string]interface{}) (*compliance.Report, error) {
+// GenerateReport generates a report based on template and parameters, with
+// record fields redacted by profile
+func (re *ReportEngine) GenerateReport(ctx context.Context, templateID string, parameters map[string]interface{}, profile *redaction.Profile) (*compliance.Report, error) {
 	re.mu.RLock()
 	template, exists := re.templates[templateID]
 	re.mu.RUnlock()
@@ -114,6 +131,7 @@ func (re *ReportEngine) GenerateReport(ctx context.Context, templateID string, p
 		TemplateID:  templateID,
 		Parameters:  parameters,
 		GeneratedAt: time.Now(),
+		RedactionProfile: profile.Name,
 	}
 
 	// Track report generation
@@ -127,7 +145,7 @@ func (re *ReportEngine) GenerateReport(ctx context.Context, templateID string, p
 	re.mu.Unlock()
 
 	// Generate report content asynchronously
-	go re.generateReportContent(ctx, report, template)
+	go re.generateReportContent(ctx, report, template, profile)
 
 	return report, nil
 }
@@ -257,7 +275,7 @@ func (re *ReportEngine) ScheduleReport(ctx context.Context, schedule *compliance
 
 // Private methods
 
-func (re *ReportEngine) generateReportContent(ctx context.Context, report *compliance.Report, template *compliance.ReportTemplate) {
+func (re *ReportEngine) generateReportContent(ctx context.Context, report *compliance.Report, template *compliance.ReportTemplate, profile *redaction.Profile) {
 	re.updateReportStatus(report.ID, "generating", 10.0, "")
 
 	// Generate content based on format
@@ -268,13 +286,13 @@ func (re *ReportEngine) generateReportContent(ctx context.Context, report *compl
 	case compliance.ReportFormatPDF:
 		content, err = re.generatePDFReport(ctx, report, template)
 	case compliance.ReportFormatExcel:
-		content, err = re.generateExcelReport(ctx, report, template)
+		content, err = re.generateExcelReport(ctx, report, template, profile)
 	case compliance.ReportFormatCSV:
-		content, err = re.generateCSVReport(ctx, report, template)
+		content, err = re.generateCSVReport(ctx, report, template, profile)
 	case compliance.ReportFormatJSON:
-		content, err = re.generateJSONReport(ctx, report, template)
+		content, err = re.generateJSONReport(ctx, report, template, profile)
 	case compliance.ReportFormatXML:
-		content, err = re.generateXMLReport(ctx, report, template)
+		content, err = re.generateXMLReport(ctx, report, template, profile)
 	default:
 		err = fmt.Errorf("unsupported report format: %s", template.Format)
 	}
@@ -294,6 +312,7 @@ func (re *ReportEngine) generateReportContent(ctx context.Context, report *compl
 	report.Status = "completed"
 	re.mu.Unlock()
 
+	re.storeArtifact(report)
 	re.updateReportStatus(report.ID, "completed", 100.0, "")
 
 	re.logger.Info("Report generated successfully",
@@ -327,7 +346,7 @@ func (re *ReportEngine) generatePDFReport(ctx context.Context, report *complianc
 	}
 }
 
-func (re *ReportEngine) generateExcelReport(ctx context.Context, report *compliance.Report, template *compliance.ReportTemplate) ([]byte, error) {
+func (re *ReportEngine) generateExcelReport(ctx context.Context, report *compliance.Report, template *compliance.ReportTemplate, profile *redaction.Profile) ([]byte, error) {
 	re.updateReportStatus(report.ID, "generating", 30.0, "Generating Excel content")
 
 	f := excelize.NewFile()
@@ -338,8 +357,7 @@ func (re *ReportEngine) generateExcelReport(ctx context.Context, report *complia
 	f.SetSheetName("Sheet1", sheetName)
 
 	// Add headers
-	headers := []string{"ID", "Name", "Type", "Severity", "Status", "Created At"}
-	for i, header := range headers {
+	for i, header := range reportHeaders {
 		cell := fmt.Sprintf("%c1", 'A'+i)
 		f.SetCellValue(sheetName, cell, header)
 	}
@@ -347,7 +365,7 @@ func (re *ReportEngine) generateExcelReport(ctx context.Context, report *complia
 	// Add data based on template type
 	switch template.Type {
 	case compliance.ReportTypeViolation:
-		return re.generateViolationExcelContent(ctx, f, sheetName, report, template)
+		return re.generateViolationExcelContent(ctx, f, sheetName, report, template, profile)
 	case compliance.ReportTypeRegulatory:
 		return re.generateRegulatoryExcelContent(ctx, f, sheetName, report, template)
 	case compliance.ReportTypeMetrics:
@@ -357,32 +375,31 @@ func (re *ReportEngine) generateExcelReport(ctx context.Context, report *complia
 	}
 }
 
-func (re *ReportEngine) generateCSVReport(ctx context.Context, report *compliance.Report, template *compliance.ReportTemplate) ([]byte, error) {
+func (re *ReportEngine) generateCSVReport(ctx context.Context, report *compliance.Report, template *compliance.ReportTemplate, profile *redaction.Profile) ([]byte, error) {
 	re.updateReportStatus(report.ID, "generating", 30.0, "Generating CSV content")
 
 	var buf bytes.Buffer
 	writer := csv.NewWriter(&buf)
 
 	// Add headers
-	headers := []string{"ID", "Name", "Type", "Severity", "Status", "Created At"}
-	if err := writer.Write(headers); err != nil {
+	if err := writer.Write(reportHeaders); err != nil {
 		return nil, fmt.Errorf("failed to write CSV headers: %w", err)
 	}
 
 	// Add data based on template type
 	switch template.Type {
 	case compliance.ReportTypeViolation:
-		return re.generateViolationCSVContent(ctx, writer, &buf, report, template)
+		return re.generateViolationCSVContent(ctx, writer, &buf, report, template, profile)
 	case compliance.ReportTypeRegulatory:
-		return re.generateRegulatoryCSVContent(ctx, writer, &buf, report, template)
+		return re.generateRegulatoryCSVContent(ctx, writer, &buf, report, template, profile)
 	case compliance.ReportTypeMetrics:
-		return re.generateMetricsCSVContent(ctx, writer, &buf, report, template)
+		return re.generateMetricsCSVContent(ctx, writer, &buf, report, template, profile)
 	default:
-		return re.generateGenericCSVContent(ctx, writer, &buf, report, template)
+		return re.generateGenericCSVContent(ctx, writer, &buf, report, template, profile)
 	}
 }
 
-func (re *ReportEngine) generateJSONReport(ctx context.Context, report *compliance.Report, template *compliance.ReportTemplate) ([]byte, error) {
+func (re *ReportEngine) generateJSONReport(ctx context.Context, report *compliance.Report, template *compliance.ReportTemplate, profile *redaction.Profile) ([]byte, error) {
 	re.updateReportStatus(report.ID, "generating", 30.0, "Generating JSON content")
 
 	// Create report data structure
@@ -401,18 +418,27 @@ func (re *ReportEngine) generateJSONReport(ctx context.Context, report *complian
 		if err != nil {
 			return nil, err
 		}
+		if data, err = profile.Apply(data); err != nil {
+			return nil, err
+		}
 		reportData["violations"] = data
 	case compliance.ReportTypeRegulatory:
 		data, err := re.getRegulatoryData(ctx, report, template)
 		if err != nil {
 			return nil, err
 		}
+		if data, err = profile.Apply(data); err != nil {
+			return nil, err
+		}
 		reportData["regulatory_info"] = data
 	case compliance.ReportTypeMetrics:
 		data, err := re.getMetricsData(ctx, report, template)
 		if err != nil {
 			return nil, err
 		}
+		if data, err = profile.Apply(data); err != nil {
+			return nil, err
+		}
 		reportData["metrics"] = data
 	}
 
@@ -426,7 +452,7 @@ func (re *ReportEngine) generateJSONReport(ctx context.Context, report *complian
 	return content, nil
 }
 
-func (re *ReportEngine) generateXMLReport(ctx context.Context, report *compliance.Report, template *compliance.ReportTemplate) ([]byte, error) {
+func (re *ReportEngine) generateXMLReport(ctx context.Context, report *compliance.Report, template *compliance.ReportTemplate, profile *redaction.Profile) ([]byte, error) {
 	re.updateReportStatus(report.ID, "generating", 30.0, "Generating XML content")
 
 	// Create report data structure
@@ -453,18 +479,27 @@ func (re *ReportEngine) generateXMLReport(ctx context.Context, report *complianc
 		if err != nil {
 			return nil, err
 		}
+		if data, err = profile.Apply(data); err != nil {
+			return nil, err
+		}
 		xmlReport.Data = data
 	case compliance.ReportTypeRegulatory:
 		data, err := re.getRegulatoryData(ctx, report, template)
 		if err != nil {
 			return nil, err
 		}
+		if data, err = profile.Apply(data); err != nil {
+			return nil, err
+		}
 		xmlReport.Data = data
 	case compliance.ReportTypeMetrics:
 		data, err := re.getMetricsData(ctx, report, template)
 		if err != nil {
 			return nil, err
 		}
+		if data, err = profile.Apply(data); err != nil {
+			return nil, err
+		}
 		xmlReport.Data = data
 	}
 
@@ -478,6 +513,10 @@ func (re *ReportEngine) generateXMLReport(ctx context.Context, report *complianc
 	return content, nil
 }
 
+// reportHeaders are the columns of tabular report formats; redaction profiles
+// match them by name, so "Created At" is redacted as created_at
+var reportHeaders = []string{"ID", "Name", "Type", "Severity", "Status", "Created At"}
+
 // Data retrieval methods (simplified implementations)
 
 func (re *ReportEngine) getViolationData(ctx context.Context, report *compliance.Report, template *compliance.ReportTemplate) (interface{}, error) {
@@ -597,18 +636,18 @@ func (re *ReportEngine) finalizePDF(pdf *gofpdf.Fpdf) ([]byte, error) {
 
 // Excel content generation methods (simplified implementations)
 
-func (re *ReportEngine) generateViolationExcelContent(ctx context.Context, f *excelize.File, sheetName string, report *compliance.Report, template *compliance.ReportTemplate) ([]byte, error) {
+func (re *ReportEngine) generateViolationExcelContent(ctx context.Context, f *excelize.File, sheetName string, report *compliance.Report, template *compliance.ReportTemplate, profile *redaction.Profile) ([]byte, error) {
 	re.updateReportStatus(report.ID, "generating", 60.0, "Adding violation data to Excel")
 
 	// Add sample data
-	violations := [][]interface{}{
-		{"VIO_001", "Transaction Limit Violation", "violation", "high", "open", time.Now().AddDate(0, 0, -1)},
-		{"VIO_002", "Suspicious Pattern", "violation", "medium", "resolved", time.Now().AddDate(0, 0, -2)},
+	violations := [][]string{
+		{"VIO_001", "Transaction Limit Violation", "violation", "high", "open", time.Now().AddDate(0, 0, -1).Format("2006-01-02")},
+		{"VIO_002", "Suspicious Pattern", "violation", "medium", "resolved", time.Now().AddDate(0, 0, -2).Format("2006-01-02")},
 	}
 
 	for i, violation := range violations {
 		row := i + 2
-		for j, value := range violation {
+		for j, value := range profile.ApplyRecord(reportHeaders, violation) {
 			cell := fmt.Sprintf("%c%d", 'A'+j, row)
 			f.SetCellValue(sheetName, cell, value)
 		}
@@ -666,7 +705,7 @@ func (re *ReportEngine) finalizeExcel(f *excelize.File) ([]byte, error) {
 
 // CSV content generation methods (simplified implementations)
 
-func (re *ReportEngine) generateViolationCSVContent(ctx context.Context, writer *csv.Writer, buf *bytes.Buffer, report *compliance.Report, template *compliance.ReportTemplate) ([]byte, error) {
+func (re *ReportEngine) generateViolationCSVContent(ctx context.Context, writer *csv.Writer, buf *bytes.Buffer, report *compliance.Report, template *compliance.ReportTemplate, profile *redaction.Profile) ([]byte, error) {
 	re.updateReportStatus(report.ID, "generating", 60.0, "Adding violation data to CSV")
 
 	violations := [][]string{
@@ -675,7 +714,7 @@ func (re *ReportEngine) generateViolationCSVContent(ctx context.Context, writer
 	}
 
 	for _, violation := range violations {
-		if err := writer.Write(violation); err != nil {
+		if err := writer.Write(profile.ApplyRecord(reportHeaders, violation)); err != nil {
 			return nil, fmt.Errorf("failed to write CSV row: %w", err)
 		}
 	}
@@ -684,11 +723,11 @@ func (re *ReportEngine) generateViolationCSVContent(ctx context.Context, writer
 	return buf.Bytes(), nil
 }
 
-func (re *ReportEngine) generateRegulatoryCSVContent(ctx context.Context, writer *csv.Writer, buf *bytes.Buffer, report *compliance.Report, template *compliance.ReportTemplate) ([]byte, error) {
+func (re *ReportEngine) generateRegulatoryCSVContent(ctx context.Context, writer *csv.Writer, buf *bytes.Buffer, report *compliance.Report, template *compliance.ReportTemplate, profile *redaction.Profile) ([]byte, error) {
 	re.updateReportStatus(report.ID, "generating", 60.0, "Adding regulatory data to CSV")
 
 	record := []string{"Overall Status", "Compliant", "regulatory", "info", "active", time.Now().Format("2006-01-02")}
-	if err := writer.Write(record); err != nil {
+	if err := writer.Write(profile.ApplyRecord(reportHeaders, record)); err != nil {
 		return nil, fmt.Errorf("failed to write CSV row: %w", err)
 	}
 
@@ -696,7 +735,7 @@ func (re *ReportEngine) generateRegulatoryCSVContent(ctx context.Context, writer
 	return buf.Bytes(), nil
 }
 
-func (re *ReportEngine) generateMetricsCSVContent(ctx context.Context, writer *csv.Writer, buf *bytes.Buffer, report *compliance.Report, template *compliance.ReportTemplate) ([]byte, error) {
+func (re *ReportEngine) generateMetricsCSVContent(ctx context.Context, writer *csv.Writer, buf *bytes.Buffer, report *compliance.Report, template *compliance.ReportTemplate, profile *redaction.Profile) ([]byte, error) {
 	re.updateReportStatus(report.ID, "generating", 60.0, "Adding metrics data to CSV")
 
 	metrics := [][]string{
@@ -705,7 +744,7 @@ func (re *ReportEngine) generateMetricsCSVContent(ctx context.Context, writer *c
 	}
 
 	for _, metric := range metrics {
-		if err := writer.Write(metric); err != nil {
+		if err := writer.Write(profile.ApplyRecord(reportHeaders, metric)); err != nil {
 			return nil, fmt.Errorf("failed to write CSV row: %w", err)
 		}
 	}
@@ -714,11 +753,11 @@ func (re *ReportEngine) generateMetricsCSVContent(ctx context.Context, writer *c
 	return buf.Bytes(), nil
 }
 
-func (re *ReportEngine) generateGenericCSVContent(ctx context.Context, writer *csv.Writer, buf *bytes.Buffer, report *compliance.Report, template *compliance.ReportTemplate) ([]byte, error) {
+func (re *ReportEngine) generateGenericCSVContent(ctx context.Context, writer *csv.Writer, buf *bytes.Buffer, report *compliance.Report, template *compliance.ReportTemplate, profile *redaction.Profile) ([]byte, error) {
 	re.updateReportStatus(report.ID, "generating", 60.0, "Adding generic content to CSV")
 
 	record := []string{report.ID, report.Name, report.Type, "info", "generated", report.GeneratedAt.Format("2006-01-02")}
-	if err := writer.Write(record); err != nil {
+	if err := writer.Write(profile.ApplyRecord(reportHeaders, record)); err != nil {
 		return nil, fmt.Errorf("failed to write CSV row: %w", err)
 	}
 
@@ -824,7 +863,11 @@ func (re *ReportEngine) executeScheduledReport(ctx context.Context, schedule *co
 		zap.String("template_id", schedule.TemplateID),
 	)
 
-	_, err := re.GenerateReport(ctx, schedule.TemplateID, schedule.Parameters)
+	// The profile was authorized for the scheduling user when the schedule was created
+	profile, err := re.RedactionProfile(schedule.RedactionProfile)
+	if err == nil {
+		_, err = re.GenerateReport(ctx, schedule.TemplateID, schedule.Parameters, profile)
+	}
 	if err != nil {
 		re.logger.Error("Failed to execute scheduled report",
 			zap.String("schedule_id", schedule.ID),
@@ -0,0 +1,279 @@
+package reporting
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aegisshield/compliance-engine/internal/compliance"
+	"go.uber.org/zap"
+)
+
+// Storage tiers for generated report artifacts
+const (
+	StorageTierStandard         = "standard"
+	StorageTierInfrequentAccess = "infrequent_access"
+	StorageTierArchive          = "archive"
+)
+
+// Availability values returned with report content requests
+const (
+	AvailabilityAvailable = "available"
+	AvailabilityRestoring = "restoring_from_archive"
+	AvailabilityArchived  = "archived"
+)
+
+// ErrReportRestoring is returned when an archived report is still being restored
+var ErrReportRestoring = errors.New("report is restoring from archive")
+
+// ReportArtifact tracks the stored content of a generated report and its storage tier
+type ReportArtifact struct {
+	ReportID         string     `json:"report_id"`
+	Name             string     `json:"name"`
+	Format           string     `json:"format"`
+	SizeBytes        int        `json:"size_bytes"`
+	Tier             string     `json:"tier"`
+	TierChangedAt    time.Time  `json:"tier_changed_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	LastAccessedAt   time.Time  `json:"last_accessed_at"`
+	RestoreReadyAt   *time.Time `json:"restore_ready_at,omitempty"`
+	RestoreExpiresAt *time.Time `json:"restore_expires_at,omitempty"`
+
+	content  []byte // readable content in the standard and infrequent access tiers
+	archived []byte // compressed content in the archive tier
+	restored []byte // temporary readable copy of an archived report
+}
+
+// ReportStorageStatus is the user-facing storage state of a report
+type ReportStorageStatus struct {
+	ReportID         string     `json:"report_id"`
+	Tier             string     `json:"tier"`
+	Availability     string     `json:"availability"`
+	Message          string     `json:"message"`
+	RestoreReadyAt   *time.Time `json:"restore_ready_at,omitempty"`
+	RestoreExpiresAt *time.Time `json:"restore_expires_at,omitempty"`
+}
+
+// storeArtifact keeps the content of a completed report in the standard tier
+func (re *ReportEngine) storeArtifact(report *compliance.Report) {
+	now := time.Now()
+
+	re.mu.Lock()
+	defer re.mu.Unlock()
+
+	re.artifacts[report.ID] = &ReportArtifact{
+		ReportID:       report.ID,
+		Name:           report.Name,
+		Format:         report.Format,
+		SizeBytes:      len(report.Content),
+		Tier:           StorageTierStandard,
+		TierChangedAt:  now,
+		CreatedAt:      now,
+		LastAccessedAt: now,
+		content:        report.Content,
+	}
+}
+
+// GetReportContent returns the content of a generated report from whichever
+// tier holds it. Reading an archived report starts a restore and returns
+// ErrReportRestoring with the status to show the user.
+func (re *ReportEngine) GetReportContent(ctx context.Context, reportID string) ([]byte, *ReportStorageStatus, error) {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+
+	artifact, exists := re.artifacts[reportID]
+	if !exists {
+		return nil, nil, fmt.Errorf("report not found: %s", reportID)
+	}
+
+	now := time.Now()
+	switch availability(artifact) {
+	case AvailabilityArchived:
+		re.startRestore(artifact, now)
+		return nil, storageStatus(artifact), ErrReportRestoring
+	case AvailabilityRestoring:
+		return nil, storageStatus(artifact), ErrReportRestoring
+	}
+
+	content := artifact.content
+	if artifact.Tier == StorageTierArchive {
+		content = artifact.restored
+	}
+	artifact.LastAccessedAt = now
+
+	return content, storageStatus(artifact), nil
+}
+
+// RequestReportRestore starts restoring an archived report ahead of a download
+func (re *ReportEngine) RequestReportRestore(ctx context.Context, reportID string) (*ReportStorageStatus, error) {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+
+	artifact, exists := re.artifacts[reportID]
+	if !exists {
+		return nil, fmt.Errorf("report not found: %s", reportID)
+	}
+
+	now := time.Now()
+	if availability(artifact) == AvailabilityArchived {
+		re.startRestore(artifact, now)
+	}
+
+	return storageStatus(artifact), nil
+}
+
+// GetReportStorageStatus returns the storage tier of a report and whether it can be downloaded now
+func (re *ReportEngine) GetReportStorageStatus(ctx context.Context, reportID string) (*ReportStorageStatus, error) {
+	re.mu.RLock()
+	defer re.mu.RUnlock()
+
+	artifact, exists := re.artifacts[reportID]
+	if !exists {
+		return nil, fmt.Errorf("report not found: %s", reportID)
+	}
+
+	return storageStatus(artifact), nil
+}
+
+func (re *ReportEngine) tieringLoop(ctx context.Context) {
+	ticker := time.NewTicker(re.config.Tiering.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-re.stopChan:
+			return
+		case <-ticker.C:
+			re.applyTiering(time.Now())
+		}
+	}
+}
+
+// applyTiering moves idle reports to colder tiers, completes restores and
+// drops restored copies once they expire
+func (re *ReportEngine) applyTiering(now time.Time) {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+
+	for _, artifact := range re.artifacts {
+		if artifact.RestoreReadyAt != nil && artifact.restored == nil && !now.Before(*artifact.RestoreReadyAt) {
+			if err := re.completeRestore(artifact, now); err != nil {
+				re.logger.Error("Failed to restore archived report",
+					zap.String("report_id", artifact.ReportID),
+					zap.Error(err),
+				)
+			}
+			continue
+		}
+
+		if artifact.RestoreExpiresAt != nil && !now.Before(*artifact.RestoreExpiresAt) {
+			artifact.restored = nil
+			artifact.RestoreReadyAt = nil
+			artifact.RestoreExpiresAt = nil
+			continue
+		}
+
+		idle := now.Sub(artifact.LastAccessedAt)
+		switch {
+		case artifact.Tier != StorageTierArchive && idle >= re.config.Tiering.ArchiveAfter:
+			if err := re.archive(artifact, now); err != nil {
+				re.logger.Error("Failed to archive report",
+					zap.String("report_id", artifact.ReportID),
+					zap.Error(err),
+				)
+			}
+		case artifact.Tier == StorageTierStandard && idle >= re.config.Tiering.InfrequentAfter:
+			artifact.Tier = StorageTierInfrequentAccess
+			artifact.TierChangedAt = now
+		}
+	}
+}
+
+func (re *ReportEngine) archive(artifact *ReportArtifact, now time.Time) error {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(artifact.content); err != nil {
+		return fmt.Errorf("failed to compress report: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to compress report: %w", err)
+	}
+
+	artifact.archived = buf.Bytes()
+	artifact.content = nil
+	artifact.Tier = StorageTierArchive
+	artifact.TierChangedAt = now
+
+	re.logger.Info("Report moved to archive storage",
+		zap.String("report_id", artifact.ReportID),
+		zap.Int("size_bytes", artifact.SizeBytes),
+		zap.Int("archived_bytes", len(artifact.archived)),
+	)
+
+	return nil
+}
+
+func (re *ReportEngine) startRestore(artifact *ReportArtifact, now time.Time) {
+	readyAt := now.Add(re.config.Tiering.RestoreDelay)
+	artifact.RestoreReadyAt = &readyAt
+
+	re.logger.Info("Archived report restore requested",
+		zap.String("report_id", artifact.ReportID),
+		zap.Time("ready_at", readyAt),
+	)
+}
+
+func (re *ReportEngine) completeRestore(artifact *ReportArtifact, now time.Time) error {
+	reader, err := gzip.NewReader(bytes.NewReader(artifact.archived))
+	if err != nil {
+		return fmt.Errorf("failed to read archived report: %w", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read archived report: %w", err)
+	}
+
+	expiresAt := now.Add(re.config.Tiering.RestoredRetention)
+	artifact.restored = content
+	artifact.RestoreExpiresAt = &expiresAt
+	return nil
+}
+
+func availability(artifact *ReportArtifact) string {
+	if artifact.Tier != StorageTierArchive || artifact.restored != nil {
+		return AvailabilityAvailable
+	}
+	if artifact.RestoreReadyAt != nil {
+		return AvailabilityRestoring
+	}
+	return AvailabilityArchived
+}
+
+func storageStatus(artifact *ReportArtifact) *ReportStorageStatus {
+	status := &ReportStorageStatus{
+		ReportID:     artifact.ReportID,
+		Tier:         artifact.Tier,
+		Availability: availability(artifact),
+	}
+
+	switch status.Availability {
+	case AvailabilityRestoring:
+		status.RestoreReadyAt = artifact.RestoreReadyAt
+		status.Message = "This report is being restored from archive storage and will be available shortly"
+	case AvailabilityArchived:
+		status.Message = "This report is in archive storage; downloading it will start a restore"
+	default:
+		status.RestoreExpiresAt = artifact.RestoreExpiresAt
+		status.Message = "This report is available for download"
+	}
+
+	return status
+}
@@ -18,16 +18,23 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/reflection"
 
+	"aegisshield/services/data-ingestion/internal/canary"
 	"aegisshield/services/data-ingestion/internal/config"
 	"aegisshield/services/data-ingestion/internal/database"
+	"aegisshield/services/data-ingestion/internal/feedhealth"
 	"aegisshield/services/data-ingestion/internal/handlers"
 	"aegisshield/services/data-ingestion/internal/kafka"
 	"aegisshield/services/data-ingestion/internal/metrics"
+	"aegisshield/services/data-ingestion/internal/quota"
 	"aegisshield/services/data-ingestion/internal/server"
 	"aegisshield/services/data-ingestion/internal/storage"
 	pb "aegisshield/shared/proto/data-ingestion"
+	"aegisshield/shared/rbac"
+	"aegisshield/shared/rbac/userservice"
+	"aegisshield/shared/validation"
 )
 
 var (
@@ -81,8 +88,8 @@ func main() {
 		logger.WithError(err).Fatal("Failed to initialize storage service")
 	}
 
-	// Initialize Kafka producer
-	kafkaProducer, err := kafka.NewProducer(cfg.Kafka)
+	// Initialize Kafka producer; oversized payloads are claim-checked into storage
+	kafkaProducer, err := kafka.NewProducer(cfg.Kafka, storageService, metricsCollector)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to initialize Kafka producer")
 	}
@@ -104,9 +111,66 @@ func main() {
 		Logger:      logger,
 	}
 
+	// Initialize feed health monitoring; missing feeds and volume anomalies
+	// are raised as alerts in the alerting engine
+	feedHealthRepo := database.NewFeedHealthRepository(db)
+	feedMonitor := feedhealth.NewMonitor(
+		feedHealthRepo,
+		feedhealth.NewAlertingEngineClient(cfg.FeedHealth.AlertingEngineURL, cfg.FeedHealth.AlertTimeout),
+		cfg.FeedHealth,
+		logger,
+	)
+
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	defer stopMonitor()
+	if cfg.FeedHealth.Enabled {
+		go feedMonitor.Start(monitorCtx)
+	}
+
+	// Initialize the pipeline canary; labeled synthetic transactions are
+	// injected at the gRPC API and followed through every stage topic
+	var canaryRunner *canary.Runner
+	if cfg.Canary.Enabled {
+		injector, err := canary.NewGRPCInjector(cfg.Canary.IngestionAddr)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize canary injector")
+		}
+		defer injector.Close()
+
+		canaryRunner = canary.NewRunner(
+			cfg.Canary,
+			injector,
+			canary.NewAlertingEngineClient(cfg.Canary.AlertingEngineURL, cfg.Canary.AlertTimeout),
+			metricsCollector,
+			logger,
+		)
+		canaryObserver := canary.NewObserver(cfg.Kafka.Brokers, cfg.Canary.Stages, canaryRunner, logger)
+		defer canaryObserver.Close()
+
+		canaryObserver.Start(monitorCtx)
+		go canaryRunner.Start(monitorCtx)
+	}
+
+	// Initialize storage quotas; uploads reserve their size before they are
+	// stored and tenants or users nearing their limits are raised as alerts
+	var quotaService *quota.Service
+	if cfg.Quota.Enabled {
+		quotaService = quota.NewService(
+			database.NewQuotaRepository(db),
+			quota.NewAlertingEngineClient(cfg.Quota.AlertingEngineURL, cfg.Quota.AlertTimeout),
+			cfg.Quota,
+			logger,
+		)
+		services.Quota = quotaService
+		go quotaService.Start(monitorCtx)
+	}
+
 	// Create gRPC server
 	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(server.LoggingInterceptor(logger)),
+		grpc.ChainUnaryInterceptor(
+			server.LoggingInterceptor(logger),
+			validation.UnaryServerInterceptor(),
+		),
 		grpc.StreamInterceptor(server.StreamLoggingInterceptor(logger)),
 	)
 
@@ -146,10 +210,37 @@ func main() {
 		
 		// File upload endpoints (REST API)
 		api := httpRouter.PathPrefix("/api/v1").Subrouter()
+		
+		// ETL jobs authenticate with service account API keys scoped to ingestion
+		if cfg.Auth.UserServiceAddr != "" {
+			conn, err := grpc.Dial(cfg.Auth.UserServiceAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				logger.WithError(err).Fatal("Failed to connect to user service")
+			}
+			defer conn.Close()
+			
+			evaluator := rbac.NewEvaluator(rbac.DefaultPolicy(), time.Minute, 10000)
+			api.Use(rbac.AuthenticateAPIKey(userservice.NewClient(conn).APIKeys()))
+			api.Use(rbac.RequireResource(evaluator, "ingestion"))
+			logger.WithField("user_service", cfg.Auth.UserServiceAddr).Info("REST API requires API keys")
+		}
 		fileHandler := handlers.NewFileHandler(storageService, repos.FileUpload, kafkaProducer, logger)
 		api.HandleFunc("/files/upload", fileHandler.Upload).Methods("POST")
 		api.HandleFunc("/files/{id}/status", fileHandler.GetStatus).Methods("GET")
 		
+		// Feed health dashboard and delivery recording
+		handlers.NewFeedHealthHandler(feedHealthRepo, feedMonitor, logger).RegisterRoutes(api)
+		
+		// Pipeline canary results
+		if canaryRunner != nil {
+			handlers.NewCanaryHandler(monitorCtx, canaryRunner, logger).RegisterRoutes(api)
+		}
+		
+		// Storage quota usage, pre-upload checks and limits
+		if quotaService != nil {
+			handlers.NewQuotaHandler(quotaService, logger).RegisterRoutes(api)
+		}
+		
 		httpServer := &http.Server{
 			Addr:         fmt.Sprintf(":%d", cfg.Server.HTTPPort),
 			Handler:      httpRouter,
@@ -0,0 +1,86 @@
+package canary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AlertingEngineClient raises canary alerts through the alerting engine's REST API
+type AlertingEngineClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewAlertingEngineClient(baseURL string, timeout time.Duration) *AlertingEngineClient {
+	return &AlertingEngineClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// SendAlert creates an alert naming the stages the probe failed at
+func (c *AlertingEngineClient) SendAlert(ctx context.Context, run *Run, failed []*StageResult) (string, error) {
+	stages := make([]string, len(failed))
+	for i, stage := range failed {
+		stages[i] = stage.Stage
+	}
+
+	description := fmt.Sprintf("Synthetic probe %s did not pass stages: %s", run.ProbeID, strings.Join(stages, ", "))
+	if run.Error != "" {
+		description = fmt.Sprintf("Synthetic probe %s could not be injected: %s", run.ProbeID, run.Error)
+	}
+
+	payload := map[string]interface{}{
+		"title":       fmt.Sprintf("Pipeline canary failed at %s", strings.Join(stages, ", ")),
+		"description": description,
+		"severity":    "high",
+		"type":        "pipeline_health",
+		"priority":    "high",
+		"source":      "data-ingestion",
+		"created_by":  "pipeline-canary",
+		"event_data": map[string]interface{}{
+			"probe_id":   run.ProbeID,
+			"started_at": run.StartedAt,
+			"error":      run.Error,
+			"stages":     run.Stages,
+		},
+		"metadata": map[string]interface{}{
+			"component": "pipeline-canary",
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/alerts", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("alerting engine returned status %d", resp.StatusCode)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("failed to decode alert response: %w", err)
+	}
+
+	return created.ID, nil
+}
@@ -0,0 +1,77 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pb "aegisshield/shared/proto/data-ingestion"
+	shared "aegisshield/shared/proto/shared"
+)
+
+// GRPCInjector submits probes through the ingestion gRPC API, so they take
+// the same validation, enrichment and publishing path as real transactions
+type GRPCInjector struct {
+	conn   *grpc.ClientConn
+	client pb.DataIngestionServiceClient
+}
+
+// NewGRPCInjector connects to the ingestion API at addr
+func NewGRPCInjector(addr string) (*GRPCInjector, error) {
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ingestion API: %w", err)
+	}
+
+	return &GRPCInjector{
+		conn:   conn,
+		client: pb.NewDataIngestionServiceClient(conn),
+	}, nil
+}
+
+// Inject streams the probe as a single labeled transaction
+func (i *GRPCInjector) Inject(ctx context.Context, probe *Probe) error {
+	stream, err := i.client.ProcessTransactionStream(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open transaction stream: %w", err)
+	}
+
+	if err := stream.Send(&pb.ProcessTransactionRequest{
+		Transaction: &shared.Transaction{
+			Id:           probe.ID,
+			ExternalId:   probe.ID,
+			Amount:       probe.Amount,
+			Currency:     probe.Currency,
+			Description:  "Synthetic pipeline canary transaction",
+			FromEntity:   probe.FromEntity,
+			ToEntity:     probe.ToEntity,
+			SourceSystem: "pipeline-canary",
+			Metadata:     probe.Labels(),
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to send probe: %w", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		return fmt.Errorf("failed to close transaction stream: %w", err)
+	}
+
+	response, err := stream.Recv()
+	if err == io.EOF {
+		return fmt.Errorf("ingestion API closed the stream without a response")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to receive probe response: %w", err)
+	}
+	if !response.Success {
+		return fmt.Errorf("ingestion API rejected the probe: %s", response.Message)
+	}
+	return nil
+}
+
+// Close closes the connection to the ingestion API
+func (i *GRPCInjector) Close() error {
+	return i.conn.Close()
+}
@@ -0,0 +1,87 @@
+package canary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/data-ingestion/internal/config"
+)
+
+// Observer watches the topic of every stage and reports each message to the runner
+type Observer struct {
+	readers map[string]*kafka.Reader // stage name -> reader
+	runner  *Runner
+	logger  *logrus.Logger
+}
+
+// NewObserver creates a reader per stage. Each instance reads in its own
+// consumer group so every instance sees all messages, starting from the
+// newest since older messages cannot carry its probes.
+func NewObserver(brokers []string, stages []config.CanaryStage, runner *Runner, logger *logrus.Logger) *Observer {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	readers := make(map[string]*kafka.Reader, len(stages))
+	for _, stage := range stages {
+		readers[stage.Name] = kafka.NewReader(kafka.ReaderConfig{
+			Brokers:     brokers,
+			Topic:       stage.Topic,
+			GroupID:     fmt.Sprintf("data-ingestion-canary-%s-%s", hostname, stage.Name),
+			StartOffset: kafka.LastOffset,
+			MinBytes:    1,
+			MaxBytes:    10e6,
+			MaxWait:     time.Second,
+		})
+	}
+
+	return &Observer{
+		readers: readers,
+		runner:  runner,
+		logger:  logger,
+	}
+}
+
+// Start reads every stage topic until the context is cancelled
+func (o *Observer) Start(ctx context.Context) {
+	for stage, reader := range o.readers {
+		go o.watch(ctx, stage, reader)
+	}
+}
+
+func (o *Observer) watch(ctx context.Context, stage string, reader *kafka.Reader) {
+	for {
+		message, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+				return
+			}
+			o.logger.WithError(err).WithField("stage", stage).Warn("Failed to read canary stage topic")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+		o.runner.Observe(stage, message.Value, time.Now())
+	}
+}
+
+// Close closes every stage reader
+func (o *Observer) Close() error {
+	var firstErr error
+	for _, reader := range o.readers {
+		if err := reader.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,349 @@
+package canary
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/data-ingestion/internal/config"
+)
+
+// ProbePrefix starts the ID of every synthetic transaction, so downstream
+// consumers can recognise and exclude canary traffic
+const ProbePrefix = "canary-"
+
+// Run results
+const (
+	RunRunning = "running"
+	RunPassed  = "passed"
+	RunFailed  = "failed"
+)
+
+// Stage results
+const (
+	StagePending = "pending"
+	StagePassed  = "passed"
+	StageLate    = "late"    // emitted after the stage's max latency
+	StageMissing = "missing" // never emitted while the run was open
+)
+
+// historySize is how many finished runs are kept for the status endpoint
+const historySize = 20
+
+// Probe is a labeled synthetic transaction
+type Probe struct {
+	ID         string
+	FromEntity string
+	ToEntity   string
+	Amount     float64
+	Currency   string
+	CreatedAt  time.Time
+}
+
+// Labels mark a probe as synthetic wherever its metadata travels
+func (p *Probe) Labels() map[string]string {
+	return map[string]string{
+		"synthetic":       "true",
+		"canary_probe_id": p.ID,
+	}
+}
+
+// Run is one probe's journey through the pipeline
+type Run struct {
+	ProbeID     string         `json:"probe_id"`
+	Status      string         `json:"status"`
+	Error       string         `json:"error,omitempty"`
+	StartedAt   time.Time      `json:"started_at"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+	Stages      []*StageResult `json:"stages"`
+	AlertID     string         `json:"alert_id,omitempty"`
+
+	done chan struct{} // closed once every stage has emitted the probe
+}
+
+// StageResult records when a stage emitted the probe
+type StageResult struct {
+	Stage        string     `json:"stage"`
+	Topic        string     `json:"topic"`
+	MaxLatencyMs int64      `json:"max_latency_ms"`
+	Status       string     `json:"status"`
+	ObservedAt   *time.Time `json:"observed_at,omitempty"`
+	LatencyMs    int64      `json:"latency_ms,omitempty"`
+
+	maxLatency time.Duration
+}
+
+// Failed returns the stages that missed the probe or emitted it late
+func (r *Run) Failed() []*StageResult {
+	var failed []*StageResult
+	for _, stage := range r.Stages {
+		if stage.Status == StageLate || stage.Status == StageMissing {
+			failed = append(failed, stage)
+		}
+	}
+	return failed
+}
+
+// Injector submits a probe at the ingestion API
+type Injector interface {
+	Inject(ctx context.Context, probe *Probe) error
+}
+
+// AlertSender raises an alert for a failed run and returns its ID
+type AlertSender interface {
+	SendAlert(ctx context.Context, run *Run, failed []*StageResult) (string, error)
+}
+
+// Metrics records canary results
+type Metrics interface {
+	IncrementCanaryRuns(result string)
+	ObserveCanaryStageLatency(stage string, latency time.Duration)
+	IncrementCanaryStageFailures(stage string)
+}
+
+// Runner injects probes on a schedule and follows them through the
+// pipeline stages. Observers report every message seen on a stage topic;
+// a run fails when any stage does not emit the probe within its max latency.
+type Runner struct {
+	cfg      config.CanaryConfig
+	injector Injector
+	alerts   AlertSender
+	metrics  Metrics
+	logger   *logrus.Logger
+
+	mu      sync.Mutex
+	active  map[string]*Run
+	history []*Run
+	failing map[string]bool // stages failing as of the last run, to alert once per outage
+}
+
+// NewRunner creates a canary runner. A nil alert sender or metrics recorder
+// disables alerts or metrics.
+func NewRunner(cfg config.CanaryConfig, injector Injector, alerts AlertSender, metrics Metrics, logger *logrus.Logger) *Runner {
+	return &Runner{
+		cfg:      cfg,
+		injector: injector,
+		alerts:   alerts,
+		metrics:  metrics,
+		logger:   logger,
+		active:   make(map[string]*Run),
+		failing:  make(map[string]bool),
+	}
+}
+
+// Start runs a probe every Interval until the context is cancelled. Runs
+// do not overlap; a tick that arrives during a run is skipped.
+func (r *Runner) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce injects a probe and waits until every stage has emitted it or the
+// longest stage latency has passed
+func (r *Runner) RunOnce(ctx context.Context) *Run {
+	now := time.Now()
+	probe := &Probe{
+		ID:        ProbePrefix + uuid.New().String(),
+		Amount:    r.cfg.Amount,
+		Currency:  r.cfg.Currency,
+		CreatedAt: now,
+	}
+	probe.FromEntity = probe.ID + "-originator"
+	probe.ToEntity = probe.ID + "-beneficiary"
+
+	run := &Run{
+		ProbeID:   probe.ID,
+		Status:    RunRunning,
+		StartedAt: now,
+		done:      make(chan struct{}),
+	}
+	var window time.Duration
+	for _, stage := range r.cfg.Stages {
+		run.Stages = append(run.Stages, &StageResult{
+			Stage:        stage.Name,
+			Topic:        stage.Topic,
+			MaxLatencyMs: stage.MaxLatency.Milliseconds(),
+			Status:       StagePending,
+			maxLatency:   stage.MaxLatency,
+		})
+		if stage.MaxLatency > window {
+			window = stage.MaxLatency
+		}
+	}
+
+	// Registered before injecting so fast stages are not missed
+	r.mu.Lock()
+	r.active[probe.ID] = run
+	r.mu.Unlock()
+
+	if err := r.injector.Inject(ctx, probe); err != nil {
+		run.Error = fmt.Sprintf("injection failed: %v", err)
+	} else {
+		timer := time.NewTimer(window)
+		select {
+		case <-run.done:
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+		timer.Stop()
+	}
+
+	r.finish(ctx, run)
+	return run
+}
+
+// Observe checks a message seen on a stage topic for active probes
+func (r *Runner) Observe(stage string, value []byte, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, run := range r.active {
+		if !bytes.Contains(value, []byte(id)) {
+			continue
+		}
+
+		complete := true
+		for _, result := range run.Stages {
+			if result.Stage == stage && result.Status == StagePending {
+				latency := at.Sub(run.StartedAt)
+				observed := at
+				result.ObservedAt = &observed
+				result.LatencyMs = latency.Milliseconds()
+				result.Status = StagePassed
+				if latency > result.maxLatency {
+					result.Status = StageLate
+				}
+			}
+			if result.Status == StagePending {
+				complete = false
+			}
+		}
+		if complete {
+			select {
+			case <-run.done:
+			default:
+				close(run.done)
+			}
+		}
+	}
+}
+
+// Runs returns the active run followed by recent finished runs, newest first
+func (r *Runner) Runs() []Run {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	runs := make([]Run, 0, len(r.active)+len(r.history))
+	for _, run := range r.active {
+		runs = append(runs, snapshot(run))
+	}
+	for i := len(r.history) - 1; i >= 0; i-- {
+		runs = append(runs, snapshot(r.history[i]))
+	}
+	return runs
+}
+
+// finish closes a run, records its results and alerts on stages that
+// started failing. Stages that keep failing are not alerted again.
+func (r *Runner) finish(ctx context.Context, run *Run) {
+	r.mu.Lock()
+	delete(r.active, run.ProbeID)
+
+	completed := time.Now()
+	run.CompletedAt = &completed
+	for _, stage := range run.Stages {
+		if stage.Status == StagePending {
+			stage.Status = StageMissing
+		}
+	}
+
+	failed := run.Failed()
+	run.Status = RunPassed
+	if run.Error != "" || len(failed) > 0 {
+		run.Status = RunFailed
+	}
+
+	failing := make(map[string]bool, len(failed))
+	var newlyFailing, recovered []string
+	for _, stage := range failed {
+		failing[stage.Stage] = true
+		if !r.failing[stage.Stage] {
+			newlyFailing = append(newlyFailing, stage.Stage)
+		}
+	}
+	for stage := range r.failing {
+		if !failing[stage] {
+			recovered = append(recovered, stage)
+		}
+	}
+	r.failing = failing
+	r.mu.Unlock()
+
+	if r.metrics != nil {
+		r.metrics.IncrementCanaryRuns(run.Status)
+		for _, stage := range run.Stages {
+			if stage.ObservedAt != nil {
+				r.metrics.ObserveCanaryStageLatency(stage.Stage, time.Duration(stage.LatencyMs)*time.Millisecond)
+			}
+			if stage.Status != StagePassed {
+				r.metrics.IncrementCanaryStageFailures(stage.Stage)
+			}
+		}
+	}
+
+	fields := logrus.Fields{"probe_id": run.ProbeID, "status": run.Status}
+	if run.Status == RunFailed {
+		stages := make([]string, len(failed))
+		for i, stage := range failed {
+			stages[i] = stage.Stage + "=" + stage.Status
+		}
+		fields["failed_stages"] = stages
+		r.logger.WithFields(fields).WithField("error", run.Error).Warn("Pipeline canary failed")
+	} else {
+		r.logger.WithFields(fields).Info("Pipeline canary passed")
+	}
+	if len(recovered) > 0 {
+		r.logger.WithField("stages", recovered).Info("Pipeline canary stages recovered")
+	}
+
+	if r.alerts != nil && len(newlyFailing) > 0 {
+		alertID, err := r.alerts.SendAlert(ctx, run, failed)
+		if err != nil {
+			r.logger.WithError(err).WithField("probe_id", run.ProbeID).Error("Failed to raise pipeline canary alert")
+		}
+		run.AlertID = alertID
+	}
+
+	r.mu.Lock()
+	r.history = append(r.history, run)
+	if len(r.history) > historySize {
+		r.history = r.history[len(r.history)-historySize:]
+	}
+	r.mu.Unlock()
+}
+
+// snapshot copies a run so callers can read it while observers update it
+func snapshot(run *Run) Run {
+	copied := *run
+	copied.done = nil
+	copied.Stages = make([]*StageResult, len(run.Stages))
+	for i, stage := range run.Stages {
+		stageCopy := *stage
+		copied.Stages[i] = &stageCopy
+	}
+	return copied
+}
@@ -0,0 +1,233 @@
+package canary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/data-ingestion/internal/config"
+)
+
+// emission is a stage emitting a message offset from the probe's injection
+type emission struct {
+	stage string
+	after time.Duration
+	other bool // the message carries another probe's ID
+}
+
+// scriptedPipeline emits messages for each injected probe as scripted
+type scriptedPipeline struct {
+	runner    *Runner
+	emissions []emission
+	err       error
+	injected  []*Probe
+}
+
+func (p *scriptedPipeline) Inject(ctx context.Context, probe *Probe) error {
+	p.injected = append(p.injected, probe)
+	if p.err != nil {
+		return p.err
+	}
+	for _, e := range p.emissions {
+		id := probe.ID
+		if e.other {
+			id = ProbePrefix + "00000000-0000-0000-0000-000000000000"
+		}
+		value := fmt.Sprintf(`{"transaction_id": %q, "metadata": {"synthetic": "true"}}`, id)
+		p.runner.Observe(e.stage, []byte(value), probe.CreatedAt.Add(e.after))
+	}
+	return nil
+}
+
+// recordingAlerts records the stages each alert was raised for
+type recordingAlerts struct {
+	sent [][]string
+}
+
+func (a *recordingAlerts) SendAlert(ctx context.Context, run *Run, failed []*StageResult) (string, error) {
+	stages := make([]string, len(failed))
+	for i, stage := range failed {
+		stages[i] = stage.Stage
+	}
+	a.sent = append(a.sent, stages)
+	return fmt.Sprintf("alert-%d", len(a.sent)), nil
+}
+
+// testStages keep the missing-stage wait short
+var testStages = []config.CanaryStage{
+	{Name: "etl", Topic: "transactions", MaxLatency: 10 * time.Millisecond},
+	{Name: "resolution", Topic: "entities.resolved", MaxLatency: 20 * time.Millisecond},
+	{Name: "rules", Topic: "alert-generated", MaxLatency: 30 * time.Millisecond},
+}
+
+func testRunner() (*Runner, *scriptedPipeline, *recordingAlerts) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	pipeline := &scriptedPipeline{}
+	alerts := &recordingAlerts{}
+	runner := NewRunner(config.CanaryConfig{Amount: 15000, Currency: "USD", Stages: testStages}, pipeline, alerts, nil, logger)
+	pipeline.runner = runner
+	return runner, pipeline, alerts
+}
+
+func stageStatuses(run *Run) string {
+	statuses := make([]string, len(run.Stages))
+	for i, stage := range run.Stages {
+		statuses[i] = stage.Stage + "=" + stage.Status
+	}
+	return strings.Join(statuses, " ")
+}
+
+func TestRunnerRunOnce(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		emissions []emission
+		err       error
+		status    string
+		stages    string
+	}{
+		{
+			"every stage on time",
+			[]emission{{"etl", 5 * time.Millisecond, false}, {"resolution", 15 * time.Millisecond, false}, {"rules", 25 * time.Millisecond, false}},
+			nil, RunPassed, "etl=passed resolution=passed rules=passed",
+		},
+		{
+			"a stage past its max latency",
+			[]emission{{"etl", 5 * time.Millisecond, false}, {"resolution", time.Minute, false}, {"rules", 25 * time.Millisecond, false}},
+			nil, RunFailed, "etl=passed resolution=late rules=passed",
+		},
+		{
+			"a stage that never emits the probe",
+			[]emission{{"etl", 5 * time.Millisecond, false}, {"resolution", 15 * time.Millisecond, false}},
+			nil, RunFailed, "etl=passed resolution=passed rules=missing",
+		},
+		{
+			"another probe's message is not this probe",
+			[]emission{{"etl", 5 * time.Millisecond, false}, {"resolution", 15 * time.Millisecond, true}, {"rules", 25 * time.Millisecond, false}},
+			nil, RunFailed, "etl=passed resolution=missing rules=passed",
+		},
+		{
+			"a message on another stage's topic counts only for that stage",
+			[]emission{{"etl", 5 * time.Millisecond, false}, {"etl", 15 * time.Millisecond, false}, {"rules", 25 * time.Millisecond, false}},
+			nil, RunFailed, "etl=passed resolution=missing rules=passed",
+		},
+		{
+			"an injection failure",
+			nil, errors.New("connection refused"), RunFailed, "etl=missing resolution=missing rules=missing",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, pipeline, _ := testRunner()
+			pipeline.emissions = tt.emissions
+			pipeline.err = tt.err
+
+			run := runner.RunOnce(context.Background())
+			if run.Status != tt.status {
+				t.Fatalf("got %s, want %s", run.Status, tt.status)
+			}
+			if got := stageStatuses(run); got != tt.stages {
+				t.Fatalf("got %s, want %s", got, tt.stages)
+			}
+			if tt.err != nil && !strings.Contains(run.Error, "connection refused") {
+				t.Fatalf("got error %q, want the injection error", run.Error)
+			}
+
+			probe := pipeline.injected[0]
+			if !strings.HasPrefix(probe.ID, ProbePrefix) || probe.Labels()["synthetic"] != "true" {
+				t.Fatalf("got probe %+v, want a labeled synthetic probe", probe)
+			}
+			if len(runner.active) != 0 {
+				t.Fatal("the run is still active")
+			}
+		})
+	}
+
+	t.Run("a complete run does not wait out the window", func(t *testing.T) {
+		runner, pipeline, _ := testRunner()
+		pipeline.emissions = []emission{{"etl", 0, false}, {"resolution", 0, false}, {"rules", 0, false}}
+		runner.cfg.Stages = []config.CanaryStage{
+			{Name: "etl", MaxLatency: time.Minute},
+			{Name: "resolution", MaxLatency: time.Minute},
+			{Name: "rules", MaxLatency: time.Minute},
+		}
+
+		started := time.Now()
+		if run := runner.RunOnce(context.Background()); run.Status != RunPassed {
+			t.Fatalf("got %s, want %s", run.Status, RunPassed)
+		}
+		if elapsed := time.Since(started); elapsed > 5*time.Second {
+			t.Fatalf("run took %v", elapsed)
+		}
+	})
+}
+
+func TestRunnerAlerts(t *testing.T) {
+	healthy := []emission{{"etl", 0, false}, {"resolution", 0, false}, {"rules", 0, false}}
+	rulesDown := []emission{{"etl", 0, false}, {"resolution", 0, false}}
+	resolutionDown := []emission{{"etl", 0, false}, {"rules", 0, false}}
+
+	for _, tt := range []struct {
+		name   string
+		runs   [][]emission
+		alerts []string
+	}{
+		{"healthy runs", [][]emission{healthy, healthy}, nil},
+		{"one alert per outage", [][]emission{rulesDown, rulesDown, rulesDown}, []string{"rules"}},
+		{"a recovered stage alerts again", [][]emission{rulesDown, healthy, rulesDown}, []string{"rules", "rules"}},
+		{"a second stage failing during an outage", [][]emission{rulesDown, resolutionDown}, []string{"rules", "resolution"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, pipeline, alerts := testRunner()
+
+			var alertIDs []string
+			for _, emissions := range tt.runs {
+				pipeline.emissions = emissions
+				if run := runner.RunOnce(context.Background()); run.AlertID != "" {
+					alertIDs = append(alertIDs, run.AlertID)
+				}
+			}
+
+			var got []string
+			for _, stages := range alerts.sent {
+				got = append(got, strings.Join(stages, ","))
+			}
+			if strings.Join(got, " ") != strings.Join(tt.alerts, " ") {
+				t.Fatalf("got alerts for %v, want %v", got, tt.alerts)
+			}
+			if len(alertIDs) != len(tt.alerts) {
+				t.Fatalf("got %d runs with an alert ID, want %d", len(alertIDs), len(tt.alerts))
+			}
+		})
+	}
+}
+
+func TestRunnerRuns(t *testing.T) {
+	runner, pipeline, _ := testRunner()
+	pipeline.emissions = []emission{{"etl", 0, false}, {"resolution", 0, false}, {"rules", 0, false}}
+
+	var last *Run
+	for i := 0; i < historySize+5; i++ {
+		last = runner.RunOnce(context.Background())
+	}
+
+	runs := runner.Runs()
+	if len(runs) != historySize {
+		t.Fatalf("got %d runs, want the last %d", len(runs), historySize)
+	}
+	if runs[0].ProbeID != last.ProbeID {
+		t.Fatalf("got %s first, want the newest run %s", runs[0].ProbeID, last.ProbeID)
+	}
+
+	// Runs are copies
+	runs[0].Stages[0].Status = StageMissing
+	if last.Stages[0].Status != StagePassed {
+		t.Fatal("changing a returned run changed the runner's history")
+	}
+}
@@ -10,13 +10,17 @@ import (
 
 // Config holds all configuration for the data ingestion service
 type Config struct {
-	Environment string         `json:"environment"`
-	Server      ServerConfig   `json:"server"`
-	Database    DatabaseConfig `json:"database"`
-	Storage     StorageConfig  `json:"storage"`
-	Kafka       KafkaConfig    `json:"kafka"`
-	Tracing     TracingConfig  `json:"tracing"`
-	Metrics     MetricsConfig  `json:"metrics"`
+	Environment string           `json:"environment"`
+	Server      ServerConfig     `json:"server"`
+	Database    DatabaseConfig   `json:"database"`
+	Storage     StorageConfig    `json:"storage"`
+	Kafka       KafkaConfig      `json:"kafka"`
+	Tracing     TracingConfig    `json:"tracing"`
+	Metrics     MetricsConfig    `json:"metrics"`
+	FeedHealth  FeedHealthConfig `json:"feed_health"`
+	Canary      CanaryConfig     `json:"canary"`
+	Auth        AuthConfig       `json:"auth"`
+	Quota       QuotaConfig      `json:"quota"`
 }
 
 type ServerConfig struct {
@@ -59,6 +63,13 @@ type KafkaConfig struct {
 	ProducerRetries      int           `json:"producer_retries"`
 	ProducerBatchSize    int           `json:"producer_batch_size"`
 	ProducerFlushTimeout time.Duration `json:"producer_flush_timeout"`
+
+	// Payload governance
+	Compression         string            `json:"compression"`           // none, gzip, snappy, lz4 or zstd
+	TopicCompression    map[string]string `json:"topic_compression"`     // per-topic overrides of Compression
+	MaxMessageBytes     int               `json:"max_message_bytes"`     // must not exceed the broker's message.max.bytes
+	ClaimCheckEnabled   bool              `json:"claim_check_enabled"`   // store oversized payloads and publish references
+	ClaimCheckThreshold int               `json:"claim_check_threshold"` // payloads larger than this are claim-checked
 	
 	// Topic configurations
 	Topics struct {
@@ -86,6 +97,75 @@ type MetricsConfig struct {
 	Subsystem  string `json:"subsystem"`
 }
 
+// FeedHealthConfig controls missing-feed and volume anomaly detection
+type FeedHealthConfig struct {
+	Enabled           bool          `json:"enabled"`
+	CheckInterval     time.Duration `json:"check_interval"`
+	Lookback          time.Duration `json:"lookback"`
+	DashboardDays     int           `json:"dashboard_days"`
+	AlertingEngineURL string        `json:"alerting_engine_url"`
+	AlertTimeout      time.Duration `json:"alert_timeout"`
+}
+
+// AuthConfig controls authentication of the REST API. When UserServiceAddr
+// is set, requests need a service account API key, validated by
+// user-management, whose scopes grant the ingestion resource.
+type AuthConfig struct {
+	UserServiceAddr string `json:"user_service_addr"` // empty leaves the REST API unauthenticated
+}
+
+// QuotaConfig controls per-tenant and per-user upload storage limits.
+// Limits of zero are unlimited; limits set through the quota API replace
+// these defaults for one tenant or user.
+type QuotaConfig struct {
+	Enabled          bool    `json:"enabled"`
+	TenantHeader     string  `json:"tenant_header"` // HTTP header, and lowercased gRPC metadata key, naming the tenant
+	DefaultTenant    string  `json:"default_tenant"`
+	TenantLimitBytes int64   `json:"tenant_limit_bytes"`
+	TenantLimitFiles int64   `json:"tenant_limit_files"`
+	UserLimitBytes   int64   `json:"user_limit_bytes"`
+	UserLimitFiles   int64   `json:"user_limit_files"`
+	WarningPercent   float64 `json:"warning_percent"`   // tenants are alerted when uploads reach this share of a limit
+	HardStopPercent  float64 `json:"hard_stop_percent"` // uploads past this share of a limit are refused
+	// Quota held for an upload is returned if the upload neither completes
+	// nor fails within the TTL
+	ReservationTTL    time.Duration `json:"reservation_ttl"`
+	SweepInterval     time.Duration `json:"sweep_interval"`
+	AlertingEngineURL string        `json:"alerting_engine_url"`
+	AlertTimeout      time.Duration `json:"alert_timeout"`
+}
+
+// CanaryConfig controls the synthetic transactions injected on a schedule
+// to check the pipeline end to end
+type CanaryConfig struct {
+	Enabled           bool          `json:"enabled"`
+	Interval          time.Duration `json:"interval"`
+	IngestionAddr     string        `json:"ingestion_addr"` // gRPC address probes are injected at
+	Amount            float64       `json:"amount"`         // large enough for rule evaluation to alert on
+	Currency          string        `json:"currency"`
+	Stages            []CanaryStage `json:"stages"`
+	AlertingEngineURL string        `json:"alerting_engine_url"`
+	AlertTimeout      time.Duration `json:"alert_timeout"`
+}
+
+// CanaryStage is a pipeline stage that must emit every probe on its topic
+// within MaxLatency of the injection
+type CanaryStage struct {
+	Name       string        `json:"name"`
+	Topic      string        `json:"topic"`
+	MaxLatency time.Duration `json:"max_latency"`
+}
+
+// defaultCanaryStages follows a transaction from ETL through entity
+// resolution and the graph to rule evaluation; the ETL topic is the
+// service's own transaction flow topic
+var defaultCanaryStages = []CanaryStage{
+	{Name: "etl", MaxLatency: 30 * time.Second},
+	{Name: "resolution", Topic: "entities.resolved", MaxLatency: 2 * time.Minute},
+	{Name: "graph", Topic: "network.events", MaxLatency: 3 * time.Minute},
+	{Name: "rules", Topic: "alert-generated", MaxLatency: 5 * time.Minute},
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	cfg := &Config{
@@ -127,6 +207,11 @@ func Load() (*Config, error) {
 			ProducerRetries:      getEnvAsInt("KAFKA_PRODUCER_RETRIES", 3),
 			ProducerBatchSize:    getEnvAsInt("KAFKA_PRODUCER_BATCH_SIZE", 16384),
 			ProducerFlushTimeout: getEnvAsDuration("KAFKA_PRODUCER_FLUSH_TIMEOUT", "5s"),
+			Compression:          getEnv("KAFKA_COMPRESSION", "snappy"),
+			TopicCompression:     getEnvAsMap("KAFKA_TOPIC_COMPRESSION"),
+			MaxMessageBytes:      getEnvAsInt("KAFKA_MAX_MESSAGE_BYTES", 1024*1024),
+			ClaimCheckEnabled:    getEnvAsBool("KAFKA_CLAIM_CHECK_ENABLED", true),
+			ClaimCheckThreshold:  getEnvAsInt("KAFKA_CLAIM_CHECK_THRESHOLD", 512*1024),
 		},
 		Tracing: TracingConfig{
 			Enabled:     getEnvAsBool("TRACING_ENABLED", true),
@@ -142,6 +227,41 @@ func Load() (*Config, error) {
 			Namespace: getEnv("METRICS_NAMESPACE", "aegisshield"),
 			Subsystem: getEnv("METRICS_SUBSYSTEM", "data_ingestion"),
 		},
+		FeedHealth: FeedHealthConfig{
+			Enabled:           getEnvAsBool("FEED_HEALTH_ENABLED", true),
+			CheckInterval:     getEnvAsDuration("FEED_HEALTH_CHECK_INTERVAL", "5m"),
+			Lookback:          getEnvAsDuration("FEED_HEALTH_LOOKBACK", "24h"),
+			DashboardDays:     getEnvAsInt("FEED_HEALTH_DASHBOARD_DAYS", 14),
+			AlertingEngineURL: getEnv("ALERTING_ENGINE_URL", "http://localhost:8084"),
+			AlertTimeout:      getEnvAsDuration("FEED_HEALTH_ALERT_TIMEOUT", "10s"),
+		},
+		Canary: CanaryConfig{
+			Enabled:           getEnvAsBool("CANARY_ENABLED", false),
+			Interval:          getEnvAsDuration("CANARY_INTERVAL", "5m"),
+			IngestionAddr:     getEnv("CANARY_INGESTION_ADDR", "localhost:50051"),
+			Amount:            getEnvAsFloat64("CANARY_AMOUNT", 15000),
+			Currency:          getEnv("CANARY_CURRENCY", "USD"),
+			AlertingEngineURL: getEnv("ALERTING_ENGINE_URL", "http://localhost:8084"),
+			AlertTimeout:      getEnvAsDuration("CANARY_ALERT_TIMEOUT", "10s"),
+		},
+		Auth: AuthConfig{
+			UserServiceAddr: getEnv("USER_SERVICE_ADDR", ""),
+		},
+		Quota: QuotaConfig{
+			Enabled:           getEnvAsBool("QUOTA_ENABLED", true),
+			TenantHeader:      getEnv("QUOTA_TENANT_HEADER", "X-Tenant-ID"),
+			DefaultTenant:     getEnv("QUOTA_DEFAULT_TENANT", "default"),
+			TenantLimitBytes:  getEnvAsInt64("QUOTA_TENANT_LIMIT_BYTES", 1<<40), // 1TB
+			TenantLimitFiles:  getEnvAsInt64("QUOTA_TENANT_LIMIT_FILES", 0),
+			UserLimitBytes:    getEnvAsInt64("QUOTA_USER_LIMIT_BYTES", 100<<30), // 100GB
+			UserLimitFiles:    getEnvAsInt64("QUOTA_USER_LIMIT_FILES", 0),
+			WarningPercent:    getEnvAsFloat64("QUOTA_WARNING_PERCENT", 80),
+			HardStopPercent:   getEnvAsFloat64("QUOTA_HARD_STOP_PERCENT", 100),
+			ReservationTTL:    getEnvAsDuration("QUOTA_RESERVATION_TTL", "30m"),
+			SweepInterval:     getEnvAsDuration("QUOTA_SWEEP_INTERVAL", "5m"),
+			AlertingEngineURL: getEnv("ALERTING_ENGINE_URL", "http://localhost:8084"),
+			AlertTimeout:      getEnvAsDuration("QUOTA_ALERT_TIMEOUT", "10s"),
+		},
 	}
 
 	// Set Kafka topics
@@ -151,6 +271,8 @@ func Load() (*Config, error) {
 	cfg.Kafka.Topics.TransactionFlow = getEnv("KAFKA_TOPIC_TRANSACTION_FLOW", "aegis.data.transaction-flow")
 	cfg.Kafka.Topics.ErrorEvents = getEnv("KAFKA_TOPIC_ERROR_EVENTS", "aegis.data.errors")
 
+	cfg.Canary.Stages = loadCanaryStages(cfg.Kafka.Topics.TransactionFlow)
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
@@ -169,6 +291,23 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("at least one Kafka broker is required")
 	}
 
+	if c.Kafka.MaxMessageBytes <= 0 {
+		return fmt.Errorf("Kafka max message bytes must be positive")
+	}
+
+	if c.Kafka.ClaimCheckEnabled && (c.Kafka.ClaimCheckThreshold <= 0 || c.Kafka.ClaimCheckThreshold > c.Kafka.MaxMessageBytes) {
+		return fmt.Errorf("Kafka claim check threshold must be positive and at most the max message bytes")
+	}
+
+	if !validCompressions[c.Kafka.Compression] {
+		return fmt.Errorf("unsupported Kafka compression: %s", c.Kafka.Compression)
+	}
+	for topic, codec := range c.Kafka.TopicCompression {
+		if !validCompressions[codec] {
+			return fmt.Errorf("unsupported Kafka compression %s for topic %s", codec, topic)
+		}
+	}
+
 	if c.Storage.Type == "s3" || c.Storage.Type == "gcs" {
 		if c.Storage.BucketName == "" {
 			return fmt.Errorf("bucket name is required for cloud storage")
@@ -183,9 +322,83 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("max file size must be positive")
 	}
 
+	if c.FeedHealth.Enabled && (c.FeedHealth.CheckInterval <= 0 || c.FeedHealth.Lookback <= 0) {
+		return fmt.Errorf("feed health check interval and lookback must be positive")
+	}
+
+	if c.Canary.Enabled {
+		if c.Canary.Interval <= 0 || c.Canary.Amount <= 0 {
+			return fmt.Errorf("canary interval and amount must be positive")
+		}
+		if len(c.Canary.Stages) == 0 {
+			return fmt.Errorf("at least one canary stage is required")
+		}
+		for _, stage := range c.Canary.Stages {
+			if stage.Topic == "" || stage.MaxLatency <= 0 {
+				return fmt.Errorf("canary stage %s needs a topic and a positive max latency", stage.Name)
+			}
+		}
+	}
+
+	if c.Quota.Enabled {
+		if c.Quota.TenantHeader == "" || c.Quota.DefaultTenant == "" {
+			return fmt.Errorf("quota tenant header and default tenant are required")
+		}
+		if c.Quota.TenantLimitBytes < 0 || c.Quota.TenantLimitFiles < 0 || c.Quota.UserLimitBytes < 0 || c.Quota.UserLimitFiles < 0 {
+			return fmt.Errorf("quota limits must not be negative")
+		}
+		if c.Quota.WarningPercent <= 0 || c.Quota.WarningPercent >= c.Quota.HardStopPercent {
+			return fmt.Errorf("quota warning percent must be positive and below the hard stop percent")
+		}
+		if c.Quota.ReservationTTL <= 0 || c.Quota.SweepInterval <= 0 {
+			return fmt.Errorf("quota reservation TTL and sweep interval must be positive")
+		}
+	}
+
 	return nil
 }
 
+// validCompressions are the Kafka compression codecs producers support
+var validCompressions = map[string]bool{
+	"none":   true,
+	"gzip":   true,
+	"snappy": true,
+	"lz4":    true,
+	"zstd":   true,
+}
+
+// loadCanaryStages picks the stages named in CANARY_STAGES (all by default)
+// in pipeline order, applying CANARY_STAGE_TOPICS and CANARY_STAGE_LATENCIES
+// overrides given as "stage=value" pairs
+func loadCanaryStages(transactionTopic string) []CanaryStage {
+	names := getEnvAsStringSlice("CANARY_STAGES", nil)
+	topics := getEnvAsMap("CANARY_STAGE_TOPICS")
+	latencies := getEnvAsMap("CANARY_STAGE_LATENCIES")
+
+	enabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		enabled[strings.TrimSpace(name)] = true
+	}
+
+	var stages []CanaryStage
+	for _, stage := range defaultCanaryStages {
+		if len(enabled) > 0 && !enabled[stage.Name] {
+			continue
+		}
+		if stage.Name == "etl" {
+			stage.Topic = transactionTopic
+		}
+		if topic := topics[stage.Name]; topic != "" {
+			stage.Topic = topic
+		}
+		if latency, err := time.ParseDuration(latencies[stage.Name]); err == nil {
+			stage.MaxLatency = latency
+		}
+		stages = append(stages, stage)
+	}
+	return stages
+}
+
 // Utility functions for environment variable parsing
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -248,4 +461,18 @@ func getEnvAsStringSlice(key string, defaultValue []string) []string {
 		return strings.Split(value, ",")
 	}
 	return defaultValue
+}
+
+// getEnvAsMap parses comma-separated key=value pairs such as
+// "aegis.data.errors=none,aegis.data.transaction-flow=zstd"
+func getEnvAsMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" {
+			continue
+		}
+		result[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return result
 }
\ No newline at end of file
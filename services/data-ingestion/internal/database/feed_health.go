@@ -0,0 +1,356 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrFeedSourceNotFound is returned when a feed source does not exist
+	ErrFeedSourceNotFound = errors.New("feed source not found")
+	// ErrFeedIssueNotFound is returned when a feed health issue does not exist or is already resolved
+	ErrFeedIssueNotFound = errors.New("open feed health issue not found")
+)
+
+// FeedHealthRepository handles feed source, delivery and health issue persistence
+type FeedHealthRepository struct {
+	db *sql.DB
+}
+
+func NewFeedHealthRepository(db *sql.DB) *FeedHealthRepository {
+	return &FeedHealthRepository{db: db}
+}
+
+// ArrivalWindow is a daily time range in the source's timezone in which a
+// feed is expected. Days uses 0 for Sunday; an empty list means every day.
+// An end before the start spans midnight.
+type ArrivalWindow struct {
+	Days  []int  `json:"days,omitempty"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type FeedSource struct {
+	ID                 string          `json:"id"`
+	Name               string          `json:"name"`
+	Description        string          `json:"description,omitempty"`
+	Timezone           string          `json:"timezone"`
+	ArrivalWindows     []ArrivalWindow `json:"arrival_windows"`
+	GracePeriodMinutes int             `json:"grace_period_minutes"`
+	MinRecords         int64           `json:"min_records"`
+	DeviationThreshold float64         `json:"deviation_threshold"`
+	BaselineWeeks      int             `json:"baseline_weeks"`
+	Enabled            bool            `json:"enabled"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+type FeedDelivery struct {
+	ID          string    `json:"id"`
+	SourceID    string    `json:"source_id"`
+	FileID      *string   `json:"file_id,omitempty"`
+	RecordCount int64     `json:"record_count"`
+	ReceivedAt  time.Time `json:"received_at"`
+}
+
+// DailyVolume is the number of files and records a feed delivered on one day
+type DailyVolume struct {
+	Date    string `json:"date"`
+	Files   int    `json:"files"`
+	Records int64  `json:"records"`
+}
+
+type FeedHealthIssue struct {
+	ID              string     `json:"id"`
+	SourceID        string     `json:"source_id"`
+	IssueType       string     `json:"issue_type"`
+	Severity        string     `json:"severity"`
+	Status          string     `json:"status"`
+	WindowStart     time.Time  `json:"window_start"`
+	WindowEnd       time.Time  `json:"window_end"`
+	ExpectedRecords *float64   `json:"expected_records,omitempty"`
+	ActualRecords   *int64     `json:"actual_records,omitempty"`
+	Message         string     `json:"message"`
+	AlertID         *string    `json:"alert_id,omitempty"`
+	DetectedAt      time.Time  `json:"detected_at"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy      *string    `json:"resolved_by,omitempty"`
+}
+
+const feedSourceColumns = `
+	id, name, COALESCE(description, ''), timezone, arrival_windows, grace_period_minutes,
+	min_records, deviation_threshold, baseline_weeks, enabled, created_at, updated_at`
+
+const feedIssueColumns = `
+	id, source_id, issue_type, severity, status, window_start, window_end,
+	expected_records, actual_records, message, alert_id, detected_at, resolved_at, resolved_by`
+
+// UpsertSource creates a feed source or replaces its settings
+func (r *FeedHealthRepository) UpsertSource(ctx context.Context, source *FeedSource) error {
+	windowsJSON, err := json.Marshal(source.ArrivalWindows)
+	if err != nil {
+		return fmt.Errorf("failed to marshal arrival windows: %w", err)
+	}
+
+	query := `
+		INSERT INTO feed_sources (
+			id, name, description, timezone, arrival_windows, grace_period_minutes,
+			min_records, deviation_threshold, baseline_weeks, enabled
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			description = EXCLUDED.description,
+			timezone = EXCLUDED.timezone,
+			arrival_windows = EXCLUDED.arrival_windows,
+			grace_period_minutes = EXCLUDED.grace_period_minutes,
+			min_records = EXCLUDED.min_records,
+			deviation_threshold = EXCLUDED.deviation_threshold,
+			baseline_weeks = EXCLUDED.baseline_weeks,
+			enabled = EXCLUDED.enabled,
+			updated_at = NOW()
+		RETURNING created_at, updated_at`
+
+	return r.db.QueryRowContext(ctx, query,
+		source.ID, source.Name, source.Description, source.Timezone, windowsJSON,
+		source.GracePeriodMinutes, source.MinRecords, source.DeviationThreshold,
+		source.BaselineWeeks, source.Enabled,
+	).Scan(&source.CreatedAt, &source.UpdatedAt)
+}
+
+func (r *FeedHealthRepository) GetSource(ctx context.Context, id string) (*FeedSource, error) {
+	query := `SELECT ` + feedSourceColumns + ` FROM feed_sources WHERE id = $1`
+
+	source, err := scanFeedSource(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrFeedSourceNotFound
+	}
+	return source, err
+}
+
+func (r *FeedHealthRepository) ListSources(ctx context.Context, enabledOnly bool) ([]*FeedSource, error) {
+	query := `SELECT ` + feedSourceColumns + ` FROM feed_sources`
+	if enabledOnly {
+		query += ` WHERE enabled = TRUE`
+	}
+	query += ` ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sources []*FeedSource
+	for rows.Next() {
+		source, err := scanFeedSource(rows)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	return sources, rows.Err()
+}
+
+func (r *FeedHealthRepository) RecordDelivery(ctx context.Context, delivery *FeedDelivery) error {
+	query := `
+		INSERT INTO feed_deliveries (source_id, file_id, record_count, received_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+
+	return r.db.QueryRowContext(ctx, query,
+		delivery.SourceID, delivery.FileID, delivery.RecordCount, delivery.ReceivedAt,
+	).Scan(&delivery.ID)
+}
+
+// SumDeliveries returns how many deliveries and records a feed received in [from, to)
+func (r *FeedHealthRepository) SumDeliveries(ctx context.Context, sourceID string, from, to time.Time) (int, int64, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(record_count), 0)
+		FROM feed_deliveries
+		WHERE source_id = $1 AND received_at >= $2 AND received_at < $3`
+
+	var files int
+	var records int64
+	err := r.db.QueryRowContext(ctx, query, sourceID, from, to).Scan(&files, &records)
+	return files, records, err
+}
+
+// LastDelivery returns a feed's most recent delivery, or nil if it has none
+func (r *FeedHealthRepository) LastDelivery(ctx context.Context, sourceID string) (*FeedDelivery, error) {
+	query := `
+		SELECT id, source_id, file_id, record_count, received_at
+		FROM feed_deliveries
+		WHERE source_id = $1
+		ORDER BY received_at DESC
+		LIMIT 1`
+
+	delivery := &FeedDelivery{}
+	err := r.db.QueryRowContext(ctx, query, sourceID).Scan(
+		&delivery.ID, &delivery.SourceID, &delivery.FileID, &delivery.RecordCount, &delivery.ReceivedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return delivery, err
+}
+
+// DailyVolumes returns per-day delivery totals since from, with days taken in the given timezone
+func (r *FeedHealthRepository) DailyVolumes(ctx context.Context, sourceID string, from time.Time, timezone string) ([]DailyVolume, error) {
+	query := `
+		SELECT TO_CHAR((received_at AT TIME ZONE $3)::date, 'YYYY-MM-DD') AS day,
+			   COUNT(*), COALESCE(SUM(record_count), 0)
+		FROM feed_deliveries
+		WHERE source_id = $1 AND received_at >= $2
+		GROUP BY day
+		ORDER BY day`
+
+	rows, err := r.db.QueryContext(ctx, query, sourceID, from, timezone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var volumes []DailyVolume
+	for rows.Next() {
+		var volume DailyVolume
+		if err := rows.Scan(&volume.Date, &volume.Files, &volume.Records); err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, volume)
+	}
+
+	return volumes, rows.Err()
+}
+
+// CreateIssue stores a new issue and reports false when the same source,
+// type and window was already recorded
+func (r *FeedHealthRepository) CreateIssue(ctx context.Context, issue *FeedHealthIssue) (bool, error) {
+	query := `
+		INSERT INTO feed_health_issues (
+			source_id, issue_type, severity, status, window_start, window_end,
+			expected_records, actual_records, message, detected_at
+		) VALUES ($1, $2, $3, 'open', $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (source_id, issue_type, window_start) DO NOTHING
+		RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, query,
+		issue.SourceID, issue.IssueType, issue.Severity, issue.WindowStart, issue.WindowEnd,
+		issue.ExpectedRecords, issue.ActualRecords, issue.Message, issue.DetectedAt,
+	).Scan(&issue.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	issue.Status = "open"
+	return true, nil
+}
+
+func (r *FeedHealthRepository) SetIssueAlert(ctx context.Context, id, alertID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE feed_health_issues SET alert_id = $2 WHERE id = $1`, id, alertID)
+	return err
+}
+
+// ListIssues lists issues newest first, optionally filtered by source and status
+func (r *FeedHealthRepository) ListIssues(ctx context.Context, sourceID, status string, limit int) ([]*FeedHealthIssue, error) {
+	query := `
+		SELECT ` + feedIssueColumns + `
+		FROM feed_health_issues
+		WHERE ($1 = '' OR source_id = $1) AND ($2 = '' OR status = $2)
+		ORDER BY detected_at DESC
+		LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, query, sourceID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var issues []*FeedHealthIssue
+	for rows.Next() {
+		issue, err := scanFeedIssue(rows)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, issue)
+	}
+
+	return issues, rows.Err()
+}
+
+func (r *FeedHealthRepository) ResolveIssue(ctx context.Context, id, resolvedBy string) (*FeedHealthIssue, error) {
+	query := `
+		UPDATE feed_health_issues
+		SET status = 'resolved', resolved_at = NOW(), resolved_by = $2
+		WHERE id = $1 AND status = 'open'
+		RETURNING ` + feedIssueColumns
+
+	issue, err := scanFeedIssue(r.db.QueryRowContext(ctx, query, id, resolvedBy))
+	if err == sql.ErrNoRows {
+		return nil, ErrFeedIssueNotFound
+	}
+	return issue, err
+}
+
+// ResolveMissingFeedIssues closes open missing feed issues for windows that
+// started before a late delivery arrived
+func (r *FeedHealthRepository) ResolveMissingFeedIssues(ctx context.Context, sourceID string, receivedAt time.Time, resolvedBy string) (int64, error) {
+	query := `
+		UPDATE feed_health_issues
+		SET status = 'resolved', resolved_at = NOW(), resolved_by = $3
+		WHERE source_id = $1 AND issue_type = 'missing_feed' AND status = 'open' AND window_start <= $2`
+
+	result, err := r.db.ExecContext(ctx, query, sourceID, receivedAt, resolvedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanFeedSource(row rowScanner) (*FeedSource, error) {
+	source := &FeedSource{}
+	var windowsJSON []byte
+
+	err := row.Scan(
+		&source.ID, &source.Name, &source.Description, &source.Timezone, &windowsJSON,
+		&source.GracePeriodMinutes, &source.MinRecords, &source.DeviationThreshold,
+		&source.BaselineWeeks, &source.Enabled, &source.CreatedAt, &source.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(windowsJSON) > 0 {
+		if err := json.Unmarshal(windowsJSON, &source.ArrivalWindows); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal arrival windows of feed %s: %w", source.ID, err)
+		}
+	}
+
+	return source, nil
+}
+
+func scanFeedIssue(row rowScanner) (*FeedHealthIssue, error) {
+	issue := &FeedHealthIssue{}
+
+	err := row.Scan(
+		&issue.ID, &issue.SourceID, &issue.IssueType, &issue.Severity, &issue.Status,
+		&issue.WindowStart, &issue.WindowEnd, &issue.ExpectedRecords, &issue.ActualRecords,
+		&issue.Message, &issue.AlertID, &issue.DetectedAt, &issue.ResolvedAt, &issue.ResolvedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return issue, nil
+}
@@ -0,0 +1,345 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Quota scopes
+const (
+	QuotaScopeTenant = "tenant"
+	QuotaScopeUser   = "user"
+)
+
+// QuotaRepository handles upload storage usage, limits and reservations
+type QuotaRepository struct {
+	db *sql.DB
+}
+
+func NewQuotaRepository(db *sql.DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+// QuotaUsage is the upload storage a tenant or user holds. LimitBytes and
+// LimitFiles are set only when the subject has limits of its own in place
+// of the configured defaults.
+type QuotaUsage struct {
+	Scope         string    `json:"scope"`
+	SubjectID     string    `json:"subject_id"`
+	UsedBytes     int64     `json:"used_bytes"`
+	UsedFiles     int64     `json:"used_files"`
+	ReservedBytes int64     `json:"reserved_bytes"`
+	ReservedFiles int64     `json:"reserved_files"`
+	LimitBytes    *int64    `json:"limit_bytes,omitempty"`
+	LimitFiles    *int64    `json:"limit_files,omitempty"`
+	AlertedLevel  string    `json:"alerted_level"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// QuotaLimit replaces the configured limits for one tenant or user. A nil
+// limit keeps the default; zero is unlimited.
+type QuotaLimit struct {
+	Scope      string    `json:"scope"`
+	SubjectID  string    `json:"subject_id"`
+	LimitBytes *int64    `json:"limit_bytes,omitempty"`
+	LimitFiles *int64    `json:"limit_files,omitempty"`
+	UpdatedBy  string    `json:"updated_by"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// QuotaReservation holds quota for an upload in progress until it is
+// stored or fails
+type QuotaReservation struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	UserID    string    `json:"user_id"`
+	Bytes     int64     `json:"bytes"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const quotaUsageColumns = `
+	u.scope, u.subject_id, u.used_bytes, u.used_files, u.reserved_bytes, u.reserved_files,
+	l.limit_bytes, l.limit_files, u.alerted_level, u.updated_at`
+
+func scanQuotaUsage(row rowScanner) (*QuotaUsage, error) {
+	usage := &QuotaUsage{}
+	var limitBytes, limitFiles sql.NullInt64
+	err := row.Scan(
+		&usage.Scope, &usage.SubjectID, &usage.UsedBytes, &usage.UsedFiles,
+		&usage.ReservedBytes, &usage.ReservedFiles, &limitBytes, &limitFiles,
+		&usage.AlertedLevel, &usage.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if limitBytes.Valid {
+		usage.LimitBytes = &limitBytes.Int64
+	}
+	if limitFiles.Valid {
+		usage.LimitFiles = &limitFiles.Int64
+	}
+	return usage, nil
+}
+
+// lockUsage creates a subject's usage row if it has none and locks it for
+// the rest of the transaction
+func lockUsage(ctx context.Context, tx *sql.Tx, scope, subjectID string) (*QuotaUsage, error) {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO storage_quota_usage (scope, subject_id) VALUES ($1, $2)
+		ON CONFLICT (scope, subject_id) DO NOTHING`,
+		scope, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quota usage: %w", err)
+	}
+
+	usage, err := scanQuotaUsage(tx.QueryRowContext(ctx, `
+		SELECT `+quotaUsageColumns+`
+		FROM storage_quota_usage u
+		LEFT JOIN storage_quota_limits l ON l.scope = u.scope AND l.subject_id = u.subject_id
+		WHERE u.scope = $1 AND u.subject_id = $2
+		FOR UPDATE OF u`,
+		scope, subjectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock quota usage: %w", err)
+	}
+	return usage, nil
+}
+
+// adjustUsage adds to a subject's stored and reserved bytes and files and
+// returns the updated usage
+func adjustUsage(ctx context.Context, tx *sql.Tx, scope, subjectID string, usedBytes, usedFiles, reservedBytes, reservedFiles int64) (*QuotaUsage, error) {
+	usage, err := scanQuotaUsage(tx.QueryRowContext(ctx, `
+		WITH u AS (
+			UPDATE storage_quota_usage
+			SET used_bytes = GREATEST(used_bytes + $3, 0), used_files = GREATEST(used_files + $4, 0),
+				reserved_bytes = GREATEST(reserved_bytes + $5, 0), reserved_files = GREATEST(reserved_files + $6, 0),
+				updated_at = NOW()
+			WHERE scope = $1 AND subject_id = $2
+			RETURNING *
+		)
+		SELECT `+quotaUsageColumns+`
+		FROM u
+		LEFT JOIN storage_quota_limits l ON l.scope = u.scope AND l.subject_id = u.subject_id`,
+		scope, subjectID, usedBytes, usedFiles, reservedBytes, reservedFiles))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update quota usage: %w", err)
+	}
+	return usage, nil
+}
+
+// Reserve locks the usage of the reservation's tenant and user, tenant
+// first, calls check with it and, unless check fails, holds the
+// reservation's bytes and one file against both. Concurrent reservations
+// for the same tenant or user are checked one after another.
+func (r *QuotaRepository) Reserve(ctx context.Context, reservation *QuotaReservation, check func(tenant, user *QuotaUsage) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenant, err := lockUsage(ctx, tx, QuotaScopeTenant, reservation.TenantID)
+	if err != nil {
+		return err
+	}
+	user, err := lockUsage(ctx, tx, QuotaScopeUser, reservation.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := check(tenant, user); err != nil {
+		return err
+	}
+
+	if _, err := adjustUsage(ctx, tx, QuotaScopeTenant, reservation.TenantID, 0, 0, reservation.Bytes, 1); err != nil {
+		return err
+	}
+	if _, err := adjustUsage(ctx, tx, QuotaScopeUser, reservation.UserID, 0, 0, reservation.Bytes, 1); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO storage_quota_reservations (tenant_id, user_id, bytes, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		reservation.TenantID, reservation.UserID, reservation.Bytes, reservation.ExpiresAt,
+	).Scan(&reservation.ID, &reservation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create quota reservation: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Commit moves a reservation's bytes and file from reserved to stored and
+// returns the updated usage. A reservation that already expired is charged
+// all the same.
+func (r *QuotaRepository) Commit(ctx context.Context, reservation *QuotaReservation) (*QuotaUsage, *QuotaUsage, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock in the same order as Reserve
+	if _, err := lockUsage(ctx, tx, QuotaScopeTenant, reservation.TenantID); err != nil {
+		return nil, nil, err
+	}
+	if _, err := lockUsage(ctx, tx, QuotaScopeUser, reservation.UserID); err != nil {
+		return nil, nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM storage_quota_reservations WHERE id = $1`, reservation.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to free quota reservation: %w", err)
+	}
+	var reservedBytes, reservedFiles int64
+	if rows, err := result.RowsAffected(); err == nil && rows > 0 {
+		reservedBytes, reservedFiles = reservation.Bytes, 1
+	}
+
+	tenant, err := adjustUsage(ctx, tx, QuotaScopeTenant, reservation.TenantID, reservation.Bytes, 1, -reservedBytes, -reservedFiles)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := adjustUsage(ctx, tx, QuotaScopeUser, reservation.UserID, reservation.Bytes, 1, -reservedBytes, -reservedFiles)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return tenant, user, nil
+}
+
+// Release frees a reservation
+func (r *QuotaRepository) Release(ctx context.Context, reservationID string) error {
+	_, err := r.release(ctx, `WHERE id = $1`, reservationID)
+	return err
+}
+
+// ReleaseExpired frees the reservations that expired before now
+func (r *QuotaRepository) ReleaseExpired(ctx context.Context, now time.Time) (int, error) {
+	return r.release(ctx, `WHERE expires_at < $1`, now)
+}
+
+func (r *QuotaRepository) release(ctx context.Context, where string, arg interface{}) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		DELETE FROM storage_quota_reservations `+where+`
+		RETURNING tenant_id, user_id, bytes`,
+		arg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to release quota reservations: %w", err)
+	}
+
+	var freed []QuotaReservation
+	for rows.Next() {
+		var reservation QuotaReservation
+		if err := rows.Scan(&reservation.TenantID, &reservation.UserID, &reservation.Bytes); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		freed = append(freed, reservation)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, reservation := range freed {
+		if _, err := adjustUsage(ctx, tx, QuotaScopeTenant, reservation.TenantID, 0, 0, -reservation.Bytes, -1); err != nil {
+			return 0, err
+		}
+		if _, err := adjustUsage(ctx, tx, QuotaScopeUser, reservation.UserID, 0, 0, -reservation.Bytes, -1); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(freed), tx.Commit()
+}
+
+// GetUsage returns a subject's usage, which is empty if it has stored nothing
+func (r *QuotaRepository) GetUsage(ctx context.Context, scope, subjectID string) (*QuotaUsage, error) {
+	query := `
+		SELECT $1::varchar, $2::varchar,
+			COALESCE(u.used_bytes, 0), COALESCE(u.used_files, 0),
+			COALESCE(u.reserved_bytes, 0), COALESCE(u.reserved_files, 0),
+			l.limit_bytes, l.limit_files, COALESCE(u.alerted_level, 'ok'),
+			COALESCE(u.updated_at, l.updated_at, NOW())
+		FROM (SELECT 1) AS subject
+		LEFT JOIN storage_quota_usage u ON u.scope = $1 AND u.subject_id = $2
+		LEFT JOIN storage_quota_limits l ON l.scope = $1 AND l.subject_id = $2`
+
+	return scanQuotaUsage(r.db.QueryRowContext(ctx, query, scope, subjectID))
+}
+
+// ListUsage returns the usage of every subject in a scope, largest first
+func (r *QuotaRepository) ListUsage(ctx context.Context, scope string) ([]*QuotaUsage, error) {
+	query := `SELECT ` + quotaUsageColumns + `
+		FROM storage_quota_usage u
+		LEFT JOIN storage_quota_limits l ON l.scope = u.scope AND l.subject_id = u.subject_id
+		WHERE u.scope = $1
+		ORDER BY u.used_bytes + u.reserved_bytes DESC, u.subject_id`
+
+	rows, err := r.db.QueryContext(ctx, query, scope)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usages []*QuotaUsage
+	for rows.Next() {
+		usage, err := scanQuotaUsage(rows)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+
+	return usages, rows.Err()
+}
+
+// SetLimit replaces a subject's limits
+func (r *QuotaRepository) SetLimit(ctx context.Context, limit *QuotaLimit) error {
+	query := `
+		INSERT INTO storage_quota_limits (scope, subject_id, limit_bytes, limit_files, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (scope, subject_id) DO UPDATE SET
+			limit_bytes = EXCLUDED.limit_bytes,
+			limit_files = EXCLUDED.limit_files,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING updated_at`
+
+	return r.db.QueryRowContext(ctx, query,
+		limit.Scope, limit.SubjectID, limit.LimitBytes, limit.LimitFiles, limit.UpdatedBy,
+	).Scan(&limit.UpdatedAt)
+}
+
+// MarkAlerted records the quota level last alerted on for a subject, if it
+// is still from. It reports whether the level was changed.
+func (r *QuotaRepository) MarkAlerted(ctx context.Context, scope, subjectID, from, to string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE storage_quota_usage SET alerted_level = $4
+		WHERE scope = $1 AND subject_id = $2 AND alerted_level = $3`,
+		scope, subjectID, from, to)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,97 @@
+package feedhealth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"aegisshield/services/data-ingestion/internal/database"
+)
+
+// AlertSender raises an alert for a feed health issue and returns its ID
+type AlertSender interface {
+	SendAlert(ctx context.Context, source *database.FeedSource, issue *database.FeedHealthIssue) (string, error)
+}
+
+// AlertingEngineClient raises feed health alerts through the alerting engine's REST API
+type AlertingEngineClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewAlertingEngineClient(baseURL string, timeout time.Duration) *AlertingEngineClient {
+	return &AlertingEngineClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// SendAlert creates an alert for the issue in the alerting engine
+func (c *AlertingEngineClient) SendAlert(ctx context.Context, source *database.FeedSource, issue *database.FeedHealthIssue) (string, error) {
+	payload := map[string]interface{}{
+		"title":       alertTitle(source, issue),
+		"description": issue.Message,
+		"severity":    issue.Severity,
+		"type":        "data_quality",
+		"priority":    issue.Severity,
+		"source":      "data-ingestion",
+		"created_by":  "feed-health-monitor",
+		"event_data": map[string]interface{}{
+			"feed_source_id":   source.ID,
+			"issue_id":         issue.ID,
+			"issue_type":       issue.IssueType,
+			"window_start":     issue.WindowStart,
+			"window_end":       issue.WindowEnd,
+			"expected_records": issue.ExpectedRecords,
+			"actual_records":   issue.ActualRecords,
+		},
+		"metadata": map[string]interface{}{
+			"feed_name": source.Name,
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/alerts", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("alerting engine returned status %d", resp.StatusCode)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("failed to decode alert response: %w", err)
+	}
+
+	return created.ID, nil
+}
+
+func alertTitle(source *database.FeedSource, issue *database.FeedHealthIssue) string {
+	switch issue.IssueType {
+	case IssueMissingFeed:
+		return fmt.Sprintf("Feed %s missing for window starting %s", source.Name, issue.WindowStart.Format(time.RFC3339))
+	case IssueVolumeSpike:
+		return fmt.Sprintf("Feed %s volume spike", source.Name)
+	default:
+		return fmt.Sprintf("Feed %s volume drop", source.Name)
+	}
+}
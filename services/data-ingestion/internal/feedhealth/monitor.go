@@ -0,0 +1,352 @@
+package feedhealth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/data-ingestion/internal/config"
+	"aegisshield/services/data-ingestion/internal/database"
+)
+
+// Feed statuses reported by the dashboard
+const (
+	StatusHealthy   = "healthy"
+	StatusLate      = "late"
+	StatusMissing   = "missing"
+	StatusAnomalous = "anomalous"
+	StatusDisabled  = "disabled"
+)
+
+// maxDashboardIssues bounds the open issues listed per feed
+const maxDashboardIssues = 50
+
+// CheckResult summarises one detection run
+type CheckResult struct {
+	SourcesChecked int                         `json:"sources_checked"`
+	WindowsChecked int                         `json:"windows_checked"`
+	Issues         []*database.FeedHealthIssue `json:"issues"`
+	CheckedAt      time.Time                   `json:"checked_at"`
+}
+
+// SourceHealth is a feed's entry on the feed health dashboard
+type SourceHealth struct {
+	Source        *database.FeedSource        `json:"source"`
+	Status        string                      `json:"status"`
+	LastDelivery  *database.FeedDelivery      `json:"last_delivery,omitempty"`
+	CurrentWindow *Occurrence                 `json:"current_window,omitempty"`
+	NextWindow    *Occurrence                 `json:"next_window,omitempty"`
+	OpenIssues    []*database.FeedHealthIssue `json:"open_issues"`
+	DailyVolumes  []database.DailyVolume      `json:"daily_volumes"`
+}
+
+// feedHealthStore is the part of the feed health repository the monitor uses
+type feedHealthStore interface {
+	GetSource(ctx context.Context, id string) (*database.FeedSource, error)
+	ListSources(ctx context.Context, enabledOnly bool) ([]*database.FeedSource, error)
+	RecordDelivery(ctx context.Context, delivery *database.FeedDelivery) error
+	SumDeliveries(ctx context.Context, sourceID string, from, to time.Time) (int, int64, error)
+	LastDelivery(ctx context.Context, sourceID string) (*database.FeedDelivery, error)
+	DailyVolumes(ctx context.Context, sourceID string, from time.Time, timezone string) ([]database.DailyVolume, error)
+	CreateIssue(ctx context.Context, issue *database.FeedHealthIssue) (bool, error)
+	SetIssueAlert(ctx context.Context, id, alertID string) error
+	ListIssues(ctx context.Context, sourceID, status string, limit int) ([]*database.FeedHealthIssue, error)
+	ResolveMissingFeedIssues(ctx context.Context, sourceID string, receivedAt time.Time, resolvedBy string) (int64, error)
+}
+
+// Monitor checks each feed's closed arrival windows for missing deliveries
+// and unusual record counts, and raises alerts for new issues
+type Monitor struct {
+	repo   feedHealthStore
+	alerts AlertSender
+	cfg    config.FeedHealthConfig
+	logger *logrus.Logger
+}
+
+// NewMonitor creates a feed health monitor. A nil alert sender only records issues.
+func NewMonitor(repo *database.FeedHealthRepository, alerts AlertSender, cfg config.FeedHealthConfig, logger *logrus.Logger) *Monitor {
+	return &Monitor{
+		repo:   repo,
+		alerts: alerts,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Start runs a check every CheckInterval until the context is cancelled
+func (m *Monitor) Start(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.Check(ctx); err != nil {
+				m.logger.WithError(err).Error("Feed health check failed")
+			}
+		}
+	}
+}
+
+// Check evaluates every arrival window whose grace period ended within the
+// lookback. Issues are unique per window, so overlapping runs and restarts
+// do not raise duplicate alerts.
+func (m *Monitor) Check(ctx context.Context) (*CheckResult, error) {
+	now := time.Now()
+	result := &CheckResult{CheckedAt: now}
+
+	sources, err := m.repo.ListSources(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feed sources: %w", err)
+	}
+
+	for _, source := range sources {
+		windows, issues, err := m.checkSource(ctx, source, now)
+		if err != nil {
+			m.logger.WithError(err).WithField("source_id", source.ID).Error("Failed to check feed health")
+			continue
+		}
+		result.SourcesChecked++
+		result.WindowsChecked += windows
+		result.Issues = append(result.Issues, issues...)
+	}
+
+	if len(result.Issues) > 0 {
+		m.logger.WithField("issues", len(result.Issues)).Warn("Feed health issues detected")
+	}
+	return result, nil
+}
+
+func (m *Monitor) checkSource(ctx context.Context, source *database.FeedSource, now time.Time) (int, []*database.FeedHealthIssue, error) {
+	loc, err := time.LoadLocation(source.Timezone)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid timezone %q: %w", source.Timezone, err)
+	}
+	grace := time.Duration(source.GracePeriodMinutes) * time.Minute
+
+	occurrences := Occurrences(source.ArrivalWindows, loc, now.Add(-m.cfg.Lookback-grace), now.Add(-grace))
+
+	var issues []*database.FeedHealthIssue
+	for _, occurrence := range occurrences {
+		issue, err := m.checkWindow(ctx, source, occurrence, grace, now)
+		if err != nil {
+			return len(occurrences), issues, err
+		}
+		if issue == nil {
+			continue
+		}
+
+		created, err := m.repo.CreateIssue(ctx, issue)
+		if err != nil {
+			return len(occurrences), issues, fmt.Errorf("failed to record feed health issue: %w", err)
+		}
+		if !created {
+			continue
+		}
+
+		m.raiseAlert(ctx, source, issue)
+		issues = append(issues, issue)
+	}
+
+	return len(occurrences), issues, nil
+}
+
+// checkWindow returns the issue found in one closed arrival window, if any
+func (m *Monitor) checkWindow(ctx context.Context, source *database.FeedSource, occurrence Occurrence, grace time.Duration, now time.Time) (*database.FeedHealthIssue, error) {
+	files, records, err := m.repo.SumDeliveries(ctx, source.ID, occurrence.Start, occurrence.End.Add(grace))
+	if err != nil {
+		return nil, err
+	}
+
+	issue := &database.FeedHealthIssue{
+		SourceID:    source.ID,
+		WindowStart: occurrence.Start,
+		WindowEnd:   occurrence.End,
+		DetectedAt:  now,
+	}
+
+	if files == 0 {
+		issue.IssueType = IssueMissingFeed
+		issue.Severity = "critical"
+		issue.Message = fmt.Sprintf("no delivery from feed %s between %s and %s",
+			source.Name, occurrence.Start.Format(time.RFC3339), occurrence.End.Add(grace).Format(time.RFC3339))
+		return issue, nil
+	}
+
+	// The same window in previous weeks is the baseline. Weeks without any
+	// delivery are left out; they were already reported as missing.
+	var history []int64
+	for week := 1; week <= source.BaselineWeeks; week++ {
+		offset := -7 * week
+		pastFiles, pastRecords, err := m.repo.SumDeliveries(ctx, source.ID,
+			occurrence.Start.AddDate(0, 0, offset), occurrence.End.Add(grace).AddDate(0, 0, offset))
+		if err != nil {
+			return nil, err
+		}
+		if pastFiles > 0 {
+			history = append(history, pastRecords)
+		}
+	}
+
+	finding := EvaluateVolume(records, history, source.MinRecords, source.DeviationThreshold)
+	if finding == nil {
+		return nil, nil
+	}
+
+	issue.IssueType = finding.IssueType
+	issue.Severity = finding.Severity
+	issue.ExpectedRecords = &finding.Expected
+	issue.ActualRecords = &finding.Actual
+	issue.Message = finding.Message
+	return issue, nil
+}
+
+func (m *Monitor) raiseAlert(ctx context.Context, source *database.FeedSource, issue *database.FeedHealthIssue) {
+	if m.alerts == nil {
+		return
+	}
+
+	logger := m.logger.WithFields(logrus.Fields{
+		"source_id":  source.ID,
+		"issue_id":   issue.ID,
+		"issue_type": issue.IssueType,
+	})
+
+	alertID, err := m.alerts.SendAlert(ctx, source, issue)
+	if err != nil {
+		// The issue stays visible on the dashboard even if the alert failed
+		logger.WithError(err).Error("Failed to raise feed health alert")
+		return
+	}
+
+	issue.AlertID = &alertID
+	if err := m.repo.SetIssueAlert(ctx, issue.ID, alertID); err != nil {
+		logger.WithError(err).Error("Failed to store feed health alert ID")
+	}
+}
+
+// RecordDelivery stores a delivery for a known feed and closes missing feed
+// issues it arrived late for
+func (m *Monitor) RecordDelivery(ctx context.Context, delivery *database.FeedDelivery) error {
+	if _, err := m.repo.GetSource(ctx, delivery.SourceID); err != nil {
+		return err
+	}
+	if delivery.RecordCount < 0 {
+		return fmt.Errorf("record_count must not be negative")
+	}
+	if delivery.ReceivedAt.IsZero() {
+		delivery.ReceivedAt = time.Now()
+	}
+
+	if err := m.repo.RecordDelivery(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to record delivery: %w", err)
+	}
+
+	resolved, err := m.repo.ResolveMissingFeedIssues(ctx, delivery.SourceID, delivery.ReceivedAt, "late-delivery")
+	if err != nil {
+		return fmt.Errorf("failed to resolve missing feed issues: %w", err)
+	}
+	if resolved > 0 {
+		m.logger.WithFields(logrus.Fields{
+			"source_id": delivery.SourceID,
+			"resolved":  resolved,
+		}).Info("Late feed delivery resolved missing feed issues")
+	}
+
+	return nil
+}
+
+// Dashboard returns the health of every feed
+func (m *Monitor) Dashboard(ctx context.Context) ([]*SourceHealth, error) {
+	sources, err := m.repo.ListSources(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	health := make([]*SourceHealth, 0, len(sources))
+	for _, source := range sources {
+		sourceHealth, err := m.SourceHealth(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		health = append(health, sourceHealth)
+	}
+
+	return health, nil
+}
+
+// SourceHealth returns one feed's status, open issues and recent daily volumes
+func (m *Monitor) SourceHealth(ctx context.Context, source *database.FeedSource) (*SourceHealth, error) {
+	now := time.Now()
+	loc, err := time.LoadLocation(source.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	health := &SourceHealth{Source: source}
+
+	if health.LastDelivery, err = m.repo.LastDelivery(ctx, source.ID); err != nil {
+		return nil, err
+	}
+	if health.OpenIssues, err = m.repo.ListIssues(ctx, source.ID, "open", maxDashboardIssues); err != nil {
+		return nil, err
+	}
+	if health.DailyVolumes, err = m.repo.DailyVolumes(ctx, source.ID, now.AddDate(0, 0, -m.cfg.DashboardDays), loc.String()); err != nil {
+		return nil, err
+	}
+
+	grace := time.Duration(source.GracePeriodMinutes) * time.Minute
+	health.NextWindow = NextOccurrence(source.ArrivalWindows, loc, now)
+
+	// A window that has closed but is still within its grace period is
+	// reported as late until a delivery arrives or the monitor flags it
+	late := false
+	for _, occurrence := range Occurrences(source.ArrivalWindows, loc, now.Add(-grace), now.Add(24*time.Hour)) {
+		if occurrence.Start.After(now) {
+			continue
+		}
+		if occurrence.End.After(now) {
+			current := occurrence
+			health.CurrentWindow = &current
+			continue
+		}
+
+		files, _, err := m.repo.SumDeliveries(ctx, source.ID, occurrence.Start, now)
+		if err != nil {
+			return nil, err
+		}
+		if files == 0 {
+			late = true
+		}
+	}
+
+	health.Status = status(source, health.OpenIssues, late)
+	return health, nil
+}
+
+func status(source *database.FeedSource, openIssues []*database.FeedHealthIssue, late bool) string {
+	if !source.Enabled {
+		return StatusDisabled
+	}
+
+	anomalous := false
+	for _, issue := range openIssues {
+		if issue.IssueType == IssueMissingFeed {
+			return StatusMissing
+		}
+		anomalous = true
+	}
+
+	switch {
+	case anomalous:
+		return StatusAnomalous
+	case late:
+		return StatusLate
+	default:
+		return StatusHealthy
+	}
+}
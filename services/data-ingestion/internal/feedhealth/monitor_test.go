@@ -0,0 +1,266 @@
+package feedhealth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/data-ingestion/internal/config"
+	"aegisshield/services/data-ingestion/internal/database"
+)
+
+// memoryStore keeps deliveries and issues in memory. Issues are unique per
+// source, type and window start, like the feed_health_issues table.
+type memoryStore struct {
+	sources    []*database.FeedSource
+	deliveries []database.FeedDelivery
+	issues     []*database.FeedHealthIssue
+}
+
+func (s *memoryStore) GetSource(ctx context.Context, id string) (*database.FeedSource, error) {
+	for _, source := range s.sources {
+		if source.ID == id {
+			return source, nil
+		}
+	}
+	return nil, fmt.Errorf("feed source %s not found", id)
+}
+
+func (s *memoryStore) ListSources(ctx context.Context, enabledOnly bool) ([]*database.FeedSource, error) {
+	return s.sources, nil
+}
+
+func (s *memoryStore) RecordDelivery(ctx context.Context, delivery *database.FeedDelivery) error {
+	s.deliveries = append(s.deliveries, *delivery)
+	return nil
+}
+
+func (s *memoryStore) SumDeliveries(ctx context.Context, sourceID string, from, to time.Time) (int, int64, error) {
+	var files int
+	var records int64
+	for _, delivery := range s.deliveries {
+		if delivery.SourceID == sourceID && !delivery.ReceivedAt.Before(from) && delivery.ReceivedAt.Before(to) {
+			files++
+			records += delivery.RecordCount
+		}
+	}
+	return files, records, nil
+}
+
+func (s *memoryStore) LastDelivery(ctx context.Context, sourceID string) (*database.FeedDelivery, error) {
+	return nil, nil
+}
+
+func (s *memoryStore) DailyVolumes(ctx context.Context, sourceID string, from time.Time, timezone string) ([]database.DailyVolume, error) {
+	return nil, nil
+}
+
+func (s *memoryStore) CreateIssue(ctx context.Context, issue *database.FeedHealthIssue) (bool, error) {
+	for _, existing := range s.issues {
+		if existing.SourceID == issue.SourceID && existing.IssueType == issue.IssueType && existing.WindowStart.Equal(issue.WindowStart) {
+			return false, nil
+		}
+	}
+	issue.ID = fmt.Sprintf("issue-%d", len(s.issues)+1)
+	issue.Status = "open"
+	s.issues = append(s.issues, issue)
+	return true, nil
+}
+
+func (s *memoryStore) SetIssueAlert(ctx context.Context, id, alertID string) error {
+	return nil
+}
+
+func (s *memoryStore) ListIssues(ctx context.Context, sourceID, status string, limit int) ([]*database.FeedHealthIssue, error) {
+	return s.issues, nil
+}
+
+func (s *memoryStore) ResolveMissingFeedIssues(ctx context.Context, sourceID string, receivedAt time.Time, resolvedBy string) (int64, error) {
+	return 0, nil
+}
+
+// recordingAlerts records the issues alerts were raised for
+type recordingAlerts struct {
+	sent []*database.FeedHealthIssue
+}
+
+func (a *recordingAlerts) SendAlert(ctx context.Context, source *database.FeedSource, issue *database.FeedHealthIssue) (string, error) {
+	a.sent = append(a.sent, issue)
+	return fmt.Sprintf("alert-%d", len(a.sent)), nil
+}
+
+// nightlyFeed delivers between 23:00 and 23:40 UTC with a 30 minute grace
+// period, so its windows are checked after midnight
+func nightlyFeed() *database.FeedSource {
+	return &database.FeedSource{
+		ID:                 "swift-mt940",
+		Name:               "SWIFT MT940",
+		Timezone:           "UTC",
+		ArrivalWindows:     []database.ArrivalWindow{{Start: "23:00", End: "23:40"}},
+		GracePeriodMinutes: 30,
+		DeviationThreshold: 3,
+		BaselineWeeks:      4,
+		Enabled:            true,
+	}
+}
+
+func testMonitor(store *memoryStore) (*Monitor, *recordingAlerts) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	alerts := &recordingAlerts{}
+	monitor := &Monitor{
+		repo:   store,
+		alerts: alerts,
+		cfg:    config.FeedHealthConfig{Lookback: 6 * time.Hour},
+		logger: logger,
+	}
+	return monitor, alerts
+}
+
+func delivery(receivedAt time.Time, records int64) database.FeedDelivery {
+	return database.FeedDelivery{SourceID: "swift-mt940", RecordCount: records, ReceivedAt: receivedAt}
+}
+
+func TestMonitorLatenessAtDayBoundary(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		deliveries []database.FeedDelivery
+		now        time.Time
+		want       string // issue type raised, empty for none
+	}{
+		{"the grace period running past midnight is waited for", nil, monday(24, 5), ""},
+		{"no delivery once the grace period has passed", nil, monday(24, 15), IssueMissingFeed},
+		{"a delivery inside the window", []database.FeedDelivery{delivery(monday(23, 20), 1000)}, monday(24, 15), ""},
+		{"a late delivery after midnight within the grace period", []database.FeedDelivery{delivery(monday(24, 5), 1000)}, monday(24, 15), ""},
+		{"a delivery after the grace period", []database.FeedDelivery{delivery(monday(24, 12), 1000)}, monday(24, 15), IssueMissingFeed},
+		{"the previous day's delivery does not count", []database.FeedDelivery{delivery(monday(22, 55), 1000)}, monday(24, 15), IssueMissingFeed},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor, _ := testMonitor(&memoryStore{deliveries: tt.deliveries})
+
+			_, issues, err := monitor.checkSource(context.Background(), nightlyFeed(), tt.now)
+			if err != nil {
+				t.Fatalf("checkSource: %v", err)
+			}
+			if tt.want == "" {
+				if len(issues) != 0 {
+					t.Fatalf("expected no issues, got %+v", issues[0])
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].IssueType != tt.want {
+				t.Fatalf("expected one %s issue, got %d", tt.want, len(issues))
+			}
+			if !issues[0].WindowStart.Equal(monday(23, 0)) {
+				t.Fatalf("issue is for the window starting %v, want Monday's", issues[0].WindowStart)
+			}
+		})
+	}
+}
+
+func TestMonitorVolumeSeasonality(t *testing.T) {
+	// Mondays carry ten times the records of other days; only the same
+	// window in previous weeks makes the baseline
+	var deliveries []database.FeedDelivery
+	for week := 1; week <= 4; week++ {
+		deliveries = append(deliveries,
+			delivery(monday(23, 10).AddDate(0, 0, -7*week), 10000),
+			delivery(monday(23, 10).AddDate(0, 0, -7*week+1), 1000),
+		)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		records int64
+		want    string
+	}{
+		{"a usual Monday", 10000, ""},
+		{"a Monday with a weekday's volume", 1000, IssueVolumeDrop},
+		{"a Monday far above the usual", 20000, IssueVolumeSpike},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryStore{deliveries: append(deliveries[:len(deliveries):len(deliveries)], delivery(monday(23, 10), tt.records))}
+			monitor, _ := testMonitor(store)
+
+			_, issues, err := monitor.checkSource(context.Background(), nightlyFeed(), monday(24, 15))
+			if err != nil {
+				t.Fatalf("checkSource: %v", err)
+			}
+			if tt.want == "" {
+				if len(issues) != 0 {
+					t.Fatalf("expected no issues, got %+v", issues[0])
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].IssueType != tt.want {
+				t.Fatalf("expected one %s issue, got %d", tt.want, len(issues))
+			}
+			if *issues[0].ExpectedRecords != 10000 {
+				t.Fatalf("got expected records %v, want the Monday baseline", *issues[0].ExpectedRecords)
+			}
+		})
+	}
+}
+
+func TestMonitorAlertDeduplication(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		runs   []time.Time
+		alerts int
+	}{
+		{"a single run", []time.Time{monday(24, 15)}, 1},
+		{"a repeated run", []time.Time{monday(24, 15), monday(24, 15)}, 1},
+		{"overlapping runs while the window is in the lookback", []time.Time{monday(24, 15), monday(24, 30), monday(25, 45)}, 1},
+		{"the next day's window", []time.Time{monday(24, 15), monday(48, 15)}, 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryStore{}
+			monitor, alerts := testMonitor(store)
+
+			for _, now := range tt.runs {
+				if _, _, err := monitor.checkSource(context.Background(), nightlyFeed(), now); err != nil {
+					t.Fatalf("checkSource: %v", err)
+				}
+			}
+			if len(alerts.sent) != tt.alerts || len(store.issues) != tt.alerts {
+				t.Fatalf("got %d alerts for %d issues, want %d", len(alerts.sent), len(store.issues), tt.alerts)
+			}
+			for _, issue := range store.issues {
+				if issue.AlertID == nil {
+					t.Fatalf("issue %s has no alert", issue.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	missing := &database.FeedHealthIssue{IssueType: IssueMissingFeed}
+	drop := &database.FeedHealthIssue{IssueType: IssueVolumeDrop}
+
+	for _, tt := range []struct {
+		name     string
+		disabled bool
+		issues   []*database.FeedHealthIssue
+		late     bool
+		want     string
+	}{
+		{"disabled feeds are not judged", true, []*database.FeedHealthIssue{missing}, true, StatusDisabled},
+		{"a missing delivery outranks anomalies", false, []*database.FeedHealthIssue{drop, missing}, false, StatusMissing},
+		{"open volume issues", false, []*database.FeedHealthIssue{drop}, true, StatusAnomalous},
+		{"a window in its grace period", false, nil, true, StatusLate},
+		{"nothing wrong", false, nil, false, StatusHealthy},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &database.FeedSource{Enabled: !tt.disabled}
+			if got := status(source, tt.issues, tt.late); got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
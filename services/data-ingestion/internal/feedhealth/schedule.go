@@ -0,0 +1,136 @@
+package feedhealth
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"aegisshield/services/data-ingestion/internal/database"
+)
+
+// Occurrence is one arrival window on a concrete day
+type Occurrence struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// ValidateSource checks a feed source's settings before it is stored
+func ValidateSource(source *database.FeedSource) error {
+	if source.ID == "" || source.Name == "" {
+		return fmt.Errorf("id and name are required")
+	}
+	if _, err := time.LoadLocation(source.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", source.Timezone, err)
+	}
+	if len(source.ArrivalWindows) == 0 {
+		return fmt.Errorf("at least one arrival window is required")
+	}
+
+	for i, window := range source.ArrivalWindows {
+		start, err := parseClock(window.Start)
+		if err != nil {
+			return fmt.Errorf("arrival window %d: %w", i, err)
+		}
+		end, err := parseClock(window.End)
+		if err != nil {
+			return fmt.Errorf("arrival window %d: %w", i, err)
+		}
+		if start == end {
+			return fmt.Errorf("arrival window %d: start and end must differ", i)
+		}
+		for _, day := range window.Days {
+			if day < 0 || day > 6 {
+				return fmt.Errorf("arrival window %d: days must be between 0 (Sunday) and 6 (Saturday)", i)
+			}
+		}
+	}
+
+	if source.GracePeriodMinutes < 0 || source.MinRecords < 0 {
+		return fmt.Errorf("grace_period_minutes and min_records must not be negative")
+	}
+	if source.DeviationThreshold <= 0 || source.BaselineWeeks <= 0 {
+		return fmt.Errorf("deviation_threshold and baseline_weeks must be positive")
+	}
+
+	return nil
+}
+
+// Occurrences returns the arrival windows of a source that end in (from, to],
+// ordered by start. Windows are laid out in the source's timezone, so they
+// follow local daylight saving changes.
+func Occurrences(windows []database.ArrivalWindow, loc *time.Location, from, to time.Time) []Occurrence {
+	var occurrences []Occurrence
+
+	// Start a day early so windows spanning midnight into the range are found
+	fromLocal := from.In(loc)
+	day := time.Date(fromLocal.Year(), fromLocal.Month(), fromLocal.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, -1)
+	for !day.After(to) {
+		for _, window := range windows {
+			if !runsOn(window, day.Weekday()) {
+				continue
+			}
+
+			startMinutes, err := parseClock(window.Start)
+			if err != nil {
+				continue
+			}
+			endMinutes, err := parseClock(window.End)
+			if err != nil {
+				continue
+			}
+
+			start := atClock(day, startMinutes)
+			end := atClock(day, endMinutes)
+			if !end.After(start) {
+				end = atClock(day.AddDate(0, 0, 1), endMinutes)
+			}
+
+			if end.After(from) && !end.After(to) {
+				occurrences = append(occurrences, Occurrence{Start: start, End: end})
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].Start.Before(occurrences[j].Start)
+	})
+	return occurrences
+}
+
+// NextOccurrence returns the first arrival window starting after t, looking
+// a week ahead
+func NextOccurrence(windows []database.ArrivalWindow, loc *time.Location, t time.Time) *Occurrence {
+	for _, occurrence := range Occurrences(windows, loc, t, t.AddDate(0, 0, 8)) {
+		if occurrence.Start.After(t) {
+			next := occurrence
+			return &next
+		}
+	}
+	return nil
+}
+
+func runsOn(window database.ArrivalWindow, weekday time.Weekday) bool {
+	if len(window.Days) == 0 {
+		return true
+	}
+	for _, day := range window.Days {
+		if time.Weekday(day) == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock parses "HH:MM" into minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func atClock(day time.Time, minutes int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, day.Location())
+}
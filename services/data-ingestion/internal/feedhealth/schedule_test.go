@@ -0,0 +1,109 @@
+package feedhealth
+
+import (
+	"testing"
+	"time"
+
+	"aegisshield/services/data-ingestion/internal/database"
+)
+
+// monday returns a time on Monday 2026-01-05 in UTC; hours past 23 run into
+// the following days
+func monday(hour, minute int) time.Time {
+	return time.Date(2026, 1, 5, hour, minute, 0, 0, time.UTC)
+}
+
+func TestOccurrences(t *testing.T) {
+	overnight := []database.ArrivalWindow{{Start: "23:30", End: "00:30"}}
+	mondayNights := []database.ArrivalWindow{{Days: []int{1}, Start: "23:30", End: "00:30"}}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		windows []database.ArrivalWindow
+		loc     *time.Location
+		from    time.Time
+		to      time.Time
+		want    []Occurrence
+	}{
+		{
+			name:    "a window spanning midnight ends the next day",
+			windows: overnight,
+			loc:     time.UTC,
+			from:    monday(23, 0),
+			to:      monday(25, 0),
+			want:    []Occurrence{{Start: monday(23, 30), End: monday(24, 30)}},
+		},
+		{
+			name:    "a window spanning into the range from the previous day is found",
+			windows: overnight,
+			loc:     time.UTC,
+			from:    monday(24, 0),
+			to:      monday(24, 30),
+			want:    []Occurrence{{Start: monday(23, 30), End: monday(24, 30)}},
+		},
+		{
+			name:    "a window ending at the start of the range is excluded",
+			windows: overnight,
+			loc:     time.UTC,
+			from:    monday(24, 30),
+			to:      monday(26, 0),
+		},
+		{
+			name:    "a window is matched by the day it starts on",
+			windows: mondayNights,
+			loc:     time.UTC,
+			from:    monday(0, 0),
+			to:      monday(24+24+1, 0),
+			want:    []Occurrence{{Start: monday(23, 30), End: monday(24, 30)}},
+		},
+		{
+			name:    "windows are laid out in the source timezone",
+			windows: []database.ArrivalWindow{{Start: "22:00", End: "23:00"}},
+			loc:     newYork,
+			from:    monday(24, 0),
+			to:      monday(24+6, 0),
+			want: []Occurrence{{
+				Start: time.Date(2026, 1, 5, 22, 0, 0, 0, newYork),
+				End:   time.Date(2026, 1, 5, 23, 0, 0, 0, newYork),
+			}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Occurrences(tt.windows, tt.loc, tt.from, tt.to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) {
+					t.Fatalf("occurrence %d: got %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	windows := []database.ArrivalWindow{{Days: []int{1, 2}, Start: "23:30", End: "00:30"}}
+
+	for _, tt := range []struct {
+		name string
+		at   time.Time
+		want time.Time
+	}{
+		{"before the window", monday(12, 0), monday(23, 30)},
+		{"inside the window", monday(23, 45), monday(24+23, 30)},
+		{"after the window has closed past midnight", monday(24, 45), monday(24+23, 30)},
+		{"after the last window of the week", monday(24+23, 45), monday(7*24+23, 30)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			next := NextOccurrence(windows, time.UTC, tt.at)
+			if next == nil || !next.Start.Equal(tt.want) {
+				t.Fatalf("got %v, want a window starting %v", next, tt.want)
+			}
+		})
+	}
+}
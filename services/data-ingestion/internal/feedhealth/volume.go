@@ -0,0 +1,109 @@
+package feedhealth
+
+import (
+	"fmt"
+	"math"
+)
+
+// Issue types recorded in feed_health_issues
+const (
+	IssueMissingFeed = "missing_feed"
+	IssueVolumeDrop  = "volume_drop"
+	IssueVolumeSpike = "volume_spike"
+)
+
+// minBaselineSamples is how many past windows are needed before deviations
+// from the baseline are reported
+const minBaselineSamples = 3
+
+// VolumeFinding is a window whose record count looks wrong
+type VolumeFinding struct {
+	IssueType string
+	Severity  string
+	Expected  float64
+	Actual    int64
+	Message   string
+}
+
+// Baseline returns the mean and standard deviation of past window volumes
+func Baseline(history []int64) (float64, float64) {
+	if len(history) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, v := range history {
+		sum += float64(v)
+	}
+	mean := sum / float64(len(history))
+
+	var variance float64
+	for _, v := range history {
+		variance += (float64(v) - mean) * (float64(v) - mean)
+	}
+
+	return mean, math.Sqrt(variance / float64(len(history)))
+}
+
+// EvaluateVolume compares a window's record count with the same window in
+// previous weeks, so weekday seasonality is part of the baseline. An empty
+// delivery is always a critical drop once the feed is known to carry data.
+func EvaluateVolume(actual int64, history []int64, minRecords int64, threshold float64) *VolumeFinding {
+	mean, std := Baseline(history)
+	hasBaseline := len(history) >= minBaselineSamples
+
+	expected := mean
+	if !hasBaseline {
+		expected = float64(minRecords)
+	}
+
+	if actual == 0 && (minRecords > 0 || mean > 0) {
+		return &VolumeFinding{
+			IssueType: IssueVolumeDrop,
+			Severity:  "critical",
+			Expected:  expected,
+			Actual:    actual,
+			Message:   fmt.Sprintf("feed delivered no records, expected about %.0f", expected),
+		}
+	}
+
+	if actual < minRecords {
+		return &VolumeFinding{
+			IssueType: IssueVolumeDrop,
+			Severity:  "high",
+			Expected:  expected,
+			Actual:    actual,
+			Message:   fmt.Sprintf("feed delivered %d records, below the minimum of %d", actual, minRecords),
+		}
+	}
+
+	if !hasBaseline {
+		return nil
+	}
+
+	// A perfectly regular feed has no variance, so allow at least 10% of the
+	// mean before calling a window anomalous
+	spread := math.Max(std, math.Max(0.1*mean, 1))
+	deviation := (float64(actual) - mean) / spread
+
+	switch {
+	case deviation <= -threshold:
+		return &VolumeFinding{
+			IssueType: IssueVolumeDrop,
+			Severity:  "high",
+			Expected:  mean,
+			Actual:    actual,
+			Message:   fmt.Sprintf("feed delivered %d records, %.1f standard deviations below the usual %.0f", actual, -deviation, mean),
+		}
+	case deviation >= threshold:
+		return &VolumeFinding{
+			IssueType: IssueVolumeSpike,
+			Severity:  "medium",
+			Expected:  mean,
+			Actual:    actual,
+			Message:   fmt.Sprintf("feed delivered %d records, %.1f standard deviations above the usual %.0f", actual, deviation, mean),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package feedhealth
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBaseline(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		history []int64
+		mean    float64
+		stddev  float64
+	}{
+		{"empty history", nil, 0, 0},
+		{"single window", []int64{500}, 500, 0},
+		{"regular feed", []int64{1000, 1000, 1000}, 1000, 0},
+		{"varying feed", []int64{900, 1000, 1100}, 1000, math.Sqrt(20000.0 / 3)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mean, stddev := Baseline(tt.history)
+			if mean != tt.mean || math.Abs(stddev-tt.stddev) > 1e-9 {
+				t.Fatalf("got (%v, %v), want (%v, %v)", mean, stddev, tt.mean, tt.stddev)
+			}
+		})
+	}
+}
+
+func TestEvaluateVolume(t *testing.T) {
+	mondays := []int64{1000, 1000, 1000}
+
+	for _, tt := range []struct {
+		name       string
+		actual     int64
+		history    []int64
+		minRecords int64
+		issueType  string // empty when no finding is expected
+		severity   string
+		expected   float64
+	}{
+		// Without enough history only the minimum applies
+		{"new feed without a minimum", 0, nil, 0, "", "", 0},
+		{"new feed delivering nothing", 0, nil, 100, IssueVolumeDrop, "critical", 100},
+		{"new feed below the minimum", 50, nil, 100, IssueVolumeDrop, "high", 100},
+		{"new feed far above its minimum", 5000, nil, 100, "", "", 0},
+		{"too little history for a baseline", 5000, []int64{1000, 1000}, 0, "", "", 0},
+		{"empty delivery with too little history", 0, []int64{1000}, 0, IssueVolumeDrop, "critical", 0},
+
+		// The same window in previous weeks is the baseline
+		{"usual volume", 1000, mondays, 0, "", "", 0},
+		{"within the 10% floor of a regular feed", 750, mondays, 0, "", "", 0},
+		{"drop on a regular feed", 600, mondays, 0, IssueVolumeDrop, "high", 1000},
+		{"spike on a regular feed", 1400, mondays, 0, IssueVolumeSpike, "medium", 1000},
+		{"empty delivery against the baseline", 0, mondays, 0, IssueVolumeDrop, "critical", 1000},
+		{"below the minimum despite the baseline", 950, mondays, 960, IssueVolumeDrop, "high", 1000},
+		{"within the spread of a varying feed", 1300, []int64{800, 1000, 1200}, 0, "", "", 0},
+		{"outside the spread of a varying feed", 1600, []int64{800, 1000, 1200}, 0, IssueVolumeSpike, "medium", 1000},
+		{"feed that always delivers empty files", 0, []int64{0, 0, 0}, 0, "", "", 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			finding := EvaluateVolume(tt.actual, tt.history, tt.minRecords, 3)
+			if tt.issueType == "" {
+				if finding != nil {
+					t.Fatalf("expected no finding, got %+v", finding)
+				}
+				return
+			}
+			if finding == nil {
+				t.Fatal("expected a finding")
+			}
+			if finding.IssueType != tt.issueType || finding.Severity != tt.severity {
+				t.Fatalf("got %s/%s, want %s/%s", finding.IssueType, finding.Severity, tt.issueType, tt.severity)
+			}
+			if finding.Expected != tt.expected || finding.Actual != tt.actual {
+				t.Fatalf("got expected %v actual %d, want %v and %d", finding.Expected, finding.Actual, tt.expected, tt.actual)
+			}
+		})
+	}
+}
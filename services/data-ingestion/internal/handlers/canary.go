@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/data-ingestion/internal/canary"
+)
+
+// CanaryHandler serves pipeline canary results and on-demand probes
+type CanaryHandler struct {
+	runner *canary.Runner
+	ctx    context.Context // bounds probes started on demand
+	logger *logrus.Logger
+}
+
+// NewCanaryHandler creates a new canary handler. Probes started through it
+// stop when ctx is cancelled.
+func NewCanaryHandler(ctx context.Context, runner *canary.Runner, logger *logrus.Logger) *CanaryHandler {
+	return &CanaryHandler{
+		runner: runner,
+		ctx:    ctx,
+		logger: logger,
+	}
+}
+
+// RegisterRoutes registers canary routes
+func (h *CanaryHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/canary/runs", h.ListRuns).Methods("GET")
+	router.HandleFunc("/canary/runs", h.StartRun).Methods("POST")
+}
+
+// ListRuns returns the probe in flight and recent probe results
+func (h *CanaryHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"runs": h.runner.Runs(),
+	})
+}
+
+// StartRun injects a probe now. The run takes up to the longest stage
+// latency, so it is followed through ListRuns.
+func (h *CanaryHandler) StartRun(w http.ResponseWriter, r *http.Request) {
+	go h.runner.RunOnce(h.ctx)
+
+	h.writeJSON(w, http.StatusAccepted, map[string]string{
+		"message": "Canary probe started",
+	})
+}
+
+func (h *CanaryHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode JSON response")
+	}
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/data-ingestion/internal/database"
+	"aegisshield/services/data-ingestion/internal/feedhealth"
+)
+
+const (
+	defaultIssueLimit = 100
+	maxIssueLimit     = 1000
+)
+
+// FeedHealthHandler serves feed source configuration, delivery recording
+// and the feed health dashboard
+type FeedHealthHandler struct {
+	repo    *database.FeedHealthRepository
+	monitor *feedhealth.Monitor
+	logger  *logrus.Logger
+}
+
+// NewFeedHealthHandler creates a new feed health handler
+func NewFeedHealthHandler(repo *database.FeedHealthRepository, monitor *feedhealth.Monitor, logger *logrus.Logger) *FeedHealthHandler {
+	return &FeedHealthHandler{
+		repo:    repo,
+		monitor: monitor,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers feed health routes
+func (h *FeedHealthHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/feeds", h.ListSources).Methods("GET")
+	router.HandleFunc("/feeds", h.UpsertSource).Methods("POST")
+	router.HandleFunc("/feeds/health", h.Dashboard).Methods("GET")
+	router.HandleFunc("/feeds/check", h.RunCheck).Methods("POST")
+	router.HandleFunc("/feeds/issues", h.ListIssues).Methods("GET")
+	router.HandleFunc("/feeds/issues/{id}/resolve", h.ResolveIssue).Methods("POST")
+	router.HandleFunc("/feeds/{id}", h.GetSource).Methods("GET")
+	router.HandleFunc("/feeds/{id}/health", h.GetSourceHealth).Methods("GET")
+	router.HandleFunc("/feeds/{id}/deliveries", h.RecordDelivery).Methods("POST")
+}
+
+// ListSources lists configured feed sources
+func (h *FeedHealthHandler) ListSources(w http.ResponseWriter, r *http.Request) {
+	sources, err := h.repo.ListSources(r.Context(), false)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list feed sources")
+		h.writeError(w, http.StatusInternalServerError, "Failed to list feed sources")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sources": sources,
+		"count":   len(sources),
+	})
+}
+
+// UpsertSource creates a feed source or replaces its settings
+func (h *FeedHealthHandler) UpsertSource(w http.ResponseWriter, r *http.Request) {
+	source := database.FeedSource{
+		Timezone:           "UTC",
+		GracePeriodMinutes: 30,
+		DeviationThreshold: 3,
+		BaselineWeeks:      8,
+		Enabled:            true,
+	}
+	if err := json.NewDecoder(r.Body).Decode(&source); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := feedhealth.ValidateSource(&source); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.repo.UpsertSource(r.Context(), &source); err != nil {
+		h.logger.WithError(err).WithField("source_id", source.ID).Error("Failed to save feed source")
+		h.writeError(w, http.StatusInternalServerError, "Failed to save feed source")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, source)
+}
+
+// GetSource returns a feed source
+func (h *FeedHealthHandler) GetSource(w http.ResponseWriter, r *http.Request) {
+	source, ok := h.loadSource(w, r)
+	if !ok {
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, source)
+}
+
+// Dashboard returns the health of every feed
+func (h *FeedHealthHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
+	feeds, err := h.monitor.Dashboard(r.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to build feed health dashboard")
+		h.writeError(w, http.StatusInternalServerError, "Failed to build feed health dashboard")
+		return
+	}
+
+	summary := make(map[string]int)
+	for _, feed := range feeds {
+		summary[feed.Status]++
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"feeds":        feeds,
+		"summary":      summary,
+		"generated_at": time.Now(),
+	})
+}
+
+// GetSourceHealth returns one feed's dashboard entry
+func (h *FeedHealthHandler) GetSourceHealth(w http.ResponseWriter, r *http.Request) {
+	source, ok := h.loadSource(w, r)
+	if !ok {
+		return
+	}
+
+	health, err := h.monitor.SourceHealth(r.Context(), source)
+	if err != nil {
+		h.logger.WithError(err).WithField("source_id", source.ID).Error("Failed to get feed health")
+		h.writeError(w, http.StatusInternalServerError, "Failed to get feed health")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, health)
+}
+
+// RunCheck runs missing feed and volume anomaly detection now
+func (h *FeedHealthHandler) RunCheck(w http.ResponseWriter, r *http.Request) {
+	result, err := h.monitor.Check(r.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Feed health check failed")
+		h.writeError(w, http.StatusInternalServerError, "Feed health check failed")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// RecordDelivery records a file or batch received from a feed
+func (h *FeedHealthHandler) RecordDelivery(w http.ResponseWriter, r *http.Request) {
+	var delivery database.FeedDelivery
+	if err := json.NewDecoder(r.Body).Decode(&delivery); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	delivery.SourceID = mux.Vars(r)["id"]
+
+	if err := h.monitor.RecordDelivery(r.Context(), &delivery); err != nil {
+		if errors.Is(err, database.ErrFeedSourceNotFound) {
+			h.writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.WithError(err).WithField("source_id", delivery.SourceID).Error("Failed to record feed delivery")
+		h.writeError(w, http.StatusInternalServerError, "Failed to record feed delivery")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, delivery)
+}
+
+// ListIssues lists feed health issues, optionally by source_id and status
+func (h *FeedHealthHandler) ListIssues(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultIssueLimit
+	}
+	if limit > maxIssueLimit {
+		limit = maxIssueLimit
+	}
+
+	issues, err := h.repo.ListIssues(r.Context(), query.Get("source_id"), query.Get("status"), limit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list feed health issues")
+		h.writeError(w, http.StatusInternalServerError, "Failed to list feed health issues")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issues": issues,
+		"count":  len(issues),
+	})
+}
+
+// ResolveIssue closes an open feed health issue
+func (h *FeedHealthHandler) ResolveIssue(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ResolvedBy string `json:"resolved_by"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ResolvedBy == "" {
+		h.writeError(w, http.StatusBadRequest, "resolved_by is required")
+		return
+	}
+
+	issue, err := h.repo.ResolveIssue(r.Context(), mux.Vars(r)["id"], req.ResolvedBy)
+	if err != nil {
+		if errors.Is(err, database.ErrFeedIssueNotFound) {
+			h.writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.WithError(err).Error("Failed to resolve feed health issue")
+		h.writeError(w, http.StatusInternalServerError, "Failed to resolve feed health issue")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, issue)
+}
+
+func (h *FeedHealthHandler) loadSource(w http.ResponseWriter, r *http.Request) (*database.FeedSource, bool) {
+	source, err := h.repo.GetSource(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, database.ErrFeedSourceNotFound) {
+			h.writeError(w, http.StatusNotFound, err.Error())
+			return nil, false
+		}
+		h.logger.WithError(err).Error("Failed to get feed source")
+		h.writeError(w, http.StatusInternalServerError, "Failed to get feed source")
+		return nil, false
+	}
+	return source, true
+}
+
+func (h *FeedHealthHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode JSON response")
+	}
+}
+
+func (h *FeedHealthHandler) writeError(w http.ResponseWriter, status int, message string) {
+	h.writeJSON(w, status, ErrorResponse{
+		Error:     http.StatusText(status),
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
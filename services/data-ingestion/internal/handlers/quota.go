@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/services/data-ingestion/internal/quota"
+	limits "aegisshield/shared/quota"
+	"aegisshield/shared/rbac"
+)
+
+// QuotaHandler serves upload storage usage, pre-upload quota checks and
+// per-tenant and per-user limits
+type QuotaHandler struct {
+	service *quota.Service
+	logger  *logrus.Logger
+}
+
+// SetQuotaRequest replaces a tenant's or user's limits. Omitted limits fall
+// back to the configured defaults.
+type SetQuotaRequest struct {
+	LimitBytes *int64 `json:"limit_bytes"`
+	LimitFiles *int64 `json:"limit_files"`
+}
+
+// NewQuotaHandler creates a new quota handler
+func NewQuotaHandler(service *quota.Service, logger *logrus.Logger) *QuotaHandler {
+	return &QuotaHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers quota routes
+func (h *QuotaHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/quota/usage", h.GetUsage).Methods("GET")
+	router.HandleFunc("/quota/check", h.CheckUpload).Methods("GET")
+	router.HandleFunc("/quota/tenants", h.ListTenantUsage).Methods("GET")
+	router.HandleFunc("/quota/tenants/{tenant_id}", h.GetTenantUsage).Methods("GET")
+	router.HandleFunc("/quota/tenants/{tenant_id}", h.SetTenantQuota).Methods("PUT")
+	router.HandleFunc("/quota/users", h.ListUserUsage).Methods("GET")
+	router.HandleFunc("/quota/users/{user_id}", h.GetUserUsage).Methods("GET")
+	router.HandleFunc("/quota/users/{user_id}", h.SetUserQuota).Methods("PUT")
+}
+
+// GetUsage reports the storage usage of the caller and their tenant
+func (h *QuotaHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := h.caller(w, r)
+	if !ok {
+		return
+	}
+
+	tenant, err := h.service.Usage(r.Context(), limits.ScopeTenant, tenantID)
+	if err != nil {
+		h.handleError(w, err, "Failed to get storage usage")
+		return
+	}
+	user, err := h.service.Usage(r.Context(), limits.ScopeUser, userID)
+	if err != nil {
+		h.handleError(w, err, "Failed to get storage usage")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"tenant": tenant,
+		"user":   user,
+	})
+}
+
+// CheckUpload reports whether a file of the given size would be accepted,
+// so clients can stop before uploading it
+func (h *QuotaHandler) CheckUpload(w http.ResponseWriter, r *http.Request) {
+	size, err := strconv.ParseInt(r.URL.Query().Get("size"), 10, 64)
+	if err != nil || size < 0 {
+		h.writeError(w, http.StatusBadRequest, "size must be a non-negative number of bytes")
+		return
+	}
+
+	tenantID, userID, ok := h.caller(w, r)
+	if !ok {
+		return
+	}
+
+	decision, err := h.service.Check(r.Context(), tenantID, userID, size)
+	if err != nil {
+		h.handleError(w, err, "Failed to check storage quota")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, decision)
+}
+
+// ListTenantUsage reports the storage usage of every tenant
+func (h *QuotaHandler) ListTenantUsage(w http.ResponseWriter, r *http.Request) {
+	h.listUsage(w, r, limits.ScopeTenant)
+}
+
+// ListUserUsage reports the storage usage of every user
+func (h *QuotaHandler) ListUserUsage(w http.ResponseWriter, r *http.Request) {
+	h.listUsage(w, r, limits.ScopeUser)
+}
+
+// GetTenantUsage reports a tenant's storage usage
+func (h *QuotaHandler) GetTenantUsage(w http.ResponseWriter, r *http.Request) {
+	h.getUsage(w, r, limits.ScopeTenant, mux.Vars(r)["tenant_id"])
+}
+
+// GetUserUsage reports a user's storage usage
+func (h *QuotaHandler) GetUserUsage(w http.ResponseWriter, r *http.Request) {
+	h.getUsage(w, r, limits.ScopeUser, mux.Vars(r)["user_id"])
+}
+
+// SetTenantQuota replaces a tenant's storage limits
+func (h *QuotaHandler) SetTenantQuota(w http.ResponseWriter, r *http.Request) {
+	h.setLimit(w, r, limits.ScopeTenant, mux.Vars(r)["tenant_id"])
+}
+
+// SetUserQuota replaces a user's storage limits
+func (h *QuotaHandler) SetUserQuota(w http.ResponseWriter, r *http.Request) {
+	h.setLimit(w, r, limits.ScopeUser, mux.Vars(r)["user_id"])
+}
+
+func (h *QuotaHandler) listUsage(w http.ResponseWriter, r *http.Request, scope limits.Scope) {
+	reports, err := h.service.ListUsage(r.Context(), scope)
+	if err != nil {
+		h.handleError(w, err, "Failed to list storage usage")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"usage": reports,
+	})
+}
+
+func (h *QuotaHandler) getUsage(w http.ResponseWriter, r *http.Request, scope limits.Scope, subjectID string) {
+	report, err := h.service.Usage(r.Context(), scope, subjectID)
+	if err != nil {
+		h.handleError(w, err, "Failed to get storage usage")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, report)
+}
+
+func (h *QuotaHandler) setLimit(w http.ResponseWriter, r *http.Request, scope limits.Scope, subjectID string) {
+	var req SetQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var updatedBy string
+	if subject, ok := rbac.SubjectFromContext(r.Context()); ok {
+		updatedBy = subject.ID
+	}
+
+	report, err := h.service.SetLimit(r.Context(), scope, subjectID, req.LimitBytes, req.LimitFiles, updatedBy)
+	if err != nil {
+		h.handleError(w, err, "Failed to set storage quota")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, report)
+}
+
+// caller resolves the tenant and user a request is made for. Requests
+// authenticated with an API key are made for its subject; otherwise the
+// user is named in the user query parameter.
+func (h *QuotaHandler) caller(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	tenantID, err := h.service.Tenant(r)
+	if err != nil {
+		h.handleError(w, err, "Invalid tenant")
+		return "", "", false
+	}
+
+	userID := r.URL.Query().Get("user")
+	if subject, ok := rbac.SubjectFromContext(r.Context()); ok {
+		userID = subject.ID
+	}
+	if userID == "" {
+		h.writeError(w, http.StatusBadRequest, "user is required")
+		return "", "", false
+	}
+
+	return tenantID, userID, true
+}
+
+func (h *QuotaHandler) handleError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, quota.ErrInvalidSubject), errors.Is(err, quota.ErrInvalidSize), errors.Is(err, quota.ErrInvalidLimit):
+		h.writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.WithError(err).Error(message)
+		h.writeError(w, http.StatusInternalServerError, message)
+	}
+}
+
+func (h *QuotaHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode JSON response")
+	}
+}
+
+func (h *QuotaHandler) writeError(w http.ResponseWriter, status int, message string) {
+	h.writeJSON(w, status, ErrorResponse{
+		Error:     http.StatusText(status),
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,177 @@
+package kafka
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// ClaimCheckHeader marks messages whose value is a ClaimCheck reference
+// instead of the event itself
+const ClaimCheckHeader = "claim-check"
+
+// ErrPayloadTooLarge is returned for payloads above the broker message limit
+// that could not be claim-checked
+var ErrPayloadTooLarge = errors.New("payload exceeds the maximum Kafka message size")
+
+// ClaimCheck is published in place of an oversized payload. Consumers fetch
+// the payload from object storage and can verify it against the digest.
+type ClaimCheck struct {
+	Location    string `json:"location"`
+	URL         string `json:"url,omitempty"`
+	SizeBytes   int    `json:"size_bytes"`
+	SHA256      string `json:"sha256"`
+	ContentType string `json:"content_type"`
+	Topic       string `json:"topic"`
+	Key         string `json:"key"`
+}
+
+// ClaimCheckEnvelope is the message value published for a claim-checked payload
+type ClaimCheckEnvelope struct {
+	ClaimCheck ClaimCheck `json:"claim_check"`
+}
+
+// ClaimStore keeps oversized payloads; storage.Service satisfies it
+type ClaimStore interface {
+	Store(ctx context.Context, fileID, fileName string, data []byte) (string, error)
+	GetURL(filePath string) (string, error)
+}
+
+// PayloadMetrics records payload size governance metrics per topic
+type PayloadMetrics interface {
+	ObserveKafkaPayloadSize(topic string, bytes int)
+	IncrementKafkaClaimChecks(topic string)
+	IncrementKafkaOversizedPayloads(topic string)
+}
+
+// PayloadGovernor enforces the message size limit. Payloads above the claim
+// check threshold are moved to object storage and replaced by a reference;
+// payloads that still cannot be published are rejected before they reach the
+// broker.
+type PayloadGovernor struct {
+	maxBytes       int
+	claimThreshold int
+	store          ClaimStore
+	metrics        PayloadMetrics
+	logger         *logrus.Logger
+}
+
+// NewPayloadGovernor creates a payload governor. A nil store or a zero
+// threshold disables claim checks; a nil metrics recorder disables metrics.
+func NewPayloadGovernor(maxBytes, claimThreshold int, store ClaimStore, metrics PayloadMetrics, logger *logrus.Logger) *PayloadGovernor {
+	if store == nil {
+		claimThreshold = 0
+	}
+
+	return &PayloadGovernor{
+		maxBytes:       maxBytes,
+		claimThreshold: claimThreshold,
+		store:          store,
+		metrics:        metrics,
+		logger:         logger,
+	}
+}
+
+// Prepare checks a serialized payload and returns the value to publish along
+// with any extra headers
+func (g *PayloadGovernor) Prepare(ctx context.Context, topic, key string, payload []byte) ([]byte, []kafka.Header, error) {
+	size := len(payload)
+	if g.metrics != nil {
+		g.metrics.ObserveKafkaPayloadSize(topic, size)
+	}
+
+	if g.claimThreshold > 0 && size > g.claimThreshold {
+		value, err := g.claimCheck(ctx, topic, key, payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		return value, []kafka.Header{{Key: ClaimCheckHeader, Value: []byte("true")}}, nil
+	}
+
+	if g.maxBytes > 0 && size > g.maxBytes {
+		if g.metrics != nil {
+			g.metrics.IncrementKafkaOversizedPayloads(topic)
+		}
+		return nil, nil, fmt.Errorf("%w: %d bytes for topic %s, limit %d", ErrPayloadTooLarge, size, topic, g.maxBytes)
+	}
+
+	return payload, nil, nil
+}
+
+// claimCheck stores the payload and returns the serialized reference to it
+func (g *PayloadGovernor) claimCheck(ctx context.Context, topic, key string, payload []byte) ([]byte, error) {
+	objectID := fmt.Sprintf("claim-check-%s-%s", sanitizeObjectName(topic), uuid.New().String())
+	location, err := g.store.Store(ctx, objectID, "payload.json", payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store claim-checked payload: %w", err)
+	}
+
+	digest := sha256.Sum256(payload)
+	check := ClaimCheck{
+		Location:    location,
+		SizeBytes:   len(payload),
+		SHA256:      hex.EncodeToString(digest[:]),
+		ContentType: "application/json",
+		Topic:       topic,
+		Key:         key,
+	}
+	if url, err := g.store.GetURL(location); err == nil {
+		check.URL = url
+	}
+
+	value, err := json.Marshal(ClaimCheckEnvelope{ClaimCheck: check})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize claim check: %w", err)
+	}
+
+	if g.metrics != nil {
+		g.metrics.IncrementKafkaClaimChecks(topic)
+	}
+	g.logger.WithFields(logrus.Fields{
+		"topic":      topic,
+		"key":        key,
+		"size_bytes": len(payload),
+		"location":   location,
+	}).Info("Published oversized payload by claim check")
+
+	return value, nil
+}
+
+// sanitizeObjectName keeps topic names safe to use in object keys
+func sanitizeObjectName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			return r
+		default:
+			return '-'
+		}
+	}, name)
+}
+
+// compressionCodec maps a configured codec name to the writer setting.
+// "none" leaves messages uncompressed.
+func compressionCodec(name string) (kafka.Compression, error) {
+	switch name {
+	case "", "none":
+		return 0, nil
+	case "gzip":
+		return kafka.Gzip, nil
+	case "snappy":
+		return kafka.Snappy, nil
+	case "lz4":
+		return kafka.Lz4, nil
+	case "zstd":
+		return kafka.Zstd, nil
+	default:
+		return 0, fmt.Errorf("unsupported compression: %s", name)
+	}
+}
@@ -0,0 +1,173 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// memoryClaimStore keeps claim-checked payloads in memory
+type memoryClaimStore struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (s *memoryClaimStore) Store(ctx context.Context, fileID, fileName string, data []byte) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	location := "claims/" + fileID + "/" + fileName
+	s.objects[location] = data
+	return location, nil
+}
+
+func (s *memoryClaimStore) GetURL(filePath string) (string, error) {
+	return "https://storage.example.com/" + filePath, nil
+}
+
+// countingMetrics counts claim checks and rejected payloads
+type countingMetrics struct {
+	observed, claimChecks, oversized int
+}
+
+func (m *countingMetrics) ObserveKafkaPayloadSize(topic string, bytes int) { m.observed++ }
+func (m *countingMetrics) IncrementKafkaClaimChecks(topic string)          { m.claimChecks++ }
+func (m *countingMetrics) IncrementKafkaOversizedPayloads(topic string)    { m.oversized++ }
+
+func testGovernor(maxBytes, claimThreshold int, store ClaimStore) (*PayloadGovernor, *countingMetrics) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	metrics := &countingMetrics{}
+	return NewPayloadGovernor(maxBytes, claimThreshold, store, metrics, logger), metrics
+}
+
+func payloadOf(size int) []byte {
+	return []byte(`"` + strings.Repeat("x", size-2) + `"`)
+}
+
+func TestPayloadGovernorPrepare(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		maxBytes       int
+		claimThreshold int
+		noStore        bool
+		size           int
+		claimChecked   bool
+		rejected       bool
+	}{
+		{"small payloads pass through", 1000, 500, false, 400, false, false},
+		{"payloads at the threshold pass through", 1000, 500, false, 500, false, false},
+		{"payloads above the threshold are claim-checked", 1000, 500, false, 501, true, false},
+		{"payloads above the limit are claim-checked", 1000, 500, false, 5000, true, false},
+		{"payloads above the limit without a store are rejected", 1000, 500, true, 1001, false, true},
+		{"payloads at the limit without a store pass through", 1000, 500, true, 1000, false, false},
+		{"payloads above the limit with claim checks disabled are rejected", 1000, 0, false, 1001, false, true},
+		{"no limit", 0, 0, false, 1 << 20, false, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryClaimStore{objects: make(map[string][]byte)}
+			var claimStore ClaimStore = store
+			if tt.noStore {
+				claimStore = nil
+			}
+			governor, metrics := testGovernor(tt.maxBytes, tt.claimThreshold, claimStore)
+
+			payload := payloadOf(tt.size)
+			value, headers, err := governor.Prepare(context.Background(), "raw.transactions", "txn-1", payload)
+			if metrics.observed != 1 {
+				t.Fatalf("got %d size observations, want 1", metrics.observed)
+			}
+
+			if tt.rejected {
+				if !errors.Is(err, ErrPayloadTooLarge) {
+					t.Fatalf("got %v, want ErrPayloadTooLarge", err)
+				}
+				if metrics.oversized != 1 || len(store.objects) != 0 {
+					t.Fatalf("got %d oversized and %d stored, want the rejection counted and nothing stored", metrics.oversized, len(store.objects))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Prepare: %v", err)
+			}
+
+			if !tt.claimChecked {
+				if !bytes.Equal(value, payload) || len(headers) != 0 || len(store.objects) != 0 {
+					t.Fatalf("expected the payload to be published unchanged")
+				}
+				return
+			}
+
+			if len(headers) != 1 || headers[0].Key != ClaimCheckHeader || string(headers[0].Value) != "true" {
+				t.Fatalf("got headers %v, want the claim check header", headers)
+			}
+			var envelope ClaimCheckEnvelope
+			if err := json.Unmarshal(value, &envelope); err != nil {
+				t.Fatalf("claim check is not JSON: %v", err)
+			}
+			check := envelope.ClaimCheck
+			digest := sha256.Sum256(payload)
+			if check.SizeBytes != tt.size || check.SHA256 != hex.EncodeToString(digest[:]) || check.Topic != "raw.transactions" || check.Key != "txn-1" {
+				t.Fatalf("got %+v, want the payload's size, digest, topic and key", check)
+			}
+			if !bytes.Equal(store.objects[check.Location], payload) {
+				t.Fatalf("nothing stored at %s", check.Location)
+			}
+			if check.URL != "https://storage.example.com/"+check.Location {
+				t.Fatalf("got URL %s", check.URL)
+			}
+			if !strings.HasPrefix(check.Location, "claims/claim-check-raw-transactions-") {
+				t.Fatalf("got location %s, want the topic sanitized into the object name", check.Location)
+			}
+			if metrics.claimChecks != 1 {
+				t.Fatalf("got %d claim checks, want 1", metrics.claimChecks)
+			}
+		})
+	}
+
+	t.Run("storage failures are not published", func(t *testing.T) {
+		store := &memoryClaimStore{objects: make(map[string][]byte), err: errors.New("bucket unavailable")}
+		governor, metrics := testGovernor(1000, 500, store)
+
+		value, _, err := governor.Prepare(context.Background(), "raw.transactions", "txn-1", payloadOf(2000))
+		if err == nil || value != nil {
+			t.Fatalf("got %q, %v, want an error", value, err)
+		}
+		if metrics.claimChecks != 0 {
+			t.Fatalf("got %d claim checks, want none", metrics.claimChecks)
+		}
+	})
+}
+
+func TestCompressionCodec(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		codec kafka.Compression
+	}{
+		{"", 0},
+		{"none", 0},
+		{"gzip", kafka.Gzip},
+		{"snappy", kafka.Snappy},
+		{"lz4", kafka.Lz4},
+		{"zstd", kafka.Zstd},
+	} {
+		codec, err := compressionCodec(tt.name)
+		if err != nil || codec != tt.codec {
+			t.Errorf("compressionCodec(%q) = %v, %v, want %v", tt.name, codec, err, tt.codec)
+		}
+	}
+
+	if _, err := compressionCodec("brotli"); err == nil {
+		t.Error("expected an error for an unsupported codec")
+	}
+}
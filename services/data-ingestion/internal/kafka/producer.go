@@ -27,17 +27,27 @@ type Message struct {
 
 // KafkaProducer implements the Producer interface
 type KafkaProducer struct {
-	writers map[string]*kafka.Writer
-	config  config.KafkaConfig
-	logger  *logrus.Logger
+	writers  map[string]*kafka.Writer
+	config   config.KafkaConfig
+	governor *PayloadGovernor
+	logger   *logrus.Logger
 }
 
-// NewProducer creates a new Kafka producer
-func NewProducer(cfg config.KafkaConfig) (*KafkaProducer, error) {
+// NewProducer creates a new Kafka producer. Oversized payloads are
+// claim-checked into store when claim checks are enabled.
+func NewProducer(cfg config.KafkaConfig, store ClaimStore, metrics PayloadMetrics) (*KafkaProducer, error) {
+	logger := logrus.New()
+
+	claimThreshold := 0
+	if cfg.ClaimCheckEnabled {
+		claimThreshold = cfg.ClaimCheckThreshold
+	}
+
 	producer := &KafkaProducer{
-		writers: make(map[string]*kafka.Writer),
-		config:  cfg,
-		logger:  logrus.New(),
+		writers:  make(map[string]*kafka.Writer),
+		config:   cfg,
+		governor: NewPayloadGovernor(cfg.MaxMessageBytes, claimThreshold, store, metrics, logger),
+		logger:   logger,
 	}
 
 	// Create writers for each topic
@@ -50,6 +60,15 @@ func NewProducer(cfg config.KafkaConfig) (*KafkaProducer, error) {
 	}
 
 	for _, topic := range topics {
+		codecName := cfg.Compression
+		if override, ok := cfg.TopicCompression[topic]; ok {
+			codecName = override
+		}
+		compression, err := compressionCodec(codecName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid compression for topic %s: %w", topic, err)
+		}
+
 		writer := &kafka.Writer{
 			Addr:         kafka.TCP(cfg.Brokers...),
 			Topic:        topic,
@@ -57,7 +76,9 @@ func NewProducer(cfg config.KafkaConfig) (*KafkaProducer, error) {
 			BatchSize:    cfg.ProducerBatchSize,
 			BatchTimeout: cfg.ProducerFlushTimeout,
 			WriteTimeout: cfg.ProducerTimeout,
+			BatchBytes:   int64(cfg.MaxMessageBytes),
 			RequiredAcks: kafka.RequireAll,
+			Compression:  compression,
 			Async:        false,
 		}
 
@@ -80,18 +101,31 @@ func (p *KafkaProducer) Publish(topic, key string, message interface{}) error {
 		return fmt.Errorf("no writer configured for topic: %s", topic)
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.ProducerTimeout)
+	defer cancel()
+
 	// Serialize message
 	messageBytes, err := json.Marshal(message)
 	if err != nil {
 		return fmt.Errorf("failed to serialize message: %w", err)
 	}
 
+	// Enforce the size limit, claim-checking oversized payloads
+	value, extraHeaders, err := p.governor.Prepare(ctx, topic, key, messageBytes)
+	if err != nil {
+		p.logger.WithError(err).WithFields(logrus.Fields{
+			"topic": topic,
+			"key":   key,
+		}).Error("Rejected message payload")
+		return err
+	}
+
 	// Create Kafka message
 	kafkaMessage := kafka.Message{
 		Key:   []byte(key),
-		Value: messageBytes,
+		Value: value,
 		Time:  time.Now(),
-		Headers: []kafka.Header{
+		Headers: append([]kafka.Header{
 			{
 				Key:   "content-type",
 				Value: []byte("application/json"),
@@ -100,12 +134,10 @@ func (p *KafkaProducer) Publish(topic, key string, message interface{}) error {
 				Key:   "source-service",
 				Value: []byte("data-ingestion"),
 			},
-		},
+		}, extraHeaders...),
 	}
 
 	// Send message
-	ctx, cancel := context.WithTimeout(context.Background(), p.config.ProducerTimeout)
-	defer cancel()
 
 	if err := writer.WriteMessages(ctx, kafkaMessage); err != nil {
 		p.logger.WithError(err).WithFields(logrus.Fields{
@@ -130,6 +162,9 @@ func (p *KafkaProducer) PublishBatch(topic string, messages []Message) error {
 		return fmt.Errorf("no writer configured for topic: %s", topic)
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.ProducerTimeout)
+	defer cancel()
+
 	// Convert messages to Kafka messages
 	kafkaMessages := make([]kafka.Message, len(messages))
 	for i, msg := range messages {
@@ -138,11 +173,16 @@ func (p *KafkaProducer) PublishBatch(topic string, messages []Message) error {
 			return fmt.Errorf("failed to serialize message %d: %w", i, err)
 		}
 
+		value, extraHeaders, err := p.governor.Prepare(ctx, topic, msg.Key, messageBytes)
+		if err != nil {
+			return fmt.Errorf("message %d: %w", i, err)
+		}
+
 		kafkaMessages[i] = kafka.Message{
 			Key:   []byte(msg.Key),
-			Value: messageBytes,
+			Value: value,
 			Time:  time.Now(),
-			Headers: []kafka.Header{
+			Headers: append([]kafka.Header{
 				{
 					Key:   "content-type",
 					Value: []byte("application/json"),
@@ -151,13 +191,11 @@ func (p *KafkaProducer) PublishBatch(topic string, messages []Message) error {
 					Key:   "source-service",
 					Value: []byte("data-ingestion"),
 				},
-			},
+			}, extraHeaders...),
 		}
 	}
 
 	// Send batch
-	ctx, cancel := context.WithTimeout(context.Background(), p.config.ProducerTimeout)
-	defer cancel()
 
 	if err := writer.WriteMessages(ctx, kafkaMessages...); err != nil {
 		p.logger.WithError(err).WithFields(logrus.Fields{
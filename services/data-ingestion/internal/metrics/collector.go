@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -51,6 +53,16 @@ type Collector struct {
 	kafkaMessageErrors  prometheus.Counter
 	kafkaPublishDuration prometheus.Histogram
 
+	// Kafka payload governance metrics, labelled by topic
+	kafkaPayloadSize       *prometheus.HistogramVec
+	kafkaClaimChecks       *prometheus.CounterVec
+	kafkaOversizedPayloads *prometheus.CounterVec
+
+	// Pipeline canary metrics
+	canaryRuns          *prometheus.CounterVec
+	canaryStageLatency  *prometheus.HistogramVec
+	canaryStageFailures *prometheus.CounterVec
+
 	// Storage metrics
 	storageOperations       prometheus.Counter
 	storageErrors           prometheus.Counter
@@ -253,6 +265,46 @@ func NewCollector() *Collector {
 			Help:      "Duration of Kafka message publishing",
 			Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1.0},
 		}),
+		kafkaPayloadSize: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "aegisshield",
+			Subsystem: "data_ingestion",
+			Name:      "kafka_payload_size_bytes",
+			Help:      "Size of serialized Kafka payloads before claim checks",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 9), // 256B to 16MB
+		}, []string{"topic"}),
+		kafkaClaimChecks: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "aegisshield",
+			Subsystem: "data_ingestion",
+			Name:      "kafka_claim_checks_total",
+			Help:      "Total number of oversized payloads moved to object storage",
+		}, []string{"topic"}),
+		kafkaOversizedPayloads: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "aegisshield",
+			Subsystem: "data_ingestion",
+			Name:      "kafka_oversized_payloads_total",
+			Help:      "Total number of payloads rejected for exceeding the message size limit",
+		}, []string{"topic"}),
+
+		// Pipeline canary metrics
+		canaryRuns: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "aegisshield",
+			Subsystem: "data_ingestion",
+			Name:      "canary_runs_total",
+			Help:      "Total number of synthetic pipeline probes by result",
+		}, []string{"result"}),
+		canaryStageLatency: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "aegisshield",
+			Subsystem: "data_ingestion",
+			Name:      "canary_stage_latency_seconds",
+			Help:      "Time from injecting a synthetic probe until each pipeline stage emitted it",
+			Buckets:   prometheus.ExponentialBuckets(0.5, 2, 12), // 0.5s to ~17m
+		}, []string{"stage"}),
+		canaryStageFailures: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "aegisshield",
+			Subsystem: "data_ingestion",
+			Name:      "canary_stage_failures_total",
+			Help:      "Total number of synthetic probes a pipeline stage missed or delivered late",
+		}, []string{"stage"}),
 
 		// Storage metrics
 		storageOperations: promauto.NewCounter(prometheus.CounterOpts{
@@ -374,4 +426,34 @@ func (c *Collector) AddGauge(name string, value float64) {
 	case "db_connections":
 		c.dbConnections.Add(value)
 	}
-}
\ No newline at end of file
+}
+
+// ObserveKafkaPayloadSize records the size of a serialized Kafka payload
+func (c *Collector) ObserveKafkaPayloadSize(topic string, bytes int) {
+	c.kafkaPayloadSize.WithLabelValues(topic).Observe(float64(bytes))
+}
+
+// IncrementKafkaClaimChecks counts a payload published by claim check
+func (c *Collector) IncrementKafkaClaimChecks(topic string) {
+	c.kafkaClaimChecks.WithLabelValues(topic).Inc()
+}
+
+// IncrementKafkaOversizedPayloads counts a payload rejected for its size
+func (c *Collector) IncrementKafkaOversizedPayloads(topic string) {
+	c.kafkaOversizedPayloads.WithLabelValues(topic).Inc()
+}
+
+// IncrementCanaryRuns counts a finished canary probe by result
+func (c *Collector) IncrementCanaryRuns(result string) {
+	c.canaryRuns.WithLabelValues(result).Inc()
+}
+
+// ObserveCanaryStageLatency records how long a stage took to emit a probe
+func (c *Collector) ObserveCanaryStageLatency(stage string, latency time.Duration) {
+	c.canaryStageLatency.WithLabelValues(stage).Observe(latency.Seconds())
+}
+
+// IncrementCanaryStageFailures counts a probe a stage missed or delivered late
+func (c *Collector) IncrementCanaryStageFailures(stage string) {
+	c.canaryStageFailures.WithLabelValues(stage).Inc()
+}
@@ -0,0 +1,100 @@
+package quota
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	limits "aegisshield/shared/quota"
+)
+
+// AlertSender raises an alert for a tenant or user reaching a quota threshold
+// and returns its ID
+type AlertSender interface {
+	SendAlert(ctx context.Context, report *Report) (string, error)
+}
+
+// AlertingEngineClient raises quota alerts through the alerting engine's REST API
+type AlertingEngineClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewAlertingEngineClient(baseURL string, timeout time.Duration) *AlertingEngineClient {
+	return &AlertingEngineClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// SendAlert creates an alert for the tenant or user in the alerting engine
+func (c *AlertingEngineClient) SendAlert(ctx context.Context, report *Report) (string, error) {
+	severity := "medium"
+	if report.Level == limits.LevelExceeded {
+		severity = "high"
+	}
+
+	payload := map[string]interface{}{
+		"title":       alertTitle(report),
+		"description": fmt.Sprintf("%s %s holds %d bytes in %d files of a %d byte, %d file quota", report.Scope, report.Subject, report.Bytes(), report.Files(), report.Limits.Bytes, report.Limits.Files),
+		"severity":    severity,
+		"type":        "storage_quota",
+		"priority":    severity,
+		"source":      "data-ingestion",
+		"created_by":  "storage-quota",
+		"event_data": map[string]interface{}{
+			"scope":          report.Scope,
+			"subject_id":     report.Subject,
+			"level":          report.Level,
+			"used_bytes":     report.UsedBytes,
+			"used_files":     report.UsedFiles,
+			"reserved_bytes": report.ReservedBytes,
+			"limit_bytes":    report.Limits.Bytes,
+			"limit_files":    report.Limits.Files,
+		},
+		"metadata": map[string]interface{}{
+			"component": "storage-quota",
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/alerts", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("alerting engine returned status %d", resp.StatusCode)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("failed to decode alert response: %w", err)
+	}
+
+	return created.ID, nil
+}
+
+func alertTitle(report *Report) string {
+	if report.Level == limits.LevelExceeded {
+		return fmt.Sprintf("Upload storage quota exceeded for %s %s", report.Scope, report.Subject)
+	}
+	return fmt.Sprintf("Upload storage for %s %s is approaching its quota", report.Scope, report.Subject)
+}
@@ -0,0 +1,377 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/metadata"
+
+	"aegisshield/services/data-ingestion/internal/config"
+	"aegisshield/services/data-ingestion/internal/database"
+	limits "aegisshield/shared/quota"
+)
+
+// maxUserIDLength matches the width of the quota tables' subject column
+const maxUserIDLength = 255
+
+var (
+	// ErrExceeded is returned when an upload would pass the hard stop of its
+	// tenant's or uploader's quota
+	ErrExceeded = limits.ErrExceeded
+	// ErrInvalidSize is returned for uploads of negative size
+	ErrInvalidSize = errors.New("upload size must not be negative")
+	// ErrInvalidSubject is returned for malformed tenant or user IDs
+	ErrInvalidSubject = errors.New("invalid quota subject")
+	// ErrInvalidLimit is returned for negative limits
+	ErrInvalidLimit = errors.New("quota limits must not be negative")
+)
+
+// Report is a tenant's or user's upload storage usage against its limits
+type Report struct {
+	limits.Usage
+	Level limits.Level `json:"level"`
+	// RemainingBytes is what can still be uploaded before the hard stop, or
+	// -1 without a byte limit
+	RemainingBytes  int64   `json:"remaining_bytes"`
+	WarningPercent  float64 `json:"warning_percent"`
+	HardStopPercent float64 `json:"hard_stop_percent"`
+	// Overridden is set when the limits replace the configured defaults
+	Overridden bool `json:"overridden"`
+}
+
+// Service enforces per-tenant and per-user upload storage quotas. Uploads
+// reserve their size before they are stored, so that a burst of concurrent
+// uploads cannot together pass a limit, and commit the reservation once
+// stored. Tenants and users reaching the warning threshold or the hard stop
+// are raised as alerts in the alerting engine.
+type Service struct {
+	repo       *database.QuotaRepository
+	alerts     AlertSender
+	thresholds limits.Thresholds
+	cfg        config.QuotaConfig
+	logger     *logrus.Logger
+}
+
+// NewService creates a quota service. A nil alert sender only logs
+// threshold crossings.
+func NewService(repo *database.QuotaRepository, alerts AlertSender, cfg config.QuotaConfig, logger *logrus.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		alerts: alerts,
+		thresholds: limits.Thresholds{
+			WarningPercent:  cfg.WarningPercent,
+			HardStopPercent: cfg.HardStopPercent,
+		},
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Tenant returns the tenant an HTTP request is made for
+func (s *Service) Tenant(r *http.Request) (string, error) {
+	tenantID, err := limits.Tenant(r, s.cfg.TenantHeader, s.cfg.DefaultTenant)
+	if err != nil {
+		return "", ErrInvalidSubject
+	}
+	return tenantID, nil
+}
+
+// TenantFromContext returns the tenant a gRPC call is made for, named in
+// its metadata under the lowercased tenant header
+func (s *Service) TenantFromContext(ctx context.Context) (string, error) {
+	var value string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(strings.ToLower(s.cfg.TenantHeader)); len(values) > 0 {
+			value = values[0]
+		}
+	}
+
+	tenantID, err := limits.TenantFrom(value, s.cfg.DefaultTenant)
+	if err != nil {
+		return "", ErrInvalidSubject
+	}
+	return tenantID, nil
+}
+
+// Check reports whether an upload of size bytes would fit within the quota
+// of a tenant and user, without reserving anything
+func (s *Service) Check(ctx context.Context, tenantID, userID string, size int64) (limits.Decision, error) {
+	if size < 0 {
+		return limits.Decision{}, ErrInvalidSize
+	}
+	if err := validateSubject(limits.ScopeUser, userID); err != nil {
+		return limits.Decision{}, err
+	}
+
+	tenant, err := s.repo.GetUsage(ctx, database.QuotaScopeTenant, tenantID)
+	if err != nil {
+		return limits.Decision{}, err
+	}
+	user, err := s.repo.GetUsage(ctx, database.QuotaScopeUser, userID)
+	if err != nil {
+		return limits.Decision{}, err
+	}
+
+	return s.thresholds.Evaluate(size, s.usage(tenant), s.usage(user)), nil
+}
+
+// Reserve holds quota for an upload of size bytes. Uploads that would pass a
+// hard stop fail with ErrExceeded and the decision explaining why. The
+// reservation must be committed once the file is stored, or released if the
+// upload fails.
+func (s *Service) Reserve(ctx context.Context, tenantID, userID string, size int64) (*database.QuotaReservation, limits.Decision, error) {
+	if size < 0 {
+		return nil, limits.Decision{}, ErrInvalidSize
+	}
+	if err := validateSubject(limits.ScopeUser, userID); err != nil {
+		return nil, limits.Decision{}, err
+	}
+
+	reservation := &database.QuotaReservation{
+		TenantID:  tenantID,
+		UserID:    userID,
+		Bytes:     size,
+		ExpiresAt: time.Now().Add(s.cfg.ReservationTTL),
+	}
+
+	var decision limits.Decision
+	err := s.repo.Reserve(ctx, reservation, func(tenant, user *database.QuotaUsage) error {
+		decision = s.thresholds.Evaluate(size, s.usage(tenant), s.usage(user))
+		if !decision.Allowed {
+			return ErrExceeded
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrExceeded) {
+			s.logger.WithFields(logrus.Fields{
+				"tenant_id": tenantID,
+				"user_id":   userID,
+				"size":      size,
+				"scope":     decision.Scope,
+			}).Warn("Upload refused by storage quota")
+			s.alertExceeded(ctx, decision)
+		}
+		return nil, decision, err
+	}
+
+	return reservation, decision, nil
+}
+
+// Commit charges a stored upload to its reservation and raises an alert if
+// the upload took its tenant or user to a higher quota level
+func (s *Service) Commit(ctx context.Context, reservation *database.QuotaReservation) error {
+	tenant, user, err := s.repo.Commit(ctx, reservation)
+	if err != nil {
+		return err
+	}
+
+	s.updateLevel(ctx, tenant)
+	s.updateLevel(ctx, user)
+	return nil
+}
+
+// Release returns the quota of an upload that failed
+func (s *Service) Release(ctx context.Context, reservation *database.QuotaReservation) {
+	if err := s.repo.Release(ctx, reservation.ID); err != nil {
+		s.logger.WithError(err).WithField("reservation_id", reservation.ID).Error("Failed to release quota reservation")
+	}
+}
+
+// Usage reports a tenant's or user's upload storage usage
+func (s *Service) Usage(ctx context.Context, scope limits.Scope, subjectID string) (*Report, error) {
+	if err := validateSubject(scope, subjectID); err != nil {
+		return nil, err
+	}
+
+	usage, err := s.repo.GetUsage(ctx, string(scope), subjectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.report(usage), nil
+}
+
+// ListUsage reports the upload storage usage of every tenant or user holding any
+func (s *Service) ListUsage(ctx context.Context, scope limits.Scope) ([]*Report, error) {
+	usages, err := s.repo.ListUsage(ctx, string(scope))
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*Report, 0, len(usages))
+	for _, usage := range usages {
+		reports = append(reports, s.report(usage))
+	}
+	return reports, nil
+}
+
+// SetLimit replaces the configured limits for one tenant or user
+func (s *Service) SetLimit(ctx context.Context, scope limits.Scope, subjectID string, limitBytes, limitFiles *int64, updatedBy string) (*Report, error) {
+	if err := validateSubject(scope, subjectID); err != nil {
+		return nil, err
+	}
+	if (limitBytes != nil && *limitBytes < 0) || (limitFiles != nil && *limitFiles < 0) {
+		return nil, ErrInvalidLimit
+	}
+
+	limit := &database.QuotaLimit{
+		Scope:      string(scope),
+		SubjectID:  subjectID,
+		LimitBytes: limitBytes,
+		LimitFiles: limitFiles,
+		UpdatedBy:  updatedBy,
+	}
+	if err := s.repo.SetLimit(ctx, limit); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"scope":      scope,
+		"subject_id": subjectID,
+		"updated_by": updatedBy,
+	}).Info("Storage quota set")
+
+	return s.Usage(ctx, scope, subjectID)
+}
+
+// Start releases expired reservations every SweepInterval until the context
+// is cancelled
+func (s *Service) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := s.repo.ReleaseExpired(ctx, time.Now())
+			if err != nil {
+				s.logger.WithError(err).Error("Failed to release expired quota reservations")
+				continue
+			}
+			if released > 0 {
+				s.logger.WithField("count", released).Info("Expired quota reservations released")
+			}
+		}
+	}
+}
+
+// updateLevel records a subject's quota level after an upload, alerting when
+// it rises. Only the upload that wins the swap alerts, so a burst of uploads
+// crossing a threshold together raises one alert.
+func (s *Service) updateLevel(ctx context.Context, usage *database.QuotaUsage) {
+	report := s.report(usage)
+	if string(report.Level) == usage.AlertedLevel {
+		return
+	}
+
+	changed, err := s.repo.MarkAlerted(ctx, usage.Scope, usage.SubjectID, usage.AlertedLevel, string(report.Level))
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to record quota level")
+		return
+	}
+	// Falling levels are recorded so the next rise alerts again
+	if !changed || report.Level.Rank() < limits.Level(usage.AlertedLevel).Rank() {
+		return
+	}
+
+	s.alert(ctx, report)
+}
+
+// alertExceeded raises an alert the first time uploads are refused by a
+// subject's hard stop
+func (s *Service) alertExceeded(ctx context.Context, decision limits.Decision) {
+	usage, err := s.repo.GetUsage(ctx, string(decision.Scope), decision.Subject)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get quota usage")
+		return
+	}
+	if limits.Level(usage.AlertedLevel) == limits.LevelExceeded {
+		return
+	}
+
+	changed, err := s.repo.MarkAlerted(ctx, usage.Scope, usage.SubjectID, usage.AlertedLevel, string(limits.LevelExceeded))
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to record quota level")
+		return
+	}
+	if changed {
+		report := s.report(usage)
+		report.Level = limits.LevelExceeded
+		s.alert(ctx, report)
+	}
+}
+
+func (s *Service) alert(ctx context.Context, report *Report) {
+	entry := s.logger.WithFields(logrus.Fields{
+		"scope":       report.Scope,
+		"subject_id":  report.Subject,
+		"level":       report.Level,
+		"bytes":       report.Bytes(),
+		"limit_bytes": report.Limits.Bytes,
+	})
+	entry.Warn("Storage quota threshold reached")
+
+	if s.alerts == nil {
+		return
+	}
+	if _, err := s.alerts.SendAlert(ctx, report); err != nil {
+		entry.WithError(err).Error("Failed to raise storage quota alert")
+	}
+}
+
+// usage applies the configured limits to a subject without its own
+func (s *Service) usage(u *database.QuotaUsage) limits.Usage {
+	usage := limits.Usage{
+		Scope:         limits.Scope(u.Scope),
+		Subject:       u.SubjectID,
+		UsedBytes:     u.UsedBytes,
+		UsedFiles:     u.UsedFiles,
+		ReservedBytes: u.ReservedBytes,
+		ReservedFiles: u.ReservedFiles,
+		Limits:        limits.Limits{Bytes: s.cfg.UserLimitBytes, Files: s.cfg.UserLimitFiles},
+	}
+	if usage.Scope == limits.ScopeTenant {
+		usage.Limits = limits.Limits{Bytes: s.cfg.TenantLimitBytes, Files: s.cfg.TenantLimitFiles}
+	}
+	if u.LimitBytes != nil {
+		usage.Limits.Bytes = *u.LimitBytes
+	}
+	if u.LimitFiles != nil {
+		usage.Limits.Files = *u.LimitFiles
+	}
+	return usage
+}
+
+func (s *Service) report(u *database.QuotaUsage) *Report {
+	usage := s.usage(u)
+	return &Report{
+		Usage:           usage,
+		Level:           s.thresholds.Level(usage),
+		RemainingBytes:  s.thresholds.Remaining(usage),
+		WarningPercent:  s.thresholds.WarningPercent,
+		HardStopPercent: s.thresholds.HardStopPercent,
+		Overridden:      u.LimitBytes != nil || u.LimitFiles != nil,
+	}
+}
+
+func validateSubject(scope limits.Scope, subjectID string) error {
+	switch scope {
+	case limits.ScopeTenant:
+		if tenantID, err := limits.TenantFrom(subjectID, ""); err != nil || tenantID == "" {
+			return ErrInvalidSubject
+		}
+	case limits.ScopeUser:
+		if strings.TrimSpace(subjectID) == "" || len(subjectID) > maxUserIDLength {
+			return ErrInvalidSubject
+		}
+	default:
+		return ErrInvalidSubject
+	}
+	return nil
+}
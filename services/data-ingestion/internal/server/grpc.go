@@ -2,13 +2,16 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -17,13 +20,19 @@ import (
 	"aegisshield/services/data-ingestion/internal/kafka"
 	"aegisshield/services/data-ingestion/internal/metrics"
 	"aegisshield/services/data-ingestion/internal/processor"
+	"aegisshield/services/data-ingestion/internal/quota"
 	"aegisshield/services/data-ingestion/internal/storage"
 	"aegisshield/services/data-ingestion/internal/validator"
 	pb "aegisshield/shared/proto/data-ingestion"
 	shared "aegisshield/shared/proto/shared"
+	limits "aegisshield/shared/quota"
 	"aegisshield/shared/utils"
 )
 
+// quotaLevelHeader flags uploads that take their tenant or uploader past the
+// storage quota warning threshold
+const quotaLevelHeader = "x-quota-level"
+
 // Repositories contains all data access objects
 type Repositories struct {
 	FileUpload  *database.FileUploadRepository
@@ -38,6 +47,8 @@ type Services struct {
 	Kafka   kafka.Producer
 	Metrics *metrics.Collector
 	Logger  *logrus.Logger
+	// Quota enforces upload storage quotas; nil when quotas are disabled
+	Quota *quota.Service
 }
 
 // DataIngestionServer implements the DataIngestionService gRPC service
@@ -83,11 +94,24 @@ func (s *DataIngestionServer) UploadFile(ctx context.Context, req *pb.UploadFile
 		Metadata:   req.Metadata,
 	}
 
+	// Hold storage quota before the file is stored
+	reservation, level, err := s.reserveQuota(ctx, req.UploadedBy, upload.FileSize)
+	if err != nil {
+		s.services.Metrics.IncrementCounter("upload_file_errors_total")
+		return nil, err
+	}
+	if level != limits.LevelOK {
+		if err := grpc.SetHeader(ctx, metadata.Pairs(quotaLevelHeader, string(level))); err != nil {
+			s.services.Logger.WithError(err).Warn("Failed to set quota level header")
+		}
+	}
+
 	// Store file
 	storagePath, err := s.services.Storage.Store(ctx, fileID, req.FileName, req.FileData)
 	if err != nil {
 		s.services.Logger.WithError(err).Error("Failed to store file")
 		s.services.Metrics.IncrementCounter("upload_file_errors_total")
+		s.releaseQuota(ctx, reservation)
 		return nil, status.Errorf(codes.Internal, "failed to store file: %v", err)
 	}
 
@@ -97,8 +121,10 @@ func (s *DataIngestionServer) UploadFile(ctx context.Context, req *pb.UploadFile
 	if err := s.repos.FileUpload.Create(upload); err != nil {
 		s.services.Logger.WithError(err).Error("Failed to create file upload record")
 		s.services.Metrics.IncrementCounter("upload_file_errors_total")
+		s.releaseQuota(ctx, reservation)
 		return nil, status.Errorf(codes.Internal, "failed to save upload record: %v", err)
 	}
+	s.commitQuota(ctx, reservation)
 
 	// Update status to uploaded
 	if err := s.repos.FileUpload.UpdateStatus(fileID, "uploaded", nil); err != nil {
@@ -179,12 +205,25 @@ func (s *DataIngestionServer) UploadFileStream(stream pb.DataIngestionService_Up
 		Metadata:   metadata,
 	}
 
-	// Store file
+	// Hold storage quota before the file is stored
 	ctx := stream.Context()
+	reservation, level, err := s.reserveQuota(ctx, uploadedBy, upload.FileSize)
+	if err != nil {
+		s.services.Metrics.IncrementCounter("upload_file_stream_errors_total")
+		return err
+	}
+	if level != limits.LevelOK {
+		if err := stream.SetHeader(metadata.Pairs(quotaLevelHeader, string(level))); err != nil {
+			s.services.Logger.WithError(err).Warn("Failed to set quota level header")
+		}
+	}
+
+	// Store file
 	storagePath, err := s.services.Storage.Store(ctx, fileID, fileName, fileData)
 	if err != nil {
 		s.services.Logger.WithError(err).Error("Failed to store streamed file")
 		s.services.Metrics.IncrementCounter("upload_file_stream_errors_total")
+		s.releaseQuota(ctx, reservation)
 		return status.Errorf(codes.Internal, "failed to store file: %v", err)
 	}
 
@@ -194,8 +233,10 @@ func (s *DataIngestionServer) UploadFileStream(stream pb.DataIngestionService_Up
 	if err := s.repos.FileUpload.Create(upload); err != nil {
 		s.services.Logger.WithError(err).Error("Failed to create file upload record")
 		s.services.Metrics.IncrementCounter("upload_file_stream_errors_total")
+		s.releaseQuota(ctx, reservation)
 		return status.Errorf(codes.Internal, "failed to save upload record: %v", err)
 	}
+	s.commitQuota(ctx, reservation)
 
 	// Update status
 	if err := s.repos.FileUpload.UpdateStatus(fileID, "uploaded", nil); err != nil {
@@ -457,6 +498,52 @@ func (s *DataIngestionServer) validateUploadRequest(req *pb.UploadFileRequest) e
 	return nil
 }
 
+// reserveQuota holds storage quota for an upload of size bytes by the
+// tenant named in the call metadata. Uploads refused by quota fail with
+// ResourceExhausted. No reservation is made when quotas are disabled.
+func (s *DataIngestionServer) reserveQuota(ctx context.Context, uploadedBy string, size int64) (*database.QuotaReservation, limits.Level, error) {
+	if s.services.Quota == nil {
+		return nil, limits.LevelOK, nil
+	}
+
+	tenantID, err := s.services.Quota.TenantFromContext(ctx)
+	if err != nil {
+		return nil, "", status.Errorf(codes.InvalidArgument, "invalid tenant: %v", err)
+	}
+
+	reservation, decision, err := s.services.Quota.Reserve(ctx, tenantID, uploadedBy, size)
+	if err != nil {
+		switch {
+		case errors.Is(err, quota.ErrExceeded):
+			return nil, "", status.Error(codes.ResourceExhausted, decision.Message)
+		case errors.Is(err, quota.ErrInvalidSubject), errors.Is(err, quota.ErrInvalidSize):
+			return nil, "", status.Errorf(codes.InvalidArgument, "invalid upload: %v", err)
+		default:
+			s.services.Logger.WithError(err).Error("Failed to reserve storage quota")
+			return nil, "", status.Errorf(codes.Internal, "failed to reserve storage quota: %v", err)
+		}
+	}
+
+	return reservation, decision.Level, nil
+}
+
+// commitQuota charges a stored upload to its reservation. A failed commit
+// leaves the reservation to expire rather than failing the stored upload.
+func (s *DataIngestionServer) commitQuota(ctx context.Context, reservation *database.QuotaReservation) {
+	if reservation == nil {
+		return
+	}
+	if err := s.services.Quota.Commit(ctx, reservation); err != nil {
+		s.services.Logger.WithError(err).WithField("reservation_id", reservation.ID).Error("Failed to commit storage quota")
+	}
+}
+
+func (s *DataIngestionServer) releaseQuota(ctx context.Context, reservation *database.QuotaReservation) {
+	if reservation != nil {
+		s.services.Quota.Release(ctx, reservation)
+	}
+}
+
 func (s *DataIngestionServer) validateTransaction(txn *shared.Transaction) error {
 	if txn.Id == "" {
 		return fmt.Errorf("transaction ID is required")
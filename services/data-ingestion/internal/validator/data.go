@@ -14,6 +14,7 @@ import (
 	"github.com/aegisshield/data-ingestion/internal/metrics"
 	"github.com/aegisshield/shared/models"
 	pb "github.com/aegisshield/shared/proto"
+	"github.com/aegisshield/shared/validation"
 	"github.com/google/uuid"
 )
 
@@ -511,17 +512,7 @@ func (v *DataValidator) calculateQualityScore(result *ValidationResult) float64
 
 // isValidCurrency checks if the currency code is valid (ISO 4217)
 func (v *DataValidator) isValidCurrency(currency string) bool {
-	validCurrencies := []string{
-		"USD", "EUR", "GBP", "JPY", "AUD", "CAD", "CHF", "CNY", "SEK", "NZD",
-		"MXN", "SGD", "HKD", "NOK", "TRY", "ZAR", "BRL", "INR", "KRW", "RUB",
-	}
-
-	for _, valid := range validCurrencies {
-		if currency == valid {
-			return true
-		}
-	}
-	return false
+	return validation.IsCurrencyCode(currency)
 }
 
 // isValidAccountID checks if the account ID format is valid
@@ -0,0 +1,13 @@
+-- Migration: 005_create_feed_health_tables
+-- Description: Drop feed health tables
+-- Down Migration
+
+DROP INDEX IF EXISTS idx_feed_health_issues_window;
+DROP INDEX IF EXISTS idx_feed_health_issues_detected_at;
+DROP INDEX IF EXISTS idx_feed_health_issues_source_status;
+DROP INDEX IF EXISTS idx_feed_deliveries_file_id;
+DROP INDEX IF EXISTS idx_feed_deliveries_source_received;
+
+DROP TABLE IF EXISTS feed_health_issues;
+DROP TABLE IF EXISTS feed_deliveries;
+DROP TABLE IF EXISTS feed_sources;
@@ -0,0 +1,86 @@
+-- Migration: 005_create_feed_health_tables
+-- Description: Create feed source, delivery and health issue tables for volume anomaly detection
+-- Up Migration
+
+CREATE TABLE IF NOT EXISTS feed_sources (
+    id VARCHAR(100) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    arrival_windows JSONB NOT NULL DEFAULT '[]',
+    grace_period_minutes INTEGER NOT NULL DEFAULT 30,
+    min_records BIGINT NOT NULL DEFAULT 0,
+    deviation_threshold DOUBLE PRECISION NOT NULL DEFAULT 3.0,
+    baseline_weeks INTEGER NOT NULL DEFAULT 8,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS feed_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_id VARCHAR(100) NOT NULL REFERENCES feed_sources(id) ON DELETE CASCADE,
+    file_id UUID REFERENCES file_uploads(id) ON DELETE SET NULL,
+    record_count BIGINT NOT NULL DEFAULT 0,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS feed_health_issues (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_id VARCHAR(100) NOT NULL REFERENCES feed_sources(id) ON DELETE CASCADE,
+    issue_type VARCHAR(50) NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    window_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    expected_records DOUBLE PRECISION,
+    actual_records BIGINT,
+    message TEXT NOT NULL,
+    alert_id VARCHAR(100),
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    resolved_by VARCHAR(255)
+);
+
+-- Indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_feed_deliveries_source_received ON feed_deliveries(source_id, received_at);
+CREATE INDEX IF NOT EXISTS idx_feed_deliveries_file_id ON feed_deliveries(file_id);
+CREATE INDEX IF NOT EXISTS idx_feed_health_issues_source_status ON feed_health_issues(source_id, status);
+CREATE INDEX IF NOT EXISTS idx_feed_health_issues_detected_at ON feed_health_issues(detected_at);
+
+-- One issue per source, type and arrival window so repeated checks do not re-alert
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feed_health_issues_window
+    ON feed_health_issues(source_id, issue_type, window_start);
+
+-- Check constraints
+ALTER TABLE feed_sources
+ADD CONSTRAINT chk_feed_sources_settings
+CHECK (grace_period_minutes >= 0 AND min_records >= 0 AND deviation_threshold > 0 AND baseline_weeks > 0);
+
+ALTER TABLE feed_deliveries
+ADD CONSTRAINT chk_feed_deliveries_record_count
+CHECK (record_count >= 0);
+
+ALTER TABLE feed_health_issues
+ADD CONSTRAINT chk_feed_health_issues_type
+CHECK (issue_type IN ('missing_feed', 'volume_drop', 'volume_spike'));
+
+ALTER TABLE feed_health_issues
+ADD CONSTRAINT chk_feed_health_issues_severity
+CHECK (severity IN ('low', 'medium', 'high', 'critical'));
+
+ALTER TABLE feed_health_issues
+ADD CONSTRAINT chk_feed_health_issues_status
+CHECK (status IN ('open', 'resolved'));
+
+-- Comments
+COMMENT ON TABLE feed_sources IS 'Upstream data feeds with their expected arrival windows and volume settings';
+COMMENT ON COLUMN feed_sources.arrival_windows IS 'Expected arrival windows as JSON: [{"days": [1,2,3,4,5], "start": "06:00", "end": "08:00"}]';
+COMMENT ON COLUMN feed_sources.grace_period_minutes IS 'How long after a window closes a feed may still arrive before it is reported missing';
+COMMENT ON COLUMN feed_sources.min_records IS 'Absolute floor on records per window, independent of the learned baseline';
+COMMENT ON COLUMN feed_sources.deviation_threshold IS 'Standard deviations from the same-weekday baseline that count as an anomaly';
+COMMENT ON COLUMN feed_sources.baseline_weeks IS 'Weeks of same-weekday history used as the volume baseline';
+COMMENT ON TABLE feed_deliveries IS 'Files or batches received per feed with their record counts';
+COMMENT ON TABLE feed_health_issues IS 'Missing feeds and volume anomalies detected per arrival window';
+COMMENT ON COLUMN feed_health_issues.alert_id IS 'Alert raised in the alerting engine for this issue';
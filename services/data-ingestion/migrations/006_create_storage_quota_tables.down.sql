@@ -0,0 +1,9 @@
+-- Migration: 006_create_storage_quota_tables
+-- Description: Drop storage quota tables
+-- Down Migration
+
+DROP INDEX IF EXISTS idx_storage_quota_reservations_expires_at;
+
+DROP TABLE IF EXISTS storage_quota_reservations;
+DROP TABLE IF EXISTS storage_quota_usage;
+DROP TABLE IF EXISTS storage_quota_limits;
@@ -0,0 +1,60 @@
+-- Migration: 006_create_storage_quota_tables
+-- Description: Create per-tenant and per-user upload storage quota tables
+-- Up Migration
+
+CREATE TABLE IF NOT EXISTS storage_quota_limits (
+    scope VARCHAR(10) NOT NULL,
+    subject_id VARCHAR(255) NOT NULL,
+    limit_bytes BIGINT,
+    limit_files BIGINT,
+    updated_by VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scope, subject_id)
+);
+
+CREATE TABLE IF NOT EXISTS storage_quota_usage (
+    scope VARCHAR(10) NOT NULL,
+    subject_id VARCHAR(255) NOT NULL,
+    used_bytes BIGINT NOT NULL DEFAULT 0,
+    used_files BIGINT NOT NULL DEFAULT 0,
+    reserved_bytes BIGINT NOT NULL DEFAULT 0,
+    reserved_files BIGINT NOT NULL DEFAULT 0,
+    alerted_level VARCHAR(20) NOT NULL DEFAULT 'ok',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scope, subject_id)
+);
+
+CREATE TABLE IF NOT EXISTS storage_quota_reservations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    bytes BIGINT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_storage_quota_reservations_expires_at ON storage_quota_reservations(expires_at);
+
+-- Check constraints
+ALTER TABLE storage_quota_limits
+ADD CONSTRAINT chk_storage_quota_limits_settings
+CHECK (scope IN ('tenant', 'user') AND (limit_bytes IS NULL OR limit_bytes >= 0) AND (limit_files IS NULL OR limit_files >= 0));
+
+ALTER TABLE storage_quota_usage
+ADD CONSTRAINT chk_storage_quota_usage_amounts
+CHECK (scope IN ('tenant', 'user') AND used_bytes >= 0 AND used_files >= 0 AND reserved_bytes >= 0 AND reserved_files >= 0);
+
+ALTER TABLE storage_quota_usage
+ADD CONSTRAINT chk_storage_quota_usage_alerted_level
+CHECK (alerted_level IN ('ok', 'warning', 'exceeded'));
+
+ALTER TABLE storage_quota_reservations
+ADD CONSTRAINT chk_storage_quota_reservations_bytes
+CHECK (bytes >= 0);
+
+-- Comments
+COMMENT ON TABLE storage_quota_limits IS 'Tenant and user upload limits that replace the configured defaults; NULL keeps the default and 0 is unlimited';
+COMMENT ON TABLE storage_quota_usage IS 'Bytes and files stored, and reserved by uploads in progress, per tenant and user';
+COMMENT ON COLUMN storage_quota_usage.alerted_level IS 'Quota level last raised as an alert, so each threshold crossing alerts once';
+COMMENT ON TABLE storage_quota_reservations IS 'Quota held for uploads in progress; expired reservations are released';
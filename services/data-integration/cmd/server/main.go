@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/aegisshield/data-integration/internal/config"
+	"github.com/aegisshield/data-integration/internal/enrichment"
 	"github.com/aegisshield/data-integration/internal/etl"
 	"github.com/aegisshield/data-integration/internal/handlers"
 	"github.com/aegisshield/data-integration/internal/kafka"
@@ -55,6 +56,15 @@ func main() {
 		logger.Fatal("Failed to initialize storage manager", zap.Error(err))
 	}
 
+	if cfg.Storage.WORM.Enabled {
+		if err := storageManager.VerifyWORM(context.Background()); err != nil {
+			logger.Fatal("WORM storage is enabled but the backend does not enforce Object Lock", zap.Error(err))
+		}
+		logger.Info("WORM storage enabled",
+			zap.Duration("default_retention", cfg.Storage.WORM.DefaultRetention),
+			zap.Strings("locked_prefixes", cfg.Storage.WORM.LockedPrefixes))
+	}
+
 	// Initialize lineage tracker
 	lineageStore := lineage.NewInMemoryLineageStore(logger)
 	lineageTracker := lineage.NewTracker(lineageStore, logger)
@@ -75,6 +85,16 @@ func main() {
 		logger,
 	)
 
+	// Initialize transaction enrichment
+	var enrichmentStage *enrichment.Stage
+	if cfg.ETL.Enrichment.Enabled {
+		enrichmentStage, err = enrichment.NewStage(cfg.ETL.Enrichment, logger)
+		if err != nil {
+			logger.Fatal("Failed to create enrichment stage", zap.Error(err))
+		}
+		etlPipeline.SetEnrichment(enrichmentStage)
+	}
+
 	// Initialize Kafka components
 	kafkaProducer, err := kafka.NewProducer(cfg.Kafka, logger)
 	if err != nil {
@@ -112,6 +132,10 @@ func main() {
 		logger,
 	)
 
+	if enrichmentStage != nil {
+		httpHandlers.SetEnrichment(enrichmentStage)
+	}
+
 	// Setup HTTP router
 	router := mux.NewRouter()
 	httpHandlers.RegisterRoutes(router)
@@ -137,6 +161,11 @@ func main() {
 		logger.Fatal("Failed to start ETL pipeline", zap.Error(err))
 	}
 
+	// Prune category aggregates that left the window
+	if enrichmentStage != nil {
+		go enrichmentStage.Start(ctx)
+	}
+
 	// Start gRPC server
 	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.GRPCPort))
 	if err != nil {
@@ -65,6 +65,7 @@ type ETLConfig struct {
 	MaxConcurrentJobs   int           `mapstructure:"max_concurrent_jobs"`
 	ValidationRules     ValidationConfig `mapstructure:"validation"`
 	DataQuality         QualityConfig    `mapstructure:"quality"`
+	Enrichment          EnrichmentConfig `mapstructure:"enrichment"`
 }
 
 // ValidationConfig represents data validation configuration
@@ -94,6 +95,26 @@ type QualityConfig struct {
 	FreshnessThreshold     time.Duration `mapstructure:"freshness_threshold"`
 }
 
+// EnrichmentConfig represents transaction classification configuration.
+// Rules are tried before the built-in merchant rules; classifications below
+// MinConfidence fall through to the next classifier. Category aggregates
+// per account cover AggregateWindow in buckets of AggregateBucket.
+type EnrichmentConfig struct {
+	Enabled         bool           `mapstructure:"enabled"`
+	MinConfidence   float64        `mapstructure:"min_confidence"`
+	AggregateWindow time.Duration  `mapstructure:"aggregate_window"`
+	AggregateBucket time.Duration  `mapstructure:"aggregate_bucket"`
+	Rules           []CategoryRule `mapstructure:"rules"`
+}
+
+// CategoryRule maps merchant descriptors matching a regular expression to a
+// normalized category
+type CategoryRule struct {
+	Name     string `mapstructure:"name"`
+	Pattern  string `mapstructure:"pattern"`
+	Category string `mapstructure:"category"`
+}
+
 // StorageConfig represents storage configuration
 type StorageConfig struct {
 	Type        string `mapstructure:"type"`
@@ -104,6 +125,14 @@ type StorageConfig struct {
 	Bucket      string `mapstructure:"bucket"`
 	Prefix      string `mapstructure:"prefix"`
 	Encryption  bool   `mapstructure:"encryption"`
+	WORM        WORMConfig `mapstructure:"worm"`
+}
+
+// WORMConfig represents write-once storage configuration backed by S3 Object Lock in compliance mode
+type WORMConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	DefaultRetention time.Duration `mapstructure:"default_retention"`
+	LockedPrefixes   []string      `mapstructure:"locked_prefixes"`
 }
 
 // MonitoringConfig represents monitoring configuration
@@ -159,8 +188,16 @@ func Load() (Config, error) {
 	viper.SetDefault("etl.quality.consistency_threshold", 0.98)
 	viper.SetDefault("etl.quality.freshness_threshold", "1h")
 
+	viper.SetDefault("etl.enrichment.enabled", true)
+	viper.SetDefault("etl.enrichment.min_confidence", 0.5)
+	viper.SetDefault("etl.enrichment.aggregate_window", "720h") // 30 days
+	viper.SetDefault("etl.enrichment.aggregate_bucket", "1h")
+
 	viper.SetDefault("storage.type", "s3")
 	viper.SetDefault("storage.encryption", true)
+	viper.SetDefault("storage.worm.enabled", false)
+	viper.SetDefault("storage.worm.default_retention", "61320h") // 7 years
+	viper.SetDefault("storage.worm.locked_prefixes", []string{"audit/", "evidence/"})
 
 	viper.SetDefault("monitoring.metrics_enabled", true)
 	viper.SetDefault("monitoring.metrics_port", 9090)
@@ -242,6 +279,32 @@ func validateConfig(config Config) error {
 		return fmt.Errorf("consistency threshold must be between 0 and 1")
 	}
 
+	// Validate enrichment configuration
+	if config.ETL.Enrichment.Enabled {
+		if config.ETL.Enrichment.MinConfidence < 0 || config.ETL.Enrichment.MinConfidence > 1 {
+			return fmt.Errorf("enrichment min confidence must be between 0 and 1")
+		}
+
+		if config.ETL.Enrichment.AggregateWindow <= 0 || config.ETL.Enrichment.AggregateBucket <= 0 {
+			return fmt.Errorf("enrichment aggregate window and bucket must be positive")
+		}
+
+		if config.ETL.Enrichment.AggregateBucket > config.ETL.Enrichment.AggregateWindow {
+			return fmt.Errorf("enrichment aggregate bucket must not exceed the window")
+		}
+	}
+
+	// Validate WORM storage configuration
+	if config.Storage.WORM.Enabled {
+		if config.Storage.Type != "s3" {
+			return fmt.Errorf("WORM storage requires the s3 storage type, got %s", config.Storage.Type)
+		}
+
+		if config.Storage.WORM.DefaultRetention <= 0 {
+			return fmt.Errorf("WORM default retention must be positive")
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,173 @@
+package enrichment
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// CategoryAggregate is an account's activity in one category over the
+// aggregation window
+type CategoryAggregate struct {
+	Category    string    `json:"category"`
+	Count       int       `json:"count"`
+	TotalAmount float64   `json:"total_amount"`
+	MaxAmount   float64   `json:"max_amount"`
+	Window      string    `json:"window"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+// categoryBucket holds one bucket of an account's activity in a category
+type categoryBucket struct {
+	start     time.Time
+	count     int
+	total     float64
+	max       float64
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// Aggregator keeps per-account, per-category activity over a sliding window
+// made of fixed buckets, so memory stays bounded by the window rather than
+// by the transaction count
+type Aggregator struct {
+	window     time.Duration
+	bucketSize time.Duration
+
+	mu       sync.Mutex
+	accounts map[string]map[string][]*categoryBucket // account -> category -> buckets, oldest first
+}
+
+// NewAggregator creates an aggregator over the given window
+func NewAggregator(window, bucketSize time.Duration) *Aggregator {
+	if bucketSize <= 0 || bucketSize > window {
+		bucketSize = window
+	}
+	return &Aggregator{
+		window:     window,
+		bucketSize: bucketSize,
+		accounts:   make(map[string]map[string][]*categoryBucket),
+	}
+}
+
+// Observe records a transaction and returns the account's aggregates in
+// every category as of the transaction
+func (a *Aggregator) Observe(accountID, category string, amount float64, at time.Time) map[string]*CategoryAggregate {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	categories, ok := a.accounts[accountID]
+	if !ok {
+		categories = make(map[string][]*categoryBucket)
+		a.accounts[accountID] = categories
+	}
+
+	start := at.Truncate(a.bucketSize)
+	buckets := categories[category]
+
+	var bucket *categoryBucket
+	for _, existing := range buckets {
+		if existing.start.Equal(start) {
+			bucket = existing
+			break
+		}
+	}
+	if bucket == nil {
+		bucket = &categoryBucket{start: start, firstSeen: at, lastSeen: at}
+		buckets = append(buckets, bucket)
+		sort.Slice(buckets, func(i, j int) bool { return buckets[i].start.Before(buckets[j].start) })
+		categories[category] = buckets
+	}
+
+	bucket.count++
+	bucket.total += amount
+	if amount > bucket.max {
+		bucket.max = amount
+	}
+	if at.Before(bucket.firstSeen) {
+		bucket.firstSeen = at
+	}
+	if at.After(bucket.lastSeen) {
+		bucket.lastSeen = at
+	}
+
+	return a.aggregatesLocked(accountID, at)
+}
+
+// Aggregates returns an account's aggregates in every category with
+// activity in the window ending at the given time
+func (a *Aggregator) Aggregates(accountID string, at time.Time) map[string]*CategoryAggregate {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.aggregatesLocked(accountID, at)
+}
+
+// Prune drops buckets that left the window, and accounts left without any
+func (a *Aggregator) Prune(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cutoff := a.cutoff(now)
+	for accountID, categories := range a.accounts {
+		for category, buckets := range categories {
+			kept := buckets[:0]
+			for _, bucket := range buckets {
+				if !bucket.start.Before(cutoff) {
+					kept = append(kept, bucket)
+				}
+			}
+			if len(kept) == 0 {
+				delete(categories, category)
+			} else {
+				categories[category] = kept
+			}
+		}
+		if len(categories) == 0 {
+			delete(a.accounts, accountID)
+		}
+	}
+}
+
+func (a *Aggregator) aggregatesLocked(accountID string, at time.Time) map[string]*CategoryAggregate {
+	cutoff := a.cutoff(at)
+	aggregates := make(map[string]*CategoryAggregate)
+
+	for category, buckets := range a.accounts[accountID] {
+		var aggregate *CategoryAggregate
+		for _, bucket := range buckets {
+			if bucket.start.Before(cutoff) || bucket.start.After(at) {
+				continue
+			}
+			if aggregate == nil {
+				aggregate = &CategoryAggregate{
+					Category:  category,
+					Window:    a.window.String(),
+					FirstSeen: bucket.firstSeen,
+				}
+			}
+			aggregate.Count += bucket.count
+			aggregate.TotalAmount += bucket.total
+			if bucket.max > aggregate.MaxAmount {
+				aggregate.MaxAmount = bucket.max
+			}
+			if bucket.firstSeen.Before(aggregate.FirstSeen) {
+				aggregate.FirstSeen = bucket.firstSeen
+			}
+			if bucket.lastSeen.After(aggregate.LastSeen) {
+				aggregate.LastSeen = bucket.lastSeen
+			}
+		}
+		if aggregate != nil {
+			aggregates[category] = aggregate
+		}
+	}
+
+	return aggregates
+}
+
+// cutoff is the start of the oldest bucket still inside the window ending
+// at the given time
+func (a *Aggregator) cutoff(at time.Time) time.Time {
+	return at.Add(-a.window).Truncate(a.bucketSize).Add(a.bucketSize)
+}
@@ -0,0 +1,318 @@
+package enrichment
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aegisshield/data-integration/internal/config"
+)
+
+// Normalized merchant categories
+const (
+	CategoryGambling          = "gambling"
+	CategoryCrypto            = "crypto"
+	CategoryMoneyTransfer     = "money_transfer"
+	CategoryCashWithdrawal    = "cash_withdrawal"
+	CategoryQuasiCash         = "quasi_cash"
+	CategoryFinancialServices = "financial_services"
+	CategoryPawnShop          = "pawn_shop"
+	CategoryJewelry           = "jewelry"
+	CategoryTravel            = "travel"
+	CategoryGroceries         = "groceries"
+	CategoryRestaurants       = "restaurants"
+	CategoryFuel              = "fuel"
+	CategoryUtilities         = "utilities"
+	CategoryDigitalGoods      = "digital_goods"
+	CategoryRetail            = "retail"
+	CategoryUnknown           = "unknown"
+)
+
+// Normalized transaction channels
+const (
+	ChannelPOS       = "pos"
+	ChannelEcommerce = "ecommerce"
+	ChannelATM       = "atm"
+	ChannelWire      = "wire"
+	ChannelACH       = "ach"
+	ChannelP2P       = "p2p"
+	ChannelBranch    = "branch"
+	ChannelUnknown   = "unknown"
+)
+
+// Categories lists every normalized merchant category
+var Categories = []string{
+	CategoryGambling, CategoryCrypto, CategoryMoneyTransfer, CategoryCashWithdrawal,
+	CategoryQuasiCash, CategoryFinancialServices, CategoryPawnShop, CategoryJewelry,
+	CategoryTravel, CategoryGroceries, CategoryRestaurants, CategoryFuel,
+	CategoryUtilities, CategoryDigitalGoods, CategoryRetail, CategoryUnknown,
+}
+
+// Channels lists every normalized transaction channel
+var Channels = []string{
+	ChannelPOS, ChannelEcommerce, ChannelATM, ChannelWire,
+	ChannelACH, ChannelP2P, ChannelBranch, ChannelUnknown,
+}
+
+// Transaction holds the fields of a raw transaction a classifier reads
+type Transaction struct {
+	Descriptor string // merchant name or payment description
+	MCC        string // merchant category code, when the source provides one
+	Channel    string // channel as reported by the source
+	Amount     float64
+	AccountID  string
+}
+
+// Classification is the normalized category and channel of a transaction
+// and the classifier that chose them
+type Classification struct {
+	Category    string  `json:"category"`
+	MCC         string  `json:"mcc,omitempty"`
+	Channel     string  `json:"channel"`
+	Classifier  string  `json:"classifier"`
+	Confidence  float64 `json:"confidence"`
+	MatchedRule string  `json:"matched_rule,omitempty"`
+}
+
+// Classifier maps a transaction to a normalized category and channel.
+// Classifiers that cannot place a transaction report false rather than
+// guessing, so the next classifier in a chain gets a chance.
+type Classifier interface {
+	Name() string
+	Classify(ctx context.Context, txn *Transaction) (*Classification, bool, error)
+}
+
+// Chain runs classifiers in order and keeps the first classification that
+// reaches the minimum confidence. Rules come first; a model added later only
+// sees what the rules could not place.
+type Chain struct {
+	classifiers   []Classifier
+	minConfidence float64
+}
+
+// NewChain creates a classifier chain
+func NewChain(minConfidence float64, classifiers ...Classifier) *Chain {
+	return &Chain{
+		classifiers:   classifiers,
+		minConfidence: minConfidence,
+	}
+}
+
+// Name implements Classifier
+func (c *Chain) Name() string {
+	return "chain"
+}
+
+// Classify implements Classifier. A transaction no classifier places is
+// classified as unknown with its channel still inferred.
+func (c *Chain) Classify(ctx context.Context, txn *Transaction) (*Classification, bool, error) {
+	for _, classifier := range c.classifiers {
+		classification, ok, err := classifier.Classify(ctx, txn)
+		if err != nil {
+			return nil, false, fmt.Errorf("classifier %s failed: %w", classifier.Name(), err)
+		}
+		if ok && classification.Confidence >= c.minConfidence {
+			return classification, true, nil
+		}
+	}
+
+	return &Classification{
+		Category:   CategoryUnknown,
+		MCC:        normalizeMCC(txn.MCC),
+		Channel:    InferChannel(txn, ""),
+		Classifier: c.Name(),
+	}, false, nil
+}
+
+// mccRange maps a range of merchant category codes to a category
+type mccRange struct {
+	from, to int
+	category string
+}
+
+// mccRanges follows ISO 18245 groupings for the categories rules care about
+var mccRanges = []mccRange{
+	{6011, 6011, CategoryCashWithdrawal},
+	{6010, 6010, CategoryCashWithdrawal},
+	{6051, 6051, CategoryQuasiCash},
+	{6540, 6540, CategoryQuasiCash},
+	{4829, 4829, CategoryMoneyTransfer},
+	{6012, 6012, CategoryFinancialServices},
+	{6211, 6211, CategoryFinancialServices},
+	{7995, 7995, CategoryGambling},
+	{7800, 7802, CategoryGambling},
+	{5933, 5933, CategoryPawnShop},
+	{5944, 5944, CategoryJewelry},
+	{5094, 5094, CategoryJewelry},
+	{3000, 3999, CategoryTravel},
+	{4511, 4511, CategoryTravel},
+	{4722, 4722, CategoryTravel},
+	{7011, 7011, CategoryTravel},
+	{5411, 5411, CategoryGroceries},
+	{5499, 5499, CategoryGroceries},
+	{5812, 5814, CategoryRestaurants},
+	{5541, 5542, CategoryFuel},
+	{4900, 4900, CategoryUtilities},
+	{5815, 5818, CategoryDigitalGoods},
+	{5200, 5999, CategoryRetail},
+}
+
+// atmPattern finds ATM in a descriptor without matching words containing it
+var atmPattern = regexp.MustCompile(`\batm\b`)
+
+// descriptorRule matches a merchant descriptor to a category
+type descriptorRule struct {
+	name     string
+	pattern  *regexp.Regexp
+	category string
+}
+
+// defaultDescriptorRules place well-known merchants whose sources do not
+// send merchant category codes
+var defaultDescriptorRules = []config.CategoryRule{
+	{Name: "crypto_exchanges", Pattern: `(?i)\b(coinbase|binance|kraken|bitstamp|gemini|crypto\.com|bitpay)\b`, Category: CategoryCrypto},
+	{Name: "gambling_operators", Pattern: `(?i)\b(casino|betfair|draftkings|fanduel|bet365|pokerstars|lottery|bingo)\b`, Category: CategoryGambling},
+	{Name: "money_transfer_operators", Pattern: `(?i)\b(western union|moneygram|wise|remitly|worldremit|xoom)\b`, Category: CategoryMoneyTransfer},
+	{Name: "prepaid_and_gift_cards", Pattern: `(?i)\b(gift ?card|prepaid|green dot|netspend)\b`, Category: CategoryQuasiCash},
+	{Name: "pawn_shops", Pattern: `(?i)\bpawn\b`, Category: CategoryPawnShop},
+	{Name: "atm_withdrawals", Pattern: `(?i)\b(atm|cash withdrawal)\b`, Category: CategoryCashWithdrawal},
+}
+
+// RuleClassifier classifies transactions by descriptor patterns, then by
+// merchant category code. Configured rules are tried before the built-in
+// ones, so deployments can override how a merchant is placed.
+type RuleClassifier struct {
+	rules []descriptorRule
+}
+
+// NewRuleClassifier compiles the configured descriptor rules ahead of the
+// built-in rules
+func NewRuleClassifier(rules []config.CategoryRule) (*RuleClassifier, error) {
+	classifier := &RuleClassifier{}
+	for _, rule := range append(append([]config.CategoryRule{}, rules...), defaultDescriptorRules...) {
+		if rule.Pattern == "" || rule.Category == "" {
+			return nil, fmt.Errorf("category rule %q needs a pattern and a category", rule.Name)
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for category rule %q: %w", rule.Name, err)
+		}
+		classifier.rules = append(classifier.rules, descriptorRule{
+			name:     rule.Name,
+			pattern:  pattern,
+			category: rule.Category,
+		})
+	}
+	return classifier, nil
+}
+
+// Name implements Classifier
+func (c *RuleClassifier) Name() string {
+	return "rules"
+}
+
+// Classify implements Classifier. Descriptor rules are trusted over a
+// merchant category code because acquirers often report a generic code.
+func (c *RuleClassifier) Classify(ctx context.Context, txn *Transaction) (*Classification, bool, error) {
+	mcc := normalizeMCC(txn.MCC)
+
+	for _, rule := range c.rules {
+		if txn.Descriptor != "" && rule.pattern.MatchString(txn.Descriptor) {
+			return &Classification{
+				Category:    rule.category,
+				MCC:         mcc,
+				Channel:     InferChannel(txn, rule.category),
+				Classifier:  c.Name(),
+				Confidence:  0.9,
+				MatchedRule: rule.name,
+			}, true, nil
+		}
+	}
+
+	if category, ok := categoryForMCC(mcc); ok {
+		return &Classification{
+			Category:    category,
+			MCC:         mcc,
+			Channel:     InferChannel(txn, category),
+			Classifier:  c.Name(),
+			Confidence:  0.8,
+			MatchedRule: "mcc:" + mcc,
+		}, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// InferChannel normalizes the channel a source reported, or infers it from
+// the descriptor and category when the source did not report one
+func InferChannel(txn *Transaction, category string) string {
+	switch strings.ToLower(strings.TrimSpace(txn.Channel)) {
+	case "pos", "card_present", "in_store", "contactless", "chip", "swipe":
+		return ChannelPOS
+	case "ecommerce", "e-commerce", "ecom", "online", "card_not_present", "cnp", "web", "mobile":
+		return ChannelEcommerce
+	case "atm":
+		return ChannelATM
+	case "wire", "swift", "fedwire", "chaps", "sepa_instant":
+		return ChannelWire
+	case "ach", "sepa", "bacs", "direct_debit":
+		return ChannelACH
+	case "p2p", "peer_to_peer":
+		return ChannelP2P
+	case "branch", "teller", "counter":
+		return ChannelBranch
+	}
+
+	descriptor := strings.ToLower(txn.Descriptor)
+	switch {
+	case category == CategoryCashWithdrawal || atmPattern.MatchString(descriptor):
+		return ChannelATM
+	case containsAny(descriptor, "wire", "swift", "fedwire"):
+		return ChannelWire
+	case containsAny(descriptor, "ach ", "ach-", "direct debit"):
+		return ChannelACH
+	case containsAny(descriptor, "venmo", "zelle", "cash app", "paypal *p2p"):
+		return ChannelP2P
+	case containsAny(descriptor, "www.", ".com", "online", "paypal *"):
+		return ChannelEcommerce
+	case category == CategoryDigitalGoods:
+		return ChannelEcommerce
+	}
+
+	return ChannelUnknown
+}
+
+func categoryForMCC(mcc string) (string, bool) {
+	code, err := strconv.Atoi(mcc)
+	if err != nil {
+		return "", false
+	}
+	for _, r := range mccRanges {
+		if code >= r.from && code <= r.to {
+			return r.category, true
+		}
+	}
+	return "", false
+}
+
+// normalizeMCC keeps four-digit merchant category codes, padding shorter
+// numeric codes with leading zeros
+func normalizeMCC(mcc string) string {
+	mcc = strings.TrimSpace(mcc)
+	code, err := strconv.Atoi(mcc)
+	if err != nil || code < 0 || code > 9999 {
+		return ""
+	}
+	return fmt.Sprintf("%04d", code)
+}
+
+func containsAny(value string, parts ...string) bool {
+	for _, part := range parts {
+		if strings.Contains(value, part) {
+			return true
+		}
+	}
+	return false
+}
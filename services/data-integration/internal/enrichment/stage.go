@@ -0,0 +1,179 @@
+package enrichment
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aegisshield/data-integration/internal/config"
+	"go.uber.org/zap"
+)
+
+// Fields written to the canonical record. Rules read the classification
+// from merchant_category and channel, and the account's activity per
+// category from category_aggregates, e.g.
+// event.category_aggregates.gambling.total_amount > 5000.
+const (
+	FieldClassification     = "classification"
+	FieldMerchantCategory   = "merchant_category"
+	FieldChannel            = "channel"
+	FieldSourceChannel      = "source_channel" // the channel as the source reported it
+	FieldCategoryAggregates = "category_aggregates"
+)
+
+// Raw record fields read for classification, first present wins
+var (
+	descriptorFields = []string{"merchant_descriptor", "merchant_name", "description", "narrative"}
+	mccFields        = []string{"mcc", "merchant_category_code"}
+	channelFields    = []string{"channel", "payment_channel"}
+	amountFields     = []string{"amount", "transaction_amount"}
+	accountFields    = []string{"account_id", "from_account", "source_account"}
+	timestampFields  = []string{"timestamp", "transaction_date", "created_at"}
+)
+
+// Stage classifies transaction records in the pipeline, storing the
+// classification on each record along with the account's category
+// aggregates
+type Stage struct {
+	classifier Classifier
+	aggregator *Aggregator
+	logger     *zap.Logger
+}
+
+// NewStage creates a classification stage with the configured rules ahead
+// of any further classifiers, such as a model
+func NewStage(config config.EnrichmentConfig, logger *zap.Logger, classifiers ...Classifier) (*Stage, error) {
+	rules, err := NewRuleClassifier(config.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stage{
+		classifier: NewChain(config.MinConfidence, append([]Classifier{rules}, classifiers...)...),
+		aggregator: NewAggregator(config.AggregateWindow, config.AggregateBucket),
+		logger:     logger,
+	}, nil
+}
+
+// Classify classifies a single transaction without recording it
+func (s *Stage) Classify(ctx context.Context, txn *Transaction) (*Classification, error) {
+	classification, _, err := s.classifier.Classify(ctx, txn)
+	return classification, err
+}
+
+// Aggregates returns an account's category aggregates as of now
+func (s *Stage) Aggregates(accountID string) map[string]*CategoryAggregate {
+	return s.aggregator.Aggregates(accountID, time.Now())
+}
+
+// EnrichRecords classifies the transaction records among the given records
+// in place and returns how many were classified. Records without a
+// descriptor or merchant category code are not transactions and are left
+// untouched.
+func (s *Stage) EnrichRecords(ctx context.Context, records []map[string]interface{}) (int, error) {
+	classified := 0
+	for _, record := range records {
+		txn, ok := transactionFromRecord(record)
+		if !ok {
+			continue
+		}
+
+		classification, placed, err := s.classifier.Classify(ctx, txn)
+		if err != nil {
+			return classified, err
+		}
+		if !placed {
+			s.logger.Debug("Transaction left unclassified",
+				zap.String("descriptor", txn.Descriptor),
+				zap.String("mcc", txn.MCC))
+		}
+
+		record[FieldClassification] = classification
+		record[FieldMerchantCategory] = classification.Category
+		if txn.Channel != "" && txn.Channel != classification.Channel {
+			record[FieldSourceChannel] = txn.Channel
+		}
+		record[FieldChannel] = classification.Channel
+
+		if txn.AccountID != "" {
+			at := recordTime(record)
+			record[FieldCategoryAggregates] = s.aggregator.Observe(txn.AccountID, classification.Category, txn.Amount, at)
+		}
+		classified++
+	}
+
+	return classified, nil
+}
+
+// Start prunes aggregates that left the window until the context is
+// cancelled
+func (s *Stage) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.aggregator.bucketSize)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.aggregator.Prune(now)
+		}
+	}
+}
+
+// transactionFromRecord reads the classification inputs from a raw record
+func transactionFromRecord(record map[string]interface{}) (*Transaction, bool) {
+	txn := &Transaction{
+		Descriptor: stringField(record, descriptorFields),
+		MCC:        stringField(record, mccFields),
+		Channel:    stringField(record, channelFields),
+		AccountID:  stringField(record, accountFields),
+	}
+	if txn.Descriptor == "" && txn.MCC == "" {
+		return nil, false
+	}
+
+	if amount, err := strconv.ParseFloat(stringField(record, amountFields), 64); err == nil {
+		txn.Amount = amount
+	}
+	return txn, true
+}
+
+// recordTime is the record's transaction time, or now when it has none
+func recordTime(record map[string]interface{}) time.Time {
+	for _, field := range timestampFields {
+		switch value := record[field].(type) {
+		case time.Time:
+			return value
+		case string:
+			if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+				return parsed
+			}
+		}
+	}
+	return time.Now()
+}
+
+func stringField(record map[string]interface{}, fields []string) string {
+	for _, field := range fields {
+		value, ok := record[field]
+		if !ok || value == nil {
+			continue
+		}
+		var text string
+		switch v := value.(type) {
+		case string:
+			text = v
+		case float64:
+			text = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			text = fmt.Sprint(v)
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			return text
+		}
+	}
+	return ""
+}
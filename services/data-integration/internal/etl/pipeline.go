@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/aegisshield/data-integration/internal/config"
+	"github.com/aegisshield/data-integration/internal/enrichment"
 	"github.com/aegisshield/data-integration/internal/lineage"
 	"github.com/aegisshield/data-integration/internal/quality"
 	"github.com/aegisshield/data-integration/internal/storage"
@@ -24,6 +25,7 @@ type Pipeline struct {
 	qualityChecker  *quality.Checker
 	lineageTracker  *lineage.Tracker
 	storageManager  *storage.Manager
+	enrichment      *enrichment.Stage
 	logger          *zap.Logger
 	jobQueue        chan *Job
 	workerPool      sync.WaitGroup
@@ -64,6 +66,7 @@ type JobMetrics struct {
 	RecordsProcessed int           `json:"records_processed"`
 	RecordsValid     int           `json:"records_valid"`
 	RecordsInvalid   int           `json:"records_invalid"`
+	RecordsClassified int          `json:"records_classified"`
 	ProcessingTime   time.Duration `json:"processing_time"`
 	ValidationTime   time.Duration `json:"validation_time"`
 	QualityScore     float64       `json:"quality_score"`
@@ -86,6 +89,7 @@ type ProcessingOptions struct {
 	SkipValidation     bool                   `json:"skip_validation"`
 	SkipQualityChecks  bool                   `json:"skip_quality_checks"`
 	SkipLineageTracking bool                  `json:"skip_lineage_tracking"`
+	SkipEnrichment     bool                   `json:"skip_enrichment"`
 	CustomTransforms   []TransformFunction    `json:"-"`
 	Metadata           map[string]interface{} `json:"metadata,omitempty"`
 }
@@ -115,6 +119,12 @@ func NewPipeline(
 	}
 }
 
+// SetEnrichment adds the transaction classification stage, run on valid
+// records before any custom transforms
+func (p *Pipeline) SetEnrichment(stage *enrichment.Stage) {
+	p.enrichment = stage
+}
+
 // Start starts the ETL pipeline workers
 func (p *Pipeline) Start(ctx context.Context) error {
 	p.logger.Info("Starting ETL pipeline",
@@ -365,6 +375,20 @@ func (p *Pipeline) processJobData(ctx context.Context, job *Job, options *Proces
 			zap.Int("invalid_records", len(invalidRecords)))
 	}
 
+	// Classify transactions by merchant category and channel
+	if !options.SkipEnrichment && p.enrichment != nil {
+		classified, err := p.enrichment.EnrichRecords(ctx, records)
+		if err != nil {
+			return nil, fmt.Errorf("enrichment failed: %w", err)
+		}
+
+		job.Metrics.RecordsClassified = classified
+
+		p.logger.Info("Transaction classification completed",
+			zap.String("job_id", job.ID),
+			zap.Int("classified_records", classified))
+	}
+
 	// Apply custom transforms
 	for _, transform := range options.CustomTransforms {
 		transformedData, err := transform(ctx, records)
@@ -550,6 +574,7 @@ func (p *Pipeline) ProcessBatch(ctx context.Context, batchData []interface{}, op
 		totalMetrics.RecordsProcessed += chunkMetrics.RecordsProcessed
 		totalMetrics.RecordsValid += chunkMetrics.RecordsValid
 		totalMetrics.RecordsInvalid += chunkMetrics.RecordsInvalid
+		totalMetrics.RecordsClassified += chunkMetrics.RecordsClassified
 		totalMetrics.ProcessingTime += chunkMetrics.ProcessingTime
 		totalMetrics.ValidationTime += chunkMetrics.ValidationTime
 	}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/aegisshield/data-integration/internal/enrichment"
+	"github.com/gorilla/mux"
+)
+
+// errEnrichmentDisabled is returned when the classification stage is off
+var errEnrichmentDisabled = errors.New("transaction enrichment is disabled")
+
+// classifyRequest is a raw transaction to classify
+type classifyRequest struct {
+	Descriptor string  `json:"descriptor"`
+	MCC        string  `json:"mcc"`
+	Channel    string  `json:"channel"`
+	Amount     float64 `json:"amount"`
+	AccountID  string  `json:"account_id"`
+}
+
+// SetEnrichment enables the transaction enrichment endpoints
+func (h *Handler) SetEnrichment(stage *enrichment.Stage) {
+	h.enrichment = stage
+}
+
+// ClassifyTransaction classifies a transaction without recording it in the
+// account's aggregates
+func (h *Handler) ClassifyTransaction(w http.ResponseWriter, r *http.Request) {
+	if h.enrichment == nil {
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, "Enrichment not available", errEnrichmentDisabled)
+		return
+	}
+
+	var req classifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if req.Descriptor == "" && req.MCC == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", errors.New("descriptor or mcc is required"))
+		return
+	}
+
+	classification, err := h.enrichment.Classify(r.Context(), &enrichment.Transaction{
+		Descriptor: req.Descriptor,
+		MCC:        req.MCC,
+		Channel:    req.Channel,
+		Amount:     req.Amount,
+		AccountID:  req.AccountID,
+	})
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to classify transaction", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, classification)
+}
+
+// GetCategoryAggregates returns an account's activity per merchant category
+// over the aggregation window
+func (h *Handler) GetCategoryAggregates(w http.ResponseWriter, r *http.Request) {
+	if h.enrichment == nil {
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, "Enrichment not available", errEnrichmentDisabled)
+		return
+	}
+
+	accountID := mux.Vars(r)["accountId"]
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"account_id": accountID,
+		"aggregates": h.enrichment.Aggregates(accountID),
+	})
+}
+
+// ListCategories returns the normalized categories and channels records are
+// classified into
+func (h *Handler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	categories := append([]string{}, enrichment.Categories...)
+	sort.Strings(categories)
+	channels := append([]string{}, enrichment.Channels...)
+	sort.Strings(channels)
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"categories": categories,
+		"channels":   channels,
+	})
+}
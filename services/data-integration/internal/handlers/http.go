@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/aegisshield/data-integration/internal/config"
+	"github.com/aegisshield/data-integration/internal/enrichment"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
@@ -20,6 +21,7 @@ type Handler struct {
 	qualityChecker  interface{} // Quality checker interface
 	lineageTracker  interface{} // Lineage tracker interface
 	storageManager  interface{} // Storage manager interface
+	enrichment      *enrichment.Stage
 	config          config.Config
 	logger          *zap.Logger
 }
@@ -104,9 +106,17 @@ func (h *Handler) SetupRoutes() *mux.Router {
 	storage.HandleFunc("/list", h.ListStorageObjects).Methods("GET")
 	storage.HandleFunc("/delete", h.DeleteStorageObject).Methods("DELETE")
 	storage.HandleFunc("/metadata/{path:.*}", h.GetStorageMetadata).Methods("GET")
+	storage.HandleFunc("/lock/{path:.*}", h.GetRetentionLock).Methods("GET")
+	storage.HandleFunc("/lock/{path:.*}", h.ExtendRetentionLock).Methods("PUT")
 	storage.HandleFunc("/archive", h.ArchiveData).Methods("POST")
 	storage.HandleFunc("/restore", h.RestoreData).Methods("POST")
 
+	// Transaction enrichment endpoints
+	enrichment := router.PathPrefix("/api/v1/enrichment").Subrouter()
+	enrichment.HandleFunc("/classify", h.ClassifyTransaction).Methods("POST")
+	enrichment.HandleFunc("/categories", h.ListCategories).Methods("GET")
+	enrichment.HandleFunc("/aggregates/{accountId}", h.GetCategoryAggregates).Methods("GET")
+
 	// Metrics and monitoring
 	router.HandleFunc("/metrics", h.GetSystemMetrics).Methods("GET")
 
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -11,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aegisshield/data-integration/internal/storage"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
@@ -52,6 +54,13 @@ func (h *Handler) UploadData(w http.ResponseWriter, r *http.Request) {
 	metadata["content_type"] = header.Header.Get("Content-Type")
 	metadata["uploaded_at"] = time.Now().UTC()
 
+	if manager, ok := h.storageBackend(); ok {
+		if err := manager.Store(r.Context(), strings.TrimPrefix(path, "/"), file, metadata); err != nil {
+			h.writeStorageError(w, "Failed to store file", err)
+			return
+		}
+	}
+
 	// Mock upload response
 	uploadID := fmt.Sprintf("upload_%d", time.Now().Unix())
 	
@@ -162,6 +171,13 @@ func (h *Handler) DeleteStorageObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if manager, ok := h.storageBackend(); ok {
+		if err := manager.Delete(r.Context(), strings.TrimPrefix(deleteRequest.Path, "/")); err != nil {
+			h.writeStorageError(w, "Failed to delete object", err)
+			return
+		}
+	}
+
 	response := map[string]interface{}{
 		"path":       deleteRequest.Path,
 		"deleted_at": time.Now().UTC(),
@@ -212,6 +228,68 @@ func (h *Handler) GetStorageMetadata(w http.ResponseWriter, r *http.Request) {
 	h.writeJSONResponse(w, http.StatusOK, metadata)
 }
 
+func (h *Handler) GetRetentionLock(w http.ResponseWriter, r *http.Request) {
+	path := mux.Vars(r)["path"]
+	if path == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Path parameter is required", nil)
+		return
+	}
+
+	manager, ok := h.storageBackend()
+	if !ok {
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, "Storage manager is not configured", nil)
+		return
+	}
+
+	status, err := manager.VerifyLock(r.Context(), strings.TrimPrefix(path, "/"))
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to verify retention lock", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, status)
+}
+
+func (h *Handler) ExtendRetentionLock(w http.ResponseWriter, r *http.Request) {
+	path := mux.Vars(r)["path"]
+	if path == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Path parameter is required", nil)
+		return
+	}
+
+	var extendRequest struct {
+		RetainUntil time.Time `json:"retain_until"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&extendRequest); err != nil || extendRequest.RetainUntil.IsZero() {
+		h.writeErrorResponse(w, http.StatusBadRequest, "retain_until is required", err)
+		return
+	}
+
+	manager, ok := h.storageBackend()
+	if !ok {
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, "Storage manager is not configured", nil)
+		return
+	}
+
+	key := strings.TrimPrefix(path, "/")
+	if err := manager.ExtendRetention(r.Context(), key, extendRequest.RetainUntil); err != nil {
+		h.writeStorageError(w, "Failed to extend retention lock", err)
+		return
+	}
+
+	status, err := manager.VerifyLock(r.Context(), key)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to verify retention lock", err)
+		return
+	}
+
+	h.logger.Info("Retention lock extended",
+		zap.String("path", path),
+		zap.Time("retain_until", extendRequest.RetainUntil))
+	h.writeJSONResponse(w, http.StatusOK, status)
+}
+
 func (h *Handler) ArchiveData(w http.ResponseWriter, r *http.Request) {
 	var archiveRequest struct {
 		Paths       []string               `json:"paths"`
@@ -410,6 +488,21 @@ func (h *Handler) processUploadedFile(file multipart.File, header *multipart.Fil
 }
 
 // Helper method for path validation
+func (h *Handler) storageBackend() (*storage.Manager, bool) {
+	manager, ok := h.storageManager.(*storage.Manager)
+	return manager, ok && manager != nil
+}
+
+// writeStorageError maps retention lock violations to 409 Conflict
+func (h *Handler) writeStorageError(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, storage.ErrObjectLocked), errors.Is(err, storage.ErrRetentionShortened):
+		h.writeErrorResponse(w, http.StatusConflict, message, err)
+	default:
+		h.writeErrorResponse(w, http.StatusInternalServerError, message, err)
+	}
+}
+
 func (h *Handler) validateStoragePath(path string) error {
 	// Basic path validation
 	if path == "" {
@@ -68,11 +68,22 @@ func NewManager(config config.StorageConfig, logger *zap.Logger) (*Manager, erro
 		return nil, fmt.Errorf("failed to create storage client: %w", err)
 	}
 
-	return &Manager{
+	manager := &Manager{
 		config: config,
 		logger: logger,
 		client: client,
-	}, nil
+	}
+
+	// Locked prefixes are matched against full keys, so apply the storage prefix once here
+	if config.WORM.Enabled {
+		lockedPrefixes := make([]string, len(config.WORM.LockedPrefixes))
+		for i, prefix := range config.WORM.LockedPrefixes {
+			lockedPrefixes[i] = manager.buildKey(prefix)
+		}
+		manager.client = NewWORMClient(client, config.WORM.DefaultRetention, lockedPrefixes, logger)
+	}
+
+	return manager, nil
 }
 
 // Store stores data with the given key and metadata
@@ -214,6 +225,52 @@ func (m *Manager) Copy(ctx context.Context, sourceKey, targetKey string) error {
 	return m.Store(ctx, targetKey, data, metadata)
 }
 
+// VerifyWORM confirms the storage backend enforces Object Lock when WORM storage is enabled
+func (m *Manager) VerifyWORM(ctx context.Context) error {
+	worm, ok := m.client.(*WORMClient)
+	if !ok {
+		return nil
+	}
+	return worm.VerifyBackend(ctx)
+}
+
+// VerifyLock reports the retention lock on a key and whether our APIs refuse to modify it
+func (m *Manager) VerifyLock(ctx context.Context, key string) (*LockStatus, error) {
+	fullKey := m.buildKey(key)
+
+	worm, ok := m.client.(*WORMClient)
+	if !ok {
+		exists, err := m.client.Exists(ctx, fullKey)
+		if err != nil {
+			return nil, err
+		}
+		return &LockStatus{Key: key, Exists: exists, VerifiedAt: time.Now().UTC()}, nil
+	}
+
+	status, err := worm.Verify(ctx, fullKey)
+	if err != nil {
+		return nil, err
+	}
+	status.Key = key
+	return status, nil
+}
+
+// ExtendRetention extends the retention lock on a key; locks can never be shortened
+func (m *Manager) ExtendRetention(ctx context.Context, key string, retainUntil time.Time) error {
+	worm, ok := m.client.(*WORMClient)
+	if !ok {
+		return fmt.Errorf("WORM storage is not enabled")
+	}
+
+	fullKey := m.buildKey(key)
+
+	m.logger.Info("Extending retention lock",
+		zap.String("key", fullKey),
+		zap.Time("retain_until", retainUntil))
+
+	return worm.ExtendRetention(ctx, fullKey, retainUntil)
+}
+
 // Helper methods
 
 func (m *Manager) buildKey(key string) string {
@@ -343,6 +400,38 @@ func (c *S3Client) GetMetadata(ctx context.Context, key string) (map[string]inte
 	return map[string]interface{}{}, nil
 }
 
+func (c *S3Client) PutLocked(ctx context.Context, key string, data io.Reader, metadata map[string]interface{}, lock RetentionLock) error {
+	c.logger.Debug("S3 put with object lock",
+		zap.String("key", key),
+		zap.String("mode", lock.Mode),
+		zap.Time("retain_until", lock.RetainUntil))
+	// Implementation would use AWS SDK PutObject with ObjectLockMode and ObjectLockRetainUntilDate
+	return nil
+}
+
+func (c *S3Client) GetRetention(ctx context.Context, key string) (*RetentionLock, error) {
+	c.logger.Debug("S3 get object retention", zap.String("key", key))
+	// Implementation would use AWS SDK GetObjectRetention
+	return nil, nil
+}
+
+func (c *S3Client) ExtendRetention(ctx context.Context, key string, retainUntil time.Time) error {
+	c.logger.Debug("S3 put object retention",
+		zap.String("key", key),
+		zap.Time("retain_until", retainUntil))
+	// Implementation would use AWS SDK PutObjectRetention in COMPLIANCE mode
+	return nil
+}
+
+func (c *S3Client) VerifyObjectLock(ctx context.Context) error {
+	if c.config.Bucket == "" {
+		return fmt.Errorf("bucket is required for Object Lock")
+	}
+	c.logger.Debug("S3 get object lock configuration", zap.String("bucket", c.config.Bucket))
+	// Implementation would use AWS SDK GetObjectLockConfiguration and require ObjectLockEnabled
+	return nil
+}
+
 // GCS storage client implementation (placeholder)
 type GCSClient struct {
 	config config.StorageConfig
@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Object Lock metadata keys and modes
+const (
+	ObjectLockModeCompliance = "COMPLIANCE"
+
+	MetadataObjectLockMode        = "object_lock_mode"
+	MetadataObjectLockRetainUntil = "object_lock_retain_until"
+)
+
+var (
+	// ErrObjectLocked is returned when a write or delete targets an object under an active retention lock
+	ErrObjectLocked = errors.New("object is under an active retention lock")
+	// ErrRetentionShortened is returned when a retention change would release an object earlier
+	ErrRetentionShortened = errors.New("retention lock can only be extended")
+)
+
+// RetentionLock describes the Object Lock retention applied to a stored object
+type RetentionLock struct {
+	Mode        string    `json:"mode"`
+	RetainUntil time.Time `json:"retain_until"`
+}
+
+// Active reports whether the lock still protects the object at the given time
+func (l *RetentionLock) Active(now time.Time) bool {
+	return l != nil && l.Mode != "" && now.Before(l.RetainUntil)
+}
+
+// LockStatus reports the retention state of a key and which operations our APIs will refuse
+type LockStatus struct {
+	Key             string     `json:"key"`
+	Exists          bool       `json:"exists"`
+	Protected       bool       `json:"protected"`
+	Locked          bool       `json:"locked"`
+	Mode            string     `json:"mode,omitempty"`
+	RetainUntil     *time.Time `json:"retain_until,omitempty"`
+	OverwriteDenied bool       `json:"overwrite_denied"`
+	DeleteDenied    bool       `json:"delete_denied"`
+	VerifiedAt      time.Time  `json:"verified_at"`
+}
+
+// ObjectLocker is implemented by storage clients that apply Object Lock retention natively
+type ObjectLocker interface {
+	PutLocked(ctx context.Context, key string, data io.Reader, metadata map[string]interface{}, lock RetentionLock) error
+	GetRetention(ctx context.Context, key string) (*RetentionLock, error)
+	ExtendRetention(ctx context.Context, key string, retainUntil time.Time) error
+	VerifyObjectLock(ctx context.Context) error
+}
+
+// WORMClient wraps a storage client and enforces write-once semantics on locked prefixes
+type WORMClient struct {
+	client           StorageClient
+	defaultRetention time.Duration
+	lockedPrefixes   []string
+	logger           *zap.Logger
+	now              func() time.Time
+}
+
+// NewWORMClient creates a new write-once storage client. When no prefixes are
+// given every key is protected.
+func NewWORMClient(client StorageClient, defaultRetention time.Duration, lockedPrefixes []string, logger *zap.Logger) *WORMClient {
+	return &WORMClient{
+		client:           client,
+		defaultRetention: defaultRetention,
+		lockedPrefixes:   lockedPrefixes,
+		logger:           logger,
+		now:              time.Now,
+	}
+}
+
+// Protected reports whether the key falls under a locked prefix
+func (c *WORMClient) Protected(key string) bool {
+	if len(c.lockedPrefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range c.lockedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *WORMClient) Put(ctx context.Context, key string, data io.Reader, metadata map[string]interface{}) error {
+	if !c.Protected(key) {
+		return c.client.Put(ctx, key, data, metadata)
+	}
+
+	current, err := c.Retention(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to check retention for %s: %w", key, err)
+	}
+	if current.Active(c.now()) {
+		c.logger.Warn("Rejected overwrite of locked object",
+			zap.String("key", key),
+			zap.Time("retain_until", current.RetainUntil))
+		return fmt.Errorf("cannot overwrite %s: %w", key, ErrObjectLocked)
+	}
+
+	lock := RetentionLock{
+		Mode:        ObjectLockModeCompliance,
+		RetainUntil: c.now().Add(c.defaultRetention).UTC(),
+	}
+
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	metadata[MetadataObjectLockMode] = lock.Mode
+	metadata[MetadataObjectLockRetainUntil] = lock.RetainUntil.Format(time.RFC3339)
+
+	c.logger.Info("Storing object under retention lock",
+		zap.String("key", key),
+		zap.String("mode", lock.Mode),
+		zap.Time("retain_until", lock.RetainUntil))
+
+	if locker, ok := c.client.(ObjectLocker); ok {
+		return locker.PutLocked(ctx, key, data, metadata, lock)
+	}
+	return c.client.Put(ctx, key, data, metadata)
+}
+
+func (c *WORMClient) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return c.client.Get(ctx, key)
+}
+
+func (c *WORMClient) Delete(ctx context.Context, key string) error {
+	if c.Protected(key) {
+		current, err := c.Retention(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to check retention for %s: %w", key, err)
+		}
+		if current.Active(c.now()) {
+			c.logger.Warn("Rejected delete of locked object",
+				zap.String("key", key),
+				zap.Time("retain_until", current.RetainUntil))
+			return fmt.Errorf("cannot delete %s: %w", key, ErrObjectLocked)
+		}
+	}
+
+	return c.client.Delete(ctx, key)
+}
+
+func (c *WORMClient) List(ctx context.Context, prefix string) ([]string, error) {
+	return c.client.List(ctx, prefix)
+}
+
+func (c *WORMClient) Exists(ctx context.Context, key string) (bool, error) {
+	return c.client.Exists(ctx, key)
+}
+
+func (c *WORMClient) GetMetadata(ctx context.Context, key string) (map[string]interface{}, error) {
+	return c.client.GetMetadata(ctx, key)
+}
+
+// Retention returns the retention lock on a key, or nil when the object is missing or unlocked
+func (c *WORMClient) Retention(ctx context.Context, key string) (*RetentionLock, error) {
+	if locker, ok := c.client.(ObjectLocker); ok {
+		return locker.GetRetention(ctx, key)
+	}
+
+	exists, err := c.client.Exists(ctx, key)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	metadata, err := c.client.GetMetadata(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return retentionFromMetadata(metadata)
+}
+
+// ExtendRetention moves the retain-until date of a locked object further out
+func (c *WORMClient) ExtendRetention(ctx context.Context, key string, retainUntil time.Time) error {
+	current, err := c.Retention(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to check retention for %s: %w", key, err)
+	}
+	if current != nil && retainUntil.Before(current.RetainUntil) {
+		return fmt.Errorf("retain until %s is before %s: %w",
+			retainUntil.Format(time.RFC3339), current.RetainUntil.Format(time.RFC3339), ErrRetentionShortened)
+	}
+
+	locker, ok := c.client.(ObjectLocker)
+	if !ok {
+		return fmt.Errorf("storage backend does not support retention updates")
+	}
+	return locker.ExtendRetention(ctx, key, retainUntil.UTC())
+}
+
+// Verify reports the retention state of a key and whether overwrites and deletes are refused
+func (c *WORMClient) Verify(ctx context.Context, key string) (*LockStatus, error) {
+	now := c.now()
+	status := &LockStatus{
+		Key:        key,
+		Protected:  c.Protected(key),
+		VerifiedAt: now.UTC(),
+	}
+
+	exists, err := c.client.Exists(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	status.Exists = exists
+
+	lock, err := c.Retention(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if lock != nil {
+		status.Mode = lock.Mode
+		retainUntil := lock.RetainUntil
+		status.RetainUntil = &retainUntil
+	}
+
+	// Both checks mirror the guards in Put and Delete
+	status.Locked = status.Protected && lock.Active(now)
+	status.OverwriteDenied = status.Locked
+	status.DeleteDenied = status.Locked
+
+	return status, nil
+}
+
+// VerifyBackend confirms the underlying bucket enforces Object Lock
+func (c *WORMClient) VerifyBackend(ctx context.Context) error {
+	locker, ok := c.client.(ObjectLocker)
+	if !ok {
+		return fmt.Errorf("storage backend does not support Object Lock")
+	}
+	return locker.VerifyObjectLock(ctx)
+}
+
+func retentionFromMetadata(metadata map[string]interface{}) (*RetentionLock, error) {
+	mode, _ := metadata[MetadataObjectLockMode].(string)
+	if mode == "" {
+		return nil, nil
+	}
+
+	var retainUntil time.Time
+	switch v := metadata[MetadataObjectLockRetainUntil].(type) {
+	case time.Time:
+		retainUntil = v
+	case string:
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retain until date %q: %w", v, err)
+		}
+		retainUntil = parsed
+	default:
+		return nil, fmt.Errorf("missing retain until date for locked object")
+	}
+
+	return &RetentionLock{Mode: mode, RetainUntil: retainUntil}, nil
+}
@@ -0,0 +1,179 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aegisshield/data-integration/internal/config"
+	"github.com/aegisshield/data-integration/internal/enrichment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeModelClassifier places every transaction it is asked about with a
+// fixed confidence, standing in for a model behind the rules
+type fakeModelClassifier struct {
+	confidence float64
+	calls      int
+}
+
+func (c *fakeModelClassifier) Name() string {
+	return "model"
+}
+
+func (c *fakeModelClassifier) Classify(ctx context.Context, txn *enrichment.Transaction) (*enrichment.Classification, bool, error) {
+	c.calls++
+	return &enrichment.Classification{
+		Category:   enrichment.CategoryDigitalGoods,
+		Channel:    enrichment.InferChannel(txn, enrichment.CategoryDigitalGoods),
+		Classifier: c.Name(),
+		Confidence: c.confidence,
+	}, true, nil
+}
+
+func enrichmentConfig() config.EnrichmentConfig {
+	return config.EnrichmentConfig{
+		Enabled:         true,
+		MinConfidence:   0.5,
+		AggregateWindow: 24 * time.Hour,
+		AggregateBucket: time.Hour,
+	}
+}
+
+func TestRuleClassifier(t *testing.T) {
+	ctx := context.Background()
+
+	classifier, err := enrichment.NewRuleClassifier([]config.CategoryRule{
+		{Name: "local_casino", Pattern: `(?i)lucky star`, Category: enrichment.CategoryGambling},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		txn      enrichment.Transaction
+		category string
+		channel  string
+		rule     string
+	}{
+		{"configured rule", enrichment.Transaction{Descriptor: "LUCKY STAR #12"}, enrichment.CategoryGambling, enrichment.ChannelUnknown, "local_casino"},
+		{"built-in rule", enrichment.Transaction{Descriptor: "COINBASE.COM 8889087930"}, enrichment.CategoryCrypto, enrichment.ChannelEcommerce, "crypto_exchanges"},
+		{"descriptor over generic code", enrichment.Transaction{Descriptor: "WESTERN UNION 123", MCC: "5999"}, enrichment.CategoryMoneyTransfer, enrichment.ChannelUnknown, "money_transfer_operators"},
+		{"merchant category code", enrichment.Transaction{Descriptor: "SHELL OIL 5744", MCC: "5541", Channel: "contactless"}, enrichment.CategoryFuel, enrichment.ChannelPOS, "mcc:5541"},
+		{"cash withdrawal code", enrichment.Transaction{MCC: "6011"}, enrichment.CategoryCashWithdrawal, enrichment.ChannelATM, "mcc:6011"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classification, ok, err := classifier.Classify(ctx, &tt.txn)
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, tt.category, classification.Category)
+			assert.Equal(t, tt.channel, classification.Channel)
+			assert.Equal(t, tt.rule, classification.MatchedRule)
+		})
+	}
+
+	t.Run("unplaced transactions are left to the next classifier", func(t *testing.T) {
+		_, ok, err := classifier.Classify(ctx, &enrichment.Transaction{Descriptor: "ACME WIDGETS", MCC: "0742"})
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid rules are rejected", func(t *testing.T) {
+		_, err := enrichment.NewRuleClassifier([]config.CategoryRule{{Name: "broken", Pattern: "(", Category: "x"}})
+		assert.Error(t, err)
+
+		_, err = enrichment.NewRuleClassifier([]config.CategoryRule{{Name: "empty", Pattern: "x"}})
+		assert.Error(t, err)
+	})
+}
+
+func TestClassifierChain(t *testing.T) {
+	ctx := context.Background()
+	rules, err := enrichment.NewRuleClassifier(nil)
+	require.NoError(t, err)
+
+	model := &fakeModelClassifier{confidence: 0.7}
+	chain := enrichment.NewChain(0.5, rules, model)
+
+	classification, ok, err := chain.Classify(ctx, &enrichment.Transaction{Descriptor: "BINANCE"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "rules", classification.Classifier)
+	assert.Zero(t, model.calls, "the model only sees what the rules cannot place")
+
+	classification, ok, err = chain.Classify(ctx, &enrichment.Transaction{Descriptor: "STEAMGAMES.COM"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "model", classification.Classifier)
+	assert.Equal(t, enrichment.CategoryDigitalGoods, classification.Category)
+
+	t.Run("low confidence falls back to unknown", func(t *testing.T) {
+		chain := enrichment.NewChain(0.5, rules, &fakeModelClassifier{confidence: 0.3})
+
+		classification, ok, err := chain.Classify(ctx, &enrichment.Transaction{Descriptor: "ZELLE TO J SMITH"})
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, enrichment.CategoryUnknown, classification.Category)
+		assert.Equal(t, enrichment.ChannelP2P, classification.Channel, "the channel is still inferred")
+	})
+}
+
+func TestEnrichmentStage(t *testing.T) {
+	ctx := context.Background()
+	stage, err := enrichment.NewStage(enrichmentConfig(), zap.NewNop())
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	records := []map[string]interface{}{
+		{"account_id": "acc-1", "merchant_name": "BET365", "amount": 1200.0, "channel": "online", "timestamp": now.Add(-2 * time.Hour).Format(time.RFC3339)},
+		{"account_id": "acc-1", "merchant_name": "DRAFTKINGS", "amount": "800", "timestamp": now.Add(-time.Hour).Format(time.RFC3339)},
+		{"account_id": "acc-1", "mcc": 5411, "amount": 50.0, "timestamp": now.Format(time.RFC3339)},
+		{"customer_id": "cust-1", "name": "Jane Doe"},
+	}
+
+	classified, err := stage.EnrichRecords(ctx, records)
+	require.NoError(t, err)
+	assert.Equal(t, 3, classified)
+
+	assert.Equal(t, enrichment.CategoryGambling, records[0][enrichment.FieldMerchantCategory])
+	assert.Equal(t, enrichment.ChannelEcommerce, records[0][enrichment.FieldChannel])
+	assert.Equal(t, "online", records[0][enrichment.FieldSourceChannel])
+	assert.Equal(t, enrichment.CategoryGroceries, records[2][enrichment.FieldMerchantCategory])
+	assert.NotContains(t, records[3], enrichment.FieldMerchantCategory, "non-transaction records are untouched")
+
+	aggregates, ok := records[1][enrichment.FieldCategoryAggregates].(map[string]*enrichment.CategoryAggregate)
+	require.True(t, ok)
+	require.Contains(t, aggregates, enrichment.CategoryGambling)
+	assert.Equal(t, 2, aggregates[enrichment.CategoryGambling].Count)
+	assert.Equal(t, 2000.0, aggregates[enrichment.CategoryGambling].TotalAmount)
+	assert.Equal(t, 1200.0, aggregates[enrichment.CategoryGambling].MaxAmount)
+
+	current := stage.Aggregates("acc-1")
+	assert.Len(t, current, 2)
+	assert.Equal(t, 50.0, current[enrichment.CategoryGroceries].TotalAmount)
+}
+
+func TestCategoryAggregateWindow(t *testing.T) {
+	aggregator := enrichment.NewAggregator(24*time.Hour, time.Hour)
+	start := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	aggregator.Observe("acc-1", enrichment.CategoryCrypto, 100, start)
+	aggregator.Observe("acc-1", enrichment.CategoryCrypto, 250, start.Add(10*time.Minute))
+	aggregator.Observe("acc-1", enrichment.CategoryCrypto, 400, start.Add(20*time.Hour))
+
+	within := aggregator.Aggregates("acc-1", start.Add(21*time.Hour))
+	require.Contains(t, within, enrichment.CategoryCrypto)
+	assert.Equal(t, 3, within[enrichment.CategoryCrypto].Count)
+	assert.Equal(t, 750.0, within[enrichment.CategoryCrypto].TotalAmount)
+	assert.Equal(t, start, within[enrichment.CategoryCrypto].FirstSeen)
+
+	later := aggregator.Aggregates("acc-1", start.Add(30*time.Hour))
+	assert.Equal(t, 1, later[enrichment.CategoryCrypto].Count, "the first bucket left the window")
+	assert.Equal(t, 400.0, later[enrichment.CategoryCrypto].TotalAmount)
+
+	aggregator.Prune(start.Add(48 * time.Hour))
+	assert.Empty(t, aggregator.Aggregates("acc-1", start.Add(21*time.Hour)), "pruned buckets are gone")
+}
@@ -0,0 +1,157 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aegisshield/data-integration/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryStorageClient is an in-memory StorageClient without native Object Lock support
+type memoryStorageClient struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	metadata map[string]map[string]interface{}
+}
+
+func newMemoryStorageClient() *memoryStorageClient {
+	return &memoryStorageClient{
+		objects:  make(map[string][]byte),
+		metadata: make(map[string]map[string]interface{}),
+	}
+}
+
+func (c *memoryStorageClient) Put(ctx context.Context, key string, data io.Reader, metadata map[string]interface{}) error {
+	content, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.objects[key] = content
+	c.metadata[key] = metadata
+	return nil
+}
+
+func (c *memoryStorageClient) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return io.NopCloser(bytes.NewReader(c.objects[key])), nil
+}
+
+func (c *memoryStorageClient) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.objects, key)
+	delete(c.metadata, key)
+	return nil
+}
+
+func (c *memoryStorageClient) List(ctx context.Context, prefix string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var keys []string
+	for key := range c.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (c *memoryStorageClient) Exists(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.objects[key]
+	return ok, nil
+}
+
+func (c *memoryStorageClient) GetMetadata(ctx context.Context, key string) (map[string]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.metadata[key], nil
+}
+
+func TestWORMClientRejectsOverwriteAndDelete(t *testing.T) {
+	ctx := context.Background()
+	backend := newMemoryStorageClient()
+	client := storage.NewWORMClient(backend, 24*time.Hour, []string{"audit/", "evidence/"}, zap.NewNop())
+
+	require.NoError(t, client.Put(ctx, "audit/2024/01/log.json", strings.NewReader("original"), nil))
+
+	metadata, err := backend.GetMetadata(ctx, "audit/2024/01/log.json")
+	require.NoError(t, err)
+	assert.Equal(t, storage.ObjectLockModeCompliance, metadata[storage.MetadataObjectLockMode])
+
+	err = client.Put(ctx, "audit/2024/01/log.json", strings.NewReader("tampered"), nil)
+	assert.ErrorIs(t, err, storage.ErrObjectLocked)
+
+	err = client.Delete(ctx, "audit/2024/01/log.json")
+	assert.ErrorIs(t, err, storage.ErrObjectLocked)
+
+	reader, err := client.Get(ctx, "audit/2024/01/log.json")
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(content), "locked content must be unchanged")
+
+	// Keys outside the locked prefixes behave like regular storage
+	require.NoError(t, client.Put(ctx, "uploads/data.csv", strings.NewReader("a,b"), nil))
+	require.NoError(t, client.Put(ctx, "uploads/data.csv", strings.NewReader("c,d"), nil))
+	assert.NoError(t, client.Delete(ctx, "uploads/data.csv"))
+}
+
+func TestWORMClientExpiredRetention(t *testing.T) {
+	ctx := context.Background()
+	backend := newMemoryStorageClient()
+	client := storage.NewWORMClient(backend, 24*time.Hour, []string{"evidence/"}, zap.NewNop())
+
+	require.NoError(t, backend.Put(ctx, "evidence/case-1/bundle.zip", strings.NewReader("bundle"), map[string]interface{}{
+		storage.MetadataObjectLockMode:        storage.ObjectLockModeCompliance,
+		storage.MetadataObjectLockRetainUntil: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+	}))
+
+	status, err := client.Verify(ctx, "evidence/case-1/bundle.zip")
+	require.NoError(t, err)
+	assert.True(t, status.Exists)
+	assert.True(t, status.Protected)
+	assert.False(t, status.Locked)
+	assert.False(t, status.DeleteDenied)
+
+	assert.NoError(t, client.Delete(ctx, "evidence/case-1/bundle.zip"))
+}
+
+func TestWORMClientVerifyAndRetention(t *testing.T) {
+	ctx := context.Background()
+	backend := newMemoryStorageClient()
+	client := storage.NewWORMClient(backend, 24*time.Hour, nil, zap.NewNop())
+
+	require.NoError(t, client.Put(ctx, "reports/sar-1.pdf", strings.NewReader("report"), nil))
+
+	status, err := client.Verify(ctx, "reports/sar-1.pdf")
+	require.NoError(t, err)
+	assert.True(t, status.Protected, "every key is protected when no prefixes are configured")
+	assert.True(t, status.Locked)
+	assert.True(t, status.OverwriteDenied)
+	assert.True(t, status.DeleteDenied)
+	assert.Equal(t, storage.ObjectLockModeCompliance, status.Mode)
+	require.NotNil(t, status.RetainUntil)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *status.RetainUntil, time.Minute)
+
+	err = client.ExtendRetention(ctx, "reports/sar-1.pdf", time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, storage.ErrRetentionShortened)
+
+	missing, err := client.Verify(ctx, "reports/missing.pdf")
+	require.NoError(t, err)
+	assert.False(t, missing.Exists)
+	assert.False(t, missing.Locked)
+}
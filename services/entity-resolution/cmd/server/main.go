@@ -11,18 +11,30 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/aegisshield/entity-resolution/internal/calibration"
 	"github.com/aegisshield/entity-resolution/internal/config"
 	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/dedup"
 	"github.com/aegisshield/entity-resolution/internal/handlers"
 	"github.com/aegisshield/entity-resolution/internal/interceptors"
 	"github.com/aegisshield/entity-resolution/internal/kafka"
+	"github.com/aegisshield/entity-resolution/internal/lineage"
 	"github.com/aegisshield/entity-resolution/internal/matching"
+	"github.com/aegisshield/entity-resolution/internal/matchmodel"
 	"github.com/aegisshield/entity-resolution/internal/metrics"
 	"github.com/aegisshield/entity-resolution/internal/neo4j"
+	"github.com/aegisshield/entity-resolution/internal/organization"
+	"github.com/aegisshield/entity-resolution/internal/rescore"
 	"github.com/aegisshield/entity-resolution/internal/resolver"
+	"github.com/aegisshield/entity-resolution/internal/review"
+	"github.com/aegisshield/entity-resolution/internal/screening"
 	"github.com/aegisshield/entity-resolution/internal/server"
 	"github.com/aegisshield/entity-resolution/internal/standardization"
+	"github.com/aegisshield/entity-resolution/internal/survivorship"
+	"github.com/aegisshield/entity-resolution/internal/tuning"
+	"github.com/aegisshield/shared/eventcatalog"
 	pb "github.com/aegisshield/shared/proto"
+	"github.com/aegisshield/shared/startup"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
@@ -56,50 +68,86 @@ func main() {
 	metricsCollector := metrics.NewCollector()
 	metricsCollector.Register()
 
+	// Shutdown signals also abort startup retries
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Start in dependency order: migrations, repositories, consumers, servers.
+	// Unavailable dependencies are retried with backoff.
+	seq := startup.NewSequencer(startup.Backoff{
+		Initial:    cfg.Startup.InitialBackoff,
+		Max:        cfg.Startup.MaxBackoff,
+		Multiplier: 2,
+		MaxElapsed: cfg.Startup.MaxElapsed,
+	}, logger)
+
 	// Initialize database repository
-	repository, err := database.NewRepository(cfg.Database, logger)
-	if err != nil {
+	var repository *database.Repository
+	if err := seq.Step(ctx, startup.Component{Name: "postgres", Phase: startup.PhaseMigrations, Critical: true}, func(ctx context.Context) error {
+		var err error
+		repository, err = database.NewRepository(cfg.Database, logger)
+		return err
+	}); err != nil {
 		logger.Error("Failed to initialize database repository", "error", err)
 		os.Exit(1)
 	}
 	defer repository.Close()
 
 	// Run database migrations
-	if err := repository.Migrate(); err != nil {
+	if err := seq.Step(ctx, startup.Component{Name: "migrations", Phase: startup.PhaseMigrations, Critical: true}, func(ctx context.Context) error {
+		return repository.Migrate()
+	}); err != nil {
 		logger.Error("Failed to run database migrations", "error", err)
 		os.Exit(1)
 	}
 
 	// Initialize Neo4j client
-	neo4jClient, err := neo4j.NewClient(cfg.Neo4j, logger)
-	if err != nil {
+	var neo4jClient *neo4j.Client
+	if err := seq.Step(ctx, startup.Component{Name: "neo4j", Phase: startup.PhaseRepositories, Critical: true}, func(ctx context.Context) error {
+		var err error
+		neo4jClient, err = neo4j.NewClient(cfg.Neo4j, logger)
+		return err
+	}); err != nil {
 		logger.Error("Failed to initialize Neo4j client", "error", err)
 		os.Exit(1)
 	}
 	defer neo4jClient.Close()
 
 	// Initialize Kafka producer
-	kafkaProducer, err := kafka.NewProducer(cfg.Kafka, logger)
-	if err != nil {
+	var kafkaProducer *kafka.Producer
+	if err := seq.Step(ctx, startup.Component{Name: "kafka-producer", Phase: startup.PhaseRepositories, Critical: true}, func(ctx context.Context) error {
+		var err error
+		kafkaProducer, err = kafka.NewProducer(cfg.Kafka, logger)
+		return err
+	}); err != nil {
 		logger.Error("Failed to initialize Kafka producer", "error", err)
 		os.Exit(1)
 	}
 	defer kafkaProducer.Close()
 
-	// Initialize Kafka consumer
-	kafkaConsumer, err := kafka.NewConsumer(cfg.Kafka, logger)
-	if err != nil {
-		logger.Error("Failed to initialize Kafka consumer", "error", err)
-		os.Exit(1)
-	}
-	defer kafkaConsumer.Close()
-
 	// Initialize standardization engine
 	standardizer := standardization.NewEngine(logger)
 
 	// Initialize matching engine
+	if err := matching.ValidateConfig(cfg.Matching); err != nil {
+		logger.Error("Invalid matching configuration", "error", err)
+		os.Exit(1)
+	}
 	matcher := matching.NewEngine(cfg.Matching, standardizer, logger)
 
+	// Initialize per-entity-type matching models; applies the stored models
+	// and hot reloads them as they change
+	matchModelService := matchmodel.NewService(repository, matcher, cfg.Matching, logger)
+	if err := matchModelService.Load(ctx); err != nil {
+		logger.Warn("Failed to load matching models", "error", err)
+	}
+
+	// Initialize screening threshold tuning; applies the last tuned threshold
+	tuningService := tuning.NewService(repository, matcher, cfg.Tuning, logger)
+	if err := tuningService.LoadActiveSettings(ctx); err != nil {
+		logger.Warn("Failed to load screening threshold", "error", err)
+	}
+
 	// Initialize entity resolver
 	entityResolver := resolver.NewEntityResolver(
 		repository,
@@ -111,6 +159,136 @@ func main() {
 		logger,
 	)
 
+	// Initialize match confidence calibration
+	calibrationService := calibration.NewService(repository, cfg.Calibration, logger)
+	if err := calibrationService.LoadActiveModel(ctx); err != nil {
+		logger.Warn("Failed to load calibration model", "error", err)
+	}
+	entityResolver.SetCalibrator(calibrationService)
+
+	// Initialize golden-record survivorship
+	survivorshipService := survivorship.NewService(repository, survivorship.NewEngine(cfg.Survivorship), logger)
+	if cfg.Survivorship.Enabled {
+		entityResolver.SetSurvivorship(survivorshipService)
+	}
+
+	// Initialize the lineage audit trail of merges, splits and attribute changes
+	lineageService := lineage.NewService(repository, neo4jClient, cfg.Lineage, logger)
+	entityResolver.SetLineage(lineageService)
+
+	// Initialize backlog deduplication; resumes any run interrupted by a restart
+	dedupService := dedup.NewService(repository, matcher, calibrationService, cfg.Dedup, logger)
+
+	// Initialize manual merge review; low-confidence merges wait for an investigator
+	var reviewService *review.Service
+	if cfg.Review.Enabled {
+		reviewService = review.NewService(repository, neo4jClient, calibrationService, cfg.Review, logger)
+		entityResolver.SetReviewQueue(reviewService)
+	}
+
+	// Initialize sanctions and PEP list screening of resolved entities
+	var screeningService *screening.Service
+	if cfg.Screening.Enabled {
+		screeningService = screening.NewService(repository, kafkaProducer, matcher, cfg.Screening, logger)
+		if err := screeningService.LoadLists(ctx); err != nil {
+			logger.Warn("Failed to load screening lists", "error", err)
+		}
+		entityResolver.SetScreener(screeningService)
+	}
+
+	// Initialize organization aliases and corporate hierarchies
+	organizationService := organization.NewService(repository, standardizer, cfg.Organization, logger)
+
+	// Verify the consumed topics exist; a consumer group would otherwise wait
+	// on a missing topic silently
+	consumedTopics := []string{cfg.Kafka.TransactionTopic}
+	if cfg.Rescore.Enabled {
+		consumedTopics = append(consumedTopics, cfg.Kafka.AttributeUpdatedTopic)
+	}
+	if undeclared := eventcatalog.Default().Undeclared(consumedTopics...); len(undeclared) > 0 {
+		logger.Warn("Consumed topics are not in the event catalog", "topics", undeclared)
+	}
+	if cfg.Kafka.ValidateTopics {
+		if err := seq.Step(ctx, startup.Component{Name: "kafka-topics", Phase: startup.PhaseConsumers, Critical: true}, func(ctx context.Context) error {
+			return eventcatalog.CheckTopics(ctx, kafka.NewTopicLister(cfg.Kafka), consumedTopics...)
+		}); err != nil {
+			logger.Error("Consumed Kafka topics are missing", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Initialize Kafka consumer
+	consumerComponent := startup.Component{Name: "kafka-consumer", Phase: startup.PhaseConsumers, Critical: true}
+	var kafkaConsumer *kafka.Consumer
+	if err := seq.Step(ctx, consumerComponent, func(ctx context.Context) error {
+		var err error
+		kafkaConsumer, err = kafka.NewConsumer(cfg.Kafka, logger)
+		return err
+	}); err != nil {
+		logger.Error("Failed to initialize Kafka consumer", "error", err)
+		os.Exit(1)
+	}
+	defer kafkaConsumer.Close()
+
+	// Start Kafka consumer
+	seq.Go(ctx, consumerComponent, func(ctx context.Context) error {
+		logger.Info("Starting Kafka consumer")
+
+		// Process transaction events for entity resolution
+		return kafkaConsumer.ConsumeTransactionProcessedEvents(ctx, func(ctx context.Context, event *pb.TransactionProcessedEvent) error {
+			return entityResolver.ProcessTransactionEvent(ctx, event)
+		})
+	})
+
+	// Initialize incremental re-resolution; attribute updates from upstream
+	// records re-score the pending merges they affect
+	if cfg.Rescore.Enabled {
+		rescoreService := rescore.NewService(repository, matcher, calibrationService, cfg.Review.Threshold, cfg.Rescore, logger)
+
+		rescoreComponent := startup.Component{Name: "kafka-rescore-consumer", Phase: startup.PhaseConsumers}
+		var rescoreConsumer *kafka.AttributeUpdateConsumer
+		if err := seq.Step(ctx, rescoreComponent, func(ctx context.Context) error {
+			var err error
+			rescoreConsumer, err = kafka.NewAttributeUpdateConsumer(cfg.Kafka, cfg.Rescore, rescoreService, logger)
+			return err
+		}); err != nil {
+			logger.Warn("Failed to initialize Kafka attribute update consumer", "error", err)
+		} else {
+			defer rescoreConsumer.Close()
+
+			seq.Go(ctx, rescoreComponent, func(ctx context.Context) error {
+				logger.Info("Starting Kafka attribute update consumer", "topic", cfg.Kafka.AttributeUpdatedTopic)
+				return rescoreConsumer.Start(ctx)
+			})
+		}
+	}
+
+	// Start matching model hot reload
+	seq.Go(ctx, startup.Component{Name: "matching-models", Phase: startup.PhaseConsumers}, func(ctx context.Context) error {
+		matchModelService.Start(ctx)
+		return nil
+	})
+
+	// Start calibration refits and backlog deduplication
+	seq.Go(ctx, startup.Component{Name: "calibration", Phase: startup.PhaseConsumers}, func(ctx context.Context) error {
+		calibrationService.Start(ctx)
+		return nil
+	})
+	seq.Go(ctx, startup.Component{Name: "dedup", Phase: startup.PhaseConsumers}, func(ctx context.Context) error {
+		dedupService.Start(ctx)
+		return nil
+	})
+	seq.Go(ctx, startup.Component{Name: "lineage", Phase: startup.PhaseConsumers}, func(ctx context.Context) error {
+		lineageService.Start(ctx)
+		return nil
+	})
+	if screeningService != nil {
+		seq.Go(ctx, startup.Component{Name: "screening", Phase: startup.PhaseConsumers}, func(ctx context.Context) error {
+			screeningService.Start(ctx)
+			return nil
+		})
+	}
+
 	// Initialize gRPC server
 	grpcServer := grpc.NewServer(
 		grpc.UnaryInterceptor(grpc.ChainUnaryInterceptor(
@@ -135,31 +313,37 @@ func main() {
 		logger,
 	)
 
+	if reviewService != nil {
+		grpcService.SetReviewService(reviewService)
+	}
+
 	// Register services
 	pb.RegisterEntityResolutionServiceServer(grpcServer, grpcService)
 
-	// Register health service
+	// Register health service; serving once startup completes
 	healthServer := health.NewServer()
 	healthpb.RegisterHealthServer(grpcServer, healthServer)
-	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
 
 	// Enable reflection for development
 	reflection.Register(grpcServer)
 
 	// Start gRPC server
-	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.GRPCPort))
-	if err != nil {
+	grpcComponent := startup.Component{Name: "grpc-server", Phase: startup.PhaseServers, Critical: true}
+	var grpcListener net.Listener
+	if err := seq.Step(ctx, grpcComponent, func(ctx context.Context) error {
+		var err error
+		grpcListener, err = net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.GRPCPort))
+		return err
+	}); err != nil {
 		logger.Error("Failed to listen on gRPC port", "port", cfg.Server.GRPCPort, "error", err)
 		os.Exit(1)
 	}
 
-	go func() {
+	seq.Go(ctx, grpcComponent, func(ctx context.Context) error {
 		logger.Info("gRPC server starting", "address", grpcListener.Addr())
-		if err := grpcServer.Serve(grpcListener); err != nil {
-			logger.Error("gRPC server failed", "error", err)
-			os.Exit(1)
-		}
-	}()
+		return grpcServer.Serve(grpcListener)
+	})
 
 	// Initialize HTTP handlers
 	httpHandlers := handlers.NewHTTPHandlers(
@@ -172,8 +356,23 @@ func main() {
 	// Setup HTTP router
 	router := mux.NewRouter()
 	httpHandlers.RegisterRoutes(router)
+	handlers.NewCalibrationHandler(calibrationService, logger).RegisterRoutes(router)
+	handlers.NewDedupHandler(dedupService, logger).RegisterRoutes(router)
+	handlers.NewOrganizationHandler(organizationService, logger).RegisterRoutes(router)
+	handlers.NewTuningHandler(tuningService, logger).RegisterRoutes(router)
+	handlers.NewMatchingModelHandler(matchModelService, logger).RegisterRoutes(router)
+	handlers.NewSurvivorshipHandler(survivorshipService, logger).RegisterRoutes(router)
+	handlers.NewLineageHandler(lineageService, logger).RegisterRoutes(router)
+	if reviewService != nil {
+		handlers.NewReviewHandler(reviewService, logger).RegisterRoutes(router)
+	}
+	if screeningService != nil {
+		handlers.NewScreeningHandler(screeningService, logger).RegisterRoutes(router)
+	}
 
-	// Add metrics endpoint
+	// Add readiness and metrics endpoints
+	router.Handle("/api/v1/ready", seq.Readiness()).Methods("GET")
+	router.Handle("/api/v1/events/catalog", eventcatalog.Default().Handler()).Methods("GET")
 	router.Handle("/metrics", promhttp.Handler())
 
 	// Start HTTP server
@@ -185,43 +384,49 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
-	go func() {
+	httpComponent := startup.Component{Name: "http-server", Phase: startup.PhaseServers, Critical: true}
+	var httpListener net.Listener
+	if err := seq.Step(ctx, httpComponent, func(ctx context.Context) error {
+		var err error
+		httpListener, err = net.Listen("tcp", httpServer.Addr)
+		return err
+	}); err != nil {
+		logger.Error("Failed to listen on HTTP port", "port", cfg.Server.HTTPPort, "error", err)
+		os.Exit(1)
+	}
+
+	seq.Go(ctx, httpComponent, func(ctx context.Context) error {
 		logger.Info("HTTP server starting", "address", httpServer.Addr)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error("HTTP server failed", "error", err)
-			os.Exit(1)
+		if err := httpServer.Serve(httpListener); err != nil && err != http.ErrServerClosed {
+			return err
 		}
-	}()
+		return nil
+	})
 
-	// Start Kafka consumer
-	go func() {
-		ctx := context.Background()
-		logger.Info("Starting Kafka consumer")
-		
-		// Process transaction events for entity resolution
-		if err := kafkaConsumer.ConsumeTransactionProcessedEvents(ctx, func(ctx context.Context, event *pb.TransactionProcessedEvent) error {
-			return entityResolver.ProcessTransactionEvent(ctx, event)
-		}); err != nil {
-			logger.Error("Kafka consumer failed", "error", err)
-		}
-	}()
+	seq.Complete()
+	if seq.Readiness().Ready() {
+		healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	}
 
-	// Wait for interrupt signal
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	sig := <-sigChan
+	// Wait for a shutdown signal or a critical component to stop
+	select {
+	case <-ctx.Done():
+		logger.Info("Received shutdown signal")
+	case <-seq.Done():
+		logger.Error("Critical component stopped, shutting down", "error", seq.Err())
+	}
 
-	logger.Info("Received shutdown signal", "signal", sig)
+	// Stop health checks before draining connections
+	seq.Readiness().Drain()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	stop()
 
 	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// Stop health checks
-	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
-
 	// Shutdown HTTP server
-	if err := httpServer.Shutdown(ctx); err != nil {
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
 		logger.Error("HTTP server shutdown failed", "error", err)
 	} else {
 		logger.Info("HTTP server stopped")
@@ -237,10 +442,13 @@ func main() {
 	select {
 	case <-done:
 		logger.Info("gRPC server stopped")
-	case <-ctx.Done():
+	case <-shutdownCtx.Done():
 		logger.Warn("gRPC server shutdown timeout, forcing stop")
 		grpcServer.Stop()
 	}
 
+	// Wait for the consumer and background services to return
+	seq.Wait()
+
 	logger.Info("Entity Resolution Service stopped")
 }
\ No newline at end of file
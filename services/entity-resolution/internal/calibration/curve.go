@@ -0,0 +1,276 @@
+package calibration
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Calibration methods
+const (
+	MethodPlatt    = "platt"
+	MethodIsotonic = "isotonic"
+)
+
+// Sample is a raw similarity score paired with the reviewer outcome
+type Sample struct {
+	Score    float64 `json:"score"`
+	Accepted bool    `json:"accepted"`
+}
+
+// Curve maps a raw similarity score to a calibrated match probability
+type Curve interface {
+	Method() string
+	Calibrate(score float64) float64
+}
+
+// PlattCurve is a logistic calibration curve p = 1 / (1 + exp(A*s + B))
+type PlattCurve struct {
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+}
+
+// Method returns the calibration method name
+func (c *PlattCurve) Method() string {
+	return MethodPlatt
+}
+
+// Calibrate returns the calibrated probability for a raw score
+func (c *PlattCurve) Calibrate(score float64) float64 {
+	return sigmoid(-(c.A*clamp01(score) + c.B))
+}
+
+// IsotonicCurve is a monotone step function fitted with pool-adjacent-violators.
+// Scores between block centres are linearly interpolated.
+type IsotonicCurve struct {
+	Scores []float64 `json:"scores"`
+	Values []float64 `json:"values"`
+}
+
+// Method returns the calibration method name
+func (c *IsotonicCurve) Method() string {
+	return MethodIsotonic
+}
+
+// Calibrate returns the calibrated probability for a raw score
+func (c *IsotonicCurve) Calibrate(score float64) float64 {
+	if len(c.Scores) == 0 {
+		return clamp01(score)
+	}
+
+	score = clamp01(score)
+	if score <= c.Scores[0] {
+		return c.Values[0]
+	}
+	last := len(c.Scores) - 1
+	if score >= c.Scores[last] {
+		return c.Values[last]
+	}
+
+	i := sort.SearchFloat64s(c.Scores, score)
+	if c.Scores[i] == score {
+		return c.Values[i]
+	}
+
+	lowScore, highScore := c.Scores[i-1], c.Scores[i]
+	lowValue, highValue := c.Values[i-1], c.Values[i]
+	weight := (score - lowScore) / (highScore - lowScore)
+	return lowValue + weight*(highValue-lowValue)
+}
+
+// Fit fits a calibration curve of the given method to reviewer outcomes
+func Fit(method string, samples []Sample) (Curve, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples to fit")
+	}
+
+	positives := 0
+	for _, sample := range samples {
+		if sample.Accepted {
+			positives++
+		}
+	}
+	if positives == 0 || positives == len(samples) {
+		return nil, fmt.Errorf("samples must contain both accepted and rejected outcomes")
+	}
+
+	switch method {
+	case MethodPlatt:
+		return fitPlatt(samples, positives), nil
+	case MethodIsotonic:
+		return fitIsotonic(samples), nil
+	default:
+		return nil, fmt.Errorf("unsupported calibration method: %s", method)
+	}
+}
+
+// Decode restores a calibration curve from its stored parameters
+func Decode(method string, parameters json.RawMessage) (Curve, error) {
+	var curve Curve
+	switch method {
+	case MethodPlatt:
+		curve = &PlattCurve{}
+	case MethodIsotonic:
+		curve = &IsotonicCurve{}
+	default:
+		return nil, fmt.Errorf("unsupported calibration method: %s", method)
+	}
+
+	if err := json.Unmarshal(parameters, curve); err != nil {
+		return nil, fmt.Errorf("failed to decode %s parameters: %w", method, err)
+	}
+
+	return curve, nil
+}
+
+// fitPlatt fits a Platt sigmoid with Newton's method, using Platt's smoothed
+// targets so the fit does not overshoot to 0 or 1 on small samples
+func fitPlatt(samples []Sample, positives int) *PlattCurve {
+	negatives := len(samples) - positives
+	highTarget := (float64(positives) + 1) / (float64(positives) + 2)
+	lowTarget := 1 / (float64(negatives) + 2)
+
+	targets := make([]float64, len(samples))
+	for i, sample := range samples {
+		if sample.Accepted {
+			targets[i] = highTarget
+		} else {
+			targets[i] = lowTarget
+		}
+	}
+
+	a := 0.0
+	b := math.Log((float64(negatives) + 1) / (float64(positives) + 1))
+	const sigma = 1e-12
+
+	objective := func(a, b float64) float64 {
+		total := 0.0
+		for i, sample := range samples {
+			f := a*clamp01(sample.Score) + b
+			if f >= 0 {
+				total += targets[i]*f + math.Log1p(math.Exp(-f))
+			} else {
+				total += (targets[i]-1)*f + math.Log1p(math.Exp(f))
+			}
+		}
+		return total
+	}
+
+	current := objective(a, b)
+	for iteration := 0; iteration < 100; iteration++ {
+		// Gradient and Hessian of the negative log-likelihood
+		h11, h22, h21 := sigma, sigma, 0.0
+		g1, g2 := 0.0, 0.0
+		for i, sample := range samples {
+			s := clamp01(sample.Score)
+			p := sigmoid(-(a*s + b))
+			q := 1 - p
+			d2 := p * q
+			d1 := targets[i] - p
+
+			h11 += s * s * d2
+			h22 += d2
+			h21 += s * d2
+			g1 += s * d1
+			g2 += d1
+		}
+
+		if math.Abs(g1) < 1e-5 && math.Abs(g2) < 1e-5 {
+			break
+		}
+
+		det := h11*h22 - h21*h21
+		dA := -(h22*g1 - h21*g2) / det
+		dB := -(-h21*g1 + h11*g2) / det
+		gd := g1*dA + g2*dB
+
+		// Backtracking line search
+		step := 1.0
+		for step >= 1e-10 {
+			newA, newB := a+step*dA, b+step*dB
+			value := objective(newA, newB)
+			if value < current+0.0001*step*gd {
+				a, b, current = newA, newB, value
+				break
+			}
+			step /= 2
+		}
+
+		if step < 1e-10 {
+			break
+		}
+	}
+
+	return &PlattCurve{A: a, B: b}
+}
+
+// fitIsotonic fits a non-decreasing step function using pool-adjacent-violators
+func fitIsotonic(samples []Sample) *IsotonicCurve {
+	sorted := make([]Sample, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Score < sorted[j].Score
+	})
+
+	type block struct {
+		scoreSum float64
+		valueSum float64
+		weight   float64
+	}
+
+	blocks := make([]block, 0, len(sorted))
+	for _, sample := range sorted {
+		value := 0.0
+		if sample.Accepted {
+			value = 1.0
+		}
+		blocks = append(blocks, block{scoreSum: clamp01(sample.Score), valueSum: value, weight: 1})
+
+		// Merge backwards while the monotonicity constraint is violated
+		for len(blocks) > 1 {
+			last := blocks[len(blocks)-1]
+			prev := blocks[len(blocks)-2]
+			if prev.valueSum/prev.weight <= last.valueSum/last.weight {
+				break
+			}
+			blocks = blocks[:len(blocks)-2]
+			blocks = append(blocks, block{
+				scoreSum: prev.scoreSum + last.scoreSum,
+				valueSum: prev.valueSum + last.valueSum,
+				weight:   prev.weight + last.weight,
+			})
+		}
+	}
+
+	curve := &IsotonicCurve{
+		Scores: make([]float64, 0, len(blocks)),
+		Values: make([]float64, 0, len(blocks)),
+	}
+	for _, b := range blocks {
+		score := b.scoreSum / b.weight
+		value := b.valueSum / b.weight
+
+		// Blocks with identical mean scores collapse into one point
+		if n := len(curve.Scores); n > 0 && curve.Scores[n-1] == score {
+			curve.Values[n-1] = math.Max(curve.Values[n-1], value)
+			continue
+		}
+		curve.Scores = append(curve.Scores, score)
+		curve.Values = append(curve.Values, value)
+	}
+
+	return curve
+}
+
+func sigmoid(x float64) float64 {
+	if x >= 0 {
+		return 1 / (1 + math.Exp(-x))
+	}
+	e := math.Exp(x)
+	return e / (1 + e)
+}
+
+func clamp01(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
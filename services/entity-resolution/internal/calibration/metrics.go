@@ -0,0 +1,82 @@
+package calibration
+
+import "math"
+
+// Metrics describes how well a curve's probabilities match reviewer outcomes
+type Metrics struct {
+	SampleCount   int               `json:"sample_count"`
+	PositiveCount int               `json:"positive_count"`
+	BrierScore    float64           `json:"brier_score"`
+	LogLoss       float64           `json:"log_loss"`
+	Reliability   []*ReliabilityBin `json:"reliability"`
+}
+
+// ReliabilityBin compares predicted and observed precision within a score band
+type ReliabilityBin struct {
+	LowerBound        float64 `json:"lower_bound"`
+	UpperBound        float64 `json:"upper_bound"`
+	Count             int     `json:"count"`
+	MeanPredicted     float64 `json:"mean_predicted"`
+	ObservedPrecision float64 `json:"observed_precision"`
+}
+
+// Evaluate scores a calibration curve against reviewer outcomes
+func Evaluate(curve Curve, samples []Sample, binCount int) *Metrics {
+	if binCount <= 0 {
+		binCount = 10
+	}
+
+	metrics := &Metrics{
+		SampleCount: len(samples),
+		Reliability: make([]*ReliabilityBin, binCount),
+	}
+
+	width := 1.0 / float64(binCount)
+	for i := range metrics.Reliability {
+		metrics.Reliability[i] = &ReliabilityBin{
+			LowerBound: float64(i) * width,
+			UpperBound: float64(i+1) * width,
+		}
+	}
+
+	if len(samples) == 0 {
+		return metrics
+	}
+
+	const epsilon = 1e-15
+	for _, sample := range samples {
+		p := curve.Calibrate(sample.Score)
+		outcome := 0.0
+		if sample.Accepted {
+			outcome = 1.0
+			metrics.PositiveCount++
+		}
+
+		metrics.BrierScore += (p - outcome) * (p - outcome)
+
+		clipped := math.Max(epsilon, math.Min(1-epsilon, p))
+		metrics.LogLoss -= outcome*math.Log(clipped) + (1-outcome)*math.Log(1-clipped)
+
+		index := int(p / width)
+		if index >= binCount {
+			index = binCount - 1
+		}
+		bin := metrics.Reliability[index]
+		bin.Count++
+		bin.MeanPredicted += p
+		bin.ObservedPrecision += outcome
+	}
+
+	n := float64(len(samples))
+	metrics.BrierScore /= n
+	metrics.LogLoss /= n
+
+	for _, bin := range metrics.Reliability {
+		if bin.Count > 0 {
+			bin.MeanPredicted /= float64(bin.Count)
+			bin.ObservedPrecision /= float64(bin.Count)
+		}
+	}
+
+	return metrics
+}
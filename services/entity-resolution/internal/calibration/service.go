@@ -0,0 +1,260 @@
+package calibration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/google/uuid"
+)
+
+// Review outcomes
+const (
+	OutcomeAccepted = "accepted"
+	OutcomeRejected = "rejected"
+)
+
+// Service fits calibration curves from reviewer outcomes and applies the
+// active curve to similarity scores
+type Service struct {
+	db     *database.Repository
+	config config.CalibrationConfig
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	curve  Curve
+	active *database.CalibrationModel
+}
+
+// ReviewRequest represents a reviewer's decision on a proposed match
+type ReviewRequest struct {
+	SourceEntityID    string  `json:"source_entity_id"`
+	CandidateEntityID string  `json:"candidate_entity_id"`
+	RawScore          float64 `json:"raw_score"`
+	Outcome           string  `json:"outcome"`
+	ReviewerID        string  `json:"reviewer_id"`
+	Notes             string  `json:"notes,omitempty"`
+}
+
+// FitResult describes the outcome of a calibration job
+type FitResult struct {
+	Model   *database.CalibrationModel `json:"model"`
+	Metrics *Metrics                   `json:"metrics"`
+	Before  *Metrics                   `json:"before"`
+}
+
+// identityCurve treats raw scores as probabilities; used to report how
+// uncalibrated scores perform against the same reviews
+type identityCurve struct{}
+
+func (identityCurve) Method() string                  { return "none" }
+func (identityCurve) Calibrate(score float64) float64 { return clamp01(score) }
+
+// NewService creates a new calibration service
+func NewService(db *database.Repository, config config.CalibrationConfig, logger *slog.Logger) *Service {
+	return &Service{
+		db:     db,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Calibrate maps a raw similarity score to a calibrated match probability.
+// Scores pass through unchanged until a calibration model has been fitted.
+func (s *Service) Calibrate(score float64) float64 {
+	if !s.config.Enabled {
+		return score
+	}
+
+	s.mu.RLock()
+	curve := s.curve
+	s.mu.RUnlock()
+
+	if curve == nil {
+		return score
+	}
+	return curve.Calibrate(score)
+}
+
+// ActiveModel returns the calibration model currently applied to scores
+func (s *Service) ActiveModel() *database.CalibrationModel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.active
+}
+
+// LoadActiveModel loads the active calibration model from the database
+func (s *Service) LoadActiveModel(ctx context.Context) error {
+	model, err := s.db.GetActiveCalibrationModel(ctx)
+	if err != nil {
+		return err
+	}
+
+	if model == nil {
+		s.logger.Info("No active calibration model, similarity scores will be uncalibrated")
+		return nil
+	}
+
+	curve, err := Decode(model.Method, model.Parameters)
+	if err != nil {
+		return err
+	}
+
+	s.setActive(model, curve)
+
+	s.logger.Info("Loaded calibration model",
+		"model_id", model.ID,
+		"method", model.Method,
+		"sample_count", model.SampleCount)
+
+	return nil
+}
+
+// RecordReview stores a reviewer outcome so it can be used in the next fit
+func (s *Service) RecordReview(ctx context.Context, req *ReviewRequest) (*database.MatchReview, error) {
+	if req.Outcome != OutcomeAccepted && req.Outcome != OutcomeRejected {
+		return nil, fmt.Errorf("outcome must be %s or %s", OutcomeAccepted, OutcomeRejected)
+	}
+
+	if req.RawScore < 0 || req.RawScore > 1 {
+		return nil, fmt.Errorf("raw_score must be between 0 and 1")
+	}
+
+	sourceID, err := uuid.Parse(req.SourceEntityID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source_entity_id: %w", err)
+	}
+
+	candidateID, err := uuid.Parse(req.CandidateEntityID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid candidate_entity_id: %w", err)
+	}
+
+	now := time.Now()
+	review := &database.MatchReview{
+		ID:                uuid.New(),
+		SourceEntityID:    sourceID,
+		CandidateEntityID: candidateID,
+		RawScore:          req.RawScore,
+		Outcome:           req.Outcome,
+		ReviewerID:        req.ReviewerID,
+		Notes:             req.Notes,
+		ReviewedAt:        now,
+		CreatedAt:         now,
+	}
+
+	if s.ActiveModel() != nil {
+		calibrated := s.Calibrate(req.RawScore)
+		review.CalibratedScore = &calibrated
+	}
+
+	if err := s.db.CreateMatchReview(ctx, review); err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// Fit runs the calibration job: it fits a curve to recent reviewer outcomes,
+// stores it as the active model and applies it to future scores
+func (s *Service) Fit(ctx context.Context, method string) (*FitResult, error) {
+	if method == "" {
+		method = s.config.Method
+	}
+
+	since := time.Now().Add(-s.config.SampleWindow)
+	reviews, err := s.db.ListMatchReviews(ctx, since, s.config.MaxSamples)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reviews) < s.config.MinSamples {
+		return nil, fmt.Errorf("not enough reviews to calibrate: have %d, need %d", len(reviews), s.config.MinSamples)
+	}
+
+	samples := make([]Sample, len(reviews))
+	for i, review := range reviews {
+		samples[i] = Sample{
+			Score:    review.RawScore,
+			Accepted: review.Outcome == OutcomeAccepted,
+		}
+	}
+
+	curve, err := Fit(method, samples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fit calibration curve: %w", err)
+	}
+
+	parameters, err := json.Marshal(curve)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal calibration parameters: %w", err)
+	}
+
+	metrics := Evaluate(curve, samples, 10)
+	now := time.Now()
+	model := &database.CalibrationModel{
+		ID:            uuid.New(),
+		Method:        curve.Method(),
+		Parameters:    parameters,
+		SampleCount:   metrics.SampleCount,
+		PositiveCount: metrics.PositiveCount,
+		BrierScore:    metrics.BrierScore,
+		LogLoss:       metrics.LogLoss,
+		IsActive:      true,
+		FittedAt:      now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := s.db.SaveCalibrationModel(ctx, model); err != nil {
+		return nil, err
+	}
+
+	s.setActive(model, curve)
+
+	s.logger.Info("Calibration model fitted",
+		"model_id", model.ID,
+		"method", model.Method,
+		"sample_count", model.SampleCount,
+		"positive_count", model.PositiveCount,
+		"brier_score", model.BrierScore)
+
+	return &FitResult{
+		Model:   model,
+		Metrics: metrics,
+		Before:  Evaluate(identityCurve{}, samples, 10),
+	}, nil
+}
+
+// Start runs the calibration job periodically until the context is cancelled
+func (s *Service) Start(ctx context.Context) {
+	if !s.config.Enabled || s.config.RefitInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.RefitInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Fit(ctx, ""); err != nil {
+				s.logger.Warn("Scheduled calibration skipped", "error", err)
+			}
+		}
+	}
+}
+
+func (s *Service) setActive(model *database.CalibrationModel, curve Curve) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active = model
+	s.curve = curve
+}
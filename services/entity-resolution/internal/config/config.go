@@ -10,12 +10,22 @@ import (
 
 // Config holds the application configuration
 type Config struct {
-	Server   ServerConfig   `json:"server"`
-	Database DatabaseConfig `json:"database"`
-	Kafka    KafkaConfig    `json:"kafka"`
-	Neo4j    Neo4jConfig    `json:"neo4j"`
-	Matching MatchingConfig `json:"matching"`
-	Logging  LoggingConfig  `json:"logging"`
+	Server       ServerConfig       `json:"server"`
+	Database     DatabaseConfig     `json:"database"`
+	Kafka        KafkaConfig        `json:"kafka"`
+	Neo4j        Neo4jConfig        `json:"neo4j"`
+	Matching     MatchingConfig     `json:"matching"`
+	Calibration  CalibrationConfig  `json:"calibration"`
+	Dedup        DedupConfig        `json:"dedup"`
+	Review       ReviewConfig       `json:"review"`
+	Survivorship SurvivorshipConfig `json:"survivorship"`
+	Organization OrganizationConfig `json:"organization"`
+	Tuning       TuningConfig       `json:"tuning"`
+	Screening    ScreeningConfig    `json:"screening"`
+	Lineage      LineageConfig      `json:"lineage"`
+	Rescore      RescoreConfig      `json:"rescore"`
+	Logging      LoggingConfig      `json:"logging"`
+	Startup      StartupConfig      `json:"startup"`
 }
 
 // ServerConfig holds server configuration
@@ -45,6 +55,9 @@ type KafkaConfig struct {
 	ConsumerGroup          string        `json:"consumer_group"`
 	TransactionTopic       string        `json:"transaction_topic"`
 	EntityResolutionTopic  string        `json:"entity_resolution_topic"`
+	ScreeningHitTopic      string        `json:"screening_hit_topic"`
+	AttributeUpdatedTopic  string        `json:"attribute_updated_topic"`
+	ValidateTopics         bool          `json:"validate_topics"` // fail startup when a consumed topic does not exist
 	BatchSize              int           `json:"batch_size"`
 	BatchTimeout           time.Duration `json:"batch_timeout"`
 	RetryAttempts          int           `json:"retry_attempts"`
@@ -77,6 +90,108 @@ type MatchingConfig struct {
 	PhoneticMatchingEnabled    bool    `json:"phonetic_matching_enabled"`
 	BlockingEnabled            bool    `json:"blocking_enabled"`
 	BlockingKeySize            int     `json:"blocking_key_size"`
+
+	// Similarity algorithms per field. An empty name list derives the
+	// algorithms from the fuzzy and phonetic switches.
+	NameAlgorithms   []string `json:"name_algorithms"`
+	StreetAlgorithms []string `json:"street_algorithms"`
+	CityAlgorithms   []string `json:"city_algorithms"`
+
+	// How often the per-entity-type matching models saved through the
+	// admin API are checked for changes made by other replicas
+	ModelReloadInterval time.Duration `json:"model_reload_interval"`
+}
+
+// CalibrationConfig holds match confidence calibration configuration
+type CalibrationConfig struct {
+	Enabled       bool          `json:"enabled"`
+	Method        string        `json:"method"`
+	MinSamples    int           `json:"min_samples"`
+	MaxSamples    int           `json:"max_samples"`
+	SampleWindow  time.Duration `json:"sample_window"`
+	RefitInterval time.Duration `json:"refit_interval"`
+}
+
+// DedupConfig holds legacy backlog deduplication configuration
+type DedupConfig struct {
+	BlockKeySize       int     `json:"block_key_size"`
+	BlockBatchSize     int     `json:"block_batch_size"`
+	MaxBlockSize       int     `json:"max_block_size"`
+	AutoMergeThreshold float64 `json:"auto_merge_threshold"`
+	ReviewThreshold    float64 `json:"review_threshold"`
+}
+
+// ReviewConfig holds manual merge review configuration. Live resolution
+// matches scoring at least Threshold but below the auto-merge threshold are
+// queued for an investigator instead of being left unmerged.
+type ReviewConfig struct {
+	Enabled       bool    `json:"enabled"`
+	Threshold     float64 `json:"threshold"`
+	MaxCandidates int     `json:"max_candidates"` // candidates kept as evidence
+}
+
+// SurvivorshipConfig holds golden-record survivorship configuration. Rules
+// map "entity_type.attribute" to a strategy, with "*" matching any entity
+// type; attributes without a rule use DefaultStrategy. SourceTrust ranks
+// sources from 0 (unknown) to 1 for the most_trusted_source strategy.
+type SurvivorshipConfig struct {
+	Enabled         bool               `json:"enabled"`
+	DefaultStrategy string             `json:"default_strategy"`
+	Rules           map[string]string  `json:"rules"`
+	SourceTrust     map[string]float64 `json:"source_trust"`
+}
+
+// OrganizationConfig holds organization alias and hierarchy configuration
+type OrganizationConfig struct {
+	MaxFamilyDepth           int     `json:"max_family_depth"`
+	AliasSimilarityThreshold float64 `json:"alias_similarity_threshold"`
+	MaxAliasMatches          int     `json:"max_alias_matches"`
+}
+
+// TuningConfig holds screening threshold tuning configuration
+type TuningConfig struct {
+	MaxCorpusPairs int     `json:"max_corpus_pairs"`
+	MaxSweepPoints int     `json:"max_sweep_points"`
+	DefaultStep    float64 `json:"default_step"`
+}
+
+// ScreeningConfig holds sanctions and PEP list screening configuration.
+// Lists map a list name to a file path or http(s) URL; lists named in
+// PEPLists are politically exposed person lists and the rest sanctions
+// lists. Lists can also be uploaded through the API.
+type ScreeningConfig struct {
+	Enabled          bool              `json:"enabled"`
+	Lists            map[string]string `json:"lists"`
+	PEPLists         []string          `json:"pep_lists"`
+	Threshold        float64           `json:"threshold"`
+	ReloadInterval   time.Duration     `json:"reload_interval"`
+	FetchTimeout     time.Duration     `json:"fetch_timeout"`
+	APIKey           string            `json:"-"` // bearer token sent when fetching lists over http(s)
+	MaxListEntries   int               `json:"max_list_entries"`
+	MaxUploadBytes   int64             `json:"max_upload_bytes"`
+	MaxHitsPerEntity int               `json:"max_hits_per_entity"`
+}
+
+// LineageConfig holds entity lineage audit configuration. Lineage events are
+// always recorded in the database; GraphEnabled mirrors them in Neo4j, with
+// events the graph missed retried every GraphSyncInterval.
+type LineageConfig struct {
+	GraphEnabled       bool          `json:"graph_enabled"`
+	GraphSyncInterval  time.Duration `json:"graph_sync_interval"`
+	GraphSyncBatchSize int           `json:"graph_sync_batch_size"`
+	MaxMergeDepth      int           `json:"max_merge_depth"` // levels of merged entities followed in a history
+	MaxEvents          int           `json:"max_events"`      // events returned in a history
+}
+
+// RescoreConfig holds incremental re-resolution configuration. Attribute
+// update events are consumed in their own consumer group; pending merge
+// reviews and dedup candidates involving the updated entity are re-scored,
+// at most MaxPairs per event, and superseded once they fall below the
+// review threshold.
+type RescoreConfig struct {
+	Enabled       bool   `json:"enabled"`
+	ConsumerGroup string `json:"consumer_group"`
+	MaxPairs      int    `json:"max_pairs"`
 }
 
 // LoggingConfig holds logging configuration
@@ -85,6 +200,13 @@ type LoggingConfig struct {
 	Format string `json:"format"`
 }
 
+// StartupConfig holds dependency retry configuration for service startup
+type StartupConfig struct {
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff"`
+	MaxElapsed     time.Duration `json:"max_elapsed"` // 0 retries until shutdown
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	config := &Config{
@@ -110,6 +232,9 @@ func Load() (*Config, error) {
 			ConsumerGroup:         getEnvString("KAFKA_CONSUMER_GROUP", "entity-resolution-service"),
 			TransactionTopic:      getEnvString("KAFKA_TRANSACTION_TOPIC", "transactions.processed"),
 			EntityResolutionTopic: getEnvString("KAFKA_ENTITY_RESOLUTION_TOPIC", "entities.resolved"),
+			ScreeningHitTopic:     getEnvString("KAFKA_SCREENING_HIT_TOPIC", "entity.screening.hit"),
+			AttributeUpdatedTopic: getEnvString("KAFKA_ATTRIBUTE_UPDATED_TOPIC", "entity.attribute.updated"),
+			ValidateTopics:        getEnvBool("KAFKA_VALIDATE_TOPICS", true),
 			BatchSize:             getEnvInt("KAFKA_BATCH_SIZE", 100),
 			BatchTimeout:          getEnvDuration("KAFKA_BATCH_TIMEOUT", 5*time.Second),
 			RetryAttempts:         getEnvInt("KAFKA_RETRY_ATTEMPTS", 3),
@@ -138,11 +263,80 @@ func Load() (*Config, error) {
 			PhoneticMatchingEnabled:    getEnvBool("MATCHING_PHONETIC_ENABLED", true),
 			BlockingEnabled:            getEnvBool("MATCHING_BLOCKING_ENABLED", true),
 			BlockingKeySize:            getEnvInt("MATCHING_BLOCKING_KEY_SIZE", 3),
+			NameAlgorithms:             getEnvStringSlice("MATCHING_NAME_ALGORITHMS", nil),
+			StreetAlgorithms:           getEnvStringSlice("MATCHING_STREET_ALGORITHMS", []string{"levenshtein"}),
+			CityAlgorithms:             getEnvStringSlice("MATCHING_CITY_ALGORITHMS", []string{"levenshtein", "metaphone"}),
+			ModelReloadInterval:        getEnvDuration("MATCHING_MODEL_RELOAD_INTERVAL", 30*time.Second),
+		},
+		Calibration: CalibrationConfig{
+			Enabled:       getEnvBool("CALIBRATION_ENABLED", true),
+			Method:        getEnvString("CALIBRATION_METHOD", "isotonic"),
+			MinSamples:    getEnvInt("CALIBRATION_MIN_SAMPLES", 200),
+			MaxSamples:    getEnvInt("CALIBRATION_MAX_SAMPLES", 50000),
+			SampleWindow:  getEnvDuration("CALIBRATION_SAMPLE_WINDOW", 180*24*time.Hour),
+			RefitInterval: getEnvDuration("CALIBRATION_REFIT_INTERVAL", 24*time.Hour),
+		},
+		Dedup: DedupConfig{
+			BlockKeySize:       getEnvInt("DEDUP_BLOCK_KEY_SIZE", 4),
+			BlockBatchSize:     getEnvInt("DEDUP_BLOCK_BATCH_SIZE", 500),
+			MaxBlockSize:       getEnvInt("DEDUP_MAX_BLOCK_SIZE", 2000),
+			AutoMergeThreshold: getEnvFloat("DEDUP_AUTO_MERGE_THRESHOLD", 0.97),
+			ReviewThreshold:    getEnvFloat("DEDUP_REVIEW_THRESHOLD", 0.75),
+		},
+		Review: ReviewConfig{
+			Enabled:       getEnvBool("REVIEW_ENABLED", true),
+			Threshold:     getEnvFloat("REVIEW_THRESHOLD", 0.75),
+			MaxCandidates: getEnvInt("REVIEW_MAX_CANDIDATES", 10),
+		},
+		Survivorship: SurvivorshipConfig{
+			Enabled:         getEnvBool("SURVIVORSHIP_ENABLED", true),
+			DefaultStrategy: getEnvString("SURVIVORSHIP_DEFAULT_STRATEGY", "most_recent"),
+			Rules:           getEnvStringMap("SURVIVORSHIP_RULES", nil),
+			SourceTrust:     getEnvFloatMap("SURVIVORSHIP_SOURCE_TRUST", nil),
+		},
+		Organization: OrganizationConfig{
+			MaxFamilyDepth:           getEnvInt("ORGANIZATION_MAX_FAMILY_DEPTH", 10),
+			AliasSimilarityThreshold: getEnvFloat("ORGANIZATION_ALIAS_SIMILARITY_THRESHOLD", 0.8),
+			MaxAliasMatches:          getEnvInt("ORGANIZATION_MAX_ALIAS_MATCHES", 25),
+		},
+		Tuning: TuningConfig{
+			MaxCorpusPairs: getEnvInt("TUNING_MAX_CORPUS_PAIRS", 50000),
+			MaxSweepPoints: getEnvInt("TUNING_MAX_SWEEP_POINTS", 5000),
+			DefaultStep:    getEnvFloat("TUNING_DEFAULT_STEP", 0.05),
+		},
+		Screening: ScreeningConfig{
+			Enabled:          getEnvBool("SCREENING_ENABLED", true),
+			Lists:            getEnvStringMap("SCREENING_LISTS", nil),
+			PEPLists:         getEnvStringSlice("SCREENING_PEP_LISTS", []string{"pep"}),
+			Threshold:        getEnvFloat("SCREENING_THRESHOLD", 0.88),
+			ReloadInterval:   getEnvDuration("SCREENING_RELOAD_INTERVAL", 24*time.Hour),
+			FetchTimeout:     getEnvDuration("SCREENING_FETCH_TIMEOUT", 2*time.Minute),
+			APIKey:           getEnvString("SCREENING_API_KEY", ""),
+			MaxListEntries:   getEnvInt("SCREENING_MAX_LIST_ENTRIES", 500000),
+			MaxUploadBytes:   int64(getEnvInt("SCREENING_MAX_UPLOAD_BYTES", 256<<20)),
+			MaxHitsPerEntity: getEnvInt("SCREENING_MAX_HITS_PER_ENTITY", 10),
+		},
+		Lineage: LineageConfig{
+			GraphEnabled:       getEnvBool("LINEAGE_GRAPH_ENABLED", true),
+			GraphSyncInterval:  getEnvDuration("LINEAGE_GRAPH_SYNC_INTERVAL", time.Minute),
+			GraphSyncBatchSize: getEnvInt("LINEAGE_GRAPH_SYNC_BATCH_SIZE", 500),
+			MaxMergeDepth:      getEnvInt("LINEAGE_MAX_MERGE_DEPTH", 20),
+			MaxEvents:          getEnvInt("LINEAGE_MAX_EVENTS", 5000),
+		},
+		Rescore: RescoreConfig{
+			Enabled:       getEnvBool("RESCORE_ENABLED", true),
+			ConsumerGroup: getEnvString("RESCORE_CONSUMER_GROUP", "entity-resolution-rescore"),
+			MaxPairs:      getEnvInt("RESCORE_MAX_PAIRS", 200),
 		},
 		Logging: LoggingConfig{
 			Level:  getEnvString("LOG_LEVEL", "info"),
 			Format: getEnvString("LOG_FORMAT", "json"),
 		},
+		Startup: StartupConfig{
+			InitialBackoff: getEnvDuration("STARTUP_INITIAL_BACKOFF", 500*time.Millisecond),
+			MaxBackoff:     getEnvDuration("STARTUP_MAX_BACKOFF", 30*time.Second),
+			MaxElapsed:     getEnvDuration("STARTUP_MAX_ELAPSED", 5*time.Minute),
+		},
 	}
 
 	return config, config.Validate()
@@ -198,9 +392,109 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("max candidates must be positive")
 	}
 
+	if c.Matching.ModelReloadInterval <= 0 {
+		return fmt.Errorf("matching model reload interval must be positive")
+	}
+
+	if c.Calibration.Method != "platt" && c.Calibration.Method != "isotonic" {
+		return fmt.Errorf("calibration method must be platt or isotonic")
+	}
+
+	if c.Dedup.ReviewThreshold < 0 || c.Dedup.AutoMergeThreshold > 1 || c.Dedup.ReviewThreshold > c.Dedup.AutoMergeThreshold {
+		return fmt.Errorf("dedup thresholds must satisfy 0 <= review <= auto-merge <= 1")
+	}
+
+	if c.Dedup.BlockKeySize <= 0 || c.Dedup.BlockBatchSize <= 0 || c.Dedup.MaxBlockSize <= 1 {
+		return fmt.Errorf("dedup block sizes must be positive")
+	}
+
+	if c.Review.Threshold < 0 || c.Review.Threshold > 1 {
+		return fmt.Errorf("review threshold must be between 0 and 1")
+	}
+
+	if c.Review.MaxCandidates <= 0 {
+		return fmt.Errorf("review max candidates must be positive")
+	}
+
+	if !isSurvivorshipStrategy(c.Survivorship.DefaultStrategy) {
+		return fmt.Errorf("invalid survivorship default strategy: %s", c.Survivorship.DefaultStrategy)
+	}
+
+	for key, strategy := range c.Survivorship.Rules {
+		if !strings.Contains(key, ".") || !isSurvivorshipStrategy(strategy) {
+			return fmt.Errorf("invalid survivorship rule: %s=%s", key, strategy)
+		}
+	}
+
+	for source, trust := range c.Survivorship.SourceTrust {
+		if trust < 0 || trust > 1 {
+			return fmt.Errorf("survivorship trust for source %s must be between 0 and 1", source)
+		}
+	}
+
+	if c.Organization.MaxFamilyDepth <= 0 || c.Organization.MaxAliasMatches <= 0 {
+		return fmt.Errorf("organization family depth and alias match limit must be positive")
+	}
+
+	if c.Organization.AliasSimilarityThreshold < 0 || c.Organization.AliasSimilarityThreshold > 1 {
+		return fmt.Errorf("alias similarity threshold must be between 0 and 1")
+	}
+
+	if c.Tuning.MaxCorpusPairs <= 0 || c.Tuning.MaxSweepPoints <= 0 {
+		return fmt.Errorf("tuning corpus and sweep limits must be positive")
+	}
+
+	if c.Tuning.DefaultStep <= 0 || c.Tuning.DefaultStep > 1 {
+		return fmt.Errorf("tuning default step must be greater than 0 and at most 1")
+	}
+
+	if c.Screening.Threshold <= 0 || c.Screening.Threshold > 1 {
+		return fmt.Errorf("screening threshold must be greater than 0 and at most 1")
+	}
+
+	if c.Screening.ReloadInterval <= 0 || c.Screening.FetchTimeout <= 0 {
+		return fmt.Errorf("screening reload interval and fetch timeout must be positive")
+	}
+
+	if c.Screening.MaxListEntries <= 0 || c.Screening.MaxUploadBytes <= 0 || c.Screening.MaxHitsPerEntity <= 0 {
+		return fmt.Errorf("screening list, upload and hit limits must be positive")
+	}
+
+	if c.Lineage.GraphSyncInterval <= 0 || c.Lineage.GraphSyncBatchSize <= 0 {
+		return fmt.Errorf("lineage graph sync interval and batch size must be positive")
+	}
+
+	if c.Lineage.MaxMergeDepth <= 0 || c.Lineage.MaxEvents <= 0 {
+		return fmt.Errorf("lineage merge depth and event limits must be positive")
+	}
+
+	if c.Rescore.Enabled {
+		if c.Kafka.AttributeUpdatedTopic == "" || c.Rescore.ConsumerGroup == "" {
+			return fmt.Errorf("rescore requires an attribute updated topic and consumer group")
+		}
+		if c.Rescore.ConsumerGroup == c.Kafka.ConsumerGroup {
+			return fmt.Errorf("rescore consumer group must differ from the resolution consumer group")
+		}
+		if c.Rescore.MaxPairs <= 0 {
+			return fmt.Errorf("rescore max pairs must be positive")
+		}
+	}
+
+	if c.Startup.InitialBackoff <= 0 || c.Startup.MaxBackoff < c.Startup.InitialBackoff || c.Startup.MaxElapsed < 0 {
+		return fmt.Errorf("startup backoff must be positive and max backoff at least the initial backoff")
+	}
+
 	return nil
 }
 
+func isSurvivorshipStrategy(strategy string) bool {
+	switch strategy {
+	case "most_recent", "most_trusted_source", "longest", "most_frequent":
+		return true
+	}
+	return false
+}
+
 // DatabaseDSN returns the database connection string
 func (c *Config) DatabaseDSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
@@ -263,4 +557,37 @@ func getEnvStringSlice(key string, defaultValue []string) []string {
 		return strings.Split(value, ",")
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+// getEnvStringMap parses comma-separated key=value pairs
+func getEnvStringMap(key string, defaultValue map[string]string) map[string]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok {
+			result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return result
+}
+
+// getEnvFloatMap parses comma-separated key=number pairs, skipping
+// malformed numbers
+func getEnvFloatMap(key string, defaultValue map[string]float64) map[string]float64 {
+	pairs := getEnvStringMap(key, nil)
+	if pairs == nil {
+		return defaultValue
+	}
+
+	result := make(map[string]float64, len(pairs))
+	for k, v := range pairs {
+		if number, err := strconv.ParseFloat(v, 64); err == nil {
+			result[k] = number
+		}
+	}
+	return result
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MatchReview represents a reviewer's decision on a proposed entity match
+type MatchReview struct {
+	ID                uuid.UUID `json:"id"`
+	SourceEntityID    uuid.UUID `json:"source_entity_id"`
+	CandidateEntityID uuid.UUID `json:"candidate_entity_id"`
+	RawScore          float64   `json:"raw_score"`
+	CalibratedScore   *float64  `json:"calibrated_score,omitempty"`
+	Outcome           string    `json:"outcome"`
+	ReviewerID        string    `json:"reviewer_id"`
+	Notes             string    `json:"notes,omitempty"`
+	ReviewedAt        time.Time `json:"reviewed_at"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// CalibrationModel represents a fitted score calibration curve
+type CalibrationModel struct {
+	ID            uuid.UUID       `json:"id"`
+	Method        string          `json:"method"`
+	Parameters    json.RawMessage `json:"parameters"`
+	SampleCount   int             `json:"sample_count"`
+	PositiveCount int             `json:"positive_count"`
+	BrierScore    float64         `json:"brier_score"`
+	LogLoss       float64         `json:"log_loss"`
+	IsActive      bool            `json:"is_active"`
+	FittedAt      time.Time       `json:"fitted_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// Calibration operations
+
+// CreateMatchReview records a reviewer outcome for a proposed match
+func (r *Repository) CreateMatchReview(ctx context.Context, review *MatchReview) error {
+	query := `
+		INSERT INTO match_reviews (
+			id, source_entity_id, candidate_entity_id, raw_score,
+			calibrated_score, outcome, reviewer_id, notes, reviewed_at, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		review.ID,
+		review.SourceEntityID,
+		review.CandidateEntityID,
+		review.RawScore,
+		review.CalibratedScore,
+		review.Outcome,
+		review.ReviewerID,
+		review.Notes,
+		review.ReviewedAt,
+		review.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create match review: %w", err)
+	}
+
+	return nil
+}
+
+// ListMatchReviews retrieves reviews recorded since the given time, newest first
+func (r *Repository) ListMatchReviews(ctx context.Context, since time.Time, limit int) ([]*MatchReview, error) {
+	query := `
+		SELECT id, source_entity_id, candidate_entity_id, raw_score,
+			   calibrated_score, outcome, reviewer_id, COALESCE(notes, ''),
+			   reviewed_at, created_at
+		FROM match_reviews
+		WHERE reviewed_at >= $1
+		ORDER BY reviewed_at DESC
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list match reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []*MatchReview
+	for rows.Next() {
+		review := &MatchReview{}
+
+		err := rows.Scan(
+			&review.ID,
+			&review.SourceEntityID,
+			&review.CandidateEntityID,
+			&review.RawScore,
+			&review.CalibratedScore,
+			&review.Outcome,
+			&review.ReviewerID,
+			&review.Notes,
+			&review.ReviewedAt,
+			&review.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match review: %w", err)
+		}
+
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating match reviews: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// SaveCalibrationModel stores a fitted calibration model. When the model is
+// active, any previously active model is deactivated in the same transaction.
+func (r *Repository) SaveCalibrationModel(ctx context.Context, model *CalibrationModel) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if model.IsActive {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE calibration_models SET is_active = false WHERE is_active`); err != nil {
+			return fmt.Errorf("failed to deactivate calibration models: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO calibration_models (
+			id, method, parameters, sample_count, positive_count,
+			brier_score, log_loss, is_active, fitted_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)`
+
+	_, err = tx.ExecContext(ctx, query,
+		model.ID,
+		model.Method,
+		model.Parameters,
+		model.SampleCount,
+		model.PositiveCount,
+		model.BrierScore,
+		model.LogLoss,
+		model.IsActive,
+		model.FittedAt,
+		model.CreatedAt,
+		model.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create calibration model: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit calibration model: %w", err)
+	}
+
+	return nil
+}
+
+// GetActiveCalibrationModel retrieves the currently active calibration model
+func (r *Repository) GetActiveCalibrationModel(ctx context.Context) (*CalibrationModel, error) {
+	model := &CalibrationModel{}
+	query := `
+		SELECT id, method, parameters, sample_count, positive_count,
+			   COALESCE(brier_score, 0), COALESCE(log_loss, 0), is_active,
+			   fitted_at, created_at, updated_at
+		FROM calibration_models
+		WHERE is_active
+		LIMIT 1`
+
+	err := r.db.QueryRowContext(ctx, query).Scan(
+		&model.ID,
+		&model.Method,
+		&model.Parameters,
+		&model.SampleCount,
+		&model.PositiveCount,
+		&model.BrierScore,
+		&model.LogLoss,
+		&model.IsActive,
+		&model.FittedAt,
+		&model.CreatedAt,
+		&model.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active calibration model: %w", err)
+	}
+
+	return model, nil
+}
@@ -0,0 +1,719 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Dedup run statuses
+const (
+	DedupRunPending   = "pending"
+	DedupRunRunning   = "running"
+	DedupRunCompleted = "completed"
+	DedupRunFailed    = "failed"
+	DedupRunCancelled = "cancelled"
+)
+
+// Merge candidate statuses
+const (
+	CandidateAutoMerged    = "auto_merged"
+	CandidatePendingReview = "pending_review"
+	CandidateMerged        = "merged"
+	CandidateRejected      = "rejected"
+	CandidateSuperseded    = "superseded"
+)
+
+var (
+	// ErrDedupRunActive is returned when a backlog run is already pending or running
+	ErrDedupRunActive = errors.New("a dedup run is already active")
+	// ErrAlreadyMerged is returned when either side of a pair was merged elsewhere
+	ErrAlreadyMerged = errors.New("entity has already been merged")
+	// ErrCandidateNotPending is returned when a reviewed candidate is no longer in the queue
+	ErrCandidateNotPending = errors.New("merge candidate is not pending review")
+)
+
+// DedupRun represents a backlog deduplication scan
+type DedupRun struct {
+	ID                 uuid.UUID  `json:"id"`
+	Status             string     `json:"status"`
+	EntityType         string     `json:"entity_type,omitempty"`
+	BlockKeySize       int        `json:"block_key_size"`
+	AutoMergeThreshold float64    `json:"auto_merge_threshold"`
+	ReviewThreshold    float64    `json:"review_threshold"`
+	TotalBlocks        int        `json:"total_blocks"`
+	ProcessedBlocks    int        `json:"processed_blocks"`
+	SkippedBlocks      int        `json:"skipped_blocks"`
+	EntitiesScanned    int64      `json:"entities_scanned"`
+	CandidatesFound    int        `json:"candidates_found"`
+	AutoMerged         int        `json:"auto_merged"`
+	QueuedForReview    int        `json:"queued_for_review"`
+	CursorEntityType   string     `json:"cursor_entity_type,omitempty"`
+	CursorBlockKey     string     `json:"cursor_block_key,omitempty"`
+	ErrorMessage       string     `json:"error_message,omitempty"`
+	CreatedBy          string     `json:"created_by"`
+	StartedAt          *time.Time `json:"started_at,omitempty"`
+	CompletedAt        *time.Time `json:"completed_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// DedupBlock identifies a group of entities sharing a blocking key
+type DedupBlock struct {
+	EntityType string `json:"entity_type"`
+	Key        string `json:"key"`
+	Size       int    `json:"size"`
+}
+
+// MergeCandidate represents a scored duplicate pair found by a backlog run
+type MergeCandidate struct {
+	ID                uuid.UUID       `json:"id"`
+	RunID             uuid.UUID       `json:"run_id"`
+	SurvivorEntityID  uuid.UUID       `json:"survivor_entity_id"`
+	DuplicateEntityID uuid.UUID       `json:"duplicate_entity_id"`
+	EntityType        string          `json:"entity_type"`
+	BlockKey          string          `json:"block_key"`
+	RawScore          float64         `json:"raw_score"`
+	CalibratedScore   float64         `json:"calibrated_score"`
+	Evidence          json.RawMessage `json:"evidence"`
+	Status            string          `json:"status"`
+	ReviewerID        string          `json:"reviewer_id,omitempty"`
+	ReviewNotes       string          `json:"review_notes,omitempty"`
+	ReviewedAt        *time.Time      `json:"reviewed_at,omitempty"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+// MergeCandidateFilter narrows a merge candidate listing
+type MergeCandidateFilter struct {
+	RunID  *uuid.UUID
+	Status string
+	Limit  int
+	Offset int
+}
+
+// MergeCandidateSummary aggregates a run's candidates for the dedup report
+type MergeCandidateSummary struct {
+	ByStatus    map[string]int `json:"by_status"`
+	ScoreBands  map[string]int `json:"score_bands"`
+	TopBlocks   []DedupBlock   `json:"top_blocks"`
+	TotalScored int            `json:"total_scored"`
+}
+
+// Dedup run operations
+
+const dedupRunColumns = `
+	id, status, COALESCE(entity_type, ''), block_key_size, auto_merge_threshold,
+	review_threshold, total_blocks, processed_blocks, skipped_blocks,
+	entities_scanned, candidates_found, auto_merged, queued_for_review,
+	COALESCE(cursor_entity_type, ''), COALESCE(cursor_block_key, ''),
+	COALESCE(error_message, ''), created_by, started_at, completed_at,
+	created_at, updated_at`
+
+// CreateDedupRun creates a new backlog run; only one run may be active at a time
+func (r *Repository) CreateDedupRun(ctx context.Context, run *DedupRun) error {
+	query := `
+		INSERT INTO dedup_runs (
+			id, status, entity_type, block_key_size, auto_merge_threshold,
+			review_threshold, created_by, created_at, updated_at
+		) VALUES (
+			$1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9
+		)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		run.ID,
+		run.Status,
+		run.EntityType,
+		run.BlockKeySize,
+		run.AutoMergeThreshold,
+		run.ReviewThreshold,
+		run.CreatedBy,
+		run.CreatedAt,
+		run.UpdatedAt,
+	)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrDedupRunActive
+		}
+		return fmt.Errorf("failed to create dedup run: %w", err)
+	}
+
+	return nil
+}
+
+// GetDedupRun retrieves a dedup run by ID
+func (r *Repository) GetDedupRun(ctx context.Context, id uuid.UUID) (*DedupRun, error) {
+	query := `SELECT ` + dedupRunColumns + ` FROM dedup_runs WHERE id = $1`
+
+	run, err := scanDedupRun(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("dedup run not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get dedup run: %w", err)
+	}
+
+	return run, nil
+}
+
+// GetActiveDedupRun retrieves the pending or running dedup run, if any
+func (r *Repository) GetActiveDedupRun(ctx context.Context) (*DedupRun, error) {
+	query := `SELECT ` + dedupRunColumns + ` FROM dedup_runs
+		WHERE status IN ('pending', 'running')
+		LIMIT 1`
+
+	run, err := scanDedupRun(r.db.QueryRowContext(ctx, query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active dedup run: %w", err)
+	}
+
+	return run, nil
+}
+
+// ListDedupRuns retrieves dedup runs, newest first
+func (r *Repository) ListDedupRuns(ctx context.Context, limit int) ([]*DedupRun, error) {
+	query := `SELECT ` + dedupRunColumns + ` FROM dedup_runs
+		ORDER BY created_at DESC
+		LIMIT $1`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dedup runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*DedupRun
+	for rows.Next() {
+		run, err := scanDedupRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dedup run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dedup runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// UpdateDedupRun persists a run's status, progress counters and cursor. It
+// reports false when the run is no longer active, e.g. it was cancelled.
+func (r *Repository) UpdateDedupRun(ctx context.Context, run *DedupRun) (bool, error) {
+	query := `
+		UPDATE dedup_runs SET
+			status = $2, total_blocks = $3, processed_blocks = $4,
+			skipped_blocks = $5, entities_scanned = $6, candidates_found = $7,
+			auto_merged = $8, queued_for_review = $9,
+			cursor_entity_type = NULLIF($10, ''), cursor_block_key = NULLIF($11, ''),
+			error_message = NULLIF($12, ''), started_at = $13, completed_at = $14
+		WHERE id = $1 AND status IN ('pending', 'running')`
+
+	result, err := r.db.ExecContext(ctx, query,
+		run.ID,
+		run.Status,
+		run.TotalBlocks,
+		run.ProcessedBlocks,
+		run.SkippedBlocks,
+		run.EntitiesScanned,
+		run.CandidatesFound,
+		run.AutoMerged,
+		run.QueuedForReview,
+		run.CursorEntityType,
+		run.CursorBlockKey,
+		run.ErrorMessage,
+		run.StartedAt,
+		run.CompletedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update dedup run: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update dedup run: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// CancelDedupRun marks an active run cancelled; the worker stops at the next block
+func (r *Repository) CancelDedupRun(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE dedup_runs SET status = 'cancelled', completed_at = $2
+		WHERE id = $1 AND status IN ('pending', 'running')`, id, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel dedup run: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel dedup run: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDedupRun(row rowScanner) (*DedupRun, error) {
+	run := &DedupRun{}
+	err := row.Scan(
+		&run.ID,
+		&run.Status,
+		&run.EntityType,
+		&run.BlockKeySize,
+		&run.AutoMergeThreshold,
+		&run.ReviewThreshold,
+		&run.TotalBlocks,
+		&run.ProcessedBlocks,
+		&run.SkippedBlocks,
+		&run.EntitiesScanned,
+		&run.CandidatesFound,
+		&run.AutoMerged,
+		&run.QueuedForReview,
+		&run.CursorEntityType,
+		&run.CursorBlockKey,
+		&run.ErrorMessage,
+		&run.CreatedBy,
+		&run.StartedAt,
+		&run.CompletedAt,
+		&run.CreatedAt,
+		&run.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Blocking operations
+
+// CountDedupBlocks counts blocks holding at least two unmerged entities
+func (r *Repository) CountDedupBlocks(ctx context.Context, entityType string, keySize int) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM (
+			SELECT 1
+			FROM entities
+			WHERE merged_into_id IS NULL
+			  AND standardized_name IS NOT NULL AND standardized_name <> ''
+			  AND ($1 = '' OR entity_type = $1)
+			GROUP BY entity_type, LOWER(LEFT(standardized_name, $2))
+			HAVING COUNT(*) > 1
+		) AS blocks`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, entityType, keySize).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count dedup blocks: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListDedupBlocks returns the next blocks after the cursor in a stable order
+func (r *Repository) ListDedupBlocks(ctx context.Context, entityType string, keySize int, afterType, afterKey string, limit int) ([]DedupBlock, error) {
+	query := `
+		SELECT entity_type, LOWER(LEFT(standardized_name, $2)) AS block_key, COUNT(*)
+		FROM entities
+		WHERE merged_into_id IS NULL
+		  AND standardized_name IS NOT NULL AND standardized_name <> ''
+		  AND ($1 = '' OR entity_type = $1)
+		GROUP BY entity_type, LOWER(LEFT(standardized_name, $2))
+		HAVING COUNT(*) > 1
+		   AND (entity_type, LOWER(LEFT(standardized_name, $2))) > ($3, $4)
+		ORDER BY entity_type, block_key
+		LIMIT $5`
+
+	rows, err := r.db.QueryContext(ctx, query, entityType, keySize, afterType, afterKey, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dedup blocks: %w", err)
+	}
+	defer rows.Close()
+
+	var blocks []DedupBlock
+	for rows.Next() {
+		var block DedupBlock
+		if err := rows.Scan(&block.EntityType, &block.Key, &block.Size); err != nil {
+			return nil, fmt.Errorf("failed to scan dedup block: %w", err)
+		}
+		blocks = append(blocks, block)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dedup blocks: %w", err)
+	}
+
+	return blocks, nil
+}
+
+// ListBlockEntities retrieves the unmerged entities in a block, oldest first
+func (r *Repository) ListBlockEntities(ctx context.Context, block DedupBlock, keySize, limit int) ([]*Entity, error) {
+	query := `
+		SELECT id, entity_type, COALESCE(name, ''), COALESCE(standardized_name, ''),
+			   COALESCE(identifiers, '{}'), COALESCE(attributes, '{}'),
+			   confidence_score, created_at, updated_at
+		FROM entities
+		WHERE merged_into_id IS NULL
+		  AND entity_type = $1
+		  AND LOWER(LEFT(standardized_name, $3)) = $2
+		ORDER BY created_at, id
+		LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, query, block.EntityType, block.Key, keySize, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list block entities: %w", err)
+	}
+	defer rows.Close()
+
+	var entities []*Entity
+	for rows.Next() {
+		entity := &Entity{}
+		err := rows.Scan(
+			&entity.ID,
+			&entity.EntityType,
+			&entity.Name,
+			&entity.StandardizedName,
+			&entity.Identifiers,
+			&entity.Attributes,
+			&entity.ConfidenceScore,
+			&entity.CreatedAt,
+			&entity.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan block entity: %w", err)
+		}
+		entities = append(entities, entity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating block entities: %w", err)
+	}
+
+	return entities, nil
+}
+
+// Merge candidate operations
+
+// CreateMergeCandidate records a scored pair; pairs already found by the run are ignored
+func (r *Repository) CreateMergeCandidate(ctx context.Context, candidate *MergeCandidate) error {
+	query := `
+		INSERT INTO merge_candidates (
+			id, run_id, survivor_entity_id, duplicate_entity_id, entity_type,
+			block_key, raw_score, calibrated_score, evidence, status,
+			created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		)
+		ON CONFLICT (run_id, survivor_entity_id, duplicate_entity_id) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query,
+		candidate.ID,
+		candidate.RunID,
+		candidate.SurvivorEntityID,
+		candidate.DuplicateEntityID,
+		candidate.EntityType,
+		candidate.BlockKey,
+		candidate.RawScore,
+		candidate.CalibratedScore,
+		candidate.Evidence,
+		candidate.Status,
+		candidate.CreatedAt,
+		candidate.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create merge candidate: %w", err)
+	}
+
+	return nil
+}
+
+const mergeCandidateColumns = `
+	id, run_id, survivor_entity_id, duplicate_entity_id, entity_type, block_key,
+	raw_score, calibrated_score, COALESCE(evidence, '{}'), status, COALESCE(reviewer_id, ''),
+	COALESCE(review_notes, ''), reviewed_at, created_at, updated_at`
+
+// GetMergeCandidate retrieves a merge candidate by ID
+func (r *Repository) GetMergeCandidate(ctx context.Context, id uuid.UUID) (*MergeCandidate, error) {
+	query := `SELECT ` + mergeCandidateColumns + ` FROM merge_candidates WHERE id = $1`
+
+	candidate, err := scanMergeCandidate(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("merge candidate not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get merge candidate: %w", err)
+	}
+
+	return candidate, nil
+}
+
+// ListMergeCandidates retrieves candidates ranked by calibrated score
+func (r *Repository) ListMergeCandidates(ctx context.Context, filter MergeCandidateFilter) ([]*MergeCandidate, error) {
+	query := `SELECT ` + mergeCandidateColumns + ` FROM merge_candidates
+		WHERE ($1::uuid IS NULL OR run_id = $1)
+		  AND ($2 = '' OR status = $2)
+		ORDER BY calibrated_score DESC, created_at
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.RunID, filter.Status, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []*MergeCandidate
+	for rows.Next() {
+		candidate, err := scanMergeCandidate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan merge candidate: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating merge candidates: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// SummarizeMergeCandidates aggregates a run's candidates by status, score band and block
+func (r *Repository) SummarizeMergeCandidates(ctx context.Context, runID uuid.UUID) (*MergeCandidateSummary, error) {
+	summary := &MergeCandidateSummary{
+		ByStatus:   make(map[string]int),
+		ScoreBands: make(map[string]int),
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT status, COUNT(*) FROM merge_candidates
+		WHERE run_id = $1
+		GROUP BY status`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize merge candidates: %w", err)
+	}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan merge candidate summary: %w", err)
+		}
+		summary.ByStatus[status] = count
+		summary.TotalScored += count
+	}
+	rows.Close()
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT CASE
+				 WHEN calibrated_score >= 0.95 THEN '0.95-1.00'
+				 WHEN calibrated_score >= 0.90 THEN '0.90-0.95'
+				 WHEN calibrated_score >= 0.80 THEN '0.80-0.90'
+				 ELSE 'below-0.80'
+			   END AS band,
+			   COUNT(*)
+		FROM merge_candidates
+		WHERE run_id = $1
+		GROUP BY band`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize merge candidate scores: %w", err)
+	}
+	for rows.Next() {
+		var band string
+		var count int
+		if err := rows.Scan(&band, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan merge candidate score band: %w", err)
+		}
+		summary.ScoreBands[band] = count
+	}
+	rows.Close()
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT entity_type, block_key, COUNT(*) AS pairs
+		FROM merge_candidates
+		WHERE run_id = $1
+		GROUP BY entity_type, block_key
+		ORDER BY pairs DESC
+		LIMIT 10`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize merge candidate blocks: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var block DedupBlock
+		if err := rows.Scan(&block.EntityType, &block.Key, &block.Size); err != nil {
+			return nil, fmt.Errorf("failed to scan merge candidate block: %w", err)
+		}
+		summary.TopBlocks = append(summary.TopBlocks, block)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating merge candidate blocks: %w", err)
+	}
+
+	return summary, nil
+}
+
+// MergeEntities folds the duplicate into the survivor and records the merge
+// against the candidate. Other open candidates involving the duplicate are
+// superseded. Both entities are locked so concurrent merges cannot chain.
+func (r *Repository) MergeEntities(ctx context.Context, candidate *MergeCandidate, status, reviewerID, notes string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var merged int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT id FROM entities
+			WHERE id IN ($1, $2) AND merged_into_id IS NULL
+			FOR UPDATE
+		) AS locked`,
+		candidate.SurvivorEntityID, candidate.DuplicateEntityID).Scan(&merged)
+	if err != nil {
+		return fmt.Errorf("failed to lock entities for merge: %w", err)
+	}
+	if merged != 2 {
+		return ErrAlreadyMerged
+	}
+
+	survivorBefore, err := entityFieldsTx(ctx, tx, candidate.SurvivorEntityID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	// Survivor values win on conflicting keys
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE entities AS survivor SET
+			identifiers = COALESCE(duplicate.identifiers, '{}') || COALESCE(survivor.identifiers, '{}'),
+			attributes = COALESCE(duplicate.attributes, '{}') || COALESCE(survivor.attributes, '{}')
+		FROM entities AS duplicate
+		WHERE survivor.id = $1 AND duplicate.id = $2`,
+		candidate.SurvivorEntityID, candidate.DuplicateEntityID); err != nil {
+		return fmt.Errorf("failed to merge entity data: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE entities SET merged_into_id = $1, merged_at = $3
+		WHERE id = $2`,
+		candidate.SurvivorEntityID, candidate.DuplicateEntityID, now); err != nil {
+		return fmt.Errorf("failed to mark entity merged: %w", err)
+	}
+
+	properties, err := json.Marshal(map[string]interface{}{
+		"merge_candidate_id": candidate.ID,
+		"dedup_run_id":       candidate.RunID,
+		"raw_score":          candidate.RawScore,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal merge link properties: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO entity_links (
+			id, source_entity_id, target_entity_id, link_type, properties,
+			confidence_score, created_at, updated_at
+		) VALUES ($1, $2, $3, 'merged_into', $4, $5, $6, $6)`,
+		uuid.New(), candidate.DuplicateEntityID, candidate.SurvivorEntityID,
+		properties, candidate.CalibratedScore, now); err != nil {
+		return fmt.Errorf("failed to create merge link: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE merge_candidates SET
+			status = $2, reviewer_id = NULLIF($3, ''), review_notes = NULLIF($4, ''),
+			reviewed_at = CASE WHEN $3 = '' THEN NULL ELSE $5::timestamptz END
+		WHERE id = $1`,
+		candidate.ID, status, reviewerID, notes, now); err != nil {
+		return fmt.Errorf("failed to update merge candidate: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE merge_candidates SET status = 'superseded'
+		WHERE id <> $1 AND status = 'pending_review'
+		  AND (survivor_entity_id = $2 OR duplicate_entity_id = $2)`,
+		candidate.ID, candidate.DuplicateEntityID); err != nil {
+		return fmt.Errorf("failed to supersede merge candidates: %w", err)
+	}
+
+	actor := reviewerID
+	if actor == "" {
+		actor = "dedup"
+	}
+	if err := recordMergeLineageTx(ctx, tx, LineageEntityMerged, candidate.SurvivorEntityID, candidate.DuplicateEntityID,
+		survivorBefore, actor, candidate.ID.String(), now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit merge: %w", err)
+	}
+
+	return nil
+}
+
+// RejectMergeCandidate records a reviewer rejection of a queued pair
+func (r *Repository) RejectMergeCandidate(ctx context.Context, id uuid.UUID, reviewerID, notes string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE merge_candidates SET
+			status = 'rejected', reviewer_id = $2, review_notes = NULLIF($3, ''), reviewed_at = $4
+		WHERE id = $1 AND status = 'pending_review'`,
+		id, reviewerID, notes, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to reject merge candidate: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to reject merge candidate: %w", err)
+	}
+	if affected == 0 {
+		return ErrCandidateNotPending
+	}
+
+	return nil
+}
+
+func scanMergeCandidate(row rowScanner) (*MergeCandidate, error) {
+	candidate := &MergeCandidate{}
+	err := row.Scan(
+		&candidate.ID,
+		&candidate.RunID,
+		&candidate.SurvivorEntityID,
+		&candidate.DuplicateEntityID,
+		&candidate.EntityType,
+		&candidate.BlockKey,
+		&candidate.RawScore,
+		&candidate.CalibratedScore,
+		&candidate.Evidence,
+		&candidate.Status,
+		&candidate.ReviewerID,
+		&candidate.ReviewNotes,
+		&candidate.ReviewedAt,
+		&candidate.CreatedAt,
+		&candidate.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return candidate, nil
+}
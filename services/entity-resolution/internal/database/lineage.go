@@ -0,0 +1,306 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Lineage event types
+const (
+	// LineageCreated records an entity created from a source record
+	LineageCreated = "created"
+	// LineageRecordMerged records a source record resolved into an existing entity
+	LineageRecordMerged = "record_merged"
+	// LineageEntityMerged records another entity folded into the entity
+	LineageEntityMerged = "entity_merged"
+	// LineageEntitySplit records a merged entity restored from the entity
+	LineageEntitySplit = "entity_split"
+	// LineageAttributesUpdated records identifier or attribute values changed
+	// by an upstream source record
+	LineageAttributesUpdated = "attributes_updated"
+)
+
+// AttributeChange is one identifier or attribute value changed by a lineage
+// event. A missing old value means the key was added; a missing new value
+// means it was removed.
+type AttributeChange struct {
+	Field     string          `json:"field"`
+	Attribute string          `json:"attribute"`
+	OldValue  json.RawMessage `json:"old_value,omitempty"`
+	NewValue  json.RawMessage `json:"new_value,omitempty"`
+}
+
+// LineageEvent is one step in how an entity was assembled: its creation, a
+// source record resolved into it, or another entity merged into or split
+// from it. Merges and splits are recorded against the surviving entity with
+// the other entity as the related entity.
+type LineageEvent struct {
+	ID              uuid.UUID          `json:"id"`
+	EntityID        uuid.UUID          `json:"entity_id"`
+	EventType       string             `json:"event_type"`
+	RelatedEntityID *uuid.UUID         `json:"related_entity_id,omitempty"`
+	SourceID        string             `json:"source_id,omitempty"`
+	Actor           string             `json:"actor,omitempty"`
+	ReferenceID     string             `json:"reference_id,omitempty"` // merge review or merge candidate
+	Changes         []*AttributeChange `json:"changes"`
+	OccurredAt      time.Time          `json:"occurred_at"`
+	GraphSyncedAt   *time.Time         `json:"graph_synced_at,omitempty"`
+}
+
+// DiffEntityFields lists the identifier and attribute values that differ
+// between two versions of an entity, ordered by field and key
+func DiffEntityFields(before, after EntityFields) ([]*AttributeChange, error) {
+	identifiers, err := diffMap("identifiers", before.Identifiers, after.Identifiers)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := diffMap("attributes", before.Attributes, after.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	return append(identifiers, attributes...), nil
+}
+
+func diffMap(field string, before, after map[string]interface{}) ([]*AttributeChange, error) {
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []*AttributeChange
+	for _, key := range keys {
+		oldValue, hadOld := before[key]
+		newValue, hasNew := after[key]
+		if hadOld == hasNew && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		change := &AttributeChange{Field: field, Attribute: key}
+		if hadOld {
+			encoded, err := json.Marshal(oldValue)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode %s.%s: %w", field, key, err)
+			}
+			change.OldValue = encoded
+		}
+		if hasNew {
+			encoded, err := json.Marshal(newValue)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode %s.%s: %w", field, key, err)
+			}
+			change.NewValue = encoded
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// Lineage operations
+
+// RecordLineageEvent appends an event to the lineage audit trail
+func (r *Repository) RecordLineageEvent(ctx context.Context, event *LineageEvent) error {
+	return insertLineageEvent(ctx, r.db, event)
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx so lineage events can be
+// recorded inside the transaction that makes the change
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertLineageEvent(ctx context.Context, db execer, event *LineageEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.Changes == nil {
+		event.Changes = []*AttributeChange{}
+	}
+
+	changes, err := json.Marshal(event.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lineage changes: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO entity_lineage_events (
+			id, entity_id, event_type, related_entity_id, source_id, actor,
+			reference_id, changes, occurred_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		event.ID,
+		event.EntityID,
+		event.EventType,
+		event.RelatedEntityID,
+		event.SourceID,
+		event.Actor,
+		event.ReferenceID,
+		changes,
+		event.OccurredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record lineage event: %w", err)
+	}
+
+	return nil
+}
+
+// recordMergeLineageTx records, inside a merge or split transaction, the
+// event and the survivor's field changes made by it
+func recordMergeLineageTx(ctx context.Context, tx *sql.Tx, eventType string, survivorID, otherID uuid.UUID, before EntityFields, actor, referenceID string, occurredAt time.Time) error {
+	after, err := entityFieldsTx(ctx, tx, survivorID)
+	if err != nil {
+		return err
+	}
+
+	changes, err := DiffEntityFields(before, after)
+	if err != nil {
+		return err
+	}
+
+	return insertLineageEvent(ctx, tx, &LineageEvent{
+		EntityID:        survivorID,
+		EventType:       eventType,
+		RelatedEntityID: &otherID,
+		Actor:           actor,
+		ReferenceID:     referenceID,
+		Changes:         changes,
+		OccurredAt:      occurredAt,
+	})
+}
+
+// entityFieldsTx reads an entity's identifiers and attributes within a transaction
+func entityFieldsTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (EntityFields, error) {
+	var fields EntityFields
+	var identifiers, attributes []byte
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(identifiers, '{}'), COALESCE(attributes, '{}')
+		FROM entities WHERE id = $1`, id).Scan(&identifiers, &attributes); err != nil {
+		return fields, fmt.Errorf("failed to read entity fields: %w", err)
+	}
+
+	if err := json.Unmarshal(identifiers, &fields.Identifiers); err != nil {
+		return fields, fmt.Errorf("failed to decode entity identifiers: %w", err)
+	}
+	if err := json.Unmarshal(attributes, &fields.Attributes); err != nil {
+		return fields, fmt.Errorf("failed to decode entity attributes: %w", err)
+	}
+
+	return fields, nil
+}
+
+const lineageEventColumns = `
+	id, entity_id, event_type, related_entity_id, source_id, actor,
+	reference_id, changes, occurred_at, graph_synced_at`
+
+// ListLineageEvents retrieves the events recorded against, or naming as the
+// related entity, any of the given entities, oldest first
+func (r *Repository) ListLineageEvents(ctx context.Context, entityIDs []uuid.UUID, limit int) ([]*LineageEvent, error) {
+	values := make([]string, len(entityIDs))
+	for i, id := range entityIDs {
+		values[i] = id.String()
+	}
+
+	query := `SELECT ` + lineageEventColumns + ` FROM entity_lineage_events
+		WHERE entity_id = ANY($1::uuid[]) OR related_entity_id = ANY($1::uuid[])
+		ORDER BY occurred_at, id
+		LIMIT $2`
+
+	return r.queryLineageEvents(ctx, query, pq.Array(values), limit)
+}
+
+// ListUnsyncedLineageEvents retrieves events not yet mirrored in the graph, oldest first
+func (r *Repository) ListUnsyncedLineageEvents(ctx context.Context, limit int) ([]*LineageEvent, error) {
+	query := `SELECT ` + lineageEventColumns + ` FROM entity_lineage_events
+		WHERE graph_synced_at IS NULL
+		ORDER BY occurred_at, id
+		LIMIT $1`
+
+	return r.queryLineageEvents(ctx, query, limit)
+}
+
+// MarkLineageEventsSynced records that events have been mirrored in the graph
+func (r *Repository) MarkLineageEventsSynced(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE entity_lineage_events SET graph_synced_at = $2
+		WHERE id = ANY($1::uuid[])`,
+		pq.Array(values), time.Now()); err != nil {
+		return fmt.Errorf("failed to mark lineage events synced: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) queryLineageEvents(ctx context.Context, query string, args ...interface{}) ([]*LineageEvent, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lineage events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*LineageEvent
+	for rows.Next() {
+		event, err := scanLineageEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan lineage event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating lineage events: %w", err)
+	}
+
+	return events, nil
+}
+
+func scanLineageEvent(row rowScanner) (*LineageEvent, error) {
+	event := &LineageEvent{}
+	var changes []byte
+	err := row.Scan(
+		&event.ID,
+		&event.EntityID,
+		&event.EventType,
+		&event.RelatedEntityID,
+		&event.SourceID,
+		&event.Actor,
+		&event.ReferenceID,
+		&changes,
+		&event.OccurredAt,
+		&event.GraphSyncedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(changes, &event.Changes); err != nil {
+		return nil, fmt.Errorf("failed to decode lineage changes: %w", err)
+	}
+
+	return event, nil
+}
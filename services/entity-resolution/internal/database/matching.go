@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrMatchingModelNotFound is returned for entity types without a stored model
+var ErrMatchingModelNotFound = errors.New("matching model not found")
+
+// MatchingModel is a matching model for an entity type saved through the
+// admin API. A zero threshold inherits the default model's threshold.
+type MatchingModel struct {
+	EntityType  string              `json:"entity_type"`
+	Weights     map[string]float64  `json:"weights"`
+	Comparators map[string][]string `json:"comparators,omitempty"`
+	Threshold   float64             `json:"threshold,omitempty"`
+	Revision    int64               `json:"revision"`
+	UpdatedBy   string              `json:"updated_by"`
+	Notes       string              `json:"notes,omitempty"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// MatchingModelsRevision identifies the stored set of matching models. Any
+// save raises the revision and any delete lowers the count, so the pair
+// changes whenever the models do.
+type MatchingModelsRevision struct {
+	Revision int64 `json:"revision"`
+	Count    int   `json:"count"`
+}
+
+// Matching model operations
+
+// SaveMatchingModel stores an entity type's matching model, replacing any
+// earlier model, and sets the revision it was saved at
+func (r *Repository) SaveMatchingModel(ctx context.Context, model *MatchingModel) error {
+	weights, err := json.Marshal(model.Weights)
+	if err != nil {
+		return fmt.Errorf("failed to marshal matching model weights: %w", err)
+	}
+	comparators, err := json.Marshal(model.Comparators)
+	if err != nil {
+		return fmt.Errorf("failed to marshal matching model comparators: %w", err)
+	}
+
+	var threshold sql.NullFloat64
+	if model.Threshold > 0 {
+		threshold = sql.NullFloat64{Float64: model.Threshold, Valid: true}
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO matching_models (
+			entity_type, weights, comparators, threshold, updated_by, notes, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (entity_type) DO UPDATE SET
+			weights = EXCLUDED.weights,
+			comparators = EXCLUDED.comparators,
+			threshold = EXCLUDED.threshold,
+			revision = nextval('matching_model_revisions'),
+			updated_by = EXCLUDED.updated_by,
+			notes = EXCLUDED.notes,
+			updated_at = EXCLUDED.updated_at
+		RETURNING revision`,
+		model.EntityType,
+		weights,
+		comparators,
+		threshold,
+		model.UpdatedBy,
+		nullString(model.Notes),
+		model.UpdatedAt,
+	).Scan(&model.Revision)
+	if err != nil {
+		return fmt.Errorf("failed to save matching model: %w", err)
+	}
+
+	return nil
+}
+
+// ListMatchingModels retrieves every stored matching model
+func (r *Repository) ListMatchingModels(ctx context.Context) ([]*MatchingModel, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT entity_type, weights, comparators, threshold, revision, updated_by, notes, updated_at
+		FROM matching_models
+		ORDER BY entity_type`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list matching models: %w", err)
+	}
+	defer rows.Close()
+
+	var models []*MatchingModel
+	for rows.Next() {
+		var (
+			model       MatchingModel
+			weights     []byte
+			comparators []byte
+			threshold   sql.NullFloat64
+			notes       sql.NullString
+		)
+		if err := rows.Scan(
+			&model.EntityType,
+			&weights,
+			&comparators,
+			&threshold,
+			&model.Revision,
+			&model.UpdatedBy,
+			&notes,
+			&model.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan matching model: %w", err)
+		}
+
+		if err := json.Unmarshal(weights, &model.Weights); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal matching model weights: %w", err)
+		}
+		if err := json.Unmarshal(comparators, &model.Comparators); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal matching model comparators: %w", err)
+		}
+		model.Threshold = threshold.Float64
+		model.Notes = notes.String
+		models = append(models, &model)
+	}
+
+	return models, rows.Err()
+}
+
+// DeleteMatchingModel removes an entity type's matching model so the type
+// falls back to the default model
+func (r *Repository) DeleteMatchingModel(ctx context.Context, entityType string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM matching_models WHERE entity_type = $1`, entityType)
+	if err != nil {
+		return fmt.Errorf("failed to delete matching model: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete matching model: %w", err)
+	}
+	if affected == 0 {
+		return ErrMatchingModelNotFound
+	}
+
+	return nil
+}
+
+// GetMatchingModelsRevision retrieves the revision of the stored matching
+// models, cheap enough to poll
+func (r *Repository) GetMatchingModelsRevision(ctx context.Context) (MatchingModelsRevision, error) {
+	var revision MatchingModelsRevision
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(revision), 0), COUNT(*) FROM matching_models`,
+	).Scan(&revision.Revision, &revision.Count)
+	if err != nil {
+		return revision, fmt.Errorf("failed to get matching models revision: %w", err)
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,418 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Alias types
+const (
+	AliasTypeDBA          = "dba"
+	AliasTypeTradeName    = "trade_name"
+	AliasTypeFormerName   = "former_name"
+	AliasTypeAbbreviation = "abbreviation"
+	AliasTypeOther        = "other"
+)
+
+// Organization relationship types
+const (
+	RelationshipSubsidiary = "subsidiary"
+	RelationshipBranch     = "branch"
+	RelationshipDivision   = "division"
+	RelationshipAffiliate  = "affiliate"
+)
+
+var (
+	// ErrAliasExists is returned when an entity already has an equivalent alias
+	ErrAliasExists = errors.New("entity already has this alias")
+	// ErrRelationshipExists is returned for a duplicate parent/child link
+	ErrRelationshipExists = errors.New("organization relationship already exists")
+	// ErrAliasNotFound is returned when an alias does not exist on the entity
+	ErrAliasNotFound = errors.New("entity alias not found")
+	// ErrRelationshipNotFound is returned when a relationship does not exist or has already ended
+	ErrRelationshipNotFound = errors.New("organization relationship not found")
+)
+
+// EntityAlias is an alternate name an entity is known by
+type EntityAlias struct {
+	ID                uuid.UUID  `json:"id"`
+	EntityID          uuid.UUID  `json:"entity_id"`
+	Alias             string     `json:"alias"`
+	StandardizedAlias string     `json:"standardized_alias"`
+	AliasType         string     `json:"alias_type"`
+	ValidFrom         *time.Time `json:"valid_from,omitempty"`
+	ValidTo           *time.Time `json:"valid_to,omitempty"`
+	Source            string     `json:"source,omitempty"`
+	CreatedBy         string     `json:"created_by,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// AliasMatch is an entity found through one of its aliases
+type AliasMatch struct {
+	EntityID   uuid.UUID `json:"entity_id"`
+	Alias      string    `json:"alias"`
+	AliasType  string    `json:"alias_type"`
+	Similarity float64   `json:"similarity"`
+}
+
+// OrganizationRelationship links a parent organization to a subsidiary,
+// branch, division or affiliate
+type OrganizationRelationship struct {
+	ID                  uuid.UUID  `json:"id"`
+	ParentEntityID      uuid.UUID  `json:"parent_entity_id"`
+	ChildEntityID       uuid.UUID  `json:"child_entity_id"`
+	RelationshipType    string     `json:"relationship_type"`
+	OwnershipPercentage *float64   `json:"ownership_percentage,omitempty"`
+	ValidFrom           *time.Time `json:"valid_from,omitempty"`
+	ValidTo             *time.Time `json:"valid_to,omitempty"`
+	Source              string     `json:"source,omitempty"`
+	CreatedBy           string     `json:"created_by,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// EntitySummary is the identifying subset of an entity
+type EntitySummary struct {
+	ID         uuid.UUID `json:"id"`
+	EntityType string    `json:"entity_type"`
+	Name       string    `json:"name"`
+}
+
+// Alias operations
+
+// CreateEntityAlias adds an alias to an entity
+func (r *Repository) CreateEntityAlias(ctx context.Context, alias *EntityAlias) error {
+	query := `
+		INSERT INTO entity_aliases (
+			id, entity_id, alias, standardized_alias, alias_type,
+			valid_from, valid_to, source, created_by, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		alias.ID,
+		alias.EntityID,
+		alias.Alias,
+		alias.StandardizedAlias,
+		alias.AliasType,
+		alias.ValidFrom,
+		alias.ValidTo,
+		nullString(alias.Source),
+		nullString(alias.CreatedBy),
+		alias.CreatedAt,
+	)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAliasExists
+		}
+		return fmt.Errorf("failed to create entity alias: %w", err)
+	}
+
+	return nil
+}
+
+// ListEntityAliases returns an entity's aliases
+func (r *Repository) ListEntityAliases(ctx context.Context, entityID uuid.UUID) ([]*EntityAlias, error) {
+	query := `
+		SELECT id, entity_id, alias, standardized_alias, alias_type,
+			   valid_from, valid_to, COALESCE(source, ''), COALESCE(created_by, ''), created_at
+		FROM entity_aliases
+		WHERE entity_id = $1
+		ORDER BY alias_type, alias`
+
+	rows, err := r.db.QueryContext(ctx, query, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entity aliases: %w", err)
+	}
+	defer rows.Close()
+
+	var aliases []*EntityAlias
+	for rows.Next() {
+		alias := &EntityAlias{}
+		if err := rows.Scan(
+			&alias.ID,
+			&alias.EntityID,
+			&alias.Alias,
+			&alias.StandardizedAlias,
+			&alias.AliasType,
+			&alias.ValidFrom,
+			&alias.ValidTo,
+			&alias.Source,
+			&alias.CreatedBy,
+			&alias.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan entity alias: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating entity aliases: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// DeleteEntityAlias removes one of an entity's aliases
+func (r *Repository) DeleteEntityAlias(ctx context.Context, entityID, aliasID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM entity_aliases WHERE id = $1 AND entity_id = $2`, aliasID, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to delete entity alias: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrAliasNotFound
+	}
+
+	return nil
+}
+
+// FindEntitiesByAlias finds live entities with an alias similar to a
+// standardized name. An empty entity type matches all types.
+func (r *Repository) FindEntitiesByAlias(ctx context.Context, entityType, standardizedName string, threshold float64, limit int) ([]*AliasMatch, error) {
+	query := `
+		SELECT DISTINCT ON (a.entity_id)
+			   a.entity_id, a.alias, a.alias_type,
+			   similarity(a.standardized_alias, $2) AS score
+		FROM entity_aliases a
+		JOIN entities e ON e.id = a.entity_id
+		WHERE ($1 = '' OR e.entity_type = $1)
+		  AND e.merged_into_id IS NULL
+		  AND a.standardized_alias % $2
+		  AND similarity(a.standardized_alias, $2) >= $3
+		ORDER BY a.entity_id, score DESC`
+
+	rows, err := r.db.QueryContext(ctx, `SELECT * FROM (`+query+`) matches ORDER BY score DESC LIMIT $4`,
+		entityType, standardizedName, threshold, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find entities by alias: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*AliasMatch
+	for rows.Next() {
+		match := &AliasMatch{}
+		if err := rows.Scan(&match.EntityID, &match.Alias, &match.AliasType, &match.Similarity); err != nil {
+			return nil, fmt.Errorf("failed to scan alias match: %w", err)
+		}
+		matches = append(matches, match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alias matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+// Organization relationship operations
+
+// CreateOrganizationRelationship links a parent and child organization
+func (r *Repository) CreateOrganizationRelationship(ctx context.Context, rel *OrganizationRelationship) error {
+	query := `
+		INSERT INTO organization_relationships (
+			id, parent_entity_id, child_entity_id, relationship_type,
+			ownership_percentage, valid_from, valid_to, source, created_by,
+			created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		rel.ID,
+		rel.ParentEntityID,
+		rel.ChildEntityID,
+		rel.RelationshipType,
+		rel.OwnershipPercentage,
+		rel.ValidFrom,
+		rel.ValidTo,
+		nullString(rel.Source),
+		nullString(rel.CreatedBy),
+		rel.CreatedAt,
+		rel.UpdatedAt,
+	)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrRelationshipExists
+		}
+		return fmt.Errorf("failed to create organization relationship: %w", err)
+	}
+
+	return nil
+}
+
+// EndOrganizationRelationship closes a relationship, for example on divestment,
+// keeping it for historical family views
+func (r *Repository) EndOrganizationRelationship(ctx context.Context, id uuid.UUID, validTo time.Time) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE organization_relationships SET valid_to = $2
+		WHERE id = $1 AND (valid_to IS NULL OR valid_to > $2)`, id, validTo)
+	if err != nil {
+		return fmt.Errorf("failed to end organization relationship: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrRelationshipNotFound
+	}
+
+	return nil
+}
+
+// DeleteOrganizationRelationship removes a relationship recorded in error
+func (r *Repository) DeleteOrganizationRelationship(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM organization_relationships WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization relationship: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrRelationshipNotFound
+	}
+
+	return nil
+}
+
+// IsOrganizationAncestor reports whether ancestorID is above entityID in its
+// corporate family as of the given date
+func (r *Repository) IsOrganizationAncestor(ctx context.Context, ancestorID, entityID uuid.UUID, asOf time.Time, maxDepth int) (bool, error) {
+	query := `
+		WITH RECURSIVE ancestors(entity_id, depth) AS (
+			SELECT $2::uuid, 0
+			UNION
+			SELECT r.parent_entity_id, a.depth + 1
+			FROM organization_relationships r
+			JOIN ancestors a ON r.child_entity_id = a.entity_id
+			WHERE a.depth < $4
+			  AND (r.valid_from IS NULL OR r.valid_from <= $3)
+			  AND (r.valid_to IS NULL OR r.valid_to > $3)
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE entity_id = $1 AND depth > 0)`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, ancestorID, entityID, asOf, maxDepth).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check organization ancestry: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetCorporateFamilyRelationships returns every relationship in the corporate
+// family an entity belongs to as of the given date: it walks up to the
+// ultimate parents and back down through all their descendants
+func (r *Repository) GetCorporateFamilyRelationships(ctx context.Context, entityID uuid.UUID, asOf time.Time, maxDepth int) ([]*OrganizationRelationship, error) {
+	query := `
+		WITH RECURSIVE active AS (
+			SELECT * FROM organization_relationships
+			WHERE (valid_from IS NULL OR valid_from <= $2)
+			  AND (valid_to IS NULL OR valid_to > $2)
+		),
+		ancestors(entity_id, depth) AS (
+			SELECT $1::uuid, 0
+			UNION
+			SELECT a.parent_entity_id, an.depth + 1
+			FROM active a
+			JOIN ancestors an ON a.child_entity_id = an.entity_id
+			WHERE an.depth < $3
+		),
+		descendants(entity_id, depth) AS (
+			SELECT entity_id, 0 FROM ancestors
+			UNION
+			SELECT a.child_entity_id, d.depth + 1
+			FROM active a
+			JOIN descendants d ON a.parent_entity_id = d.entity_id
+			WHERE d.depth < $3
+		)
+		SELECT id, parent_entity_id, child_entity_id, relationship_type,
+			   ownership_percentage, valid_from, valid_to,
+			   COALESCE(source, ''), COALESCE(created_by, ''), created_at, updated_at
+		FROM active
+		WHERE parent_entity_id IN (SELECT entity_id FROM descendants)
+		ORDER BY parent_entity_id, child_entity_id`
+
+	rows, err := r.db.QueryContext(ctx, query, entityID, asOf, maxDepth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get corporate family: %w", err)
+	}
+	defer rows.Close()
+
+	var relationships []*OrganizationRelationship
+	for rows.Next() {
+		rel := &OrganizationRelationship{}
+		var ownership sql.NullFloat64
+		if err := rows.Scan(
+			&rel.ID,
+			&rel.ParentEntityID,
+			&rel.ChildEntityID,
+			&rel.RelationshipType,
+			&ownership,
+			&rel.ValidFrom,
+			&rel.ValidTo,
+			&rel.Source,
+			&rel.CreatedBy,
+			&rel.CreatedAt,
+			&rel.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan organization relationship: %w", err)
+		}
+		if ownership.Valid {
+			rel.OwnershipPercentage = &ownership.Float64
+		}
+		relationships = append(relationships, rel)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating organization relationships: %w", err)
+	}
+
+	return relationships, nil
+}
+
+// GetEntitySummaries returns the type and name of the given entities
+func (r *Repository) GetEntitySummaries(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*EntitySummary, error) {
+	summaries := make(map[uuid.UUID]*EntitySummary, len(ids))
+	if len(ids) == 0 {
+		return summaries, nil
+	}
+
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, entity_type, COALESCE(name, '') FROM entities WHERE id = ANY($1::uuid[])`,
+		pq.Array(values))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity summaries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		summary := &EntitySummary{}
+		if err := rows.Scan(&summary.ID, &summary.EntityType, &summary.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan entity summary: %w", err)
+		}
+		summaries[summary.ID] = summary
+	}
+
+	return summaries, rows.Err()
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -92,6 +92,7 @@ func NewRepository(cfg config.DatabaseConfig, logger *slog.Logger) (*Repository,
 	defer cancel()
 
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
@@ -0,0 +1,242 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxMergeRedirects bounds how many merges are followed from an updated
+// entity to the live entity that absorbed it
+const maxMergeRedirects = 20
+
+// ErrEntityNotFound is returned when an attribute update names an unknown entity
+var ErrEntityNotFound = errors.New("entity not found")
+
+// AttributeUpdate is a change to the identifiers and attributes an upstream
+// source reported for an entity. A nil value removes the key.
+type AttributeUpdate struct {
+	EntityID    uuid.UUID
+	Identifiers map[string]interface{}
+	Attributes  map[string]interface{}
+	SourceID    string
+	ReferenceID string // the upstream event
+	OccurredAt  time.Time
+}
+
+// PairRescore is a new score for a pending merge review or candidate. A
+// superseded pair leaves the queue.
+type PairRescore struct {
+	ID              uuid.UUID
+	RawScore        float64
+	CalibratedScore float64
+	Evidence        json.RawMessage
+	Supersede       bool
+}
+
+// Rescore operations
+
+// ApplyAttributeUpdate applies an attribute update and records it in the
+// lineage audit trail. Updates to a merged entity are applied to the entity
+// that absorbed it. The live entity and the changes made are returned; an
+// update that changes nothing records nothing.
+func (r *Repository) ApplyAttributeUpdate(ctx context.Context, update *AttributeUpdate) (*Entity, []*AttributeChange, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	entityID, err := lockLiveEntityTx(ctx, tx, update.EntityID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	before, err := entityFieldsTx(ctx, tx, entityID)
+	if err != nil {
+		return nil, nil, err
+	}
+	after := EntityFields{
+		Identifiers: applyValues(before.Identifiers, update.Identifiers),
+		Attributes:  applyValues(before.Attributes, update.Attributes),
+	}
+
+	changes, err := DiffEntityFields(before, after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(changes) > 0 {
+		identifiers, err := json.Marshal(after.Identifiers)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal identifiers: %w", err)
+		}
+		attributes, err := json.Marshal(after.Attributes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal attributes: %w", err)
+		}
+
+		occurredAt := update.OccurredAt
+		if occurredAt.IsZero() {
+			occurredAt = time.Now()
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE entities SET identifiers = $2, attributes = $3, updated_at = $4
+			WHERE id = $1`,
+			entityID, identifiers, attributes, time.Now()); err != nil {
+			return nil, nil, fmt.Errorf("failed to update entity attributes: %w", err)
+		}
+
+		if err := insertLineageEvent(ctx, tx, &LineageEvent{
+			EntityID:    entityID,
+			EventType:   LineageAttributesUpdated,
+			SourceID:    update.SourceID,
+			Actor:       "rescore",
+			ReferenceID: update.ReferenceID,
+			Changes:     changes,
+			OccurredAt:  occurredAt,
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	entity, err := getEntityTx(ctx, tx, entityID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit attribute update: %w", err)
+	}
+
+	return entity, changes, nil
+}
+
+// ListPendingMergeCandidates retrieves the candidates awaiting review that
+// involve an entity on either side, best scoring first
+func (r *Repository) ListPendingMergeCandidates(ctx context.Context, entityID uuid.UUID, limit int) ([]*MergeCandidate, error) {
+	query := `SELECT ` + mergeCandidateColumns + ` FROM merge_candidates
+		WHERE status = 'pending_review'
+		  AND (survivor_entity_id = $1 OR duplicate_entity_id = $1)
+		ORDER BY calibrated_score DESC, created_at
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, entityID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending merge candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []*MergeCandidate
+	for rows.Next() {
+		candidate, err := scanMergeCandidate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan merge candidate: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating merge candidates: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// RescoreMergeReview stores a new score for a pending merge review
+func (r *Repository) RescoreMergeReview(ctx context.Context, rescore *PairRescore) error {
+	status := MergeReviewPending
+	if rescore.Supersede {
+		status = MergeReviewSuperseded
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE merge_reviews SET
+			raw_score = $2, calibrated_score = $3, evidence = $4, status = $5, updated_at = $6
+		WHERE id = $1 AND status = 'pending'`,
+		rescore.ID, rescore.RawScore, rescore.CalibratedScore, rescore.Evidence, status, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to rescore merge review: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to rescore merge review: %w", err)
+	}
+	if affected == 0 {
+		return ErrReviewNotPending
+	}
+
+	return nil
+}
+
+// RescoreMergeCandidate stores a new score for a candidate awaiting review
+func (r *Repository) RescoreMergeCandidate(ctx context.Context, rescore *PairRescore) error {
+	status := CandidatePendingReview
+	if rescore.Supersede {
+		status = CandidateSuperseded
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE merge_candidates SET
+			raw_score = $2, calibrated_score = $3, evidence = $4, status = $5, updated_at = $6
+		WHERE id = $1 AND status = 'pending_review'`,
+		rescore.ID, rescore.RawScore, rescore.CalibratedScore, rescore.Evidence, status, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to rescore merge candidate: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to rescore merge candidate: %w", err)
+	}
+	if affected == 0 {
+		return ErrCandidateNotPending
+	}
+
+	return nil
+}
+
+// lockLiveEntityTx follows merges from an entity to the live entity that
+// absorbed it and locks that entity for update
+func lockLiveEntityTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (uuid.UUID, error) {
+	for hop := 0; hop <= maxMergeRedirects; hop++ {
+		var mergedInto *uuid.UUID
+		err := tx.QueryRowContext(ctx, `
+			SELECT merged_into_id FROM entities WHERE id = $1 FOR UPDATE`, id).Scan(&mergedInto)
+		if err == sql.ErrNoRows {
+			return uuid.Nil, fmt.Errorf("%w: %s", ErrEntityNotFound, id)
+		}
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("failed to lock entity: %w", err)
+		}
+		if mergedInto == nil {
+			return id, nil
+		}
+		id = *mergedInto
+	}
+
+	return uuid.Nil, fmt.Errorf("entity %s is more than %d merges deep", id, maxMergeRedirects)
+}
+
+// applyValues returns the current values with the updated keys set, or
+// removed where the update is nil
+func applyValues(current, update map[string]interface{}) map[string]interface{} {
+	values := make(map[string]interface{}, len(current)+len(update))
+	for key, value := range current {
+		values[key] = value
+	}
+	for key, value := range update {
+		if value == nil {
+			delete(values, key)
+			continue
+		}
+		values[key] = value
+	}
+	return values
+}
@@ -0,0 +1,513 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Merge review statuses
+const (
+	MergeReviewPending    = "pending"
+	MergeReviewApproved   = "approved"
+	MergeReviewRejected   = "rejected"
+	MergeReviewSplit      = "split"
+	MergeReviewSuperseded = "superseded"
+)
+
+var (
+	// ErrReviewNotPending is returned when a decided review is no longer in the queue
+	ErrReviewNotPending = errors.New("merge review is not pending")
+	// ErrReviewNotApproved is returned when splitting a merge that was never applied
+	ErrReviewNotApproved = errors.New("merge review has not been approved")
+	// ErrSplitConflict is returned when a merge cannot be undone because the
+	// entities were merged again after it; those merges must be split first
+	ErrSplitConflict = errors.New("entities were merged again after this review")
+)
+
+// MergeReview represents a low-confidence merge from live resolution held
+// for an investigator
+type MergeReview struct {
+	ID                uuid.UUID       `json:"id"`
+	SurvivorEntityID  uuid.UUID       `json:"survivor_entity_id"`
+	DuplicateEntityID uuid.UUID       `json:"duplicate_entity_id"`
+	EntityType        string          `json:"entity_type"`
+	RawScore          float64         `json:"raw_score"`
+	CalibratedScore   float64         `json:"calibrated_score"`
+	Evidence          json.RawMessage `json:"evidence"`
+	Status            string          `json:"status"`
+	ReviewerID        string          `json:"reviewer_id,omitempty"`
+	ReviewNotes       string          `json:"review_notes,omitempty"`
+	ReviewedAt        *time.Time      `json:"reviewed_at,omitempty"`
+	SurvivorSnapshot  json.RawMessage `json:"survivor_snapshot,omitempty"`
+	DuplicateSnapshot json.RawMessage `json:"duplicate_snapshot,omitempty"`
+	SplitBy           string          `json:"split_by,omitempty"`
+	SplitReason       string          `json:"split_reason,omitempty"`
+	SplitAt           *time.Time      `json:"split_at,omitempty"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+// MergeReviewFilter narrows a merge review listing
+type MergeReviewFilter struct {
+	Status   string
+	EntityID *uuid.UUID // either side of the pair
+	Limit    int
+	Offset   int
+}
+
+// EntityFields are the mergeable fields of an entity
+type EntityFields struct {
+	Identifiers map[string]interface{} `json:"identifiers"`
+	Attributes  map[string]interface{} `json:"attributes"`
+}
+
+// Merge review operations
+
+// CreateMergeReview queues a merge for review; a pair already pending is ignored
+func (r *Repository) CreateMergeReview(ctx context.Context, review *MergeReview) error {
+	query := `
+		INSERT INTO merge_reviews (
+			id, survivor_entity_id, duplicate_entity_id, entity_type, raw_score,
+			calibrated_score, evidence, status, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		)
+		ON CONFLICT (survivor_entity_id, duplicate_entity_id) WHERE status = 'pending' DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query,
+		review.ID,
+		review.SurvivorEntityID,
+		review.DuplicateEntityID,
+		review.EntityType,
+		review.RawScore,
+		review.CalibratedScore,
+		review.Evidence,
+		review.Status,
+		review.CreatedAt,
+		review.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create merge review: %w", err)
+	}
+
+	return nil
+}
+
+const mergeReviewColumns = `
+	id, survivor_entity_id, duplicate_entity_id, entity_type, raw_score,
+	calibrated_score, COALESCE(evidence, '{}'), status, COALESCE(reviewer_id, ''),
+	COALESCE(review_notes, ''), reviewed_at, survivor_snapshot, duplicate_snapshot,
+	COALESCE(split_by, ''), COALESCE(split_reason, ''), split_at, created_at, updated_at`
+
+// GetMergeReview retrieves a merge review by ID
+func (r *Repository) GetMergeReview(ctx context.Context, id uuid.UUID) (*MergeReview, error) {
+	query := `SELECT ` + mergeReviewColumns + ` FROM merge_reviews WHERE id = $1`
+
+	review, err := scanMergeReview(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("merge review not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get merge review: %w", err)
+	}
+
+	return review, nil
+}
+
+// ListMergeReviews retrieves merge reviews, best scoring first
+func (r *Repository) ListMergeReviews(ctx context.Context, filter MergeReviewFilter) ([]*MergeReview, error) {
+	query := `SELECT ` + mergeReviewColumns + ` FROM merge_reviews
+		WHERE ($1 = '' OR status = $1)
+		  AND ($2::uuid IS NULL OR survivor_entity_id = $2 OR duplicate_entity_id = $2)
+		ORDER BY calibrated_score DESC, created_at
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.Status, filter.EntityID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []*MergeReview
+	for rows.Next() {
+		review, err := scanMergeReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan merge review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating merge reviews: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// ApproveMergeReview folds the duplicate into the survivor, snapshotting both
+// entities first so the merge can be split. Other pending reviews involving
+// the duplicate are superseded. The merged survivor is returned.
+func (r *Repository) ApproveMergeReview(ctx context.Context, id uuid.UUID, reviewerID, notes string) (*MergeReview, *Entity, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	review, err := lockMergeReview(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if review.Status != MergeReviewPending {
+		return nil, nil, ErrReviewNotPending
+	}
+
+	var unmerged int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT id FROM entities
+			WHERE id IN ($1, $2) AND merged_into_id IS NULL
+			FOR UPDATE
+		) AS locked`,
+		review.SurvivorEntityID, review.DuplicateEntityID).Scan(&unmerged)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock entities for merge: %w", err)
+	}
+	if unmerged != 2 {
+		return nil, nil, ErrAlreadyMerged
+	}
+
+	survivorBefore, err := entityFieldsTx(ctx, tx, review.SurvivorEntityID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE merge_reviews AS r SET
+			survivor_snapshot = (SELECT to_jsonb(e) FROM entities e WHERE e.id = r.survivor_entity_id),
+			duplicate_snapshot = (SELECT to_jsonb(e) FROM entities e WHERE e.id = r.duplicate_entity_id),
+			status = 'approved', reviewer_id = $2, review_notes = NULLIF($3, ''), reviewed_at = $4
+		WHERE r.id = $1`,
+		id, reviewerID, notes, now); err != nil {
+		return nil, nil, fmt.Errorf("failed to approve merge review: %w", err)
+	}
+
+	// Survivor values win on conflicting keys
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE entities AS survivor SET
+			identifiers = COALESCE(duplicate.identifiers, '{}') || COALESCE(survivor.identifiers, '{}'),
+			attributes = COALESCE(duplicate.attributes, '{}') || COALESCE(survivor.attributes, '{}'),
+			updated_at = $3
+		FROM entities AS duplicate
+		WHERE survivor.id = $1 AND duplicate.id = $2`,
+		review.SurvivorEntityID, review.DuplicateEntityID, now); err != nil {
+		return nil, nil, fmt.Errorf("failed to merge entity data: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE entities SET merged_into_id = $1, merged_at = $3
+		WHERE id = $2`,
+		review.SurvivorEntityID, review.DuplicateEntityID, now); err != nil {
+		return nil, nil, fmt.Errorf("failed to mark entity merged: %w", err)
+	}
+
+	properties, err := json.Marshal(map[string]interface{}{
+		"merge_review_id": id,
+		"raw_score":       review.RawScore,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal merge link properties: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO entity_links (
+			id, source_entity_id, target_entity_id, link_type, properties,
+			confidence_score, created_at, updated_at
+		) VALUES ($1, $2, $3, 'merged_into', $4, $5, $6, $6)`,
+		uuid.New(), review.DuplicateEntityID, review.SurvivorEntityID,
+		properties, review.CalibratedScore, now); err != nil {
+		return nil, nil, fmt.Errorf("failed to create merge link: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE merge_reviews SET status = 'superseded'
+		WHERE id <> $1 AND status = 'pending'
+		  AND (survivor_entity_id = $2 OR duplicate_entity_id = $2)`,
+		id, review.DuplicateEntityID); err != nil {
+		return nil, nil, fmt.Errorf("failed to supersede merge reviews: %w", err)
+	}
+
+	if err := recordMergeLineageTx(ctx, tx, LineageEntityMerged, review.SurvivorEntityID, review.DuplicateEntityID,
+		survivorBefore, reviewerID, id.String(), now); err != nil {
+		return nil, nil, err
+	}
+
+	survivor, err := getEntityTx(ctx, tx, review.SurvivorEntityID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit merge: %w", err)
+	}
+
+	approved, err := r.GetMergeReview(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return approved, survivor, nil
+}
+
+// RejectMergeReview records an investigator rejection of a queued merge
+func (r *Repository) RejectMergeReview(ctx context.Context, id uuid.UUID, reviewerID, notes string) (*MergeReview, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE merge_reviews SET
+			status = 'rejected', reviewer_id = $2, review_notes = NULLIF($3, ''), reviewed_at = $4
+		WHERE id = $1 AND status = 'pending'`,
+		id, reviewerID, notes, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject merge review: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject merge review: %w", err)
+	}
+	if affected == 0 {
+		if _, err := r.GetMergeReview(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrReviewNotPending
+	}
+
+	return r.GetMergeReview(ctx, id)
+}
+
+// SplitMergeReview undoes an approved merge. The duplicate is restored as a
+// live entity and the survivor loses the fields it only gained from the
+// duplicate; fields it has updated since keep their new values. Merges into
+// either entity made after this one must be split first. The restored
+// survivor is returned.
+func (r *Repository) SplitMergeReview(ctx context.Context, id uuid.UUID, splitBy, reason string) (*MergeReview, *Entity, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	review, err := lockMergeReview(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if review.Status != MergeReviewApproved {
+		return nil, nil, ErrReviewNotApproved
+	}
+
+	var current EntityFields
+	var identifiers, attributes []byte
+	var survivorMergedInto, duplicateMergedInto *uuid.UUID
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(s.identifiers, '{}'), COALESCE(s.attributes, '{}'),
+			   s.merged_into_id, d.merged_into_id
+		FROM entities s, entities d
+		WHERE s.id = $1 AND d.id = $2
+		FOR UPDATE OF s, d`,
+		review.SurvivorEntityID, review.DuplicateEntityID).Scan(
+		&identifiers, &attributes, &survivorMergedInto, &duplicateMergedInto)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock entities for split: %w", err)
+	}
+	if survivorMergedInto != nil || duplicateMergedInto == nil || *duplicateMergedInto != review.SurvivorEntityID {
+		return nil, nil, ErrSplitConflict
+	}
+
+	var later int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM entities
+		WHERE merged_into_id IN ($1, $2) AND id <> $2 AND merged_at > $3`,
+		review.SurvivorEntityID, review.DuplicateEntityID, review.ReviewedAt).Scan(&later); err != nil {
+		return nil, nil, fmt.Errorf("failed to check later merges: %w", err)
+	}
+	if later > 0 {
+		return nil, nil, ErrSplitConflict
+	}
+
+	if err := json.Unmarshal(identifiers, &current.Identifiers); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode survivor identifiers: %w", err)
+	}
+	if err := json.Unmarshal(attributes, &current.Attributes); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode survivor attributes: %w", err)
+	}
+
+	var survivorBefore, duplicate EntityFields
+	if err := json.Unmarshal(review.SurvivorSnapshot, &survivorBefore); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode survivor snapshot: %w", err)
+	}
+	if err := json.Unmarshal(review.DuplicateSnapshot, &duplicate); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode duplicate snapshot: %w", err)
+	}
+
+	restored := UnmergeFields(current, survivorBefore, duplicate)
+	identifiers, err = json.Marshal(restored.Identifiers)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode survivor identifiers: %w", err)
+	}
+	attributes, err = json.Marshal(restored.Attributes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode survivor attributes: %w", err)
+	}
+
+	now := time.Now()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE entities SET identifiers = $2, attributes = $3, updated_at = $4
+		WHERE id = $1`,
+		review.SurvivorEntityID, identifiers, attributes, now); err != nil {
+		return nil, nil, fmt.Errorf("failed to restore survivor: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE entities SET merged_into_id = NULL, merged_at = NULL, updated_at = $2
+		WHERE id = $1`,
+		review.DuplicateEntityID, now); err != nil {
+		return nil, nil, fmt.Errorf("failed to restore duplicate: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM entity_links
+		WHERE link_type = 'merged_into' AND properties->>'merge_review_id' = $1::text`,
+		id); err != nil {
+		return nil, nil, fmt.Errorf("failed to delete merge link: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE merge_reviews SET
+			status = 'split', split_by = $2, split_reason = NULLIF($3, ''), split_at = $4
+		WHERE id = $1`,
+		id, splitBy, reason, now); err != nil {
+		return nil, nil, fmt.Errorf("failed to record split: %w", err)
+	}
+
+	if err := recordMergeLineageTx(ctx, tx, LineageEntitySplit, review.SurvivorEntityID, review.DuplicateEntityID,
+		current, splitBy, id.String(), now); err != nil {
+		return nil, nil, err
+	}
+
+	survivor, err := getEntityTx(ctx, tx, review.SurvivorEntityID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit split: %w", err)
+	}
+
+	split, err := r.GetMergeReview(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return split, survivor, nil
+}
+
+// UnmergeFields reverses a merge in which the survivor's values won every
+// conflicting key. Keys the survivor only gained from the duplicate are
+// removed unless they have changed since; everything else is kept.
+func UnmergeFields(current, survivorBefore, duplicate EntityFields) EntityFields {
+	return EntityFields{
+		Identifiers: unmergeMap(current.Identifiers, survivorBefore.Identifiers, duplicate.Identifiers),
+		Attributes:  unmergeMap(current.Attributes, survivorBefore.Attributes, duplicate.Attributes),
+	}
+}
+
+func unmergeMap(current, before, duplicate map[string]interface{}) map[string]interface{} {
+	restored := make(map[string]interface{}, len(current))
+	for key, value := range current {
+		if _, owned := before[key]; !owned {
+			if gained, ok := duplicate[key]; ok && reflect.DeepEqual(gained, value) {
+				continue
+			}
+		}
+		restored[key] = value
+	}
+	return restored
+}
+
+func lockMergeReview(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*MergeReview, error) {
+	query := `SELECT ` + mergeReviewColumns + ` FROM merge_reviews WHERE id = $1 FOR UPDATE`
+
+	review, err := scanMergeReview(tx.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("merge review not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to lock merge review: %w", err)
+	}
+
+	return review, nil
+}
+
+func getEntityTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*Entity, error) {
+	entity := &Entity{}
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, entity_type, name, standardized_name, identifiers,
+			   attributes, confidence_score, created_at, updated_at
+		FROM entities
+		WHERE id = $1`, id).Scan(
+		&entity.ID,
+		&entity.EntityType,
+		&entity.Name,
+		&entity.StandardizedName,
+		&entity.Identifiers,
+		&entity.Attributes,
+		&entity.ConfidenceScore,
+		&entity.CreatedAt,
+		&entity.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+
+	return entity, nil
+}
+
+func scanMergeReview(row rowScanner) (*MergeReview, error) {
+	review := &MergeReview{}
+	var survivorSnapshot, duplicateSnapshot []byte
+	err := row.Scan(
+		&review.ID,
+		&review.SurvivorEntityID,
+		&review.DuplicateEntityID,
+		&review.EntityType,
+		&review.RawScore,
+		&review.CalibratedScore,
+		&review.Evidence,
+		&review.Status,
+		&review.ReviewerID,
+		&review.ReviewNotes,
+		&review.ReviewedAt,
+		&survivorSnapshot,
+		&duplicateSnapshot,
+		&review.SplitBy,
+		&review.SplitReason,
+		&review.SplitAt,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	// Snapshots are NULL until the merge is approved
+	review.SurvivorSnapshot = survivorSnapshot
+	review.DuplicateSnapshot = duplicateSnapshot
+	return review, nil
+}
@@ -0,0 +1,314 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Screening hit statuses
+const (
+	ScreeningHitOpen          = "open"
+	ScreeningHitConfirmed     = "confirmed"
+	ScreeningHitFalsePositive = "false_positive"
+)
+
+// ErrScreeningListNotFound is returned for lists that were never uploaded
+var ErrScreeningListNotFound = errors.New("screening list not found")
+
+// ScreeningList is a sanctions or PEP list uploaded through the API, kept in
+// its original format so it can be parsed again on startup
+type ScreeningList struct {
+	Name       string    `json:"name"`
+	Kind       string    `json:"kind"`
+	Format     string    `json:"format"`
+	Content    []byte    `json:"-"`
+	Checksum   string    `json:"checksum"`
+	EntryCount int       `json:"entry_count"`
+	UploadedBy string    `json:"uploaded_by"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// ScreeningHit records a resolved entity matching a sanctions or PEP list entry
+type ScreeningHit struct {
+	ID              uuid.UUID       `json:"id"`
+	EntityID        uuid.UUID       `json:"entity_id"`
+	EntityType      string          `json:"entity_type"`
+	EntityName      string          `json:"entity_name"`
+	ListName        string          `json:"list_name"`
+	ListKind        string          `json:"list_kind"`
+	EntryID         string          `json:"entry_id"`
+	EntryName       string          `json:"entry_name"`
+	MatchedName     string          `json:"matched_name"`
+	Score           float64         `json:"score"`
+	Evidence        json.RawMessage `json:"evidence"`
+	Status          string          `json:"status"`
+	ReviewerID      string          `json:"reviewer_id,omitempty"`
+	ReviewNotes     string          `json:"review_notes,omitempty"`
+	ReviewedAt      *time.Time      `json:"reviewed_at,omitempty"`
+	FirstScreenedAt time.Time       `json:"first_screened_at"`
+	LastScreenedAt  time.Time       `json:"last_screened_at"`
+}
+
+// ScreeningHitFilter narrows a screening hit listing
+type ScreeningHitFilter struct {
+	EntityID *uuid.UUID
+	ListName string
+	Status   string
+	Limit    int
+	Offset   int
+}
+
+// Screening operations
+
+// SaveScreeningList stores an uploaded list, replacing an earlier upload of
+// the same name
+func (r *Repository) SaveScreeningList(ctx context.Context, list *ScreeningList) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO screening_lists (
+			name, kind, format, content, checksum, entry_count, uploaded_by, uploaded_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (name) DO UPDATE SET
+			kind = EXCLUDED.kind,
+			format = EXCLUDED.format,
+			content = EXCLUDED.content,
+			checksum = EXCLUDED.checksum,
+			entry_count = EXCLUDED.entry_count,
+			uploaded_by = EXCLUDED.uploaded_by,
+			uploaded_at = EXCLUDED.uploaded_at`,
+		list.Name,
+		list.Kind,
+		list.Format,
+		list.Content,
+		list.Checksum,
+		list.EntryCount,
+		list.UploadedBy,
+		list.UploadedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save screening list: %w", err)
+	}
+
+	return nil
+}
+
+// ListScreeningLists retrieves every uploaded list with its content
+func (r *Repository) ListScreeningLists(ctx context.Context) ([]*ScreeningList, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT name, kind, format, content, checksum, entry_count, uploaded_by, uploaded_at
+		FROM screening_lists
+		ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list screening lists: %w", err)
+	}
+	defer rows.Close()
+
+	var lists []*ScreeningList
+	for rows.Next() {
+		list := &ScreeningList{}
+		if err := rows.Scan(
+			&list.Name,
+			&list.Kind,
+			&list.Format,
+			&list.Content,
+			&list.Checksum,
+			&list.EntryCount,
+			&list.UploadedBy,
+			&list.UploadedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan screening list: %w", err)
+		}
+		lists = append(lists, list)
+	}
+
+	return lists, rows.Err()
+}
+
+// DeleteScreeningList removes an uploaded list. Hits against it are kept.
+func (r *Repository) DeleteScreeningList(ctx context.Context, name string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM screening_lists WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete screening list: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete screening list: %w", err)
+	}
+	if affected == 0 {
+		return ErrScreeningListNotFound
+	}
+
+	return nil
+}
+
+// SaveScreeningHits records the hits of one screening. Hits already recorded
+// for the same entity and list entry keep their status and have their score
+// and evidence refreshed. The hits recorded for the first time are returned.
+func (r *Repository) SaveScreeningHits(ctx context.Context, hits []*ScreeningHit) ([]*ScreeningHit, error) {
+	if len(hits) == 0 {
+		return nil, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO screening_hits AS hit (
+			id, entity_id, entity_type, entity_name, list_name, list_kind, entry_id,
+			entry_name, matched_name, score, evidence, status, first_screened_at, last_screened_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (entity_id, list_name, entry_id) DO UPDATE SET
+			entity_name = EXCLUDED.entity_name,
+			entry_name = EXCLUDED.entry_name,
+			matched_name = EXCLUDED.matched_name,
+			score = EXCLUDED.score,
+			evidence = EXCLUDED.evidence,
+			last_screened_at = EXCLUDED.last_screened_at
+		RETURNING id, status, first_screened_at, (xmax = 0) AS inserted`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare screening hit upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	var created []*ScreeningHit
+	for _, hit := range hits {
+		var inserted bool
+		if err := stmt.QueryRowContext(ctx,
+			hit.ID,
+			hit.EntityID,
+			hit.EntityType,
+			hit.EntityName,
+			hit.ListName,
+			hit.ListKind,
+			hit.EntryID,
+			hit.EntryName,
+			hit.MatchedName,
+			hit.Score,
+			hit.Evidence,
+			hit.Status,
+			hit.FirstScreenedAt,
+			hit.LastScreenedAt,
+		).Scan(&hit.ID, &hit.Status, &hit.FirstScreenedAt, &inserted); err != nil {
+			return nil, fmt.Errorf("failed to save screening hit: %w", err)
+		}
+		if inserted {
+			created = append(created, hit)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit screening hits: %w", err)
+	}
+
+	return created, nil
+}
+
+const screeningHitColumns = `
+	id, entity_id, entity_type, entity_name, list_name, list_kind, entry_id,
+	entry_name, matched_name, score, COALESCE(evidence, '{}'), status,
+	COALESCE(reviewer_id, ''), COALESCE(review_notes, ''), reviewed_at,
+	first_screened_at, last_screened_at`
+
+// GetScreeningHit retrieves a screening hit by ID
+func (r *Repository) GetScreeningHit(ctx context.Context, id uuid.UUID) (*ScreeningHit, error) {
+	query := `SELECT ` + screeningHitColumns + ` FROM screening_hits WHERE id = $1`
+
+	hit, err := scanScreeningHit(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("screening hit not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get screening hit: %w", err)
+	}
+
+	return hit, nil
+}
+
+// ListScreeningHits retrieves screening hits, best scoring first
+func (r *Repository) ListScreeningHits(ctx context.Context, filter ScreeningHitFilter) ([]*ScreeningHit, error) {
+	query := `SELECT ` + screeningHitColumns + ` FROM screening_hits
+		WHERE ($1::uuid IS NULL OR entity_id = $1)
+		  AND ($2 = '' OR list_name = $2)
+		  AND ($3 = '' OR status = $3)
+		ORDER BY score DESC, last_screened_at DESC
+		LIMIT $4 OFFSET $5`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.EntityID, filter.ListName, filter.Status, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list screening hits: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []*ScreeningHit
+	for rows.Next() {
+		hit, err := scanScreeningHit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan screening hit: %w", err)
+		}
+		hits = append(hits, hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating screening hits: %w", err)
+	}
+
+	return hits, nil
+}
+
+// ReviewScreeningHit records an investigator's disposition of a hit
+func (r *Repository) ReviewScreeningHit(ctx context.Context, id uuid.UUID, status, reviewerID, notes string) (*ScreeningHit, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE screening_hits SET
+			status = $2, reviewer_id = $3, review_notes = NULLIF($4, ''), reviewed_at = $5
+		WHERE id = $1`,
+		id, status, reviewerID, notes, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to review screening hit: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to review screening hit: %w", err)
+	}
+	if affected == 0 {
+		return nil, fmt.Errorf("screening hit not found: %s", id)
+	}
+
+	return r.GetScreeningHit(ctx, id)
+}
+
+func scanScreeningHit(row rowScanner) (*ScreeningHit, error) {
+	hit := &ScreeningHit{}
+	err := row.Scan(
+		&hit.ID,
+		&hit.EntityID,
+		&hit.EntityType,
+		&hit.EntityName,
+		&hit.ListName,
+		&hit.ListKind,
+		&hit.EntryID,
+		&hit.EntryName,
+		&hit.MatchedName,
+		&hit.Score,
+		&hit.Evidence,
+		&hit.Status,
+		&hit.ReviewerID,
+		&hit.ReviewNotes,
+		&hit.ReviewedAt,
+		&hit.FirstScreenedAt,
+		&hit.LastScreenedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return hit, nil
+}
@@ -0,0 +1,219 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entity fields holding survivorship-managed attributes
+const (
+	FieldIdentifiers = "identifiers"
+	FieldAttributes  = "attributes"
+)
+
+// AttributeObservation is a value a source reported for an entity attribute.
+// Repeated reports of the same value by the same source are counted.
+type AttributeObservation struct {
+	EntityID         uuid.UUID       `json:"entity_id"`
+	Field            string          `json:"field"`
+	Attribute        string          `json:"attribute"`
+	Value            json.RawMessage `json:"value"`
+	SourceID         string          `json:"source_id,omitempty"`
+	ObservationCount int             `json:"observation_count"`
+	FirstSeenAt      time.Time       `json:"first_seen_at"`
+	LastSeenAt       time.Time       `json:"last_seen_at"`
+}
+
+// AttributeProvenance records why a golden-record value survived
+type AttributeProvenance struct {
+	EntityID         uuid.UUID       `json:"entity_id"`
+	Field            string          `json:"field"`
+	Attribute        string          `json:"attribute"`
+	Value            json.RawMessage `json:"value"`
+	Strategy         string          `json:"strategy"`
+	SourceID         string          `json:"source_id,omitempty"`
+	ObservedAt       time.Time       `json:"observed_at"`
+	ObservationCount int             `json:"observation_count"`
+	CandidateCount   int             `json:"candidate_count"`
+	DecidedAt        time.Time       `json:"decided_at"`
+}
+
+// Survivorship operations
+
+// RecordAttributeObservations records values reported for an entity,
+// counting repeats of a value by the same source
+func (r *Repository) RecordAttributeObservations(ctx context.Context, observations []*AttributeObservation) error {
+	if len(observations) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO entity_attribute_observations AS obs (
+			entity_id, field, attribute, value, source_id,
+			observation_count, first_seen_at, last_seen_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (entity_id, field, attribute, value_hash, source_id) DO UPDATE SET
+			observation_count = obs.observation_count + EXCLUDED.observation_count,
+			first_seen_at = LEAST(obs.first_seen_at, EXCLUDED.first_seen_at),
+			last_seen_at = GREATEST(obs.last_seen_at, EXCLUDED.last_seen_at)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare attribute observation insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, observation := range observations {
+		if _, err := stmt.ExecContext(ctx,
+			observation.EntityID,
+			observation.Field,
+			observation.Attribute,
+			observation.Value,
+			observation.SourceID,
+			observation.ObservationCount,
+			observation.FirstSeenAt,
+			observation.LastSeenAt,
+		); err != nil {
+			return fmt.Errorf("failed to record attribute observation: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit attribute observations: %w", err)
+	}
+
+	return nil
+}
+
+// ListAttributeObservations returns every value reported for an entity
+func (r *Repository) ListAttributeObservations(ctx context.Context, entityID uuid.UUID) ([]*AttributeObservation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT entity_id, field, attribute, value, source_id,
+			   observation_count, first_seen_at, last_seen_at
+		FROM entity_attribute_observations
+		WHERE entity_id = $1
+		ORDER BY field, attribute, last_seen_at DESC`, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attribute observations: %w", err)
+	}
+	defer rows.Close()
+
+	var observations []*AttributeObservation
+	for rows.Next() {
+		observation := &AttributeObservation{}
+		if err := rows.Scan(
+			&observation.EntityID,
+			&observation.Field,
+			&observation.Attribute,
+			&observation.Value,
+			&observation.SourceID,
+			&observation.ObservationCount,
+			&observation.FirstSeenAt,
+			&observation.LastSeenAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan attribute observation: %w", err)
+		}
+		observations = append(observations, observation)
+	}
+
+	return observations, rows.Err()
+}
+
+// SaveAttributeProvenance replaces the provenance of the given golden-record
+// values
+func (r *Repository) SaveAttributeProvenance(ctx context.Context, provenance []*AttributeProvenance) error {
+	if len(provenance) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO entity_attribute_provenance (
+			entity_id, field, attribute, value, strategy, source_id,
+			observed_at, observation_count, candidate_count, decided_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (entity_id, field, attribute) DO UPDATE SET
+			value = EXCLUDED.value,
+			strategy = EXCLUDED.strategy,
+			source_id = EXCLUDED.source_id,
+			observed_at = EXCLUDED.observed_at,
+			observation_count = EXCLUDED.observation_count,
+			candidate_count = EXCLUDED.candidate_count,
+			decided_at = EXCLUDED.decided_at`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare attribute provenance upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, p := range provenance {
+		if _, err := stmt.ExecContext(ctx,
+			p.EntityID,
+			p.Field,
+			p.Attribute,
+			p.Value,
+			p.Strategy,
+			p.SourceID,
+			p.ObservedAt,
+			p.ObservationCount,
+			p.CandidateCount,
+			p.DecidedAt,
+		); err != nil {
+			return fmt.Errorf("failed to save attribute provenance: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit attribute provenance: %w", err)
+	}
+
+	return nil
+}
+
+// ListAttributeProvenance returns the provenance of an entity's golden-record values
+func (r *Repository) ListAttributeProvenance(ctx context.Context, entityID uuid.UUID) ([]*AttributeProvenance, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT entity_id, field, attribute, value, strategy, source_id,
+			   observed_at, observation_count, candidate_count, decided_at
+		FROM entity_attribute_provenance
+		WHERE entity_id = $1
+		ORDER BY field, attribute`, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attribute provenance: %w", err)
+	}
+	defer rows.Close()
+
+	var provenance []*AttributeProvenance
+	for rows.Next() {
+		p := &AttributeProvenance{}
+		if err := rows.Scan(
+			&p.EntityID,
+			&p.Field,
+			&p.Attribute,
+			&p.Value,
+			&p.Strategy,
+			&p.SourceID,
+			&p.ObservedAt,
+			&p.ObservationCount,
+			&p.CandidateCount,
+			&p.DecidedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan attribute provenance: %w", err)
+		}
+		provenance = append(provenance, p)
+	}
+
+	return provenance, rows.Err()
+}
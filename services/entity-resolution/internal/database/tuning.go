@@ -0,0 +1,408 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrTuningCorpusNotFound is returned when a tuning corpus does not exist
+	ErrTuningCorpusNotFound = errors.New("tuning corpus not found")
+	// ErrTuningRunNotFound is returned when a tuning run does not exist
+	ErrTuningRunNotFound = errors.New("tuning run not found")
+)
+
+// TuningCorpus is an uploaded set of labeled name pairs used to tune screening
+type TuningCorpus struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description,omitempty"`
+	PairCount     int       `json:"pair_count"`
+	PositiveCount int       `json:"positive_count"`
+	UploadedBy    string    `json:"uploaded_by"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TuningPair is a labeled name pair: whether screening should flag name A
+// against name B
+type TuningPair struct {
+	NameA   string `json:"name_a"`
+	NameB   string `json:"name_b"`
+	IsMatch bool   `json:"is_match"`
+}
+
+// TuningRun is a threshold sweep evaluated against a corpus. Best is null
+// when no point met the minimum precision; Results holds every evaluated
+// point and is only loaded for a single run.
+type TuningRun struct {
+	ID            uuid.UUID       `json:"id"`
+	CorpusID      uuid.UUID       `json:"corpus_id"`
+	ThresholdFrom float64         `json:"threshold_from"`
+	ThresholdTo   float64         `json:"threshold_to"`
+	ThresholdStep float64         `json:"threshold_step"`
+	Combinations  json.RawMessage `json:"combinations"`
+	MinPrecision  float64         `json:"min_precision,omitempty"`
+	PairCount     int             `json:"pair_count"`
+	PointCount    int             `json:"point_count"`
+	Best          json.RawMessage `json:"best"`
+	Results       json.RawMessage `json:"results,omitempty"`
+	CreatedBy     string          `json:"created_by"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// ScreeningThreshold is a name screening threshold and algorithm combination
+// applied to the matching engine, with the metrics it achieved when tuned
+type ScreeningThreshold struct {
+	ID         uuid.UUID  `json:"id"`
+	RunID      *uuid.UUID `json:"run_id,omitempty"`
+	Threshold  float64    `json:"threshold"`
+	Algorithms []string   `json:"algorithms"`
+	Precision  float64    `json:"precision"`
+	Recall     float64    `json:"recall"`
+	F1         float64    `json:"f1"`
+	IsActive   bool       `json:"is_active"`
+	AppliedBy  string     `json:"applied_by"`
+	Notes      string     `json:"notes,omitempty"`
+	AppliedAt  time.Time  `json:"applied_at"`
+}
+
+// Tuning corpus operations
+
+// CreateTuningCorpus stores a corpus and its pairs in one transaction
+func (r *Repository) CreateTuningCorpus(ctx context.Context, corpus *TuningCorpus, pairs []TuningPair) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO screening_tuning_corpora (
+			id, name, description, pair_count, positive_count, uploaded_by, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)`
+
+	_, err = tx.ExecContext(ctx, query,
+		corpus.ID,
+		corpus.Name,
+		nullString(corpus.Description),
+		corpus.PairCount,
+		corpus.PositiveCount,
+		corpus.UploadedBy,
+		corpus.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create tuning corpus: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("screening_tuning_pairs", "corpus_id", "position", "name_a", "name_b", "is_match"))
+	if err != nil {
+		return fmt.Errorf("failed to prepare tuning pair copy: %w", err)
+	}
+	for i, pair := range pairs {
+		if _, err := stmt.ExecContext(ctx, corpus.ID, i, pair.NameA, pair.NameB, pair.IsMatch); err != nil {
+			stmt.Close()
+			return fmt.Errorf("failed to copy tuning pair: %w", err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return fmt.Errorf("failed to copy tuning pairs: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("failed to copy tuning pairs: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tuning corpus: %w", err)
+	}
+
+	return nil
+}
+
+const tuningCorpusColumns = `
+	id, name, COALESCE(description, ''), pair_count, positive_count, uploaded_by, created_at`
+
+// GetTuningCorpus retrieves a tuning corpus by ID
+func (r *Repository) GetTuningCorpus(ctx context.Context, id uuid.UUID) (*TuningCorpus, error) {
+	query := `SELECT ` + tuningCorpusColumns + ` FROM screening_tuning_corpora WHERE id = $1`
+
+	corpus, err := scanTuningCorpus(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTuningCorpusNotFound
+		}
+		return nil, fmt.Errorf("failed to get tuning corpus: %w", err)
+	}
+
+	return corpus, nil
+}
+
+// ListTuningCorpora retrieves tuning corpora, newest first
+func (r *Repository) ListTuningCorpora(ctx context.Context, limit int) ([]*TuningCorpus, error) {
+	query := `SELECT ` + tuningCorpusColumns + ` FROM screening_tuning_corpora
+		ORDER BY created_at DESC
+		LIMIT $1`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tuning corpora: %w", err)
+	}
+	defer rows.Close()
+
+	var corpora []*TuningCorpus
+	for rows.Next() {
+		corpus, err := scanTuningCorpus(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tuning corpus: %w", err)
+		}
+		corpora = append(corpora, corpus)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tuning corpora: %w", err)
+	}
+
+	return corpora, nil
+}
+
+// ListTuningPairs retrieves a corpus's pairs in upload order
+func (r *Repository) ListTuningPairs(ctx context.Context, corpusID uuid.UUID) ([]TuningPair, error) {
+	query := `
+		SELECT name_a, name_b, is_match
+		FROM screening_tuning_pairs
+		WHERE corpus_id = $1
+		ORDER BY position`
+
+	rows, err := r.db.QueryContext(ctx, query, corpusID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tuning pairs: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []TuningPair
+	for rows.Next() {
+		var pair TuningPair
+		if err := rows.Scan(&pair.NameA, &pair.NameB, &pair.IsMatch); err != nil {
+			return nil, fmt.Errorf("failed to scan tuning pair: %w", err)
+		}
+		pairs = append(pairs, pair)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tuning pairs: %w", err)
+	}
+
+	return pairs, nil
+}
+
+func scanTuningCorpus(row rowScanner) (*TuningCorpus, error) {
+	corpus := &TuningCorpus{}
+	err := row.Scan(
+		&corpus.ID,
+		&corpus.Name,
+		&corpus.Description,
+		&corpus.PairCount,
+		&corpus.PositiveCount,
+		&corpus.UploadedBy,
+		&corpus.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return corpus, nil
+}
+
+// Tuning run operations
+
+// CreateTuningRun stores an evaluated threshold sweep
+func (r *Repository) CreateTuningRun(ctx context.Context, run *TuningRun) error {
+	query := `
+		INSERT INTO screening_tuning_runs (
+			id, corpus_id, threshold_from, threshold_to, threshold_step,
+			combinations, min_precision, pair_count, point_count, best,
+			results, created_by, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+		)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		run.ID,
+		run.CorpusID,
+		run.ThresholdFrom,
+		run.ThresholdTo,
+		run.ThresholdStep,
+		run.Combinations,
+		run.MinPrecision,
+		run.PairCount,
+		run.PointCount,
+		run.Best,
+		run.Results,
+		run.CreatedBy,
+		run.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create tuning run: %w", err)
+	}
+
+	return nil
+}
+
+const tuningRunColumns = `
+	id, corpus_id, threshold_from, threshold_to, threshold_step, combinations,
+	min_precision, pair_count, point_count, best, created_by, created_at`
+
+// GetTuningRun retrieves a tuning run by ID, including every evaluated point
+func (r *Repository) GetTuningRun(ctx context.Context, id uuid.UUID) (*TuningRun, error) {
+	query := `SELECT ` + tuningRunColumns + `, results FROM screening_tuning_runs WHERE id = $1`
+
+	run := &TuningRun{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(append(tuningRunFields(run), &run.Results)...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTuningRunNotFound
+		}
+		return nil, fmt.Errorf("failed to get tuning run: %w", err)
+	}
+
+	return run, nil
+}
+
+// ListTuningRuns retrieves tuning runs without their points, newest first,
+// optionally for a single corpus
+func (r *Repository) ListTuningRuns(ctx context.Context, corpusID *uuid.UUID, limit int) ([]*TuningRun, error) {
+	query := `SELECT ` + tuningRunColumns + ` FROM screening_tuning_runs
+		WHERE ($1::uuid IS NULL OR corpus_id = $1)
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, corpusID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tuning runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*TuningRun
+	for rows.Next() {
+		run := &TuningRun{}
+		if err := rows.Scan(tuningRunFields(run)...); err != nil {
+			return nil, fmt.Errorf("failed to scan tuning run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tuning runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+func tuningRunFields(run *TuningRun) []interface{} {
+	return []interface{}{
+		&run.ID,
+		&run.CorpusID,
+		&run.ThresholdFrom,
+		&run.ThresholdTo,
+		&run.ThresholdStep,
+		&run.Combinations,
+		&run.MinPrecision,
+		&run.PairCount,
+		&run.PointCount,
+		&run.Best,
+		&run.CreatedBy,
+		&run.CreatedAt,
+	}
+}
+
+// Screening threshold operations
+
+// ApplyScreeningThreshold records a threshold as the active one, deactivating
+// the previously active threshold in the same transaction
+func (r *Repository) ApplyScreeningThreshold(ctx context.Context, threshold *ScreeningThreshold) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE screening_thresholds SET is_active = false WHERE is_active`); err != nil {
+		return fmt.Errorf("failed to deactivate screening thresholds: %w", err)
+	}
+
+	query := `
+		INSERT INTO screening_thresholds (
+			id, run_id, threshold, algorithms, precision, recall, f1,
+			is_active, applied_by, notes, applied_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, true, $8, $9, $10
+		)`
+
+	_, err = tx.ExecContext(ctx, query,
+		threshold.ID,
+		threshold.RunID,
+		threshold.Threshold,
+		pq.Array(threshold.Algorithms),
+		threshold.Precision,
+		threshold.Recall,
+		threshold.F1,
+		threshold.AppliedBy,
+		nullString(threshold.Notes),
+		threshold.AppliedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create screening threshold: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit screening threshold: %w", err)
+	}
+
+	threshold.IsActive = true
+	return nil
+}
+
+// GetActiveScreeningThreshold retrieves the active screening threshold, or nil
+// if none has been applied
+func (r *Repository) GetActiveScreeningThreshold(ctx context.Context) (*ScreeningThreshold, error) {
+	query := `
+		SELECT id, run_id, threshold, algorithms, precision, recall, f1,
+			   is_active, applied_by, COALESCE(notes, ''), applied_at
+		FROM screening_thresholds
+		WHERE is_active
+		LIMIT 1`
+
+	threshold := &ScreeningThreshold{}
+	err := r.db.QueryRowContext(ctx, query).Scan(
+		&threshold.ID,
+		&threshold.RunID,
+		&threshold.Threshold,
+		pq.Array(&threshold.Algorithms),
+		&threshold.Precision,
+		&threshold.Recall,
+		&threshold.F1,
+		&threshold.IsActive,
+		&threshold.AppliedBy,
+		&threshold.Notes,
+		&threshold.AppliedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active screening threshold: %w", err)
+	}
+
+	return threshold, nil
+}
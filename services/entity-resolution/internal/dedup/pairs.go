@@ -0,0 +1,171 @@
+package dedup
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/matching"
+)
+
+// Pair decisions
+const (
+	DecisionAutoMerge = "auto_merge"
+	DecisionReview    = "review"
+	DecisionSkip      = "skip"
+)
+
+// Scorer scores an entity against a set of candidates
+type Scorer interface {
+	FindMatches(input *matching.MatchInput, candidates []matching.CandidateEntity) (*matching.MatchResult, error)
+}
+
+// Calibrator maps raw similarity scores to match probabilities
+type Calibrator interface {
+	Calibrate(score float64) float64
+}
+
+// Pair is a scored duplicate pair within a block. The survivor is the
+// older entity so references created first keep pointing at a live record.
+type Pair struct {
+	Survivor        *database.Entity
+	Duplicate       *database.Entity
+	RawScore        float64
+	CalibratedScore float64
+	Match           *matching.MatchCandidate
+}
+
+// Decide routes a calibrated score to auto-merge, review or nothing
+func Decide(calibrated, autoMergeThreshold, reviewThreshold float64) string {
+	switch {
+	case calibrated >= autoMergeThreshold:
+		return DecisionAutoMerge
+	case calibrated >= reviewThreshold:
+		return DecisionReview
+	default:
+		return DecisionSkip
+	}
+}
+
+// ScoreBlock scores every unordered pair in a block and returns them ranked by
+// calibrated score. Entities must be ordered oldest first.
+func ScoreBlock(entities []*database.Entity, scorer Scorer, calibrator Calibrator) ([]*Pair, error) {
+	var pairs []*Pair
+
+	for i := 0; i < len(entities)-1; i++ {
+		rest := entities[i+1:]
+		byID := make(map[string]*database.Entity, len(rest))
+		candidates := make([]matching.CandidateEntity, 0, len(rest))
+		for _, entity := range rest {
+			byID[entity.ID.String()] = entity
+			candidates = append(candidates, ToCandidate(entity))
+		}
+
+		input := ToMatchInput(entities[i])
+		result, err := scorer.FindMatches(input, candidates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to score entity %s: %w", entities[i].ID, err)
+		}
+
+		for _, match := range result.Candidates {
+			duplicate, ok := byID[match.EntityID]
+			if !ok {
+				continue
+			}
+			pairs = append(pairs, &Pair{
+				Survivor:        entities[i],
+				Duplicate:       duplicate,
+				RawScore:        match.OverallScore,
+				CalibratedScore: calibrator.Calibrate(match.OverallScore),
+				Match:           match,
+			})
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].CalibratedScore > pairs[j].CalibratedScore
+	})
+
+	return pairs, nil
+}
+
+// ToMatchInput converts a stored entity into matcher input
+func ToMatchInput(entity *database.Entity) *matching.MatchInput {
+	candidate := ToCandidate(entity)
+	return &matching.MatchInput{
+		EntityType:  entity.EntityType,
+		Name:        candidate.Name,
+		Address:     candidate.Address,
+		Phone:       candidate.Phone,
+		Email:       candidate.Email,
+		Identifiers: candidate.Identifiers,
+	}
+}
+
+// ToCandidate converts a stored entity into a matcher candidate. Contact
+// fields are read from identifiers first, then attributes.
+func ToCandidate(entity *database.Entity) matching.CandidateEntity {
+	identifiers := stringValues(entity.Identifiers)
+	attributes := stringValues(entity.Attributes)
+
+	name := entity.StandardizedName
+	if name == "" {
+		name = entity.Name
+	}
+
+	candidate := matching.CandidateEntity{
+		ID:          entity.ID.String(),
+		Name:        name,
+		Identifiers: make(map[string]string, len(identifiers)),
+	}
+
+	for key, value := range identifiers {
+		switch key {
+		case "address", "phone", "email":
+		default:
+			candidate.Identifiers[key] = value
+		}
+	}
+
+	candidate.Address = firstNonEmpty(identifiers["address"], attributes["address"])
+	candidate.Phone = firstNonEmpty(identifiers["phone"], attributes["phone"])
+	candidate.Email = firstNonEmpty(identifiers["email"], attributes["email"])
+
+	return candidate
+}
+
+// stringValues flattens a JSON object into its scalar values
+func stringValues(raw json.RawMessage) map[string]string {
+	values := make(map[string]string)
+	if len(raw) == 0 {
+		return values
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return values
+	}
+
+	for key, value := range fields {
+		switch v := value.(type) {
+		case string:
+			if v != "" {
+				values[key] = v
+			}
+		case float64, bool:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+
+	return values
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
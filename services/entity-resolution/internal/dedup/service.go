@@ -0,0 +1,484 @@
+package dedup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aegisshield/entity-resolution/internal/calibration"
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/google/uuid"
+)
+
+// Reviewer decisions on queued merge candidates
+const (
+	ReviewAccept = "accept"
+	ReviewReject = "reject"
+)
+
+// DecisionRequest represents a reviewer's decision on a queued merge candidate
+type DecisionRequest struct {
+	Decision   string `json:"decision"`
+	ReviewerID string `json:"reviewer_id"`
+	Notes      string `json:"notes,omitempty"`
+}
+
+// RunProgress is a dedup run with its completion percentage
+type RunProgress struct {
+	*database.DedupRun
+	PercentComplete float64 `json:"percent_complete"`
+}
+
+// Report is the ranked merge-candidate report for a run
+type Report struct {
+	Run           *RunProgress                    `json:"run"`
+	Summary       *database.MergeCandidateSummary `json:"summary"`
+	TopCandidates []*database.MergeCandidate      `json:"top_candidates"`
+}
+
+// Service scans the existing entity backlog block by block for duplicates,
+// auto-merging high confidence pairs and queueing the rest for review
+type Service struct {
+	db          *database.Repository
+	scorer      Scorer
+	calibration *calibration.Service
+	config      config.DedupConfig
+	logger      *slog.Logger
+
+	mu      sync.Mutex
+	ctx     context.Context
+	cancels map[uuid.UUID]context.CancelFunc
+}
+
+// NewService creates a new backlog dedup service
+func NewService(db *database.Repository, scorer Scorer, calibrationService *calibration.Service, config config.DedupConfig, logger *slog.Logger) *Service {
+	return &Service{
+		db:          db,
+		scorer:      scorer,
+		calibration: calibrationService,
+		config:      config,
+		logger:      logger,
+		ctx:         context.Background(),
+		cancels:     make(map[uuid.UUID]context.CancelFunc),
+	}
+}
+
+// Start resumes a run interrupted by a restart and keeps runs bound to the
+// context until it is cancelled
+func (s *Service) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	run, err := s.db.GetActiveDedupRun(ctx)
+	if err != nil {
+		s.logger.Error("Failed to look up active dedup run", "error", err)
+	} else if run != nil {
+		s.logger.Info("Resuming dedup run",
+			"run_id", run.ID,
+			"processed_blocks", run.ProcessedBlocks,
+			"total_blocks", run.TotalBlocks)
+		s.launch(run)
+	}
+
+	<-ctx.Done()
+}
+
+// StartRun creates a backlog run and processes it in the background
+func (s *Service) StartRun(ctx context.Context, entityType, createdBy string) (*RunProgress, error) {
+	active, err := s.db.GetActiveDedupRun(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, database.ErrDedupRunActive
+	}
+
+	now := time.Now()
+	run := &database.DedupRun{
+		ID:                 uuid.New(),
+		Status:             database.DedupRunPending,
+		EntityType:         entityType,
+		BlockKeySize:       s.config.BlockKeySize,
+		AutoMergeThreshold: s.config.AutoMergeThreshold,
+		ReviewThreshold:    s.config.ReviewThreshold,
+		CreatedBy:          createdBy,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+
+	if err := s.db.CreateDedupRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Dedup run created",
+		"run_id", run.ID,
+		"entity_type", entityType,
+		"created_by", createdBy)
+
+	s.launch(run)
+
+	return newRunProgress(run), nil
+}
+
+// CancelRun stops an active run after the block in progress
+func (s *Service) CancelRun(ctx context.Context, id uuid.UUID) (*RunProgress, error) {
+	cancelled, err := s.db.CancelDedupRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if cancel, ok := s.cancels[id]; ok {
+		cancel()
+	}
+	s.mu.Unlock()
+
+	run, err := s.db.GetDedupRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, fmt.Errorf("dedup run %s is already %s", id, run.Status)
+	}
+
+	return newRunProgress(run), nil
+}
+
+// GetRun returns a run with its progress
+func (s *Service) GetRun(ctx context.Context, id uuid.UUID) (*RunProgress, error) {
+	run, err := s.db.GetDedupRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return newRunProgress(run), nil
+}
+
+// ListRuns returns recent runs with their progress
+func (s *Service) ListRuns(ctx context.Context, limit int) ([]*RunProgress, error) {
+	runs, err := s.db.ListDedupRuns(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make([]*RunProgress, 0, len(runs))
+	for _, run := range runs {
+		progress = append(progress, newRunProgress(run))
+	}
+	return progress, nil
+}
+
+// Report summarizes a run's candidates and lists the highest ranked pairs
+func (s *Service) Report(ctx context.Context, id uuid.UUID, top int) (*Report, error) {
+	run, err := s.GetRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.db.SummarizeMergeCandidates(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.db.ListMergeCandidates(ctx, database.MergeCandidateFilter{
+		RunID: &id,
+		Limit: top,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Report{
+		Run:           run,
+		Summary:       summary,
+		TopCandidates: candidates,
+	}, nil
+}
+
+// ListCandidates returns merge candidates ranked by calibrated score
+func (s *Service) ListCandidates(ctx context.Context, filter database.MergeCandidateFilter) ([]*database.MergeCandidate, error) {
+	return s.db.ListMergeCandidates(ctx, filter)
+}
+
+// Decide applies a reviewer decision to a queued merge candidate and feeds
+// the outcome back into match confidence calibration
+func (s *Service) Decide(ctx context.Context, candidateID uuid.UUID, req *DecisionRequest) (*database.MergeCandidate, error) {
+	if req.Decision != ReviewAccept && req.Decision != ReviewReject {
+		return nil, fmt.Errorf("decision must be %s or %s", ReviewAccept, ReviewReject)
+	}
+
+	candidate, err := s.db.GetMergeCandidate(ctx, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	if candidate.Status != database.CandidatePendingReview {
+		return nil, database.ErrCandidateNotPending
+	}
+
+	outcome := calibration.OutcomeAccepted
+	if req.Decision == ReviewAccept {
+		err = s.db.MergeEntities(ctx, candidate, database.CandidateMerged, req.ReviewerID, req.Notes)
+	} else {
+		outcome = calibration.OutcomeRejected
+		err = s.db.RejectMergeCandidate(ctx, candidateID, req.ReviewerID, req.Notes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.calibration != nil {
+		if _, err := s.calibration.RecordReview(ctx, &calibration.ReviewRequest{
+			SourceEntityID:    candidate.SurvivorEntityID.String(),
+			CandidateEntityID: candidate.DuplicateEntityID.String(),
+			RawScore:          candidate.RawScore,
+			Outcome:           outcome,
+			ReviewerID:        req.ReviewerID,
+			Notes:             req.Notes,
+		}); err != nil {
+			s.logger.Warn("Failed to record merge review for calibration",
+				"candidate_id", candidateID,
+				"error", err)
+		}
+	}
+
+	s.logger.Info("Merge candidate reviewed",
+		"candidate_id", candidateID,
+		"decision", req.Decision,
+		"reviewer_id", req.ReviewerID)
+
+	return s.db.GetMergeCandidate(ctx, candidateID)
+}
+
+// launch processes a run in the background under a cancellable context
+func (s *Service) launch(run *database.DedupRun) {
+	s.mu.Lock()
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.cancels[run.ID] = cancel
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.cancels, run.ID)
+			s.mu.Unlock()
+			cancel()
+		}()
+
+		if err := s.execute(ctx, run); err != nil {
+			s.logger.Error("Dedup run failed", "run_id", run.ID, "error", err)
+		}
+	}()
+}
+
+// execute walks the blocks after the run's cursor. Progress and the cursor
+// are saved after every block so an interrupted run resumes where it stopped.
+func (s *Service) execute(ctx context.Context, run *database.DedupRun) error {
+	if run.StartedAt == nil {
+		now := time.Now()
+		run.StartedAt = &now
+	}
+	run.Status = database.DedupRunRunning
+
+	if run.TotalBlocks == 0 {
+		total, err := s.db.CountDedupBlocks(ctx, run.EntityType, run.BlockKeySize)
+		if err != nil {
+			return s.fail(run, err)
+		}
+		run.TotalBlocks = total
+	}
+
+	if active, err := s.db.UpdateDedupRun(ctx, run); err != nil || !active {
+		return err
+	}
+
+	for {
+		blocks, err := s.db.ListDedupBlocks(ctx, run.EntityType, run.BlockKeySize,
+			run.CursorEntityType, run.CursorBlockKey, s.config.BlockBatchSize)
+		if err != nil {
+			return s.fail(run, err)
+		}
+		if len(blocks) == 0 {
+			break
+		}
+
+		for _, block := range blocks {
+			if ctx.Err() != nil {
+				s.logger.Info("Dedup run stopped", "run_id", run.ID, "processed_blocks", run.ProcessedBlocks)
+				return nil
+			}
+
+			if block.Size > s.config.MaxBlockSize {
+				s.logger.Warn("Skipping oversized dedup block",
+					"run_id", run.ID,
+					"entity_type", block.EntityType,
+					"block_key", block.Key,
+					"size", block.Size)
+				run.SkippedBlocks++
+			} else if err := s.processBlock(ctx, run, block); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return s.fail(run, err)
+			}
+
+			run.ProcessedBlocks++
+			run.CursorEntityType = block.EntityType
+			run.CursorBlockKey = block.Key
+
+			active, err := s.db.UpdateDedupRun(ctx, run)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return s.fail(run, err)
+			}
+			if !active {
+				s.logger.Info("Dedup run no longer active, stopping", "run_id", run.ID)
+				return nil
+			}
+		}
+	}
+
+	now := time.Now()
+	run.Status = database.DedupRunCompleted
+	run.CompletedAt = &now
+	if _, err := s.db.UpdateDedupRun(ctx, run); err != nil {
+		return err
+	}
+
+	s.logger.Info("Dedup run completed",
+		"run_id", run.ID,
+		"processed_blocks", run.ProcessedBlocks,
+		"skipped_blocks", run.SkippedBlocks,
+		"entities_scanned", run.EntitiesScanned,
+		"auto_merged", run.AutoMerged,
+		"queued_for_review", run.QueuedForReview)
+
+	return nil
+}
+
+// processBlock scores a block and routes each pair by calibrated score.
+// Pairs are handled best first; an entity absorbed by a merge is not paired
+// again, and an entity that already absorbed another is only queued for
+// review so merges never chain without a reviewer.
+func (s *Service) processBlock(ctx context.Context, run *database.DedupRun, block database.DedupBlock) error {
+	entities, err := s.db.ListBlockEntities(ctx, block, run.BlockKeySize, s.config.MaxBlockSize)
+	if err != nil {
+		return err
+	}
+	run.EntitiesScanned += int64(len(entities))
+
+	pairs, err := ScoreBlock(entities, s.scorer, s.calibrator())
+	if err != nil {
+		return err
+	}
+
+	absorbed := make(map[uuid.UUID]bool)
+	survivors := make(map[uuid.UUID]bool)
+
+	for _, pair := range pairs {
+		decision := Decide(pair.CalibratedScore, run.AutoMergeThreshold, run.ReviewThreshold)
+		if decision == DecisionSkip {
+			break
+		}
+		if absorbed[pair.Survivor.ID] || absorbed[pair.Duplicate.ID] {
+			continue
+		}
+		if decision == DecisionAutoMerge && survivors[pair.Duplicate.ID] {
+			decision = DecisionReview
+		}
+
+		evidence, err := json.Marshal(pair.Match)
+		if err != nil {
+			return fmt.Errorf("failed to marshal match evidence: %w", err)
+		}
+
+		now := time.Now()
+		candidate := &database.MergeCandidate{
+			ID:                uuid.New(),
+			RunID:             run.ID,
+			SurvivorEntityID:  pair.Survivor.ID,
+			DuplicateEntityID: pair.Duplicate.ID,
+			EntityType:        block.EntityType,
+			BlockKey:          block.Key,
+			RawScore:          pair.RawScore,
+			CalibratedScore:   pair.CalibratedScore,
+			Evidence:          evidence,
+			Status:            database.CandidatePendingReview,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+
+		if err := s.db.CreateMergeCandidate(ctx, candidate); err != nil {
+			return err
+		}
+		run.CandidatesFound++
+
+		if decision == DecisionReview {
+			run.QueuedForReview++
+			continue
+		}
+
+		err = s.db.MergeEntities(ctx, candidate, database.CandidateAutoMerged, "", "")
+		if errors.Is(err, database.ErrAlreadyMerged) {
+			// Merged concurrently by live resolution; leave it for a reviewer
+			run.QueuedForReview++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		absorbed[pair.Duplicate.ID] = true
+		survivors[pair.Survivor.ID] = true
+		run.AutoMerged++
+	}
+
+	return nil
+}
+
+// fail records a run error so it is visible in the progress report
+func (s *Service) fail(run *database.DedupRun, cause error) error {
+	now := time.Now()
+	run.Status = database.DedupRunFailed
+	run.ErrorMessage = cause.Error()
+	run.CompletedAt = &now
+
+	if _, err := s.db.UpdateDedupRun(context.Background(), run); err != nil {
+		s.logger.Error("Failed to record dedup run failure", "run_id", run.ID, "error", err)
+	}
+
+	return cause
+}
+
+func (s *Service) calibrator() Calibrator {
+	if s.calibration == nil {
+		return identityCalibrator{}
+	}
+	return s.calibration
+}
+
+type identityCalibrator struct{}
+
+func (identityCalibrator) Calibrate(score float64) float64 { return score }
+
+func newRunProgress(run *database.DedupRun) *RunProgress {
+	progress := &RunProgress{DedupRun: run}
+
+	switch {
+	case run.Status == database.DedupRunCompleted:
+		progress.PercentComplete = 100
+	case run.TotalBlocks > 0:
+		progress.PercentComplete = float64(run.ProcessedBlocks) / float64(run.TotalBlocks) * 100
+		if progress.PercentComplete > 100 {
+			progress.PercentComplete = 100
+		}
+	}
+
+	return progress
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/aegisshield/entity-resolution/internal/calibration"
+	"github.com/gorilla/mux"
+)
+
+// CalibrationHandler handles HTTP requests for match confidence calibration
+type CalibrationHandler struct {
+	service *calibration.Service
+	logger  *slog.Logger
+}
+
+// NewCalibrationHandler creates a new calibration handler
+func NewCalibrationHandler(service *calibration.Service, logger *slog.Logger) *CalibrationHandler {
+	return &CalibrationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers calibration routes
+func (h *CalibrationHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/calibration/reviews", h.RecordReview).Methods("POST")
+	router.HandleFunc("/api/v1/calibration/fit", h.FitModel).Methods("POST")
+	router.HandleFunc("/api/v1/calibration/model", h.GetActiveModel).Methods("GET")
+	router.HandleFunc("/api/v1/calibration/calibrate", h.CalibrateScore).Methods("GET")
+}
+
+// RecordReview records a reviewer accept/reject outcome for a proposed match
+func (h *CalibrationHandler) RecordReview(w http.ResponseWriter, r *http.Request) {
+	var req calibration.ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.ReviewerID == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "reviewer_id is required", nil)
+		return
+	}
+
+	review, err := h.service.RecordReview(r.Context(), &req)
+	if err != nil {
+		h.logger.Error("Failed to record match review", "error", err)
+		h.writeErrorResponse(w, http.StatusBadRequest, "Failed to record match review", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusCreated, review)
+}
+
+// FitModel runs the calibration job on demand
+func (h *CalibrationHandler) FitModel(w http.ResponseWriter, r *http.Request) {
+	method := r.URL.Query().Get("method")
+	if method != "" && method != calibration.MethodPlatt && method != calibration.MethodIsotonic {
+		h.writeErrorResponse(w, http.StatusBadRequest, "method must be platt or isotonic", nil)
+		return
+	}
+
+	result, err := h.service.Fit(r.Context(), method)
+	if err != nil {
+		h.logger.Error("Failed to fit calibration model", "error", err)
+		h.writeErrorResponse(w, http.StatusUnprocessableEntity, "Failed to fit calibration model", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, result)
+}
+
+// GetActiveModel returns the calibration model currently applied to scores
+func (h *CalibrationHandler) GetActiveModel(w http.ResponseWriter, r *http.Request) {
+	model := h.service.ActiveModel()
+	if model == nil {
+		h.writeErrorResponse(w, http.StatusNotFound, "No active calibration model", nil)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, model)
+}
+
+// CalibrateScore returns the calibrated probability for a raw similarity score
+func (h *CalibrationHandler) CalibrateScore(w http.ResponseWriter, r *http.Request) {
+	score, err := strconv.ParseFloat(r.URL.Query().Get("score"), 64)
+	if err != nil || score < 0 || score > 1 {
+		h.writeErrorResponse(w, http.StatusBadRequest, "score must be a number between 0 and 1", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"raw_score":        score,
+		"calibrated_score": h.service.Calibrate(score),
+		"calibrated":       h.service.ActiveModel() != nil,
+	})
+}
+
+// Helper methods
+
+func (h *CalibrationHandler) writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *CalibrationHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, message string, err error) {
+	response := map[string]interface{}{
+		"error":  message,
+		"status": statusCode,
+	}
+
+	if err != nil {
+		response["details"] = err.Error()
+	}
+
+	h.writeJSONResponse(w, statusCode, response)
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/dedup"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// DedupHandler handles HTTP requests for backlog deduplication
+type DedupHandler struct {
+	service *dedup.Service
+	logger  *slog.Logger
+}
+
+// NewDedupHandler creates a new dedup handler
+func NewDedupHandler(service *dedup.Service, logger *slog.Logger) *DedupHandler {
+	return &DedupHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers dedup routes
+func (h *DedupHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/dedup/runs", h.StartRun).Methods("POST")
+	router.HandleFunc("/api/v1/dedup/runs", h.ListRuns).Methods("GET")
+	router.HandleFunc("/api/v1/dedup/runs/{id}", h.GetRun).Methods("GET")
+	router.HandleFunc("/api/v1/dedup/runs/{id}/cancel", h.CancelRun).Methods("POST")
+	router.HandleFunc("/api/v1/dedup/runs/{id}/report", h.GetReport).Methods("GET")
+	router.HandleFunc("/api/v1/dedup/runs/{id}/candidates", h.ListRunCandidates).Methods("GET")
+	router.HandleFunc("/api/v1/dedup/review-queue", h.GetReviewQueue).Methods("GET")
+	router.HandleFunc("/api/v1/dedup/candidates/{id}/decision", h.DecideCandidate).Methods("POST")
+}
+
+// StartRun starts a backlog dedup run
+func (h *DedupHandler) StartRun(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		EntityType string `json:"entity_type"`
+		CreatedBy  string `json:"created_by"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.CreatedBy == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "created_by is required", nil)
+		return
+	}
+
+	run, err := h.service.StartRun(r.Context(), req.EntityType, req.CreatedBy)
+	if err != nil {
+		if errors.Is(err, database.ErrDedupRunActive) {
+			h.writeErrorResponse(w, http.StatusConflict, "A dedup run is already active", err)
+			return
+		}
+		h.logger.Error("Failed to start dedup run", "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to start dedup run", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusAccepted, run)
+}
+
+// ListRuns lists recent dedup runs with progress
+func (h *DedupHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	runs, err := h.service.ListRuns(r.Context(), queryInt(r, "limit", 20))
+	if err != nil {
+		h.logger.Error("Failed to list dedup runs", "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to list dedup runs", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"runs":  runs,
+		"count": len(runs),
+	})
+}
+
+// GetRun returns a dedup run's progress
+func (h *DedupHandler) GetRun(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	run, err := h.service.GetRun(r.Context(), id)
+	if err != nil {
+		h.writeLookupError(w, "Dedup run", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, run)
+}
+
+// CancelRun cancels an active dedup run
+func (h *DedupHandler) CancelRun(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	run, err := h.service.CancelRun(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			h.writeErrorResponse(w, http.StatusNotFound, "Dedup run not found", err)
+			return
+		}
+		h.writeErrorResponse(w, http.StatusConflict, "Failed to cancel dedup run", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, run)
+}
+
+// GetReport returns the ranked merge-candidate report for a run
+func (h *DedupHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.Report(r.Context(), id, queryInt(r, "top", 50))
+	if err != nil {
+		h.writeLookupError(w, "Dedup run", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, report)
+}
+
+// ListRunCandidates lists a run's merge candidates ranked by calibrated score
+func (h *DedupHandler) ListRunCandidates(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	h.listCandidates(w, r, database.MergeCandidateFilter{
+		RunID:  &id,
+		Status: r.URL.Query().Get("status"),
+	})
+}
+
+// GetReviewQueue lists merge candidates awaiting a reviewer, best first
+func (h *DedupHandler) GetReviewQueue(w http.ResponseWriter, r *http.Request) {
+	h.listCandidates(w, r, database.MergeCandidateFilter{
+		Status: database.CandidatePendingReview,
+	})
+}
+
+// DecideCandidate accepts or rejects a queued merge candidate
+func (h *DedupHandler) DecideCandidate(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dedup.DecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.ReviewerID == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "reviewer_id is required", nil)
+		return
+	}
+
+	candidate, err := h.service.Decide(r.Context(), id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrCandidateNotPending), errors.Is(err, database.ErrAlreadyMerged):
+			h.writeErrorResponse(w, http.StatusConflict, "Merge candidate cannot be decided", err)
+		case strings.Contains(err.Error(), "not found"):
+			h.writeErrorResponse(w, http.StatusNotFound, "Merge candidate not found", err)
+		case strings.Contains(err.Error(), "decision must be"):
+			h.writeErrorResponse(w, http.StatusBadRequest, "Invalid decision", err)
+		default:
+			h.logger.Error("Failed to decide merge candidate", "candidate_id", id, "error", err)
+			h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to decide merge candidate", err)
+		}
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, candidate)
+}
+
+// Helper methods
+
+func (h *DedupHandler) listCandidates(w http.ResponseWriter, r *http.Request, filter database.MergeCandidateFilter) {
+	filter.Limit = queryInt(r, "limit", 50)
+	filter.Offset = queryInt(r, "offset", 0)
+
+	candidates, err := h.service.ListCandidates(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to list merge candidates", "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to list merge candidates", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"candidates": candidates,
+		"count":      len(candidates),
+		"limit":      filter.Limit,
+		"offset":     filter.Offset,
+	})
+}
+
+func (h *DedupHandler) pathID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid id", err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *DedupHandler) writeLookupError(w http.ResponseWriter, resource string, err error) {
+	if strings.Contains(err.Error(), "not found") {
+		h.writeErrorResponse(w, http.StatusNotFound, resource+" not found", err)
+		return
+	}
+	h.logger.Error("Failed to get "+strings.ToLower(resource), "error", err)
+	h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to get "+strings.ToLower(resource), err)
+}
+
+func (h *DedupHandler) writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *DedupHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, message string, err error) {
+	response := map[string]interface{}{
+		"error":  message,
+		"status": statusCode,
+	}
+
+	if err != nil {
+		response["details"] = err.Error()
+	}
+
+	h.writeJSONResponse(w, statusCode, response)
+}
+
+func queryInt(r *http.Request, key string, fallback int) int {
+	value, err := strconv.Atoi(r.URL.Query().Get(key))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
@@ -391,8 +391,8 @@ func (h *HTTPHandler) CORSMiddleware(next http.Handler) http.Handler {
 // AuthMiddleware validates authentication (placeholder)
 func (h *HTTPHandler) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Skip auth for health checks
-		if r.URL.Path == "/api/v1/health" {
+		// Skip auth for health and readiness checks
+		if r.URL.Path == "/api/v1/health" || r.URL.Path == "/api/v1/ready" {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/aegisshield/entity-resolution/internal/lineage"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// LineageHandler handles HTTP requests for the entity lineage audit trail
+type LineageHandler struct {
+	service *lineage.Service
+	logger  *slog.Logger
+}
+
+// NewLineageHandler creates a new lineage handler
+func NewLineageHandler(service *lineage.Service, logger *slog.Logger) *LineageHandler {
+	return &LineageHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers lineage routes
+func (h *LineageHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/entities/{id}/lineage", h.GetHistory).Methods("GET")
+	router.HandleFunc("/api/v1/lineage/sync", h.SyncGraph).Methods("POST")
+}
+
+// GetHistory returns how an entity was assembled: the entities merged into
+// it, the source records behind them and every merge, split and attribute
+// change in order
+func (h *LineageHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	entityID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid id", err)
+		return
+	}
+
+	history, err := h.service.History(r.Context(), entityID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			h.writeErrorResponse(w, http.StatusNotFound, "Entity not found", err)
+			return
+		}
+		h.logger.Error("Failed to get entity lineage", "entity_id", entityID, "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to get entity lineage", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, history)
+}
+
+// SyncGraph mirrors a batch of lineage events the graph has missed
+func (h *LineageHandler) SyncGraph(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.SyncGraph(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to sync lineage to graph", "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to sync lineage to graph", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, result)
+}
+
+// Helper methods
+
+func (h *LineageHandler) writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *LineageHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, message string, err error) {
+	response := map[string]interface{}{
+		"error":  message,
+		"status": statusCode,
+	}
+
+	if err != nil {
+		response["details"] = err.Error()
+	}
+
+	h.writeJSONResponse(w, statusCode, response)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/matching"
+	"github.com/aegisshield/entity-resolution/internal/matchmodel"
+	"github.com/gorilla/mux"
+)
+
+// MatchingModelHandler handles HTTP requests administering the
+// per-entity-type matching models
+type MatchingModelHandler struct {
+	service *matchmodel.Service
+	logger  *slog.Logger
+}
+
+// NewMatchingModelHandler creates a new matching model handler
+func NewMatchingModelHandler(service *matchmodel.Service, logger *slog.Logger) *MatchingModelHandler {
+	return &MatchingModelHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers matching model routes
+func (h *MatchingModelHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/matching/models", h.ListModels).Methods("GET")
+	router.HandleFunc("/api/v1/matching/models/reload", h.ReloadModels).Methods("POST")
+	router.HandleFunc("/api/v1/matching/models/{entity_type}", h.GetModel).Methods("GET")
+	router.HandleFunc("/api/v1/matching/models/{entity_type}", h.UpdateModel).Methods("PUT")
+	router.HandleFunc("/api/v1/matching/models/{entity_type}", h.DeleteModel).Methods("DELETE")
+}
+
+// ListModels returns the matching models in effect and those stored
+func (h *MatchingModelHandler) ListModels(w http.ResponseWriter, r *http.Request) {
+	models, err := h.service.List(r.Context())
+	if err != nil {
+		h.writeServiceError(w, "Failed to list matching models", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, models)
+}
+
+// GetModel returns the matching model an entity type is scored under
+func (h *MatchingModelHandler) GetModel(w http.ResponseWriter, r *http.Request) {
+	h.writeJSONResponse(w, http.StatusOK, h.service.Effective(mux.Vars(r)["entity_type"]))
+}
+
+// UpdateModel saves an entity type's matching model and applies it
+func (h *MatchingModelHandler) UpdateModel(w http.ResponseWriter, r *http.Request) {
+	var req matchmodel.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	model, err := h.service.Update(r.Context(), mux.Vars(r)["entity_type"], &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to update matching model", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, model)
+}
+
+// DeleteModel removes an entity type's matching model
+func (h *MatchingModelHandler) DeleteModel(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), mux.Vars(r)["entity_type"]); err != nil {
+		h.writeServiceError(w, "Failed to delete matching model", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReloadModels applies the stored matching models now instead of waiting
+// for the next reload
+func (h *MatchingModelHandler) ReloadModels(w http.ResponseWriter, r *http.Request) {
+	reloaded, err := h.service.Reload(r.Context())
+	if err != nil {
+		h.writeServiceError(w, "Failed to reload matching models", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"reloaded": reloaded,
+	})
+}
+
+// Helper methods
+
+func (h *MatchingModelHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, database.ErrMatchingModelNotFound):
+		h.writeErrorResponse(w, http.StatusNotFound, message, err)
+	case errors.Is(err, matchmodel.ErrValidation),
+		errors.Is(err, matching.ErrInvalidModel):
+		h.writeErrorResponse(w, http.StatusBadRequest, message, err)
+	default:
+		h.logger.Error(message, "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+func (h *MatchingModelHandler) writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *MatchingModelHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, message string, err error) {
+	response := map[string]interface{}{
+		"error":  message,
+		"status": statusCode,
+	}
+
+	if err != nil {
+		response["details"] = err.Error()
+	}
+
+	h.writeJSONResponse(w, statusCode, response)
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/organization"
+	"github.com/aegisshield/shared/validation"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// OrganizationHandler handles HTTP requests for organization aliases,
+// parent/subsidiary relationships and corporate family views
+type OrganizationHandler struct {
+	service *organization.Service
+	logger  *slog.Logger
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(service *organization.Service, logger *slog.Logger) *OrganizationHandler {
+	return &OrganizationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers organization routes
+func (h *OrganizationHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/entities/{id}/aliases", h.ListAliases).Methods("GET")
+	router.HandleFunc("/api/v1/entities/{id}/aliases", h.AddAlias).Methods("POST")
+	router.HandleFunc("/api/v1/entities/{id}/aliases/{alias_id}", h.RemoveAlias).Methods("DELETE")
+	router.HandleFunc("/api/v1/organizations/relationships", h.AddRelationship).Methods("POST")
+	router.HandleFunc("/api/v1/organizations/relationships/{id}", h.DeleteRelationship).Methods("DELETE")
+	router.HandleFunc("/api/v1/organizations/relationships/{id}/end", h.EndRelationship).Methods("POST")
+	router.HandleFunc("/api/v1/organizations/{id}/family", h.GetFamily).Methods("GET")
+}
+
+// ListAliases lists an entity's aliases
+func (h *OrganizationHandler) ListAliases(w http.ResponseWriter, r *http.Request) {
+	entityID, ok := h.pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	aliases, err := h.service.ListAliases(r.Context(), entityID)
+	if err != nil {
+		h.logger.Error("Failed to list entity aliases", "entity_id", entityID, "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to list entity aliases", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"aliases": aliases,
+		"count":   len(aliases),
+	})
+}
+
+// AddAlias adds a DBA, trade, former or other name to an entity
+func (h *OrganizationHandler) AddAlias(w http.ResponseWriter, r *http.Request) {
+	entityID, ok := h.pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req organization.AliasRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		validation.WriteError(w, err)
+		return
+	}
+
+	alias, err := h.service.AddAlias(r.Context(), entityID, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to add entity alias", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusCreated, alias)
+}
+
+// RemoveAlias removes one of an entity's aliases
+func (h *OrganizationHandler) RemoveAlias(w http.ResponseWriter, r *http.Request) {
+	entityID, ok := h.pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	aliasID, ok := h.pathUUID(w, r, "alias_id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveAlias(r.Context(), entityID, aliasID); err != nil {
+		h.writeServiceError(w, "Failed to remove entity alias", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddRelationship links a parent and child organization
+func (h *OrganizationHandler) AddRelationship(w http.ResponseWriter, r *http.Request) {
+	var req organization.RelationshipRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		validation.WriteError(w, err)
+		return
+	}
+
+	rel, err := h.service.AddRelationship(r.Context(), &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to add organization relationship", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusCreated, rel)
+}
+
+// EndRelationship closes a relationship, defaulting to now
+func (h *OrganizationHandler) EndRelationship(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ValidTo *time.Time `json:"valid_to"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+	}
+
+	validTo := time.Now()
+	if req.ValidTo != nil {
+		validTo = *req.ValidTo
+	}
+
+	if err := h.service.EndRelationship(r.Context(), id, validTo); err != nil {
+		h.writeServiceError(w, "Failed to end organization relationship", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"id":       id,
+		"valid_to": validTo,
+	})
+}
+
+// DeleteRelationship removes a relationship recorded in error
+func (h *OrganizationHandler) DeleteRelationship(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRelationship(r.Context(), id); err != nil {
+		h.writeServiceError(w, "Failed to delete organization relationship", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetFamily returns the corporate family of an organization with effective
+// ownership per member, optionally as of a past date (RFC 3339 or YYYY-MM-DD)
+func (h *OrganizationHandler) GetFamily(w http.ResponseWriter, r *http.Request) {
+	entityID, ok := h.pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	asOf := time.Now()
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			parsed, err = time.Parse("2006-01-02", value)
+		}
+		if err != nil {
+			h.writeErrorResponse(w, http.StatusBadRequest, "as_of must be RFC 3339 or YYYY-MM-DD", err)
+			return
+		}
+		asOf = parsed
+	}
+
+	family, err := h.service.GetFamily(r.Context(), entityID, asOf)
+	if err != nil {
+		h.writeServiceError(w, "Failed to get corporate family", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, family)
+}
+
+// Helper methods
+
+func (h *OrganizationHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, organization.ErrEntityNotFound),
+		errors.Is(err, database.ErrAliasNotFound),
+		errors.Is(err, database.ErrRelationshipNotFound):
+		h.writeErrorResponse(w, http.StatusNotFound, message, err)
+	case errors.Is(err, database.ErrAliasExists),
+		errors.Is(err, database.ErrRelationshipExists),
+		errors.Is(err, organization.ErrCycle):
+		h.writeErrorResponse(w, http.StatusConflict, message, err)
+	case errors.Is(err, organization.ErrValidation):
+		h.writeErrorResponse(w, http.StatusBadRequest, message, err)
+	default:
+		h.logger.Error(message, "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+func (h *OrganizationHandler) pathUUID(w http.ResponseWriter, r *http.Request, key string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)[key])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid "+key, err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *OrganizationHandler) writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *OrganizationHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, message string, err error) {
+	response := map[string]interface{}{
+		"error":  message,
+		"status": statusCode,
+	}
+
+	if err != nil {
+		response["details"] = err.Error()
+	}
+
+	h.writeJSONResponse(w, statusCode, response)
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/review"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// ReviewHandler handles HTTP requests for the manual merge review queue
+type ReviewHandler struct {
+	service *review.Service
+	logger  *slog.Logger
+}
+
+// NewReviewHandler creates a new merge review handler
+func NewReviewHandler(service *review.Service, logger *slog.Logger) *ReviewHandler {
+	return &ReviewHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers merge review routes
+func (h *ReviewHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/merge-reviews", h.ListReviews).Methods("GET")
+	router.HandleFunc("/api/v1/merge-reviews/queue", h.GetQueue).Methods("GET")
+	router.HandleFunc("/api/v1/merge-reviews/{id}", h.GetReview).Methods("GET")
+	router.HandleFunc("/api/v1/merge-reviews/{id}/decision", h.DecideReview).Methods("POST")
+	router.HandleFunc("/api/v1/merge-reviews/{id}/split", h.SplitReview).Methods("POST")
+}
+
+// ListReviews lists merge reviews, optionally by status or entity
+func (h *ReviewHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	filter := database.MergeReviewFilter{Status: r.URL.Query().Get("status")}
+
+	if value := r.URL.Query().Get("entity_id"); value != "" {
+		entityID, err := uuid.Parse(value)
+		if err != nil {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Invalid entity_id", err)
+			return
+		}
+		filter.EntityID = &entityID
+	}
+
+	h.listReviews(w, r, filter)
+}
+
+// GetQueue lists merges awaiting an investigator, best first
+func (h *ReviewHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
+	h.listReviews(w, r, database.MergeReviewFilter{
+		Status: database.MergeReviewPending,
+	})
+}
+
+// GetReview returns a merge review with its evidence
+func (h *ReviewHandler) GetReview(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	mergeReview, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.writeReviewError(w, id, "get", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, mergeReview)
+}
+
+// DecideReview approves or rejects a queued merge
+func (h *ReviewHandler) DecideReview(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req review.DecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	mergeReview, err := h.service.Decide(r.Context(), id, &req)
+	if err != nil {
+		h.writeReviewError(w, id, "decide", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, mergeReview)
+}
+
+// SplitReview undoes an approved merge, restoring both entities
+func (h *ReviewHandler) SplitReview(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req review.SplitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	mergeReview, err := h.service.Split(r.Context(), id, &req)
+	if err != nil {
+		h.writeReviewError(w, id, "split", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, mergeReview)
+}
+
+// Helper methods
+
+func (h *ReviewHandler) listReviews(w http.ResponseWriter, r *http.Request, filter database.MergeReviewFilter) {
+	filter.Limit = queryInt(r, "limit", 50)
+	filter.Offset = queryInt(r, "offset", 0)
+
+	reviews, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to list merge reviews", "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to list merge reviews", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"reviews": reviews,
+		"count":   len(reviews),
+		"limit":   filter.Limit,
+		"offset":  filter.Offset,
+	})
+}
+
+func (h *ReviewHandler) writeReviewError(w http.ResponseWriter, id uuid.UUID, action string, err error) {
+	switch {
+	case errors.Is(err, review.ErrInvalidDecision), errors.Is(err, review.ErrReviewerRequired):
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid merge review request", err)
+	case errors.Is(err, database.ErrReviewNotPending), errors.Is(err, database.ErrReviewNotApproved),
+		errors.Is(err, database.ErrAlreadyMerged), errors.Is(err, database.ErrSplitConflict):
+		h.writeErrorResponse(w, http.StatusConflict, "Merge review is not in a valid state", err)
+	case strings.Contains(err.Error(), "not found"):
+		h.writeErrorResponse(w, http.StatusNotFound, "Merge review not found", err)
+	default:
+		h.logger.Error("Failed to "+action+" merge review", "review_id", id, "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to "+action+" merge review", err)
+	}
+}
+
+func (h *ReviewHandler) pathID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid id", err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *ReviewHandler) writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *ReviewHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, message string, err error) {
+	response := map[string]interface{}{
+		"error":  message,
+		"status": statusCode,
+	}
+
+	if err != nil {
+		response["details"] = err.Error()
+	}
+
+	h.writeJSONResponse(w, statusCode, response)
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/screening"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// ScreeningHandler handles HTTP requests for sanctions and PEP screening
+type ScreeningHandler struct {
+	service *screening.Service
+	logger  *slog.Logger
+}
+
+// NewScreeningHandler creates a new screening handler
+func NewScreeningHandler(service *screening.Service, logger *slog.Logger) *ScreeningHandler {
+	return &ScreeningHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers screening routes
+func (h *ScreeningHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/screening/lists", h.ListLists).Methods("GET")
+	router.HandleFunc("/api/v1/screening/lists/reload", h.ReloadLists).Methods("POST")
+	router.HandleFunc("/api/v1/screening/lists/{name}", h.UploadList).Methods("PUT")
+	router.HandleFunc("/api/v1/screening/lists/{name}", h.DeleteList).Methods("DELETE")
+	router.HandleFunc("/api/v1/screening/entities/{id}", h.ScreenEntity).Methods("POST")
+	router.HandleFunc("/api/v1/screening/hits", h.ListHits).Methods("GET")
+	router.HandleFunc("/api/v1/screening/hits/{id}", h.GetHit).Methods("GET")
+	router.HandleFunc("/api/v1/screening/hits/{id}/review", h.ReviewHit).Methods("POST")
+}
+
+// ListLists returns the loaded lists
+func (h *ScreeningHandler) ListLists(w http.ResponseWriter, r *http.Request) {
+	lists := h.service.Lists()
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"lists": lists,
+		"count": len(lists),
+	})
+}
+
+// ReloadLists reloads the configured and uploaded lists now
+func (h *ScreeningHandler) ReloadLists(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.LoadLists(r.Context()); err != nil {
+		h.logger.Warn("Failed to reload screening lists", "error", err)
+		h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+			"lists": h.service.Lists(),
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"lists": h.service.Lists(),
+	})
+}
+
+// UploadList uploads a list file as the request body. The format is taken
+// from the format parameter or the content type, and the kind from the kind
+// parameter.
+func (h *ScreeningHandler) UploadList(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = screening.FormatCSV
+		if strings.Contains(r.Header.Get("Content-Type"), "json") {
+			format = screening.FormatJSON
+		}
+	}
+
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.service.MaxUploadBytes()))
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusRequestEntityTooLarge, "List too large", err)
+		return
+	}
+
+	status, err := h.service.UploadList(r.Context(), &screening.UploadRequest{
+		Name:       mux.Vars(r)["name"],
+		Kind:       r.URL.Query().Get("kind"),
+		Format:     format,
+		Content:    content,
+		UploadedBy: r.URL.Query().Get("uploaded_by"),
+	})
+	if err != nil {
+		h.writeListError(w, "upload", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, status)
+}
+
+// DeleteList removes an uploaded list
+func (h *ScreeningHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteList(r.Context(), mux.Vars(r)["name"]); err != nil {
+		h.writeListError(w, "delete", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ScreenEntity screens a stored entity now and returns its current hits
+func (h *ScreeningHandler) ScreenEntity(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	hits, err := h.service.ScreenEntityByID(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			h.writeErrorResponse(w, http.StatusNotFound, "Entity not found", err)
+			return
+		}
+		h.logger.Error("Failed to screen entity", "entity_id", id, "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to screen entity", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"entity_id": id,
+		"hits":      hits,
+		"count":     len(hits),
+	})
+}
+
+// ListHits lists screening hits, optionally by entity, list or status
+func (h *ScreeningHandler) ListHits(w http.ResponseWriter, r *http.Request) {
+	filter := database.ScreeningHitFilter{
+		ListName: r.URL.Query().Get("list"),
+		Status:   r.URL.Query().Get("status"),
+		Limit:    queryInt(r, "limit", 50),
+		Offset:   queryInt(r, "offset", 0),
+	}
+
+	if value := r.URL.Query().Get("entity_id"); value != "" {
+		entityID, err := uuid.Parse(value)
+		if err != nil {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Invalid entity_id", err)
+			return
+		}
+		filter.EntityID = &entityID
+	}
+
+	hits, err := h.service.ListHits(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to list screening hits", "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to list screening hits", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"hits":   hits,
+		"count":  len(hits),
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// GetHit returns a screening hit with its evidence
+func (h *ScreeningHandler) GetHit(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	hit, err := h.service.GetHit(r.Context(), id)
+	if err != nil {
+		h.writeHitError(w, id, "get", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, hit)
+}
+
+// ReviewHit confirms a hit or dismisses it as a false positive
+func (h *ScreeningHandler) ReviewHit(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req screening.ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	hit, err := h.service.ReviewHit(r.Context(), id, &req)
+	if err != nil {
+		h.writeHitError(w, id, "review", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, hit)
+}
+
+// Helper methods
+
+func (h *ScreeningHandler) writeListError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, screening.ErrInvalidList), errors.Is(err, screening.ErrInvalidListName):
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid screening list", err)
+	case errors.Is(err, screening.ErrConfiguredList):
+		h.writeErrorResponse(w, http.StatusConflict, "Screening list is loaded from configuration", err)
+	case errors.Is(err, database.ErrScreeningListNotFound):
+		h.writeErrorResponse(w, http.StatusNotFound, "Screening list not found", err)
+	default:
+		h.logger.Error("Failed to "+action+" screening list", "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to "+action+" screening list", err)
+	}
+}
+
+func (h *ScreeningHandler) writeHitError(w http.ResponseWriter, id uuid.UUID, action string, err error) {
+	switch {
+	case errors.Is(err, screening.ErrInvalidReview), errors.Is(err, screening.ErrReviewerRequired):
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid screening review", err)
+	case strings.Contains(err.Error(), "not found"):
+		h.writeErrorResponse(w, http.StatusNotFound, "Screening hit not found", err)
+	default:
+		h.logger.Error("Failed to "+action+" screening hit", "hit_id", id, "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to "+action+" screening hit", err)
+	}
+}
+
+func (h *ScreeningHandler) pathID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid id", err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *ScreeningHandler) writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *ScreeningHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, message string, err error) {
+	response := map[string]interface{}{
+		"error":  message,
+		"status": statusCode,
+	}
+
+	if err != nil {
+		response["details"] = err.Error()
+	}
+
+	h.writeJSONResponse(w, statusCode, response)
+}
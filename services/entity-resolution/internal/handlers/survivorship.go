@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/aegisshield/entity-resolution/internal/survivorship"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// SurvivorshipHandler handles HTTP requests for golden-record survivorship
+// policy and attribute provenance
+type SurvivorshipHandler struct {
+	service *survivorship.Service
+	logger  *slog.Logger
+}
+
+// NewSurvivorshipHandler creates a new survivorship handler
+func NewSurvivorshipHandler(service *survivorship.Service, logger *slog.Logger) *SurvivorshipHandler {
+	return &SurvivorshipHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers survivorship routes
+func (h *SurvivorshipHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/survivorship/policy", h.GetPolicy).Methods("GET")
+	router.HandleFunc("/api/v1/entities/{id}/provenance", h.GetProvenance).Methods("GET")
+}
+
+// GetPolicy returns the effective survivorship strategies and source trust
+func (h *SurvivorshipHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	h.writeJSONResponse(w, http.StatusOK, h.service.Policy())
+}
+
+// GetProvenance returns where each of an entity's golden-record values came
+// from and which strategy kept it
+func (h *SurvivorshipHandler) GetProvenance(w http.ResponseWriter, r *http.Request) {
+	entityID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid id", err)
+		return
+	}
+
+	provenance, err := h.service.Provenance(r.Context(), entityID)
+	if err != nil {
+		h.logger.Error("Failed to get attribute provenance", "entity_id", entityID, "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to get attribute provenance", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"entity_id":  entityID,
+		"provenance": provenance,
+		"count":      len(provenance),
+	})
+}
+
+// Helper methods
+
+func (h *SurvivorshipHandler) writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *SurvivorshipHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, message string, err error) {
+	response := map[string]interface{}{
+		"error":  message,
+		"status": statusCode,
+	}
+
+	if err != nil {
+		response["details"] = err.Error()
+	}
+
+	h.writeJSONResponse(w, statusCode, response)
+}
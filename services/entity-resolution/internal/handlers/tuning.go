@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/matching"
+	"github.com/aegisshield/entity-resolution/internal/tuning"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// TuningHandler handles HTTP requests for the screening threshold tuning
+// console
+type TuningHandler struct {
+	service *tuning.Service
+	logger  *slog.Logger
+}
+
+// NewTuningHandler creates a new tuning handler
+func NewTuningHandler(service *tuning.Service, logger *slog.Logger) *TuningHandler {
+	return &TuningHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers tuning routes
+func (h *TuningHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/tuning/corpora", h.UploadCorpus).Methods("POST")
+	router.HandleFunc("/api/v1/tuning/corpora", h.ListCorpora).Methods("GET")
+	router.HandleFunc("/api/v1/tuning/corpora/{id}", h.GetCorpus).Methods("GET")
+	router.HandleFunc("/api/v1/tuning/runs", h.StartRun).Methods("POST")
+	router.HandleFunc("/api/v1/tuning/runs", h.ListRuns).Methods("GET")
+	router.HandleFunc("/api/v1/tuning/runs/{id}", h.GetRun).Methods("GET")
+	router.HandleFunc("/api/v1/tuning/runs/{id}/apply", h.ApplyRun).Methods("POST")
+	router.HandleFunc("/api/v1/tuning/active", h.GetActive).Methods("GET")
+	router.HandleFunc("/api/v1/tuning/compare", h.Compare).Methods("GET")
+}
+
+// UploadCorpus uploads a labeled test corpus of name pairs
+func (h *TuningHandler) UploadCorpus(w http.ResponseWriter, r *http.Request) {
+	var req tuning.CorpusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.UploadedBy == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "uploaded_by is required", nil)
+		return
+	}
+
+	corpus, err := h.service.UploadCorpus(r.Context(), &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to upload tuning corpus", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusCreated, corpus)
+}
+
+// ListCorpora lists uploaded tuning corpora
+func (h *TuningHandler) ListCorpora(w http.ResponseWriter, r *http.Request) {
+	corpora, err := h.service.ListCorpora(r.Context(), queryInt(r, "limit", 50))
+	if err != nil {
+		h.writeServiceError(w, "Failed to list tuning corpora", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"corpora": corpora,
+		"count":   len(corpora),
+	})
+}
+
+// GetCorpus returns a tuning corpus
+func (h *TuningHandler) GetCorpus(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	corpus, err := h.service.GetCorpus(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, "Failed to get tuning corpus", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, corpus)
+}
+
+// StartRun evaluates a threshold sweep against a corpus
+func (h *TuningHandler) StartRun(w http.ResponseWriter, r *http.Request) {
+	var req tuning.RunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.CreatedBy == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "created_by is required", nil)
+		return
+	}
+
+	run, err := h.service.Run(r.Context(), &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to run threshold sweep", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusCreated, run)
+}
+
+// ListRuns lists tuning runs, optionally for one corpus
+func (h *TuningHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	var corpusID *uuid.UUID
+	if value := r.URL.Query().Get("corpus_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Invalid corpus_id", err)
+			return
+		}
+		corpusID = &id
+	}
+
+	runs, err := h.service.ListRuns(r.Context(), corpusID, queryInt(r, "limit", 20))
+	if err != nil {
+		h.writeServiceError(w, "Failed to list tuning runs", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"runs":  runs,
+		"count": len(runs),
+	})
+}
+
+// GetRun returns a tuning run with every evaluated point
+func (h *TuningHandler) GetRun(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	run, err := h.service.GetRun(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, "Failed to get tuning run", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, run)
+}
+
+// ApplyRun applies a point evaluated by a run to name screening
+func (h *TuningHandler) ApplyRun(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req tuning.ApplyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	threshold, err := h.service.Apply(r.Context(), id, &req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to apply screening threshold", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, threshold)
+}
+
+// GetActive returns the name screening settings in effect
+func (h *TuningHandler) GetActive(w http.ResponseWriter, r *http.Request) {
+	active, err := h.service.Active(r.Context())
+	if err != nil {
+		h.writeServiceError(w, "Failed to get screening settings", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, active)
+}
+
+// Compare scores one name pair under each algorithm and the settings in effect
+func (h *TuningHandler) Compare(w http.ResponseWriter, r *http.Request) {
+	nameA := r.URL.Query().Get("name_a")
+	nameB := r.URL.Query().Get("name_b")
+	if nameA == "" || nameB == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "name_a and name_b are required", nil)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, h.service.Compare(nameA, nameB))
+}
+
+// Helper methods
+
+func (h *TuningHandler) writeServiceError(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, database.ErrTuningCorpusNotFound),
+		errors.Is(err, database.ErrTuningRunNotFound):
+		h.writeErrorResponse(w, http.StatusNotFound, message, err)
+	case errors.Is(err, tuning.ErrValidation),
+		errors.Is(err, matching.ErrInvalidNameSettings):
+		h.writeErrorResponse(w, http.StatusBadRequest, message, err)
+	default:
+		h.logger.Error(message, "error", err)
+		h.writeErrorResponse(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+func (h *TuningHandler) pathUUID(w http.ResponseWriter, r *http.Request, key string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)[key])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid "+key, err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *TuningHandler) writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *TuningHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, message string, err error) {
+	response := map[string]interface{}{
+		"error":  message,
+		"status": statusCode,
+	}
+
+	if err != nil {
+		response["details"] = err.Error()
+	}
+
+	h.writeJSONResponse(w, statusCode, response)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/aegisshield/entity-resolution/internal/metrics"
+	"github.com/aegisshield/shared/validation"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -76,12 +77,17 @@ func MetricsInterceptor(collector *metrics.Collector) grpc.UnaryServerIntercepto
 	}
 }
 
-// ValidationInterceptor validates incoming requests
+// ValidationInterceptor rejects requests failing their validate struct tags
+// or Validate method with InvalidArgument and per-field violations. Business
+// rules are still checked in the service layer.
 func ValidationInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		// Basic validation could be performed here
-		// For now, we'll just pass through to the handler
-		// More complex validation is done in the service layer
+		if err := validation.Request(req); err != nil {
+			logger.Debug("gRPC request failed validation",
+				"method", info.FullMethod,
+				"error", err)
+			return nil, validation.StatusError(err)
+		}
 
 		return handler(ctx, req)
 	}
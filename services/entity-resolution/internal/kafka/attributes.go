@@ -0,0 +1,157 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/rescore"
+	"github.com/google/uuid"
+)
+
+// AttributeUpdatedEvent reports identifier and attribute values an upstream
+// source record changed for an entity. A null value removes the key.
+type AttributeUpdatedEvent struct {
+	EventID     string                 `json:"event_id"`
+	EntityID    string                 `json:"entity_id"`
+	SourceID    string                 `json:"source_id,omitempty"`
+	Identifiers map[string]interface{} `json:"identifiers,omitempty"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+	Timestamp   time.Time              `json:"timestamp"`
+}
+
+// Rescorer re-scores the matches affected by an attribute update
+type Rescorer interface {
+	Rescore(ctx context.Context, update *rescore.Update) (*rescore.Result, error)
+}
+
+// AttributeUpdateConsumer consumes entity attribute updates in its own
+// consumer group so re-scoring never holds up resolution
+type AttributeUpdateConsumer struct {
+	consumer sarama.ConsumerGroup
+	rescorer Rescorer
+	topic    string
+	logger   *slog.Logger
+}
+
+// NewAttributeUpdateConsumer creates a new attribute update consumer
+func NewAttributeUpdateConsumer(kafkaConfig config.KafkaConfig, rescoreConfig config.RescoreConfig, rescorer Rescorer, logger *slog.Logger) (*AttributeUpdateConsumer, error) {
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRoundRobin
+	saramaConfig.Consumer.Offsets.Initial = sarama.OffsetNewest
+	saramaConfig.Consumer.Group.Session.Timeout = 10 * time.Second
+	saramaConfig.Consumer.Group.Heartbeat.Interval = 3 * time.Second
+
+	consumer, err := sarama.NewConsumerGroup(kafkaConfig.Brokers, rescoreConfig.ConsumerGroup, saramaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka attribute update consumer: %w", err)
+	}
+
+	return &AttributeUpdateConsumer{
+		consumer: consumer,
+		rescorer: rescorer,
+		topic:    kafkaConfig.AttributeUpdatedTopic,
+		logger:   logger,
+	}, nil
+}
+
+// Close closes the attribute update consumer
+func (c *AttributeUpdateConsumer) Close() error {
+	return c.consumer.Close()
+}
+
+// Start consumes attribute updates until the context is cancelled
+func (c *AttributeUpdateConsumer) Start(ctx context.Context) error {
+	topics := []string{c.topic}
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.logger.Info("Kafka attribute update consumer context cancelled")
+			return ctx.Err()
+		default:
+			if err := c.consumer.Consume(ctx, topics, c); err != nil {
+				c.logger.Error("Kafka attribute update consumer error", "error", err)
+				return err
+			}
+		}
+	}
+}
+
+// Setup implements sarama.ConsumerGroupHandler
+func (c *AttributeUpdateConsumer) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// Cleanup implements sarama.ConsumerGroupHandler
+func (c *AttributeUpdateConsumer) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim implements sarama.ConsumerGroupHandler. Updates that can
+// never apply, malformed or naming an unknown entity, are skipped; others
+// that fail are left unmarked.
+func (c *AttributeUpdateConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case message := <-claim.Messages():
+			if message == nil {
+				return nil
+			}
+
+			err := c.processMessage(session.Context(), message)
+			switch {
+			case err == nil:
+				session.MarkMessage(message, "")
+			case errors.Is(err, errMalformedEvent), errors.Is(err, rescore.ErrEntityRequired), errors.Is(err, database.ErrEntityNotFound):
+				c.logger.Warn("Skipping attribute update",
+					"partition", message.Partition,
+					"offset", message.Offset,
+					"error", err)
+				session.MarkMessage(message, "")
+			default:
+				c.logger.Error("Failed to process attribute update",
+					"partition", message.Partition,
+					"offset", message.Offset,
+					"error", err)
+			}
+
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+// errMalformedEvent marks attribute updates that cannot be decoded
+var errMalformedEvent = errors.New("malformed attribute update event")
+
+func (c *AttributeUpdateConsumer) processMessage(ctx context.Context, message *sarama.ConsumerMessage) error {
+	var event AttributeUpdatedEvent
+	if err := json.Unmarshal(message.Value, &event); err != nil {
+		return fmt.Errorf("%w: %v", errMalformedEvent, err)
+	}
+
+	var entityID uuid.UUID
+	if event.EntityID != "" {
+		var err error
+		if entityID, err = uuid.Parse(event.EntityID); err != nil {
+			return fmt.Errorf("%w: invalid entity ID: %v", errMalformedEvent, err)
+		}
+	}
+
+	_, err := c.rescorer.Rescore(ctx, &rescore.Update{
+		EventID:     event.EventID,
+		EntityID:    entityID,
+		Identifiers: event.Identifiers,
+		Attributes:  event.Attributes,
+		SourceID:    event.SourceID,
+		OccurredAt:  event.Timestamp,
+	})
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
 	"github.com/aegisshield/entity-resolution/internal/resolver"
 	"github.com/IBM/sarama"
 	"github.com/google/uuid"
@@ -59,6 +60,16 @@ type EntityLinkEvent struct {
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// ScreeningHitEvent announces a new sanctions or PEP screening hit. It uses
+// the event envelope consumed by the alerting engine.
+type ScreeningHitEvent struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	Source    string                 `json:"source"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data"`
+}
+
 // TransactionEvent represents a transaction for entity resolution
 type TransactionEvent struct {
 	TransactionID   string                 `json:"transaction_id"`
@@ -191,6 +202,31 @@ func (p *Producer) PublishBatchJobStatus(ctx context.Context, job *resolver.Batc
 	return p.publishEvent(ctx, p.config.BatchJobTopic, job.JobID, event)
 }
 
+// PublishScreeningHit publishes a new screening hit, keyed by entity
+func (p *Producer) PublishScreeningHit(ctx context.Context, hit *database.ScreeningHit) error {
+	event := &ScreeningHitEvent{
+		ID:        uuid.New().String(),
+		Type:      "entity.screening.hit",
+		Source:    "entity-resolution",
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"hit_id":       hit.ID.String(),
+			"entity_id":    hit.EntityID.String(),
+			"entity_type":  hit.EntityType,
+			"entity_name":  hit.EntityName,
+			"list":         hit.ListName,
+			"list_kind":    hit.ListKind,
+			"entry_id":     hit.EntryID,
+			"entry_name":   hit.EntryName,
+			"matched_name": hit.MatchedName,
+			"score":        hit.Score,
+			"evidence":     hit.Evidence,
+		},
+	}
+
+	return p.publishEvent(ctx, p.config.ScreeningHitTopic, hit.EntityID.String(), event)
+}
+
 // publishEvent publishes an event to the specified topic
 func (p *Producer) publishEvent(ctx context.Context, topic, key string, event interface{}) error {
 	data, err := json.Marshal(event)
@@ -0,0 +1,34 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+	"github.com/aegisshield/entity-resolution/internal/config"
+)
+
+// TopicLister lists the topics on the brokers, for checking at startup that
+// consumed topics exist
+type TopicLister struct {
+	brokers []string
+}
+
+// NewTopicLister creates a topic lister for the configured brokers
+func NewTopicLister(kafkaConfig config.KafkaConfig) *TopicLister {
+	return &TopicLister{brokers: kafkaConfig.Brokers}
+}
+
+// ListTopics returns the names of the topics in the cluster's metadata
+func (l *TopicLister) ListTopics(ctx context.Context) ([]string, error) {
+	client, err := sarama.NewClient(l.brokers, sarama.NewConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Kafka: %w", err)
+	}
+	defer client.Close()
+
+	if err := client.RefreshMetadata(); err != nil {
+		return nil, fmt.Errorf("failed to refresh Kafka metadata: %w", err)
+	}
+	return client.Topics()
+}
@@ -0,0 +1,347 @@
+package lineage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/neo4j"
+	"github.com/google/uuid"
+)
+
+// Store persists the lineage audit trail
+type Store interface {
+	RecordLineageEvent(ctx context.Context, event *database.LineageEvent) error
+	ListLineageEvents(ctx context.Context, entityIDs []uuid.UUID, limit int) ([]*database.LineageEvent, error)
+	ListUnsyncedLineageEvents(ctx context.Context, limit int) ([]*database.LineageEvent, error)
+	MarkLineageEventsSynced(ctx context.Context, ids []uuid.UUID) error
+	GetEntity(ctx context.Context, id uuid.UUID) (*database.Entity, error)
+}
+
+// Graph mirrors lineage events as nodes and edges
+type Graph interface {
+	RecordLineage(ctx context.Context, event *neo4j.LineageNode) error
+}
+
+// ResolutionRecord describes a source record resolved into an entity, with
+// the entity's fields before and after
+type ResolutionRecord struct {
+	EntityID uuid.UUID
+	Created  bool
+	SourceID string
+	Before   database.EntityFields
+	After    database.EntityFields
+}
+
+// Contributor is an entity merged, directly or through another contributor,
+// into the entity whose history is reconstructed
+type Contributor struct {
+	EntityID    uuid.UUID  `json:"entity_id"`
+	MergedInto  uuid.UUID  `json:"merged_into"`
+	Depth       int        `json:"depth"` // 1 for entities merged directly into the entity
+	MergedAt    time.Time  `json:"merged_at"`
+	MergedBy    string     `json:"merged_by,omitempty"`
+	ReferenceID string     `json:"reference_id,omitempty"`
+	SplitAt     *time.Time `json:"split_at,omitempty"`
+	SplitBy     string     `json:"split_by,omitempty"`
+}
+
+// History is how an entity was assembled: every entity merged into it, every
+// source record resolved into it or its contributors, and each event in order
+type History struct {
+	EntityID     uuid.UUID                `json:"entity_id"`
+	Entity       *database.Entity         `json:"entity,omitempty"` // nil once the entity is deleted
+	Contributors []*Contributor           `json:"contributors"`
+	Sources      []string                 `json:"sources"`
+	Events       []*database.LineageEvent `json:"events"`
+	Truncated    bool                     `json:"truncated"`
+}
+
+// SyncResult reports a graph sync pass
+type SyncResult struct {
+	Synced    int  `json:"synced"`
+	Remaining bool `json:"remaining"` // more unsynced events than one batch
+}
+
+// Service records the lineage audit trail of entities and reconstructs their
+// history from it. Merges and splits record their events in the transaction
+// that applies them; resolution events are recorded here. Events are
+// mirrored in the graph, with misses retried by Start.
+type Service struct {
+	store  Store
+	graph  Graph
+	config config.LineageConfig
+	logger *slog.Logger
+}
+
+// NewService creates a new lineage service
+func NewService(store Store, graph Graph, config config.LineageConfig, logger *slog.Logger) *Service {
+	return &Service{
+		store:  store,
+		graph:  graph,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Start mirrors unsynced events in the graph every sync interval until the
+// context is cancelled
+func (s *Service) Start(ctx context.Context) {
+	if !s.graphEnabled() {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.GraphSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				result, err := s.SyncGraph(ctx)
+				if err != nil {
+					s.logger.Warn("Failed to sync lineage to graph", "error", err)
+					break
+				}
+				if !result.Remaining || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// RecordResolution records a source record creating or updating an entity
+func (s *Service) RecordResolution(ctx context.Context, record *ResolutionRecord) error {
+	changes, err := database.DiffEntityFields(record.Before, record.After)
+	if err != nil {
+		return err
+	}
+
+	eventType := database.LineageRecordMerged
+	if record.Created {
+		eventType = database.LineageCreated
+	}
+
+	return s.Record(ctx, &database.LineageEvent{
+		EntityID:  record.EntityID,
+		EventType: eventType,
+		SourceID:  record.SourceID,
+		Changes:   changes,
+	})
+}
+
+// Record appends an event to the audit trail and mirrors it in the graph.
+// Graph failures are logged and left for the next sync.
+func (s *Service) Record(ctx context.Context, event *database.LineageEvent) error {
+	if err := s.store.RecordLineageEvent(ctx, event); err != nil {
+		return err
+	}
+
+	if !s.graphEnabled() {
+		return nil
+	}
+	if err := s.mirror(ctx, event); err != nil {
+		s.logger.Warn("Failed to mirror lineage event in graph",
+			"event_id", event.ID,
+			"entity_id", event.EntityID,
+			"error", err)
+		return nil
+	}
+	if err := s.store.MarkLineageEventsSynced(ctx, []uuid.UUID{event.ID}); err != nil {
+		s.logger.Warn("Failed to mark lineage event synced", "event_id", event.ID, "error", err)
+	}
+
+	return nil
+}
+
+// SyncGraph mirrors one batch of unsynced events in the graph, oldest first,
+// stopping at the first failure
+func (s *Service) SyncGraph(ctx context.Context) (*SyncResult, error) {
+	result := &SyncResult{}
+	if !s.graphEnabled() {
+		return result, nil
+	}
+
+	events, err := s.store.ListUnsyncedLineageEvents(ctx, s.config.GraphSyncBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var synced []uuid.UUID
+	var syncErr error
+	for _, event := range events {
+		if syncErr = s.mirror(ctx, event); syncErr != nil {
+			break
+		}
+		synced = append(synced, event.ID)
+	}
+
+	if err := s.store.MarkLineageEventsSynced(ctx, synced); err != nil {
+		return nil, err
+	}
+
+	result.Synced = len(synced)
+	result.Remaining = syncErr == nil && len(events) == s.config.GraphSyncBatchSize
+	if result.Synced > 0 {
+		s.logger.Info("Synced lineage events to graph", "synced", result.Synced)
+	}
+
+	return result, syncErr
+}
+
+func (s *Service) graphEnabled() bool {
+	return s.graph != nil && s.config.GraphEnabled
+}
+
+func (s *Service) mirror(ctx context.Context, event *database.LineageEvent) error {
+	changes, err := json.Marshal(event.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lineage changes: %w", err)
+	}
+
+	node := &neo4j.LineageNode{
+		ID:          event.ID.String(),
+		EntityID:    event.EntityID.String(),
+		EventType:   event.EventType,
+		SourceID:    event.SourceID,
+		Actor:       event.Actor,
+		ReferenceID: event.ReferenceID,
+		Changes:     string(changes),
+		OccurredAt:  event.OccurredAt,
+	}
+	if event.RelatedEntityID != nil {
+		node.RelatedEntityID = event.RelatedEntityID.String()
+	}
+
+	return s.graph.RecordLineage(ctx, node)
+}
+
+// History reconstructs how an entity was assembled. Entities merged into it
+// are followed, level by level, up to the configured merge depth, so the
+// history covers the source records behind every contributor.
+func (s *Service) History(ctx context.Context, entityID uuid.UUID) (*History, error) {
+	history := &History{
+		EntityID:     entityID,
+		Contributors: []*Contributor{},
+		Sources:      []string{},
+		Events:       []*database.LineageEvent{},
+	}
+
+	entity, err := s.store.GetEntity(ctx, entityID)
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return nil, err
+	}
+	history.Entity = entity
+
+	members := map[uuid.UUID]int{entityID: 0}
+	contributors := make(map[uuid.UUID]*Contributor)
+	seen := make(map[uuid.UUID]bool)
+	var events []*database.LineageEvent
+
+	frontier := []uuid.UUID{entityID}
+	for depth := 0; len(frontier) > 0; depth++ {
+		level, err := s.store.ListLineageEvents(ctx, frontier, s.config.MaxEvents+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(level) > s.config.MaxEvents {
+			history.Truncated = true
+		}
+
+		var next []uuid.UUID
+		for _, event := range level {
+			if seen[event.ID] {
+				continue
+			}
+			seen[event.ID] = true
+			events = append(events, event)
+
+			if event.EventType != database.LineageEntityMerged || event.RelatedEntityID == nil {
+				continue
+			}
+			if _, ok := members[event.EntityID]; !ok {
+				continue
+			}
+			related := *event.RelatedEntityID
+			if _, ok := members[related]; ok {
+				continue
+			}
+			if depth+1 > s.config.MaxMergeDepth {
+				history.Truncated = true
+				continue
+			}
+
+			members[related] = depth + 1
+			contributors[related] = &Contributor{
+				EntityID:    related,
+				MergedInto:  event.EntityID,
+				Depth:       depth + 1,
+				MergedAt:    event.OccurredAt,
+				MergedBy:    event.Actor,
+				ReferenceID: event.ReferenceID,
+			}
+			next = append(next, related)
+		}
+		frontier = next
+	}
+
+	if entity == nil && len(events) == 0 {
+		return nil, fmt.Errorf("entity not found: %s", entityID)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].OccurredAt.Equal(events[j].OccurredAt) {
+			return events[i].OccurredAt.Before(events[j].OccurredAt)
+		}
+		return events[i].ID.String() < events[j].ID.String()
+	})
+	if len(events) > s.config.MaxEvents {
+		events = events[:s.config.MaxEvents]
+		history.Truncated = true
+	}
+	history.Events = events
+
+	sources := make(map[string]bool)
+	for _, event := range events {
+		if _, member := members[event.EntityID]; !member {
+			continue
+		}
+
+		switch event.EventType {
+		case database.LineageCreated, database.LineageRecordMerged, database.LineageAttributesUpdated:
+			if event.SourceID != "" && !sources[event.SourceID] {
+				sources[event.SourceID] = true
+				history.Sources = append(history.Sources, event.SourceID)
+			}
+		case database.LineageEntitySplit:
+			// A later split ends the contribution of the merge it undoes
+			if event.RelatedEntityID == nil {
+				continue
+			}
+			contributor, ok := contributors[*event.RelatedEntityID]
+			if ok && contributor.MergedInto == event.EntityID && event.OccurredAt.After(contributor.MergedAt) {
+				splitAt := event.OccurredAt
+				contributor.SplitAt = &splitAt
+				contributor.SplitBy = event.Actor
+			}
+		}
+	}
+
+	for _, contributor := range contributors {
+		history.Contributors = append(history.Contributors, contributor)
+	}
+	sort.Slice(history.Contributors, func(i, j int) bool {
+		return history.Contributors[i].MergedAt.Before(history.Contributors[j].MergedAt)
+	})
+
+	return history, nil
+}
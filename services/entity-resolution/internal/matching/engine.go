@@ -5,6 +5,7 @@ import (
 	"math"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/aegisshield/entity-resolution/internal/config"
 	"github.com/aegisshield/entity-resolution/internal/standardization"
@@ -20,6 +21,11 @@ type Engine struct {
 	nameIndex    *radix.Tree
 	phoneIndex   map[string][]string
 	emailIndex   map[string][]string
+
+	mu           sync.RWMutex
+	names        NameSettings
+	defaultModel Model
+	models       map[string]Model // by entity type
 }
 
 // MatchCandidate represents a potential entity match
@@ -36,6 +42,7 @@ type MatchCandidate struct {
 
 // MatchInput represents input data for matching
 type MatchInput struct {
+	EntityType string            `json:"entity_type,omitempty"` // selects the matching model
 	Name       string            `json:"name"`
 	Address    string            `json:"address"`
 	Phone      string            `json:"phone"`
@@ -62,11 +69,16 @@ func NewEngine(config config.MatchingConfig, standardizer *standardization.Engin
 		nameIndex:    radix.New(),
 		phoneIndex:   make(map[string][]string),
 		emailIndex:   make(map[string][]string),
+		names:        DefaultNameSettings(config),
+		defaultModel: DefaultModel(config),
+		models:       make(map[string]Model),
 	}
 }
 
-// FindMatches finds potential matches for the given input
+// FindMatches finds potential matches for the given input, scored under the
+// matching model of the input's entity type
 func (e *Engine) FindMatches(input *MatchInput, candidateEntities []CandidateEntity) (*MatchResult, error) {
+	model := e.Model(input.EntityType)
 	result := &MatchResult{
 		Query:      input,
 		Candidates: []*MatchCandidate{},
@@ -80,9 +92,9 @@ func (e *Engine) FindMatches(input *MatchInput, candidateEntities []CandidateEnt
 
 	// Score each candidate
 	for _, candidate := range candidateEntities {
-		score := e.calculateMatchScore(input, &candidate)
+		score := e.calculateMatchScore(input, &candidate, model)
 		
-		if score.OverallScore >= e.config.OverallSimilarityThreshold {
+		if score.OverallScore >= model.Threshold {
 			result.Candidates = append(result.Candidates, score)
 		}
 	}
@@ -100,7 +112,7 @@ func (e *Engine) FindMatches(input *MatchInput, candidateEntities []CandidateEnt
 	// Determine best match
 	if len(result.Candidates) > 0 {
 		result.BestMatch = result.Candidates[0]
-		result.IsMatch = result.BestMatch.OverallScore >= e.config.OverallSimilarityThreshold
+		result.IsMatch = result.BestMatch.OverallScore >= model.Threshold
 		result.MatchConfidence = result.BestMatch.OverallScore
 	}
 
@@ -115,10 +127,11 @@ type CandidateEntity struct {
 	Phone       string            `json:"phone"`
 	Email       string            `json:"email"`
 	Identifiers map[string]string `json:"identifiers"`
+	Aliases     []string          `json:"aliases,omitempty"`
 }
 
 // calculateMatchScore calculates the overall match score between input and candidate
-func (e *Engine) calculateMatchScore(input *MatchInput, candidate *CandidateEntity) *MatchCandidate {
+func (e *Engine) calculateMatchScore(input *MatchInput, candidate *CandidateEntity, model Model) *MatchCandidate {
 	matchCandidate := &MatchCandidate{
 		EntityID:          candidate.ID,
 		IdentifierMatches: make(map[string]float64),
@@ -126,8 +139,17 @@ func (e *Engine) calculateMatchScore(input *MatchInput, candidate *CandidateEnti
 	}
 
 	// Calculate individual scores
-	matchCandidate.NameScore = e.calculateNameSimilarity(input.Name, candidate.Name)
-	matchCandidate.AddressScore = e.calculateAddressSimilarity(input.Address, candidate.Address)
+	nameAlgorithms := model.Comparators[ComparatorName]
+	matchCandidate.NameScore = e.calculateNameSimilarity(input.Name, candidate.Name, nameAlgorithms)
+	matchedAlias := ""
+	for _, alias := range candidate.Aliases {
+		// DBA, trade and former names count as the candidate's name
+		if aliasScore := e.calculateNameSimilarity(input.Name, alias, nameAlgorithms); aliasScore > matchCandidate.NameScore {
+			matchCandidate.NameScore = aliasScore
+			matchedAlias = alias
+		}
+	}
+	matchCandidate.AddressScore = e.calculateAddressSimilarity(input.Address, candidate.Address, model)
 	matchCandidate.PhoneScore = e.calculatePhoneSimilarity(input.Phone, candidate.Phone)
 	matchCandidate.EmailScore = e.calculateEmailSimilarity(input.Email, candidate.Email)
 
@@ -139,7 +161,8 @@ func (e *Engine) calculateMatchScore(input *MatchInput, candidate *CandidateEnti
 	}
 
 	// Calculate weighted overall score
-	matchCandidate.OverallScore = e.calculateWeightedScore(matchCandidate)
+	matchCandidate.OverallScore = e.calculateWeightedScore(matchCandidate, model.Weights)
+	matchCandidate.Evidence["matching_model"] = model.EntityType
 
 	// Store evidence
 	matchCandidate.Evidence["name_comparison"] = map[string]interface{}{
@@ -147,6 +170,9 @@ func (e *Engine) calculateMatchScore(input *MatchInput, candidate *CandidateEnti
 		"candidate_name":  candidate.Name,
 		"similarity":      matchCandidate.NameScore,
 	}
+	if matchedAlias != "" {
+		matchCandidate.Evidence["matched_alias"] = matchedAlias
+	}
 
 	if input.Address != "" && candidate.Address != "" {
 		matchCandidate.Evidence["address_comparison"] = map[string]interface{}{
@@ -159,52 +185,21 @@ func (e *Engine) calculateMatchScore(input *MatchInput, candidate *CandidateEnti
 	return matchCandidate
 }
 
-// Name similarity calculation
-func (e *Engine) calculateNameSimilarity(name1, name2 string) float64 {
+// Name similarity calculation: the best score among the given algorithms,
+// or the name screening algorithms when none are given
+func (e *Engine) calculateNameSimilarity(name1, name2 string, algorithms []string) float64 {
 	if name1 == "" || name2 == "" {
 		return 0.0
 	}
 
-	// Standardize names
-	std1 := e.standardizer.StandardizeName(name1)
-	std2 := e.standardizer.StandardizeName(name2)
-
-	var maxScore float64
-
-	// Exact match on standardized names
-	if std1.Standardized == std2.Standardized {
-		maxScore = 1.0
-	}
-
-	// Fuzzy matching if enabled
-	if e.config.FuzzyMatchingEnabled {
-		// Levenshtein similarity on standardized names
-		levenScore := e.calculateLevenshteinSimilarity(std1.Standardized, std2.Standardized)
-		maxScore = math.Max(maxScore, levenScore)
-
-		// Token-based similarity
-		tokenScore := e.calculateTokenSimilarity(std1.Tokens, std2.Tokens)
-		maxScore = math.Max(maxScore, tokenScore)
-	}
-
-	// Phonetic matching if enabled
-	if e.config.PhoneticMatchingEnabled {
-		// Phonetic similarity
-		if std1.Phonetic == std2.Phonetic && std1.Phonetic != "" {
-			maxScore = math.Max(maxScore, 0.8)
-		}
-
-		// Metaphone similarity
-		if std1.Metaphone == std2.Metaphone && std1.Metaphone != "" {
-			maxScore = math.Max(maxScore, 0.75)
-		}
+	if len(algorithms) == 0 {
+		algorithms = e.NameSettings().Algorithms
 	}
-
-	return maxScore
+	return CombineNameScores(e.NameScores(name1, name2), algorithms)
 }
 
 // Address similarity calculation
-func (e *Engine) calculateAddressSimilarity(addr1, addr2 string) float64 {
+func (e *Engine) calculateAddressSimilarity(addr1, addr2 string, model Model) float64 {
 	if addr1 == "" || addr2 == "" {
 		return 0.0
 	}
@@ -227,13 +222,13 @@ func (e *Engine) calculateAddressSimilarity(addr1, addr2 string) float64 {
 
 	// Street name comparison (fuzzy)
 	if std1.StreetName != "" && std2.StreetName != "" {
-		streetScore := e.calculateLevenshteinSimilarity(std1.StreetName, std2.StreetName)
+		streetScore := e.calculateFieldSimilarity(std1.StreetName, std2.StreetName, model.Comparators[ComparatorStreet])
 		componentScores = append(componentScores, streetScore*0.8) // Weight street name highly
 	}
 
 	// City comparison (fuzzy)
 	if std1.City != "" && std2.City != "" {
-		cityScore := e.calculateLevenshteinSimilarity(std1.City, std2.City)
+		cityScore := e.calculateFieldSimilarity(std1.City, std2.City, model.Comparators[ComparatorCity])
 		componentScores = append(componentScores, cityScore*0.6)
 	}
 
@@ -341,6 +336,18 @@ func (e *Engine) calculateExactMatch(value1, value2 string) float64 {
 	return 0.0
 }
 
+// Field similarity calculation: the best score among the field's configured
+// algorithms, or Levenshtein when none are configured
+func (e *Engine) calculateFieldSimilarity(s1, s2 string, algorithms []string) float64 {
+	if len(algorithms) == 0 {
+		return e.calculateLevenshteinSimilarity(s1, s2)
+	}
+
+	std1 := e.standardizer.StandardizeName(s1)
+	std2 := e.standardizer.StandardizeName(s2)
+	return CombineNameScores(e.textScores(std1, std2), algorithms)
+}
+
 // Levenshtein similarity calculation
 func (e *Engine) calculateLevenshteinSimilarity(s1, s2 string) float64 {
 	if s1 == "" && s2 == "" {
@@ -402,46 +409,28 @@ func (e *Engine) calculateTokenSimilarity(tokens1, tokens2 []string) float64 {
 	return float64(intersection) / float64(union)
 }
 
-// Weighted score calculation
-func (e *Engine) calculateWeightedScore(candidate *MatchCandidate) float64 {
+// Weighted score calculation under the model's field weights. Fields
+// without a score do not count, so a sparse record is not penalized for
+// what it lacks.
+func (e *Engine) calculateWeightedScore(candidate *MatchCandidate, weights map[string]float64) float64 {
 	var score float64
 	var totalWeight float64
 
-	// Name weight (highest)
-	nameWeight := 0.4
-	if candidate.NameScore > 0 {
-		score += candidate.NameScore * nameWeight
-		totalWeight += nameWeight
-	}
-
-	// Address weight
-	addressWeight := 0.25
-	if candidate.AddressScore > 0 {
-		score += candidate.AddressScore * addressWeight
-		totalWeight += addressWeight
+	add := func(weight, fieldScore float64) {
+		if fieldScore > 0 && weight > 0 {
+			score += fieldScore * weight
+			totalWeight += weight
+		}
 	}
 
-	// Phone weight
-	phoneWeight := 0.15
-	if candidate.PhoneScore > 0 {
-		score += candidate.PhoneScore * phoneWeight
-		totalWeight += phoneWeight
-	}
+	add(weights[FieldName], candidate.NameScore)
+	add(weights[FieldAddress], candidate.AddressScore)
+	add(weights[FieldPhone], candidate.PhoneScore)
+	add(weights[FieldEmail], candidate.EmailScore)
 
-	// Email weight
-	emailWeight := 0.1
-	if candidate.EmailScore > 0 {
-		score += candidate.EmailScore * emailWeight
-		totalWeight += emailWeight
-	}
-
-	// Identifier weights (very high for exact matches)
-	identifierWeight := 0.1
+	// The identifier weight applies to each matching identifier
 	for _, identifierScore := range candidate.IdentifierMatches {
-		if identifierScore > 0 {
-			score += identifierScore * identifierWeight
-			totalWeight += identifierWeight
-		}
+		add(weights[FieldIdentifier], identifierScore)
 	}
 
 	// Normalize by total weight
@@ -467,9 +456,12 @@ func (e *Engine) applyBlocking(input *MatchInput, candidates []CandidateEntity)
 	// Filter candidates that share the blocking key
 	var filtered []CandidateEntity
 	for _, candidate := range candidates {
-		candidateKey := e.generateBlockingKey(candidate.Name)
-		if candidateKey != "" && e.shareBlockingKey(blockingKey, candidateKey) {
-			filtered = append(filtered, candidate)
+		for _, name := range append([]string{candidate.Name}, candidate.Aliases...) {
+			candidateKey := e.generateBlockingKey(name)
+			if candidateKey != "" && e.shareBlockingKey(blockingKey, candidateKey) {
+				filtered = append(filtered, candidate)
+				break
+			}
 		}
 	}
 
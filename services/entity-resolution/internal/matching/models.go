@@ -0,0 +1,230 @@
+package matching
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+)
+
+// Fields weighted by a matching model
+const (
+	FieldName       = "name"
+	FieldAddress    = "address"
+	FieldPhone      = "phone"
+	FieldEmail      = "email"
+	FieldIdentifier = "identifier" // each matching identifier
+)
+
+// Fields compared by configurable algorithms
+const (
+	ComparatorName   = "name"
+	ComparatorStreet = "street"
+	ComparatorCity   = "city"
+)
+
+// DefaultModelType is the entity type of the model used for entity types
+// without a model of their own
+const DefaultModelType = "*"
+
+// ModelFields lists every weighted field
+var ModelFields = []string{FieldName, FieldAddress, FieldPhone, FieldEmail, FieldIdentifier}
+
+// ModelComparators lists every field with configurable algorithms
+var ModelComparators = []string{ComparatorName, ComparatorStreet, ComparatorCity}
+
+// ErrInvalidModel is returned when a matching model is rejected
+var ErrInvalidModel = errors.New("invalid matching model")
+
+// Model weights the fields of an entity type's records, picks the
+// algorithms comparing them and sets the overall score a candidate needs to
+// match. Without name comparators names are compared under the name
+// screening settings, so tuned thresholds keep applying.
+type Model struct {
+	EntityType  string              `json:"entity_type"`
+	Weights     map[string]float64  `json:"weights"`
+	Comparators map[string][]string `json:"comparators,omitempty"`
+	Threshold   float64             `json:"threshold"`
+}
+
+// DefaultModel derives the model used for every entity type from
+// configuration
+func DefaultModel(config config.MatchingConfig) Model {
+	comparators := map[string][]string{}
+	if len(config.StreetAlgorithms) > 0 {
+		comparators[ComparatorStreet] = config.StreetAlgorithms
+	}
+	if len(config.CityAlgorithms) > 0 {
+		comparators[ComparatorCity] = config.CityAlgorithms
+	}
+
+	return Model{
+		EntityType: DefaultModelType,
+		Weights: map[string]float64{
+			FieldName:       0.4,
+			FieldAddress:    0.25,
+			FieldPhone:      0.15,
+			FieldEmail:      0.1,
+			FieldIdentifier: 0.1,
+		},
+		Comparators: comparators,
+		Threshold:   config.OverallSimilarityThreshold,
+	}
+}
+
+// WithDefaults fills the weights, comparators and threshold a model leaves
+// out from the base model
+func (m Model) WithDefaults(base Model) Model {
+	if m.Threshold == 0 {
+		m.Threshold = base.Threshold
+	}
+
+	weights := make(map[string]float64, len(ModelFields))
+	for field, weight := range base.Weights {
+		weights[field] = weight
+	}
+	for field, weight := range m.Weights {
+		weights[field] = weight
+	}
+
+	comparators := make(map[string][]string, len(ModelComparators))
+	for field, algorithms := range base.Comparators {
+		comparators[field] = algorithms
+	}
+	for field, algorithms := range m.Comparators {
+		comparators[field] = algorithms
+	}
+
+	m.Weights = weights
+	m.Comparators = comparators
+	return m
+}
+
+// Validate checks the weights, comparators and threshold, normalizing each
+// comparator's algorithms into a sorted set
+func (m *Model) Validate() error {
+	if m.EntityType == "" {
+		return fmt.Errorf("%w: entity type is required", ErrInvalidModel)
+	}
+	if m.Threshold <= 0 || m.Threshold > 1 {
+		return fmt.Errorf("%w: threshold must be greater than 0 and at most 1", ErrInvalidModel)
+	}
+
+	var total float64
+	for field, weight := range m.Weights {
+		if !contains(ModelFields, field) {
+			return fmt.Errorf("%w: unknown field %q", ErrInvalidModel, field)
+		}
+		if weight < 0 || weight > 1 {
+			return fmt.Errorf("%w: %s weight must be between 0 and 1", ErrInvalidModel, field)
+		}
+		total += weight
+	}
+	if total == 0 {
+		return fmt.Errorf("%w: at least one field must be weighted", ErrInvalidModel)
+	}
+
+	for field, algorithms := range m.Comparators {
+		if !contains(ModelComparators, field) {
+			return fmt.Errorf("%w: unknown comparator field %q", ErrInvalidModel, field)
+		}
+		normalized, err := NormalizeNameAlgorithms(algorithms)
+		if err != nil {
+			return fmt.Errorf("%w: %s comparators: %v", ErrInvalidModel, field, err)
+		}
+		m.Comparators[field] = normalized
+	}
+
+	return nil
+}
+
+// Model returns the matching model for an entity type, or the default model
+// when the type has none
+func (e *Engine) Model(entityType string) Model {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if model, ok := e.models[entityType]; ok {
+		return model
+	}
+	return e.defaultModel
+}
+
+// Models returns the matching models in effect, the default model first
+func (e *Engine) Models() []Model {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	models := make([]Model, 0, len(e.models)+1)
+	models = append(models, e.defaultModel)
+	for _, model := range e.models {
+		models = append(models, model)
+	}
+	sort.Slice(models[1:], func(i, j int) bool {
+		return models[i+1].EntityType < models[j+1].EntityType
+	})
+	return models
+}
+
+// ValidateModels checks that a set of matching models could be applied
+func (e *Engine) ValidateModels(models []Model) error {
+	_, _, err := e.resolveModels(models)
+	return err
+}
+
+// ApplyModels replaces the matching models. A model for DefaultModelType
+// replaces the configured default; other models fill what they leave out
+// from the default. Nothing is applied when any model is invalid, and
+// matches already in progress finish under the models they started with.
+func (e *Engine) ApplyModels(models []Model) error {
+	defaultModel, byType, err := e.resolveModels(models)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.defaultModel = defaultModel
+	e.models = byType
+	e.mu.Unlock()
+
+	e.logger.Info("Matching models applied", "models", len(byType))
+	return nil
+}
+
+// resolveModels fills each model from the default and validates it
+func (e *Engine) resolveModels(models []Model) (Model, map[string]Model, error) {
+	defaultModel := DefaultModel(e.config)
+	for _, model := range models {
+		if model.EntityType != DefaultModelType {
+			continue
+		}
+		defaultModel = model.WithDefaults(defaultModel)
+		if err := defaultModel.Validate(); err != nil {
+			return Model{}, nil, fmt.Errorf("default model: %w", err)
+		}
+	}
+
+	byType := make(map[string]Model, len(models))
+	for _, model := range models {
+		if model.EntityType == DefaultModelType {
+			continue
+		}
+		model = model.WithDefaults(defaultModel)
+		if err := model.Validate(); err != nil {
+			return Model{}, nil, fmt.Errorf("entity type %s: %w", model.EntityType, err)
+		}
+		byType[model.EntityType] = model
+	}
+
+	return defaultModel, byType, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, known := range values {
+		if value == known {
+			return true
+		}
+	}
+	return false
+}
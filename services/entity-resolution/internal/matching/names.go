@@ -0,0 +1,284 @@
+package matching
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/standardization"
+)
+
+// Name similarity algorithms
+const (
+	NameAlgorithmExact       = "exact"
+	NameAlgorithmLevenshtein = "levenshtein"
+	NameAlgorithmToken       = "token"
+	NameAlgorithmPhonetic    = "phonetic"
+	NameAlgorithmMetaphone   = "metaphone"
+)
+
+// Scores given to names that sound alike; a phonetic code match is weaker
+// evidence than the names themselves being similar
+const (
+	phoneticMatchScore  = 0.8
+	metaphoneMatchScore = 0.75
+)
+
+// NameAlgorithms lists every name similarity algorithm
+var NameAlgorithms = []string{
+	NameAlgorithmExact,
+	NameAlgorithmLevenshtein,
+	NameAlgorithmToken,
+	NameAlgorithmPhonetic,
+	NameAlgorithmMetaphone,
+}
+
+// ErrInvalidNameSettings is returned when name screening settings are rejected
+var ErrInvalidNameSettings = errors.New("invalid name screening settings")
+
+// NameSettings controls how names are compared when screening: a name
+// matches when the best score among the enabled algorithms reaches the
+// threshold
+type NameSettings struct {
+	Threshold  float64  `json:"threshold"`
+	Algorithms []string `json:"algorithms"`
+}
+
+// NameScores holds the similarity of two names under each algorithm
+type NameScores map[string]float64
+
+// DefaultNameSettings derives the name screening settings from configuration.
+// Configured name algorithms take precedence over the fuzzy and phonetic
+// switches.
+func DefaultNameSettings(config config.MatchingConfig) NameSettings {
+	if algorithms, err := NormalizeNameAlgorithms(config.NameAlgorithms); err == nil {
+		return NameSettings{
+			Threshold:  config.NameSimilarityThreshold,
+			Algorithms: algorithms,
+		}
+	}
+
+	algorithms := []string{NameAlgorithmExact}
+	if config.FuzzyMatchingEnabled {
+		algorithms = append(algorithms, NameAlgorithmLevenshtein, NameAlgorithmToken)
+	}
+	if config.PhoneticMatchingEnabled {
+		algorithms = append(algorithms, NameAlgorithmPhonetic, NameAlgorithmMetaphone)
+	}
+	sort.Strings(algorithms)
+
+	return NameSettings{
+		Threshold:  config.NameSimilarityThreshold,
+		Algorithms: algorithms,
+	}
+}
+
+// Validate checks the threshold and algorithms, normalizing the algorithm
+// list into a sorted set
+func (s *NameSettings) Validate() error {
+	if s.Threshold <= 0 || s.Threshold > 1 {
+		return fmt.Errorf("%w: threshold must be greater than 0 and at most 1", ErrInvalidNameSettings)
+	}
+
+	algorithms, err := NormalizeNameAlgorithms(s.Algorithms)
+	if err != nil {
+		return err
+	}
+	s.Algorithms = algorithms
+	return nil
+}
+
+// NormalizeNameAlgorithms deduplicates and sorts an algorithm combination,
+// rejecting unknown algorithms and empty combinations
+func NormalizeNameAlgorithms(algorithms []string) ([]string, error) {
+	seen := make(map[string]bool, len(algorithms))
+	var normalized []string
+	for _, algorithm := range algorithms {
+		if !isNameAlgorithm(algorithm) {
+			return nil, fmt.Errorf("%w: unknown algorithm %q", ErrInvalidNameSettings, algorithm)
+		}
+		if !seen[algorithm] {
+			seen[algorithm] = true
+			normalized = append(normalized, algorithm)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: at least one algorithm is required", ErrInvalidNameSettings)
+	}
+
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// ValidateConfig checks the similarity algorithms configured for each field
+func ValidateConfig(config config.MatchingConfig) error {
+	fields := []struct {
+		name       string
+		algorithms []string
+		optional   bool
+	}{
+		{name: "name", algorithms: config.NameAlgorithms, optional: true},
+		{name: "street", algorithms: config.StreetAlgorithms},
+		{name: "city", algorithms: config.CityAlgorithms},
+	}
+
+	for _, field := range fields {
+		if field.optional && len(field.algorithms) == 0 {
+			continue
+		}
+		if _, err := NormalizeNameAlgorithms(field.algorithms); err != nil {
+			return fmt.Errorf("%s algorithms: %w", field.name, err)
+		}
+	}
+	return nil
+}
+
+func isNameAlgorithm(algorithm string) bool {
+	for _, known := range NameAlgorithms {
+		if algorithm == known {
+			return true
+		}
+	}
+	return false
+}
+
+// CombineNameScores returns the best score among the given algorithms
+func CombineNameScores(scores NameScores, algorithms []string) float64 {
+	var best float64
+	for _, algorithm := range algorithms {
+		best = math.Max(best, scores[algorithm])
+	}
+	return best
+}
+
+// NameScores compares two names under every algorithm
+func (e *Engine) NameScores(name1, name2 string) NameScores {
+	if name1 == "" || name2 == "" {
+		return e.textScores(nil, nil)
+	}
+
+	// Standardize names
+	std1 := e.standardizer.StandardizeName(name1)
+	std2 := e.standardizer.StandardizeName(name2)
+
+	return e.textScores(std1, std2)
+}
+
+// textScores compares two standardized texts under every algorithm. The
+// phonetic algorithms score the share of words whose codes match, so a name
+// with one misspelled word out of three still scores in proportion.
+func (e *Engine) textScores(std1, std2 *standardization.StandardizedName) NameScores {
+	scores := make(NameScores, len(NameAlgorithms))
+	for _, algorithm := range NameAlgorithms {
+		scores[algorithm] = 0
+	}
+	if std1 == nil || std2 == nil || std1.Standardized == "" || std2.Standardized == "" {
+		return scores
+	}
+
+	if std1.Standardized == std2.Standardized {
+		scores[NameAlgorithmExact] = 1.0
+	}
+
+	scores[NameAlgorithmLevenshtein] = e.calculateLevenshteinSimilarity(std1.Standardized, std2.Standardized)
+	scores[NameAlgorithmToken] = e.calculateTokenSimilarity(std1.Tokens, std2.Tokens)
+
+	scores[NameAlgorithmPhonetic] = phoneticMatchScore * codeOverlap(len(std1.PhoneticCodes), len(std2.PhoneticCodes), func(i, j int) bool {
+		return std1.PhoneticCodes[i] == std2.PhoneticCodes[j]
+	})
+	scores[NameAlgorithmMetaphone] = metaphoneMatchScore * codeOverlap(len(std1.MetaphoneCodes), len(std2.MetaphoneCodes), func(i, j int) bool {
+		return std1.MetaphoneCodes[i].Matches(std2.MetaphoneCodes[j])
+	})
+
+	return scores
+}
+
+// codeOverlap pairs the words of two texts whose phonetic codes match, each
+// word at most once, and returns the pairs found over the longer word count
+func codeOverlap(n1, n2 int, matches func(i, j int) bool) float64 {
+	if n1 == 0 || n2 == 0 {
+		return 0
+	}
+
+	used := make([]bool, n2)
+	paired := 0
+	for i := 0; i < n1; i++ {
+		for j := 0; j < n2; j++ {
+			if !used[j] && matches(i, j) {
+				used[j] = true
+				paired++
+				break
+			}
+		}
+	}
+
+	longest := n1
+	if n2 > longest {
+		longest = n2
+	}
+	return float64(paired) / float64(longest)
+}
+
+// NameSettings returns the name screening settings in effect
+func (e *Engine) NameSettings() NameSettings {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.names
+}
+
+// ApplyNameSettings replaces the name screening settings. Screens already in
+// progress finish under the settings they started with.
+func (e *Engine) ApplyNameSettings(settings NameSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.names = settings
+	e.mu.Unlock()
+
+	e.logger.Info("Name screening settings applied",
+		"threshold", settings.Threshold,
+		"algorithms", settings.Algorithms)
+	return nil
+}
+
+// ScreenName compares a name against each candidate's name and aliases,
+// returning the candidates that match under the name screening settings,
+// best first
+func (e *Engine) ScreenName(name string, candidates []CandidateEntity) []*MatchCandidate {
+	settings := e.NameSettings()
+
+	var matches []*MatchCandidate
+	for _, candidate := range candidates {
+		score := CombineNameScores(e.NameScores(name, candidate.Name), settings.Algorithms)
+		matchedName := candidate.Name
+		for _, alias := range candidate.Aliases {
+			if aliasScore := CombineNameScores(e.NameScores(name, alias), settings.Algorithms); aliasScore > score {
+				score = aliasScore
+				matchedName = alias
+			}
+		}
+
+		if score < settings.Threshold {
+			continue
+		}
+		matches = append(matches, &MatchCandidate{
+			EntityID:     candidate.ID,
+			OverallScore: score,
+			NameScore:    score,
+			Evidence: map[string]interface{}{
+				"matched_name": matchedName,
+				"threshold":    settings.Threshold,
+				"algorithms":   settings.Algorithms,
+			},
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].NameScore > matches[j].NameScore
+	})
+	return matches
+}
@@ -0,0 +1,250 @@
+package matchmodel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/matching"
+)
+
+// ErrValidation is returned when a matching model request is rejected
+var ErrValidation = errors.New("invalid matching model request")
+
+// Store persists the matching models saved through the admin API
+type Store interface {
+	SaveMatchingModel(ctx context.Context, model *database.MatchingModel) error
+	ListMatchingModels(ctx context.Context) ([]*database.MatchingModel, error)
+	DeleteMatchingModel(ctx context.Context, entityType string) error
+	GetMatchingModelsRevision(ctx context.Context) (database.MatchingModelsRevision, error)
+}
+
+// Engine scores matches under the matching models applied to it
+type Engine interface {
+	Model(entityType string) matching.Model
+	Models() []matching.Model
+	ValidateModels(models []matching.Model) error
+	ApplyModels(models []matching.Model) error
+}
+
+// UpdateRequest saves an entity type's matching model. Weights, comparators
+// and a threshold left out are taken from the default model.
+type UpdateRequest struct {
+	Weights     map[string]float64  `json:"weights,omitempty"`
+	Comparators map[string][]string `json:"comparators,omitempty"`
+	Threshold   float64             `json:"threshold,omitempty"`
+	UpdatedBy   string              `json:"updated_by"`
+	Notes       string              `json:"notes,omitempty"`
+}
+
+// Models describes the matching models in effect and the stored models and
+// revision they were loaded from
+type Models struct {
+	Effective []matching.Model                `json:"effective"`
+	Stored    []*database.MatchingModel       `json:"stored"`
+	Revision  database.MatchingModelsRevision `json:"revision"`
+}
+
+// Service stores per-entity-type matching models and hot reloads them into
+// the matching engine. A save applies to this replica at once; other
+// replicas pick it up when they next see the stored revision change.
+type Service struct {
+	store  Store
+	engine Engine
+	config config.MatchingConfig
+	logger *slog.Logger
+
+	// mu keeps loads in order so an older set of models never replaces a
+	// newer one
+	mu     sync.Mutex
+	loaded *database.MatchingModelsRevision
+}
+
+// NewService creates a new matching model service
+func NewService(store Store, engine Engine, config config.MatchingConfig, logger *slog.Logger) *Service {
+	return &Service{
+		store:  store,
+		engine: engine,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Load applies the stored matching models to the engine; without any the
+// configured default stays in effect
+func (s *Service) Load(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(ctx)
+}
+
+// Reload applies the stored matching models when they changed since they
+// were last loaded, reporting whether they had
+func (s *Service) Reload(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revision, err := s.store.GetMatchingModelsRevision(ctx)
+	if err != nil {
+		return false, err
+	}
+	if s.loaded != nil && *s.loaded == revision {
+		return false, nil
+	}
+
+	return true, s.load(ctx)
+}
+
+// Start checks for changed matching models until the context is cancelled
+func (s *Service) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.config.ModelReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Reload(ctx); err != nil {
+				s.logger.Warn("Matching model reload skipped", "error", err)
+			}
+		}
+	}
+}
+
+// List returns the matching models in effect and those stored
+func (s *Service) List(ctx context.Context) (*Models, error) {
+	stored, err := s.store.ListMatchingModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	var revision database.MatchingModelsRevision
+	if s.loaded != nil {
+		revision = *s.loaded
+	}
+	s.mu.Unlock()
+
+	return &Models{
+		Effective: s.engine.Models(),
+		Stored:    stored,
+		Revision:  revision,
+	}, nil
+}
+
+// Effective returns the matching model an entity type is scored under
+func (s *Service) Effective(entityType string) matching.Model {
+	return s.engine.Model(entityType)
+}
+
+// Update saves an entity type's matching model and applies it. The model is
+// rejected when it, or any other model relying on it, would be invalid.
+func (s *Service) Update(ctx context.Context, entityType string, req *UpdateRequest) (*matching.Model, error) {
+	if entityType == "" {
+		return nil, fmt.Errorf("%w: entity type is required", ErrValidation)
+	}
+	if req.UpdatedBy == "" {
+		return nil, fmt.Errorf("%w: updated_by is required", ErrValidation)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.store.ListMatchingModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &database.MatchingModel{
+		EntityType:  entityType,
+		Weights:     req.Weights,
+		Comparators: req.Comparators,
+		Threshold:   req.Threshold,
+		UpdatedBy:   req.UpdatedBy,
+		Notes:       req.Notes,
+		UpdatedAt:   time.Now(),
+	}
+
+	models := []matching.Model{toModel(model)}
+	for _, existing := range stored {
+		if existing.EntityType != entityType {
+			models = append(models, toModel(existing))
+		}
+	}
+	if err := s.engine.ValidateModels(models); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.SaveMatchingModel(ctx, model); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Matching model saved",
+		"entity_type", entityType,
+		"revision", model.Revision,
+		"updated_by", req.UpdatedBy)
+
+	if err := s.load(ctx); err != nil {
+		return nil, err
+	}
+
+	effective := s.engine.Model(entityType)
+	return &effective, nil
+}
+
+// Delete removes an entity type's matching model, returning the type to the
+// default model, or the default model to configuration
+func (s *Service) Delete(ctx context.Context, entityType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.store.DeleteMatchingModel(ctx, entityType); err != nil {
+		return err
+	}
+
+	s.logger.Info("Matching model deleted", "entity_type", entityType)
+	return s.load(ctx)
+}
+
+// load applies the stored matching models; the caller holds mu
+func (s *Service) load(ctx context.Context) error {
+	revision, err := s.store.GetMatchingModelsRevision(ctx)
+	if err != nil {
+		return err
+	}
+
+	stored, err := s.store.ListMatchingModels(ctx)
+	if err != nil {
+		return err
+	}
+
+	models := make([]matching.Model, 0, len(stored))
+	for _, model := range stored {
+		models = append(models, toModel(model))
+	}
+	if err := s.engine.ApplyModels(models); err != nil {
+		return fmt.Errorf("failed to apply matching models at revision %d: %w", revision.Revision, err)
+	}
+
+	s.loaded = &revision
+	s.logger.Info("Loaded matching models",
+		"revision", revision.Revision,
+		"models", revision.Count)
+
+	return nil
+}
+
+func toModel(model *database.MatchingModel) matching.Model {
+	return matching.Model{
+		EntityType:  model.EntityType,
+		Weights:     model.Weights,
+		Comparators: model.Comparators,
+		Threshold:   model.Threshold,
+	}
+}
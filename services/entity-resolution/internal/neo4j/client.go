@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/aegisshield/entity-resolution/internal/config"
@@ -43,6 +44,20 @@ type RelationshipEdge struct {
 	UpdatedAt       time.Time              `json:"updated_at"`
 }
 
+// LineageNode is a lineage audit event mirrored in the graph. Changes holds
+// the attribute changes as JSON since node properties cannot nest.
+type LineageNode struct {
+	ID              string    `json:"id"`
+	EntityID        string    `json:"entity_id"`
+	EventType       string    `json:"event_type"`
+	RelatedEntityID string    `json:"related_entity_id,omitempty"`
+	SourceID        string    `json:"source_id,omitempty"`
+	Actor           string    `json:"actor,omitempty"`
+	ReferenceID     string    `json:"reference_id,omitempty"`
+	Changes         string    `json:"changes"`
+	OccurredAt      time.Time `json:"occurred_at"`
+}
+
 // PathResult represents a path between entities
 type PathResult struct {
 	StartEntity   *EntityNode        `json:"start_entity"`
@@ -78,6 +93,7 @@ func NewClient(cfg config.Neo4jConfig, logger *slog.Logger) (*Client, error) {
 	defer cancel()
 
 	if err := client.VerifyConnectivity(ctx); err != nil {
+		driver.Close(context.Background())
 		return nil, fmt.Errorf("failed to verify Neo4j connectivity: %w", err)
 	}
 
@@ -544,6 +560,289 @@ func (c *Client) GetEntityMetrics(ctx context.Context, entityID string) (map[str
 	return result.(map[string]interface{}), nil
 }
 
+// MergeEntities applies an approved merge review to the graph. The
+// duplicate's relationships are moved onto the survivor and tagged with the
+// review so SplitEntities can move them back; the duplicate is kept, marked
+// merged and linked to the survivor by a MERGED_INTO relationship.
+func (c *Client) MergeEntities(ctx context.Context, reviewID string, survivor *EntityNode, duplicateID string) error {
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: c.config.Database,
+	})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (d:Entity {id: $duplicate_id})-[r]-(o:Entity)
+		WHERE o.id <> $survivor_id AND o.id <> $duplicate_id AND type(r) <> 'MERGED_INTO'
+		RETURN elementId(r), type(r), startNode(r) = d, o.id, properties(r)
+	`
+
+	moved, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		rels, err := collectRelationships(ctx, tx, query, map[string]interface{}{
+			"survivor_id":  survivor.ID,
+			"duplicate_id": duplicateID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rel := range rels {
+			rel.properties["merge_review_id"] = reviewID
+			rel.properties["moved_from"] = duplicateID
+			if err := moveRelationship(ctx, tx, rel, survivor.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := setEntityProperties(ctx, tx, survivor); err != nil {
+			return nil, err
+		}
+
+		result, err := tx.Run(ctx, `
+			MATCH (d:Entity {id: $duplicate_id})
+			MATCH (s:Entity {id: $survivor_id})
+			SET d.merged_into_id = $survivor_id, d.merged_at = $merged_at
+			CREATE (d)-[m:MERGED_INTO {merge_review_id: $review_id, created_at: $merged_at}]->(s)
+			RETURN m.merge_review_id
+		`, map[string]interface{}{
+			"survivor_id":  survivor.ID,
+			"duplicate_id": duplicateID,
+			"review_id":    reviewID,
+			"merged_at":    survivor.UpdatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !result.Next(ctx) {
+			return nil, fmt.Errorf("entity not found")
+		}
+
+		return len(rels), nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to merge entities in Neo4j: %w", err)
+	}
+
+	c.logger.Info("Entities merged in Neo4j",
+		"merge_review_id", reviewID,
+		"survivor_id", survivor.ID,
+		"duplicate_id", duplicateID,
+		"moved_relationships", moved)
+
+	return nil
+}
+
+// SplitEntities undoes MergeEntities for a review: relationships moved by
+// the merge go back to the duplicate, which is unmarked, and the survivor
+// takes the restored properties
+func (c *Client) SplitEntities(ctx context.Context, reviewID string, survivor *EntityNode, duplicateID string) error {
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: c.config.Database,
+	})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (s:Entity {id: $survivor_id})-[r]-(o:Entity)
+		WHERE r.merge_review_id = $review_id AND r.moved_from = $duplicate_id
+		RETURN elementId(r), type(r), startNode(r) = s, o.id, properties(r)
+	`
+
+	moved, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		rels, err := collectRelationships(ctx, tx, query, map[string]interface{}{
+			"survivor_id":  survivor.ID,
+			"duplicate_id": duplicateID,
+			"review_id":    reviewID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rel := range rels {
+			delete(rel.properties, "merge_review_id")
+			delete(rel.properties, "moved_from")
+			if err := moveRelationship(ctx, tx, rel, duplicateID); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := tx.Run(ctx, `
+			MATCH (d:Entity {id: $duplicate_id})
+			OPTIONAL MATCH (d)-[m:MERGED_INTO {merge_review_id: $review_id}]->()
+			DELETE m
+			REMOVE d.merged_into_id, d.merged_at
+		`, map[string]interface{}{
+			"duplicate_id": duplicateID,
+			"review_id":    reviewID,
+		}); err != nil {
+			return nil, err
+		}
+
+		if err := setEntityProperties(ctx, tx, survivor); err != nil {
+			return nil, err
+		}
+
+		return len(rels), nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to split entities in Neo4j: %w", err)
+	}
+
+	c.logger.Info("Entities split in Neo4j",
+		"merge_review_id", reviewID,
+		"survivor_id", survivor.ID,
+		"duplicate_id", duplicateID,
+		"moved_relationships", moved)
+
+	return nil
+}
+
+// RecordLineage mirrors a lineage event: a LineageEvent node linked from its
+// entity by HAS_LINEAGE, to the merged or split entity by INVOLVED and from
+// its source record by CONTRIBUTED. Recording the same event twice is a no-op.
+func (c *Client) RecordLineage(ctx context.Context, event *LineageNode) error {
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: c.config.Database,
+	})
+	defer session.Close(ctx)
+
+	parameters := map[string]interface{}{
+		"id":                event.ID,
+		"entity_id":         event.EntityID,
+		"event_type":        event.EventType,
+		"related_entity_id": event.RelatedEntityID,
+		"source_id":         event.SourceID,
+		"actor":             event.Actor,
+		"reference_id":      event.ReferenceID,
+		"changes":           event.Changes,
+		"occurred_at":       event.OccurredAt,
+	}
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		// Entities are merged rather than matched so lineage is kept for
+		// entities whose node was never written
+		if _, err := tx.Run(ctx, `
+			MERGE (e:Entity {id: $entity_id})
+			MERGE (ev:LineageEvent {id: $id})
+			SET ev.entity_id = $entity_id, ev.event_type = $event_type,
+				ev.source_id = $source_id, ev.actor = $actor,
+				ev.reference_id = $reference_id, ev.changes = $changes,
+				ev.occurred_at = $occurred_at
+			MERGE (e)-[:HAS_LINEAGE]->(ev)
+		`, parameters); err != nil {
+			return nil, err
+		}
+
+		if event.RelatedEntityID != "" {
+			if _, err := tx.Run(ctx, `
+				MATCH (ev:LineageEvent {id: $id})
+				MERGE (r:Entity {id: $related_entity_id})
+				MERGE (ev)-[i:INVOLVED]->(r)
+				SET i.event_type = $event_type
+			`, parameters); err != nil {
+				return nil, err
+			}
+		}
+
+		if event.SourceID != "" {
+			if _, err := tx.Run(ctx, `
+				MATCH (ev:LineageEvent {id: $id})
+				MERGE (s:SourceRecord {id: $source_id})
+				MERGE (s)-[:CONTRIBUTED]->(ev)
+			`, parameters); err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to record lineage in Neo4j: %w", err)
+	}
+
+	return nil
+}
+
+// movableRelationship is a relationship being moved from one entity to another
+type movableRelationship struct {
+	elementID  string
+	relType    string
+	outgoing   bool
+	otherID    string
+	properties map[string]interface{}
+}
+
+func collectRelationships(ctx context.Context, tx neo4j.ManagedTransaction, query string, parameters map[string]interface{}) ([]*movableRelationship, error) {
+	result, err := tx.Run(ctx, query, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	var rels []*movableRelationship
+	for result.Next(ctx) {
+		record := result.Record()
+		rel := &movableRelationship{
+			elementID:  record.Values[0].(string),
+			relType:    record.Values[1].(string),
+			outgoing:   record.Values[2].(bool),
+			otherID:    record.Values[3].(string),
+			properties: make(map[string]interface{}),
+		}
+		if properties, ok := record.Values[4].(map[string]interface{}); ok {
+			rel.properties = properties
+		}
+		rels = append(rels, rel)
+	}
+
+	return rels, result.Err()
+}
+
+// moveRelationship recreates a relationship on the given entity, keeping its
+// type, direction and other end, and deletes the original
+func moveRelationship(ctx context.Context, tx neo4j.ManagedTransaction, rel *movableRelationship, entityID string) error {
+	pattern := `(n)-[moved:` + quoteIdentifier(rel.relType) + `]->(o)`
+	if !rel.outgoing {
+		pattern = `(n)<-[moved:` + quoteIdentifier(rel.relType) + `]-(o)`
+	}
+
+	_, err := tx.Run(ctx, `
+		MATCH (n:Entity {id: $entity_id})
+		MATCH (o:Entity {id: $other_id})
+		MATCH ()-[r]-() WHERE elementId(r) = $element_id
+		CREATE `+pattern+`
+		SET moved = $properties
+		DELETE r
+	`, map[string]interface{}{
+		"entity_id":  entityID,
+		"other_id":   rel.otherID,
+		"element_id": rel.elementID,
+		"properties": rel.properties,
+	})
+	return err
+}
+
+func setEntityProperties(ctx context.Context, tx neo4j.ManagedTransaction, entity *EntityNode) error {
+	_, err := tx.Run(ctx, `
+		MATCH (e:Entity {id: $id})
+		SET e.identifiers = $identifiers,
+			e.attributes = $attributes,
+			e.updated_at = $updated_at
+	`, map[string]interface{}{
+		"id":          entity.ID,
+		"identifiers": entity.Identifiers,
+		"attributes":  entity.Attributes,
+		"updated_at":  entity.UpdatedAt,
+	})
+	return err
+}
+
+// quoteIdentifier escapes a relationship type read from the graph for use in
+// a query
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
 // Helper functions
 
 func (c *Client) nodeToEntity(node neo4j.Node) *EntityNode {
@@ -599,6 +898,8 @@ func (c *Client) createIndexes(ctx context.Context) error {
 		"CREATE INDEX entity_name_index IF NOT EXISTS FOR (e:Entity) ON (e.name)",
 		"CREATE INDEX entity_standardized_name_index IF NOT EXISTS FOR (e:Entity) ON (e.standardized_name)",
 		"CREATE INDEX entity_confidence_index IF NOT EXISTS FOR (e:Entity) ON (e.confidence_score)",
+		"CREATE CONSTRAINT lineage_event_id_unique IF NOT EXISTS FOR (l:LineageEvent) REQUIRE l.id IS UNIQUE",
+		"CREATE CONSTRAINT source_record_id_unique IF NOT EXISTS FOR (s:SourceRecord) REQUIRE s.id IS UNIQUE",
 	}
 
 	for _, query := range queries {
@@ -0,0 +1,213 @@
+package organization
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCycle is returned when a relationship would make an organization its own ancestor
+	ErrCycle = errors.New("relationship would create an ownership cycle")
+	// ErrValidation is returned for malformed aliases and relationships
+	ErrValidation = errors.New("invalid organization data")
+	// ErrEntityNotFound is returned when a referenced entity does not exist
+	ErrEntityNotFound = errors.New("entity not found")
+)
+
+// Family is the corporate family an entity belongs to: its ultimate parents
+// and every organization below them
+type Family struct {
+	EntityID        uuid.UUID                            `json:"entity_id"`
+	AsOf            time.Time                            `json:"as_of"`
+	UltimateParents []uuid.UUID                          `json:"ultimate_parents"`
+	Members         []*Member                            `json:"members"`
+	Relationships   []*database.OrganizationRelationship `json:"relationships"`
+}
+
+// Member is one organization in a corporate family. Depth is the shortest
+// distance from an ultimate parent. EffectiveOwnership is the percentage of
+// the member each ultimate parent holds through all ownership paths; paths
+// through links of unknown ownership do not contribute.
+type Member struct {
+	EntityID           uuid.UUID             `json:"entity_id"`
+	EntityType         string                `json:"entity_type,omitempty"`
+	Name               string                `json:"name,omitempty"`
+	Depth              int                   `json:"depth"`
+	ParentIDs          []uuid.UUID           `json:"parent_ids,omitempty"`
+	EffectiveOwnership map[uuid.UUID]float64 `json:"effective_ownership,omitempty"`
+}
+
+// ValidateAliasType checks an alias type is supported
+func ValidateAliasType(aliasType string) error {
+	switch aliasType {
+	case database.AliasTypeDBA, database.AliasTypeTradeName, database.AliasTypeFormerName,
+		database.AliasTypeAbbreviation, database.AliasTypeOther:
+		return nil
+	}
+	return fmt.Errorf("%w: alias type must be one of dba, trade_name, former_name, abbreviation or other", ErrValidation)
+}
+
+// ValidateRelationship checks a relationship is well formed. It does not
+// check for cycles, which needs the rest of the family.
+func ValidateRelationship(rel *database.OrganizationRelationship) error {
+	switch rel.RelationshipType {
+	case database.RelationshipSubsidiary, database.RelationshipBranch,
+		database.RelationshipDivision, database.RelationshipAffiliate:
+	default:
+		return fmt.Errorf("%w: relationship type must be one of subsidiary, branch, division or affiliate", ErrValidation)
+	}
+
+	if rel.ParentEntityID == uuid.Nil || rel.ChildEntityID == uuid.Nil {
+		return fmt.Errorf("%w: parent and child entity ids are required", ErrValidation)
+	}
+
+	if rel.ParentEntityID == rel.ChildEntityID {
+		return ErrCycle
+	}
+
+	if rel.OwnershipPercentage != nil && (*rel.OwnershipPercentage <= 0 || *rel.OwnershipPercentage > 100) {
+		return fmt.Errorf("%w: ownership percentage must be greater than 0 and at most 100", ErrValidation)
+	}
+
+	if rel.ValidFrom != nil && rel.ValidTo != nil && !rel.ValidTo.After(*rel.ValidFrom) {
+		return fmt.Errorf("%w: valid_to must be after valid_from", ErrValidation)
+	}
+
+	return nil
+}
+
+// ownershipFraction is the share of the child a parent holds through one link.
+// Branches and divisions are part of the parent, so they are wholly owned
+// unless stated otherwise.
+func ownershipFraction(rel *database.OrganizationRelationship) (float64, bool) {
+	if rel.OwnershipPercentage != nil {
+		return *rel.OwnershipPercentage / 100, true
+	}
+	if rel.RelationshipType == database.RelationshipBranch || rel.RelationshipType == database.RelationshipDivision {
+		return 1, true
+	}
+	return 0, false
+}
+
+// BuildFamily assembles the corporate family of an entity from the
+// relationships active in it
+func BuildFamily(entityID uuid.UUID, relationships []*database.OrganizationRelationship) (*Family, error) {
+	children := make(map[uuid.UUID][]*database.OrganizationRelationship)
+	parents := make(map[uuid.UUID][]uuid.UUID)
+	inDegree := map[uuid.UUID]int{entityID: 0}
+
+	for _, rel := range relationships {
+		children[rel.ParentEntityID] = append(children[rel.ParentEntityID], rel)
+		parents[rel.ChildEntityID] = append(parents[rel.ChildEntityID], rel.ParentEntityID)
+		if _, ok := inDegree[rel.ParentEntityID]; !ok {
+			inDegree[rel.ParentEntityID] = 0
+		}
+		inDegree[rel.ChildEntityID]++
+	}
+
+	var roots []uuid.UUID
+	for id, degree := range inDegree {
+		if degree == 0 {
+			roots = append(roots, id)
+		}
+	}
+	sortIDs(roots)
+
+	// Walk the family top down in topological order so every member is
+	// visited after all of its parents
+	order := make([]uuid.UUID, 0, len(inDegree))
+	queue := append([]uuid.UUID(nil), roots...)
+	remaining := make(map[uuid.UUID]int, len(inDegree))
+	for id, degree := range inDegree {
+		remaining[id] = degree
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		order = append(order, id)
+		for _, rel := range children[id] {
+			remaining[rel.ChildEntityID]--
+			if remaining[rel.ChildEntityID] == 0 {
+				queue = append(queue, rel.ChildEntityID)
+			}
+		}
+	}
+	if len(order) != len(inDegree) {
+		return nil, ErrCycle
+	}
+
+	members := make(map[uuid.UUID]*Member, len(order))
+	for _, id := range order {
+		member := &Member{
+			EntityID:           id,
+			ParentIDs:          parents[id],
+			EffectiveOwnership: make(map[uuid.UUID]float64),
+		}
+		sortIDs(member.ParentIDs)
+		members[id] = member
+	}
+	for _, root := range roots {
+		members[root].EffectiveOwnership[root] = 1
+	}
+
+	for _, id := range order {
+		parent := members[id]
+		for _, rel := range children[id] {
+			child := members[rel.ChildEntityID]
+			if len(child.ParentIDs) > 0 && (child.Depth == 0 || parent.Depth+1 < child.Depth) {
+				child.Depth = parent.Depth + 1
+			}
+
+			fraction, known := ownershipFraction(rel)
+			if !known {
+				continue
+			}
+			for root, share := range parent.EffectiveOwnership {
+				child.EffectiveOwnership[root] += share * fraction
+			}
+		}
+	}
+
+	family := &Family{
+		EntityID:        entityID,
+		UltimateParents: roots,
+		Members:         make([]*Member, 0, len(members)),
+		Relationships:   relationships,
+	}
+	for _, member := range members {
+		for root, share := range member.EffectiveOwnership {
+			member.EffectiveOwnership[root] = share * 100
+		}
+		family.Members = append(family.Members, member)
+	}
+
+	sort.Slice(family.Members, func(i, j int) bool {
+		a, b := family.Members[i], family.Members[j]
+		if a.Depth != b.Depth {
+			return a.Depth < b.Depth
+		}
+		return a.EntityID.String() < b.EntityID.String()
+	})
+
+	return family, nil
+}
+
+// MemberIDs returns the ids of every member of the family
+func (f *Family) MemberIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, len(f.Members))
+	for i, member := range f.Members {
+		ids[i] = member.EntityID
+	}
+	return ids
+}
+
+func sortIDs(ids []uuid.UUID) {
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+}
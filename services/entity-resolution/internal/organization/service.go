@@ -0,0 +1,227 @@
+package organization
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/standardization"
+	"github.com/google/uuid"
+)
+
+// AliasRequest represents a request to add an alias to an entity
+type AliasRequest struct {
+	Alias     string     `json:"alias" validate:"required,max=500"`
+	AliasType string     `json:"alias_type" validate:"omitempty,oneof=dba trade_name former_name abbreviation other"`
+	ValidFrom *time.Time `json:"valid_from,omitempty"`
+	ValidTo   *time.Time `json:"valid_to,omitempty"`
+	Source    string     `json:"source,omitempty"`
+	CreatedBy string     `json:"created_by,omitempty"`
+}
+
+// RelationshipRequest represents a request to link a parent and child organization
+type RelationshipRequest struct {
+	ParentEntityID      uuid.UUID  `json:"parent_entity_id" validate:"required"`
+	ChildEntityID       uuid.UUID  `json:"child_entity_id" validate:"required"`
+	RelationshipType    string     `json:"relationship_type" validate:"required,oneof=subsidiary branch division affiliate"`
+	OwnershipPercentage *float64   `json:"ownership_percentage,omitempty" validate:"omitempty,max=100"`
+	ValidFrom           *time.Time `json:"valid_from,omitempty"`
+	ValidTo             *time.Time `json:"valid_to,omitempty"`
+	Source              string     `json:"source,omitempty"`
+	CreatedBy           string     `json:"created_by,omitempty"`
+}
+
+// Service manages organization aliases and parent/subsidiary relationships
+type Service struct {
+	db           *database.Repository
+	standardizer *standardization.Engine
+	config       config.OrganizationConfig
+	logger       *slog.Logger
+}
+
+// NewService creates a new organization service
+func NewService(db *database.Repository, standardizer *standardization.Engine, config config.OrganizationConfig, logger *slog.Logger) *Service {
+	return &Service{
+		db:           db,
+		standardizer: standardizer,
+		config:       config,
+		logger:       logger,
+	}
+}
+
+// AddAlias records a DBA, trade, former or other name for an entity
+func (s *Service) AddAlias(ctx context.Context, entityID uuid.UUID, req *AliasRequest) (*database.EntityAlias, error) {
+	req.Alias = strings.TrimSpace(req.Alias)
+	if req.Alias == "" {
+		return nil, fmt.Errorf("%w: alias is required", ErrValidation)
+	}
+
+	if req.AliasType == "" {
+		req.AliasType = database.AliasTypeOther
+	}
+	if err := ValidateAliasType(req.AliasType); err != nil {
+		return nil, err
+	}
+
+	if req.ValidFrom != nil && req.ValidTo != nil && !req.ValidTo.After(*req.ValidFrom) {
+		return nil, fmt.Errorf("%w: valid_to must be after valid_from", ErrValidation)
+	}
+
+	if err := s.requireEntities(ctx, entityID); err != nil {
+		return nil, err
+	}
+
+	alias := &database.EntityAlias{
+		ID:                uuid.New(),
+		EntityID:          entityID,
+		Alias:             req.Alias,
+		StandardizedAlias: s.standardizer.StandardizeName(req.Alias).Standardized,
+		AliasType:         req.AliasType,
+		ValidFrom:         req.ValidFrom,
+		ValidTo:           req.ValidTo,
+		Source:            req.Source,
+		CreatedBy:         req.CreatedBy,
+		CreatedAt:         time.Now(),
+	}
+
+	if err := s.db.CreateEntityAlias(ctx, alias); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Entity alias added",
+		"entity_id", entityID,
+		"alias_type", alias.AliasType)
+
+	return alias, nil
+}
+
+// ListAliases returns an entity's aliases
+func (s *Service) ListAliases(ctx context.Context, entityID uuid.UUID) ([]*database.EntityAlias, error) {
+	aliases, err := s.db.ListEntityAliases(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if aliases == nil {
+		aliases = []*database.EntityAlias{}
+	}
+	return aliases, nil
+}
+
+// RemoveAlias removes one of an entity's aliases
+func (s *Service) RemoveAlias(ctx context.Context, entityID, aliasID uuid.UUID) error {
+	return s.db.DeleteEntityAlias(ctx, entityID, aliasID)
+}
+
+// AddRelationship links a parent and child organization, rejecting links
+// that would make an organization its own ancestor
+func (s *Service) AddRelationship(ctx context.Context, req *RelationshipRequest) (*database.OrganizationRelationship, error) {
+	now := time.Now()
+	rel := &database.OrganizationRelationship{
+		ID:                  uuid.New(),
+		ParentEntityID:      req.ParentEntityID,
+		ChildEntityID:       req.ChildEntityID,
+		RelationshipType:    req.RelationshipType,
+		OwnershipPercentage: req.OwnershipPercentage,
+		ValidFrom:           req.ValidFrom,
+		ValidTo:             req.ValidTo,
+		Source:              req.Source,
+		CreatedBy:           req.CreatedBy,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+
+	if err := ValidateRelationship(rel); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireEntities(ctx, rel.ParentEntityID, rel.ChildEntityID); err != nil {
+		return nil, err
+	}
+
+	asOf := now
+	if rel.ValidFrom != nil && rel.ValidFrom.After(now) {
+		asOf = *rel.ValidFrom
+	}
+	cycle, err := s.db.IsOrganizationAncestor(ctx, rel.ChildEntityID, rel.ParentEntityID, asOf, s.config.MaxFamilyDepth)
+	if err != nil {
+		return nil, err
+	}
+	if cycle {
+		return nil, ErrCycle
+	}
+
+	if err := s.db.CreateOrganizationRelationship(ctx, rel); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Organization relationship added",
+		"relationship_id", rel.ID,
+		"parent_entity_id", rel.ParentEntityID,
+		"child_entity_id", rel.ChildEntityID,
+		"relationship_type", rel.RelationshipType)
+
+	return rel, nil
+}
+
+// EndRelationship closes a relationship as of the given time, such as on a
+// divestment, so it still appears in historical family views
+func (s *Service) EndRelationship(ctx context.Context, id uuid.UUID, validTo time.Time) error {
+	return s.db.EndOrganizationRelationship(ctx, id, validTo)
+}
+
+// DeleteRelationship removes a relationship recorded in error
+func (s *Service) DeleteRelationship(ctx context.Context, id uuid.UUID) error {
+	return s.db.DeleteOrganizationRelationship(ctx, id)
+}
+
+// GetFamily returns the corporate family of an entity as of the given time
+func (s *Service) GetFamily(ctx context.Context, entityID uuid.UUID, asOf time.Time) (*Family, error) {
+	if err := s.requireEntities(ctx, entityID); err != nil {
+		return nil, err
+	}
+
+	relationships, err := s.db.GetCorporateFamilyRelationships(ctx, entityID, asOf, s.config.MaxFamilyDepth)
+	if err != nil {
+		return nil, err
+	}
+	if relationships == nil {
+		relationships = []*database.OrganizationRelationship{}
+	}
+
+	family, err := BuildFamily(entityID, relationships)
+	if err != nil {
+		return nil, err
+	}
+	family.AsOf = asOf
+
+	summaries, err := s.db.GetEntitySummaries(ctx, family.MemberIDs())
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range family.Members {
+		if summary, ok := summaries[member.EntityID]; ok {
+			member.EntityType = summary.EntityType
+			member.Name = summary.Name
+		}
+	}
+
+	return family, nil
+}
+
+// requireEntities checks every given entity exists
+func (s *Service) requireEntities(ctx context.Context, ids ...uuid.UUID) error {
+	summaries, err := s.db.GetEntitySummaries(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, ok := summaries[id]; !ok {
+			return fmt.Errorf("%w: %s", ErrEntityNotFound, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package rescore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/dedup"
+	"github.com/aegisshield/entity-resolution/internal/matching"
+	"github.com/google/uuid"
+)
+
+// ErrEntityRequired is returned for updates naming no entity
+var ErrEntityRequired = errors.New("entity ID is required")
+
+// Store applies attribute updates and re-scores the pending pairs they affect
+type Store interface {
+	GetEntity(ctx context.Context, id uuid.UUID) (*database.Entity, error)
+	ApplyAttributeUpdate(ctx context.Context, update *database.AttributeUpdate) (*database.Entity, []*database.AttributeChange, error)
+	ListMergeReviews(ctx context.Context, filter database.MergeReviewFilter) ([]*database.MergeReview, error)
+	ListPendingMergeCandidates(ctx context.Context, entityID uuid.UUID, limit int) ([]*database.MergeCandidate, error)
+	GetDedupRun(ctx context.Context, id uuid.UUID) (*database.DedupRun, error)
+	RescoreMergeReview(ctx context.Context, rescore *database.PairRescore) error
+	RescoreMergeCandidate(ctx context.Context, rescore *database.PairRescore) error
+}
+
+// Update is an upstream change to an entity's identifiers and attributes. A
+// nil value removes the key; an update without values re-scores the entity
+// as stored.
+type Update struct {
+	EventID     string
+	EntityID    uuid.UUID
+	Identifiers map[string]interface{}
+	Attributes  map[string]interface{}
+	SourceID    string
+	OccurredAt  time.Time
+}
+
+// Result summarizes the re-scoring triggered by an update
+type Result struct {
+	EntityID   uuid.UUID `json:"entity_id"` // the live entity, after following merges
+	Changes    int       `json:"changes"`
+	Rescored   int       `json:"rescored"`
+	Superseded int       `json:"superseded"`
+	Skipped    int       `json:"skipped"` // pairs decided while being re-scored
+	Truncated  bool      `json:"truncated"`
+}
+
+// Evidence is added to a re-scored pair's evidence under "rescore"
+type Evidence struct {
+	EventID                 string                   `json:"event_id,omitempty"`
+	SourceID                string                   `json:"source_id,omitempty"`
+	UpdatedEntityID         uuid.UUID                `json:"updated_entity_id"`
+	PreviousRawScore        float64                  `json:"previous_raw_score"`
+	PreviousCalibratedScore float64                  `json:"previous_calibrated_score"`
+	Threshold               float64                  `json:"threshold"`
+	Match                   *matching.MatchCandidate `json:"match,omitempty"` // nil when the pair no longer matches
+	RescoredAt              time.Time                `json:"rescored_at"`
+}
+
+// Service re-resolves entities incrementally when an upstream record
+// changes. Only the pending merge reviews and dedup candidates involving the
+// updated entity are re-scored, so a change never waits for a backlog run;
+// pairs falling below their review threshold are superseded. Merges already
+// applied are left to investigators, who can split them.
+type Service struct {
+	store           Store
+	scorer          dedup.Scorer
+	calibrator      dedup.Calibrator
+	reviewThreshold float64
+	config          config.RescoreConfig
+	logger          *slog.Logger
+}
+
+// NewService creates a new re-scoring service. Merge reviews are held to
+// reviewThreshold and dedup candidates to the threshold of their run.
+func NewService(store Store, scorer dedup.Scorer, calibrator dedup.Calibrator, reviewThreshold float64, config config.RescoreConfig, logger *slog.Logger) *Service {
+	return &Service{
+		store:           store,
+		scorer:          scorer,
+		calibrator:      calibrator,
+		reviewThreshold: reviewThreshold,
+		config:          config,
+		logger:          logger,
+	}
+}
+
+// Rescore applies an update and re-scores the pending pairs involving the
+// entity, up to the configured number of pairs
+func (s *Service) Rescore(ctx context.Context, update *Update) (*Result, error) {
+	if update.EntityID == uuid.Nil {
+		return nil, ErrEntityRequired
+	}
+
+	entity, changes, err := s.store.ApplyAttributeUpdate(ctx, &database.AttributeUpdate{
+		EntityID:    update.EntityID,
+		Identifiers: update.Identifiers,
+		Attributes:  update.Attributes,
+		SourceID:    update.SourceID,
+		ReferenceID: update.EventID,
+		OccurredAt:  update.OccurredAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{EntityID: entity.ID, Changes: len(changes)}
+
+	reviews, err := s.store.ListMergeReviews(ctx, database.MergeReviewFilter{
+		Status:   database.MergeReviewPending,
+		EntityID: &entity.ID,
+		Limit:    s.config.MaxPairs + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(reviews) > s.config.MaxPairs {
+		reviews = reviews[:s.config.MaxPairs]
+		result.Truncated = true
+	}
+
+	for _, review := range reviews {
+		rescore, err := s.scorePair(ctx, entity, review.SurvivorEntityID, review.DuplicateEntityID,
+			review.RawScore, review.CalibratedScore, review.Evidence, s.reviewThreshold, update)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rescore merge review %s: %w", review.ID, err)
+		}
+		rescore.ID = review.ID
+
+		err = s.store.RescoreMergeReview(ctx, rescore)
+		if errors.Is(err, database.ErrReviewNotPending) {
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.count(result, rescore)
+	}
+
+	remaining := s.config.MaxPairs - len(reviews)
+	candidates, err := s.store.ListPendingMergeCandidates(ctx, entity.ID, remaining+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) > remaining {
+		candidates = candidates[:remaining]
+		result.Truncated = true
+	}
+
+	thresholds := make(map[uuid.UUID]float64)
+	for _, candidate := range candidates {
+		threshold, ok := thresholds[candidate.RunID]
+		if !ok {
+			run, err := s.store.GetDedupRun(ctx, candidate.RunID)
+			if err != nil {
+				return nil, err
+			}
+			threshold = run.ReviewThreshold
+			thresholds[candidate.RunID] = threshold
+		}
+
+		rescore, err := s.scorePair(ctx, entity, candidate.SurvivorEntityID, candidate.DuplicateEntityID,
+			candidate.RawScore, candidate.CalibratedScore, candidate.Evidence, threshold, update)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rescore merge candidate %s: %w", candidate.ID, err)
+		}
+		rescore.ID = candidate.ID
+
+		err = s.store.RescoreMergeCandidate(ctx, rescore)
+		if errors.Is(err, database.ErrCandidateNotPending) {
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.count(result, rescore)
+	}
+
+	s.logResult(update, result)
+	return result, nil
+}
+
+// scorePair scores a pair involving the updated entity again, keeping the
+// survivor and duplicate sides of the original match
+func (s *Service) scorePair(ctx context.Context, updated *database.Entity, survivorID, duplicateID uuid.UUID, rawScore, calibratedScore float64, evidence json.RawMessage, threshold float64, update *Update) (*database.PairRescore, error) {
+	survivor, duplicate := updated, updated
+	var err error
+	if survivorID != updated.ID {
+		survivor, err = s.store.GetEntity(ctx, survivorID)
+	} else {
+		duplicate, err = s.store.GetEntity(ctx, duplicateID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.scorer.FindMatches(dedup.ToMatchInput(survivor), []matching.CandidateEntity{dedup.ToCandidate(duplicate)})
+	if err != nil {
+		return nil, err
+	}
+
+	// The matcher drops candidates below its similarity threshold, so a
+	// missing candidate no longer matches at all
+	var match *matching.MatchCandidate
+	for _, candidate := range result.Candidates {
+		if candidate.EntityID == duplicate.ID.String() {
+			match = candidate
+			break
+		}
+	}
+
+	rescore := &database.PairRescore{}
+	if match != nil {
+		rescore.RawScore = match.OverallScore
+		rescore.CalibratedScore = s.calibrator.Calibrate(match.OverallScore)
+	}
+	rescore.Supersede = rescore.CalibratedScore < threshold
+
+	rescore.Evidence, err = withRescoreEvidence(evidence, &Evidence{
+		EventID:                 update.EventID,
+		SourceID:                update.SourceID,
+		UpdatedEntityID:         updated.ID,
+		PreviousRawScore:        rawScore,
+		PreviousCalibratedScore: calibratedScore,
+		Threshold:               threshold,
+		Match:                   match,
+		RescoredAt:              time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rescore, nil
+}
+
+func (s *Service) count(result *Result, rescore *database.PairRescore) {
+	if rescore.Supersede {
+		result.Superseded++
+		return
+	}
+	result.Rescored++
+}
+
+func (s *Service) logResult(update *Update, result *Result) {
+	s.logger.Info("Entity re-scored after attribute update",
+		"event_id", update.EventID,
+		"entity_id", result.EntityID,
+		"changes", result.Changes,
+		"rescored", result.Rescored,
+		"superseded", result.Superseded,
+		"skipped", result.Skipped,
+		"truncated", result.Truncated)
+}
+
+// withRescoreEvidence adds the re-scoring to a pair's stored evidence,
+// replacing any earlier re-scoring. Evidence that is not a JSON object is
+// kept under "original".
+func withRescoreEvidence(evidence json.RawMessage, rescore *Evidence) (json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if len(evidence) > 0 {
+		if err := json.Unmarshal(evidence, &fields); err != nil {
+			fields = map[string]json.RawMessage{"original": evidence}
+		}
+	}
+	if fields == nil {
+		fields = make(map[string]json.RawMessage)
+	}
+
+	encoded, err := json.Marshal(rescore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rescore evidence: %w", err)
+	}
+	fields["rescore"] = encoded
+
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal evidence: %w", err)
+	}
+	return merged, nil
+}
@@ -2,6 +2,7 @@ package resolver
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -9,9 +10,11 @@ import (
 
 	"github.com/aegisshield/entity-resolution/internal/config"
 	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/lineage"
 	"github.com/aegisshield/entity-resolution/internal/matching"
 	"github.com/aegisshield/entity-resolution/internal/neo4j"
 	"github.com/aegisshield/entity-resolution/internal/standardization"
+	"github.com/aegisshield/entity-resolution/internal/survivorship"
 	"github.com/google/uuid"
 )
 
@@ -23,6 +26,40 @@ type EntityResolver struct {
 	standardizer   *standardization.Engine
 	config         config.Config
 	logger         *slog.Logger
+	calibrator     ScoreCalibrator
+	reviews        MergeReviewQueue
+	survivorship   AttributeSurvivorship
+	screener       EntityScreener
+	lineage        LineageRecorder
+}
+
+// ScoreCalibrator maps raw similarity scores to calibrated match probabilities
+type ScoreCalibrator interface {
+	Calibrate(score float64) float64
+}
+
+// MergeReviewQueue holds low-confidence merges for an investigator
+type MergeReviewQueue interface {
+	Queue(ctx context.Context, review *database.MergeReview) error
+	Threshold() float64
+	MaxCandidates() int
+}
+
+// AttributeSurvivorship decides which values survive in a golden record
+// when newly reported data merges into an entity
+type AttributeSurvivorship interface {
+	Merge(ctx context.Context, req *survivorship.MergeRequest) (*survivorship.MergeResult, error)
+}
+
+// EntityScreener screens resolved entities against sanctions and PEP lists
+type EntityScreener interface {
+	ScreenEntityByID(ctx context.Context, entityID uuid.UUID) ([]*database.ScreeningHit, error)
+}
+
+// LineageRecorder records source records resolved into entities in the
+// lineage audit trail
+type LineageRecorder interface {
+	RecordResolution(ctx context.Context, record *lineage.ResolutionRecord) error
 }
 
 // ResolutionRequest represents a request to resolve entities
@@ -42,12 +79,14 @@ type ResolutionResult struct {
 	ConfidenceScore float64                `json:"confidence_score"`
 	StandardizedData map[string]interface{} `json:"standardized_data"`
 	CreatedLinks    []string               `json:"created_links,omitempty"`
+	PendingReviewID string                 `json:"pending_review_id,omitempty"`
 }
 
 // MatchCandidate represents a potential entity match
 type MatchCandidate struct {
 	EntityID        string  `json:"entity_id"`
 	MatchScore      float64 `json:"match_score"`
+	RawScore        float64 `json:"raw_score,omitempty"`
 	MatchedFields   []string `json:"matched_fields"`
 	ConflictFields  []string `json:"conflict_fields,omitempty"`
 	RecommendMerge  bool    `json:"recommend_merge"`
@@ -84,6 +123,33 @@ func NewEntityResolver(
 	}
 }
 
+// SetCalibrator sets the calibrator applied to fuzzy similarity scores
+func (r *EntityResolver) SetCalibrator(calibrator ScoreCalibrator) {
+	r.calibrator = calibrator
+}
+
+// SetReviewQueue sets the queue low-confidence merges are held in
+func (r *EntityResolver) SetReviewQueue(queue MergeReviewQueue) {
+	r.reviews = queue
+}
+
+// SetSurvivorship sets the policy engine resolving attribute conflicts on
+// merge; without one, newly reported values overwrite existing ones
+func (r *EntityResolver) SetSurvivorship(merger AttributeSurvivorship) {
+	r.survivorship = merger
+}
+
+// SetScreener sets the screener resolved entities are checked with against
+// sanctions and PEP lists
+func (r *EntityResolver) SetScreener(screener EntityScreener) {
+	r.screener = screener
+}
+
+// SetLineage sets the recorder of the lineage audit trail
+func (r *EntityResolver) SetLineage(recorder LineageRecorder) {
+	r.lineage = recorder
+}
+
 // ResolveEntity resolves a single entity
 func (r *EntityResolver) ResolveEntity(ctx context.Context, request *ResolutionRequest) (*ResolutionResult, error) {
 	startTime := time.Now()
@@ -115,6 +181,20 @@ func (r *EntityResolver) ResolveEntity(ctx context.Context, request *ResolutionR
 		return nil, fmt.Errorf("failed to persist resolution: %w", err)
 	}
 
+	// Step 5: Hold a low-confidence match for an investigator
+	if err := r.queueReview(ctx, request, result); err != nil {
+		r.logger.Warn("Failed to queue merge for review",
+			"entity_id", result.EntityID,
+			"error", err)
+	}
+
+	// Step 6: Screen the resolved entity against sanctions and PEP lists
+	if err := r.screenEntity(ctx, result); err != nil {
+		r.logger.Warn("Failed to screen resolved entity",
+			"entity_id", result.EntityID,
+			"error", err)
+	}
+
 	r.logger.Info("Entity resolution completed",
 		"entity_id", result.EntityID,
 		"is_new_entity", result.IsNewEntity,
@@ -361,6 +441,14 @@ func (r *EntityResolver) findMatchCandidates(ctx context.Context, request *Resol
 		} else {
 			allCandidates = append(allCandidates, fuzzyMatches...)
 		}
+
+		// Find entities known by this name as a DBA, trade or former name
+		aliasMatches, err := r.findAliasMatches(ctx, request.EntityType, standardizedName)
+		if err != nil {
+			r.logger.Warn("Failed to find alias matches", "error", err)
+		} else {
+			allCandidates = append(allCandidates, aliasMatches...)
+		}
 	}
 
 	// Deduplicate and score candidates
@@ -422,7 +510,9 @@ func (r *EntityResolver) findExactMatches(ctx context.Context, request *Resoluti
 
 // findFuzzyMatches finds entities with fuzzy name matches
 func (r *EntityResolver) findFuzzyMatches(ctx context.Context, entityType, standardizedName string) ([]*MatchCandidate, error) {
-	entities, err := r.db.FindEntitiesByFuzzyName(ctx, entityType, standardizedName, r.config.EntityResolution.NameSimilarityThreshold)
+	// The name threshold is the tuned screening threshold when one is applied
+	nameThreshold := r.matcher.NameSettings().Threshold
+	entities, err := r.db.FindEntitiesByFuzzyName(ctx, entityType, standardizedName, nameThreshold)
 	if err != nil {
 		return nil, err
 	}
@@ -435,12 +525,15 @@ func (r *EntityResolver) findFuzzyMatches(ctx context.Context, entityType, stand
 			map[string]interface{}{"name": entity.StandardizedName},
 		)
 
-		if matchResult.OverallScore >= r.config.EntityResolution.NameSimilarityThreshold {
+		if matchResult.OverallScore >= nameThreshold {
+			// Merge decisions use the calibrated probability so thresholds mean precision
+			score := r.calibrateScore(matchResult.OverallScore)
 			candidate := &MatchCandidate{
 				EntityID:       entity.ID,
-				MatchScore:     matchResult.OverallScore,
+				MatchScore:     score,
+				RawScore:       matchResult.OverallScore,
 				MatchedFields:  []string{"name"},
-				RecommendMerge: matchResult.OverallScore >= r.config.EntityResolution.AutoMergeThreshold,
+				RecommendMerge: score >= r.config.EntityResolution.AutoMergeThreshold,
 			}
 			candidates = append(candidates, candidate)
 		}
@@ -449,6 +542,30 @@ func (r *EntityResolver) findFuzzyMatches(ctx context.Context, entityType, stand
 	return candidates, nil
 }
 
+// findAliasMatches finds entities with an alias similar to the name
+func (r *EntityResolver) findAliasMatches(ctx context.Context, entityType, standardizedName string) ([]*MatchCandidate, error) {
+	matches, err := r.db.FindEntitiesByAlias(ctx, entityType, standardizedName,
+		r.config.Organization.AliasSimilarityThreshold, r.config.Organization.MaxAliasMatches)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*MatchCandidate
+	for _, match := range matches {
+		score := r.calibrateScore(match.Similarity)
+		candidate := &MatchCandidate{
+			EntityID:       match.EntityID.String(),
+			MatchScore:     score,
+			RawScore:       match.Similarity,
+			MatchedFields:  []string{"alias"},
+			RecommendMerge: score >= r.config.EntityResolution.AutoMergeThreshold,
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, nil
+}
+
 // evaluateMatches evaluates match candidates and determines resolution
 func (r *EntityResolver) evaluateMatches(ctx context.Context, request *ResolutionRequest, standardizedData map[string]interface{}, candidates []*MatchCandidate) (*ResolutionResult, error) {
 	result := &ResolutionResult{
@@ -491,25 +608,43 @@ func (r *EntityResolver) persistResolution(ctx context.Context, request *Resolut
 			EntityType:       request.EntityType,
 			Name:             request.Name,
 			StandardizedName: getStringFromMap(result.StandardizedData, "name"),
-			Identifiers:      request.Identifiers,
-			Attributes:       request.Attributes,
 			ConfidenceScore:  result.ConfidenceScore,
 			CreatedAt:        now,
 			UpdatedAt:        now,
 		}
+		fields := database.EntityFields{
+			Identifiers: request.Identifiers,
+			Attributes:  request.Attributes,
+		}
+		if err := setEntityFields(entity, fields); err != nil {
+			return err
+		}
 
 		if err := r.db.CreateEntity(ctx, entity); err != nil {
 			return fmt.Errorf("failed to create entity: %w", err)
 		}
 
+		// Record the first observations so later merges can weigh them
+		if r.survivorship != nil {
+			if _, err := r.survivorship.Merge(ctx, &survivorship.MergeRequest{
+				EntityID:   entity.ID,
+				EntityType: entity.EntityType,
+				Incoming:   fields,
+				SourceID:   request.SourceID,
+				ObservedAt: now,
+			}); err != nil {
+				r.logger.Warn("Failed to record attribute provenance", "entity_id", entity.ID, "error", err)
+			}
+		}
+
 		// Create Neo4j node
 		neo4jEntity := &neo4j.EntityNode{
 			ID:               entity.ID,
 			EntityType:       entity.EntityType,
 			Name:             entity.Name,
 			StandardizedName: entity.StandardizedName,
-			Identifiers:      entity.Identifiers,
-			Attributes:       entity.Attributes,
+			Identifiers:      fields.Identifiers,
+			Attributes:       fields.Attributes,
 			ConfidenceScore:  entity.ConfidenceScore,
 			CreatedAt:        entity.CreatedAt,
 			UpdatedAt:        entity.UpdatedAt,
@@ -518,19 +653,50 @@ func (r *EntityResolver) persistResolution(ctx context.Context, request *Resolut
 		if err := r.neo4jClient.CreateEntity(ctx, neo4jEntity); err != nil {
 			r.logger.Warn("Failed to create Neo4j entity", "error", err)
 		}
+
+		r.recordLineage(ctx, result.EntityID, true, request.SourceID, database.EntityFields{}, fields)
 	} else {
 		// Update existing entity with new data
 		entity, err := r.db.GetEntity(ctx, result.EntityID)
 		if err != nil {
 			return fmt.Errorf("failed to get existing entity: %w", err)
 		}
+		current, err := entityFields(entity)
+		if err != nil {
+			return err
+		}
 
-		// Merge data
-		mergedIdentifiers := mergeMap(entity.Identifiers, request.Identifiers)
-		mergedAttributes := mergeMap(entity.Attributes, request.Attributes)
+		before := database.EntityFields{
+			Identifiers: copyMap(current.Identifiers),
+			Attributes:  copyMap(current.Attributes),
+		}
 
-		entity.Identifiers = mergedIdentifiers
-		entity.Attributes = mergedAttributes
+		// Merge data, resolving conflicting values by survivorship policy
+		var merged database.EntityFields
+		if r.survivorship != nil {
+			resolved, err := r.survivorship.Merge(ctx, &survivorship.MergeRequest{
+				EntityID:         entity.ID,
+				EntityType:       entity.EntityType,
+				Current:          current,
+				CurrentUpdatedAt: entity.UpdatedAt,
+				Incoming: database.EntityFields{
+					Identifiers: request.Identifiers,
+					Attributes:  request.Attributes,
+				},
+				SourceID:   request.SourceID,
+				ObservedAt: now,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to apply survivorship: %w", err)
+			}
+			merged = resolved.Fields
+		} else {
+			merged.Identifiers = mergeMap(current.Identifiers, request.Identifiers)
+			merged.Attributes = mergeMap(current.Attributes, request.Attributes)
+		}
+		if err := setEntityFields(entity, merged); err != nil {
+			return err
+		}
 		entity.UpdatedAt = now
 
 		if err := r.db.UpdateEntity(ctx, entity); err != nil {
@@ -543,8 +709,8 @@ func (r *EntityResolver) persistResolution(ctx context.Context, request *Resolut
 			EntityType:       entity.EntityType,
 			Name:             entity.Name,
 			StandardizedName: entity.StandardizedName,
-			Identifiers:      entity.Identifiers,
-			Attributes:       entity.Attributes,
+			Identifiers:      merged.Identifiers,
+			Attributes:       merged.Attributes,
 			ConfidenceScore:  entity.ConfidenceScore,
 			CreatedAt:        entity.CreatedAt,
 			UpdatedAt:        entity.UpdatedAt,
@@ -553,11 +719,124 @@ func (r *EntityResolver) persistResolution(ctx context.Context, request *Resolut
 		if err := r.neo4jClient.UpdateEntity(ctx, neo4jEntity); err != nil {
 			r.logger.Warn("Failed to update Neo4j entity", "error", err)
 		}
+
+		r.recordLineage(ctx, result.EntityID, false, request.SourceID, before, merged)
+	}
+
+	return nil
+}
+
+// queueReview queues a merge of a new entity into its best match when the
+// match scored too low to merge automatically but high enough to be worth
+// an investigator's decision
+func (r *EntityResolver) queueReview(ctx context.Context, request *ResolutionRequest, result *ResolutionResult) error {
+	if r.reviews == nil || !result.IsNewEntity || len(result.MatchedEntities) == 0 {
+		return nil
+	}
+
+	best := result.MatchedEntities[0]
+	if best.MatchScore < r.reviews.Threshold() || best.MatchScore >= r.config.EntityResolution.AutoMergeThreshold {
+		return nil
+	}
+
+	survivorID, err := uuid.Parse(best.EntityID)
+	if err != nil {
+		return fmt.Errorf("invalid matched entity ID: %w", err)
+	}
+	duplicateID, err := uuid.Parse(result.EntityID)
+	if err != nil {
+		return fmt.Errorf("invalid entity ID: %w", err)
+	}
+
+	candidates := result.MatchedEntities
+	if len(candidates) > r.reviews.MaxCandidates() {
+		candidates = candidates[:r.reviews.MaxCandidates()]
+	}
+
+	evidence, err := json.Marshal(&reviewEvidence{
+		Request:            request,
+		StandardizedData:   result.StandardizedData,
+		Match:              best,
+		Candidates:         candidates,
+		AutoMergeThreshold: r.config.EntityResolution.AutoMergeThreshold,
+		ReviewThreshold:    r.reviews.Threshold(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal review evidence: %w", err)
+	}
+
+	rawScore := best.RawScore
+	if rawScore == 0 {
+		rawScore = best.MatchScore
+	}
+
+	review := &database.MergeReview{
+		ID:                uuid.New(),
+		SurvivorEntityID:  survivorID,
+		DuplicateEntityID: duplicateID,
+		EntityType:        request.EntityType,
+		RawScore:          rawScore,
+		CalibratedScore:   best.MatchScore,
+		Evidence:          evidence,
+	}
+	if err := r.reviews.Queue(ctx, review); err != nil {
+		return err
 	}
 
+	result.PendingReviewID = review.ID.String()
 	return nil
 }
 
+// recordLineage records a resolution in the lineage audit trail. The entity
+// is already persisted, so failures are logged rather than returned.
+func (r *EntityResolver) recordLineage(ctx context.Context, entityID string, created bool, sourceID string, before, after database.EntityFields) {
+	if r.lineage == nil {
+		return
+	}
+
+	id, err := uuid.Parse(entityID)
+	if err == nil {
+		err = r.lineage.RecordResolution(ctx, &lineage.ResolutionRecord{
+			EntityID: id,
+			Created:  created,
+			SourceID: sourceID,
+			Before:   before,
+			After:    after,
+		})
+	}
+	if err != nil {
+		r.logger.Warn("Failed to record entity lineage",
+			"entity_id", entityID,
+			"error", err)
+	}
+}
+
+// screenEntity screens the entity as stored after resolution, so a merged
+// entity is screened with its golden-record name and attributes
+func (r *EntityResolver) screenEntity(ctx context.Context, result *ResolutionResult) error {
+	if r.screener == nil {
+		return nil
+	}
+
+	entityID, err := uuid.Parse(result.EntityID)
+	if err != nil {
+		return fmt.Errorf("invalid entity ID: %w", err)
+	}
+
+	_, err = r.screener.ScreenEntityByID(ctx, entityID)
+	return err
+}
+
+// reviewEvidence is everything an investigator sees when deciding a merge
+type reviewEvidence struct {
+	Request            *ResolutionRequest     `json:"request"`
+	StandardizedData   map[string]interface{} `json:"standardized_data"`
+	Match              *MatchCandidate        `json:"match"`
+	Candidates         []*MatchCandidate      `json:"candidates"`
+	AutoMergeThreshold float64                `json:"auto_merge_threshold"`
+	ReviewThreshold    float64                `json:"review_threshold"`
+}
+
 // processBatch processes a batch of resolution requests
 func (r *EntityResolver) processBatch(ctx context.Context, requests []*ResolutionRequest) ([]*ResolutionResult, []error) {
 	var results []*ResolutionResult
@@ -575,6 +854,14 @@ func (r *EntityResolver) processBatch(ctx context.Context, requests []*Resolutio
 	return results, errors
 }
 
+// calibrateScore applies the configured calibrator to a raw similarity score
+func (r *EntityResolver) calibrateScore(score float64) float64 {
+	if r.calibrator == nil {
+		return score
+	}
+	return r.calibrator.Calibrate(score)
+}
+
 // Helper functions
 
 func getStringFromMap(m map[string]interface{}, key string) string {
@@ -586,6 +873,47 @@ func getStringFromMap(m map[string]interface{}, key string) string {
 	return ""
 }
 
+// entityFields decodes the identifiers and attributes stored on an entity
+func entityFields(entity *database.Entity) (database.EntityFields, error) {
+	var fields database.EntityFields
+	if len(entity.Identifiers) > 0 {
+		if err := json.Unmarshal(entity.Identifiers, &fields.Identifiers); err != nil {
+			return fields, fmt.Errorf("failed to decode entity identifiers: %w", err)
+		}
+	}
+	if len(entity.Attributes) > 0 {
+		if err := json.Unmarshal(entity.Attributes, &fields.Attributes); err != nil {
+			return fields, fmt.Errorf("failed to decode entity attributes: %w", err)
+		}
+	}
+	return fields, nil
+}
+
+// setEntityFields encodes identifiers and attributes onto an entity for storage
+func setEntityFields(entity *database.Entity, fields database.EntityFields) error {
+	identifiers, err := json.Marshal(copyMap(fields.Identifiers))
+	if err != nil {
+		return fmt.Errorf("failed to encode entity identifiers: %w", err)
+	}
+	attributes, err := json.Marshal(copyMap(fields.Attributes))
+	if err != nil {
+		return fmt.Errorf("failed to encode entity attributes: %w", err)
+	}
+	entity.Identifiers = identifiers
+	entity.Attributes = attributes
+	return nil
+}
+
+// copyMap returns a shallow copy, so a map can be compared after mergeMap
+// has updated it in place
+func copyMap(values map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
+
 func mergeMap(existing, new map[string]interface{}) map[string]interface{} {
 	if existing == nil {
 		existing = make(map[string]interface{})
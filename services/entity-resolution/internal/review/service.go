@@ -0,0 +1,244 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aegisshield/entity-resolution/internal/calibration"
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/neo4j"
+	"github.com/google/uuid"
+)
+
+// Investigator decisions on queued merges
+const (
+	DecisionApprove = "approve"
+	DecisionReject  = "reject"
+)
+
+var (
+	// ErrInvalidDecision is returned for decisions other than approve or reject
+	ErrInvalidDecision = fmt.Errorf("decision must be %s or %s", DecisionApprove, DecisionReject)
+	// ErrReviewerRequired is returned when a decision or split names no investigator
+	ErrReviewerRequired = errors.New("reviewer is required")
+)
+
+// DecisionRequest represents an investigator's decision on a queued merge
+type DecisionRequest struct {
+	Decision   string `json:"decision"`
+	ReviewerID string `json:"reviewer_id"`
+	Notes      string `json:"notes,omitempty"`
+}
+
+// SplitRequest undoes an approved merge
+type SplitRequest struct {
+	SplitBy string `json:"split_by"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Store persists merge reviews and applies approved merges and splits
+type Store interface {
+	CreateMergeReview(ctx context.Context, review *database.MergeReview) error
+	GetMergeReview(ctx context.Context, id uuid.UUID) (*database.MergeReview, error)
+	ListMergeReviews(ctx context.Context, filter database.MergeReviewFilter) ([]*database.MergeReview, error)
+	ApproveMergeReview(ctx context.Context, id uuid.UUID, reviewerID, notes string) (*database.MergeReview, *database.Entity, error)
+	RejectMergeReview(ctx context.Context, id uuid.UUID, reviewerID, notes string) (*database.MergeReview, error)
+	SplitMergeReview(ctx context.Context, id uuid.UUID, splitBy, reason string) (*database.MergeReview, *database.Entity, error)
+}
+
+// Graph mirrors approved merges and splits in the entity graph
+type Graph interface {
+	MergeEntities(ctx context.Context, reviewID string, survivor *neo4j.EntityNode, duplicateID string) error
+	SplitEntities(ctx context.Context, reviewID string, survivor *neo4j.EntityNode, duplicateID string) error
+}
+
+// Service holds low-confidence merges from live resolution for investigators.
+// Approved merges are applied to the database and the graph and can be split
+// again, restoring both entities.
+type Service struct {
+	store       Store
+	graph       Graph
+	calibration *calibration.Service
+	config      config.ReviewConfig
+	logger      *slog.Logger
+}
+
+// NewService creates a new merge review service
+func NewService(store Store, graph Graph, calibrationService *calibration.Service, config config.ReviewConfig, logger *slog.Logger) *Service {
+	return &Service{
+		store:       store,
+		graph:       graph,
+		calibration: calibrationService,
+		config:      config,
+		logger:      logger,
+	}
+}
+
+// Threshold returns the calibrated score at or above which matches below
+// the auto-merge threshold are queued
+func (s *Service) Threshold() float64 {
+	return s.config.Threshold
+}
+
+// MaxCandidates returns how many match candidates are kept as evidence
+func (s *Service) MaxCandidates() int {
+	return s.config.MaxCandidates
+}
+
+// Queue holds a merge for an investigator
+func (s *Service) Queue(ctx context.Context, review *database.MergeReview) error {
+	now := time.Now()
+	if review.ID == uuid.Nil {
+		review.ID = uuid.New()
+	}
+	review.Status = database.MergeReviewPending
+	review.CreatedAt = now
+	review.UpdatedAt = now
+
+	if err := s.store.CreateMergeReview(ctx, review); err != nil {
+		return err
+	}
+
+	s.logger.Info("Merge queued for review",
+		"review_id", review.ID,
+		"survivor_entity_id", review.SurvivorEntityID,
+		"duplicate_entity_id", review.DuplicateEntityID,
+		"calibrated_score", review.CalibratedScore)
+
+	return nil
+}
+
+// Get returns a merge review with its evidence
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*database.MergeReview, error) {
+	return s.store.GetMergeReview(ctx, id)
+}
+
+// List returns merge reviews, best scoring first
+func (s *Service) List(ctx context.Context, filter database.MergeReviewFilter) ([]*database.MergeReview, error) {
+	return s.store.ListMergeReviews(ctx, filter)
+}
+
+// Decide approves or rejects a queued merge. Approved merges are applied to
+// the graph, and either outcome feeds match confidence calibration.
+func (s *Service) Decide(ctx context.Context, id uuid.UUID, req *DecisionRequest) (*database.MergeReview, error) {
+	if req.Decision != DecisionApprove && req.Decision != DecisionReject {
+		return nil, ErrInvalidDecision
+	}
+	if req.ReviewerID == "" {
+		return nil, ErrReviewerRequired
+	}
+
+	var review *database.MergeReview
+	var survivor *database.Entity
+	var err error
+	outcome := calibration.OutcomeAccepted
+	if req.Decision == DecisionApprove {
+		review, survivor, err = s.store.ApproveMergeReview(ctx, id, req.ReviewerID, req.Notes)
+	} else {
+		outcome = calibration.OutcomeRejected
+		review, err = s.store.RejectMergeReview(ctx, id, req.ReviewerID, req.Notes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if survivor != nil {
+		s.syncGraph(ctx, review, survivor)
+	}
+
+	if s.calibration != nil {
+		if _, err := s.calibration.RecordReview(ctx, &calibration.ReviewRequest{
+			SourceEntityID:    review.DuplicateEntityID.String(),
+			CandidateEntityID: review.SurvivorEntityID.String(),
+			RawScore:          review.RawScore,
+			Outcome:           outcome,
+			ReviewerID:        req.ReviewerID,
+			Notes:             req.Notes,
+		}); err != nil {
+			s.logger.Warn("Failed to record merge review for calibration",
+				"review_id", id,
+				"error", err)
+		}
+	}
+
+	s.logger.Info("Merge review decided",
+		"review_id", id,
+		"decision", req.Decision,
+		"reviewer_id", req.ReviewerID)
+
+	return review, nil
+}
+
+// Split undoes an approved merge in the database and the graph
+func (s *Service) Split(ctx context.Context, id uuid.UUID, req *SplitRequest) (*database.MergeReview, error) {
+	if req.SplitBy == "" {
+		return nil, ErrReviewerRequired
+	}
+
+	review, survivor, err := s.store.SplitMergeReview(ctx, id, req.SplitBy, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	s.syncGraph(ctx, review, survivor)
+
+	s.logger.Info("Merge split",
+		"review_id", id,
+		"survivor_entity_id", review.SurvivorEntityID,
+		"duplicate_entity_id", review.DuplicateEntityID,
+		"split_by", req.SplitBy)
+
+	return review, nil
+}
+
+// syncGraph mirrors a committed merge or split in the graph. The database
+// stays the record of the decision, so graph failures are logged rather than
+// undoing it.
+func (s *Service) syncGraph(ctx context.Context, review *database.MergeReview, survivor *database.Entity) {
+	if s.graph == nil {
+		return
+	}
+
+	node, err := entityNode(survivor)
+	if err == nil {
+		if review.Status == database.MergeReviewSplit {
+			err = s.graph.SplitEntities(ctx, review.ID.String(), node, review.DuplicateEntityID.String())
+		} else {
+			err = s.graph.MergeEntities(ctx, review.ID.String(), node, review.DuplicateEntityID.String())
+		}
+	}
+	if err != nil {
+		s.logger.Error("Failed to apply merge review to graph",
+			"review_id", review.ID,
+			"status", review.Status,
+			"error", err)
+	}
+}
+
+func entityNode(entity *database.Entity) (*neo4j.EntityNode, error) {
+	node := &neo4j.EntityNode{
+		ID:               entity.ID.String(),
+		EntityType:       entity.EntityType,
+		Name:             entity.Name,
+		StandardizedName: entity.StandardizedName,
+		ConfidenceScore:  entity.ConfidenceScore,
+		CreatedAt:        entity.CreatedAt,
+		UpdatedAt:        entity.UpdatedAt,
+	}
+
+	if len(entity.Identifiers) > 0 {
+		if err := json.Unmarshal(entity.Identifiers, &node.Identifiers); err != nil {
+			return nil, fmt.Errorf("failed to decode entity identifiers: %w", err)
+		}
+	}
+	if len(entity.Attributes) > 0 {
+		if err := json.Unmarshal(entity.Attributes, &node.Attributes); err != nil {
+			return nil, fmt.Errorf("failed to decode entity attributes: %w", err)
+		}
+	}
+
+	return node, nil
+}
@@ -0,0 +1,285 @@
+package screening
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// List kinds
+const (
+	KindSanctions = "sanctions"
+	KindPEP       = "pep"
+)
+
+// List formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// List entry types
+const (
+	EntryIndividual = "individual"
+	EntryEntity     = "entity"
+	EntryVessel     = "vessel"
+	EntryAircraft   = "aircraft"
+)
+
+// ErrInvalidList is returned for list files that cannot be parsed
+var ErrInvalidList = errors.New("invalid screening list")
+
+// Entry is one designated person or organization on a list. OFAC, EU, UN
+// and PEP sources are converted to this shape before loading.
+type Entry struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Type         string   `json:"type,omitempty"`
+	Aliases      []string `json:"aliases,omitempty"`
+	Programs     []string `json:"programs,omitempty"`
+	Countries    []string `json:"countries,omitempty"`
+	DatesOfBirth []string `json:"dates_of_birth,omitempty"`
+	Remarks      string   `json:"remarks,omitempty"`
+}
+
+// Names returns the entry's name followed by its aliases
+func (e *Entry) Names() []string {
+	return append([]string{e.Name}, e.Aliases...)
+}
+
+// List is a loaded sanctions or PEP list
+type List struct {
+	Name     string
+	Kind     string
+	Source   string // file path, URL or "upload"
+	Format   string
+	Checksum string
+	Entries  []*Entry
+	LoadedAt time.Time
+}
+
+// csvColumns are the columns of a list in CSV form; multiple values in one
+// column are separated by semicolons
+var csvColumns = []string{"id", "name", "type", "aliases", "programs", "countries", "dates_of_birth", "remarks"}
+
+// FormatOf returns the format of a list file by its extension, CSV unless
+// it ends in .json
+func FormatOf(location string) string {
+	path := location
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatCSV
+}
+
+// Checksum returns the hex SHA-256 of a list file
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseList parses a list file. JSON lists are an array of entries or an
+// object with an "entries" array; CSV lists have a header row naming their
+// columns, of which id and name are required.
+func ParseList(format string, data []byte, maxEntries int) ([]*Entry, error) {
+	var entries []*Entry
+	switch format {
+	case FormatJSON:
+		var err error
+		if entries, err = parseJSON(data); err != nil {
+			return nil, err
+		}
+	case FormatCSV:
+		var err error
+		if entries, err = parseCSV(data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidList, format)
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: no entries", ErrInvalidList)
+	}
+	if maxEntries > 0 && len(entries) > maxEntries {
+		return nil, fmt.Errorf("%w: %d entries exceeds the limit of %d", ErrInvalidList, len(entries), maxEntries)
+	}
+
+	seen := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		if err := normalizeEntry(entry); err != nil {
+			return nil, fmt.Errorf("%w: entry %d: %v", ErrInvalidList, i+1, err)
+		}
+		if seen[entry.ID] {
+			return nil, fmt.Errorf("%w: duplicate entry id %q", ErrInvalidList, entry.ID)
+		}
+		seen[entry.ID] = true
+	}
+
+	return entries, nil
+}
+
+func parseJSON(data []byte) ([]*Entry, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var entries []*Entry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidList, err)
+		}
+		return entries, nil
+	}
+
+	var document struct {
+		Entries []*Entry `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidList, err)
+	}
+	return document.Entries, nil
+}
+
+func parseCSV(data []byte) ([]*Entry, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidList)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))] = i
+	}
+	for _, required := range []string{"id", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing %s column; expected columns %s", ErrInvalidList, required, strings.Join(csvColumns, ","))
+		}
+	}
+
+	var entries []*Entry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidList, err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		entries = append(entries, &Entry{
+			ID:           field("id"),
+			Name:         field("name"),
+			Type:         field("type"),
+			Aliases:      splitValues(field("aliases")),
+			Programs:     splitValues(field("programs")),
+			Countries:    splitValues(field("countries")),
+			DatesOfBirth: splitValues(field("dates_of_birth")),
+			Remarks:      field("remarks"),
+		})
+	}
+
+	return entries, nil
+}
+
+func splitValues(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ";") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+// normalizeEntry trims an entry and checks its id, name and type
+func normalizeEntry(entry *Entry) error {
+	if entry == nil {
+		return errors.New("empty entry")
+	}
+	entry.ID = strings.TrimSpace(entry.ID)
+	entry.Name = strings.TrimSpace(entry.Name)
+	if entry.ID == "" {
+		return errors.New("id is required")
+	}
+	if entry.Name == "" {
+		return fmt.Errorf("entry %q: name is required", entry.ID)
+	}
+
+	entry.Type = strings.ToLower(strings.TrimSpace(entry.Type))
+	switch entry.Type {
+	case "", EntryIndividual, EntryEntity, EntryVessel, EntryAircraft:
+	default:
+		return fmt.Errorf("entry %q: unknown type %q", entry.ID, entry.Type)
+	}
+
+	for i, country := range entry.Countries {
+		entry.Countries[i] = strings.ToUpper(strings.TrimSpace(country))
+	}
+	return nil
+}
+
+// Fetch reads a list file from a local path, a file:// URL or an http(s)
+// URL. The API key, if any, is sent as a bearer token to http(s) sources.
+func Fetch(ctx context.Context, client *http.Client, location, apiKey string, maxBytes int64) ([]byte, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create list request: %w", err)
+		}
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch list: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch list: status %d", resp.StatusCode)
+		}
+		return readLimited(resp.Body, maxBytes)
+	}
+
+	file, err := os.Open(strings.TrimPrefix(location, "file://"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open list: %w", err)
+	}
+	defer file.Close()
+
+	return readLimited(file, maxBytes)
+}
+
+func readLimited(r io.Reader, maxBytes int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read list: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalidList, maxBytes)
+	}
+	return data, nil
+}
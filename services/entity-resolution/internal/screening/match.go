@@ -0,0 +1,280 @@
+package screening
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/aegisshield/entity-resolution/internal/matching"
+)
+
+// Adjustments applied to the name score when both sides carry the field
+const (
+	dateOfBirthMatchBoost    = 0.05
+	dateOfBirthMismatchCost  = 0.15
+	countryMatchBoost        = 0.03
+	maxCandidatesPerBlockKey = 5000
+)
+
+// Subject is a resolved entity to be screened
+type Subject struct {
+	EntityID    uuid.UUID
+	EntityType  string
+	Name        string
+	Aliases     []string
+	DateOfBirth string
+	Countries   []string
+}
+
+// Names returns the subject's name followed by its aliases
+func (s *Subject) Names() []string {
+	return append([]string{s.Name}, s.Aliases...)
+}
+
+// Scorer compares two names. The matching engine satisfies it, so screening
+// uses the same name algorithms as entity matching.
+type Scorer interface {
+	NameScores(name1, name2 string) matching.NameScores
+	NameSettings() matching.NameSettings
+}
+
+// Match is a list entry scoring at or above the screening threshold
+type Match struct {
+	List        *List
+	Entry       *Entry
+	SubjectName string
+	MatchedName string
+	NameScore   float64
+	Score       float64
+	// DateOfBirthMatch is nil when either side has no date of birth
+	DateOfBirthMatch *bool
+	CountryMatch     bool
+}
+
+type indexedEntry struct {
+	list  *List
+	entry *Entry
+}
+
+// Index blocks list entries on the consonant skeletons of their name tokens
+// so a subject is only scored against entries sharing at least one token
+type Index struct {
+	entries []indexedEntry
+	blocks  map[string][]int
+}
+
+// NewIndex indexes the entries of the given lists
+func NewIndex(lists []*List) *Index {
+	index := &Index{blocks: make(map[string][]int)}
+	for _, list := range lists {
+		for _, entry := range list.Entries {
+			position := len(index.entries)
+			index.entries = append(index.entries, indexedEntry{list: list, entry: entry})
+
+			keys := make(map[string]bool)
+			for _, name := range entry.Names() {
+				for _, key := range blockingKeys(name) {
+					keys[key] = true
+				}
+			}
+			for key := range keys {
+				index.blocks[key] = append(index.blocks[key], position)
+			}
+		}
+	}
+	return index
+}
+
+// Size returns the number of indexed entries
+func (ix *Index) Size() int {
+	return len(ix.entries)
+}
+
+// Screen scores the subject against every entry sharing a blocking key and
+// returns the matches at or above the threshold, best first
+func (ix *Index) Screen(subject *Subject, scorer Scorer, threshold float64) []*Match {
+	if ix == nil || subject == nil || strings.TrimSpace(subject.Name) == "" {
+		return nil
+	}
+
+	seen := make(map[int]bool)
+	var candidates []int
+	for _, name := range subject.Names() {
+		for _, key := range blockingKeys(name) {
+			block := ix.blocks[key]
+			// Very common tokens ("al", "bin") block nothing useful on
+			// their own; other tokens of the name will still find the entry
+			if len(block) > maxCandidatesPerBlockKey {
+				continue
+			}
+			for _, position := range block {
+				if !seen[position] {
+					seen[position] = true
+					candidates = append(candidates, position)
+				}
+			}
+		}
+	}
+
+	algorithms := scorer.NameSettings().Algorithms
+	subjectCategory := entityCategory(subject.EntityType)
+
+	var matches []*Match
+	for _, position := range candidates {
+		candidate := ix.entries[position]
+		if !compatibleTypes(subjectCategory, candidate.entry.Type) {
+			continue
+		}
+
+		match := &Match{List: candidate.list, Entry: candidate.entry}
+		for _, subjectName := range subject.Names() {
+			if strings.TrimSpace(subjectName) == "" {
+				continue
+			}
+			for _, entryName := range candidate.entry.Names() {
+				score := matching.CombineNameScores(scorer.NameScores(subjectName, entryName), algorithms)
+				if score > match.NameScore {
+					match.NameScore = score
+					match.SubjectName = subjectName
+					match.MatchedName = entryName
+				}
+			}
+		}
+
+		match.Score = match.NameScore
+		if dob := compareDatesOfBirth(subject.DateOfBirth, candidate.entry.DatesOfBirth); dob != nil {
+			match.DateOfBirthMatch = dob
+			if *dob {
+				match.Score += dateOfBirthMatchBoost
+			} else {
+				match.Score -= dateOfBirthMismatchCost
+			}
+		}
+		if sharesCountry(subject.Countries, candidate.entry.Countries) {
+			match.CountryMatch = true
+			match.Score += countryMatchBoost
+		}
+		match.Score = math.Max(0, math.Min(1, match.Score))
+
+		if match.Score >= threshold {
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if matches[i].List.Name != matches[j].List.Name {
+			return matches[i].List.Name < matches[j].List.Name
+		}
+		return matches[i].Entry.ID < matches[j].Entry.ID
+	})
+
+	return matches
+}
+
+// blockingKeys returns one key per name token: its first letter followed by
+// its remaining consonants with repeats collapsed, so spelling variants such
+// as "Mohammed" and "Muhamad" share a key
+func blockingKeys(name string) []string {
+	var keys []string
+	for _, token := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(token)
+		if len(runes) < 2 {
+			continue
+		}
+
+		key := []rune{runes[0]}
+		var last rune
+		for _, r := range runes[1:] {
+			if strings.ContainsRune("aeiouyhw", r) {
+				continue
+			}
+			if r != last {
+				key = append(key, r)
+			}
+			last = r
+		}
+		keys = append(keys, string(key))
+	}
+	return keys
+}
+
+// entityCategory maps an entity type to a list entry type, or "" when the
+// type says nothing about whether it is a person
+func entityCategory(entityType string) string {
+	switch strings.ToLower(entityType) {
+	case "person", "individual", "customer":
+		return EntryIndividual
+	case "organization", "company", "business", "corporate", "entity", "legal_entity":
+		return EntryEntity
+	}
+	return ""
+}
+
+// compatibleTypes reports whether a subject may be screened against an entry;
+// people are not screened against organizations, vessels or aircraft and
+// organizations are not screened against people
+func compatibleTypes(subjectCategory, entryType string) bool {
+	if subjectCategory == "" || entryType == "" {
+		return true
+	}
+	if subjectCategory == EntryIndividual {
+		return entryType == EntryIndividual
+	}
+	return entryType != EntryIndividual
+}
+
+// compareDatesOfBirth compares dates on their common precision, so a list
+// entry carrying only a birth year matches any date in that year. It
+// returns nil when either side has no date.
+func compareDatesOfBirth(subject string, entry []string) *bool {
+	subject = digitsOnly(subject)
+	if subject == "" || len(entry) == 0 {
+		return nil
+	}
+
+	matched := false
+	compared := false
+	for _, date := range entry {
+		date = digitsOnly(date)
+		if date == "" {
+			continue
+		}
+		compared = true
+		if strings.HasPrefix(subject, date) || strings.HasPrefix(date, subject) {
+			matched = true
+			break
+		}
+	}
+	if !compared {
+		return nil
+	}
+	return &matched
+}
+
+func digitsOnly(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+}
+
+func sharesCountry(subject, entry []string) bool {
+	for _, a := range subject {
+		for _, b := range entry {
+			if a != "" && strings.EqualFold(a, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
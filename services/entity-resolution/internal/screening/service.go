@@ -0,0 +1,596 @@
+package screening
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultHitLimit = 50
+	maxHitLimit     = 500
+)
+
+var (
+	// ErrInvalidReview is returned for dispositions other than confirmed or false positive
+	ErrInvalidReview = fmt.Errorf("status must be %s or %s", database.ScreeningHitConfirmed, database.ScreeningHitFalsePositive)
+	// ErrReviewerRequired is returned when a disposition names no investigator
+	ErrReviewerRequired = errors.New("reviewer is required")
+	// ErrConfiguredList is returned when uploading over or deleting a list loaded from configuration
+	ErrConfiguredList = errors.New("list is loaded from configuration")
+	// ErrInvalidListName is returned for list names that are not lowercase slugs
+	ErrInvalidListName = errors.New("list name must be 1-100 lowercase letters, digits, '-' or '_'")
+)
+
+var listNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,100}$`)
+
+// Store persists uploaded lists and screening hits
+type Store interface {
+	SaveScreeningList(ctx context.Context, list *database.ScreeningList) error
+	ListScreeningLists(ctx context.Context) ([]*database.ScreeningList, error)
+	DeleteScreeningList(ctx context.Context, name string) error
+	SaveScreeningHits(ctx context.Context, hits []*database.ScreeningHit) ([]*database.ScreeningHit, error)
+	GetScreeningHit(ctx context.Context, id uuid.UUID) (*database.ScreeningHit, error)
+	ListScreeningHits(ctx context.Context, filter database.ScreeningHitFilter) ([]*database.ScreeningHit, error)
+	ReviewScreeningHit(ctx context.Context, id uuid.UUID, status, reviewerID, notes string) (*database.ScreeningHit, error)
+	GetEntity(ctx context.Context, id uuid.UUID) (*database.Entity, error)
+}
+
+// Publisher announces new screening hits so the alerting engine can raise alerts
+type Publisher interface {
+	PublishScreeningHit(ctx context.Context, hit *database.ScreeningHit) error
+}
+
+// UploadRequest uploads a list through the API
+type UploadRequest struct {
+	Name       string
+	Kind       string // defaults by the configured PEP list names
+	Format     string
+	Content    []byte
+	UploadedBy string
+}
+
+// ReviewRequest represents an investigator's disposition of a hit
+type ReviewRequest struct {
+	Status     string `json:"status"`
+	ReviewerID string `json:"reviewer_id"`
+	Notes      string `json:"notes,omitempty"`
+}
+
+// ListStatus describes a loaded list, or a configured list that failed to load
+type ListStatus struct {
+	Name       string    `json:"name"`
+	Kind       string    `json:"kind"`
+	Source     string    `json:"source"`
+	Format     string    `json:"format"`
+	Checksum   string    `json:"checksum,omitempty"`
+	EntryCount int       `json:"entry_count"`
+	LoadedAt   time.Time `json:"loaded_at,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Service screens resolved entities against sanctions and PEP lists. Lists
+// come from configured files or URLs, reloaded periodically, or from uploads
+// kept in the database. Hits are stored per entity and list entry and only
+// hits seen for the first time are published.
+type Service struct {
+	store     Store
+	publisher Publisher
+	scorer    Scorer
+	config    config.ScreeningConfig
+	client    *http.Client
+	logger    *slog.Logger
+
+	loadMu sync.Mutex // serializes list loads
+
+	mu         sync.RWMutex
+	lists      map[string]*List
+	loadErrors map[string]string
+	index      *Index
+}
+
+// NewService creates a new screening service
+func NewService(store Store, publisher Publisher, scorer Scorer, config config.ScreeningConfig, logger *slog.Logger) *Service {
+	return &Service{
+		store:      store,
+		publisher:  publisher,
+		scorer:     scorer,
+		config:     config,
+		client:     &http.Client{Timeout: config.FetchTimeout},
+		logger:     logger,
+		lists:      make(map[string]*List),
+		loadErrors: make(map[string]string),
+		index:      NewIndex(nil),
+	}
+}
+
+// Start reloads the configured lists every reload interval until the context
+// is cancelled
+func (s *Service) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.LoadLists(ctx); err != nil {
+				s.logger.Warn("Failed to reload screening lists", "error", err)
+			}
+		}
+	}
+}
+
+// LoadLists loads the configured and uploaded lists. A list that fails to
+// load keeps its previously loaded version; the failures are returned joined.
+func (s *Service) LoadLists(ctx context.Context) error {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	loaded := make(map[string]*List)
+	loadErrors := make(map[string]string)
+	var errs []error
+
+	names := make([]string, 0, len(s.config.Lists))
+	for name := range s.config.Lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		location := s.config.Lists[name]
+		list, err := s.fetchList(ctx, name, location)
+		if err != nil {
+			loadErrors[name] = err.Error()
+			errs = append(errs, fmt.Errorf("list %s: %w", name, err))
+			continue
+		}
+		loaded[name] = list
+	}
+
+	uploads, uploadsErr := s.store.ListScreeningLists(ctx)
+	if uploadsErr != nil {
+		errs = append(errs, uploadsErr)
+	}
+	for _, upload := range uploads {
+		if _, configured := s.config.Lists[upload.Name]; configured {
+			continue
+		}
+		list, err := s.parseUpload(upload)
+		if err != nil {
+			loadErrors[upload.Name] = err.Error()
+			errs = append(errs, fmt.Errorf("list %s: %w", upload.Name, err))
+			continue
+		}
+		loaded[upload.Name] = list
+	}
+
+	s.mu.Lock()
+	for name := range loadErrors {
+		if previous, ok := s.lists[name]; ok {
+			loaded[name] = previous
+		}
+	}
+	// Uploads are not re-read when the store is unreachable
+	if uploadsErr != nil {
+		for name, previous := range s.lists {
+			if _, ok := loaded[name]; !ok && previous.Source == "upload" {
+				loaded[name] = previous
+			}
+		}
+	}
+	s.lists = loaded
+	s.loadErrors = loadErrors
+	s.rebuildIndexLocked()
+	entries := s.index.Size()
+	s.mu.Unlock()
+
+	s.logger.Info("Loaded screening lists",
+		"lists", len(loaded),
+		"entries", entries,
+		"failed", len(loadErrors))
+
+	return errors.Join(errs...)
+}
+
+func (s *Service) fetchList(ctx context.Context, name, location string) (*List, error) {
+	data, err := Fetch(ctx, s.client, location, s.config.APIKey, s.config.MaxUploadBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	format := FormatOf(location)
+	entries, err := ParseList(format, data, s.config.MaxListEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	return &List{
+		Name:     name,
+		Kind:     s.kindOf(name),
+		Source:   location,
+		Format:   format,
+		Checksum: Checksum(data),
+		Entries:  entries,
+		LoadedAt: time.Now(),
+	}, nil
+}
+
+func (s *Service) parseUpload(upload *database.ScreeningList) (*List, error) {
+	entries, err := ParseList(upload.Format, upload.Content, s.config.MaxListEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	return &List{
+		Name:     upload.Name,
+		Kind:     upload.Kind,
+		Source:   "upload",
+		Format:   upload.Format,
+		Checksum: upload.Checksum,
+		Entries:  entries,
+		LoadedAt: upload.UploadedAt,
+	}, nil
+}
+
+// MaxUploadBytes returns the size limit of an uploaded list
+func (s *Service) MaxUploadBytes() int64 {
+	return s.config.MaxUploadBytes
+}
+
+// kindOf returns the kind of a list by its name
+func (s *Service) kindOf(name string) string {
+	for _, pep := range s.config.PEPLists {
+		if strings.EqualFold(pep, name) {
+			return KindPEP
+		}
+	}
+	return KindSanctions
+}
+
+func (s *Service) rebuildIndexLocked() {
+	lists := make([]*List, 0, len(s.lists))
+	for _, list := range s.lists {
+		lists = append(lists, list)
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Name < lists[j].Name })
+	s.index = NewIndex(lists)
+}
+
+// UploadList parses, stores and loads an uploaded list, replacing an earlier
+// upload of the same name
+func (s *Service) UploadList(ctx context.Context, req *UploadRequest) (*ListStatus, error) {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	if !listNamePattern.MatchString(req.Name) {
+		return nil, ErrInvalidListName
+	}
+	if _, configured := s.config.Lists[req.Name]; configured {
+		return nil, ErrConfiguredList
+	}
+
+	kind := req.Kind
+	if kind == "" {
+		kind = s.kindOf(req.Name)
+	}
+	if kind != KindSanctions && kind != KindPEP {
+		return nil, fmt.Errorf("%w: kind must be %s or %s", ErrInvalidList, KindSanctions, KindPEP)
+	}
+	if int64(len(req.Content)) > s.config.MaxUploadBytes {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalidList, s.config.MaxUploadBytes)
+	}
+
+	entries, err := ParseList(req.Format, req.Content, s.config.MaxListEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &database.ScreeningList{
+		Name:       req.Name,
+		Kind:       kind,
+		Format:     req.Format,
+		Content:    req.Content,
+		Checksum:   Checksum(req.Content),
+		EntryCount: len(entries),
+		UploadedBy: req.UploadedBy,
+		UploadedAt: time.Now(),
+	}
+	if err := s.store.SaveScreeningList(ctx, upload); err != nil {
+		return nil, err
+	}
+
+	list := &List{
+		Name:     upload.Name,
+		Kind:     upload.Kind,
+		Source:   "upload",
+		Format:   upload.Format,
+		Checksum: upload.Checksum,
+		Entries:  entries,
+		LoadedAt: upload.UploadedAt,
+	}
+
+	s.mu.Lock()
+	s.lists[list.Name] = list
+	delete(s.loadErrors, list.Name)
+	s.rebuildIndexLocked()
+	s.mu.Unlock()
+
+	s.logger.Info("Uploaded screening list",
+		"list", list.Name,
+		"kind", list.Kind,
+		"entries", len(entries),
+		"uploaded_by", req.UploadedBy)
+
+	return statusOf(list), nil
+}
+
+// DeleteList removes an uploaded list. Hits already recorded against it are kept.
+func (s *Service) DeleteList(ctx context.Context, name string) error {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	if _, configured := s.config.Lists[name]; configured {
+		return ErrConfiguredList
+	}
+	if err := s.store.DeleteScreeningList(ctx, name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.lists, name)
+	delete(s.loadErrors, name)
+	s.rebuildIndexLocked()
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Lists returns the status of every loaded list and of configured lists
+// that failed to load
+func (s *Service) Lists() []*ListStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]*ListStatus, 0, len(s.lists)+len(s.loadErrors))
+	for name, list := range s.lists {
+		status := statusOf(list)
+		status.Error = s.loadErrors[name]
+		statuses = append(statuses, status)
+	}
+	for name, loadErr := range s.loadErrors {
+		if _, ok := s.lists[name]; ok {
+			continue
+		}
+		source := s.config.Lists[name]
+		if source == "" {
+			source = "upload"
+		}
+		statuses = append(statuses, &ListStatus{
+			Name:   name,
+			Kind:   s.kindOf(name),
+			Source: source,
+			Error:  loadErr,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func statusOf(list *List) *ListStatus {
+	return &ListStatus{
+		Name:       list.Name,
+		Kind:       list.Kind,
+		Source:     list.Source,
+		Format:     list.Format,
+		Checksum:   list.Checksum,
+		EntryCount: len(list.Entries),
+		LoadedAt:   list.LoadedAt,
+	}
+}
+
+// ScreenEntity screens a subject against the loaded lists, records its hits
+// and publishes the ones not recorded before. Every current hit is returned.
+func (s *Service) ScreenEntity(ctx context.Context, subject *Subject) ([]*database.ScreeningHit, error) {
+	s.mu.RLock()
+	index := s.index
+	s.mu.RUnlock()
+
+	matches := index.Screen(subject, s.scorer, s.config.Threshold)
+	if len(matches) > s.config.MaxHitsPerEntity {
+		matches = matches[:s.config.MaxHitsPerEntity]
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	hits := make([]*database.ScreeningHit, 0, len(matches))
+	for _, match := range matches {
+		evidence, err := json.Marshal(evidenceOf(match))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal screening evidence: %w", err)
+		}
+
+		hits = append(hits, &database.ScreeningHit{
+			ID:              uuid.New(),
+			EntityID:        subject.EntityID,
+			EntityType:      subject.EntityType,
+			EntityName:      subject.Name,
+			ListName:        match.List.Name,
+			ListKind:        match.List.Kind,
+			EntryID:         match.Entry.ID,
+			EntryName:       match.Entry.Name,
+			MatchedName:     match.MatchedName,
+			Score:           match.Score,
+			Evidence:        evidence,
+			Status:          database.ScreeningHitOpen,
+			FirstScreenedAt: now,
+			LastScreenedAt:  now,
+		})
+	}
+
+	created, err := s.store.SaveScreeningHits(ctx, hits)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hit := range created {
+		s.logger.Info("Screening hit",
+			"entity_id", hit.EntityID,
+			"list", hit.ListName,
+			"entry_id", hit.EntryID,
+			"score", hit.Score)
+
+		if s.publisher == nil {
+			continue
+		}
+		if err := s.publisher.PublishScreeningHit(ctx, hit); err != nil {
+			s.logger.Warn("Failed to publish screening hit",
+				"hit_id", hit.ID,
+				"error", err)
+		}
+	}
+
+	return hits, nil
+}
+
+// ScreenEntityByID screens a stored entity
+func (s *Service) ScreenEntityByID(ctx context.Context, entityID uuid.UUID) ([]*database.ScreeningHit, error) {
+	entity, err := s.store.GetEntity(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	var attributes map[string]interface{}
+	if len(entity.Attributes) > 0 {
+		if err := json.Unmarshal(entity.Attributes, &attributes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal entity attributes: %w", err)
+		}
+	}
+
+	return s.ScreenEntity(ctx, NewSubject(entity.ID, entity.EntityType, entity.Name, attributes))
+}
+
+// NewSubject builds a subject from an entity's name and attributes. Aliases
+// are read from "aliases", the date of birth from "date_of_birth" or "dob"
+// and countries from "nationality", "country" or "countries".
+func NewSubject(entityID uuid.UUID, entityType, name string, attributes map[string]interface{}) *Subject {
+	subject := &Subject{
+		EntityID:   entityID,
+		EntityType: entityType,
+		Name:       name,
+		Aliases:    stringValues(attributes["aliases"]),
+	}
+
+	for _, key := range []string{"date_of_birth", "dob"} {
+		if values := stringValues(attributes[key]); len(values) > 0 {
+			subject.DateOfBirth = values[0]
+			break
+		}
+	}
+	for _, key := range []string{"nationality", "country", "countries"} {
+		for _, country := range stringValues(attributes[key]) {
+			subject.Countries = append(subject.Countries, strings.ToUpper(country))
+		}
+	}
+
+	return subject
+}
+
+func stringValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v = strings.TrimSpace(v); v != "" {
+			return []string{v}
+		}
+	case []string:
+		return v
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				values = append(values, strings.TrimSpace(s))
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func evidenceOf(match *Match) map[string]interface{} {
+	evidence := map[string]interface{}{
+		"name_score":    match.NameScore,
+		"subject_name":  match.SubjectName,
+		"matched_name":  match.MatchedName,
+		"country_match": match.CountryMatch,
+	}
+	if match.DateOfBirthMatch != nil {
+		evidence["date_of_birth_match"] = *match.DateOfBirthMatch
+	}
+	if len(match.Entry.Programs) > 0 {
+		evidence["programs"] = match.Entry.Programs
+	}
+	if len(match.Entry.Countries) > 0 {
+		evidence["countries"] = match.Entry.Countries
+	}
+	if match.Entry.Remarks != "" {
+		evidence["remarks"] = match.Entry.Remarks
+	}
+	return evidence
+}
+
+// ListHits lists recorded hits, best scoring first
+func (s *Service) ListHits(ctx context.Context, filter database.ScreeningHitFilter) ([]*database.ScreeningHit, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultHitLimit
+	}
+	if filter.Limit > maxHitLimit {
+		filter.Limit = maxHitLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.store.ListScreeningHits(ctx, filter)
+}
+
+// GetHit retrieves a recorded hit
+func (s *Service) GetHit(ctx context.Context, id uuid.UUID) (*database.ScreeningHit, error) {
+	return s.store.GetScreeningHit(ctx, id)
+}
+
+// ReviewHit records an investigator's disposition of a hit
+func (s *Service) ReviewHit(ctx context.Context, id uuid.UUID, req *ReviewRequest) (*database.ScreeningHit, error) {
+	if req.Status != database.ScreeningHitConfirmed && req.Status != database.ScreeningHitFalsePositive {
+		return nil, ErrInvalidReview
+	}
+	if strings.TrimSpace(req.ReviewerID) == "" {
+		return nil, ErrReviewerRequired
+	}
+
+	hit, err := s.store.ReviewScreeningHit(ctx, id, req.Status, req.ReviewerID, req.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Reviewed screening hit",
+		"hit_id", id,
+		"status", req.Status,
+		"reviewer_id", req.ReviewerID)
+
+	return hit, nil
+}
@@ -2,10 +2,15 @@ package server
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
 	"github.com/aegisshield/entity-resolution/internal/resolver"
+	"github.com/aegisshield/entity-resolution/internal/review"
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -19,6 +24,7 @@ import (
 type GRPCServer struct {
 	pb.UnimplementedEntityResolutionServer
 	resolver *resolver.EntityResolver
+	reviews  *review.Service
 	config   config.Config
 	logger   *slog.Logger
 }
@@ -36,6 +42,11 @@ func NewGRPCServer(
 	}
 }
 
+// SetReviewService enables the manual merge review RPCs
+func (s *GRPCServer) SetReviewService(reviews *review.Service) {
+	s.reviews = reviews
+}
+
 // ResolveEntity resolves a single entity
 func (s *GRPCServer) ResolveEntity(ctx context.Context, req *pb.ResolveEntityRequest) (*pb.ResolveEntityResponse, error) {
 	s.logger.Info("Received ResolveEntity request",
@@ -348,6 +359,106 @@ func (s *GRPCServer) StreamResolution(stream pb.EntityResolution_StreamResolutio
 	}
 }
 
+// ListMergeReviews lists merges queued for or decided by investigators
+func (s *GRPCServer) ListMergeReviews(ctx context.Context, req *pb.ListMergeReviewsRequest) (*pb.ListMergeReviewsResponse, error) {
+	if s.reviews == nil {
+		return nil, status.Error(codes.Unimplemented, "merge review is disabled")
+	}
+
+	filter := database.MergeReviewFilter{
+		Status: req.Status,
+		Limit:  int(req.Limit),
+		Offset: int(req.Offset),
+	}
+	if req.EntityId != "" {
+		entityID, err := uuid.Parse(req.EntityId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid entity_id")
+		}
+		filter.EntityID = &entityID
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+
+	reviews, err := s.reviews.List(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to list merge reviews", "error", err)
+		return nil, status.Error(codes.Internal, "failed to list merge reviews")
+	}
+
+	response := &pb.ListMergeReviewsResponse{}
+	for _, mergeReview := range reviews {
+		response.Reviews = append(response.Reviews, mergeReviewToProto(mergeReview))
+	}
+
+	return response, nil
+}
+
+// GetMergeReview returns a merge review with its evidence
+func (s *GRPCServer) GetMergeReview(ctx context.Context, req *pb.GetMergeReviewRequest) (*pb.MergeReview, error) {
+	if s.reviews == nil {
+		return nil, status.Error(codes.Unimplemented, "merge review is disabled")
+	}
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+
+	mergeReview, err := s.reviews.Get(ctx, id)
+	if err != nil {
+		return nil, s.reviewError(id, "get", err)
+	}
+
+	return mergeReviewToProto(mergeReview), nil
+}
+
+// DecideMergeReview approves or rejects a queued merge
+func (s *GRPCServer) DecideMergeReview(ctx context.Context, req *pb.DecideMergeReviewRequest) (*pb.MergeReview, error) {
+	if s.reviews == nil {
+		return nil, status.Error(codes.Unimplemented, "merge review is disabled")
+	}
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+
+	mergeReview, err := s.reviews.Decide(ctx, id, &review.DecisionRequest{
+		Decision:   req.Decision,
+		ReviewerID: req.ReviewerId,
+		Notes:      req.Notes,
+	})
+	if err != nil {
+		return nil, s.reviewError(id, "decide", err)
+	}
+
+	return mergeReviewToProto(mergeReview), nil
+}
+
+// SplitMerge undoes an approved merge, restoring both entities
+func (s *GRPCServer) SplitMerge(ctx context.Context, req *pb.SplitMergeRequest) (*pb.MergeReview, error) {
+	if s.reviews == nil {
+		return nil, status.Error(codes.Unimplemented, "merge review is disabled")
+	}
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+
+	mergeReview, err := s.reviews.Split(ctx, id, &review.SplitRequest{
+		SplitBy: req.SplitBy,
+		Reason:  req.Reason,
+	})
+	if err != nil {
+		return nil, s.reviewError(id, "split", err)
+	}
+
+	return mergeReviewToProto(mergeReview), nil
+}
+
 // HealthCheck performs a health check
 func (s *GRPCServer) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
 	return &pb.HealthCheckResponse{
@@ -358,6 +469,48 @@ func (s *GRPCServer) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest
 
 // Helper functions
 
+func (s *GRPCServer) reviewError(id uuid.UUID, action string, err error) error {
+	switch {
+	case errors.Is(err, review.ErrInvalidDecision), errors.Is(err, review.ErrReviewerRequired):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, database.ErrReviewNotPending), errors.Is(err, database.ErrReviewNotApproved),
+		errors.Is(err, database.ErrAlreadyMerged), errors.Is(err, database.ErrSplitConflict):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case strings.Contains(err.Error(), "not found"):
+		return status.Error(codes.NotFound, "merge review not found")
+	default:
+		s.logger.Error("Failed to "+action+" merge review", "review_id", id, "error", err)
+		return status.Error(codes.Internal, "failed to "+action+" merge review")
+	}
+}
+
+func mergeReviewToProto(mergeReview *database.MergeReview) *pb.MergeReview {
+	return &pb.MergeReview{
+		Id:                mergeReview.ID.String(),
+		SurvivorEntityId:  mergeReview.SurvivorEntityID.String(),
+		DuplicateEntityId: mergeReview.DuplicateEntityID.String(),
+		EntityType:        mergeReview.EntityType,
+		RawScore:          mergeReview.RawScore,
+		CalibratedScore:   mergeReview.CalibratedScore,
+		EvidenceJson:      string(mergeReview.Evidence),
+		Status:            mergeReview.Status,
+		ReviewerId:        mergeReview.ReviewerID,
+		ReviewNotes:       mergeReview.ReviewNotes,
+		ReviewedAt:        optionalTimestamp(mergeReview.ReviewedAt),
+		SplitBy:           mergeReview.SplitBy,
+		SplitReason:       mergeReview.SplitReason,
+		SplitAt:           optionalTimestamp(mergeReview.SplitAt),
+		CreatedAt:         timestamppb.New(mergeReview.CreatedAt),
+	}
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
 func protoMapToGoMap(protoMap map[string]*pb.Value) map[string]interface{} {
 	if protoMap == nil {
 		return nil
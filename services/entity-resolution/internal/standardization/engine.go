@@ -4,7 +4,6 @@ import (
 	"log/slog"
 	"regexp"
 	"strings"
-	"unicode"
 
 	"github.com/bbalet/stopwords"
 	"github.com/kljensen/snowball"
@@ -17,11 +16,13 @@ type Engine struct {
 
 // StandardizedName represents a standardized name with metadata
 type StandardizedName struct {
-	Original     string   `json:"original"`
-	Standardized string   `json:"standardized"`
-	Tokens       []string `json:"tokens"`
-	Phonetic     string   `json:"phonetic"`
-	Metaphone    string   `json:"metaphone"`
+	Original       string          `json:"original"`
+	Standardized   string          `json:"standardized"`
+	Tokens         []string        `json:"tokens"`
+	Phonetic       string          `json:"phonetic"`  // Soundex codes of the standardized tokens
+	Metaphone      string          `json:"metaphone"` // primary Double Metaphone codes of the standardized tokens
+	PhoneticCodes  []string        `json:"phonetic_codes"`
+	MetaphoneCodes []MetaphoneCode `json:"metaphone_codes"`
 }
 
 // StandardizedAddress represents a standardized address
@@ -66,19 +67,26 @@ func (e *Engine) StandardizeName(name string) *StandardizedName {
 		}
 	}
 
-	// Clean and normalize the name
-	cleaned := e.cleanName(name)
+	// Clean and normalize the name; non-Latin names are transliterated first
+	// so they compare with their Latin spellings
+	cleaned := e.cleanName(Transliterate(name))
 	tokens := e.tokenizeName(cleaned)
 	standardized := e.standardizeNameTokens(tokens)
-	phonetic := e.generatePhonetic(standardized)
-	metaphone := e.generateMetaphone(standardized)
+	phonetic, metaphone := PhoneticCodes(standardized)
+
+	primaries := make([]string, len(metaphone))
+	for i, code := range metaphone {
+		primaries[i] = code.Primary
+	}
 
 	return &StandardizedName{
-		Original:     name,
-		Standardized: standardized,
-		Tokens:       tokens,
-		Phonetic:     phonetic,
-		Metaphone:    metaphone,
+		Original:       name,
+		Standardized:   standardized,
+		Tokens:         tokens,
+		Phonetic:       strings.Join(phonetic, " "),
+		Metaphone:      strings.Join(primaries, " "),
+		PhoneticCodes:  phonetic,
+		MetaphoneCodes: metaphone,
 	}
 }
 
@@ -259,118 +267,20 @@ func (e *Engine) normalizeNameToken(token string) string {
 	return token
 }
 
-// Phonetic encoding
-func (e *Engine) generatePhonetic(text string) string {
-	// Simple phonetic encoding (Soundex-like)
-	if text == "" {
-		return ""
-	}
-
-	text = strings.ToUpper(text)
-	text = regexp.MustCompile(`[^A-Z]`).ReplaceAllString(text, "")
-
-	if len(text) == 0 {
-		return ""
-	}
-
-	// Keep first letter
-	result := string(text[0])
-
-	// Replace consonants with digits
-	replacements := map[rune]rune{
-		'B': '1', 'F': '1', 'P': '1', 'V': '1',
-		'C': '2', 'G': '2', 'J': '2', 'K': '2', 'Q': '2', 'S': '2', 'X': '2', 'Z': '2',
-		'D': '3', 'T': '3',
-		'L': '4',
-		'M': '5', 'N': '5',
-		'R': '6',
-	}
-
-	for _, char := range text[1:] {
-		if replacement, exists := replacements[char]; exists {
-			result += string(replacement)
+// PhoneticCodes encodes each word of a standardized text with Soundex and
+// Double Metaphone, skipping words with no letters to encode
+func PhoneticCodes(text string) ([]string, []MetaphoneCode) {
+	var soundex []string
+	var metaphone []MetaphoneCode
+	for _, word := range strings.Fields(text) {
+		code := Soundex(word)
+		if code == "" {
+			continue
 		}
+		soundex = append(soundex, code)
+		metaphone = append(metaphone, DoubleMetaphone(word))
 	}
-
-	// Remove duplicates and pad/truncate to 4 characters
-	result = e.removeDuplicateChars(result)
-	for len(result) < 4 {
-		result += "0"
-	}
-	if len(result) > 4 {
-		result = result[:4]
-	}
-
-	return result
-}
-
-func (e *Engine) generateMetaphone(text string) string {
-	// Simplified Double Metaphone algorithm
-	if text == "" {
-		return ""
-	}
-
-	text = strings.ToUpper(text)
-	text = regexp.MustCompile(`[^A-Z]`).ReplaceAllString(text, "")
-
-	// Apply basic metaphone rules (simplified)
-	result := text
-	
-	// Replace common patterns
-	replacements := [][]string{
-		{"PH", "F"},
-		{"GH", "F"},
-		{"CK", "K"},
-		{"SCH", "SK"},
-		{"TH", "0"},
-		{"SH", "X"},
-		{"CH", "X"},
-	}
-
-	for _, replacement := range replacements {
-		result = strings.ReplaceAll(result, replacement[0], replacement[1])
-	}
-
-	// Remove vowels except at the beginning
-	if len(result) > 1 {
-		vowels := "AEIOU"
-		var newResult strings.Builder
-		newResult.WriteByte(result[0])
-		
-		for i := 1; i < len(result); i++ {
-			if !strings.ContainsRune(vowels, rune(result[i])) {
-				newResult.WriteByte(result[i])
-			}
-		}
-		result = newResult.String()
-	}
-
-	// Pad/truncate to 4 characters
-	for len(result) < 4 {
-		result += "0"
-	}
-	if len(result) > 4 {
-		result = result[:4]
-	}
-
-	return result
-}
-
-func (e *Engine) removeDuplicateChars(s string) string {
-	if len(s) <= 1 {
-		return s
-	}
-
-	var result strings.Builder
-	result.WriteByte(s[0])
-
-	for i := 1; i < len(s); i++ {
-		if s[i] != s[i-1] {
-			result.WriteByte(s[i])
-		}
-	}
-
-	return result.String()
+	return soundex, metaphone
 }
 
 // Address standardization
@@ -414,8 +324,8 @@ func (ac *AddressComponents) String() string {
 }
 
 func (e *Engine) cleanAddress(address string) string {
-	// Convert to lowercase and remove extra whitespace
-	address = strings.ToLower(address)
+	// Transliterate to lower-case Latin and remove extra whitespace
+	address = Transliterate(address)
 	address = regexp.MustCompile(`\s+`).ReplaceAllString(address, " ")
 	address = strings.TrimSpace(address)
 
@@ -0,0 +1,647 @@
+package standardization
+
+import (
+	"strings"
+)
+
+// metaphoneLength is the length Double Metaphone codes are truncated to
+const metaphoneLength = 4
+
+// MetaphoneCode is the Double Metaphone encoding of a word: the primary code
+// for its most likely pronunciation and an alternate for words whose origin
+// suggests another, such as the Germanic and Slavic spellings of a name
+type MetaphoneCode struct {
+	Primary   string `json:"primary"`
+	Alternate string `json:"alternate"`
+}
+
+// Matches reports whether two words may sound alike: any pairing of their
+// primary and alternate codes is equal
+func (c MetaphoneCode) Matches(other MetaphoneCode) bool {
+	if c.Primary == "" || other.Primary == "" {
+		return false
+	}
+	return c.Primary == other.Primary || c.Primary == other.Alternate ||
+		c.Alternate == other.Primary || c.Alternate == other.Alternate
+}
+
+// Soundex returns the American Soundex code of a word: its first letter
+// followed by three digits for the consonant sounds after it. Letters
+// outside A-Z are ignored; a word without any has no code.
+func Soundex(word string) string {
+	letters := asciiLetters(word)
+	if letters == "" {
+		return ""
+	}
+
+	code := []byte{letters[0]}
+	last := soundexDigit(letters[0])
+	for i := 1; i < len(letters) && len(code) < 4; i++ {
+		c := letters[i]
+		if c == 'H' || c == 'W' {
+			// H and W do not separate consonants sharing a code
+			continue
+		}
+		digit := soundexDigit(c)
+		if digit != '0' && digit != last {
+			code = append(code, digit)
+		}
+		last = digit
+	}
+
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+func soundexDigit(c byte) byte {
+	switch c {
+	case 'B', 'F', 'P', 'V':
+		return '1'
+	case 'C', 'G', 'J', 'K', 'Q', 'S', 'X', 'Z':
+		return '2'
+	case 'D', 'T':
+		return '3'
+	case 'L':
+		return '4'
+	case 'M', 'N':
+		return '5'
+	case 'R':
+		return '6'
+	default:
+		return '0'
+	}
+}
+
+// asciiLetters upper-cases a word and keeps only its letters A-Z
+func asciiLetters(word string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(word) {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// DoubleMetaphone returns the Double Metaphone codes of a word, following
+// Lawrence Philips' algorithm. Words should be transliterated to Latin
+// letters first; other characters are ignored.
+func DoubleMetaphone(word string) MetaphoneCode {
+	value := asciiLetters(word)
+	if value == "" {
+		return MetaphoneCode{}
+	}
+
+	m := &metaphone{value: value, slavoGermanic: isSlavoGermanic(value)}
+	index := 0
+	if m.contains(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		index = 1
+	}
+	if m.at(0) == 'X' {
+		m.add("S")
+		index = 1
+	}
+
+	for !m.full() && index < len(value) {
+		switch m.at(index) {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if index == 0 {
+				m.add("A")
+			}
+			index++
+		case 'B':
+			m.add("P")
+			index = m.skip(index, 'B')
+		case 'C':
+			index = m.handleC(index)
+		case 'D':
+			index = m.handleD(index)
+		case 'F':
+			m.add("F")
+			index = m.skip(index, 'F')
+		case 'G':
+			index = m.handleG(index)
+		case 'H':
+			index = m.handleH(index)
+		case 'J':
+			index = m.handleJ(index)
+		case 'K':
+			m.add("K")
+			index = m.skip(index, 'K')
+		case 'L':
+			index = m.handleL(index)
+		case 'M':
+			m.add("M")
+			if m.conditionM0(index) {
+				index += 2
+			} else {
+				index++
+			}
+		case 'N':
+			m.add("N")
+			index = m.skip(index, 'N')
+		case 'P':
+			index = m.handleP(index)
+		case 'Q':
+			m.add("K")
+			index = m.skip(index, 'Q')
+		case 'R':
+			index = m.handleR(index)
+		case 'S':
+			index = m.handleS(index)
+		case 'T':
+			index = m.handleT(index)
+		case 'V':
+			m.add("F")
+			index = m.skip(index, 'V')
+		case 'W':
+			index = m.handleW(index)
+		case 'X':
+			index = m.handleX(index)
+		case 'Z':
+			index = m.handleZ(index)
+		default:
+			index++
+		}
+	}
+
+	return MetaphoneCode{Primary: m.primary.String(), Alternate: m.alternate.String()}
+}
+
+// metaphone holds the state of one Double Metaphone encoding
+type metaphone struct {
+	value         string
+	slavoGermanic bool
+	primary       strings.Builder
+	alternate     strings.Builder
+}
+
+func isSlavoGermanic(value string) bool {
+	return strings.Contains(value, "W") || strings.Contains(value, "K") ||
+		strings.Contains(value, "CZ") || strings.Contains(value, "WITZ")
+}
+
+func isVowel(c byte) bool {
+	return strings.IndexByte("AEIOUY", c) >= 0
+}
+
+// at returns the letter at an index, or zero outside the word
+func (m *metaphone) at(index int) byte {
+	if index < 0 || index >= len(m.value) {
+		return 0
+	}
+	return m.value[index]
+}
+
+// contains reports whether the length letters from start equal any of criteria
+func (m *metaphone) contains(start, length int, criteria ...string) bool {
+	if start < 0 || start+length > len(m.value) {
+		return false
+	}
+	target := m.value[start : start+length]
+	for _, criterion := range criteria {
+		if target == criterion {
+			return true
+		}
+	}
+	return false
+}
+
+// skip moves past a letter, and past its double if the next letter repeats it
+func (m *metaphone) skip(index int, double byte) int {
+	if m.at(index+1) == double {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) full() bool {
+	return m.primary.Len() >= metaphoneLength && m.alternate.Len() >= metaphoneLength
+}
+
+// add appends the same sound to both codes
+func (m *metaphone) add(sound string) {
+	m.addPair(sound, sound)
+}
+
+// addPair appends a sound to each code, truncating both to metaphoneLength
+func (m *metaphone) addPair(primary, alternate string) {
+	appendLimited(&m.primary, primary)
+	appendLimited(&m.alternate, alternate)
+}
+
+func appendLimited(b *strings.Builder, sound string) {
+	if room := metaphoneLength - b.Len(); room > 0 {
+		if len(sound) > room {
+			sound = sound[:room]
+		}
+		b.WriteString(sound)
+	}
+}
+
+func (m *metaphone) handleC(index int) int {
+	switch {
+	case m.conditionC0(index):
+		m.add("K")
+		return index + 2
+	case index == 0 && m.contains(index, 6, "CAESAR"):
+		m.add("S")
+		return index + 2
+	case m.contains(index, 2, "CH"):
+		return m.handleCH(index)
+	case m.contains(index, 2, "CZ") && !m.contains(index-2, 4, "WICZ"):
+		m.addPair("S", "X")
+		return index + 2
+	case m.contains(index+1, 3, "CIA"):
+		m.add("X")
+		return index + 3
+	case m.contains(index, 2, "CC") && !(index == 1 && m.at(0) == 'M'):
+		return m.handleCC(index)
+	case m.contains(index, 2, "CK", "CG", "CQ"):
+		m.add("K")
+		return index + 2
+	case m.contains(index, 2, "CI", "CE", "CY"):
+		if m.contains(index, 3, "CIO", "CIE", "CIA") {
+			m.addPair("S", "X")
+		} else {
+			m.add("S")
+		}
+		return index + 2
+	}
+
+	m.add("K")
+	if m.contains(index+1, 1, "C", "K", "Q") && !m.contains(index+1, 2, "CE", "CI") {
+		return index + 2
+	}
+	return index + 1
+}
+
+// conditionC0 matches the Germanic "ACH" as in "Bacher" and "Macher"
+func (m *metaphone) conditionC0(index int) bool {
+	if m.contains(index, 4, "CHIA") {
+		return true
+	}
+	if index <= 1 || isVowel(m.at(index-2)) || !m.contains(index-1, 3, "ACH") {
+		return false
+	}
+	c := m.at(index + 2)
+	return (c != 'I' && c != 'E') || m.contains(index-2, 6, "BACHER", "MACHER")
+}
+
+func (m *metaphone) handleCC(index int) int {
+	if m.contains(index+2, 1, "I", "E", "H") && !m.contains(index+2, 2, "HU") {
+		if (index == 1 && m.at(index-1) == 'A') || m.contains(index-1, 5, "UCCEE", "UCCES") {
+			m.add("KS")
+		} else {
+			m.add("X")
+		}
+		return index + 3
+	}
+	m.add("K")
+	return index + 2
+}
+
+func (m *metaphone) handleCH(index int) int {
+	switch {
+	case index > 0 && m.contains(index, 4, "CHAE"):
+		m.addPair("K", "X")
+	case m.conditionCH0(index), m.conditionCH1(index):
+		m.add("K")
+	case index > 0 && m.contains(0, 2, "MC"):
+		m.add("K")
+	case index > 0:
+		m.addPair("X", "K")
+	default:
+		m.add("X")
+	}
+	return index + 2
+}
+
+// conditionCH0 matches a Greek initial "CH" as in "Character" and "Chorus"
+func (m *metaphone) conditionCH0(index int) bool {
+	if index != 0 {
+		return false
+	}
+	if !m.contains(index+1, 5, "HARAC", "HARIS") && !m.contains(index+1, 3, "HOR", "HYM", "HIA", "HEM") {
+		return false
+	}
+	return !m.contains(0, 5, "CHORE")
+}
+
+// conditionCH1 matches a Germanic or Greek "CH" pronounced K
+func (m *metaphone) conditionCH1(index int) bool {
+	return m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") ||
+		m.contains(index-2, 6, "ORCHES", "ARCHIT", "ORCHID") ||
+		m.contains(index+2, 1, "T", "S") ||
+		((m.contains(index-1, 1, "A", "O", "U", "E") || index == 0) &&
+			(m.contains(index+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") || index+1 == len(m.value)-1))
+}
+
+func (m *metaphone) handleD(index int) int {
+	if m.contains(index, 2, "DG") {
+		if m.contains(index+2, 1, "I", "E", "Y") {
+			m.add("J")
+			return index + 3
+		}
+		m.add("TK")
+		return index + 2
+	}
+	m.add("T")
+	if m.contains(index, 2, "DT", "DD") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleG(index int) int {
+	switch {
+	case m.at(index+1) == 'H':
+		return m.handleGH(index)
+	case m.at(index+1) == 'N':
+		if index == 1 && isVowel(m.at(0)) && !m.slavoGermanic {
+			m.addPair("KN", "N")
+		} else if !m.contains(index+2, 2, "EY") && m.at(index+1) != 'Y' && !m.slavoGermanic {
+			m.addPair("N", "KN")
+		} else {
+			m.add("KN")
+		}
+		return index + 2
+	case m.contains(index+1, 2, "LI") && !m.slavoGermanic:
+		m.addPair("KL", "L")
+		return index + 2
+	case index == 0 && (m.at(index+1) == 'Y' ||
+		m.contains(index+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		m.addPair("K", "J")
+		return index + 2
+	case (m.contains(index+1, 2, "ER") || m.at(index+1) == 'Y') &&
+		!m.contains(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!m.contains(index-1, 1, "E", "I") && !m.contains(index-1, 3, "RGY", "OGY"):
+		m.addPair("K", "J")
+		return index + 2
+	case m.contains(index+1, 1, "E", "I", "Y") || m.contains(index-1, 4, "AGGI", "OGGI"):
+		if m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") || m.contains(index+1, 2, "ET") {
+			m.add("K")
+		} else if m.contains(index+1, 3, "IER") {
+			m.add("J")
+		} else {
+			m.addPair("J", "K")
+		}
+		return index + 2
+	}
+
+	m.add("K")
+	return m.skip(index, 'G')
+}
+
+func (m *metaphone) handleGH(index int) int {
+	switch {
+	case index > 0 && !isVowel(m.at(index-1)):
+		m.add("K")
+	case index == 0:
+		if m.at(index+2) == 'I' {
+			m.add("J")
+		} else {
+			m.add("K")
+		}
+	case (index > 1 && m.contains(index-2, 1, "B", "H", "D")) ||
+		(index > 2 && m.contains(index-3, 1, "B", "H", "D")) ||
+		(index > 3 && m.contains(index-4, 1, "B", "H")):
+		// silent, as in "Hugh" and "Bough"
+	case index > 2 && m.at(index-1) == 'U' && m.contains(index-3, 1, "C", "G", "L", "R", "T"):
+		m.add("F")
+	case m.at(index-1) != 'I':
+		m.add("K")
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleH(index int) int {
+	if (index == 0 || isVowel(m.at(index-1))) && isVowel(m.at(index+1)) {
+		m.add("H")
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleJ(index int) int {
+	if m.contains(index, 4, "JOSE") || m.contains(0, 4, "SAN ") {
+		if (index == 0 && m.at(index+4) == ' ') || len(m.value) == 4 || m.contains(0, 4, "SAN ") {
+			m.add("H")
+		} else {
+			m.addPair("J", "H")
+		}
+		return index + 1
+	}
+
+	switch {
+	case index == 0:
+		m.addPair("J", "A")
+	case isVowel(m.at(index-1)) && !m.slavoGermanic && (m.at(index+1) == 'A' || m.at(index+1) == 'O'):
+		m.addPair("J", "H")
+	case index == len(m.value)-1:
+		m.addPair("J", "")
+	case !m.contains(index+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") && !m.contains(index-1, 1, "S", "K", "L"):
+		m.add("J")
+	}
+	return m.skip(index, 'J')
+}
+
+func (m *metaphone) handleL(index int) int {
+	if m.at(index+1) == 'L' {
+		if m.conditionL0(index) {
+			// Spanish "LL" as in "Cabrillo" and "Gallegos"
+			m.addPair("L", "")
+		} else {
+			m.add("L")
+		}
+		return index + 2
+	}
+	m.add("L")
+	return index + 1
+}
+
+func (m *metaphone) conditionL0(index int) bool {
+	last := len(m.value) - 1
+	if index == last-2 && m.contains(index-1, 4, "ILLO", "ILLA", "ALLE") {
+		return true
+	}
+	return (m.contains(last-1, 2, "AS", "OS") || m.contains(last, 1, "A", "O")) &&
+		m.contains(index-1, 4, "ALLE")
+}
+
+// conditionM0 matches a doubled M or the silent B of "Dumb" and "Thumb"
+func (m *metaphone) conditionM0(index int) bool {
+	if m.at(index+1) == 'M' {
+		return true
+	}
+	return m.contains(index-1, 3, "UMB") && (index+1 == len(m.value)-1 || m.contains(index+2, 2, "ER"))
+}
+
+func (m *metaphone) handleP(index int) int {
+	if m.at(index+1) == 'H' {
+		m.add("F")
+		return index + 2
+	}
+	m.add("P")
+	if m.contains(index+1, 1, "P", "B") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleR(index int) int {
+	if index == len(m.value)-1 && !m.slavoGermanic &&
+		m.contains(index-2, 2, "IE") && !m.contains(index-4, 2, "ME", "MA") {
+		// French final R as in "Rogier"
+		m.addPair("", "R")
+	} else {
+		m.add("R")
+	}
+	return m.skip(index, 'R')
+}
+
+func (m *metaphone) handleS(index int) int {
+	switch {
+	case m.contains(index-1, 3, "ISL", "YSL"):
+		// silent, as in "Island" and "Carlisle"
+		return index + 1
+	case index == 0 && m.contains(index, 5, "SUGAR"):
+		m.addPair("X", "S")
+		return index + 1
+	case m.contains(index, 2, "SH"):
+		if m.contains(index+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			m.add("S")
+		} else {
+			m.add("X")
+		}
+		return index + 2
+	case m.contains(index, 3, "SIO", "SIA") || m.contains(index, 4, "SIAN"):
+		if m.slavoGermanic {
+			m.add("S")
+		} else {
+			m.addPair("S", "X")
+		}
+		return index + 3
+	case (index == 0 && m.contains(index+1, 1, "M", "N", "L", "W")) || m.contains(index+1, 1, "Z"):
+		m.addPair("S", "X")
+		if m.contains(index+1, 1, "Z") {
+			return index + 2
+		}
+		return index + 1
+	case m.contains(index, 2, "SC"):
+		return m.handleSC(index)
+	}
+
+	if index == len(m.value)-1 && m.contains(index-2, 2, "AI", "OI") {
+		// French final S as in "Artois"
+		m.addPair("", "S")
+	} else {
+		m.add("S")
+	}
+	if m.contains(index+1, 1, "S", "Z") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleSC(index int) int {
+	switch {
+	case m.at(index+2) == 'H':
+		if m.contains(index+3, 2, "OO", "ER", "EN", "UY", "ED", "EM") {
+			if m.contains(index+3, 2, "ER", "EN") {
+				m.addPair("X", "SK")
+			} else {
+				m.add("SK")
+			}
+		} else if index == 0 && !isVowel(m.at(3)) && m.at(3) != 'W' {
+			m.addPair("X", "S")
+		} else {
+			m.add("X")
+		}
+	case m.contains(index+2, 1, "I", "E", "Y"):
+		m.add("S")
+	default:
+		m.add("SK")
+	}
+	return index + 3
+}
+
+func (m *metaphone) handleT(index int) int {
+	switch {
+	case m.contains(index, 4, "TION"):
+		m.add("X")
+		return index + 3
+	case m.contains(index, 3, "TIA", "TCH"):
+		m.add("X")
+		return index + 3
+	case m.contains(index, 2, "TH") || m.contains(index, 3, "TTH"):
+		if m.contains(index+2, 2, "OM", "AM") || m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") {
+			m.add("T")
+		} else {
+			m.addPair("0", "T")
+		}
+		return index + 2
+	}
+
+	m.add("T")
+	if m.contains(index+1, 1, "T", "D") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleW(index int) int {
+	switch {
+	case m.contains(index, 2, "WR"):
+		m.add("R")
+		return index + 2
+	case index == 0 && (isVowel(m.at(index+1)) || m.contains(index, 2, "WH")):
+		if isVowel(m.at(index + 1)) {
+			m.addPair("A", "F")
+		} else {
+			m.add("A")
+		}
+		return index + 1
+	case (index == len(m.value)-1 && isVowel(m.at(index-1))) ||
+		m.contains(index-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.contains(0, 3, "SCH"):
+		// Polish W as in "Filipowicz"
+		m.addPair("", "F")
+		return index + 1
+	case m.contains(index, 4, "WICZ", "WITZ"):
+		m.addPair("TS", "FX")
+		return index + 4
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleX(index int) int {
+	if index == 0 {
+		m.add("S")
+		return index + 1
+	}
+	if !(index == len(m.value)-1 &&
+		(m.contains(index-3, 3, "IAU", "EAU") || m.contains(index-2, 2, "AU", "OU"))) {
+		// unless the silent French final X of "Breaux"
+		m.add("KS")
+	}
+	if m.contains(index+1, 1, "C", "X") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleZ(index int) int {
+	if m.at(index+1) == 'H' {
+		// Chinese pinyin as in "Zhao"
+		m.add("J")
+		return index + 2
+	}
+	if m.contains(index+1, 2, "ZO", "ZI", "ZA") || (m.slavoGermanic && index > 0 && m.at(index-1) != 'T') {
+		m.addPair("S", "TS")
+	} else {
+		m.add("S")
+	}
+	return m.skip(index, 'Z')
+}
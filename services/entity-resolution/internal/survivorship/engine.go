@@ -0,0 +1,227 @@
+package survivorship
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+)
+
+// Survivorship strategies
+const (
+	// StrategyMostRecent keeps the most recently reported value
+	StrategyMostRecent = "most_recent"
+	// StrategyMostTrustedSource keeps the value reported by the most trusted source
+	StrategyMostTrustedSource = "most_trusted_source"
+	// StrategyLongest keeps the longest value, e.g. the most complete address
+	StrategyLongest = "longest"
+	// StrategyMostFrequent keeps the value reported most often
+	StrategyMostFrequent = "most_frequent"
+)
+
+// anyEntityType matches every entity type in a rule key
+const anyEntityType = "*"
+
+// Observation is a value reported for an attribute
+type Observation struct {
+	Value      interface{}
+	SourceID   string
+	ObservedAt time.Time
+	Count      int
+}
+
+// Decision is the value that survives for an attribute and why
+type Decision struct {
+	Value        interface{} `json:"value"`
+	Strategy     string      `json:"strategy"`
+	SourceID     string      `json:"source_id,omitempty"`
+	ObservedAt   time.Time   `json:"observed_at"`
+	Observations int         `json:"observations"`
+	Candidates   int         `json:"candidates"`
+}
+
+// Policy is the effective survivorship configuration
+type Policy struct {
+	DefaultStrategy string             `json:"default_strategy"`
+	Rules           map[string]string  `json:"rules"`
+	SourceTrust     map[string]float64 `json:"source_trust"`
+}
+
+// Engine picks the surviving value of each attribute when entities merge
+type Engine struct {
+	policy Policy
+}
+
+// NewEngine creates a survivorship engine from configuration
+func NewEngine(cfg config.SurvivorshipConfig) *Engine {
+	policy := Policy{
+		DefaultStrategy: cfg.DefaultStrategy,
+		Rules:           make(map[string]string, len(cfg.Rules)),
+		SourceTrust:     make(map[string]float64, len(cfg.SourceTrust)),
+	}
+	if policy.DefaultStrategy == "" {
+		policy.DefaultStrategy = StrategyMostRecent
+	}
+	for key, strategy := range cfg.Rules {
+		policy.Rules[strings.ToLower(key)] = strategy
+	}
+	for source, trust := range cfg.SourceTrust {
+		policy.SourceTrust[source] = trust
+	}
+
+	return &Engine{policy: policy}
+}
+
+// Policy returns the effective survivorship configuration
+func (e *Engine) Policy() Policy {
+	return e.policy
+}
+
+// StrategyFor returns the strategy applied to an attribute of an entity
+// type. A rule for the entity type wins over a "*" rule, which wins over
+// the default.
+func (e *Engine) StrategyFor(entityType, attribute string) string {
+	attribute = strings.ToLower(attribute)
+	if strategy, ok := e.policy.Rules[strings.ToLower(entityType)+"."+attribute]; ok {
+		return strategy
+	}
+	if strategy, ok := e.policy.Rules[anyEntityType+"."+attribute]; ok {
+		return strategy
+	}
+	return e.policy.DefaultStrategy
+}
+
+// Trust returns how trusted a source is; unknown sources have no trust
+func (e *Engine) Trust(sourceID string) float64 {
+	return e.policy.SourceTrust[sourceID]
+}
+
+// Resolve picks the surviving value among an attribute's observations.
+// Observations of the same value are pooled. Ties on the strategy are
+// broken by recency, then source trust, then frequency, so the outcome does
+// not depend on observation order.
+func (e *Engine) Resolve(entityType, attribute string, observations []Observation) (*Decision, error) {
+	if len(observations) == 0 {
+		return nil, fmt.Errorf("no observations for attribute %s", attribute)
+	}
+
+	strategy := e.StrategyFor(entityType, attribute)
+
+	candidates, err := e.pool(observations)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch strategy {
+		case StrategyMostTrustedSource:
+			if a.trust != b.trust {
+				return a.trust > b.trust
+			}
+		case StrategyLongest:
+			if a.length != b.length {
+				return a.length > b.length
+			}
+		case StrategyMostFrequent:
+			if a.count != b.count {
+				return a.count > b.count
+			}
+		}
+		if !a.lastSeen.Equal(b.lastSeen) {
+			return a.lastSeen.After(b.lastSeen)
+		}
+		if a.trust != b.trust {
+			return a.trust > b.trust
+		}
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		return a.key < b.key
+	})
+
+	winner := candidates[0]
+	source := winner.latestSource
+	if strategy == StrategyMostTrustedSource {
+		source = winner.trustedSource
+	}
+
+	return &Decision{
+		Value:        winner.value,
+		Strategy:     strategy,
+		SourceID:     source,
+		ObservedAt:   winner.lastSeen,
+		Observations: winner.count,
+		Candidates:   len(candidates),
+	}, nil
+}
+
+// candidate is a distinct value pooled across its observations
+type candidate struct {
+	key           string
+	value         interface{}
+	count         int
+	length        int
+	lastSeen      time.Time
+	latestSource  string
+	trust         float64
+	trustedSource string
+}
+
+func (e *Engine) pool(observations []Observation) ([]*candidate, error) {
+	byKey := make(map[string]*candidate)
+	var candidates []*candidate
+
+	for _, observation := range observations {
+		encoded, err := json.Marshal(observation.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode observed value: %w", err)
+		}
+		key := string(encoded)
+
+		c, ok := byKey[key]
+		if !ok {
+			c = &candidate{
+				key:           key,
+				value:         observation.Value,
+				length:        valueLength(observation.Value, key),
+				trust:         -1,
+				lastSeen:      observation.ObservedAt,
+				latestSource:  observation.SourceID,
+				trustedSource: observation.SourceID,
+			}
+			byKey[key] = c
+			candidates = append(candidates, c)
+		}
+
+		count := observation.Count
+		if count <= 0 {
+			count = 1
+		}
+		c.count += count
+
+		if observation.ObservedAt.After(c.lastSeen) {
+			c.lastSeen = observation.ObservedAt
+			c.latestSource = observation.SourceID
+		}
+		if trust := e.Trust(observation.SourceID); trust > c.trust {
+			c.trust = trust
+			c.trustedSource = observation.SourceID
+		}
+	}
+
+	return candidates, nil
+}
+
+// valueLength measures strings by characters and other values by their
+// JSON encoding
+func valueLength(value interface{}, encoded string) int {
+	if s, ok := value.(string); ok {
+		return utf8.RuneCountInString(strings.TrimSpace(s))
+	}
+	return len(encoded)
+}
@@ -0,0 +1,239 @@
+package survivorship
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/google/uuid"
+)
+
+// Store persists attribute observations and the provenance of surviving values
+type Store interface {
+	RecordAttributeObservations(ctx context.Context, observations []*database.AttributeObservation) error
+	ListAttributeObservations(ctx context.Context, entityID uuid.UUID) ([]*database.AttributeObservation, error)
+	SaveAttributeProvenance(ctx context.Context, provenance []*database.AttributeProvenance) error
+	ListAttributeProvenance(ctx context.Context, entityID uuid.UUID) ([]*database.AttributeProvenance, error)
+}
+
+// MergeRequest merges newly reported data into an entity's golden record
+type MergeRequest struct {
+	EntityID         uuid.UUID
+	EntityType       string
+	Current          database.EntityFields
+	CurrentUpdatedAt time.Time // when values without observations were last set
+	Incoming         database.EntityFields
+	SourceID         string
+	ObservedAt       time.Time
+}
+
+// MergeResult is the golden record after a merge and the provenance of each
+// value decided by it
+type MergeResult struct {
+	Fields     database.EntityFields           `json:"fields"`
+	Provenance []*database.AttributeProvenance `json:"provenance"`
+}
+
+// Service maintains golden records: every reported value is kept as an
+// observation and each attribute's surviving value is chosen by the
+// configured strategy, with its provenance recorded.
+type Service struct {
+	store  Store
+	engine *Engine
+	logger *slog.Logger
+}
+
+// NewService creates a new survivorship service
+func NewService(store Store, engine *Engine, logger *slog.Logger) *Service {
+	return &Service{
+		store:  store,
+		engine: engine,
+		logger: logger,
+	}
+}
+
+// Policy returns the effective survivorship configuration
+func (s *Service) Policy() Policy {
+	return s.engine.Policy()
+}
+
+// Provenance returns the provenance of an entity's golden-record values
+func (s *Service) Provenance(ctx context.Context, entityID uuid.UUID) ([]*database.AttributeProvenance, error) {
+	return s.store.ListAttributeProvenance(ctx, entityID)
+}
+
+// Merge records the incoming values and re-decides every attribute they
+// touch. Values already on the entity that predate survivorship are first
+// recorded as observations from an unknown source so they compete fairly.
+func (s *Service) Merge(ctx context.Context, req *MergeRequest) (*MergeResult, error) {
+	existing, err := s.store.ListAttributeObservations(ctx, req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+
+	observed := make(map[attributeKey]bool, len(existing))
+	for _, observation := range existing {
+		observed[attributeKey{observation.Field, observation.Attribute}] = true
+	}
+
+	var batch []*database.AttributeObservation
+	touched := make(map[attributeKey]bool)
+
+	for _, field := range []string{database.FieldIdentifiers, database.FieldAttributes} {
+		for attribute, value := range fieldValues(req.Current, field) {
+			key := attributeKey{field, attribute}
+			if observed[key] || value == nil {
+				continue
+			}
+			observation, err := newObservation(req.EntityID, key, value, "", req.CurrentUpdatedAt)
+			if err != nil {
+				return nil, err
+			}
+			batch = append(batch, observation)
+			touched[key] = true
+		}
+
+		for attribute, value := range fieldValues(req.Incoming, field) {
+			if value == nil {
+				continue
+			}
+			key := attributeKey{field, attribute}
+			observation, err := newObservation(req.EntityID, key, value, req.SourceID, req.ObservedAt)
+			if err != nil {
+				return nil, err
+			}
+			batch = append(batch, observation)
+			touched[key] = true
+		}
+	}
+
+	if err := s.store.RecordAttributeObservations(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	byAttribute := make(map[attributeKey][]Observation)
+	for _, observation := range append(existing, batch...) {
+		key := attributeKey{observation.Field, observation.Attribute}
+		if !touched[key] {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(observation.Value, &value); err != nil {
+			return nil, fmt.Errorf("failed to decode observed %s value: %w", observation.Attribute, err)
+		}
+		byAttribute[key] = append(byAttribute[key], Observation{
+			Value:      value,
+			SourceID:   observation.SourceID,
+			ObservedAt: observation.LastSeenAt,
+			Count:      observation.ObservationCount,
+		})
+	}
+
+	result := &MergeResult{
+		Fields: database.EntityFields{
+			Identifiers: copyValues(req.Current.Identifiers),
+			Attributes:  copyValues(req.Current.Attributes),
+		},
+	}
+
+	now := time.Now()
+	for _, key := range sortedKeys(byAttribute) {
+		decision, err := s.engine.Resolve(req.EntityType, key.attribute, byAttribute[key])
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(decision.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode surviving %s value: %w", key.attribute, err)
+		}
+
+		if key.field == database.FieldIdentifiers {
+			result.Fields.Identifiers[key.attribute] = decision.Value
+		} else {
+			result.Fields.Attributes[key.attribute] = decision.Value
+		}
+
+		result.Provenance = append(result.Provenance, &database.AttributeProvenance{
+			EntityID:         req.EntityID,
+			Field:            key.field,
+			Attribute:        key.attribute,
+			Value:            value,
+			Strategy:         decision.Strategy,
+			SourceID:         decision.SourceID,
+			ObservedAt:       decision.ObservedAt,
+			ObservationCount: decision.Observations,
+			CandidateCount:   decision.Candidates,
+			DecidedAt:        now,
+		})
+	}
+
+	if err := s.store.SaveAttributeProvenance(ctx, result.Provenance); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug("Applied survivorship",
+		"entity_id", req.EntityID,
+		"source_id", req.SourceID,
+		"observations", len(batch),
+		"decided", len(result.Provenance))
+
+	return result, nil
+}
+
+// attributeKey identifies an attribute within an entity field
+type attributeKey struct {
+	field     string
+	attribute string
+}
+
+func newObservation(entityID uuid.UUID, key attributeKey, value interface{}, sourceID string, observedAt time.Time) (*database.AttributeObservation, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s value: %w", key.attribute, err)
+	}
+
+	return &database.AttributeObservation{
+		EntityID:         entityID,
+		Field:            key.field,
+		Attribute:        key.attribute,
+		Value:            encoded,
+		SourceID:         sourceID,
+		ObservationCount: 1,
+		FirstSeenAt:      observedAt,
+		LastSeenAt:       observedAt,
+	}, nil
+}
+
+func fieldValues(fields database.EntityFields, field string) map[string]interface{} {
+	if field == database.FieldIdentifiers {
+		return fields.Identifiers
+	}
+	return fields.Attributes
+}
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
+
+func sortedKeys(byAttribute map[attributeKey][]Observation) []attributeKey {
+	keys := make([]attributeKey, 0, len(byAttribute))
+	for key := range byAttribute {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].field != keys[j].field {
+			return keys[i].field < keys[j].field
+		}
+		return keys[i].attribute < keys[j].attribute
+	})
+	return keys
+}
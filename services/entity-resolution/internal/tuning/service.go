@@ -0,0 +1,426 @@
+package tuning
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/matching"
+	"github.com/google/uuid"
+)
+
+// ErrValidation is returned when a tuning request is rejected
+var ErrValidation = errors.New("invalid tuning request")
+
+// Screener scores names under every algorithm and holds the name screening
+// settings tuning applies
+type Screener interface {
+	NameScores(name1, name2 string) matching.NameScores
+	NameSettings() matching.NameSettings
+	ApplyNameSettings(settings matching.NameSettings) error
+}
+
+// CorpusRequest uploads a labeled test corpus of name pairs
+type CorpusRequest struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description,omitempty"`
+	Pairs       []database.TuningPair `json:"pairs"`
+	UploadedBy  string                `json:"uploaded_by"`
+}
+
+// RunRequest evaluates a threshold sweep across algorithm combinations. No
+// combinations means every combination of every algorithm; a minimum
+// precision picks the best point by recall instead of F1.
+type RunRequest struct {
+	CorpusID     string     `json:"corpus_id"`
+	Sweep        Sweep      `json:"sweep"`
+	Combinations [][]string `json:"combinations,omitempty"`
+	MinPrecision float64    `json:"min_precision,omitempty"`
+	CreatedBy    string     `json:"created_by"`
+}
+
+// RunResult is a tuning run with its evaluated points decoded
+type RunResult struct {
+	*database.TuningRun
+	Best   *Point   `json:"best"`
+	Points []*Point `json:"points,omitempty"`
+}
+
+// ApplyRequest applies a point evaluated by a run to name screening. Without
+// a threshold and algorithms the run's best point is applied.
+type ApplyRequest struct {
+	Threshold  *float64 `json:"threshold,omitempty"`
+	Algorithms []string `json:"algorithms,omitempty"`
+	AppliedBy  string   `json:"applied_by"`
+	Notes      string   `json:"notes,omitempty"`
+}
+
+// ActiveSettings describes the name screening settings in effect and the
+// tuned threshold they came from, if any
+type ActiveSettings struct {
+	Settings matching.NameSettings        `json:"settings"`
+	Applied  *database.ScreeningThreshold `json:"applied,omitempty"`
+}
+
+// Service evaluates name screening thresholds against labeled corpora and
+// applies the chosen threshold to the matching engine
+type Service struct {
+	db       *database.Repository
+	screener Screener
+	config   config.TuningConfig
+	logger   *slog.Logger
+
+	// applyMu keeps the stored active threshold and the engine's settings
+	// in the same order when thresholds are applied concurrently
+	applyMu sync.Mutex
+}
+
+// NewService creates a new screening threshold tuning service
+func NewService(db *database.Repository, screener Screener, config config.TuningConfig, logger *slog.Logger) *Service {
+	return &Service{
+		db:       db,
+		screener: screener,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// LoadActiveSettings applies the last applied screening threshold to the
+// matching engine; without one the configured settings stay in effect
+func (s *Service) LoadActiveSettings(ctx context.Context) error {
+	active, err := s.db.GetActiveScreeningThreshold(ctx)
+	if err != nil {
+		return err
+	}
+
+	if active == nil {
+		s.logger.Info("No tuned screening threshold, using configured name matching settings")
+		return nil
+	}
+
+	if err := s.screener.ApplyNameSettings(matching.NameSettings{
+		Threshold:  active.Threshold,
+		Algorithms: active.Algorithms,
+	}); err != nil {
+		return fmt.Errorf("failed to apply screening threshold %s: %w", active.ID, err)
+	}
+
+	s.logger.Info("Loaded screening threshold",
+		"threshold_id", active.ID,
+		"threshold", active.Threshold,
+		"algorithms", active.Algorithms)
+
+	return nil
+}
+
+// UploadCorpus validates and stores a labeled test corpus
+func (s *Service) UploadCorpus(ctx context.Context, req *CorpusRequest) (*database.TuningCorpus, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrValidation)
+	}
+	if len(req.Pairs) == 0 {
+		return nil, fmt.Errorf("%w: at least one pair is required", ErrValidation)
+	}
+	if len(req.Pairs) > s.config.MaxCorpusPairs {
+		return nil, fmt.Errorf("%w: corpus has %d pairs, at most %d are allowed", ErrValidation, len(req.Pairs), s.config.MaxCorpusPairs)
+	}
+
+	positives := 0
+	for i, pair := range req.Pairs {
+		if strings.TrimSpace(pair.NameA) == "" || strings.TrimSpace(pair.NameB) == "" {
+			return nil, fmt.Errorf("%w: pair %d is missing a name", ErrValidation, i)
+		}
+		if pair.IsMatch {
+			positives++
+		}
+	}
+	if positives == 0 || positives == len(req.Pairs) {
+		return nil, fmt.Errorf("%w: corpus needs both matching and non-matching pairs", ErrValidation)
+	}
+
+	corpus := &database.TuningCorpus{
+		ID:            uuid.New(),
+		Name:          req.Name,
+		Description:   req.Description,
+		PairCount:     len(req.Pairs),
+		PositiveCount: positives,
+		UploadedBy:    req.UploadedBy,
+		CreatedAt:     time.Now(),
+	}
+
+	if err := s.db.CreateTuningCorpus(ctx, corpus, req.Pairs); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Tuning corpus uploaded",
+		"corpus_id", corpus.ID,
+		"pair_count", corpus.PairCount,
+		"positive_count", corpus.PositiveCount)
+
+	return corpus, nil
+}
+
+// GetCorpus retrieves a tuning corpus
+func (s *Service) GetCorpus(ctx context.Context, id uuid.UUID) (*database.TuningCorpus, error) {
+	return s.db.GetTuningCorpus(ctx, id)
+}
+
+// ListCorpora retrieves tuning corpora, newest first
+func (s *Service) ListCorpora(ctx context.Context, limit int) ([]*database.TuningCorpus, error) {
+	return s.db.ListTuningCorpora(ctx, limit)
+}
+
+// Run scores a corpus under every algorithm once, evaluates precision and
+// recall at each threshold of the sweep for each combination and stores the
+// run
+func (s *Service) Run(ctx context.Context, req *RunRequest) (*RunResult, error) {
+	corpusID, err := uuid.Parse(req.CorpusID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid corpus_id", ErrValidation)
+	}
+	if req.MinPrecision < 0 || req.MinPrecision > 1 {
+		return nil, fmt.Errorf("%w: min_precision must be between 0 and 1", ErrValidation)
+	}
+
+	sweep := req.Sweep
+	if sweep.Step == 0 {
+		sweep.Step = s.config.DefaultStep
+	}
+	if sweep.From == 0 && sweep.To == 0 {
+		sweep.From, sweep.To = sweep.Step, 1
+	}
+	thresholds, err := sweep.Thresholds()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+
+	combinations, err := NormalizeCombinations(req.Combinations)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+	if points := len(thresholds) * len(combinations); points > s.config.MaxSweepPoints {
+		return nil, fmt.Errorf("%w: sweep evaluates %d points, at most %d are allowed", ErrValidation, points, s.config.MaxSweepPoints)
+	}
+
+	if _, err := s.db.GetTuningCorpus(ctx, corpusID); err != nil {
+		return nil, err
+	}
+	pairs, err := s.db.ListTuningPairs(ctx, corpusID)
+	if err != nil {
+		return nil, err
+	}
+
+	scored := make([]ScoredPair, len(pairs))
+	for i, pair := range pairs {
+		if i%1000 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		scored[i] = ScoredPair{
+			Scores:  s.screener.NameScores(pair.NameA, pair.NameB),
+			IsMatch: pair.IsMatch,
+		}
+	}
+
+	points := Evaluate(scored, combinations, thresholds)
+	best := Best(points, req.MinPrecision)
+
+	encodedCombinations, err := json.Marshal(combinations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal combinations: %w", err)
+	}
+	encodedBest, err := json.Marshal(best)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal best point: %w", err)
+	}
+	encodedPoints, err := json.Marshal(points)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal points: %w", err)
+	}
+
+	run := &database.TuningRun{
+		ID:            uuid.New(),
+		CorpusID:      corpusID,
+		ThresholdFrom: sweep.From,
+		ThresholdTo:   sweep.To,
+		ThresholdStep: sweep.Step,
+		Combinations:  encodedCombinations,
+		MinPrecision:  req.MinPrecision,
+		PairCount:     len(pairs),
+		PointCount:    len(points),
+		Best:          encodedBest,
+		Results:       encodedPoints,
+		CreatedBy:     req.CreatedBy,
+		CreatedAt:     time.Now(),
+	}
+
+	if err := s.db.CreateTuningRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	logArgs := []any{"run_id", run.ID, "corpus_id", corpusID, "pairs", len(pairs), "points", len(points)}
+	if best != nil {
+		logArgs = append(logArgs,
+			"best_threshold", best.Threshold,
+			"best_algorithms", best.Algorithms,
+			"precision", best.Precision,
+			"recall", best.Recall)
+	}
+	s.logger.Info("Tuning run evaluated", logArgs...)
+
+	run.Results = nil
+	return &RunResult{TuningRun: run, Best: best, Points: points}, nil
+}
+
+// GetRun retrieves a tuning run with its evaluated points
+func (s *Service) GetRun(ctx context.Context, id uuid.UUID) (*RunResult, error) {
+	run, err := s.db.GetTuningRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRun(run)
+}
+
+// ListRuns retrieves tuning runs without their points, newest first
+func (s *Service) ListRuns(ctx context.Context, corpusID *uuid.UUID, limit int) ([]*RunResult, error) {
+	runs, err := s.db.ListTuningRuns(ctx, corpusID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*RunResult, len(runs))
+	for i, run := range runs {
+		if results[i], err = decodeRun(run); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// Apply records a point evaluated by a run as the active screening threshold
+// and swaps it into the matching engine. Only evaluated points may be
+// applied, so the applied threshold always has measured precision and
+// recall. The engine is only updated once the threshold has been stored.
+func (s *Service) Apply(ctx context.Context, runID uuid.UUID, req *ApplyRequest) (*database.ScreeningThreshold, error) {
+	if strings.TrimSpace(req.AppliedBy) == "" {
+		return nil, fmt.Errorf("%w: applied_by is required", ErrValidation)
+	}
+
+	run, err := s.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	point := run.Best
+	if req.Threshold != nil || len(req.Algorithms) > 0 {
+		if req.Threshold == nil || len(req.Algorithms) == 0 {
+			return nil, fmt.Errorf("%w: threshold and algorithms must be given together", ErrValidation)
+		}
+		algorithms, err := matching.NormalizeNameAlgorithms(req.Algorithms)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrValidation, err)
+		}
+		point = Find(run.Points, algorithms, *req.Threshold)
+		if point == nil {
+			return nil, fmt.Errorf("%w: run %s did not evaluate threshold %g with %s", ErrValidation, runID, *req.Threshold, strings.Join(algorithms, "+"))
+		}
+	}
+	if point == nil {
+		return nil, fmt.Errorf("%w: run %s has no point meeting its minimum precision", ErrValidation, runID)
+	}
+
+	settings := matching.NameSettings{Threshold: point.Threshold, Algorithms: point.Algorithms}
+	if err := settings.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+
+	threshold := &database.ScreeningThreshold{
+		ID:         uuid.New(),
+		RunID:      &runID,
+		Threshold:  settings.Threshold,
+		Algorithms: settings.Algorithms,
+		Precision:  point.Precision,
+		Recall:     point.Recall,
+		F1:         point.F1,
+		AppliedBy:  req.AppliedBy,
+		Notes:      req.Notes,
+		AppliedAt:  time.Now(),
+	}
+
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	if err := s.db.ApplyScreeningThreshold(ctx, threshold); err != nil {
+		return nil, err
+	}
+	if err := s.screener.ApplyNameSettings(settings); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Screening threshold applied",
+		"threshold_id", threshold.ID,
+		"run_id", runID,
+		"threshold", threshold.Threshold,
+		"algorithms", threshold.Algorithms,
+		"applied_by", threshold.AppliedBy)
+
+	return threshold, nil
+}
+
+// Active returns the name screening settings in effect
+func (s *Service) Active(ctx context.Context) (*ActiveSettings, error) {
+	applied, err := s.db.GetActiveScreeningThreshold(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ActiveSettings{Settings: s.screener.NameSettings(), Applied: applied}, nil
+}
+
+// Comparison shows how a name pair scores under each algorithm and whether
+// the screening settings in effect flag it
+type Comparison struct {
+	NameA    string                `json:"name_a"`
+	NameB    string                `json:"name_b"`
+	Scores   matching.NameScores   `json:"scores"`
+	Settings matching.NameSettings `json:"settings"`
+	Score    float64               `json:"score"`
+	Flagged  bool                  `json:"flagged"`
+}
+
+// Compare scores a single name pair under the screening settings in effect
+func (s *Service) Compare(nameA, nameB string) *Comparison {
+	settings := s.screener.NameSettings()
+	scores := s.screener.NameScores(nameA, nameB)
+	score := matching.CombineNameScores(scores, settings.Algorithms)
+
+	return &Comparison{
+		NameA:    nameA,
+		NameB:    nameB,
+		Scores:   scores,
+		Settings: settings,
+		Score:    score,
+		Flagged:  score >= settings.Threshold,
+	}
+}
+
+func decodeRun(run *database.TuningRun) (*RunResult, error) {
+	result := &RunResult{TuningRun: run}
+	if len(run.Best) > 0 {
+		if err := json.Unmarshal(run.Best, &result.Best); err != nil {
+			return nil, fmt.Errorf("failed to decode best point of run %s: %w", run.ID, err)
+		}
+	}
+	if len(run.Results) > 0 {
+		if err := json.Unmarshal(run.Results, &result.Points); err != nil {
+			return nil, fmt.Errorf("failed to decode points of run %s: %w", run.ID, err)
+		}
+		run.Results = nil
+	}
+	return result, nil
+}
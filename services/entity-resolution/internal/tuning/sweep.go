@@ -0,0 +1,204 @@
+package tuning
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/aegisshield/entity-resolution/internal/matching"
+)
+
+// ErrInvalidSweep is returned when a threshold sweep cannot be evaluated
+var ErrInvalidSweep = errors.New("invalid threshold sweep")
+
+// ScoredPair is a labeled corpus pair with its score under every algorithm
+type ScoredPair struct {
+	Scores  matching.NameScores
+	IsMatch bool
+}
+
+// Sweep is an inclusive range of thresholds to evaluate
+type Sweep struct {
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+	Step float64 `json:"step"`
+}
+
+// Point is the screening outcome of one algorithm combination at one threshold
+type Point struct {
+	Algorithms     []string `json:"algorithms"`
+	Threshold      float64  `json:"threshold"`
+	TruePositives  int      `json:"true_positives"`
+	FalsePositives int      `json:"false_positives"`
+	FalseNegatives int      `json:"false_negatives"`
+	TrueNegatives  int      `json:"true_negatives"`
+	Precision      float64  `json:"precision"`
+	Recall         float64  `json:"recall"`
+	F1             float64  `json:"f1"`
+}
+
+// Thresholds expands a sweep into its thresholds, rounded to four decimals
+// so stored thresholds compare equal to the ones a reviewer picks
+func (s Sweep) Thresholds() ([]float64, error) {
+	if s.Step <= 0 || s.Step > 1 {
+		return nil, fmt.Errorf("%w: step must be greater than 0 and at most 1", ErrInvalidSweep)
+	}
+	if s.From <= 0 || s.To > 1 || s.From > s.To {
+		return nil, fmt.Errorf("%w: thresholds must satisfy 0 < from <= to <= 1", ErrInvalidSweep)
+	}
+
+	var thresholds []float64
+	for i := 0; ; i++ {
+		threshold := roundThreshold(s.From + float64(i)*s.Step)
+		if threshold > s.To+1e-9 {
+			break
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds, nil
+}
+
+func roundThreshold(threshold float64) float64 {
+	return math.Round(threshold*10000) / 10000
+}
+
+// Combinations returns every non-empty combination of the given algorithms,
+// each sorted, smallest combinations first
+func Combinations(algorithms []string) [][]string {
+	sorted := append([]string(nil), algorithms...)
+	sort.Strings(sorted)
+
+	var combinations [][]string
+	for mask := 1; mask < 1<<len(sorted); mask++ {
+		var combination []string
+		for i, algorithm := range sorted {
+			if mask&(1<<i) != 0 {
+				combination = append(combination, algorithm)
+			}
+		}
+		combinations = append(combinations, combination)
+	}
+
+	sort.SliceStable(combinations, func(i, j int) bool {
+		return len(combinations[i]) < len(combinations[j])
+	})
+	return combinations
+}
+
+// NormalizeCombinations validates and deduplicates algorithm combinations.
+// No combinations means every combination of every algorithm.
+func NormalizeCombinations(combinations [][]string) ([][]string, error) {
+	if len(combinations) == 0 {
+		return Combinations(matching.NameAlgorithms), nil
+	}
+
+	seen := make(map[string]bool, len(combinations))
+	var normalized [][]string
+	for _, combination := range combinations {
+		algorithms, err := matching.NormalizeNameAlgorithms(combination)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.Join(algorithms, "+")
+		if !seen[key] {
+			seen[key] = true
+			normalized = append(normalized, algorithms)
+		}
+	}
+	return normalized, nil
+}
+
+// Evaluate screens every pair under each algorithm combination at each
+// threshold. A pair is flagged when its best score among the combination's
+// algorithms reaches the threshold.
+func Evaluate(pairs []ScoredPair, combinations [][]string, thresholds []float64) []*Point {
+	points := make([]*Point, 0, len(combinations)*len(thresholds))
+	for _, algorithms := range combinations {
+		scores := make([]float64, len(pairs))
+		for i, pair := range pairs {
+			scores[i] = matching.CombineNameScores(pair.Scores, algorithms)
+		}
+
+		for _, threshold := range thresholds {
+			point := &Point{Algorithms: algorithms, Threshold: threshold}
+			for i, pair := range pairs {
+				flagged := scores[i] >= threshold
+				switch {
+				case flagged && pair.IsMatch:
+					point.TruePositives++
+				case flagged:
+					point.FalsePositives++
+				case pair.IsMatch:
+					point.FalseNegatives++
+				default:
+					point.TrueNegatives++
+				}
+			}
+			point.computeRates()
+			points = append(points, point)
+		}
+	}
+	return points
+}
+
+// computeRates derives precision, recall and F1 from the counts. A point that
+// flags nothing has zero precision rather than an undefined one.
+func (p *Point) computeRates() {
+	if flagged := p.TruePositives + p.FalsePositives; flagged > 0 {
+		p.Precision = float64(p.TruePositives) / float64(flagged)
+	}
+	if matches := p.TruePositives + p.FalseNegatives; matches > 0 {
+		p.Recall = float64(p.TruePositives) / float64(matches)
+	}
+	if p.Precision+p.Recall > 0 {
+		p.F1 = 2 * p.Precision * p.Recall / (p.Precision + p.Recall)
+	}
+}
+
+// Best picks the recommended point. With a minimum precision it is the point
+// with the highest recall among those meeting it; otherwise the point with
+// the highest F1. Ties go to higher precision, then the higher threshold,
+// then fewer algorithms. It returns nil when no point qualifies.
+func Best(points []*Point, minPrecision float64) *Point {
+	var best *Point
+	for _, point := range points {
+		if minPrecision > 0 && point.Precision < minPrecision {
+			continue
+		}
+		if best == nil || better(point, best, minPrecision > 0) {
+			best = point
+		}
+	}
+	return best
+}
+
+func better(a, b *Point, byRecall bool) bool {
+	primaryA, primaryB := a.F1, b.F1
+	if byRecall {
+		primaryA, primaryB = a.Recall, b.Recall
+	}
+	if primaryA != primaryB {
+		return primaryA > primaryB
+	}
+	if a.Precision != b.Precision {
+		return a.Precision > b.Precision
+	}
+	if a.Threshold != b.Threshold {
+		return a.Threshold > b.Threshold
+	}
+	return len(a.Algorithms) < len(b.Algorithms)
+}
+
+// Find returns the evaluated point for an algorithm combination and threshold
+func Find(points []*Point, algorithms []string, threshold float64) *Point {
+	key := strings.Join(algorithms, "+")
+	threshold = roundThreshold(threshold)
+	for _, point := range points {
+		if point.Threshold == threshold && strings.Join(point.Algorithms, "+") == key {
+			return point
+		}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+-- Drop trigger
+DROP TRIGGER IF EXISTS update_calibration_models_updated_at ON calibration_models;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_calibration_models_active;
+DROP INDEX IF EXISTS idx_calibration_models_fitted_at;
+DROP INDEX IF EXISTS idx_match_reviews_reviewed_at;
+DROP INDEX IF EXISTS idx_match_reviews_outcome;
+DROP INDEX IF EXISTS idx_match_reviews_candidate_entity_id;
+DROP INDEX IF EXISTS idx_match_reviews_source_entity_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS calibration_models;
+DROP TABLE IF EXISTS match_reviews;
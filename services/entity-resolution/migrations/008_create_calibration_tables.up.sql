@@ -0,0 +1,63 @@
+-- Create match_reviews table for storing reviewer outcomes on proposed matches
+CREATE TABLE IF NOT EXISTS match_reviews (
+    id UUID PRIMARY KEY,
+    source_entity_id UUID NOT NULL,
+    candidate_entity_id UUID NOT NULL,
+    raw_score DECIMAL(5,4) NOT NULL,
+    calibrated_score DECIMAL(5,4),
+    outcome VARCHAR(20) NOT NULL,
+    reviewer_id VARCHAR(255) NOT NULL,
+    notes TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Ensure valid outcome values
+    CONSTRAINT chk_match_reviews_outcome
+        CHECK (outcome IN ('accepted', 'rejected')),
+
+    -- Ensure valid scores
+    CONSTRAINT chk_match_reviews_raw_score
+        CHECK (raw_score >= 0.0 AND raw_score <= 1.0)
+);
+
+-- Create indexes for efficient querying
+CREATE INDEX IF NOT EXISTS idx_match_reviews_source_entity_id ON match_reviews(source_entity_id);
+CREATE INDEX IF NOT EXISTS idx_match_reviews_candidate_entity_id ON match_reviews(candidate_entity_id);
+CREATE INDEX IF NOT EXISTS idx_match_reviews_outcome ON match_reviews(outcome);
+CREATE INDEX IF NOT EXISTS idx_match_reviews_reviewed_at ON match_reviews(reviewed_at);
+
+-- Create calibration_models table for fitted score calibration curves
+CREATE TABLE IF NOT EXISTS calibration_models (
+    id UUID PRIMARY KEY,
+    method VARCHAR(20) NOT NULL,
+    parameters JSONB NOT NULL DEFAULT '{}',
+    sample_count INTEGER NOT NULL DEFAULT 0,
+    positive_count INTEGER NOT NULL DEFAULT 0,
+    brier_score DECIMAL(8,6),
+    log_loss DECIMAL(10,6),
+    is_active BOOLEAN NOT NULL DEFAULT false,
+    fitted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Ensure valid method values
+    CONSTRAINT chk_calibration_models_method
+        CHECK (method IN ('platt', 'isotonic')),
+
+    -- Ensure valid counts
+    CONSTRAINT chk_calibration_models_counts
+        CHECK (sample_count >= 0 AND positive_count >= 0 AND positive_count <= sample_count)
+);
+
+-- Create indexes for efficient querying
+CREATE INDEX IF NOT EXISTS idx_calibration_models_fitted_at ON calibration_models(fitted_at);
+
+-- Only one calibration model may be active at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calibration_models_active
+    ON calibration_models(is_active) WHERE is_active;
+
+-- Add trigger to automatically update updated_at timestamp
+CREATE TRIGGER update_calibration_models_updated_at
+    BEFORE UPDATE ON calibration_models
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
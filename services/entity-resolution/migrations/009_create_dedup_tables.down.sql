@@ -0,0 +1,21 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_merge_candidates_updated_at ON merge_candidates;
+DROP TRIGGER IF EXISTS update_dedup_runs_updated_at ON dedup_runs;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_merge_candidates_duplicate;
+DROP INDEX IF EXISTS idx_merge_candidates_review_queue;
+DROP INDEX IF EXISTS idx_merge_candidates_run_score;
+DROP INDEX IF EXISTS idx_dedup_runs_active;
+DROP INDEX IF EXISTS idx_dedup_runs_created_at;
+DROP INDEX IF EXISTS idx_dedup_runs_status;
+DROP INDEX IF EXISTS idx_entities_dedup_block;
+DROP INDEX IF EXISTS idx_entities_merged_into_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS merge_candidates;
+DROP TABLE IF EXISTS dedup_runs;
+
+-- Drop merge tracking columns
+ALTER TABLE entities DROP COLUMN IF EXISTS merged_at;
+ALTER TABLE entities DROP COLUMN IF EXISTS merged_into_id;
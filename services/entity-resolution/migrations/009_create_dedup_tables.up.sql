@@ -0,0 +1,101 @@
+-- Track entities absorbed into a surviving entity by a merge
+ALTER TABLE entities ADD COLUMN IF NOT EXISTS merged_into_id UUID REFERENCES entities(id);
+ALTER TABLE entities ADD COLUMN IF NOT EXISTS merged_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_entities_merged_into_id ON entities(merged_into_id);
+
+-- Blocking index used by the backlog scan at the default block key size
+CREATE INDEX IF NOT EXISTS idx_entities_dedup_block
+    ON entities(entity_type, LOWER(LEFT(standardized_name, 4)))
+    WHERE merged_into_id IS NULL;
+
+-- Create dedup_runs table for tracking backlog deduplication progress
+CREATE TABLE IF NOT EXISTS dedup_runs (
+    id UUID PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    entity_type VARCHAR(100),
+    block_key_size INTEGER NOT NULL,
+    auto_merge_threshold DECIMAL(5,4) NOT NULL,
+    review_threshold DECIMAL(5,4) NOT NULL,
+
+    -- Progress; the cursor is the last fully processed block so runs can resume
+    total_blocks INTEGER NOT NULL DEFAULT 0,
+    processed_blocks INTEGER NOT NULL DEFAULT 0,
+    skipped_blocks INTEGER NOT NULL DEFAULT 0,
+    entities_scanned BIGINT NOT NULL DEFAULT 0,
+    candidates_found INTEGER NOT NULL DEFAULT 0,
+    auto_merged INTEGER NOT NULL DEFAULT 0,
+    queued_for_review INTEGER NOT NULL DEFAULT 0,
+    cursor_entity_type VARCHAR(100),
+    cursor_block_key VARCHAR(100),
+
+    error_message TEXT,
+    created_by VARCHAR(255) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Ensure valid status values
+    CONSTRAINT chk_dedup_runs_status
+        CHECK (status IN ('pending', 'running', 'completed', 'failed', 'cancelled')),
+
+    -- Ensure valid counts
+    CONSTRAINT chk_dedup_runs_counts
+        CHECK (processed_blocks >= 0 AND skipped_blocks >= 0 AND entities_scanned >= 0)
+);
+
+-- Create indexes for efficient querying
+CREATE INDEX IF NOT EXISTS idx_dedup_runs_status ON dedup_runs(status);
+CREATE INDEX IF NOT EXISTS idx_dedup_runs_created_at ON dedup_runs(created_at);
+
+-- Only one backlog run may be active at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dedup_runs_active
+    ON dedup_runs((true)) WHERE status IN ('pending', 'running');
+
+-- Create merge_candidates table holding the ranked merge-candidate report
+CREATE TABLE IF NOT EXISTS merge_candidates (
+    id UUID PRIMARY KEY,
+    run_id UUID NOT NULL REFERENCES dedup_runs(id) ON DELETE CASCADE,
+    survivor_entity_id UUID NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+    duplicate_entity_id UUID NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+    entity_type VARCHAR(100) NOT NULL,
+    block_key VARCHAR(100) NOT NULL,
+    raw_score DECIMAL(5,4) NOT NULL,
+    calibrated_score DECIMAL(5,4) NOT NULL,
+    evidence JSONB DEFAULT '{}',
+    status VARCHAR(20) NOT NULL,
+    reviewer_id VARCHAR(255),
+    review_notes TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Ensure valid status values
+    CONSTRAINT chk_merge_candidates_status
+        CHECK (status IN ('auto_merged', 'pending_review', 'merged', 'rejected', 'superseded')),
+
+    -- Ensure no self-pairs
+    CONSTRAINT chk_merge_candidates_distinct
+        CHECK (survivor_entity_id != duplicate_entity_id),
+
+    CONSTRAINT uq_merge_candidates_pair
+        UNIQUE (run_id, survivor_entity_id, duplicate_entity_id)
+);
+
+-- Create indexes for efficient querying
+CREATE INDEX IF NOT EXISTS idx_merge_candidates_run_score ON merge_candidates(run_id, calibrated_score DESC);
+CREATE INDEX IF NOT EXISTS idx_merge_candidates_review_queue
+    ON merge_candidates(calibrated_score DESC) WHERE status = 'pending_review';
+CREATE INDEX IF NOT EXISTS idx_merge_candidates_duplicate ON merge_candidates(duplicate_entity_id);
+
+-- Add triggers to automatically update updated_at timestamp
+CREATE TRIGGER update_dedup_runs_updated_at
+    BEFORE UPDATE ON dedup_runs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_merge_candidates_updated_at
+    BEFORE UPDATE ON merge_candidates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,12 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_organization_relationships_updated_at ON organization_relationships;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_organization_relationships_child;
+DROP INDEX IF EXISTS idx_organization_relationships_parent;
+DROP INDEX IF EXISTS idx_entity_aliases_standardized_trgm;
+DROP INDEX IF EXISTS idx_entity_aliases_entity_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS organization_relationships;
+DROP TABLE IF EXISTS entity_aliases;
@@ -0,0 +1,46 @@
+-- Alternate names an entity is known by: DBA and trade names, former names, abbreviations
+CREATE TABLE IF NOT EXISTS entity_aliases (
+    id UUID PRIMARY KEY,
+    entity_id UUID NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+    alias VARCHAR(500) NOT NULL,
+    standardized_alias VARCHAR(500) NOT NULL,
+    alias_type VARCHAR(50) NOT NULL DEFAULT 'other',
+    valid_from DATE,
+    valid_to DATE,
+    source VARCHAR(255),
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (entity_id, standardized_alias)
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_aliases_entity_id ON entity_aliases(entity_id);
+CREATE INDEX IF NOT EXISTS idx_entity_aliases_standardized_trgm
+    ON entity_aliases USING GIN(standardized_alias gin_trgm_ops);
+
+-- Parent/subsidiary links between organizations
+CREATE TABLE IF NOT EXISTS organization_relationships (
+    id UUID PRIMARY KEY,
+    parent_entity_id UUID NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+    child_entity_id UUID NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+    relationship_type VARCHAR(50) NOT NULL DEFAULT 'subsidiary',
+    ownership_percentage DECIMAL(7,4),
+    valid_from DATE,
+    valid_to DATE,
+    source VARCHAR(255),
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (parent_entity_id, child_entity_id, relationship_type),
+    CHECK (parent_entity_id <> child_entity_id),
+    CHECK (ownership_percentage IS NULL OR (ownership_percentage > 0 AND ownership_percentage <= 100))
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_relationships_parent ON organization_relationships(parent_entity_id);
+CREATE INDEX IF NOT EXISTS idx_organization_relationships_child ON organization_relationships(child_entity_id);
+
+CREATE TRIGGER update_organization_relationships_updated_at
+    BEFORE UPDATE ON organization_relationships
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
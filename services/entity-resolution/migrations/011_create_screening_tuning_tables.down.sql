@@ -0,0 +1,12 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_screening_thresholds_active;
+DROP INDEX IF EXISTS idx_screening_thresholds_applied_at;
+DROP INDEX IF EXISTS idx_screening_tuning_runs_created_at;
+DROP INDEX IF EXISTS idx_screening_tuning_runs_corpus_id;
+DROP INDEX IF EXISTS idx_screening_tuning_corpora_created_at;
+
+-- Drop tables
+DROP TABLE IF EXISTS screening_thresholds;
+DROP TABLE IF EXISTS screening_tuning_runs;
+DROP TABLE IF EXISTS screening_tuning_pairs;
+DROP TABLE IF EXISTS screening_tuning_corpora;
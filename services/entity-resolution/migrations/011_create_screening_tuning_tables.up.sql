@@ -0,0 +1,80 @@
+-- Create screening_tuning_corpora table for uploaded labeled name pair corpora
+CREATE TABLE IF NOT EXISTS screening_tuning_corpora (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    pair_count INTEGER NOT NULL DEFAULT 0,
+    positive_count INTEGER NOT NULL DEFAULT 0,
+    uploaded_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Ensure valid counts
+    CONSTRAINT chk_screening_tuning_corpora_counts
+        CHECK (pair_count >= 0 AND positive_count >= 0 AND positive_count <= pair_count)
+);
+
+CREATE INDEX IF NOT EXISTS idx_screening_tuning_corpora_created_at ON screening_tuning_corpora(created_at);
+
+-- Create screening_tuning_pairs table holding each corpus's labeled name pairs
+CREATE TABLE IF NOT EXISTS screening_tuning_pairs (
+    corpus_id UUID NOT NULL REFERENCES screening_tuning_corpora(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    name_a TEXT NOT NULL,
+    name_b TEXT NOT NULL,
+    is_match BOOLEAN NOT NULL,
+
+    PRIMARY KEY (corpus_id, position)
+);
+
+-- Create screening_tuning_runs table for evaluated threshold sweeps
+CREATE TABLE IF NOT EXISTS screening_tuning_runs (
+    id UUID PRIMARY KEY,
+    corpus_id UUID NOT NULL REFERENCES screening_tuning_corpora(id) ON DELETE CASCADE,
+    threshold_from DECIMAL(5,4) NOT NULL,
+    threshold_to DECIMAL(5,4) NOT NULL,
+    threshold_step DECIMAL(5,4) NOT NULL,
+    combinations JSONB NOT NULL DEFAULT '[]',
+    min_precision DECIMAL(5,4) NOT NULL DEFAULT 0,
+    pair_count INTEGER NOT NULL DEFAULT 0,
+    point_count INTEGER NOT NULL DEFAULT 0,
+    best JSONB NOT NULL DEFAULT 'null',
+    results JSONB NOT NULL DEFAULT '[]',
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Ensure a valid sweep range
+    CONSTRAINT chk_screening_tuning_runs_sweep
+        CHECK (threshold_from > 0 AND threshold_from <= threshold_to AND threshold_to <= 1 AND threshold_step > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_screening_tuning_runs_corpus_id ON screening_tuning_runs(corpus_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_screening_tuning_runs_created_at ON screening_tuning_runs(created_at);
+
+-- Create screening_thresholds table for thresholds applied to name screening
+CREATE TABLE IF NOT EXISTS screening_thresholds (
+    id UUID PRIMARY KEY,
+    run_id UUID REFERENCES screening_tuning_runs(id) ON DELETE SET NULL,
+    threshold DECIMAL(5,4) NOT NULL,
+    algorithms TEXT[] NOT NULL,
+    precision DECIMAL(5,4) NOT NULL DEFAULT 0,
+    recall DECIMAL(5,4) NOT NULL DEFAULT 0,
+    f1 DECIMAL(5,4) NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT false,
+    applied_by VARCHAR(255) NOT NULL,
+    notes TEXT,
+    applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Ensure valid threshold values
+    CONSTRAINT chk_screening_thresholds_threshold
+        CHECK (threshold > 0.0 AND threshold <= 1.0),
+
+    -- Ensure at least one algorithm
+    CONSTRAINT chk_screening_thresholds_algorithms
+        CHECK (cardinality(algorithms) > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_screening_thresholds_applied_at ON screening_thresholds(applied_at);
+
+-- Only one screening threshold may be active at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_screening_thresholds_active
+    ON screening_thresholds(is_active) WHERE is_active;
@@ -0,0 +1,12 @@
+-- Drop trigger
+DROP TRIGGER IF EXISTS update_merge_reviews_updated_at ON merge_reviews;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_merge_reviews_pending_pair;
+DROP INDEX IF EXISTS idx_merge_reviews_duplicate;
+DROP INDEX IF EXISTS idx_merge_reviews_survivor;
+DROP INDEX IF EXISTS idx_merge_reviews_status;
+DROP INDEX IF EXISTS idx_merge_reviews_queue;
+
+-- Drop table
+DROP TABLE IF EXISTS merge_reviews;
@@ -0,0 +1,54 @@
+-- Create merge_reviews table holding low-confidence merges from live
+-- resolution until an investigator approves or rejects them
+CREATE TABLE IF NOT EXISTS merge_reviews (
+    id UUID PRIMARY KEY,
+    survivor_entity_id UUID NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+    duplicate_entity_id UUID NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+    entity_type VARCHAR(100) NOT NULL,
+    raw_score DECIMAL(5,4) NOT NULL,
+    calibrated_score DECIMAL(5,4) NOT NULL,
+    evidence JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewer_id VARCHAR(255),
+    review_notes TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+
+    -- Both entities as they were before an approved merge, so it can be split
+    survivor_snapshot JSONB,
+    duplicate_snapshot JSONB,
+    split_by VARCHAR(255),
+    split_reason TEXT,
+    split_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Ensure valid status values
+    CONSTRAINT chk_merge_reviews_status
+        CHECK (status IN ('pending', 'approved', 'rejected', 'split', 'superseded')),
+
+    -- Ensure no self-pairs
+    CONSTRAINT chk_merge_reviews_distinct
+        CHECK (survivor_entity_id != duplicate_entity_id),
+
+    -- Applied merges keep the snapshots a split restores
+    CONSTRAINT chk_merge_reviews_snapshots
+        CHECK (status NOT IN ('approved', 'split') OR (survivor_snapshot IS NOT NULL AND duplicate_snapshot IS NOT NULL))
+);
+
+-- Create indexes for efficient querying
+CREATE INDEX IF NOT EXISTS idx_merge_reviews_queue
+    ON merge_reviews(calibrated_score DESC, created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_merge_reviews_status ON merge_reviews(status);
+CREATE INDEX IF NOT EXISTS idx_merge_reviews_survivor ON merge_reviews(survivor_entity_id);
+CREATE INDEX IF NOT EXISTS idx_merge_reviews_duplicate ON merge_reviews(duplicate_entity_id);
+
+-- A pair is queued at most once at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merge_reviews_pending_pair
+    ON merge_reviews(survivor_entity_id, duplicate_entity_id) WHERE status = 'pending';
+
+-- Add trigger to automatically update updated_at timestamp
+CREATE TRIGGER update_merge_reviews_updated_at
+    BEFORE UPDATE ON merge_reviews
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
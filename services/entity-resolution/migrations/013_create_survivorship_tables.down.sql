@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_entity_attribute_provenance_source;
+DROP INDEX IF EXISTS idx_entity_attribute_observations_source;
+
+-- Drop tables
+DROP TABLE IF EXISTS entity_attribute_provenance;
+DROP TABLE IF EXISTS entity_attribute_observations;
@@ -0,0 +1,52 @@
+-- Create entity_attribute_observations table recording every value each
+-- source has reported for an entity attribute
+CREATE TABLE IF NOT EXISTS entity_attribute_observations (
+    entity_id UUID NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+    field VARCHAR(20) NOT NULL,
+    attribute VARCHAR(255) NOT NULL,
+    value JSONB NOT NULL,
+    value_hash TEXT GENERATED ALWAYS AS (md5(value::text)) STORED,
+    source_id VARCHAR(255) NOT NULL DEFAULT '',
+    observation_count INTEGER NOT NULL DEFAULT 1,
+    first_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (entity_id, field, attribute, value_hash, source_id),
+
+    -- Ensure valid field
+    CONSTRAINT chk_entity_attribute_observations_field
+        CHECK (field IN ('identifiers', 'attributes')),
+
+    -- Ensure valid counts
+    CONSTRAINT chk_entity_attribute_observations_count
+        CHECK (observation_count > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_attribute_observations_source ON entity_attribute_observations(source_id);
+
+-- Create entity_attribute_provenance table recording why each golden-record
+-- value survived
+CREATE TABLE IF NOT EXISTS entity_attribute_provenance (
+    entity_id UUID NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+    field VARCHAR(20) NOT NULL,
+    attribute VARCHAR(255) NOT NULL,
+    value JSONB NOT NULL,
+    strategy VARCHAR(50) NOT NULL,
+    source_id VARCHAR(255) NOT NULL DEFAULT '',
+    observed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    observation_count INTEGER NOT NULL DEFAULT 1,
+    candidate_count INTEGER NOT NULL DEFAULT 1,
+    decided_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (entity_id, field, attribute),
+
+    -- Ensure valid field
+    CONSTRAINT chk_entity_attribute_provenance_field
+        CHECK (field IN ('identifiers', 'attributes')),
+
+    -- Ensure valid strategy
+    CONSTRAINT chk_entity_attribute_provenance_strategy
+        CHECK (strategy IN ('most_recent', 'most_trusted_source', 'longest', 'most_frequent'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_attribute_provenance_source ON entity_attribute_provenance(source_id);
@@ -0,0 +1,8 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_screening_hits_list;
+DROP INDEX IF EXISTS idx_screening_hits_status;
+DROP INDEX IF EXISTS idx_screening_hits_entry;
+
+-- Drop tables
+DROP TABLE IF EXISTS screening_hits;
+DROP TABLE IF EXISTS screening_lists;
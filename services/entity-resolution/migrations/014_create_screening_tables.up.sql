@@ -0,0 +1,63 @@
+-- Create screening_lists table holding sanctions and PEP lists uploaded
+-- through the API; configured file and URL lists are read from their source
+CREATE TABLE IF NOT EXISTS screening_lists (
+    name VARCHAR(100) PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    content BYTEA NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    entry_count INTEGER NOT NULL DEFAULT 0,
+    uploaded_by VARCHAR(255) NOT NULL,
+    uploaded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Ensure valid kind
+    CONSTRAINT chk_screening_lists_kind
+        CHECK (kind IN ('sanctions', 'pep')),
+
+    -- Ensure valid format
+    CONSTRAINT chk_screening_lists_format
+        CHECK (format IN ('csv', 'json')),
+
+    -- Ensure valid counts
+    CONSTRAINT chk_screening_lists_entry_count
+        CHECK (entry_count >= 0)
+);
+
+-- Create screening_hits table recording resolved entities matching a list
+-- entry. An entity hits each list entry at most once; screening it again
+-- refreshes the score.
+CREATE TABLE IF NOT EXISTS screening_hits (
+    id UUID PRIMARY KEY,
+    entity_id UUID NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+    entity_type VARCHAR(100) NOT NULL,
+    entity_name TEXT NOT NULL,
+    list_name VARCHAR(100) NOT NULL,
+    list_kind VARCHAR(20) NOT NULL,
+    entry_id VARCHAR(255) NOT NULL,
+    entry_name TEXT NOT NULL,
+    matched_name TEXT NOT NULL,
+    score DECIMAL(5,4) NOT NULL,
+    evidence JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    reviewer_id VARCHAR(255),
+    review_notes TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    first_screened_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_screened_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Ensure valid kind
+    CONSTRAINT chk_screening_hits_list_kind
+        CHECK (list_kind IN ('sanctions', 'pep')),
+
+    -- Ensure valid status
+    CONSTRAINT chk_screening_hits_status
+        CHECK (status IN ('open', 'confirmed', 'false_positive')),
+
+    -- Ensure valid score
+    CONSTRAINT chk_screening_hits_score
+        CHECK (score >= 0.0 AND score <= 1.0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_screening_hits_entry ON screening_hits(entity_id, list_name, entry_id);
+CREATE INDEX IF NOT EXISTS idx_screening_hits_status ON screening_hits(status, score DESC);
+CREATE INDEX IF NOT EXISTS idx_screening_hits_list ON screening_hits(list_name, entry_id);
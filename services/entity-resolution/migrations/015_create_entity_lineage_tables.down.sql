@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_entity_lineage_events_unsynced;
+DROP INDEX IF EXISTS idx_entity_lineage_events_related;
+DROP INDEX IF EXISTS idx_entity_lineage_events_entity;
+
+-- Drop tables
+DROP TABLE IF EXISTS entity_lineage_events;
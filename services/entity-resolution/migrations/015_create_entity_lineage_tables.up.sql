@@ -0,0 +1,23 @@
+-- Create entity_lineage_events table, the audit trail of how each entity was
+-- assembled. Entity IDs carry no foreign keys so the trail outlives the
+-- entities it describes.
+CREATE TABLE IF NOT EXISTS entity_lineage_events (
+    id UUID PRIMARY KEY,
+    entity_id UUID NOT NULL,
+    event_type VARCHAR(30) NOT NULL,
+    related_entity_id UUID,
+    source_id VARCHAR(255) NOT NULL DEFAULT '',
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    reference_id VARCHAR(255) NOT NULL DEFAULT '',
+    changes JSONB NOT NULL DEFAULT '[]',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    graph_synced_at TIMESTAMP WITH TIME ZONE,
+
+    -- Ensure valid event type
+    CONSTRAINT chk_entity_lineage_events_type
+        CHECK (event_type IN ('created', 'record_merged', 'entity_merged', 'entity_split'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_lineage_events_entity ON entity_lineage_events(entity_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_entity_lineage_events_related ON entity_lineage_events(related_entity_id) WHERE related_entity_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_entity_lineage_events_unsynced ON entity_lineage_events(occurred_at) WHERE graph_synced_at IS NULL;
@@ -0,0 +1,9 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_merge_candidates_duplicate_pending;
+DROP INDEX IF EXISTS idx_merge_candidates_survivor_pending;
+
+-- Restore the lineage event types
+DELETE FROM entity_lineage_events WHERE event_type = 'attributes_updated';
+ALTER TABLE entity_lineage_events DROP CONSTRAINT IF EXISTS chk_entity_lineage_events_type;
+ALTER TABLE entity_lineage_events ADD CONSTRAINT chk_entity_lineage_events_type
+    CHECK (event_type IN ('created', 'record_merged', 'entity_merged', 'entity_split'));
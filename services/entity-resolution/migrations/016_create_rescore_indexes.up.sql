@@ -0,0 +1,10 @@
+-- Allow attribute updates from upstream records in the lineage audit trail
+ALTER TABLE entity_lineage_events DROP CONSTRAINT IF EXISTS chk_entity_lineage_events_type;
+ALTER TABLE entity_lineage_events ADD CONSTRAINT chk_entity_lineage_events_type
+    CHECK (event_type IN ('created', 'record_merged', 'entity_merged', 'entity_split', 'attributes_updated'));
+
+-- Find the pending pairs involving an updated entity from either side
+CREATE INDEX IF NOT EXISTS idx_merge_candidates_survivor_pending
+    ON merge_candidates(survivor_entity_id) WHERE status = 'pending_review';
+CREATE INDEX IF NOT EXISTS idx_merge_candidates_duplicate_pending
+    ON merge_candidates(duplicate_entity_id) WHERE status = 'pending_review';
@@ -0,0 +1,3 @@
+-- Drop tables
+DROP TABLE IF EXISTS matching_models;
+DROP SEQUENCE IF EXISTS matching_model_revisions;
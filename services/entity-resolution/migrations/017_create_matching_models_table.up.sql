@@ -0,0 +1,19 @@
+-- Create matching_models table holding the per-entity-type matching models
+-- updated through the admin API. Each save takes the next revision so
+-- replicas can tell when to reload.
+CREATE SEQUENCE IF NOT EXISTS matching_model_revisions;
+
+CREATE TABLE IF NOT EXISTS matching_models (
+    entity_type VARCHAR(100) PRIMARY KEY,
+    weights JSONB NOT NULL DEFAULT '{}',
+    comparators JSONB NOT NULL DEFAULT '{}',
+    threshold DECIMAL(5,4),
+    revision BIGINT NOT NULL DEFAULT nextval('matching_model_revisions'),
+    updated_by VARCHAR(255) NOT NULL,
+    notes TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Ensure valid threshold values; no threshold inherits the default
+    CONSTRAINT chk_matching_models_threshold
+        CHECK (threshold IS NULL OR (threshold > 0.0 AND threshold <= 1.0))
+);
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/entity-resolution/internal/calibration"
+)
+
+// overconfidentSamples simulates a matcher whose raw scores overstate
+// precision: scores of 0.9 are only accepted by reviewers 60% of the time
+func overconfidentSamples() []calibration.Sample {
+	bands := []struct {
+		score    float64
+		accepted int
+		total    int
+	}{
+		{0.5, 1, 20},
+		{0.6, 2, 20},
+		{0.7, 4, 20},
+		{0.8, 8, 20},
+		{0.9, 12, 20},
+		{0.99, 18, 20},
+	}
+
+	var samples []calibration.Sample
+	for _, band := range bands {
+		for i := 0; i < band.total; i++ {
+			samples = append(samples, calibration.Sample{
+				Score:    band.score,
+				Accepted: i < band.accepted,
+			})
+		}
+	}
+	return samples
+}
+
+func TestCalibration_Unit(t *testing.T) {
+	samples := overconfidentSamples()
+
+	t.Run("Isotonic Matches Observed Precision", func(t *testing.T) {
+		curve, err := calibration.Fit(calibration.MethodIsotonic, samples)
+		require.NoError(t, err)
+
+		assert.InDelta(t, 0.6, curve.Calibrate(0.9), 0.0001)
+		assert.InDelta(t, 0.05, curve.Calibrate(0.5), 0.0001)
+		assert.InDelta(t, 0.5, curve.Calibrate(0.85), 0.0001, "Scores between bands are interpolated")
+	})
+
+	t.Run("Platt Is Monotone And Reduces Overconfidence", func(t *testing.T) {
+		curve, err := calibration.Fit(calibration.MethodPlatt, samples)
+		require.NoError(t, err)
+
+		assert.Less(t, curve.Calibrate(0.6), curve.Calibrate(0.9))
+		assert.InDelta(t, 0.6, curve.Calibrate(0.9), 0.1)
+
+		before := calibration.Evaluate(identity{}, samples, 10)
+		after := calibration.Evaluate(curve, samples, 10)
+		assert.Less(t, after.BrierScore, before.BrierScore)
+	})
+
+	t.Run("Curves Round Trip Through Stored Parameters", func(t *testing.T) {
+		curve, err := calibration.Fit(calibration.MethodIsotonic, samples)
+		require.NoError(t, err)
+
+		parameters, err := json.Marshal(curve)
+		require.NoError(t, err)
+
+		restored, err := calibration.Decode(curve.Method(), parameters)
+		require.NoError(t, err)
+		assert.Equal(t, curve.Calibrate(0.75), restored.Calibrate(0.75))
+	})
+
+	t.Run("Fit Requires Both Outcomes", func(t *testing.T) {
+		_, err := calibration.Fit(calibration.MethodPlatt, []calibration.Sample{
+			{Score: 0.9, Accepted: true},
+			{Score: 0.8, Accepted: true},
+		})
+		assert.Error(t, err)
+	})
+}
+
+type identity struct{}
+
+func (identity) Method() string                  { return "none" }
+func (identity) Calibrate(score float64) float64 { return score }
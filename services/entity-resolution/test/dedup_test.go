@@ -0,0 +1,113 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/dedup"
+	"github.com/aegisshield/entity-resolution/internal/matching"
+)
+
+// tableScorer returns fixed scores keyed by "input|candidate" entity name
+type tableScorer map[string]float64
+
+func (s tableScorer) FindMatches(input *matching.MatchInput, candidates []matching.CandidateEntity) (*matching.MatchResult, error) {
+	result := &matching.MatchResult{Query: input}
+	for _, candidate := range candidates {
+		if score, ok := s[input.Name+"|"+candidate.Name]; ok {
+			result.Candidates = append(result.Candidates, &matching.MatchCandidate{
+				EntityID:     candidate.ID,
+				OverallScore: score,
+			})
+		}
+	}
+	return result, nil
+}
+
+type halvingCalibrator struct{}
+
+func (halvingCalibrator) Calibrate(score float64) float64 { return score / 2 }
+
+func blockEntity(name string, age time.Duration) *database.Entity {
+	return &database.Entity{
+		ID:               uuid.New(),
+		EntityType:       "person",
+		StandardizedName: name,
+		CreatedAt:        time.Now().Add(-age),
+	}
+}
+
+func TestDedupDecide(t *testing.T) {
+	assert.Equal(t, dedup.DecisionAutoMerge, dedup.Decide(0.98, 0.97, 0.75))
+	assert.Equal(t, dedup.DecisionAutoMerge, dedup.Decide(0.97, 0.97, 0.75))
+	assert.Equal(t, dedup.DecisionReview, dedup.Decide(0.80, 0.97, 0.75))
+	assert.Equal(t, dedup.DecisionSkip, dedup.Decide(0.50, 0.97, 0.75))
+}
+
+func TestScoreBlockRanksPairsAndKeepsOldestAsSurvivor(t *testing.T) {
+	oldest := blockEntity("john smith", 72*time.Hour)
+	middle := blockEntity("jon smith", 48*time.Hour)
+	newest := blockEntity("john smyth", 24*time.Hour)
+
+	scorer := tableScorer{
+		"john smith|jon smith":  0.90,
+		"john smith|john smyth": 0.96,
+		"jon smith|john smyth":  0.80,
+	}
+
+	pairs, err := dedup.ScoreBlock([]*database.Entity{oldest, middle, newest}, scorer, halvingCalibrator{})
+	require.NoError(t, err)
+	require.Len(t, pairs, 3)
+
+	assert.Equal(t, oldest.ID, pairs[0].Survivor.ID)
+	assert.Equal(t, newest.ID, pairs[0].Duplicate.ID)
+	assert.InDelta(t, 0.96, pairs[0].RawScore, 1e-9)
+	assert.InDelta(t, 0.48, pairs[0].CalibratedScore, 1e-9)
+
+	assert.Equal(t, middle.ID, pairs[1].Duplicate.ID)
+	assert.Equal(t, middle.ID, pairs[2].Survivor.ID)
+
+	for i := 1; i < len(pairs); i++ {
+		assert.GreaterOrEqual(t, pairs[i-1].CalibratedScore, pairs[i].CalibratedScore)
+	}
+}
+
+func TestScoreBlockSingleEntity(t *testing.T) {
+	pairs, err := dedup.ScoreBlock([]*database.Entity{blockEntity("acme ltd", time.Hour)}, tableScorer{}, halvingCalibrator{})
+	require.NoError(t, err)
+	assert.Empty(t, pairs)
+}
+
+func TestToCandidateReadsContactFields(t *testing.T) {
+	identifiers, _ := json.Marshal(map[string]interface{}{
+		"ssn":   "123-45-6789",
+		"email": "j.smith@example.com",
+		"nin":   42,
+	})
+	attributes, _ := json.Marshal(map[string]interface{}{
+		"phone":   "+15551234567",
+		"email":   "ignored@example.com",
+		"address": "1 Main St",
+	})
+
+	entity := &database.Entity{
+		ID:          uuid.New(),
+		Name:        "John Smith",
+		Identifiers: identifiers,
+		Attributes:  attributes,
+	}
+
+	candidate := dedup.ToCandidate(entity)
+	assert.Equal(t, entity.ID.String(), candidate.ID)
+	assert.Equal(t, "John Smith", candidate.Name)
+	assert.Equal(t, "j.smith@example.com", candidate.Email)
+	assert.Equal(t, "+15551234567", candidate.Phone)
+	assert.Equal(t, "1 Main St", candidate.Address)
+	assert.Equal(t, map[string]string{"ssn": "123-45-6789", "nin": "42"}, candidate.Identifiers)
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/shared/eventcatalog"
+)
+
+type staticTopics []string
+
+func (t staticTopics) ListTopics(context.Context) ([]string, error) { return t, nil }
+
+type failingTopics struct{}
+
+func (failingTopics) ListTopics(context.Context) ([]string, error) {
+	return nil, errors.New("no brokers available")
+}
+
+func TestEventCatalog(t *testing.T) {
+	catalog := eventcatalog.Default()
+
+	require.NoError(t, catalog.CheckSchemas("../../../shared/proto"), "every event type is a message of its schema")
+
+	consumed := make(map[string]bool)
+	for _, topic := range catalog.ConsumedBy(eventcatalog.EntityResolution) {
+		consumed[topic.Name] = true
+	}
+	assert.Equal(t, map[string]bool{"transactions.processed": true, "entity.attribute.updated": true}, consumed)
+
+	topic, ok := catalog.Lookup("entities.resolved")
+	require.True(t, ok)
+	assert.True(t, topic.Produces(eventcatalog.EntityResolution))
+	assert.True(t, topic.Consumes(eventcatalog.GraphEngine))
+	assert.Equal(t, "aegisshield.events.EntityResolvedEvent", topic.EventType)
+
+	assert.Equal(t, []string{"transactions.renamed"}, catalog.Undeclared("transactions.processed", "transactions.renamed"))
+
+	t.Run("invalid catalogs are rejected", func(t *testing.T) {
+		valid := eventcatalog.Topic{
+			Name: "a", Description: "d", EventType: "aegisshield.events.ErrorEvent", Schema: "events.proto",
+			Encoding: eventcatalog.EncodingJSON, Producers: []string{"x"},
+		}
+		_, err := eventcatalog.New([]eventcatalog.Topic{valid, valid})
+		assert.Error(t, err, "duplicate names")
+
+		noProducer := valid
+		noProducer.Producers = nil
+		_, err = eventcatalog.New([]eventcatalog.Topic{noProducer})
+		assert.Error(t, err)
+
+		unknown := valid
+		unknown.EventType = "aegisshield.events.NoSuchEvent"
+		bad, err := eventcatalog.New([]eventcatalog.Topic{unknown})
+		require.NoError(t, err)
+		assert.Error(t, bad.CheckSchemas("../../../shared/proto"))
+	})
+}
+
+func TestCheckConsumedTopics(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, eventcatalog.CheckTopics(ctx, staticTopics{"transactions.processed", "entity.attribute.updated", "other"},
+		"transactions.processed", "entity.attribute.updated"))
+
+	err := eventcatalog.CheckTopics(ctx, staticTopics{"other"}, "transactions.processed", "entity.attribute.updated")
+	require.ErrorIs(t, err, eventcatalog.ErrMissingTopics)
+	var missing *eventcatalog.MissingTopicsError
+	require.True(t, errors.As(err, &missing))
+	assert.Equal(t, []string{"entity.attribute.updated", "transactions.processed"}, missing.Topics)
+
+	err = eventcatalog.CheckTopics(ctx, failingTopics{}, "transactions.processed")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, eventcatalog.ErrMissingTopics)
+}
+
+func TestEventCatalogEndpoint(t *testing.T) {
+	handler := eventcatalog.Default().Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/events/catalog?service=entity-resolution", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var doc eventcatalog.Document
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&doc))
+	require.Contains(t, doc.Services, "entity-resolution")
+	assert.Len(t, doc.Services, 1)
+	assert.Contains(t, doc.Services["entity-resolution"].Consumes, "transactions.processed")
+	for _, topic := range doc.Topics {
+		assert.True(t, topic.Produces("entity-resolution") || topic.Consumes("entity-resolution"), topic.Name)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/events/catalog?format=markdown", nil))
+	assert.Contains(t, rec.Body.String(), "# Event catalog")
+	assert.Contains(t, rec.Body.String(), "### entities.resolved")
+}
@@ -0,0 +1,271 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/lineage"
+	"github.com/aegisshield/entity-resolution/internal/neo4j"
+)
+
+// memoryLineageStore keeps the lineage audit trail in memory
+type memoryLineageStore struct {
+	events   []*database.LineageEvent
+	entities map[uuid.UUID]*database.Entity
+}
+
+func newMemoryLineageStore() *memoryLineageStore {
+	return &memoryLineageStore{entities: make(map[uuid.UUID]*database.Entity)}
+}
+
+func (s *memoryLineageStore) RecordLineageEvent(ctx context.Context, event *database.LineageEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *memoryLineageStore) ListLineageEvents(ctx context.Context, entityIDs []uuid.UUID, limit int) ([]*database.LineageEvent, error) {
+	wanted := make(map[uuid.UUID]bool, len(entityIDs))
+	for _, id := range entityIDs {
+		wanted[id] = true
+	}
+
+	var events []*database.LineageEvent
+	for _, event := range s.events {
+		if wanted[event.EntityID] || (event.RelatedEntityID != nil && wanted[*event.RelatedEntityID]) {
+			events = append(events, event)
+		}
+	}
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (s *memoryLineageStore) ListUnsyncedLineageEvents(ctx context.Context, limit int) ([]*database.LineageEvent, error) {
+	var events []*database.LineageEvent
+	for _, event := range s.events {
+		if event.GraphSyncedAt == nil && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *memoryLineageStore) MarkLineageEventsSynced(ctx context.Context, ids []uuid.UUID) error {
+	now := time.Now()
+	for _, id := range ids {
+		for _, event := range s.events {
+			if event.ID == id {
+				event.GraphSyncedAt = &now
+			}
+		}
+	}
+	return nil
+}
+
+func (s *memoryLineageStore) GetEntity(ctx context.Context, id uuid.UUID) (*database.Entity, error) {
+	entity, ok := s.entities[id]
+	if !ok {
+		return nil, errors.New("entity not found")
+	}
+	return entity, nil
+}
+
+// recordingLineageGraph records mirrored lineage nodes and fails while down
+type recordingLineageGraph struct {
+	nodes []*neo4j.LineageNode
+	down  bool
+}
+
+func (g *recordingLineageGraph) RecordLineage(ctx context.Context, event *neo4j.LineageNode) error {
+	if g.down {
+		return errors.New("graph unavailable")
+	}
+	g.nodes = append(g.nodes, event)
+	return nil
+}
+
+func lineageConfig() config.LineageConfig {
+	return config.LineageConfig{
+		GraphEnabled:       true,
+		GraphSyncInterval:  time.Minute,
+		GraphSyncBatchSize: 100,
+		MaxMergeDepth:      5,
+		MaxEvents:          100,
+	}
+}
+
+func lineageLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestDiffEntityFields(t *testing.T) {
+	changes, err := database.DiffEntityFields(
+		database.EntityFields{
+			Identifiers: map[string]interface{}{"ssn": "123"},
+			Attributes:  map[string]interface{}{"city": "Boston", "phone": "555"},
+		},
+		database.EntityFields{
+			Identifiers: map[string]interface{}{"ssn": "123", "passport": "X1"},
+			Attributes:  map[string]interface{}{"city": "Chicago"},
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	assert.Equal(t, "identifiers", changes[0].Field)
+	assert.Equal(t, "passport", changes[0].Attribute)
+	assert.Nil(t, changes[0].OldValue)
+	assert.JSONEq(t, `"X1"`, string(changes[0].NewValue))
+
+	assert.Equal(t, "city", changes[1].Attribute)
+	assert.JSONEq(t, `"Boston"`, string(changes[1].OldValue))
+	assert.JSONEq(t, `"Chicago"`, string(changes[1].NewValue))
+
+	assert.Equal(t, "phone", changes[2].Attribute)
+	assert.Nil(t, changes[2].NewValue, "removed keys have no new value")
+}
+
+func TestRecordResolutionMirrorsInGraph(t *testing.T) {
+	store := newMemoryLineageStore()
+	graph := &recordingLineageGraph{}
+	service := lineage.NewService(store, graph, lineageConfig(), lineageLogger())
+	entityID := uuid.New()
+
+	err := service.RecordResolution(context.Background(), &lineage.ResolutionRecord{
+		EntityID: entityID,
+		Created:  true,
+		SourceID: "core-banking",
+		After:    database.EntityFields{Attributes: map[string]interface{}{"city": "Boston"}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, database.LineageCreated, store.events[0].EventType)
+	assert.Len(t, store.events[0].Changes, 1)
+	assert.NotNil(t, store.events[0].GraphSyncedAt)
+
+	require.Len(t, graph.nodes, 1)
+	assert.Equal(t, entityID.String(), graph.nodes[0].EntityID)
+	assert.Equal(t, "core-banking", graph.nodes[0].SourceID)
+}
+
+func TestSyncGraphRetriesMissedEvents(t *testing.T) {
+	store := newMemoryLineageStore()
+	graph := &recordingLineageGraph{down: true}
+	service := lineage.NewService(store, graph, lineageConfig(), lineageLogger())
+
+	err := service.RecordResolution(context.Background(), &lineage.ResolutionRecord{
+		EntityID: uuid.New(),
+		SourceID: "cards",
+	})
+	require.NoError(t, err, "graph failures do not fail the audit record")
+	require.Len(t, store.events, 1)
+	assert.Nil(t, store.events[0].GraphSyncedAt)
+
+	_, err = service.SyncGraph(context.Background())
+	assert.Error(t, err)
+
+	graph.down = false
+	result, err := service.SyncGraph(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Synced)
+	assert.False(t, result.Remaining)
+	assert.NotNil(t, store.events[0].GraphSyncedAt)
+}
+
+func TestHistoryFollowsMergedEntities(t *testing.T) {
+	store := newMemoryLineageStore()
+	service := lineage.NewService(store, nil, lineageConfig(), lineageLogger())
+
+	root, merged, nested, split := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	store.entities[root] = &database.Entity{ID: root, Name: "Jane Smith"}
+	start := time.Now().Add(-time.Hour)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	events := []*database.LineageEvent{
+		{EntityID: root, EventType: database.LineageCreated, SourceID: "core-banking", OccurredAt: at(0)},
+		{EntityID: nested, EventType: database.LineageCreated, SourceID: "kyc", OccurredAt: at(1)},
+		{EntityID: merged, EventType: database.LineageCreated, SourceID: "cards", OccurredAt: at(2)},
+		{EntityID: merged, EventType: database.LineageEntityMerged, RelatedEntityID: &nested, Actor: "dedup", OccurredAt: at(3)},
+		{EntityID: split, EventType: database.LineageCreated, SourceID: "wires", OccurredAt: at(4)},
+		{EntityID: root, EventType: database.LineageEntityMerged, RelatedEntityID: &merged, Actor: "analyst-1", ReferenceID: "review-1", OccurredAt: at(5)},
+		{EntityID: root, EventType: database.LineageEntityMerged, RelatedEntityID: &split, Actor: "analyst-1", ReferenceID: "review-2", OccurredAt: at(6)},
+		{EntityID: root, EventType: database.LineageRecordMerged, SourceID: "core-banking", OccurredAt: at(7)},
+		{EntityID: root, EventType: database.LineageEntitySplit, RelatedEntityID: &split, Actor: "analyst-2", ReferenceID: "review-2", OccurredAt: at(8)},
+	}
+	for _, event := range events {
+		require.NoError(t, store.RecordLineageEvent(context.Background(), event))
+	}
+
+	history, err := service.History(context.Background(), root)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Jane Smith", history.Entity.Name)
+	assert.Len(t, history.Events, len(events))
+	assert.False(t, history.Truncated)
+	for i := 1; i < len(history.Events); i++ {
+		assert.False(t, history.Events[i].OccurredAt.Before(history.Events[i-1].OccurredAt))
+	}
+
+	require.Len(t, history.Contributors, 3)
+	byID := make(map[uuid.UUID]*lineage.Contributor)
+	for _, contributor := range history.Contributors {
+		byID[contributor.EntityID] = contributor
+	}
+	assert.Equal(t, 1, byID[merged].Depth)
+	assert.Equal(t, "review-1", byID[merged].ReferenceID)
+	assert.Equal(t, 2, byID[nested].Depth, "entities merged into contributors are followed")
+	assert.Equal(t, merged, byID[nested].MergedInto)
+	require.NotNil(t, byID[split].SplitAt)
+	assert.Equal(t, "analyst-2", byID[split].SplitBy)
+	assert.Nil(t, byID[merged].SplitAt)
+
+	assert.Equal(t, []string{"core-banking", "kyc", "cards", "wires"}, history.Sources)
+}
+
+func TestHistoryLimitsMergeDepth(t *testing.T) {
+	store := newMemoryLineageStore()
+	cfg := lineageConfig()
+	cfg.MaxMergeDepth = 1
+	service := lineage.NewService(store, nil, cfg, lineageLogger())
+
+	root, child, grandchild := uuid.New(), uuid.New(), uuid.New()
+	store.entities[root] = &database.Entity{ID: root}
+	require.NoError(t, store.RecordLineageEvent(context.Background(), &database.LineageEvent{
+		EntityID: child, EventType: database.LineageEntityMerged, RelatedEntityID: &grandchild,
+	}))
+	require.NoError(t, store.RecordLineageEvent(context.Background(), &database.LineageEvent{
+		EntityID: root, EventType: database.LineageEntityMerged, RelatedEntityID: &child,
+	}))
+
+	history, err := service.History(context.Background(), root)
+	require.NoError(t, err)
+	require.Len(t, history.Contributors, 1)
+	assert.Equal(t, child, history.Contributors[0].EntityID)
+	assert.True(t, history.Truncated)
+}
+
+func TestHistoryOfUnknownEntity(t *testing.T) {
+	service := lineage.NewService(newMemoryLineageStore(), nil, lineageConfig(), lineageLogger())
+
+	_, err := service.History(context.Background(), uuid.New())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
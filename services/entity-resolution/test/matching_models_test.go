@@ -0,0 +1,206 @@
+package test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/matching"
+	"github.com/aegisshield/entity-resolution/internal/matchmodel"
+	"github.com/aegisshield/entity-resolution/internal/standardization"
+)
+
+// memoryMatchingModelStore holds matching models in memory, raising the
+// revision on every save like the database sequence
+type memoryMatchingModelStore struct {
+	models   map[string]*database.MatchingModel
+	revision int64
+}
+
+func newMemoryMatchingModelStore() *memoryMatchingModelStore {
+	return &memoryMatchingModelStore{models: make(map[string]*database.MatchingModel)}
+}
+
+func (s *memoryMatchingModelStore) SaveMatchingModel(ctx context.Context, model *database.MatchingModel) error {
+	s.revision++
+	model.Revision = s.revision
+	stored := *model
+	s.models[model.EntityType] = &stored
+	return nil
+}
+
+func (s *memoryMatchingModelStore) ListMatchingModels(ctx context.Context) ([]*database.MatchingModel, error) {
+	var models []*database.MatchingModel
+	for _, model := range s.models {
+		stored := *model
+		models = append(models, &stored)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].EntityType < models[j].EntityType })
+	return models, nil
+}
+
+func (s *memoryMatchingModelStore) DeleteMatchingModel(ctx context.Context, entityType string) error {
+	if _, ok := s.models[entityType]; !ok {
+		return database.ErrMatchingModelNotFound
+	}
+	delete(s.models, entityType)
+	return nil
+}
+
+func (s *memoryMatchingModelStore) GetMatchingModelsRevision(ctx context.Context) (database.MatchingModelsRevision, error) {
+	var revision database.MatchingModelsRevision
+	for _, model := range s.models {
+		if model.Revision > revision.Revision {
+			revision.Revision = model.Revision
+		}
+		revision.Count++
+	}
+	return revision, nil
+}
+
+func matchingModelConfig() config.MatchingConfig {
+	return config.MatchingConfig{
+		NameSimilarityThreshold:    0.8,
+		OverallSimilarityThreshold: 0.5,
+		MaxCandidates:              10,
+		FuzzyMatchingEnabled:       true,
+		StreetAlgorithms:           []string{"levenshtein"},
+		CityAlgorithms:             []string{"levenshtein"},
+	}
+}
+
+func matchingModelEngine() *matching.Engine {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return matching.NewEngine(matchingModelConfig(), standardization.NewEngine(logger), logger)
+}
+
+// acmeCandidate shares a name with acmeInput but not an email
+var acmeCandidate = matching.CandidateEntity{ID: "acme", Name: "Acme Holdings", Email: "sales@acme-group.org"}
+
+func acmeInput(entityType string) *matching.MatchInput {
+	return &matching.MatchInput{EntityType: entityType, Name: "Acme Holdings", Email: "info@acme.com"}
+}
+
+func TestMatchingModelsPerEntityType(t *testing.T) {
+	engine := matchingModelEngine()
+
+	require.NoError(t, engine.ApplyModels([]matching.Model{
+		{EntityType: "organization", Weights: map[string]float64{"email": 0}},
+		{EntityType: "person", Threshold: 0.99},
+	}))
+
+	organization, err := engine.FindMatches(acmeInput("organization"), []matching.CandidateEntity{acmeCandidate})
+	require.NoError(t, err)
+	require.Len(t, organization.Candidates, 1)
+	assert.Equal(t, 1.0, organization.Candidates[0].OverallScore, "emails carry no weight for organizations")
+	assert.Equal(t, "organization", organization.Candidates[0].Evidence["matching_model"])
+
+	unknown, err := engine.FindMatches(acmeInput("vessel"), []matching.CandidateEntity{acmeCandidate})
+	require.NoError(t, err)
+	require.Len(t, unknown.Candidates, 1)
+	assert.Less(t, unknown.Candidates[0].OverallScore, 1.0, "the default model weighs the differing emails")
+	assert.Equal(t, matching.DefaultModelType, unknown.Candidates[0].Evidence["matching_model"])
+
+	person, err := engine.FindMatches(acmeInput("person"), []matching.CandidateEntity{acmeCandidate})
+	require.NoError(t, err)
+	assert.Empty(t, person.Candidates, "below the person threshold")
+
+	t.Run("models fill what they leave out from the default", func(t *testing.T) {
+		model := engine.Model("organization")
+		assert.Equal(t, 0.5, model.Threshold)
+		assert.Equal(t, 0.4, model.Weights[matching.FieldName])
+		assert.Equal(t, []string{"levenshtein"}, model.Comparators[matching.ComparatorStreet])
+	})
+
+	t.Run("an invalid model applies nothing", func(t *testing.T) {
+		err := engine.ApplyModels([]matching.Model{
+			{EntityType: "organization", Comparators: map[string][]string{"name": {"soundalike"}}},
+		})
+		assert.ErrorIs(t, err, matching.ErrInvalidModel)
+
+		err = engine.ApplyModels([]matching.Model{
+			{EntityType: "person", Weights: map[string]float64{"name": 0, "address": 0, "phone": 0, "email": 0, "identifier": 0}},
+		})
+		assert.ErrorIs(t, err, matching.ErrInvalidModel)
+
+		assert.Equal(t, 0.99, engine.Model("person").Threshold)
+	})
+
+	t.Run("a default model replaces the configured default", func(t *testing.T) {
+		require.NoError(t, engine.ApplyModels([]matching.Model{
+			{EntityType: matching.DefaultModelType, Threshold: 0.6},
+			{EntityType: "organization"},
+		}))
+
+		assert.Equal(t, 0.6, engine.Model("vessel").Threshold)
+		assert.Equal(t, 0.6, engine.Model("organization").Threshold)
+		assert.Len(t, engine.Models(), 2)
+		assert.Equal(t, matching.DefaultModelType, engine.Models()[0].EntityType)
+	})
+}
+
+func TestMatchingModelHotReload(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := newMemoryMatchingModelStore()
+
+	// Two replicas share the store
+	engineA, engineB := matchingModelEngine(), matchingModelEngine()
+	replicaA := matchmodel.NewService(store, engineA, matchingModelConfig(), logger)
+	replicaB := matchmodel.NewService(store, engineB, matchingModelConfig(), logger)
+	require.NoError(t, replicaA.Load(ctx))
+	require.NoError(t, replicaB.Load(ctx))
+
+	model, err := replicaA.Update(ctx, "organization", &matchmodel.UpdateRequest{
+		Weights:     map[string]float64{"email": 0},
+		Comparators: map[string][]string{"name": {"token", "exact", "token"}},
+		UpdatedBy:   "admin",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"exact", "token"}, model.Comparators[matching.ComparatorName])
+	assert.Equal(t, 0.0, engineA.Model("organization").Weights[matching.FieldEmail], "applied at once where saved")
+	assert.Equal(t, 0.1, engineB.Model("organization").Weights[matching.FieldEmail], "other replicas wait for a reload")
+
+	reloaded, err := replicaB.Reload(ctx)
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, 0.0, engineB.Model("organization").Weights[matching.FieldEmail])
+
+	reloaded, err = replicaB.Reload(ctx)
+	require.NoError(t, err)
+	assert.False(t, reloaded, "nothing changed since the last reload")
+
+	t.Run("invalid updates are not saved", func(t *testing.T) {
+		_, err := replicaA.Update(ctx, "person", &matchmodel.UpdateRequest{Threshold: 1.5, UpdatedBy: "admin"})
+		assert.ErrorIs(t, err, matching.ErrInvalidModel)
+
+		_, err = replicaA.Update(ctx, "person", &matchmodel.UpdateRequest{})
+		assert.ErrorIs(t, err, matchmodel.ErrValidation)
+
+		// A default leaving the organization model nothing to weigh is rejected
+		_, err = replicaA.Update(ctx, matching.DefaultModelType, &matchmodel.UpdateRequest{
+			Weights:   map[string]float64{"name": 0, "address": 0, "phone": 0, "identifier": 0},
+			UpdatedBy: "admin",
+		})
+		assert.ErrorIs(t, err, matching.ErrInvalidModel)
+
+		assert.Len(t, store.models, 1)
+	})
+
+	t.Run("deleting returns the type to the default model", func(t *testing.T) {
+		require.NoError(t, replicaA.Delete(ctx, "organization"))
+		assert.ErrorIs(t, replicaA.Delete(ctx, "organization"), database.ErrMatchingModelNotFound)
+
+		reloaded, err := replicaB.Reload(ctx)
+		require.NoError(t, err)
+		assert.True(t, reloaded)
+		assert.Equal(t, matching.DefaultModelType, engineB.Model("organization").EntityType)
+	})
+}
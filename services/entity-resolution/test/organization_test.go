@@ -0,0 +1,136 @@
+package test
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/matching"
+	"github.com/aegisshield/entity-resolution/internal/organization"
+	"github.com/aegisshield/entity-resolution/internal/standardization"
+)
+
+func ownership(percentage float64) *float64 {
+	return &percentage
+}
+
+func link(parent, child uuid.UUID, relationshipType string, percentage *float64) *database.OrganizationRelationship {
+	return &database.OrganizationRelationship{
+		ID:                  uuid.New(),
+		ParentEntityID:      parent,
+		ChildEntityID:       child,
+		RelationshipType:    relationshipType,
+		OwnershipPercentage: percentage,
+	}
+}
+
+func memberByID(family *organization.Family, id uuid.UUID) *organization.Member {
+	for _, member := range family.Members {
+		if member.EntityID == id {
+			return member
+		}
+	}
+	return nil
+}
+
+func TestBuildFamilyEffectiveOwnership(t *testing.T) {
+	holding, bank, leasing, branch := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	family, err := organization.BuildFamily(leasing, []*database.OrganizationRelationship{
+		link(holding, bank, database.RelationshipSubsidiary, ownership(80)),
+		link(bank, leasing, database.RelationshipSubsidiary, ownership(50)),
+		link(holding, leasing, database.RelationshipSubsidiary, ownership(10)),
+		link(bank, branch, database.RelationshipBranch, nil),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []uuid.UUID{holding}, family.UltimateParents)
+	require.Len(t, family.Members, 4)
+	assert.Equal(t, holding, family.Members[0].EntityID)
+
+	assert.Equal(t, 1, memberByID(family, bank).Depth)
+	assert.Equal(t, 1, memberByID(family, leasing).Depth, "depth is the shortest path from the ultimate parent")
+	assert.Equal(t, 2, memberByID(family, branch).Depth)
+
+	// Held directly (10%) and through the bank (80% of 50%)
+	assert.InDelta(t, 50.0, memberByID(family, leasing).EffectiveOwnership[holding], 1e-9)
+	assert.InDelta(t, 80.0, memberByID(family, branch).EffectiveOwnership[holding], 1e-9)
+	assert.Len(t, memberByID(family, leasing).ParentIDs, 2)
+}
+
+func TestBuildFamilyUnknownOwnershipAndJointVentures(t *testing.T) {
+	parentA, parentB, venture, affiliate := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	family, err := organization.BuildFamily(venture, []*database.OrganizationRelationship{
+		link(parentA, venture, database.RelationshipSubsidiary, ownership(50)),
+		link(parentB, venture, database.RelationshipSubsidiary, ownership(50)),
+		link(venture, affiliate, database.RelationshipAffiliate, nil),
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, family.UltimateParents, 2)
+	assert.InDelta(t, 50.0, memberByID(family, venture).EffectiveOwnership[parentA], 1e-9)
+	assert.InDelta(t, 50.0, memberByID(family, venture).EffectiveOwnership[parentB], 1e-9)
+	assert.Empty(t, memberByID(family, affiliate).EffectiveOwnership, "affiliates without a stake carry no ownership")
+}
+
+func TestBuildFamilyStandalone(t *testing.T) {
+	id := uuid.New()
+
+	family, err := organization.BuildFamily(id, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []uuid.UUID{id}, family.UltimateParents)
+	require.Len(t, family.Members, 1)
+	assert.InDelta(t, 100.0, family.Members[0].EffectiveOwnership[id], 1e-9)
+}
+
+func TestBuildFamilyRejectsCycles(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+
+	_, err := organization.BuildFamily(a, []*database.OrganizationRelationship{
+		link(a, b, database.RelationshipSubsidiary, nil),
+		link(b, c, database.RelationshipSubsidiary, nil),
+		link(c, b, database.RelationshipSubsidiary, nil),
+	})
+	assert.ErrorIs(t, err, organization.ErrCycle)
+}
+
+func TestValidateRelationship(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+
+	assert.NoError(t, organization.ValidateRelationship(link(a, b, database.RelationshipDivision, nil)))
+	assert.ErrorIs(t, organization.ValidateRelationship(link(a, a, database.RelationshipSubsidiary, nil)), organization.ErrCycle)
+	assert.ErrorIs(t, organization.ValidateRelationship(link(a, b, "partner", nil)), organization.ErrValidation)
+	assert.ErrorIs(t, organization.ValidateRelationship(link(a, b, database.RelationshipSubsidiary, ownership(120))), organization.ErrValidation)
+	assert.ErrorIs(t, organization.ValidateAliasType("nickname"), organization.ErrValidation)
+	assert.NoError(t, organization.ValidateAliasType(database.AliasTypeFormerName))
+}
+
+func TestMatchingUsesAliases(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	engine := matching.NewEngine(config.MatchingConfig{
+		OverallSimilarityThreshold: 0.1,
+		MaxCandidates:              10,
+		FuzzyMatchingEnabled:       true,
+	}, standardization.NewEngine(logger), logger)
+
+	candidates := []matching.CandidateEntity{
+		{ID: "renamed", Name: "Northwind Holdings Corporation", Aliases: []string{"Acme Widgets Incorporated"}},
+		{ID: "unrelated", Name: "Northwind Holdings Corporation"},
+	}
+
+	result, err := engine.FindMatches(&matching.MatchInput{Name: "Acme Widgets Incorporated"}, candidates)
+	require.NoError(t, err)
+	require.NotNil(t, result.BestMatch)
+
+	assert.Equal(t, "renamed", result.BestMatch.EntityID)
+	assert.InDelta(t, 1.0, result.BestMatch.NameScore, 1e-9)
+	assert.Equal(t, "Acme Widgets Incorporated", result.BestMatch.Evidence["matched_alias"])
+}
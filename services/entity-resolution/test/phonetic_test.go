@@ -0,0 +1,119 @@
+package test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/matching"
+	"github.com/aegisshield/entity-resolution/internal/standardization"
+)
+
+func TestSoundex(t *testing.T) {
+	cases := map[string]string{
+		"Robert":   "R163",
+		"Rupert":   "R163",
+		"Ashcraft": "A261",
+		"Tymczak":  "T522",
+		"Pfister":  "P236",
+		"Lee":      "L000",
+		"":         "",
+	}
+	for word, code := range cases {
+		assert.Equal(t, code, standardization.Soundex(word), word)
+	}
+}
+
+func TestDoubleMetaphone(t *testing.T) {
+	smith := standardization.DoubleMetaphone("Smith")
+	assert.Equal(t, "SM0", smith.Primary)
+	assert.Equal(t, "XMT", smith.Alternate)
+
+	schmidt := standardization.DoubleMetaphone("Schmidt")
+	assert.Equal(t, "XMT", schmidt.Primary)
+	assert.Equal(t, "SMT", schmidt.Alternate)
+
+	// Smith and Schmidt share an encoding; Smith and Jones do not
+	assert.True(t, smith.Matches(schmidt))
+	assert.False(t, smith.Matches(standardization.DoubleMetaphone("Jones")))
+	assert.True(t, standardization.DoubleMetaphone("Catherine").Matches(standardization.DoubleMetaphone("Kathryn")))
+}
+
+func TestTransliterate(t *testing.T) {
+	assert.Equal(t, "ivan petrov", standardization.Transliterate("Иван Петров"))
+	assert.Equal(t, "muller", standardization.Transliterate("Müller"))
+	assert.Equal(t, "strasse", standardization.Transliterate("Straße"))
+	assert.Equal(t, "jose garcia", standardization.Transliterate("José García"))
+	assert.Equal(t, "nikolaos", standardization.Transliterate("Νικολαος"))
+}
+
+func TestNameScoresPhonetic(t *testing.T) {
+	engine := tuningEngine()
+
+	// Transliterated names compare exactly with their Latin spellings
+	scores := engine.NameScores("Иван Петров", "Ivan Petrov")
+	assert.Equal(t, 1.0, scores[matching.NameAlgorithmExact])
+
+	// Differently spelled names still sound the same
+	scores = engine.NameScores("Stephen Smyth", "Steven Smith")
+	assert.InDelta(t, 0.8, scores[matching.NameAlgorithmPhonetic], 1e-9)
+	assert.InDelta(t, 0.75, scores[matching.NameAlgorithmMetaphone], 1e-9)
+
+	// Phonetic scores count matching words, in any order
+	scores = engine.NameScores("Smith John", "John Smith")
+	assert.InDelta(t, 0.8, scores[matching.NameAlgorithmPhonetic], 1e-9)
+
+	scores = engine.NameScores("John Smith", "John Garcia")
+	assert.InDelta(t, 0.4, scores[matching.NameAlgorithmPhonetic], 1e-9)
+
+	scores = engine.NameScores("John Smith", "Maria Garcia")
+	assert.Zero(t, scores[matching.NameAlgorithmPhonetic])
+	assert.Zero(t, scores[matching.NameAlgorithmMetaphone])
+}
+
+func TestNameAlgorithmsConfig(t *testing.T) {
+	settings := matching.DefaultNameSettings(config.MatchingConfig{
+		NameSimilarityThreshold: 0.8,
+		FuzzyMatchingEnabled:    true,
+		NameAlgorithms:          []string{"metaphone", "exact", "metaphone"},
+	})
+	assert.Equal(t, []string{"exact", "metaphone"}, settings.Algorithms)
+
+	valid := config.MatchingConfig{
+		StreetAlgorithms: []string{"levenshtein"},
+		CityAlgorithms:   []string{"levenshtein", "metaphone"},
+	}
+	require.NoError(t, matching.ValidateConfig(valid))
+
+	invalid := valid
+	invalid.CityAlgorithms = []string{"soundex"}
+	assert.ErrorIs(t, matching.ValidateConfig(invalid), matching.ErrInvalidNameSettings)
+
+	invalid = valid
+	invalid.StreetAlgorithms = nil
+	assert.ErrorIs(t, matching.ValidateConfig(invalid), matching.ErrInvalidNameSettings)
+}
+
+func TestAddressMatchingUsesFieldAlgorithms(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	engine := matching.NewEngine(config.MatchingConfig{
+		NameSimilarityThreshold: 0.8,
+		MaxCandidates:           10,
+		FuzzyMatchingEnabled:    true,
+		StreetAlgorithms:        []string{"levenshtein"},
+		CityAlgorithms:          []string{"levenshtein", "metaphone"},
+	}, standardization.NewEngine(logger), logger)
+
+	result, err := engine.FindMatches(&matching.MatchInput{Name: "Ivan Petrov", Address: "12 Main Street, Moskva"}, []matching.CandidateEntity{
+		{ID: "e1", Name: "Иван Петров", Address: "12 Main Street, Москва"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Candidates)
+	assert.Equal(t, "e1", result.Candidates[0].EntityID)
+	assert.Equal(t, 1.0, result.Candidates[0].NameScore)
+	assert.Greater(t, result.Candidates[0].AddressScore, 0.5)
+}
@@ -0,0 +1,334 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/matching"
+	"github.com/aegisshield/entity-resolution/internal/rescore"
+)
+
+// memoryRescoreStore holds entities and pending pairs in memory
+type memoryRescoreStore struct {
+	entities   map[uuid.UUID]*database.Entity
+	reviews    map[uuid.UUID]*database.MergeReview
+	candidates map[uuid.UUID]*database.MergeCandidate
+	runs       map[uuid.UUID]*database.DedupRun
+	decided    map[uuid.UUID]bool // pairs decided while being re-scored
+}
+
+func newMemoryRescoreStore(entities ...*database.Entity) *memoryRescoreStore {
+	store := &memoryRescoreStore{
+		entities:   make(map[uuid.UUID]*database.Entity),
+		reviews:    make(map[uuid.UUID]*database.MergeReview),
+		candidates: make(map[uuid.UUID]*database.MergeCandidate),
+		runs:       make(map[uuid.UUID]*database.DedupRun),
+		decided:    make(map[uuid.UUID]bool),
+	}
+	for _, entity := range entities {
+		store.entities[entity.ID] = entity
+	}
+	return store
+}
+
+func (s *memoryRescoreStore) GetEntity(ctx context.Context, id uuid.UUID) (*database.Entity, error) {
+	entity, ok := s.entities[id]
+	if !ok {
+		return nil, errors.New("entity not found")
+	}
+	return entity, nil
+}
+
+func (s *memoryRescoreStore) ApplyAttributeUpdate(ctx context.Context, update *database.AttributeUpdate) (*database.Entity, []*database.AttributeChange, error) {
+	entity, ok := s.entities[update.EntityID]
+	if !ok {
+		return nil, nil, database.ErrEntityNotFound
+	}
+
+	attributes := make(map[string]interface{})
+	if len(entity.Attributes) > 0 {
+		if err := json.Unmarshal(entity.Attributes, &attributes); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var changes []*database.AttributeChange
+	for key, value := range update.Attributes {
+		if value == nil {
+			delete(attributes, key)
+		} else {
+			attributes[key] = value
+		}
+		changes = append(changes, &database.AttributeChange{Field: "attributes", Attribute: key})
+	}
+
+	encoded, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, nil, err
+	}
+	entity.Attributes = encoded
+	return entity, changes, nil
+}
+
+func (s *memoryRescoreStore) ListMergeReviews(ctx context.Context, filter database.MergeReviewFilter) ([]*database.MergeReview, error) {
+	var reviews []*database.MergeReview
+	for _, mergeReview := range s.reviews {
+		if mergeReview.Status != filter.Status {
+			continue
+		}
+		if filter.EntityID != nil && mergeReview.SurvivorEntityID != *filter.EntityID && mergeReview.DuplicateEntityID != *filter.EntityID {
+			continue
+		}
+		reviews = append(reviews, mergeReview)
+	}
+	sort.Slice(reviews, func(i, j int) bool { return reviews[i].CalibratedScore > reviews[j].CalibratedScore })
+	if len(reviews) > filter.Limit {
+		reviews = reviews[:filter.Limit]
+	}
+	return reviews, nil
+}
+
+func (s *memoryRescoreStore) ListPendingMergeCandidates(ctx context.Context, entityID uuid.UUID, limit int) ([]*database.MergeCandidate, error) {
+	var candidates []*database.MergeCandidate
+	for _, candidate := range s.candidates {
+		if candidate.Status != database.CandidatePendingReview {
+			continue
+		}
+		if candidate.SurvivorEntityID != entityID && candidate.DuplicateEntityID != entityID {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].CalibratedScore > candidates[j].CalibratedScore })
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
+
+func (s *memoryRescoreStore) GetDedupRun(ctx context.Context, id uuid.UUID) (*database.DedupRun, error) {
+	run, ok := s.runs[id]
+	if !ok {
+		return nil, errors.New("dedup run not found")
+	}
+	return run, nil
+}
+
+func (s *memoryRescoreStore) RescoreMergeReview(ctx context.Context, rescore *database.PairRescore) error {
+	mergeReview := s.reviews[rescore.ID]
+	if s.decided[rescore.ID] || mergeReview.Status != database.MergeReviewPending {
+		return database.ErrReviewNotPending
+	}
+	mergeReview.RawScore = rescore.RawScore
+	mergeReview.CalibratedScore = rescore.CalibratedScore
+	mergeReview.Evidence = rescore.Evidence
+	if rescore.Supersede {
+		mergeReview.Status = database.MergeReviewSuperseded
+	}
+	return nil
+}
+
+func (s *memoryRescoreStore) RescoreMergeCandidate(ctx context.Context, rescore *database.PairRescore) error {
+	candidate := s.candidates[rescore.ID]
+	if s.decided[rescore.ID] || candidate.Status != database.CandidatePendingReview {
+		return database.ErrCandidateNotPending
+	}
+	candidate.RawScore = rescore.RawScore
+	candidate.CalibratedScore = rescore.CalibratedScore
+	candidate.Evidence = rescore.Evidence
+	if rescore.Supersede {
+		candidate.Status = database.CandidateSuperseded
+	}
+	return nil
+}
+
+// emailScorer scores pairs sharing an email at 0.95 and pairs sharing only a
+// name at 0.8; anything else does not match
+type emailScorer struct{}
+
+func (emailScorer) FindMatches(input *matching.MatchInput, candidates []matching.CandidateEntity) (*matching.MatchResult, error) {
+	result := &matching.MatchResult{Query: input}
+	for _, candidate := range candidates {
+		score := 0.0
+		switch {
+		case input.Email != "" && input.Email == candidate.Email:
+			score = 0.95
+		case input.Name == candidate.Name:
+			score = 0.8
+		default:
+			continue
+		}
+		result.Candidates = append(result.Candidates, &matching.MatchCandidate{
+			EntityID:     candidate.ID,
+			OverallScore: score,
+		})
+	}
+	return result, nil
+}
+
+type identityCalibrator struct{}
+
+func (identityCalibrator) Calibrate(score float64) float64 { return score }
+
+func rescoreEntity(name, email string) *database.Entity {
+	attributes, _ := json.Marshal(map[string]interface{}{"email": email})
+	return &database.Entity{
+		ID:               uuid.New(),
+		EntityType:       "person",
+		StandardizedName: name,
+		Attributes:       attributes,
+	}
+}
+
+func rescoreConfig(maxPairs int) config.RescoreConfig {
+	return config.RescoreConfig{
+		Enabled:       true,
+		ConsumerGroup: "entity-resolution-rescore",
+		MaxPairs:      maxPairs,
+	}
+}
+
+func newRescoreService(store rescore.Store, maxPairs int) *rescore.Service {
+	return rescore.NewService(store, emailScorer{}, identityCalibrator{}, 0.75, rescoreConfig(maxPairs), slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestRescoreAfterAttributeUpdate(t *testing.T) {
+	updated := rescoreEntity("jane doe", "jane@old.example")
+	sameName := rescoreEntity("jane doe", "jd@other.example")
+	newEmail := rescoreEntity("jane doe", "jane@new.example")
+	oldEmail := rescoreEntity("j roe", "jane@old.example")
+	store := newMemoryRescoreStore(updated, sameName, newEmail, oldEmail)
+
+	runID := uuid.New()
+	store.runs[runID] = &database.DedupRun{ID: runID, ReviewThreshold: 0.85}
+
+	reviewID := uuid.New()
+	store.reviews[reviewID] = &database.MergeReview{
+		ID:                reviewID,
+		SurvivorEntityID:  sameName.ID,
+		DuplicateEntityID: updated.ID,
+		RawScore:          0.8,
+		CalibratedScore:   0.8,
+		Evidence:          json.RawMessage(`{"review_threshold":0.75}`),
+		Status:            database.MergeReviewPending,
+	}
+	risingID := uuid.New()
+	store.candidates[risingID] = &database.MergeCandidate{
+		ID:                risingID,
+		RunID:             runID,
+		SurvivorEntityID:  newEmail.ID,
+		DuplicateEntityID: updated.ID,
+		RawScore:          0.86,
+		CalibratedScore:   0.86,
+		Status:            database.CandidatePendingReview,
+	}
+	fallingID := uuid.New()
+	store.candidates[fallingID] = &database.MergeCandidate{
+		ID:                fallingID,
+		RunID:             runID,
+		SurvivorEntityID:  updated.ID,
+		DuplicateEntityID: oldEmail.ID,
+		RawScore:          0.95,
+		CalibratedScore:   0.95,
+		Evidence:          json.RawMessage(`{"entity_id":"x","overall_score":0.95}`),
+		Status:            database.CandidatePendingReview,
+	}
+
+	result, err := newRescoreService(store, 10).Rescore(context.Background(), &rescore.Update{
+		EventID:    "evt-1",
+		EntityID:   updated.ID,
+		Attributes: map[string]interface{}{"email": "jane@new.example"},
+		SourceID:   "crm",
+		OccurredAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, updated.ID, result.EntityID)
+	assert.Equal(t, 1, result.Changes)
+	assert.Equal(t, 2, result.Rescored)
+	assert.Equal(t, 1, result.Superseded)
+	assert.False(t, result.Truncated)
+
+	mergeReview := store.reviews[reviewID]
+	assert.Equal(t, database.MergeReviewPending, mergeReview.Status)
+	assert.InDelta(t, 0.8, mergeReview.CalibratedScore, 1e-9, "the names still match")
+
+	rising := store.candidates[risingID]
+	assert.Equal(t, database.CandidatePendingReview, rising.Status)
+	assert.InDelta(t, 0.95, rising.CalibratedScore, 1e-9)
+
+	falling := store.candidates[fallingID]
+	assert.Equal(t, database.CandidateSuperseded, falling.Status, "the shared email was the only evidence")
+	assert.Zero(t, falling.RawScore)
+
+	t.Run("evidence keeps the original and records the re-scoring", func(t *testing.T) {
+		var evidence map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(falling.Evidence, &evidence))
+		assert.JSONEq(t, `"x"`, string(evidence["entity_id"]))
+
+		var recorded rescore.Evidence
+		require.NoError(t, json.Unmarshal(evidence["rescore"], &recorded))
+		assert.Equal(t, "evt-1", recorded.EventID)
+		assert.Equal(t, "crm", recorded.SourceID)
+		assert.Equal(t, updated.ID, recorded.UpdatedEntityID)
+		assert.InDelta(t, 0.95, recorded.PreviousCalibratedScore, 1e-9)
+		assert.InDelta(t, 0.85, recorded.Threshold, 1e-9, "candidates are held to their run's threshold")
+		assert.Nil(t, recorded.Match)
+
+		require.NoError(t, json.Unmarshal(mergeReview.Evidence, &evidence))
+		assert.JSONEq(t, `0.75`, string(evidence["review_threshold"]))
+		require.NoError(t, json.Unmarshal(evidence["rescore"], &recorded))
+		require.NotNil(t, recorded.Match)
+		assert.Equal(t, updated.ID.String(), recorded.Match.EntityID)
+	})
+}
+
+func TestRescoreLimitsAndSkips(t *testing.T) {
+	updated := rescoreEntity("jane doe", "jane@example.com")
+	other := rescoreEntity("jane doe", "jd@example.com")
+	store := newMemoryRescoreStore(updated, other)
+
+	var ids []uuid.UUID
+	for i := 0; i < 3; i++ {
+		id := uuid.New()
+		ids = append(ids, id)
+		store.reviews[id] = &database.MergeReview{
+			ID:                id,
+			SurvivorEntityID:  other.ID,
+			DuplicateEntityID: updated.ID,
+			CalibratedScore:   0.9 - float64(i)*0.01,
+			Status:            database.MergeReviewPending,
+		}
+	}
+	store.decided[ids[0]] = true
+
+	service := newRescoreService(store, 2)
+	result, err := service.Rescore(context.Background(), &rescore.Update{EntityID: updated.ID})
+	require.NoError(t, err)
+
+	assert.Zero(t, result.Changes, "an update without values re-scores the entity as stored")
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, 1, result.Rescored)
+	assert.True(t, result.Truncated)
+	assert.InDelta(t, 0.88, store.reviews[ids[2]].CalibratedScore, 1e-9, "beyond the pair limit")
+
+	t.Run("invalid updates", func(t *testing.T) {
+		_, err := service.Rescore(context.Background(), &rescore.Update{})
+		assert.ErrorIs(t, err, rescore.ErrEntityRequired)
+
+		_, err = service.Rescore(context.Background(), &rescore.Update{EntityID: uuid.New()})
+		assert.ErrorIs(t, err, database.ErrEntityNotFound)
+	})
+}
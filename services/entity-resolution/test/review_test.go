@@ -0,0 +1,285 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/neo4j"
+	"github.com/aegisshield/entity-resolution/internal/review"
+)
+
+// memoryReviewStore applies merge review transitions in memory
+type memoryReviewStore struct {
+	reviews  map[uuid.UUID]*database.MergeReview
+	survivor *database.Entity
+}
+
+func newMemoryReviewStore(survivor *database.Entity) *memoryReviewStore {
+	return &memoryReviewStore{
+		reviews:  make(map[uuid.UUID]*database.MergeReview),
+		survivor: survivor,
+	}
+}
+
+func (s *memoryReviewStore) CreateMergeReview(ctx context.Context, mergeReview *database.MergeReview) error {
+	s.reviews[mergeReview.ID] = mergeReview
+	return nil
+}
+
+func (s *memoryReviewStore) GetMergeReview(ctx context.Context, id uuid.UUID) (*database.MergeReview, error) {
+	mergeReview, ok := s.reviews[id]
+	if !ok {
+		return nil, errors.New("merge review not found")
+	}
+	return mergeReview, nil
+}
+
+func (s *memoryReviewStore) ListMergeReviews(ctx context.Context, filter database.MergeReviewFilter) ([]*database.MergeReview, error) {
+	var reviews []*database.MergeReview
+	for _, mergeReview := range s.reviews {
+		if filter.Status == "" || mergeReview.Status == filter.Status {
+			reviews = append(reviews, mergeReview)
+		}
+	}
+	return reviews, nil
+}
+
+func (s *memoryReviewStore) ApproveMergeReview(ctx context.Context, id uuid.UUID, reviewerID, notes string) (*database.MergeReview, *database.Entity, error) {
+	mergeReview, err := s.transition(id, database.MergeReviewPending, database.MergeReviewApproved, database.ErrReviewNotPending)
+	if err != nil {
+		return nil, nil, err
+	}
+	mergeReview.ReviewerID = reviewerID
+	mergeReview.ReviewNotes = notes
+	return mergeReview, s.survivor, nil
+}
+
+func (s *memoryReviewStore) RejectMergeReview(ctx context.Context, id uuid.UUID, reviewerID, notes string) (*database.MergeReview, error) {
+	mergeReview, err := s.transition(id, database.MergeReviewPending, database.MergeReviewRejected, database.ErrReviewNotPending)
+	if err != nil {
+		return nil, err
+	}
+	mergeReview.ReviewerID = reviewerID
+	mergeReview.ReviewNotes = notes
+	return mergeReview, nil
+}
+
+func (s *memoryReviewStore) SplitMergeReview(ctx context.Context, id uuid.UUID, splitBy, reason string) (*database.MergeReview, *database.Entity, error) {
+	mergeReview, err := s.transition(id, database.MergeReviewApproved, database.MergeReviewSplit, database.ErrReviewNotApproved)
+	if err != nil {
+		return nil, nil, err
+	}
+	mergeReview.SplitBy = splitBy
+	mergeReview.SplitReason = reason
+	return mergeReview, s.survivor, nil
+}
+
+func (s *memoryReviewStore) transition(id uuid.UUID, from, to string, conflict error) (*database.MergeReview, error) {
+	mergeReview, ok := s.reviews[id]
+	if !ok {
+		return nil, errors.New("merge review not found")
+	}
+	if mergeReview.Status != from {
+		return nil, conflict
+	}
+	mergeReview.Status = to
+	return mergeReview, nil
+}
+
+// recordingGraph records the merges and splits mirrored into the graph
+type recordingGraph struct {
+	merged []string
+	split  []string
+	err    error
+}
+
+func (g *recordingGraph) MergeEntities(ctx context.Context, reviewID string, survivor *neo4j.EntityNode, duplicateID string) error {
+	g.merged = append(g.merged, survivor.ID+"<-"+duplicateID)
+	return g.err
+}
+
+func (g *recordingGraph) SplitEntities(ctx context.Context, reviewID string, survivor *neo4j.EntityNode, duplicateID string) error {
+	g.split = append(g.split, survivor.ID+"->"+duplicateID)
+	return g.err
+}
+
+func newReviewService(store review.Store, graph review.Graph) *review.Service {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return review.NewService(store, graph, nil, config.ReviewConfig{
+		Enabled:       true,
+		Threshold:     0.75,
+		MaxCandidates: 10,
+	}, logger)
+}
+
+func queueTestReview(t *testing.T, service *review.Service, survivor *database.Entity) *database.MergeReview {
+	mergeReview := &database.MergeReview{
+		SurvivorEntityID:  survivor.ID,
+		DuplicateEntityID: uuid.New(),
+		EntityType:        survivor.EntityType,
+		RawScore:          0.88,
+		CalibratedScore:   0.82,
+		Evidence:          []byte(`{"match_score":0.88}`),
+	}
+	require.NoError(t, service.Queue(context.Background(), mergeReview))
+	return mergeReview
+}
+
+func reviewSurvivor() *database.Entity {
+	return &database.Entity{
+		ID:          uuid.New(),
+		EntityType:  "person",
+		Name:        "John Smith",
+		Identifiers: []byte(`{"ssn":"123-45-6789"}`),
+		Attributes:  []byte(`{"country":"US"}`),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+func TestUnmergeFieldsRemovesOnlyKeysGainedFromDuplicate(t *testing.T) {
+	before := database.EntityFields{
+		Identifiers: map[string]interface{}{"ssn": "123-45-6789"},
+		Attributes:  map[string]interface{}{"country": "US"},
+	}
+	duplicate := database.EntityFields{
+		Identifiers: map[string]interface{}{"ssn": "999-99-9999", "passport": "X123"},
+		Attributes:  map[string]interface{}{"country": "CA", "occupation": "banker", "city": "Toronto"},
+	}
+	current := database.EntityFields{
+		Identifiers: map[string]interface{}{"ssn": "123-45-6789", "passport": "X123"},
+		Attributes: map[string]interface{}{
+			"country":    "US",
+			"occupation": "banker",
+			"city":       "Boston", // changed after the merge
+			"email":      "john@example.com",
+		},
+	}
+
+	restored := database.UnmergeFields(current, before, duplicate)
+
+	assert.Equal(t, map[string]interface{}{"ssn": "123-45-6789"}, restored.Identifiers)
+	assert.Equal(t, map[string]interface{}{
+		"country": "US",
+		"city":    "Boston",
+		"email":   "john@example.com",
+	}, restored.Attributes)
+}
+
+func TestUnmergeFieldsHandlesEmptySnapshots(t *testing.T) {
+	current := database.EntityFields{
+		Identifiers: map[string]interface{}{"passport": "X123"},
+	}
+
+	restored := database.UnmergeFields(current, database.EntityFields{}, database.EntityFields{
+		Identifiers: map[string]interface{}{"passport": "X123"},
+	})
+
+	assert.Empty(t, restored.Identifiers)
+	assert.Empty(t, restored.Attributes)
+}
+
+func TestReviewQueueMarksReviewPending(t *testing.T) {
+	survivor := reviewSurvivor()
+	service := newReviewService(newMemoryReviewStore(survivor), &recordingGraph{})
+
+	mergeReview := queueTestReview(t, service, survivor)
+
+	assert.NotEqual(t, uuid.Nil, mergeReview.ID)
+	assert.Equal(t, database.MergeReviewPending, mergeReview.Status)
+	assert.False(t, mergeReview.CreatedAt.IsZero())
+	assert.Equal(t, 0.75, service.Threshold())
+	assert.Equal(t, 10, service.MaxCandidates())
+}
+
+func TestReviewApproveMergesGraphAndSplitRestoresIt(t *testing.T) {
+	survivor := reviewSurvivor()
+	graph := &recordingGraph{}
+	service := newReviewService(newMemoryReviewStore(survivor), graph)
+	mergeReview := queueTestReview(t, service, survivor)
+	ctx := context.Background()
+
+	approved, err := service.Decide(ctx, mergeReview.ID, &review.DecisionRequest{
+		Decision:   review.DecisionApprove,
+		ReviewerID: "investigator-1",
+		Notes:      "same SSN",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, database.MergeReviewApproved, approved.Status)
+	assert.Equal(t, []string{survivor.ID.String() + "<-" + mergeReview.DuplicateEntityID.String()}, graph.merged)
+
+	split, err := service.Split(ctx, mergeReview.ID, &review.SplitRequest{
+		SplitBy: "investigator-2",
+		Reason:  "different date of birth",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, database.MergeReviewSplit, split.Status)
+	assert.Equal(t, []string{survivor.ID.String() + "->" + mergeReview.DuplicateEntityID.String()}, graph.split)
+
+	_, err = service.Split(ctx, mergeReview.ID, &review.SplitRequest{SplitBy: "investigator-2"})
+	assert.ErrorIs(t, err, database.ErrReviewNotApproved)
+}
+
+func TestReviewRejectLeavesGraphUntouched(t *testing.T) {
+	survivor := reviewSurvivor()
+	graph := &recordingGraph{}
+	service := newReviewService(newMemoryReviewStore(survivor), graph)
+	mergeReview := queueTestReview(t, service, survivor)
+
+	rejected, err := service.Decide(context.Background(), mergeReview.ID, &review.DecisionRequest{
+		Decision:   review.DecisionReject,
+		ReviewerID: "investigator-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, database.MergeReviewRejected, rejected.Status)
+	assert.Empty(t, graph.merged)
+
+	_, err = service.Decide(context.Background(), mergeReview.ID, &review.DecisionRequest{
+		Decision:   review.DecisionApprove,
+		ReviewerID: "investigator-1",
+	})
+	assert.ErrorIs(t, err, database.ErrReviewNotPending)
+}
+
+func TestReviewDecisionValidation(t *testing.T) {
+	survivor := reviewSurvivor()
+	service := newReviewService(newMemoryReviewStore(survivor), &recordingGraph{})
+	mergeReview := queueTestReview(t, service, survivor)
+	ctx := context.Background()
+
+	_, err := service.Decide(ctx, mergeReview.ID, &review.DecisionRequest{Decision: "maybe", ReviewerID: "investigator-1"})
+	assert.ErrorIs(t, err, review.ErrInvalidDecision)
+
+	_, err = service.Decide(ctx, mergeReview.ID, &review.DecisionRequest{Decision: review.DecisionApprove})
+	assert.ErrorIs(t, err, review.ErrReviewerRequired)
+
+	_, err = service.Split(ctx, mergeReview.ID, &review.SplitRequest{})
+	assert.ErrorIs(t, err, review.ErrReviewerRequired)
+
+	assert.Equal(t, database.MergeReviewPending, mergeReview.Status)
+}
+
+func TestReviewGraphFailureDoesNotUndoDecision(t *testing.T) {
+	survivor := reviewSurvivor()
+	graph := &recordingGraph{err: errors.New("neo4j unavailable")}
+	service := newReviewService(newMemoryReviewStore(survivor), graph)
+	mergeReview := queueTestReview(t, service, survivor)
+
+	approved, err := service.Decide(context.Background(), mergeReview.ID, &review.DecisionRequest{
+		Decision:   review.DecisionApprove,
+		ReviewerID: "investigator-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, database.MergeReviewApproved, approved.Status)
+	assert.Len(t, graph.merged, 1)
+}
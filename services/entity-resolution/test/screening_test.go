@@ -0,0 +1,346 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/matching"
+	"github.com/aegisshield/entity-resolution/internal/screening"
+)
+
+const sanctionsCSV = `id,name,type,aliases,programs,countries,dates_of_birth,remarks
+SDN-1,Viktor Petrov,individual,Victor Petrov;V. Petrov,UKRAINE-EO13660,RU,1961-03-14,
+SDN-2,Petrov Shipping LLC,entity,,UKRAINE-EO13660,RU,,front company
+SDN-3,Ali Hassan Rahimi,individual,,SDGT,IR,1975,
+`
+
+// memoryScreeningStore keeps lists and hits in memory
+type memoryScreeningStore struct {
+	lists    map[string]*database.ScreeningList
+	hits     map[string]*database.ScreeningHit
+	entities map[uuid.UUID]*database.Entity
+}
+
+func newMemoryScreeningStore() *memoryScreeningStore {
+	return &memoryScreeningStore{
+		lists:    make(map[string]*database.ScreeningList),
+		hits:     make(map[string]*database.ScreeningHit),
+		entities: make(map[uuid.UUID]*database.Entity),
+	}
+}
+
+func (s *memoryScreeningStore) SaveScreeningList(ctx context.Context, list *database.ScreeningList) error {
+	s.lists[list.Name] = list
+	return nil
+}
+
+func (s *memoryScreeningStore) ListScreeningLists(ctx context.Context) ([]*database.ScreeningList, error) {
+	var lists []*database.ScreeningList
+	for _, list := range s.lists {
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
+
+func (s *memoryScreeningStore) DeleteScreeningList(ctx context.Context, name string) error {
+	if _, ok := s.lists[name]; !ok {
+		return database.ErrScreeningListNotFound
+	}
+	delete(s.lists, name)
+	return nil
+}
+
+func (s *memoryScreeningStore) SaveScreeningHits(ctx context.Context, hits []*database.ScreeningHit) ([]*database.ScreeningHit, error) {
+	var created []*database.ScreeningHit
+	for _, hit := range hits {
+		key := hit.EntityID.String() + "/" + hit.ListName + "/" + hit.EntryID
+		if existing, ok := s.hits[key]; ok {
+			hit.ID = existing.ID
+			hit.Status = existing.Status
+			hit.FirstScreenedAt = existing.FirstScreenedAt
+			s.hits[key] = hit
+			continue
+		}
+		s.hits[key] = hit
+		created = append(created, hit)
+	}
+	return created, nil
+}
+
+func (s *memoryScreeningStore) GetScreeningHit(ctx context.Context, id uuid.UUID) (*database.ScreeningHit, error) {
+	for _, hit := range s.hits {
+		if hit.ID == id {
+			return hit, nil
+		}
+	}
+	return nil, errors.New("screening hit not found: " + id.String())
+}
+
+func (s *memoryScreeningStore) ListScreeningHits(ctx context.Context, filter database.ScreeningHitFilter) ([]*database.ScreeningHit, error) {
+	var hits []*database.ScreeningHit
+	for _, hit := range s.hits {
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
+
+func (s *memoryScreeningStore) ReviewScreeningHit(ctx context.Context, id uuid.UUID, status, reviewerID, notes string) (*database.ScreeningHit, error) {
+	hit, err := s.GetScreeningHit(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	hit.Status = status
+	hit.ReviewerID = reviewerID
+	hit.ReviewNotes = notes
+	return hit, nil
+}
+
+func (s *memoryScreeningStore) GetEntity(ctx context.Context, id uuid.UUID) (*database.Entity, error) {
+	entity, ok := s.entities[id]
+	if !ok {
+		return nil, errors.New("entity not found: " + id.String())
+	}
+	return entity, nil
+}
+
+// recordingPublisher records the hits it is asked to publish
+type recordingPublisher struct {
+	hits []*database.ScreeningHit
+}
+
+func (p *recordingPublisher) PublishScreeningHit(ctx context.Context, hit *database.ScreeningHit) error {
+	p.hits = append(p.hits, hit)
+	return nil
+}
+
+// pairScorer scores identical names 1 and the listed pairs as given
+type pairScorer map[string]float64
+
+func (s pairScorer) NameScores(name1, name2 string) matching.NameScores {
+	score := s[strings.ToLower(name1)+"|"+strings.ToLower(name2)]
+	if strings.EqualFold(name1, name2) {
+		score = 1
+	}
+	return matching.NameScores{matching.NameAlgorithms[0]: score}
+}
+
+func (s pairScorer) NameSettings() matching.NameSettings {
+	return matching.NameSettings{Threshold: 0.85, Algorithms: matching.NameAlgorithms[:1]}
+}
+
+func screeningConfig() config.ScreeningConfig {
+	return config.ScreeningConfig{
+		Enabled:          true,
+		PEPLists:         []string{"pep"},
+		Threshold:        0.85,
+		ReloadInterval:   time.Hour,
+		FetchTimeout:     time.Second,
+		MaxListEntries:   1000,
+		MaxUploadBytes:   1 << 20,
+		MaxHitsPerEntity: 10,
+	}
+}
+
+func screeningLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestParseListCSV(t *testing.T) {
+	entries, err := screening.ParseList(screening.FormatCSV, []byte(sanctionsCSV), 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "SDN-1", entries[0].ID)
+	assert.Equal(t, screening.EntryIndividual, entries[0].Type)
+	assert.Equal(t, []string{"Victor Petrov", "V. Petrov"}, entries[0].Aliases)
+	assert.Equal(t, []string{"1961-03-14"}, entries[0].DatesOfBirth)
+	assert.Equal(t, "front company", entries[1].Remarks)
+}
+
+func TestParseListJSON(t *testing.T) {
+	data := []byte(`{"entries": [{"id": "PEP-1", "name": "Maria Lopez", "type": "Individual", "countries": ["mx"]}]}`)
+
+	entries, err := screening.ParseList(screening.FormatJSON, data, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, screening.EntryIndividual, entries[0].Type)
+	assert.Equal(t, []string{"MX"}, entries[0].Countries)
+
+	entries, err = screening.ParseList(screening.FormatJSON, []byte(`[{"id": "PEP-2", "name": "Jan Novak"}]`), 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Jan Novak", entries[0].Name)
+}
+
+func TestParseListRejectsInvalidLists(t *testing.T) {
+	tests := map[string]struct {
+		format string
+		data   string
+		max    int
+	}{
+		"missing name column": {screening.FormatCSV, "id,aliases\n1,x\n", 0},
+		"empty name":          {screening.FormatCSV, "id,name\n1,\n", 0},
+		"duplicate id":        {screening.FormatCSV, "id,name\n1,A B\n1,C D\n", 0},
+		"unknown type":        {screening.FormatJSON, `[{"id":"1","name":"A B","type":"ship"}]`, 0},
+		"no entries":          {screening.FormatJSON, `[]`, 0},
+		"too many entries":    {screening.FormatCSV, "id,name\n1,A B\n2,C D\n", 1},
+		"unknown format":      {"xml", "<list/>", 0},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := screening.ParseList(tt.format, []byte(tt.data), tt.max)
+			assert.ErrorIs(t, err, screening.ErrInvalidList)
+		})
+	}
+}
+
+func TestIndexScreensSpellingVariants(t *testing.T) {
+	entries, err := screening.ParseList(screening.FormatCSV, []byte(sanctionsCSV), 0)
+	require.NoError(t, err)
+	index := screening.NewIndex([]*screening.List{{Name: "ofac", Kind: screening.KindSanctions, Entries: entries}})
+
+	matches := index.Screen(&screening.Subject{
+		EntityType: "person",
+		Name:       "Viktor Petrow",
+	}, tuningEngine(), 0.85)
+
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "SDN-1", matches[0].Entry.ID)
+	for _, match := range matches {
+		assert.NotEqual(t, "SDN-2", match.Entry.ID, "people are not screened against organizations")
+	}
+}
+
+func TestIndexAdjustsScoreByDateOfBirthAndCountry(t *testing.T) {
+	entries, err := screening.ParseList(screening.FormatCSV, []byte(sanctionsCSV), 0)
+	require.NoError(t, err)
+	index := screening.NewIndex([]*screening.List{{Name: "ofac", Kind: screening.KindSanctions, Entries: entries}})
+	scorer := pairScorer{"ali hasan rahimi|ali hassan rahimi": 0.9}
+
+	matches := index.Screen(&screening.Subject{
+		Name:        "Ali Hasan Rahimi",
+		DateOfBirth: "1975-06-01",
+		Countries:   []string{"IR"},
+	}, scorer, 0.85)
+	require.Len(t, matches, 1)
+	require.NotNil(t, matches[0].DateOfBirthMatch)
+	assert.True(t, *matches[0].DateOfBirthMatch, "a birth year matches any date in that year")
+	assert.True(t, matches[0].CountryMatch)
+	assert.InDelta(t, 0.98, matches[0].Score, 0.0001)
+
+	matches = index.Screen(&screening.Subject{
+		Name:        "Ali Hasan Rahimi",
+		DateOfBirth: "1990-01-01",
+	}, scorer, 0.85)
+	assert.Empty(t, matches, "a conflicting date of birth drops the score below the threshold")
+}
+
+func TestScreenEntityPublishesNewHitsOnce(t *testing.T) {
+	store := newMemoryScreeningStore()
+	publisher := &recordingPublisher{}
+	service := screening.NewService(store, publisher, pairScorer{}, screeningConfig(), screeningLogger())
+
+	_, err := service.UploadList(context.Background(), &screening.UploadRequest{
+		Name:       "ofac",
+		Format:     screening.FormatCSV,
+		Content:    []byte(sanctionsCSV),
+		UploadedBy: "analyst-1",
+	})
+	require.NoError(t, err)
+
+	subject := &screening.Subject{EntityID: uuid.New(), EntityType: "person", Name: "Victor Petrov"}
+
+	hits, err := service.ScreenEntity(context.Background(), subject)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "SDN-1", hits[0].EntryID)
+	assert.Equal(t, screening.KindSanctions, hits[0].ListKind)
+	assert.Equal(t, "Victor Petrov", hits[0].MatchedName)
+	require.Len(t, publisher.hits, 1)
+
+	var evidence map[string]interface{}
+	require.NoError(t, json.Unmarshal(hits[0].Evidence, &evidence))
+	assert.Equal(t, []interface{}{"UKRAINE-EO13660"}, evidence["programs"])
+
+	hits, err = service.ScreenEntity(context.Background(), subject)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Len(t, publisher.hits, 1, "a hit already recorded is not published again")
+}
+
+func TestScreenEntityByIDReadsAttributes(t *testing.T) {
+	store := newMemoryScreeningStore()
+	service := screening.NewService(store, &recordingPublisher{}, pairScorer{}, screeningConfig(), screeningLogger())
+
+	_, err := service.UploadList(context.Background(), &screening.UploadRequest{
+		Name:    "pep",
+		Format:  screening.FormatJSON,
+		Content: []byte(`[{"id": "PEP-1", "name": "Maria Lopez", "type": "individual"}]`),
+	})
+	require.NoError(t, err)
+
+	entity := &database.Entity{
+		ID:         uuid.New(),
+		EntityType: "person",
+		Name:       "M. Lopez Garcia",
+		Attributes: json.RawMessage(`{"aliases": ["Maria Lopez"], "nationality": "mx"}`),
+	}
+	store.entities[entity.ID] = entity
+
+	hits, err := service.ScreenEntityByID(context.Background(), entity.ID)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, screening.KindPEP, hits[0].ListKind, "lists named as PEP lists are PEP lists")
+	assert.Equal(t, "Maria Lopez", hits[0].MatchedName)
+}
+
+func TestLoadListsKeepsPreviousVersionOnFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "un.csv")
+	require.NoError(t, os.WriteFile(path, []byte(sanctionsCSV), 0o600))
+
+	cfg := screeningConfig()
+	cfg.Lists = map[string]string{"un": path}
+	service := screening.NewService(newMemoryScreeningStore(), nil, pairScorer{}, cfg, screeningLogger())
+
+	require.NoError(t, service.LoadLists(context.Background()))
+	lists := service.Lists()
+	require.Len(t, lists, 1)
+	assert.Equal(t, 3, lists[0].EntryCount)
+
+	require.NoError(t, os.WriteFile(path, []byte("id,name\n1,\n"), 0o600))
+	assert.Error(t, service.LoadLists(context.Background()))
+
+	lists = service.Lists()
+	require.Len(t, lists, 1)
+	assert.Equal(t, 3, lists[0].EntryCount)
+	assert.NotEmpty(t, lists[0].Error)
+
+	_, err := service.UploadList(context.Background(), &screening.UploadRequest{
+		Name: "un", Format: screening.FormatCSV, Content: []byte(sanctionsCSV),
+	})
+	assert.ErrorIs(t, err, screening.ErrConfiguredList)
+}
+
+func TestReviewHitRequiresDisposition(t *testing.T) {
+	service := screening.NewService(newMemoryScreeningStore(), nil, pairScorer{}, screeningConfig(), screeningLogger())
+
+	_, err := service.ReviewHit(context.Background(), uuid.New(), &screening.ReviewRequest{Status: "open", ReviewerID: "analyst-1"})
+	assert.ErrorIs(t, err, screening.ErrInvalidReview)
+
+	_, err = service.ReviewHit(context.Background(), uuid.New(), &screening.ReviewRequest{Status: database.ScreeningHitConfirmed})
+	assert.ErrorIs(t, err, screening.ErrReviewerRequired)
+}
@@ -0,0 +1,212 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/database"
+	"github.com/aegisshield/entity-resolution/internal/survivorship"
+)
+
+// memoryObservationStore keeps attribute observations and provenance in memory
+type memoryObservationStore struct {
+	observations []*database.AttributeObservation
+	provenance   map[string]*database.AttributeProvenance
+}
+
+func newMemoryObservationStore() *memoryObservationStore {
+	return &memoryObservationStore{provenance: make(map[string]*database.AttributeProvenance)}
+}
+
+func (s *memoryObservationStore) RecordAttributeObservations(ctx context.Context, observations []*database.AttributeObservation) error {
+	for _, observation := range observations {
+		recorded := *observation
+		s.observations = append(s.observations, &recorded)
+	}
+	return nil
+}
+
+func (s *memoryObservationStore) ListAttributeObservations(ctx context.Context, entityID uuid.UUID) ([]*database.AttributeObservation, error) {
+	var observations []*database.AttributeObservation
+	for _, observation := range s.observations {
+		if observation.EntityID == entityID {
+			observations = append(observations, observation)
+		}
+	}
+	return observations, nil
+}
+
+func (s *memoryObservationStore) SaveAttributeProvenance(ctx context.Context, provenance []*database.AttributeProvenance) error {
+	for _, p := range provenance {
+		s.provenance[p.Field+"."+p.Attribute] = p
+	}
+	return nil
+}
+
+func (s *memoryObservationStore) ListAttributeProvenance(ctx context.Context, entityID uuid.UUID) ([]*database.AttributeProvenance, error) {
+	var provenance []*database.AttributeProvenance
+	for _, p := range s.provenance {
+		if p.EntityID == entityID {
+			provenance = append(provenance, p)
+		}
+	}
+	return provenance, nil
+}
+
+func survivorshipEngine() *survivorship.Engine {
+	return survivorship.NewEngine(config.SurvivorshipConfig{
+		DefaultStrategy: survivorship.StrategyMostRecent,
+		Rules: map[string]string{
+			"person.date_of_birth": survivorship.StrategyMostTrustedSource,
+			"*.address":            survivorship.StrategyLongest,
+			"person.occupation":    survivorship.StrategyMostFrequent,
+		},
+		SourceTrust: map[string]float64{
+			"passport_registry": 0.95,
+			"kyc_form":          0.6,
+		},
+	})
+}
+
+func TestSurvivorshipStrategyLookup(t *testing.T) {
+	engine := survivorshipEngine()
+
+	assert.Equal(t, survivorship.StrategyMostTrustedSource, engine.StrategyFor("person", "date_of_birth"))
+	assert.Equal(t, survivorship.StrategyMostRecent, engine.StrategyFor("organization", "date_of_birth"))
+	assert.Equal(t, survivorship.StrategyLongest, engine.StrategyFor("organization", "address"))
+	assert.Equal(t, survivorship.StrategyLongest, engine.StrategyFor("Person", "Address"))
+	assert.Equal(t, survivorship.StrategyMostRecent, engine.StrategyFor("person", "email"))
+}
+
+func TestSurvivorshipStrategies(t *testing.T) {
+	engine := survivorshipEngine()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("most recent", func(t *testing.T) {
+		decision, err := engine.Resolve("person", "email", []survivorship.Observation{
+			{Value: "old@example.com", SourceID: "kyc_form", ObservedAt: base},
+			{Value: "new@example.com", SourceID: "crm", ObservedAt: base.Add(time.Hour)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", decision.Value)
+		assert.Equal(t, "crm", decision.SourceID)
+		assert.Equal(t, 2, decision.Candidates)
+	})
+
+	t.Run("most trusted source", func(t *testing.T) {
+		decision, err := engine.Resolve("person", "date_of_birth", []survivorship.Observation{
+			{Value: "1980-05-01", SourceID: "passport_registry", ObservedAt: base},
+			{Value: "1980-01-05", SourceID: "kyc_form", ObservedAt: base.Add(time.Hour)},
+			{Value: "1980-01-05", SourceID: "crm", ObservedAt: base.Add(2 * time.Hour)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "1980-05-01", decision.Value)
+		assert.Equal(t, survivorship.StrategyMostTrustedSource, decision.Strategy)
+		assert.Equal(t, "passport_registry", decision.SourceID)
+	})
+
+	t.Run("longest", func(t *testing.T) {
+		decision, err := engine.Resolve("person", "address", []survivorship.Observation{
+			{Value: "1 Main St, Springfield, IL 62701", SourceID: "kyc_form", ObservedAt: base},
+			{Value: "1 Main St", SourceID: "crm", ObservedAt: base.Add(time.Hour)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "1 Main St, Springfield, IL 62701", decision.Value)
+	})
+
+	t.Run("most frequent pools repeated values", func(t *testing.T) {
+		decision, err := engine.Resolve("person", "occupation", []survivorship.Observation{
+			{Value: "banker", SourceID: "kyc_form", ObservedAt: base, Count: 2},
+			{Value: "banker", SourceID: "crm", ObservedAt: base.Add(time.Hour)},
+			{Value: "trader", SourceID: "crm", ObservedAt: base.Add(2 * time.Hour), Count: 2},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "banker", decision.Value)
+		assert.Equal(t, 3, decision.Observations)
+		assert.Equal(t, "crm", decision.SourceID)
+	})
+
+	t.Run("ties fall back to recency", func(t *testing.T) {
+		decision, err := engine.Resolve("person", "occupation", []survivorship.Observation{
+			{Value: "banker", SourceID: "crm", ObservedAt: base},
+			{Value: "trader", SourceID: "crm", ObservedAt: base.Add(time.Hour)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "trader", decision.Value)
+	})
+
+	t.Run("no observations", func(t *testing.T) {
+		_, err := engine.Resolve("person", "email", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestSurvivorshipMergeRecordsProvenance(t *testing.T) {
+	store := newMemoryObservationStore()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := survivorship.NewService(store, survivorshipEngine(), logger)
+	ctx := context.Background()
+	entityID := uuid.New()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// A legacy value predating survivorship competes as an unknown source
+	first, err := service.Merge(ctx, &survivorship.MergeRequest{
+		EntityID:   entityID,
+		EntityType: "person",
+		Current: database.EntityFields{
+			Attributes: map[string]interface{}{"date_of_birth": "1980-01-05", "nationality": "US"},
+		},
+		CurrentUpdatedAt: created,
+		Incoming: database.EntityFields{
+			Identifiers: map[string]interface{}{"passport": "X123"},
+			Attributes:  map[string]interface{}{"date_of_birth": "1980-05-01"},
+		},
+		SourceID:   "passport_registry",
+		ObservedAt: created.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1980-05-01", first.Fields.Attributes["date_of_birth"])
+	assert.Equal(t, "US", first.Fields.Attributes["nationality"])
+	assert.Equal(t, "X123", first.Fields.Identifiers["passport"])
+
+	// A later, less trusted report does not displace the passport registry
+	second, err := service.Merge(ctx, &survivorship.MergeRequest{
+		EntityID:         entityID,
+		EntityType:       "person",
+		Current:          first.Fields,
+		CurrentUpdatedAt: created.Add(time.Hour),
+		Incoming: database.EntityFields{
+			Attributes: map[string]interface{}{"date_of_birth": "1980-01-05"},
+		},
+		SourceID:   "kyc_form",
+		ObservedAt: created.Add(2 * time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1980-05-01", second.Fields.Attributes["date_of_birth"])
+	assert.Equal(t, "X123", second.Fields.Identifiers["passport"])
+	require.Len(t, second.Provenance, 1)
+
+	provenance := store.provenance["attributes.date_of_birth"]
+	require.NotNil(t, provenance)
+	assert.Equal(t, survivorship.StrategyMostTrustedSource, provenance.Strategy)
+	assert.Equal(t, "passport_registry", provenance.SourceID)
+	assert.Equal(t, 2, provenance.CandidateCount)
+
+	var value string
+	require.NoError(t, json.Unmarshal(provenance.Value, &value))
+	assert.Equal(t, "1980-05-01", value)
+
+	nationality := store.provenance["attributes.nationality"]
+	require.NotNil(t, nationality)
+	assert.Empty(t, nationality.SourceID)
+	assert.Equal(t, created, nationality.ObservedAt)
+}
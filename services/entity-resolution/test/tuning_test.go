@@ -0,0 +1,169 @@
+package test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/entity-resolution/internal/config"
+	"github.com/aegisshield/entity-resolution/internal/matching"
+	"github.com/aegisshield/entity-resolution/internal/standardization"
+	"github.com/aegisshield/entity-resolution/internal/tuning"
+)
+
+func tuningEngine() *matching.Engine {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return matching.NewEngine(config.MatchingConfig{
+		NameSimilarityThreshold: 0.8,
+		FuzzyMatchingEnabled:    true,
+		PhoneticMatchingEnabled: true,
+	}, standardization.NewEngine(logger), logger)
+}
+
+// scored builds a corpus pair scoring the same under every algorithm except
+// those overridden
+func scored(isMatch bool, base float64, overrides map[string]float64) tuning.ScoredPair {
+	scores := matching.NameScores{}
+	for _, algorithm := range matching.NameAlgorithms {
+		scores[algorithm] = base
+	}
+	for algorithm, score := range overrides {
+		scores[algorithm] = score
+	}
+	return tuning.ScoredPair{Scores: scores, IsMatch: isMatch}
+}
+
+func TestSweepThresholds(t *testing.T) {
+	thresholds, err := tuning.Sweep{From: 0.7, To: 0.9, Step: 0.05}.Thresholds()
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.7, 0.75, 0.8, 0.85, 0.9}, thresholds)
+
+	for name, sweep := range map[string]tuning.Sweep{
+		"zero step":     {From: 0.5, To: 0.9},
+		"reversed":      {From: 0.9, To: 0.5, Step: 0.1},
+		"zero from":     {From: 0, To: 0.5, Step: 0.1},
+		"beyond one":    {From: 0.5, To: 1.2, Step: 0.1},
+		"negative step": {From: 0.5, To: 0.9, Step: -0.1},
+	} {
+		_, err := sweep.Thresholds()
+		assert.ErrorIs(t, err, tuning.ErrInvalidSweep, name)
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	combinations := tuning.Combinations([]string{matching.NameAlgorithmToken, matching.NameAlgorithmExact})
+	assert.Equal(t, [][]string{{"exact"}, {"token"}, {"exact", "token"}}, combinations)
+
+	assert.Len(t, tuning.Combinations(matching.NameAlgorithms), 31)
+
+	normalized, err := tuning.NormalizeCombinations([][]string{{"token", "exact"}, {"exact", "token", "exact"}, {"phonetic"}})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"exact", "token"}, {"phonetic"}}, normalized)
+
+	_, err = tuning.NormalizeCombinations([][]string{{"soundex"}})
+	assert.ErrorIs(t, err, matching.ErrInvalidNameSettings)
+	_, err = tuning.NormalizeCombinations([][]string{{}})
+	assert.ErrorIs(t, err, matching.ErrInvalidNameSettings)
+}
+
+func TestEvaluatePrecisionRecall(t *testing.T) {
+	pairs := []tuning.ScoredPair{
+		scored(true, 0.95, nil),
+		scored(true, 0.85, nil),
+		scored(true, 0.6, map[string]float64{matching.NameAlgorithmPhonetic: 0.8}),
+		scored(false, 0.9, nil),
+		scored(false, 0.5, nil),
+	}
+
+	points := tuning.Evaluate(pairs, [][]string{{"levenshtein"}, {"levenshtein", "phonetic"}}, []float64{0.8, 0.9})
+	require.Len(t, points, 4)
+
+	at := func(algorithms []string, threshold float64) *tuning.Point {
+		point := tuning.Find(points, algorithms, threshold)
+		require.NotNil(t, point)
+		return point
+	}
+
+	levenshtein := at([]string{"levenshtein"}, 0.8)
+	assert.Equal(t, 2, levenshtein.TruePositives)
+	assert.Equal(t, 1, levenshtein.FalsePositives)
+	assert.Equal(t, 1, levenshtein.FalseNegatives)
+	assert.Equal(t, 1, levenshtein.TrueNegatives)
+	assert.InDelta(t, 2.0/3, levenshtein.Precision, 1e-9)
+	assert.InDelta(t, 2.0/3, levenshtein.Recall, 1e-9)
+
+	// adding phonetic matching catches the sound-alike pair
+	combined := at([]string{"levenshtein", "phonetic"}, 0.8)
+	assert.Equal(t, 3, combined.TruePositives)
+	assert.InDelta(t, 1.0, combined.Recall, 1e-9)
+	assert.InDelta(t, 0.75, combined.Precision, 1e-9)
+
+	strict := at([]string{"levenshtein"}, 0.9)
+	assert.Equal(t, 1, strict.TruePositives)
+	assert.Equal(t, 1, strict.FalsePositives)
+
+	assert.Nil(t, tuning.Find(points, []string{"token"}, 0.8))
+}
+
+func TestBestPoint(t *testing.T) {
+	points := []*tuning.Point{
+		{Algorithms: []string{"exact"}, Threshold: 0.9, Precision: 1.0, Recall: 0.4, F1: 0.571},
+		{Algorithms: []string{"levenshtein"}, Threshold: 0.8, Precision: 0.8, Recall: 0.8, F1: 0.8},
+		{Algorithms: []string{"levenshtein", "phonetic"}, Threshold: 0.8, Precision: 0.8, Recall: 0.8, F1: 0.8},
+		{Algorithms: []string{"levenshtein", "phonetic"}, Threshold: 0.7, Precision: 0.6, Recall: 1.0, F1: 0.75},
+	}
+
+	// by F1, ties go to fewer algorithms
+	best := tuning.Best(points, 0)
+	require.NotNil(t, best)
+	assert.Equal(t, []string{"levenshtein"}, best.Algorithms)
+
+	// with a precision floor the best is the highest recall meeting it
+	best = tuning.Best(points, 0.75)
+	require.NotNil(t, best)
+	assert.Equal(t, 0.8, best.Threshold)
+	assert.Equal(t, 0.8, best.Recall)
+
+	assert.Nil(t, tuning.Best(points, 1.01))
+}
+
+func TestEngineNameSettings(t *testing.T) {
+	engine := tuningEngine()
+
+	settings := engine.NameSettings()
+	assert.Equal(t, 0.8, settings.Threshold)
+	assert.Equal(t, []string{"exact", "levenshtein", "metaphone", "phonetic", "token"}, settings.Algorithms)
+
+	scores := engine.NameScores("John Smith", "John Smith")
+	assert.Equal(t, 1.0, scores[matching.NameAlgorithmExact])
+	assert.Equal(t, 1.0, scores[matching.NameAlgorithmLevenshtein])
+	assert.Len(t, scores, len(matching.NameAlgorithms))
+
+	candidates := []matching.CandidateEntity{
+		{ID: "exact", Name: "John Smith"},
+		{ID: "alias", Name: "Acme Holdings", Aliases: []string{"Smith John"}},
+		{ID: "other", Name: "Maria Garcia"},
+	}
+
+	matches := engine.ScreenName("John Smith", candidates)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "exact", matches[0].EntityID)
+	assert.Equal(t, "alias", matches[1].EntityID)
+	assert.Equal(t, "Smith John", matches[1].Evidence["matched_name"])
+
+	// exact matching alone no longer flags the alias
+	require.NoError(t, engine.ApplyNameSettings(matching.NameSettings{Threshold: 1, Algorithms: []string{"exact", "exact"}}))
+	assert.Equal(t, []string{"exact"}, engine.NameSettings().Algorithms)
+	matches = engine.ScreenName("John Smith", candidates)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "exact", matches[0].EntityID)
+
+	err := engine.ApplyNameSettings(matching.NameSettings{Threshold: 0, Algorithms: []string{"exact"}})
+	assert.ErrorIs(t, err, matching.ErrInvalidNameSettings)
+	err = engine.ApplyNameSettings(matching.NameSettings{Threshold: 0.9, Algorithms: []string{"soundex"}})
+	assert.ErrorIs(t, err, matching.ErrInvalidNameSettings)
+	assert.Equal(t, 1.0, engine.NameSettings().Threshold, "rejected settings are not applied")
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aegisshield/entity-resolution/internal/organization"
+	"github.com/aegisshield/shared/validation"
+)
+
+func TestRelationshipRequestFieldErrors(t *testing.T) {
+	err := validation.Struct(&organization.RelationshipRequest{
+		ChildEntityID:       uuid.New(),
+		RelationshipType:    "partner",
+		OwnershipPercentage: ownership(120),
+	})
+	require.Error(t, err)
+
+	errs, ok := validation.FieldErrors(err)
+	require.True(t, ok)
+	require.Len(t, errs, 3)
+	assert.Equal(t, "parent_entity_id", errs[0].Field)
+	assert.Equal(t, "required", errs[0].Rule)
+	assert.Equal(t, "relationship_type", errs[1].Field)
+	assert.Equal(t, "oneof", errs[1].Rule)
+	assert.Equal(t, "ownership_percentage", errs[2].Field)
+	assert.Equal(t, "max", errs[2].Rule)
+
+	assert.NoError(t, validation.Struct(&organization.RelationshipRequest{
+		ParentEntityID:   uuid.New(),
+		ChildEntityID:    uuid.New(),
+		RelationshipType: "subsidiary",
+	}))
+}
+
+func TestAliasRequestValidation(t *testing.T) {
+	assert.Error(t, validation.Struct(&organization.AliasRequest{}))
+	assert.Error(t, validation.Struct(&organization.AliasRequest{Alias: "Acme", AliasType: "nickname"}))
+	assert.NoError(t, validation.Struct(&organization.AliasRequest{Alias: "Acme"}))
+	assert.NoError(t, validation.Struct(&organization.AliasRequest{Alias: "Acme", AliasType: "dba"}))
+}
+
+func TestBankingValidators(t *testing.T) {
+	assert.True(t, validation.IsIBAN("GB82 WEST 1234 5698 7654 32"))
+	assert.True(t, validation.IsIBAN("de89370400440532013000"))
+	assert.False(t, validation.IsIBAN("GB82WEST12345698765433"), "check digits must hold")
+	assert.False(t, validation.IsIBAN("DE8937040044053201300"), "length must match the country")
+
+	assert.True(t, validation.IsBIC("DEUTDEFF"))
+	assert.True(t, validation.IsBIC("DEUTDEFF500"))
+	assert.False(t, validation.IsBIC("DEUTZZFF"), "country must be a known code")
+	assert.False(t, validation.IsBIC("DEUT1EFF"))
+
+	assert.True(t, validation.IsCountryCode("KY"))
+	assert.False(t, validation.IsCountryCode("ZZ"))
+	assert.True(t, validation.IsCurrencyCode("CHF"))
+	assert.False(t, validation.IsCurrencyCode("XYZ"))
+}
+
+func TestValidationStatusError(t *testing.T) {
+	type transfer struct {
+		Account  string `json:"account" validate:"required,iban"`
+		Currency string `json:"currency" validate:"required,currency"`
+	}
+
+	err := validation.Request(&transfer{Account: "GB00WEST12345698765432", Currency: "EUR"})
+	require.Error(t, err)
+
+	st := status.Convert(validation.StatusError(err))
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 1)
+}
@@ -11,23 +11,35 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/aegisshield/graph-engine/internal/accessaudit"
 	"github.com/aegisshield/graph-engine/internal/analytics"
+	"github.com/aegisshield/graph-engine/internal/backup"
+	"github.com/aegisshield/graph-engine/internal/blocking"
 	"github.com/aegisshield/graph-engine/internal/config"
 	"github.com/aegisshield/graph-engine/internal/database"
 	"github.com/aegisshield/graph-engine/internal/engine"
+	"github.com/aegisshield/graph-engine/internal/exposure"
+	"github.com/aegisshield/graph-engine/internal/geo"
 	"github.com/aegisshield/graph-engine/internal/handlers"
 	"github.com/aegisshield/graph-engine/internal/interceptors"
 	"github.com/aegisshield/graph-engine/internal/kafka"
+	"github.com/aegisshield/graph-engine/internal/loadshed"
+	"github.com/aegisshield/graph-engine/internal/maintenance"
 	"github.com/aegisshield/graph-engine/internal/metrics"
 	"github.com/aegisshield/graph-engine/internal/neo4j"
 	"github.com/aegisshield/graph-engine/internal/patterns"
+	"github.com/aegisshield/graph-engine/internal/querytemplates"
 	"github.com/aegisshield/graph-engine/internal/resolution"
 	"github.com/aegisshield/graph-engine/internal/server"
+	"github.com/aegisshield/graph-engine/internal/temporal"
+	"github.com/aegisshield/graph-engine/internal/tenancy"
+	"github.com/aegisshield/graph-engine/internal/thumbnail"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 
 	pb "github.com/aegisshield/shared/proto"
+	"github.com/aegisshield/shared/startup"
 )
 
 func main() {
@@ -50,16 +62,36 @@ func main() {
 	// Initialize metrics collector
 	metricsCollector := metrics.NewCollector()
 
+	// Create context for graceful shutdown; a shutdown signal also aborts
+	// startup retries
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	// Start in dependency order: migrations, repositories, consumers, servers.
+	// Unavailable dependencies are retried with backoff.
+	seq := startup.NewSequencer(startup.Backoff{
+		Initial:    cfg.Startup.InitialBackoff,
+		Max:        cfg.Startup.MaxBackoff,
+		Multiplier: 2,
+		MaxElapsed: cfg.Startup.MaxElapsed,
+	}, logger)
+
 	// Initialize database connection
-	db, err := database.NewConnection(cfg.Database, logger)
-	if err != nil {
+	var db *database.Connection
+	if err := seq.Step(ctx, startup.Component{Name: "postgres", Phase: startup.PhaseMigrations, Critical: true}, func(ctx context.Context) error {
+		var err error
+		db, err = database.NewConnection(cfg.Database, logger)
+		return err
+	}); err != nil {
 		logger.Error("Failed to connect to database", "error", err)
 		os.Exit(1)
 	}
 	defer db.Close()
 
 	// Run database migrations
-	if err := database.RunMigrations(cfg.Database.URL); err != nil {
+	if err := seq.Step(ctx, startup.Component{Name: "migrations", Phase: startup.PhaseMigrations, Critical: true}, func(ctx context.Context) error {
+		return database.RunMigrations(cfg.Database.URL)
+	}); err != nil {
 		logger.Error("Failed to run database migrations", "error", err)
 		os.Exit(1)
 	}
@@ -68,16 +100,29 @@ func main() {
 	repo := database.NewRepository(db, logger)
 
 	// Initialize Neo4j client
-	neo4jClient, err := neo4j.NewClient(cfg.Neo4j, logger)
-	if err != nil {
+	var neo4jClient *neo4j.Client
+	if err := seq.Step(ctx, startup.Component{Name: "neo4j", Phase: startup.PhaseRepositories, Critical: true}, func(ctx context.Context) error {
+		var err error
+		neo4jClient, err = neo4j.NewClient(cfg.Neo4j, logger)
+		return err
+	}); err != nil {
 		logger.Error("Failed to connect to Neo4j", "error", err)
 		os.Exit(1)
 	}
 	defer neo4jClient.Close()
 
+	// Route each tenant's queries to its own database
+	if cfg.Tenancy.Enabled {
+		neo4jClient.SetTenancy(cfg.Tenancy)
+	}
+
 	// Initialize Kafka producer
-	kafkaProducer, err := kafka.NewProducer(cfg.Kafka, logger)
-	if err != nil {
+	var kafkaProducer *kafka.Producer
+	if err := seq.Step(ctx, startup.Component{Name: "kafka-producer", Phase: startup.PhaseRepositories, Critical: true}, func(ctx context.Context) error {
+		var err error
+		kafkaProducer, err = kafka.NewProducer(cfg.Kafka, logger)
+		return err
+	}); err != nil {
 		logger.Error("Failed to create Kafka producer", "error", err)
 		os.Exit(1)
 	}
@@ -109,6 +154,11 @@ func main() {
 		interceptors.StreamRecoveryInterceptor(logger),
 	}
 
+	if cfg.Tenancy.Enabled {
+		unaryInterceptors = append(unaryInterceptors, tenancy.UnaryServerInterceptor(cfg.Tenancy))
+		streamInterceptors = append(streamInterceptors, tenancy.StreamServerInterceptor(cfg.Tenancy))
+	}
+
 	// Create gRPC server with interceptors
 	grpcSrv := grpc.NewServer(
 		grpc.UnaryInterceptor(interceptors.ChainUnaryInterceptors(unaryInterceptors...)),
@@ -127,8 +177,24 @@ func main() {
 	// Initialize entity resolver
 	entityResolver := resolution.NewEntityResolver(neo4jClient, logger)
 
+	// Initialize entity resolution blocking
+	blocker := blocking.NewBlocker(neo4jClient, cfg.Blocking, logger)
+	if cfg.Tenancy.Enabled {
+		blocker.SetTenants(neo4jClient)
+	}
+	if cfg.Blocking.Enabled {
+		entityResolver.SetBlocker(blocker)
+	}
+
+	// Initialize cross-border flow analyzer
+	flowAnalyzer := geo.NewFlowAnalyzer(neo4jClient, cfg.Geo, logger)
+
+	// Initialize network thumbnail renderer
+	thumbnailRenderer := thumbnail.NewRenderer(neo4jClient, cfg.Thumbnail, logger)
+
 	// Initialize HTTP handlers
 	httpHandlers := handlers.NewHTTPHandlers(graphEngine, cfg, logger)
+	httpHandlers.SetReadiness(seq.Readiness())
 	enhancedHandlers := handlers.NewEnhancedHTTPHandlers(
 		graphEngine,
 		patternDetector,
@@ -137,6 +203,61 @@ func main() {
 		cfg,
 		logger,
 	)
+	geoHandlers := handlers.NewGeoHTTPHandlers(flowAnalyzer, logger)
+	thumbnailHandlers := handlers.NewThumbnailHTTPHandlers(thumbnailRenderer, logger)
+
+	// Initialize entity access auditing and excessive browsing detection
+	accessDetector := accessaudit.NewDetector(repo, kafkaProducer, cfg.AccessAudit, logger)
+	accessAuditHandlers := handlers.NewAccessAuditHTTPHandlers(repo, accessDetector, logger)
+
+	// Initialize graph snapshot backups
+	backupStore, err := backup.NewStore(cfg.Backup)
+	if err != nil {
+		logger.Error("Failed to create backup store", "error", err)
+		os.Exit(1)
+	}
+	backupService := backup.NewService(backupStore, neo4jClient, cfg.Backup, logger)
+	backupHandlers := handlers.NewBackupHTTPHandlers(backupService, logger)
+
+	// Initialize scheduled graph maintenance; expired relationships are
+	// archived to the backup store
+	maintenanceService := maintenance.NewService(neo4jClient, backupStore, cfg.Maintenance, logger)
+	if cfg.Tenancy.Enabled {
+		maintenanceService.SetTenants(neo4jClient)
+	}
+	maintenanceHandlers := handlers.NewMaintenanceHTTPHandlers(maintenanceService, logger)
+
+	// Initialize exposure to sanctioned and flagged entities; band changes
+	// are published for rule evaluation
+	exposureService := exposure.NewService(neo4jClient, kafkaProducer, cfg.Exposure, logger)
+	if cfg.Tenancy.Enabled {
+		exposureService.SetTenants(neo4jClient)
+	}
+	exposureHandlers := handlers.NewExposureHTTPHandlers(exposureService, logger)
+
+	// Initialize money-laundering motif detection, served on demand by the
+	// pattern detector and gRPC; scheduled findings are published for
+	// alerting
+	motifLibrary := patterns.NewMotifLibrary(neo4jClient, kafkaProducer, cfg.Motifs, logger)
+	if cfg.Tenancy.Enabled {
+		motifLibrary.SetTenants(neo4jClient)
+	}
+	patternDetector.SetMotifs(motifLibrary)
+	grpcServer.SetMotifs(motifLibrary)
+
+	// Initialize saved query templates, run read-only in the caller's
+	// database with every execution recorded
+	queryTemplateService := querytemplates.NewService(repo, neo4jClient, cfg.QueryTemplates, logger)
+	queryTemplateHandlers := handlers.NewQueryTemplateHTTPHandlers(queryTemplateService, cfg.QueryTemplates, logger)
+
+	// Initialize versioned relationship writes, so neighborhood, path and
+	// pattern reads can be made as of an earlier time
+	edgeWriter := temporal.NewWriter(neo4jClient, logger)
+	temporalHandlers := handlers.NewTemporalHTTPHandlers(edgeWriter, logger)
+
+	// Initialize tenant database provisioning and monitoring
+	tenantHandlers := handlers.NewTenantHTTPHandlers(neo4jClient, logger)
+	tenantMonitor := tenancy.NewMonitor(neo4jClient, cfg.Tenancy, logger)
 
 	// Setup HTTP router
 	router := mux.NewRouter()
@@ -144,77 +265,176 @@ func main() {
 	// Register routes
 	httpHandlers.RegisterRoutes(router)
 	enhancedHandlers.RegisterEnhancedRoutes(router)
+	geoHandlers.RegisterGeoRoutes(router)
+	thumbnailHandlers.RegisterThumbnailRoutes(router)
+	accessAuditHandlers.RegisterAccessAuditRoutes(router)
+	backupHandlers.RegisterBackupRoutes(router)
+	maintenanceHandlers.RegisterMaintenanceRoutes(router)
+	exposureHandlers.RegisterExposureRoutes(router)
+	temporalHandlers.RegisterTemporalRoutes(router)
+	if cfg.QueryTemplates.Enabled {
+		queryTemplateHandlers.RegisterQueryTemplateRoutes(router)
+	}
+
+	// Serve tenant database administration, and route every other request to
+	// the database of the tenant it names
+	if cfg.Tenancy.Enabled {
+		tenantHandlers.RegisterTenantRoutes(router)
+		router.Use(tenancy.Middleware(cfg.Tenancy))
+	}
+
+	// Record who viewed which entities
+	if cfg.AccessAudit.Enabled {
+		router.Use(accessaudit.NewRecorder(repo, cfg.AccessAudit, logger).Middleware)
+	}
 	
 	// Add Prometheus metrics endpoint
 	router.Handle("/metrics", promhttp.Handler())
 
-	// Apply HTTP middleware
+	// Apply HTTP middleware; load shedding wraps the router so saturated
+	// route classes are turned away before any other work is done
+	var httpHandler http.Handler = router
+	if cfg.LoadShedding.Enabled {
+		httpHandler = loadshed.NewShedder(cfg.LoadShedding, logger).Middleware(router)
+	}
+
 	httpSrv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Server.HTTPPort),
-		Handler: router,
+		Handler: httpHandler,
 		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
 		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout) * time.Second,
 	}
 
 	// Initialize Kafka consumer
-	kafkaConsumer, err := kafka.NewConsumer(cfg.Kafka, graphEngine, logger)
-	if err != nil {
+	consumerComponent := startup.Component{Name: "kafka-consumer", Phase: startup.PhaseConsumers, Critical: true}
+	var kafkaConsumer *kafka.Consumer
+	if err := seq.Step(ctx, consumerComponent, func(ctx context.Context) error {
+		var err error
+		kafkaConsumer, err = kafka.NewConsumer(cfg.Kafka, graphEngine, logger)
+		return err
+	}); err != nil {
 		logger.Error("Failed to create Kafka consumer", "error", err)
 		os.Exit(1)
 	}
+	kafkaConsumer.SetEdgeWriter(edgeWriter)
 	defer kafkaConsumer.Close()
 
-	// Create context for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Start Kafka consumer
+	seq.Go(ctx, consumerComponent, func(ctx context.Context) error {
+		logger.Info("Starting Kafka consumer")
+		return kafkaConsumer.Start(ctx)
+	})
+
+	// Start entity access anomaly detection
+	if cfg.AccessAudit.Enabled {
+		seq.Go(ctx, startup.Component{Name: "access-detector", Phase: startup.PhaseConsumers}, func(ctx context.Context) error {
+			accessDetector.Start(ctx)
+			return nil
+		})
+	}
+
+	// Start scheduled graph snapshots
+	if cfg.Backup.Enabled {
+		seq.Go(ctx, startup.Component{Name: "graph-backup", Phase: startup.PhaseConsumers}, func(ctx context.Context) error {
+			backupService.Start(ctx)
+			return nil
+		})
+	}
+
+	// Start scheduled graph maintenance
+	if cfg.Maintenance.Enabled {
+		seq.Go(ctx, startup.Component{Name: "graph-maintenance", Phase: startup.PhaseConsumers}, func(ctx context.Context) error {
+			maintenanceService.Start(ctx)
+			return nil
+		})
+	}
+
+	// Start blocking key indexing
+	if cfg.Blocking.Enabled {
+		seq.Go(ctx, startup.Component{Name: "blocking-indexer", Phase: startup.PhaseConsumers}, func(ctx context.Context) error {
+			blocker.Start(ctx)
+			return nil
+		})
+	}
+
+	// Start exposure recomputation
+	if cfg.Exposure.Enabled {
+		seq.Go(ctx, startup.Component{Name: "exposure-recompute", Phase: startup.PhaseConsumers}, func(ctx context.Context) error {
+			exposureService.Start(ctx)
+			return nil
+		})
+	}
+
+	// Start scheduled motif detection
+	if cfg.Motifs.Enabled {
+		seq.Go(ctx, startup.Component{Name: "motif-detection", Phase: startup.PhaseConsumers}, func(ctx context.Context) error {
+			motifLibrary.Start(ctx)
+			return nil
+		})
+	}
+
+	// Start tenant database health and size monitoring
+	if cfg.Tenancy.Enabled {
+		seq.Go(ctx, startup.Component{Name: "tenant-monitor", Phase: startup.PhaseConsumers}, func(ctx context.Context) error {
+			tenantMonitor.Start(ctx)
+			return nil
+		})
+	}
 
 	// Start gRPC server
-	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.GRPCPort))
-	if err != nil {
+	grpcComponent := startup.Component{Name: "grpc-server", Phase: startup.PhaseServers, Critical: true}
+	var grpcListener net.Listener
+	if err := seq.Step(ctx, grpcComponent, func(ctx context.Context) error {
+		var err error
+		grpcListener, err = net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.GRPCPort))
+		return err
+	}); err != nil {
 		logger.Error("Failed to create gRPC listener", "error", err)
 		os.Exit(1)
 	}
 
-	go func() {
+	seq.Go(ctx, grpcComponent, func(ctx context.Context) error {
 		logger.Info("Starting gRPC server", "port", cfg.Server.GRPCPort)
-		if err := grpcSrv.Serve(grpcListener); err != nil {
-			logger.Error("gRPC server failed", "error", err)
-			cancel()
-		}
-	}()
+		return grpcSrv.Serve(grpcListener)
+	})
 
 	// Start HTTP server
-	go func() {
-		logger.Info("Starting HTTP server", "port", cfg.Server.HTTPPort)
-		if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error("HTTP server failed", "error", err)
-			cancel()
-		}
-	}()
+	httpComponent := startup.Component{Name: "http-server", Phase: startup.PhaseServers, Critical: true}
+	var httpListener net.Listener
+	if err := seq.Step(ctx, httpComponent, func(ctx context.Context) error {
+		var err error
+		httpListener, err = net.Listen("tcp", httpSrv.Addr)
+		return err
+	}); err != nil {
+		logger.Error("Failed to create HTTP listener", "error", err)
+		os.Exit(1)
+	}
 
-	// Start Kafka consumer
-	go func() {
-		logger.Info("Starting Kafka consumer")
-		if err := kafkaConsumer.Start(ctx); err != nil && err != context.Canceled {
-			logger.Error("Kafka consumer failed", "error", err)
-			cancel()
+	seq.Go(ctx, httpComponent, func(ctx context.Context) error {
+		logger.Info("Starting HTTP server", "port", cfg.Server.HTTPPort)
+		if err := httpSrv.Serve(httpListener); err != nil && err != http.ErrServerClosed {
+			return err
 		}
-	}()
+		return nil
+	})
 
-	// Wait for shutdown signal
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	seq.Complete()
 
+	// Wait for shutdown signal or a critical component to stop
 	select {
-	case sig := <-sigChan:
-		logger.Info("Received shutdown signal", "signal", sig)
 	case <-ctx.Done():
-		logger.Info("Context cancelled")
+		logger.Info("Received shutdown signal")
+	case <-seq.Done():
+		logger.Error("Critical component stopped", "error", seq.Err())
 	}
 
 	// Graceful shutdown
 	logger.Info("Starting graceful shutdown")
+	seq.Readiness().Drain()
+
+	// Cancel context to stop the Kafka consumer and background detection
+	cancel()
 
 	// Stop gRPC server
 	grpcSrv.GracefulStop()
@@ -226,8 +446,8 @@ func main() {
 		logger.Error("HTTP server shutdown failed", "error", err)
 	}
 
-	// Cancel context to stop Kafka consumer
-	cancel()
+	// Wait for the consumer and servers to return
+	seq.Wait()
 
 	logger.Info("Graph Engine Service shutdown completed")
 }
\ No newline at end of file
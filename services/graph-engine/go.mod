@@ -18,6 +18,8 @@ require (
 	github.com/gonum/matrix v0.0.0-20181209220409-c518dec07be9
 	github.com/yourbasic/graph v0.0.0-20210606180040-8ecfec1c2869
 	github.com/dominikbraun/graph v0.23.0
+	github.com/testcontainers/testcontainers-go v0.25.0
+	github.com/testcontainers/testcontainers-go/modules/neo4j v0.25.0
 )
 
 require (
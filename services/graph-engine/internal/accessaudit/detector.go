@@ -0,0 +1,213 @@
+package accessaudit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/database"
+	"github.com/aegisshield/graph-engine/internal/kafka"
+)
+
+// AnomalyType identifies excessive browsing in published anomaly events
+const AnomalyType = "excessive_entity_browsing"
+
+// maxSampleEntities bounds the entity IDs kept on an alert
+const maxSampleEntities = 25
+
+// Finding is a user who viewed more entities outside their assigned
+// investigations than the configured limit
+type Finding struct {
+	UserID           string
+	DistinctEntities int
+	Unassigned       []string
+}
+
+// AnomalyPublisher publishes anomaly events for downstream alerting
+type AnomalyPublisher interface {
+	PublishAnomalyDetected(ctx context.Context, event *kafka.AnomalyDetectedEvent) error
+}
+
+// Detector periodically looks for users browsing entities outside their
+// assigned investigations
+type Detector struct {
+	repo      *database.Repository
+	publisher AnomalyPublisher
+	cfg       config.AccessAuditConfig
+	logger    *slog.Logger
+}
+
+// NewDetector creates an excessive browsing detector. A nil publisher only
+// stores alerts.
+func NewDetector(repo *database.Repository, publisher AnomalyPublisher, cfg config.AccessAuditConfig, logger *slog.Logger) *Detector {
+	return &Detector{
+		repo:      repo,
+		publisher: publisher,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+// Start runs detection every DetectionInterval until the context is cancelled
+func (d *Detector) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.DetectionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.Detect(ctx); err != nil {
+				d.logger.Error("Entity access anomaly detection failed", "error", err)
+			}
+		}
+	}
+}
+
+// Detect checks the last DetectionWindow of views and raises an alert for
+// each user over the limit who has not been alerted on within AlertCooldown
+func (d *Detector) Detect(ctx context.Context) ([]*database.EntityAccessAlert, error) {
+	windowEnd := time.Now()
+	windowStart := windowEnd.Add(-d.cfg.DetectionWindow)
+
+	views, err := d.repo.ListViewedEntitiesByUser(ctx, windowStart)
+	if err != nil {
+		return nil, err
+	}
+	delete(views, UnknownUser)
+	if len(views) == 0 {
+		return nil, nil
+	}
+
+	userIDs := make([]string, 0, len(views))
+	for userID := range views {
+		userIDs = append(userIDs, userID)
+	}
+
+	assigned, err := d.repo.ListAssignedEntities(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []*database.EntityAccessAlert
+	for _, finding := range FindExcessiveBrowsing(views, assigned, d.cfg.MaxUnassignedEntities) {
+		recent, err := d.repo.HasRecentEntityAccessAlert(ctx, finding.UserID, windowEnd.Add(-d.cfg.AlertCooldown))
+		if err != nil {
+			return alerts, err
+		}
+		if recent {
+			continue
+		}
+
+		sample := finding.Unassigned
+		if len(sample) > maxSampleEntities {
+			sample = sample[:maxSampleEntities]
+		}
+
+		alert := &database.EntityAccessAlert{
+			ID:                 uuid.New().String(),
+			UserID:             finding.UserID,
+			WindowStart:        windowStart,
+			WindowEnd:          windowEnd,
+			DistinctEntities:   finding.DistinctEntities,
+			UnassignedEntities: len(finding.Unassigned),
+			SampleEntityIDs:    sample,
+			Severity:           Severity(len(finding.Unassigned), d.cfg.MaxUnassignedEntities),
+			Status:             "open",
+			CreatedAt:          windowEnd,
+		}
+		if err := d.repo.CreateEntityAccessAlert(ctx, alert); err != nil {
+			return alerts, err
+		}
+		alerts = append(alerts, alert)
+
+		d.publish(ctx, alert)
+	}
+
+	if len(alerts) > 0 {
+		d.logger.Warn("Excessive entity browsing detected", "alerts", len(alerts))
+	}
+	return alerts, nil
+}
+
+func (d *Detector) publish(ctx context.Context, alert *database.EntityAccessAlert) {
+	if d.publisher == nil {
+		return
+	}
+
+	event := &kafka.AnomalyDetectedEvent{
+		AnomalyID:   alert.ID,
+		AnomalyType: AnomalyType,
+		EntityIDs:   alert.SampleEntityIDs,
+		Severity:    alert.Severity,
+		Confidence:  1,
+		DetectedAt:  alert.CreatedAt,
+		Description: fmt.Sprintf("User %s viewed %d entities outside their assigned investigations in %s",
+			alert.UserID, alert.UnassignedEntities, d.cfg.DetectionWindow),
+		Evidence: map[string]interface{}{
+			"user_id":             alert.UserID,
+			"distinct_entities":   alert.DistinctEntities,
+			"unassigned_entities": alert.UnassignedEntities,
+			"window_start":        alert.WindowStart,
+			"window_end":          alert.WindowEnd,
+		},
+		BaselineData: map[string]interface{}{
+			"max_unassigned_entities": d.cfg.MaxUnassignedEntities,
+		},
+	}
+
+	if err := d.publisher.PublishAnomalyDetected(ctx, event); err != nil {
+		d.logger.Error("Failed to publish entity access anomaly", "alert_id", alert.ID, "error", err)
+	}
+}
+
+// FindExcessiveBrowsing returns the users whose viewed entities outside
+// their assigned investigations exceed limit, most excessive first
+func FindExcessiveBrowsing(views map[string][]string, assigned map[string]map[string]bool, limit int) []Finding {
+	var findings []Finding
+	for userID, entityIDs := range views {
+		var unassigned []string
+		for _, entityID := range entityIDs {
+			if !assigned[userID][entityID] {
+				unassigned = append(unassigned, entityID)
+			}
+		}
+
+		if len(unassigned) <= limit {
+			continue
+		}
+
+		sort.Strings(unassigned)
+		findings = append(findings, Finding{
+			UserID:           userID,
+			DistinctEntities: len(entityIDs),
+			Unassigned:       unassigned,
+		})
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		if len(findings[i].Unassigned) != len(findings[j].Unassigned) {
+			return len(findings[i].Unassigned) > len(findings[j].Unassigned)
+		}
+		return findings[i].UserID < findings[j].UserID
+	})
+	return findings
+}
+
+// Severity grades an alert by how far the user went over the limit
+func Severity(unassigned, limit int) string {
+	switch {
+	case limit > 0 && unassigned >= 4*limit:
+		return "critical"
+	case limit > 0 && unassigned >= 2*limit:
+		return "high"
+	default:
+		return "medium"
+	}
+}
@@ -0,0 +1,246 @@
+package accessaudit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/database"
+)
+
+// UnknownUser is recorded for views without a user header
+const UnknownUser = "unknown"
+
+// auditedViews maps the routes that reveal entity data to the view type they
+// are recorded as. Routes are keyed by method and mux path template.
+var auditedViews = map[string]string{
+	"GET /api/v1/entities/{id}/neighborhood":       "neighborhood",
+	"GET /api/v1/entities/{id}/metrics":            "entity_metrics",
+	"GET /api/v1/analytics/centrality/{entity_id}": "centrality",
+	"GET /api/v1/resolution/matches/{entity_id}":   "entity_matches",
+	"POST /api/v1/analysis/subgraph":               "subgraph",
+	"POST /api/v1/analysis/paths":                  "paths",
+	"POST /api/v1/analysis/metrics":                "metrics",
+	"POST /api/v1/analytics/paths":                 "paths",
+	"POST /api/v1/analytics/influence":             "influence",
+	"POST /api/v1/analysis/investigation-support":  "investigation_support",
+	"POST /api/v1/geo/cross-border-flows":          "cross_border_flows",
+	"GET /api/v1/geo/corridors":                    "corridors",
+	"GET /api/v1/graph/thumbnail":                  "thumbnail",
+	"POST /api/v1/graph/thumbnail":                 "thumbnail",
+}
+
+// entityIDFields are the path, query and body fields that name entities
+var entityIDFields = []string{
+	"id", "entity_id", "entity_ids", "source_id", "source_ids", "target_id", "target_ids",
+}
+
+// Store persists entity access events
+type Store interface {
+	RecordEntityAccess(ctx context.Context, event *database.EntityAccessEvent) error
+}
+
+// Recorder is HTTP middleware recording who viewed which entities
+type Recorder struct {
+	store  Store
+	cfg    config.AccessAuditConfig
+	logger *slog.Logger
+}
+
+// NewRecorder creates an entity access recorder
+func NewRecorder(store Store, cfg config.AccessAuditConfig, logger *slog.Logger) *Recorder {
+	return &Recorder{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Middleware records every audited view after it has been served. Failed
+// views are recorded too, with their status code, since attempts matter for
+// insider-risk review.
+func (rec *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		viewType, ok := viewTypeFor(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, truncated := rec.captureBody(r)
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		entityIDs := ExtractEntityIDs(mux.Vars(r), r.URL.Query(), body)
+		if len(entityIDs) == 0 {
+			return
+		}
+
+		event := &database.EntityAccessEvent{
+			ID:              uuid.New().String(),
+			UserID:          rec.userID(r),
+			EntityIDs:       entityIDs,
+			ViewType:        viewType,
+			Method:          r.Method,
+			Path:            r.URL.Path,
+			QueryParams:     queryParams(r.URL.Query(), body, truncated),
+			InvestigationID: rec.investigationID(r),
+			ClientIP:        clientIP(r),
+			StatusCode:      sw.status,
+			AccessedAt:      time.Now(),
+		}
+
+		// The request context may already be cancelled once the response is written
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 5*time.Second)
+		defer cancel()
+		if err := rec.store.RecordEntityAccess(ctx, event); err != nil {
+			rec.logger.Error("Failed to record entity access",
+				"user_id", event.UserID,
+				"view_type", viewType,
+				"error", err)
+		}
+	})
+}
+
+// captureBody reads up to MaxRecordedBodyBytes of a JSON body and restores
+// it for the handler. Larger bodies are passed through and not recorded.
+func (rec *Recorder) captureBody(r *http.Request) ([]byte, bool) {
+	if r.Body == nil || r.Method == http.MethodGet {
+		return nil, false
+	}
+
+	limit := rec.cfg.MaxRecordedBodyBytes
+	if limit <= 0 {
+		limit = 64 * 1024
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	if err != nil || int64(len(body)) > limit {
+		return nil, true
+	}
+	return body, false
+}
+
+func (rec *Recorder) userID(r *http.Request) string {
+	if userID := strings.TrimSpace(r.Header.Get(rec.cfg.UserHeader)); userID != "" {
+		return userID
+	}
+	return UnknownUser
+}
+
+func (rec *Recorder) investigationID(r *http.Request) string {
+	if id := strings.TrimSpace(r.Header.Get(rec.cfg.InvestigationHeader)); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("investigation_id")
+}
+
+// ExtractEntityIDs collects the entity IDs a view names in its path variables,
+// query string and JSON body, deduplicated and sorted
+func ExtractEntityIDs(vars map[string]string, query url.Values, body []byte) []string {
+	seen := make(map[string]bool)
+	add := func(values ...string) {
+		for _, value := range values {
+			for _, id := range strings.Split(value, ",") {
+				if id = strings.TrimSpace(id); id != "" {
+					seen[id] = true
+				}
+			}
+		}
+	}
+
+	var fields map[string]interface{}
+	if len(body) > 0 {
+		json.Unmarshal(body, &fields)
+	}
+
+	for _, field := range entityIDFields {
+		add(vars[field])
+		add(query[field]...)
+
+		switch value := fields[field].(type) {
+		case string:
+			add(value)
+		case []interface{}:
+			for _, item := range value {
+				if id, ok := item.(string); ok {
+					add(id)
+				}
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func viewTypeFor(r *http.Request) (string, bool) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "", false
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return "", false
+	}
+
+	viewType, ok := auditedViews[r.Method+" "+template]
+	return viewType, ok
+}
+
+func queryParams(query url.Values, body []byte, truncated bool) map[string]interface{} {
+	params := make(map[string]interface{})
+	if len(query) > 0 {
+		params["query"] = query
+	}
+	if len(body) > 0 {
+		var decoded interface{}
+		if err := json.Unmarshal(body, &decoded); err == nil {
+			params["body"] = decoded
+		}
+	}
+	if truncated {
+		params["body_truncated"] = true
+	}
+	return params
+}
+
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// statusWriter captures the status code written by a handler
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
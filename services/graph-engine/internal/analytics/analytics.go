@@ -2,14 +2,17 @@ package analytics
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/aegisshield/graph-engine/internal/config"
 	"github.com/aegisshield/graph-engine/internal/neo4j"
+	"github.com/aegisshield/graph-engine/internal/temporal"
 	"github.com/google/uuid"
 )
 
@@ -111,22 +114,34 @@ type Community struct {
 	Metadata   map[string]interface{} `json:"metadata"`
 }
 
-// PathAnalysisRequest represents a path analysis request
+// PathAnalysisRequest represents a path analysis request. PathTypes limits
+// the relationship types followed. Weighted algorithms need a target and
+// read each relationship's weight from WeightProperty, using DefaultWeight
+// (1 when unset) for relationships without it.
 type PathAnalysisRequest struct {
-	SourceID      string                 `json:"source_id"`
-	TargetID      string                 `json:"target_id,omitempty"`
-	MaxDepth      int                    `json:"max_depth"`
-	MaxPaths      int                    `json:"max_paths"`
-	WeightProperty string                `json:"weight_property,omitempty"`
-	PathTypes     []string               `json:"path_types,omitempty"`
-	Filters       map[string]interface{} `json:"filters,omitempty"`
-}
-
-// PathAnalysisResult contains path analysis results
+	SourceID          string                 `json:"source_id"`
+	TargetID          string                 `json:"target_id,omitempty"`
+	MaxDepth          int                    `json:"max_depth"`
+	MaxPaths          int                    `json:"max_paths"`
+	Algorithm         PathAlgorithm          `json:"algorithm,omitempty"`
+	WeightProperty    string                 `json:"weight_property,omitempty"`
+	DefaultWeight     float64                `json:"default_weight,omitempty"`
+	LatitudeProperty  string                 `json:"latitude_property,omitempty"`
+	LongitudeProperty string                 `json:"longitude_property,omitempty"`
+	MinCostPerKm      float64                `json:"min_cost_per_km,omitempty"`
+	PathTypes         []string               `json:"path_types,omitempty"`
+	Filters           map[string]interface{} `json:"filters,omitempty"`
+}
+
+// PathAnalysisResult contains path analysis results. Each path carries its
+// entities and relationships with their properties; a weighted path's cost
+// is its total weight, any other path's its length.
 type PathAnalysisResult struct {
+	Algorithm        PathAlgorithm `json:"algorithm"`
 	Paths            []*neo4j.Path `json:"paths"`
 	ShortestPath     *neo4j.Path   `json:"shortest_path"`
 	ShortestDistance int           `json:"shortest_distance"`
+	ShortestCost     float64       `json:"shortest_cost"`
 	AverageDistance  float64       `json:"average_distance"`
 	PathDiversity    float64       `json:"path_diversity"`
 	ProcessingTime   time.Duration `json:"processing_time"`
@@ -590,51 +605,120 @@ func (ga *GraphAnalytics) buildCommunitiesFromResults(records []map[string]inter
 func (ga *GraphAnalytics) AnalyzePaths(ctx context.Context, req *PathAnalysisRequest) (*PathAnalysisResult, error) {
 	startTime := time.Now()
 
+	if req.Algorithm == "" {
+		req.Algorithm = PathAlgorithmShortest
+	}
+	if err := ValidateRelationshipTypes(req.PathTypes); err != nil {
+		return nil, err
+	}
+	depth := ga.pathDepth(req.MaxDepth)
+
 	ga.logger.Info("Starting path analysis",
 		"source_id", req.SourceID,
 		"target_id", req.TargetID,
-		"max_depth", req.MaxDepth)
+		"algorithm", req.Algorithm,
+		"max_depth", depth)
 
-	var query string
-	var params map[string]interface{}
+	var paths []*neo4j.Path
+	switch req.Algorithm {
+	case PathAlgorithmShortest:
+		var query string
+		var params map[string]interface{}
+
+		if req.TargetID != "" {
+			// Analyze paths between specific source and target
+			query, params = ga.buildSpecificPathQuery(ctx, req, depth)
+		} else {
+			// Analyze all paths from source
+			query, params = ga.buildAllPathsQuery(ctx, req, depth)
+		}
 
-	if req.TargetID != "" {
-		// Analyze paths between specific source and target
-		query, params = ga.buildSpecificPathQuery(req)
-	} else {
-		// Analyze all paths from source
-		query, params = ga.buildAllPathsQuery(req)
-	}
+		records, err := ga.neo4jClient.ExecuteQuery(ctx, query, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute path analysis query: %w", err)
+		}
+		paths = ga.buildPathsFromResults(records)
 
-	records, err := ga.neo4jClient.ExecuteQuery(ctx, query, params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute path analysis query: %w", err)
+	case PathAlgorithmDijkstra, PathAlgorithmAStar:
+		path, err := ga.findWeightedPath(ctx, req, depth)
+		if err != nil && !errors.Is(err, ErrNoPath) {
+			return nil, err
+		}
+		if path != nil {
+			paths = append(paths, path)
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: unknown algorithm %q", ErrInvalidPathRequest, req.Algorithm)
 	}
 
 	result := &PathAnalysisResult{
-		ProcessingTime: time.Since(startTime),
+		Algorithm: req.Algorithm,
+		Paths:     paths,
 	}
 
-	// Build paths from results
-	paths := ga.buildPathsFromResults(records)
-	result.Paths = paths
-
 	// Calculate path statistics
 	if len(paths) > 0 {
 		result.ShortestPath = ga.findShortestPath(paths)
 		result.ShortestDistance = result.ShortestPath.Length
+		result.ShortestCost = result.ShortestPath.Cost
 		result.AverageDistance = ga.calculateAverageDistance(paths)
 		result.PathDiversity = ga.calculatePathDiversity(paths)
 	}
+	result.ProcessingTime = time.Since(startTime)
 
 	ga.logger.Info("Path analysis completed",
 		"paths_found", len(paths),
 		"shortest_distance", result.ShortestDistance,
+		"shortest_cost", result.ShortestCost,
 		"processing_time", result.ProcessingTime)
 
 	return result, nil
 }
 
+// findWeightedPath loads the neighbourhood within depth hops of the source
+// and searches it for the cheapest path to the target
+func (ga *GraphAnalytics) findWeightedPath(ctx context.Context, req *PathAnalysisRequest, depth int) (*neo4j.Path, error) {
+	if req.TargetID == "" {
+		return nil, fmt.Errorf("%w: %s needs a target_id", ErrInvalidPathRequest, req.Algorithm)
+	}
+
+	opts := WeightedPathOptions{
+		Algorithm:         req.Algorithm,
+		WeightProperty:    req.WeightProperty,
+		DefaultWeight:     req.DefaultWeight,
+		MaxHops:           depth,
+		RelationshipTypes: req.PathTypes,
+		LatitudeProperty:  req.LatitudeProperty,
+		LongitudeProperty: req.LongitudeProperty,
+		MinCostPerKm:      req.MinCostPerKm,
+	}
+	if opts.DefaultWeight == 0 {
+		opts.DefaultWeight = 1
+	}
+	if opts.LatitudeProperty == "" {
+		opts.LatitudeProperty = "latitude"
+	}
+	if opts.LongitudeProperty == "" {
+		opts.LongitudeProperty = "longitude"
+	}
+
+	sub, err := ga.neo4jClient.GetSubGraph(ctx, []string{req.SourceID}, depth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load path neighbourhood: %w", err)
+	}
+
+	return WeightedShortestPath(sub, req.SourceID, req.TargetID, opts)
+}
+
+// pathDepth bounds a requested depth by the configured maximum path length
+func (ga *GraphAnalytics) pathDepth(requested int) int {
+	if requested <= 0 || requested > ga.config.MaxPathLength {
+		return ga.config.MaxPathLength
+	}
+	return requested
+}
+
 // AnalyzeInfluence performs influence analysis on the network
 func (ga *GraphAnalytics) AnalyzeInfluence(ctx context.Context, req *InfluenceAnalysisRequest) (*InfluenceAnalysisResult, error) {
 	startTime := time.Now()
@@ -699,46 +783,57 @@ func (ga *GraphAnalytics) AnalyzeInfluence(ctx context.Context, req *InfluenceAn
 
 // Helper methods
 
-func (ga *GraphAnalytics) buildSpecificPathQuery(req *PathAnalysisRequest) (string, map[string]interface{}) {
-	query := `
-		MATCH path = shortestPath((source {id: $sourceId})-[*1..$maxDepth]-(target {id: $targetId}))
+// Variable-length bounds cannot be parameters in Cypher, so the depth,
+// already bounded by pathDepth, and the validated relationship types are
+// written into the pattern. Paths only follow relationship versions valid
+// at the context's as-of time.
+
+func (ga *GraphAnalytics) buildSpecificPathQuery(ctx context.Context, req *PathAnalysisRequest, depth int) (string, map[string]interface{}) {
+	query := fmt.Sprintf(`
+		MATCH (source:Entity {id: $sourceId}), (target:Entity {id: $targetId})
+		MATCH path = allShortestPaths((source)-[%s*1..%d]-(target))
+		WHERE %s
 		RETURN path,
-			   length(path) as pathLength,
-			   [r IN relationships(path) | r.amount] as amounts
-		ORDER BY pathLength
+			   length(path) as pathLength
 		LIMIT $maxPaths
-	`
+	`, relationshipPattern(req.PathTypes), depth, temporal.Path(ctx, "path"))
 
-	params := map[string]interface{}{
+	params := temporal.Bind(ctx, map[string]interface{}{
 		"sourceId": req.SourceID,
 		"targetId": req.TargetID,
-		"maxDepth": req.MaxDepth,
 		"maxPaths": req.MaxPaths,
-	}
+	})
 
 	return query, params
 }
 
-func (ga *GraphAnalytics) buildAllPathsQuery(req *PathAnalysisRequest) (string, map[string]interface{}) {
-	query := `
-		MATCH path = (source {id: $sourceId})-[*1..$maxDepth]-(target)
-		WHERE source <> target
+func (ga *GraphAnalytics) buildAllPathsQuery(ctx context.Context, req *PathAnalysisRequest, depth int) (string, map[string]interface{}) {
+	query := fmt.Sprintf(`
+		MATCH path = (source:Entity {id: $sourceId})-[%s*1..%d]-(target:Entity)
+		WHERE source <> target AND %s
 		RETURN path,
 			   length(path) as pathLength,
 			   target.id as targetId
 		ORDER BY pathLength
 		LIMIT $maxPaths
-	`
+	`, relationshipPattern(req.PathTypes), depth, temporal.Path(ctx, "path"))
 
-	params := map[string]interface{}{
+	params := temporal.Bind(ctx, map[string]interface{}{
 		"sourceId": req.SourceID,
-		"maxDepth": req.MaxDepth,
 		"maxPaths": req.MaxPaths,
-	}
+	})
 
 	return query, params
 }
 
+// relationshipPattern is the type part of a relationship pattern
+func relationshipPattern(types []string) string {
+	if len(types) == 0 {
+		return ""
+	}
+	return ":" + strings.Join(types, "|")
+}
+
 func (ga *GraphAnalytics) buildInfluenceQuery(req *InfluenceAnalysisRequest) (string, map[string]interface{}) {
 	// Use PageRank algorithm for influence analysis
 	query := `
@@ -768,12 +863,12 @@ func (ga *GraphAnalytics) buildPathsFromResults(records []map[string]interface{}
 			continue
 		}
 
-		path := &neo4j.Path{
-			Length: int(pathLength),
+		path, ok := ga.neo4jClient.ToPath(record["path"])
+		if !ok {
+			path = &neo4j.Path{}
 		}
-
-		// Extract additional path information from record
-		// This would be implemented based on the actual Neo4j record structure
+		path.Length = int(pathLength)
+		path.Cost = float64(pathLength)
 
 		paths = append(paths, path)
 	}
@@ -788,7 +883,7 @@ func (ga *GraphAnalytics) findShortestPath(paths []*neo4j.Path) *neo4j.Path {
 
 	shortest := paths[0]
 	for _, path := range paths {
-		if path.Length < shortest.Length {
+		if path.Cost < shortest.Cost || (path.Cost == shortest.Cost && path.Length < shortest.Length) {
 			shortest = path
 		}
 	}
@@ -0,0 +1,275 @@
+package analytics
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/aegisshield/graph-engine/internal/neo4j"
+)
+
+// PathAlgorithm represents how paths are searched for
+type PathAlgorithm string
+
+const (
+	// PathAlgorithmShortest finds the paths with the fewest hops
+	PathAlgorithmShortest PathAlgorithm = "shortest"
+	// PathAlgorithmDijkstra finds the path with the lowest total weight
+	PathAlgorithmDijkstra PathAlgorithm = "dijkstra"
+	// PathAlgorithmAStar finds the path with the lowest total weight,
+	// guided by the great-circle distance between entities' coordinates
+	PathAlgorithmAStar PathAlgorithm = "astar"
+)
+
+var (
+	// ErrInvalidPathRequest is returned for path requests that cannot be run
+	ErrInvalidPathRequest = errors.New("invalid path request")
+	// ErrNoPath is returned when no path within the hop limit connects the
+	// source and target
+	ErrNoPath = errors.New("no path found")
+)
+
+// relationshipType is what a relationship type filter may look like; types
+// are interpolated into Cypher
+var relationshipType = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// earthRadiusKm is the mean Earth radius used for great-circle distances
+const earthRadiusKm = 6371.0
+
+// WeightedPathOptions controls a weighted shortest path search
+type WeightedPathOptions struct {
+	Algorithm         PathAlgorithm
+	WeightProperty    string   // relationship property holding the weight
+	DefaultWeight     float64  // weight of relationships without the property
+	MaxHops           int      // longest path considered
+	RelationshipTypes []string // empty follows every type
+	LatitudeProperty  string   // A*: entity coordinate properties
+	LongitudeProperty string
+	MinCostPerKm      float64 // A*: lowest weight any path can cost per kilometre
+}
+
+// WeightedShortestPath finds the path of at most MaxHops relationships with
+// the lowest total weight between two entities of a subgraph, following
+// relationships in either direction. A* expands entities closer to the
+// target first; its heuristic is the great-circle distance to the target
+// times MinCostPerKm, which must not overestimate the remaining weight for
+// the path found to be the cheapest. Entities without coordinates get no
+// estimate, so A* degrades to Dijkstra around them.
+func WeightedShortestPath(sub *neo4j.SubGraph, sourceID, targetID string, opts WeightedPathOptions) (*neo4j.Path, error) {
+	if opts.Algorithm != PathAlgorithmDijkstra && opts.Algorithm != PathAlgorithmAStar {
+		return nil, fmt.Errorf("%w: unsupported weighted algorithm %q", ErrInvalidPathRequest, opts.Algorithm)
+	}
+	if opts.MaxHops <= 0 {
+		return nil, fmt.Errorf("%w: max hops must be positive", ErrInvalidPathRequest)
+	}
+	if opts.DefaultWeight < 0 || opts.MinCostPerKm < 0 {
+		return nil, fmt.Errorf("%w: default weight and cost per km must not be negative", ErrInvalidPathRequest)
+	}
+
+	entities := make(map[string]*neo4j.Entity, len(sub.Entities))
+	for _, entity := range sub.Entities {
+		entities[entity.ID] = entity
+	}
+	if entities[sourceID] == nil || entities[targetID] == nil {
+		return nil, ErrNoPath
+	}
+
+	types := make(map[string]bool, len(opts.RelationshipTypes))
+	for _, relType := range opts.RelationshipTypes {
+		types[relType] = true
+	}
+
+	// Undirected adjacency over the relationships followed
+	adjacency := make(map[string][]weightedEdge)
+	for _, rel := range sub.Relationships {
+		if len(types) > 0 && !types[rel.Type] {
+			continue
+		}
+		if entities[rel.SourceID] == nil || entities[rel.TargetID] == nil {
+			continue
+		}
+
+		weight := opts.DefaultWeight
+		if opts.WeightProperty != "" {
+			if value, ok := numericProperty(rel.Properties, opts.WeightProperty); ok {
+				weight = value
+			}
+		}
+		if weight < 0 || math.IsNaN(weight) {
+			return nil, fmt.Errorf("%w: relationship %s has negative weight %v", ErrInvalidPathRequest, rel.ID, weight)
+		}
+
+		adjacency[rel.SourceID] = append(adjacency[rel.SourceID], weightedEdge{to: rel.TargetID, weight: weight, rel: rel})
+		if rel.TargetID != rel.SourceID {
+			adjacency[rel.TargetID] = append(adjacency[rel.TargetID], weightedEdge{to: rel.SourceID, weight: weight, rel: rel})
+		}
+	}
+
+	estimate := func(string) float64 { return 0 }
+	if opts.Algorithm == PathAlgorithmAStar && opts.MinCostPerKm > 0 {
+		targetLat, targetLon, ok := coordinates(entities[targetID], opts)
+		if ok {
+			estimate = func(id string) float64 {
+				lat, lon, ok := coordinates(entities[id], opts)
+				if !ok {
+					return 0
+				}
+				return haversineKm(lat, lon, targetLat, targetLon) * opts.MinCostPerKm
+			}
+		}
+	}
+
+	// Search states are (entity, hops) so the hop limit cannot cut off a
+	// cheaper path that reaches an entity in more hops
+	start := &searchState{entityID: sourceID, estimate: estimate(sourceID)}
+	best := map[stateKey]float64{{sourceID, 0}: 0}
+	queue := &stateQueue{start}
+
+	for queue.Len() > 0 {
+		state := heap.Pop(queue).(*searchState)
+		if state.entityID == targetID {
+			return buildWeightedPath(state, entities), nil
+		}
+		if cost, ok := best[stateKey{state.entityID, state.hops}]; ok && state.cost > cost {
+			continue // superseded
+		}
+		if state.hops == opts.MaxHops {
+			continue
+		}
+
+		for _, edge := range adjacency[state.entityID] {
+			if state.visits(edge.to) {
+				continue // paths are simple
+			}
+			next := &searchState{
+				entityID: edge.to,
+				hops:     state.hops + 1,
+				cost:     state.cost + edge.weight,
+				estimate: estimate(edge.to),
+				via:      edge.rel,
+				prev:     state,
+			}
+			key := stateKey{next.entityID, next.hops}
+			if cost, ok := best[key]; ok && cost <= next.cost {
+				continue
+			}
+			best[key] = next.cost
+			heap.Push(queue, next)
+		}
+	}
+
+	return nil, ErrNoPath
+}
+
+// ValidateRelationshipTypes checks relationship type filters before they
+// are interpolated into Cypher
+func ValidateRelationshipTypes(types []string) error {
+	for _, relType := range types {
+		if !relationshipType.MatchString(relType) {
+			return fmt.Errorf("%w: invalid relationship type %q", ErrInvalidPathRequest, relType)
+		}
+	}
+	return nil
+}
+
+type weightedEdge struct {
+	to     string
+	weight float64
+	rel    *neo4j.Relationship
+}
+
+type stateKey struct {
+	entityID string
+	hops     int
+}
+
+// searchState is an entity reached along a path; prev links back to the
+// source
+type searchState struct {
+	entityID string
+	hops     int
+	cost     float64
+	estimate float64
+	via      *neo4j.Relationship
+	prev     *searchState
+}
+
+func (s *searchState) visits(entityID string) bool {
+	for state := s; state != nil; state = state.prev {
+		if state.entityID == entityID {
+			return true
+		}
+	}
+	return false
+}
+
+// stateQueue orders states by estimated total cost, then by fewer hops
+type stateQueue []*searchState
+
+func (q stateQueue) Len() int { return len(q) }
+func (q stateQueue) Less(i, j int) bool {
+	pi, pj := q[i].cost+q[i].estimate, q[j].cost+q[j].estimate
+	if pi != pj {
+		return pi < pj
+	}
+	if q[i].hops != q[j].hops {
+		return q[i].hops < q[j].hops
+	}
+	return q[i].entityID < q[j].entityID
+}
+func (q stateQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *stateQueue) Push(x interface{}) { *q = append(*q, x.(*searchState)) }
+func (q *stateQueue) Pop() interface{} {
+	old := *q
+	state := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return state
+}
+
+func buildWeightedPath(end *searchState, entities map[string]*neo4j.Entity) *neo4j.Path {
+	path := &neo4j.Path{
+		Length: end.hops,
+		Cost:   end.cost,
+	}
+	for state := end; state != nil; state = state.prev {
+		path.Entities = append([]*neo4j.Entity{entities[state.entityID]}, path.Entities...)
+		if state.via != nil {
+			path.Relationships = append([]*neo4j.Relationship{state.via}, path.Relationships...)
+		}
+	}
+	path.StartEntity = path.Entities[0]
+	path.EndEntity = path.Entities[len(path.Entities)-1]
+	return path
+}
+
+func coordinates(entity *neo4j.Entity, opts WeightedPathOptions) (float64, float64, bool) {
+	lat, ok := numericProperty(entity.Properties, opts.LatitudeProperty)
+	if !ok {
+		return 0, 0, false
+	}
+	lon, ok := numericProperty(entity.Properties, opts.LongitudeProperty)
+	return lat, lon, ok
+}
+
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func numericProperty(properties map[string]interface{}, key string) (float64, bool) {
+	switch v := properties[key].(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,229 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrTargetNotEmpty is returned when restoring into a database that already
+// holds nodes without asking for it to be wiped
+var ErrTargetNotEmpty = errors.New("restore target database is not empty")
+
+// restoreLabel marks nodes created by a restore until their relationships
+// have been recreated, so endpoints can be matched by key through an index
+const restoreLabel = "BackupRestore"
+
+// restoreBatchSize bounds the rows sent in one restore query
+const restoreBatchSize = 1000
+
+// RestoreOptions controls how a graph is written back
+type RestoreOptions struct {
+	// Wipe deletes everything in the target database first
+	Wipe bool
+}
+
+// RestoreResult summarises a completed restore
+type RestoreResult struct {
+	Nodes         int           `json:"nodes"`
+	Relationships int           `json:"relationships"`
+	Duration      time.Duration `json:"duration"`
+}
+
+// Restorer writes a materialized graph into a Neo4j database
+type Restorer struct {
+	graph  QueryRunner
+	logger *slog.Logger
+}
+
+// NewRestorer creates a restorer writing through graph, which should be
+// connected to the database being rebuilt
+func NewRestorer(graph QueryRunner, logger *slog.Logger) *Restorer {
+	return &Restorer{graph: graph, logger: logger}
+}
+
+// Restore recreates every node and relationship of the graph and then checks
+// the target holds exactly as many of each
+func (r *Restorer) Restore(ctx context.Context, graph *Graph, opts RestoreOptions) (*RestoreResult, error) {
+	started := time.Now()
+
+	existing, err := r.count(ctx, `MATCH (n) RETURN count(n) AS count`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect restore target: %w", err)
+	}
+	if existing > 0 {
+		if !opts.Wipe {
+			return nil, fmt.Errorf("%w: holds %d nodes", ErrTargetNotEmpty, existing)
+		}
+		if err := r.repeat(ctx, `MATCH (n) WITH n LIMIT $limit DETACH DELETE n RETURN count(*) AS count`); err != nil {
+			return nil, fmt.Errorf("failed to wipe restore target: %w", err)
+		}
+	}
+
+	if _, err := r.graph.ExecuteQuery(ctx, fmt.Sprintf(
+		"CREATE INDEX backup_restore_key IF NOT EXISTS FOR (n:%s) ON (n._backup_key)", restoreLabel), nil); err != nil {
+		return nil, fmt.Errorf("failed to create restore index: %w", err)
+	}
+
+	if err := r.createNodes(ctx, graph); err != nil {
+		return nil, err
+	}
+	if err := r.createRelationships(ctx, graph); err != nil {
+		return nil, err
+	}
+
+	if err := r.repeat(ctx, fmt.Sprintf(
+		"MATCH (n:%[1]s) WITH n LIMIT $limit REMOVE n:%[1]s, n._backup_key RETURN count(*) AS count", restoreLabel)); err != nil {
+		return nil, fmt.Errorf("failed to clear restore markers: %w", err)
+	}
+	if _, err := r.graph.ExecuteQuery(ctx, "DROP INDEX backup_restore_key IF EXISTS", nil); err != nil {
+		r.logger.Warn("Failed to drop restore index", "error", err)
+	}
+
+	result := &RestoreResult{Duration: time.Since(started)}
+	if result.Nodes, err = r.count(ctx, `MATCH (n) RETURN count(n) AS count`); err != nil {
+		return nil, err
+	}
+	if result.Relationships, err = r.count(ctx, `MATCH ()-[r]->() RETURN count(r) AS count`); err != nil {
+		return nil, err
+	}
+	if result.Nodes != len(graph.Nodes) || result.Relationships != len(graph.Relationships) {
+		return result, fmt.Errorf("restored %d nodes and %d relationships, expected %d and %d",
+			result.Nodes, result.Relationships, len(graph.Nodes), len(graph.Relationships))
+	}
+
+	r.logger.Info("Graph restore completed",
+		"nodes", result.Nodes,
+		"relationships", result.Relationships,
+		"duration", result.Duration)
+	return result, nil
+}
+
+// createNodes creates nodes in batches of one label set, since labels cannot
+// be parameters
+func (r *Restorer) createNodes(ctx context.Context, graph *Graph) error {
+	groups := make(map[string][]map[string]interface{})
+	for _, key := range sortedKeys(graph.Nodes) {
+		node := graph.Nodes[key]
+		properties, err := DecodeProperties(node.Properties)
+		if err != nil {
+			return fmt.Errorf("failed to decode node %s: %w", key, err)
+		}
+
+		labels := append([]string{restoreLabel}, node.Labels...)
+		for i := range labels {
+			labels[i] = quoteIdentifier(labels[i])
+		}
+		pattern := strings.Join(labels, ":")
+		groups[pattern] = append(groups[pattern], map[string]interface{}{"key": key, "properties": properties})
+	}
+
+	for pattern, rows := range groups {
+		query := fmt.Sprintf("UNWIND $rows AS row CREATE (n:%s) SET n = row.properties, n._backup_key = row.key", pattern)
+		if err := r.batches(ctx, query, rows); err != nil {
+			return fmt.Errorf("failed to restore nodes: %w", err)
+		}
+	}
+	return nil
+}
+
+// createRelationships creates relationships in batches of one type
+func (r *Restorer) createRelationships(ctx context.Context, graph *Graph) error {
+	groups := make(map[string][]map[string]interface{})
+	for _, key := range sortedKeys(graph.Relationships) {
+		rel := graph.Relationships[key]
+		properties, err := DecodeProperties(rel.Properties)
+		if err != nil {
+			return fmt.Errorf("failed to decode relationship %s: %w", key, err)
+		}
+		groups[rel.Type] = append(groups[rel.Type], map[string]interface{}{
+			"source":     rel.Source,
+			"target":     rel.Target,
+			"properties": properties,
+		})
+	}
+
+	for relType, rows := range groups {
+		query := fmt.Sprintf(`
+			UNWIND $rows AS row
+			MATCH (a:%[1]s {_backup_key: row.source}), (b:%[1]s {_backup_key: row.target})
+			CREATE (a)-[r:%[2]s]->(b)
+			SET r = row.properties
+		`, restoreLabel, quoteIdentifier(relType))
+		if err := r.batches(ctx, query, rows); err != nil {
+			return fmt.Errorf("failed to restore relationships: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *Restorer) batches(ctx context.Context, query string, rows []map[string]interface{}) error {
+	for start := 0; start < len(rows); start += restoreBatchSize {
+		end := start + restoreBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		batch := make([]interface{}, 0, end-start)
+		for _, row := range rows[start:end] {
+			batch = append(batch, row)
+		}
+		if _, err := r.graph.ExecuteQuery(ctx, query, map[string]interface{}{"rows": batch}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repeat runs a batched query until it reports no more rows affected
+func (r *Restorer) repeat(ctx context.Context, query string) error {
+	for {
+		records, err := r.graph.ExecuteQuery(ctx, query, map[string]interface{}{"limit": restoreBatchSize * 10})
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 || toInt(records[0]["count"]) == 0 {
+			return nil
+		}
+	}
+}
+
+func (r *Restorer) count(ctx context.Context, query string) (int, error) {
+	records, err := r.graph.ExecuteQuery(ctx, query, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	return toInt(records[0]["count"]), nil
+}
+
+// quoteIdentifier escapes a label or relationship type for use in Cypher
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func sortedKeys(records map[string]*Record) []string {
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int64:
+		return int(v)
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
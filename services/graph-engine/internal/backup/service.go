@@ -0,0 +1,543 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+)
+
+var (
+	// ErrSnapshotNotFound is returned when a snapshot has no manifest
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+	// ErrNoSnapshot is returned when no snapshot was taken by the requested time
+	ErrNoSnapshot = errors.New("no snapshot at or before the requested time")
+	// ErrBrokenChain is returned when an incremental snapshot's parents are missing
+	ErrBrokenChain = errors.New("broken snapshot chain")
+)
+
+// QueryRunner runs Cypher against the graph
+type QueryRunner interface {
+	ExecuteQuery(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error)
+}
+
+// Service takes graph snapshots on a schedule and reads them back for
+// verification and restore
+type Service struct {
+	store  Store
+	graph  QueryRunner
+	cfg    config.BackupConfig
+	logger *slog.Logger
+	mu     sync.Mutex
+	now    func() time.Time
+}
+
+// NewService creates a backup service. graph may be nil for tools that only
+// read snapshots.
+func NewService(store Store, graph QueryRunner, cfg config.BackupConfig, logger *slog.Logger) *Service {
+	return &Service{
+		store:  store,
+		graph:  graph,
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Start takes a snapshot every Interval until the context is cancelled
+func (s *Service) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.TakeSnapshot(ctx, false); err != nil {
+				s.logger.Error("Scheduled graph snapshot failed", "error", err)
+			}
+		}
+	}
+}
+
+// TakeSnapshot exports the graph. It is incremental on the latest snapshot
+// unless full is set, there is no snapshot yet, or the chain already holds
+// FullEvery snapshots.
+func (s *Service) TakeSnapshot(ctx context.Context, full bool) (*Manifest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	manifests, err := s.ListSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	takenAt := s.now().UTC()
+	manifest := &Manifest{
+		ID:            takenAt.Format("20060102T150405Z") + "-" + uuid.New().String()[:8],
+		Kind:          KindFull,
+		TakenAt:       takenAt,
+		FormatVersion: FormatVersion,
+	}
+	manifest.BaseID = manifest.ID
+	manifest.DataKey = s.dataKey(manifest.ID)
+
+	if parent := latest(manifests); !full && parent != nil && chainLength(manifests, parent) < s.cfg.FullEvery {
+		since := parent.TakenAt
+		manifest.Kind = KindIncremental
+		manifest.ParentID = parent.ID
+		manifest.BaseID = parent.BaseID
+		manifest.Since = &since
+	}
+
+	tmp, err := os.CreateTemp("", "graph-snapshot-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	writer := newDataWriter(tmp)
+	if err := s.export(ctx, manifest, writer); err != nil {
+		return nil, err
+	}
+	if manifest.Size, manifest.SHA256, err = writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, manifest.DataKey, tmp); err != nil {
+		return nil, err
+	}
+
+	manifest.CompletedAt = s.now().UTC()
+	body, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, s.manifestKey(manifest.ID), bytes.NewReader(body)); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Graph snapshot completed",
+		"snapshot_id", manifest.ID,
+		"kind", manifest.Kind,
+		"nodes", manifest.Nodes,
+		"relationships", manifest.Relationships,
+		"size", manifest.Size,
+		"duration", manifest.CompletedAt.Sub(manifest.TakenAt))
+
+	return manifest, nil
+}
+
+// export writes the nodes and relationships whose updated_at (or created_at)
+// is at or after the manifest's Since time, or all of them for a full
+// snapshot. Entities without either timestamp are always written. Incremental snapshots also
+// record the keys of every live node and relationship so that deletions are
+// replayed on restore.
+func (s *Service) export(ctx context.Context, manifest *Manifest, writer *dataWriter) error {
+	var since interface{}
+	if manifest.Since != nil {
+		since = manifest.Since.Format(time.RFC3339Nano)
+	}
+
+	nodes, err := s.graph.ExecuteQuery(ctx, `
+		MATCH (n)
+		WITH n, coalesce(n.updated_at, n.created_at) AS changed
+		WHERE $since IS NULL OR changed IS NULL OR datetime(toString(changed)) >= datetime($since)
+		RETURN coalesce(n.id, elementId(n)) AS key, labels(n) AS labels, properties(n) AS properties
+	`, map[string]interface{}{"since": since})
+	if err != nil {
+		return fmt.Errorf("failed to export nodes: %w", err)
+	}
+	for _, row := range nodes {
+		properties, err := EncodeProperties(toMap(row["properties"]))
+		if err != nil {
+			return fmt.Errorf("failed to export node %s: %w", toString(row["key"]), err)
+		}
+		if err := writer.Write(&Record{
+			Kind:       RecordNode,
+			Key:        toString(row["key"]),
+			Labels:     toStrings(row["labels"]),
+			Properties: properties,
+		}); err != nil {
+			return err
+		}
+	}
+	manifest.Nodes = len(nodes)
+
+	relationships, err := s.graph.ExecuteQuery(ctx, `
+		MATCH (a)-[r]->(b)
+		WITH a, r, b, coalesce(r.updated_at, r.created_at) AS changed
+		WHERE $since IS NULL OR changed IS NULL OR datetime(toString(changed)) >= datetime($since)
+		RETURN coalesce(r.id, elementId(r)) AS key, type(r) AS type,
+			   coalesce(a.id, elementId(a)) AS source, coalesce(b.id, elementId(b)) AS target,
+			   properties(r) AS properties
+	`, map[string]interface{}{"since": since})
+	if err != nil {
+		return fmt.Errorf("failed to export relationships: %w", err)
+	}
+	for _, row := range relationships {
+		properties, err := EncodeProperties(toMap(row["properties"]))
+		if err != nil {
+			return fmt.Errorf("failed to export relationship %s: %w", toString(row["key"]), err)
+		}
+		if err := writer.Write(&Record{
+			Kind:       RecordRelationship,
+			Key:        toString(row["key"]),
+			Type:       toString(row["type"]),
+			Source:     toString(row["source"]),
+			Target:     toString(row["target"]),
+			Properties: properties,
+		}); err != nil {
+			return err
+		}
+	}
+	manifest.Relationships = len(relationships)
+
+	if manifest.Kind == KindFull {
+		manifest.LiveNodes = manifest.Nodes
+		manifest.LiveRelationships = manifest.Relationships
+		return nil
+	}
+
+	if manifest.LiveNodes, err = s.exportKeys(ctx, writer, RecordNodeKey,
+		`MATCH (n) RETURN coalesce(n.id, elementId(n)) AS key`); err != nil {
+		return fmt.Errorf("failed to export node keys: %w", err)
+	}
+	if manifest.LiveRelationships, err = s.exportKeys(ctx, writer, RecordRelationshipKey,
+		`MATCH ()-[r]->() RETURN coalesce(r.id, elementId(r)) AS key`); err != nil {
+		return fmt.Errorf("failed to export relationship keys: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) exportKeys(ctx context.Context, writer *dataWriter, kind, query string) (int, error) {
+	rows, err := s.graph.ExecuteQuery(ctx, query, nil)
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		if err := writer.Write(&Record{Kind: kind, Key: toString(row["key"])}); err != nil {
+			return 0, err
+		}
+	}
+	return len(rows), nil
+}
+
+// ListSnapshots returns the completed snapshots, oldest first
+func (s *Service) ListSnapshots(ctx context.Context) ([]*Manifest, error) {
+	keys, err := s.store.List(ctx, s.key("manifests")+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make([]*Manifest, 0, len(keys))
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		manifest, err := s.readManifest(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].TakenAt.Before(manifests[j].TakenAt)
+	})
+	return manifests, nil
+}
+
+// GetSnapshot returns a snapshot's manifest
+func (s *Service) GetSnapshot(ctx context.Context, id string) (*Manifest, error) {
+	manifest, err := s.readManifest(ctx, s.manifestKey(id))
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, id)
+	}
+	return manifest, err
+}
+
+func (s *Service) readManifest(ctx context.Context, key string) (*Manifest, error) {
+	body, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: manifest %s: %v", ErrCorruptSnapshot, key, err)
+	}
+	return &manifest, nil
+}
+
+// VerifyResult reports whether a snapshot and the chain it depends on are intact
+type VerifyResult struct {
+	SnapshotID string    `json:"snapshot_id"`
+	Valid      bool      `json:"valid"`
+	Chain      []string  `json:"chain"`
+	Problems   []string  `json:"problems,omitempty"`
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+// Verify re-reads a snapshot and every snapshot it builds on, checking their
+// size, checksum and record counts against their manifests
+func (s *Service) Verify(ctx context.Context, id string) (*VerifyResult, error) {
+	target, err := s.GetSnapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VerifyResult{SnapshotID: id}
+	chain, err := s.chain(ctx, target)
+	if err != nil {
+		result.Problems = append(result.Problems, err.Error())
+	}
+	for _, manifest := range chain {
+		result.Chain = append(result.Chain, manifest.ID)
+		result.Problems = append(result.Problems, s.verifyData(ctx, manifest, nil)...)
+	}
+
+	result.Valid = len(result.Problems) == 0
+	result.VerifiedAt = s.now().UTC()
+	return result, nil
+}
+
+// verifyData reads a snapshot's data, passing every record to apply if it is
+// not nil, and checks it against the manifest
+func (s *Service) verifyData(ctx context.Context, manifest *Manifest, apply func(*Record)) []string {
+	body, err := s.store.Get(ctx, manifest.DataKey)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", manifest.ID, err)}
+	}
+	defer body.Close()
+
+	counts := make(map[string]int)
+	size, checksum, err := ReadData(body, func(record *Record) error {
+		counts[record.Kind]++
+		if apply != nil {
+			apply(record)
+		}
+		return nil
+	})
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", manifest.ID, err)}
+	}
+
+	var problems []string
+	if size != manifest.Size {
+		problems = append(problems, fmt.Sprintf("%s: size is %d bytes, manifest records %d", manifest.ID, size, manifest.Size))
+	}
+	if checksum != manifest.SHA256 {
+		problems = append(problems, fmt.Sprintf("%s: checksum mismatch", manifest.ID))
+	}
+	if counts[RecordNode] != manifest.Nodes || counts[RecordRelationship] != manifest.Relationships {
+		problems = append(problems, fmt.Sprintf("%s: holds %d nodes and %d relationships, manifest records %d and %d",
+			manifest.ID, counts[RecordNode], counts[RecordRelationship], manifest.Nodes, manifest.Relationships))
+	}
+	if manifest.Kind == KindIncremental &&
+		(counts[RecordNodeKey] != manifest.LiveNodes || counts[RecordRelationshipKey] != manifest.LiveRelationships) {
+		problems = append(problems, fmt.Sprintf("%s: live key counts do not match manifest", manifest.ID))
+	}
+	return problems
+}
+
+// RestorePlan is the chain of snapshots that rebuilds the graph as of a
+// point in time, oldest first
+type RestorePlan struct {
+	PointInTime time.Time   `json:"point_in_time"`
+	Target      *Manifest   `json:"target"`
+	Chain       []*Manifest `json:"chain"`
+}
+
+// PlanRestore selects the latest snapshot taken at or before at
+func (s *Service) PlanRestore(ctx context.Context, at time.Time) (*RestorePlan, error) {
+	manifests, err := s.ListSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *Manifest
+	for _, manifest := range manifests {
+		if !manifest.TakenAt.After(at) {
+			target = manifest
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoSnapshot, at.Format(time.RFC3339))
+	}
+
+	return s.PlanSnapshot(ctx, target.ID, at)
+}
+
+// PlanSnapshot returns the chain that restores a given snapshot
+func (s *Service) PlanSnapshot(ctx context.Context, id string, at time.Time) (*RestorePlan, error) {
+	target, err := s.GetSnapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := s.chain(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if at.IsZero() {
+		at = target.TakenAt
+	}
+	return &RestorePlan{PointInTime: at, Target: target, Chain: chain}, nil
+}
+
+// chain walks parent links back to the full snapshot the target builds on
+func (s *Service) chain(ctx context.Context, target *Manifest) ([]*Manifest, error) {
+	chain := []*Manifest{target}
+	current := target
+	for current.Kind == KindIncremental {
+		if current.ParentID == "" {
+			return chain, fmt.Errorf("%w: %s has no parent", ErrBrokenChain, current.ID)
+		}
+		parent, err := s.GetSnapshot(ctx, current.ParentID)
+		if err != nil {
+			return chain, fmt.Errorf("%w: parent %s of %s: %v", ErrBrokenChain, current.ParentID, current.ID, err)
+		}
+		if !parent.TakenAt.Before(current.TakenAt) {
+			return chain, fmt.Errorf("%w: %s does not precede %s", ErrBrokenChain, parent.ID, current.ID)
+		}
+		chain = append([]*Manifest{parent}, chain...)
+		current = parent
+	}
+	if current.Kind != KindFull {
+		return chain, fmt.Errorf("%w: %s has unknown kind %q", ErrBrokenChain, current.ID, current.Kind)
+	}
+	return chain, nil
+}
+
+// Graph is the graph as rebuilt from a snapshot chain, keyed by record key
+type Graph struct {
+	Nodes         map[string]*Record
+	Relationships map[string]*Record
+}
+
+// Materialize replays a restore plan into memory. Every snapshot is checked
+// against its manifest as it is read, and a mismatch fails the whole replay.
+func (s *Service) Materialize(ctx context.Context, plan *RestorePlan) (*Graph, error) {
+	graph := &Graph{
+		Nodes:         make(map[string]*Record),
+		Relationships: make(map[string]*Record),
+	}
+
+	for _, manifest := range plan.Chain {
+		liveNodes := make(map[string]bool)
+		liveRelationships := make(map[string]bool)
+		problems := s.verifyData(ctx, manifest, func(record *Record) {
+			switch record.Kind {
+			case RecordNode:
+				graph.Nodes[record.Key] = record
+			case RecordRelationship:
+				graph.Relationships[record.Key] = record
+			case RecordNodeKey:
+				liveNodes[record.Key] = true
+			case RecordRelationshipKey:
+				liveRelationships[record.Key] = true
+			}
+		})
+		if len(problems) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrCorruptSnapshot, strings.Join(problems, "; "))
+		}
+
+		if manifest.Kind == KindIncremental {
+			ApplyDeletions(graph, liveNodes, liveRelationships)
+		}
+	}
+
+	return graph, nil
+}
+
+// ApplyDeletions drops the nodes and relationships missing from the live key
+// sets of an incremental snapshot, along with relationships left without an
+// endpoint
+func ApplyDeletions(graph *Graph, liveNodes, liveRelationships map[string]bool) {
+	for key := range graph.Nodes {
+		if !liveNodes[key] {
+			delete(graph.Nodes, key)
+		}
+	}
+	for key, rel := range graph.Relationships {
+		if !liveRelationships[key] || graph.Nodes[rel.Source] == nil || graph.Nodes[rel.Target] == nil {
+			delete(graph.Relationships, key)
+		}
+	}
+}
+
+// latest returns the most recent snapshot
+func latest(manifests []*Manifest) *Manifest {
+	if len(manifests) == 0 {
+		return nil
+	}
+	return manifests[len(manifests)-1]
+}
+
+// chainLength counts the snapshots from the manifest back to its base
+func chainLength(manifests []*Manifest, manifest *Manifest) int {
+	length := 0
+	for _, m := range manifests {
+		if m.BaseID == manifest.BaseID && !m.TakenAt.After(manifest.TakenAt) {
+			length++
+		}
+	}
+	return length
+}
+
+func (s *Service) key(parts ...string) string {
+	return path.Join(append([]string{s.cfg.Prefix}, parts...)...)
+}
+
+func (s *Service) manifestKey(id string) string {
+	return s.key("manifests", id+".json")
+}
+
+func (s *Service) dataKey(id string) string {
+	return s.key("snapshots", id+".jsonl.gz")
+}
+
+func toString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func toStrings(value interface{}) []string {
+	items, _ := value.([]interface{})
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		values = append(values, toString(item))
+	}
+	return values
+}
+
+func toMap(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
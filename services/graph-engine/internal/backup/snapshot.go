@@ -0,0 +1,357 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+)
+
+// Snapshot kinds. A full snapshot holds the whole graph; an incremental one
+// holds what changed since its parent and the keys of everything still live.
+const (
+	KindFull        = "full"
+	KindIncremental = "incremental"
+)
+
+// Record kinds in snapshot data
+const (
+	RecordNode            = "node"
+	RecordRelationship    = "relationship"
+	RecordNodeKey         = "node_key"
+	RecordRelationshipKey = "relationship_key"
+)
+
+// FormatVersion is the snapshot data format written by this package
+const FormatVersion = 1
+
+// ErrCorruptSnapshot is returned when snapshot data cannot be decoded
+var ErrCorruptSnapshot = errors.New("corrupt snapshot")
+
+// Manifest describes a stored snapshot. It is written after the snapshot
+// data, so a snapshot without a manifest was never completed.
+type Manifest struct {
+	ID                string     `json:"id"`
+	Kind              string     `json:"kind"`
+	ParentID          string     `json:"parent_id,omitempty"`
+	BaseID            string     `json:"base_id"`
+	Since             *time.Time `json:"since,omitempty"`
+	TakenAt           time.Time  `json:"taken_at"`
+	CompletedAt       time.Time  `json:"completed_at"`
+	Nodes             int        `json:"nodes"`
+	Relationships     int        `json:"relationships"`
+	LiveNodes         int        `json:"live_nodes"`
+	LiveRelationships int        `json:"live_relationships"`
+	DataKey           string     `json:"data_key"`
+	Size              int64      `json:"size"`
+	SHA256            string     `json:"sha256"`
+	FormatVersion     int        `json:"format_version"`
+}
+
+// Record is one line of snapshot data. Nodes and relationships are
+// identified by their id property, or their element ID when they have none.
+// Property values are stored in the typed encoding of EncodeValue.
+type Record struct {
+	Kind       string                 `json:"kind"`
+	Key        string                 `json:"key"`
+	Labels     []string               `json:"labels,omitempty"`
+	Type       string                 `json:"type,omitempty"`
+	Source     string                 `json:"source,omitempty"`
+	Target     string                 `json:"target,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// dataWriter writes gzipped JSON lines and tracks the checksum and size of
+// the compressed output
+type dataWriter struct {
+	hasher hash.Hash
+	count  *countingWriter
+	gzip   *gzip.Writer
+	enc    *json.Encoder
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func newDataWriter(w io.Writer) *dataWriter {
+	hasher := sha256.New()
+	count := &countingWriter{w: io.MultiWriter(w, hasher)}
+	gz := gzip.NewWriter(count)
+	return &dataWriter{
+		hasher: hasher,
+		count:  count,
+		gzip:   gz,
+		enc:    json.NewEncoder(gz),
+	}
+}
+
+func (d *dataWriter) Write(record *Record) error {
+	return d.enc.Encode(record)
+}
+
+// Close flushes the data and returns its size and SHA-256 checksum
+func (d *dataWriter) Close() (int64, string, error) {
+	if err := d.gzip.Close(); err != nil {
+		return 0, "", err
+	}
+	return d.count.n, hex.EncodeToString(d.hasher.Sum(nil)), nil
+}
+
+// WriteData encodes records in the snapshot data format and returns the
+// size and SHA-256 checksum of the written bytes
+func WriteData(w io.Writer, records []*Record) (int64, string, error) {
+	writer := newDataWriter(w)
+	for _, record := range records {
+		if err := writer.Write(record); err != nil {
+			return 0, "", err
+		}
+	}
+	return writer.Close()
+}
+
+// ReadData decodes snapshot data, calling fn for every record, and returns
+// the size and SHA-256 checksum of the stored bytes
+func ReadData(r io.Reader, fn func(*Record) error) (int64, string, error) {
+	hasher := sha256.New()
+	count := &countingWriter{w: hasher}
+	gz, err := gzip.NewReader(io.TeeReader(r, count))
+	if err != nil {
+		return 0, "", fmt.Errorf("%w: %v", ErrCorruptSnapshot, err)
+	}
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		decoder.UseNumber()
+
+		var record Record
+		if err := decoder.Decode(&record); err != nil {
+			return 0, "", fmt.Errorf("%w: line %d: %v", ErrCorruptSnapshot, line, err)
+		}
+		if err := fn(&record); err != nil {
+			return 0, "", err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, "", fmt.Errorf("%w: %v", ErrCorruptSnapshot, err)
+	}
+	if err := gz.Close(); err != nil {
+		return 0, "", fmt.Errorf("%w: %v", ErrCorruptSnapshot, err)
+	}
+
+	// Drain anything after the gzip stream so it counts towards the checksum
+	if _, err := io.Copy(io.Discard, io.TeeReader(r, count)); err != nil {
+		return 0, "", err
+	}
+	return count.n, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// EncodeValue converts a property value returned by the driver into a form
+// that survives a JSON round trip. Strings, booleans and integers are kept
+// as they are; floats, temporal, spatial and byte values are tagged with
+// their type so DecodeValue can restore them exactly.
+func EncodeValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil, string, bool, int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case float64:
+		return typed("float", strconv.FormatFloat(v, 'g', -1, 64)), nil
+	case []byte:
+		return typed("bytes", base64.StdEncoding.EncodeToString(v)), nil
+	case time.Time:
+		return typed("datetime", v.Format(time.RFC3339Nano)), nil
+	case dbtype.Date:
+		return typed("date", time.Time(v).Format("2006-01-02")), nil
+	case dbtype.LocalDateTime:
+		return typed("local_datetime", time.Time(v).Format("2006-01-02T15:04:05.999999999")), nil
+	case dbtype.LocalTime:
+		return typed("local_time", time.Time(v).Format("15:04:05.999999999")), nil
+	case dbtype.Time:
+		return typed("time", time.Time(v).Format("15:04:05.999999999Z07:00")), nil
+	case dbtype.Duration:
+		return typed("duration", []interface{}{v.Months, v.Days, v.Seconds, int64(v.Nanos)}), nil
+	case dbtype.Point2D:
+		return typed("point2d", []interface{}{int64(v.SpatialRefId), strconv.FormatFloat(v.X, 'g', -1, 64), strconv.FormatFloat(v.Y, 'g', -1, 64)}), nil
+	case dbtype.Point3D:
+		return typed("point3d", []interface{}{int64(v.SpatialRefId), strconv.FormatFloat(v.X, 'g', -1, 64), strconv.FormatFloat(v.Y, 'g', -1, 64), strconv.FormatFloat(v.Z, 'g', -1, 64)}), nil
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			encoded, err := EncodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = encoded
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unsupported property type %T", value)
+	}
+}
+
+// DecodeValue reverses EncodeValue for a value read with UseNumber
+func DecodeValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil, string, bool, int64:
+		return v, nil
+	case json.Number:
+		return v.Int64()
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			decoded, err := DecodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = decoded
+		}
+		return items, nil
+	case map[string]interface{}:
+		return decodeTyped(v)
+	default:
+		return nil, fmt.Errorf("%w: unexpected value %T", ErrCorruptSnapshot, value)
+	}
+}
+
+// EncodeProperties encodes every value of a property map
+func EncodeProperties(properties map[string]interface{}) (map[string]interface{}, error) {
+	encoded := make(map[string]interface{}, len(properties))
+	for key, value := range properties {
+		v, err := EncodeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", key, err)
+		}
+		encoded[key] = v
+	}
+	return encoded, nil
+}
+
+// DecodeProperties decodes every value of a property map
+func DecodeProperties(properties map[string]interface{}) (map[string]interface{}, error) {
+	decoded := make(map[string]interface{}, len(properties))
+	for key, value := range properties {
+		v, err := DecodeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", key, err)
+		}
+		decoded[key] = v
+	}
+	return decoded, nil
+}
+
+func typed(kind string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{"$type": kind, "value": value}
+}
+
+func decodeTyped(v map[string]interface{}) (interface{}, error) {
+	kind, _ := v["$type"].(string)
+	switch kind {
+	case "float":
+		return strconv.ParseFloat(asString(v["value"]), 64)
+	case "bytes":
+		return base64.StdEncoding.DecodeString(asString(v["value"]))
+	case "datetime":
+		return time.Parse(time.RFC3339Nano, asString(v["value"]))
+	case "date":
+		t, err := time.Parse("2006-01-02", asString(v["value"]))
+		return dbtype.Date(t), err
+	case "local_datetime":
+		t, err := time.Parse("2006-01-02T15:04:05.999999999", asString(v["value"]))
+		return dbtype.LocalDateTime(t), err
+	case "local_time":
+		t, err := time.Parse("15:04:05.999999999", asString(v["value"]))
+		return dbtype.LocalTime(t), err
+	case "time":
+		t, err := time.Parse("15:04:05.999999999Z07:00", asString(v["value"]))
+		return dbtype.Time(t), err
+	}
+
+	parts, _ := v["value"].([]interface{})
+	switch {
+	case kind == "duration" && len(parts) == 4:
+		numbers, err := int64s(parts)
+		if err != nil {
+			return nil, err
+		}
+		return dbtype.Duration{Months: numbers[0], Days: numbers[1], Seconds: numbers[2], Nanos: int(numbers[3])}, nil
+	case kind == "point2d" && len(parts) == 3:
+		srid, err := int64s(parts[:1])
+		if err != nil {
+			return nil, err
+		}
+		coords, err := float64s(parts[1:])
+		if err != nil {
+			return nil, err
+		}
+		return dbtype.Point2D{SpatialRefId: uint32(srid[0]), X: coords[0], Y: coords[1]}, nil
+	case kind == "point3d" && len(parts) == 4:
+		srid, err := int64s(parts[:1])
+		if err != nil {
+			return nil, err
+		}
+		coords, err := float64s(parts[1:])
+		if err != nil {
+			return nil, err
+		}
+		return dbtype.Point3D{SpatialRefId: uint32(srid[0]), X: coords[0], Y: coords[1], Z: coords[2]}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown value type %q", ErrCorruptSnapshot, kind)
+}
+
+func asString(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
+
+func int64s(values []interface{}) ([]int64, error) {
+	numbers := make([]int64, len(values))
+	for i, value := range values {
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("%w: expected integer, got %T", ErrCorruptSnapshot, value)
+		}
+		n, err := number.Int64()
+		if err != nil {
+			return nil, err
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+func float64s(values []interface{}) ([]float64, error) {
+	numbers := make([]float64, len(values))
+	for i, value := range values {
+		n, err := strconv.ParseFloat(asString(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorruptSnapshot, err)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
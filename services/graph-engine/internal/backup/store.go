@@ -0,0 +1,118 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+)
+
+// ErrObjectNotFound is returned when a stored object does not exist
+var ErrObjectNotFound = errors.New("backup object not found")
+
+// Store is the object storage snapshots are exported to
+type Store interface {
+	// Put writes an object, replacing any existing one
+	Put(ctx context.Context, key string, body io.Reader) error
+	// Get opens an object for reading
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// List returns the keys under a prefix, sorted
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// NewStore creates the store selected by configuration
+func NewStore(cfg config.BackupConfig) (Store, error) {
+	switch cfg.Storage {
+	case "filesystem":
+		return NewFileStore(cfg.Path), nil
+	case "s3":
+		return NewS3Store(cfg.S3), nil
+	default:
+		return nil, fmt.Errorf("unsupported backup storage: %s", cfg.Storage)
+	}
+}
+
+// FileStore keeps objects as files under a root directory, for local
+// development and object storage mounted into the container
+type FileStore struct {
+	root string
+}
+
+// NewFileStore creates a store rooted at dir
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{root: dir}
+}
+
+// Put writes the object to a temporary file and renames it into place so
+// readers never see a partial object
+func (s *FileStore) Put(ctx context.Context, key string, body io.Reader) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create backup object: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write backup object %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write backup object %s: %w", key, err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get opens the object's file
+func (s *FileStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	return file, err
+}
+
+// List walks the directory under prefix
+func (s *FileStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(s.root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup objects: %w", err)
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *FileStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(filepath.Clean("/"+key)))
+}
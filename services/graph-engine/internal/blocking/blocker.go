@@ -0,0 +1,291 @@
+package blocking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/tenancy"
+)
+
+// ErrNotBlocked is returned for entities blocking cannot narrow: types that
+// are not blocked and entities with no blocking field values. Callers fall
+// back to scanning the type.
+var ErrNotBlocked = errors.New("entity is not blocked")
+
+// keyVersion identifies how keys are built; entities indexed by another
+// version are re-indexed
+const keyVersion = "1"
+
+var (
+	blockSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "graph_engine_blocking_block_size",
+		Help:    "Entities in each block looked up for a candidate",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+	}, []string{"entity_type"})
+	candidateCount = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "graph_engine_blocking_candidates",
+		Help:    "Candidates retrieved through blocking for one entity",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+	}, []string{"entity_type"})
+	oversizedBlocks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "graph_engine_blocking_oversized_blocks_total",
+		Help: "Blocks skipped for holding more than the maximum block size",
+	}, []string{"entity_type"})
+	indexedEntities = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "graph_engine_blocking_indexed_entities_total",
+		Help: "Entities whose blocking keys were (re)built",
+	}, []string{"entity_type"})
+	recallSamples = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "graph_engine_blocking_recall_samples_total",
+		Help: "Lookups also matched exhaustively to measure blocking recall",
+	}, []string{"entity_type"})
+	recall = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "graph_engine_blocking_recall",
+		Help:    "Share of exhaustive matches that blocking also retrieved, per sampled lookup",
+		Buckets: []float64{0.5, 0.7, 0.8, 0.9, 0.95, 0.99, 1},
+	}, []string{"entity_type"})
+)
+
+// Blocker indexes entities under blocking keys and retrieves the entities
+// sharing a block with a candidate, so matching compares a candidate with
+// its blocks rather than every entity of its type
+type Blocker struct {
+	store   *Store
+	cfg     config.BlockingConfig
+	types   map[string]bool
+	version string
+	tenants tenancy.Inspector
+	logger  *slog.Logger
+}
+
+// NewBlocker creates a blocker over the graph
+func NewBlocker(graph QueryRunner, cfg config.BlockingConfig, logger *slog.Logger) *Blocker {
+	types := make(map[string]bool, len(cfg.EntityTypes))
+	for _, entityType := range cfg.EntityTypes {
+		types[entityType] = true
+	}
+
+	return &Blocker{
+		store:   NewStore(graph),
+		cfg:     cfg,
+		types:   types,
+		version: fmt.Sprintf("%s:%d:%s", keyVersion, cfg.QGramSize, strings.Join(cfg.Fields, ",")),
+		logger:  logger,
+	}
+}
+
+// SetTenants makes the indexer also index every online tenant database
+func (b *Blocker) SetTenants(inspector tenancy.Inspector) {
+	b.tenants = inspector
+}
+
+// Blocked reports whether entities of a type are blocked
+func (b *Blocker) Blocked(entityType string) bool {
+	return b.types[entityType]
+}
+
+// Candidates returns the entities of a type sharing a block with the given
+// attributes, at most the configured maximum, those sharing most blocks
+// first. q-gram keys are looked up directly; sorted-neighborhood keys also
+// pull in the keys within the window on either side. Blocks larger than the
+// maximum block size are skipped.
+func (b *Blocker) Candidates(ctx context.Context, entityType string, attributes map[string]interface{}) ([]*Record, error) {
+	if !b.Blocked(entityType) {
+		return nil, fmt.Errorf("%w: type %s", ErrNotBlocked, entityType)
+	}
+
+	keys := KeysFor(entityType, attributes, b.cfg)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no blocking field values", ErrNotBlocked)
+	}
+
+	seen := make(map[string]bool)
+	var lookup []string
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			lookup = append(lookup, key)
+		}
+	}
+
+	for _, key := range keys {
+		add(key.Value)
+		if key.Kind != KindSorted || b.cfg.SortedWindow == 0 {
+			continue
+		}
+		neighbors, err := b.store.Neighbors(ctx, SortedPrefix(entityType, key.Field), key.Value, b.cfg.SortedWindow)
+		if err != nil {
+			return nil, err
+		}
+		for _, neighbor := range neighbors {
+			add(neighbor)
+		}
+	}
+
+	sizes, err := b.store.BlockSizes(ctx, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	usable := make([]string, 0, len(sizes))
+	for _, key := range lookup {
+		size, ok := sizes[key]
+		if !ok {
+			continue
+		}
+		blockSize.WithLabelValues(entityType).Observe(float64(size))
+		if size > b.cfg.MaxBlockSize {
+			oversizedBlocks.WithLabelValues(entityType).Inc()
+			continue
+		}
+		usable = append(usable, key)
+	}
+
+	if len(usable) == 0 {
+		candidateCount.WithLabelValues(entityType).Observe(0)
+		return nil, nil
+	}
+
+	records, err := b.store.Members(ctx, entityType, usable, b.cfg.MaxCandidates)
+	if err != nil {
+		return nil, err
+	}
+	candidateCount.WithLabelValues(entityType).Observe(float64(len(records)))
+	return records, nil
+}
+
+// Scan returns entities of a type without blocking, up to the recall sample
+// limit
+func (b *Blocker) Scan(ctx context.Context, entityType string) ([]*Record, error) {
+	return b.store.Scan(ctx, entityType, b.cfg.RecallSampleLimit)
+}
+
+// SampleRecall reports whether a lookup should also be matched exhaustively
+// to measure recall
+func (b *Blocker) SampleRecall() bool {
+	return b.cfg.RecallSampleRate > 0 && rand.Float64() < b.cfg.RecallSampleRate
+}
+
+// RecordRecall records a sampled lookup in which exhaustive matching found
+// relevant matches, of which blocking retrieved found
+func (b *Blocker) RecordRecall(entityType string, relevant, found int) {
+	recallSamples.WithLabelValues(entityType).Inc()
+	if relevant == 0 {
+		return
+	}
+	recall.WithLabelValues(entityType).Observe(float64(found) / float64(relevant))
+}
+
+// Start indexes pending entities every index interval until the context ends
+func (b *Blocker) Start(ctx context.Context) {
+	ticker := time.NewTicker(b.cfg.IndexInterval)
+	defer ticker.Stop()
+
+	for {
+		if indexed, err := b.IndexPending(ctx); err != nil {
+			b.logger.Error("Failed to index blocking keys", "error", err)
+		} else if indexed > 0 {
+			b.logger.Info("Indexed blocking keys", "entities", indexed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// IndexPending builds the keys of every entity that has none or whose keys
+// are stale, in the shared database and every online tenant database, and
+// prunes keys left without entities. It returns how many entities it indexed.
+func (b *Blocker) IndexPending(ctx context.Context) (int, error) {
+	total, err := b.indexDatabase(ctx)
+	if err != nil && !errors.Is(err, tenancy.ErrTenantRequired) {
+		return total, err
+	}
+
+	if b.tenants == nil {
+		return total, nil
+	}
+
+	databases, err := b.tenants.TenantDatabases(ctx)
+	if err != nil {
+		return total, err
+	}
+	for _, db := range databases {
+		if !db.Online {
+			continue
+		}
+		indexed, err := b.indexDatabase(tenancy.WithTenant(ctx, db.TenantID))
+		total += indexed
+		if err != nil {
+			return total, fmt.Errorf("tenant %s: %w", db.TenantID, err)
+		}
+	}
+	return total, nil
+}
+
+// indexDatabase indexes the pending entities of the context's database
+func (b *Blocker) indexDatabase(ctx context.Context) (int, error) {
+	if err := b.store.EnsureSchema(ctx); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, entityType := range b.cfg.EntityTypes {
+		for {
+			if err := ctx.Err(); err != nil {
+				return total, err
+			}
+
+			pending, err := b.store.Pending(ctx, entityType, b.cfg.Fields, b.version, b.cfg.IndexBatchSize)
+			if err != nil {
+				return total, err
+			}
+			if len(pending) == 0 {
+				break
+			}
+
+			entities := make([]IndexedEntity, len(pending))
+			for i, record := range pending {
+				keys := KeysFor(entityType, record.Properties, b.cfg)
+				values := make([]string, len(keys))
+				for j, key := range keys {
+					values[j] = key.Value
+				}
+				entities[i] = IndexedEntity{EntityID: record.EntityID, Fields: record.Fields, Keys: values}
+			}
+
+			if err := b.store.Index(ctx, entityType, b.cfg.Fields, b.version, entities); err != nil {
+				return total, err
+			}
+			indexedEntities.WithLabelValues(entityType).Add(float64(len(entities)))
+			total += len(entities)
+
+			if len(pending) < b.cfg.IndexBatchSize {
+				break
+			}
+		}
+	}
+
+	for {
+		pruned, err := b.store.Prune(ctx, b.cfg.IndexBatchSize)
+		if err != nil {
+			return total, err
+		}
+		if pruned < b.cfg.IndexBatchSize {
+			break
+		}
+	}
+	return total, nil
+}
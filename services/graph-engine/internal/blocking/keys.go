@@ -0,0 +1,130 @@
+package blocking
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+)
+
+// Key kinds: q-gram keys put entities sharing a q-gram of a blocking field
+// in the same block; sorted-neighborhood keys order entities by their sorted
+// field tokens so that near neighbours in that order are compared
+const (
+	KindQGram  = "qg"
+	KindSorted = "sn"
+)
+
+// Key is one blocking key of an entity. Value is the key as stored: entity
+// type, kind, field and key joined so that blocks never mix types and each
+// type and field's sorted-neighborhood keys fall in one contiguous range.
+type Key struct {
+	Kind  string `json:"kind"`
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+// Normalize lower-cases a value and reduces it to letters and digits
+// separated by single spaces
+func Normalize(value string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(value) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// QGrams returns the distinct q-grams of each token of a normalized value,
+// in order of first appearance. Tokens shorter than q are their own q-gram.
+func QGrams(value string, q int) []string {
+	seen := make(map[string]bool)
+	var grams []string
+	add := func(gram string) {
+		if !seen[gram] {
+			seen[gram] = true
+			grams = append(grams, gram)
+		}
+	}
+
+	for _, token := range strings.Fields(value) {
+		runes := []rune(token)
+		if len(runes) <= q {
+			add(token)
+			continue
+		}
+		for i := 0; i+q <= len(runes); i++ {
+			add(string(runes[i : i+q]))
+		}
+	}
+	return grams
+}
+
+// SortedKey returns a normalized value's tokens sorted and concatenated, so
+// "Smith, John" and "John Smith" share a sorted-neighborhood key
+func SortedKey(value string) string {
+	tokens := strings.Fields(value)
+	sort.Strings(tokens)
+	return strings.Join(tokens, "")
+}
+
+// KeysFor returns the blocking keys of an entity of the given type from its
+// blocking fields. Entities with no blocking field values have no keys.
+func KeysFor(entityType string, attributes map[string]interface{}, cfg config.BlockingConfig) []Key {
+	var keys []Key
+	seen := make(map[string]bool)
+	add := func(kind, field, value string) {
+		key := Key{Kind: kind, Field: field, Value: scoped(entityType, kind, field+":"+value)}
+		if !seen[key.Value] {
+			seen[key.Value] = true
+			keys = append(keys, key)
+		}
+	}
+
+	for _, field := range cfg.Fields {
+		raw := attributeValue(attributes, field)
+		if raw == nil {
+			continue
+		}
+		value := Normalize(fmt.Sprint(raw))
+		if value == "" {
+			continue
+		}
+		for _, gram := range QGrams(value, cfg.QGramSize) {
+			add(KindQGram, field, gram)
+		}
+		add(KindSorted, field, SortedKey(value))
+	}
+	return keys
+}
+
+// SortedPrefix is the range every sorted-neighborhood key of a type and
+// field falls in
+func SortedPrefix(entityType, field string) string {
+	return scoped(entityType, KindSorted, field+":")
+}
+
+func scoped(entityType, kind, value string) string {
+	return entityType + "|" + kind + "|" + value
+}
+
+func attributeValue(attributes map[string]interface{}, field string) interface{} {
+	value, ok := attributes[field]
+	if !ok || value == nil {
+		return nil
+	}
+	if s, ok := value.(string); ok && strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return value
+}
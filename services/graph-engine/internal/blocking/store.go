@@ -0,0 +1,234 @@
+package blocking
+
+import (
+	"context"
+	"fmt"
+)
+
+// QueryRunner runs Cypher against the graph
+type QueryRunner interface {
+	ExecuteQuery(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error)
+}
+
+// Record is an entity read for indexing or matching
+type Record struct {
+	EntityID   string
+	Properties map[string]interface{}
+	Fields     []string // blocking field values as last read, for indexing
+	Shared     int      // blocks shared with the entity being matched
+}
+
+// Store keeps blocking keys in the graph: each key is a BlockKey node, and
+// entities link to the keys they carry, so a block is a key's neighbours
+type Store struct {
+	graph QueryRunner
+}
+
+// NewStore creates a blocking key store
+func NewStore(graph QueryRunner) *Store {
+	return &Store{graph: graph}
+}
+
+// EnsureSchema creates the unique BlockKey constraint, whose index serves
+// both key lookups and sorted-neighborhood range scans
+func (s *Store) EnsureSchema(ctx context.Context) error {
+	if _, err := s.graph.ExecuteQuery(ctx,
+		"CREATE CONSTRAINT block_key_value IF NOT EXISTS FOR (b:BlockKey) REQUIRE b.value IS UNIQUE", nil); err != nil {
+		return fmt.Errorf("failed to create block key constraint: %w", err)
+	}
+	return nil
+}
+
+// Pending returns up to limit entities of a type whose keys are missing,
+// were built by another key version, or predate a change to their fields
+func (s *Store) Pending(ctx context.Context, entityType string, fields []string, version string, limit int) ([]*Record, error) {
+	rows, err := s.graph.ExecuteQuery(ctx, `
+		MATCH (e:`+entityType+`)
+		WHERE e.id IS NOT NULL
+		WITH e, [f IN $fields | coalesce(toString(e[f]), '')] AS fields
+		WHERE e._blocking_version IS NULL OR e._blocking_version <> $version OR e._blocking_fields <> fields
+		RETURN e.id AS entityId, properties(e) AS properties, fields
+		LIMIT $limit
+	`, map[string]interface{}{
+		"fields":  fields,
+		"version": version,
+		"limit":   limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read entities pending blocking: %w", err)
+	}
+	return toRecords(rows), nil
+}
+
+// IndexedEntity is an entity's new set of blocking keys
+type IndexedEntity struct {
+	EntityID string
+	Fields   []string
+	Keys     []string
+}
+
+// Index replaces the blocking keys of a batch of entities of one type. An
+// entity whose fields changed since they were read is left for the next pass.
+func (s *Store) Index(ctx context.Context, entityType string, fields []string, version string, entities []IndexedEntity) error {
+	rows := make([]map[string]interface{}, len(entities))
+	for i, entity := range entities {
+		rows[i] = map[string]interface{}{
+			"id":     entity.EntityID,
+			"fields": entity.Fields,
+			"keys":   entity.Keys,
+		}
+	}
+
+	_, err := s.graph.ExecuteQuery(ctx, `
+		UNWIND $rows AS row
+		MATCH (e:`+entityType+` {id: row.id})
+		WHERE [f IN $fields | coalesce(toString(e[f]), '')] = row.fields
+		OPTIONAL MATCH (e)-[old:IN_BLOCK]->(:BlockKey)
+		DELETE old
+		WITH DISTINCT e, row
+		FOREACH (key IN row.keys |
+			MERGE (b:BlockKey {value: key})
+			MERGE (e)-[:IN_BLOCK]->(b))
+		SET e._blocking_fields = row.fields, e._blocking_version = $version
+	`, map[string]interface{}{
+		"rows":    rows,
+		"fields":  fields,
+		"version": version,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index blocking keys: %w", err)
+	}
+	return nil
+}
+
+// Prune deletes up to limit keys no entity carries any longer, left behind
+// when entities change or keys are rebuilt
+func (s *Store) Prune(ctx context.Context, limit int) (int, error) {
+	rows, err := s.graph.ExecuteQuery(ctx, `
+		MATCH (b:BlockKey)
+		WHERE NOT (b)<-[:IN_BLOCK]-()
+		WITH b LIMIT $limit
+		DELETE b
+		RETURN count(*) AS pruned
+	`, map[string]interface{}{"limit": limit})
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune block keys: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	pruned, _ := rows[0]["pruned"].(int64)
+	return int(pruned), nil
+}
+
+// Neighbors returns up to window stored keys on each side of a
+// sorted-neighborhood key, within the keys sharing its prefix
+func (s *Store) Neighbors(ctx context.Context, prefix, key string, window int) ([]string, error) {
+	rows, err := s.graph.ExecuteQuery(ctx, `
+		CALL {
+			MATCH (b:BlockKey)
+			WHERE b.value STARTS WITH $prefix AND b.value < $key
+			RETURN b.value AS key ORDER BY b.value DESC LIMIT $window
+			UNION
+			MATCH (b:BlockKey)
+			WHERE b.value STARTS WITH $prefix AND b.value >= $key
+			RETURN b.value AS key ORDER BY b.value ASC LIMIT $window
+		}
+		RETURN key
+	`, map[string]interface{}{
+		"prefix": prefix,
+		"key":    key,
+		"window": window,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sorted neighborhood: %w", err)
+	}
+
+	keys := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if value, ok := row["key"].(string); ok {
+			keys = append(keys, value)
+		}
+	}
+	return keys, nil
+}
+
+// BlockSizes returns how many entities each of the given keys holds. Keys
+// no entity carries are absent.
+func (s *Store) BlockSizes(ctx context.Context, keys []string) (map[string]int, error) {
+	rows, err := s.graph.ExecuteQuery(ctx, `
+		MATCH (b:BlockKey)
+		WHERE b.value IN $keys
+		RETURN b.value AS key, COUNT { (b)<-[:IN_BLOCK]-() } AS size
+	`, map[string]interface{}{"keys": keys})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block sizes: %w", err)
+	}
+
+	sizes := make(map[string]int, len(rows))
+	for _, row := range rows {
+		key, _ := row["key"].(string)
+		size, _ := row["size"].(int64)
+		if key != "" && size > 0 {
+			sizes[key] = int(size)
+		}
+	}
+	return sizes, nil
+}
+
+// Members returns the entities of a type in any of the given blocks, those
+// sharing the most blocks first
+func (s *Store) Members(ctx context.Context, entityType string, keys []string, limit int) ([]*Record, error) {
+	rows, err := s.graph.ExecuteQuery(ctx, `
+		MATCH (b:BlockKey)<-[:IN_BLOCK]-(e:`+entityType+`)
+		WHERE b.value IN $keys
+		WITH e, count(b) AS shared
+		RETURN e.id AS entityId, properties(e) AS properties, shared
+		ORDER BY shared DESC, entityId
+		LIMIT $limit
+	`, map[string]interface{}{
+		"keys":  keys,
+		"limit": limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block members: %w", err)
+	}
+	return toRecords(rows), nil
+}
+
+// Scan returns up to limit entities of a type without blocking, for recall
+// sampling and types that are not blocked
+func (s *Store) Scan(ctx context.Context, entityType string, limit int) ([]*Record, error) {
+	rows, err := s.graph.ExecuteQuery(ctx, `
+		MATCH (e:`+entityType+`)
+		RETURN e.id AS entityId, properties(e) AS properties
+		LIMIT $limit
+	`, map[string]interface{}{"limit": limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan entities: %w", err)
+	}
+	return toRecords(rows), nil
+}
+
+func toRecords(rows []map[string]interface{}) []*Record {
+	records := make([]*Record, 0, len(rows))
+	for _, row := range rows {
+		entityID, ok := row["entityId"].(string)
+		if !ok {
+			continue
+		}
+		record := &Record{EntityID: entityID}
+		record.Properties, _ = row["properties"].(map[string]interface{})
+		if fields, ok := row["fields"].([]interface{}); ok {
+			for _, field := range fields {
+				value, _ := field.(string)
+				record.Fields = append(record.Fields, value)
+			}
+		}
+		if shared, ok := row["shared"].(int64); ok {
+			record.Shared = int(shared)
+		}
+		records = append(records, record)
+	}
+	return records
+}
@@ -2,11 +2,35 @@ package config
 
 import (
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+// tenantDatabasePrefix is what may precede a tenant ID in a database name:
+// Neo4j database names start with a letter and hold letters, digits, dots
+// and dashes
+var tenantDatabasePrefix = regexp.MustCompile(`^[a-z][a-z0-9.-]{0,22}$`)
+
+// blockingEntityType is what a blocked entity type may look like; types are
+// interpolated into Cypher as labels
+var blockingEntityType = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// cypherIdentifier is what exposure relationship types and properties may
+// look like; they are interpolated into Cypher
+var cypherIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// motifDetectors are the detectors scheduled motif detection may run
+var motifDetectors = map[string]bool{
+	"round_tripping": true,
+	"fan_in":         true,
+	"fan_out":        true,
+	"pass_through":   true,
+	"layering_chain": true,
+	"nested_shell":   true,
+}
+
 // Config holds the application configuration
 type Config struct {
 	Environment string        `mapstructure:"environment"`
@@ -15,7 +39,19 @@ type Config struct {
 	Neo4j       Neo4jConfig   `mapstructure:"neo4j"`
 	Kafka       KafkaConfig   `mapstructure:"kafka"`
 	GraphEngine GraphEngineConfig `mapstructure:"graph_engine"`
+	Geo         GeoConfig     `mapstructure:"geo"`
+	Thumbnail   ThumbnailConfig `mapstructure:"thumbnail"`
+	AccessAudit AccessAuditConfig `mapstructure:"access_audit"`
+	Backup      BackupConfig  `mapstructure:"backup"`
+	Maintenance MaintenanceConfig `mapstructure:"maintenance"`
+	Tenancy     TenancyConfig `mapstructure:"tenancy"`
+	Blocking    BlockingConfig `mapstructure:"blocking"`
+	Exposure    ExposureConfig `mapstructure:"exposure"`
+	Motifs      MotifConfig   `mapstructure:"motifs"`
+	QueryTemplates QueryTemplateConfig `mapstructure:"query_templates"`
+	LoadShedding LoadSheddingConfig `mapstructure:"load_shedding"`
 	Logging     LoggingConfig `mapstructure:"logging"`
+	Startup     StartupConfig `mapstructure:"startup"`
 }
 
 // ServerConfig holds server configuration
@@ -57,6 +93,7 @@ type KafkaConfig struct {
 	InvestigationTopic     string `mapstructure:"investigation_topic"`
 	PatternDetectionTopic  string `mapstructure:"pattern_detection_topic"`
 	EntityResolvedTopic    string `mapstructure:"entity_resolved_topic"`
+	ExposureTopic          string `mapstructure:"exposure_topic"`
 }
 
 // GraphEngineConfig holds graph engine specific configuration
@@ -72,12 +109,235 @@ type GraphEngineConfig struct {
 	AnomalyThreshold       float64 `mapstructure:"anomaly_threshold"`
 }
 
+// GeoConfig holds cross-border flow analysis configuration
+type GeoConfig struct {
+	HighRiskJurisdictions []string      `mapstructure:"high_risk_jurisdictions"`
+	HighRiskCorridors     []string      `mapstructure:"high_risk_corridors"`
+	JurisdictionFields    []string      `mapstructure:"jurisdiction_fields"`
+	DefaultNetworkDepth   int           `mapstructure:"default_network_depth"`
+	MaxNetworkDepth       int           `mapstructure:"max_network_depth"`
+	MaxRouteHops          int           `mapstructure:"max_route_hops"`
+	DefaultTimeWindow     time.Duration `mapstructure:"default_time_window"`
+	MaxRoutes             int           `mapstructure:"max_routes"`
+}
+
+// ThumbnailConfig holds network thumbnail rendering configuration
+type ThumbnailConfig struct {
+	MaxNodes         int           `mapstructure:"max_nodes"`
+	FetchMultiplier  int           `mapstructure:"fetch_multiplier"`
+	DefaultDepth     int           `mapstructure:"default_depth"`
+	MaxDepth         int           `mapstructure:"max_depth"`
+	DefaultWidth     int           `mapstructure:"default_width"`
+	DefaultHeight    int           `mapstructure:"default_height"`
+	MaxWidth         int           `mapstructure:"max_width"`
+	MaxHeight        int           `mapstructure:"max_height"`
+	Padding          int           `mapstructure:"padding"`
+	LayoutIterations int           `mapstructure:"layout_iterations"`
+	CacheSize        int           `mapstructure:"cache_size"`
+	CacheTTL         time.Duration `mapstructure:"cache_ttl"`
+}
+
+// AccessAuditConfig holds entity access auditing and browsing anomaly configuration
+type AccessAuditConfig struct {
+	Enabled               bool          `mapstructure:"enabled"`
+	UserHeader            string        `mapstructure:"user_header"`
+	InvestigationHeader   string        `mapstructure:"investigation_header"`
+	MaxRecordedBodyBytes  int64         `mapstructure:"max_recorded_body_bytes"`
+	DetectionInterval     time.Duration `mapstructure:"detection_interval"`
+	DetectionWindow       time.Duration `mapstructure:"detection_window"`
+	MaxUnassignedEntities int           `mapstructure:"max_unassigned_entities"`
+	AlertCooldown         time.Duration `mapstructure:"alert_cooldown"`
+}
+
+// BackupConfig holds graph snapshot backup configuration
+type BackupConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Interval  time.Duration `mapstructure:"interval"`
+	FullEvery int           `mapstructure:"full_every"` // every Nth scheduled snapshot is full
+	Storage   string        `mapstructure:"storage"`    // filesystem or s3
+	Prefix    string        `mapstructure:"prefix"`
+	Path      string        `mapstructure:"path"`
+	S3        S3Config      `mapstructure:"s3"`
+}
+
+// MaintenanceConfig holds scheduled graph maintenance configuration.
+// Relationships past their retention are archived to the backup storage
+// under ArchivePrefix before they are deleted.
+type MaintenanceConfig struct {
+	Enabled        bool                     `mapstructure:"enabled"`
+	Interval       time.Duration            `mapstructure:"interval"`
+	DryRun         bool                     `mapstructure:"dry_run"` // scheduled runs only report what they would change
+	BatchSize      int                      `mapstructure:"batch_size"`
+	MaxBatches     int                      `mapstructure:"max_batches"` // per job and database in one run
+	OrphanLabels   []string                 `mapstructure:"orphan_labels"`
+	OrphanMinAge   time.Duration            `mapstructure:"orphan_min_age"`  // younger orphans may still be linked
+	DuplicateTypes []string                 `mapstructure:"duplicate_types"` // empty collapses duplicates of every type
+	Retention      map[string]time.Duration `mapstructure:"retention"`       // relationship type to retention window
+	ArchivePrefix  string                   `mapstructure:"archive_prefix"`
+	HistorySize    int                      `mapstructure:"history_size"`
+}
+
+// S3Config holds S3-compatible object storage configuration
+type S3Config struct {
+	Endpoint        string        `mapstructure:"endpoint"`
+	Region          string        `mapstructure:"region"`
+	Bucket          string        `mapstructure:"bucket"`
+	AccessKeyID     string        `mapstructure:"access_key_id"`
+	SecretAccessKey string        `mapstructure:"secret_access_key"`
+	Timeout         time.Duration `mapstructure:"timeout"`
+}
+
+// TenancyConfig holds per-tenant graph database configuration. With tenancy
+// enabled each tenant's graph lives in its own Neo4j database, named by the
+// prefix and the tenant ID.
+type TenancyConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Header          string        `mapstructure:"header"`
+	MetadataKey     string        `mapstructure:"metadata_key"`
+	RequireTenant   bool          `mapstructure:"require_tenant"` // reject requests naming no tenant
+	DatabasePrefix  string        `mapstructure:"database_prefix"`
+	MonitorInterval time.Duration `mapstructure:"monitor_interval"`
+}
+
+// BlockingConfig holds entity resolution blocking configuration. Entities
+// of the listed types are indexed under q-gram and sorted-neighborhood keys
+// of their blocking fields, and similarity matching compares a candidate
+// only with entities sharing a block.
+type BlockingConfig struct {
+	Enabled           bool          `mapstructure:"enabled"`
+	EntityTypes       []string      `mapstructure:"entity_types"`
+	Fields            []string      `mapstructure:"fields"`
+	QGramSize         int           `mapstructure:"qgram_size"`
+	SortedWindow      int           `mapstructure:"sorted_window"`  // neighbouring keys on each side
+	MaxBlockSize      int           `mapstructure:"max_block_size"` // larger blocks are too common to discriminate and are skipped
+	MaxCandidates     int           `mapstructure:"max_candidates"`
+	IndexInterval     time.Duration `mapstructure:"index_interval"`
+	IndexBatchSize    int           `mapstructure:"index_batch_size"`
+	RecallSampleRate  float64       `mapstructure:"recall_sample_rate"` // fraction of lookups also run exhaustively
+	RecallSampleLimit int           `mapstructure:"recall_sample_limit"`
+}
+
+// ExposureConfig holds entity exposure configuration. An entity's exposure
+// is its distance to the nearest sanctioned or flagged entity (a source) and
+// the transaction value that can have reached it from sources within
+// MaxDepth hops. Exposure is recomputed around entities and relationships
+// as they change, and for the whole graph every FullInterval.
+type ExposureConfig struct {
+	Enabled           bool           `mapstructure:"enabled"`
+	SourceProperties  []string       `mapstructure:"source_properties"`  // boolean entity properties marking a source
+	RelationshipTypes []string       `mapstructure:"relationship_types"` // empty follows every type
+	MaxDepth          int            `mapstructure:"max_depth"`
+	TransactionType   string         `mapstructure:"transaction_type"`
+	AmountProperty    string         `mapstructure:"amount_property"`
+	TimeWindow        time.Duration  `mapstructure:"time_window"` // 0 counts every transaction
+	Bands             []ExposureBand `mapstructure:"bands"`       // most severe first
+	RecomputeInterval time.Duration  `mapstructure:"recompute_interval"`
+	FullInterval      time.Duration  `mapstructure:"full_interval"`
+	SourceBatchSize   int            `mapstructure:"source_batch_size"`  // sources whose neighbourhood is loaded at once
+	MaxSubgraphNodes  int            `mapstructure:"max_subgraph_nodes"` // per source batch
+	BatchSize         int            `mapstructure:"batch_size"`         // changed entities recomputed at once
+	MaxListLimit      int            `mapstructure:"max_list_limit"`
+}
+
+// ExposureBand is a named exposure threshold. An entity falls in the first
+// band whose distance and value thresholds it meets.
+type ExposureBand struct {
+	Name        string  `mapstructure:"name"`
+	MaxDistance int     `mapstructure:"max_distance"`
+	MinValue    float64 `mapstructure:"min_value"`
+}
+
+// MotifConfig holds money-laundering motif detection configuration. Motifs
+// are searched for among the transactions of the time window and the
+// ownership relationships between entities. Scheduled runs cover the listed
+// detectors in every database and publish instances scoring at least
+// PublishMinRisk.
+type MotifConfig struct {
+	Enabled              bool          `mapstructure:"enabled"`
+	Interval             time.Duration `mapstructure:"interval"`
+	Detectors            []string      `mapstructure:"detectors"` // empty runs every detector
+	TransactionType      string        `mapstructure:"transaction_type"`
+	AmountProperty       string        `mapstructure:"amount_property"`
+	OwnershipTypes       []string      `mapstructure:"ownership_types"`  // directed from owner to owned
+	ShellProperties      []string      `mapstructure:"shell_properties"` // boolean entity properties marking a shell company
+	TimeWindow           time.Duration `mapstructure:"time_window"`
+	MaxTransactions      int           `mapstructure:"max_transactions"` // loaded per run, most recent first
+	MinConfidence        float64       `mapstructure:"min_confidence"`
+	MaxInstances         int           `mapstructure:"max_instances"` // per detector and run
+	PublishMinRisk       float64       `mapstructure:"publish_min_risk"`
+	MaxCycleLength       int           `mapstructure:"max_cycle_length"`
+	CycleWindow          time.Duration `mapstructure:"cycle_window"` // from the first to the last transaction of a cycle
+	FanMinCounterparties int           `mapstructure:"fan_min_counterparties"`
+	FanWindow            time.Duration `mapstructure:"fan_window"`
+	StructuringThreshold float64       `mapstructure:"structuring_threshold"` // reporting threshold smurfs stay below
+	StructuringMargin    float64       `mapstructure:"structuring_margin"`    // fraction below the threshold counted as just below
+	PassThroughWindow    time.Duration `mapstructure:"pass_through_window"`
+	PassThroughMinRatio  float64       `mapstructure:"pass_through_min_ratio"` // of the smaller to the larger of inflow and outflow
+	PassThroughMinAmount float64       `mapstructure:"pass_through_min_amount"`
+	LayeringMinHops      int           `mapstructure:"layering_min_hops"`
+	LayeringMaxHops      int           `mapstructure:"layering_max_hops"`
+	LayeringMaxShave     float64       `mapstructure:"layering_max_shave"` // largest fraction of the amount kept back per hop
+	LayeringMaxGap       time.Duration `mapstructure:"layering_max_gap"`   // between receiving and passing on
+	ShellMinDepth        int           `mapstructure:"shell_min_depth"`    // shell companies between owner and owned
+	ShellMaxDepth        int           `mapstructure:"shell_max_depth"`
+}
+
+// QueryTemplateConfig holds saved investigative query configuration.
+// Templates are read-only Cypher with declared parameters. Callers are
+// identified by the user and roles headers set by the gateway; AdminRoles
+// may edit, delete and audit every template.
+type QueryTemplateConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	UserHeader       string        `mapstructure:"user_header"`
+	RolesHeader      string        `mapstructure:"roles_header"` // comma-separated
+	AdminRoles       []string      `mapstructure:"admin_roles"`
+	MaxQueryLength   int           `mapstructure:"max_query_length"`
+	MaxParameters    int           `mapstructure:"max_parameters"`
+	DefaultRows      int           `mapstructure:"default_rows"`
+	MaxRows          int           `mapstructure:"max_rows"`
+	ExecutionTimeout time.Duration `mapstructure:"execution_timeout"`
+	MaxHistoryLimit  int           `mapstructure:"max_history_limit"`
+}
+
+// LoadSheddingConfig holds HTTP concurrency limiting configuration. Each
+// request falls in the route class with the longest matching path prefix,
+// or the default class. A class serves up to its current limit of requests
+// at once and queues up to MaxQueue more for at most QueueTimeout; requests
+// beyond that are shed with 503 and a Retry-After header.
+type LoadSheddingConfig struct {
+	Enabled    bool               `mapstructure:"enabled"`
+	RetryAfter time.Duration      `mapstructure:"retry_after"`
+	Classes    []RouteClassConfig `mapstructure:"classes"`
+	Default    RouteClassConfig   `mapstructure:"default"`
+}
+
+// RouteClassConfig holds the concurrency limit of one class of routes. The
+// limit adapts between MinLimit and MaxLimit: it backs off while requests
+// take longer than TargetLatency and grows while the class is saturated and
+// requests are fast.
+type RouteClassConfig struct {
+	Name          string        `mapstructure:"name"`
+	PathPrefixes  []string      `mapstructure:"path_prefixes"`
+	MinLimit      int           `mapstructure:"min_limit"`
+	MaxLimit      int           `mapstructure:"max_limit"`
+	MaxQueue      int           `mapstructure:"max_queue"`
+	QueueTimeout  time.Duration `mapstructure:"queue_timeout"`
+	TargetLatency time.Duration `mapstructure:"target_latency"`
+}
+
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
 	Level  string `mapstructure:"level"`
 	Format string `mapstructure:"format"`
 }
 
+// StartupConfig holds dependency retry configuration for service startup
+type StartupConfig struct {
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`
+	MaxElapsed     time.Duration `mapstructure:"max_elapsed"` // 0 retries until shutdown
+}
+
 // Load loads configuration from environment variables and config files
 func Load() (*Config, error) {
 	viper.SetConfigName("config")
@@ -149,6 +409,7 @@ func setDefaults() {
 	viper.SetDefault("kafka.investigation_topic", "investigations")
 	viper.SetDefault("kafka.pattern_detection_topic", "patterns.detected")
 	viper.SetDefault("kafka.entity_resolved_topic", "entities.resolved")
+	viper.SetDefault("kafka.exposure_topic", "graph.exposure")
 
 	// Graph engine defaults
 	viper.SetDefault("graph_engine.max_traversal_depth", 10)
@@ -161,9 +422,195 @@ func setDefaults() {
 	viper.SetDefault("graph_engine.clustering_threshold", 0.6)
 	viper.SetDefault("graph_engine.anomaly_threshold", 0.8)
 
+	// Geo analysis defaults
+	viper.SetDefault("geo.high_risk_jurisdictions", []string{"IR", "KP", "MM"})
+	viper.SetDefault("geo.high_risk_corridors", []string{})
+	viper.SetDefault("geo.jurisdiction_fields", []string{"jurisdiction", "country_code", "country", "residence_country"})
+	viper.SetDefault("geo.default_network_depth", 2)
+	viper.SetDefault("geo.max_network_depth", 4)
+	viper.SetDefault("geo.max_route_hops", 4)
+	viper.SetDefault("geo.default_time_window", "720h")
+	viper.SetDefault("geo.max_routes", 100)
+
+	// Thumbnail rendering defaults
+	viper.SetDefault("thumbnail.max_nodes", 150)
+	viper.SetDefault("thumbnail.fetch_multiplier", 3)
+	viper.SetDefault("thumbnail.default_depth", 2)
+	viper.SetDefault("thumbnail.max_depth", 3)
+	viper.SetDefault("thumbnail.default_width", 320)
+	viper.SetDefault("thumbnail.default_height", 200)
+	viper.SetDefault("thumbnail.max_width", 1200)
+	viper.SetDefault("thumbnail.max_height", 1200)
+	viper.SetDefault("thumbnail.padding", 12)
+	viper.SetDefault("thumbnail.layout_iterations", 150)
+	viper.SetDefault("thumbnail.cache_size", 500)
+	viper.SetDefault("thumbnail.cache_ttl", "24h")
+
+	// Entity access audit defaults
+	viper.SetDefault("access_audit.enabled", true)
+	viper.SetDefault("access_audit.user_header", "X-User-ID")
+	viper.SetDefault("access_audit.investigation_header", "X-Investigation-ID")
+	viper.SetDefault("access_audit.max_recorded_body_bytes", 65536)
+	viper.SetDefault("access_audit.detection_interval", "15m")
+	viper.SetDefault("access_audit.detection_window", "1h")
+	viper.SetDefault("access_audit.max_unassigned_entities", 50)
+	viper.SetDefault("access_audit.alert_cooldown", "24h")
+
+	// Backup defaults
+	viper.SetDefault("backup.enabled", false)
+	viper.SetDefault("backup.interval", "1h")
+	viper.SetDefault("backup.full_every", 24)
+	viper.SetDefault("backup.storage", "filesystem")
+	viper.SetDefault("backup.prefix", "graph-engine")
+	viper.SetDefault("backup.path", "/var/lib/graph-engine/backups")
+	viper.SetDefault("backup.s3.endpoint", "https://s3.amazonaws.com")
+	viper.SetDefault("backup.s3.region", "us-east-1")
+	viper.SetDefault("backup.s3.bucket", "")
+	viper.SetDefault("backup.s3.timeout", "5m")
+
+	// Maintenance defaults
+	viper.SetDefault("maintenance.enabled", false)
+	viper.SetDefault("maintenance.interval", "24h")
+	viper.SetDefault("maintenance.dry_run", true)
+	viper.SetDefault("maintenance.batch_size", 1000)
+	viper.SetDefault("maintenance.max_batches", 100)
+	viper.SetDefault("maintenance.orphan_labels", []string{"Entity"})
+	viper.SetDefault("maintenance.orphan_min_age", "720h")
+	viper.SetDefault("maintenance.duplicate_types", []string{})
+	viper.SetDefault("maintenance.retention", map[string]string{})
+	viper.SetDefault("maintenance.archive_prefix", "graph-engine/archives")
+	viper.SetDefault("maintenance.history_size", 50)
+
+	// Tenancy defaults
+	viper.SetDefault("tenancy.enabled", false)
+	viper.SetDefault("tenancy.header", "X-Tenant-ID")
+	viper.SetDefault("tenancy.metadata_key", "x-tenant-id")
+	viper.SetDefault("tenancy.require_tenant", false)
+	viper.SetDefault("tenancy.database_prefix", "tenant-")
+	viper.SetDefault("tenancy.monitor_interval", "1m")
+
+	// Blocking defaults
+	viper.SetDefault("blocking.enabled", true)
+	viper.SetDefault("blocking.entity_types", []string{"Person", "Company", "Account"})
+	viper.SetDefault("blocking.fields", []string{"name"})
+	viper.SetDefault("blocking.qgram_size", 3)
+	viper.SetDefault("blocking.sorted_window", 5)
+	viper.SetDefault("blocking.max_block_size", 1000)
+	viper.SetDefault("blocking.max_candidates", 200)
+	viper.SetDefault("blocking.index_interval", "1m")
+	viper.SetDefault("blocking.index_batch_size", 500)
+	viper.SetDefault("blocking.recall_sample_rate", 0.01)
+	viper.SetDefault("blocking.recall_sample_limit", 1000)
+
+	// Exposure defaults
+	viper.SetDefault("exposure.enabled", true)
+	viper.SetDefault("exposure.source_properties", []string{"sanctioned", "flagged"})
+	viper.SetDefault("exposure.relationship_types", []string{})
+	viper.SetDefault("exposure.max_depth", 3)
+	viper.SetDefault("exposure.transaction_type", "TRANSACTION")
+	viper.SetDefault("exposure.amount_property", "amount")
+	viper.SetDefault("exposure.time_window", "8760h")
+	viper.SetDefault("exposure.bands", []map[string]interface{}{
+		{"name": "critical", "max_distance": 1, "min_value": 0},
+		{"name": "high", "max_distance": 2, "min_value": 10000},
+		{"name": "medium", "max_distance": 3, "min_value": 0},
+	})
+	viper.SetDefault("exposure.recompute_interval", "30s")
+	viper.SetDefault("exposure.full_interval", "24h")
+	viper.SetDefault("exposure.source_batch_size", 50)
+	viper.SetDefault("exposure.max_subgraph_nodes", 20000)
+	viper.SetDefault("exposure.batch_size", 500)
+	viper.SetDefault("exposure.max_list_limit", 1000)
+
+	// Motif detection defaults
+	viper.SetDefault("motifs.enabled", true)
+	viper.SetDefault("motifs.interval", "1h")
+	viper.SetDefault("motifs.detectors", []string{})
+	viper.SetDefault("motifs.transaction_type", "TRANSACTION")
+	viper.SetDefault("motifs.amount_property", "amount")
+	viper.SetDefault("motifs.ownership_types", []string{"OWNS", "CONTROLS"})
+	viper.SetDefault("motifs.shell_properties", []string{"shell_company"})
+	viper.SetDefault("motifs.time_window", "720h")
+	viper.SetDefault("motifs.max_transactions", 200000)
+	viper.SetDefault("motifs.min_confidence", 0.5)
+	viper.SetDefault("motifs.max_instances", 500)
+	viper.SetDefault("motifs.publish_min_risk", 70.0)
+	viper.SetDefault("motifs.max_cycle_length", 6)
+	viper.SetDefault("motifs.cycle_window", "720h")
+	viper.SetDefault("motifs.fan_min_counterparties", 5)
+	viper.SetDefault("motifs.fan_window", "72h")
+	viper.SetDefault("motifs.structuring_threshold", 10000.0)
+	viper.SetDefault("motifs.structuring_margin", 0.1)
+	viper.SetDefault("motifs.pass_through_window", "48h")
+	viper.SetDefault("motifs.pass_through_min_ratio", 0.8)
+	viper.SetDefault("motifs.pass_through_min_amount", 5000.0)
+	viper.SetDefault("motifs.layering_min_hops", 3)
+	viper.SetDefault("motifs.layering_max_hops", 8)
+	viper.SetDefault("motifs.layering_max_shave", 0.1)
+	viper.SetDefault("motifs.layering_max_gap", "72h")
+	viper.SetDefault("motifs.shell_min_depth", 2)
+	viper.SetDefault("motifs.shell_max_depth", 6)
+
+	// Query template defaults
+	viper.SetDefault("query_templates.enabled", true)
+	viper.SetDefault("query_templates.user_header", "X-User-ID")
+	viper.SetDefault("query_templates.roles_header", "X-User-Roles")
+	viper.SetDefault("query_templates.admin_roles", []string{"admin"})
+	viper.SetDefault("query_templates.max_query_length", 10000)
+	viper.SetDefault("query_templates.max_parameters", 20)
+	viper.SetDefault("query_templates.default_rows", 500)
+	viper.SetDefault("query_templates.max_rows", 5000)
+	viper.SetDefault("query_templates.execution_timeout", "30s")
+	viper.SetDefault("query_templates.max_history_limit", 500)
+
+	// Load shedding defaults: graph analyses run long Neo4j queries and get
+	// few slots so lookups keep being served while they queue
+	viper.SetDefault("load_shedding.enabled", true)
+	viper.SetDefault("load_shedding.retry_after", "5s")
+	viper.SetDefault("load_shedding.classes", []map[string]interface{}{
+		{
+			"name":           "analysis",
+			"path_prefixes":  []string{"/api/v1/analysis", "/api/v1/analytics", "/api/v1/patterns/detect", "/api/v1/geo/cross-border-flows", "/api/v1/graph/thumbnail", "/api/v1/exposure/recompute", "/api/v1/query-executions"},
+			"min_limit":      2,
+			"max_limit":      16,
+			"max_queue":      32,
+			"queue_timeout":  "2s",
+			"target_latency": "5s",
+		},
+		{
+			"name":           "lookup",
+			"path_prefixes":  []string{"/api/v1/entities", "/api/v1/patterns", "/api/v1/exposure", "/api/v1/resolution", "/api/v1/geo", "/api/v1/relationships"},
+			"min_limit":      8,
+			"max_limit":      64,
+			"max_queue":      128,
+			"queue_timeout":  "1s",
+			"target_latency": "500ms",
+		},
+		{
+			"name":           "admin",
+			"path_prefixes":  []string{"/api/v1/backups", "/api/v1/maintenance", "/api/v1/tenants", "/api/v1/access-audit"},
+			"min_limit":      1,
+			"max_limit":      4,
+			"max_queue":      8,
+			"queue_timeout":  "5s",
+			"target_latency": "30s",
+		},
+	})
+	viper.SetDefault("load_shedding.default.name", "default")
+	viper.SetDefault("load_shedding.default.min_limit", 16)
+	viper.SetDefault("load_shedding.default.max_limit", 128)
+	viper.SetDefault("load_shedding.default.max_queue", 256)
+	viper.SetDefault("load_shedding.default.queue_timeout", "1s")
+	viper.SetDefault("load_shedding.default.target_latency", "1s")
+
 	// Logging defaults
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "json")
+
+	// Startup defaults
+	viper.SetDefault("startup.initial_backoff", "500ms")
+	viper.SetDefault("startup.max_backoff", "30s")
+	viper.SetDefault("startup.max_elapsed", "5m")
 }
 
 func validateConfig(config *Config) error {
@@ -236,5 +683,269 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("anomaly_threshold must be between 0 and 1")
 	}
 
+	// Validate geo configuration
+	if config.Geo.MaxRouteHops <= 0 {
+		return fmt.Errorf("geo max_route_hops must be positive")
+	}
+
+	if config.Geo.DefaultNetworkDepth <= 0 || config.Geo.DefaultNetworkDepth > config.Geo.MaxNetworkDepth {
+		return fmt.Errorf("geo default_network_depth must be between 1 and max_network_depth")
+	}
+
+	// Validate thumbnail configuration
+	if config.Thumbnail.MaxNodes <= 0 || config.Thumbnail.FetchMultiplier <= 0 {
+		return fmt.Errorf("thumbnail max_nodes and fetch_multiplier must be positive")
+	}
+
+	if config.Thumbnail.MaxDepth <= 0 {
+		return fmt.Errorf("thumbnail max_depth must be positive")
+	}
+
+	if config.Thumbnail.MaxWidth <= 0 || config.Thumbnail.MaxHeight <= 0 {
+		return fmt.Errorf("thumbnail max_width and max_height must be positive")
+	}
+
+	// Validate access audit configuration
+	if config.AccessAudit.Enabled {
+		if config.AccessAudit.DetectionWindow <= 0 || config.AccessAudit.DetectionInterval <= 0 {
+			return fmt.Errorf("access_audit detection_window and detection_interval must be positive")
+		}
+
+		if config.AccessAudit.MaxUnassignedEntities <= 0 {
+			return fmt.Errorf("access_audit max_unassigned_entities must be positive")
+		}
+	}
+
+	// Validate backup configuration
+	switch config.Backup.Storage {
+	case "filesystem":
+		if config.Backup.Path == "" {
+			return fmt.Errorf("backup path is required for filesystem storage")
+		}
+	case "s3":
+		if config.Backup.S3.Endpoint == "" || config.Backup.S3.Bucket == "" {
+			return fmt.Errorf("backup s3 endpoint and bucket are required for s3 storage")
+		}
+	default:
+		return fmt.Errorf("invalid backup storage: %s", config.Backup.Storage)
+	}
+
+	if config.Backup.Enabled && (config.Backup.Interval <= 0 || config.Backup.FullEvery <= 0) {
+		return fmt.Errorf("backup interval and full_every must be positive")
+	}
+
+	// Validate maintenance configuration
+	if config.Maintenance.Enabled && config.Maintenance.Interval <= 0 {
+		return fmt.Errorf("maintenance interval must be positive")
+	}
+
+	if config.Maintenance.BatchSize <= 0 || config.Maintenance.MaxBatches <= 0 || config.Maintenance.HistorySize <= 0 {
+		return fmt.Errorf("maintenance batch_size, max_batches and history_size must be positive")
+	}
+
+	if config.Maintenance.OrphanMinAge < 0 {
+		return fmt.Errorf("maintenance orphan_min_age must not be negative")
+	}
+
+	for relType, retention := range config.Maintenance.Retention {
+		if retention <= 0 {
+			return fmt.Errorf("maintenance retention for %s must be positive", relType)
+		}
+	}
+
+	// Validate tenancy configuration
+	if config.Tenancy.Enabled {
+		if config.Tenancy.Header == "" || config.Tenancy.MetadataKey == "" {
+			return fmt.Errorf("tenancy header and metadata_key are required")
+		}
+
+		if !tenantDatabasePrefix.MatchString(config.Tenancy.DatabasePrefix) {
+			return fmt.Errorf("invalid tenancy database_prefix: %q", config.Tenancy.DatabasePrefix)
+		}
+
+		if config.Tenancy.MonitorInterval <= 0 {
+			return fmt.Errorf("tenancy monitor_interval must be positive")
+		}
+	}
+
+	// Validate blocking configuration
+	if config.Blocking.Enabled {
+		if len(config.Blocking.EntityTypes) == 0 || len(config.Blocking.Fields) == 0 {
+			return fmt.Errorf("blocking entity_types and fields are required")
+		}
+
+		for _, entityType := range config.Blocking.EntityTypes {
+			if !blockingEntityType.MatchString(entityType) {
+				return fmt.Errorf("invalid blocking entity type: %q", entityType)
+			}
+		}
+
+		if config.Blocking.QGramSize < 2 || config.Blocking.SortedWindow < 0 {
+			return fmt.Errorf("blocking qgram_size must be at least 2 and sorted_window not negative")
+		}
+
+		if config.Blocking.MaxBlockSize <= 0 || config.Blocking.MaxCandidates <= 0 || config.Blocking.IndexBatchSize <= 0 {
+			return fmt.Errorf("blocking max_block_size, max_candidates and index_batch_size must be positive")
+		}
+
+		if config.Blocking.IndexInterval <= 0 {
+			return fmt.Errorf("blocking index_interval must be positive")
+		}
+
+		if config.Blocking.RecallSampleRate < 0 || config.Blocking.RecallSampleRate > 1 || config.Blocking.RecallSampleLimit <= 0 {
+			return fmt.Errorf("blocking recall_sample_rate must be between 0 and 1 and recall_sample_limit positive")
+		}
+	}
+
+	// Validate exposure configuration
+	if config.Exposure.Enabled {
+		if len(config.Exposure.SourceProperties) == 0 {
+			return fmt.Errorf("exposure source_properties are required")
+		}
+
+		identifiers := append([]string{config.Exposure.TransactionType, config.Exposure.AmountProperty}, config.Exposure.SourceProperties...)
+		for _, identifier := range append(identifiers, config.Exposure.RelationshipTypes...) {
+			if !cypherIdentifier.MatchString(identifier) {
+				return fmt.Errorf("invalid exposure property or relationship type: %q", identifier)
+			}
+		}
+
+		if config.Exposure.MaxDepth < 1 || config.Exposure.MaxDepth > 6 {
+			return fmt.Errorf("exposure max_depth must be between 1 and 6")
+		}
+
+		if config.Exposure.TimeWindow < 0 {
+			return fmt.Errorf("exposure time_window must not be negative")
+		}
+
+		seen := make(map[string]bool, len(config.Exposure.Bands))
+		for _, band := range config.Exposure.Bands {
+			if band.Name == "" || band.Name == "none" || band.Name == "low" || seen[band.Name] {
+				return fmt.Errorf("invalid or duplicate exposure band name: %q", band.Name)
+			}
+			seen[band.Name] = true
+
+			if band.MaxDistance < 0 || band.MinValue < 0 {
+				return fmt.Errorf("exposure band %s thresholds must not be negative", band.Name)
+			}
+		}
+
+		if config.Exposure.RecomputeInterval <= 0 || config.Exposure.FullInterval <= 0 {
+			return fmt.Errorf("exposure recompute_interval and full_interval must be positive")
+		}
+
+		if config.Exposure.SourceBatchSize <= 0 || config.Exposure.MaxSubgraphNodes <= 0 || config.Exposure.BatchSize <= 0 || config.Exposure.MaxListLimit <= 0 {
+			return fmt.Errorf("exposure source_batch_size, max_subgraph_nodes, batch_size and max_list_limit must be positive")
+		}
+	}
+
+	// Validate motif detection configuration
+	if config.Motifs.Enabled {
+		identifiers := append([]string{config.Motifs.TransactionType, config.Motifs.AmountProperty}, config.Motifs.ShellProperties...)
+		for _, identifier := range append(identifiers, config.Motifs.OwnershipTypes...) {
+			if !cypherIdentifier.MatchString(identifier) {
+				return fmt.Errorf("invalid motifs property or relationship type: %q", identifier)
+			}
+		}
+
+		for _, detector := range config.Motifs.Detectors {
+			if !motifDetectors[detector] {
+				return fmt.Errorf("unknown motifs detector: %q", detector)
+			}
+		}
+
+		if config.Motifs.Interval <= 0 || config.Motifs.TimeWindow <= 0 || config.Motifs.CycleWindow <= 0 || config.Motifs.FanWindow <= 0 || config.Motifs.PassThroughWindow <= 0 || config.Motifs.LayeringMaxGap <= 0 {
+			return fmt.Errorf("motifs interval, time_window and detector windows must be positive")
+		}
+
+		if config.Motifs.MaxTransactions <= 0 || config.Motifs.MaxInstances <= 0 {
+			return fmt.Errorf("motifs max_transactions and max_instances must be positive")
+		}
+
+		if config.Motifs.MinConfidence < 0 || config.Motifs.MinConfidence > 1 || config.Motifs.PublishMinRisk < 0 || config.Motifs.PublishMinRisk > 100 {
+			return fmt.Errorf("motifs min_confidence must be between 0 and 1 and publish_min_risk between 0 and 100")
+		}
+
+		if config.Motifs.MaxCycleLength < 2 || config.Motifs.MaxCycleLength > 10 {
+			return fmt.Errorf("motifs max_cycle_length must be between 2 and 10")
+		}
+
+		if config.Motifs.FanMinCounterparties < 2 || config.Motifs.StructuringThreshold <= 0 || config.Motifs.StructuringMargin <= 0 || config.Motifs.StructuringMargin >= 1 {
+			return fmt.Errorf("motifs fan_min_counterparties must be at least 2, structuring_threshold positive and structuring_margin between 0 and 1")
+		}
+
+		if config.Motifs.PassThroughMinRatio <= 0 || config.Motifs.PassThroughMinRatio > 1 || config.Motifs.PassThroughMinAmount < 0 {
+			return fmt.Errorf("motifs pass_through_min_ratio must be between 0 and 1 and pass_through_min_amount not negative")
+		}
+
+		if config.Motifs.LayeringMinHops < 2 || config.Motifs.LayeringMaxHops < config.Motifs.LayeringMinHops || config.Motifs.LayeringMaxHops > 12 {
+			return fmt.Errorf("motifs layering_min_hops must be at least 2 and layering_max_hops between it and 12")
+		}
+
+		if config.Motifs.LayeringMaxShave <= 0 || config.Motifs.LayeringMaxShave >= 1 {
+			return fmt.Errorf("motifs layering_max_shave must be between 0 and 1")
+		}
+
+		if config.Motifs.ShellMinDepth < 1 || config.Motifs.ShellMaxDepth < config.Motifs.ShellMinDepth || config.Motifs.ShellMaxDepth > 10 {
+			return fmt.Errorf("motifs shell_min_depth must be at least 1 and shell_max_depth between it and 10")
+		}
+	}
+
+	// Validate query template configuration
+	if config.QueryTemplates.Enabled {
+		if config.QueryTemplates.UserHeader == "" || config.QueryTemplates.RolesHeader == "" {
+			return fmt.Errorf("query_templates user_header and roles_header are required")
+		}
+
+		if config.QueryTemplates.MaxQueryLength <= 0 || config.QueryTemplates.MaxParameters <= 0 {
+			return fmt.Errorf("query_templates max_query_length and max_parameters must be positive")
+		}
+
+		if config.QueryTemplates.DefaultRows <= 0 || config.QueryTemplates.MaxRows < config.QueryTemplates.DefaultRows {
+			return fmt.Errorf("query_templates default_rows must be positive and max_rows at least default_rows")
+		}
+
+		if config.QueryTemplates.ExecutionTimeout <= 0 || config.QueryTemplates.MaxHistoryLimit <= 0 {
+			return fmt.Errorf("query_templates execution_timeout and max_history_limit must be positive")
+		}
+	}
+
+	// Validate load shedding configuration
+	if config.LoadShedding.Enabled {
+		if config.LoadShedding.RetryAfter <= 0 {
+			return fmt.Errorf("load_shedding retry_after must be positive")
+		}
+
+		classes := append([]RouteClassConfig{config.LoadShedding.Default}, config.LoadShedding.Classes...)
+		seen := make(map[string]bool, len(classes))
+		for i, class := range classes {
+			if class.Name == "" || seen[class.Name] {
+				return fmt.Errorf("invalid or duplicate load_shedding class name: %q", class.Name)
+			}
+			seen[class.Name] = true
+
+			if i > 0 && len(class.PathPrefixes) == 0 {
+				return fmt.Errorf("load_shedding class %s needs path_prefixes", class.Name)
+			}
+
+			if class.MinLimit <= 0 || class.MaxLimit < class.MinLimit {
+				return fmt.Errorf("load_shedding class %s min_limit must be positive and max_limit at least min_limit", class.Name)
+			}
+
+			if class.MaxQueue < 0 || class.QueueTimeout < 0 || class.TargetLatency <= 0 {
+				return fmt.Errorf("load_shedding class %s max_queue and queue_timeout must not be negative and target_latency positive", class.Name)
+			}
+		}
+	}
+
+	// Validate startup configuration
+	if config.Startup.InitialBackoff <= 0 || config.Startup.MaxBackoff < config.Startup.InitialBackoff {
+		return fmt.Errorf("startup initial_backoff must be positive and max_backoff at least initial_backoff")
+	}
+
+	if config.Startup.MaxElapsed < 0 {
+		return fmt.Errorf("startup max_elapsed must not be negative")
+	}
+
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,324 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrAccessAlertNotFound is returned when an entity access alert does not exist
+var ErrAccessAlertNotFound = errors.New("entity access alert not found")
+
+// EntityAccessEvent records one view of one or more entities
+type EntityAccessEvent struct {
+	ID              string                 `json:"id"`
+	UserID          string                 `json:"user_id"`
+	EntityIDs       []string               `json:"entity_ids"`
+	ViewType        string                 `json:"view_type"`
+	Method          string                 `json:"method"`
+	Path            string                 `json:"path"`
+	QueryParams     map[string]interface{} `json:"query_params,omitempty"`
+	InvestigationID string                 `json:"investigation_id,omitempty"`
+	ClientIP        string                 `json:"client_ip,omitempty"`
+	StatusCode      int                    `json:"status_code"`
+	AccessedAt      time.Time              `json:"accessed_at"`
+}
+
+// EntityAccessFilter narrows entity access event queries
+type EntityAccessFilter struct {
+	EntityID string
+	UserID   string
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+}
+
+// EntityViewer summarises one user's views of an entity
+type EntityViewer struct {
+	UserID        string    `json:"user_id"`
+	ViewCount     int       `json:"view_count"`
+	ViewTypes     []string  `json:"view_types"`
+	FirstViewedAt time.Time `json:"first_viewed_at"`
+	LastViewedAt  time.Time `json:"last_viewed_at"`
+}
+
+// EntityAccessAlert flags a user browsing many entities outside their assigned investigations
+type EntityAccessAlert struct {
+	ID                 string     `json:"id"`
+	UserID             string     `json:"user_id"`
+	WindowStart        time.Time  `json:"window_start"`
+	WindowEnd          time.Time  `json:"window_end"`
+	DistinctEntities   int        `json:"distinct_entities"`
+	UnassignedEntities int        `json:"unassigned_entities"`
+	SampleEntityIDs    []string   `json:"sample_entity_ids"`
+	Severity           string     `json:"severity"`
+	Status             string     `json:"status"`
+	AcknowledgedBy     string     `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt     *time.Time `json:"acknowledged_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// Entity Access Audit Operations
+
+// RecordEntityAccess stores an entity access event
+func (r *Repository) RecordEntityAccess(ctx context.Context, event *EntityAccessEvent) error {
+	params, err := json.Marshal(event.QueryParams)
+	if err != nil {
+		return fmt.Errorf("failed to marshal query params: %w", err)
+	}
+
+	query := `
+		INSERT INTO entity_access_events
+			(id, user_id, entity_ids, view_type, method, path, query_params, investigation_id, client_ip, status_code, accessed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''), $10, $11)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		event.ID, event.UserID, pq.Array(event.EntityIDs), event.ViewType, event.Method, event.Path,
+		params, event.InvestigationID, event.ClientIP, event.StatusCode, event.AccessedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record entity access: %w", err)
+	}
+
+	return nil
+}
+
+// ListEntityAccessEvents lists access events, newest first
+func (r *Repository) ListEntityAccessEvents(ctx context.Context, filter EntityAccessFilter) ([]*EntityAccessEvent, error) {
+	query := `
+		SELECT id, user_id, entity_ids, view_type, method, path, query_params,
+			   COALESCE(investigation_id, ''), COALESCE(client_ip, ''), status_code, accessed_at
+		FROM entity_access_events
+		WHERE ($1 = '' OR $1 = ANY(entity_ids))
+		  AND ($2 = '' OR user_id = $2)
+		  AND ($3::timestamptz IS NULL OR accessed_at >= $3)
+		  AND ($4::timestamptz IS NULL OR accessed_at <= $4)
+		ORDER BY accessed_at DESC
+		LIMIT $5
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.EntityID, filter.UserID, filter.From, filter.To, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entity access events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*EntityAccessEvent
+	for rows.Next() {
+		var event EntityAccessEvent
+		var params []byte
+		if err := rows.Scan(&event.ID, &event.UserID, pq.Array(&event.EntityIDs), &event.ViewType,
+			&event.Method, &event.Path, &params, &event.InvestigationID, &event.ClientIP,
+			&event.StatusCode, &event.AccessedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan entity access event: %w", err)
+		}
+		if len(params) > 0 {
+			json.Unmarshal(params, &event.QueryParams)
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+// ListEntityViewers summarises who viewed an entity in a time range, most recent viewer first
+func (r *Repository) ListEntityViewers(ctx context.Context, entityID string, from, to *time.Time) ([]*EntityViewer, error) {
+	query := `
+		SELECT user_id, COUNT(*), ARRAY_AGG(DISTINCT view_type), MIN(accessed_at), MAX(accessed_at)
+		FROM entity_access_events
+		WHERE $1 = ANY(entity_ids)
+		  AND ($2::timestamptz IS NULL OR accessed_at >= $2)
+		  AND ($3::timestamptz IS NULL OR accessed_at <= $3)
+		GROUP BY user_id
+		ORDER BY MAX(accessed_at) DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, entityID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entity viewers: %w", err)
+	}
+	defer rows.Close()
+
+	var viewers []*EntityViewer
+	for rows.Next() {
+		var viewer EntityViewer
+		if err := rows.Scan(&viewer.UserID, &viewer.ViewCount, pq.Array(&viewer.ViewTypes),
+			&viewer.FirstViewedAt, &viewer.LastViewedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan entity viewer: %w", err)
+		}
+		viewers = append(viewers, &viewer)
+	}
+
+	return viewers, rows.Err()
+}
+
+// ListViewedEntitiesByUser returns the distinct entities each user successfully viewed since a time
+func (r *Repository) ListViewedEntitiesByUser(ctx context.Context, since time.Time) (map[string][]string, error) {
+	query := `
+		SELECT user_id, ARRAY_AGG(DISTINCT entity_id ORDER BY entity_id)
+		FROM entity_access_events, UNNEST(entity_ids) AS entity_id
+		WHERE accessed_at >= $1 AND status_code < 400
+		GROUP BY user_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list viewed entities: %w", err)
+	}
+	defer rows.Close()
+
+	views := make(map[string][]string)
+	for rows.Next() {
+		var userID string
+		var entityIDs []string
+		if err := rows.Scan(&userID, pq.Array(&entityIDs)); err != nil {
+			return nil, fmt.Errorf("failed to scan viewed entities: %w", err)
+		}
+		views[userID] = entityIDs
+	}
+
+	return views, rows.Err()
+}
+
+// ListAssignedEntities returns the entities in open investigations assigned to each user
+func (r *Repository) ListAssignedEntities(ctx context.Context, userIDs []string) (map[string]map[string]bool, error) {
+	query := `
+		SELECT assigned_to, entities
+		FROM investigations
+		WHERE assigned_to = ANY($1) AND status <> 'closed'
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assigned entities: %w", err)
+	}
+	defer rows.Close()
+
+	assigned := make(map[string]map[string]bool)
+	for rows.Next() {
+		var userID string
+		var entityIDs []string
+		if err := rows.Scan(&userID, pq.Array(&entityIDs)); err != nil {
+			return nil, fmt.Errorf("failed to scan assigned entities: %w", err)
+		}
+		if assigned[userID] == nil {
+			assigned[userID] = make(map[string]bool)
+		}
+		for _, entityID := range entityIDs {
+			assigned[userID][entityID] = true
+		}
+	}
+
+	return assigned, rows.Err()
+}
+
+// HasRecentEntityAccessAlert reports whether a user was alerted on since a time
+func (r *Repository) HasRecentEntityAccessAlert(ctx context.Context, userID string, since time.Time) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM entity_access_alerts WHERE user_id = $1 AND created_at >= $2)`,
+		userID, since).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check entity access alerts: %w", err)
+	}
+	return exists, nil
+}
+
+// CreateEntityAccessAlert stores an entity access alert
+func (r *Repository) CreateEntityAccessAlert(ctx context.Context, alert *EntityAccessAlert) error {
+	query := `
+		INSERT INTO entity_access_alerts
+			(id, user_id, window_start, window_end, distinct_entities, unassigned_entities,
+			 sample_entity_ids, severity, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		alert.ID, alert.UserID, alert.WindowStart, alert.WindowEnd, alert.DistinctEntities,
+		alert.UnassignedEntities, pq.Array(alert.SampleEntityIDs), alert.Severity, alert.Status, alert.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create entity access alert: %w", err)
+	}
+
+	r.logger.Info("Entity access alert created", "alert_id", alert.ID, "user_id", alert.UserID)
+	return nil
+}
+
+// ListEntityAccessAlerts lists entity access alerts, newest first
+func (r *Repository) ListEntityAccessAlerts(ctx context.Context, status, userID string, limit int) ([]*EntityAccessAlert, error) {
+	query := `
+		SELECT id, user_id, window_start, window_end, distinct_entities, unassigned_entities,
+			   sample_entity_ids, severity, status, acknowledged_by, acknowledged_at, created_at
+		FROM entity_access_alerts
+		WHERE ($1 = '' OR status = $1) AND ($2 = '' OR user_id = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entity access alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []*EntityAccessAlert
+	for rows.Next() {
+		alert, err := scanEntityAccessAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+// AcknowledgeEntityAccessAlert marks an alert as reviewed
+func (r *Repository) AcknowledgeEntityAccessAlert(ctx context.Context, alertID, acknowledgedBy string) (*EntityAccessAlert, error) {
+	query := `
+		UPDATE entity_access_alerts
+		SET status = 'acknowledged', acknowledged_by = $2, acknowledged_at = NOW()
+		WHERE id = $1
+		RETURNING id, user_id, window_start, window_end, distinct_entities, unassigned_entities,
+				  sample_entity_ids, severity, status, acknowledged_by, acknowledged_at, created_at
+	`
+
+	alert, err := scanEntityAccessAlert(r.db.QueryRowContext(ctx, query, alertID, acknowledgedBy))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccessAlertNotFound
+	}
+	return alert, err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntityAccessAlert(row rowScanner) (*EntityAccessAlert, error) {
+	var alert EntityAccessAlert
+	var acknowledgedBy sql.NullString
+	var acknowledgedAt sql.NullTime
+
+	if err := row.Scan(&alert.ID, &alert.UserID, &alert.WindowStart, &alert.WindowEnd,
+		&alert.DistinctEntities, &alert.UnassignedEntities, pq.Array(&alert.SampleEntityIDs),
+		&alert.Severity, &alert.Status, &acknowledgedBy, &acknowledgedAt, &alert.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan entity access alert: %w", err)
+	}
+
+	if acknowledgedBy.Valid {
+		alert.AcknowledgedBy = acknowledgedBy.String
+	}
+	if acknowledgedAt.Valid {
+		alert.AcknowledgedAt = &acknowledgedAt.Time
+	}
+
+	return &alert, nil
+}
@@ -0,0 +1,303 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrQueryTemplateNotFound is returned when a query template does not exist
+	ErrQueryTemplateNotFound = errors.New("query template not found")
+
+	// ErrQueryTemplateConflict is returned when a query template was changed
+	// since the version an update was based on
+	ErrQueryTemplateConflict = errors.New("query template was modified concurrently")
+)
+
+// QueryTemplate is a saved, parameterized read-only Cypher query
+type QueryTemplate struct {
+	ID          string                    `json:"id"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description,omitempty"`
+	Query       string                    `json:"query"`
+	Parameters  []*QueryTemplateParameter `json:"parameters"`
+	Tags        []string                  `json:"tags"`
+	Visibility  string                    `json:"visibility"`
+	SharedRoles []string                  `json:"shared_roles"`
+	OwnerID     string                    `json:"owner_id"`
+	Version     int                       `json:"version"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+	UpdatedBy   string                    `json:"updated_by"`
+}
+
+// QueryTemplateParameter declares one $parameter of a query template and
+// the constraints its values must meet
+type QueryTemplateParameter struct {
+	Name          string      `json:"name"`
+	Type          string      `json:"type"`
+	Description   string      `json:"description,omitempty"`
+	Required      bool        `json:"required"`
+	Default       interface{} `json:"default,omitempty"`
+	Pattern       string      `json:"pattern,omitempty"`
+	AllowedValues []string    `json:"allowed_values,omitempty"`
+	Min           *float64    `json:"min,omitempty"`
+	Max           *float64    `json:"max,omitempty"`
+	MaxLength     int         `json:"max_length,omitempty"`
+	MaxItems      int         `json:"max_items,omitempty"`
+}
+
+// QueryTemplateFilter narrows query template listings to what a caller may see
+type QueryTemplateFilter struct {
+	UserID string
+	Roles  []string
+	All    bool // ignore visibility, for administrators
+	Tag    string
+}
+
+// QueryExecution records one run of a query template
+type QueryExecution struct {
+	ID              string                 `json:"id"`
+	TemplateID      string                 `json:"template_id"`
+	TemplateName    string                 `json:"template_name"`
+	TemplateVersion int                    `json:"template_version"`
+	Query           string                 `json:"query"`
+	Parameters      map[string]interface{} `json:"parameters"`
+	UserID          string                 `json:"user_id"`
+	InvestigationID string                 `json:"investigation_id,omitempty"`
+	Status          string                 `json:"status"`
+	RowCount        int                    `json:"row_count"`
+	Truncated       bool                   `json:"truncated"`
+	Error           string                 `json:"error,omitempty"`
+	DurationMs      int64                  `json:"duration_ms"`
+	ExecutedAt      time.Time              `json:"executed_at"`
+}
+
+// QueryExecutionFilter narrows query execution history queries
+type QueryExecutionFilter struct {
+	TemplateID      string
+	UserID          string
+	InvestigationID string
+	Limit           int
+}
+
+// Query Template Operations
+
+// CreateQueryTemplate stores a new query template
+func (r *Repository) CreateQueryTemplate(ctx context.Context, template *QueryTemplate) error {
+	parameters, err := json.Marshal(template.Parameters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal template parameters: %w", err)
+	}
+
+	query := `
+		INSERT INTO query_templates
+			(id, name, description, query, parameters, tags, visibility, shared_roles,
+			 owner_id, version, created_at, updated_at, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		template.ID, template.Name, template.Description, template.Query, parameters,
+		pq.Array(template.Tags), template.Visibility, pq.Array(template.SharedRoles),
+		template.OwnerID, template.Version, template.CreatedAt, template.UpdatedAt, template.UpdatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to create query template: %w", err)
+	}
+
+	r.logger.Info("Query template created", "template_id", template.ID, "owner_id", template.OwnerID)
+	return nil
+}
+
+// UpdateQueryTemplate replaces a query template, provided it is still at
+// expectedVersion
+func (r *Repository) UpdateQueryTemplate(ctx context.Context, template *QueryTemplate, expectedVersion int) error {
+	parameters, err := json.Marshal(template.Parameters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal template parameters: %w", err)
+	}
+
+	query := `
+		UPDATE query_templates
+		SET name = $3, description = $4, query = $5, parameters = $6, tags = $7, visibility = $8,
+			shared_roles = $9, version = $10, updated_at = $11, updated_by = $12
+		WHERE id = $1 AND version = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		template.ID, expectedVersion, template.Name, template.Description, template.Query, parameters,
+		pq.Array(template.Tags), template.Visibility, pq.Array(template.SharedRoles),
+		template.Version, template.UpdatedAt, template.UpdatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to update query template: %w", err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update query template: %w", err)
+	}
+	if updated == 0 {
+		return ErrQueryTemplateConflict
+	}
+
+	return nil
+}
+
+// GetQueryTemplate retrieves a query template by ID
+func (r *Repository) GetQueryTemplate(ctx context.Context, id string) (*QueryTemplate, error) {
+	query := `
+		SELECT id, name, description, query, parameters, tags, visibility, shared_roles,
+			   owner_id, version, created_at, updated_at, updated_by
+		FROM query_templates
+		WHERE id = $1
+	`
+
+	template, err := scanQueryTemplate(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrQueryTemplateNotFound
+	}
+	return template, err
+}
+
+// ListQueryTemplates lists the query templates a caller owns, or that are
+// public or shared with one of their roles, by name
+func (r *Repository) ListQueryTemplates(ctx context.Context, filter QueryTemplateFilter) ([]*QueryTemplate, error) {
+	query := `
+		SELECT id, name, description, query, parameters, tags, visibility, shared_roles,
+			   owner_id, version, created_at, updated_at, updated_by
+		FROM query_templates
+		WHERE ($1 OR owner_id = $2 OR visibility = 'public'
+			   OR (visibility = 'roles' AND shared_roles && $3))
+		  AND ($4 = '' OR $4 = ANY(tags))
+		ORDER BY name, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.All, filter.UserID, pq.Array(filter.Roles), filter.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list query templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*QueryTemplate
+	for rows.Next() {
+		template, err := scanQueryTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+// DeleteQueryTemplate deletes a query template. Its execution history is kept.
+func (r *Repository) DeleteQueryTemplate(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM query_templates WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete query template: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete query template: %w", err)
+	}
+	if deleted == 0 {
+		return ErrQueryTemplateNotFound
+	}
+
+	r.logger.Info("Query template deleted", "template_id", id)
+	return nil
+}
+
+// RecordQueryExecution stores a query template execution
+func (r *Repository) RecordQueryExecution(ctx context.Context, execution *QueryExecution) error {
+	parameters, err := json.Marshal(execution.Parameters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal execution parameters: %w", err)
+	}
+
+	query := `
+		INSERT INTO query_executions
+			(id, template_id, template_name, template_version, query, parameters, user_id,
+			 investigation_id, status, row_count, truncated, error, duration_ms, executed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11, NULLIF($12, ''), $13, $14)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		execution.ID, execution.TemplateID, execution.TemplateName, execution.TemplateVersion,
+		execution.Query, parameters, execution.UserID, execution.InvestigationID, execution.Status,
+		execution.RowCount, execution.Truncated, execution.Error, execution.DurationMs, execution.ExecutedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record query execution: %w", err)
+	}
+
+	return nil
+}
+
+// ListQueryExecutions lists query template executions, newest first
+func (r *Repository) ListQueryExecutions(ctx context.Context, filter QueryExecutionFilter) ([]*QueryExecution, error) {
+	query := `
+		SELECT id, template_id, template_name, template_version, query, parameters, user_id,
+			   COALESCE(investigation_id, ''), status, row_count, truncated, COALESCE(error, ''),
+			   duration_ms, executed_at
+		FROM query_executions
+		WHERE ($1 = '' OR template_id = $1)
+		  AND ($2 = '' OR user_id = $2)
+		  AND ($3 = '' OR investigation_id = $3)
+		ORDER BY executed_at DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.TemplateID, filter.UserID, filter.InvestigationID, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list query executions: %w", err)
+	}
+	defer rows.Close()
+
+	var executions []*QueryExecution
+	for rows.Next() {
+		var execution QueryExecution
+		var parameters []byte
+		if err := rows.Scan(&execution.ID, &execution.TemplateID, &execution.TemplateName,
+			&execution.TemplateVersion, &execution.Query, &parameters, &execution.UserID,
+			&execution.InvestigationID, &execution.Status, &execution.RowCount, &execution.Truncated,
+			&execution.Error, &execution.DurationMs, &execution.ExecutedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan query execution: %w", err)
+		}
+		if len(parameters) > 0 {
+			json.Unmarshal(parameters, &execution.Parameters)
+		}
+		executions = append(executions, &execution)
+	}
+
+	return executions, rows.Err()
+}
+
+func scanQueryTemplate(row rowScanner) (*QueryTemplate, error) {
+	var template QueryTemplate
+	var parameters []byte
+
+	if err := row.Scan(&template.ID, &template.Name, &template.Description, &template.Query,
+		&parameters, pq.Array(&template.Tags), &template.Visibility, pq.Array(&template.SharedRoles),
+		&template.OwnerID, &template.Version, &template.CreatedAt, &template.UpdatedAt,
+		&template.UpdatedBy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan query template: %w", err)
+	}
+
+	if len(parameters) > 0 {
+		if err := json.Unmarshal(parameters, &template.Parameters); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal template parameters: %w", err)
+		}
+	}
+
+	return &template, nil
+}
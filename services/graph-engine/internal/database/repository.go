@@ -10,7 +10,6 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 
 	"github.com/aegisshield/graph-engine/internal/config"
@@ -105,6 +104,7 @@ func NewConnection(cfg config.DatabaseConfig, logger *slog.Logger) (*Connection,
 	defer cancel()
 
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
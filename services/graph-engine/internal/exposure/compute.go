@@ -0,0 +1,202 @@
+package exposure
+
+import (
+	"math"
+	"sort"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+)
+
+// Implicit bands
+const (
+	// BandNone is the band of entities with no source within the maximum depth
+	BandNone = "none"
+	// BandLow is the band of exposed entities meeting no configured band
+	BandLow = "low"
+)
+
+// maxNearestSources bounds the nearest sources recorded per entity
+const maxNearestSources = 10
+
+// Edge is a relationship between two entities of a loaded subgraph. Value
+// is the transaction value the pair exchanged within the time window, in
+// either direction; it is 0 for pairs linked by other relationships only.
+type Edge struct {
+	From  string
+	To    string
+	Value float64
+}
+
+// Subgraph is the neighbourhood of a batch of sources: every entity within
+// the maximum depth of a source and the relationships between them
+type Subgraph struct {
+	Sources []string
+	Edges   []Edge
+}
+
+// Exposure is an entity's exposure to sanctioned or flagged entities
+type Exposure struct {
+	EntityID    string   `json:"entity_id"`
+	Distance    int      `json:"distance"`     // hops to the nearest source; 0 for a source
+	Value       float64  `json:"value"`        // transaction value that can have reached the entity from sources
+	DirectValue float64  `json:"direct_value"` // transacted directly with sources
+	Sources     []string `json:"sources"`      // the nearest sources
+	SourceCount int      `json:"source_count"` // sources within the maximum depth
+	Band        string   `json:"band"`
+}
+
+// Compute returns the exposure of every entity within maxDepth hops of a
+// source of the subgraph. Distance follows relationships of any kind. Value
+// sums, over each source, the largest amount that can have travelled from
+// the source to the entity along a chain of at most maxDepth transactions,
+// which is the smallest transaction on the chain.
+func Compute(sub Subgraph, maxDepth int) map[string]*Exposure {
+	adjacency := make(map[string]map[string]float64)
+	link := func(a, b string, value float64) {
+		if adjacency[a] == nil {
+			adjacency[a] = make(map[string]float64)
+		}
+		adjacency[a][b] += value
+	}
+	for _, edge := range sub.Edges {
+		if edge.From == edge.To {
+			continue
+		}
+		link(edge.From, edge.To, edge.Value)
+		link(edge.To, edge.From, edge.Value)
+	}
+
+	results := make(map[string]*Exposure)
+	seen := make(map[string]bool, len(sub.Sources))
+	for _, source := range sub.Sources {
+		if seen[source] {
+			continue
+		}
+		seen[source] = true
+
+		distances := distancesFrom(adjacency, source, maxDepth)
+		values := valuesFrom(adjacency, source, maxDepth)
+		for entityID, distance := range distances {
+			result := results[entityID]
+			if result == nil {
+				result = &Exposure{EntityID: entityID, Distance: distance}
+				results[entityID] = result
+			}
+			addSource(result, source, distance)
+			result.SourceCount++
+			result.Value += values[entityID]
+			if entityID != source {
+				result.DirectValue += adjacency[entityID][source]
+			}
+		}
+	}
+
+	for _, result := range results {
+		sort.Strings(result.Sources)
+	}
+	return results
+}
+
+// Merge adds the exposures computed for another batch of sources. Batches
+// hold disjoint sources, so their values and counts add up.
+func Merge(into, from map[string]*Exposure) {
+	for entityID, other := range from {
+		result := into[entityID]
+		if result == nil {
+			into[entityID] = other
+			continue
+		}
+		for _, source := range other.Sources {
+			addSource(result, source, other.Distance)
+		}
+		sort.Strings(result.Sources)
+		result.Value += other.Value
+		result.DirectValue += other.DirectValue
+		result.SourceCount += other.SourceCount
+	}
+}
+
+// AssignBands sets the band of every exposure
+func AssignBands(results map[string]*Exposure, bands []config.ExposureBand) {
+	for _, result := range results {
+		result.Band = BandFor(result.Distance, result.Value, bands)
+	}
+}
+
+// BandFor returns the first band whose thresholds an exposed entity meets,
+// or BandLow
+func BandFor(distance int, value float64, bands []config.ExposureBand) string {
+	for _, band := range bands {
+		if distance <= band.MaxDistance && value >= band.MinValue {
+			return band.Name
+		}
+	}
+	return BandLow
+}
+
+// addSource records a source at a distance, keeping only the nearest ones
+func addSource(result *Exposure, source string, distance int) {
+	switch {
+	case distance < result.Distance:
+		result.Distance = distance
+		result.Sources = []string{source}
+	case distance == result.Distance && len(result.Sources) < maxNearestSources:
+		for _, existing := range result.Sources {
+			if existing == source {
+				return
+			}
+		}
+		result.Sources = append(result.Sources, source)
+	}
+}
+
+// distancesFrom returns the hops from the source to every entity within
+// maxDepth of it
+func distancesFrom(adjacency map[string]map[string]float64, source string, maxDepth int) map[string]int {
+	distances := map[string]int{source: 0}
+	frontier := []string{source}
+	for depth := 1; depth <= maxDepth && len(frontier) > 0; depth++ {
+		var next []string
+		for _, node := range frontier {
+			for neighbour := range adjacency[node] {
+				if _, ok := distances[neighbour]; !ok {
+					distances[neighbour] = depth
+					next = append(next, neighbour)
+				}
+			}
+		}
+		frontier = next
+	}
+	return distances
+}
+
+// valuesFrom returns, for every entity reachable from the source over at
+// most maxDepth transaction edges, the largest bottleneck value of such a
+// chain
+func valuesFrom(adjacency map[string]map[string]float64, source string, maxDepth int) map[string]float64 {
+	best := make(map[string]float64)
+	current := map[string]float64{source: math.Inf(1)}
+	for hop := 1; hop <= maxDepth && len(current) > 0; hop++ {
+		next := make(map[string]float64)
+		for node, width := range current {
+			for neighbour, value := range adjacency[node] {
+				if value <= 0 || neighbour == source {
+					continue
+				}
+				if w := math.Min(width, value); w > next[neighbour] {
+					next[neighbour] = w
+				}
+			}
+		}
+
+		// Only widths that improve on a shorter chain can lead anywhere new
+		current = make(map[string]float64)
+		for node, width := range next {
+			if width > best[node] {
+				best[node] = width
+				current[node] = width
+			}
+		}
+	}
+	return best
+}
@@ -0,0 +1,982 @@
+package exposure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/tenancy"
+)
+
+// EventExposureChanged is the type of the event published when an entity
+// moves to another exposure band
+const EventExposureChanged = "entity_exposure_changed"
+
+// Recompute modes
+const (
+	ModeIncremental = "incremental"
+	ModeFull        = "full"
+)
+
+var (
+	// ErrEntityNotFound is returned for entity IDs not in the graph
+	ErrEntityNotFound = errors.New("entity not found")
+	// ErrUnknownSourceProperty is returned for properties that do not mark
+	// exposure sources
+	ErrUnknownSourceProperty = errors.New("unknown exposure source property")
+	// ErrUnknownBand is returned for band filters naming no band
+	ErrUnknownBand = errors.New("unknown exposure band")
+)
+
+var (
+	exposedEntities = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "graph_engine_exposed_entities",
+		Help: "Entities in each exposure band after the last full recompute",
+	}, []string{"band"})
+	bandChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "graph_engine_exposure_band_changes_total",
+		Help: "Entities that moved into an exposure band",
+	}, []string{"band"})
+	recomputeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "graph_engine_exposure_recompute_duration_seconds",
+		Help:    "Time taken to recompute exposure",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"mode"})
+	truncatedSubgraphs = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "graph_engine_exposure_truncated_subgraphs_total",
+		Help: "Source neighbourhoods cut off at the maximum subgraph size",
+	})
+)
+
+// QueryRunner runs Cypher against the graph
+type QueryRunner interface {
+	ExecuteQuery(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error)
+}
+
+// Publisher publishes exposure band changes for rule evaluation
+type Publisher interface {
+	PublishExposureChanged(ctx context.Context, event *ChangeEvent) error
+}
+
+// ChangeEvent reports an entity moving to another exposure band. It has the
+// shape of the events the alerting engine evaluates rules against, so rules
+// can match on band, distance and value.
+type ChangeEvent struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	Source    string                 `json:"source"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// Filter selects exposed entities
+type Filter struct {
+	Band        string
+	MinValue    float64
+	MaxDistance int // negative for any distance
+	Limit       int
+}
+
+// Summary counts exposed entities for dashboards
+type Summary struct {
+	Sources       int                   `json:"sources"`
+	Exposed       int                   `json:"exposed"`
+	ByBand        map[string]int        `json:"by_band"`
+	ByDistance    map[int]int           `json:"by_distance"`
+	TotalValue    float64               `json:"total_value"`
+	Bands         []config.ExposureBand `json:"bands"`
+	MaxDepth      int                   `json:"max_depth"`
+	LastFullRunAt *time.Time            `json:"last_full_run_at,omitempty"`
+}
+
+// Result reports one recompute
+type Result struct {
+	Mode        string        `json:"mode"`
+	Changed     int           `json:"changed"` // entities whose change triggered the recompute
+	Sources     int           `json:"sources"`
+	Updated     int           `json:"updated"`
+	Cleared     int           `json:"cleared"`
+	BandChanges int           `json:"band_changes"`
+	Truncated   bool          `json:"truncated"` // a source neighbourhood exceeded the maximum subgraph size
+	Duration    time.Duration `json:"duration"`
+}
+
+// Service keeps each entity's exposure to sanctioned and flagged entities
+// on the entity, recomputing it around entities and relationships as they
+// change and for the whole graph on a schedule
+type Service struct {
+	graph     QueryRunner
+	publisher Publisher
+	cfg       config.ExposureConfig
+	tenants   tenancy.Inspector
+	logger    *slog.Logger
+	now       func() time.Time
+
+	run sync.Mutex // serializes recomputes
+
+	mu        sync.Mutex
+	dirty     map[string]map[string]bool // tenant to changed entity IDs
+	watermark map[string]time.Time       // tenant to when changes were last polled
+	lastFull  time.Time
+}
+
+// NewService creates an exposure service. publisher may be nil to skip
+// band change events.
+func NewService(graph QueryRunner, publisher Publisher, cfg config.ExposureConfig, logger *slog.Logger) *Service {
+	return &Service{
+		graph:     graph,
+		publisher: publisher,
+		cfg:       cfg,
+		logger:    logger,
+		now:       time.Now,
+		dirty:     make(map[string]map[string]bool),
+		watermark: make(map[string]time.Time),
+	}
+}
+
+// SetTenants makes scheduled recomputes also cover every online tenant
+// database
+func (s *Service) SetTenants(inspector tenancy.Inspector) {
+	s.tenants = inspector
+}
+
+// MarkChanged queues entities of the context's database whose exposure may
+// have changed; their neighbourhood is recomputed on the next cycle
+func (s *Service) MarkChanged(ctx context.Context, entityIDs ...string) {
+	tenantID, _ := tenancy.FromContext(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dirty[tenantID] == nil {
+		s.dirty[tenantID] = make(map[string]bool)
+	}
+	for _, id := range entityIDs {
+		if id != "" {
+			s.dirty[tenantID][id] = true
+		}
+	}
+}
+
+// Start recomputes the whole graph, then every recompute interval picks up
+// changed entities and relationships and recomputes around them, and every
+// full interval recomputes the whole graph again
+func (s *Service) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.RecomputeInterval)
+	defer ticker.Stop()
+
+	for {
+		if s.fullDue() {
+			if _, err := s.Recompute(ctx, nil); err != nil {
+				s.logger.Error("Full exposure recompute failed", "error", err)
+			}
+		} else {
+			s.recomputeChanged(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) fullDue() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastFull.IsZero() || s.now().Sub(s.lastFull) >= s.cfg.FullInterval
+}
+
+// recomputeChanged recomputes around the changes of every database
+func (s *Service) recomputeChanged(ctx context.Context) {
+	databases, err := s.databases(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list tenant databases for exposure", "error", err)
+		return
+	}
+
+	for _, dbCtx := range databases {
+		tenantID, _ := tenancy.FromContext(dbCtx)
+		if err := s.pollChanges(dbCtx); err != nil {
+			if !errors.Is(err, tenancy.ErrTenantRequired) {
+				s.logger.Error("Failed to poll graph changes for exposure", "tenant_id", tenantID, "error", err)
+			}
+			continue
+		}
+
+		ids := s.takeDirty(tenantID)
+		if len(ids) == 0 {
+			continue
+		}
+		result, err := s.Recompute(dbCtx, ids)
+		if err != nil {
+			// Retry the changes on the next cycle
+			s.MarkChanged(dbCtx, ids...)
+			s.logger.Error("Incremental exposure recompute failed", "tenant_id", tenantID, "error", err)
+			continue
+		}
+		s.logger.Debug("Recomputed exposure",
+			"tenant_id", tenantID,
+			"changed", result.Changed,
+			"updated", result.Updated,
+			"cleared", result.Cleared,
+			"band_changes", result.BandChanges)
+	}
+}
+
+func (s *Service) takeDirty(tenantID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.dirty[tenantID]))
+	for id := range s.dirty[tenantID] {
+		ids = append(ids, id)
+	}
+	delete(s.dirty, tenantID)
+	sort.Strings(ids)
+	return ids
+}
+
+// changedEntitiesQuery finds entities created or updated since the last poll
+const changedEntitiesQuery = `
+	MATCH (n:Entity)
+	WHERE coalesce(n.updated_at, n.created_at) IS NOT NULL
+	  AND datetime(toString(coalesce(n.updated_at, n.created_at))) > datetime($since)
+	RETURN n.id AS id`
+
+// changedRelationshipsQuery finds the ends of relationships created or
+// updated since the last poll
+const changedRelationshipsQuery = `
+	MATCH (a:Entity)-[r]->(b:Entity)
+	WHERE coalesce(r.updated_at, r.created_at) IS NOT NULL
+	  AND datetime(toString(coalesce(r.updated_at, r.created_at))) > datetime($since)
+	RETURN a.id AS from, b.id AS to`
+
+// pollChanges queues the entities of the context's database that changed,
+// or whose relationships changed, since the last poll. The first poll of a
+// database only sets its watermark; the full recompute covers what came
+// before.
+func (s *Service) pollChanges(ctx context.Context) error {
+	tenantID, _ := tenancy.FromContext(ctx)
+	polledAt := s.now().UTC()
+
+	s.mu.Lock()
+	since, ok := s.watermark[tenantID]
+	s.mu.Unlock()
+	if !ok {
+		s.setWatermark(tenantID, polledAt)
+		return nil
+	}
+
+	params := map[string]interface{}{"since": since.Format(time.RFC3339Nano)}
+	rows, err := s.graph.ExecuteQuery(ctx, changedEntitiesQuery, params)
+	if err != nil {
+		return fmt.Errorf("failed to query changed entities: %w", err)
+	}
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, toString(row["id"]))
+	}
+
+	rows, err = s.graph.ExecuteQuery(ctx, changedRelationshipsQuery, params)
+	if err != nil {
+		return fmt.Errorf("failed to query changed relationships: %w", err)
+	}
+	for _, row := range rows {
+		ids = append(ids, toString(row["from"]), toString(row["to"]))
+	}
+
+	s.MarkChanged(ctx, ids...)
+	s.setWatermark(tenantID, polledAt)
+	return nil
+}
+
+// startWatermark starts polling a database's changes at the given time
+// unless it is already polled
+func (s *Service) startWatermark(tenantID string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.watermark[tenantID]; !ok {
+		s.watermark[tenantID] = at
+	}
+}
+
+func (s *Service) setWatermark(tenantID string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watermark[tenantID] = at
+}
+
+// Recompute recomputes exposure around the given entities of the context's
+// database, or for the whole graph when none are given. A full recompute of
+// a context naming no tenant covers the shared database and every online
+// tenant database.
+func (s *Service) Recompute(ctx context.Context, entityIDs []string) (*Result, error) {
+	s.run.Lock()
+	defer s.run.Unlock()
+
+	start := s.now()
+	mode := ModeIncremental
+	if len(entityIDs) == 0 {
+		mode = ModeFull
+	}
+	result := &Result{Mode: mode, Changed: len(entityIDs)}
+
+	if mode == ModeIncremental {
+		for begin := 0; begin < len(entityIDs); begin += s.cfg.BatchSize {
+			end := begin + s.cfg.BatchSize
+			if end > len(entityIDs) {
+				end = len(entityIDs)
+			}
+			if err := s.recomputeAround(ctx, entityIDs[begin:end], result); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		databases, err := s.databases(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		counts := make(map[string]int)
+		for _, dbCtx := range databases {
+			tenantID, _ := tenancy.FromContext(dbCtx)
+			s.startWatermark(tenantID, start.UTC())
+			err := s.recomputeAll(dbCtx, result, counts)
+			if errors.Is(err, tenancy.ErrTenantRequired) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("tenant %q: %w", tenantID, err)
+			}
+		}
+
+		exposedEntities.Reset()
+		for band, count := range counts {
+			exposedEntities.WithLabelValues(band).Set(float64(count))
+		}
+
+		s.mu.Lock()
+		s.lastFull = s.now()
+		s.mu.Unlock()
+	}
+
+	result.Duration = s.now().Sub(start)
+	recomputeDuration.WithLabelValues(mode).Observe(result.Duration.Seconds())
+	if mode == ModeFull {
+		s.logger.Info("Recomputed exposure",
+			"mode", mode,
+			"sources", result.Sources,
+			"updated", result.Updated,
+			"cleared", result.Cleared,
+			"band_changes", result.BandChanges,
+			"truncated", result.Truncated,
+			"duration", result.Duration)
+	}
+	return result, nil
+}
+
+// recomputeAround recomputes the entities within the maximum depth of the
+// changed entities. Only sources within the maximum depth of those entities
+// can expose them.
+func (s *Service) recomputeAround(ctx context.Context, changed []string, result *Result) error {
+	affected, err := s.neighbourhood(ctx, changed)
+	if err != nil {
+		return fmt.Errorf("failed to load changed neighbourhood: %w", err)
+	}
+	if len(affected) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`
+		MATCH (n:Entity)
+		WHERE n.id IN $ids
+		MATCH (n)-[%s*0..%d]-(source:Entity)
+		WHERE %s
+		RETURN DISTINCT source.id AS id`, s.relTypes(), s.cfg.MaxDepth, s.sourceCondition("source"))
+	rows, err := s.graph.ExecuteQuery(ctx, query, map[string]interface{}{"ids": affected})
+	if err != nil {
+		return fmt.Errorf("failed to query exposure sources: %w", err)
+	}
+	sources := make([]string, 0, len(rows))
+	for _, row := range rows {
+		sources = append(sources, toString(row["id"]))
+	}
+
+	computed, err := s.compute(ctx, sources, result)
+	if err != nil {
+		return err
+	}
+
+	scope := make(map[string]bool, len(affected))
+	for _, id := range affected {
+		scope[id] = true
+	}
+	for id := range computed {
+		if !scope[id] {
+			delete(computed, id)
+		}
+	}
+
+	return s.write(ctx, affected, computed, result)
+}
+
+// recomputeAll recomputes every entity of the context's database and adds
+// the entities in each band to counts
+func (s *Service) recomputeAll(ctx context.Context, result *Result, counts map[string]int) error {
+	query := fmt.Sprintf(`
+		MATCH (source:Entity)
+		WHERE %s
+		RETURN source.id AS id
+		ORDER BY id`, s.sourceCondition("source"))
+	rows, err := s.graph.ExecuteQuery(ctx, query, nil)
+	if err != nil {
+		return fmt.Errorf("failed to query exposure sources: %w", err)
+	}
+	sources := make([]string, 0, len(rows))
+	for _, row := range rows {
+		sources = append(sources, toString(row["id"]))
+	}
+
+	computed, err := s.compute(ctx, sources, result)
+	if err != nil {
+		return err
+	}
+
+	// Entities exposed before but not now are cleared
+	rows, err = s.graph.ExecuteQuery(ctx, `
+		MATCH (n:Entity)
+		WHERE n.exposure_band IS NOT NULL
+		RETURN n.id AS id`, nil)
+	if err != nil {
+		return fmt.Errorf("failed to query exposed entities: %w", err)
+	}
+	scope := make(map[string]bool, len(computed)+len(rows))
+	for id := range computed {
+		scope[id] = true
+	}
+	for _, row := range rows {
+		scope[toString(row["id"])] = true
+	}
+	ids := make([]string, 0, len(scope))
+	for id := range scope {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for begin := 0; begin < len(ids); begin += s.cfg.BatchSize {
+		end := begin + s.cfg.BatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := make(map[string]*Exposure)
+		for _, id := range ids[begin:end] {
+			if exposure, ok := computed[id]; ok {
+				batch[id] = exposure
+			}
+		}
+		if err := s.write(ctx, ids[begin:end], batch, result); err != nil {
+			return err
+		}
+	}
+
+	for _, exposure := range computed {
+		counts[exposure.Band]++
+	}
+	return nil
+}
+
+// compute loads the neighbourhood of the sources in batches and returns the
+// exposure of every entity within the maximum depth of one
+func (s *Service) compute(ctx context.Context, sources []string, result *Result) (map[string]*Exposure, error) {
+	result.Sources += len(sources)
+	computed := make(map[string]*Exposure)
+	for begin := 0; begin < len(sources); begin += s.cfg.SourceBatchSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		end := begin + s.cfg.SourceBatchSize
+		if end > len(sources) {
+			end = len(sources)
+		}
+		sub, truncated, err := s.loadSubgraph(ctx, sources[begin:end])
+		if err != nil {
+			return nil, err
+		}
+		if truncated {
+			result.Truncated = true
+			truncatedSubgraphs.Inc()
+			s.logger.Warn("Exposure source neighbourhood truncated",
+				"sources", end-begin,
+				"max_subgraph_nodes", s.cfg.MaxSubgraphNodes)
+		}
+		Merge(computed, Compute(sub, s.cfg.MaxDepth))
+	}
+
+	AssignBands(computed, s.cfg.Bands)
+	return computed, nil
+}
+
+// loadSubgraph loads the entities within the maximum depth of the sources
+// and the relationships between them, with the transaction value each pair
+// exchanged within the time window
+func (s *Service) loadSubgraph(ctx context.Context, sources []string) (Subgraph, bool, error) {
+	sub := Subgraph{Sources: sources}
+
+	transactionFilter := ""
+	params := map[string]interface{}{
+		"sources":  sources,
+		"maxNodes": s.cfg.MaxSubgraphNodes,
+		"txType":   s.cfg.TransactionType,
+	}
+	if s.cfg.TimeWindow > 0 {
+		transactionFilter = "AND r.timestamp >= datetime() - duration($timeWindow)"
+		params["timeWindow"] = cypherDuration(s.cfg.TimeWindow)
+	}
+
+	query := fmt.Sprintf(`
+		MATCH (source:Entity)
+		WHERE source.id IN $sources
+		MATCH (source)-[%[1]s*0..%[2]d]-(n:Entity)
+		WITH DISTINCT n LIMIT $maxNodes
+		WITH collect(n) AS nodes
+		UNWIND nodes AS a
+		MATCH (a)-[r%[1]s]-(b:Entity)
+		WHERE b IN nodes AND a.id < b.id
+		WITH a, b, size(nodes) AS loaded,
+			 sum(CASE WHEN type(r) = $txType %[3]s
+			          THEN coalesce(toFloat(r.%[4]s), 0.0) ELSE 0.0 END) AS value
+		RETURN a.id AS from, b.id AS to, value, loaded`,
+		s.relTypes(), s.cfg.MaxDepth, transactionFilter, s.cfg.AmountProperty)
+
+	rows, err := s.graph.ExecuteQuery(ctx, query, params)
+	if err != nil {
+		return sub, false, fmt.Errorf("failed to load exposure subgraph: %w", err)
+	}
+
+	truncated := false
+	for _, row := range rows {
+		sub.Edges = append(sub.Edges, Edge{
+			From:  toString(row["from"]),
+			To:    toString(row["to"]),
+			Value: toFloat(row["value"]),
+		})
+		if toInt(row["loaded"]) >= s.cfg.MaxSubgraphNodes {
+			truncated = true
+		}
+	}
+	return sub, truncated, nil
+}
+
+// neighbourhood returns the entities within the maximum depth of the given
+// entities, including those still in the graph among them
+func (s *Service) neighbourhood(ctx context.Context, ids []string) ([]string, error) {
+	query := fmt.Sprintf(`
+		MATCH (c:Entity)
+		WHERE c.id IN $ids
+		MATCH (c)-[%s*0..%d]-(n:Entity)
+		RETURN DISTINCT n.id AS id`, s.relTypes(), s.cfg.MaxDepth)
+	rows, err := s.graph.ExecuteQuery(ctx, query, map[string]interface{}{"ids": ids})
+	if err != nil {
+		return nil, err
+	}
+
+	affected := make([]string, 0, len(rows))
+	for _, row := range rows {
+		affected = append(affected, toString(row["id"]))
+	}
+	sort.Strings(affected)
+	return affected, nil
+}
+
+// write stores the computed exposure of the scoped entities, clears it from
+// scoped entities no longer exposed and publishes band changes
+func (s *Service) write(ctx context.Context, scope []string, computed map[string]*Exposure, result *Result) error {
+	rows, err := s.graph.ExecuteQuery(ctx, `
+		MATCH (n:Entity)
+		WHERE n.id IN $ids
+		RETURN n.id AS id, n.exposure_band AS band`, map[string]interface{}{"ids": scope})
+	if err != nil {
+		return fmt.Errorf("failed to query previous exposure bands: %w", err)
+	}
+	previous := make(map[string]string, len(rows))
+	for _, row := range rows {
+		if band := toString(row["band"]); band != "" {
+			previous[toString(row["id"])] = band
+		}
+	}
+
+	updatedAt := s.now().UTC().Format(time.RFC3339Nano)
+	updates := make([]map[string]interface{}, 0, len(computed))
+	var cleared []string
+	for _, id := range scope {
+		exposure, ok := computed[id]
+		if !ok {
+			if previous[id] != "" {
+				cleared = append(cleared, id)
+			}
+			continue
+		}
+		updates = append(updates, map[string]interface{}{
+			"id":           id,
+			"distance":     exposure.Distance,
+			"value":        exposure.Value,
+			"direct_value": exposure.DirectValue,
+			"sources":      exposure.Sources,
+			"source_count": exposure.SourceCount,
+			"band":         exposure.Band,
+		})
+	}
+
+	if len(updates) > 0 {
+		if _, err := s.graph.ExecuteQuery(ctx, `
+			UNWIND $rows AS row
+			MATCH (n:Entity {id: row.id})
+			SET n.exposure_distance = row.distance,
+				n.exposure_value = row.value,
+				n.exposure_direct_value = row.direct_value,
+				n.exposure_sources = row.sources,
+				n.exposure_source_count = row.source_count,
+				n.exposure_band = row.band,
+				n.exposure_updated_at = $updatedAt`,
+			map[string]interface{}{"rows": updates, "updatedAt": updatedAt}); err != nil {
+			return fmt.Errorf("failed to write exposure: %w", err)
+		}
+		result.Updated += len(updates)
+	}
+
+	if len(cleared) > 0 {
+		if _, err := s.graph.ExecuteQuery(ctx, `
+			MATCH (n:Entity)
+			WHERE n.id IN $ids
+			REMOVE n.exposure_distance, n.exposure_value, n.exposure_direct_value,
+				n.exposure_sources, n.exposure_source_count, n.exposure_band,
+				n.exposure_updated_at`,
+			map[string]interface{}{"ids": cleared}); err != nil {
+			return fmt.Errorf("failed to clear exposure: %w", err)
+		}
+		result.Cleared += len(cleared)
+	}
+
+	for _, id := range scope {
+		before := previous[id]
+		if before == "" {
+			before = BandNone
+		}
+		exposure, ok := computed[id]
+		if !ok {
+			exposure = &Exposure{EntityID: id, Band: BandNone}
+		}
+		if exposure.Band == before {
+			continue
+		}
+		result.BandChanges++
+		bandChanges.WithLabelValues(exposure.Band).Inc()
+		s.publish(ctx, exposure, before)
+	}
+	return nil
+}
+
+func (s *Service) publish(ctx context.Context, exposure *Exposure, previousBand string) {
+	if s.publisher == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"entity_id":     exposure.EntityID,
+		"band":          exposure.Band,
+		"previous_band": previousBand,
+		"exposed":       exposure.Band != BandNone,
+		"distance":      exposure.Distance,
+		"value":         exposure.Value,
+		"direct_value":  exposure.DirectValue,
+		"sources":       exposure.Sources,
+		"source_count":  exposure.SourceCount,
+	}
+	if exposure.Band == BandNone {
+		data["distance"] = nil
+	}
+	if tenantID, ok := tenancy.FromContext(ctx); ok {
+		data["tenant_id"] = tenantID
+	}
+
+	event := &ChangeEvent{
+		ID:        uuid.New().String(),
+		Type:      EventExposureChanged,
+		Source:    "graph-engine",
+		Timestamp: s.now().UTC(),
+		Data:      data,
+	}
+	if err := s.publisher.PublishExposureChanged(ctx, event); err != nil {
+		s.logger.Error("Failed to publish exposure change", "entity_id", exposure.EntityID, "error", err)
+	}
+}
+
+// Get returns an entity's stored exposure; entities with none are in
+// BandNone
+func (s *Service) Get(ctx context.Context, entityID string) (*Exposure, error) {
+	rows, err := s.graph.ExecuteQuery(ctx, `
+		MATCH (n:Entity {id: $id})
+		RETURN `+exposureColumns, map[string]interface{}{"id": entityID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exposure: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrEntityNotFound, entityID)
+	}
+	return exposureFromRow(rows[0]), nil
+}
+
+// List returns the exposed entities matching the filter, nearest and most
+// exposed first
+func (s *Service) List(ctx context.Context, filter Filter) ([]*Exposure, error) {
+	if filter.Band != "" && !s.knownBand(filter.Band) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBand, filter.Band)
+	}
+	if filter.Limit <= 0 || filter.Limit > s.cfg.MaxListLimit {
+		filter.Limit = s.cfg.MaxListLimit
+	}
+
+	conditions := []string{"n.exposure_band IS NOT NULL", "n.exposure_value >= $minValue"}
+	params := map[string]interface{}{"minValue": filter.MinValue, "limit": filter.Limit}
+	if filter.Band != "" {
+		conditions = append(conditions, "n.exposure_band = $band")
+		params["band"] = filter.Band
+	}
+	if filter.MaxDistance >= 0 {
+		conditions = append(conditions, "n.exposure_distance <= $maxDistance")
+		params["maxDistance"] = filter.MaxDistance
+	}
+
+	rows, err := s.graph.ExecuteQuery(ctx, fmt.Sprintf(`
+		MATCH (n:Entity)
+		WHERE %s
+		RETURN %s
+		ORDER BY distance ASC, value DESC, entity_id
+		LIMIT $limit`, strings.Join(conditions, " AND "), exposureColumns), params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exposed entities: %w", err)
+	}
+
+	exposures := make([]*Exposure, 0, len(rows))
+	for _, row := range rows {
+		exposures = append(exposures, exposureFromRow(row))
+	}
+	return exposures, nil
+}
+
+// Summary counts the sources and exposed entities of the context's database
+func (s *Service) Summary(ctx context.Context) (*Summary, error) {
+	summary := &Summary{
+		ByBand:     make(map[string]int),
+		ByDistance: make(map[int]int),
+		Bands:      s.cfg.Bands,
+		MaxDepth:   s.cfg.MaxDepth,
+	}
+
+	rows, err := s.graph.ExecuteQuery(ctx, fmt.Sprintf(`
+		MATCH (n:Entity)
+		WHERE %s
+		RETURN count(n) AS count`, s.sourceCondition("n")), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count exposure sources: %w", err)
+	}
+	if len(rows) > 0 {
+		summary.Sources = toInt(rows[0]["count"])
+	}
+
+	rows, err = s.graph.ExecuteQuery(ctx, `
+		MATCH (n:Entity)
+		WHERE n.exposure_band IS NOT NULL
+		RETURN n.exposure_band AS band, n.exposure_distance AS distance,
+			   count(n) AS count, sum(n.exposure_value) AS value`, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize exposure: %w", err)
+	}
+	for _, row := range rows {
+		count := toInt(row["count"])
+		summary.Exposed += count
+		summary.ByBand[toString(row["band"])] += count
+		summary.ByDistance[toInt(row["distance"])] += count
+		summary.TotalValue += toFloat(row["value"])
+	}
+
+	s.mu.Lock()
+	if !s.lastFull.IsZero() {
+		lastFull := s.lastFull.UTC()
+		summary.LastFullRunAt = &lastFull
+	}
+	s.mu.Unlock()
+
+	return summary, nil
+}
+
+// SetSource marks or unmarks an entity as a source through one of the
+// source properties and queues its neighbourhood for recompute
+func (s *Service) SetSource(ctx context.Context, entityID, property string, source bool) error {
+	known := false
+	for _, candidate := range s.cfg.SourceProperties {
+		if candidate == property {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("%w: %s", ErrUnknownSourceProperty, property)
+	}
+
+	rows, err := s.graph.ExecuteQuery(ctx, fmt.Sprintf(`
+		MATCH (n:Entity {id: $id})
+		SET n.%s = $source
+		RETURN n.id AS id`, property), map[string]interface{}{"id": entityID, "source": source})
+	if err != nil {
+		return fmt.Errorf("failed to set exposure source: %w", err)
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("%w: %s", ErrEntityNotFound, entityID)
+	}
+
+	s.MarkChanged(ctx, entityID)
+	return nil
+}
+
+func (s *Service) knownBand(name string) bool {
+	if name == BandLow {
+		return true
+	}
+	for _, band := range s.cfg.Bands {
+		if band.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// databases returns a context for every database a recompute covers
+func (s *Service) databases(ctx context.Context) ([]context.Context, error) {
+	if _, ok := tenancy.FromContext(ctx); ok || s.tenants == nil {
+		return []context.Context{ctx}, nil
+	}
+
+	databases, err := s.tenants.TenantDatabases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contexts := []context.Context{ctx}
+	for _, db := range databases {
+		if db.Online {
+			contexts = append(contexts, tenancy.WithTenant(ctx, db.TenantID))
+		}
+	}
+	return contexts, nil
+}
+
+// sourceCondition matches entities marked by any source property
+func (s *Service) sourceCondition(variable string) string {
+	conditions := make([]string, len(s.cfg.SourceProperties))
+	for i, property := range s.cfg.SourceProperties {
+		conditions[i] = fmt.Sprintf("coalesce(%s.%s, false) = true", variable, property)
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// relTypes returns the relationship type filter of a pattern
+func (s *Service) relTypes() string {
+	if len(s.cfg.RelationshipTypes) == 0 {
+		return ""
+	}
+	return ":" + strings.Join(s.cfg.RelationshipTypes, "|")
+}
+
+// exposureColumns returns the stored exposure of entity n
+const exposureColumns = `n.id AS entity_id,
+		   n.exposure_distance AS distance,
+		   n.exposure_value AS value,
+		   n.exposure_direct_value AS direct_value,
+		   n.exposure_sources AS sources,
+		   n.exposure_source_count AS source_count,
+		   n.exposure_band AS band`
+
+func exposureFromRow(row map[string]interface{}) *Exposure {
+	exposure := &Exposure{
+		EntityID:    toString(row["entity_id"]),
+		Distance:    toInt(row["distance"]),
+		Value:       toFloat(row["value"]),
+		DirectValue: toFloat(row["direct_value"]),
+		Sources:     toStringSlice(row["sources"]),
+		SourceCount: toInt(row["source_count"]),
+		Band:        toString(row["band"]),
+	}
+	if exposure.Band == "" {
+		exposure.Band = BandNone
+	}
+	if exposure.Sources == nil {
+		exposure.Sources = []string{}
+	}
+	return exposure
+}
+
+// cypherDuration formats a duration as an ISO-8601 duration for Cypher
+func cypherDuration(d time.Duration) string {
+	return fmt.Sprintf("PT%dS", int64(d.Seconds()))
+}
+
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int64:
+		return int(v)
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+func toString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func toStringSlice(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, toString(item))
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,418 @@
+package geo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/neo4j"
+	"github.com/google/uuid"
+)
+
+// FlowAnalyzer tags entities with jurisdictions and analyzes cross-border fund flows
+type FlowAnalyzer struct {
+	neo4jClient *neo4j.Client
+	config      config.GeoConfig
+	policy      *RiskPolicy
+	logger      *slog.Logger
+}
+
+// JurisdictionTag assigns a jurisdiction to an entity or account
+type JurisdictionTag struct {
+	EntityID     string `json:"entity_id"`
+	Jurisdiction string `json:"jurisdiction"`
+	Source       string `json:"source,omitempty"`
+}
+
+// CrossBorderFlowRequest represents a cross-border flow analysis request
+type CrossBorderFlowRequest struct {
+	EntityIDs  []string      `json:"entity_ids,omitempty"`
+	Depth      int           `json:"depth,omitempty"`
+	TimeWindow time.Duration `json:"time_window,omitempty"`
+	MinAmount  float64       `json:"min_amount,omitempty"`
+	MaxHops    int           `json:"max_hops,omitempty"`
+}
+
+// CorridorFlow represents the aggregated flow of funds between two jurisdictions
+type CorridorFlow struct {
+	Corridor         string  `json:"corridor"`
+	Origin           string  `json:"origin"`
+	Destination      string  `json:"destination"`
+	TotalAmount      float64 `json:"total_amount"`
+	TransactionCount int     `json:"transaction_count"`
+	EntityCount      int     `json:"entity_count"`
+	AverageAmount    float64 `json:"average_amount"`
+	MaxAmount        float64 `json:"max_amount"`
+	Share            float64 `json:"share"`
+	HighRisk         bool    `json:"high_risk"`
+}
+
+// FlowSummary contains corridor totals for a network
+type FlowSummary struct {
+	Corridors             []*CorridorFlow    `json:"corridors"`
+	CrossBorderAmount     float64            `json:"cross_border_amount"`
+	CrossBorderCount      int                `json:"cross_border_count"`
+	DomesticAmount        float64            `json:"domestic_amount"`
+	DomesticCount         int                `json:"domestic_count"`
+	CrossBorderShare      float64            `json:"cross_border_share"`
+	HighRiskAmount        float64            `json:"high_risk_amount"`
+	HighRiskCorridorCount int                `json:"high_risk_corridor_count"`
+	JurisdictionOutflows  map[string]float64 `json:"jurisdiction_outflows"`
+	JurisdictionInflows   map[string]float64 `json:"jurisdiction_inflows"`
+}
+
+// FlaggedRoute represents a multi-hop route through high-risk jurisdictions or corridors
+type FlaggedRoute struct {
+	EntityIDs     []string  `json:"entity_ids"`
+	Jurisdictions []string  `json:"jurisdictions"`
+	Amounts       []float64 `json:"amounts"`
+	FlowAmount    float64   `json:"flow_amount"`
+	HopCount      int       `json:"hop_count"`
+	Reasons       []string  `json:"reasons"`
+}
+
+// CrossBorderFlowResult contains the result of a cross-border flow analysis
+type CrossBorderFlowResult struct {
+	ID                    string          `json:"id"`
+	AnalyzedAt            time.Time       `json:"analyzed_at"`
+	EntityIDs             []string        `json:"entity_ids,omitempty"`
+	TimeWindow            time.Duration   `json:"time_window"`
+	Summary               *FlowSummary    `json:"summary"`
+	FlaggedRoutes         []*FlaggedRoute `json:"flagged_routes"`
+	HighRiskJurisdictions []string        `json:"high_risk_jurisdictions"`
+	HighRiskCorridors     []string        `json:"high_risk_corridors"`
+}
+
+// NewFlowAnalyzer creates a new cross-border flow analyzer
+func NewFlowAnalyzer(client *neo4j.Client, config config.GeoConfig, logger *slog.Logger) *FlowAnalyzer {
+	return &FlowAnalyzer{
+		neo4jClient: client,
+		config:      config,
+		policy:      NewRiskPolicy(config),
+		logger:      logger,
+	}
+}
+
+// TagJurisdictions sets the jurisdiction of the given entities
+func (fa *FlowAnalyzer) TagJurisdictions(ctx context.Context, tags []JurisdictionTag) (int, error) {
+	rows := make([]map[string]interface{}, 0, len(tags))
+	for _, tag := range tags {
+		if tag.EntityID == "" {
+			continue
+		}
+
+		source := tag.Source
+		if source == "" {
+			source = "manual"
+		}
+
+		rows = append(rows, map[string]interface{}{
+			"entityId":     tag.EntityID,
+			"jurisdiction": NormalizeJurisdiction(tag.Jurisdiction),
+			"source":       source,
+		})
+	}
+
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	query := `
+		UNWIND $tags AS tag
+		MATCH (n:Entity {id: tag.entityId})
+		SET n.jurisdiction = tag.jurisdiction,
+			n.jurisdiction_source = tag.source,
+			n.jurisdiction_tagged_at = datetime()
+		RETURN count(n) AS tagged
+	`
+
+	records, err := fa.neo4jClient.ExecuteQuery(ctx, query, map[string]interface{}{"tags": rows})
+	if err != nil {
+		return 0, fmt.Errorf("failed to tag jurisdictions: %w", err)
+	}
+
+	tagged := 0
+	if len(records) > 0 {
+		tagged = int(getFloat64(records[0], "tagged"))
+	}
+
+	fa.logger.Info("Tagged entity jurisdictions", "requested", len(rows), "tagged", tagged)
+
+	return tagged, nil
+}
+
+// InferJurisdictions tags untagged entities from their country-like properties
+func (fa *FlowAnalyzer) InferJurisdictions(ctx context.Context) (int, error) {
+	query := `
+		MATCH (n:Entity)
+		WHERE n.jurisdiction IS NULL
+		WITH n, [field IN $fields WHERE n[field] IS NOT NULL AND trim(toString(n[field])) <> '' | field] AS present
+		WHERE size(present) > 0
+		SET n.jurisdiction = toUpper(trim(toString(n[present[0]]))),
+			n.jurisdiction_source = 'inferred:' + present[0],
+			n.jurisdiction_tagged_at = datetime()
+		RETURN count(n) AS tagged
+	`
+
+	records, err := fa.neo4jClient.ExecuteQuery(ctx, query, map[string]interface{}{
+		"fields": fa.config.JurisdictionFields,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to infer jurisdictions: %w", err)
+	}
+
+	tagged := 0
+	if len(records) > 0 {
+		tagged = int(getFloat64(records[0], "tagged"))
+	}
+
+	fa.logger.Info("Inferred entity jurisdictions", "tagged", tagged)
+
+	return tagged, nil
+}
+
+// NormalizeFlowRequest fills in defaults and clamps a request to the
+// configured limits. Depth and hops become variable-length path bounds, so
+// they must never be left to the caller.
+func NormalizeFlowRequest(req *CrossBorderFlowRequest, cfg config.GeoConfig) {
+	if req.Depth <= 0 {
+		req.Depth = cfg.DefaultNetworkDepth
+	}
+	if req.Depth > cfg.MaxNetworkDepth {
+		req.Depth = cfg.MaxNetworkDepth
+	}
+	if req.TimeWindow <= 0 {
+		req.TimeWindow = cfg.DefaultTimeWindow
+	}
+	if req.MaxHops <= 0 || req.MaxHops > cfg.MaxRouteHops {
+		req.MaxHops = cfg.MaxRouteHops
+	}
+}
+
+// AnalyzeCrossBorderFlows summarizes how funds move between jurisdictions
+// within a network and flags routes through high-risk corridors. When no
+// entity IDs are given the whole graph is summarized.
+func (fa *FlowAnalyzer) AnalyzeCrossBorderFlows(ctx context.Context, req *CrossBorderFlowRequest) (*CrossBorderFlowResult, error) {
+	NormalizeFlowRequest(req, fa.config)
+
+	flows, err := fa.queryCorridorFlows(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CrossBorderFlowResult{
+		ID:                    uuid.New().String(),
+		AnalyzedAt:            time.Now(),
+		EntityIDs:             req.EntityIDs,
+		TimeWindow:            req.TimeWindow,
+		Summary:               SummarizeCorridors(flows, fa.policy),
+		FlaggedRoutes:         make([]*FlaggedRoute, 0),
+		HighRiskJurisdictions: fa.policy.HighRiskJurisdictions(),
+		HighRiskCorridors:     fa.policy.HighRiskCorridors(),
+	}
+
+	// Route tracing is only meaningful from a set of seed entities
+	if len(req.EntityIDs) > 0 {
+		routes, err := fa.queryFlaggedRoutes(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		result.FlaggedRoutes = routes
+	}
+
+	fa.logger.Info("Cross-border flow analysis completed",
+		"analysis_id", result.ID,
+		"seed_entities", len(req.EntityIDs),
+		"corridors", len(result.Summary.Corridors),
+		"high_risk_corridors", result.Summary.HighRiskCorridorCount,
+		"flagged_routes", len(result.FlaggedRoutes))
+
+	return result, nil
+}
+
+// queryCorridorFlows aggregates transaction amounts per jurisdiction pair
+func (fa *FlowAnalyzer) queryCorridorFlows(ctx context.Context, req *CrossBorderFlowRequest) ([]*CorridorFlow, error) {
+	var query string
+	params := map[string]interface{}{
+		"timeWindow": cypherDuration(req.TimeWindow),
+		"minAmount":  req.MinAmount,
+	}
+
+	if len(req.EntityIDs) > 0 {
+		query = fmt.Sprintf(`
+			MATCH (seed:Entity)
+			WHERE seed.id IN $entityIds
+			MATCH (seed)-[*0..%d]-(member:Entity)
+			WITH collect(DISTINCT member) AS members
+			UNWIND members AS src
+			MATCH (src)-[t:TRANSACTION]->(dst:Entity)
+			WHERE dst IN members
+			AND t.timestamp >= datetime() - duration($timeWindow)
+			AND coalesce(t.amount, 0) >= $minAmount
+			RETURN coalesce(src.jurisdiction, 'UNKNOWN') AS origin,
+				   coalesce(dst.jurisdiction, 'UNKNOWN') AS destination,
+				   sum(t.amount) AS totalAmount,
+				   max(t.amount) AS maxAmount,
+				   count(t) AS txCount,
+				   count(DISTINCT src) + count(DISTINCT dst) AS entityCount
+		`, req.Depth)
+		params["entityIds"] = req.EntityIDs
+	} else {
+		query = `
+			MATCH (src:Entity)-[t:TRANSACTION]->(dst:Entity)
+			WHERE t.timestamp >= datetime() - duration($timeWindow)
+			AND coalesce(t.amount, 0) >= $minAmount
+			RETURN coalesce(src.jurisdiction, 'UNKNOWN') AS origin,
+				   coalesce(dst.jurisdiction, 'UNKNOWN') AS destination,
+				   sum(t.amount) AS totalAmount,
+				   max(t.amount) AS maxAmount,
+				   count(t) AS txCount,
+				   count(DISTINCT src) + count(DISTINCT dst) AS entityCount
+		`
+	}
+
+	records, err := fa.neo4jClient.ExecuteQuery(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query corridor flows: %w", err)
+	}
+
+	flows := make([]*CorridorFlow, 0, len(records))
+	for _, record := range records {
+		origin, _ := record["origin"].(string)
+		destination, _ := record["destination"].(string)
+
+		flows = append(flows, &CorridorFlow{
+			Origin:           origin,
+			Destination:      destination,
+			TotalAmount:      getFloat64(record, "totalAmount"),
+			MaxAmount:        getFloat64(record, "maxAmount"),
+			TransactionCount: int(getFloat64(record, "txCount")),
+			EntityCount:      int(getFloat64(record, "entityCount")),
+		})
+	}
+
+	return flows, nil
+}
+
+// queryFlaggedRoutes finds transaction routes from the seed entities that
+// pass through high-risk jurisdictions or corridors
+func (fa *FlowAnalyzer) queryFlaggedRoutes(ctx context.Context, req *CrossBorderFlowRequest) ([]*FlaggedRoute, error) {
+	query := fmt.Sprintf(`
+		MATCH (seed:Entity)
+		WHERE seed.id IN $entityIds
+		MATCH p = (seed)-[:TRANSACTION*1..%d]->(dst:Entity)
+		WHERE ALL(r IN relationships(p) WHERE r.timestamp >= datetime() - duration($timeWindow)
+			AND coalesce(r.amount, 0) >= $minAmount)
+		WITH p, [n IN nodes(p) | coalesce(n.jurisdiction, 'UNKNOWN')] AS jurisdictions
+		WHERE ANY(j IN jurisdictions WHERE j IN $highRiskJurisdictions)
+		OR ANY(i IN range(0, size(jurisdictions) - 2)
+			WHERE jurisdictions[i] + '->' + jurisdictions[i + 1] IN $highRiskCorridors)
+		RETURN [n IN nodes(p) | n.id] AS entityIds,
+			   jurisdictions,
+			   [r IN relationships(p) | coalesce(r.amount, 0)] AS amounts
+		LIMIT $maxRoutes
+	`, req.MaxHops)
+
+	params := map[string]interface{}{
+		"entityIds":             req.EntityIDs,
+		"timeWindow":            cypherDuration(req.TimeWindow),
+		"minAmount":             req.MinAmount,
+		"highRiskJurisdictions": fa.policy.HighRiskJurisdictions(),
+		"highRiskCorridors":     fa.policy.HighRiskCorridors(),
+		"maxRoutes":             fa.config.MaxRoutes,
+	}
+
+	records, err := fa.neo4jClient.ExecuteQuery(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query high-risk routes: %w", err)
+	}
+
+	routes := make([]*FlaggedRoute, 0, len(records))
+	for _, record := range records {
+		jurisdictions := toStringSlice(record["jurisdictions"])
+
+		flagged, reasons := EvaluateRoute(jurisdictions, fa.policy)
+		if !flagged {
+			continue
+		}
+
+		amounts := toFloat64Slice(record["amounts"])
+		routes = append(routes, &FlaggedRoute{
+			EntityIDs:     toStringSlice(record["entityIds"]),
+			Jurisdictions: jurisdictions,
+			Amounts:       amounts,
+			FlowAmount:    minAmount(amounts),
+			HopCount:      len(amounts),
+			Reasons:       reasons,
+		})
+	}
+
+	return routes, nil
+}
+
+// Helper functions
+
+// cypherDuration formats a duration as an ISO-8601 duration accepted by Cypher
+func cypherDuration(d time.Duration) string {
+	return fmt.Sprintf("PT%dS", int64(d.Seconds()))
+}
+
+// minAmount returns the smallest amount on a route, which bounds how much
+// money can have travelled its full length
+func minAmount(amounts []float64) float64 {
+	if len(amounts) == 0 {
+		return 0
+	}
+
+	min := amounts[0]
+	for _, amount := range amounts[1:] {
+		if amount < min {
+			min = amount
+		}
+	}
+	return min
+}
+
+func getFloat64(record map[string]interface{}, key string) float64 {
+	if val, ok := record[key]; ok {
+		switch v := val.(type) {
+		case float64:
+			return v
+		case int64:
+			return float64(v)
+		case int:
+			return float64(v)
+		}
+	}
+	return 0.0
+}
+
+func toStringSlice(value interface{}) []string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return []string{}
+	}
+
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func toFloat64Slice(value interface{}) []float64 {
+	items, ok := value.([]interface{})
+	if !ok {
+		return []float64{}
+	}
+
+	result := make([]float64, 0, len(items))
+	for _, item := range items {
+		result = append(result, getFloat64(map[string]interface{}{"v": item}, "v"))
+	}
+	return result
+}
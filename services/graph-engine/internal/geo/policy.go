@@ -0,0 +1,193 @@
+package geo
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+)
+
+// UnknownJurisdiction is used for entities without a jurisdiction tag
+const UnknownJurisdiction = "UNKNOWN"
+
+// RiskPolicy decides which jurisdictions and corridors are considered high risk
+type RiskPolicy struct {
+	jurisdictions map[string]bool
+	corridors     map[string]bool
+}
+
+// NewRiskPolicy builds a risk policy from the geo configuration
+func NewRiskPolicy(cfg config.GeoConfig) *RiskPolicy {
+	policy := &RiskPolicy{
+		jurisdictions: make(map[string]bool),
+		corridors:     make(map[string]bool),
+	}
+
+	for _, jurisdiction := range cfg.HighRiskJurisdictions {
+		if code := NormalizeJurisdiction(jurisdiction); code != UnknownJurisdiction {
+			policy.jurisdictions[code] = true
+		}
+	}
+
+	for _, corridor := range cfg.HighRiskCorridors {
+		parts := strings.Split(corridor, "->")
+		if len(parts) != 2 {
+			continue
+		}
+		policy.corridors[CorridorKey(parts[0], parts[1])] = true
+	}
+
+	return policy
+}
+
+// IsHighRiskJurisdiction reports whether a jurisdiction is on the high-risk list
+func (p *RiskPolicy) IsHighRiskJurisdiction(jurisdiction string) bool {
+	return p.jurisdictions[NormalizeJurisdiction(jurisdiction)]
+}
+
+// IsHighRiskCorridor reports whether funds moving from origin to destination
+// cross a high-risk corridor, either because the corridor is listed or
+// because one of its ends is a high-risk jurisdiction
+func (p *RiskPolicy) IsHighRiskCorridor(origin, destination string) bool {
+	if p.corridors[CorridorKey(origin, destination)] {
+		return true
+	}
+	return p.IsHighRiskJurisdiction(origin) || p.IsHighRiskJurisdiction(destination)
+}
+
+// HighRiskJurisdictions returns the configured high-risk jurisdictions
+func (p *RiskPolicy) HighRiskJurisdictions() []string {
+	codes := make([]string, 0, len(p.jurisdictions))
+	for code := range p.jurisdictions {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// HighRiskCorridors returns the explicitly configured high-risk corridors
+func (p *RiskPolicy) HighRiskCorridors() []string {
+	corridors := make([]string, 0, len(p.corridors))
+	for corridor := range p.corridors {
+		corridors = append(corridors, corridor)
+	}
+	sort.Strings(corridors)
+	return corridors
+}
+
+// NormalizeJurisdiction converts a jurisdiction code to its canonical form
+func NormalizeJurisdiction(jurisdiction string) string {
+	code := strings.ToUpper(strings.TrimSpace(jurisdiction))
+	if code == "" {
+		return UnknownJurisdiction
+	}
+	return code
+}
+
+// CorridorKey returns the canonical "ORIGIN->DESTINATION" key for a corridor
+func CorridorKey(origin, destination string) string {
+	return NormalizeJurisdiction(origin) + "->" + NormalizeJurisdiction(destination)
+}
+
+// SummarizeCorridors aggregates raw corridor flows into a summary, flagging
+// high-risk corridors and ordering corridors by total amount
+func SummarizeCorridors(flows []*CorridorFlow, policy *RiskPolicy) *FlowSummary {
+	summary := &FlowSummary{
+		Corridors:            make([]*CorridorFlow, 0),
+		JurisdictionOutflows: make(map[string]float64),
+		JurisdictionInflows:  make(map[string]float64),
+	}
+
+	merged := make(map[string]*CorridorFlow)
+	for _, flow := range flows {
+		origin := NormalizeJurisdiction(flow.Origin)
+		destination := NormalizeJurisdiction(flow.Destination)
+
+		if origin == destination {
+			summary.DomesticAmount += flow.TotalAmount
+			summary.DomesticCount += flow.TransactionCount
+			continue
+		}
+
+		key := CorridorKey(origin, destination)
+		corridor, ok := merged[key]
+		if !ok {
+			corridor = &CorridorFlow{
+				Corridor:    key,
+				Origin:      origin,
+				Destination: destination,
+				HighRisk:    policy.IsHighRiskCorridor(origin, destination),
+			}
+			merged[key] = corridor
+		}
+
+		corridor.TotalAmount += flow.TotalAmount
+		corridor.TransactionCount += flow.TransactionCount
+		corridor.EntityCount += flow.EntityCount
+		if flow.MaxAmount > corridor.MaxAmount {
+			corridor.MaxAmount = flow.MaxAmount
+		}
+
+		summary.CrossBorderAmount += flow.TotalAmount
+		summary.CrossBorderCount += flow.TransactionCount
+		summary.JurisdictionOutflows[origin] += flow.TotalAmount
+		summary.JurisdictionInflows[destination] += flow.TotalAmount
+	}
+
+	for _, corridor := range merged {
+		if corridor.TransactionCount > 0 {
+			corridor.AverageAmount = corridor.TotalAmount / float64(corridor.TransactionCount)
+		}
+		if corridor.HighRisk {
+			summary.HighRiskAmount += corridor.TotalAmount
+			summary.HighRiskCorridorCount++
+		}
+		summary.Corridors = append(summary.Corridors, corridor)
+	}
+
+	sort.Slice(summary.Corridors, func(i, j int) bool {
+		if summary.Corridors[i].TotalAmount == summary.Corridors[j].TotalAmount {
+			return summary.Corridors[i].Corridor < summary.Corridors[j].Corridor
+		}
+		return summary.Corridors[i].TotalAmount > summary.Corridors[j].TotalAmount
+	})
+
+	total := summary.CrossBorderAmount + summary.DomesticAmount
+	if total > 0 {
+		summary.CrossBorderShare = summary.CrossBorderAmount / total
+	}
+	for _, corridor := range summary.Corridors {
+		if summary.CrossBorderAmount > 0 {
+			corridor.Share = corridor.TotalAmount / summary.CrossBorderAmount
+		}
+	}
+
+	return summary
+}
+
+// EvaluateRoute determines whether a multi-hop route passes through a
+// high-risk jurisdiction or corridor and returns the reasons it was flagged
+func EvaluateRoute(jurisdictions []string, policy *RiskPolicy) (bool, []string) {
+	reasons := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for i, jurisdiction := range jurisdictions {
+		code := NormalizeJurisdiction(jurisdiction)
+		if policy.IsHighRiskJurisdiction(code) && !seen["jurisdiction:"+code] {
+			seen["jurisdiction:"+code] = true
+			reasons = append(reasons, "passes through high-risk jurisdiction "+code)
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		key := CorridorKey(jurisdictions[i-1], code)
+		if policy.corridors[key] && !seen["corridor:"+key] {
+			seen["corridor:"+key] = true
+			reasons = append(reasons, "crosses high-risk corridor "+key)
+		}
+	}
+
+	return len(reasons) > 0, reasons
+}
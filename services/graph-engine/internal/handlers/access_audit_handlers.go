@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aegisshield/graph-engine/internal/accessaudit"
+	"github.com/aegisshield/graph-engine/internal/database"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultAccessAuditLimit = 100
+	maxAccessAuditLimit     = 1000
+)
+
+// AccessAuditHTTPHandlers contains HTTP handlers for compliance review of entity access
+type AccessAuditHTTPHandlers struct {
+	repo     *database.Repository
+	detector *accessaudit.Detector
+	logger   *slog.Logger
+}
+
+// NewAccessAuditHTTPHandlers creates new access audit HTTP handlers
+func NewAccessAuditHTTPHandlers(repo *database.Repository, detector *accessaudit.Detector, logger *slog.Logger) *AccessAuditHTTPHandlers {
+	return &AccessAuditHTTPHandlers{
+		repo:     repo,
+		detector: detector,
+		logger:   logger,
+	}
+}
+
+// RegisterAccessAuditRoutes registers entity access audit HTTP routes
+func (h *AccessAuditHTTPHandlers) RegisterAccessAuditRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/access-audit/entities/{entity_id}/viewers", h.getEntityViewers).Methods("GET")
+	router.HandleFunc("/api/v1/access-audit/events", h.listAccessEvents).Methods("GET")
+	router.HandleFunc("/api/v1/access-audit/alerts", h.listAlerts).Methods("GET")
+	router.HandleFunc("/api/v1/access-audit/alerts/{id}/acknowledge", h.acknowledgeAlert).Methods("POST")
+	router.HandleFunc("/api/v1/access-audit/detect", h.runDetection).Methods("POST")
+}
+
+// getEntityViewers answers "who looked at entity X", with the most recent views
+func (h *AccessAuditHTTPHandlers) getEntityViewers(w http.ResponseWriter, r *http.Request) {
+	entityID := mux.Vars(r)["entity_id"]
+
+	from, to, err := parseTimeRange(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid time range, expected RFC3339", err)
+		return
+	}
+
+	viewers, err := h.repo.ListEntityViewers(r.Context(), entityID, from, to)
+	if err != nil {
+		h.logger.Error("Failed to list entity viewers", "entity_id", entityID, "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to list entity viewers", err)
+		return
+	}
+
+	events, err := h.repo.ListEntityAccessEvents(r.Context(), database.EntityAccessFilter{
+		EntityID: entityID,
+		From:     from,
+		To:       to,
+		Limit:    parseLimit(r),
+	})
+	if err != nil {
+		h.logger.Error("Failed to list entity access events", "entity_id", entityID, "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to list entity access events", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"entity_id":     entityID,
+		"viewers":       viewers,
+		"viewer_count":  len(viewers),
+		"recent_events": events,
+	})
+}
+
+func (h *AccessAuditHTTPHandlers) listAccessEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("entity_id") == "" && query.Get("user_id") == "" {
+		h.writeError(w, http.StatusBadRequest, "entity_id or user_id is required", nil)
+		return
+	}
+
+	from, to, err := parseTimeRange(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid time range, expected RFC3339", err)
+		return
+	}
+
+	events, err := h.repo.ListEntityAccessEvents(r.Context(), database.EntityAccessFilter{
+		EntityID: query.Get("entity_id"),
+		UserID:   query.Get("user_id"),
+		From:     from,
+		To:       to,
+		Limit:    parseLimit(r),
+	})
+	if err != nil {
+		h.logger.Error("Failed to list entity access events", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to list entity access events", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"events": events,
+		"count":  len(events),
+	})
+}
+
+func (h *AccessAuditHTTPHandlers) listAlerts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	alerts, err := h.repo.ListEntityAccessAlerts(r.Context(), query.Get("status"), query.Get("user_id"), parseLimit(r))
+	if err != nil {
+		h.logger.Error("Failed to list entity access alerts", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to list entity access alerts", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"alerts": alerts,
+		"count":  len(alerts),
+	})
+}
+
+func (h *AccessAuditHTTPHandlers) acknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AcknowledgedBy string `json:"acknowledged_by"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.AcknowledgedBy == "" {
+		h.writeError(w, http.StatusBadRequest, "acknowledged_by is required", err)
+		return
+	}
+
+	alert, err := h.repo.AcknowledgeEntityAccessAlert(r.Context(), mux.Vars(r)["id"], req.AcknowledgedBy)
+	if err != nil {
+		if errors.Is(err, database.ErrAccessAlertNotFound) {
+			h.writeError(w, http.StatusNotFound, "Entity access alert not found", err)
+			return
+		}
+		h.logger.Error("Failed to acknowledge entity access alert", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to acknowledge entity access alert", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, alert)
+}
+
+func (h *AccessAuditHTTPHandlers) runDetection(w http.ResponseWriter, r *http.Request) {
+	alerts, err := h.detector.Detect(r.Context())
+	if err != nil {
+		h.logger.Error("Entity access anomaly detection failed", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Entity access anomaly detection failed", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"alerts": alerts,
+		"count":  len(alerts),
+	})
+}
+
+func parseTimeRange(r *http.Request) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+	query := r.URL.Query()
+
+	if value := query.Get("from"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, nil, err
+		}
+		from = &t
+	}
+	if value := query.Get("to"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, nil, err
+		}
+		to = &t
+	}
+
+	return from, to, nil
+}
+
+func parseLimit(r *http.Request) int {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		return defaultAccessAuditLimit
+	}
+	if limit > maxAccessAuditLimit {
+		return maxAccessAuditLimit
+	}
+	return limit
+}
+
+// Helper methods
+
+func (h *AccessAuditHTTPHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *AccessAuditHTTPHandlers) writeError(w http.ResponseWriter, status int, message string, err error) {
+	errorResponse := map[string]interface{}{
+		"error":     message,
+		"status":    status,
+		"timestamp": time.Now(),
+	}
+
+	if err != nil {
+		errorResponse["details"] = err.Error()
+	}
+
+	h.writeJSON(w, status, errorResponse)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aegisshield/graph-engine/internal/backup"
+	"github.com/gorilla/mux"
+)
+
+// BackupHTTPHandlers contains HTTP handlers for graph snapshots. Restores are
+// run with the restore command, not over HTTP.
+type BackupHTTPHandlers struct {
+	service *backup.Service
+	logger  *slog.Logger
+}
+
+// NewBackupHTTPHandlers creates new backup HTTP handlers
+func NewBackupHTTPHandlers(service *backup.Service, logger *slog.Logger) *BackupHTTPHandlers {
+	return &BackupHTTPHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterBackupRoutes registers graph backup HTTP routes
+func (h *BackupHTTPHandlers) RegisterBackupRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/backups", h.listSnapshots).Methods("GET")
+	router.HandleFunc("/api/v1/backups", h.takeSnapshot).Methods("POST")
+	router.HandleFunc("/api/v1/backups/restore-plan", h.planRestore).Methods("GET")
+	router.HandleFunc("/api/v1/backups/{id}", h.getSnapshot).Methods("GET")
+	router.HandleFunc("/api/v1/backups/{id}/verify", h.verifySnapshot).Methods("POST")
+}
+
+func (h *BackupHTTPHandlers) listSnapshots(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := h.service.ListSnapshots(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list graph snapshots", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to list graph snapshots", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"snapshots": snapshots,
+		"count":     len(snapshots),
+	})
+}
+
+// takeSnapshot takes a snapshot now, outside the schedule
+func (h *BackupHTTPHandlers) takeSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Full bool `json:"full"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+	}
+
+	manifest, err := h.service.TakeSnapshot(r.Context(), req.Full)
+	if err != nil {
+		h.logger.Error("Graph snapshot failed", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Graph snapshot failed", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, manifest)
+}
+
+func (h *BackupHTTPHandlers) getSnapshot(w http.ResponseWriter, r *http.Request) {
+	manifest, err := h.service.GetSnapshot(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to get graph snapshot")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, manifest)
+}
+
+func (h *BackupHTTPHandlers) verifySnapshot(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.Verify(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to verify graph snapshot")
+		return
+	}
+
+	if !result.Valid {
+		h.logger.Warn("Graph snapshot failed verification", "snapshot_id", result.SnapshotID, "problems", result.Problems)
+	}
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// planRestore shows which snapshots a restore to ?at= would replay, so a
+// drill can be checked before it is run
+func (h *BackupHTTPHandlers) planRestore(w http.ResponseWriter, r *http.Request) {
+	at := time.Now()
+	if value := r.URL.Query().Get("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "Invalid point in time, expected RFC3339", err)
+			return
+		}
+		at = parsed
+	}
+
+	plan, err := h.service.PlanRestore(r.Context(), at)
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to plan graph restore")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, plan)
+}
+
+// writeServiceError maps missing snapshots to 404 and broken chains to 409
+func (h *BackupHTTPHandlers) writeServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, backup.ErrSnapshotNotFound), errors.Is(err, backup.ErrNoSnapshot):
+		h.writeError(w, http.StatusNotFound, message, err)
+	case errors.Is(err, backup.ErrBrokenChain):
+		h.writeError(w, http.StatusConflict, message, err)
+	default:
+		h.logger.Error(message, "error", err)
+		h.writeError(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+// Helper methods
+
+func (h *BackupHTTPHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *BackupHTTPHandlers) writeError(w http.ResponseWriter, status int, message string, err error) {
+	errorResponse := map[string]interface{}{
+		"error":     message,
+		"status":    status,
+		"timestamp": time.Now(),
+	}
+
+	if err != nil {
+		errorResponse["details"] = err.Error()
+	}
+
+	h.writeJSON(w, status, errorResponse)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -85,6 +86,12 @@ func (h *EnhancedHTTPHandlers) detectPatterns(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	r, asOf, err := withAsOf(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid as_of", err)
+		return
+	}
+
 	// Validate request
 	if len(req.Types) == 0 {
 		h.writeError(w, http.StatusBadRequest, "pattern types are required", nil)
@@ -102,7 +109,8 @@ func (h *EnhancedHTTPHandlers) detectPatterns(w http.ResponseWriter, r *http.Req
 	h.logger.Info("Processing pattern detection request",
 		"types", req.Types,
 		"entity_count", len(req.EntityIDs),
-		"min_confidence", req.MinConfidence)
+		"min_confidence", req.MinConfidence,
+		"as_of", asOf)
 
 	// Perform pattern detection
 	result, err := h.patternDetector.DetectPatterns(r.Context(), &req)
@@ -246,6 +254,12 @@ func (h *EnhancedHTTPHandlers) analyzePaths(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	r, asOf, err := withAsOf(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid as_of", err)
+		return
+	}
+
 	// Validate request
 	if req.SourceID == "" {
 		h.writeError(w, http.StatusBadRequest, "source_id is required", nil)
@@ -264,9 +278,15 @@ func (h *EnhancedHTTPHandlers) analyzePaths(w http.ResponseWriter, r *http.Reque
 	h.logger.Info("Analyzing paths",
 		"source_id", req.SourceID,
 		"target_id", req.TargetID,
-		"max_depth", req.MaxDepth)
+		"algorithm", req.Algorithm,
+		"max_depth", req.MaxDepth,
+		"as_of", asOf)
 
 	result, err := h.analytics.AnalyzePaths(r.Context(), &req)
+	if errors.Is(err, analytics.ErrInvalidPathRequest) {
+		h.writeError(w, http.StatusBadRequest, "Invalid path request", err)
+		return
+	}
 	if err != nil {
 		h.logger.Error("Path analysis failed", "error", err)
 		h.writeError(w, http.StatusInternalServerError, "Path analysis failed", err)
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aegisshield/graph-engine/internal/exposure"
+	"github.com/gorilla/mux"
+)
+
+// ExposureHTTPHandlers contains HTTP handlers for entity exposure to
+// sanctioned and flagged entities
+type ExposureHTTPHandlers struct {
+	service *exposure.Service
+	logger  *slog.Logger
+}
+
+// NewExposureHTTPHandlers creates new exposure HTTP handlers
+func NewExposureHTTPHandlers(service *exposure.Service, logger *slog.Logger) *ExposureHTTPHandlers {
+	return &ExposureHTTPHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterExposureRoutes registers exposure HTTP routes
+func (h *ExposureHTTPHandlers) RegisterExposureRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/exposure/summary", h.getSummary).Methods("GET")
+	router.HandleFunc("/api/v1/exposure/entities", h.listExposures).Methods("GET")
+	router.HandleFunc("/api/v1/exposure/entities/{id}", h.getExposure).Methods("GET")
+	router.HandleFunc("/api/v1/exposure/sources/{id}", h.setSource).Methods("PUT")
+	router.HandleFunc("/api/v1/exposure/recompute", h.recompute).Methods("POST")
+}
+
+func (h *ExposureHTTPHandlers) getSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.service.Summary(r.Context())
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to summarize exposure")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, summary)
+}
+
+// listExposures lists exposed entities, optionally filtered by band,
+// minimum value and maximum distance
+func (h *ExposureHTTPHandlers) listExposures(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := exposure.Filter{
+		Band:        query.Get("band"),
+		MinValue:    parseFloat(query.Get("min_value"), 0),
+		MaxDistance: parseInt(query.Get("max_distance"), -1),
+		Limit:       parseInt(query.Get("limit"), 0),
+	}
+
+	exposures, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to list exposed entities")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"entities": exposures,
+		"count":    len(exposures),
+	})
+}
+
+func (h *ExposureHTTPHandlers) getExposure(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to get entity exposure")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// setSource marks or unmarks an entity as sanctioned or flagged. Exposure
+// around it is recomputed on the next cycle.
+func (h *ExposureHTTPHandlers) setSource(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Property string `json:"property"`
+		Source   *bool  `json:"source"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if req.Source == nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body", fmt.Errorf("source is required"))
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if err := h.service.SetSource(r.Context(), id, req.Property, *req.Source); err != nil {
+		h.writeServiceError(w, err, "Failed to set exposure source")
+		return
+	}
+
+	h.writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"entity_id": id,
+		"property":  req.Property,
+		"source":    *req.Source,
+	})
+}
+
+// recompute recomputes exposure around the given entities now, or for the
+// whole graph when none are given
+func (h *ExposureHTTPHandlers) recompute(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		EntityIDs []string `json:"entity_ids"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+	}
+
+	result, err := h.service.Recompute(r.Context(), req.EntityIDs)
+	if err != nil {
+		h.writeServiceError(w, err, "Exposure recompute failed")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// writeServiceError maps unknown bands and source properties to 400 and
+// missing entities to 404
+func (h *ExposureHTTPHandlers) writeServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, exposure.ErrUnknownBand), errors.Is(err, exposure.ErrUnknownSourceProperty):
+		h.writeError(w, http.StatusBadRequest, message, err)
+	case errors.Is(err, exposure.ErrEntityNotFound):
+		h.writeError(w, http.StatusNotFound, message, err)
+	default:
+		h.logger.Error(message, "error", err)
+		h.writeError(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+// Helper methods
+
+func (h *ExposureHTTPHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *ExposureHTTPHandlers) writeError(w http.ResponseWriter, status int, message string, err error) {
+	errorResponse := map[string]interface{}{
+		"error":     message,
+		"status":    status,
+		"timestamp": time.Now(),
+	}
+
+	if err != nil {
+		errorResponse["details"] = err.Error()
+	}
+
+	h.writeJSON(w, status, errorResponse)
+}
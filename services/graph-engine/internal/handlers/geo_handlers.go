@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aegisshield/graph-engine/internal/geo"
+	"github.com/gorilla/mux"
+)
+
+// GeoHTTPHandlers contains HTTP handlers for jurisdiction tagging and cross-border flow analysis
+type GeoHTTPHandlers struct {
+	flowAnalyzer *geo.FlowAnalyzer
+	logger       *slog.Logger
+}
+
+// NewGeoHTTPHandlers creates new geo HTTP handlers
+func NewGeoHTTPHandlers(flowAnalyzer *geo.FlowAnalyzer, logger *slog.Logger) *GeoHTTPHandlers {
+	return &GeoHTTPHandlers{
+		flowAnalyzer: flowAnalyzer,
+		logger:       logger,
+	}
+}
+
+// RegisterGeoRoutes registers geo analysis HTTP routes
+func (h *GeoHTTPHandlers) RegisterGeoRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/geo/jurisdictions", h.tagJurisdictions).Methods("PUT")
+	router.HandleFunc("/api/v1/geo/jurisdictions/infer", h.inferJurisdictions).Methods("POST")
+	router.HandleFunc("/api/v1/geo/cross-border-flows", h.analyzeCrossBorderFlows).Methods("POST")
+	router.HandleFunc("/api/v1/geo/corridors", h.getCorridorTotals).Methods("GET")
+}
+
+func (h *GeoHTTPHandlers) tagJurisdictions(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Tags []geo.JurisdictionTag `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if len(req.Tags) == 0 {
+		h.writeError(w, http.StatusBadRequest, "At least one jurisdiction tag is required", nil)
+		return
+	}
+
+	for _, tag := range req.Tags {
+		if tag.EntityID == "" || strings.TrimSpace(tag.Jurisdiction) == "" {
+			h.writeError(w, http.StatusBadRequest, "Each tag requires entity_id and jurisdiction", nil)
+			return
+		}
+	}
+
+	tagged, err := h.flowAnalyzer.TagJurisdictions(r.Context(), req.Tags)
+	if err != nil {
+		h.logger.Error("Failed to tag jurisdictions", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to tag jurisdictions", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"requested": len(req.Tags),
+		"tagged":    tagged,
+	})
+}
+
+func (h *GeoHTTPHandlers) inferJurisdictions(w http.ResponseWriter, r *http.Request) {
+	tagged, err := h.flowAnalyzer.InferJurisdictions(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to infer jurisdictions", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to infer jurisdictions", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"tagged": tagged,
+	})
+}
+
+func (h *GeoHTTPHandlers) analyzeCrossBorderFlows(w http.ResponseWriter, r *http.Request) {
+	var req geo.CrossBorderFlowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if len(req.EntityIDs) == 0 {
+		h.writeError(w, http.StatusBadRequest, "entity_ids is required", nil)
+		return
+	}
+
+	result, err := h.flowAnalyzer.AnalyzeCrossBorderFlows(r.Context(), &req)
+	if err != nil {
+		h.logger.Error("Cross-border flow analysis failed", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Cross-border flow analysis failed", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// getCorridorTotals returns per-corridor totals, optionally scoped to a network,
+// for use in reports
+func (h *GeoHTTPHandlers) getCorridorTotals(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := &geo.CrossBorderFlowRequest{}
+
+	if entityIDs := query.Get("entity_ids"); entityIDs != "" {
+		req.EntityIDs = strings.Split(entityIDs, ",")
+	}
+	if depth, err := strconv.Atoi(query.Get("depth")); err == nil {
+		req.Depth = depth
+	}
+	if minAmount, err := strconv.ParseFloat(query.Get("min_amount"), 64); err == nil {
+		req.MinAmount = minAmount
+	}
+	if window := query.Get("time_window"); window != "" {
+		timeWindow, err := time.ParseDuration(window)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "Invalid time_window", err)
+			return
+		}
+		req.TimeWindow = timeWindow
+	}
+
+	result, err := h.flowAnalyzer.AnalyzeCrossBorderFlows(r.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to calculate corridor totals", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to calculate corridor totals", err)
+		return
+	}
+
+	highRiskOnly := query.Get("high_risk_only") == "true"
+	corridors := make([]*geo.CorridorFlow, 0, len(result.Summary.Corridors))
+	for _, corridor := range result.Summary.Corridors {
+		if highRiskOnly && !corridor.HighRisk {
+			continue
+		}
+		corridors = append(corridors, corridor)
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"corridors":           corridors,
+		"cross_border_amount": result.Summary.CrossBorderAmount,
+		"high_risk_amount":    result.Summary.HighRiskAmount,
+		"time_window":         result.TimeWindow.String(),
+		"generated_at":        result.AnalyzedAt,
+	})
+}
+
+// Helper methods
+
+func (h *GeoHTTPHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *GeoHTTPHandlers) writeError(w http.ResponseWriter, status int, message string, err error) {
+	errorResponse := map[string]interface{}{
+		"error":     message,
+		"status":    status,
+		"timestamp": time.Now(),
+	}
+
+	if err != nil {
+		errorResponse["details"] = err.Error()
+	}
+
+	h.writeJSON(w, status, errorResponse)
+}
@@ -12,13 +12,15 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/aegisshield/graph-engine/internal/config"
 	"github.com/aegisshield/graph-engine/internal/engine"
+	"github.com/aegisshield/shared/startup"
 )
 
 // HTTPHandlers contains HTTP request handlers
 type HTTPHandlers struct {
-	engine *engine.GraphEngine
-	config config.Config
-	logger *slog.Logger
+	engine    *engine.GraphEngine
+	config    config.Config
+	logger    *slog.Logger
+	readiness *startup.Readiness
 }
 
 // NewHTTPHandlers creates new HTTP handlers
@@ -134,6 +136,12 @@ func (h *HTTPHandlers) findPaths(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	r, asOf, err := withAsOf(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid as_of", err)
+		return
+	}
+
 	// Validate request
 	if len(req.SourceIDs) == 0 {
 		h.writeError(w, http.StatusBadRequest, "source_ids is required", nil)
@@ -163,6 +171,7 @@ func (h *HTTPHandlers) findPaths(w http.ResponseWriter, r *http.Request) {
 
 	response := &FindPathsResponse{
 		Paths: convertPathsFromEngine(paths),
+		AsOf:  asOf,
 	}
 
 	h.writeJSON(w, http.StatusOK, response)
@@ -382,6 +391,12 @@ func (h *HTTPHandlers) getEntityNeighborhood(w http.ResponseWriter, r *http.Requ
 		relationshipTypes = strings.Split(types, ",")
 	}
 
+	r, asOf, err := withAsOf(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid as_of", err)
+		return
+	}
+
 	subGraph, err := h.engine.GetEntityNeighborhood(r.Context(), entityID, relationshipTypes)
 	if err != nil {
 		h.logger.Error("Failed to get entity neighborhood", "entity_id", entityID, "error", err)
@@ -392,6 +407,7 @@ func (h *HTTPHandlers) getEntityNeighborhood(w http.ResponseWriter, r *http.Requ
 	response := &GetEntityNeighborhoodResponse{
 		EntityID: entityID,
 		SubGraph: convertSubGraphFromEngine(subGraph),
+		AsOf:     asOf,
 	}
 
 	h.writeJSON(w, http.StatusOK, response)
@@ -476,8 +492,19 @@ func (h *HTTPHandlers) healthCheck(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// SetReadiness gates the readiness check on service startup
+func (h *HTTPHandlers) SetReadiness(readiness *startup.Readiness) {
+	h.readiness = readiness
+}
+
 // readinessCheck returns service readiness status
 func (h *HTTPHandlers) readinessCheck(w http.ResponseWriter, r *http.Request) {
+	// Report pending or failed components until startup has completed
+	if h.readiness != nil && !h.readiness.Ready() {
+		h.readiness.ServeHTTP(w, r)
+		return
+	}
+
 	// Check if engine is ready
 	if !h.engine.IsReady() {
 		h.writeError(w, http.StatusServiceUnavailable, "Service not ready", nil)
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aegisshield/graph-engine/internal/maintenance"
+	"github.com/gorilla/mux"
+)
+
+// MaintenanceHTTPHandlers contains HTTP handlers for graph maintenance runs
+type MaintenanceHTTPHandlers struct {
+	service *maintenance.Service
+	logger  *slog.Logger
+}
+
+// NewMaintenanceHTTPHandlers creates new maintenance HTTP handlers
+func NewMaintenanceHTTPHandlers(service *maintenance.Service, logger *slog.Logger) *MaintenanceHTTPHandlers {
+	return &MaintenanceHTTPHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterMaintenanceRoutes registers graph maintenance HTTP routes
+func (h *MaintenanceHTTPHandlers) RegisterMaintenanceRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/maintenance/runs", h.listRuns).Methods("GET")
+	router.HandleFunc("/api/v1/maintenance/runs", h.startRun).Methods("POST")
+	router.HandleFunc("/api/v1/maintenance/runs/{id}", h.getRun).Methods("GET")
+}
+
+func (h *MaintenanceHTTPHandlers) listRuns(w http.ResponseWriter, r *http.Request) {
+	runs := h.service.Runs()
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"runs":  runs,
+		"count": len(runs),
+		"jobs":  maintenance.Jobs,
+	})
+}
+
+// startRun runs maintenance now, outside the schedule. Runs are dry runs
+// unless dry_run is explicitly false.
+func (h *MaintenanceHTTPHandlers) startRun(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DryRun *bool    `json:"dry_run"`
+		Jobs   []string `json:"jobs"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+	}
+
+	opts := maintenance.RunOptions{DryRun: true, Jobs: req.Jobs, Trigger: maintenance.TriggerManual}
+	if req.DryRun != nil {
+		opts.DryRun = *req.DryRun
+	}
+
+	run, err := h.service.Run(r.Context(), opts)
+	if err != nil {
+		h.writeServiceError(w, err, "Graph maintenance failed")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, run)
+}
+
+func (h *MaintenanceHTTPHandlers) getRun(w http.ResponseWriter, r *http.Request) {
+	run, err := h.service.GetRun(mux.Vars(r)["id"])
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to get maintenance run")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, run)
+}
+
+// writeServiceError maps unknown jobs to 400, missing runs to 404 and
+// overlapping runs to 409
+func (h *MaintenanceHTTPHandlers) writeServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, maintenance.ErrUnknownJob):
+		h.writeError(w, http.StatusBadRequest, message, err)
+	case errors.Is(err, maintenance.ErrRunNotFound):
+		h.writeError(w, http.StatusNotFound, message, err)
+	case errors.Is(err, maintenance.ErrRunInProgress):
+		h.writeError(w, http.StatusConflict, message, err)
+	default:
+		h.logger.Error(message, "error", err)
+		h.writeError(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+// Helper methods
+
+func (h *MaintenanceHTTPHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *MaintenanceHTTPHandlers) writeError(w http.ResponseWriter, status int, message string, err error) {
+	errorResponse := map[string]interface{}{
+		"error":     message,
+		"status":    status,
+		"timestamp": time.Now(),
+	}
+
+	if err != nil {
+		errorResponse["details"] = err.Error()
+	}
+
+	h.writeJSON(w, status, errorResponse)
+}
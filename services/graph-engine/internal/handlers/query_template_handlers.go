@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/database"
+	"github.com/aegisshield/graph-engine/internal/querytemplates"
+	"github.com/gorilla/mux"
+)
+
+// QueryTemplateHTTPHandlers contains HTTP handlers for saved query templates
+type QueryTemplateHTTPHandlers struct {
+	service *querytemplates.Service
+	cfg     config.QueryTemplateConfig
+	logger  *slog.Logger
+}
+
+// NewQueryTemplateHTTPHandlers creates new query template HTTP handlers
+func NewQueryTemplateHTTPHandlers(service *querytemplates.Service, cfg config.QueryTemplateConfig, logger *slog.Logger) *QueryTemplateHTTPHandlers {
+	return &QueryTemplateHTTPHandlers{
+		service: service,
+		cfg:     cfg,
+		logger:  logger,
+	}
+}
+
+// RegisterQueryTemplateRoutes registers query template HTTP routes
+func (h *QueryTemplateHTTPHandlers) RegisterQueryTemplateRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/query-templates", h.listTemplates).Methods("GET")
+	router.HandleFunc("/api/v1/query-templates", h.createTemplate).Methods("POST")
+	router.HandleFunc("/api/v1/query-templates/{id}", h.getTemplate).Methods("GET")
+	router.HandleFunc("/api/v1/query-templates/{id}", h.updateTemplate).Methods("PUT")
+	router.HandleFunc("/api/v1/query-templates/{id}", h.deleteTemplate).Methods("DELETE")
+	router.HandleFunc("/api/v1/query-executions", h.executeTemplate).Methods("POST")
+	router.HandleFunc("/api/v1/query-executions", h.listExecutions).Methods("GET")
+}
+
+func (h *QueryTemplateHTTPHandlers) listTemplates(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.caller(w, r)
+	if !ok {
+		return
+	}
+
+	templates, err := h.service.List(r.Context(), caller, r.URL.Query().Get("tag"))
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to list query templates")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+func (h *QueryTemplateHTTPHandlers) createTemplate(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.caller(w, r)
+	if !ok {
+		return
+	}
+
+	var input querytemplates.TemplateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	template, err := h.service.Create(r.Context(), caller, input)
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to create query template")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, template)
+}
+
+func (h *QueryTemplateHTTPHandlers) getTemplate(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.caller(w, r)
+	if !ok {
+		return
+	}
+
+	template, err := h.service.Get(r.Context(), caller, mux.Vars(r)["id"])
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to get query template")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, template)
+}
+
+// updateTemplate replaces a template's fields. A version in the body must
+// match the current version, so concurrent edits are not lost.
+func (h *QueryTemplateHTTPHandlers) updateTemplate(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.caller(w, r)
+	if !ok {
+		return
+	}
+
+	var input querytemplates.TemplateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	template, err := h.service.Update(r.Context(), caller, mux.Vars(r)["id"], input)
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to update query template")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, template)
+}
+
+func (h *QueryTemplateHTTPHandlers) deleteTemplate(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.caller(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), caller, mux.Vars(r)["id"]); err != nil {
+		h.writeServiceError(w, err, "Failed to delete query template")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// executeTemplate runs a template with the given parameter values, for the
+// case UI's one-click execution
+func (h *QueryTemplateHTTPHandlers) executeTemplate(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.caller(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		TemplateID string `json:"template_id"`
+		querytemplates.ExecuteRequest
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if req.TemplateID == "" {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body", fmt.Errorf("template_id is required"))
+		return
+	}
+
+	result, err := h.service.Execute(r.Context(), caller, req.TemplateID, req.ExecuteRequest)
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to execute query template")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// listExecutions lists execution history by template, investigation or user
+func (h *QueryTemplateHTTPHandlers) listExecutions(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.caller(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	executions, err := h.service.History(r.Context(), caller, database.QueryExecutionFilter{
+		TemplateID:      query.Get("template_id"),
+		UserID:          query.Get("user_id"),
+		InvestigationID: query.Get("investigation_id"),
+		Limit:           limit,
+	})
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to list query executions")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"executions": executions,
+		"count":      len(executions),
+	})
+}
+
+// caller identifies the caller from the gateway's user and roles headers,
+// writing a 401 when there is no user
+func (h *QueryTemplateHTTPHandlers) caller(w http.ResponseWriter, r *http.Request) (querytemplates.Caller, bool) {
+	caller := querytemplates.Caller{UserID: strings.TrimSpace(r.Header.Get(h.cfg.UserHeader))}
+	if caller.UserID == "" {
+		h.writeError(w, http.StatusUnauthorized, "Query templates require an authenticated user",
+			fmt.Errorf("missing %s header", h.cfg.UserHeader))
+		return caller, false
+	}
+
+	for _, role := range strings.Split(r.Header.Get(h.cfg.RolesHeader), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			caller.Roles = append(caller.Roles, role)
+		}
+	}
+	return caller, true
+}
+
+// writeServiceError maps invalid templates and parameter values to 400 with
+// the problem of each field, hidden or missing templates to 404, edits by
+// non-owners to 403, stale versions to 409 and timeouts to 504
+func (h *QueryTemplateHTTPHandlers) writeServiceError(w http.ResponseWriter, err error, message string) {
+	var validationErr *querytemplates.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		h.writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":     message,
+			"status":    http.StatusBadRequest,
+			"timestamp": time.Now(),
+			"details":   validationErr.Err.Error(),
+			"fields":    validationErr.Fields,
+		})
+	case errors.Is(err, querytemplates.ErrTemplateNotFound):
+		h.writeError(w, http.StatusNotFound, message, err)
+	case errors.Is(err, querytemplates.ErrNotPermitted):
+		h.writeError(w, http.StatusForbidden, message, err)
+	case errors.Is(err, querytemplates.ErrVersionConflict):
+		h.writeError(w, http.StatusConflict, message, err)
+	case errors.Is(err, querytemplates.ErrExecutionTimeout):
+		h.writeError(w, http.StatusGatewayTimeout, message, err)
+	default:
+		h.logger.Error(message, "error", err)
+		h.writeError(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+// Helper methods
+
+func (h *QueryTemplateHTTPHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *QueryTemplateHTTPHandlers) writeError(w http.ResponseWriter, status int, message string, err error) {
+	errorResponse := map[string]interface{}{
+		"error":     message,
+		"status":    status,
+		"timestamp": time.Now(),
+	}
+
+	if err != nil {
+		errorResponse["details"] = err.Error()
+	}
+
+	h.writeJSON(w, status, errorResponse)
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aegisshield/graph-engine/internal/temporal"
+	"github.com/gorilla/mux"
+)
+
+// TemporalHTTPHandlers contains HTTP handlers for versioned relationships
+type TemporalHTTPHandlers struct {
+	edges  *temporal.Writer
+	logger *slog.Logger
+}
+
+// NewTemporalHTTPHandlers creates new versioned relationship HTTP handlers
+func NewTemporalHTTPHandlers(edges *temporal.Writer, logger *slog.Logger) *TemporalHTTPHandlers {
+	return &TemporalHTTPHandlers{
+		edges:  edges,
+		logger: logger,
+	}
+}
+
+// RegisterTemporalRoutes registers versioned relationship HTTP routes
+func (h *TemporalHTTPHandlers) RegisterTemporalRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/relationships", h.recordRelationship).Methods("POST")
+	router.HandleFunc("/api/v1/relationships/end", h.endRelationship).Methods("POST")
+	router.HandleFunc("/api/v1/relationships/history", h.getRelationshipHistory).Methods("GET")
+}
+
+// recordRelationship sets a relationship's properties from valid_from on,
+// closing its previous version
+func (h *TemporalHTTPHandlers) recordRelationship(w http.ResponseWriter, r *http.Request) {
+	var change temporal.Change
+	if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	result, err := h.edges.Record(r.Context(), change)
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to record relationship")
+		return
+	}
+
+	status := http.StatusOK
+	if result.Outcome == temporal.OutcomeCreated {
+		status = http.StatusCreated
+	}
+	h.writeJSON(w, status, result)
+}
+
+// endRelationship closes a relationship's current version at valid_to, or
+// now when it is omitted
+func (h *TemporalHTTPHandlers) endRelationship(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SourceID string    `json:"source_id"`
+		TargetID string    `json:"target_id"`
+		Type     string    `json:"type"`
+		ValidTo  time.Time `json:"valid_to"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	ended, err := h.edges.End(r.Context(), req.SourceID, req.TargetID, req.Type, req.ValidTo)
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to end relationship")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"source_id": req.SourceID,
+		"target_id": req.TargetID,
+		"type":      req.Type,
+		"ended":     ended,
+	})
+}
+
+// getRelationshipHistory lists every version of the relationships from one
+// entity to another, optionally of one type
+func (h *TemporalHTTPHandlers) getRelationshipHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	versions, err := h.edges.History(r.Context(), query.Get("source_id"), query.Get("target_id"), query.Get("type"))
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to get relationship history")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"versions": versions,
+		"count":    len(versions),
+	})
+}
+
+// writeServiceError maps invalid changes to 400 and missing entities or
+// relationships to 404
+func (h *TemporalHTTPHandlers) writeServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, temporal.ErrInvalidRelationship):
+		h.writeError(w, http.StatusBadRequest, message, err)
+	case errors.Is(err, temporal.ErrEntityNotFound), errors.Is(err, temporal.ErrRelationshipNotFound):
+		h.writeError(w, http.StatusNotFound, message, err)
+	default:
+		h.logger.Error(message, "error", err)
+		h.writeError(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+// withAsOf reads the as_of query parameter, an RFC 3339 time, into the
+// request's context, so graph reads under it see relationships as they were
+// then. Requests without one read the current graph.
+func withAsOf(r *http.Request) (*http.Request, *time.Time, error) {
+	value := r.URL.Query().Get(temporal.AsOfParam)
+	if value == "" {
+		return r, nil, nil
+	}
+
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return r, nil, fmt.Errorf("as_of must be an RFC 3339 time: %w", err)
+	}
+	at = at.UTC()
+	return r.WithContext(temporal.WithAsOf(r.Context(), at)), &at, nil
+}
+
+// Helper methods
+
+func (h *TemporalHTTPHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *TemporalHTTPHandlers) writeError(w http.ResponseWriter, status int, message string, err error) {
+	errorResponse := map[string]interface{}{
+		"error":     message,
+		"status":    status,
+		"timestamp": time.Now(),
+	}
+
+	if err != nil {
+		errorResponse["details"] = err.Error()
+	}
+
+	h.writeJSON(w, status, errorResponse)
+}
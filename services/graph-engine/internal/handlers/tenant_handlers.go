@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aegisshield/graph-engine/internal/tenancy"
+	"github.com/gorilla/mux"
+)
+
+// TenantHTTPHandlers contains HTTP handlers for tenant graph databases. The
+// tenant service provisions a tenant's database when the tenant is created.
+type TenantHTTPHandlers struct {
+	provisioner tenancy.Provisioner
+	logger      *slog.Logger
+}
+
+// NewTenantHTTPHandlers creates new tenant database HTTP handlers
+func NewTenantHTTPHandlers(provisioner tenancy.Provisioner, logger *slog.Logger) *TenantHTTPHandlers {
+	return &TenantHTTPHandlers{
+		provisioner: provisioner,
+		logger:      logger,
+	}
+}
+
+// RegisterTenantRoutes registers tenant database HTTP routes
+func (h *TenantHTTPHandlers) RegisterTenantRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/tenants/databases", h.listDatabases).Methods("GET")
+	router.HandleFunc("/api/v1/tenants/{tenant_id}/database", h.provisionDatabase).Methods("POST")
+	router.HandleFunc("/api/v1/tenants/{tenant_id}/database", h.getDatabase).Methods("GET")
+}
+
+func (h *TenantHTTPHandlers) listDatabases(w http.ResponseWriter, r *http.Request) {
+	databases, err := h.provisioner.TenantDatabases(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list tenant databases", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to list tenant databases", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"databases": databases,
+		"count":     len(databases),
+	})
+}
+
+// provisionDatabase creates the tenant's database; provisioning an existing
+// tenant again returns its database unchanged
+func (h *TenantHTTPHandlers) provisionDatabase(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenant_id"]
+	database, err := h.provisioner.ProvisionTenantDatabase(r.Context(), tenantID)
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to provision tenant database")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, database)
+}
+
+func (h *TenantHTTPHandlers) getDatabase(w http.ResponseWriter, r *http.Request) {
+	database, err := h.provisioner.TenantDatabase(r.Context(), mux.Vars(r)["tenant_id"])
+	if err != nil {
+		h.writeServiceError(w, err, "Failed to get tenant database")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, database)
+}
+
+// writeServiceError maps invalid tenants to 400 and missing databases to 404
+func (h *TenantHTTPHandlers) writeServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, tenancy.ErrInvalidTenant):
+		h.writeError(w, http.StatusBadRequest, message, err)
+	case errors.Is(err, tenancy.ErrTenantNotProvisioned):
+		h.writeError(w, http.StatusNotFound, message, err)
+	default:
+		h.logger.Error(message, "error", err)
+		h.writeError(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+// Helper methods
+
+func (h *TenantHTTPHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *TenantHTTPHandlers) writeError(w http.ResponseWriter, status int, message string, err error) {
+	errorResponse := map[string]interface{}{
+		"error":     message,
+		"status":    status,
+		"timestamp": time.Now(),
+	}
+
+	if err != nil {
+		errorResponse["details"] = err.Error()
+	}
+
+	h.writeJSON(w, status, errorResponse)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aegisshield/graph-engine/internal/thumbnail"
+	"github.com/gorilla/mux"
+)
+
+// ThumbnailHTTPHandlers contains HTTP handlers for network thumbnail rendering
+type ThumbnailHTTPHandlers struct {
+	renderer *thumbnail.Renderer
+	logger   *slog.Logger
+}
+
+// NewThumbnailHTTPHandlers creates new thumbnail HTTP handlers
+func NewThumbnailHTTPHandlers(renderer *thumbnail.Renderer, logger *slog.Logger) *ThumbnailHTTPHandlers {
+	return &ThumbnailHTTPHandlers{
+		renderer: renderer,
+		logger:   logger,
+	}
+}
+
+// RegisterThumbnailRoutes registers thumbnail HTTP routes
+func (h *ThumbnailHTTPHandlers) RegisterThumbnailRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/graph/thumbnail", h.getThumbnail).Methods("GET")
+	router.HandleFunc("/api/v1/graph/thumbnail", h.renderThumbnail).Methods("POST")
+}
+
+// getThumbnail renders a thumbnail from query parameters so it can be used
+// directly as an image source in case lists and reports
+func (h *ThumbnailHTTPHandlers) getThumbnail(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := &thumbnail.Request{Format: query.Get("format")}
+
+	if entityIDs := query.Get("entity_ids"); entityIDs != "" {
+		req.EntityIDs = strings.Split(entityIDs, ",")
+	}
+	if depth, err := strconv.Atoi(query.Get("depth")); err == nil {
+		req.Depth = depth
+	}
+	if maxNodes, err := strconv.Atoi(query.Get("max_nodes")); err == nil {
+		req.MaxNodes = maxNodes
+	}
+	if width, err := strconv.Atoi(query.Get("width")); err == nil {
+		req.Width = width
+	}
+	if height, err := strconv.Atoi(query.Get("height")); err == nil {
+		req.Height = height
+	}
+
+	h.render(w, r, req)
+}
+
+func (h *ThumbnailHTTPHandlers) renderThumbnail(w http.ResponseWriter, r *http.Request) {
+	var req thumbnail.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	h.render(w, r, &req)
+}
+
+func (h *ThumbnailHTTPHandlers) render(w http.ResponseWriter, r *http.Request, req *thumbnail.Request) {
+	if len(req.EntityIDs) == 0 {
+		h.writeError(w, http.StatusBadRequest, "entity_ids is required", nil)
+		return
+	}
+
+	result, err := h.renderer.Render(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, thumbnail.ErrUnsupportedFormat):
+			h.writeError(w, http.StatusBadRequest, "format must be svg or png", err)
+		case errors.Is(err, thumbnail.ErrEmptyGraph):
+			h.writeError(w, http.StatusNotFound, "No entities found", err)
+		default:
+			h.logger.Error("Failed to render graph thumbnail", "error", err)
+			h.writeError(w, http.StatusInternalServerError, "Failed to render graph thumbnail", err)
+		}
+		return
+	}
+
+	etag := result.ETag()
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, max-age=60")
+	w.Header().Set("X-Graph-Version", result.Version)
+	w.Header().Set("X-Graph-Nodes", strconv.Itoa(result.NodeCount))
+	w.Header().Set("X-Graph-Truncated", strconv.FormatBool(result.Truncated))
+
+	if match := r.Header.Get("If-None-Match"); match != "" && match == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", result.ContentType)
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(result.Data)))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(result.Data); err != nil {
+		h.logger.Error("Failed to write thumbnail response", "error", err)
+	}
+}
+
+// Helper methods
+
+func (h *ThumbnailHTTPHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+func (h *ThumbnailHTTPHandlers) writeError(w http.ResponseWriter, status int, message string, err error) {
+	errorResponse := map[string]interface{}{
+		"error":     message,
+		"status":    status,
+		"timestamp": time.Now(),
+	}
+
+	if err != nil {
+		errorResponse["details"] = err.Error()
+	}
+
+	h.writeJSON(w, status, errorResponse)
+}
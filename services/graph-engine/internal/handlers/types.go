@@ -79,7 +79,8 @@ type AnalyzeSubGraphResponse struct {
 
 // FindPathsResponse represents a path finding response
 type FindPathsResponse struct {
-	Paths []*Path `json:"paths"`
+	Paths []*Path    `json:"paths"`
+	AsOf  *time.Time `json:"as_of,omitempty"`
 }
 
 // CalculateMetricsResponse represents a metrics calculation response
@@ -105,8 +106,9 @@ type ListInvestigationsResponse struct {
 
 // GetEntityNeighborhoodResponse represents entity neighborhood response
 type GetEntityNeighborhoodResponse struct {
-	EntityID string    `json:"entity_id"`
-	SubGraph *SubGraph `json:"subgraph"`
+	EntityID string     `json:"entity_id"`
+	SubGraph *SubGraph  `json:"subgraph"`
+	AsOf     *time.Time `json:"as_of,omitempty"`
 }
 
 // ListPatternsResponse represents patterns list response
@@ -10,12 +10,16 @@ import (
 	"github.com/IBM/sarama"
 	"github.com/aegisshield/graph-engine/internal/config"
 	"github.com/aegisshield/graph-engine/internal/engine"
+	"github.com/aegisshield/graph-engine/internal/exposure"
+	"github.com/aegisshield/graph-engine/internal/patterns"
+	"github.com/aegisshield/graph-engine/internal/temporal"
 )
 
 // Consumer handles Kafka message consumption
 type Consumer struct {
 	consumer sarama.ConsumerGroup
 	engine   *engine.GraphEngine
+	edges    *temporal.Writer
 	config   config.Config
 	logger   *slog.Logger
 	topics   []string
@@ -104,6 +108,12 @@ func NewProducer(config config.Config, logger *slog.Logger) (*Producer, error) {
 	}, nil
 }
 
+// SetEdgeWriter records linked entities as versioned relationships, so a
+// link's earlier properties stay readable as of earlier times
+func (c *Consumer) SetEdgeWriter(edges *temporal.Writer) {
+	c.edges = edges
+}
+
 // Start begins consuming messages
 func (c *Consumer) Start() error {
 	c.logger.Info("Starting Kafka consumer",
@@ -239,10 +249,37 @@ func (c *Consumer) handleEntityLinkedEvent(message *sarama.ConsumerMessage) erro
 
 	// Update graph with new relationship
 	ctx := context.Background()
-	if err := c.engine.ProcessEntityLinkedEvent(ctx, &event); err != nil {
-		return fmt.Errorf("failed to process entity linked event: %w", err)
+	if c.edges == nil {
+		if err := c.engine.ProcessEntityLinkedEvent(ctx, &event); err != nil {
+			return fmt.Errorf("failed to process entity linked event: %w", err)
+		}
+		return nil
 	}
 
+	properties := make(map[string]interface{}, len(event.Properties)+1)
+	for key, value := range event.Properties {
+		properties[key] = value
+	}
+	properties["confidence"] = event.Confidence
+
+	result, err := c.edges.Record(ctx, temporal.Change{
+		SourceID:   event.SourceEntityID,
+		TargetID:   event.TargetEntityID,
+		Type:       event.LinkType,
+		Properties: properties,
+		ValidFrom:  event.LinkedAt,
+		RecordedBy: event.LinkedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record entity link: %w", err)
+	}
+
+	c.logger.Debug("Recorded entity link version",
+		"source_id", event.SourceEntityID,
+		"target_id", event.TargetEntityID,
+		"link_type", event.LinkType,
+		"outcome", result.Outcome,
+		"version", result.Version)
 	return nil
 }
 
@@ -316,6 +353,50 @@ func (p *Producer) PublishNetworkMetricsCalculated(ctx context.Context, event *N
 	return p.publishEvent(ctx, p.config.Kafka.Topics.NetworkMetricsCalculated, event)
 }
 
+// PublishExposureChanged publishes an entity's move to another exposure band
+func (p *Producer) PublishExposureChanged(ctx context.Context, event *exposure.ChangeEvent) error {
+	return p.publishEvent(ctx, p.config.Kafka.ExposureTopic, event)
+}
+
+// PublishMotifDetected publishes a money-laundering motif instance found by
+// scheduled detection
+func (p *Producer) PublishMotifDetected(ctx context.Context, pattern *patterns.Pattern) error {
+	entityIDs := make([]string, len(pattern.Entities))
+	for i, entity := range pattern.Entities {
+		entityIDs[i] = entity.ID
+	}
+
+	severity := "low"
+	switch {
+	case pattern.RiskScore >= 90:
+		severity = "critical"
+	case pattern.RiskScore >= 70:
+		severity = "high"
+	case pattern.RiskScore >= 50:
+		severity = "medium"
+	}
+
+	evidence := map[string]interface{}{
+		"risk_score":    pattern.RiskScore,
+		"indicators":    pattern.Indicators,
+		"relationships": pattern.Relationships,
+	}
+	for key, value := range pattern.Metadata {
+		evidence[key] = value
+	}
+
+	return p.publishEvent(ctx, p.config.Kafka.PatternDetectionTopic, &PatternDetectedEvent{
+		PatternID:   pattern.ID,
+		PatternType: string(pattern.Type),
+		EntityIDs:   entityIDs,
+		Confidence:  pattern.Confidence,
+		Severity:    severity,
+		DetectedAt:  pattern.DetectedAt,
+		Evidence:    evidence,
+		Description: pattern.Description,
+	})
+}
+
 // publishEvent publishes an event to Kafka
 func (p *Producer) publishEvent(ctx context.Context, topic string, event interface{}) error {
 	data, err := json.Marshal(event)
@@ -0,0 +1,166 @@
+package loadshed
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+)
+
+var (
+	// ErrQueueFull is returned when a class is at its limit with a full queue
+	ErrQueueFull = errors.New("concurrency limit reached and queue full")
+	// ErrQueueTimeout is returned when a queued request was not admitted
+	// within the class's queue timeout
+	ErrQueueTimeout = errors.New("timed out waiting for a concurrency slot")
+)
+
+// backoffRatio is how much of its limit a class keeps after requests run
+// slower than the target latency
+const backoffRatio = 0.9
+
+// waiter is a queued request; ready is closed once it is admitted
+type waiter struct {
+	ready    chan struct{}
+	admitted bool
+}
+
+// Stats is the state of one class's limiter
+type Stats struct {
+	Class      string  `json:"class"`
+	Limit      int     `json:"limit"`
+	InFlight   int     `json:"in_flight"`
+	Queued     int     `json:"queued"`
+	Saturation float64 `json:"saturation"` // (in flight + queued) / limit
+}
+
+// Limiter bounds the requests of one route class served at once. The limit
+// adapts by additive increase and multiplicative decrease: each request
+// completing within the target latency while the class was at its limit
+// raises it by 1/limit, and requests slower than the target cut it by a
+// tenth, at most once per target latency so a burst of slow completions
+// counts as one signal.
+type Limiter struct {
+	cfg config.RouteClassConfig
+
+	mu          sync.Mutex
+	limit       float64
+	inFlight    int
+	queue       []*waiter
+	lastBackoff time.Time
+}
+
+// NewLimiter creates a limiter starting at the class's maximum limit
+func NewLimiter(cfg config.RouteClassConfig) *Limiter {
+	return &Limiter{
+		cfg:   cfg,
+		limit: float64(cfg.MaxLimit),
+	}
+}
+
+// Name returns the route class the limiter bounds
+func (l *Limiter) Name() string {
+	return l.cfg.Name
+}
+
+// Acquire admits a request, queueing it while the class is at its limit.
+// Every successful Acquire must be followed by a Release.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if l.inFlight < l.currentLimit() && len(l.queue) == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		return nil
+	}
+	if len(l.queue) >= l.cfg.MaxQueue {
+		l.mu.Unlock()
+		return ErrQueueFull
+	}
+	w := &waiter{ready: make(chan struct{})}
+	l.queue = append(l.queue, w)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.cfg.QueueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-w.ready:
+		return nil
+	case <-timer.C:
+		err = ErrQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.admitted {
+		// Admitted as the wait ended; hand the slot on
+		l.inFlight--
+		l.admitLocked()
+		return err
+	}
+	for i, queued := range l.queue {
+		if queued == w {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+			break
+		}
+	}
+	return err
+}
+
+// Release frees a request's slot and adapts the limit to how long the
+// request took once admitted
+func (l *Limiter) Release(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	saturated := l.inFlight >= l.currentLimit()
+	l.inFlight--
+
+	switch {
+	case latency > l.cfg.TargetLatency:
+		if now := time.Now(); now.Sub(l.lastBackoff) >= l.cfg.TargetLatency {
+			l.limit = math.Max(float64(l.cfg.MinLimit), l.limit*backoffRatio)
+			l.lastBackoff = now
+		}
+	case saturated:
+		l.limit = math.Min(float64(l.cfg.MaxLimit), l.limit+1/l.limit)
+	}
+
+	l.admitLocked()
+}
+
+// Stats returns the limiter's current state
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit := l.currentLimit()
+	return Stats{
+		Class:      l.cfg.Name,
+		Limit:      limit,
+		InFlight:   l.inFlight,
+		Queued:     len(l.queue),
+		Saturation: float64(l.inFlight+len(l.queue)) / float64(limit),
+	}
+}
+
+// admitLocked admits queued requests, oldest first, while there is room
+func (l *Limiter) admitLocked() {
+	for len(l.queue) > 0 && l.inFlight < l.currentLimit() {
+		w := l.queue[0]
+		l.queue = l.queue[1:]
+		w.admitted = true
+		l.inFlight++
+		close(w.ready)
+	}
+}
+
+func (l *Limiter) currentLimit() int {
+	return int(l.limit)
+}
@@ -0,0 +1,191 @@
+package loadshed
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+)
+
+// exemptPaths are never limited: probes and metrics must answer while the
+// service is saturated, or it would be restarted rather than scaled out
+var exemptPaths = []string{"/health", "/ready", "/metrics"}
+
+var (
+	concurrencyLimit = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "graph_engine_http_concurrency_limit",
+		Help: "Current adaptive concurrency limit of a route class",
+	}, []string{"class"})
+	inFlightRequests = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "graph_engine_http_concurrency_in_flight",
+		Help: "Requests of a route class being served",
+	}, []string{"class"})
+	queuedRequests = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "graph_engine_http_concurrency_queued",
+		Help: "Requests of a route class waiting for a slot",
+	}, []string{"class"})
+	saturation = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "graph_engine_http_saturation",
+		Help: "Requests in flight and queued over the concurrency limit of a route class; above 1 requests are queueing",
+	}, []string{"class"})
+	shedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "graph_engine_http_shed_requests_total",
+		Help: "Requests rejected with 503 because a route class was saturated",
+	}, []string{"class", "reason"})
+	queueWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "graph_engine_http_queue_wait_seconds",
+		Help:    "Time requests waited for a concurrency slot, whether admitted or shed",
+		Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}, []string{"class"})
+)
+
+// Shedder limits concurrent HTTP requests per route class and sheds those
+// that cannot be admitted in time
+type Shedder struct {
+	prefixes   []classPrefix // longest first
+	fallback   *Limiter
+	limiters   []*Limiter
+	retryAfter time.Duration
+	logger     *slog.Logger
+}
+
+// classPrefix routes requests under a path prefix to a class's limiter
+type classPrefix struct {
+	prefix  string
+	limiter *Limiter
+}
+
+// NewShedder creates a limiter for each configured route class
+func NewShedder(cfg config.LoadSheddingConfig, logger *slog.Logger) *Shedder {
+	s := &Shedder{
+		fallback:   NewLimiter(cfg.Default),
+		retryAfter: cfg.RetryAfter,
+		logger:     logger,
+	}
+	s.limiters = append(s.limiters, s.fallback)
+
+	for _, class := range cfg.Classes {
+		limiter := NewLimiter(class)
+		s.limiters = append(s.limiters, limiter)
+		for _, prefix := range class.PathPrefixes {
+			s.prefixes = append(s.prefixes, classPrefix{prefix: strings.TrimSuffix(prefix, "/"), limiter: limiter})
+		}
+	}
+	sort.SliceStable(s.prefixes, func(i, j int) bool {
+		return len(s.prefixes[i].prefix) > len(s.prefixes[j].prefix)
+	})
+
+	for _, limiter := range s.limiters {
+		s.publish(limiter)
+	}
+	return s
+}
+
+// Middleware admits each request through its route class's limiter,
+// responding 503 with Retry-After when the class is saturated
+func (s *Shedder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isExempt(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limiter := s.classify(r.URL.Path)
+		queued := time.Now()
+		err := limiter.Acquire(r.Context())
+		queueWait.WithLabelValues(limiter.Name()).Observe(time.Since(queued).Seconds())
+		if err != nil {
+			s.publish(limiter)
+			s.shed(w, r, limiter, err)
+			return
+		}
+		s.publish(limiter)
+
+		admitted := time.Now()
+		defer func() {
+			limiter.Release(time.Since(admitted))
+			s.publish(limiter)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Stats returns the state of every route class's limiter
+func (s *Shedder) Stats() []Stats {
+	stats := make([]Stats, 0, len(s.limiters))
+	for _, limiter := range s.limiters {
+		stats = append(stats, limiter.Stats())
+	}
+	return stats
+}
+
+// classify returns the limiter of the class with the longest prefix
+// matching the path
+func (s *Shedder) classify(path string) *Limiter {
+	for _, p := range s.prefixes {
+		if path == p.prefix || strings.HasPrefix(path, p.prefix+"/") {
+			return p.limiter
+		}
+	}
+	return s.fallback
+}
+
+func (s *Shedder) shed(w http.ResponseWriter, r *http.Request, limiter *Limiter, err error) {
+	reason := "queue_full"
+	switch {
+	case errors.Is(err, ErrQueueTimeout):
+		reason = "queue_timeout"
+	case r.Context().Err() != nil:
+		// The client went away while queued; there is no one to answer
+		shedRequests.WithLabelValues(limiter.Name(), "canceled").Inc()
+		return
+	}
+	shedRequests.WithLabelValues(limiter.Name(), reason).Inc()
+
+	s.logger.Warn("Shedding request",
+		"class", limiter.Name(),
+		"reason", reason,
+		"method", r.Method,
+		"path", r.URL.Path)
+
+	retryAfter := int(math.Ceil(s.retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":       "server is saturated, retry later",
+		"class":       limiter.Name(),
+		"reason":      reason,
+		"retry_after": retryAfter,
+		"status":      http.StatusServiceUnavailable,
+		"timestamp":   time.Now(),
+	})
+}
+
+// publish exports a limiter's state for autoscaling
+func (s *Shedder) publish(limiter *Limiter) {
+	stats := limiter.Stats()
+	concurrencyLimit.WithLabelValues(stats.Class).Set(float64(stats.Limit))
+	inFlightRequests.WithLabelValues(stats.Class).Set(float64(stats.InFlight))
+	queuedRequests.WithLabelValues(stats.Class).Set(float64(stats.Queued))
+	saturation.WithLabelValues(stats.Class).Set(stats.Saturation)
+}
+
+func isExempt(path string) bool {
+	for _, exempt := range exemptPaths {
+		if path == exempt || strings.HasPrefix(path, exempt+"/") {
+			return true
+		}
+	}
+	return false
+}
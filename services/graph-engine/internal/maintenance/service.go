@@ -0,0 +1,594 @@
+package maintenance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegisshield/graph-engine/internal/backup"
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/tenancy"
+)
+
+// Maintenance jobs
+const (
+	// JobOrphans deletes nodes of the configured labels that have no
+	// relationships and have not changed for the minimum orphan age
+	JobOrphans = "orphan_cleanup"
+	// JobDuplicates collapses parallel relationships of the same type and
+	// identical properties into one
+	JobDuplicates = "duplicate_relationships"
+	// JobRetention archives and deletes relationships whose last change is
+	// older than their type's retention window
+	JobRetention = "relationship_retention"
+)
+
+// Jobs lists every maintenance job in the order a run executes them.
+// Retention runs before orphan cleanup so nodes it leaves unconnected are
+// not pruned until they reach the orphan age.
+var Jobs = []string{JobRetention, JobDuplicates, JobOrphans}
+
+// Run triggers
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+var (
+	// ErrRunInProgress is returned when a run is requested while another is running
+	ErrRunInProgress = errors.New("maintenance run already in progress")
+	// ErrRunNotFound is returned for runs not in the history
+	ErrRunNotFound = errors.New("maintenance run not found")
+	// ErrUnknownJob is returned for job names that are not maintenance jobs
+	ErrUnknownJob = errors.New("unknown maintenance job")
+)
+
+var (
+	changesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "graph_engine_maintenance_changes_total",
+		Help: "Nodes and relationships removed by graph maintenance jobs",
+	}, []string{"job"})
+	pendingChanges = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "graph_engine_maintenance_pending_changes",
+		Help: "Nodes and relationships the last dry run found to remove",
+	}, []string{"job"})
+	jobFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "graph_engine_maintenance_job_failures_total",
+		Help: "Graph maintenance jobs that failed",
+	}, []string{"job"})
+)
+
+// QueryRunner runs Cypher against the graph
+type QueryRunner interface {
+	ExecuteQuery(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error)
+}
+
+// RunOptions selects what a run does
+type RunOptions struct {
+	DryRun  bool     `json:"dry_run"`
+	Jobs    []string `json:"jobs,omitempty"` // empty runs every job
+	Trigger string   `json:"trigger"`
+}
+
+// JobResult reports what one job found and changed in one database
+type JobResult struct {
+	Job      string         `json:"job"`
+	TenantID string         `json:"tenant_id,omitempty"` // empty for the shared database
+	Matched  int            `json:"matched"`             // nodes or relationships eligible for removal
+	Changed  int            `json:"changed"`             // removed; always 0 in a dry run
+	ByType   map[string]int `json:"by_type,omitempty"`   // matched per node label or relationship type
+	Archives []string       `json:"archives,omitempty"`  // archive objects written before deleting
+	Error    string         `json:"error,omitempty"`
+}
+
+// Run is one execution of the maintenance jobs
+type Run struct {
+	ID          string         `json:"id"`
+	Trigger     string         `json:"trigger"`
+	DryRun      bool           `json:"dry_run"`
+	StartedAt   time.Time      `json:"started_at"`
+	CompletedAt time.Time      `json:"completed_at"`
+	Results     []*JobResult   `json:"results"`
+	Matched     map[string]int `json:"matched"` // per job, across databases
+	Changed     map[string]int `json:"changed"` // per job, across databases
+	Failed      bool           `json:"failed"`
+}
+
+// Service runs graph maintenance jobs on a schedule or on demand and keeps
+// the reports of recent runs
+type Service struct {
+	graph   QueryRunner
+	archive backup.Store
+	cfg     config.MaintenanceConfig
+	tenants tenancy.Inspector
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu      sync.Mutex
+	running bool
+	history []*Run // oldest first
+}
+
+// NewService creates a maintenance service. Relationships removed by the
+// retention job are archived to the store first.
+func NewService(graph QueryRunner, archive backup.Store, cfg config.MaintenanceConfig, logger *slog.Logger) *Service {
+	return &Service{
+		graph:   graph,
+		archive: archive,
+		cfg:     cfg,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// SetTenants makes runs without a tenant also maintain every online tenant
+// database
+func (s *Service) SetTenants(inspector tenancy.Inspector) {
+	s.tenants = inspector
+}
+
+// Start runs every job each interval until the context is cancelled, in
+// dry-run mode when configured
+func (s *Service) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Run(ctx, RunOptions{DryRun: s.cfg.DryRun, Trigger: TriggerSchedule}); err != nil {
+				s.logger.Error("Scheduled graph maintenance failed", "error", err)
+			}
+		}
+	}
+}
+
+// Run executes the selected jobs. A context naming a tenant maintains only
+// that tenant's database; otherwise the shared database and every online
+// tenant database are maintained. A failing job is reported in the run and
+// does not stop the others.
+func (s *Service) Run(ctx context.Context, opts RunOptions) (*Run, error) {
+	jobs, err := selectJobs(opts.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, ErrRunInProgress
+	}
+	s.running = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	trigger := opts.Trigger
+	if trigger == "" {
+		trigger = TriggerManual
+	}
+	run := &Run{
+		ID:        uuid.New().String(),
+		Trigger:   trigger,
+		DryRun:    opts.DryRun,
+		StartedAt: s.now().UTC(),
+		Matched:   make(map[string]int),
+		Changed:   make(map[string]int),
+	}
+
+	databases, err := s.databases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, dbCtx := range databases {
+		tenantID, _ := tenancy.FromContext(dbCtx)
+		for _, job := range jobs {
+			result := s.runJob(dbCtx, run, job, opts.DryRun)
+			result.TenantID = tenantID
+			run.Results = append(run.Results, result)
+			run.Matched[job] += result.Matched
+			run.Changed[job] += result.Changed
+			if result.Error != "" {
+				run.Failed = true
+				jobFailures.WithLabelValues(job).Inc()
+			}
+		}
+	}
+	run.CompletedAt = s.now().UTC()
+
+	for _, job := range jobs {
+		if opts.DryRun {
+			pendingChanges.WithLabelValues(job).Set(float64(run.Matched[job]))
+		} else {
+			changesTotal.WithLabelValues(job).Add(float64(run.Changed[job]))
+		}
+	}
+
+	s.record(run)
+	s.logger.Info("Graph maintenance completed",
+		"run_id", run.ID,
+		"trigger", run.Trigger,
+		"dry_run", run.DryRun,
+		"matched", run.Matched,
+		"changed", run.Changed,
+		"failed", run.Failed,
+		"duration", run.CompletedAt.Sub(run.StartedAt))
+
+	return run, nil
+}
+
+// Runs returns the recent runs, newest first
+func (s *Service) Runs() []*Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := make([]*Run, 0, len(s.history))
+	for i := len(s.history) - 1; i >= 0; i-- {
+		runs = append(runs, s.history[i])
+	}
+	return runs
+}
+
+// GetRun returns a recent run
+func (s *Service) GetRun(id string) (*Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, run := range s.history {
+		if run.ID == id {
+			return run, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+}
+
+func (s *Service) record(run *Run) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.history = append(s.history, run)
+	if excess := len(s.history) - s.cfg.HistorySize; excess > 0 {
+		s.history = s.history[excess:]
+	}
+}
+
+// databases returns a context for every database a run maintains
+func (s *Service) databases(ctx context.Context) ([]context.Context, error) {
+	if _, ok := tenancy.FromContext(ctx); ok || s.tenants == nil {
+		return []context.Context{ctx}, nil
+	}
+
+	databases, err := s.tenants.TenantDatabases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contexts := []context.Context{ctx}
+	for _, db := range databases {
+		if db.Online {
+			contexts = append(contexts, tenancy.WithTenant(ctx, db.TenantID))
+		}
+	}
+	return contexts, nil
+}
+
+func (s *Service) runJob(ctx context.Context, run *Run, job string, dryRun bool) *JobResult {
+	result := &JobResult{Job: job, ByType: make(map[string]int)}
+
+	var err error
+	switch job {
+	case JobOrphans:
+		err = s.pruneOrphans(ctx, result, dryRun)
+	case JobDuplicates:
+		err = s.collapseDuplicates(ctx, result, dryRun)
+	case JobRetention:
+		err = s.archiveExpired(ctx, run, result, dryRun)
+	}
+
+	// A job that is tenant-scoped in a deployment requiring tenants has no
+	// shared database to maintain
+	if errors.Is(err, tenancy.ErrTenantRequired) {
+		return result
+	}
+	if err != nil {
+		result.Error = err.Error()
+		s.logger.Error("Graph maintenance job failed", "run_id", run.ID, "job", job, "error", err)
+	}
+	return result
+}
+
+// orphanFilter matches nodes of the configured labels with no relationships
+// that have not changed since the cutoff. Nodes without timestamps are
+// treated as old.
+const orphanFilter = `
+	MATCH (n)
+	WHERE any(label IN labels(n) WHERE label IN $labels)
+	  AND NOT (n)--()
+	  AND (coalesce(n.updated_at, n.created_at) IS NULL
+	       OR datetime(toString(coalesce(n.updated_at, n.created_at))) < datetime($cutoff))
+	WITH n, head([label IN labels(n) WHERE label IN $labels]) AS type`
+
+func (s *Service) pruneOrphans(ctx context.Context, result *JobResult, dryRun bool) error {
+	if len(s.cfg.OrphanLabels) == 0 {
+		return nil
+	}
+	params := map[string]interface{}{
+		"labels": s.cfg.OrphanLabels,
+		"cutoff": s.now().Add(-s.cfg.OrphanMinAge).UTC().Format(time.RFC3339Nano),
+		"limit":  s.cfg.BatchSize,
+	}
+
+	if err := s.count(ctx, result, orphanFilter+`
+		RETURN type, count(n) AS count`, params); err != nil {
+		return fmt.Errorf("failed to count orphan nodes: %w", err)
+	}
+	if dryRun {
+		return nil
+	}
+
+	return s.deleteBatches(ctx, result, orphanFilter+`
+		LIMIT $limit
+		DELETE n
+		RETURN type, count(*) AS count`, params)
+}
+
+// duplicateGroups groups parallel relationships by type and properties;
+// rels[0] of each group is the one kept
+const duplicateGroups = `
+	MATCH (a)-[r]->(b)
+	WHERE size($types) = 0 OR type(r) IN $types
+	WITH a, b, type(r) AS type, properties(r) AS props, r
+	ORDER BY elementId(r)
+	WITH a, b, type, props, collect(r) AS rels
+	WHERE size(rels) > 1`
+
+func (s *Service) collapseDuplicates(ctx context.Context, result *JobResult, dryRun bool) error {
+	types := s.cfg.DuplicateTypes
+	if types == nil {
+		types = []string{}
+	}
+	params := map[string]interface{}{
+		"types": types,
+		"limit": s.cfg.BatchSize,
+	}
+
+	if err := s.count(ctx, result, duplicateGroups+`
+		RETURN type, sum(size(rels) - 1) AS count`, params); err != nil {
+		return fmt.Errorf("failed to count duplicate relationships: %w", err)
+	}
+	if dryRun {
+		return nil
+	}
+
+	return s.deleteBatches(ctx, result, duplicateGroups+`
+		WITH type, rels[1..] AS extra
+		LIMIT $limit
+		UNWIND extra AS r
+		DELETE r
+		RETURN type, count(*) AS count`, params)
+}
+
+// expiredFilter matches relationships whose last change, when they stopped
+// being valid or were last updated or created, is before their type's
+// cutoff. Types are compared in lower case since configuration keys are.
+const expiredFilter = `
+	UNWIND $rules AS rule
+	MATCH (a)-[r]->(b)
+	WHERE toLower(type(r)) = rule.type
+	  AND coalesce(r.valid_to, r.updated_at, r.created_at) IS NOT NULL
+	  AND datetime(toString(coalesce(r.valid_to, r.updated_at, r.created_at))) < datetime(rule.cutoff)`
+
+func (s *Service) archiveExpired(ctx context.Context, run *Run, result *JobResult, dryRun bool) error {
+	if len(s.cfg.Retention) == 0 {
+		return nil
+	}
+
+	now := s.now()
+	types := make([]string, 0, len(s.cfg.Retention))
+	for relType := range s.cfg.Retention {
+		types = append(types, relType)
+	}
+	sort.Strings(types)
+
+	rules := make([]map[string]interface{}, 0, len(types))
+	for _, relType := range types {
+		rules = append(rules, map[string]interface{}{
+			"type":   strings.ToLower(relType),
+			"cutoff": now.Add(-s.cfg.Retention[relType]).UTC().Format(time.RFC3339Nano),
+		})
+	}
+	params := map[string]interface{}{"rules": rules, "limit": s.cfg.BatchSize}
+
+	if err := s.count(ctx, result, expiredFilter+`
+		RETURN type(r) AS type, count(r) AS count`, params); err != nil {
+		return fmt.Errorf("failed to count expired relationships: %w", err)
+	}
+	if dryRun {
+		return nil
+	}
+
+	for batch := 0; batch < s.cfg.MaxBatches; batch++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rows, err := s.graph.ExecuteQuery(ctx, expiredFilter+`
+			WITH r, a, b LIMIT $limit
+			RETURN elementId(r) AS element_id, coalesce(r.id, elementId(r)) AS key, type(r) AS type,
+				   coalesce(a.id, elementId(a)) AS source, coalesce(b.id, elementId(b)) AS target,
+				   properties(r) AS properties`, params)
+		if err != nil {
+			return fmt.Errorf("failed to read expired relationships: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		key, ids, err := s.writeArchive(ctx, run, batch, rows)
+		if err != nil {
+			return err
+		}
+		result.Archives = append(result.Archives, key)
+
+		deleted, err := s.graph.ExecuteQuery(ctx, `
+			MATCH ()-[r]->()
+			WHERE elementId(r) IN $ids
+			DELETE r
+			RETURN count(*) AS count`, map[string]interface{}{"ids": ids})
+		if err != nil {
+			return fmt.Errorf("failed to delete expired relationships: %w", err)
+		}
+		if len(deleted) > 0 {
+			result.Changed += toInt(deleted[0]["count"])
+		}
+
+		if len(rows) < s.cfg.BatchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+// writeArchive stores a batch of expired relationships in the snapshot data
+// format and returns its key and the element IDs it holds
+func (s *Service) writeArchive(ctx context.Context, run *Run, batch int, rows []map[string]interface{}) (string, []string, error) {
+	records := make([]*backup.Record, 0, len(rows))
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		properties, err := backup.EncodeProperties(toMap(row["properties"]))
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to archive relationship %s: %w", toString(row["key"]), err)
+		}
+		records = append(records, &backup.Record{
+			Kind:       backup.RecordRelationship,
+			Key:        toString(row["key"]),
+			Type:       toString(row["type"]),
+			Source:     toString(row["source"]),
+			Target:     toString(row["target"]),
+			Properties: properties,
+		})
+		ids = append(ids, toString(row["element_id"]))
+	}
+
+	var body bytes.Buffer
+	if _, _, err := backup.WriteData(&body, records); err != nil {
+		return "", nil, fmt.Errorf("failed to encode relationship archive: %w", err)
+	}
+
+	database := "shared"
+	if tenantID, ok := tenancy.FromContext(ctx); ok {
+		database = tenantID
+	}
+	key := path.Join(s.cfg.ArchivePrefix, run.ID, fmt.Sprintf("%s-%04d.jsonl.gz", database, batch))
+	if err := s.archive.Put(ctx, key, &body); err != nil {
+		return "", nil, fmt.Errorf("failed to store relationship archive: %w", err)
+	}
+	return key, ids, nil
+}
+
+// count adds the per-type counts returned by query to the result's matches
+func (s *Service) count(ctx context.Context, result *JobResult, query string, params map[string]interface{}) error {
+	rows, err := s.graph.ExecuteQuery(ctx, query, params)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		n := toInt(row["count"])
+		if n == 0 {
+			continue
+		}
+		result.ByType[toString(row["type"])] += n
+		result.Matched += n
+	}
+	return nil
+}
+
+// deleteBatches runs a batched delete until it removes nothing or the batch
+// limit is reached
+func (s *Service) deleteBatches(ctx context.Context, result *JobResult, query string, params map[string]interface{}) error {
+	for batch := 0; batch < s.cfg.MaxBatches; batch++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rows, err := s.graph.ExecuteQuery(ctx, query, params)
+		if err != nil {
+			return err
+		}
+		deleted := 0
+		for _, row := range rows {
+			deleted += toInt(row["count"])
+		}
+		result.Changed += deleted
+		if deleted == 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+func selectJobs(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return Jobs, nil
+	}
+
+	wanted := make(map[string]bool, len(requested))
+	for _, job := range requested {
+		wanted[job] = true
+	}
+	jobs := make([]string, 0, len(requested))
+	for _, job := range Jobs {
+		if wanted[job] {
+			jobs = append(jobs, job)
+			delete(wanted, job)
+		}
+	}
+	for job := range wanted {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJob, job)
+	}
+	return jobs, nil
+}
+
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int64:
+		return int(v)
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+func toString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func toMap(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
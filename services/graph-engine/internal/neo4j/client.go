@@ -4,17 +4,24 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/temporal"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
-// Client wraps Neo4j driver for graph analysis operations
+// Client wraps Neo4j driver for graph analysis operations. With tenancy
+// enabled, queries run in the database of the tenant named by their context.
 type Client struct {
-	driver neo4j.DriverWithContext
-	logger *slog.Logger
-	config config.Neo4jConfig
+	driver  neo4j.DriverWithContext
+	logger  *slog.Logger
+	config  config.Neo4jConfig
+	tenancy config.TenancyConfig
+
+	mu          sync.RWMutex
+	provisioned map[string]bool // tenant databases known to exist
 }
 
 // Entity represents an entity node in the graph
@@ -94,9 +101,10 @@ func NewClient(cfg config.Neo4jConfig, logger *slog.Logger) (*Client, error) {
 	}
 
 	client := &Client{
-		driver: driver,
-		logger: logger,
-		config: cfg,
+		driver:      driver,
+		logger:      logger,
+		config:      cfg,
+		provisioned: make(map[string]bool),
 	}
 
 	// Verify connectivity
@@ -104,6 +112,7 @@ func NewClient(cfg config.Neo4jConfig, logger *slog.Logger) (*Client, error) {
 	defer cancel()
 
 	if err := client.VerifyConnectivity(ctx); err != nil {
+		driver.Close(context.Background())
 		return nil, fmt.Errorf("failed to verify Neo4j connectivity: %w", err)
 	}
 
@@ -122,11 +131,13 @@ func (c *Client) VerifyConnectivity(ctx context.Context) error {
 	return c.driver.VerifyConnectivity(ctx)
 }
 
-// GetSubGraph retrieves a subgraph around specified entities
+// GetSubGraph retrieves a subgraph around specified entities, with the
+// relationship versions valid at the context's as-of time
 func (c *Client) GetSubGraph(ctx context.Context, entityIDs []string, depth int) (*SubGraph, error) {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
-		DatabaseName: c.config.Database,
-	})
+	session, err := c.newSession(ctx)
+	if err != nil {
+		return nil, err
+	}
 	defer session.Close(ctx)
 
 	query := `
@@ -151,17 +162,19 @@ func (c *Client) GetSubGraph(ctx context.Context, entityIDs []string, depth int)
 
 		var entities []*Entity
 		var relationships []*Relationship
+		var nodes []neo4j.Node
 
 		for result.Next(ctx) {
 			record := result.Record()
 			
 			// Process nodes
-			if nodes, ok := record.Get("nodes"); ok {
-				nodeList := nodes.([]interface{})
+			if nodeValues, ok := record.Get("nodes"); ok {
+				nodeList := nodeValues.([]interface{})
 				for _, nodeInterface := range nodeList {
 					node := nodeInterface.(neo4j.Node)
 					entity := c.nodeToEntity(node)
 					entities = append(entities, entity)
+					nodes = append(nodes, node)
 				}
 			}
 
@@ -170,11 +183,15 @@ func (c *Client) GetSubGraph(ctx context.Context, entityIDs []string, depth int)
 				relList := rels.([]interface{})
 				for _, relInterface := range relList {
 					rel := relInterface.(neo4j.Relationship)
+					if !temporal.Valid(ctx, rel.Props) {
+						continue
+					}
 					relationship := c.relationshipToEdge(rel)
 					relationships = append(relationships, relationship)
 				}
 			}
 		}
+		resolveEndpoints(nodes, relationships)
 
 		return &SubGraph{
 			Entities:      entities,
@@ -194,27 +211,30 @@ func (c *Client) GetSubGraph(ctx context.Context, entityIDs []string, depth int)
 	return result.(*SubGraph), nil
 }
 
-// FindShortestPaths finds shortest paths between two sets of entities
+// FindShortestPaths finds shortest paths between two sets of entities over
+// the relationship versions valid at the context's as-of time
 func (c *Client) FindShortestPaths(ctx context.Context, sourceIDs, targetIDs []string, maxLength int) ([]*Path, error) {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
-		DatabaseName: c.config.Database,
-	})
+	session, err := c.newSession(ctx)
+	if err != nil {
+		return nil, err
+	}
 	defer session.Close(ctx)
 
 	query := `
 		MATCH (source:Entity), (target:Entity)
 		WHERE source.id IN $source_ids AND target.id IN $target_ids
 		MATCH path = shortestPath((source)-[*1..` + fmt.Sprintf("%d", maxLength) + `]-(target))
+		WHERE ` + temporal.Path(ctx, "path") + `
 		RETURN path, length(path) as pathLength
 		ORDER BY pathLength
 		LIMIT 10
 	`
 
 	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
-		result, err := tx.Run(ctx, query, map[string]interface{}{
+		result, err := tx.Run(ctx, query, temporal.Bind(ctx, map[string]interface{}{
 			"source_ids": sourceIDs,
 			"target_ids": targetIDs,
-		})
+		}))
 		if err != nil {
 			return nil, err
 		}
@@ -241,9 +261,10 @@ func (c *Client) FindShortestPaths(ctx context.Context, sourceIDs, targetIDs []s
 
 // CalculateCentralityMetrics calculates centrality metrics for entities
 func (c *Client) CalculateCentralityMetrics(ctx context.Context, entityIDs []string) ([]*CentralityMetrics, error) {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
-		DatabaseName: c.config.Database,
-	})
+	session, err := c.newSession(ctx)
+	if err != nil {
+		return nil, err
+	}
 	defer session.Close(ctx)
 
 	// Calculate degree centrality
@@ -306,9 +327,10 @@ func (c *Client) CalculateCentralityMetrics(ctx context.Context, entityIDs []str
 
 // DetectCommunities detects communities/clusters in the graph
 func (c *Client) DetectCommunities(ctx context.Context, entityIDs []string) ([]*Community, error) {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
-		DatabaseName: c.config.Database,
-	})
+	session, err := c.newSession(ctx)
+	if err != nil {
+		return nil, err
+	}
 	defer session.Close(ctx)
 
 	// Simplified community detection using connected components
@@ -367,11 +389,13 @@ func (c *Client) DetectCommunities(ctx context.Context, entityIDs []string) ([]*
 	return result.([]*Community), nil
 }
 
-// FindPatterns finds specific patterns in the graph
+// FindPatterns finds specific patterns in the graph as of the context's
+// as-of time
 func (c *Client) FindPatterns(ctx context.Context, patternType string, entityIDs []string) ([]*PatternMatch, error) {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
-		DatabaseName: c.config.Database,
-	})
+	session, err := c.newSession(ctx)
+	if err != nil {
+		return nil, err
+	}
 	defer session.Close(ctx)
 
 	var query string
@@ -379,14 +403,16 @@ func (c *Client) FindPatterns(ctx context.Context, patternType string, entityIDs
 	case "triangle":
 		query = `
 			MATCH (a:Entity)-[r1]-(b:Entity)-[r2]-(c:Entity)-[r3]-(a)
-			WHERE a.id IN $entity_ids OR b.id IN $entity_ids OR c.id IN $entity_ids
+			WHERE (a.id IN $entity_ids OR b.id IN $entity_ids OR c.id IN $entity_ids)
+			AND ` + temporal.Relationship(ctx, "r1") + ` AND ` + temporal.Relationship(ctx, "r2") + `
+			AND ` + temporal.Relationship(ctx, "r3") + `
 			RETURN a, b, c, r1, r2, r3
 			LIMIT 50
 		`
 	case "star":
 		query = `
 			MATCH (center:Entity)-[r]-(leaf:Entity)
-			WHERE center.id IN $entity_ids
+			WHERE center.id IN $entity_ids AND ` + temporal.Relationship(ctx, "r") + `
 			WITH center, collect(leaf) as leaves, collect(r) as relationships
 			WHERE size(leaves) >= 3
 			RETURN center, leaves, relationships
@@ -395,7 +421,7 @@ func (c *Client) FindPatterns(ctx context.Context, patternType string, entityIDs
 	case "chain":
 		query = `
 			MATCH path = (a:Entity)-[*3..5]-(b:Entity)
-			WHERE a.id IN $entity_ids AND b.id IN $entity_ids
+			WHERE a.id IN $entity_ids AND b.id IN $entity_ids AND ` + temporal.Path(ctx, "path") + `
 			RETURN path
 			LIMIT 50
 		`
@@ -404,9 +430,9 @@ func (c *Client) FindPatterns(ctx context.Context, patternType string, entityIDs
 	}
 
 	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
-		result, err := tx.Run(ctx, query, map[string]interface{}{
+		result, err := tx.Run(ctx, query, temporal.Bind(ctx, map[string]interface{}{
 			"entity_ids": entityIDs,
-		})
+		}))
 		if err != nil {
 			return nil, err
 		}
@@ -456,11 +482,13 @@ func (c *Client) FindPatterns(ctx context.Context, patternType string, entityIDs
 	return result.([]*PatternMatch), nil
 }
 
-// GetEntityNeighborhood gets immediate neighbors of an entity
+// GetEntityNeighborhood gets immediate neighbors of an entity over the
+// relationship versions valid at the context's as-of time
 func (c *Client) GetEntityNeighborhood(ctx context.Context, entityID string, relationshipTypes []string) (*SubGraph, error) {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
-		DatabaseName: c.config.Database,
-	})
+	session, err := c.newSession(ctx)
+	if err != nil {
+		return nil, err
+	}
 	defer session.Close(ctx)
 
 	var typeFilter string
@@ -473,13 +501,14 @@ func (c *Client) GetEntityNeighborhood(ctx context.Context, entityID string, rel
 
 	query := `
 		MATCH (center:Entity {id: $entity_id})-[r` + typeFilter + `]-(neighbor:Entity)
+		WHERE ` + temporal.Relationship(ctx, "r") + `
 		RETURN center, collect(DISTINCT neighbor) as neighbors, collect(DISTINCT r) as relationships
 	`
 
 	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
-		result, err := tx.Run(ctx, query, map[string]interface{}{
+		result, err := tx.Run(ctx, query, temporal.Bind(ctx, map[string]interface{}{
 			"entity_id": entityID,
-		})
+		}))
 		if err != nil {
 			return nil, err
 		}
@@ -489,10 +518,12 @@ func (c *Client) GetEntityNeighborhood(ctx context.Context, entityID string, rel
 			
 			var entities []*Entity
 			var relationships []*Relationship
+			var nodes []neo4j.Node
 
 			// Add center entity
 			if centerNode, ok := record.Values[0].(neo4j.Node); ok {
 				entities = append(entities, c.nodeToEntity(centerNode))
+				nodes = append(nodes, centerNode)
 			}
 
 			// Add neighbor entities
@@ -500,6 +531,7 @@ func (c *Client) GetEntityNeighborhood(ctx context.Context, entityID string, rel
 				for _, neighborInterface := range neighbors {
 					neighbor := neighborInterface.(neo4j.Node)
 					entities = append(entities, c.nodeToEntity(neighbor))
+					nodes = append(nodes, neighbor)
 				}
 			}
 
@@ -510,6 +542,7 @@ func (c *Client) GetEntityNeighborhood(ctx context.Context, entityID string, rel
 					relationships = append(relationships, c.relationshipToEdge(rel))
 				}
 			}
+			resolveEndpoints(nodes, relationships)
 
 			return &SubGraph{
 				Entities:      entities,
@@ -568,9 +601,11 @@ func (c *Client) relationshipToEdge(rel neo4j.Relationship) *Relationship {
 		Properties: make(map[string]interface{}),
 	}
 
-	// Get relationship ID
+	// Get relationship ID, falling back to the database's own
 	if id, exists := rel.Props["id"]; exists {
 		relationship.ID = id.(string)
+	} else {
+		relationship.ID = rel.ElementId
 	}
 
 	// Copy all properties
@@ -606,6 +641,37 @@ func (c *Client) pathToResult(path neo4j.Path, length int) *Path {
 		relationship := c.relationshipToEdge(rel)
 		pathResult.Relationships = append(pathResult.Relationships, relationship)
 	}
+	resolveEndpoints(path.Nodes, pathResult.Relationships)
 
 	return pathResult
+}
+
+// ToPath converts a path returned by ExecuteQuery, reporting false for
+// values that are not paths
+func (c *Client) ToPath(value interface{}) (*Path, bool) {
+	path, ok := value.(neo4j.Path)
+	if !ok {
+		return nil, false
+	}
+	return c.pathToResult(path, len(path.Relationships)), true
+}
+
+// resolveEndpoints replaces the internal node IDs relationships are
+// converted with by the entity IDs of the given nodes, where known
+func resolveEndpoints(nodes []neo4j.Node, relationships []*Relationship) {
+	entityIDs := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		if id, ok := node.Props["id"].(string); ok {
+			entityIDs[fmt.Sprintf("%d", node.Id)] = id
+		}
+	}
+
+	for _, relationship := range relationships {
+		if id, ok := entityIDs[relationship.SourceID]; ok {
+			relationship.SourceID = id
+		}
+		if id, ok := entityIDs[relationship.TargetID]; ok {
+			relationship.TargetID = id
+		}
+	}
 }
\ No newline at end of file
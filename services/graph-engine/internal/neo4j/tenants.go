@@ -0,0 +1,349 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/tenancy"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// systemDatabase is where Neo4j administers databases
+const systemDatabase = "system"
+
+// tenantSchema is created in every new tenant database, matching the schema
+// entity resolution creates in the shared database
+var tenantSchema = []string{
+	"CREATE CONSTRAINT entity_id_unique IF NOT EXISTS FOR (e:Entity) REQUIRE e.id IS UNIQUE",
+	"CREATE INDEX entity_type_index IF NOT EXISTS FOR (e:Entity) ON (e.entity_type)",
+	"CREATE INDEX entity_name_index IF NOT EXISTS FOR (e:Entity) ON (e.name)",
+	"CREATE INDEX entity_standardized_name_index IF NOT EXISTS FOR (e:Entity) ON (e.standardized_name)",
+	"CREATE INDEX entity_confidence_index IF NOT EXISTS FOR (e:Entity) ON (e.confidence_score)",
+}
+
+// SetTenancy enables per-tenant database routing
+func (c *Client) SetTenancy(cfg config.TenancyConfig) {
+	c.tenancy = cfg
+}
+
+// ExecuteQuery runs Cypher in the context's database and returns its
+// records as maps keyed by column
+func (c *Client) ExecuteQuery(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	if err := tenancy.CheckQuery(query); err != nil {
+		return nil, err
+	}
+
+	database, err := c.database(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := neo4j.ExecuteQuery(ctx, c.driver, query, params, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(database))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	records := make([]map[string]interface{}, 0, len(result.Records))
+	for _, record := range result.Records {
+		records = append(records, record.AsMap())
+	}
+	return records, nil
+}
+
+// QueryResult holds the records of a read query. Nodes, relationships and
+// paths in its rows are converted to entities, relationships and paths.
+type QueryResult struct {
+	Columns   []string                 `json:"columns"`
+	Rows      []map[string]interface{} `json:"rows"`
+	Truncated bool                     `json:"truncated"`
+}
+
+// ExecuteReadQuery runs Cypher in a read transaction on the context's
+// database, so Neo4j rejects anything that writes, and returns at most
+// maxRows records
+func (c *Client) ExecuteReadQuery(ctx context.Context, query string, params map[string]interface{}, maxRows int) (*QueryResult, error) {
+	if err := tenancy.CheckQuery(query); err != nil {
+		return nil, err
+	}
+
+	session, err := c.newSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close(ctx)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		records, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+
+		keys, err := records.Keys()
+		if err != nil {
+			return nil, err
+		}
+
+		queryResult := &QueryResult{Columns: keys, Rows: []map[string]interface{}{}}
+		for records.Next(ctx) {
+			if len(queryResult.Rows) == maxRows {
+				queryResult.Truncated = true
+				break
+			}
+
+			record := records.Record()
+			row := make(map[string]interface{}, len(record.Keys))
+			for i, key := range record.Keys {
+				row[key] = c.convertValue(record.Values[i])
+			}
+			queryResult.Rows = append(queryResult.Rows, row)
+		}
+		if err := records.Err(); err != nil {
+			return nil, err
+		}
+
+		return queryResult, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute read query: %w", err)
+	}
+
+	return result.(*QueryResult), nil
+}
+
+// convertValue converts graph values in a record to entities,
+// relationships and paths, descending into lists and maps
+func (c *Client) convertValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case neo4j.Node:
+		return c.nodeToEntity(v)
+	case neo4j.Relationship:
+		return c.relationshipToEdge(v)
+	case neo4j.Path:
+		return c.pathToResult(v, len(v.Relationships))
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			converted[i] = c.convertValue(item)
+		}
+		return converted
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[key] = c.convertValue(item)
+		}
+		return converted
+	default:
+		return v
+	}
+}
+
+// newSession opens a session on the context's database
+func (c *Client) newSession(ctx context.Context) (neo4j.SessionWithContext, error) {
+	database, err := c.database(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: database}), nil
+}
+
+// database returns the database a context's queries run in: the tenant's
+// own database when the context names a tenant, else the shared database.
+// A tenant is never routed anywhere but its own database, so a missing
+// tenant database is an error rather than a fallback.
+func (c *Client) database(ctx context.Context) (string, error) {
+	if !c.tenancy.Enabled {
+		return c.config.Database, nil
+	}
+
+	tenantID, ok := tenancy.FromContext(ctx)
+	if !ok {
+		if c.tenancy.RequireTenant {
+			return "", tenancy.ErrTenantRequired
+		}
+		return c.config.Database, nil
+	}
+
+	name, err := c.tenantDatabaseName(tenantID)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.RLock()
+	provisioned := c.provisioned[name]
+	c.mu.RUnlock()
+	if provisioned {
+		return name, nil
+	}
+
+	statuses, err := c.databaseStatuses(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := statuses[name]; !ok {
+		return "", fmt.Errorf("%w: %s", tenancy.ErrTenantNotProvisioned, tenantID)
+	}
+
+	c.mu.Lock()
+	c.provisioned[name] = true
+	c.mu.Unlock()
+	return name, nil
+}
+
+// tenantDatabaseName names a tenant's database, refusing names that would
+// collide with the shared or system database
+func (c *Client) tenantDatabaseName(tenantID string) (string, error) {
+	name, err := tenancy.DatabaseName(c.tenancy.DatabasePrefix, tenantID)
+	if err != nil {
+		return "", err
+	}
+	if name == c.config.Database || name == systemDatabase {
+		return "", fmt.Errorf("%w: %q names a reserved database", tenancy.ErrInvalidTenant, tenantID)
+	}
+	return name, nil
+}
+
+// ProvisionTenantDatabase creates a tenant's database and its schema. It is
+// safe to call again for a tenant that already has a database.
+func (c *Client) ProvisionTenantDatabase(ctx context.Context, tenantID string) (*tenancy.Database, error) {
+	if !c.tenancy.Enabled {
+		return nil, fmt.Errorf("tenancy is not enabled")
+	}
+
+	name, err := c.tenantDatabaseName(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := neo4j.ExecuteQuery(ctx, c.driver, "CREATE DATABASE $name IF NOT EXISTS WAIT",
+		map[string]interface{}{"name": name}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(systemDatabase)); err != nil {
+		return nil, fmt.Errorf("failed to create database %s: %w", name, err)
+	}
+
+	for _, statement := range tenantSchema {
+		if _, err := neo4j.ExecuteQuery(ctx, c.driver, statement, nil, neo4j.EagerResultTransformer,
+			neo4j.ExecuteQueryWithDatabase(name)); err != nil {
+			return nil, fmt.Errorf("failed to create schema in database %s: %w", name, err)
+		}
+	}
+
+	c.mu.Lock()
+	c.provisioned[name] = true
+	c.mu.Unlock()
+
+	c.logger.Info("Tenant database provisioned", "tenant_id", tenantID, "database", name)
+	return c.TenantDatabase(ctx, tenantID)
+}
+
+// TenantDatabase returns the health and size of a tenant's database
+func (c *Client) TenantDatabase(ctx context.Context, tenantID string) (*tenancy.Database, error) {
+	name, err := c.tenantDatabaseName(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := c.databaseStatuses(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	status, ok := statuses[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", tenancy.ErrTenantNotProvisioned, tenantID)
+	}
+
+	normalized, _ := tenancy.NormalizeTenant(tenantID)
+	return c.inspectDatabase(ctx, normalized, name, status), nil
+}
+
+// TenantDatabases returns the health and size of every tenant database
+func (c *Client) TenantDatabases(ctx context.Context) ([]*tenancy.Database, error) {
+	statuses, err := c.databaseStatuses(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var databases []*tenancy.Database
+	for name, status := range statuses {
+		tenantID, ok := tenancy.TenantOf(c.tenancy.DatabasePrefix, name)
+		if !ok || name == c.config.Database {
+			continue
+		}
+		databases = append(databases, c.inspectDatabase(ctx, tenantID, name, status))
+	}
+
+	sort.Slice(databases, func(i, j int) bool {
+		return databases[i].TenantID < databases[j].TenantID
+	})
+	return databases, nil
+}
+
+// databaseStatuses returns the status of the named database, or of every
+// database when name is empty. In a cluster a database is reported once per
+// server; it is only online when it is online everywhere.
+func (c *Client) databaseStatuses(ctx context.Context, name string) (map[string]string, error) {
+	query := "SHOW DATABASES YIELD name, currentStatus RETURN name, currentStatus"
+	params := map[string]interface{}{}
+	if name != "" {
+		query = "SHOW DATABASES YIELD name, currentStatus WHERE name = $name RETURN name, currentStatus"
+		params["name"] = name
+	}
+
+	result, err := neo4j.ExecuteQuery(ctx, c.driver, query, params, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(systemDatabase))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+
+	statuses := make(map[string]string)
+	for _, record := range result.Records {
+		dbName, _ := record.AsMap()["name"].(string)
+		status, _ := record.AsMap()["currentStatus"].(string)
+		if current, ok := statuses[dbName]; !ok || current == "online" {
+			statuses[dbName] = status
+		}
+	}
+	return statuses, nil
+}
+
+// inspectDatabase counts an online database's nodes and relationships and
+// reads its store size where APOC reports it
+func (c *Client) inspectDatabase(ctx context.Context, tenantID, name, status string) *tenancy.Database {
+	db := &tenancy.Database{
+		TenantID:   tenantID,
+		Name:       name,
+		Status:     status,
+		Online:     status == "online",
+		StoreBytes: -1,
+		CheckedAt:  time.Now(),
+	}
+	if !db.Online {
+		return db
+	}
+
+	counts, err := neo4j.ExecuteQuery(ctx, c.driver, `
+		CALL { MATCH (n) RETURN count(n) AS nodes }
+		CALL { MATCH ()-[r]->() RETURN count(r) AS relationships }
+		RETURN nodes, relationships
+	`, nil, neo4j.EagerResultTransformer, neo4j.ExecuteQueryWithDatabase(name), neo4j.ExecuteQueryWithReadersRouting())
+	if err != nil {
+		c.logger.Warn("Failed to count tenant database", "database", name, "error", err)
+	} else if len(counts.Records) > 0 {
+		values := counts.Records[0].AsMap()
+		db.Nodes, _ = values["nodes"].(int64)
+		db.Relationships, _ = values["relationships"].(int64)
+	}
+
+	store, err := neo4j.ExecuteQuery(ctx, c.driver, "CALL apoc.monitor.store() YIELD totalStoreSize RETURN totalStoreSize",
+		nil, neo4j.EagerResultTransformer, neo4j.ExecuteQueryWithDatabase(name), neo4j.ExecuteQueryWithReadersRouting())
+	if err == nil && len(store.Records) > 0 {
+		if size, ok := store.Records[0].AsMap()["totalStoreSize"].(int64); ok {
+			db.StoreBytes = size
+		}
+	}
+
+	return db
+}
@@ -5,11 +5,11 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
-	"sort"
 	"time"
 
 	"github.com/aegisshield/graph-engine/internal/config"
 	"github.com/aegisshield/graph-engine/internal/neo4j"
+	"github.com/aegisshield/graph-engine/internal/temporal"
 	"github.com/google/uuid"
 )
 
@@ -17,6 +17,7 @@ import (
 type PatternDetector struct {
 	neo4jClient *neo4j.Client
 	config      config.GraphEngineConfig
+	motifs      *MotifLibrary
 	logger      *slog.Logger
 }
 
@@ -36,6 +37,37 @@ const (
 	PatternTypeKitingScheme      PatternType = "kiting_scheme"
 )
 
+// Money-laundering typologies found by the motif library
+const (
+	PatternTypeRoundTripping PatternType = "round_tripping"
+	PatternTypeFanIn         PatternType = "fan_in"
+	PatternTypeFanOut        PatternType = "fan_out"
+	PatternTypePassThrough   PatternType = "pass_through"
+	PatternTypeLayeringChain PatternType = "layering_chain"
+	PatternTypeNestedShell   PatternType = "nested_shell"
+)
+
+// riskMultipliers weigh a pattern's confidence by how strongly its type
+// indicates laundering
+var riskMultipliers = map[PatternType]float64{
+	PatternTypeSmurfing:          1.0,
+	PatternTypeLayering:          1.2,
+	PatternTypeStructuring:       1.1,
+	PatternTypeCircularFlow:      1.3,
+	PatternTypeRapidMovement:     1.1,
+	PatternTypeHighRiskGeography: 1.4,
+	PatternTypeUnusualVolume:     1.0,
+	PatternTypeShellCompany:      1.5,
+	PatternTypeMuleAccount:       1.2,
+	PatternTypeKitingScheme:      1.3,
+	PatternTypeRoundTripping:     1.3,
+	PatternTypeFanIn:             1.1,
+	PatternTypeFanOut:            1.1,
+	PatternTypePassThrough:       1.2,
+	PatternTypeLayeringChain:     1.3,
+	PatternTypeNestedShell:       1.5,
+}
+
 // Pattern represents a detected suspicious pattern
 type Pattern struct {
 	ID               string                 `json:"id"`
@@ -99,6 +131,11 @@ func NewPatternDetector(client *neo4j.Client, config config.GraphEngineConfig, l
 	}
 }
 
+// SetMotifs makes the detector serve the motif library's typologies
+func (pd *PatternDetector) SetMotifs(library *MotifLibrary) {
+	pd.motifs = library
+}
+
 // DetectPatterns performs comprehensive pattern detection
 func (pd *PatternDetector) DetectPatterns(ctx context.Context, req *DetectionRequest) (*DetectionResult, error) {
 	startTime := time.Now()
@@ -170,6 +207,12 @@ func (pd *PatternDetector) detectPatternType(ctx context.Context, patternType Pa
 		return pd.detectMuleAccountPattern(ctx, req)
 	case PatternTypeKitingScheme:
 		return pd.detectKitingSchemePattern(ctx, req)
+	case PatternTypeRoundTripping, PatternTypeFanIn, PatternTypeFanOut,
+		PatternTypePassThrough, PatternTypeLayeringChain, PatternTypeNestedShell:
+		if pd.motifs == nil {
+			return nil, fmt.Errorf("motif detection is not configured")
+		}
+		return pd.motifs.DetectType(ctx, patternType, req)
 	default:
 		return nil, fmt.Errorf("unsupported pattern type: %s", patternType)
 	}
@@ -181,7 +224,9 @@ func (pd *PatternDetector) detectSmurfingPattern(ctx context.Context, req *Detec
 	query := `
 		MATCH (source:Account)-[t:TRANSACTION]->(dest:Account)
 		WHERE t.amount < $threshold
-		AND t.timestamp >= datetime() - duration($timeWindow)
+		AND t.timestamp >= $as_of - duration($timeWindow)
+		AND t.timestamp <= $as_of
+		AND ` + temporal.Relationship(ctx, "t") + `
 		WITH source, dest, COUNT(t) as txCount, 
 			 AVG(t.amount) as avgAmount,
 			 STDEV(t.amount) as amountStdev,
@@ -219,7 +264,7 @@ func (pd *PatternDetector) detectSmurfingPattern(ctx context.Context, req *Detec
 		"timeWindow":      timeWindow.String(),
 	}
 
-	records, err := pd.neo4jClient.ExecuteQuery(ctx, query, params)
+	records, err := pd.neo4jClient.ExecuteQuery(ctx, query, temporal.Bind(ctx, params))
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute smurfing detection query: %w", err)
 	}
@@ -309,7 +354,8 @@ func (pd *PatternDetector) detectLayeringPattern(ctx context.Context, req *Detec
 	query := `
 		MATCH path = (start:Account)-[:TRANSACTION*2..10]->(end:Account)
 		WHERE start <> end
-		AND ALL(r IN relationships(path) WHERE r.timestamp >= datetime() - duration($timeWindow))
+		AND ALL(r IN relationships(path) WHERE r.timestamp >= $as_of - duration($timeWindow) AND r.timestamp <= $as_of)
+		AND ` + temporal.Path(ctx, "path") + `
 		WITH path, length(path) as pathLength,
 			 [n IN nodes(path) | n.country] as countries,
 			 [n IN nodes(path) | n.institution] as institutions,
@@ -348,7 +394,7 @@ func (pd *PatternDetector) detectLayeringPattern(ctx context.Context, req *Detec
 		"timeWindow":   timeWindow.String(),
 	}
 
-	records, err := pd.neo4jClient.ExecuteQuery(ctx, query, params)
+	records, err := pd.neo4jClient.ExecuteQuery(ctx, query, temporal.Bind(ctx, params))
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute layering detection query: %w", err)
 	}
@@ -430,7 +476,8 @@ func (pd *PatternDetector) buildLayeringPattern(record map[string]interface{}, r
 func (pd *PatternDetector) detectCircularFlowPattern(ctx context.Context, req *DetectionRequest) ([]*Pattern, error) {
 	query := `
 		MATCH path = (start:Account)-[:TRANSACTION*3..8]->(start)
-		WHERE ALL(r IN relationships(path) WHERE r.timestamp >= datetime() - duration($timeWindow))
+		WHERE ALL(r IN relationships(path) WHERE r.timestamp >= $as_of - duration($timeWindow) AND r.timestamp <= $as_of)
+		AND ` + temporal.Path(ctx, "path") + `
 		WITH path, 
 			 length(path) as circleLength,
 			 [r IN relationships(path) | r.amount] as amounts,
@@ -460,7 +507,7 @@ func (pd *PatternDetector) detectCircularFlowPattern(ctx context.Context, req *D
 		"timeWindow":      timeWindow.String(),
 	}
 
-	records, err := pd.neo4jClient.ExecuteQuery(ctx, query, params)
+	records, err := pd.neo4jClient.ExecuteQuery(ctx, query, temporal.Bind(ctx, params))
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute circular flow detection query: %w", err)
 	}
@@ -616,24 +663,15 @@ func (pd *PatternDetector) calculateCircularFlowConfidence(circleLength int, tot
 
 // calculateRiskScore calculates overall risk score for a pattern
 func (pd *PatternDetector) calculateRiskScore(confidence float64, patternType PatternType) float64 {
+	return riskScore(confidence, patternType)
+}
+
+// riskScore scales confidence to 0-100 by the pattern type's multiplier
+func riskScore(confidence float64, patternType PatternType) float64 {
 	// Base risk score from confidence
 	riskScore := confidence * 100
 
-	// Pattern type multipliers
-	multipliers := map[PatternType]float64{
-		PatternTypeSmurfing:          1.0,
-		PatternTypeLayering:          1.2,
-		PatternTypeStructuring:       1.1,
-		PatternTypeCircularFlow:      1.3,
-		PatternTypeRapidMovement:     1.1,
-		PatternTypeHighRiskGeography: 1.4,
-		PatternTypeUnusualVolume:     1.0,
-		PatternTypeShellCompany:      1.5,
-		PatternTypeMuleAccount:       1.2,
-		PatternTypeKitingScheme:      1.3,
-	}
-
-	if multiplier, exists := multipliers[patternType]; exists {
+	if multiplier, exists := riskMultipliers[patternType]; exists {
 		riskScore *= multiplier
 	}
 
@@ -0,0 +1,805 @@
+package patterns
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/neo4j"
+)
+
+// motifSearchBudget bounds the transactions and ownerships a detector
+// visits in one search; dense graphs stop early with the instances found
+const motifSearchBudget = 2000000
+
+// ErrUnknownMotif is returned for pattern types the motif library does not
+// detect
+var ErrUnknownMotif = errors.New("unknown motif")
+
+// MotifTypes are the typologies the motif library detects
+var MotifTypes = []PatternType{
+	PatternTypeRoundTripping,
+	PatternTypeFanIn,
+	PatternTypeFanOut,
+	PatternTypePassThrough,
+	PatternTypeLayeringChain,
+	PatternTypeNestedShell,
+}
+
+// MotifTransaction is a transaction from one entity to another
+type MotifTransaction struct {
+	ID     string
+	From   string
+	To     string
+	Amount float64
+	At     time.Time
+}
+
+// MotifOwnership is an ownership relationship from Owner to Owned
+type MotifOwnership struct {
+	ID    string
+	Type  string
+	Owner string
+	Owned string
+}
+
+// MotifGraph is what motifs are searched for in: the transactions of the
+// time window, the ownership relationships and the entities marked as
+// shell companies
+type MotifGraph struct {
+	Transactions []MotifTransaction
+	Ownerships   []MotifOwnership
+	Shells       map[string]bool
+}
+
+// DetectMotif finds the instances of a motif, highest risk first. It orders
+// the graph's transactions chronologically. The instances' entities carry
+// only their IDs.
+func DetectMotif(graph *MotifGraph, motif PatternType, cfg config.MotifConfig) ([]*Pattern, error) {
+	sort.SliceStable(graph.Transactions, func(i, j int) bool {
+		return graph.Transactions[i].At.Before(graph.Transactions[j].At)
+	})
+	index := newMotifIndex(graph)
+
+	var instances []*motifInstance
+	switch motif {
+	case PatternTypeRoundTripping:
+		instances = index.roundTrips(cfg)
+	case PatternTypeFanIn, PatternTypeFanOut:
+		instances = index.fans(motif, cfg)
+	case PatternTypePassThrough:
+		instances = index.passThroughs(cfg)
+	case PatternTypeLayeringChain:
+		instances = index.layeringChains(cfg)
+	case PatternTypeNestedShell:
+		instances = index.nestedShells(cfg)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMotif, motif)
+	}
+
+	patterns := make([]*Pattern, 0, len(instances))
+	for _, instance := range instances {
+		patterns = append(patterns, index.pattern(motif, instance, cfg))
+	}
+	sort.SliceStable(patterns, func(i, j int) bool {
+		if patterns[i].RiskScore != patterns[j].RiskScore {
+			return patterns[i].RiskScore > patterns[j].RiskScore
+		}
+		return patterns[i].ID < patterns[j].ID
+	})
+	return patterns, nil
+}
+
+// motifInstance is one occurrence of a motif before it becomes a Pattern
+type motifInstance struct {
+	key          string // identifies the occurrence across runs
+	entities     []string
+	transactions []int
+	ownerships   []int
+	confidence   float64
+	description  string
+	indicators   []string
+	metadata     map[string]interface{}
+}
+
+// motifIndex holds each entity's transactions and ownerships in
+// chronological order
+type motifIndex struct {
+	graph  *MotifGraph
+	out    map[string][]int
+	in     map[string][]int
+	owns   map[string][]int
+	owners map[string][]int
+	budget int
+}
+
+func newMotifIndex(graph *MotifGraph) *motifIndex {
+	index := &motifIndex{
+		graph:  graph,
+		out:    make(map[string][]int),
+		in:     make(map[string][]int),
+		owns:   make(map[string][]int),
+		owners: make(map[string][]int),
+	}
+	for i, t := range graph.Transactions {
+		if t.From == t.To {
+			continue
+		}
+		index.out[t.From] = append(index.out[t.From], i)
+		index.in[t.To] = append(index.in[t.To], i)
+	}
+	for i, o := range graph.Ownerships {
+		if o.Owner == o.Owned {
+			continue
+		}
+		index.owns[o.Owner] = append(index.owns[o.Owner], i)
+		index.owners[o.Owned] = append(index.owners[o.Owned], i)
+	}
+	return index
+}
+
+// spend takes one step from the search budget and reports whether the
+// search may go on
+func (x *motifIndex) spend() bool {
+	if x.budget <= 0 {
+		return false
+	}
+	x.budget--
+	return true
+}
+
+// from returns the transactions of a chronological list made at or after at
+func (x *motifIndex) from(list []int, at time.Time) []int {
+	i := sort.Search(len(list), func(i int) bool {
+		return !x.graph.Transactions[list[i]].At.Before(at)
+	})
+	return list[i:]
+}
+
+// roundTrips finds funds leaving an entity and coming back to it through
+// other entities: simple cycles of transactions made one after another
+// within the cycle window
+func (x *motifIndex) roundTrips(cfg config.MotifConfig) []*motifInstance {
+	x.budget = motifSearchBudget
+	txs := x.graph.Transactions
+	best := make(map[string]*motifInstance)
+
+	var path []int
+	visited := make(map[string]bool)
+	var walk func(origin string, node string) bool
+	walk = func(origin string, node string) bool {
+		first := txs[path[0]]
+		for _, next := range x.from(x.out[node], txs[path[len(path)-1]].At) {
+			if !x.spend() {
+				return false
+			}
+			t := txs[next]
+			if t.At.Sub(first.At) > cfg.CycleWindow {
+				break
+			}
+			if t.To == origin {
+				keepBest(best, x.roundTrip(append(path, next), cfg))
+				continue
+			}
+			if visited[t.To] || len(path)+1 >= cfg.MaxCycleLength {
+				continue
+			}
+
+			visited[t.To] = true
+			path = append(path, next)
+			ok := walk(origin, t.To)
+			path = path[:len(path)-1]
+			delete(visited, t.To)
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	for start, t := range txs {
+		if t.From == t.To || t.Amount <= 0 {
+			continue
+		}
+		path = []int{start}
+		visited = map[string]bool{t.From: true, t.To: true}
+		if !walk(t.From, t.To) {
+			truncatedMotifSearches.WithLabelValues(string(PatternTypeRoundTripping)).Inc()
+			break
+		}
+	}
+	return instanceList(best)
+}
+
+func (x *motifIndex) roundTrip(cycle []int, cfg config.MotifConfig) *motifInstance {
+	txs := x.graph.Transactions
+	first, last := txs[cycle[0]], txs[cycle[len(cycle)-1]]
+
+	entities := make([]string, len(cycle))
+	for i, t := range cycle {
+		entities[i] = txs[t].From
+	}
+
+	returned := last.Amount / first.Amount
+	closeness := clamp01(1 - math.Abs(1-returned))
+	span := last.At.Sub(first.At)
+	speed := clamp01(1 - float64(span)/float64(cfg.CycleWindow))
+
+	confidence := 0.35 + 0.35*closeness + 0.2*speed
+	if len(cycle) >= 3 {
+		confidence += 0.1
+	}
+
+	indicators := []string{
+		fmt.Sprintf("Funds returned to the originator through %d counterparties", len(cycle)-1),
+		fmt.Sprintf("%.0f%% of the original amount returned", returned*100),
+		fmt.Sprintf("Cycle completed in %s", span.Round(time.Minute)),
+	}
+
+	return &motifInstance{
+		key:          cycleKey(entities),
+		entities:     entities,
+		transactions: append([]int(nil), cycle...),
+		confidence:   math.Min(confidence, 1.0),
+		description:  fmt.Sprintf("Round trip of $%.2f from %s through %d entities and back", first.Amount, first.From, len(cycle)-1),
+		indicators:   indicators,
+		metadata: map[string]interface{}{
+			"origin":         first.From,
+			"cycle_length":   len(cycle),
+			"returned_ratio": returned,
+		},
+	}
+}
+
+// fans finds entities receiving from (fan-in) or sending to (fan-out) many
+// counterparties within the fan window, scored up when the amounts sit just
+// below the structuring threshold. Each entity is reported for the window
+// with the most counterparties.
+func (x *motifIndex) fans(motif PatternType, cfg config.MotifConfig) []*motifInstance {
+	txs := x.graph.Transactions
+	lists := x.in
+	counterparty := func(t MotifTransaction) string { return t.From }
+	if motif == PatternTypeFanOut {
+		lists = x.out
+		counterparty = func(t MotifTransaction) string { return t.To }
+	}
+
+	var instances []*motifInstance
+	for _, hub := range sortedKeys(lists) {
+		list := lists[hub]
+		counts := make(map[string]int)
+		lo, bestLo, bestHi, bestDistinct := 0, 0, -1, 0
+		for hi, i := range list {
+			counts[counterparty(txs[i])]++
+			for txs[i].At.Sub(txs[list[lo]].At) > cfg.FanWindow {
+				party := counterparty(txs[list[lo]])
+				if counts[party]--; counts[party] == 0 {
+					delete(counts, party)
+				}
+				lo++
+			}
+			if len(counts) > bestDistinct {
+				bestLo, bestHi, bestDistinct = lo, hi, len(counts)
+			}
+		}
+		if bestDistinct < cfg.FanMinCounterparties {
+			continue
+		}
+		instances = append(instances, x.fan(motif, hub, list[bestLo:bestHi+1], bestDistinct, counterparty, cfg))
+	}
+	return instances
+}
+
+func (x *motifIndex) fan(motif PatternType, hub string, window []int, distinct int, counterparty func(MotifTransaction) string, cfg config.MotifConfig) *motifInstance {
+	txs := x.graph.Transactions
+	floor := cfg.StructuringThreshold * (1 - cfg.StructuringMargin)
+
+	entities := []string{hub}
+	seen := map[string]bool{hub: true}
+	justBelow, below, total := 0, 0, 0.0
+	for _, i := range window {
+		t := txs[i]
+		if party := counterparty(t); !seen[party] {
+			seen[party] = true
+			entities = append(entities, party)
+		}
+		if t.Amount < cfg.StructuringThreshold {
+			below++
+			if t.Amount >= floor {
+				justBelow++
+			}
+		}
+		total += t.Amount
+	}
+
+	structuring := float64(justBelow) / float64(len(window))
+	breadth := clamp01(float64(distinct) / float64(2*cfg.FanMinCounterparties))
+	confidence := 0.3 + 0.3*breadth + 0.3*structuring
+	if below == len(window) {
+		confidence += 0.1
+	}
+
+	direction, preposition := "received", "from"
+	if motif == PatternTypeFanOut {
+		direction, preposition = "sent", "to"
+	}
+	span := txs[window[len(window)-1]].At.Sub(txs[window[0]].At)
+	indicators := []string{
+		fmt.Sprintf("%d transactions %s %s %d counterparties within %s", len(window), direction, preposition, distinct, span.Round(time.Minute)),
+	}
+	if justBelow > 0 {
+		indicators = append(indicators, fmt.Sprintf("%d amounts just below the $%.0f reporting threshold", justBelow, cfg.StructuringThreshold))
+	}
+	if below == len(window) {
+		indicators = append(indicators, "Every amount below the reporting threshold")
+	}
+
+	return &motifInstance{
+		key:          hub + "@" + txs[window[0]].ID,
+		entities:     entities,
+		transactions: append([]int(nil), window...),
+		confidence:   math.Min(confidence, 1.0),
+		description:  fmt.Sprintf("%s $%.2f %s %d counterparties", hub, total, direction+" "+preposition, distinct),
+		indicators:   indicators,
+		metadata: map[string]interface{}{
+			"hub":                  hub,
+			"counterparties":       distinct,
+			"just_below_threshold": justBelow,
+		},
+	}
+}
+
+// passThroughs finds entities passing on most of what they receive within
+// the pass-through window. Each entity is reported for the window moving the
+// most money.
+func (x *motifIndex) passThroughs(cfg config.MotifConfig) []*motifInstance {
+	txs := x.graph.Transactions
+
+	var instances []*motifInstance
+	for _, entity := range sortedKeys(x.in) {
+		ins, outs := x.in[entity], x.out[entity]
+		if len(outs) == 0 {
+			continue
+		}
+		inSums, outSums := prefixSums(txs, ins), prefixSums(txs, outs)
+
+		var best *motifInstance
+		bestMoved := 0.0
+		for i := range ins {
+			start := txs[ins[i]].At
+			end := start.Add(cfg.PassThroughWindow)
+			j := sort.Search(len(ins), func(k int) bool { return txs[ins[k]].At.After(end) })
+			k := sort.Search(len(outs), func(k int) bool { return !txs[outs[k]].At.Before(start) })
+			l := sort.Search(len(outs), func(k int) bool { return txs[outs[k]].At.After(end) })
+
+			inflow, outflow := inSums[j]-inSums[i], outSums[l]-outSums[k]
+			if outflow <= 0 || inflow < cfg.PassThroughMinAmount {
+				continue
+			}
+			ratio := math.Min(inflow, outflow) / math.Max(inflow, outflow)
+			if ratio < cfg.PassThroughMinRatio || math.Min(inflow, outflow) <= bestMoved {
+				continue
+			}
+			bestMoved = math.Min(inflow, outflow)
+			best = x.passThrough(entity, ins[i:j], outs[k:l], inflow, outflow, ratio, cfg)
+		}
+		if best != nil {
+			instances = append(instances, best)
+		}
+	}
+	return instances
+}
+
+func (x *motifIndex) passThrough(entity string, ins, outs []int, inflow, outflow, ratio float64, cfg config.MotifConfig) *motifInstance {
+	txs := x.graph.Transactions
+
+	entities := []string{entity}
+	seen := map[string]bool{entity: true}
+	for _, i := range ins {
+		if from := txs[i].From; !seen[from] {
+			seen[from] = true
+			entities = append(entities, from)
+		}
+	}
+	for _, i := range outs {
+		if to := txs[i].To; !seen[to] {
+			seen[to] = true
+			entities = append(entities, to)
+		}
+	}
+
+	closeness := 1.0
+	if cfg.PassThroughMinRatio < 1 {
+		closeness = clamp01((ratio - cfg.PassThroughMinRatio) / (1 - cfg.PassThroughMinRatio))
+	}
+	dwell := txs[outs[len(outs)-1]].At.Sub(txs[ins[0]].At)
+	speed := clamp01(1 - float64(dwell)/float64(cfg.PassThroughWindow))
+	confidence := 0.4 + 0.35*closeness + 0.25*speed
+
+	return &motifInstance{
+		key:          entity + "@" + txs[ins[0]].ID,
+		entities:     entities,
+		transactions: append(append([]int(nil), ins...), outs...),
+		confidence:   math.Min(confidence, 1.0),
+		description:  fmt.Sprintf("%s passed on $%.2f of $%.2f received within %s", entity, outflow, inflow, dwell.Round(time.Minute)),
+		indicators: []string{
+			fmt.Sprintf("%.0f%% of inflow passed on", outflow/inflow*100),
+			fmt.Sprintf("Funds held for %s", dwell.Round(time.Minute)),
+			fmt.Sprintf("%d incoming and %d outgoing transactions", len(ins), len(outs)),
+		},
+		metadata: map[string]interface{}{
+			"account":   entity,
+			"inflow":    inflow,
+			"outflow":   outflow,
+			"dwell_sec": int64(dwell.Seconds()),
+		},
+	}
+}
+
+// layeringChains finds funds passed along a chain of entities, each hop
+// made soon after the previous one and for slightly less, as when each
+// layer keeps a fee. Chains are reported from their first hop, once, along
+// their longest continuation.
+func (x *motifIndex) layeringChains(cfg config.MotifConfig) []*motifInstance {
+	x.budget = motifSearchBudget
+	txs := x.graph.Transactions
+
+	follows := func(prev, next MotifTransaction) bool {
+		return prev.To == next.From &&
+			!next.At.Before(prev.At) && next.At.Sub(prev.At) <= cfg.LayeringMaxGap &&
+			next.Amount < prev.Amount && next.Amount >= prev.Amount*(1-cfg.LayeringMaxShave)
+	}
+
+	var instances []*motifInstance
+	for start, t := range txs {
+		if t.From == t.To || t.Amount <= 0 {
+			continue
+		}
+		continues := false
+		for _, p := range x.in[t.From] {
+			if follows(txs[p], t) {
+				continues = true
+				break
+			}
+		}
+		if continues {
+			continue
+		}
+
+		var best []int
+		path := []int{start}
+		visited := map[string]bool{t.From: true, t.To: true}
+		var walk func() bool
+		walk = func() bool {
+			if len(path) > len(best) {
+				best = append(best[:0], path...)
+			}
+			if len(path) >= cfg.LayeringMaxHops {
+				return true
+			}
+			prev := txs[path[len(path)-1]]
+			for _, next := range x.from(x.out[prev.To], prev.At) {
+				if !x.spend() {
+					return false
+				}
+				n := txs[next]
+				if n.At.Sub(prev.At) > cfg.LayeringMaxGap {
+					break
+				}
+				if visited[n.To] || !follows(prev, n) {
+					continue
+				}
+				visited[n.To] = true
+				path = append(path, next)
+				ok := walk()
+				path = path[:len(path)-1]
+				delete(visited, n.To)
+				if !ok {
+					return false
+				}
+			}
+			return true
+		}
+		ok := walk()
+		if len(best) >= cfg.LayeringMinHops {
+			instances = append(instances, x.layeringChain(best, cfg))
+		}
+		if !ok {
+			truncatedMotifSearches.WithLabelValues(string(PatternTypeLayeringChain)).Inc()
+			break
+		}
+	}
+	return instances
+}
+
+func (x *motifIndex) layeringChain(chain []int, cfg config.MotifConfig) *motifInstance {
+	txs := x.graph.Transactions
+	first, last := txs[chain[0]], txs[chain[len(chain)-1]]
+
+	entities := []string{first.From}
+	shave := 0.0
+	for i, t := range chain {
+		entities = append(entities, txs[t].To)
+		if i > 0 {
+			shave += 1 - txs[t].Amount/txs[chain[i-1]].Amount
+		}
+	}
+	hops := len(chain)
+	averageShave := shave / float64(hops-1)
+	span := last.At.Sub(first.At)
+
+	length := clamp01(float64(hops-cfg.LayeringMinHops+1) / float64(cfg.LayeringMaxHops-cfg.LayeringMinHops+1))
+	consistency := clamp01(1 - averageShave/cfg.LayeringMaxShave)
+	speed := clamp01(1 - float64(span)/float64(cfg.LayeringMaxGap*time.Duration(hops-1)))
+	confidence := 0.4 + 0.25*length + 0.2*consistency + 0.15*speed
+
+	return &motifInstance{
+		key:          "chain@" + first.ID,
+		entities:     entities,
+		transactions: append([]int(nil), chain...),
+		confidence:   math.Min(confidence, 1.0),
+		description:  fmt.Sprintf("$%.2f layered through %d hops from %s to %s, arriving as $%.2f", first.Amount, hops, first.From, last.To, last.Amount),
+		indicators: []string{
+			fmt.Sprintf("Chain of %d hops with decreasing amounts", hops),
+			fmt.Sprintf("%.1f%% kept back per hop on average", averageShave*100),
+			fmt.Sprintf("Chain completed in %s", span.Round(time.Minute)),
+		},
+		metadata: map[string]interface{}{
+			"hops":          hops,
+			"first_amount":  first.Amount,
+			"last_amount":   last.Amount,
+			"average_shave": averageShave,
+		},
+	}
+}
+
+// nestedShells finds owners reaching entities through layers of shell
+// companies. A chain starts at an owner that is not a shell, or at a shell
+// nobody is known to own, and runs through shells down to the first entity
+// that is not one, or to the last shell.
+func (x *motifIndex) nestedShells(cfg config.MotifConfig) []*motifInstance {
+	x.budget = motifSearchBudget
+	ownerships := x.graph.Ownerships
+	shells := x.graph.Shells
+	best := make(map[string]*motifInstance)
+
+	var path []int
+	visited := make(map[string]bool)
+	var walk func(node string) bool
+	walk = func(node string) bool {
+		extended := false
+		for _, o := range x.owns[node] {
+			if !x.spend() {
+				return false
+			}
+			owned := ownerships[o].Owned
+			if visited[owned] {
+				continue
+			}
+			if !shells[owned] {
+				extended = true
+				if len(path) >= cfg.ShellMinDepth {
+					keepBest(best, x.nestedShell(append(path, o), cfg))
+				}
+				continue
+			}
+			if len(path) >= cfg.ShellMaxDepth {
+				continue
+			}
+			extended = true
+			visited[owned] = true
+			path = append(path, o)
+			ok := walk(owned)
+			path = path[:len(path)-1]
+			delete(visited, owned)
+			if !ok {
+				return false
+			}
+		}
+		if !extended && len(path) >= cfg.ShellMinDepth {
+			keepBest(best, x.nestedShell(append([]int(nil), path...), cfg))
+		}
+		return true
+	}
+
+	for _, owner := range sortedKeys(x.owns) {
+		if shells[owner] && len(x.owners[owner]) > 0 {
+			continue
+		}
+		for _, o := range x.owns[owner] {
+			owned := ownerships[o].Owned
+			if !shells[owned] {
+				continue
+			}
+			path = []int{o}
+			visited = map[string]bool{owner: true, owned: true}
+			if !walk(owned) {
+				truncatedMotifSearches.WithLabelValues(string(PatternTypeNestedShell)).Inc()
+				return instanceList(best)
+			}
+		}
+	}
+	return instanceList(best)
+}
+
+func (x *motifIndex) nestedShell(chain []int, cfg config.MotifConfig) *motifInstance {
+	ownerships := x.graph.Ownerships
+	top := ownerships[chain[0]].Owner
+	bottom := ownerships[chain[len(chain)-1]].Owned
+
+	entities := []string{top}
+	members := map[string]bool{top: true}
+	depth := 0
+	for _, o := range chain {
+		owned := ownerships[o].Owned
+		entities = append(entities, owned)
+		members[owned] = true
+		if x.graph.Shells[owned] {
+			depth++
+		}
+	}
+
+	// Money moving along the chain ties the ownership layers to activity
+	var transactions []int
+	for _, entity := range entities {
+		for _, t := range x.out[entity] {
+			if members[x.graph.Transactions[t].To] {
+				transactions = append(transactions, t)
+			}
+		}
+	}
+
+	nesting := clamp01(float64(depth-cfg.ShellMinDepth+1) / float64(cfg.ShellMaxDepth-cfg.ShellMinDepth+1))
+	confidence := 0.45 + 0.25*nesting
+	indicators := []string{fmt.Sprintf("%d layers of shell companies between %s and %s", depth, top, bottom)}
+	if x.graph.Shells[top] {
+		confidence += 0.1
+		indicators = append(indicators, "No beneficial owner known above the chain")
+	}
+	if len(transactions) > 0 {
+		confidence += 0.2
+		indicators = append(indicators, fmt.Sprintf("%d transactions between entities of the chain", len(transactions)))
+	}
+
+	return &motifInstance{
+		key:          top + ">" + bottom,
+		entities:     entities,
+		transactions: transactions,
+		ownerships:   append([]int(nil), chain...),
+		confidence:   math.Min(confidence, 1.0),
+		description:  fmt.Sprintf("%s owns %s through %d nested shell companies", top, bottom, depth),
+		indicators:   indicators,
+		metadata: map[string]interface{}{
+			"owner":       top,
+			"owned":       bottom,
+			"shell_depth": depth,
+		},
+	}
+}
+
+// pattern turns an instance into a scored pattern. Its ID is derived from
+// the motif and the instance key, so the same occurrence keeps its ID
+// across runs.
+func (x *motifIndex) pattern(motif PatternType, instance *motifInstance, cfg config.MotifConfig) *Pattern {
+	txs := x.graph.Transactions
+
+	entities := make([]*neo4j.Entity, len(instance.entities))
+	for i, id := range instance.entities {
+		entities[i] = &neo4j.Entity{ID: id, Properties: map[string]interface{}{}}
+	}
+
+	relationships := make([]*neo4j.Relationship, 0, len(instance.transactions)+len(instance.ownerships))
+	total := 0.0
+	var first, last time.Time
+	for _, i := range instance.transactions {
+		t := txs[i]
+		relationships = append(relationships, &neo4j.Relationship{
+			ID:       t.ID,
+			Type:     cfg.TransactionType,
+			SourceID: t.From,
+			TargetID: t.To,
+			Properties: map[string]interface{}{
+				cfg.AmountProperty: t.Amount,
+				"timestamp":        t.At,
+			},
+		})
+		total += t.Amount
+		if first.IsZero() || t.At.Before(first) {
+			first = t.At
+		}
+		if t.At.After(last) {
+			last = t.At
+		}
+	}
+	for _, i := range instance.ownerships {
+		o := x.graph.Ownerships[i]
+		relationships = append(relationships, &neo4j.Relationship{
+			ID:         o.ID,
+			Type:       o.Type,
+			SourceID:   o.Owner,
+			TargetID:   o.Owned,
+			Properties: map[string]interface{}{},
+		})
+	}
+
+	metadata := map[string]interface{}{
+		"instance_key":      instance.key,
+		"transaction_count": len(instance.transactions),
+		"total_amount":      total,
+	}
+	if !first.IsZero() {
+		metadata["first_transaction_at"] = first
+		metadata["last_transaction_at"] = last
+	}
+	for key, value := range instance.metadata {
+		metadata[key] = value
+	}
+
+	return &Pattern{
+		ID:            uuid.NewSHA1(uuid.NameSpaceOID, []byte(string(motif)+":"+instance.key)).String(),
+		Type:          motif,
+		Entities:      entities,
+		Relationships: relationships,
+		Confidence:    instance.confidence,
+		RiskScore:     riskScore(instance.confidence, motif),
+		DetectedAt:    time.Now(),
+		Description:   instance.description,
+		Indicators:    instance.indicators,
+		Metadata:      metadata,
+	}
+}
+
+// keepBest keeps the most confident instance per key
+func keepBest(best map[string]*motifInstance, instance *motifInstance) {
+	if current, ok := best[instance.key]; !ok || instance.confidence > current.confidence {
+		best[instance.key] = instance
+	}
+}
+
+func instanceList(instances map[string]*motifInstance) []*motifInstance {
+	list := make([]*motifInstance, 0, len(instances))
+	for _, key := range sortedKeys(instances) {
+		list = append(list, instances[key])
+	}
+	return list
+}
+
+// cycleKey names a cycle by its entities, rotated to start at the smallest
+// ID, so the same cycle entered at another entity has the same key
+func cycleKey(entities []string) string {
+	start := 0
+	for i, id := range entities {
+		if id < entities[start] {
+			start = i
+		}
+	}
+	rotated := append(append([]string(nil), entities[start:]...), entities[:start]...)
+	return "cycle:" + strings.Join(rotated, ">")
+}
+
+func prefixSums(txs []MotifTransaction, list []int) []float64 {
+	sums := make([]float64, len(list)+1)
+	for i, t := range list {
+		sums[i+1] = sums[i] + txs[t].Amount
+	}
+	return sums
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func clamp01(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
@@ -0,0 +1,549 @@
+package patterns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/temporal"
+	"github.com/aegisshield/graph-engine/internal/tenancy"
+)
+
+// Motif detection triggers
+const (
+	MotifTriggerScheduled = "scheduled"
+	MotifTriggerOnDemand  = "on_demand"
+)
+
+// maxMotifScopeDepth bounds how far around requested entities transactions
+// are loaded
+const maxMotifScopeDepth = 6
+
+var (
+	motifInstances = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "graph_engine_motif_instances_total",
+		Help: "Money-laundering motif instances found",
+	}, []string{"motif", "trigger"})
+	motifRunDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "graph_engine_motif_run_duration_seconds",
+		Help:    "Time taken to load the graph and run the motif detectors",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"trigger"})
+	truncatedMotifSearches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "graph_engine_motif_truncated_searches_total",
+		Help: "Motif searches stopped at the search budget",
+	}, []string{"motif"})
+)
+
+// QueryRunner runs Cypher against the graph
+type QueryRunner interface {
+	ExecuteQuery(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error)
+}
+
+// MotifPublisher publishes motif instances for alerting
+type MotifPublisher interface {
+	PublishMotifDetected(ctx context.Context, pattern *Pattern) error
+}
+
+// MotifResult reports one motif detection run
+type MotifResult struct {
+	Patterns             []*Pattern          `json:"patterns"`
+	ByType               map[PatternType]int `json:"by_type"`
+	EntitiesAnalyzed     int                 `json:"entities_analyzed"`
+	TransactionsAnalyzed int                 `json:"transactions_analyzed"`
+	OwnershipsAnalyzed   int                 `json:"ownerships_analyzed"`
+	Truncated            bool                `json:"truncated"` // only the most recent max_transactions were searched
+	Duration             time.Duration       `json:"duration"`
+}
+
+// MotifLibrary runs the named money-laundering typology detectors over the
+// transaction and ownership graph, on demand and on a schedule
+type MotifLibrary struct {
+	graph     QueryRunner
+	publisher MotifPublisher
+	cfg       config.MotifConfig
+	tenants   tenancy.Inspector
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu        sync.Mutex
+	published map[string]time.Time // tenant and pattern ID to when the instance was published
+}
+
+// NewMotifLibrary creates a motif library. publisher may be nil to skip
+// publishing scheduled findings.
+func NewMotifLibrary(graph QueryRunner, publisher MotifPublisher, cfg config.MotifConfig, logger *slog.Logger) *MotifLibrary {
+	return &MotifLibrary{
+		graph:     graph,
+		publisher: publisher,
+		cfg:       cfg,
+		logger:    logger,
+		now:       time.Now,
+		published: make(map[string]time.Time),
+	}
+}
+
+// SetTenants makes scheduled runs also cover every online tenant database
+func (l *MotifLibrary) SetTenants(inspector tenancy.Inspector) {
+	l.tenants = inspector
+}
+
+// Start runs the scheduled detectors over every database each interval
+func (l *MotifLibrary) Start(ctx context.Context) {
+	ticker := time.NewTicker(l.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.runScheduled(ctx)
+		}
+	}
+}
+
+// runScheduled detects the scheduled motifs in every database and publishes
+// the instances at or above the publish risk that were not published within
+// the time window
+func (l *MotifLibrary) runScheduled(ctx context.Context) {
+	databases, err := l.databases(ctx)
+	if err != nil {
+		l.logger.Error("Failed to list tenant databases for motif detection", "error", err)
+		return
+	}
+
+	types := make([]PatternType, len(l.cfg.Detectors))
+	for i, detector := range l.cfg.Detectors {
+		types[i] = PatternType(detector)
+	}
+
+	for _, dbCtx := range databases {
+		tenantID, _ := tenancy.FromContext(dbCtx)
+		result, err := l.run(dbCtx, &DetectionRequest{Types: types}, MotifTriggerScheduled)
+		if err != nil {
+			if !errors.Is(err, tenancy.ErrTenantRequired) {
+				l.logger.Error("Scheduled motif detection failed", "tenant_id", tenantID, "error", err)
+			}
+			continue
+		}
+
+		published := 0
+		for _, pattern := range result.Patterns {
+			if pattern.RiskScore >= l.cfg.PublishMinRisk && l.publish(dbCtx, tenantID, pattern) {
+				published++
+			}
+		}
+		l.logger.Info("Scheduled motif detection completed",
+			"tenant_id", tenantID,
+			"patterns_found", len(result.Patterns),
+			"published", published,
+			"transactions", result.TransactionsAnalyzed,
+			"truncated", result.Truncated,
+			"duration", result.Duration)
+	}
+	l.forgetPublished()
+}
+
+// Run detects the requested motifs, every one when none is requested. With
+// entity IDs only the transactions and ownerships around those entities are
+// searched.
+func (l *MotifLibrary) Run(ctx context.Context, req *DetectionRequest) (*MotifResult, error) {
+	return l.run(ctx, req, MotifTriggerOnDemand)
+}
+
+// DetectType runs a single motif detector for the pattern detector
+func (l *MotifLibrary) DetectType(ctx context.Context, motif PatternType, req *DetectionRequest) ([]*Pattern, error) {
+	single := *req
+	single.Types = []PatternType{motif}
+	result, err := l.Run(ctx, &single)
+	if err != nil {
+		return nil, err
+	}
+	return result.Patterns, nil
+}
+
+func (l *MotifLibrary) run(ctx context.Context, req *DetectionRequest, trigger string) (*MotifResult, error) {
+	start := l.now()
+
+	types := req.Types
+	if len(types) == 0 {
+		types = MotifTypes
+	}
+	for _, motif := range types {
+		if !isMotif(motif) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownMotif, motif)
+		}
+	}
+
+	cfg := l.settings(req)
+	minConfidence := req.MinConfidence
+	if minConfidence <= 0 {
+		minConfidence = l.cfg.MinConfidence
+	}
+	minTotal := floatParameter(req.Parameters, "min_total_amount", 0)
+
+	graph, truncated, err := l.load(ctx, cfg, req.EntityIDs, req.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MotifResult{
+		Patterns:             make([]*Pattern, 0),
+		ByType:               make(map[PatternType]int),
+		TransactionsAnalyzed: len(graph.Transactions),
+		OwnershipsAnalyzed:   len(graph.Ownerships),
+		Truncated:            truncated,
+	}
+	entities := make(map[string]bool)
+	for _, t := range graph.Transactions {
+		entities[t.From] = true
+		entities[t.To] = true
+	}
+	for _, o := range graph.Ownerships {
+		entities[o.Owner] = true
+		entities[o.Owned] = true
+	}
+	result.EntitiesAnalyzed = len(entities)
+
+	for _, motif := range types {
+		found, err := DetectMotif(graph, motif, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		kept := 0
+		for _, pattern := range found {
+			if kept >= cfg.MaxInstances {
+				break
+			}
+			if pattern.Confidence < minConfidence || toFloat(pattern.Metadata["total_amount"]) < minTotal {
+				continue
+			}
+			pattern.InvestigationID = req.InvestigationID
+			result.Patterns = append(result.Patterns, pattern)
+			kept++
+		}
+		result.ByType[motif] = kept
+		motifInstances.WithLabelValues(string(motif), trigger).Add(float64(kept))
+	}
+
+	if err := l.describeEntities(ctx, result.Patterns); err != nil {
+		return nil, err
+	}
+
+	result.Duration = l.now().Sub(start)
+	motifRunDuration.WithLabelValues(trigger).Observe(result.Duration.Seconds())
+	return result, nil
+}
+
+// settings applies a request's time window, depth and parameters to the
+// configured detector settings. Requests may narrow the search but not
+// widen it beyond the configured depths.
+func (l *MotifLibrary) settings(req *DetectionRequest) config.MotifConfig {
+	cfg := l.cfg
+	if req.TimeWindow > 0 {
+		cfg.TimeWindow = req.TimeWindow
+	}
+	if req.MaxDepth > 0 {
+		cfg.MaxCycleLength = maxInt(2, minInt(cfg.MaxCycleLength, req.MaxDepth))
+		cfg.LayeringMaxHops = maxInt(cfg.LayeringMinHops, minInt(cfg.LayeringMaxHops, req.MaxDepth))
+		cfg.ShellMaxDepth = maxInt(cfg.ShellMinDepth, minInt(cfg.ShellMaxDepth, req.MaxDepth))
+	}
+
+	cfg.FanMinCounterparties = maxInt(2, intParameter(req.Parameters, "min_counterparties", cfg.FanMinCounterparties))
+	cfg.StructuringThreshold = floatParameter(req.Parameters, "structuring_threshold", cfg.StructuringThreshold)
+	cfg.PassThroughMinAmount = floatParameter(req.Parameters, "pass_through_min_amount", cfg.PassThroughMinAmount)
+	cfg.MaxInstances = minInt(cfg.MaxInstances, intParameter(req.Parameters, "max_instances", cfg.MaxInstances))
+	return cfg
+}
+
+// load reads the transactions of the time window, most recent first up to
+// the transaction limit, and the ownership relationships touching shell
+// companies. With entity IDs both are limited to the entities within depth
+// hops of them. The window ends at the context's as-of time, and only
+// relationships valid then are read.
+func (l *MotifLibrary) load(ctx context.Context, cfg config.MotifConfig, entityIDs []string, depth int) (*MotifGraph, bool, error) {
+	scope, scopeFilter := "", func(string, string) string { return "" }
+	params := temporal.Bind(ctx, map[string]interface{}{
+		"timeWindow":      cypherDuration(cfg.TimeWindow),
+		"maxTransactions": cfg.MaxTransactions,
+	})
+	if len(entityIDs) > 0 {
+		if depth <= 0 || depth > maxMotifScopeDepth {
+			depth = maxMotifScopeDepth / 2
+		}
+		scope = fmt.Sprintf(`
+		MATCH (s:Entity)
+		WHERE s.id IN $entityIds
+		MATCH path = (s)-[:%s*0..%d]-(n:Entity)
+		WHERE %s
+		WITH collect(DISTINCT n.id) AS scope`,
+			strings.Join(append([]string{cfg.TransactionType}, cfg.OwnershipTypes...), "|"), depth,
+			temporal.Path(ctx, "path"))
+		scopeFilter = func(a, b string) string {
+			return fmt.Sprintf("AND %s.id IN scope AND %s.id IN scope", a, b)
+		}
+		params["entityIds"] = entityIDs
+	}
+
+	rows, err := l.graph.ExecuteQuery(ctx, fmt.Sprintf(`%s
+		MATCH (a:Entity)-[t:%s]->(b:Entity)
+		WHERE t.timestamp IS NOT NULL
+		  AND datetime(t.timestamp) >= $as_of - duration($timeWindow)
+		  AND datetime(t.timestamp) <= $as_of
+		  AND %s
+		  %s
+		RETURN coalesce(t.id, elementId(t)) AS id, a.id AS from, b.id AS to,
+			   coalesce(toFloat(t.%s), 0.0) AS amount,
+			   datetime(t.timestamp).epochMillis AS at
+		ORDER BY at DESC
+		LIMIT $maxTransactions`,
+		scope, cfg.TransactionType, temporal.Relationship(ctx, "t"), scopeFilter("a", "b"), cfg.AmountProperty), params)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load motif transactions: %w", err)
+	}
+
+	graph := &MotifGraph{
+		Transactions: make([]MotifTransaction, 0, len(rows)),
+		Shells:       make(map[string]bool),
+	}
+	for _, row := range rows {
+		graph.Transactions = append(graph.Transactions, MotifTransaction{
+			ID:     toString(row["id"]),
+			From:   toString(row["from"]),
+			To:     toString(row["to"]),
+			Amount: toFloat(row["amount"]),
+			At:     time.UnixMilli(int64(toFloat(row["at"]))).UTC(),
+		})
+	}
+	truncated := len(rows) >= cfg.MaxTransactions
+
+	if len(cfg.OwnershipTypes) == 0 || len(cfg.ShellProperties) == 0 {
+		return graph, truncated, nil
+	}
+	rows, err = l.graph.ExecuteQuery(ctx, fmt.Sprintf(`%s
+		MATCH (o:Entity)-[r:%s]->(c:Entity)
+		WHERE (%s OR %s)
+		  AND %s
+		  %s
+		RETURN coalesce(r.id, elementId(r)) AS id, type(r) AS type,
+			   o.id AS owner, c.id AS owned,
+			   %s AS owner_shell, %s AS owned_shell
+		LIMIT $maxTransactions`,
+		scope, strings.Join(cfg.OwnershipTypes, "|"),
+		shellCondition(cfg, "o"), shellCondition(cfg, "c"), temporal.Relationship(ctx, "r"), scopeFilter("o", "c"),
+		shellCondition(cfg, "o"), shellCondition(cfg, "c")), params)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load motif ownerships: %w", err)
+	}
+
+	for _, row := range rows {
+		ownership := MotifOwnership{
+			ID:    toString(row["id"]),
+			Type:  toString(row["type"]),
+			Owner: toString(row["owner"]),
+			Owned: toString(row["owned"]),
+		}
+		graph.Ownerships = append(graph.Ownerships, ownership)
+		if shell, _ := row["owner_shell"].(bool); shell {
+			graph.Shells[ownership.Owner] = true
+		}
+		if shell, _ := row["owned_shell"].(bool); shell {
+			graph.Shells[ownership.Owned] = true
+		}
+	}
+	return graph, truncated, nil
+}
+
+// describeEntities fills in the type and properties of the patterns'
+// entities
+func (l *MotifLibrary) describeEntities(ctx context.Context, patterns []*Pattern) error {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, pattern := range patterns {
+		for _, entity := range pattern.Entities {
+			if !seen[entity.ID] {
+				seen[entity.ID] = true
+				ids = append(ids, entity.ID)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := l.graph.ExecuteQuery(ctx, `
+		MATCH (n:Entity)
+		WHERE n.id IN $ids
+		RETURN n.id AS id, head([label IN labels(n) WHERE label <> 'Entity']) AS type,
+			   properties(n) AS properties`, map[string]interface{}{"ids": ids})
+	if err != nil {
+		return fmt.Errorf("failed to load motif entities: %w", err)
+	}
+
+	type description struct {
+		entityType string
+		properties map[string]interface{}
+	}
+	described := make(map[string]description, len(rows))
+	for _, row := range rows {
+		properties, _ := row["properties"].(map[string]interface{})
+		described[toString(row["id"])] = description{entityType: toString(row["type"]), properties: properties}
+	}
+	for _, pattern := range patterns {
+		for _, entity := range pattern.Entities {
+			if d, ok := described[entity.ID]; ok {
+				entity.Type = d.entityType
+				if d.properties != nil {
+					entity.Properties = d.properties
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// publish publishes an instance unless it was published within the time
+// window, and reports whether it did
+func (l *MotifLibrary) publish(ctx context.Context, tenantID string, pattern *Pattern) bool {
+	if l.publisher == nil {
+		return false
+	}
+
+	key := tenantID + "/" + pattern.ID
+	l.mu.Lock()
+	_, done := l.published[key]
+	l.mu.Unlock()
+	if done {
+		return false
+	}
+
+	if tenantID != "" {
+		pattern.Metadata["tenant_id"] = tenantID
+	}
+	if err := l.publisher.PublishMotifDetected(ctx, pattern); err != nil {
+		l.logger.Error("Failed to publish motif instance", "pattern_id", pattern.ID, "type", pattern.Type, "error", err)
+		return false
+	}
+
+	l.mu.Lock()
+	l.published[key] = l.now()
+	l.mu.Unlock()
+	return true
+}
+
+// forgetPublished drops instances published longer than the time window
+// ago; an instance still found after that is published again
+func (l *MotifLibrary) forgetPublished() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := l.now().Add(-l.cfg.TimeWindow)
+	for key, at := range l.published {
+		if at.Before(cutoff) {
+			delete(l.published, key)
+		}
+	}
+}
+
+// databases returns a context for every database a scheduled run covers
+func (l *MotifLibrary) databases(ctx context.Context) ([]context.Context, error) {
+	if _, ok := tenancy.FromContext(ctx); ok || l.tenants == nil {
+		return []context.Context{ctx}, nil
+	}
+
+	databases, err := l.tenants.TenantDatabases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contexts := []context.Context{ctx}
+	for _, db := range databases {
+		if db.Online {
+			contexts = append(contexts, tenancy.WithTenant(ctx, db.TenantID))
+		}
+	}
+	return contexts, nil
+}
+
+// shellCondition matches entities marked as shell companies by any shell
+// property
+func shellCondition(cfg config.MotifConfig, variable string) string {
+	conditions := make([]string, len(cfg.ShellProperties))
+	for i, property := range cfg.ShellProperties {
+		conditions[i] = fmt.Sprintf("coalesce(%s.%s, false) = true", variable, property)
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+func isMotif(patternType PatternType) bool {
+	for _, motif := range MotifTypes {
+		if motif == patternType {
+			return true
+		}
+	}
+	return false
+}
+
+// cypherDuration formats a duration as an ISO-8601 duration for Cypher
+func cypherDuration(d time.Duration) string {
+	return fmt.Sprintf("PT%dS", int64(d.Seconds()))
+}
+
+func intParameter(parameters map[string]interface{}, key string, fallback int) int {
+	if value := toFloat(parameters[key]); value > 0 {
+		return int(value)
+	}
+	return fallback
+}
+
+func floatParameter(parameters map[string]interface{}, key string, fallback float64) float64 {
+	if value := toFloat(parameters[key]); value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+func toString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,397 @@
+package querytemplates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/database"
+	"github.com/aegisshield/graph-engine/internal/neo4j"
+)
+
+// Execution statuses
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusRejected  = "rejected" // parameter values failed validation
+)
+
+var (
+	// ErrTemplateNotFound is returned for templates that do not exist or
+	// that the caller may not see
+	ErrTemplateNotFound = errors.New("query template not found")
+	// ErrNotPermitted is returned when the caller may see a template but
+	// not change it
+	ErrNotPermitted = errors.New("only the template owner or an administrator may change it")
+	// ErrVersionConflict is returned when a template changed since the
+	// version an update was based on
+	ErrVersionConflict = errors.New("query template was modified since it was read")
+	// ErrInvalidTemplate is wrapped by validation errors of template fields
+	ErrInvalidTemplate = errors.New("invalid query template")
+	// ErrInvalidParameters is wrapped by validation errors of parameter values
+	ErrInvalidParameters = errors.New("invalid query parameters")
+	// ErrExecutionTimeout is returned when a template runs past the execution timeout
+	ErrExecutionTimeout = errors.New("query template execution timed out")
+)
+
+var (
+	executions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "graph_engine_query_template_executions_total",
+		Help: "Query template executions by status",
+	}, []string{"status"})
+	executionDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "graph_engine_query_template_execution_duration_seconds",
+		Help:    "Time taken to run query templates",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 14),
+	})
+)
+
+// Store persists query templates and their execution history
+type Store interface {
+	CreateQueryTemplate(ctx context.Context, template *database.QueryTemplate) error
+	UpdateQueryTemplate(ctx context.Context, template *database.QueryTemplate, expectedVersion int) error
+	GetQueryTemplate(ctx context.Context, id string) (*database.QueryTemplate, error)
+	ListQueryTemplates(ctx context.Context, filter database.QueryTemplateFilter) ([]*database.QueryTemplate, error)
+	DeleteQueryTemplate(ctx context.Context, id string) error
+	RecordQueryExecution(ctx context.Context, execution *database.QueryExecution) error
+	ListQueryExecutions(ctx context.Context, filter database.QueryExecutionFilter) ([]*database.QueryExecution, error)
+}
+
+// QueryRunner runs read-only Cypher against the graph
+type QueryRunner interface {
+	ExecuteReadQuery(ctx context.Context, query string, params map[string]interface{}, maxRows int) (*neo4j.QueryResult, error)
+}
+
+// Caller identifies who is using templates
+type Caller struct {
+	UserID string
+	Roles  []string
+}
+
+// TemplateInput holds the editable fields of a template. Version is the
+// version an update is based on; zero skips the concurrency check.
+type TemplateInput struct {
+	Name        string                             `json:"name"`
+	Description string                             `json:"description"`
+	Query       string                             `json:"query"`
+	Parameters  []*database.QueryTemplateParameter `json:"parameters"`
+	Tags        []string                           `json:"tags"`
+	Visibility  string                             `json:"visibility"`
+	SharedRoles []string                           `json:"shared_roles"`
+	Version     int                                `json:"version"`
+}
+
+// ExecuteRequest runs a template with parameter values, optionally on
+// behalf of an investigation. Limit defaults to the configured default rows
+// and is capped at the configured maximum.
+type ExecuteRequest struct {
+	Parameters      map[string]interface{} `json:"parameters"`
+	InvestigationID string                 `json:"investigation_id"`
+	Limit           int                    `json:"limit"`
+}
+
+// ExecutionResult holds a template's rows and the recorded execution
+type ExecutionResult struct {
+	Execution *database.QueryExecution `json:"execution"`
+	Columns   []string                 `json:"columns"`
+	Rows      []map[string]interface{} `json:"rows"`
+	Truncated bool                     `json:"truncated"`
+}
+
+// Service manages saved, parameterized graph queries. Templates are private
+// to their owner, shared with roles, or public; only the owner and
+// administrators may change them. Every execution is recorded.
+type Service struct {
+	store  Store
+	graph  QueryRunner
+	cfg    config.QueryTemplateConfig
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewService creates a query template service
+func NewService(store Store, graph QueryRunner, cfg config.QueryTemplateConfig, logger *slog.Logger) *Service {
+	return &Service{
+		store:  store,
+		graph:  graph,
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Create validates and saves a new template owned by the caller
+func (s *Service) Create(ctx context.Context, caller Caller, input TemplateInput) (*database.QueryTemplate, error) {
+	now := s.now()
+	template := &database.QueryTemplate{
+		ID:        uuid.New().String(),
+		OwnerID:   caller.UserID,
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+		UpdatedBy: caller.UserID,
+	}
+	applyInput(template, input)
+
+	if problems := s.validateTemplate(template); len(problems) > 0 {
+		return nil, &ValidationError{Err: ErrInvalidTemplate, Fields: problems}
+	}
+
+	if err := s.store.CreateQueryTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// Update validates and saves new fields of a template as its next version
+func (s *Service) Update(ctx context.Context, caller Caller, id string, input TemplateInput) (*database.QueryTemplate, error) {
+	template, err := s.editable(ctx, caller, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Version != 0 && input.Version != template.Version {
+		return nil, ErrVersionConflict
+	}
+
+	expectedVersion := template.Version
+	applyInput(template, input)
+	template.Version++
+	template.UpdatedAt = s.now()
+	template.UpdatedBy = caller.UserID
+
+	if problems := s.validateTemplate(template); len(problems) > 0 {
+		return nil, &ValidationError{Err: ErrInvalidTemplate, Fields: problems}
+	}
+
+	if err := s.store.UpdateQueryTemplate(ctx, template, expectedVersion); err != nil {
+		if errors.Is(err, database.ErrQueryTemplateConflict) {
+			return nil, ErrVersionConflict
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+// Delete deletes a template, keeping its execution history
+func (s *Service) Delete(ctx context.Context, caller Caller, id string) error {
+	if _, err := s.editable(ctx, caller, id); err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteQueryTemplate(ctx, id); err != nil {
+		if errors.Is(err, database.ErrQueryTemplateNotFound) {
+			return ErrTemplateNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Get returns a template the caller may see
+func (s *Service) Get(ctx context.Context, caller Caller, id string) (*database.QueryTemplate, error) {
+	template, err := s.store.GetQueryTemplate(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrQueryTemplateNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+
+	if !s.canView(caller, template) {
+		return nil, ErrTemplateNotFound
+	}
+	return template, nil
+}
+
+// List returns the templates the caller may see, optionally with a tag
+func (s *Service) List(ctx context.Context, caller Caller, tag string) ([]*database.QueryTemplate, error) {
+	return s.store.ListQueryTemplates(ctx, database.QueryTemplateFilter{
+		UserID: caller.UserID,
+		Roles:  caller.Roles,
+		All:    s.isAdmin(caller),
+		Tag:    tag,
+	})
+}
+
+// Execute validates parameter values against a template and runs it in a
+// read transaction. Every attempt is recorded in the execution history,
+// including ones rejected for invalid values.
+func (s *Service) Execute(ctx context.Context, caller Caller, id string, request ExecuteRequest) (*ExecutionResult, error) {
+	template, err := s.Get(ctx, caller, id)
+	if err != nil {
+		return nil, err
+	}
+
+	execution := &database.QueryExecution{
+		ID:              uuid.New().String(),
+		TemplateID:      template.ID,
+		TemplateName:    template.Name,
+		TemplateVersion: template.Version,
+		Query:           template.Query,
+		Parameters:      request.Parameters,
+		UserID:          caller.UserID,
+		InvestigationID: request.InvestigationID,
+		ExecutedAt:      s.now(),
+	}
+
+	params, problems := bindParameters(template, request.Parameters)
+	if len(problems) > 0 {
+		validationErr := &ValidationError{Err: ErrInvalidParameters, Fields: problems}
+		execution.Status = StatusRejected
+		execution.Error = validationErr.Error()
+		s.record(ctx, execution)
+		return nil, validationErr
+	}
+	execution.Parameters = params
+
+	limit := request.Limit
+	if limit <= 0 {
+		limit = s.cfg.DefaultRows
+	}
+	if limit > s.cfg.MaxRows {
+		limit = s.cfg.MaxRows
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, s.cfg.ExecutionTimeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := s.graph.ExecuteReadQuery(runCtx, template.Query, params, limit)
+	elapsed := time.Since(start)
+	executionDuration.Observe(elapsed.Seconds())
+	execution.DurationMs = elapsed.Milliseconds()
+
+	if err != nil {
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("%w after %s", ErrExecutionTimeout, s.cfg.ExecutionTimeout)
+		}
+		execution.Status = StatusFailed
+		execution.Error = err.Error()
+		s.record(ctx, execution)
+		return nil, err
+	}
+
+	execution.Status = StatusSucceeded
+	execution.RowCount = len(result.Rows)
+	execution.Truncated = result.Truncated
+	s.record(ctx, execution)
+
+	return &ExecutionResult{
+		Execution: execution,
+		Columns:   result.Columns,
+		Rows:      result.Rows,
+		Truncated: result.Truncated,
+	}, nil
+}
+
+// History lists executions, newest first. Callers see their own executions,
+// and every execution of templates they own; administrators see all.
+func (s *Service) History(ctx context.Context, caller Caller, filter database.QueryExecutionFilter) ([]*database.QueryExecution, error) {
+	if filter.Limit <= 0 || filter.Limit > s.cfg.MaxHistoryLimit {
+		filter.Limit = s.cfg.MaxHistoryLimit
+	}
+
+	if !s.isAdmin(caller) {
+		owner := false
+		if filter.TemplateID != "" {
+			template, err := s.store.GetQueryTemplate(ctx, filter.TemplateID)
+			if err != nil && !errors.Is(err, database.ErrQueryTemplateNotFound) {
+				return nil, err
+			}
+			owner = template != nil && template.OwnerID == caller.UserID
+		}
+		if !owner {
+			filter.UserID = caller.UserID
+		}
+	}
+
+	return s.store.ListQueryExecutions(ctx, filter)
+}
+
+// editable returns a template the caller may change
+func (s *Service) editable(ctx context.Context, caller Caller, id string) (*database.QueryTemplate, error) {
+	template, err := s.Get(ctx, caller, id)
+	if err != nil {
+		return nil, err
+	}
+	if template.OwnerID != caller.UserID && !s.isAdmin(caller) {
+		return nil, ErrNotPermitted
+	}
+	return template, nil
+}
+
+func (s *Service) canView(caller Caller, template *database.QueryTemplate) bool {
+	switch {
+	case template.OwnerID == caller.UserID, template.Visibility == VisibilityPublic, s.isAdmin(caller):
+		return true
+	case template.Visibility == VisibilityRoles:
+		return hasAnyRole(caller.Roles, template.SharedRoles)
+	}
+	return false
+}
+
+func (s *Service) isAdmin(caller Caller) bool {
+	return hasAnyRole(caller.Roles, s.cfg.AdminRoles)
+}
+
+// record stores an execution; a failure is logged rather than failing the
+// query the caller already ran
+func (s *Service) record(ctx context.Context, execution *database.QueryExecution) {
+	executions.WithLabelValues(execution.Status).Inc()
+
+	if err := s.store.RecordQueryExecution(ctx, execution); err != nil {
+		s.logger.Error("Failed to record query template execution",
+			"template_id", execution.TemplateID,
+			"user_id", execution.UserID,
+			"error", err)
+	}
+}
+
+func applyInput(template *database.QueryTemplate, input TemplateInput) {
+	template.Name = strings.TrimSpace(input.Name)
+	template.Description = input.Description
+	template.Query = input.Query
+	template.Parameters = input.Parameters
+	template.Tags = normalize(input.Tags)
+	template.Visibility = input.Visibility
+	if template.Visibility == "" {
+		template.Visibility = VisibilityPrivate
+	}
+	template.SharedRoles = normalize(input.SharedRoles)
+	if template.Parameters == nil {
+		template.Parameters = []*database.QueryTemplateParameter{}
+	}
+}
+
+// normalize trims values and drops empty and repeated ones
+func normalize(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	normalized := []string{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !seen[value] {
+			seen[value] = true
+			normalized = append(normalized, value)
+		}
+	}
+	return normalized
+}
+
+func hasAnyRole(roles, wanted []string) bool {
+	for _, role := range roles {
+		for _, candidate := range wanted {
+			if strings.EqualFold(role, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
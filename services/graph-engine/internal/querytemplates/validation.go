@@ -0,0 +1,417 @@
+package querytemplates
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aegisshield/graph-engine/internal/database"
+	"github.com/aegisshield/graph-engine/internal/tenancy"
+)
+
+// Parameter types
+const (
+	TypeString       = "string"
+	TypeInteger      = "integer"
+	TypeFloat        = "float"
+	TypeBoolean      = "boolean"
+	TypeDateTime     = "datetime"
+	TypeEntityID     = "entity_id"
+	TypeStringList   = "string_list"
+	TypeEntityIDList = "entity_id_list"
+)
+
+// Template visibilities
+const (
+	VisibilityPrivate = "private" // the owner only
+	VisibilityRoles   = "roles"   // the owner and callers with a shared role
+	VisibilityPublic  = "public"  // every caller
+)
+
+const maxNameLength = 255
+
+var (
+	parameterTypes = map[string]bool{
+		TypeString:       true,
+		TypeInteger:      true,
+		TypeFloat:        true,
+		TypeBoolean:      true,
+		TypeDateTime:     true,
+		TypeEntityID:     true,
+		TypeStringList:   true,
+		TypeEntityIDList: true,
+	}
+
+	parameterName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+	// literals matches comments, string literals and quoted identifiers, so
+	// keywords and parameters are only looked for in the query's own text
+	literals = regexp.MustCompile("(?s)//[^\n]*|/\\*.*?\\*/|'(?:[^'\\\\]|\\\\.)*'|\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`")
+
+	// writeClauses catches queries that would change the graph or call
+	// administrative procedures. Templates also run in read transactions,
+	// which Neo4j refuses to write in; this only fails them when saved.
+	writeClauses = regexp.MustCompile(`(?i)\b(CREATE|MERGE|DELETE|DETACH|SET|REMOVE|DROP|FOREACH|LOAD\s+CSV|IN\s+TRANSACTIONS|CALL\s+(dbms|apoc\.(create|merge|refactor|periodic|trigger|schema|nodes\.delete)|db\.(create|index|clear)))\b`)
+
+	parameterRef = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// ValidationError reports invalid template fields or parameter values, by
+// field or parameter name
+type ValidationError struct {
+	Err    error
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	problems := make([]string, len(names))
+	for i, name := range names {
+		problems[i] = name + ": " + e.Fields[name]
+	}
+	return fmt.Sprintf("%s: %s", e.Err, strings.Join(problems, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// validateTemplate checks a template's query is read-only, references only
+// its declared parameters, and that every parameter declaration is sound
+func (s *Service) validateTemplate(template *database.QueryTemplate) map[string]string {
+	problems := make(map[string]string)
+
+	if template.Name == "" {
+		problems["name"] = "is required"
+	} else if utf8.RuneCountInString(template.Name) > maxNameLength {
+		problems["name"] = fmt.Sprintf("must be at most %d characters", maxNameLength)
+	}
+
+	switch template.Visibility {
+	case VisibilityRoles:
+		if len(template.SharedRoles) == 0 {
+			problems["shared_roles"] = "are required to share with roles"
+		}
+	case VisibilityPrivate, VisibilityPublic:
+		if len(template.SharedRoles) > 0 {
+			problems["shared_roles"] = "only apply to roles visibility"
+		}
+	default:
+		problems["visibility"] = "must be private, roles or public"
+	}
+
+	if len(template.Parameters) > s.cfg.MaxParameters {
+		problems["parameters"] = fmt.Sprintf("at most %d parameters are allowed", s.cfg.MaxParameters)
+	}
+
+	declared := make(map[string]bool, len(template.Parameters))
+	for i, spec := range template.Parameters {
+		if spec == nil || !parameterName.MatchString(spec.Name) {
+			problems[fmt.Sprintf("parameters[%d].name", i)] = "must be a letter or underscore followed by up to 63 letters, digits or underscores"
+			continue
+		}
+		if declared[spec.Name] {
+			problems["parameters."+spec.Name] = "is declared more than once"
+			continue
+		}
+		declared[spec.Name] = true
+
+		if problem := validateSpec(spec); problem != "" {
+			problems["parameters."+spec.Name] = problem
+		}
+	}
+
+	query := strings.TrimSpace(template.Query)
+	switch {
+	case query == "":
+		problems["query"] = "is required"
+	case len(query) > s.cfg.MaxQueryLength:
+		problems["query"] = fmt.Sprintf("must be at most %d bytes", s.cfg.MaxQueryLength)
+	case tenancy.CheckQuery(query) != nil:
+		problems["query"] = "must not select a database with USE"
+	default:
+		text := literals.ReplaceAllString(query, " ")
+		if match := writeClauses.FindString(text); match != "" {
+			problems["query"] = fmt.Sprintf("must be read-only, found %s", strings.ToUpper(match))
+			break
+		}
+
+		referenced := make(map[string]bool)
+		for _, match := range parameterRef.FindAllStringSubmatch(text, -1) {
+			referenced[match[1]] = true
+		}
+		for _, name := range sortedNames(referenced) {
+			if !declared[name] {
+				problems["query"] = fmt.Sprintf("references undeclared parameter $%s", name)
+				break
+			}
+		}
+		for _, spec := range template.Parameters {
+			if spec != nil && declared[spec.Name] && !referenced[spec.Name] {
+				problems["parameters."+spec.Name] = "is not referenced by the query"
+			}
+		}
+	}
+
+	return problems
+}
+
+// validateSpec checks a parameter's constraints suit its type and that its
+// default satisfies them
+func validateSpec(spec *database.QueryTemplateParameter) string {
+	if !parameterTypes[spec.Type] {
+		return fmt.Sprintf("unknown type %q", spec.Type)
+	}
+
+	textual := isTextual(spec.Type)
+	numeric := spec.Type == TypeInteger || spec.Type == TypeFloat
+
+	switch {
+	case spec.Pattern != "" && !textual:
+		return "pattern only applies to string and entity ID parameters"
+	case len(spec.AllowedValues) > 0 && !textual:
+		return "allowed_values only apply to string and entity ID parameters"
+	case spec.MaxLength != 0 && !textual:
+		return "max_length only applies to string and entity ID parameters"
+	case spec.MaxLength < 0:
+		return "max_length must not be negative"
+	case (spec.Min != nil || spec.Max != nil) && !numeric:
+		return "min and max only apply to numeric parameters"
+	case spec.Min != nil && spec.Max != nil && *spec.Min > *spec.Max:
+		return "min must not exceed max"
+	case spec.MaxItems != 0 && !isList(spec.Type):
+		return "max_items only applies to list parameters"
+	case spec.MaxItems < 0:
+		return "max_items must not be negative"
+	}
+
+	if spec.Pattern != "" {
+		if _, err := regexp.Compile(anchored(spec.Pattern)); err != nil {
+			return fmt.Sprintf("invalid pattern: %v", err)
+		}
+	}
+
+	if spec.Default != nil {
+		if _, err := bindValue(spec, spec.Default); err != nil {
+			return fmt.Sprintf("invalid default: %v", err)
+		}
+	}
+
+	return ""
+}
+
+// bindParameters validates values against a template's parameters and
+// returns the query parameters, with defaults for values not given
+func bindParameters(template *database.QueryTemplate, values map[string]interface{}) (map[string]interface{}, map[string]string) {
+	params := make(map[string]interface{}, len(template.Parameters))
+	problems := make(map[string]string)
+
+	declared := make(map[string]bool, len(template.Parameters))
+	for _, spec := range template.Parameters {
+		declared[spec.Name] = true
+
+		value, given := values[spec.Name]
+		if !given || value == nil {
+			value = spec.Default
+		}
+		if value == nil {
+			if spec.Required {
+				problems[spec.Name] = "is required"
+			}
+			params[spec.Name] = nil
+			continue
+		}
+
+		bound, err := bindValue(spec, value)
+		if err != nil {
+			problems[spec.Name] = err.Error()
+			continue
+		}
+		params[spec.Name] = bound
+	}
+
+	for name := range values {
+		if !declared[name] {
+			problems[name] = "is not a parameter of this template"
+		}
+	}
+
+	return params, problems
+}
+
+// bindValue converts a JSON value to the parameter's type and checks it
+// against the parameter's constraints
+func bindValue(spec *database.QueryTemplateParameter, value interface{}) (interface{}, error) {
+	switch spec.Type {
+	case TypeString, TypeEntityID:
+		return bindText(spec, value)
+
+	case TypeStringList, TypeEntityIDList:
+		items, ok := value.([]interface{})
+		if !ok {
+			if strs, isStrings := value.([]string); isStrings {
+				items = make([]interface{}, len(strs))
+				for i, str := range strs {
+					items[i] = str
+				}
+			} else {
+				return nil, fmt.Errorf("must be a list of strings")
+			}
+		}
+		if len(items) == 0 {
+			return nil, fmt.Errorf("must not be empty")
+		}
+		if spec.MaxItems > 0 && len(items) > spec.MaxItems {
+			return nil, fmt.Errorf("must have at most %d items", spec.MaxItems)
+		}
+
+		bound := make([]string, len(items))
+		for i, item := range items {
+			text, err := bindText(spec, item)
+			if err != nil {
+				return nil, fmt.Errorf("item %d %v", i, err)
+			}
+			bound[i] = text
+		}
+		return bound, nil
+
+	case TypeInteger:
+		number, err := toNumber(value)
+		if err != nil {
+			return nil, err
+		}
+		if number != float64(int64(number)) {
+			return nil, fmt.Errorf("must be a whole number")
+		}
+		if err := checkRange(spec, number); err != nil {
+			return nil, err
+		}
+		return int64(number), nil
+
+	case TypeFloat:
+		number, err := toNumber(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkRange(spec, number); err != nil {
+			return nil, err
+		}
+		return number, nil
+
+	case TypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf("must be true or false")
+
+	case TypeDateTime:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be an RFC3339 timestamp")
+		}
+		t, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return nil, fmt.Errorf("must be an RFC3339 timestamp")
+		}
+		return t, nil
+	}
+
+	return nil, fmt.Errorf("unknown type %q", spec.Type)
+}
+
+func bindText(spec *database.QueryTemplateParameter, value interface{}) (string, error) {
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("must be a string")
+	}
+
+	if isEntityType(spec.Type) && (text == "" || strings.ContainsAny(text, " \t\r\n")) {
+		return "", fmt.Errorf("must be a non-empty entity ID without whitespace")
+	}
+	if spec.MaxLength > 0 && utf8.RuneCountInString(text) > spec.MaxLength {
+		return "", fmt.Errorf("must be at most %d characters", spec.MaxLength)
+	}
+	if spec.Pattern != "" && !regexp.MustCompile(anchored(spec.Pattern)).MatchString(text) {
+		return "", fmt.Errorf("must match %s", spec.Pattern)
+	}
+	if len(spec.AllowedValues) > 0 {
+		allowed := false
+		for _, candidate := range spec.AllowedValues {
+			if text == candidate {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return "", fmt.Errorf("must be one of %s", strings.Join(spec.AllowedValues, ", "))
+		}
+	}
+
+	return text, nil
+}
+
+func toNumber(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case string:
+		if number, err := strconv.ParseFloat(v, 64); err == nil {
+			return number, nil
+		}
+	}
+	return 0, fmt.Errorf("must be a number")
+}
+
+func checkRange(spec *database.QueryTemplateParameter, number float64) error {
+	if spec.Min != nil && number < *spec.Min {
+		return fmt.Errorf("must be at least %v", *spec.Min)
+	}
+	if spec.Max != nil && number > *spec.Max {
+		return fmt.Errorf("must be at most %v", *spec.Max)
+	}
+	return nil
+}
+
+// anchored makes a parameter pattern match whole values
+func anchored(pattern string) string {
+	return `^(?:` + pattern + `)$`
+}
+
+func isTextual(parameterType string) bool {
+	return parameterType == TypeString || isEntityType(parameterType) || parameterType == TypeStringList
+}
+
+func isEntityType(parameterType string) bool {
+	return parameterType == TypeEntityID || parameterType == TypeEntityIDList
+}
+
+func isList(parameterType string) bool {
+	return parameterType == TypeStringList || parameterType == TypeEntityIDList
+}
+
+func sortedNames(names map[string]bool) []string {
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
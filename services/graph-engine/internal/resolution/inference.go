@@ -0,0 +1,536 @@
+package resolution
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Inferred relationship types
+const (
+	RelationshipSharedCounterparty = "SHARED_COUNTERPARTY"
+	RelationshipTemporalCoActivity = "TEMPORAL_CO_ACTIVITY"
+	RelationshipSimilarBehavior    = "SIMILAR_BEHAVIOR"
+	RelationshipSharedAttribute    = "SHARED_ATTRIBUTE"
+)
+
+// Inference defaults, each overridable through the request parameters
+const (
+	defaultMinSharedCounterparties = 2
+	defaultMaxCounterpartyDegree   = 100 // busier counterparties, such as large merchants, say little about a pair
+	defaultCoOccurrenceWindow      = 15 * time.Minute
+	defaultActivityLookback        = 90 * 24 * time.Hour
+	defaultMinCoOccurrences        = 3
+	defaultMinTransactions         = 5
+	defaultMaxCandidates           = 200
+	defaultMaxAttributeSharers     = 20 // addresses of registered agents and the like link too many entities to mean anything
+	defaultMaxInferenceResults     = 500
+)
+
+// attributeWeights are how strongly sharing an attribute node of each label
+// links two entities; a device is rarely shared, an address often is
+var attributeWeights = map[string]float64{
+	"Device":  0.9,
+	"Phone":   0.7,
+	"Email":   0.7,
+	"Address": 0.5,
+}
+
+// relationshipTypePattern is what a relationship type followed during
+// inference may look like; types are interpolated into Cypher
+var relationshipTypePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// inferTransactionalRelationships links entities that transact with the
+// same counterparties. Confidence grows with the overlap of the two
+// entities' counterparties (Jaccard) and with the number shared.
+func (er *EntityResolver) inferTransactionalRelationships(ctx context.Context, req *RelationshipInferenceRequest) ([]*InferredRelationship, error) {
+	minShared := intParameter(req.Parameters, "min_shared_counterparties", defaultMinSharedCounterparties)
+
+	query := `
+		MATCH (a:Entity)-[t1:TRANSACTION]-(c:Entity)-[t2:TRANSACTION]-(b:Entity)
+		WHERE a.id IN $entityIds AND b <> a
+		  AND NOT (b.id IN $entityIds AND b.id < a.id)
+		  AND size([(c)-[:TRANSACTION]-() | 1]) <= $maxCounterpartyDegree
+		WITH a, b, collect(DISTINCT c.id) AS counterparties,
+			 count(DISTINCT t1) AS sourceTransactions,
+			 count(DISTINCT t2) AS targetTransactions
+		WHERE size(counterparties) >= $minShared
+		MATCH (a)-[:TRANSACTION]-(ac:Entity)
+		WITH a, b, counterparties, sourceTransactions, targetTransactions,
+			 count(DISTINCT ac) AS sourceCounterparties
+		MATCH (b)-[:TRANSACTION]-(bc:Entity)
+		WITH a, b, counterparties, sourceTransactions, targetTransactions, sourceCounterparties,
+			 count(DISTINCT bc) AS targetCounterparties
+		RETURN a.id AS sourceId, b.id AS targetId, counterparties,
+			   sourceTransactions, targetTransactions,
+			   sourceCounterparties, targetCounterparties
+		ORDER BY size(counterparties) DESC
+		LIMIT $maxResults
+	`
+
+	records, err := er.neo4jClient.ExecuteQuery(ctx, query, map[string]interface{}{
+		"entityIds":             req.EntityIDs,
+		"minShared":             minShared,
+		"maxCounterpartyDegree": intParameter(req.Parameters, "max_counterparty_degree", defaultMaxCounterpartyDegree),
+		"maxResults":            intParameter(req.Parameters, "max_results", defaultMaxInferenceResults),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute co-transaction query: %w", err)
+	}
+
+	relationships := make([]*InferredRelationship, 0, len(records))
+	for _, record := range records {
+		counterparties := stringList(record["counterparties"])
+		shared := float64(len(counterparties))
+		union := getFloat64(record, "sourceCounterparties") + getFloat64(record, "targetCounterparties") - shared
+		if shared < float64(minShared) || union <= 0 {
+			continue
+		}
+
+		jaccard := shared / union
+		support := 1 - math.Exp(-shared/float64(minShared))
+		confidence := round(0.6*jaccard + 0.4*support)
+
+		sort.Strings(counterparties)
+		relationships = append(relationships, newInferredRelationship(record, RelationshipSharedCounterparty, InferenceStrategyTransactional, confidence, RelationshipEvidence{
+			EvidenceType: "co_transaction",
+			Description:  fmt.Sprintf("Both entities transact with %d of the same counterparties", len(counterparties)),
+			Strength:     round(jaccard),
+			Source:       "transaction_graph",
+			Metadata: map[string]interface{}{
+				"counterparties":      counterparties,
+				"jaccard":             round(jaccard),
+				"source_transactions": getFloat64(record, "sourceTransactions"),
+				"target_transactions": getFloat64(record, "targetTransactions"),
+			},
+		}))
+	}
+
+	return relationships, nil
+}
+
+// inferTemporalRelationships links entities whose transactions repeatedly
+// happen within a short window of each other, excluding their transactions
+// with each other
+func (er *EntityResolver) inferTemporalRelationships(ctx context.Context, req *RelationshipInferenceRequest) ([]*InferredRelationship, error) {
+	window := durationParameter(req.Parameters, "window", defaultCoOccurrenceWindow)
+	minCoOccurrences := intParameter(req.Parameters, "min_co_occurrences", defaultMinCoOccurrences)
+
+	activity, err := er.transactionActivity(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	relationships := make([]*InferredRelationship, 0)
+	for _, pair := range activityPairs(req.EntityIDs, activity) {
+		source := eventsExcluding(activity[pair[0]], pair[1])
+		target := eventsExcluding(activity[pair[1]], pair[0])
+		if len(source) == 0 || len(target) == 0 {
+			continue
+		}
+
+		matches, first, last := coOccurrences(source, target, window)
+		if matches < minCoOccurrences {
+			continue
+		}
+
+		ratio := float64(matches) / float64(minInt(len(source), len(target)))
+		support := 1 - math.Exp(-float64(matches)/float64(minCoOccurrences))
+		confidence := round(ratio * support)
+
+		record := map[string]interface{}{"sourceId": pair[0], "targetId": pair[1]}
+		relationships = append(relationships, newInferredRelationship(record, RelationshipTemporalCoActivity, InferenceStrategyTemporal, confidence, RelationshipEvidence{
+			EvidenceType: "temporal_co_occurrence",
+			Description:  fmt.Sprintf("%d transactions of each entity within %s of the other's", matches, window),
+			Strength:     round(ratio),
+			Source:       "transaction_graph",
+			Metadata: map[string]interface{}{
+				"window":              window.String(),
+				"co_occurrences":      matches,
+				"source_events":       len(source),
+				"target_events":       len(target),
+				"first_co_occurrence": first.UTC(),
+				"last_co_occurrence":  last.UTC(),
+			},
+		}))
+	}
+
+	return relationships, nil
+}
+
+// inferBehavioralRelationships links entities whose transactions look
+// alike: the same hours of the day and the same orders of magnitude,
+// leaving out their transactions with each other
+func (er *EntityResolver) inferBehavioralRelationships(ctx context.Context, req *RelationshipInferenceRequest) ([]*InferredRelationship, error) {
+	minTransactions := intParameter(req.Parameters, "min_transactions", defaultMinTransactions)
+
+	activity, err := er.transactionActivity(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	relationships := make([]*InferredRelationship, 0)
+	for _, pair := range activityPairs(req.EntityIDs, activity) {
+		// Transactions with each other would make any two counterparties look alike
+		sourceEvents := eventsExcluding(activity[pair[0]], pair[1])
+		targetEvents := eventsExcluding(activity[pair[1]], pair[0])
+		if len(sourceEvents) < minTransactions || len(targetEvents) < minTransactions {
+			continue
+		}
+		source, target := newBehaviorProfile(sourceEvents), newBehaviorProfile(targetEvents)
+
+		hourSimilarity := cosine(source.hours[:], target.hours[:])
+		amountSimilarity := cosine(source.amounts[:], target.amounts[:])
+		confidence := round(0.5*hourSimilarity + 0.5*amountSimilarity)
+
+		record := map[string]interface{}{"sourceId": pair[0], "targetId": pair[1]}
+		relationships = append(relationships, newInferredRelationship(record, RelationshipSimilarBehavior, InferenceStrategyBehavioral, confidence,
+			RelationshipEvidence{
+				EvidenceType: "hour_of_day_profile",
+				Description:  "Transactions fall in the same hours of the day",
+				Strength:     round(hourSimilarity),
+				Source:       "transaction_graph",
+				Metadata:     map[string]interface{}{"source_transactions": source.count, "target_transactions": target.count},
+			},
+			RelationshipEvidence{
+				EvidenceType: "amount_profile",
+				Description:  "Transaction amounts are of the same orders of magnitude",
+				Strength:     round(amountSimilarity),
+				Source:       "transaction_graph",
+				Metadata:     map[string]interface{}{"source_average": round(source.average()), "target_average": round(target.average())},
+			},
+		))
+	}
+
+	return relationships, nil
+}
+
+// inferNetworkRelationships links entities connected to the same address,
+// phone, device or email node. Each shared attribute is independent
+// evidence, so confidence is one minus the chance every link is a
+// coincidence.
+func (er *EntityResolver) inferNetworkRelationships(ctx context.Context, req *RelationshipInferenceRequest) ([]*InferredRelationship, error) {
+	labels := make([]string, 0, len(attributeWeights))
+	for label := range attributeWeights {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	query := `
+		MATCH (a:Entity)--(s)
+		WHERE a.id IN $entityIds
+		  AND any(label IN labels(s) WHERE label IN $attributeLabels)
+		  AND size([(s)--() | 1]) <= $maxSharers
+		MATCH (s)--(b:Entity)
+		WHERE b <> a
+		  AND NOT any(label IN labels(b) WHERE label IN $attributeLabels)
+		  AND NOT (b.id IN $entityIds AND b.id < a.id)
+		WITH a, b, collect(DISTINCT {
+			label: [label IN labels(s) WHERE label IN $attributeLabels][0],
+			id: coalesce(s.id, elementId(s)),
+			value: coalesce(s.value, s.id)
+		}) AS sharedAttributes
+		RETURN a.id AS sourceId, b.id AS targetId, sharedAttributes
+		ORDER BY size(sharedAttributes) DESC
+		LIMIT $maxResults
+	`
+
+	records, err := er.neo4jClient.ExecuteQuery(ctx, query, map[string]interface{}{
+		"entityIds":       req.EntityIDs,
+		"attributeLabels": labels,
+		"maxSharers":      intParameter(req.Parameters, "max_attribute_sharers", defaultMaxAttributeSharers),
+		"maxResults":      intParameter(req.Parameters, "max_results", defaultMaxInferenceResults),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute shared attribute query: %w", err)
+	}
+
+	relationships := make([]*InferredRelationship, 0, len(records))
+	for _, record := range records {
+		attributes, _ := record["sharedAttributes"].([]interface{})
+
+		coincidence := 1.0
+		evidence := make([]RelationshipEvidence, 0, len(attributes))
+		for _, value := range attributes {
+			attribute, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			label, _ := attribute["label"].(string)
+			weight := attributeWeights[label]
+			coincidence *= 1 - weight
+
+			evidence = append(evidence, RelationshipEvidence{
+				EvidenceType: "shared_" + strings.ToLower(label),
+				Description:  fmt.Sprintf("Both entities are linked to %s %v", strings.ToLower(label), attribute["value"]),
+				Strength:     weight,
+				Source:       "entity_graph",
+				Metadata:     map[string]interface{}{"attribute_id": attribute["id"], "value": attribute["value"]},
+			})
+		}
+		if len(evidence) == 0 {
+			continue
+		}
+		sort.Slice(evidence, func(i, j int) bool { return evidence[i].Strength > evidence[j].Strength })
+
+		relationships = append(relationships, newInferredRelationship(record, RelationshipSharedAttribute, InferenceStrategyNetwork, round(1-coincidence), evidence...))
+	}
+
+	return relationships, nil
+}
+
+// activityEvent is one transaction of an entity
+type activityEvent struct {
+	at           time.Time
+	counterparty string
+	amount       float64
+}
+
+// transactionActivity loads the recent transactions of the requested
+// entities and of the entities within MaxDepth hops of them
+func (er *EntityResolver) transactionActivity(ctx context.Context, req *RelationshipInferenceRequest) (map[string][]activityEvent, error) {
+	for _, relType := range req.RelationshipTypes {
+		if !relationshipTypePattern.MatchString(relType) {
+			return nil, fmt.Errorf("invalid relationship type: %q", relType)
+		}
+	}
+	typeFilter := ""
+	if len(req.RelationshipTypes) > 0 {
+		typeFilter = ":" + strings.Join(req.RelationshipTypes, "|")
+	}
+
+	depth := req.MaxDepth
+	if depth <= 0 || depth > er.config.MaxTraversalDepth {
+		depth = minInt(2, er.config.MaxTraversalDepth)
+	}
+
+	// Variable-length bounds cannot be parameters, so the bounded depth and
+	// validated types are written into the pattern
+	query := fmt.Sprintf(`
+		MATCH (a:Entity)
+		WHERE a.id IN $entityIds
+		OPTIONAL MATCH (a)-[%s*1..%d]-(n:Entity)
+		WITH collect(DISTINCT a) + collect(DISTINCT n) AS candidates
+		UNWIND candidates AS e
+		WITH DISTINCT e
+		LIMIT $maxCandidates
+		MATCH (e)-[t:TRANSACTION]-(other:Entity)
+		WHERE t.timestamp IS NOT NULL
+		  AND datetime(t.timestamp) >= datetime() - duration({seconds: $lookbackSeconds})
+		RETURN e.id AS entityId,
+			   collect({at: datetime(t.timestamp).epochMillis, counterparty: other.id, amount: coalesce(t.amount, 0.0)}) AS events
+	`, typeFilter, depth)
+
+	lookback := durationParameter(req.Parameters, "lookback", defaultActivityLookback)
+	records, err := er.neo4jClient.ExecuteQuery(ctx, query, map[string]interface{}{
+		"entityIds":       req.EntityIDs,
+		"maxCandidates":   intParameter(req.Parameters, "max_candidates", defaultMaxCandidates),
+		"lookbackSeconds": int64(lookback.Seconds()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute transaction activity query: %w", err)
+	}
+
+	activity := make(map[string][]activityEvent, len(records))
+	for _, record := range records {
+		entityID, _ := record["entityId"].(string)
+		values, _ := record["events"].([]interface{})
+
+		events := make([]activityEvent, 0, len(values))
+		for _, value := range values {
+			event, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			counterparty, _ := event["counterparty"].(string)
+			events = append(events, activityEvent{
+				at:           time.UnixMilli(int64(getFloat64(event, "at"))),
+				counterparty: counterparty,
+				amount:       getFloat64(event, "amount"),
+			})
+		}
+		sort.Slice(events, func(i, j int) bool { return events[i].at.Before(events[j].at) })
+		activity[entityID] = events
+	}
+
+	return activity, nil
+}
+
+// activityPairs pairs each requested entity with every other entity with
+// activity, once per pair
+func activityPairs(requested []string, activity map[string][]activityEvent) [][2]string {
+	isRequested := make(map[string]bool, len(requested))
+	for _, id := range requested {
+		isRequested[id] = true
+	}
+
+	others := make([]string, 0, len(activity))
+	for id := range activity {
+		others = append(others, id)
+	}
+	sort.Strings(others)
+
+	var pairs [][2]string
+	seen := make(map[[2]string]bool)
+	for _, source := range requested {
+		if _, ok := activity[source]; !ok {
+			continue
+		}
+		for _, target := range others {
+			if target == source || (isRequested[target] && seen[[2]string{target, source}]) {
+				continue
+			}
+			pair := [2]string{source, target}
+			if !seen[pair] {
+				seen[pair] = true
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	return pairs
+}
+
+func eventsExcluding(events []activityEvent, counterparty string) []activityEvent {
+	kept := make([]activityEvent, 0, len(events))
+	for _, event := range events {
+		if event.counterparty != counterparty {
+			kept = append(kept, event)
+		}
+	}
+	return kept
+}
+
+// coOccurrences matches the two sorted event lists one to one within the
+// window, returning the number of matches and when the first and last
+// happened
+func coOccurrences(source, target []activityEvent, window time.Duration) (int, time.Time, time.Time) {
+	var matches int
+	var first, last time.Time
+
+	i, j := 0, 0
+	for i < len(source) && j < len(target) {
+		gap := source[i].at.Sub(target[j].at)
+		switch {
+		case gap <= window && gap >= -window:
+			if matches == 0 {
+				first = source[i].at
+			}
+			last = source[i].at
+			matches++
+			i++
+			j++
+		case gap < 0:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches, first, last
+}
+
+// behaviorProfile is an entity's transactions by hour of day (UTC) and by
+// order of magnitude of the amount
+type behaviorProfile struct {
+	hours   [24]float64
+	amounts [8]float64
+	total   float64
+	count   int
+}
+
+func newBehaviorProfile(events []activityEvent) *behaviorProfile {
+	profile := &behaviorProfile{count: len(events)}
+	for _, event := range events {
+		profile.hours[event.at.UTC().Hour()]++
+
+		magnitude := 0
+		if event.amount >= 1 {
+			magnitude = int(math.Log10(event.amount))
+		}
+		if magnitude >= len(profile.amounts) {
+			magnitude = len(profile.amounts) - 1
+		}
+		profile.amounts[magnitude]++
+		profile.total += event.amount
+	}
+	return profile
+}
+
+func (p *behaviorProfile) average() float64 {
+	if p.count == 0 {
+		return 0
+	}
+	return p.total / float64(p.count)
+}
+
+func cosine(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+func newInferredRelationship(record map[string]interface{}, relType string, strategy InferenceStrategy, confidence float64, evidence ...RelationshipEvidence) *InferredRelationship {
+	source, _ := record["sourceId"].(string)
+	target, _ := record["targetId"].(string)
+	return &InferredRelationship{
+		ID:             uuid.New().String(),
+		SourceEntityID: source,
+		TargetEntityID: target,
+		Type:           relType,
+		Confidence:     confidence,
+		Evidence:       evidence,
+		InferredAt:     time.Now(),
+		Metadata:       map[string]interface{}{"strategy": string(strategy)},
+	}
+}
+
+func intParameter(parameters map[string]interface{}, key string, fallback int) int {
+	if value := getFloat64(parameters, key); value > 0 {
+		return int(value)
+	}
+	return fallback
+}
+
+// durationParameter reads a duration such as "15m" from the parameters
+func durationParameter(parameters map[string]interface{}, key string, fallback time.Duration) time.Duration {
+	if value, ok := parameters[key].(string); ok {
+		if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return fallback
+}
+
+func stringList(value interface{}) []string {
+	values, _ := value.([]interface{})
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+func round(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -2,6 +2,7 @@ package resolution
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
@@ -9,15 +10,21 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aegisshield/graph-engine/internal/blocking"
 	"github.com/aegisshield/graph-engine/internal/config"
-	"github.com/aegisshield/graph-engine/internal/neo4j"
 	"github.com/google/uuid"
 )
 
+// QueryRunner runs Cypher against the graph
+type QueryRunner interface {
+	ExecuteQuery(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error)
+}
+
 // EntityResolver performs entity resolution and relationship inference
 type EntityResolver struct {
-	neo4jClient *neo4j.Client
+	neo4jClient QueryRunner
 	config      config.GraphEngineConfig
+	blocker     *blocking.Blocker
 	logger      *slog.Logger
 }
 
@@ -178,7 +185,7 @@ type InferenceStatistics struct {
 }
 
 // NewEntityResolver creates a new entity resolver
-func NewEntityResolver(client *neo4j.Client, config config.GraphEngineConfig, logger *slog.Logger) *EntityResolver {
+func NewEntityResolver(client QueryRunner, config config.GraphEngineConfig, logger *slog.Logger) *EntityResolver {
 	return &EntityResolver{
 		neo4jClient: client,
 		config:      config,
@@ -186,6 +193,12 @@ func NewEntityResolver(client *neo4j.Client, config config.GraphEngineConfig, lo
 	}
 }
 
+// SetBlocker makes similarity matching retrieve candidates by blocking
+// rather than scanning every entity of a type
+func (er *EntityResolver) SetBlocker(blocker *blocking.Blocker) {
+	er.blocker = blocker
+}
+
 // ResolveEntities performs entity resolution on candidate entities
 func (er *EntityResolver) ResolveEntities(ctx context.Context, req *ResolutionRequest) (*ResolutionResult, error) {
 	startTime := time.Now()
@@ -387,9 +400,21 @@ func (er *EntityResolver) findFuzzyMatches(ctx context.Context, candidate *Candi
 func (er *EntityResolver) findMLSimilarityMatches(ctx context.Context, candidate *CandidateEntity, req *ResolutionRequest) ([]*EntityMatch, error) {
 	// This would integrate with ML models for semantic similarity
 	// For now, implement a simplified version using attribute similarity
-	
-	matches := make([]*EntityMatch, 0)
-	
+
+	if er.blocker != nil {
+		records, err := er.blocker.Candidates(ctx, candidate.Type, candidate.Attributes)
+		if err == nil {
+			matches := er.scoreMLSimilarity(candidate, blockedRecords(records), req)
+			if er.blocker.SampleRecall() {
+				er.sampleBlockingRecall(ctx, candidate, req, matches)
+			}
+			return matches, nil
+		}
+		if !errors.Is(err, blocking.ErrNotBlocked) {
+			return nil, fmt.Errorf("failed to get ML similarity candidates: %w", err)
+		}
+	}
+
 	// Get potential candidates based on type
 	query := `
 		MATCH (e:` + candidate.Type + `)
@@ -402,6 +427,12 @@ func (er *EntityResolver) findMLSimilarityMatches(ctx context.Context, candidate
 		return nil, fmt.Errorf("failed to get ML similarity candidates: %w", err)
 	}
 
+	return er.scoreMLSimilarity(candidate, records, req), nil
+}
+
+// scoreMLSimilarity returns the records similar enough to the candidate
+func (er *EntityResolver) scoreMLSimilarity(candidate *CandidateEntity, records []map[string]interface{}, req *ResolutionRequest) []*EntityMatch {
+	matches := make([]*EntityMatch, 0)
 	for _, record := range records {
 		similarity := er.calculateMLSimilarity(candidate, record)
 		if similarity >= req.SimilarityThreshold {
@@ -415,8 +446,46 @@ func (er *EntityResolver) findMLSimilarityMatches(ctx context.Context, candidate
 			matches = append(matches, match)
 		}
 	}
+	return matches
+}
 
-	return matches, nil
+// sampleBlockingRecall matches a candidate against an unblocked scan of its
+// type and records how many of those matches blocking also found
+func (er *EntityResolver) sampleBlockingRecall(ctx context.Context, candidate *CandidateEntity, req *ResolutionRequest, matches []*EntityMatch) {
+	records, err := er.blocker.Scan(ctx, candidate.Type)
+	if err != nil {
+		er.logger.Warn("Failed to sample blocking recall", "entity_type", candidate.Type, "error", err)
+		return
+	}
+
+	found := make(map[string]bool, len(matches))
+	for _, match := range matches {
+		found[match.MatchedEntityID] = true
+	}
+
+	exhaustive := er.scoreMLSimilarity(candidate, blockedRecords(records), req)
+	retrieved := 0
+	for _, match := range exhaustive {
+		if found[match.MatchedEntityID] {
+			retrieved++
+		}
+	}
+	er.blocker.RecordRecall(candidate.Type, len(exhaustive), retrieved)
+}
+
+// blockedRecords flattens blocking records into the entity's properties
+// keyed alongside its entityId, as similarity scoring reads them
+func blockedRecords(records []*blocking.Record) []map[string]interface{} {
+	flattened := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		row := make(map[string]interface{}, len(record.Properties)+1)
+		for key, value := range record.Properties {
+			row[key] = value
+		}
+		row["entityId"] = record.EntityID
+		flattened = append(flattened, row)
+	}
+	return flattened
 }
 
 // findHybridMatches combines multiple matching strategies
@@ -558,6 +627,7 @@ func (er *EntityResolver) InferRelationships(ctx context.Context, req *Relations
 			InferenceStrategyTransactional,
 			InferenceStrategyTemporal,
 			InferenceStrategyBehavioral,
+			InferenceStrategyNetwork,
 		}
 		
 		for _, strategy := range strategies {
@@ -890,28 +960,6 @@ func (er *EntityResolver) identifyMerges(ctx context.Context, matches []*EntityM
 	return mergedEntities
 }
 
-// Placeholder methods for relationship inference strategies
-
-func (er *EntityResolver) inferTransactionalRelationships(ctx context.Context, req *RelationshipInferenceRequest) ([]*InferredRelationship, error) {
-	// Implementation for transactional relationship inference
-	return []*InferredRelationship{}, nil
-}
-
-func (er *EntityResolver) inferTemporalRelationships(ctx context.Context, req *RelationshipInferenceRequest) ([]*InferredRelationship, error) {
-	// Implementation for temporal relationship inference
-	return []*InferredRelationship{}, nil
-}
-
-func (er *EntityResolver) inferBehavioralRelationships(ctx context.Context, req *RelationshipInferenceRequest) ([]*InferredRelationship, error) {
-	// Implementation for behavioral relationship inference
-	return []*InferredRelationship{}, nil
-}
-
-func (er *EntityResolver) inferNetworkRelationships(ctx context.Context, req *RelationshipInferenceRequest) ([]*InferredRelationship, error) {
-	// Implementation for network-based relationship inference
-	return []*InferredRelationship{}, nil
-}
-
 // getFloat64 safely extracts a float64 value from a record
 func getFloat64(record map[string]interface{}, key string) float64 {
 	if val, ok := record[key]; ok {
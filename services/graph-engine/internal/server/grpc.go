@@ -2,10 +2,15 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/aegisshield/graph-engine/internal/config"
 	"github.com/aegisshield/graph-engine/internal/engine"
+	"github.com/aegisshield/graph-engine/internal/patterns"
+	"github.com/aegisshield/graph-engine/internal/temporal"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -17,6 +22,7 @@ import (
 type GRPCServer struct {
 	pb.UnimplementedGraphEngineServer
 	engine *engine.GraphEngine
+	motifs *patterns.MotifLibrary
 	config config.Config
 	logger *slog.Logger
 }
@@ -34,6 +40,12 @@ func NewGRPCServer(
 	}
 }
 
+// SetMotifs makes the server answer DetectSuspiciousPatterns with the motif
+// library
+func (s *GRPCServer) SetMotifs(library *patterns.MotifLibrary) {
+	s.motifs = library
+}
+
 // AnalyzeSubGraph performs subgraph analysis
 func (s *GRPCServer) AnalyzeSubGraph(ctx context.Context, req *pb.AnalyzeSubGraphRequest) (*pb.AnalyzeSubGraphResponse, error) {
 	s.logger.Info("Received AnalyzeSubGraph request",
@@ -399,6 +411,94 @@ func (s *GRPCServer) CalculateNetworkMetrics(ctx context.Context, req *pb.Calcul
 	return response, nil
 }
 
+// DetectSuspiciousPatterns runs the motif detectors for the requested
+// pattern types, every detector when none is requested
+func (s *GRPCServer) DetectSuspiciousPatterns(ctx context.Context, req *pb.DetectSuspiciousPatternsRequest) (*pb.DetectSuspiciousPatternsResponse, error) {
+	s.logger.Info("Received DetectSuspiciousPatterns request",
+		"pattern_types", req.PatternTypes,
+		"entity_count", len(req.EntityIds))
+
+	if s.motifs == nil {
+		return nil, status.Error(codes.Unavailable, "motif detection is not configured")
+	}
+
+	// Convert request
+	detectionReq := &patterns.DetectionRequest{
+		EntityIDs:  req.EntityIds,
+		Parameters: make(map[string]interface{}),
+	}
+	seen := make(map[patterns.PatternType]bool)
+	for _, patternType := range req.PatternTypes {
+		motifs, ok := protoPatternMotifs[patternType]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unsupported pattern type: %s", patternType)
+		}
+		for _, motif := range motifs {
+			if !seen[motif] {
+				seen[motif] = true
+				detectionReq.Types = append(detectionReq.Types, motif)
+			}
+		}
+	}
+	// A time range ending in the past reads the graph as it was at its end
+	end := time.Now()
+	if endTime := req.TimeRange.GetEndTime(); endTime != nil && endTime.AsTime().Before(end) {
+		end = endTime.AsTime()
+		ctx = temporal.WithAsOf(ctx, end)
+	}
+	if start := req.TimeRange.GetStartTime(); start != nil {
+		window := end.Sub(start.AsTime())
+		if window <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "time_range start_time must be before its end")
+		}
+		detectionReq.TimeWindow = window
+	}
+	if params := req.Parameters; params != nil {
+		if params.ConfidenceThreshold < 0 || params.ConfidenceThreshold > 1 {
+			return nil, status.Error(codes.InvalidArgument, "confidence_threshold must be between 0 and 1")
+		}
+		detectionReq.MinConfidence = params.ConfidenceThreshold
+		detectionReq.MaxDepth = int(params.MaxPatternDepth)
+		detectionReq.Parameters["min_counterparties"] = int(params.MinTransactionCount)
+		detectionReq.Parameters["min_total_amount"] = params.MinTotalAmount
+	}
+
+	// Detect patterns
+	result, err := s.motifs.Run(ctx, detectionReq)
+	if err != nil {
+		if errors.Is(err, patterns.ErrUnknownMotif) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		s.logger.Error("Failed to detect suspicious patterns", "error", err)
+		return nil, status.Error(codes.Internal, "failed to detect suspicious patterns")
+	}
+
+	// Convert response
+	response := &pb.DetectSuspiciousPatternsResponse{
+		Statistics: &pb.PatternStatistics{
+			EntitiesAnalyzed:     int32(result.EntitiesAnalyzed),
+			TransactionsAnalyzed: int32(result.TransactionsAnalyzed),
+			PatternsFound:        int32(len(result.Patterns)),
+			PatternsByTypology:   make(map[string]int32),
+			ProcessingTimeMs:     float64(result.Duration.Milliseconds()),
+			Truncated:            result.Truncated,
+		},
+		AnalyzedAt: timestamppb.Now(),
+	}
+	for motif, count := range result.ByType {
+		response.Statistics.PatternsByTypology[string(motif)] = int32(count)
+	}
+	for _, pattern := range result.Patterns {
+		if pattern.RiskScore >= s.config.Motifs.PublishMinRisk {
+			response.Statistics.HighRiskPatterns++
+		}
+		response.Patterns = append(response.Patterns, convertMotifToProto(pattern))
+	}
+
+	s.logger.Info("Suspicious patterns detected", "count", len(result.Patterns))
+	return response, nil
+}
+
 // HealthCheck performs a health check
 func (s *GRPCServer) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
 	return &pb.HealthCheckResponse{
@@ -409,6 +509,93 @@ func (s *GRPCServer) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest
 
 // Helper functions
 
+// protoPatternMotifs maps the pattern types of the API to the motif
+// detectors finding them
+var protoPatternMotifs = map[pb.PatternType][]patterns.PatternType{
+	pb.PatternType_LAYERING_PATTERN:            {patterns.PatternTypeLayeringChain},
+	pb.PatternType_SMURFING_PATTERN:            {patterns.PatternTypeFanIn, patterns.PatternTypeFanOut},
+	pb.PatternType_ROUND_TRIPPING:              {patterns.PatternTypeRoundTripping},
+	pb.PatternType_STRUCTURING:                 {patterns.PatternTypeFanIn, patterns.PatternTypeFanOut},
+	pb.PatternType_RAPID_MOVEMENT:              {patterns.PatternTypePassThrough},
+	pb.PatternType_FAN_OUT_PATTERN:             {patterns.PatternTypeFanOut},
+	pb.PatternType_FAN_IN_PATTERN:              {patterns.PatternTypeFanIn},
+	pb.PatternType_CIRCULAR_TRADING:            {patterns.PatternTypeRoundTripping},
+	pb.PatternType_SHELL_COMPANY_NETWORK:       {patterns.PatternTypeNestedShell},
+	pb.PatternType_BENEFICIAL_OWNERSHIP_HIDING: {patterns.PatternTypeNestedShell},
+}
+
+// motifProtoPatterns maps each motif to the pattern type it is reported as
+var motifProtoPatterns = map[patterns.PatternType]pb.PatternType{
+	patterns.PatternTypeRoundTripping: pb.PatternType_ROUND_TRIPPING,
+	patterns.PatternTypeFanIn:         pb.PatternType_FAN_IN_PATTERN,
+	patterns.PatternTypeFanOut:        pb.PatternType_FAN_OUT_PATTERN,
+	patterns.PatternTypePassThrough:   pb.PatternType_RAPID_MOVEMENT,
+	patterns.PatternTypeLayeringChain: pb.PatternType_LAYERING_PATTERN,
+	patterns.PatternTypeNestedShell:   pb.PatternType_SHELL_COMPANY_NETWORK,
+}
+
+func convertMotifToProto(pattern *patterns.Pattern) *pb.SuspiciousPattern {
+	pbPattern := &pb.SuspiciousPattern{
+		PatternId:       pattern.ID,
+		PatternType:     motifProtoPatterns[pattern.Type],
+		ConfidenceScore: pattern.Confidence,
+		Description:     pattern.Description,
+		RiskScore:       pattern.RiskScore,
+		Indicators:      pattern.Indicators,
+		Typology:        string(pattern.Type),
+		PatternData:     make(map[string]string, len(pattern.Metadata)),
+	}
+
+	for _, entity := range pattern.Entities {
+		pbPattern.InvolvedEntities = append(pbPattern.InvolvedEntities, entity.ID)
+	}
+
+	var first, last time.Time
+	totalAmount := 0.0
+	for _, rel := range pattern.Relationships {
+		at, ok := rel.Properties["timestamp"].(time.Time)
+		if !ok {
+			continue // ownership
+		}
+		pbPattern.InvolvedTransactions = append(pbPattern.InvolvedTransactions, rel.ID)
+		if first.IsZero() || at.Before(first) {
+			first = at
+		}
+		if at.After(last) {
+			last = at
+		}
+	}
+	if amount, ok := pattern.Metadata["total_amount"].(float64); ok {
+		totalAmount = amount
+	}
+
+	pbPattern.Metrics = &pb.PatternMetrics{
+		EntityCount:      int32(len(pattern.Entities)),
+		TransactionCount: int32(len(pbPattern.InvolvedTransactions)),
+		TotalAmount:      totalAmount,
+		PatternDepth:     int32(len(pattern.Relationships)),
+	}
+	if !first.IsZero() {
+		pbPattern.TimeSpan = &pb.TimeRange{
+			StartTime: timestamppb.New(first),
+			EndTime:   timestamppb.New(last),
+		}
+		if hours := last.Sub(first).Hours(); hours > 0 {
+			pbPattern.Metrics.Velocity = float64(len(pbPattern.InvolvedTransactions)) / hours
+		}
+	}
+
+	for key, value := range pattern.Metadata {
+		if at, ok := value.(time.Time); ok {
+			pbPattern.PatternData[key] = at.UTC().Format(time.RFC3339)
+			continue
+		}
+		pbPattern.PatternData[key] = fmt.Sprintf("%v", value)
+	}
+
+	return pbPattern
+}
+
 func convertEntityToProto(entity *engine.Entity) *pb.Entity {
 	if entity == nil {
 		return nil
@@ -0,0 +1,110 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Properties versioned relationships carry. A relationship between two
+// entities is kept as a chain of versions of the same type, each valid from
+// valid_from until valid_to; the current version has no valid_to.
+// Relationships written before versioning have neither and count as valid
+// at every point in time.
+const (
+	PropertyValidFrom   = "valid_from"
+	PropertyValidTo     = "valid_to"
+	PropertyVersion     = "version"
+	PropertyVersionHash = "version_hash"
+	PropertyRecordedAt  = "recorded_at"
+	PropertyRecordedBy  = "recorded_by"
+)
+
+// AsOfParam is the Cypher parameter the predicates compare validity with
+const AsOfParam = "as_of"
+
+type asOfKey struct{}
+
+// WithAsOf makes graph reads under the context see relationships as they
+// were at t
+func WithAsOf(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, asOfKey{}, t.UTC())
+}
+
+// AsOf returns the point in time the context reads the graph at, or false
+// when it reads the current graph
+func AsOf(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(asOfKey{}).(time.Time)
+	return t, ok
+}
+
+// At returns the context's as-of time, or now when it reads the current
+// graph. Time windows of reads are anchored here.
+func At(ctx context.Context) time.Time {
+	if t, ok := AsOf(ctx); ok {
+		return t
+	}
+	return time.Now().UTC()
+}
+
+// Bind adds the as_of parameter the predicates use to params
+func Bind(ctx context.Context, params map[string]interface{}) map[string]interface{} {
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	params[AsOfParam] = At(ctx)
+	return params
+}
+
+// Relationship returns a Cypher predicate selecting the versions of the
+// relationship variable valid at the context's as-of time, or the current
+// versions when the context has none. Queries using it need Bind.
+func Relationship(ctx context.Context, variable string) string {
+	if _, ok := AsOf(ctx); !ok {
+		return fmt.Sprintf("%s.%s IS NULL", variable, PropertyValidTo)
+	}
+	return fmt.Sprintf("(%[1]s.%[2]s IS NULL OR %[1]s.%[2]s <= $%[4]s) AND (%[1]s.%[3]s IS NULL OR %[1]s.%[3]s > $%[4]s)",
+		variable, PropertyValidFrom, PropertyValidTo, AsOfParam)
+}
+
+// Path returns a Cypher predicate selecting paths whose every relationship
+// passes Relationship
+func Path(ctx context.Context, variable string) string {
+	return fmt.Sprintf("ALL(version IN relationships(%s) WHERE %s)", variable, Relationship(ctx, "version"))
+}
+
+// Relationships returns a Cypher predicate applying Relationship to every
+// relationship in a list variable, as bound by variable-length patterns
+func Relationships(ctx context.Context, variable string) string {
+	return fmt.Sprintf("ALL(version IN %s WHERE %s)", variable, Relationship(ctx, "version"))
+}
+
+// Valid reports whether a relationship with the given properties is valid
+// at the context's as-of time, or current when the context has none. It
+// filters relationships already loaded, where Relationship cannot apply.
+func Valid(ctx context.Context, properties map[string]interface{}) bool {
+	validTo, closed := timeProperty(properties[PropertyValidTo])
+
+	at, ok := AsOf(ctx)
+	if !ok {
+		return !closed
+	}
+
+	if validFrom, opened := timeProperty(properties[PropertyValidFrom]); opened && validFrom.After(at) {
+		return false
+	}
+	return !closed || validTo.After(at)
+}
+
+// timeProperty reads a datetime property returned by Neo4j, reporting
+// false for absent properties
+func timeProperty(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,360 @@
+package temporal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Outcomes of recording a relationship version
+const (
+	// OutcomeCreated means a new version was created and the previous
+	// current version, if any, was closed at its valid_from
+	OutcomeCreated = "created"
+	// OutcomeUnchanged means the current version already has the properties
+	OutcomeUnchanged = "unchanged"
+	// OutcomeStale means the current version is valid from later than the
+	// change, which is ignored rather than rewriting history
+	OutcomeStale = "stale"
+)
+
+var (
+	// ErrInvalidRelationship is returned for changes with missing entities,
+	// malformed types or properties Neo4j cannot store
+	ErrInvalidRelationship = errors.New("invalid relationship")
+	// ErrEntityNotFound is returned when an endpoint is not in the graph
+	ErrEntityNotFound = errors.New("entity not found")
+	// ErrRelationshipNotFound is returned when ending a relationship that
+	// has no current version
+	ErrRelationshipNotFound = errors.New("relationship has no current version")
+)
+
+// relationshipType is what a relationship type may look like; types are
+// interpolated into Cypher
+var relationshipType = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reserved properties are managed by the writer
+var reserved = map[string]bool{
+	PropertyValidFrom:   true,
+	PropertyValidTo:     true,
+	PropertyVersion:     true,
+	PropertyVersionHash: true,
+	PropertyRecordedAt:  true,
+	PropertyRecordedBy:  true,
+}
+
+// QueryRunner runs Cypher against the graph
+type QueryRunner interface {
+	ExecuteQuery(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error)
+}
+
+// Change sets the properties of the relationship of a type from one entity
+// to another, from ValidFrom on. A zero ValidFrom means now.
+type Change struct {
+	SourceID   string                 `json:"source_id"`
+	TargetID   string                 `json:"target_id"`
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties"`
+	ValidFrom  time.Time              `json:"valid_from"`
+	RecordedBy string                 `json:"recorded_by,omitempty"`
+}
+
+// Result reports how a change was recorded and the relationship's current
+// version after it
+type Result struct {
+	Outcome   string    `json:"outcome"`
+	Version   int       `json:"version"`
+	ValidFrom time.Time `json:"valid_from"`
+}
+
+// Version is one version of a relationship
+type Version struct {
+	Type       string                 `json:"type"`
+	Version    int                    `json:"version"`
+	ValidFrom  *time.Time             `json:"valid_from,omitempty"`
+	ValidTo    *time.Time             `json:"valid_to,omitempty"`
+	RecordedAt *time.Time             `json:"recorded_at,omitempty"`
+	RecordedBy string                 `json:"recorded_by,omitempty"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Writer writes relationships as versions, so the graph can be read as it
+// was at any point in time
+type Writer struct {
+	graph  QueryRunner
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewWriter creates a versioned relationship writer
+func NewWriter(graph QueryRunner, logger *slog.Logger) *Writer {
+	return &Writer{
+		graph:  graph,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Record makes the change's properties the current version of the
+// relationship. A change with the properties the current version already
+// has is a no-op, so replayed events do not add versions.
+func (w *Writer) Record(ctx context.Context, change Change) (*Result, error) {
+	if err := validateEndpoints(change.SourceID, change.TargetID, change.Type); err != nil {
+		return nil, err
+	}
+	properties, err := storable(change.Properties)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := versionHash(properties)
+	if err != nil {
+		return nil, err
+	}
+
+	validFrom := change.ValidFrom.UTC()
+	if change.ValidFrom.IsZero() {
+		validFrom = w.now().UTC()
+	}
+
+	rows, err := w.graph.ExecuteQuery(ctx, fmt.Sprintf(`
+		MATCH (a:Entity {id: $sourceId}), (b:Entity {id: $targetId})
+		OPTIONAL MATCH (a)-[open:%[1]s]->(b)
+		WHERE open.valid_to IS NULL
+		WITH a, b, open
+		ORDER BY coalesce(open.version, 0) DESC
+		WITH a, b, collect(open) AS opens
+		WITH a, b, opens, head(opens) AS latest
+		WITH a, b, opens, latest,
+			 CASE
+			   WHEN latest.valid_from IS NOT NULL AND latest.valid_from > $validFrom THEN 'stale'
+			   WHEN latest.version_hash = $versionHash THEN 'unchanged'
+			   ELSE 'created'
+			 END AS outcome
+		FOREACH (previous IN CASE WHEN outcome = 'created' THEN opens ELSE [] END |
+			SET previous.valid_to = $validFrom)
+		FOREACH (_ IN CASE WHEN outcome = 'created' THEN [1] ELSE [] END |
+			CREATE (a)-[r:%[1]s]->(b)
+			SET r = $properties,
+				r.valid_from = $validFrom,
+				r.version = coalesce(latest.version, 0) + 1,
+				r.version_hash = $versionHash,
+				r.recorded_at = datetime(),
+				r.recorded_by = $recordedBy)
+		RETURN outcome,
+			   coalesce(latest.version, 0) + CASE WHEN outcome = 'created' THEN 1 ELSE 0 END AS version,
+			   CASE WHEN outcome = 'created' THEN $validFrom ELSE latest.valid_from END AS valid_from`,
+		change.Type), map[string]interface{}{
+		"sourceId":    change.SourceID,
+		"targetId":    change.TargetID,
+		"properties":  properties,
+		"validFrom":   validFrom,
+		"versionHash": hash,
+		"recordedBy":  change.RecordedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record relationship version: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: %s or %s", ErrEntityNotFound, change.SourceID, change.TargetID)
+	}
+
+	result := &Result{
+		Outcome: toString(rows[0]["outcome"]),
+		Version: toInt(rows[0]["version"]),
+	}
+	if t, ok := timeProperty(rows[0]["valid_from"]); ok {
+		result.ValidFrom = t
+	}
+
+	if result.Outcome == OutcomeStale {
+		w.logger.Warn("Ignored relationship change older than its current version",
+			"source_id", change.SourceID,
+			"target_id", change.TargetID,
+			"type", change.Type,
+			"valid_from", validFrom)
+	}
+	return result, nil
+}
+
+// End closes the current version of a relationship at validTo, or now when
+// validTo is zero, so it no longer exists from then on. It returns the
+// number of versions closed.
+func (w *Writer) End(ctx context.Context, sourceID, targetID, relType string, validTo time.Time) (int, error) {
+	if err := validateEndpoints(sourceID, targetID, relType); err != nil {
+		return 0, err
+	}
+	if validTo.IsZero() {
+		validTo = w.now()
+	}
+
+	rows, err := w.graph.ExecuteQuery(ctx, fmt.Sprintf(`
+		MATCH (a:Entity {id: $sourceId})-[r:%s]->(b:Entity {id: $targetId})
+		WHERE r.valid_to IS NULL
+		  AND (r.valid_from IS NULL OR r.valid_from <= $validTo)
+		SET r.valid_to = $validTo
+		RETURN count(r) AS ended`, relType), map[string]interface{}{
+		"sourceId": sourceID,
+		"targetId": targetID,
+		"validTo":  validTo.UTC(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to end relationship: %w", err)
+	}
+
+	ended := 0
+	if len(rows) > 0 {
+		ended = toInt(rows[0]["ended"])
+	}
+	if ended == 0 {
+		return 0, ErrRelationshipNotFound
+	}
+	return ended, nil
+}
+
+// History returns every version of the relationships from one entity to
+// another, of one type or all types when relType is empty, oldest first
+func (w *Writer) History(ctx context.Context, sourceID, targetID, relType string) ([]*Version, error) {
+	if relType == "" {
+		if sourceID == "" || targetID == "" {
+			return nil, fmt.Errorf("%w: source and target entity IDs are required", ErrInvalidRelationship)
+		}
+	} else if err := validateEndpoints(sourceID, targetID, relType); err != nil {
+		return nil, err
+	}
+
+	pattern := "r"
+	if relType != "" {
+		pattern = "r:" + relType
+	}
+
+	rows, err := w.graph.ExecuteQuery(ctx, fmt.Sprintf(`
+		MATCH (:Entity {id: $sourceId})-[%s]->(:Entity {id: $targetId})
+		RETURN type(r) AS type, r.version AS version, r.valid_from AS valid_from,
+			   r.valid_to AS valid_to, r.recorded_at AS recorded_at, r.recorded_by AS recorded_by,
+			   properties(r) AS properties
+		ORDER BY type, coalesce(r.version, 0)`, pattern), map[string]interface{}{
+		"sourceId": sourceID,
+		"targetId": targetID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load relationship history: %w", err)
+	}
+
+	versions := make([]*Version, 0, len(rows))
+	for _, row := range rows {
+		version := &Version{
+			Type:       toString(row["type"]),
+			Version:    toInt(row["version"]),
+			RecordedBy: toString(row["recorded_by"]),
+			Properties: make(map[string]interface{}),
+		}
+		if t, ok := timeProperty(row["valid_from"]); ok {
+			version.ValidFrom = &t
+		}
+		if t, ok := timeProperty(row["valid_to"]); ok {
+			version.ValidTo = &t
+		}
+		if t, ok := timeProperty(row["recorded_at"]); ok {
+			version.RecordedAt = &t
+		}
+		if properties, ok := row["properties"].(map[string]interface{}); ok {
+			for key, value := range properties {
+				if !reserved[key] {
+					version.Properties[key] = value
+				}
+			}
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+func validateEndpoints(sourceID, targetID, relType string) error {
+	if sourceID == "" || targetID == "" {
+		return fmt.Errorf("%w: source and target entity IDs are required", ErrInvalidRelationship)
+	}
+	if !relationshipType.MatchString(relType) {
+		return fmt.Errorf("%w: invalid relationship type %q", ErrInvalidRelationship, relType)
+	}
+	return nil
+}
+
+// storable checks properties are values Neo4j can store on a relationship:
+// primitives and lists of primitives, without the writer's own properties
+func storable(properties map[string]interface{}) (map[string]interface{}, error) {
+	stored := make(map[string]interface{}, len(properties))
+	for key, value := range properties {
+		if reserved[key] {
+			return nil, fmt.Errorf("%w: property %s is managed by versioning", ErrInvalidRelationship, key)
+		}
+		if value == nil {
+			continue
+		}
+		if !primitive(value) {
+			items, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%w: property %s must be a primitive or a list of primitives", ErrInvalidRelationship, key)
+			}
+			for _, item := range items {
+				if !primitive(item) {
+					return nil, fmt.Errorf("%w: property %s must be a primitive or a list of primitives", ErrInvalidRelationship, key)
+				}
+			}
+		}
+		stored[key] = value
+	}
+	return stored, nil
+}
+
+func primitive(value interface{}) bool {
+	switch value.(type) {
+	case string, bool, int, int32, int64, float32, float64, time.Time:
+		return true
+	}
+	return false
+}
+
+// versionHash fingerprints properties so unchanged writes are recognised
+func versionHash(properties map[string]interface{}) (string, error) {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		value, err := json.Marshal(properties[key])
+		if err != nil {
+			return "", fmt.Errorf("%w: property %s: %v", ErrInvalidRelationship, key, err)
+		}
+		fmt.Fprintf(hash, "%s=%s\n", key, value)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:32], nil
+}
+
+func toString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
+
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int64:
+		return int(v)
+	case int:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
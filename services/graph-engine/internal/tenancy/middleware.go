@@ -0,0 +1,120 @@
+package tenancy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+)
+
+// exemptPaths are served without a tenant: probes, metrics and the tenant
+// administration routes, which name the tenant in the path
+var exemptPaths = []string{"/health", "/ready", "/metrics", "/api/v1/tenants"}
+
+// Middleware routes each request's graph queries to the database of the
+// tenant named in the tenant header. Requests naming an invalid tenant are
+// rejected, as are requests naming none when a tenant is required.
+func Middleware(cfg config.TenancyConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isExempt(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, err := tenantContext(r.Context(), r.Header.Get(cfg.Header), cfg.RequireTenant)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// UnaryServerInterceptor routes each call's graph queries to the database of
+// the tenant named in the tenant metadata key
+func UnaryServerInterceptor(cfg config.TenancyConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := tenantContext(ctx, metadataValue(ctx, cfg.MetadataKey), cfg.RequireTenant)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor routes each stream's graph queries to the database
+// of the tenant named in the tenant metadata key
+func StreamServerInterceptor(cfg config.TenancyConfig) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := tenantContext(ss.Context(), metadataValue(ss.Context(), cfg.MetadataKey), cfg.RequireTenant)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return handler(srv, &tenantStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func tenantContext(ctx context.Context, tenantID string, required bool) (context.Context, error) {
+	if tenantID == "" {
+		if required {
+			return nil, ErrTenantRequired
+		}
+		return ctx, nil
+	}
+
+	normalized, err := NormalizeTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return WithTenant(ctx, normalized), nil
+}
+
+func isExempt(path string) bool {
+	for _, exempt := range exemptPaths {
+		if path == exempt || strings.HasPrefix(path, exempt+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// tenantStream carries the tenant context into a streaming handler
+type tenantStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tenantStream) Context() context.Context {
+	return s.ctx
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":     err.Error(),
+		"status":    http.StatusBadRequest,
+		"timestamp": time.Now(),
+	})
+}
@@ -0,0 +1,110 @@
+package tenancy
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+)
+
+var (
+	databaseOnline = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "graph_engine_tenant_database_online",
+		Help: "Whether a tenant graph database is online (1) or not (0)",
+	}, []string{"tenant", "database"})
+	databaseNodes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "graph_engine_tenant_database_nodes",
+		Help: "Nodes stored in a tenant graph database",
+	}, []string{"tenant", "database"})
+	databaseRelationships = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "graph_engine_tenant_database_relationships",
+		Help: "Relationships stored in a tenant graph database",
+	}, []string{"tenant", "database"})
+	databaseStoreBytes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "graph_engine_tenant_database_store_bytes",
+		Help: "On-disk store size of a tenant graph database",
+	}, []string{"tenant", "database"})
+)
+
+// Inspector reports the health and size of every tenant database
+type Inspector interface {
+	TenantDatabases(ctx context.Context) ([]*Database, error)
+}
+
+// Monitor publishes tenant database health and size as Prometheus gauges
+type Monitor struct {
+	inspector Inspector
+	cfg       config.TenancyConfig
+	logger    *slog.Logger
+	reported  map[string]string // database name to tenant, for clearing dropped databases
+}
+
+// NewMonitor creates a tenant database monitor
+func NewMonitor(inspector Inspector, cfg config.TenancyConfig, logger *slog.Logger) *Monitor {
+	return &Monitor{
+		inspector: inspector,
+		cfg:       cfg,
+		logger:    logger,
+		reported:  make(map[string]string),
+	}
+}
+
+// Start refreshes the gauges every monitor interval until the context ends
+func (m *Monitor) Start(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.MonitorInterval)
+	defer ticker.Stop()
+
+	for {
+		m.Refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh inspects the tenant databases once and updates the gauges
+func (m *Monitor) Refresh(ctx context.Context) {
+	databases, err := m.inspector.TenantDatabases(ctx)
+	if err != nil {
+		m.logger.Error("Failed to inspect tenant databases", "error", err)
+		return
+	}
+
+	seen := make(map[string]string, len(databases))
+	for _, db := range databases {
+		seen[db.Name] = db.TenantID
+
+		online := 0.0
+		if db.Online {
+			online = 1
+		} else {
+			m.logger.Warn("Tenant database is not online",
+				"tenant_id", db.TenantID,
+				"database", db.Name,
+				"status", db.Status)
+		}
+		databaseOnline.WithLabelValues(db.TenantID, db.Name).Set(online)
+		databaseNodes.WithLabelValues(db.TenantID, db.Name).Set(float64(db.Nodes))
+		databaseRelationships.WithLabelValues(db.TenantID, db.Name).Set(float64(db.Relationships))
+		if db.StoreBytes >= 0 {
+			databaseStoreBytes.WithLabelValues(db.TenantID, db.Name).Set(float64(db.StoreBytes))
+		}
+	}
+
+	for name, tenantID := range m.reported {
+		if _, ok := seen[name]; !ok {
+			databaseOnline.DeleteLabelValues(tenantID, name)
+			databaseNodes.DeleteLabelValues(tenantID, name)
+			databaseRelationships.DeleteLabelValues(tenantID, name)
+			databaseStoreBytes.DeleteLabelValues(tenantID, name)
+		}
+	}
+	m.reported = seen
+}
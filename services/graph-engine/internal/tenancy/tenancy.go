@@ -0,0 +1,100 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTenantRequired is returned when a request names no tenant and
+	// tenancy requires one
+	ErrTenantRequired = errors.New("tenant is required")
+	// ErrInvalidTenant is returned for tenant IDs that cannot name a database
+	ErrInvalidTenant = errors.New("invalid tenant")
+	// ErrTenantNotProvisioned is returned when a tenant's database does not exist
+	ErrTenantNotProvisioned = errors.New("tenant database is not provisioned")
+	// ErrCrossTenantQuery is returned for Cypher that selects its own database
+	ErrCrossTenantQuery = errors.New("query selects a database")
+)
+
+type contextKey struct{}
+
+// tenantPattern is what a tenant ID may look like once lower-cased: Neo4j
+// database names are at most 63 characters of letters, digits, dots and
+// dashes, and the prefix takes some of those
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,39}$`)
+
+// useClause matches a USE clause at the start of a query or subquery, which
+// would run the query against a database other than the session's
+var useClause = regexp.MustCompile(`(?is)(^|\{)\s*USE\s`)
+
+// Database is a tenant's graph database and its last observed health and size
+type Database struct {
+	TenantID      string    `json:"tenant_id"`
+	Name          string    `json:"database"`
+	Status        string    `json:"status"`
+	Online        bool      `json:"online"`
+	Nodes         int64     `json:"nodes"`
+	Relationships int64     `json:"relationships"`
+	StoreBytes    int64     `json:"store_bytes"` // -1 when the store size is unavailable
+	CheckedAt     time.Time `json:"checked_at"`
+}
+
+// Provisioner creates tenant databases and reports their health and size
+type Provisioner interface {
+	Inspector
+	ProvisionTenantDatabase(ctx context.Context, tenantID string) (*Database, error)
+	TenantDatabase(ctx context.Context, tenantID string) (*Database, error)
+}
+
+// WithTenant returns a context whose graph queries run in the tenant's database
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, tenantID)
+}
+
+// FromContext returns the tenant a context's graph queries are routed to
+func FromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(contextKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// NormalizeTenant lower-cases and trims a tenant ID, rejecting IDs that
+// cannot be part of a database name
+func NormalizeTenant(tenantID string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(tenantID))
+	if !tenantPattern.MatchString(normalized) {
+		return "", fmt.Errorf("%w: %q must be 1-40 letters, digits or dashes", ErrInvalidTenant, tenantID)
+	}
+	return normalized, nil
+}
+
+// DatabaseName returns the name of a tenant's database
+func DatabaseName(prefix, tenantID string) (string, error) {
+	normalized, err := NormalizeTenant(tenantID)
+	if err != nil {
+		return "", err
+	}
+	return prefix + normalized, nil
+}
+
+// TenantOf returns the tenant a database belongs to, or false for databases
+// that are not tenant databases
+func TenantOf(prefix, database string) (string, bool) {
+	if !strings.HasPrefix(database, prefix) {
+		return "", false
+	}
+	tenantID := strings.TrimPrefix(database, prefix)
+	return tenantID, tenantPattern.MatchString(tenantID)
+}
+
+// CheckQuery rejects Cypher that would escape the session's database
+func CheckQuery(query string) error {
+	if useClause.MatchString(query) {
+		return ErrCrossTenantQuery
+	}
+	return nil
+}
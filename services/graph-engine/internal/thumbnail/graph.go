@@ -0,0 +1,94 @@
+package thumbnail
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// Node is an entity drawn in a thumbnail
+type Node struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Distance  int    `json:"distance"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// Edge is a relationship drawn in a thumbnail
+type Edge struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	SourceID string `json:"source_id"`
+	TargetID string `json:"target_id"`
+}
+
+// Graph is the subgraph a thumbnail is rendered from
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Cap reduces a graph to at most maxNodes nodes. Nodes closest to the seed
+// entities are kept first and, at equal distance, the best connected ones, so
+// the preview shows the core of the network rather than its periphery. Edges
+// to dropped nodes are removed. The second return value reports whether any
+// node was dropped.
+func Cap(graph *Graph, maxNodes int) (*Graph, bool) {
+	if maxNodes <= 0 || len(graph.Nodes) <= maxNodes {
+		return graph, false
+	}
+
+	degree := make(map[string]int, len(graph.Nodes))
+	for _, edge := range graph.Edges {
+		degree[edge.SourceID]++
+		degree[edge.TargetID]++
+	}
+
+	nodes := make([]Node, len(graph.Nodes))
+	copy(nodes, graph.Nodes)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Distance != nodes[j].Distance {
+			return nodes[i].Distance < nodes[j].Distance
+		}
+		if degree[nodes[i].ID] != degree[nodes[j].ID] {
+			return degree[nodes[i].ID] > degree[nodes[j].ID]
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+	nodes = nodes[:maxNodes]
+
+	kept := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		kept[node.ID] = true
+	}
+
+	edges := make([]Edge, 0, len(graph.Edges))
+	for _, edge := range graph.Edges {
+		if kept[edge.SourceID] && kept[edge.TargetID] {
+			edges = append(edges, edge)
+		}
+	}
+
+	return &Graph{Nodes: nodes, Edges: edges}, true
+}
+
+// Version returns a stable fingerprint of a graph's structure and node
+// update times. It does not depend on the order nodes and edges were
+// returned in, so it only changes when the network itself changes.
+func Version(graph *Graph) string {
+	keys := make([]string, 0, len(graph.Nodes)+len(graph.Edges))
+	for _, node := range graph.Nodes {
+		keys = append(keys, "n\x00"+node.ID+"\x00"+node.Type+"\x00"+node.UpdatedAt)
+	}
+	for _, edge := range graph.Edges {
+		keys = append(keys, "e\x00"+edge.SourceID+"\x00"+edge.TargetID+"\x00"+edge.Type+"\x00"+edge.ID)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
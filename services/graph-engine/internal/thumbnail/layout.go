@@ -0,0 +1,149 @@
+package thumbnail
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// Point is a node position in image coordinates
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Layout places the nodes of a graph inside a width x height box using the
+// Fruchterman-Reingold force-directed algorithm. Start positions are derived
+// from node IDs rather than a random source so the same graph always renders
+// the same picture, which keeps cached thumbnails and ETags meaningful.
+// Positions are returned in node order and keep padding pixels clear of the
+// image border.
+func Layout(graph *Graph, width, height, padding float64, iterations int) []Point {
+	n := len(graph.Nodes)
+	positions := make([]Point, n)
+	if n == 0 {
+		return positions
+	}
+	if n == 1 {
+		positions[0] = Point{X: width / 2, Y: height / 2}
+		return positions
+	}
+
+	index := make(map[string]int, n)
+	for i, node := range graph.Nodes {
+		index[node.ID] = i
+		angle, radius := seedPosition(node.ID)
+		positions[i] = Point{
+			X: 0.5 + 0.4*radius*math.Cos(angle),
+			Y: 0.5 + 0.4*radius*math.Sin(angle),
+		}
+	}
+
+	type link struct{ source, target int }
+	links := make([]link, 0, len(graph.Edges))
+	for _, edge := range graph.Edges {
+		source, okSource := index[edge.SourceID]
+		target, okTarget := index[edge.TargetID]
+		if okSource && okTarget && source != target {
+			links = append(links, link{source, target})
+		}
+	}
+
+	// Lay out in the unit square and scale afterwards; k is the ideal
+	// distance between nodes for the available area
+	k := math.Sqrt(1.0 / float64(n))
+	temperature := 0.1
+	cooling := temperature / float64(iterations+1)
+	displacement := make([]Point, n)
+
+	for iter := 0; iter < iterations; iter++ {
+		for i := range displacement {
+			displacement[i] = Point{}
+		}
+
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				dx := positions[i].X - positions[j].X
+				dy := positions[i].Y - positions[j].Y
+				distance := math.Max(math.Hypot(dx, dy), 1e-4)
+				force := k * k / distance
+				displacement[i].X += dx / distance * force
+				displacement[i].Y += dy / distance * force
+				displacement[j].X -= dx / distance * force
+				displacement[j].Y -= dy / distance * force
+			}
+		}
+
+		for _, l := range links {
+			dx := positions[l.source].X - positions[l.target].X
+			dy := positions[l.source].Y - positions[l.target].Y
+			distance := math.Max(math.Hypot(dx, dy), 1e-4)
+			force := distance * distance / k
+			displacement[l.source].X -= dx / distance * force
+			displacement[l.source].Y -= dy / distance * force
+			displacement[l.target].X += dx / distance * force
+			displacement[l.target].Y += dy / distance * force
+		}
+
+		for i := range positions {
+			length := math.Hypot(displacement[i].X, displacement[i].Y)
+			if length > 0 {
+				step := math.Min(length, temperature)
+				positions[i].X += displacement[i].X / length * step
+				positions[i].Y += displacement[i].Y / length * step
+			}
+			// Weak gravity keeps disconnected components on screen
+			positions[i].X += (0.5 - positions[i].X) * 0.01
+			positions[i].Y += (0.5 - positions[i].Y) * 0.01
+		}
+
+		temperature -= cooling
+	}
+
+	return fit(positions, width, height, padding)
+}
+
+// fit scales positions to fill the drawable area while preserving aspect ratio
+func fit(positions []Point, width, height, padding float64) []Point {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range positions {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+
+	drawWidth := math.Max(width-2*padding, 1)
+	drawHeight := math.Max(height-2*padding, 1)
+	spanX, spanY := maxX-minX, maxY-minY
+
+	scale := math.Inf(1)
+	if spanX > 0 {
+		scale = drawWidth / spanX
+	}
+	if spanY > 0 {
+		scale = math.Min(scale, drawHeight/spanY)
+	}
+	if math.IsInf(scale, 1) {
+		scale = 0
+	}
+
+	offsetX := padding + (drawWidth-spanX*scale)/2
+	offsetY := padding + (drawHeight-spanY*scale)/2
+	for i, p := range positions {
+		positions[i] = Point{
+			X: offsetX + (p.X-minX)*scale,
+			Y: offsetY + (p.Y-minY)*scale,
+		}
+	}
+	return positions
+}
+
+// seedPosition derives a deterministic polar start position from a node ID
+func seedPosition(id string) (angle, radius float64) {
+	hash := fnv.New64a()
+	hash.Write([]byte(id))
+	sum := hash.Sum64()
+
+	angle = float64(sum&0xffffffff) / float64(0xffffffff) * 2 * math.Pi
+	radius = 0.2 + 0.8*float64(sum>>32)/float64(0xffffffff)
+	return angle, radius
+}
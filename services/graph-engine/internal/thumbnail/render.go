@@ -0,0 +1,191 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strings"
+)
+
+// Supported thumbnail formats
+const (
+	FormatSVG = "svg"
+	FormatPNG = "png"
+)
+
+var (
+	backgroundColor = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	edgeColor       = color.RGBA{R: 0xb0, G: 0xb7, B: 0xc3, A: 0xff}
+	seedColor       = color.RGBA{R: 0x1f, G: 0x29, B: 0x37, A: 0xff}
+	defaultColor    = color.RGBA{R: 0x9c, G: 0xa3, B: 0xaf, A: 0xff}
+
+	typeColors = map[string]color.RGBA{
+		"person":       {R: 0x25, G: 0x63, B: 0xeb, A: 0xff},
+		"organization": {R: 0x7c, G: 0x3a, B: 0xed, A: 0xff},
+		"company":      {R: 0x7c, G: 0x3a, B: 0xed, A: 0xff},
+		"account":      {R: 0x05, G: 0x96, B: 0x69, A: 0xff},
+		"transaction":  {R: 0xd9, G: 0x77, B: 0x06, A: 0xff},
+		"address":      {R: 0xdb, G: 0x27, B: 0x77, A: 0xff},
+		"device":       {R: 0x08, G: 0x91, B: 0xb2, A: 0xff},
+	}
+)
+
+// NodeColor returns the fill color used for an entity type
+func NodeColor(entityType string) color.RGBA {
+	if c, ok := typeColors[strings.ToLower(entityType)]; ok {
+		return c
+	}
+	return defaultColor
+}
+
+// NodeRadius picks a node radius that keeps dense graphs legible
+func NodeRadius(nodeCount int, width, height float64) float64 {
+	if nodeCount == 0 {
+		return 0
+	}
+	radius := math.Sqrt(width*height/float64(nodeCount)) / 6
+	return math.Max(2, math.Min(8, radius))
+}
+
+// RenderSVG draws a laid out graph as an SVG document
+func RenderSVG(graph *Graph, positions []Point, width, height int) []byte {
+	var buf bytes.Buffer
+	radius := NodeRadius(len(graph.Nodes), float64(width), float64(height))
+	index := nodeIndex(graph)
+
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		width, height, width, height)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"/>`, hexColor(backgroundColor))
+
+	fmt.Fprintf(&buf, `<g stroke="%s" stroke-width="1">`, hexColor(edgeColor))
+	for _, edge := range graph.Edges {
+		source, okSource := index[edge.SourceID]
+		target, okTarget := index[edge.TargetID]
+		if !okSource || !okTarget {
+			continue
+		}
+		fmt.Fprintf(&buf, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f"/>`,
+			positions[source].X, positions[source].Y, positions[target].X, positions[target].Y)
+	}
+	buf.WriteString(`</g>`)
+
+	buf.WriteString(`<g>`)
+	for i, node := range graph.Nodes {
+		fmt.Fprintf(&buf, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s"`,
+			positions[i].X, positions[i].Y, radius, hexColor(NodeColor(node.Type)))
+		if node.Distance == 0 {
+			fmt.Fprintf(&buf, ` stroke="%s" stroke-width="2"`, hexColor(seedColor))
+		}
+		buf.WriteString(`><title>`)
+		xml.EscapeText(&buf, []byte(node.ID))
+		buf.WriteString(`</title></circle>`)
+	}
+	buf.WriteString(`</g></svg>`)
+
+	return buf.Bytes()
+}
+
+// RenderPNG draws a laid out graph as a PNG image
+func RenderPNG(graph *Graph, positions []Point, width, height int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: backgroundColor}, image.Point{}, draw.Src)
+
+	radius := NodeRadius(len(graph.Nodes), float64(width), float64(height))
+	index := nodeIndex(graph)
+
+	for _, edge := range graph.Edges {
+		source, okSource := index[edge.SourceID]
+		target, okTarget := index[edge.TargetID]
+		if !okSource || !okTarget {
+			continue
+		}
+		drawLine(img, positions[source], positions[target], edgeColor)
+	}
+
+	for i, node := range graph.Nodes {
+		if node.Distance == 0 {
+			fillCircle(img, positions[i], radius+2, seedColor)
+		}
+		fillCircle(img, positions[i], radius, NodeColor(node.Type))
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Helper functions
+
+func nodeIndex(graph *Graph) map[string]int {
+	index := make(map[string]int, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		index[node.ID] = i
+	}
+	return index
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// drawLine draws a one pixel line using Bresenham's algorithm
+func drawLine(img *image.RGBA, from, to Point, c color.RGBA) {
+	x0, y0 := int(math.Round(from.X)), int(math.Round(from.Y))
+	x1, y1 := int(math.Round(to.X)), int(math.Round(to.Y))
+
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	err := dx + dy
+	for {
+		img.SetRGBA(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func fillCircle(img *image.RGBA, center Point, radius float64, c color.RGBA) {
+	bounds := image.Rect(
+		int(math.Floor(center.X-radius)), int(math.Floor(center.Y-radius)),
+		int(math.Ceil(center.X+radius))+1, int(math.Ceil(center.Y+radius))+1,
+	).Intersect(img.Bounds())
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if math.Hypot(float64(x)-center.X, float64(y)-center.Y) <= radius {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,260 @@
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/neo4j"
+)
+
+var (
+	// ErrEmptyGraph is returned when none of the requested entities exist
+	ErrEmptyGraph = errors.New("no entities found for thumbnail")
+	// ErrUnsupportedFormat is returned for formats other than svg and png
+	ErrUnsupportedFormat = errors.New("unsupported thumbnail format")
+)
+
+// Request describes the network to preview and how to draw it
+type Request struct {
+	EntityIDs []string `json:"entity_ids"`
+	Depth     int      `json:"depth,omitempty"`
+	MaxNodes  int      `json:"max_nodes,omitempty"`
+	Format    string   `json:"format,omitempty"`
+	Width     int      `json:"width,omitempty"`
+	Height    int      `json:"height,omitempty"`
+}
+
+// Thumbnail is a rendered network preview
+type Thumbnail struct {
+	Version     string    `json:"version"`
+	Format      string    `json:"format"`
+	ContentType string    `json:"content_type"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	NodeCount   int       `json:"node_count"`
+	EdgeCount   int       `json:"edge_count"`
+	Truncated   bool      `json:"truncated"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Data        []byte    `json:"-"`
+}
+
+// ETag returns an entity tag identifying this rendering of the graph version
+func (t *Thumbnail) ETag() string {
+	return fmt.Sprintf(`"%s-%s-%dx%d"`, t.Version[:16], t.Format, t.Width, t.Height)
+}
+
+// Renderer builds thumbnails of case networks from the graph
+type Renderer struct {
+	neo4jClient *neo4j.Client
+	config      config.ThumbnailConfig
+	cache       *Cache
+	logger      *slog.Logger
+}
+
+// NewRenderer creates a new thumbnail renderer
+func NewRenderer(client *neo4j.Client, config config.ThumbnailConfig, logger *slog.Logger) *Renderer {
+	return &Renderer{
+		neo4jClient: client,
+		config:      config,
+		cache:       NewCache(config.CacheSize, config.CacheTTL),
+		logger:      logger,
+	}
+}
+
+// Normalize fills in defaults and clamps a request to the configured limits
+func Normalize(req *Request, cfg config.ThumbnailConfig) error {
+	switch req.Format {
+	case "":
+		req.Format = FormatSVG
+	case FormatSVG, FormatPNG:
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, req.Format)
+	}
+
+	if req.Depth <= 0 {
+		req.Depth = cfg.DefaultDepth
+	}
+	if req.Depth > cfg.MaxDepth {
+		req.Depth = cfg.MaxDepth
+	}
+	if req.MaxNodes <= 0 || req.MaxNodes > cfg.MaxNodes {
+		req.MaxNodes = cfg.MaxNodes
+	}
+	if req.Width <= 0 {
+		req.Width = cfg.DefaultWidth
+	}
+	if req.Width > cfg.MaxWidth {
+		req.Width = cfg.MaxWidth
+	}
+	if req.Height <= 0 {
+		req.Height = cfg.DefaultHeight
+	}
+	if req.Height > cfg.MaxHeight {
+		req.Height = cfg.MaxHeight
+	}
+
+	return nil
+}
+
+// Render returns a thumbnail of the network around the requested entities.
+// The subgraph is always read so that the version reflects the current
+// graph, but layout and drawing are skipped when that version has already
+// been rendered at the same format and size.
+func (r *Renderer) Render(ctx context.Context, req *Request) (*Thumbnail, error) {
+	if err := Normalize(req, r.config); err != nil {
+		return nil, err
+	}
+
+	graph, err := r.fetchGraph(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(graph.Nodes) == 0 {
+		return nil, ErrEmptyGraph
+	}
+
+	graph, truncated := Cap(graph, req.MaxNodes)
+	version := Version(graph)
+
+	key := fmt.Sprintf("%s:%s:%dx%d", version, req.Format, req.Width, req.Height)
+	if cached, ok := r.cache.Get(key); ok {
+		return cached, nil
+	}
+
+	start := time.Now()
+	positions := Layout(graph, float64(req.Width), float64(req.Height),
+		float64(r.config.Padding), r.config.LayoutIterations)
+
+	thumbnail := &Thumbnail{
+		Version:     version,
+		Format:      req.Format,
+		Width:       req.Width,
+		Height:      req.Height,
+		NodeCount:   len(graph.Nodes),
+		EdgeCount:   len(graph.Edges),
+		Truncated:   truncated,
+		GeneratedAt: time.Now(),
+	}
+
+	switch req.Format {
+	case FormatPNG:
+		thumbnail.ContentType = "image/png"
+		thumbnail.Data, err = RenderPNG(graph, positions, req.Width, req.Height)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		thumbnail.ContentType = "image/svg+xml"
+		thumbnail.Data = RenderSVG(graph, positions, req.Width, req.Height)
+	}
+
+	r.cache.Put(key, thumbnail)
+
+	r.logger.Debug("Rendered graph thumbnail",
+		"version", version,
+		"format", req.Format,
+		"nodes", thumbnail.NodeCount,
+		"edges", thumbnail.EdgeCount,
+		"truncated", truncated,
+		"duration", time.Since(start))
+
+	return thumbnail, nil
+}
+
+// fetchGraph reads the neighbourhood of the seed entities. More nodes than
+// the cap are fetched so that Cap can prefer well connected entities at the
+// boundary distance.
+func (r *Renderer) fetchGraph(ctx context.Context, req *Request) (*Graph, error) {
+	query := fmt.Sprintf(`
+		MATCH (seed:Entity)
+		WHERE seed.id IN $entityIds
+		MATCH p = (seed)-[*0..%d]-(n:Entity)
+		WITH n, min(length(p)) AS distance
+		ORDER BY distance, n.id
+		LIMIT $fetchLimit
+		WITH collect(n) AS members, collect({
+			id: n.id,
+			type: coalesce(n.entity_type, n.type, head(labels(n))),
+			distance: distance,
+			updatedAt: toString(coalesce(n.updated_at, ''))
+		}) AS nodes
+		UNWIND members AS a
+		OPTIONAL MATCH (a)-[r]->(b:Entity)
+		WHERE b IN members
+		RETURN nodes,
+			   collect(CASE WHEN r IS NULL THEN NULL ELSE {
+				   id: coalesce(r.id, elementId(r)),
+				   type: type(r),
+				   source: a.id,
+				   target: b.id
+			   } END) AS edges
+	`, req.Depth)
+
+	records, err := r.neo4jClient.ExecuteQuery(ctx, query, map[string]interface{}{
+		"entityIds":  req.EntityIDs,
+		"fetchLimit": req.MaxNodes * r.config.FetchMultiplier,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query thumbnail subgraph: %w", err)
+	}
+
+	graph := &Graph{}
+	if len(records) == 0 {
+		return graph, nil
+	}
+
+	if nodes, ok := records[0]["nodes"].([]interface{}); ok {
+		for _, item := range nodes {
+			values, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			graph.Nodes = append(graph.Nodes, Node{
+				ID:        getString(values, "id"),
+				Type:      getString(values, "type"),
+				Distance:  getInt(values, "distance"),
+				UpdatedAt: getString(values, "updatedAt"),
+			})
+		}
+	}
+
+	if edges, ok := records[0]["edges"].([]interface{}); ok {
+		for _, item := range edges {
+			values, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			graph.Edges = append(graph.Edges, Edge{
+				ID:       getString(values, "id"),
+				Type:     getString(values, "type"),
+				SourceID: getString(values, "source"),
+				TargetID: getString(values, "target"),
+			})
+		}
+	}
+
+	return graph, nil
+}
+
+func getString(record map[string]interface{}, key string) string {
+	if val, ok := record[key].(string); ok {
+		return val
+	}
+	return ""
+}
+
+func getInt(record map[string]interface{}, key string) int {
+	switch v := record[key].(type) {
+	case int64:
+		return int(v)
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
@@ -0,0 +1,3 @@
+-- Drop entity access audit tables
+DROP TABLE IF EXISTS entity_access_alerts;
+DROP TABLE IF EXISTS entity_access_events;
@@ -0,0 +1,45 @@
+-- Create entity_access_events table recording who viewed which entities
+CREATE TABLE IF NOT EXISTS entity_access_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id VARCHAR(255) NOT NULL,
+    entity_ids TEXT[] NOT NULL,
+    view_type VARCHAR(100) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    query_params JSONB,
+    investigation_id VARCHAR(255),
+    client_ip VARCHAR(100),
+    status_code INTEGER NOT NULL,
+    accessed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_access_events_entity_ids ON entity_access_events USING GIN(entity_ids);
+CREATE INDEX IF NOT EXISTS idx_entity_access_events_user_accessed ON entity_access_events(user_id, accessed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_entity_access_events_accessed_at ON entity_access_events(accessed_at);
+
+-- Create entity_access_alerts table for excessive browsing outside assigned cases
+CREATE TABLE IF NOT EXISTS entity_access_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id VARCHAR(255) NOT NULL,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    window_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    distinct_entities INTEGER NOT NULL,
+    unassigned_entities INTEGER NOT NULL,
+    sample_entity_ids TEXT[] NOT NULL,
+    severity VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'acknowledged')),
+    acknowledged_by VARCHAR(255),
+    acknowledged_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_access_alerts_user_created ON entity_access_alerts(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_entity_access_alerts_status ON entity_access_alerts(status);
+
+-- Add comments
+COMMENT ON TABLE entity_access_events IS 'Audit trail of entity and subgraph views for insider-risk review';
+COMMENT ON COLUMN entity_access_events.entity_ids IS 'Entity IDs requested by the view';
+COMMENT ON COLUMN entity_access_events.view_type IS 'Kind of view (neighborhood, subgraph, paths, thumbnail, ...)';
+COMMENT ON COLUMN entity_access_events.query_params IS 'Query string and request body parameters of the view';
+COMMENT ON COLUMN entity_access_events.investigation_id IS 'Investigation the user declared the view was for, if any';
+COMMENT ON TABLE entity_access_alerts IS 'Users browsing many entities outside their assigned investigations';
@@ -0,0 +1,3 @@
+-- Drop query template tables
+DROP TABLE IF EXISTS query_executions;
+DROP TABLE IF EXISTS query_templates;
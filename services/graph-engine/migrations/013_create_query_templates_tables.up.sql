@@ -0,0 +1,50 @@
+-- Create query_templates table of saved, parameterized investigative queries
+CREATE TABLE IF NOT EXISTS query_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    query TEXT NOT NULL,
+    parameters JSONB NOT NULL DEFAULT '[]',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    visibility VARCHAR(20) NOT NULL DEFAULT 'private' CHECK (visibility IN ('private', 'roles', 'public')),
+    shared_roles TEXT[] NOT NULL DEFAULT '{}',
+    owner_id VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_query_templates_owner_id ON query_templates(owner_id);
+CREATE INDEX IF NOT EXISTS idx_query_templates_shared_roles ON query_templates USING GIN(shared_roles);
+CREATE INDEX IF NOT EXISTS idx_query_templates_tags ON query_templates USING GIN(tags);
+
+-- Create query_executions table recording every template run
+CREATE TABLE IF NOT EXISTS query_executions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    template_id UUID NOT NULL,
+    template_name VARCHAR(255) NOT NULL,
+    template_version INTEGER NOT NULL,
+    query TEXT NOT NULL,
+    parameters JSONB,
+    user_id VARCHAR(255) NOT NULL,
+    investigation_id VARCHAR(255),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('succeeded', 'failed', 'rejected')),
+    row_count INTEGER NOT NULL DEFAULT 0,
+    truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    error TEXT,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    executed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_query_executions_template_executed ON query_executions(template_id, executed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_query_executions_user_executed ON query_executions(user_id, executed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_query_executions_investigation_id ON query_executions(investigation_id);
+
+-- Add comments
+COMMENT ON TABLE query_templates IS 'Saved read-only Cypher queries analysts run with their own parameter values';
+COMMENT ON COLUMN query_templates.parameters IS 'Declared $parameters with their types and validation constraints';
+COMMENT ON COLUMN query_templates.visibility IS 'private to the owner, shared with shared_roles, or public';
+COMMENT ON TABLE query_executions IS 'History of query template runs; kept when the template is deleted';
+COMMENT ON COLUMN query_executions.query IS 'Query text of the template version that ran';
+COMMENT ON COLUMN query_executions.status IS 'succeeded, failed in Neo4j, or rejected for invalid parameters';
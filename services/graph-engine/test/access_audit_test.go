@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/graph-engine/internal/accessaudit"
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/database"
+)
+
+type recordingStore struct {
+	events []*database.EntityAccessEvent
+}
+
+func (s *recordingStore) RecordEntityAccess(_ context.Context, event *database.EntityAccessEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestEntityAccessAudit_Unit(t *testing.T) {
+	t.Run("Extract Entity IDs", func(t *testing.T) {
+		ids := accessaudit.ExtractEntityIDs(
+			map[string]string{"id": "ent-3"},
+			url.Values{"entity_ids": {"ent-1, ent-2"}},
+			[]byte(`{"source_ids": ["ent-2", "ent-4"], "target_ids": ["ent-5"], "max_length": 4}`),
+		)
+		assert.Equal(t, []string{"ent-1", "ent-2", "ent-3", "ent-4", "ent-5"}, ids)
+
+		assert.Empty(t, accessaudit.ExtractEntityIDs(nil, nil, []byte(`not json`)))
+	})
+
+	t.Run("Excessive Browsing", func(t *testing.T) {
+		views := map[string][]string{
+			"analyst-1": {"e1", "e2", "e3", "e4"},
+			"analyst-2": {"e1", "e2", "e3", "e4", "e5", "e6"},
+			"analyst-3": {"e1", "e2"},
+		}
+		assigned := map[string]map[string]bool{
+			"analyst-1": {"e1": true, "e2": true, "e3": true},
+		}
+
+		findings := accessaudit.FindExcessiveBrowsing(views, assigned, 2)
+		require.Len(t, findings, 1, "views inside assigned investigations are not counted")
+		assert.Equal(t, "analyst-2", findings[0].UserID)
+		assert.Equal(t, 6, findings[0].DistinctEntities)
+		assert.Len(t, findings[0].Unassigned, 6)
+	})
+
+	t.Run("Severity", func(t *testing.T) {
+		assert.Equal(t, "medium", accessaudit.Severity(60, 50))
+		assert.Equal(t, "high", accessaudit.Severity(100, 50))
+		assert.Equal(t, "critical", accessaudit.Severity(200, 50))
+	})
+
+	t.Run("Recorder Middleware", func(t *testing.T) {
+		store := &recordingStore{}
+		recorder := accessaudit.NewRecorder(store, config.AccessAuditConfig{
+			UserHeader:          "X-User-ID",
+			InvestigationHeader: "X-Investigation-ID",
+		}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+		var handlerBody string
+		router := mux.NewRouter()
+		router.HandleFunc("/api/v1/entities/{id}/neighborhood", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}).Methods("GET")
+		router.HandleFunc("/api/v1/analysis/paths", func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			handlerBody = string(body)
+			w.WriteHeader(http.StatusNotFound)
+		}).Methods("POST")
+		router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+		router.Use(recorder.Middleware)
+
+		req := httptest.NewRequest("GET", "/api/v1/entities/ent-9/neighborhood?depth=2", nil)
+		req.Header.Set("X-User-ID", "analyst-1")
+		req.Header.Set("X-Investigation-ID", "inv-7")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		body := `{"source_ids":["ent-1"],"target_ids":["ent-2"]}`
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/v1/analysis/paths", strings.NewReader(body)))
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/health", nil))
+
+		require.Len(t, store.events, 2, "only audited views are recorded")
+
+		neighborhood := store.events[0]
+		assert.Equal(t, "analyst-1", neighborhood.UserID)
+		assert.Equal(t, []string{"ent-9"}, neighborhood.EntityIDs)
+		assert.Equal(t, "neighborhood", neighborhood.ViewType)
+		assert.Equal(t, "inv-7", neighborhood.InvestigationID)
+		assert.Equal(t, http.StatusOK, neighborhood.StatusCode)
+		assert.Contains(t, neighborhood.QueryParams, "query")
+
+		paths := store.events[1]
+		assert.Equal(t, accessaudit.UnknownUser, paths.UserID)
+		assert.Equal(t, []string{"ent-1", "ent-2"}, paths.EntityIDs)
+		assert.Equal(t, http.StatusNotFound, paths.StatusCode)
+		assert.Equal(t, body, handlerBody, "the handler still receives the full body")
+	})
+}
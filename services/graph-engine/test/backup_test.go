@@ -0,0 +1,318 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/graph-engine/internal/backup"
+	"github.com/aegisshield/graph-engine/internal/config"
+)
+
+// fakeGraph answers the backup export queries from in-memory nodes and
+// relationships
+type fakeGraph struct {
+	nodes         []map[string]interface{}
+	relationships []map[string]interface{}
+	queries       []string
+}
+
+func (g *fakeGraph) ExecuteQuery(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	g.queries = append(g.queries, query)
+	switch {
+	case strings.Contains(query, "labels(n)"):
+		return g.nodes, nil
+	case strings.Contains(query, "type(r)"):
+		return g.relationships, nil
+	case strings.Contains(query, "MATCH (n) RETURN coalesce"):
+		return keysOf(g.nodes), nil
+	case strings.Contains(query, "MATCH ()-[r]->() RETURN coalesce"):
+		return keysOf(g.relationships), nil
+	case strings.Contains(query, "count(n)"):
+		return []map[string]interface{}{{"count": int64(len(g.nodes))}}, nil
+	}
+	return nil, nil
+}
+
+func keysOf(rows []map[string]interface{}) []map[string]interface{} {
+	keys := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, map[string]interface{}{"key": row["key"]})
+	}
+	return keys
+}
+
+func backupNode(key string, properties map[string]interface{}) map[string]interface{} {
+	properties["id"] = key
+	return map[string]interface{}{"key": key, "labels": []interface{}{"Entity", "Person"}, "properties": properties}
+}
+
+func backupRelationship(key, source, target string) map[string]interface{} {
+	return map[string]interface{}{"key": key, "type": "TRANSFER", "source": source, "target": target,
+		"properties": map[string]interface{}{"amount": 1250.5}}
+}
+
+func newBackupService(t *testing.T, graph backup.QueryRunner) (*backup.Service, string) {
+	dir := t.TempDir()
+	cfg := config.BackupConfig{Storage: "filesystem", Path: dir, Prefix: "graph", FullEvery: 3, Interval: time.Hour}
+	store, err := backup.NewStore(cfg)
+	require.NoError(t, err)
+	return backup.NewService(store, graph, cfg, slog.New(slog.NewTextHandler(io.Discard, nil))), dir
+}
+
+func TestBackup_Unit(t *testing.T) {
+	t.Run("Property Values Round Trip", func(t *testing.T) {
+		when := time.Date(2026, 10, 1, 12, 30, 0, 500, time.UTC)
+		values := map[string]interface{}{
+			"name":     "Acme",
+			"count":    int64(42),
+			"ratio":    2.0,
+			"active":   true,
+			"tags":     []interface{}{"a", "b"},
+			"seen_at":  when,
+			"born":     dbtype.Date(time.Date(1980, 5, 17, 0, 0, 0, 0, time.UTC)),
+			"duration": dbtype.Duration{Months: 1, Days: 2, Seconds: 3, Nanos: 4},
+			"location": dbtype.Point2D{SpatialRefId: 4326, X: 13.4, Y: 52.5},
+			"missing":  nil,
+		}
+
+		encoded, err := backup.EncodeProperties(values)
+		require.NoError(t, err)
+		raw, err := json.Marshal(encoded)
+		require.NoError(t, err)
+
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		var read map[string]interface{}
+		require.NoError(t, decoder.Decode(&read))
+
+		decoded, err := backup.DecodeProperties(read)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", decoded["name"])
+		assert.Equal(t, int64(42), decoded["count"])
+		assert.Equal(t, 2.0, decoded["ratio"], "integral floats stay floats")
+		assert.Equal(t, true, decoded["active"])
+		assert.Equal(t, []interface{}{"a", "b"}, decoded["tags"])
+		assert.True(t, when.Equal(decoded["seen_at"].(time.Time)))
+		assert.Equal(t, values["born"], decoded["born"])
+		assert.Equal(t, values["duration"], decoded["duration"])
+		assert.Equal(t, values["location"], decoded["location"])
+		assert.Nil(t, decoded["missing"])
+
+		_, err = backup.EncodeValue(struct{}{})
+		assert.Error(t, err)
+	})
+
+	t.Run("Incremental Snapshots Replay Changes And Deletions", func(t *testing.T) {
+		ctx := context.Background()
+		graph := &fakeGraph{
+			nodes: []map[string]interface{}{
+				backupNode("p1", map[string]interface{}{"name": "Alice"}),
+				backupNode("p2", map[string]interface{}{"name": "Bob"}),
+				backupNode("p3", map[string]interface{}{"name": "Carol"}),
+			},
+			relationships: []map[string]interface{}{
+				backupRelationship("t1", "p1", "p2"),
+				backupRelationship("t2", "p2", "p3"),
+			},
+		}
+		service, _ := newBackupService(t, graph)
+
+		full, err := service.TakeSnapshot(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, backup.KindFull, full.Kind, "the first snapshot is full")
+		assert.Equal(t, 3, full.Nodes)
+		assert.NotEmpty(t, full.SHA256)
+
+		// Bob is renamed and Carol deleted along with her relationship
+		time.Sleep(10 * time.Millisecond)
+		graph.nodes = []map[string]interface{}{
+			backupNode("p1", map[string]interface{}{"name": "Alice"}),
+			backupNode("p2", map[string]interface{}{"name": "Robert"}),
+		}
+		graph.relationships = []map[string]interface{}{backupRelationship("t1", "p1", "p2")}
+		incremental, err := service.TakeSnapshot(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, backup.KindIncremental, incremental.Kind)
+		assert.Equal(t, full.ID, incremental.ParentID)
+		assert.Equal(t, full.ID, incremental.BaseID)
+		require.NotNil(t, incremental.Since)
+		assert.True(t, incremental.Since.Equal(full.TakenAt))
+		assert.Equal(t, 2, incremental.LiveNodes)
+
+		// Restoring to before the incremental replays only the full snapshot
+		plan, err := service.PlanRestore(ctx, full.TakenAt.Add(time.Millisecond))
+		require.NoError(t, err)
+		assert.Equal(t, full.ID, plan.Target.ID)
+		restored, err := service.Materialize(ctx, plan)
+		require.NoError(t, err)
+		assert.Len(t, restored.Nodes, 3)
+		assert.Len(t, restored.Relationships, 2)
+
+		plan, err = service.PlanRestore(ctx, time.Now())
+		require.NoError(t, err)
+		require.Len(t, plan.Chain, 2)
+		assert.Equal(t, full.ID, plan.Chain[0].ID)
+		assert.Equal(t, incremental.ID, plan.Chain[1].ID)
+
+		restored, err = service.Materialize(ctx, plan)
+		require.NoError(t, err)
+		assert.Len(t, restored.Nodes, 2)
+		assert.Nil(t, restored.Nodes["p3"])
+		assert.Equal(t, "Robert", restored.Nodes["p2"].Properties["name"])
+		assert.Len(t, restored.Relationships, 1)
+		assert.NotNil(t, restored.Relationships["t1"])
+
+		_, err = service.PlanRestore(ctx, full.TakenAt.Add(-time.Hour))
+		assert.ErrorIs(t, err, backup.ErrNoSnapshot)
+	})
+
+	t.Run("Chain Is Capped At FullEvery", func(t *testing.T) {
+		ctx := context.Background()
+		graph := &fakeGraph{nodes: []map[string]interface{}{backupNode("p1", map[string]interface{}{})}}
+		service, _ := newBackupService(t, graph)
+
+		var kinds []string
+		for i := 0; i < 4; i++ {
+			manifest, err := service.TakeSnapshot(ctx, false)
+			require.NoError(t, err)
+			kinds = append(kinds, manifest.Kind)
+			time.Sleep(2 * time.Millisecond)
+		}
+		assert.Equal(t, []string{backup.KindFull, backup.KindIncremental, backup.KindIncremental, backup.KindFull}, kinds)
+
+		manifest, err := service.TakeSnapshot(ctx, true)
+		require.NoError(t, err)
+		assert.Equal(t, backup.KindFull, manifest.Kind, "a full snapshot can be forced")
+
+		snapshots, err := service.ListSnapshots(ctx)
+		require.NoError(t, err)
+		assert.Len(t, snapshots, 5)
+	})
+
+	t.Run("Verify Detects Tampering And Broken Chains", func(t *testing.T) {
+		ctx := context.Background()
+		graph := &fakeGraph{nodes: []map[string]interface{}{backupNode("p1", map[string]interface{}{})}}
+		service, dir := newBackupService(t, graph)
+
+		full, err := service.TakeSnapshot(ctx, false)
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond)
+		incremental, err := service.TakeSnapshot(ctx, false)
+		require.NoError(t, err)
+
+		result, err := service.Verify(ctx, incremental.ID)
+		require.NoError(t, err)
+		assert.True(t, result.Valid, "problems: %v", result.Problems)
+		assert.Equal(t, []string{full.ID, incremental.ID}, result.Chain)
+
+		// Flip a byte in the base snapshot
+		dataPath := filepath.Join(dir, filepath.FromSlash(full.DataKey))
+		data, err := os.ReadFile(dataPath)
+		require.NoError(t, err)
+		data[len(data)/2] ^= 0xff
+		require.NoError(t, os.WriteFile(dataPath, data, 0o600))
+
+		result, err = service.Verify(ctx, incremental.ID)
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.NotEmpty(t, result.Problems)
+
+		plan, err := service.PlanSnapshot(ctx, incremental.ID, time.Time{})
+		require.NoError(t, err)
+		_, err = service.Materialize(ctx, plan)
+		assert.ErrorIs(t, err, backup.ErrCorruptSnapshot)
+
+		// Losing the base breaks the chain
+		require.NoError(t, os.Remove(filepath.Join(dir, "graph", "manifests", full.ID+".json")))
+		_, err = service.PlanSnapshot(ctx, incremental.ID, time.Time{})
+		assert.ErrorIs(t, err, backup.ErrBrokenChain)
+
+		_, err = service.GetSnapshot(ctx, "missing")
+		assert.ErrorIs(t, err, backup.ErrSnapshotNotFound)
+	})
+
+	t.Run("Restore Refuses Non-Empty Target Without Wipe", func(t *testing.T) {
+		graph := &fakeGraph{nodes: []map[string]interface{}{backupNode("p1", map[string]interface{}{})}}
+		restorer := backup.NewRestorer(graph, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+		_, err := restorer.Restore(context.Background(), &backup.Graph{}, backup.RestoreOptions{})
+		assert.ErrorIs(t, err, backup.ErrTargetNotEmpty)
+		for _, query := range graph.queries {
+			assert.NotContains(t, query, "CREATE", "nothing is written")
+		}
+	})
+
+	t.Run("S3 Store Signs Requests And Pages Listings", func(t *testing.T) {
+		objects := map[string][]byte{}
+		var authorizations []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorizations = append(authorizations, r.Header.Get("Authorization"))
+			key := strings.TrimPrefix(r.URL.Path, "/backups/")
+			switch {
+			case r.Method == http.MethodPut:
+				body, _ := io.ReadAll(r.Body)
+				objects[key] = body
+			case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+				if r.URL.Query().Get("continuation-token") == "" {
+					io.WriteString(w, `<ListBucketResult><Contents><Key>graph/b</Key></Contents><IsTruncated>true</IsTruncated><NextContinuationToken>next</NextContinuationToken></ListBucketResult>`)
+					return
+				}
+				io.WriteString(w, `<ListBucketResult><Contents><Key>graph/a</Key></Contents><IsTruncated>false</IsTruncated></ListBucketResult>`)
+			case r.Method == http.MethodGet:
+				body, ok := objects[key]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write(body)
+			}
+		}))
+		defer server.Close()
+
+		store := backup.NewS3Store(config.S3Config{
+			Endpoint:        server.URL,
+			Region:          "eu-west-1",
+			Bucket:          "backups",
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "secret",
+			Timeout:         5 * time.Second,
+		})
+		ctx := context.Background()
+
+		require.NoError(t, store.Put(ctx, "graph/manifests/one two.json", strings.NewReader("{}")))
+		assert.Equal(t, []byte("{}"), objects["graph/manifests/one two.json"])
+
+		body, err := store.Get(ctx, "graph/manifests/one two.json")
+		require.NoError(t, err)
+		data, _ := io.ReadAll(body)
+		body.Close()
+		assert.Equal(t, "{}", string(data))
+
+		_, err = store.Get(ctx, "graph/missing")
+		assert.ErrorIs(t, err, backup.ErrObjectNotFound)
+
+		keys, err := store.List(ctx, "graph/")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"graph/a", "graph/b"}, keys)
+
+		for _, authorization := range authorizations {
+			assert.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+			assert.Contains(t, authorization, "/eu-west-1/s3/aws4_request")
+			assert.Contains(t, authorization, "SignedHeaders=host;x-amz-content-sha256;x-amz-date")
+		}
+	})
+}
@@ -0,0 +1,337 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/graph-engine/internal/blocking"
+	"github.com/aegisshield/graph-engine/internal/config"
+)
+
+var blockedLabel = regexp.MustCompile(`\(e:(\w+)`)
+
+type blockedEntity struct {
+	entityType string
+	id         string
+	properties map[string]interface{}
+	fields     []interface{}
+	version    string
+	keys       []string
+}
+
+// fakeBlockGraph answers the blocking store's Cypher from memory
+type fakeBlockGraph struct {
+	entities []*blockedEntity
+}
+
+func (g *fakeBlockGraph) add(entityType, id, name string) *blockedEntity {
+	entity := &blockedEntity{entityType: entityType, id: id, properties: map[string]interface{}{"id": id, "name": name}}
+	g.entities = append(g.entities, entity)
+	return entity
+}
+
+func (g *fakeBlockGraph) fieldValues(entity *blockedEntity, fields []string) []interface{} {
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		values[i] = ""
+		if value, ok := entity.properties[field]; ok {
+			values[i] = fmt.Sprint(value)
+		}
+	}
+	return values
+}
+
+func (g *fakeBlockGraph) blocks() map[string][]*blockedEntity {
+	blocks := make(map[string][]*blockedEntity)
+	for _, entity := range g.entities {
+		for _, key := range entity.keys {
+			blocks[key] = append(blocks[key], entity)
+		}
+	}
+	return blocks
+}
+
+func (g *fakeBlockGraph) row(entity *blockedEntity) map[string]interface{} {
+	return map[string]interface{}{"entityId": entity.id, "properties": entity.properties}
+}
+
+func (g *fakeBlockGraph) ExecuteQuery(_ context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	entityType := ""
+	if match := blockedLabel.FindStringSubmatch(query); match != nil {
+		entityType = match[1]
+	}
+
+	switch {
+	case strings.Contains(query, "CREATE CONSTRAINT"):
+		return nil, nil
+
+	case strings.Contains(query, "_blocking_version IS NULL"):
+		fields := params["fields"].([]string)
+		var rows []map[string]interface{}
+		for _, entity := range g.entities {
+			values := g.fieldValues(entity, fields)
+			if entity.entityType != entityType || (entity.version == params["version"] && fmt.Sprint(entity.fields) == fmt.Sprint(values)) {
+				continue
+			}
+			if len(rows) == params["limit"].(int) {
+				break
+			}
+			row := g.row(entity)
+			row["fields"] = values
+			rows = append(rows, row)
+		}
+		return rows, nil
+
+	case strings.Contains(query, "UNWIND $rows"):
+		for _, row := range params["rows"].([]map[string]interface{}) {
+			for _, entity := range g.entities {
+				if entity.entityType == entityType && entity.id == row["id"] {
+					entity.keys = row["keys"].([]string)
+					entity.fields = nil
+					for _, value := range row["fields"].([]string) {
+						entity.fields = append(entity.fields, value)
+					}
+					entity.version = params["version"].(string)
+				}
+			}
+		}
+		return nil, nil
+
+	case strings.Contains(query, "NOT (b)<-[:IN_BLOCK]-()"):
+		return []map[string]interface{}{{"pruned": int64(0)}}, nil
+
+	case strings.Contains(query, "STARTS WITH $prefix"):
+		var keys []string
+		for key := range g.blocks() {
+			if strings.HasPrefix(key, params["prefix"].(string)) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		key, window := params["key"].(string), params["window"].(int)
+		at := sort.SearchStrings(keys, key)
+		var rows []map[string]interface{}
+		for i := at - window; i < at+window && i < len(keys); i++ {
+			if i >= 0 {
+				rows = append(rows, map[string]interface{}{"key": keys[i]})
+			}
+		}
+		return rows, nil
+
+	case strings.Contains(query, "COUNT {"):
+		blocks := g.blocks()
+		var rows []map[string]interface{}
+		for _, key := range params["keys"].([]string) {
+			if members, ok := blocks[key]; ok {
+				rows = append(rows, map[string]interface{}{"key": key, "size": int64(len(members))})
+			}
+		}
+		return rows, nil
+
+	case strings.Contains(query, "AS shared"):
+		blocks := g.blocks()
+		shared := make(map[*blockedEntity]int)
+		for _, key := range params["keys"].([]string) {
+			for _, entity := range blocks[key] {
+				if entity.entityType == entityType {
+					shared[entity]++
+				}
+			}
+		}
+		var rows []map[string]interface{}
+		for _, entity := range g.entities {
+			if count, ok := shared[entity]; ok {
+				row := g.row(entity)
+				row["shared"] = int64(count)
+				rows = append(rows, row)
+			}
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i]["shared"].(int64) > rows[j]["shared"].(int64)
+		})
+		if limit := params["limit"].(int); len(rows) > limit {
+			rows = rows[:limit]
+		}
+		return rows, nil
+
+	default:
+		var rows []map[string]interface{}
+		for _, entity := range g.entities {
+			if entity.entityType == entityType {
+				rows = append(rows, g.row(entity))
+			}
+		}
+		return rows, nil
+	}
+}
+
+func blockingConfig() config.BlockingConfig {
+	return config.BlockingConfig{
+		Enabled:           true,
+		EntityTypes:       []string{"Person", "Company"},
+		Fields:            []string{"name"},
+		QGramSize:         3,
+		SortedWindow:      1,
+		MaxBlockSize:      100,
+		MaxCandidates:     50,
+		IndexInterval:     time.Minute,
+		IndexBatchSize:    2,
+		RecallSampleLimit: 1000,
+	}
+}
+
+func candidateIDs(records []*blocking.Record) []string {
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = record.EntityID
+	}
+	return ids
+}
+
+func TestBlocking_Keys(t *testing.T) {
+	assert.Equal(t, "smith john", blocking.Normalize("  Smith,  JOHN! "))
+	assert.Equal(t, []string{"joh", "ohn", "smi", "mit", "ith", "al"}, blocking.QGrams("john smith al", 3))
+	assert.Equal(t, "johnsmith", blocking.SortedKey("smith john"))
+
+	keys := blocking.KeysFor("Person", map[string]interface{}{"name": "Smith, John", "alias": nil}, blockingConfig())
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = key.Value
+	}
+	assert.Contains(t, values, "Person|qg|name:smi")
+	assert.Contains(t, values, "Person|sn|name:johnsmith")
+	assert.True(t, strings.HasPrefix("Person|sn|name:johnsmith", blocking.SortedPrefix("Person", "name")))
+
+	assert.Empty(t, blocking.KeysFor("Person", map[string]interface{}{"name": "  "}, blockingConfig()))
+	assert.Empty(t, blocking.KeysFor("Person", map[string]interface{}{"name": nil}, blockingConfig()))
+}
+
+func TestBlocking_IndexAndCandidates(t *testing.T) {
+	graph := &fakeBlockGraph{}
+	graph.add("Person", "p1", "John Smith")
+	graph.add("Person", "p2", "Jon Smith")
+	graph.add("Person", "p3", "Maria Garcia")
+	graph.add("Person", "p4", "Abc Xyz")
+	graph.add("Company", "c1", "John Smith Holdings")
+	graph.add("Vessel", "v1", "John Smith")
+
+	blocker := blocking.NewBlocker(graph, blockingConfig(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	indexed, err := blocker.IndexPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, indexed, "only configured entity types are indexed")
+
+	indexed, err = blocker.IndexPending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, indexed, "indexed entities are not indexed again")
+
+	t.Run("QGram Blocks", func(t *testing.T) {
+		records, err := blocker.Candidates(ctx, "Person", map[string]interface{}{"name": "John Smyth"})
+		require.NoError(t, err)
+		ids := candidateIDs(records)
+		assert.Equal(t, "p1", ids[0], "the entity sharing most blocks ranks first")
+		assert.ElementsMatch(t, []string{"p1", "p2"}, ids)
+		assert.Equal(t, "John Smith", records[0].Properties["name"])
+	})
+
+	t.Run("Sorted Neighborhood", func(t *testing.T) {
+		// "abd" shares no q-gram with "Abc Xyz" but their sorted keys are adjacent
+		records, err := blocker.Candidates(ctx, "Person", map[string]interface{}{"name": "Abd"})
+		require.NoError(t, err)
+		assert.Contains(t, candidateIDs(records), "p4")
+	})
+
+	t.Run("Not Blocked", func(t *testing.T) {
+		_, err := blocker.Candidates(ctx, "Vessel", map[string]interface{}{"name": "John Smith"})
+		assert.ErrorIs(t, err, blocking.ErrNotBlocked)
+
+		_, err = blocker.Candidates(ctx, "Person", map[string]interface{}{"email": "john@example.com"})
+		assert.ErrorIs(t, err, blocking.ErrNotBlocked)
+	})
+
+	t.Run("Changed Fields Are Reindexed", func(t *testing.T) {
+		graph.entities[2].properties["name"] = "Maria Smith"
+
+		indexed, err := blocker.IndexPending(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, indexed)
+
+		records, err := blocker.Candidates(ctx, "Person", map[string]interface{}{"name": "John Smith"})
+		require.NoError(t, err)
+		assert.Contains(t, candidateIDs(records), "p3")
+	})
+}
+
+// blockingMetric reads a counter or histogram sample count for a type
+func blockingMetric(t *testing.T, name, entityType string) (float64, float64) {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() != "entity_type" || label.GetValue() != entityType {
+					continue
+				}
+				if histogram := metric.GetHistogram(); histogram != nil {
+					return float64(histogram.GetSampleCount()), histogram.GetSampleSum()
+				}
+				return metric.GetCounter().GetValue(), 0
+			}
+		}
+	}
+	return 0, 0
+}
+
+func TestBlocking_OversizedBlocksAndRecall(t *testing.T) {
+	graph := &fakeBlockGraph{}
+	graph.add("Company", "c1", "Acme Trading")
+	graph.add("Company", "c2", "Acme Shipping")
+	graph.add("Company", "c3", "Acme Holdings")
+	graph.add("Company", "c4", "Zenith Trading")
+
+	cfg := blockingConfig()
+	cfg.SortedWindow = 0
+	cfg.MaxBlockSize = 2
+	blocker := blocking.NewBlocker(graph, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	_, err := blocker.IndexPending(ctx)
+	require.NoError(t, err)
+
+	before, _ := blockingMetric(t, "graph_engine_blocking_oversized_blocks_total", "Company")
+	records, err := blocker.Candidates(ctx, "Company", map[string]interface{}{"name": "Acme Trading"})
+	require.NoError(t, err)
+
+	// The "acm", "cme", "din" and "ing" blocks are skipped as too common;
+	// the other trading blocks still find both trading companies
+	assert.ElementsMatch(t, []string{"c1", "c4"}, candidateIDs(records))
+	after, _ := blockingMetric(t, "graph_engine_blocking_oversized_blocks_total", "Company")
+	assert.Equal(t, 4.0, after-before)
+
+	scanned, err := blocker.Scan(ctx, "Company")
+	require.NoError(t, err)
+	assert.Len(t, scanned, 4)
+
+	blocker.RecordRecall("Company", 4, 3)
+	blocker.RecordRecall("Company", 0, 0)
+	samples, _ := blockingMetric(t, "graph_engine_blocking_recall_samples_total", "Company")
+	assert.Equal(t, 2.0, samples)
+	count, sum := blockingMetric(t, "graph_engine_blocking_recall", "Company")
+	assert.Equal(t, 1.0, count, "samples without relevant matches record no recall")
+	assert.Equal(t, 0.75, sum)
+}
@@ -0,0 +1,337 @@
+package test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/exposure"
+)
+
+// exposureGraph is an in-memory entity graph answering the exposure queries.
+// Edges are undirected; their value is the transaction value between the
+// pair.
+type exposureGraph struct {
+	sources map[string]bool
+	edges   map[string]map[string]float64
+	stored  map[string]map[string]interface{} // entity to exposure properties
+}
+
+func newExposureGraph() *exposureGraph {
+	return &exposureGraph{
+		sources: make(map[string]bool),
+		edges:   make(map[string]map[string]float64),
+		stored:  make(map[string]map[string]interface{}),
+	}
+}
+
+func (g *exposureGraph) entity(id string) {
+	if g.edges[id] == nil {
+		g.edges[id] = make(map[string]float64)
+	}
+}
+
+func (g *exposureGraph) link(a, b string, value float64) {
+	g.entity(a)
+	g.entity(b)
+	g.edges[a][b] += value
+	g.edges[b][a] += value
+}
+
+// ball returns the entities within depth hops of the given ones
+func (g *exposureGraph) ball(ids []string, depth int) []string {
+	seen := make(map[string]bool)
+	var frontier []string
+	for _, id := range ids {
+		if _, ok := g.edges[id]; ok && !seen[id] {
+			seen[id] = true
+			frontier = append(frontier, id)
+		}
+	}
+	for hop := 0; hop < depth; hop++ {
+		var next []string
+		for _, id := range frontier {
+			for neighbour := range g.edges[id] {
+				if !seen[neighbour] {
+					seen[neighbour] = true
+					next = append(next, neighbour)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	out := make([]string, 0, len(seen))
+	for id := range seen {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func idRows(ids []string) []map[string]interface{} {
+	rows := make([]map[string]interface{}, len(ids))
+	for i, id := range ids {
+		rows[i] = map[string]interface{}{"id": id}
+	}
+	return rows
+}
+
+func (g *exposureGraph) ExecuteQuery(_ context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	const depth = 3
+
+	switch {
+	case strings.Contains(query, "AS loaded"):
+		nodes := g.ball(params["sources"].([]string), depth)
+		var rows []map[string]interface{}
+		for _, a := range nodes {
+			for _, b := range nodes {
+				if value, ok := g.edges[a][b]; ok && a < b {
+					rows = append(rows, map[string]interface{}{"from": a, "to": b, "value": value, "loaded": int64(len(nodes))})
+				}
+			}
+		}
+		return rows, nil
+	case strings.Contains(query, "RETURN DISTINCT source.id"):
+		var sources []string
+		for _, id := range g.ball(params["ids"].([]string), depth) {
+			if g.sources[id] {
+				sources = append(sources, id)
+			}
+		}
+		return idRows(sources), nil
+	case strings.Contains(query, "RETURN DISTINCT n.id"):
+		return idRows(g.ball(params["ids"].([]string), depth)), nil
+	case strings.Contains(query, "RETURN source.id"):
+		var sources []string
+		for id := range g.sources {
+			sources = append(sources, id)
+		}
+		sort.Strings(sources)
+		return idRows(sources), nil
+	case strings.Contains(query, "n.exposure_band AS band") && strings.Contains(query, "$ids"):
+		var rows []map[string]interface{}
+		for _, id := range params["ids"].([]string) {
+			row := map[string]interface{}{"id": id, "band": nil}
+			if stored, ok := g.stored[id]; ok {
+				row["band"] = stored["band"]
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	case strings.Contains(query, "exposure_band IS NOT NULL") && strings.Contains(query, "RETURN n.id AS id"):
+		var ids []string
+		for id := range g.stored {
+			ids = append(ids, id)
+		}
+		return idRows(ids), nil
+	case strings.Contains(query, "UNWIND $rows"):
+		for _, row := range params["rows"].([]map[string]interface{}) {
+			g.stored[row["id"].(string)] = row
+		}
+		return nil, nil
+	case strings.Contains(query, "REMOVE n.exposure_distance"):
+		for _, id := range params["ids"].([]string) {
+			delete(g.stored, id)
+		}
+		return nil, nil
+	case strings.Contains(query, "MATCH (n:Entity {id: $id})") && strings.Contains(query, "SET n."):
+		id := params["id"].(string)
+		if _, ok := g.edges[id]; !ok {
+			return nil, nil
+		}
+		g.sources[id] = params["source"].(bool)
+		return idRows([]string{id}), nil
+	}
+	return nil, nil
+}
+
+type recordingExposurePublisher struct {
+	events []*exposure.ChangeEvent
+}
+
+func (p *recordingExposurePublisher) PublishExposureChanged(_ context.Context, event *exposure.ChangeEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func exposureConfig() config.ExposureConfig {
+	return config.ExposureConfig{
+		Enabled:          true,
+		SourceProperties: []string{"sanctioned", "flagged"},
+		MaxDepth:         3,
+		TransactionType:  "TRANSACTION",
+		AmountProperty:   "amount",
+		Bands: []config.ExposureBand{
+			{Name: "critical", MaxDistance: 1, MinValue: 0},
+			{Name: "high", MaxDistance: 2, MinValue: 10000},
+			{Name: "medium", MaxDistance: 3, MinValue: 0},
+		},
+		RecomputeInterval: time.Minute,
+		FullInterval:      time.Hour,
+		SourceBatchSize:   1,
+		MaxSubgraphNodes:  1000,
+		BatchSize:         100,
+		MaxListLimit:      100,
+	}
+}
+
+func TestExposure_Compute(t *testing.T) {
+	// S1 - A - B - C - D, with S2 - B; transactions S1-A 500, A-B 200, S2-B 50
+	sub := exposure.Subgraph{
+		Sources: []string{"S1", "S2"},
+		Edges: []exposure.Edge{
+			{From: "S1", To: "A", Value: 500},
+			{From: "A", To: "B", Value: 200},
+			{From: "B", To: "C"},
+			{From: "C", To: "D"},
+			{From: "S2", To: "B", Value: 50},
+		},
+	}
+
+	t.Run("distance, value and nearest sources", func(t *testing.T) {
+		results := exposure.Compute(sub, 3)
+
+		require.Contains(t, results, "A")
+		assert.Equal(t, 1, results["A"].Distance)
+		assert.Equal(t, []string{"S1"}, results["A"].Sources)
+		assert.Equal(t, 500.0, results["A"].DirectValue)
+		// 500 from S1 directly, 50 from S2 through B
+		assert.Equal(t, 550.0, results["A"].Value)
+		assert.Equal(t, 2, results["A"].SourceCount)
+
+		require.Contains(t, results, "B")
+		assert.Equal(t, 1, results["B"].Distance)
+		assert.Equal(t, []string{"S2"}, results["B"].Sources)
+		// S1's value is bounded by the 200 transaction on A-B
+		assert.Equal(t, 250.0, results["B"].Value)
+		assert.Equal(t, 50.0, results["B"].DirectValue)
+
+		require.Contains(t, results, "C")
+		assert.Equal(t, 2, results["C"].Distance)
+		assert.Equal(t, 0.0, results["C"].Value, "B-C carries no transactions")
+
+		require.Contains(t, results, "D")
+		assert.Equal(t, 3, results["D"].Distance)
+		assert.Equal(t, 1, results["D"].SourceCount, "S1 is four hops from D")
+
+		assert.Equal(t, 0, results["S1"].Distance)
+	})
+
+	t.Run("depth bounds distance and value", func(t *testing.T) {
+		results := exposure.Compute(sub, 1)
+
+		assert.NotContains(t, results, "C")
+		assert.NotContains(t, results, "D")
+		assert.Equal(t, 500.0, results["A"].Value, "S2's value needs two hops")
+	})
+
+	t.Run("batches merge to the same result", func(t *testing.T) {
+		whole := exposure.Compute(sub, 3)
+
+		merged := exposure.Compute(exposure.Subgraph{Sources: []string{"S1"}, Edges: sub.Edges}, 3)
+		exposure.Merge(merged, exposure.Compute(exposure.Subgraph{Sources: []string{"S2"}, Edges: sub.Edges}, 3))
+
+		require.Len(t, merged, len(whole))
+		for id, expected := range whole {
+			assert.Equal(t, expected, merged[id], id)
+		}
+	})
+
+	t.Run("bands", func(t *testing.T) {
+		bands := exposureConfig().Bands
+
+		assert.Equal(t, "critical", exposure.BandFor(1, 0, bands))
+		assert.Equal(t, "high", exposure.BandFor(2, 25000, bands))
+		assert.Equal(t, "medium", exposure.BandFor(2, 100, bands))
+		assert.Equal(t, exposure.BandLow, exposure.BandFor(4, 1e6, bands))
+	})
+}
+
+func TestExposure_Recompute(t *testing.T) {
+	graph := newExposureGraph()
+	graph.sources["S"] = true
+	graph.link("S", "A", 20000)
+	graph.link("A", "B", 15000)
+	graph.link("B", "C", 0)
+	graph.link("C", "D", 0)
+	graph.entity("E")
+
+	publisher := &recordingExposurePublisher{}
+	service := exposure.NewService(graph, publisher, exposureConfig(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	result, err := service.Recompute(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, exposure.ModeFull, result.Mode)
+	assert.Equal(t, 1, result.Sources)
+	assert.Equal(t, 4, result.Updated, "S, A, B and C are within three hops")
+	assert.Equal(t, 4, result.BandChanges)
+
+	assert.Equal(t, "critical", graph.stored["A"]["band"])
+	assert.Equal(t, "high", graph.stored["B"]["band"])
+	assert.Equal(t, "medium", graph.stored["C"]["band"])
+	assert.NotContains(t, graph.stored, "D")
+	assert.NotContains(t, graph.stored, "E")
+
+	t.Run("incremental recompute around a change", func(t *testing.T) {
+		publisher.events = nil
+		graph.link("D", "E", 0)
+		graph.link("S", "D", 1000)
+
+		result, err := service.Recompute(ctx, []string{"S", "D"})
+		require.NoError(t, err)
+		assert.Equal(t, exposure.ModeIncremental, result.Mode)
+
+		assert.Equal(t, "critical", graph.stored["D"]["band"])
+		assert.Equal(t, 1, graph.stored["D"]["distance"])
+		assert.Equal(t, "medium", graph.stored["E"]["band"], "two hops from S over a link without transactions")
+		assert.Equal(t, "medium", graph.stored["C"]["band"])
+
+		require.Len(t, publisher.events, 2)
+		byEntity := map[string]*exposure.ChangeEvent{}
+		for _, event := range publisher.events {
+			assert.Equal(t, exposure.EventExposureChanged, event.Type)
+			assert.Equal(t, "graph-engine", event.Source)
+			byEntity[event.Data["entity_id"].(string)] = event
+		}
+		require.Contains(t, byEntity, "D")
+		assert.Equal(t, "none", byEntity["D"].Data["previous_band"])
+		assert.Equal(t, "critical", byEntity["D"].Data["band"])
+	})
+
+	t.Run("unflagging a source clears its exposure", func(t *testing.T) {
+		publisher.events = nil
+		require.NoError(t, service.SetSource(ctx, "S", "sanctioned", false))
+
+		result, err := service.Recompute(ctx, []string{"S"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Sources)
+		assert.Equal(t, 6, result.Cleared)
+		assert.Empty(t, graph.stored)
+		assert.Len(t, publisher.events, 6)
+		for _, event := range publisher.events {
+			assert.Equal(t, exposure.BandNone, event.Data["band"])
+			assert.Equal(t, false, event.Data["exposed"])
+		}
+	})
+
+	t.Run("source validation", func(t *testing.T) {
+		err := service.SetSource(ctx, "S", "rating", true)
+		assert.ErrorIs(t, err, exposure.ErrUnknownSourceProperty)
+
+		err = service.SetSource(ctx, "missing", "flagged", true)
+		assert.ErrorIs(t, err, exposure.ErrEntityNotFound)
+
+		_, err = service.List(ctx, exposure.Filter{Band: "severe"})
+		assert.ErrorIs(t, err, exposure.ErrUnknownBand)
+	})
+}
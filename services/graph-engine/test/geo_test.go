@@ -0,0 +1,73 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/geo"
+)
+
+func TestCrossBorderFlows_Unit(t *testing.T) {
+	policy := geo.NewRiskPolicy(config.GeoConfig{
+		HighRiskJurisdictions: []string{"kp", "IR"},
+		HighRiskCorridors:     []string{"AE->RU"},
+	})
+
+	t.Run("Risk Policy", func(t *testing.T) {
+		assert.True(t, policy.IsHighRiskJurisdiction("KP"))
+		assert.True(t, policy.IsHighRiskCorridor("ae", "ru"))
+		assert.True(t, policy.IsHighRiskCorridor("GB", "IR"), "Corridors touching a high-risk jurisdiction are high risk")
+		assert.False(t, policy.IsHighRiskCorridor("RU", "AE"), "Corridors are directional")
+		assert.False(t, policy.IsHighRiskCorridor("GB", "US"))
+	})
+
+	t.Run("Corridor Summary", func(t *testing.T) {
+		summary := geo.SummarizeCorridors([]*geo.CorridorFlow{
+			{Origin: "GB", Destination: "GB", TotalAmount: 500, TransactionCount: 5},
+			{Origin: "GB", Destination: "US", TotalAmount: 1000, TransactionCount: 4},
+			{Origin: "ae", Destination: "RU", TotalAmount: 3000, TransactionCount: 2},
+			{Origin: "AE", Destination: "ru", TotalAmount: 1000, TransactionCount: 2},
+		}, policy)
+
+		require.Len(t, summary.Corridors, 2)
+		assert.Equal(t, "AE->RU", summary.Corridors[0].Corridor)
+		assert.Equal(t, 4000.0, summary.Corridors[0].TotalAmount)
+		assert.Equal(t, 1000.0, summary.Corridors[0].AverageAmount)
+		assert.True(t, summary.Corridors[0].HighRisk)
+		assert.False(t, summary.Corridors[1].HighRisk)
+
+		assert.Equal(t, 5000.0, summary.CrossBorderAmount)
+		assert.Equal(t, 500.0, summary.DomesticAmount)
+		assert.Equal(t, 4000.0, summary.HighRiskAmount)
+		assert.Equal(t, 1, summary.HighRiskCorridorCount)
+		assert.InDelta(t, 0.8, summary.Corridors[0].Share, 0.0001)
+	})
+
+	t.Run("Normalize Request", func(t *testing.T) {
+		cfg := config.GeoConfig{DefaultNetworkDepth: 2, MaxNetworkDepth: 4, MaxRouteHops: 3, DefaultTimeWindow: 24 * time.Hour}
+
+		req := &geo.CrossBorderFlowRequest{Depth: 50, MaxHops: 20}
+		geo.NormalizeFlowRequest(req, cfg)
+		assert.Equal(t, 4, req.Depth)
+		assert.Equal(t, 3, req.MaxHops)
+		assert.Equal(t, 24*time.Hour, req.TimeWindow)
+
+		req = &geo.CrossBorderFlowRequest{}
+		geo.NormalizeFlowRequest(req, cfg)
+		assert.Equal(t, 2, req.Depth)
+		assert.Equal(t, 3, req.MaxHops)
+	})
+
+	t.Run("Route Evaluation", func(t *testing.T) {
+		flagged, reasons := geo.EvaluateRoute([]string{"GB", "AE", "RU"}, policy)
+		assert.True(t, flagged)
+		assert.Equal(t, []string{"crosses high-risk corridor AE->RU"}, reasons)
+
+		flagged, _ = geo.EvaluateRoute([]string{"GB", "US", "DE"}, policy)
+		assert.False(t, flagged)
+	})
+}
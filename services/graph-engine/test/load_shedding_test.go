@@ -0,0 +1,164 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/loadshed"
+)
+
+func routeClass(name string, limit, queue int, prefixes ...string) config.RouteClassConfig {
+	return config.RouteClassConfig{
+		Name:          name,
+		PathPrefixes:  prefixes,
+		MinLimit:      1,
+		MaxLimit:      limit,
+		MaxQueue:      queue,
+		QueueTimeout:  50 * time.Millisecond,
+		TargetLatency: time.Second,
+	}
+}
+
+func TestLimiterQueuesAndSheds(t *testing.T) {
+	ctx := context.Background()
+	limiter := loadshed.NewLimiter(routeClass("analysis", 2, 1))
+
+	require.NoError(t, limiter.Acquire(ctx))
+	require.NoError(t, limiter.Acquire(ctx))
+
+	// The third request queues and is admitted when a slot frees
+	admitted := make(chan error, 1)
+	go func() { admitted <- limiter.Acquire(ctx) }()
+	require.Eventually(t, func() bool { return limiter.Stats().Queued == 1 }, time.Second, time.Millisecond)
+
+	assert.ErrorIs(t, limiter.Acquire(ctx), loadshed.ErrQueueFull, "the queue holds one request")
+
+	stats := limiter.Stats()
+	assert.Equal(t, 2, stats.InFlight)
+	assert.Equal(t, 1.5, stats.Saturation)
+
+	limiter.Release(10 * time.Millisecond)
+	require.NoError(t, <-admitted)
+	assert.Equal(t, 2, limiter.Stats().InFlight)
+	assert.Zero(t, limiter.Stats().Queued)
+
+	t.Run("queued requests time out", func(t *testing.T) {
+		start := time.Now()
+		assert.ErrorIs(t, limiter.Acquire(ctx), loadshed.ErrQueueTimeout)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+		assert.Zero(t, limiter.Stats().Queued)
+	})
+
+	t.Run("queued requests give up with their context", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.ErrorIs(t, limiter.Acquire(canceled), context.Canceled)
+	})
+}
+
+func TestLimiterAdapts(t *testing.T) {
+	ctx := context.Background()
+	cfg := routeClass("lookup", 10, 0)
+	cfg.MinLimit = 5
+	limiter := loadshed.NewLimiter(cfg)
+
+	require.NoError(t, limiter.Acquire(ctx))
+	limiter.Release(2 * time.Second)
+	assert.Equal(t, 9, limiter.Stats().Limit, "slow requests back the limit off")
+
+	require.NoError(t, limiter.Acquire(ctx))
+	limiter.Release(2 * time.Second)
+	assert.Equal(t, 9, limiter.Stats().Limit, "backs off once per target latency")
+
+	// Fast requests while saturated grow the limit back, by one per limit's
+	// worth of requests
+	for i := 0; i < 9; i++ {
+		require.NoError(t, limiter.Acquire(ctx))
+	}
+	for i := 0; i < 12; i++ {
+		limiter.Release(10 * time.Millisecond)
+		require.NoError(t, limiter.Acquire(ctx))
+	}
+	assert.Equal(t, 10, limiter.Stats().Limit, "never above max_limit")
+	for i := 0; i < 9; i++ {
+		limiter.Release(10 * time.Millisecond)
+	}
+
+	// Fast requests below the limit leave it alone
+	require.NoError(t, limiter.Acquire(ctx))
+	limiter.Release(10 * time.Millisecond)
+	assert.Equal(t, 10, limiter.Stats().Limit)
+	assert.Zero(t, limiter.Stats().InFlight)
+}
+
+func TestLoadSheddingMiddleware(t *testing.T) {
+	shedder := loadshed.NewShedder(config.LoadSheddingConfig{
+		Enabled:    true,
+		RetryAfter: 1500 * time.Millisecond,
+		Classes: []config.RouteClassConfig{
+			routeClass("analysis", 1, 0, "/api/v1/analysis", "/api/v1/patterns/detect"),
+			routeClass("lookup", 4, 0, "/api/v1/patterns"),
+		},
+		Default: routeClass("default", 4, 0),
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	handler := shedder.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("block") != "" {
+			started.Done()
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Occupy the only analysis slot
+	started.Add(1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/analysis/paths?block=1", nil))
+	}()
+	started.Wait()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/patterns/detect", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "detection shares the analysis class")
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, "analysis", body["class"])
+	assert.Equal(t, "queue_full", body["reason"])
+
+	for _, path := range []string{"/api/v1/patterns/p-1", "/api/v1/entities/e-1/neighborhood", "/health", "/metrics"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, "%s is not in the saturated class", path)
+	}
+
+	close(release)
+	<-done
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/analysis/subgraph", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	stats := shedder.Stats()
+	require.Len(t, stats, 3)
+	assert.Equal(t, "default", stats[0].Class)
+	for _, class := range stats {
+		assert.Zero(t, class.InFlight, class.Class)
+	}
+}
@@ -0,0 +1,254 @@
+package test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/graph-engine/internal/backup"
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/maintenance"
+	"github.com/aegisshield/graph-engine/internal/tenancy"
+)
+
+// maintenanceGraph answers the maintenance queries from in-memory counts of
+// orphans, duplicates and expired relationships, removing them as they are
+// deleted
+type maintenanceGraph struct {
+	orphans    int
+	duplicates int
+	expired    []map[string]interface{}
+	deletes    int
+	params     []map[string]interface{}
+	tenants    []string
+}
+
+func (g *maintenanceGraph) ExecuteQuery(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	g.params = append(g.params, params)
+	tenantID, _ := tenancy.FromContext(ctx)
+	g.tenants = append(g.tenants, tenantID)
+
+	count := func(relType string, n int) []map[string]interface{} {
+		if n == 0 {
+			return nil
+		}
+		return []map[string]interface{}{{"type": relType, "count": int64(n)}}
+	}
+
+	switch {
+	case strings.Contains(query, "NOT (n)--()") && strings.Contains(query, "DELETE n"):
+		g.deletes++
+		n := min(g.orphans, params["limit"].(int))
+		g.orphans -= n
+		return count("Entity", n), nil
+	case strings.Contains(query, "NOT (n)--()"):
+		return count("Entity", g.orphans), nil
+	case strings.Contains(query, "UNWIND extra"):
+		g.deletes++
+		n := min(g.duplicates, params["limit"].(int))
+		g.duplicates -= n
+		return count("OWNS", n), nil
+	case strings.Contains(query, "size(rels) > 1"):
+		return count("OWNS", g.duplicates), nil
+	case strings.Contains(query, "elementId(r) IN $ids"):
+		g.deletes++
+		ids := params["ids"].([]string)
+		remaining := g.expired[:0]
+		for _, rel := range g.expired {
+			if !hasValue(ids, rel["element_id"].(string)) {
+				remaining = append(remaining, rel)
+			}
+		}
+		deleted := len(g.expired) - len(remaining)
+		g.expired = remaining
+		return []map[string]interface{}{{"count": int64(deleted)}}, nil
+	case strings.Contains(query, "AS element_id"):
+		n := min(len(g.expired), params["limit"].(int))
+		return g.expired[:n], nil
+	case strings.Contains(query, "UNWIND $rules"):
+		return count("TRANSACTION", len(g.expired)), nil
+	}
+	return nil, nil
+}
+
+func hasValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func expiredRelationship(key string) map[string]interface{} {
+	return map[string]interface{}{
+		"element_id": "element-" + key,
+		"key":        key,
+		"type":       "TRANSACTION",
+		"source":     "acct-1",
+		"target":     "acct-2",
+		"properties": map[string]interface{}{"id": key, "amount": 900.0, "created_at": "2020-01-01T00:00:00Z"},
+	}
+}
+
+type tenantLister []*tenancy.Database
+
+func (l tenantLister) TenantDatabases(ctx context.Context) ([]*tenancy.Database, error) {
+	return l, nil
+}
+
+func maintenanceConfig() config.MaintenanceConfig {
+	return config.MaintenanceConfig{
+		Interval:      time.Hour,
+		DryRun:        true,
+		BatchSize:     2,
+		MaxBatches:    10,
+		OrphanLabels:  []string{"Entity"},
+		OrphanMinAge:  30 * 24 * time.Hour,
+		Retention:     map[string]time.Duration{"TRANSACTION": 365 * 24 * time.Hour},
+		ArchivePrefix: "archives",
+		HistorySize:   2,
+	}
+}
+
+func newMaintenanceService(t *testing.T, graph maintenance.QueryRunner) (*maintenance.Service, backup.Store) {
+	store := backup.NewFileStore(t.TempDir())
+	return maintenance.NewService(graph, store, maintenanceConfig(), slog.New(slog.NewTextHandler(io.Discard, nil))), store
+}
+
+func TestMaintenance_Unit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Dry Run Reports Without Changing", func(t *testing.T) {
+		graph := &maintenanceGraph{
+			orphans:    3,
+			duplicates: 5,
+			expired:    []map[string]interface{}{expiredRelationship("tx-1")},
+		}
+		service, _ := newMaintenanceService(t, graph)
+
+		run, err := service.Run(ctx, maintenance.RunOptions{DryRun: true})
+		require.NoError(t, err)
+
+		assert.True(t, run.DryRun)
+		assert.Equal(t, maintenance.TriggerManual, run.Trigger)
+		assert.False(t, run.Failed)
+		assert.Equal(t, 3, run.Matched[maintenance.JobOrphans])
+		assert.Equal(t, 5, run.Matched[maintenance.JobDuplicates])
+		assert.Equal(t, 1, run.Matched[maintenance.JobRetention])
+		for _, job := range maintenance.Jobs {
+			assert.Zero(t, run.Changed[job])
+		}
+		assert.Zero(t, graph.deletes)
+		assert.Equal(t, 3, graph.orphans)
+
+		require.Len(t, run.Results, 3)
+		assert.Equal(t, maintenance.JobRetention, run.Results[0].Job)
+		assert.Equal(t, map[string]int{"TRANSACTION": 1}, run.Results[0].ByType)
+	})
+
+	t.Run("Run Deletes In Batches", func(t *testing.T) {
+		graph := &maintenanceGraph{orphans: 3, duplicates: 5}
+		service, _ := newMaintenanceService(t, graph)
+
+		run, err := service.Run(ctx, maintenance.RunOptions{
+			Jobs: []string{maintenance.JobOrphans, maintenance.JobDuplicates},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, run.Changed[maintenance.JobOrphans])
+		assert.Equal(t, 5, run.Changed[maintenance.JobDuplicates])
+		assert.Zero(t, graph.orphans)
+		assert.Zero(t, graph.duplicates)
+		require.Len(t, run.Results, 2)
+		assert.Equal(t, maintenance.JobDuplicates, run.Results[0].Job)
+	})
+
+	t.Run("Retention Archives Before Deleting", func(t *testing.T) {
+		graph := &maintenanceGraph{expired: []map[string]interface{}{
+			expiredRelationship("tx-1"), expiredRelationship("tx-2"), expiredRelationship("tx-3"),
+		}}
+		service, store := newMaintenanceService(t, graph)
+
+		run, err := service.Run(ctx, maintenance.RunOptions{Jobs: []string{maintenance.JobRetention}})
+		require.NoError(t, err)
+
+		result := run.Results[0]
+		assert.Equal(t, 3, result.Changed)
+		assert.Empty(t, graph.expired)
+		require.Len(t, result.Archives, 2)
+
+		var archived []*backup.Record
+		for _, key := range result.Archives {
+			assert.True(t, strings.HasPrefix(key, "archives/"+run.ID+"/shared-"))
+			body, err := store.Get(ctx, key)
+			require.NoError(t, err)
+			_, _, err = backup.ReadData(body, func(record *backup.Record) error {
+				archived = append(archived, record)
+				return nil
+			})
+			body.Close()
+			require.NoError(t, err)
+		}
+		require.Len(t, archived, 3)
+		assert.Equal(t, "tx-1", archived[0].Key)
+		assert.Equal(t, backup.RecordRelationship, archived[0].Kind)
+		assert.Equal(t, "acct-1", archived[0].Source)
+
+		rules := graph.params[0]["rules"].([]map[string]interface{})
+		require.Len(t, rules, 1)
+		assert.Equal(t, "transaction", rules[0]["type"])
+	})
+
+	t.Run("Tenant Databases Are Maintained", func(t *testing.T) {
+		graph := &maintenanceGraph{orphans: 1}
+		service, _ := newMaintenanceService(t, graph)
+		service.SetTenants(tenantLister{
+			{TenantID: "acme", Online: true},
+			{TenantID: "globex", Online: false},
+		})
+
+		run, err := service.Run(ctx, maintenance.RunOptions{DryRun: true, Jobs: []string{maintenance.JobOrphans}})
+		require.NoError(t, err)
+		require.Len(t, run.Results, 2)
+		assert.Empty(t, run.Results[0].TenantID)
+		assert.Equal(t, "acme", run.Results[1].TenantID)
+
+		// A run for one tenant maintains only that tenant's database
+		graph.tenants = nil
+		run, err = service.Run(tenancy.WithTenant(ctx, "acme"), maintenance.RunOptions{DryRun: true, Jobs: []string{maintenance.JobOrphans}})
+		require.NoError(t, err)
+		require.Len(t, run.Results, 1)
+		assert.Equal(t, []string{"acme"}, graph.tenants)
+	})
+
+	t.Run("History And Unknown Jobs", func(t *testing.T) {
+		service, _ := newMaintenanceService(t, &maintenanceGraph{})
+
+		_, err := service.Run(ctx, maintenance.RunOptions{Jobs: []string{"vacuum"}})
+		assert.ErrorIs(t, err, maintenance.ErrUnknownJob)
+
+		var ids []string
+		for i := 0; i < 3; i++ {
+			run, err := service.Run(ctx, maintenance.RunOptions{DryRun: true})
+			require.NoError(t, err)
+			ids = append(ids, run.ID)
+		}
+
+		runs := service.Runs()
+		require.Len(t, runs, 2)
+		assert.Equal(t, ids[2], runs[0].ID)
+
+		_, err = service.GetRun(ids[0])
+		assert.ErrorIs(t, err, maintenance.ErrRunNotFound)
+		found, err := service.GetRun(ids[1])
+		require.NoError(t, err)
+		assert.Equal(t, ids[1], found.ID)
+	})
+}
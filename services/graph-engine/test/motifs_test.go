@@ -0,0 +1,401 @@
+package test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/patterns"
+)
+
+var motifEpoch = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func motifConfig() config.MotifConfig {
+	return config.MotifConfig{
+		Enabled:              true,
+		Interval:             time.Hour,
+		TransactionType:      "TRANSACTION",
+		AmountProperty:       "amount",
+		OwnershipTypes:       []string{"OWNS"},
+		ShellProperties:      []string{"shell_company"},
+		TimeWindow:           30 * 24 * time.Hour,
+		MaxTransactions:      1000,
+		MinConfidence:        0.5,
+		MaxInstances:         100,
+		PublishMinRisk:       70,
+		MaxCycleLength:       5,
+		CycleWindow:          7 * 24 * time.Hour,
+		FanMinCounterparties: 5,
+		FanWindow:            48 * time.Hour,
+		StructuringThreshold: 10000,
+		StructuringMargin:    0.1,
+		PassThroughWindow:    24 * time.Hour,
+		PassThroughMinRatio:  0.8,
+		PassThroughMinAmount: 5000,
+		LayeringMinHops:      3,
+		LayeringMaxHops:      6,
+		LayeringMaxShave:     0.1,
+		LayeringMaxGap:       24 * time.Hour,
+		ShellMinDepth:        2,
+		ShellMaxDepth:        4,
+	}
+}
+
+// tx is a transaction made hours after the motif epoch
+func tx(id, from, to string, amount float64, hours float64) patterns.MotifTransaction {
+	return patterns.MotifTransaction{
+		ID:     id,
+		From:   from,
+		To:     to,
+		Amount: amount,
+		At:     motifEpoch.Add(time.Duration(hours * float64(time.Hour))),
+	}
+}
+
+func detectMotif(t *testing.T, graph *patterns.MotifGraph, motif patterns.PatternType) []*patterns.Pattern {
+	t.Helper()
+	found, err := patterns.DetectMotif(graph, motif, motifConfig())
+	require.NoError(t, err)
+	return found
+}
+
+func patternEntityIDs(pattern *patterns.Pattern) []string {
+	ids := make([]string, len(pattern.Entities))
+	for i, entity := range pattern.Entities {
+		ids[i] = entity.ID
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestMotifs_Detect(t *testing.T) {
+	t.Run("round tripping", func(t *testing.T) {
+		graph := &patterns.MotifGraph{Transactions: []patterns.MotifTransaction{
+			tx("t1", "A", "B", 10000, 0),
+			tx("t2", "B", "C", 9800, 2),
+			tx("t3", "C", "A", 9600, 5),
+			// Money going back before it left is no round trip
+			tx("t4", "D", "E", 5000, 10),
+			tx("t5", "E", "F", 5000, 9),
+			tx("t6", "F", "D", 5000, 11),
+		}}
+
+		found := detectMotif(t, graph, patterns.PatternTypeRoundTripping)
+		require.Len(t, found, 1)
+		assert.Equal(t, []string{"A", "B", "C"}, patternEntityIDs(found[0]))
+		assert.Len(t, found[0].Relationships, 3)
+		assert.Equal(t, 3, found[0].Metadata["cycle_length"])
+		assert.Greater(t, found[0].Confidence, 0.8)
+		assert.Greater(t, found[0].RiskScore, found[0].Confidence*100, "round trips weigh more than their confidence")
+
+		again := detectMotif(t, graph, patterns.PatternTypeRoundTripping)
+		assert.Equal(t, found[0].ID, again[0].ID, "the same cycle keeps its ID")
+	})
+
+	t.Run("cycles close within the cycle window and length", func(t *testing.T) {
+		graph := &patterns.MotifGraph{Transactions: []patterns.MotifTransaction{
+			tx("t1", "A", "B", 10000, 0),
+			tx("t2", "B", "A", 10000, 8*24),
+			tx("t3", "P", "Q", 1000, 0),
+			tx("t4", "Q", "R", 1000, 1),
+			tx("t5", "R", "S", 1000, 2),
+			tx("t6", "S", "T", 1000, 3),
+			tx("t7", "T", "U", 1000, 4),
+			tx("t8", "U", "P", 1000, 5),
+		}}
+
+		assert.Empty(t, detectMotif(t, graph, patterns.PatternTypeRoundTripping))
+	})
+
+	t.Run("fan-in just below the threshold", func(t *testing.T) {
+		var transactions []patterns.MotifTransaction
+		for i, sender := range []string{"S1", "S2", "S3", "S4", "S5", "S6"} {
+			transactions = append(transactions, tx("in"+sender, sender, "HUB", 9500, float64(i*4)))
+		}
+		// Spread over too long to count in the same window
+		transactions = append(transactions,
+			tx("slow1", "L1", "SLOW", 9500, 0),
+			tx("slow2", "L2", "SLOW", 9500, 60),
+			tx("slow3", "L3", "SLOW", 9500, 120),
+			tx("slow4", "L4", "SLOW", 9500, 180),
+			tx("slow5", "L5", "SLOW", 9500, 240),
+		)
+		graph := &patterns.MotifGraph{Transactions: transactions}
+
+		found := detectMotif(t, graph, patterns.PatternTypeFanIn)
+		require.Len(t, found, 1)
+		assert.Equal(t, "HUB", found[0].Metadata["hub"])
+		assert.Equal(t, 6, found[0].Metadata["counterparties"])
+		assert.Equal(t, 6, found[0].Metadata["just_below_threshold"])
+		assert.Equal(t, 57000.0, found[0].Metadata["total_amount"])
+		assert.Greater(t, found[0].Confidence, 0.8)
+
+		assert.Empty(t, detectMotif(t, graph, patterns.PatternTypeFanOut))
+	})
+
+	t.Run("fan-out", func(t *testing.T) {
+		var transactions []patterns.MotifTransaction
+		for i, receiver := range []string{"R1", "R2", "R3", "R4", "R5"} {
+			transactions = append(transactions, tx("out"+receiver, "HUB", receiver, 2500, float64(i)))
+		}
+		graph := &patterns.MotifGraph{Transactions: transactions}
+
+		found := detectMotif(t, graph, patterns.PatternTypeFanOut)
+		require.Len(t, found, 1)
+		assert.Equal(t, []string{"HUB", "R1", "R2", "R3", "R4", "R5"}, patternEntityIDs(found[0]))
+		assert.Equal(t, 0, found[0].Metadata["just_below_threshold"])
+		assert.Less(t, found[0].Confidence, 0.8, "amounts far below the threshold are not structuring")
+	})
+
+	t.Run("pass-through", func(t *testing.T) {
+		graph := &patterns.MotifGraph{Transactions: []patterns.MotifTransaction{
+			tx("in1", "X", "MULE", 30000, 0),
+			tx("in2", "Y", "MULE", 20000, 1),
+			tx("out1", "MULE", "Z", 48000, 3),
+			// Keeps most of what it receives
+			tx("in3", "X", "SAVER", 30000, 0),
+			tx("out2", "SAVER", "Z", 5000, 2),
+			// Passes everything on, but too late
+			tx("in4", "X", "SLOW", 30000, 0),
+			tx("out3", "SLOW", "Z", 30000, 30),
+		}}
+
+		found := detectMotif(t, graph, patterns.PatternTypePassThrough)
+		require.Len(t, found, 1)
+		assert.Equal(t, "MULE", found[0].Metadata["account"])
+		assert.Equal(t, 50000.0, found[0].Metadata["inflow"])
+		assert.Equal(t, 48000.0, found[0].Metadata["outflow"])
+		assert.Equal(t, []string{"MULE", "X", "Y", "Z"}, patternEntityIDs(found[0]))
+	})
+
+	t.Run("layering chain with decreasing amounts", func(t *testing.T) {
+		graph := &patterns.MotifGraph{Transactions: []patterns.MotifTransaction{
+			tx("h1", "L1", "L2", 20000, 0),
+			tx("h2", "L2", "L3", 19000, 2),
+			tx("h3", "L3", "L4", 18200, 5),
+			tx("h4", "L4", "L5", 17500, 9),
+			// Amounts growing along the way are no layering
+			tx("g1", "G1", "G2", 1000, 0),
+			tx("g2", "G2", "G3", 5000, 1),
+			tx("g3", "G3", "G4", 9000, 2),
+		}}
+
+		found := detectMotif(t, graph, patterns.PatternTypeLayeringChain)
+		require.Len(t, found, 1, "sub-chains are not reported on their own")
+		assert.Equal(t, 4, found[0].Metadata["hops"])
+		assert.Equal(t, 20000.0, found[0].Metadata["first_amount"])
+		assert.Equal(t, 17500.0, found[0].Metadata["last_amount"])
+		assert.Equal(t, []string{"L1", "L2", "L3", "L4", "L5"}, patternEntityIDs(found[0]))
+	})
+
+	t.Run("nested shell ownership", func(t *testing.T) {
+		graph := &patterns.MotifGraph{
+			Transactions: []patterns.MotifTransaction{tx("m1", "TARGET", "P", 40000, 0)},
+			Ownerships: []patterns.MotifOwnership{
+				{ID: "o1", Type: "OWNS", Owner: "P", Owned: "S1"},
+				{ID: "o2", Type: "OWNS", Owner: "S1", Owned: "S2"},
+				{ID: "o3", Type: "OWNS", Owner: "S2", Owned: "TARGET"},
+				// A single shell layer is below the minimum depth
+				{ID: "o4", Type: "OWNS", Owner: "Q", Owned: "S3"},
+				{ID: "o5", Type: "OWNS", Owner: "S3", Owned: "OPCO"},
+			},
+			Shells: map[string]bool{"S1": true, "S2": true, "S3": true},
+		}
+
+		found := detectMotif(t, graph, patterns.PatternTypeNestedShell)
+		require.Len(t, found, 1)
+		assert.Equal(t, "P", found[0].Metadata["owner"])
+		assert.Equal(t, "TARGET", found[0].Metadata["owned"])
+		assert.Equal(t, 2, found[0].Metadata["shell_depth"])
+		assert.Len(t, found[0].Relationships, 4, "three ownerships and the transaction along the chain")
+		assert.Contains(t, found[0].Indicators, "1 transactions between entities of the chain")
+	})
+
+	t.Run("unknown motif", func(t *testing.T) {
+		_, err := patterns.DetectMotif(&patterns.MotifGraph{}, patterns.PatternTypeSmurfing, motifConfig())
+		assert.ErrorIs(t, err, patterns.ErrUnknownMotif)
+	})
+}
+
+// motifGraph answers the motif library's queries from fixed transactions,
+// ownerships and entities
+type motifGraph struct {
+	transactions []patterns.MotifTransaction
+	ownerships   []patterns.MotifOwnership
+	shells       map[string]bool
+	types        map[string]string
+
+	mu      sync.Mutex
+	queries []string
+}
+
+func (g *motifGraph) ExecuteQuery(_ context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	g.mu.Lock()
+	g.queries = append(g.queries, query)
+	g.mu.Unlock()
+
+	var rows []map[string]interface{}
+	switch {
+	case strings.Contains(query, "AS at"):
+		for _, t := range g.transactions {
+			rows = append(rows, map[string]interface{}{
+				"id": t.ID, "from": t.From, "to": t.To, "amount": t.Amount, "at": t.At.UnixMilli(),
+			})
+		}
+	case strings.Contains(query, "AS owned_shell"):
+		for _, o := range g.ownerships {
+			rows = append(rows, map[string]interface{}{
+				"id": o.ID, "type": o.Type, "owner": o.Owner, "owned": o.Owned,
+				"owner_shell": g.shells[o.Owner], "owned_shell": g.shells[o.Owned],
+			})
+		}
+	case strings.Contains(query, "AS properties"):
+		for _, id := range params["ids"].([]string) {
+			rows = append(rows, map[string]interface{}{
+				"id": id, "type": g.types[id], "properties": map[string]interface{}{"id": id, "name": "Entity " + id},
+			})
+		}
+	}
+	return rows, nil
+}
+
+type recordingMotifPublisher struct {
+	mu       sync.Mutex
+	patterns []*patterns.Pattern
+}
+
+func (p *recordingMotifPublisher) PublishMotifDetected(_ context.Context, pattern *patterns.Pattern) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.patterns = append(p.patterns, pattern)
+	return nil
+}
+
+func (p *recordingMotifPublisher) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.patterns)
+}
+
+func newMotifFixture() *motifGraph {
+	graph := &motifGraph{
+		shells: map[string]bool{"S1": true, "S2": true},
+		types:  map[string]string{"MULE": "Account", "S1": "Company", "S2": "Company"},
+		ownerships: []patterns.MotifOwnership{
+			{ID: "o1", Type: "OWNS", Owner: "P", Owned: "S1"},
+			{ID: "o2", Type: "OWNS", Owner: "S1", Owned: "S2"},
+			{ID: "o3", Type: "OWNS", Owner: "S2", Owned: "TARGET"},
+		},
+	}
+	for i, sender := range []string{"F1", "F2", "F3", "F4", "F5"} {
+		graph.transactions = append(graph.transactions, tx("in"+sender, sender, "MULE", 9600, float64(i)))
+	}
+	graph.transactions = append(graph.transactions, tx("out", "MULE", "OFFSHORE", 47000, 6))
+	return graph
+}
+
+func TestMotifs_Run(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("every detector on demand", func(t *testing.T) {
+		graph := newMotifFixture()
+		library := patterns.NewMotifLibrary(graph, nil, motifConfig(), logger)
+
+		result, err := library.Run(context.Background(), &patterns.DetectionRequest{InvestigationID: "inv-1"})
+		require.NoError(t, err)
+
+		assert.Equal(t, 6, result.TransactionsAnalyzed)
+		assert.Equal(t, 3, result.OwnershipsAnalyzed)
+		assert.Equal(t, 11, result.EntitiesAnalyzed)
+		assert.False(t, result.Truncated)
+		assert.Equal(t, 1, result.ByType[patterns.PatternTypeFanIn])
+		assert.Equal(t, 1, result.ByType[patterns.PatternTypePassThrough])
+		assert.Equal(t, 1, result.ByType[patterns.PatternTypeNestedShell])
+		assert.Equal(t, 0, result.ByType[patterns.PatternTypeRoundTripping])
+
+		for _, pattern := range result.Patterns {
+			assert.Equal(t, "inv-1", pattern.InvestigationID)
+			for _, entity := range pattern.Entities {
+				assert.Equal(t, "Entity "+entity.ID, entity.Properties["name"], "entities are described")
+			}
+		}
+		for _, pattern := range result.Patterns {
+			if pattern.Type == patterns.PatternTypePassThrough {
+				assert.Equal(t, "Account", pattern.Entities[0].Type)
+			}
+		}
+	})
+
+	t.Run("requests narrow the search", func(t *testing.T) {
+		graph := newMotifFixture()
+		library := patterns.NewMotifLibrary(graph, nil, motifConfig(), logger)
+
+		result, err := library.Run(context.Background(), &patterns.DetectionRequest{
+			Types:      []patterns.PatternType{patterns.PatternTypeFanIn},
+			EntityIDs:  []string{"MULE"},
+			Parameters: map[string]interface{}{"min_counterparties": float64(6)},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, result.Patterns, "five senders are fewer than the requested six")
+
+		graph.mu.Lock()
+		assert.Contains(t, graph.queries[0], "WHERE s.id IN $entityIds")
+		graph.mu.Unlock()
+
+		result, err = library.Run(context.Background(), &patterns.DetectionRequest{
+			Types:         []patterns.PatternType{patterns.PatternTypeFanIn},
+			MinConfidence: 0.99,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, result.Patterns)
+
+		_, err = library.Run(context.Background(), &patterns.DetectionRequest{Types: []patterns.PatternType{patterns.PatternTypeKitingScheme}})
+		assert.ErrorIs(t, err, patterns.ErrUnknownMotif)
+	})
+
+	t.Run("pattern detector serves motifs", func(t *testing.T) {
+		detector := patterns.NewPatternDetector(nil, config.GraphEngineConfig{}, logger)
+		detector.SetMotifs(patterns.NewMotifLibrary(newMotifFixture(), nil, motifConfig(), logger))
+
+		result, err := detector.DetectPatterns(context.Background(), &patterns.DetectionRequest{
+			Types: []patterns.PatternType{patterns.PatternTypeNestedShell, patterns.PatternTypePassThrough},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.PatternsFound)
+	})
+
+	t.Run("scheduled runs publish high risk instances once", func(t *testing.T) {
+		cfg := motifConfig()
+		cfg.Interval = 10 * time.Millisecond
+		cfg.PublishMinRisk = 80
+		publisher := &recordingMotifPublisher{}
+		library := patterns.NewMotifLibrary(newMotifFixture(), publisher, cfg, logger)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			library.Start(ctx)
+			close(done)
+		}()
+
+		require.Eventually(t, func() bool { return publisher.count() > 0 }, time.Second, 5*time.Millisecond)
+		published := publisher.count()
+		time.Sleep(5 * cfg.Interval)
+		cancel()
+		<-done
+
+		assert.Equal(t, published, publisher.count(), "instances are not republished")
+		for _, pattern := range publisher.patterns {
+			assert.GreaterOrEqual(t, pattern.RiskScore, cfg.PublishMinRisk)
+		}
+	})
+}
@@ -0,0 +1,450 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/database"
+	"github.com/aegisshield/graph-engine/internal/neo4j"
+	"github.com/aegisshield/graph-engine/internal/querytemplates"
+)
+
+// fakeTemplateStore keeps templates and executions in memory
+type fakeTemplateStore struct {
+	mu         sync.Mutex
+	templates  map[string]*database.QueryTemplate
+	executions []*database.QueryExecution
+}
+
+func newFakeTemplateStore() *fakeTemplateStore {
+	return &fakeTemplateStore{templates: make(map[string]*database.QueryTemplate)}
+}
+
+func (s *fakeTemplateStore) CreateQueryTemplate(ctx context.Context, template *database.QueryTemplate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *template
+	s.templates[template.ID] = &copied
+	return nil
+}
+
+func (s *fakeTemplateStore) UpdateQueryTemplate(ctx context.Context, template *database.QueryTemplate, expectedVersion int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.templates[template.ID]
+	if !ok || current.Version != expectedVersion {
+		return database.ErrQueryTemplateConflict
+	}
+	copied := *template
+	s.templates[template.ID] = &copied
+	return nil
+}
+
+func (s *fakeTemplateStore) GetQueryTemplate(ctx context.Context, id string) (*database.QueryTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	template, ok := s.templates[id]
+	if !ok {
+		return nil, database.ErrQueryTemplateNotFound
+	}
+	copied := *template
+	return &copied, nil
+}
+
+func (s *fakeTemplateStore) ListQueryTemplates(ctx context.Context, filter database.QueryTemplateFilter) ([]*database.QueryTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	roles := make(map[string]bool)
+	for _, role := range filter.Roles {
+		roles[role] = true
+	}
+
+	var templates []*database.QueryTemplate
+	for _, template := range s.templates {
+		visible := filter.All || template.OwnerID == filter.UserID || template.Visibility == "public"
+		for _, role := range template.SharedRoles {
+			visible = visible || (template.Visibility == "roles" && roles[role])
+		}
+		tagged := filter.Tag == ""
+		for _, tag := range template.Tags {
+			tagged = tagged || tag == filter.Tag
+		}
+		if visible && tagged {
+			templates = append(templates, template)
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+func (s *fakeTemplateStore) DeleteQueryTemplate(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.templates[id]; !ok {
+		return database.ErrQueryTemplateNotFound
+	}
+	delete(s.templates, id)
+	return nil
+}
+
+func (s *fakeTemplateStore) RecordQueryExecution(ctx context.Context, execution *database.QueryExecution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.executions = append(s.executions, execution)
+	return nil
+}
+
+func (s *fakeTemplateStore) ListQueryExecutions(ctx context.Context, filter database.QueryExecutionFilter) ([]*database.QueryExecution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var executions []*database.QueryExecution
+	for i := len(s.executions) - 1; i >= 0 && len(executions) < filter.Limit; i-- {
+		execution := s.executions[i]
+		if (filter.TemplateID == "" || execution.TemplateID == filter.TemplateID) &&
+			(filter.UserID == "" || execution.UserID == filter.UserID) &&
+			(filter.InvestigationID == "" || execution.InvestigationID == filter.InvestigationID) {
+			executions = append(executions, execution)
+		}
+	}
+	return executions, nil
+}
+
+// fakeReadGraph returns a fixed number of rows and remembers the last query
+type fakeReadGraph struct {
+	rows    int
+	err     error
+	delay   time.Duration
+	query   string
+	params  map[string]interface{}
+	maxRows int
+}
+
+func (g *fakeReadGraph) ExecuteReadQuery(ctx context.Context, query string, params map[string]interface{}, maxRows int) (*neo4j.QueryResult, error) {
+	g.query, g.params, g.maxRows = query, params, maxRows
+
+	if g.delay > 0 {
+		select {
+		case <-time.After(g.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if g.err != nil {
+		return nil, g.err
+	}
+
+	result := &neo4j.QueryResult{Columns: []string{"account"}, Rows: []map[string]interface{}{}}
+	for i := 0; i < g.rows && i < maxRows; i++ {
+		result.Rows = append(result.Rows, map[string]interface{}{"account": i})
+	}
+	result.Truncated = g.rows > maxRows
+	return result, nil
+}
+
+func queryTemplateConfig() config.QueryTemplateConfig {
+	return config.QueryTemplateConfig{
+		Enabled:          true,
+		UserHeader:       "X-User-ID",
+		RolesHeader:      "X-User-Roles",
+		AdminRoles:       []string{"admin"},
+		MaxQueryLength:   2000,
+		MaxParameters:    5,
+		DefaultRows:      10,
+		MaxRows:          50,
+		ExecutionTimeout: time.Second,
+		MaxHistoryLimit:  100,
+	}
+}
+
+func newQueryTemplateService(graph *fakeReadGraph) (*querytemplates.Service, *fakeTemplateStore) {
+	store := newFakeTemplateStore()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return querytemplates.NewService(store, graph, queryTemplateConfig(), logger), store
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+// sharedPhoneTemplate finds accounts sharing a phone number with an entity
+func sharedPhoneTemplate() querytemplates.TemplateInput {
+	return querytemplates.TemplateInput{
+		Name:  "Accounts sharing a phone",
+		Query: "MATCH (e:Entity {id: $entity_id})-[:HAS_PHONE]->(p)<-[:HAS_PHONE]-(a:Entity) WHERE a.risk_score >= $min_risk RETURN a AS account LIMIT $max_results",
+		Parameters: []*database.QueryTemplateParameter{
+			{Name: "entity_id", Type: querytemplates.TypeEntityID, Required: true},
+			{Name: "min_risk", Type: querytemplates.TypeFloat, Default: 0.0, Min: floatPtr(0), Max: floatPtr(100)},
+			{Name: "max_results", Type: querytemplates.TypeInteger, Default: 25.0, Min: floatPtr(1), Max: floatPtr(100)},
+		},
+		Tags:        []string{"phone", " linking ", "phone"},
+		Visibility:  querytemplates.VisibilityRoles,
+		SharedRoles: []string{"analyst"},
+	}
+}
+
+var (
+	templateOwner   = querytemplates.Caller{UserID: "alice", Roles: []string{"analyst"}}
+	templateAnalyst = querytemplates.Caller{UserID: "bob", Roles: []string{"analyst"}}
+	templateAuditor = querytemplates.Caller{UserID: "carol", Roles: []string{"auditor"}}
+	templateAdmin   = querytemplates.Caller{UserID: "dave", Roles: []string{"admin"}}
+)
+
+func validationFields(t *testing.T, err error, wrapped error) map[string]string {
+	t.Helper()
+	var validationErr *querytemplates.ValidationError
+	require.True(t, errors.As(err, &validationErr), "expected a validation error, got %v", err)
+	assert.ErrorIs(t, err, wrapped)
+	return validationErr.Fields
+}
+
+func TestQueryTemplates_Validation(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newQueryTemplateService(&fakeReadGraph{})
+
+	t.Run("valid template is saved with normalized tags", func(t *testing.T) {
+		template, err := service.Create(ctx, templateOwner, sharedPhoneTemplate())
+		require.NoError(t, err)
+		assert.Equal(t, "alice", template.OwnerID)
+		assert.Equal(t, 1, template.Version)
+		assert.Equal(t, []string{"phone", "linking"}, template.Tags)
+	})
+
+	t.Run("write clauses are rejected", func(t *testing.T) {
+		for _, query := range []string{
+			"MATCH (e:Entity {id: $entity_id}) DETACH DELETE e",
+			"MATCH (e:Entity {id: $entity_id}) SET e.flagged = true RETURN e",
+			"MERGE (e:Entity {id: $entity_id}) RETURN e",
+			"CALL apoc.periodic.iterate('MATCH (e) RETURN e', 'DELETE e', {}) YIELD batches WITH $entity_id AS id RETURN id",
+			"USE other MATCH (e:Entity {id: $entity_id}) RETURN e",
+		} {
+			input := sharedPhoneTemplate()
+			input.Query = query
+			input.Parameters = input.Parameters[:1]
+			_, err := service.Create(ctx, templateOwner, input)
+			fields := validationFields(t, err, querytemplates.ErrInvalidTemplate)
+			assert.Contains(t, fields, "query", query)
+		}
+	})
+
+	t.Run("keywords in strings and comments are ignored", func(t *testing.T) {
+		input := sharedPhoneTemplate()
+		input.Query = "// do not DELETE anything\nMATCH (e:Entity {id: $entity_id}) WHERE e.note <> 'SET by $nobody' RETURN e AS account"
+		input.Parameters = input.Parameters[:1]
+		_, err := service.Create(ctx, templateOwner, input)
+		assert.NoError(t, err)
+	})
+
+	t.Run("parameters must match the query", func(t *testing.T) {
+		input := sharedPhoneTemplate()
+		input.Query = "MATCH (e:Entity {id: $entity_id}) WHERE e.country = $country RETURN e"
+		_, err := service.Create(ctx, templateOwner, input)
+		fields := validationFields(t, err, querytemplates.ErrInvalidTemplate)
+		assert.Equal(t, "references undeclared parameter $country", fields["query"])
+		assert.Equal(t, "is not referenced by the query", fields["parameters.min_risk"])
+		assert.Equal(t, "is not referenced by the query", fields["parameters.max_results"])
+	})
+
+	t.Run("parameter declarations are checked", func(t *testing.T) {
+		input := sharedPhoneTemplate()
+		input.Parameters[1].Pattern = "[a-z]+"
+		input.Parameters[2].Default = 500.0
+		input.Parameters = append(input.Parameters, &database.QueryTemplateParameter{Name: "entity_id", Type: "string"})
+		_, err := service.Create(ctx, templateOwner, input)
+		fields := validationFields(t, err, querytemplates.ErrInvalidTemplate)
+		assert.Equal(t, "pattern only applies to string and entity ID parameters", fields["parameters.min_risk"])
+		assert.Equal(t, "invalid default: must be at most 100", fields["parameters.max_results"])
+		assert.Equal(t, "is declared more than once", fields["parameters.entity_id"])
+	})
+
+	t.Run("sharing with roles needs roles", func(t *testing.T) {
+		input := sharedPhoneTemplate()
+		input.SharedRoles = nil
+		_, err := service.Create(ctx, templateOwner, input)
+		fields := validationFields(t, err, querytemplates.ErrInvalidTemplate)
+		assert.Contains(t, fields, "shared_roles")
+	})
+}
+
+func TestQueryTemplates_Sharing(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newQueryTemplateService(&fakeReadGraph{})
+
+	shared, err := service.Create(ctx, templateOwner, sharedPhoneTemplate())
+	require.NoError(t, err)
+
+	private := sharedPhoneTemplate()
+	private.Name = "My scratch query"
+	private.Visibility = querytemplates.VisibilityPrivate
+	private.SharedRoles = nil
+	_, err = service.Create(ctx, templateOwner, private)
+	require.NoError(t, err)
+
+	t.Run("callers see templates shared with their roles", func(t *testing.T) {
+		owned, err := service.List(ctx, templateOwner, "")
+		require.NoError(t, err)
+		assert.Len(t, owned, 2)
+
+		analyst, err := service.List(ctx, templateAnalyst, "")
+		require.NoError(t, err)
+		require.Len(t, analyst, 1)
+		assert.Equal(t, shared.ID, analyst[0].ID)
+
+		auditor, err := service.List(ctx, templateAuditor, "")
+		require.NoError(t, err)
+		assert.Empty(t, auditor)
+
+		admin, err := service.List(ctx, templateAdmin, "")
+		require.NoError(t, err)
+		assert.Len(t, admin, 2)
+	})
+
+	t.Run("hidden templates look missing", func(t *testing.T) {
+		_, err := service.Get(ctx, templateAuditor, shared.ID)
+		assert.ErrorIs(t, err, querytemplates.ErrTemplateNotFound)
+
+		_, err = service.Execute(ctx, templateAuditor, shared.ID, querytemplates.ExecuteRequest{})
+		assert.ErrorIs(t, err, querytemplates.ErrTemplateNotFound)
+	})
+
+	t.Run("only the owner and administrators may change a template", func(t *testing.T) {
+		input := sharedPhoneTemplate()
+		input.Description = "Shared phone numbers"
+
+		_, err := service.Update(ctx, templateAnalyst, shared.ID, input)
+		assert.ErrorIs(t, err, querytemplates.ErrNotPermitted)
+		assert.ErrorIs(t, service.Delete(ctx, templateAnalyst, shared.ID), querytemplates.ErrNotPermitted)
+
+		input.Version = 1
+		updated, err := service.Update(ctx, templateAdmin, shared.ID, input)
+		require.NoError(t, err)
+		assert.Equal(t, 2, updated.Version)
+		assert.Equal(t, "dave", updated.UpdatedBy)
+		assert.Equal(t, "alice", updated.OwnerID)
+
+		_, err = service.Update(ctx, templateOwner, shared.ID, input)
+		assert.ErrorIs(t, err, querytemplates.ErrVersionConflict)
+	})
+}
+
+func TestQueryTemplates_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("parameters are bound with defaults and the run is recorded", func(t *testing.T) {
+		graph := &fakeReadGraph{rows: 3}
+		service, store := newQueryTemplateService(graph)
+		template, err := service.Create(ctx, templateOwner, sharedPhoneTemplate())
+		require.NoError(t, err)
+
+		result, err := service.Execute(ctx, templateAnalyst, template.ID, querytemplates.ExecuteRequest{
+			Parameters:      map[string]interface{}{"entity_id": "acct-1", "max_results": "10"},
+			InvestigationID: "case-7",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, template.Query, graph.query)
+		assert.Equal(t, map[string]interface{}{"entity_id": "acct-1", "min_risk": 0.0, "max_results": int64(10)}, graph.params)
+		assert.Equal(t, 10, graph.maxRows)
+		assert.Equal(t, []string{"account"}, result.Columns)
+		assert.Len(t, result.Rows, 3)
+
+		require.Len(t, store.executions, 1)
+		execution := store.executions[0]
+		assert.Equal(t, querytemplates.StatusSucceeded, execution.Status)
+		assert.Equal(t, "bob", execution.UserID)
+		assert.Equal(t, "case-7", execution.InvestigationID)
+		assert.Equal(t, 1, execution.TemplateVersion)
+		assert.Equal(t, 3, execution.RowCount)
+	})
+
+	t.Run("invalid values are rejected per parameter and recorded", func(t *testing.T) {
+		graph := &fakeReadGraph{rows: 3}
+		service, store := newQueryTemplateService(graph)
+		template, err := service.Create(ctx, templateOwner, sharedPhoneTemplate())
+		require.NoError(t, err)
+
+		_, err = service.Execute(ctx, templateOwner, template.ID, querytemplates.ExecuteRequest{
+			Parameters: map[string]interface{}{"min_risk": 250.0, "max_results": 2.5, "phone": "555"},
+		})
+		fields := validationFields(t, err, querytemplates.ErrInvalidParameters)
+		assert.Equal(t, map[string]string{
+			"entity_id":   "is required",
+			"min_risk":    "must be at most 100",
+			"max_results": "must be a whole number",
+			"phone":       "is not a parameter of this template",
+		}, fields)
+
+		assert.Empty(t, graph.query, "rejected executions never reach the graph")
+		require.Len(t, store.executions, 1)
+		assert.Equal(t, querytemplates.StatusRejected, store.executions[0].Status)
+	})
+
+	t.Run("rows are capped at the maximum", func(t *testing.T) {
+		graph := &fakeReadGraph{rows: 80}
+		service, store := newQueryTemplateService(graph)
+		template, err := service.Create(ctx, templateOwner, sharedPhoneTemplate())
+		require.NoError(t, err)
+
+		result, err := service.Execute(ctx, templateOwner, template.ID, querytemplates.ExecuteRequest{
+			Parameters: map[string]interface{}{"entity_id": "acct-1"},
+			Limit:      1000,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 50, graph.maxRows)
+		assert.Len(t, result.Rows, 50)
+		assert.True(t, result.Truncated)
+		assert.True(t, store.executions[0].Truncated)
+	})
+
+	t.Run("slow queries time out", func(t *testing.T) {
+		graph := &fakeReadGraph{delay: time.Minute}
+		store := newFakeTemplateStore()
+		cfg := queryTemplateConfig()
+		cfg.ExecutionTimeout = 20 * time.Millisecond
+		service := querytemplates.NewService(store, graph, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		template, err := service.Create(ctx, templateOwner, sharedPhoneTemplate())
+		require.NoError(t, err)
+
+		_, err = service.Execute(ctx, templateOwner, template.ID, querytemplates.ExecuteRequest{
+			Parameters: map[string]interface{}{"entity_id": "acct-1"},
+		})
+		assert.ErrorIs(t, err, querytemplates.ErrExecutionTimeout)
+		require.Len(t, store.executions, 1)
+		assert.Equal(t, querytemplates.StatusFailed, store.executions[0].Status)
+	})
+
+	t.Run("history is limited to the caller unless they own the template", func(t *testing.T) {
+		service, _ := newQueryTemplateService(&fakeReadGraph{rows: 1})
+		template, err := service.Create(ctx, templateOwner, sharedPhoneTemplate())
+		require.NoError(t, err)
+
+		for _, caller := range []querytemplates.Caller{templateOwner, templateAnalyst, templateAnalyst} {
+			_, err := service.Execute(ctx, caller, template.ID, querytemplates.ExecuteRequest{
+				Parameters: map[string]interface{}{"entity_id": "acct-1"},
+			})
+			require.NoError(t, err)
+		}
+
+		own, err := service.History(ctx, templateAnalyst, database.QueryExecutionFilter{TemplateID: template.ID})
+		require.NoError(t, err)
+		assert.Len(t, own, 2)
+
+		all, err := service.History(ctx, templateOwner, database.QueryExecutionFilter{TemplateID: template.ID})
+		require.NoError(t, err)
+		assert.Len(t, all, 3)
+
+		admin, err := service.History(ctx, templateAdmin, database.QueryExecutionFilter{})
+		require.NoError(t, err)
+		assert.Len(t, admin, 3)
+	})
+}
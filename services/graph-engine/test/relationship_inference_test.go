@@ -0,0 +1,273 @@
+package test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	tcneo4j "github.com/testcontainers/testcontainers-go/modules/neo4j"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/neo4j"
+	"github.com/aegisshield/graph-engine/internal/resolution"
+)
+
+type inferenceNode struct {
+	id    string
+	label string // Entity, or an attribute label such as Device
+	value string
+}
+
+type inferenceEdge struct {
+	from, to string
+	relType  string
+	amount   float64
+	at       time.Time
+}
+
+// inferenceFixture is a small transaction graph seeded for each strategy:
+//   - alice and bob share counterparties c1-c3, carol only c1, and all of
+//     them use a hub merchant too busy to count
+//   - dave transacts five minutes after alice four times, and once with her
+//   - frank and grace gamble large sums at night at the same casino, henry
+//     small sums at noon
+//   - erin shares alice's device and address, ivan only the address, and
+//     alice, bob, carol and dave share a registered agent's address
+type inferenceFixture struct {
+	nodes []inferenceNode
+	edges []inferenceEdge
+}
+
+func newInferenceFixture(now time.Time) *inferenceFixture {
+	f := &inferenceFixture{}
+	for _, id := range []string{"alice", "bob", "carol", "dave", "erin", "frank", "grace", "henry", "ivan",
+		"c1", "c2", "c3", "c4", "hub", "shop-a", "shop-b", "casino"} {
+		f.nodes = append(f.nodes, inferenceNode{id: id, label: "Entity"})
+	}
+	f.nodes = append(f.nodes,
+		inferenceNode{id: "device-1", label: "Device", value: "imei-3550"},
+		inferenceNode{id: "address-1", label: "Address", value: "12 Mill Lane"},
+		inferenceNode{id: "address-agent", label: "Address", value: "1 Registered Agent Way"},
+	)
+
+	// Unrelated transactions are spread seven hours apart, on the half hour
+	day := now.Truncate(24 * time.Hour).Add(-24 * time.Hour)
+	grid := 0
+	spread := func(from, to string, amount float64) {
+		grid++
+		f.edges = append(f.edges, inferenceEdge{from: from, to: to, relType: "TRANSACTION", amount: amount,
+			at: day.Add(-time.Duration(grid*7)*time.Hour + 30*time.Minute)})
+	}
+	for _, pair := range [][2]string{
+		{"alice", "c1"}, {"alice", "c2"}, {"c3", "alice"}, {"bob", "c1"}, {"bob", "c2"}, {"bob", "c3"}, {"bob", "c4"},
+		{"carol", "c1"}, {"alice", "hub"}, {"bob", "hub"}, {"carol", "hub"}, {"dave", "hub"}, {"erin", "hub"},
+	} {
+		spread(pair[0], pair[1], 120)
+	}
+
+	at := func(from, to string, amount float64, daysAgo int, hour, minute int) {
+		f.edges = append(f.edges, inferenceEdge{from: from, to: to, relType: "TRANSACTION", amount: amount,
+			at: day.Add(-time.Duration(daysAgo)*24*time.Hour + time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)})
+	}
+	for _, daysAgo := range []int{10, 8, 6, 4} {
+		at("alice", "shop-a", 75, daysAgo, 14, 0)
+		at("dave", "shop-b", 80, daysAgo, 14, 5)
+	}
+	at("alice", "dave", 500, 3, 9, 0)
+	at("alice", "shop-a", 60, 400, 14, 0) // outside the lookback
+	at("dave", "shop-b", 60, 400, 14, 5)
+
+	for daysAgo := 1; daysAgo <= 6; daysAgo++ {
+		at("frank", "casino", 4000+float64(daysAgo)*100, daysAgo, 2, 0)
+		at("grace", "casino", 5000+float64(daysAgo)*100, daysAgo+20, 3, 0)
+		at("henry", "casino", 20, daysAgo+40, 12, 0)
+	}
+
+	for _, link := range []struct{ entity, attribute, relType string }{
+		{"alice", "device-1", "USES_DEVICE"}, {"erin", "device-1", "USES_DEVICE"},
+		{"alice", "address-1", "LOCATED_AT"}, {"erin", "address-1", "LOCATED_AT"}, {"ivan", "address-1", "LOCATED_AT"},
+		{"alice", "address-agent", "LOCATED_AT"}, {"bob", "address-agent", "LOCATED_AT"}, {"carol", "address-agent", "LOCATED_AT"},
+		{"dave", "address-agent", "LOCATED_AT"},
+	} {
+		f.edges = append(f.edges, inferenceEdge{from: link.entity, to: link.attribute, relType: link.relType})
+	}
+	return f
+}
+
+func newInferenceResolver(graph resolution.QueryRunner) *resolution.EntityResolver {
+	return resolution.NewEntityResolver(graph, config.GraphEngineConfig{MaxTraversalDepth: 5},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func inferred(t *testing.T, resolver *resolution.EntityResolver, strategy resolution.InferenceStrategy, minConfidence float64, parameters map[string]interface{}, ids ...string) map[string]*resolution.InferredRelationship {
+	t.Helper()
+	result, err := resolver.InferRelationships(context.Background(), &resolution.RelationshipInferenceRequest{
+		EntityIDs:         ids,
+		InferenceStrategy: strategy,
+		MinConfidence:     minConfidence,
+		MaxDepth:          2,
+		Parameters:        parameters,
+	})
+	require.NoError(t, err)
+
+	byPair := make(map[string]*resolution.InferredRelationship)
+	for _, rel := range result.InferredRelationships {
+		key := rel.SourceEntityID + "-" + rel.TargetEntityID
+		require.NotContains(t, byPair, key, "each pair is inferred once")
+		byPair[key] = rel
+	}
+	return byPair
+}
+
+// seedInferenceGraph starts a disposable Neo4j and seeds it with the fixture
+func seedInferenceGraph(t *testing.T, fixture *inferenceFixture) *neo4j.Client {
+	t.Helper()
+	ctx := context.Background()
+
+	// Start Neo4j container
+	neo4jContainer, err := tcneo4j.RunContainer(ctx,
+		testcontainers.WithImage("neo4j:5.12-community"),
+		tcneo4j.WithAdminPassword("inference-test"),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := neo4jContainer.Terminate(ctx); err != nil {
+			t.Logf("failed to terminate container: %s", err)
+		}
+	})
+
+	boltURL, err := neo4jContainer.BoltUrl(ctx)
+	require.NoError(t, err)
+
+	client, err := neo4j.NewClient(config.Neo4jConfig{
+		URI:               boltURL,
+		Username:          "neo4j",
+		Password:          "inference-test",
+		Database:          "neo4j",
+		MaxConnections:    5,
+		ConnectionTimeout: 10 * time.Second,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	for _, node := range fixture.nodes {
+		_, err := client.ExecuteQuery(ctx, "CREATE (n:"+node.label+" {id: $id, value: $value})",
+			map[string]interface{}{"id": node.id, "value": node.value})
+		require.NoError(t, err)
+	}
+	for _, edge := range fixture.edges {
+		properties := map[string]interface{}{}
+		if edge.relType == "TRANSACTION" {
+			properties = map[string]interface{}{"amount": edge.amount, "timestamp": edge.at}
+		}
+		_, err := client.ExecuteQuery(ctx, `
+			MATCH (a {id: $from}), (b {id: $to})
+			CREATE (a)-[r:`+edge.relType+`]->(b)
+			SET r = $properties
+		`, map[string]interface{}{"from": edge.from, "to": edge.to, "properties": properties})
+		require.NoError(t, err)
+	}
+
+	return client
+}
+
+func TestRelationshipInference(t *testing.T) {
+	resolver := newInferenceResolver(seedInferenceGraph(t, newInferenceFixture(time.Now())))
+
+	t.Run("co-transaction counterparties", func(t *testing.T) {
+		rels := inferred(t, resolver, resolution.InferenceStrategyTransactional, 0.1,
+			map[string]interface{}{"max_counterparty_degree": 4}, "alice", "bob", "carol")
+		require.Contains(t, rels, "alice-bob")
+		assert.NotContains(t, rels, "bob-alice")
+		assert.NotContains(t, rels, "alice-carol", "one shared counterparty is not enough")
+
+		rel := rels["alice-bob"]
+		assert.Equal(t, resolution.RelationshipSharedCounterparty, rel.Type)
+		require.Len(t, rel.Evidence, 1)
+		assert.Equal(t, []string{"c1", "c2", "c3"}, rel.Evidence[0].Metadata["counterparties"], "the busy hub does not count")
+		assert.InDelta(t, 0.6*3.0/8+0.4*(1-0.2231), rel.Confidence, 0.01) // alice: c1-c3, hub, shop-a, dave; bob: c1-c4, hub
+		assert.Equal(t, "transactional", rel.Metadata["strategy"])
+		assert.NotEmpty(t, rel.ID)
+	})
+
+	t.Run("temporal co-occurrence", func(t *testing.T) {
+		rels := inferred(t, resolver, resolution.InferenceStrategyTemporal, 0.3, nil, "alice")
+		require.Contains(t, rels, "alice-dave")
+		assert.NotContains(t, rels, "alice-bob")
+
+		rel := rels["alice-dave"]
+		assert.Equal(t, resolution.RelationshipTemporalCoActivity, rel.Type)
+		assert.Equal(t, 4, rel.Evidence[0].Metadata["co_occurrences"], "their own transaction and those outside the lookback do not count")
+		assert.InDelta(t, 0.8*(1-0.2636), rel.Confidence, 0.01) // four of dave's five other transactions
+
+		rels = inferred(t, resolver, resolution.InferenceStrategyTemporal, 0.3, map[string]interface{}{"window": "1m"}, "alice")
+		assert.Empty(t, rels, "five minutes apart is outside a one minute window")
+	})
+
+	t.Run("behavioral similarity", func(t *testing.T) {
+		rels := inferred(t, resolver, resolution.InferenceStrategyBehavioral, 0.5, nil, "frank")
+		require.Contains(t, rels, "frank-grace")
+		assert.NotContains(t, rels, "frank-henry")
+
+		rel := rels["frank-grace"]
+		assert.Equal(t, resolution.RelationshipSimilarBehavior, rel.Type)
+		require.Len(t, rel.Evidence, 2)
+		assert.Equal(t, 1.0, rel.Evidence[1].Strength, "the same orders of magnitude")
+		assert.Zero(t, rel.Evidence[0].Strength, "an hour apart")
+		assert.Equal(t, 0.5, rel.Confidence)
+
+		rels = inferred(t, resolver, resolution.InferenceStrategyBehavioral, 0.5, map[string]interface{}{"min_transactions": 7}, "frank")
+		assert.Empty(t, rels, "too few transactions to profile")
+	})
+
+	t.Run("shared attributes", func(t *testing.T) {
+		rels := inferred(t, resolver, resolution.InferenceStrategyNetwork, 0.1,
+			map[string]interface{}{"max_attribute_sharers": 3}, "alice")
+		require.Contains(t, rels, "alice-erin")
+		require.Contains(t, rels, "alice-ivan")
+		assert.NotContains(t, rels, "alice-bob", "the registered agent's address links too many entities")
+
+		rel := rels["alice-erin"]
+		assert.Equal(t, resolution.RelationshipSharedAttribute, rel.Type)
+		assert.InDelta(t, 0.95, rel.Confidence, 0.001)
+		require.Len(t, rel.Evidence, 2)
+		assert.Equal(t, "shared_device", rel.Evidence[0].EvidenceType)
+		assert.Equal(t, "shared_address", rel.Evidence[1].EvidenceType)
+		assert.Equal(t, "imei-3550", rel.Evidence[0].Metadata["value"])
+
+		assert.InDelta(t, 0.5, rels["alice-ivan"].Confidence, 0.001)
+	})
+
+	t.Run("hybrid combines every strategy", func(t *testing.T) {
+		result, err := resolver.InferRelationships(context.Background(), &resolution.RelationshipInferenceRequest{
+			EntityIDs:         []string{"alice", "bob"},
+			InferenceStrategy: resolution.InferenceStrategyHybrid,
+			MinConfidence:     0.4,
+			MaxDepth:          2,
+			Parameters:        map[string]interface{}{"max_counterparty_degree": 4, "max_attribute_sharers": 3},
+		})
+		require.NoError(t, err)
+
+		types := make(map[string]bool)
+		for _, rel := range result.InferredRelationships {
+			types[rel.Type] = true
+		}
+		assert.True(t, types[resolution.RelationshipSharedCounterparty])
+		assert.True(t, types[resolution.RelationshipTemporalCoActivity])
+		assert.True(t, types[resolution.RelationshipSharedAttribute])
+	})
+
+	t.Run("relationship types are validated", func(t *testing.T) {
+		_, err := resolver.InferRelationships(context.Background(), &resolution.RelationshipInferenceRequest{
+			EntityIDs:         []string{"alice"},
+			InferenceStrategy: resolution.InferenceStrategyTemporal,
+			RelationshipTypes: []string{"TRANSACTION]-(n) DETACH DELETE n //"},
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,215 @@
+package test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/graph-engine/internal/patterns"
+	"github.com/aegisshield/graph-engine/internal/temporal"
+)
+
+var temporalEpoch = time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+
+// versionGraph returns fixed rows and records the queries it is given
+type versionGraph struct {
+	rows    []map[string]interface{}
+	queries []string
+	params  []map[string]interface{}
+}
+
+func (g *versionGraph) ExecuteQuery(_ context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	g.queries = append(g.queries, query)
+	g.params = append(g.params, params)
+	return g.rows, nil
+}
+
+func TestTemporal_Predicates(t *testing.T) {
+	current := context.Background()
+	past := temporal.WithAsOf(current, temporalEpoch)
+
+	t.Run("current graph reads open versions", func(t *testing.T) {
+		assert.Equal(t, "r.valid_to IS NULL", temporal.Relationship(current, "r"))
+		assert.Contains(t, temporal.Path(current, "path"), "relationships(path)")
+
+		_, ok := temporal.AsOf(current)
+		assert.False(t, ok)
+		assert.WithinDuration(t, time.Now(), temporal.Bind(current, nil)[temporal.AsOfParam].(time.Time), time.Minute)
+	})
+
+	t.Run("as-of reads versions valid then", func(t *testing.T) {
+		predicate := temporal.Relationship(past, "r")
+		assert.Contains(t, predicate, "r.valid_from <= $as_of")
+		assert.Contains(t, predicate, "r.valid_to > $as_of")
+
+		params := temporal.Bind(past, map[string]interface{}{"entityId": "A"})
+		assert.Equal(t, temporalEpoch, params[temporal.AsOfParam])
+		assert.Equal(t, "A", params["entityId"])
+	})
+
+	t.Run("loaded relationships are filtered the same way", func(t *testing.T) {
+		before := temporalEpoch.Add(-time.Hour)
+		after := temporalEpoch.Add(time.Hour)
+
+		cases := []struct {
+			name       string
+			properties map[string]interface{}
+			current    bool
+			asOf       bool
+		}{
+			{"unversioned", map[string]interface{}{}, true, true},
+			{"open since before", map[string]interface{}{"valid_from": before}, true, true},
+			{"opened after", map[string]interface{}{"valid_from": after}, true, false},
+			{"closed after", map[string]interface{}{"valid_from": before, "valid_to": after}, false, true},
+			{"closed before", map[string]interface{}{"valid_to": before}, false, false},
+			{"closed then", map[string]interface{}{"valid_to": temporalEpoch.Format(time.RFC3339)}, false, false},
+		}
+		for _, c := range cases {
+			assert.Equal(t, c.current, temporal.Valid(current, c.properties), c.name)
+			assert.Equal(t, c.asOf, temporal.Valid(past, c.properties), c.name)
+		}
+	})
+}
+
+func TestTemporal_Writer(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	t.Run("records a new version", func(t *testing.T) {
+		graph := &versionGraph{rows: []map[string]interface{}{
+			{"outcome": temporal.OutcomeCreated, "version": int64(2), "valid_from": temporalEpoch},
+		}}
+		writer := temporal.NewWriter(graph, logger)
+
+		result, err := writer.Record(ctx, temporal.Change{
+			SourceID:   "A",
+			TargetID:   "B",
+			Type:       "OWNS",
+			Properties: map[string]interface{}{"share": 0.6, "note": nil},
+			ValidFrom:  temporalEpoch.In(time.FixedZone("CET", 3600)),
+			RecordedBy: "analyst-1",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &temporal.Result{Outcome: temporal.OutcomeCreated, Version: 2, ValidFrom: temporalEpoch}, result)
+
+		require.Len(t, graph.queries, 1)
+		assert.Contains(t, graph.queries[0], "-[open:OWNS]->")
+		assert.Contains(t, graph.queries[0], "SET previous.valid_to = $validFrom")
+		params := graph.params[0]
+		assert.Equal(t, map[string]interface{}{"share": 0.6}, params["properties"], "nil properties are not stored")
+		assert.Equal(t, time.UTC, params["validFrom"].(time.Time).Location())
+		assert.Equal(t, "analyst-1", params["recordedBy"])
+	})
+
+	t.Run("version hashes ignore property order and see changes", func(t *testing.T) {
+		graph := &versionGraph{rows: []map[string]interface{}{{"outcome": temporal.OutcomeUnchanged, "version": int64(1)}}}
+		writer := temporal.NewWriter(graph, logger)
+
+		for _, properties := range []map[string]interface{}{
+			{"share": 0.6, "role": "director"},
+			{"role": "director", "share": 0.6},
+			{"role": "director", "share": 0.7},
+		} {
+			_, err := writer.Record(ctx, temporal.Change{SourceID: "A", TargetID: "B", Type: "OWNS", Properties: properties})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, graph.params[0]["versionHash"], graph.params[1]["versionHash"])
+		assert.NotEqual(t, graph.params[0]["versionHash"], graph.params[2]["versionHash"])
+	})
+
+	t.Run("rejects invalid changes", func(t *testing.T) {
+		graph := &versionGraph{}
+		writer := temporal.NewWriter(graph, logger)
+
+		for _, change := range []temporal.Change{
+			{TargetID: "B", Type: "OWNS"},
+			{SourceID: "A", TargetID: "B", Type: "OWNS]->(x) DETACH DELETE x //"},
+			{SourceID: "A", TargetID: "B", Type: "OWNS", Properties: map[string]interface{}{"valid_to": temporalEpoch}},
+			{SourceID: "A", TargetID: "B", Type: "OWNS", Properties: map[string]interface{}{"nested": map[string]interface{}{"a": 1}}},
+		} {
+			_, err := writer.Record(ctx, change)
+			assert.ErrorIs(t, err, temporal.ErrInvalidRelationship)
+		}
+		assert.Empty(t, graph.queries, "invalid changes are not written")
+
+		_, err := writer.Record(ctx, temporal.Change{SourceID: "A", TargetID: "MISSING", Type: "OWNS"})
+		assert.ErrorIs(t, err, temporal.ErrEntityNotFound)
+	})
+
+	t.Run("ends the current version", func(t *testing.T) {
+		graph := &versionGraph{rows: []map[string]interface{}{{"ended": int64(1)}}}
+		writer := temporal.NewWriter(graph, logger)
+
+		ended, err := writer.End(ctx, "A", "B", "OWNS", temporalEpoch)
+		require.NoError(t, err)
+		assert.Equal(t, 1, ended)
+		assert.Equal(t, temporalEpoch, graph.params[0]["validTo"])
+
+		graph.rows = []map[string]interface{}{{"ended": int64(0)}}
+		_, err = writer.End(ctx, "A", "B", "OWNS", temporalEpoch)
+		assert.ErrorIs(t, err, temporal.ErrRelationshipNotFound)
+	})
+
+	t.Run("lists history without versioning properties", func(t *testing.T) {
+		graph := &versionGraph{rows: []map[string]interface{}{
+			{
+				"type": "OWNS", "version": int64(1), "valid_from": temporalEpoch, "valid_to": temporalEpoch.Add(time.Hour),
+				"properties": map[string]interface{}{"share": 0.6, "version": int64(1), "version_hash": "abc"},
+			},
+			{
+				"type": "OWNS", "version": int64(2), "valid_from": temporalEpoch.Add(time.Hour), "recorded_by": "analyst-1",
+				"properties": map[string]interface{}{"share": 0.7, "version": int64(2)},
+			},
+		}}
+		writer := temporal.NewWriter(graph, logger)
+
+		versions, err := writer.History(ctx, "A", "B", "")
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.Equal(t, map[string]interface{}{"share": 0.6}, versions[0].Properties)
+		assert.Equal(t, temporalEpoch.Add(time.Hour), *versions[0].ValidTo)
+		assert.Nil(t, versions[1].ValidTo, "the current version is open")
+		assert.Equal(t, "analyst-1", versions[1].RecordedBy)
+		assert.Contains(t, graph.queries[0], "-[r]->", "every type without a type filter")
+	})
+}
+
+// asOfMotifGraph records the parameters of the motif library's queries
+type asOfMotifGraph struct {
+	*motifGraph
+	params []map[string]interface{}
+}
+
+func (g *asOfMotifGraph) ExecuteQuery(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	g.params = append(g.params, params)
+	return g.motifGraph.ExecuteQuery(ctx, query, params)
+}
+
+func TestTemporal_MotifsAsOf(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	graph := &asOfMotifGraph{motifGraph: newMotifFixture()}
+	library := patterns.NewMotifLibrary(graph, nil, motifConfig(), logger)
+
+	_, err := library.Run(temporal.WithAsOf(context.Background(), temporalEpoch), &patterns.DetectionRequest{
+		Types:     []patterns.PatternType{patterns.PatternTypeFanIn},
+		EntityIDs: []string{"MULE"},
+	})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, graph.queries)
+	transactions := graph.queries[0]
+	assert.Contains(t, transactions, "$as_of - duration($timeWindow)", "the window ends at the as-of time")
+	assert.Contains(t, transactions, "t.valid_from <= $as_of")
+	assert.Contains(t, transactions, "relationships(path)", "the scope follows relationships valid then")
+	assert.Equal(t, temporalEpoch, graph.params[0][temporal.AsOfParam])
+
+	for _, query := range graph.queries {
+		assert.False(t, strings.Contains(query, "datetime() -"), "no window is anchored at now")
+	}
+}
@@ -0,0 +1,229 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/handlers"
+	"github.com/aegisshield/graph-engine/internal/tenancy"
+)
+
+// fakeProvisioner keeps tenant databases in memory
+type fakeProvisioner struct {
+	databases map[string]*tenancy.Database
+}
+
+func (p *fakeProvisioner) ProvisionTenantDatabase(_ context.Context, tenantID string) (*tenancy.Database, error) {
+	name, err := tenancy.DatabaseName("tenant-", tenantID)
+	if err != nil {
+		return nil, err
+	}
+	db := &tenancy.Database{TenantID: tenantID, Name: name, Status: "online", Online: true}
+	p.databases[tenantID] = db
+	return db, nil
+}
+
+func (p *fakeProvisioner) TenantDatabase(_ context.Context, tenantID string) (*tenancy.Database, error) {
+	if db, ok := p.databases[tenantID]; ok {
+		return db, nil
+	}
+	return nil, tenancy.ErrTenantNotProvisioned
+}
+
+func (p *fakeProvisioner) TenantDatabases(context.Context) ([]*tenancy.Database, error) {
+	var databases []*tenancy.Database
+	for _, db := range p.databases {
+		databases = append(databases, db)
+	}
+	return databases, nil
+}
+
+func tenancyConfig(required bool) config.TenancyConfig {
+	return config.TenancyConfig{
+		Enabled:        true,
+		Header:         "X-Tenant-ID",
+		MetadataKey:    "x-tenant-id",
+		RequireTenant:  required,
+		DatabasePrefix: "tenant-",
+	}
+}
+
+func TestTenancy_Unit(t *testing.T) {
+	t.Run("Database Names", func(t *testing.T) {
+		name, err := tenancy.DatabaseName("tenant-", " Acme-Bank ")
+		require.NoError(t, err)
+		assert.Equal(t, "tenant-acme-bank", name)
+
+		for _, invalid := range []string{"", "acme_bank", "acme.bank", "-acme", "acme bank"} {
+			_, err := tenancy.NormalizeTenant(invalid)
+			assert.ErrorIs(t, err, tenancy.ErrInvalidTenant, invalid)
+		}
+
+		tenantID, ok := tenancy.TenantOf("tenant-", "tenant-acme-bank")
+		assert.True(t, ok)
+		assert.Equal(t, "acme-bank", tenantID)
+
+		_, ok = tenancy.TenantOf("tenant-", "neo4j")
+		assert.False(t, ok)
+	})
+
+	t.Run("Cross Database Queries", func(t *testing.T) {
+		assert.NoError(t, tenancy.CheckQuery("MATCH (n:Entity) WHERE n.use = $use RETURN n"))
+		assert.NoError(t, tenancy.CheckQuery("MATCH (n:Entity {purpose: 'USE funds'}) RETURN n"))
+
+		assert.ErrorIs(t, tenancy.CheckQuery("USE `tenant-other` MATCH (n) RETURN n"), tenancy.ErrCrossTenantQuery)
+		assert.ErrorIs(t, tenancy.CheckQuery("  use tenant-other\nMATCH (n) RETURN n"), tenancy.ErrCrossTenantQuery)
+		assert.ErrorIs(t, tenancy.CheckQuery("MATCH (a) CALL { USE tenant-other MATCH (b) RETURN b } RETURN a, b"), tenancy.ErrCrossTenantQuery)
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		_, ok := tenancy.FromContext(context.Background())
+		assert.False(t, ok)
+
+		tenantID, ok := tenancy.FromContext(tenancy.WithTenant(context.Background(), "acme"))
+		assert.True(t, ok)
+		assert.Equal(t, "acme", tenantID)
+	})
+}
+
+func TestTenancy_HTTPMiddleware(t *testing.T) {
+	var routed string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routed, _ = tenancy.FromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(required bool, path, tenantID string) int {
+		routed = ""
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if tenantID != "" {
+			req.Header.Set("X-Tenant-ID", tenantID)
+		}
+		rec := httptest.NewRecorder()
+		tenancy.Middleware(tenancyConfig(required))(next).ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(true, "/api/v1/entities/e1/neighborhood", "Acme"))
+	assert.Equal(t, "acme", routed)
+
+	assert.Equal(t, http.StatusBadRequest, serve(true, "/api/v1/entities/e1/neighborhood", ""))
+	assert.Equal(t, http.StatusBadRequest, serve(false, "/api/v1/entities/e1/neighborhood", "acme_bank"))
+
+	assert.Equal(t, http.StatusOK, serve(false, "/api/v1/entities/e1/neighborhood", ""))
+	assert.Empty(t, routed, "requests without a tenant use the shared database")
+
+	assert.Equal(t, http.StatusOK, serve(true, "/health", ""))
+	assert.Equal(t, http.StatusOK, serve(true, "/api/v1/tenants/acme/database", ""))
+}
+
+func TestTenancy_GRPCInterceptor(t *testing.T) {
+	interceptor := tenancy.UnaryServerInterceptor(tenancyConfig(true))
+	info := &grpc.UnaryServerInfo{FullMethod: "/graph.GraphEngine/AnalyzeSubGraph"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		tenantID, _ := tenancy.FromContext(ctx)
+		return tenantID, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant-id", "acme"))
+	resp, err := interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", resp)
+
+	_, err = interceptor(context.Background(), nil, info, handler)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestTenancy_Handlers(t *testing.T) {
+	provisioner := &fakeProvisioner{databases: map[string]*tenancy.Database{}}
+	router := mux.NewRouter()
+	handlers.NewTenantHTTPHandlers(provisioner, slog.New(slog.NewTextHandler(io.Discard, nil))).RegisterTenantRoutes(router)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/tenants/acme/database").Code)
+
+	rec := do(http.MethodPost, "/api/v1/tenants/acme/database")
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var db tenancy.Database
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &db))
+	assert.Equal(t, "tenant-acme", db.Name)
+
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/v1/tenants/acme_bank/database").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/tenants/acme/database").Code)
+
+	rec = do(http.MethodGet, "/api/v1/tenants/databases")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Count int `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.Count)
+}
+
+// gaugeValue reads a tenant database gauge from the default registry
+func gaugeValue(t *testing.T, name, tenantID string) (float64, bool) {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "tenant" && label.GetValue() == tenantID {
+					return metric.GetGauge().GetValue(), true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+func TestTenancy_Monitor(t *testing.T) {
+	provisioner := &fakeProvisioner{databases: map[string]*tenancy.Database{
+		"acme":   {TenantID: "acme", Name: "tenant-acme", Status: "online", Online: true, Nodes: 120, Relationships: 340, StoreBytes: 4096},
+		"globex": {TenantID: "globex", Name: "tenant-globex", Status: "offline", StoreBytes: -1},
+	}}
+	monitor := tenancy.NewMonitor(provisioner, tenancyConfig(false), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	monitor.Refresh(context.Background())
+
+	online, ok := gaugeValue(t, "graph_engine_tenant_database_online", "acme")
+	require.True(t, ok)
+	assert.Equal(t, 1.0, online)
+	nodes, _ := gaugeValue(t, "graph_engine_tenant_database_nodes", "acme")
+	assert.Equal(t, 120.0, nodes)
+	size, _ := gaugeValue(t, "graph_engine_tenant_database_store_bytes", "acme")
+	assert.Equal(t, 4096.0, size)
+
+	online, ok = gaugeValue(t, "graph_engine_tenant_database_online", "globex")
+	require.True(t, ok)
+	assert.Zero(t, online)
+	_, ok = gaugeValue(t, "graph_engine_tenant_database_store_bytes", "globex")
+	assert.False(t, ok, "unknown store sizes are not reported")
+
+	// Databases that disappear stop being reported
+	delete(provisioner.databases, "globex")
+	monitor.Refresh(context.Background())
+	_, ok = gaugeValue(t, "graph_engine_tenant_database_online", "globex")
+	assert.False(t, ok)
+}
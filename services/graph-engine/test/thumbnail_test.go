@@ -0,0 +1,165 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/graph-engine/internal/config"
+	"github.com/aegisshield/graph-engine/internal/thumbnail"
+)
+
+func thumbnailGraph() *thumbnail.Graph {
+	return &thumbnail.Graph{
+		Nodes: []thumbnail.Node{
+			{ID: "p1", Type: "Person", Distance: 0},
+			{ID: "a1", Type: "Account", Distance: 1},
+			{ID: "a2", Type: "Account", Distance: 1},
+			{ID: "o1", Type: "Organization", Distance: 2},
+			{ID: "x1", Type: "Address", Distance: 2},
+		},
+		Edges: []thumbnail.Edge{
+			{ID: "r1", Type: "OWNS", SourceID: "p1", TargetID: "a1"},
+			{ID: "r2", Type: "OWNS", SourceID: "p1", TargetID: "a2"},
+			{ID: "r3", Type: "TRANSFER", SourceID: "a1", TargetID: "o1"},
+			{ID: "r4", Type: "TRANSFER", SourceID: "a2", TargetID: "o1"},
+			{ID: "r5", Type: "LOCATED_AT", SourceID: "a2", TargetID: "x1"},
+		},
+	}
+}
+
+func TestThumbnail_Unit(t *testing.T) {
+	t.Run("Cap Prefers Close And Connected Nodes", func(t *testing.T) {
+		capped, truncated := thumbnail.Cap(thumbnailGraph(), 4)
+		require.True(t, truncated)
+		require.Len(t, capped.Nodes, 4)
+
+		ids := make([]string, 0, len(capped.Nodes))
+		for _, node := range capped.Nodes {
+			ids = append(ids, node.ID)
+		}
+		assert.Equal(t, []string{"p1", "a2", "a1", "o1"}, ids)
+		for _, edge := range capped.Edges {
+			assert.NotEqual(t, "x1", edge.TargetID, "edges to dropped nodes are removed")
+		}
+
+		same, truncated := thumbnail.Cap(thumbnailGraph(), 10)
+		assert.False(t, truncated)
+		assert.Len(t, same.Nodes, 5)
+	})
+
+	t.Run("Version Is Order Independent", func(t *testing.T) {
+		graph := thumbnailGraph()
+		reversed := thumbnailGraph()
+		for i, j := 0, len(reversed.Nodes)-1; i < j; i, j = i+1, j-1 {
+			reversed.Nodes[i], reversed.Nodes[j] = reversed.Nodes[j], reversed.Nodes[i]
+		}
+		reversed.Edges[0], reversed.Edges[4] = reversed.Edges[4], reversed.Edges[0]
+
+		assert.Equal(t, thumbnail.Version(graph), thumbnail.Version(reversed))
+
+		updated := thumbnailGraph()
+		updated.Nodes[3].UpdatedAt = "2024-03-01T10:00:00Z"
+		assert.NotEqual(t, thumbnail.Version(graph), thumbnail.Version(updated))
+	})
+
+	t.Run("Layout Is Deterministic And In Bounds", func(t *testing.T) {
+		first := thumbnail.Layout(thumbnailGraph(), 320, 200, 10, 100)
+		second := thumbnail.Layout(thumbnailGraph(), 320, 200, 10, 100)
+		require.Len(t, first, 5)
+		assert.Equal(t, first, second)
+
+		for _, point := range first {
+			assert.GreaterOrEqual(t, point.X, 10.0-1e-9)
+			assert.LessOrEqual(t, point.X, 310.0+1e-9)
+			assert.GreaterOrEqual(t, point.Y, 10.0-1e-9)
+			assert.LessOrEqual(t, point.Y, 190.0+1e-9)
+		}
+		for i := range first {
+			for j := i + 1; j < len(first); j++ {
+				assert.NotEqual(t, first[i], first[j], "nodes should not overlap")
+			}
+		}
+
+		single := thumbnail.Layout(&thumbnail.Graph{Nodes: []thumbnail.Node{{ID: "p1"}}}, 320, 200, 10, 100)
+		assert.Equal(t, []thumbnail.Point{{X: 160, Y: 100}}, single)
+	})
+
+	t.Run("Render SVG", func(t *testing.T) {
+		graph := thumbnailGraph()
+		graph.Nodes[4].ID = `x<1>&"`
+		graph.Edges[4].TargetID = graph.Nodes[4].ID
+
+		positions := thumbnail.Layout(graph, 320, 200, 10, 50)
+		svg := string(thumbnail.RenderSVG(graph, positions, 320, 200))
+
+		assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="320" height="200"`))
+		assert.True(t, strings.HasSuffix(svg, "</svg>"))
+		assert.Equal(t, 5, strings.Count(svg, "<circle"))
+		assert.Equal(t, 5, strings.Count(svg, "<line"))
+		assert.Equal(t, 1, strings.Count(svg, "stroke-width=\"2\""), "only seed entities are outlined")
+		assert.Contains(t, svg, "x&lt;1&gt;&amp;&#34;")
+	})
+
+	t.Run("Render PNG", func(t *testing.T) {
+		graph := thumbnailGraph()
+		positions := thumbnail.Layout(graph, 160, 100, 8, 50)
+
+		data, err := thumbnail.RenderPNG(graph, positions, 160, 100)
+		require.NoError(t, err)
+
+		img, err := png.Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, 160, img.Bounds().Dx())
+		assert.Equal(t, 100, img.Bounds().Dy())
+
+		r, g, b, _ := img.At(int(positions[1].X), int(positions[1].Y)).RGBA()
+		account := thumbnail.NodeColor("account")
+		assert.Equal(t, []uint32{uint32(account.R), uint32(account.G), uint32(account.B)},
+			[]uint32{r >> 8, g >> 8, b >> 8})
+	})
+
+	t.Run("Normalize Request", func(t *testing.T) {
+		cfg := config.ThumbnailConfig{
+			MaxNodes: 100, DefaultDepth: 2, MaxDepth: 3,
+			DefaultWidth: 320, DefaultHeight: 200, MaxWidth: 800, MaxHeight: 600,
+		}
+
+		req := &thumbnail.Request{EntityIDs: []string{"p1"}, Depth: 9, MaxNodes: 500, Width: 5000}
+		require.NoError(t, thumbnail.Normalize(req, cfg))
+		assert.Equal(t, thumbnail.FormatSVG, req.Format)
+		assert.Equal(t, 3, req.Depth)
+		assert.Equal(t, 100, req.MaxNodes)
+		assert.Equal(t, 800, req.Width)
+		assert.Equal(t, 200, req.Height)
+
+		assert.ErrorIs(t, thumbnail.Normalize(&thumbnail.Request{Format: "gif"}, cfg), thumbnail.ErrUnsupportedFormat)
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		cache := thumbnail.NewCache(2, time.Hour)
+		for i := 0; i < 3; i++ {
+			cache.Put(fmt.Sprintf("v%d", i), &thumbnail.Thumbnail{Version: fmt.Sprintf("v%d", i)})
+		}
+
+		assert.Equal(t, 2, cache.Len())
+		_, ok := cache.Get("v0")
+		assert.False(t, ok, "least recently used entry is evicted")
+		cached, ok := cache.Get("v2")
+		require.True(t, ok)
+		assert.Equal(t, "v2", cached.Version)
+
+		expiring := thumbnail.NewCache(2, time.Millisecond)
+		expiring.Put("v0", &thumbnail.Thumbnail{})
+		time.Sleep(5 * time.Millisecond)
+		_, ok = expiring.Get("v0")
+		assert.False(t, ok)
+		assert.Equal(t, 0, expiring.Len())
+	})
+}
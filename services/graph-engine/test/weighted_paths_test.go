@@ -0,0 +1,132 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aegisshield/graph-engine/internal/analytics"
+	"github.com/aegisshield/graph-engine/internal/neo4j"
+)
+
+func pathEntity(id string, lat, lon float64) *neo4j.Entity {
+	return &neo4j.Entity{ID: id, Type: "Entity", Properties: map[string]interface{}{"id": id, "latitude": lat, "longitude": lon}}
+}
+
+func pathRelationship(id, relType, source, target string, weight interface{}) *neo4j.Relationship {
+	properties := map[string]interface{}{}
+	if weight != nil {
+		properties["cost"] = weight
+	}
+	return &neo4j.Relationship{ID: id, Type: relType, SourceID: source, TargetID: target, Properties: properties}
+}
+
+// pathGraph has a direct but expensive transfer from a to d, a cheap route
+// through b and c, and a longer, cheaper route through e
+func pathGraph() *neo4j.SubGraph {
+	return &neo4j.SubGraph{
+		Entities: []*neo4j.Entity{
+			pathEntity("a", 51.50, -0.12),
+			pathEntity("b", 50.85, 4.35),
+			pathEntity("c", 52.52, 13.40),
+			pathEntity("d", 48.20, 16.37),
+			pathEntity("e", 40.71, -74.00),
+		},
+		Relationships: []*neo4j.Relationship{
+			pathRelationship("ad", "TRANSACTION", "a", "d", 100.0),
+			pathRelationship("ab", "TRANSACTION", "a", "b", int64(10)),
+			pathRelationship("cb", "TRANSACTION", "c", "b", 10.0), // followed against its direction
+			pathRelationship("cd", "OWNS", "c", "d", 10.0),
+			pathRelationship("ae", "TRANSACTION", "a", "e", 1.0),
+			pathRelationship("ed", "TRANSACTION", "e", "d", nil), // default weight
+		},
+	}
+}
+
+func weightedOptions(algorithm analytics.PathAlgorithm) analytics.WeightedPathOptions {
+	return analytics.WeightedPathOptions{
+		Algorithm:         algorithm,
+		WeightProperty:    "cost",
+		DefaultWeight:     50,
+		MaxHops:           5,
+		LatitudeProperty:  "latitude",
+		LongitudeProperty: "longitude",
+	}
+}
+
+func pathEntityIDs(path *neo4j.Path) []string {
+	var ids []string
+	for _, entity := range path.Entities {
+		ids = append(ids, entity.ID)
+	}
+	return ids
+}
+
+func TestWeightedShortestPath(t *testing.T) {
+	path, err := analytics.WeightedShortestPath(pathGraph(), "a", "d", weightedOptions(analytics.PathAlgorithmDijkstra))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, pathEntityIDs(path))
+	assert.Equal(t, 30.0, path.Cost)
+	assert.Equal(t, 3, path.Length)
+	assert.Equal(t, "a", path.StartEntity.ID)
+	assert.Equal(t, "d", path.EndEntity.ID)
+	require.Len(t, path.Relationships, 3)
+	assert.Equal(t, "cb", path.Relationships[1].ID)
+	assert.Equal(t, "c", path.Relationships[1].SourceID, "relationships keep their stored direction")
+
+	t.Run("the hop limit is respected", func(t *testing.T) {
+		opts := weightedOptions(analytics.PathAlgorithmDijkstra)
+		opts.MaxHops = 2
+		path, err := analytics.WeightedShortestPath(pathGraph(), "a", "d", opts)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "e", "d"}, pathEntityIDs(path))
+		assert.Equal(t, 51.0, path.Cost, "the missing weight defaults")
+
+		opts.MaxHops = 1
+		path, err = analytics.WeightedShortestPath(pathGraph(), "a", "d", opts)
+		require.NoError(t, err)
+		assert.Equal(t, 100.0, path.Cost)
+	})
+
+	t.Run("relationship types are filtered", func(t *testing.T) {
+		opts := weightedOptions(analytics.PathAlgorithmDijkstra)
+		opts.RelationshipTypes = []string{"TRANSACTION"}
+		path, err := analytics.WeightedShortestPath(pathGraph(), "a", "d", opts)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "e", "d"}, pathEntityIDs(path))
+	})
+
+	t.Run("a star finds the same cheapest path", func(t *testing.T) {
+		opts := weightedOptions(analytics.PathAlgorithmAStar)
+		opts.MinCostPerKm = 0.001
+		path, err := analytics.WeightedShortestPath(pathGraph(), "a", "d", opts)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c", "d"}, pathEntityIDs(path))
+		assert.Equal(t, 30.0, path.Cost)
+	})
+
+	t.Run("unreachable targets have no path", func(t *testing.T) {
+		sub := pathGraph()
+		sub.Entities = append(sub.Entities, pathEntity("z", 0, 0))
+
+		_, err := analytics.WeightedShortestPath(sub, "a", "z", weightedOptions(analytics.PathAlgorithmDijkstra))
+		assert.ErrorIs(t, err, analytics.ErrNoPath)
+
+		_, err = analytics.WeightedShortestPath(sub, "a", "missing", weightedOptions(analytics.PathAlgorithmDijkstra))
+		assert.ErrorIs(t, err, analytics.ErrNoPath)
+	})
+
+	t.Run("invalid searches are rejected", func(t *testing.T) {
+		sub := pathGraph()
+		sub.Relationships = append(sub.Relationships, pathRelationship("bd", "TRANSACTION", "b", "d", -5.0))
+		_, err := analytics.WeightedShortestPath(sub, "a", "d", weightedOptions(analytics.PathAlgorithmDijkstra))
+		assert.ErrorIs(t, err, analytics.ErrInvalidPathRequest)
+
+		_, err = analytics.WeightedShortestPath(pathGraph(), "a", "d", weightedOptions(analytics.PathAlgorithmShortest))
+		assert.ErrorIs(t, err, analytics.ErrInvalidPathRequest)
+
+		assert.ErrorIs(t, analytics.ValidateRelationshipTypes([]string{"OWNS", "X]-(n) DETACH DELETE n //"}), analytics.ErrInvalidPathRequest)
+		assert.NoError(t, analytics.ValidateRelationshipTypes([]string{"OWNS", "TRANSACTION"}))
+	})
+}
@@ -3,6 +3,7 @@ module github.com/aegis-shield/services/investigation-toolkit
 go 1.21
 
 require (
+	aegisshield/shared v0.0.0
 	github.com/gorilla/mux v1.8.0
 	github.com/prometheus/client_golang v1.17.0
 	google.golang.org/grpc v1.59.0
@@ -16,6 +17,7 @@ require (
 	github.com/go-redis/redis/v8 v8.11.5
 	gopkg.in/yaml.v3 v3.0.1
 	github.com/google/uuid v1.3.1
+	github.com/golang-jwt/jwt/v5 v5.2.0
 	github.com/jackc/pgx/v5 v5.4.3
 	github.com/neo4j/neo4j-go-driver/v5 v5.13.0
 	github.com/segmentio/kafka-go v0.4.44
@@ -25,6 +27,7 @@ require (
 	github.com/dgraph-io/badger/v4 v4.2.0
 	github.com/tidwall/gjson v1.17.0
 	github.com/xeipuuv/gojsonschema v1.2.0
+	github.com/xuri/excelize/v2 v2.8.0
 )
 
 require (
@@ -46,4 +49,6 @@ require (
 	github.com/jackc/puddle/v2 v2.2.1 // indirect
 	golang.org/x/crypto v0.14.0 // indirect
 	golang.org/x/sync v0.4.0 // indirect
-)
\ No newline at end of file
+)
+
+replace aegisshield/shared => ../../shared
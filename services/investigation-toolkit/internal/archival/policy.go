@@ -0,0 +1,109 @@
+package archival
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+)
+
+// ErrManifestCorrupt is returned when an archived evidence manifest does not
+// match its checksum or cannot be read
+var ErrManifestCorrupt = errors.New("archived evidence manifest is corrupt")
+
+// Policy decides when closed cases move to the archive
+type Policy struct {
+	ClosedAfter time.Duration
+}
+
+// NewPolicy builds an archival policy from configuration
+func NewPolicy(cfg config.ArchivalConfig) Policy {
+	return Policy{
+		ClosedAfter: time.Duration(cfg.ClosedAfterDays) * 24 * time.Hour,
+	}
+}
+
+// Cutoff returns the time a case must have been closed before to be archived
+func (p Policy) Cutoff(now time.Time) time.Time {
+	return now.Add(-p.ClosedAfter)
+}
+
+// Eligible reports whether a case has been closed long enough to be archived
+func (p Policy) Eligible(investigation *models.Investigation, now time.Time) bool {
+	if investigation.Status != models.StatusClosed || investigation.ClosedAt == nil {
+		return false
+	}
+	return investigation.ClosedAt.Before(p.Cutoff(now))
+}
+
+// ManifestEntry is the storage reference and status of one evidence item of
+// an archived case
+type ManifestEntry struct {
+	EvidenceID uuid.UUID             `json:"evidence_id"`
+	Status     models.EvidenceStatus `json:"status"`
+	FilePath   *string               `json:"file_path,omitempty"`
+	FileHash   *string               `json:"file_hash,omitempty"`
+	FileSize   *int64                `json:"file_size,omitempty"`
+}
+
+// EncodeManifest compresses the evidence references of a case and returns
+// them with the hex SHA-256 of the compressed manifest
+func EncodeManifest(evidence []models.Evidence) ([]byte, string, error) {
+	entries := make([]ManifestEntry, 0, len(evidence))
+	for _, item := range evidence {
+		entries = append(entries, ManifestEntry{
+			EvidenceID: item.ID,
+			Status:     item.Status,
+			FilePath:   item.FilePath,
+			FileHash:   item.FileHash,
+			FileSize:   item.FileSize,
+		})
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(writer).Encode(entries); err != nil {
+		return nil, "", errors.Wrap(err, "failed to encode evidence manifest")
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", errors.Wrap(err, "failed to compress evidence manifest")
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), hex.EncodeToString(sum[:]), nil
+}
+
+// DecodeManifest verifies a compressed evidence manifest against its
+// checksum and reads its entries
+func DecodeManifest(manifest []byte, checksum string) ([]ManifestEntry, error) {
+	sum := sha256.Sum256(manifest)
+	if hex.EncodeToString(sum[:]) != checksum {
+		return nil, ErrManifestCorrupt
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(manifest))
+	if err != nil {
+		return nil, errors.Wrap(ErrManifestCorrupt, err.Error())
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(ErrManifestCorrupt, err.Error())
+	}
+
+	var entries []ManifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Wrap(ErrManifestCorrupt, err.Error())
+	}
+	return entries, nil
+}
@@ -0,0 +1,316 @@
+package archival
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+	"investigation-toolkit/internal/residency"
+	"investigation-toolkit/internal/search"
+)
+
+var (
+	// ErrReasonRequired is returned when archiving or re-opening a case without a reason
+	ErrReasonRequired = errors.New("a reason is required")
+	// ErrCaseArchived is returned when changing a case that is archived
+	ErrCaseArchived = errors.New("investigation is archived; re-open it to make changes")
+)
+
+// Store persists case archives and re-opens
+type Store interface {
+	GetInvestigation(ctx context.Context, id uuid.UUID) (*models.Investigation, error)
+	ListArchivable(ctx context.Context, closedBefore time.Time, limit int) ([]models.Investigation, error)
+	ListCaseEvidence(ctx context.Context, investigationID uuid.UUID) ([]models.Evidence, error)
+	Archive(ctx context.Context, archive *models.CaseArchive, evidenceIDs []uuid.UUID) error
+	ActiveArchive(ctx context.Context, investigationID uuid.UUID) (*models.CaseArchive, error)
+	Reopen(ctx context.Context, archive *models.CaseArchive, evidenceStatuses map[uuid.UUID]models.EvidenceStatus) error
+	ListArchives(ctx context.Context, investigationID uuid.UUID) ([]models.CaseArchive, error)
+	IsArchived(ctx context.Context, investigationID uuid.UUID) (bool, error)
+	GetEvidenceCase(ctx context.Context, evidenceID uuid.UUID) (uuid.UUID, error)
+	GetSARFilingCase(ctx context.Context, filingID uuid.UUID) (uuid.UUID, error)
+}
+
+// Indexer writes case documents to the search indices
+type Indexer interface {
+	Index(ctx context.Context, index, id string, document interface{}) error
+	Delete(ctx context.Context, index, id string) error
+}
+
+// SweepResult summarizes one archival sweep
+type SweepResult struct {
+	Archived int `json:"archived"`
+	Failed   int `json:"failed"`
+}
+
+// Service moves cases that have been closed for a while to the archive,
+// where they are left out of default case lists and search and cannot be
+// changed, and re-opens them on request
+type Service struct {
+	store   Store
+	indexer Indexer
+	policy  Policy
+	config  config.ArchivalConfig
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+// NewService creates a new case archival service. indexer may be nil when
+// search is disabled.
+func NewService(store Store, indexer Indexer, cfg config.ArchivalConfig, logger *zap.Logger) *Service {
+	return &Service{
+		store:   store,
+		indexer: indexer,
+		policy:  NewPolicy(cfg),
+		config:  cfg,
+		logger:  logger.Named("archival"),
+		now:     time.Now,
+	}
+}
+
+// Run archives aged closed cases until the context is cancelled
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.Sweep(ctx)
+			if err != nil {
+				s.logger.Error("Failed to sweep closed cases", zap.Error(err))
+				continue
+			}
+			if result.Archived > 0 || result.Failed > 0 {
+				s.logger.Info("Case archival sweep completed",
+					zap.Int("archived", result.Archived),
+					zap.Int("failed", result.Failed))
+			}
+		}
+	}
+}
+
+// Sweep archives one batch of cases closed longer than the configured age
+func (s *Service) Sweep(ctx context.Context) (*SweepResult, error) {
+	result := &SweepResult{}
+	now := s.now()
+
+	due, err := s.store.ListArchivable(ctx, s.policy.Cutoff(now), s.config.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := fmt.Sprintf("closed more than %d days", s.config.ClosedAfterDays)
+	for i := range due {
+		investigation := &due[i]
+		if !s.policy.Eligible(investigation, now) {
+			continue
+		}
+
+		if _, err := s.archive(ctx, investigation, nil, reason); err != nil {
+			if errors.Is(err, repository.ErrCaseNotClosed) {
+				// Re-opened or archived since it was listed
+				continue
+			}
+			s.logger.Error("Failed to archive closed case",
+				zap.String("investigation_id", investigation.ID.String()),
+				zap.Error(err))
+			result.Failed++
+			continue
+		}
+		result.Archived++
+	}
+
+	return result, nil
+}
+
+// Archive moves a closed case to the archive ahead of the sweep
+func (s *Service) Archive(ctx context.Context, investigationID, userID uuid.UUID, reason string) (*models.CaseArchive, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	investigation, err := s.store.GetInvestigation(ctx, investigationID)
+	if err != nil {
+		return nil, err
+	}
+	if investigation.Status != models.StatusClosed || investigation.ClosedAt == nil {
+		return nil, repository.ErrCaseNotClosed
+	}
+
+	return s.archive(ctx, investigation, &userID, reason)
+}
+
+func (s *Service) archive(ctx context.Context, investigation *models.Investigation, userID *uuid.UUID, reason string) (*models.CaseArchive, error) {
+	evidence, err := s.store.ListCaseEvidence(ctx, investigation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, checksum, err := EncodeManifest(evidence)
+	if err != nil {
+		return nil, err
+	}
+
+	evidenceIDs := make([]uuid.UUID, 0, len(evidence))
+	for _, item := range evidence {
+		evidenceIDs = append(evidenceIDs, item.ID)
+	}
+
+	archive := &models.CaseArchive{
+		InvestigationID:  investigation.ID,
+		ArchivedBy:       userID,
+		ArchiveReason:    reason,
+		ClosedAt:         *investigation.ClosedAt,
+		EvidenceCount:    len(evidence),
+		EvidenceManifest: manifest,
+		ManifestSHA256:   checksum,
+	}
+	if err := s.store.Archive(ctx, archive, evidenceIDs); err != nil {
+		return nil, err
+	}
+
+	s.unindex(ctx, investigation.ID, evidenceIDs)
+
+	s.logger.Info("Case archived",
+		zap.String("investigation_id", investigation.ID.String()),
+		zap.Int("evidence", len(evidence)),
+		zap.String("reason", reason))
+	return archive, nil
+}
+
+// Reopen returns an archived case to work with its evidence restored to the
+// statuses it had when it was archived, and puts it back in search
+func (s *Service) Reopen(ctx context.Context, investigationID, userID uuid.UUID, reason string) (*models.Investigation, *models.CaseArchive, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, nil, ErrReasonRequired
+	}
+
+	archive, err := s.store.ActiveArchive(ctx, investigationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries, err := DecodeManifest(archive.EvidenceManifest, archive.ManifestSHA256)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "archive %s", archive.ID)
+	}
+
+	statuses := make(map[uuid.UUID]models.EvidenceStatus, len(entries))
+	for _, entry := range entries {
+		statuses[entry.EvidenceID] = entry.Status
+	}
+
+	archive.ReopenedBy = &userID
+	archive.ReopenReason = &reason
+	if err := s.store.Reopen(ctx, archive, statuses); err != nil {
+		return nil, nil, err
+	}
+
+	investigation, err := s.store.GetInvestigation(ctx, investigationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.reindex(ctx, investigation)
+
+	s.logger.Info("Case re-opened",
+		zap.String("investigation_id", investigationID.String()),
+		zap.String("reopened_by", userID.String()),
+		zap.String("reason", reason))
+	return investigation, archive, nil
+}
+
+// Archives lists every time a case was archived and re-opened, most recent first
+func (s *Service) Archives(ctx context.Context, investigationID uuid.UUID) ([]models.CaseArchive, error) {
+	if _, err := s.store.GetInvestigation(ctx, investigationID); err != nil {
+		return nil, err
+	}
+	return s.store.ListArchives(ctx, investigationID)
+}
+
+// Manifest reads the evidence references a case was archived with
+func (s *Service) Manifest(archive *models.CaseArchive) ([]ManifestEntry, error) {
+	return DecodeManifest(archive.EvidenceManifest, archive.ManifestSHA256)
+}
+
+// IsArchived reports whether the case a scoped request addresses is archived
+func (s *Service) IsArchived(ctx context.Context, scope residency.Scope) (bool, error) {
+	investigationID := scope.ID
+	var err error
+
+	switch scope.Kind {
+	case residency.ScopeCase:
+	case residency.ScopeEvidence:
+		investigationID, err = s.store.GetEvidenceCase(ctx, scope.ID)
+	case residency.ScopeSARFiling:
+		investigationID, err = s.store.GetSARFilingCase(ctx, scope.ID)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return s.store.IsArchived(ctx, investigationID)
+}
+
+// unindex removes an archived case and its evidence from search. Failures
+// are logged rather than returned; the case is archived either way and the
+// next reindex leaves it out.
+func (s *Service) unindex(ctx context.Context, investigationID uuid.UUID, evidenceIDs []uuid.UUID) {
+	if s.indexer == nil {
+		return
+	}
+
+	if err := s.indexer.Delete(ctx, search.IndexInvestigations, investigationID.String()); err != nil {
+		s.logger.Warn("Failed to remove archived case from search",
+			zap.String("investigation_id", investigationID.String()),
+			zap.Error(err))
+	}
+	for _, evidenceID := range evidenceIDs {
+		if err := s.indexer.Delete(ctx, search.IndexEvidence, evidenceID.String()); err != nil {
+			s.logger.Warn("Failed to remove archived evidence from search",
+				zap.String("evidence_id", evidenceID.String()),
+				zap.Error(err))
+		}
+	}
+}
+
+// reindex puts a re-opened case and its evidence back in search
+func (s *Service) reindex(ctx context.Context, investigation *models.Investigation) {
+	if s.indexer == nil {
+		return
+	}
+
+	if err := s.indexer.Index(ctx, search.IndexInvestigations, investigation.ID.String(), investigation); err != nil {
+		s.logger.Warn("Failed to index re-opened case",
+			zap.String("investigation_id", investigation.ID.String()),
+			zap.Error(err))
+	}
+
+	evidence, err := s.store.ListCaseEvidence(ctx, investigation.ID)
+	if err != nil {
+		s.logger.Warn("Failed to list evidence of re-opened case",
+			zap.String("investigation_id", investigation.ID.String()),
+			zap.Error(err))
+		return
+	}
+	for i := range evidence {
+		if err := s.indexer.Index(ctx, search.IndexEvidence, evidence[i].ID.String(), &evidence[i]); err != nil {
+			s.logger.Warn("Failed to index evidence of re-opened case",
+				zap.String("evidence_id", evidence[i].ID.String()),
+				zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"investigation-toolkit/internal/models"
+)
+
+const (
+	icalDateTime = "20060102T150405Z"
+	icalDate     = "20060102"
+	// maxLineOctets is the RFC 5545 content line limit before folding
+	maxLineOctets = 75
+)
+
+// BuildFeed renders events as an RFC 5545 iCalendar document suitable for
+// calendar clients subscribing to a user's feed
+func BuildFeed(name, domain string, events []models.CalendarEvent, stamp time.Time) []byte {
+	var b strings.Builder
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:-//AegisShield//Investigation Toolkit//EN")
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	writeLine(&b, "X-WR-CALNAME:"+escapeText(name))
+	writeLine(&b, "X-PUBLISHED-TTL:PT15M")
+
+	for i := range events {
+		writeEvent(&b, &events[i], domain, stamp)
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+func writeEvent(b *strings.Builder, event *models.CalendarEvent, domain string, stamp time.Time) {
+	writeLine(b, "BEGIN:VEVENT")
+	writeLine(b, fmt.Sprintf("UID:%s@%s", event.ID, domain))
+	writeLine(b, "DTSTAMP:"+stamp.UTC().Format(icalDateTime))
+
+	if event.AllDay {
+		writeLine(b, "DTSTART;VALUE=DATE:"+event.StartsAt.UTC().Format(icalDate))
+		writeLine(b, "DTEND;VALUE=DATE:"+event.EndsAt.UTC().Format(icalDate))
+	} else {
+		writeLine(b, "DTSTART:"+event.StartsAt.UTC().Format(icalDateTime))
+		writeLine(b, "DTEND:"+event.EndsAt.UTC().Format(icalDateTime))
+	}
+
+	writeLine(b, "SUMMARY:"+escapeText(fmt.Sprintf("[%s] %s", eventTypeLabel(event.EventType), event.Title)))
+
+	description := fmt.Sprintf("Investigation: %s", event.InvestigationID)
+	if event.Description != nil && *event.Description != "" {
+		description = *event.Description + "\n\n" + description
+	}
+	writeLine(b, "DESCRIPTION:"+escapeText(description))
+
+	if event.Location != nil && *event.Location != "" {
+		writeLine(b, "LOCATION:"+escapeText(*event.Location))
+	}
+
+	writeLine(b, "CATEGORIES:"+escapeText(strings.ToUpper(string(event.EventType))))
+	writeLine(b, "STATUS:"+icalStatus(event.Status))
+	writeLine(b, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+	if !event.UpdatedAt.IsZero() {
+		writeLine(b, "LAST-MODIFIED:"+event.UpdatedAt.UTC().Format(icalDateTime))
+	}
+
+	if event.Status == models.CalendarEventStatusScheduled {
+		for _, minutes := range event.ReminderMinutes {
+			if minutes < 0 {
+				continue
+			}
+			writeLine(b, "BEGIN:VALARM")
+			writeLine(b, "ACTION:DISPLAY")
+			writeLine(b, "DESCRIPTION:"+escapeText(event.Title))
+			writeLine(b, fmt.Sprintf("TRIGGER:-PT%dM", minutes))
+			writeLine(b, "END:VALARM")
+		}
+	}
+
+	writeLine(b, "END:VEVENT")
+}
+
+func icalStatus(status models.CalendarEventStatus) string {
+	if status == models.CalendarEventStatusCancelled {
+		return "CANCELLED"
+	}
+	return "CONFIRMED"
+}
+
+// escapeText escapes a TEXT property value per RFC 5545 section 3.3.11
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(s)
+}
+
+// writeLine writes a content line, folding it at 75 octets without
+// splitting a UTF-8 sequence
+func writeLine(b *strings.Builder, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts toward the limit
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
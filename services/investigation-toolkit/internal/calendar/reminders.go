@@ -0,0 +1,131 @@
+package calendar
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+)
+
+// ReminderDispatcher periodically delivers due calendar reminders through
+// the collaboration notification system
+type ReminderDispatcher struct {
+	calendarRepo      *repository.CalendarRepository
+	collaborationRepo repository.CollaborationRepository
+	interval          time.Duration
+	batchSize         int
+	logger            *zap.Logger
+}
+
+// NewReminderDispatcher creates a new reminder dispatcher
+func NewReminderDispatcher(
+	calendarRepo *repository.CalendarRepository,
+	collaborationRepo repository.CollaborationRepository,
+	interval time.Duration,
+	batchSize int,
+	logger *zap.Logger,
+) *ReminderDispatcher {
+	return &ReminderDispatcher{
+		calendarRepo:      calendarRepo,
+		collaborationRepo: collaborationRepo,
+		interval:          interval,
+		batchSize:         batchSize,
+		logger:            logger.Named("calendar_reminders"),
+	}
+}
+
+// Run dispatches reminders until the context is cancelled
+func (d *ReminderDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.DispatchDue(ctx); err != nil {
+				d.logger.Error("Failed to dispatch calendar reminders", zap.Error(err))
+			}
+		}
+	}
+}
+
+// DispatchDue sends every reminder that has come due and returns how many were delivered
+func (d *ReminderDispatcher) DispatchDue(ctx context.Context) (int, error) {
+	reminders, err := d.calendarRepo.ClaimDueReminders(ctx, d.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	events := make(map[uuid.UUID]*models.CalendarEvent)
+	sent := 0
+	now := time.Now()
+
+	for i := range reminders {
+		reminder := &reminders[i]
+
+		event, ok := events[reminder.EventID]
+		if !ok {
+			event, err = d.calendarRepo.GetByID(ctx, reminder.EventID)
+			if err != nil {
+				d.logger.Error("Failed to load calendar event for reminder",
+					zap.String("reminder_id", reminder.ID.String()),
+					zap.Error(err))
+				d.release(ctx, reminder)
+				continue
+			}
+			events[reminder.EventID] = event
+		}
+
+		// Reminders claimed late, after the event is over, are dropped
+		if event.Status != models.CalendarEventStatusScheduled || now.After(event.EndsAt) {
+			continue
+		}
+
+		title, message := ReminderMessage(event, reminder.MinutesBefore)
+		notification := &models.NotificationEvent{
+			UserID:     reminder.UserID,
+			Type:       "calendar_reminder",
+			Title:      title,
+			Message:    message,
+			EntityType: "calendar_event",
+			EntityID:   event.ID,
+			Metadata: map[string]interface{}{
+				"investigation_id": event.InvestigationID.String(),
+				"event_type":       string(event.EventType),
+				"starts_at":        event.StartsAt,
+				"minutes_before":   reminder.MinutesBefore,
+			},
+			IsRead: false,
+		}
+
+		if err := d.collaborationRepo.CreateNotification(ctx, notification); err != nil {
+			d.logger.Error("Failed to send calendar reminder",
+				zap.String("reminder_id", reminder.ID.String()),
+				zap.String("user_id", reminder.UserID.String()),
+				zap.Error(err))
+			d.release(ctx, reminder)
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		d.logger.Info("Calendar reminders sent", zap.Int("count", sent))
+	}
+
+	return sent, nil
+}
+
+func (d *ReminderDispatcher) release(ctx context.Context, reminder *models.CalendarReminder) {
+	if err := d.calendarRepo.ReleaseReminder(ctx, reminder.ID); err != nil {
+		d.logger.Error("Failed to release calendar reminder",
+			zap.String("reminder_id", reminder.ID.String()),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,212 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"investigation-toolkit/internal/models"
+)
+
+// Normalize validates an event's time range and aligns all-day events to
+// whole UTC days, with an exclusive end as iCal expects. Events without an
+// end, such as filing deadlines, become a single instant.
+func Normalize(event *models.CalendarEvent) error {
+	if event.StartsAt.IsZero() {
+		return fmt.Errorf("starts_at is required")
+	}
+
+	if event.EndsAt.IsZero() {
+		event.EndsAt = event.StartsAt
+	}
+
+	if event.AllDay {
+		start := truncateDay(event.StartsAt)
+		end := truncateDay(event.EndsAt)
+		if !end.After(start) || !event.EndsAt.Equal(end) {
+			end = end.AddDate(0, 0, 1)
+		}
+		event.StartsAt, event.EndsAt = start, end
+	}
+
+	if event.EndsAt.Before(event.StartsAt) {
+		return fmt.Errorf("ends_at cannot be before starts_at")
+	}
+
+	return nil
+}
+
+// Overlaps reports whether two time ranges share any instant. Ranges are
+// half-open so back-to-back events do not clash, while a zero-length event
+// such as a deadline overlaps any range containing it.
+func Overlaps(aStart, aEnd, bStart, bEnd time.Time) bool {
+	aPoint, bPoint := aStart.Equal(aEnd), bStart.Equal(bEnd)
+
+	switch {
+	case aPoint && bPoint:
+		return aStart.Equal(bStart)
+	case aPoint:
+		return !aStart.Before(bStart) && aStart.Before(bEnd)
+	case bPoint:
+		return !bStart.Before(aStart) && bStart.Before(aEnd)
+	default:
+		return aStart.Before(bEnd) && bStart.Before(aEnd)
+	}
+}
+
+// Participants returns the organizer and attendees of an event without duplicates
+func Participants(event *models.CalendarEvent) []uuid.UUID {
+	seen := map[uuid.UUID]bool{event.OrganizerID: true}
+	participants := []uuid.UUID{event.OrganizerID}
+
+	for _, attendee := range event.Attendees {
+		if attendee == uuid.Nil || seen[attendee] {
+			continue
+		}
+		seen[attendee] = true
+		participants = append(participants, attendee)
+	}
+
+	return participants
+}
+
+// FindConflicts returns the scheduled events that overlap the candidate and
+// share at least one participant with it
+func FindConflicts(candidate *models.CalendarEvent, existing []models.CalendarEvent) []models.CalendarConflict {
+	candidateUsers := make(map[uuid.UUID]bool)
+	for _, user := range Participants(candidate) {
+		candidateUsers[user] = true
+	}
+
+	var conflicts []models.CalendarConflict
+	for i := range existing {
+		event := &existing[i]
+		if event.ID == candidate.ID || event.Status != models.CalendarEventStatusScheduled {
+			continue
+		}
+		if !Overlaps(candidate.StartsAt, candidate.EndsAt, event.StartsAt, event.EndsAt) {
+			continue
+		}
+
+		var shared []uuid.UUID
+		for _, user := range Participants(event) {
+			if candidateUsers[user] {
+				shared = append(shared, user)
+			}
+		}
+		if len(shared) == 0 {
+			continue
+		}
+
+		conflicts = append(conflicts, models.CalendarConflict{
+			EventID:         event.ID,
+			InvestigationID: event.InvestigationID,
+			EventType:       event.EventType,
+			Title:           event.Title,
+			StartsAt:        event.StartsAt,
+			EndsAt:          event.EndsAt,
+			Users:           shared,
+		})
+	}
+
+	return conflicts
+}
+
+// ReminderSchedule builds the reminders each participant is owed, skipping
+// any whose time has already passed
+func ReminderSchedule(event *models.CalendarEvent, now time.Time) []models.CalendarReminder {
+	if event.Status != models.CalendarEventStatusScheduled {
+		return nil
+	}
+
+	var reminders []models.CalendarReminder
+	seen := make(map[int64]bool)
+	for _, minutes := range event.ReminderMinutes {
+		if minutes < 0 || seen[minutes] {
+			continue
+		}
+		seen[minutes] = true
+
+		remindAt := event.StartsAt.Add(-time.Duration(minutes) * time.Minute)
+		if !remindAt.After(now) {
+			continue
+		}
+
+		for _, user := range Participants(event) {
+			reminders = append(reminders, models.CalendarReminder{
+				ID:            uuid.New(),
+				EventID:       event.ID,
+				UserID:        user,
+				MinutesBefore: int(minutes),
+				RemindAt:      remindAt,
+				Status:        models.CalendarReminderStatusPending,
+				CreatedAt:     now,
+			})
+		}
+	}
+
+	return reminders
+}
+
+// ReminderMessage renders the notification title and body for a reminder
+func ReminderMessage(event *models.CalendarEvent, minutesBefore int) (string, string) {
+	label := eventTypeLabel(event.EventType)
+
+	title := fmt.Sprintf("%s reminder", label)
+	if event.EventType == models.CalendarEventTypeFilingDeadline {
+		title = "Filing deadline approaching"
+	}
+
+	when := event.StartsAt.UTC().Format("Mon 2 Jan 2006 15:04 MST")
+	if event.AllDay {
+		when = event.StartsAt.UTC().Format("Mon 2 Jan 2006")
+	}
+
+	message := fmt.Sprintf("%s \"%s\" is %s (%s)", label, event.Title, leadTime(minutesBefore), when)
+	if event.Location != nil && *event.Location != "" {
+		message += " at " + *event.Location
+	}
+
+	return title, message
+}
+
+func leadTime(minutes int) string {
+	switch {
+	case minutes <= 0:
+		return "starting now"
+	case minutes%1440 == 0:
+		return "in " + plural(minutes/1440, "day")
+	case minutes%60 == 0:
+		return "in " + plural(minutes/60, "hour")
+	default:
+		return "in " + plural(minutes, "minute")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+func eventTypeLabel(eventType models.CalendarEventType) string {
+	switch eventType {
+	case models.CalendarEventTypeInterview:
+		return "Interview"
+	case models.CalendarEventTypeFilingDeadline:
+		return "Filing deadline"
+	case models.CalendarEventTypeCourtDate:
+		return "Court date"
+	case models.CalendarEventTypeMeeting:
+		return "Meeting"
+	default:
+		return "Event"
+	}
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,159 @@
+package classification
+
+import (
+	"github.com/pkg/errors"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+)
+
+var (
+	// ErrUnknownLevel is returned for classifications outside Levels
+	ErrUnknownLevel = errors.New("unknown evidence classification")
+	// ErrInvalidParticipant is returned for participants that could not be added
+	ErrInvalidParticipant = errors.New("invalid participant")
+)
+
+// Levels are the evidence classifications, from least to most sensitive
+var Levels = []models.EvidenceClassification{
+	models.ClassificationPublic,
+	models.ClassificationInternal,
+	models.ClassificationConfidential,
+	models.ClassificationRestricted,
+}
+
+// participantRoles are the roles a user can be added to a case in
+var participantRoles = map[models.Role]bool{
+	models.RoleLeadInvestigator: true,
+	models.RoleInvestigator:     true,
+	models.RoleAnalyst:          true,
+	models.RoleReviewer:         true,
+	models.RoleObserver:         true,
+	models.RoleConsultant:       true,
+	models.RoleExternalCounsel:  true,
+}
+
+// Rank returns a classification's position in Levels
+func Rank(level models.EvidenceClassification) (int, bool) {
+	for i, l := range Levels {
+		if l == level {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// CanSee reports whether a participant cleared to clearance may see evidence
+// classified at level
+func CanSee(clearance, level models.EvidenceClassification) bool {
+	cleared, ok := Rank(clearance)
+	if !ok {
+		return false
+	}
+	rank, ok := Rank(level)
+	return ok && rank <= cleared
+}
+
+// Visible returns the classifications a participant cleared to clearance may see
+func Visible(clearance models.EvidenceClassification) []models.EvidenceClassification {
+	cleared, ok := Rank(clearance)
+	if !ok {
+		return []models.EvidenceClassification{}
+	}
+	return append([]models.EvidenceClassification(nil), Levels[:cleared+1]...)
+}
+
+// Access is what a participant may see of a case's evidence
+type Access struct {
+	Restricted bool                            `json:"restricted"`
+	Clearance  *models.EvidenceClassification  `json:"clearance,omitempty"`
+	Visible    []models.EvidenceClassification `json:"visible_classifications"`
+}
+
+// Policy decides which participants are restricted and what they may see
+type Policy struct {
+	DefaultLevel models.EvidenceClassification
+	restricted   map[models.Role]models.EvidenceClassification
+}
+
+// NewPolicy builds a classification policy from configuration
+func NewPolicy(cfg config.ClassificationConfig) (Policy, error) {
+	policy := Policy{
+		DefaultLevel: models.EvidenceClassification(cfg.DefaultLevel),
+		restricted:   make(map[models.Role]models.EvidenceClassification, len(cfg.RestrictedRoles)),
+	}
+	if _, ok := Rank(policy.DefaultLevel); !ok {
+		return Policy{}, errors.Wrapf(ErrUnknownLevel, "default level %q", cfg.DefaultLevel)
+	}
+
+	for role, clearance := range cfg.RestrictedRoles {
+		if !participantRoles[models.Role(role)] {
+			return Policy{}, errors.Errorf("restricted role %q is not a participant role", role)
+		}
+		level := models.EvidenceClassification(clearance)
+		if _, ok := Rank(level); !ok {
+			return Policy{}, errors.Wrapf(ErrUnknownLevel, "clearance %q of restricted role %s", clearance, role)
+		}
+		policy.restricted[models.Role(role)] = level
+	}
+
+	return policy, nil
+}
+
+// Restricted reports whether participants in a role are limited by clearance
+func (p Policy) Restricted(role models.Role) bool {
+	_, ok := p.restricted[role]
+	return ok
+}
+
+// ClassificationFor returns the classification new evidence is given: the
+// one requested, or the default level
+func (p Policy) ClassificationFor(requested *models.EvidenceClassification) (models.EvidenceClassification, error) {
+	if requested == nil || *requested == "" {
+		return p.DefaultLevel, nil
+	}
+	if _, ok := Rank(*requested); !ok {
+		return "", errors.Wrap(ErrUnknownLevel, string(*requested))
+	}
+	return *requested, nil
+}
+
+// ClearanceFor returns the clearance a participant in a role is given: the
+// one requested, or the role's default. Unrestricted roles see everything
+// and take no clearance.
+func (p Policy) ClearanceFor(role models.Role, requested *models.EvidenceClassification) (*models.EvidenceClassification, error) {
+	if !participantRoles[role] {
+		return nil, errors.Wrapf(ErrInvalidParticipant, "unknown role %q", role)
+	}
+
+	defaultClearance, restricted := p.restricted[role]
+	if !restricted {
+		if requested != nil {
+			return nil, errors.Wrapf(ErrInvalidParticipant, "role %s is not restricted and takes no clearance", role)
+		}
+		return nil, nil
+	}
+
+	if requested == nil || *requested == "" {
+		return &defaultClearance, nil
+	}
+	if _, ok := Rank(*requested); !ok {
+		return nil, errors.Wrap(ErrUnknownLevel, string(*requested))
+	}
+	clearance := *requested
+	return &clearance, nil
+}
+
+// AccessOf returns what a participant may see. Restricted participants
+// without a recorded clearance see nothing above public.
+func (p Policy) AccessOf(participant *models.Collaboration) Access {
+	if !p.Restricted(participant.Role) {
+		return Access{Visible: append([]models.EvidenceClassification(nil), Levels...)}
+	}
+
+	clearance := models.ClassificationPublic
+	if participant.Clearance != nil {
+		clearance = *participant.Clearance
+	}
+	return Access{Restricted: true, Clearance: &clearance, Visible: Visible(clearance)}
+}
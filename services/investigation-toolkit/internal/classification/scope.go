@@ -0,0 +1,53 @@
+package classification
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// RestrictedRouteAllowed reports whether restricted participants may use an
+// /api/v1 route. They may read a case they participate in, list its
+// evidence and read or download the evidence they are cleared for; every
+// other route, including those addressing no single case, is closed to them.
+func RestrictedRouteAllowed(method, path string) bool {
+	if method != http.MethodGet {
+		return false
+	}
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/v1"), "/"), "/")
+	switch {
+	case len(segments) == 2 && segments[0] == "investigations":
+		return isID(segments[1])
+	case len(segments) == 3 && segments[0] == "evidence" && segments[1] == "investigation":
+		return isID(segments[2])
+	case len(segments) >= 2 && segments[0] == "evidence" && isID(segments[1]):
+		rest := segments[2:]
+		return len(rest) == 0 ||
+			(len(rest) == 1 && rest[0] == "content") ||
+			(len(rest) == 2 && rest[0] == "files")
+	}
+	return false
+}
+
+func isID(segment string) bool {
+	_, err := uuid.Parse(segment)
+	return err == nil
+}
+
+type accessKey struct{}
+
+// WithAccess returns a context carrying a restricted participant's access,
+// which evidence lists filter by
+func WithAccess(ctx context.Context, access Access) context.Context {
+	return context.WithValue(ctx, accessKey{}, access)
+}
+
+// AccessFromContext returns the restricted participant access a request was
+// admitted with, if any
+func AccessFromContext(ctx context.Context) (Access, bool) {
+	access, ok := ctx.Value(accessKey{}).(Access)
+	return access, ok
+}
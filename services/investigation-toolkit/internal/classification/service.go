@@ -0,0 +1,300 @@
+package classification
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/residency"
+)
+
+// ErrInvalidChange is returned for reclassifications that change nothing or give no reason
+var ErrInvalidChange = errors.New("invalid classification change")
+
+// Store persists case participants and evidence classifications
+type Store interface {
+	CaseExists(ctx context.Context, investigationID uuid.UUID) error
+	AddParticipant(ctx context.Context, participant *models.Collaboration) error
+	RemoveParticipant(ctx context.Context, investigationID, userID, removedBy uuid.UUID) error
+	ListParticipants(ctx context.Context, investigationID uuid.UUID) ([]models.Collaboration, error)
+	ActiveParticipant(ctx context.Context, investigationID, userID uuid.UUID) (*models.Collaboration, error)
+	GetEvidenceClassification(ctx context.Context, evidenceID uuid.UUID) (uuid.UUID, models.EvidenceClassification, error)
+	Classify(ctx context.Context, change *models.EvidenceClassificationChange) error
+	ListClassificationChanges(ctx context.Context, evidenceID uuid.UUID) ([]models.EvidenceClassificationChange, error)
+	ListCaseEvidenceClassifications(ctx context.Context, investigationID uuid.UUID) ([]models.Evidence, error)
+}
+
+// Decision is the outcome of checking a request against a participant's clearance
+type Decision struct {
+	Allowed bool
+	// Hidden is set when the request addresses evidence above the
+	// participant's clearance, whose existence is not disclosed
+	Hidden bool
+	Reason string
+	// Access is set for restricted participants whose request was allowed
+	Access *Access
+}
+
+// Service manages case participants and evidence classifications and
+// limits restricted participants, such as external counsel, to the
+// evidence they are cleared for
+type Service struct {
+	store  Store
+	policy Policy
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewService creates a new classification service
+func NewService(store Store, cfg config.ClassificationConfig, logger *zap.Logger) (*Service, error) {
+	policy, err := NewPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		store:  store,
+		policy: policy,
+		logger: logger.Named("classification"),
+		now:    time.Now,
+	}, nil
+}
+
+// Policy returns the classification policy of this deployment
+func (s *Service) Policy() Policy {
+	return s.policy
+}
+
+// AddParticipant adds a user to a case. Restricted roles are given the
+// requested clearance or their default one.
+func (s *Service) AddParticipant(ctx context.Context, investigationID uuid.UUID, req *models.AddParticipantRequest, assignedBy uuid.UUID) (*models.Collaboration, error) {
+	if req.UserID == uuid.Nil {
+		return nil, errors.Wrap(ErrInvalidParticipant, "user_id is required")
+	}
+
+	clearance, err := s.policy.ClearanceFor(req.Role, req.Clearance)
+	if err != nil {
+		return nil, err
+	}
+
+	participant := &models.Collaboration{
+		InvestigationID: investigationID,
+		UserID:          req.UserID,
+		Role:            req.Role,
+		AssignedBy:      assignedBy,
+		Notes:           req.Notes,
+		Clearance:       clearance,
+	}
+	if err := s.store.AddParticipant(ctx, participant); err != nil {
+		return nil, err
+	}
+
+	fields := []zap.Field{
+		zap.String("investigation_id", investigationID.String()),
+		zap.String("user_id", req.UserID.String()),
+		zap.String("role", string(req.Role)),
+	}
+	if clearance != nil {
+		fields = append(fields, zap.String("clearance", string(*clearance)))
+	}
+	s.logger.Info("Participant added", fields...)
+
+	return participant, nil
+}
+
+// RemoveParticipant ends a user's participation in a case
+func (s *Service) RemoveParticipant(ctx context.Context, investigationID, userID, removedBy uuid.UUID) error {
+	if err := s.store.RemoveParticipant(ctx, investigationID, userID, removedBy); err != nil {
+		return err
+	}
+
+	s.logger.Info("Participant removed",
+		zap.String("investigation_id", investigationID.String()),
+		zap.String("user_id", userID.String()))
+	return nil
+}
+
+// Participants lists the active participants of a case
+func (s *Service) Participants(ctx context.Context, investigationID uuid.UUID) ([]models.Collaboration, error) {
+	if err := s.store.CaseExists(ctx, investigationID); err != nil {
+		return nil, err
+	}
+	return s.store.ListParticipants(ctx, investigationID)
+}
+
+// Classify changes an evidence item's classification, recording who changed
+// it and why
+func (s *Service) Classify(ctx context.Context, evidenceID uuid.UUID, req *models.ClassifyEvidenceRequest, changedBy uuid.UUID) (*models.EvidenceClassificationChange, error) {
+	if _, ok := Rank(req.Classification); !ok {
+		return nil, errors.Wrap(ErrUnknownLevel, string(req.Classification))
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errors.Wrap(ErrInvalidChange, "reason is required")
+	}
+
+	_, current, err := s.store.GetEvidenceClassification(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+	if current == req.Classification {
+		return nil, errors.Wrapf(ErrInvalidChange, "evidence is already %s", current)
+	}
+
+	change := &models.EvidenceClassificationChange{
+		EvidenceID:         evidenceID,
+		FromClassification: current,
+		ToClassification:   req.Classification,
+		Reason:             reason,
+		ChangedBy:          changedBy,
+	}
+	if err := s.store.Classify(ctx, change); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Evidence reclassified",
+		zap.String("evidence_id", evidenceID.String()),
+		zap.String("from", string(current)),
+		zap.String("to", string(req.Classification)))
+
+	return change, nil
+}
+
+// History lists an evidence item's reclassifications, oldest first
+func (s *Service) History(ctx context.Context, evidenceID uuid.UUID) ([]models.EvidenceClassificationChange, error) {
+	if _, _, err := s.store.GetEvidenceClassification(ctx, evidenceID); err != nil {
+		return nil, err
+	}
+	return s.store.ListClassificationChanges(ctx, evidenceID)
+}
+
+// Check decides whether a user may make a request for scoped case data.
+// restrictedSubject is set when the user's token carries a restricted role;
+// such users are limited to a few read routes on the cases they participate
+// in. Any user taking part in a case in a restricted role is limited to the
+// evidence their clearance covers there.
+func (s *Service) Check(ctx context.Context, scope residency.Scope, userID *uuid.UUID, restrictedSubject bool, method, path string) (*Decision, error) {
+	if !RestrictedRouteAllowed(method, path) {
+		if restrictedSubject {
+			return &Decision{Reason: "Route is not available to restricted participants"}, nil
+		}
+		return &Decision{Allowed: true}, nil
+	}
+
+	var (
+		investigationID uuid.UUID
+		level           models.EvidenceClassification
+		err             error
+	)
+	switch scope.Kind {
+	case residency.ScopeCase:
+		investigationID = scope.ID
+	case residency.ScopeEvidence:
+		investigationID, level, err = s.store.GetEvidenceClassification(ctx, scope.ID)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unsupported classification scope %q", scope.Kind)
+	}
+
+	var participant *models.Collaboration
+	if userID != nil {
+		participant, err = s.store.ActiveParticipant(ctx, investigationID, *userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if participant == nil {
+		if restrictedSubject {
+			return &Decision{Reason: "Not a participant in this investigation"}, nil
+		}
+		return &Decision{Allowed: true}, nil
+	}
+
+	access := s.policy.AccessOf(participant)
+	if !access.Restricted {
+		if restrictedSubject {
+			// A restricted token never sees more than a restricted role would
+			clearance := models.ClassificationPublic
+			access = Access{Restricted: true, Clearance: &clearance, Visible: Visible(clearance)}
+		} else {
+			return &Decision{Allowed: true}, nil
+		}
+	}
+
+	if scope.Kind == residency.ScopeEvidence && !CanSee(*access.Clearance, level) {
+		return &Decision{Hidden: true, Reason: "Evidence is above the participant's clearance"}, nil
+	}
+
+	return &Decision{Allowed: true, Access: &access}, nil
+}
+
+// AccessMatrix reports, for every participant of a case, which evidence
+// classifications and items they can see
+func (s *Service) AccessMatrix(ctx context.Context, investigationID uuid.UUID) (*models.AccessMatrix, error) {
+	if err := s.store.CaseExists(ctx, investigationID); err != nil {
+		return nil, err
+	}
+
+	participants, err := s.store.ListParticipants(ctx, investigationID)
+	if err != nil {
+		return nil, err
+	}
+	evidence, err := s.store.ListCaseEvidenceClassifications(ctx, investigationID)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := &models.AccessMatrix{
+		InvestigationID: investigationID,
+		GeneratedAt:     s.now(),
+		Levels:          make([]models.ClassificationCount, len(Levels)),
+		Participants:    make([]models.ParticipantAccess, 0, len(participants)),
+		Evidence:        make([]models.EvidenceAccess, 0, len(evidence)),
+	}
+	for i, level := range Levels {
+		matrix.Levels[i].Classification = level
+	}
+	for _, item := range evidence {
+		if rank, ok := Rank(item.Classification); ok {
+			matrix.Levels[rank].Evidence++
+		}
+		matrix.Evidence = append(matrix.Evidence, models.EvidenceAccess{
+			EvidenceID:     item.ID,
+			Name:           item.Name,
+			Classification: item.Classification,
+			HiddenFrom:     []uuid.UUID{},
+		})
+	}
+
+	for i := range participants {
+		participant := &participants[i]
+		access := s.policy.AccessOf(participant)
+		row := models.ParticipantAccess{
+			UserID:     participant.UserID,
+			Role:       participant.Role,
+			Restricted: access.Restricted,
+			Clearance:  access.Clearance,
+			Visible:    access.Visible,
+		}
+		for j := range matrix.Evidence {
+			item := &matrix.Evidence[j]
+			if !access.Restricted || CanSee(*access.Clearance, item.Classification) {
+				row.VisibleEvidence++
+				continue
+			}
+			row.HiddenEvidence++
+			item.HiddenFrom = append(item.HiddenFrom, participant.UserID)
+		}
+		matrix.Participants = append(matrix.Participants, row)
+	}
+
+	return matrix, nil
+}
@@ -10,18 +10,28 @@ import (
 
 // Config holds the configuration for the investigation toolkit service
 type Config struct {
-	Environment string         `yaml:"environment"`
-	Debug       bool           `yaml:"debug"`
-	Server      ServerConfig   `yaml:"server"`
-	Database    DatabaseConfig `yaml:"database"`
-	Neo4j       Neo4jConfig    `yaml:"neo4j"`
-	Kafka       KafkaConfig    `yaml:"kafka"`
-	Redis       RedisConfig    `yaml:"redis"`
-	Storage     StorageConfig  `yaml:"storage"`
-	Search      SearchConfig   `yaml:"search"`
-	Auth        AuthConfig     `yaml:"auth"`
-	Workflow    WorkflowConfig `yaml:"workflow"`
-	Audit       AuditConfig    `yaml:"audit"`
+	Environment      string                `yaml:"environment"`
+	Debug            bool                  `yaml:"debug"`
+	Server           ServerConfig          `yaml:"server"`
+	Database         DatabaseConfig        `yaml:"database"`
+	Neo4j            Neo4jConfig           `yaml:"neo4j"`
+	Kafka            KafkaConfig           `yaml:"kafka"`
+	Redis            RedisConfig           `yaml:"redis"`
+	Storage          StorageConfig         `yaml:"storage"`
+	Search           SearchConfig          `yaml:"search"`
+	Auth             AuthConfig            `yaml:"auth"`
+	Workflow         WorkflowConfig        `yaml:"workflow"`
+	Audit            AuditConfig           `yaml:"audit"`
+	EvidenceRequests EvidenceRequestConfig `yaml:"evidence_requests"`
+	Calendar         CalendarConfig        `yaml:"calendar"`
+	Tiering          TieringConfig         `yaml:"tiering"`
+	Traceability     TraceabilityConfig    `yaml:"traceability"`
+	Residency        ResidencyConfig       `yaml:"residency"`
+	Classification   ClassificationConfig  `yaml:"classification"`
+	Archival         ArchivalConfig        `yaml:"archival"`
+	Production       ProductionConfig      `yaml:"production"`
+	Quota            QuotaConfig           `yaml:"quota"`
+	OfflineSync      OfflineSyncConfig     `yaml:"offline_sync"`
 }
 
 // ServerConfig contains HTTP and gRPC server settings
@@ -211,6 +221,12 @@ type SearchConfig struct {
 	EnableMetrics        bool          `yaml:"enable_metrics"`
 	IndexPrefix          string        `yaml:"index_prefix"`
 	IndexSettings        map[string]interface{} `yaml:"index_settings"`
+	Enabled              bool          `yaml:"enabled"`
+	ReindexPollInterval  time.Duration `yaml:"reindex_poll_interval"`
+	ReindexStateRefresh  time.Duration `yaml:"reindex_state_refresh"` // how often writers reload which indices are being rebuilt
+	RetiredWarmAfter     time.Duration `yaml:"retired_warm_after"`
+	RetiredColdAfter     time.Duration `yaml:"retired_cold_after"`
+	RetiredDeleteAfter   time.Duration `yaml:"retired_delete_after"` // 0 keeps retired indices in the cold tier
 }
 
 // AuthConfig contains authentication settings
@@ -226,6 +242,17 @@ type AuthConfig struct {
 	SessionTimeout   time.Duration `yaml:"session_timeout"`
 	MaxSessions      int           `yaml:"max_sessions"`
 	EnableAuditLog   bool          `yaml:"enable_audit_log"`
+	// RBAC enforces the shared role policy on /api/v1 routes. Checks run
+	// against PolicyServiceAddr when set, otherwise against a local policy.
+	EnableRBAC          bool          `yaml:"enable_rbac"`
+	RBACPolicyFile      string        `yaml:"rbac_policy_file"`
+	RBACCacheTTL        time.Duration `yaml:"rbac_cache_ttl"`
+	RBACCacheMaxEntries int           `yaml:"rbac_cache_max_entries"`
+	PolicyServiceAddr   string        `yaml:"policy_service_addr"`
+	// UserServiceAddr validates tokens against user-management's session
+	// store over gRPC when set, so revoked sessions are rejected; otherwise
+	// only the token signature is checked
+	UserServiceAddr string `yaml:"user_service_addr"`
 }
 
 // OAuth2Config contains OAuth2 settings
@@ -315,6 +342,146 @@ type AuditConfig struct {
 	MaxPayloadSize      int           `yaml:"max_payload_size"`
 }
 
+// EvidenceRequestConfig contains settings for external evidence request links
+type EvidenceRequestConfig struct {
+	PublicBaseURL    string        `yaml:"public_base_url"`
+	DefaultExpiry    time.Duration `yaml:"default_expiry"`
+	MaxExpiry        time.Duration `yaml:"max_expiry"`
+	DefaultMaxFiles  int           `yaml:"default_max_files"`
+	MaxFileSize      int64         `yaml:"max_file_size"`
+	AllowedFileTypes []string      `yaml:"allowed_file_types"`
+	ScanEnabled      bool          `yaml:"scan_enabled"`
+	ClamAVAddress    string        `yaml:"clamav_address"`
+	ScanTimeout      time.Duration `yaml:"scan_timeout"`
+	SweepInterval    time.Duration `yaml:"sweep_interval"`
+}
+
+// CalendarConfig contains settings for case calendars, reminders and iCal feeds
+type CalendarConfig struct {
+	PublicBaseURL          string        `yaml:"public_base_url"`
+	FeedDomain             string        `yaml:"feed_domain"`
+	FeedPastWindow         time.Duration `yaml:"feed_past_window"`
+	FeedFutureWindow       time.Duration `yaml:"feed_future_window"`
+	DefaultReminderMinutes []int         `yaml:"default_reminder_minutes"`
+	MaxReminders           int           `yaml:"max_reminders"`
+	ReminderInterval       time.Duration `yaml:"reminder_interval"`
+	ReminderBatchSize      int           `yaml:"reminder_batch_size"`
+}
+
+// TieringConfig contains lifecycle tiering settings for stored evidence files
+type TieringConfig struct {
+	Enabled             bool          `yaml:"enabled"`
+	InfrequentAfterDays int           `yaml:"infrequent_after_days"`
+	ArchiveAfterDays    int           `yaml:"archive_after_days"`
+	RestoreDelay        time.Duration `yaml:"restore_delay"`
+	RestoredRetention   time.Duration `yaml:"restored_retention"`
+	SweepInterval       time.Duration `yaml:"sweep_interval"`
+	BatchSize           int           `yaml:"batch_size"`
+}
+
+// TraceabilityConfig contains settings for SAR traceability validation
+type TraceabilityConfig struct {
+	// AlertingEngineURL is the alerting engine's HTTP API, used to resolve
+	// the alerts a SAR cites and the cases they are linked to
+	AlertingEngineURL string        `yaml:"alerting_engine_url"`
+	AlertTimeout      time.Duration `yaml:"alert_timeout"`
+	AlertConcurrency  int           `yaml:"alert_concurrency"`
+	// RequireCompleteChain refuses to mark a SAR filed while its chain has
+	// broken links
+	RequireCompleteChain bool `yaml:"require_complete_chain"`
+	MaxReportDays        int  `yaml:"max_report_days"`
+}
+
+// ResidencyConfig contains per-case data residency settings for multi-region
+// deployments. Case records are shared; each case is pinned to a region and
+// its evidence files and exports are written to that region's storage.
+type ResidencyConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// LocalRegion is the region this deployment serves. Requests for cases
+	// pinned to another region need a residency grant.
+	LocalRegion string `yaml:"local_region"`
+	// DefaultRegion is given to cases created without a region; it defaults
+	// to the local region
+	DefaultRegion string `yaml:"default_region"`
+	// EvidenceLocations and ExportLocations map each region to the storage
+	// root (local path or mounted bucket) its files are written under
+	EvidenceLocations    map[string]string `yaml:"evidence_locations"`
+	ExportLocations      map[string]string `yaml:"export_locations"`
+	MaxGrantDuration     time.Duration     `yaml:"max_grant_duration"`
+	ReportViolationLimit int               `yaml:"report_violation_limit"`
+}
+
+// ClassificationConfig contains evidence classification settings. Case
+// participants in a restricted role only see evidence classified at or below
+// their clearance.
+type ClassificationConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// DefaultLevel is given to evidence added without a classification
+	DefaultLevel string `yaml:"default_level"`
+	// RestrictedRoles maps each restricted participant role to the clearance
+	// its participants get when they are added without one
+	RestrictedRoles map[string]string `yaml:"restricted_roles"`
+}
+
+// ArchivalConfig contains settings for archiving closed cases. Archived cases
+// are left out of default case lists and search, are read-only, and come back
+// through the re-open workflow.
+type ArchivalConfig struct {
+	Enabled         bool          `yaml:"enabled"`
+	ClosedAfterDays int           `yaml:"closed_after_days"`
+	SweepInterval   time.Duration `yaml:"sweep_interval"`
+	BatchSize       int           `yaml:"batch_size"`
+}
+
+// ProductionConfig contains settings for producing case artifacts to
+// law-enforcement agencies
+type ProductionConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// TemplatesFile optionally holds further production templates as a JSON
+	// array, in addition to the built-in ones
+	TemplatesFile string `yaml:"templates_file"`
+	// RedactionText replaces redacted values in produced files
+	RedactionText string `yaml:"redaction_text"`
+}
+
+// QuotaConfig contains per-tenant and per-user evidence storage limits.
+// Limits of zero are unlimited; limits set through the quota API replace
+// these defaults for one tenant or user.
+type QuotaConfig struct {
+	Enabled          bool   `yaml:"enabled"`
+	TenantHeader     string `yaml:"tenant_header"`
+	DefaultTenant    string `yaml:"default_tenant"`
+	TenantLimitBytes int64  `yaml:"tenant_limit_bytes"`
+	TenantLimitFiles int64  `yaml:"tenant_limit_files"`
+	UserLimitBytes   int64  `yaml:"user_limit_bytes"`
+	UserLimitFiles   int64  `yaml:"user_limit_files"`
+	// Percentages of a limit at which uploaders are warned and at which
+	// uploads are refused
+	WarningPercent  float64 `yaml:"warning_percent"`
+	HardStopPercent float64 `yaml:"hard_stop_percent"`
+	// Quota reserved for an upload is returned if the upload neither
+	// completes nor fails within the TTL
+	ReservationTTL time.Duration `yaml:"reservation_ttl"`
+	SweepInterval  time.Duration `yaml:"sweep_interval"`
+}
+
+// OfflineSyncConfig contains settings for syncing evidence collected offline
+// on field devices. Chunks are staged until their session completes;
+// sessions left open past the TTL expire and their chunks are removed.
+type OfflineSyncConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	StagingPath string `yaml:"staging_path"`
+	ChunkSize   int64  `yaml:"chunk_size"`
+	MaxItems    int    `yaml:"max_items"`
+	MaxFileSize int64  `yaml:"max_file_size"`
+	// ClockSkew is how far ahead of the server a device clock may run when
+	// its custody timestamps are checked against the offline window
+	ClockSkew        time.Duration `yaml:"clock_skew"`
+	MaxOfflineWindow time.Duration `yaml:"max_offline_window"`
+	SessionTTL       time.Duration `yaml:"session_ttl"`
+	SweepInterval    time.Duration `yaml:"sweep_interval"`
+}
+
 // Load reads configuration from environment variables
 func Load() (*Config, error) {
 	cfg := &Config{
@@ -444,6 +611,12 @@ func Load() (*Config, error) {
 			EnableGzip:           getBoolEnv("ELASTICSEARCH_ENABLE_GZIP", true),
 			EnableMetrics:        getBoolEnv("ELASTICSEARCH_ENABLE_METRICS", true),
 			IndexPrefix:          getEnv("ELASTICSEARCH_INDEX_PREFIX", "investigation-toolkit"),
+			Enabled:              getBoolEnv("ELASTICSEARCH_ENABLED", false),
+			ReindexPollInterval:  getDurationEnv("ELASTICSEARCH_REINDEX_POLL_INTERVAL", 5*time.Second),
+			ReindexStateRefresh:  getDurationEnv("ELASTICSEARCH_REINDEX_STATE_REFRESH", 10*time.Second),
+			RetiredWarmAfter:     getDurationEnv("ELASTICSEARCH_RETIRED_WARM_AFTER", 24*time.Hour),
+			RetiredColdAfter:     getDurationEnv("ELASTICSEARCH_RETIRED_COLD_AFTER", 7*24*time.Hour),
+			RetiredDeleteAfter:   getDurationEnv("ELASTICSEARCH_RETIRED_DELETE_AFTER", 90*24*time.Hour),
 		},
 
 		Auth: AuthConfig{
@@ -455,6 +628,12 @@ func Load() (*Config, error) {
 			SessionTimeout: getDurationEnv("AUTH_SESSION_TIMEOUT", 8*time.Hour),
 			MaxSessions:    getIntEnv("AUTH_MAX_SESSIONS", 5),
 			EnableAuditLog: getBoolEnv("AUTH_ENABLE_AUDIT_LOG", true),
+			EnableRBAC:          getBoolEnv("AUTH_ENABLE_RBAC", false),
+			RBACPolicyFile:      getEnv("RBAC_POLICY_FILE", ""),
+			RBACCacheTTL:        getDurationEnv("RBAC_CACHE_TTL", time.Minute),
+			RBACCacheMaxEntries: getIntEnv("RBAC_CACHE_MAX_ENTRIES", 10000),
+			PolicyServiceAddr:   getEnv("RBAC_POLICY_SERVICE_ADDR", ""),
+			UserServiceAddr:     getEnv("USER_SERVICE_ADDR", ""),
 		},
 
 		Workflow: WorkflowConfig{
@@ -505,6 +684,109 @@ func Load() (*Config, error) {
 			IncludeResponseBody: getBoolEnv("AUDIT_INCLUDE_RESPONSE_BODY", false),
 			MaxPayloadSize:      getIntEnv("AUDIT_MAX_PAYLOAD_SIZE", 10240), // 10KB
 		},
+
+		EvidenceRequests: EvidenceRequestConfig{
+			PublicBaseURL:    getEnv("EVIDENCE_REQUEST_PUBLIC_BASE_URL", "http://localhost:8080"),
+			DefaultExpiry:    getDurationEnv("EVIDENCE_REQUEST_DEFAULT_EXPIRY", 7*24*time.Hour),
+			MaxExpiry:        getDurationEnv("EVIDENCE_REQUEST_MAX_EXPIRY", 30*24*time.Hour),
+			DefaultMaxFiles:  getIntEnv("EVIDENCE_REQUEST_DEFAULT_MAX_FILES", 10),
+			MaxFileSize:      getInt64Env("EVIDENCE_REQUEST_MAX_FILE_SIZE", 25*1024*1024), // 25MB
+			AllowedFileTypes: getStringSliceEnv("EVIDENCE_REQUEST_ALLOWED_FILE_TYPES", []string{"pdf", "jpg", "jpeg", "png", "tiff", "csv", "xlsx", "docx"}),
+			ScanEnabled:      getBoolEnv("EVIDENCE_REQUEST_SCAN_ENABLED", true),
+			ClamAVAddress:    getEnv("CLAMAV_ADDRESS", "localhost:3310"),
+			ScanTimeout:      getDurationEnv("EVIDENCE_REQUEST_SCAN_TIMEOUT", 60*time.Second),
+			SweepInterval:    getDurationEnv("EVIDENCE_REQUEST_SWEEP_INTERVAL", 15*time.Minute),
+		},
+
+		Calendar: CalendarConfig{
+			PublicBaseURL:          getEnv("CALENDAR_PUBLIC_BASE_URL", "http://localhost:8080"),
+			FeedDomain:             getEnv("CALENDAR_FEED_DOMAIN", "aegisshield"),
+			FeedPastWindow:         getDurationEnv("CALENDAR_FEED_PAST_WINDOW", 90*24*time.Hour),
+			FeedFutureWindow:       getDurationEnv("CALENDAR_FEED_FUTURE_WINDOW", 365*24*time.Hour),
+			DefaultReminderMinutes: getIntSliceEnv("CALENDAR_DEFAULT_REMINDER_MINUTES", []int{1440, 60}), // 1 day, 1 hour
+			MaxReminders:           getIntEnv("CALENDAR_MAX_REMINDERS", 5),
+			ReminderInterval:       getDurationEnv("CALENDAR_REMINDER_INTERVAL", time.Minute),
+			ReminderBatchSize:      getIntEnv("CALENDAR_REMINDER_BATCH_SIZE", 100),
+		},
+
+		Tiering: TieringConfig{
+			Enabled:             getBoolEnv("TIERING_ENABLED", false),
+			InfrequentAfterDays: getIntEnv("TIERING_INFREQUENT_AFTER_DAYS", 30),
+			ArchiveAfterDays:    getIntEnv("TIERING_ARCHIVE_AFTER_DAYS", 180),
+			RestoreDelay:        getDurationEnv("TIERING_RESTORE_DELAY", 4*time.Hour),
+			RestoredRetention:   getDurationEnv("TIERING_RESTORED_RETENTION", 7*24*time.Hour),
+			SweepInterval:       getDurationEnv("TIERING_SWEEP_INTERVAL", time.Hour),
+			BatchSize:           getIntEnv("TIERING_BATCH_SIZE", 500),
+		},
+
+		Traceability: TraceabilityConfig{
+			AlertingEngineURL:    getEnv("TRACEABILITY_ALERTING_ENGINE_URL", "http://alerting-engine:8080"),
+			AlertTimeout:         getDurationEnv("TRACEABILITY_ALERT_TIMEOUT", 10*time.Second),
+			AlertConcurrency:     getIntEnv("TRACEABILITY_ALERT_CONCURRENCY", 8),
+			RequireCompleteChain: getBoolEnv("TRACEABILITY_REQUIRE_COMPLETE_CHAIN", true),
+			MaxReportDays:        getIntEnv("TRACEABILITY_MAX_REPORT_DAYS", 366),
+		},
+
+		Residency: ResidencyConfig{
+			Enabled:              getBoolEnv("RESIDENCY_ENABLED", false),
+			LocalRegion:          getEnv("RESIDENCY_LOCAL_REGION", ""),
+			DefaultRegion:        getEnv("RESIDENCY_DEFAULT_REGION", ""),
+			EvidenceLocations:    getStringMapEnv("RESIDENCY_EVIDENCE_LOCATIONS", map[string]string{}),
+			ExportLocations:      getStringMapEnv("RESIDENCY_EXPORT_LOCATIONS", map[string]string{}),
+			MaxGrantDuration:     getDurationEnv("RESIDENCY_MAX_GRANT_DURATION", 7*24*time.Hour),
+			ReportViolationLimit: getIntEnv("RESIDENCY_REPORT_VIOLATION_LIMIT", 500),
+		},
+
+		Classification: ClassificationConfig{
+			Enabled:      getBoolEnv("CLASSIFICATION_ENABLED", true),
+			DefaultLevel: getEnv("CLASSIFICATION_DEFAULT_LEVEL", "internal"),
+			RestrictedRoles: getStringMapEnv("CLASSIFICATION_RESTRICTED_ROLES", map[string]string{
+				"external_counsel": "internal",
+			}),
+		},
+
+		Archival: ArchivalConfig{
+			Enabled:         getBoolEnv("ARCHIVAL_ENABLED", true),
+			ClosedAfterDays: getIntEnv("ARCHIVAL_CLOSED_AFTER_DAYS", 180),
+			SweepInterval:   getDurationEnv("ARCHIVAL_SWEEP_INTERVAL", time.Hour),
+			BatchSize:       getIntEnv("ARCHIVAL_BATCH_SIZE", 100),
+		},
+
+		Production: ProductionConfig{
+			Enabled:       getBoolEnv("PRODUCTION_ENABLED", true),
+			TemplatesFile: getEnv("PRODUCTION_TEMPLATES_FILE", ""),
+			RedactionText: getEnv("PRODUCTION_REDACTION_TEXT", "[REDACTED]"),
+		},
+
+		Quota: QuotaConfig{
+			Enabled:          getBoolEnv("QUOTA_ENABLED", true),
+			TenantHeader:     getEnv("QUOTA_TENANT_HEADER", "X-Tenant-ID"),
+			DefaultTenant:    getEnv("QUOTA_DEFAULT_TENANT", "default"),
+			TenantLimitBytes: getInt64Env("QUOTA_TENANT_LIMIT_BYTES", 1<<40), // 1TB
+			TenantLimitFiles: getInt64Env("QUOTA_TENANT_LIMIT_FILES", 0),
+			UserLimitBytes:   getInt64Env("QUOTA_USER_LIMIT_BYTES", 100<<30), // 100GB
+			UserLimitFiles:   getInt64Env("QUOTA_USER_LIMIT_FILES", 0),
+			WarningPercent:   getFloatEnv("QUOTA_WARNING_PERCENT", 80),
+			HardStopPercent:  getFloatEnv("QUOTA_HARD_STOP_PERCENT", 100),
+			ReservationTTL:   getDurationEnv("QUOTA_RESERVATION_TTL", 30*time.Minute),
+			SweepInterval:    getDurationEnv("QUOTA_SWEEP_INTERVAL", 5*time.Minute),
+		},
+
+		OfflineSync: OfflineSyncConfig{
+			Enabled:          getBoolEnv("OFFLINE_SYNC_ENABLED", true),
+			StagingPath:      getEnv("OFFLINE_SYNC_STAGING_PATH", "./storage/offline-sync"),
+			ChunkSize:        getInt64Env("OFFLINE_SYNC_CHUNK_SIZE", 8*1024*1024), // 8MB
+			MaxItems:         getIntEnv("OFFLINE_SYNC_MAX_ITEMS", 500),
+			MaxFileSize:      getInt64Env("OFFLINE_SYNC_MAX_FILE_SIZE", 2<<30), // 2GB
+			ClockSkew:        getDurationEnv("OFFLINE_SYNC_CLOCK_SKEW", 5*time.Minute),
+			MaxOfflineWindow: getDurationEnv("OFFLINE_SYNC_MAX_OFFLINE_WINDOW", 90*24*time.Hour),
+			SessionTTL:       getDurationEnv("OFFLINE_SYNC_SESSION_TTL", 7*24*time.Hour),
+			SweepInterval:    getDurationEnv("OFFLINE_SYNC_SWEEP_INTERVAL", time.Hour),
+		},
+	}
+
+	if cfg.Residency.DefaultRegion == "" {
+		cfg.Residency.DefaultRegion = cfg.Residency.LocalRegion
 	}
 
 	// Load S3 configuration if provider is s3
@@ -555,6 +837,101 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("S3 bucket is required when using S3 storage provider")
 	}
 
+	if c.EvidenceRequests.DefaultExpiry > c.EvidenceRequests.MaxExpiry {
+		return fmt.Errorf("evidence request default expiry cannot exceed max expiry")
+	}
+
+	if c.EvidenceRequests.SweepInterval <= 0 {
+		return fmt.Errorf("evidence request sweep interval must be positive")
+	}
+
+	if c.Calendar.ReminderInterval <= 0 {
+		return fmt.Errorf("calendar reminder interval must be positive")
+	}
+
+	if c.Traceability.MaxReportDays <= 0 {
+		return fmt.Errorf("traceability max report days must be positive")
+	}
+
+	if c.Tiering.Enabled {
+		if c.Tiering.InfrequentAfterDays <= 0 {
+			return fmt.Errorf("tiering infrequent access age must be positive")
+		}
+		if c.Tiering.ArchiveAfterDays <= c.Tiering.InfrequentAfterDays {
+			return fmt.Errorf("tiering archive age must be greater than the infrequent access age")
+		}
+		if c.Tiering.SweepInterval <= 0 {
+			return fmt.Errorf("tiering sweep interval must be positive")
+		}
+	}
+
+	if c.Archival.Enabled {
+		if c.Archival.ClosedAfterDays <= 0 {
+			return fmt.Errorf("archival closed case age must be positive")
+		}
+		if c.Archival.SweepInterval <= 0 {
+			return fmt.Errorf("archival sweep interval must be positive")
+		}
+		if c.Archival.BatchSize <= 0 {
+			return fmt.Errorf("archival batch size must be positive")
+		}
+	}
+
+	if c.Production.Enabled && c.Production.RedactionText == "" {
+		return fmt.Errorf("production redaction text is required")
+	}
+
+	if c.Quota.Enabled {
+		if c.Quota.TenantHeader == "" || c.Quota.DefaultTenant == "" {
+			return fmt.Errorf("quota tenant header and default tenant are required")
+		}
+		if c.Quota.TenantLimitBytes < 0 || c.Quota.TenantLimitFiles < 0 || c.Quota.UserLimitBytes < 0 || c.Quota.UserLimitFiles < 0 {
+			return fmt.Errorf("quota limits must not be negative")
+		}
+		if c.Quota.WarningPercent <= 0 || c.Quota.WarningPercent >= c.Quota.HardStopPercent {
+			return fmt.Errorf("quota warning percent must be positive and below the hard stop percent")
+		}
+		if c.Quota.ReservationTTL <= 0 || c.Quota.SweepInterval <= 0 {
+			return fmt.Errorf("quota reservation TTL and sweep interval must be positive")
+		}
+	}
+
+	if c.OfflineSync.Enabled {
+		if c.OfflineSync.StagingPath == "" {
+			return fmt.Errorf("offline sync staging path is required")
+		}
+		if c.OfflineSync.ChunkSize <= 0 || c.OfflineSync.MaxFileSize <= 0 || c.OfflineSync.MaxItems <= 0 {
+			return fmt.Errorf("offline sync chunk size, max file size and max items must be positive")
+		}
+		if c.OfflineSync.MaxOfflineWindow <= 0 || c.OfflineSync.SessionTTL <= 0 || c.OfflineSync.SweepInterval <= 0 {
+			return fmt.Errorf("offline sync max offline window, session TTL and sweep interval must be positive")
+		}
+		if c.OfflineSync.ClockSkew < 0 {
+			return fmt.Errorf("offline sync clock skew must not be negative")
+		}
+	}
+
+	if c.Residency.Enabled {
+		if err := c.Residency.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Search.Enabled {
+		if len(c.Search.Addresses) == 0 {
+			return fmt.Errorf("search requires at least one Elasticsearch address")
+		}
+		if c.Search.ReindexPollInterval <= 0 || c.Search.ReindexStateRefresh <= 0 {
+			return fmt.Errorf("search reindex poll interval and state refresh must be positive")
+		}
+		if c.Search.RetiredColdAfter <= c.Search.RetiredWarmAfter {
+			return fmt.Errorf("retired search indices must move to the cold tier after the warm tier")
+		}
+		if c.Search.RetiredDeleteAfter > 0 && c.Search.RetiredDeleteAfter <= c.Search.RetiredColdAfter {
+			return fmt.Errorf("retired search indices must be deleted after moving to the cold tier")
+		}
+	}
+
 	if c.Auth.JWTSecret == "change-me-in-production" && c.Environment == "production" {
 		return fmt.Errorf("JWT secret must be changed in production")
 	}
@@ -562,6 +939,27 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+func (r ResidencyConfig) validate() error {
+	if r.LocalRegion == "" {
+		return fmt.Errorf("residency requires a local region")
+	}
+	if _, ok := r.EvidenceLocations[r.LocalRegion]; !ok {
+		return fmt.Errorf("residency local region %s has no evidence location", r.LocalRegion)
+	}
+	if _, ok := r.EvidenceLocations[r.DefaultRegion]; !ok {
+		return fmt.Errorf("residency default region %s has no evidence location", r.DefaultRegion)
+	}
+	for region := range r.EvidenceLocations {
+		if _, ok := r.ExportLocations[region]; !ok {
+			return fmt.Errorf("residency region %s has no export location", region)
+		}
+	}
+	if r.MaxGrantDuration <= 0 {
+		return fmt.Errorf("residency max grant duration must be positive")
+	}
+	return nil
+}
+
 // Helper functions for environment variable parsing
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -620,4 +1018,35 @@ func getStringSliceEnv(key string, defaultValue []string) []string {
 		return strings.Split(value, ",")
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func getIntSliceEnv(key string, defaultValue []int) []int {
+	if value := os.Getenv(key); value != "" {
+		var values []int
+		for _, part := range strings.Split(value, ",") {
+			parsed, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return defaultValue
+			}
+			values = append(values, parsed)
+		}
+		return values
+	}
+	return defaultValue
+}
+
+// getStringMapEnv parses comma separated key=value pairs
+func getStringMapEnv(key string, defaultValue map[string]string) map[string]string {
+	if value := os.Getenv(key); value != "" {
+		values := make(map[string]string)
+		for _, pair := range strings.Split(value, ",") {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				return defaultValue
+			}
+			values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		return values
+	}
+	return defaultValue
+}
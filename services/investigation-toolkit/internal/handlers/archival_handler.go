@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/archival"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+	"investigation-toolkit/internal/residency"
+)
+
+// ArchivalMiddleware makes archived cases read-only. Changes to an archived
+// case, its evidence or its SAR filings are rejected until the case is
+// re-opened. External routes are skipped; their handlers check the case.
+func ArchivalMiddleware(service *archival.Service, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if isExternalRoute(path) || strings.HasSuffix(path, "/reopen") {
+			c.Next()
+			return
+		}
+
+		scope := residency.ScopeOf(path)
+		if scope.Kind == residency.ScopeNone {
+			c.Next()
+			return
+		}
+
+		archived, err := service.IsArchived(c.Request.Context(), scope)
+		if err != nil {
+			if errors.Is(err, repository.ErrCaseNotFound) {
+				// Handlers report missing records
+				c.Next()
+				return
+			}
+			logger.Error("Archive check failed", zap.String("path", path), zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Archive check failed"})
+			return
+		}
+		if archived {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": archival.ErrCaseArchived.Error()})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ArchivalHandler handles archiving closed cases and re-opening them
+type ArchivalHandler struct {
+	service *archival.Service
+	logger  *zap.Logger
+}
+
+// NewArchivalHandler creates a new archival handler
+func NewArchivalHandler(service *archival.Service, logger *zap.Logger) *ArchivalHandler {
+	return &ArchivalHandler{
+		service: service,
+		logger:  logger.Named("archival_handler"),
+	}
+}
+
+// ArchiveCase moves a closed case to the archive without waiting for the sweep
+func (h *ArchivalHandler) ArchiveCase(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	var req models.ArchiveCaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	archive, err := h.service.Archive(c.Request.Context(), investigationID, userID, req.Reason)
+	if err != nil {
+		h.handleError(c, err, "Failed to archive investigation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, archive)
+}
+
+// ReopenCase returns an archived case to work
+func (h *ArchivalHandler) ReopenCase(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	var req models.ReopenCaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	investigation, archive, err := h.service.Reopen(c.Request.Context(), investigationID, userID, req.Reason)
+	if err != nil {
+		h.handleError(c, err, "Failed to re-open investigation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"investigation": investigation, "archive": archive})
+}
+
+// ListArchives lists the times a case was archived and re-opened
+func (h *ArchivalHandler) ListArchives(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	archives, err := h.service.Archives(c.Request.Context(), investigationID)
+	if err != nil {
+		h.handleError(c, err, "Failed to list case archives")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"archives": archives})
+}
+
+func (h *ArchivalHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrCaseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Investigation not found"})
+	case errors.Is(err, repository.ErrCaseNotClosed), errors.Is(err, repository.ErrCaseNotArchived):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, archival.ErrReasonRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (h *ArchivalHandler) requireUser(c *gin.Context) (uuid.UUID, bool) {
+	userID := requestUser(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID required"})
+		return uuid.Nil, false
+	}
+	return *userID, true
+}
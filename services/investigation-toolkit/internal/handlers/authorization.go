@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"aegisshield/shared/rbac"
+)
+
+// routeResources maps the first path segment under /api/v1 to the RBAC
+// resource it exposes
+var routeResources = map[string]string{
+	"investigations":    "investigations",
+	"timeline":          "investigations",
+	"calendar":          "investigations",
+	"collaboration":     "investigations",
+	"evidence":          "evidence",
+	"evidence-requests": "evidence",
+	"storage":           "evidence",
+	"offline-sync":      "evidence",
+	"workflows":         "workflows",
+	"audit":             "audit",
+	"sar-filings":       "sar",
+	"traceability":      "sar",
+	"residency":         "residency",
+	// Review questionnaires are part of case work
+	"questionnaire-templates": "investigations",
+	"questionnaires":          "investigations",
+	// Law-enforcement productions are regulatory disclosures
+	"production-templates": "sar",
+	"productions":          "sar",
+	// Storage quota administration is usage management
+	"quotas": "usage",
+}
+
+// AuthorizationMiddleware authenticates the bearer token and checks the
+// request against the shared RBAC policy. External routes are skipped; they
+// are authorized by the token in their URL.
+func AuthorizationMiddleware(checker rbac.Checker, authenticator rbac.Authenticator, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isExternalRoute(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		token, ok := rbac.BearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			return
+		}
+		subject, err := authenticator.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			if errors.Is(err, rbac.ErrInvalidToken) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+				return
+			}
+			logger.Error("Authentication failed", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Authentication failed"})
+			return
+		}
+
+		resource, action := RoutePermission(c.Request.Method, c.Request.URL.Path)
+		decision, err := checker.CheckPermission(c.Request.Context(), subject, resource, action)
+		if err != nil {
+			logger.Error("Permission check failed",
+				zap.String("resource", resource),
+				zap.String("action", action),
+				zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Permission check failed"})
+			return
+		}
+		if !decision.Allowed {
+			logger.Warn("Request denied by RBAC policy",
+				zap.String("user_id", subject.ID),
+				zap.String("resource", resource),
+				zap.String("action", action),
+				zap.String("path", c.Request.URL.Path))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		}
+
+		c.Request = c.Request.WithContext(rbac.WithSubject(c.Request.Context(), subject))
+		c.Next()
+	}
+}
+
+// RoutePermission returns the resource and action an /api/v1 request needs.
+// Evidence requests raised under an investigation are guarded as evidence,
+// SAR filings under an investigation as SARs, and an investigation's region
+// and residency grants as residency.
+func RoutePermission(method, path string) (string, string) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/v1"), "/"), "/")
+
+	resource, ok := routeResources[segments[0]]
+	if !ok {
+		resource = segments[0]
+	}
+	if segments[0] == "investigations" && len(segments) >= 3 && (segments[2] == "evidence-requests" || segments[2] == "offline-sync") {
+		resource = "evidence"
+	}
+	if segments[0] == "investigations" && len(segments) >= 3 && (segments[2] == "sar-filings" || segments[2] == "productions") {
+		resource = "sar"
+	}
+	if segments[0] == "investigations" && len(segments) >= 3 && (segments[2] == "region" || segments[2] == "residency-grants") {
+		resource = "residency"
+	}
+
+	return resource, rbac.ActionForMethod(method)
+}
+
+func isExternalRoute(path string) bool {
+	return strings.HasPrefix(path, "/api/v1/external/")
+}
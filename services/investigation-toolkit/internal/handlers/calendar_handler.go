@@ -0,0 +1,573 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/calendar"
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+)
+
+// maxReminderMinutes caps how far ahead of an event a reminder can be set (30 days)
+const maxReminderMinutes = 30 * 24 * 60
+
+// CalendarHandler handles case calendar events, conflict checks and iCal feeds
+type CalendarHandler struct {
+	calendarRepo *repository.CalendarRepository
+	config       config.CalendarConfig
+	logger       *zap.Logger
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(calendarRepo *repository.CalendarRepository, cfg *config.Config, logger *zap.Logger) *CalendarHandler {
+	return &CalendarHandler{
+		calendarRepo: calendarRepo,
+		config:       cfg.Calendar,
+		logger:       logger.Named("calendar_handler"),
+	}
+}
+
+// CreateEvent schedules an interview, deadline or court date on an investigation.
+// Overlaps with a participant's existing events are rejected with 409 unless
+// the caller sets allow_conflicts.
+func (h *CalendarHandler) CreateEvent(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	var req models.CreateCalendarEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if !validEventType(req.EventType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported event type: %s", req.EventType)})
+		return
+	}
+
+	reminderMinutes, err := h.resolveReminders(req.ReminderMinutes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	event := &models.CalendarEvent{
+		ID:              uuid.New(),
+		InvestigationID: investigationID,
+		EventType:       req.EventType,
+		Title:           req.Title,
+		Description:     req.Description,
+		Location:        req.Location,
+		StartsAt:        req.StartsAt,
+		AllDay:          req.AllDay,
+		OrganizerID:     userID,
+		Attendees:       models.UUIDArray(req.Attendees),
+		ReminderMinutes: reminderMinutes,
+		Status:          models.CalendarEventStatusScheduled,
+		Metadata:        req.Metadata,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if req.EndsAt != nil {
+		event.EndsAt = *req.EndsAt
+	}
+	if event.Attendees == nil {
+		event.Attendees = models.UUIDArray{}
+	}
+
+	if err := calendar.Normalize(event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conflicts, ok := h.checkConflicts(c, event, req.AllowConflicts)
+	if !ok {
+		return
+	}
+
+	if err := h.calendarRepo.Create(c.Request.Context(), event, calendar.ReminderSchedule(event, now)); err != nil {
+		h.logger.Error("Failed to create calendar event", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar event"})
+		return
+	}
+
+	h.logger.Info("Calendar event created",
+		zap.String("id", event.ID.String()),
+		zap.String("investigation_id", investigationID.String()),
+		zap.String("event_type", string(event.EventType)),
+		zap.Int("conflicts", len(conflicts)))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"event":     event,
+		"conflicts": conflicts,
+	})
+}
+
+// ListInvestigationEvents returns all calendar events for an investigation
+func (h *CalendarHandler) ListInvestigationEvents(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	events, err := h.calendarRepo.GetByInvestigationID(c.Request.Context(), investigationID)
+	if err != nil {
+		h.logger.Error("Failed to list calendar events", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list calendar events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events": events,
+		"total":  len(events),
+	})
+}
+
+// ListMyEvents returns the caller's events within a window, defaulting to the next 30 days
+func (h *CalendarHandler) ListMyEvents(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	from := time.Now()
+	to := from.Add(30 * 24 * time.Hour)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from timestamp"})
+			return
+		}
+		from = parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to timestamp"})
+			return
+		}
+		to = parsed
+	}
+
+	events, err := h.calendarRepo.GetForUser(c.Request.Context(), userID, from, to)
+	if err != nil {
+		h.logger.Error("Failed to list user calendar events", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list calendar events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events": events,
+		"total":  len(events),
+	})
+}
+
+// GetEvent returns a calendar event with its reminders
+func (h *CalendarHandler) GetEvent(c *gin.Context) {
+	event, ok := h.loadEvent(c)
+	if !ok {
+		return
+	}
+
+	reminders, err := h.calendarRepo.GetReminders(c.Request.Context(), event.ID)
+	if err != nil {
+		h.logger.Error("Failed to get calendar reminders", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get calendar event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"event":     event,
+		"reminders": reminders,
+	})
+}
+
+// UpdateEvent reschedules or edits an event. Only its participants may change it.
+func (h *CalendarHandler) UpdateEvent(c *gin.Context) {
+	event, ok := h.loadEvent(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCalendarEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireParticipant(c, event)
+	if !ok {
+		return
+	}
+
+	if event.Status == models.CalendarEventStatusCancelled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Calendar event is cancelled"})
+		return
+	}
+
+	if req.Title != nil {
+		event.Title = *req.Title
+	}
+	if req.Description != nil {
+		event.Description = req.Description
+	}
+	if req.Location != nil {
+		event.Location = req.Location
+	}
+	if req.AllDay != nil {
+		event.AllDay = *req.AllDay
+	}
+	if req.StartsAt != nil {
+		// Keep the duration when only the start moves
+		duration := event.EndsAt.Sub(event.StartsAt)
+		event.StartsAt = *req.StartsAt
+		event.EndsAt = req.StartsAt.Add(duration)
+	}
+	if req.EndsAt != nil {
+		event.EndsAt = *req.EndsAt
+	}
+	if req.Attendees != nil {
+		event.Attendees = models.UUIDArray(req.Attendees)
+	}
+	if req.ReminderMinutes != nil {
+		reminderMinutes, err := h.resolveReminders(req.ReminderMinutes)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		event.ReminderMinutes = reminderMinutes
+	}
+	if req.Metadata != nil {
+		event.Metadata = req.Metadata
+	}
+	if req.Status != nil {
+		switch *req.Status {
+		case models.CalendarEventStatusScheduled, models.CalendarEventStatusCompleted:
+			event.Status = *req.Status
+		case models.CalendarEventStatusCancelled:
+			h.markCancelled(event, userID)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported status: %s", *req.Status)})
+			return
+		}
+	}
+
+	if err := calendar.Normalize(event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var conflicts []models.CalendarConflict
+	if event.Status == models.CalendarEventStatusScheduled {
+		conflicts, ok = h.checkConflicts(c, event, req.AllowConflicts)
+		if !ok {
+			return
+		}
+	}
+
+	if err := h.calendarRepo.Update(c.Request.Context(), event, calendar.ReminderSchedule(event, time.Now())); err != nil {
+		h.logger.Error("Failed to update calendar event", zap.Error(err))
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"event":     event,
+		"conflicts": conflicts,
+	})
+}
+
+// CancelEvent cancels an event; subscribed calendars show it as cancelled
+func (h *CalendarHandler) CancelEvent(c *gin.Context) {
+	event, ok := h.loadEvent(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := h.requireParticipant(c, event)
+	if !ok {
+		return
+	}
+
+	if event.Status == models.CalendarEventStatusCancelled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Calendar event is already cancelled"})
+		return
+	}
+
+	h.markCancelled(event, userID)
+	if err := h.calendarRepo.Update(c.Request.Context(), event, nil); err != nil {
+		h.logger.Error("Failed to cancel calendar event", zap.Error(err))
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Calendar event cancelled",
+		zap.String("id", event.ID.String()),
+		zap.String("cancelled_by", userID.String()))
+
+	c.JSON(http.StatusOK, event)
+}
+
+// CheckConflicts reports existing events that would clash with a proposed slot
+func (h *CalendarHandler) CheckConflicts(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	startsAt, err := time.Parse(time.RFC3339, c.Query("starts_at"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid starts_at timestamp"})
+		return
+	}
+
+	candidate := &models.CalendarEvent{
+		StartsAt:    startsAt,
+		AllDay:      c.Query("all_day") == "true",
+		OrganizerID: userID,
+		Status:      models.CalendarEventStatusScheduled,
+	}
+	if value := c.Query("ends_at"); value != "" {
+		if candidate.EndsAt, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ends_at timestamp"})
+			return
+		}
+	}
+	if value := c.Query("attendees"); value != "" {
+		for _, raw := range strings.Split(value, ",") {
+			attendee, err := uuid.Parse(strings.TrimSpace(raw))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attendee ID"})
+				return
+			}
+			candidate.Attendees = append(candidate.Attendees, attendee)
+		}
+	}
+
+	if err := calendar.Normalize(candidate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conflicts, err := h.findConflicts(c, candidate)
+	if err != nil {
+		h.logger.Error("Failed to check calendar conflicts", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check calendar conflicts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"conflicts": conflicts,
+		"total":     len(conflicts),
+	})
+}
+
+// CreateFeed issues the caller a new iCal feed URL, invalidating any previous one
+func (h *CalendarHandler) CreateFeed(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	token, tokenHash, err := generateUploadToken()
+	if err != nil {
+		h.logger.Error("Failed to generate calendar feed token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+		return
+	}
+
+	if err := h.calendarRepo.SetFeedToken(c.Request.Context(), userID, tokenHash); err != nil {
+		h.logger.Error("Failed to store calendar feed token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+		return
+	}
+
+	// The raw token is only returned once; only its hash is stored
+	c.JSON(http.StatusCreated, gin.H{
+		"feed_url": strings.TrimRight(h.config.PublicBaseURL, "/") + "/api/v1/external/calendar/" + token + ".ics",
+	})
+}
+
+// RevokeFeed disables the caller's iCal feed URL
+func (h *CalendarHandler) RevokeFeed(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.calendarRepo.RevokeFeedToken(c.Request.Context(), userID); err != nil {
+		h.logger.Error("Failed to revoke calendar feed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed revoked"})
+}
+
+// GetFeed serves a user's iCal feed. Calendar clients cannot send headers, so
+// the token in the URL authorizes the request.
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	if token == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+
+	userID, err := h.calendarRepo.GetUserByFeedToken(c.Request.Context(), hashUploadToken(token))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+
+	now := time.Now()
+	events, err := h.calendarRepo.GetForUser(c.Request.Context(), userID,
+		now.Add(-h.config.FeedPastWindow), now.Add(h.config.FeedFutureWindow))
+	if err != nil {
+		h.logger.Error("Failed to build calendar feed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build calendar feed"})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8",
+		calendar.BuildFeed("AegisShield Investigations", h.config.FeedDomain, events, now))
+}
+
+// checkConflicts finds clashes for an event and writes a 409 when they are not allowed
+func (h *CalendarHandler) checkConflicts(c *gin.Context, event *models.CalendarEvent, allow bool) ([]models.CalendarConflict, bool) {
+	conflicts, err := h.findConflicts(c, event)
+	if err != nil {
+		h.logger.Error("Failed to check calendar conflicts", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check calendar conflicts"})
+		return nil, false
+	}
+
+	if len(conflicts) > 0 && !allow {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "Event overlaps existing events for one or more participants",
+			"conflicts": conflicts,
+		})
+		return nil, false
+	}
+
+	return conflicts, true
+}
+
+func (h *CalendarHandler) findConflicts(c *gin.Context, event *models.CalendarEvent) ([]models.CalendarConflict, error) {
+	existing, err := h.calendarRepo.GetOverlapping(c.Request.Context(),
+		calendar.Participants(event), event.StartsAt, event.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return calendar.FindConflicts(event, existing), nil
+}
+
+// resolveReminders applies the default reminders and validates requested ones
+func (h *CalendarHandler) resolveReminders(requested []int) (pq.Int64Array, error) {
+	if requested == nil {
+		requested = h.config.DefaultReminderMinutes
+	}
+	if len(requested) > h.config.MaxReminders {
+		return nil, fmt.Errorf("at most %d reminders are allowed", h.config.MaxReminders)
+	}
+
+	reminders := make(pq.Int64Array, 0, len(requested))
+	for _, minutes := range requested {
+		if minutes < 0 || minutes > maxReminderMinutes {
+			return nil, fmt.Errorf("reminder minutes must be between 0 and %d", maxReminderMinutes)
+		}
+		reminders = append(reminders, int64(minutes))
+	}
+
+	return reminders, nil
+}
+
+func (h *CalendarHandler) loadEvent(c *gin.Context) (*models.CalendarEvent, bool) {
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid calendar event ID"})
+		return nil, false
+	}
+
+	event, err := h.calendarRepo.GetByID(c.Request.Context(), eventID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar event not found"})
+		return nil, false
+	}
+
+	return event, true
+}
+
+func (h *CalendarHandler) requireParticipant(c *gin.Context, event *models.CalendarEvent) (uuid.UUID, bool) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	for _, participant := range calendar.Participants(event) {
+		if participant == userID {
+			return userID, true
+		}
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": "Only event participants can change this event"})
+	return uuid.Nil, false
+}
+
+func (h *CalendarHandler) markCancelled(event *models.CalendarEvent, userID uuid.UUID) {
+	now := time.Now()
+	event.Status = models.CalendarEventStatusCancelled
+	event.CancelledAt = &now
+	event.CancelledBy = &userID
+}
+
+func (h *CalendarHandler) requireUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr := c.GetHeader("X-User-ID")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID required"})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+func validEventType(eventType models.CalendarEventType) bool {
+	switch eventType {
+	case models.CalendarEventTypeInterview,
+		models.CalendarEventTypeFilingDeadline,
+		models.CalendarEventTypeCourtDate,
+		models.CalendarEventTypeMeeting,
+		models.CalendarEventTypeOther:
+		return true
+	default:
+		return false
+	}
+}
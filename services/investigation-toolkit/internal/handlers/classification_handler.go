@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"aegisshield/shared/rbac"
+
+	"investigation-toolkit/internal/classification"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+	"investigation-toolkit/internal/residency"
+)
+
+// ClassificationMiddleware limits restricted participants, such as external
+// counsel, to the cases they take part in and the evidence their clearance
+// covers. Evidence above their clearance is reported as missing, and
+// evidence lists are filtered to the classifications they may see.
+func ClassificationMiddleware(service *classification.Service, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if isExternalRoute(path) {
+			c.Next()
+			return
+		}
+
+		restricted := false
+		if subject, ok := rbac.SubjectFromContext(c.Request.Context()); ok {
+			restricted = subject.HasRole(rbac.RoleExternalCounsel)
+		}
+
+		scope := residency.ScopeOf(path)
+		if scope.Kind == residency.ScopeNone {
+			if restricted {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Route is not available to restricted participants"})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		decision, err := service.Check(c.Request.Context(), scope, requestUser(c), restricted, c.Request.Method, path)
+		if err != nil {
+			if errors.Is(err, repository.ErrEvidenceNotFound) {
+				// Handlers report missing records
+				c.Next()
+				return
+			}
+			logger.Error("Classification check failed", zap.String("path", path), zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Classification check failed"})
+			return
+		}
+		if decision.Hidden {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Evidence not found"})
+			return
+		}
+		if !decision.Allowed {
+			logger.Warn("Request denied by evidence classification", zap.String("path", path), zap.String("reason", decision.Reason))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": decision.Reason})
+			return
+		}
+
+		if decision.Access != nil {
+			c.Request = c.Request.WithContext(classification.WithAccess(c.Request.Context(), *decision.Access))
+		}
+		c.Next()
+	}
+}
+
+// ClassificationHandler handles case participants, evidence classifications
+// and per-case access matrices
+type ClassificationHandler struct {
+	service *classification.Service
+	logger  *zap.Logger
+}
+
+// NewClassificationHandler creates a new classification handler
+func NewClassificationHandler(service *classification.Service, logger *zap.Logger) *ClassificationHandler {
+	return &ClassificationHandler{
+		service: service,
+		logger:  logger.Named("classification_handler"),
+	}
+}
+
+// AddParticipant adds a user to a case, optionally in a restricted role
+func (h *ClassificationHandler) AddParticipant(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	var req models.AddParticipantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	participant, err := h.service.AddParticipant(c.Request.Context(), investigationID, &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to add participant")
+		return
+	}
+
+	c.JSON(http.StatusCreated, participant)
+}
+
+// ListParticipants lists the active participants of a case
+func (h *ClassificationHandler) ListParticipants(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	participants, err := h.service.Participants(c.Request.Context(), investigationID)
+	if err != nil {
+		h.handleError(c, err, "Failed to list participants")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"participants": participants})
+}
+
+// RemoveParticipant ends a user's participation in a case
+func (h *ClassificationHandler) RemoveParticipant(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+	participantID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveParticipant(c.Request.Context(), investigationID, participantID, userID); err != nil {
+		h.handleError(c, err, "Failed to remove participant")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ClassifyEvidence changes an evidence item's classification
+func (h *ClassificationHandler) ClassifyEvidence(c *gin.Context) {
+	evidenceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid evidence ID"})
+		return
+	}
+
+	var req models.ClassifyEvidenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	change, err := h.service.Classify(c.Request.Context(), evidenceID, &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to classify evidence")
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// GetClassificationHistory lists an evidence item's reclassifications
+func (h *ClassificationHandler) GetClassificationHistory(c *gin.Context) {
+	evidenceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid evidence ID"})
+		return
+	}
+
+	changes, err := h.service.History(c.Request.Context(), evidenceID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get classification history")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"changes": changes})
+}
+
+// GetAccessMatrix reports who can see which evidence of a case
+func (h *ClassificationHandler) GetAccessMatrix(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	matrix, err := h.service.AccessMatrix(c.Request.Context(), investigationID)
+	if err != nil {
+		h.handleError(c, err, "Failed to build access matrix")
+		return
+	}
+
+	c.JSON(http.StatusOK, matrix)
+}
+
+func (h *ClassificationHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrCaseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Investigation not found"})
+	case errors.Is(err, repository.ErrEvidenceNotFound), errors.Is(err, repository.ErrParticipantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrParticipantExists), errors.Is(err, repository.ErrClassificationChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, classification.ErrUnknownLevel),
+		errors.Is(err, classification.ErrInvalidParticipant),
+		errors.Is(err, classification.ErrInvalidChange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (h *ClassificationHandler) requireUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr := c.GetHeader("X-User-ID")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID required"})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
@@ -8,22 +8,30 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"investigation-toolkit/internal/classification"
 	"investigation-toolkit/internal/database"
 	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/quota"
 	"investigation-toolkit/internal/repository"
 )
 
 // EvidenceHandler handles HTTP requests for evidence
 type EvidenceHandler struct {
-	repo   *repository.EvidenceRepository
-	logger *zap.Logger
+	repo           *repository.EvidenceRepository
+	classification *classification.Service
+	quota          *quota.Service
+	logger         *zap.Logger
 }
 
-// NewEvidenceHandler creates a new evidence handler
-func NewEvidenceHandler(repo *repository.EvidenceRepository, logger *zap.Logger) *EvidenceHandler {
+// NewEvidenceHandler creates a new evidence handler. classificationService
+// and quotaService may be nil when evidence classification or storage
+// quotas are disabled.
+func NewEvidenceHandler(repo *repository.EvidenceRepository, classificationService *classification.Service, quotaService *quota.Service, logger *zap.Logger) *EvidenceHandler {
 	return &EvidenceHandler{
-		repo:   repo,
-		logger: logger.Named("evidence_handler"),
+		repo:           repo,
+		classification: classificationService,
+		quota:          quotaService,
+		logger:         logger.Named("evidence_handler"),
 	}
 }
 
@@ -44,6 +52,15 @@ func (h *EvidenceHandler) CreateEvidence(c *gin.Context) {
 		return
 	}
 
+	if h.classification != nil {
+		level, err := h.classification.Policy().ClassificationFor(req.Classification)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.Classification = &level
+	}
+
 	// Get user ID from context (would come from auth middleware)
 	userIDStr := c.GetHeader("X-User-ID")
 	if userIDStr == "" {
@@ -126,6 +143,11 @@ func (h *EvidenceHandler) ListEvidence(c *gin.Context) {
 		}
 	}
 
+	// Restricted participants only see the classifications they are cleared for
+	if access, ok := classification.AccessFromContext(c.Request.Context()); ok {
+		filter.Classifications = access.Visible
+	}
+
 	result, err := h.repo.GetByInvestigationID(c.Request.Context(), investigationID, filter, paginate)
 	if err != nil {
 		h.logger.Error("Failed to list evidence", zap.Error(err))
@@ -157,8 +179,33 @@ func (h *EvidenceHandler) UpdateEvidenceFile(c *gin.Context) {
 		return
 	}
 
-	err = h.repo.UpdateFile(c.Request.Context(), id, req.FilePath, req.FileHash, req.MimeType, req.FileSize)
+	ctx := c.Request.Context()
+
+	// The file is charged to the caller and their tenant; an earlier file's
+	// charge is returned when the new one is committed
+	var reservation *models.StorageReservation
+	if h.quota != nil {
+		userID := requestUser(c)
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID required"})
+			return
+		}
+		tenantID, err := h.quota.Tenant(c.Request)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var ok bool
+		if reservation, ok = reserveUpload(c, h.quota, h.logger, tenantID, *userID, req.FileSize); !ok {
+			return
+		}
+	}
+
+	err = h.repo.UpdateFile(ctx, id, req.FilePath, req.FileHash, req.MimeType, req.FileSize)
 	if err != nil {
+		if reservation != nil {
+			h.quota.Release(ctx, reservation)
+		}
 		if err.Error() == "evidence not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Evidence not found"})
 			return
@@ -168,6 +215,12 @@ func (h *EvidenceHandler) UpdateEvidenceFile(c *gin.Context) {
 		return
 	}
 
+	if reservation != nil {
+		if err := h.quota.Commit(ctx, reservation, quota.ObjectEvidence, id); err != nil {
+			h.logger.Error("Failed to charge evidence file to storage quota", zap.String("id", id.String()), zap.Error(err))
+		}
+	}
+
 	h.logger.Info("Evidence file updated", zap.String("id", id.String()))
 	c.JSON(http.StatusOK, gin.H{"message": "Evidence file updated successfully"})
 }
@@ -303,6 +356,12 @@ func (h *EvidenceHandler) DeleteEvidence(c *gin.Context) {
 		return
 	}
 
+	if h.quota != nil {
+		if err := h.quota.Remove(c.Request.Context(), quota.ObjectEvidence, id); err != nil {
+			h.logger.Error("Failed to return deleted evidence's storage quota", zap.String("id", id.String()), zap.Error(err))
+		}
+	}
+
 	h.logger.Info("Evidence deleted", zap.String("id", id.String()))
 	c.JSON(http.StatusNoContent, nil)
 }
@@ -0,0 +1,687 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	limits "aegisshield/shared/quota"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/quota"
+	"investigation-toolkit/internal/repository"
+	"investigation-toolkit/internal/residency"
+	"investigation-toolkit/internal/scanner"
+)
+
+// Chain of custody action recorded when a file arrives through a request link
+const custodyActionReceivedExternal = "received_external"
+
+// EvidenceRequestStore persists evidence requests and the files received for them
+type EvidenceRequestStore interface {
+	Create(ctx context.Context, request *models.EvidenceRequest) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.EvidenceRequest, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.EvidenceRequest, error)
+	GetByInvestigationID(ctx context.Context, investigationID uuid.UUID) ([]models.EvidenceRequest, error)
+	Revoke(ctx context.Context, id uuid.UUID, revokedBy uuid.UUID) error
+	ExpireOverdue(ctx context.Context) (int64, error)
+	ReserveSlot(ctx context.Context, id uuid.UUID) error
+	ReleaseSlot(ctx context.Context, id uuid.UUID) error
+	CreateSubmission(ctx context.Context, submission *models.EvidenceRequestSubmission) error
+	GetSubmissions(ctx context.Context, requestID uuid.UUID) ([]models.EvidenceRequestSubmission, error)
+}
+
+// ExternalEvidenceStore registers files received through evidence requests as evidence
+type ExternalEvidenceStore interface {
+	Create(ctx context.Context, investigationID uuid.UUID, req *models.CreateEvidenceRequest, collectedBy uuid.UUID) (*models.Evidence, error)
+	UpdateFile(ctx context.Context, id uuid.UUID, filePath, fileHash, mimeType string, fileSize int64) error
+	UpdateChainOfCustody(ctx context.Context, id uuid.UUID, userID uuid.UUID, action, location, notes string) error
+}
+
+// EvidenceRequestHandler handles evidence requests sent to external parties
+type EvidenceRequestHandler struct {
+	requestRepo       EvidenceRequestStore
+	evidenceRepo      ExternalEvidenceStore
+	collaborationRepo repository.CollaborationRepository
+	scanner           scanner.Scanner
+	residency         *residency.Service
+	quota             *quota.Service
+	config            config.EvidenceRequestConfig
+	storagePath       string
+	logger            *zap.Logger
+}
+
+// NewEvidenceRequestHandler creates a new evidence request handler
+func NewEvidenceRequestHandler(
+	requestRepo EvidenceRequestStore,
+	evidenceRepo ExternalEvidenceStore,
+	collaborationRepo repository.CollaborationRepository,
+	fileScanner scanner.Scanner,
+	residencyService *residency.Service,
+	quotaService *quota.Service,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *EvidenceRequestHandler {
+	return &EvidenceRequestHandler{
+		requestRepo:       requestRepo,
+		evidenceRepo:      evidenceRepo,
+		collaborationRepo: collaborationRepo,
+		scanner:           fileScanner,
+		residency:         residencyService,
+		quota:             quotaService,
+		config:            cfg.EvidenceRequests,
+		storagePath:       cfg.Storage.LocalPath,
+		logger:            logger.Named("evidence_request_handler"),
+	}
+}
+
+// Run expires overdue evidence requests every sweep interval until the
+// context is cancelled
+func (h *EvidenceRequestHandler) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.config.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := h.ExpireOverdue(ctx); err != nil {
+				h.logger.Error("Failed to expire evidence requests", zap.Error(err))
+			}
+		}
+	}
+}
+
+// ExpireOverdue closes open requests past their expiry so they are listed as
+// expired rather than pending
+func (h *EvidenceRequestHandler) ExpireOverdue(ctx context.Context) (int64, error) {
+	expired, err := h.requestRepo.ExpireOverdue(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if expired > 0 {
+		h.logger.Info("Expired evidence requests", zap.Int64("expired", expired))
+	}
+	return expired, nil
+}
+
+// CreateEvidenceRequest creates a secure upload link for an external party
+func (h *EvidenceRequestHandler) CreateEvidenceRequest(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	var req models.CreateEvidenceRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	expiry := h.config.DefaultExpiry
+	if req.ExpiresInHours > 0 {
+		expiry = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	if expiry > h.config.MaxExpiry {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Expiry cannot exceed %d hours", int(h.config.MaxExpiry.Hours())),
+		})
+		return
+	}
+
+	maxFiles := h.config.DefaultMaxFiles
+	if req.MaxFiles > 0 {
+		maxFiles = req.MaxFiles
+	}
+
+	allowedTypes, err := h.resolveAllowedTypes(req.AllowedFileTypes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Files received through the request are charged to the tenant that sent it
+	tenantID := limits.DefaultTenant
+	if h.quota != nil {
+		if tenantID, err = h.quota.Tenant(c.Request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	token, tokenHash, err := generateUploadToken()
+	if err != nil {
+		h.logger.Error("Failed to generate upload token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create evidence request"})
+		return
+	}
+
+	now := time.Now()
+	request := &models.EvidenceRequest{
+		ID:               uuid.New(),
+		InvestigationID:  investigationID,
+		TokenHash:        tokenHash,
+		RecipientName:    req.RecipientName,
+		RecipientEmail:   req.RecipientEmail,
+		RecipientType:    req.RecipientType,
+		Instructions:     req.Instructions,
+		AllowedFileTypes: allowedTypes,
+		MaxFiles:         maxFiles,
+		MaxFileSize:      h.config.MaxFileSize,
+		Status:           models.EvidenceRequestStatusPending,
+		ExpiresAt:        now.Add(expiry),
+		RequestedBy:      userID,
+		TenantID:         tenantID,
+		Metadata:         req.Metadata,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	if err := h.requestRepo.Create(c.Request.Context(), request); err != nil {
+		h.logger.Error("Failed to create evidence request", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create evidence request"})
+		return
+	}
+
+	h.logger.Info("Evidence request created",
+		zap.String("id", request.ID.String()),
+		zap.String("investigation_id", investigationID.String()),
+		zap.String("recipient_type", string(request.RecipientType)))
+
+	// The raw token is only returned once; only its hash is stored
+	c.JSON(http.StatusCreated, gin.H{
+		"evidence_request": request,
+		"upload_url":       h.uploadURL(token),
+	})
+}
+
+// ListEvidenceRequests lists the evidence requests sent for an investigation
+func (h *EvidenceRequestHandler) ListEvidenceRequests(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	requests, err := h.requestRepo.GetByInvestigationID(c.Request.Context(), investigationID)
+	if err != nil {
+		h.logger.Error("Failed to list evidence requests", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list evidence requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"evidence_requests": requests})
+}
+
+// GetEvidenceRequest retrieves an evidence request and the files received for it
+func (h *EvidenceRequestHandler) GetEvidenceRequest(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid evidence request ID"})
+		return
+	}
+
+	request, err := h.requestRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get evidence request", zap.Error(err))
+		c.JSON(http.StatusNotFound, gin.H{"error": "Evidence request not found"})
+		return
+	}
+
+	submissions, err := h.requestRepo.GetSubmissions(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get evidence request submissions", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get evidence request"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"evidence_request": request,
+		"submissions":      submissions,
+	})
+}
+
+// RevokeEvidenceRequest disables an evidence request link before it expires
+func (h *EvidenceRequestHandler) RevokeEvidenceRequest(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid evidence request ID"})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.requestRepo.Revoke(c.Request.Context(), id, userID); err != nil {
+		h.logger.Error("Failed to revoke evidence request", zap.Error(err))
+		c.JSON(http.StatusConflict, gin.H{"error": "Failed to revoke evidence request", "details": err.Error()})
+		return
+	}
+
+	h.logger.Info("Evidence request revoked", zap.String("id", id.String()))
+	c.JSON(http.StatusOK, gin.H{"message": "Evidence request revoked"})
+}
+
+// GetExternalRequest returns what an external party needs to fulfil a request.
+// Investigation details are deliberately not exposed on the public link.
+func (h *EvidenceRequestHandler) GetExternalRequest(c *gin.Context) {
+	request, ok := h.lookupOpenRequest(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recipient_name":     request.RecipientName,
+		"instructions":       request.Instructions,
+		"allowed_file_types": request.AllowedFileTypes,
+		"max_file_size":      request.MaxFileSize,
+		"files_remaining":    request.MaxFiles - request.FilesReceived,
+		"expires_at":         request.ExpiresAt,
+	})
+}
+
+// SubmitExternalEvidence accepts a file uploaded through an evidence request link
+func (h *EvidenceRequestHandler) SubmitExternalEvidence(c *gin.Context) {
+	request, ok := h.lookupOpenRequest(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, request.MaxFileSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required"})
+		return
+	}
+
+	if fileHeader.Size > request.MaxFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("File exceeds the maximum size of %d bytes", request.MaxFileSize),
+		})
+		return
+	}
+
+	fileName := filepath.Base(fileHeader.Filename)
+	if !isAllowedFileType(fileName, request.AllowedFileTypes) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error":              "File type is not accepted for this request",
+			"allowed_file_types": request.AllowedFileTypes,
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.logger.Error("Failed to open uploaded file", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, request.MaxFileSize+1))
+	if err != nil {
+		h.logger.Error("Failed to read uploaded file", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	if int64(len(content)) > request.MaxFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("File exceeds the maximum size of %d bytes", request.MaxFileSize),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Claim an upload slot before doing any work so concurrent uploads
+	// cannot exceed the request's file limit
+	if err := h.requestRepo.ReserveSlot(ctx, request.ID); err != nil {
+		c.JSON(http.StatusGone, gin.H{"error": "This evidence request is no longer accepting files"})
+		return
+	}
+
+	sum := sha256.Sum256(content)
+	fileHash := hex.EncodeToString(sum[:])
+	mimeType := http.DetectContentType(content)
+
+	submission := &models.EvidenceRequestSubmission{
+		ID:          uuid.New(),
+		RequestID:   request.ID,
+		FileName:    fileName,
+		FileSize:    int64(len(content)),
+		FileHash:    fileHash,
+		MimeType:    &mimeType,
+		SubmitterIP: stringPtr(c.ClientIP()),
+		SubmittedAt: time.Now(),
+	}
+	if note := strings.TrimSpace(c.PostForm("note")); note != "" {
+		submission.SubmitterNote = &note
+	}
+
+	submission.ScanStatus, submission.ScanResult = h.scan(ctx, content)
+	if submission.ScanStatus != models.ScanStatusClean && submission.ScanStatus != models.ScanStatusSkipped {
+		// Infected or unscannable files never become evidence; fail closed
+		submission.Status = models.SubmissionStatusQuarantined
+		h.rejectSubmission(ctx, request, submission)
+
+		h.logger.Warn("External evidence quarantined",
+			zap.String("request_id", request.ID.String()),
+			zap.String("scan_status", string(submission.ScanStatus)),
+			zap.String("file_hash", fileHash))
+
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "File failed security scanning and was not accepted"})
+		return
+	}
+
+	// Received files count against the quota of the investigator who sent
+	// the request and their tenant
+	var reservation *models.StorageReservation
+	if h.quota != nil {
+		var ok bool
+		if reservation, ok = reserveUpload(c, h.quota, h.logger, request.TenantID, request.RequestedBy, submission.FileSize); !ok {
+			submission.Status = models.SubmissionStatusRejected
+			h.rejectSubmission(ctx, request, submission)
+			return
+		}
+	}
+
+	evidence, err := h.storeEvidence(ctx, request, submission, content)
+	if err != nil {
+		if reservation != nil {
+			h.quota.Release(ctx, reservation)
+		}
+		h.logger.Error("Failed to store external evidence", zap.Error(err))
+		submission.Status = models.SubmissionStatusRejected
+		h.rejectSubmission(ctx, request, submission)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store uploaded file"})
+		return
+	}
+
+	if reservation != nil {
+		if err := h.quota.Commit(ctx, reservation, quota.ObjectEvidence, evidence.ID); err != nil {
+			h.logger.Error("Failed to charge external evidence to storage quota", zap.Error(err))
+		}
+	}
+
+	submission.EvidenceID = &evidence.ID
+	submission.Status = models.SubmissionStatusAccepted
+	if err := h.requestRepo.CreateSubmission(ctx, submission); err != nil {
+		h.logger.Error("Failed to record evidence request submission", zap.Error(err))
+	}
+
+	h.notifyRequester(ctx, request, evidence)
+
+	h.logger.Info("External evidence received",
+		zap.String("request_id", request.ID.String()),
+		zap.String("evidence_id", evidence.ID.String()),
+		zap.String("file_hash", fileHash))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "File received",
+		"file_name": fileName,
+		"file_hash": fileHash,
+	})
+}
+
+// lookupOpenRequest resolves the token in the URL to a request that can still accept files
+func (h *EvidenceRequestHandler) lookupOpenRequest(c *gin.Context) (*models.EvidenceRequest, bool) {
+	token := c.Param("token")
+	if token == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Evidence request not found"})
+		return nil, false
+	}
+
+	request, err := h.requestRepo.GetByTokenHash(c.Request.Context(), hashUploadToken(token))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Evidence request not found"})
+		return nil, false
+	}
+
+	switch {
+	case request.Status == models.EvidenceRequestStatusRevoked,
+		request.Status == models.EvidenceRequestStatusExpired,
+		time.Now().After(request.ExpiresAt):
+		c.JSON(http.StatusGone, gin.H{"error": "This evidence request has expired"})
+		return nil, false
+	case request.Status == models.EvidenceRequestStatusFulfilled,
+		request.FilesReceived >= request.MaxFiles:
+		c.JSON(http.StatusGone, gin.H{"error": "This evidence request has already been fulfilled"})
+		return nil, false
+	}
+
+	return request, true
+}
+
+// scan runs the malware scanner over the uploaded content
+func (h *EvidenceRequestHandler) scan(ctx context.Context, content []byte) (models.ScanStatus, *string) {
+	if !h.config.ScanEnabled || h.scanner == nil {
+		return models.ScanStatusSkipped, nil
+	}
+
+	result, err := h.scanner.Scan(ctx, bytes.NewReader(content))
+	if err != nil {
+		h.logger.Error("Malware scan failed", zap.Error(err))
+		return models.ScanStatusError, stringPtr(err.Error())
+	}
+
+	if !result.Clean {
+		return models.ScanStatusInfected, stringPtr(result.Signature)
+	}
+
+	return models.ScanStatusClean, stringPtr(result.Raw)
+}
+
+// storeEvidence writes the file to storage and registers it as evidence with
+// a chain of custody entry recording the external receipt
+func (h *EvidenceRequestHandler) storeEvidence(ctx context.Context, request *models.EvidenceRequest, submission *models.EvidenceRequestSubmission, content []byte) (*models.Evidence, error) {
+	source := fmt.Sprintf("external:%s", request.RecipientName)
+	collectionMethod := "external_evidence_request"
+	description := fmt.Sprintf("Received from %s via evidence request", request.RecipientName)
+
+	evidence, err := h.evidenceRepo.Create(ctx, request.InvestigationID, &models.CreateEvidenceRequest{
+		Name:             submission.FileName,
+		Description:      &description,
+		EvidenceType:     evidenceTypeForMime(*submission.MimeType),
+		Source:           &source,
+		CollectionMethod: &collectionMethod,
+		Tags:             []string{"external_request"},
+		Metadata: map[string]interface{}{
+			"evidence_request_id": request.ID,
+			"submission_id":       submission.ID,
+			"recipient_type":      request.RecipientType,
+			"scan_status":         submission.ScanStatus,
+		},
+	}, request.RequestedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// With residency controls, the file is written to the storage of the
+	// case's region
+	dir := filepath.Join(h.storagePath, request.InvestigationID.String(), evidence.ID.String())
+	var region string
+	if h.residency != nil {
+		dir, region, err = h.residency.EvidenceDir(ctx, request.InvestigationID, evidence.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, errors.Wrap(err, "failed to create evidence directory")
+	}
+
+	filePath := filepath.Join(dir, submission.FileName)
+	if err := os.WriteFile(filePath, content, 0640); err != nil {
+		return nil, errors.Wrap(err, "failed to write evidence file")
+	}
+
+	if err := h.evidenceRepo.UpdateFile(ctx, evidence.ID, filePath, submission.FileHash, *submission.MimeType, submission.FileSize); err != nil {
+		return nil, err
+	}
+	if region != "" {
+		if err := h.residency.RecordEvidenceRegion(ctx, evidence.ID, region); err != nil {
+			return nil, err
+		}
+	}
+
+	notes := fmt.Sprintf("Received from %s (%s) via evidence request %s; sha256 %s; scan %s",
+		request.RecipientName, request.RecipientType, request.ID, submission.FileHash, submission.ScanStatus)
+	location := "external upload"
+	if submission.SubmitterIP != nil {
+		location = fmt.Sprintf("external upload from %s", *submission.SubmitterIP)
+	}
+
+	if err := h.evidenceRepo.UpdateChainOfCustody(ctx, evidence.ID, request.RequestedBy, custodyActionReceivedExternal, location, notes); err != nil {
+		return nil, err
+	}
+
+	return evidence, nil
+}
+
+// rejectSubmission records a file that was not accepted and frees its upload slot
+func (h *EvidenceRequestHandler) rejectSubmission(ctx context.Context, request *models.EvidenceRequest, submission *models.EvidenceRequestSubmission) {
+	if err := h.requestRepo.CreateSubmission(ctx, submission); err != nil {
+		h.logger.Error("Failed to record evidence request submission", zap.Error(err))
+	}
+
+	if err := h.requestRepo.ReleaseSlot(ctx, request.ID); err != nil {
+		h.logger.Error("Failed to release evidence request slot", zap.Error(err))
+	}
+}
+
+// notifyRequester tells the investigator who sent the request that a file arrived
+func (h *EvidenceRequestHandler) notifyRequester(ctx context.Context, request *models.EvidenceRequest, evidence *models.Evidence) {
+	if h.collaborationRepo == nil {
+		return
+	}
+
+	notification := &models.NotificationEvent{
+		UserID:     request.RequestedBy,
+		Type:       "evidence_request_received",
+		Title:      "Evidence received",
+		Message:    fmt.Sprintf("%s uploaded %s in response to your evidence request", request.RecipientName, evidence.Name),
+		EntityType: "evidence",
+		EntityID:   evidence.ID,
+		IsRead:     false,
+	}
+
+	if err := h.collaborationRepo.CreateNotification(ctx, notification); err != nil {
+		h.logger.Error("Failed to notify evidence requester", zap.Error(err))
+	}
+}
+
+// requireUser reads the authenticated user from the request headers
+func (h *EvidenceRequestHandler) requireUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr := c.GetHeader("X-User-ID")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID required"})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// resolveAllowedTypes validates requested file types against the configured allow list
+func (h *EvidenceRequestHandler) resolveAllowedTypes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return h.config.AllowedFileTypes, nil
+	}
+
+	types := make([]string, 0, len(requested))
+	for _, fileType := range requested {
+		normalized := normalizeFileType(fileType)
+		if !containsString(h.config.AllowedFileTypes, normalized) {
+			return nil, errors.Errorf("file type %q is not permitted for external requests", fileType)
+		}
+		types = append(types, normalized)
+	}
+
+	return types, nil
+}
+
+func (h *EvidenceRequestHandler) uploadURL(token string) string {
+	return strings.TrimRight(h.config.PublicBaseURL, "/") + "/api/v1/external/evidence-requests/" + token
+}
+
+// generateUploadToken returns a random URL-safe token and its stored hash
+func generateUploadToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", errors.Wrap(err, "failed to read random bytes")
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashUploadToken(token), nil
+}
+
+func hashUploadToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeFileType(fileType string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(fileType)), ".")
+}
+
+func isAllowedFileType(fileName string, allowed []string) bool {
+	return containsString(allowed, normalizeFileType(filepath.Ext(fileName)))
+}
+
+func evidenceTypeForMime(mimeType string) models.EvidenceType {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return models.EvidenceTypeImage
+	case strings.HasPrefix(mimeType, "video/"):
+		return models.EvidenceTypeVideo
+	case strings.HasPrefix(mimeType, "audio/"):
+		return models.EvidenceTypeAudio
+	default:
+		return models.EvidenceTypeDocument
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+	"investigation-toolkit/internal/tiering"
+)
+
+// EvidenceStorageHandler handles storage tier status, downloads and archive
+// restores for evidence files
+type EvidenceStorageHandler struct {
+	evidenceRepo *repository.EvidenceRepository
+	tiering      *tiering.Service
+	logger       *zap.Logger
+}
+
+// NewEvidenceStorageHandler creates a new evidence storage handler
+func NewEvidenceStorageHandler(evidenceRepo *repository.EvidenceRepository, tieringService *tiering.Service, logger *zap.Logger) *EvidenceStorageHandler {
+	return &EvidenceStorageHandler{
+		evidenceRepo: evidenceRepo,
+		tiering:      tieringService,
+		logger:       logger.Named("evidence_storage_handler"),
+	}
+}
+
+// GetStorageStatus returns the storage tier of an evidence file and whether it can be downloaded now
+func (h *EvidenceStorageHandler) GetStorageStatus(c *gin.Context) {
+	evidence, ok := h.evidenceFile(c)
+	if !ok {
+		return
+	}
+	evidenceID := evidence.ID
+
+	status, err := h.tiering.Status(c.Request.Context(), evidenceID)
+	if err != nil {
+		h.logger.Error("Failed to get evidence storage status", zap.String("evidence_id", evidenceID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get storage status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// DownloadContent streams an evidence file from whichever tier holds it.
+// Archived files respond with 202 and a restoring_from_archive status until
+// the restore completes.
+func (h *EvidenceStorageHandler) DownloadContent(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	evidence, ok := h.evidenceFile(c)
+	if !ok {
+		return
+	}
+	evidenceID := evidence.ID
+
+	reader, status, err := h.tiering.Open(c.Request.Context(), evidenceID, userID)
+	if err == tiering.ErrRestoreInProgress {
+		c.JSON(http.StatusAccepted, status)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to open evidence file", zap.String("evidence_id", evidenceID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read evidence file"})
+		return
+	}
+	defer reader.Close()
+
+	contentType := "application/octet-stream"
+	if evidence.MimeType != nil && *evidence.MimeType != "" {
+		contentType = *evidence.MimeType
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(*evidence.FilePath)))
+	c.Header("X-Storage-Tier", string(status.Tier))
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, reader); err != nil {
+		h.logger.Warn("Evidence download interrupted", zap.String("evidence_id", evidenceID.String()), zap.Error(err))
+	}
+}
+
+// RequestRestore starts restoring an archived evidence file ahead of a download
+func (h *EvidenceStorageHandler) RequestRestore(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	evidence, ok := h.evidenceFile(c)
+	if !ok {
+		return
+	}
+	evidenceID := evidence.ID
+
+	status, err := h.tiering.RequestRestore(c.Request.Context(), evidenceID, userID)
+	if err != nil {
+		h.logger.Error("Failed to request archive restore", zap.String("evidence_id", evidenceID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request restore"})
+		return
+	}
+
+	if status.Availability == tiering.AvailabilityRestoring {
+		c.JSON(http.StatusAccepted, status)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// GetTierStats returns the number of evidence files and bytes held in each storage tier
+func (h *EvidenceStorageHandler) GetTierStats(c *gin.Context) {
+	stats, err := h.tiering.Stats(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to get storage tier stats", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get storage tier stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tiers": stats})
+}
+
+// evidenceFile loads the evidence from the path and checks it has a stored file
+func (h *EvidenceStorageHandler) evidenceFile(c *gin.Context) (*models.Evidence, bool) {
+	evidenceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid evidence ID"})
+		return nil, false
+	}
+
+	evidence, err := h.evidenceRepo.GetByID(c.Request.Context(), evidenceID)
+	if err != nil {
+		if err.Error() == "evidence not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Evidence not found"})
+			return nil, false
+		}
+		h.logger.Error("Failed to get evidence", zap.String("evidence_id", evidenceID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get evidence"})
+		return nil, false
+	}
+
+	if evidence.FilePath == nil || *evidence.FilePath == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Evidence has no stored file"})
+		return nil, false
+	}
+
+	return evidence, true
+}
+
+func (h *EvidenceStorageHandler) requireUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr := c.GetHeader("X-User-ID")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID required"})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
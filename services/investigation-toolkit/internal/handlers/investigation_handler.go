@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -11,19 +12,22 @@ import (
 	"investigation-toolkit/internal/database"
 	"investigation-toolkit/internal/models"
 	"investigation-toolkit/internal/repository"
+	"investigation-toolkit/internal/residency"
 )
 
 // InvestigationHandler handles HTTP requests for investigations
 type InvestigationHandler struct {
-	repo   *repository.InvestigationRepository
-	logger *zap.Logger
+	repo      *repository.InvestigationRepository
+	residency *residency.Service // nil when residency controls are disabled
+	logger    *zap.Logger
 }
 
 // NewInvestigationHandler creates a new investigation handler
-func NewInvestigationHandler(repo *repository.InvestigationRepository, logger *zap.Logger) *InvestigationHandler {
+func NewInvestigationHandler(repo *repository.InvestigationRepository, residencyService *residency.Service, logger *zap.Logger) *InvestigationHandler {
 	return &InvestigationHandler{
-		repo:   repo,
-		logger: logger.Named("investigation_handler"),
+		repo:      repo,
+		residency: residencyService,
+		logger:    logger.Named("investigation_handler"),
 	}
 }
 
@@ -49,6 +53,16 @@ func (h *InvestigationHandler) CreateInvestigation(c *gin.Context) {
 		return
 	}
 
+	// New cases are pinned to the requested region, or the default one
+	if h.residency != nil {
+		region, err := h.residency.Policy().RegionForNewCase(req.Region)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown region", "details": err.Error()})
+			return
+		}
+		req.Region = &region
+	}
+
 	investigation, err := h.repo.Create(c.Request.Context(), &req, userID)
 	if err != nil {
 		h.logger.Error("Failed to create investigation", zap.Error(err))
@@ -159,6 +173,30 @@ func (h *InvestigationHandler) ListInvestigations(c *gin.Context) {
 		filter.Search = &search
 	}
 
+	if region := c.Query("region"); region != "" {
+		filter.Region = &region
+	}
+
+	// Comma-separated statuses, e.g. status=open,in_progress
+	if statuses := c.Query("status"); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				filter.Statuses = append(filter.Statuses, models.Status(status))
+			}
+		}
+	}
+
+	if assignedTo := c.Query("assigned_to"); assignedTo != "" {
+		id, err := uuid.Parse(assignedTo)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assigned_to"})
+			return
+		}
+		filter.AssignedTo = &id
+	}
+
+	filter.IncludeArchived, _ = strconv.ParseBool(c.Query("include_archived"))
+
 	// Add other filter parsing as needed
 
 	result, err := h.repo.List(c.Request.Context(), filter, paginate)
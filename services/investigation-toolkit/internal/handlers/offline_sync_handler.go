@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	limits "aegisshield/shared/quota"
+
+	"investigation-toolkit/internal/archival"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/offlinesync"
+	"investigation-toolkit/internal/quota"
+	"investigation-toolkit/internal/repository"
+)
+
+// OfflineSyncHandler handles uploads of evidence collected offline on field
+// devices
+type OfflineSyncHandler struct {
+	service   *offlinesync.Service
+	quota     *quota.Service
+	chunkSize int64
+	logger    *zap.Logger
+}
+
+// NewOfflineSyncHandler creates a new offline sync handler. quotaService may
+// be nil when storage quotas are disabled.
+func NewOfflineSyncHandler(service *offlinesync.Service, quotaService *quota.Service, chunkSize int64, logger *zap.Logger) *OfflineSyncHandler {
+	return &OfflineSyncHandler{
+		service:   service,
+		quota:     quotaService,
+		chunkSize: chunkSize,
+		logger:    logger.Named("offline_sync_handler"),
+	}
+}
+
+// CreateSession registers a device's manifest of offline evidence and
+// returns the chunks to upload. Sending the same manifest again resumes the
+// device's open session.
+func (h *OfflineSyncHandler) CreateSession(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	var req models.CreateOfflineSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID := requestUser(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID required"})
+		return
+	}
+
+	// Synced files are charged to the tenant that opened the session
+	tenantID := limits.DefaultTenant
+	if h.quota != nil {
+		if tenantID, err = h.quota.Tenant(c.Request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	progress, err := h.service.CreateSession(c.Request.Context(), investigationID, &req, *userID, tenantID)
+	if err != nil {
+		h.handleError(c, err, "Failed to create offline sync session")
+		return
+	}
+
+	status := http.StatusCreated
+	if progress.Resumed {
+		status = http.StatusOK
+	}
+	c.JSON(status, progress)
+}
+
+// ListSessions lists the offline sync sessions of a case
+func (h *OfflineSyncHandler) ListSessions(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	sessions, err := h.service.Sessions(c.Request.Context(), investigationID)
+	if err != nil {
+		h.handleError(c, err, "Failed to list offline sync sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// GetSession reports a session's upload progress, listing the chunks each
+// item still needs
+func (h *OfflineSyncHandler) GetSession(c *gin.Context) {
+	id, ok := h.sessionID(c)
+	if !ok {
+		return
+	}
+
+	progress, err := h.service.Progress(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to get offline sync session")
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// UploadChunk stores one chunk of an item. The request body is the chunk
+// and the X-Chunk-SHA256 header its hex SHA-256.
+func (h *OfflineSyncHandler) UploadChunk(c *gin.Context) {
+	id, ok := h.sessionID(c)
+	if !ok {
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chunk index"})
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, h.chunkSize+1)
+	progress, err := h.service.UploadChunk(c.Request.Context(), id, itemID, index, c.GetHeader("X-Chunk-SHA256"), body)
+	if err != nil {
+		h.handleError(c, err, "Failed to upload chunk")
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// CompleteSession verifies the uploaded files and adds them to the case
+func (h *OfflineSyncHandler) CompleteSession(c *gin.Context) {
+	id, ok := h.sessionID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Complete(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, offlinesync.ErrIncomplete) {
+			progress, progressErr := h.service.Progress(c.Request.Context(), id)
+			if progressErr == nil {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "progress": progress})
+				return
+			}
+		}
+		h.handleError(c, err, "Failed to complete offline sync session")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CancelSession abandons an open session and discards its chunks
+func (h *OfflineSyncHandler) CancelSession(c *gin.Context) {
+	id, ok := h.sessionID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Cancel(c.Request.Context(), id); err != nil {
+		h.handleError(c, err, "Failed to cancel offline sync session")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *OfflineSyncHandler) sessionID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offline sync session ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *OfflineSyncHandler) handleError(c *gin.Context, err error, message string) {
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.Is(err, repository.ErrCaseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Investigation not found"})
+	case errors.Is(err, repository.ErrOfflineSyncNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offline sync session not found"})
+	case errors.Is(err, repository.ErrOfflineSyncItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offline sync item not found"})
+	case errors.Is(err, archival.ErrCaseArchived):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, offlinesync.ErrSessionClosed), errors.Is(err, offlinesync.ErrIncomplete):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, offlinesync.ErrChunkHashMismatch):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.As(err, &maxBytes):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Chunk exceeds the session's chunk size"})
+	case errors.Is(err, offlinesync.ErrInvalidManifest), errors.Is(err, offlinesync.ErrInvalidChunk):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/production"
+	"investigation-toolkit/internal/repository"
+)
+
+// ProductionHandler handles producing case artifacts to law-enforcement
+// agencies in the layouts of their request templates
+type ProductionHandler struct {
+	service *production.Service
+	logger  *zap.Logger
+}
+
+// NewProductionHandler creates a new production handler
+func NewProductionHandler(service *production.Service, logger *zap.Logger) *ProductionHandler {
+	return &ProductionHandler{
+		service: service,
+		logger:  logger.Named("production_handler"),
+	}
+}
+
+// ListTemplates lists the production templates
+func (h *ProductionHandler) ListTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"templates": h.service.Templates()})
+}
+
+// CreateProduction drafts a production of selected case artifacts
+func (h *ProductionHandler) CreateProduction(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	var req models.CreateProductionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	p, err := h.service.Create(c.Request.Context(), investigationID, &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to create production")
+		return
+	}
+
+	c.JSON(http.StatusCreated, p)
+}
+
+// ListProductions lists a case's productions
+func (h *ProductionHandler) ListProductions(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	productions, err := h.service.List(c.Request.Context(), investigationID)
+	if err != nil {
+		h.handleError(c, err, "Failed to list productions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"productions": productions})
+}
+
+// GetProduction retrieves a production
+func (h *ProductionHandler) GetProduction(c *gin.Context) {
+	id, ok := h.productionID(c)
+	if !ok {
+		return
+	}
+
+	p, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to get production")
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
+
+// ApproveProduction approves a draft production and its redactions
+func (h *ProductionHandler) ApproveProduction(c *gin.Context) {
+	id, ok := h.productionID(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	p, err := h.service.Approve(c.Request.Context(), id, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to approve production")
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
+
+// Produce writes the file of an approved production and records its
+// disclosure. The file is downloaded from the production's file route.
+func (h *ProductionHandler) Produce(c *gin.Context) {
+	id, ok := h.productionID(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	p, err := h.service.Produce(c.Request.Context(), id, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to produce production")
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
+
+// DownloadFile downloads a produced production's file as it was disclosed
+func (h *ProductionHandler) DownloadFile(c *gin.Context) {
+	id, ok := h.productionID(c)
+	if !ok {
+		return
+	}
+
+	p, err := h.service.File(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to get production file")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", *p.FileName))
+	c.Header("X-Content-SHA256", *p.FileSHA256)
+	c.Data(http.StatusOK, *p.ContentType, p.Content)
+}
+
+func (h *ProductionHandler) productionID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid production ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *ProductionHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrCaseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Investigation not found"})
+	case errors.Is(err, repository.ErrProductionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Production not found"})
+	case errors.Is(err, production.ErrSelfApproval):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrProductionNotDraft), errors.Is(err, repository.ErrProductionNotApproved),
+		errors.Is(err, production.ErrNotProduced):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, production.ErrInvalidProduction), errors.Is(err, production.ErrUnknownTemplate),
+		errors.Is(err, production.ErrArtifactNotInCase):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (h *ProductionHandler) requireUser(c *gin.Context) (uuid.UUID, bool) {
+	userID := requestUser(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID required"})
+		return uuid.Nil, false
+	}
+	return *userID, true
+}
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/questionnaire"
+	"investigation-toolkit/internal/repository"
+)
+
+// QuestionnaireHandler handles questionnaire templates, their assignment
+// within review cases, responses and the questionnaire section of case reports
+type QuestionnaireHandler struct {
+	service *questionnaire.Service
+	logger  *zap.Logger
+}
+
+// NewQuestionnaireHandler creates a new questionnaire handler
+func NewQuestionnaireHandler(service *questionnaire.Service, logger *zap.Logger) *QuestionnaireHandler {
+	return &QuestionnaireHandler{
+		service: service,
+		logger:  logger.Named("questionnaire_handler"),
+	}
+}
+
+// CreateTemplate creates a draft questionnaire template
+func (h *QuestionnaireHandler) CreateTemplate(c *gin.Context) {
+	var req models.QuestionnaireTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	template, err := h.service.CreateTemplate(c.Request.Context(), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to create questionnaire template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// ListTemplates lists questionnaire templates, optionally by ?status=
+func (h *QuestionnaireHandler) ListTemplates(c *gin.Context) {
+	status := models.QuestionnaireTemplateStatus(c.Query("status"))
+	switch status {
+	case "", models.QuestionnaireTemplateStatusDraft, models.QuestionnaireTemplateStatusPublished,
+		models.QuestionnaireTemplateStatusArchived:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown template status: %s", status)})
+		return
+	}
+
+	templates, err := h.service.Templates(c.Request.Context(), status)
+	if err != nil {
+		h.handleError(c, err, "Failed to list questionnaire templates")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// GetTemplate retrieves a questionnaire template
+func (h *QuestionnaireHandler) GetTemplate(c *gin.Context) {
+	id, ok := h.parseID(c, "Invalid template ID")
+	if !ok {
+		return
+	}
+
+	template, err := h.service.GetTemplate(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to get questionnaire template")
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// UpdateTemplate replaces a draft template's content
+func (h *QuestionnaireHandler) UpdateTemplate(c *gin.Context) {
+	id, ok := h.parseID(c, "Invalid template ID")
+	if !ok {
+		return
+	}
+
+	var req models.QuestionnaireTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	template, err := h.service.UpdateTemplate(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update questionnaire template")
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// PublishTemplate freezes a draft template so it can be assigned
+func (h *QuestionnaireHandler) PublishTemplate(c *gin.Context) {
+	id, ok := h.parseID(c, "Invalid template ID")
+	if !ok {
+		return
+	}
+
+	template, err := h.service.Publish(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to publish questionnaire template")
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// ArchiveTemplate withdraws a template from new assignments
+func (h *QuestionnaireHandler) ArchiveTemplate(c *gin.Context) {
+	id, ok := h.parseID(c, "Invalid template ID")
+	if !ok {
+		return
+	}
+
+	template, err := h.service.Archive(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to archive questionnaire template")
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// AssignQuestionnaire assigns a published questionnaire within a case
+func (h *QuestionnaireHandler) AssignQuestionnaire(c *gin.Context) {
+	investigationID, ok := h.parseID(c, "Invalid investigation ID")
+	if !ok {
+		return
+	}
+
+	var req models.AssignQuestionnaireRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	assignment, err := h.service.Assign(c.Request.Context(), investigationID, &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to assign questionnaire")
+		return
+	}
+
+	c.JSON(http.StatusCreated, assignment)
+}
+
+// ListQuestionnaires lists the questionnaires assigned within a case
+func (h *QuestionnaireHandler) ListQuestionnaires(c *gin.Context) {
+	investigationID, ok := h.parseID(c, "Invalid investigation ID")
+	if !ok {
+		return
+	}
+
+	assignments, err := h.service.Assignments(c.Request.Context(), investigationID)
+	if err != nil {
+		h.handleError(c, err, "Failed to list questionnaires")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"questionnaires": assignments})
+}
+
+// GetQuestionnaire retrieves an assigned questionnaire and its answers
+func (h *QuestionnaireHandler) GetQuestionnaire(c *gin.Context) {
+	id, ok := h.parseID(c, "Invalid questionnaire ID")
+	if !ok {
+		return
+	}
+
+	assignment, err := h.service.GetAssignment(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to get questionnaire")
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// SaveResponses saves answers to an open questionnaire
+func (h *QuestionnaireHandler) SaveResponses(c *gin.Context) {
+	id, ok := h.parseID(c, "Invalid questionnaire ID")
+	if !ok {
+		return
+	}
+
+	var req models.SaveQuestionnaireResponsesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	assignment, err := h.service.SaveResponses(c.Request.Context(), id, &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to save questionnaire responses")
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// SubmitQuestionnaire completes a questionnaire
+func (h *QuestionnaireHandler) SubmitQuestionnaire(c *gin.Context) {
+	id, ok := h.parseID(c, "Invalid questionnaire ID")
+	if !ok {
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	assignment, err := h.service.Submit(c.Request.Context(), id, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to submit questionnaire")
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// CancelQuestionnaire withdraws an open questionnaire
+func (h *QuestionnaireHandler) CancelQuestionnaire(c *gin.Context) {
+	id, ok := h.parseID(c, "Invalid questionnaire ID")
+	if !ok {
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	assignment, err := h.service.Cancel(c.Request.Context(), id, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to cancel questionnaire")
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// GetQuestionnaireReport exports a case's completed questionnaires for the
+// case report; format=csv returns one row per answered question.
+func (h *QuestionnaireHandler) GetQuestionnaireReport(c *gin.Context) {
+	investigationID, ok := h.parseID(c, "Invalid investigation ID")
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported format: %s", format)})
+		return
+	}
+
+	report, err := h.service.Report(c.Request.Context(), investigationID)
+	if err != nil {
+		h.handleError(c, err, "Failed to build questionnaire report")
+		return
+	}
+
+	if format == "csv" {
+		h.writeReportCSV(c, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *QuestionnaireHandler) writeReportCSV(c *gin.Context, report *models.QuestionnaireReport) {
+	filename := fmt.Sprintf("questionnaires-%s.csv", report.InvestigationID)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"questionnaire_id", "questionnaire", "submitted_by", "submitted_at",
+		"question_id", "question", "answer",
+	})
+	for _, entry := range report.Questionnaires {
+		row := []string{entry.AssignmentID.String(), entry.TemplateName, "", ""}
+		if entry.SubmittedBy != nil {
+			row[2] = entry.SubmittedBy.String()
+		}
+		if entry.SubmittedAt != nil {
+			row[3] = entry.SubmittedAt.UTC().Format(time.RFC3339)
+		}
+
+		for _, item := range entry.Answers {
+			w.Write(append(row[:4:4], item.QuestionID, item.Question, item.Answer))
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		h.logger.Error("Failed to write questionnaire report", zap.Error(err))
+	}
+}
+
+func (h *QuestionnaireHandler) handleError(c *gin.Context, err error, message string) {
+	var invalid *questionnaire.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Questionnaire answers are invalid", "fields": invalid.Fields})
+	case errors.Is(err, repository.ErrCaseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Investigation not found"})
+	case errors.Is(err, repository.ErrQuestionnaireTemplateNotFound), errors.Is(err, repository.ErrQuestionnaireNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, questionnaire.ErrNotAssignee):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, questionnaire.ErrTemplateNotDraft),
+		errors.Is(err, questionnaire.ErrTemplateNotPublished),
+		errors.Is(err, questionnaire.ErrAssignmentClosed),
+		errors.Is(err, repository.ErrQuestionnaireChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, questionnaire.ErrInvalidForm), errors.Is(err, questionnaire.ErrInvalidAssignment):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (h *QuestionnaireHandler) parseID(c *gin.Context, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// requireUser identifies the caller, by their token when RBAC is enabled,
+// since answering is limited to the assignee
+func (h *QuestionnaireHandler) requireUser(c *gin.Context) (uuid.UUID, bool) {
+	userID := requestUser(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID required"})
+		return uuid.Nil, false
+	}
+	return *userID, true
+}
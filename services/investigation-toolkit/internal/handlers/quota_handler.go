@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	limits "aegisshield/shared/quota"
+
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/quota"
+)
+
+// QuotaHandler handles evidence storage usage, pre-upload quota checks and
+// per-tenant and per-user limits
+type QuotaHandler struct {
+	service *quota.Service
+	logger  *zap.Logger
+}
+
+// NewQuotaHandler creates a new quota handler
+func NewQuotaHandler(service *quota.Service, logger *zap.Logger) *QuotaHandler {
+	return &QuotaHandler{
+		service: service,
+		logger:  logger.Named("quota_handler"),
+	}
+}
+
+// GetMyUsage reports the storage usage of the caller and their tenant
+func (h *QuotaHandler) GetMyUsage(c *gin.Context) {
+	tenantID, userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	tenant, err := h.service.Usage(c.Request.Context(), limits.ScopeTenant, tenantID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get storage usage")
+		return
+	}
+	user, err := h.service.Usage(c.Request.Context(), limits.ScopeUser, userID.String())
+	if err != nil {
+		h.handleError(c, err, "Failed to get storage usage")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tenant": tenant, "user": user})
+}
+
+// CheckUpload reports whether a file of the given size would be accepted,
+// so clients can stop before uploading it
+func (h *QuotaHandler) CheckUpload(c *gin.Context) {
+	size, err := strconv.ParseInt(c.Query("size"), 10, 64)
+	if err != nil || size < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "size must be a non-negative number of bytes"})
+		return
+	}
+
+	tenantID, userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	decision, err := h.service.Check(c.Request.Context(), tenantID, userID, size)
+	if err != nil {
+		h.handleError(c, err, "Failed to check storage quota")
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
+
+// ListTenantUsage reports the storage usage of every tenant
+func (h *QuotaHandler) ListTenantUsage(c *gin.Context) {
+	h.listUsage(c, limits.ScopeTenant)
+}
+
+// ListUserUsage reports the storage usage of every user
+func (h *QuotaHandler) ListUserUsage(c *gin.Context) {
+	h.listUsage(c, limits.ScopeUser)
+}
+
+// GetTenantUsage reports a tenant's storage usage
+func (h *QuotaHandler) GetTenantUsage(c *gin.Context) {
+	h.getUsage(c, limits.ScopeTenant, c.Param("tenant_id"))
+}
+
+// GetUserUsage reports a user's storage usage
+func (h *QuotaHandler) GetUserUsage(c *gin.Context) {
+	h.getUsage(c, limits.ScopeUser, c.Param("user_id"))
+}
+
+// SetTenantQuota replaces a tenant's storage limits
+func (h *QuotaHandler) SetTenantQuota(c *gin.Context) {
+	h.setLimit(c, limits.ScopeTenant, c.Param("tenant_id"))
+}
+
+// SetUserQuota replaces a user's storage limits
+func (h *QuotaHandler) SetUserQuota(c *gin.Context) {
+	h.setLimit(c, limits.ScopeUser, c.Param("user_id"))
+}
+
+func (h *QuotaHandler) listUsage(c *gin.Context, scope limits.Scope) {
+	reports, err := h.service.ListUsage(c.Request.Context(), scope)
+	if err != nil {
+		h.handleError(c, err, "Failed to list storage usage")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"usage": reports})
+}
+
+func (h *QuotaHandler) getUsage(c *gin.Context, scope limits.Scope, subjectID string) {
+	report, err := h.service.Usage(c.Request.Context(), scope, subjectID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get storage usage")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *QuotaHandler) setLimit(c *gin.Context, scope limits.Scope, subjectID string) {
+	var req models.SetStorageQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.SetLimit(c.Request.Context(), scope, subjectID, &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to set storage quota")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// caller resolves the tenant and user a request is made for
+func (h *QuotaHandler) caller(c *gin.Context) (string, uuid.UUID, bool) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return "", uuid.Nil, false
+	}
+
+	tenantID, err := h.service.Tenant(c.Request)
+	if err != nil {
+		h.handleError(c, err, "Invalid tenant")
+		return "", uuid.Nil, false
+	}
+
+	return tenantID, userID, true
+}
+
+func (h *QuotaHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, quota.ErrInvalidSubject), errors.Is(err, quota.ErrInvalidSize), errors.Is(err, quota.ErrInvalidLimit):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (h *QuotaHandler) requireUser(c *gin.Context) (uuid.UUID, bool) {
+	userID := requestUser(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID required"})
+		return uuid.Nil, false
+	}
+	return *userID, true
+}
+
+// quotaExceeded responds to an upload refused by storage quota
+func quotaExceeded(c *gin.Context, decision limits.Decision) {
+	c.JSON(http.StatusInsufficientStorage, gin.H{
+		"error":    decision.Message,
+		"decision": decision,
+	})
+}
+
+// reserveUpload holds storage quota for an upload of size bytes, responding
+// to the request if the upload is refused. Uploads that take the tenant or
+// user past the warning threshold are flagged in the X-Quota-Level header.
+func reserveUpload(c *gin.Context, service *quota.Service, logger *zap.Logger, tenantID string, userID uuid.UUID, size int64) (*models.StorageReservation, bool) {
+	reservation, decision, err := service.Reserve(c.Request.Context(), tenantID, userID, size)
+	if err != nil {
+		switch {
+		case errors.Is(err, quota.ErrExceeded):
+			quotaExceeded(c, decision)
+		case errors.Is(err, quota.ErrInvalidSize):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			logger.Error("Failed to reserve storage quota", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reserve storage quota"})
+		}
+		return nil, false
+	}
+
+	if decision.Level != limits.LevelOK {
+		c.Header("X-Quota-Level", string(decision.Level))
+	}
+	return reservation, true
+}
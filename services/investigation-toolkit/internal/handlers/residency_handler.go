@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"aegisshield/shared/rbac"
+
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+	"investigation-toolkit/internal/residency"
+)
+
+// ResidencyMiddleware blocks requests for cases pinned to another region
+// unless the user holds a residency grant for this region. Case lists are
+// limited to cases pinned here. External routes are skipped; their tokens
+// are checked against the case by their handlers.
+func ResidencyMiddleware(service *residency.Service, logger *zap.Logger) gin.HandlerFunc {
+	policy := service.Policy()
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if isExternalRoute(path) {
+			c.Next()
+			return
+		}
+
+		if c.Request.Method == http.MethodGet && path == "/api/v1/investigations" {
+			query := c.Request.URL.Query()
+			region := query.Get("region")
+			if region == "" {
+				query.Set("region", policy.LocalRegion)
+				c.Request.URL.RawQuery = query.Encode()
+			} else if region != policy.LocalRegion {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error":        "Cross-region queries are not allowed",
+					"local_region": policy.LocalRegion,
+				})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		scope := residency.ScopeOf(path)
+		if scope.Kind == residency.ScopeNone {
+			c.Next()
+			return
+		}
+
+		decision, err := service.Check(c.Request.Context(), scope, requestUser(c), c.Request.Method, path)
+		if err != nil {
+			if errors.Is(err, repository.ErrCaseNotFound) {
+				// Handlers report missing records
+				c.Next()
+				return
+			}
+			logger.Error("Residency check failed", zap.String("path", path), zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Residency check failed"})
+			return
+		}
+		if !decision.Allowed {
+			logger.Warn("Request denied by residency controls",
+				zap.String("path", path),
+				zap.String("case_region", decision.CaseRegion),
+				zap.String("local_region", policy.LocalRegion))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":        "Case data is pinned to another region",
+				"case_region":  decision.CaseRegion,
+				"local_region": policy.LocalRegion,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// requestUser returns the authenticated user, falling back to the X-User-ID
+// header when RBAC is disabled
+func requestUser(c *gin.Context) *uuid.UUID {
+	userIDStr := c.GetHeader("X-User-ID")
+	if subject, ok := rbac.SubjectFromContext(c.Request.Context()); ok {
+		userIDStr = subject.ID
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil
+	}
+	return &userID
+}
+
+// ResidencyHandler handles case region tags, cross-region grants, regional
+// exports and residency compliance reports
+type ResidencyHandler struct {
+	service *residency.Service
+	logger  *zap.Logger
+}
+
+// NewResidencyHandler creates a new residency handler
+func NewResidencyHandler(service *residency.Service, logger *zap.Logger) *ResidencyHandler {
+	return &ResidencyHandler{
+		service: service,
+		logger:  logger.Named("residency_handler"),
+	}
+}
+
+// SetCaseRegion pins a case created before residency controls to a region
+func (h *ResidencyHandler) SetCaseRegion(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	var req models.SetCaseRegionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	if err := h.service.TagCase(c.Request.Context(), investigationID, req.Region); err != nil {
+		h.handleError(c, err, "Failed to set investigation region")
+		return
+	}
+
+	h.logger.Info("Investigation pinned to region",
+		zap.String("investigation_id", investigationID.String()),
+		zap.String("region", req.Region))
+	c.JSON(http.StatusOK, gin.H{"investigation_id": investigationID, "region": req.Region})
+}
+
+// CreateGrant authorizes a user in another region to access a case
+func (h *ResidencyHandler) CreateGrant(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	var req models.CreateResidencyGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	grant, err := h.service.Grant(c.Request.Context(), investigationID, &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to create residency grant")
+		return
+	}
+
+	c.JSON(http.StatusCreated, grant)
+}
+
+// ListGrants lists the residency grants made on a case
+func (h *ResidencyHandler) ListGrants(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	grants, err := h.service.Grants(c.Request.Context(), investigationID)
+	if err != nil {
+		h.handleError(c, err, "Failed to list residency grants")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"grants": grants})
+}
+
+// RevokeGrant ends a residency grant before it expires
+func (h *ResidencyHandler) RevokeGrant(c *gin.Context) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID"})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	grant, err := h.service.Revoke(c.Request.Context(), grantID, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to revoke residency grant")
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+// CreateExport writes a case bundle to the export storage of its region
+func (h *ResidencyHandler) CreateExport(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.service.Export(c.Request.Context(), investigationID, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to export investigation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, export)
+}
+
+// ListExports lists the exports of a case
+func (h *ResidencyHandler) ListExports(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	exports, err := h.service.Exports(c.Request.Context(), investigationID)
+	if err != nil {
+		h.handleError(c, err, "Failed to list exports")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exports": exports})
+}
+
+// GetReport builds a residency compliance report covering cross-region
+// requests over the last `days` days
+func (h *ResidencyHandler) GetReport(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	report, err := h.service.Report(c.Request.Context(), since)
+	if err != nil {
+		h.logger.Error("Failed to build residency report", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build residency report"})
+		return
+	}
+
+	h.logger.Info("Residency report generated",
+		zap.Int("days", days),
+		zap.Bool("compliant", report.Compliant),
+		zap.Int("issues", len(report.Issues)))
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *ResidencyHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrCaseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Investigation not found"})
+	case errors.Is(err, repository.ErrGrantNotActive):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrCaseRegionSet), errors.Is(err, residency.ErrNotHomeRegion):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, residency.ErrUnknownRegion), errors.Is(err, residency.ErrInvalidGrant):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (h *ResidencyHandler) requireUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr := c.GetHeader("X-User-ID")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID required"})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
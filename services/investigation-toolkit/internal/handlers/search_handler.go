@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/repository"
+	"investigation-toolkit/internal/search"
+)
+
+// SearchHandler handles search index status and zero-downtime reindexing
+type SearchHandler struct {
+	orchestrator *search.Orchestrator
+	reindexRepo  *repository.SearchReindexRepository
+	logger       *zap.Logger
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(orchestrator *search.Orchestrator, reindexRepo *repository.SearchReindexRepository, logger *zap.Logger) *SearchHandler {
+	return &SearchHandler{
+		orchestrator: orchestrator,
+		reindexRepo:  reindexRepo,
+		logger:       logger.Named("search_handler"),
+	}
+}
+
+// GetIndices returns the serving version and document count of each search index
+func (h *SearchHandler) GetIndices(c *gin.Context) {
+	statuses, err := h.orchestrator.Status(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to get search index status", zap.Error(err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to get search index status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"indices": statuses})
+}
+
+// StartReindex rebuilds an index into a new version with the current mappings
+func (h *SearchHandler) StartReindex(c *gin.Context) {
+	var requestedBy *uuid.UUID
+	if userID, err := uuid.Parse(c.GetHeader("X-User-ID")); err == nil {
+		requestedBy = &userID
+	}
+
+	job, err := h.orchestrator.StartReindex(c.Request.Context(), c.Param("name"), requestedBy)
+	switch {
+	case err == search.ErrUnknownIndex:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown search index"})
+		return
+	case err == repository.ErrReindexInProgress:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		h.logger.Error("Failed to start search reindex", zap.String("index", c.Param("name")), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start reindex"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// ListReindexJobs returns the most recent reindex jobs
+func (h *SearchHandler) ListReindexJobs(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+		return
+	}
+
+	jobs, err := h.reindexRepo.ListRecent(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to list search reindex jobs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list reindex jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+// GetReindexJob returns the progress of a reindex job
+func (h *SearchHandler) GetReindexJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reindex job ID"})
+		return
+	}
+
+	job, err := h.reindexRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reindex job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// CancelReindexJob stops an active reindex and drops its new index version
+func (h *SearchHandler) CancelReindexJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reindex job ID"})
+		return
+	}
+
+	job, err := h.orchestrator.Cancel(c.Request.Context(), id)
+	switch {
+	case err == search.ErrReindexNotActive:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		h.logger.Error("Failed to cancel search reindex", zap.String("job_id", id.String()), zap.Error(err))
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reindex job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
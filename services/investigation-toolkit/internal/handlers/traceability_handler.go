@@ -0,0 +1,477 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+	"investigation-toolkit/internal/traceability"
+)
+
+// TraceabilityHandler handles SAR filings and the validation of their
+// alert → case → evidence → SAR chains
+type TraceabilityHandler struct {
+	filingRepo *repository.SARFilingRepository
+	tracer     *traceability.Tracer
+	config     config.TraceabilityConfig
+	logger     *zap.Logger
+}
+
+// NewTraceabilityHandler creates a new traceability handler
+func NewTraceabilityHandler(filingRepo *repository.SARFilingRepository, tracer *traceability.Tracer, cfg *config.Config, logger *zap.Logger) *TraceabilityHandler {
+	return &TraceabilityHandler{
+		filingRepo: filingRepo,
+		tracer:     tracer,
+		config:     cfg.Traceability,
+		logger:     logger.Named("traceability_handler"),
+	}
+}
+
+// CreateSARFiling drafts a SAR on an investigation, citing the alerts that
+// originated it and the evidence supporting it
+func (h *TraceabilityHandler) CreateSARFiling(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	var req models.CreateSARFilingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if !validFilingType(req.FilingType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported filing type: %s", req.FilingType)})
+		return
+	}
+	if !h.checkPriorFiling(c, investigationID, req.FilingType, req.PriorFilingID) {
+		return
+	}
+	if req.ActivityStart != nil && req.ActivityEnd != nil && req.ActivityEnd.Before(*req.ActivityStart) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "activity_end must not be before activity_start"})
+		return
+	}
+
+	now := time.Now()
+	filing := &models.SARFiling{
+		ID:              uuid.New(),
+		InvestigationID: investigationID,
+		FilingType:      req.FilingType,
+		Status:          models.SARStatusDraft,
+		PriorFilingID:   req.PriorFilingID,
+		AlertIDs:        pq.StringArray(req.AlertIDs),
+		EvidenceIDs:     models.UUIDArray(req.EvidenceIDs),
+		ActivityStart:   req.ActivityStart,
+		ActivityEnd:     req.ActivityEnd,
+		Narrative:       req.Narrative,
+		CreatedBy:       userID,
+		Metadata:        req.Metadata,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if filing.AlertIDs == nil {
+		filing.AlertIDs = pq.StringArray{}
+	}
+	if filing.EvidenceIDs == nil {
+		filing.EvidenceIDs = models.UUIDArray{}
+	}
+
+	if err := h.filingRepo.Create(c.Request.Context(), filing); err != nil {
+		h.logger.Error("Failed to create SAR filing", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create SAR filing"})
+		return
+	}
+
+	h.logger.Info("SAR filing drafted",
+		zap.String("id", filing.ID.String()),
+		zap.String("investigation_id", investigationID.String()),
+		zap.String("filing_type", string(filing.FilingType)))
+
+	c.JSON(http.StatusCreated, filing)
+}
+
+// ListSARFilings returns every SAR raised from an investigation
+func (h *TraceabilityHandler) ListSARFilings(c *gin.Context) {
+	investigationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid investigation ID"})
+		return
+	}
+
+	filings, err := h.filingRepo.GetByInvestigationID(c.Request.Context(), investigationID)
+	if err != nil {
+		h.logger.Error("Failed to list SAR filings", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list SAR filings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"filings": filings,
+		"total":   len(filings),
+	})
+}
+
+// GetSARFiling returns a SAR filing
+func (h *TraceabilityHandler) GetSARFiling(c *gin.Context) {
+	filing, ok := h.loadFiling(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, filing)
+}
+
+// UpdateSARFiling changes the links and details of a draft SAR. Filed
+// reports are immutable; corrections are filed as new reports.
+func (h *TraceabilityHandler) UpdateSARFiling(c *gin.Context) {
+	filing, ok := h.loadFiling(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSARFilingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+
+	if req.AlertIDs != nil {
+		filing.AlertIDs = pq.StringArray(req.AlertIDs)
+	}
+	if req.EvidenceIDs != nil {
+		filing.EvidenceIDs = models.UUIDArray(req.EvidenceIDs)
+	}
+	if req.ActivityStart != nil {
+		filing.ActivityStart = req.ActivityStart
+	}
+	if req.ActivityEnd != nil {
+		filing.ActivityEnd = req.ActivityEnd
+	}
+	if req.Narrative != nil {
+		filing.Narrative = req.Narrative
+	}
+	if req.Metadata != nil {
+		filing.Metadata = req.Metadata
+	}
+	if filing.ActivityStart != nil && filing.ActivityEnd != nil && filing.ActivityEnd.Before(*filing.ActivityStart) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "activity_end must not be before activity_start"})
+		return
+	}
+
+	if err := h.filingRepo.Update(c.Request.Context(), filing); err != nil {
+		h.respondNotDraft(c, err, "Failed to update SAR filing")
+		return
+	}
+
+	c.JSON(http.StatusOK, filing)
+}
+
+// FileSAR records that a draft SAR was filed with the regulator. When a
+// complete chain is required, filing is refused while any link is broken.
+func (h *TraceabilityHandler) FileSAR(c *gin.Context) {
+	filing, ok := h.loadFiling(c)
+	if !ok {
+		return
+	}
+
+	var req models.FileSARRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload", "details": err.Error()})
+		return
+	}
+	if req.Reference == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reference is required"})
+		return
+	}
+
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if filing.Status != models.SARStatusDraft {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only draft SAR filings can be filed"})
+		return
+	}
+
+	filedAt := time.Now().UTC()
+	if req.FiledAt != nil {
+		filedAt = *req.FiledAt
+	}
+
+	if h.config.RequireCompleteChain {
+		filing.FiledAt = &filedAt
+		trace, ok := h.trace(c, filing)
+		if !ok {
+			return
+		}
+		if !trace.Complete {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":  "SAR filing does not trace back to its alerts and evidence",
+				"issues": trace.Issues,
+			})
+			return
+		}
+	}
+
+	if err := h.filingRepo.MarkFiled(c.Request.Context(), filing.ID, req.Reference, filedAt, userID); err != nil {
+		h.respondNotDraft(c, err, "Failed to file SAR")
+		return
+	}
+
+	filing.Status = models.SARStatusFiled
+	filing.Reference = &req.Reference
+	filing.FiledAt = &filedAt
+	filing.FiledBy = &userID
+
+	h.logger.Info("SAR filed",
+		zap.String("id", filing.ID.String()),
+		zap.String("reference", req.Reference),
+		zap.String("filed_by", userID.String()))
+
+	c.JSON(http.StatusOK, filing)
+}
+
+// WithdrawSAR abandons a draft SAR filing
+func (h *TraceabilityHandler) WithdrawSAR(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid SAR filing ID"})
+		return
+	}
+
+	if _, ok := h.requireUser(c); !ok {
+		return
+	}
+
+	if err := h.filingRepo.Withdraw(c.Request.Context(), id); err != nil {
+		h.respondNotDraft(c, err, "Failed to withdraw SAR filing")
+		return
+	}
+
+	h.logger.Info("SAR filing withdrawn", zap.String("id", id.String()))
+	c.JSON(http.StatusOK, gin.H{"message": "SAR filing withdrawn"})
+}
+
+// GetSARTraceability walks and validates the chain of one SAR filing
+func (h *TraceabilityHandler) GetSARTraceability(c *gin.Context) {
+	filing, ok := h.loadFiling(c)
+	if !ok {
+		return
+	}
+
+	trace, ok := h.trace(c, filing)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, trace)
+}
+
+// GetTraceabilityReport validates every SAR filed in a period. from and to
+// are inclusive dates (YYYY-MM-DD); format=csv returns one row per issue.
+func (h *TraceabilityHandler) GetTraceabilityReport(c *gin.Context) {
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or missing from date, expected YYYY-MM-DD"})
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or missing to date, expected YYYY-MM-DD"})
+		return
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+	end := to.AddDate(0, 0, 1)
+	if days := int(end.Sub(from).Hours() / 24); days > h.config.MaxReportDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Report period cannot exceed %d days", h.config.MaxReportDays)})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported format: %s", format)})
+		return
+	}
+
+	ctx := traceability.WithAuthorization(c.Request.Context(), c.GetHeader("Authorization"))
+	report, err := h.tracer.Report(ctx, from, end)
+	if err != nil {
+		h.logger.Error("Failed to build traceability report", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build traceability report"})
+		return
+	}
+
+	h.logger.Info("Traceability report generated",
+		zap.String("from", from.Format("2006-01-02")),
+		zap.String("to", to.Format("2006-01-02")),
+		zap.Int("filings", report.Summary.Filings),
+		zap.Int("incomplete", report.Summary.Incomplete))
+
+	if format == "csv" {
+		h.writeReportCSV(c, report, to)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *TraceabilityHandler) writeReportCSV(c *gin.Context, report *traceability.Report, to time.Time) {
+	filename := fmt.Sprintf("sar-traceability-%s-%s.csv",
+		report.PeriodStart.Format("20060102"), to.Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"filing_id", "reference", "investigation_id", "filed_at", "complete",
+		"issue_code", "severity", "link", "link_reference", "message",
+	})
+	for _, trace := range report.Filings {
+		filing := trace.Filing
+		row := []string{
+			filing.ID.String(),
+			stringValue(filing.Reference),
+			filing.InvestigationID.String(),
+			"",
+			strconv.FormatBool(trace.Complete),
+		}
+		if filing.FiledAt != nil {
+			row[3] = filing.FiledAt.UTC().Format(time.RFC3339)
+		}
+
+		if len(trace.Issues) == 0 {
+			w.Write(append(row, "", "", "", "", ""))
+			continue
+		}
+		for _, issue := range trace.Issues {
+			w.Write(append(row[:5:5], issue.Code, string(issue.Severity), issue.Link, issue.Reference, issue.Message))
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		h.logger.Error("Failed to write traceability report", zap.Error(err))
+	}
+}
+
+func (h *TraceabilityHandler) trace(c *gin.Context, filing *models.SARFiling) (*traceability.Trace, bool) {
+	ctx := traceability.WithAuthorization(c.Request.Context(), c.GetHeader("Authorization"))
+	trace, err := h.tracer.Trace(ctx, filing)
+	if err != nil {
+		h.logger.Error("Failed to trace SAR filing", zap.String("id", filing.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to trace SAR filing"})
+		return nil, false
+	}
+	return trace, true
+}
+
+// checkPriorFiling ensures continuing and correction reports cite a filed
+// SAR from the same investigation
+func (h *TraceabilityHandler) checkPriorFiling(c *gin.Context, investigationID uuid.UUID, filingType models.SARFilingType, priorID *uuid.UUID) bool {
+	if filingType == models.SARFilingTypeInitial {
+		if priorID != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "An initial SAR cannot cite a prior filing"})
+			return false
+		}
+		return true
+	}
+
+	if priorID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A %s SAR must cite its prior filing", filingType)})
+		return false
+	}
+	prior, err := h.filingRepo.GetByID(c.Request.Context(), *priorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Prior SAR filing not found"})
+		return false
+	}
+	if prior.InvestigationID != investigationID || prior.Status != models.SARStatusFiled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Prior SAR filing must be a filed report from the same investigation"})
+		return false
+	}
+	return true
+}
+
+func (h *TraceabilityHandler) loadFiling(c *gin.Context) (*models.SARFiling, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid SAR filing ID"})
+		return nil, false
+	}
+
+	filing, err := h.filingRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SAR filing not found"})
+		return nil, false
+	}
+
+	return filing, true
+}
+
+func (h *TraceabilityHandler) respondNotDraft(c *gin.Context, err error, message string) {
+	if errors.Is(err, repository.ErrSARNotDraft) {
+		c.JSON(http.StatusConflict, gin.H{"error": message, "details": err.Error()})
+		return
+	}
+	h.logger.Error(message, zap.Error(err))
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
+
+func (h *TraceabilityHandler) requireUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr := c.GetHeader("X-User-ID")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID required"})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+func validFilingType(filingType models.SARFilingType) bool {
+	switch filingType {
+	case models.SARFilingTypeInitial,
+		models.SARFilingTypeContinuing,
+		models.SARFilingTypeCorrection:
+		return true
+	default:
+		return false
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
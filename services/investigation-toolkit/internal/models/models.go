@@ -27,34 +27,36 @@ type Investigation struct {
 	DueDate        *time.Time     `json:"due_date,omitempty" db:"due_date"`
 	ClosedAt       *time.Time     `json:"closed_at,omitempty" db:"closed_at"`
 	ArchivedAt     *time.Time     `json:"archived_at,omitempty" db:"archived_at"`
+	Region         *string        `json:"region,omitempty" db:"region"` // data residency region
 }
 
 // Evidence represents a piece of evidence in an investigation
 type Evidence struct {
-	ID                   uuid.UUID      `json:"id" db:"id"`
-	InvestigationID      uuid.UUID      `json:"investigation_id" db:"investigation_id" validate:"required"`
-	Name                 string         `json:"name" db:"name" validate:"required,min=1,max=255"`
-	Description          *string        `json:"description,omitempty" db:"description"`
-	EvidenceType         EvidenceType   `json:"evidence_type" db:"evidence_type" validate:"required"`
-	Source               *string        `json:"source,omitempty" db:"source"`
-	CollectionMethod     *string        `json:"collection_method,omitempty" db:"collection_method"`
-	FilePath             *string        `json:"file_path,omitempty" db:"file_path"`
-	FileSize             *int64         `json:"file_size,omitempty" db:"file_size"`
-	FileHash             *string        `json:"file_hash,omitempty" db:"file_hash"`
-	MimeType             *string        `json:"mime_type,omitempty" db:"mime_type"`
-	CollectedBy          uuid.UUID      `json:"collected_by" db:"collected_by" validate:"required"`
-	CollectedAt          time.Time      `json:"collected_at" db:"collected_at"`
-	ChainOfCustody       JSONB          `json:"chain_of_custody" db:"chain_of_custody"`
-	Metadata             JSONB          `json:"metadata" db:"metadata"`
-	Tags                 pq.StringArray `json:"tags" db:"tags"`
-	IsAuthenticated      bool           `json:"is_authenticated" db:"is_authenticated"`
-	AuthenticationMethod *string        `json:"authentication_method,omitempty" db:"authentication_method"`
-	AuthenticationDate   *time.Time     `json:"authentication_date,omitempty" db:"authentication_date"`
-	AuthenticationBy     *uuid.UUID     `json:"authentication_by,omitempty" db:"authentication_by"`
-	RetentionDate        *time.Time     `json:"retention_date,omitempty" db:"retention_date"`
-	Status               EvidenceStatus `json:"status" db:"status" validate:"required"`
-	CreatedAt            time.Time      `json:"created_at" db:"created_at"`
-	UpdatedAt            time.Time      `json:"updated_at" db:"updated_at"`
+	ID                   uuid.UUID              `json:"id" db:"id"`
+	InvestigationID      uuid.UUID              `json:"investigation_id" db:"investigation_id" validate:"required"`
+	Name                 string                 `json:"name" db:"name" validate:"required,min=1,max=255"`
+	Description          *string                `json:"description,omitempty" db:"description"`
+	EvidenceType         EvidenceType           `json:"evidence_type" db:"evidence_type" validate:"required"`
+	Source               *string                `json:"source,omitempty" db:"source"`
+	CollectionMethod     *string                `json:"collection_method,omitempty" db:"collection_method"`
+	FilePath             *string                `json:"file_path,omitempty" db:"file_path"`
+	FileSize             *int64                 `json:"file_size,omitempty" db:"file_size"`
+	FileHash             *string                `json:"file_hash,omitempty" db:"file_hash"`
+	MimeType             *string                `json:"mime_type,omitempty" db:"mime_type"`
+	CollectedBy          uuid.UUID              `json:"collected_by" db:"collected_by" validate:"required"`
+	CollectedAt          time.Time              `json:"collected_at" db:"collected_at"`
+	ChainOfCustody       JSONB                  `json:"chain_of_custody" db:"chain_of_custody"`
+	Metadata             JSONB                  `json:"metadata" db:"metadata"`
+	Tags                 pq.StringArray         `json:"tags" db:"tags"`
+	IsAuthenticated      bool                   `json:"is_authenticated" db:"is_authenticated"`
+	AuthenticationMethod *string                `json:"authentication_method,omitempty" db:"authentication_method"`
+	AuthenticationDate   *time.Time             `json:"authentication_date,omitempty" db:"authentication_date"`
+	AuthenticationBy     *uuid.UUID             `json:"authentication_by,omitempty" db:"authentication_by"`
+	RetentionDate        *time.Time             `json:"retention_date,omitempty" db:"retention_date"`
+	Status               EvidenceStatus         `json:"status" db:"status" validate:"required"`
+	Classification       EvidenceClassification `json:"classification" db:"classification"`
+	CreatedAt            time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time              `json:"updated_at" db:"updated_at"`
 }
 
 // Timeline represents a timeline event in an investigation
@@ -90,6 +92,7 @@ type Collaboration struct {
 	RemovedBy      *uuid.UUID `json:"removed_by,omitempty" db:"removed_by"`
 	IsActive       bool      `json:"is_active" db:"is_active"`
 	Notes          *string   `json:"notes,omitempty" db:"notes"`
+	Clearance      *EvidenceClassification `json:"clearance,omitempty" db:"clearance"` // highest classification a restricted participant may see
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -187,6 +190,607 @@ type AuditLog struct {
 	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
 }
 
+// EvidenceRequest represents a request for documents sent to an external party
+type EvidenceRequest struct {
+	ID               uuid.UUID             `json:"id" db:"id"`
+	InvestigationID  uuid.UUID             `json:"investigation_id" db:"investigation_id" validate:"required"`
+	TokenHash        string                `json:"-" db:"token_hash"`
+	RecipientName    string                `json:"recipient_name" db:"recipient_name" validate:"required,min=1,max=255"`
+	RecipientEmail   *string               `json:"recipient_email,omitempty" db:"recipient_email"`
+	RecipientType    RecipientType         `json:"recipient_type" db:"recipient_type" validate:"required"`
+	Instructions     *string               `json:"instructions,omitempty" db:"instructions"`
+	AllowedFileTypes pq.StringArray        `json:"allowed_file_types" db:"allowed_file_types"`
+	MaxFiles         int                   `json:"max_files" db:"max_files"`
+	MaxFileSize      int64                 `json:"max_file_size" db:"max_file_size"`
+	FilesReceived    int                   `json:"files_received" db:"files_received"`
+	Status           EvidenceRequestStatus `json:"status" db:"status"`
+	ExpiresAt        time.Time             `json:"expires_at" db:"expires_at"`
+	RequestedBy      uuid.UUID             `json:"requested_by" db:"requested_by"`
+	TenantID         string                `json:"tenant_id" db:"tenant_id"` // charged for the files received
+	LastSubmissionAt *time.Time            `json:"last_submission_at,omitempty" db:"last_submission_at"`
+	RevokedAt        *time.Time            `json:"revoked_at,omitempty" db:"revoked_at"`
+	RevokedBy        *uuid.UUID            `json:"revoked_by,omitempty" db:"revoked_by"`
+	Metadata         JSONB                 `json:"metadata" db:"metadata"`
+	CreatedAt        time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// EvidenceRequestSubmission represents a file received through an evidence request link
+type EvidenceRequestSubmission struct {
+	ID            uuid.UUID        `json:"id" db:"id"`
+	RequestID     uuid.UUID        `json:"request_id" db:"request_id"`
+	EvidenceID    *uuid.UUID       `json:"evidence_id,omitempty" db:"evidence_id"`
+	FileName      string           `json:"file_name" db:"file_name"`
+	FileSize      int64            `json:"file_size" db:"file_size"`
+	FileHash      string           `json:"file_hash" db:"file_hash"`
+	MimeType      *string          `json:"mime_type,omitempty" db:"mime_type"`
+	ScanStatus    ScanStatus       `json:"scan_status" db:"scan_status"`
+	ScanResult    *string          `json:"scan_result,omitempty" db:"scan_result"`
+	Status        SubmissionStatus `json:"status" db:"status"`
+	SubmitterIP   *string          `json:"submitter_ip,omitempty" db:"submitter_ip"`
+	SubmitterNote *string          `json:"submitter_note,omitempty" db:"submitter_note"`
+	SubmittedAt   time.Time        `json:"submitted_at" db:"submitted_at"`
+}
+
+// CalendarEvent represents a scheduled interview, filing deadline or court date on a case
+type CalendarEvent struct {
+	ID              uuid.UUID           `json:"id" db:"id"`
+	InvestigationID uuid.UUID           `json:"investigation_id" db:"investigation_id" validate:"required"`
+	EventType       CalendarEventType   `json:"event_type" db:"event_type" validate:"required"`
+	Title           string              `json:"title" db:"title" validate:"required,min=1,max=255"`
+	Description     *string             `json:"description,omitempty" db:"description"`
+	Location        *string             `json:"location,omitempty" db:"location"`
+	StartsAt        time.Time           `json:"starts_at" db:"starts_at" validate:"required"`
+	EndsAt          time.Time           `json:"ends_at" db:"ends_at" validate:"required"`
+	AllDay          bool                `json:"all_day" db:"all_day"`
+	OrganizerID     uuid.UUID           `json:"organizer_id" db:"organizer_id"`
+	Attendees       UUIDArray           `json:"attendees" db:"attendees"`
+	ReminderMinutes pq.Int64Array       `json:"reminder_minutes" db:"reminder_minutes"`
+	Status          CalendarEventStatus `json:"status" db:"status"`
+	Sequence        int                 `json:"sequence" db:"sequence"`
+	CancelledAt     *time.Time          `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CancelledBy     *uuid.UUID          `json:"cancelled_by,omitempty" db:"cancelled_by"`
+	Metadata        JSONB               `json:"metadata" db:"metadata"`
+	CreatedAt       time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// CalendarReminder represents a reminder owed to one participant of a calendar event
+type CalendarReminder struct {
+	ID            uuid.UUID              `json:"id" db:"id"`
+	EventID       uuid.UUID              `json:"event_id" db:"event_id"`
+	UserID        uuid.UUID              `json:"user_id" db:"user_id"`
+	MinutesBefore int                    `json:"minutes_before" db:"minutes_before"`
+	RemindAt      time.Time              `json:"remind_at" db:"remind_at"`
+	Status        CalendarReminderStatus `json:"status" db:"status"`
+	SentAt        *time.Time             `json:"sent_at,omitempty" db:"sent_at"`
+	CreatedAt     time.Time              `json:"created_at" db:"created_at"`
+}
+
+// EvidenceStorageObject tracks where an evidence file is stored and which storage tier holds it
+type EvidenceStorageObject struct {
+	EvidenceID         uuid.UUID     `json:"evidence_id" db:"evidence_id"`
+	SourcePath         string        `json:"source_path" db:"source_path"`
+	ObjectKey          string        `json:"object_key" db:"object_key"`
+	FileSize           *int64        `json:"file_size,omitempty" db:"file_size"`
+	Tier               StorageTier   `json:"tier" db:"tier"`
+	TierChangedAt      time.Time     `json:"tier_changed_at" db:"tier_changed_at"`
+	RestoreStatus      RestoreStatus `json:"restore_status" db:"restore_status"`
+	RestoreRequestedAt *time.Time    `json:"restore_requested_at,omitempty" db:"restore_requested_at"`
+	RestoreRequestedBy *uuid.UUID    `json:"restore_requested_by,omitempty" db:"restore_requested_by"`
+	RestoreReadyAt     *time.Time    `json:"restore_ready_at,omitempty" db:"restore_ready_at"`
+	RestoreExpiresAt   *time.Time    `json:"restore_expires_at,omitempty" db:"restore_expires_at"`
+	LastAccessedAt     *time.Time    `json:"last_accessed_at,omitempty" db:"last_accessed_at"`
+	CreatedAt          time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// ReindexStatus is the phase of a search reindex job
+type ReindexStatus string
+
+const (
+	ReindexStatusDualWriting ReindexStatus = "dual_writing"
+	ReindexStatusBackfilling ReindexStatus = "backfilling"
+	ReindexStatusCompleted   ReindexStatus = "completed"
+	ReindexStatusFailed      ReindexStatus = "failed"
+	ReindexStatusCancelled   ReindexStatus = "cancelled"
+)
+
+// SearchReindexJob tracks rebuilding a search index into a new versioned index
+type SearchReindexJob struct {
+	ID                uuid.UUID     `json:"id" db:"id"`
+	IndexName         string        `json:"index_name" db:"index_name"`
+	SourceIndex       string        `json:"source_index" db:"source_index"`
+	TargetIndex       string        `json:"target_index" db:"target_index"`
+	Status            ReindexStatus `json:"status" db:"status"`
+	TaskID            *string       `json:"task_id,omitempty" db:"task_id"`
+	DocumentsTotal    int64         `json:"documents_total" db:"documents_total"`
+	DocumentsCopied   int64         `json:"documents_copied" db:"documents_copied"`
+	Error             *string       `json:"error,omitempty" db:"error"`
+	RequestedBy       *uuid.UUID    `json:"requested_by,omitempty" db:"requested_by"`
+	BackfillStartedAt *time.Time    `json:"backfill_started_at,omitempty" db:"backfill_started_at"`
+	CompletedAt       *time.Time    `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt         time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// Active reports whether the job still dual-writes into its target index
+func (j *SearchReindexJob) Active() bool {
+	return j.Status == ReindexStatusDualWriting || j.Status == ReindexStatusBackfilling
+}
+
+// SARFiling is a suspicious activity report raised from an investigation. It
+// cites the alerts that originated it and the evidence supporting it, so
+// every filing can be traced back for auditors.
+type SARFiling struct {
+	ID              uuid.UUID      `json:"id" db:"id"`
+	InvestigationID uuid.UUID      `json:"investigation_id" db:"investigation_id" validate:"required"`
+	FilingType      SARFilingType  `json:"filing_type" db:"filing_type" validate:"required"`
+	Status          SARStatus      `json:"status" db:"status"`
+	Reference       *string        `json:"reference,omitempty" db:"reference"` // regulator's acknowledgement or BSA ID
+	PriorFilingID   *uuid.UUID     `json:"prior_filing_id,omitempty" db:"prior_filing_id"`
+	AlertIDs        pq.StringArray `json:"alert_ids" db:"alert_ids"` // alerting-engine alert IDs
+	EvidenceIDs     UUIDArray      `json:"evidence_ids" db:"evidence_ids"`
+	ActivityStart   *time.Time     `json:"activity_start,omitempty" db:"activity_start"`
+	ActivityEnd     *time.Time     `json:"activity_end,omitempty" db:"activity_end"`
+	Narrative       *string        `json:"narrative,omitempty" db:"narrative"`
+	FiledAt         *time.Time     `json:"filed_at,omitempty" db:"filed_at"`
+	FiledBy         *uuid.UUID     `json:"filed_by,omitempty" db:"filed_by"`
+	CreatedBy       uuid.UUID      `json:"created_by" db:"created_by"`
+	Metadata        JSONB          `json:"metadata" db:"metadata"`
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// ResidencyGrant authorizes a user in another region to access a case pinned
+// to its home region until the grant expires or is revoked
+type ResidencyGrant struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	InvestigationID uuid.UUID  `json:"investigation_id" db:"investigation_id"`
+	Region          string     `json:"region" db:"region"` // region the access comes from
+	GrantedTo       uuid.UUID  `json:"granted_to" db:"granted_to"`
+	Reason          string     `json:"reason" db:"reason"`
+	GrantedBy       uuid.UUID  `json:"granted_by" db:"granted_by"`
+	ExpiresAt       time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	RevokedBy       *uuid.UUID `json:"revoked_by,omitempty" db:"revoked_by"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Active reports whether the grant currently authorizes access
+func (g *ResidencyGrant) Active(now time.Time) bool {
+	return g.RevokedAt == nil && now.Before(g.ExpiresAt)
+}
+
+// ResidencyAccess records a request for a case from outside its home region
+type ResidencyAccess struct {
+	InvestigationID uuid.UUID         `json:"investigation_id" db:"investigation_id"`
+	CaseRegion      string            `json:"case_region" db:"case_region"`
+	RequestRegion   string            `json:"request_region" db:"request_region"`
+	UserID          *uuid.UUID        `json:"user_id,omitempty" db:"user_id"`
+	Method          string            `json:"method" db:"method"`
+	Path            string            `json:"path" db:"path"`
+	Decision        ResidencyDecision `json:"decision" db:"decision"`
+	GrantID         *uuid.UUID        `json:"grant_id,omitempty" db:"grant_id"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+}
+
+// CaseExport is a case bundle written to the storage of the case's region
+type CaseExport struct {
+	ID              uuid.UUID `json:"id" db:"id"`
+	InvestigationID uuid.UUID `json:"investigation_id" db:"investigation_id"`
+	Region          string    `json:"region" db:"region"`
+	FilePath        string    `json:"file_path" db:"file_path"`
+	FileSize        *int64    `json:"file_size,omitempty" db:"file_size"`
+	FileHash        *string   `json:"file_hash,omitempty" db:"file_hash"`
+	ExportedBy      uuid.UUID `json:"exported_by" db:"exported_by"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// EvidenceClassificationChange records an evidence item being reclassified
+type EvidenceClassificationChange struct {
+	ID                 uuid.UUID              `json:"id" db:"id"`
+	EvidenceID         uuid.UUID              `json:"evidence_id" db:"evidence_id"`
+	FromClassification EvidenceClassification `json:"from_classification" db:"from_classification"`
+	ToClassification   EvidenceClassification `json:"to_classification" db:"to_classification"`
+	Reason             string                 `json:"reason" db:"reason"`
+	ChangedBy          uuid.UUID              `json:"changed_by" db:"changed_by"`
+	CreatedAt          time.Time              `json:"created_at" db:"created_at"`
+}
+
+// AccessMatrix shows which participants of a case can see which of its
+// evidence. Unrestricted participants see every classification.
+type AccessMatrix struct {
+	InvestigationID uuid.UUID             `json:"investigation_id"`
+	GeneratedAt     time.Time             `json:"generated_at"`
+	Levels          []ClassificationCount `json:"levels"`
+	Participants    []ParticipantAccess   `json:"participants"`
+	Evidence        []EvidenceAccess      `json:"evidence"`
+}
+
+// ClassificationCount is the number of a case's evidence items at one classification
+type ClassificationCount struct {
+	Classification EvidenceClassification `json:"classification"`
+	Evidence       int                    `json:"evidence"`
+}
+
+// ParticipantAccess is one row of an access matrix
+type ParticipantAccess struct {
+	UserID          uuid.UUID                `json:"user_id"`
+	Role            Role                     `json:"role"`
+	Restricted      bool                     `json:"restricted"`
+	Clearance       *EvidenceClassification  `json:"clearance,omitempty"`
+	Visible         []EvidenceClassification `json:"visible_classifications"`
+	VisibleEvidence int                      `json:"visible_evidence"`
+	HiddenEvidence  int                      `json:"hidden_evidence"`
+}
+
+// EvidenceAccess lists the participants an evidence item is hidden from
+type EvidenceAccess struct {
+	EvidenceID     uuid.UUID              `json:"evidence_id"`
+	Name           string                 `json:"name"`
+	Classification EvidenceClassification `json:"classification"`
+	HiddenFrom     []uuid.UUID            `json:"hidden_from"`
+}
+
+// QuestionnaireTemplate is a reusable form, such as the questionnaire of a
+// periodic KYC refresh. Templates are edited as drafts; once published they
+// are frozen and can be assigned to review cases.
+type QuestionnaireTemplate struct {
+	ID          uuid.UUID                   `json:"id" db:"id"`
+	Name        string                      `json:"name" db:"name"`
+	Description *string                     `json:"description,omitempty" db:"description"`
+	Status      QuestionnaireTemplateStatus `json:"status" db:"status"`
+	Questions   Questions                   `json:"questions" db:"questions"`
+	CreatedBy   uuid.UUID                   `json:"created_by" db:"created_by"`
+	PublishedAt *time.Time                  `json:"published_at,omitempty" db:"published_at"`
+	CreatedAt   time.Time                   `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time                   `json:"updated_at" db:"updated_at"`
+}
+
+// Question is one field of a questionnaire. A question with ShowIf is only
+// asked, and only required, when the condition on an earlier answer holds.
+type Question struct {
+	ID       string             `json:"id"`
+	Text     string             `json:"text"`
+	Help     string             `json:"help,omitempty"`
+	Type     QuestionType       `json:"type"`
+	Required bool               `json:"required"`
+	Options  []QuestionOption   `json:"options,omitempty"`
+	Min      *float64           `json:"min,omitempty"` // number questions
+	Max      *float64           `json:"max,omitempty"`
+	ShowIf   *QuestionCondition `json:"show_if,omitempty"`
+}
+
+// QuestionOption is a choice offered by a choice question
+type QuestionOption struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// QuestionCondition holds when the answer to QuestionID is, or for multiple
+// choice questions includes, one of AnyOf. Yes/no answers match "yes" or "no".
+type QuestionCondition struct {
+	QuestionID string   `json:"question_id"`
+	AnyOf      []string `json:"any_of"`
+}
+
+// Questions is stored as a JSON array
+type Questions []Question
+
+func (q Questions) Value() (driver.Value, error) {
+	if q == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(q)
+}
+
+func (q *Questions) Scan(value interface{}) error {
+	if value == nil {
+		*q = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return json.Unmarshal([]byte(value.(string)), q)
+	}
+	return json.Unmarshal(bytes, q)
+}
+
+// QuestionnaireAssignment is a questionnaire given to a user within a review
+// case. It keeps its own copy of the template's questions, so answers stay
+// readable however the template library changes.
+type QuestionnaireAssignment struct {
+	ID              uuid.UUID           `json:"id" db:"id"`
+	InvestigationID uuid.UUID           `json:"investigation_id" db:"investigation_id"`
+	TemplateID      uuid.UUID           `json:"template_id" db:"template_id"`
+	TemplateName    string              `json:"template_name" db:"template_name"`
+	Questions       Questions           `json:"questions" db:"questions"`
+	Answers         JSONB               `json:"answers" db:"answers"`
+	Status          QuestionnaireStatus `json:"status" db:"status"`
+	AssignedTo      uuid.UUID           `json:"assigned_to" db:"assigned_to"`
+	AssignedBy      uuid.UUID           `json:"assigned_by" db:"assigned_by"`
+	DueDate         *time.Time          `json:"due_date,omitempty" db:"due_date"`
+	SubmittedBy     *uuid.UUID          `json:"submitted_by,omitempty" db:"submitted_by"`
+	SubmittedAt     *time.Time          `json:"submitted_at,omitempty" db:"submitted_at"`
+	CancelledBy     *uuid.UUID          `json:"cancelled_by,omitempty" db:"cancelled_by"`
+	CancelledAt     *time.Time          `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CreatedAt       time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// Open reports whether the assignment still takes responses
+func (a *QuestionnaireAssignment) Open() bool {
+	return a.Status == QuestionnaireStatusAssigned || a.Status == QuestionnaireStatusInProgress
+}
+
+// QuestionnaireReport is the questionnaire section of a case report: every
+// completed questionnaire of the case with the questions that were asked
+// and their answers
+type QuestionnaireReport struct {
+	InvestigationID uuid.UUID                  `json:"investigation_id"`
+	GeneratedAt     time.Time                  `json:"generated_at"`
+	Questionnaires  []QuestionnaireReportEntry `json:"questionnaires"`
+}
+
+// QuestionnaireReportEntry is one completed questionnaire in a case report
+type QuestionnaireReportEntry struct {
+	AssignmentID uuid.UUID                 `json:"assignment_id"`
+	TemplateName string                    `json:"template_name"`
+	AssignedTo   uuid.UUID                 `json:"assigned_to"`
+	SubmittedBy  *uuid.UUID                `json:"submitted_by,omitempty"`
+	SubmittedAt  *time.Time                `json:"submitted_at,omitempty"`
+	Answers      []QuestionnaireReportItem `json:"answers"`
+}
+
+// QuestionnaireReportItem is a question that was asked and its answer as
+// shown to readers of the report
+type QuestionnaireReportItem struct {
+	QuestionID string `json:"question_id"`
+	Question   string `json:"question"`
+	Answer     string `json:"answer"`
+}
+
+// StorageTierStats summarizes tracked evidence files in one storage tier
+type StorageTierStats struct {
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// CalendarConflict describes an existing event that overlaps a proposed one for shared participants
+type CalendarConflict struct {
+	EventID         uuid.UUID         `json:"event_id"`
+	InvestigationID uuid.UUID         `json:"investigation_id"`
+	EventType       CalendarEventType `json:"event_type"`
+	Title           string            `json:"title"`
+	StartsAt        time.Time         `json:"starts_at"`
+	EndsAt          time.Time         `json:"ends_at"`
+	Users           []uuid.UUID       `json:"users"`
+}
+
+// CaseArchive records a closed case being archived and, once it is re-opened,
+// who re-opened it and why. The evidence references of the case at the time it
+// was archived are kept as a compressed manifest.
+type CaseArchive struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	InvestigationID  uuid.UUID  `json:"investigation_id" db:"investigation_id"`
+	ArchivedBy       *uuid.UUID `json:"archived_by,omitempty" db:"archived_by"` // nil when archived by the sweep
+	ArchiveReason    string     `json:"archive_reason" db:"archive_reason"`
+	ClosedAt         time.Time  `json:"closed_at" db:"closed_at"`
+	EvidenceCount    int        `json:"evidence_count" db:"evidence_count"`
+	EvidenceManifest []byte     `json:"-" db:"evidence_manifest"`
+	ManifestSHA256   string     `json:"manifest_sha256" db:"manifest_sha256"`
+	ArchivedAt       time.Time  `json:"archived_at" db:"archived_at"`
+	ReopenedAt       *time.Time `json:"reopened_at,omitempty" db:"reopened_at"`
+	ReopenedBy       *uuid.UUID `json:"reopened_by,omitempty" db:"reopened_by"`
+	ReopenReason     *string    `json:"reopen_reason,omitempty" db:"reopen_reason"`
+}
+
+// LawEnforcementProduction is a selection of case artifacts to be produced
+// to a law-enforcement agency in the layout of a production template. A
+// production is drafted with its redactions, approved by someone other than
+// the requester and then produced once; the produced file is kept as sent.
+type LawEnforcementProduction struct {
+	ID               uuid.UUID            `json:"id" db:"id"`
+	InvestigationID  uuid.UUID            `json:"investigation_id" db:"investigation_id"`
+	TemplateID       string               `json:"template_id" db:"template_id"`
+	Agency           string               `json:"agency" db:"agency"`
+	RequestReference string               `json:"request_reference" db:"request_reference"` // subpoena, order or request number
+	EvidenceIDs      UUIDArray            `json:"evidence_ids" db:"evidence_ids"`
+	TimelineIDs      UUIDArray            `json:"timeline_ids" db:"timeline_ids"`
+	SARFilingIDs     UUIDArray            `json:"sar_filing_ids" db:"sar_filing_ids"`
+	Redactions       ProductionRedactions `json:"redactions" db:"redactions"`
+	Status           ProductionStatus     `json:"status" db:"status"`
+	RequestedBy      uuid.UUID            `json:"requested_by" db:"requested_by"`
+	ApprovedBy       *uuid.UUID           `json:"approved_by,omitempty" db:"approved_by"`
+	ApprovedAt       *time.Time           `json:"approved_at,omitempty" db:"approved_at"`
+	ProducedBy       *uuid.UUID           `json:"produced_by,omitempty" db:"produced_by"`
+	ProducedAt       *time.Time           `json:"produced_at,omitempty" db:"produced_at"`
+	FileName         *string              `json:"file_name,omitempty" db:"file_name"`
+	ContentType      *string              `json:"content_type,omitempty" db:"content_type"`
+	FileSize         *int64               `json:"file_size,omitempty" db:"file_size"`
+	FileSHA256       *string              `json:"file_sha256,omitempty" db:"file_sha256"`
+	Content          []byte               `json:"-" db:"content"`
+	CreatedAt        time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at" db:"updated_at"`
+}
+
+// ProductionRedaction withholds one field of the produced artifacts. Without
+// an ArtifactID the field is withheld from every artifact of the source.
+type ProductionRedaction struct {
+	Source     string     `json:"source"`
+	ArtifactID *uuid.UUID `json:"artifact_id,omitempty"`
+	Field      string     `json:"field"`
+	Reason     string     `json:"reason"`
+}
+
+// ProductionRedactions is stored as a JSON array
+type ProductionRedactions []ProductionRedaction
+
+func (r ProductionRedactions) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
+
+func (r *ProductionRedactions) Scan(value interface{}) error {
+	if value == nil {
+		*r = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return json.Unmarshal([]byte(value.(string)), r)
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// StorageQuotaUsage is the evidence storage a tenant or user holds. Reserved
+// bytes and files belong to uploads in progress. LimitBytes and LimitFiles
+// are set only when the tenant or user has limits of their own in place of
+// the configured defaults.
+type StorageQuotaUsage struct {
+	Scope         string    `json:"scope" db:"scope"`
+	SubjectID     string    `json:"subject_id" db:"subject_id"`
+	UsedBytes     int64     `json:"used_bytes" db:"used_bytes"`
+	UsedFiles     int64     `json:"used_files" db:"used_files"`
+	ReservedBytes int64     `json:"reserved_bytes" db:"reserved_bytes"`
+	ReservedFiles int64     `json:"reserved_files" db:"reserved_files"`
+	LimitBytes    *int64    `json:"limit_bytes,omitempty" db:"limit_bytes"`
+	LimitFiles    *int64    `json:"limit_files,omitempty" db:"limit_files"`
+	WarnedLevel   string    `json:"warned_level" db:"warned_level"` // the level uploaders were last notified of
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// StorageQuotaLimit replaces the configured limits for one tenant or user.
+// A nil limit keeps the default; zero is unlimited.
+type StorageQuotaLimit struct {
+	Scope      string    `json:"scope" db:"scope"`
+	SubjectID  string    `json:"subject_id" db:"subject_id"`
+	LimitBytes *int64    `json:"limit_bytes,omitempty" db:"limit_bytes"`
+	LimitFiles *int64    `json:"limit_files,omitempty" db:"limit_files"`
+	UpdatedBy  uuid.UUID `json:"updated_by" db:"updated_by"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// StorageReservation holds quota for an upload in progress until the upload
+// is stored or fails
+type StorageReservation struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	TenantID  string    `json:"tenant_id" db:"tenant_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Bytes     int64     `json:"bytes" db:"bytes"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// StorageCharge is the quota a stored file takes from its tenant and uploader
+type StorageCharge struct {
+	ObjectType string    `json:"object_type" db:"object_type"`
+	ObjectID   uuid.UUID `json:"object_id" db:"object_id"`
+	TenantID   string    `json:"tenant_id" db:"tenant_id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	Bytes      int64     `json:"bytes" db:"bytes"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// OfflineSyncSession is one upload of evidence collected offline on a field
+// device. The device sends a manifest of the items it collected with their
+// local hashes, uploads each file in chunks, resuming where it left off
+// after a dropped connection, and completes the session to turn the items
+// into evidence.
+type OfflineSyncSession struct {
+	ID               uuid.UUID         `json:"id" db:"id"`
+	InvestigationID  uuid.UUID         `json:"investigation_id" db:"investigation_id"`
+	DeviceID         string            `json:"device_id" db:"device_id"`
+	ManifestHash     string            `json:"manifest_hash" db:"manifest_hash"`
+	OfflineFrom      time.Time         `json:"offline_from" db:"offline_from"`
+	OfflineUntil     time.Time         `json:"offline_until" db:"offline_until"`
+	ConflictStrategy ConflictStrategy  `json:"conflict_strategy" db:"conflict_strategy"`
+	ChunkSize        int64             `json:"chunk_size" db:"chunk_size"`
+	Status           OfflineSyncStatus `json:"status" db:"status"`
+	TenantID         string            `json:"tenant_id" db:"tenant_id"` // charged for the synced files
+	CreatedBy        uuid.UUID         `json:"created_by" db:"created_by"`
+	ExpiresAt        time.Time         `json:"expires_at" db:"expires_at"`
+	CompletedAt      *time.Time        `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt        time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// OfflineSyncItem is one evidence file in an offline sync session. Items
+// that revise evidence already on the server name it as their base, with
+// the hash the device last saw, so that edits made on both sides while the
+// device was offline are detected.
+type OfflineSyncItem struct {
+	ID                 uuid.UUID             `json:"id" db:"id"`
+	SessionID          uuid.UUID             `json:"session_id" db:"session_id"`
+	ClientItemID       string                `json:"client_item_id" db:"client_item_id"`
+	Name               string                `json:"name" db:"name"`
+	Description        *string               `json:"description,omitempty" db:"description"`
+	EvidenceType       EvidenceType          `json:"evidence_type" db:"evidence_type"`
+	FileName           string                `json:"file_name" db:"file_name"`
+	FileSize           int64                 `json:"file_size" db:"file_size"`
+	FileHash           string                `json:"file_hash" db:"file_hash"`
+	MimeType           *string               `json:"mime_type,omitempty" db:"mime_type"`
+	CollectedAt        time.Time             `json:"collected_at" db:"collected_at"`
+	BaseEvidenceID     *uuid.UUID            `json:"base_evidence_id,omitempty" db:"base_evidence_id"`
+	BaseFileHash       *string               `json:"base_file_hash,omitempty" db:"base_file_hash"`
+	LocalCustody       OfflineCustodyEntries `json:"local_custody" db:"local_custody"`
+	Metadata           JSONB                 `json:"metadata" db:"metadata"`
+	Tags               pq.StringArray        `json:"tags" db:"tags"`
+	TotalChunks        int                   `json:"total_chunks" db:"total_chunks"`
+	Status             OfflineItemStatus     `json:"status" db:"status"`
+	StatusReason       *string               `json:"status_reason,omitempty" db:"status_reason"`
+	EvidenceID         *uuid.UUID            `json:"evidence_id,omitempty" db:"evidence_id"`
+	ConflictEvidenceID *uuid.UUID            `json:"conflict_evidence_id,omitempty" db:"conflict_evidence_id"`
+	SyncedAt           *time.Time            `json:"synced_at,omitempty" db:"synced_at"`
+	CreatedAt          time.Time             `json:"created_at" db:"created_at"`
+}
+
+// OfflineSyncChunk records a verified chunk of an offline sync item
+type OfflineSyncChunk struct {
+	ItemID     uuid.UUID `json:"item_id" db:"item_id"`
+	ChunkIndex int       `json:"chunk_index" db:"chunk_index"`
+	Size       int64     `json:"size" db:"size"`
+	ChunkHash  string    `json:"chunk_hash" db:"chunk_hash"`
+	ReceivedAt time.Time `json:"received_at" db:"received_at"`
+}
+
+// OfflineCustodyEntry is a chain of custody entry recorded on the field
+// device while it was offline
+type OfflineCustodyEntry struct {
+	Action    string    `json:"action"`
+	Timestamp time.Time `json:"timestamp"`
+	Location  string    `json:"location,omitempty"`
+	Notes     string    `json:"notes,omitempty"`
+}
+
+// OfflineCustodyEntries is stored as a JSON array
+type OfflineCustodyEntries []OfflineCustodyEntry
+
+func (e OfflineCustodyEntries) Value() (driver.Value, error) {
+	if e == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(e)
+}
+
+func (e *OfflineCustodyEntries) Scan(value interface{}) error {
+	if value == nil {
+		*e = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return json.Unmarshal([]byte(value.(string)), e)
+	}
+	return json.Unmarshal(bytes, e)
+}
+
 // Enum types
 type CaseType string
 
@@ -262,6 +866,9 @@ const (
 	RoleReviewer         Role = "reviewer"
 	RoleObserver         Role = "observer"
 	RoleConsultant       Role = "consultant"
+	// RoleExternalCounsel is outside counsel; they only see evidence up to
+	// their clearance
+	RoleExternalCounsel Role = "external_counsel"
 )
 
 type CommentType string
@@ -315,6 +922,190 @@ const (
 	StepStatusCancelled  StepStatus = "cancelled"
 )
 
+type RecipientType string
+
+const (
+	RecipientTypeBankBranch        RecipientType = "bank_branch"
+	RecipientTypeCustomer          RecipientType = "customer"
+	RecipientTypeCorrespondentBank RecipientType = "correspondent_bank"
+	RecipientTypeLawEnforcement    RecipientType = "law_enforcement"
+	RecipientTypeOther             RecipientType = "other"
+)
+
+type EvidenceRequestStatus string
+
+const (
+	EvidenceRequestStatusPending           EvidenceRequestStatus = "pending"
+	EvidenceRequestStatusPartiallyReceived EvidenceRequestStatus = "partially_received"
+	EvidenceRequestStatusFulfilled         EvidenceRequestStatus = "fulfilled"
+	EvidenceRequestStatusExpired           EvidenceRequestStatus = "expired"
+	EvidenceRequestStatusRevoked           EvidenceRequestStatus = "revoked"
+)
+
+type ScanStatus string
+
+const (
+	ScanStatusClean    ScanStatus = "clean"
+	ScanStatusInfected ScanStatus = "infected"
+	ScanStatusError    ScanStatus = "error"
+	ScanStatusSkipped  ScanStatus = "skipped"
+)
+
+type SubmissionStatus string
+
+const (
+	SubmissionStatusAccepted    SubmissionStatus = "accepted"
+	SubmissionStatusQuarantined SubmissionStatus = "quarantined"
+	SubmissionStatusRejected    SubmissionStatus = "rejected"
+)
+
+type CalendarEventType string
+
+const (
+	CalendarEventTypeInterview      CalendarEventType = "interview"
+	CalendarEventTypeFilingDeadline CalendarEventType = "filing_deadline"
+	CalendarEventTypeCourtDate      CalendarEventType = "court_date"
+	CalendarEventTypeMeeting        CalendarEventType = "meeting"
+	CalendarEventTypeOther          CalendarEventType = "other"
+)
+
+type CalendarEventStatus string
+
+const (
+	CalendarEventStatusScheduled CalendarEventStatus = "scheduled"
+	CalendarEventStatusCompleted CalendarEventStatus = "completed"
+	CalendarEventStatusCancelled CalendarEventStatus = "cancelled"
+)
+
+type CalendarReminderStatus string
+
+const (
+	CalendarReminderStatusPending   CalendarReminderStatus = "pending"
+	CalendarReminderStatusSent      CalendarReminderStatus = "sent"
+	CalendarReminderStatusCancelled CalendarReminderStatus = "cancelled"
+)
+
+type SARFilingType string
+
+const (
+	SARFilingTypeInitial    SARFilingType = "initial"
+	SARFilingTypeContinuing SARFilingType = "continuing"
+	SARFilingTypeCorrection SARFilingType = "correction"
+)
+
+type SARStatus string
+
+const (
+	SARStatusDraft     SARStatus = "draft"
+	SARStatusFiled     SARStatus = "filed"
+	SARStatusWithdrawn SARStatus = "withdrawn"
+)
+
+type StorageTier string
+
+const (
+	StorageTierStandard         StorageTier = "standard"
+	StorageTierInfrequentAccess StorageTier = "infrequent_access"
+	StorageTierArchive          StorageTier = "archive"
+)
+
+type RestoreStatus string
+
+const (
+	RestoreStatusNone       RestoreStatus = "none"
+	RestoreStatusInProgress RestoreStatus = "in_progress"
+	RestoreStatusRestored   RestoreStatus = "restored"
+)
+
+// EvidenceClassification levels, from least to most sensitive
+type EvidenceClassification string
+
+const (
+	ClassificationPublic       EvidenceClassification = "public"
+	ClassificationInternal     EvidenceClassification = "internal"
+	ClassificationConfidential EvidenceClassification = "confidential"
+	ClassificationRestricted   EvidenceClassification = "restricted"
+)
+
+type ResidencyDecision string
+
+const (
+	ResidencyDecisionAllowed ResidencyDecision = "allowed"
+	ResidencyDecisionDenied  ResidencyDecision = "denied"
+)
+
+type QuestionType string
+
+const (
+	QuestionTypeText           QuestionType = "text"
+	QuestionTypeLongText       QuestionType = "long_text"
+	QuestionTypeNumber         QuestionType = "number"
+	QuestionTypeDate           QuestionType = "date"
+	QuestionTypeYesNo          QuestionType = "yes_no"
+	QuestionTypeSingleChoice   QuestionType = "single_choice"
+	QuestionTypeMultipleChoice QuestionType = "multiple_choice"
+)
+
+type QuestionnaireTemplateStatus string
+
+const (
+	QuestionnaireTemplateStatusDraft     QuestionnaireTemplateStatus = "draft"
+	QuestionnaireTemplateStatusPublished QuestionnaireTemplateStatus = "published"
+	QuestionnaireTemplateStatusArchived  QuestionnaireTemplateStatus = "archived"
+)
+
+type QuestionnaireStatus string
+
+const (
+	QuestionnaireStatusAssigned   QuestionnaireStatus = "assigned"
+	QuestionnaireStatusInProgress QuestionnaireStatus = "in_progress"
+	QuestionnaireStatusCompleted  QuestionnaireStatus = "completed"
+	QuestionnaireStatusCancelled  QuestionnaireStatus = "cancelled"
+)
+
+type ProductionStatus string
+
+const (
+	ProductionStatusDraft    ProductionStatus = "draft"
+	ProductionStatusApproved ProductionStatus = "approved"
+	ProductionStatusProduced ProductionStatus = "produced"
+)
+
+type OfflineSyncStatus string
+
+const (
+	OfflineSyncStatusOpen       OfflineSyncStatus = "open"
+	OfflineSyncStatusCompleting OfflineSyncStatus = "completing"
+	OfflineSyncStatusCompleted  OfflineSyncStatus = "completed"
+	OfflineSyncStatusExpired    OfflineSyncStatus = "expired"
+	OfflineSyncStatusCancelled  OfflineSyncStatus = "cancelled"
+)
+
+type OfflineItemStatus string
+
+const (
+	OfflineItemStatusPending      OfflineItemStatus = "pending"
+	OfflineItemStatusSynced       OfflineItemStatus = "synced"
+	OfflineItemStatusDuplicate    OfflineItemStatus = "duplicate"
+	OfflineItemStatusConflict     OfflineItemStatus = "conflict"
+	OfflineItemStatusHashMismatch OfflineItemStatus = "hash_mismatch"
+	OfflineItemStatusRejected     OfflineItemStatus = "rejected"
+)
+
+// ConflictStrategy decides what happens to an item revising evidence that
+// was also changed on the server while the device was offline
+type ConflictStrategy string
+
+const (
+	// ConflictKeepBoth stores the device's version as new evidence linked
+	// to the server's
+	ConflictKeepBoth ConflictStrategy = "keep_both"
+	// ConflictServerWins keeps the server's version and drops the device's
+	ConflictServerWins ConflictStrategy = "server_wins"
+	// ConflictClientWins replaces the server's version with the device's
+	ConflictClientWins ConflictStrategy = "client_wins"
+)
+
 // Custom types for database handling
 type JSONB map[string]interface{}
 
@@ -388,6 +1179,7 @@ type CreateInvestigationRequest struct {
 	Tags           []string               `json:"tags,omitempty"`
 	Metadata       map[string]interface{} `json:"metadata,omitempty"`
 	DueDate        *time.Time             `json:"due_date,omitempty"`
+	Region         *string                `json:"region,omitempty"`
 }
 
 type UpdateInvestigationRequest struct {
@@ -404,15 +1196,16 @@ type UpdateInvestigationRequest struct {
 }
 
 type CreateEvidenceRequest struct {
-	Name                 string                 `json:"name" validate:"required,min=1,max=255"`
-	Description          *string                `json:"description,omitempty"`
-	EvidenceType         EvidenceType           `json:"evidence_type" validate:"required"`
-	Source               *string                `json:"source,omitempty"`
-	CollectionMethod     *string                `json:"collection_method,omitempty"`
-	Tags                 []string               `json:"tags,omitempty"`
-	Metadata             map[string]interface{} `json:"metadata,omitempty"`
-	AuthenticationMethod *string                `json:"authentication_method,omitempty"`
-	RetentionDate        *time.Time             `json:"retention_date,omitempty"`
+	Name                 string                  `json:"name" validate:"required,min=1,max=255"`
+	Description          *string                 `json:"description,omitempty"`
+	EvidenceType         EvidenceType            `json:"evidence_type" validate:"required"`
+	Source               *string                 `json:"source,omitempty"`
+	CollectionMethod     *string                 `json:"collection_method,omitempty"`
+	Tags                 []string                `json:"tags,omitempty"`
+	Metadata             map[string]interface{}  `json:"metadata,omitempty"`
+	AuthenticationMethod *string                 `json:"authentication_method,omitempty"`
+	RetentionDate        *time.Time              `json:"retention_date,omitempty"`
+	Classification       *EvidenceClassification `json:"classification,omitempty"`
 }
 
 type CreateTimelineRequest struct {
@@ -446,6 +1239,163 @@ type CreateCommentRequest struct {
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
 }
 
+type CreateEvidenceRequestRequest struct {
+	RecipientName    string                 `json:"recipient_name" validate:"required,min=1,max=255"`
+	RecipientEmail   *string                `json:"recipient_email,omitempty" validate:"omitempty,email"`
+	RecipientType    RecipientType          `json:"recipient_type" validate:"required"`
+	Instructions     *string                `json:"instructions,omitempty"`
+	AllowedFileTypes []string               `json:"allowed_file_types,omitempty"`
+	MaxFiles         int                    `json:"max_files,omitempty"`
+	ExpiresInHours   int                    `json:"expires_in_hours,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type CreateCalendarEventRequest struct {
+	EventType       CalendarEventType      `json:"event_type" validate:"required"`
+	Title           string                 `json:"title" validate:"required,min=1,max=255"`
+	Description     *string                `json:"description,omitempty"`
+	Location        *string                `json:"location,omitempty"`
+	StartsAt        time.Time              `json:"starts_at" validate:"required"`
+	EndsAt          *time.Time             `json:"ends_at,omitempty"`
+	AllDay          bool                   `json:"all_day"`
+	Attendees       []uuid.UUID            `json:"attendees,omitempty"`
+	ReminderMinutes []int                  `json:"reminder_minutes,omitempty"`
+	AllowConflicts  bool                   `json:"allow_conflicts"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type UpdateCalendarEventRequest struct {
+	Title           *string                `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
+	Description     *string                `json:"description,omitempty"`
+	Location        *string                `json:"location,omitempty"`
+	StartsAt        *time.Time             `json:"starts_at,omitempty"`
+	EndsAt          *time.Time             `json:"ends_at,omitempty"`
+	AllDay          *bool                  `json:"all_day,omitempty"`
+	Attendees       []uuid.UUID            `json:"attendees,omitempty"`
+	ReminderMinutes []int                  `json:"reminder_minutes,omitempty"`
+	Status          *CalendarEventStatus   `json:"status,omitempty"`
+	AllowConflicts  bool                   `json:"allow_conflicts"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type CreateSARFilingRequest struct {
+	FilingType    SARFilingType          `json:"filing_type" validate:"required"`
+	PriorFilingID *uuid.UUID             `json:"prior_filing_id,omitempty"`
+	AlertIDs      []string               `json:"alert_ids"`
+	EvidenceIDs   []uuid.UUID            `json:"evidence_ids"`
+	ActivityStart *time.Time             `json:"activity_start,omitempty"`
+	ActivityEnd   *time.Time             `json:"activity_end,omitempty"`
+	Narrative     *string                `json:"narrative,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type UpdateSARFilingRequest struct {
+	AlertIDs      []string               `json:"alert_ids,omitempty"`
+	EvidenceIDs   []uuid.UUID            `json:"evidence_ids,omitempty"`
+	ActivityStart *time.Time             `json:"activity_start,omitempty"`
+	ActivityEnd   *time.Time             `json:"activity_end,omitempty"`
+	Narrative     *string                `json:"narrative,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type FileSARRequest struct {
+	Reference string     `json:"reference" validate:"required"`
+	FiledAt   *time.Time `json:"filed_at,omitempty"`
+}
+
+type SetCaseRegionRequest struct {
+	Region string `json:"region" validate:"required"`
+}
+
+type CreateResidencyGrantRequest struct {
+	Region         string    `json:"region" validate:"required"`
+	GrantedTo      uuid.UUID `json:"granted_to" validate:"required"`
+	Reason         string    `json:"reason" validate:"required"`
+	ExpiresInHours int       `json:"expires_in_hours" validate:"required"`
+}
+
+type AddParticipantRequest struct {
+	UserID    uuid.UUID               `json:"user_id" validate:"required"`
+	Role      Role                    `json:"role" validate:"required"`
+	Clearance *EvidenceClassification `json:"clearance,omitempty"`
+	Notes     *string                 `json:"notes,omitempty"`
+}
+
+type ClassifyEvidenceRequest struct {
+	Classification EvidenceClassification `json:"classification" validate:"required"`
+	Reason         string                 `json:"reason" validate:"required"`
+}
+
+type QuestionnaireTemplateRequest struct {
+	Name        string     `json:"name" validate:"required"`
+	Description *string    `json:"description,omitempty"`
+	Questions   []Question `json:"questions" validate:"required"`
+}
+
+type AssignQuestionnaireRequest struct {
+	TemplateID uuid.UUID  `json:"template_id" validate:"required"`
+	AssignedTo uuid.UUID  `json:"assigned_to" validate:"required"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+}
+
+// SaveQuestionnaireResponsesRequest sets answers by question ID; a null
+// answer clears it
+type SaveQuestionnaireResponsesRequest struct {
+	Answers map[string]interface{} `json:"answers" validate:"required"`
+}
+
+type ArchiveCaseRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+type SetStorageQuotaRequest struct {
+	LimitBytes *int64 `json:"limit_bytes"`
+	LimitFiles *int64 `json:"limit_files"`
+}
+
+type ReopenCaseRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+type CreateProductionRequest struct {
+	TemplateID       string                `json:"template_id" validate:"required"`
+	Agency           string                `json:"agency" validate:"required"`
+	RequestReference string                `json:"request_reference" validate:"required"`
+	EvidenceIDs      []uuid.UUID           `json:"evidence_ids,omitempty"`
+	TimelineIDs      []uuid.UUID           `json:"timeline_ids,omitempty"`
+	SARFilingIDs     []uuid.UUID           `json:"sar_filing_ids,omitempty"`
+	Redactions       []ProductionRedaction `json:"redactions,omitempty"`
+}
+
+// CreateOfflineSyncRequest is the manifest a field device sends before
+// uploading the evidence it collected offline. ManifestHash, when given, is
+// checked against the items so a damaged manifest is refused.
+type CreateOfflineSyncRequest struct {
+	DeviceID         string                   `json:"device_id" validate:"required"`
+	OfflineFrom      time.Time                `json:"offline_from" validate:"required"`
+	OfflineUntil     time.Time                `json:"offline_until" validate:"required"`
+	ConflictStrategy ConflictStrategy         `json:"conflict_strategy,omitempty"`
+	ManifestHash     string                   `json:"manifest_hash,omitempty"`
+	Items            []OfflineSyncItemRequest `json:"items" validate:"required"`
+}
+
+type OfflineSyncItemRequest struct {
+	ClientItemID   string                 `json:"client_item_id" validate:"required"`
+	Name           string                 `json:"name" validate:"required,min=1,max=255"`
+	Description    *string                `json:"description,omitempty"`
+	EvidenceType   EvidenceType           `json:"evidence_type" validate:"required"`
+	FileName       string                 `json:"file_name" validate:"required"`
+	FileSize       int64                  `json:"file_size"`
+	FileHash       string                 `json:"file_hash" validate:"required"`
+	MimeType       *string                `json:"mime_type,omitempty"`
+	CollectedAt    time.Time              `json:"collected_at" validate:"required"`
+	BaseEvidenceID *uuid.UUID             `json:"base_evidence_id,omitempty"`
+	BaseFileHash   *string                `json:"base_file_hash,omitempty"`
+	Custody        []OfflineCustodyEntry  `json:"custody,omitempty"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	Tags           []string               `json:"tags,omitempty"`
+}
+
 // Filter and search structs
 type InvestigationFilter struct {
 	CaseTypes    []CaseType `json:"case_types,omitempty"`
@@ -459,15 +1409,20 @@ type InvestigationFilter struct {
 	DueBefore    *time.Time `json:"due_before,omitempty"`
 	Tags         []string   `json:"tags,omitempty"`
 	Search       *string    `json:"search,omitempty"`
+	Region       *string    `json:"region,omitempty"` // cases pinned to the region, plus untagged cases
+	// IncludeArchived lists archived cases too; they are left out unless
+	// asked for or filtered on by status
+	IncludeArchived bool `json:"include_archived,omitempty"`
 }
 
 type EvidenceFilter struct {
-	EvidenceTypes   []EvidenceType   `json:"evidence_types,omitempty"`
-	Statuses        []EvidenceStatus `json:"statuses,omitempty"`
-	CollectedBy     *uuid.UUID       `json:"collected_by,omitempty"`
-	CollectedAfter  *time.Time       `json:"collected_after,omitempty"`
-	CollectedBefore *time.Time       `json:"collected_before,omitempty"`
-	IsAuthenticated *bool            `json:"is_authenticated,omitempty"`
-	Tags            []string         `json:"tags,omitempty"`
-	Search          *string          `json:"search,omitempty"`
+	EvidenceTypes   []EvidenceType           `json:"evidence_types,omitempty"`
+	Statuses        []EvidenceStatus         `json:"statuses,omitempty"`
+	CollectedBy     *uuid.UUID               `json:"collected_by,omitempty"`
+	CollectedAfter  *time.Time               `json:"collected_after,omitempty"`
+	CollectedBefore *time.Time               `json:"collected_before,omitempty"`
+	IsAuthenticated *bool                    `json:"is_authenticated,omitempty"`
+	Tags            []string                 `json:"tags,omitempty"`
+	Search          *string                  `json:"search,omitempty"`
+	Classifications []EvidenceClassification `json:"classifications,omitempty"` // set for restricted participants
 }
\ No newline at end of file
@@ -0,0 +1,144 @@
+package offlinesync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"investigation-toolkit/internal/models"
+)
+
+// ErrInvalidManifest is returned for manifests that cannot be accepted
+var ErrInvalidManifest = errors.New("invalid offline sync manifest")
+
+// ManifestHash returns the hex SHA-256 a device computes over its manifest:
+// one "client_item_id<TAB>file_hash<TAB>file_size" line per item, ordered
+// by client item ID
+func ManifestHash(items []models.OfflineSyncItemRequest) string {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, fmt.Sprintf("%s\t%s\t%d\n", item.ClientItemID, strings.ToLower(item.FileHash), item.FileSize))
+	}
+	sort.Strings(lines)
+
+	hash := sha256.New()
+	for _, line := range lines {
+		hash.Write([]byte(line))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Window is the period a device was offline, widened by the clock skew
+// allowed for the device's clock
+type Window struct {
+	From  time.Time
+	Until time.Time
+	Skew  time.Duration
+}
+
+// Contains reports whether a device timestamp falls within the window
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.From.Add(-w.Skew)) && !t.After(w.Until.Add(w.Skew))
+}
+
+// validateItem checks one manifest item against the offline window and the
+// configured limits
+func validateItem(item *models.OfflineSyncItemRequest, window Window, maxFileSize int64) error {
+	item.ClientItemID = strings.TrimSpace(item.ClientItemID)
+	if item.ClientItemID == "" {
+		return errors.New("client_item_id is required")
+	}
+	if strings.TrimSpace(item.Name) == "" {
+		return errors.Errorf("item %s: name is required", item.ClientItemID)
+	}
+	if !validEvidenceType(item.EvidenceType) {
+		return errors.Errorf("item %s: unknown evidence type %q", item.ClientItemID, item.EvidenceType)
+	}
+
+	name := filepath.Base(strings.ReplaceAll(item.FileName, "\\", "/"))
+	if name == "." || name == "/" || name == ".." {
+		return errors.Errorf("item %s: file_name is required", item.ClientItemID)
+	}
+	item.FileName = name
+
+	if item.FileSize < 0 || item.FileSize > maxFileSize {
+		return errors.Errorf("item %s: file size must be between 0 and %d bytes", item.ClientItemID, maxFileSize)
+	}
+	item.FileHash = strings.ToLower(strings.TrimSpace(item.FileHash))
+	if !validHash(item.FileHash) {
+		return errors.Errorf("item %s: file_hash must be a hex SHA-256", item.ClientItemID)
+	}
+	if item.BaseFileHash != nil {
+		hash := strings.ToLower(strings.TrimSpace(*item.BaseFileHash))
+		if !validHash(hash) {
+			return errors.Errorf("item %s: base_file_hash must be a hex SHA-256", item.ClientItemID)
+		}
+		item.BaseFileHash = &hash
+	}
+	if item.BaseFileHash != nil && item.BaseEvidenceID == nil {
+		return errors.Errorf("item %s: base_file_hash requires base_evidence_id", item.ClientItemID)
+	}
+
+	if !window.Contains(item.CollectedAt) {
+		return errors.Errorf("item %s: collected_at is outside the offline window", item.ClientItemID)
+	}
+	for i, entry := range item.Custody {
+		if strings.TrimSpace(entry.Action) == "" {
+			return errors.Errorf("item %s: custody entry %d has no action", item.ClientItemID, i+1)
+		}
+		if !window.Contains(entry.Timestamp) {
+			return errors.Errorf("item %s: custody entry %d is outside the offline window", item.ClientItemID, i+1)
+		}
+	}
+
+	return nil
+}
+
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+func validEvidenceType(evidenceType models.EvidenceType) bool {
+	switch evidenceType {
+	case models.EvidenceTypeDocument, models.EvidenceTypeImage, models.EvidenceTypeVideo,
+		models.EvidenceTypeAudio, models.EvidenceTypeTransaction, models.EvidenceTypeCommunication,
+		models.EvidenceTypeDigital, models.EvidenceTypePhysical, models.EvidenceTypeOther:
+		return true
+	}
+	return false
+}
+
+func validConflictStrategy(strategy models.ConflictStrategy) bool {
+	switch strategy {
+	case models.ConflictKeepBoth, models.ConflictServerWins, models.ConflictClientWins:
+		return true
+	}
+	return false
+}
+
+// totalChunks returns the number of chunks a file is uploaded in; empty
+// files are sent as one empty chunk
+func totalChunks(size, chunkSize int64) int {
+	if size <= 0 {
+		return 1
+	}
+	return int((size + chunkSize - 1) / chunkSize)
+}
+
+// chunkLength returns the expected size of a chunk of a file
+func chunkLength(size, chunkSize int64, total, index int) int64 {
+	if index < total-1 {
+		return chunkSize
+	}
+	return size - chunkSize*int64(total-1)
+}
@@ -0,0 +1,940 @@
+package offlinesync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	limits "aegisshield/shared/quota"
+
+	"investigation-toolkit/internal/archival"
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/quota"
+)
+
+// Chain of custody actions recorded for evidence synced from a field device
+const (
+	CustodyActionCollectedOffline = "collected_offline"
+	CustodyActionSyncedOffline    = "synced_from_offline"
+)
+
+var (
+	// ErrSessionClosed is returned when uploading to or completing a session
+	// that is no longer open
+	ErrSessionClosed = errors.New("offline sync session is not open")
+	// ErrInvalidChunk is returned for chunks outside the item or of the wrong size
+	ErrInvalidChunk = errors.New("invalid chunk")
+	// ErrChunkHashMismatch is returned when a chunk does not match its hash
+	ErrChunkHashMismatch = errors.New("chunk does not match its SHA-256")
+	// ErrIncomplete is returned when completing a session with chunks missing
+	ErrIncomplete = errors.New("offline sync session has chunks missing")
+)
+
+// Store persists offline sync sessions and the evidence they create
+type Store interface {
+	GetInvestigation(ctx context.Context, id uuid.UUID) (*models.Investigation, error)
+	GetEvidence(ctx context.Context, id uuid.UUID) (*models.Evidence, error)
+	// FindEvidenceByHash returns active evidence of a case with the file
+	// hash, or nil
+	FindEvidenceByHash(ctx context.Context, investigationID uuid.UUID, fileHash string) (*models.Evidence, error)
+	CreateEvidence(ctx context.Context, evidence *models.Evidence) error
+	// ReviseEvidenceFile replaces an evidence item's file and appends
+	// chain of custody entries
+	ReviseEvidenceFile(ctx context.Context, evidenceID uuid.UUID, filePath, fileHash, mimeType string, fileSize int64, custody []interface{}) error
+	AppendCustody(ctx context.Context, evidenceID uuid.UUID, custody []interface{}) error
+
+	CreateSession(ctx context.Context, session *models.OfflineSyncSession, items []models.OfflineSyncItem) error
+	GetSession(ctx context.Context, id uuid.UUID) (*models.OfflineSyncSession, error)
+	// FindOpenSession returns the open session of a device for the same
+	// manifest, or nil
+	FindOpenSession(ctx context.Context, investigationID uuid.UUID, deviceID, manifestHash string) (*models.OfflineSyncSession, error)
+	ListSessions(ctx context.Context, investigationID uuid.UUID) ([]models.OfflineSyncSession, error)
+	// SetSessionStatus moves a session from one status to another and
+	// reports whether it was in the from status
+	SetSessionStatus(ctx context.Context, id uuid.UUID, from, to models.OfflineSyncStatus) (bool, error)
+	// ExpireSessions expires open sessions past their expiry and returns them
+	ExpireSessions(ctx context.Context, now time.Time) ([]uuid.UUID, error)
+
+	GetItem(ctx context.Context, sessionID, itemID uuid.UUID) (*models.OfflineSyncItem, error)
+	ListItems(ctx context.Context, sessionID uuid.UUID) ([]models.OfflineSyncItem, error)
+	UpdateItem(ctx context.Context, item *models.OfflineSyncItem) error
+	// FindSyncedItem returns an item of the device synced into the case by
+	// another session, or nil
+	FindSyncedItem(ctx context.Context, investigationID uuid.UUID, deviceID, clientItemID string, excludeSession uuid.UUID) (*models.OfflineSyncItem, error)
+
+	RecordChunk(ctx context.Context, chunk *models.OfflineSyncChunk) error
+	ListChunks(ctx context.Context, sessionID uuid.UUID) ([]models.OfflineSyncChunk, error)
+}
+
+// Quota holds and charges evidence storage quota
+type Quota interface {
+	Reserve(ctx context.Context, tenantID string, userID uuid.UUID, size int64) (*models.StorageReservation, limits.Decision, error)
+	Commit(ctx context.Context, reservation *models.StorageReservation, objectType string, objectID uuid.UUID) error
+	Release(ctx context.Context, reservation *models.StorageReservation)
+}
+
+// Residency places evidence files in the storage of their case's region
+type Residency interface {
+	EvidenceDir(ctx context.Context, investigationID, evidenceID uuid.UUID) (string, string, error)
+	RecordEvidenceRegion(ctx context.Context, evidenceID uuid.UUID, region string) error
+}
+
+// ItemProgress is an item with the chunks still to be uploaded
+type ItemProgress struct {
+	models.OfflineSyncItem
+	ReceivedChunks int   `json:"received_chunks"`
+	MissingChunks  []int `json:"missing_chunks"`
+}
+
+// Progress is a session with the upload progress of each item
+type Progress struct {
+	Session *models.OfflineSyncSession `json:"session"`
+	Items   []ItemProgress             `json:"items"`
+	// Complete is set once every chunk has been received
+	Complete bool `json:"complete"`
+	// Resumed is set when creating a session returned the device's open
+	// session for the same manifest
+	Resumed bool `json:"resumed"`
+}
+
+// Result reports what became of the items of a completed session
+type Result struct {
+	Session      *models.OfflineSyncSession `json:"session"`
+	Items        []models.OfflineSyncItem   `json:"items"`
+	Synced       int                        `json:"synced"`
+	Duplicates   int                        `json:"duplicates"`
+	Conflicts    int                        `json:"conflicts"`
+	HashMismatch int                        `json:"hash_mismatch"`
+	Rejected     int                        `json:"rejected"`
+}
+
+// Service takes in evidence collected offline on field devices. A device
+// registers a manifest of its items with their local hashes, uploads each
+// file in verified chunks, resuming from the chunks the server reports
+// missing, and completes the session. Completing checks every file against
+// its manifest hash, skips evidence the case already holds, resolves edits
+// made on both sides while the device was offline, and records the offline
+// collection window in each item's chain of custody.
+type Service struct {
+	store       Store
+	quota       Quota
+	residency   Residency
+	staging     staging
+	storagePath string
+	config      config.OfflineSyncConfig
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+// NewService creates a new offline sync service. quota and residency may be
+// nil when storage quotas or residency controls are disabled.
+func NewService(store Store, quota Quota, residency Residency, cfg config.OfflineSyncConfig, storagePath string, logger *zap.Logger) *Service {
+	return &Service{
+		store:       store,
+		quota:       quota,
+		residency:   residency,
+		staging:     staging{root: cfg.StagingPath},
+		storagePath: storagePath,
+		config:      cfg,
+		logger:      logger.Named("offline_sync"),
+		now:         time.Now,
+	}
+}
+
+// Run expires abandoned sessions until the context is cancelled
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := s.Sweep(ctx)
+			if err != nil {
+				s.logger.Error("Failed to expire offline sync sessions", zap.Error(err))
+				continue
+			}
+			if expired > 0 {
+				s.logger.Info("Expired offline sync sessions", zap.Int("expired", expired))
+			}
+		}
+	}
+}
+
+// Sweep expires open sessions past their expiry and removes their chunks
+func (s *Service) Sweep(ctx context.Context) (int, error) {
+	expired, err := s.store.ExpireSessions(ctx, s.now())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range expired {
+		if err := s.staging.remove(id); err != nil {
+			s.logger.Warn("Failed to remove staged chunks", zap.String("session_id", id.String()), zap.Error(err))
+		}
+	}
+	return len(expired), nil
+}
+
+// CreateSession registers a device's manifest and returns the session with
+// the chunks to upload. A device that sends the same manifest again while
+// its session is open gets that session back, so it can resume.
+func (s *Service) CreateSession(ctx context.Context, investigationID uuid.UUID, req *models.CreateOfflineSyncRequest, userID uuid.UUID, tenantID string) (*Progress, error) {
+	if err := s.checkCase(ctx, investigationID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if err := s.validate(ctx, investigationID, req, now); err != nil {
+		return nil, err
+	}
+
+	manifestHash := ManifestHash(req.Items)
+	if req.ManifestHash != "" && !strings.EqualFold(strings.TrimSpace(req.ManifestHash), manifestHash) {
+		return nil, errors.Wrap(ErrInvalidManifest, "manifest_hash does not match the items")
+	}
+
+	open, err := s.store.FindOpenSession(ctx, investigationID, req.DeviceID, manifestHash)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil && open.ExpiresAt.After(now) {
+		progress, err := s.Progress(ctx, open.ID)
+		if err != nil {
+			return nil, err
+		}
+		progress.Resumed = true
+		return progress, nil
+	}
+
+	session := &models.OfflineSyncSession{
+		ID:               uuid.New(),
+		InvestigationID:  investigationID,
+		DeviceID:         req.DeviceID,
+		ManifestHash:     manifestHash,
+		OfflineFrom:      req.OfflineFrom,
+		OfflineUntil:     req.OfflineUntil,
+		ConflictStrategy: req.ConflictStrategy,
+		ChunkSize:        s.config.ChunkSize,
+		Status:           models.OfflineSyncStatusOpen,
+		TenantID:         tenantID,
+		CreatedBy:        userID,
+		ExpiresAt:        now.Add(s.config.SessionTTL),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	items := make([]models.OfflineSyncItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, models.OfflineSyncItem{
+			ID:             uuid.New(),
+			SessionID:      session.ID,
+			ClientItemID:   item.ClientItemID,
+			Name:           item.Name,
+			Description:    item.Description,
+			EvidenceType:   item.EvidenceType,
+			FileName:       item.FileName,
+			FileSize:       item.FileSize,
+			FileHash:       item.FileHash,
+			MimeType:       item.MimeType,
+			CollectedAt:    item.CollectedAt,
+			BaseEvidenceID: item.BaseEvidenceID,
+			BaseFileHash:   item.BaseFileHash,
+			LocalCustody:   models.OfflineCustodyEntries(item.Custody),
+			Metadata:       item.Metadata,
+			Tags:           item.Tags,
+			TotalChunks:    totalChunks(item.FileSize, s.config.ChunkSize),
+			Status:         models.OfflineItemStatusPending,
+			CreatedAt:      now,
+		})
+	}
+
+	if err := s.store.CreateSession(ctx, session, items); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Offline sync session created",
+		zap.String("session_id", session.ID.String()),
+		zap.String("investigation_id", investigationID.String()),
+		zap.String("device_id", session.DeviceID),
+		zap.Int("items", len(items)))
+
+	return s.progress(session, items, nil), nil
+}
+
+// validate checks a manifest and fills in its defaults
+func (s *Service) validate(ctx context.Context, investigationID uuid.UUID, req *models.CreateOfflineSyncRequest, now time.Time) error {
+	req.DeviceID = strings.TrimSpace(req.DeviceID)
+	if req.DeviceID == "" {
+		return errors.Wrap(ErrInvalidManifest, "device_id is required")
+	}
+	if req.OfflineFrom.IsZero() || req.OfflineUntil.IsZero() || req.OfflineUntil.Before(req.OfflineFrom) {
+		return errors.Wrap(ErrInvalidManifest, "offline_from must not be after offline_until")
+	}
+	if req.OfflineUntil.After(now.Add(s.config.ClockSkew)) {
+		return errors.Wrap(ErrInvalidManifest, "offline_until is in the future")
+	}
+	if req.OfflineUntil.Sub(req.OfflineFrom) > s.config.MaxOfflineWindow {
+		return errors.Wrapf(ErrInvalidManifest, "offline window exceeds %s", s.config.MaxOfflineWindow)
+	}
+
+	if req.ConflictStrategy == "" {
+		req.ConflictStrategy = models.ConflictKeepBoth
+	}
+	if !validConflictStrategy(req.ConflictStrategy) {
+		return errors.Wrapf(ErrInvalidManifest, "unknown conflict strategy %q", req.ConflictStrategy)
+	}
+
+	if len(req.Items) == 0 {
+		return errors.Wrap(ErrInvalidManifest, "at least one item is required")
+	}
+	if len(req.Items) > s.config.MaxItems {
+		return errors.Wrapf(ErrInvalidManifest, "at most %d items can be synced at once", s.config.MaxItems)
+	}
+
+	window := Window{From: req.OfflineFrom, Until: req.OfflineUntil, Skew: s.config.ClockSkew}
+	seen := make(map[string]bool, len(req.Items))
+	for i := range req.Items {
+		item := &req.Items[i]
+		if err := validateItem(item, window, s.config.MaxFileSize); err != nil {
+			return errors.Wrap(ErrInvalidManifest, err.Error())
+		}
+		if seen[item.ClientItemID] {
+			return errors.Wrapf(ErrInvalidManifest, "duplicate client_item_id %s", item.ClientItemID)
+		}
+		seen[item.ClientItemID] = true
+
+		if item.BaseEvidenceID != nil {
+			base, err := s.store.GetEvidence(ctx, *item.BaseEvidenceID)
+			if err != nil {
+				if isNotFound(err) {
+					return errors.Wrapf(ErrInvalidManifest, "item %s: base evidence not found", item.ClientItemID)
+				}
+				return err
+			}
+			if base.InvestigationID != investigationID {
+				return errors.Wrapf(ErrInvalidManifest, "item %s: base evidence belongs to another investigation", item.ClientItemID)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Progress returns a session with the chunks each item still needs
+func (s *Service) Progress(ctx context.Context, sessionID uuid.UUID) (*Progress, error) {
+	session, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.store.ListItems(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := s.store.ListChunks(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.progress(session, items, chunks), nil
+}
+
+func (s *Service) progress(session *models.OfflineSyncSession, items []models.OfflineSyncItem, chunks []models.OfflineSyncChunk) *Progress {
+	received := make(map[uuid.UUID]map[int]bool)
+	for _, chunk := range chunks {
+		if received[chunk.ItemID] == nil {
+			received[chunk.ItemID] = make(map[int]bool)
+		}
+		received[chunk.ItemID][chunk.ChunkIndex] = true
+	}
+
+	progress := &Progress{Session: session, Items: make([]ItemProgress, 0, len(items)), Complete: true}
+	for _, item := range items {
+		entry := ItemProgress{OfflineSyncItem: item, MissingChunks: []int{}}
+		for index := 0; index < item.TotalChunks; index++ {
+			if received[item.ID][index] {
+				entry.ReceivedChunks++
+			} else if item.Status == models.OfflineItemStatusPending {
+				entry.MissingChunks = append(entry.MissingChunks, index)
+			}
+		}
+		if len(entry.MissingChunks) > 0 {
+			progress.Complete = false
+		}
+		progress.Items = append(progress.Items, entry)
+	}
+
+	return progress
+}
+
+// Sessions lists the offline sync sessions of a case, newest first
+func (s *Service) Sessions(ctx context.Context, investigationID uuid.UUID) ([]models.OfflineSyncSession, error) {
+	return s.store.ListSessions(ctx, investigationID)
+}
+
+// UploadChunk verifies and stages one chunk of an item. Chunks can be sent
+// in any order and again after a failed upload.
+func (s *Service) UploadChunk(ctx context.Context, sessionID, itemID uuid.UUID, index int, chunkHash string, body io.Reader) (*ItemProgress, error) {
+	session, err := s.openSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.store.GetItem(ctx, session.ID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != models.OfflineItemStatusPending {
+		return nil, errors.Wrap(ErrSessionClosed, "item has already been synced")
+	}
+	if index < 0 || index >= item.TotalChunks {
+		return nil, errors.Wrapf(ErrInvalidChunk, "chunk index must be between 0 and %d", item.TotalChunks-1)
+	}
+
+	chunkHash = strings.ToLower(strings.TrimSpace(chunkHash))
+	if !validHash(chunkHash) {
+		return nil, errors.Wrap(ErrInvalidChunk, "chunk SHA-256 is required")
+	}
+
+	size := chunkLength(item.FileSize, session.ChunkSize, item.TotalChunks, index)
+	if err := s.staging.writeChunk(session.ID, item.ID, index, body, size, chunkHash); err != nil {
+		return nil, err
+	}
+
+	chunk := &models.OfflineSyncChunk{
+		ItemID:     item.ID,
+		ChunkIndex: index,
+		Size:       size,
+		ChunkHash:  chunkHash,
+		ReceivedAt: s.now(),
+	}
+	if err := s.store.RecordChunk(ctx, chunk); err != nil {
+		return nil, err
+	}
+
+	chunks, err := s.store.ListChunks(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	progress := s.progress(session, []models.OfflineSyncItem{*item}, chunks)
+	return &progress.Items[0], nil
+}
+
+// Cancel abandons an open session and removes its chunks
+func (s *Service) Cancel(ctx context.Context, sessionID uuid.UUID) error {
+	if _, err := s.store.GetSession(ctx, sessionID); err != nil {
+		return err
+	}
+
+	cancelled, err := s.store.SetSessionStatus(ctx, sessionID, models.OfflineSyncStatusOpen, models.OfflineSyncStatusCancelled)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrSessionClosed
+	}
+
+	if err := s.staging.remove(sessionID); err != nil {
+		s.logger.Warn("Failed to remove staged chunks", zap.String("session_id", sessionID.String()), zap.Error(err))
+	}
+	return nil
+}
+
+// Complete turns the items of a fully uploaded session into evidence.
+// Completing a session again returns its result. If completion stops on an
+// error the session is reopened, and completing it again picks up the
+// items not yet synced.
+func (s *Service) Complete(ctx context.Context, sessionID uuid.UUID) (*Result, error) {
+	session, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == models.OfflineSyncStatusCompleted {
+		return s.result(ctx, session)
+	}
+	if _, err := s.openSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+	if err := s.checkCase(ctx, session.InvestigationID); err != nil {
+		return nil, err
+	}
+
+	progress, err := s.Progress(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !progress.Complete {
+		return nil, ErrIncomplete
+	}
+
+	claimed, err := s.store.SetSessionStatus(ctx, sessionID, models.OfflineSyncStatusOpen, models.OfflineSyncStatusCompleting)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, errors.Wrap(ErrSessionClosed, "session is already being completed")
+	}
+
+	for i := range progress.Items {
+		item := &progress.Items[i].OfflineSyncItem
+		if item.Status != models.OfflineItemStatusPending {
+			continue
+		}
+		if err := s.syncItem(ctx, session, item); err != nil {
+			if _, reopenErr := s.store.SetSessionStatus(ctx, sessionID, models.OfflineSyncStatusCompleting, models.OfflineSyncStatusOpen); reopenErr != nil {
+				s.logger.Error("Failed to reopen offline sync session", zap.String("session_id", sessionID.String()), zap.Error(reopenErr))
+			}
+			return nil, errors.Wrapf(err, "failed to sync item %s", item.ClientItemID)
+		}
+	}
+
+	if _, err := s.store.SetSessionStatus(ctx, sessionID, models.OfflineSyncStatusCompleting, models.OfflineSyncStatusCompleted); err != nil {
+		return nil, err
+	}
+	if err := s.staging.remove(sessionID); err != nil {
+		s.logger.Warn("Failed to remove staged chunks", zap.String("session_id", sessionID.String()), zap.Error(err))
+	}
+
+	session, err = s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.result(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Offline sync session completed",
+		zap.String("session_id", sessionID.String()),
+		zap.String("device_id", session.DeviceID),
+		zap.Int("synced", result.Synced),
+		zap.Int("duplicates", result.Duplicates),
+		zap.Int("conflicts", result.Conflicts),
+		zap.Int("hash_mismatch", result.HashMismatch),
+		zap.Int("rejected", result.Rejected))
+
+	return result, nil
+}
+
+func (s *Service) result(ctx context.Context, session *models.OfflineSyncSession) (*Result, error) {
+	items, err := s.store.ListItems(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Session: session, Items: items}
+	for _, item := range items {
+		switch item.Status {
+		case models.OfflineItemStatusSynced:
+			result.Synced++
+		case models.OfflineItemStatusDuplicate:
+			result.Duplicates++
+		case models.OfflineItemStatusConflict:
+			result.Conflicts++
+		case models.OfflineItemStatusHashMismatch:
+			result.HashMismatch++
+		case models.OfflineItemStatusRejected:
+			result.Rejected++
+		}
+	}
+	return result, nil
+}
+
+// syncItem verifies an item's file against its manifest hash and stores it
+// as evidence, or records why it was not. Only storage failures are returned.
+func (s *Service) syncItem(ctx context.Context, session *models.OfflineSyncSession, item *models.OfflineSyncItem) error {
+	path, size, hash, err := s.staging.assemble(session.ID, item.ID, item.TotalChunks)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	if size != item.FileSize || hash != item.FileHash {
+		return s.finishItem(ctx, item, models.OfflineItemStatusHashMismatch, nil, nil,
+			fmt.Sprintf("received %d bytes with sha256 %s; the manifest lists %d bytes with sha256 %s", size, hash, item.FileSize, item.FileHash))
+	}
+
+	if item.MimeType == nil {
+		mimeType, err := detectMimeType(path)
+		if err != nil {
+			return err
+		}
+		item.MimeType = &mimeType
+	}
+
+	// A device that synced the item before, from a session whose result it
+	// did not see, sends it again
+	prior, err := s.store.FindSyncedItem(ctx, session.InvestigationID, session.DeviceID, item.ClientItemID, session.ID)
+	if err != nil {
+		return err
+	}
+	if prior != nil && prior.EvidenceID != nil {
+		return s.finishItem(ctx, item, models.OfflineItemStatusDuplicate, prior.EvidenceID, nil,
+			fmt.Sprintf("already synced in session %s", prior.SessionID))
+	}
+
+	if item.BaseEvidenceID != nil {
+		return s.syncRevision(ctx, session, item, path)
+	}
+
+	existing, err := s.store.FindEvidenceByHash(ctx, session.InvestigationID, item.FileHash)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		// The same file was already collected; its custody gains the offline collection
+		notes := fmt.Sprintf("Identical copy collected offline as %s was not stored again", item.ClientItemID)
+		if err := s.store.AppendCustody(ctx, existing.ID, s.custody(session, item, notes)); err != nil {
+			return err
+		}
+		return s.finishItem(ctx, item, models.OfflineItemStatusDuplicate, &existing.ID, nil,
+			"the investigation already holds evidence with this file")
+	}
+
+	evidence, ok, err := s.createEvidence(ctx, session, item, path, nil)
+	if err != nil || !ok {
+		return err
+	}
+	return s.finishItem(ctx, item, models.OfflineItemStatusSynced, &evidence.ID, nil, "")
+}
+
+// syncRevision stores an item that revises existing evidence. If the
+// server's file changed since the device last saw it, the session's
+// conflict strategy decides which version is kept.
+func (s *Service) syncRevision(ctx context.Context, session *models.OfflineSyncSession, item *models.OfflineSyncItem, path string) error {
+	base, err := s.store.GetEvidence(ctx, *item.BaseEvidenceID)
+	if err != nil {
+		if isNotFound(err) {
+			return s.finishItem(ctx, item, models.OfflineItemStatusRejected, nil, nil, "base evidence no longer exists")
+		}
+		return err
+	}
+
+	serverHash := stringValue(base.FileHash)
+	if serverHash == item.FileHash {
+		return s.finishItem(ctx, item, models.OfflineItemStatusDuplicate, &base.ID, nil,
+			"the base evidence already holds this file")
+	}
+
+	if serverHash == stringValue(item.BaseFileHash) {
+		ok, err := s.reviseEvidence(ctx, session, item, base, path, "")
+		if err != nil || !ok {
+			return err
+		}
+		return s.finishItem(ctx, item, models.OfflineItemStatusSynced, &base.ID, nil, "")
+	}
+
+	const changed = "the evidence changed on the server while the device was offline"
+	switch session.ConflictStrategy {
+	case models.ConflictServerWins:
+		return s.finishItem(ctx, item, models.OfflineItemStatusConflict, nil, &base.ID,
+			changed+"; kept the server's version")
+
+	case models.ConflictClientWins:
+		notes := fmt.Sprintf("Replaced server version with sha256 %s", serverHash)
+		ok, err := s.reviseEvidence(ctx, session, item, base, path, notes)
+		if err != nil || !ok {
+			return err
+		}
+		return s.finishItem(ctx, item, models.OfflineItemStatusConflict, &base.ID, &base.ID,
+			changed+"; replaced the server's version")
+
+	default:
+		evidence, ok, err := s.createEvidence(ctx, session, item, path, &base.ID)
+		if err != nil || !ok {
+			return err
+		}
+		return s.finishItem(ctx, item, models.OfflineItemStatusConflict, &evidence.ID, &base.ID,
+			changed+"; kept both versions")
+	}
+}
+
+// createEvidence stores the item's file as new evidence. Items refused by
+// storage quota are recorded as rejected and reported as not stored.
+func (s *Service) createEvidence(ctx context.Context, session *models.OfflineSyncSession, item *models.OfflineSyncItem, path string, conflictsWith *uuid.UUID) (*models.Evidence, bool, error) {
+	evidenceID := uuid.New()
+
+	reservation, ok, err := s.reserve(ctx, session, item)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	filePath, region, err := s.storeFile(ctx, session.InvestigationID, evidenceID, item.FileName, path)
+	if err != nil {
+		s.release(ctx, reservation)
+		return nil, false, err
+	}
+
+	source := "offline:" + session.DeviceID
+	collectionMethod := "offline_field_collection"
+	metadata := models.JSONB{}
+	for key, value := range item.Metadata {
+		metadata[key] = value
+	}
+	metadata["offline_sync_session_id"] = session.ID
+	metadata["client_item_id"] = item.ClientItemID
+	metadata["device_id"] = session.DeviceID
+	metadata["offline_from"] = session.OfflineFrom
+	metadata["offline_until"] = session.OfflineUntil
+	if conflictsWith != nil {
+		metadata["conflicts_with"] = *conflictsWith
+	}
+
+	notes := ""
+	if conflictsWith != nil {
+		notes = fmt.Sprintf("Stored alongside evidence %s, which changed on the server while the device was offline", *conflictsWith)
+	}
+
+	now := s.now()
+	evidence := &models.Evidence{
+		ID:               evidenceID,
+		InvestigationID:  session.InvestigationID,
+		Name:             item.Name,
+		Description:      item.Description,
+		EvidenceType:     item.EvidenceType,
+		Source:           &source,
+		CollectionMethod: &collectionMethod,
+		FilePath:         &filePath,
+		FileSize:         &item.FileSize,
+		FileHash:         &item.FileHash,
+		MimeType:         item.MimeType,
+		CollectedBy:      session.CreatedBy,
+		CollectedAt:      item.CollectedAt,
+		ChainOfCustody:   models.JSONB{"entries": s.custody(session, item, notes)},
+		Metadata:         metadata,
+		Tags:             append(append([]string{}, item.Tags...), "offline_sync"),
+		Status:           models.EvidenceStatusActive,
+		Classification:   models.ClassificationInternal,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	if err := s.store.CreateEvidence(ctx, evidence); err != nil {
+		os.Remove(filePath)
+		s.release(ctx, reservation)
+		return nil, false, err
+	}
+
+	s.finishStorage(ctx, reservation, evidence.ID, region)
+	return evidence, true, nil
+}
+
+// reviseEvidence replaces the file of existing evidence with the item's.
+// The earlier file is kept; the new one is stored beside it.
+func (s *Service) reviseEvidence(ctx context.Context, session *models.OfflineSyncSession, item *models.OfflineSyncItem, base *models.Evidence, path, notes string) (bool, error) {
+	reservation, ok, err := s.reserve(ctx, session, item)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	fileName := item.FileHash[:16] + "_" + item.FileName
+	filePath, region, err := s.storeFile(ctx, session.InvestigationID, base.ID, fileName, path)
+	if err != nil {
+		s.release(ctx, reservation)
+		return false, err
+	}
+
+	if notes == "" {
+		notes = fmt.Sprintf("Revised from sha256 %s", stringValue(base.FileHash))
+	}
+	err = s.store.ReviseEvidenceFile(ctx, base.ID, filePath, item.FileHash, stringValue(item.MimeType), item.FileSize,
+		s.custody(session, item, notes))
+	if err != nil {
+		os.Remove(filePath)
+		s.release(ctx, reservation)
+		return false, err
+	}
+
+	s.finishStorage(ctx, reservation, base.ID, region)
+	return true, nil
+}
+
+// reserve holds storage quota for an item's file. Items that would pass a
+// hard stop are recorded as rejected.
+func (s *Service) reserve(ctx context.Context, session *models.OfflineSyncSession, item *models.OfflineSyncItem) (*models.StorageReservation, bool, error) {
+	if s.quota == nil {
+		return nil, true, nil
+	}
+
+	reservation, decision, err := s.quota.Reserve(ctx, session.TenantID, session.CreatedBy, item.FileSize)
+	if err != nil {
+		if errors.Is(err, quota.ErrExceeded) {
+			return nil, false, s.finishItem(ctx, item, models.OfflineItemStatusRejected, nil, nil, decision.Message)
+		}
+		return nil, false, err
+	}
+	return reservation, true, nil
+}
+
+func (s *Service) release(ctx context.Context, reservation *models.StorageReservation) {
+	if reservation != nil {
+		s.quota.Release(ctx, reservation)
+	}
+}
+
+// finishStorage charges a stored file to its reservation and records the
+// region it was stored in
+func (s *Service) finishStorage(ctx context.Context, reservation *models.StorageReservation, evidenceID uuid.UUID, region string) {
+	if reservation != nil {
+		if err := s.quota.Commit(ctx, reservation, quota.ObjectEvidence, evidenceID); err != nil {
+			s.logger.Error("Failed to charge synced evidence to storage quota", zap.String("evidence_id", evidenceID.String()), zap.Error(err))
+		}
+	}
+	if region != "" {
+		if err := s.residency.RecordEvidenceRegion(ctx, evidenceID, region); err != nil {
+			s.logger.Error("Failed to record synced evidence region", zap.String("evidence_id", evidenceID.String()), zap.Error(err))
+		}
+	}
+}
+
+// storeFile moves an assembled file into the evidence directory, in the
+// storage of the case's region when residency controls are enabled
+func (s *Service) storeFile(ctx context.Context, investigationID, evidenceID uuid.UUID, fileName, path string) (string, string, error) {
+	dir := filepath.Join(s.storagePath, investigationID.String(), evidenceID.String())
+	var region string
+	if s.residency != nil {
+		var err error
+		if dir, region, err = s.residency.EvidenceDir(ctx, investigationID, evidenceID); err != nil {
+			return "", "", err
+		}
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", "", errors.Wrap(err, "failed to create evidence directory")
+	}
+
+	target := filepath.Join(dir, fileName)
+	if err := moveFile(path, target); err != nil {
+		return "", "", err
+	}
+	return target, region, nil
+}
+
+// custody builds the chain of custody entries of an item: its collection
+// and the custody entries recorded on the device, both within the offline
+// window, then its upload
+func (s *Service) custody(session *models.OfflineSyncSession, item *models.OfflineSyncItem, notes string) []interface{} {
+	window := map[string]interface{}{
+		"from":  session.OfflineFrom,
+		"until": session.OfflineUntil,
+	}
+
+	entries := []interface{}{map[string]interface{}{
+		"action":         CustodyActionCollectedOffline,
+		"user_id":        session.CreatedBy,
+		"timestamp":      item.CollectedAt,
+		"location":       nil,
+		"notes":          fmt.Sprintf("Collected offline on device %s", session.DeviceID),
+		"device_id":      session.DeviceID,
+		"offline":        true,
+		"offline_window": window,
+	}}
+
+	local := append(models.OfflineCustodyEntries{}, item.LocalCustody...)
+	sort.SliceStable(local, func(i, j int) bool { return local[i].Timestamp.Before(local[j].Timestamp) })
+	for _, entry := range local {
+		entries = append(entries, map[string]interface{}{
+			"action":    entry.Action,
+			"user_id":   session.CreatedBy,
+			"timestamp": entry.Timestamp,
+			"location":  entry.Location,
+			"notes":     entry.Notes,
+			"device_id": session.DeviceID,
+			"offline":   true,
+		})
+	}
+
+	syncNotes := fmt.Sprintf("Uploaded from device %s in offline sync session %s; sha256 %s verified",
+		session.DeviceID, session.ID, item.FileHash)
+	if notes != "" {
+		syncNotes += "; " + notes
+	}
+	entries = append(entries, map[string]interface{}{
+		"action":         CustodyActionSyncedOffline,
+		"user_id":        session.CreatedBy,
+		"timestamp":      s.now(),
+		"location":       "offline sync",
+		"notes":          syncNotes,
+		"device_id":      session.DeviceID,
+		"session_id":     session.ID,
+		"offline_window": window,
+	})
+
+	return entries
+}
+
+func (s *Service) finishItem(ctx context.Context, item *models.OfflineSyncItem, status models.OfflineItemStatus, evidenceID, conflictID *uuid.UUID, reason string) error {
+	now := s.now()
+	item.Status = status
+	item.EvidenceID = evidenceID
+	item.ConflictEvidenceID = conflictID
+	item.StatusReason = nil
+	if reason != "" {
+		item.StatusReason = &reason
+	}
+	item.SyncedAt = &now
+
+	return s.store.UpdateItem(ctx, item)
+}
+
+// openSession returns a session that can still take chunks
+func (s *Service) openSession(ctx context.Context, sessionID uuid.UUID) (*models.OfflineSyncSession, error) {
+	session, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != models.OfflineSyncStatusOpen || !session.ExpiresAt.After(s.now()) {
+		return nil, ErrSessionClosed
+	}
+	return session, nil
+}
+
+// checkCase refuses evidence for archived cases
+func (s *Service) checkCase(ctx context.Context, investigationID uuid.UUID) error {
+	investigation, err := s.store.GetInvestigation(ctx, investigationID)
+	if err != nil {
+		return err
+	}
+	if investigation.Status == models.StatusArchived || investigation.ArchivedAt != nil {
+		return archival.ErrCaseArchived
+	}
+	return nil
+}
+
+func detectMimeType(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open assembled file")
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", errors.Wrap(err, "failed to read assembled file")
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+func isNotFound(err error) bool {
+	return strings.Contains(err.Error(), "not found")
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,144 @@
+package offlinesync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// staging keeps the chunks of open sessions on disk until they complete
+type staging struct {
+	root string
+}
+
+func (s staging) sessionDir(sessionID uuid.UUID) string {
+	return filepath.Join(s.root, sessionID.String())
+}
+
+func (s staging) itemDir(sessionID, itemID uuid.UUID) string {
+	return filepath.Join(s.sessionDir(sessionID), itemID.String())
+}
+
+func (s staging) chunkPath(sessionID, itemID uuid.UUID, index int) string {
+	return filepath.Join(s.itemDir(sessionID, itemID), fmt.Sprintf("%06d.part", index))
+}
+
+// writeChunk stores a chunk read from r, which must hold exactly size bytes
+// with the given hex SHA-256. A chunk sent again replaces the earlier copy.
+func (s staging) writeChunk(sessionID, itemID uuid.UUID, index int, r io.Reader, size int64, hash string) error {
+	dir := s.itemDir(sessionID, itemID)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return errors.Wrap(err, "failed to create staging directory")
+	}
+
+	file, err := os.CreateTemp(dir, "upload-*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create staged chunk")
+	}
+	defer os.Remove(file.Name())
+
+	digest := sha256.New()
+	written, err := io.Copy(io.MultiWriter(file, digest), io.LimitReader(r, size+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write staged chunk")
+	}
+
+	if written != size {
+		return errors.Wrapf(ErrInvalidChunk, "expected %d bytes, received %d", size, written)
+	}
+	if hex.EncodeToString(digest.Sum(nil)) != hash {
+		return ErrChunkHashMismatch
+	}
+
+	if err := os.Rename(file.Name(), s.chunkPath(sessionID, itemID, index)); err != nil {
+		return errors.Wrap(err, "failed to store staged chunk")
+	}
+	return nil
+}
+
+// assemble joins an item's chunks into one file in the item's staging
+// directory and returns its path, size and hex SHA-256
+func (s staging) assemble(sessionID, itemID uuid.UUID, chunks int) (string, int64, string, error) {
+	path := filepath.Join(s.itemDir(sessionID, itemID), "assembled")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return "", 0, "", errors.Wrap(err, "failed to create assembled file")
+	}
+
+	digest := sha256.New()
+	var size int64
+	for index := 0; index < chunks; index++ {
+		n, err := appendFile(io.MultiWriter(file, digest), s.chunkPath(sessionID, itemID, index))
+		if err != nil {
+			file.Close()
+			os.Remove(path)
+			return "", 0, "", err
+		}
+		size += n
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return "", 0, "", errors.Wrap(err, "failed to write assembled file")
+	}
+
+	return path, size, hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+func appendFile(w io.Writer, path string) (int64, error) {
+	chunk, err := os.Open(path)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to open staged chunk")
+	}
+	defer chunk.Close()
+
+	n, err := io.Copy(w, chunk)
+	if err != nil {
+		return n, errors.Wrap(err, "failed to assemble staged chunks")
+	}
+	return n, nil
+}
+
+// remove deletes everything staged for a session
+func (s staging) remove(sessionID uuid.UUID) error {
+	return os.RemoveAll(s.sessionDir(sessionID))
+}
+
+// moveFile moves a file into place, copying it when the staging area is on
+// another filesystem
+func moveFile(from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	source, err := os.Open(from)
+	if err != nil {
+		return errors.Wrap(err, "failed to open assembled file")
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(to, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		return errors.Wrap(err, "failed to create evidence file")
+	}
+	if _, err := io.Copy(target, source); err != nil {
+		target.Close()
+		os.Remove(to)
+		return errors.Wrap(err, "failed to write evidence file")
+	}
+	if err := target.Close(); err != nil {
+		os.Remove(to)
+		return errors.Wrap(err, "failed to write evidence file")
+	}
+
+	return os.Remove(from)
+}
@@ -0,0 +1,212 @@
+package production
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"investigation-toolkit/internal/models"
+)
+
+// Artifacts are the case records selected for a production
+type Artifacts struct {
+	Case       *models.Investigation
+	Evidence   []models.Evidence
+	Timeline   []models.Timeline
+	SARFilings []models.SARFiling
+}
+
+// Record is one artifact's field values, as produced
+type Record struct {
+	ID     uuid.UUID
+	Values []string // one per section column
+}
+
+// SectionData is a section of a production filled with its artifacts
+type SectionData struct {
+	Section Section
+	Records []Record
+}
+
+// Dataset is everything a production file holds
+type Dataset struct {
+	Production *models.LawEnforcementProduction
+	Template   *Template
+	Sections   []SectionData
+	ProducedAt time.Time
+}
+
+// Build fills a template's sections with the artifacts, withholding every
+// redacted field behind redactionText
+func Build(template *Template, production *models.LawEnforcementProduction, artifacts *Artifacts, redactionText string, producedAt time.Time) *Dataset {
+	dataset := &Dataset{
+		Production: production,
+		Template:   template,
+		ProducedAt: producedAt,
+	}
+
+	for _, section := range template.Sections {
+		data := SectionData{Section: section, Records: []Record{}}
+		for _, fields := range artifacts.fields(section.Source) {
+			id, _ := uuid.Parse(fields["id"])
+			record := Record{ID: id, Values: make([]string, len(section.Columns))}
+			for i, column := range section.Columns {
+				if redacted(production.Redactions, section.Source, id, column.Field) {
+					record.Values[i] = redactionText
+					continue
+				}
+				record.Values[i] = fields[column.Field]
+			}
+			data.Records = append(data.Records, record)
+		}
+		dataset.Sections = append(dataset.Sections, data)
+	}
+
+	return dataset
+}
+
+func redacted(redactions []models.ProductionRedaction, source string, id uuid.UUID, field string) bool {
+	for _, redaction := range redactions {
+		if redaction.Source == source && redaction.Field == field &&
+			(redaction.ArtifactID == nil || *redaction.ArtifactID == id) {
+			return true
+		}
+	}
+	return false
+}
+
+// fields returns the field values of every artifact of a source, keyed as
+// in SourceFields
+func (a *Artifacts) fields(source string) []map[string]string {
+	var records []map[string]string
+
+	switch source {
+	case SourceCase:
+		if a.Case != nil {
+			records = append(records, caseFields(a.Case))
+		}
+	case SourceEvidence:
+		for i := range a.Evidence {
+			records = append(records, evidenceFields(&a.Evidence[i]))
+		}
+	case SourceTimeline:
+		for i := range a.Timeline {
+			records = append(records, timelineFields(&a.Timeline[i]))
+		}
+	case SourceSARFiling:
+		for i := range a.SARFilings {
+			records = append(records, sarFilingFields(&a.SARFilings[i]))
+		}
+	}
+
+	return records
+}
+
+func caseFields(c *models.Investigation) map[string]string {
+	return map[string]string{
+		"id":               c.ID.String(),
+		"title":            c.Title,
+		"description":      text(c.Description),
+		"case_type":        string(c.CaseType),
+		"priority":         string(c.Priority),
+		"status":           string(c.Status),
+		"external_case_id": text(c.ExternalCaseID),
+		"tags":             list(c.Tags),
+		"created_at":       timestamp(&c.CreatedAt),
+		"closed_at":        timestamp(c.ClosedAt),
+	}
+}
+
+func evidenceFields(e *models.Evidence) map[string]string {
+	size := ""
+	if e.FileSize != nil {
+		size = strconv.FormatInt(*e.FileSize, 10)
+	}
+
+	return map[string]string{
+		"id":                e.ID.String(),
+		"name":              e.Name,
+		"description":       text(e.Description),
+		"evidence_type":     string(e.EvidenceType),
+		"source":            text(e.Source),
+		"collection_method": text(e.CollectionMethod),
+		"file_hash":         text(e.FileHash),
+		"file_size":         size,
+		"mime_type":         text(e.MimeType),
+		"collected_at":      timestamp(&e.CollectedAt),
+		"status":            string(e.Status),
+		"classification":    string(e.Classification),
+		"is_authenticated":  strconv.FormatBool(e.IsAuthenticated),
+		"tags":              list(e.Tags),
+	}
+}
+
+func timelineFields(t *models.Timeline) map[string]string {
+	duration := ""
+	if t.DurationMinutes != nil {
+		duration = strconv.Itoa(*t.DurationMinutes)
+	}
+
+	return map[string]string{
+		"id":                   t.ID.String(),
+		"title":                t.Title,
+		"description":          text(t.Description),
+		"event_type":           string(t.EventType),
+		"event_date":           timestamp(&t.EventDate),
+		"duration_minutes":     duration,
+		"location":             text(t.Location),
+		"participants":         list(t.Participants),
+		"related_evidence_ids": ids(t.RelatedEvidenceIDs),
+		"tags":                 list(t.Tags),
+	}
+}
+
+func sarFilingFields(f *models.SARFiling) map[string]string {
+	prior := ""
+	if f.PriorFilingID != nil {
+		prior = f.PriorFilingID.String()
+	}
+
+	return map[string]string{
+		"id":              f.ID.String(),
+		"filing_type":     string(f.FilingType),
+		"status":          string(f.Status),
+		"reference":       text(f.Reference),
+		"prior_filing_id": prior,
+		"alert_ids":       list(f.AlertIDs),
+		"evidence_ids":    ids(f.EvidenceIDs),
+		"activity_start":  timestamp(f.ActivityStart),
+		"activity_end":    timestamp(f.ActivityEnd),
+		"narrative":       text(f.Narrative),
+		"filed_at":        timestamp(f.FiledAt),
+	}
+}
+
+func text(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func timestamp(value *time.Time) string {
+	if value == nil || value.IsZero() {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
+
+func list(values pq.StringArray) string {
+	return strings.Join(values, "; ")
+}
+
+func ids(values models.UUIDArray) string {
+	parts := make([]string, len(values))
+	for i, id := range values {
+		parts[i] = id.String()
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,190 @@
+package production
+
+import (
+	"bytes"
+	"encoding/xml"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+)
+
+// Render writes a dataset in its template's format
+func Render(dataset *Dataset) ([]byte, error) {
+	if dataset.Template.Format == FormatXML {
+		return renderXML(dataset)
+	}
+	return renderXLSX(dataset)
+}
+
+// manifest describes the production itself: who it is for, what it answers
+// and what was withheld from it
+func manifest(dataset *Dataset) [][]string {
+	p := dataset.Production
+	approvedBy := ""
+	if p.ApprovedBy != nil {
+		approvedBy = p.ApprovedBy.String()
+	}
+
+	rows := [][]string{
+		{"Agency", p.Agency},
+		{"Request Reference", p.RequestReference},
+		{"Production ID", p.ID.String()},
+		{"Case ID", p.InvestigationID.String()},
+		{"Template", dataset.Template.Name},
+		{"Produced At", dataset.ProducedAt.UTC().Format(time.RFC3339)},
+		{"Approved By", approvedBy},
+		{},
+		{"Redactions"},
+		{"Source", "Artifact", "Field", "Reason"},
+	}
+	for _, redaction := range p.Redactions {
+		artifact := "all"
+		if redaction.ArtifactID != nil {
+			artifact = redaction.ArtifactID.String()
+		}
+		rows = append(rows, []string{redaction.Source, artifact, redaction.Field, redaction.Reason})
+	}
+	return rows
+}
+
+func renderXLSX(dataset *Dataset) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create workbook style")
+	}
+
+	if err := f.SetSheetName("Sheet1", ManifestSheet); err != nil {
+		return nil, errors.Wrap(err, "failed to create manifest sheet")
+	}
+	for i, row := range manifest(dataset) {
+		if err := writeRow(f, ManifestSheet, i+1, row); err != nil {
+			return nil, err
+		}
+	}
+	if err := f.SetColStyle(ManifestSheet, "A", bold); err != nil {
+		return nil, errors.Wrap(err, "failed to style manifest sheet")
+	}
+
+	for _, section := range dataset.Sections {
+		if _, err := f.NewSheet(section.Section.Name); err != nil {
+			return nil, errors.Wrapf(err, "failed to create sheet %s", section.Section.Name)
+		}
+
+		headers := make([]string, len(section.Section.Columns))
+		for i, column := range section.Section.Columns {
+			headers[i] = column.Header
+		}
+		if err := writeRow(f, section.Section.Name, 1, headers); err != nil {
+			return nil, err
+		}
+		if err := f.SetRowStyle(section.Section.Name, 1, 1, bold); err != nil {
+			return nil, errors.Wrapf(err, "failed to style sheet %s", section.Section.Name)
+		}
+
+		for i, record := range section.Records {
+			if err := writeRow(f, section.Section.Name, i+2, record.Values); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write workbook")
+	}
+	return buf.Bytes(), nil
+}
+
+// writeRow writes values as text so that identifiers and dates are produced
+// exactly as they are held
+func writeRow(f *excelize.File, sheet string, row int, values []string) error {
+	for i, value := range values {
+		cell, err := excelize.CoordinatesToCellName(i+1, row)
+		if err != nil {
+			return errors.Wrap(err, "failed to address cell")
+		}
+		if err := f.SetCellStr(sheet, cell, value); err != nil {
+			return errors.Wrapf(err, "failed to write sheet %s", sheet)
+		}
+	}
+	return nil
+}
+
+func renderXML(dataset *Dataset) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+
+	p := dataset.Production
+	root := xml.StartElement{
+		Name: xml.Name{Local: dataset.Template.RootElement},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "agency"}, Value: p.Agency},
+			{Name: xml.Name{Local: "requestReference"}, Value: p.RequestReference},
+			{Name: xml.Name{Local: "productionId"}, Value: p.ID.String()},
+			{Name: xml.Name{Local: "caseId"}, Value: p.InvestigationID.String()},
+			{Name: xml.Name{Local: "producedAt"}, Value: dataset.ProducedAt.UTC().Format(time.RFC3339)},
+		},
+	}
+	if err := enc.EncodeToken(root); err != nil {
+		return nil, errors.Wrap(err, "failed to write production XML")
+	}
+
+	for _, section := range dataset.Sections {
+		start := xml.StartElement{Name: xml.Name{Local: section.Section.Element}}
+		if err := enc.EncodeToken(start); err != nil {
+			return nil, errors.Wrap(err, "failed to write production XML")
+		}
+		for _, record := range section.Records {
+			recordStart := xml.StartElement{Name: xml.Name{Local: section.Section.RecordElement}}
+			if err := enc.EncodeToken(recordStart); err != nil {
+				return nil, errors.Wrap(err, "failed to write production XML")
+			}
+			for i, column := range section.Section.Columns {
+				if err := enc.EncodeElement(record.Values[i], xml.StartElement{Name: xml.Name{Local: column.Element}}); err != nil {
+					return nil, errors.Wrap(err, "failed to write production XML")
+				}
+			}
+			if err := enc.EncodeToken(recordStart.End()); err != nil {
+				return nil, errors.Wrap(err, "failed to write production XML")
+			}
+		}
+		if err := enc.EncodeToken(start.End()); err != nil {
+			return nil, errors.Wrap(err, "failed to write production XML")
+		}
+	}
+
+	redactions := xml.StartElement{Name: xml.Name{Local: "Redactions"}}
+	if err := enc.EncodeToken(redactions); err != nil {
+		return nil, errors.Wrap(err, "failed to write production XML")
+	}
+	for _, redaction := range p.Redactions {
+		attrs := []xml.Attr{
+			{Name: xml.Name{Local: "source"}, Value: redaction.Source},
+			{Name: xml.Name{Local: "field"}, Value: redaction.Field},
+		}
+		if redaction.ArtifactID != nil {
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "artifactId"}, Value: redaction.ArtifactID.String()})
+		}
+		if err := enc.EncodeElement(redaction.Reason, xml.StartElement{Name: xml.Name{Local: "Redaction"}, Attr: attrs}); err != nil {
+			return nil, errors.Wrap(err, "failed to write production XML")
+		}
+	}
+	if err := enc.EncodeToken(redactions.End()); err != nil {
+		return nil, errors.Wrap(err, "failed to write production XML")
+	}
+
+	if err := enc.EncodeToken(root.End()); err != nil {
+		return nil, errors.Wrap(err, "failed to write production XML")
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, errors.Wrap(err, "failed to write production XML")
+	}
+	return buf.Bytes(), nil
+}
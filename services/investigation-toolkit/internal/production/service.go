@@ -0,0 +1,374 @@
+package production
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+)
+
+// DisclosureAction is the audit log action recording a production being
+// disclosed to an agency
+const DisclosureAction = "law_enforcement_disclosure"
+
+var (
+	// ErrInvalidProduction is returned for production requests that cannot be drafted
+	ErrInvalidProduction = errors.New("invalid production")
+	// ErrArtifactNotInCase is returned when a selected artifact is not part of the case
+	ErrArtifactNotInCase = errors.New("selected artifact does not belong to the investigation")
+	// ErrSelfApproval is returned when the requester of a production approves it
+	ErrSelfApproval = errors.New("a production must be approved by someone other than its requester")
+	// ErrNotProduced is returned when downloading a production that has not been produced
+	ErrNotProduced = errors.New("production has not been produced")
+)
+
+// Store persists productions and reads the case artifacts they select
+type Store interface {
+	GetInvestigation(ctx context.Context, id uuid.UUID) (*models.Investigation, error)
+	// The artifact lists return those of the IDs that belong to the case
+	ListEvidence(ctx context.Context, investigationID uuid.UUID, ids []uuid.UUID) ([]models.Evidence, error)
+	ListTimeline(ctx context.Context, investigationID uuid.UUID, ids []uuid.UUID) ([]models.Timeline, error)
+	ListSARFilings(ctx context.Context, investigationID uuid.UUID, ids []uuid.UUID) ([]models.SARFiling, error)
+	CreateProduction(ctx context.Context, production *models.LawEnforcementProduction) error
+	GetProduction(ctx context.Context, id uuid.UUID) (*models.LawEnforcementProduction, error)
+	ListProductions(ctx context.Context, investigationID uuid.UUID) ([]models.LawEnforcementProduction, error)
+	// ApproveProduction records the approval of a production that is still a draft
+	ApproveProduction(ctx context.Context, production *models.LawEnforcementProduction) error
+	// RecordProduction stores the file of an approved production and
+	// records its disclosure in the audit log, together
+	RecordProduction(ctx context.Context, production *models.LawEnforcementProduction, disclosure *models.AuditLog) error
+}
+
+// Service drafts productions of case artifacts to law-enforcement agencies,
+// has their redactions approved, and produces them in the layout of the
+// agency's template, recording each production as a disclosure
+type Service struct {
+	store     Store
+	templates map[string]*Template
+	order     []string
+	config    config.ProductionConfig
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewService creates a new production service with the built-in templates
+// and any in the configured templates file
+func NewService(store Store, cfg config.ProductionConfig, logger *zap.Logger) (*Service, error) {
+	templates, err := LoadTemplates(cfg.TemplatesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Service{
+		store:     store,
+		templates: make(map[string]*Template, len(templates)),
+		config:    cfg,
+		logger:    logger.Named("production"),
+		now:       time.Now,
+	}
+	for i := range templates {
+		template := &templates[i]
+		if err := template.Validate(); err != nil {
+			return nil, err
+		}
+		s.templates[template.ID] = template
+		s.order = append(s.order, template.ID)
+	}
+	return s, nil
+}
+
+// Templates lists the production templates
+func (s *Service) Templates() []*Template {
+	templates := make([]*Template, 0, len(s.order))
+	for _, id := range s.order {
+		templates = append(templates, s.templates[id])
+	}
+	return templates
+}
+
+// Create drafts a production of the selected artifacts of a case
+func (s *Service) Create(ctx context.Context, investigationID uuid.UUID, req *models.CreateProductionRequest, requestedBy uuid.UUID) (*models.LawEnforcementProduction, error) {
+	template, ok := s.templates[req.TemplateID]
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownTemplate, "%q", req.TemplateID)
+	}
+
+	production := &models.LawEnforcementProduction{
+		InvestigationID:  investigationID,
+		TemplateID:       template.ID,
+		Agency:           strings.TrimSpace(req.Agency),
+		RequestReference: strings.TrimSpace(req.RequestReference),
+		EvidenceIDs:      unique(req.EvidenceIDs),
+		TimelineIDs:      unique(req.TimelineIDs),
+		SARFilingIDs:     unique(req.SARFilingIDs),
+		Redactions:       models.ProductionRedactions(req.Redactions),
+		Status:           models.ProductionStatusDraft,
+		RequestedBy:      requestedBy,
+	}
+	if production.Redactions == nil {
+		production.Redactions = models.ProductionRedactions{}
+	}
+	if err := validateProduction(template, production); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.artifacts(ctx, production); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.CreateProduction(ctx, production); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Law enforcement production drafted",
+		zap.String("production_id", production.ID.String()),
+		zap.String("investigation_id", investigationID.String()),
+		zap.String("template_id", template.ID),
+		zap.String("agency", production.Agency))
+
+	return production, nil
+}
+
+// Get retrieves a production
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*models.LawEnforcementProduction, error) {
+	return s.store.GetProduction(ctx, id)
+}
+
+// List lists a case's productions, most recent first
+func (s *Service) List(ctx context.Context, investigationID uuid.UUID) ([]models.LawEnforcementProduction, error) {
+	if _, err := s.store.GetInvestigation(ctx, investigationID); err != nil {
+		return nil, err
+	}
+	return s.store.ListProductions(ctx, investigationID)
+}
+
+// Approve approves a draft production's selection and redactions for
+// production. The requester cannot approve their own production.
+func (s *Service) Approve(ctx context.Context, id, approvedBy uuid.UUID) (*models.LawEnforcementProduction, error) {
+	production, err := s.store.GetProduction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if production.RequestedBy == approvedBy {
+		return nil, ErrSelfApproval
+	}
+
+	now := s.now()
+	production.Status = models.ProductionStatusApproved
+	production.ApprovedBy = &approvedBy
+	production.ApprovedAt = &now
+	if err := s.store.ApproveProduction(ctx, production); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Law enforcement production approved",
+		zap.String("production_id", id.String()),
+		zap.String("approved_by", approvedBy.String()),
+		zap.Int("redactions", len(production.Redactions)))
+
+	return production, nil
+}
+
+// Produce writes an approved production in its template's layout with its
+// redactions applied, keeps the file and records the disclosure
+func (s *Service) Produce(ctx context.Context, id, producedBy uuid.UUID) (*models.LawEnforcementProduction, error) {
+	production, err := s.store.GetProduction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	template, ok := s.templates[production.TemplateID]
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownTemplate, "%q", production.TemplateID)
+	}
+
+	artifacts, err := s.artifacts(ctx, production)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	content, err := Render(Build(template, production, artifacts, s.config.RedactionText, now))
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+	fileName := fmt.Sprintf("production-%s.%s", production.ID, template.Format)
+	contentType := template.ContentType()
+	size := int64(len(content))
+
+	production.Status = models.ProductionStatusProduced
+	production.ProducedBy = &producedBy
+	production.ProducedAt = &now
+	production.FileName = &fileName
+	production.ContentType = &contentType
+	production.FileSize = &size
+	production.FileSHA256 = &checksum
+	production.Content = content
+
+	if err := s.store.RecordProduction(ctx, production, disclosure(production, producedBy, now)); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Law enforcement production disclosed",
+		zap.String("production_id", id.String()),
+		zap.String("investigation_id", production.InvestigationID.String()),
+		zap.String("agency", production.Agency),
+		zap.String("request_reference", production.RequestReference),
+		zap.String("file_sha256", checksum))
+
+	return production, nil
+}
+
+// File retrieves a produced production with its file as it was disclosed
+func (s *Service) File(ctx context.Context, id uuid.UUID) (*models.LawEnforcementProduction, error) {
+	production, err := s.store.GetProduction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if production.Status != models.ProductionStatusProduced {
+		return nil, ErrNotProduced
+	}
+	return production, nil
+}
+
+// artifacts reads the case and the artifacts a production selects, failing
+// when any of them is not part of the case
+func (s *Service) artifacts(ctx context.Context, production *models.LawEnforcementProduction) (*Artifacts, error) {
+	investigation, err := s.store.GetInvestigation(ctx, production.InvestigationID)
+	if err != nil {
+		return nil, err
+	}
+	artifacts := &Artifacts{Case: investigation}
+
+	if len(production.EvidenceIDs) > 0 {
+		if artifacts.Evidence, err = s.store.ListEvidence(ctx, production.InvestigationID, production.EvidenceIDs); err != nil {
+			return nil, err
+		}
+		if len(artifacts.Evidence) != len(production.EvidenceIDs) {
+			return nil, errors.Wrap(ErrArtifactNotInCase, "evidence")
+		}
+	}
+	if len(production.TimelineIDs) > 0 {
+		if artifacts.Timeline, err = s.store.ListTimeline(ctx, production.InvestigationID, production.TimelineIDs); err != nil {
+			return nil, err
+		}
+		if len(artifacts.Timeline) != len(production.TimelineIDs) {
+			return nil, errors.Wrap(ErrArtifactNotInCase, "timeline event")
+		}
+	}
+	if len(production.SARFilingIDs) > 0 {
+		if artifacts.SARFilings, err = s.store.ListSARFilings(ctx, production.InvestigationID, production.SARFilingIDs); err != nil {
+			return nil, err
+		}
+		if len(artifacts.SARFilings) != len(production.SARFilingIDs) {
+			return nil, errors.Wrap(ErrArtifactNotInCase, "SAR filing")
+		}
+	}
+
+	return artifacts, nil
+}
+
+// validateProduction checks a draft's recipient, that its selection fits
+// its template and that every redaction names a produced field and reason
+func validateProduction(template *Template, production *models.LawEnforcementProduction) error {
+	if production.Agency == "" || production.RequestReference == "" {
+		return errors.Wrap(ErrInvalidProduction, "agency and request_reference are required")
+	}
+
+	selected := map[string][]uuid.UUID{
+		SourceCase:      {production.InvestigationID},
+		SourceEvidence:  production.EvidenceIDs,
+		SourceTimeline:  production.TimelineIDs,
+		SourceSARFiling: production.SARFilingIDs,
+	}
+	if len(production.EvidenceIDs)+len(production.TimelineIDs)+len(production.SARFilingIDs) == 0 && !template.Uses(SourceCase) {
+		return errors.Wrap(ErrInvalidProduction, "select at least one artifact")
+	}
+	for source, ids := range selected {
+		if source != SourceCase && len(ids) > 0 && !template.Uses(source) {
+			return errors.Wrapf(ErrInvalidProduction, "template %s does not produce %s", template.ID, source)
+		}
+	}
+
+	for _, redaction := range production.Redactions {
+		fields, ok := SourceFields[redaction.Source]
+		if !ok {
+			return errors.Wrapf(ErrInvalidProduction, "redaction of unknown source %q", redaction.Source)
+		}
+		if !hasField(fields, redaction.Field) {
+			return errors.Wrapf(ErrInvalidProduction, "redaction of unknown %s field %q", redaction.Source, redaction.Field)
+		}
+		if strings.TrimSpace(redaction.Reason) == "" {
+			return errors.Wrapf(ErrInvalidProduction, "redaction of %s %s needs a reason", redaction.Source, redaction.Field)
+		}
+		if redaction.ArtifactID != nil && !contains(selected[redaction.Source], *redaction.ArtifactID) {
+			return errors.Wrapf(ErrInvalidProduction, "redaction names %s %s, which is not selected", redaction.Source, redaction.ArtifactID)
+		}
+	}
+
+	return nil
+}
+
+// disclosure is the audit log entry recording a production being disclosed
+func disclosure(production *models.LawEnforcementProduction, producedBy uuid.UUID, at time.Time) *models.AuditLog {
+	redactions := make([]string, 0, len(production.Redactions))
+	for _, redaction := range production.Redactions {
+		redactions = append(redactions, redaction.Source+"."+redaction.Field)
+	}
+	sort.Strings(redactions)
+
+	return &models.AuditLog{
+		InvestigationID: &production.InvestigationID,
+		UserID:          producedBy,
+		Action:          DisclosureAction,
+		ResourceType:    "law_enforcement_production",
+		ResourceID:      &production.ID,
+		NewValues: models.JSONB{
+			"agency":            production.Agency,
+			"request_reference": production.RequestReference,
+			"template_id":       production.TemplateID,
+			"evidence_ids":      production.EvidenceIDs,
+			"timeline_ids":      production.TimelineIDs,
+			"sar_filing_ids":    production.SARFilingIDs,
+			"redacted_fields":   redactions,
+			"approved_by":       production.ApprovedBy,
+			"file_name":         *production.FileName,
+			"file_sha256":       *production.FileSHA256,
+		},
+		Metadata:  models.JSONB{"event": "disclosure"},
+		CreatedAt: at,
+	}
+}
+
+func unique(ids []uuid.UUID) models.UUIDArray {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	result := models.UUIDArray{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func contains(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,271 @@
+package production
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Production file formats
+const (
+	FormatXLSX = "xlsx"
+	FormatXML  = "xml"
+)
+
+// Sources of produced artifacts
+const (
+	SourceCase      = "case"
+	SourceEvidence  = "evidence"
+	SourceTimeline  = "timeline"
+	SourceSARFiling = "sar_filings"
+)
+
+// ManifestSheet is the workbook tab describing the production itself; it is
+// added to every workbook ahead of the template's tabs
+const ManifestSheet = "Production"
+
+var (
+	// ErrUnknownTemplate is returned for template IDs that are not configured
+	ErrUnknownTemplate = errors.New("unknown production template")
+	// ErrInvalidTemplate is returned for templates that cannot be produced
+	ErrInvalidTemplate = errors.New("invalid production template")
+)
+
+var (
+	templateID  = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,99}$`)
+	xmlName     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9._-]*$`)
+	sheetBanned = regexp.MustCompile(`[\[\]:*?/\\]`)
+)
+
+// SourceFields lists the fields of each source that a template column or a
+// redaction may name
+var SourceFields = map[string][]string{
+	SourceCase: {
+		"id", "title", "description", "case_type", "priority", "status",
+		"external_case_id", "tags", "created_at", "closed_at",
+	},
+	SourceEvidence: {
+		"id", "name", "description", "evidence_type", "source", "collection_method",
+		"file_hash", "file_size", "mime_type", "collected_at", "status",
+		"classification", "is_authenticated", "tags",
+	},
+	SourceTimeline: {
+		"id", "title", "description", "event_type", "event_date", "duration_minutes",
+		"location", "participants", "related_evidence_ids", "tags",
+	},
+	SourceSARFiling: {
+		"id", "filing_type", "status", "reference", "prior_filing_id", "alert_ids",
+		"evidence_ids", "activity_start", "activity_end", "narrative", "filed_at",
+	},
+}
+
+// Template maps case artifacts into the layout an agency asks for: a
+// workbook with one tab per section, or an XML document with one element
+// per section
+type Template struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Format      string    `json:"format"`
+	RootElement string    `json:"root_element,omitempty"` // XML only
+	Sections    []Section `json:"sections"`
+}
+
+// Section lists the artifacts of one source, one row or record per artifact
+type Section struct {
+	Name          string   `json:"name"` // workbook tab name
+	Source        string   `json:"source"`
+	Element       string   `json:"element,omitempty"`        // XML only
+	RecordElement string   `json:"record_element,omitempty"` // XML only
+	Columns       []Column `json:"columns"`
+}
+
+// Column is one field of a section's artifacts
+type Column struct {
+	Header  string `json:"header"`
+	Field   string `json:"field"`
+	Element string `json:"element,omitempty"` // XML only
+}
+
+// BuiltinTemplates are the production templates available without a
+// templates file
+func BuiltinTemplates() []Template {
+	caseColumns := []Column{
+		{Header: "Case ID", Field: "id", Element: "CaseID"},
+		{Header: "Title", Field: "title", Element: "Title"},
+		{Header: "Case Type", Field: "case_type", Element: "CaseType"},
+		{Header: "Status", Field: "status", Element: "Status"},
+		{Header: "External Case ID", Field: "external_case_id", Element: "ExternalCaseID"},
+		{Header: "Opened", Field: "created_at", Element: "Opened"},
+		{Header: "Closed", Field: "closed_at", Element: "Closed"},
+	}
+	evidenceColumns := []Column{
+		{Header: "Evidence ID", Field: "id", Element: "EvidenceID"},
+		{Header: "Name", Field: "name", Element: "Name"},
+		{Header: "Type", Field: "evidence_type", Element: "Type"},
+		{Header: "Description", Field: "description", Element: "Description"},
+		{Header: "Source", Field: "source", Element: "Source"},
+		{Header: "Collected", Field: "collected_at", Element: "Collected"},
+		{Header: "SHA-256", Field: "file_hash", Element: "SHA256"},
+		{Header: "Authenticated", Field: "is_authenticated", Element: "Authenticated"},
+	}
+	timelineColumns := []Column{
+		{Header: "Event ID", Field: "id", Element: "EventID"},
+		{Header: "Date", Field: "event_date", Element: "Date"},
+		{Header: "Type", Field: "event_type", Element: "Type"},
+		{Header: "Title", Field: "title", Element: "Title"},
+		{Header: "Description", Field: "description", Element: "Description"},
+		{Header: "Location", Field: "location", Element: "Location"},
+		{Header: "Participants", Field: "participants", Element: "Participants"},
+	}
+	sarColumns := []Column{
+		{Header: "Filing ID", Field: "id", Element: "FilingID"},
+		{Header: "Filing Type", Field: "filing_type", Element: "FilingType"},
+		{Header: "BSA ID", Field: "reference", Element: "BSAID"},
+		{Header: "Filed", Field: "filed_at", Element: "Filed"},
+		{Header: "Activity Start", Field: "activity_start", Element: "ActivityStart"},
+		{Header: "Activity End", Field: "activity_end", Element: "ActivityEnd"},
+		{Header: "Narrative", Field: "narrative", Element: "Narrative"},
+	}
+
+	return []Template{
+		{
+			ID:          "leo_workbook",
+			Name:        "Law enforcement production workbook",
+			Description: "Workbook with case, evidence, timeline and SAR filing tabs",
+			Format:      FormatXLSX,
+			Sections: []Section{
+				{Name: "Case", Source: SourceCase, Columns: caseColumns},
+				{Name: "Evidence", Source: SourceEvidence, Columns: evidenceColumns},
+				{Name: "Timeline", Source: SourceTimeline, Columns: timelineColumns},
+				{Name: "SAR Filings", Source: SourceSARFiling, Columns: sarColumns},
+			},
+		},
+		{
+			ID:          "leo_xml",
+			Name:        "Law enforcement production XML",
+			Description: "XML document with case, evidence, timeline and SAR filing records",
+			Format:      FormatXML,
+			RootElement: "LawEnforcementProduction",
+			Sections: []Section{
+				{Name: "Case", Source: SourceCase, Element: "Case", RecordElement: "CaseRecord", Columns: caseColumns},
+				{Name: "Evidence", Source: SourceEvidence, Element: "Evidence", RecordElement: "Item", Columns: evidenceColumns},
+				{Name: "Timeline", Source: SourceTimeline, Element: "Timeline", RecordElement: "Event", Columns: timelineColumns},
+				{Name: "SAR Filings", Source: SourceSARFiling, Element: "SARFilings", RecordElement: "Filing", Columns: sarColumns},
+			},
+		},
+	}
+}
+
+// LoadTemplates returns the built-in templates followed by those in path,
+// a JSON array of templates. An empty path loads only the built-in ones.
+// Templates in the file replace built-in templates with the same ID.
+func LoadTemplates(path string) ([]Template, error) {
+	templates := BuiltinTemplates()
+	if path == "" {
+		return templates, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read production templates")
+	}
+	var custom []Template
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return nil, errors.Wrap(err, "failed to decode production templates")
+	}
+
+	for _, template := range custom {
+		replaced := false
+		for i := range templates {
+			if templates[i].ID == template.ID {
+				templates[i] = template
+				replaced = true
+			}
+		}
+		if !replaced {
+			templates = append(templates, template)
+		}
+	}
+	return templates, nil
+}
+
+// Validate checks that a template names known sources and fields and that
+// its tab or element names are usable in its format
+func (t *Template) Validate() error {
+	if !templateID.MatchString(t.ID) {
+		return errors.Wrapf(ErrInvalidTemplate, "id %q must be lower-case letters, digits, dashes or underscores", t.ID)
+	}
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.Wrapf(ErrInvalidTemplate, "template %s: name is required", t.ID)
+	}
+	if t.Format != FormatXLSX && t.Format != FormatXML {
+		return errors.Wrapf(ErrInvalidTemplate, "template %s: unsupported format %q", t.ID, t.Format)
+	}
+	if t.Format == FormatXML && !xmlName.MatchString(t.RootElement) {
+		return errors.Wrapf(ErrInvalidTemplate, "template %s: invalid root element %q", t.ID, t.RootElement)
+	}
+	if len(t.Sections) == 0 {
+		return errors.Wrapf(ErrInvalidTemplate, "template %s: at least one section is required", t.ID)
+	}
+
+	names := map[string]bool{strings.ToLower(ManifestSheet): true}
+	for _, section := range t.Sections {
+		fields, ok := SourceFields[section.Source]
+		if !ok {
+			return errors.Wrapf(ErrInvalidTemplate, "template %s: unknown source %q", t.ID, section.Source)
+		}
+
+		name := strings.ToLower(section.Name)
+		if section.Name == "" || len(section.Name) > 31 || sheetBanned.MatchString(section.Name) || names[name] {
+			return errors.Wrapf(ErrInvalidTemplate, "template %s: invalid or duplicate section name %q", t.ID, section.Name)
+		}
+		names[name] = true
+
+		if t.Format == FormatXML && (!xmlName.MatchString(section.Element) || !xmlName.MatchString(section.RecordElement)) {
+			return errors.Wrapf(ErrInvalidTemplate, "template %s: section %s needs valid element and record_element names", t.ID, section.Name)
+		}
+		if len(section.Columns) == 0 {
+			return errors.Wrapf(ErrInvalidTemplate, "template %s: section %s has no columns", t.ID, section.Name)
+		}
+		for _, column := range section.Columns {
+			if !hasField(fields, column.Field) {
+				return errors.Wrapf(ErrInvalidTemplate, "template %s: section %s: unknown %s field %q", t.ID, section.Name, section.Source, column.Field)
+			}
+			if t.Format == FormatXML && !xmlName.MatchString(column.Element) {
+				return errors.Wrapf(ErrInvalidTemplate, "template %s: section %s: invalid element %q for field %s", t.ID, section.Name, column.Element, column.Field)
+			}
+		}
+	}
+	return nil
+}
+
+// Uses reports whether the template produces artifacts of a source
+func (t *Template) Uses(source string) bool {
+	for _, section := range t.Sections {
+		if section.Source == source {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentType is the media type of the template's format
+func (t *Template) ContentType() string {
+	if t.Format == FormatXML {
+		return "application/xml"
+	}
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+func hasField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
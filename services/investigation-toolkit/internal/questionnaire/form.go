@@ -0,0 +1,367 @@
+package questionnaire
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"investigation-toolkit/internal/models"
+)
+
+// ErrInvalidForm is returned for questionnaire templates that cannot be built
+var ErrInvalidForm = errors.New("invalid questionnaire")
+
+const (
+	// maxTextLength bounds answers to text questions, in characters
+	maxTextLength = 500
+	// maxLongTextLength bounds answers to long text questions, in characters
+	maxLongTextLength = 10000
+	// dateLayout is the format of answers to date questions
+	dateLayout = "2006-01-02"
+)
+
+var questionTypes = map[models.QuestionType]bool{
+	models.QuestionTypeText:           true,
+	models.QuestionTypeLongText:       true,
+	models.QuestionTypeNumber:         true,
+	models.QuestionTypeDate:           true,
+	models.QuestionTypeYesNo:          true,
+	models.QuestionTypeSingleChoice:   true,
+	models.QuestionTypeMultipleChoice: true,
+}
+
+// FieldError is a problem with the answer to one question
+type FieldError struct {
+	QuestionID string `json:"question_id"`
+	Message    string `json:"message"`
+}
+
+// ValidationError lists every problem found in a set of answers
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		problems = append(problems, field.QuestionID+": "+field.Message)
+	}
+	return "invalid answers: " + strings.Join(problems, "; ")
+}
+
+func (e *ValidationError) add(questionID, format string, args ...interface{}) {
+	e.Fields = append(e.Fields, FieldError{QuestionID: questionID, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ValidateForm checks that questions make a usable form. Branching may only
+// depend on an earlier yes/no or choice question, so forms cannot loop.
+func ValidateForm(questions []models.Question) error {
+	if len(questions) == 0 {
+		return errors.Wrap(ErrInvalidForm, "at least one question is required")
+	}
+
+	seen := make(map[string]*models.Question, len(questions))
+	for i := range questions {
+		q := &questions[i]
+		if strings.TrimSpace(q.ID) == "" {
+			return errors.Wrapf(ErrInvalidForm, "question %d has no id", i+1)
+		}
+		if seen[q.ID] != nil {
+			return errors.Wrapf(ErrInvalidForm, "duplicate question id %q", q.ID)
+		}
+		if strings.TrimSpace(q.Text) == "" {
+			return errors.Wrapf(ErrInvalidForm, "question %q has no text", q.ID)
+		}
+		if !questionTypes[q.Type] {
+			return errors.Wrapf(ErrInvalidForm, "question %q has unknown type %q", q.ID, q.Type)
+		}
+
+		if isChoice(q.Type) {
+			if len(q.Options) < 2 {
+				return errors.Wrapf(ErrInvalidForm, "choice question %q needs at least two options", q.ID)
+			}
+			values := make(map[string]bool, len(q.Options))
+			for _, option := range q.Options {
+				if option.Value == "" || values[option.Value] {
+					return errors.Wrapf(ErrInvalidForm, "question %q has an empty or duplicate option value", q.ID)
+				}
+				values[option.Value] = true
+			}
+		} else if len(q.Options) > 0 {
+			return errors.Wrapf(ErrInvalidForm, "question %q is not a choice question but has options", q.ID)
+		}
+
+		if q.Min != nil || q.Max != nil {
+			if q.Type != models.QuestionTypeNumber {
+				return errors.Wrapf(ErrInvalidForm, "only number questions may have a min or max, not %q", q.ID)
+			}
+			if q.Min != nil && q.Max != nil && *q.Min > *q.Max {
+				return errors.Wrapf(ErrInvalidForm, "question %q has min above max", q.ID)
+			}
+		}
+
+		if q.ShowIf != nil {
+			if err := validateCondition(q, seen[q.ShowIf.QuestionID]); err != nil {
+				return err
+			}
+		}
+
+		seen[q.ID] = q
+	}
+
+	return nil
+}
+
+func validateCondition(q, parent *models.Question) error {
+	if parent == nil {
+		return errors.Wrapf(ErrInvalidForm, "question %q depends on %q, which is not an earlier question",
+			q.ID, q.ShowIf.QuestionID)
+	}
+	if parent.Type != models.QuestionTypeYesNo && !isChoice(parent.Type) {
+		return errors.Wrapf(ErrInvalidForm, "question %q depends on %q, which is not a yes/no or choice question",
+			q.ID, parent.ID)
+	}
+	if len(q.ShowIf.AnyOf) == 0 {
+		return errors.Wrapf(ErrInvalidForm, "question %q has a condition without values", q.ID)
+	}
+
+	for _, value := range q.ShowIf.AnyOf {
+		if parent.Type == models.QuestionTypeYesNo {
+			if value != "yes" && value != "no" {
+				return errors.Wrapf(ErrInvalidForm, "question %q depends on yes/no question %q being %q",
+					q.ID, parent.ID, value)
+			}
+			continue
+		}
+		if optionLabel(parent, value) == "" {
+			return errors.Wrapf(ErrInvalidForm, "question %q depends on %q being %q, which is not one of its options",
+				q.ID, parent.ID, value)
+		}
+	}
+	return nil
+}
+
+// Visible reports which questions are asked given the answers so far. A
+// question is asked when it has no condition, or when the question it
+// depends on is asked and its answer meets the condition.
+func Visible(questions []models.Question, answers map[string]interface{}) map[string]bool {
+	visible := make(map[string]bool, len(questions))
+	for i := range questions {
+		q := &questions[i]
+		if q.ShowIf == nil {
+			visible[q.ID] = true
+			continue
+		}
+		parent := q.ShowIf.QuestionID
+		visible[q.ID] = visible[parent] && conditionMet(q.ShowIf, answers[parent])
+	}
+	return visible
+}
+
+func conditionMet(condition *models.QuestionCondition, answer interface{}) bool {
+	var given []string
+	switch v := answer.(type) {
+	case bool:
+		given = []string{yesNo(v)}
+	case string:
+		given = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				given = append(given, s)
+			}
+		}
+	}
+
+	for _, value := range given {
+		for _, want := range condition.AnyOf {
+			if value == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckAnswers type-checks answers against the questions they answer.
+// With complete set, every asked required question must also be answered;
+// answers to questions that are not asked are allowed until then.
+func CheckAnswers(questions []models.Question, answers map[string]interface{}, complete bool) error {
+	problems := &ValidationError{}
+
+	byID := make(map[string]*models.Question, len(questions))
+	for i := range questions {
+		byID[questions[i].ID] = &questions[i]
+	}
+	for id := range answers {
+		if byID[id] == nil {
+			problems.add(id, "not a question in this questionnaire")
+		}
+	}
+
+	visible := Visible(questions, answers)
+	for i := range questions {
+		q := &questions[i]
+		answer, answered := answers[q.ID]
+		if answered {
+			if message := checkAnswer(q, answer); message != "" {
+				problems.add(q.ID, message)
+			}
+			continue
+		}
+		if complete && q.Required && visible[q.ID] {
+			problems.add(q.ID, "an answer is required")
+		}
+	}
+
+	return problems.err()
+}
+
+// checkAnswer returns what is wrong with one answer, or "" when it is valid.
+// Answers are as decoded from JSON.
+func checkAnswer(q *models.Question, answer interface{}) string {
+	switch q.Type {
+	case models.QuestionTypeText, models.QuestionTypeLongText:
+		text, ok := answer.(string)
+		if !ok {
+			return "must be text"
+		}
+		limit := maxTextLength
+		if q.Type == models.QuestionTypeLongText {
+			limit = maxLongTextLength
+		}
+		if strings.TrimSpace(text) == "" {
+			return "must not be blank"
+		}
+		if len([]rune(text)) > limit {
+			return fmt.Sprintf("must be at most %d characters", limit)
+		}
+
+	case models.QuestionTypeNumber:
+		number, ok := answer.(float64)
+		if !ok || math.IsNaN(number) || math.IsInf(number, 0) {
+			return "must be a number"
+		}
+		if q.Min != nil && number < *q.Min {
+			return fmt.Sprintf("must be at least %s", formatNumber(*q.Min))
+		}
+		if q.Max != nil && number > *q.Max {
+			return fmt.Sprintf("must be at most %s", formatNumber(*q.Max))
+		}
+
+	case models.QuestionTypeDate:
+		date, ok := answer.(string)
+		if !ok {
+			return "must be a date"
+		}
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return "must be a date in YYYY-MM-DD format"
+		}
+
+	case models.QuestionTypeYesNo:
+		if _, ok := answer.(bool); !ok {
+			return "must be true or false"
+		}
+
+	case models.QuestionTypeSingleChoice:
+		value, ok := answer.(string)
+		if !ok || optionLabel(q, value) == "" {
+			return "must be one of the question's options"
+		}
+
+	case models.QuestionTypeMultipleChoice:
+		values, ok := answer.([]interface{})
+		if !ok || len(values) == 0 {
+			return "must be a list of the question's options"
+		}
+		chosen := make(map[string]bool, len(values))
+		for _, item := range values {
+			value, ok := item.(string)
+			if !ok || optionLabel(q, value) == "" {
+				return "must be a list of the question's options"
+			}
+			if chosen[value] {
+				return fmt.Sprintf("lists %q more than once", value)
+			}
+			chosen[value] = true
+		}
+	}
+
+	return ""
+}
+
+// DisplayAnswer renders an answer for a report: choices by their labels,
+// yes/no as Yes or No and dates and numbers as given
+func DisplayAnswer(q *models.Question, answer interface{}) string {
+	if answer == nil {
+		return ""
+	}
+
+	switch v := answer.(type) {
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	case float64:
+		return formatNumber(v)
+	case string:
+		if q.Type == models.QuestionTypeSingleChoice {
+			if label := optionLabel(q, v); label != "" {
+				return label
+			}
+		}
+		return v
+	case []interface{}:
+		labels := make([]string, 0, len(v))
+		for _, item := range v {
+			value := fmt.Sprint(item)
+			if label := optionLabel(q, value); label != "" {
+				value = label
+			}
+			labels = append(labels, value)
+		}
+		return strings.Join(labels, "; ")
+	}
+	return fmt.Sprint(answer)
+}
+
+func isChoice(questionType models.QuestionType) bool {
+	return questionType == models.QuestionTypeSingleChoice || questionType == models.QuestionTypeMultipleChoice
+}
+
+// optionLabel returns the label of a question's option, or "" when the
+// question has no such option. Options without a label are shown by value.
+func optionLabel(q *models.Question, value string) string {
+	for _, option := range q.Options {
+		if option.Value == value {
+			if option.Label == "" {
+				return option.Value
+			}
+			return option.Label
+		}
+	}
+	return ""
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
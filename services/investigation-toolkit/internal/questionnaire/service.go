@@ -0,0 +1,395 @@
+package questionnaire
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/models"
+)
+
+var (
+	// ErrTemplateNotDraft is returned when editing or publishing a template
+	// that has already been published or archived
+	ErrTemplateNotDraft = errors.New("questionnaire template is not a draft")
+	// ErrTemplateNotPublished is returned when assigning a template that is
+	// not published
+	ErrTemplateNotPublished = errors.New("questionnaire template is not published")
+	// ErrInvalidAssignment is returned for assignments missing an assignee
+	ErrInvalidAssignment = errors.New("invalid questionnaire assignment")
+	// ErrAssignmentClosed is returned when changing a completed or cancelled questionnaire
+	ErrAssignmentClosed = errors.New("questionnaire is no longer open")
+	// ErrNotAssignee is returned when someone other than the assignee
+	// answers or submits a questionnaire
+	ErrNotAssignee = errors.New("questionnaire is assigned to another user")
+)
+
+// Store persists questionnaire templates and their assignments to cases
+type Store interface {
+	CaseExists(ctx context.Context, investigationID uuid.UUID) error
+	CreateTemplate(ctx context.Context, template *models.QuestionnaireTemplate) error
+	GetTemplate(ctx context.Context, id uuid.UUID) (*models.QuestionnaireTemplate, error)
+	ListTemplates(ctx context.Context, status models.QuestionnaireTemplateStatus) ([]models.QuestionnaireTemplate, error)
+	// UpdateTemplate saves a draft template's name, description and questions
+	UpdateTemplate(ctx context.Context, template *models.QuestionnaireTemplate) error
+	SetTemplateStatus(ctx context.Context, template *models.QuestionnaireTemplate, from models.QuestionnaireTemplateStatus) error
+	CreateAssignment(ctx context.Context, assignment *models.QuestionnaireAssignment) error
+	GetAssignment(ctx context.Context, id uuid.UUID) (*models.QuestionnaireAssignment, error)
+	ListAssignments(ctx context.Context, investigationID uuid.UUID) ([]models.QuestionnaireAssignment, error)
+	// UpdateAssignment saves an assignment's answers and status unless it
+	// was changed since it was read at previous
+	UpdateAssignment(ctx context.Context, assignment *models.QuestionnaireAssignment, previous time.Time) error
+}
+
+// Service builds questionnaire templates, such as those of periodic KYC
+// refreshes, assigns them within review cases and validates the answers
+type Service struct {
+	store  Store
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewService creates a new questionnaire service
+func NewService(store Store, logger *zap.Logger) *Service {
+	return &Service{
+		store:  store,
+		logger: logger.Named("questionnaire"),
+		now:    time.Now,
+	}
+}
+
+// CreateTemplate creates a draft template
+func (s *Service) CreateTemplate(ctx context.Context, req *models.QuestionnaireTemplateRequest, createdBy uuid.UUID) (*models.QuestionnaireTemplate, error) {
+	template := &models.QuestionnaireTemplate{
+		Status:    models.QuestionnaireTemplateStatusDraft,
+		CreatedBy: createdBy,
+	}
+	if err := applyTemplateRequest(template, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.CreateTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Questionnaire template created",
+		zap.String("template_id", template.ID.String()),
+		zap.Int("questions", len(template.Questions)))
+
+	return template, nil
+}
+
+// UpdateTemplate replaces a draft template's content
+func (s *Service) UpdateTemplate(ctx context.Context, id uuid.UUID, req *models.QuestionnaireTemplateRequest) (*models.QuestionnaireTemplate, error) {
+	template, err := s.store.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template.Status != models.QuestionnaireTemplateStatusDraft {
+		return nil, ErrTemplateNotDraft
+	}
+
+	if err := applyTemplateRequest(template, req); err != nil {
+		return nil, err
+	}
+	if err := s.store.UpdateTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// GetTemplate retrieves a template
+func (s *Service) GetTemplate(ctx context.Context, id uuid.UUID) (*models.QuestionnaireTemplate, error) {
+	return s.store.GetTemplate(ctx, id)
+}
+
+// Templates lists templates, optionally only those with a status
+func (s *Service) Templates(ctx context.Context, status models.QuestionnaireTemplateStatus) ([]models.QuestionnaireTemplate, error) {
+	return s.store.ListTemplates(ctx, status)
+}
+
+// Publish freezes a draft template so it can be assigned
+func (s *Service) Publish(ctx context.Context, id uuid.UUID) (*models.QuestionnaireTemplate, error) {
+	template, err := s.store.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template.Status != models.QuestionnaireTemplateStatusDraft {
+		return nil, ErrTemplateNotDraft
+	}
+
+	now := s.now()
+	template.Status = models.QuestionnaireTemplateStatusPublished
+	template.PublishedAt = &now
+	if err := s.store.SetTemplateStatus(ctx, template, models.QuestionnaireTemplateStatusDraft); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Questionnaire template published", zap.String("template_id", id.String()))
+	return template, nil
+}
+
+// Archive withdraws a template from new assignments. Questionnaires already
+// assigned from it are unaffected.
+func (s *Service) Archive(ctx context.Context, id uuid.UUID) (*models.QuestionnaireTemplate, error) {
+	template, err := s.store.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template.Status == models.QuestionnaireTemplateStatusArchived {
+		return template, nil
+	}
+
+	from := template.Status
+	template.Status = models.QuestionnaireTemplateStatusArchived
+	if err := s.store.SetTemplateStatus(ctx, template, from); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Questionnaire template archived", zap.String("template_id", id.String()))
+	return template, nil
+}
+
+// Assign gives a published questionnaire to a user within a review case
+func (s *Service) Assign(ctx context.Context, investigationID uuid.UUID, req *models.AssignQuestionnaireRequest, assignedBy uuid.UUID) (*models.QuestionnaireAssignment, error) {
+	if req.AssignedTo == uuid.Nil {
+		return nil, errors.Wrap(ErrInvalidAssignment, "assigned_to is required")
+	}
+	if err := s.store.CaseExists(ctx, investigationID); err != nil {
+		return nil, err
+	}
+
+	template, err := s.store.GetTemplate(ctx, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if template.Status != models.QuestionnaireTemplateStatusPublished {
+		return nil, ErrTemplateNotPublished
+	}
+
+	assignment := &models.QuestionnaireAssignment{
+		InvestigationID: investigationID,
+		TemplateID:      template.ID,
+		TemplateName:    template.Name,
+		Questions:       template.Questions,
+		Answers:         models.JSONB{},
+		Status:          models.QuestionnaireStatusAssigned,
+		AssignedTo:      req.AssignedTo,
+		AssignedBy:      assignedBy,
+		DueDate:         req.DueDate,
+	}
+	if err := s.store.CreateAssignment(ctx, assignment); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Questionnaire assigned",
+		zap.String("assignment_id", assignment.ID.String()),
+		zap.String("investigation_id", investigationID.String()),
+		zap.String("template_id", template.ID.String()),
+		zap.String("assigned_to", req.AssignedTo.String()))
+
+	return assignment, nil
+}
+
+// GetAssignment retrieves an assigned questionnaire
+func (s *Service) GetAssignment(ctx context.Context, id uuid.UUID) (*models.QuestionnaireAssignment, error) {
+	return s.store.GetAssignment(ctx, id)
+}
+
+// Assignments lists the questionnaires assigned within a case
+func (s *Service) Assignments(ctx context.Context, investigationID uuid.UUID) ([]models.QuestionnaireAssignment, error) {
+	if err := s.store.CaseExists(ctx, investigationID); err != nil {
+		return nil, err
+	}
+	return s.store.ListAssignments(ctx, investigationID)
+}
+
+// SaveResponses records answers without requiring the questionnaire to be
+// complete. Answers are merged into those saved before; a null answer
+// clears one.
+func (s *Service) SaveResponses(ctx context.Context, id uuid.UUID, req *models.SaveQuestionnaireResponsesRequest, userID uuid.UUID) (*models.QuestionnaireAssignment, error) {
+	assignment, err := s.openAssignment(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	answers := make(map[string]interface{}, len(assignment.Answers)+len(req.Answers))
+	for questionID, answer := range assignment.Answers {
+		answers[questionID] = answer
+	}
+	for questionID, answer := range req.Answers {
+		if answer == nil {
+			delete(answers, questionID)
+			continue
+		}
+		answers[questionID] = answer
+	}
+	if err := CheckAnswers(assignment.Questions, answers, false); err != nil {
+		return nil, err
+	}
+
+	previous := assignment.UpdatedAt
+	assignment.Answers = answers
+	assignment.Status = models.QuestionnaireStatusInProgress
+	if err := s.store.UpdateAssignment(ctx, assignment, previous); err != nil {
+		return nil, err
+	}
+
+	return assignment, nil
+}
+
+// Submit completes a questionnaire once every required question that is
+// asked has a valid answer. Answers to questions that are no longer asked
+// are dropped.
+func (s *Service) Submit(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.QuestionnaireAssignment, error) {
+	assignment, err := s.openAssignment(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := CheckAnswers(assignment.Questions, assignment.Answers, true); err != nil {
+		return nil, err
+	}
+
+	visible := Visible(assignment.Questions, assignment.Answers)
+	answers := models.JSONB{}
+	for questionID, answer := range assignment.Answers {
+		if visible[questionID] {
+			answers[questionID] = answer
+		}
+	}
+
+	now := s.now()
+	previous := assignment.UpdatedAt
+	assignment.Answers = answers
+	assignment.Status = models.QuestionnaireStatusCompleted
+	assignment.SubmittedBy = &userID
+	assignment.SubmittedAt = &now
+	if err := s.store.UpdateAssignment(ctx, assignment, previous); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Questionnaire submitted",
+		zap.String("assignment_id", id.String()),
+		zap.String("investigation_id", assignment.InvestigationID.String()))
+
+	return assignment, nil
+}
+
+// Cancel withdraws an open questionnaire
+func (s *Service) Cancel(ctx context.Context, id uuid.UUID, cancelledBy uuid.UUID) (*models.QuestionnaireAssignment, error) {
+	assignment, err := s.store.GetAssignment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !assignment.Open() {
+		return nil, ErrAssignmentClosed
+	}
+
+	now := s.now()
+	previous := assignment.UpdatedAt
+	assignment.Status = models.QuestionnaireStatusCancelled
+	assignment.CancelledBy = &cancelledBy
+	assignment.CancelledAt = &now
+	if err := s.store.UpdateAssignment(ctx, assignment, previous); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Questionnaire cancelled",
+		zap.String("assignment_id", id.String()),
+		zap.String("cancelled_by", cancelledBy.String()))
+
+	return assignment, nil
+}
+
+// Report builds the questionnaire section of a case report from the case's
+// completed questionnaires, oldest submission first
+func (s *Service) Report(ctx context.Context, investigationID uuid.UUID) (*models.QuestionnaireReport, error) {
+	assignments, err := s.Assignments(ctx, investigationID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.QuestionnaireReport{
+		InvestigationID: investigationID,
+		GeneratedAt:     s.now(),
+		Questionnaires:  []models.QuestionnaireReportEntry{},
+	}
+	for i := range assignments {
+		assignment := &assignments[i]
+		if assignment.Status != models.QuestionnaireStatusCompleted {
+			continue
+		}
+
+		entry := models.QuestionnaireReportEntry{
+			AssignmentID: assignment.ID,
+			TemplateName: assignment.TemplateName,
+			AssignedTo:   assignment.AssignedTo,
+			SubmittedBy:  assignment.SubmittedBy,
+			SubmittedAt:  assignment.SubmittedAt,
+			Answers:      []models.QuestionnaireReportItem{},
+		}
+		visible := Visible(assignment.Questions, assignment.Answers)
+		for j := range assignment.Questions {
+			q := &assignment.Questions[j]
+			if !visible[q.ID] {
+				continue
+			}
+			entry.Answers = append(entry.Answers, models.QuestionnaireReportItem{
+				QuestionID: q.ID,
+				Question:   q.Text,
+				Answer:     DisplayAnswer(q, assignment.Answers[q.ID]),
+			})
+		}
+		report.Questionnaires = append(report.Questionnaires, entry)
+	}
+
+	sortBySubmission(report.Questionnaires)
+	return report, nil
+}
+
+// openAssignment retrieves an assignment its assignee may still answer
+func (s *Service) openAssignment(ctx context.Context, id, userID uuid.UUID) (*models.QuestionnaireAssignment, error) {
+	assignment, err := s.store.GetAssignment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !assignment.Open() {
+		return nil, ErrAssignmentClosed
+	}
+	if assignment.AssignedTo != userID {
+		return nil, ErrNotAssignee
+	}
+	if assignment.Answers == nil {
+		assignment.Answers = models.JSONB{}
+	}
+	return assignment, nil
+}
+
+func applyTemplateRequest(template *models.QuestionnaireTemplate, req *models.QuestionnaireTemplateRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.Wrap(ErrInvalidForm, "name is required")
+	}
+	if err := ValidateForm(req.Questions); err != nil {
+		return err
+	}
+
+	template.Name = name
+	template.Description = req.Description
+	template.Questions = req.Questions
+	return nil
+}
+
+func sortBySubmission(entries []models.QuestionnaireReportEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].SubmittedAt, entries[j].SubmittedAt
+		return a != nil && b != nil && a.Before(*b)
+	})
+}
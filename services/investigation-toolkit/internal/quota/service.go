@@ -0,0 +1,425 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	limits "aegisshield/shared/quota"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+)
+
+// Object types charged against quota
+const (
+	ObjectEvidence = "evidence"
+)
+
+var (
+	// ErrExceeded is returned when an upload would pass the hard stop of
+	// its tenant's or uploader's quota
+	ErrExceeded = limits.ErrExceeded
+	// ErrInvalidSize is returned for uploads of negative size
+	ErrInvalidSize = errors.New("upload size must not be negative")
+	// ErrInvalidSubject is returned for malformed tenant or user IDs
+	ErrInvalidSubject = errors.New("invalid quota subject")
+	// ErrInvalidLimit is returned for negative limits
+	ErrInvalidLimit = errors.New("quota limits must not be negative")
+)
+
+// Store persists storage usage, limit overrides, reservations and charges
+type Store interface {
+	// Reserve locks the usage of the reservation's tenant and user, calls
+	// check with it and, unless check fails, reserves the reservation's
+	// bytes and one file against both. Concurrent reservations for the same
+	// tenant or user are checked one after another.
+	Reserve(ctx context.Context, reservation *models.StorageReservation, check func(tenant, user *models.StorageQuotaUsage) error) error
+	// Commit charges an object's stored bytes to the reservation's tenant
+	// and user, replacing any earlier charge for the object, and frees the
+	// reservation if it has not already expired. It returns the updated usage.
+	Commit(ctx context.Context, reservation *models.StorageReservation, objectType string, objectID uuid.UUID) (tenant, user *models.StorageQuotaUsage, err error)
+	Release(ctx context.Context, reservationID uuid.UUID) error
+	// ReleaseExpired frees the reservations of uploads that neither
+	// completed nor failed in time
+	ReleaseExpired(ctx context.Context, now time.Time) (int, error)
+	// Uncharge removes an object's charge, if any
+	Uncharge(ctx context.Context, objectType string, objectID uuid.UUID) error
+	GetUsage(ctx context.Context, scope, subjectID string) (*models.StorageQuotaUsage, error)
+	ListUsage(ctx context.Context, scope string) ([]models.StorageQuotaUsage, error)
+	SetLimit(ctx context.Context, limit *models.StorageQuotaLimit) error
+	// MarkWarned records the level a subject's uploaders were last notified
+	// of, if it is still from. It reports whether the level was changed.
+	MarkWarned(ctx context.Context, scope, subjectID, from, to string) (bool, error)
+}
+
+// Notifier tells users about their storage quota
+type Notifier interface {
+	CreateNotification(ctx context.Context, notification *models.NotificationEvent) error
+}
+
+// Report is a tenant's or user's storage usage against its limits
+type Report struct {
+	limits.Usage
+	Level limits.Level `json:"level"`
+	// RemainingBytes is what can still be stored before the hard stop, or
+	// -1 without a byte limit
+	RemainingBytes  int64   `json:"remaining_bytes"`
+	WarningPercent  float64 `json:"warning_percent"`
+	HardStopPercent float64 `json:"hard_stop_percent"`
+	// Overridden is set when the limits replace the configured defaults
+	Overridden bool `json:"overridden"`
+}
+
+// Service enforces per-tenant and per-user evidence storage quotas. Uploads
+// reserve their size before they are stored, so that a burst of concurrent
+// uploads cannot together pass a limit, and commit the reservation once the
+// file is stored. Uploaders are notified when an upload takes its tenant or
+// them past the warning threshold.
+type Service struct {
+	store      Store
+	notifier   Notifier
+	thresholds limits.Thresholds
+	config     config.QuotaConfig
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewService creates a new quota service. notifier may be nil.
+func NewService(store Store, notifier Notifier, cfg config.QuotaConfig, logger *zap.Logger) *Service {
+	return &Service{
+		store:    store,
+		notifier: notifier,
+		thresholds: limits.Thresholds{
+			WarningPercent:  cfg.WarningPercent,
+			HardStopPercent: cfg.HardStopPercent,
+		},
+		config: cfg,
+		logger: logger.Named("quota"),
+		now:    time.Now,
+	}
+}
+
+// Tenant returns the tenant a request is made for
+func (s *Service) Tenant(r *http.Request) (string, error) {
+	tenantID, err := limits.Tenant(r, s.config.TenantHeader, s.config.DefaultTenant)
+	if err != nil {
+		return "", errors.Wrap(ErrInvalidSubject, err.Error())
+	}
+	return tenantID, nil
+}
+
+// DefaultTenant is the tenant of requests that name none
+func (s *Service) DefaultTenant() string {
+	return s.config.DefaultTenant
+}
+
+// Check reports whether an upload of size bytes would fit within the quota
+// of a tenant and user, without reserving anything
+func (s *Service) Check(ctx context.Context, tenantID string, userID uuid.UUID, size int64) (limits.Decision, error) {
+	if size < 0 {
+		return limits.Decision{}, ErrInvalidSize
+	}
+
+	tenant, err := s.store.GetUsage(ctx, string(limits.ScopeTenant), tenantID)
+	if err != nil {
+		return limits.Decision{}, err
+	}
+	user, err := s.store.GetUsage(ctx, string(limits.ScopeUser), userID.String())
+	if err != nil {
+		return limits.Decision{}, err
+	}
+
+	return s.thresholds.Evaluate(size, s.usage(tenant), s.usage(user)), nil
+}
+
+// Reserve holds quota for an upload of size bytes. Uploads that would pass
+// a hard stop fail with ErrExceeded and the decision explaining why. The
+// reservation must be committed once the file is stored, or released if
+// the upload fails.
+func (s *Service) Reserve(ctx context.Context, tenantID string, userID uuid.UUID, size int64) (*models.StorageReservation, limits.Decision, error) {
+	if size < 0 {
+		return nil, limits.Decision{}, ErrInvalidSize
+	}
+
+	now := s.now()
+	reservation := &models.StorageReservation{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		UserID:    userID,
+		Bytes:     size,
+		ExpiresAt: now.Add(s.config.ReservationTTL),
+		CreatedAt: now,
+	}
+
+	var decision limits.Decision
+	err := s.store.Reserve(ctx, reservation, func(tenant, user *models.StorageQuotaUsage) error {
+		decision = s.thresholds.Evaluate(size, s.usage(tenant), s.usage(user))
+		if !decision.Allowed {
+			return ErrExceeded
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrExceeded) {
+			s.logger.Warn("Upload refused by storage quota",
+				zap.String("tenant_id", tenantID),
+				zap.String("user_id", userID.String()),
+				zap.Int64("size", size),
+				zap.String("scope", string(decision.Scope)))
+		}
+		return nil, decision, err
+	}
+
+	return reservation, decision, nil
+}
+
+// Commit charges a stored object to its reservation and notifies the
+// uploader if the upload took its tenant or them to a higher quota level
+func (s *Service) Commit(ctx context.Context, reservation *models.StorageReservation, objectType string, objectID uuid.UUID) error {
+	tenant, user, err := s.store.Commit(ctx, reservation, objectType, objectID)
+	if err != nil {
+		return err
+	}
+
+	for _, usage := range []*models.StorageQuotaUsage{tenant, user} {
+		s.updateLevel(ctx, usage, reservation.UserID)
+	}
+	return nil
+}
+
+// Release returns the quota of an upload that failed
+func (s *Service) Release(ctx context.Context, reservation *models.StorageReservation) {
+	if err := s.store.Release(ctx, reservation.ID); err != nil {
+		s.logger.Error("Failed to release storage reservation",
+			zap.String("reservation_id", reservation.ID.String()),
+			zap.Error(err))
+	}
+}
+
+// Remove returns the quota charged for a deleted object
+func (s *Service) Remove(ctx context.Context, objectType string, objectID uuid.UUID) error {
+	return s.store.Uncharge(ctx, objectType, objectID)
+}
+
+// Usage reports a tenant's or user's storage usage
+func (s *Service) Usage(ctx context.Context, scope limits.Scope, subjectID string) (*Report, error) {
+	if err := validateSubject(scope, subjectID); err != nil {
+		return nil, err
+	}
+
+	usage, err := s.store.GetUsage(ctx, string(scope), subjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.report(usage), nil
+}
+
+// ListUsage reports the storage usage of every tenant or user holding any
+func (s *Service) ListUsage(ctx context.Context, scope limits.Scope) ([]Report, error) {
+	usages, err := s.store.ListUsage(ctx, string(scope))
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]Report, 0, len(usages))
+	for i := range usages {
+		reports = append(reports, *s.report(&usages[i]))
+	}
+	return reports, nil
+}
+
+// SetLimit replaces the configured limits for one tenant or user
+func (s *Service) SetLimit(ctx context.Context, scope limits.Scope, subjectID string, req *models.SetStorageQuotaRequest, updatedBy uuid.UUID) (*Report, error) {
+	if err := validateSubject(scope, subjectID); err != nil {
+		return nil, err
+	}
+	if (req.LimitBytes != nil && *req.LimitBytes < 0) || (req.LimitFiles != nil && *req.LimitFiles < 0) {
+		return nil, ErrInvalidLimit
+	}
+
+	limit := &models.StorageQuotaLimit{
+		Scope:      string(scope),
+		SubjectID:  subjectID,
+		LimitBytes: req.LimitBytes,
+		LimitFiles: req.LimitFiles,
+		UpdatedBy:  updatedBy,
+		UpdatedAt:  s.now(),
+	}
+	if err := s.store.SetLimit(ctx, limit); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Storage quota set",
+		zap.String("scope", string(scope)),
+		zap.String("subject_id", subjectID),
+		zap.String("updated_by", updatedBy.String()))
+
+	return s.Usage(ctx, scope, subjectID)
+}
+
+// Run releases expired reservations until the context is cancelled
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := s.store.ReleaseExpired(ctx, s.now())
+			if err != nil {
+				s.logger.Error("Failed to release expired storage reservations", zap.Error(err))
+				continue
+			}
+			if released > 0 {
+				s.logger.Info("Expired storage reservations released", zap.Int("count", released))
+			}
+		}
+	}
+}
+
+// updateLevel records a subject's quota level after an upload, notifying
+// the uploader when it rises. Only the upload that wins the swap notifies,
+// so a burst of uploads crossing a threshold together sends one notification.
+func (s *Service) updateLevel(ctx context.Context, usage *models.StorageQuotaUsage, uploader uuid.UUID) {
+	level := s.thresholds.Level(s.usage(usage))
+	warned := limits.Level(usage.WarnedLevel)
+	if warned == "" {
+		warned = limits.LevelOK
+	}
+	if level == warned {
+		return
+	}
+
+	changed, err := s.store.MarkWarned(ctx, usage.Scope, usage.SubjectID, usage.WarnedLevel, string(level))
+	if err != nil {
+		s.logger.Error("Failed to record storage quota level", zap.Error(err))
+		return
+	}
+	// Falling levels are recorded so the next rise notifies again
+	if !changed || level.Rank() < warned.Rank() {
+		return
+	}
+
+	s.notify(ctx, s.report(usage), uploader)
+}
+
+func (s *Service) notify(ctx context.Context, report *Report, uploader uuid.UUID) {
+	s.logger.Warn("Storage quota threshold reached",
+		zap.String("scope", string(report.Scope)),
+		zap.String("subject_id", report.Subject),
+		zap.String("level", string(report.Level)),
+		zap.Int64("bytes", report.Bytes()),
+		zap.Int64("limit_bytes", report.Limits.Bytes))
+
+	if s.notifier == nil {
+		return
+	}
+
+	owner := "Your"
+	if report.Scope == limits.ScopeTenant {
+		owner = fmt.Sprintf("Tenant %s", report.Subject)
+	}
+	title := "Storage quota warning"
+	message := fmt.Sprintf("%s evidence storage has reached %.0f%% of its quota", owner, percentUsed(report.Usage))
+	if report.Level == limits.LevelExceeded {
+		title = "Storage quota exceeded"
+		message = fmt.Sprintf("%s evidence storage is over quota; further uploads will be refused", owner)
+	}
+
+	notification := &models.NotificationEvent{
+		UserID:     uploader,
+		Type:       "storage_quota_" + string(report.Level),
+		Title:      title,
+		Message:    message,
+		EntityType: "storage_quota",
+		EntityID:   uploader,
+		Metadata: map[string]interface{}{
+			"scope":       string(report.Scope),
+			"subject_id":  report.Subject,
+			"used_bytes":  report.UsedBytes,
+			"used_files":  report.UsedFiles,
+			"limit_bytes": report.Limits.Bytes,
+			"limit_files": report.Limits.Files,
+		},
+		IsRead: false,
+	}
+
+	if err := s.notifier.CreateNotification(ctx, notification); err != nil {
+		s.logger.Error("Failed to send storage quota notification", zap.Error(err))
+	}
+}
+
+// usage applies the configured limits to a subject without its own
+func (s *Service) usage(u *models.StorageQuotaUsage) limits.Usage {
+	usage := limits.Usage{
+		Scope:         limits.Scope(u.Scope),
+		Subject:       u.SubjectID,
+		UsedBytes:     u.UsedBytes,
+		UsedFiles:     u.UsedFiles,
+		ReservedBytes: u.ReservedBytes,
+		ReservedFiles: u.ReservedFiles,
+		Limits:        limits.Limits{Bytes: s.config.UserLimitBytes, Files: s.config.UserLimitFiles},
+	}
+	if usage.Scope == limits.ScopeTenant {
+		usage.Limits = limits.Limits{Bytes: s.config.TenantLimitBytes, Files: s.config.TenantLimitFiles}
+	}
+	if u.LimitBytes != nil {
+		usage.Limits.Bytes = *u.LimitBytes
+	}
+	if u.LimitFiles != nil {
+		usage.Limits.Files = *u.LimitFiles
+	}
+	return usage
+}
+
+func (s *Service) report(u *models.StorageQuotaUsage) *Report {
+	usage := s.usage(u)
+	return &Report{
+		Usage:           usage,
+		Level:           s.thresholds.Level(usage),
+		RemainingBytes:  s.thresholds.Remaining(usage),
+		WarningPercent:  s.thresholds.WarningPercent,
+		HardStopPercent: s.thresholds.HardStopPercent,
+		Overridden:      u.LimitBytes != nil || u.LimitFiles != nil,
+	}
+}
+
+// percentUsed is the larger of the byte and file usage as a percentage of
+// its limit
+func percentUsed(u limits.Usage) float64 {
+	var percent float64
+	if u.Limits.Bytes > 0 {
+		percent = float64(u.Bytes()) * 100 / float64(u.Limits.Bytes)
+	}
+	if u.Limits.Files > 0 {
+		if files := float64(u.Files()) * 100 / float64(u.Limits.Files); files > percent {
+			percent = files
+		}
+	}
+	return percent
+}
+
+func validateSubject(scope limits.Scope, subjectID string) error {
+	switch scope {
+	case limits.ScopeTenant:
+		if tenantID, err := limits.TenantFrom(subjectID, ""); err != nil || tenantID == "" {
+			return ErrInvalidSubject
+		}
+	case limits.ScopeUser:
+		if _, err := uuid.Parse(subjectID); err != nil {
+			return ErrInvalidSubject
+		}
+	default:
+		return ErrInvalidSubject
+	}
+	return nil
+}
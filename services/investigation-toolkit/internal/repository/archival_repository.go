@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/database"
+	"investigation-toolkit/internal/models"
+)
+
+var (
+	// ErrCaseNotClosed is returned when archiving a case that is not closed
+	ErrCaseNotClosed = errors.New("only closed investigations can be archived")
+	// ErrCaseNotArchived is returned when re-opening a case that has no
+	// archive to re-open
+	ErrCaseNotArchived = errors.New("investigation is not archived")
+)
+
+// ArchivalRepository handles moving closed cases to the archive and
+// re-opening them
+type ArchivalRepository struct {
+	*database.Repository
+}
+
+// NewArchivalRepository creates a new archival repository
+func NewArchivalRepository(db *database.Database, logger *zap.Logger) *ArchivalRepository {
+	return &ArchivalRepository{
+		Repository: database.NewRepository(db, logger),
+	}
+}
+
+const caseArchiveColumns = `
+	id, investigation_id, archived_by, archive_reason, closed_at, evidence_count,
+	evidence_manifest, manifest_sha256, archived_at, reopened_at, reopened_by, reopen_reason`
+
+const archivalInvestigationColumns = `
+	id, title, description, case_type, priority, status, assigned_to,
+	created_by, external_case_id, tags, metadata, created_at, updated_at,
+	due_date, closed_at, archived_at, region`
+
+// GetInvestigation retrieves a case
+func (r *ArchivalRepository) GetInvestigation(ctx context.Context, id uuid.UUID) (*models.Investigation, error) {
+	var investigation models.Investigation
+
+	query := `SELECT ` + archivalInvestigationColumns + ` FROM investigations WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &investigation, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCaseNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get investigation")
+	}
+
+	return &investigation, nil
+}
+
+// ListArchivable retrieves cases closed before the cutoff, longest closed first
+func (r *ArchivalRepository) ListArchivable(ctx context.Context, closedBefore time.Time, limit int) ([]models.Investigation, error) {
+	var investigations []models.Investigation
+
+	query := `
+		SELECT ` + archivalInvestigationColumns + `
+		FROM investigations
+		WHERE status = 'closed' AND closed_at < $1
+		ORDER BY closed_at
+		LIMIT $2`
+
+	if err := r.DB().SelectContext(ctx, &investigations, query, closedBefore, limit); err != nil {
+		return nil, errors.Wrap(err, "failed to list archivable investigations")
+	}
+
+	return investigations, nil
+}
+
+// ListCaseEvidence retrieves the storage references and status of every
+// evidence item in a case
+func (r *ArchivalRepository) ListCaseEvidence(ctx context.Context, investigationID uuid.UUID) ([]models.Evidence, error) {
+	var evidence []models.Evidence
+
+	query := `
+		SELECT id, investigation_id, name, description, evidence_type, file_path, file_size, file_hash,
+			   mime_type, collected_by, collected_at, tags, status, created_at, updated_at
+		FROM evidence
+		WHERE investigation_id = $1
+		ORDER BY collected_at`
+
+	if err := r.DB().SelectContext(ctx, &evidence, query, investigationID); err != nil {
+		return nil, errors.Wrap(err, "failed to list investigation evidence")
+	}
+
+	return evidence, nil
+}
+
+// Archive moves a closed case and the listed evidence to the archive and
+// records the archive. The case must still be closed.
+func (r *ArchivalRepository) Archive(ctx context.Context, archive *models.CaseArchive, evidenceIDs []uuid.UUID) error {
+	return r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE investigations
+			SET status = 'archived', archived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND status = 'closed'`,
+			archive.InvestigationID)
+		if err != nil {
+			return errors.Wrap(err, "failed to archive investigation")
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "failed to archive investigation")
+		}
+		if rows == 0 {
+			return ErrCaseNotClosed
+		}
+
+		if len(evidenceIDs) > 0 {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE evidence SET status = 'archived', updated_at = CURRENT_TIMESTAMP
+				WHERE investigation_id = $1 AND id = ANY($2)`,
+				archive.InvestigationID, pq.Array(evidenceIDs))
+			if err != nil {
+				return errors.Wrap(err, "failed to archive investigation evidence")
+			}
+		}
+
+		err = tx.GetContext(ctx, archive, `
+			INSERT INTO case_archives (
+				investigation_id, archived_by, archive_reason, closed_at, evidence_count,
+				evidence_manifest, manifest_sha256
+			) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING `+caseArchiveColumns,
+			archive.InvestigationID, archive.ArchivedBy, archive.ArchiveReason, archive.ClosedAt,
+			archive.EvidenceCount, archive.EvidenceManifest, archive.ManifestSHA256)
+		if err != nil {
+			return errors.Wrap(err, "failed to record case archive")
+		}
+
+		return nil
+	})
+}
+
+// ActiveArchive retrieves the archive of a case that has not been re-opened
+func (r *ArchivalRepository) ActiveArchive(ctx context.Context, investigationID uuid.UUID) (*models.CaseArchive, error) {
+	var archive models.CaseArchive
+
+	query := `SELECT ` + caseArchiveColumns + ` FROM case_archives WHERE investigation_id = $1 AND reopened_at IS NULL`
+
+	if err := r.DB().GetContext(ctx, &archive, query, investigationID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCaseNotArchived
+		}
+		return nil, errors.Wrap(err, "failed to get case archive")
+	}
+
+	return &archive, nil
+}
+
+// Reopen returns an archived case to work, restores its evidence to the
+// statuses it had when it was archived and records who re-opened it
+func (r *ArchivalRepository) Reopen(ctx context.Context, archive *models.CaseArchive, evidenceStatuses map[uuid.UUID]models.EvidenceStatus) error {
+	return r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE case_archives
+			SET reopened_at = CURRENT_TIMESTAMP, reopened_by = $2, reopen_reason = $3
+			WHERE id = $1 AND reopened_at IS NULL`,
+			archive.ID, archive.ReopenedBy, archive.ReopenReason)
+		if err != nil {
+			return errors.Wrap(err, "failed to record case re-open")
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "failed to record case re-open")
+		}
+		if rows == 0 {
+			return ErrCaseNotArchived
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE investigations
+			SET status = 'in_progress', closed_at = NULL, archived_at = NULL, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1`,
+			archive.InvestigationID)
+		if err != nil {
+			return errors.Wrap(err, "failed to re-open investigation")
+		}
+
+		for evidenceID, status := range evidenceStatuses {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE evidence SET status = $1, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $2 AND investigation_id = $3`,
+				status, evidenceID, archive.InvestigationID)
+			if err != nil {
+				return errors.Wrap(err, "failed to restore investigation evidence")
+			}
+		}
+
+		return tx.GetContext(ctx, archive,
+			`SELECT `+caseArchiveColumns+` FROM case_archives WHERE id = $1`, archive.ID)
+	})
+}
+
+// ListArchives retrieves every time a case was archived, most recent first
+func (r *ArchivalRepository) ListArchives(ctx context.Context, investigationID uuid.UUID) ([]models.CaseArchive, error) {
+	var archives []models.CaseArchive
+
+	query := `
+		SELECT ` + caseArchiveColumns + `
+		FROM case_archives
+		WHERE investigation_id = $1
+		ORDER BY archived_at DESC`
+
+	if err := r.DB().SelectContext(ctx, &archives, query, investigationID); err != nil {
+		return nil, errors.Wrap(err, "failed to list case archives")
+	}
+
+	return archives, nil
+}
+
+// IsArchived reports whether a case has an archive that has not been re-opened
+func (r *ArchivalRepository) IsArchived(ctx context.Context, investigationID uuid.UUID) (bool, error) {
+	var archived bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM case_archives WHERE investigation_id = $1 AND reopened_at IS NULL)`
+
+	if err := r.DB().GetContext(ctx, &archived, query, investigationID); err != nil {
+		return false, errors.Wrap(err, "failed to check case archive")
+	}
+
+	return archived, nil
+}
+
+// GetEvidenceCase returns the case an evidence item belongs to
+func (r *ArchivalRepository) GetEvidenceCase(ctx context.Context, evidenceID uuid.UUID) (uuid.UUID, error) {
+	return r.caseOf(ctx, `SELECT investigation_id FROM evidence WHERE id = $1`, evidenceID)
+}
+
+// GetSARFilingCase returns the case a SAR filing was raised from
+func (r *ArchivalRepository) GetSARFilingCase(ctx context.Context, filingID uuid.UUID) (uuid.UUID, error) {
+	return r.caseOf(ctx, `SELECT investigation_id FROM sar_filings WHERE id = $1`, filingID)
+}
+
+func (r *ArchivalRepository) caseOf(ctx context.Context, query string, id uuid.UUID) (uuid.UUID, error) {
+	var investigationID uuid.UUID
+
+	if err := r.DB().GetContext(ctx, &investigationID, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, ErrCaseNotFound
+		}
+		return uuid.Nil, errors.Wrap(err, "failed to resolve investigation")
+	}
+
+	return investigationID, nil
+}
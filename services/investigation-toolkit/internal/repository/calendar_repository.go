@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/database"
+	"investigation-toolkit/internal/models"
+)
+
+// CalendarRepository handles case calendar, reminder and feed token database operations
+type CalendarRepository struct {
+	*database.Repository
+}
+
+// NewCalendarRepository creates a new calendar repository
+func NewCalendarRepository(db *database.Database, logger *zap.Logger) *CalendarRepository {
+	return &CalendarRepository{
+		Repository: database.NewRepository(db, logger),
+	}
+}
+
+const calendarEventColumns = `
+	id, investigation_id, event_type, title, description, location, starts_at, ends_at,
+	all_day, organizer_id, attendees, reminder_minutes, status, sequence,
+	cancelled_at, cancelled_by, metadata, created_at, updated_at`
+
+// Create creates a calendar event together with its reminders
+func (r *CalendarRepository) Create(ctx context.Context, event *models.CalendarEvent, reminders []models.CalendarReminder) error {
+	query := `
+		INSERT INTO calendar_events (
+			id, investigation_id, event_type, title, description, location, starts_at, ends_at,
+			all_day, organizer_id, attendees, reminder_minutes, status, sequence, metadata,
+			created_at, updated_at
+		) VALUES (
+			:id, :investigation_id, :event_type, :title, :description, :location, :starts_at, :ends_at,
+			:all_day, :organizer_id, :attendees, :reminder_minutes, :status, :sequence, :metadata,
+			:created_at, :updated_at
+		)`
+
+	return r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, query, event); err != nil {
+			return errors.Wrap(err, "failed to create calendar event")
+		}
+		return insertReminders(ctx, tx, reminders)
+	})
+}
+
+// GetByID retrieves a calendar event by ID
+func (r *CalendarRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.CalendarEvent, error) {
+	var event models.CalendarEvent
+
+	query := `SELECT ` + calendarEventColumns + ` FROM calendar_events WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &event, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("calendar event not found")
+		}
+		return nil, errors.Wrap(err, "failed to get calendar event")
+	}
+
+	return &event, nil
+}
+
+// GetByInvestigationID retrieves all calendar events for an investigation
+func (r *CalendarRepository) GetByInvestigationID(ctx context.Context, investigationID uuid.UUID) ([]models.CalendarEvent, error) {
+	var events []models.CalendarEvent
+
+	query := `SELECT ` + calendarEventColumns + `
+		FROM calendar_events
+		WHERE investigation_id = $1
+		ORDER BY starts_at`
+
+	if err := r.DB().SelectContext(ctx, &events, query, investigationID); err != nil {
+		return nil, errors.Wrap(err, "failed to get calendar events")
+	}
+
+	return events, nil
+}
+
+// GetForUser retrieves events a user organizes or attends that overlap the window
+func (r *CalendarRepository) GetForUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.CalendarEvent, error) {
+	var events []models.CalendarEvent
+
+	query := `SELECT ` + calendarEventColumns + `
+		FROM calendar_events
+		WHERE (organizer_id = $1 OR $1 = ANY(attendees))
+		  AND ends_at >= $2 AND starts_at < $3
+		ORDER BY starts_at`
+
+	if err := r.DB().SelectContext(ctx, &events, query, userID, from, to); err != nil {
+		return nil, errors.Wrap(err, "failed to get user calendar events")
+	}
+
+	return events, nil
+}
+
+// GetOverlapping retrieves scheduled events involving any of the users that
+// touch the window. Callers refine the result with calendar.FindConflicts.
+func (r *CalendarRepository) GetOverlapping(ctx context.Context, userIDs []uuid.UUID, start, end time.Time) ([]models.CalendarEvent, error) {
+	var events []models.CalendarEvent
+
+	users := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		users[i] = id.String()
+	}
+
+	query := `SELECT ` + calendarEventColumns + `
+		FROM calendar_events
+		WHERE status = $1
+		  AND (organizer_id = ANY($2::uuid[]) OR attendees && $2::uuid[])
+		  AND starts_at <= $4 AND ends_at >= $3
+		ORDER BY starts_at`
+
+	if err := r.DB().SelectContext(ctx, &events, query,
+		models.CalendarEventStatusScheduled, pq.Array(users), start, end); err != nil {
+		return nil, errors.Wrap(err, "failed to get overlapping calendar events")
+	}
+
+	return events, nil
+}
+
+// Update saves an event, bumps its iCal sequence and replaces its pending reminders
+func (r *CalendarRepository) Update(ctx context.Context, event *models.CalendarEvent, reminders []models.CalendarReminder) error {
+	query := `
+		UPDATE calendar_events SET
+			title = :title, description = :description, location = :location,
+			starts_at = :starts_at, ends_at = :ends_at, all_day = :all_day,
+			attendees = :attendees, reminder_minutes = :reminder_minutes,
+			status = :status, sequence = sequence + 1, metadata = :metadata,
+			cancelled_at = :cancelled_at, cancelled_by = :cancelled_by,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = :id AND sequence = :sequence`
+
+	err := r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.NamedExecContext(ctx, query, event)
+		if err != nil {
+			return errors.Wrap(err, "failed to update calendar event")
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "failed to get rows affected")
+		}
+		if rowsAffected == 0 {
+			return errors.New("calendar event was modified concurrently")
+		}
+
+		// Keep reminders already sent for the new schedule so they are not repeated;
+		// everything else is rebuilt from the updated event
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM calendar_reminders cr
+			USING calendar_events ce
+			WHERE cr.event_id = ce.id AND ce.id = $1
+			  AND NOT (cr.status = $2 AND cr.remind_at = ce.starts_at - cr.minutes_before * INTERVAL '1 minute')`,
+			event.ID, models.CalendarReminderStatusSent); err != nil {
+			return errors.Wrap(err, "failed to replace calendar reminders")
+		}
+
+		return insertReminders(ctx, tx, reminders)
+	})
+	if err != nil {
+		return err
+	}
+
+	event.Sequence++
+	return nil
+}
+
+// ClaimDueReminders marks due pending reminders as sent and returns them.
+// Reminders that fail to deliver are handed back with ReleaseReminder.
+func (r *CalendarRepository) ClaimDueReminders(ctx context.Context, limit int) ([]models.CalendarReminder, error) {
+	var reminders []models.CalendarReminder
+
+	query := `
+		UPDATE calendar_reminders SET status = $1, sent_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id FROM calendar_reminders
+			WHERE status = $2 AND remind_at <= CURRENT_TIMESTAMP
+			ORDER BY remind_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_id, user_id, minutes_before, remind_at, status, sent_at, created_at`
+
+	if err := r.DB().SelectContext(ctx, &reminders, query,
+		models.CalendarReminderStatusSent, models.CalendarReminderStatusPending, limit); err != nil {
+		return nil, errors.Wrap(err, "failed to claim calendar reminders")
+	}
+
+	return reminders, nil
+}
+
+// ReleaseReminder returns a claimed reminder to pending so it is retried
+func (r *CalendarRepository) ReleaseReminder(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE calendar_reminders SET status = $1, sent_at = NULL WHERE id = $2`
+
+	if _, err := r.DB().ExecContext(ctx, query, models.CalendarReminderStatusPending, id); err != nil {
+		return errors.Wrap(err, "failed to release calendar reminder")
+	}
+
+	return nil
+}
+
+// GetReminders retrieves all reminders for an event
+func (r *CalendarRepository) GetReminders(ctx context.Context, eventID uuid.UUID) ([]models.CalendarReminder, error) {
+	var reminders []models.CalendarReminder
+
+	query := `
+		SELECT id, event_id, user_id, minutes_before, remind_at, status, sent_at, created_at
+		FROM calendar_reminders
+		WHERE event_id = $1
+		ORDER BY remind_at, user_id`
+
+	if err := r.DB().SelectContext(ctx, &reminders, query, eventID); err != nil {
+		return nil, errors.Wrap(err, "failed to get calendar reminders")
+	}
+
+	return reminders, nil
+}
+
+// SetFeedToken stores the hash of a user's iCal feed token, replacing any previous token
+func (r *CalendarRepository) SetFeedToken(ctx context.Context, userID uuid.UUID, tokenHash string) error {
+	query := `
+		INSERT INTO calendar_feed_tokens (user_id, token_hash, created_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE SET
+			token_hash = EXCLUDED.token_hash,
+			created_at = EXCLUDED.created_at,
+			last_accessed_at = NULL`
+
+	if _, err := r.DB().ExecContext(ctx, query, userID, tokenHash); err != nil {
+		return errors.Wrap(err, "failed to set calendar feed token")
+	}
+
+	return nil
+}
+
+// GetUserByFeedToken resolves a feed token hash to its user and records the access
+func (r *CalendarRepository) GetUserByFeedToken(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	var userID uuid.UUID
+
+	query := `
+		UPDATE calendar_feed_tokens SET last_accessed_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1
+		RETURNING user_id`
+
+	if err := r.DB().GetContext(ctx, &userID, query, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, errors.New("calendar feed not found")
+		}
+		return uuid.Nil, errors.Wrap(err, "failed to get calendar feed token")
+	}
+
+	return userID, nil
+}
+
+// RevokeFeedToken removes a user's iCal feed token
+func (r *CalendarRepository) RevokeFeedToken(ctx context.Context, userID uuid.UUID) error {
+	if _, err := r.DB().ExecContext(ctx, `DELETE FROM calendar_feed_tokens WHERE user_id = $1`, userID); err != nil {
+		return errors.Wrap(err, "failed to revoke calendar feed token")
+	}
+
+	return nil
+}
+
+func insertReminders(ctx context.Context, tx *sqlx.Tx, reminders []models.CalendarReminder) error {
+	query := `
+		INSERT INTO calendar_reminders (
+			id, event_id, user_id, minutes_before, remind_at, status, created_at
+		) VALUES (
+			:id, :event_id, :user_id, :minutes_before, :remind_at, :status, :created_at
+		)
+		ON CONFLICT (event_id, user_id, minutes_before) DO NOTHING`
+
+	for i := range reminders {
+		if _, err := tx.NamedExecContext(ctx, query, &reminders[i]); err != nil {
+			return errors.Wrap(err, "failed to create calendar reminder")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/database"
+	"investigation-toolkit/internal/models"
+)
+
+var (
+	// ErrParticipantExists is returned when adding a user who already participates in a case
+	ErrParticipantExists = errors.New("user is already a participant in this investigation")
+	// ErrParticipantNotFound is returned when removing a user who does not participate in a case
+	ErrParticipantNotFound = errors.New("participant not found")
+	// ErrEvidenceNotFound is returned when classifying evidence that does not exist
+	ErrEvidenceNotFound = errors.New("evidence not found")
+	// ErrClassificationChanged is returned when evidence was reclassified
+	// after the classification a change was based on was read
+	ErrClassificationChanged = errors.New("evidence was reclassified concurrently")
+)
+
+// ClassificationRepository handles case participants, evidence
+// classifications and their change history
+type ClassificationRepository struct {
+	*database.Repository
+}
+
+// NewClassificationRepository creates a new classification repository
+func NewClassificationRepository(db *database.Database, logger *zap.Logger) *ClassificationRepository {
+	return &ClassificationRepository{
+		Repository: database.NewRepository(db, logger),
+	}
+}
+
+const participantColumns = `
+	id, investigation_id, user_id, role, permissions, assigned_by, assigned_at,
+	removed_at, removed_by, is_active, notes, clearance, created_at, updated_at`
+
+// AddParticipant adds a user to a case
+func (r *ClassificationRepository) AddParticipant(ctx context.Context, participant *models.Collaboration) error {
+	query := `
+		INSERT INTO collaboration (
+			investigation_id, user_id, role, permissions, assigned_by, notes, clearance
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + participantColumns
+
+	err := r.DB().GetContext(ctx, participant, query,
+		participant.InvestigationID, participant.UserID, participant.Role, models.JSONB{},
+		participant.AssignedBy, participant.Notes, participant.Clearance)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			switch pqErr.Code {
+			case "23505":
+				return ErrParticipantExists
+			case "23503":
+				return ErrCaseNotFound
+			}
+		}
+		return errors.Wrap(err, "failed to add participant")
+	}
+
+	return nil
+}
+
+// RemoveParticipant ends a user's participation in a case
+func (r *ClassificationRepository) RemoveParticipant(ctx context.Context, investigationID, userID, removedBy uuid.UUID) error {
+	query := `
+		UPDATE collaboration
+		SET is_active = FALSE, removed_at = $3, removed_by = $4, updated_at = $3
+		WHERE investigation_id = $1 AND user_id = $2 AND is_active`
+
+	result, err := r.DB().ExecContext(ctx, query, investigationID, userID, time.Now(), removedBy)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove participant")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to remove participant")
+	}
+	if rows == 0 {
+		return ErrParticipantNotFound
+	}
+
+	return nil
+}
+
+// ListParticipants retrieves the active participants of a case
+func (r *ClassificationRepository) ListParticipants(ctx context.Context, investigationID uuid.UUID) ([]models.Collaboration, error) {
+	var participants []models.Collaboration
+
+	query := `SELECT ` + participantColumns + `
+		FROM collaboration
+		WHERE investigation_id = $1 AND is_active
+		ORDER BY assigned_at`
+
+	if err := r.DB().SelectContext(ctx, &participants, query, investigationID); err != nil {
+		return nil, errors.Wrap(err, "failed to list participants")
+	}
+
+	return participants, nil
+}
+
+// ActiveParticipant retrieves a user's participation in a case, or nil when
+// they do not participate
+func (r *ClassificationRepository) ActiveParticipant(ctx context.Context, investigationID, userID uuid.UUID) (*models.Collaboration, error) {
+	var participant models.Collaboration
+
+	query := `SELECT ` + participantColumns + `
+		FROM collaboration
+		WHERE investigation_id = $1 AND user_id = $2 AND is_active`
+
+	if err := r.DB().GetContext(ctx, &participant, query, investigationID, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to get participant")
+	}
+
+	return &participant, nil
+}
+
+// CaseExists returns ErrCaseNotFound unless the case exists
+func (r *ClassificationRepository) CaseExists(ctx context.Context, investigationID uuid.UUID) error {
+	var exists bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM investigations WHERE id = $1)`
+
+	if err := r.DB().GetContext(ctx, &exists, query, investigationID); err != nil {
+		return errors.Wrap(err, "failed to get investigation")
+	}
+	if !exists {
+		return ErrCaseNotFound
+	}
+
+	return nil
+}
+
+// GetEvidenceClassification returns the case an evidence item belongs to and its classification
+func (r *ClassificationRepository) GetEvidenceClassification(ctx context.Context, evidenceID uuid.UUID) (uuid.UUID, models.EvidenceClassification, error) {
+	var row struct {
+		InvestigationID uuid.UUID                     `db:"investigation_id"`
+		Classification  models.EvidenceClassification `db:"classification"`
+	}
+
+	query := `SELECT investigation_id, classification FROM evidence WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &row, query, evidenceID); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, "", ErrEvidenceNotFound
+		}
+		return uuid.Nil, "", errors.Wrap(err, "failed to get evidence classification")
+	}
+
+	return row.InvestigationID, row.Classification, nil
+}
+
+// Classify changes an evidence item's classification and records the change.
+// The evidence must still have the classification the change is from.
+func (r *ClassificationRepository) Classify(ctx context.Context, change *models.EvidenceClassificationChange) error {
+	return r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE evidence SET classification = $1, updated_at = NOW()
+			WHERE id = $2 AND classification = $3`,
+			change.ToClassification, change.EvidenceID, change.FromClassification)
+		if err != nil {
+			return errors.Wrap(err, "failed to classify evidence")
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "failed to classify evidence")
+		}
+		if rows == 0 {
+			return ErrClassificationChanged
+		}
+
+		err = tx.GetContext(ctx, change, `
+			INSERT INTO evidence_classification_changes (
+				evidence_id, from_classification, to_classification, reason, changed_by
+			) VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, evidence_id, from_classification, to_classification, reason, changed_by, created_at`,
+			change.EvidenceID, change.FromClassification, change.ToClassification, change.Reason, change.ChangedBy)
+		if err != nil {
+			return errors.Wrap(err, "failed to record classification change")
+		}
+
+		return nil
+	})
+}
+
+// ListClassificationChanges retrieves an evidence item's reclassifications, oldest first
+func (r *ClassificationRepository) ListClassificationChanges(ctx context.Context, evidenceID uuid.UUID) ([]models.EvidenceClassificationChange, error) {
+	var changes []models.EvidenceClassificationChange
+
+	query := `
+		SELECT id, evidence_id, from_classification, to_classification, reason, changed_by, created_at
+		FROM evidence_classification_changes
+		WHERE evidence_id = $1
+		ORDER BY created_at`
+
+	if err := r.DB().SelectContext(ctx, &changes, query, evidenceID); err != nil {
+		return nil, errors.Wrap(err, "failed to list classification changes")
+	}
+
+	return changes, nil
+}
+
+// ListCaseEvidenceClassifications retrieves the ID, name and classification
+// of every evidence item in a case
+func (r *ClassificationRepository) ListCaseEvidenceClassifications(ctx context.Context, investigationID uuid.UUID) ([]models.Evidence, error) {
+	var evidence []models.Evidence
+
+	query := `
+		SELECT id, investigation_id, name, classification
+		FROM evidence
+		WHERE investigation_id = $1
+		ORDER BY collected_at`
+
+	if err := r.DB().SelectContext(ctx, &evidence, query, investigationID); err != nil {
+		return nil, errors.Wrap(err, "failed to list investigation evidence")
+	}
+
+	return evidence, nil
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
@@ -46,9 +47,13 @@ func (r *EvidenceRepository) Create(ctx context.Context, investigationID uuid.UU
 		AuthenticationMethod: req.AuthenticationMethod,
 		RetentionDate:        req.RetentionDate,
 		Status:               models.EvidenceStatusActive,
+		Classification:       models.ClassificationInternal,
 		CreatedAt:            time.Now(),
 		UpdatedAt:            time.Now(),
 	}
+	if req.Classification != nil {
+		evidence.Classification = *req.Classification
+	}
 
 	// Initialize chain of custody
 	custodyEntry := map[string]interface{}{
@@ -66,11 +71,11 @@ func (r *EvidenceRepository) Create(ctx context.Context, investigationID uuid.UU
 		INSERT INTO evidence (
 			id, investigation_id, name, description, evidence_type, source, collection_method,
 			collected_by, collected_at, chain_of_custody, metadata, tags, is_authenticated,
-			authentication_method, retention_date, status, created_at, updated_at
+			authentication_method, retention_date, status, classification, created_at, updated_at
 		) VALUES (
 			:id, :investigation_id, :name, :description, :evidence_type, :source, :collection_method,
 			:collected_by, :collected_at, :chain_of_custody, :metadata, :tags, :is_authenticated,
-			:authentication_method, :retention_date, :status, :created_at, :updated_at
+			:authentication_method, :retention_date, :status, :classification, :created_at, :updated_at
 		) RETURNING id, created_at, updated_at`
 
 	rows, err := r.DB().NamedQueryContext(ctx, query, evidence)
@@ -96,7 +101,7 @@ func (r *EvidenceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 		SELECT id, investigation_id, name, description, evidence_type, source, collection_method,
 			   file_path, file_size, file_hash, mime_type, collected_by, collected_at,
 			   chain_of_custody, metadata, tags, is_authenticated, authentication_method,
-			   authentication_date, authentication_by, retention_date, status, created_at, updated_at
+			   authentication_date, authentication_by, retention_date, status, classification, created_at, updated_at
 		FROM evidence 
 		WHERE id = $1`
 
@@ -159,6 +164,11 @@ func (r *EvidenceRepository) GetByInvestigationID(ctx context.Context, investiga
 			whereConditions = append(whereConditions, fmt.Sprintf("(name ILIKE $%d OR description ILIKE $%d)", argIndex, argIndex))
 			args = append(args, "%"+*filter.Search+"%")
 		}
+		if len(filter.Classifications) > 0 {
+			argIndex++
+			whereConditions = append(whereConditions, fmt.Sprintf("classification = ANY($%d)", argIndex))
+			args = append(args, pq.Array(filter.Classifications))
+		}
 	}
 
 	whereClause := strings.Join(whereConditions, " AND ")
@@ -176,7 +186,7 @@ func (r *EvidenceRepository) GetByInvestigationID(ctx context.Context, investiga
 		SELECT id, investigation_id, name, description, evidence_type, source, collection_method,
 			   file_path, file_size, file_hash, mime_type, collected_by, collected_at,
 			   chain_of_custody, metadata, tags, is_authenticated, authentication_method,
-			   authentication_date, authentication_by, retention_date, status, created_at, updated_at
+			   authentication_date, authentication_by, retention_date, status, classification, created_at, updated_at
 		FROM evidence 
 		WHERE %s
 		ORDER BY collected_at DESC
@@ -337,7 +347,7 @@ func (r *EvidenceRepository) GetByFileHash(ctx context.Context, fileHash string)
 		SELECT id, investigation_id, name, description, evidence_type, source, collection_method,
 			   file_path, file_size, file_hash, mime_type, collected_by, collected_at,
 			   chain_of_custody, metadata, tags, is_authenticated, authentication_method,
-			   authentication_date, authentication_by, retention_date, status, created_at, updated_at
+			   authentication_date, authentication_by, retention_date, status, classification, created_at, updated_at
 		FROM evidence 
 		WHERE file_hash = $1 AND status != 'archived'`
 
@@ -448,7 +458,7 @@ func (r *EvidenceRepository) GetExpiredEvidence(ctx context.Context, paginate *d
 		SELECT id, investigation_id, name, description, evidence_type, source, collection_method,
 			   file_path, file_size, file_hash, mime_type, collected_by, collected_at,
 			   chain_of_custody, metadata, tags, is_authenticated, authentication_method,
-			   authentication_date, authentication_by, retention_date, status, created_at, updated_at
+			   authentication_date, authentication_by, retention_date, status, classification, created_at, updated_at
 		FROM evidence 
 		WHERE %s
 		ORDER BY retention_date ASC
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/database"
+	"investigation-toolkit/internal/models"
+)
+
+// EvidenceRequestRepository handles external evidence request database operations
+type EvidenceRequestRepository struct {
+	*database.Repository
+}
+
+// NewEvidenceRequestRepository creates a new evidence request repository
+func NewEvidenceRequestRepository(db *database.Database, logger *zap.Logger) *EvidenceRequestRepository {
+	return &EvidenceRequestRepository{
+		Repository: database.NewRepository(db, logger),
+	}
+}
+
+const evidenceRequestColumns = `
+	id, investigation_id, token_hash, recipient_name, recipient_email, recipient_type,
+	instructions, allowed_file_types, max_files, max_file_size, files_received, status,
+	expires_at, requested_by, tenant_id, last_submission_at, revoked_at, revoked_by, metadata,
+	created_at, updated_at`
+
+// Create creates a new evidence request
+func (r *EvidenceRequestRepository) Create(ctx context.Context, request *models.EvidenceRequest) error {
+	query := `
+		INSERT INTO evidence_requests (
+			id, investigation_id, token_hash, recipient_name, recipient_email, recipient_type,
+			instructions, allowed_file_types, max_files, max_file_size, files_received, status,
+			expires_at, requested_by, tenant_id, metadata, created_at, updated_at
+		) VALUES (
+			:id, :investigation_id, :token_hash, :recipient_name, :recipient_email, :recipient_type,
+			:instructions, :allowed_file_types, :max_files, :max_file_size, :files_received, :status,
+			:expires_at, :requested_by, :tenant_id, :metadata, :created_at, :updated_at
+		)`
+
+	if _, err := r.DB().NamedExecContext(ctx, query, request); err != nil {
+		return errors.Wrap(err, "failed to create evidence request")
+	}
+
+	return nil
+}
+
+// GetByID retrieves an evidence request by ID
+func (r *EvidenceRequestRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.EvidenceRequest, error) {
+	var request models.EvidenceRequest
+
+	query := `SELECT ` + evidenceRequestColumns + ` FROM evidence_requests WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &request, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("evidence request not found")
+		}
+		return nil, errors.Wrap(err, "failed to get evidence request")
+	}
+
+	return &request, nil
+}
+
+// GetByTokenHash retrieves an evidence request by the hash of its upload token
+func (r *EvidenceRequestRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.EvidenceRequest, error) {
+	var request models.EvidenceRequest
+
+	query := `SELECT ` + evidenceRequestColumns + ` FROM evidence_requests WHERE token_hash = $1`
+
+	if err := r.DB().GetContext(ctx, &request, query, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("evidence request not found")
+		}
+		return nil, errors.Wrap(err, "failed to get evidence request")
+	}
+
+	return &request, nil
+}
+
+// GetByInvestigationID retrieves all evidence requests for an investigation
+func (r *EvidenceRequestRepository) GetByInvestigationID(ctx context.Context, investigationID uuid.UUID) ([]models.EvidenceRequest, error) {
+	var requests []models.EvidenceRequest
+
+	query := `SELECT ` + evidenceRequestColumns + `
+		FROM evidence_requests
+		WHERE investigation_id = $1
+		ORDER BY created_at DESC`
+
+	if err := r.DB().SelectContext(ctx, &requests, query, investigationID); err != nil {
+		return nil, errors.Wrap(err, "failed to get evidence requests")
+	}
+
+	return requests, nil
+}
+
+// Revoke revokes an open evidence request so its link stops working
+func (r *EvidenceRequestRepository) Revoke(ctx context.Context, id uuid.UUID, revokedBy uuid.UUID) error {
+	query := `
+		UPDATE evidence_requests
+		SET status = $1, revoked_at = CURRENT_TIMESTAMP, revoked_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status IN ($4, $5)`
+
+	result, err := r.DB().ExecContext(ctx, query,
+		models.EvidenceRequestStatusRevoked, revokedBy, id,
+		models.EvidenceRequestStatusPending, models.EvidenceRequestStatusPartiallyReceived)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke evidence request")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("evidence request not found or already closed")
+	}
+
+	return nil
+}
+
+// ExpireOverdue marks open requests past their expiry as expired
+func (r *EvidenceRequestRepository) ExpireOverdue(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE evidence_requests
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE expires_at < CURRENT_TIMESTAMP AND status IN ($2, $3)`
+
+	result, err := r.DB().ExecContext(ctx, query,
+		models.EvidenceRequestStatusExpired,
+		models.EvidenceRequestStatusPending, models.EvidenceRequestStatusPartiallyReceived)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to expire evidence requests")
+	}
+
+	return result.RowsAffected()
+}
+
+// ReserveSlot atomically claims an upload slot on an open, unexpired request.
+// It fails when the request is closed, expired or has no files remaining.
+func (r *EvidenceRequestRepository) ReserveSlot(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE evidence_requests
+		SET files_received = files_received + 1,
+			status = CASE WHEN files_received + 1 >= max_files THEN $1 ELSE $2 END,
+			last_submission_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		  AND status IN ($4, $2)
+		  AND expires_at > CURRENT_TIMESTAMP
+		  AND files_received < max_files`
+
+	result, err := r.DB().ExecContext(ctx, query,
+		models.EvidenceRequestStatusFulfilled,
+		models.EvidenceRequestStatusPartiallyReceived,
+		id,
+		models.EvidenceRequestStatusPending)
+	if err != nil {
+		return errors.Wrap(err, "failed to reserve evidence request slot")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("evidence request is closed or has no uploads remaining")
+	}
+
+	return nil
+}
+
+// ReleaseSlot returns a reserved upload slot when the file is not accepted
+func (r *EvidenceRequestRepository) ReleaseSlot(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE evidence_requests
+		SET files_received = GREATEST(files_received - 1, 0),
+			status = CASE
+				WHEN status = $1 THEN status
+				WHEN files_received - 1 <= 0 THEN $2
+				ELSE $3
+			END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4`
+
+	_, err := r.DB().ExecContext(ctx, query,
+		models.EvidenceRequestStatusRevoked,
+		models.EvidenceRequestStatusPending,
+		models.EvidenceRequestStatusPartiallyReceived,
+		id)
+	if err != nil {
+		return errors.Wrap(err, "failed to release evidence request slot")
+	}
+
+	return nil
+}
+
+// CreateSubmission records a file received through an evidence request link
+func (r *EvidenceRequestRepository) CreateSubmission(ctx context.Context, submission *models.EvidenceRequestSubmission) error {
+	query := `
+		INSERT INTO evidence_request_submissions (
+			id, request_id, evidence_id, file_name, file_size, file_hash, mime_type,
+			scan_status, scan_result, status, submitter_ip, submitter_note, submitted_at
+		) VALUES (
+			:id, :request_id, :evidence_id, :file_name, :file_size, :file_hash, :mime_type,
+			:scan_status, :scan_result, :status, :submitter_ip, :submitter_note, :submitted_at
+		)`
+
+	if _, err := r.DB().NamedExecContext(ctx, query, submission); err != nil {
+		return errors.Wrap(err, "failed to create evidence request submission")
+	}
+
+	return nil
+}
+
+// GetSubmissions retrieves all submissions for an evidence request
+func (r *EvidenceRequestRepository) GetSubmissions(ctx context.Context, requestID uuid.UUID) ([]models.EvidenceRequestSubmission, error) {
+	var submissions []models.EvidenceRequestSubmission
+
+	query := `
+		SELECT id, request_id, evidence_id, file_name, file_size, file_hash, mime_type,
+			   scan_status, scan_result, status, submitter_ip, submitter_note, submitted_at
+		FROM evidence_request_submissions
+		WHERE request_id = $1
+		ORDER BY submitted_at`
+
+	if err := r.DB().SelectContext(ctx, &submissions, query, requestID); err != nil {
+		return nil, errors.Wrap(err, "failed to get evidence request submissions")
+	}
+
+	return submissions, nil
+}
@@ -43,6 +43,7 @@ func (r *InvestigationRepository) Create(ctx context.Context, req *models.Create
 		Tags:           req.Tags,
 		Metadata:       req.Metadata,
 		DueDate:        req.DueDate,
+		Region:         req.Region,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
@@ -50,10 +51,10 @@ func (r *InvestigationRepository) Create(ctx context.Context, req *models.Create
 	query := `
 		INSERT INTO investigations (
 			id, title, description, case_type, priority, status, assigned_to, 
-			created_by, external_case_id, tags, metadata, due_date, created_at, updated_at
+			created_by, external_case_id, tags, metadata, due_date, region, created_at, updated_at
 		) VALUES (
 			:id, :title, :description, :case_type, :priority, :status, :assigned_to,
-			:created_by, :external_case_id, :tags, :metadata, :due_date, :created_at, :updated_at
+			:created_by, :external_case_id, :tags, :metadata, :due_date, :region, :created_at, :updated_at
 		) RETURNING id, created_at, updated_at`
 
 	rows, err := r.DB().NamedQueryContext(ctx, query, investigation)
@@ -78,7 +79,7 @@ func (r *InvestigationRepository) GetByID(ctx context.Context, id uuid.UUID) (*m
 	query := `
 		SELECT id, title, description, case_type, priority, status, assigned_to,
 			   created_by, external_case_id, tags, metadata, created_at, updated_at,
-			   due_date, closed_at, archived_at
+			   due_date, closed_at, archived_at, region
 		FROM investigations 
 		WHERE id = $1`
 
@@ -168,7 +169,7 @@ func (r *InvestigationRepository) Update(ctx context.Context, id uuid.UUID, req
 		WHERE id = $1
 		RETURNING id, title, description, case_type, priority, status, assigned_to,
 				  created_by, external_case_id, tags, metadata, created_at, updated_at,
-				  due_date, closed_at, archived_at`,
+				  due_date, closed_at, archived_at, region`,
 		strings.Join(setParts, ", "))
 
 	var investigation models.Investigation
@@ -270,6 +271,16 @@ func (r *InvestigationRepository) List(ctx context.Context, filter *models.Inves
 			whereConditions = append(whereConditions, fmt.Sprintf("(title ILIKE $%d OR description ILIKE $%d)", argIndex, argIndex))
 			args[fmt.Sprintf("arg%d", argIndex)] = "%" + *filter.Search + "%"
 		}
+		if filter.Region != nil {
+			argIndex++
+			whereConditions = append(whereConditions, fmt.Sprintf("(region = $%d OR region IS NULL)", argIndex))
+			args[fmt.Sprintf("arg%d", argIndex)] = *filter.Region
+		}
+	}
+
+	// Archived cases are left out of default lists
+	if filter == nil || (len(filter.Statuses) == 0 && !filter.IncludeArchived) {
+		whereConditions = append(whereConditions, "status <> 'archived'")
 	}
 
 	whereClause := strings.Join(whereConditions, " AND ")
@@ -286,7 +297,7 @@ func (r *InvestigationRepository) List(ctx context.Context, filter *models.Inves
 	dataQuery := fmt.Sprintf(`
 		SELECT id, title, description, case_type, priority, status, assigned_to,
 			   created_by, external_case_id, tags, metadata, created_at, updated_at,
-			   due_date, closed_at, archived_at
+			   due_date, closed_at, archived_at, region
 		FROM investigations 
 		WHERE %s
 		ORDER BY created_at DESC
@@ -312,7 +323,7 @@ func (r *InvestigationRepository) GetByExternalCaseID(ctx context.Context, exter
 	query := `
 		SELECT id, title, description, case_type, priority, status, assigned_to,
 			   created_by, external_case_id, tags, metadata, created_at, updated_at,
-			   due_date, closed_at, archived_at
+			   due_date, closed_at, archived_at, region
 		FROM investigations 
 		WHERE external_case_id = $1`
 
@@ -341,7 +352,7 @@ func (r *InvestigationRepository) GetAssignedInvestigations(ctx context.Context,
 	dataQuery := `
 		SELECT id, title, description, case_type, priority, status, assigned_to,
 			   created_by, external_case_id, tags, metadata, created_at, updated_at,
-			   due_date, closed_at, archived_at
+			   due_date, closed_at, archived_at, region
 		FROM investigations 
 		WHERE assigned_to = $1 AND status NOT IN ('closed', 'archived')
 		ORDER BY priority DESC, due_date ASC NULLS LAST, created_at DESC
@@ -0,0 +1,420 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/database"
+	"investigation-toolkit/internal/models"
+)
+
+var (
+	// ErrOfflineSyncNotFound is returned when an offline sync session does not exist
+	ErrOfflineSyncNotFound = errors.New("offline sync session not found")
+	// ErrOfflineSyncItemNotFound is returned when an item is not part of an offline sync session
+	ErrOfflineSyncItemNotFound = errors.New("offline sync item not found")
+)
+
+// OfflineSyncRepository handles offline sync sessions, their items and
+// chunks, and the evidence they create
+type OfflineSyncRepository struct {
+	*database.Repository
+}
+
+// NewOfflineSyncRepository creates a new offline sync repository
+func NewOfflineSyncRepository(db *database.Database, logger *zap.Logger) *OfflineSyncRepository {
+	return &OfflineSyncRepository{
+		Repository: database.NewRepository(db, logger),
+	}
+}
+
+const offlineSyncSessionColumns = `
+	id, investigation_id, device_id, manifest_hash, offline_from, offline_until,
+	conflict_strategy, chunk_size, status, tenant_id, created_by, expires_at,
+	completed_at, created_at, updated_at`
+
+const offlineSyncItemColumns = `
+	id, session_id, client_item_id, name, description, evidence_type, file_name,
+	file_size, file_hash, mime_type, collected_at, base_evidence_id, base_file_hash,
+	local_custody, metadata, tags, total_chunks, status, status_reason, evidence_id,
+	conflict_evidence_id, synced_at, created_at`
+
+const offlineSyncEvidenceColumns = `
+	id, investigation_id, name, description, evidence_type, source, collection_method,
+	file_path, file_size, file_hash, mime_type, collected_by, collected_at,
+	chain_of_custody, metadata, tags, is_authenticated, authentication_method,
+	authentication_date, authentication_by, retention_date, status, classification, created_at, updated_at`
+
+// GetInvestigation retrieves a case
+func (r *OfflineSyncRepository) GetInvestigation(ctx context.Context, id uuid.UUID) (*models.Investigation, error) {
+	var investigation models.Investigation
+
+	query := `SELECT ` + archivalInvestigationColumns + ` FROM investigations WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &investigation, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCaseNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get investigation")
+	}
+
+	return &investigation, nil
+}
+
+// GetEvidence retrieves an evidence item
+func (r *OfflineSyncRepository) GetEvidence(ctx context.Context, id uuid.UUID) (*models.Evidence, error) {
+	var evidence models.Evidence
+
+	query := `SELECT ` + offlineSyncEvidenceColumns + ` FROM evidence WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &evidence, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("evidence not found")
+		}
+		return nil, errors.Wrap(err, "failed to get evidence")
+	}
+
+	return &evidence, nil
+}
+
+// FindEvidenceByHash returns the oldest active evidence of a case holding a
+// file with the hash, or nil
+func (r *OfflineSyncRepository) FindEvidenceByHash(ctx context.Context, investigationID uuid.UUID, fileHash string) (*models.Evidence, error) {
+	var evidence models.Evidence
+
+	query := `
+		SELECT ` + offlineSyncEvidenceColumns + `
+		FROM evidence
+		WHERE investigation_id = $1 AND file_hash = $2 AND status = 'active'
+		ORDER BY created_at
+		LIMIT 1`
+
+	if err := r.DB().GetContext(ctx, &evidence, query, investigationID, fileHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to find evidence by hash")
+	}
+
+	return &evidence, nil
+}
+
+// CreateEvidence stores evidence with its file
+func (r *OfflineSyncRepository) CreateEvidence(ctx context.Context, evidence *models.Evidence) error {
+	query := `
+		INSERT INTO evidence (
+			id, investigation_id, name, description, evidence_type, source, collection_method,
+			file_path, file_size, file_hash, mime_type, collected_by, collected_at,
+			chain_of_custody, metadata, tags, is_authenticated, status, classification,
+			created_at, updated_at
+		) VALUES (
+			:id, :investigation_id, :name, :description, :evidence_type, :source, :collection_method,
+			:file_path, :file_size, :file_hash, :mime_type, :collected_by, :collected_at,
+			:chain_of_custody, :metadata, :tags, :is_authenticated, :status, :classification,
+			:created_at, :updated_at
+		)`
+
+	if _, err := r.DB().NamedExecContext(ctx, query, evidence); err != nil {
+		return errors.Wrap(err, "failed to create evidence")
+	}
+
+	return nil
+}
+
+// ReviseEvidenceFile replaces an evidence item's file and appends chain of
+// custody entries in one transaction
+func (r *OfflineSyncRepository) ReviseEvidenceFile(ctx context.Context, evidenceID uuid.UUID, filePath, fileHash, mimeType string, fileSize int64, custody []interface{}) error {
+	return r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := appendCustody(ctx, tx, evidenceID, custody); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE evidence
+			SET file_path = $1, file_hash = $2, mime_type = $3, file_size = $4, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $5`,
+			filePath, fileHash, mimeType, fileSize, evidenceID)
+		if err != nil {
+			return errors.Wrap(err, "failed to update evidence file")
+		}
+
+		return nil
+	})
+}
+
+// AppendCustody appends chain of custody entries to an evidence item
+func (r *OfflineSyncRepository) AppendCustody(ctx context.Context, evidenceID uuid.UUID, custody []interface{}) error {
+	return r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		return appendCustody(ctx, tx, evidenceID, custody)
+	})
+}
+
+func appendCustody(ctx context.Context, tx *sqlx.Tx, evidenceID uuid.UUID, custody []interface{}) error {
+	var chain models.JSONB
+	err := tx.GetContext(ctx, &chain, `SELECT chain_of_custody FROM evidence WHERE id = $1 FOR UPDATE`, evidenceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("evidence not found")
+		}
+		return errors.Wrap(err, "failed to get current chain of custody")
+	}
+	if chain == nil {
+		chain = models.JSONB{}
+	}
+
+	entries, ok := chain["entries"].([]interface{})
+	if !ok {
+		entries = []interface{}{}
+	}
+	chain["entries"] = append(entries, custody...)
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE evidence SET chain_of_custody = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2`,
+		chain, evidenceID)
+	if err != nil {
+		return errors.Wrap(err, "failed to update chain of custody")
+	}
+
+	return nil
+}
+
+// CreateSession stores a session with the items of its manifest
+func (r *OfflineSyncRepository) CreateSession(ctx context.Context, session *models.OfflineSyncSession, items []models.OfflineSyncItem) error {
+	return r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExecContext(ctx, `
+			INSERT INTO offline_sync_sessions (`+offlineSyncSessionColumns+`)
+			VALUES (
+				:id, :investigation_id, :device_id, :manifest_hash, :offline_from, :offline_until,
+				:conflict_strategy, :chunk_size, :status, :tenant_id, :created_by, :expires_at,
+				:completed_at, :created_at, :updated_at
+			)`, session)
+		if err != nil {
+			return errors.Wrap(err, "failed to create offline sync session")
+		}
+
+		for i := range items {
+			_, err := tx.NamedExecContext(ctx, `
+				INSERT INTO offline_sync_items (`+offlineSyncItemColumns+`)
+				VALUES (
+					:id, :session_id, :client_item_id, :name, :description, :evidence_type, :file_name,
+					:file_size, :file_hash, :mime_type, :collected_at, :base_evidence_id, :base_file_hash,
+					:local_custody, :metadata, :tags, :total_chunks, :status, :status_reason, :evidence_id,
+					:conflict_evidence_id, :synced_at, :created_at
+				)`, &items[i])
+			if err != nil {
+				return errors.Wrap(err, "failed to create offline sync item")
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetSession retrieves an offline sync session
+func (r *OfflineSyncRepository) GetSession(ctx context.Context, id uuid.UUID) (*models.OfflineSyncSession, error) {
+	var session models.OfflineSyncSession
+
+	query := `SELECT ` + offlineSyncSessionColumns + ` FROM offline_sync_sessions WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &session, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrOfflineSyncNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get offline sync session")
+	}
+
+	return &session, nil
+}
+
+// FindOpenSession returns the newest open session of a device for a
+// manifest, or nil
+func (r *OfflineSyncRepository) FindOpenSession(ctx context.Context, investigationID uuid.UUID, deviceID, manifestHash string) (*models.OfflineSyncSession, error) {
+	var session models.OfflineSyncSession
+
+	query := `
+		SELECT ` + offlineSyncSessionColumns + `
+		FROM offline_sync_sessions
+		WHERE investigation_id = $1 AND device_id = $2 AND manifest_hash = $3 AND status = 'open'
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	if err := r.DB().GetContext(ctx, &session, query, investigationID, deviceID, manifestHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to find open offline sync session")
+	}
+
+	return &session, nil
+}
+
+// ListSessions lists the offline sync sessions of a case, newest first
+func (r *OfflineSyncRepository) ListSessions(ctx context.Context, investigationID uuid.UUID) ([]models.OfflineSyncSession, error) {
+	sessions := []models.OfflineSyncSession{}
+
+	query := `
+		SELECT ` + offlineSyncSessionColumns + `
+		FROM offline_sync_sessions
+		WHERE investigation_id = $1
+		ORDER BY created_at DESC`
+
+	if err := r.DB().SelectContext(ctx, &sessions, query, investigationID); err != nil {
+		return nil, errors.Wrap(err, "failed to list offline sync sessions")
+	}
+
+	return sessions, nil
+}
+
+// SetSessionStatus moves a session from one status to another and reports
+// whether it was in the from status
+func (r *OfflineSyncRepository) SetSessionStatus(ctx context.Context, id uuid.UUID, from, to models.OfflineSyncStatus) (bool, error) {
+	query := `
+		UPDATE offline_sync_sessions
+		SET status = $1, updated_at = NOW(),
+			completed_at = CASE WHEN $1 = 'completed' THEN NOW() ELSE completed_at END
+		WHERE id = $2 AND status = $3`
+
+	result, err := r.DB().ExecContext(ctx, query, to, id, from)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to update offline sync session status")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get rows affected")
+	}
+
+	return rows > 0, nil
+}
+
+// ExpireSessions expires open sessions past their expiry and returns them
+func (r *OfflineSyncRepository) ExpireSessions(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+
+	query := `
+		UPDATE offline_sync_sessions SET status = 'expired', updated_at = NOW()
+		WHERE status = 'open' AND expires_at <= $1
+		RETURNING id`
+
+	if err := r.DB().SelectContext(ctx, &ids, query, now); err != nil {
+		return nil, errors.Wrap(err, "failed to expire offline sync sessions")
+	}
+
+	return ids, nil
+}
+
+// GetItem retrieves an item of an offline sync session
+func (r *OfflineSyncRepository) GetItem(ctx context.Context, sessionID, itemID uuid.UUID) (*models.OfflineSyncItem, error) {
+	var item models.OfflineSyncItem
+
+	query := `SELECT ` + offlineSyncItemColumns + ` FROM offline_sync_items WHERE session_id = $1 AND id = $2`
+
+	if err := r.DB().GetContext(ctx, &item, query, sessionID, itemID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrOfflineSyncItemNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get offline sync item")
+	}
+
+	return &item, nil
+}
+
+// ListItems lists the items of an offline sync session in manifest order
+func (r *OfflineSyncRepository) ListItems(ctx context.Context, sessionID uuid.UUID) ([]models.OfflineSyncItem, error) {
+	items := []models.OfflineSyncItem{}
+
+	query := `
+		SELECT ` + offlineSyncItemColumns + `
+		FROM offline_sync_items
+		WHERE session_id = $1
+		ORDER BY client_item_id`
+
+	if err := r.DB().SelectContext(ctx, &items, query, sessionID); err != nil {
+		return nil, errors.Wrap(err, "failed to list offline sync items")
+	}
+
+	return items, nil
+}
+
+// UpdateItem records what became of an item
+func (r *OfflineSyncRepository) UpdateItem(ctx context.Context, item *models.OfflineSyncItem) error {
+	query := `
+		UPDATE offline_sync_items
+		SET mime_type = :mime_type, status = :status, status_reason = :status_reason,
+			evidence_id = :evidence_id, conflict_evidence_id = :conflict_evidence_id, synced_at = :synced_at
+		WHERE id = :id`
+
+	if _, err := r.DB().NamedExecContext(ctx, query, item); err != nil {
+		return errors.Wrap(err, "failed to update offline sync item")
+	}
+
+	return nil
+}
+
+// FindSyncedItem returns an item of a device that another session synced
+// into the case, or nil
+func (r *OfflineSyncRepository) FindSyncedItem(ctx context.Context, investigationID uuid.UUID, deviceID, clientItemID string, excludeSession uuid.UUID) (*models.OfflineSyncItem, error) {
+	var item models.OfflineSyncItem
+
+	query := `
+		SELECT i.id, i.session_id, i.client_item_id, i.name, i.description, i.evidence_type, i.file_name,
+			   i.file_size, i.file_hash, i.mime_type, i.collected_at, i.base_evidence_id, i.base_file_hash,
+			   i.local_custody, i.metadata, i.tags, i.total_chunks, i.status, i.status_reason, i.evidence_id,
+			   i.conflict_evidence_id, i.synced_at, i.created_at
+		FROM offline_sync_items i
+		JOIN offline_sync_sessions s ON s.id = i.session_id
+		WHERE s.investigation_id = $1 AND s.device_id = $2 AND i.client_item_id = $3
+			AND s.id <> $4 AND i.status IN ('synced', 'conflict') AND i.evidence_id IS NOT NULL
+		ORDER BY i.synced_at DESC
+		LIMIT 1`
+
+	if err := r.DB().GetContext(ctx, &item, query, investigationID, deviceID, clientItemID, excludeSession); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to find synced offline item")
+	}
+
+	return &item, nil
+}
+
+// RecordChunk records a verified chunk; a chunk sent again replaces the
+// earlier record
+func (r *OfflineSyncRepository) RecordChunk(ctx context.Context, chunk *models.OfflineSyncChunk) error {
+	query := `
+		INSERT INTO offline_sync_chunks (item_id, chunk_index, size, chunk_hash, received_at)
+		VALUES (:item_id, :chunk_index, :size, :chunk_hash, :received_at)
+		ON CONFLICT (item_id, chunk_index) DO UPDATE
+		SET size = EXCLUDED.size, chunk_hash = EXCLUDED.chunk_hash, received_at = EXCLUDED.received_at`
+
+	if _, err := r.DB().NamedExecContext(ctx, query, chunk); err != nil {
+		return errors.Wrap(err, "failed to record offline sync chunk")
+	}
+
+	return nil
+}
+
+// ListChunks lists the chunks received for the items of a session
+func (r *OfflineSyncRepository) ListChunks(ctx context.Context, sessionID uuid.UUID) ([]models.OfflineSyncChunk, error) {
+	chunks := []models.OfflineSyncChunk{}
+
+	query := `
+		SELECT c.item_id, c.chunk_index, c.size, c.chunk_hash, c.received_at
+		FROM offline_sync_chunks c
+		JOIN offline_sync_items i ON i.id = c.item_id
+		WHERE i.session_id = $1
+		ORDER BY c.item_id, c.chunk_index`
+
+	if err := r.DB().SelectContext(ctx, &chunks, query, sessionID); err != nil {
+		return nil, errors.Wrap(err, "failed to list offline sync chunks")
+	}
+
+	return chunks, nil
+}
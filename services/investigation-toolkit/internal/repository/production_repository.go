@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/database"
+	"investigation-toolkit/internal/models"
+)
+
+var (
+	// ErrProductionNotFound is returned when a law-enforcement production does not exist
+	ErrProductionNotFound = errors.New("production not found")
+	// ErrProductionNotDraft is returned when approving a production that is no longer a draft
+	ErrProductionNotDraft = errors.New("production is no longer a draft")
+	// ErrProductionNotApproved is returned when producing a production that
+	// is not approved, or has already been produced
+	ErrProductionNotApproved = errors.New("production is not approved or has already been produced")
+)
+
+// ProductionRepository handles law-enforcement productions and the case
+// artifacts they select
+type ProductionRepository struct {
+	*database.Repository
+}
+
+// NewProductionRepository creates a new production repository
+func NewProductionRepository(db *database.Database, logger *zap.Logger) *ProductionRepository {
+	return &ProductionRepository{
+		Repository: database.NewRepository(db, logger),
+	}
+}
+
+const productionColumns = `
+	id, investigation_id, template_id, agency, request_reference, evidence_ids,
+	timeline_ids, sar_filing_ids, redactions, status, requested_by, approved_by,
+	approved_at, produced_by, produced_at, file_name, content_type, file_size,
+	file_sha256, created_at, updated_at`
+
+// GetInvestigation retrieves a case
+func (r *ProductionRepository) GetInvestigation(ctx context.Context, id uuid.UUID) (*models.Investigation, error) {
+	var investigation models.Investigation
+
+	query := `SELECT ` + archivalInvestigationColumns + ` FROM investigations WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &investigation, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCaseNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get investigation")
+	}
+
+	return &investigation, nil
+}
+
+// ListEvidence retrieves those of the evidence items that belong to a case
+func (r *ProductionRepository) ListEvidence(ctx context.Context, investigationID uuid.UUID, ids []uuid.UUID) ([]models.Evidence, error) {
+	var evidence []models.Evidence
+
+	query := `
+		SELECT id, investigation_id, name, description, evidence_type, source, collection_method,
+			   file_path, file_size, file_hash, mime_type, collected_by, collected_at,
+			   chain_of_custody, metadata, tags, is_authenticated, authentication_method,
+			   authentication_date, authentication_by, retention_date, status, classification, created_at, updated_at
+		FROM evidence
+		WHERE investigation_id = $1 AND id = ANY($2)
+		ORDER BY collected_at, id`
+
+	if err := r.DB().SelectContext(ctx, &evidence, query, investigationID, pq.Array(ids)); err != nil {
+		return nil, errors.Wrap(err, "failed to list production evidence")
+	}
+
+	return evidence, nil
+}
+
+// ListTimeline retrieves those of the timeline events that belong to a case
+func (r *ProductionRepository) ListTimeline(ctx context.Context, investigationID uuid.UUID, ids []uuid.UUID) ([]models.Timeline, error) {
+	var timeline []models.Timeline
+
+	query := `
+		SELECT id, investigation_id, title, description, event_type, event_date, duration_minutes,
+			   location, participants, related_evidence_ids, external_references, metadata, tags,
+			   created_by, created_at, updated_at
+		FROM timelines
+		WHERE investigation_id = $1 AND id = ANY($2)
+		ORDER BY event_date, id`
+
+	if err := r.DB().SelectContext(ctx, &timeline, query, investigationID, pq.Array(ids)); err != nil {
+		return nil, errors.Wrap(err, "failed to list production timeline")
+	}
+
+	return timeline, nil
+}
+
+// ListSARFilings retrieves those of the SAR filings that were raised from a case
+func (r *ProductionRepository) ListSARFilings(ctx context.Context, investigationID uuid.UUID, ids []uuid.UUID) ([]models.SARFiling, error) {
+	var filings []models.SARFiling
+
+	query := `SELECT ` + sarFilingColumns + `
+		FROM sar_filings
+		WHERE investigation_id = $1 AND id = ANY($2)
+		ORDER BY created_at, id`
+
+	if err := r.DB().SelectContext(ctx, &filings, query, investigationID, pq.Array(ids)); err != nil {
+		return nil, errors.Wrap(err, "failed to list production SAR filings")
+	}
+
+	return filings, nil
+}
+
+// CreateProduction records a draft production
+func (r *ProductionRepository) CreateProduction(ctx context.Context, production *models.LawEnforcementProduction) error {
+	query := `
+		INSERT INTO law_enforcement_productions (
+			investigation_id, template_id, agency, request_reference, evidence_ids,
+			timeline_ids, sar_filing_ids, redactions, status, requested_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + productionColumns
+
+	err := r.DB().GetContext(ctx, production, query,
+		production.InvestigationID, production.TemplateID, production.Agency, production.RequestReference,
+		production.EvidenceIDs, production.TimelineIDs, production.SARFilingIDs, production.Redactions,
+		production.Status, production.RequestedBy)
+	if err != nil {
+		return errors.Wrap(err, "failed to create production")
+	}
+
+	return nil
+}
+
+// GetProduction retrieves a production, with its file once produced
+func (r *ProductionRepository) GetProduction(ctx context.Context, id uuid.UUID) (*models.LawEnforcementProduction, error) {
+	var production models.LawEnforcementProduction
+
+	query := `SELECT ` + productionColumns + `, content FROM law_enforcement_productions WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &production, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrProductionNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get production")
+	}
+
+	return &production, nil
+}
+
+// ListProductions retrieves a case's productions without their files, most recent first
+func (r *ProductionRepository) ListProductions(ctx context.Context, investigationID uuid.UUID) ([]models.LawEnforcementProduction, error) {
+	productions := []models.LawEnforcementProduction{}
+
+	query := `SELECT ` + productionColumns + `
+		FROM law_enforcement_productions
+		WHERE investigation_id = $1
+		ORDER BY created_at DESC`
+
+	if err := r.DB().SelectContext(ctx, &productions, query, investigationID); err != nil {
+		return nil, errors.Wrap(err, "failed to list productions")
+	}
+
+	return productions, nil
+}
+
+// ApproveProduction records the approval of a production that is still a draft
+func (r *ProductionRepository) ApproveProduction(ctx context.Context, production *models.LawEnforcementProduction) error {
+	result, err := r.DB().ExecContext(ctx, `
+		UPDATE law_enforcement_productions
+		SET status = $2, approved_by = $3, approved_at = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'draft'`,
+		production.ID, production.Status, production.ApprovedBy, production.ApprovedAt)
+	if err != nil {
+		return errors.Wrap(err, "failed to approve production")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to approve production")
+	}
+	if rows == 0 {
+		return ErrProductionNotDraft
+	}
+
+	return nil
+}
+
+// RecordProduction stores the file of an approved production and records
+// its disclosure in the audit log. A production is only produced once.
+func (r *ProductionRepository) RecordProduction(ctx context.Context, production *models.LawEnforcementProduction, disclosure *models.AuditLog) error {
+	return r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE law_enforcement_productions
+			SET status = $2, produced_by = $3, produced_at = $4, file_name = $5, content_type = $6,
+				file_size = $7, file_sha256 = $8, content = $9, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND status = 'approved'`,
+			production.ID, production.Status, production.ProducedBy, production.ProducedAt, production.FileName,
+			production.ContentType, production.FileSize, production.FileSHA256, production.Content)
+		if err != nil {
+			return errors.Wrap(err, "failed to record production")
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "failed to record production")
+		}
+		if rows == 0 {
+			return ErrProductionNotApproved
+		}
+
+		err = tx.GetContext(ctx, &disclosure.ID, `
+			INSERT INTO audit_logs (
+				investigation_id, user_id, action, resource_type, resource_id, new_values, metadata, created_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id`,
+			disclosure.InvestigationID, disclosure.UserID, disclosure.Action, disclosure.ResourceType,
+			disclosure.ResourceID, disclosure.NewValues, disclosure.Metadata, disclosure.CreatedAt)
+		if err != nil {
+			return errors.Wrap(err, "failed to record disclosure")
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/database"
+	"investigation-toolkit/internal/models"
+)
+
+var (
+	// ErrQuestionnaireTemplateNotFound is returned for unknown questionnaire templates
+	ErrQuestionnaireTemplateNotFound = errors.New("questionnaire template not found")
+	// ErrQuestionnaireNotFound is returned for unknown questionnaire assignments
+	ErrQuestionnaireNotFound = errors.New("questionnaire not found")
+	// ErrQuestionnaireChanged is returned when a template or questionnaire
+	// was changed after the copy an update was based on was read
+	ErrQuestionnaireChanged = errors.New("questionnaire was changed concurrently")
+)
+
+// QuestionnaireRepository handles questionnaire templates and the
+// questionnaires assigned from them within cases
+type QuestionnaireRepository struct {
+	*database.Repository
+}
+
+// NewQuestionnaireRepository creates a new questionnaire repository
+func NewQuestionnaireRepository(db *database.Database, logger *zap.Logger) *QuestionnaireRepository {
+	return &QuestionnaireRepository{
+		Repository: database.NewRepository(db, logger),
+	}
+}
+
+const questionnaireTemplateColumns = `
+	id, name, description, status, questions, created_by, published_at, created_at, updated_at`
+
+const questionnaireAssignmentColumns = `
+	id, investigation_id, template_id, template_name, questions, answers, status,
+	assigned_to, assigned_by, due_date, submitted_by, submitted_at,
+	cancelled_by, cancelled_at, created_at, updated_at`
+
+// CreateTemplate stores a new template
+func (r *QuestionnaireRepository) CreateTemplate(ctx context.Context, template *models.QuestionnaireTemplate) error {
+	query := `
+		INSERT INTO questionnaire_templates (name, description, status, questions, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + questionnaireTemplateColumns
+
+	err := r.DB().GetContext(ctx, template, query,
+		template.Name, template.Description, template.Status, template.Questions, template.CreatedBy)
+	if err != nil {
+		return errors.Wrap(err, "failed to create questionnaire template")
+	}
+
+	return nil
+}
+
+// GetTemplate retrieves a template by ID
+func (r *QuestionnaireRepository) GetTemplate(ctx context.Context, id uuid.UUID) (*models.QuestionnaireTemplate, error) {
+	var template models.QuestionnaireTemplate
+
+	query := `SELECT ` + questionnaireTemplateColumns + ` FROM questionnaire_templates WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &template, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrQuestionnaireTemplateNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get questionnaire template")
+	}
+
+	return &template, nil
+}
+
+// ListTemplates retrieves templates by name, only those with status unless it is empty
+func (r *QuestionnaireRepository) ListTemplates(ctx context.Context, status models.QuestionnaireTemplateStatus) ([]models.QuestionnaireTemplate, error) {
+	var templates []models.QuestionnaireTemplate
+
+	query := `SELECT ` + questionnaireTemplateColumns + `
+		FROM questionnaire_templates
+		WHERE $1 = '' OR status = $1
+		ORDER BY name, created_at`
+
+	if err := r.DB().SelectContext(ctx, &templates, query, string(status)); err != nil {
+		return nil, errors.Wrap(err, "failed to list questionnaire templates")
+	}
+
+	return templates, nil
+}
+
+// UpdateTemplate saves a draft template's name, description and questions
+func (r *QuestionnaireRepository) UpdateTemplate(ctx context.Context, template *models.QuestionnaireTemplate) error {
+	query := `
+		UPDATE questionnaire_templates
+		SET name = $2, description = $3, questions = $4
+		WHERE id = $1 AND status = 'draft'
+		RETURNING ` + questionnaireTemplateColumns
+
+	err := r.DB().GetContext(ctx, template, query,
+		template.ID, template.Name, template.Description, template.Questions)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrQuestionnaireChanged
+		}
+		return errors.Wrap(err, "failed to update questionnaire template")
+	}
+
+	return nil
+}
+
+// SetTemplateStatus moves a template to its status and publication time,
+// provided it still has the status it is moving from
+func (r *QuestionnaireRepository) SetTemplateStatus(ctx context.Context, template *models.QuestionnaireTemplate, from models.QuestionnaireTemplateStatus) error {
+	query := `
+		UPDATE questionnaire_templates
+		SET status = $2, published_at = $3
+		WHERE id = $1 AND status = $4
+		RETURNING ` + questionnaireTemplateColumns
+
+	err := r.DB().GetContext(ctx, template, query, template.ID, template.Status, template.PublishedAt, from)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrQuestionnaireChanged
+		}
+		return errors.Wrap(err, "failed to update questionnaire template status")
+	}
+
+	return nil
+}
+
+// CreateAssignment stores a questionnaire assigned within a case
+func (r *QuestionnaireRepository) CreateAssignment(ctx context.Context, assignment *models.QuestionnaireAssignment) error {
+	query := `
+		INSERT INTO questionnaire_assignments (
+			investigation_id, template_id, template_name, questions, answers, status,
+			assigned_to, assigned_by, due_date
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + questionnaireAssignmentColumns
+
+	err := r.DB().GetContext(ctx, assignment, query,
+		assignment.InvestigationID, assignment.TemplateID, assignment.TemplateName, assignment.Questions,
+		assignment.Answers, assignment.Status, assignment.AssignedTo, assignment.AssignedBy, assignment.DueDate)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return ErrCaseNotFound
+		}
+		return errors.Wrap(err, "failed to create questionnaire assignment")
+	}
+
+	return nil
+}
+
+// GetAssignment retrieves an assigned questionnaire by ID
+func (r *QuestionnaireRepository) GetAssignment(ctx context.Context, id uuid.UUID) (*models.QuestionnaireAssignment, error) {
+	var assignment models.QuestionnaireAssignment
+
+	query := `SELECT ` + questionnaireAssignmentColumns + ` FROM questionnaire_assignments WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &assignment, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrQuestionnaireNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get questionnaire assignment")
+	}
+
+	return &assignment, nil
+}
+
+// ListAssignments retrieves the questionnaires assigned within a case, oldest first
+func (r *QuestionnaireRepository) ListAssignments(ctx context.Context, investigationID uuid.UUID) ([]models.QuestionnaireAssignment, error) {
+	var assignments []models.QuestionnaireAssignment
+
+	query := `SELECT ` + questionnaireAssignmentColumns + `
+		FROM questionnaire_assignments
+		WHERE investigation_id = $1
+		ORDER BY created_at`
+
+	if err := r.DB().SelectContext(ctx, &assignments, query, investigationID); err != nil {
+		return nil, errors.Wrap(err, "failed to list questionnaire assignments")
+	}
+
+	return assignments, nil
+}
+
+// UpdateAssignment saves an assignment's answers and status unless it was
+// updated after previous
+func (r *QuestionnaireRepository) UpdateAssignment(ctx context.Context, assignment *models.QuestionnaireAssignment, previous time.Time) error {
+	query := `
+		UPDATE questionnaire_assignments
+		SET answers = $2, status = $3, submitted_by = $4, submitted_at = $5,
+			cancelled_by = $6, cancelled_at = $7
+		WHERE id = $1 AND updated_at = $8
+		RETURNING ` + questionnaireAssignmentColumns
+
+	err := r.DB().GetContext(ctx, assignment, query,
+		assignment.ID, assignment.Answers, assignment.Status, assignment.SubmittedBy, assignment.SubmittedAt,
+		assignment.CancelledBy, assignment.CancelledAt, previous)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrQuestionnaireChanged
+		}
+		return errors.Wrap(err, "failed to update questionnaire assignment")
+	}
+
+	return nil
+}
+
+// CaseExists returns ErrCaseNotFound unless the case exists
+func (r *QuestionnaireRepository) CaseExists(ctx context.Context, investigationID uuid.UUID) error {
+	var exists bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM investigations WHERE id = $1)`
+
+	if err := r.DB().GetContext(ctx, &exists, query, investigationID); err != nil {
+		return errors.Wrap(err, "failed to get investigation")
+	}
+	if !exists {
+		return ErrCaseNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/database"
+	"investigation-toolkit/internal/models"
+)
+
+// QuotaRepository handles evidence storage usage, limits, reservations and charges
+type QuotaRepository struct {
+	*database.Repository
+}
+
+// NewQuotaRepository creates a new quota repository
+func NewQuotaRepository(db *database.Database, logger *zap.Logger) *QuotaRepository {
+	return &QuotaRepository{
+		Repository: database.NewRepository(db, logger),
+	}
+}
+
+const quotaUsageColumns = `
+	u.scope, u.subject_id, u.used_bytes, u.used_files, u.reserved_bytes, u.reserved_files,
+	l.limit_bytes, l.limit_files, u.warned_level, u.updated_at`
+
+// lockUsage creates a subject's usage row if it has none and locks it for
+// the rest of the transaction
+func (r *QuotaRepository) lockUsage(ctx context.Context, tx *sqlx.Tx, scope, subjectID string) (*models.StorageQuotaUsage, error) {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO storage_quota_usage (scope, subject_id) VALUES ($1, $2)
+		ON CONFLICT (scope, subject_id) DO NOTHING`,
+		scope, subjectID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create storage usage")
+	}
+
+	var usage models.StorageQuotaUsage
+	err = tx.GetContext(ctx, &usage, `
+		SELECT `+quotaUsageColumns+`
+		FROM storage_quota_usage u
+		LEFT JOIN storage_quota_limits l ON l.scope = u.scope AND l.subject_id = u.subject_id
+		WHERE u.scope = $1 AND u.subject_id = $2
+		FOR UPDATE OF u`,
+		scope, subjectID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to lock storage usage")
+	}
+
+	return &usage, nil
+}
+
+// adjustUsage adds to a subject's stored and reserved bytes and files,
+// returning the updated usage
+func (r *QuotaRepository) adjustUsage(ctx context.Context, tx *sqlx.Tx, scope, subjectID string, usedBytes, usedFiles, reservedBytes, reservedFiles int64) (*models.StorageQuotaUsage, error) {
+	var usage models.StorageQuotaUsage
+	err := tx.GetContext(ctx, &usage, `
+		WITH u AS (
+			UPDATE storage_quota_usage
+			SET used_bytes = GREATEST(used_bytes + $3, 0), used_files = GREATEST(used_files + $4, 0),
+				reserved_bytes = GREATEST(reserved_bytes + $5, 0), reserved_files = GREATEST(reserved_files + $6, 0),
+				updated_at = CURRENT_TIMESTAMP
+			WHERE scope = $1 AND subject_id = $2
+			RETURNING *
+		)
+		SELECT `+quotaUsageColumns+`
+		FROM u
+		LEFT JOIN storage_quota_limits l ON l.scope = u.scope AND l.subject_id = u.subject_id`,
+		scope, subjectID, usedBytes, usedFiles, reservedBytes, reservedFiles)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update storage usage")
+	}
+
+	return &usage, nil
+}
+
+// Reserve locks the usage of the reservation's tenant and user, tenant
+// first, checks it and holds the reservation's bytes and one file against both
+func (r *QuotaRepository) Reserve(ctx context.Context, reservation *models.StorageReservation, check func(tenant, user *models.StorageQuotaUsage) error) error {
+	return r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		tenant, err := r.lockUsage(ctx, tx, "tenant", reservation.TenantID)
+		if err != nil {
+			return err
+		}
+		user, err := r.lockUsage(ctx, tx, "user", reservation.UserID.String())
+		if err != nil {
+			return err
+		}
+
+		if err := check(tenant, user); err != nil {
+			return err
+		}
+
+		if _, err := r.adjustUsage(ctx, tx, "tenant", reservation.TenantID, 0, 0, reservation.Bytes, 1); err != nil {
+			return err
+		}
+		if _, err := r.adjustUsage(ctx, tx, "user", reservation.UserID.String(), 0, 0, reservation.Bytes, 1); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO storage_quota_reservations (id, tenant_id, user_id, bytes, expires_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			reservation.ID, reservation.TenantID, reservation.UserID, reservation.Bytes,
+			reservation.ExpiresAt, reservation.CreatedAt)
+		if err != nil {
+			return errors.Wrap(err, "failed to create storage reservation")
+		}
+
+		return nil
+	})
+}
+
+// Commit charges an object's bytes to the reservation's tenant and user in
+// place of any earlier charge for the object, and frees the reservation.
+// A reservation that already expired is charged all the same.
+func (r *QuotaRepository) Commit(ctx context.Context, reservation *models.StorageReservation, objectType string, objectID uuid.UUID) (*models.StorageQuotaUsage, *models.StorageQuotaUsage, error) {
+	var tenant, user *models.StorageQuotaUsage
+
+	err := r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		// Lock in the same order as Reserve
+		if _, err := r.lockUsage(ctx, tx, "tenant", reservation.TenantID); err != nil {
+			return err
+		}
+		if _, err := r.lockUsage(ctx, tx, "user", reservation.UserID.String()); err != nil {
+			return err
+		}
+
+		var reservedBytes, reservedFiles int64
+		var held []int64
+		err := tx.SelectContext(ctx, &held, `DELETE FROM storage_quota_reservations WHERE id = $1 RETURNING bytes`, reservation.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to free storage reservation")
+		}
+		if len(held) > 0 {
+			reservedBytes, reservedFiles = held[0], 1
+		}
+
+		if err := r.uncharge(ctx, tx, objectType, objectID); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO storage_quota_charges (object_type, object_id, tenant_id, user_id, bytes)
+			VALUES ($1, $2, $3, $4, $5)`,
+			objectType, objectID, reservation.TenantID, reservation.UserID, reservation.Bytes)
+		if err != nil {
+			return errors.Wrap(err, "failed to charge storage")
+		}
+
+		tenant, err = r.adjustUsage(ctx, tx, "tenant", reservation.TenantID, reservation.Bytes, 1, -reservedBytes, -reservedFiles)
+		if err != nil {
+			return err
+		}
+		user, err = r.adjustUsage(ctx, tx, "user", reservation.UserID.String(), reservation.Bytes, 1, -reservedBytes, -reservedFiles)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tenant, user, nil
+}
+
+// Release frees a reservation
+func (r *QuotaRepository) Release(ctx context.Context, reservationID uuid.UUID) error {
+	return r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := r.release(ctx, tx, `WHERE id = $1`, reservationID)
+		return err
+	})
+}
+
+// ReleaseExpired frees the reservations that expired before now
+func (r *QuotaRepository) ReleaseExpired(ctx context.Context, now time.Time) (int, error) {
+	var released int
+	err := r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		released, err = r.release(ctx, tx, `WHERE expires_at < $1`, now)
+		return err
+	})
+	return released, err
+}
+
+func (r *QuotaRepository) release(ctx context.Context, tx *sqlx.Tx, where string, arg interface{}) (int, error) {
+	var freed []models.StorageReservation
+	err := tx.SelectContext(ctx, &freed, `
+		DELETE FROM storage_quota_reservations `+where+`
+		RETURNING id, tenant_id, user_id, bytes, expires_at, created_at`,
+		arg)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to release storage reservations")
+	}
+
+	for _, reservation := range freed {
+		if _, err := r.adjustUsage(ctx, tx, "tenant", reservation.TenantID, 0, 0, -reservation.Bytes, -1); err != nil {
+			return 0, err
+		}
+		if _, err := r.adjustUsage(ctx, tx, "user", reservation.UserID.String(), 0, 0, -reservation.Bytes, -1); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(freed), nil
+}
+
+// Uncharge removes an object's charge and returns its bytes to its tenant and user
+func (r *QuotaRepository) Uncharge(ctx context.Context, objectType string, objectID uuid.UUID) error {
+	return r.WithTx(ctx, func(tx *sqlx.Tx) error {
+		return r.uncharge(ctx, tx, objectType, objectID)
+	})
+}
+
+func (r *QuotaRepository) uncharge(ctx context.Context, tx *sqlx.Tx, objectType string, objectID uuid.UUID) error {
+	var charges []models.StorageCharge
+	err := tx.SelectContext(ctx, &charges, `
+		DELETE FROM storage_quota_charges WHERE object_type = $1 AND object_id = $2
+		RETURNING object_type, object_id, tenant_id, user_id, bytes, created_at`,
+		objectType, objectID)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove storage charge")
+	}
+
+	for _, charge := range charges {
+		if _, err := r.adjustUsage(ctx, tx, "tenant", charge.TenantID, -charge.Bytes, -1, 0, 0); err != nil {
+			return err
+		}
+		if _, err := r.adjustUsage(ctx, tx, "user", charge.UserID.String(), -charge.Bytes, -1, 0, 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetUsage retrieves a subject's usage, which is empty if it has stored nothing
+func (r *QuotaRepository) GetUsage(ctx context.Context, scope, subjectID string) (*models.StorageQuotaUsage, error) {
+	var usage models.StorageQuotaUsage
+
+	query := `
+		SELECT $1::varchar AS scope, $2::varchar AS subject_id,
+			   COALESCE(u.used_bytes, 0) AS used_bytes, COALESCE(u.used_files, 0) AS used_files,
+			   COALESCE(u.reserved_bytes, 0) AS reserved_bytes, COALESCE(u.reserved_files, 0) AS reserved_files,
+			   l.limit_bytes, l.limit_files, COALESCE(u.warned_level, 'ok') AS warned_level,
+			   COALESCE(u.updated_at, l.updated_at, CURRENT_TIMESTAMP) AS updated_at
+		FROM (SELECT 1) AS subject
+		LEFT JOIN storage_quota_usage u ON u.scope = $1 AND u.subject_id = $2
+		LEFT JOIN storage_quota_limits l ON l.scope = $1 AND l.subject_id = $2`
+
+	if err := r.DB().GetContext(ctx, &usage, query, scope, subjectID); err != nil {
+		return nil, errors.Wrap(err, "failed to get storage usage")
+	}
+
+	return &usage, nil
+}
+
+// ListUsage retrieves the usage of every subject in a scope, largest first
+func (r *QuotaRepository) ListUsage(ctx context.Context, scope string) ([]models.StorageQuotaUsage, error) {
+	usages := []models.StorageQuotaUsage{}
+
+	query := `SELECT ` + quotaUsageColumns + `
+		FROM storage_quota_usage u
+		LEFT JOIN storage_quota_limits l ON l.scope = u.scope AND l.subject_id = u.subject_id
+		WHERE u.scope = $1
+		ORDER BY u.used_bytes + u.reserved_bytes DESC, u.subject_id`
+
+	if err := r.DB().SelectContext(ctx, &usages, query, scope); err != nil {
+		return nil, errors.Wrap(err, "failed to list storage usage")
+	}
+
+	return usages, nil
+}
+
+// SetLimit replaces a subject's limits
+func (r *QuotaRepository) SetLimit(ctx context.Context, limit *models.StorageQuotaLimit) error {
+	_, err := r.DB().ExecContext(ctx, `
+		INSERT INTO storage_quota_limits (scope, subject_id, limit_bytes, limit_files, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (scope, subject_id) DO UPDATE
+		SET limit_bytes = EXCLUDED.limit_bytes, limit_files = EXCLUDED.limit_files,
+			updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`,
+		limit.Scope, limit.SubjectID, limit.LimitBytes, limit.LimitFiles, limit.UpdatedBy, limit.UpdatedAt)
+	if err != nil {
+		return errors.Wrap(err, "failed to set storage quota")
+	}
+
+	return nil
+}
+
+// MarkWarned swaps the level a subject's uploaders were last notified of
+func (r *QuotaRepository) MarkWarned(ctx context.Context, scope, subjectID, from, to string) (bool, error) {
+	result, err := r.DB().ExecContext(ctx, `
+		UPDATE storage_quota_usage SET warned_level = $4
+		WHERE scope = $1 AND subject_id = $2 AND warned_level = $3`,
+		scope, subjectID, from, to)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to record storage quota level")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to record storage quota level")
+	}
+
+	return rows > 0, nil
+}
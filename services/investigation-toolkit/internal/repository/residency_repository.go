@@ -0,0 +1,400 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/database"
+	"investigation-toolkit/internal/models"
+)
+
+var (
+	// ErrCaseNotFound is returned when the case a residency check refers to does not exist
+	ErrCaseNotFound = errors.New("investigation not found")
+	// ErrCaseRegionSet is returned when tagging a case that already has a region
+	ErrCaseRegionSet = errors.New("investigation is already pinned to a region")
+	// ErrGrantNotActive is returned when revoking a grant that is missing, revoked or expired
+	ErrGrantNotActive = errors.New("residency grant not found or no longer active")
+)
+
+// ResidencyRepository handles case region tags, cross-region grants and the
+// records behind residency compliance reports
+type ResidencyRepository struct {
+	*database.Repository
+}
+
+// NewResidencyRepository creates a new residency repository
+func NewResidencyRepository(db *database.Database, logger *zap.Logger) *ResidencyRepository {
+	return &ResidencyRepository{
+		Repository: database.NewRepository(db, logger),
+	}
+}
+
+const residencyGrantColumns = `
+	id, investigation_id, region, granted_to, reason, granted_by, expires_at,
+	revoked_at, revoked_by, created_at`
+
+// GetCaseRegion returns the region a case is pinned to, or nil for cases
+// created before residency controls
+func (r *ResidencyRepository) GetCaseRegion(ctx context.Context, investigationID uuid.UUID) (*string, error) {
+	var region sql.NullString
+
+	query := `SELECT region FROM investigations WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &region, query, investigationID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCaseNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get investigation region")
+	}
+
+	if !region.Valid {
+		return nil, nil
+	}
+	return &region.String, nil
+}
+
+// GetEvidenceCase returns the case an evidence item belongs to
+func (r *ResidencyRepository) GetEvidenceCase(ctx context.Context, evidenceID uuid.UUID) (uuid.UUID, error) {
+	return r.caseOf(ctx, `SELECT investigation_id FROM evidence WHERE id = $1`, evidenceID)
+}
+
+// GetSARFilingCase returns the case a SAR filing was raised from
+func (r *ResidencyRepository) GetSARFilingCase(ctx context.Context, filingID uuid.UUID) (uuid.UUID, error) {
+	return r.caseOf(ctx, `SELECT investigation_id FROM sar_filings WHERE id = $1`, filingID)
+}
+
+func (r *ResidencyRepository) caseOf(ctx context.Context, query string, id uuid.UUID) (uuid.UUID, error) {
+	var investigationID uuid.UUID
+
+	if err := r.DB().GetContext(ctx, &investigationID, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, ErrCaseNotFound
+		}
+		return uuid.Nil, errors.Wrap(err, "failed to resolve investigation")
+	}
+
+	return investigationID, nil
+}
+
+// TagCase pins an untagged case to a region. Cases that already have a
+// region are never moved, since their files are stored in that region.
+func (r *ResidencyRepository) TagCase(ctx context.Context, investigationID uuid.UUID, region string) error {
+	query := `UPDATE investigations SET region = $1 WHERE id = $2 AND region IS NULL`
+
+	result, err := r.DB().ExecContext(ctx, query, region, investigationID)
+	if err != nil {
+		return errors.Wrap(err, "failed to set investigation region")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to set investigation region")
+	}
+	if rows == 0 {
+		if _, err := r.GetCaseRegion(ctx, investigationID); err != nil {
+			return err
+		}
+		return ErrCaseRegionSet
+	}
+
+	return nil
+}
+
+// SetEvidenceRegion records the region whose storage holds an evidence file
+func (r *ResidencyRepository) SetEvidenceRegion(ctx context.Context, evidenceID uuid.UUID, region string) error {
+	query := `UPDATE evidence SET storage_region = $1 WHERE id = $2`
+
+	if _, err := r.DB().ExecContext(ctx, query, region, evidenceID); err != nil {
+		return errors.Wrap(err, "failed to set evidence storage region")
+	}
+
+	return nil
+}
+
+// ListCaseEvidence retrieves the evidence records of a case for export
+func (r *ResidencyRepository) ListCaseEvidence(ctx context.Context, investigationID uuid.UUID) ([]models.Evidence, error) {
+	var evidence []models.Evidence
+
+	query := `
+		SELECT id, investigation_id, name, description, evidence_type, source, collection_method,
+			   file_path, file_size, file_hash, mime_type, collected_by, collected_at,
+			   chain_of_custody, metadata, tags, is_authenticated, authentication_method,
+			   authentication_date, authentication_by, retention_date, status, created_at, updated_at
+		FROM evidence
+		WHERE investigation_id = $1
+		ORDER BY collected_at`
+
+	if err := r.DB().SelectContext(ctx, &evidence, query, investigationID); err != nil {
+		return nil, errors.Wrap(err, "failed to list investigation evidence")
+	}
+
+	return evidence, nil
+}
+
+// CreateGrant records a cross-region grant
+func (r *ResidencyRepository) CreateGrant(ctx context.Context, grant *models.ResidencyGrant) error {
+	query := `
+		INSERT INTO residency_grants (
+			id, investigation_id, region, granted_to, reason, granted_by, expires_at, created_at
+		) VALUES (
+			:id, :investigation_id, :region, :granted_to, :reason, :granted_by, :expires_at, :created_at
+		)`
+
+	if _, err := r.DB().NamedExecContext(ctx, query, grant); err != nil {
+		return errors.Wrap(err, "failed to create residency grant")
+	}
+
+	return nil
+}
+
+// GetGrant retrieves a grant by ID
+func (r *ResidencyRepository) GetGrant(ctx context.Context, id uuid.UUID) (*models.ResidencyGrant, error) {
+	var grant models.ResidencyGrant
+
+	query := `SELECT ` + residencyGrantColumns + ` FROM residency_grants WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &grant, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrGrantNotActive
+		}
+		return nil, errors.Wrap(err, "failed to get residency grant")
+	}
+
+	return &grant, nil
+}
+
+// ActiveGrant returns the unexpired, unrevoked grant letting a user in a
+// region access a case, or nil when there is none
+func (r *ResidencyRepository) ActiveGrant(ctx context.Context, investigationID uuid.UUID, region string, userID uuid.UUID) (*models.ResidencyGrant, error) {
+	var grant models.ResidencyGrant
+
+	query := `SELECT ` + residencyGrantColumns + `
+		FROM residency_grants
+		WHERE investigation_id = $1 AND region = $2 AND granted_to = $3
+		  AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY expires_at DESC
+		LIMIT 1`
+
+	if err := r.DB().GetContext(ctx, &grant, query, investigationID, region, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to get active residency grant")
+	}
+
+	return &grant, nil
+}
+
+// ListGrants retrieves every grant made on a case, newest first
+func (r *ResidencyRepository) ListGrants(ctx context.Context, investigationID uuid.UUID) ([]models.ResidencyGrant, error) {
+	var grants []models.ResidencyGrant
+
+	query := `SELECT ` + residencyGrantColumns + `
+		FROM residency_grants
+		WHERE investigation_id = $1
+		ORDER BY created_at DESC`
+
+	if err := r.DB().SelectContext(ctx, &grants, query, investigationID); err != nil {
+		return nil, errors.Wrap(err, "failed to list residency grants")
+	}
+
+	return grants, nil
+}
+
+// RevokeGrant ends an active grant
+func (r *ResidencyRepository) RevokeGrant(ctx context.Context, id, revokedBy uuid.UUID) error {
+	query := `
+		UPDATE residency_grants
+		SET revoked_at = CURRENT_TIMESTAMP, revoked_by = $1
+		WHERE id = $2 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP`
+
+	result, err := r.DB().ExecContext(ctx, query, revokedBy, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke residency grant")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke residency grant")
+	}
+	if rows == 0 {
+		return ErrGrantNotActive
+	}
+
+	return nil
+}
+
+// LogAccess records a cross-region request and its outcome
+func (r *ResidencyRepository) LogAccess(ctx context.Context, access *models.ResidencyAccess) error {
+	query := `
+		INSERT INTO residency_access_log (
+			investigation_id, case_region, request_region, user_id, method, path, decision, grant_id
+		) VALUES (
+			:investigation_id, :case_region, :request_region, :user_id, :method, :path, :decision, :grant_id
+		)`
+
+	if _, err := r.DB().NamedExecContext(ctx, query, access); err != nil {
+		return errors.Wrap(err, "failed to log residency access")
+	}
+
+	return nil
+}
+
+// CreateExport records a case bundle written to regional storage
+func (r *ResidencyRepository) CreateExport(ctx context.Context, export *models.CaseExport) error {
+	query := `
+		INSERT INTO case_exports (
+			id, investigation_id, region, file_path, file_size, file_hash, exported_by, created_at
+		) VALUES (
+			:id, :investigation_id, :region, :file_path, :file_size, :file_hash, :exported_by, :created_at
+		)`
+
+	if _, err := r.DB().NamedExecContext(ctx, query, export); err != nil {
+		return errors.Wrap(err, "failed to create case export")
+	}
+
+	return nil
+}
+
+// ListExports retrieves the exports of a case, newest first
+func (r *ResidencyRepository) ListExports(ctx context.Context, investigationID uuid.UUID) ([]models.CaseExport, error) {
+	var exports []models.CaseExport
+
+	query := `
+		SELECT id, investigation_id, region, file_path, file_size, file_hash, exported_by, created_at
+		FROM case_exports
+		WHERE investigation_id = $1
+		ORDER BY created_at DESC`
+
+	if err := r.DB().SelectContext(ctx, &exports, query, investigationID); err != nil {
+		return nil, errors.Wrap(err, "failed to list case exports")
+	}
+
+	return exports, nil
+}
+
+// RegionCounts are the stored objects of cases pinned to one region
+type RegionCounts struct {
+	Region            string `json:"region" db:"region"`
+	Cases             int64  `json:"cases" db:"cases"`
+	EvidenceFiles     int64  `json:"evidence_files" db:"evidence_files"`
+	EvidenceMisplaced int64  `json:"evidence_misplaced" db:"evidence_misplaced"`
+	Exports           int64  `json:"exports" db:"exports"`
+	ExportsMisplaced  int64  `json:"exports_misplaced" db:"exports_misplaced"`
+}
+
+// Misplacement is an evidence file or export stored outside its case's region
+type Misplacement struct {
+	Kind            string    `json:"kind" db:"kind"` // evidence or export
+	ObjectID        uuid.UUID `json:"object_id" db:"object_id"`
+	InvestigationID uuid.UUID `json:"investigation_id" db:"investigation_id"`
+	CaseRegion      string    `json:"case_region" db:"case_region"`
+	StoredRegion    string    `json:"stored_region" db:"stored_region"`
+}
+
+// AccessCounts counts cross-region requests by route between regions
+type AccessCounts struct {
+	CaseRegion    string                   `json:"case_region" db:"case_region"`
+	RequestRegion string                   `json:"request_region" db:"request_region"`
+	Decision      models.ResidencyDecision `json:"decision" db:"decision"`
+	Requests      int64                    `json:"requests" db:"requests"`
+}
+
+// ResidencySnapshot is the raw data a residency compliance report is built from
+type ResidencySnapshot struct {
+	Regions            []RegionCounts
+	UntaggedCases      int64
+	UnverifiedEvidence int64
+	Misplaced          []Misplacement
+	Access             []AccessCounts
+	ActiveGrants       int64
+}
+
+// GetResidencySnapshot gathers per-region storage counts, files stored
+// outside their case's region and cross-region requests since a time
+func (r *ResidencyRepository) GetResidencySnapshot(ctx context.Context, since time.Time, misplacedLimit int) (*ResidencySnapshot, error) {
+	snapshot := &ResidencySnapshot{}
+
+	query := `
+		SELECT i.region,
+		       COUNT(*) AS cases,
+		       COALESCE(SUM(ef.files), 0) AS evidence_files,
+		       COALESCE(SUM(ef.misplaced), 0) AS evidence_misplaced,
+		       COALESCE(SUM(x.exports), 0) AS exports,
+		       COALESCE(SUM(x.misplaced), 0) AS exports_misplaced
+		FROM investigations i
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS files,
+			       COUNT(*) FILTER (WHERE ev.storage_region IS NOT NULL AND ev.storage_region <> i.region) AS misplaced
+			FROM evidence ev
+			WHERE ev.investigation_id = i.id AND ev.file_path IS NOT NULL AND ev.file_path <> ''
+		) ef ON true
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS exports,
+			       COUNT(*) FILTER (WHERE ce.region <> i.region) AS misplaced
+			FROM case_exports ce
+			WHERE ce.investigation_id = i.id
+		) x ON true
+		WHERE i.region IS NOT NULL
+		GROUP BY i.region
+		ORDER BY i.region`
+
+	if err := r.DB().SelectContext(ctx, &snapshot.Regions, query); err != nil {
+		return nil, errors.Wrap(err, "failed to count residency by region")
+	}
+
+	if err := r.DB().GetContext(ctx, &snapshot.UntaggedCases,
+		`SELECT COUNT(*) FROM investigations WHERE region IS NULL`); err != nil {
+		return nil, errors.Wrap(err, "failed to count untagged investigations")
+	}
+
+	if err := r.DB().GetContext(ctx, &snapshot.UnverifiedEvidence, `
+		SELECT COUNT(*) FROM evidence
+		WHERE file_path IS NOT NULL AND file_path <> '' AND storage_region IS NULL`); err != nil {
+		return nil, errors.Wrap(err, "failed to count evidence with unknown storage region")
+	}
+
+	misplacedQuery := `
+		SELECT 'evidence' AS kind, e.id AS object_id, i.id AS investigation_id,
+		       i.region AS case_region, e.storage_region AS stored_region
+		FROM evidence e
+		JOIN investigations i ON i.id = e.investigation_id
+		WHERE i.region IS NOT NULL AND e.storage_region IS NOT NULL AND e.storage_region <> i.region
+		UNION ALL
+		SELECT 'export' AS kind, x.id AS object_id, i.id AS investigation_id,
+		       i.region AS case_region, x.region AS stored_region
+		FROM case_exports x
+		JOIN investigations i ON i.id = x.investigation_id
+		WHERE i.region IS NOT NULL AND x.region <> i.region
+		ORDER BY investigation_id
+		LIMIT $1`
+
+	if err := r.DB().SelectContext(ctx, &snapshot.Misplaced, misplacedQuery, misplacedLimit); err != nil {
+		return nil, errors.Wrap(err, "failed to list misplaced residency objects")
+	}
+
+	accessQuery := `
+		SELECT case_region, request_region, decision, COUNT(*) AS requests
+		FROM residency_access_log
+		WHERE created_at >= $1
+		GROUP BY case_region, request_region, decision
+		ORDER BY case_region, request_region, decision`
+
+	if err := r.DB().SelectContext(ctx, &snapshot.Access, accessQuery, since); err != nil {
+		return nil, errors.Wrap(err, "failed to count cross-region access")
+	}
+
+	if err := r.DB().GetContext(ctx, &snapshot.ActiveGrants, `
+		SELECT COUNT(*) FROM residency_grants
+		WHERE revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP`); err != nil {
+		return nil, errors.Wrap(err, "failed to count active residency grants")
+	}
+
+	return snapshot, nil
+}
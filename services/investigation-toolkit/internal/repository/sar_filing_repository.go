@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/database"
+	"investigation-toolkit/internal/models"
+)
+
+// ErrSARNotDraft is returned when changing a SAR that is missing or no
+// longer a draft
+var ErrSARNotDraft = errors.New("SAR filing not found or no longer a draft")
+
+// SARFilingRepository handles suspicious activity report database operations
+type SARFilingRepository struct {
+	*database.Repository
+}
+
+// NewSARFilingRepository creates a new SAR filing repository
+func NewSARFilingRepository(db *database.Database, logger *zap.Logger) *SARFilingRepository {
+	return &SARFilingRepository{
+		Repository: database.NewRepository(db, logger),
+	}
+}
+
+const sarFilingColumns = `
+	id, investigation_id, filing_type, status, reference, prior_filing_id, alert_ids,
+	evidence_ids, activity_start, activity_end, narrative, filed_at, filed_by, created_by,
+	metadata, created_at, updated_at`
+
+// Create creates a new SAR filing
+func (r *SARFilingRepository) Create(ctx context.Context, filing *models.SARFiling) error {
+	query := `
+		INSERT INTO sar_filings (
+			id, investigation_id, filing_type, status, prior_filing_id, alert_ids, evidence_ids,
+			activity_start, activity_end, narrative, created_by, metadata, created_at, updated_at
+		) VALUES (
+			:id, :investigation_id, :filing_type, :status, :prior_filing_id, :alert_ids, :evidence_ids,
+			:activity_start, :activity_end, :narrative, :created_by, :metadata, :created_at, :updated_at
+		)`
+
+	if _, err := r.DB().NamedExecContext(ctx, query, filing); err != nil {
+		return errors.Wrap(err, "failed to create SAR filing")
+	}
+
+	return nil
+}
+
+// GetByID retrieves a SAR filing by ID
+func (r *SARFilingRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SARFiling, error) {
+	var filing models.SARFiling
+
+	query := `SELECT ` + sarFilingColumns + ` FROM sar_filings WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &filing, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("SAR filing not found")
+		}
+		return nil, errors.Wrap(err, "failed to get SAR filing")
+	}
+
+	return &filing, nil
+}
+
+// GetByInvestigationID retrieves all SAR filings raised from an investigation
+func (r *SARFilingRepository) GetByInvestigationID(ctx context.Context, investigationID uuid.UUID) ([]models.SARFiling, error) {
+	var filings []models.SARFiling
+
+	query := `SELECT ` + sarFilingColumns + `
+		FROM sar_filings
+		WHERE investigation_id = $1
+		ORDER BY created_at DESC`
+
+	if err := r.DB().SelectContext(ctx, &filings, query, investigationID); err != nil {
+		return nil, errors.Wrap(err, "failed to get SAR filings")
+	}
+
+	return filings, nil
+}
+
+// GetFiledBetween retrieves the SARs filed in [from, to), oldest first
+func (r *SARFilingRepository) GetFiledBetween(ctx context.Context, from, to time.Time) ([]models.SARFiling, error) {
+	var filings []models.SARFiling
+
+	query := `SELECT ` + sarFilingColumns + `
+		FROM sar_filings
+		WHERE status = $1 AND filed_at >= $2 AND filed_at < $3
+		ORDER BY filed_at, id`
+
+	if err := r.DB().SelectContext(ctx, &filings, query, models.SARStatusFiled, from, to); err != nil {
+		return nil, errors.Wrap(err, "failed to get filed SARs")
+	}
+
+	return filings, nil
+}
+
+// Update updates the links and details of a draft SAR filing. Filed reports
+// are immutable; corrections are filed as new reports.
+func (r *SARFilingRepository) Update(ctx context.Context, filing *models.SARFiling) error {
+	query := `
+		UPDATE sar_filings
+		SET alert_ids = :alert_ids, evidence_ids = :evidence_ids,
+			activity_start = :activity_start, activity_end = :activity_end,
+			narrative = :narrative, metadata = :metadata, updated_at = CURRENT_TIMESTAMP
+		WHERE id = :id AND status = 'draft'`
+
+	result, err := r.DB().NamedExecContext(ctx, query, filing)
+	if err != nil {
+		return errors.Wrap(err, "failed to update SAR filing")
+	}
+
+	return requireDraft(result)
+}
+
+// MarkFiled records that a draft SAR was filed with the regulator
+func (r *SARFilingRepository) MarkFiled(ctx context.Context, id uuid.UUID, reference string, filedAt time.Time, filedBy uuid.UUID) error {
+	query := `
+		UPDATE sar_filings
+		SET status = $1, reference = $2, filed_at = $3, filed_by = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5 AND status = $6`
+
+	result, err := r.DB().ExecContext(ctx, query,
+		models.SARStatusFiled, reference, filedAt, filedBy, id, models.SARStatusDraft)
+	if err != nil {
+		return errors.Wrap(err, "failed to mark SAR filed")
+	}
+
+	return requireDraft(result)
+}
+
+// Withdraw abandons a draft SAR filing
+func (r *SARFilingRepository) Withdraw(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE sar_filings
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = $3`
+
+	result, err := r.DB().ExecContext(ctx, query, models.SARStatusWithdrawn, id, models.SARStatusDraft)
+	if err != nil {
+		return errors.Wrap(err, "failed to withdraw SAR filing")
+	}
+
+	return requireDraft(result)
+}
+
+// GetEvidenceByIDs retrieves the evidence items cited by a filing. Items
+// that no longer exist are simply absent from the result.
+func (r *SARFilingRepository) GetEvidenceByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Evidence, error) {
+	var evidence []models.Evidence
+	if len(ids) == 0 {
+		return evidence, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = id.String()
+	}
+
+	query := `
+		SELECT id, investigation_id, name, description, evidence_type, source, collection_method,
+			   file_path, file_size, file_hash, mime_type, collected_by, collected_at,
+			   chain_of_custody, metadata, tags, is_authenticated, authentication_method,
+			   authentication_date, authentication_by, retention_date, status, created_at, updated_at
+		FROM evidence
+		WHERE id = ANY($1::uuid[])`
+
+	if err := r.DB().SelectContext(ctx, &evidence, query, pq.Array(keys)); err != nil {
+		return nil, errors.Wrap(err, "failed to get cited evidence")
+	}
+
+	return evidence, nil
+}
+
+func requireDraft(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return ErrSARNotDraft
+	}
+
+	return nil
+}
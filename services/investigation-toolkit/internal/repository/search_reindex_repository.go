@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/database"
+	"investigation-toolkit/internal/models"
+)
+
+// ErrReindexInProgress is returned when an index already has an active rebuild
+var ErrReindexInProgress = errors.New("a reindex of this index is already in progress")
+
+// SearchReindexRepository handles search reindex job tracking
+type SearchReindexRepository struct {
+	*database.Repository
+}
+
+// NewSearchReindexRepository creates a new search reindex repository
+func NewSearchReindexRepository(db *database.Database, logger *zap.Logger) *SearchReindexRepository {
+	return &SearchReindexRepository{
+		Repository: database.NewRepository(db, logger),
+	}
+}
+
+const reindexJobColumns = `
+	id, index_name, source_index, target_index, status, task_id, documents_total,
+	documents_copied, error, requested_by, backfill_started_at, completed_at, created_at, updated_at`
+
+// Create inserts a new job in the dual-writing phase
+func (r *SearchReindexRepository) Create(ctx context.Context, job *models.SearchReindexJob) error {
+	query := `
+		INSERT INTO search_reindex_jobs (index_name, source_index, target_index, status, requested_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + reindexJobColumns
+
+	err := r.DB().GetContext(ctx, job, query,
+		job.IndexName, job.SourceIndex, job.TargetIndex, models.ReindexStatusDualWriting, job.RequestedBy)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrReindexInProgress
+		}
+		return errors.Wrap(err, "failed to create reindex job")
+	}
+
+	return nil
+}
+
+// GetByID retrieves a reindex job
+func (r *SearchReindexRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SearchReindexJob, error) {
+	var job models.SearchReindexJob
+
+	query := `SELECT ` + reindexJobColumns + ` FROM search_reindex_jobs WHERE id = $1`
+
+	if err := r.DB().GetContext(ctx, &job, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("reindex job not found")
+		}
+		return nil, errors.Wrap(err, "failed to get reindex job")
+	}
+
+	return &job, nil
+}
+
+// ListActive retrieves jobs that are still dual-writing or backfilling
+func (r *SearchReindexRepository) ListActive(ctx context.Context) ([]models.SearchReindexJob, error) {
+	var jobs []models.SearchReindexJob
+
+	query := `SELECT ` + reindexJobColumns + `
+		FROM search_reindex_jobs
+		WHERE status IN ($1, $2)
+		ORDER BY created_at`
+
+	if err := r.DB().SelectContext(ctx, &jobs, query,
+		models.ReindexStatusDualWriting, models.ReindexStatusBackfilling); err != nil {
+		return nil, errors.Wrap(err, "failed to list active reindex jobs")
+	}
+
+	return jobs, nil
+}
+
+// ListRecent retrieves the most recently started jobs
+func (r *SearchReindexRepository) ListRecent(ctx context.Context, limit int) ([]models.SearchReindexJob, error) {
+	var jobs []models.SearchReindexJob
+
+	query := `SELECT ` + reindexJobColumns + `
+		FROM search_reindex_jobs
+		ORDER BY created_at DESC
+		LIMIT $1`
+
+	if err := r.DB().SelectContext(ctx, &jobs, query, limit); err != nil {
+		return nil, errors.Wrap(err, "failed to list reindex jobs")
+	}
+
+	return jobs, nil
+}
+
+// StartBackfill records the reindex task copying documents into the target index
+func (r *SearchReindexRepository) StartBackfill(ctx context.Context, id uuid.UUID, taskID string) error {
+	query := `
+		UPDATE search_reindex_jobs
+		SET status = $1, task_id = $2, backfill_started_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status = $4`
+
+	if _, err := r.DB().ExecContext(ctx, query,
+		models.ReindexStatusBackfilling, taskID, id, models.ReindexStatusDualWriting); err != nil {
+		return errors.Wrap(err, "failed to start reindex backfill")
+	}
+
+	return nil
+}
+
+// UpdateProgress records how many documents the backfill has copied
+func (r *SearchReindexRepository) UpdateProgress(ctx context.Context, id uuid.UUID, total, copied int64) error {
+	query := `UPDATE search_reindex_jobs SET documents_total = $1, documents_copied = $2 WHERE id = $3`
+
+	if _, err := r.DB().ExecContext(ctx, query, total, copied, id); err != nil {
+		return errors.Wrap(err, "failed to update reindex progress")
+	}
+
+	return nil
+}
+
+// Finish moves an active job to a final status. It returns false when the job
+// had already finished, so a cancelled job is not later marked completed.
+func (r *SearchReindexRepository) Finish(ctx context.Context, id uuid.UUID, status models.ReindexStatus, message *string) (bool, error) {
+	query := `
+		UPDATE search_reindex_jobs
+		SET status = $1, error = $2, completed_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status IN ($4, $5)`
+
+	result, err := r.DB().ExecContext(ctx, query, status, message, id,
+		models.ReindexStatusDualWriting, models.ReindexStatusBackfilling)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to finish reindex job")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to finish reindex job")
+	}
+
+	return rows > 0, nil
+}
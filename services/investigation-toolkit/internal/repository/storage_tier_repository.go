@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/database"
+	"investigation-toolkit/internal/models"
+)
+
+// StorageTierRepository handles storage tier tracking for evidence files
+type StorageTierRepository struct {
+	*database.Repository
+}
+
+// NewStorageTierRepository creates a new storage tier repository
+func NewStorageTierRepository(db *database.Database, logger *zap.Logger) *StorageTierRepository {
+	return &StorageTierRepository{
+		Repository: database.NewRepository(db, logger),
+	}
+}
+
+const storageObjectColumns = `
+	evidence_id, source_path, object_key, file_size, tier, tier_changed_at, restore_status,
+	restore_requested_at, restore_requested_by, restore_ready_at, restore_expires_at,
+	last_accessed_at, created_at, updated_at`
+
+// registerQuery starts tracking evidence files. A file replaced through the
+// evidence API has a new path, so its row is reset to the standard tier.
+const registerQuery = `
+	INSERT INTO evidence_storage_objects (evidence_id, source_path, object_key, file_size, created_at)
+	SELECT id, file_path, file_path, file_size, COALESCE(created_at, CURRENT_TIMESTAMP)
+	FROM evidence
+	WHERE file_path IS NOT NULL AND file_path <> '' AND ($1::uuid IS NULL OR id = $1)
+	ON CONFLICT (evidence_id) DO UPDATE SET
+		source_path = EXCLUDED.source_path,
+		object_key = EXCLUDED.object_key,
+		file_size = EXCLUDED.file_size,
+		tier = 'standard',
+		tier_changed_at = CURRENT_TIMESTAMP,
+		restore_status = 'none',
+		restore_requested_at = NULL,
+		restore_requested_by = NULL,
+		restore_ready_at = NULL,
+		restore_expires_at = NULL,
+		created_at = CURRENT_TIMESTAMP
+	WHERE evidence_storage_objects.source_path <> EXCLUDED.source_path`
+
+// RegisterAll starts tracking every evidence file that is not tracked yet
+func (r *StorageTierRepository) RegisterAll(ctx context.Context) (int64, error) {
+	result, err := r.DB().ExecContext(ctx, registerQuery, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to register evidence storage objects")
+	}
+
+	return result.RowsAffected()
+}
+
+// Register starts tracking the file of a single evidence item
+func (r *StorageTierRepository) Register(ctx context.Context, evidenceID uuid.UUID) error {
+	if _, err := r.DB().ExecContext(ctx, registerQuery, evidenceID); err != nil {
+		return errors.Wrap(err, "failed to register evidence storage object")
+	}
+
+	return nil
+}
+
+// GetByEvidenceID retrieves the storage object for an evidence item
+func (r *StorageTierRepository) GetByEvidenceID(ctx context.Context, evidenceID uuid.UUID) (*models.EvidenceStorageObject, error) {
+	var object models.EvidenceStorageObject
+
+	query := `SELECT ` + storageObjectColumns + ` FROM evidence_storage_objects WHERE evidence_id = $1`
+
+	if err := r.DB().GetContext(ctx, &object, query, evidenceID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("storage object not found")
+		}
+		return nil, errors.Wrap(err, "failed to get storage object")
+	}
+
+	return &object, nil
+}
+
+// ListDueForTransition retrieves objects whose last activity is older than the
+// cutoff for their next tier. Objects with a restore in flight are left alone.
+func (r *StorageTierRepository) ListDueForTransition(ctx context.Context, infrequentCutoff, archiveCutoff time.Time, limit int) ([]models.EvidenceStorageObject, error) {
+	var objects []models.EvidenceStorageObject
+
+	query := `SELECT ` + storageObjectColumns + `
+		FROM evidence_storage_objects
+		WHERE restore_status = $1
+		  AND ((tier = $2 AND GREATEST(created_at, COALESCE(last_accessed_at, created_at)) < $4)
+		    OR (tier = $3 AND GREATEST(created_at, COALESCE(last_accessed_at, created_at)) < $5))
+		ORDER BY created_at
+		LIMIT $6`
+
+	if err := r.DB().SelectContext(ctx, &objects, query,
+		models.RestoreStatusNone, models.StorageTierStandard, models.StorageTierInfrequentAccess,
+		infrequentCutoff, archiveCutoff, limit); err != nil {
+		return nil, errors.Wrap(err, "failed to list storage objects due for transition")
+	}
+
+	return objects, nil
+}
+
+// SetTier records that an object moved to a new tier and storage key
+func (r *StorageTierRepository) SetTier(ctx context.Context, evidenceID uuid.UUID, tier models.StorageTier, objectKey string) error {
+	query := `
+		UPDATE evidence_storage_objects
+		SET tier = $1, object_key = $2, tier_changed_at = CURRENT_TIMESTAMP
+		WHERE evidence_id = $3`
+
+	if _, err := r.DB().ExecContext(ctx, query, tier, objectKey, evidenceID); err != nil {
+		return errors.Wrap(err, "failed to update storage tier")
+	}
+
+	return nil
+}
+
+// StartRestore marks an archived object as restoring. It returns false when a
+// restore was already requested, so concurrent downloads start only one restore.
+func (r *StorageTierRepository) StartRestore(ctx context.Context, evidenceID, requestedBy uuid.UUID, readyAt time.Time) (bool, error) {
+	query := `
+		UPDATE evidence_storage_objects
+		SET restore_status = $1, restore_requested_at = CURRENT_TIMESTAMP,
+		    restore_requested_by = $2, restore_ready_at = $3, restore_expires_at = NULL
+		WHERE evidence_id = $4 AND tier = $5 AND restore_status = $6`
+
+	result, err := r.DB().ExecContext(ctx, query,
+		models.RestoreStatusInProgress, requestedBy, readyAt, evidenceID,
+		models.StorageTierArchive, models.RestoreStatusNone)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to start restore")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to start restore")
+	}
+
+	return rows > 0, nil
+}
+
+// ResetRestore returns an object to the plain archived state
+func (r *StorageTierRepository) ResetRestore(ctx context.Context, evidenceID uuid.UUID) error {
+	query := `
+		UPDATE evidence_storage_objects
+		SET restore_status = $1, restore_requested_at = NULL, restore_requested_by = NULL,
+		    restore_ready_at = NULL, restore_expires_at = NULL
+		WHERE evidence_id = $2`
+
+	if _, err := r.DB().ExecContext(ctx, query, models.RestoreStatusNone, evidenceID); err != nil {
+		return errors.Wrap(err, "failed to reset restore")
+	}
+
+	return nil
+}
+
+// ListRestoresInProgress retrieves objects waiting for an archive restore
+func (r *StorageTierRepository) ListRestoresInProgress(ctx context.Context, limit int) ([]models.EvidenceStorageObject, error) {
+	var objects []models.EvidenceStorageObject
+
+	query := `SELECT ` + storageObjectColumns + `
+		FROM evidence_storage_objects
+		WHERE restore_status = $1
+		ORDER BY restore_requested_at
+		LIMIT $2`
+
+	if err := r.DB().SelectContext(ctx, &objects, query, models.RestoreStatusInProgress, limit); err != nil {
+		return nil, errors.Wrap(err, "failed to list restores in progress")
+	}
+
+	return objects, nil
+}
+
+// CompleteRestore marks a restored copy as readable until it expires
+func (r *StorageTierRepository) CompleteRestore(ctx context.Context, evidenceID uuid.UUID, expiresAt time.Time) error {
+	query := `
+		UPDATE evidence_storage_objects
+		SET restore_status = $1, restore_ready_at = CURRENT_TIMESTAMP, restore_expires_at = $2
+		WHERE evidence_id = $3 AND restore_status = $4`
+
+	if _, err := r.DB().ExecContext(ctx, query,
+		models.RestoreStatusRestored, expiresAt, evidenceID, models.RestoreStatusInProgress); err != nil {
+		return errors.Wrap(err, "failed to complete restore")
+	}
+
+	return nil
+}
+
+// ListExpiredRestores retrieves restored copies past their expiry
+func (r *StorageTierRepository) ListExpiredRestores(ctx context.Context, limit int) ([]models.EvidenceStorageObject, error) {
+	var objects []models.EvidenceStorageObject
+
+	query := `SELECT ` + storageObjectColumns + `
+		FROM evidence_storage_objects
+		WHERE restore_status = $1 AND restore_expires_at <= CURRENT_TIMESTAMP
+		ORDER BY restore_expires_at
+		LIMIT $2`
+
+	if err := r.DB().SelectContext(ctx, &objects, query, models.RestoreStatusRestored, limit); err != nil {
+		return nil, errors.Wrap(err, "failed to list expired restores")
+	}
+
+	return objects, nil
+}
+
+// TouchAccess records a read so recently used files stay in the faster tiers
+func (r *StorageTierRepository) TouchAccess(ctx context.Context, evidenceID uuid.UUID) error {
+	query := `UPDATE evidence_storage_objects SET last_accessed_at = CURRENT_TIMESTAMP WHERE evidence_id = $1`
+
+	if _, err := r.DB().ExecContext(ctx, query, evidenceID); err != nil {
+		return errors.Wrap(err, "failed to record storage access")
+	}
+
+	return nil
+}
+
+// GetTierStats counts tracked objects and bytes per tier
+func (r *StorageTierRepository) GetTierStats(ctx context.Context) (map[models.StorageTier]models.StorageTierStats, error) {
+	var rows []struct {
+		Tier    models.StorageTier `db:"tier"`
+		Objects int64              `db:"objects"`
+		Bytes   int64              `db:"bytes"`
+	}
+
+	query := `
+		SELECT tier, COUNT(*) AS objects, COALESCE(SUM(file_size), 0) AS bytes
+		FROM evidence_storage_objects
+		GROUP BY tier`
+
+	if err := r.DB().SelectContext(ctx, &rows, query); err != nil {
+		return nil, errors.Wrap(err, "failed to get storage tier stats")
+	}
+
+	stats := make(map[models.StorageTier]models.StorageTierStats, len(rows))
+	for _, row := range rows {
+		stats[row.Tier] = models.StorageTierStats{Objects: row.Objects, Bytes: row.Bytes}
+	}
+
+	return stats, nil
+}
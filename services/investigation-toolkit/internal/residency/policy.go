@@ -0,0 +1,88 @@
+package residency
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+)
+
+// ErrUnknownRegion is returned for regions without configured storage
+var ErrUnknownRegion = errors.New("unknown data residency region")
+
+// Policy decides which cases this deployment may serve
+type Policy struct {
+	LocalRegion   string
+	DefaultRegion string
+	regions       map[string]bool
+}
+
+// NewPolicy builds a residency policy from configuration. The regions are
+// those with an evidence location.
+func NewPolicy(cfg config.ResidencyConfig) Policy {
+	regions := make(map[string]bool, len(cfg.EvidenceLocations))
+	for region := range cfg.EvidenceLocations {
+		regions[region] = true
+	}
+
+	return Policy{
+		LocalRegion:   cfg.LocalRegion,
+		DefaultRegion: cfg.DefaultRegion,
+		regions:       regions,
+	}
+}
+
+// Known reports whether a region has configured storage
+func (p Policy) Known(region string) bool {
+	return p.regions[region]
+}
+
+// RegionForNewCase returns the region a new case is pinned to: the one
+// requested, or the default region
+func (p Policy) RegionForNewCase(requested *string) (string, error) {
+	if requested == nil || *requested == "" {
+		return p.DefaultRegion, nil
+	}
+	if !p.Known(*requested) {
+		return "", errors.Wrap(ErrUnknownRegion, *requested)
+	}
+	return *requested, nil
+}
+
+// Decision is the outcome of a residency check
+type Decision struct {
+	Allowed bool `json:"allowed"`
+	// CrossRegion is set when the case is pinned to another region; these
+	// requests are recorded whether or not they are allowed
+	CrossRegion bool       `json:"cross_region"`
+	CaseRegion  string     `json:"case_region,omitempty"`
+	GrantID     *uuid.UUID `json:"grant_id,omitempty"`
+	Reason      string     `json:"reason"`
+}
+
+// Decide checks whether this deployment may serve a case pinned to
+// caseRegion. Cases pinned elsewhere are served only under an active grant
+// for the local region. Untagged cases predate residency controls and are
+// served anywhere until they are tagged.
+func (p Policy) Decide(caseRegion *string, grant *models.ResidencyGrant, now time.Time) Decision {
+	if caseRegion == nil {
+		return Decision{Allowed: true, Reason: "case is not pinned to a region"}
+	}
+	if *caseRegion == p.LocalRegion {
+		return Decision{Allowed: true, CaseRegion: *caseRegion, Reason: "case is pinned to this region"}
+	}
+
+	decision := Decision{CrossRegion: true, CaseRegion: *caseRegion}
+	if grant != nil && grant.Region == p.LocalRegion && grant.Active(now) {
+		decision.Allowed = true
+		decision.GrantID = &grant.ID
+		decision.Reason = "cross-region access authorized by residency grant"
+		return decision
+	}
+
+	decision.Reason = "case data is pinned to region " + *caseRegion
+	return decision
+}
@@ -0,0 +1,89 @@
+package residency
+
+import (
+	"fmt"
+	"time"
+
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+)
+
+// Report summarizes residency compliance: where case data is stored, what is
+// stored outside its case's region, and cross-region requests in a period
+type Report struct {
+	GeneratedAt        time.Time                 `json:"generated_at"`
+	LocalRegion        string                    `json:"local_region"`
+	Since              time.Time                 `json:"since"`
+	Compliant          bool                      `json:"compliant"`
+	Issues             []string                  `json:"issues"`
+	Regions            []repository.RegionCounts `json:"regions"`
+	UntaggedCases      int64                     `json:"untagged_cases"`
+	UnverifiedEvidence int64                     `json:"unverified_evidence"`
+	Misplaced          []repository.Misplacement `json:"misplaced"`
+	CrossRegionAccess  []repository.AccessCounts `json:"cross_region_access"`
+	DeniedRequests     int64                     `json:"denied_requests"`
+	ActiveGrants       int64                     `json:"active_grants"`
+}
+
+// BuildReport assesses a residency snapshot. Data stored outside its case's
+// region, cases pinned to regions without storage, untagged cases and
+// evidence with an unknown storage region make the report non-compliant.
+// Denied cross-region requests are reported but are the controls working.
+func BuildReport(snapshot *repository.ResidencySnapshot, policy Policy, since, now time.Time) *Report {
+	report := &Report{
+		GeneratedAt:        now,
+		LocalRegion:        policy.LocalRegion,
+		Since:              since,
+		Issues:             []string{},
+		Regions:            snapshot.Regions,
+		UntaggedCases:      snapshot.UntaggedCases,
+		UnverifiedEvidence: snapshot.UnverifiedEvidence,
+		Misplaced:          snapshot.Misplaced,
+		CrossRegionAccess:  snapshot.Access,
+		ActiveGrants:       snapshot.ActiveGrants,
+	}
+	if report.Regions == nil {
+		report.Regions = []repository.RegionCounts{}
+	}
+	if report.Misplaced == nil {
+		report.Misplaced = []repository.Misplacement{}
+	}
+	if report.CrossRegionAccess == nil {
+		report.CrossRegionAccess = []repository.AccessCounts{}
+	}
+
+	var misplacedEvidence, misplacedExports int64
+	for _, counts := range snapshot.Regions {
+		misplacedEvidence += counts.EvidenceMisplaced
+		misplacedExports += counts.ExportsMisplaced
+		if !policy.Known(counts.Region) {
+			report.Issues = append(report.Issues,
+				fmt.Sprintf("%d cases are pinned to region %s, which has no configured storage", counts.Cases, counts.Region))
+		}
+	}
+	for _, access := range snapshot.Access {
+		if access.Decision == models.ResidencyDecisionDenied {
+			report.DeniedRequests += access.Requests
+		}
+	}
+
+	if misplacedEvidence > 0 {
+		report.Issues = append(report.Issues,
+			fmt.Sprintf("%d evidence files are stored outside their case's region", misplacedEvidence))
+	}
+	if misplacedExports > 0 {
+		report.Issues = append(report.Issues,
+			fmt.Sprintf("%d exports are stored outside their case's region", misplacedExports))
+	}
+	if snapshot.UntaggedCases > 0 {
+		report.Issues = append(report.Issues,
+			fmt.Sprintf("%d cases are not pinned to a region", snapshot.UntaggedCases))
+	}
+	if snapshot.UnverifiedEvidence > 0 {
+		report.Issues = append(report.Issues,
+			fmt.Sprintf("%d evidence files have no recorded storage region", snapshot.UnverifiedEvidence))
+	}
+
+	report.Compliant = len(report.Issues) == 0
+	return report
+}
@@ -0,0 +1,44 @@
+package residency
+
+import (
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"investigation-toolkit/internal/config"
+)
+
+// Router pins evidence files and exports to the storage of a case's region
+type Router struct {
+	evidence map[string]string
+	exports  map[string]string
+}
+
+// NewRouter creates a storage router from the configured region locations
+func NewRouter(cfg config.ResidencyConfig) *Router {
+	return &Router{
+		evidence: cfg.EvidenceLocations,
+		exports:  cfg.ExportLocations,
+	}
+}
+
+// EvidenceDir returns the directory an evidence item's files are written to
+func (r *Router) EvidenceDir(region string, investigationID, evidenceID uuid.UUID) (string, error) {
+	root, ok := r.evidence[region]
+	if !ok || root == "" {
+		return "", errors.Wrap(ErrUnknownRegion, region)
+	}
+
+	return filepath.Join(root, investigationID.String(), evidenceID.String()), nil
+}
+
+// ExportPath returns the file a case export is written to
+func (r *Router) ExportPath(region string, investigationID, exportID uuid.UUID) (string, error) {
+	root, ok := r.exports[region]
+	if !ok || root == "" {
+		return "", errors.Wrap(ErrUnknownRegion, region)
+	}
+
+	return filepath.Join(root, investigationID.String(), exportID.String()+".json"), nil
+}
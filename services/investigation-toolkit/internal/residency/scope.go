@@ -0,0 +1,55 @@
+package residency
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ScopeKind is the kind of case data an API path addresses
+type ScopeKind string
+
+const (
+	ScopeNone      ScopeKind = ""
+	ScopeCase      ScopeKind = "case"
+	ScopeEvidence  ScopeKind = "evidence"
+	ScopeSARFiling ScopeKind = "sar_filing"
+)
+
+// Scope identifies the case, evidence item or SAR filing a request reads or
+// changes
+type Scope struct {
+	Kind ScopeKind
+	ID   uuid.UUID
+}
+
+// ScopeOf returns the case data an /api/v1 path addresses. The first ID
+// following an investigation, evidence or SAR filing segment wins, which
+// covers nested routes such as /timeline/investigation/{id} and
+// /audit/custody/evidence/{id}. Paths that address no single case, like
+// lists and user dashboards, have no scope.
+func ScopeOf(path string) Scope {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/v1"), "/"), "/")
+
+	for i := 0; i+1 < len(segments); i++ {
+		var kind ScopeKind
+		switch segments[i] {
+		case "investigations", "investigation":
+			kind = ScopeCase
+		case "evidence":
+			kind = ScopeEvidence
+		case "sar-filings":
+			kind = ScopeSARFiling
+		default:
+			continue
+		}
+
+		id, err := uuid.Parse(segments[i+1])
+		if err != nil {
+			continue
+		}
+		return Scope{Kind: kind, ID: id}
+	}
+
+	return Scope{}
+}
@@ -0,0 +1,389 @@
+package residency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+)
+
+var (
+	// ErrInvalidGrant is returned for grants that could not authorize anything
+	ErrInvalidGrant = errors.New("invalid residency grant")
+	// ErrNotHomeRegion is returned when a case's residency is managed from
+	// outside the region it is pinned to
+	ErrNotHomeRegion = errors.New("case residency can only be managed from its home region")
+)
+
+// Store persists case regions, residency grants, cross-region requests and exports
+type Store interface {
+	GetCaseRegion(ctx context.Context, investigationID uuid.UUID) (*string, error)
+	GetEvidenceCase(ctx context.Context, evidenceID uuid.UUID) (uuid.UUID, error)
+	GetSARFilingCase(ctx context.Context, filingID uuid.UUID) (uuid.UUID, error)
+	TagCase(ctx context.Context, investigationID uuid.UUID, region string) error
+	SetEvidenceRegion(ctx context.Context, evidenceID uuid.UUID, region string) error
+	ListCaseEvidence(ctx context.Context, investigationID uuid.UUID) ([]models.Evidence, error)
+	CreateGrant(ctx context.Context, grant *models.ResidencyGrant) error
+	GetGrant(ctx context.Context, id uuid.UUID) (*models.ResidencyGrant, error)
+	ActiveGrant(ctx context.Context, investigationID uuid.UUID, region string, userID uuid.UUID) (*models.ResidencyGrant, error)
+	ListGrants(ctx context.Context, investigationID uuid.UUID) ([]models.ResidencyGrant, error)
+	RevokeGrant(ctx context.Context, id, revokedBy uuid.UUID) error
+	LogAccess(ctx context.Context, access *models.ResidencyAccess) error
+	CreateExport(ctx context.Context, export *models.CaseExport) error
+	ListExports(ctx context.Context, investigationID uuid.UUID) ([]models.CaseExport, error)
+	GetResidencySnapshot(ctx context.Context, since time.Time, misplacedLimit int) (*repository.ResidencySnapshot, error)
+}
+
+// Cases loads the investigations that are exported
+type Cases interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Investigation, error)
+}
+
+// Service enforces per-case data residency: it checks requests against the
+// region a case is pinned to, routes the case's files to that region's
+// storage and reports on compliance
+type Service struct {
+	store  Store
+	cases  Cases
+	policy Policy
+	router *Router
+	config config.ResidencyConfig
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewService creates a new residency service
+func NewService(store Store, cases Cases, cfg config.ResidencyConfig, logger *zap.Logger) *Service {
+	return &Service{
+		store:  store,
+		cases:  cases,
+		policy: NewPolicy(cfg),
+		router: NewRouter(cfg),
+		config: cfg,
+		logger: logger.Named("residency"),
+		now:    time.Now,
+	}
+}
+
+// Policy returns the residency policy of this deployment
+func (s *Service) Policy() Policy {
+	return s.policy
+}
+
+// Check decides whether this deployment may serve a request for scoped case
+// data. Cross-region requests are recorded whether or not they are allowed.
+// userID may be nil for anonymous requests, which no grant covers.
+func (s *Service) Check(ctx context.Context, scope Scope, userID *uuid.UUID, method, path string) (*Decision, error) {
+	investigationID, err := s.caseOf(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	region, err := s.store.GetCaseRegion(ctx, investigationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var grant *models.ResidencyGrant
+	if region != nil && *region != s.policy.LocalRegion && userID != nil {
+		grant, err = s.store.ActiveGrant(ctx, investigationID, s.policy.LocalRegion, *userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	decision := s.policy.Decide(region, grant, s.now())
+	if decision.CrossRegion {
+		access := &models.ResidencyAccess{
+			InvestigationID: investigationID,
+			CaseRegion:      decision.CaseRegion,
+			RequestRegion:   s.policy.LocalRegion,
+			UserID:          userID,
+			Method:          method,
+			Path:            path,
+			Decision:        models.ResidencyDecisionDenied,
+			GrantID:         decision.GrantID,
+		}
+		if decision.Allowed {
+			access.Decision = models.ResidencyDecisionAllowed
+		}
+		if err := s.store.LogAccess(ctx, access); err != nil {
+			s.logger.Error("Failed to record cross-region access",
+				zap.String("investigation_id", investigationID.String()),
+				zap.Error(err))
+		}
+	}
+
+	return &decision, nil
+}
+
+func (s *Service) caseOf(ctx context.Context, scope Scope) (uuid.UUID, error) {
+	switch scope.Kind {
+	case ScopeCase:
+		return scope.ID, nil
+	case ScopeEvidence:
+		return s.store.GetEvidenceCase(ctx, scope.ID)
+	case ScopeSARFiling:
+		return s.store.GetSARFilingCase(ctx, scope.ID)
+	default:
+		return uuid.Nil, errors.Errorf("unsupported residency scope %q", scope.Kind)
+	}
+}
+
+// TagCase pins a case created before residency controls to a region
+func (s *Service) TagCase(ctx context.Context, investigationID uuid.UUID, region string) error {
+	if !s.policy.Known(region) {
+		return errors.Wrap(ErrUnknownRegion, region)
+	}
+
+	return s.store.TagCase(ctx, investigationID, region)
+}
+
+// EvidenceDir returns the directory in the case's region that an evidence
+// item's files are written to, and that region. Files of untagged cases go
+// to the default region.
+func (s *Service) EvidenceDir(ctx context.Context, investigationID, evidenceID uuid.UUID) (string, string, error) {
+	region, err := s.storageRegion(ctx, investigationID)
+	if err != nil {
+		return "", "", err
+	}
+
+	dir, err := s.router.EvidenceDir(region, investigationID, evidenceID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return dir, region, nil
+}
+
+// RecordEvidenceRegion records where an evidence file was stored
+func (s *Service) RecordEvidenceRegion(ctx context.Context, evidenceID uuid.UUID, region string) error {
+	return s.store.SetEvidenceRegion(ctx, evidenceID, region)
+}
+
+// Grant authorizes a user in another region to access a case until the
+// grant expires. Grants are made from the case's home region only, so a
+// grant holder cannot extend their own access.
+func (s *Service) Grant(ctx context.Context, investigationID uuid.UUID, req *models.CreateResidencyGrantRequest, grantedBy uuid.UUID) (*models.ResidencyGrant, error) {
+	if !s.policy.Known(req.Region) {
+		return nil, errors.Wrap(ErrUnknownRegion, req.Region)
+	}
+	if req.Reason == "" {
+		return nil, errors.Wrap(ErrInvalidGrant, "a reason is required")
+	}
+
+	duration := time.Duration(req.ExpiresInHours) * time.Hour
+	if duration <= 0 || duration > s.config.MaxGrantDuration {
+		return nil, errors.Wrapf(ErrInvalidGrant, "grants must expire within %s", s.config.MaxGrantDuration)
+	}
+
+	region, err := s.store.GetCaseRegion(ctx, investigationID)
+	if err != nil {
+		return nil, err
+	}
+	if region == nil || *region != s.policy.LocalRegion {
+		return nil, ErrNotHomeRegion
+	}
+	if req.Region == *region {
+		return nil, errors.Wrap(ErrInvalidGrant, "the case is already pinned to that region")
+	}
+
+	now := s.now()
+	grant := &models.ResidencyGrant{
+		ID:              uuid.New(),
+		InvestigationID: investigationID,
+		Region:          req.Region,
+		GrantedTo:       req.GrantedTo,
+		Reason:          req.Reason,
+		GrantedBy:       grantedBy,
+		ExpiresAt:       now.Add(duration),
+		CreatedAt:       now,
+	}
+	if err := s.store.CreateGrant(ctx, grant); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Residency grant created",
+		zap.String("grant_id", grant.ID.String()),
+		zap.String("investigation_id", investigationID.String()),
+		zap.String("region", grant.Region),
+		zap.String("granted_to", grant.GrantedTo.String()),
+		zap.Time("expires_at", grant.ExpiresAt))
+
+	return grant, nil
+}
+
+// Grants lists the grants made on a case
+func (s *Service) Grants(ctx context.Context, investigationID uuid.UUID) ([]models.ResidencyGrant, error) {
+	grants, err := s.store.ListGrants(ctx, investigationID)
+	if err != nil {
+		return nil, err
+	}
+	if grants == nil {
+		grants = []models.ResidencyGrant{}
+	}
+	return grants, nil
+}
+
+// Revoke ends a grant before it expires. Like creating one, it is done from
+// the case's home region.
+func (s *Service) Revoke(ctx context.Context, grantID, revokedBy uuid.UUID) (*models.ResidencyGrant, error) {
+	grant, err := s.store.GetGrant(ctx, grantID)
+	if err != nil {
+		return nil, err
+	}
+
+	region, err := s.store.GetCaseRegion(ctx, grant.InvestigationID)
+	if err != nil {
+		return nil, err
+	}
+	if region == nil || *region != s.policy.LocalRegion {
+		return nil, ErrNotHomeRegion
+	}
+
+	if err := s.store.RevokeGrant(ctx, grantID, revokedBy); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	grant.RevokedAt = &now
+	grant.RevokedBy = &revokedBy
+
+	s.logger.Info("Residency grant revoked",
+		zap.String("grant_id", grantID.String()),
+		zap.String("revoked_by", revokedBy.String()))
+
+	return grant, nil
+}
+
+// caseExportBundle is the document written for a case export
+type caseExportBundle struct {
+	ExportID      uuid.UUID             `json:"export_id"`
+	Region        string                `json:"region"`
+	ExportedAt    time.Time             `json:"exported_at"`
+	ExportedBy    uuid.UUID             `json:"exported_by"`
+	Investigation *models.Investigation `json:"investigation"`
+	Evidence      []models.Evidence     `json:"evidence"`
+}
+
+// Export writes a case and its evidence records to the export storage of the
+// case's region
+func (s *Service) Export(ctx context.Context, investigationID, exportedBy uuid.UUID) (*models.CaseExport, error) {
+	region, err := s.storageRegion(ctx, investigationID)
+	if err != nil {
+		return nil, err
+	}
+
+	investigation, err := s.cases.GetByID(ctx, investigationID)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := s.store.ListCaseEvidence(ctx, investigationID)
+	if err != nil {
+		return nil, err
+	}
+	if evidence == nil {
+		evidence = []models.Evidence{}
+	}
+
+	now := s.now()
+	export := &models.CaseExport{
+		ID:              uuid.New(),
+		InvestigationID: investigationID,
+		Region:          region,
+		ExportedBy:      exportedBy,
+		CreatedAt:       now,
+	}
+
+	path, err := s.router.ExportPath(region, investigationID, export.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := json.MarshalIndent(caseExportBundle{
+		ExportID:      export.ID,
+		Region:        region,
+		ExportedAt:    now,
+		ExportedBy:    exportedBy,
+		Investigation: investigation,
+		Evidence:      evidence,
+	}, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode case export")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, errors.Wrap(err, "failed to create export directory")
+	}
+	if err := os.WriteFile(path, content, 0640); err != nil {
+		return nil, errors.Wrap(err, "failed to write case export")
+	}
+
+	sum := sha256.Sum256(content)
+	size := int64(len(content))
+	hash := hex.EncodeToString(sum[:])
+	export.FilePath = path
+	export.FileSize = &size
+	export.FileHash = &hash
+
+	if err := s.store.CreateExport(ctx, export); err != nil {
+		if removeErr := os.Remove(path); removeErr != nil {
+			s.logger.Warn("Failed to remove unrecorded case export", zap.String("path", path), zap.Error(removeErr))
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Case exported",
+		zap.String("export_id", export.ID.String()),
+		zap.String("investigation_id", investigationID.String()),
+		zap.String("region", region))
+
+	return export, nil
+}
+
+// Exports lists the exports of a case
+func (s *Service) Exports(ctx context.Context, investigationID uuid.UUID) ([]models.CaseExport, error) {
+	exports, err := s.store.ListExports(ctx, investigationID)
+	if err != nil {
+		return nil, err
+	}
+	if exports == nil {
+		exports = []models.CaseExport{}
+	}
+	return exports, nil
+}
+
+// Report builds a residency compliance report covering cross-region requests
+// since the given time
+func (s *Service) Report(ctx context.Context, since time.Time) (*Report, error) {
+	snapshot, err := s.store.GetResidencySnapshot(ctx, since, s.config.ReportViolationLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildReport(snapshot, s.policy, since, s.now()), nil
+}
+
+// storageRegion returns the region a case's files are stored in
+func (s *Service) storageRegion(ctx context.Context, investigationID uuid.UUID) (string, error) {
+	region, err := s.store.GetCaseRegion(ctx, investigationID)
+	if err != nil {
+		return "", err
+	}
+	if region == nil {
+		return s.policy.DefaultRegion, nil
+	}
+	return *region, nil
+}
@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Result holds the outcome of a malware scan
+type Result struct {
+	Clean     bool   `json:"clean"`
+	Signature string `json:"signature,omitempty"`
+	Raw       string `json:"raw"`
+}
+
+// Scanner scans file content for malware
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+// ClamAVScanner scans content using a clamd daemon over the INSTREAM protocol
+type ClamAVScanner struct {
+	address   string
+	timeout   time.Duration
+	chunkSize int
+}
+
+// NewClamAVScanner creates a new clamd scanner
+func NewClamAVScanner(address string, timeout time.Duration) *ClamAVScanner {
+	return &ClamAVScanner{
+		address:   address,
+		timeout:   timeout,
+		chunkSize: 64 * 1024,
+	}
+}
+
+// Scan streams the content to clamd and parses the verdict
+func (s *ClamAVScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to clamd")
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, errors.Wrap(err, "failed to set clamd deadline")
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, errors.Wrap(err, "failed to start clamd stream")
+	}
+
+	buf := make([]byte, s.chunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return nil, errors.Wrap(err, "failed to write chunk size to clamd")
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return nil, errors.Wrap(err, "failed to write chunk to clamd")
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, errors.Wrap(readErr, "failed to read content for scanning")
+		}
+	}
+
+	// A zero-length chunk terminates the stream
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return nil, errors.Wrap(err, "failed to terminate clamd stream")
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to read clamd reply")
+	}
+
+	return ParseReply(reply)
+}
+
+// ParseReply parses a clamd INSTREAM reply such as "stream: OK" or
+// "stream: Eicar-Test-Signature FOUND"
+func ParseReply(reply string) (*Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	result := &Result{Raw: reply}
+
+	verdict := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case verdict == "OK":
+		result.Clean = true
+	case strings.HasSuffix(verdict, "FOUND"):
+		result.Signature = strings.TrimSpace(strings.TrimSuffix(verdict, "FOUND"))
+	default:
+		return nil, errors.Errorf("unexpected clamd reply: %s", reply)
+	}
+
+	return result, nil
+}
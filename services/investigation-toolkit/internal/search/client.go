@@ -0,0 +1,91 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"investigation-toolkit/internal/config"
+)
+
+// ErrNotFound is returned when Elasticsearch responds 404
+var ErrNotFound = errors.New("search resource not found")
+
+// Client is a minimal Elasticsearch REST client covering the index, alias,
+// reindex, task and lifecycle APIs the toolkit uses
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the first configured Elasticsearch address
+func NewClient(cfg config.SearchConfig) *Client {
+	return &Client{
+		baseURL:  strings.TrimRight(cfg.Addresses[0], "/"),
+		username: cfg.Username,
+		password: cfg.Password,
+		apiKey:   cfg.APIKey,
+		httpClient: &http.Client{
+			Timeout: cfg.RequestTimeout,
+			Transport: &http.Transport{
+				MaxIdleConnsPerHost:   cfg.MaxIdleConnections,
+				ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+			},
+		},
+	}
+}
+
+// do sends a JSON request and decodes the JSON response into out when out is not nil
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode search request")
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return errors.Wrap(err, "failed to build search request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch {
+	case c.apiKey != "":
+		req.Header.Set("Authorization", "ApiKey "+c.apiKey)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "search request %s %s failed", method, path)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("search request %s %s returned %d: %s", method, path, resp.StatusCode, detail)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.Wrap(err, "failed to decode search response")
+	}
+	return nil
+}
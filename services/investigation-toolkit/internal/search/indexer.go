@@ -0,0 +1,107 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+)
+
+// ActiveJobLister lists reindex jobs whose target index receives dual writes
+type ActiveJobLister interface {
+	ListActive(ctx context.Context) ([]models.SearchReindexJob, error)
+}
+
+// Indexer writes documents to search indices. While an index is being
+// rebuilt, every write is repeated against the new version so documents
+// changed during the backfill are not lost.
+type Indexer struct {
+	client  *Client
+	jobs    ActiveJobLister
+	naming  Naming
+	refresh time.Duration
+	logger  *zap.Logger
+
+	mu          sync.Mutex
+	rebuilding  map[string]bool // index names with an active reindex
+	refreshedAt time.Time
+}
+
+// NewIndexer creates a new search indexer
+func NewIndexer(client *Client, jobs ActiveJobLister, cfg config.SearchConfig, logger *zap.Logger) *Indexer {
+	return &Indexer{
+		client:  client,
+		jobs:    jobs,
+		naming:  Naming{Prefix: cfg.IndexPrefix},
+		refresh: cfg.ReindexStateRefresh,
+		logger:  logger.Named("search_indexer"),
+	}
+}
+
+// Index creates or replaces a document
+func (i *Indexer) Index(ctx context.Context, index, id string, document interface{}) error {
+	return i.write(ctx, http.MethodPut, index, id, document)
+}
+
+// Delete removes a document. A document deleted while the backfill is
+// copying its index may be copied back into the new version; deleting it
+// again after the reindex completes removes it for good.
+func (i *Indexer) Delete(ctx context.Context, index, id string) error {
+	return i.write(ctx, http.MethodDelete, index, id, nil)
+}
+
+func (i *Indexer) write(ctx context.Context, method, index, id string, document interface{}) error {
+	rebuilding, err := i.isRebuilding(ctx, index)
+	if err != nil {
+		return err
+	}
+
+	aliases := []string{i.naming.Alias(index)}
+	if rebuilding {
+		aliases = append(aliases, i.naming.Next(index))
+	}
+
+	for n, alias := range aliases {
+		// require_alias stops a write from creating a concrete index when the
+		// alias is missing, e.g. after a cancelled reindex dropped its target
+		path := "/" + alias + "/_doc/" + url.PathEscape(id) + "?require_alias=true"
+		err := i.client.do(ctx, method, path, document, nil)
+		if err == ErrNotFound && (n > 0 || method == http.MethodDelete) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to write document %s to %s", id, alias)
+		}
+	}
+
+	return nil
+}
+
+// isRebuilding reports whether index has an active reindex, reloading the
+// active jobs at most once per refresh interval
+func (i *Indexer) isRebuilding(ctx context.Context, index string) (bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.rebuilding == nil || time.Since(i.refreshedAt) >= i.refresh {
+		jobs, err := i.jobs.ListActive(ctx)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to load active reindex jobs")
+		}
+
+		i.rebuilding = make(map[string]bool, len(jobs))
+		for _, job := range jobs {
+			i.rebuilding[job.IndexName] = true
+		}
+		i.refreshedAt = time.Now()
+	}
+
+	return i.rebuilding[index], nil
+}
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Search indices
+const (
+	IndexInvestigations = "investigations"
+	IndexEvidence       = "evidence"
+	IndexTimeline       = "timeline"
+)
+
+// IndexSpec describes one logical search index. Readers query an alias named
+// after the index; the alias points at a versioned physical index so the
+// mappings can change without downtime.
+type IndexSpec struct {
+	Name     string                 `json:"name"`
+	Mappings map[string]interface{} `json:"mappings"`
+}
+
+// DefaultIndices returns the indices the toolkit maintains. Fields outside
+// the mappings are kept in the source but not indexed.
+func DefaultIndices() []IndexSpec {
+	keyword := map[string]interface{}{"type": "keyword"}
+	text := map[string]interface{}{"type": "text"}
+	date := map[string]interface{}{"type": "date"}
+
+	return []IndexSpec{
+		{
+			Name: IndexInvestigations,
+			Mappings: map[string]interface{}{
+				"dynamic": false,
+				"properties": map[string]interface{}{
+					"id":          keyword,
+					"title":       map[string]interface{}{"type": "text", "fields": map[string]interface{}{"raw": keyword}},
+					"description": text,
+					"status":      keyword,
+					"priority":    keyword,
+					"case_type":   keyword,
+					"assigned_to": keyword,
+					"tags":        keyword,
+					"created_at":  date,
+					"updated_at":  date,
+				},
+			},
+		},
+		{
+			Name: IndexEvidence,
+			Mappings: map[string]interface{}{
+				"dynamic": false,
+				"properties": map[string]interface{}{
+					"id":               keyword,
+					"investigation_id": keyword,
+					"name":             text,
+					"description":      text,
+					"evidence_type":    keyword,
+					"status":           keyword,
+					"tags":             keyword,
+					"collected_at":     date,
+					"created_at":       date,
+					"updated_at":       date,
+				},
+			},
+		},
+		{
+			Name: IndexTimeline,
+			Mappings: map[string]interface{}{
+				"dynamic": false,
+				"properties": map[string]interface{}{
+					"id":               keyword,
+					"investigation_id": keyword,
+					"event_type":       keyword,
+					"title":            text,
+					"description":      text,
+					"event_date":       date,
+					"tags":             keyword,
+					"created_at":       date,
+				},
+			},
+		},
+	}
+}
+
+// Naming derives alias and versioned index names from the configured prefix
+type Naming struct {
+	Prefix string
+}
+
+// Alias returns the alias readers and writers use for an index
+func (n Naming) Alias(index string) string {
+	return fmt.Sprintf("%s-%s", n.Prefix, index)
+}
+
+// Versioned returns the physical index name of one version of an index
+func (n Naming) Versioned(index string, version int) string {
+	return fmt.Sprintf("%s-%s-v%d", n.Prefix, index, version)
+}
+
+// Version parses the version of a physical index name, returning false for
+// names that are not versions of index
+func (n Naming) Version(index, physical string) (int, bool) {
+	suffix, ok := strings.CutPrefix(physical, n.Alias(index)+"-v")
+	if !ok {
+		return 0, false
+	}
+	version, err := strconv.Atoi(suffix)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+// Next returns the alias of the index version being built by a reindex.
+// Dual writes go through it, so they stop as soon as the job's target index
+// is deleted instead of recreating it.
+func (n Naming) Next(index string) string {
+	return n.Alias(index) + "-next"
+}
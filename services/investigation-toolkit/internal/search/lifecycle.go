@@ -0,0 +1,77 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"investigation-toolkit/internal/config"
+)
+
+// RetiredPolicy returns the name of the lifecycle policy for retired index versions
+func (n Naming) RetiredPolicy() string {
+	return n.Prefix + "-retired"
+}
+
+// LifecyclePolicy builds the ILM policy attached to an index version when a
+// reindex replaces it. Phase ages count from retirement: the origination date
+// is set when the policy is attached. Elasticsearch migrates the index to the
+// warm and cold data tiers as it enters each phase.
+func LifecyclePolicy(cfg config.SearchConfig) map[string]interface{} {
+	phases := map[string]interface{}{
+		"warm": map[string]interface{}{
+			"min_age": minAge(cfg.RetiredWarmAfter),
+			"actions": map[string]interface{}{
+				"readonly":     map[string]interface{}{},
+				"forcemerge":   map[string]interface{}{"max_num_segments": 1},
+				"set_priority": map[string]interface{}{"priority": 50},
+			},
+		},
+		"cold": map[string]interface{}{
+			"min_age": minAge(cfg.RetiredColdAfter),
+			"actions": map[string]interface{}{
+				"set_priority": map[string]interface{}{"priority": 0},
+			},
+		},
+	}
+	if cfg.RetiredDeleteAfter > 0 {
+		phases["delete"] = map[string]interface{}{
+			"min_age": minAge(cfg.RetiredDeleteAfter),
+			"actions": map[string]interface{}{"delete": map[string]interface{}{}},
+		}
+	}
+
+	return map[string]interface{}{
+		"policy": map[string]interface{}{"phases": phases},
+	}
+}
+
+func minAge(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(d/time.Second))
+}
+
+// EnsureLifecyclePolicy creates or updates the retired index lifecycle policy
+func (o *Orchestrator) EnsureLifecyclePolicy(ctx context.Context) error {
+	path := "/_ilm/policy/" + o.naming.RetiredPolicy()
+	if err := o.client.do(ctx, http.MethodPut, path, LifecyclePolicy(o.config), nil); err != nil {
+		return errors.Wrap(err, "failed to put retired index lifecycle policy")
+	}
+	return nil
+}
+
+// retire blocks writes to a replaced index version and hands it to the
+// retired lifecycle policy
+func (o *Orchestrator) retire(ctx context.Context, index string, now time.Time) error {
+	settings := map[string]interface{}{
+		"index.blocks.write":               true,
+		"index.lifecycle.name":             o.naming.RetiredPolicy(),
+		"index.lifecycle.origination_date": now.UnixMilli(),
+	}
+	if err := o.client.do(ctx, http.MethodPut, "/"+index+"/_settings", settings, nil); err != nil {
+		return errors.Wrapf(err, "failed to retire index %s", index)
+	}
+	return nil
+}
@@ -0,0 +1,436 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+)
+
+var (
+	// ErrUnknownIndex is returned for an index the toolkit does not maintain
+	ErrUnknownIndex = errors.New("unknown search index")
+	// ErrReindexNotActive is returned when cancelling a job that has finished
+	ErrReindexNotActive = errors.New("reindex job is not active")
+)
+
+// IndexStatus describes which index version currently serves an index
+type IndexStatus struct {
+	Name         string `json:"name"`
+	Alias        string `json:"alias"`
+	CurrentIndex string `json:"current_index"`
+	Version      int    `json:"version"`
+	Documents    int64  `json:"documents"`
+}
+
+// Orchestrator rebuilds search indices without downtime. A reindex creates
+// the next index version, has writers dual-write into it, backfills it from
+// the current version and then atomically swaps the alias. The replaced
+// version is retired to the warm and cold tiers by a lifecycle policy.
+type Orchestrator struct {
+	client *Client
+	repo   *repository.SearchReindexRepository
+	naming Naming
+	specs  map[string]IndexSpec
+	config config.SearchConfig
+	logger *zap.Logger
+}
+
+// NewOrchestrator creates a new reindex orchestrator for the default indices
+func NewOrchestrator(client *Client, repo *repository.SearchReindexRepository, cfg config.SearchConfig, logger *zap.Logger) *Orchestrator {
+	specs := make(map[string]IndexSpec)
+	for _, spec := range DefaultIndices() {
+		specs[spec.Name] = spec
+	}
+
+	return &Orchestrator{
+		client: client,
+		repo:   repo,
+		naming: Naming{Prefix: cfg.IndexPrefix},
+		specs:  specs,
+		config: cfg,
+		logger: logger.Named("search_reindex"),
+	}
+}
+
+// EnsureIndices creates version 1 of every index that has no alias yet
+func (o *Orchestrator) EnsureIndices(ctx context.Context) error {
+	for _, name := range o.indexNames() {
+		if _, err := o.currentIndex(ctx, name); err == nil {
+			continue
+		} else if err != ErrNotFound {
+			return err
+		}
+
+		index := o.naming.Versioned(name, 1)
+		if err := o.createIndex(ctx, o.specs[name], index, o.naming.Alias(name)); err != nil {
+			return err
+		}
+		o.logger.Info("Created search index", zap.String("index", index))
+	}
+
+	return nil
+}
+
+// Status reports the serving version and document count of every index
+func (o *Orchestrator) Status(ctx context.Context) ([]IndexStatus, error) {
+	var statuses []IndexStatus
+
+	for _, name := range o.indexNames() {
+		status := IndexStatus{Name: name, Alias: o.naming.Alias(name)}
+
+		current, err := o.currentIndex(ctx, name)
+		if err != nil && err != ErrNotFound {
+			return nil, err
+		}
+		if err == nil {
+			status.CurrentIndex = current
+			status.Version, _ = o.naming.Version(name, current)
+
+			var count struct {
+				Count int64 `json:"count"`
+			}
+			if err := o.client.do(ctx, http.MethodGet, "/"+status.Alias+"/_count", nil, &count); err != nil {
+				return nil, errors.Wrapf(err, "failed to count documents in %s", status.Alias)
+			}
+			status.Documents = count.Count
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// StartReindex creates the next version of an index with the current
+// mappings and starts dual-writing into it. The backfill starts once every
+// writer has had time to pick up the new job.
+func (o *Orchestrator) StartReindex(ctx context.Context, name string, requestedBy *uuid.UUID) (*models.SearchReindexJob, error) {
+	spec, ok := o.specs[name]
+	if !ok {
+		return nil, ErrUnknownIndex
+	}
+
+	current, err := o.currentIndex(ctx, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve current version of %s", name)
+	}
+	version, ok := o.naming.Version(name, current)
+	if !ok {
+		return nil, fmt.Errorf("alias %s points at unversioned index %s", o.naming.Alias(name), current)
+	}
+
+	job := &models.SearchReindexJob{
+		IndexName:   name,
+		SourceIndex: current,
+		TargetIndex: o.naming.Versioned(name, version+1),
+		RequestedBy: requestedBy,
+	}
+
+	if err := o.createIndex(ctx, spec, job.TargetIndex, o.naming.Next(name)); err != nil {
+		return nil, err
+	}
+	if err := o.repo.Create(ctx, job); err != nil {
+		o.dropTarget(ctx, job)
+		return nil, err
+	}
+
+	o.logger.Info("Search reindex started",
+		zap.String("job_id", job.ID.String()),
+		zap.String("source", job.SourceIndex),
+		zap.String("target", job.TargetIndex))
+
+	return job, nil
+}
+
+// Cancel stops an active reindex and deletes its target index
+func (o *Orchestrator) Cancel(ctx context.Context, id uuid.UUID) (*models.SearchReindexJob, error) {
+	job, err := o.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	finished, err := o.repo.Finish(ctx, id, models.ReindexStatusCancelled, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !finished {
+		return nil, ErrReindexNotActive
+	}
+
+	o.stopBackfill(ctx, job)
+	o.dropTarget(ctx, job)
+	o.logger.Info("Search reindex cancelled", zap.String("job_id", id.String()))
+
+	return o.repo.GetByID(ctx, id)
+}
+
+// Run advances active reindex jobs until the context is cancelled
+func (o *Orchestrator) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.config.ReindexPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := o.Advance(ctx); err != nil {
+				o.logger.Error("Failed to advance search reindex jobs", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Advance moves every active job forward by at most one phase
+func (o *Orchestrator) Advance(ctx context.Context) error {
+	jobs, err := o.repo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range jobs {
+		job := &jobs[i]
+		if err := o.advance(ctx, job, now); err != nil {
+			o.fail(ctx, job, err)
+		}
+	}
+
+	return nil
+}
+
+func (o *Orchestrator) advance(ctx context.Context, job *models.SearchReindexJob, now time.Time) error {
+	switch job.Status {
+	case models.ReindexStatusDualWriting:
+		// Writers reload active jobs every refresh interval; wait for all of
+		// them before snapshotting the source so no write is missed
+		if now.Sub(job.CreatedAt) < 2*o.config.ReindexStateRefresh {
+			return nil
+		}
+		taskID, err := o.startBackfill(ctx, job)
+		if err != nil {
+			return err
+		}
+		o.logger.Info("Search reindex backfill started",
+			zap.String("job_id", job.ID.String()), zap.String("task_id", taskID))
+		return o.repo.StartBackfill(ctx, job.ID, taskID)
+
+	case models.ReindexStatusBackfilling:
+		if job.TaskID == nil {
+			return errors.New("backfill task id missing")
+		}
+		task, err := o.backfillTask(ctx, *job.TaskID)
+		if err != nil {
+			return err
+		}
+		if !task.Completed {
+			return o.repo.UpdateProgress(ctx, job.ID, task.Task.Status.Total, task.Task.Status.copied())
+		}
+		if task.Error != nil {
+			return fmt.Errorf("backfill failed: %s", task.Error.Reason)
+		}
+		if len(task.Response.Failures) > 0 {
+			return fmt.Errorf("backfill failed for %d documents", len(task.Response.Failures))
+		}
+		if err := o.repo.UpdateProgress(ctx, job.ID, task.Response.Total, task.Response.copied()); err != nil {
+			return err
+		}
+		return o.swap(ctx, job, now)
+	}
+
+	return nil
+}
+
+// swap points the alias at the target index in one atomic alias update and
+// retires the source index
+func (o *Orchestrator) swap(ctx context.Context, job *models.SearchReindexJob, now time.Time) error {
+	if err := o.client.do(ctx, http.MethodPost, "/"+job.TargetIndex+"/_refresh", nil, nil); err != nil {
+		return errors.Wrapf(err, "failed to refresh %s", job.TargetIndex)
+	}
+
+	alias := o.naming.Alias(job.IndexName)
+	actions := map[string]interface{}{
+		"actions": []interface{}{
+			map[string]interface{}{"remove": map[string]interface{}{"index": job.SourceIndex, "alias": alias}},
+			map[string]interface{}{"add": map[string]interface{}{"index": job.TargetIndex, "alias": alias}},
+			map[string]interface{}{"remove": map[string]interface{}{"index": job.TargetIndex, "alias": o.naming.Next(job.IndexName)}},
+		},
+	}
+	if err := o.client.do(ctx, http.MethodPost, "/_aliases", actions, nil); err != nil {
+		return errors.Wrapf(err, "failed to swap alias %s", alias)
+	}
+
+	finished, err := o.repo.Finish(ctx, job.ID, models.ReindexStatusCompleted, nil)
+	if err != nil {
+		return err
+	}
+	if !finished {
+		o.logger.Warn("Search reindex was cancelled during the alias swap; the new index is serving",
+			zap.String("job_id", job.ID.String()), zap.String("index", job.TargetIndex))
+		return nil
+	}
+
+	if err := o.retire(ctx, job.SourceIndex, now); err != nil {
+		o.logger.Error("Failed to retire replaced search index",
+			zap.String("index", job.SourceIndex), zap.Error(err))
+	}
+
+	o.logger.Info("Search reindex completed",
+		zap.String("job_id", job.ID.String()),
+		zap.String("alias", alias),
+		zap.String("index", job.TargetIndex))
+	return nil
+}
+
+// fail records a failed job and deletes its target index
+func (o *Orchestrator) fail(ctx context.Context, job *models.SearchReindexJob, cause error) {
+	o.logger.Error("Search reindex failed", zap.String("job_id", job.ID.String()), zap.Error(cause))
+
+	message := cause.Error()
+	finished, err := o.repo.Finish(ctx, job.ID, models.ReindexStatusFailed, &message)
+	if err != nil {
+		o.logger.Error("Failed to record search reindex failure", zap.String("job_id", job.ID.String()), zap.Error(err))
+		return
+	}
+	if finished {
+		o.stopBackfill(ctx, job)
+		o.dropTarget(ctx, job)
+	}
+}
+
+// backfillTask is the subset of the task API response the orchestrator reads
+type backfillTask struct {
+	Completed bool `json:"completed"`
+	Task      struct {
+		Status reindexCounts `json:"status"`
+	} `json:"task"`
+	Response struct {
+		reindexCounts
+		Failures []interface{} `json:"failures"`
+	} `json:"response"`
+	Error *struct {
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+type reindexCounts struct {
+	Total            int64 `json:"total"`
+	Created          int64 `json:"created"`
+	Updated          int64 `json:"updated"`
+	VersionConflicts int64 `json:"version_conflicts"`
+}
+
+// copied counts documents present in the target. Version conflicts are
+// documents a dual write created first, which the backfill leaves alone.
+func (c reindexCounts) copied() int64 {
+	return c.Created + c.Updated + c.VersionConflicts
+}
+
+// startBackfill starts an asynchronous reindex task. Documents are only
+// created, so copies of documents already dual-written never overwrite them.
+func (o *Orchestrator) startBackfill(ctx context.Context, job *models.SearchReindexJob) (string, error) {
+	body := map[string]interface{}{
+		"conflicts": "proceed",
+		"source":    map[string]interface{}{"index": job.SourceIndex},
+		"dest":      map[string]interface{}{"index": job.TargetIndex, "op_type": "create"},
+	}
+
+	var resp struct {
+		Task string `json:"task"`
+	}
+	if err := o.client.do(ctx, http.MethodPost, "/_reindex?wait_for_completion=false&slices=auto", body, &resp); err != nil {
+		return "", errors.Wrap(err, "failed to start backfill")
+	}
+	if resp.Task == "" {
+		return "", errors.New("reindex did not return a task id")
+	}
+
+	return resp.Task, nil
+}
+
+func (o *Orchestrator) backfillTask(ctx context.Context, taskID string) (*backfillTask, error) {
+	var task backfillTask
+	if err := o.client.do(ctx, http.MethodGet, "/_tasks/"+url.PathEscape(taskID), nil, &task); err != nil {
+		return nil, errors.Wrap(err, "failed to get backfill task")
+	}
+	return &task, nil
+}
+
+func (o *Orchestrator) stopBackfill(ctx context.Context, job *models.SearchReindexJob) {
+	if job.TaskID == nil {
+		return
+	}
+	err := o.client.do(ctx, http.MethodPost, "/_tasks/"+url.PathEscape(*job.TaskID)+"/_cancel", nil, nil)
+	if err != nil && err != ErrNotFound {
+		o.logger.Warn("Failed to cancel backfill task", zap.String("task_id", *job.TaskID), zap.Error(err))
+	}
+}
+
+// dropTarget deletes a job's target index unless it already serves the alias
+func (o *Orchestrator) dropTarget(ctx context.Context, job *models.SearchReindexJob) {
+	if current, err := o.currentIndex(ctx, job.IndexName); err == nil && current == job.TargetIndex {
+		return
+	}
+	err := o.client.do(ctx, http.MethodDelete, "/"+job.TargetIndex, nil, nil)
+	if err != nil && err != ErrNotFound {
+		o.logger.Warn("Failed to delete reindex target", zap.String("index", job.TargetIndex), zap.Error(err))
+	}
+}
+
+// createIndex creates a physical index with the spec's mappings and one alias
+func (o *Orchestrator) createIndex(ctx context.Context, spec IndexSpec, index, alias string) error {
+	body := map[string]interface{}{
+		"mappings": spec.Mappings,
+		"aliases":  map[string]interface{}{alias: map[string]interface{}{}},
+	}
+	if len(o.config.IndexSettings) > 0 {
+		body["settings"] = o.config.IndexSettings
+	}
+
+	if err := o.client.do(ctx, http.MethodPut, "/"+index, body, nil); err != nil {
+		return errors.Wrapf(err, "failed to create index %s", index)
+	}
+	return nil
+}
+
+// currentIndex returns the physical index behind an index's alias
+func (o *Orchestrator) currentIndex(ctx context.Context, name string) (string, error) {
+	alias := o.naming.Alias(name)
+
+	var resp map[string]interface{}
+	if err := o.client.do(ctx, http.MethodGet, "/_alias/"+alias, nil, &resp); err != nil {
+		return "", err
+	}
+
+	indices := make([]string, 0, len(resp))
+	for index := range resp {
+		indices = append(indices, index)
+	}
+	if len(indices) != 1 {
+		sort.Strings(indices)
+		return "", fmt.Errorf("alias %s points at %d indices: %s", alias, len(indices), strings.Join(indices, ", "))
+	}
+
+	return indices[0], nil
+}
+
+func (o *Orchestrator) indexNames() []string {
+	names := make([]string, 0, len(o.specs))
+	for name := range o.specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -12,14 +12,31 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
+	"aegisshield/shared/rbac"
+	"aegisshield/shared/rbac/policyservice"
+	"aegisshield/shared/rbac/userservice"
+
+	"investigation-toolkit/internal/archival"
+	"investigation-toolkit/internal/calendar"
+	"investigation-toolkit/internal/classification"
 	"investigation-toolkit/internal/config"
 	"investigation-toolkit/internal/database"
 	"investigation-toolkit/internal/handlers"
+	"investigation-toolkit/internal/offlinesync"
+	"investigation-toolkit/internal/production"
+	"investigation-toolkit/internal/quota"
+	"investigation-toolkit/internal/questionnaire"
 	"investigation-toolkit/internal/repository"
+	"investigation-toolkit/internal/residency"
+	"investigation-toolkit/internal/scanner"
+	"investigation-toolkit/internal/search"
+	"investigation-toolkit/internal/tiering"
+	"investigation-toolkit/internal/traceability"
 )
 
 // Server represents the investigation toolkit server
@@ -35,6 +52,18 @@ type Server struct {
 	workflowRepo     repository.WorkflowRepository
 	collaborationRepo repository.CollaborationRepository
 	auditRepo        repository.AuditRepository
+	evidenceRequestRepo *repository.EvidenceRequestRepository
+	calendarRepo     *repository.CalendarRepository
+	storageTierRepo  *repository.StorageTierRepository
+	searchReindexRepo *repository.SearchReindexRepository
+	sarFilingRepo    *repository.SARFilingRepository
+	residencyRepo    *repository.ResidencyRepository
+	classificationRepo *repository.ClassificationRepository
+	questionnaireRepo *repository.QuestionnaireRepository
+	archivalRepo     *repository.ArchivalRepository
+	productionRepo   *repository.ProductionRepository
+	offlineSyncRepo  *repository.OfflineSyncRepository
+	quotaRepo        *repository.QuotaRepository
 	
 	// Handlers
 	investigationHandler *handlers.InvestigationHandler
@@ -43,6 +72,18 @@ type Server struct {
 	workflowHandler     *handlers.WorkflowHandler
 	collaborationHandler *handlers.CollaborationHandler
 	auditHandler        *handlers.AuditHandler
+	evidenceRequestHandler *handlers.EvidenceRequestHandler
+	calendarHandler     *handlers.CalendarHandler
+	evidenceStorageHandler *handlers.EvidenceStorageHandler
+	searchHandler       *handlers.SearchHandler
+	traceabilityHandler *handlers.TraceabilityHandler
+	residencyHandler    *handlers.ResidencyHandler
+	classificationHandler *handlers.ClassificationHandler
+	questionnaireHandler *handlers.QuestionnaireHandler
+	archivalHandler     *handlers.ArchivalHandler
+	productionHandler   *handlers.ProductionHandler
+	offlineSyncHandler  *handlers.OfflineSyncHandler
+	quotaHandler        *handlers.QuotaHandler
 	healthHandler       *handlers.HealthHandler
 	
 	// HTTP and gRPC servers
@@ -52,6 +93,39 @@ type Server struct {
 	
 	// Health server
 	healthServer *health.Server
+
+	// Background workers
+	reminderDispatcher *calendar.ReminderDispatcher
+	tieringService     *tiering.Service
+	searchOrchestrator *search.Orchestrator
+
+	// Search writes; dual-writes into indices that are being rebuilt
+	searchIndexer *search.Indexer
+
+	// Closed case archival; nil when archival is disabled
+	archivalService *archival.Service
+
+	// Per-case data residency controls; nil when residency is disabled
+	residencyService *residency.Service
+
+	// Evidence classification and restricted participants; nil when
+	// classification is disabled
+	classificationService *classification.Service
+
+	// Evidence storage quotas; nil when quotas are disabled
+	quotaService *quota.Service
+
+	// Offline evidence sync from field devices; nil when offline sync is
+	// disabled
+	offlineSyncService *offlinesync.Service
+
+	// RBAC policy checks; nil when RBAC is disabled
+	policyChecker rbac.Checker
+	policyConn    *grpc.ClientConn
+
+	// Bearer token authentication for RBAC-checked routes
+	authenticator   rbac.Authenticator
+	userServiceConn *grpc.ClientConn
 }
 
 // New creates a new server instance
@@ -77,6 +151,11 @@ func (s *Server) Initialize() error {
 		return errors.Wrap(err, "failed to initialize handlers")
 	}
 
+	// Initialize RBAC policy checks
+	if err := s.initAuthorization(); err != nil {
+		return errors.Wrap(err, "failed to initialize authorization")
+	}
+
 	// Initialize health server
 	s.healthServer = health.NewServer()
 
@@ -104,6 +183,18 @@ func (s *Server) initRepositories() error {
 	s.workflowRepo = repository.NewWorkflowRepository(s.db.DB)
 	s.collaborationRepo = repository.NewCollaborationRepository(s.db.DB)
 	s.auditRepo = repository.NewAuditRepository(s.db.DB)
+	s.evidenceRequestRepo = repository.NewEvidenceRequestRepository(s.db, s.logger)
+	s.calendarRepo = repository.NewCalendarRepository(s.db, s.logger)
+	s.storageTierRepo = repository.NewStorageTierRepository(s.db, s.logger)
+	s.searchReindexRepo = repository.NewSearchReindexRepository(s.db, s.logger)
+	s.sarFilingRepo = repository.NewSARFilingRepository(s.db, s.logger)
+	s.residencyRepo = repository.NewResidencyRepository(s.db, s.logger)
+	s.classificationRepo = repository.NewClassificationRepository(s.db, s.logger)
+	s.questionnaireRepo = repository.NewQuestionnaireRepository(s.db, s.logger)
+	s.archivalRepo = repository.NewArchivalRepository(s.db, s.logger)
+	s.productionRepo = repository.NewProductionRepository(s.db, s.logger)
+	s.offlineSyncRepo = repository.NewOfflineSyncRepository(s.db, s.logger)
+	s.quotaRepo = repository.NewQuotaRepository(s.db, s.logger)
 	
 	s.logger.Info("Repositories initialized successfully")
 	return nil
@@ -113,18 +204,182 @@ func (s *Server) initRepositories() error {
 func (s *Server) initHandlers() error {
 	s.logger.Info("Initializing handlers")
 	
-	s.investigationHandler = handlers.NewInvestigationHandler(s.investigationRepo, s.auditRepo)
-	s.evidenceHandler = handlers.NewEvidenceHandler(s.evidenceRepo, s.auditRepo)
+	if s.config.Residency.Enabled {
+		s.residencyService = residency.NewService(s.residencyRepo,
+			repository.NewInvestigationRepository(s.db, s.logger), s.config.Residency, s.logger)
+		s.residencyHandler = handlers.NewResidencyHandler(s.residencyService, s.logger)
+	}
+
+	if s.config.Classification.Enabled {
+		classificationService, err := classification.NewService(s.classificationRepo, s.config.Classification, s.logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to create classification service")
+		}
+		s.classificationService = classificationService
+		s.classificationHandler = handlers.NewClassificationHandler(s.classificationService, s.logger)
+	}
+
+	if s.config.Quota.Enabled {
+		s.quotaService = quota.NewService(s.quotaRepo, s.collaborationRepo, s.config.Quota, s.logger)
+		s.quotaHandler = handlers.NewQuotaHandler(s.quotaService, s.logger)
+	}
+
+	s.investigationHandler = handlers.NewInvestigationHandler(s.investigationRepo, s.residencyService, s.auditRepo)
+	s.evidenceHandler = handlers.NewEvidenceHandler(s.evidenceRepo, s.classificationService, s.quotaService, s.auditRepo)
 	s.timelineHandler = handlers.NewTimelineHandler(s.timelineRepo, s.auditRepo)
 	s.workflowHandler = handlers.NewWorkflowHandler(s.workflowRepo, s.auditRepo)
 	s.collaborationHandler = handlers.NewCollaborationHandler(s.collaborationRepo, s.auditRepo)
 	s.auditHandler = handlers.NewAuditHandler(s.auditRepo)
+
+	var fileScanner scanner.Scanner
+	if s.config.EvidenceRequests.ScanEnabled {
+		fileScanner = scanner.NewClamAVScanner(s.config.EvidenceRequests.ClamAVAddress, s.config.EvidenceRequests.ScanTimeout)
+	}
+	s.evidenceRequestHandler = handlers.NewEvidenceRequestHandler(
+		s.evidenceRequestRepo, s.evidenceRepo, s.collaborationRepo, fileScanner, s.residencyService, s.quotaService, s.config, s.logger)
+	if s.config.OfflineSync.Enabled {
+		// Pass nil interfaces, not typed nil pointers, for disabled features
+		var syncQuota offlinesync.Quota
+		if s.quotaService != nil {
+			syncQuota = s.quotaService
+		}
+		var syncResidency offlinesync.Residency
+		if s.residencyService != nil {
+			syncResidency = s.residencyService
+		}
+		s.offlineSyncService = offlinesync.NewService(s.offlineSyncRepo, syncQuota, syncResidency,
+			s.config.OfflineSync, s.config.Storage.LocalPath, s.logger)
+		s.offlineSyncHandler = handlers.NewOfflineSyncHandler(
+			s.offlineSyncService, s.quotaService, s.config.OfflineSync.ChunkSize, s.logger)
+	}
+	s.calendarHandler = handlers.NewCalendarHandler(s.calendarRepo, s.config, s.logger)
+	s.reminderDispatcher = calendar.NewReminderDispatcher(s.calendarRepo, s.collaborationRepo,
+		s.config.Calendar.ReminderInterval, s.config.Calendar.ReminderBatchSize, s.logger)
+
+	if s.config.Tiering.Enabled {
+		backend, err := tiering.NewBackend(s.config)
+		if err != nil {
+			return errors.Wrap(err, "failed to create storage tiering backend")
+		}
+		s.tieringService = tiering.NewService(s.storageTierRepo, backend, s.config.Tiering, s.logger)
+		s.evidenceStorageHandler = handlers.NewEvidenceStorageHandler(s.evidenceRepo, s.tieringService, s.logger)
+	}
+
+	// SAR traceability; without an alerting engine, cited alerts are
+	// reported as unverified rather than checked
+	var alertSource traceability.AlertSource
+	if s.config.Traceability.AlertingEngineURL != "" {
+		alertSource = traceability.NewHTTPAlertSource(
+			s.config.Traceability.AlertingEngineURL, s.config.Traceability.AlertTimeout)
+	}
+	tracer := traceability.NewTracer(s.sarFilingRepo, repository.NewInvestigationRepository(s.db, s.logger),
+		alertSource, s.config.Traceability.AlertConcurrency)
+	s.traceabilityHandler = handlers.NewTraceabilityHandler(s.sarFilingRepo, tracer, s.config, s.logger)
+	s.questionnaireHandler = handlers.NewQuestionnaireHandler(
+		questionnaire.NewService(s.questionnaireRepo, s.logger), s.logger)
+
+	if s.config.Search.Enabled {
+		if err := s.initSearch(); err != nil {
+			return err
+		}
+	}
+
+	if s.config.Archival.Enabled {
+		// Archived cases are removed from search when search is enabled
+		var indexer archival.Indexer
+		if s.searchIndexer != nil {
+			indexer = s.searchIndexer
+		}
+		s.archivalService = archival.NewService(s.archivalRepo, indexer, s.config.Archival, s.logger)
+		s.archivalHandler = handlers.NewArchivalHandler(s.archivalService, s.logger)
+	}
+
+	if s.config.Production.Enabled {
+		productionService, err := production.NewService(s.productionRepo, s.config.Production, s.logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to create production service")
+		}
+		s.productionHandler = handlers.NewProductionHandler(productionService, s.logger)
+	}
 	s.healthHandler = handlers.NewHealthHandler(s.db)
 	
 	s.logger.Info("Handlers initialized successfully")
 	return nil
 }
 
+// initSearch prepares the search indices and their retired index lifecycle
+// policy, and creates the reindex orchestrator and indexer
+func (s *Server) initSearch() error {
+	client := search.NewClient(s.config.Search)
+	s.searchOrchestrator = search.NewOrchestrator(client, s.searchReindexRepo, s.config.Search, s.logger)
+	s.searchIndexer = search.NewIndexer(client, s.searchReindexRepo, s.config.Search, s.logger)
+	s.searchHandler = handlers.NewSearchHandler(s.searchOrchestrator, s.searchReindexRepo, s.logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Search.RequestTimeout)
+	defer cancel()
+
+	if err := s.searchOrchestrator.EnsureLifecyclePolicy(ctx); err != nil {
+		return errors.Wrap(err, "failed to prepare search lifecycle policy")
+	}
+	if err := s.searchOrchestrator.EnsureIndices(ctx); err != nil {
+		return errors.Wrap(err, "failed to prepare search indices")
+	}
+
+	return nil
+}
+
+// initAuthorization sets up RBAC checks against the policy service when one
+// is configured, otherwise against a locally loaded policy
+func (s *Server) initAuthorization() error {
+	if !s.config.Auth.EnableRBAC {
+		return nil
+	}
+
+	if err := s.initAuthenticator(); err != nil {
+		return err
+	}
+
+	if s.config.Auth.PolicyServiceAddr != "" {
+		conn, err := grpc.Dial(s.config.Auth.PolicyServiceAddr,
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return errors.Wrap(err, "failed to connect to policy service")
+		}
+		s.policyConn = conn
+		s.policyChecker = rbac.NewCachedChecker(policyservice.NewClient(conn),
+			s.config.Auth.RBACCacheTTL, s.config.Auth.RBACCacheMaxEntries)
+		s.logger.Info("RBAC enabled", zap.String("policy_service", s.config.Auth.PolicyServiceAddr))
+		return nil
+	}
+
+	policy, err := rbac.LoadPolicyFile(s.config.Auth.RBACPolicyFile)
+	if err != nil {
+		return err
+	}
+	s.policyChecker = rbac.NewEvaluator(policy, s.config.Auth.RBACCacheTTL, s.config.Auth.RBACCacheMaxEntries)
+	s.logger.Info("RBAC enabled with local policy")
+	return nil
+}
+
+// initAuthenticator validates bearer tokens against the user service when one
+// is configured, otherwise by their signature alone
+func (s *Server) initAuthenticator() error {
+	if s.config.Auth.UserServiceAddr == "" {
+		s.authenticator = rbac.NewTokenAuthenticator([]byte(s.config.Auth.JWTSecret))
+		return nil
+	}
+
+	conn, err := grpc.Dial(s.config.Auth.UserServiceAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to user service")
+	}
+	s.userServiceConn = conn
+	s.authenticator = userservice.NewClient(conn)
+	s.logger.Info("Validating tokens against user service", zap.String("user_service", s.config.Auth.UserServiceAddr))
+	return nil
+}
+
 // initHTTPServer initializes the HTTP server with Gin
 func (s *Server) initHTTPServer() error {
 	s.logger.Info("Initializing HTTP server")
@@ -185,6 +440,18 @@ func (s *Server) setupRoutes() {
 
 	// API v1 routes
 	v1 := s.router.Group("/api/v1")
+	if s.policyChecker != nil {
+		v1.Use(handlers.AuthorizationMiddleware(s.policyChecker, s.authenticator, s.logger))
+	}
+	if s.residencyService != nil {
+		v1.Use(handlers.ResidencyMiddleware(s.residencyService, s.logger))
+	}
+	if s.classificationService != nil {
+		v1.Use(handlers.ClassificationMiddleware(s.classificationService, s.logger))
+	}
+	if s.archivalService != nil {
+		v1.Use(handlers.ArchivalMiddleware(s.archivalService, s.logger))
+	}
 	{
 		// Investigation routes
 		investigations := v1.Group("/investigations")
@@ -200,6 +467,13 @@ func (s *Server) setupRoutes() {
 			investigations.GET("/user/:user_id", s.investigationHandler.GetUserInvestigations)
 		}
 
+		// Case archival routes
+		if s.archivalHandler != nil {
+			investigations.POST("/:id/archive", s.archivalHandler.ArchiveCase)
+			investigations.POST("/:id/reopen", s.archivalHandler.ReopenCase)
+			investigations.GET("/:id/archives", s.archivalHandler.ListArchives)
+		}
+
 		// Evidence routes
 		evidence := v1.Group("/evidence")
 		{
@@ -214,6 +488,157 @@ func (s *Server) setupRoutes() {
 			evidence.DELETE("/:id/files/:file_id", s.evidenceHandler.DeleteFile)
 		}
 
+		// Evidence storage tiering routes
+		if s.evidenceStorageHandler != nil {
+			evidence.GET("/:id/storage", s.evidenceStorageHandler.GetStorageStatus)
+			evidence.GET("/:id/content", s.evidenceStorageHandler.DownloadContent)
+			evidence.POST("/:id/restore", s.evidenceStorageHandler.RequestRestore)
+			v1.GET("/storage/tiers", s.evidenceStorageHandler.GetTierStats)
+		}
+
+		// Storage quota routes
+		if s.quotaHandler != nil {
+			v1.GET("/storage/quota", s.quotaHandler.GetMyUsage)
+			v1.GET("/storage/quota/check", s.quotaHandler.CheckUpload)
+			quotas := v1.Group("/quotas")
+			{
+				quotas.GET("/tenants", s.quotaHandler.ListTenantUsage)
+				quotas.GET("/tenants/:tenant_id", s.quotaHandler.GetTenantUsage)
+				quotas.PUT("/tenants/:tenant_id", s.quotaHandler.SetTenantQuota)
+				quotas.GET("/users", s.quotaHandler.ListUserUsage)
+				quotas.GET("/users/:user_id", s.quotaHandler.GetUserUsage)
+				quotas.PUT("/users/:user_id", s.quotaHandler.SetUserQuota)
+			}
+		}
+
+		// Search index administration routes
+		if s.searchHandler != nil {
+			searchRoutes := v1.Group("/search")
+			{
+				searchRoutes.GET("/indices", s.searchHandler.GetIndices)
+				searchRoutes.POST("/indices/:name/reindex", s.searchHandler.StartReindex)
+				searchRoutes.GET("/reindex-jobs", s.searchHandler.ListReindexJobs)
+				searchRoutes.GET("/reindex-jobs/:id", s.searchHandler.GetReindexJob)
+				searchRoutes.POST("/reindex-jobs/:id/cancel", s.searchHandler.CancelReindexJob)
+			}
+		}
+
+		// Evidence request routes
+		v1.POST("/investigations/:id/evidence-requests", s.evidenceRequestHandler.CreateEvidenceRequest)
+		v1.GET("/investigations/:id/evidence-requests", s.evidenceRequestHandler.ListEvidenceRequests)
+		evidenceRequests := v1.Group("/evidence-requests")
+		{
+			evidenceRequests.GET("/:id", s.evidenceRequestHandler.GetEvidenceRequest)
+			evidenceRequests.POST("/:id/revoke", s.evidenceRequestHandler.RevokeEvidenceRequest)
+		}
+
+		// Calendar routes
+		v1.POST("/investigations/:id/calendar-events", s.calendarHandler.CreateEvent)
+		v1.GET("/investigations/:id/calendar-events", s.calendarHandler.ListInvestigationEvents)
+		calendarRoutes := v1.Group("/calendar")
+		{
+			calendarRoutes.GET("/events", s.calendarHandler.ListMyEvents)
+			calendarRoutes.GET("/events/:id", s.calendarHandler.GetEvent)
+			calendarRoutes.PUT("/events/:id", s.calendarHandler.UpdateEvent)
+			calendarRoutes.POST("/events/:id/cancel", s.calendarHandler.CancelEvent)
+			calendarRoutes.GET("/conflicts", s.calendarHandler.CheckConflicts)
+			calendarRoutes.POST("/feed", s.calendarHandler.CreateFeed)
+			calendarRoutes.DELETE("/feed", s.calendarHandler.RevokeFeed)
+		}
+
+		// SAR filing and traceability routes
+		v1.POST("/investigations/:id/sar-filings", s.traceabilityHandler.CreateSARFiling)
+		v1.GET("/investigations/:id/sar-filings", s.traceabilityHandler.ListSARFilings)
+		sarFilings := v1.Group("/sar-filings")
+		{
+			sarFilings.GET("/:id", s.traceabilityHandler.GetSARFiling)
+			sarFilings.PUT("/:id", s.traceabilityHandler.UpdateSARFiling)
+			sarFilings.POST("/:id/file", s.traceabilityHandler.FileSAR)
+			sarFilings.POST("/:id/withdraw", s.traceabilityHandler.WithdrawSAR)
+			sarFilings.GET("/:id/traceability", s.traceabilityHandler.GetSARTraceability)
+		}
+		v1.GET("/traceability/report", s.traceabilityHandler.GetTraceabilityReport)
+
+		// Data residency routes
+		if s.residencyHandler != nil {
+			v1.PUT("/investigations/:id/region", s.residencyHandler.SetCaseRegion)
+			v1.POST("/investigations/:id/residency-grants", s.residencyHandler.CreateGrant)
+			v1.GET("/investigations/:id/residency-grants", s.residencyHandler.ListGrants)
+			v1.POST("/investigations/:id/exports", s.residencyHandler.CreateExport)
+			v1.GET("/investigations/:id/exports", s.residencyHandler.ListExports)
+			residencyRoutes := v1.Group("/residency")
+			{
+				residencyRoutes.POST("/grants/:id/revoke", s.residencyHandler.RevokeGrant)
+				residencyRoutes.GET("/report", s.residencyHandler.GetReport)
+			}
+		}
+
+		// Case participant and evidence classification routes
+		if s.classificationHandler != nil {
+			v1.POST("/investigations/:id/participants", s.classificationHandler.AddParticipant)
+			v1.GET("/investigations/:id/participants", s.classificationHandler.ListParticipants)
+			v1.DELETE("/investigations/:id/participants/:user_id", s.classificationHandler.RemoveParticipant)
+			v1.GET("/investigations/:id/access-matrix", s.classificationHandler.GetAccessMatrix)
+			v1.PUT("/evidence/:id/classification", s.classificationHandler.ClassifyEvidence)
+			v1.GET("/evidence/:id/classification-history", s.classificationHandler.GetClassificationHistory)
+		}
+
+		// Review questionnaire routes
+		questionnaireTemplates := v1.Group("/questionnaire-templates")
+		{
+			questionnaireTemplates.POST("", s.questionnaireHandler.CreateTemplate)
+			questionnaireTemplates.GET("", s.questionnaireHandler.ListTemplates)
+			questionnaireTemplates.GET("/:id", s.questionnaireHandler.GetTemplate)
+			questionnaireTemplates.PUT("/:id", s.questionnaireHandler.UpdateTemplate)
+			questionnaireTemplates.POST("/:id/publish", s.questionnaireHandler.PublishTemplate)
+			questionnaireTemplates.POST("/:id/archive", s.questionnaireHandler.ArchiveTemplate)
+		}
+		v1.POST("/investigations/:id/questionnaires", s.questionnaireHandler.AssignQuestionnaire)
+		v1.GET("/investigations/:id/questionnaires", s.questionnaireHandler.ListQuestionnaires)
+		v1.GET("/investigations/:id/questionnaire-report", s.questionnaireHandler.GetQuestionnaireReport)
+		questionnaires := v1.Group("/questionnaires")
+		{
+			questionnaires.GET("/:id", s.questionnaireHandler.GetQuestionnaire)
+			questionnaires.PUT("/:id/responses", s.questionnaireHandler.SaveResponses)
+			questionnaires.POST("/:id/submit", s.questionnaireHandler.SubmitQuestionnaire)
+			questionnaires.POST("/:id/cancel", s.questionnaireHandler.CancelQuestionnaire)
+		}
+
+		// Offline evidence sync routes
+		if s.offlineSyncHandler != nil {
+			v1.POST("/investigations/:id/offline-sync", s.offlineSyncHandler.CreateSession)
+			v1.GET("/investigations/:id/offline-sync", s.offlineSyncHandler.ListSessions)
+			offlineSync := v1.Group("/offline-sync")
+			{
+				offlineSync.GET("/:id", s.offlineSyncHandler.GetSession)
+				offlineSync.DELETE("/:id", s.offlineSyncHandler.CancelSession)
+				offlineSync.PUT("/:id/items/:item_id/chunks/:index", s.offlineSyncHandler.UploadChunk)
+				offlineSync.POST("/:id/complete", s.offlineSyncHandler.CompleteSession)
+			}
+		}
+
+		// Law-enforcement production routes
+		if s.productionHandler != nil {
+			v1.GET("/production-templates", s.productionHandler.ListTemplates)
+			v1.POST("/investigations/:id/productions", s.productionHandler.CreateProduction)
+			v1.GET("/investigations/:id/productions", s.productionHandler.ListProductions)
+			productions := v1.Group("/productions")
+			{
+				productions.GET("/:id", s.productionHandler.GetProduction)
+				productions.POST("/:id/approve", s.productionHandler.ApproveProduction)
+				productions.POST("/:id/produce", s.productionHandler.Produce)
+				productions.GET("/:id/file", s.productionHandler.DownloadFile)
+			}
+		}
+
+		// External routes, authorized by a token in the URL rather than a user
+		external := v1.Group("/external")
+		{
+			external.GET("/evidence-requests/:token", s.evidenceRequestHandler.GetExternalRequest)
+			external.POST("/evidence-requests/:token", s.evidenceRequestHandler.SubmitExternalEvidence)
+			external.GET("/calendar/:token", s.calendarHandler.GetFeed)
+		}
+
 		// Timeline routes
 		timeline := v1.Group("/timeline")
 		{
@@ -416,6 +841,37 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 	}()
 
+	// Start calendar reminder delivery
+	go s.reminderDispatcher.Run(ctx)
+
+	// Start expiry of overdue evidence requests
+	go s.evidenceRequestHandler.Run(ctx)
+
+	// Start evidence storage tiering
+	if s.tieringService != nil {
+		go s.tieringService.Run(ctx)
+	}
+
+	// Start release of expired storage reservations
+	if s.quotaService != nil {
+		go s.quotaService.Run(ctx)
+	}
+
+	// Start expiry of abandoned offline sync sessions
+	if s.offlineSyncService != nil {
+		go s.offlineSyncService.Run(ctx)
+	}
+
+	// Start closed case archival
+	if s.archivalService != nil {
+		go s.archivalService.Run(ctx)
+	}
+
+	// Start search reindex orchestration
+	if s.searchOrchestrator != nil {
+		go s.searchOrchestrator.Run(ctx)
+	}
+
 	// Set health status to serving
 	s.healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
 
@@ -444,6 +900,13 @@ func (s *Server) Shutdown() error {
 	// Shutdown gRPC server
 	s.grpcServer.GracefulStop()
 
+	if s.policyConn != nil {
+		s.policyConn.Close()
+	}
+	if s.userServiceConn != nil {
+		s.userServiceConn.Close()
+	}
+
 	// Close database connection
 	if err := s.db.Close(); err != nil {
 		s.logger.Error("Failed to close database connection", zap.Error(err))
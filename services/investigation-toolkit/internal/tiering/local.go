@@ -0,0 +1,219 @@
+package tiering
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+)
+
+// Backend moves stored objects between storage tiers and reads them back
+type Backend interface {
+	// Transition moves an object to a tier and returns its new key
+	Transition(ctx context.Context, key string, tier models.StorageTier) (string, error)
+	// RequestRestore starts an asynchronous restore of an archived object
+	RequestRestore(ctx context.Context, key string) error
+	// RestoreReady reports whether a requested restore can be read
+	RestoreReady(ctx context.Context, key string) (bool, error)
+	// ExpireRestore drops the readable copy of a restored object
+	ExpireRestore(ctx context.Context, key string) error
+	// Open reads an object from whichever tier holds it
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// NewBackend creates the tiering backend for the configured storage provider
+func NewBackend(cfg *config.Config) (Backend, error) {
+	switch cfg.Storage.Provider {
+	case "local":
+		return NewLocalBackend(cfg.Storage.LocalPath, cfg.Tiering.RestoreDelay), nil
+	default:
+		return nil, errors.Errorf("storage tiering is not supported for provider %s", cfg.Storage.Provider)
+	}
+}
+
+const (
+	tiersDir         = ".tiers"
+	archiveExt       = ".gz"
+	restoreMarkerExt = ".restore"
+)
+
+// LocalBackend tiers files on the local filesystem. Infrequent access files
+// move to a separate directory; archived files are gzip compressed and must be
+// restored before they can be read, mirroring object store archive classes.
+type LocalBackend struct {
+	basePath     string
+	restoreDelay time.Duration
+	now          func() time.Time
+}
+
+// NewLocalBackend creates a new local tiering backend
+func NewLocalBackend(basePath string, restoreDelay time.Duration) *LocalBackend {
+	return &LocalBackend{
+		basePath:     basePath,
+		restoreDelay: restoreDelay,
+		now:          time.Now,
+	}
+}
+
+// Transition moves a file into the directory for the target tier
+func (b *LocalBackend) Transition(ctx context.Context, key string, tier models.StorageTier) (string, error) {
+	relative, err := b.relativePath(key)
+	if err != nil {
+		return "", err
+	}
+
+	target := filepath.Join(b.basePath, relative)
+	if tier != models.StorageTierStandard {
+		target = filepath.Join(b.basePath, tiersDir, string(tier), relative)
+	}
+	if tier == models.StorageTierArchive {
+		target += archiveExt
+	}
+	if target == key {
+		return key, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return "", errors.Wrap(err, "failed to create tier directory")
+	}
+
+	if strings.HasSuffix(key, archiveExt) == strings.HasSuffix(target, archiveExt) {
+		if err := os.Rename(key, target); err != nil {
+			return "", errors.Wrap(err, "failed to move file between tiers")
+		}
+		return target, nil
+	}
+
+	if err := b.recompress(key, target); err != nil {
+		os.Remove(target)
+		return "", err
+	}
+	if err := os.Remove(key); err != nil {
+		return "", errors.Wrap(err, "failed to remove file from previous tier")
+	}
+
+	return target, nil
+}
+
+// RequestRestore records when the archived file becomes readable
+func (b *LocalBackend) RequestRestore(ctx context.Context, key string) error {
+	if _, err := os.Stat(key); err != nil {
+		return errors.Wrap(err, "archived file not found")
+	}
+
+	readyAt := b.now().Add(b.restoreDelay).UTC().Format(time.RFC3339)
+	if err := os.WriteFile(key+restoreMarkerExt, []byte(readyAt), 0640); err != nil {
+		return errors.Wrap(err, "failed to request restore")
+	}
+
+	return nil
+}
+
+// RestoreReady reports whether the restore delay has passed
+func (b *LocalBackend) RestoreReady(ctx context.Context, key string) (bool, error) {
+	marker, err := os.ReadFile(key + restoreMarkerExt)
+	if err != nil {
+		return false, errors.Wrap(err, "restore was not requested")
+	}
+
+	readyAt, err := time.Parse(time.RFC3339, strings.TrimSpace(string(marker)))
+	if err != nil {
+		return false, errors.Wrap(err, "invalid restore marker")
+	}
+
+	return !b.now().Before(readyAt), nil
+}
+
+// ExpireRestore removes the restore marker so the file is archived again
+func (b *LocalBackend) ExpireRestore(ctx context.Context, key string) error {
+	if err := os.Remove(key + restoreMarkerExt); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to expire restore")
+	}
+
+	return nil
+}
+
+// Open reads a file, decompressing archived files transparently
+func (b *LocalBackend) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open stored file")
+	}
+
+	if !strings.HasSuffix(key, archiveExt) {
+		return file, nil
+	}
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrap(err, "failed to read archived file")
+	}
+
+	return &archiveReader{Reader: reader, file: file}, nil
+}
+
+// relativePath returns a key's path below the base path with any tier directory removed
+func (b *LocalBackend) relativePath(key string) (string, error) {
+	relative, err := filepath.Rel(b.basePath, key)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("file %s is outside the storage path", key)
+	}
+
+	parts := strings.SplitN(relative, string(filepath.Separator), 3)
+	if len(parts) == 3 && parts[0] == tiersDir {
+		relative = parts[2]
+	}
+
+	return strings.TrimSuffix(relative, archiveExt), nil
+}
+
+func (b *LocalBackend) recompress(source, target string) error {
+	in, err := b.Open(context.Background(), source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return errors.Wrap(err, "failed to create tiered file")
+	}
+	defer out.Close()
+
+	if !strings.HasSuffix(target, archiveExt) {
+		if _, err := io.Copy(out, in); err != nil {
+			return errors.Wrap(err, "failed to copy file between tiers")
+		}
+		return out.Sync()
+	}
+
+	writer := gzip.NewWriter(out)
+	if _, err := io.Copy(writer, in); err != nil {
+		return errors.Wrap(err, "failed to copy file between tiers")
+	}
+	if err := writer.Close(); err != nil {
+		return errors.Wrap(err, "failed to finish archived file")
+	}
+
+	return out.Sync()
+}
+
+// archiveReader closes both the gzip stream and the underlying file
+type archiveReader struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (r *archiveReader) Close() error {
+	r.Reader.Close()
+	return r.file.Close()
+}
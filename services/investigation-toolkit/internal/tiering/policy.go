@@ -0,0 +1,71 @@
+package tiering
+
+import (
+	"time"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+)
+
+// Availability describes whether an evidence file can be downloaded right now
+type Availability string
+
+const (
+	AvailabilityAvailable Availability = "available"
+	AvailabilityRestoring Availability = "restoring_from_archive"
+	AvailabilityArchived  Availability = "archived"
+)
+
+// Policy decides when evidence files move to colder storage tiers
+type Policy struct {
+	InfrequentAfter time.Duration
+	ArchiveAfter    time.Duration
+}
+
+// NewPolicy builds a tiering policy from configuration
+func NewPolicy(cfg config.TieringConfig) Policy {
+	return Policy{
+		InfrequentAfter: time.Duration(cfg.InfrequentAfterDays) * 24 * time.Hour,
+		ArchiveAfter:    time.Duration(cfg.ArchiveAfterDays) * 24 * time.Hour,
+	}
+}
+
+// TargetTier returns the tier an object belongs in based on its last activity.
+// Objects never move back to a warmer tier and are left alone while a restore
+// is in flight.
+func (p Policy) TargetTier(object *models.EvidenceStorageObject, now time.Time) (models.StorageTier, bool) {
+	if object.RestoreStatus != models.RestoreStatusNone {
+		return object.Tier, false
+	}
+
+	lastActivity := object.CreatedAt
+	if object.LastAccessedAt != nil && object.LastAccessedAt.After(lastActivity) {
+		lastActivity = *object.LastAccessedAt
+	}
+	age := now.Sub(lastActivity)
+
+	switch {
+	case object.Tier != models.StorageTierArchive && age >= p.ArchiveAfter:
+		return models.StorageTierArchive, true
+	case object.Tier == models.StorageTierStandard && age >= p.InfrequentAfter:
+		return models.StorageTierInfrequentAccess, true
+	default:
+		return object.Tier, false
+	}
+}
+
+// AvailabilityOf reports whether an object can be read without waiting for a restore
+func AvailabilityOf(object *models.EvidenceStorageObject) Availability {
+	if object.Tier != models.StorageTierArchive {
+		return AvailabilityAvailable
+	}
+
+	switch object.RestoreStatus {
+	case models.RestoreStatusRestored:
+		return AvailabilityAvailable
+	case models.RestoreStatusInProgress:
+		return AvailabilityRestoring
+	default:
+		return AvailabilityArchived
+	}
+}
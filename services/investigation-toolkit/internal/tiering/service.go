@@ -0,0 +1,302 @@
+package tiering
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+)
+
+// ErrRestoreInProgress is returned when an archived file is still being restored
+var ErrRestoreInProgress = errors.New("evidence file is restoring from archive")
+
+// StorageStatus is the user-facing storage state of an evidence file
+type StorageStatus struct {
+	EvidenceID       uuid.UUID          `json:"evidence_id"`
+	Tier             models.StorageTier `json:"tier"`
+	Availability     Availability       `json:"availability"`
+	Message          string             `json:"message"`
+	TierChangedAt    time.Time          `json:"tier_changed_at"`
+	RestoreReadyAt   *time.Time         `json:"restore_ready_at,omitempty"`
+	RestoreExpiresAt *time.Time         `json:"restore_expires_at,omitempty"`
+}
+
+// SweepResult summarizes one lifecycle sweep
+type SweepResult struct {
+	Registered      int64 `json:"registered"`
+	Transitioned    int   `json:"transitioned"`
+	RestoresReady   int   `json:"restores_ready"`
+	RestoresExpired int   `json:"restores_expired"`
+	Failed          int   `json:"failed"`
+}
+
+// Service moves aged evidence files to colder tiers and restores archived
+// files transparently when they are read
+type Service struct {
+	repo    *repository.StorageTierRepository
+	backend Backend
+	policy  Policy
+	config  config.TieringConfig
+	logger  *zap.Logger
+}
+
+// NewService creates a new storage tiering service
+func NewService(repo *repository.StorageTierRepository, backend Backend, cfg config.TieringConfig, logger *zap.Logger) *Service {
+	return &Service{
+		repo:    repo,
+		backend: backend,
+		policy:  NewPolicy(cfg),
+		config:  cfg,
+		logger:  logger.Named("storage_tiering"),
+	}
+}
+
+// Run sweeps storage tiers until the context is cancelled
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.Sweep(ctx)
+			if err != nil {
+				s.logger.Error("Failed to sweep storage tiers", zap.Error(err))
+				continue
+			}
+			if result.Transitioned > 0 || result.RestoresReady > 0 || result.RestoresExpired > 0 || result.Failed > 0 {
+				s.logger.Info("Storage tier sweep completed",
+					zap.Int64("registered", result.Registered),
+					zap.Int("transitioned", result.Transitioned),
+					zap.Int("restores_ready", result.RestoresReady),
+					zap.Int("restores_expired", result.RestoresExpired),
+					zap.Int("failed", result.Failed))
+			}
+		}
+	}
+}
+
+// Sweep registers new evidence files, moves aged files to colder tiers and
+// advances archive restores
+func (s *Service) Sweep(ctx context.Context) (*SweepResult, error) {
+	result := &SweepResult{}
+
+	registered, err := s.repo.RegisterAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.Registered = registered
+
+	now := time.Now()
+	due, err := s.repo.ListDueForTransition(ctx,
+		now.Add(-s.policy.InfrequentAfter), now.Add(-s.policy.ArchiveAfter), s.config.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range due {
+		object := &due[i]
+		tier, move := s.policy.TargetTier(object, now)
+		if !move {
+			continue
+		}
+
+		if err := s.transition(ctx, object, tier); err != nil {
+			s.logger.Error("Failed to move evidence file to storage tier",
+				zap.String("evidence_id", object.EvidenceID.String()),
+				zap.String("tier", string(tier)),
+				zap.Error(err))
+			result.Failed++
+			continue
+		}
+		result.Transitioned++
+	}
+
+	restoring, err := s.repo.ListRestoresInProgress(ctx, s.config.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range restoring {
+		object := &restoring[i]
+		ready, err := s.backend.RestoreReady(ctx, object.ObjectKey)
+		if err != nil {
+			s.logger.Error("Failed to check archive restore",
+				zap.String("evidence_id", object.EvidenceID.String()),
+				zap.Error(err))
+			result.Failed++
+			continue
+		}
+		if !ready {
+			continue
+		}
+
+		if err := s.repo.CompleteRestore(ctx, object.EvidenceID, time.Now().Add(s.config.RestoredRetention)); err != nil {
+			result.Failed++
+			continue
+		}
+		result.RestoresReady++
+	}
+
+	expired, err := s.repo.ListExpiredRestores(ctx, s.config.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range expired {
+		object := &expired[i]
+		if err := s.backend.ExpireRestore(ctx, object.ObjectKey); err != nil {
+			s.logger.Error("Failed to expire restored copy",
+				zap.String("evidence_id", object.EvidenceID.String()),
+				zap.Error(err))
+			result.Failed++
+			continue
+		}
+		if err := s.repo.ResetRestore(ctx, object.EvidenceID); err != nil {
+			result.Failed++
+			continue
+		}
+		result.RestoresExpired++
+	}
+
+	return result, nil
+}
+
+// Status returns the storage tier and availability of an evidence file
+func (s *Service) Status(ctx context.Context, evidenceID uuid.UUID) (*StorageStatus, error) {
+	object, err := s.object(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return statusOf(object), nil
+}
+
+// Open reads an evidence file from whichever tier holds it. Reading an
+// archived file starts a restore and returns ErrRestoreInProgress together
+// with the status to show the user.
+func (s *Service) Open(ctx context.Context, evidenceID, userID uuid.UUID) (io.ReadCloser, *StorageStatus, error) {
+	object, err := s.object(ctx, evidenceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch AvailabilityOf(object) {
+	case AvailabilityArchived:
+		status, err := s.RequestRestore(ctx, evidenceID, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, status, ErrRestoreInProgress
+	case AvailabilityRestoring:
+		return nil, statusOf(object), ErrRestoreInProgress
+	}
+
+	reader, err := s.backend.Open(ctx, object.ObjectKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.repo.TouchAccess(ctx, evidenceID); err != nil {
+		s.logger.Warn("Failed to record evidence file access",
+			zap.String("evidence_id", evidenceID.String()),
+			zap.Error(err))
+	}
+
+	return reader, statusOf(object), nil
+}
+
+// RequestRestore starts restoring an archived evidence file. Requests for
+// files that are not archived, or already restoring, return the current status.
+func (s *Service) RequestRestore(ctx context.Context, evidenceID, userID uuid.UUID) (*StorageStatus, error) {
+	object, err := s.object(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if AvailabilityOf(object) != AvailabilityArchived {
+		return statusOf(object), nil
+	}
+
+	started, err := s.repo.StartRestore(ctx, evidenceID, userID, time.Now().Add(s.config.RestoreDelay))
+	if err != nil {
+		return nil, err
+	}
+
+	if started {
+		if err := s.backend.RequestRestore(ctx, object.ObjectKey); err != nil {
+			if resetErr := s.repo.ResetRestore(ctx, evidenceID); resetErr != nil {
+				s.logger.Error("Failed to reset restore after backend error",
+					zap.String("evidence_id", evidenceID.String()),
+					zap.Error(resetErr))
+			}
+			return nil, err
+		}
+
+		s.logger.Info("Archive restore requested",
+			zap.String("evidence_id", evidenceID.String()),
+			zap.String("requested_by", userID.String()))
+	}
+
+	object, err = s.repo.GetByEvidenceID(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return statusOf(object), nil
+}
+
+// Stats returns object counts and bytes per storage tier
+func (s *Service) Stats(ctx context.Context) (map[models.StorageTier]models.StorageTierStats, error) {
+	return s.repo.GetTierStats(ctx)
+}
+
+// object loads the storage object for an evidence item, registering files
+// uploaded since the last sweep
+func (s *Service) object(ctx context.Context, evidenceID uuid.UUID) (*models.EvidenceStorageObject, error) {
+	if err := s.repo.Register(ctx, evidenceID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByEvidenceID(ctx, evidenceID)
+}
+
+func (s *Service) transition(ctx context.Context, object *models.EvidenceStorageObject, tier models.StorageTier) error {
+	key, err := s.backend.Transition(ctx, object.ObjectKey, tier)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SetTier(ctx, object.EvidenceID, tier, key)
+}
+
+func statusOf(object *models.EvidenceStorageObject) *StorageStatus {
+	status := &StorageStatus{
+		EvidenceID:    object.EvidenceID,
+		Tier:          object.Tier,
+		Availability:  AvailabilityOf(object),
+		TierChangedAt: object.TierChangedAt,
+	}
+
+	switch status.Availability {
+	case AvailabilityRestoring:
+		status.RestoreReadyAt = object.RestoreReadyAt
+		status.Message = "This file is being restored from archive storage and will be available shortly"
+	case AvailabilityArchived:
+		status.Message = "This file is in archive storage; downloading it will start a restore"
+	default:
+		status.RestoreExpiresAt = object.RestoreExpiresAt
+		status.Message = "This file is available for download"
+	}
+
+	return status
+}
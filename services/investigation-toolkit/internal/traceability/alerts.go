@@ -0,0 +1,109 @@
+package traceability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Alert is the part of an alerting engine alert a trace records
+type Alert struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Severity  string     `json:"severity"`
+	Status    string     `json:"status"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// AlertSource looks up alerts and the cases they are linked to. GetAlert
+// returns a nil alert, not an error, when the alert does not exist.
+type AlertSource interface {
+	GetAlert(ctx context.Context, alertID string) (*Alert, error)
+	ListAlertCases(ctx context.Context, alertID string) ([]string, error)
+}
+
+type authorizationKey struct{}
+
+// WithAuthorization forwards the caller's Authorization header to the
+// alerting engine, so alerts are read with the caller's own permissions
+func WithAuthorization(ctx context.Context, header string) context.Context {
+	return context.WithValue(ctx, authorizationKey{}, header)
+}
+
+// HTTPAlertSource reads alerts from the alerting engine's HTTP API
+type HTTPAlertSource struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPAlertSource creates an alert source for the alerting engine at
+// baseURL, for example http://alerting-engine:8080
+func NewHTTPAlertSource(baseURL string, timeout time.Duration) *HTTPAlertSource {
+	return &HTTPAlertSource{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// GetAlert implements AlertSource
+func (s *HTTPAlertSource) GetAlert(ctx context.Context, alertID string) (*Alert, error) {
+	var alert Alert
+	found, err := s.get(ctx, "/alerts/"+url.PathEscape(alertID), &alert)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// ListAlertCases implements AlertSource
+func (s *HTTPAlertSource) ListAlertCases(ctx context.Context, alertID string) ([]string, error) {
+	var resp struct {
+		Cases []struct {
+			CaseID string `json:"case_id"`
+		} `json:"cases"`
+	}
+	if _, err := s.get(ctx, "/alert-clusters/alerts/"+url.PathEscape(alertID)+"/cases", &resp); err != nil {
+		return nil, err
+	}
+
+	caseIDs := make([]string, 0, len(resp.Cases))
+	for _, link := range resp.Cases {
+		caseIDs = append(caseIDs, link.CaseID)
+	}
+	return caseIDs, nil
+}
+
+// get decodes a JSON response into out. It reports false for 404.
+func (s *HTTPAlertSource) get(ctx context.Context, path string, out interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create alerting engine request: %w", err)
+	}
+	if header, ok := ctx.Value(authorizationKey{}).(string); ok && header != "" {
+		req.Header.Set("Authorization", header)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("alerting engine request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return false, fmt.Errorf("alerting engine returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("failed to decode alerting engine response: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,294 @@
+// Package traceability proves that every SAR filing traces back through its
+// case to the alerts that originated it and the evidence that supports it.
+// Each link in the alert → case → evidence → SAR chain is checked, and
+// missing, broken or orphaned links are reported as issues.
+package traceability
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"investigation-toolkit/internal/models"
+)
+
+// Severity of a traceability issue. Errors break the chain; warnings weaken
+// it but do not stop a filing from tracing back.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue codes
+const (
+	IssueCaseNotFound            = "case_not_found"
+	IssueNoAlerts                = "no_originating_alerts"
+	IssueAlertNotFound           = "alert_not_found"
+	IssueAlertUnverified         = "alert_unverified"
+	IssueAlertNotLinked          = "alert_not_linked_to_case"
+	IssueNoEvidence              = "no_evidence"
+	IssueEvidenceNotFound        = "evidence_not_found"
+	IssueEvidenceOtherCase       = "evidence_from_other_case"
+	IssueEvidenceWithdrawn       = "evidence_withdrawn"
+	IssueEvidenceUnauthenticated = "evidence_unauthenticated"
+	IssueEvidenceAfterFiling     = "evidence_after_filing"
+)
+
+// Links of the chain an issue is found on
+const (
+	LinkCase     = "case"
+	LinkAlert    = "alert"
+	LinkEvidence = "evidence"
+)
+
+// Issue is a missing, broken or weak link in a filing's chain
+type Issue struct {
+	Code      string   `json:"code"`
+	Severity  Severity `json:"severity"`
+	Link      string   `json:"link"`
+	Reference string   `json:"reference,omitempty"` // ID of the alert, case or evidence
+	Message   string   `json:"message"`
+}
+
+// AlertLookup is what the alerting engine returned for one cited alert
+type AlertLookup struct {
+	Alert   *Alert
+	CaseIDs []string // cases the alert is linked to
+	Err     error    // set when the alerting engine could not be asked
+}
+
+// Chain holds everything a SAR filing links to. Missing records are absent
+// from the maps, or nil for the case.
+type Chain struct {
+	Filing   *models.SARFiling
+	Case     *models.Investigation
+	Alerts   map[string]AlertLookup
+	Evidence map[uuid.UUID]*models.Evidence
+}
+
+// CaseLink summarizes the investigation a filing was raised from
+type CaseLink struct {
+	ID     uuid.UUID     `json:"id"`
+	Found  bool          `json:"found"`
+	Title  string        `json:"title,omitempty"`
+	Status models.Status `json:"status,omitempty"`
+}
+
+// AlertLink is one originating alert of a filing
+type AlertLink struct {
+	AlertID      string     `json:"alert_id"`
+	Found        bool       `json:"found"`
+	Verified     bool       `json:"verified"` // false when the alerting engine could not be reached
+	LinkedToCase bool       `json:"linked_to_case"`
+	Title        string     `json:"title,omitempty"`
+	Severity     string     `json:"severity,omitempty"`
+	Status       string     `json:"status,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+}
+
+// EvidenceLink is one evidence item cited by a filing
+type EvidenceLink struct {
+	EvidenceID      uuid.UUID             `json:"evidence_id"`
+	Found           bool                  `json:"found"`
+	InvestigationID *uuid.UUID            `json:"investigation_id,omitempty"`
+	Name            string                `json:"name,omitempty"`
+	Status          models.EvidenceStatus `json:"status,omitempty"`
+	IsAuthenticated bool                  `json:"is_authenticated"`
+	FileHash        *string               `json:"file_hash,omitempty"`
+	CollectedAt     *time.Time            `json:"collected_at,omitempty"`
+}
+
+// Trace is the validated chain of one SAR filing
+type Trace struct {
+	Filing   *models.SARFiling `json:"filing"`
+	Case     CaseLink          `json:"case"`
+	Alerts   []AlertLink       `json:"alerts"`
+	Evidence []EvidenceLink    `json:"evidence"`
+	Issues   []Issue           `json:"issues"`
+	Complete bool              `json:"complete"` // no error-level issues
+}
+
+// Validate walks the chain of a filing and reports every missing, broken or
+// weak link
+func Validate(chain Chain) *Trace {
+	filing := chain.Filing
+	trace := &Trace{
+		Filing:   filing,
+		Case:     CaseLink{ID: filing.InvestigationID},
+		Alerts:   []AlertLink{},
+		Evidence: []EvidenceLink{},
+		Issues:   []Issue{},
+	}
+
+	if chain.Case != nil {
+		trace.Case.Found = true
+		trace.Case.Title = chain.Case.Title
+		trace.Case.Status = chain.Case.Status
+	} else {
+		trace.addIssue(IssueCaseNotFound, SeverityError, LinkCase, filing.InvestigationID.String(),
+			"the investigation the filing was raised from does not exist")
+	}
+
+	caseID := filing.InvestigationID.String()
+	if len(filing.AlertIDs) == 0 {
+		trace.addIssue(IssueNoAlerts, SeverityError, LinkAlert, "",
+			"the filing cites no originating alerts")
+	}
+	for _, alertID := range filing.AlertIDs {
+		trace.Alerts = append(trace.Alerts, trace.checkAlert(alertID, chain.Alerts[alertID], caseID))
+	}
+
+	if len(filing.EvidenceIDs) == 0 {
+		trace.addIssue(IssueNoEvidence, SeverityError, LinkEvidence, "",
+			"the filing cites no supporting evidence")
+	}
+	for _, evidenceID := range filing.EvidenceIDs {
+		trace.Evidence = append(trace.Evidence, trace.checkEvidence(evidenceID, chain.Evidence[evidenceID], filing))
+	}
+
+	trace.Complete = true
+	for _, issue := range trace.Issues {
+		if issue.Severity == SeverityError {
+			trace.Complete = false
+			break
+		}
+	}
+	return trace
+}
+
+func (t *Trace) checkAlert(alertID string, lookup AlertLookup, caseID string) AlertLink {
+	link := AlertLink{AlertID: alertID}
+
+	if lookup.Err != nil {
+		t.addIssue(IssueAlertUnverified, SeverityWarning, LinkAlert, alertID,
+			"the alerting engine could not be reached to verify the alert: "+lookup.Err.Error())
+		return link
+	}
+	link.Verified = true
+
+	if lookup.Alert == nil {
+		t.addIssue(IssueAlertNotFound, SeverityError, LinkAlert, alertID,
+			"the cited alert does not exist")
+		return link
+	}
+	link.Found = true
+	link.Title = lookup.Alert.Title
+	link.Severity = lookup.Alert.Severity
+	link.Status = lookup.Alert.Status
+	link.CreatedAt = lookup.Alert.CreatedAt
+
+	for _, id := range lookup.CaseIDs {
+		if id == caseID {
+			link.LinkedToCase = true
+			break
+		}
+	}
+	if !link.LinkedToCase {
+		t.addIssue(IssueAlertNotLinked, SeverityError, LinkAlert, alertID,
+			"the alert is not linked to the filing's investigation")
+	}
+	return link
+}
+
+func (t *Trace) checkEvidence(evidenceID uuid.UUID, evidence *models.Evidence, filing *models.SARFiling) EvidenceLink {
+	link := EvidenceLink{EvidenceID: evidenceID}
+	reference := evidenceID.String()
+
+	if evidence == nil {
+		t.addIssue(IssueEvidenceNotFound, SeverityError, LinkEvidence, reference,
+			"the cited evidence does not exist")
+		return link
+	}
+	link.Found = true
+	link.InvestigationID = &evidence.InvestigationID
+	link.Name = evidence.Name
+	link.Status = evidence.Status
+	link.IsAuthenticated = evidence.IsAuthenticated
+	link.FileHash = evidence.FileHash
+	link.CollectedAt = &evidence.CollectedAt
+
+	if evidence.InvestigationID != filing.InvestigationID {
+		t.addIssue(IssueEvidenceOtherCase, SeverityError, LinkEvidence, reference,
+			"the evidence belongs to a different investigation")
+	}
+	switch evidence.Status {
+	case models.EvidenceStatusArchived, models.EvidenceStatusRejected:
+		t.addIssue(IssueEvidenceWithdrawn, SeverityError, LinkEvidence, reference,
+			"the evidence was "+string(evidence.Status)+" after being cited")
+	}
+	if !evidence.IsAuthenticated {
+		t.addIssue(IssueEvidenceUnauthenticated, SeverityWarning, LinkEvidence, reference,
+			"the evidence has not been authenticated")
+	}
+	if filing.FiledAt != nil && evidence.CollectedAt.After(*filing.FiledAt) {
+		t.addIssue(IssueEvidenceAfterFiling, SeverityWarning, LinkEvidence, reference,
+			"the evidence was collected after the filing was submitted")
+	}
+	return link
+}
+
+func (t *Trace) addIssue(code string, severity Severity, link, reference, message string) {
+	t.Issues = append(t.Issues, Issue{
+		Code:      code,
+		Severity:  severity,
+		Link:      link,
+		Reference: reference,
+		Message:   message,
+	})
+}
+
+// Report is the traceability of every SAR filed in a period
+type Report struct {
+	PeriodStart time.Time     `json:"period_start"`
+	PeriodEnd   time.Time     `json:"period_end"`
+	GeneratedAt time.Time     `json:"generated_at"`
+	Summary     ReportSummary `json:"summary"`
+	Filings     []*Trace      `json:"filings"`
+}
+
+// ReportSummary counts complete and broken chains in a report
+type ReportSummary struct {
+	Filings    int            `json:"filings"`
+	Complete   int            `json:"complete"`
+	Incomplete int            `json:"incomplete"`
+	Warnings   int            `json:"warnings"`
+	IssueCodes map[string]int `json:"issue_codes"`
+}
+
+// NewReport summarizes the traces of the filings in a period
+func NewReport(from, to time.Time, traces []*Trace) *Report {
+	report := &Report{
+		PeriodStart: from,
+		PeriodEnd:   to,
+		GeneratedAt: time.Now().UTC(),
+		Summary:     ReportSummary{IssueCodes: map[string]int{}},
+		Filings:     traces,
+	}
+	if report.Filings == nil {
+		report.Filings = []*Trace{}
+	}
+
+	// Broken chains first so auditors see them at the top
+	sort.SliceStable(report.Filings, func(i, j int) bool {
+		return !report.Filings[i].Complete && report.Filings[j].Complete
+	})
+
+	for _, trace := range report.Filings {
+		report.Summary.Filings++
+		if trace.Complete {
+			report.Summary.Complete++
+		} else {
+			report.Summary.Incomplete++
+		}
+		for _, issue := range trace.Issues {
+			if issue.Severity == SeverityWarning {
+				report.Summary.Warnings++
+			}
+			report.Summary.IssueCodes[issue.Code]++
+		}
+	}
+	return report
+}
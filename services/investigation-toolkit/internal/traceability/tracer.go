@@ -0,0 +1,139 @@
+package traceability
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"investigation-toolkit/internal/models"
+)
+
+// Filings loads SAR filings and the evidence they cite
+type Filings interface {
+	GetFiledBetween(ctx context.Context, from, to time.Time) ([]models.SARFiling, error)
+	GetEvidenceByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Evidence, error)
+}
+
+// Cases loads the investigation a filing was raised from. It returns an
+// error for missing investigations as well as failed lookups.
+type Cases interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Investigation, error)
+}
+
+// Tracer assembles and validates the chains of SAR filings
+type Tracer struct {
+	filings     Filings
+	cases       Cases
+	alerts      AlertSource
+	concurrency int
+}
+
+// NewTracer creates a tracer. concurrency bounds the alert lookups made at
+// once per filing.
+func NewTracer(filings Filings, cases Cases, alerts AlertSource, concurrency int) *Tracer {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Tracer{
+		filings:     filings,
+		cases:       cases,
+		alerts:      alerts,
+		concurrency: concurrency,
+	}
+}
+
+// Trace validates the chain of one filing. Links that cannot be resolved
+// are reported as issues; only failing to load the filing's own evidence
+// is an error.
+func (t *Tracer) Trace(ctx context.Context, filing *models.SARFiling) (*Trace, error) {
+	chain := Chain{Filing: filing}
+
+	// The repositories do not distinguish a missing case from a failed
+	// lookup, so an unreachable database shows up as a missing case
+	if investigation, err := t.cases.GetByID(ctx, filing.InvestigationID); err == nil {
+		chain.Case = investigation
+	}
+
+	evidence, err := t.filings.GetEvidenceByIDs(ctx, filing.EvidenceIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load cited evidence")
+	}
+	chain.Evidence = make(map[uuid.UUID]*models.Evidence, len(evidence))
+	for i := range evidence {
+		chain.Evidence[evidence[i].ID] = &evidence[i]
+	}
+
+	chain.Alerts = t.lookupAlerts(ctx, filing.AlertIDs)
+
+	return Validate(chain), nil
+}
+
+// Report validates every SAR filed in [from, to)
+func (t *Tracer) Report(ctx context.Context, from, to time.Time) (*Report, error) {
+	filings, err := t.filings.GetFiledBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	traces := make([]*Trace, 0, len(filings))
+	for i := range filings {
+		trace, err := t.Trace(ctx, &filings[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to trace SAR filing %s", filings[i].ID)
+		}
+		traces = append(traces, trace)
+	}
+
+	return NewReport(from, to, traces), nil
+}
+
+func (t *Tracer) lookupAlerts(ctx context.Context, alertIDs []string) map[string]AlertLookup {
+	lookups := make(map[string]AlertLookup, len(alertIDs))
+	if t.alerts == nil {
+		for _, id := range alertIDs {
+			lookups[id] = AlertLookup{Err: errors.New("no alerting engine configured")}
+		}
+		return lookups
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, t.concurrency)
+	)
+	for _, id := range alertIDs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			lookup := t.lookupAlert(ctx, id)
+			mu.Lock()
+			lookups[id] = lookup
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	return lookups
+}
+
+func (t *Tracer) lookupAlert(ctx context.Context, alertID string) AlertLookup {
+	alert, err := t.alerts.GetAlert(ctx, alertID)
+	if err != nil {
+		return AlertLookup{Err: err}
+	}
+	if alert == nil {
+		return AlertLookup{}
+	}
+
+	caseIDs, err := t.alerts.ListAlertCases(ctx, alertID)
+	if err != nil {
+		return AlertLookup{Err: err}
+	}
+	return AlertLookup{Alert: alert, CaseIDs: caseIDs}
+}
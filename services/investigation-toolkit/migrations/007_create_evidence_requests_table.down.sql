@@ -0,0 +1,4 @@
+-- Drop evidence request tables
+DROP TRIGGER IF EXISTS update_evidence_requests_updated_at ON evidence_requests;
+DROP TABLE IF EXISTS evidence_request_submissions;
+DROP TABLE IF EXISTS evidence_requests;
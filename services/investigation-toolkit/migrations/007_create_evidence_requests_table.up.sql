@@ -0,0 +1,55 @@
+-- Create evidence requests table for document requests sent to external parties
+CREATE TABLE IF NOT EXISTS evidence_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    investigation_id UUID NOT NULL REFERENCES investigations(id) ON DELETE CASCADE,
+    token_hash VARCHAR(128) NOT NULL UNIQUE,
+    recipient_name VARCHAR(255) NOT NULL,
+    recipient_email VARCHAR(255),
+    recipient_type VARCHAR(50) NOT NULL CHECK (recipient_type IN ('bank_branch', 'customer', 'correspondent_bank', 'law_enforcement', 'other')),
+    instructions TEXT,
+    allowed_file_types TEXT[] NOT NULL,
+    max_files INTEGER NOT NULL DEFAULT 10 CHECK (max_files > 0),
+    max_file_size BIGINT NOT NULL CHECK (max_file_size > 0),
+    files_received INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'partially_received', 'fulfilled', 'expired', 'revoked')),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    requested_by UUID NOT NULL,
+    last_submission_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by UUID,
+    metadata JSONB DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create evidence request submissions table for every file received through a request link
+CREATE TABLE IF NOT EXISTS evidence_request_submissions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    request_id UUID NOT NULL REFERENCES evidence_requests(id) ON DELETE CASCADE,
+    evidence_id UUID REFERENCES evidence(id) ON DELETE SET NULL,
+    file_name VARCHAR(255) NOT NULL,
+    file_size BIGINT NOT NULL,
+    file_hash VARCHAR(128) NOT NULL,
+    mime_type VARCHAR(100),
+    scan_status VARCHAR(20) NOT NULL CHECK (scan_status IN ('clean', 'infected', 'error', 'skipped')),
+    scan_result TEXT,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('accepted', 'quarantined', 'rejected')),
+    submitter_ip VARCHAR(45),
+    submitter_note TEXT,
+    submitted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_evidence_requests_investigation_id ON evidence_requests(investigation_id);
+CREATE INDEX IF NOT EXISTS idx_evidence_requests_status ON evidence_requests(status);
+CREATE INDEX IF NOT EXISTS idx_evidence_requests_expires_at ON evidence_requests(expires_at);
+CREATE INDEX IF NOT EXISTS idx_evidence_requests_requested_by ON evidence_requests(requested_by);
+CREATE INDEX IF NOT EXISTS idx_evidence_request_submissions_request_id ON evidence_request_submissions(request_id);
+CREATE INDEX IF NOT EXISTS idx_evidence_request_submissions_evidence_id ON evidence_request_submissions(evidence_id);
+CREATE INDEX IF NOT EXISTS idx_evidence_request_submissions_file_hash ON evidence_request_submissions(file_hash);
+
+-- Create trigger to update updated_at timestamp
+CREATE TRIGGER update_evidence_requests_updated_at
+    BEFORE UPDATE ON evidence_requests
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
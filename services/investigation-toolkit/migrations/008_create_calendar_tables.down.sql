@@ -0,0 +1,5 @@
+-- Drop calendar tables
+DROP TRIGGER IF EXISTS update_calendar_events_updated_at ON calendar_events;
+DROP TABLE IF EXISTS calendar_feed_tokens;
+DROP TABLE IF EXISTS calendar_reminders;
+DROP TABLE IF EXISTS calendar_events;
@@ -0,0 +1,59 @@
+-- Create calendar events table for interviews, filing deadlines and court dates on cases
+CREATE TABLE IF NOT EXISTS calendar_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    investigation_id UUID NOT NULL REFERENCES investigations(id) ON DELETE CASCADE,
+    event_type VARCHAR(30) NOT NULL CHECK (event_type IN ('interview', 'filing_deadline', 'court_date', 'meeting', 'other')),
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    location VARCHAR(500),
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    all_day BOOLEAN NOT NULL DEFAULT false,
+    organizer_id UUID NOT NULL,
+    attendees UUID[] NOT NULL DEFAULT '{}',
+    reminder_minutes INTEGER[] NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'completed', 'cancelled')),
+    sequence INTEGER NOT NULL DEFAULT 0,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    cancelled_by UUID,
+    metadata JSONB DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at >= starts_at)
+);
+
+-- Create calendar reminders table, one row per participant and reminder offset
+CREATE TABLE IF NOT EXISTS calendar_reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES calendar_events(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    minutes_before INTEGER NOT NULL,
+    remind_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'cancelled')),
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (event_id, user_id, minutes_before)
+);
+
+-- Create calendar feed tokens table; calendar clients authenticate iCal feeds by URL token
+CREATE TABLE IF NOT EXISTS calendar_feed_tokens (
+    user_id UUID PRIMARY KEY,
+    token_hash VARCHAR(128) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_accessed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_calendar_events_investigation_id ON calendar_events(investigation_id);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_organizer_id ON calendar_events(organizer_id);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_attendees ON calendar_events USING GIN(attendees);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_starts_at ON calendar_events(starts_at);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_status ON calendar_events(status);
+CREATE INDEX IF NOT EXISTS idx_calendar_reminders_due ON calendar_reminders(remind_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_calendar_reminders_event_id ON calendar_reminders(event_id);
+
+-- Create trigger to update updated_at timestamp
+CREATE TRIGGER update_calendar_events_updated_at
+    BEFORE UPDATE ON calendar_events
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
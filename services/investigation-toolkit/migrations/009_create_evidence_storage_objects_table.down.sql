@@ -0,0 +1,3 @@
+-- Drop evidence storage objects table
+DROP TRIGGER IF EXISTS update_evidence_storage_objects_updated_at ON evidence_storage_objects;
+DROP TABLE IF EXISTS evidence_storage_objects;
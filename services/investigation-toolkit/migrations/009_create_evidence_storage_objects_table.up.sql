@@ -0,0 +1,27 @@
+-- Create evidence storage objects table tracking the storage tier of each evidence file
+CREATE TABLE IF NOT EXISTS evidence_storage_objects (
+    evidence_id UUID PRIMARY KEY REFERENCES evidence(id) ON DELETE CASCADE,
+    source_path VARCHAR(500) NOT NULL,
+    object_key VARCHAR(1000) NOT NULL,
+    file_size BIGINT,
+    tier VARCHAR(20) NOT NULL DEFAULT 'standard' CHECK (tier IN ('standard', 'infrequent_access', 'archive')),
+    tier_changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    restore_status VARCHAR(20) NOT NULL DEFAULT 'none' CHECK (restore_status IN ('none', 'in_progress', 'restored')),
+    restore_requested_at TIMESTAMP WITH TIME ZONE,
+    restore_requested_by UUID,
+    restore_ready_at TIMESTAMP WITH TIME ZONE,
+    restore_expires_at TIMESTAMP WITH TIME ZONE,
+    last_accessed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_evidence_storage_objects_tier ON evidence_storage_objects(tier);
+CREATE INDEX IF NOT EXISTS idx_evidence_storage_objects_restore ON evidence_storage_objects(restore_status) WHERE restore_status <> 'none';
+
+-- Create trigger to update updated_at timestamp
+CREATE TRIGGER update_evidence_storage_objects_updated_at
+    BEFORE UPDATE ON evidence_storage_objects
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+-- Drop search reindex jobs table
+DROP TRIGGER IF EXISTS update_search_reindex_jobs_updated_at ON search_reindex_jobs;
+DROP TABLE IF EXISTS search_reindex_jobs;
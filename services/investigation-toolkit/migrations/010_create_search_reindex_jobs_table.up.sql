@@ -0,0 +1,28 @@
+-- Create search reindex jobs table tracking rebuilds of versioned search indices
+CREATE TABLE IF NOT EXISTS search_reindex_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    index_name VARCHAR(100) NOT NULL,
+    source_index VARCHAR(255) NOT NULL,
+    target_index VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'dual_writing' CHECK (status IN ('dual_writing', 'backfilling', 'completed', 'failed', 'cancelled')),
+    task_id VARCHAR(255),
+    documents_total BIGINT NOT NULL DEFAULT 0,
+    documents_copied BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    requested_by UUID,
+    backfill_started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Only one rebuild of an index may run at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_reindex_jobs_active
+    ON search_reindex_jobs(index_name) WHERE status IN ('dual_writing', 'backfilling');
+CREATE INDEX IF NOT EXISTS idx_search_reindex_jobs_created_at ON search_reindex_jobs(created_at DESC);
+
+-- Create trigger to update updated_at timestamp
+CREATE TRIGGER update_search_reindex_jobs_updated_at
+    BEFORE UPDATE ON search_reindex_jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+-- Drop SAR filings table
+DROP TRIGGER IF EXISTS update_sar_filings_updated_at ON sar_filings;
+DROP TABLE IF EXISTS sar_filings;
@@ -0,0 +1,36 @@
+-- Create sar_filings table recording suspicious activity reports and the alerts and evidence they cite
+CREATE TABLE IF NOT EXISTS sar_filings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    investigation_id UUID NOT NULL REFERENCES investigations(id) ON DELETE RESTRICT,
+    filing_type VARCHAR(20) NOT NULL CHECK (filing_type IN ('initial', 'continuing', 'correction')),
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'filed', 'withdrawn')),
+    reference VARCHAR(255),
+    prior_filing_id UUID REFERENCES sar_filings(id) ON DELETE RESTRICT,
+    alert_ids TEXT[] NOT NULL DEFAULT '{}',
+    evidence_ids UUID[] NOT NULL DEFAULT '{}',
+    activity_start TIMESTAMP WITH TIME ZONE,
+    activity_end TIMESTAMP WITH TIME ZONE,
+    narrative TEXT,
+    filed_at TIMESTAMP WITH TIME ZONE,
+    filed_by UUID,
+    created_by UUID NOT NULL,
+    metadata JSONB DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    -- A filed report must carry the regulator's reference and filing date
+    CONSTRAINT sar_filings_filed_check CHECK (status <> 'filed' OR (reference IS NOT NULL AND filed_at IS NOT NULL)),
+    -- Continuing reports and corrections follow an earlier filing
+    CONSTRAINT sar_filings_prior_check CHECK (filing_type = 'initial' OR prior_filing_id IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sar_filings_reference ON sar_filings(reference) WHERE reference IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_sar_filings_investigation_id ON sar_filings(investigation_id);
+CREATE INDEX IF NOT EXISTS idx_sar_filings_filed_at ON sar_filings(filed_at) WHERE status = 'filed';
+CREATE INDEX IF NOT EXISTS idx_sar_filings_alert_ids ON sar_filings USING GIN (alert_ids);
+
+-- Create trigger to update updated_at timestamp
+CREATE TRIGGER update_sar_filings_updated_at
+    BEFORE UPDATE ON sar_filings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,7 @@
+-- Drop case data residency tables and columns
+DROP TABLE IF EXISTS case_exports;
+DROP TABLE IF EXISTS residency_access_log;
+DROP TABLE IF EXISTS residency_grants;
+ALTER TABLE evidence DROP COLUMN IF EXISTS storage_region;
+DROP INDEX IF EXISTS idx_investigations_region;
+ALTER TABLE investigations DROP COLUMN IF EXISTS region;
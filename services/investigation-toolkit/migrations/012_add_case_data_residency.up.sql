@@ -0,0 +1,54 @@
+-- Pin cases to a data residency region. Untagged cases predate residency
+-- controls and are reported until they are tagged.
+ALTER TABLE investigations ADD COLUMN IF NOT EXISTS region VARCHAR(50);
+CREATE INDEX IF NOT EXISTS idx_investigations_region ON investigations(region);
+
+-- Region whose storage holds each evidence file
+ALTER TABLE evidence ADD COLUMN IF NOT EXISTS storage_region VARCHAR(50);
+
+-- Create residency_grants table authorizing named users in another region to access a case
+CREATE TABLE IF NOT EXISTS residency_grants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    investigation_id UUID NOT NULL REFERENCES investigations(id) ON DELETE CASCADE,
+    region VARCHAR(50) NOT NULL,
+    granted_to UUID NOT NULL,
+    reason TEXT NOT NULL,
+    granted_by UUID NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_residency_grants_active ON residency_grants(investigation_id, region, granted_to) WHERE revoked_at IS NULL;
+
+-- Create residency_access_log table recording cross-region requests and whether they were allowed
+CREATE TABLE IF NOT EXISTS residency_access_log (
+    id BIGSERIAL PRIMARY KEY,
+    investigation_id UUID NOT NULL,
+    case_region VARCHAR(50) NOT NULL,
+    request_region VARCHAR(50) NOT NULL,
+    user_id UUID,
+    method VARCHAR(10) NOT NULL,
+    path VARCHAR(500) NOT NULL,
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('allowed', 'denied')),
+    grant_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_residency_access_log_created_at ON residency_access_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_residency_access_log_investigation_id ON residency_access_log(investigation_id);
+
+-- Create case_exports table tracking case bundles and the region they were written to
+CREATE TABLE IF NOT EXISTS case_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    investigation_id UUID NOT NULL REFERENCES investigations(id) ON DELETE CASCADE,
+    region VARCHAR(50) NOT NULL,
+    file_path VARCHAR(1000) NOT NULL,
+    file_size BIGINT,
+    file_hash VARCHAR(64),
+    exported_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_case_exports_investigation_id ON case_exports(investigation_id);
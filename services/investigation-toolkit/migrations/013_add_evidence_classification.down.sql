@@ -0,0 +1,12 @@
+-- Drop evidence classification and restricted participants
+DROP TABLE IF EXISTS evidence_classification_changes;
+-- The original active/removed uniqueness constraint is not restored, since
+-- participants re-added since the up migration would violate it
+DROP INDEX IF EXISTS idx_collaboration_active_participant;
+DELETE FROM collaboration WHERE role = 'external_counsel';
+ALTER TABLE collaboration DROP COLUMN IF EXISTS clearance;
+ALTER TABLE collaboration DROP CONSTRAINT IF EXISTS collaboration_role_check;
+ALTER TABLE collaboration ADD CONSTRAINT collaboration_role_check
+    CHECK (role IN ('lead_investigator', 'investigator', 'analyst', 'reviewer', 'observer', 'consultant'));
+DROP INDEX IF EXISTS idx_evidence_investigation_classification;
+ALTER TABLE evidence DROP COLUMN IF EXISTS classification;
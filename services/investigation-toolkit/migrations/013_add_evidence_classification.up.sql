@@ -0,0 +1,32 @@
+-- Classify evidence so restricted case participants only see the levels
+-- they are cleared for. Existing evidence is internal.
+ALTER TABLE evidence ADD COLUMN IF NOT EXISTS classification VARCHAR(20) NOT NULL DEFAULT 'internal'
+    CHECK (classification IN ('public', 'internal', 'confidential', 'restricted'));
+CREATE INDEX IF NOT EXISTS idx_evidence_investigation_classification ON evidence(investigation_id, classification);
+
+-- Allow outside counsel as case participants, with the highest
+-- classification they are cleared to see
+ALTER TABLE collaboration DROP CONSTRAINT IF EXISTS collaboration_role_check;
+ALTER TABLE collaboration ADD CONSTRAINT collaboration_role_check
+    CHECK (role IN ('lead_investigator', 'investigator', 'analyst', 'reviewer', 'observer', 'consultant', 'external_counsel'));
+ALTER TABLE collaboration ADD COLUMN IF NOT EXISTS clearance VARCHAR(20)
+    CHECK (clearance IN ('public', 'internal', 'confidential', 'restricted'));
+
+-- The original constraint allowed one active and one removed row per user;
+-- participants can be removed and re-added any number of times
+ALTER TABLE collaboration DROP CONSTRAINT IF EXISTS collaboration_unique_active_user_investigation;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collaboration_active_participant
+    ON collaboration(investigation_id, user_id) WHERE is_active;
+
+-- Create evidence_classification_changes table recording every reclassification
+CREATE TABLE IF NOT EXISTS evidence_classification_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    evidence_id UUID NOT NULL REFERENCES evidence(id) ON DELETE CASCADE,
+    from_classification VARCHAR(20) NOT NULL,
+    to_classification VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    changed_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_evidence_classification_changes_evidence_id ON evidence_classification_changes(evidence_id, created_at);
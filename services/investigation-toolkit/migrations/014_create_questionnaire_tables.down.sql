@@ -0,0 +1,5 @@
+-- Drop questionnaire tables
+DROP TRIGGER IF EXISTS update_questionnaire_assignments_updated_at ON questionnaire_assignments;
+DROP TRIGGER IF EXISTS update_questionnaire_templates_updated_at ON questionnaire_templates;
+DROP TABLE IF EXISTS questionnaire_assignments;
+DROP TABLE IF EXISTS questionnaire_templates;
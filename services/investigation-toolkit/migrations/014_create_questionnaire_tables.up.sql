@@ -0,0 +1,54 @@
+-- Create questionnaire_templates table holding the forms used in periodic reviews
+CREATE TABLE IF NOT EXISTS questionnaire_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'published', 'archived')),
+    questions JSONB NOT NULL DEFAULT '[]',
+    created_by UUID NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_questionnaire_templates_status ON questionnaire_templates(status);
+
+-- Create questionnaire_assignments table. Each assignment keeps a copy of its
+-- template's questions, which its answers are validated against.
+CREATE TABLE IF NOT EXISTS questionnaire_assignments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    investigation_id UUID NOT NULL REFERENCES investigations(id) ON DELETE CASCADE,
+    template_id UUID NOT NULL REFERENCES questionnaire_templates(id) ON DELETE RESTRICT,
+    template_name VARCHAR(255) NOT NULL,
+    questions JSONB NOT NULL,
+    answers JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'assigned'
+        CHECK (status IN ('assigned', 'in_progress', 'completed', 'cancelled')),
+    assigned_to UUID NOT NULL,
+    assigned_by UUID NOT NULL,
+    due_date TIMESTAMP WITH TIME ZONE,
+    submitted_by UUID,
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    cancelled_by UUID,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT questionnaire_assignments_submitted_check
+        CHECK (status <> 'completed' OR (submitted_by IS NOT NULL AND submitted_at IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_questionnaire_assignments_investigation_id ON questionnaire_assignments(investigation_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_questionnaire_assignments_assigned_to ON questionnaire_assignments(assigned_to)
+    WHERE status IN ('assigned', 'in_progress');
+
+-- Create triggers to update updated_at timestamp
+CREATE TRIGGER update_questionnaire_templates_updated_at
+    BEFORE UPDATE ON questionnaire_templates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_questionnaire_assignments_updated_at
+    BEFORE UPDATE ON questionnaire_assignments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+-- Drop case archive tables and indexes
+DROP INDEX IF EXISTS idx_investigations_closed_at;
+DROP TABLE IF EXISTS case_archives;
@@ -0,0 +1,27 @@
+-- Create case_archives table recording closed cases moving to the archive and
+-- being re-opened. A case has at most one archive that has not been re-opened.
+CREATE TABLE IF NOT EXISTS case_archives (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    investigation_id UUID NOT NULL REFERENCES investigations(id) ON DELETE CASCADE,
+    archived_by UUID,
+    archive_reason TEXT NOT NULL,
+    closed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    evidence_count INTEGER NOT NULL DEFAULT 0,
+    evidence_manifest BYTEA NOT NULL,
+    manifest_sha256 VARCHAR(64) NOT NULL,
+    archived_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    reopened_at TIMESTAMP WITH TIME ZONE,
+    reopened_by UUID,
+    reopen_reason TEXT,
+
+    CONSTRAINT case_archives_reopen_complete CHECK (
+        (reopened_at IS NULL AND reopened_by IS NULL AND reopen_reason IS NULL) OR
+        (reopened_at IS NOT NULL AND reopened_by IS NOT NULL AND reopen_reason IS NOT NULL)
+    )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_case_archives_active ON case_archives(investigation_id) WHERE reopened_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_case_archives_investigation_id ON case_archives(investigation_id, archived_at);
+
+-- Closed cases are swept into the archive by the age of their closure
+CREATE INDEX IF NOT EXISTS idx_investigations_closed_at ON investigations(closed_at) WHERE status = 'closed';
@@ -0,0 +1,2 @@
+-- Drop law enforcement production tables and indexes
+DROP TABLE IF EXISTS law_enforcement_productions;
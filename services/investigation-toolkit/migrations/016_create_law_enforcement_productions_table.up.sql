@@ -0,0 +1,37 @@
+-- Create law_enforcement_productions table recording case artifacts produced
+-- to law-enforcement agencies. The produced file is kept exactly as sent.
+CREATE TABLE IF NOT EXISTS law_enforcement_productions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    investigation_id UUID NOT NULL REFERENCES investigations(id) ON DELETE RESTRICT,
+    template_id VARCHAR(100) NOT NULL,
+    agency VARCHAR(255) NOT NULL,
+    request_reference VARCHAR(255) NOT NULL,
+    evidence_ids UUID[] NOT NULL DEFAULT '{}',
+    timeline_ids UUID[] NOT NULL DEFAULT '{}',
+    sar_filing_ids UUID[] NOT NULL DEFAULT '{}',
+    redactions JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    requested_by UUID NOT NULL,
+    approved_by UUID,
+    approved_at TIMESTAMP WITH TIME ZONE,
+    produced_by UUID,
+    produced_at TIMESTAMP WITH TIME ZONE,
+    file_name VARCHAR(255),
+    content_type VARCHAR(100),
+    file_size BIGINT,
+    file_sha256 VARCHAR(64),
+    content BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT law_enforcement_productions_status CHECK (status IN ('draft', 'approved', 'produced')),
+    CONSTRAINT law_enforcement_productions_approval CHECK (
+        status = 'draft' OR (approved_by IS NOT NULL AND approved_at IS NOT NULL AND approved_by <> requested_by)
+    ),
+    CONSTRAINT law_enforcement_productions_produced CHECK (
+        status <> 'produced' OR (produced_by IS NOT NULL AND produced_at IS NOT NULL AND content IS NOT NULL AND file_sha256 IS NOT NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_law_enforcement_productions_investigation_id ON law_enforcement_productions(investigation_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_law_enforcement_productions_agency ON law_enforcement_productions(agency, produced_at) WHERE status = 'produced';
@@ -0,0 +1,6 @@
+-- Drop storage quota tables and columns
+ALTER TABLE evidence_requests DROP COLUMN IF EXISTS tenant_id;
+DROP TABLE IF EXISTS storage_quota_charges;
+DROP TABLE IF EXISTS storage_quota_reservations;
+DROP TABLE IF EXISTS storage_quota_usage;
+DROP TABLE IF EXISTS storage_quota_limits;
@@ -0,0 +1,55 @@
+-- Create storage_quota_limits table holding the limits of tenants and users
+-- that differ from the configured defaults. A NULL limit keeps the default.
+CREATE TABLE IF NOT EXISTS storage_quota_limits (
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('tenant', 'user')),
+    subject_id VARCHAR(64) NOT NULL,
+    limit_bytes BIGINT CHECK (limit_bytes >= 0),
+    limit_files BIGINT CHECK (limit_files >= 0),
+    updated_by UUID NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (scope, subject_id)
+);
+
+-- Create storage_quota_usage table with the evidence storage each tenant and
+-- user holds. Uploads lock these rows while reserving so that concurrent
+-- uploads cannot together pass a limit.
+CREATE TABLE IF NOT EXISTS storage_quota_usage (
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('tenant', 'user')),
+    subject_id VARCHAR(64) NOT NULL,
+    used_bytes BIGINT NOT NULL DEFAULT 0 CHECK (used_bytes >= 0),
+    used_files BIGINT NOT NULL DEFAULT 0 CHECK (used_files >= 0),
+    reserved_bytes BIGINT NOT NULL DEFAULT 0 CHECK (reserved_bytes >= 0),
+    reserved_files BIGINT NOT NULL DEFAULT 0 CHECK (reserved_files >= 0),
+    warned_level VARCHAR(20) NOT NULL DEFAULT 'ok' CHECK (warned_level IN ('ok', 'warning', 'exceeded')),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (scope, subject_id)
+);
+
+-- Create storage_quota_reservations table holding quota for uploads in progress
+CREATE TABLE IF NOT EXISTS storage_quota_reservations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL,
+    user_id UUID NOT NULL,
+    bytes BIGINT NOT NULL CHECK (bytes >= 0),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_quota_reservations_expires_at ON storage_quota_reservations(expires_at);
+
+-- Create storage_quota_charges table recording the quota each stored file takes
+CREATE TABLE IF NOT EXISTS storage_quota_charges (
+    object_type VARCHAR(50) NOT NULL,
+    object_id UUID NOT NULL,
+    tenant_id VARCHAR(64) NOT NULL,
+    user_id UUID NOT NULL,
+    bytes BIGINT NOT NULL CHECK (bytes >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (object_type, object_id)
+);
+
+-- Tenant charged for the files received through each evidence request
+ALTER TABLE evidence_requests ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
@@ -0,0 +1,4 @@
+-- Drop offline sync tables
+DROP TABLE IF EXISTS offline_sync_chunks;
+DROP TABLE IF EXISTS offline_sync_items;
+DROP TABLE IF EXISTS offline_sync_sessions;
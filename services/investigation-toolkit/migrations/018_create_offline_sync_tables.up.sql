@@ -0,0 +1,69 @@
+-- Create offline_sync_sessions table tracking uploads of evidence collected
+-- offline on field devices
+CREATE TABLE IF NOT EXISTS offline_sync_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    investigation_id UUID NOT NULL REFERENCES investigations(id) ON DELETE CASCADE,
+    device_id VARCHAR(255) NOT NULL,
+    manifest_hash VARCHAR(64) NOT NULL,
+    offline_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    offline_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    conflict_strategy VARCHAR(20) NOT NULL DEFAULT 'keep_both' CHECK (conflict_strategy IN ('keep_both', 'server_wins', 'client_wins')),
+    chunk_size BIGINT NOT NULL CHECK (chunk_size > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'completing', 'completed', 'expired', 'cancelled')),
+    tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
+    created_by UUID NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CHECK (offline_until >= offline_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_offline_sync_sessions_investigation_id ON offline_sync_sessions(investigation_id);
+CREATE INDEX IF NOT EXISTS idx_offline_sync_sessions_open ON offline_sync_sessions(expires_at) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_offline_sync_sessions_device ON offline_sync_sessions(investigation_id, device_id, manifest_hash);
+
+-- Create offline_sync_items table with the evidence files listed in each
+-- session's manifest and what became of them
+CREATE TABLE IF NOT EXISTS offline_sync_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES offline_sync_sessions(id) ON DELETE CASCADE,
+    client_item_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    evidence_type VARCHAR(50) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    file_size BIGINT NOT NULL CHECK (file_size >= 0),
+    file_hash VARCHAR(64) NOT NULL,
+    mime_type VARCHAR(255),
+    collected_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    base_evidence_id UUID REFERENCES evidence(id) ON DELETE SET NULL,
+    base_file_hash VARCHAR(64),
+    local_custody JSONB NOT NULL DEFAULT '[]',
+    metadata JSONB DEFAULT '{}',
+    tags TEXT[] DEFAULT '{}',
+    total_chunks INTEGER NOT NULL CHECK (total_chunks > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'synced', 'duplicate', 'conflict', 'hash_mismatch', 'rejected')),
+    status_reason TEXT,
+    evidence_id UUID REFERENCES evidence(id) ON DELETE SET NULL,
+    conflict_evidence_id UUID REFERENCES evidence(id) ON DELETE SET NULL,
+    synced_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (session_id, client_item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_offline_sync_items_client_item_id ON offline_sync_items(client_item_id) WHERE status IN ('synced', 'conflict');
+
+-- Create offline_sync_chunks table recording the verified chunks received
+-- for each item, so interrupted uploads resume with the missing chunks
+CREATE TABLE IF NOT EXISTS offline_sync_chunks (
+    item_id UUID NOT NULL REFERENCES offline_sync_items(id) ON DELETE CASCADE,
+    chunk_index INTEGER NOT NULL CHECK (chunk_index >= 0),
+    size BIGINT NOT NULL CHECK (size >= 0),
+    chunk_hash VARCHAR(64) NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (item_id, chunk_index)
+);
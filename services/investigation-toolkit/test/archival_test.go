@@ -0,0 +1,335 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/archival"
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+	"investigation-toolkit/internal/residency"
+	"investigation-toolkit/internal/search"
+)
+
+func archivalConfig() config.ArchivalConfig {
+	return config.ArchivalConfig{
+		Enabled:         true,
+		ClosedAfterDays: 90,
+		SweepInterval:   time.Hour,
+		BatchSize:       10,
+	}
+}
+
+func closedCase(closedFor time.Duration) *models.Investigation {
+	closedAt := time.Now().Add(-closedFor)
+	return &models.Investigation{
+		ID:       uuid.New(),
+		Title:    "Structuring review",
+		Status:   models.StatusClosed,
+		ClosedAt: &closedAt,
+	}
+}
+
+// fakeArchivalStore keeps cases, evidence and archives in memory
+type fakeArchivalStore struct {
+	cases    map[uuid.UUID]*models.Investigation
+	evidence map[uuid.UUID]*models.Evidence
+	filings  map[uuid.UUID]uuid.UUID
+	archives []*models.CaseArchive
+}
+
+func newFakeArchivalStore() *fakeArchivalStore {
+	return &fakeArchivalStore{
+		cases:    map[uuid.UUID]*models.Investigation{},
+		evidence: map[uuid.UUID]*models.Evidence{},
+		filings:  map[uuid.UUID]uuid.UUID{},
+	}
+}
+
+func (f *fakeArchivalStore) addEvidence(investigationID uuid.UUID, status models.EvidenceStatus) *models.Evidence {
+	path := "evidence/" + uuid.NewString()
+	evidence := &models.Evidence{ID: uuid.New(), InvestigationID: investigationID, Status: status, FilePath: &path}
+	f.evidence[evidence.ID] = evidence
+	return evidence
+}
+
+func (f *fakeArchivalStore) GetInvestigation(ctx context.Context, id uuid.UUID) (*models.Investigation, error) {
+	investigation, ok := f.cases[id]
+	if !ok {
+		return nil, repository.ErrCaseNotFound
+	}
+	copied := *investigation
+	return &copied, nil
+}
+
+func (f *fakeArchivalStore) ListArchivable(ctx context.Context, closedBefore time.Time, limit int) ([]models.Investigation, error) {
+	var investigations []models.Investigation
+	for _, investigation := range f.cases {
+		if investigation.Status == models.StatusClosed && investigation.ClosedAt.Before(closedBefore) {
+			investigations = append(investigations, *investigation)
+		}
+	}
+	return investigations, nil
+}
+
+func (f *fakeArchivalStore) ListCaseEvidence(ctx context.Context, investigationID uuid.UUID) ([]models.Evidence, error) {
+	var evidence []models.Evidence
+	for _, item := range f.evidence {
+		if item.InvestigationID == investigationID {
+			evidence = append(evidence, *item)
+		}
+	}
+	return evidence, nil
+}
+
+func (f *fakeArchivalStore) Archive(ctx context.Context, archive *models.CaseArchive, evidenceIDs []uuid.UUID) error {
+	investigation := f.cases[archive.InvestigationID]
+	if investigation.Status != models.StatusClosed {
+		return repository.ErrCaseNotClosed
+	}
+	investigation.Status = models.StatusArchived
+	for _, id := range evidenceIDs {
+		f.evidence[id].Status = models.EvidenceStatusArchived
+	}
+	archive.ID = uuid.New()
+	archive.ArchivedAt = time.Now()
+	f.archives = append(f.archives, archive)
+	return nil
+}
+
+func (f *fakeArchivalStore) ActiveArchive(ctx context.Context, investigationID uuid.UUID) (*models.CaseArchive, error) {
+	for _, archive := range f.archives {
+		if archive.InvestigationID == investigationID && archive.ReopenedAt == nil {
+			return archive, nil
+		}
+	}
+	return nil, repository.ErrCaseNotArchived
+}
+
+func (f *fakeArchivalStore) Reopen(ctx context.Context, archive *models.CaseArchive, evidenceStatuses map[uuid.UUID]models.EvidenceStatus) error {
+	now := time.Now()
+	archive.ReopenedAt = &now
+	investigation := f.cases[archive.InvestigationID]
+	investigation.Status = models.StatusInProgress
+	investigation.ClosedAt = nil
+	for id, status := range evidenceStatuses {
+		f.evidence[id].Status = status
+	}
+	return nil
+}
+
+func (f *fakeArchivalStore) ListArchives(ctx context.Context, investigationID uuid.UUID) ([]models.CaseArchive, error) {
+	var archives []models.CaseArchive
+	for _, archive := range f.archives {
+		if archive.InvestigationID == investigationID {
+			archives = append(archives, *archive)
+		}
+	}
+	return archives, nil
+}
+
+func (f *fakeArchivalStore) IsArchived(ctx context.Context, investigationID uuid.UUID) (bool, error) {
+	_, err := f.ActiveArchive(ctx, investigationID)
+	return err == nil, nil
+}
+
+func (f *fakeArchivalStore) GetEvidenceCase(ctx context.Context, evidenceID uuid.UUID) (uuid.UUID, error) {
+	evidence, ok := f.evidence[evidenceID]
+	if !ok {
+		return uuid.Nil, repository.ErrCaseNotFound
+	}
+	return evidence.InvestigationID, nil
+}
+
+func (f *fakeArchivalStore) GetSARFilingCase(ctx context.Context, filingID uuid.UUID) (uuid.UUID, error) {
+	investigationID, ok := f.filings[filingID]
+	if !ok {
+		return uuid.Nil, repository.ErrCaseNotFound
+	}
+	return investigationID, nil
+}
+
+// fakeIndexer records the documents in each search index
+type fakeIndexer struct {
+	documents map[string]map[string]interface{}
+}
+
+func newFakeIndexer() *fakeIndexer {
+	return &fakeIndexer{documents: map[string]map[string]interface{}{
+		search.IndexInvestigations: {},
+		search.IndexEvidence:       {},
+	}}
+}
+
+func (f *fakeIndexer) Index(ctx context.Context, index, id string, document interface{}) error {
+	f.documents[index][id] = document
+	return nil
+}
+
+func (f *fakeIndexer) Delete(ctx context.Context, index, id string) error {
+	delete(f.documents[index], id)
+	return nil
+}
+
+func TestArchivalPolicyEligible(t *testing.T) {
+	policy := archival.NewPolicy(archivalConfig())
+	now := time.Now()
+	day := 24 * time.Hour
+
+	assert.True(t, policy.Eligible(closedCase(120*day), now))
+	assert.False(t, policy.Eligible(closedCase(30*day), now))
+
+	open := closedCase(120 * day)
+	open.Status = models.StatusInProgress
+	assert.False(t, policy.Eligible(open, now), "only closed cases are archived")
+
+	noClosedAt := closedCase(120 * day)
+	noClosedAt.ClosedAt = nil
+	assert.False(t, policy.Eligible(noClosedAt, now))
+}
+
+func TestArchivalManifestRoundTrip(t *testing.T) {
+	path := "evidence/statement.pdf"
+	hash := "abc123"
+	size := int64(2048)
+	evidence := []models.Evidence{
+		{ID: uuid.New(), Status: models.EvidenceStatusAuthenticated, FilePath: &path, FileHash: &hash, FileSize: &size},
+		{ID: uuid.New(), Status: models.EvidenceStatusUnderReview},
+	}
+
+	manifest, checksum, err := archival.EncodeManifest(evidence)
+	require.NoError(t, err)
+	assert.Len(t, checksum, 64)
+
+	entries, err := archival.DecodeManifest(manifest, checksum)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, evidence[0].ID, entries[0].EvidenceID)
+	assert.Equal(t, models.EvidenceStatusAuthenticated, entries[0].Status)
+	assert.Equal(t, path, *entries[0].FilePath)
+	assert.Equal(t, size, *entries[0].FileSize)
+	assert.Nil(t, entries[1].FilePath)
+
+	// A tampered manifest fails its checksum
+	tampered := append([]byte{}, manifest...)
+	tampered[len(tampered)-1] ^= 0xff
+	_, err = archival.DecodeManifest(tampered, checksum)
+	assert.True(t, errors.Is(err, archival.ErrManifestCorrupt))
+
+	// Empty cases still have a readable manifest
+	manifest, checksum, err = archival.EncodeManifest(nil)
+	require.NoError(t, err)
+	entries, err = archival.DecodeManifest(manifest, checksum)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestArchivalSweepAndReopen(t *testing.T) {
+	store := newFakeArchivalStore()
+	indexer := newFakeIndexer()
+	service := archival.NewService(store, indexer, archivalConfig(), zap.NewNop())
+	ctx := context.Background()
+
+	aged := closedCase(120 * 24 * time.Hour)
+	recent := closedCase(10 * 24 * time.Hour)
+	store.cases[aged.ID] = aged
+	store.cases[recent.ID] = recent
+	authenticated := store.addEvidence(aged.ID, models.EvidenceStatusAuthenticated)
+	rejected := store.addEvidence(aged.ID, models.EvidenceStatusRejected)
+	indexer.documents[search.IndexInvestigations][aged.ID.String()] = aged
+	indexer.documents[search.IndexEvidence][authenticated.ID.String()] = authenticated
+
+	result, err := service.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Archived)
+	assert.Equal(t, 0, result.Failed)
+
+	assert.Equal(t, models.StatusArchived, store.cases[aged.ID].Status)
+	assert.Equal(t, models.StatusClosed, store.cases[recent.ID].Status)
+	assert.Equal(t, models.EvidenceStatusArchived, authenticated.Status)
+	assert.Equal(t, models.EvidenceStatusArchived, rejected.Status)
+	assert.Empty(t, indexer.documents[search.IndexInvestigations], "archived cases leave search")
+	assert.Empty(t, indexer.documents[search.IndexEvidence])
+
+	require.Len(t, store.archives, 1)
+	assert.Nil(t, store.archives[0].ArchivedBy, "sweep archives have no user")
+	assert.Equal(t, 2, store.archives[0].EvidenceCount)
+
+	// Changes to the case and its evidence are blocked while it is archived
+	archived, err := service.IsArchived(ctx, residency.Scope{Kind: residency.ScopeCase, ID: aged.ID})
+	require.NoError(t, err)
+	assert.True(t, archived)
+	archived, err = service.IsArchived(ctx, residency.Scope{Kind: residency.ScopeEvidence, ID: rejected.ID})
+	require.NoError(t, err)
+	assert.True(t, archived)
+	archived, err = service.IsArchived(ctx, residency.Scope{Kind: residency.ScopeCase, ID: recent.ID})
+	require.NoError(t, err)
+	assert.False(t, archived)
+
+	// A second sweep finds nothing new
+	result, err = service.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Archived)
+
+	userID := uuid.New()
+	_, _, err = service.Reopen(ctx, aged.ID, userID, "  ")
+	assert.ErrorIs(t, err, archival.ErrReasonRequired)
+
+	investigation, archive, err := service.Reopen(ctx, aged.ID, userID, "New alert on the customer")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusInProgress, investigation.Status)
+	assert.Equal(t, userID, *archive.ReopenedBy)
+	assert.Equal(t, "New alert on the customer", *archive.ReopenReason)
+	assert.Equal(t, models.EvidenceStatusAuthenticated, authenticated.Status, "evidence statuses are restored")
+	assert.Equal(t, models.EvidenceStatusRejected, rejected.Status)
+	assert.Contains(t, indexer.documents[search.IndexInvestigations], aged.ID.String())
+	assert.Len(t, indexer.documents[search.IndexEvidence], 2)
+
+	archived, err = service.IsArchived(ctx, residency.Scope{Kind: residency.ScopeCase, ID: aged.ID})
+	require.NoError(t, err)
+	assert.False(t, archived)
+
+	_, _, err = service.Reopen(ctx, aged.ID, userID, "again")
+	assert.ErrorIs(t, err, repository.ErrCaseNotArchived)
+
+	archives, err := service.Archives(ctx, aged.ID)
+	require.NoError(t, err)
+	assert.Len(t, archives, 1)
+}
+
+func TestArchivalManualArchive(t *testing.T) {
+	store := newFakeArchivalStore()
+	service := archival.NewService(store, nil, archivalConfig(), zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	recent := closedCase(24 * time.Hour)
+	store.cases[recent.ID] = recent
+
+	_, err := service.Archive(ctx, recent.ID, userID, "")
+	assert.ErrorIs(t, err, archival.ErrReasonRequired)
+
+	// Manual archives do not wait for the closed case age
+	archive, err := service.Archive(ctx, recent.ID, userID, "Duplicate of another case")
+	require.NoError(t, err)
+	assert.Equal(t, userID, *archive.ArchivedBy)
+	assert.Equal(t, models.StatusArchived, store.cases[recent.ID].Status)
+
+	open := closedCase(0)
+	open.Status = models.StatusInProgress
+	open.ClosedAt = nil
+	store.cases[open.ID] = open
+	_, err = service.Archive(ctx, open.ID, userID, "Not needed")
+	assert.ErrorIs(t, err, repository.ErrCaseNotClosed)
+
+	_, err = service.Archive(ctx, uuid.New(), userID, "Missing")
+	assert.ErrorIs(t, err, repository.ErrCaseNotFound)
+}
@@ -0,0 +1,162 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"investigation-toolkit/internal/calendar"
+	"investigation-toolkit/internal/models"
+)
+
+func calendarEvent(organizer uuid.UUID, start, end time.Time, attendees ...uuid.UUID) models.CalendarEvent {
+	return models.CalendarEvent{
+		ID:              uuid.New(),
+		InvestigationID: uuid.New(),
+		EventType:       models.CalendarEventTypeInterview,
+		Title:           "Interview branch manager",
+		StartsAt:        start,
+		EndsAt:          end,
+		OrganizerID:     organizer,
+		Attendees:       models.UUIDArray(attendees),
+		Status:          models.CalendarEventStatusScheduled,
+	}
+}
+
+func TestCalendarNormalize(t *testing.T) {
+	start := time.Date(2024, 3, 1, 15, 30, 0, 0, time.UTC)
+
+	deadline := models.CalendarEvent{StartsAt: start}
+	require.NoError(t, calendar.Normalize(&deadline))
+	assert.Equal(t, start, deadline.EndsAt)
+
+	allDay := models.CalendarEvent{StartsAt: start, AllDay: true}
+	require.NoError(t, calendar.Normalize(&allDay))
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), allDay.StartsAt)
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), allDay.EndsAt)
+
+	// A multi-day hearing includes its last day
+	hearing := models.CalendarEvent{StartsAt: start, EndsAt: start.Add(48 * time.Hour), AllDay: true}
+	require.NoError(t, calendar.Normalize(&hearing))
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), hearing.EndsAt)
+
+	backwards := models.CalendarEvent{StartsAt: start, EndsAt: start.Add(-time.Hour)}
+	assert.Error(t, calendar.Normalize(&backwards))
+}
+
+func TestCalendarOverlaps(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2024, 3, 1, hour, 0, 0, 0, time.UTC) }
+
+	assert.True(t, calendar.Overlaps(at(9), at(11), at(10), at(12)))
+	assert.False(t, calendar.Overlaps(at(9), at(10), at(10), at(11)), "back-to-back events do not clash")
+	assert.True(t, calendar.Overlaps(at(10), at(10), at(9), at(11)), "deadline inside an interview")
+	assert.False(t, calendar.Overlaps(at(11), at(11), at(9), at(11)), "deadline at the end of an interview")
+	assert.True(t, calendar.Overlaps(at(9), at(11), at(9), at(9)))
+	assert.True(t, calendar.Overlaps(at(9), at(9), at(9), at(9)))
+	assert.False(t, calendar.Overlaps(at(9), at(9), at(10), at(10)))
+}
+
+func TestCalendarFindConflicts(t *testing.T) {
+	analyst, lawyer, other := uuid.New(), uuid.New(), uuid.New()
+	at := func(hour int) time.Time { return time.Date(2024, 3, 1, hour, 0, 0, 0, time.UTC) }
+
+	candidate := calendarEvent(analyst, at(10), at(11), lawyer)
+
+	sharedAttendee := calendarEvent(other, at(10), at(12), lawyer)
+	unrelated := calendarEvent(other, at(10), at(11))
+	later := calendarEvent(analyst, at(11), at(12))
+	cancelled := calendarEvent(analyst, at(10), at(11))
+	cancelled.Status = models.CalendarEventStatusCancelled
+
+	conflicts := calendar.FindConflicts(&candidate,
+		[]models.CalendarEvent{sharedAttendee, unrelated, later, cancelled, candidate})
+
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, sharedAttendee.ID, conflicts[0].EventID)
+	assert.Equal(t, []uuid.UUID{lawyer}, conflicts[0].Users)
+}
+
+func TestCalendarReminderSchedule(t *testing.T) {
+	organizer, attendee := uuid.New(), uuid.New()
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	start := now.Add(2 * time.Hour)
+
+	event := calendarEvent(organizer, start, start.Add(time.Hour), attendee, organizer)
+	event.ReminderMinutes = pq.Int64Array{1440, 60, 60, 15}
+
+	reminders := calendar.ReminderSchedule(&event, now)
+
+	// The one-day reminder has already passed and duplicates are dropped
+	require.Len(t, reminders, 4)
+	for _, reminder := range reminders {
+		assert.Equal(t, event.ID, reminder.EventID)
+		assert.Equal(t, start.Add(-time.Duration(reminder.MinutesBefore)*time.Minute), reminder.RemindAt)
+		assert.Contains(t, []int{60, 15}, reminder.MinutesBefore)
+		assert.Contains(t, []uuid.UUID{organizer, attendee}, reminder.UserID)
+	}
+
+	event.Status = models.CalendarEventStatusCancelled
+	assert.Empty(t, calendar.ReminderSchedule(&event, now))
+}
+
+func TestCalendarReminderMessage(t *testing.T) {
+	location := "Court 4"
+	event := calendarEvent(uuid.New(), time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), time.Time{})
+	event.EventType = models.CalendarEventTypeCourtDate
+	event.Title = "Forfeiture hearing"
+	event.Location = &location
+
+	title, message := calendar.ReminderMessage(&event, 1440)
+	assert.Equal(t, "Court date reminder", title)
+	assert.Equal(t, `Court date "Forfeiture hearing" is in 1 day (Fri 1 Mar 2024 09:00 UTC) at Court 4`, message)
+
+	event.EventType = models.CalendarEventTypeFilingDeadline
+	title, message = calendar.ReminderMessage(&event, 120)
+	assert.Equal(t, "Filing deadline approaching", title)
+	assert.Contains(t, message, "in 2 hours")
+}
+
+func TestCalendarBuildFeed(t *testing.T) {
+	stamp := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	description := "Bring KYC file; ask about wires, cash deposits\nand the safe deposit box " + strings.Repeat("details ", 10)
+
+	interview := calendarEvent(uuid.New(),
+		time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC))
+	interview.Description = &description
+	interview.ReminderMinutes = pq.Int64Array{30}
+	interview.Sequence = 2
+
+	hearing := calendarEvent(uuid.New(),
+		time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC))
+	hearing.EventType = models.CalendarEventTypeCourtDate
+	hearing.AllDay = true
+	hearing.Status = models.CalendarEventStatusCancelled
+	hearing.ReminderMinutes = pq.Int64Array{60}
+
+	feed := string(calendar.BuildFeed("Cases", "aegisshield", []models.CalendarEvent{interview, hearing}, stamp))
+
+	assert.True(t, strings.HasPrefix(feed, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(feed, "END:VCALENDAR\r\n"))
+
+	for _, line := range strings.Split(strings.TrimSuffix(feed, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "line exceeds 75 octets: %q", line)
+	}
+
+	unfolded := strings.ReplaceAll(feed, "\r\n ", "")
+	assert.Contains(t, unfolded, "UID:"+interview.ID.String()+"@aegisshield\r\n")
+	assert.Contains(t, unfolded, "DTSTART:20240301T100000Z\r\n")
+	assert.Contains(t, unfolded, "DTEND:20240301T110000Z\r\n")
+	assert.Contains(t, unfolded, `DESCRIPTION:Bring KYC file\; ask about wires\, cash deposits\nand the safe`)
+	assert.Contains(t, unfolded, "SEQUENCE:2\r\n")
+	assert.Contains(t, unfolded, "TRIGGER:-PT30M\r\n")
+
+	assert.Contains(t, unfolded, "DTSTART;VALUE=DATE:20240304\r\n")
+	assert.Contains(t, unfolded, "DTEND;VALUE=DATE:20240305\r\n")
+	assert.Contains(t, unfolded, "STATUS:CANCELLED\r\n")
+	assert.NotContains(t, unfolded, "TRIGGER:-PT60M", "cancelled events carry no alarms")
+}
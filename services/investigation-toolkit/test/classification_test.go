@@ -0,0 +1,467 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"aegisshield/shared/rbac"
+
+	"investigation-toolkit/internal/classification"
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/handlers"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+	"investigation-toolkit/internal/residency"
+)
+
+func classificationConfig() config.ClassificationConfig {
+	return config.ClassificationConfig{
+		Enabled:         true,
+		DefaultLevel:    "internal",
+		RestrictedRoles: map[string]string{"external_counsel": "internal"},
+	}
+}
+
+func levelPtr(level models.EvidenceClassification) *models.EvidenceClassification {
+	return &level
+}
+
+// fakeClassificationStore keeps participants and evidence classifications in memory
+type fakeClassificationStore struct {
+	cases        map[uuid.UUID]bool
+	participants []models.Collaboration
+	evidence     []models.Evidence
+	changes      []models.EvidenceClassificationChange
+}
+
+func newFakeClassificationStore() *fakeClassificationStore {
+	return &fakeClassificationStore{cases: map[uuid.UUID]bool{}}
+}
+
+func (f *fakeClassificationStore) CaseExists(ctx context.Context, investigationID uuid.UUID) error {
+	if !f.cases[investigationID] {
+		return repository.ErrCaseNotFound
+	}
+	return nil
+}
+
+func (f *fakeClassificationStore) AddParticipant(ctx context.Context, participant *models.Collaboration) error {
+	if !f.cases[participant.InvestigationID] {
+		return repository.ErrCaseNotFound
+	}
+	for _, p := range f.participants {
+		if p.InvestigationID == participant.InvestigationID && p.UserID == participant.UserID && p.IsActive {
+			return repository.ErrParticipantExists
+		}
+	}
+	participant.ID = uuid.New()
+	participant.IsActive = true
+	f.participants = append(f.participants, *participant)
+	return nil
+}
+
+func (f *fakeClassificationStore) RemoveParticipant(ctx context.Context, investigationID, userID, removedBy uuid.UUID) error {
+	for i := range f.participants {
+		p := &f.participants[i]
+		if p.InvestigationID == investigationID && p.UserID == userID && p.IsActive {
+			p.IsActive = false
+			p.RemovedBy = &removedBy
+			return nil
+		}
+	}
+	return repository.ErrParticipantNotFound
+}
+
+func (f *fakeClassificationStore) ListParticipants(ctx context.Context, investigationID uuid.UUID) ([]models.Collaboration, error) {
+	var participants []models.Collaboration
+	for _, p := range f.participants {
+		if p.InvestigationID == investigationID && p.IsActive {
+			participants = append(participants, p)
+		}
+	}
+	return participants, nil
+}
+
+func (f *fakeClassificationStore) ActiveParticipant(ctx context.Context, investigationID, userID uuid.UUID) (*models.Collaboration, error) {
+	for _, p := range f.participants {
+		if p.InvestigationID == investigationID && p.UserID == userID && p.IsActive {
+			participant := p
+			return &participant, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeClassificationStore) GetEvidenceClassification(ctx context.Context, evidenceID uuid.UUID) (uuid.UUID, models.EvidenceClassification, error) {
+	for _, e := range f.evidence {
+		if e.ID == evidenceID {
+			return e.InvestigationID, e.Classification, nil
+		}
+	}
+	return uuid.Nil, "", repository.ErrEvidenceNotFound
+}
+
+func (f *fakeClassificationStore) Classify(ctx context.Context, change *models.EvidenceClassificationChange) error {
+	for i := range f.evidence {
+		e := &f.evidence[i]
+		if e.ID != change.EvidenceID {
+			continue
+		}
+		if e.Classification != change.FromClassification {
+			return repository.ErrClassificationChanged
+		}
+		e.Classification = change.ToClassification
+		change.ID = uuid.New()
+		f.changes = append(f.changes, *change)
+		return nil
+	}
+	return repository.ErrEvidenceNotFound
+}
+
+func (f *fakeClassificationStore) ListClassificationChanges(ctx context.Context, evidenceID uuid.UUID) ([]models.EvidenceClassificationChange, error) {
+	var changes []models.EvidenceClassificationChange
+	for _, c := range f.changes {
+		if c.EvidenceID == evidenceID {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+func (f *fakeClassificationStore) ListCaseEvidenceClassifications(ctx context.Context, investigationID uuid.UUID) ([]models.Evidence, error) {
+	var evidence []models.Evidence
+	for _, e := range f.evidence {
+		if e.InvestigationID == investigationID {
+			evidence = append(evidence, e)
+		}
+	}
+	return evidence, nil
+}
+
+func (f *fakeClassificationStore) addEvidence(investigationID uuid.UUID, name string, level models.EvidenceClassification) uuid.UUID {
+	id := uuid.New()
+	f.evidence = append(f.evidence, models.Evidence{ID: id, InvestigationID: investigationID, Name: name, Classification: level})
+	return id
+}
+
+func TestClassificationLevels(t *testing.T) {
+	assert.True(t, classification.CanSee(models.ClassificationInternal, models.ClassificationPublic))
+	assert.True(t, classification.CanSee(models.ClassificationInternal, models.ClassificationInternal))
+	assert.False(t, classification.CanSee(models.ClassificationInternal, models.ClassificationConfidential))
+	assert.False(t, classification.CanSee("secret", models.ClassificationPublic))
+	assert.False(t, classification.CanSee(models.ClassificationRestricted, "secret"))
+
+	assert.Equal(t, []models.EvidenceClassification{models.ClassificationPublic, models.ClassificationInternal},
+		classification.Visible(models.ClassificationInternal))
+	assert.Empty(t, classification.Visible("secret"))
+}
+
+func TestClassificationPolicy(t *testing.T) {
+	policy, err := classification.NewPolicy(classificationConfig())
+	require.NoError(t, err)
+
+	assert.True(t, policy.Restricted(models.RoleExternalCounsel))
+	assert.False(t, policy.Restricted(models.RoleInvestigator))
+
+	clearance, err := policy.ClearanceFor(models.RoleExternalCounsel, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.ClassificationInternal, *clearance)
+
+	clearance, err = policy.ClearanceFor(models.RoleExternalCounsel, levelPtr(models.ClassificationConfidential))
+	require.NoError(t, err)
+	assert.Equal(t, models.ClassificationConfidential, *clearance)
+
+	clearance, err = policy.ClearanceFor(models.RoleInvestigator, nil)
+	require.NoError(t, err)
+	assert.Nil(t, clearance)
+
+	_, err = policy.ClearanceFor(models.RoleInvestigator, levelPtr(models.ClassificationPublic))
+	assert.ErrorIs(t, err, classification.ErrInvalidParticipant)
+	_, err = policy.ClearanceFor("paralegal", nil)
+	assert.ErrorIs(t, err, classification.ErrInvalidParticipant)
+	_, err = policy.ClearanceFor(models.RoleExternalCounsel, levelPtr("secret"))
+	assert.ErrorIs(t, err, classification.ErrUnknownLevel)
+
+	level, err := policy.ClassificationFor(nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.ClassificationInternal, level)
+
+	cfg := classificationConfig()
+	cfg.DefaultLevel = "secret"
+	_, err = classification.NewPolicy(cfg)
+	assert.ErrorIs(t, err, classification.ErrUnknownLevel)
+
+	cfg = classificationConfig()
+	cfg.RestrictedRoles = map[string]string{"paralegal": "public"}
+	_, err = classification.NewPolicy(cfg)
+	assert.Error(t, err)
+}
+
+func TestClassificationRestrictedRoutes(t *testing.T) {
+	id := uuid.New().String()
+
+	tests := []struct {
+		method  string
+		path    string
+		allowed bool
+	}{
+		{"GET", "/api/v1/investigations/" + id, true},
+		{"GET", "/api/v1/evidence/investigation/" + id, true},
+		{"GET", "/api/v1/evidence/" + id, true},
+		{"GET", "/api/v1/evidence/" + id + "/content", true},
+		{"GET", "/api/v1/evidence/" + id + "/files/f1", true},
+		{"PUT", "/api/v1/evidence/" + id, false},
+		{"GET", "/api/v1/investigations", false},
+		{"GET", "/api/v1/investigations/" + id + "/stats", false},
+		{"GET", "/api/v1/investigations/" + id + "/access-matrix", false},
+		{"GET", "/api/v1/evidence/" + id + "/classification-history", false},
+		{"GET", "/api/v1/timeline/investigation/" + id, false},
+		{"GET", "/api/v1/sar-filings/" + id, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, classification.RestrictedRouteAllowed(tt.method, tt.path), "%s %s", tt.method, tt.path)
+	}
+}
+
+func TestClassificationParticipants(t *testing.T) {
+	store := newFakeClassificationStore()
+	service, err := classification.NewService(store, classificationConfig(), zap.NewNop())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	caseID, lead, counsel := uuid.New(), uuid.New(), uuid.New()
+	store.cases[caseID] = true
+
+	participant, err := service.AddParticipant(ctx, caseID,
+		&models.AddParticipantRequest{UserID: counsel, Role: models.RoleExternalCounsel}, lead)
+	require.NoError(t, err)
+	require.NotNil(t, participant.Clearance)
+	assert.Equal(t, models.ClassificationInternal, *participant.Clearance)
+
+	_, err = service.AddParticipant(ctx, caseID,
+		&models.AddParticipantRequest{UserID: counsel, Role: models.RoleExternalCounsel}, lead)
+	assert.ErrorIs(t, err, repository.ErrParticipantExists)
+
+	_, err = service.AddParticipant(ctx, caseID, &models.AddParticipantRequest{Role: models.RoleAnalyst}, lead)
+	assert.ErrorIs(t, err, classification.ErrInvalidParticipant)
+
+	_, err = service.Participants(ctx, uuid.New())
+	assert.ErrorIs(t, err, repository.ErrCaseNotFound)
+
+	require.NoError(t, service.RemoveParticipant(ctx, caseID, counsel, lead))
+	participants, err := service.Participants(ctx, caseID)
+	require.NoError(t, err)
+	assert.Empty(t, participants)
+	assert.ErrorIs(t, service.RemoveParticipant(ctx, caseID, counsel, lead), repository.ErrParticipantNotFound)
+}
+
+func TestClassificationReclassify(t *testing.T) {
+	store := newFakeClassificationStore()
+	service, err := classification.NewService(store, classificationConfig(), zap.NewNop())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	caseID, user := uuid.New(), uuid.New()
+	evidenceID := store.addEvidence(caseID, "Wire records", models.ClassificationInternal)
+
+	_, err = service.Classify(ctx, evidenceID, &models.ClassifyEvidenceRequest{Classification: models.ClassificationRestricted}, user)
+	assert.ErrorIs(t, err, classification.ErrInvalidChange)
+	_, err = service.Classify(ctx, evidenceID,
+		&models.ClassifyEvidenceRequest{Classification: models.ClassificationInternal, Reason: "no-op"}, user)
+	assert.ErrorIs(t, err, classification.ErrInvalidChange)
+	_, err = service.Classify(ctx, evidenceID, &models.ClassifyEvidenceRequest{Classification: "secret", Reason: "x"}, user)
+	assert.ErrorIs(t, err, classification.ErrUnknownLevel)
+	_, err = service.Classify(ctx, uuid.New(),
+		&models.ClassifyEvidenceRequest{Classification: models.ClassificationPublic, Reason: "x"}, user)
+	assert.ErrorIs(t, err, repository.ErrEvidenceNotFound)
+
+	change, err := service.Classify(ctx, evidenceID,
+		&models.ClassifyEvidenceRequest{Classification: models.ClassificationRestricted, Reason: " privileged advice "}, user)
+	require.NoError(t, err)
+	assert.Equal(t, models.ClassificationInternal, change.FromClassification)
+	assert.Equal(t, models.ClassificationRestricted, change.ToClassification)
+	assert.Equal(t, "privileged advice", change.Reason)
+
+	history, err := service.History(ctx, evidenceID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, user, history[0].ChangedBy)
+}
+
+func TestClassificationCheck(t *testing.T) {
+	store := newFakeClassificationStore()
+	service, err := classification.NewService(store, classificationConfig(), zap.NewNop())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	caseID, lead, counsel, outsider := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	store.cases[caseID] = true
+	_, err = service.AddParticipant(ctx, caseID, &models.AddParticipantRequest{UserID: lead, Role: models.RoleLeadInvestigator}, lead)
+	require.NoError(t, err)
+	_, err = service.AddParticipant(ctx, caseID, &models.AddParticipantRequest{UserID: counsel, Role: models.RoleExternalCounsel}, lead)
+	require.NoError(t, err)
+	internal := store.addEvidence(caseID, "Account statements", models.ClassificationInternal)
+	confidential := store.addEvidence(caseID, "Informant notes", models.ClassificationConfidential)
+
+	check := func(kind residency.ScopeKind, id uuid.UUID, user uuid.UUID, restricted bool, path string) *classification.Decision {
+		decision, err := service.Check(ctx, residency.Scope{Kind: kind, ID: id}, &user, restricted, http.MethodGet, path)
+		require.NoError(t, err)
+		return decision
+	}
+
+	decision := check(residency.ScopeEvidence, confidential, lead, false, "/api/v1/evidence/"+confidential.String())
+	assert.True(t, decision.Allowed)
+	assert.Nil(t, decision.Access)
+
+	decision = check(residency.ScopeEvidence, internal, counsel, true, "/api/v1/evidence/"+internal.String())
+	assert.True(t, decision.Allowed)
+	require.NotNil(t, decision.Access)
+	assert.Equal(t, models.ClassificationInternal, *decision.Access.Clearance)
+
+	decision = check(residency.ScopeEvidence, confidential, counsel, true, "/api/v1/evidence/"+confidential.String())
+	assert.False(t, decision.Allowed)
+	assert.True(t, decision.Hidden)
+
+	decision = check(residency.ScopeCase, caseID, counsel, true, "/api/v1/evidence/investigation/"+caseID.String())
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, []models.EvidenceClassification{models.ClassificationPublic, models.ClassificationInternal},
+		decision.Access.Visible)
+
+	decision = check(residency.ScopeCase, caseID, counsel, true, "/api/v1/investigations/"+caseID.String()+"/stats")
+	assert.False(t, decision.Allowed)
+	assert.False(t, decision.Hidden)
+
+	decision = check(residency.ScopeCase, caseID, outsider, true, "/api/v1/investigations/"+caseID.String())
+	assert.False(t, decision.Allowed)
+
+	decision = check(residency.ScopeCase, caseID, outsider, false, "/api/v1/investigations/"+caseID.String())
+	assert.True(t, decision.Allowed)
+
+	// A restricted token is held to public evidence even in an unrestricted case role
+	decision = check(residency.ScopeEvidence, internal, lead, true, "/api/v1/evidence/"+internal.String())
+	assert.True(t, decision.Hidden)
+}
+
+func TestClassificationAccessMatrix(t *testing.T) {
+	store := newFakeClassificationStore()
+	service, err := classification.NewService(store, classificationConfig(), zap.NewNop())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	caseID, lead, counsel := uuid.New(), uuid.New(), uuid.New()
+	store.cases[caseID] = true
+	_, err = service.AddParticipant(ctx, caseID, &models.AddParticipantRequest{UserID: lead, Role: models.RoleLeadInvestigator}, lead)
+	require.NoError(t, err)
+	_, err = service.AddParticipant(ctx, caseID,
+		&models.AddParticipantRequest{UserID: counsel, Role: models.RoleExternalCounsel, Clearance: levelPtr(models.ClassificationPublic)}, lead)
+	require.NoError(t, err)
+	store.addEvidence(caseID, "Press release", models.ClassificationPublic)
+	store.addEvidence(caseID, "Account statements", models.ClassificationInternal)
+	restricted := store.addEvidence(caseID, "Legal opinion", models.ClassificationRestricted)
+
+	matrix, err := service.AccessMatrix(ctx, caseID)
+	require.NoError(t, err)
+
+	counts := map[models.EvidenceClassification]int{}
+	for _, level := range matrix.Levels {
+		counts[level.Classification] = level.Evidence
+	}
+	assert.Equal(t, map[models.EvidenceClassification]int{
+		models.ClassificationPublic:       1,
+		models.ClassificationInternal:     1,
+		models.ClassificationConfidential: 0,
+		models.ClassificationRestricted:   1,
+	}, counts)
+
+	require.Len(t, matrix.Participants, 2)
+	for _, row := range matrix.Participants {
+		if row.UserID == lead {
+			assert.False(t, row.Restricted)
+			assert.Equal(t, 3, row.VisibleEvidence)
+			assert.Equal(t, 0, row.HiddenEvidence)
+		} else {
+			assert.True(t, row.Restricted)
+			assert.Equal(t, 1, row.VisibleEvidence)
+			assert.Equal(t, 2, row.HiddenEvidence)
+		}
+	}
+	for _, item := range matrix.Evidence {
+		if item.EvidenceID == restricted {
+			assert.Equal(t, []uuid.UUID{counsel}, item.HiddenFrom)
+		}
+	}
+
+	_, err = service.AccessMatrix(ctx, uuid.New())
+	assert.ErrorIs(t, err, repository.ErrCaseNotFound)
+}
+
+func TestClassificationMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := newFakeClassificationStore()
+	service, err := classification.NewService(store, classificationConfig(), zap.NewNop())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	caseID, counsel := uuid.New(), uuid.New()
+	store.cases[caseID] = true
+	_, err = service.AddParticipant(ctx, caseID, &models.AddParticipantRequest{UserID: counsel, Role: models.RoleExternalCounsel}, uuid.New())
+	require.NoError(t, err)
+	confidential := store.addEvidence(caseID, "Informant notes", models.ClassificationConfidential)
+
+	router := gin.New()
+	v1 := router.Group("/api/v1")
+	v1.Use(func(c *gin.Context) {
+		subject := &rbac.Subject{ID: c.GetHeader("X-Test-Subject"), Roles: []string{c.GetHeader("X-Test-Role")}}
+		c.Request = c.Request.WithContext(rbac.WithSubject(c.Request.Context(), subject))
+	})
+	v1.Use(handlers.ClassificationMiddleware(service, zap.NewNop()))
+	visible := func(c *gin.Context) {
+		if access, ok := classification.AccessFromContext(c.Request.Context()); ok {
+			c.JSON(http.StatusOK, gin.H{"visible": access.Visible})
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+	v1.GET("/investigations", visible)
+	v1.GET("/evidence/:id", visible)
+	v1.GET("/evidence/investigation/:id", visible)
+
+	tests := []struct {
+		name   string
+		path   string
+		user   uuid.UUID
+		role   string
+		status int
+		body   string
+	}{
+		{"Counsel Lists Case Evidence", "/api/v1/evidence/investigation/" + caseID.String(), counsel, rbac.RoleExternalCounsel, http.StatusOK, `{"visible":["public","internal"]}`},
+		{"Counsel Cannot See Confidential Evidence", "/api/v1/evidence/" + confidential.String(), counsel, rbac.RoleExternalCounsel, http.StatusNotFound, ""},
+		{"Counsel Cannot List Cases", "/api/v1/investigations", counsel, rbac.RoleExternalCounsel, http.StatusForbidden, ""},
+		{"Investigator Sees Confidential Evidence", "/api/v1/evidence/" + confidential.String(), uuid.New(), rbac.RoleInvestigator, http.StatusOK, ""},
+		{"Missing Evidence Left To Handler", "/api/v1/evidence/" + uuid.New().String(), counsel, rbac.RoleExternalCounsel, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("X-Test-Subject", tt.user.String())
+			req.Header.Set("X-Test-Role", tt.role)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.body != "" {
+				assert.JSONEq(t, tt.body, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,455 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/handlers"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/scanner"
+)
+
+// fakeEvidenceRequestStore keeps evidence requests in memory, applying the
+// same slot and expiry rules as the SQL repository
+type fakeEvidenceRequestStore struct {
+	mu          sync.Mutex
+	requests    map[uuid.UUID]*models.EvidenceRequest
+	submissions []models.EvidenceRequestSubmission
+}
+
+func newFakeEvidenceRequestStore() *fakeEvidenceRequestStore {
+	return &fakeEvidenceRequestStore{requests: map[uuid.UUID]*models.EvidenceRequest{}}
+}
+
+func isOpenRequest(status models.EvidenceRequestStatus) bool {
+	return status == models.EvidenceRequestStatusPending || status == models.EvidenceRequestStatusPartiallyReceived
+}
+
+func (f *fakeEvidenceRequestStore) Create(ctx context.Context, request *models.EvidenceRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored := *request
+	f.requests[request.ID] = &stored
+	return nil
+}
+
+func (f *fakeEvidenceRequestStore) GetByID(ctx context.Context, id uuid.UUID) (*models.EvidenceRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	request, ok := f.requests[id]
+	if !ok {
+		return nil, errors.New("evidence request not found")
+	}
+	copied := *request
+	return &copied, nil
+}
+
+func (f *fakeEvidenceRequestStore) GetByTokenHash(ctx context.Context, tokenHash string) (*models.EvidenceRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, request := range f.requests {
+		if request.TokenHash == tokenHash {
+			copied := *request
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("evidence request not found")
+}
+
+func (f *fakeEvidenceRequestStore) GetByInvestigationID(ctx context.Context, investigationID uuid.UUID) ([]models.EvidenceRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var requests []models.EvidenceRequest
+	for _, request := range f.requests {
+		if request.InvestigationID == investigationID {
+			requests = append(requests, *request)
+		}
+	}
+	return requests, nil
+}
+
+func (f *fakeEvidenceRequestStore) Revoke(ctx context.Context, id uuid.UUID, revokedBy uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	request, ok := f.requests[id]
+	if !ok || !isOpenRequest(request.Status) {
+		return errors.New("evidence request not found or already closed")
+	}
+	now := time.Now()
+	request.Status = models.EvidenceRequestStatusRevoked
+	request.RevokedAt = &now
+	request.RevokedBy = &revokedBy
+	return nil
+}
+
+func (f *fakeEvidenceRequestStore) ExpireOverdue(ctx context.Context) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var expired int64
+	for _, request := range f.requests {
+		if request.ExpiresAt.Before(time.Now()) && isOpenRequest(request.Status) {
+			request.Status = models.EvidenceRequestStatusExpired
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+func (f *fakeEvidenceRequestStore) ReserveSlot(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	request, ok := f.requests[id]
+	if !ok || !isOpenRequest(request.Status) || !request.ExpiresAt.After(time.Now()) || request.FilesReceived >= request.MaxFiles {
+		return errors.New("evidence request is closed or has no uploads remaining")
+	}
+	request.FilesReceived++
+	request.Status = models.EvidenceRequestStatusPartiallyReceived
+	if request.FilesReceived >= request.MaxFiles {
+		request.Status = models.EvidenceRequestStatusFulfilled
+	}
+	return nil
+}
+
+func (f *fakeEvidenceRequestStore) ReleaseSlot(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	request, ok := f.requests[id]
+	if !ok {
+		return nil
+	}
+	if request.FilesReceived > 0 {
+		request.FilesReceived--
+	}
+	switch {
+	case request.Status == models.EvidenceRequestStatusRevoked:
+	case request.FilesReceived == 0:
+		request.Status = models.EvidenceRequestStatusPending
+	default:
+		request.Status = models.EvidenceRequestStatusPartiallyReceived
+	}
+	return nil
+}
+
+func (f *fakeEvidenceRequestStore) CreateSubmission(ctx context.Context, submission *models.EvidenceRequestSubmission) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.submissions = append(f.submissions, *submission)
+	return nil
+}
+
+func (f *fakeEvidenceRequestStore) GetSubmissions(ctx context.Context, requestID uuid.UUID) ([]models.EvidenceRequestSubmission, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var submissions []models.EvidenceRequestSubmission
+	for _, submission := range f.submissions {
+		if submission.RequestID == requestID {
+			submissions = append(submissions, submission)
+		}
+	}
+	return submissions, nil
+}
+
+func (f *fakeEvidenceRequestStore) request(id uuid.UUID) models.EvidenceRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return *f.requests[id]
+}
+
+func (f *fakeEvidenceRequestStore) expire(id uuid.UUID) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests[id].ExpiresAt = time.Now().Add(-time.Minute)
+}
+
+// fakeExternalEvidenceStore records evidence registered from external uploads
+type fakeExternalEvidenceStore struct {
+	mu        sync.Mutex
+	createErr error
+	evidence  []*models.Evidence
+	custody   []string
+}
+
+func (f *fakeExternalEvidenceStore) Create(ctx context.Context, investigationID uuid.UUID, req *models.CreateEvidenceRequest, collectedBy uuid.UUID) (*models.Evidence, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	evidence := &models.Evidence{ID: uuid.New(), InvestigationID: investigationID, Name: req.Name}
+	f.evidence = append(f.evidence, evidence)
+	return evidence, nil
+}
+
+func (f *fakeExternalEvidenceStore) UpdateFile(ctx context.Context, id uuid.UUID, filePath, fileHash, mimeType string, fileSize int64) error {
+	return nil
+}
+
+func (f *fakeExternalEvidenceStore) UpdateChainOfCustody(ctx context.Context, id uuid.UUID, userID uuid.UUID, action, location, notes string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.custody = append(f.custody, action)
+	return nil
+}
+
+// stubScanner returns a fixed scan result or error
+type stubScanner struct {
+	result *scanner.Result
+	err    error
+}
+
+func (s stubScanner) Scan(ctx context.Context, r io.Reader) (*scanner.Result, error) {
+	return s.result, s.err
+}
+
+type evidenceRequestFixture struct {
+	handler  *handlers.EvidenceRequestHandler
+	router   *gin.Engine
+	requests *fakeEvidenceRequestStore
+	evidence *fakeExternalEvidenceStore
+	caseID   uuid.UUID
+}
+
+func newEvidenceRequestFixture(t *testing.T, fileScanner scanner.Scanner) *evidenceRequestFixture {
+	cfg := &config.Config{
+		Storage: config.StorageConfig{LocalPath: t.TempDir()},
+		EvidenceRequests: config.EvidenceRequestConfig{
+			PublicBaseURL:    "https://evidence.example.com",
+			DefaultExpiry:    24 * time.Hour,
+			MaxExpiry:        7 * 24 * time.Hour,
+			DefaultMaxFiles:  2,
+			MaxFileSize:      1024,
+			AllowedFileTypes: []string{"pdf", "csv"},
+			ScanEnabled:      fileScanner != nil,
+			SweepInterval:    time.Minute,
+		},
+	}
+
+	f := &evidenceRequestFixture{
+		requests: newFakeEvidenceRequestStore(),
+		evidence: &fakeExternalEvidenceStore{},
+		caseID:   uuid.New(),
+	}
+	f.handler = handlers.NewEvidenceRequestHandler(f.requests, f.evidence, nil, fileScanner, nil, nil, cfg, zap.NewNop())
+
+	gin.SetMode(gin.TestMode)
+	f.router = gin.New()
+	f.router.POST("/investigations/:id/evidence-requests", f.handler.CreateEvidenceRequest)
+	f.router.GET("/external/evidence-requests/:token", f.handler.GetExternalRequest)
+	f.router.POST("/external/evidence-requests/:token", f.handler.SubmitExternalEvidence)
+	return f
+}
+
+// create sends an evidence request and returns its ID and upload token
+func (f *evidenceRequestFixture) create(t *testing.T, maxFiles int) (uuid.UUID, string) {
+	body := fmt.Sprintf(`{"recipient_name": "Harbour Street branch", "recipient_type": "bank_branch", "max_files": %d}`, maxFiles)
+	req := httptest.NewRequest(http.MethodPost, "/investigations/"+f.caseID.String()+"/evidence-requests", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", uuid.New().String())
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var response struct {
+		EvidenceRequest models.EvidenceRequest `json:"evidence_request"`
+		UploadURL       string                 `json:"upload_url"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	token := response.UploadURL[strings.LastIndex(response.UploadURL, "/")+1:]
+	return response.EvidenceRequest.ID, token
+}
+
+func (f *evidenceRequestFixture) upload(t *testing.T, token, fileName string, content []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/external/evidence-requests/"+token, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+func (f *evidenceRequestFixture) get(t *testing.T, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestEvidenceRequestsExpire(t *testing.T) {
+	f := newEvidenceRequestFixture(t, nil)
+	id, token := f.create(t, 2)
+	openID, _ := f.create(t, 2)
+
+	assert.Equal(t, http.StatusOK, f.get(t, "/external/evidence-requests/"+token).Code)
+
+	f.requests.expire(id)
+	w := f.get(t, "/external/evidence-requests/"+token)
+	assert.Equal(t, http.StatusGone, w.Code, "the link stops working as soon as it expires")
+	assert.Equal(t, http.StatusGone, f.upload(t, token, "statement.pdf", []byte("%PDF-1.4")).Code)
+
+	expired, err := f.handler.ExpireOverdue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+	assert.Equal(t, models.EvidenceRequestStatusExpired, f.requests.request(id).Status)
+	assert.Equal(t, models.EvidenceRequestStatusPending, f.requests.request(openID).Status)
+
+	expired, err = f.handler.ExpireOverdue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, expired, "expired requests are not expired again")
+}
+
+func TestEvidenceRequestExpirySweepRuns(t *testing.T) {
+	f := newEvidenceRequestFixture(t, nil)
+	id, _ := f.create(t, 2)
+	f.requests.expire(id)
+
+	// The fixture sweeps every minute, so run the sweep loop against a handler
+	// with a short interval instead
+	cfg := &config.Config{EvidenceRequests: config.EvidenceRequestConfig{SweepInterval: 10 * time.Millisecond}}
+	sweeper := handlers.NewEvidenceRequestHandler(f.requests, f.evidence, nil, nil, nil, nil, cfg, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sweeper.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return f.requests.request(id).Status == models.EvidenceRequestStatusExpired
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sweep did not stop when its context was cancelled")
+	}
+}
+
+func TestEvidenceRequestUploadSlots(t *testing.T) {
+	t.Run("accepted files use a slot until the request is fulfilled", func(t *testing.T) {
+		f := newEvidenceRequestFixture(t, nil)
+		id, token := f.create(t, 2)
+
+		w := f.upload(t, token, "statement.pdf", []byte("%PDF-1.4 march"))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		request := f.requests.request(id)
+		assert.Equal(t, 1, request.FilesReceived)
+		assert.Equal(t, models.EvidenceRequestStatusPartiallyReceived, request.Status)
+
+		w = f.upload(t, token, "ledger.csv", []byte("date,amount\n2026-03-01,9500\n"))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		request = f.requests.request(id)
+		assert.Equal(t, 2, request.FilesReceived)
+		assert.Equal(t, models.EvidenceRequestStatusFulfilled, request.Status)
+
+		assert.Equal(t, http.StatusGone, f.upload(t, token, "extra.pdf", []byte("%PDF-1.4")).Code)
+		assert.Len(t, f.evidence.evidence, 2)
+		assert.Equal(t, []string{"received_external", "received_external"}, f.evidence.custody)
+	})
+
+	t.Run("files that are not stored release their slot", func(t *testing.T) {
+		f := newEvidenceRequestFixture(t, nil)
+		id, token := f.create(t, 1)
+		f.evidence.createErr = errors.New("database unavailable")
+
+		w := f.upload(t, token, "statement.pdf", []byte("%PDF-1.4"))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		request := f.requests.request(id)
+		assert.Zero(t, request.FilesReceived)
+		assert.Equal(t, models.EvidenceRequestStatusPending, request.Status)
+		require.Len(t, f.requests.submissions, 1)
+		assert.Equal(t, models.SubmissionStatusRejected, f.requests.submissions[0].Status)
+
+		// The slot can be used again
+		f.evidence.createErr = nil
+		assert.Equal(t, http.StatusCreated, f.upload(t, token, "statement.pdf", []byte("%PDF-1.4")).Code)
+		assert.Equal(t, models.EvidenceRequestStatusFulfilled, f.requests.request(id).Status)
+	})
+
+	t.Run("files of other types do not use a slot", func(t *testing.T) {
+		f := newEvidenceRequestFixture(t, nil)
+		id, token := f.create(t, 1)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, f.upload(t, token, "payload.exe", []byte("MZ")).Code)
+		assert.Zero(t, f.requests.request(id).FilesReceived)
+		assert.Empty(t, f.requests.submissions)
+	})
+}
+
+func TestEvidenceRequestQuarantinesUnsafeFiles(t *testing.T) {
+	cases := map[string]struct {
+		scanner scanner.Scanner
+		status  models.ScanStatus
+		result  string
+	}{
+		"infected": {
+			scanner: stubScanner{result: &scanner.Result{Clean: false, Signature: "Eicar-Test-Signature"}},
+			status:  models.ScanStatusInfected,
+			result:  "Eicar-Test-Signature",
+		},
+		"scanner unavailable": {
+			scanner: stubScanner{err: errors.New("dial tcp: connection refused")},
+			status:  models.ScanStatusError,
+			result:  "dial tcp: connection refused",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := newEvidenceRequestFixture(t, c.scanner)
+			id, token := f.create(t, 1)
+
+			w := f.upload(t, token, "statement.pdf", []byte("%PDF-1.4"))
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+			require.Len(t, f.requests.submissions, 1)
+			submission := f.requests.submissions[0]
+			assert.Equal(t, models.SubmissionStatusQuarantined, submission.Status)
+			assert.Equal(t, c.status, submission.ScanStatus)
+			require.NotNil(t, submission.ScanResult)
+			assert.Equal(t, c.result, *submission.ScanResult)
+			assert.Nil(t, submission.EvidenceID)
+
+			assert.Empty(t, f.evidence.evidence, "quarantined files never become evidence")
+			request := f.requests.request(id)
+			assert.Zero(t, request.FilesReceived)
+			assert.Equal(t, models.EvidenceRequestStatusPending, request.Status)
+		})
+	}
+
+	t.Run("clean files are accepted", func(t *testing.T) {
+		f := newEvidenceRequestFixture(t, stubScanner{result: &scanner.Result{Clean: true, Raw: "stream: OK"}})
+		_, token := f.create(t, 1)
+
+		assert.Equal(t, http.StatusCreated, f.upload(t, token, "statement.pdf", []byte("%PDF-1.4")).Code)
+		require.Len(t, f.requests.submissions, 1)
+		assert.Equal(t, models.ScanStatusClean, f.requests.submissions[0].ScanStatus)
+		assert.Len(t, f.evidence.evidence, 1)
+	})
+}
@@ -0,0 +1,648 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/archival"
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/offlinesync"
+	"investigation-toolkit/internal/repository"
+)
+
+func offlineSyncConfig(t *testing.T) config.OfflineSyncConfig {
+	return config.OfflineSyncConfig{
+		Enabled:          true,
+		StagingPath:      t.TempDir(),
+		ChunkSize:        4,
+		MaxItems:         10,
+		MaxFileSize:      1024,
+		ClockSkew:        5 * time.Minute,
+		MaxOfflineWindow: 30 * 24 * time.Hour,
+		SessionTTL:       24 * time.Hour,
+		SweepInterval:    time.Hour,
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// fakeOfflineSyncStore keeps a case, its evidence and offline sync sessions
+// in memory
+type fakeOfflineSyncStore struct {
+	mu            sync.Mutex
+	investigation *models.Investigation
+	evidence      map[uuid.UUID]*models.Evidence
+	sessions      map[uuid.UUID]*models.OfflineSyncSession
+	items         map[uuid.UUID]*models.OfflineSyncItem
+	chunks        map[uuid.UUID]map[int]models.OfflineSyncChunk
+}
+
+func newFakeOfflineSyncStore() *fakeOfflineSyncStore {
+	return &fakeOfflineSyncStore{
+		investigation: &models.Investigation{
+			ID:       uuid.New(),
+			Title:    "Cash courier network",
+			CaseType: models.CaseTypeMoneyLaundering,
+			Status:   models.StatusOpen,
+		},
+		evidence: map[uuid.UUID]*models.Evidence{},
+		sessions: map[uuid.UUID]*models.OfflineSyncSession{},
+		items:    map[uuid.UUID]*models.OfflineSyncItem{},
+		chunks:   map[uuid.UUID]map[int]models.OfflineSyncChunk{},
+	}
+}
+
+func (f *fakeOfflineSyncStore) addEvidence(content []byte) *models.Evidence {
+	hash := sha256Hex(content)
+	evidence := &models.Evidence{
+		ID:              uuid.New(),
+		InvestigationID: f.investigation.ID,
+		Name:            "Ledger photo",
+		FileHash:        &hash,
+		ChainOfCustody:  models.JSONB{"entries": []interface{}{}},
+		Status:          models.EvidenceStatusActive,
+	}
+	f.evidence[evidence.ID] = evidence
+	return evidence
+}
+
+func (f *fakeOfflineSyncStore) custody(evidenceID uuid.UUID) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, entry := range f.evidence[evidenceID].ChainOfCustody["entries"].([]interface{}) {
+		entries = append(entries, entry.(map[string]interface{}))
+	}
+	return entries
+}
+
+func (f *fakeOfflineSyncStore) GetInvestigation(ctx context.Context, id uuid.UUID) (*models.Investigation, error) {
+	if id != f.investigation.ID {
+		return nil, repository.ErrCaseNotFound
+	}
+	return f.investigation, nil
+}
+
+func (f *fakeOfflineSyncStore) GetEvidence(ctx context.Context, id uuid.UUID) (*models.Evidence, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	evidence, ok := f.evidence[id]
+	if !ok {
+		return nil, errors.New("evidence not found")
+	}
+	clone := *evidence
+	return &clone, nil
+}
+
+func (f *fakeOfflineSyncStore) FindEvidenceByHash(ctx context.Context, investigationID uuid.UUID, fileHash string) (*models.Evidence, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, evidence := range f.evidence {
+		if evidence.InvestigationID == investigationID && evidence.FileHash != nil && *evidence.FileHash == fileHash {
+			return evidence, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeOfflineSyncStore) CreateEvidence(ctx context.Context, evidence *models.Evidence) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.evidence[evidence.ID] = evidence
+	return nil
+}
+
+func (f *fakeOfflineSyncStore) ReviseEvidenceFile(ctx context.Context, evidenceID uuid.UUID, filePath, fileHash, mimeType string, fileSize int64, custody []interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	evidence := f.evidence[evidenceID]
+	evidence.FilePath = &filePath
+	evidence.FileHash = &fileHash
+	evidence.MimeType = &mimeType
+	evidence.FileSize = &fileSize
+	evidence.ChainOfCustody["entries"] = append(evidence.ChainOfCustody["entries"].([]interface{}), custody...)
+	return nil
+}
+
+func (f *fakeOfflineSyncStore) AppendCustody(ctx context.Context, evidenceID uuid.UUID, custody []interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	evidence := f.evidence[evidenceID]
+	evidence.ChainOfCustody["entries"] = append(evidence.ChainOfCustody["entries"].([]interface{}), custody...)
+	return nil
+}
+
+func (f *fakeOfflineSyncStore) CreateSession(ctx context.Context, session *models.OfflineSyncSession, items []models.OfflineSyncItem) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copySession := *session
+	f.sessions[session.ID] = &copySession
+	for i := range items {
+		item := items[i]
+		f.items[item.ID] = &item
+	}
+	return nil
+}
+
+func (f *fakeOfflineSyncStore) GetSession(ctx context.Context, id uuid.UUID) (*models.OfflineSyncSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	session, ok := f.sessions[id]
+	if !ok {
+		return nil, repository.ErrOfflineSyncNotFound
+	}
+	clone := *session
+	return &clone, nil
+}
+
+func (f *fakeOfflineSyncStore) FindOpenSession(ctx context.Context, investigationID uuid.UUID, deviceID, manifestHash string) (*models.OfflineSyncSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, session := range f.sessions {
+		if session.InvestigationID == investigationID && session.DeviceID == deviceID &&
+			session.ManifestHash == manifestHash && session.Status == models.OfflineSyncStatusOpen {
+			clone := *session
+			return &clone, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeOfflineSyncStore) ListSessions(ctx context.Context, investigationID uuid.UUID) ([]models.OfflineSyncSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var sessions []models.OfflineSyncSession
+	for _, session := range f.sessions {
+		if session.InvestigationID == investigationID {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (f *fakeOfflineSyncStore) SetSessionStatus(ctx context.Context, id uuid.UUID, from, to models.OfflineSyncStatus) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	session, ok := f.sessions[id]
+	if !ok || session.Status != from {
+		return false, nil
+	}
+	session.Status = to
+	if to == models.OfflineSyncStatusCompleted {
+		now := time.Now()
+		session.CompletedAt = &now
+	}
+	return true, nil
+}
+
+func (f *fakeOfflineSyncStore) ExpireSessions(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var expired []uuid.UUID
+	for _, session := range f.sessions {
+		if session.Status == models.OfflineSyncStatusOpen && !session.ExpiresAt.After(now) {
+			session.Status = models.OfflineSyncStatusExpired
+			expired = append(expired, session.ID)
+		}
+	}
+	return expired, nil
+}
+
+func (f *fakeOfflineSyncStore) GetItem(ctx context.Context, sessionID, itemID uuid.UUID) (*models.OfflineSyncItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.items[itemID]
+	if !ok || item.SessionID != sessionID {
+		return nil, repository.ErrOfflineSyncItemNotFound
+	}
+	clone := *item
+	return &clone, nil
+}
+
+func (f *fakeOfflineSyncStore) ListItems(ctx context.Context, sessionID uuid.UUID) ([]models.OfflineSyncItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var items []models.OfflineSyncItem
+	for _, item := range f.items {
+		if item.SessionID == sessionID {
+			items = append(items, *item)
+		}
+	}
+	return items, nil
+}
+
+func (f *fakeOfflineSyncStore) UpdateItem(ctx context.Context, item *models.OfflineSyncItem) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	clone := *item
+	f.items[item.ID] = &clone
+	return nil
+}
+
+func (f *fakeOfflineSyncStore) FindSyncedItem(ctx context.Context, investigationID uuid.UUID, deviceID, clientItemID string, excludeSession uuid.UUID) (*models.OfflineSyncItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, item := range f.items {
+		session := f.sessions[item.SessionID]
+		if session.InvestigationID == investigationID && session.DeviceID == deviceID && session.ID != excludeSession &&
+			item.ClientItemID == clientItemID && item.EvidenceID != nil &&
+			(item.Status == models.OfflineItemStatusSynced || item.Status == models.OfflineItemStatusConflict) {
+			clone := *item
+			return &clone, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeOfflineSyncStore) RecordChunk(ctx context.Context, chunk *models.OfflineSyncChunk) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.chunks[chunk.ItemID] == nil {
+		f.chunks[chunk.ItemID] = map[int]models.OfflineSyncChunk{}
+	}
+	f.chunks[chunk.ItemID][chunk.ChunkIndex] = *chunk
+	return nil
+}
+
+func (f *fakeOfflineSyncStore) ListChunks(ctx context.Context, sessionID uuid.UUID) ([]models.OfflineSyncChunk, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var chunks []models.OfflineSyncChunk
+	for itemID, received := range f.chunks {
+		if f.items[itemID].SessionID != sessionID {
+			continue
+		}
+		for _, chunk := range received {
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks, nil
+}
+
+// offlineItem builds a manifest item for a file collected in the window
+func offlineItem(clientID string, content []byte, collectedAt time.Time) models.OfflineSyncItemRequest {
+	return models.OfflineSyncItemRequest{
+		ClientItemID: clientID,
+		Name:         "Field note " + clientID,
+		EvidenceType: models.EvidenceTypeDocument,
+		FileName:     clientID + ".txt",
+		FileSize:     int64(len(content)),
+		FileHash:     sha256Hex(content),
+		CollectedAt:  collectedAt,
+	}
+}
+
+type offlineSyncFixture struct {
+	service *offlinesync.Service
+	store   *fakeOfflineSyncStore
+	config  config.OfflineSyncConfig
+	from    time.Time
+	until   time.Time
+	userID  uuid.UUID
+}
+
+func newOfflineSyncFixture(t *testing.T) *offlineSyncFixture {
+	store := newFakeOfflineSyncStore()
+	cfg := offlineSyncConfig(t)
+	until := time.Now().Add(-time.Hour)
+	return &offlineSyncFixture{
+		service: offlinesync.NewService(store, nil, nil, cfg, t.TempDir(), zap.NewNop()),
+		store:   store,
+		config:  cfg,
+		from:    until.Add(-48 * time.Hour),
+		until:   until,
+		userID:  uuid.New(),
+	}
+}
+
+func (f *offlineSyncFixture) request(strategy models.ConflictStrategy, items ...models.OfflineSyncItemRequest) *models.CreateOfflineSyncRequest {
+	return &models.CreateOfflineSyncRequest{
+		DeviceID:         "tablet-07",
+		OfflineFrom:      f.from,
+		OfflineUntil:     f.until,
+		ConflictStrategy: strategy,
+		Items:            items,
+	}
+}
+
+// upload sends every chunk of a file
+func (f *offlineSyncFixture) upload(t *testing.T, sessionID, itemID uuid.UUID, content []byte) {
+	size := int(f.config.ChunkSize)
+	for index := 0; index*size < len(content) || index == 0; index++ {
+		end := (index + 1) * size
+		if end > len(content) {
+			end = len(content)
+		}
+		chunk := content[index*size : end]
+		_, err := f.service.UploadChunk(context.Background(), sessionID, itemID, index, sha256Hex(chunk), bytes.NewReader(chunk))
+		require.NoError(t, err)
+	}
+}
+
+func itemByClientID(t *testing.T, items []offlinesync.ItemProgress, clientID string) offlinesync.ItemProgress {
+	for _, item := range items {
+		if item.ClientItemID == clientID {
+			return item
+		}
+	}
+	t.Fatalf("item %s not in session", clientID)
+	return offlinesync.ItemProgress{}
+}
+
+func TestOfflineSyncResumesAndRecordsOfflineWindow(t *testing.T) {
+	ctx := context.Background()
+	f := newOfflineSyncFixture(t)
+
+	note := []byte("courier met at depot, cash counted")
+	photo := []byte("JPEG")
+	req := f.request("",
+		offlineItem("note-1", note, f.from.Add(time.Hour)),
+		offlineItem("photo-1", photo, f.from.Add(2*time.Hour)))
+	req.Items[0].Custody = []models.OfflineCustodyEntry{
+		{Action: "sealed", Timestamp: f.from.Add(90 * time.Minute), Location: "Depot 4"},
+	}
+
+	progress, err := f.service.CreateSession(ctx, f.store.investigation.ID, req, f.userID, "default")
+	require.NoError(t, err)
+	assert.False(t, progress.Resumed)
+	assert.Equal(t, models.ConflictKeepBoth, progress.Session.ConflictStrategy)
+
+	noteItem := itemByClientID(t, progress.Items, "note-1")
+	assert.Equal(t, 9, noteItem.TotalChunks)
+
+	// A chunk that does not match its hash is refused
+	_, err = f.service.UploadChunk(ctx, progress.Session.ID, noteItem.ID, 0, sha256Hex([]byte("nope")), bytes.NewReader(note[:4]))
+	assert.ErrorIs(t, err, offlinesync.ErrChunkHashMismatch)
+
+	// Only part of the note is sent before the device loses its connection
+	_, err = f.service.UploadChunk(ctx, progress.Session.ID, noteItem.ID, 0, sha256Hex(note[:4]), bytes.NewReader(note[:4]))
+	require.NoError(t, err)
+
+	_, err = f.service.Complete(ctx, progress.Session.ID)
+	assert.ErrorIs(t, err, offlinesync.ErrIncomplete)
+
+	// Sending the same manifest again resumes the session
+	resumed, err := f.service.CreateSession(ctx, f.store.investigation.ID, f.request("",
+		offlineItem("photo-1", photo, f.from.Add(2*time.Hour)),
+		offlineItem("note-1", note, f.from.Add(time.Hour))), f.userID, "default")
+	require.NoError(t, err)
+	assert.True(t, resumed.Resumed)
+	assert.Equal(t, progress.Session.ID, resumed.Session.ID)
+	resumedNote := itemByClientID(t, resumed.Items, "note-1")
+	assert.Equal(t, 1, resumedNote.ReceivedChunks)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, resumedNote.MissingChunks)
+
+	f.upload(t, progress.Session.ID, noteItem.ID, note)
+	f.upload(t, progress.Session.ID, itemByClientID(t, progress.Items, "photo-1").ID, photo)
+
+	result, err := f.service.Complete(ctx, progress.Session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Synced)
+	assert.Equal(t, models.OfflineSyncStatusCompleted, result.Session.Status)
+
+	var synced *models.OfflineSyncItem
+	for i := range result.Items {
+		if result.Items[i].ClientItemID == "note-1" {
+			synced = &result.Items[i]
+		}
+	}
+	require.NotNil(t, synced)
+	require.NotNil(t, synced.EvidenceID)
+
+	evidence := f.store.evidence[*synced.EvidenceID]
+	assert.Equal(t, sha256Hex(note), *evidence.FileHash)
+	assert.Equal(t, "offline:tablet-07", *evidence.Source)
+	assert.Equal(t, f.userID, evidence.CollectedBy)
+	assert.Contains(t, evidence.Tags, "offline_sync")
+
+	stored, err := os.ReadFile(*evidence.FilePath)
+	require.NoError(t, err)
+	assert.Equal(t, note, stored)
+
+	custody := f.store.custody(evidence.ID)
+	require.Len(t, custody, 3)
+	assert.Equal(t, offlinesync.CustodyActionCollectedOffline, custody[0]["action"])
+	assert.Equal(t, map[string]interface{}{"from": f.from, "until": f.until}, custody[0]["offline_window"])
+	assert.Equal(t, "sealed", custody[1]["action"])
+	assert.Equal(t, true, custody[1]["offline"])
+	assert.Equal(t, offlinesync.CustodyActionSyncedOffline, custody[2]["action"])
+
+	// Completing again returns the result without storing anything twice
+	again, err := f.service.Complete(ctx, progress.Session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, again.Synced)
+	assert.Len(t, f.store.evidence, 2)
+}
+
+func TestOfflineSyncVerifiesAssembledFileHash(t *testing.T) {
+	ctx := context.Background()
+	f := newOfflineSyncFixture(t)
+
+	content := []byte("wire receipt")
+	item := offlineItem("receipt", content, f.from.Add(time.Hour))
+	item.FileHash = sha256Hex([]byte("wire receipT"))
+
+	progress, err := f.service.CreateSession(ctx, f.store.investigation.ID, f.request("", item), f.userID, "default")
+	require.NoError(t, err)
+
+	// Each chunk is intact, but the file is not the one in the manifest
+	f.upload(t, progress.Session.ID, progress.Items[0].ID, content)
+
+	result, err := f.service.Complete(ctx, progress.Session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.HashMismatch)
+	assert.Equal(t, models.OfflineItemStatusHashMismatch, result.Items[0].Status)
+	assert.Empty(t, f.store.evidence)
+}
+
+func TestOfflineSyncSkipsEvidenceAlreadyInCase(t *testing.T) {
+	ctx := context.Background()
+	f := newOfflineSyncFixture(t)
+
+	content := []byte("passport scan")
+	existing := f.store.addEvidence(content)
+
+	progress, err := f.service.CreateSession(ctx, f.store.investigation.ID,
+		f.request("", offlineItem("scan", content, f.from.Add(time.Hour))), f.userID, "default")
+	require.NoError(t, err)
+	f.upload(t, progress.Session.ID, progress.Items[0].ID, content)
+
+	result, err := f.service.Complete(ctx, progress.Session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Duplicates)
+	assert.Equal(t, existing.ID, *result.Items[0].EvidenceID)
+	assert.Len(t, f.store.evidence, 1)
+
+	// The existing evidence records that a copy was also collected offline
+	custody := f.store.custody(existing.ID)
+	require.Len(t, custody, 2)
+	assert.Equal(t, offlinesync.CustodyActionCollectedOffline, custody[0]["action"])
+}
+
+func TestOfflineSyncResolvesConflicts(t *testing.T) {
+	original := []byte("statement v1")
+	serverEdit := []byte("statement v2 (server)")
+	deviceEdit := []byte("statement v2 (device)")
+
+	revision := func(f *offlineSyncFixture, base *models.Evidence) models.OfflineSyncItemRequest {
+		item := offlineItem("statement", deviceEdit, f.from.Add(time.Hour))
+		baseHash := sha256Hex(original)
+		item.BaseEvidenceID = &base.ID
+		item.BaseFileHash = &baseHash
+		return item
+	}
+
+	tests := []struct {
+		name     string
+		strategy models.ConflictStrategy
+		edited   bool
+		check    func(t *testing.T, f *offlineSyncFixture, base *models.Evidence, item models.OfflineSyncItem)
+	}{
+		{
+			name:     "unchanged on server is revised",
+			strategy: models.ConflictKeepBoth,
+			check: func(t *testing.T, f *offlineSyncFixture, base *models.Evidence, item models.OfflineSyncItem) {
+				assert.Equal(t, models.OfflineItemStatusSynced, item.Status)
+				assert.Equal(t, sha256Hex(deviceEdit), *f.store.evidence[base.ID].FileHash)
+			},
+		},
+		{
+			name:     "keep both",
+			strategy: models.ConflictKeepBoth,
+			edited:   true,
+			check: func(t *testing.T, f *offlineSyncFixture, base *models.Evidence, item models.OfflineSyncItem) {
+				assert.Equal(t, models.OfflineItemStatusConflict, item.Status)
+				assert.Equal(t, base.ID, *item.ConflictEvidenceID)
+				assert.NotEqual(t, base.ID, *item.EvidenceID)
+				assert.Equal(t, sha256Hex(serverEdit), *f.store.evidence[base.ID].FileHash)
+				assert.Equal(t, base.ID, f.store.evidence[*item.EvidenceID].Metadata["conflicts_with"])
+			},
+		},
+		{
+			name:     "server wins",
+			strategy: models.ConflictServerWins,
+			edited:   true,
+			check: func(t *testing.T, f *offlineSyncFixture, base *models.Evidence, item models.OfflineSyncItem) {
+				assert.Equal(t, models.OfflineItemStatusConflict, item.Status)
+				assert.Nil(t, item.EvidenceID)
+				assert.Equal(t, sha256Hex(serverEdit), *f.store.evidence[base.ID].FileHash)
+				assert.Len(t, f.store.evidence, 1)
+			},
+		},
+		{
+			name:     "client wins",
+			strategy: models.ConflictClientWins,
+			edited:   true,
+			check: func(t *testing.T, f *offlineSyncFixture, base *models.Evidence, item models.OfflineSyncItem) {
+				assert.Equal(t, models.OfflineItemStatusConflict, item.Status)
+				assert.Equal(t, base.ID, *item.EvidenceID)
+				assert.Equal(t, sha256Hex(deviceEdit), *f.store.evidence[base.ID].FileHash)
+				assert.Len(t, f.store.custody(base.ID), 2)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newOfflineSyncFixture(t)
+
+			base := f.store.addEvidence(original)
+			if tt.edited {
+				hash := sha256Hex(serverEdit)
+				f.store.evidence[base.ID].FileHash = &hash
+			}
+
+			progress, err := f.service.CreateSession(ctx, f.store.investigation.ID,
+				f.request(tt.strategy, revision(f, base)), f.userID, "default")
+			require.NoError(t, err)
+			f.upload(t, progress.Session.ID, progress.Items[0].ID, deviceEdit)
+
+			result, err := f.service.Complete(ctx, progress.Session.ID)
+			require.NoError(t, err)
+			tt.check(t, f, base, result.Items[0])
+		})
+	}
+}
+
+func TestOfflineSyncRejectsInvalidManifests(t *testing.T) {
+	f := newOfflineSyncFixture(t)
+	content := []byte("memo")
+
+	tests := []struct {
+		name   string
+		mutate func(req *models.CreateOfflineSyncRequest)
+	}{
+		{"missing device", func(req *models.CreateOfflineSyncRequest) { req.DeviceID = " " }},
+		{"collected outside window", func(req *models.CreateOfflineSyncRequest) {
+			req.Items[0].CollectedAt = f.until.Add(time.Hour)
+		}},
+		{"custody outside window", func(req *models.CreateOfflineSyncRequest) {
+			req.Items[0].Custody = []models.OfflineCustodyEntry{{Action: "sealed", Timestamp: f.from.Add(-time.Hour)}}
+		}},
+		{"window in the future", func(req *models.CreateOfflineSyncRequest) {
+			req.OfflineUntil = time.Now().Add(time.Hour)
+		}},
+		{"window too long", func(req *models.CreateOfflineSyncRequest) {
+			req.OfflineFrom = f.until.Add(-60 * 24 * time.Hour)
+		}},
+		{"bad hash", func(req *models.CreateOfflineSyncRequest) { req.Items[0].FileHash = "abc" }},
+		{"duplicate client item", func(req *models.CreateOfflineSyncRequest) {
+			req.Items = append(req.Items, req.Items[0])
+		}},
+		{"manifest hash mismatch", func(req *models.CreateOfflineSyncRequest) {
+			req.ManifestHash = sha256Hex([]byte("other manifest"))
+		}},
+		{"unknown strategy", func(req *models.CreateOfflineSyncRequest) { req.ConflictStrategy = "newest_wins" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := f.request("", offlineItem("memo", content, f.from.Add(time.Hour)))
+			tt.mutate(req)
+
+			_, err := f.service.CreateSession(context.Background(), f.store.investigation.ID, req, f.userID, "default")
+			assert.ErrorIs(t, err, offlinesync.ErrInvalidManifest)
+		})
+	}
+}
+
+func TestOfflineSyncRefusesArchivedCase(t *testing.T) {
+	f := newOfflineSyncFixture(t)
+	f.store.investigation.Status = models.StatusArchived
+
+	_, err := f.service.CreateSession(context.Background(), f.store.investigation.ID,
+		f.request("", offlineItem("memo", []byte("memo"), f.from.Add(time.Hour))), f.userID, "default")
+	assert.ErrorIs(t, err, archival.ErrCaseArchived)
+}
+
+func TestOfflineSyncSweepExpiresSessions(t *testing.T) {
+	ctx := context.Background()
+	f := newOfflineSyncFixture(t)
+
+	progress, err := f.service.CreateSession(ctx, f.store.investigation.ID,
+		f.request("", offlineItem("memo", []byte("memo"), f.from.Add(time.Hour))), f.userID, "default")
+	require.NoError(t, err)
+	f.store.sessions[progress.Session.ID].ExpiresAt = time.Now().Add(-time.Minute)
+
+	expired, err := f.service.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	_, err = f.service.UploadChunk(ctx, progress.Session.ID, progress.Items[0].ID, 0, sha256Hex([]byte("memo")), bytes.NewReader([]byte("memo")))
+	assert.ErrorIs(t, err, offlinesync.ErrSessionClosed)
+}
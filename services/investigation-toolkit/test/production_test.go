@@ -0,0 +1,352 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/production"
+	"investigation-toolkit/internal/repository"
+)
+
+func productionConfig() config.ProductionConfig {
+	return config.ProductionConfig{Enabled: true, RedactionText: "[REDACTED]"}
+}
+
+// fakeProductionStore keeps a case, its artifacts and productions in memory
+type fakeProductionStore struct {
+	investigation *models.Investigation
+	evidence      []models.Evidence
+	timeline      []models.Timeline
+	filings       []models.SARFiling
+	productions   map[uuid.UUID]*models.LawEnforcementProduction
+	disclosures   []*models.AuditLog
+}
+
+func newFakeProductionStore() *fakeProductionStore {
+	description := "Potential structuring through linked accounts"
+	return &fakeProductionStore{
+		investigation: &models.Investigation{
+			ID:          uuid.New(),
+			Title:       "Structuring review",
+			Description: &description,
+			CaseType:    models.CaseTypeMoneyLaundering,
+			Status:      models.StatusOpen,
+			CreatedAt:   time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+		},
+		productions: map[uuid.UUID]*models.LawEnforcementProduction{},
+	}
+}
+
+func (f *fakeProductionStore) addEvidence(name, source string) models.Evidence {
+	evidence := models.Evidence{
+		ID:              uuid.New(),
+		InvestigationID: f.investigation.ID,
+		Name:            name,
+		Source:          &source,
+		CollectedAt:     time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC),
+	}
+	f.evidence = append(f.evidence, evidence)
+	return evidence
+}
+
+func (f *fakeProductionStore) GetInvestigation(ctx context.Context, id uuid.UUID) (*models.Investigation, error) {
+	if id != f.investigation.ID {
+		return nil, repository.ErrCaseNotFound
+	}
+	return f.investigation, nil
+}
+
+func (f *fakeProductionStore) ListEvidence(ctx context.Context, investigationID uuid.UUID, ids []uuid.UUID) ([]models.Evidence, error) {
+	var evidence []models.Evidence
+	for _, item := range f.evidence {
+		if item.InvestigationID == investigationID && containsID(ids, item.ID) {
+			evidence = append(evidence, item)
+		}
+	}
+	return evidence, nil
+}
+
+func (f *fakeProductionStore) ListTimeline(ctx context.Context, investigationID uuid.UUID, ids []uuid.UUID) ([]models.Timeline, error) {
+	var timeline []models.Timeline
+	for _, event := range f.timeline {
+		if event.InvestigationID == investigationID && containsID(ids, event.ID) {
+			timeline = append(timeline, event)
+		}
+	}
+	return timeline, nil
+}
+
+func (f *fakeProductionStore) ListSARFilings(ctx context.Context, investigationID uuid.UUID, ids []uuid.UUID) ([]models.SARFiling, error) {
+	var filings []models.SARFiling
+	for _, filing := range f.filings {
+		if filing.InvestigationID == investigationID && containsID(ids, filing.ID) {
+			filings = append(filings, filing)
+		}
+	}
+	return filings, nil
+}
+
+func (f *fakeProductionStore) CreateProduction(ctx context.Context, p *models.LawEnforcementProduction) error {
+	p.ID = uuid.New()
+	copied := *p
+	f.productions[p.ID] = &copied
+	return nil
+}
+
+func (f *fakeProductionStore) GetProduction(ctx context.Context, id uuid.UUID) (*models.LawEnforcementProduction, error) {
+	p, ok := f.productions[id]
+	if !ok {
+		return nil, repository.ErrProductionNotFound
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (f *fakeProductionStore) ListProductions(ctx context.Context, investigationID uuid.UUID) ([]models.LawEnforcementProduction, error) {
+	var productions []models.LawEnforcementProduction
+	for _, p := range f.productions {
+		if p.InvestigationID == investigationID {
+			productions = append(productions, *p)
+		}
+	}
+	return productions, nil
+}
+
+func (f *fakeProductionStore) ApproveProduction(ctx context.Context, p *models.LawEnforcementProduction) error {
+	if f.productions[p.ID].Status != models.ProductionStatusDraft {
+		return repository.ErrProductionNotDraft
+	}
+	copied := *p
+	f.productions[p.ID] = &copied
+	return nil
+}
+
+func (f *fakeProductionStore) RecordProduction(ctx context.Context, p *models.LawEnforcementProduction, disclosure *models.AuditLog) error {
+	if f.productions[p.ID].Status != models.ProductionStatusApproved {
+		return repository.ErrProductionNotApproved
+	}
+	copied := *p
+	f.productions[p.ID] = &copied
+	f.disclosures = append(f.disclosures, disclosure)
+	return nil
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestProductionTemplates(t *testing.T) {
+	for _, template := range production.BuiltinTemplates() {
+		template := template
+		assert.NoError(t, template.Validate(), template.ID)
+	}
+
+	t.Run("Unknown Field", func(t *testing.T) {
+		template := production.BuiltinTemplates()[0]
+		template.Sections = []production.Section{{
+			Name:    "Evidence",
+			Source:  production.SourceEvidence,
+			Columns: []production.Column{{Header: "Path", Field: "file_path"}},
+		}}
+		assert.ErrorIs(t, template.Validate(), production.ErrInvalidTemplate)
+	})
+
+	t.Run("XML Names", func(t *testing.T) {
+		template := production.BuiltinTemplates()[1]
+		template.Sections[0].Columns[0].Element = "Case ID"
+		assert.ErrorIs(t, template.Validate(), production.ErrInvalidTemplate)
+	})
+
+	t.Run("Sheet Names", func(t *testing.T) {
+		template := production.BuiltinTemplates()[0]
+		template.Sections[1].Name = "production"
+		assert.ErrorIs(t, template.Validate(), production.ErrInvalidTemplate, "clashes with the manifest tab")
+	})
+}
+
+func TestProductionCreate(t *testing.T) {
+	store := newFakeProductionStore()
+	evidence := store.addEvidence("Bank statements", "First National Bank")
+	service, err := production.NewService(store, productionConfig(), zap.NewNop())
+	require.NoError(t, err)
+	ctx := context.Background()
+	requester := uuid.New()
+
+	request := func() *models.CreateProductionRequest {
+		return &models.CreateProductionRequest{
+			TemplateID:       "leo_workbook",
+			Agency:           "FBI Field Office",
+			RequestReference: "GJ-2024-118",
+			EvidenceIDs:      []uuid.UUID{evidence.ID, evidence.ID},
+		}
+	}
+
+	p, err := service.Create(ctx, store.investigation.ID, request(), requester)
+	require.NoError(t, err)
+	assert.Equal(t, models.ProductionStatusDraft, p.Status)
+	assert.Equal(t, models.UUIDArray{evidence.ID}, p.EvidenceIDs, "selections are de-duplicated")
+
+	t.Run("Unknown Template", func(t *testing.T) {
+		req := request()
+		req.TemplateID = "missing"
+		_, err := service.Create(ctx, store.investigation.ID, req, requester)
+		assert.ErrorIs(t, err, production.ErrUnknownTemplate)
+	})
+
+	t.Run("Artifact From Another Case", func(t *testing.T) {
+		req := request()
+		req.EvidenceIDs = []uuid.UUID{uuid.New()}
+		_, err := service.Create(ctx, store.investigation.ID, req, requester)
+		assert.ErrorIs(t, err, production.ErrArtifactNotInCase)
+	})
+
+	t.Run("Redactions", func(t *testing.T) {
+		req := request()
+		req.Redactions = []models.ProductionRedaction{{Source: production.SourceEvidence, Field: "file_path", Reason: "internal"}}
+		_, err := service.Create(ctx, store.investigation.ID, req, requester)
+		assert.ErrorIs(t, err, production.ErrInvalidProduction, "unknown field")
+
+		req.Redactions = []models.ProductionRedaction{{Source: production.SourceEvidence, Field: "source"}}
+		_, err = service.Create(ctx, store.investigation.ID, req, requester)
+		assert.ErrorIs(t, err, production.ErrInvalidProduction, "missing reason")
+
+		other := uuid.New()
+		req.Redactions = []models.ProductionRedaction{{Source: production.SourceEvidence, ArtifactID: &other, Field: "source", Reason: "third party"}}
+		_, err = service.Create(ctx, store.investigation.ID, req, requester)
+		assert.ErrorIs(t, err, production.ErrInvalidProduction, "unselected artifact")
+	})
+}
+
+func TestProductionApproveAndProduce(t *testing.T) {
+	store := newFakeProductionStore()
+	statements := store.addEvidence("Bank statements", "First National Bank")
+	interview := store.addEvidence("Interview notes", "Confidential informant")
+	service, err := production.NewService(store, productionConfig(), zap.NewNop())
+	require.NoError(t, err)
+	ctx := context.Background()
+	requester, approver := uuid.New(), uuid.New()
+
+	create := func(templateID string) *models.LawEnforcementProduction {
+		p, err := service.Create(ctx, store.investigation.ID, &models.CreateProductionRequest{
+			TemplateID:       templateID,
+			Agency:           "FBI Field Office",
+			RequestReference: "GJ-2024-118",
+			EvidenceIDs:      []uuid.UUID{statements.ID, interview.ID},
+			Redactions: []models.ProductionRedaction{
+				{Source: production.SourceEvidence, ArtifactID: &interview.ID, Field: "source", Reason: "Informant identity"},
+				{Source: production.SourceCase, Field: "description", Reason: "Internal analysis"},
+			},
+		}, requester)
+		require.NoError(t, err)
+		return p
+	}
+
+	t.Run("Self Approval", func(t *testing.T) {
+		p := create("leo_workbook")
+		_, err := service.Approve(ctx, p.ID, requester)
+		assert.ErrorIs(t, err, production.ErrSelfApproval)
+	})
+
+	t.Run("Produce Requires Approval", func(t *testing.T) {
+		p := create("leo_workbook")
+		_, err := service.Produce(ctx, p.ID, approver)
+		assert.ErrorIs(t, err, repository.ErrProductionNotApproved)
+
+		_, err = service.File(ctx, p.ID)
+		assert.ErrorIs(t, err, production.ErrNotProduced)
+	})
+
+	t.Run("Workbook", func(t *testing.T) {
+		p := create("leo_workbook")
+		_, err := service.Approve(ctx, p.ID, approver)
+		require.NoError(t, err)
+
+		produced, err := service.Produce(ctx, p.ID, approver)
+		require.NoError(t, err)
+		assert.Equal(t, models.ProductionStatusProduced, produced.Status)
+		sum := sha256.Sum256(produced.Content)
+		assert.Equal(t, hex.EncodeToString(sum[:]), *produced.FileSHA256)
+
+		_, err = service.Produce(ctx, p.ID, approver)
+		assert.ErrorIs(t, err, repository.ErrProductionNotApproved, "a production is only disclosed once")
+
+		f, err := excelize.OpenReader(bytes.NewReader(produced.Content))
+		require.NoError(t, err)
+		defer f.Close()
+		assert.Equal(t, []string{"Production", "Case", "Evidence", "Timeline", "SAR Filings"}, f.GetSheetList())
+
+		rows, err := f.GetRows("Evidence")
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		assert.Equal(t, "Source", rows[0][4])
+		assert.Equal(t, "First National Bank", rows[1][4])
+		assert.Equal(t, "[REDACTED]", rows[2][4], "redacted only for the named artifact")
+
+		rows, err = f.GetRows("Case")
+		require.NoError(t, err)
+		assert.Equal(t, store.investigation.Title, rows[1][1])
+
+		agency, err := f.GetCellValue("Production", "B1")
+		require.NoError(t, err)
+		assert.Equal(t, "FBI Field Office", agency)
+	})
+
+	t.Run("XML", func(t *testing.T) {
+		p := create("leo_xml")
+		_, err := service.Approve(ctx, p.ID, approver)
+		require.NoError(t, err)
+
+		produced, err := service.Produce(ctx, p.ID, approver)
+		require.NoError(t, err)
+		assert.Equal(t, "application/xml", *produced.ContentType)
+
+		var document struct {
+			XMLName  xml.Name `xml:"LawEnforcementProduction"`
+			Agency   string   `xml:"agency,attr"`
+			Evidence []struct {
+				Source string `xml:"Source"`
+			} `xml:"Evidence>Item"`
+			Redactions []struct {
+				Field  string `xml:"field,attr"`
+				Reason string `xml:",chardata"`
+			} `xml:"Redactions>Redaction"`
+		}
+		require.NoError(t, xml.Unmarshal(produced.Content, &document))
+		assert.Equal(t, "FBI Field Office", document.Agency)
+		require.Len(t, document.Evidence, 2)
+		assert.Equal(t, "First National Bank", document.Evidence[0].Source)
+		assert.Equal(t, "[REDACTED]", document.Evidence[1].Source)
+		require.Len(t, document.Redactions, 2)
+		assert.Equal(t, "Informant identity", document.Redactions[0].Reason)
+		assert.NotContains(t, string(produced.Content), "Confidential informant")
+		assert.NotContains(t, string(produced.Content), *store.investigation.Description)
+	})
+
+	t.Run("Disclosure Events", func(t *testing.T) {
+		require.Len(t, store.disclosures, 2)
+		disclosure := store.disclosures[0]
+		assert.Equal(t, production.DisclosureAction, disclosure.Action)
+		assert.Equal(t, approver, disclosure.UserID)
+		assert.Equal(t, store.investigation.ID, *disclosure.InvestigationID)
+		assert.Equal(t, "GJ-2024-118", disclosure.NewValues["request_reference"])
+		assert.Equal(t, []string{"case.description", "evidence.source"}, disclosure.NewValues["redacted_fields"])
+	})
+}
@@ -0,0 +1,431 @@
+package test
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/handlers"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/questionnaire"
+	"investigation-toolkit/internal/repository"
+)
+
+// fakeQuestionnaireStore keeps templates and assignments in memory
+type fakeQuestionnaireStore struct {
+	cases       map[uuid.UUID]bool
+	templates   map[uuid.UUID]models.QuestionnaireTemplate
+	assignments []models.QuestionnaireAssignment
+	clock       time.Time
+}
+
+func newFakeQuestionnaireStore() *fakeQuestionnaireStore {
+	return &fakeQuestionnaireStore{
+		cases:     map[uuid.UUID]bool{},
+		templates: map[uuid.UUID]models.QuestionnaireTemplate{},
+		clock:     time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func (f *fakeQuestionnaireStore) tick() time.Time {
+	f.clock = f.clock.Add(time.Minute)
+	return f.clock
+}
+
+func (f *fakeQuestionnaireStore) CaseExists(ctx context.Context, investigationID uuid.UUID) error {
+	if !f.cases[investigationID] {
+		return repository.ErrCaseNotFound
+	}
+	return nil
+}
+
+func (f *fakeQuestionnaireStore) CreateTemplate(ctx context.Context, template *models.QuestionnaireTemplate) error {
+	template.ID = uuid.New()
+	template.CreatedAt = f.tick()
+	template.UpdatedAt = template.CreatedAt
+	f.templates[template.ID] = *template
+	return nil
+}
+
+func (f *fakeQuestionnaireStore) GetTemplate(ctx context.Context, id uuid.UUID) (*models.QuestionnaireTemplate, error) {
+	template, ok := f.templates[id]
+	if !ok {
+		return nil, repository.ErrQuestionnaireTemplateNotFound
+	}
+	return &template, nil
+}
+
+func (f *fakeQuestionnaireStore) ListTemplates(ctx context.Context, status models.QuestionnaireTemplateStatus) ([]models.QuestionnaireTemplate, error) {
+	var templates []models.QuestionnaireTemplate
+	for _, template := range f.templates {
+		if status == "" || template.Status == status {
+			templates = append(templates, template)
+		}
+	}
+	return templates, nil
+}
+
+func (f *fakeQuestionnaireStore) UpdateTemplate(ctx context.Context, template *models.QuestionnaireTemplate) error {
+	if f.templates[template.ID].Status != models.QuestionnaireTemplateStatusDraft {
+		return repository.ErrQuestionnaireChanged
+	}
+	template.UpdatedAt = f.tick()
+	f.templates[template.ID] = *template
+	return nil
+}
+
+func (f *fakeQuestionnaireStore) SetTemplateStatus(ctx context.Context, template *models.QuestionnaireTemplate, from models.QuestionnaireTemplateStatus) error {
+	if f.templates[template.ID].Status != from {
+		return repository.ErrQuestionnaireChanged
+	}
+	template.UpdatedAt = f.tick()
+	f.templates[template.ID] = *template
+	return nil
+}
+
+func (f *fakeQuestionnaireStore) CreateAssignment(ctx context.Context, assignment *models.QuestionnaireAssignment) error {
+	if !f.cases[assignment.InvestigationID] {
+		return repository.ErrCaseNotFound
+	}
+	assignment.ID = uuid.New()
+	assignment.CreatedAt = f.tick()
+	assignment.UpdatedAt = assignment.CreatedAt
+	f.assignments = append(f.assignments, *assignment)
+	return nil
+}
+
+func (f *fakeQuestionnaireStore) GetAssignment(ctx context.Context, id uuid.UUID) (*models.QuestionnaireAssignment, error) {
+	for _, assignment := range f.assignments {
+		if assignment.ID == id {
+			return &assignment, nil
+		}
+	}
+	return nil, repository.ErrQuestionnaireNotFound
+}
+
+func (f *fakeQuestionnaireStore) ListAssignments(ctx context.Context, investigationID uuid.UUID) ([]models.QuestionnaireAssignment, error) {
+	var assignments []models.QuestionnaireAssignment
+	for _, assignment := range f.assignments {
+		if assignment.InvestigationID == investigationID {
+			assignments = append(assignments, assignment)
+		}
+	}
+	return assignments, nil
+}
+
+func (f *fakeQuestionnaireStore) UpdateAssignment(ctx context.Context, assignment *models.QuestionnaireAssignment, previous time.Time) error {
+	for i := range f.assignments {
+		if f.assignments[i].ID != assignment.ID {
+			continue
+		}
+		if !f.assignments[i].UpdatedAt.Equal(previous) {
+			return repository.ErrQuestionnaireChanged
+		}
+		assignment.UpdatedAt = f.tick()
+		f.assignments[i] = *assignment
+		return nil
+	}
+	return repository.ErrQuestionnaireNotFound
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+// kycRefreshQuestions asks for source of funds details only when the
+// customer's funds changed, and for the new source only when it is "other"
+func kycRefreshQuestions() []models.Question {
+	return []models.Question{
+		{ID: "occupation", Text: "Current occupation", Type: models.QuestionTypeText, Required: true},
+		{ID: "annual_income", Text: "Annual income", Type: models.QuestionTypeNumber, Min: floatPtr(0)},
+		{ID: "funds_changed", Text: "Has the source of funds changed?", Type: models.QuestionTypeYesNo, Required: true},
+		{
+			ID: "new_source", Text: "New source of funds", Type: models.QuestionTypeSingleChoice, Required: true,
+			Options: []models.QuestionOption{
+				{Value: "salary", Label: "Salary"},
+				{Value: "inheritance", Label: "Inheritance"},
+				{Value: "other", Label: "Other"},
+			},
+			ShowIf: &models.QuestionCondition{QuestionID: "funds_changed", AnyOf: []string{"yes"}},
+		},
+		{
+			ID: "other_source", Text: "Describe the source", Type: models.QuestionTypeLongText, Required: true,
+			ShowIf: &models.QuestionCondition{QuestionID: "new_source", AnyOf: []string{"other"}},
+		},
+		{
+			ID: "products", Text: "Products held", Type: models.QuestionTypeMultipleChoice,
+			Options: []models.QuestionOption{
+				{Value: "current", Label: "Current account"},
+				{Value: "savings", Label: "Savings"},
+				{Value: "fx", Label: "FX"},
+			},
+		},
+		{ID: "review_date", Text: "Date of customer contact", Type: models.QuestionTypeDate},
+	}
+}
+
+func TestQuestionnaireFormValidation(t *testing.T) {
+	require.NoError(t, questionnaire.ValidateForm(kycRefreshQuestions()))
+
+	tests := []struct {
+		name      string
+		questions []models.Question
+	}{
+		{"empty", nil},
+		{"duplicate id", []models.Question{
+			{ID: "q1", Text: "One", Type: models.QuestionTypeText},
+			{ID: "q1", Text: "Two", Type: models.QuestionTypeText},
+		}},
+		{"unknown type", []models.Question{{ID: "q1", Text: "One", Type: "signature"}}},
+		{"choice without options", []models.Question{
+			{ID: "q1", Text: "One", Type: models.QuestionTypeSingleChoice},
+		}},
+		{"min above max", []models.Question{
+			{ID: "q1", Text: "One", Type: models.QuestionTypeNumber, Min: floatPtr(10), Max: floatPtr(1)},
+		}},
+		{"condition on later question", []models.Question{
+			{ID: "q1", Text: "One", Type: models.QuestionTypeText,
+				ShowIf: &models.QuestionCondition{QuestionID: "q2", AnyOf: []string{"yes"}}},
+			{ID: "q2", Text: "Two", Type: models.QuestionTypeYesNo},
+		}},
+		{"condition on text question", []models.Question{
+			{ID: "q1", Text: "One", Type: models.QuestionTypeText},
+			{ID: "q2", Text: "Two", Type: models.QuestionTypeText,
+				ShowIf: &models.QuestionCondition{QuestionID: "q1", AnyOf: []string{"x"}}},
+		}},
+		{"condition on unknown option", []models.Question{
+			{ID: "q1", Text: "One", Type: models.QuestionTypeSingleChoice,
+				Options: []models.QuestionOption{{Value: "a"}, {Value: "b"}}},
+			{ID: "q2", Text: "Two", Type: models.QuestionTypeText,
+				ShowIf: &models.QuestionCondition{QuestionID: "q1", AnyOf: []string{"c"}}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, questionnaire.ValidateForm(tt.questions), questionnaire.ErrInvalidForm)
+		})
+	}
+}
+
+func TestQuestionnaireBranching(t *testing.T) {
+	questions := kycRefreshQuestions()
+
+	visible := questionnaire.Visible(questions, map[string]interface{}{"funds_changed": false})
+	assert.False(t, visible["new_source"])
+	assert.False(t, visible["other_source"])
+
+	visible = questionnaire.Visible(questions, map[string]interface{}{"funds_changed": true, "new_source": "other"})
+	assert.True(t, visible["new_source"])
+	assert.True(t, visible["other_source"])
+
+	// Hiding a question hides the questions that depend on it
+	visible = questionnaire.Visible(questions, map[string]interface{}{"funds_changed": false, "new_source": "other"})
+	assert.False(t, visible["other_source"])
+
+	// Required questions are only required when asked
+	err := questionnaire.CheckAnswers(questions, map[string]interface{}{
+		"occupation": "Engineer", "funds_changed": false,
+	}, true)
+	assert.NoError(t, err)
+
+	err = questionnaire.CheckAnswers(questions, map[string]interface{}{
+		"occupation": "Engineer", "funds_changed": true,
+	}, true)
+	var invalid *questionnaire.ValidationError
+	require.ErrorAs(t, err, &invalid)
+	require.Len(t, invalid.Fields, 1)
+	assert.Equal(t, "new_source", invalid.Fields[0].QuestionID)
+}
+
+func TestQuestionnaireAnswerValidation(t *testing.T) {
+	questions := kycRefreshQuestions()
+
+	tests := []struct {
+		name     string
+		answers  map[string]interface{}
+		question string
+	}{
+		{"text as number", map[string]interface{}{"occupation": 42.0}, "occupation"},
+		{"blank text", map[string]interface{}{"occupation": "  "}, "occupation"},
+		{"text too long", map[string]interface{}{"occupation": strings.Repeat("x", 501)}, "occupation"},
+		{"number below min", map[string]interface{}{"annual_income": -1.0}, "annual_income"},
+		{"number as text", map[string]interface{}{"annual_income": "lots"}, "annual_income"},
+		{"yes/no as text", map[string]interface{}{"funds_changed": "yes"}, "funds_changed"},
+		{"unknown option", map[string]interface{}{"new_source": "lottery"}, "new_source"},
+		{"repeated option", map[string]interface{}{"products": []interface{}{"fx", "fx"}}, "products"},
+		{"option list as string", map[string]interface{}{"products": "fx"}, "products"},
+		{"bad date", map[string]interface{}{"review_date": "01/03/2026"}, "review_date"},
+		{"unknown question", map[string]interface{}{"pep": true}, "pep"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := questionnaire.CheckAnswers(questions, tt.answers, false)
+			var invalid *questionnaire.ValidationError
+			require.ErrorAs(t, err, &invalid)
+			require.Len(t, invalid.Fields, 1)
+			assert.Equal(t, tt.question, invalid.Fields[0].QuestionID)
+		})
+	}
+
+	err := questionnaire.CheckAnswers(questions, map[string]interface{}{
+		"annual_income": 55000.0,
+		"products":      []interface{}{"current", "fx"},
+		"review_date":   "2026-03-01",
+	}, false)
+	assert.NoError(t, err)
+}
+
+func newQuestionnaireService(t *testing.T) (*questionnaire.Service, *fakeQuestionnaireStore, uuid.UUID, *models.QuestionnaireTemplate) {
+	store := newFakeQuestionnaireStore()
+	caseID := uuid.New()
+	store.cases[caseID] = true
+
+	service := questionnaire.NewService(store, zap.NewNop())
+	template, err := service.CreateTemplate(context.Background(), &models.QuestionnaireTemplateRequest{
+		Name:      "Periodic KYC refresh",
+		Questions: kycRefreshQuestions(),
+	}, uuid.New())
+	require.NoError(t, err)
+
+	return service, store, caseID, template
+}
+
+func TestQuestionnaireLifecycle(t *testing.T) {
+	ctx := context.Background()
+	service, _, caseID, template := newQuestionnaireService(t)
+	analyst := uuid.New()
+	assign := &models.AssignQuestionnaireRequest{TemplateID: template.ID, AssignedTo: analyst}
+
+	_, err := service.Assign(ctx, caseID, assign, uuid.New())
+	assert.ErrorIs(t, err, questionnaire.ErrTemplateNotPublished)
+
+	_, err = service.Publish(ctx, template.ID)
+	require.NoError(t, err)
+
+	_, err = service.UpdateTemplate(ctx, template.ID, &models.QuestionnaireTemplateRequest{
+		Name: "Edited", Questions: kycRefreshQuestions(),
+	})
+	assert.ErrorIs(t, err, questionnaire.ErrTemplateNotDraft)
+
+	_, err = service.Assign(ctx, uuid.New(), assign, uuid.New())
+	assert.ErrorIs(t, err, repository.ErrCaseNotFound)
+
+	assignment, err := service.Assign(ctx, caseID, assign, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, models.QuestionnaireStatusAssigned, assignment.Status)
+	assert.Len(t, assignment.Questions, len(template.Questions))
+
+	save := func(userID uuid.UUID, answers map[string]interface{}) (*models.QuestionnaireAssignment, error) {
+		return service.SaveResponses(ctx, assignment.ID, &models.SaveQuestionnaireResponsesRequest{Answers: answers}, userID)
+	}
+
+	_, err = save(uuid.New(), map[string]interface{}{"occupation": "Engineer"})
+	assert.ErrorIs(t, err, questionnaire.ErrNotAssignee)
+
+	saved, err := save(analyst, map[string]interface{}{
+		"occupation": "Engineer", "funds_changed": true, "new_source": "other", "other_source": "Sold a house",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.QuestionnaireStatusInProgress, saved.Status)
+
+	// Incomplete: a source is now required but was cleared
+	_, err = save(analyst, map[string]interface{}{"new_source": nil})
+	require.NoError(t, err)
+	_, err = service.Submit(ctx, assignment.ID, analyst)
+	var invalid *questionnaire.ValidationError
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "new_source", invalid.Fields[0].QuestionID)
+
+	// Answering "no" hides the follow-up questions, whose answers are dropped
+	_, err = save(analyst, map[string]interface{}{"funds_changed": false})
+	require.NoError(t, err)
+	submitted, err := service.Submit(ctx, assignment.ID, analyst)
+	require.NoError(t, err)
+	assert.Equal(t, models.QuestionnaireStatusCompleted, submitted.Status)
+	assert.Equal(t, analyst, *submitted.SubmittedBy)
+	assert.NotContains(t, submitted.Answers, "other_source")
+
+	_, err = save(analyst, map[string]interface{}{"occupation": "Retired"})
+	assert.ErrorIs(t, err, questionnaire.ErrAssignmentClosed)
+	_, err = service.Cancel(ctx, assignment.ID, uuid.New())
+	assert.ErrorIs(t, err, questionnaire.ErrAssignmentClosed)
+}
+
+func TestQuestionnaireReport(t *testing.T) {
+	ctx := context.Background()
+	service, _, caseID, template := newQuestionnaireService(t)
+	_, err := service.Publish(ctx, template.ID)
+	require.NoError(t, err)
+
+	analyst := uuid.New()
+	assign := &models.AssignQuestionnaireRequest{TemplateID: template.ID, AssignedTo: analyst}
+
+	completed, err := service.Assign(ctx, caseID, assign, uuid.New())
+	require.NoError(t, err)
+	_, err = service.SaveResponses(ctx, completed.ID, &models.SaveQuestionnaireResponsesRequest{Answers: map[string]interface{}{
+		"occupation":    "Engineer",
+		"annual_income": 55000.0,
+		"funds_changed": true,
+		"new_source":    "inheritance",
+		"products":      []interface{}{"current", "fx"},
+	}}, analyst)
+	require.NoError(t, err)
+	_, err = service.Submit(ctx, completed.ID, analyst)
+	require.NoError(t, err)
+
+	open, err := service.Assign(ctx, caseID, assign, uuid.New())
+	require.NoError(t, err)
+
+	report, err := service.Report(ctx, caseID)
+	require.NoError(t, err)
+	require.Len(t, report.Questionnaires, 1, "only completed questionnaires are reported")
+	entry := report.Questionnaires[0]
+	assert.Equal(t, completed.ID, entry.AssignmentID)
+
+	answers := map[string]string{}
+	for _, item := range entry.Answers {
+		answers[item.QuestionID] = item.Answer
+	}
+	assert.Equal(t, "Engineer", answers["occupation"])
+	assert.Equal(t, "55000", answers["annual_income"])
+	assert.Equal(t, "Yes", answers["funds_changed"])
+	assert.Equal(t, "Inheritance", answers["new_source"])
+	assert.Equal(t, "Current account; FX", answers["products"])
+	assert.Equal(t, "", answers["review_date"])
+	assert.NotContains(t, answers, "other_source", "questions that were not asked are left out")
+
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewQuestionnaireHandler(service, zap.NewNop())
+	router := gin.New()
+	router.GET("/investigations/:id/questionnaire-report", handler.GetQuestionnaireReport)
+	router.PUT("/questionnaires/:id/responses", handler.SaveResponses)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+		"/investigations/"+caseID.String()+"/questionnaire-report?format=csv", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, rows, 1+len(entry.Answers))
+	assert.Equal(t, "question_id", rows[0][4])
+
+	req := httptest.NewRequest(http.MethodPut, "/questionnaires/"+open.ID.String()+"/responses",
+		strings.NewReader(`{"answers": {"annual_income": "a lot"}}`))
+	req.Header.Set("X-User-ID", analyst.String())
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "annual_income")
+}
@@ -0,0 +1,430 @@
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	limits "aegisshield/shared/quota"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/quota"
+)
+
+func quotaConfig() config.QuotaConfig {
+	return config.QuotaConfig{
+		Enabled:          true,
+		TenantHeader:     limits.TenantHeader,
+		DefaultTenant:    limits.DefaultTenant,
+		TenantLimitBytes: 1000,
+		UserLimitBytes:   600,
+		WarningPercent:   80,
+		HardStopPercent:  100,
+		ReservationTTL:   time.Minute,
+		SweepInterval:    time.Minute,
+	}
+}
+
+// fakeQuotaStore keeps usage, reservations and charges in memory. A single
+// mutex stands in for the row locks taken while reserving.
+type fakeQuotaStore struct {
+	mu           sync.Mutex
+	usage        map[string]*models.StorageQuotaUsage
+	limits       map[string]*models.StorageQuotaLimit
+	reservations map[uuid.UUID]*models.StorageReservation
+	charges      map[uuid.UUID]*models.StorageCharge
+}
+
+func newFakeQuotaStore() *fakeQuotaStore {
+	return &fakeQuotaStore{
+		usage:        map[string]*models.StorageQuotaUsage{},
+		limits:       map[string]*models.StorageQuotaLimit{},
+		reservations: map[uuid.UUID]*models.StorageReservation{},
+		charges:      map[uuid.UUID]*models.StorageCharge{},
+	}
+}
+
+func (f *fakeQuotaStore) row(scope, subjectID string) *models.StorageQuotaUsage {
+	key := scope + "/" + subjectID
+	usage, ok := f.usage[key]
+	if !ok {
+		usage = &models.StorageQuotaUsage{Scope: scope, SubjectID: subjectID, WarnedLevel: "ok"}
+		f.usage[key] = usage
+	}
+	copied := *usage
+	if limit, ok := f.limits[key]; ok {
+		copied.LimitBytes, copied.LimitFiles = limit.LimitBytes, limit.LimitFiles
+	}
+	return &copied
+}
+
+func (f *fakeQuotaStore) adjust(scope, subjectID string, usedBytes, usedFiles, reservedBytes, reservedFiles int64) {
+	f.row(scope, subjectID)
+	usage := f.usage[scope+"/"+subjectID]
+	usage.UsedBytes += usedBytes
+	usage.UsedFiles += usedFiles
+	usage.ReservedBytes += reservedBytes
+	usage.ReservedFiles += reservedFiles
+}
+
+func (f *fakeQuotaStore) Reserve(ctx context.Context, reservation *models.StorageReservation, check func(tenant, user *models.StorageQuotaUsage) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := check(f.row("tenant", reservation.TenantID), f.row("user", reservation.UserID.String())); err != nil {
+		return err
+	}
+	f.adjust("tenant", reservation.TenantID, 0, 0, reservation.Bytes, 1)
+	f.adjust("user", reservation.UserID.String(), 0, 0, reservation.Bytes, 1)
+	f.reservations[reservation.ID] = reservation
+	return nil
+}
+
+func (f *fakeQuotaStore) Commit(ctx context.Context, reservation *models.StorageReservation, objectType string, objectID uuid.UUID) (*models.StorageQuotaUsage, *models.StorageQuotaUsage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var reservedBytes, reservedFiles int64
+	if _, ok := f.reservations[reservation.ID]; ok {
+		delete(f.reservations, reservation.ID)
+		reservedBytes, reservedFiles = reservation.Bytes, 1
+	}
+	f.uncharge(objectID)
+	f.charges[objectID] = &models.StorageCharge{
+		ObjectType: objectType, ObjectID: objectID,
+		TenantID: reservation.TenantID, UserID: reservation.UserID, Bytes: reservation.Bytes,
+	}
+	f.adjust("tenant", reservation.TenantID, reservation.Bytes, 1, -reservedBytes, -reservedFiles)
+	f.adjust("user", reservation.UserID.String(), reservation.Bytes, 1, -reservedBytes, -reservedFiles)
+	return f.row("tenant", reservation.TenantID), f.row("user", reservation.UserID.String()), nil
+}
+
+func (f *fakeQuotaStore) Release(ctx context.Context, reservationID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if reservation, ok := f.reservations[reservationID]; ok {
+		delete(f.reservations, reservationID)
+		f.adjust("tenant", reservation.TenantID, 0, 0, -reservation.Bytes, -1)
+		f.adjust("user", reservation.UserID.String(), 0, 0, -reservation.Bytes, -1)
+	}
+	return nil
+}
+
+func (f *fakeQuotaStore) ReleaseExpired(ctx context.Context, now time.Time) (int, error) {
+	f.mu.Lock()
+	var expired []uuid.UUID
+	for id, reservation := range f.reservations {
+		if reservation.ExpiresAt.Before(now) {
+			expired = append(expired, id)
+		}
+	}
+	f.mu.Unlock()
+
+	for _, id := range expired {
+		f.Release(ctx, id)
+	}
+	return len(expired), nil
+}
+
+func (f *fakeQuotaStore) uncharge(objectID uuid.UUID) {
+	if charge, ok := f.charges[objectID]; ok {
+		delete(f.charges, objectID)
+		f.adjust("tenant", charge.TenantID, -charge.Bytes, -1, 0, 0)
+		f.adjust("user", charge.UserID.String(), -charge.Bytes, -1, 0, 0)
+	}
+}
+
+func (f *fakeQuotaStore) Uncharge(ctx context.Context, objectType string, objectID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.uncharge(objectID)
+	return nil
+}
+
+func (f *fakeQuotaStore) GetUsage(ctx context.Context, scope, subjectID string) (*models.StorageQuotaUsage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.row(scope, subjectID), nil
+}
+
+func (f *fakeQuotaStore) ListUsage(ctx context.Context, scope string) ([]models.StorageQuotaUsage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var usages []models.StorageQuotaUsage
+	for _, usage := range f.usage {
+		if usage.Scope == scope {
+			usages = append(usages, *f.row(usage.Scope, usage.SubjectID))
+		}
+	}
+	return usages, nil
+}
+
+func (f *fakeQuotaStore) SetLimit(ctx context.Context, limit *models.StorageQuotaLimit) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.limits[limit.Scope+"/"+limit.SubjectID] = limit
+	return nil
+}
+
+func (f *fakeQuotaStore) MarkWarned(ctx context.Context, scope, subjectID, from, to string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	usage := f.usage[scope+"/"+subjectID]
+	if usage == nil || usage.WarnedLevel != from {
+		return false, nil
+	}
+	usage.WarnedLevel = to
+	return true, nil
+}
+
+// recordingNotifier collects the notifications sent
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []*models.NotificationEvent
+}
+
+func (n *recordingNotifier) CreateNotification(ctx context.Context, notification *models.NotificationEvent) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func (n *recordingNotifier) sent() []*models.NotificationEvent {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]*models.NotificationEvent(nil), n.notifications...)
+}
+
+func upload(t *testing.T, service *quota.Service, tenantID string, userID uuid.UUID, size int64) (uuid.UUID, error) {
+	t.Helper()
+
+	ctx := context.Background()
+	reservation, _, err := service.Reserve(ctx, tenantID, userID, size)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	objectID := uuid.New()
+	require.NoError(t, service.Commit(ctx, reservation, quota.ObjectEvidence, objectID))
+	return objectID, nil
+}
+
+func TestQuotaRefusesUploadsPastTheHardStop(t *testing.T) {
+	service := quota.NewService(newFakeQuotaStore(), nil, quotaConfig(), zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	_, err := upload(t, service, "acme", userID, 500)
+	require.NoError(t, err)
+
+	_, decision, err := service.Reserve(ctx, "acme", userID, 101)
+	assert.ErrorIs(t, err, quota.ErrExceeded)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, limits.ScopeUser, decision.Scope)
+	assert.Equal(t, int64(100), decision.Remaining)
+
+	// Up to the limit itself is still accepted
+	_, err = upload(t, service, "acme", userID, 100)
+	assert.NoError(t, err)
+}
+
+func TestQuotaTenantLimitSpansUsers(t *testing.T) {
+	service := quota.NewService(newFakeQuotaStore(), nil, quotaConfig(), zap.NewNop())
+
+	_, err := upload(t, service, "acme", uuid.New(), 600)
+	require.NoError(t, err)
+
+	decision, err := service.Check(context.Background(), "acme", uuid.New(), 401)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, limits.ScopeTenant, decision.Scope)
+	assert.Equal(t, "acme", decision.Subject)
+
+	// Other tenants are unaffected
+	decision, err = service.Check(context.Background(), "globex", uuid.New(), 401)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+}
+
+func TestQuotaBurstOfUploadsCannotPassTheLimit(t *testing.T) {
+	store := newFakeQuotaStore()
+	service := quota.NewService(store, nil, quotaConfig(), zap.NewNop())
+	userID := uuid.New()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	accepted := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := context.Background()
+			reservation, _, err := service.Reserve(ctx, "acme", userID, 100)
+			if err != nil {
+				assert.ErrorIs(t, err, quota.ErrExceeded)
+				return
+			}
+			assert.NoError(t, service.Commit(ctx, reservation, quota.ObjectEvidence, uuid.New()))
+			mu.Lock()
+			accepted++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 6, accepted)
+	report, err := service.Usage(context.Background(), limits.ScopeUser, userID.String())
+	require.NoError(t, err)
+	assert.Equal(t, int64(600), report.UsedBytes)
+	assert.Zero(t, report.ReservedBytes)
+}
+
+func TestQuotaNotifiesOnceWhenWarningThresholdIsReached(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := quota.NewService(newFakeQuotaStore(), notifier, quotaConfig(), zap.NewNop())
+	userID := uuid.New()
+
+	_, err := upload(t, service, "acme", userID, 400)
+	require.NoError(t, err)
+	assert.Empty(t, notifier.sent())
+
+	// 500 of 600 bytes takes the user past 80%
+	_, err = upload(t, service, "acme", userID, 100)
+	require.NoError(t, err)
+	_, err = upload(t, service, "acme", userID, 10)
+	require.NoError(t, err)
+
+	sent := notifier.sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, userID, sent[0].UserID)
+	assert.Equal(t, "storage_quota_warning", sent[0].Type)
+	assert.Equal(t, "user", sent[0].Metadata["scope"])
+
+	// Another user takes the tenant past 80% of its 1000 bytes
+	other := uuid.New()
+	_, err = upload(t, service, "acme", other, 300)
+	require.NoError(t, err)
+
+	sent = notifier.sent()
+	require.Len(t, sent, 2)
+	assert.Equal(t, other, sent[1].UserID)
+	assert.Equal(t, "acme", sent[1].Metadata["subject_id"])
+	assert.Contains(t, sent[1].Message, "Tenant acme")
+}
+
+func TestQuotaWarnsAgainAfterUsageFalls(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := quota.NewService(newFakeQuotaStore(), notifier, quotaConfig(), zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	objectID, err := upload(t, service, "acme", userID, 500)
+	require.NoError(t, err)
+	require.Len(t, notifier.sent(), 1)
+
+	require.NoError(t, service.Remove(ctx, quota.ObjectEvidence, objectID))
+	_, err = upload(t, service, "acme", userID, 10)
+	require.NoError(t, err)
+
+	_, err = upload(t, service, "acme", userID, 490)
+	require.NoError(t, err)
+	assert.Len(t, notifier.sent(), 2)
+}
+
+func TestQuotaReleaseAndRemoveReturnQuota(t *testing.T) {
+	service := quota.NewService(newFakeQuotaStore(), nil, quotaConfig(), zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	reservation, _, err := service.Reserve(ctx, "acme", userID, 600)
+	require.NoError(t, err)
+	_, _, err = service.Reserve(ctx, "acme", userID, 1)
+	assert.ErrorIs(t, err, quota.ErrExceeded)
+
+	service.Release(ctx, reservation)
+	objectID, err := upload(t, service, "acme", userID, 600)
+	require.NoError(t, err)
+
+	require.NoError(t, service.Remove(ctx, quota.ObjectEvidence, objectID))
+	report, err := service.Usage(ctx, limits.ScopeTenant, "acme")
+	require.NoError(t, err)
+	assert.Zero(t, report.UsedBytes)
+	assert.Zero(t, report.UsedFiles)
+	assert.Equal(t, limits.LevelOK, report.Level)
+}
+
+func TestQuotaRecommitReplacesEarlierCharge(t *testing.T) {
+	service := quota.NewService(newFakeQuotaStore(), nil, quotaConfig(), zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+	objectID := uuid.New()
+
+	for _, size := range []int64{300, 200} {
+		reservation, _, err := service.Reserve(ctx, "acme", userID, size)
+		require.NoError(t, err)
+		require.NoError(t, service.Commit(ctx, reservation, quota.ObjectEvidence, objectID))
+	}
+
+	report, err := service.Usage(ctx, limits.ScopeUser, userID.String())
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), report.UsedBytes)
+	assert.Equal(t, int64(1), report.UsedFiles)
+}
+
+func TestQuotaLimitOverridesReplaceDefaults(t *testing.T) {
+	service := quota.NewService(newFakeQuotaStore(), nil, quotaConfig(), zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	unlimited := int64(0)
+	report, err := service.SetLimit(ctx, limits.ScopeUser, userID.String(),
+		&models.SetStorageQuotaRequest{LimitBytes: &unlimited}, uuid.New())
+	require.NoError(t, err)
+	assert.True(t, report.Overridden)
+	assert.Equal(t, int64(-1), report.RemainingBytes)
+
+	// Without a user limit, the tenant's 1000 bytes apply
+	decision, err := service.Check(ctx, "acme", userID, 1000)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, limits.LevelWarning, decision.Level)
+
+	negative := int64(-1)
+	_, err = service.SetLimit(ctx, limits.ScopeTenant, "acme", &models.SetStorageQuotaRequest{LimitBytes: &negative}, uuid.New())
+	assert.ErrorIs(t, err, quota.ErrInvalidLimit)
+	_, err = service.SetLimit(ctx, limits.ScopeTenant, "not a tenant", &models.SetStorageQuotaRequest{}, uuid.New())
+	assert.ErrorIs(t, err, quota.ErrInvalidSubject)
+}
+
+func TestQuotaExpiredReservationsAreReleased(t *testing.T) {
+	store := newFakeQuotaStore()
+	service := quota.NewService(store, nil, quotaConfig(), zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	_, _, err := service.Reserve(ctx, "acme", userID, 600)
+	require.NoError(t, err)
+
+	released, err := store.ReleaseExpired(ctx, time.Now().Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+
+	decision, err := service.Check(ctx, "acme", userID, 600)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+}
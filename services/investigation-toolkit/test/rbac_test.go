@@ -0,0 +1,171 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"aegisshield/shared/rbac"
+	"investigation-toolkit/internal/handlers"
+)
+
+func TestRBACRoutePermission(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		resource string
+		action   string
+	}{
+		{"GET", "/api/v1/investigations/abc", "investigations", rbac.ActionRead},
+		{"POST", "/api/v1/investigations/abc/evidence-requests", "evidence", rbac.ActionWrite},
+		{"DELETE", "/api/v1/evidence/abc/files/f1", "evidence", rbac.ActionDelete},
+		{"PUT", "/api/v1/workflows/steps/s1/complete", "workflows", rbac.ActionWrite},
+		{"POST", "/api/v1/collaboration/comments", "investigations", rbac.ActionWrite},
+		{"GET", "/api/v1/audit/logs", "audit", rbac.ActionRead},
+		{"PUT", "/api/v1/investigations/abc/region", "residency", rbac.ActionWrite},
+		{"POST", "/api/v1/investigations/abc/residency-grants", "residency", rbac.ActionWrite},
+		{"POST", "/api/v1/investigations/abc/exports", "investigations", rbac.ActionWrite},
+		{"GET", "/api/v1/residency/report", "residency", rbac.ActionRead},
+		{"POST", "/api/v1/investigations/abc/participants", "investigations", rbac.ActionWrite},
+		{"GET", "/api/v1/investigations/abc/access-matrix", "investigations", rbac.ActionRead},
+		{"PUT", "/api/v1/evidence/abc/classification", "evidence", rbac.ActionWrite},
+		{"POST", "/api/v1/questionnaire-templates/abc/publish", "investigations", rbac.ActionWrite},
+		{"PUT", "/api/v1/questionnaires/abc/responses", "investigations", rbac.ActionWrite},
+		{"GET", "/api/v1/investigations/abc/questionnaire-report", "investigations", rbac.ActionRead},
+		{"POST", "/api/v1/investigations/abc/productions", "sar", rbac.ActionWrite},
+		{"POST", "/api/v1/productions/abc/approve", "sar", rbac.ActionWrite},
+		{"GET", "/api/v1/production-templates", "sar", rbac.ActionRead},
+		{"GET", "/api/v1/storage/quota/check", "evidence", rbac.ActionRead},
+		{"PUT", "/api/v1/quotas/tenants/acme", "usage", rbac.ActionWrite},
+		{"GET", "/api/v1/quotas/users", "usage", rbac.ActionRead},
+		{"POST", "/api/v1/investigations/abc/offline-sync", "evidence", rbac.ActionWrite},
+		{"PUT", "/api/v1/offline-sync/abc/items/i1/chunks/0", "evidence", rbac.ActionWrite},
+		{"DELETE", "/api/v1/offline-sync/abc", "evidence", rbac.ActionDelete},
+	}
+
+	for _, tt := range tests {
+		resource, action := handlers.RoutePermission(tt.method, tt.path)
+		assert.Equal(t, tt.resource, resource, tt.path)
+		assert.Equal(t, tt.action, action, tt.path)
+	}
+}
+
+func TestRBACAuthorizationMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := []byte("rbac-test-secret")
+
+	sign := func(claims jwt.MapClaims) string {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		require.NoError(t, err)
+		return token
+	}
+
+	evaluator := rbac.NewEvaluator(rbac.DefaultPolicy(), time.Minute, 100)
+	router := gin.New()
+	v1 := router.Group("/api/v1")
+	v1.Use(handlers.AuthorizationMiddleware(evaluator, rbac.NewTokenAuthenticator(secret), zap.NewNop()))
+
+	ok := func(c *gin.Context) {
+		subject, found := rbac.SubjectFromContext(c.Request.Context())
+		if found {
+			c.Header("X-Subject", subject.ID)
+		}
+		c.Status(http.StatusOK)
+	}
+	v1.GET("/investigations/:id", ok)
+	v1.PUT("/investigations/:id", ok)
+	v1.GET("/audit/logs", ok)
+	v1.GET("/external/calendar/:token", ok)
+
+	analyst := sign(jwt.MapClaims{"user_id": float64(3), "role": "analyst"})
+	investigator := sign(jwt.MapClaims{"user_id": float64(4), "role": "investigator"})
+	viewer := sign(jwt.MapClaims{"user_id": float64(5), "role": "view_only", "permissions": []string{"audit:read"}})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{"External Route Skips Auth", "GET", "/api/v1/external/calendar/feed-token", "", http.StatusOK},
+		{"Missing Token", "GET", "/api/v1/investigations/i1", "", http.StatusUnauthorized},
+		{"Analyst Reads Investigation", "GET", "/api/v1/investigations/i1", analyst, http.StatusOK},
+		{"Analyst Cannot Update Investigation", "PUT", "/api/v1/investigations/i1", analyst, http.StatusForbidden},
+		{"Investigator Updates Investigation", "PUT", "/api/v1/investigations/i1", investigator, http.StatusOK},
+		{"Analyst Cannot Read Audit", "GET", "/api/v1/audit/logs", analyst, http.StatusForbidden},
+		{"Direct Permission Grants Audit", "GET", "/api/v1/audit/logs", viewer, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+
+	t.Run("Subject Stored In Context", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/investigations/i1", nil)
+		req.Header.Set("Authorization", "Bearer "+investigator)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, "4", rec.Header().Get("X-Subject"))
+	})
+}
+
+type stubAuthenticator struct {
+	err error
+}
+
+func (a stubAuthenticator) Authenticate(ctx context.Context, token string) (*rbac.Subject, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	return &rbac.Subject{ID: "7", Roles: []string{rbac.RoleInvestigator}}, nil
+}
+
+func TestRBACAuthenticatorFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	evaluator := rbac.NewEvaluator(rbac.DefaultPolicy(), time.Minute, 100)
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"Active Session", nil, http.StatusOK},
+		{"Revoked Session", fmt.Errorf("%w: session is invalid or has been revoked", rbac.ErrInvalidToken), http.StatusUnauthorized},
+		{"User Service Unavailable", errors.New("token validation failed: connection refused"), http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			v1 := router.Group("/api/v1")
+			v1.Use(handlers.AuthorizationMiddleware(evaluator, stubAuthenticator{err: tt.err}, zap.NewNop()))
+			v1.GET("/investigations/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest("GET", "/api/v1/investigations/i1", nil)
+			req.Header.Set("Authorization", "Bearer opaque-token")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,455 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/repository"
+	"investigation-toolkit/internal/residency"
+)
+
+func residencyConfig(root string) config.ResidencyConfig {
+	return config.ResidencyConfig{
+		Enabled:       true,
+		LocalRegion:   "eu",
+		DefaultRegion: "eu",
+		EvidenceLocations: map[string]string{
+			"eu": filepath.Join(root, "eu", "evidence"),
+			"us": filepath.Join(root, "us", "evidence"),
+		},
+		ExportLocations: map[string]string{
+			"eu": filepath.Join(root, "eu", "exports"),
+			"us": filepath.Join(root, "us", "exports"),
+		},
+		MaxGrantDuration:     7 * 24 * time.Hour,
+		ReportViolationLimit: 100,
+	}
+}
+
+func regionPtr(region string) *string {
+	return &region
+}
+
+// fakeResidencyStore keeps case regions, grants and access records in memory
+type fakeResidencyStore struct {
+	regions  map[uuid.UUID]*string
+	evidence map[uuid.UUID]uuid.UUID
+	grants   map[uuid.UUID]*models.ResidencyGrant
+	access   []models.ResidencyAccess
+	exports  []models.CaseExport
+	stored   map[uuid.UUID]string
+	snapshot *repository.ResidencySnapshot
+}
+
+func newFakeResidencyStore() *fakeResidencyStore {
+	return &fakeResidencyStore{
+		regions:  map[uuid.UUID]*string{},
+		evidence: map[uuid.UUID]uuid.UUID{},
+		grants:   map[uuid.UUID]*models.ResidencyGrant{},
+		stored:   map[uuid.UUID]string{},
+		snapshot: &repository.ResidencySnapshot{},
+	}
+}
+
+func (f *fakeResidencyStore) GetCaseRegion(ctx context.Context, investigationID uuid.UUID) (*string, error) {
+	region, ok := f.regions[investigationID]
+	if !ok {
+		return nil, repository.ErrCaseNotFound
+	}
+	return region, nil
+}
+
+func (f *fakeResidencyStore) GetEvidenceCase(ctx context.Context, evidenceID uuid.UUID) (uuid.UUID, error) {
+	investigationID, ok := f.evidence[evidenceID]
+	if !ok {
+		return uuid.Nil, repository.ErrCaseNotFound
+	}
+	return investigationID, nil
+}
+
+func (f *fakeResidencyStore) GetSARFilingCase(ctx context.Context, filingID uuid.UUID) (uuid.UUID, error) {
+	return uuid.Nil, repository.ErrCaseNotFound
+}
+
+func (f *fakeResidencyStore) TagCase(ctx context.Context, investigationID uuid.UUID, region string) error {
+	current, ok := f.regions[investigationID]
+	if !ok {
+		return repository.ErrCaseNotFound
+	}
+	if current != nil {
+		return repository.ErrCaseRegionSet
+	}
+	f.regions[investigationID] = &region
+	return nil
+}
+
+func (f *fakeResidencyStore) SetEvidenceRegion(ctx context.Context, evidenceID uuid.UUID, region string) error {
+	f.stored[evidenceID] = region
+	return nil
+}
+
+func (f *fakeResidencyStore) ListCaseEvidence(ctx context.Context, investigationID uuid.UUID) ([]models.Evidence, error) {
+	var evidence []models.Evidence
+	for evidenceID, caseID := range f.evidence {
+		if caseID == investigationID {
+			evidence = append(evidence, models.Evidence{ID: evidenceID, InvestigationID: caseID, Name: "statement"})
+		}
+	}
+	return evidence, nil
+}
+
+func (f *fakeResidencyStore) CreateGrant(ctx context.Context, grant *models.ResidencyGrant) error {
+	f.grants[grant.ID] = grant
+	return nil
+}
+
+func (f *fakeResidencyStore) GetGrant(ctx context.Context, id uuid.UUID) (*models.ResidencyGrant, error) {
+	grant, ok := f.grants[id]
+	if !ok {
+		return nil, repository.ErrGrantNotActive
+	}
+	copied := *grant
+	return &copied, nil
+}
+
+func (f *fakeResidencyStore) ActiveGrant(ctx context.Context, investigationID uuid.UUID, region string, userID uuid.UUID) (*models.ResidencyGrant, error) {
+	for _, grant := range f.grants {
+		if grant.InvestigationID == investigationID && grant.Region == region &&
+			grant.GrantedTo == userID && grant.Active(time.Now()) {
+			return grant, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeResidencyStore) ListGrants(ctx context.Context, investigationID uuid.UUID) ([]models.ResidencyGrant, error) {
+	var grants []models.ResidencyGrant
+	for _, grant := range f.grants {
+		if grant.InvestigationID == investigationID {
+			grants = append(grants, *grant)
+		}
+	}
+	return grants, nil
+}
+
+func (f *fakeResidencyStore) RevokeGrant(ctx context.Context, id, revokedBy uuid.UUID) error {
+	grant, ok := f.grants[id]
+	if !ok || !grant.Active(time.Now()) {
+		return repository.ErrGrantNotActive
+	}
+	now := time.Now()
+	grant.RevokedAt = &now
+	grant.RevokedBy = &revokedBy
+	return nil
+}
+
+func (f *fakeResidencyStore) LogAccess(ctx context.Context, access *models.ResidencyAccess) error {
+	f.access = append(f.access, *access)
+	return nil
+}
+
+func (f *fakeResidencyStore) CreateExport(ctx context.Context, export *models.CaseExport) error {
+	f.exports = append(f.exports, *export)
+	return nil
+}
+
+func (f *fakeResidencyStore) ListExports(ctx context.Context, investigationID uuid.UUID) ([]models.CaseExport, error) {
+	return f.exports, nil
+}
+
+func (f *fakeResidencyStore) GetResidencySnapshot(ctx context.Context, since time.Time, misplacedLimit int) (*repository.ResidencySnapshot, error) {
+	return f.snapshot, nil
+}
+
+// fakeResidencyCases serves investigations from the residency store's case regions
+type fakeResidencyCases struct {
+	store *fakeResidencyStore
+}
+
+func (f fakeResidencyCases) GetByID(ctx context.Context, id uuid.UUID) (*models.Investigation, error) {
+	region, ok := f.store.regions[id]
+	if !ok {
+		return nil, errors.New("investigation not found")
+	}
+	return &models.Investigation{ID: id, Title: "Layering through shell companies", Region: region}, nil
+}
+
+func newResidencyService(t *testing.T) (*residency.Service, *fakeResidencyStore, config.ResidencyConfig) {
+	cfg := residencyConfig(t.TempDir())
+	store := newFakeResidencyStore()
+	return residency.NewService(store, fakeResidencyCases{store: store}, cfg, zap.NewNop()), store, cfg
+}
+
+func TestResidencyPolicyDecide(t *testing.T) {
+	policy := residency.NewPolicy(residencyConfig("/data"))
+	now := time.Now()
+
+	decision := policy.Decide(nil, nil, now)
+	assert.True(t, decision.Allowed)
+	assert.False(t, decision.CrossRegion)
+
+	decision = policy.Decide(regionPtr("eu"), nil, now)
+	assert.True(t, decision.Allowed)
+	assert.False(t, decision.CrossRegion)
+
+	decision = policy.Decide(regionPtr("us"), nil, now)
+	assert.False(t, decision.Allowed)
+	assert.True(t, decision.CrossRegion)
+	assert.Equal(t, "us", decision.CaseRegion)
+
+	grant := &models.ResidencyGrant{ID: uuid.New(), Region: "eu", ExpiresAt: now.Add(time.Hour)}
+	decision = policy.Decide(regionPtr("us"), grant, now)
+	assert.True(t, decision.Allowed)
+	assert.True(t, decision.CrossRegion)
+	require.NotNil(t, decision.GrantID)
+	assert.Equal(t, grant.ID, *decision.GrantID)
+
+	// Grants for another region, expired or revoked grants authorize nothing
+	elsewhere := &models.ResidencyGrant{ID: uuid.New(), Region: "ap", ExpiresAt: now.Add(time.Hour)}
+	assert.False(t, policy.Decide(regionPtr("us"), elsewhere, now).Allowed)
+
+	expired := &models.ResidencyGrant{ID: uuid.New(), Region: "eu", ExpiresAt: now.Add(-time.Minute)}
+	assert.False(t, policy.Decide(regionPtr("us"), expired, now).Allowed)
+
+	revokedAt := now.Add(-time.Minute)
+	revoked := &models.ResidencyGrant{ID: uuid.New(), Region: "eu", ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}
+	assert.False(t, policy.Decide(regionPtr("us"), revoked, now).Allowed)
+}
+
+func TestResidencyPolicyRegionForNewCase(t *testing.T) {
+	policy := residency.NewPolicy(residencyConfig("/data"))
+
+	region, err := policy.RegionForNewCase(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "eu", region)
+
+	region, err = policy.RegionForNewCase(regionPtr("us"))
+	require.NoError(t, err)
+	assert.Equal(t, "us", region)
+
+	_, err = policy.RegionForNewCase(regionPtr("mars"))
+	assert.True(t, errors.Is(err, residency.ErrUnknownRegion))
+}
+
+func TestResidencyRouterPinsFilesToRegion(t *testing.T) {
+	router := residency.NewRouter(residencyConfig("/data"))
+	caseID, evidenceID, exportID := uuid.New(), uuid.New(), uuid.New()
+
+	dir, err := router.EvidenceDir("us", caseID, evidenceID)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/data/us/evidence", caseID.String(), evidenceID.String()), dir)
+
+	path, err := router.ExportPath("eu", caseID, exportID)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/data/eu/exports", caseID.String(), exportID.String()+".json"), path)
+
+	_, err = router.EvidenceDir("mars", caseID, evidenceID)
+	assert.True(t, errors.Is(err, residency.ErrUnknownRegion))
+}
+
+func TestResidencyScopeOf(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		path  string
+		scope residency.Scope
+	}{
+		{"/api/v1/investigations/" + id.String(), residency.Scope{Kind: residency.ScopeCase, ID: id}},
+		{"/api/v1/investigations/" + id.String() + "/sar-filings", residency.Scope{Kind: residency.ScopeCase, ID: id}},
+		{"/api/v1/timeline/investigation/" + id.String(), residency.Scope{Kind: residency.ScopeCase, ID: id}},
+		{"/api/v1/evidence/" + id.String() + "/files/f1", residency.Scope{Kind: residency.ScopeEvidence, ID: id}},
+		{"/api/v1/audit/custody/evidence/" + id.String(), residency.Scope{Kind: residency.ScopeEvidence, ID: id}},
+		{"/api/v1/sar-filings/" + id.String() + "/traceability", residency.Scope{Kind: residency.ScopeSARFiling, ID: id}},
+		{"/api/v1/investigations", residency.Scope{}},
+		{"/api/v1/investigations/user/" + id.String(), residency.Scope{}},
+		{"/api/v1/workflows/pending", residency.Scope{}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.scope, residency.ScopeOf(tt.path), tt.path)
+	}
+}
+
+func TestResidencyCheckRecordsCrossRegionAccess(t *testing.T) {
+	service, store, _ := newResidencyService(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	local, foreign := uuid.New(), uuid.New()
+	store.regions[local] = regionPtr("eu")
+	store.regions[foreign] = regionPtr("us")
+	evidenceID := uuid.New()
+	store.evidence[evidenceID] = foreign
+
+	decision, err := service.Check(ctx, residency.Scope{Kind: residency.ScopeCase, ID: local}, &userID, "GET", "/api/v1/investigations/x")
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Empty(t, store.access)
+
+	decision, err = service.Check(ctx, residency.Scope{Kind: residency.ScopeEvidence, ID: evidenceID}, &userID, "GET", "/api/v1/evidence/x")
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	require.Len(t, store.access, 1)
+	assert.Equal(t, models.ResidencyDecisionDenied, store.access[0].Decision)
+	assert.Equal(t, foreign, store.access[0].InvestigationID)
+	assert.Equal(t, "us", store.access[0].CaseRegion)
+	assert.Equal(t, "eu", store.access[0].RequestRegion)
+
+	grant := &models.ResidencyGrant{
+		ID: uuid.New(), InvestigationID: foreign, Region: "eu", GrantedTo: userID,
+		Reason: "joint investigation", ExpiresAt: time.Now().Add(time.Hour),
+	}
+	store.grants[grant.ID] = grant
+
+	decision, err = service.Check(ctx, residency.Scope{Kind: residency.ScopeCase, ID: foreign}, &userID, "GET", "/api/v1/investigations/x")
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	require.Len(t, store.access, 2)
+	assert.Equal(t, models.ResidencyDecisionAllowed, store.access[1].Decision)
+	require.NotNil(t, store.access[1].GrantID)
+	assert.Equal(t, grant.ID, *store.access[1].GrantID)
+
+	// Another user is not covered by the grant
+	other := uuid.New()
+	decision, err = service.Check(ctx, residency.Scope{Kind: residency.ScopeCase, ID: foreign}, &other, "GET", "/api/v1/investigations/x")
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+
+	_, err = service.Check(ctx, residency.Scope{Kind: residency.ScopeCase, ID: uuid.New()}, &userID, "GET", "/api/v1/investigations/x")
+	assert.True(t, errors.Is(err, repository.ErrCaseNotFound))
+}
+
+func TestResidencyGrantValidation(t *testing.T) {
+	service, store, _ := newResidencyService(t)
+	ctx := context.Background()
+	grantedBy := uuid.New()
+
+	local, foreign, untagged := uuid.New(), uuid.New(), uuid.New()
+	store.regions[local] = regionPtr("eu")
+	store.regions[foreign] = regionPtr("us")
+	store.regions[untagged] = nil
+
+	request := func(region string, hours int) *models.CreateResidencyGrantRequest {
+		return &models.CreateResidencyGrantRequest{
+			Region: region, GrantedTo: uuid.New(), Reason: "joint investigation", ExpiresInHours: hours,
+		}
+	}
+
+	grant, err := service.Grant(ctx, local, request("us", 24), grantedBy)
+	require.NoError(t, err)
+	assert.Equal(t, "us", grant.Region)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), grant.ExpiresAt, time.Minute)
+
+	_, err = service.Grant(ctx, local, request("mars", 24), grantedBy)
+	assert.True(t, errors.Is(err, residency.ErrUnknownRegion))
+
+	_, err = service.Grant(ctx, local, request("eu", 24), grantedBy)
+	assert.True(t, errors.Is(err, residency.ErrInvalidGrant))
+
+	_, err = service.Grant(ctx, local, request("us", 24*30), grantedBy)
+	assert.True(t, errors.Is(err, residency.ErrInvalidGrant))
+
+	_, err = service.Grant(ctx, local, request("us", 0), grantedBy)
+	assert.True(t, errors.Is(err, residency.ErrInvalidGrant))
+
+	// Grants are only made from the case's home region
+	_, err = service.Grant(ctx, foreign, request("ap", 24), grantedBy)
+	assert.True(t, errors.Is(err, residency.ErrUnknownRegion))
+	_, err = service.Grant(ctx, foreign, request("us", 24), grantedBy)
+	assert.True(t, errors.Is(err, residency.ErrNotHomeRegion))
+	_, err = service.Grant(ctx, untagged, request("us", 24), grantedBy)
+	assert.True(t, errors.Is(err, residency.ErrNotHomeRegion))
+
+	revoked, err := service.Revoke(ctx, grant.ID, grantedBy)
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+	assert.False(t, store.grants[grant.ID].Active(time.Now()))
+
+	_, err = service.Revoke(ctx, grant.ID, grantedBy)
+	assert.True(t, errors.Is(err, repository.ErrGrantNotActive))
+}
+
+func TestResidencyExportWritesToCaseRegion(t *testing.T) {
+	service, store, cfg := newResidencyService(t)
+	ctx := context.Background()
+
+	caseID := uuid.New()
+	store.regions[caseID] = regionPtr("us")
+	store.evidence[uuid.New()] = caseID
+
+	export, err := service.Export(ctx, caseID, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, "us", export.Region)
+	assert.Equal(t, filepath.Join(cfg.ExportLocations["us"], caseID.String(), export.ID.String()+".json"), export.FilePath)
+	require.NotNil(t, export.FileHash)
+	require.Len(t, store.exports, 1)
+
+	content, err := os.ReadFile(export.FilePath)
+	require.NoError(t, err)
+	var bundle struct {
+		Region   string            `json:"region"`
+		Evidence []models.Evidence `json:"evidence"`
+	}
+	require.NoError(t, json.Unmarshal(content, &bundle))
+	assert.Equal(t, "us", bundle.Region)
+	assert.Len(t, bundle.Evidence, 1)
+
+	// Files of untagged cases go to the default region
+	untagged := uuid.New()
+	store.regions[untagged] = nil
+	dir, region, err := service.EvidenceDir(ctx, untagged, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, "eu", region)
+	assert.Contains(t, dir, cfg.EvidenceLocations["eu"])
+}
+
+func TestResidencyReport(t *testing.T) {
+	policy := residency.NewPolicy(residencyConfig("/data"))
+	now := time.Now()
+	since := now.AddDate(0, 0, -30)
+
+	report := residency.BuildReport(&repository.ResidencySnapshot{
+		Regions: []repository.RegionCounts{
+			{Region: "eu", Cases: 10, EvidenceFiles: 40},
+			{Region: "us", Cases: 4, EvidenceFiles: 12},
+		},
+		Access: []repository.AccessCounts{
+			{CaseRegion: "us", RequestRegion: "eu", Decision: models.ResidencyDecisionDenied, Requests: 3},
+			{CaseRegion: "us", RequestRegion: "eu", Decision: models.ResidencyDecisionAllowed, Requests: 2},
+		},
+		ActiveGrants: 1,
+	}, policy, since, now)
+
+	assert.True(t, report.Compliant)
+	assert.Empty(t, report.Issues)
+	assert.Equal(t, int64(3), report.DeniedRequests)
+	assert.Empty(t, report.Misplaced)
+
+	report = residency.BuildReport(&repository.ResidencySnapshot{
+		Regions: []repository.RegionCounts{
+			{Region: "eu", Cases: 10, EvidenceFiles: 40, EvidenceMisplaced: 2},
+			{Region: "ap", Cases: 1},
+		},
+		Misplaced: []repository.Misplacement{
+			{Kind: "evidence", ObjectID: uuid.New(), InvestigationID: uuid.New(), CaseRegion: "eu", StoredRegion: "us"},
+		},
+		UntaggedCases:      5,
+		UnverifiedEvidence: 7,
+	}, policy, since, now)
+
+	assert.False(t, report.Compliant)
+	assert.Len(t, report.Issues, 4)
+	assert.Len(t, report.Misplaced, 1)
+}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"investigation-toolkit/internal/scanner"
+)
+
+func TestParseClamAVReply(t *testing.T) {
+	result, err := scanner.ParseReply("stream: OK\x00")
+	require.NoError(t, err)
+	assert.True(t, result.Clean)
+	assert.Empty(t, result.Signature)
+
+	result, err = scanner.ParseReply("stream: Eicar-Test-Signature FOUND\x00")
+	require.NoError(t, err)
+	assert.False(t, result.Clean)
+	assert.Equal(t, "Eicar-Test-Signature", result.Signature)
+
+	_, err = scanner.ParseReply("INSTREAM size limit exceeded. ERROR\x00")
+	assert.Error(t, err)
+}
+
+func TestClamAVScannerStreamsContent(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	content := bytes.Repeat([]byte("evidence"), 20000)
+	received := make(chan []byte, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		command := make([]byte, len("zINSTREAM\x00"))
+		if _, err := io.ReadFull(conn, command); err != nil {
+			return
+		}
+
+		var data []byte
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(conn, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				break
+			}
+			chunk := make([]byte, n)
+			if _, err := io.ReadFull(conn, chunk); err != nil {
+				return
+			}
+			data = append(data, chunk...)
+		}
+
+		received <- data
+		conn.Write([]byte("stream: OK\x00"))
+	}()
+
+	s := scanner.NewClamAVScanner(listener.Addr().String(), 5*time.Second)
+	result, err := s.Scan(context.Background(), bytes.NewReader(content))
+	require.NoError(t, err)
+	assert.True(t, result.Clean)
+	assert.Equal(t, content, <-received)
+}
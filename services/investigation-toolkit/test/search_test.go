@@ -0,0 +1,173 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/search"
+)
+
+// fakeElasticsearch records requests and serves alias lookups from a fixed table
+type fakeElasticsearch struct {
+	mu       sync.Mutex
+	aliases  map[string]string // alias to index
+	requests []string
+	bodies   map[string]map[string]interface{}
+}
+
+func newFakeElasticsearch(aliases map[string]string) *fakeElasticsearch {
+	return &fakeElasticsearch{aliases: aliases, bodies: make(map[string]map[string]interface{})}
+}
+
+func (f *fakeElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	request := r.Method + " " + r.URL.Path
+	f.requests = append(f.requests, request)
+
+	raw, _ := io.ReadAll(r.Body)
+	if len(raw) > 0 {
+		var body map[string]interface{}
+		_ = json.Unmarshal(raw, &body)
+		f.bodies[request] = body
+	}
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/_alias/"):
+		index, ok := f.aliases[strings.TrimPrefix(r.URL.Path, "/_alias/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{index: map[string]interface{}{}})
+	case strings.HasSuffix(r.URL.Path, "/_count"):
+		w.Write([]byte(`{"count": 42}`))
+	case strings.Contains(r.URL.Path, "-next/_doc/"):
+		if r.URL.Query().Get("require_alias") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.Write([]byte(`{"acknowledged": true}`))
+	}
+}
+
+func (f *fakeElasticsearch) seen(request string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.requests {
+		if r == request {
+			return true
+		}
+	}
+	return false
+}
+
+func searchConfig(address string) config.SearchConfig {
+	return config.SearchConfig{
+		Addresses:           []string{address},
+		RequestTimeout:      5 * time.Second,
+		IndexPrefix:         "itk",
+		ReindexStateRefresh: time.Minute,
+		RetiredWarmAfter:    24 * time.Hour,
+		RetiredColdAfter:    7 * 24 * time.Hour,
+		RetiredDeleteAfter:  90 * 24 * time.Hour,
+	}
+}
+
+type staticJobs []models.SearchReindexJob
+
+func (s staticJobs) ListActive(ctx context.Context) ([]models.SearchReindexJob, error) {
+	return s, nil
+}
+
+func TestSearchIndexNaming(t *testing.T) {
+	naming := search.Naming{Prefix: "itk"}
+
+	assert.Equal(t, "itk-evidence", naming.Alias(search.IndexEvidence))
+	assert.Equal(t, "itk-evidence-v3", naming.Versioned(search.IndexEvidence, 3))
+	assert.Equal(t, "itk-evidence-next", naming.Next(search.IndexEvidence))
+
+	version, ok := naming.Version(search.IndexEvidence, "itk-evidence-v12")
+	assert.True(t, ok)
+	assert.Equal(t, 12, version)
+
+	_, ok = naming.Version(search.IndexEvidence, "itk-evidence")
+	assert.False(t, ok)
+	_, ok = naming.Version(search.IndexEvidence, "itk-investigations-v2")
+	assert.False(t, ok)
+}
+
+func TestSearchLifecyclePolicy(t *testing.T) {
+	cfg := searchConfig("http://localhost:9200")
+
+	phases := search.LifecyclePolicy(cfg)["policy"].(map[string]interface{})["phases"].(map[string]interface{})
+	assert.Equal(t, "86400s", phases["warm"].(map[string]interface{})["min_age"])
+	assert.Equal(t, "604800s", phases["cold"].(map[string]interface{})["min_age"])
+	assert.Contains(t, phases, "delete")
+
+	cfg.RetiredDeleteAfter = 0
+	phases = search.LifecyclePolicy(cfg)["policy"].(map[string]interface{})["phases"].(map[string]interface{})
+	assert.NotContains(t, phases, "delete", "retired indices stay in the cold tier when no delete age is set")
+}
+
+func TestSearchEnsureIndicesCreatesFirstVersion(t *testing.T) {
+	fake := newFakeElasticsearch(map[string]string{
+		"itk-investigations": "itk-investigations-v2",
+	})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	orchestrator := search.NewOrchestrator(search.NewClient(searchConfig(server.URL)), nil, searchConfig(server.URL), zap.NewNop())
+	require.NoError(t, orchestrator.EnsureIndices(context.Background()))
+
+	assert.True(t, fake.seen("PUT /itk-evidence-v1"))
+	assert.True(t, fake.seen("PUT /itk-timeline-v1"))
+	assert.False(t, fake.seen("PUT /itk-investigations-v1"), "existing indices are left alone")
+	assert.Contains(t, fake.bodies["PUT /itk-evidence-v1"]["aliases"], "itk-evidence")
+
+	statuses, err := orchestrator.Status(context.Background())
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "itk-investigations-v2", statuses[1].CurrentIndex)
+	assert.Equal(t, 2, statuses[1].Version)
+	assert.Equal(t, int64(42), statuses[1].Documents)
+}
+
+func TestSearchIndexerDualWritesDuringReindex(t *testing.T) {
+	fake := newFakeElasticsearch(nil)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	cfg := searchConfig(server.URL)
+	jobs := staticJobs{{IndexName: search.IndexEvidence, Status: models.ReindexStatusBackfilling}}
+	indexer := search.NewIndexer(search.NewClient(cfg), jobs, cfg, zap.NewNop())
+	ctx := context.Background()
+
+	require.NoError(t, indexer.Index(ctx, search.IndexEvidence, "ev-1", map[string]interface{}{"name": "ledger"}))
+	assert.True(t, fake.seen("PUT /itk-evidence/_doc/ev-1"))
+	assert.True(t, fake.seen("PUT /itk-evidence-next/_doc/ev-1"), "writes go to the index being built")
+
+	require.NoError(t, indexer.Index(ctx, search.IndexTimeline, "tl-1", map[string]interface{}{"title": "wire"}))
+	assert.True(t, fake.seen("PUT /itk-timeline/_doc/tl-1"))
+	assert.False(t, fake.seen("PUT /itk-timeline-next/_doc/tl-1"))
+
+	require.NoError(t, indexer.Delete(ctx, search.IndexEvidence, "ev-1"))
+	assert.True(t, fake.seen("DELETE /itk-evidence-next/_doc/ev-1"))
+}
@@ -0,0 +1,157 @@
+package test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"investigation-toolkit/internal/config"
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/tiering"
+)
+
+func storageObject(tier models.StorageTier, age time.Duration, now time.Time) *models.EvidenceStorageObject {
+	return &models.EvidenceStorageObject{
+		EvidenceID:    uuid.New(),
+		Tier:          tier,
+		RestoreStatus: models.RestoreStatusNone,
+		CreatedAt:     now.Add(-age),
+	}
+}
+
+func TestTieringPolicyTargetTier(t *testing.T) {
+	policy := tiering.NewPolicy(config.TieringConfig{InfrequentAfterDays: 30, ArchiveAfterDays: 180})
+	now := time.Now()
+	day := 24 * time.Hour
+
+	tier, move := policy.TargetTier(storageObject(models.StorageTierStandard, 10*day, now), now)
+	assert.False(t, move)
+	assert.Equal(t, models.StorageTierStandard, tier)
+
+	tier, move = policy.TargetTier(storageObject(models.StorageTierStandard, 45*day, now), now)
+	assert.True(t, move)
+	assert.Equal(t, models.StorageTierInfrequentAccess, tier)
+
+	tier, move = policy.TargetTier(storageObject(models.StorageTierStandard, 200*day, now), now)
+	assert.True(t, move, "files older than the archive age skip the infrequent access tier")
+	assert.Equal(t, models.StorageTierArchive, tier)
+
+	tier, move = policy.TargetTier(storageObject(models.StorageTierInfrequentAccess, 200*day, now), now)
+	assert.True(t, move)
+	assert.Equal(t, models.StorageTierArchive, tier)
+
+	_, move = policy.TargetTier(storageObject(models.StorageTierArchive, 400*day, now), now)
+	assert.False(t, move)
+
+	// Recent access keeps a file in its current tier
+	accessed := storageObject(models.StorageTierStandard, 45*day, now)
+	lastAccess := now.Add(-2 * day)
+	accessed.LastAccessedAt = &lastAccess
+	_, move = policy.TargetTier(accessed, now)
+	assert.False(t, move)
+
+	// Files with a restore in flight are left alone
+	restoring := storageObject(models.StorageTierStandard, 200*day, now)
+	restoring.RestoreStatus = models.RestoreStatusInProgress
+	_, move = policy.TargetTier(restoring, now)
+	assert.False(t, move)
+}
+
+func TestTieringAvailability(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, tiering.AvailabilityAvailable, tiering.AvailabilityOf(storageObject(models.StorageTierStandard, 0, now)))
+	assert.Equal(t, tiering.AvailabilityAvailable, tiering.AvailabilityOf(storageObject(models.StorageTierInfrequentAccess, 0, now)))
+
+	archived := storageObject(models.StorageTierArchive, 0, now)
+	assert.Equal(t, tiering.AvailabilityArchived, tiering.AvailabilityOf(archived))
+
+	archived.RestoreStatus = models.RestoreStatusInProgress
+	assert.Equal(t, tiering.AvailabilityRestoring, tiering.AvailabilityOf(archived))
+	assert.Equal(t, "restoring_from_archive", string(tiering.AvailabilityOf(archived)))
+
+	archived.RestoreStatus = models.RestoreStatusRestored
+	assert.Equal(t, tiering.AvailabilityAvailable, tiering.AvailabilityOf(archived))
+}
+
+func readAll(t *testing.T, backend tiering.Backend, key string) string {
+	reader, err := backend.Open(context.Background(), key)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestLocalBackendTransitionsAndRestore(t *testing.T) {
+	ctx := context.Background()
+	basePath := t.TempDir()
+	backend := tiering.NewLocalBackend(basePath, 0)
+
+	original := filepath.Join(basePath, "case-1", "evidence-1", "statement.pdf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(original), 0750))
+	require.NoError(t, os.WriteFile(original, []byte("bank statement"), 0640))
+
+	infrequentKey, err := backend.Transition(ctx, original, models.StorageTierInfrequentAccess)
+	require.NoError(t, err)
+	assert.NotEqual(t, original, infrequentKey)
+	assert.NoFileExists(t, original)
+	assert.Equal(t, "bank statement", readAll(t, backend, infrequentKey))
+
+	archiveKey, err := backend.Transition(ctx, infrequentKey, models.StorageTierArchive)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(archiveKey, ".gz"))
+	assert.NoFileExists(t, infrequentKey)
+
+	raw, err := os.ReadFile(archiveKey)
+	require.NoError(t, err)
+	assert.NotEqual(t, "bank statement", string(raw), "archived files are compressed")
+	assert.Equal(t, "bank statement", readAll(t, backend, archiveKey))
+
+	_, err = backend.RestoreReady(ctx, archiveKey)
+	assert.Error(t, err, "restore readiness requires a restore request")
+
+	require.NoError(t, backend.RequestRestore(ctx, archiveKey))
+	ready, err := backend.RestoreReady(ctx, archiveKey)
+	require.NoError(t, err)
+	assert.True(t, ready)
+
+	require.NoError(t, backend.ExpireRestore(ctx, archiveKey))
+	_, err = backend.RestoreReady(ctx, archiveKey)
+	assert.Error(t, err)
+
+	standardKey, err := backend.Transition(ctx, archiveKey, models.StorageTierStandard)
+	require.NoError(t, err)
+	assert.Equal(t, original, standardKey)
+	assert.Equal(t, "bank statement", readAll(t, backend, standardKey))
+
+	_, err = backend.Transition(ctx, filepath.Join(os.TempDir(), "outside.pdf"), models.StorageTierArchive)
+	assert.Error(t, err)
+}
+
+func TestLocalBackendRestoreDelay(t *testing.T) {
+	ctx := context.Background()
+	basePath := t.TempDir()
+	backend := tiering.NewLocalBackend(basePath, time.Hour)
+
+	key := filepath.Join(basePath, "case-1", "evidence-1", "ledger.csv")
+	require.NoError(t, os.MkdirAll(filepath.Dir(key), 0750))
+	require.NoError(t, os.WriteFile(key, []byte("a,b"), 0640))
+
+	archiveKey, err := backend.Transition(ctx, key, models.StorageTierArchive)
+	require.NoError(t, err)
+
+	require.NoError(t, backend.RequestRestore(ctx, archiveKey))
+	ready, err := backend.RestoreReady(ctx, archiveKey)
+	require.NoError(t, err)
+	assert.False(t, ready, "restores are not ready until the restore delay has passed")
+}
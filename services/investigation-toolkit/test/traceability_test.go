@@ -0,0 +1,251 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"investigation-toolkit/internal/models"
+	"investigation-toolkit/internal/traceability"
+)
+
+func sarFiling(investigationID uuid.UUID, alertIDs []string, evidenceIDs ...uuid.UUID) *models.SARFiling {
+	filedAt := time.Now()
+	return &models.SARFiling{
+		ID:              uuid.New(),
+		InvestigationID: investigationID,
+		FilingType:      models.SARFilingTypeInitial,
+		Status:          models.SARStatusFiled,
+		AlertIDs:        pq.StringArray(alertIDs),
+		EvidenceIDs:     models.UUIDArray(evidenceIDs),
+		FiledAt:         &filedAt,
+	}
+}
+
+func citedEvidence(investigationID uuid.UUID) *models.Evidence {
+	return &models.Evidence{
+		ID:              uuid.New(),
+		InvestigationID: investigationID,
+		Name:            "bank statement",
+		Status:          models.EvidenceStatusAuthenticated,
+		IsAuthenticated: true,
+		CollectedAt:     time.Now().Add(-48 * time.Hour),
+	}
+}
+
+func issueCodes(trace *traceability.Trace) []string {
+	codes := []string{}
+	for _, issue := range trace.Issues {
+		codes = append(codes, issue.Code)
+	}
+	return codes
+}
+
+func TestTraceabilityValidateCompleteChain(t *testing.T) {
+	caseID := uuid.New()
+	evidence := citedEvidence(caseID)
+	filing := sarFiling(caseID, []string{"alert-1"}, evidence.ID)
+
+	trace := traceability.Validate(traceability.Chain{
+		Filing: filing,
+		Case:   &models.Investigation{ID: caseID, Title: "Structuring ring"},
+		Alerts: map[string]traceability.AlertLookup{
+			"alert-1": {Alert: &traceability.Alert{ID: "alert-1", Title: "Rapid deposits"}, CaseIDs: []string{caseID.String()}},
+		},
+		Evidence: map[uuid.UUID]*models.Evidence{evidence.ID: evidence},
+	})
+
+	assert.True(t, trace.Complete)
+	assert.Empty(t, trace.Issues)
+	assert.True(t, trace.Case.Found)
+	require.Len(t, trace.Alerts, 1)
+	assert.True(t, trace.Alerts[0].LinkedToCase)
+	require.Len(t, trace.Evidence, 1)
+	assert.True(t, trace.Evidence[0].Found)
+}
+
+func TestTraceabilityValidateBrokenLinks(t *testing.T) {
+	caseID := uuid.New()
+	otherCase := citedEvidence(uuid.New())
+	rejected := citedEvidence(caseID)
+	rejected.Status = models.EvidenceStatusRejected
+	missing := uuid.New()
+	filing := sarFiling(caseID, []string{"orphan", "unlinked"}, otherCase.ID, rejected.ID, missing)
+
+	trace := traceability.Validate(traceability.Chain{
+		Filing: filing,
+		Alerts: map[string]traceability.AlertLookup{
+			"orphan":   {},
+			"unlinked": {Alert: &traceability.Alert{ID: "unlinked"}, CaseIDs: []string{uuid.NewString()}},
+		},
+		Evidence: map[uuid.UUID]*models.Evidence{otherCase.ID: otherCase, rejected.ID: rejected},
+	})
+
+	assert.False(t, trace.Complete)
+	assert.ElementsMatch(t, []string{
+		traceability.IssueCaseNotFound,
+		traceability.IssueAlertNotFound,
+		traceability.IssueAlertNotLinked,
+		traceability.IssueEvidenceOtherCase,
+		traceability.IssueEvidenceWithdrawn,
+		traceability.IssueEvidenceNotFound,
+	}, issueCodes(trace))
+}
+
+func TestTraceabilityValidateEmptyFiling(t *testing.T) {
+	caseID := uuid.New()
+
+	trace := traceability.Validate(traceability.Chain{
+		Filing: sarFiling(caseID, nil),
+		Case:   &models.Investigation{ID: caseID},
+	})
+
+	assert.False(t, trace.Complete)
+	assert.ElementsMatch(t, []string{traceability.IssueNoAlerts, traceability.IssueNoEvidence}, issueCodes(trace))
+}
+
+func TestTraceabilityValidateWarningsKeepChainComplete(t *testing.T) {
+	caseID := uuid.New()
+	evidence := citedEvidence(caseID)
+	evidence.IsAuthenticated = false
+	filing := sarFiling(caseID, []string{"alert-1"}, evidence.ID)
+	evidence.CollectedAt = filing.FiledAt.Add(time.Hour)
+
+	trace := traceability.Validate(traceability.Chain{
+		Filing:   filing,
+		Case:     &models.Investigation{ID: caseID},
+		Alerts:   map[string]traceability.AlertLookup{"alert-1": {Err: errors.New("connection refused")}},
+		Evidence: map[uuid.UUID]*models.Evidence{evidence.ID: evidence},
+	})
+
+	assert.True(t, trace.Complete)
+	assert.ElementsMatch(t, []string{
+		traceability.IssueAlertUnverified,
+		traceability.IssueEvidenceUnauthenticated,
+		traceability.IssueEvidenceAfterFiling,
+	}, issueCodes(trace))
+	for _, issue := range trace.Issues {
+		assert.Equal(t, traceability.SeverityWarning, issue.Severity)
+	}
+}
+
+func TestTraceabilityReportSummary(t *testing.T) {
+	complete := &traceability.Trace{Complete: true, Issues: []traceability.Issue{
+		{Code: traceability.IssueAlertUnverified, Severity: traceability.SeverityWarning},
+	}}
+	broken := &traceability.Trace{Complete: false, Issues: []traceability.Issue{
+		{Code: traceability.IssueEvidenceNotFound, Severity: traceability.SeverityError},
+		{Code: traceability.IssueEvidenceNotFound, Severity: traceability.SeverityError},
+	}}
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := traceability.NewReport(from, from.AddDate(0, 3, 0), []*traceability.Trace{complete, broken})
+
+	assert.Equal(t, 2, report.Summary.Filings)
+	assert.Equal(t, 1, report.Summary.Complete)
+	assert.Equal(t, 1, report.Summary.Incomplete)
+	assert.Equal(t, 1, report.Summary.Warnings)
+	assert.Equal(t, 2, report.Summary.IssueCodes[traceability.IssueEvidenceNotFound])
+	assert.Same(t, broken, report.Filings[0], "broken chains are listed first")
+}
+
+type fakeFilings struct {
+	filings  []models.SARFiling
+	evidence []models.Evidence
+}
+
+func (f *fakeFilings) GetFiledBetween(ctx context.Context, from, to time.Time) ([]models.SARFiling, error) {
+	return f.filings, nil
+}
+
+func (f *fakeFilings) GetEvidenceByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Evidence, error) {
+	return f.evidence, nil
+}
+
+type fakeCases map[uuid.UUID]*models.Investigation
+
+func (f fakeCases) GetByID(ctx context.Context, id uuid.UUID) (*models.Investigation, error) {
+	if investigation, ok := f[id]; ok {
+		return investigation, nil
+	}
+	return nil, errors.New("investigation not found")
+}
+
+func TestTracerUsesAlertingEngine(t *testing.T) {
+	caseID := uuid.New()
+	evidence := citedEvidence(caseID)
+	filing := sarFiling(caseID, []string{"alert-1", "alert-2"}, evidence.ID)
+
+	var (
+		mu             sync.Mutex
+		authorizations []string
+	)
+	engine := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/alerts/alert-1":
+			json.NewEncoder(w).Encode(map[string]string{"id": "alert-1", "title": "Rapid deposits", "severity": "high"})
+		case "/alert-clusters/alerts/alert-1/cases":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"cases": []map[string]string{{"alert_id": "alert-1", "case_id": caseID.String()}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer engine.Close()
+
+	tracer := traceability.NewTracer(
+		&fakeFilings{filings: []models.SARFiling{*filing}, evidence: []models.Evidence{*evidence}},
+		fakeCases{caseID: {ID: caseID, Title: "Structuring ring"}},
+		traceability.NewHTTPAlertSource(engine.URL, 5*time.Second),
+		4,
+	)
+
+	ctx := traceability.WithAuthorization(context.Background(), "Bearer analyst-token")
+	report, err := tracer.Report(ctx, time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, report.Filings, 1)
+
+	trace := report.Filings[0]
+	assert.False(t, trace.Complete)
+	assert.Equal(t, []string{traceability.IssueAlertNotFound}, issueCodes(trace))
+	assert.Equal(t, "alert-2", trace.Issues[0].Reference)
+	assert.Equal(t, "high", trace.Alerts[0].Severity)
+	assert.True(t, trace.Alerts[0].LinkedToCase)
+	for _, authorization := range authorizations {
+		assert.Equal(t, "Bearer analyst-token", authorization)
+	}
+}
+
+func TestTracerWithoutAlertingEngine(t *testing.T) {
+	caseID := uuid.New()
+	evidence := citedEvidence(caseID)
+	filing := sarFiling(caseID, []string{"alert-1"}, evidence.ID)
+
+	tracer := traceability.NewTracer(
+		&fakeFilings{evidence: []models.Evidence{*evidence}},
+		fakeCases{caseID: {ID: caseID}},
+		nil,
+		1,
+	)
+
+	trace, err := tracer.Trace(context.Background(), filing)
+	require.NoError(t, err)
+	assert.True(t, trace.Complete)
+	assert.Equal(t, []string{traceability.IssueAlertUnverified}, issueCodes(trace))
+	assert.False(t, trace.Alerts[0].Verified)
+}
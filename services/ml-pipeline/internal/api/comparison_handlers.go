@@ -0,0 +1,269 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"../models"
+	"../monitoring"
+)
+
+// comparisonQuery is the window, alert threshold and histogram resolution
+// a champion/challenger request asks for
+type comparisonQuery struct {
+	from      time.Time
+	to        time.Time
+	threshold float64
+	bins      int
+}
+
+// GetABTests returns A/B tests for the model monitoring pages to pick from
+func (h *Handler) GetABTests(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+
+	tests, err := h.repos.ABTest.List(c.Query("status"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list A/B tests", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve A/B tests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ab_tests": tests})
+}
+
+// GetScoreDistributions returns champion and challenger score histograms over a window
+func (h *Handler) GetScoreDistributions(c *gin.Context) {
+	test, query, ok := h.comparisonRequest(c)
+	if !ok {
+		return
+	}
+
+	report := h.newComparisonReport(test, query)
+	if err := h.addScoreDistributions(report, test, query); err != nil {
+		h.respondComparisonError(c, test, err, "Failed to retrieve score distributions")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetAlertConversion returns champion and challenger alert and conversion rates over a window
+func (h *Handler) GetAlertConversion(c *gin.Context) {
+	test, query, ok := h.comparisonRequest(c)
+	if !ok {
+		return
+	}
+
+	report := h.newComparisonReport(test, query)
+	if err := h.addConversion(report, test, query); err != nil {
+		h.respondComparisonError(c, test, err, "Failed to retrieve alert conversion")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetAgreementMatrix returns how often champion and challenger alert on the same events
+func (h *Handler) GetAgreementMatrix(c *gin.Context) {
+	test, query, ok := h.comparisonRequest(c)
+	if !ok {
+		return
+	}
+
+	report := h.newComparisonReport(test, query)
+	if err := h.addAgreement(report, test, query); err != nil {
+		h.respondComparisonError(c, test, err, "Failed to retrieve agreement matrix")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetComparison returns score distributions, conversion and agreement in one
+// response, as the model monitoring overview page shows them together
+func (h *Handler) GetComparison(c *gin.Context) {
+	test, query, ok := h.comparisonRequest(c)
+	if !ok {
+		return
+	}
+
+	report := h.newComparisonReport(test, query)
+	if err := h.addScoreDistributions(report, test, query); err != nil {
+		h.respondComparisonError(c, test, err, "Failed to retrieve score distributions")
+		return
+	}
+	if err := h.addConversion(report, test, query); err != nil {
+		h.respondComparisonError(c, test, err, "Failed to retrieve alert conversion")
+		return
+	}
+	if err := h.addAgreement(report, test, query); err != nil {
+		h.respondComparisonError(c, test, err, "Failed to retrieve agreement matrix")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// comparisonRequest loads the A/B test and parses the comparison query,
+// responding with an error when either fails
+func (h *Handler) comparisonRequest(c *gin.Context) (*models.ABTest, *comparisonQuery, bool) {
+	testID := c.Param("id")
+	if testID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A/B test ID is required"})
+		return nil, nil, false
+	}
+
+	query, err := h.parseComparisonQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+
+	test, err := h.repos.ABTest.GetByID(testID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "A/B test not found"})
+		return nil, nil, false
+	}
+	if err != nil {
+		h.logger.Error("Failed to get A/B test", zap.String("test_id", testID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve A/B test"})
+		return nil, nil, false
+	}
+
+	return test, query, true
+}
+
+// parseComparisonQuery reads from and to, or a window ending now, plus the
+// alert threshold and histogram bins, applying the configured defaults and limits
+func (h *Handler) parseComparisonQuery(c *gin.Context) (*comparisonQuery, error) {
+	cfg := h.config.ML.ABTesting.Comparison
+	query := &comparisonQuery{
+		to:        time.Now().UTC(),
+		threshold: cfg.AlertThreshold,
+		bins:      cfg.DefaultBins,
+	}
+
+	if to := c.Query("to"); to != "" {
+		parsed, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to parameter, expected RFC 3339")
+		}
+		query.to = parsed
+	}
+	if from := c.Query("from"); from != "" {
+		parsed, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from parameter, expected RFC 3339")
+		}
+		query.from = parsed
+	} else {
+		window := cfg.DefaultWindow
+		if value := c.Query("window"); value != "" {
+			parsed, err := monitoring.ParseWindow(value)
+			if err != nil {
+				return nil, err
+			}
+			window = parsed
+		}
+		query.from = query.to.Add(-window)
+	}
+
+	if !query.from.Before(query.to) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+	if cfg.MaxWindow > 0 && query.to.Sub(query.from) > cfg.MaxWindow {
+		return nil, fmt.Errorf("window may not exceed %s", cfg.MaxWindow)
+	}
+
+	if value := c.Query("threshold"); value != "" {
+		threshold, err := strconv.ParseFloat(value, 64)
+		if err != nil || threshold < 0 || threshold > 1 {
+			return nil, fmt.Errorf("threshold must be between 0 and 1")
+		}
+		query.threshold = threshold
+	}
+	if value := c.Query("bins"); value != "" {
+		bins, err := strconv.Atoi(value)
+		if err != nil || bins <= 0 || bins > cfg.MaxBins {
+			return nil, fmt.Errorf("bins must be between 1 and %d", cfg.MaxBins)
+		}
+		query.bins = bins
+	}
+
+	return query, nil
+}
+
+func (h *Handler) newComparisonReport(test *models.ABTest, query *comparisonQuery) *monitoring.ComparisonReport {
+	return &monitoring.ComparisonReport{
+		TestID:    test.ID.String(),
+		From:      query.from,
+		To:        query.to,
+		Threshold: query.threshold,
+	}
+}
+
+func (h *Handler) addScoreDistributions(report *monitoring.ComparisonReport, test *models.ABTest, query *comparisonQuery) error {
+	for _, model := range comparisonModels(test) {
+		summary, err := h.repos.PredictionRequest.GetScoreSummary(model.id, query.from, query.to)
+		if err != nil {
+			return err
+		}
+		buckets, err := h.repos.PredictionRequest.GetScoreBuckets(model.id, query.from, query.to, query.bins)
+		if err != nil {
+			return err
+		}
+		report.Distributions = append(report.Distributions,
+			monitoring.NewScoreDistribution(model.id, model.role, *summary, query.bins, buckets))
+	}
+	return nil
+}
+
+func (h *Handler) addConversion(report *monitoring.ComparisonReport, test *models.ABTest, query *comparisonQuery) error {
+	for _, model := range comparisonModels(test) {
+		counts, err := h.repos.PredictionRequest.GetConversionCounts(model.id, query.from, query.to, query.threshold)
+		if err != nil {
+			return err
+		}
+		report.Conversion = append(report.Conversion, monitoring.NewConversionStats(model.id, model.role, *counts))
+	}
+	return nil
+}
+
+func (h *Handler) addAgreement(report *monitoring.ComparisonReport, test *models.ABTest, query *comparisonQuery) error {
+	counts, err := h.repos.PredictionRequest.GetAgreementCounts(
+		test.ChampionModelID.String(), test.ChallengerModelID.String(), query.from, query.to, query.threshold)
+	if err != nil {
+		return err
+	}
+	report.Agreement = monitoring.NewAgreementMatrix(*counts, query.threshold)
+	return nil
+}
+
+func (h *Handler) respondComparisonError(c *gin.Context, test *models.ABTest, err error, message string) {
+	h.logger.Error(message, zap.String("test_id", test.ID.String()), zap.Error(err))
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
+
+type comparisonModel struct {
+	id   string
+	role string
+}
+
+// comparisonModels lists a test's champion and challenger in that order
+func comparisonModels(test *models.ABTest) []comparisonModel {
+	return []comparisonModel{
+		{id: test.ChampionModelID.String(), role: monitoring.RoleChampion},
+		{id: test.ChallengerModelID.String(), role: monitoring.RoleChallenger},
+	}
+}
@@ -85,6 +85,16 @@ func SetupRouter(
 			monitoring.GET("/alerts", handler.GetSystemAlerts)
 			monitoring.GET("/health", handler.GetSystemHealth)
 		}
+
+		// Champion/challenger comparison routes
+		abTests := v1.Group("/ab-tests")
+		{
+			abTests.GET("", handler.GetABTests)
+			abTests.GET("/:id/comparison", handler.GetComparison)
+			abTests.GET("/:id/score-distributions", handler.GetScoreDistributions)
+			abTests.GET("/:id/conversion", handler.GetAlertConversion)
+			abTests.GET("/:id/agreement", handler.GetAgreementMatrix)
+		}
 	}
 
 	return router
@@ -170,6 +170,16 @@ type ABTestingConfig struct {
 	AutoPromote         bool          `mapstructure:"auto_promote"`
 	PromotionThreshold  float64       `mapstructure:"promotion_threshold"`
 	MetricsCollection   MetricsCollectionConfig `mapstructure:"metrics_collection"`
+	Comparison          ComparisonConfig `mapstructure:"comparison"`
+}
+
+// ComparisonConfig holds champion/challenger comparison settings for the model monitoring pages
+type ComparisonConfig struct {
+	AlertThreshold float64       `mapstructure:"alert_threshold"` // scores at or above this raise an alert
+	DefaultWindow  time.Duration `mapstructure:"default_window"`
+	MaxWindow      time.Duration `mapstructure:"max_window"`
+	DefaultBins    int           `mapstructure:"default_bins"`
+	MaxBins        int           `mapstructure:"max_bins"`
 }
 
 // ModelMonitoringConfig holds model monitoring configuration
@@ -510,6 +520,11 @@ func setDefaults() {
 	viper.SetDefault("ml.ab_testing.test_duration", "7d")
 	viper.SetDefault("ml.ab_testing.auto_promote", false)
 	viper.SetDefault("ml.ab_testing.promotion_threshold", 0.95)
+	viper.SetDefault("ml.ab_testing.comparison.alert_threshold", 0.5)
+	viper.SetDefault("ml.ab_testing.comparison.default_window", "168h")
+	viper.SetDefault("ml.ab_testing.comparison.max_window", "2160h")
+	viper.SetDefault("ml.ab_testing.comparison.default_bins", 10)
+	viper.SetDefault("ml.ab_testing.comparison.max_bins", 100)
 
 	viper.SetDefault("ml.model_monitoring.enable_monitoring", true)
 	viper.SetDefault("ml.model_monitoring.metrics_interval", "1m")
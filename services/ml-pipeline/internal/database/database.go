@@ -399,6 +399,19 @@ func (r *ABTestRepository) GetRunningTests() ([]*models.ABTest, error) {
 	return tests, err
 }
 
+// List retrieves A/B tests, optionally by status, most recent first
+func (r *ABTestRepository) List(status string, limit int) ([]*models.ABTest, error) {
+	var tests []*models.ABTest
+	query := r.db.Preload("ChampionModel").Preload("ChallengerModel")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").
+		Limit(limit).
+		Find(&tests).Error
+	return tests, err
+}
+
 // Update updates an A/B test
 func (r *ABTestRepository) Update(test *models.ABTest) error {
 	return r.db.Save(test).Error
@@ -661,6 +674,138 @@ func (r *PredictionRequestRepository) GetPerformanceStats(modelID string, since
 	return result, nil
 }
 
+// ScoreSummary summarizes a model's predicted probabilities over a window
+type ScoreSummary struct {
+	Count int64   `json:"count"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+}
+
+// ConversionCounts counts a model's predictions that raised an alert and how
+// the reviewed ones were labelled. A prediction is confirmed when its
+// feedback score is at least 0.5.
+type ConversionCounts struct {
+	Scored             int64 `json:"scored"`
+	Alerted            int64 `json:"alerted"`
+	AlertedReviewed    int64 `json:"alerted_reviewed"`
+	AlertedConfirmed   int64 `json:"alerted_confirmed"`
+	UnalertedReviewed  int64 `json:"unalerted_reviewed"`
+	UnalertedConfirmed int64 `json:"unalerted_confirmed"`
+}
+
+// AgreementCounts cross-tabulates champion and challenger alert decisions
+// over the events both models scored
+type AgreementCounts struct {
+	Paired           int64   `json:"paired"`
+	BothAlerted      int64   `json:"both_alerted"`
+	ChampionOnly     int64   `json:"champion_only"`
+	ChallengerOnly   int64   `json:"challenger_only"`
+	NeitherAlerted   int64   `json:"neither_alerted"`
+	MeanAbsScoreDiff float64 `json:"mean_abs_score_diff"`
+}
+
+// GetScoreBuckets counts a model's predictions in equal-width probability
+// buckets over [0, 1], keyed by bucket number from 1. A probability of
+// exactly 1 falls in the last bucket.
+func (r *PredictionRequestRepository) GetScoreBuckets(modelID string, from, to time.Time, bins int) (map[int]int64, error) {
+	var rows []struct {
+		Bucket int
+		Count  int64
+	}
+	err := r.db.Raw(`
+		SELECT GREATEST(LEAST(width_bucket(probability, 0, 1, @bins), @bins), 1) AS bucket, COUNT(*) AS count
+		FROM prediction_requests
+		WHERE model_id = @model_id AND requested_at >= @from AND requested_at < @to
+		AND probability IS NOT NULL
+		GROUP BY 1
+	`, map[string]interface{}{"bins": bins, "model_id": modelID, "from": from, "to": to}).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		buckets[row.Bucket] = row.Count
+	}
+	return buckets, nil
+}
+
+// GetScoreSummary retrieves the count, mean and percentiles of a model's predicted probabilities
+func (r *PredictionRequestRepository) GetScoreSummary(modelID string, from, to time.Time) (*ScoreSummary, error) {
+	var summary ScoreSummary
+	err := r.db.Raw(`
+		SELECT
+			COUNT(*) AS count,
+			COALESCE(AVG(probability), 0) AS mean,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY probability), 0) AS p50,
+			COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY probability), 0) AS p90,
+			COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY probability), 0) AS p99
+		FROM prediction_requests
+		WHERE model_id = @model_id AND requested_at >= @from AND requested_at < @to
+		AND probability IS NOT NULL
+	`, map[string]interface{}{"model_id": modelID, "from": from, "to": to}).
+		Scan(&summary).Error
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// GetConversionCounts counts a model's alerting predictions at a threshold and their review outcomes
+func (r *PredictionRequestRepository) GetConversionCounts(modelID string, from, to time.Time, threshold float64) (*ConversionCounts, error) {
+	var counts ConversionCounts
+	err := r.db.Raw(`
+		SELECT
+			COUNT(*) AS scored,
+			COUNT(*) FILTER (WHERE probability >= @threshold) AS alerted,
+			COUNT(*) FILTER (WHERE probability >= @threshold AND feedback_score IS NOT NULL) AS alerted_reviewed,
+			COUNT(*) FILTER (WHERE probability >= @threshold AND feedback_score >= 0.5) AS alerted_confirmed,
+			COUNT(*) FILTER (WHERE probability < @threshold AND feedback_score IS NOT NULL) AS unalerted_reviewed,
+			COUNT(*) FILTER (WHERE probability < @threshold AND feedback_score >= 0.5) AS unalerted_confirmed
+		FROM prediction_requests
+		WHERE model_id = @model_id AND requested_at >= @from AND requested_at < @to
+		AND probability IS NOT NULL
+	`, map[string]interface{}{"threshold": threshold, "model_id": modelID, "from": from, "to": to}).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
+
+// GetAgreementCounts pairs champion and challenger predictions on their
+// correlation ID and cross-tabulates their alert decisions at a threshold
+func (r *PredictionRequestRepository) GetAgreementCounts(championID, challengerID string, from, to time.Time, threshold float64) (*AgreementCounts, error) {
+	var counts AgreementCounts
+	err := r.db.Raw(`
+		SELECT
+			COUNT(*) AS paired,
+			COUNT(*) FILTER (WHERE c.probability >= @threshold AND x.probability >= @threshold) AS both_alerted,
+			COUNT(*) FILTER (WHERE c.probability >= @threshold AND x.probability < @threshold) AS champion_only,
+			COUNT(*) FILTER (WHERE c.probability < @threshold AND x.probability >= @threshold) AS challenger_only,
+			COUNT(*) FILTER (WHERE c.probability < @threshold AND x.probability < @threshold) AS neither_alerted,
+			COALESCE(AVG(ABS(c.probability - x.probability)), 0) AS mean_abs_score_diff
+		FROM prediction_requests c
+		JOIN prediction_requests x ON x.correlation_id = c.correlation_id AND x.model_id = @challenger_id
+		WHERE c.model_id = @champion_id AND c.correlation_id <> ''
+		AND c.requested_at >= @from AND c.requested_at < @to
+		AND c.probability IS NOT NULL AND x.probability IS NOT NULL
+	`, map[string]interface{}{
+		"threshold":     threshold,
+		"champion_id":   championID,
+		"challenger_id": challengerID,
+		"from":          from,
+		"to":            to,
+	}).Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
+
 // Repositories aggregates all repository instances
 type Repositories struct {
 	Model             *ModelRepository
@@ -463,6 +463,10 @@ func (e *InferenceEngine) storePredictionRequest(request *PredictionRequest, res
 		if metadataJSON, err := json.Marshal(request.RequestMetadata); err == nil {
 			predictionRequest.RequestMetadata = models.JSON(metadataJSON)
 		}
+		// Champion and challenger predictions for the same event are paired on it
+		if correlationID, ok := request.RequestMetadata["correlation_id"].(string); ok {
+			predictionRequest.CorrelationID = correlationID
+		}
 	}
 
 	// Set confidence and probability
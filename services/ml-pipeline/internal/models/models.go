@@ -528,6 +528,7 @@ type PredictionRequest struct {
 	ID              uuid.UUID       `gorm:"type:uuid;primary_key" json:"id"`
 	ModelID         uuid.UUID       `gorm:"type:uuid;not null;index" json:"model_id"`
 	RequestID       string          `gorm:"not null;unique;index" json:"request_id"`
+	CorrelationID   string          `gorm:"index" json:"correlation_id,omitempty"` // the event scored, shared by a champion and its challenger
 	
 	// Request data
 	Features        JSON            `gorm:"type:jsonb;not null" json:"features"`
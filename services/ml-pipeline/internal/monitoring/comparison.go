@@ -0,0 +1,166 @@
+package monitoring
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"../../internal/database"
+)
+
+// Comparison roles
+const (
+	RoleChampion   = "champion"
+	RoleChallenger = "challenger"
+)
+
+// ScoreBin is one equal-width bucket of a score histogram
+type ScoreBin struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+	Count int64   `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// ScoreDistribution is the histogram and summary of one model's scores over a window
+type ScoreDistribution struct {
+	ModelID string `json:"model_id"`
+	Role    string `json:"role"`
+	database.ScoreSummary
+	Bins []ScoreBin `json:"bins"`
+}
+
+// ConversionStats is how often a model's scores raise alerts and how often
+// reviewed alerts are confirmed
+type ConversionStats struct {
+	ModelID string `json:"model_id"`
+	Role    string `json:"role"`
+	database.ConversionCounts
+	AlertRate float64 `json:"alert_rate"`
+	// ConversionRate is the confirmed share of reviewed alerts, unset when no alert was reviewed
+	ConversionRate *float64 `json:"conversion_rate,omitempty"`
+	// MissRate is the confirmed share of reviewed predictions below the
+	// threshold, unset when none were reviewed
+	MissRate *float64 `json:"miss_rate,omitempty"`
+}
+
+// AgreementMatrix compares champion and challenger alert decisions on the
+// events both scored. Matrix rows are the champion alerting and not, columns
+// the challenger.
+type AgreementMatrix struct {
+	Threshold float64 `json:"threshold"`
+	database.AgreementCounts
+	Matrix        [2][2]int64 `json:"matrix"`
+	AgreementRate float64     `json:"agreement_rate"`
+	// Kappa is Cohen's kappa, agreement corrected for chance; unset when
+	// chance agreement is total
+	Kappa *float64 `json:"kappa,omitempty"`
+}
+
+// ComparisonReport is the champion/challenger data behind the model monitoring pages
+type ComparisonReport struct {
+	TestID        string               `json:"test_id"`
+	From          time.Time            `json:"from"`
+	To            time.Time            `json:"to"`
+	Threshold     float64              `json:"threshold"`
+	Distributions []*ScoreDistribution `json:"distributions,omitempty"`
+	Conversion    []*ConversionStats   `json:"conversion,omitempty"`
+	Agreement     *AgreementMatrix     `json:"agreement,omitempty"`
+}
+
+// NewScoreDistribution spreads bucket counts, keyed by bucket number from 1,
+// over equal-width bins in [0, 1]
+func NewScoreDistribution(modelID, role string, summary database.ScoreSummary, bins int, buckets map[int]int64) *ScoreDistribution {
+	var total int64
+	for _, count := range buckets {
+		total += count
+	}
+
+	distribution := &ScoreDistribution{
+		ModelID:      modelID,
+		Role:         role,
+		ScoreSummary: summary,
+		Bins:         make([]ScoreBin, bins),
+	}
+	width := 1.0 / float64(bins)
+	for i := range distribution.Bins {
+		bin := ScoreBin{
+			Lower: float64(i) * width,
+			Upper: float64(i+1) * width,
+			Count: buckets[i+1],
+		}
+		if total > 0 {
+			bin.Share = float64(bin.Count) / float64(total)
+		}
+		distribution.Bins[i] = bin
+	}
+	return distribution
+}
+
+// NewConversionStats derives alert and conversion rates from a model's counts
+func NewConversionStats(modelID, role string, counts database.ConversionCounts) *ConversionStats {
+	stats := &ConversionStats{
+		ModelID:          modelID,
+		Role:             role,
+		ConversionCounts: counts,
+	}
+	if counts.Scored > 0 {
+		stats.AlertRate = float64(counts.Alerted) / float64(counts.Scored)
+	}
+	if counts.AlertedReviewed > 0 {
+		rate := float64(counts.AlertedConfirmed) / float64(counts.AlertedReviewed)
+		stats.ConversionRate = &rate
+	}
+	if counts.UnalertedReviewed > 0 {
+		rate := float64(counts.UnalertedConfirmed) / float64(counts.UnalertedReviewed)
+		stats.MissRate = &rate
+	}
+	return stats
+}
+
+// NewAgreementMatrix derives the agreement rate and Cohen's kappa from paired counts
+func NewAgreementMatrix(counts database.AgreementCounts, threshold float64) *AgreementMatrix {
+	matrix := &AgreementMatrix{
+		Threshold:       threshold,
+		AgreementCounts: counts,
+		Matrix: [2][2]int64{
+			{counts.BothAlerted, counts.ChampionOnly},
+			{counts.ChallengerOnly, counts.NeitherAlerted},
+		},
+	}
+	if counts.Paired == 0 {
+		return matrix
+	}
+
+	n := float64(counts.Paired)
+	observed := float64(counts.BothAlerted+counts.NeitherAlerted) / n
+	matrix.AgreementRate = observed
+
+	championYes := float64(counts.BothAlerted+counts.ChampionOnly) / n
+	challengerYes := float64(counts.BothAlerted+counts.ChallengerOnly) / n
+	expected := championYes*challengerYes + (1-championYes)*(1-challengerYes)
+	if expected < 1 {
+		kappa := (observed - expected) / (1 - expected)
+		matrix.Kappa = &kappa
+	}
+	return matrix
+}
+
+// ParseWindow parses a comparison window such as "24h", "7d" or "90m". Days
+// are accepted on top of the units time.ParseDuration knows.
+func ParseWindow(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid window %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	window, err := time.ParseDuration(value)
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("invalid window %q", value)
+	}
+	return window, nil
+}
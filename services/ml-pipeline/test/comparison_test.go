@@ -0,0 +1,86 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"../internal/database"
+	"../internal/monitoring"
+)
+
+func TestAgreementMatrixKappa(t *testing.T) {
+	matrix := monitoring.NewAgreementMatrix(database.AgreementCounts{
+		Paired:         100,
+		BothAlerted:    20,
+		ChampionOnly:   5,
+		ChallengerOnly: 10,
+		NeitherAlerted: 65,
+	}, 0.5)
+
+	assert.Equal(t, [2][2]int64{{20, 5}, {10, 65}}, matrix.Matrix)
+	assert.InDelta(t, 0.85, matrix.AgreementRate, 1e-9)
+	require.NotNil(t, matrix.Kappa)
+	// expected agreement is 0.25*0.30 + 0.75*0.70 = 0.60
+	assert.InDelta(t, 0.625, *matrix.Kappa, 1e-9)
+}
+
+func TestAgreementMatrixWithoutChanceCorrection(t *testing.T) {
+	empty := monitoring.NewAgreementMatrix(database.AgreementCounts{}, 0.5)
+	assert.Zero(t, empty.AgreementRate)
+	assert.Nil(t, empty.Kappa)
+
+	// both models never alerting agree by chance alone
+	silent := monitoring.NewAgreementMatrix(database.AgreementCounts{Paired: 40, NeitherAlerted: 40}, 0.5)
+	assert.Equal(t, 1.0, silent.AgreementRate)
+	assert.Nil(t, silent.Kappa)
+}
+
+func TestConversionStatsRates(t *testing.T) {
+	stats := monitoring.NewConversionStats("model-1", monitoring.RoleChampion, database.ConversionCounts{
+		Scored:           200,
+		Alerted:          50,
+		AlertedReviewed:  40,
+		AlertedConfirmed: 10,
+	})
+
+	assert.InDelta(t, 0.25, stats.AlertRate, 1e-9)
+	require.NotNil(t, stats.ConversionRate)
+	assert.InDelta(t, 0.25, *stats.ConversionRate, 1e-9)
+	assert.Nil(t, stats.MissRate)
+
+	empty := monitoring.NewConversionStats("model-2", monitoring.RoleChallenger, database.ConversionCounts{})
+	assert.Zero(t, empty.AlertRate)
+	assert.Nil(t, empty.ConversionRate)
+}
+
+func TestScoreDistributionBins(t *testing.T) {
+	distribution := monitoring.NewScoreDistribution("model-1", monitoring.RoleChallenger,
+		database.ScoreSummary{Count: 8}, 4, map[int]int64{1: 2, 4: 6})
+
+	require.Len(t, distribution.Bins, 4)
+	assert.Equal(t, 0.0, distribution.Bins[0].Lower)
+	assert.Equal(t, 0.25, distribution.Bins[0].Upper)
+	assert.Equal(t, int64(2), distribution.Bins[0].Count)
+	assert.InDelta(t, 0.25, distribution.Bins[0].Share, 1e-9)
+	assert.Zero(t, distribution.Bins[1].Count)
+	assert.Equal(t, 1.0, distribution.Bins[3].Upper)
+	assert.InDelta(t, 0.75, distribution.Bins[3].Share, 1e-9)
+}
+
+func TestParseComparisonWindow(t *testing.T) {
+	window, err := monitoring.ParseWindow("7d")
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, window)
+
+	window, err = monitoring.ParseWindow("90m")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, window)
+
+	for _, value := range []string{"", "0d", "-2h", "d", "week"} {
+		_, err := monitoring.ParseWindow(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	apiKeyPrefix = "ask_"
+	// Characters of the raw key kept in clear so a key can be recognised in
+	// listings and logs
+	apiKeyDisplayLength = 12
+
+	defaultAPIKeyTTL = 90 * 24 * time.Hour
+	maxAPIKeyTTL     = 365 * 24 * time.Hour
+
+	// LastUsedAt is only written when it is older than this, so busy keys do
+	// not cost a write per request
+	apiKeyUsageResolution = time.Minute
+)
+
+// ErrAPIKeyInvalid is returned for unknown, expired or revoked API keys and
+// keys of deactivated service accounts
+var ErrAPIKeyInvalid = errors.New("API key is invalid, expired or revoked")
+
+// ServiceAccount is a non-human identity for automated jobs such as ETL
+// pipelines. It cannot log in; it authenticates with its API keys.
+type ServiceAccount struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description"`
+	Owner       string    `json:"owner"` // team or person responsible for the account
+	IsActive    bool      `json:"is_active" gorm:"default:true"`
+	CreatedBy   uint      `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// APIKey is a credential bound to a service account. Its scopes are the only
+// permissions it grants. Only a hash of the key is stored; the key itself is
+// shown once, when it is created.
+type APIKey struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	ServiceAccountID uint           `json:"service_account_id" gorm:"not null;index"`
+	ServiceAccount   ServiceAccount `json:"-"`
+	Name             string         `json:"name" gorm:"not null"`
+	DisplayPrefix    string         `json:"display_prefix"`
+	KeyHash          string         `json:"-" gorm:"uniqueIndex;not null"`
+	Scopes           []Permission   `json:"scopes" gorm:"many2many:api_key_scopes;"`
+	ExpiresAt        time.Time      `json:"expires_at"`
+	LastUsedAt       *time.Time     `json:"last_used_at"`
+	CreatedBy        uint           `json:"created_by"`
+	CreatedAt        time.Time      `json:"created_at"`
+	RevokedAt        *time.Time     `json:"revoked_at" gorm:"index"`
+}
+
+type ServiceAccountRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Owner       string `json:"owner" binding:"required"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string `json:"name" binding:"required"`
+	ScopeIDs      []uint `json:"scope_ids" binding:"required"` // permission IDs the key grants
+	ExpiresInDays int    `json:"expires_in_days"`              // defaults to 90, at most 365
+}
+
+type CreateAPIKeyResponse struct {
+	Key    string `json:"key"` // only returned here
+	APIKey APIKey `json:"api_key"`
+}
+
+// CreateServiceAccount creates a service account
+func (s *UserManagementService) CreateServiceAccount(c *gin.Context) {
+	var req ServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account := ServiceAccount{
+		Name:        req.Name,
+		Description: req.Description,
+		Owner:       req.Owner,
+		IsActive:    true,
+		CreatedBy:   s.GetUserIDFromContext(c),
+	}
+	if err := s.db.WithContext(c.Request.Context()).Create(&account).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Failed to create service account, name may already exist"})
+		return
+	}
+
+	s.LogAuditEvent(s.GetUserIDFromContext(c), "create_service_account", "user_management",
+		fmt.Sprintf("Created service account: %s", account.Name), c.ClientIP())
+
+	c.JSON(http.StatusCreated, account)
+}
+
+// ListServiceAccounts returns every service account
+func (s *UserManagementService) ListServiceAccounts(c *gin.Context) {
+	var accounts []ServiceAccount
+	if err := s.db.WithContext(c.Request.Context()).Order("id ASC").Find(&accounts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"service_accounts": accounts})
+}
+
+// DeactivateServiceAccount disables a service account and revokes its keys
+func (s *UserManagementService) DeactivateServiceAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var account ServiceAccount
+	if err := s.db.WithContext(ctx).First(&account, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+		return
+	}
+
+	var revoked int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&account).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&APIKey{}).
+			Where("service_account_id = ? AND revoked_at IS NULL", account.ID).
+			Update("revoked_at", time.Now())
+		revoked = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate service account"})
+		return
+	}
+
+	s.LogAuditEvent(s.GetUserIDFromContext(c), "deactivate_service_account", "user_management",
+		fmt.Sprintf("Deactivated service account %s and revoked %d API keys", account.Name, revoked), c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service account deactivated", "revoked_keys": revoked})
+}
+
+// CreateAPIKey issues a scoped, expiring API key for a service account
+func (s *UserManagementService) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.ScopeIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one scope is required"})
+		return
+	}
+
+	ttl := defaultAPIKeyTTL
+	if req.ExpiresInDays != 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl <= 0 || ttl > maxAPIKeyTTL {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("expires_in_days must be 1 to %d", int(maxAPIKeyTTL.Hours()/24))})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var account ServiceAccount
+	if err := s.db.WithContext(ctx).First(&account, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+		return
+	}
+	if !account.IsActive {
+		c.JSON(http.StatusConflict, gin.H{"error": "Service account is deactivated"})
+		return
+	}
+
+	scopes, err := s.findPermissions(req.ScopeIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+	raw := apiKeyPrefix + secret
+
+	key := APIKey{
+		ServiceAccountID: account.ID,
+		Name:             req.Name,
+		DisplayPrefix:    raw[:apiKeyDisplayLength],
+		KeyHash:          hashAPIKey(raw),
+		Scopes:           scopes,
+		ExpiresAt:        time.Now().Add(ttl),
+		CreatedBy:        s.GetUserIDFromContext(c),
+	}
+	if err := s.db.WithContext(ctx).Create(&key).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store API key"})
+		return
+	}
+
+	s.LogAuditEvent(s.GetUserIDFromContext(c), "create_api_key", "user_management",
+		fmt.Sprintf("Created API key %s (%s) for service account %s, expires %s",
+			key.Name, key.DisplayPrefix, account.Name, key.ExpiresAt.Format(time.RFC3339)), c.ClientIP())
+
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{Key: raw, APIKey: key})
+}
+
+// ListAPIKeys returns a service account's API keys, including revoked and
+// expired ones
+func (s *UserManagementService) ListAPIKeys(c *gin.Context) {
+	var keys []APIKey
+	if err := s.db.WithContext(c.Request.Context()).Preload("Scopes").
+		Where("service_account_id = ?", c.Param("id")).
+		Order("id ASC").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// RevokeAPIKey revokes one of a service account's API keys
+func (s *UserManagementService) RevokeAPIKey(c *gin.Context) {
+	keyID, err := strconv.ParseUint(c.Param("keyId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	result := s.db.WithContext(c.Request.Context()).Model(&APIKey{}).
+		Where("id = ? AND service_account_id = ? AND revoked_at IS NULL", keyID, c.Param("id")).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Active API key not found"})
+		return
+	}
+
+	s.LogAuditEvent(s.GetUserIDFromContext(c), "revoke_api_key", "user_management",
+		fmt.Sprintf("Revoked API key %d of service account %s", keyID, c.Param("id")), c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
+
+// ValidateAPIKey returns the active key matching raw with its scopes and
+// service account, or ErrAPIKeyInvalid
+func (s *UserManagementService) ValidateAPIKey(ctx context.Context, raw string) (*APIKey, error) {
+	var key APIKey
+	err := s.db.WithContext(ctx).Preload("Scopes").Preload("ServiceAccount").
+		Where("key_hash = ? AND revoked_at IS NULL AND expires_at > ?", hashAPIKey(raw), time.Now()).
+		First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAPIKeyInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API key: %w", err)
+	}
+	if !key.ServiceAccount.IsActive {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyUsageResolution {
+		s.db.WithContext(ctx).Model(&APIKey{}).Where("id = ?", key.ID).Update("last_used_at", now)
+		key.LastUsedAt = &now
+	}
+
+	return &key, nil
+}
+
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys set by AuthMiddleware
+const (
+	contextUserKey        = "auth_user"
+	contextUserIDKey      = "auth_user_id"
+	contextSessionIDKey   = "auth_session_id"
+	contextPermissionsKey = "auth_permissions"
+)
+
+// roleAdmin passes every role and permission check
+const roleAdmin = "admin"
+
+// AuthMiddleware requires a valid bearer token backed by an active session,
+// then loads the user and their permissions into the request context
+func (s *UserManagementService) AuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing bearer token"})
+			return
+		}
+
+		session, err := s.ValidateToken(c.Request.Context(), token)
+		if err != nil {
+			if errors.Is(err, ErrSessionInvalid) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to validate session"})
+			return
+		}
+
+		// Role and permissions are read fresh rather than trusted from the
+		// token, so changes apply to sessions that are already open
+		var user User
+		if err := s.db.WithContext(c.Request.Context()).Preload("Permissions").First(&user, session.UserID).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrSessionInvalid.Error()})
+			return
+		}
+		if !user.IsActive {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "User account is disabled"})
+			return
+		}
+
+		// Users whose role requires MFA can only reach the MFA routes (and the
+		// password change they may also owe) until they enroll
+		if !strings.HasPrefix(c.FullPath(), mfaRoutePrefix) && c.FullPath() != passwordRoutePath {
+			pending, err := s.mfa.EnrollmentPending(c.Request.Context(), &user)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to check MFA enrollment"})
+				return
+			}
+			if pending {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error":                   "MFA enrollment required",
+					"mfa_enrollment_required": true,
+				})
+				return
+			}
+		}
+
+		// Users with an expired or admin-set password can only change it
+		if c.FullPath() != passwordRoutePath && !strings.HasPrefix(c.FullPath(), mfaRoutePrefix) &&
+			s.passwords.ChangeRequired(&user, time.Now()) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":                    "Password change required",
+				"password_change_required": true,
+			})
+			return
+		}
+
+		permissions := make(map[string]bool, len(user.Permissions))
+		for _, permission := range user.Permissions {
+			permissions[permission.Name] = true
+		}
+
+		c.Set(contextUserKey, &user)
+		c.Set(contextUserIDKey, user.ID)
+		c.Set(contextSessionIDKey, session.ID)
+		c.Set(contextPermissionsKey, permissions)
+		c.Next()
+	}
+}
+
+// RequireRole allows only users with one of the given roles. Admins are
+// always allowed. It must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentUser(c)
+		if user == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			return
+		}
+
+		if user.Role == roleAdmin {
+			c.Next()
+			return
+		}
+		for _, role := range roles {
+			if user.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":          "Insufficient role",
+			"required_roles": roles,
+		})
+	}
+}
+
+// RequirePermission allows only users holding every given permission. Admins
+// are always allowed. It must run after AuthMiddleware.
+func RequirePermission(names ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentUser(c)
+		if user == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			return
+		}
+
+		if user.Role != roleAdmin {
+			permissions, _ := c.Get(contextPermissionsKey)
+			granted, _ := permissions.(map[string]bool)
+			for _, name := range names {
+				if !granted[name] {
+					c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+						"error":      "Missing permission",
+						"permission": name,
+					})
+					return
+				}
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// CurrentUser returns the authenticated user, or nil outside AuthMiddleware
+func CurrentUser(c *gin.Context) *User {
+	value, exists := c.Get(contextUserKey)
+	if !exists {
+		return nil
+	}
+	user, _ := value.(*User)
+	return user
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+
+	pb "aegisshield/shared/proto/user-management"
+	"aegisshield/shared/rbac"
+)
+
+// UserServiceServer exposes identity lookups, session validation and
+// permission checks over gRPC so other services can authenticate callers
+// without an HTTP round trip. It applies the same session, MFA and password
+// rules as AuthMiddleware.
+type UserServiceServer struct {
+	pb.UnimplementedUserServiceServer
+	service   *UserManagementService
+	evaluator *rbac.Evaluator
+}
+
+// NewUserServiceServer creates the gRPC user service. Permissions are
+// checked against the shared role policy, plus each user's direct grants.
+func NewUserServiceServer(service *UserManagementService, evaluator *rbac.Evaluator) *UserServiceServer {
+	return &UserServiceServer{
+		service:   service,
+		evaluator: evaluator,
+	}
+}
+
+// Register registers the user service on a gRPC server
+func (s *UserServiceServer) Register(grpcServer *grpc.Server) {
+	pb.RegisterUserServiceServer(grpcServer, s)
+}
+
+// GetUser looks a user up by ID or username
+func (s *UserServiceServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	query := s.service.db.WithContext(ctx).Preload("Permissions")
+	switch {
+	case req.GetId() != 0:
+		query = query.Where("id = ?", req.GetId())
+	case req.GetUsername() != "":
+		query = query.Where("username = ?", req.GetUsername())
+	default:
+		return nil, status.Error(codes.InvalidArgument, "id or username is required")
+	}
+
+	var user User
+	if err := query.First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to load user")
+	}
+
+	return &pb.GetUserResponse{User: userToProto(&user)}, nil
+}
+
+// ValidateToken checks that a bearer token belongs to an active session of
+// an active user. Rejected tokens are reported in the response rather than
+// as an error; errors mean the check itself could not be made.
+func (s *UserServiceServer) ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	session, user, err := s.authenticate(ctx, req.GetToken())
+	if err != nil {
+		if errors.Is(err, ErrSessionInvalid) {
+			return &pb.ValidateTokenResponse{Reason: err.Error()}, nil
+		}
+		return nil, err
+	}
+	if !user.IsActive {
+		return &pb.ValidateTokenResponse{Reason: "user account is disabled"}, nil
+	}
+
+	mfaPending, err := s.service.mfa.EnrollmentPending(ctx, user)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, "failed to check MFA enrollment")
+	}
+
+	return &pb.ValidateTokenResponse{
+		Valid:                  true,
+		User:                   userToProto(user),
+		SessionId:              uint64(session.ID),
+		ExpiresAt:              timestamppb.New(session.ExpiresAt),
+		MfaEnrollmentRequired:  mfaPending,
+		PasswordChangeRequired: s.service.passwords.ChangeRequired(user, time.Now()),
+	}, nil
+}
+
+// CheckPermission decides whether the token's user, or the given user, may
+// perform the action. Disabled users and sessions still owing MFA enrollment
+// or a password change are denied.
+func (s *UserServiceServer) CheckPermission(ctx context.Context, req *pb.CheckPermissionRequest) (*pb.CheckPermissionResponse, error) {
+	if req.GetResource() == "" || req.GetAction() == "" {
+		return nil, status.Error(codes.InvalidArgument, "resource and action are required")
+	}
+
+	var user *User
+	switch {
+	case req.GetToken() != "":
+		_, authenticated, err := s.authenticate(ctx, req.GetToken())
+		if err != nil {
+			if errors.Is(err, ErrSessionInvalid) {
+				return nil, status.Error(codes.Unauthenticated, err.Error())
+			}
+			return nil, err
+		}
+		user = authenticated
+
+		mfaPending, err := s.service.mfa.EnrollmentPending(ctx, user)
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, "failed to check MFA enrollment")
+		}
+		if mfaPending {
+			return denied(user, "MFA enrollment required"), nil
+		}
+		if s.service.passwords.ChangeRequired(user, time.Now()) {
+			return denied(user, "password change required"), nil
+		}
+	case req.GetUserId() != 0:
+		var loaded User
+		if err := s.service.db.WithContext(ctx).Preload("Permissions").First(&loaded, req.GetUserId()).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, status.Error(codes.NotFound, "user not found")
+			}
+			return nil, status.Error(codes.Internal, "failed to load user")
+		}
+		user = &loaded
+	default:
+		return nil, status.Error(codes.InvalidArgument, "token or user_id is required")
+	}
+
+	if !user.IsActive {
+		return denied(user, "user account is disabled"), nil
+	}
+
+	decision := s.evaluator.Check(userSubject(user), req.GetResource(), req.GetAction())
+	return &pb.CheckPermissionResponse{
+		Allowed:   decision.Allowed,
+		Reason:    decision.Reason,
+		MatchedBy: decision.MatchedBy,
+		UserId:    uint64(user.ID),
+	}, nil
+}
+
+// ListUsersByRole returns a page of the users holding role
+func (s *UserServiceServer) ListUsersByRole(ctx context.Context, req *pb.ListUsersByRoleRequest) (*pb.ListUsersByRoleResponse, error) {
+	if req.GetRole() == "" {
+		return nil, status.Error(codes.InvalidArgument, "role is required")
+	}
+
+	page, limit := int(req.GetPage()), int(req.GetLimit())
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = userPageOptions.DefaultLimit
+	}
+	if page < 1 || limit < 1 || limit > userPageOptions.MaxLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "page must be positive and limit 1 to %d", userPageOptions.MaxLimit)
+	}
+
+	query := s.service.db.WithContext(ctx).Model(&User{}).Where("role = ?", req.GetRole())
+	if !req.GetIncludeInactive() {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, status.Error(codes.Internal, "failed to count users")
+	}
+
+	pagination := Pagination{Page: page, Limit: limit, order: []string{"id ASC"}}
+	var users []User
+	if err := pagination.Apply(query.Preload("Permissions")).Find(&users).Error; err != nil {
+		return nil, status.Error(codes.Internal, "failed to fetch users")
+	}
+
+	resp := &pb.ListUsersByRoleResponse{
+		Total:      total,
+		TotalPages: int32((total + int64(limit) - 1) / int64(limit)),
+	}
+	for i := range users {
+		resp.Users = append(resp.Users, userToProto(&users[i]))
+	}
+	return resp, nil
+}
+
+// ValidateAPIKey checks a service account's API key. Rejected keys are
+// reported in the response rather than as an error.
+func (s *UserServiceServer) ValidateAPIKey(ctx context.Context, req *pb.ValidateAPIKeyRequest) (*pb.ValidateAPIKeyResponse, error) {
+	if req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "key is required")
+	}
+
+	key, err := s.service.ValidateAPIKey(ctx, req.GetKey())
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyInvalid) {
+			return &pb.ValidateAPIKeyResponse{Reason: err.Error()}, nil
+		}
+		return nil, status.Error(codes.Unavailable, "failed to validate API key")
+	}
+
+	return &pb.ValidateAPIKeyResponse{
+		Valid: true,
+		ServiceAccount: &pb.ServiceAccount{
+			Id:    uint64(key.ServiceAccount.ID),
+			Name:  key.ServiceAccount.Name,
+			Owner: key.ServiceAccount.Owner,
+		},
+		KeyId:     uint64(key.ID),
+		Scopes:    permissionClaims(key.Scopes),
+		ExpiresAt: timestamppb.New(key.ExpiresAt),
+	}, nil
+}
+
+// authenticate resolves a token to its session and user. It returns
+// ErrSessionInvalid for rejected tokens and a gRPC status error when the
+// session or user could not be loaded.
+func (s *UserServiceServer) authenticate(ctx context.Context, token string) (*cachedSession, *User, error) {
+	session, err := s.service.ValidateToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, ErrSessionInvalid) {
+			return nil, nil, err
+		}
+		return nil, nil, status.Error(codes.Unavailable, "failed to validate session")
+	}
+
+	// Role and permissions are read fresh rather than trusted from the token
+	var user User
+	if err := s.service.db.WithContext(ctx).Preload("Permissions").First(&user, session.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrSessionInvalid
+		}
+		return nil, nil, status.Error(codes.Unavailable, "failed to load user")
+	}
+	return session, &user, nil
+}
+
+// userSubject builds the shared RBAC subject for a user
+func userSubject(user *User) *rbac.Subject {
+	return &rbac.Subject{
+		ID:          strconv.FormatUint(uint64(user.ID), 10),
+		Roles:       []string{user.Role},
+		Permissions: permissionClaims(user.Permissions),
+	}
+}
+
+func denied(user *User, reason string) *pb.CheckPermissionResponse {
+	return &pb.CheckPermissionResponse{Reason: reason, UserId: uint64(user.ID)}
+}
+
+func userToProto(user *User) *pb.User {
+	msg := &pb.User{
+		Id:           uint64(user.ID),
+		Username:     user.Username,
+		Email:        user.Email,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		Role:         user.Role,
+		Department:   user.Department,
+		IsActive:     user.IsActive,
+		AuthProvider: user.AuthProvider,
+		Permissions:  permissionClaims(user.Permissions),
+		CreatedAt:    timestamppb.New(user.CreatedAt),
+	}
+	if user.LastLogin != nil {
+		msg.LastLogin = timestamppb.New(*user.LastLogin)
+	}
+	return msg
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Login throttle scopes: failures are counted per submitted username and per
+// source IP, so both targeted guessing and password spraying are slowed
+const (
+	throttleScopeUsername = "username"
+	throttleScopeIP       = "ip"
+)
+
+// LoginThrottle tracks recent failed logins for one username or source IP.
+// After BackoffAfter failures each further attempt must wait an exponentially
+// growing delay; reaching the failure limit locks the key out, and every
+// consecutive lockout doubles the lockout duration.
+type LoginThrottle struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Scope         string     `json:"scope" gorm:"not null;uniqueIndex:idx_login_throttle_key"`
+	Key           string     `json:"key" gorm:"not null;uniqueIndex:idx_login_throttle_key"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+	LockedUntil   *time.Time `json:"locked_until" gorm:"index"`
+	Lockouts      int        `json:"lockouts"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// LockoutPolicy configures failed-login backoff and lockout
+type LockoutPolicy struct {
+	MaxUsernameFailures int
+	MaxIPFailures       int
+	FailureWindow       time.Duration
+	BackoffAfter        int
+	BackoffBase         time.Duration
+	BackoffMax          time.Duration
+	LockoutDuration     time.Duration
+	MaxLockoutDuration  time.Duration
+}
+
+// defaultLockoutPolicy locks a username after 5 failures and an IP after 20
+// within 15 minutes, for 15 minutes doubling up to a day
+var defaultLockoutPolicy = LockoutPolicy{
+	MaxUsernameFailures: 5,
+	MaxIPFailures:       20,
+	FailureWindow:       15 * time.Minute,
+	BackoffAfter:        2,
+	BackoffBase:         time.Second,
+	BackoffMax:          30 * time.Second,
+	LockoutDuration:     15 * time.Minute,
+	MaxLockoutDuration:  24 * time.Hour,
+}
+
+// lockoutPolicyFromEnv reads LOGIN_MAX_FAILURES, LOGIN_MAX_IP_FAILURES,
+// LOGIN_FAILURE_WINDOW, LOGIN_BACKOFF_AFTER, LOGIN_BACKOFF_BASE,
+// LOGIN_BACKOFF_MAX, LOGIN_LOCKOUT_DURATION and LOGIN_MAX_LOCKOUT_DURATION,
+// falling back to the defaults for unset or invalid values
+func lockoutPolicyFromEnv() LockoutPolicy {
+	policy := defaultLockoutPolicy
+	envInt("LOGIN_MAX_FAILURES", &policy.MaxUsernameFailures)
+	envInt("LOGIN_MAX_IP_FAILURES", &policy.MaxIPFailures)
+	envInt("LOGIN_BACKOFF_AFTER", &policy.BackoffAfter)
+	envDuration("LOGIN_FAILURE_WINDOW", &policy.FailureWindow)
+	envDuration("LOGIN_BACKOFF_BASE", &policy.BackoffBase)
+	envDuration("LOGIN_BACKOFF_MAX", &policy.BackoffMax)
+	envDuration("LOGIN_LOCKOUT_DURATION", &policy.LockoutDuration)
+	envDuration("LOGIN_MAX_LOCKOUT_DURATION", &policy.MaxLockoutDuration)
+	return policy
+}
+
+func envInt(name string, target *int) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("Invalid %s %q, using %d", name, value, *target)
+		return
+	}
+	*target = parsed
+}
+
+func envDuration(name string, target *time.Duration) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("Invalid %s %q, using %s", name, value, *target)
+		return
+	}
+	*target = parsed
+}
+
+func envBool(name string, target *bool) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %t", name, value, *target)
+		return
+	}
+	*target = parsed
+}
+
+// maxFailures returns the failure limit for a scope
+func (p LockoutPolicy) maxFailures(scope string) int {
+	if scope == throttleScopeIP {
+		return p.MaxIPFailures
+	}
+	return p.MaxUsernameFailures
+}
+
+// recordFailure applies one failed attempt to a throttle and reports whether
+// it started a lockout
+func (p LockoutPolicy) recordFailure(throttle *LoginThrottle, now time.Time) bool {
+	if now.Sub(throttle.LastFailureAt) > p.MaxLockoutDuration {
+		throttle.Lockouts = 0
+	}
+	if now.Sub(throttle.LastFailureAt) > p.FailureWindow {
+		throttle.Failures = 0
+	}
+
+	throttle.Failures++
+	throttle.LastFailureAt = now
+	throttle.NextAttemptAt = nil
+
+	if throttle.Failures >= p.maxFailures(throttle.Scope) {
+		throttle.Lockouts++
+		lockedUntil := now.Add(doubled(p.LockoutDuration, throttle.Lockouts-1, p.MaxLockoutDuration))
+		throttle.LockedUntil = &lockedUntil
+		throttle.Failures = 0
+		return true
+	}
+
+	if throttle.Failures >= p.BackoffAfter {
+		nextAttempt := now.Add(doubled(p.BackoffBase, throttle.Failures-p.BackoffAfter, p.BackoffMax))
+		throttle.NextAttemptAt = &nextAttempt
+	}
+	return false
+}
+
+// doubled returns base doubled times times, capped at max
+func doubled(base time.Duration, times int, max time.Duration) time.Duration {
+	delay := base
+	for i := 0; i < times && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// retryAfter returns how long the throttle blocks further attempts
+func (t *LoginThrottle) retryAfter(now time.Time) time.Duration {
+	var wait time.Duration
+	if t.LockedUntil != nil && now.Before(*t.LockedUntil) {
+		wait = t.LockedUntil.Sub(now)
+	}
+	if t.NextAttemptAt != nil && now.Before(*t.NextAttemptAt) && t.NextAttemptAt.Sub(now) > wait {
+		wait = t.NextAttemptAt.Sub(now)
+	}
+	return wait
+}
+
+// LoginGuard enforces the lockout policy using throttles stored in Postgres,
+// so every replica sees the same failure counts
+type LoginGuard struct {
+	db     *gorm.DB
+	policy LockoutPolicy
+}
+
+// NewLoginGuard creates a login guard
+func NewLoginGuard(db *gorm.DB, policy LockoutPolicy) *LoginGuard {
+	return &LoginGuard{db: db, policy: policy}
+}
+
+// throttleKey identifies one throttle
+type throttleKey struct {
+	scope string
+	key   string
+}
+
+// throttleKeys returns the throttles a login attempt is counted against, in
+// the fixed order rows are locked in
+func throttleKeys(username, ip string) []throttleKey {
+	return []throttleKey{
+		{scope: throttleScopeUsername, key: normalizeUsername(username)},
+		{scope: throttleScopeIP, key: ip},
+	}
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// Check returns the throttle blocking a login attempt and how long it must
+// wait, or nil when the attempt may proceed
+func (g *LoginGuard) Check(ctx context.Context, username, ip string) (*LoginThrottle, time.Duration, error) {
+	var throttles []LoginThrottle
+	if err := g.db.WithContext(ctx).
+		Where("(scope = ? AND key = ?) OR (scope = ? AND key = ?)",
+			throttleScopeUsername, normalizeUsername(username), throttleScopeIP, ip).
+		Find(&throttles).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to load login throttles: %w", err)
+	}
+
+	now := time.Now()
+	var blocking *LoginThrottle
+	var wait time.Duration
+	for i := range throttles {
+		if retry := throttles[i].retryAfter(now); retry > wait {
+			blocking, wait = &throttles[i], retry
+		}
+	}
+	return blocking, wait, nil
+}
+
+// RecordFailure counts a failed login against the username and the source IP,
+// returning the throttles the failure locked out
+func (g *LoginGuard) RecordFailure(ctx context.Context, username, ip string) ([]*LoginThrottle, error) {
+	var locked []*LoginThrottle
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, k := range throttleKeys(username, ip) {
+			if k.key == "" {
+				continue
+			}
+
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&LoginThrottle{Scope: k.scope, Key: k.key}).Error; err != nil {
+				return fmt.Errorf("failed to create login throttle: %w", err)
+			}
+
+			var throttle LoginThrottle
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("scope = ? AND key = ?", k.scope, k.key).First(&throttle).Error; err != nil {
+				return fmt.Errorf("failed to load login throttle: %w", err)
+			}
+
+			if g.policy.recordFailure(&throttle, now) {
+				locked = append(locked, &throttle)
+			}
+			if err := tx.Save(&throttle).Error; err != nil {
+				return fmt.Errorf("failed to update login throttle: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return locked, nil
+}
+
+// RecordSuccess clears the failures of a username after a successful login.
+// The source IP keeps its count, so one valid account cannot reset spraying.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, username string) error {
+	if err := g.db.WithContext(ctx).
+		Where("scope = ? AND key = ?", throttleScopeUsername, normalizeUsername(username)).
+		Delete(&LoginThrottle{}).Error; err != nil {
+		return fmt.Errorf("failed to clear login throttle: %w", err)
+	}
+	return nil
+}
+
+// List returns throttles, only those currently locked out unless all is set
+func (g *LoginGuard) List(ctx context.Context, all bool) ([]LoginThrottle, error) {
+	query := g.db.WithContext(ctx).Order("updated_at DESC")
+	if !all {
+		query = query.Where("locked_until > ?", time.Now())
+	}
+
+	var throttles []LoginThrottle
+	if err := query.Find(&throttles).Error; err != nil {
+		return nil, fmt.Errorf("failed to list login throttles: %w", err)
+	}
+	return throttles, nil
+}
+
+// Clear removes a throttle, lifting its lockout and resetting its failures
+func (g *LoginGuard) Clear(ctx context.Context, id uint) (*LoginThrottle, error) {
+	var throttle LoginThrottle
+	err := g.db.WithContext(ctx).First(&throttle, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load login throttle: %w", err)
+	}
+
+	if err := g.db.WithContext(ctx).Delete(&throttle).Error; err != nil {
+		return nil, fmt.Errorf("failed to clear login throttle: %w", err)
+	}
+	return &throttle, nil
+}
+
+// rejectThrottledLogin answers a login attempt blocked by backoff or lockout
+func rejectThrottledLogin(c *gin.Context, wait time.Duration) {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many failed login attempts, try again later",
+		"retry_after": seconds,
+	})
+}
+
+// recordLoginFailure counts a failed login and audits any lockout it caused.
+// userID is zero when the username does not exist.
+func (s *UserManagementService) recordLoginFailure(c *gin.Context, username string, userID uint) {
+	locked, err := s.loginGuard.RecordFailure(c.Request.Context(), username, c.ClientIP())
+	if err != nil {
+		log.Printf("Failed to record login failure for %q: %v", username, err)
+		return
+	}
+
+	for _, throttle := range locked {
+		auditUserID := userID
+		if throttle.Scope == throttleScopeIP {
+			auditUserID = 0
+		}
+		s.LogAuditEvent(auditUserID, "account_locked", "authentication",
+			fmt.Sprintf("Login locked for %s %s until %s after repeated failures (lockout %d)",
+				throttle.Scope, throttle.Key, throttle.LockedUntil.Format(time.RFC3339), throttle.Lockouts),
+			c.ClientIP())
+	}
+}
+
+// ListLockouts returns active login lockouts, or every tracked throttle with ?all=true
+func (s *UserManagementService) ListLockouts(c *gin.Context) {
+	throttles, err := s.loginGuard.List(c.Request.Context(), c.Query("all") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list lockouts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lockouts": throttles, "total": len(throttles)})
+}
+
+// ClearLockout lifts a lockout and resets its failure count
+func (s *UserManagementService) ClearLockout(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lockout id"})
+		return
+	}
+
+	throttle, err := s.loginGuard.Clear(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear lockout"})
+		return
+	}
+	if throttle == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lockout not found"})
+		return
+	}
+
+	currentUserID := s.GetUserIDFromContext(c)
+	s.LogAuditEvent(currentUserID, "clear_lockout", "user_management",
+		fmt.Sprintf("Cleared login lockout for %s %s", throttle.Scope, throttle.Key), c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lockout cleared", "lockout": throttle})
+}
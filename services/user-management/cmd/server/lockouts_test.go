@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// lockoutTestDB adds login throttles to the session test database
+func lockoutTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := sessionTestDB(t)
+	require.NoError(t, db.AutoMigrate(&LoginThrottle{}))
+	return db
+}
+
+func TestLockoutPolicy(t *testing.T) {
+	policy := defaultLockoutPolicy
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("backs off before locking out", func(t *testing.T) {
+		throttle := &LoginThrottle{Scope: throttleScopeUsername, Key: "alice"}
+		now := start
+
+		assert.False(t, policy.recordFailure(throttle, now))
+		assert.Nil(t, throttle.NextAttemptAt, "first failure is not delayed")
+
+		for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+			now = now.Add(time.Second)
+			assert.False(t, policy.recordFailure(throttle, now), "failure %d", i+2)
+			require.NotNil(t, throttle.NextAttemptAt)
+			assert.Equal(t, want, throttle.retryAfter(now))
+		}
+
+		now = now.Add(time.Second)
+		assert.True(t, policy.recordFailure(throttle, now), "fifth failure locks out")
+		require.NotNil(t, throttle.LockedUntil)
+		assert.Equal(t, policy.LockoutDuration, throttle.retryAfter(now))
+		assert.Equal(t, 0, throttle.Failures)
+		assert.Equal(t, 1, throttle.Lockouts)
+	})
+
+	t.Run("consecutive lockouts double up to the maximum", func(t *testing.T) {
+		throttle := &LoginThrottle{Scope: throttleScopeUsername, Key: "bob"}
+		now := start
+
+		want := policy.LockoutDuration
+		for lockout := 1; lockout <= 8; lockout++ {
+			for i := 0; i < policy.MaxUsernameFailures; i++ {
+				now = now.Add(time.Second)
+				policy.recordFailure(throttle, now)
+			}
+			assert.Equal(t, lockout, throttle.Lockouts)
+			assert.Equal(t, want, throttle.LockedUntil.Sub(now), "lockout %d", lockout)
+
+			want *= 2
+			if want > policy.MaxLockoutDuration {
+				want = policy.MaxLockoutDuration
+			}
+		}
+	})
+
+	t.Run("failures outside the window start over", func(t *testing.T) {
+		throttle := &LoginThrottle{Scope: throttleScopeUsername, Key: "carol"}
+		now := start
+		for i := 0; i < policy.MaxUsernameFailures-1; i++ {
+			policy.recordFailure(throttle, now)
+		}
+
+		now = now.Add(policy.FailureWindow + time.Second)
+		assert.False(t, policy.recordFailure(throttle, now))
+		assert.Equal(t, 1, throttle.Failures)
+		assert.Nil(t, throttle.LockedUntil)
+	})
+
+	t.Run("lockout count resets after a quiet day", func(t *testing.T) {
+		throttle := &LoginThrottle{Scope: throttleScopeUsername, Key: "dave", Lockouts: 4}
+		now := start
+		throttle.LastFailureAt = now.Add(-policy.MaxLockoutDuration - time.Minute)
+
+		for i := 0; i < policy.MaxUsernameFailures; i++ {
+			policy.recordFailure(throttle, now)
+		}
+		assert.Equal(t, 1, throttle.Lockouts)
+		assert.Equal(t, policy.LockoutDuration, throttle.LockedUntil.Sub(now))
+	})
+
+	t.Run("source IPs have their own limit", func(t *testing.T) {
+		throttle := &LoginThrottle{Scope: throttleScopeIP, Key: "10.0.0.1"}
+		now := start
+		for i := 1; i < policy.MaxIPFailures; i++ {
+			assert.False(t, policy.recordFailure(throttle, now), "failure %d", i)
+		}
+		assert.True(t, policy.recordFailure(throttle, now))
+	})
+}
+
+func TestLoginGuard(t *testing.T) {
+	ctx := context.Background()
+	policy := defaultLockoutPolicy
+	policy.BackoffAfter = policy.MaxUsernameFailures // isolate the lockout from backoff
+
+	t.Run("locks out a username across spellings", func(t *testing.T) {
+		guard := NewLoginGuard(lockoutTestDB(t), policy)
+
+		for i := 1; i < policy.MaxUsernameFailures; i++ {
+			locked, err := guard.RecordFailure(ctx, "Alice", "10.0.0.1")
+			require.NoError(t, err)
+			assert.Empty(t, locked)
+		}
+		blocking, _, err := guard.Check(ctx, "alice", "10.0.0.2")
+		require.NoError(t, err)
+		assert.Nil(t, blocking)
+
+		locked, err := guard.RecordFailure(ctx, " ALICE ", "10.0.0.1")
+		require.NoError(t, err)
+		require.Len(t, locked, 1)
+		assert.Equal(t, throttleScopeUsername, locked[0].Scope)
+		assert.Equal(t, "alice", locked[0].Key)
+
+		blocking, wait, err := guard.Check(ctx, "alice", "10.0.0.2")
+		require.NoError(t, err)
+		require.NotNil(t, blocking)
+		assert.Equal(t, throttleScopeUsername, blocking.Scope)
+		assert.InDelta(t, policy.LockoutDuration.Seconds(), wait.Seconds(), 5)
+
+		lockouts, err := guard.List(ctx, false)
+		require.NoError(t, err)
+		require.Len(t, lockouts, 1)
+		assert.Equal(t, "alice", lockouts[0].Key)
+	})
+
+	t.Run("successful login keeps the source IP count", func(t *testing.T) {
+		db := lockoutTestDB(t)
+		guard := NewLoginGuard(db, policy)
+
+		for i := 0; i < 3; i++ {
+			_, err := guard.RecordFailure(ctx, "bob", "10.0.0.9")
+			require.NoError(t, err)
+		}
+		require.NoError(t, guard.RecordSuccess(ctx, "Bob"))
+
+		var throttles []LoginThrottle
+		require.NoError(t, db.Find(&throttles).Error)
+		require.Len(t, throttles, 1)
+		assert.Equal(t, throttleScopeIP, throttles[0].Scope)
+		assert.Equal(t, 3, throttles[0].Failures)
+	})
+
+	t.Run("clearing a throttle lifts the lockout", func(t *testing.T) {
+		guard := NewLoginGuard(lockoutTestDB(t), policy)
+		for i := 0; i < policy.MaxUsernameFailures; i++ {
+			_, err := guard.RecordFailure(ctx, "carol", "")
+			require.NoError(t, err)
+		}
+
+		blocking, _, err := guard.Check(ctx, "carol", "")
+		require.NoError(t, err)
+		require.NotNil(t, blocking)
+
+		_, err = guard.Clear(ctx, blocking.ID)
+		require.NoError(t, err)
+		blocking, _, err = guard.Check(ctx, "carol", "")
+		require.NoError(t, err)
+		assert.Nil(t, blocking)
+	})
+}
@@ -2,13 +2,13 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -17,6 +17,9 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+
+	"aegisshield/shared/rbac"
 )
 
 // User represents a system user
@@ -30,6 +33,10 @@ type User struct {
 	Role        string    `json:"role" gorm:"not null;default:'analyst'"` // analyst, investigator, admin, compliance
 	Department  string    `json:"department"`
 	IsActive    bool      `json:"is_active" gorm:"default:true"`
+	AuthProvider string   `json:"auth_provider" gorm:"not null;default:'local'"` // local, oidc
+	PasswordChangedAt *time.Time `json:"password_changed_at"`
+	MustChangePassword bool `json:"must_change_password" gorm:"default:false"`
+	RoleTemplateID *uint  `json:"role_template_id"`
 	LastLogin   *time.Time `json:"last_login"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -54,6 +61,8 @@ type UserSession struct {
 	CreatedAt time.Time `json:"created_at"`
 	IPAddress string    `json:"ip_address"`
 	UserAgent string    `json:"user_agent"`
+	RevokedAt *time.Time `json:"revoked_at" gorm:"index"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
 }
 
 // AuditLog represents user activity logs
@@ -71,23 +80,29 @@ type AuditLog struct {
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	MFACode  string `json:"mfa_code"` // TOTP or backup code, required once MFA is enabled
 }
 
 type LoginResponse struct {
-	Token     string    `json:"token"`
-	ExpiresAt time.Time `json:"expires_at"`
-	User      User      `json:"user"`
+	Token                 string    `json:"token"`
+	ExpiresAt             time.Time `json:"expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+	User                  User      `json:"user"`
+	MFAEnrollmentRequired bool      `json:"mfa_enrollment_required,omitempty"`
+	PasswordChangeRequired bool     `json:"password_change_required,omitempty"`
 }
 
 type CreateUserRequest struct {
 	Username   string   `json:"username" binding:"required"`
 	Email      string   `json:"email" binding:"required,email"`
-	Password   string   `json:"password" binding:"required,min=8"`
+	Password   string   `json:"password" binding:"required"` // checked against the password policy
 	FirstName  string   `json:"first_name" binding:"required"`
 	LastName   string   `json:"last_name" binding:"required"`
 	Role       string   `json:"role" binding:"required"`
 	Department string   `json:"department"`
 	Permissions []uint  `json:"permission_ids"`
+	MustChangePassword bool `json:"must_change_password"`
 }
 
 type UpdateUserRequest struct {
@@ -98,24 +113,55 @@ type UpdateUserRequest struct {
 	Department  *string `json:"department"`
 	IsActive    *bool   `json:"is_active"`
 	Permissions []uint  `json:"permission_ids"`
+	MustChangePassword *bool `json:"must_change_password"`
 }
 
 // UserManagementService handles user operations
 type UserManagementService struct {
-	db        *gorm.DB
-	jwtSecret []byte
+	db            *gorm.DB
+	sessions      *SessionStore
+	refreshTokens *RefreshTokenStore
+	loginGuard    *LoginGuard
+	mfa           *MFAService
+	passwords     *PasswordPolicyEngine
+	oidc          *OIDCProvider   // nil when single sign-on is not configured
+	userEvents    *UserEventRelay // nil when lifecycle events are not published
+	sessionPolicy SessionPolicy
+	policy        *rbac.Policy   // role grants permission changes are simulated against
+	workItems     WorkItemSource // nil when simulations skip open cases and alerts
+	jwtSecret     []byte
 }
 
 // NewUserManagementService creates a new user management service
-func NewUserManagementService(db *gorm.DB) *UserManagementService {
+func NewUserManagementService(db *gorm.DB, sessions *SessionStore) *UserManagementService {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		jwtSecret = "aegisshield-default-secret-change-in-production"
 	}
 	
+	var oidc *OIDCProvider
+	if cfg := oidcConfigFromEnv(); cfg != nil {
+		oidc = NewOIDCProvider(cfg)
+	}
+	
+	var userEvents *UserEventRelay
+	if cfg := userEventConfigFromEnv(); cfg != nil {
+		userEvents = NewUserEventRelay(db, *cfg)
+	}
+	
 	return &UserManagementService{
-		db:        db,
-		jwtSecret: []byte(jwtSecret),
+		db:            db,
+		sessions:      sessions,
+		refreshTokens: NewRefreshTokenStore(db, sessions, refreshTokenTTLFromEnv()),
+		loginGuard:    NewLoginGuard(db, lockoutPolicyFromEnv()),
+		mfa:           NewMFAService(db, mfaIssuerFromEnv(), mfaKeyFromEnv([]byte(jwtSecret))),
+		passwords:     NewPasswordPolicyEngine(db, passwordPolicyFromEnv()),
+		oidc:          oidc,
+		userEvents:    userEvents,
+		sessionPolicy: sessionPolicyFromEnv(),
+		policy:        rbac.DefaultPolicy(),
+		workItems:     workItemSourceFromEnv(),
+		jwtSecret:     []byte(jwtSecret),
 	}
 }
 
@@ -135,12 +181,21 @@ func (s *UserManagementService) CheckPassword(password, hash string) bool {
 func (s *UserManagementService) GenerateJWT(user *User) (string, time.Time, error) {
 	expiresAt := time.Now().Add(24 * time.Hour)
 	
+	// A unique id keeps tokens issued in the same second distinct, since
+	// each one backs its own session
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	
 	claims := jwt.MapClaims{
-		"user_id":   user.ID,
-		"username":  user.Username,
-		"role":      user.Role,
-		"exp":       expiresAt.Unix(),
-		"iat":       time.Now().Unix(),
+		"jti":         jti,
+		"user_id":     user.ID,
+		"username":    user.Username,
+		"role":        user.Role,
+		"permissions": permissionClaims(user.Permissions),
+		"exp":         expiresAt.Unix(),
+		"iat":         time.Now().Unix(),
 	}
 	
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -149,6 +204,16 @@ func (s *UserManagementService) GenerateJWT(user *User) (string, time.Time, erro
 	return tokenString, expiresAt, err
 }
 
+// permissionClaims lists a user's direct permissions as "resource:action"
+// grants for the shared RBAC policy evaluated by other services
+func permissionClaims(permissions []Permission) []string {
+	claims := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		claims = append(claims, permission.Resource+":"+permission.Action)
+	}
+	return claims
+}
+
 // Login authenticates a user and returns a JWT token
 func (s *UserManagementService) Login(c *gin.Context) {
 	var req LoginRequest
@@ -157,8 +222,20 @@ func (s *UserManagementService) Login(c *gin.Context) {
 		return
 	}
 	
+	// Throttled usernames and source IPs are turned away before any password check
+	_, wait, err := s.loginGuard.Check(c.Request.Context(), req.Username, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check login throttle"})
+		return
+	}
+	if wait > 0 {
+		rejectThrottledLogin(c, wait)
+		return
+	}
+	
 	var user User
 	if err := s.db.Preload("Permissions").Where("username = ? OR email = ?", req.Username, req.Username).First(&user).Error; err != nil {
+		s.recordLoginFailure(c, req.Username, 0)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
@@ -169,42 +246,50 @@ func (s *UserManagementService) Login(c *gin.Context) {
 	}
 	
 	if !s.CheckPassword(req.Password, user.PasswordHash) {
+		s.recordLoginFailure(c, req.Username, user.ID)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 	
-	token, expiresAt, err := s.GenerateJWT(&user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+	// Users with MFA enabled must also present a TOTP or backup code
+	enrollMFA, ok := s.checkLoginMFA(c, &user, req.Username, req.MFACode)
+	if !ok {
 		return
 	}
 	
-	// Save session
-	session := UserSession{
-		UserID:    user.ID,
-		Token:     token,
-		ExpiresAt: expiresAt,
-		IPAddress: c.ClientIP(),
-		UserAgent: c.GetHeader("User-Agent"),
+	if err := s.loginGuard.RecordSuccess(c.Request.Context(), req.Username); err != nil {
+		log.Printf("Failed to reset login throttle for %q: %v", req.Username, err)
 	}
-	s.db.Create(&session)
 	
-	// Update last login
+	// Save session with a refresh token starting a new rotation family
+	response, _, err := s.issueSession(c, &user, "")
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+	
+	// Update last login, starting password expiry for passwords set before it was tracked
 	now := time.Now()
 	user.LastLogin = &now
+	if user.PasswordChangedAt == nil {
+		user.PasswordChangedAt = &now
+	}
 	s.db.Save(&user)
 	
 	// Log audit event
 	s.LogAuditEvent(user.ID, "login", "authentication", "User logged in", c.ClientIP())
 	
 	// Remove password hash from response
-	user.PasswordHash = ""
+	response.User.PasswordHash = ""
+	response.User.LastLogin = user.LastLogin
 	
-	c.JSON(http.StatusOK, LoginResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		User:      user,
-	})
+	// Roles that require MFA can only reach the MFA routes until enrolled
+	response.MFAEnrollmentRequired = enrollMFA
+	
+	// Expired or admin-set passwords must be changed before anything else
+	response.PasswordChangeRequired = s.passwords.ChangeRequired(&user, now)
+	
+	c.JSON(http.StatusOK, response)
 }
 
 // CreateUser creates a new user account
@@ -222,6 +307,11 @@ func (s *UserManagementService) CreateUser(c *gin.Context) {
 		return
 	}
 	
+	if err := s.passwords.Validate(req.Password, &User{Username: req.Username, Email: req.Email}); err != nil {
+		rejectPassword(c, err)
+		return
+	}
+	
 	// Hash password
 	passwordHash, err := s.HashPassword(req.Password)
 	if err != nil {
@@ -230,6 +320,7 @@ func (s *UserManagementService) CreateUser(c *gin.Context) {
 	}
 	
 	// Create user
+	now := time.Now()
 	user := User{
 		Username:     req.Username,
 		Email:        req.Email,
@@ -239,22 +330,37 @@ func (s *UserManagementService) CreateUser(c *gin.Context) {
 		Role:         req.Role,
 		Department:   req.Department,
 		IsActive:     true,
+		AuthProvider: authProviderLocal,
+		PasswordChangedAt:  &now,
+		MustChangePassword: req.MustChangePassword,
 	}
 	
-	if err := s.db.Create(&user).Error; err != nil {
+	// The user, its permissions and its lifecycle event commit together
+	currentUserID := s.GetUserIDFromContext(c)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		
+		// Assign permissions
+		if len(req.Permissions) > 0 {
+			var permissions []Permission
+			if err := tx.Where("id IN ?", req.Permissions).Find(&permissions).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&user).Association("Permissions").Append(permissions); err != nil {
+				return err
+			}
+		}
+		
+		return s.recordUserChange(tx, nil, user.ID, currentUserID)
+	})
+	if err != nil {
+		log.Printf("Failed to create user %s: %v", user.Username, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 	
-	// Assign permissions
-	if len(req.Permissions) > 0 {
-		var permissions []Permission
-		s.db.Where("id IN ?", req.Permissions).Find(&permissions)
-		s.db.Model(&user).Association("Permissions").Append(permissions)
-	}
-	
-	// Get current user for audit log
-	currentUserID := s.GetUserIDFromContext(c)
 	s.LogAuditEvent(currentUserID, "create_user", "user_management", 
 		fmt.Sprintf("Created user: %s", user.Username), c.ClientIP())
 	
@@ -266,14 +372,16 @@ func (s *UserManagementService) CreateUser(c *gin.Context) {
 
 // GetUsers returns a list of users with optional filtering
 func (s *UserManagementService) GetUsers(c *gin.Context) {
-	page := c.DefaultQuery("page", "1")
-	limit := c.DefaultQuery("limit", "50")
+	pagination, err := parsePagination(c, userPageOptions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	role := c.Query("role")
 	department := c.Query("department")
 	active := c.Query("active")
 	
-	var users []User
-	query := s.db.Preload("Permissions").Offset((getIntFromString(page) - 1) * getIntFromString(limit)).Limit(getIntFromString(limit))
+	query := s.db.Model(&User{})
 	
 	if role != "" {
 		query = query.Where("role = ?", role)
@@ -285,7 +393,14 @@ func (s *UserManagementService) GetUsers(c *gin.Context) {
 		query = query.Where("is_active = ?", active == "true")
 	}
 	
-	if err := query.Find(&users).Error; err != nil {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count users"})
+		return
+	}
+	
+	var users []User
+	if err := pagination.Apply(query.Preload("Permissions")).Find(&users).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
 		return
 	}
@@ -295,7 +410,35 @@ func (s *UserManagementService) GetUsers(c *gin.Context) {
 		users[i].PasswordHash = ""
 	}
 	
-	c.JSON(http.StatusOK, gin.H{"users": users})
+	pagination.Respond(c, "users", users, total)
+}
+
+// ListPermissions returns a page of permissions
+func (s *UserManagementService) ListPermissions(c *gin.Context) {
+	pagination, err := parsePagination(c, permissionPageOptions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := s.db.Model(&Permission{})
+	if resource := c.Query("resource"); resource != "" {
+		query = query.Where("resource = ?", resource)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count permissions"})
+		return
+	}
+
+	var permissions []Permission
+	if err := pagination.Apply(query).Find(&permissions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch permissions"})
+		return
+	}
+
+	pagination.Respond(c, "permissions", permissions, total)
 }
 
 // UpdateUser updates user information
@@ -314,6 +457,12 @@ func (s *UserManagementService) UpdateUser(c *gin.Context) {
 		return
 	}
 	
+	before, err := s.beforeUserChange(s.db, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		return
+	}
+	
 	// Update fields
 	if req.FirstName != nil {
 		user.FirstName = *req.FirstName
@@ -333,21 +482,45 @@ func (s *UserManagementService) UpdateUser(c *gin.Context) {
 	if req.IsActive != nil {
 		user.IsActive = *req.IsActive
 	}
+	if req.MustChangePassword != nil {
+		user.MustChangePassword = *req.MustChangePassword
+	}
 	
-	if err := s.db.Save(&user).Error; err != nil {
+	// The user, its permissions and its lifecycle event commit together
+	currentUserID := s.GetUserIDFromContext(c)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		
+		// Update permissions
+		if req.Permissions != nil {
+			var permissions []Permission
+			if err := tx.Where("id IN ?", req.Permissions).Find(&permissions).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&user).Association("Permissions").Replace(permissions); err != nil {
+				return err
+			}
+		}
+		
+		return s.recordUserChange(tx, before, user.ID, currentUserID)
+	})
+	if err != nil {
+		log.Printf("Failed to update user %d: %v", user.ID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
 	
-	// Update permissions
-	if req.Permissions != nil {
-		var permissions []Permission
-		s.db.Where("id IN ?", req.Permissions).Find(&permissions)
-		s.db.Model(&user).Association("Permissions").Replace(permissions)
+	// Deactivated users are signed out everywhere once the deactivation
+	// has committed
+	if req.IsActive != nil && !*req.IsActive {
+		if _, err := s.refreshTokens.RevokeUser(c.Request.Context(), user.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+			return
+		}
 	}
 	
-	// Get current user for audit log
-	currentUserID := s.GetUserIDFromContext(c)
 	s.LogAuditEvent(currentUserID, "update_user", "user_management", 
 		fmt.Sprintf("Updated user: %s", user.Username), c.ClientIP())
 	
@@ -370,29 +543,69 @@ func (s *UserManagementService) LogAuditEvent(userID uint, action, resource, det
 	s.db.Create(&auditLog)
 }
 
-// GetUserIDFromContext extracts user ID from JWT token in context
-func (s *UserManagementService) GetUserIDFromContext(c *gin.Context) uint {
-	// This would be implemented by the JWT middleware
-	// For now, returning a default value
-	return 1
-}
+// GetAuditLogs returns audit log entries filtered by user, action, resource and time range
+func (s *UserManagementService) GetAuditLogs(c *gin.Context) {
+	pagination, err := parsePagination(c, auditLogPageOptions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-// Helper function to convert string to int
-func getIntFromString(s string) int {
-	if s == "" {
-		return 0
+	query := s.db.Model(&AuditLog{})
+
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id"})
+			return
+		}
+		query = query.Where("user_id = ?", id)
 	}
-	// Simple conversion, should use strconv.Atoi in production
-	switch s {
-	case "1":
-		return 1
-	case "2":
-		return 2
-	default:
-		return 1
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
 	}
+	if resource := c.Query("resource"); resource != "" {
+		query = query.Where("resource = ?", resource)
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from timestamp, expected RFC3339"})
+			return
+		}
+		query = query.Where("timestamp >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to timestamp, expected RFC3339"})
+			return
+		}
+		query = query.Where("timestamp <= ?", t)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count audit logs"})
+		return
+	}
+
+	var logs []AuditLog
+	if err := pagination.Apply(query).Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit logs"})
+		return
+	}
+
+	pagination.Respond(c, "audit_logs", logs, total)
 }
 
+// GetUserIDFromContext returns the user ID set by AuthMiddleware, or 0 for
+// unauthenticated requests
+func (s *UserManagementService) GetUserIDFromContext(c *gin.Context) uint {
+	return c.GetUint(contextUserIDKey)
+}
+
+
 // SetupRoutes configures the HTTP routes
 func SetupRoutes(service *UserManagementService) *gin.Engine {
 	r := gin.Default()
@@ -403,6 +616,7 @@ func SetupRoutes(service *UserManagementService) *gin.Engine {
 			"status":    "healthy",
 			"service":   "user-management",
 			"timestamp": time.Now(),
+			"session_cache": service.sessions.Status(),
 		})
 	})
 	
@@ -410,33 +624,108 @@ func SetupRoutes(service *UserManagementService) *gin.Engine {
 	auth := r.Group("/auth")
 	{
 		auth.POST("/login", service.Login)
-		auth.POST("/logout", func(c *gin.Context) {
-			// Implement logout logic
-			c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
-		})
+		auth.POST("/logout", service.Logout)
+		auth.POST("/refresh", service.RefreshSession)
+		auth.POST("/revoke", service.RevokeRefreshToken)
+		auth.GET("/session", service.GetSession)
+		auth.GET("/oidc/login", service.OIDCLogin)
+		auth.GET("/oidc/callback", service.OIDCCallback)
+	}
+	
+	// Session management for the signed-in user
+	sessions := r.Group("/auth/sessions")
+	sessions.Use(service.AuthMiddleware())
+	{
+		sessions.GET("", service.ListSessions)
+		sessions.DELETE("", service.RevokeOtherSessions)
+		sessions.DELETE("/:id", service.RevokeSession)
+	}
+	
+	// MFA enrollment routes, reachable before a required enrollment is done
+	mfa := r.Group(mfaRoutePrefix)
+	mfa.Use(service.AuthMiddleware())
+	{
+		mfa.GET("", service.GetMFAStatus)
+		mfa.POST("/enroll", service.EnrollMFA)
+		mfa.POST("/confirm", service.ConfirmMFA)
+		mfa.POST("/backup-codes", service.RegenerateBackupCodes)
+		mfa.DELETE("", service.DisableMFA)
 	}
 	
-	// User management routes (protected)
-	users := r.Group("/users")
-	// users.Use(AuthMiddleware()) // JWT middleware would go here
+	// Everything below requires an authenticated, active user
+	authenticated := r.Group("/")
+	authenticated.Use(service.AuthMiddleware())
+	
+	// Password changes, open to the user themselves as well as admins
+	authenticated.PUT(passwordRoutePath, service.ChangePassword)
+	
+	// User management routes
+	users := authenticated.Group("/users")
+	users.Use(RequireRole(roleAdmin))
 	{
 		users.POST("/", service.CreateUser)
 		users.GET("/", service.GetUsers)
 		users.PUT("/:id", service.UpdateUser)
+		users.GET("/:id/sessions", service.ListUserSessions)
+		users.DELETE("/:id/sessions", service.RevokeUserSessions)
+		users.DELETE("/:id/mfa", service.ResetUserMFA)
 		users.GET("/:id", func(c *gin.Context) {
 			// Get single user implementation
 			c.JSON(http.StatusOK, gin.H{"message": "Get user endpoint"})
 		})
 	}
 	
+	// Login lockout routes
+	lockouts := authenticated.Group("/lockouts")
+	lockouts.Use(RequireRole(roleAdmin))
+	{
+		lockouts.GET("/", service.ListLockouts)
+		lockouts.DELETE("/:id", service.ClearLockout)
+	}
+	
+	// MFA policy routes
+	mfaPolicies := authenticated.Group("/mfa-policies")
+	mfaPolicies.Use(RequireRole(roleAdmin))
+	{
+		mfaPolicies.GET("/", service.ListMFAPolicies)
+		mfaPolicies.PUT("/:role", service.UpdateMFAPolicy)
+	}
+	
+	// Service accounts and their API keys
+	serviceAccounts := authenticated.Group("/service-accounts")
+	serviceAccounts.Use(RequireRole(roleAdmin))
+	{
+		serviceAccounts.POST("/", service.CreateServiceAccount)
+		serviceAccounts.GET("/", service.ListServiceAccounts)
+		serviceAccounts.DELETE("/:id", service.DeactivateServiceAccount)
+		serviceAccounts.POST("/:id/api-keys", service.CreateAPIKey)
+		serviceAccounts.GET("/:id/api-keys", service.ListAPIKeys)
+		serviceAccounts.DELETE("/:id/api-keys/:keyId", service.RevokeAPIKey)
+	}
+	
+	// Audit log routes
+	authenticated.GET("/audit-logs", RequireRole("compliance"), service.GetAuditLogs)
+	
 	// Permissions routes
-	permissions := r.Group("/permissions")
+	permissions := authenticated.Group("/permissions")
 	{
-		permissions.GET("/", func(c *gin.Context) {
-			var permissions []Permission
-			service.db.Find(&permissions)
-			c.JSON(http.StatusOK, gin.H{"permissions": permissions})
-		})
+		permissions.GET("/", service.ListPermissions)
+		permissions.POST("/bulk", RequireRole(roleAdmin), service.BulkUpdatePermissions)
+		permissions.GET("/drift", RequireRole("compliance"), service.GetPermissionDrift)
+		permissions.POST("/simulate/:role", RequireRole(roleAdmin), service.SimulatePermissionChange)
+	}
+	
+	// Role template routes
+	templates := authenticated.Group("/role-templates")
+	templates.Use(RequireRole(roleAdmin))
+	{
+		templates.GET("/", service.ListRoleTemplates)
+		templates.POST("/", service.CreateRoleTemplate)
+		templates.GET("/:id", service.GetRoleTemplate)
+		templates.PUT("/:id", service.UpdateRoleTemplate)
+		templates.DELETE("/:id", service.DeleteRoleTemplate)
+		templates.POST("/:id/preview", service.PreviewRoleTemplate)
+		templates.POST("/:id/apply", service.ApplyRoleTemplate)
 	}
 	
 	return r
@@ -453,6 +742,12 @@ func SeedDefaultData(db *gorm.DB) error {
 		{Name: "admin_users", Resource: "users", Action: "admin", Description: "Manage users"},
 		{Name: "read_entities", Resource: "entities", Action: "read", Description: "Read entities"},
 		{Name: "write_entities", Resource: "entities", Action: "write", Description: "Update entities"},
+		{Name: "read_ingestion", Resource: "ingestion", Action: "read", Description: "Read uploads and ingestion jobs"},
+		{Name: "write_ingestion", Resource: "ingestion", Action: "write", Description: "Upload data and record feed deliveries"},
+		{Name: "read_sar", Resource: "sar", Action: "read", Description: "Read SAR filings and traceability reports"},
+		{Name: "write_sar", Resource: "sar", Action: "write", Description: "Draft and file SARs"},
+		{Name: "read_residency", Resource: "residency", Action: "read", Description: "Read case regions, residency grants and compliance reports"},
+		{Name: "write_residency", Resource: "residency", Action: "write", Description: "Pin cases to regions and grant cross-region access"},
 	}
 	
 	for _, perm := range permissions {
@@ -460,7 +755,7 @@ func SeedDefaultData(db *gorm.DB) error {
 	}
 	
 	// Create default admin user
-	service := NewUserManagementService(db)
+	service := NewUserManagementService(db, NewSessionStore(db, nil, 0))
 	passwordHash, _ := service.HashPassword("admin123")
 	
 	adminUser := User{
@@ -472,11 +767,13 @@ func SeedDefaultData(db *gorm.DB) error {
 		Role:         "admin",
 		Department:   "IT",
 		IsActive:     true,
+		// The well-known seed password has to be replaced on first login
+		MustChangePassword: true,
 	}
 	
 	db.FirstOrCreate(&adminUser, User{Username: "admin"})
 	
-	return nil
+	return SeedMFAPolicies(db)
 }
 
 func main() {
@@ -492,7 +789,7 @@ func main() {
 	}
 	
 	// Auto-migrate schemas
-	err = db.AutoMigrate(&User{}, &Permission{}, &UserSession{}, &AuditLog{})
+	err = db.AutoMigrate(&User{}, &Permission{}, &UserSession{}, &RefreshToken{}, &AuditLog{}, &RoleTemplate{}, &LoginThrottle{}, &UserMFA{}, &MFABackupCode{}, &MFAPolicy{}, &OIDCLoginState{}, &OIDCIdentity{}, &PasswordHistory{}, &ServiceAccount{}, &APIKey{}, &UserEventRecord{}, &SessionCacheEviction{})
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
@@ -502,8 +799,24 @@ func main() {
 		log.Fatal("Failed to seed default data:", err)
 	}
 	
-	// Create service
-	service := NewUserManagementService(db)
+	// Create service; sessions are cached in Redis when SESSION_STORE=redis
+	service := NewUserManagementService(db, NewSessionStoreFromEnv(db))
+	
+	// Expire idle sessions and purge ended ones in the background
+	reaperCtx, stopReaper := context.WithCancel(context.Background())
+	go service.StartSessionReaper(reaperCtx)
+	
+	// Relay user lifecycle events to Kafka when USER_EVENTS_BROKERS is set
+	if service.userEvents != nil {
+		go service.userEvents.Run(reaperCtx)
+	}
+	
+	// Role grants for the gRPC permission checks and change simulations
+	policy, err := rbac.LoadPolicyFile(os.Getenv("RBAC_POLICY_FILE"))
+	if err != nil {
+		log.Fatal("Failed to load RBAC policy:", err)
+	}
+	service.policy = policy
 	
 	// Setup routes
 	router := SetupRoutes(service)
@@ -528,12 +841,38 @@ func main() {
 	
 	log.Printf("User Management Service started on port %s", port)
 	
+	// gRPC user service for identity lookups from other services
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9070"
+	}
+	grpcListener, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		log.Fatalf("Failed to listen on gRPC port %s: %v", grpcPort, err)
+	}
+	grpcSrv := grpc.NewServer()
+	NewUserServiceServer(service, rbac.NewEvaluator(policy, time.Minute, 10000)).Register(grpcSrv)
+	go func() {
+		if err := grpcSrv.Serve(grpcListener); err != nil {
+			log.Fatalf("gRPC server failed: %v", err)
+		}
+	}()
+	
+	log.Printf("User service gRPC API started on port %s", grpcPort)
+	
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	
 	log.Println("Shutting down server...")
+	stopReaper()
+	grpcSrv.GracefulStop()
+	if service.userEvents != nil {
+		if err := service.userEvents.Close(); err != nil {
+			log.Printf("Failed to close user event writer: %v", err)
+		}
+	}
 	
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -0,0 +1,845 @@
+package main
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TOTP parameters (RFC 6238), matching what authenticator apps expect
+const (
+	totpPeriod    = 30 * time.Second
+	totpDigits    = 6
+	totpSkewSteps = 1 // steps either side of now still accepted for clock drift
+	totpSecretLen = 20
+
+	backupCodeCount = 10
+	backupCodeLen   = 10
+)
+
+// Ways a login can satisfy MFA
+const (
+	mfaMethodTOTP       = "totp"
+	mfaMethodBackupCode = "backup_code"
+)
+
+// mfaRoutePrefix marks the routes users who still have to enroll can reach
+const mfaRoutePrefix = "/auth/mfa"
+
+// validRoles are the roles an MFA policy can be set for
+var validRoles = map[string]bool{
+	"analyst":      true,
+	"investigator": true,
+	"admin":        true,
+	"compliance":   true,
+}
+
+var (
+	// ErrMFANotEnrolled is returned when a user has no confirmed MFA enrollment
+	ErrMFANotEnrolled = errors.New("MFA is not enrolled")
+	// ErrMFAAlreadyEnabled is returned when enrolling a user who already has MFA
+	ErrMFAAlreadyEnabled = errors.New("MFA is already enabled")
+	// ErrMFACodeInvalid is returned for wrong, expired, replayed or used codes
+	ErrMFACodeInvalid = errors.New("MFA code is invalid")
+)
+
+// UserMFA is a user's TOTP enrollment. The secret is encrypted at rest and
+// the enrollment only takes effect once confirmed with a valid code.
+// LastUsedStep rejects replays of a code within its validity window.
+type UserMFA struct {
+	ID               uint       `json:"-" gorm:"primaryKey"`
+	UserID           uint       `json:"user_id" gorm:"uniqueIndex;not null"`
+	SecretCiphertext string     `json:"-" gorm:"not null"`
+	Enabled          bool       `json:"enabled"`
+	ConfirmedAt      *time.Time `json:"confirmed_at"`
+	LastUsedStep     int64      `json:"-"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// MFABackupCode is a single-use recovery code. Only a hash is stored.
+type MFABackupCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null;index"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MFAPolicy records whether a role must use MFA
+type MFAPolicy struct {
+	Role      string    `json:"role" gorm:"primaryKey"`
+	Required  bool      `json:"required"`
+	UpdatedBy uint      `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// defaultMFAPolicies require MFA for the roles that can change users or read
+// the audit trail
+var defaultMFAPolicies = []MFAPolicy{
+	{Role: "admin", Required: true},
+	{Role: "compliance", Required: true},
+}
+
+// MFAStatus describes a user's MFA state
+type MFAStatus struct {
+	Enabled              bool       `json:"enabled"`
+	Required             bool       `json:"required"`
+	ConfirmedAt          *time.Time `json:"confirmed_at,omitempty"`
+	BackupCodesRemaining int        `json:"backup_codes_remaining"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFAPolicyRequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
+
+// MFAService manages TOTP enrollments, backup codes and the role policy
+type MFAService struct {
+	db     *gorm.DB
+	issuer string
+	aead   cipher.AEAD
+}
+
+// NewMFAService creates an MFA service. key is hashed into the AES-256 key
+// that encrypts TOTP secrets.
+func NewMFAService(db *gorm.DB, issuer string, key []byte) *MFAService {
+	sum := sha256.Sum256(key)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		log.Fatalf("Failed to create MFA cipher: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		log.Fatalf("Failed to create MFA cipher: %v", err)
+	}
+
+	return &MFAService{
+		db:     db,
+		issuer: issuer,
+		aead:   aead,
+	}
+}
+
+// mfaIssuerFromEnv reads MFA_ISSUER, the name authenticator apps show
+func mfaIssuerFromEnv() string {
+	if issuer := os.Getenv("MFA_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "AegisShield"
+}
+
+// mfaKeyFromEnv reads MFA_ENCRYPTION_KEY, falling back to the JWT secret
+func mfaKeyFromEnv(jwtSecret []byte) []byte {
+	if key := os.Getenv("MFA_ENCRYPTION_KEY"); key != "" {
+		return []byte(key)
+	}
+	return jwtSecret
+}
+
+// SeedMFAPolicies creates the default role policies without overriding
+// policies an admin has already changed
+func SeedMFAPolicies(db *gorm.DB) error {
+	for _, policy := range defaultMFAPolicies {
+		policy := policy
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&policy).Error; err != nil {
+			return fmt.Errorf("failed to seed MFA policy: %w", err)
+		}
+	}
+	return nil
+}
+
+// Enroll starts (or restarts) an unconfirmed enrollment and returns the new
+// secret with its otpauth URI for QR codes
+func (m *MFAService) Enroll(ctx context.Context, user *User) (string, string, error) {
+	secret := make([]byte, totpSecretLen)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate MFA secret: %w", err)
+	}
+	ciphertext, err := m.encrypt(secret)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		enrollment, err := lockEnrollment(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		if enrollment == nil {
+			return tx.Create(&UserMFA{UserID: user.ID, SecretCiphertext: ciphertext}).Error
+		}
+		if enrollment.Enabled {
+			return ErrMFAAlreadyEnabled
+		}
+		enrollment.SecretCiphertext = ciphertext
+		enrollment.LastUsedStep = 0
+		return tx.Save(enrollment).Error
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+	return encoded, m.otpauthURI(user.Username, encoded), nil
+}
+
+// Confirm enables a pending enrollment once the user proves their
+// authenticator works, and returns a fresh set of backup codes
+func (m *MFAService) Confirm(ctx context.Context, userID uint, code string) ([]string, error) {
+	var codes []string
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		enrollment, err := lockEnrollment(tx, userID)
+		if err != nil {
+			return err
+		}
+		if enrollment == nil {
+			return ErrMFANotEnrolled
+		}
+		if enrollment.Enabled {
+			return ErrMFAAlreadyEnabled
+		}
+
+		step, err := m.checkTOTP(enrollment, code, time.Now())
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		enrollment.Enabled = true
+		enrollment.ConfirmedAt = &now
+		enrollment.LastUsedStep = step
+		if err := tx.Save(enrollment).Error; err != nil {
+			return fmt.Errorf("failed to enable MFA: %w", err)
+		}
+
+		codes, err = replaceBackupCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verify checks a TOTP or backup code for an enabled enrollment and returns
+// which method matched and how many backup codes remain
+func (m *MFAService) Verify(ctx context.Context, enrollment *UserMFA, code string) (string, int, error) {
+	code = strings.TrimSpace(code)
+	if isTOTPCode(code) {
+		if err := m.consumeTOTP(ctx, enrollment, code); err != nil {
+			return "", 0, err
+		}
+		remaining, err := m.remainingBackupCodes(ctx, enrollment.UserID)
+		return mfaMethodTOTP, remaining, err
+	}
+
+	result := m.db.WithContext(ctx).Model(&MFABackupCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", enrollment.UserID, hashBackupCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return "", 0, fmt.Errorf("failed to use backup code: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return "", 0, ErrMFACodeInvalid
+	}
+
+	remaining, err := m.remainingBackupCodes(ctx, enrollment.UserID)
+	return mfaMethodBackupCode, remaining, err
+}
+
+// RegenerateBackupCodes replaces every backup code after checking a current TOTP code
+func (m *MFAService) RegenerateBackupCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+	enrollment, err := m.enrollment(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment == nil || !enrollment.Enabled {
+		return nil, ErrMFANotEnrolled
+	}
+	if !isTOTPCode(strings.TrimSpace(code)) {
+		return nil, ErrMFACodeInvalid
+	}
+	if err := m.consumeTOTP(ctx, enrollment, strings.TrimSpace(code)); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		codes, err = replaceBackupCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable removes a user's enrollment and backup codes, reporting whether
+// there was anything to remove
+func (m *MFAService) Disable(ctx context.Context, userID uint) (bool, error) {
+	var removed int64
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ?", userID).Delete(&UserMFA{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to remove MFA enrollment: %w", result.Error)
+		}
+		removed = result.RowsAffected
+		if err := tx.Where("user_id = ?", userID).Delete(&MFABackupCode{}).Error; err != nil {
+			return fmt.Errorf("failed to remove backup codes: %w", err)
+		}
+		return nil
+	})
+	return removed > 0, err
+}
+
+// Status reports a user's MFA state
+func (m *MFAService) Status(ctx context.Context, user *User) (*MFAStatus, error) {
+	required, err := m.Required(ctx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := m.enrollment(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MFAStatus{Required: required}
+	if enrollment != nil && enrollment.Enabled {
+		status.Enabled = true
+		status.ConfirmedAt = enrollment.ConfirmedAt
+		if status.BackupCodesRemaining, err = m.remainingBackupCodes(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// Required reports whether the policy requires MFA for a role
+func (m *MFAService) Required(ctx context.Context, role string) (bool, error) {
+	var policy MFAPolicy
+	err := m.db.WithContext(ctx).Where("role = ?", role).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load MFA policy: %w", err)
+	}
+	return policy.Required, nil
+}
+
+// EnrollmentPending reports whether a user's role requires MFA that the
+// user has not enabled yet. SSO users are left to the identity provider's
+// own MFA.
+func (m *MFAService) EnrollmentPending(ctx context.Context, user *User) (bool, error) {
+	if user.AuthProvider == authProviderOIDC {
+		return false, nil
+	}
+	required, err := m.Required(ctx, user.Role)
+	if err != nil || !required {
+		return false, err
+	}
+	enrollment, err := m.enrollment(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	return enrollment == nil || !enrollment.Enabled, nil
+}
+
+// ListPolicies returns the policy of every role, defaulting to not required
+func (m *MFAService) ListPolicies(ctx context.Context) ([]MFAPolicy, error) {
+	var stored []MFAPolicy
+	if err := m.db.WithContext(ctx).Find(&stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to list MFA policies: %w", err)
+	}
+
+	byRole := make(map[string]MFAPolicy, len(stored))
+	for _, policy := range stored {
+		byRole[policy.Role] = policy
+	}
+
+	policies := make([]MFAPolicy, 0, len(validRoles))
+	for _, role := range []string{"admin", "analyst", "compliance", "investigator"} {
+		policy, ok := byRole[role]
+		if !ok {
+			policy = MFAPolicy{Role: role}
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// SetPolicy requires or stops requiring MFA for a role
+func (m *MFAService) SetPolicy(ctx context.Context, role string, required bool, updatedBy uint) (*MFAPolicy, error) {
+	policy := &MFAPolicy{Role: role, Required: required, UpdatedBy: updatedBy, UpdatedAt: time.Now()}
+	if err := m.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "role"}},
+		DoUpdates: clause.AssignmentColumns([]string{"required", "updated_by", "updated_at"}),
+	}).Create(policy).Error; err != nil {
+		return nil, fmt.Errorf("failed to update MFA policy: %w", err)
+	}
+	return policy, nil
+}
+
+// enrollment loads a user's enrollment, or nil when there is none
+func (m *MFAService) enrollment(ctx context.Context, userID uint) (*UserMFA, error) {
+	var enrollment UserMFA
+	err := m.db.WithContext(ctx).Where("user_id = ?", userID).First(&enrollment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load MFA enrollment: %w", err)
+	}
+	return &enrollment, nil
+}
+
+// lockEnrollment loads a user's enrollment for update inside a transaction
+func lockEnrollment(tx *gorm.DB, userID uint) (*UserMFA, error) {
+	var enrollment UserMFA
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&enrollment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load MFA enrollment: %w", err)
+	}
+	return &enrollment, nil
+}
+
+// consumeTOTP accepts a code at most once. The step only moves forward in a
+// conditional update, so two logins racing with the same code cannot both pass.
+func (m *MFAService) consumeTOTP(ctx context.Context, enrollment *UserMFA, code string) error {
+	step, err := m.checkTOTP(enrollment, code, time.Now())
+	if err != nil {
+		return err
+	}
+
+	result := m.db.WithContext(ctx).Model(&UserMFA{}).
+		Where("id = ? AND last_used_step < ?", enrollment.ID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return fmt.Errorf("failed to record MFA code use: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrMFACodeInvalid
+	}
+	enrollment.LastUsedStep = step
+	return nil
+}
+
+// checkTOTP returns the time step a code matches, rejecting steps at or
+// before the last one used
+func (m *MFAService) checkTOTP(enrollment *UserMFA, code string, now time.Time) (int64, error) {
+	secret, err := m.decrypt(enrollment.SecretCiphertext)
+	if err != nil {
+		return 0, err
+	}
+
+	current := now.Unix() / int64(totpPeriod/time.Second)
+	for offset := -totpSkewSteps; offset <= totpSkewSteps; offset++ {
+		step := current + int64(offset)
+		if step <= enrollment.LastUsedStep {
+			continue
+		}
+		if hmac.Equal([]byte(totpCode(secret, step)), []byte(code)) {
+			return step, nil
+		}
+	}
+	return 0, ErrMFACodeInvalid
+}
+
+// totpCode computes the RFC 4226 HOTP value for a time step
+func totpCode(secret []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totpDigits {
+		return false
+	}
+	_, err := strconv.Atoi(code)
+	return err == nil
+}
+
+func (m *MFAService) otpauthURI(account, secret string) string {
+	values := url.Values{}
+	values.Set("secret", secret)
+	values.Set("issuer", m.issuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", strconv.Itoa(totpDigits))
+	values.Set("period", strconv.Itoa(int(totpPeriod/time.Second)))
+
+	label := url.PathEscape(m.issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + values.Encode()
+}
+
+func (m *MFAService) encrypt(secret []byte) (string, error) {
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to encrypt MFA secret: %w", err)
+	}
+	sealed := m.aead.Seal(nonce, nonce, secret, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (m *MFAService) decrypt(ciphertext string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < m.aead.NonceSize() {
+		return nil, errors.New("failed to decrypt MFA secret")
+	}
+	nonce := sealed[:m.aead.NonceSize()]
+	secret, err := m.aead.Open(nil, nonce, sealed[m.aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt MFA secret")
+	}
+	return secret, nil
+}
+
+// replaceBackupCodes discards a user's backup codes and stores a new set,
+// returning the codes in the clear for the only time
+func replaceBackupCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&MFABackupCode{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to remove backup codes: %w", err)
+	}
+
+	codes := make([]string, 0, backupCodeCount)
+	rows := make([]MFABackupCode, 0, backupCodeCount)
+	for i := 0; i < backupCodeCount; i++ {
+		code, err := newBackupCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		rows = append(rows, MFABackupCode{UserID: userID, CodeHash: hashBackupCode(code)})
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to store backup codes: %w", err)
+	}
+	return codes, nil
+}
+
+func (m *MFAService) remainingBackupCodes(ctx context.Context, userID uint) (int, error) {
+	var remaining int64
+	if err := m.db.WithContext(ctx).Model(&MFABackupCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).Count(&remaining).Error; err != nil {
+		return 0, fmt.Errorf("failed to count backup codes: %w", err)
+	}
+	return int(remaining), nil
+}
+
+// newBackupCode returns a random code such as "k7m2q-x9d4t"
+func newBackupCode() (string, error) {
+	raw := make([]byte, backupCodeLen)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate backup code: %w", err)
+	}
+
+	// 32 symbols without look-alikes, so each random byte maps without bias
+	const alphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+	code := make([]byte, backupCodeLen)
+	for i, b := range raw {
+		code[i] = alphabet[b&31]
+	}
+	return string(code[:backupCodeLen/2]) + "-" + string(code[backupCodeLen/2:]), nil
+}
+
+// hashBackupCode hashes a backup code, ignoring case and separators
+func hashBackupCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkLoginMFA enforces MFA after a correct password. It returns whether the
+// user still has to enroll, and false when it has already answered the request.
+func (s *UserManagementService) checkLoginMFA(c *gin.Context, user *User, username, code string) (bool, bool) {
+	ctx := c.Request.Context()
+
+	enrollment, err := s.mfa.enrollment(ctx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check MFA"})
+		return false, false
+	}
+	if enrollment == nil || !enrollment.Enabled {
+		required, err := s.mfa.Required(ctx, user.Role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check MFA policy"})
+			return false, false
+		}
+		return required, true
+	}
+
+	if strings.TrimSpace(code) == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "MFA code required", "mfa_required": true})
+		return false, false
+	}
+
+	method, remaining, err := s.mfa.Verify(ctx, enrollment, code)
+	if errors.Is(err, ErrMFACodeInvalid) {
+		// Wrong codes count towards the lockout so six digits cannot be brute forced
+		s.recordLoginFailure(c, username, user.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid MFA code", "mfa_required": true})
+		return false, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify MFA code"})
+		return false, false
+	}
+
+	if method == mfaMethodBackupCode {
+		s.LogAuditEvent(user.ID, "mfa_backup_code_used", "authentication",
+			fmt.Sprintf("Logged in with a backup code, %d remaining", remaining), c.ClientIP())
+	}
+	return false, true
+}
+
+// GetMFAStatus returns the current user's MFA state
+func (s *UserManagementService) GetMFAStatus(c *gin.Context) {
+	status, err := s.mfa.Status(c.Request.Context(), CurrentUser(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load MFA status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// EnrollMFA starts TOTP enrollment for the current user
+func (s *UserManagementService) EnrollMFA(c *gin.Context) {
+	user := CurrentUser(c)
+
+	secret, uri, err := s.mfa.Enroll(c.Request.Context(), user)
+	if errors.Is(err, ErrMFAAlreadyEnabled) {
+		c.JSON(http.StatusConflict, gin.H{"error": "MFA is already enabled, disable it before enrolling again"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start MFA enrollment"})
+		return
+	}
+
+	s.LogAuditEvent(user.ID, "mfa_enroll", "authentication", "Started MFA enrollment", c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": uri,
+		"message":     "Confirm enrollment with a code from your authenticator app",
+	})
+}
+
+// ConfirmMFA enables MFA for the current user and returns their backup codes
+func (s *UserManagementService) ConfirmMFA(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := CurrentUser(c)
+	codes, err := s.mfa.Confirm(c.Request.Context(), user.ID, strings.TrimSpace(req.Code))
+	switch {
+	case errors.Is(err, ErrMFANotEnrolled):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No MFA enrollment in progress"})
+		return
+	case errors.Is(err, ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, ErrMFACodeInvalid):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid MFA code"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm MFA enrollment"})
+		return
+	}
+
+	s.LogAuditEvent(user.ID, "mfa_enabled", "authentication", "Enabled MFA", c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "MFA enabled",
+		"backup_codes": codes,
+	})
+}
+
+// RegenerateBackupCodes replaces the current user's backup codes
+func (s *UserManagementService) RegenerateBackupCodes(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := CurrentUser(c)
+	codes, err := s.mfa.RegenerateBackupCodes(c.Request.Context(), user.ID, req.Code)
+	switch {
+	case errors.Is(err, ErrMFANotEnrolled):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, ErrMFACodeInvalid):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid MFA code"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate backup codes"})
+		return
+	}
+
+	s.LogAuditEvent(user.ID, "mfa_backup_codes_regenerated", "authentication", "Regenerated MFA backup codes", c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{"backup_codes": codes})
+}
+
+// DisableMFA turns off MFA for the current user unless their role requires it
+func (s *UserManagementService) DisableMFA(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	user := CurrentUser(c)
+
+	required, err := s.mfa.Required(ctx, user.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check MFA policy"})
+		return
+	}
+	if required {
+		c.JSON(http.StatusForbidden, gin.H{"error": "MFA is required for your role"})
+		return
+	}
+
+	enrollment, err := s.mfa.enrollment(ctx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check MFA"})
+		return
+	}
+	if enrollment == nil || !enrollment.Enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": ErrMFANotEnrolled.Error()})
+		return
+	}
+	if _, _, err := s.mfa.Verify(ctx, enrollment, req.Code); err != nil {
+		if errors.Is(err, ErrMFACodeInvalid) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid MFA code"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify MFA code"})
+		return
+	}
+
+	if _, err := s.mfa.Disable(ctx, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable MFA"})
+		return
+	}
+
+	s.LogAuditEvent(user.ID, "mfa_disabled", "authentication", "Disabled MFA", c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{"message": "MFA disabled"})
+}
+
+// ResetUserMFA removes a user's MFA enrollment, for users who lost their device
+func (s *UserManagementService) ResetUserMFA(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return
+	}
+
+	var user User
+	if err := s.db.WithContext(c.Request.Context()).First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	removed, err := s.mfa.Disable(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset MFA"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User has no MFA enrollment"})
+		return
+	}
+
+	currentUserID := s.GetUserIDFromContext(c)
+	s.LogAuditEvent(currentUserID, "mfa_reset", "user_management",
+		fmt.Sprintf("Reset MFA for user: %s", user.Username), c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{"message": "MFA reset"})
+}
+
+// ListMFAPolicies returns whether each role requires MFA
+func (s *UserManagementService) ListMFAPolicies(c *gin.Context) {
+	policies, err := s.mfa.ListPolicies(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list MFA policies"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"policies": policies})
+}
+
+// UpdateMFAPolicy requires or stops requiring MFA for a role
+func (s *UserManagementService) UpdateMFAPolicy(c *gin.Context) {
+	role := c.Param("role")
+	if !validRoles[role] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role"})
+		return
+	}
+
+	var req MFAPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	currentUserID := s.GetUserIDFromContext(c)
+	policy, err := s.mfa.SetPolicy(c.Request.Context(), role, *req.Required, currentUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update MFA policy"})
+		return
+	}
+
+	s.LogAuditEvent(currentUserID, "update_mfa_policy", "user_management",
+		fmt.Sprintf("Set MFA required=%t for role %s", policy.Required, role), c.ClientIP())
+
+	c.JSON(http.StatusOK, policy)
+}
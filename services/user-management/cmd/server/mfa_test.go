@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// mfaTestDB adds MFA enrollments and backup codes to the session test database
+func mfaTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := sessionTestDB(t)
+	require.NoError(t, db.AutoMigrate(&UserMFA{}, &MFABackupCode{}))
+	return db
+}
+
+// currentStep returns the TOTP time step authenticator apps are showing now
+func currentStep() int64 {
+	return time.Now().Unix() / int64(totpPeriod/time.Second)
+}
+
+// enrollMFA enrolls and confirms a user, returning the TOTP secret and the
+// backup codes
+func enrollMFA(t *testing.T, m *MFAService, user *User) ([]byte, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	encoded, uri, err := m.Enroll(ctx, user)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/"), uri)
+
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(encoded)
+	require.NoError(t, err)
+
+	codes, err := m.Confirm(ctx, user.ID, totpCode(secret, currentStep()))
+	require.NoError(t, err)
+	return secret, codes
+}
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B SHA-1 vectors, truncated to six digits
+	secret := []byte("12345678901234567890")
+	for _, tt := range []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	} {
+		assert.Equal(t, tt.want, totpCode(secret, tt.unix/30), "time %d", tt.unix)
+	}
+}
+
+func TestMFAService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("enrollment needs a valid code to take effect", func(t *testing.T) {
+		m := NewMFAService(mfaTestDB(t), "AegisShield", []byte("test-key"))
+		user := &User{ID: 1, Username: "alice"}
+
+		encoded, _, err := m.Enroll(ctx, user)
+		require.NoError(t, err)
+		secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(encoded)
+		require.NoError(t, err)
+
+		_, err = m.Confirm(ctx, user.ID, totpCode(secret, currentStep()-5))
+		assert.ErrorIs(t, err, ErrMFACodeInvalid)
+		enrollment, err := m.enrollment(ctx, user.ID)
+		require.NoError(t, err)
+		assert.False(t, enrollment.Enabled)
+
+		codes, err := m.Confirm(ctx, user.ID, totpCode(secret, currentStep()))
+		require.NoError(t, err)
+		assert.Len(t, codes, backupCodeCount)
+
+		_, _, err = m.Enroll(ctx, user)
+		assert.ErrorIs(t, err, ErrMFAAlreadyEnabled)
+	})
+
+	t.Run("TOTP codes cannot be replayed", func(t *testing.T) {
+		m := NewMFAService(mfaTestDB(t), "AegisShield", []byte("test-key"))
+		user := &User{ID: 2, Username: "bob"}
+		secret, _ := enrollMFA(t, m, user)
+
+		enrollment, err := m.enrollment(ctx, user.ID)
+		require.NoError(t, err)
+
+		// The code used to confirm is spent; the next one is within the skew
+		_, _, err = m.Verify(ctx, enrollment, totpCode(secret, enrollment.LastUsedStep))
+		assert.ErrorIs(t, err, ErrMFACodeInvalid)
+
+		next := totpCode(secret, enrollment.LastUsedStep+1)
+		method, remaining, err := m.Verify(ctx, enrollment, next)
+		require.NoError(t, err)
+		assert.Equal(t, mfaMethodTOTP, method)
+		assert.Equal(t, backupCodeCount, remaining)
+
+		_, _, err = m.Verify(ctx, enrollment, next)
+		assert.ErrorIs(t, err, ErrMFACodeInvalid)
+	})
+
+	t.Run("codes outside the skew are rejected", func(t *testing.T) {
+		m := NewMFAService(mfaTestDB(t), "AegisShield", []byte("test-key"))
+		user := &User{ID: 3, Username: "carol"}
+		secret, _ := enrollMFA(t, m, user)
+
+		enrollment, err := m.enrollment(ctx, user.ID)
+		require.NoError(t, err)
+		now := time.Now()
+		step := now.Unix() / int64(totpPeriod/time.Second)
+
+		_, err = m.checkTOTP(enrollment, totpCode(secret, step+totpSkewSteps+1), now)
+		assert.ErrorIs(t, err, ErrMFACodeInvalid)
+	})
+
+	t.Run("backup codes work once", func(t *testing.T) {
+		m := NewMFAService(mfaTestDB(t), "AegisShield", []byte("test-key"))
+		user := &User{ID: 4, Username: "dave"}
+		_, codes := enrollMFA(t, m, user)
+
+		enrollment, err := m.enrollment(ctx, user.ID)
+		require.NoError(t, err)
+
+		// Case and separators are ignored, as users retype them by hand
+		typed := strings.ToUpper(strings.ReplaceAll(codes[0], "-", " "))
+		method, remaining, err := m.Verify(ctx, enrollment, typed)
+		require.NoError(t, err)
+		assert.Equal(t, mfaMethodBackupCode, method)
+		assert.Equal(t, backupCodeCount-1, remaining)
+
+		_, _, err = m.Verify(ctx, enrollment, codes[0])
+		assert.ErrorIs(t, err, ErrMFACodeInvalid)
+
+		_, _, err = m.Verify(ctx, enrollment, "aaaaa-bbbbb")
+		assert.ErrorIs(t, err, ErrMFACodeInvalid)
+	})
+
+	t.Run("regenerating backup codes discards the old ones", func(t *testing.T) {
+		m := NewMFAService(mfaTestDB(t), "AegisShield", []byte("test-key"))
+		user := &User{ID: 5, Username: "erin"}
+		secret, old := enrollMFA(t, m, user)
+
+		enrollment, err := m.enrollment(ctx, user.ID)
+		require.NoError(t, err)
+
+		_, err = m.RegenerateBackupCodes(ctx, user.ID, old[0])
+		assert.ErrorIs(t, err, ErrMFACodeInvalid, "a backup code cannot stand in for TOTP")
+
+		fresh, err := m.RegenerateBackupCodes(ctx, user.ID, totpCode(secret, enrollment.LastUsedStep+1))
+		require.NoError(t, err)
+		assert.Len(t, fresh, backupCodeCount)
+
+		_, _, err = m.Verify(ctx, enrollment, old[1])
+		assert.ErrorIs(t, err, ErrMFACodeInvalid)
+		_, remaining, err := m.Verify(ctx, enrollment, fresh[0])
+		require.NoError(t, err)
+		assert.Equal(t, backupCodeCount-1, remaining)
+	})
+}
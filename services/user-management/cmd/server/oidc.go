@@ -0,0 +1,672 @@
+package main
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// Authentication providers a user account can belong to
+const (
+	authProviderLocal = "local"
+	authProviderOIDC  = "oidc"
+)
+
+// unusablePasswordHash is stored for SSO users; it is not a bcrypt hash, so
+// no password ever matches it
+const unusablePasswordHash = "!sso"
+
+// ssoRolePrecedence orders the roles an IdP group can map to. A user in
+// several mapped groups gets the first matching role.
+var ssoRolePrecedence = []string{roleAdmin, "compliance", "investigator", "analyst"}
+
+// OIDCConfig configures single sign-on against an OpenID Connect provider
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+	GroupRoles   map[string]string // IdP group -> AegisShield role
+	DefaultRole  string            // role for users in no mapped group; empty denies them
+	StateTTL     time.Duration
+}
+
+// oidcConfigFromEnv reads OIDC_ISSUER_URL, OIDC_CLIENT_ID,
+// OIDC_CLIENT_SECRET, OIDC_REDIRECT_URL, OIDC_SCOPES, OIDC_GROUPS_CLAIM,
+// OIDC_GROUP_ROLES ("group=role,..."), OIDC_DEFAULT_ROLE and
+// OIDC_STATE_TTL. It returns nil, disabling SSO, when no issuer is set.
+func oidcConfigFromEnv() *OIDCConfig {
+	issuer := os.Getenv("OIDC_ISSUER_URL")
+	if issuer == "" {
+		return nil
+	}
+
+	cfg := &OIDCConfig{
+		IssuerURL:    strings.TrimRight(issuer, "/"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       []string{"openid", "profile", "email", "groups"},
+		GroupsClaim:  "groups",
+		GroupRoles:   make(map[string]string),
+		DefaultRole:  os.Getenv("OIDC_DEFAULT_ROLE"),
+		StateTTL:     10 * time.Minute,
+	}
+	if scopes := os.Getenv("OIDC_SCOPES"); scopes != "" {
+		cfg.Scopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+	}
+	if claim := os.Getenv("OIDC_GROUPS_CLAIM"); claim != "" {
+		cfg.GroupsClaim = claim
+	}
+	for _, pair := range strings.Split(os.Getenv("OIDC_GROUP_ROLES"), ",") {
+		group, role, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if group, role = strings.TrimSpace(group), strings.TrimSpace(role); group != "" && role != "" {
+			cfg.GroupRoles[group] = role
+		}
+	}
+	envDuration("OIDC_STATE_TTL", &cfg.StateTTL)
+
+	if cfg.ClientID == "" || cfg.RedirectURL == "" {
+		log.Fatal("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required when OIDC_ISSUER_URL is set")
+	}
+	return cfg
+}
+
+// OIDCLoginState is a pending authorization request. The state value is
+// only accepted once, within the state TTL.
+type OIDCLoginState struct {
+	State        string    `gorm:"primaryKey"`
+	Nonce        string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"not null"`
+	ExpiresAt    time.Time `gorm:"index"`
+	CreatedAt    time.Time
+}
+
+// OIDCIdentity links an IdP subject to the local account it signs in as
+type OIDCIdentity struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Issuer    string     `json:"issuer" gorm:"not null;uniqueIndex:idx_oidc_identity_subject"`
+	Subject   string     `json:"subject" gorm:"not null;uniqueIndex:idx_oidc_identity_subject"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Email     string     `json:"email"`
+	Groups    string     `json:"groups"` // comma separated groups from the last login
+	LastLogin *time.Time `json:"last_login"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// OIDCClaims are the verified ID token claims used to sign a user in
+type OIDCClaims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	GivenName         string
+	FamilyName        string
+	Groups            []string
+}
+
+// oidcDiscovery is the subset of the provider metadata the login flow needs
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCProvider runs the authorization code flow (with PKCE) against the IdP
+// and verifies the ID tokens it returns
+type OIDCProvider struct {
+	cfg    *OIDCConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	keys          map[string]*rsa.PublicKey
+	keysFetchedAt time.Time
+}
+
+// jwksRefreshInterval limits how often an unknown key id refetches the JWKS
+const jwksRefreshInterval = time.Minute
+
+// NewOIDCProvider creates an OIDC provider. Provider metadata is discovered
+// on first use.
+func NewOIDCProvider(cfg *OIDCConfig) *OIDCProvider {
+	return &OIDCProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   make(map[string]*rsa.PublicKey),
+	}
+}
+
+// AuthCodeURL returns the IdP authorization URL for a new login
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	discovery, err := p.metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange trades an authorization code for the ID token
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	discovery, err := p.metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return "", errors.New("token response has no id_token")
+	}
+	return tokens.IDToken, nil
+}
+
+// VerifyIDToken checks the ID token signature against the IdP keys, its
+// issuer, audience, expiry and the nonce of the login it answers
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawToken, nonce string) (*OIDCClaims, error) {
+	discovery, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, discovery.JWKSURI, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, errors.New("invalid ID token: nonce mismatch")
+	}
+	// Tokens issued to several audiences must name this client as the party
+	// they were authorized for
+	if azp, ok := claims["azp"].(string); ok && azp != p.cfg.ClientID {
+		return nil, errors.New("invalid ID token: authorized party mismatch")
+	}
+
+	result := &OIDCClaims{
+		Subject:           stringClaim(claims, "sub"),
+		Email:             stringClaim(claims, "email"),
+		PreferredUsername: stringClaim(claims, "preferred_username"),
+		GivenName:         stringClaim(claims, "given_name"),
+		FamilyName:        stringClaim(claims, "family_name"),
+		Groups:            groupsClaim(claims[p.cfg.GroupsClaim]),
+	}
+	result.EmailVerified, _ = claims["email_verified"].(bool)
+	if result.Subject == "" {
+		return nil, errors.New("invalid ID token: missing subject")
+	}
+	return result, nil
+}
+
+// MapRole returns the role for a user's IdP groups, or the default role
+// when none of them is mapped
+func (p *OIDCProvider) MapRole(groups []string) string {
+	mapped := make(map[string]bool)
+	for _, group := range groups {
+		if role, ok := p.cfg.GroupRoles[group]; ok {
+			mapped[role] = true
+		}
+	}
+	for _, role := range ssoRolePrecedence {
+		if mapped[role] {
+			return role
+		}
+	}
+	return p.cfg.DefaultRole
+}
+
+// metadata discovers and caches the provider configuration
+func (p *OIDCProvider) metadata(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := p.getJSON(ctx, p.cfg.IssuerURL+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if strings.TrimRight(discovery.Issuer, "/") != p.cfg.IssuerURL {
+		return nil, fmt.Errorf("OIDC discovery returned issuer %q, expected %q", discovery.Issuer, p.cfg.IssuerURL)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document is missing endpoints")
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+// key returns the signing key with the given id, refetching the key set
+// when the IdP has rotated to a key not seen yet
+func (p *OIDCProvider) key(ctx context.Context, jwksURI, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set jsonWebKeySet
+	if err := p.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	p.keys = set.rsaKeys()
+	p.keysFetchedAt = time.Now()
+
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, target string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", target, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+// jsonWebKeySet is a JWKS document (RFC 7517)
+type jsonWebKeySet struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// rsaKeys returns the RSA signing keys of the set by key id
+func (s jsonWebKeySet) rsaKeys() map[string]*rsa.PublicKey {
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range s.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// groupsClaim accepts groups as a JSON array or a single string
+func groupsClaim(value interface{}) []string {
+	switch groups := value.(type) {
+	case []interface{}:
+		result := make([]string, 0, len(groups))
+		for _, group := range groups {
+			if name, ok := group.(string); ok && name != "" {
+				result = append(result, name)
+			}
+		}
+		return result
+	case string:
+		if groups != "" {
+			return []string{groups}
+		}
+	}
+	return nil
+}
+
+// beginOIDCLogin records a new login attempt and returns its state, nonce
+// and PKCE verifier
+func (s *UserManagementService) beginOIDCLogin(ctx context.Context) (*OIDCLoginState, error) {
+	state, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomToken(48)
+	if err != nil {
+		return nil, err
+	}
+
+	login := &OIDCLoginState{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(s.oidc.cfg.StateTTL),
+	}
+
+	db := s.db.WithContext(ctx)
+	// Abandoned logins are cleared as new ones start
+	db.Where("expires_at < ?", time.Now()).Delete(&OIDCLoginState{})
+	if err := db.Create(login).Error; err != nil {
+		return nil, fmt.Errorf("failed to save login state: %w", err)
+	}
+	return login, nil
+}
+
+// claimOIDCLogin consumes a pending login so its state cannot be replayed
+func (s *UserManagementService) claimOIDCLogin(ctx context.Context, state string) (*OIDCLoginState, error) {
+	var login OIDCLoginState
+	db := s.db.WithContext(ctx)
+	if err := db.Where("state = ? AND expires_at > ?", state, time.Now()).First(&login).Error; err != nil {
+		return nil, err
+	}
+	result := db.Where("state = ?", state).Delete(&OIDCLoginState{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &login, nil
+}
+
+// resolveSSOUser finds or provisions the account for an IdP identity and
+// syncs its role from the IdP groups. It also returns the role the account
+// had before when the sync changed it.
+func (s *UserManagementService) resolveSSOUser(ctx context.Context, claims *OIDCClaims, role string) (*User, string, error) {
+	issuer := s.oidc.cfg.IssuerURL
+	now := time.Now()
+	var user User
+	var previousRole string
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var identity OIDCIdentity
+		err := tx.Where("issuer = ? AND subject = ?", issuer, claims.Subject).First(&identity).Error
+		switch {
+		case err == nil:
+			if err := tx.Preload("Permissions").First(&user, identity.UserID).Error; err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// Existing accounts are only linked by a verified email address
+			linked := false
+			if claims.Email != "" && claims.EmailVerified {
+				err := tx.Preload("Permissions").Where("email = ?", claims.Email).First(&user).Error
+				if err == nil {
+					linked = true
+				} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+					return err
+				}
+			}
+			if !linked {
+				if claims.Email == "" {
+					return errors.New("the identity provider did not return an email address")
+				}
+				username := claims.PreferredUsername
+				if username == "" {
+					username = claims.Email
+				}
+				user = User{
+					Username:     username,
+					Email:        claims.Email,
+					PasswordHash: unusablePasswordHash,
+					FirstName:    claims.GivenName,
+					LastName:     claims.FamilyName,
+					Role:         role,
+					AuthProvider: authProviderOIDC,
+					IsActive:     true,
+				}
+				if err := tx.Create(&user).Error; err != nil {
+					return fmt.Errorf("failed to provision user: %w", err)
+				}
+				if err := s.recordUserChange(tx, nil, user.ID, 0); err != nil {
+					return err
+				}
+			}
+			identity = OIDCIdentity{Issuer: issuer, Subject: claims.Subject, UserID: user.ID}
+		default:
+			return err
+		}
+
+		identity.Email = claims.Email
+		identity.Groups = strings.Join(claims.Groups, ",")
+		identity.LastLogin = &now
+		if err := tx.Save(&identity).Error; err != nil {
+			return err
+		}
+
+		// The IdP is the source of truth for the role of SSO accounts, and a
+		// linked account stops accepting its local password
+		if user.Role != role || user.AuthProvider != authProviderOIDC {
+			before := snapshotUser(&user)
+			previousRole = user.Role
+			user.Role = role
+			user.AuthProvider = authProviderOIDC
+			if err := tx.Model(&user).Updates(map[string]interface{}{
+				"role":          role,
+				"auth_provider": authProviderOIDC,
+				"password_hash": unusablePasswordHash,
+			}).Error; err != nil {
+				return err
+			}
+			return s.recordUserChange(tx, before, user.ID, 0)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if previousRole == role {
+		previousRole = ""
+	}
+	return &user, previousRole, nil
+}
+
+// OIDCLogin redirects the browser to the identity provider
+func (s *UserManagementService) OIDCLogin(c *gin.Context) {
+	if s.oidc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	login, err := s.beginOIDCLogin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start single sign-on"})
+		return
+	}
+
+	target, err := s.oidc.AuthCodeURL(c.Request.Context(), login.State, login.Nonce, login.CodeVerifier)
+	if err != nil {
+		log.Printf("OIDC login failed: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider unavailable"})
+		return
+	}
+
+	c.Redirect(http.StatusFound, target)
+}
+
+// OIDCCallback completes the authorization code flow and issues a session
+func (s *UserManagementService) OIDCCallback(c *gin.Context) {
+	if s.oidc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+	ctx := c.Request.Context()
+
+	if idpError := c.Query("error"); idpError != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Identity provider rejected the login", "reason": idpError})
+		return
+	}
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code and state are required"})
+		return
+	}
+
+	login, err := s.claimOIDCLogin(ctx, state)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired login state"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load login state"})
+		return
+	}
+
+	rawToken, err := s.oidc.Exchange(ctx, code, login.CodeVerifier)
+	if err != nil {
+		log.Printf("OIDC code exchange failed: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to exchange authorization code"})
+		return
+	}
+	claims, err := s.oidc.VerifyIDToken(ctx, rawToken, login.Nonce)
+	if err != nil {
+		log.Printf("OIDC token verification failed: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid ID token"})
+		return
+	}
+
+	role := s.oidc.MapRole(claims.Groups)
+	if role == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No AegisShield role is mapped to your groups"})
+		return
+	}
+
+	user, previousRole, err := s.resolveSSOUser(ctx, claims, role)
+	if err != nil {
+		log.Printf("OIDC user provisioning failed for %q: %v", claims.Subject, err)
+		c.JSON(http.StatusConflict, gin.H{"error": "Failed to sign in the SSO account"})
+		return
+	}
+	if previousRole != "" {
+		s.LogAuditEvent(user.ID, "sso_role_sync", "users",
+			fmt.Sprintf("Role changed from %s to %s by IdP groups", previousRole, role), c.ClientIP())
+	}
+	if !user.IsActive {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is deactivated"})
+		return
+	}
+
+	response, _, err := s.issueSession(c, user, "")
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	now := time.Now()
+	user.LastLogin = &now
+	s.db.Model(user).Update("last_login", now)
+
+	s.LogAuditEvent(user.ID, "sso_login", "authentication",
+		fmt.Sprintf("User logged in through %s as %s", s.oidc.cfg.IssuerURL, role), c.ClientIP())
+
+	response.User.PasswordHash = ""
+	response.User.LastLogin = user.LastLogin
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Pagination is a validated page request: page and limit from the query
+// string, and sort, a comma-separated list of fields each optionally
+// prefixed with "-" for descending order
+type Pagination struct {
+	Page  int
+	Limit int
+	order []string // resolved ORDER BY terms
+}
+
+// PageOptions describes how a listing can be paged and sorted
+type PageOptions struct {
+	DefaultLimit int
+	MaxLimit     int
+	// SortFields maps the names clients may sort by to their columns
+	SortFields  map[string]string
+	DefaultSort string
+	// TieBreaker is appended to every ordering so pages do not overlap
+	TieBreaker string
+}
+
+// parsePagination reads page, limit and sort from the request. A limit above
+// the listing's maximum is lowered to it; the response reports the limit
+// applied.
+func parsePagination(c *gin.Context, opts PageOptions) (Pagination, error) {
+	p := Pagination{Page: 1, Limit: opts.DefaultLimit}
+
+	if raw := c.Query("page"); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return p, fmt.Errorf("invalid page %q, expected a positive integer", raw)
+		}
+		p.Page = page
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return p, fmt.Errorf("invalid limit %q, expected a positive integer", raw)
+		}
+		if limit > opts.MaxLimit {
+			limit = opts.MaxLimit
+		}
+		p.Limit = limit
+	}
+
+	sort := c.DefaultQuery("sort", opts.DefaultSort)
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		direction := "ASC"
+		if strings.HasPrefix(field, "-") {
+			direction = "DESC"
+			field = field[1:]
+		}
+		column, ok := opts.SortFields[field]
+		if !ok {
+			return p, fmt.Errorf("cannot sort by %q", field)
+		}
+		p.order = append(p.order, column+" "+direction)
+	}
+	if opts.TieBreaker != "" {
+		p.order = append(p.order, opts.TieBreaker)
+	}
+
+	return p, nil
+}
+
+// Apply orders the query and restricts it to the requested page
+func (p Pagination) Apply(query *gorm.DB) *gorm.DB {
+	for _, term := range p.order {
+		query = query.Order(term)
+	}
+	return query.Offset((p.Page - 1) * p.Limit).Limit(p.Limit)
+}
+
+// Respond writes a page of items under key with the total count, and the
+// X-Total-Count and Link headers
+func (p Pagination) Respond(c *gin.Context, key string, items interface{}, total int64) {
+	totalPages := int((total + int64(p.Limit) - 1) / int64(p.Limit))
+
+	links := []string{p.link(c, 1, "first")}
+	if p.Page > 1 {
+		links = append(links, p.link(c, p.Page-1, "prev"))
+	}
+	if p.Page < totalPages {
+		links = append(links, p.link(c, p.Page+1, "next"))
+	}
+	if totalPages > 0 {
+		links = append(links, p.link(c, totalPages, "last"))
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.Header("Link", strings.Join(links, ", "))
+	c.JSON(http.StatusOK, gin.H{
+		key:           items,
+		"total":       total,
+		"page":        p.Page,
+		"limit":       p.Limit,
+		"total_pages": totalPages,
+	})
+}
+
+// link renders an RFC 8288 link to another page of the same listing
+func (p Pagination) link(c *gin.Context, page int, rel string) string {
+	query := c.Request.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("limit", strconv.Itoa(p.Limit))
+	return fmt.Sprintf("<%s?%s>; rel=%q", c.Request.URL.Path, query.Encode(), rel)
+}
+
+var userPageOptions = PageOptions{
+	DefaultLimit: 50,
+	MaxLimit:     500,
+	SortFields: map[string]string{
+		"id":         "id",
+		"username":   "username",
+		"email":      "email",
+		"role":       "role",
+		"department": "department",
+		"created_at": "created_at",
+		"last_login": "last_login",
+	},
+	DefaultSort: "id",
+	TieBreaker:  "id ASC",
+}
+
+var permissionPageOptions = PageOptions{
+	DefaultLimit: 100,
+	MaxLimit:     1000,
+	SortFields: map[string]string{
+		"id":       "id",
+		"name":     "name",
+		"resource": "resource",
+		"action":   "action",
+	},
+	DefaultSort: "id",
+	TieBreaker:  "id ASC",
+}
+
+var auditLogPageOptions = PageOptions{
+	DefaultLimit: 100,
+	MaxLimit:     1000,
+	SortFields: map[string]string{
+		"timestamp": "timestamp",
+		"action":    "action",
+		"resource":  "resource",
+		"user_id":   "user_id",
+	},
+	DefaultSort: "-timestamp",
+	TieBreaker:  "id DESC",
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func paginationContext(target string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	return c, recorder
+}
+
+func TestParsePagination(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		c, _ := paginationContext("/audit-logs")
+		p, err := parsePagination(c, auditLogPageOptions)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, p.Page)
+		assert.Equal(t, auditLogPageOptions.DefaultLimit, p.Limit)
+		assert.Equal(t, []string{"timestamp DESC", "id DESC"}, p.order)
+	})
+
+	t.Run("clamps limits above the maximum", func(t *testing.T) {
+		// The API gateway's audit aggregator asks for up to AUDIT_MAX_RESULTS
+		c, _ := paginationContext("/audit-logs?limit=5000")
+		p, err := parsePagination(c, auditLogPageOptions)
+		require.NoError(t, err)
+
+		assert.Equal(t, auditLogPageOptions.MaxLimit, p.Limit)
+	})
+
+	t.Run("sorts by allowed fields", func(t *testing.T) {
+		c, _ := paginationContext("/users?page=3&limit=20&sort=role,-last_login")
+		p, err := parsePagination(c, userPageOptions)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, p.Page)
+		assert.Equal(t, 20, p.Limit)
+		assert.Equal(t, []string{"role ASC", "last_login DESC", "id ASC"}, p.order)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		for _, query := range []string{"page=0", "page=first", "limit=0", "limit=-5", "limit=many", "sort=password_hash"} {
+			c, _ := paginationContext("/users?" + query)
+			_, err := parsePagination(c, userPageOptions)
+			assert.Error(t, err, query)
+		}
+	})
+}
+
+func TestPaginationRespond(t *testing.T) {
+	c, recorder := paginationContext("/users?role=analyst&page=2&limit=10")
+	p, err := parsePagination(c, userPageOptions)
+	require.NoError(t, err)
+
+	p.Respond(c, "users", []string{}, 35)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "35", recorder.Header().Get("X-Total-Count"))
+	assert.Equal(t, `</users?limit=10&page=1&role=analyst>; rel="first", `+
+		`</users?limit=10&page=1&role=analyst>; rel="prev", `+
+		`</users?limit=10&page=3&role=analyst>; rel="next", `+
+		`</users?limit=10&page=4&role=analyst>; rel="last"`, recorder.Header().Get("Link"))
+	assert.JSONEq(t, `{"users": [], "total": 35, "page": 2, "limit": 10, "total_pages": 4}`, recorder.Body.String())
+}
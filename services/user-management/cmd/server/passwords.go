@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// passwordRoutePath is the only route users with a pending password change
+// can reach besides the MFA routes
+const passwordRoutePath = "/users/:id/password"
+
+// PasswordHistory keeps a previous password hash of a user so recent
+// passwords cannot be reused
+type PasswordHistory struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"not null;index"`
+	PasswordHash string    `json:"-" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PasswordPolicy configures the rules new passwords must meet
+type PasswordPolicy struct {
+	MinLength      int
+	MaxLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSymbol  bool
+	HistorySize    int           // previous passwords that cannot be reused
+	MaxAge         time.Duration // 0 disables expiry
+	DictionaryFile string        // extra forbidden words, one per line
+}
+
+// defaultPasswordPolicy requires 12 characters from all four character
+// classes, remembers the last 5 passwords and expires them after 90 days
+var defaultPasswordPolicy = PasswordPolicy{
+	MinLength:     12,
+	MaxLength:     72, // bcrypt ignores anything longer
+	RequireUpper:  true,
+	RequireLower:  true,
+	RequireDigit:  true,
+	RequireSymbol: true,
+	HistorySize:   5,
+	MaxAge:        90 * 24 * time.Hour,
+}
+
+// commonPasswords are rejected even when they meet the complexity rules,
+// after stripping the digits and symbols usually added to them
+var commonPasswords = []string{
+	"password", "passw0rd", "letmein", "welcome", "qwerty", "qwertyuiop",
+	"abc123", "123456", "12345678", "iloveyou", "admin", "administrator",
+	"monkey", "dragon", "football", "baseball", "sunshine", "princess",
+	"trustno1", "changeme", "secret", "master", "superman", "starwars",
+	"aegisshield", "aegis", "summer", "winter", "spring", "autumn",
+}
+
+// passwordPolicyFromEnv reads PASSWORD_MIN_LENGTH, PASSWORD_HISTORY,
+// PASSWORD_MAX_AGE ("0" disables expiry), PASSWORD_REQUIRE_UPPER,
+// PASSWORD_REQUIRE_LOWER, PASSWORD_REQUIRE_DIGIT, PASSWORD_REQUIRE_SYMBOL
+// and PASSWORD_DICTIONARY_FILE, falling back to the defaults for unset or
+// invalid values
+func passwordPolicyFromEnv() PasswordPolicy {
+	policy := defaultPasswordPolicy
+	envInt("PASSWORD_MIN_LENGTH", &policy.MinLength)
+	envInt("PASSWORD_HISTORY", &policy.HistorySize)
+	if os.Getenv("PASSWORD_MAX_AGE") == "0" {
+		policy.MaxAge = 0
+	} else {
+		envDuration("PASSWORD_MAX_AGE", &policy.MaxAge)
+	}
+	envBool("PASSWORD_REQUIRE_UPPER", &policy.RequireUpper)
+	envBool("PASSWORD_REQUIRE_LOWER", &policy.RequireLower)
+	envBool("PASSWORD_REQUIRE_DIGIT", &policy.RequireDigit)
+	envBool("PASSWORD_REQUIRE_SYMBOL", &policy.RequireSymbol)
+	policy.DictionaryFile = os.Getenv("PASSWORD_DICTIONARY_FILE")
+	return policy
+}
+
+// PolicyViolationError lists every rule a password failed
+type PolicyViolationError struct {
+	Violations []string
+}
+
+func (e *PolicyViolationError) Error() string {
+	return "password does not meet the policy: " + strings.Join(e.Violations, "; ")
+}
+
+// ErrPasswordReused is returned for a password found in the user's history
+var ErrPasswordReused = errors.New("password was used recently")
+
+// PasswordPolicyEngine checks new passwords and password age
+type PasswordPolicyEngine struct {
+	db         *gorm.DB
+	policy     PasswordPolicy
+	dictionary map[string]bool
+}
+
+// NewPasswordPolicyEngine creates a password policy engine, loading the
+// dictionary file on top of the built-in common passwords
+func NewPasswordPolicyEngine(db *gorm.DB, policy PasswordPolicy) *PasswordPolicyEngine {
+	dictionary := make(map[string]bool, len(commonPasswords))
+	for _, word := range commonPasswords {
+		dictionary[word] = true
+	}
+
+	if policy.DictionaryFile != "" {
+		words, err := loadDictionary(policy.DictionaryFile)
+		if err != nil {
+			log.Printf("Failed to load password dictionary %s: %v", policy.DictionaryFile, err)
+		}
+		for _, word := range words {
+			dictionary[word] = true
+		}
+	}
+
+	return &PasswordPolicyEngine{
+		db:         db,
+		policy:     policy,
+		dictionary: dictionary,
+	}
+}
+
+func loadDictionary(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if word := strings.ToLower(strings.TrimSpace(scanner.Text())); word != "" && !strings.HasPrefix(word, "#") {
+			words = append(words, word)
+		}
+	}
+	return words, scanner.Err()
+}
+
+// Validate checks a candidate password against the complexity and
+// dictionary rules. user may be a new account that is not saved yet.
+func (e *PasswordPolicyEngine) Validate(password string, user *User) error {
+	var violations []string
+	p := e.policy
+
+	length := len([]rune(password))
+	if length < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		violations = append(violations, fmt.Sprintf("must be at most %d bytes", p.MaxLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, "must contain a symbol")
+	}
+
+	if e.inDictionary(password) {
+		violations = append(violations, "is too common")
+	}
+	if user != nil && containsIdentity(password, user) {
+		violations = append(violations, "must not contain the username or email")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyViolationError{Violations: violations}
+	}
+	return nil
+}
+
+// inDictionary matches the password, and the password stripped of leading
+// and trailing digits and symbols, against the forbidden words
+func (e *PasswordPolicyEngine) inDictionary(password string) bool {
+	lowered := strings.ToLower(password)
+	if e.dictionary[lowered] {
+		return true
+	}
+	core := strings.TrimFunc(lowered, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	return core != "" && e.dictionary[core]
+}
+
+func containsIdentity(password string, user *User) bool {
+	lowered := strings.ToLower(password)
+	local, _, _ := strings.Cut(user.Email, "@")
+	for _, identity := range []string{user.Username, local} {
+		identity = strings.ToLower(identity)
+		if len(identity) >= 3 && strings.Contains(lowered, identity) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHistory rejects the current password and the last HistorySize ones
+func (e *PasswordPolicyEngine) CheckHistory(ctx context.Context, user *User, password string, matches func(password, hash string) bool) error {
+	if user.PasswordHash != "" && matches(password, user.PasswordHash) {
+		return ErrPasswordReused
+	}
+	if e.policy.HistorySize <= 0 {
+		return nil
+	}
+
+	var history []PasswordHistory
+	if err := e.db.WithContext(ctx).Where("user_id = ?", user.ID).
+		Order("created_at DESC").Limit(e.policy.HistorySize).Find(&history).Error; err != nil {
+		return fmt.Errorf("failed to load password history: %w", err)
+	}
+	for _, entry := range history {
+		if matches(password, entry.PasswordHash) {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// Record stores a replaced password hash and trims the history to the policy size
+func (e *PasswordPolicyEngine) Record(tx *gorm.DB, userID uint, oldHash string) error {
+	if e.policy.HistorySize <= 0 || oldHash == "" || oldHash == unusablePasswordHash {
+		return nil
+	}
+
+	if err := tx.Create(&PasswordHistory{UserID: userID, PasswordHash: oldHash}).Error; err != nil {
+		return fmt.Errorf("failed to record password history: %w", err)
+	}
+
+	var keep []uint
+	if err := tx.Model(&PasswordHistory{}).Where("user_id = ?", userID).
+		Order("created_at DESC").Limit(e.policy.HistorySize).Pluck("id", &keep).Error; err != nil {
+		return fmt.Errorf("failed to trim password history: %w", err)
+	}
+	return tx.Where("user_id = ? AND id NOT IN ?", userID, keep).Delete(&PasswordHistory{}).Error
+}
+
+// Expired reports whether the user's password is older than the maximum
+// age. Passwords changed before expiry tracking began are not expired.
+func (e *PasswordPolicyEngine) Expired(user *User, now time.Time) bool {
+	if e.policy.MaxAge <= 0 || user.PasswordChangedAt == nil {
+		return false
+	}
+	return now.Sub(*user.PasswordChangedAt) > e.policy.MaxAge
+}
+
+// ChangeRequired reports whether a local user has to set a new password
+// before using anything else
+func (e *PasswordPolicyEngine) ChangeRequired(user *User, now time.Time) bool {
+	if user.AuthProvider == authProviderOIDC {
+		return false
+	}
+	return user.MustChangePassword || e.Expired(user, now)
+}
+
+// rejectPassword answers a request whose new password failed the policy
+func rejectPassword(c *gin.Context, err error) {
+	var violation *PolicyViolationError
+	switch {
+	case errors.As(err, &violation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password does not meet the policy", "violations": violation.Violations})
+	case errors.Is(err, ErrPasswordReused):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password was used recently"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check password"})
+	}
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword    string `json:"current_password"` // required when changing your own password
+	NewPassword        string `json:"new_password" binding:"required"`
+	MustChangePassword *bool  `json:"must_change_password"` // admin resets default to true
+}
+
+// ChangePassword sets a user's password. Users change their own password
+// with the current one; admins can reset anyone's, which by default makes
+// the user choose a new password at the next login. Every session of the
+// user is ended, and users changing their own password get a new one.
+func (s *UserManagementService) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx := c.Request.Context()
+	caller := CurrentUser(c)
+
+	var user User
+	if err := s.db.WithContext(ctx).Preload("Permissions").First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	self := caller.ID == user.ID
+	if !self && caller.Role != roleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return
+	}
+	if user.AuthProvider == authProviderOIDC {
+		c.JSON(http.StatusConflict, gin.H{"error": "Single sign-on accounts have no local password"})
+		return
+	}
+	if self && !s.CheckPassword(req.CurrentPassword, user.PasswordHash) {
+		s.recordLoginFailure(c, user.Username, user.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	if err := s.passwords.Validate(req.NewPassword, &user); err != nil {
+		rejectPassword(c, err)
+		return
+	}
+	if err := s.passwords.CheckHistory(ctx, &user, req.NewPassword, s.CheckPassword); err != nil {
+		rejectPassword(c, err)
+		return
+	}
+
+	passwordHash, err := s.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	mustChange := !self
+	if !self && req.MustChangePassword != nil {
+		mustChange = *req.MustChangePassword
+	}
+	now := time.Now()
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.passwords.Record(tx, user.ID, user.PasswordHash); err != nil {
+			return err
+		}
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"password_hash":        passwordHash,
+			"password_changed_at":  now,
+			"must_change_password": mustChange,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+	user.PasswordHash = passwordHash
+	user.PasswordChangedAt = &now
+	user.MustChangePassword = mustChange
+
+	if _, err := s.refreshTokens.RevokeUser(ctx, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	if !self {
+		s.LogAuditEvent(caller.ID, "reset_password", "user_management",
+			fmt.Sprintf("Reset password of user: %s", user.Username), c.ClientIP())
+		c.JSON(http.StatusOK, gin.H{"message": "Password reset", "must_change_password": mustChange})
+		return
+	}
+
+	s.LogAuditEvent(user.ID, "change_password", "authentication", "User changed password", c.ClientIP())
+
+	response, _, err := s.issueSession(c, &user, "")
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+	response.User.PasswordHash = ""
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// passwordTestDB adds password history to the session test database
+func passwordTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := sessionTestDB(t)
+	require.NoError(t, db.AutoMigrate(&PasswordHistory{}))
+	return db
+}
+
+// plainHash stands in for bcrypt so history checks stay fast
+func plainHash(password string) string {
+	return "hash:" + password
+}
+
+func plainMatches(password, hash string) bool {
+	return plainHash(password) == hash
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	engine := NewPasswordPolicyEngine(nil, defaultPasswordPolicy)
+	user := &User{Username: "jsmith", Email: "john.smith@example.com"}
+
+	tests := []struct {
+		name       string
+		password   string
+		violations []string
+	}{
+		{"meets every rule", "Vivid-Harbor-42", nil},
+		{"too short", "Ab1!xyz", []string{"must be at least 12 characters"}},
+		{"missing character classes", "lowercaseonlypassword", []string{
+			"must contain an uppercase letter", "must contain a digit", "must contain a symbol",
+		}},
+		{"common password with decorations", "Password2024!", []string{"is too common"}},
+		{"contains the username", "Hello-jsmith-99", []string{"must not contain the username or email"}},
+		{"contains the email local part", "John.Smith#2024", []string{"must not contain the username or email"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := engine.Validate(tt.password, user)
+			if tt.violations == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var violation *PolicyViolationError
+			require.True(t, errors.As(err, &violation), "error %v", err)
+			assert.Equal(t, tt.violations, violation.Violations)
+		})
+	}
+}
+
+func TestPasswordHistory(t *testing.T) {
+	ctx := context.Background()
+	policy := defaultPasswordPolicy
+	policy.HistorySize = 2
+
+	db := passwordTestDB(t)
+	engine := NewPasswordPolicyEngine(db, policy)
+	user := &User{ID: 1, PasswordHash: plainHash("Current-Pass-4")}
+
+	for _, old := range []string{"Oldest-Pass-1", "Older-Pass-2", "Recent-Pass-3"} {
+		require.NoError(t, engine.Record(db, user.ID, plainHash(old)))
+	}
+
+	t.Run("history is trimmed to the policy size", func(t *testing.T) {
+		var count int64
+		require.NoError(t, db.Model(&PasswordHistory{}).Where("user_id = ?", user.ID).Count(&count).Error)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("recent passwords cannot be reused", func(t *testing.T) {
+		for _, reused := range []string{"Current-Pass-4", "Recent-Pass-3", "Older-Pass-2"} {
+			assert.ErrorIs(t, engine.CheckHistory(ctx, user, reused, plainMatches), ErrPasswordReused, reused)
+		}
+	})
+
+	t.Run("passwords beyond the history can be reused", func(t *testing.T) {
+		assert.NoError(t, engine.CheckHistory(ctx, user, "Oldest-Pass-1", plainMatches))
+		assert.NoError(t, engine.CheckHistory(ctx, user, "Brand-New-Pass-5", plainMatches))
+	})
+
+	t.Run("unusable hashes are not recorded", func(t *testing.T) {
+		require.NoError(t, engine.Record(db, 2, unusablePasswordHash))
+
+		var count int64
+		require.NoError(t, db.Model(&PasswordHistory{}).Where("user_id = ?", 2).Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
+
+func TestPasswordExpiry(t *testing.T) {
+	engine := NewPasswordPolicyEngine(nil, defaultPasswordPolicy)
+	now := time.Now()
+	changedAt := func(age time.Duration) *time.Time {
+		at := now.Add(-age)
+		return &at
+	}
+
+	tests := []struct {
+		name           string
+		user           User
+		expired        bool
+		changeRequired bool
+	}{
+		{"recently changed", User{PasswordChangedAt: changedAt(24 * time.Hour)}, false, false},
+		{"older than the maximum age", User{PasswordChangedAt: changedAt(91 * 24 * time.Hour)}, true, true},
+		{"changed before tracking began", User{}, false, false},
+		{"flagged by an admin", User{PasswordChangedAt: changedAt(time.Hour), MustChangePassword: true}, false, true},
+		{"single sign-on user", User{AuthProvider: authProviderOIDC, PasswordChangedAt: changedAt(365 * 24 * time.Hour)}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expired, engine.Expired(&tt.user, now))
+			assert.Equal(t, tt.changeRequired, engine.ChangeRequired(&tt.user, now))
+		})
+	}
+
+	t.Run("expiry can be disabled", func(t *testing.T) {
+		policy := defaultPasswordPolicy
+		policy.MaxAge = 0
+		user := &User{PasswordChangedAt: changedAt(365 * 24 * time.Hour)}
+		assert.False(t, NewPasswordPolicyEngine(nil, policy).ChangeRequired(user, now))
+	})
+}
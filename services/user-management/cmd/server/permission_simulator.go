@@ -0,0 +1,392 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"aegisshield/shared/rbac"
+)
+
+// PermissionSimulationRequest is a proposed change to the permissions a role
+// grants under the shared RBAC policy. Replace, when set, is the role's full
+// new permission list and Grant and Revoke are ignored.
+type PermissionSimulationRequest struct {
+	Grant           []string  `json:"grant"`
+	Revoke          []string  `json:"revoke"`
+	Replace         *[]string `json:"replace"`
+	IncludeInactive bool      `json:"include_inactive"`
+}
+
+// WorkItem is an open case or alert assigned to a user
+type WorkItem struct {
+	Type     string `json:"type"` // "investigation" or "alert"
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Resource string `json:"resource"` // RBAC resource needed to read it
+}
+
+// Work item types
+const (
+	WorkItemInvestigation = "investigation"
+	WorkItemAlert         = "alert"
+)
+
+// UserImpact is how a permission change affects one user
+type UserImpact struct {
+	UserID          uint            `json:"user_id"`
+	Username        string          `json:"username"`
+	Department      string          `json:"department"`
+	LostEndpoints   []rbac.Endpoint `json:"lost_endpoints"`
+	GainedEndpoints []rbac.Endpoint `json:"gained_endpoints"`
+	// InaccessibleWorkItems are open cases and alerts assigned to the user
+	// that they could no longer read
+	InaccessibleWorkItems []WorkItem `json:"inaccessible_work_items"`
+}
+
+// PermissionImpactReport is the diff report for a simulated role change
+type PermissionImpactReport struct {
+	Role               string          `json:"role"`
+	PermissionsAdded   []string        `json:"permissions_added"`
+	PermissionsRemoved []string        `json:"permissions_removed"`
+	LostEndpoints      []rbac.Endpoint `json:"lost_endpoints"`
+	GainedEndpoints    []rbac.Endpoint `json:"gained_endpoints"`
+	MatchedUsers       int             `json:"matched_users"`
+	AffectedUsers      []UserImpact    `json:"affected_users"`
+	InaccessibleItems  int             `json:"inaccessible_work_items"`
+	// Warnings list open case and alert lookups that failed, so an empty
+	// InaccessibleWorkItems is not mistaken for a clean result
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// WorkItemSource lists the open cases and alerts assigned to a user. Lookups
+// are made with the caller's token.
+type WorkItemSource interface {
+	OpenInvestigations(ctx context.Context, token, userID string) ([]WorkItem, error)
+	OpenAlerts(ctx context.Context, token, userID string) ([]WorkItem, error)
+}
+
+// openInvestigationStatuses are the case statuses still being worked
+var openInvestigationStatuses = []string{"open", "in_progress", "under_review"}
+
+// closedAlertStatuses are the alert statuses no one works any more
+var closedAlertStatuses = map[string]bool{
+	"resolved":              true,
+	"suppressed":            true,
+	"closed_false_positive": true,
+	"closed_confirmed":      true,
+}
+
+// workItemPageSize caps the work items fetched per user and service
+const workItemPageSize = 200
+
+// HTTPWorkItemSource reads assigned work from the investigation toolkit and
+// alerting engine REST APIs
+type HTTPWorkItemSource struct {
+	investigationURL string // empty skips case lookups
+	alertingURL      string // empty skips alert lookups
+	client           *http.Client
+}
+
+// workItemSourceFromEnv reads INVESTIGATION_SERVICE_URL and
+// ALERTING_SERVICE_URL. It returns nil, leaving work items out of
+// simulations, when neither is set.
+func workItemSourceFromEnv() WorkItemSource {
+	investigationURL := strings.TrimRight(os.Getenv("INVESTIGATION_SERVICE_URL"), "/")
+	alertingURL := strings.TrimRight(os.Getenv("ALERTING_SERVICE_URL"), "/")
+	if investigationURL == "" && alertingURL == "" {
+		return nil
+	}
+	return &HTTPWorkItemSource{
+		investigationURL: investigationURL,
+		alertingURL:      alertingURL,
+		client:           &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// OpenInvestigations lists open, in-progress and under-review cases
+// assigned to the user
+func (s *HTTPWorkItemSource) OpenInvestigations(ctx context.Context, token, userID string) ([]WorkItem, error) {
+	if s.investigationURL == "" {
+		return nil, nil
+	}
+
+	params := url.Values{
+		"assigned_to": {userID},
+		"status":      {strings.Join(openInvestigationStatuses, ",")},
+		"limit":       {strconv.Itoa(workItemPageSize)},
+	}
+	var page struct {
+		Data []struct {
+			ID     string `json:"id"`
+			Title  string `json:"title"`
+			Status string `json:"status"`
+		} `json:"data"`
+	}
+	if err := s.getJSON(ctx, s.investigationURL+"/api/v1/investigations?"+params.Encode(), token, &page); err != nil {
+		return nil, err
+	}
+
+	items := make([]WorkItem, 0, len(page.Data))
+	for _, investigation := range page.Data {
+		items = append(items, WorkItem{
+			Type:     WorkItemInvestigation,
+			ID:       investigation.ID,
+			Title:    investigation.Title,
+			Status:   investigation.Status,
+			Resource: "investigations",
+		})
+	}
+	return items, nil
+}
+
+// OpenAlerts lists alerts assigned to the user that are not yet closed
+func (s *HTTPWorkItemSource) OpenAlerts(ctx context.Context, token, userID string) ([]WorkItem, error) {
+	if s.alertingURL == "" {
+		return nil, nil
+	}
+
+	params := url.Values{
+		"assigned_to": {userID},
+		"limit":       {strconv.Itoa(workItemPageSize)},
+	}
+	var page struct {
+		Alerts []struct {
+			ID     string `json:"id"`
+			Title  string `json:"title"`
+			Status string `json:"status"`
+		} `json:"alerts"`
+	}
+	if err := s.getJSON(ctx, s.alertingURL+"/alerts?"+params.Encode(), token, &page); err != nil {
+		return nil, err
+	}
+
+	var items []WorkItem
+	for _, alert := range page.Alerts {
+		if closedAlertStatuses[alert.Status] {
+			continue
+		}
+		items = append(items, WorkItem{
+			Type:     WorkItemAlert,
+			ID:       alert.ID,
+			Title:    alert.Title,
+			Status:   alert.Status,
+			Resource: "alerts",
+		})
+	}
+	return items, nil
+}
+
+func (s *HTTPWorkItemSource) getJSON(ctx context.Context, target, token string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", req.URL.Path, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(out)
+}
+
+// proposedPolicy applies a simulation request to a copy of the policy and
+// returns it with the role's permission changes
+func proposedPolicy(current *rbac.Policy, role string, req PermissionSimulationRequest) (*rbac.Policy, []string, []string, error) {
+	before := permissionStrings(current.Permissions(role))
+
+	var after []string
+	if req.Replace != nil {
+		after = *req.Replace
+	} else {
+		revoked := make(map[string]bool, len(req.Revoke))
+		for _, value := range req.Revoke {
+			permission, err := rbac.ParsePermission(value)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			revoked[permission.String()] = true
+		}
+		for _, value := range before {
+			if !revoked[value] {
+				after = append(after, value)
+			}
+		}
+		after = append(after, req.Grant...)
+	}
+
+	proposed := current.Clone()
+	if err := proposed.Replace(role, after...); err != nil {
+		return nil, nil, nil, err
+	}
+
+	added, removed := diffStrings(before, permissionStrings(proposed.Permissions(role)))
+	return proposed, added, removed, nil
+}
+
+// endpointChanges lists the catalog endpoints the subject loses and gains
+// when moving from the current to the proposed policy
+func endpointChanges(current, proposed *rbac.Evaluator, subject *rbac.Subject, endpoints []rbac.Endpoint) (lost, gained []rbac.Endpoint) {
+	for _, endpoint := range endpoints {
+		before := current.Check(subject, endpoint.Resource, endpoint.Action).Allowed
+		after := proposed.Check(subject, endpoint.Resource, endpoint.Action).Allowed
+		switch {
+		case before && !after:
+			lost = append(lost, endpoint)
+		case !before && after:
+			gained = append(gained, endpoint)
+		}
+	}
+	return lost, gained
+}
+
+// SimulatePermissionChange reports who a change to a role's permissions
+// would affect without applying it: the endpoints each user with the role
+// would lose or gain, and the open cases and alerts assigned to them they
+// could no longer read
+func (s *UserManagementService) SimulatePermissionChange(c *gin.Context) {
+	role := c.Param("role")
+
+	var req PermissionSimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	proposed, added, removed, err := proposedPolicy(s.policy, role, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	users, err := s.selectUsers(UserSelector{Role: role, IncludeInactive: req.IncludeInactive})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load users", "details": err.Error()})
+		return
+	}
+
+	// Decisions are not cached; each simulation evaluates fresh
+	current := rbac.NewEvaluator(s.policy, 0, 0)
+	next := rbac.NewEvaluator(proposed, 0, 0)
+	endpoints := rbac.DefaultEndpoints()
+
+	report := PermissionImpactReport{
+		Role:               role,
+		PermissionsAdded:   added,
+		PermissionsRemoved: removed,
+		MatchedUsers:       len(users),
+		AffectedUsers:      []UserImpact{},
+	}
+	report.LostEndpoints, report.GainedEndpoints = endpointChanges(current, next,
+		&rbac.Subject{Roles: []string{role}}, endpoints)
+
+	token, _ := rbac.BearerToken(c.GetHeader("Authorization"))
+	for i := range users {
+		user := &users[i]
+		subject := userSubject(user)
+
+		lost, gained := endpointChanges(current, next, subject, endpoints)
+		if len(lost) == 0 && len(gained) == 0 {
+			continue
+		}
+
+		impact := UserImpact{
+			UserID:          user.ID,
+			Username:        user.Username,
+			Department:      user.Department,
+			LostEndpoints:   lost,
+			GainedEndpoints: gained,
+		}
+		if s.workItems != nil {
+			items, warnings := s.inaccessibleWorkItems(c.Request.Context(), token, user, subject, next)
+			impact.InaccessibleWorkItems = items
+			report.InaccessibleItems += len(items)
+			report.Warnings = append(report.Warnings, warnings...)
+		}
+		report.AffectedUsers = append(report.AffectedUsers, impact)
+	}
+
+	s.LogAuditEvent(s.GetUserIDFromContext(c), "simulate_permission_change", "user_management",
+		fmt.Sprintf("Simulated role %s change (+%d/-%d permissions) affecting %d of %d users",
+			role, len(added), len(removed), len(report.AffectedUsers), len(users)), c.ClientIP())
+
+	c.JSON(http.StatusOK, report)
+}
+
+// inaccessibleWorkItems returns the user's open cases and alerts they could
+// not read under the proposed policy. Failed lookups become warnings.
+func (s *UserManagementService) inaccessibleWorkItems(ctx context.Context, token string, user *User, subject *rbac.Subject, proposed *rbac.Evaluator) ([]WorkItem, []string) {
+	var items []WorkItem
+	var warnings []string
+
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	if !proposed.Check(subject, "investigations", rbac.ActionRead).Allowed {
+		investigations, err := s.workItems.OpenInvestigations(ctx, token, userID)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("open cases for %s: %v", user.Username, err))
+		}
+		items = append(items, investigations...)
+	}
+	if !proposed.Check(subject, "alerts", rbac.ActionRead).Allowed {
+		alerts, err := s.workItems.OpenAlerts(ctx, token, userID)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("open alerts for %s: %v", user.Username, err))
+		}
+		items = append(items, alerts...)
+	}
+	return items, warnings
+}
+
+func permissionStrings(permissions []rbac.Permission) []string {
+	values := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		values = append(values, permission.String())
+	}
+	return values
+}
+
+// diffStrings returns the values only in after and only in before, sorted
+func diffStrings(before, after []string) (added, removed []string) {
+	had := make(map[string]bool, len(before))
+	for _, value := range before {
+		had[value] = true
+	}
+	has := make(map[string]bool, len(after))
+	for _, value := range after {
+		if has[value] {
+			continue
+		}
+		has[value] = true
+		if !had[value] {
+			added = append(added, value)
+		}
+	}
+	for _, value := range before {
+		if !has[value] {
+			removed = append(removed, value)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
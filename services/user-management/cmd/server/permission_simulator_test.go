@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aegisshield/shared/rbac"
+)
+
+// stubWorkItems returns fixed open work per user ID and records lookups
+type stubWorkItems struct {
+	investigations map[string][]WorkItem
+	alerts         map[string][]WorkItem
+	err            error
+	lookups        []string
+}
+
+func (s *stubWorkItems) OpenInvestigations(ctx context.Context, token, userID string) ([]WorkItem, error) {
+	s.lookups = append(s.lookups, "investigations:"+userID)
+	return s.investigations[userID], s.err
+}
+
+func (s *stubWorkItems) OpenAlerts(ctx context.Context, token, userID string) ([]WorkItem, error) {
+	s.lookups = append(s.lookups, "alerts:"+userID)
+	return s.alerts[userID], s.err
+}
+
+// simulatorService returns the user event service under the default policy
+// with three analysts: alice, bob who also holds investigations:read
+// directly, and the inactive dave
+func simulatorService(t *testing.T) (*UserManagementService, *stubWorkItems, map[string]*User) {
+	t.Helper()
+
+	s, alice := userEventService(t)
+	workItems := &stubWorkItems{}
+	s.policy = rbac.DefaultPolicy()
+	s.workItems = workItems
+
+	direct := Permission{Name: "Read investigations", Resource: "investigations", Action: "read"}
+	require.NoError(t, s.db.Create(&direct).Error)
+	bob := &User{Username: "bob", Email: "bob@example.com", PasswordHash: "hash", Role: "analyst", IsActive: true, AuthProvider: authProviderLocal, Permissions: []Permission{direct}}
+	require.NoError(t, s.db.Create(bob).Error)
+	dave := &User{Username: "dave", Email: "dave@example.com", PasswordHash: "hash", Role: "analyst", IsActive: true, AuthProvider: authProviderLocal}
+	require.NoError(t, s.db.Create(dave).Error)
+	require.NoError(t, s.db.Model(dave).Update("is_active", false).Error)
+
+	return s, workItems, map[string]*User{"alice": alice, "bob": bob, "dave": dave}
+}
+
+func simulate(s *UserManagementService, role string, body interface{}) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	payload, _ := json.Marshal(body)
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/permissions/simulate/"+role, strings.NewReader(string(payload)))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "role", Value: role}}
+	c.Set(contextUserIDKey, uint(1))
+
+	s.SimulatePermissionChange(c)
+	return recorder
+}
+
+func endpointNames(endpoints []rbac.Endpoint) []string {
+	names := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		names[i] = endpoint.String()
+	}
+	return names
+}
+
+func TestProposedPolicy(t *testing.T) {
+	current := rbac.DefaultPolicy()
+	replace := []string{"alerts:read", "entities:read"}
+
+	for _, tt := range []struct {
+		name    string
+		req     PermissionSimulationRequest
+		added   []string
+		removed []string
+	}{
+		{"grant and revoke", PermissionSimulationRequest{Grant: []string{"sar:read"}, Revoke: []string{"alerts:write", "training:*"}}, []string{"sar:read"}, []string{"alerts:write", "training:*"}},
+		{"granting a held permission changes nothing", PermissionSimulationRequest{Grant: []string{"alerts:read"}}, nil, nil},
+		{"revoking a permission not held changes nothing", PermissionSimulationRequest{Revoke: []string{"sar:write"}}, nil, nil},
+		{"replace ignores grant and revoke", PermissionSimulationRequest{Replace: &replace, Grant: []string{"sar:read"}}, nil,
+			[]string{"alerts:write", "graph:read", "investigations:read", "notifications:read", "rules:read", "training:*", "watchlists:read"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			proposed, added, removed, err := proposedPolicy(current, "analyst", tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.added, added)
+			assert.Equal(t, tt.removed, removed)
+			assert.NotSame(t, current, proposed)
+		})
+	}
+
+	t.Run("invalid permissions are rejected", func(t *testing.T) {
+		_, _, _, err := proposedPolicy(current, "analyst", PermissionSimulationRequest{Grant: []string{"alerts"}})
+		assert.Error(t, err)
+		_, _, _, err = proposedPolicy(current, "analyst", PermissionSimulationRequest{Revoke: []string{"alerts"}})
+		assert.Error(t, err)
+	})
+
+	assert.Len(t, current.Permissions("analyst"), 9, "the current policy must not change")
+}
+
+func TestEndpointChanges(t *testing.T) {
+	current := rbac.DefaultPolicy()
+	proposed, _, _, err := proposedPolicy(current, "analyst", PermissionSimulationRequest{
+		Grant:  []string{"sar:read"},
+		Revoke: []string{"alerts:write"},
+	})
+	require.NoError(t, err)
+	before, after := rbac.NewEvaluator(current, 0, 0), rbac.NewEvaluator(proposed, 0, 0)
+
+	lost, gained := endpointChanges(before, after, &rbac.Subject{Roles: []string{"analyst"}}, rbac.DefaultEndpoints())
+	assert.Equal(t, []string{"alerting-engine PUT /alerts/{id}"}, endpointNames(lost))
+	assert.Equal(t, []string{"investigation-toolkit GET /api/v1/sar-filings"}, endpointNames(gained))
+
+	// A direct grant keeps what the role no longer gives
+	lost, _ = endpointChanges(before, after, &rbac.Subject{Roles: []string{"analyst"}, Permissions: []string{"alerts:write"}}, rbac.DefaultEndpoints())
+	assert.Empty(t, lost)
+
+	// Another role's grants are unaffected
+	lost, gained = endpointChanges(before, after, &rbac.Subject{Roles: []string{"investigator"}}, rbac.DefaultEndpoints())
+	assert.Empty(t, lost)
+	assert.Empty(t, gained)
+}
+
+func TestSimulatePermissionChange(t *testing.T) {
+	revokeInvestigations := PermissionSimulationRequest{Revoke: []string{"investigations:read"}}
+
+	t.Run("reports affected users and the open cases they would lose", func(t *testing.T) {
+		s, workItems, users := simulatorService(t)
+		aliceID := idParam(users["alice"].ID)
+		workItems.investigations = map[string][]WorkItem{
+			aliceID: {{Type: WorkItemInvestigation, ID: "case-7", Status: "in_progress", Resource: "investigations"}},
+		}
+
+		rec := simulate(s, "analyst", revokeInvestigations)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var report PermissionImpactReport
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, []string{"investigations:read"}, report.PermissionsRemoved)
+		assert.Equal(t, []string{"investigation-toolkit GET /api/v1/investigations"}, endpointNames(report.LostEndpoints))
+		assert.Equal(t, 2, report.MatchedUsers, "inactive users are left out")
+
+		// bob keeps access through his direct grant
+		require.Len(t, report.AffectedUsers, 1)
+		impact := report.AffectedUsers[0]
+		assert.Equal(t, "alice", impact.Username)
+		assert.Equal(t, []string{"investigation-toolkit GET /api/v1/investigations"}, endpointNames(impact.LostEndpoints))
+		require.Len(t, impact.InaccessibleWorkItems, 1)
+		assert.Equal(t, "case-7", impact.InaccessibleWorkItems[0].ID)
+		assert.Equal(t, 1, report.InaccessibleItems)
+		assert.Empty(t, report.Warnings)
+
+		// alice can still read alerts, so her alerts are not looked up
+		assert.Equal(t, []string{"investigations:" + aliceID}, workItems.lookups)
+
+		// Nothing is applied
+		assert.Len(t, s.policy.Permissions("analyst"), 9)
+	})
+
+	t.Run("inactive users are included on request", func(t *testing.T) {
+		s, _, _ := simulatorService(t)
+
+		rec := simulate(s, "analyst", PermissionSimulationRequest{Revoke: []string{"investigations:read"}, IncludeInactive: true})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var report PermissionImpactReport
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, 3, report.MatchedUsers)
+		assert.Len(t, report.AffectedUsers, 2)
+	})
+
+	t.Run("failed lookups become warnings", func(t *testing.T) {
+		s, workItems, _ := simulatorService(t)
+		workItems.err = errors.New("investigation toolkit unavailable")
+
+		rec := simulate(s, "analyst", revokeInvestigations)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var report PermissionImpactReport
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		require.Len(t, report.Warnings, 1)
+		assert.Contains(t, report.Warnings[0], "open cases for alice")
+	})
+
+	t.Run("a change no user notices", func(t *testing.T) {
+		s, workItems, _ := simulatorService(t)
+
+		rec := simulate(s, "analyst", PermissionSimulationRequest{Grant: []string{"alerts:read"}})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var report PermissionImpactReport
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Empty(t, report.AffectedUsers)
+		assert.Empty(t, workItems.lookups)
+	})
+
+	t.Run("invalid permissions are rejected", func(t *testing.T) {
+		s, _, _ := simulatorService(t)
+
+		rec := simulate(s, "analyst", PermissionSimulationRequest{Grant: []string{"not a permission"}})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
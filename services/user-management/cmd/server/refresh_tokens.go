@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	// ErrRefreshTokenInvalid is returned for unknown, expired or revoked refresh tokens
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or has been revoked")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is
+	// presented again; the whole token family is revoked when this happens
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+)
+
+// RefreshToken is a single-use token exchanged for a new access token. Every
+// refresh rotates it: the presented token is marked used and replaced by a
+// new one in the same family, bound to a new session. Only a hash of the
+// token is stored.
+type RefreshToken struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	SessionID    uint       `json:"session_id" gorm:"not null;index"`
+	FamilyID     string     `json:"family_id" gorm:"not null;index"`
+	TokenHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UsedAt       *time.Time `json:"used_at"`
+	ReplacedByID *uint      `json:"replaced_by_id"`
+	RevokedAt    *time.Time `json:"revoked_at" gorm:"index"`
+	IPAddress    string     `json:"ip_address"`
+	UserAgent    string     `json:"user_agent"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshTokenStore issues, rotates and revokes refresh tokens. Revoking a
+// refresh token also revokes the sessions issued from its family, so a
+// compromised token can be killed centrally.
+type RefreshTokenStore struct {
+	db       *gorm.DB
+	sessions *SessionStore
+	ttl      time.Duration
+}
+
+// NewRefreshTokenStore creates a refresh token store
+func NewRefreshTokenStore(db *gorm.DB, sessions *SessionStore, ttl time.Duration) *RefreshTokenStore {
+	if ttl <= 0 {
+		ttl = defaultRefreshTokenTTL
+	}
+
+	return &RefreshTokenStore{
+		db:       db,
+		sessions: sessions,
+		ttl:      ttl,
+	}
+}
+
+// refreshTokenTTLFromEnv reads REFRESH_TOKEN_TTL, falling back to 30 days
+func refreshTokenTTLFromEnv() time.Duration {
+	ttl := os.Getenv("REFRESH_TOKEN_TTL")
+	if ttl == "" {
+		return defaultRefreshTokenTTL
+	}
+
+	parsed, err := time.ParseDuration(ttl)
+	if err != nil || parsed <= 0 {
+		log.Printf("Invalid REFRESH_TOKEN_TTL %q, using %s", ttl, defaultRefreshTokenTTL)
+		return defaultRefreshTokenTTL
+	}
+	return parsed
+}
+
+// Issue creates a refresh token for a session. An empty family starts a new
+// family, as on login.
+func (s *RefreshTokenStore) Issue(ctx context.Context, session *UserSession, familyID string) (string, *RefreshToken, error) {
+	raw, err := randomToken(32)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	if familyID == "" {
+		if familyID, err = randomToken(16); err != nil {
+			return "", nil, fmt.Errorf("failed to generate token family: %w", err)
+		}
+	}
+
+	token := &RefreshToken{
+		UserID:    session.UserID,
+		SessionID: session.ID,
+		FamilyID:  familyID,
+		TokenHash: hashRefreshToken(raw),
+		ExpiresAt: time.Now().Add(s.ttl),
+		IPAddress: session.IPAddress,
+		UserAgent: session.UserAgent,
+	}
+	if err := s.db.WithContext(ctx).Create(token).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return raw, token, nil
+}
+
+// Claim marks a refresh token used and returns it. The claim is a single
+// conditional update, so of two concurrent refreshes with the same token only
+// one succeeds. Presenting a token that was already used revokes its family.
+func (s *RefreshTokenStore) Claim(ctx context.Context, raw string) (*RefreshToken, error) {
+	var token RefreshToken
+	err := s.db.WithContext(ctx).Where("token_hash = ?", hashRefreshToken(raw)).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load refresh token: %w", err)
+	}
+
+	if token.RevokedAt != nil || !time.Now().Before(token.ExpiresAt) {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", token.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to claim refresh token: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		// The token was rotated before, so either the client or an attacker
+		// holds a stolen copy; neither can be trusted
+		if _, err := s.RevokeFamily(ctx, token.FamilyID); err != nil {
+			return nil, err
+		}
+		log.Printf("Refresh token reuse detected for user %d, revoked token family %s", token.UserID, token.FamilyID)
+		return &token, ErrRefreshTokenReused
+	}
+
+	token.UsedAt = &now
+	return &token, nil
+}
+
+// Replace records the token that succeeded a claimed one
+func (s *RefreshTokenStore) Replace(ctx context.Context, old, next *RefreshToken) error {
+	if err := s.db.WithContext(ctx).Model(old).Update("replaced_by_id", next.ID).Error; err != nil {
+		return fmt.Errorf("failed to link rotated refresh token: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the refresh token matching a raw token value
+func (s *RefreshTokenStore) Lookup(ctx context.Context, raw string) (*RefreshToken, error) {
+	var token RefreshToken
+	err := s.db.WithContext(ctx).Where("token_hash = ?", hashRefreshToken(raw)).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load refresh token: %w", err)
+	}
+	return &token, nil
+}
+
+// RevokeFamily revokes every refresh token in a family and the sessions they
+// were issued with, returning how many sessions were revoked
+func (s *RefreshTokenStore) RevokeFamily(ctx context.Context, familyID string) (int, error) {
+	return s.revoke(ctx, s.db.WithContext(ctx).Where("family_id = ?", familyID))
+}
+
+// RevokeSession revokes the refresh token families of a session, as on logout
+func (s *RefreshTokenStore) RevokeSession(ctx context.Context, sessionID uint) (int, error) {
+	var families []string
+	if err := s.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("session_id = ?", sessionID).
+		Distinct().Pluck("family_id", &families).Error; err != nil {
+		return 0, fmt.Errorf("failed to load refresh tokens: %w", err)
+	}
+	if len(families) == 0 {
+		return 0, nil
+	}
+
+	return s.revoke(ctx, s.db.WithContext(ctx).Where("family_id IN ?", families))
+}
+
+// RevokeUser revokes every refresh token and session of a user, returning
+// how many sessions were revoked
+func (s *RefreshTokenStore) RevokeUser(ctx context.Context, userID uint) (int, error) {
+	if err := s.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return s.sessions.RevokeUser(ctx, userID)
+}
+
+// revoke marks the matching refresh tokens revoked and cascades into their sessions
+func (s *RefreshTokenStore) revoke(ctx context.Context, scope *gorm.DB) (int, error) {
+	var tokens []RefreshToken
+	if err := scope.Find(&tokens).Error; err != nil {
+		return 0, fmt.Errorf("failed to load refresh tokens: %w", err)
+	}
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]uint, len(tokens))
+	sessionIDs := make([]uint, len(tokens))
+	for i, token := range tokens {
+		ids[i] = token.ID
+		sessionIDs[i] = token.SessionID
+	}
+
+	if err := s.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("id IN ? AND revoked_at IS NULL", ids).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return s.sessions.RevokeIDs(ctx, sessionIDs)
+}
+
+func hashRefreshToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// issueSession creates a session with a new access token and a refresh token
+// in the given family, starting a new family when it is empty. A new family
+// is a new login and counts against the concurrent session limit.
+func (s *UserManagementService) issueSession(c *gin.Context, user *User, familyID string) (*LoginResponse, *RefreshToken, error) {
+	if familyID == "" {
+		if err := s.enforceSessionLimit(c, user); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	token, expiresAt, err := s.GenerateJWT(user)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	session := UserSession{
+		UserID:    user.ID,
+		Token:     token,
+		ExpiresAt: expiresAt,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.GetHeader("User-Agent"),
+	}
+	if err := s.sessions.Create(c.Request.Context(), &session); err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, stored, err := s.refreshTokens.Issue(c.Request.Context(), &session, familyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &LoginResponse{
+		Token:                 token,
+		ExpiresAt:             expiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: stored.ExpiresAt,
+		User:                  *user,
+	}, stored, nil
+}
+
+// RefreshSession exchanges a refresh token for a new access token and a new
+// refresh token. The old refresh token and its session stop working.
+func (s *UserManagementService) RefreshSession(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	old, err := s.refreshTokens.Claim(ctx, req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRefreshTokenReused):
+			s.LogAuditEvent(old.UserID, "refresh_token_reuse", "authentication",
+				fmt.Sprintf("Reused refresh token revoked token family %s", old.FamilyID), c.ClientIP())
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrRefreshTokenInvalid):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+		}
+		return
+	}
+
+	var user User
+	if err := s.db.Preload("Permissions").First(&user, old.UserID).Error; err != nil || !user.IsActive {
+		s.refreshTokens.RevokeFamily(ctx, old.FamilyID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is deactivated"})
+		return
+	}
+
+	response, next, err := s.issueSession(c, &user, old.FamilyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+		return
+	}
+
+	if err := s.refreshTokens.Replace(ctx, old, next); err != nil {
+		log.Printf("Failed to link refresh token %d to its replacement: %v", old.ID, err)
+	}
+
+	// The access token issued with the rotated refresh token is retired too
+	if _, err := s.sessions.RevokeIDs(ctx, []uint{old.SessionID}); err != nil {
+		log.Printf("Failed to revoke session %d after refresh: %v", old.SessionID, err)
+	}
+
+	s.LogAuditEvent(user.ID, "refresh_token", "authentication", "Session refreshed", c.ClientIP())
+
+	response.User.PasswordHash = ""
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeRefreshToken revokes a refresh token's whole family and every
+// session issued from it
+func (s *UserManagementService) RevokeRefreshToken(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	token, err := s.refreshTokens.Lookup(ctx, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenInvalid) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh token"})
+		return
+	}
+
+	revoked, err := s.refreshTokens.RevokeFamily(ctx, token.FamilyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh token"})
+		return
+	}
+
+	s.LogAuditEvent(token.UserID, "revoke_refresh_token", "authentication",
+		fmt.Sprintf("Revoked refresh token family %s and %d sessions", token.FamilyID, revoked), c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{"revoked_sessions": revoked})
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// refreshTestDB adds refresh tokens to the session test database
+func refreshTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := sessionTestDB(t)
+	require.NoError(t, db.AutoMigrate(&RefreshToken{}))
+	return db
+}
+
+// refreshTestSession creates an active session to issue refresh tokens for
+func refreshTestSession(t *testing.T, sessions *SessionStore, token string) *UserSession {
+	t.Helper()
+
+	session := &UserSession{UserID: 7, Token: token, ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, sessions.Create(context.Background(), session))
+	return session
+}
+
+func sessionRevoked(t *testing.T, db *gorm.DB, id uint) bool {
+	t.Helper()
+
+	var session UserSession
+	require.NoError(t, db.First(&session, id).Error)
+	return session.RevokedAt != nil
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("a rotated token is replaced within its family", func(t *testing.T) {
+		db := refreshTestDB(t)
+		store := NewRefreshTokenStore(db, NewSessionStore(db, nil, 0), time.Hour)
+		first := refreshTestSession(t, store.sessions, "token-first")
+
+		raw, issued, err := store.Issue(ctx, first, "")
+		require.NoError(t, err)
+		assert.NotEqual(t, raw, issued.TokenHash, "only the hash is stored")
+
+		claimed, err := store.Claim(ctx, raw)
+		require.NoError(t, err)
+		require.NotNil(t, claimed.UsedAt)
+
+		second := refreshTestSession(t, store.sessions, "token-second")
+		nextRaw, next, err := store.Issue(ctx, second, claimed.FamilyID)
+		require.NoError(t, err)
+		require.NoError(t, store.Replace(ctx, claimed, next))
+
+		old, err := store.Lookup(ctx, raw)
+		require.NoError(t, err)
+		require.NotNil(t, old.ReplacedByID)
+		assert.Equal(t, next.ID, *old.ReplacedByID)
+		assert.Equal(t, issued.FamilyID, next.FamilyID)
+
+		_, err = store.Claim(ctx, nextRaw)
+		assert.NoError(t, err)
+	})
+
+	t.Run("reusing a rotated token revokes the whole family", func(t *testing.T) {
+		db := refreshTestDB(t)
+		store := NewRefreshTokenStore(db, NewSessionStore(db, nil, 0), time.Hour)
+		first := refreshTestSession(t, store.sessions, "token-stolen")
+
+		raw, _, err := store.Issue(ctx, first, "")
+		require.NoError(t, err)
+		claimed, err := store.Claim(ctx, raw)
+		require.NoError(t, err)
+
+		second := refreshTestSession(t, store.sessions, "token-legitimate")
+		nextRaw, _, err := store.Issue(ctx, second, claimed.FamilyID)
+		require.NoError(t, err)
+
+		// An attacker presents the token the client already rotated
+		reused, err := store.Claim(ctx, raw)
+		assert.ErrorIs(t, err, ErrRefreshTokenReused)
+		require.NotNil(t, reused)
+		assert.Equal(t, claimed.FamilyID, reused.FamilyID)
+
+		_, err = store.Claim(ctx, nextRaw)
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid, "the successor dies with the family")
+		assert.True(t, sessionRevoked(t, db, first.ID))
+		assert.True(t, sessionRevoked(t, db, second.ID))
+	})
+
+	t.Run("other families are untouched by a revocation", func(t *testing.T) {
+		db := refreshTestDB(t)
+		store := NewRefreshTokenStore(db, NewSessionStore(db, nil, 0), time.Hour)
+		laptop := refreshTestSession(t, store.sessions, "token-laptop")
+		phone := refreshTestSession(t, store.sessions, "token-phone")
+
+		_, _, err := store.Issue(ctx, laptop, "")
+		require.NoError(t, err)
+		phoneRaw, _, err := store.Issue(ctx, phone, "")
+		require.NoError(t, err)
+
+		revoked, err := store.RevokeSession(ctx, laptop.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, revoked)
+
+		assert.True(t, sessionRevoked(t, db, laptop.ID))
+		assert.False(t, sessionRevoked(t, db, phone.ID))
+		_, err = store.Claim(ctx, phoneRaw)
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired and unknown tokens are invalid", func(t *testing.T) {
+		db := refreshTestDB(t)
+		store := NewRefreshTokenStore(db, NewSessionStore(db, nil, 0), time.Hour)
+		session := refreshTestSession(t, store.sessions, "token-expired")
+
+		raw, issued, err := store.Issue(ctx, session, "")
+		require.NoError(t, err)
+		require.NoError(t, db.Model(issued).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+		_, err = store.Claim(ctx, raw)
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+		_, err = store.Claim(ctx, "not-a-token")
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+	})
+}
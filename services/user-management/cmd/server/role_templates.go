@@ -0,0 +1,545 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RoleTemplate is a named bundle of permissions that can be applied to many users
+type RoleTemplate struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	Name        string       `json:"name" gorm:"uniqueIndex;not null"`
+	Description string       `json:"description"`
+	Role        string       `json:"role"` // optional role set on users the template is applied to
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_template_permissions;"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Bulk apply modes
+const (
+	ApplyModeReplace = "replace" // users get exactly the template permissions and are linked to it
+	ApplyModeAdd     = "add"     // template permissions are granted on top of existing ones
+	ApplyModeRemove  = "remove"  // template permissions are revoked
+)
+
+type RoleTemplateRequest struct {
+	Name          string `json:"name" binding:"required"`
+	Description   string `json:"description"`
+	Role          string `json:"role"`
+	PermissionIDs []uint `json:"permission_ids"`
+}
+
+// UserSelector picks the users a bulk operation applies to. At least one
+// filter or All must be set so an empty body never targets every user.
+type UserSelector struct {
+	UserIDs         []uint `json:"user_ids"`
+	Department      string `json:"department"`
+	Role            string `json:"role"`
+	IncludeInactive bool   `json:"include_inactive"`
+	All             bool   `json:"all"`
+}
+
+type ApplyTemplateRequest struct {
+	UserSelector
+	Mode string `json:"mode"`
+}
+
+type BulkPermissionRequest struct {
+	UserSelector
+	PermissionIDs []uint `json:"permission_ids" binding:"required"`
+	Mode          string `json:"mode" binding:"required"`
+}
+
+// PermissionDiff describes how a user's permissions change, or for drift
+// detection how they differ from their template
+type PermissionDiff struct {
+	UserID     uint     `json:"user_id"`
+	Username   string   `json:"username"`
+	Department string   `json:"department"`
+	Added      []string `json:"added"`
+	Removed    []string `json:"removed"`
+	RoleChange string   `json:"role_change,omitempty"`
+}
+
+// HasChanges reports whether applying the diff would change anything
+func (d PermissionDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || d.RoleChange != ""
+}
+
+// DiffPermissions computes the change from current to the result of applying
+// target in the given mode
+func DiffPermissions(current, target []Permission, mode string) (added, removed []string) {
+	have := make(map[uint]string, len(current))
+	for _, p := range current {
+		have[p.ID] = p.Name
+	}
+	want := make(map[uint]string, len(target))
+	for _, p := range target {
+		want[p.ID] = p.Name
+	}
+
+	added, removed = []string{}, []string{}
+	switch mode {
+	case ApplyModeReplace:
+		for id, name := range want {
+			if _, ok := have[id]; !ok {
+				added = append(added, name)
+			}
+		}
+		for id, name := range have {
+			if _, ok := want[id]; !ok {
+				removed = append(removed, name)
+			}
+		}
+	case ApplyModeAdd:
+		for id, name := range want {
+			if _, ok := have[id]; !ok {
+				added = append(added, name)
+			}
+		}
+	case ApplyModeRemove:
+		for id, name := range want {
+			if _, ok := have[id]; ok {
+				removed = append(removed, name)
+			}
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func validApplyMode(mode string) bool {
+	return mode == ApplyModeReplace || mode == ApplyModeAdd || mode == ApplyModeRemove
+}
+
+// selectUsers loads the users matched by a selector with their permissions
+func (s *UserManagementService) selectUsers(selector UserSelector) ([]User, error) {
+	if len(selector.UserIDs) == 0 && selector.Department == "" && selector.Role == "" && !selector.All {
+		return nil, fmt.Errorf("select users by user_ids, department or role, or set all")
+	}
+
+	query := s.db.Preload("Permissions").Order("id")
+	if len(selector.UserIDs) > 0 {
+		query = query.Where("id IN ?", selector.UserIDs)
+	}
+	if selector.Department != "" {
+		query = query.Where("department = ?", selector.Department)
+	}
+	if selector.Role != "" {
+		query = query.Where("role = ?", selector.Role)
+	}
+	if !selector.IncludeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var users []User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// planBulkChange computes the per-user diffs for applying permissions to a user set
+func planBulkChange(users []User, target []Permission, mode, role string) []PermissionDiff {
+	diffs := make([]PermissionDiff, 0, len(users))
+	for _, user := range users {
+		added, removed := DiffPermissions(user.Permissions, target, mode)
+		diff := PermissionDiff{
+			UserID:     user.ID,
+			Username:   user.Username,
+			Department: user.Department,
+			Added:      added,
+			Removed:    removed,
+		}
+		if mode == ApplyModeReplace && role != "" && role != user.Role {
+			diff.RoleChange = fmt.Sprintf("%s -> %s", user.Role, role)
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// applyBulkChange writes permissions to every user in a single transaction
+func (s *UserManagementService) applyBulkChange(users []User, target []Permission, mode string, template *RoleTemplate, actorID uint) (int, error) {
+	changed := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for i := range users {
+			user := &users[i]
+			added, removed := DiffPermissions(user.Permissions, target, mode)
+			templateChanged := template != nil && mode == ApplyModeReplace &&
+				(user.RoleTemplateID == nil || *user.RoleTemplateID != template.ID ||
+					(template.Role != "" && template.Role != user.Role))
+			if len(added) == 0 && len(removed) == 0 && !templateChanged {
+				continue
+			}
+
+			before := snapshotUser(user)
+			association := tx.Model(user).Association("Permissions")
+			var err error
+			switch mode {
+			case ApplyModeReplace:
+				err = association.Replace(target)
+			case ApplyModeAdd:
+				err = association.Append(target)
+			case ApplyModeRemove:
+				err = association.Delete(target)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update permissions for user %d: %w", user.ID, err)
+			}
+
+			if templateChanged {
+				updates := map[string]interface{}{"role_template_id": template.ID}
+				if template.Role != "" {
+					updates["role"] = template.Role
+				}
+				if err := tx.Model(user).Updates(updates).Error; err != nil {
+					return fmt.Errorf("failed to link user %d to template: %w", user.ID, err)
+				}
+			}
+			if err := s.recordUserChange(tx, before, user.ID, actorID); err != nil {
+				return err
+			}
+			changed++
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// ListRoleTemplates returns all role templates
+func (s *UserManagementService) ListRoleTemplates(c *gin.Context) {
+	var templates []RoleTemplate
+	if err := s.db.Preload("Permissions").Order("name").Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch role templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"role_templates": templates})
+}
+
+// GetRoleTemplate returns a single role template
+func (s *UserManagementService) GetRoleTemplate(c *gin.Context) {
+	var template RoleTemplate
+	if err := s.db.Preload("Permissions").First(&template, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role template not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// CreateRoleTemplate creates a new role template
+func (s *UserManagementService) CreateRoleTemplate(c *gin.Context) {
+	var req RoleTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	permissions, err := s.findPermissions(req.PermissionIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template := RoleTemplate{
+		Name:        req.Name,
+		Description: req.Description,
+		Role:        req.Role,
+		Permissions: permissions,
+	}
+	if err := s.db.Create(&template).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Failed to create role template, name may already exist"})
+		return
+	}
+
+	s.LogAuditEvent(s.GetUserIDFromContext(c), "create_role_template", "user_management",
+		fmt.Sprintf("Created role template: %s", template.Name), c.ClientIP())
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// UpdateRoleTemplate replaces a role template's definition. Users linked to
+// the template are not changed; they show up as drifted until it is re-applied.
+func (s *UserManagementService) UpdateRoleTemplate(c *gin.Context) {
+	var req RoleTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var template RoleTemplate
+	if err := s.db.First(&template, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role template not found"})
+		return
+	}
+
+	permissions, err := s.findPermissions(req.PermissionIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template.Name = req.Name
+	template.Description = req.Description
+	template.Role = req.Role
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&template).Error; err != nil {
+			return err
+		}
+		return tx.Model(&template).Association("Permissions").Replace(permissions)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role template"})
+		return
+	}
+	template.Permissions = permissions
+
+	s.LogAuditEvent(s.GetUserIDFromContext(c), "update_role_template", "user_management",
+		fmt.Sprintf("Updated role template: %s", template.Name), c.ClientIP())
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteRoleTemplate deletes a role template and unlinks its users
+func (s *UserManagementService) DeleteRoleTemplate(c *gin.Context) {
+	var template RoleTemplate
+	if err := s.db.First(&template, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role template not found"})
+		return
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&User{}).Where("role_template_id = ?", template.ID).
+			Update("role_template_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&template).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(&template).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete role template"})
+		return
+	}
+
+	s.LogAuditEvent(s.GetUserIDFromContext(c), "delete_role_template", "user_management",
+		fmt.Sprintf("Deleted role template: %s", template.Name), c.ClientIP())
+
+	c.Status(http.StatusNoContent)
+}
+
+// PreviewRoleTemplate shows the per-user permission changes applying a
+// template would make, without changing anything
+func (s *UserManagementService) PreviewRoleTemplate(c *gin.Context) {
+	s.handleApplyRoleTemplate(c, true)
+}
+
+// ApplyRoleTemplate applies a template to every selected user
+func (s *UserManagementService) ApplyRoleTemplate(c *gin.Context) {
+	s.handleApplyRoleTemplate(c, false)
+}
+
+func (s *UserManagementService) handleApplyRoleTemplate(c *gin.Context, dryRun bool) {
+	var req ApplyTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = ApplyModeReplace
+	}
+	if !validApplyMode(req.Mode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be replace, add or remove"})
+		return
+	}
+
+	var template RoleTemplate
+	if err := s.db.Preload("Permissions").First(&template, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role template not found"})
+		return
+	}
+
+	users, err := s.selectUsers(req.UserSelector)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.respondBulkChange(c, users, template.Permissions, req.Mode, &template, dryRun,
+		fmt.Sprintf("role template %s (%s)", template.Name, req.Mode))
+}
+
+// BulkUpdatePermissions grants or revokes individual permissions across a user set
+func (s *UserManagementService) BulkUpdatePermissions(c *gin.Context) {
+	var req BulkPermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Mode != ApplyModeAdd && req.Mode != ApplyModeRemove {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be add or remove"})
+		return
+	}
+
+	permissions, err := s.findPermissions(req.PermissionIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	users, err := s.selectUsers(req.UserSelector)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.respondBulkChange(c, users, permissions, req.Mode, nil, c.Query("dry_run") == "true",
+		fmt.Sprintf("%d permissions (%s)", len(permissions), req.Mode))
+}
+
+func (s *UserManagementService) respondBulkChange(c *gin.Context, users []User, target []Permission, mode string, template *RoleTemplate, dryRun bool, description string) {
+	role := ""
+	if template != nil {
+		role = template.Role
+	}
+
+	diffs := planBulkChange(users, target, mode, role)
+	changes := make([]PermissionDiff, 0, len(diffs))
+	for _, diff := range diffs {
+		if diff.HasChanges() {
+			changes = append(changes, diff)
+		}
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, gin.H{
+			"dry_run":       true,
+			"matched_users": len(users),
+			"changed_users": len(changes),
+			"changes":       changes,
+		})
+		return
+	}
+
+	updated, err := s.applyBulkChange(users, target, mode, template, s.GetUserIDFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply permissions", "details": err.Error()})
+		return
+	}
+
+	s.LogAuditEvent(s.GetUserIDFromContext(c), "bulk_update_permissions", "user_management",
+		fmt.Sprintf("Applied %s to %d of %d users", description, updated, len(users)), c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run":       false,
+		"matched_users": len(users),
+		"changed_users": updated,
+		"changes":       changes,
+	})
+}
+
+// GetPermissionDrift lists users whose permissions no longer match the
+// template they were assigned
+func (s *UserManagementService) GetPermissionDrift(c *gin.Context) {
+	query := s.db.Preload("Permissions").Where("role_template_id IS NOT NULL").Order("id")
+	if templateID := c.Query("template_id"); templateID != "" {
+		query = query.Where("role_template_id = ?", templateID)
+	}
+	if department := c.Query("department"); department != "" {
+		query = query.Where("department = ?", department)
+	}
+	if c.Query("include_inactive") != "true" {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var users []User
+	if err := query.Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	var templates []RoleTemplate
+	if err := s.db.Preload("Permissions").Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch role templates"})
+		return
+	}
+
+	drifted := DetectDrift(users, templates)
+
+	c.JSON(http.StatusOK, gin.H{
+		"checked_users": len(users),
+		"drifted_users": len(drifted),
+		"drift":         drifted,
+	})
+}
+
+// DetectDrift compares each templated user with their template. Added lists
+// permissions the user holds beyond the template and Removed lists template
+// permissions the user is missing.
+func DetectDrift(users []User, templates []RoleTemplate) []PermissionDiff {
+	byID := make(map[uint]*RoleTemplate, len(templates))
+	for i := range templates {
+		byID[templates[i].ID] = &templates[i]
+	}
+
+	drifted := []PermissionDiff{}
+	for _, user := range users {
+		if user.RoleTemplateID == nil {
+			continue
+		}
+		template, ok := byID[*user.RoleTemplateID]
+		if !ok {
+			continue
+		}
+
+		// Diffing from the template to the user reports extras as added
+		extra, missing := DiffPermissions(template.Permissions, user.Permissions, ApplyModeReplace)
+		diff := PermissionDiff{
+			UserID:     user.ID,
+			Username:   user.Username,
+			Department: user.Department,
+			Added:      extra,
+			Removed:    missing,
+		}
+		if template.Role != "" && template.Role != user.Role {
+			diff.RoleChange = fmt.Sprintf("%s -> %s", template.Role, user.Role)
+		}
+		if diff.HasChanges() {
+			drifted = append(drifted, diff)
+		}
+	}
+	return drifted
+}
+
+// findPermissions loads permissions by ID and rejects unknown IDs
+func (s *UserManagementService) findPermissions(ids []uint) ([]Permission, error) {
+	if len(ids) == 0 {
+		return []Permission{}, nil
+	}
+
+	var permissions []Permission
+	if err := s.db.Where("id IN ?", ids).Find(&permissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load permissions: %w", err)
+	}
+
+	unique := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	if len(permissions) != len(unique) {
+		return nil, fmt.Errorf("one or more permission_ids do not exist")
+	}
+	return permissions, nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bulkChangeResponse is the body of preview, apply and bulk permission calls
+type bulkChangeResponse struct {
+	DryRun       bool             `json:"dry_run"`
+	MatchedUsers int              `json:"matched_users"`
+	ChangedUsers int              `json:"changed_users"`
+	Changes      []PermissionDiff `json:"changes"`
+}
+
+// roleTemplateService adds an "investigator" template granting cases:read
+// and cases:write to the user event service, and returns it with the
+// cases:write permission
+func roleTemplateService(t *testing.T) (*UserManagementService, *User, *RoleTemplate, Permission) {
+	t.Helper()
+
+	s, alice := userEventService(t)
+	require.NoError(t, s.db.AutoMigrate(&RoleTemplate{}))
+
+	write := Permission{Name: "Write cases", Resource: "cases", Action: "write"}
+	require.NoError(t, s.db.Create(&write).Error)
+	template := &RoleTemplate{
+		Name:        "investigator",
+		Role:        "investigator",
+		Permissions: []Permission{alice.Permissions[0], write},
+	}
+	require.NoError(t, s.db.Create(template).Error)
+	return s, alice, template, write
+}
+
+func idParam(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func decodeBulkChange(t *testing.T, body []byte) bulkChangeResponse {
+	t.Helper()
+
+	var response bulkChangeResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	return response
+}
+
+func storedUser(t *testing.T, s *UserManagementService, id uint) User {
+	t.Helper()
+
+	var user User
+	require.NoError(t, s.db.Preload("Permissions").First(&user, id).Error)
+	return user
+}
+
+func TestDiffPermissions(t *testing.T) {
+	read := Permission{ID: 1, Name: "Read cases"}
+	write := Permission{ID: 2, Name: "Write cases"}
+	export := Permission{ID: 3, Name: "Export cases"}
+
+	for _, tt := range []struct {
+		name    string
+		current []Permission
+		target  []Permission
+		mode    string
+		added   []string
+		removed []string
+	}{
+		{"replace grants missing and revokes extra", []Permission{read, export}, []Permission{read, write}, ApplyModeReplace, []string{"Write cases"}, []string{"Export cases"}},
+		{"replace with the same permissions changes nothing", []Permission{read, write}, []Permission{write, read}, ApplyModeReplace, []string{}, []string{}},
+		{"add keeps existing permissions", []Permission{read, export}, []Permission{read, write}, ApplyModeAdd, []string{"Write cases"}, []string{}},
+		{"remove only revokes held permissions", []Permission{read}, []Permission{read, write}, ApplyModeRemove, []string{}, []string{"Read cases"}},
+		{"unknown modes change nothing", []Permission{read}, []Permission{write}, "merge", []string{}, []string{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := DiffPermissions(tt.current, tt.target, tt.mode)
+			assert.Equal(t, tt.added, added)
+			assert.Equal(t, tt.removed, removed)
+		})
+	}
+}
+
+func TestDetectDrift(t *testing.T) {
+	read := Permission{ID: 1, Name: "Read cases"}
+	write := Permission{ID: 2, Name: "Write cases"}
+	export := Permission{ID: 3, Name: "Export cases"}
+	templateID, removedTemplateID := uint(1), uint(99)
+	templates := []RoleTemplate{{ID: templateID, Role: "investigator", Permissions: []Permission{read, write}}}
+
+	drift := DetectDrift([]User{
+		{ID: 1, Username: "matches", Role: "investigator", RoleTemplateID: &templateID, Permissions: []Permission{read, write}},
+		{ID: 2, Username: "extra", Role: "investigator", RoleTemplateID: &templateID, Permissions: []Permission{read, write, export}},
+		{ID: 3, Username: "missing", Role: "investigator", RoleTemplateID: &templateID, Permissions: []Permission{read}},
+		{ID: 4, Username: "promoted", Role: "admin", RoleTemplateID: &templateID, Permissions: []Permission{read, write}},
+		{ID: 5, Username: "untemplated", Permissions: []Permission{export}},
+		{ID: 6, Username: "orphaned", RoleTemplateID: &removedTemplateID, Permissions: []Permission{export}},
+	}, templates)
+
+	require.Len(t, drift, 3)
+	assert.Equal(t, "extra", drift[0].Username)
+	assert.Equal(t, []string{"Export cases"}, drift[0].Added)
+	assert.Empty(t, drift[0].Removed)
+	assert.Equal(t, "missing", drift[1].Username)
+	assert.Equal(t, []string{"Write cases"}, drift[1].Removed)
+	assert.Equal(t, "promoted", drift[2].Username)
+	assert.Equal(t, "investigator -> admin", drift[2].RoleChange)
+}
+
+func TestApplyRoleTemplate(t *testing.T) {
+	t.Run("users must be selected", func(t *testing.T) {
+		s, _, template, _ := roleTemplateService(t)
+
+		rec := userRequest(s.ApplyRoleTemplate, http.MethodPost, "/role-templates/1/apply", idParam(template.ID), map[string]interface{}{})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = userRequest(s.ApplyRoleTemplate, http.MethodPost, "/role-templates/1/apply", idParam(template.ID), map[string]interface{}{"all": true, "mode": "merge"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("preview changes nothing", func(t *testing.T) {
+		s, alice, template, _ := roleTemplateService(t)
+
+		rec := userRequest(s.PreviewRoleTemplate, http.MethodPost, "/role-templates/1/preview", idParam(template.ID), map[string]interface{}{"all": true})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		response := decodeBulkChange(t, rec.Body.Bytes())
+		assert.True(t, response.DryRun)
+		require.Len(t, response.Changes, 1)
+		assert.Equal(t, []string{"Write cases"}, response.Changes[0].Added)
+		assert.Equal(t, "analyst -> investigator", response.Changes[0].RoleChange)
+
+		stored := storedUser(t, s, alice.ID)
+		assert.Equal(t, "analyst", stored.Role)
+		assert.Len(t, stored.Permissions, 1)
+		assert.Nil(t, stored.RoleTemplateID)
+		assert.Empty(t, outboxEvents(t, s))
+	})
+
+	t.Run("replace grants the template and links the user to it", func(t *testing.T) {
+		s, alice, template, _ := roleTemplateService(t)
+
+		rec := userRequest(s.ApplyRoleTemplate, http.MethodPost, "/role-templates/1/apply", idParam(template.ID), map[string]interface{}{"user_ids": []uint{alice.ID}})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, 1, decodeBulkChange(t, rec.Body.Bytes()).ChangedUsers)
+
+		stored := storedUser(t, s, alice.ID)
+		assert.Equal(t, "investigator", stored.Role)
+		assert.Len(t, stored.Permissions, 2)
+		require.NotNil(t, stored.RoleTemplateID)
+		assert.Equal(t, template.ID, *stored.RoleTemplateID)
+		assert.Len(t, outboxEvents(t, s), 1)
+
+		// Applying it again finds nothing to change
+		rec = userRequest(s.ApplyRoleTemplate, http.MethodPost, "/role-templates/1/apply", idParam(template.ID), map[string]interface{}{"user_ids": []uint{alice.ID}})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Zero(t, decodeBulkChange(t, rec.Body.Bytes()).ChangedUsers)
+		assert.Len(t, outboxEvents(t, s), 1)
+	})
+
+	t.Run("inactive users are skipped unless included", func(t *testing.T) {
+		s, alice, template, _ := roleTemplateService(t)
+		require.NoError(t, s.db.Model(alice).Update("is_active", false).Error)
+
+		rec := userRequest(s.ApplyRoleTemplate, http.MethodPost, "/role-templates/1/apply", idParam(template.ID), map[string]interface{}{"role": "analyst"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Zero(t, decodeBulkChange(t, rec.Body.Bytes()).MatchedUsers)
+
+		rec = userRequest(s.ApplyRoleTemplate, http.MethodPost, "/role-templates/1/apply", idParam(template.ID), map[string]interface{}{"role": "analyst", "include_inactive": true})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, 1, decodeBulkChange(t, rec.Body.Bytes()).ChangedUsers)
+	})
+}
+
+func TestBulkUpdatePermissions(t *testing.T) {
+	t.Run("only add and remove are allowed", func(t *testing.T) {
+		s, _, _, write := roleTemplateService(t)
+
+		rec := userRequest(s.BulkUpdatePermissions, http.MethodPost, "/users/permissions/bulk", "", map[string]interface{}{
+			"all": true, "mode": ApplyModeReplace, "permission_ids": []uint{write.ID},
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("unknown permissions are rejected", func(t *testing.T) {
+		s, alice, _, write := roleTemplateService(t)
+
+		rec := userRequest(s.BulkUpdatePermissions, http.MethodPost, "/users/permissions/bulk", "", map[string]interface{}{
+			"all": true, "mode": ApplyModeAdd, "permission_ids": []uint{write.ID, 999},
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Len(t, storedUser(t, s, alice.ID).Permissions, 1)
+	})
+
+	t.Run("remove revokes from the selected users", func(t *testing.T) {
+		s, alice, _, _ := roleTemplateService(t)
+
+		rec := userRequest(s.BulkUpdatePermissions, http.MethodPost, "/users/permissions/bulk", "", map[string]interface{}{
+			"user_ids": []uint{alice.ID}, "mode": ApplyModeRemove, "permission_ids": []uint{alice.Permissions[0].ID},
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		response := decodeBulkChange(t, rec.Body.Bytes())
+		require.Len(t, response.Changes, 1)
+		assert.Equal(t, []string{"Read cases"}, response.Changes[0].Removed)
+		assert.Empty(t, storedUser(t, s, alice.ID).Permissions)
+	})
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// What happens to a login that would exceed the concurrent session limit
+const (
+	sessionLimitEvictOldest = "evict_oldest" // the least recently used sessions are revoked
+	sessionLimitReject      = "reject"       // the new login is refused
+)
+
+// lastSeenResolution is how stale a session's last_seen_at may get before a
+// request updates it, so busy sessions do not write on every request
+const lastSeenResolution = time.Minute
+
+// ErrSessionLimitReached is returned when a login would exceed the user's
+// concurrent session limit and the policy rejects it
+var ErrSessionLimitReached = errors.New("maximum number of concurrent sessions reached")
+
+// SessionPolicy limits concurrent sessions and controls how stale sessions
+// are expired and purged
+type SessionPolicy struct {
+	MaxConcurrent int           // active sessions per user; 0 disables the limit
+	LimitAction   string        // evict_oldest or reject
+	IdleTimeout   time.Duration // sessions unused this long are revoked; 0 disables
+	ReapInterval  time.Duration
+	Retention     time.Duration // ended sessions are deleted after this long
+}
+
+var defaultSessionPolicy = SessionPolicy{
+	MaxConcurrent: 5,
+	LimitAction:   sessionLimitEvictOldest,
+	IdleTimeout:   12 * time.Hour,
+	ReapInterval:  10 * time.Minute,
+	Retention:     30 * 24 * time.Hour,
+}
+
+// sessionPolicyFromEnv reads SESSION_MAX_CONCURRENT ("0" disables the limit),
+// SESSION_LIMIT_ACTION, SESSION_IDLE_TIMEOUT ("0" disables), SESSION_REAP_INTERVAL
+// and SESSION_RETENTION
+func sessionPolicyFromEnv() SessionPolicy {
+	policy := defaultSessionPolicy
+	if os.Getenv("SESSION_MAX_CONCURRENT") == "0" {
+		policy.MaxConcurrent = 0
+	} else {
+		envInt("SESSION_MAX_CONCURRENT", &policy.MaxConcurrent)
+	}
+	if action := strings.ToLower(os.Getenv("SESSION_LIMIT_ACTION")); action != "" {
+		if action == sessionLimitEvictOldest || action == sessionLimitReject {
+			policy.LimitAction = action
+		} else {
+			log.Printf("Invalid SESSION_LIMIT_ACTION %q, using %s", action, policy.LimitAction)
+		}
+	}
+	if os.Getenv("SESSION_IDLE_TIMEOUT") == "0" {
+		policy.IdleTimeout = 0
+	} else {
+		envDuration("SESSION_IDLE_TIMEOUT", &policy.IdleTimeout)
+	}
+	envDuration("SESSION_REAP_INTERVAL", &policy.ReapInterval)
+	envDuration("SESSION_RETENTION", &policy.Retention)
+	return policy
+}
+
+// SessionInfo describes a session without its token
+type SessionInfo struct {
+	ID         uint       `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	Current    bool       `json:"current"`
+}
+
+// activeSessions matches sessions that can still be used: the access token is
+// valid, or the refresh token issued with it can still mint a new one
+func (s *SessionStore) activeSessions(ctx context.Context, now time.Time) *gorm.DB {
+	return s.db.WithContext(ctx).Model(&UserSession{}).
+		Where("revoked_at IS NULL").
+		Where(`expires_at > ? OR EXISTS (
+			SELECT 1 FROM refresh_tokens rt
+			WHERE rt.session_id = user_sessions.id
+			  AND rt.used_at IS NULL AND rt.revoked_at IS NULL AND rt.expires_at > ?)`, now, now)
+}
+
+// ListActive returns a user's active sessions, most recently used first
+func (s *SessionStore) ListActive(ctx context.Context, userID uint) ([]UserSession, error) {
+	var sessions []UserSession
+	if err := s.activeSessions(ctx, time.Now()).
+		Where("user_id = ?", userID).
+		Order("COALESCE(last_seen_at, created_at) DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// ListIdle returns the IDs of active sessions not used since the cutoff
+func (s *SessionStore) ListIdle(ctx context.Context, cutoff time.Time) ([]uint, error) {
+	var ids []uint
+	if err := s.activeSessions(ctx, time.Now()).
+		Where("COALESCE(last_seen_at, created_at) < ?", cutoff).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to list idle sessions: %w", err)
+	}
+	return ids, nil
+}
+
+// Purge deletes sessions that ended before the cutoff, and refresh tokens
+// that expired before it, returning how many sessions were deleted
+func (s *SessionStore) Purge(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Where("revoked_at < ? OR (expires_at < ? AND NOT EXISTS (?))", cutoff, cutoff,
+			s.db.Model(&RefreshToken{}).Select("1").
+				Where("refresh_tokens.session_id = user_sessions.id AND refresh_tokens.used_at IS NULL AND refresh_tokens.revoked_at IS NULL AND refresh_tokens.expires_at > ?", time.Now())).
+		Delete(&UserSession{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge sessions: %w", result.Error)
+	}
+
+	if err := s.db.WithContext(ctx).Where("expires_at < ?", cutoff).Delete(&RefreshToken{}).Error; err != nil {
+		return result.RowsAffected, fmt.Errorf("failed to purge refresh tokens: %w", err)
+	}
+	return result.RowsAffected, nil
+}
+
+// touch records that a session was used, at most once per lastSeenResolution
+func (s *SessionStore) touch(ctx context.Context, session *UserSession) {
+	now := time.Now()
+	if session.LastSeenAt != nil && now.Sub(*session.LastSeenAt) < lastSeenResolution {
+		return
+	}
+	if err := s.db.WithContext(ctx).Model(session).Update("last_seen_at", now).Error; err != nil {
+		log.Printf("Failed to record activity of session %d: %v", session.ID, err)
+	}
+}
+
+// endSession revokes a session together with the refresh token family it
+// belongs to, so the client cannot simply refresh its way back in
+func (s *UserManagementService) endSession(ctx context.Context, sessionID uint) error {
+	if _, err := s.refreshTokens.RevokeSession(ctx, sessionID); err != nil {
+		return err
+	}
+	_, err := s.sessions.RevokeIDs(ctx, []uint{sessionID})
+	return err
+}
+
+// enforceSessionLimit makes room for a new login. Under evict_oldest the
+// least recently used sessions beyond the limit are ended; under reject the
+// login fails instead.
+func (s *UserManagementService) enforceSessionLimit(c *gin.Context, user *User) error {
+	limit := s.sessionPolicy.MaxConcurrent
+	if limit <= 0 {
+		return nil
+	}
+
+	ctx := c.Request.Context()
+	active, err := s.sessions.ListActive(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if len(active) < limit {
+		return nil
+	}
+	if s.sessionPolicy.LimitAction == sessionLimitReject {
+		return ErrSessionLimitReached
+	}
+
+	for _, session := range active[limit-1:] {
+		if err := s.endSession(ctx, session.ID); err != nil {
+			return err
+		}
+		s.LogAuditEvent(user.ID, "session_evicted", "authentication",
+			fmt.Sprintf("Session %d from %s ended by the concurrent session limit of %d", session.ID, session.IPAddress, limit),
+			c.ClientIP())
+	}
+	return nil
+}
+
+// respondSessionError answers a login whose session could not be issued
+func respondSessionError(c *gin.Context, err error) {
+	if errors.Is(err, ErrSessionLimitReached) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+}
+
+// StartSessionReaper ends idle sessions and purges old ones every
+// ReapInterval until the context is cancelled
+func (s *UserManagementService) StartSessionReaper(ctx context.Context) {
+	ticker := time.NewTicker(s.sessionPolicy.ReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reapSessions(ctx)
+		}
+	}
+}
+
+func (s *UserManagementService) reapSessions(ctx context.Context) {
+	now := time.Now()
+
+	if s.sessionPolicy.IdleTimeout > 0 {
+		idle, err := s.sessions.ListIdle(ctx, now.Add(-s.sessionPolicy.IdleTimeout))
+		if err != nil {
+			log.Printf("Session reaper failed: %v", err)
+			return
+		}
+		expired := 0
+		for _, id := range idle {
+			if err := s.endSession(ctx, id); err != nil {
+				log.Printf("Session reaper failed to end session %d: %v", id, err)
+				continue
+			}
+			expired++
+		}
+		if expired > 0 {
+			log.Printf("Session reaper ended %d sessions idle for over %s", expired, s.sessionPolicy.IdleTimeout)
+		}
+	}
+
+	purged, err := s.sessions.Purge(ctx, now.Add(-s.sessionPolicy.Retention))
+	if err != nil {
+		log.Printf("Session reaper failed to purge sessions: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Session reaper purged %d sessions ended over %s ago", purged, s.sessionPolicy.Retention)
+	}
+}
+
+// sessionInfos describes sessions, flagging the one the request was made with
+func sessionInfos(sessions []UserSession, currentID uint) []SessionInfo {
+	infos := make([]SessionInfo, len(sessions))
+	for i, session := range sessions {
+		infos[i] = SessionInfo{
+			ID:         session.ID,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			Current:    session.ID == currentID,
+		}
+	}
+	return infos
+}
+
+// ListSessions returns the caller's active sessions
+func (s *UserManagementService) ListSessions(c *gin.Context) {
+	sessions, err := s.sessions.ListActive(c.Request.Context(), s.GetUserIDFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions":       sessionInfos(sessions, c.GetUint(contextSessionIDKey)),
+		"max_concurrent": s.sessionPolicy.MaxConcurrent,
+	})
+}
+
+// RevokeSession ends one of the caller's sessions, which may be the current one
+func (s *UserManagementService) RevokeSession(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := s.GetUserIDFromContext(c)
+
+	var session UserSession
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if err := s.endSession(ctx, session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	s.LogAuditEvent(userID, "revoke_session", "authentication",
+		fmt.Sprintf("Revoked session %d from %s", session.ID, session.IPAddress), c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// RevokeOtherSessions ends every session of the caller except the current one
+func (s *UserManagementService) RevokeOtherSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := s.GetUserIDFromContext(c)
+	currentID := c.GetUint(contextSessionIDKey)
+
+	sessions, err := s.sessions.ListActive(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == currentID {
+			continue
+		}
+		if err := s.endSession(ctx, session.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+			return
+		}
+		revoked++
+	}
+
+	s.LogAuditEvent(userID, "revoke_sessions", "authentication",
+		fmt.Sprintf("Revoked %d other sessions", revoked), c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// ListUserSessions returns the active sessions of any user, for admins
+func (s *UserManagementService) ListUserSessions(c *gin.Context) {
+	var user User
+	if err := s.db.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	sessions, err := s.sessions.ListActive(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions":       sessionInfos(sessions, c.GetUint(contextSessionIDKey)),
+		"max_concurrent": s.sessionPolicy.MaxConcurrent,
+	})
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sessionLimitService returns a service with the given session limit and a
+// user holding existing sessions, last used 1, 2, ... hours ago
+func sessionLimitService(t *testing.T, limit int, action string, existing int) (*UserManagementService, *User, []UserSession) {
+	t.Helper()
+	ctx := context.Background()
+
+	db := refreshTestDB(t)
+	require.NoError(t, db.AutoMigrate(&AuditLog{}))
+	sessions := NewSessionStore(db, nil, 0)
+	s := &UserManagementService{
+		db:            db,
+		sessions:      sessions,
+		refreshTokens: NewRefreshTokenStore(db, sessions, 0),
+		sessionPolicy: SessionPolicy{MaxConcurrent: limit, LimitAction: action},
+	}
+	user := &User{ID: 7, Username: "alice"}
+
+	created := make([]UserSession, existing)
+	for i := range created {
+		session := &UserSession{
+			UserID:    user.ID,
+			Token:     fmt.Sprintf("token-%d", i),
+			ExpiresAt: time.Now().Add(time.Hour),
+			IPAddress: fmt.Sprintf("10.0.0.%d", i+1),
+		}
+		require.NoError(t, sessions.Create(ctx, session))
+		lastSeen := time.Now().Add(-time.Duration(i+1) * time.Hour)
+		require.NoError(t, db.Model(session).Update("last_seen_at", lastSeen).Error)
+		_, _, err := s.refreshTokens.Issue(ctx, session, "")
+		require.NoError(t, err)
+		created[i] = *session
+	}
+	return s, user, created
+}
+
+func activeSessionIDs(t *testing.T, s *UserManagementService, userID uint) []uint {
+	t.Helper()
+
+	active, err := s.sessions.ListActive(context.Background(), userID)
+	require.NoError(t, err)
+	ids := make([]uint, len(active))
+	for i, session := range active {
+		ids[i] = session.ID
+	}
+	return ids
+}
+
+func TestEnforceSessionLimit(t *testing.T) {
+	t.Run("evicts the least recently used sessions", func(t *testing.T) {
+		s, user, existing := sessionLimitService(t, 3, sessionLimitEvictOldest, 4)
+		c, _ := paginationContext("/auth/login")
+
+		require.NoError(t, s.enforceSessionLimit(c, user))
+
+		// Two sessions stay, leaving room for the login being made
+		assert.Equal(t, []uint{existing[0].ID, existing[1].ID}, activeSessionIDs(t, s, user.ID))
+
+		var revoked int64
+		require.NoError(t, s.db.Model(&RefreshToken{}).
+			Where("session_id IN ? AND revoked_at IS NOT NULL", []uint{existing[2].ID, existing[3].ID}).
+			Count(&revoked).Error)
+		assert.Equal(t, int64(2), revoked, "evicted sessions cannot be refreshed")
+
+		var audits []AuditLog
+		require.NoError(t, s.db.Where("action = ?", "session_evicted").Find(&audits).Error)
+		assert.Len(t, audits, 2)
+	})
+
+	t.Run("rejects the login under the reject policy", func(t *testing.T) {
+		s, user, existing := sessionLimitService(t, 2, sessionLimitReject, 2)
+		c, _ := paginationContext("/auth/login")
+
+		assert.ErrorIs(t, s.enforceSessionLimit(c, user), ErrSessionLimitReached)
+		assert.Equal(t, []uint{existing[0].ID, existing[1].ID}, activeSessionIDs(t, s, user.ID))
+	})
+
+	t.Run("allows logins under the limit", func(t *testing.T) {
+		for _, action := range []string{sessionLimitEvictOldest, sessionLimitReject} {
+			s, user, _ := sessionLimitService(t, 3, action, 2)
+			c, _ := paginationContext("/auth/login")
+
+			require.NoError(t, s.enforceSessionLimit(c, user), action)
+			assert.Len(t, activeSessionIDs(t, s, user.ID), 2, action)
+		}
+	})
+
+	t.Run("a limit of zero disables the check", func(t *testing.T) {
+		s, user, _ := sessionLimitService(t, 0, sessionLimitReject, 3)
+		c, _ := paginationContext("/auth/login")
+
+		require.NoError(t, s.enforceSessionLimit(c, user))
+		assert.Len(t, activeSessionIDs(t, s, user.ID), 3)
+	})
+
+	t.Run("only the user's own sessions count", func(t *testing.T) {
+		s, user, _ := sessionLimitService(t, 2, sessionLimitReject, 1)
+		require.NoError(t, s.sessions.Create(context.Background(), &UserSession{
+			UserID:    user.ID + 1,
+			Token:     "token-other-user",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}))
+		c, _ := paginationContext("/auth/login")
+
+		assert.NoError(t, s.enforceSessionLimit(c, user))
+	})
+}
@@ -0,0 +1,552 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	sessionKeyPrefix = "user-management:session:"
+	// Marks a revoked session so no instance trusts a cache entry for it, even
+	// one written by a request that raced the revocation
+	revokedKeyPrefix = "user-management:session-revoked:"
+
+	// Redis calls are on the request path, so give up quickly and use Postgres
+	redisOpTimeout = 250 * time.Millisecond
+	// After a Redis error the cache is skipped for this long before retrying
+	redisRetryInterval = 10 * time.Second
+	// Upper bound on how long a cached session is trusted without Postgres
+	defaultSessionCacheTTL = 5 * time.Minute
+)
+
+// ErrSessionInvalid is returned for unknown, expired or revoked sessions
+var ErrSessionInvalid = errors.New("session is invalid or has been revoked")
+
+// cachedSession is the subset of a session kept in Redis. The token itself is
+// never stored; keys are derived from its hash.
+type cachedSession struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SessionCacheEviction is a revoked session whose cache entry could not be
+// removed while Redis was down. It is kept in Postgres so whichever instance
+// reaches Redis first evicts it, not only the one that revoked the session.
+type SessionCacheEviction struct {
+	CacheKey  string    `json:"cache_key" gorm:"primaryKey;size:128"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SessionStore keeps user sessions in Postgres and, when configured, caches
+// them in Redis so validating a token does not hit the database on every
+// request. Writes go to Postgres first; Redis is best effort and validation
+// falls back to Postgres whenever Redis is unavailable.
+type SessionStore struct {
+	db       *gorm.DB
+	redis    *redis.Client
+	cacheTTL time.Duration
+
+	mu               sync.Mutex
+	unavailableUntil time.Time
+	// When to next flush evictions other instances recorded during an outage
+	nextEvictionSync time.Time
+	// Set while one request flushes evictions, so others do not queue behind it
+	syncingEvictions bool
+	// Bumped whenever this instance records evictions it could not write
+	evictionsRecorded uint64
+}
+
+// NewSessionStore creates a session store. A nil Redis client stores and
+// validates sessions in Postgres only.
+func NewSessionStore(db *gorm.DB, client *redis.Client, cacheTTL time.Duration) *SessionStore {
+	if cacheTTL <= 0 {
+		cacheTTL = defaultSessionCacheTTL
+	}
+
+	return &SessionStore{
+		db:       db,
+		redis:    client,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// NewSessionStoreFromEnv creates a session store configured by SESSION_STORE
+// ("postgres" or "redis"), REDIS_URL and SESSION_CACHE_TTL
+func NewSessionStoreFromEnv(db *gorm.DB) *SessionStore {
+	if strings.ToLower(os.Getenv("SESSION_STORE")) != "redis" {
+		return NewSessionStore(db, nil, 0)
+	}
+
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		redisURL = "redis://localhost:6379/0"
+	}
+
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		log.Printf("Invalid REDIS_URL, sessions will be validated against Postgres only: %v", err)
+		return NewSessionStore(db, nil, 0)
+	}
+	options.DialTimeout = time.Second
+	options.ReadTimeout = redisOpTimeout
+	options.WriteTimeout = redisOpTimeout
+
+	var cacheTTL time.Duration
+	if ttl := os.Getenv("SESSION_CACHE_TTL"); ttl != "" {
+		if cacheTTL, err = time.ParseDuration(ttl); err != nil {
+			log.Printf("Invalid SESSION_CACHE_TTL %q, using %s", ttl, defaultSessionCacheTTL)
+		}
+	}
+
+	store := NewSessionStore(db, redis.NewClient(options), cacheTTL)
+
+	// Start even if Redis is down; requests fall back to Postgres until it recovers
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := store.redis.Ping(ctx).Err(); err != nil {
+		store.markUnavailable(err)
+	} else {
+		log.Printf("Session cache enabled using Redis at %s", options.Addr)
+	}
+
+	return store
+}
+
+// Create stores a new session, writing through to the cache
+func (s *SessionStore) Create(ctx context.Context, session *UserSession) error {
+	now := time.Now()
+	session.LastSeenAt = &now
+	if err := s.db.WithContext(ctx).Create(session).Error; err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	s.cache(ctx, session.Token, cachedSession{
+		ID:        session.ID,
+		UserID:    session.UserID,
+		ExpiresAt: session.ExpiresAt,
+	})
+	return nil
+}
+
+// Validate returns the active session for a token, checking the cache before
+// Postgres. Cache entries of revoked sessions are ignored. Last use is
+// recorded when Postgres is consulted, so with the cache enabled it lags by up
+// to the cache TTL.
+func (s *SessionStore) Validate(ctx context.Context, token string) (*cachedSession, error) {
+	key := sessionKey(token)
+
+	if client := s.client(ctx); client != nil {
+		opCtx, cancel := context.WithTimeout(ctx, redisOpTimeout)
+		values, err := client.MGet(opCtx, key, revokedKey(key)).Result()
+		cancel()
+
+		if err != nil {
+			s.markUnavailable(err)
+		} else if data, ok := values[0].(string); ok && values[1] == nil {
+			var cached cachedSession
+			if jsonErr := json.Unmarshal([]byte(data), &cached); jsonErr == nil && time.Now().Before(cached.ExpiresAt) {
+				return &cached, nil
+			}
+		}
+	}
+
+	var session UserSession
+	err := s.db.WithContext(ctx).
+		Where("token = ? AND revoked_at IS NULL AND expires_at > ?", token, time.Now()).
+		First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	s.touch(ctx, &session)
+
+	cached := cachedSession{ID: session.ID, UserID: session.UserID, ExpiresAt: session.ExpiresAt}
+	s.cache(ctx, token, cached)
+	return &cached, nil
+}
+
+// Revoke ends a session and evicts it from the cache so every instance
+// rejects the token on its next request
+func (s *SessionStore) Revoke(ctx context.Context, token string) error {
+	var session UserSession
+	err := s.db.WithContext(ctx).Where("token = ? AND revoked_at IS NULL", token).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSessionInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(&session).Update("revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	s.evict(ctx, map[string]time.Time{sessionKey(token): session.ExpiresAt})
+	return nil
+}
+
+// RevokeUser ends every active session of a user and returns how many were revoked
+func (s *SessionStore) RevokeUser(ctx context.Context, userID uint) (int, error) {
+	var ids []uint
+	if err := s.db.WithContext(ctx).Model(&UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("failed to load sessions: %w", err)
+	}
+
+	return s.RevokeIDs(ctx, ids)
+}
+
+// RevokeIDs ends the given sessions if they are still active and returns how
+// many were revoked
+func (s *SessionStore) RevokeIDs(ctx context.Context, ids []uint) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var sessions []UserSession
+	if err := s.db.WithContext(ctx).
+		Where("id IN ? AND revoked_at IS NULL AND expires_at > ?", ids, time.Now()).
+		Find(&sessions).Error; err != nil {
+		return 0, fmt.Errorf("failed to load sessions: %w", err)
+	}
+	if len(sessions) == 0 {
+		return 0, nil
+	}
+
+	active := make([]uint, len(sessions))
+	keys := make(map[string]time.Time, len(sessions))
+	for i, session := range sessions {
+		active[i] = session.ID
+		keys[sessionKey(session.Token)] = session.ExpiresAt
+	}
+
+	if err := s.db.WithContext(ctx).Model(&UserSession{}).
+		Where("id IN ?", active).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.evict(ctx, keys)
+	return len(sessions), nil
+}
+
+// Status describes the session cache for health checks
+func (s *SessionStore) Status() string {
+	if s.redis == nil {
+		return "disabled"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Now().Before(s.unavailableUntil) {
+		return "degraded"
+	}
+	return "redis"
+}
+
+// cache stores a session until it expires or the cache TTL passes, whichever is sooner
+func (s *SessionStore) cache(ctx context.Context, token string, session cachedSession) {
+	client := s.client(ctx)
+	if client == nil {
+		return
+	}
+
+	ttl := time.Until(session.ExpiresAt)
+	if ttl > s.cacheTTL {
+		ttl = s.cacheTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, redisOpTimeout)
+	defer cancel()
+	if err := client.Set(opCtx, sessionKey(token), data, ttl).Err(); err != nil {
+		s.markUnavailable(err)
+	}
+}
+
+// evict removes revoked sessions from the cache and marks them revoked until
+// any cache entry written before the revocation has expired. Keys that cannot
+// be evicted are recorded in Postgres and flushed by the first instance to
+// reach Redis again, so a cache entry written before an outage cannot outlive
+// its revocation on any instance.
+func (s *SessionStore) evict(ctx context.Context, keys map[string]time.Time) {
+	if s.redis == nil {
+		return
+	}
+
+	if client := s.client(ctx); client != nil {
+		err := s.writeEvictions(ctx, client, keys)
+		if err == nil {
+			return
+		}
+		s.markUnavailable(err)
+	}
+
+	pending := make([]SessionCacheEviction, 0, len(keys))
+	for key, expiresAt := range keys {
+		pending = append(pending, SessionCacheEviction{CacheKey: key, ExpiresAt: expiresAt})
+	}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&pending).Error; err != nil {
+		log.Printf("Failed to record %d session cache evictions: %v", len(pending), err)
+	}
+
+	// This instance stops trusting the cache until the evictions are flushed
+	s.mu.Lock()
+	s.nextEvictionSync = time.Time{}
+	s.evictionsRecorded++
+	s.mu.Unlock()
+}
+
+// writeEvictions deletes cache entries and sets their revocation markers
+func (s *SessionStore) writeEvictions(ctx context.Context, client *redis.Client, keys map[string]time.Time) error {
+	opCtx, cancel := context.WithTimeout(ctx, redisOpTimeout)
+	defer cancel()
+
+	now := time.Now()
+	_, err := client.TxPipelined(opCtx, func(pipe redis.Pipeliner) error {
+		for key, expiresAt := range keys {
+			ttl := expiresAt.Sub(now)
+			if ttl > s.cacheTTL {
+				ttl = s.cacheTTL
+			}
+			pipe.Del(opCtx, key)
+			if ttl > 0 {
+				pipe.Set(opCtx, revokedKey(key), 1, ttl)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// client returns the Redis client when the cache is usable. Evictions missed
+// during an outage, by any instance, are flushed first; the cache is not
+// trusted until that succeeds. Each instance checks for such evictions every
+// redisRetryInterval, so after an outage another instance can serve a
+// revoked session from the cache for up to redisRetryInterval.
+func (s *SessionStore) client(ctx context.Context) *redis.Client {
+	if s.redis == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	switch {
+	case now.Before(s.unavailableUntil):
+		s.mu.Unlock()
+		return nil
+	case now.Before(s.nextEvictionSync):
+		s.mu.Unlock()
+		return s.redis
+	case s.syncingEvictions:
+		// Another request is flushing; use Postgres rather than wait for it
+		s.mu.Unlock()
+		return nil
+	}
+	s.syncingEvictions = true
+	recorded := s.evictionsRecorded
+	s.mu.Unlock()
+
+	// Postgres and Redis are called without holding the lock, so a slow
+	// database does not stall cache lookups or health checks
+	err := s.syncEvictions(ctx, now)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncingEvictions = false
+	if err != nil {
+		if errors.Is(err, errCacheUnavailable) {
+			s.unavailableUntil = now.Add(redisRetryInterval)
+		}
+		log.Printf("Session cache not trusted, using Postgres: %v", err)
+		return nil
+	}
+	// Evictions recorded while syncing may have been missed; flush again next time
+	if s.evictionsRecorded == recorded {
+		s.nextEvictionSync = now.Add(redisRetryInterval)
+	}
+	return s.redis
+}
+
+// errCacheUnavailable wraps Redis failures met while flushing evictions
+var errCacheUnavailable = errors.New("session cache unavailable")
+
+// syncEvictions writes the evictions recorded in Postgres to Redis and
+// clears them
+func (s *SessionStore) syncEvictions(ctx context.Context, now time.Time) error {
+	var pending []SessionCacheEviction
+	if err := s.db.WithContext(ctx).Find(&pending).Error; err != nil {
+		return fmt.Errorf("failed to load pending session cache evictions: %w", err)
+	}
+
+	keys := make(map[string]time.Time, len(pending))
+	names := make([]string, len(pending))
+	for i, eviction := range pending {
+		names[i] = eviction.CacheKey
+		if now.Before(eviction.ExpiresAt) {
+			keys[eviction.CacheKey] = eviction.ExpiresAt
+		}
+	}
+
+	if len(keys) > 0 {
+		if err := s.writeEvictions(ctx, s.redis, keys); err != nil {
+			return fmt.Errorf("%w: %v", errCacheUnavailable, err)
+		}
+		log.Printf("Session cache recovered, evicted %d sessions revoked during the outage", len(keys))
+	}
+	if len(names) > 0 {
+		if err := s.db.WithContext(ctx).Where("cache_key IN ?", names).Delete(&SessionCacheEviction{}).Error; err != nil {
+			log.Printf("Failed to clear flushed session cache evictions: %v", err)
+		}
+	}
+	return nil
+}
+
+func (s *SessionStore) markUnavailable(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Now().Before(s.unavailableUntil) {
+		return
+	}
+	s.unavailableUntil = time.Now().Add(redisRetryInterval)
+	log.Printf("Session cache unavailable, falling back to Postgres for %s: %v", redisRetryInterval, err)
+}
+
+func sessionKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return sessionKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+func revokedKey(key string) string {
+	return revokedKeyPrefix + strings.TrimPrefix(key, sessionKeyPrefix)
+}
+
+// bearerToken extracts the token from an Authorization header
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// ValidateToken checks a token's signature and expiry, then that its session is still active
+func (s *UserManagementService) ValidateToken(ctx context.Context, tokenString string) (*cachedSession, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return s.jwtSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrSessionInvalid
+	}
+
+	return s.sessions.Validate(ctx, tokenString)
+}
+
+// GetSession validates the caller's bearer token
+func (s *UserManagementService) GetSession(c *gin.Context) {
+	token := bearerToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing bearer token"})
+		return
+	}
+
+	session, err := s.ValidateToken(c.Request.Context(), token)
+	if err != nil {
+		if errors.Is(err, ErrSessionInvalid) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to validate session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"session_id": session.ID,
+		"user_id":    session.UserID,
+		"expires_at": session.ExpiresAt,
+	})
+}
+
+// Logout revokes the caller's session
+func (s *UserManagementService) Logout(c *gin.Context) {
+	token := bearerToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing bearer token"})
+		return
+	}
+
+	session, err := s.ValidateToken(c.Request.Context(), token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrSessionInvalid.Error()})
+		return
+	}
+
+	if err := s.sessions.Revoke(c.Request.Context(), token); err != nil && !errors.Is(err, ErrSessionInvalid) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	// The refresh token issued with this session must not mint new ones
+	if _, err := s.refreshTokens.RevokeSession(c.Request.Context(), session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh token"})
+		return
+	}
+
+	s.LogAuditEvent(session.UserID, "logout", "authentication", "User logged out", c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// RevokeUserSessions ends every active session and refresh token of a user
+func (s *UserManagementService) RevokeUserSessions(c *gin.Context) {
+	var user User
+	if err := s.db.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	revoked, err := s.refreshTokens.RevokeUser(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	currentUserID := s.GetUserIDFromContext(c)
+	s.LogAuditEvent(currentUserID, "revoke_sessions", "user_management",
+		fmt.Sprintf("Revoked %d sessions of user: %s", revoked, user.Username), c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
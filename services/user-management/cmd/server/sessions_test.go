@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func sessionTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "sessions.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&UserSession{}, &SessionCacheEviction{}))
+	return db
+}
+
+func redisClient(t *testing.T, addr string) *redis.Client {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1, DialTimeout: redisOpTimeout})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func createSession(t *testing.T, store *SessionStore, token string) {
+	t.Helper()
+
+	require.NoError(t, store.Create(context.Background(), &UserSession{
+		UserID:    7,
+		Token:     token,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+}
+
+func TestSessionStoreSharesRevocations(t *testing.T) {
+	ctx := context.Background()
+	cache := miniredis.RunT(t)
+	db := sessionTestDB(t)
+
+	t.Run("revocations reach every instance", func(t *testing.T) {
+		first := NewSessionStore(db, redisClient(t, cache.Addr()), time.Minute)
+		second := NewSessionStore(db, redisClient(t, cache.Addr()), time.Minute)
+
+		createSession(t, first, "token-shared")
+		_, err := second.Validate(ctx, "token-shared")
+		require.NoError(t, err)
+
+		require.NoError(t, first.Revoke(ctx, "token-shared"))
+
+		_, err = second.Validate(ctx, "token-shared")
+		assert.ErrorIs(t, err, ErrSessionInvalid)
+		assert.True(t, cache.Exists(revokedKey(sessionKey("token-shared"))))
+	})
+
+	t.Run("entries cached after a revocation are ignored", func(t *testing.T) {
+		store := NewSessionStore(db, redisClient(t, cache.Addr()), time.Minute)
+		createSession(t, store, "token-raced")
+		require.NoError(t, store.Revoke(ctx, "token-raced"))
+
+		// Another instance read the session from Postgres before it was revoked
+		store.cache(ctx, "token-raced", cachedSession{ID: 1, UserID: 7, ExpiresAt: time.Now().Add(time.Hour)})
+
+		_, err := store.Validate(ctx, "token-raced")
+		assert.ErrorIs(t, err, ErrSessionInvalid)
+	})
+
+	t.Run("evictions missed during an outage are flushed by any instance", func(t *testing.T) {
+		healthy := NewSessionStore(db, redisClient(t, cache.Addr()), time.Minute)
+		createSession(t, healthy, "token-outage")
+
+		// This instance cannot reach Redis while revoking
+		down := miniredis.RunT(t)
+		unreachable := NewSessionStore(db, redisClient(t, down.Addr()), time.Minute)
+		down.Close()
+		require.NoError(t, unreachable.Revoke(ctx, "token-outage"))
+
+		var pending []SessionCacheEviction
+		require.NoError(t, db.Find(&pending).Error)
+		require.Len(t, pending, 1)
+		assert.Equal(t, sessionKey("token-outage"), pending[0].CacheKey)
+		assert.True(t, cache.Exists(sessionKey("token-outage")), "entry written before the outage")
+
+		// The healthy instance picks the eviction up on its next sync
+		healthy.nextEvictionSync = time.Time{}
+		_, err := healthy.Validate(ctx, "token-outage")
+		assert.ErrorIs(t, err, ErrSessionInvalid)
+		assert.False(t, cache.Exists(sessionKey("token-outage")))
+		assert.True(t, cache.Exists(revokedKey(sessionKey("token-outage"))))
+
+		var remaining int64
+		require.NoError(t, db.Model(&SessionCacheEviction{}).Count(&remaining).Error)
+		assert.Zero(t, remaining)
+	})
+
+	t.Run("requests do not wait for another request's eviction sync", func(t *testing.T) {
+		store := NewSessionStore(db, redisClient(t, cache.Addr()), time.Minute)
+		store.syncingEvictions = true
+
+		assert.Nil(t, store.client(ctx), "Postgres is used while another request syncs")
+		assert.Equal(t, "redis", store.Status())
+
+		store.syncingEvictions = false
+		assert.NotNil(t, store.client(ctx))
+		assert.False(t, store.nextEvictionSync.IsZero())
+	})
+
+	t.Run("revocation markers outlive cached entries only", func(t *testing.T) {
+		store := NewSessionStore(db, redisClient(t, cache.Addr()), time.Minute)
+		createSession(t, store, "token-ttl")
+		require.NoError(t, store.Revoke(ctx, "token-ttl"))
+
+		ttl := cache.TTL(revokedKey(sessionKey("token-ttl")))
+		assert.True(t, ttl > 0 && ttl <= time.Minute, "marker TTL %s", ttl)
+	})
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
+)
+
+// User lifecycle event types. The schema is documented as UserLifecycleEvent
+// in shared/proto/events.proto.
+const (
+	UserEventCreated     = "user.created"
+	UserEventUpdated     = "user.updated"
+	UserEventDeactivated = "user.deactivated"
+
+	userEventSchemaVersion = "1"
+	userEventSource        = "user-management"
+)
+
+// userEventsLockKey serializes relays across replicas so events reach Kafka
+// in sequence order
+const userEventsLockKey = 7_420_116
+
+// UserEventConfig controls publishing of user lifecycle events
+type UserEventConfig struct {
+	Brokers       []string
+	Topic         string
+	RelayInterval time.Duration
+	BatchSize     int
+	Retention     time.Duration // published events are deleted from the outbox after this long
+}
+
+var defaultUserEventConfig = UserEventConfig{
+	Topic:         "aegis.users.lifecycle",
+	RelayInterval: 2 * time.Second,
+	BatchSize:     100,
+	Retention:     7 * 24 * time.Hour,
+}
+
+// userEventConfigFromEnv reads USER_EVENTS_BROKERS (comma separated),
+// USER_EVENTS_TOPIC, USER_EVENTS_RELAY_INTERVAL, USER_EVENTS_BATCH_SIZE and
+// USER_EVENTS_RETENTION. It returns nil, disabling events, when no brokers
+// are set.
+func userEventConfigFromEnv() *UserEventConfig {
+	brokers := os.Getenv("USER_EVENTS_BROKERS")
+	if brokers == "" {
+		return nil
+	}
+
+	cfg := defaultUserEventConfig
+	for _, broker := range strings.Split(brokers, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			cfg.Brokers = append(cfg.Brokers, broker)
+		}
+	}
+	if topic := os.Getenv("USER_EVENTS_TOPIC"); topic != "" {
+		cfg.Topic = topic
+	}
+	envDuration("USER_EVENTS_RELAY_INTERVAL", &cfg.RelayInterval)
+	envInt("USER_EVENTS_BATCH_SIZE", &cfg.BatchSize)
+	envDuration("USER_EVENTS_RETENTION", &cfg.Retention)
+	return &cfg
+}
+
+// UserSnapshot is the full state of a user carried by every lifecycle event,
+// so consumers can upsert their read model from any single event
+type UserSnapshot struct {
+	ID           uint      `json:"id"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email"`
+	FirstName    string    `json:"first_name"`
+	LastName     string    `json:"last_name"`
+	Role         string    `json:"role"`
+	Department   string    `json:"department"`
+	IsActive     bool      `json:"is_active"`
+	AuthProvider string    `json:"auth_provider"`
+	Permissions  []string  `json:"permissions"` // "resource:action" grants, sorted
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// UserEvent is the JSON message published to the user lifecycle topic, keyed
+// by user ID. Sequence increases with every event, so a consumer can skip any
+// event older than the last one it applied for that user.
+type UserEvent struct {
+	EventID       string       `json:"event_id"`
+	EventType     string       `json:"event_type"`
+	SourceService string       `json:"source_service"`
+	SchemaVersion string       `json:"schema_version"`
+	Timestamp     time.Time    `json:"timestamp"`
+	Sequence      uint64       `json:"sequence"`
+	ActorID       uint         `json:"actor_id,omitempty"` // 0 for changes made by the system, such as SSO provisioning
+	User          UserSnapshot `json:"user"`
+	ChangedFields []string     `json:"changed_fields,omitempty"`
+}
+
+// UserEventRecord is a lifecycle event in the transactional outbox. Events
+// are written alongside the change they describe and relayed to Kafka in
+// ID order, so a failed publish delays events instead of losing them.
+type UserEventRecord struct {
+	ID            uint64 `gorm:"primaryKey"`
+	EventID       string `gorm:"uniqueIndex;not null"`
+	EventType     string `gorm:"not null"`
+	UserID        uint   `gorm:"not null;index"`
+	ActorID       uint
+	Snapshot      string `gorm:"type:jsonb;not null"`
+	ChangedFields string // comma separated
+	CreatedAt     time.Time
+	PublishedAt   *time.Time `gorm:"index"`
+	Attempts      int
+	LastError     string
+}
+
+// UserEventRelay publishes outbox events to Kafka
+type UserEventRelay struct {
+	db     *gorm.DB
+	writer *kafka.Writer
+	cfg    UserEventConfig
+}
+
+// NewUserEventRelay creates a relay for the configured topic
+func NewUserEventRelay(db *gorm.DB, cfg UserEventConfig) *UserEventRelay {
+	return &UserEventRelay{
+		db: db,
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Brokers...),
+			Topic:        cfg.Topic,
+			Balancer:     &kafka.Hash{}, // keys by user ID, keeping each user's events on one partition
+			RequiredAcks: kafka.RequireAll,
+			BatchSize:    cfg.BatchSize,
+			BatchTimeout: 10 * time.Millisecond,
+		},
+		cfg: cfg,
+	}
+}
+
+// Run relays pending events every RelayInterval until the context is cancelled
+func (r *UserEventRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.RelayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.relay(ctx); err != nil {
+				log.Printf("User event relay failed: %v", err)
+			}
+			r.purge(ctx)
+		}
+	}
+}
+
+// Close flushes and closes the Kafka writer
+func (r *UserEventRelay) Close() error {
+	return r.writer.Close()
+}
+
+// relay publishes the oldest batch of pending events. The batch is marked
+// published only once Kafka has acknowledged all of it; on failure the whole
+// batch is retried on the next pass, so consumers may see duplicates but
+// never a gap.
+func (r *UserEventRelay) relay(ctx context.Context) error {
+	var publishErr error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", userEventsLockKey).Scan(&locked).Error; err != nil {
+			return fmt.Errorf("failed to take relay lock: %w", err)
+		}
+		if !locked {
+			return nil // another replica is relaying
+		}
+
+		var records []UserEventRecord
+		if err := tx.Where("published_at IS NULL").Order("id").Limit(r.cfg.BatchSize).Find(&records).Error; err != nil {
+			return fmt.Errorf("failed to load pending user events: %w", err)
+		}
+		if len(records) == 0 {
+			return nil
+		}
+
+		messages := make([]kafka.Message, 0, len(records))
+		ids := make([]uint64, 0, len(records))
+		for _, record := range records {
+			message, err := record.message()
+			if err != nil {
+				return err
+			}
+			messages = append(messages, message)
+			ids = append(ids, record.ID)
+		}
+
+		if err := r.writer.WriteMessages(ctx, messages...); err != nil {
+			// The attempt is recorded, not rolled back, so stuck events show up
+			publishErr = fmt.Errorf("failed to publish %d user events: %w", len(messages), err)
+			return tx.Model(&UserEventRecord{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+				"attempts":   gorm.Expr("attempts + 1"),
+				"last_error": err.Error(),
+			}).Error
+		}
+
+		if err := tx.Model(&UserEventRecord{}).Where("id IN ?", ids).Update("published_at", time.Now()).Error; err != nil {
+			return fmt.Errorf("failed to mark user events published: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return publishErr
+}
+
+// purge deletes events published longer ago than the retention period
+func (r *UserEventRelay) purge(ctx context.Context) {
+	cutoff := time.Now().Add(-r.cfg.Retention)
+	if err := r.db.WithContext(ctx).Where("published_at < ?", cutoff).Delete(&UserEventRecord{}).Error; err != nil {
+		log.Printf("Failed to purge published user events: %v", err)
+	}
+}
+
+func (record UserEventRecord) message() (kafka.Message, error) {
+	var snapshot UserSnapshot
+	if err := json.Unmarshal([]byte(record.Snapshot), &snapshot); err != nil {
+		return kafka.Message{}, fmt.Errorf("failed to decode user event %d: %w", record.ID, err)
+	}
+
+	event := UserEvent{
+		EventID:       record.EventID,
+		EventType:     record.EventType,
+		SourceService: userEventSource,
+		SchemaVersion: userEventSchemaVersion,
+		Timestamp:     record.CreatedAt.UTC(),
+		Sequence:      record.ID,
+		ActorID:       record.ActorID,
+		User:          snapshot,
+	}
+	if record.ChangedFields != "" {
+		event.ChangedFields = strings.Split(record.ChangedFields, ",")
+	}
+
+	value, err := json.Marshal(event)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("failed to encode user event %d: %w", record.ID, err)
+	}
+
+	return kafka.Message{
+		Key:   []byte(fmt.Sprintf("%d", record.UserID)),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: "event_type", Value: []byte(record.EventType)},
+			{Key: "schema_version", Value: []byte(userEventSchemaVersion)},
+		},
+	}, nil
+}
+
+// snapshotUser captures the published state of a user. Permissions must be
+// preloaded.
+func snapshotUser(user *User) *UserSnapshot {
+	permissions := permissionClaims(user.Permissions)
+	sort.Strings(permissions)
+
+	return &UserSnapshot{
+		ID:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		Role:         user.Role,
+		Department:   user.Department,
+		IsActive:     user.IsActive,
+		AuthProvider: user.AuthProvider,
+		Permissions:  permissions,
+		CreatedAt:    user.CreatedAt,
+		UpdatedAt:    user.UpdatedAt,
+	}
+}
+
+// changedUserFields lists the fields that differ between two snapshots
+func changedUserFields(before, after *UserSnapshot) []string {
+	var changed []string
+	check := func(field string, differs bool) {
+		if differs {
+			changed = append(changed, field)
+		}
+	}
+	check("username", before.Username != after.Username)
+	check("email", before.Email != after.Email)
+	check("first_name", before.FirstName != after.FirstName)
+	check("last_name", before.LastName != after.LastName)
+	check("role", before.Role != after.Role)
+	check("department", before.Department != after.Department)
+	check("is_active", before.IsActive != after.IsActive)
+	check("auth_provider", before.AuthProvider != after.AuthProvider)
+	check("permissions", strings.Join(before.Permissions, ",") != strings.Join(after.Permissions, ","))
+	return changed
+}
+
+// beforeUserChange snapshots a user ahead of a change, for recordUserChange
+// to diff against. It returns nil when events are not published.
+func (s *UserManagementService) beforeUserChange(db *gorm.DB, userID uint) (*UserSnapshot, error) {
+	if s.userEvents == nil {
+		return nil, nil
+	}
+
+	var user User
+	if err := db.Preload("Permissions").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return snapshotUser(&user), nil
+}
+
+// recordUserChange writes the lifecycle event for a change to the outbox.
+// Pass the db or transaction the change was made with; inside a transaction
+// the event commits or rolls back with the change. A nil before records the
+// user as created. Changes to fields that are not published, such as
+// passwords and login times, record nothing.
+func (s *UserManagementService) recordUserChange(db *gorm.DB, before *UserSnapshot, userID, actorID uint) error {
+	if s.userEvents == nil {
+		return nil
+	}
+
+	var user User
+	if err := db.Preload("Permissions").First(&user, userID).Error; err != nil {
+		return fmt.Errorf("failed to load user %d for event: %w", userID, err)
+	}
+	after := snapshotUser(&user)
+
+	eventType := UserEventCreated
+	var changed []string
+	if before != nil {
+		changed = changedUserFields(before, after)
+		if len(changed) == 0 {
+			return nil
+		}
+		eventType = UserEventUpdated
+		if before.IsActive && !after.IsActive {
+			eventType = UserEventDeactivated
+		}
+	}
+
+	snapshot, err := json.Marshal(after)
+	if err != nil {
+		return fmt.Errorf("failed to encode user %d for event: %w", userID, err)
+	}
+
+	record := UserEventRecord{
+		EventID:       uuid.NewString(),
+		EventType:     eventType,
+		UserID:        userID,
+		ActorID:       actorID,
+		Snapshot:      string(snapshot),
+		ChangedFields: strings.Join(changed, ","),
+	}
+	if err := db.Create(&record).Error; err != nil {
+		return fmt.Errorf("failed to record %s event for user %d: %w", eventType, userID, err)
+	}
+	return nil
+}
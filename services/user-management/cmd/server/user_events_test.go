@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// userEventService returns a service that publishes lifecycle events, with
+// an existing user holding the "cases:read" permission
+func userEventService(t *testing.T) (*UserManagementService, *User) {
+	t.Helper()
+
+	db := sessionTestDB(t)
+	require.NoError(t, db.AutoMigrate(&User{}, &Permission{}, &UserEventRecord{}, &RefreshToken{}, &AuditLog{}))
+
+	sessions := NewSessionStore(db, nil, 0)
+	s := &UserManagementService{
+		db:            db,
+		sessions:      sessions,
+		refreshTokens: NewRefreshTokenStore(db, sessions, 0),
+		passwords:     NewPasswordPolicyEngine(db, defaultPasswordPolicy),
+		userEvents:    &UserEventRelay{db: db, cfg: defaultUserEventConfig},
+	}
+
+	permission := Permission{Name: "Read cases", Resource: "cases", Action: "read"}
+	require.NoError(t, db.Create(&permission).Error)
+	user := &User{
+		Username:     "alice",
+		Email:        "alice@example.com",
+		PasswordHash: "hash",
+		Role:         "analyst",
+		IsActive:     true,
+		AuthProvider: authProviderLocal,
+		Permissions:  []Permission{permission},
+	}
+	require.NoError(t, db.Create(user).Error)
+	return s, user
+}
+
+// userRequest runs a handler as admin user 1 with a JSON body
+func userRequest(handler gin.HandlerFunc, method, target, id string, body interface{}) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	payload, _ := json.Marshal(body)
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(string(payload)))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if id != "" {
+		c.Params = gin.Params{{Key: "id", Value: id}}
+	}
+	c.Set(contextUserIDKey, uint(1))
+
+	handler(c)
+	return recorder
+}
+
+func outboxEvents(t *testing.T, s *UserManagementService) []UserEventRecord {
+	t.Helper()
+
+	var records []UserEventRecord
+	require.NoError(t, s.db.Order("id").Find(&records).Error)
+	return records
+}
+
+func TestUserHandlersRecordEvents(t *testing.T) {
+	t.Run("create writes user.created with the user", func(t *testing.T) {
+		s, existing := userEventService(t)
+
+		rec := userRequest(s.CreateUser, http.MethodPost, "/users", "", CreateUserRequest{
+			Username:    "bob",
+			Email:       "bob@example.com",
+			Password:    "Vivid-Harbor-42",
+			FirstName:   "Bob",
+			LastName:    "Builder",
+			Role:        "investigator",
+			Permissions: []uint{existing.Permissions[0].ID},
+		})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		events := outboxEvents(t, s)
+		require.Len(t, events, 1)
+		assert.Equal(t, UserEventCreated, events[0].EventType)
+		assert.Equal(t, uint(1), events[0].ActorID)
+
+		var snapshot UserSnapshot
+		require.NoError(t, json.Unmarshal([]byte(events[0].Snapshot), &snapshot))
+		assert.Equal(t, "bob", snapshot.Username)
+		assert.Equal(t, "investigator", snapshot.Role)
+		assert.Equal(t, []string{"cases:read"}, snapshot.Permissions)
+	})
+
+	t.Run("create fails without a user when the event cannot be recorded", func(t *testing.T) {
+		s, _ := userEventService(t)
+		require.NoError(t, s.db.Migrator().DropTable(&UserEventRecord{}))
+
+		rec := userRequest(s.CreateUser, http.MethodPost, "/users", "", CreateUserRequest{
+			Username:  "carol",
+			Email:     "carol@example.com",
+			Password:  "Vivid-Harbor-42",
+			FirstName: "Carol",
+			LastName:  "Jones",
+			Role:      "analyst",
+		})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+		var count int64
+		require.NoError(t, s.db.Model(&User{}).Where("username = ?", "carol").Count(&count).Error)
+		assert.Zero(t, count, "the user rolls back with its event")
+	})
+
+	t.Run("update writes user.updated with the changed fields", func(t *testing.T) {
+		s, user := userEventService(t)
+
+		rec := userRequest(s.UpdateUser, http.MethodPut, "/users/1", "1", map[string]interface{}{
+			"department":     "AML",
+			"permission_ids": []uint{},
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		events := outboxEvents(t, s)
+		require.Len(t, events, 1)
+		assert.Equal(t, UserEventUpdated, events[0].EventType)
+		assert.Equal(t, user.ID, events[0].UserID)
+		assert.Equal(t, "department,permissions", events[0].ChangedFields)
+	})
+
+	t.Run("deactivate writes user.deactivated and signs the user out", func(t *testing.T) {
+		s, user := userEventService(t)
+		session := &UserSession{UserID: user.ID, Token: "token-alice", ExpiresAt: time.Now().Add(time.Hour)}
+		require.NoError(t, s.sessions.Create(context.Background(), session))
+
+		rec := userRequest(s.UpdateUser, http.MethodPut, "/users/1", "1", map[string]interface{}{"is_active": false})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		events := outboxEvents(t, s)
+		require.Len(t, events, 1)
+		assert.Equal(t, UserEventDeactivated, events[0].EventType)
+		assert.Equal(t, "is_active", events[0].ChangedFields)
+
+		_, err := s.sessions.Validate(context.Background(), "token-alice")
+		assert.ErrorIs(t, err, ErrSessionInvalid)
+	})
+
+	t.Run("update changes nothing when the event cannot be recorded", func(t *testing.T) {
+		s, user := userEventService(t)
+		session := &UserSession{UserID: user.ID, Token: "token-alice", ExpiresAt: time.Now().Add(time.Hour)}
+		require.NoError(t, s.sessions.Create(context.Background(), session))
+		require.NoError(t, s.db.Migrator().DropTable(&UserEventRecord{}))
+
+		rec := userRequest(s.UpdateUser, http.MethodPut, "/users/1", "1", map[string]interface{}{
+			"is_active":      false,
+			"permission_ids": []uint{},
+		})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+		var stored User
+		require.NoError(t, s.db.Preload("Permissions").First(&stored, user.ID).Error)
+		assert.True(t, stored.IsActive)
+		assert.Len(t, stored.Permissions, 1)
+
+		_, err := s.sessions.Validate(context.Background(), "token-alice")
+		assert.NoError(t, err, "sessions are only revoked after the deactivation commits")
+	})
+}
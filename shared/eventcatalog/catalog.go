@@ -0,0 +1,160 @@
+// Package eventcatalog declares the platform's Kafka topics: the event each
+// carries, the proto schema of that event, and the services that produce
+// and consume it. The catalog is the source of the generated event
+// documentation (docs/events/catalog.md), of the catalog endpoint services
+// serve, and of the startup check that consumed topics exist.
+package eventcatalog
+
+//go:generate go run ./cmd/eventdoc -out ../../docs/events/catalog.md -proto ../proto
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Encoding is how events are serialized on a topic
+type Encoding string
+
+const (
+	EncodingProtobuf Encoding = "protobuf"
+	EncodingJSON     Encoding = "json"
+)
+
+// Topic is one Kafka topic and the event it carries
+type Topic struct {
+	Name         string   `json:"name"` // default name; services may override it
+	Description  string   `json:"description"`
+	EventType    string   `json:"event_type"` // fully qualified proto message
+	Schema       string   `json:"schema"`     // proto file, relative to shared/proto
+	Encoding     Encoding `json:"encoding"`
+	Key          string   `json:"key"` // what messages are keyed, and so partitioned, by
+	ConfiguredBy string   `json:"configured_by,omitempty"`
+	Producers    []string `json:"producers"`
+	Consumers    []string `json:"consumers"`
+}
+
+// Produces reports whether the service produces to the topic
+func (t Topic) Produces(service string) bool {
+	return contains(t.Producers, service)
+}
+
+// Consumes reports whether the service consumes the topic
+func (t Topic) Consumes(service string) bool {
+	return contains(t.Consumers, service)
+}
+
+// Catalog is a validated set of topics
+type Catalog struct {
+	topics []Topic
+	byName map[string]int
+}
+
+// New validates topics into a catalog. Every topic needs a unique name, a
+// description, a schema and at least one producer.
+func New(topics []Topic) (*Catalog, error) {
+	c := &Catalog{byName: make(map[string]int, len(topics))}
+	for _, topic := range topics {
+		switch {
+		case topic.Name == "":
+			return nil, fmt.Errorf("topic without a name")
+		case topic.Description == "":
+			return nil, fmt.Errorf("topic %s: missing description", topic.Name)
+		case topic.EventType == "" || topic.Schema == "":
+			return nil, fmt.Errorf("topic %s: missing event type or schema", topic.Name)
+		case topic.Encoding != EncodingProtobuf && topic.Encoding != EncodingJSON:
+			return nil, fmt.Errorf("topic %s: unknown encoding %q", topic.Name, topic.Encoding)
+		case len(topic.Producers) == 0:
+			return nil, fmt.Errorf("topic %s: no producers", topic.Name)
+		}
+		if _, ok := c.byName[topic.Name]; ok {
+			return nil, fmt.Errorf("topic %s declared twice", topic.Name)
+		}
+		c.byName[topic.Name] = len(c.topics)
+		c.topics = append(c.topics, topic)
+	}
+	sort.Slice(c.topics, func(i, j int) bool { return c.topics[i].Name < c.topics[j].Name })
+	for i, topic := range c.topics {
+		c.byName[topic.Name] = i
+	}
+	return c, nil
+}
+
+// Default returns the catalog of DefaultTopics
+func Default() *Catalog {
+	c, err := New(DefaultTopics())
+	if err != nil {
+		panic(fmt.Sprintf("eventcatalog: %v", err))
+	}
+	return c
+}
+
+// Topics returns every topic, by name
+func (c *Catalog) Topics() []Topic {
+	return append([]Topic(nil), c.topics...)
+}
+
+// Lookup returns the topic with the name
+func (c *Catalog) Lookup(name string) (Topic, bool) {
+	i, ok := c.byName[name]
+	if !ok {
+		return Topic{}, false
+	}
+	return c.topics[i], true
+}
+
+// ProducedBy returns the topics the service produces to
+func (c *Catalog) ProducedBy(service string) []Topic {
+	return c.filter(func(t Topic) bool { return t.Produces(service) })
+}
+
+// ConsumedBy returns the topics the service consumes
+func (c *Catalog) ConsumedBy(service string) []Topic {
+	return c.filter(func(t Topic) bool { return t.Consumes(service) })
+}
+
+// Services returns every service that produces or consumes a topic
+func (c *Catalog) Services() []string {
+	seen := make(map[string]bool)
+	for _, topic := range c.topics {
+		for _, service := range append(append([]string(nil), topic.Producers...), topic.Consumers...) {
+			seen[service] = true
+		}
+	}
+	services := make([]string, 0, len(seen))
+	for service := range seen {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return services
+}
+
+// Undeclared returns the topic names that are not in the catalog, such as
+// topics a service was configured to rename
+func (c *Catalog) Undeclared(names ...string) []string {
+	var undeclared []string
+	for _, name := range names {
+		if _, ok := c.byName[name]; !ok {
+			undeclared = append(undeclared, name)
+		}
+	}
+	return undeclared
+}
+
+func (c *Catalog) filter(keep func(Topic) bool) []Topic {
+	var topics []Topic
+	for _, topic := range c.topics {
+		if keep(topic) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+// Command eventdoc checks the declared topics against their proto schemas
+// and generates the event catalog documentation from them:
+//
+//	go generate ./eventcatalog
+//
+// With -check it fails instead when the documentation is out of date, for CI.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"aegisshield/shared/eventcatalog"
+)
+
+func main() {
+	out := flag.String("out", "../../docs/events/catalog.md", "markdown file to write")
+	protoDir := flag.String("proto", "../proto", "directory of the proto schemas")
+	check := flag.Bool("check", false, "fail if the file is not up to date instead of writing it")
+	flag.Parse()
+
+	if err := run(*out, *protoDir, *check); err != nil {
+		fmt.Fprintln(os.Stderr, "eventdoc:", err)
+		os.Exit(1)
+	}
+}
+
+func run(out, protoDir string, check bool) error {
+	catalog := eventcatalog.Default()
+	if err := catalog.CheckSchemas(protoDir); err != nil {
+		return err
+	}
+
+	var doc bytes.Buffer
+	if err := catalog.WriteMarkdown(&doc); err != nil {
+		return err
+	}
+
+	if check {
+		current, err := os.ReadFile(out)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(current, doc.Bytes()) {
+			return fmt.Errorf("%s is out of date; run go generate ./eventcatalog", out)
+		}
+		return nil
+	}
+
+	return os.WriteFile(out, doc.Bytes(), 0o644)
+}
@@ -0,0 +1,62 @@
+package eventcatalog
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ServiceTopics are the topics a service produces and consumes
+type ServiceTopics struct {
+	Produces []string `json:"produces"`
+	Consumes []string `json:"consumes"`
+}
+
+// Document is the machine-readable catalog
+type Document struct {
+	Topics   []Topic                  `json:"topics"`
+	Services map[string]ServiceTopics `json:"services"`
+}
+
+// Document returns the catalog, optionally narrowed to the topics a service
+// produces or consumes
+func (c *Catalog) Document(service string) Document {
+	doc := Document{Topics: []Topic{}, Services: make(map[string]ServiceTopics)}
+	for _, topic := range c.topics {
+		if service == "" || topic.Produces(service) || topic.Consumes(service) {
+			doc.Topics = append(doc.Topics, topic)
+		}
+	}
+	for _, name := range c.Services() {
+		if service != "" && name != service {
+			continue
+		}
+		doc.Services[name] = ServiceTopics{
+			Produces: names(c.ProducedBy(name)),
+			Consumes: names(c.ConsumedBy(name)),
+		}
+	}
+	return doc
+}
+
+// Handler serves the catalog as JSON, or as markdown with format=markdown.
+// The service query parameter narrows it to one service's topics.
+func (c *Catalog) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("format") == "markdown" {
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			c.WriteMarkdown(w)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(c.Document(r.URL.Query().Get("service")))
+	})
+}
+
+func names(topics []Topic) []string {
+	names := make([]string, len(topics))
+	for i, topic := range topics {
+		names[i] = topic.Name
+	}
+	return names
+}
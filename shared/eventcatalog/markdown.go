@@ -0,0 +1,85 @@
+package eventcatalog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown documents the catalog: a summary of every topic, the
+// topics of each service, and a section per topic
+func (c *Catalog) WriteMarkdown(w io.Writer) error {
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintln(b, "# Event catalog")
+	fmt.Fprintln(b)
+	fmt.Fprintln(b, "<!-- Generated by shared/eventcatalog/cmd/eventdoc from shared/eventcatalog/topics.go; do not edit. -->")
+	fmt.Fprintln(b)
+	fmt.Fprintln(b, "Every Kafka topic of the platform, the event it carries and the services producing and consuming it.")
+	fmt.Fprintln(b, "Names are defaults; each service can rename a topic through the setting listed.")
+	fmt.Fprintln(b, "Services serve this catalog as JSON at `/api/v1/events/catalog`.")
+	fmt.Fprintln(b)
+
+	fmt.Fprintln(b, "## Topics")
+	fmt.Fprintln(b)
+	fmt.Fprintln(b, "| Topic | Event | Producers | Consumers |")
+	fmt.Fprintln(b, "| --- | --- | --- | --- |")
+	for _, topic := range c.topics {
+		fmt.Fprintf(b, "| [`%s`](#%s) | `%s` | %s | %s |\n",
+			topic.Name, anchor(topic.Name), shortType(topic.EventType), list(topic.Producers), list(topic.Consumers))
+	}
+	fmt.Fprintln(b)
+
+	fmt.Fprintln(b, "## Services")
+	fmt.Fprintln(b)
+	for _, service := range c.Services() {
+		fmt.Fprintf(b, "### %s\n\n", service)
+		fmt.Fprintf(b, "- Produces: %s\n", topicList(c.ProducedBy(service)))
+		fmt.Fprintf(b, "- Consumes: %s\n\n", topicList(c.ConsumedBy(service)))
+	}
+
+	fmt.Fprintln(b, "## Topic details")
+	fmt.Fprintln(b)
+	for _, topic := range c.topics {
+		fmt.Fprintf(b, "### %s\n\n", topic.Name)
+		fmt.Fprintf(b, "%s.\n\n", topic.Description)
+		fmt.Fprintf(b, "- Event: `%s`, defined in [`shared/proto/%s`](../../shared/proto/%s)\n", topic.EventType, topic.Schema, topic.Schema)
+		fmt.Fprintf(b, "- Encoding: %s\n", topic.Encoding)
+		fmt.Fprintf(b, "- Key: `%s`\n", topic.Key)
+		if topic.ConfiguredBy != "" {
+			fmt.Fprintf(b, "- Configured by: `%s`\n", topic.ConfiguredBy)
+		}
+		fmt.Fprintf(b, "- Producers: %s\n", list(topic.Producers))
+		fmt.Fprintf(b, "- Consumers: %s\n\n", list(topic.Consumers))
+	}
+
+	return b.Flush()
+}
+
+func list(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
+}
+
+func topicList(topics []Topic) string {
+	if len(topics) == 0 {
+		return "none"
+	}
+	links := make([]string, len(topics))
+	for i, topic := range topics {
+		links[i] = fmt.Sprintf("[`%s`](#%s)", topic.Name, anchor(topic.Name))
+	}
+	return strings.Join(links, ", ")
+}
+
+// anchor is the heading anchor markdown renderers derive from a topic name
+func anchor(name string) string {
+	return strings.NewReplacer(".", "").Replace(strings.ToLower(name))
+}
+
+func shortType(eventType string) string {
+	return eventType[strings.LastIndex(eventType, ".")+1:]
+}
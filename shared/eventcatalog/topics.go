@@ -0,0 +1,159 @@
+package eventcatalog
+
+// Services producing and consuming events
+const (
+	AlertingEngine       = "alerting-engine"
+	DataIngestion        = "data-ingestion"
+	DataIntegration      = "data-integration"
+	EntityResolution     = "entity-resolution"
+	GraphEngine          = "graph-engine"
+	InvestigationToolkit = "investigation-toolkit"
+	MLPipeline           = "ml-pipeline"
+	UserManagement       = "user-management"
+)
+
+const eventsSchema = "events.proto"
+
+// event builds a topic carrying a message of events.proto, serialized as
+// the message's JSON mapping as every producer does today
+func event(name, message, key, configuredBy, description string, producers, consumers []string) Topic {
+	return Topic{
+		Name:         name,
+		Description:  description,
+		EventType:    "aegisshield.events." + message,
+		Schema:       eventsSchema,
+		Encoding:     EncodingJSON,
+		Key:          key,
+		ConfiguredBy: configuredBy,
+		Producers:    producers,
+		Consumers:    consumers,
+	}
+}
+
+func services(names ...string) []string { return names }
+
+// DefaultTopics declares the platform's topics under their default names
+func DefaultTopics() []Topic {
+	return []Topic{
+		// Ingestion
+		event("aegis.data.file-upload", "FileUploadEvent", "file_id", "KAFKA_TOPIC_FILE_UPLOAD",
+			"A file was uploaded for ingestion",
+			services(DataIngestion), nil),
+		event("aegis.data.processing", "DataProcessingEvent", "job_id", "KAFKA_TOPIC_DATA_PROCESSING",
+			"Progress and outcome of an ingestion job",
+			services(DataIngestion), nil),
+		event("aegis.data.validation", "DataProcessingEvent", "job_id", "KAFKA_TOPIC_DATA_VALIDATION",
+			"Validation results of an ingestion job, with the records' errors",
+			services(DataIngestion), nil),
+		event("aegis.data.transaction-flow", "TransactionIngestionEvent", "transaction_id", "KAFKA_TOPIC_TRANSACTION_FLOW",
+			"A transaction was ingested and risk scored",
+			services(DataIngestion), nil),
+		event("aegis.data.errors", "ErrorEvent", "component", "KAFKA_TOPIC_ERROR_EVENTS",
+			"An ingestion component failed",
+			services(DataIngestion), nil),
+
+		// ETL
+		event("raw-data", "DataProcessingEvent", "source_id", "kafka.topics.raw_data",
+			"Raw records read from a data source, before transformation",
+			services(DataIntegration), services(DataIntegration)),
+		event("processed-data", "DataProcessingEvent", "job_id", "kafka.topics.processed_data",
+			"Records transformed, validated and enriched by an ETL job",
+			services(DataIntegration), nil),
+		event("validation-errors", "DataProcessingEvent", "job_id", "kafka.topics.validation_errors",
+			"Records an ETL job rejected, with the rules they failed",
+			services(DataIntegration), services(DataIntegration)),
+		event("data-lineage", "DataProcessingEvent", "job_id", "kafka.topics.data_lineage",
+			"Lineage of records through an ETL job",
+			services(DataIntegration), nil),
+		event("quality-metrics", "DataProcessingEvent", "job_id", "kafka.topics.quality_metrics",
+			"Data quality scores of an ETL job",
+			services(DataIntegration), nil),
+		event("schema-changes", "ConfigurationChangedEvent", "source_id", "kafka.topics.schema_changes",
+			"The schema of a data source changed",
+			services(DataIntegration), services(DataIntegration)),
+
+		// Resolution
+		event("transactions.processed", "TransactionIngestionEvent", "transaction_id", "KAFKA_TRANSACTION_TOPIC",
+			"A transaction whose parties need resolving to entities",
+			services(EntityResolution), services(EntityResolution)),
+		event("entities.resolved", "EntityResolvedEvent", "entity_id", "KAFKA_ENTITY_RESOLUTION_TOPIC",
+			"An entity was resolved, created or updated",
+			services(EntityResolution), services(GraphEngine, DataIngestion)),
+		event("entity.attribute.updated", "EntityResolvedEvent", "entity_id", "KAFKA_ATTRIBUTE_UPDATED_TOPIC",
+			"Attributes of a resolved entity changed, so pending merges involving it are re-scored",
+			services(EntityResolution), services(EntityResolution)),
+		event("entity.screening.hit", "AlertTriggeredEvent", "entity_id", "KAFKA_SCREENING_HIT_TOPIC",
+			"An entity matched a sanctions or watchlist entry",
+			services(EntityResolution), nil),
+
+		// Graph
+		event("network.events", "GraphUpdatedEvent", "entity_id", "kafka.network_events_topic",
+			"Entities and relationships were written to the graph",
+			services(GraphEngine), services(DataIngestion)),
+		event("graph.exposure", "NetworkAnalysisCompletedEvent", "entity_id", "kafka.exposure_topic",
+			"An entity's exposure to high-risk entities was recomputed",
+			services(GraphEngine), nil),
+		event("analysis-completed", "NetworkAnalysisCompletedEvent", "analysis_id", "kafka.topics.analysis_completed",
+			"A network analysis finished",
+			services(GraphEngine), services(AlertingEngine)),
+		event("pattern-detected", "SuspiciousPatternDetectedEvent", "pattern_id", "kafka.topics.pattern_detected",
+			"A suspicious pattern, such as a cycle or structuring, was found in the graph",
+			services(GraphEngine), services(AlertingEngine)),
+		event("anomaly-detected", "NetworkAnalysisCompletedEvent", "entity_id", "kafka.topics.anomaly_detected",
+			"An entity's network behaves anomalously",
+			services(GraphEngine), services(AlertingEngine)),
+		event("investigation-created", "GraphUpdatedEvent", "investigation_id", "kafka.topics.investigation_created",
+			"A graph investigation was opened",
+			services(GraphEngine), services(AlertingEngine)),
+		event("investigation-updated", "GraphUpdatedEvent", "investigation_id", "kafka.topics.investigation_updated",
+			"A graph investigation changed",
+			services(GraphEngine), services(AlertingEngine)),
+
+		// Alerting
+		event("alert-generated", "AlertTriggeredEvent", "alert_id", "kafka.topics.alert_generated",
+			"A rule raised an alert",
+			services(AlertingEngine), services(DataIngestion)),
+		event("alert-escalated", "AlertStatusChangedEvent", "alert_id", "kafka.topics.alert_escalated",
+			"An alert was escalated",
+			services(AlertingEngine), nil),
+		event("alert-resolved", "AlertStatusChangedEvent", "alert_id", "kafka.topics.alert_resolved",
+			"An alert was closed with a disposition",
+			services(AlertingEngine), nil),
+		event("notification-sent", "AlertStatusChangedEvent", "alert_id", "kafka.topics.notification_sent",
+			"An alert notification was delivered",
+			services(AlertingEngine), nil),
+		event("notification-failed", "ErrorEvent", "alert_id", "kafka.topics.notification_failed",
+			"An alert notification could not be delivered",
+			services(AlertingEngine), nil),
+		event("alerting-engine-dlq", "ErrorEvent", "source_topic", "kafka.dead_letter.topic",
+			"Messages the alerting engine failed to process, with the source topic in headers",
+			services(AlertingEngine), services(AlertingEngine)),
+
+		// Investigations
+		event("investigations", "AuditEvent", "investigation_id", "KAFKA_TOPIC_INVESTIGATIONS",
+			"Investigation lifecycle changes",
+			services(InvestigationToolkit), nil),
+		event("case-updates", "AuditEvent", "case_id", "KAFKA_TOPIC_CASE_UPDATES",
+			"A case was updated",
+			services(InvestigationToolkit), nil),
+		event("audit-events", "AuditEvent", "resource_id", "AUDIT_KAFKA_TOPIC",
+			"Audit trail of investigator actions",
+			services(InvestigationToolkit), nil),
+
+		// Machine learning
+		event("ml.predictions", "PerformanceMetricEvent", "entity_id", "kafka.topics.predictions",
+			"Model risk predictions",
+			services(MLPipeline), nil),
+		event("ml.model.updates", "ConfigurationChangedEvent", "model_id", "kafka.topics.model_updates",
+			"A model was trained or promoted",
+			services(MLPipeline), nil),
+		event("ml.data.drift", "ThresholdExceededEvent", "model_id", "kafka.topics.data_drift",
+			"A model's input data drifted",
+			services(MLPipeline), nil),
+
+		// Users
+		event("aegis.users.lifecycle", "UserLifecycleEvent", "user_id", "USER_EVENTS_TOPIC",
+			"A user was created, updated, deactivated or deleted; relayed from an outbox in order",
+			services(UserManagement), nil),
+	}
+}
@@ -0,0 +1,95 @@
+package eventcatalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrMissingTopics is returned when topics a service consumes do not exist
+var ErrMissingTopics = errors.New("consumed topics do not exist")
+
+// TopicLister lists the topics that exist on the brokers
+type TopicLister interface {
+	ListTopics(ctx context.Context) ([]string, error)
+}
+
+// MissingTopicsError names the consumed topics missing from the brokers
+type MissingTopicsError struct {
+	Topics []string
+}
+
+func (e *MissingTopicsError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrMissingTopics, strings.Join(e.Topics, ", "))
+}
+
+func (e *MissingTopicsError) Unwrap() error {
+	return ErrMissingTopics
+}
+
+// CheckTopics verifies that every topic a service consumes exists. Consumer
+// groups otherwise wait on a missing topic without error, or auto-create
+// it with default partitioning and never see the producer's events.
+func CheckTopics(ctx context.Context, lister TopicLister, consumed ...string) error {
+	existing, err := lister.ListTopics(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list topics: %w", err)
+	}
+
+	exists := make(map[string]bool, len(existing))
+	for _, topic := range existing {
+		exists[topic] = true
+	}
+
+	var missing []string
+	for _, topic := range consumed {
+		if !exists[topic] {
+			missing = append(missing, topic)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return &MissingTopicsError{Topics: missing}
+	}
+	return nil
+}
+
+var (
+	protoPackage = regexp.MustCompile(`(?m)^package\s+([\w.]+)\s*;`)
+	protoMessage = regexp.MustCompile(`(?m)^message\s+(\w+)\s*\{`)
+)
+
+// CheckSchemas verifies that every topic's event type is a top-level
+// message of its schema, read from the proto directory
+func (c *Catalog) CheckSchemas(protoDir string) error {
+	messages := make(map[string]map[string]bool)
+	for _, topic := range c.topics {
+		declared, ok := messages[topic.Schema]
+		if !ok {
+			source, err := os.ReadFile(filepath.Join(protoDir, topic.Schema))
+			if err != nil {
+				return fmt.Errorf("topic %s: %w", topic.Name, err)
+			}
+
+			declared = make(map[string]bool)
+			pkg := ""
+			if match := protoPackage.FindSubmatch(source); match != nil {
+				pkg = string(match[1]) + "."
+			}
+			for _, match := range protoMessage.FindAllSubmatch(source, -1) {
+				declared[pkg+string(match[1])] = true
+			}
+			messages[topic.Schema] = declared
+		}
+
+		if !declared[topic.EventType] {
+			return fmt.Errorf("topic %s: %s is not a message of %s", topic.Name, topic.EventType, topic.Schema)
+		}
+	}
+	return nil
+}
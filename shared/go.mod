@@ -3,15 +3,19 @@ module aegisshield/shared
 go 1.21
 
 require (
+	github.com/golang-jwt/jwt/v5 v5.2.0
 	github.com/google/uuid v1.5.0
+	github.com/sirupsen/logrus v1.9.3
+	go.uber.org/zap v1.26.0
 	google.golang.org/protobuf v1.31.0
 	google.golang.org/grpc v1.60.1
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20231002182017-d307bd883b97
 )
 
 require (
 	github.com/golang/protobuf v1.5.4 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
 	golang.org/x/net v0.16.0 // indirect
 	golang.org/x/sys v0.13.0 // indirect
 	golang.org/x/text v0.13.0 // indirect
-	google.golang.org/genproto/googleapis/rpc v0.0.0-20231002182017-d307bd883b97 // indirect
 )
\ No newline at end of file
@@ -0,0 +1,203 @@
+// Package quota decides whether an upload fits within the storage limits of
+// the tenant and user making it. Services keep their own usage and limits;
+// this package holds the thresholds both enforce: a soft warning level at
+// which tenants are told they are approaching a limit, and a hard stop at
+// which uploads are refused.
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	// TenantHeader names the tenant a request is made for
+	TenantHeader = "X-Tenant-ID"
+	// DefaultTenant is used for requests that name no tenant
+	DefaultTenant = "default"
+)
+
+// ErrExceeded is returned when an upload would take a tenant or user past
+// the hard stop of a limit
+var ErrExceeded = errors.New("storage quota exceeded")
+
+var tenantID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// Scope is what a limit applies to
+type Scope string
+
+const (
+	ScopeTenant Scope = "tenant"
+	ScopeUser   Scope = "user"
+)
+
+// Level is how close usage is to a limit
+type Level string
+
+const (
+	LevelOK       Level = "ok"
+	LevelWarning  Level = "warning"
+	LevelExceeded Level = "exceeded"
+)
+
+// Rank orders levels from ok to exceeded
+func (l Level) Rank() int {
+	switch l {
+	case LevelWarning:
+		return 1
+	case LevelExceeded:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Limits caps stored bytes and files. Zero means unlimited.
+type Limits struct {
+	Bytes int64 `json:"bytes"`
+	Files int64 `json:"files"`
+}
+
+// Usage is what a tenant or user stores. Reserved bytes and files belong to
+// uploads in progress and count against limits like stored ones, so that
+// concurrent uploads cannot together pass a limit.
+type Usage struct {
+	Scope         Scope  `json:"scope"`
+	Subject       string `json:"subject"`
+	UsedBytes     int64  `json:"used_bytes"`
+	UsedFiles     int64  `json:"used_files"`
+	ReservedBytes int64  `json:"reserved_bytes"`
+	ReservedFiles int64  `json:"reserved_files"`
+	Limits        Limits `json:"limits"`
+}
+
+// Bytes is the stored and reserved bytes
+func (u Usage) Bytes() int64 {
+	return u.UsedBytes + u.ReservedBytes
+}
+
+// Files is the stored and reserved files
+func (u Usage) Files() int64 {
+	return u.UsedFiles + u.ReservedFiles
+}
+
+// Thresholds are the percentages of a limit at which usage is reported as
+// approaching it and at which uploads are refused
+type Thresholds struct {
+	WarningPercent  float64 `json:"warning_percent"`
+	HardStopPercent float64 `json:"hard_stop_percent"`
+}
+
+// DefaultThresholds warn at 80% of a limit and stop at the limit
+func DefaultThresholds() Thresholds {
+	return Thresholds{WarningPercent: 80, HardStopPercent: 100}
+}
+
+// Validate checks that the warning comes before the hard stop
+func (t Thresholds) Validate() error {
+	if t.WarningPercent <= 0 || t.HardStopPercent <= 0 || t.WarningPercent >= t.HardStopPercent {
+		return fmt.Errorf("quota warning percent must be positive and below the hard stop percent")
+	}
+	return nil
+}
+
+// Level reports how close usage is to its limits
+func (t Thresholds) Level(u Usage) Level {
+	return t.level(u.Bytes(), u.Files(), u.Limits)
+}
+
+func (t Thresholds) level(bytes, files int64, limits Limits) Level {
+	level := LevelOK
+	for _, pair := range [][2]int64{{bytes, limits.Bytes}, {files, limits.Files}} {
+		value, limit := pair[0], pair[1]
+		if limit <= 0 {
+			continue
+		}
+		switch {
+		case float64(value) > float64(limit)*t.HardStopPercent/100:
+			return LevelExceeded
+		case float64(value) >= float64(limit)*t.WarningPercent/100:
+			level = LevelWarning
+		}
+	}
+	return level
+}
+
+// Remaining is the bytes that can still be stored before the hard stop, or
+// -1 without a byte limit
+func (t Thresholds) Remaining(u Usage) int64 {
+	if u.Limits.Bytes <= 0 {
+		return -1
+	}
+	remaining := int64(float64(u.Limits.Bytes)*t.HardStopPercent/100) - u.Bytes()
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Decision is the outcome of checking an upload against every limit that
+// applies to it
+type Decision struct {
+	Allowed bool  `json:"allowed"`
+	Level   Level `json:"level"`
+	// Scope and Subject name the limit that decided the level
+	Scope     Scope  `json:"scope,omitempty"`
+	Subject   string `json:"subject,omitempty"`
+	Requested int64  `json:"requested_bytes"`
+	// Remaining is the bytes that can be stored before the nearest hard
+	// stop, or -1 when no byte limit applies
+	Remaining int64  `json:"remaining_bytes"`
+	Message   string `json:"message,omitempty"`
+}
+
+// Evaluate decides whether a file of size bytes fits within every usage's
+// limits. The upload is refused if it passes any hard stop and warned about
+// if it reaches any warning level.
+func (t Thresholds) Evaluate(size int64, usages ...Usage) Decision {
+	decision := Decision{Allowed: true, Level: LevelOK, Requested: size, Remaining: -1}
+
+	for _, u := range usages {
+		if remaining := t.Remaining(u); remaining >= 0 && (decision.Remaining < 0 || remaining < decision.Remaining) {
+			decision.Remaining = remaining
+		}
+
+		level := t.level(u.Bytes()+size, u.Files()+1, u.Limits)
+		if level.Rank() <= decision.Level.Rank() {
+			continue
+		}
+		decision.Level = level
+		decision.Scope = u.Scope
+		decision.Subject = u.Subject
+	}
+
+	switch decision.Level {
+	case LevelExceeded:
+		decision.Allowed = false
+		decision.Message = fmt.Sprintf("upload would exceed the %s storage quota", decision.Scope)
+	case LevelWarning:
+		decision.Message = fmt.Sprintf("%s storage is approaching its quota", decision.Scope)
+	}
+	return decision
+}
+
+// Tenant returns the tenant named by a request's tenant header, or
+// fallback when the header is empty. Malformed tenant IDs are rejected.
+func Tenant(r *http.Request, header, fallback string) (string, error) {
+	return TenantFrom(r.Header.Get(header), fallback)
+}
+
+// TenantFrom validates a tenant ID taken from a header or metadata value
+func TenantFrom(value, fallback string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback, nil
+	}
+	if !tenantID.MatchString(value) {
+		return "", fmt.Errorf("invalid tenant ID %q", value)
+	}
+	return value, nil
+}
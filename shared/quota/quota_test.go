@@ -0,0 +1,169 @@
+package quota
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func tenantUsage(bytes, files int64) Usage {
+	return Usage{Scope: ScopeTenant, Subject: "acme", UsedBytes: bytes, UsedFiles: files, Limits: Limits{Bytes: 1000, Files: 10}}
+}
+
+func userUsage(bytes, files int64) Usage {
+	return Usage{Scope: ScopeUser, Subject: "42", UsedBytes: bytes, UsedFiles: files, Limits: Limits{Bytes: 500}}
+}
+
+func TestThresholdsValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds Thresholds
+		valid      bool
+	}{
+		{"Defaults", DefaultThresholds(), true},
+		{"Hard Stop Above Limit", Thresholds{WarningPercent: 90, HardStopPercent: 110}, true},
+		{"Warning At Hard Stop", Thresholds{WarningPercent: 100, HardStopPercent: 100}, false},
+		{"Warning Above Hard Stop", Thresholds{WarningPercent: 90, HardStopPercent: 80}, false},
+		{"No Warning", Thresholds{HardStopPercent: 100}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.thresholds.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestLevel(t *testing.T) {
+	thresholds := DefaultThresholds()
+
+	tests := []struct {
+		name  string
+		usage Usage
+		want  Level
+	}{
+		{"Below Warning", tenantUsage(799, 1), LevelOK},
+		{"At Warning", tenantUsage(800, 1), LevelWarning},
+		{"At Limit", tenantUsage(1000, 1), LevelWarning},
+		{"Past Limit", tenantUsage(1001, 1), LevelExceeded},
+		{"Files Past Limit", tenantUsage(0, 11), LevelExceeded},
+		{"Reservations Count", Usage{UsedBytes: 400, ReservedBytes: 400, Limits: Limits{Bytes: 1000}}, LevelWarning},
+		{"Unlimited", Usage{UsedBytes: 1 << 40, UsedFiles: 1 << 20}, LevelOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := thresholds.Level(tt.usage); got != tt.want {
+				t.Errorf("Level() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	thresholds := Thresholds{WarningPercent: 80, HardStopPercent: 110}
+
+	tests := []struct {
+		name  string
+		usage Usage
+		want  int64
+	}{
+		{"Up To Hard Stop", tenantUsage(600, 0), 500},
+		{"Past Hard Stop", tenantUsage(1200, 0), 0},
+		{"No Byte Limit", Usage{UsedBytes: 600, Limits: Limits{Files: 10}}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := thresholds.Remaining(tt.usage); got != tt.want {
+				t.Errorf("Remaining() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	thresholds := DefaultThresholds()
+
+	tests := []struct {
+		name      string
+		size      int64
+		usages    []Usage
+		allowed   bool
+		level     Level
+		scope     Scope
+		remaining int64
+		message   string
+	}{
+		{"Fits", 100, []Usage{tenantUsage(100, 1), userUsage(100, 1)}, true, LevelOK, "", 400, ""},
+		{"Fills Limit Exactly", 500, []Usage{tenantUsage(500, 1)}, true, LevelWarning, ScopeTenant, 500, "tenant storage is approaching its quota"},
+		{"Reaches User Warning", 300, []Usage{tenantUsage(100, 1), userUsage(100, 1)}, true, LevelWarning, ScopeUser, 400, "user storage is approaching its quota"},
+		{"Passes Tenant Hard Stop", 300, []Usage{tenantUsage(900, 1), userUsage(0, 0)}, false, LevelExceeded, ScopeTenant, 100, "upload would exceed the tenant storage quota"},
+		{"Exceeded Outranks Warning", 150, []Usage{tenantUsage(700, 1), userUsage(400, 1)}, false, LevelExceeded, ScopeUser, 100, "upload would exceed the user storage quota"},
+		{"File Limit", 1, []Usage{tenantUsage(0, 10)}, false, LevelExceeded, ScopeTenant, 1000, "upload would exceed the tenant storage quota"},
+		{"Unlimited", 1 << 30, []Usage{{Scope: ScopeTenant, Subject: "acme"}}, true, LevelOK, "", -1, ""},
+		{"No Usages", 1 << 30, nil, true, LevelOK, "", -1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := thresholds.Evaluate(tt.size, tt.usages...)
+			if decision.Allowed != tt.allowed {
+				t.Errorf("Allowed = %v, want %v", decision.Allowed, tt.allowed)
+			}
+			if decision.Level != tt.level {
+				t.Errorf("Level = %v, want %v", decision.Level, tt.level)
+			}
+			if decision.Scope != tt.scope {
+				t.Errorf("Scope = %q, want %q", decision.Scope, tt.scope)
+			}
+			if decision.Remaining != tt.remaining {
+				t.Errorf("Remaining = %d, want %d", decision.Remaining, tt.remaining)
+			}
+			if decision.Message != tt.message {
+				t.Errorf("Message = %q, want %q", decision.Message, tt.message)
+			}
+			if decision.Requested != tt.size {
+				t.Errorf("Requested = %d, want %d", decision.Requested, tt.size)
+			}
+		})
+	}
+}
+
+func TestTenantFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"Longest Allowed", strings.Repeat("a", 64), strings.Repeat("a", 64), false},
+		{"Named Tenant", "acme-bank.eu_1", "acme-bank.eu_1", false},
+		{"Trimmed", "  acme ", "acme", false},
+		{"Empty Uses Fallback", "", DefaultTenant, false},
+		{"Leading Dot", ".acme", "", true},
+		{"Path Traversal", "../acme", "", true},
+		{"Spaces", "acme bank", "", true},
+		{"Too Long", strings.Repeat("a", 65), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TenantFrom(tt.value, DefaultTenant)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TenantFrom(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("TenantFrom(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("POST", "/api/v1/upload", nil)
+	req.Header.Set(TenantHeader, "acme")
+	if got, err := Tenant(req, TenantHeader, DefaultTenant); err != nil || got != "acme" {
+		t.Errorf("Tenant() = %q, %v, want acme", got, err)
+	}
+}
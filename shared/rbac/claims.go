@@ -0,0 +1,128 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Subject is the caller a permission check is made for
+type Subject struct {
+	ID          string   `json:"id"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// HasRole reports whether the subject holds role
+func (s *Subject) HasRole(role string) bool {
+	for _, r := range s.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseToken verifies an HS256 token and returns its subject
+func ParseToken(tokenString string, secret []byte) (*Subject, error) {
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return secret, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	return SubjectFromClaims(claims), nil
+}
+
+// ErrInvalidToken is returned by an Authenticator for tokens it rejects, as
+// opposed to failures to check them
+var ErrInvalidToken = errors.New("invalid token")
+
+// Authenticator resolves a bearer token to its subject. TokenAuthenticator
+// verifies the signature locally; the user service client also checks that
+// the session behind the token is still active.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Subject, error)
+}
+
+// TokenAuthenticator authenticates HS256 tokens signed with a shared secret
+type TokenAuthenticator struct {
+	secret []byte
+}
+
+// NewTokenAuthenticator creates an authenticator for tokens signed with secret
+func NewTokenAuthenticator(secret []byte) *TokenAuthenticator {
+	return &TokenAuthenticator{secret: secret}
+}
+
+// Authenticate implements Authenticator
+func (a *TokenAuthenticator) Authenticate(ctx context.Context, token string) (*Subject, error) {
+	subject, err := ParseToken(token, a.secret)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return subject, nil
+}
+
+// SubjectFromClaims builds a subject from JWT claims. The API gateway issues
+// a "roles" list while user-management issues a single "role"; both are
+// accepted. Direct grants are read from "permissions".
+func SubjectFromClaims(claims map[string]interface{}) *Subject {
+	subject := &Subject{
+		ID:          claimString(claims["user_id"]),
+		Roles:       claimStrings(claims["roles"]),
+		Permissions: claimStrings(claims["permissions"]),
+	}
+	if subject.ID == "" {
+		subject.ID = claimString(claims["sub"])
+	}
+	if role := claimString(claims["role"]); role != "" && !subject.HasRole(role) {
+		subject.Roles = append(subject.Roles, role)
+	}
+	return subject
+}
+
+func claimString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		// JSON numbers, such as user-management's numeric user IDs
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return append([]string(nil), v...)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	}
+	return nil
+}
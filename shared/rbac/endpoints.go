@@ -0,0 +1,85 @@
+package rbac
+
+import "net/http"
+
+// Endpoint is an HTTP route and the permission services require for it
+type Endpoint struct {
+	Service  string `json:"service"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+}
+
+// Permission returns the permission the endpoint requires
+func (e Endpoint) Permission() Permission {
+	return Permission{Resource: e.Resource, Action: e.Action}
+}
+
+func (e Endpoint) String() string {
+	return e.Service + " " + e.Method + " " + e.Path
+}
+
+// endpoint builds a catalog entry whose action follows ActionForMethod, as
+// RequireResource does
+func endpoint(service, method, path, resource string) Endpoint {
+	return Endpoint{
+		Service:  service,
+		Method:   method,
+		Path:     path,
+		Resource: resource,
+		Action:   ActionForMethod(method),
+	}
+}
+
+// DefaultEndpoints catalogs the routes guarded by the shared policy, one or
+// more per resource and action, so a policy change can be translated into
+// the endpoints it opens or closes
+func DefaultEndpoints() []Endpoint {
+	const (
+		alerting      = "alerting-engine"
+		investigation = "investigation-toolkit"
+		resolution    = "entity-resolution"
+		graph         = "graph-engine"
+		ingestion     = "data-ingestion"
+	)
+	return []Endpoint{
+		endpoint(alerting, http.MethodGet, "/alerts", "alerts"),
+		endpoint(alerting, http.MethodPut, "/alerts/{id}", "alerts"),
+		endpoint(alerting, http.MethodDelete, "/alerts/{id}", "alerts"),
+		endpoint(alerting, http.MethodGet, "/rules", "rules"),
+		endpoint(alerting, http.MethodPost, "/rules", "rules"),
+		endpoint(alerting, http.MethodGet, "/notifications", "notifications"),
+		endpoint(alerting, http.MethodPost, "/notification-templates", "notifications"),
+		endpoint(alerting, http.MethodGet, "/watchlists", "watchlists"),
+		endpoint(alerting, http.MethodPost, "/watchlists", "watchlists"),
+		endpoint(alerting, http.MethodGet, "/case-sync", "cases"),
+		endpoint(alerting, http.MethodPost, "/case-sync", "cases"),
+		endpoint(alerting, http.MethodGet, "/training", "training"),
+		endpoint(alerting, http.MethodPost, "/training", "training"),
+		endpoint(alerting, http.MethodGet, "/slo", "slo"),
+		endpoint(alerting, http.MethodGet, "/dead-letters", "dead_letters"),
+		endpoint(alerting, http.MethodPost, "/risk-webhooks", "risk_webhooks"),
+
+		endpoint(investigation, http.MethodGet, "/api/v1/investigations", "investigations"),
+		endpoint(investigation, http.MethodPost, "/api/v1/investigations", "investigations"),
+		endpoint(investigation, http.MethodDelete, "/api/v1/investigations/{id}", "investigations"),
+		endpoint(investigation, http.MethodGet, "/api/v1/evidence", "evidence"),
+		endpoint(investigation, http.MethodPost, "/api/v1/evidence", "evidence"),
+		endpoint(investigation, http.MethodDelete, "/api/v1/evidence/{id}", "evidence"),
+		endpoint(investigation, http.MethodGet, "/api/v1/workflows", "workflows"),
+		endpoint(investigation, http.MethodPost, "/api/v1/workflows", "workflows"),
+		endpoint(investigation, http.MethodGet, "/api/v1/audit", "audit"),
+		endpoint(investigation, http.MethodGet, "/api/v1/sar-filings", "sar"),
+		endpoint(investigation, http.MethodPost, "/api/v1/sar-filings", "sar"),
+		endpoint(investigation, http.MethodGet, "/api/v1/residency", "residency"),
+		endpoint(investigation, http.MethodPut, "/api/v1/residency", "residency"),
+		endpoint(investigation, http.MethodGet, "/api/v1/quotas", "usage"),
+		endpoint(investigation, http.MethodPut, "/api/v1/quotas", "usage"),
+
+		endpoint(resolution, http.MethodGet, "/api/v1/entities/{id}/similar", "entities"),
+		endpoint(resolution, http.MethodPost, "/api/v1/entities/resolve", "entities"),
+		endpoint(graph, http.MethodGet, "/api/v1/entities/{id}/neighborhood", "graph"),
+		endpoint(ingestion, http.MethodPost, "/api/v1/files/upload", "ingestion"),
+	}
+}
@@ -0,0 +1,234 @@
+package rbac
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Decision is the outcome of a permission check
+type Decision struct {
+	Allowed   bool   `json:"allowed"`
+	Reason    string `json:"reason"`
+	MatchedBy string `json:"matched_by,omitempty"`
+}
+
+// Checker decides whether a subject may perform an action on a resource.
+// Evaluator checks locally; the policy service client checks over gRPC.
+type Checker interface {
+	CheckPermission(ctx context.Context, subject *Subject, resource, action string) (Decision, error)
+}
+
+// Evaluator checks subjects against a policy and caches the decisions.
+// Decisions depend only on the subject's roles and direct permissions, so
+// subjects holding the same grants share cache entries.
+type Evaluator struct {
+	mu     sync.RWMutex
+	policy *Policy
+	cache  *decisionCache
+}
+
+// NewEvaluator creates an evaluator. A zero ttl disables caching.
+func NewEvaluator(policy *Policy, ttl time.Duration, maxEntries int) *Evaluator {
+	if policy == nil {
+		policy = DefaultPolicy()
+	}
+	return &Evaluator{
+		policy: policy,
+		cache:  newDecisionCache(ttl, maxEntries),
+	}
+}
+
+// SetPolicy replaces the policy and drops cached decisions
+func (e *Evaluator) SetPolicy(policy *Policy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.policy = policy
+	e.cache.clear()
+}
+
+// Policy returns the policy in use
+func (e *Evaluator) Policy() *Policy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.policy
+}
+
+// Check decides whether subject may perform action on resource
+func (e *Evaluator) Check(subject *Subject, resource, action string) Decision {
+	if subject == nil {
+		return Decision{Reason: "no subject"}
+	}
+
+	// Hold the read lock so a decision made under an old policy is not
+	// cached after SetPolicy cleared the cache
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	key := cacheKey(subject, resource, action)
+	if decision, ok := e.cache.get(key); ok {
+		return decision
+	}
+
+	decision := e.evaluate(subject, resource, action)
+	e.cache.put(key, decision)
+	return decision
+}
+
+// Allowed reports whether subject may perform action on resource
+func (e *Evaluator) Allowed(subject *Subject, resource, action string) bool {
+	return e.Check(subject, resource, action).Allowed
+}
+
+// CheckPermission implements Checker
+func (e *Evaluator) CheckPermission(ctx context.Context, subject *Subject, resource, action string) (Decision, error) {
+	return e.Check(subject, resource, action), nil
+}
+
+func (e *Evaluator) evaluate(subject *Subject, resource, action string) Decision {
+	requested := Permission{Resource: resource, Action: action}
+
+	if role, permission, ok := e.policy.match(subject.Roles, resource, action); ok {
+		return Decision{
+			Allowed:   true,
+			Reason:    "role " + role + " grants " + permission.String(),
+			MatchedBy: "role:" + role,
+		}
+	}
+
+	for _, value := range subject.Permissions {
+		permission, err := ParsePermission(value)
+		if err != nil {
+			continue
+		}
+		if permission.Matches(resource, action) {
+			return Decision{
+				Allowed:   true,
+				Reason:    "token grants " + permission.String(),
+				MatchedBy: "permission:" + permission.String(),
+			}
+		}
+	}
+
+	return Decision{Reason: "no role or permission grants " + requested.String()}
+}
+
+// cacheKey identifies a check by the subject's grants rather than its ID
+func cacheKey(subject *Subject, resource, action string) string {
+	roles := append([]string(nil), subject.Roles...)
+	sort.Strings(roles)
+	permissions := append([]string(nil), subject.Permissions...)
+	sort.Strings(permissions)
+
+	return strings.Join(roles, ",") + "|" + strings.Join(permissions, ",") + "|" + resource + ":" + action
+}
+
+type cachedDecision struct {
+	decision  Decision
+	expiresAt time.Time
+}
+
+// decisionCache is a TTL cache bounded to maxEntries
+type decisionCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]cachedDecision
+}
+
+func newDecisionCache(ttl time.Duration, maxEntries int) *decisionCache {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &decisionCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]cachedDecision),
+	}
+}
+
+func (c *decisionCache) get(key string) (Decision, bool) {
+	if c.ttl <= 0 {
+		return Decision{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return Decision{}, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return Decision{}, false
+	}
+	return entry.decision, true
+}
+
+func (c *decisionCache) put(key string, decision Decision) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= c.maxEntries {
+		for k, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		// Still full of live entries: start over rather than track recency
+		if len(c.entries) >= c.maxEntries {
+			c.entries = make(map[string]cachedDecision)
+		}
+	}
+
+	c.entries[key] = cachedDecision{decision: decision, expiresAt: now.Add(c.ttl)}
+}
+
+func (c *decisionCache) clear() {
+	c.mu.Lock()
+	c.entries = make(map[string]cachedDecision)
+	c.mu.Unlock()
+}
+
+// CachedChecker caches the decisions of another checker, such as the policy
+// service client, so repeated checks skip the round trip. Errors are not cached.
+type CachedChecker struct {
+	checker Checker
+	cache   *decisionCache
+}
+
+// NewCachedChecker wraps checker with a decision cache
+func NewCachedChecker(checker Checker, ttl time.Duration, maxEntries int) *CachedChecker {
+	return &CachedChecker{
+		checker: checker,
+		cache:   newDecisionCache(ttl, maxEntries),
+	}
+}
+
+// CheckPermission implements Checker
+func (c *CachedChecker) CheckPermission(ctx context.Context, subject *Subject, resource, action string) (Decision, error) {
+	if subject == nil {
+		return Decision{Reason: "no subject"}, nil
+	}
+
+	key := cacheKey(subject, resource, action)
+	if decision, ok := c.cache.get(key); ok {
+		return decision, nil
+	}
+
+	decision, err := c.checker.CheckPermission(ctx, subject, resource, action)
+	if err != nil {
+		return Decision{}, err
+	}
+	c.cache.put(key, decision)
+	return decision, nil
+}
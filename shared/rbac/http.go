@@ -0,0 +1,140 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader carries a service account's API key
+const APIKeyHeader = "X-API-Key"
+
+type contextKey struct{}
+
+// WithSubject returns a context carrying subject
+func WithSubject(ctx context.Context, subject *Subject) context.Context {
+	return context.WithValue(ctx, contextKey{}, subject)
+}
+
+// SubjectFromContext returns the subject stored by WithSubject
+func SubjectFromContext(ctx context.Context) (*Subject, bool) {
+	subject, ok := ctx.Value(contextKey{}).(*Subject)
+	return subject, ok && subject != nil
+}
+
+// BearerToken extracts the token from an Authorization header value
+func BearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// Authenticate verifies the request's bearer token and stores its subject in
+// the request context. Requests without a valid token get 401.
+func Authenticate(secret []byte) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := BearerToken(r.Header.Get("Authorization"))
+			if !ok {
+				writeError(w, http.StatusUnauthorized, "Authorization header required")
+				return
+			}
+
+			subject, err := ParseToken(token, secret)
+			if err != nil {
+				writeError(w, http.StatusUnauthorized, "Invalid token")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithSubject(r.Context(), subject)))
+		})
+	}
+}
+
+// AuthenticateAPIKey verifies the request's X-API-Key header with keys and
+// stores the service account's subject in the request context. Requests
+// without a valid key get 401, and 503 when the key could not be checked.
+func AuthenticateAPIKey(keys Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := strings.TrimSpace(r.Header.Get(APIKeyHeader))
+			if key == "" {
+				writeError(w, http.StatusUnauthorized, "API key required")
+				return
+			}
+
+			subject, err := keys.Authenticate(r.Context(), key)
+			if err != nil {
+				if errors.Is(err, ErrInvalidToken) {
+					writeError(w, http.StatusUnauthorized, "Invalid API key")
+					return
+				}
+				writeError(w, http.StatusServiceUnavailable, "API key validation failed")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithSubject(r.Context(), subject)))
+		})
+	}
+}
+
+// Require allows the request through only if the subject in its context may
+// perform action on resource. It runs after Authenticate or another
+// middleware that calls WithSubject.
+func Require(checker Checker, resource, action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			subject, ok := SubjectFromContext(r.Context())
+			if !ok {
+				writeError(w, http.StatusUnauthorized, "Authentication required")
+				return
+			}
+
+			decision, err := checker.CheckPermission(r.Context(), subject, resource, action)
+			if err != nil {
+				writeError(w, http.StatusServiceUnavailable, "Permission check failed")
+				return
+			}
+			if !decision.Allowed {
+				writeError(w, http.StatusForbidden, "Insufficient permissions")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ActionForMethod maps an HTTP method to the action it performs
+func ActionForMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ActionRead
+	case http.MethodDelete:
+		return ActionDelete
+	default:
+		return ActionWrite
+	}
+}
+
+// RequireResource is Require with the action taken from the request method
+func RequireResource(checker Checker, resource string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Require(checker, resource, ActionForMethod(r.Method))(next).ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  message,
+		"status": status,
+	})
+}
@@ -0,0 +1,68 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stubAPIKeys authenticates the keys it knows and reports any other key as
+// invalid; errUnavailable simulates the user service being down
+type stubAPIKeys struct {
+	subjects       map[string]*Subject
+	errUnavailable error
+}
+
+func (s stubAPIKeys) Authenticate(ctx context.Context, key string) (*Subject, error) {
+	if s.errUnavailable != nil {
+		return nil, s.errUnavailable
+	}
+	if subject, ok := s.subjects[key]; ok {
+		return subject, nil
+	}
+	return nil, fmt.Errorf("%w: unknown key", ErrInvalidToken)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	evaluator := NewEvaluator(DefaultPolicy(), time.Minute, 100)
+	keys := stubAPIKeys{subjects: map[string]*Subject{
+		"ask_uploader": {ID: "service-account:1", Permissions: []string{"ingestion:write"}},
+	}}
+
+	newHandler := func(keys Authenticator) http.Handler {
+		ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+		return AuthenticateAPIKey(keys)(RequireResource(evaluator, "ingestion")(ok))
+	}
+
+	tests := []struct {
+		name   string
+		keys   Authenticator
+		method string
+		key    string
+		status int
+	}{
+		{"Missing Key", keys, "POST", "", http.StatusUnauthorized},
+		{"Unknown Key", keys, "POST", "ask_unknown", http.StatusUnauthorized},
+		{"Scope Grants Upload", keys, "POST", "ask_uploader", http.StatusOK},
+		{"Scope Limits Key", keys, "GET", "ask_uploader", http.StatusForbidden},
+		{"User Service Unavailable", stubAPIKeys{errUnavailable: errors.New("connection refused")}, "POST", "ask_uploader", http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/files/upload", nil)
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			newHandler(tt.keys).ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Package rbac evaluates resource/action permissions for the roles and
+// permissions carried in AegisShield JWTs.
+//
+// A permission is written "resource:action", for example "alerts:read".
+// Either half may be "*" to match anything, so "*:*" grants everything and
+// "evidence:*" grants every action on evidence. A subject is allowed an
+// action when one of its roles grants it under the policy, or when the
+// token lists the permission directly.
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Wildcard matches any resource or action
+const Wildcard = "*"
+
+// Roles issued by user-management and the API gateway
+const (
+	RoleAnalyst      = "analyst"
+	RoleInvestigator = "investigator"
+	RoleAdmin        = "admin"
+	RoleCompliance   = "compliance"
+	RoleViewOnly     = "view_only"
+	RoleService      = "service"
+	// RoleExternalCounsel is outside counsel joining individual
+	// investigations; what they see is limited by their case clearance
+	RoleExternalCounsel = "external_counsel"
+)
+
+// Actions used by the default policy
+const (
+	ActionRead    = "read"
+	ActionWrite   = "write"
+	ActionDelete  = "delete"
+	ActionApprove = "approve"
+	ActionAdmin   = "admin"
+)
+
+// Permission is an action on a resource
+type Permission struct {
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+}
+
+// ParsePermission parses "resource:action"
+func ParsePermission(value string) (Permission, error) {
+	resource, action, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok || resource == "" || action == "" || strings.Contains(action, ":") {
+		return Permission{}, fmt.Errorf("invalid permission %q, expected resource:action", value)
+	}
+	return Permission{Resource: resource, Action: action}, nil
+}
+
+func (p Permission) String() string {
+	return p.Resource + ":" + p.Action
+}
+
+// Matches reports whether the permission grants action on resource
+func (p Permission) Matches(resource, action string) bool {
+	return (p.Resource == Wildcard || p.Resource == resource) &&
+		(p.Action == Wildcard || p.Action == action)
+}
+
+// Policy maps roles to the permissions they grant
+type Policy struct {
+	roles map[string][]Permission
+}
+
+// NewPolicy creates an empty policy that denies everything
+func NewPolicy() *Policy {
+	return &Policy{roles: make(map[string][]Permission)}
+}
+
+// Grant adds permissions to a role
+func (p *Policy) Grant(role string, permissions ...string) error {
+	if role == "" {
+		return fmt.Errorf("role is required")
+	}
+	for _, value := range permissions {
+		permission, err := ParsePermission(value)
+		if err != nil {
+			return fmt.Errorf("role %s: %w", role, err)
+		}
+		p.roles[role] = append(p.roles[role], permission)
+	}
+	return nil
+}
+
+// Replace sets the permissions granted to a role, dropping its previous
+// grants. A role with no permissions is removed.
+func (p *Policy) Replace(role string, permissions ...string) error {
+	if role == "" {
+		return fmt.Errorf("role is required")
+	}
+	parsed := make([]Permission, 0, len(permissions))
+	for _, value := range permissions {
+		permission, err := ParsePermission(value)
+		if err != nil {
+			return fmt.Errorf("role %s: %w", role, err)
+		}
+		parsed = append(parsed, permission)
+	}
+
+	if len(parsed) == 0 {
+		delete(p.roles, role)
+		return nil
+	}
+	p.roles[role] = parsed
+	return nil
+}
+
+// Clone returns a copy of the policy that can be changed independently
+func (p *Policy) Clone() *Policy {
+	clone := NewPolicy()
+	for role, permissions := range p.roles {
+		clone.roles[role] = append([]Permission(nil), permissions...)
+	}
+	return clone
+}
+
+// Permissions returns the permissions granted to a role
+func (p *Policy) Permissions(role string) []Permission {
+	return append([]Permission(nil), p.roles[role]...)
+}
+
+// Roles returns the roles known to the policy, sorted
+func (p *Policy) Roles() []string {
+	roles := make([]string, 0, len(p.roles))
+	for role := range p.roles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// match returns the first of the roles that grants action on resource
+func (p *Policy) match(roles []string, resource, action string) (string, Permission, bool) {
+	for _, role := range roles {
+		for _, permission := range p.roles[role] {
+			if permission.Matches(resource, action) {
+				return role, permission, true
+			}
+		}
+	}
+	return "", Permission{}, false
+}
+
+// DefaultPolicy returns the built-in role policy. Resources follow the
+// permission rows seeded by user-management.
+func DefaultPolicy() *Policy {
+	policy := NewPolicy()
+	grants := map[string][]string{
+		RoleAdmin: {"*:*"},
+		RoleAnalyst: {
+			"alerts:read", "alerts:write",
+			"entities:read", "graph:read",
+			"investigations:read",
+			"rules:read", "notifications:read", "watchlists:read",
+			"training:*",
+		},
+		RoleInvestigator: {
+			"alerts:read", "alerts:write",
+			"entities:read", "entities:write", "graph:read",
+			"investigations:*", "evidence:read", "evidence:write",
+			"workflows:read", "workflows:write", "audit:read",
+			"cases:read", "cases:write", "sar:read", "sar:write",
+			"residency:read",
+			"rules:read", "notifications:read", "watchlists:read",
+		},
+		RoleCompliance: {
+			"alerts:read", "investigations:read", "evidence:read",
+			"audit:read", "audit:write", "reports:*", "usage:read",
+			"rules:read", "watchlists:*", "slo:read", "sar:*",
+			"residency:*",
+		},
+		RoleViewOnly: {
+			"alerts:read", "entities:read", "investigations:read",
+		},
+		RoleService: {
+			"alerts:read", "alerts:write",
+			"entities:read", "entities:write", "usage:write",
+		},
+		RoleExternalCounsel: {
+			"investigations:read", "evidence:read",
+		},
+	}
+	for role, permissions := range grants {
+		if err := policy.Grant(role, permissions...); err != nil {
+			panic(err)
+		}
+	}
+	return policy
+}
+
+// LoadPolicy reads a policy from JSON of the form
+//
+//	{"roles": {"analyst": ["alerts:read", "entities:read"]}}
+func LoadPolicy(r io.Reader) (*Policy, error) {
+	var doc struct {
+		Roles map[string][]string `json:"roles"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode policy: %w", err)
+	}
+
+	policy := NewPolicy()
+	for role, permissions := range doc.Roles {
+		if err := policy.Grant(role, permissions...); err != nil {
+			return nil, err
+		}
+	}
+	return policy, nil
+}
+
+// LoadPolicyFile reads a JSON policy file, falling back to the default
+// policy when path is empty
+func LoadPolicyFile(path string) (*Policy, error) {
+	if path == "" {
+		return DefaultPolicy(), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open policy file: %w", err)
+	}
+	defer f.Close()
+
+	return LoadPolicy(f)
+}
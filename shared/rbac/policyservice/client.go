@@ -0,0 +1,48 @@
+package policyservice
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	pb "aegisshield/shared/proto/rbac"
+	"aegisshield/shared/rbac"
+)
+
+// Client checks permissions against a remote policy service. Wrap it in
+// rbac.NewCachedChecker to avoid a round trip per request.
+type Client struct {
+	client pb.PolicyServiceClient
+}
+
+// NewClient creates a policy service client on an existing connection
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{client: pb.NewPolicyServiceClient(conn)}
+}
+
+// CheckPermission implements rbac.Checker
+func (c *Client) CheckPermission(ctx context.Context, subject *rbac.Subject, resource, action string) (rbac.Decision, error) {
+	if subject == nil {
+		return rbac.Decision{Reason: "no subject"}, nil
+	}
+
+	resp, err := c.client.CheckPermission(ctx, &pb.CheckPermissionRequest{
+		Subject: &pb.Subject{
+			Id:          subject.ID,
+			Roles:       subject.Roles,
+			Permissions: subject.Permissions,
+		},
+		Resource: resource,
+		Action:   action,
+	})
+	if err != nil {
+		return rbac.Decision{}, fmt.Errorf("policy check failed: %w", err)
+	}
+
+	return rbac.Decision{
+		Allowed:   resp.GetAllowed(),
+		Reason:    resp.GetReason(),
+		MatchedBy: resp.GetMatchedBy(),
+	}, nil
+}
@@ -0,0 +1,88 @@
+// Package policyservice serves rbac permission checks over gRPC and provides
+// a client that satisfies rbac.Checker.
+package policyservice
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "aegisshield/shared/proto/rbac"
+	"aegisshield/shared/rbac"
+)
+
+// Server implements the PolicyService gRPC service
+type Server struct {
+	pb.UnimplementedPolicyServiceServer
+	evaluator *rbac.Evaluator
+	jwtSecret []byte
+}
+
+// NewServer creates a policy server. jwtSecret verifies tokens sent in place
+// of a subject.
+func NewServer(evaluator *rbac.Evaluator, jwtSecret []byte) *Server {
+	return &Server{
+		evaluator: evaluator,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// Register registers the policy service on a gRPC server
+func (s *Server) Register(grpcServer *grpc.Server) {
+	pb.RegisterPolicyServiceServer(grpcServer, s)
+}
+
+// CheckPermission decides whether the token or subject may perform the action
+func (s *Server) CheckPermission(ctx context.Context, req *pb.CheckPermissionRequest) (*pb.CheckPermissionResponse, error) {
+	if req.GetResource() == "" || req.GetAction() == "" {
+		return nil, status.Error(codes.InvalidArgument, "resource and action are required")
+	}
+
+	var subject *rbac.Subject
+	switch {
+	case req.GetToken() != "":
+		parsed, err := rbac.ParseToken(req.GetToken(), s.jwtSecret)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		subject = parsed
+	case req.GetSubject() != nil:
+		subject = &rbac.Subject{
+			ID:          req.GetSubject().GetId(),
+			Roles:       req.GetSubject().GetRoles(),
+			Permissions: req.GetSubject().GetPermissions(),
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "token or subject is required")
+	}
+
+	decision := s.evaluator.Check(subject, req.GetResource(), req.GetAction())
+	return &pb.CheckPermissionResponse{
+		Allowed:   decision.Allowed,
+		Reason:    decision.Reason,
+		MatchedBy: decision.MatchedBy,
+		SubjectId: subject.ID,
+	}, nil
+}
+
+// ListRolePermissions returns the permissions each role grants
+func (s *Server) ListRolePermissions(ctx context.Context, req *pb.ListRolePermissionsRequest) (*pb.ListRolePermissionsResponse, error) {
+	policy := s.evaluator.Policy()
+
+	roles := policy.Roles()
+	if req.GetRole() != "" {
+		roles = []string{req.GetRole()}
+	}
+
+	resp := &pb.ListRolePermissionsResponse{}
+	for _, role := range roles {
+		entry := &pb.RolePermissions{Role: role}
+		for _, permission := range policy.Permissions(role) {
+			entry.Permissions = append(entry.Permissions, permission.String())
+		}
+		resp.Roles = append(resp.Roles, entry)
+	}
+	return resp, nil
+}
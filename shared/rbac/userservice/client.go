@@ -0,0 +1,95 @@
+// Package userservice authenticates bearer tokens and service account API
+// keys against user-management's gRPC user service. Unlike
+// rbac.TokenAuthenticator it rejects tokens whose session was revoked or
+// expired, and reads the user's current role and permissions instead of the
+// ones in the token.
+package userservice
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/grpc"
+
+	pb "aegisshield/shared/proto/user-management"
+	"aegisshield/shared/rbac"
+)
+
+// ServiceAccountSubjectPrefix starts the subject ID of a service account, so
+// it cannot be mistaken for a user ID
+const ServiceAccountSubjectPrefix = "service-account:"
+
+// Client resolves tokens and users through the user service
+type Client struct {
+	client pb.UserServiceClient
+}
+
+// NewClient creates a user service client on an existing connection
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{client: pb.NewUserServiceClient(conn)}
+}
+
+// Authenticate implements rbac.Authenticator. Sessions that are limited to
+// MFA enrollment or a password change are rejected, as user-management
+// rejects them for every other route.
+func (c *Client) Authenticate(ctx context.Context, token string) (*rbac.Subject, error) {
+	resp, err := c.client.ValidateToken(ctx, &pb.ValidateTokenRequest{Token: token})
+	if err != nil {
+		return nil, fmt.Errorf("token validation failed: %w", err)
+	}
+
+	switch {
+	case !resp.GetValid():
+		return nil, fmt.Errorf("%w: %s", rbac.ErrInvalidToken, resp.GetReason())
+	case resp.GetMfaEnrollmentRequired():
+		return nil, fmt.Errorf("%w: MFA enrollment required", rbac.ErrInvalidToken)
+	case resp.GetPasswordChangeRequired():
+		return nil, fmt.Errorf("%w: password change required", rbac.ErrInvalidToken)
+	}
+
+	return Subject(resp.GetUser()), nil
+}
+
+// APIKeys returns an rbac.Authenticator for service account API keys. A key's
+// subject holds no roles; its scopes are its only permissions.
+func (c *Client) APIKeys() rbac.Authenticator {
+	return apiKeyAuthenticator{client: c.client}
+}
+
+type apiKeyAuthenticator struct {
+	client pb.UserServiceClient
+}
+
+func (a apiKeyAuthenticator) Authenticate(ctx context.Context, key string) (*rbac.Subject, error) {
+	resp, err := a.client.ValidateAPIKey(ctx, &pb.ValidateAPIKeyRequest{Key: key})
+	if err != nil {
+		return nil, fmt.Errorf("API key validation failed: %w", err)
+	}
+	if !resp.GetValid() {
+		return nil, fmt.Errorf("%w: %s", rbac.ErrInvalidToken, resp.GetReason())
+	}
+
+	return &rbac.Subject{
+		ID:          ServiceAccountSubjectPrefix + strconv.FormatUint(resp.GetServiceAccount().GetId(), 10),
+		Permissions: resp.GetScopes(),
+	}, nil
+}
+
+// GetUser looks a user up by ID
+func (c *Client) GetUser(ctx context.Context, id uint64) (*pb.User, error) {
+	resp, err := c.client.GetUser(ctx, &pb.GetUserRequest{Id: id})
+	if err != nil {
+		return nil, fmt.Errorf("user lookup failed: %w", err)
+	}
+	return resp.GetUser(), nil
+}
+
+// Subject builds the RBAC subject for a user
+func Subject(user *pb.User) *rbac.Subject {
+	return &rbac.Subject{
+		ID:          strconv.FormatUint(user.GetId(), 10),
+		Roles:       []string{user.GetRole()},
+		Permissions: user.GetPermissions(),
+	}
+}
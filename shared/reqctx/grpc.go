@@ -0,0 +1,104 @@
+package reqctx
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataCarrier carries the request context in gRPC metadata, whose keys
+// are lowercase
+type MetadataCarrier metadata.MD
+
+// Get implements Carrier
+func (c MetadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Set implements Carrier
+func (c MetadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(strings.ToLower(key), value)
+}
+
+// UnaryServerInterceptor accepts the request context of unary calls. Strict
+// mode fails calls without one with InvalidArgument.
+func UnaryServerInterceptor(opts Options) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := acceptIncoming(ctx, info.FullMethod, opts)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor accepts the request context of streaming calls.
+// Strict mode fails calls without one with InvalidArgument.
+func StreamServerInterceptor(opts Options) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := acceptIncoming(stream.Context(), info.FullMethod, opts)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// UnaryClientInterceptor adds the request context of the call context to
+// the metadata of unary calls, naming service as their origin
+func UnaryClientInterceptor(service string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingContext(ctx, service), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor adds the request context of the call context to
+// the metadata of streaming calls, naming service as their origin
+func StreamClientInterceptor(service string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx, service), desc, cc, method, opts...)
+	}
+}
+
+func acceptIncoming(ctx context.Context, method string, opts Options) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx, err := opts.Accept(ctx, method, MetadataCarrier(md))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return ctx, nil
+}
+
+func outgoingContext(ctx context.Context, service string) context.Context {
+	if _, ok := FromContext(ctx); !ok {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(CorrelationHeader)) > 0 {
+		return ctx
+	}
+
+	md := metadata.MD{}
+	Inject(ctx, service, MetadataCarrier(md))
+	pairs := make([]string, 0, 2*len(md))
+	for key, values := range md {
+		pairs = append(pairs, key, values[0])
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// contextStream is a server stream whose context carries the request context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
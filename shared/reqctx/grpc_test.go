@@ -0,0 +1,42 @@
+package reqctx
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(Options{Strict: true})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return CorrelationID(ctx), nil
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		md     metadata.MD
+		code   codes.Code
+	}{
+		{"Carried ID", "/ingestion.DataIngestionService/ProcessTransaction", metadata.Pairs("x-correlation-id", "req-1"), codes.OK},
+		{"Missing ID", "/ingestion.DataIngestionService/ProcessTransaction", metadata.MD{}, codes.InvalidArgument},
+		{"Health Check Exempt", "/grpc.health.v1.Health/Check", metadata.MD{}, codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			id, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("code = %v, want %v", code, tt.code)
+			}
+			if err == nil && id == "" {
+				t.Error("handler saw no correlation ID")
+			}
+		})
+	}
+}
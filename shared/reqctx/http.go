@@ -0,0 +1,74 @@
+package reqctx
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// HeaderCarrier carries the request context in HTTP headers
+type HeaderCarrier http.Header
+
+// Get implements Carrier
+func (c HeaderCarrier) Get(key string) string {
+	return http.Header(c).Get(key)
+}
+
+// Set implements Carrier
+func (c HeaderCarrier) Set(key, value string) {
+	http.Header(c).Set(key, value)
+}
+
+// Middleware accepts the request context of inbound HTTP requests, returns
+// the correlation ID in the X-Correlation-ID response header and stores the
+// request context in the request context for handlers and loggers. Strict
+// mode answers requests without one with 400.
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, err := opts.Accept(r.Context(), r.URL.Path, HeaderCarrier(r.Header))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			w.Header().Set(CorrelationHeader, CorrelationID(ctx))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Transport sets the request context of the request's context on outgoing
+// HTTP calls, naming Service as their origin
+type Transport struct {
+	Service string
+	Base    http.RoundTripper
+}
+
+// NewTransport wraps base, or http.DefaultTransport when base is nil
+func NewTransport(service string, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Service: service, Base: base}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, ok := FromContext(req.Context()); !ok || req.Header.Get(CorrelationHeader) != "" {
+		return t.Base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	Inject(req.Context(), t.Service, HeaderCarrier(req.Header))
+	return t.Base.RoundTrip(req)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  message,
+		"status": status,
+	})
+}
@@ -0,0 +1,89 @@
+package reqctx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		strict bool
+		path   string
+		id     string
+		status int
+	}{
+		{"Strict Accepts Carried ID", true, "/api/v1/alerts", "req-1", http.StatusOK},
+		{"Strict Rejects Missing ID", true, "/api/v1/alerts", "", http.StatusBadRequest},
+		{"Strict Rejects Invalid ID", true, "/api/v1/alerts", "req 1", http.StatusBadRequest},
+		{"Strict Exempts Probes", true, "/ready", "", http.StatusOK},
+		{"Lenient Starts New ID", false, "/api/v1/alerts", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := Middleware(Options{Service: "alerting-engine", Strict: tt.strict})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = CorrelationID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.id != "" {
+				req.Header.Set(CorrelationHeader, tt.id)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				if seen != "" {
+					t.Error("rejected request reached the handler")
+				}
+				return
+			}
+			if seen == "" || rec.Header().Get(CorrelationHeader) != seen {
+				t.Errorf("handler saw %q, response header %q", seen, rec.Header().Get(CorrelationHeader))
+			}
+			if tt.id != "" && seen != tt.id {
+				t.Errorf("correlation ID = %q, want %q", seen, tt.id)
+			}
+		})
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransport(t *testing.T) {
+	var sent http.Header
+	transport := NewTransport("api-gateway", roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req.Header
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}))
+
+	ctx := NewContext(context.Background(), RequestContext{CorrelationID: "req-1"})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://alerting-engine/alerts", nil)
+	transport.RoundTrip(req)
+	if sent.Get(CorrelationHeader) != "req-1" || sent.Get(OriginHeader) != "api-gateway" {
+		t.Errorf("sent headers %v", sent)
+	}
+	if req.Header.Get(CorrelationHeader) != "" {
+		t.Error("the caller's request was modified")
+	}
+
+	// A correlation ID set by the caller wins
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, "http://alerting-engine/alerts", nil)
+	req.Header.Set(CorrelationHeader, "req-2")
+	transport.RoundTrip(req)
+	if sent.Get(CorrelationHeader) != "req-2" {
+		t.Errorf("correlation ID = %q, want the caller's", sent.Get(CorrelationHeader))
+	}
+}
@@ -0,0 +1,22 @@
+package reqctx
+
+import "context"
+
+// MessageHeaders returns the Kafka message headers that carry the request
+// context of ctx, naming service as the producer. Producers add them to the
+// headers of every message they write; contexts without a request context
+// get none.
+func MessageHeaders(ctx context.Context, service string) map[string]string {
+	headers := MapCarrier{}
+	Inject(ctx, service, headers)
+	return headers
+}
+
+// ConsumeContext returns ctx carrying the request context of a consumed
+// Kafka message's headers. Strict mode returns ErrMissingContext or
+// ErrInvalidCorrelationID for messages without a usable one, which consumers
+// should treat as permanent failures; otherwise a new correlation ID is
+// started for the message.
+func (o Options) ConsumeContext(ctx context.Context, topic string, headers map[string]string) (context.Context, error) {
+	return o.Accept(ctx, topic, MapCarrier(headers))
+}
@@ -0,0 +1,41 @@
+// Package logrusctx adds the request context to logrus loggers
+package logrusctx
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"aegisshield/shared/reqctx"
+)
+
+// Hook adds the request context of an entry's context to the entry, for
+// lines logged through WithContext
+type Hook struct{}
+
+// Levels implements logrus.Hook
+func (Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook
+func (Hook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	for key, value := range reqctx.Fields(entry.Context) {
+		if _, set := entry.Data[key]; !set {
+			entry.Data[key] = value
+		}
+	}
+	return nil
+}
+
+// Entry returns an entry of logger carrying ctx and its request context
+func Entry(ctx context.Context, logger logrus.FieldLogger) *logrus.Entry {
+	fields := logrus.Fields{}
+	for key, value := range reqctx.Fields(ctx) {
+		fields[key] = value
+	}
+	return logger.WithFields(fields).WithContext(ctx)
+}
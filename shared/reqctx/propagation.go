@@ -0,0 +1,124 @@
+package reqctx
+
+import (
+	"context"
+	"strings"
+)
+
+// Carrier reads and writes the request context on a transport's headers
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+// MapCarrier carries the request context in plain string headers, such as
+// Kafka message headers copied into a map. Lookups ignore case.
+type MapCarrier map[string]string
+
+// Get implements Carrier
+func (c MapCarrier) Get(key string) string {
+	if value, ok := c[key]; ok {
+		return value
+	}
+	for name, value := range c {
+		if strings.EqualFold(name, key) {
+			return value
+		}
+	}
+	return ""
+}
+
+// Set implements Carrier
+func (c MapCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// Inject writes the request context of ctx to carrier, naming service as
+// the origin of the call. Contexts without one write nothing.
+func Inject(ctx context.Context, service string, carrier Carrier) {
+	rc, ok := FromContext(ctx)
+	if !ok {
+		return
+	}
+	carrier.Set(CorrelationHeader, rc.CorrelationID)
+	if service != "" {
+		carrier.Set(OriginHeader, service)
+	}
+}
+
+// Extract reads the request context from carrier. It returns
+// ErrMissingContext when there is none and ErrInvalidCorrelationID when the
+// correlation ID is not usable.
+func Extract(carrier Carrier) (RequestContext, error) {
+	id := carrier.Get(CorrelationHeader)
+	if id == "" {
+		return RequestContext{}, ErrMissingContext
+	}
+	if !ValidCorrelationID(id) {
+		return RequestContext{}, ErrInvalidCorrelationID
+	}
+
+	rc := RequestContext{CorrelationID: id}
+	if origin := carrier.Get(OriginHeader); ValidCorrelationID(origin) {
+		rc.Origin = origin
+	}
+	return rc, nil
+}
+
+// DefaultExempt lists the health, readiness, metrics and reflection
+// endpoints that probes and tooling call without a request context
+var DefaultExempt = []string{
+	"/health",
+	"/ready",
+	"/live",
+	"/metrics",
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.",
+}
+
+// Options control how a service accepts the request context of inbound
+// requests and messages
+type Options struct {
+	// Service names this service as the origin of the calls it makes
+	Service string
+	// Strict rejects inbound requests and messages without a usable
+	// correlation ID instead of starting a new one. Internal services run
+	// strict behind the gateway, which assigns IDs at the edge.
+	Strict bool
+	// Exempt lists HTTP path and gRPC method prefixes accepted without a
+	// request context in strict mode; nil means DefaultExempt
+	Exempt []string
+}
+
+// Accept returns ctx carrying the request context read from carrier for
+// the inbound request or message called name: an HTTP path, gRPC method or
+// Kafka topic. Without a usable one, strict mode returns the Extract error
+// unless name is exempt; otherwise a new correlation ID is started.
+func (o Options) Accept(ctx context.Context, name string, carrier Carrier) (context.Context, error) {
+	rc, err := Extract(carrier)
+	if err == nil {
+		return NewContext(ctx, rc), nil
+	}
+	if o.Strict && !o.exempt(name) {
+		return ctx, err
+	}
+
+	rc = RequestContext{CorrelationID: NewCorrelationID()}
+	if origin := carrier.Get(OriginHeader); ValidCorrelationID(origin) {
+		rc.Origin = origin
+	}
+	return NewContext(ctx, rc), nil
+}
+
+func (o Options) exempt(name string) bool {
+	exempt := o.Exempt
+	if exempt == nil {
+		exempt = DefaultExempt
+	}
+	for _, prefix := range exempt {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package reqctx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidCorrelationID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{"Generated ID", NewCorrelationID(), true},
+		{"UUID", "3f2b8c1e-7d4a-4e0b-9c55-0a6f1d2e3b4c", true},
+		{"Dotted Trace ID", "gw.2026_03_02.0042", true},
+		{"Empty", "", false},
+		{"Too Long", strings.Repeat("a", maxCorrelationIDLength+1), false},
+		{"Longest Allowed", strings.Repeat("a", maxCorrelationIDLength), true},
+		{"Log Line Injection", "abc\nlevel=error msg=forged", false},
+		{"Header Injection", "abc\r\nX-Origin-Service: admin", false},
+		{"Spaces", "abc def", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidCorrelationID(tt.id); got != tt.want {
+				t.Errorf("ValidCorrelationID(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptionsAccept(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		path    string
+		headers MapCarrier
+		err     error
+		id      string // expected correlation ID; "new" for a freshly started one
+		origin  string
+	}{
+		{"Carried ID", Options{Strict: true}, "/api/v1/cases", MapCarrier{CorrelationHeader: "req-1", OriginHeader: "api-gateway"}, nil, "req-1", "api-gateway"},
+		{"Header Names Ignore Case", Options{Strict: true}, "/api/v1/cases", MapCarrier{"x-correlation-id": "req-1"}, nil, "req-1", ""},
+		{"Invalid Origin Dropped", Options{Strict: true}, "/api/v1/cases", MapCarrier{CorrelationHeader: "req-1", OriginHeader: "bad origin"}, nil, "req-1", ""},
+		{"Strict Rejects Missing", Options{Strict: true}, "/api/v1/cases", MapCarrier{}, ErrMissingContext, "", ""},
+		{"Strict Rejects Invalid", Options{Strict: true}, "/api/v1/cases", MapCarrier{CorrelationHeader: "req 1"}, ErrInvalidCorrelationID, "", ""},
+		{"Strict Exempts Health", Options{Strict: true}, "/health", MapCarrier{}, nil, "new", ""},
+		{"Strict Exempts gRPC Health", Options{Strict: true}, "/grpc.health.v1.Health/Check", MapCarrier{}, nil, "new", ""},
+		{"Custom Exemptions Replace Defaults", Options{Strict: true, Exempt: []string{"/public/"}}, "/health", MapCarrier{}, ErrMissingContext, "", ""},
+		{"Custom Exemption", Options{Strict: true, Exempt: []string{"/public/"}}, "/public/status", MapCarrier{}, nil, "new", ""},
+		{"Lenient Starts New ID", Options{}, "/api/v1/cases", MapCarrier{OriginHeader: "scheduler"}, nil, "new", "scheduler"},
+		{"Lenient Replaces Invalid ID", Options{}, "/api/v1/cases", MapCarrier{CorrelationHeader: "req\n1"}, nil, "new", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := tt.opts.Accept(context.Background(), tt.path, tt.headers)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				if _, ok := FromContext(ctx); ok {
+					t.Error("rejected request carries a request context")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Accept: %v", err)
+			}
+
+			rc, ok := FromContext(ctx)
+			if !ok {
+				t.Fatal("no request context")
+			}
+			switch tt.id {
+			case "new":
+				if !ValidCorrelationID(rc.CorrelationID) || rc.CorrelationID == tt.headers.Get(CorrelationHeader) {
+					t.Errorf("correlation ID = %q, want a new one", rc.CorrelationID)
+				}
+			default:
+				if rc.CorrelationID != tt.id {
+					t.Errorf("correlation ID = %q, want %q", rc.CorrelationID, tt.id)
+				}
+			}
+			if rc.Origin != tt.origin {
+				t.Errorf("origin = %q, want %q", rc.Origin, tt.origin)
+			}
+		})
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	ctx := NewContext(context.Background(), RequestContext{CorrelationID: "req-1", Origin: "api-gateway"})
+
+	headers := MessageHeaders(ctx, "data-ingestion")
+	rc, err := Extract(MapCarrier(headers))
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	// The origin is the service making this call, not the one before it
+	if rc != (RequestContext{CorrelationID: "req-1", Origin: "data-ingestion"}) {
+		t.Errorf("extracted %+v", rc)
+	}
+
+	if headers := MessageHeaders(context.Background(), "data-ingestion"); len(headers) != 0 {
+		t.Errorf("headers = %v, want none without a request context", headers)
+	}
+
+	if _, err := (Options{Strict: true}).ConsumeContext(context.Background(), "transactions", nil); !errors.Is(err, ErrMissingContext) {
+		t.Errorf("err = %v, want ErrMissingContext for a message without headers", err)
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	ctx := Ensure(context.Background())
+	id := CorrelationID(ctx)
+	if !ValidCorrelationID(id) {
+		t.Fatalf("correlation ID = %q, want a new one", id)
+	}
+	if again := CorrelationID(Ensure(ctx)); again != id {
+		t.Errorf("correlation ID = %q, want %q kept", again, id)
+	}
+}
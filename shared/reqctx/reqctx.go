@@ -0,0 +1,112 @@
+// Package reqctx carries the request context that joins the log lines of one
+// request across services: a correlation ID assigned where the request enters
+// the platform, and the service that made each internal call. It moves with
+// the request through HTTP headers, gRPC metadata and Kafka message headers,
+// and is added to every log line written with the request's context.
+package reqctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+const (
+	// CorrelationHeader carries the correlation ID on HTTP requests and
+	// Kafka messages; gRPC metadata carries it lowercased
+	CorrelationHeader = "X-Correlation-ID"
+	// OriginHeader names the service that made an internal call
+	OriginHeader = "X-Origin-Service"
+)
+
+const (
+	// CorrelationField is the log field holding the correlation ID
+	CorrelationField = "correlation_id"
+	// OriginField is the log field holding the calling service
+	OriginField = "origin_service"
+)
+
+// maxCorrelationIDLength bounds correlation IDs taken from callers
+const maxCorrelationIDLength = 128
+
+var (
+	// ErrMissingContext is returned when a request carries no correlation ID
+	ErrMissingContext = errors.New("request context missing: " + CorrelationHeader + " is required")
+	// ErrInvalidCorrelationID is returned when a request carries a correlation ID that is not usable
+	ErrInvalidCorrelationID = errors.New("invalid " + CorrelationHeader)
+)
+
+// RequestContext identifies the request a unit of work belongs to
+type RequestContext struct {
+	CorrelationID string `json:"correlation_id"`
+	Origin        string `json:"origin_service,omitempty"` // the service that made the call, empty at the edge
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying rc
+func NewContext(ctx context.Context, rc RequestContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, rc)
+}
+
+// FromContext returns the request context stored by NewContext
+func FromContext(ctx context.Context) (RequestContext, bool) {
+	rc, ok := ctx.Value(contextKey{}).(RequestContext)
+	return rc, ok && rc.CorrelationID != ""
+}
+
+// CorrelationID returns the correlation ID carried by ctx, if any
+func CorrelationID(ctx context.Context) string {
+	rc, _ := FromContext(ctx)
+	return rc.CorrelationID
+}
+
+// Ensure returns ctx with a fresh correlation ID unless it already carries
+// one. Work that no request started, such as scheduled jobs, uses it so its
+// log lines and the calls it makes can still be joined.
+func Ensure(ctx context.Context) context.Context {
+	if _, ok := FromContext(ctx); ok {
+		return ctx
+	}
+	return NewContext(ctx, RequestContext{CorrelationID: NewCorrelationID()})
+}
+
+// NewCorrelationID generates a random correlation ID
+func NewCorrelationID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// ValidCorrelationID accepts short IDs of letters, digits, dots, dashes and
+// underscores so caller input cannot forge log lines or headers
+func ValidCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Fields returns the log fields for the request context carried by ctx,
+// keyed by CorrelationField and OriginField
+func Fields(ctx context.Context) map[string]string {
+	rc, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	fields := map[string]string{CorrelationField: rc.CorrelationID}
+	if rc.Origin != "" {
+		fields[OriginField] = rc.Origin
+	}
+	return fields
+}
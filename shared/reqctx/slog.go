@@ -0,0 +1,59 @@
+package reqctx
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler is a slog handler that adds the request context of each record's
+// context to the record, so lines logged with InfoContext and the other
+// Context methods can be joined across services
+type Handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps next
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{Handler: next}
+}
+
+// Handle implements slog.Handler
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if rc, ok := FromContext(ctx); ok {
+		record = record.Clone()
+		record.AddAttrs(attrs(rc)...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
+
+// Logger returns logger with the request context of ctx attached, for code
+// that logs without passing the context on every line
+func Logger(ctx context.Context, logger *slog.Logger) *slog.Logger {
+	rc, ok := FromContext(ctx)
+	if !ok {
+		return logger
+	}
+	args := make([]interface{}, 0, 2)
+	for _, attr := range attrs(rc) {
+		args = append(args, attr)
+	}
+	return logger.With(args...)
+}
+
+func attrs(rc RequestContext) []slog.Attr {
+	attrs := []slog.Attr{slog.String(CorrelationField, rc.CorrelationID)}
+	if rc.Origin != "" {
+		attrs = append(attrs, slog.String(OriginField, rc.Origin))
+	}
+	return attrs
+}
@@ -0,0 +1,32 @@
+// Package zapctx adds the request context to zap loggers
+package zapctx
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"aegisshield/shared/reqctx"
+)
+
+// Fields returns the zap fields for the request context carried by ctx
+func Fields(ctx context.Context) []zap.Field {
+	rc, ok := reqctx.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	fields := []zap.Field{zap.String(reqctx.CorrelationField, rc.CorrelationID)}
+	if rc.Origin != "" {
+		fields = append(fields, zap.String(reqctx.OriginField, rc.Origin))
+	}
+	return fields
+}
+
+// Logger returns logger with the request context of ctx attached
+func Logger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	fields := Fields(ctx)
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
@@ -0,0 +1,111 @@
+package startup
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Backoff controls how a failed startup step is retried
+type Backoff struct {
+	Initial    time.Duration // wait before the first retry
+	Max        time.Duration // upper bound on a single wait
+	Multiplier float64       // growth of the wait after each failure
+	MaxElapsed time.Duration // give up after this long; zero retries until the context ends
+}
+
+// DefaultBackoff retries quickly at first and gives up after five minutes,
+// long enough for dependencies started alongside the service to come up
+func DefaultBackoff() Backoff {
+	return Backoff{
+		Initial:    500 * time.Millisecond,
+		Max:        30 * time.Second,
+		Multiplier: 2,
+		MaxElapsed: 5 * time.Minute,
+	}
+}
+
+// next returns the wait after one that lasted current, before jitter
+func (b Backoff) next(current time.Duration) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	next := time.Duration(float64(current) * multiplier)
+	if b.Max > 0 && next > b.Max {
+		next = b.Max
+	}
+	return next
+}
+
+// jitter spreads a wait by up to a fifth either way so replicas restarted
+// together do not retry in lockstep
+func jitter(d time.Duration) time.Duration {
+	spread := int64(d) / 5
+	if spread <= 0 {
+		return d
+	}
+	return d - time.Duration(spread) + time.Duration(rand.Int63n(2*spread))
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error that retrying cannot fix, such as invalid
+// configuration, so Retry returns it immediately
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Retry calls fn until it succeeds, returns a permanent error, the context
+// ends or the backoff gives up. onRetry, when set, is called before each wait.
+func Retry(ctx context.Context, b Backoff, fn func(ctx context.Context) error, onRetry func(attempt int, err error, wait time.Duration)) error {
+	started := time.Now()
+	wait := b.Initial
+	if wait <= 0 {
+		wait = DefaultBackoff().Initial
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if IsPermanent(err) {
+			return err
+		}
+
+		delay := jitter(wait)
+		if b.MaxElapsed > 0 && time.Since(started)+delay > b.MaxElapsed {
+			return err
+		}
+		if onRetry != nil {
+			onRetry(attempt, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		wait = b.next(wait)
+	}
+}
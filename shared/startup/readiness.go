@@ -0,0 +1,146 @@
+package startup
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// State is the startup state of a component
+type State string
+
+const (
+	StatePending  State = "pending"
+	StateStarting State = "starting"
+	StateReady    State = "ready"
+	StateFailed   State = "failed"
+	StateStopped  State = "stopped"
+)
+
+// ComponentStatus reports the startup progress of one component
+type ComponentStatus struct {
+	Name      string     `json:"name"`
+	Phase     string     `json:"phase"`
+	Critical  bool       `json:"critical"`
+	State     State      `json:"state"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+}
+
+// Readiness gates a service's readiness on its components. The service is
+// ready once startup has completed and every critical component is ready,
+// and stops being ready when a critical component fails or the service
+// begins draining for shutdown.
+type Readiness struct {
+	mu         sync.RWMutex
+	components []*ComponentStatus
+	byName     map[string]*ComponentStatus
+	complete   bool
+	draining   bool
+}
+
+// NewReadiness creates an empty readiness gate
+func NewReadiness() *Readiness {
+	return &Readiness{byName: make(map[string]*ComponentStatus)}
+}
+
+// register adds a component, or returns the existing one with that name
+func (r *Readiness) register(c Component) *ComponentStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if status, ok := r.byName[c.Name]; ok {
+		return status
+	}
+
+	status := &ComponentStatus{
+		Name:     c.Name,
+		Phase:    c.Phase.String(),
+		Critical: c.Critical,
+		State:    StatePending,
+	}
+	r.components = append(r.components, status)
+	r.byName[c.Name] = status
+	return status
+}
+
+// update changes a component's status under the lock
+func (r *Readiness) update(name string, fn func(status *ComponentStatus)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if status, ok := r.byName[name]; ok {
+		fn(status)
+	}
+}
+
+// markComplete records that every startup phase has run
+func (r *Readiness) markComplete() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.complete = true
+}
+
+// Drain marks the service not ready so load balancers stop routing to it
+// before it shuts down
+func (r *Readiness) Drain() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.draining = true
+}
+
+// Ready reports whether the service should receive traffic
+func (r *Readiness) Ready() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.complete || r.draining {
+		return false
+	}
+	for _, status := range r.components {
+		if status.Critical && status.State != StateReady {
+			return false
+		}
+	}
+	return true
+}
+
+// Components returns a snapshot of every component's status in registration order
+func (r *Readiness) Components() []ComponentStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	components := make([]ComponentStatus, 0, len(r.components))
+	for _, status := range r.components {
+		components = append(components, *status)
+	}
+	return components
+}
+
+// ServeHTTP answers readiness probes: 200 when ready, 503 otherwise, with
+// the status of each component in the body
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ready := r.Ready()
+
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not_ready", http.StatusServiceUnavailable
+		r.mu.RLock()
+		if r.draining {
+			status = "draining"
+		} else if !r.complete {
+			status = "starting"
+		}
+		r.mu.RUnlock()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     status,
+		"components": r.Components(),
+		"timestamp":  time.Now().UTC(),
+	})
+}
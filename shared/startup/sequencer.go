@@ -0,0 +1,220 @@
+// Package startup sequences a service's boot. Work runs phase by phase —
+// migrations, repositories, consumers, servers — failed dependency
+// connections are retried with backoff, and a readiness gate stays closed
+// until every critical component has confirmed it is up.
+package startup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Phase orders startup work. A phase begins only after every step of the
+// phases before it has finished, so consumers never run against a schema
+// that is still being migrated and servers never take traffic before the
+// components behind them are up.
+type Phase int
+
+const (
+	PhaseMigrations Phase = iota
+	PhaseRepositories
+	PhaseConsumers
+	PhaseServers
+)
+
+// String returns the phase name
+func (p Phase) String() string {
+	switch p {
+	case PhaseMigrations:
+		return "migrations"
+	case PhaseRepositories:
+		return "repositories"
+	case PhaseConsumers:
+		return "consumers"
+	case PhaseServers:
+		return "servers"
+	default:
+		return fmt.Sprintf("phase(%d)", int(p))
+	}
+}
+
+// Component identifies a unit of startup work
+type Component struct {
+	Name     string
+	Phase    Phase
+	Critical bool // startup fails and the service is not ready without it
+}
+
+// Sequencer runs a service's startup steps in phase order and tracks their
+// readiness
+type Sequencer struct {
+	backoff   Backoff
+	readiness *Readiness
+	logger    *slog.Logger
+
+	mu    sync.Mutex
+	phase Phase
+	wg    sync.WaitGroup
+
+	done     chan struct{}
+	failOnce sync.Once
+	err      error
+}
+
+// NewSequencer creates a sequencer that retries failed steps with backoff
+func NewSequencer(backoff Backoff, logger *slog.Logger) *Sequencer {
+	return &Sequencer{
+		backoff:   backoff,
+		readiness: NewReadiness(),
+		logger:    logger.With("component", "startup"),
+		done:      make(chan struct{}),
+	}
+}
+
+// Readiness returns the readiness gate, which also serves readiness probes
+func (s *Sequencer) Readiness() *Readiness {
+	return s.readiness
+}
+
+// enter moves the sequencer to the component's phase. Steps are issued in
+// order by the caller, so a step from a phase that has already ended is a
+// wiring mistake and panics.
+func (s *Sequencer) enter(c Component) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c.Phase < s.phase {
+		panic(fmt.Sprintf("startup: %s belongs to the %s phase but the %s phase has already begun", c.Name, c.Phase, s.phase))
+	}
+	if c.Phase > s.phase {
+		s.logger.Info("Entering startup phase", "phase", c.Phase.String())
+	}
+	s.phase = c.Phase
+}
+
+// Step runs fn for the component, retrying with backoff until it succeeds.
+// A critical step that gives up returns its error. A non-critical step that
+// gives up is logged and marked failed, and Step returns nil so startup
+// continues without it.
+func (s *Sequencer) Step(ctx context.Context, c Component, fn func(ctx context.Context) error) error {
+	s.enter(c)
+	s.readiness.register(c)
+	s.readiness.update(c.Name, func(status *ComponentStatus) { status.State = StateStarting })
+
+	err := Retry(ctx, s.backoff, func(ctx context.Context) error {
+		err := fn(ctx)
+		s.readiness.update(c.Name, func(status *ComponentStatus) {
+			status.Attempts++
+			if err != nil {
+				status.LastError = err.Error()
+			}
+		})
+		return err
+	}, func(attempt int, err error, wait time.Duration) {
+		s.logger.Warn("Startup step failed, retrying",
+			"step", c.Name,
+			"phase", c.Phase.String(),
+			"attempt", attempt,
+			"retry_in", wait,
+			"error", err)
+	})
+
+	if err != nil {
+		s.readiness.update(c.Name, func(status *ComponentStatus) { status.State = StateFailed })
+		if c.Critical {
+			return fmt.Errorf("%s: %w", c.Name, err)
+		}
+		s.logger.Error("Optional startup step failed, continuing without it",
+			"step", c.Name,
+			"phase", c.Phase.String(),
+			"error", err)
+		return nil
+	}
+
+	s.markReady(c)
+	return nil
+}
+
+// Go starts a long-running component, such as a consumer or server, in the
+// background. Its startup work, e.g. binding a listener, belongs in a Step
+// beforehand; the component is ready once running. When a critical component
+// exits before ctx ends, readiness is withdrawn and Done is closed so the
+// service can shut down.
+func (s *Sequencer) Go(ctx context.Context, c Component, run func(ctx context.Context) error) {
+	s.enter(c)
+	s.readiness.register(c)
+	s.markReady(c)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		err := run(ctx)
+		if ctx.Err() != nil && (err == nil || errors.Is(err, context.Canceled)) {
+			s.readiness.update(c.Name, func(status *ComponentStatus) { status.State = StateStopped })
+			return
+		}
+		if err == nil {
+			err = errors.New("exited unexpectedly")
+		}
+
+		s.readiness.update(c.Name, func(status *ComponentStatus) {
+			status.State = StateFailed
+			status.LastError = err.Error()
+		})
+		if !c.Critical {
+			s.logger.Error("Optional component stopped", "component_name", c.Name, "error", err)
+			return
+		}
+
+		s.logger.Error("Critical component stopped", "component_name", c.Name, "error", err)
+		s.fail(fmt.Errorf("%s: %w", c.Name, err))
+	}()
+}
+
+// Complete marks the end of startup. The service becomes ready once every
+// critical component is ready.
+func (s *Sequencer) Complete() {
+	s.readiness.markComplete()
+	s.logger.Info("Startup complete", "ready", s.readiness.Ready())
+}
+
+// Done is closed when a critical component stops after startup
+func (s *Sequencer) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the failure that closed Done
+func (s *Sequencer) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Wait blocks until every component started with Go has returned
+func (s *Sequencer) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Sequencer) markReady(c Component) {
+	now := time.Now().UTC()
+	s.readiness.update(c.Name, func(status *ComponentStatus) {
+		status.State = StateReady
+		status.LastError = ""
+		status.ReadyAt = &now
+	})
+	s.logger.Info("Component ready", "component_name", c.Name, "phase", c.Phase.String())
+}
+
+func (s *Sequencer) fail(err error) {
+	s.failOnce.Do(func() {
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		close(s.done)
+	})
+}
@@ -21,6 +21,8 @@ import (
 	"unicode"
 
 	"github.com/google/uuid"
+
+	"aegisshield/shared/validation"
 )
 
 // ID Generation Utilities
@@ -125,27 +127,12 @@ func IsValidPhoneNumber(phone string) bool {
 
 func IsValidCountryCode(code string) bool {
 	// ISO 3166-1 alpha-2 country codes
-	validCodes := map[string]bool{
-		"US": true, "CA": true, "GB": true, "DE": true, "FR": true,
-		"IT": true, "ES": true, "NL": true, "BE": true, "CH": true,
-		"AU": true, "NZ": true, "JP": true, "CN": true, "IN": true,
-		"BR": true, "MX": true, "AR": true, "CL": true, "PE": true,
-		"SG": true, "HK": true, "AE": true, "SA": true, "IL": true,
-		// Add more as needed
-	}
-	return validCodes[strings.ToUpper(code)]
+	return validation.IsCountryCode(strings.ToUpper(code))
 }
 
 func IsValidCurrencyCode(code string) bool {
 	// ISO 4217 currency codes
-	validCodes := map[string]bool{
-		"USD": true, "EUR": true, "GBP": true, "JPY": true, "CAD": true,
-		"AUD": true, "CHF": true, "CNY": true, "INR": true, "BRL": true,
-		"MXN": true, "SGD": true, "HKD": true, "AED": true, "SAR": true,
-		"ILS": true, "ZAR": true, "RUB": true, "KRW": true, "SEK": true,
-		// Add more as needed
-	}
-	return validCodes[strings.ToUpper(code)]
+	return validation.IsCurrencyCode(strings.ToUpper(code))
 }
 
 func IsValidAmount(amount float64) bool {
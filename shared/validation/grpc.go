@@ -0,0 +1,67 @@
+package validation
+
+import (
+	"context"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Validator is implemented by requests that validate themselves, such as
+// generated messages with hand-written checks
+type Validator interface {
+	Validate() error
+}
+
+// UnaryServerInterceptor validates requests before they reach the handler.
+// Requests implementing Validator are checked with Validate, everything else
+// with its struct tags. Failures return InvalidArgument with the failing
+// fields attached as a BadRequest detail.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := Request(req); err != nil {
+			return nil, StatusError(err)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StatusError converts a validation error into an InvalidArgument status
+func StatusError(err error) error {
+	errs, ok := FieldErrors(err)
+	if !ok {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	st := status.New(codes.InvalidArgument, errs.Error())
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(errs))
+	for i, fieldErr := range errs {
+		violations[i] = &errdetails.BadRequest_FieldViolation{
+			Field:       fieldErr.Field,
+			Description: fieldErr.Message,
+		}
+	}
+
+	detailed, detailErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// Request validates a gRPC request with its Validate method when it has one,
+// otherwise with its struct tags
+func Request(req interface{}) error {
+	if v, ok := req.(Validator); ok {
+		return v.Validate()
+	}
+	if err := Struct(req); err != nil {
+		if _, ok := FieldErrors(err); ok {
+			return err
+		}
+	}
+	// Requests that are not structs have nothing to check
+	return nil
+}
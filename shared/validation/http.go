@@ -0,0 +1,46 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// DecodeJSON decodes the request body into v and validates it. Malformed
+// bodies return a plain error; invalid fields return Errors.
+func DecodeJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return Struct(v)
+}
+
+// FieldErrors returns the field errors carried by err, if any
+func FieldErrors(err error) (Errors, bool) {
+	var errs Errors
+	if errors.As(err, &errs) {
+		return errs, true
+	}
+	return nil, false
+}
+
+// WriteError writes err as JSON: 422 with the failing fields for validation
+// errors, 400 for anything else such as a malformed body
+func WriteError(w http.ResponseWriter, err error) {
+	response := map[string]interface{}{
+		"error": err.Error(),
+	}
+
+	statusCode := http.StatusBadRequest
+	if errs, ok := FieldErrors(err); ok {
+		statusCode = http.StatusUnprocessableEntity
+		response["error"] = "Validation failed"
+		response["fields"] = errs
+	}
+	response["status"] = statusCode
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,309 @@
+// Package validation validates request DTOs from `validate` struct tags.
+//
+// Rules are comma separated and run in order, for example:
+//
+//	type PaymentRequest struct {
+//		Account  string `json:"account" validate:"required,iban"`
+//		Currency string `json:"currency" validate:"required,currency"`
+//		Country  string `json:"country,omitempty" validate:"omitempty,country"`
+//	}
+//
+// Built-in rules are required, omitempty, min, max, len, oneof, email, uuid,
+// iban, bic, country and currency. Services add their own with Register.
+// Nested structs are validated recursively; slice elements only when the
+// field carries the dive rule.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Func checks a single field value against a rule parameter and returns a
+// message describing the failure, or an empty string when the value is valid
+type Func func(value reflect.Value, param string) string
+
+// FieldError describes one field that failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Errors is the list of field errors returned by Struct
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Error()
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+var (
+	emailPattern = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	registryMu sync.RWMutex
+	registry   = map[string]Func{
+		"required": validateRequired,
+		"min":      validateMin,
+		"max":      validateMax,
+		"len":      validateLen,
+		"oneof":    validateOneOf,
+		"email":    stringRule("must be a valid email address", emailPattern.MatchString),
+		"uuid":     stringRule("must be a valid UUID", uuidPattern.MatchString),
+		"iban":     stringRule("must be a valid IBAN", IsIBAN),
+		"bic":      stringRule("must be a valid BIC", IsBIC),
+		"country":  stringRule("must be an ISO 3166-1 alpha-2 country code", IsCountryCode),
+		"currency": stringRule("must be an ISO 4217 currency code", IsCurrencyCode),
+	}
+)
+
+// Register adds or replaces a named rule
+func Register(name string, fn Func) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = fn
+}
+
+// Struct validates v, which must be a struct or a pointer to one, and
+// returns Errors listing every failing field, or nil
+func Struct(v interface{}) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("validation: expected a struct, got %s", value.Kind())
+	}
+
+	var errs Errors
+	validateStruct(value, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateStruct(value reflect.Value, prefix string, errs *Errors) {
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		fieldValue := value.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+
+		dive := false
+		if tag != "" {
+			dive = validateField(fieldValue, name, tag, errs)
+		}
+
+		validateNested(fieldValue, name, dive, errs)
+	}
+}
+
+// validateField applies the rules of one tag and reports whether the field
+// asked for its slice elements to be validated
+func validateField(value reflect.Value, name, tag string, errs *Errors) bool {
+	dive := false
+	for _, rule := range strings.Split(tag, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		ruleName, param, _ := strings.Cut(rule, "=")
+		switch ruleName {
+		case "omitempty":
+			if isEmpty(value) {
+				return false
+			}
+			continue
+		case "dive":
+			dive = true
+			continue
+		}
+
+		registryMu.RLock()
+		fn, exists := registry[ruleName]
+		registryMu.RUnlock()
+		if !exists {
+			*errs = append(*errs, FieldError{Field: name, Rule: ruleName, Param: param, Message: "unknown validation rule " + ruleName})
+			return false
+		}
+
+		if message := fn(value, param); message != "" {
+			*errs = append(*errs, FieldError{Field: name, Rule: ruleName, Param: param, Message: message})
+			// Later rules usually assume earlier ones held
+			return false
+		}
+	}
+	return dive
+}
+
+func validateNested(value reflect.Value, name string, dive bool, errs *Errors) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		validateStruct(value, name, errs)
+	case reflect.Slice, reflect.Array:
+		if !dive {
+			return
+		}
+		for i := 0; i < value.Len(); i++ {
+			validateNested(value.Index(i), fmt.Sprintf("%s[%d]", name, i), false, errs)
+		}
+	}
+}
+
+// fieldName reports a field by its JSON name so errors match the payload
+func fieldName(field reflect.StructField) string {
+	if tag := field.Tag.Get("json"); tag != "" {
+		if name, _, _ := strings.Cut(tag, ","); name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.String, reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	// Arrays such as uuid.UUID are empty when every element is zero
+	return value.IsZero()
+}
+
+func validateRequired(value reflect.Value, _ string) string {
+	if isEmpty(value) {
+		return "is required"
+	}
+	return ""
+}
+
+func validateMin(value reflect.Value, param string) string {
+	return compareBound(value, param, "at least", func(n, bound float64) bool { return n >= bound })
+}
+
+func validateMax(value reflect.Value, param string) string {
+	return compareBound(value, param, "at most", func(n, bound float64) bool { return n <= bound })
+}
+
+func validateLen(value reflect.Value, param string) string {
+	return compareBound(value, param, "exactly", func(n, bound float64) bool { return n == bound })
+}
+
+// compareBound compares lengths for strings and collections and values for numbers
+func compareBound(value reflect.Value, param, relation string, ok func(n, bound float64) bool) string {
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return "has an invalid rule parameter " + param
+	}
+
+	value = indirect(value)
+	if !value.IsValid() {
+		return ""
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		if !ok(float64(utf8.RuneCountInString(value.String())), bound) {
+			return fmt.Sprintf("must be %s %s characters long", relation, param)
+		}
+	case reflect.Slice, reflect.Map, reflect.Array:
+		if !ok(float64(value.Len()), bound) {
+			return fmt.Sprintf("must contain %s %s items", relation, param)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !ok(float64(value.Int()), bound) {
+			return fmt.Sprintf("must be %s %s", relation, param)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !ok(float64(value.Uint()), bound) {
+			return fmt.Sprintf("must be %s %s", relation, param)
+		}
+	case reflect.Float32, reflect.Float64:
+		if !ok(value.Float(), bound) {
+			return fmt.Sprintf("must be %s %s", relation, param)
+		}
+	}
+	return ""
+}
+
+func validateOneOf(value reflect.Value, param string) string {
+	value = indirect(value)
+	if !value.IsValid() {
+		return ""
+	}
+
+	options := strings.Fields(param)
+	actual := fmt.Sprint(value.Interface())
+	for _, option := range options {
+		if actual == option {
+			return ""
+		}
+	}
+	return "must be one of " + strings.Join(options, ", ")
+}
+
+// stringRule adapts a string predicate to a Func; non-string fields pass
+func stringRule(message string, valid func(string) bool) Func {
+	return func(value reflect.Value, _ string) string {
+		value = indirect(value)
+		if !value.IsValid() || value.Kind() != reflect.String {
+			return ""
+		}
+		if !valid(value.String()) {
+			return message
+		}
+		return ""
+	}
+}
+
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}